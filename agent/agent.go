@@ -29,11 +29,13 @@ import (
 	"golang.org/x/exp/slices"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/xerrors"
+	"tailscale.com/net/socks5"
 	"tailscale.com/net/speedtest"
 	"tailscale.com/tailcfg"
 	"tailscale.com/types/netlogtype"
 
 	"cdr.dev/slog"
+	"github.com/coder/coder/agent/agentsecrets"
 	"github.com/coder/coder/agent/agentssh"
 	"github.com/coder/coder/agent/reconnectingpty"
 	"github.com/coder/coder/buildinfo"
@@ -67,7 +69,20 @@ type Options struct {
 	Addresses                    []netip.Prefix
 	PrometheusRegistry           *prometheus.Registry
 	ReportMetadataInterval       time.Duration
+	MetadataPluginDir            string
+	MetadataPluginReloadInterval time.Duration
 	ServiceBannerRefreshInterval time.Duration
+	// SecretResolver resolves references to secrets in external secret
+	// managers in the environment variables and scripts of the manifest. If
+	// nil, references are left as they are.
+	SecretResolver *agentsecrets.Resolver
+	// DotfilesCommand is the command the arguments of "coder dotfiles" are
+	// appended to when applying the dotfiles of the workspace owner. It
+	// defaults to the agent binary.
+	DotfilesCommand string
+	// DotfilesTimeout is how long applying the dotfiles may take before it's
+	// killed. It defaults to 10 minutes.
+	DotfilesTimeout time.Duration
 }
 
 type Client interface {
@@ -76,11 +91,15 @@ type Client interface {
 	DERPMapUpdates(ctx context.Context) (<-chan agentsdk.DERPMapUpdate, io.Closer, error)
 	ReportStats(ctx context.Context, log slog.Logger, statsChan <-chan *agentsdk.Stats, setInterval func(time.Duration)) (io.Closer, error)
 	PostLifecycle(ctx context.Context, state agentsdk.PostLifecycleRequest) error
+	PostScriptStatus(ctx context.Context, req agentsdk.PostScriptStatusRequest) error
 	PostAppHealth(ctx context.Context, req agentsdk.PostAppHealthsRequest) error
 	PostStartup(ctx context.Context, req agentsdk.PostStartupRequest) error
 	PostMetadata(ctx context.Context, key string, req agentsdk.PostMetadataRequest) error
+	PostMetadataPlugins(ctx context.Context, req agentsdk.PostMetadataPluginsRequest) error
 	PatchLogs(ctx context.Context, req agentsdk.PatchLogs) error
 	GetServiceBanner(ctx context.Context) (codersdk.ServiceBannerConfig, error)
+	WaitForDrain(ctx context.Context) (agentsdk.DrainRequest, error)
+	GitAuth(ctx context.Context, gitURL string, listen bool) (agentsdk.GitAuthResponse, error)
 }
 
 type Agent interface {
@@ -111,9 +130,15 @@ func New(options Options) Agent {
 	if options.ReportMetadataInterval == 0 {
 		options.ReportMetadataInterval = time.Second
 	}
+	if options.MetadataPluginReloadInterval == 0 {
+		options.MetadataPluginReloadInterval = 30 * time.Second
+	}
 	if options.ServiceBannerRefreshInterval == 0 {
 		options.ServiceBannerRefreshInterval = 2 * time.Minute
 	}
+	if options.DotfilesTimeout == 0 {
+		options.DotfilesTimeout = 10 * time.Minute
+	}
 
 	prometheusRegistry := options.PrometheusRegistry
 	if prometheusRegistry == nil {
@@ -139,10 +164,15 @@ func New(options Options) Agent {
 		ignorePorts:                  options.IgnorePorts,
 		connStatsChan:                make(chan *agentsdk.Stats, 1),
 		reportMetadataInterval:       options.ReportMetadataInterval,
+		metadataPluginDir:            options.MetadataPluginDir,
+		metadataPluginReloadInterval: options.MetadataPluginReloadInterval,
 		serviceBannerRefreshInterval: options.ServiceBannerRefreshInterval,
 		sshMaxTimeout:                options.SSHMaxTimeout,
 		subsystems:                   options.Subsystems,
 		addresses:                    options.Addresses,
+		secretResolver:               options.SecretResolver,
+		dotfilesCommand:              options.DotfilesCommand,
+		dotfilesTimeout:              options.DotfilesTimeout,
 
 		prometheusRegistry: prometheusRegistry,
 		metrics:            newAgentMetrics(prometheusRegistry),
@@ -164,6 +194,9 @@ type agent struct {
 	// are used by the agent, that the user does not care about.
 	ignorePorts map[int]string
 	subsystems  []codersdk.AgentSubsystem
+	// devcontainerPorts are the forwardPorts of the devcontainers of the
+	// manifest, which are listed even if nothing listens on them yet.
+	devcontainerPorts atomic.Pointer[[]uint16]
 
 	reconnectingPTYs       sync.Map
 	reconnectingPTYTimeout time.Duration
@@ -173,10 +206,15 @@ type agent struct {
 	closeMutex    sync.Mutex
 	closed        chan struct{}
 
-	envVars map[string]string
+	envVars         map[string]string
+	secretResolver  *agentsecrets.Resolver
+	dotfilesCommand string
+	dotfilesTimeout time.Duration
 
 	manifest                     atomic.Pointer[agentsdk.Manifest] // manifest is atomic because values can change after reconnection.
 	reportMetadataInterval       time.Duration
+	metadataPluginDir            string
+	metadataPluginReloadInterval time.Duration
 	serviceBanner                atomic.Pointer[codersdk.ServiceBannerConfig] // serviceBanner is atomic because it is periodically updated.
 	serviceBannerRefreshInterval time.Duration
 	sessionToken                 atomic.Pointer[string]
@@ -188,6 +226,9 @@ type agent struct {
 	lifecycleMu       sync.RWMutex // Protects following.
 	lifecycleStates   []agentsdk.PostLifecycleRequest
 
+	shutdownOnce  sync.Once
+	shutdownState codersdk.WorkspaceAgentLifecycle
+
 	network       *tailnet.Conn
 	addresses     []netip.Prefix
 	connStatsChan chan *agentsdk.Stats
@@ -208,6 +249,17 @@ func (a *agent) init(ctx context.Context) {
 	if err != nil {
 		panic(err)
 	}
+	envVars := make(map[string]string, len(a.envVars)+1)
+	for k, v := range a.envVars {
+		envVars[k] = v
+	}
+	gitCredentialsSocket, err := a.listenGitCredentials(ctx)
+	if err != nil {
+		a.logger.Warn(ctx, "failed to serve git credentials", slog.Error(err))
+	} else {
+		envVars[EnvGitCredentialsSocket] = gitCredentialsSocket
+	}
+	a.envVars = envVars
 	sshSrv.Env = a.envVars
 	sshSrv.AgentToken = func() string { return *a.sessionToken.Load() }
 	sshSrv.Manifest = &a.manifest
@@ -225,6 +277,7 @@ func (a *agent) runLoop(ctx context.Context) {
 	go a.reportLifecycleLoop(ctx)
 	go a.reportMetadataLoop(ctx)
 	go a.fetchServiceBannerLoop(ctx)
+	go a.drainLoop(ctx)
 
 	for retrier := retry.New(100*time.Millisecond, 10*time.Second); retrier.Wait(ctx); {
 		a.logger.Info(ctx, "connecting to coderd")
@@ -337,6 +390,14 @@ func (a *agent) reportMetadataLoop(ctx context.Context) {
 		lastCollectedAts  = make(map[string]time.Time)
 		metadataResults   = make(chan metadataResultAndKey, metadataLimit)
 		logger            = a.logger.Named("metadata")
+		plugins           = metadataPlugins{
+			fs:             a.filesystem,
+			dir:            a.metadataPluginDir,
+			reloadInterval: a.metadataPluginReloadInterval,
+			limit:          metadataLimit,
+			client:         a.client,
+			logger:         logger.Named("plugins"),
+		}
 	)
 	defer baseTicker.Stop()
 
@@ -377,12 +438,20 @@ func (a *agent) reportMetadataLoop(ctx context.Context) {
 			continue
 		}
 
-		if len(manifest.Metadata) > metadataLimit {
+		metadata := manifest.Metadata
+		if a.metadataPluginDir != "" {
+			if registered := plugins.sync(ctx, manifest); len(registered) > 0 {
+				metadata = append(slices.Clone(metadata), registered...)
+			}
+		}
+
+		if len(metadata) > metadataLimit {
+			// Collect what fits rather than nothing at all.
 			logger.Error(
-				ctx, "metadata limit exceeded",
-				slog.F("limit", metadataLimit), slog.F("got", len(manifest.Metadata)),
+				ctx, "metadata limit exceeded, ignoring the remaining metadata",
+				slog.F("limit", metadataLimit), slog.F("got", len(metadata)),
 			)
-			continue
+			metadata = metadata[:metadataLimit]
 		}
 
 		// If the manifest changes (e.g. on agent reconnect) we need to
@@ -390,7 +459,7 @@ func (a *agent) reportMetadataLoop(ctx context.Context) {
 		// boundlessly.
 		lastCollectedAtMu.Lock()
 		for key := range lastCollectedAts {
-			if slices.IndexFunc(manifest.Metadata, func(md codersdk.WorkspaceAgentMetadataDescription) bool {
+			if slices.IndexFunc(metadata, func(md codersdk.WorkspaceAgentMetadataDescription) bool {
 				return md.Key == key
 			}) < 0 {
 				logger.Debug(ctx, "deleting lastCollected key, missing from manifest",
@@ -404,7 +473,7 @@ func (a *agent) reportMetadataLoop(ctx context.Context) {
 		// Spawn a goroutine for each metadata collection, and use a
 		// channel to synchronize the results and avoid both messy
 		// mutex logic and overloading the API.
-		for _, md := range manifest.Metadata {
+		for _, md := range metadata {
 			md := md
 			// We send the result to the channel in the goroutine to avoid
 			// sending the same result multiple times. So, we don't care about
@@ -587,7 +656,8 @@ func (a *agent) run(ctx context.Context) error {
 	if err != nil {
 		return xerrors.Errorf("fetch metadata: %w", err)
 	}
-	a.logger.Info(ctx, "fetched manifest", slog.F("manifest", manifest))
+	a.logger.Info(ctx, "fetched manifest", slog.F("manifest", redactSecrets(manifest)))
+	a.expandSecrets(ctx, &manifest)
 
 	if manifest.AgentID == uuid.Nil {
 		return xerrors.New("nil agentID returned by manifest")
@@ -633,9 +703,26 @@ func (a *agent) run(ctx context.Context) error {
 
 		lifecycleState := codersdk.WorkspaceAgentLifecycleReady
 		scriptDone := make(chan error, 1)
+		scriptsDone := make(chan error, 1)
 		err = a.trackConnGoroutine(func() {
-			defer close(scriptDone)
-			scriptDone <- a.runStartupScript(ctx, manifest.StartupScript)
+			defer close(scriptsDone)
+			func() {
+				defer close(scriptDone)
+				scriptDone <- a.runStartupScript(ctx, manifest.StartupScript)
+			}()
+			// The scripts have their own timeouts, so they aren't covered
+			// by the startup script timeout.
+			scriptsErr := a.runScripts(ctx, "startup", manifest.Scripts)
+			// Devcontainers are applied after the scripts, which usually
+			// clone the repositories they are defined in.
+			if err := a.applyDevcontainers(ctx, manifest.Devcontainers); scriptsErr == nil {
+				scriptsErr = err
+			}
+			// Dotfiles are applied last, so they can rely on the tools
+			// installed by the scripts and devcontainer features. Failing
+			// to apply them doesn't fail the startup.
+			a.applyDotfiles(ctx, manifest.Dotfiles)
+			scriptsDone <- scriptsErr
 		})
 		if err != nil {
 			return xerrors.Errorf("track startup script: %w", err)
@@ -658,6 +745,9 @@ func (a *agent) run(ctx context.Context) error {
 				a.setLifecycle(ctx, codersdk.WorkspaceAgentLifecycleStartTimeout)
 				err = <-scriptDone // The script can still complete after a timeout.
 			}
+			if scriptsErr := <-scriptsDone; err == nil {
+				err = scriptsErr
+			}
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
 					return
@@ -888,6 +978,39 @@ func (a *agent) createTailnet(ctx context.Context, agentID uuid.UUID, derpMap *t
 		return nil, err
 	}
 
+	socks5Listener, err := network.Listen("tcp", ":"+strconv.Itoa(codersdk.WorkspaceAgentSOCKS5Port))
+	if err != nil {
+		return nil, xerrors.Errorf("listen for socks5: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = socks5Listener.Close()
+		}
+	}()
+	if err = a.trackConnGoroutine(func() {
+		defer socks5Listener.Close()
+		logger := a.logger.Named("socks5")
+		server := &socks5.Server{
+			Logf: func(format string, args ...any) {
+				logger.Debug(ctx, fmt.Sprintf(format, args...))
+			},
+		}
+		go func() {
+			select {
+			case <-ctx.Done():
+			case <-a.closed:
+			}
+			_ = socks5Listener.Close()
+		}()
+
+		err := server.Serve(socks5Listener)
+		if err != nil && !a.isClosed() && !xerrors.Is(err, net.ErrClosed) {
+			logger.Debug(ctx, "socks5 listener failed", slog.Error(err))
+		}
+	}); err != nil {
+		return nil, err
+	}
+
 	apiListener, err := network.Listen("tcp", ":"+strconv.Itoa(codersdk.WorkspaceAgentHTTPAPIServerPort))
 	if err != nil {
 		return nil, xerrors.Errorf("api listener: %w", err)
@@ -900,10 +1023,10 @@ func (a *agent) createTailnet(ctx context.Context, agentID uuid.UUID, derpMap *t
 	if err = a.trackConnGoroutine(func() {
 		defer apiListener.Close()
 		server := &http.Server{
-			Handler:           a.apiHandler(),
-			ReadTimeout:       20 * time.Second,
+			Handler: a.apiHandler(),
+			// File transfers can take arbitrarily long, so only the headers
+			// are bounded.
 			ReadHeaderTimeout: 20 * time.Second,
-			WriteTimeout:      20 * time.Second,
 			ErrorLog:          slog.Stdlib(ctx, a.logger.Named("http_api_server"), slog.LevelInfo),
 		}
 		go func() {
@@ -977,60 +1100,97 @@ func (a *agent) runDERPMapSubscriber(ctx context.Context, network *tailnet.Conn)
 	}
 }
 
+// shutdownLogsFlushTimeout is how long the agent waits for the output of the
+// shutdown script to be sent to coderd.
+const shutdownLogsFlushTimeout = 5 * time.Second
+
 func (a *agent) runStartupScript(ctx context.Context, script string) error {
-	return a.runScript(ctx, "startup", script)
+	return a.runScript(ctx, "startup", "", script, 0)
 }
 
 func (a *agent) runShutdownScript(ctx context.Context, script string) error {
-	return a.runScript(ctx, "shutdown", script)
+	return a.runScript(ctx, "shutdown", "", script, 0)
 }
 
-func (a *agent) runScript(ctx context.Context, lifecycle, script string) (err error) {
+// runScript runs a script of the given lifecycle. The name is empty for the
+// startup and shutdown scripts of the agent, and the name of the script for
+// the scripts in the manifest. The script is killed if it runs longer than the
+// timeout, unless the timeout is zero.
+func (a *agent) runScript(ctx context.Context, lifecycle, name, script string, timeout time.Duration) (err error) {
 	if script == "" {
 		return nil
 	}
 
 	logger := a.logger.With(slog.F("lifecycle", lifecycle))
+	desc := fmt.Sprintf("%s script", lifecycle)
+	logFile := fmt.Sprintf("coder-%s-script.log", lifecycle)
+	if name != "" {
+		logger = logger.With(slog.F("script_name", name))
+		desc = fmt.Sprintf("%s script %q", lifecycle, name)
+		logFile = fmt.Sprintf("coder-script-%s.log", name)
+	}
 
-	logger.Info(ctx, fmt.Sprintf("running %s script", lifecycle), slog.F("script", script))
-	fileWriter, err := a.filesystem.OpenFile(filepath.Join(a.logDir, fmt.Sprintf("coder-%s-script.log", lifecycle)), os.O_CREATE|os.O_RDWR, 0o600)
+	logger.Info(ctx, fmt.Sprintf("running %s", desc), slog.F("script", script))
+	fileWriter, err := a.filesystem.OpenFile(filepath.Join(a.logDir, logFile), os.O_CREATE|os.O_RDWR, 0o600)
 	if err != nil {
-		return xerrors.Errorf("open %s script log file: %w", lifecycle, err)
+		return xerrors.Errorf("open %s log file: %w", desc, err)
 	}
 	defer func() {
 		err := fileWriter.Close()
 		if err != nil {
-			logger.Warn(ctx, fmt.Sprintf("close %s script log file", lifecycle), slog.Error(err))
+			logger.Warn(ctx, fmt.Sprintf("close %s log file", desc), slog.Error(err))
 		}
 	}()
 
-	cmdPty, err := a.sshServer.CreateCommand(ctx, script, nil)
+	// The logs are still flushed with ctx after the script is killed.
+	cmdCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		cmdCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cmdPty, err := a.sshServer.CreateCommand(cmdCtx, script, nil)
 	if err != nil {
-		return xerrors.Errorf("%s script: create command: %w", lifecycle, err)
+		return xerrors.Errorf("%s: create command: %w", desc, err)
 	}
 	cmd := cmdPty.AsExec()
 
-	var stdout, stderr io.Writer = fileWriter, fileWriter
-	if lifecycle == "startup" {
-		send, flushAndClose := agentsdk.LogsSender(a.client.PatchLogs, logger)
-		// If ctx is canceled here (or in a writer below), we may be
-		// discarding logs, but that's okay because we're shutting down
-		// anyway. We could consider creating a new context here if we
-		// want better control over flush during shutdown.
-		defer func() {
-			if err := flushAndClose(ctx); err != nil {
-				logger.Warn(ctx, "flush startup logs failed", slog.Error(err))
-			}
-		}()
+	logSource := codersdk.WorkspaceAgentLogSourceStartupScript
+	switch lifecycle {
+	case "shutdown":
+		logSource = codersdk.WorkspaceAgentLogSourceShutdownScript
+	case "dotfiles":
+		logSource = codersdk.WorkspaceAgentLogSourceDotfiles
+	case "devcontainer":
+		logSource = codersdk.WorkspaceAgentLogSourceDevcontainer
+	}
+	send, flushAndClose := agentsdk.LogsSender(a.client.PatchLogs, logger)
+	// If ctx is canceled here (or in a writer below), we may be
+	// discarding logs, but that's okay because we're shutting down
+	// anyway. We could consider creating a new context here if we
+	// want better control over flush during shutdown.
+	defer func() {
+		flushCtx := ctx
+		if lifecycle == "shutdown" {
+			// The shutdown script runs with a context that is never
+			// canceled, so don't hold up the shutdown if coderd is
+			// unreachable.
+			var cancel context.CancelFunc
+			flushCtx, cancel = context.WithTimeout(ctx, shutdownLogsFlushTimeout)
+			defer cancel()
+		}
+		if err := flushAndClose(flushCtx); err != nil {
+			logger.Warn(ctx, fmt.Sprintf("flush %s logs failed", lifecycle), slog.Error(err))
+		}
+	}()
 
-		infoW := agentsdk.StartupLogsWriter(ctx, send, codersdk.WorkspaceAgentLogSourceStartupScript, codersdk.LogLevelInfo)
-		defer infoW.Close()
-		errW := agentsdk.StartupLogsWriter(ctx, send, codersdk.WorkspaceAgentLogSourceStartupScript, codersdk.LogLevelError)
-		defer errW.Close()
+	infoW := agentsdk.StartupLogsWriter(ctx, send, logSource, codersdk.LogLevelInfo)
+	defer infoW.Close()
+	errW := agentsdk.StartupLogsWriter(ctx, send, logSource, codersdk.LogLevelError)
+	defer errW.Close()
 
-		stdout = io.MultiWriter(fileWriter, infoW)
-		stderr = io.MultiWriter(fileWriter, errW)
-	}
+	stdout := io.MultiWriter(fileWriter, infoW)
+	stderr := io.MultiWriter(fileWriter, errW)
 
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
@@ -1039,16 +1199,11 @@ func (a *agent) runScript(ctx context.Context, lifecycle, script string) (err er
 	defer func() {
 		end := time.Now()
 		execTime := end.Sub(start)
-		exitCode := 0
+		exitCode := scriptExitCode(err)
 		if err != nil {
-			exitCode = 255 // Unknown status.
-			var exitError *exec.ExitError
-			if xerrors.As(err, &exitError) {
-				exitCode = exitError.ExitCode()
-			}
-			logger.Warn(ctx, fmt.Sprintf("%s script failed", lifecycle), slog.F("execution_time", execTime), slog.F("exit_code", exitCode), slog.Error(err))
+			logger.Warn(ctx, fmt.Sprintf("%s failed", desc), slog.F("execution_time", execTime), slog.F("exit_code", exitCode), slog.Error(err))
 		} else {
-			logger.Info(ctx, fmt.Sprintf("%s script completed", lifecycle), slog.F("execution_time", execTime), slog.F("exit_code", exitCode))
+			logger.Info(ctx, fmt.Sprintf("%s completed", desc), slog.F("execution_time", execTime), slog.F("exit_code", exitCode))
 		}
 	}()
 
@@ -1058,12 +1213,27 @@ func (a *agent) runScript(ctx context.Context, lifecycle, script string) (err er
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
+		if cmdCtx.Err() != nil {
+			return xerrors.Errorf("%s: timed out after %s: %w", desc, timeout, cmdCtx.Err())
+		}
 
-		return xerrors.Errorf("%s script: run: %w", lifecycle, err)
+		return xerrors.Errorf("%s: run: %w", desc, err)
 	}
 	return nil
 }
 
+// scriptExitCode returns the exit code of a script that returned err.
+func scriptExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitError *exec.ExitError
+	if xerrors.As(err, &exitError) {
+		return exitError.ExitCode()
+	}
+	return 255 // Unknown status.
+}
+
 func (a *agent) handleReconnectingPTY(ctx context.Context, logger slog.Logger, msg codersdk.WorkspaceAgentReconnectingPTYInit, conn net.Conn) (retErr error) {
 	defer conn.Close()
 	a.metrics.connectionsTotal.Add(1)
@@ -1189,12 +1359,17 @@ func (a *agent) startReportingConnectionStats(ctx context.Context) {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				duration, _, _, err := a.network.Ping(pingCtx, addresses[0].Addr())
+				duration, p2p, _, err := a.network.Ping(pingCtx, addresses[0].Addr())
 				if err != nil {
 					return
 				}
 				mu.Lock()
 				durations = append(durations, float64(duration.Microseconds()))
+				if p2p {
+					stats.PeerCountP2P++
+				} else {
+					stats.PeerCountDERP++
+				}
 				mu.Unlock()
 			}()
 		}
@@ -1323,7 +1498,48 @@ func (a *agent) Close() error {
 
 	ctx := context.Background()
 	a.logger.Info(ctx, "shutting down agent")
-	a.setLifecycle(ctx, codersdk.WorkspaceAgentLifecycleShuttingDown)
+	lifecycleState := a.shutdown(ctx)
+
+	// Wait for the lifecycle to be reported, but don't wait forever so
+	// that we don't break user expectations.
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+lifecycleWaitLoop:
+	for {
+		select {
+		case <-ctx.Done():
+			break lifecycleWaitLoop
+		case s := <-a.lifecycleReported:
+			if s == lifecycleState {
+				break lifecycleWaitLoop
+			}
+		}
+	}
+
+	close(a.closed)
+	a.closeCancel()
+	_ = a.sshServer.Close()
+	if a.network != nil {
+		_ = a.network.Close()
+	}
+	a.connCloseWait.Wait()
+
+	return nil
+}
+
+// shutdown runs the shutdown script and sets the final lifecycle state, which
+// is returned. It only runs once, whether the agent is drained or closed.
+func (a *agent) shutdown(ctx context.Context) codersdk.WorkspaceAgentLifecycle {
+	a.shutdownOnce.Do(func() {
+		a.shutdownState = a.runShutdown(ctx)
+	})
+	return a.shutdownState
+}
+
+func (a *agent) runShutdown(ctx context.Context) codersdk.WorkspaceAgentLifecycle {
+	if a.lifecycle() != codersdk.WorkspaceAgentLifecycleShuttingDown {
+		a.setLifecycle(ctx, codersdk.WorkspaceAgentLifecycleShuttingDown)
+	}
 
 	// Attempt to gracefully shut down all active SSH connections and
 	// stop accepting new ones.
@@ -1361,36 +1577,17 @@ func (a *agent) Close() error {
 			lifecycleState = codersdk.WorkspaceAgentLifecycleShutdownError
 		}
 	}
-
-	// Set final state and wait for it to be reported because context
-	// cancellation will stop the report loop.
-	a.setLifecycle(ctx, lifecycleState)
-
-	// Wait for the lifecycle to be reported, but don't wait forever so
-	// that we don't break user expectations.
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-lifecycleWaitLoop:
-	for {
-		select {
-		case <-ctx.Done():
-			break lifecycleWaitLoop
-		case s := <-a.lifecycleReported:
-			if s == lifecycleState {
-				break lifecycleWaitLoop
-			}
+	if manifest := a.manifest.Load(); manifest != nil {
+		err := a.runScripts(ctx, "shutdown", manifest.Scripts)
+		if err != nil {
+			lifecycleState = codersdk.WorkspaceAgentLifecycleShutdownError
 		}
 	}
 
-	close(a.closed)
-	a.closeCancel()
-	_ = a.sshServer.Close()
-	if a.network != nil {
-		_ = a.network.Close()
-	}
-	a.connCloseWait.Wait()
-
-	return nil
+	// Set final state, callers must wait for it to be reported because
+	// context cancellation will stop the report loop.
+	a.setLifecycle(ctx, lifecycleState)
+	return lifecycleState
 }
 
 // userHomeDir returns the home directory of the current user, giving
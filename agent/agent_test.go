@@ -1,8 +1,10 @@
 package agent_test
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -84,7 +86,8 @@ func TestAgent_Stats_SSH(t *testing.T) {
 	require.Eventuallyf(t, func() bool {
 		var ok bool
 		s, ok = <-stats
-		return ok && s.ConnectionCount > 0 && s.RxBytes > 0 && s.TxBytes > 0 && s.SessionCountSSH == 1
+		return ok && s.ConnectionCount > 0 && s.RxBytes > 0 && s.TxBytes > 0 && s.SessionCountSSH == 1 &&
+			s.PeerCountP2P+s.PeerCountDERP == 1
 	}, testutil.WaitLong, testutil.IntervalFast,
 		"never saw stats: %+v", s,
 	)
@@ -93,6 +96,144 @@ func TestAgent_Stats_SSH(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestAgent_DebugLogs(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	logDir := "/var/log/coder"
+	//nolint:dogsled
+	conn, _, _, fs, _ := setupAgent(t, agentsdk.Manifest{}, 0, func(_ *agenttest.Client, o *agent.Options) {
+		o.LogDir = logDir
+	})
+	err := afero.WriteFile(fs, filepath.Join(logDir, "coder-agent.log"), []byte("agent started\n"), 0o600)
+	require.NoError(t, err)
+	large := strings.Repeat("a", 2<<20) + "end"
+	err = afero.WriteFile(fs, filepath.Join(logDir, "coder-startup-script.log"), []byte(large), 0o600)
+	require.NoError(t, err)
+
+	logs, err := conn.DebugLogs(ctx)
+	require.NoError(t, err)
+	require.Len(t, logs.Files, 2)
+	require.Equal(t, "coder-agent.log", logs.Files[0].Name)
+	require.Equal(t, "agent started\n", logs.Files[0].Content)
+	require.False(t, logs.Files[0].Truncated)
+	require.Equal(t, "coder-startup-script.log", logs.Files[1].Name)
+	require.True(t, logs.Files[1].Truncated)
+	require.Len(t, logs.Files[1].Content, 1<<20)
+	require.True(t, strings.HasSuffix(logs.Files[1].Content, "end"))
+}
+
+func TestAgent_Files(t *testing.T) {
+	t.Parallel()
+
+	t.Run("UploadDownload", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		dir := "/home/coder"
+		//nolint:dogsled
+		conn, _, _, fs, _ := setupAgent(t, agentsdk.Manifest{Directory: dir}, 0)
+		err := fs.MkdirAll(dir, 0o755)
+		require.NoError(t, err)
+
+		info, err := conn.UploadFile(ctx, "notes.txt", strings.NewReader("hello"), codersdk.WorkspaceAgentUploadFileOptions{
+			Mode: 0o600,
+		})
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(dir, "notes.txt"), info.Path)
+		require.EqualValues(t, 5, info.Size)
+		require.EqualValues(t, 0o600, info.Mode)
+
+		// Resume the upload.
+		_, err = conn.UploadFile(ctx, "notes.txt", strings.NewReader(" world"), codersdk.WorkspaceAgentUploadFileOptions{
+			Offset: 4,
+		})
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusConflict, sdkErr.StatusCode())
+		info, err = conn.UploadFile(ctx, "notes.txt", strings.NewReader(" world"), codersdk.WorkspaceAgentUploadFileOptions{
+			Offset: 5,
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 11, info.Size)
+		require.EqualValues(t, 0o600, info.Mode)
+
+		body, err := conn.DownloadFile(ctx, filepath.Join(dir, "notes.txt"), 0)
+		require.NoError(t, err)
+		content, err := io.ReadAll(body)
+		_ = body.Close()
+		require.NoError(t, err)
+		require.Equal(t, "hello world", string(content))
+
+		// Resume the download.
+		body, err = conn.DownloadFile(ctx, "notes.txt", 6)
+		require.NoError(t, err)
+		content, err = io.ReadAll(body)
+		_ = body.Close()
+		require.NoError(t, err)
+		require.Equal(t, "world", string(content))
+
+		// Resuming the upload of a missing file doesn't create it.
+		_, err = conn.UploadFile(ctx, "missing.txt", strings.NewReader("world"), codersdk.WorkspaceAgentUploadFileOptions{
+			Offset: 6,
+		})
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusConflict, sdkErr.StatusCode())
+		_, err = fs.Stat(filepath.Join(dir, "missing.txt"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		//nolint:dogsled
+		conn, _, _, fs, _ := setupAgent(t, agentsdk.Manifest{}, 0)
+		err := fs.MkdirAll("/data", 0o755)
+		require.NoError(t, err)
+
+		info, err := conn.StatFile(ctx, "/data")
+		require.NoError(t, err)
+		require.True(t, info.IsDir)
+
+		_, err = conn.StatFile(ctx, "/data/missing")
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+
+		_, err = conn.DownloadFile(ctx, "/data", 0)
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode())
+	})
+}
+
+func TestAgent_ConnStats(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	//nolint:dogsled
+	conn, _, _, _, _ := setupAgent(t, agentsdk.Manifest{}, 0)
+	require.Zero(t, conn.Stats().Latency)
+
+	// Pings don't go through WireGuard, so send some traffic too.
+	sshClient, err := conn.SSHClient(ctx)
+	require.NoError(t, err)
+	defer sshClient.Close()
+	latency, p2p, _, err := conn.Ping(ctx)
+	require.NoError(t, err)
+
+	stats := conn.Stats()
+	require.Equal(t, latency, stats.Latency)
+	require.Greater(t, stats.RxBytes, int64(0))
+	require.Greater(t, stats.TxBytes, int64(0))
+	require.False(t, stats.LastHandshake.IsZero())
+	if p2p {
+		require.True(t, stats.P2P)
+		require.NotEmpty(t, stats.Endpoint)
+	}
+	require.NotZero(t, stats.DERPRegionID)
+}
+
 func TestAgent_Stats_ReconnectingPTY(t *testing.T) {
 	t.Parallel()
 
@@ -215,6 +356,72 @@ func TestAgent_GitSSH(t *testing.T) {
 	require.True(t, strings.HasSuffix(strings.TrimSpace(string(output)), "gitssh --"))
 }
 
+func TestAgent_GitCredentials(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("The test reads the socket path with sh.")
+	}
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	var calls atomic.Int64
+	//nolint:dogsled
+	conn, _, _, _, _ := setupAgent(t, agentsdk.Manifest{}, 0, func(c *agenttest.Client, _ *agent.Options) {
+		c.GitAuthFunc = func(_ string, _ bool) (agentsdk.GitAuthResponse, error) {
+			calls.Add(1)
+			return agentsdk.GitAuthResponse{
+				Username: "oauth2",
+				Password: fmt.Sprintf("token-%d", calls.Load()),
+				Expiry:   time.Now().Add(time.Hour),
+			}, nil
+		}
+	})
+	sshClient, err := conn.SSHClient(ctx)
+	require.NoError(t, err)
+	defer sshClient.Close()
+	session, err := sshClient.NewSession()
+	require.NoError(t, err)
+	defer session.Close()
+	output, err := session.Output("sh -c 'echo $" + agent.EnvGitCredentialsSocket + "'")
+	require.NoError(t, err)
+	socket := strings.TrimSpace(string(output))
+	require.NotEmpty(t, socket)
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	get := func() agentsdk.GitAuthResponse {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://agent/?url=https%3A%2F%2Fgitlab.com", nil)
+		require.NoError(t, err)
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		var token agentsdk.GitAuthResponse
+		err = json.NewDecoder(res.Body).Decode(&token)
+		require.NoError(t, err)
+		return token
+	}
+
+	// Tokens are cached until they're about to expire.
+	require.Equal(t, "token-1", get().Password)
+	require.Equal(t, "token-1", get().Password)
+	require.EqualValues(t, 1, calls.Load())
+
+	// Erased tokens are fetched again.
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, "http://agent/?url=https%3A%2F%2Fgitlab.com", nil)
+	require.NoError(t, err)
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+	require.Equal(t, "token-2", get().Password)
+}
+
 func TestAgent_SessionTTYShell(t *testing.T) {
 	t.Parallel()
 	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
@@ -950,6 +1157,108 @@ func TestAgent_SFTP(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestAgent_SFTPLargeFile(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	//nolint:dogsled
+	conn, _, _, _, _ := setupAgent(t, agentsdk.Manifest{}, 0)
+	sshClient, err := conn.SSHClient(ctx)
+	require.NoError(t, err)
+	defer sshClient.Close()
+	// Concurrent writes and reads are what clients like IDE plugins use
+	// for large files, so make sure they're enabled.
+	client, err := sftp.NewClient(sshClient, sftp.UseConcurrentWrites(true), sftp.UseConcurrentReads(true))
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Larger than the maximum packet size and the concurrent request window.
+	content := make([]byte, 16<<20)
+	_, err = rand.Read(content)
+	require.NoError(t, err)
+	tempFile := filepath.Join(t.TempDir(), "large")
+	remoteFile := filepath.ToSlash(tempFile)
+	if !path.IsAbs(remoteFile) {
+		remoteFile = path.Join("/", remoteFile)
+	}
+
+	file, err := client.Create(remoteFile)
+	require.NoError(t, err)
+	n, err := file.ReadFrom(bytes.NewReader(content))
+	require.NoError(t, err)
+	require.EqualValues(t, len(content), n)
+	err = file.Close()
+	require.NoError(t, err)
+	written, err := os.ReadFile(tempFile)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(content, written), "uploaded content differs")
+
+	file, err = client.Open(remoteFile)
+	require.NoError(t, err)
+	defer file.Close()
+	var downloaded bytes.Buffer
+	_, err = file.WriteTo(&downloaded)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(content, downloaded.Bytes()), "downloaded content differs")
+}
+
+func TestAgent_SFTPPreservesPermissions(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows doesn't support POSIX permissions")
+	}
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	//nolint:dogsled
+	conn, _, _, _, _ := setupAgent(t, agentsdk.Manifest{}, 0)
+	sshClient, err := conn.SSHClient(ctx)
+	require.NoError(t, err)
+	defer sshClient.Close()
+	client, err := sftp.NewClient(sshClient)
+	require.NoError(t, err)
+	defer client.Close()
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.sh")
+	mtime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// This is what "sftp put -p" and "scp -p" do to preserve the mode and
+	// times of the uploaded file.
+	file, err := client.Create(script)
+	require.NoError(t, err)
+	_, err = file.Write([]byte("#!/bin/sh\necho hello\n"))
+	require.NoError(t, err)
+	err = file.Chmod(0o750)
+	require.NoError(t, err)
+	err = file.Close()
+	require.NoError(t, err)
+	err = client.Chtimes(script, mtime, mtime)
+	require.NoError(t, err)
+
+	info, err := os.Stat(script)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o750), info.Mode().Perm())
+	require.True(t, info.ModTime().Equal(mtime), "mtime %s should be %s", info.ModTime(), mtime)
+
+	// Downloads see the mode and times of the file in the workspace.
+	err = os.Chmod(script, 0o700)
+	require.NoError(t, err)
+	remoteInfo, err := client.Stat(script)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o700), remoteInfo.Mode().Perm())
+	require.True(t, remoteInfo.ModTime().Equal(mtime), "mtime %s should be %s", remoteInfo.ModTime(), mtime)
+
+	sub := filepath.Join(dir, "sub")
+	err = client.Mkdir(sub)
+	require.NoError(t, err)
+	err = client.Chmod(sub, 0o700)
+	require.NoError(t, err)
+	info, err = os.Stat(sub)
+	require.NoError(t, err)
+	require.True(t, info.IsDir())
+	require.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+}
+
 func TestAgent_SCP(t *testing.T) {
 	t.Parallel()
 
@@ -1133,6 +1442,198 @@ func TestAgent_StartupScript(t *testing.T) {
 	})
 }
 
+func TestAgent_Dotfiles(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("the dotfiles commands use sh")
+	}
+	dotfiles := &codersdk.UserDotfiles{
+		RepoURL: "https://github.com/example/dotfiles.git",
+		Branch:  "main",
+	}
+
+	run := func(t *testing.T, command string, timeout time.Duration) *agenttest.Client {
+		t.Helper()
+		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+		client := agenttest.NewClient(t,
+			logger,
+			uuid.New(),
+			agentsdk.Manifest{
+				DERPMap:  &tailcfg.DERPMap{},
+				Dotfiles: dotfiles,
+			},
+			make(chan *agentsdk.Stats),
+			tailnet.NewCoordinator(logger),
+		)
+		closer := agent.New(agent.Options{
+			Client:          client,
+			Filesystem:      afero.NewMemMapFs(),
+			Logger:          logger.Named("agent"),
+			DotfilesCommand: command,
+			DotfilesTimeout: timeout,
+		})
+		t.Cleanup(func() {
+			_ = closer.Close()
+		})
+		// Failing to apply dotfiles doesn't fail the startup.
+		assert.Eventually(t, func() bool {
+			got := client.GetLifecycleStates()
+			return len(got) > 0 && got[len(got)-1] == codersdk.WorkspaceAgentLifecycleReady
+		}, testutil.WaitShort, testutil.IntervalMedium)
+		return client
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		client := run(t, "echo", 0)
+		logs := client.GetStartupLogs()
+		require.Len(t, logs, 1)
+		require.Equal(t, "dotfiles --yes --branch main https://github.com/example/dotfiles.git", logs[0].Output)
+		require.Equal(t, codersdk.WorkspaceAgentLogSourceDotfiles, logs[0].Source)
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		t.Parallel()
+		client := run(t, "false", 0)
+		logs := client.GetStartupLogs()
+		require.Len(t, logs, 1)
+		require.Contains(t, logs[0].Output, "Failed to apply dotfiles from https://github.com/example/dotfiles.git")
+		require.Equal(t, codersdk.LogLevelError, logs[0].Level)
+		require.Equal(t, codersdk.WorkspaceAgentLogSourceDotfiles, logs[0].Source)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		t.Parallel()
+		// The dotfiles arguments are commented out.
+		client := run(t, "sleep 30 #", time.Second)
+		logs := client.GetStartupLogs()
+		require.Len(t, logs, 1)
+		require.Contains(t, logs[0].Output, "timed out")
+		require.Equal(t, codersdk.LogLevelError, logs[0].Level)
+	})
+}
+
+func TestAgent_Devcontainer(t *testing.T) {
+	t.Parallel()
+	if runtime.GOOS == "windows" {
+		t.Skip("devcontainers are not supported on Windows")
+	}
+
+	t.Run("Apply", func(t *testing.T) {
+		t.Parallel()
+
+		folder := t.TempDir()
+		writeFile := func(name, content string) {
+			t.Helper()
+			name = filepath.Join(folder, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+			require.NoError(t, os.WriteFile(name, []byte(content), 0o600))
+		}
+		writeFile(".devcontainer/devcontainer.json", `{
+		// The image is ignored, since the workspace is the container.
+		"image": "mcr.microsoft.com/devcontainers/base:ubuntu",
+		"features": {
+			"./greeter": {
+				"greeting": "hello", /* The name keeps its default. */
+			},
+		},
+		"postCreateCommand": ["sh", "-c", "echo created in $(basename $(pwd))"],
+		"postStartCommand": {
+			"b": "echo started b",
+			"a": "echo started a",
+		},
+		"forwardPorts": [3000, "localhost:8080"],
+	}`)
+		writeFile(".devcontainer/greeter/devcontainer-feature.json", `{
+		"id": "greeter",
+		"options": {
+			"greeting": {"type": "string", "default": "hi"},
+			"name": {"type": "string", "default": "world"}
+		}
+	}`)
+		writeFile(".devcontainer/greeter/install.sh", "#!/bin/sh\necho \"$GREETING $NAME\"\n")
+
+		//nolint:dogsled
+		conn, client, _, _, _ := setupAgent(t, agentsdk.Manifest{
+			Devcontainers: []codersdk.WorkspaceAgentDevcontainer{{
+				WorkspaceFolder: folder,
+			}},
+		}, 0, func(_ *agenttest.Client, opts *agent.Options) {
+			opts.Filesystem = afero.NewOsFs()
+		})
+		require.Eventually(t, func() bool {
+			got := client.GetLifecycleStates()
+			return len(got) > 0 && got[len(got)-1] == codersdk.WorkspaceAgentLifecycleReady
+		}, testutil.WaitShort, testutil.IntervalMedium)
+
+		var outputs []string
+		for _, log := range client.GetStartupLogs() {
+			require.Equal(t, codersdk.WorkspaceAgentLogSourceDevcontainer, log.Source)
+			outputs = append(outputs, log.Output)
+		}
+		require.Equal(t, []string{
+			"hello world",
+			"created in " + filepath.Base(folder),
+			"started a",
+			"started b",
+		}, outputs)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		res, err := conn.ListeningPorts(ctx)
+		require.NoError(t, err)
+		var ports []uint16
+		for _, port := range res.Ports {
+			ports = append(ports, port.Port)
+		}
+		require.Contains(t, ports, uint16(3000))
+		require.Contains(t, ports, uint16(8080))
+
+		// The postCreateCommand only runs in the first start of the
+		// container.
+		require.FileExists(t, filepath.Join(folder, ".coder-devcontainer-created"))
+		//nolint:dogsled
+		_, client, _, _, _ = setupAgent(t, agentsdk.Manifest{
+			Devcontainers: []codersdk.WorkspaceAgentDevcontainer{{
+				WorkspaceFolder: folder,
+			}},
+		}, 0, func(_ *agenttest.Client, opts *agent.Options) {
+			opts.Filesystem = afero.NewOsFs()
+		})
+		require.Eventually(t, func() bool {
+			got := client.GetLifecycleStates()
+			return len(got) > 0 && got[len(got)-1] == codersdk.WorkspaceAgentLifecycleReady
+		}, testutil.WaitShort, testutil.IntervalMedium)
+
+		outputs = nil
+		for _, log := range client.GetStartupLogs() {
+			outputs = append(outputs, log.Output)
+		}
+		require.Equal(t, []string{
+			"hello world",
+			"started a",
+			"started b",
+		}, outputs)
+	})
+
+	t.Run("MissingConfig", func(t *testing.T) {
+		t.Parallel()
+		//nolint:dogsled
+		_, client, _, _, _ := setupAgent(t, agentsdk.Manifest{
+			Devcontainers: []codersdk.WorkspaceAgentDevcontainer{{
+				WorkspaceFolder: t.TempDir(),
+			}},
+		}, 0)
+		require.Eventually(t, func() bool {
+			got := client.GetLifecycleStates()
+			return len(got) > 0 && got[len(got)-1] == codersdk.WorkspaceAgentLifecycleStartError
+		}, testutil.WaitShort, testutil.IntervalMedium)
+		logs := client.GetStartupLogs()
+		require.Len(t, logs, 1)
+		require.Contains(t, logs[0].Output, "no devcontainer.json found")
+		require.Equal(t, codersdk.LogLevelError, logs[0].Level)
+	})
+}
+
 func TestAgent_Metadata(t *testing.T) {
 	t.Parallel()
 
@@ -1202,6 +1703,91 @@ func TestAgent_Metadata(t *testing.T) {
 			t.Fatalf("expected metadata to be collected again")
 		}
 	})
+
+	t.Run("Plugins", func(t *testing.T) {
+		t.Parallel()
+		pluginDir := filepath.Join(t.TempDir(), "metadata.d")
+		//nolint:dogsled
+		_, client, _, fs, _ := setupAgent(t, agentsdk.Manifest{
+			Metadata: []codersdk.WorkspaceAgentMetadataDescription{
+				{
+					Key:    "greeting",
+					Script: echoHello,
+				},
+			},
+		}, 0, func(_ *agenttest.Client, opts *agent.Options) {
+			opts.ReportMetadataInterval = testutil.IntervalFast
+			opts.MetadataPluginDir = pluginDir
+			opts.MetadataPluginReloadInterval = testutil.IntervalFast
+			for name, content := range map[string]string{
+				"farewell.json": `{"key": "farewell", "display_name": "Farewell", "script": "echo 'bye'"}`,
+				// Plugins can't replace the metadata of the template.
+				"greeting.json": `{"key": "greeting", "script": "echo 'hijacked'"}`,
+				"invalid.json":  `{"key": "invalid"}`,
+				"README.md":     "not a plugin",
+			} {
+				err := afero.WriteFile(opts.Filesystem, filepath.Join(pluginDir, name), []byte(content), 0o600)
+				require.NoError(t, err)
+			}
+		})
+
+		require.Eventually(t, func() bool {
+			gotMd := client.GetMetadata()
+			return len(gotMd) == 2 && gotMd["farewell"].Value != ""
+		}, testutil.WaitShort, testutil.IntervalFast)
+		gotMd := client.GetMetadata()
+		require.Equal(t, "hello", strings.TrimSpace(gotMd["greeting"].Value))
+		require.Equal(t, "bye", strings.TrimSpace(gotMd["farewell"].Value))
+		require.Equal(t, []codersdk.WorkspaceAgentMetadataDescription{{
+			DisplayName: "Farewell",
+			Key:         "farewell",
+			Script:      "echo 'bye'",
+			Plugin:      true,
+		}}, client.GetMetadataPlugins())
+
+		// Removing a plugin unregisters it.
+		err := fs.Remove(filepath.Join(pluginDir, "farewell.json"))
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return len(client.GetMetadataPlugins()) == 0
+		}, testutil.WaitShort, testutil.IntervalFast)
+	})
+
+	t.Run("PluginsLimit", func(t *testing.T) {
+		t.Parallel()
+		pluginDir := filepath.Join(t.TempDir(), "metadata.d")
+		metadata := make([]codersdk.WorkspaceAgentMetadataDescription, 127)
+		for i := range metadata {
+			metadata[i] = codersdk.WorkspaceAgentMetadataDescription{
+				Key:      fmt.Sprintf("template-%d", i),
+				Script:   echoHello,
+				Interval: 10,
+			}
+		}
+		//nolint:dogsled
+		_, client, _, _, _ := setupAgent(t, agentsdk.Manifest{
+			Metadata: metadata,
+		}, 0, func(_ *agenttest.Client, opts *agent.Options) {
+			opts.ReportMetadataInterval = testutil.IntervalFast
+			opts.MetadataPluginDir = pluginDir
+			opts.MetadataPluginReloadInterval = testutil.IntervalFast
+			for _, name := range []string{"a", "b"} {
+				content := fmt.Sprintf(`{"key": %q, "script": "echo 'bye'"}`, name)
+				err := afero.WriteFile(opts.Filesystem, filepath.Join(pluginDir, name+".json"), []byte(content), 0o600)
+				require.NoError(t, err)
+			}
+		})
+
+		// Only the plugins that fit next to the metadata of the template
+		// are registered, and everything that fits is still collected.
+		require.Eventually(t, func() bool {
+			gotMd := client.GetMetadata()
+			return len(gotMd) == 128 && gotMd["a"].Value != ""
+		}, testutil.WaitShort, testutil.IntervalFast)
+		plugins := client.GetMetadataPlugins()
+		require.Len(t, plugins, 1)
+		require.Equal(t, "a", plugins[0].Key)
+	})
 }
 
 func TestAgentMetadata_Timing(t *testing.T) {
@@ -1465,6 +2051,156 @@ func TestAgent_Lifecycle(t *testing.T) {
 		require.Equal(t, want, got[:len(want)])
 	})
 
+	t.Run("ShutdownScriptLogs", func(t *testing.T) {
+		t.Parallel()
+
+		_, client, _, _, closer := setupAgent(t, agentsdk.Manifest{
+			StartupScript:         "echo started",
+			ShutdownScript:        "echo stopped",
+			ShutdownScriptTimeout: 30 * time.Second,
+		}, 0)
+
+		assert.Eventually(t, func() bool {
+			return slices.Contains(client.GetLifecycleStates(), codersdk.WorkspaceAgentLifecycleReady)
+		}, testutil.WaitShort, testutil.IntervalMedium)
+
+		err := closer.Close()
+		require.NoError(t, err)
+
+		logs := client.GetStartupLogs()
+		require.Len(t, logs, 2)
+		require.Equal(t, "started", logs[0].Output)
+		require.Equal(t, codersdk.WorkspaceAgentLogSourceStartupScript, logs[0].Source)
+		require.Equal(t, "stopped", logs[1].Output)
+		require.Equal(t, codersdk.WorkspaceAgentLogSourceShutdownScript, logs[1].Source)
+	})
+
+	t.Run("Scripts", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS == "windows" {
+			t.Skip("the scripts use sleep")
+		}
+
+		_, client, _, _, closer := setupAgent(t, agentsdk.Manifest{
+			StartupScript: "echo legacy",
+			Scripts: []codersdk.WorkspaceAgentScript{{
+				Name:       "first",
+				Script:     "echo first",
+				RunOnStart: true,
+			}, {
+				Name:            "fails",
+				Script:          "exit 3",
+				RunOnStart:      true,
+				ContinueOnError: true,
+			}, {
+				Name:           "times-out",
+				Script:         "sleep 30",
+				RunOnStart:     true,
+				TimeoutSeconds: 1,
+			}, {
+				Name:       "skipped",
+				Script:     "echo skipped",
+				RunOnStart: true,
+				RunOnStop:  true,
+			}, {
+				Name:      "stop",
+				Script:    "echo stop",
+				RunOnStop: true,
+			}},
+		}, 0)
+
+		require.Eventually(t, func() bool {
+			return slices.Contains(client.GetLifecycleStates(), codersdk.WorkspaceAgentLifecycleStartError)
+		}, testutil.WaitShort, testutil.IntervalMedium)
+
+		status := map[string]agentsdk.PostScriptStatusRequest{}
+		for _, req := range client.GetScriptStatuses() {
+			status[req.Name] = req
+		}
+		require.Len(t, status, 4)
+		require.Equal(t, codersdk.WorkspaceAgentScriptStatusOK, status["first"].Status)
+		require.Equal(t, codersdk.WorkspaceAgentScriptStatusError, status["fails"].Status)
+		require.EqualValues(t, 3, status["fails"].ExitCode)
+		require.Equal(t, codersdk.WorkspaceAgentScriptStatusTimedOut, status["times-out"].Status)
+		require.Equal(t, codersdk.WorkspaceAgentScriptStatusSkipped, status["skipped"].Status)
+
+		var outputs []string
+		for _, log := range client.GetStartupLogs() {
+			outputs = append(outputs, log.Output)
+		}
+		require.Equal(t, []string{"legacy", "first"}, outputs)
+
+		err := closer.Close()
+		require.NoError(t, err)
+
+		// Shutdown scripts are independent of the startup scripts.
+		status = map[string]agentsdk.PostScriptStatusRequest{}
+		for _, req := range client.GetScriptStatuses() {
+			status[req.Name] = req
+		}
+		require.Equal(t, codersdk.WorkspaceAgentScriptStatusOK, status["skipped"].Status)
+		require.Equal(t, codersdk.WorkspaceAgentScriptStatusOK, status["stop"].Status)
+		require.Equal(t, codersdk.WorkspaceAgentLifecycleOff, client.GetLifecycleStates()[len(client.GetLifecycleStates())-1])
+	})
+
+	t.Run("Drain", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		//nolint:dogsled
+		conn, client, _, fs, _ := setupAgent(t, agentsdk.Manifest{
+			ShutdownScript:        "echo drained",
+			ShutdownScriptTimeout: 30 * time.Second,
+		}, 0)
+		require.Eventually(t, func() bool {
+			return slices.Contains(client.GetLifecycleStates(), codersdk.WorkspaceAgentLifecycleReady)
+		}, testutil.WaitShort, testutil.IntervalMedium)
+
+		sshClient, err := conn.SSHClient(ctx)
+		require.NoError(t, err)
+		defer sshClient.Close()
+		session, err := sshClient.NewSession()
+		require.NoError(t, err)
+		defer session.Close()
+		stdin, err := session.StdinPipe()
+		require.NoError(t, err)
+		stdout, err := session.StdoutPipe()
+		require.NoError(t, err)
+		stderr, err := session.StderrPipe()
+		require.NoError(t, err)
+		err = session.Shell()
+		require.NoError(t, err)
+		// Wait for the shell so the session is tracked by the agent.
+		_, err = stdin.Write([]byte("echo ready\n"))
+		require.NoError(t, err)
+		line, err := bufio.NewReader(stdout).ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "ready\n", line)
+
+		err = client.PushDrainRequest(agentsdk.DrainRequest{
+			BuildID:     uuid.New(),
+			Transition:  codersdk.WorkspaceTransitionStop,
+			GracePeriod: testutil.WaitLong,
+		})
+		require.NoError(t, err)
+
+		// Sessions are warned, and the shutdown script waits for them to end.
+		stderrScanner := bufio.NewScanner(stderr)
+		for !strings.Contains(stderrScanner.Text(), "Workspace stop requested") {
+			require.True(t, stderrScanner.Scan(), "never saw drain message")
+		}
+		require.NotContains(t, client.GetLifecycleStates(), codersdk.WorkspaceAgentLifecycleOff)
+		_ = stdin.Close()
+		_ = session.Wait()
+
+		require.Eventually(t, func() bool {
+			return slices.Contains(client.GetLifecycleStates(), codersdk.WorkspaceAgentLifecycleOff)
+		}, testutil.WaitLong, testutil.IntervalMedium)
+		content, err := afero.ReadFile(fs, filepath.Join(os.TempDir(), "coder-shutdown-script.log"))
+		require.NoError(t, err)
+		require.Equal(t, "drained", strings.TrimSpace(string(content)))
+	})
+
 	t.Run("ShutdownScriptOnce", func(t *testing.T) {
 		t.Parallel()
 		logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
@@ -1911,6 +2647,40 @@ func TestAgent_Speedtest(t *testing.T) {
 	t.Logf("%.2f MBits/s", res[len(res)-1].MBitsPerSecond())
 }
 
+func TestAgent_SOCKS5(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	local, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer local.Close()
+	go func() {
+		conn, err := local.Accept()
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.Copy(conn, conn)
+	}()
+
+	//nolint:dogsled
+	conn, _, _, _, _ := setupAgent(t, agentsdk.Manifest{}, 0)
+	defer conn.Close()
+
+	// The address is dialed by the agent, so it doesn't have to be a port
+	// on the agent's tailnet address.
+	remote, err := conn.SOCKS5Dialer().DialContext(ctx, "tcp", local.Addr().String())
+	require.NoError(t, err)
+	defer remote.Close()
+
+	_, err = remote.Write([]byte("test"))
+	require.NoError(t, err)
+	b := make([]byte, 4)
+	_, err = io.ReadFull(remote, b)
+	require.NoError(t, err)
+	require.Equal(t, "test", string(b))
+}
+
 func TestAgent_Reconnect(t *testing.T) {
 	t.Parallel()
 	logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
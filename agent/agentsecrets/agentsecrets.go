@@ -0,0 +1,126 @@
+// Package agentsecrets resolves references to secrets in external secret
+// managers, like HashiCorp Vault and AWS Secrets Manager. A reference has the
+// form "secret://<backend>/<path>#<key>", for example
+// "secret://vault/kv/team/db#password". Secrets are fetched with the identity
+// of the workspace, e.g. its Kubernetes service account or IAM role, so the
+// secret manager decides which secrets a workspace can read.
+package agentsecrets
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// Scheme is the URL scheme of secret references.
+const Scheme = "secret"
+
+// referenceRegex matches references in text like scripts. The characters of
+// the path are limited so a reference ends at whitespace, quotes and shell
+// operators, and at "=" and "," so references can be used in lists of
+// key=value pairs like tags. Query parameters aren't matched for the same
+// reason.
+var referenceRegex = regexp.MustCompile(`secret://[a-z0-9-]+/[A-Za-z0-9_.\-/:@+%#]+`)
+
+// Reference is a parsed secret reference.
+type Reference struct {
+	// Backend is the name of the backend the secret is stored in, e.g.
+	// "vault" or "aws".
+	Backend string
+	// Path identifies the secret in the backend, without a leading slash.
+	Path string
+	// Key selects a field of a secret that holds multiple values. It may be
+	// empty.
+	Key string
+	// Query holds backend specific options, e.g. the AWS region.
+	Query url.Values
+}
+
+// ParseReference parses a reference of the form
+// "secret://<backend>/<path>#<key>".
+func ParseReference(s string) (Reference, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return Reference{}, xerrors.Errorf("parse secret reference: %w", err)
+	}
+	if u.Scheme != Scheme {
+		return Reference{}, xerrors.Errorf("secret reference %q must use the %s:// scheme", s, Scheme)
+	}
+	ref := Reference{
+		Backend: u.Host,
+		Path:    strings.TrimPrefix(u.Path, "/"),
+		Key:     u.Fragment,
+		Query:   u.Query(),
+	}
+	if ref.Backend == "" {
+		return Reference{}, xerrors.Errorf("secret reference %q has no backend", s)
+	}
+	if ref.Path == "" {
+		return Reference{}, xerrors.Errorf("secret reference %q has no path", s)
+	}
+	return ref, nil
+}
+
+// Backend fetches secrets from an external secret manager.
+type Backend interface {
+	// Secret returns the value of the referenced secret.
+	Secret(ctx context.Context, ref Reference) (string, error)
+}
+
+// Resolver resolves secret references with the backends they name.
+type Resolver struct {
+	backends map[string]Backend
+}
+
+// NewResolver returns a Resolver for the given backends, keyed by the name
+// used in references.
+func NewResolver(backends map[string]Backend) *Resolver {
+	return &Resolver{backends: backends}
+}
+
+// Resolve returns the value of the secret reference s.
+func (r *Resolver) Resolve(ctx context.Context, s string) (string, error) {
+	ref, err := ParseReference(s)
+	if err != nil {
+		return "", err
+	}
+	backend, ok := r.backends[ref.Backend]
+	if !ok {
+		return "", xerrors.Errorf("secret backend %q is not configured", ref.Backend)
+	}
+	value, err := backend.Secret(ctx, ref)
+	if err != nil {
+		return "", xerrors.Errorf("fetch secret %q: %w", s, err)
+	}
+	return value, nil
+}
+
+// Expand replaces the secret references in s with the values of the secrets.
+// Each secret is only fetched once. References that can't be resolved are
+// left as they are and reported in the returned error.
+func (r *Resolver) Expand(ctx context.Context, s string) (string, error) {
+	values := map[string]string{}
+	var errs []error
+	expanded := referenceRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		if value, ok := values[ref]; ok {
+			return value
+		}
+		value, err := r.Resolve(ctx, ref)
+		if err != nil {
+			errs = append(errs, err)
+			value = ref
+		}
+		values[ref] = value
+		return value
+	})
+	return expanded, errors.Join(errs...)
+}
+
+// ContainsReference reports whether s contains a secret reference.
+func ContainsReference(s string) bool {
+	return referenceRegex.MatchString(s)
+}
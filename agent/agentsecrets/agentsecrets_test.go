@@ -0,0 +1,191 @@
+package agentsecrets_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/agent/agentsecrets"
+	"github.com/coder/coder/testutil"
+)
+
+func TestParseReference(t *testing.T) {
+	t.Parallel()
+
+	ref, err := agentsecrets.ParseReference("secret://aws/prod/db?region=eu-west-1#password")
+	require.NoError(t, err)
+	require.Equal(t, "aws", ref.Backend)
+	require.Equal(t, "prod/db", ref.Path)
+	require.Equal(t, "password", ref.Key)
+	require.Equal(t, "eu-west-1", ref.Query.Get("region"))
+
+	for _, s := range []string{
+		"https://vault/kv/db#password",
+		"secret:///kv/db",
+		"secret://vault",
+		"secret://vault/",
+	} {
+		_, err := agentsecrets.ParseReference(s)
+		require.Error(t, err, s)
+	}
+}
+
+type fakeBackend struct {
+	secrets map[string]string
+	calls   atomic.Int64
+}
+
+func (f *fakeBackend) Secret(_ context.Context, ref agentsecrets.Reference) (string, error) {
+	f.calls.Add(1)
+	value, ok := f.secrets[ref.Path+"#"+ref.Key]
+	if !ok {
+		return "", xerrors.New("not found")
+	}
+	return value, nil
+}
+
+func TestResolverExpand(t *testing.T) {
+	t.Parallel()
+
+	backend := &fakeBackend{secrets: map[string]string{
+		"kv/db#password": "hunter2",
+		"kv/api#":        "token",
+	}}
+	resolver := agentsecrets.NewResolver(map[string]agentsecrets.Backend{"vault": backend})
+	ctx := testutil.Context(t, testutil.WaitShort)
+
+	expanded, err := resolver.Expand(ctx, `psql "password=secret://vault/kv/db#password" && echo secret://vault/kv/db#password secret://vault/kv/api`)
+	require.NoError(t, err)
+	require.Equal(t, `psql "password=hunter2" && echo hunter2 token`, expanded)
+	require.EqualValues(t, 2, backend.calls.Load())
+
+	// References that can't be resolved are left as they are.
+	expanded, err = resolver.Expand(ctx, "secret://vault/kv/missing#key secret://aws/db secret://vault/kv/api")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `secret backend "aws" is not configured`)
+	require.Equal(t, "secret://vault/kv/missing#key secret://aws/db token", expanded)
+
+	// References end at "=" and ",", so they can be used in key=value lists.
+	expanded, err = resolver.Expand(ctx, "env=secret://vault/kv/api,password=secret://vault/kv/db#password")
+	require.NoError(t, err)
+	require.Equal(t, "env=token,password=hunter2", expanded)
+
+	require.True(t, agentsecrets.ContainsReference("X=secret://vault/kv/db#password"))
+	require.False(t, agentsecrets.ContainsReference("echo hello"))
+}
+
+func TestVault(t *testing.T) {
+	t.Parallel()
+
+	var logins atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			var req map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req["role"] != "workspace" || req["jwt"] != "service-account-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			logins.Add(1)
+			_, _ = w.Write([]byte(`{"auth":{"client_token":"vault-token","lease_duration":3600}}`))
+		case "/v1/kv/data/team/db":
+			if r.Header.Get("X-Vault-Token") != "vault-token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"hunter2","port":5432}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	jwtFile := filepath.Join(t.TempDir(), "token")
+	err := os.WriteFile(jwtFile, []byte("service-account-token\n"), 0o600)
+	require.NoError(t, err)
+	resolver := agentsecrets.NewResolver(map[string]agentsecrets.Backend{
+		"vault": agentsecrets.NewVault(agentsecrets.VaultOptions{
+			Address:  srv.URL,
+			AuthRole: "workspace",
+			JWTFile:  jwtFile,
+		}),
+	})
+	ctx := testutil.Context(t, testutil.WaitShort)
+
+	value, err := resolver.Resolve(ctx, "secret://vault/kv/team/db#password")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", value)
+	_, err = resolver.Resolve(ctx, "secret://vault/kv/team/db#password")
+	require.NoError(t, err)
+	require.EqualValues(t, 1, logins.Load())
+
+	_, err = resolver.Resolve(ctx, "secret://vault/kv/team/db")
+	require.ErrorContains(t, err, "must select a key")
+	_, err = resolver.Resolve(ctx, "secret://vault/kv/team/db#port")
+	require.ErrorContains(t, err, "not a string")
+	_, err = resolver.Resolve(ctx, "secret://vault/kv/team/other#password")
+	require.ErrorContains(t, err, "404")
+}
+
+func TestAWS(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.Contains(r.Header.Get("Authorization"), "/us-west-2/secretsmanager/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var req struct {
+			SecretId string //nolint:revive,stylecheck // AWS field name.
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.SecretId {
+		case "prod/db":
+			_, _ = w.Write([]byte(`{"SecretString":"{\"password\":\"hunter2\"}"}`))
+		case "prod/token":
+			_, _ = w.Write([]byte(`{"SecretString":"token"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	backend, err := agentsecrets.NewAWS(ctx, agentsecrets.AWSOptions{
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+		}),
+		Region:   "us-east-1",
+		Endpoint: srv.URL,
+	})
+	require.NoError(t, err)
+	resolver := agentsecrets.NewResolver(map[string]agentsecrets.Backend{"aws": backend})
+
+	value, err := resolver.Resolve(ctx, "secret://aws/prod/db?region=us-west-2#password")
+	require.NoError(t, err)
+	require.Equal(t, "hunter2", value)
+	// The region of an ARN is used if no region is set.
+	_, err = resolver.Resolve(ctx, "secret://aws/arn:aws:secretsmanager:us-west-2:123456789012:secret:prod/token")
+	require.ErrorContains(t, err, "ResourceNotFoundException")
+	value, err = resolver.Resolve(ctx, "secret://aws/prod/token?region=us-west-2")
+	require.NoError(t, err)
+	require.Equal(t, "token", value)
+	_, err = resolver.Resolve(ctx, "secret://aws/prod/token?region=us-west-2#password")
+	require.ErrorContains(t, err, "not a JSON object")
+	// The default region is used otherwise.
+	_, err = resolver.Resolve(ctx, "secret://aws/prod/token")
+	require.ErrorContains(t, err, "400")
+}
@@ -0,0 +1,150 @@
+package agentsecrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"golang.org/x/xerrors"
+)
+
+type AWSOptions struct {
+	// Credentials default to the default credential chain of the AWS SDK,
+	// e.g. the IAM role of the instance or pod the workspace runs in.
+	Credentials aws.CredentialsProvider
+	// Region is used for secrets that don't set a region. Defaults to the
+	// region of the AWS SDK configuration.
+	Region string
+	// Endpoint selects a Secrets Manager compatible service other than AWS.
+	Endpoint   string
+	HTTPClient *http.Client
+}
+
+// AWS reads secrets from AWS Secrets Manager. References have the form
+// "secret://aws/<name or ARN>#<key>". The key selects a field of a secret
+// that holds a JSON object, and may be omitted to use the whole secret. The
+// region query parameter selects the region of a secret referenced by name.
+// References expanded in text can't have query parameters, so they use the
+// region of the ARN or the default region.
+type AWS struct {
+	opts   AWSOptions
+	signer *v4.Signer
+}
+
+func NewAWS(ctx context.Context, opts AWSOptions) (*AWS, error) {
+	if opts.Credentials == nil || opts.Region == "" {
+		cfg, err := awsconfig.LoadDefaultConfig(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("load AWS config: %w", err)
+		}
+		if opts.Credentials == nil {
+			opts.Credentials = cfg.Credentials
+		}
+		if opts.Region == "" {
+			opts.Region = cfg.Region
+		}
+	}
+	opts.Endpoint = strings.TrimSuffix(opts.Endpoint, "/")
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &AWS{opts: opts, signer: v4.NewSigner()}, nil
+}
+
+func (a *AWS) Secret(ctx context.Context, ref Reference) (string, error) {
+	region := ref.Query.Get("region")
+	if region == "" {
+		region = arnRegion(ref.Path)
+	}
+	if region == "" {
+		region = a.opts.Region
+	}
+	if region == "" {
+		return "", xerrors.Errorf("no AWS region for secret %q, reference it by ARN or set a default region", ref.Path)
+	}
+	endpoint := a.opts.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": ref.Path})
+	if err != nil {
+		return "", xerrors.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return "", xerrors.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+
+	hash := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(hash[:])
+	creds, err := a.opts.Credentials.Retrieve(ctx)
+	if err != nil {
+		return "", xerrors.Errorf("retrieve AWS credentials: %w", err)
+	}
+	err = a.signer.SignHTTP(ctx, creds, req, payloadHash, "secretsmanager", region, time.Now())
+	if err != nil {
+		return "", xerrors.Errorf("sign request: %w", err)
+	}
+
+	resp, err := a.opts.HTTPClient.Do(req)
+	if err != nil {
+		return "", xerrors.Errorf("get secret value: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", xerrors.Errorf("get secret value %q: unexpected status code %d: %s", ref.Path, resp.StatusCode, data)
+	}
+	var res struct {
+		SecretString *string `json:"SecretString"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return "", xerrors.Errorf("decode response: %w", err)
+	}
+	if res.SecretString == nil {
+		return "", xerrors.Errorf("secret %q is binary, only string secrets are supported", ref.Path)
+	}
+	if ref.Key == "" {
+		return *res.SecretString, nil
+	}
+
+	var fields map[string]interface{}
+	err = json.Unmarshal([]byte(*res.SecretString), &fields)
+	if err != nil {
+		return "", xerrors.Errorf("secret %q is not a JSON object, remove the key to use the whole secret", ref.Path)
+	}
+	value, ok := fields[ref.Key]
+	if !ok {
+		return "", xerrors.Errorf("secret %q has no key %q", ref.Path, ref.Key)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", xerrors.Errorf("key %q of secret %q is not a string", ref.Key, ref.Path)
+	}
+	return s, nil
+}
+
+// arnRegion returns the region of a secret referenced by ARN, e.g.
+// "arn:aws:secretsmanager:us-east-1:123456789012:secret:db", or an empty
+// string if id isn't an ARN.
+func arnRegion(id string) string {
+	parts := strings.SplitN(id, ":", 5)
+	if len(parts) < 5 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[3]
+}
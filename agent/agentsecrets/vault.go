@@ -0,0 +1,165 @@
+package agentsecrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// DefaultVaultJWTFile is the service account token of a Kubernetes pod, which
+// Vault's Kubernetes auth method accepts.
+const DefaultVaultJWTFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+type VaultOptions struct {
+	// Address is the URL of the Vault server.
+	Address string
+	// AuthMount is the path the JWT or Kubernetes auth method is mounted at.
+	// Defaults to "kubernetes".
+	AuthMount string
+	// AuthRole is the role to log in as.
+	AuthRole string
+	// JWTFile is the file the JWT identifying the workspace is read from. It
+	// is read on every login, so rotated tokens are picked up. Defaults to
+	// DefaultVaultJWTFile.
+	JWTFile    string
+	HTTPClient *http.Client
+}
+
+// Vault reads secrets from the KV version 2 secrets engine of HashiCorp
+// Vault. References have the form "secret://vault/<mount>/<path>#<key>". The
+// backend logs in with the JWT or Kubernetes auth method, using a JWT that
+// identifies the workspace.
+type Vault struct {
+	opts VaultOptions
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+func NewVault(opts VaultOptions) *Vault {
+	opts.Address = strings.TrimSuffix(opts.Address, "/")
+	if opts.AuthMount == "" {
+		opts.AuthMount = "kubernetes"
+	}
+	if opts.JWTFile == "" {
+		opts.JWTFile = DefaultVaultJWTFile
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &Vault{opts: opts}
+}
+
+func (v *Vault) Secret(ctx context.Context, ref Reference) (string, error) {
+	if ref.Key == "" {
+		return "", xerrors.New("vault secret references must select a key, e.g. #password")
+	}
+	mount, path, ok := strings.Cut(ref.Path, "/")
+	if !ok || path == "" {
+		return "", xerrors.Errorf("vault secret path %q must start with the mount of the secrets engine", ref.Path)
+	}
+	token, err := v.loginToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	var res struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	err = v.request(ctx, http.MethodGet, fmt.Sprintf("/v1/%s/data/%s", mount, path), token, nil, &res)
+	if err != nil {
+		return "", err
+	}
+	value, ok := res.Data.Data[ref.Key]
+	if !ok {
+		return "", xerrors.Errorf("vault secret %q has no key %q", ref.Path, ref.Key)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", xerrors.Errorf("key %q of vault secret %q is not a string", ref.Key, ref.Path)
+	}
+	return s, nil
+}
+
+// loginToken returns the token to authenticate requests with, logging in if
+// there is no token or it's about to expire.
+func (v *Vault) loginToken(ctx context.Context) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.token != "" && time.Now().Before(v.tokenExpiry) {
+		return v.token, nil
+	}
+	if v.opts.AuthRole == "" {
+		return "", xerrors.New("vault auth role is not set")
+	}
+
+	jwt, err := os.ReadFile(v.opts.JWTFile)
+	if err != nil {
+		return "", xerrors.Errorf("read vault jwt: %w", err)
+	}
+	var res struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+	err = v.request(ctx, http.MethodPost, fmt.Sprintf("/v1/auth/%s/login", v.opts.AuthMount), "", map[string]string{
+		"role": v.opts.AuthRole,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	}, &res)
+	if err != nil {
+		return "", xerrors.Errorf("vault login: %w", err)
+	}
+	if res.Auth.ClientToken == "" {
+		return "", xerrors.New("vault login returned no token")
+	}
+	v.token = res.Auth.ClientToken
+	// Log in again a little before the token expires, so it doesn't expire
+	// while a request is in flight.
+	v.tokenExpiry = time.Now().Add(time.Duration(res.Auth.LeaseDuration) * time.Second * 9 / 10)
+	return v.token, nil
+}
+
+func (v *Vault) request(ctx context.Context, method, path, token string, body interface{}, res interface{}) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return xerrors.Errorf("marshal request: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, v.opts.Address+path, r)
+	if err != nil {
+		return xerrors.Errorf("create request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	resp, err := v.opts.HTTPClient.Do(req)
+	if err != nil {
+		return xerrors.Errorf("vault request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return xerrors.Errorf("vault request %s %s: unexpected status code %d: %s", method, path, resp.StatusCode, data)
+	}
+	err = json.NewDecoder(resp.Body).Decode(res)
+	if err != nil {
+		return xerrors.Errorf("decode vault response: %w", err)
+	}
+	return nil
+}
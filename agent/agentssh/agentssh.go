@@ -582,6 +582,12 @@ func (s *Server) CreateCommand(ctx context.Context, script string, env []string)
 		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", envKey, os.ExpandEnv(value)))
 	}
 
+	// Secrets of the workspace owner that the template references. Their
+	// values are used verbatim, without expanding environment variables.
+	for name, value := range manifest.Secrets {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", name, value))
+	}
+
 	// Agent-level environment variables should take over all!
 	// This is used for setting agent-specific variables like "CODER_AGENT_TOKEN".
 	for envKey, value := range s.Env {
@@ -756,6 +762,25 @@ func (*Server) Shutdown(_ context.Context) error {
 	return nil
 }
 
+// Broadcast writes the message to the stderr of all active sessions, e.g. to
+// warn users that the workspace is about to stop.
+func (s *Server) Broadcast(message string) {
+	s.mu.RLock()
+	sessions := make([]ssh.Session, 0, len(s.sessions))
+	for ss := range s.sessions {
+		sessions = append(sessions, ss)
+	}
+	s.mu.RUnlock()
+
+	// Writes may block on slow clients, so they happen without the lock.
+	for _, ss := range sessions {
+		err := writeWithCarriageReturn(strings.NewReader(message), ss.Stderr())
+		if err != nil {
+			s.logger.Debug(ss.Context(), "broadcast to session", slog.Error(err))
+		}
+	}
+}
+
 func isLoginShell(rawCommand string) bool {
 	return len(rawCommand) == 0
 }
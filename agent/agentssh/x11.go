@@ -24,6 +24,12 @@ import (
 // x11Callback is called when the client requests X11 forwarding.
 // It adds an Xauthority entry to the Xauthority file.
 func (s *Server) x11Callback(ctx ssh.Context, x11 ssh.X11) bool {
+	if manifest := s.Manifest.Load(); manifest != nil && manifest.DisableX11Forwarding {
+		s.logger.Info(ctx, "rejected x11 forwarding request, x11 forwarding is disabled by the deployment")
+		s.metrics.x11HandlerErrors.WithLabelValues("disabled").Add(1)
+		return false
+	}
+
 	hostname, err := os.Hostname()
 	if err != nil {
 		s.logger.Warn(ctx, "failed to get hostname", slog.Error(err))
@@ -10,6 +10,7 @@ import (
 
 	"github.com/google/uuid"
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
@@ -37,6 +38,7 @@ func NewClient(t testing.TB,
 		statsChan:      statsChan,
 		coordinator:    coordinator,
 		derpMapUpdates: make(chan agentsdk.DERPMapUpdate),
+		drainRequests:  make(chan agentsdk.DrainRequest),
 	}
 }
 
@@ -51,12 +53,16 @@ type Client struct {
 	LastWorkspaceAgent   func()
 	PatchWorkspaceLogs   func() error
 	GetServiceBannerFunc func() (codersdk.ServiceBannerConfig, error)
+	GitAuthFunc          func(gitURL string, listen bool) (agentsdk.GitAuthResponse, error)
 
 	mu              sync.Mutex // Protects following.
 	lifecycleStates []codersdk.WorkspaceAgentLifecycle
+	scriptStatuses  []agentsdk.PostScriptStatusRequest
+	metadataPlugins []codersdk.WorkspaceAgentMetadataDescription
 	startup         agentsdk.PostStartupRequest
 	logs            []agentsdk.Log
 	derpMapUpdates  chan agentsdk.DERPMapUpdate
+	drainRequests   chan agentsdk.DrainRequest
 }
 
 func (c *Client) Manifest(_ context.Context) (agentsdk.Manifest, error) {
@@ -125,6 +131,20 @@ func (c *Client) PostLifecycle(ctx context.Context, req agentsdk.PostLifecycleRe
 	return nil
 }
 
+func (c *Client) GetScriptStatuses() []agentsdk.PostScriptStatusRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.scriptStatuses)
+}
+
+func (c *Client) PostScriptStatus(ctx context.Context, req agentsdk.PostScriptStatusRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.scriptStatuses = append(c.scriptStatuses, req)
+	c.logger.Debug(ctx, "post script status", slog.F("req", req))
+	return nil
+}
+
 func (c *Client) PostAppHealth(ctx context.Context, req agentsdk.PostAppHealthsRequest) error {
 	c.logger.Debug(ctx, "post app health", slog.F("req", req))
 	return nil
@@ -142,6 +162,20 @@ func (c *Client) GetMetadata() map[string]agentsdk.PostMetadataRequest {
 	return maps.Clone(c.metadata)
 }
 
+func (c *Client) GetMetadataPlugins() []codersdk.WorkspaceAgentMetadataDescription {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.metadataPlugins)
+}
+
+func (c *Client) PostMetadataPlugins(ctx context.Context, req agentsdk.PostMetadataPluginsRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metadataPlugins = req.Plugins
+	c.logger.Debug(ctx, "post metadata plugins", slog.F("req", req))
+	return nil
+}
+
 func (c *Client) PostMetadata(ctx context.Context, key string, req agentsdk.PostMetadataRequest) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -207,6 +241,38 @@ func (c *Client) PushDERPMapUpdate(update agentsdk.DERPMapUpdate) error {
 	return nil
 }
 
+func (c *Client) PushDrainRequest(req agentsdk.DrainRequest) error {
+	timer := time.NewTimer(testutil.WaitShort)
+	defer timer.Stop()
+	select {
+	case c.drainRequests <- req:
+	case <-timer.C:
+		return xerrors.New("timeout waiting to push drain request")
+	}
+
+	return nil
+}
+
+func (c *Client) WaitForDrain(ctx context.Context) (agentsdk.DrainRequest, error) {
+	select {
+	case req := <-c.drainRequests:
+		return req, nil
+	case <-ctx.Done():
+		return agentsdk.DrainRequest{}, ctx.Err()
+	}
+}
+
+func (c *Client) GitAuth(ctx context.Context, gitURL string, listen bool) (agentsdk.GitAuthResponse, error) {
+	c.mu.Lock()
+	gitAuth := c.GitAuthFunc
+	c.mu.Unlock()
+	c.logger.Debug(ctx, "git auth", slog.F("url", gitURL), slog.F("listen", listen))
+	if gitAuth != nil {
+		return gitAuth(gitURL, listen)
+	}
+	return agentsdk.GitAuthResponse{}, xerrors.New("no git auth func set")
+}
+
 func (c *Client) DERPMapUpdates(_ context.Context) (<-chan agentsdk.DERPMapUpdate, io.Closer, error) {
 	closed := make(chan struct{})
 	return c.derpMapUpdates, closeFunc(func() error {
@@ -1,7 +1,12 @@
 package agent
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
@@ -26,17 +31,97 @@ func (a *agent) apiHandler() http.Handler {
 		cpy[k] = b
 	}
 
-	lp := &listeningPortsHandler{ignorePorts: cpy}
+	lp := &listeningPortsHandler{
+		ignorePorts: cpy,
+		forwardedPorts: func() []uint16 {
+			if ports := a.devcontainerPorts.Load(); ports != nil {
+				return *ports
+			}
+			return nil
+		},
+	}
 	r.Get("/api/v0/listening-ports", lp.handler)
+	r.Get("/api/v0/debug/logs", a.debugLogsHandler)
+	r.Get("/api/v0/files", a.filesDownloadHandler)
+	r.Put("/api/v0/files", a.filesUploadHandler)
+	r.Get("/api/v0/files/stat", a.filesStatHandler)
 
 	return r
 }
 
+// debugLogsMaxBytes is the maximum number of bytes served from the end of
+// each log file.
+const debugLogsMaxBytes = 1 << 20
+
+// debugLogsHandler returns the end of the agent's log files, so they can be
+// collected without a shell in the workspace.
+func (a *agent) debugLogsHandler(rw http.ResponseWriter, r *http.Request) {
+	resp := codersdk.WorkspaceAgentDebugLogsResponse{
+		Files: []codersdk.WorkspaceAgentDebugLogFile{},
+	}
+	for _, name := range []string{
+		"coder-agent.log",
+		"coder-startup-script.log",
+		"coder-shutdown-script.log",
+	} {
+		file, ok, err := a.readLogTail(filepath.Join(a.logDir, name))
+		if err != nil {
+			httpapi.Write(r.Context(), rw, http.StatusInternalServerError, codersdk.Response{
+				Message: fmt.Sprintf("Could not read log file %q.", name),
+				Detail:  err.Error(),
+			})
+			return
+		}
+		if !ok {
+			continue
+		}
+		file.Name = name
+		resp.Files = append(resp.Files, file)
+	}
+
+	httpapi.Write(r.Context(), rw, http.StatusOK, resp)
+}
+
+// readLogTail reads up to debugLogsMaxBytes from the end of the file at path.
+// The bool is false if the file doesn't exist.
+func (a *agent) readLogTail(path string) (codersdk.WorkspaceAgentDebugLogFile, bool, error) {
+	f, err := a.filesystem.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return codersdk.WorkspaceAgentDebugLogFile{}, false, nil
+	}
+	if err != nil {
+		return codersdk.WorkspaceAgentDebugLogFile{}, false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return codersdk.WorkspaceAgentDebugLogFile{}, false, err
+	}
+	var file codersdk.WorkspaceAgentDebugLogFile
+	if info.Size() > debugLogsMaxBytes {
+		_, err = f.Seek(info.Size()-debugLogsMaxBytes, io.SeekStart)
+		if err != nil {
+			return codersdk.WorkspaceAgentDebugLogFile{}, false, err
+		}
+		file.Truncated = true
+	}
+	content, err := io.ReadAll(io.LimitReader(f, debugLogsMaxBytes))
+	if err != nil {
+		return codersdk.WorkspaceAgentDebugLogFile{}, false, err
+	}
+	file.Content = string(content)
+	return file, true, nil
+}
+
 type listeningPortsHandler struct {
 	mut         sync.Mutex
 	ports       []codersdk.WorkspaceAgentListeningPort
 	mtime       time.Time
 	ignorePorts map[int]string
+	// forwardedPorts returns ports that are listed even if nothing listens
+	// on them, e.g. the forwardPorts of devcontainer.json.
+	forwardedPorts func() []uint16
 }
 
 // handler returns a list of listening ports. This is tested by coderd's
@@ -50,8 +135,30 @@ func (lp *listeningPortsHandler) handler(rw http.ResponseWriter, r *http.Request
 		})
 		return
 	}
+	if lp.forwardedPorts != nil {
+		ports = appendForwardedPorts(ports, lp.forwardedPorts())
+	}
 
 	httpapi.Write(r.Context(), rw, http.StatusOK, codersdk.WorkspaceAgentListeningPortsResponse{
 		Ports: ports,
 	})
 }
+
+// appendForwardedPorts appends the forwarded ports that aren't listening yet.
+func appendForwardedPorts(ports []codersdk.WorkspaceAgentListeningPort, forwarded []uint16) []codersdk.WorkspaceAgentListeningPort {
+	listening := make(map[uint16]struct{}, len(ports))
+	for _, port := range ports {
+		listening[port.Port] = struct{}{}
+	}
+	for _, port := range forwarded {
+		if _, ok := listening[port]; ok {
+			continue
+		}
+		listening[port] = struct{}{}
+		ports = append(ports, codersdk.WorkspaceAgentListeningPort{
+			Network: "tcp",
+			Port:    port,
+		})
+	}
+	return ports
+}
@@ -0,0 +1,668 @@
+package agent
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+)
+
+// devcontainerConfigPaths are the locations of devcontainer.json in a
+// repository, in the order they are searched.
+var devcontainerConfigPaths = []string{
+	filepath.Join(".devcontainer", "devcontainer.json"),
+	".devcontainer.json",
+}
+
+// devcontainerPostCreateMarker is the file in the workspace folder that marks
+// that the postCreateCommand has run.
+const devcontainerPostCreateMarker = ".coder-devcontainer-created"
+
+// devcontainerConfig is the part of devcontainer.json the agent applies. The
+// workspace already is the container, so everything related to building or
+// running one is ignored.
+type devcontainerConfig struct {
+	Features                    map[string]json.RawMessage `json:"features"`
+	OverrideFeatureInstallOrder []string                   `json:"overrideFeatureInstallOrder"`
+	PostCreateCommand           json.RawMessage            `json:"postCreateCommand"`
+	PostStartCommand            json.RawMessage            `json:"postStartCommand"`
+	ForwardPorts                []json.RawMessage          `json:"forwardPorts"`
+}
+
+// applyDevcontainers applies the devcontainer.json of every devcontainer in
+// the manifest. All of them are applied even if one fails, and the first
+// error is returned.
+func (a *agent) applyDevcontainers(ctx context.Context, devcontainers []codersdk.WorkspaceAgentDevcontainer) error {
+	if len(devcontainers) == 0 {
+		return nil
+	}
+	var (
+		firstErr error
+		ports    []uint16
+	)
+	for _, devcontainer := range devcontainers {
+		forwardPorts, err := a.applyDevcontainer(ctx, devcontainer)
+		ports = append(ports, forwardPorts...)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+
+		a.logger.Warn(ctx, "failed to apply devcontainer", slog.F("workspace_folder", devcontainer.WorkspaceFolder), slog.Error(err))
+		logErr := a.client.PatchLogs(ctx, agentsdk.PatchLogs{
+			Logs: []agentsdk.Log{{
+				CreatedAt: time.Now(),
+				Output:    fmt.Sprintf("Failed to apply devcontainer in %s: %s", devcontainer.WorkspaceFolder, err),
+				Level:     codersdk.LogLevelError,
+				Source:    codersdk.WorkspaceAgentLogSourceDevcontainer,
+			}},
+		})
+		if logErr != nil {
+			a.logger.Warn(ctx, "send devcontainer failure log", slog.Error(logErr))
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	a.devcontainerPorts.Store(&ports)
+	return firstErr
+}
+
+// applyDevcontainer installs the features of the devcontainer.json in the
+// workspace folder and runs its post start command, and its post create
+// command if it hasn't run in the workspace folder before. The
+// forwarded ports are returned as soon as the config has been read, so they
+// are reported even if a command fails.
+func (a *agent) applyDevcontainer(ctx context.Context, devcontainer codersdk.WorkspaceAgentDevcontainer) ([]uint16, error) {
+	if runtime.GOOS == "windows" {
+		return nil, xerrors.New("devcontainers are not supported on Windows")
+	}
+	folder, err := expandDirectory(devcontainer.WorkspaceFolder)
+	if err != nil {
+		return nil, xerrors.Errorf("expand workspace folder: %w", err)
+	}
+	configPath, err := a.findDevcontainerConfig(folder, devcontainer.ConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := afero.ReadFile(a.filesystem, configPath)
+	if err != nil {
+		return nil, xerrors.Errorf("read %s: %w", configPath, err)
+	}
+	var config devcontainerConfig
+	err = json.Unmarshal(standardizeJSONC(raw), &config)
+	if err != nil {
+		return nil, xerrors.Errorf("parse %s: %w", configPath, err)
+	}
+	ports, err := devcontainerForwardPorts(config.ForwardPorts)
+	if err != nil {
+		return nil, xerrors.Errorf("parse forwardPorts of %s: %w", configPath, err)
+	}
+
+	for _, id := range devcontainerFeatureOrder(config) {
+		err = a.installDevcontainerFeature(ctx, filepath.Dir(configPath), id, config.Features[id])
+		if err != nil {
+			return ports, xerrors.Errorf("install feature %q: %w", id, err)
+		}
+	}
+	// postCreateCommand only runs once per container, so a marker is written
+	// to the workspace folder after it succeeded.
+	markerPath := filepath.Join(folder, devcontainerPostCreateMarker)
+	_, err = a.filesystem.Stat(markerPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		err = a.runDevcontainerCommand(ctx, folder, configPath, "postCreateCommand", config.PostCreateCommand)
+		if err != nil {
+			return ports, err
+		}
+		err = afero.WriteFile(a.filesystem, markerPath, []byte(time.Now().UTC().Format(time.RFC3339)+"\n"), 0o600)
+		if err != nil {
+			return ports, xerrors.Errorf("write %s: %w", markerPath, err)
+		}
+	case err != nil:
+		return ports, xerrors.Errorf("stat %s: %w", markerPath, err)
+	}
+	err = a.runDevcontainerCommand(ctx, folder, configPath, "postStartCommand", config.PostStartCommand)
+	if err != nil {
+		return ports, err
+	}
+	return ports, nil
+}
+
+// runDevcontainerCommand runs a lifecycle command of devcontainer.json in the
+// workspace folder.
+func (a *agent) runDevcontainerCommand(ctx context.Context, folder, configPath, name string, raw json.RawMessage) error {
+	scripts, err := devcontainerCommandScripts(raw)
+	if err != nil {
+		return xerrors.Errorf("parse %s of %s: %w", name, configPath, err)
+	}
+	for _, script := range scripts {
+		err = a.runScript(ctx, "devcontainer", "", "cd "+quoteShellArg(folder)+" && "+script, 0)
+		if err != nil {
+			return xerrors.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// findDevcontainerConfig returns the path of the devcontainer.json of the
+// workspace folder. If configPath is empty, the default locations are
+// searched.
+func (a *agent) findDevcontainerConfig(folder, configPath string) (string, error) {
+	if configPath != "" {
+		if !filepath.IsAbs(configPath) {
+			configPath = filepath.Join(folder, configPath)
+		}
+		if _, err := a.filesystem.Stat(configPath); err != nil {
+			return "", xerrors.Errorf("stat %s: %w", configPath, err)
+		}
+		return configPath, nil
+	}
+	for _, name := range devcontainerConfigPaths {
+		configPath = filepath.Join(folder, name)
+		_, err := a.filesystem.Stat(configPath)
+		if err == nil {
+			return configPath, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", xerrors.Errorf("stat %s: %w", configPath, err)
+		}
+	}
+	return "", xerrors.Errorf("no devcontainer.json found in %s", folder)
+}
+
+// devcontainerFeatureOrder returns the IDs of the features in the order they
+// are installed. Features in overrideFeatureInstallOrder come first, the rest
+// are sorted by ID so the order doesn't change between starts.
+func devcontainerFeatureOrder(config devcontainerConfig) []string {
+	ids := make([]string, 0, len(config.Features))
+	seen := make(map[string]struct{}, len(config.Features))
+	for _, id := range config.OverrideFeatureInstallOrder {
+		if _, ok := config.Features[id]; !ok {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	rest := make([]string, 0, len(config.Features))
+	for id := range config.Features {
+		if _, ok := seen[id]; !ok {
+			rest = append(rest, id)
+		}
+	}
+	sort.Strings(rest)
+	return append(ids, rest...)
+}
+
+// installDevcontainerFeature fetches a feature and runs its install.sh with
+// the options of the feature as environment variables.
+func (a *agent) installDevcontainerFeature(ctx context.Context, configDir, id string, rawOptions json.RawMessage) error {
+	featureDir, err := a.fetchDevcontainerFeature(ctx, configDir, id)
+	if err != nil {
+		return err
+	}
+
+	options := map[string]any{}
+	if metadata, err := afero.ReadFile(a.filesystem, filepath.Join(featureDir, "devcontainer-feature.json")); err == nil {
+		var feature struct {
+			Options map[string]struct {
+				Default any `json:"default"`
+			} `json:"options"`
+		}
+		err = json.Unmarshal(standardizeJSONC(metadata), &feature)
+		if err != nil {
+			return xerrors.Errorf("parse devcontainer-feature.json: %w", err)
+		}
+		for name, option := range feature.Options {
+			if option.Default != nil {
+				options[name] = option.Default
+			}
+		}
+	}
+	// A string is a shorthand for the version option.
+	var version string
+	if err := json.Unmarshal(rawOptions, &version); err == nil {
+		options["version"] = version
+	} else if len(rawOptions) > 0 {
+		var userOptions map[string]any
+		err = json.Unmarshal(rawOptions, &userOptions)
+		if err != nil {
+			return xerrors.Errorf("parse options: %w", err)
+		}
+		for name, value := range userOptions {
+			options[name] = value
+		}
+	}
+
+	env := devcontainerUserEnv()
+	for name, value := range options {
+		env = append(env, devcontainerOptionEnvName(name)+"="+quoteShellArg(fmt.Sprint(value)))
+	}
+	sort.Strings(env)
+	script := fmt.Sprintf("cd %s && chmod +x ./install.sh && %s ./install.sh", quoteShellArg(featureDir), strings.Join(env, " "))
+	return a.runScript(ctx, "devcontainer", "", script, 0)
+}
+
+// devcontainerUserEnv returns the variables features use to find out which
+// user they install the tools for. The workspace user is both the container
+// and the remote user.
+func devcontainerUserEnv() []string {
+	u, err := user.Current()
+	if err != nil {
+		return nil
+	}
+	var env []string
+	for _, prefix := range []string{"_CONTAINER_USER", "_REMOTE_USER"} {
+		env = append(env,
+			prefix+"="+quoteShellArg(u.Username),
+			prefix+"_HOME="+quoteShellArg(u.HomeDir),
+		)
+	}
+	return env
+}
+
+var (
+	devcontainerOptionInvalidChars = regexp.MustCompile(`[^\w]`)
+	devcontainerOptionLeadingChars = regexp.MustCompile(`^[\d_]+`)
+)
+
+// devcontainerOptionEnvName returns the name of the environment variable of a
+// feature option, as defined by the devcontainer features spec.
+func devcontainerOptionEnvName(name string) string {
+	name = devcontainerOptionInvalidChars.ReplaceAllString(name, "_")
+	name = devcontainerOptionLeadingChars.ReplaceAllString(name, "_")
+	return strings.ToUpper(name)
+}
+
+// fetchDevcontainerFeature returns the directory of the feature with the
+// given ID. Local features are used in place, tarballs and features in OCI
+// registries are downloaded to the temporary directory of the agent.
+func (a *agent) fetchDevcontainerFeature(ctx context.Context, configDir, id string) (string, error) {
+	if strings.HasPrefix(id, "./") || strings.HasPrefix(id, "../") {
+		return filepath.Join(configDir, id), nil
+	}
+
+	sum := sha256.Sum256([]byte(id))
+	featureDir := filepath.Join(a.tempDir, "coder-devcontainer-features", hex.EncodeToString(sum[:8]))
+	err := os.RemoveAll(featureDir)
+	if err != nil {
+		return "", xerrors.Errorf("remove %s: %w", featureDir, err)
+	}
+
+	var layer io.ReadCloser
+	if strings.HasPrefix(id, "https://") {
+		layer, err = httpGet(ctx, id, nil)
+	} else {
+		layer, err = fetchOCIFeatureLayer(ctx, id)
+	}
+	if err != nil {
+		return "", xerrors.Errorf("download: %w", err)
+	}
+	defer layer.Close()
+
+	err = extractTar(layer, featureDir)
+	if err != nil {
+		return "", xerrors.Errorf("extract: %w", err)
+	}
+	return featureDir, nil
+}
+
+// devcontainerFeatureMediaType is the media type of the layer that holds a
+// feature in an OCI registry.
+const devcontainerFeatureMediaType = "application/vnd.devcontainers.layer.v1+tar"
+
+// fetchOCIFeatureLayer downloads the layer of a feature that's published to
+// an OCI registry, e.g. "ghcr.io/devcontainers/features/go:1". Only public
+// features are supported.
+func fetchOCIFeatureLayer(ctx context.Context, ref string) (io.ReadCloser, error) {
+	registry, repository, ok := strings.Cut(ref, "/")
+	if !ok {
+		return nil, xerrors.Errorf("invalid feature reference %q", ref)
+	}
+	tag := "latest"
+	if name, digest, ok := strings.Cut(repository, "@"); ok {
+		repository, tag = name, digest
+	} else if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	baseURL := fmt.Sprintf("https://%s/v2/%s", registry, repository)
+
+	header := http.Header{"Accept": []string{"application/vnd.oci.image.manifest.v1+json"}}
+	token, err := ociAnonymousToken(ctx, baseURL+"/manifests/"+tag)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	body, err := httpGet(ctx, baseURL+"/manifests/"+tag, header)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	var manifest struct {
+		Layers []struct {
+			MediaType string `json:"mediaType"`
+			Digest    string `json:"digest"`
+		} `json:"layers"`
+	}
+	err = json.NewDecoder(body).Decode(&manifest)
+	if err != nil {
+		return nil, xerrors.Errorf("decode manifest: %w", err)
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != devcontainerFeatureMediaType {
+			continue
+		}
+		digest, ok := strings.CutPrefix(layer.Digest, "sha256:")
+		if !ok {
+			return nil, xerrors.Errorf("unsupported layer digest %q", layer.Digest)
+		}
+		blob, err := httpGet(ctx, baseURL+"/blobs/"+layer.Digest, header)
+		if err != nil {
+			return nil, err
+		}
+		defer blob.Close()
+		// Features are small, so the layer is verified before it is
+		// extracted.
+		data, err := io.ReadAll(io.LimitReader(blob, 100<<20))
+		if err != nil {
+			return nil, xerrors.Errorf("read layer: %w", err)
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != digest {
+			return nil, xerrors.Errorf("layer digest mismatch for %s", layer.Digest)
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return nil, xerrors.Errorf("manifest of %q has no %s layer", ref, devcontainerFeatureMediaType)
+}
+
+// ociAnonymousToken returns an anonymous pull token for a registry that
+// requires one, e.g. ghcr.io. It's empty if the registry allows anonymous
+// requests.
+func ociAnonymousToken(ctx context.Context, manifestURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return "", err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		return "", nil
+	}
+
+	challenge, ok := strings.CutPrefix(res.Header.Get("WWW-Authenticate"), "Bearer ")
+	if !ok {
+		return "", xerrors.Errorf("unsupported authentication challenge %q", res.Header.Get("WWW-Authenticate"))
+	}
+	params := map[string]string{}
+	for _, param := range strings.Split(challenge, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		params[key] = strings.Trim(value, `"`)
+	}
+	tokenURL, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", xerrors.Errorf("parse token realm: %w", err)
+	}
+	query := tokenURL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	body, err := httpGet(ctx, tokenURL.String(), nil)
+	if err != nil {
+		return "", xerrors.Errorf("get token: %w", err)
+	}
+	defer body.Close()
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(body).Decode(&token)
+	if err != nil {
+		return "", xerrors.Errorf("decode token: %w", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+func httpGet(ctx context.Context, rawURL string, header http.Header) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		_ = res.Body.Close()
+		return nil, xerrors.Errorf("get %s: unexpected status %s", rawURL, res.Status)
+	}
+	return res.Body, nil
+}
+
+// extractTar extracts a tar archive, which may be gzipped, to dir.
+func extractTar(r io.Reader, dir string) error {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = br
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean("/" + header.Name)
+		if name == "/" {
+			continue
+		}
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0o755)
+		case tar.TypeReg:
+			err = writeTarFile(tr, target, os.FileMode(header.Mode).Perm())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeTarFile(r io.Reader, target string, mode os.FileMode) error {
+	err := os.MkdirAll(filepath.Dir(target), 0o755)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// devcontainerCommandScripts converts a lifecycle command of devcontainer.json
+// to shell scripts. A command is either a string that's run by the shell, an
+// array of arguments, or an object of named commands. Named commands run one
+// after another, sorted by name.
+func devcontainerCommandScripts(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var script string
+	if err := json.Unmarshal(raw, &script); err == nil {
+		return []string{script}, nil
+	}
+	var args []string
+	if err := json.Unmarshal(raw, &args); err == nil {
+		for i, arg := range args {
+			args[i] = quoteShellArg(arg)
+		}
+		return []string{strings.Join(args, " ")}, nil
+	}
+	var named map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &named); err != nil {
+		return nil, xerrors.New("must be a string, an array of strings or an object")
+	}
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var scripts []string
+	for _, name := range names {
+		if bytes.HasPrefix(bytes.TrimSpace(named[name]), []byte("{")) {
+			return nil, xerrors.Errorf("command %q must be a string or an array of strings", name)
+		}
+		commandScripts, err := devcontainerCommandScripts(named[name])
+		if err != nil {
+			return nil, xerrors.Errorf("command %q: %w", name, err)
+		}
+		scripts = append(scripts, commandScripts...)
+	}
+	return scripts, nil
+}
+
+// devcontainerForwardPorts parses the forwardPorts of devcontainer.json. Ports
+// are either numbers or "host:port" strings; the host is ignored since the
+// workspace is the container.
+func devcontainerForwardPorts(raw []json.RawMessage) ([]uint16, error) {
+	ports := make([]uint16, 0, len(raw))
+	for _, value := range raw {
+		var port uint16
+		if err := json.Unmarshal(value, &port); err == nil {
+			ports = append(ports, port)
+			continue
+		}
+		var hostPort string
+		if err := json.Unmarshal(value, &hostPort); err != nil {
+			return nil, xerrors.Errorf("invalid port %s", value)
+		}
+		_, portString, _ := strings.Cut(hostPort, ":")
+		if portString == "" {
+			portString = hostPort
+		}
+		parsed, err := strconv.ParseUint(portString, 10, 16)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid port %q", hostPort)
+		}
+		ports = append(ports, uint16(parsed))
+	}
+	return ports, nil
+}
+
+// standardizeJSONC removes the comments and trailing commas devcontainer.json
+// allows, so that the result can be parsed with encoding/json.
+func standardizeJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if inString {
+			out = append(out, c)
+			switch c {
+			case '\\':
+				if i+1 < len(data) {
+					i++
+					out = append(out, data[i])
+				}
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			i--
+			continue
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+			continue
+		case c == '}' || c == ']':
+			// Drop a comma that only has whitespace between it and the end
+			// of the object or array.
+			j := len(out) - 1
+			for j >= 0 && isJSONWhitespace(out[j]) {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func isJSONWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
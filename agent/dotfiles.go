@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+)
+
+// applyDotfiles applies the dotfiles repository of the workspace owner with
+// "coder dotfiles". Since the home directory may not survive a rebuild, this
+// is done every time the agent starts. Failures are reported in the startup
+// logs of the agent, but don't fail the startup. Applying the dotfiles is
+// killed after the dotfiles timeout, so a hanging install script or clone
+// doesn't keep the agent from becoming ready.
+func (a *agent) applyDotfiles(ctx context.Context, dotfiles *codersdk.UserDotfiles) {
+	if dotfiles == nil || dotfiles.RepoURL == "" {
+		return
+	}
+	script, err := a.dotfilesScript(dotfiles)
+	if err == nil {
+		err = a.runScript(ctx, "dotfiles", "", script, a.dotfilesTimeout)
+	}
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	a.logger.Warn(ctx, "failed to apply dotfiles", slog.F("repo_url", dotfiles.RepoURL), slog.Error(err))
+	err = a.client.PatchLogs(ctx, agentsdk.PatchLogs{
+		Logs: []agentsdk.Log{{
+			CreatedAt: time.Now(),
+			Output:    fmt.Sprintf("Failed to apply dotfiles from %s: %s", dotfiles.RepoURL, err),
+			Level:     codersdk.LogLevelError,
+			Source:    codersdk.WorkspaceAgentLogSourceDotfiles,
+		}},
+	})
+	if err != nil {
+		a.logger.Warn(ctx, "send dotfiles failure log", slog.Error(err))
+	}
+}
+
+// dotfilesScript returns the command that applies the dotfiles. The agent
+// binary is used by default, since the coder CLI isn't necessarily on the
+// PATH.
+func (a *agent) dotfilesScript(dotfiles *codersdk.UserDotfiles) (string, error) {
+	command := a.dotfilesCommand
+	if command == "" {
+		executablePath, err := os.Executable()
+		if err != nil {
+			return "", xerrors.Errorf("get executable path: %w", err)
+		}
+		command = quoteShellArg(executablePath)
+	}
+	args := []string{"dotfiles", "--yes"}
+	if dotfiles.Branch != "" {
+		args = append(args, "--branch", dotfiles.Branch)
+	}
+	args = append(args, dotfiles.RepoURL)
+	for _, arg := range args {
+		command += " " + quoteShellArg(arg)
+	}
+	return command, nil
+}
+
+// quoteShellArg quotes an argument for the shell of the user. coderd only
+// accepts repository URLs and branches the shell wouldn't interpret, but the
+// path of the agent binary may contain spaces.
+func quoteShellArg(arg string) string {
+	if runtime.GOOS == "windows" {
+		return `"` + arg + `"`
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
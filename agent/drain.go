@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/retry"
+)
+
+// drainSessionsInterval is how often the agent checks whether all sessions
+// have ended while draining.
+const drainSessionsInterval = time.Second
+
+// drainLoop waits for coderd to request a drain, which happens when a stop or
+// delete build of the workspace is waiting for the agent. It returns right
+// away if draining is disabled.
+func (a *agent) drainLoop(ctx context.Context) {
+	for retrier := retry.New(100*time.Millisecond, 10*time.Second); retrier.Wait(ctx); {
+		req, err := a.client.WaitForDrain(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			var sdkErr *codersdk.Error
+			if errors.As(err, &sdkErr) && sdkErr.StatusCode() == http.StatusNotFound {
+				a.logger.Debug(ctx, "draining is disabled", slog.Error(err))
+				return
+			}
+			a.logger.Debug(ctx, "wait for drain request", slog.Error(err))
+			continue
+		}
+		a.drain(ctx, req)
+		return
+	}
+}
+
+// drain warns SSH sessions that the workspace is stopping, waits for all
+// sessions to end or the grace period to pass, and runs the shutdown script.
+// Reporting the final lifecycle state lets the build continue.
+//
+// Web terminal sessions are waited for, but are not warned.
+func (a *agent) drain(ctx context.Context, req agentsdk.DrainRequest) {
+	a.logger.Info(ctx, "draining agent",
+		slog.F("build_id", req.BuildID),
+		slog.F("transition", req.Transition),
+		slog.F("grace_period", req.GracePeriod),
+	)
+	a.setLifecycle(ctx, codersdk.WorkspaceAgentLifecycleShuttingDown)
+	a.sshServer.Broadcast(fmt.Sprintf("\nWorkspace %s requested, save your work. Sessions will be closed in %s.\n", req.Transition, req.GracePeriod))
+
+	grace := time.NewTimer(req.GracePeriod)
+	defer grace.Stop()
+	ticker := time.NewTicker(drainSessionsInterval)
+	defer ticker.Stop()
+waitLoop:
+	for a.hasSessions() {
+		select {
+		case <-ctx.Done():
+			// The agent is closing and runs the shutdown script itself.
+			return
+		case <-grace.C:
+			a.logger.Info(ctx, "drain grace period passed with active sessions")
+			break waitLoop
+		case <-ticker.C:
+		}
+	}
+
+	a.shutdown(ctx)
+}
+
+func (a *agent) hasSessions() bool {
+	return a.sshServer.ConnStats().Sessions > 0 || a.connCountReconnectingPTY.Load() > 0
+}
+
+// lifecycle returns the latest lifecycle state.
+func (a *agent) lifecycle() codersdk.WorkspaceAgentLifecycle {
+	a.lifecycleMu.RLock()
+	defer a.lifecycleMu.RUnlock()
+	return a.lifecycleStates[len(a.lifecycleStates)-1].State
+}
@@ -0,0 +1,222 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+)
+
+// The file handlers serve the workspace filesystem to the user that owns the
+// agent. They're only reachable over tailnet, so like SSH, anyone who can
+// connect to the agent is already authorized to access the workspace.
+
+// filesStatHandler returns information about the file at the path in the
+// "path" query parameter.
+func (a *agent) filesStatHandler(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	path, ok := a.filePathParam(rw, r)
+	if !ok {
+		return
+	}
+	info, err := a.filesystem.Stat(path)
+	if err != nil {
+		writeFileError(rw, r, path, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertFileInfo(path, info))
+}
+
+// filesDownloadHandler streams the file at the path in the "path" query
+// parameter. Range requests are supported, so interrupted downloads can be
+// resumed.
+func (a *agent) filesDownloadHandler(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	path, ok := a.filePathParam(rw, r)
+	if !ok {
+		return
+	}
+	f, err := a.filesystem.Open(path)
+	if err != nil {
+		writeFileError(rw, r, path, err)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		writeFileError(rw, r, path, err)
+		return
+	}
+	if info.IsDir() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("%q is a directory.", path),
+		})
+		return
+	}
+	http.ServeContent(rw, r, filepath.Base(path), info.ModTime(), f)
+}
+
+// filesUploadHandler writes the request body to the file at the path in the
+// "path" query parameter. The file is replaced unless the "offset" query
+// parameter is set, in which case the body is appended to the file. The offset
+// must match the size of the file, so a resumed upload can't corrupt it.
+func (a *agent) filesUploadHandler(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	path, ok := a.filePathParam(rw, r)
+	if !ok {
+		return
+	}
+	var (
+		offset int64
+		mode   os.FileMode = 0o644
+		err    error
+	)
+	if raw := r.URL.Query().Get("offset"); raw != "" {
+		offset, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil || offset < 0 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Query param \"offset\" must be a non-negative integer.",
+			})
+			return
+		}
+	}
+	if raw := r.URL.Query().Get("mode"); raw != "" {
+		parsed, err := strconv.ParseUint(raw, 8, 32)
+		if err != nil || os.FileMode(parsed)&^os.ModePerm != 0 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Query param \"mode\" must be octal permission bits, e.g. \"0644\".",
+			})
+			return
+		}
+		mode = os.FileMode(parsed)
+	}
+
+	// Resumed uploads append to an existing file, so they don't create one.
+	flags := os.O_WRONLY
+	if offset == 0 {
+		flags |= os.O_CREATE | os.O_TRUNC
+	}
+	f, err := a.filesystem.OpenFile(path, flags, mode)
+	if offset > 0 && errors.Is(err, os.ErrNotExist) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("Offset %d does not match the size of %q, which doesn't exist.", offset, path),
+		})
+		return
+	}
+	if err != nil {
+		writeFileError(rw, r, path, err)
+		return
+	}
+	defer f.Close()
+	if offset > 0 {
+		info, err := f.Stat()
+		if err != nil {
+			writeFileError(rw, r, path, err)
+			return
+		}
+		if info.Size() != offset {
+			httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+				Message: fmt.Sprintf("Offset %d does not match the size of %q (%d bytes).", offset, path, info.Size()),
+			})
+			return
+		}
+		_, err = f.Seek(offset, io.SeekStart)
+		if err != nil {
+			writeFileError(rw, r, path, err)
+			return
+		}
+	}
+	_, err = io.Copy(f, r.Body)
+	if err != nil {
+		writeFileError(rw, r, path, xerrors.Errorf("write: %w", err))
+		return
+	}
+	err = f.Close()
+	if err != nil {
+		writeFileError(rw, r, path, xerrors.Errorf("close: %w", err))
+		return
+	}
+	// The mode passed to OpenFile only applies to new files.
+	if r.URL.Query().Has("mode") {
+		err = a.filesystem.Chmod(path, mode)
+		if err != nil {
+			writeFileError(rw, r, path, xerrors.Errorf("chmod: %w", err))
+			return
+		}
+	}
+
+	info, err := a.filesystem.Stat(path)
+	if err != nil {
+		writeFileError(rw, r, path, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertFileInfo(path, info))
+}
+
+// filePathParam resolves the "path" query parameter to an absolute path.
+// Relative paths are relative to the working directory of SSH sessions.
+func (a *agent) filePathParam(rw http.ResponseWriter, r *http.Request) (string, bool) {
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		httpapi.Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Query param \"path\" is required.",
+		})
+		return "", false
+	}
+
+	var err error
+	switch {
+	case path == "~" || strings.HasPrefix(path, "~/"):
+		path, err = expandDirectory(path)
+	case !filepath.IsAbs(path):
+		dir := ""
+		if manifest := a.manifest.Load(); manifest != nil {
+			dir = manifest.Directory
+		}
+		if dir == "" {
+			dir, err = userHomeDir()
+		}
+		path = filepath.Join(dir, path)
+	}
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Could not resolve path.",
+			Detail:  err.Error(),
+		})
+		return "", false
+	}
+	return filepath.Clean(path), true
+}
+
+func writeFileError(rw http.ResponseWriter, r *http.Request, path string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		status = http.StatusNotFound
+	case errors.Is(err, os.ErrPermission):
+		status = http.StatusForbidden
+	}
+	httpapi.Write(r.Context(), rw, status, codersdk.Response{
+		Message: fmt.Sprintf("Could not access %q.", path),
+		Detail:  err.Error(),
+	})
+}
+
+func convertFileInfo(path string, info os.FileInfo) codersdk.WorkspaceAgentFileInfo {
+	return codersdk.WorkspaceAgentFileInfo{
+		Path:    path,
+		Size:    info.Size(),
+		Mode:    uint32(info.Mode().Perm()),
+		ModTime: info.ModTime(),
+		IsDir:   info.IsDir(),
+	}
+}
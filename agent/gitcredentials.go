@@ -0,0 +1,141 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+)
+
+// EnvGitCredentialsSocket is set in sessions to the Unix socket the git
+// credential helper ("coder gitcredentials") fetches credentials from.
+const EnvGitCredentialsSocket = "CODER_AGENT_GIT_CREDENTIALS_SOCKET"
+
+// gitCredentialsRefreshBefore is how long before a token expires the agent
+// fetches a new one, so git is never handed a token that is about to expire.
+const gitCredentialsRefreshBefore = 5 * time.Minute
+
+// gitCredentials brokers git provider tokens for the git credential helper.
+// Tokens are exchanged for the identity of the workspace with coderd, which
+// refreshes them with the git provider, and are cached until shortly before
+// they expire. Since tokens are cached by URL, a workspace can use any number
+// of git providers.
+type gitCredentials struct {
+	logger slog.Logger
+	client Client
+
+	mu     sync.Mutex
+	tokens map[string]agentsdk.GitAuthResponse
+}
+
+// listenGitCredentials serves git credentials on a Unix socket in a new
+// directory in tempDir until ctx is canceled, and returns the path of the
+// socket.
+func (a *agent) listenGitCredentials(ctx context.Context) (string, error) {
+	// The directory is only accessible by the user the agent runs as, so
+	// other users can't fetch credentials.
+	dir, err := os.MkdirTemp(a.tempDir, "coder-git-credentials-")
+	if err != nil {
+		return "", xerrors.Errorf("create socket dir: %w", err)
+	}
+	path := filepath.Join(dir, "git-credentials.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return "", xerrors.Errorf("listen: %w", err)
+	}
+
+	g := &gitCredentials{
+		logger: a.logger.Named("git-credentials"),
+		client: a.client,
+		tokens: map[string]agentsdk.GitAuthResponse{},
+	}
+	srv := &http.Server{
+		Handler:           g.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+		_ = os.RemoveAll(dir)
+	}()
+	go func() {
+		err := srv.Serve(l)
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			g.logger.Warn(ctx, "serve git credentials", slog.Error(err))
+		}
+	}()
+	return path, nil
+}
+
+func (g *gitCredentials) handler() http.Handler {
+	r := chi.NewRouter()
+	r.Get("/", g.get)
+	r.Delete("/", g.erase)
+	return r
+}
+
+// get returns the credentials for the git URL in the url query parameter.
+// If the listen query parameter is set, it waits for the user to
+// authenticate with the git provider.
+func (g *gitCredentials) get(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	gitURL := r.URL.Query().Get("url")
+	if gitURL == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Missing 'url' query parameter!",
+		})
+		return
+	}
+
+	g.mu.Lock()
+	token, ok := g.tokens[gitURL]
+	g.mu.Unlock()
+	if ok && (token.Expiry.IsZero() || time.Until(token.Expiry) > gitCredentialsRefreshBefore) {
+		httpapi.Write(ctx, rw, http.StatusOK, token)
+		return
+	}
+
+	token, err := g.client.GitAuth(ctx, gitURL, r.URL.Query().Has("listen"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		var apiErr *codersdk.Error
+		if errors.As(err, &apiErr) {
+			status = apiErr.StatusCode()
+		}
+		httpapi.Write(ctx, rw, status, codersdk.Response{
+			Message: "Failed to get git credentials.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// A URL means the user must authenticate with the git provider first.
+	if token.URL == "" {
+		g.mu.Lock()
+		g.tokens[gitURL] = token
+		g.mu.Unlock()
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, token)
+}
+
+// erase forgets the credentials for the git URL in the url query parameter.
+// Git erases credentials that are rejected by the git provider, e.g. because
+// the token was revoked, so a new token is fetched the next time.
+func (g *gitCredentials) erase(rw http.ResponseWriter, r *http.Request) {
+	g.mu.Lock()
+	delete(g.tokens, r.URL.Query().Get("url"))
+	g.mu.Unlock()
+	rw.WriteHeader(http.StatusNoContent)
+}
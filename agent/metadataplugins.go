@@ -0,0 +1,167 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+)
+
+// loadMetadataPlugins reads the metadata plugins defined in dir. Every file
+// with a .json extension defines one plugin, using the same fields as a
+// metadata block of the coder_agent resource:
+//
+//	{
+//	  "key": "load",
+//	  "display_name": "Load Average",
+//	  "script": "cat /proc/loadavg",
+//	  "interval": 10,
+//	  "timeout": 1
+//	}
+//
+// Invalid definitions are skipped and reported in the returned error, so a
+// single broken file doesn't disable the other plugins.
+func loadMetadataPlugins(fs afero.Fs, dir string) ([]codersdk.WorkspaceAgentMetadataDescription, error) {
+	entries, err := afero.ReadDir(fs, dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, xerrors.Errorf("read metadata plugin dir: %w", err)
+	}
+
+	var (
+		plugins []codersdk.WorkspaceAgentMetadataDescription
+		errs    []error
+	)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			errs = append(errs, xerrors.Errorf("read %s: %w", path, err))
+			continue
+		}
+
+		var plugin codersdk.WorkspaceAgentMetadataDescription
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&plugin)
+		switch {
+		case err != nil:
+			err = xerrors.Errorf("decode %s: %w", path, err)
+		case plugin.Key == "":
+			err = xerrors.Errorf("%s: key is required", path)
+		case plugin.Script == "":
+			err = xerrors.Errorf("%s: script is required", path)
+		case plugin.Interval < 0 || plugin.Timeout < 0:
+			err = xerrors.Errorf("%s: interval and timeout must not be negative", path)
+		case slices.ContainsFunc(plugins, func(md codersdk.WorkspaceAgentMetadataDescription) bool {
+			return md.Key == plugin.Key
+		}):
+			err = xerrors.Errorf("%s: duplicate key %q", path, plugin.Key)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		plugin.Plugin = true
+		plugins = append(plugins, plugin)
+	}
+	return plugins, errors.Join(errs...)
+}
+
+// metadataPlugins keeps the metadata plugins registered with coderd in sync
+// with the plugin definitions on disk.
+type metadataPlugins struct {
+	fs             afero.Fs
+	dir            string
+	reloadInterval time.Duration
+	// limit is the number of metadata values the agent collects, shared
+	// with the metadata of the template.
+	limit  int
+	client Client
+	logger slog.Logger
+
+	loaded     []codersdk.WorkspaceAgentMetadataDescription
+	loadErr    error
+	loadedAt   time.Time
+	registered []codersdk.WorkspaceAgentMetadataDescription
+	manifest   *agentsdk.Manifest
+	lastErr    string
+}
+
+// sync registers the plugin definitions with coderd when they changed, or
+// when the manifest changed since they were last registered (e.g. on
+// reconnect). The plugin directory is only reread every reloadInterval, not on
+// every collection tick. It returns the plugins that are currently registered.
+func (p *metadataPlugins) sync(ctx context.Context, manifest *agentsdk.Manifest) []codersdk.WorkspaceAgentMetadataDescription {
+	now := time.Now()
+	if p.loadedAt.IsZero() || now.Sub(p.loadedAt) >= p.reloadInterval {
+		p.loaded, p.loadErr = loadMetadataPlugins(p.fs, p.dir)
+		p.loadedAt = now
+	} else if p.manifest == manifest {
+		return p.registered
+	}
+
+	err := p.loadErr
+	// Plugins can't replace the metadata defined in the template.
+	plugins := slices.DeleteFunc(slices.Clone(p.loaded), func(plugin codersdk.WorkspaceAgentMetadataDescription) bool {
+		if !slices.ContainsFunc(manifest.Metadata, func(md codersdk.WorkspaceAgentMetadataDescription) bool {
+			return md.Key == plugin.Key
+		}) {
+			return false
+		}
+		err = errors.Join(err, xerrors.Errorf("key %q is already used by the metadata of the template", plugin.Key))
+		return true
+	})
+	// Register the plugins that fit next to the metadata of the template,
+	// coderd rejects the rest.
+	if available := p.limit - len(manifest.Metadata); len(plugins) > available {
+		if available < 0 {
+			available = 0
+		}
+		err = errors.Join(err, xerrors.Errorf("metadata limit of %d exceeded, ignoring %d plugins", p.limit, len(plugins)-available))
+		plugins = plugins[:available]
+	}
+	// Only log when the problem changes, the same plugins are synced
+	// over and over.
+	var errMsg string
+	if err != nil {
+		errMsg = err.Error()
+		if errMsg != p.lastErr {
+			p.logger.Warn(ctx, "invalid metadata plugins", slog.F("dir", p.dir), slog.Error(err))
+		}
+	}
+	p.lastErr = errMsg
+
+	if p.manifest == manifest && slices.Equal(plugins, p.registered) {
+		return p.registered
+	}
+	err = p.client.PostMetadataPlugins(ctx, agentsdk.PostMetadataPluginsRequest{
+		Plugins: plugins,
+	})
+	if err != nil {
+		p.logger.Error(ctx, "agent failed to register metadata plugins", slog.Error(err))
+		// Retry on the next tick.
+		p.loadedAt = time.Time{}
+		return p.registered
+	}
+	p.logger.Debug(ctx, "registered metadata plugins", slog.F("count", len(plugins)))
+	p.registered = plugins
+	p.manifest = manifest
+	return plugins
+}
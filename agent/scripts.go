@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+)
+
+// reportScriptStatusTimeout bounds reporting the status of a script, since the
+// shutdown scripts run with a context that is never canceled.
+const reportScriptStatusTimeout = 10 * time.Second
+
+// runScripts runs the scripts of the manifest that run on the given lifecycle
+// ("startup" or "shutdown") one after another, and reports the status of each
+// run to coderd. When a script fails or times out, the scripts after it are
+// skipped unless it continues on error. An error is returned if any of the
+// scripts failed.
+func (a *agent) runScripts(ctx context.Context, lifecycle string, scripts []codersdk.WorkspaceAgentScript) error {
+	var firstErr error
+	skip := false
+	for _, script := range scripts {
+		if (lifecycle == "startup" && !script.RunOnStart) || (lifecycle == "shutdown" && !script.RunOnStop) {
+			continue
+		}
+		if skip {
+			a.reportScriptStatus(ctx, agentsdk.PostScriptStatusRequest{
+				Name:   script.Name,
+				Status: codersdk.WorkspaceAgentScriptStatusSkipped,
+			})
+			continue
+		}
+
+		startedAt := time.Now()
+		a.reportScriptStatus(ctx, agentsdk.PostScriptStatusRequest{
+			Name:      script.Name,
+			Status:    codersdk.WorkspaceAgentScriptStatusRunning,
+			StartedAt: startedAt,
+		})
+		err := a.runScript(ctx, lifecycle, script.Name, script.Script, time.Duration(script.TimeoutSeconds)*time.Second)
+		if errors.Is(err, context.Canceled) {
+			return err
+		}
+		status := codersdk.WorkspaceAgentScriptStatusOK
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			status = codersdk.WorkspaceAgentScriptStatusTimedOut
+		case err != nil:
+			status = codersdk.WorkspaceAgentScriptStatusError
+		}
+		a.reportScriptStatus(ctx, agentsdk.PostScriptStatusRequest{
+			Name:      script.Name,
+			Status:    status,
+			ExitCode:  int32(scriptExitCode(err)),
+			StartedAt: startedAt,
+			EndedAt:   time.Now(),
+		})
+		if err != nil {
+			if firstErr == nil {
+				firstErr = xerrors.Errorf("run %s scripts: %w", lifecycle, err)
+			}
+			skip = !script.ContinueOnError
+		}
+	}
+	return firstErr
+}
+
+func (a *agent) reportScriptStatus(ctx context.Context, req agentsdk.PostScriptStatusRequest) {
+	ctx, cancel := context.WithTimeout(ctx, reportScriptStatusTimeout)
+	defer cancel()
+	err := a.client.PostScriptStatus(ctx, req)
+	if err != nil {
+		a.logger.Warn(ctx, "failed to report script status", slog.F("script_name", req.Name), slog.F("status", req.Status), slog.Error(err))
+	}
+}
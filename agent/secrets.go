@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"context"
+	"time"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/codersdk/agentsdk"
+)
+
+// expandSecretsTimeout bounds fetching secrets from external secret managers,
+// so an unreachable secret manager doesn't keep the agent from connecting.
+const expandSecretsTimeout = 30 * time.Second
+
+// expandSecrets replaces references to secrets in external secret managers,
+// like "secret://vault/kv/team/db#password", in the environment variables and
+// scripts of the manifest. References that can't be resolved are logged and
+// left as they are.
+func (a *agent) expandSecrets(ctx context.Context, manifest *agentsdk.Manifest) {
+	if a.secretResolver == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, expandSecretsTimeout)
+	defer cancel()
+
+	expand := func(field, s string) string {
+		expanded, err := a.secretResolver.Expand(ctx, s)
+		if err != nil {
+			a.logger.Warn(ctx, "failed to resolve secret references", slog.F("field", field), slog.Error(err))
+		}
+		return expanded
+	}
+	for key, value := range manifest.EnvironmentVariables {
+		manifest.EnvironmentVariables[key] = expand("environment variable "+key, value)
+	}
+	manifest.StartupScript = expand("startup script", manifest.StartupScript)
+	manifest.ShutdownScript = expand("shutdown script", manifest.ShutdownScript)
+	for i, script := range manifest.Scripts {
+		manifest.Scripts[i].Script = expand("script "+script.Name, script.Script)
+	}
+}
+
+// redactSecrets returns a copy of the manifest that is safe to log, with the
+// values of the secrets of the workspace owner redacted.
+func redactSecrets(manifest agentsdk.Manifest) agentsdk.Manifest {
+	if len(manifest.Secrets) == 0 {
+		return manifest
+	}
+	secrets := make(map[string]string, len(manifest.Secrets))
+	for name := range manifest.Secrets {
+		secrets[name] = "*redacted*"
+	}
+	manifest.Secrets = secrets
+	return manifest
+}
@@ -29,6 +29,7 @@ import (
 	"cdr.dev/slog/sloggers/slogjson"
 	"cdr.dev/slog/sloggers/slogstackdriver"
 	"github.com/coder/coder/agent"
+	"github.com/coder/coder/agent/agentsecrets"
 	"github.com/coder/coder/agent/reaper"
 	"github.com/coder/coder/buildinfo"
 	"github.com/coder/coder/cli/clibase"
@@ -40,6 +41,7 @@ func (r *RootCmd) workspaceAgent() *clibase.Cmd {
 	var (
 		auth                string
 		logDir              string
+		metadataPluginDir   string
 		pprofAddress        string
 		noReap              bool
 		sshMaxTimeout       time.Duration
@@ -49,6 +51,10 @@ func (r *RootCmd) workspaceAgent() *clibase.Cmd {
 		slogHumanPath       string
 		slogJSONPath        string
 		slogStackdriverPath string
+		vaultAddress        string
+		vaultAuthMount      string
+		vaultAuthRole       string
+		vaultAuthJWTFile    string
 	)
 	cmd := &clibase.Cmd{
 		Use:   "agent",
@@ -159,6 +165,17 @@ func (r *RootCmd) workspaceAgent() *clibase.Cmd {
 			defer logWriter.Close()
 
 			sinks = append(sinks, sloghuman.Sink(logWriter))
+			runningAsService := isAgentService()
+			if runningAsService {
+				// Services have no console, so the event log is the
+				// first place administrators look.
+				sink, closeSink, err := agentServiceEventLogSink()
+				if err != nil {
+					return xerrors.Errorf("open event log: %w", err)
+				}
+				sinks = append(sinks, sink)
+				logClosers = append(logClosers, closeSink)
+			}
 			logger := slog.Make(sinks...).Leveled(slog.LevelDebug)
 
 			version := buildinfo.Version()
@@ -267,10 +284,29 @@ func (r *RootCmd) workspaceAgent() *clibase.Cmd {
 				subsystems = append(subsystems, subsystem)
 			}
 
+			// Secrets are fetched with the identity of the workspace, so
+			// the backends don't need any credentials from coderd.
+			secretBackends := map[string]agentsecrets.Backend{}
+			if vaultAddress != "" {
+				secretBackends["vault"] = agentsecrets.NewVault(agentsecrets.VaultOptions{
+					Address:   vaultAddress,
+					AuthMount: vaultAuthMount,
+					AuthRole:  vaultAuthRole,
+					JWTFile:   vaultAuthJWTFile,
+				})
+			}
+			awsSecrets, err := agentsecrets.NewAWS(ctx, agentsecrets.AWSOptions{})
+			if err != nil {
+				logger.Warn(ctx, "failed to configure AWS Secrets Manager", slog.Error(err))
+			} else {
+				secretBackends["aws"] = awsSecrets
+			}
+
 			agnt := agent.New(agent.Options{
 				Client:            client,
 				Logger:            logger,
 				LogDir:            logDir,
+				MetadataPluginDir: metadataPluginDir,
 				TailnetListenPort: uint16(tailnetListenPort),
 				ExchangeToken: func(ctx context.Context) (string, error) {
 					if exchangeToken == nil {
@@ -291,6 +327,7 @@ func (r *RootCmd) workspaceAgent() *clibase.Cmd {
 				Subsystems:    subsystems,
 
 				PrometheusRegistry: prometheusRegistry,
+				SecretResolver:     agentsecrets.NewResolver(secretBackends),
 			})
 
 			prometheusSrvClose := ServeHandler(ctx, logger, prometheusMetricsHandler(prometheusRegistry, logger), prometheusAddress, "prometheus")
@@ -299,8 +336,18 @@ func (r *RootCmd) workspaceAgent() *clibase.Cmd {
 			debugSrvClose := ServeHandler(ctx, logger, agnt.HTTPDebug(), debugAddress, "debug")
 			defer debugSrvClose()
 
-			<-ctx.Done()
-			return agnt.Close()
+			run := func(ctx context.Context) error {
+				<-ctx.Done()
+				return agnt.Close()
+			}
+			if runningAsService {
+				return runAgentService(ctx, logger, run)
+			}
+			return run(ctx)
+		},
+		Children: []*clibase.Cmd{
+			r.workspaceAgentInstallService(),
+			r.workspaceAgentUninstallService(),
 		},
 	}
 
@@ -319,6 +366,12 @@ func (r *RootCmd) workspaceAgent() *clibase.Cmd {
 			Env:         "CODER_AGENT_LOG_DIR",
 			Value:       clibase.StringOf(&logDir),
 		},
+		{
+			Flag:        "metadata-plugin-dir",
+			Description: "Specify a directory of metadata plugin definitions (*.json files) to collect in addition to the metadata defined in the template.",
+			Env:         "CODER_AGENT_METADATA_PLUGIN_DIR",
+			Value:       clibase.StringOf(&metadataPluginDir),
+		},
 		{
 			Flag:        "pprof-address",
 			Default:     "127.0.0.1:6060",
@@ -348,6 +401,32 @@ func (r *RootCmd) workspaceAgent() *clibase.Cmd {
 			Description: "Specify a static port for Tailscale to use for listening.",
 			Value:       clibase.Int64Of(&tailnetListenPort),
 		},
+		{
+			Flag:        "vault-address",
+			Env:         "CODER_AGENT_VAULT_ADDRESS",
+			Description: "The address of a HashiCorp Vault server to resolve secret://vault/ references in the environment variables and scripts of the agent with.",
+			Value:       clibase.StringOf(&vaultAddress),
+		},
+		{
+			Flag:        "vault-auth-mount",
+			Default:     "kubernetes",
+			Env:         "CODER_AGENT_VAULT_AUTH_MOUNT",
+			Description: "The path the JWT or Kubernetes auth method of Vault is mounted at.",
+			Value:       clibase.StringOf(&vaultAuthMount),
+		},
+		{
+			Flag:        "vault-auth-role",
+			Env:         "CODER_AGENT_VAULT_AUTH_ROLE",
+			Description: "The Vault role to log in as.",
+			Value:       clibase.StringOf(&vaultAuthRole),
+		},
+		{
+			Flag:        "vault-auth-jwt-file",
+			Default:     agentsecrets.DefaultVaultJWTFile,
+			Env:         "CODER_AGENT_VAULT_AUTH_JWT_FILE",
+			Description: "The file of the JWT identifying the workspace to log in to Vault with.",
+			Value:       clibase.StringOf(&vaultAuthJWTFile),
+		},
 		{
 			Flag:        "prometheus-address",
 			Default:     "127.0.0.1:2112",
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+)
+
+const (
+	agentServiceName        = "CoderAgent"
+	agentServiceDisplayName = "Coder Agent"
+	agentServiceDescription = "Connects this machine to Coder as a workspace agent."
+)
+
+// agentServiceConfig describes how the agent is installed as a Windows
+// service.
+type agentServiceConfig struct {
+	// Executable is the path to the coder binary the service runs.
+	Executable string
+	// Environ is the environment the service runs the agent with, in
+	// KEY=value form.
+	Environ []string
+	// Start starts the service after it's installed.
+	Start bool
+}
+
+func (r *RootCmd) workspaceAgentInstallService() *clibase.Cmd {
+	var noStart bool
+	cmd := &clibase.Cmd{
+		Use:   "install-service",
+		Short: "Install the agent as a Windows service.",
+		Long: "The service starts with Windows, is restarted automatically when the agent exits " +
+			"unexpectedly, and logs to the Windows event log. It keeps running when users log " +
+			"off or disconnect from a Remote Desktop session. The agent options and the CODER_AGENT_* " +
+			"environment variables given to this command are stored with the service.",
+		Handler: func(inv *clibase.Invocation) error {
+			executable, err := os.Executable()
+			if err != nil {
+				return xerrors.Errorf("get executable: %w", err)
+			}
+
+			cfg := agentServiceConfig{
+				Executable: executable,
+				Environ:    agentServiceEnviron(inv.Environ, inv.Command.FullOptions()),
+				Start:      !noStart,
+			}
+			err = installAgentService(inv.Context(), cfg)
+			if err != nil {
+				return xerrors.Errorf("install service: %w", err)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "The %s service has been installed!\n", cliui.DefaultStyles.Keyword.Render(agentServiceName))
+			return nil
+		},
+	}
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:        "no-start",
+			Description: "Do not start the service after installing it.",
+			Value:       clibase.BoolOf(&noStart),
+		},
+	}
+	return cmd
+}
+
+func (*RootCmd) workspaceAgentUninstallService() *clibase.Cmd {
+	return &clibase.Cmd{
+		Use:   "uninstall-service",
+		Short: "Stop and remove the agent Windows service.",
+		Handler: func(inv *clibase.Invocation) error {
+			err := uninstallAgentService(inv.Context())
+			if err != nil {
+				return xerrors.Errorf("uninstall service: %w", err)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "The %s service has been removed!\n", cliui.DefaultStyles.Keyword.Render(agentServiceName))
+			return nil
+		},
+	}
+}
+
+// agentServiceEnviron returns the CODER_AGENT_* environment the service runs
+// the agent with. Options set by flags override the environment, so the
+// service runs the agent exactly like the install command would.
+func agentServiceEnviron(environ clibase.Environ, opts clibase.OptionSet) []string {
+	vars := map[string]string{}
+	for _, env := range environ {
+		if strings.HasPrefix(env.Name, "CODER_AGENT_") {
+			vars[env.Name] = env.Value
+		}
+	}
+	for _, opt := range opts {
+		if opt.ValueSource != clibase.ValueSourceFlag || !strings.HasPrefix(opt.Env, "CODER_AGENT_") {
+			continue
+		}
+		vars[opt.Env] = opt.Value.String()
+	}
+
+	serviceEnviron := make([]string, 0, len(vars))
+	for name, value := range vars {
+		serviceEnviron = append(serviceEnviron, name+"="+value)
+	}
+	sort.Strings(serviceEnviron)
+	return serviceEnviron
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clibase"
+)
+
+func Test_agentServiceEnviron(t *testing.T) {
+	t.Parallel()
+
+	var (
+		auth     = "token"
+		url      = "https://flag.example.com"
+		logDir   = "/tmp"
+		pprofURL = "127.0.0.1:6060"
+	)
+	opts := clibase.OptionSet{
+		{Env: "CODER_AGENT_AUTH", Value: clibase.StringOf(&auth), ValueSource: clibase.ValueSourceFlag},
+		{Env: "CODER_AGENT_URL", Value: clibase.StringOf(&url), ValueSource: clibase.ValueSourceFlag},
+		// Options that weren't set by a flag are taken from the environment.
+		{Env: "CODER_AGENT_LOG_DIR", Value: clibase.StringOf(&logDir), ValueSource: clibase.ValueSourceEnv},
+		// Options that aren't agent options are never stored.
+		{Env: "CODER_PPROF_ADDRESS", Value: clibase.StringOf(&pprofURL), ValueSource: clibase.ValueSourceFlag},
+	}
+	environ := clibase.Environ{
+		{Name: "CODER_AGENT_URL", Value: "https://env.example.com"},
+		{Name: "CODER_AGENT_LOG_DIR", Value: "/var/log"},
+		{Name: "CODER_SESSION_TOKEN", Value: "secret"},
+		{Name: "PATH", Value: "/usr/bin"},
+	}
+
+	require.Equal(t, []string{
+		"CODER_AGENT_AUTH=token",
+		"CODER_AGENT_LOG_DIR=/var/log",
+		"CODER_AGENT_URL=https://flag.example.com",
+	}, agentServiceEnviron(environ, opts))
+	require.Empty(t, agentServiceEnviron(nil, nil))
+}
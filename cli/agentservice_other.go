@@ -0,0 +1,33 @@
+//go:build !windows
+
+package cli
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+var errAgentServiceUnsupported = xerrors.New("the agent can only run as a service on Windows")
+
+func isAgentService() bool {
+	return false
+}
+
+func runAgentService(_ context.Context, _ slog.Logger, _ func(context.Context) error) error {
+	return errAgentServiceUnsupported
+}
+
+func agentServiceEventLogSink() (slog.Sink, func() error, error) {
+	return nil, nil, errAgentServiceUnsupported
+}
+
+func installAgentService(_ context.Context, _ agentServiceConfig) error {
+	return errAgentServiceUnsupported
+}
+
+func uninstallAgentService(_ context.Context) error {
+	return errAgentServiceUnsupported
+}
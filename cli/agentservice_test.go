@@ -0,0 +1,29 @@
+package cli_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+)
+
+func TestWorkspaceAgentService(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("installing the service requires an administrator")
+	}
+
+	for _, cmd := range []string{"install-service", "uninstall-service"} {
+		cmd := cmd
+		t.Run(cmd, func(t *testing.T) {
+			t.Parallel()
+
+			inv, _ := clitest.New(t, "agent", cmd)
+			err := inv.Run()
+			require.ErrorContains(t, err, "the agent can only run as a service on Windows")
+		})
+	}
+}
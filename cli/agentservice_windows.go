@@ -0,0 +1,284 @@
+//go:build windows
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/registry"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+)
+
+// agentServiceEventID is the ID of all events the agent writes to the event
+// log. The agent is installed with EventCreate as the message file, which only
+// supports IDs from 1 to 1000.
+const agentServiceEventID = 1
+
+func isAgentService() bool {
+	isService, err := svc.IsWindowsService()
+	return err == nil && isService
+}
+
+// runAgentService reports the agent to the service control manager and runs
+// fn until the service is stopped. The context passed to fn is canceled when
+// the service control manager stops the service or Windows shuts down.
+func runAgentService(ctx context.Context, logger slog.Logger, fn func(context.Context) error) error {
+	handler := &agentServiceHandler{
+		ctx:    ctx,
+		logger: logger.Named("service"),
+		fn:     fn,
+	}
+	err := svc.Run(agentServiceName, handler)
+	if err != nil {
+		return xerrors.Errorf("run service: %w", err)
+	}
+	return handler.err
+}
+
+type agentServiceHandler struct {
+	ctx    context.Context
+	logger slog.Logger
+	fn     func(context.Context) error
+	err    error
+}
+
+func (h *agentServiceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (svcSpecificEC bool, exitCode uint32) {
+	const accepts = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptSessionChange
+
+	status <- svc.Status{State: svc.StartPending}
+	ctx, cancel := context.WithCancel(h.ctx)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- h.fn(ctx)
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: accepts}
+
+	stopping := false
+	for {
+		select {
+		case h.err = <-done:
+			status <- svc.Status{State: svc.StopPending}
+			// Exiting with an error when the service wasn't asked to
+			// stop makes the service control manager restart the agent.
+			if !stopping {
+				h.logger.Error(h.ctx, "agent exited unexpectedly", slog.Error(h.err))
+				return true, 1
+			}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				h.logger.Info(h.ctx, "service is stopping", slog.F("shutdown", req.Cmd == svc.Shutdown))
+				stopping = true
+				status <- svc.Status{State: svc.StopPending}
+				cancel()
+			case svc.SessionChange:
+				h.logSessionChange(req)
+			}
+		}
+	}
+}
+
+// logSessionChange logs users logging on and off and connecting to the
+// machine over Remote Desktop. The service keeps running through all of
+// them, unlike an agent started in a user session.
+func (h *agentServiceHandler) logSessionChange(req svc.ChangeRequest) {
+	var event string
+	switch req.EventType {
+	case windows.WTS_SESSION_LOGON:
+		event = "logon"
+	case windows.WTS_SESSION_LOGOFF:
+		event = "logoff"
+	case windows.WTS_REMOTE_CONNECT:
+		event = "remote_connect"
+	case windows.WTS_REMOTE_DISCONNECT:
+		event = "remote_disconnect"
+	case windows.WTS_CONSOLE_CONNECT:
+		event = "console_connect"
+	case windows.WTS_CONSOLE_DISCONNECT:
+		event = "console_disconnect"
+	default:
+		return
+	}
+	h.logger.Info(h.ctx, "session changed", slog.F("event", event))
+}
+
+// agentServiceEventLogSink returns a sink that writes info and higher log
+// entries to the Windows event log.
+func agentServiceEventLogSink() (slog.Sink, func() error, error) {
+	log, err := eventlog.Open(agentServiceName)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("open event log: %w", err)
+	}
+	sink := &eventLogSink{log: log}
+	sink.human = sloghuman.Sink(&sink.buf)
+	return sink, log.Close, nil
+}
+
+type eventLogSink struct {
+	log *eventlog.Log
+
+	mu    sync.Mutex // Protects following.
+	buf   bytes.Buffer
+	human slog.Sink
+}
+
+func (s *eventLogSink) LogEntry(ctx context.Context, e slog.SinkEntry) {
+	if e.Level < slog.LevelInfo {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf.Reset()
+	s.human.LogEntry(ctx, e)
+	msg := strings.TrimSpace(s.buf.String())
+	switch {
+	case e.Level >= slog.LevelError:
+		_ = s.log.Error(agentServiceEventID, msg)
+	case e.Level == slog.LevelWarn:
+		_ = s.log.Warning(agentServiceEventID, msg)
+	default:
+		_ = s.log.Info(agentServiceEventID, msg)
+	}
+}
+
+func (*eventLogSink) Sync() {}
+
+func installAgentService(_ context.Context, cfg agentServiceConfig) (err error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return xerrors.Errorf("connect to service control manager (are you running as an administrator?): %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(agentServiceName)
+	if err == nil {
+		_ = s.Close()
+		return xerrors.Errorf("service %q is already installed", agentServiceName)
+	}
+	s, err = m.CreateService(agentServiceName, cfg.Executable, mgr.Config{
+		DisplayName: agentServiceDisplayName,
+		Description: agentServiceDescription,
+		StartType:   mgr.StartAutomatic,
+		// The agent retries connecting anyway, but starting after the
+		// network is up avoids a burst of errors on boot.
+		DelayedAutoStart: true,
+	}, "agent")
+	if err != nil {
+		return xerrors.Errorf("create service: %w", err)
+	}
+	defer s.Close()
+	// Don't leave a half configured service behind.
+	defer func() {
+		if err != nil {
+			_ = s.Delete()
+			_ = eventlog.Remove(agentServiceName)
+		}
+	}()
+
+	const resetPeriod = 24 * time.Hour
+	err = s.SetRecoveryActions([]mgr.RecoveryAction{
+		{Type: mgr.ServiceRestart, Delay: 5 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 10 * time.Second},
+		{Type: mgr.ServiceRestart, Delay: 30 * time.Second},
+	}, uint32(resetPeriod.Seconds()))
+	if err != nil {
+		return xerrors.Errorf("set recovery actions: %w", err)
+	}
+	// The agent exits with an error instead of crashing, so recovery must
+	// also apply to that.
+	err = s.SetRecoveryActionsOnNonCrashFailures(true)
+	if err != nil {
+		return xerrors.Errorf("set recovery on non-crash failures: %w", err)
+	}
+
+	if len(cfg.Environ) > 0 {
+		var key registry.Key
+		key, err = registry.OpenKey(registry.LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Services\`+agentServiceName, registry.SET_VALUE)
+		if err != nil {
+			return xerrors.Errorf("open service registry key: %w", err)
+		}
+		err = key.SetStringsValue("Environment", cfg.Environ)
+		_ = key.Close()
+		if err != nil {
+			return xerrors.Errorf("set service environment: %w", err)
+		}
+	}
+
+	err = eventlog.InstallAsEventCreate(agentServiceName, eventlog.Error|eventlog.Warning|eventlog.Info)
+	if err != nil {
+		return xerrors.Errorf("install event log source: %w", err)
+	}
+
+	if cfg.Start {
+		err = s.Start()
+		if err != nil {
+			return xerrors.Errorf("start service: %w", err)
+		}
+	}
+	return nil
+}
+
+func uninstallAgentService(ctx context.Context) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return xerrors.Errorf("connect to service control manager (are you running as an administrator?): %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(agentServiceName)
+	if err != nil {
+		return xerrors.Errorf("open service %q: %w", agentServiceName, err)
+	}
+	defer s.Close()
+
+	status, err := s.Control(svc.Stop)
+	if err != nil && !errors.Is(err, windows.ERROR_SERVICE_NOT_ACTIVE) {
+		return xerrors.Errorf("stop service: %w", err)
+	}
+	if err == nil {
+		// Give the agent time to shut down gracefully, like the service
+		// control manager does on shutdown.
+		ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+		for status.State != svc.Stopped {
+			select {
+			case <-ctx.Done():
+				return xerrors.Errorf("wait for service to stop: %w", ctx.Err())
+			case <-ticker.C:
+			}
+			status, err = s.Query()
+			if err != nil {
+				return xerrors.Errorf("query service: %w", err)
+			}
+		}
+	}
+
+	err = s.Delete()
+	if err != nil {
+		return xerrors.Errorf("delete service: %w", err)
+	}
+	err = eventlog.Remove(agentServiceName)
+	if err != nil {
+		return xerrors.Errorf("remove event log source: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+//go:build windows
+
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/testutil"
+)
+
+func TestAgentServiceHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Stop", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		handler := &agentServiceHandler{
+			ctx:    ctx,
+			logger: slogtest.Make(t, nil),
+			fn: func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+		}
+		requests := make(chan svc.ChangeRequest, 2)
+		status := make(chan svc.Status, 10)
+
+		running := svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown | svc.AcceptSessionChange}
+		requests <- svc.ChangeRequest{Cmd: svc.Interrogate, CurrentStatus: running}
+		requests <- svc.ChangeRequest{Cmd: svc.Stop}
+		svcSpecificEC, exitCode := handler.Execute(nil, requests, status)
+		require.False(t, svcSpecificEC)
+		require.Zero(t, exitCode)
+		require.NoError(t, handler.err)
+
+		close(status)
+		var states []svc.State
+		for s := range status {
+			states = append(states, s.State)
+		}
+		require.Equal(t, []svc.State{svc.StartPending, svc.Running, svc.Running, svc.StopPending, svc.StopPending}, states)
+	})
+
+	t.Run("UnexpectedExit", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		handler := &agentServiceHandler{
+			ctx:    ctx,
+			logger: slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}),
+			fn: func(context.Context) error {
+				return xerrors.New("agent failed")
+			},
+		}
+		requests := make(chan svc.ChangeRequest)
+		status := make(chan svc.Status, 10)
+
+		// The service control manager only restarts the agent if the service
+		// exits with an error.
+		svcSpecificEC, exitCode := handler.Execute(nil, requests, status)
+		require.True(t, svcSpecificEC)
+		require.EqualValues(t, 1, exitCode)
+		require.ErrorContains(t, handler.err, "agent failed")
+	})
+}
@@ -187,10 +187,12 @@ func ProvisionerJob(ctx context.Context, writer io.Writer, opts ProvisionerJobOp
 			jobMutex.Lock()
 			if log.Stage != currentStage && log.Stage != "" {
 				updateStage(log.Stage, log.CreatedAt)
+				sw.Progress(log.Progress)
 				jobMutex.Unlock()
 				continue
 			}
 			sw.Log(log.CreatedAt, log.Level, log.Output)
+			sw.Progress(log.Progress)
 			jobMutex.Unlock()
 		}
 	}
@@ -201,6 +203,8 @@ type stageWriter struct {
 	verbose    bool
 	silentLogs bool
 	logBuf     bytes.Buffer
+	// progress is the last progress that was rendered.
+	progress codersdk.ProvisionerJobLogProgress
 }
 
 func (s *stageWriter) Start(stage string) {
@@ -259,6 +263,41 @@ func (s *stageWriter) Log(createdAt time.Time, level codersdk.LogLevel, line str
 	_, _ = fmt.Fprintf(w, "%s\n", render(lines...))
 }
 
+// Progress renders a progress bar when more resources are done. Progress
+// without a known number of resources isn't rendered.
+func (s *stageWriter) Progress(progress *codersdk.ProvisionerJobLogProgress) {
+	if progress == nil || progress.Total <= 0 || s.silentLogs {
+		return
+	}
+	if progress.Stage == s.progress.Stage &&
+		progress.Completed == s.progress.Completed &&
+		progress.Total == s.progress.Total {
+		return
+	}
+	s.progress = *progress
+	_, _ = fmt.Fprintf(s.w, "%s\n", renderProgress(*progress))
+}
+
+const progressBarWidth = 30
+
+func renderProgress(progress codersdk.ProvisionerJobLogProgress) string {
+	completed := progress.Completed
+	if completed > progress.Total {
+		completed = progress.Total
+	}
+	filled := int(completed) * progressBarWidth / int(progress.Total)
+	bar := strings.Repeat("█", filled) + DefaultStyles.Placeholder.Render(strings.Repeat("░", progressBarWidth-filled))
+
+	title := string(progress.Stage)
+	switch progress.Stage {
+	case codersdk.ProvisionerJobLogStagePlan:
+		title = "Planned"
+	case codersdk.ProvisionerJobLogStageApply:
+		title = "Applied"
+	}
+	return fmt.Sprintf("%s %s %d/%d resources", title, bar, completed, progress.Total)
+}
+
 func (s *stageWriter) flushLogs() {
 	if s.silentLogs {
 		_, _ = io.Copy(s.w, &s.logBuf)
@@ -2,6 +2,7 @@ package cliui_test
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"os"
 	"runtime"
@@ -16,6 +17,7 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
 )
 
 // This cannot be ran in parallel because it uses a signal.
@@ -79,6 +81,49 @@ func TestProvisionerJob(t *testing.T) {
 		test.PTY.ExpectMatch("Something")
 	})
 
+	t.Run("Progress", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		test := newProvisionerJob(t)
+		go func() {
+			<-test.Next
+			test.JobMutex.Lock()
+			test.Job.Status = codersdk.ProvisionerJobRunning
+			now := database.Now()
+			test.Job.StartedAt = &now
+			test.JobMutex.Unlock()
+			for _, completed := range []int32{0, 1, 1, 2} {
+				test.Logs <- codersdk.ProvisionerJobLog{
+					CreatedAt: database.Now(),
+					Level:     codersdk.LogLevelInfo,
+					Output:    fmt.Sprintf("Resource %d", completed),
+					Progress: &codersdk.ProvisionerJobLogProgress{
+						Stage:     codersdk.ProvisionerJobLogStageApply,
+						Completed: completed,
+						Total:     2,
+					},
+				}
+			}
+			<-test.Next
+			test.JobMutex.Lock()
+			test.Job.Status = codersdk.ProvisionerJobSucceeded
+			now = database.Now()
+			test.Job.CompletedAt = &now
+			close(test.Logs)
+			test.JobMutex.Unlock()
+		}()
+		test.PTY.ExpectMatch("Queued")
+		test.Next <- struct{}{}
+		test.PTY.ExpectMatch("0/2 resources")
+		test.PTY.ExpectMatch("Resource 1")
+		test.PTY.ExpectMatch("1/2 resources")
+		// Progress is only rendered again once more resources are done.
+		test.PTY.ExpectNoMatchBefore(ctx, "1/2 resources", "Resource 2")
+		test.PTY.ExpectMatch("2/2 resources")
+		test.Next <- struct{}{}
+	})
+
 	// This cannot be ran in parallel because it uses a signal.
 	// nolint:paralleltest
 	t.Run("Cancel", func(t *testing.T) {
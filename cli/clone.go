@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) clone() *clibase.Cmd {
+	var copyVolumes bool
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Annotations: workspaceCommand,
+		Use:         "clone <workspace> <name>",
+		Short:       "Clone a workspace",
+		Long: "The clone uses the template version and parameter values of the latest build of the workspace. Ephemeral parameters are not copied.\n\n" + formatExamples(
+			example{
+				Description: "Clone a workspace",
+				Command:     "coder clone my-workspace my-workspace-copy",
+			},
+			example{
+				Description: "Clone a workspace including the contents of its volumes",
+				Command:     "coder clone my-workspace my-workspace-copy --copy-volumes",
+			},
+		),
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			source, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get workspace: %w", err)
+			}
+
+			_, err = cliui.Prompt(inv, cliui.PromptOptions{
+				Text:      fmt.Sprintf("Confirm clone of %s?", cliui.DefaultStyles.Keyword.Render(source.Name)),
+				IsConfirm: true,
+			})
+			if err != nil {
+				return err
+			}
+
+			workspace, err := client.CloneWorkspace(inv.Context(), source.ID, codersdk.CloneWorkspaceRequest{
+				Name:        inv.Args[1],
+				CopyVolumes: copyVolumes,
+			})
+			if err != nil {
+				return xerrors.Errorf("clone workspace: %w", err)
+			}
+
+			err = cliui.WorkspaceBuild(inv.Context(), inv.Stdout, client, workspace.LatestBuild.ID)
+			if err != nil {
+				return xerrors.Errorf("watch build: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "\nThe %s workspace has been cloned from %s at %s!\n",
+				cliui.DefaultStyles.Keyword.Render(workspace.Name),
+				cliui.DefaultStyles.Keyword.Render(source.Name),
+				cliui.DefaultStyles.DateTimeStamp.Render(time.Now().Format(time.Stamp)),
+			)
+			return nil
+		},
+	}
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:        "copy-volumes",
+			Description: "Copy the contents of the persistent volumes of the workspace. The template must support copying volumes, e.g. from snapshots.",
+			Value:       clibase.BoolOf(&copyVolumes),
+		},
+		cliui.SkipPromptOption(),
+	}
+	return cmd
+}
@@ -0,0 +1,44 @@
+package cli_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	inv, root := clitest.New(t, "clone", workspace.Name, "clone")
+	clitest.SetupConfig(t, client, root)
+	pty := ptytest.New(t)
+	pty.Attach(inv)
+	clitest.Start(t, inv)
+
+	pty.ExpectMatch("Confirm clone")
+	pty.WriteLine("yes")
+	pty.ExpectMatch("has been cloned")
+
+	clone, err := client.WorkspaceByOwnerAndName(ctx, codersdk.Me, "clone", codersdk.WorkspaceOptions{})
+	require.NoError(t, err)
+	require.Equal(t, workspace.TemplateID, clone.TemplateID)
+	require.Equal(t, version.ID, clone.LatestBuild.TemplateVersionID)
+}
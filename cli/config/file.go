@@ -46,6 +46,19 @@ func (r Root) Organization() File {
 	return File(filepath.Join(string(r), "organization"))
 }
 
+// Proxy is the name of the region selected with coder proxy select.
+func (r Root) Proxy() File {
+	r.mustNotEmpty()
+	return File(filepath.Join(string(r), "proxy"))
+}
+
+// FastestProxy caches the name of the region with the lowest latency, so
+// connections don't measure the latency to every region.
+func (r Root) FastestProxy() File {
+	r.mustNotEmpty()
+	return File(filepath.Join(string(r), "fastest_proxy"))
+}
+
 func (r Root) DotfilesURL() File {
 	r.mustNotEmpty()
 	return File(filepath.Join(string(r), "dotfilesurl"))
@@ -47,6 +47,7 @@ const (
 // from the coder config in ~/.ssh/coder.
 type sshConfigOptions struct {
 	waitEnum       string
+	multiplex      bool
 	userHostPrefix string
 	sshOptions     []string
 }
@@ -106,13 +107,16 @@ func (o sshConfigOptions) equal(other sshConfigOptions) bool {
 	if !slices.Equal(opt1, opt2) {
 		return false
 	}
-	return o.waitEnum == other.waitEnum && o.userHostPrefix == other.userHostPrefix
+	return o.waitEnum == other.waitEnum && o.multiplex == other.multiplex && o.userHostPrefix == other.userHostPrefix
 }
 
 func (o sshConfigOptions) asList() (list []string) {
 	if o.waitEnum != "auto" {
 		list = append(list, fmt.Sprintf("wait: %s", o.waitEnum))
 	}
+	if o.multiplex {
+		list = append(list, "multiplex: true")
+	}
 	if o.userHostPrefix != "" {
 		list = append(list, fmt.Sprintf("ssh-host-prefix: %s", o.userHostPrefix))
 	}
@@ -226,6 +230,9 @@ func (r *RootCmd) configSSH() *clibase.Cmd {
 				// specifies skip-proxy-command, then wait cannot be applied.
 				return xerrors.Errorf("cannot specify both --skip-proxy-command and --wait")
 			}
+			if sshConfigOpts.multiplex && skipProxyCommand {
+				return xerrors.Errorf("cannot specify both --skip-proxy-command and --multiplex")
+			}
 
 			recvWorkspaceConfigs := sshPrepareWorkspaceConfigs(inv.Context(), client)
 
@@ -393,6 +400,9 @@ func (r *RootCmd) configSSH() *clibase.Cmd {
 						if sshConfigOpts.waitEnum != "auto" {
 							flags += " --wait=" + sshConfigOpts.waitEnum
 						}
+						if sshConfigOpts.multiplex {
+							flags += " --multiplex"
+						}
 						defaultOptions = append(defaultOptions, fmt.Sprintf(
 							"ProxyCommand %s --global-config %s ssh --stdio%s %s",
 							escapedCoderBinary, escapedGlobalConfig, flags, workspaceHostname,
@@ -567,6 +577,12 @@ func (r *RootCmd) configSSH() *clibase.Cmd {
 			Default:     "auto",
 			Value:       clibase.EnumOf(&sshConfigOpts.waitEnum, "yes", "no", "auto"),
 		},
+		{
+			Flag:        "multiplex",
+			Env:         "CODER_CONFIGSSH_MULTIPLEX",
+			Description: "Specifies whether concurrent SSH sessions to a workspace share a single connection. Reduces the time new sessions take to connect, e.g. for IDEs that open many sessions.",
+			Value:       clibase.BoolOf(&sshConfigOpts.multiplex),
+		},
 		{
 			Flag: "force-unix-filepaths",
 			Env:  "CODER_CONFIGSSH_UNIX_FILEPATHS",
@@ -600,6 +616,9 @@ func sshConfigWriteSectionHeader(w io.Writer, addNewline bool, o sshConfigOption
 	if o.waitEnum != "auto" {
 		_, _ = fmt.Fprintf(&ow, "# :%s=%s\n", "wait", o.waitEnum)
 	}
+	if o.multiplex {
+		_, _ = fmt.Fprintf(&ow, "# :%s=%t\n", "multiplex", o.multiplex)
+	}
 	if o.userHostPrefix != "" {
 		_, _ = fmt.Fprintf(&ow, "# :%s=%s\n", "ssh-host-prefix", o.userHostPrefix)
 	}
@@ -631,6 +650,8 @@ func sshConfigParseLastOptions(r io.Reader) (o sshConfigOptions) {
 			switch parts[0] {
 			case "wait":
 				o.waitEnum = parts[1]
+			case "multiplex":
+				o.multiplex = parts[1] == "true"
 			case "ssh-host-prefix":
 				o.userHostPrefix = parts[1]
 			case "ssh-option":
@@ -490,6 +490,7 @@ func TestConfigSSH_FileWriteAndOptionsFlow(t *testing.T) {
 					headerStart,
 					"# Last config-ssh options:",
 					"# :wait=yes",
+					"# :multiplex=true",
 					"# :ssh-host-prefix=coder-test.",
 					"#",
 					headerEnd,
@@ -499,6 +500,7 @@ func TestConfigSSH_FileWriteAndOptionsFlow(t *testing.T) {
 			args: []string{
 				"--yes",
 				"--wait=yes",
+				"--multiplex",
 				"--ssh-host-prefix", "coder-test.",
 			},
 		},
@@ -595,6 +597,20 @@ func TestConfigSSH_FileWriteAndOptionsFlow(t *testing.T) {
 				regexMatch: "ProxyCommand /foo/bar/coder",
 			},
 		},
+		{
+			name: "Multiplex",
+			args: []string{
+				"-y", "--multiplex",
+			},
+			wantErr: false,
+			echoResponse: &echo.Responses{
+				Parse:          echo.ParseComplete,
+				ProvisionApply: echo.ProvisionApplyWithAgent(""),
+			},
+			wantConfig: wantConfig{
+				regexMatch: `ProxyCommand .* ssh --stdio --multiplex `,
+			},
+		},
 	}
 	for _, tt := range tests {
 		tt := tt
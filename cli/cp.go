@@ -0,0 +1,343 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) cp() *clibase.Cmd {
+	var resume bool
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Annotations: workspaceCommand,
+		Use:         "cp <source> <destination>",
+		Short:       "Copy a file between your machine and a workspace",
+		Long: "Paths in a workspace are prefixed with the workspace name and a colon. Relative paths in a workspace are relative to the working directory of SSH sessions.\n\n" + formatExamples(
+			example{
+				Description: "Upload a file to the home directory of a workspace",
+				Command:     "coder cp ./notes.txt my-workspace:~/notes.txt",
+			},
+			example{
+				Description: "Download a file from a specific agent of a workspace to the current directory",
+				Command:     "coder cp my-workspace.main:/var/log/app.log .",
+			},
+			example{
+				Description: "Resume an interrupted download",
+				Command:     "coder cp --resume my-workspace:backup.tar.gz .",
+			},
+		),
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx, cancel := context.WithCancel(inv.Context())
+			defer cancel()
+
+			src, dst := parseCopyTarget(inv.Args[0]), parseCopyTarget(inv.Args[1])
+			if (src.workspace == "") == (dst.workspace == "") {
+				return xerrors.New("exactly one of the source and destination must be in a workspace, e.g. my-workspace:~/file")
+			}
+			workspaceName := src.workspace
+			if workspaceName == "" {
+				workspaceName = dst.workspace
+			}
+
+			_, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, codersdk.Me, workspaceName)
+			if err != nil {
+				return err
+			}
+			err = cliui.Agent(ctx, inv.Stderr, workspaceAgent.ID, cliui.AgentOptions{
+				Fetch: client.WorkspaceAgent,
+				Wait:  false,
+			})
+			if err != nil {
+				return xerrors.Errorf("await agent: %w", err)
+			}
+
+			logger, ok := LoggerFromContext(ctx)
+			if !ok {
+				logger = slog.Make(sloghuman.Sink(inv.Stderr))
+			}
+			if r.verbose {
+				logger = logger.Leveled(slog.LevelDebug)
+			}
+			conn, err := client.DialWorkspaceAgent(ctx, workspaceAgent.ID, &codersdk.DialWorkspaceAgentOptions{
+				Logger:           logger,
+				PreferDERPRegion: r.preferDERPRegion(ctx, inv, logger, client),
+			})
+			if err != nil {
+				return xerrors.Errorf("dial workspace agent: %w", err)
+			}
+			defer conn.Close()
+			if !conn.AwaitReachable(ctx) {
+				return xerrors.Errorf("workspace agent not reachable in time: %w", ctx.Err())
+			}
+
+			c := &fileCopier{
+				inv:    inv,
+				conn:   conn,
+				resume: resume,
+			}
+			if src.workspace == "" {
+				return c.upload(ctx, src.path, dst.path)
+			}
+			return c.download(ctx, src.path, dst.path)
+		},
+	}
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:          "resume",
+			FlagShorthand: "r",
+			Description:   "Resume an interrupted copy by appending to the destination file instead of replacing it.",
+			Value:         clibase.BoolOf(&resume),
+		},
+	}
+	return cmd
+}
+
+type copyTarget struct {
+	// workspace is empty for local paths.
+	workspace string
+	path      string
+}
+
+// parseCopyTarget splits "<workspace>:<path>" arguments. Arguments without a
+// colon, or that start like a local path, are local.
+func parseCopyTarget(arg string) copyTarget {
+	workspace, p, ok := strings.Cut(arg, ":")
+	if !ok || workspace == "" || strings.ContainsAny(workspace[:1], `./~\`) {
+		return copyTarget{path: arg}
+	}
+	// Drive letters, e.g. C:\Users.
+	if runtime.GOOS == "windows" && len(workspace) == 1 {
+		return copyTarget{path: arg}
+	}
+	if p == "" {
+		p = "."
+	}
+	return copyTarget{workspace: workspace, path: p}
+}
+
+type fileCopier struct {
+	inv    *clibase.Invocation
+	conn   *codersdk.WorkspaceAgentConn
+	resume bool
+}
+
+func (c *fileCopier) upload(ctx context.Context, src, dst string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return xerrors.Errorf("open source: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return xerrors.Errorf("stat source: %w", err)
+	}
+	if info.IsDir() {
+		return xerrors.Errorf("%q is a directory, only files can be copied", src)
+	}
+
+	// Copying into a directory keeps the name of the source.
+	dstInfo, err := c.conn.StatFile(ctx, dst)
+	if err == nil && dstInfo.IsDir {
+		dst = path.Join(dstInfo.Path, filepath.Base(src))
+		dstInfo, err = c.conn.StatFile(ctx, dst)
+	}
+	exists := err == nil
+	if err != nil && !isNotFound(err) {
+		return xerrors.Errorf("stat destination: %w", err)
+	}
+
+	var offset int64
+	if c.resume && exists {
+		offset, err = resumeOffset(dstInfo.Size, info.Size())
+		if err != nil {
+			return err
+		}
+		_, err = f.Seek(offset, io.SeekStart)
+		if err != nil {
+			return xerrors.Errorf("seek source: %w", err)
+		}
+	}
+
+	progress := c.progress(filepath.Base(src), offset, info.Size())
+	uploaded, err := c.conn.UploadFile(ctx, dst, io.TeeReader(f, progress), codersdk.WorkspaceAgentUploadFileOptions{
+		Offset: offset,
+		Mode:   info.Mode().Perm(),
+	})
+	progress.done()
+	if err != nil {
+		return xerrors.Errorf("upload: %w", err)
+	}
+	if uploaded.Size != info.Size() {
+		return xerrors.Errorf("uploaded %d bytes, but %q is %d bytes: the source changed during the upload", uploaded.Size, src, info.Size())
+	}
+	c.printCopied(src, uploaded.Path, uploaded.Size)
+	return nil
+}
+
+func (c *fileCopier) download(ctx context.Context, src, dst string) error {
+	info, err := c.conn.StatFile(ctx, src)
+	if err != nil {
+		return xerrors.Errorf("stat source: %w", err)
+	}
+	if info.IsDir {
+		return xerrors.Errorf("%q is a directory, only files can be copied", info.Path)
+	}
+
+	// Copying into a directory keeps the name of the source.
+	dstInfo, err := os.Stat(dst)
+	if err == nil && dstInfo.IsDir() {
+		dst = filepath.Join(dst, path.Base(info.Path))
+		dstInfo, err = os.Stat(dst)
+	}
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return xerrors.Errorf("stat destination: %w", err)
+	}
+
+	var offset int64
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if c.resume && exists {
+		offset, err = resumeOffset(dstInfo.Size(), info.Size)
+		if err != nil {
+			return err
+		}
+		flags = os.O_WRONLY
+	}
+	if offset > 0 && offset == info.Size {
+		c.printCopied(info.Path, dst, info.Size)
+		return nil
+	}
+
+	body, err := c.conn.DownloadFile(ctx, src, offset)
+	if err != nil {
+		return xerrors.Errorf("download: %w", err)
+	}
+	defer body.Close()
+
+	f, err := os.OpenFile(dst, flags, os.FileMode(info.Mode))
+	if err != nil {
+		return xerrors.Errorf("open destination: %w", err)
+	}
+	defer f.Close()
+	_, err = f.Seek(offset, io.SeekStart)
+	if err != nil {
+		return xerrors.Errorf("seek destination: %w", err)
+	}
+
+	progress := c.progress(path.Base(info.Path), offset, info.Size)
+	n, err := io.Copy(io.MultiWriter(f, progress), body)
+	progress.done()
+	if err != nil {
+		return xerrors.Errorf("download: %w", err)
+	}
+	err = f.Close()
+	if err != nil {
+		return xerrors.Errorf("close destination: %w", err)
+	}
+	if offset+n != info.Size {
+		return xerrors.Errorf("downloaded %d bytes, but %q is %d bytes: the source changed during the download", offset+n, info.Path, info.Size)
+	}
+	c.printCopied(info.Path, dst, info.Size)
+	return nil
+}
+
+func (c *fileCopier) printCopied(src, dst string, size int64) {
+	_, _ = fmt.Fprintf(c.inv.Stdout, "Copied %s to %s (%s)\n",
+		cliui.DefaultStyles.Code.Render(src),
+		cliui.DefaultStyles.Code.Render(dst),
+		formatBytes(size),
+	)
+}
+
+// resumeOffset returns where to resume a copy to a destination of dstSize
+// bytes from a source of srcSize bytes.
+func resumeOffset(dstSize, srcSize int64) (int64, error) {
+	if dstSize > srcSize {
+		return 0, xerrors.Errorf("the destination (%s) is larger than the source (%s), so the copy can't be resumed", formatBytes(dstSize), formatBytes(srcSize))
+	}
+	return dstSize, nil
+}
+
+func isNotFound(err error) bool {
+	var sdkErr *codersdk.Error
+	return xerrors.As(err, &sdkErr) && sdkErr.StatusCode() == http.StatusNotFound
+}
+
+func (c *fileCopier) progress(name string, offset, total int64) *copyProgress {
+	return &copyProgress{
+		w:       c.inv.Stderr,
+		enabled: isTTYErr(c.inv),
+		name:    name,
+		written: offset,
+		total:   total,
+	}
+}
+
+// copyProgress prints the progress of a copy on a single line of a terminal.
+type copyProgress struct {
+	w       io.Writer
+	enabled bool
+	name    string
+	written int64
+	total   int64
+	printed time.Time
+}
+
+func (p *copyProgress) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if p.enabled && time.Since(p.printed) > 100*time.Millisecond {
+		p.print()
+	}
+	return len(b), nil
+}
+
+func (p *copyProgress) print() {
+	percent := 100
+	if p.total > 0 {
+		percent = int(p.written * 100 / p.total)
+	}
+	_, _ = fmt.Fprintf(p.w, "\r\033[K%s %s / %s (%d%%)", p.name, formatBytes(p.written), formatBytes(p.total), percent)
+	p.printed = time.Now()
+}
+
+func (p *copyProgress) done() {
+	if !p.enabled || p.printed.IsZero() {
+		return
+	}
+	p.print()
+	_, _ = fmt.Fprintln(p.w)
+}
+
+// formatBytes formats a size with binary units, e.g. "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,106 @@
+package cli_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestCp(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (*codersdk.Client, codersdk.Workspace) {
+		client, workspace, agentToken := setupWorkspaceForAgent(t, nil)
+		agentClient := agentsdk.New(client.URL)
+		agentClient.SetSessionToken(agentToken)
+		agentCloser := agent.New(agent.Options{
+			Client: agentClient,
+			Logger: slogtest.Make(t, nil).Named("agent"),
+		})
+		t.Cleanup(func() {
+			_ = agentCloser.Close()
+		})
+		coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+		return client, workspace
+	}
+	run := func(ctx context.Context, t *testing.T, client *codersdk.Client, args ...string) error {
+		inv, root := clitest.New(t, append([]string{"cp"}, args...)...)
+		clitest.SetupConfig(t, client, root)
+		return inv.WithContext(ctx).Run()
+	}
+
+	t.Run("UploadDownload", func(t *testing.T) {
+		t.Parallel()
+		client, workspace := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		dir := t.TempDir()
+		src := filepath.Join(dir, "src.txt")
+		err := os.WriteFile(src, []byte("hello world"), 0o600)
+		require.NoError(t, err)
+		remoteDir := filepath.Join(dir, "remote")
+		err = os.Mkdir(remoteDir, 0o755)
+		require.NoError(t, err)
+
+		// Copying into a directory keeps the file name.
+		err = run(ctx, t, client, src, workspace.Name+":"+remoteDir)
+		require.NoError(t, err)
+		content, err := os.ReadFile(filepath.Join(remoteDir, "src.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "hello world", string(content))
+
+		dst := filepath.Join(dir, "dst.txt")
+		err = run(ctx, t, client, workspace.Name+":"+filepath.Join(remoteDir, "src.txt"), dst)
+		require.NoError(t, err)
+		content, err = os.ReadFile(dst)
+		require.NoError(t, err)
+		require.Equal(t, "hello world", string(content))
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		t.Parallel()
+		client, workspace := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		dir := t.TempDir()
+		src := filepath.Join(dir, "src.txt")
+		err := os.WriteFile(src, []byte("hello world"), 0o600)
+		require.NoError(t, err)
+		dst := filepath.Join(dir, "dst.txt")
+		err = os.WriteFile(dst, []byte("hello"), 0o600)
+		require.NoError(t, err)
+
+		err = run(ctx, t, client, "--resume", workspace.Name+":"+src, dst)
+		require.NoError(t, err)
+		content, err := os.ReadFile(dst)
+		require.NoError(t, err)
+		require.Equal(t, "hello world", string(content))
+
+		// The destination can't be larger than the source.
+		err = os.WriteFile(dst, []byte("hello world!"), 0o600)
+		require.NoError(t, err)
+		err = run(ctx, t, client, "--resume", workspace.Name+":"+src, dst)
+		require.ErrorContains(t, err, "can't be resumed")
+	})
+
+	t.Run("NoWorkspace", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		ctx := testutil.Context(t, testutil.WaitShort)
+
+		err := run(ctx, t, client, "a.txt", "./b.txt")
+		require.ErrorContains(t, err, "exactly one of the source and destination")
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,6 +24,7 @@ func (r *RootCmd) create() *clibase.Cmd {
 		startAt       string
 		stopAfter     time.Duration
 		workspaceName string
+		preset        string
 
 		parameterFlags workspaceParameterFlags
 	)
@@ -140,6 +142,7 @@ func (r *RootCmd) create() *clibase.Cmd {
 				Action:           WorkspaceCreate,
 				Template:         template,
 				NewWorkspaceName: workspaceName,
+				Preset:           preset,
 
 				RichParameterFile: parameterFlags.richParameterFile,
 				RichParameters:    cliRichParameters,
@@ -189,6 +192,12 @@ func (r *RootCmd) create() *clibase.Cmd {
 			Description:   "Specify a template name.",
 			Value:         clibase.StringOf(&templateName),
 		},
+		clibase.Option{
+			Flag:        "preset",
+			Env:         "CODER_PRESET_NAME",
+			Description: "Specify the name of a parameter preset of the template version. Parameter values given by other flags take precedence over the values of the preset.",
+			Value:       clibase.StringOf(&preset),
+		},
 		clibase.Option{
 			Flag:        "start-at",
 			Env:         "CODER_WORKSPACE_START_AT",
@@ -212,6 +221,7 @@ type prepWorkspaceBuildArgs struct {
 	Template         codersdk.Template
 	NewWorkspaceName string
 	WorkspaceID      uuid.UUID
+	Preset           string
 
 	LastBuildParameters []codersdk.WorkspaceBuildParameter
 
@@ -246,8 +256,33 @@ func prepWorkspaceBuild(inv *clibase.Invocation, client *codersdk.Client, args p
 		}
 	}
 
+	var presetParameters []codersdk.WorkspaceBuildParameter
+	if args.Preset != "" {
+		presets, err := client.TemplateVersionPresets(ctx, templateVersion.ID)
+		if err != nil {
+			return nil, xerrors.Errorf("get template version presets: %w", err)
+		}
+		found := false
+		names := make([]string, 0, len(presets))
+		for _, preset := range presets {
+			if preset.Name == args.Preset {
+				presetParameters = preset.Parameters
+				found = true
+				break
+			}
+			names = append(names, preset.Name)
+		}
+		if !found {
+			if len(names) == 0 {
+				return nil, xerrors.Errorf("preset %q not found, template version %q has no presets", args.Preset, templateVersion.Name)
+			}
+			return nil, xerrors.Errorf("preset %q not found, available presets: %s", args.Preset, strings.Join(names, ", "))
+		}
+	}
+
 	resolver := new(ParameterResolver).
 		WithLastBuildParameters(args.LastBuildParameters).
+		WithPresetParameters(presetParameters).
 		WithPromptBuildOptions(args.PromptBuildOptions).
 		WithBuildOptions(args.BuildOptions).
 		WithPromptRichParameters(args.PromptRichParameters).
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) extend() *clibase.Cmd {
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Annotations: workspaceCommand,
+		Use:         "extend <workspace> <duration>",
+		Short:       "Extend the deadline of a running workspace",
+		Long: "Pushes off the autostop of a running workspace by the given duration. " +
+			"Templates can limit how often and by how much the deadline can be extended.\n" + formatExamples(
+			example{
+				Description: "Stop the workspace an hour later than scheduled",
+				Command:     "coder extend my-workspace 1h",
+			},
+		),
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			duration, err := parseDuration(inv.Args[1])
+			if err != nil {
+				return err
+			}
+			if duration <= 0 {
+				return xerrors.New("duration must be positive")
+			}
+
+			workspace, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get workspace: %w", err)
+			}
+
+			resp, err := client.ExtendWorkspace(inv.Context(), workspace.ID, codersdk.ExtendWorkspaceRequest{
+				DurationMillis: duration.Milliseconds(),
+			})
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "The %s workspace will now stop at %s.\n",
+				cliui.DefaultStyles.Keyword.Render(workspace.Name),
+				cliui.DefaultStyles.DateTimeStamp.Render(resp.Deadline.In(localLocation()).Format(time.RFC822)),
+			)
+			if resp.RemainingExtensions != nil {
+				_, _ = fmt.Fprintf(inv.Stdout, "Remaining extensions within 24 hours: %d\n", *resp.RemainingExtensions)
+			}
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/signal"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/coderd/gitauth"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/retry"
+)
+
+// gitCredentialsListenMaxErrors is how many times in a row waiting for the
+// user to authenticate with the git provider may fail before giving up.
+const gitCredentialsListenMaxErrors = 5
+
+// gitCredentials is a git credential helper that fetches tokens for git
+// providers from the workspace agent. It's configured with:
+//
+//	git config --global credential.helper '!coder gitcredentials'
+func (r *RootCmd) gitCredentials() *clibase.Cmd {
+	return &clibase.Cmd{
+		Use:        "gitcredentials <get|store|erase>",
+		Short:      "Fetch credentials for git providers from the workspace agent.",
+		Hidden:     true,
+		Middleware: clibase.RequireNArgs(1),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx := inv.Context()
+
+			ctx, stop := signal.NotifyContext(ctx, InterruptSignals...)
+			defer stop()
+
+			operation := inv.Args[0]
+			if operation != "get" && operation != "erase" {
+				// Git stores credentials that worked with all helpers, but
+				// the agent already caches them.
+				return nil
+			}
+			gitURL, err := gitauth.ParseCredentialHelper(inv.Stdin)
+			if err != nil {
+				return xerrors.Errorf("parse credential attributes: %w", err)
+			}
+			if gitURL == "" {
+				return nil
+			}
+			socket := inv.Environ.Get(agent.EnvGitCredentialsSocket)
+			if socket == "" {
+				return xerrors.Errorf("%s is not set, this command must be run in a workspace", agent.EnvGitCredentialsSocket)
+			}
+			client := &http.Client{
+				Transport: &http.Transport{
+					DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+						var d net.Dialer
+						return d.DialContext(ctx, "unix", socket)
+					},
+				},
+			}
+
+			if operation == "erase" {
+				return eraseGitCredentials(ctx, client, gitURL)
+			}
+
+			token, err := getGitCredentials(ctx, client, gitURL, false)
+			if err != nil {
+				var apiError *codersdk.Error
+				if xerrors.As(err, &apiError) && apiError.StatusCode() == http.StatusNotFound {
+					// No git provider is configured for the URL, so leave
+					// it to the other credential helpers.
+					return nil
+				}
+				return xerrors.Errorf("get git credentials: %w", err)
+			}
+			if token.URL != "" {
+				if err := openURL(inv, token.URL); err == nil {
+					cliui.Infof(inv.Stderr, "Your browser has been opened to authenticate with Git:\n%s", token.URL)
+				} else {
+					cliui.Infof(inv.Stderr, "Open the following URL to authenticate with Git:\n%s", token.URL)
+				}
+
+				var errs int
+				for r := retry.New(250*time.Millisecond, 10*time.Second); r.Wait(ctx); {
+					token, err = getGitCredentials(ctx, client, gitURL, true)
+					if err == nil {
+						cliui.Infof(inv.Stderr, "You've been authenticated with Git!")
+						break
+					}
+					if ctx.Err() != nil {
+						break
+					}
+					// Client errors won't go away by retrying, and neither
+					// will an agent that keeps failing.
+					errs++
+					var apiError *codersdk.Error
+					if (xerrors.As(err, &apiError) && apiError.StatusCode() < http.StatusInternalServerError) || errs >= gitCredentialsListenMaxErrors {
+						return xerrors.Errorf("wait for git authentication: %w", err)
+					}
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+			}
+
+			// Some providers only use the token as the username, but git
+			// requires a password as well.
+			password := token.Password
+			if password == "" {
+				password = token.Username
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "username=%s\npassword=%s\n", token.Username, password)
+			return nil
+		},
+	}
+}
+
+func getGitCredentials(ctx context.Context, client *http.Client, gitURL string, listen bool) (agentsdk.GitAuthResponse, error) {
+	reqURL := "http://agent/?url=" + url.QueryEscape(gitURL)
+	if listen {
+		reqURL += "&listen"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return agentsdk.GitAuthResponse{}, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return agentsdk.GitAuthResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return agentsdk.GitAuthResponse{}, codersdk.ReadBodyAsError(res)
+	}
+	var token agentsdk.GitAuthResponse
+	return token, json.NewDecoder(res.Body).Decode(&token)
+}
+
+func eraseGitCredentials(ctx context.Context, client *http.Client, gitURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, "http://agent/?url="+url.QueryEscape(gitURL), nil)
+	if err != nil {
+		return err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("erase git credentials: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return codersdk.ReadBodyAsError(res)
+	}
+	return nil
+}
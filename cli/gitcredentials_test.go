@@ -0,0 +1,138 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+)
+
+func TestGitCredentials(t *testing.T) {
+	t.Parallel()
+
+	// serveAgent serves the handler on a Unix socket like the agent, and
+	// returns the path of the socket.
+	serveAgent := func(t *testing.T, handler http.HandlerFunc) string {
+		t.Helper()
+		socket := filepath.Join(t.TempDir(), "git-credentials.sock")
+		l, err := net.Listen("unix", socket)
+		require.NoError(t, err)
+		srv := &http.Server{Handler: handler} //nolint:gosec
+		go func() {
+			_ = srv.Serve(l)
+		}()
+		t.Cleanup(func() {
+			_ = srv.Close()
+		})
+		return socket
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		t.Parallel()
+		socket := serveAgent(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("url") != "https://gitlab.com/coder/coder" {
+				httpapi.Write(context.Background(), w, http.StatusBadRequest, codersdk.Response{})
+				return
+			}
+			httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.GitAuthResponse{
+				Username: "oauth2",
+				Password: "token",
+			})
+		})
+		inv, _ := clitest.New(t, "gitcredentials", "get")
+		inv.Environ.Set(agent.EnvGitCredentialsSocket, socket)
+		inv.Stdin = strings.NewReader("protocol=https\nhost=gitlab.com\npath=coder/coder\n\n")
+		var stdout bytes.Buffer
+		inv.Stdout = &stdout
+		err := inv.Run()
+		require.NoError(t, err)
+		require.Equal(t, "username=oauth2\npassword=token\n", stdout.String())
+	})
+
+	t.Run("UsernameOnly", func(t *testing.T) {
+		t.Parallel()
+		socket := serveAgent(t, func(w http.ResponseWriter, r *http.Request) {
+			httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.GitAuthResponse{
+				Username: "token",
+			})
+		})
+		inv, _ := clitest.New(t, "gitcredentials", "get")
+		inv.Environ.Set(agent.EnvGitCredentialsSocket, socket)
+		inv.Stdin = strings.NewReader("protocol=https\nhost=github.com\n\n")
+		var stdout bytes.Buffer
+		inv.Stdout = &stdout
+		err := inv.Run()
+		require.NoError(t, err)
+		require.Equal(t, "username=token\npassword=token\n", stdout.String())
+	})
+
+	t.Run("NoProvider", func(t *testing.T) {
+		t.Parallel()
+		socket := serveAgent(t, func(w http.ResponseWriter, r *http.Request) {
+			httpapi.Write(context.Background(), w, http.StatusNotFound, codersdk.Response{
+				Message: "No git provider found for URL",
+			})
+		})
+		inv, _ := clitest.New(t, "gitcredentials", "get")
+		inv.Environ.Set(agent.EnvGitCredentialsSocket, socket)
+		inv.Stdin = strings.NewReader("protocol=https\nhost=example.com\n\n")
+		var stdout bytes.Buffer
+		inv.Stdout = &stdout
+		err := inv.Run()
+		require.NoError(t, err)
+		require.Empty(t, stdout.String())
+	})
+
+	t.Run("ListenFails", func(t *testing.T) {
+		t.Parallel()
+		var listens atomic.Int64
+		socket := serveAgent(t, func(w http.ResponseWriter, r *http.Request) {
+			if !r.URL.Query().Has("listen") {
+				httpapi.Write(context.Background(), w, http.StatusOK, agentsdk.GitAuthResponse{
+					URL: "https://coder.example.com/gitauth/github",
+				})
+				return
+			}
+			listens.Add(1)
+			httpapi.Write(context.Background(), w, http.StatusBadGateway, codersdk.Response{
+				Message: "Failed to reach coderd.",
+			})
+		})
+		inv, _ := clitest.New(t, "--no-open", "gitcredentials", "get")
+		inv.Environ.Set(agent.EnvGitCredentialsSocket, socket)
+		inv.Stdin = strings.NewReader("protocol=https\nhost=github.com\n\n")
+		inv.Stderr = &bytes.Buffer{}
+		err := inv.Run()
+		require.ErrorContains(t, err, "wait for git authentication")
+		require.EqualValues(t, 5, listens.Load())
+	})
+
+	t.Run("Erase", func(t *testing.T) {
+		t.Parallel()
+		var erased atomic.Bool
+		socket := serveAgent(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete && r.URL.Query().Get("url") == "https://github.com" {
+				erased.Store(true)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+		inv, _ := clitest.New(t, "gitcredentials", "erase")
+		inv.Environ.Set(agent.EnvGitCredentialsSocket, socket)
+		inv.Stdin = strings.NewReader("protocol=https\nhost=github.com\nusername=token\npassword=token\n\n")
+		err := inv.Run()
+		require.NoError(t, err)
+		require.True(t, erased.Load())
+	})
+}
@@ -30,6 +30,9 @@ type workspaceListRow struct {
 	Outdated      bool   `json:"-" table:"outdated"`
 	StartsAt      string `json:"-" table:"starts at"`
 	StopsAfter    string `json:"-" table:"stops after"`
+	// LastBuildReason is not displayed by default, it explains why the
+	// workspace was last started or stopped.
+	LastBuildReason string `json:"-" table:"last build reason"`
 }
 
 func workspaceListRowFromWorkspace(now time.Time, usersByID map[uuid.UUID]codersdk.User, workspace codersdk.Workspace) workspaceListRow {
@@ -68,6 +71,8 @@ func workspaceListRowFromWorkspace(now time.Time, usersByID map[uuid.UUID]coders
 		Outdated:      workspace.Outdated,
 		StartsAt:      autostartDisplay,
 		StopsAfter:    autostopDisplay,
+
+		LastBuildReason: string(workspace.LatestBuild.Reason),
 	}
 }
 
@@ -78,7 +83,16 @@ func (r *RootCmd) list() *clibase.Cmd {
 		searchQuery       string
 		displayWorkspaces []workspaceListRow
 		formatter         = cliui.NewOutputFormatter(
-			cliui.TableFormat([]workspaceListRow{}, nil),
+			cliui.TableFormat([]workspaceListRow{}, []string{
+				"workspace",
+				"template",
+				"status",
+				"healthy",
+				"last built",
+				"outdated",
+				"starts at",
+				"stops after",
+			}),
 			cliui.JSONFormat(),
 		)
 	)
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/codersdk"
+)
+
+// logsMaxBuilds is how many builds are searched for the agent of a stopped
+// workspace.
+const logsMaxBuilds = 10
+
+func (r *RootCmd) logs() *clibase.Cmd {
+	var follow bool
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Annotations: workspaceCommand,
+		Use:         "logs <workspace>",
+		Short:       "Show the startup and shutdown script logs of a workspace agent",
+		Long: "The logs of the first agent of the workspace are shown, unless an " +
+			"agent is selected with <workspace>.<agent>.\n" + formatExamples(
+			example{
+				Description: "Follow the logs of the agent \"main\" of the workspace \"dev\"",
+				Command:     "coder logs dev.main --follow",
+			},
+		),
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx := inv.Context()
+			workspace, err := NamedWorkspace(ctx, client, strings.Split(inv.Args[0], ".")[0])
+			if err != nil {
+				return err
+			}
+			agent, ok := findWorkspaceAgent(workspace.LatestBuild.Resources, inv.Args[0])
+			if !ok {
+				// Stopped workspaces have no agents, but the logs of the
+				// agents of earlier builds are kept.
+				builds, err := client.WorkspaceBuilds(ctx, codersdk.WorkspaceBuildsRequest{
+					WorkspaceID: workspace.ID,
+					Pagination:  codersdk.Pagination{Limit: logsMaxBuilds},
+				})
+				if err != nil {
+					return xerrors.Errorf("get workspace builds: %w", err)
+				}
+				for _, build := range builds {
+					agent, ok = findWorkspaceAgent(build.Resources, inv.Args[0])
+					if ok {
+						break
+					}
+				}
+			}
+			if !ok {
+				return xerrors.Errorf("agent not found in the recent builds of workspace %q", workspace.Name)
+			}
+
+			logs, closer, err := client.WorkspaceAgentLogsAfter(ctx, agent.ID, 0, follow)
+			if err != nil {
+				return xerrors.Errorf("fetch workspace agent logs: %w", err)
+			}
+			defer closer.Close()
+			for {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case batch, ok := <-logs:
+					if !ok {
+						return nil
+					}
+					writeAgentLogs(inv.Stdout, batch)
+				}
+			}
+		},
+	}
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:          "follow",
+			FlagShorthand: "f",
+			Description:   "Keep streaming new logs until interrupted.",
+			Value:         clibase.BoolOf(&follow),
+		},
+	}
+	return cmd
+}
+
+func writeAgentLogs(w io.Writer, logs []codersdk.WorkspaceAgentLog) {
+	for _, log := range logs {
+		_, _ = fmt.Fprintf(w, "%s [%s] [%s] %s\n", log.CreatedAt.Local().Format(time.RFC3339), log.Level, log.Source, log.Output)
+	}
+}
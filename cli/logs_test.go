@@ -0,0 +1,93 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestLogs(t *testing.T) {
+	t.Parallel()
+
+	client, workspace, agentToken := setupWorkspaceForAgent(t, func(agents []*proto.Agent) []*proto.Agent {
+		agents[0].StartupScript = "echo hello-startup"
+		return agents
+	})
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(agentToken)
+	agentCloser := agent.New(agent.Options{
+		Client: agentClient,
+		Logger: slogtest.Make(t, nil).Named("agent"),
+	})
+	t.Cleanup(func() {
+		_ = agentCloser.Close()
+	})
+	resources := coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+	require.Eventually(t, func() bool {
+		agent, err := client.WorkspaceAgent(context.Background(), resources[0].Agents[0].ID)
+		return err == nil && agent.LifecycleState == codersdk.WorkspaceAgentLifecycleReady
+	}, testutil.WaitLong, testutil.IntervalMedium)
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "logs", workspace.Name)
+		clitest.SetupConfig(t, client, root)
+		var stdout bytes.Buffer
+		inv.Stdout = &stdout
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+		require.Contains(t, stdout.String(), "[info] [startup_script] hello-startup")
+	})
+
+	t.Run("Follow", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "logs", workspace.Name+"."+resources[0].Agents[0].Name, "--follow")
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t).Attach(inv)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		cmdDone := tGo(t, func() {
+			err := inv.WithContext(ctx).Run()
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+
+		pty.ExpectMatch("hello-startup")
+		// The command keeps streaming until it is interrupted.
+		select {
+		case <-cmdDone:
+			t.Fatal("command exited while following")
+		default:
+		}
+		cancel()
+		<-cmdDone
+	})
+
+	t.Run("NoAgent", func(t *testing.T) {
+		t.Parallel()
+
+		inv, root := clitest.New(t, "logs", workspace.Name+".nope")
+		clitest.SetupConfig(t, client, root)
+
+		err := inv.Run()
+		require.ErrorContains(t, err, "agent not found")
+	})
+}
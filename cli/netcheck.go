@@ -14,6 +14,7 @@ import (
 )
 
 func (r *RootCmd) netcheck() *clibase.Cmd {
+	var coordinator bool
 	client := new(codersdk.Client)
 
 	cmd := &clibase.Cmd{
@@ -26,6 +27,14 @@ func (r *RootCmd) netcheck() *clibase.Cmd {
 			ctx, cancel := context.WithTimeout(inv.Context(), 30*time.Second)
 			defer cancel()
 
+			if coordinator {
+				debug, err := client.DebugCoordinator(ctx)
+				if err != nil {
+					return xerrors.Errorf("get coordinator debug info: %w", err)
+				}
+				return writeNetcheckJSON(inv, debug)
+			}
+
 			connInfo, err := client.WorkspaceAgentConnectionInfoGeneric(ctx)
 			if err != nil {
 				return err
@@ -38,24 +47,34 @@ func (r *RootCmd) netcheck() *clibase.Cmd {
 				DERPMap: connInfo.DERPMap,
 			})
 
-			raw, err := json.MarshalIndent(report, "", "  ")
-			if err != nil {
-				return err
-			}
-
-			n, err := inv.Stdout.Write(raw)
-			if err != nil {
-				return err
-			}
-			if n != len(raw) {
-				return xerrors.Errorf("failed to write all bytes to stdout; wrote %d, len %d", n, len(raw))
-			}
-
-			_, _ = inv.Stdout.Write([]byte("\n"))
-			return nil
+			return writeNetcheckJSON(inv, report)
 		},
 	}
 
-	cmd.Options = clibase.OptionSet{}
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:        "coordinator",
+			Description: "Print the agents, clients and nodes known to the tailnet coordinator instead of a network report. Requires the owner role.",
+			Value:       clibase.BoolOf(&coordinator),
+		},
+	}
 	return cmd
 }
+
+func writeNetcheckJSON(inv *clibase.Invocation, v any) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	n, err := inv.Stdout.Write(raw)
+	if err != nil {
+		return err
+	}
+	if n != len(raw) {
+		return xerrors.Errorf("failed to write all bytes to stdout; wrote %d, len %d", n, len(raw))
+	}
+
+	_, _ = inv.Stdout.Write([]byte("\n"))
+	return nil
+}
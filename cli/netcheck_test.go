@@ -3,14 +3,20 @@ package cli_test
 import (
 	"bytes"
 	"encoding/json"
+	"net"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/healthcheck"
 	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/tailnet"
+	"github.com/coder/coder/testutil"
 )
 
 func TestNetcheck(t *testing.T) {
@@ -36,3 +42,43 @@ func TestNetcheck(t *testing.T) {
 		require.Len(t, v.NodeReports, len(v.Region.Nodes))
 	}
 }
+
+func TestNetcheckCoordinator(t *testing.T) {
+	t.Parallel()
+
+	client, _, api := coderdtest.NewWithAPI(t, nil)
+	_ = coderdtest.CreateFirstUser(t, client)
+
+	// Connect a fake agent to the coordinator.
+	agentID := uuid.New()
+	agentConn, serverConn := net.Pipe()
+	defer agentConn.Close()
+	go func() {
+		_ = (*api.TailnetCoordinator.Load()).ServeAgent(serverConn, agentID, "dev")
+	}()
+	sendNode, _ := tailnet.ServeCoordinator(agentConn, func([]*tailnet.Node) error { return nil })
+	sendNode(&tailnet.Node{PreferredDERP: 7, AsOf: time.Now().Add(-time.Hour)})
+
+	require.Eventually(t, func() bool {
+		return (*api.TailnetCoordinator.Load()).Node(agentID) != nil
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	var out bytes.Buffer
+	inv, root := clitest.New(t, "netcheck", "--coordinator")
+	clitest.SetupConfig(t, client, root)
+	inv.Stdout = &out
+
+	clitest.Run(t, inv)
+
+	var debug tailnet.HTMLDebug
+	require.NoError(t, json.Unmarshal(out.Bytes(), &debug))
+	require.Len(t, debug.Agents, 1)
+	require.Equal(t, agentID, debug.Agents[0].ID)
+	require.Equal(t, "dev", debug.Agents[0].Name)
+	require.Len(t, debug.Nodes, 1)
+	require.Equal(t, agentID, debug.Nodes[0].ID)
+	require.Equal(t, 7, debug.Nodes[0].Node.PreferredDERP)
+	require.Equal(t, time.Hour, time.Duration(debug.Nodes[0].HandshakeAge))
+	// Durations are formatted instead of being printed in nanoseconds.
+	require.Contains(t, out.String(), `"handshake_age": "1h0m0s"`)
+}
@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"github.com/coder/coder/cli/clibase"
+)
+
+func (r *RootCmd) organizations() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Use:     "organizations",
+		Short:   "Organization related commands",
+		Aliases: []string{"organization", "org", "orgs"},
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.organizationVariables(),
+		},
+	}
+	return cmd
+}
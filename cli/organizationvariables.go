@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) organizationVariables() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Use:   "variables",
+		Short: "Manage the template variable values of the organization",
+		Long: "Values apply to every template in the organization that declares the variable.\n" + formatExamples(
+			example{
+				Description: "Set the VPC that templates deploy workspaces to",
+				Command:     "coder organizations variables set vpc_id vpc-0123456789abcdef0",
+			},
+			example{
+				Description: "List the template variable values of the organization",
+				Command:     "coder organizations variables ls",
+			},
+		),
+		Aliases: []string{"variable"},
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.listOrganizationVariables(),
+			r.setOrganizationVariable(),
+			r.deleteOrganizationVariable(),
+		},
+	}
+	return cmd
+}
+
+// organizationVariableRow is the type provided to the OutputFormatter.
+type organizationVariableRow struct {
+	// For JSON format:
+	codersdk.OrganizationTemplateVariable `table:"-"`
+
+	// For table format:
+	Name      string    `json:"-" table:"name,default_sort"`
+	Value     string    `json:"-" table:"value"`
+	Sensitive bool      `json:"-" table:"sensitive"`
+	UpdatedAt time.Time `json:"-" table:"updated at"`
+}
+
+func (r *RootCmd) listOrganizationVariables() *clibase.Cmd {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]organizationVariableRow{}, []string{"name", "value", "sensitive", "updated at"}),
+		cliui.JSONFormat(),
+	)
+
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the template variable values of the organization",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(0),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return err
+			}
+
+			variables, err := client.OrganizationTemplateVariables(inv.Context(), organization.ID)
+			if err != nil {
+				return xerrors.Errorf("list organization template variables: %w", err)
+			}
+			if len(variables) == 0 {
+				cliui.Infof(inv.Stdout, "No template variables found.\n")
+			}
+
+			rows := make([]organizationVariableRow, 0, len(variables))
+			for _, variable := range variables {
+				rows = append(rows, organizationVariableRow{
+					OrganizationTemplateVariable: variable,
+					Name:                         variable.Name,
+					Value:                        variable.Value,
+					Sensitive:                    variable.Sensitive,
+					UpdatedAt:                    variable.UpdatedAt,
+				})
+			}
+
+			out, err := formatter.Format(inv.Context(), rows)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func (r *RootCmd) setOrganizationVariable() *clibase.Cmd {
+	var sensitive bool
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "set <name> <value>",
+		Short: "Set the value of a template variable for the organization",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(2),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return err
+			}
+
+			variable, err := client.UpsertOrganizationTemplateVariable(inv.Context(), organization.ID, inv.Args[0], codersdk.UpsertOrganizationTemplateVariableRequest{
+				Value:     inv.Args[1],
+				Sensitive: sensitive,
+			})
+			if err != nil {
+				return xerrors.Errorf("set organization template variable: %w", err)
+			}
+
+			cliui.Infof(inv.Stdout, "Set the template variable %s.", cliui.DefaultStyles.Keyword.Render(variable.Name))
+			return nil
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:        "sensitive",
+			Description: "Redact the value when the template variables of the organization are listed.",
+			Value:       clibase.BoolOf(&sensitive),
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) deleteOrganizationVariable() *clibase.Cmd {
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "delete <name>",
+		Short: "Delete the value of a template variable from the organization",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return err
+			}
+
+			err = client.DeleteOrganizationTemplateVariable(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("delete organization template variable: %w", err)
+			}
+
+			cliui.Infof(inv.Stdout, "Deleted the template variable %s.", cliui.DefaultStyles.Keyword.Render(inv.Args[0]))
+			return nil
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,73 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationVariables(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, nil)
+	_ = coderdtest.CreateFirstUser(t, client)
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancelFunc()
+
+	inv, root := clitest.New(t, "organizations", "variables", "ls")
+	clitest.SetupConfig(t, client, root)
+	buf := new(bytes.Buffer)
+	inv.Stdout = buf
+	err := inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "No template variables found")
+
+	inv, root = clitest.New(t, "organizations", "variables", "set", "vpc_id", "vpc-0123456789abcdef0")
+	clitest.SetupConfig(t, client, root)
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	inv, root = clitest.New(t, "organizations", "variables", "set", "--sensitive", "api_key", "hunter2")
+	clitest.SetupConfig(t, client, root)
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	inv, root = clitest.New(t, "organizations", "variables", "ls")
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	inv.Stdout = buf
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	res := buf.String()
+	require.Contains(t, res, "vpc_id")
+	require.Contains(t, res, "vpc-0123456789abcdef0")
+	require.Contains(t, res, "api_key")
+	require.NotContains(t, res, "hunter2")
+
+	inv, root = clitest.New(t, "organizations", "variables", "rm", "api_key")
+	clitest.SetupConfig(t, client, root)
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	inv, root = clitest.New(t, "organizations", "variables", "ls", "--output=json")
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	inv.Stdout = buf
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	var variables []codersdk.OrganizationTemplateVariable
+	err = json.Unmarshal(buf.Bytes(), &variables)
+	require.NoError(t, err)
+	require.Len(t, variables, 1)
+	require.Equal(t, "vpc_id", variables[0].Name)
+	require.Equal(t, "vpc-0123456789abcdef0", variables[0].Value)
+}
@@ -22,6 +22,7 @@ const (
 type ParameterResolver struct {
 	lastBuildParameters []codersdk.WorkspaceBuildParameter
 
+	presetParameters   []codersdk.WorkspaceBuildParameter
 	richParameters     []codersdk.WorkspaceBuildParameter
 	richParametersFile map[string]string
 	buildOptions       []codersdk.WorkspaceBuildParameter
@@ -35,6 +36,11 @@ func (pr *ParameterResolver) WithLastBuildParameters(params []codersdk.Workspace
 	return pr
 }
 
+func (pr *ParameterResolver) WithPresetParameters(params []codersdk.WorkspaceBuildParameter) *ParameterResolver {
+	pr.presetParameters = params
+	return pr
+}
+
 func (pr *ParameterResolver) WithRichParameters(params []codersdk.WorkspaceBuildParameter) *ParameterResolver {
 	pr.richParameters = params
 	return pr
@@ -64,6 +70,7 @@ func (pr *ParameterResolver) Resolve(inv *clibase.Invocation, action WorkspaceCL
 	var staged []codersdk.WorkspaceBuildParameter
 	var err error
 
+	staged = pr.resolveWithPreset(staged)
 	staged = pr.resolveWithParametersMapFile(staged)
 	staged = pr.resolveWithCommandLineOrEnv(staged)
 	staged = pr.resolveWithLastBuildParameters(staged, templateVersionParameters)
@@ -76,6 +83,12 @@ func (pr *ParameterResolver) Resolve(inv *clibase.Invocation, action WorkspaceCL
 	return staged, nil
 }
 
+func (pr *ParameterResolver) resolveWithPreset(resolved []codersdk.WorkspaceBuildParameter) []codersdk.WorkspaceBuildParameter {
+	// The values of the preset are resolved first, so that they can be
+	// overridden by the parameter file and command line.
+	return append(resolved, pr.presetParameters...)
+}
+
 func (pr *ParameterResolver) resolveWithParametersMapFile(resolved []codersdk.WorkspaceBuildParameter) []codersdk.WorkspaceBuildParameter {
 next:
 	for name, value := range pr.richParametersFile {
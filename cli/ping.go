@@ -2,10 +2,14 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/sloghuman"
@@ -13,6 +17,8 @@ import (
 	"github.com/coder/coder/cli/clibase"
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/cryptorand"
 )
 
 func (r *RootCmd) ping() *clibase.Cmd {
@@ -20,30 +26,27 @@ func (r *RootCmd) ping() *clibase.Cmd {
 		pingNum     int64
 		pingTimeout time.Duration
 		pingWait    time.Duration
+		pingStats   bool
 	)
 
-	client := new(codersdk.Client)
+	var (
+		client      = new(codersdk.Client)
+		agentClient *agentsdk.Client
+	)
 	cmd := &clibase.Cmd{
 		Annotations: workspaceCommand,
 		Use:         "ping <workspace>",
 		Short:       "Ping a workspace",
+		Long: "Inside a workspace, the CLI can ping the other workspaces of the " +
+			"workspace's owner without logging in.",
 		Middleware: clibase.Chain(
 			clibase.RequireNArgs(1),
-			r.InitClient(client),
+			r.initClientOrAgent(client, &agentClient),
 		),
 		Handler: func(inv *clibase.Invocation) error {
 			ctx, cancel := context.WithCancel(inv.Context())
 			defer cancel()
 
-			workspaceName := inv.Args[0]
-			_, workspaceAgent, err := getWorkspaceAndAgent(
-				ctx, inv, client,
-				codersdk.Me, workspaceName,
-			)
-			if err != nil {
-				return err
-			}
-
 			var logger slog.Logger
 			if r.verbose {
 				logger = slog.Make(sloghuman.Sink(inv.Stdout)).Leveled(slog.LevelDebug)
@@ -52,17 +55,44 @@ func (r *RootCmd) ping() *clibase.Cmd {
 			if r.disableDirect {
 				_, _ = fmt.Fprintln(inv.Stderr, "Direct connections disabled.")
 			}
-			conn, err := client.DialWorkspaceAgent(ctx, workspaceAgent.ID, &codersdk.DialWorkspaceAgentOptions{
+			dialOptions := &codersdk.DialWorkspaceAgentOptions{
 				Logger:         logger,
 				BlockEndpoints: r.disableDirect,
-			})
-			if err != nil {
-				return err
+			}
+
+			workspaceName := inv.Args[0]
+			var conn *codersdk.WorkspaceAgentConn
+			if agentClient != nil {
+				peer, err := getPeer(ctx, agentClient, workspaceName)
+				if err != nil {
+					return err
+				}
+				conn, err = agentClient.DialPeer(ctx, peer.AgentID, dialOptions)
+				if err != nil {
+					return err
+				}
+			} else {
+				_, workspaceAgent, err := getWorkspaceAndAgent(
+					ctx, inv, client,
+					codersdk.Me, workspaceName,
+				)
+				if err != nil {
+					return err
+				}
+				dialOptions.PreferDERPRegion = r.preferDERPRegion(ctx, inv, logger, client)
+				conn, err = client.DialWorkspaceAgent(ctx, workspaceAgent.ID, dialOptions)
+				if err != nil {
+					return err
+				}
 			}
 			defer conn.Close()
 
 			derpMap := conn.DERPMap()
-			_ = derpMap
+			if pingStats {
+				defer func() {
+					writePingStats(inv.Stdout, conn.Stats(), derpMap)
+				}()
+			}
 
 			n := 0
 			didP2p := false
@@ -159,6 +189,90 @@ func (r *RootCmd) ping() *clibase.Cmd {
 			Description:   "Specifies the number of pings to perform.",
 			Value:         clibase.Int64Of(&pingNum),
 		},
+		{
+			Flag:        "stats",
+			Description: "Print the bytes transferred, latency and path of the connection after pinging.",
+			Value:       clibase.BoolOf(&pingStats),
+		},
 	}
 	return cmd
 }
+
+// writePingStats writes the statistics of the connection to the workspace.
+func writePingStats(w io.Writer, stats codersdk.WorkspaceAgentConnStats, derpMap *tailcfg.DERPMap) {
+	var path string
+	if stats.P2P {
+		path = fmt.Sprintf("p2p via %s", stats.Endpoint)
+	} else {
+		derpName := "unknown"
+		if derpRegion, ok := derpMap.Regions[stats.DERPRegionID]; ok {
+			derpName = derpRegion.RegionName
+		}
+		path = fmt.Sprintf("proxied via DERP(%s)", derpName)
+	}
+	latency := "unknown"
+	if stats.Latency > 0 {
+		latency = stats.Latency.Round(time.Millisecond).String()
+	}
+
+	_, _ = fmt.Fprintln(w, cliui.DefaultStyles.Bold.Render("Connection stats:"))
+	_, _ = fmt.Fprintf(w, "  Path:     %s\n", path)
+	_, _ = fmt.Fprintf(w, "  Latency:  %s\n", latency)
+	_, _ = fmt.Fprintf(w, "  Received: %d bytes\n", stats.RxBytes)
+	_, _ = fmt.Fprintf(w, "  Sent:     %d bytes\n", stats.TxBytes)
+}
+
+// initClientOrAgent initializes client like InitClient. If the CLI isn't
+// logged in but runs inside a workspace, agentClient is initialized with the
+// agent token of the workspace instead, so the workspace's peers can be
+// dialed.
+func (r *RootCmd) initClientOrAgent(client *codersdk.Client, agentClient **agentsdk.Client) clibase.MiddlewareFunc {
+	initClient := r.InitClient(client)
+	return func(next clibase.HandlerFunc) clibase.HandlerFunc {
+		withClient := initClient(next)
+		return func(inv *clibase.Invocation) error {
+			err := withClient(inv)
+			if !errors.Is(err, errUnauthenticated) || r.agentToken == "" || r.agentURL.String() == "" {
+				return err
+			}
+			c, err := r.createAgentClient()
+			if err != nil {
+				return xerrors.Errorf("create agent client: %w", err)
+			}
+			c.SDK.DisableDirectConnections = r.disableDirect
+			*agentClient = c
+			return next(inv)
+		}
+	}
+}
+
+// getPeer returns the peer agent of the workspace the CLI runs in that matches
+// the given "workspace" or "workspace.agent" name.
+func getPeer(ctx context.Context, client *agentsdk.Client, in string) (agentsdk.Peer, error) {
+	workspaceName, agentName, _ := strings.Cut(in, ".")
+	peers, err := client.Peers(ctx)
+	if err != nil {
+		return agentsdk.Peer{}, xerrors.Errorf("get peers: %w", err)
+	}
+
+	var agents []agentsdk.Peer
+	for _, peer := range peers {
+		if peer.WorkspaceName != workspaceName {
+			continue
+		}
+		if agentName != "" && peer.AgentName != agentName {
+			continue
+		}
+		agents = append(agents, peer)
+	}
+	if len(agents) == 0 {
+		if agentName != "" {
+			return agentsdk.Peer{}, xerrors.Errorf("agent not found by name %q", agentName)
+		}
+		return agentsdk.Peer{}, xerrors.Errorf("workspace %q not found or has no agents", workspaceName)
+	}
+	if len(agents) > 1 {
+		return cryptorand.Element(agents)
+	}
+	return agents[0], nil
+}
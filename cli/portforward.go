@@ -26,8 +26,9 @@ import (
 
 func (r *RootCmd) portForward() *clibase.Cmd {
 	var (
-		tcpForwards []string // <port>:<port>
-		udpForwards []string // <port>:<port>
+		tcpForwards   []string // <port>:<port>
+		udpForwards   []string // <port>:<port>
+		socks5Address string   // [<ip>:]<port>
 	)
 	client := new(codersdk.Client)
 	cmd := &clibase.Cmd{
@@ -55,6 +56,10 @@ func (r *RootCmd) portForward() *clibase.Cmd {
 				Description: "Port forward specifying the local address to bind to",
 				Command:     "coder port-forward <workspace> --tcp 1.2.3.4:8080:8080",
 			},
+			example{
+				Description: "Start a SOCKS5 proxy on port 1080 on your local machine that connects to addresses from the workspace network",
+				Command:     "coder port-forward <workspace> --socks5 1080",
+			},
 		),
 		Middleware: clibase.Chain(
 			clibase.RequireNArgs(1),
@@ -68,7 +73,14 @@ func (r *RootCmd) portForward() *clibase.Cmd {
 			if err != nil {
 				return xerrors.Errorf("parse port-forward specs: %w", err)
 			}
-			if len(specs) == 0 {
+			var socks5Listen netip.AddrPort
+			if socks5Address != "" {
+				socks5Listen, err = parseListenAddress(socks5Address)
+				if err != nil {
+					return xerrors.Errorf("parse SOCKS5 address: %w", err)
+				}
+			}
+			if len(specs) == 0 && socks5Address == "" {
 				err = inv.Command.HelpHandler(inv)
 				if err != nil {
 					return xerrors.Errorf("generate help output: %w", err)
@@ -107,8 +119,9 @@ func (r *RootCmd) portForward() *clibase.Cmd {
 				_, _ = fmt.Fprintln(inv.Stderr, "Direct connections disabled.")
 			}
 			conn, err := client.DialWorkspaceAgent(ctx, workspaceAgent.ID, &codersdk.DialWorkspaceAgentOptions{
-				Logger:         logger,
-				BlockEndpoints: r.disableDirect,
+				Logger:           logger,
+				BlockEndpoints:   r.disableDirect,
+				PreferDERPRegion: r.preferDERPRegion(ctx, inv, logger, client),
 			})
 			if err != nil {
 				return err
@@ -118,7 +131,7 @@ func (r *RootCmd) portForward() *clibase.Cmd {
 			// Start all listeners.
 			var (
 				wg                = new(sync.WaitGroup)
-				listeners         = make([]net.Listener, len(specs))
+				listeners         = make([]net.Listener, len(specs), len(specs)+1)
 				closeAllListeners = func() {
 					for _, l := range listeners {
 						if l == nil {
@@ -137,6 +150,13 @@ func (r *RootCmd) portForward() *clibase.Cmd {
 				}
 				listeners[i] = l
 			}
+			if socks5Address != "" {
+				l, err := listenAndServeSOCKS5(ctx, inv, conn, wg, socks5Listen.String())
+				if err != nil {
+					return err
+				}
+				listeners = append(listeners, l)
+			}
 
 			// Wait for the context to be canceled or for a signal and close
 			// all listeners.
@@ -180,6 +200,12 @@ func (r *RootCmd) portForward() *clibase.Cmd {
 			Description: "Forward UDP port(s) from the workspace to the local machine. The UDP connection has TCP-like semantics to support stateful UDP protocols.",
 			Value:       clibase.StringArrayOf(&udpForwards),
 		},
+		{
+			Flag:        "socks5",
+			Env:         "CODER_PORT_FORWARD_SOCKS5",
+			Description: "Start a SOCKS5 proxy on the local machine that connects to addresses from the workspace network. Accepts a port or an <ip>:<port> to bind to.",
+			Value:       clibase.StringOf(&socks5Address),
+		},
 	}
 
 	return cmd
@@ -251,6 +277,49 @@ func listenAndPortForward(ctx context.Context, inv *clibase.Invocation, conn *co
 	return l, nil
 }
 
+// listenAndServeSOCKS5 pipes connections to the listen address to the SOCKS5
+// proxy in the workspace agent, so addresses are resolved and dialed from the
+// workspace network.
+func listenAndServeSOCKS5(ctx context.Context, inv *clibase.Invocation, conn *codersdk.WorkspaceAgentConn, wg *sync.WaitGroup, listenAddress string) (net.Listener, error) {
+	_, _ = fmt.Fprintf(inv.Stderr, "Serving a SOCKS5 proxy on 'tcp://%v' that connects from the workspace\n", listenAddress)
+
+	l, err := net.Listen("tcp", listenAddress)
+	if err != nil {
+		return nil, xerrors.Errorf("listen 'tcp://%v': %w", listenAddress, err)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			netConn, err := l.Accept()
+			if err != nil {
+				// Silently ignore net.ErrClosed errors.
+				if xerrors.Is(err, net.ErrClosed) {
+					return
+				}
+				_, _ = fmt.Fprintf(inv.Stderr, "Error accepting connection from 'tcp://%v': %v\n", listenAddress, err)
+				_, _ = fmt.Fprintln(inv.Stderr, "Killing listener")
+				return
+			}
+
+			go func(netConn net.Conn) {
+				defer netConn.Close()
+				remoteConn, err := conn.SOCKS5(ctx)
+				if err != nil {
+					_, _ = fmt.Fprintf(inv.Stderr, "Failed to dial SOCKS5 proxy in workspace: %s\n", err)
+					return
+				}
+				defer remoteConn.Close()
+
+				agentssh.Bicopy(ctx, netConn, remoteConn)
+			}(netConn)
+		}
+	}()
+
+	return l, nil
+}
+
 type portForwardSpec struct {
 	listenNetwork string // tcp, udp
 	listenAddress string // <ip>:<port> or path
@@ -311,6 +380,26 @@ func parsePortForwards(tcpSpecs, udpSpecs []string) ([]portForwardSpec, error) {
 	return specs, nil
 }
 
+// parseListenAddress parses a local address in the form [<ip>:]<port>. The
+// address defaults to 127.0.0.1.
+func parseListenAddress(in string) (netip.AddrPort, error) {
+	addr := netip.AddrFrom4([4]byte{127, 0, 0, 1})
+	rawPort := in
+	if i := strings.LastIndex(in, ":"); i != -1 {
+		var err error
+		addr, err = netip.ParseAddr(strings.Trim(in[:i], "[]"))
+		if err != nil {
+			return netip.AddrPort{}, xerrors.Errorf("invalid address %q; invalid ip %q: %w", in, in[:i], err)
+		}
+		rawPort = in[i+1:]
+	}
+	port, err := parsePort(rawPort)
+	if err != nil {
+		return netip.AddrPort{}, xerrors.Errorf("parse port from %q: %w", in, err)
+	}
+	return netip.AddrPortFrom(addr, port), nil
+}
+
 func parsePort(in string) (uint16, error) {
 	port, err := strconv.ParseUint(strings.TrimSpace(in), 10, 16)
 	if err != nil {
@@ -88,3 +88,34 @@ func Test_parsePortForwards(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseListenAddress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "1080", want: "127.0.0.1:1080"},
+		{in: "0.0.0.0:1080", want: "0.0.0.0:1080"},
+		{in: "[::1]:1080", want: "[::1]:1080"},
+		{in: "localhost:1080", wantErr: true},
+		{in: "1080-1081", wantErr: true},
+		{in: "0", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseListenAddress(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
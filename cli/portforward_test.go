@@ -12,6 +12,7 @@ import (
 	"github.com/pion/udp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/proxy"
 
 	"github.com/coder/coder/cli/clitest"
 	"github.com/coder/coder/coderd/coderdtest"
@@ -286,6 +287,38 @@ func TestPortForward(t *testing.T) {
 		err := <-errC
 		require.ErrorIs(t, err, context.Canceled)
 	})
+
+	t.Run("SOCKS5", func(t *testing.T) {
+		p := setupTestListener(t, cases[0].setupRemote(t))
+		localAddress, localFlag := cases[0].setupLocal(t)
+
+		inv, root := clitest.New(t, "-v", "port-forward", workspace.Name, "--socks5", localFlag)
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t).Attach(inv)
+		inv.Stderr = pty.Output()
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		errC := make(chan error)
+		go func() {
+			errC <- inv.WithContext(ctx).Run()
+		}()
+		pty.ExpectMatchContext(ctx, "Ready!")
+
+		t.Parallel() // Port is reserved, enable parallel execution.
+
+		// The remote address is dialed from the workspace, not locally.
+		dialer, err := proxy.SOCKS5("tcp", localAddress, nil, proxy.Direct)
+		require.NoError(t, err)
+		//nolint:forcetypeassert
+		c, err := dialer.(proxy.ContextDialer).DialContext(ctx, "tcp", fmt.Sprint("127.0.0.1:", p))
+		require.NoError(t, err, "open connection through SOCKS5 proxy")
+		defer c.Close()
+		testDial(t, c)
+
+		cancel()
+		err = <-errC
+		require.ErrorIs(t, err, context.Canceled)
+	})
 }
 
 // runAgent creates a fake workspace and starts an agent locally for that
@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) proxy() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Use:   "proxy",
+		Short: "Select the region that relays connections to workspaces",
+		Long: "Connections to workspaces that can't be established directly are relayed through " +
+			"the primary region or a workspace proxy. By default the region with the lowest latency " +
+			"is used.",
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.proxyList(),
+			r.proxySelect(),
+		},
+	}
+	return cmd
+}
+
+type proxyListRow struct {
+	// For JSON format:
+	codersdk.Region `table:"-"`
+	LatencyMS       float64 `json:"latency_ms" table:"-"`
+	Selected        bool    `json:"selected" table:"-"`
+	Error           string  `json:"error,omitempty" table:"-"`
+
+	// For table format:
+	Name          string `json:"-" table:"name,default_sort"`
+	DisplayName   string `json:"-" table:"display name"`
+	Healthy       bool   `json:"-" table:"healthy"`
+	Latency       string `json:"-" table:"latency"`
+	SelectedTable string `json:"-" table:"selected"`
+}
+
+func (r *RootCmd) proxyList() *clibase.Cmd {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]proxyListRow{}, []string{"name", "display name", "healthy", "latency", "selected"}),
+		cliui.JSONFormat(),
+	)
+
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List regions and the latency to them",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(0),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx := inv.Context()
+			pinned, err := r.selectedProxy()
+			if err != nil {
+				return err
+			}
+			regions, err := client.Regions(ctx)
+			if err != nil {
+				return xerrors.Errorf("get regions: %w", err)
+			}
+			latencies := client.RegionLatencies(ctx, regions)
+			selected := pinned
+			if selected == "" {
+				fastest, ok := codersdk.FastestRegion(latencies)
+				if ok {
+					selected = fastest.Region.Name
+				}
+			}
+
+			rows := make([]proxyListRow, 0, len(latencies))
+			for _, latency := range latencies {
+				row := proxyListRow{
+					Region:      latency.Region,
+					LatencyMS:   float64(latency.Latency.Microseconds()) / 1000,
+					Selected:    strings.EqualFold(latency.Region.Name, selected),
+					Name:        latency.Region.Name,
+					DisplayName: latency.Region.DisplayName,
+					Healthy:     latency.Region.Healthy,
+					Latency:     "-",
+				}
+				if latency.Error != nil {
+					row.Error = latency.Error.Error()
+				} else {
+					row.Latency = fmt.Sprintf("%.1fms", row.LatencyMS)
+				}
+				switch {
+				case row.Selected && pinned != "":
+					row.SelectedTable = "pinned"
+				case row.Selected:
+					row.SelectedTable = "auto"
+				}
+				rows = append(rows, row)
+			}
+
+			out, err := formatter.Format(ctx, rows)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, out)
+			return err
+		},
+	}
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func (r *RootCmd) proxySelect() *clibase.Cmd {
+	var auto bool
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "select [name]",
+		Short: "Pin the region that relays connections to workspaces",
+		Long: formatExamples(
+			example{
+				Description: "Relay connections through the workspace proxy named \"eu\"",
+				Command:     "coder proxy select eu",
+			},
+			example{
+				Description: "Use the region with the lowest latency again",
+				Command:     "coder proxy select --auto",
+			},
+		),
+		Middleware: clibase.Chain(
+			clibase.RequireRangeArgs(0, 1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			if auto {
+				if len(inv.Args) > 0 {
+					return xerrors.New("a region name can't be used with --auto")
+				}
+				err := r.createConfig().Proxy().Delete()
+				if err != nil && !errors.Is(err, os.ErrNotExist) {
+					return xerrors.Errorf("delete selected proxy: %w", err)
+				}
+				_, _ = fmt.Fprintln(inv.Stdout, "Connections to workspaces are relayed through the region with the lowest latency.")
+				return nil
+			}
+			if len(inv.Args) == 0 {
+				return xerrors.New("specify the name of a region, or --auto to use the region with the lowest latency")
+			}
+
+			regions, err := client.Regions(inv.Context())
+			if err != nil {
+				return xerrors.Errorf("get regions: %w", err)
+			}
+			region, ok := regionByName(regions, inv.Args[0])
+			if !ok {
+				return xerrors.Errorf("region %q does not exist, run \"coder proxy ls\" to list regions", inv.Args[0])
+			}
+			if !region.Healthy {
+				cliui.Warnf(inv.Stderr, "Region %q is unhealthy, connections are relayed through another region until it's healthy again.", region.Name)
+			}
+			err = r.createConfig().Proxy().Write(region.Name)
+			if err != nil {
+				return xerrors.Errorf("write selected proxy: %w", err)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Connections to workspaces are relayed through %q.\n", region.Name)
+			return nil
+		},
+	}
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:        "auto",
+			Description: "Unpin the selected region and use the region with the lowest latency.",
+			Value:       clibase.BoolOf(&auto),
+		},
+	}
+	return cmd
+}
+
+// selectedProxy returns the name of the region pinned with coder proxy
+// select, or an empty string if none is pinned.
+func (r *RootCmd) selectedProxy() (string, error) {
+	name, err := r.createConfig().Proxy().Read()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", xerrors.Errorf("read selected proxy: %w", err)
+	}
+	return strings.TrimSpace(name), nil
+}
+
+// fastestProxyCacheTTL is how long the region with the lowest latency is
+// cached before the latency to every region is measured again.
+const fastestProxyCacheTTL = 10 * time.Minute
+
+// preferDERPRegion returns the DERP regions that connections to workspaces
+// should relay through: the ones of the region pinned with coder proxy
+// select, or of the region with the lowest latency. It returns nil if
+// there's only one region, or the regions can't be listed, so the
+// connection picks a DERP region by itself.
+func (r *RootCmd) preferDERPRegion(ctx context.Context, inv *clibase.Invocation, logger slog.Logger, client *codersdk.Client) func(region *tailcfg.DERPRegion) bool {
+	pinned, err := r.selectedProxy()
+	if err != nil {
+		logger.Warn(ctx, "read selected proxy", slog.Error(err))
+	}
+	regions, err := client.Regions(ctx)
+	if err != nil {
+		logger.Debug(ctx, "list regions to select proxy", slog.Error(err))
+		return nil
+	}
+
+	var selected codersdk.Region
+	if pinned != "" {
+		region, ok := regionByName(regions, pinned)
+		if !ok {
+			cliui.Warnf(inv.Stderr, "The selected proxy %q does not exist anymore, run \"coder proxy select\" to select another one.", pinned)
+			return nil
+		}
+		selected = region
+	} else {
+		if len(regions) < 2 {
+			return nil
+		}
+		fastest, ok := r.fastestProxy(ctx, logger, client, regions)
+		if !ok {
+			logger.Debug(ctx, "no region is reachable to select proxy")
+			return nil
+		}
+		selected = fastest
+	}
+	logger.Debug(ctx, "selected proxy", slog.F("name", selected.Name), slog.F("pinned", pinned != ""))
+
+	if selected.Name != codersdk.PrimaryRegionName {
+		code := codersdk.WorkspaceProxyDERPRegionCode(selected.Name)
+		return func(region *tailcfg.DERPRegion) bool {
+			return region.RegionCode == code
+		}
+	}
+	// The DERP regions of the primary aren't listed, so prefer every region
+	// that doesn't belong to a workspace proxy.
+	proxyCodes := make(map[string]struct{}, len(regions))
+	for _, region := range regions {
+		if region.Name != codersdk.PrimaryRegionName {
+			proxyCodes[codersdk.WorkspaceProxyDERPRegionCode(region.Name)] = struct{}{}
+		}
+	}
+	return func(region *tailcfg.DERPRegion) bool {
+		_, ok := proxyCodes[region.RegionCode]
+		return !ok
+	}
+}
+
+// fastestProxy returns the region with the lowest latency. The region is
+// cached for fastestProxyCacheTTL, as long as it's still healthy.
+func (r *RootCmd) fastestProxy(ctx context.Context, logger slog.Logger, client *codersdk.Client, regions []codersdk.Region) (codersdk.Region, bool) {
+	cache := r.createConfig().FastestProxy()
+	if info, err := os.Stat(string(cache)); err == nil && time.Since(info.ModTime()) < fastestProxyCacheTTL {
+		name, err := cache.Read()
+		if err == nil {
+			region, ok := regionByName(regions, strings.TrimSpace(name))
+			if ok && region.Healthy {
+				return region, true
+			}
+		}
+	}
+
+	fastest, ok := codersdk.FastestRegion(client.RegionLatencies(ctx, regions))
+	if !ok {
+		return codersdk.Region{}, false
+	}
+	err := cache.Write(fastest.Region.Name)
+	if err != nil {
+		logger.Debug(ctx, "cache fastest proxy", slog.Error(err))
+	}
+	return fastest.Region, true
+}
+
+func regionByName(regions []codersdk.Region, name string) (codersdk.Region, bool) {
+	for _, region := range regions {
+		if strings.EqualFold(region.Name, name) {
+			return region, true
+		}
+	}
+	return codersdk.Region{}, false
+}
@@ -0,0 +1,93 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestProxy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("List", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		inv, root := clitest.New(t, "proxy", "ls", "--output", "json")
+		clitest.SetupConfig(t, client, root)
+		buf := new(bytes.Buffer)
+		inv.Stdout = buf
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+
+		var rows []struct {
+			codersdk.Region
+			LatencyMS float64 `json:"latency_ms"`
+			Selected  bool    `json:"selected"`
+			Error     string  `json:"error"`
+		}
+		err = json.Unmarshal(buf.Bytes(), &rows)
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		require.Equal(t, codersdk.PrimaryRegionName, rows[0].Name)
+		require.Empty(t, rows[0].Error)
+		require.Positive(t, rows[0].LatencyMS)
+		// The fastest region is selected when none is pinned.
+		require.True(t, rows[0].Selected)
+	})
+
+	t.Run("Select", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		inv, root := clitest.New(t, "proxy", "select", "PRIMARY")
+		clitest.SetupConfig(t, client, root)
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+		selected, err := root.Proxy().Read()
+		require.NoError(t, err)
+		require.Equal(t, codersdk.PrimaryRegionName, selected)
+
+		inv, root = clitest.New(t, "proxy", "select", "--auto")
+		clitest.SetupConfig(t, client, root)
+		err = root.Proxy().Write(codersdk.PrimaryRegionName)
+		require.NoError(t, err)
+		err = inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+		_, err = root.Proxy().Read()
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("SelectMissing", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		inv, root := clitest.New(t, "proxy", "select", "missing")
+		clitest.SetupConfig(t, client, root)
+		err := inv.WithContext(ctx).Run()
+		require.ErrorContains(t, err, `region "missing" does not exist`)
+		_, err = root.Proxy().Read()
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) resume() *clibase.Cmd {
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Annotations: workspaceCommand,
+		Use:         "resume <workspace>",
+		Short:       "Resume the failed or canceled build of a workspace",
+		Long: "The build runs again with the template version, parameters and state of the\n" +
+			"interrupted build, so resources that were already applied are kept.",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			workspace, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
+			if err != nil {
+				return err
+			}
+			latest := workspace.LatestBuild
+			if latest.Job.Status != codersdk.ProvisionerJobFailed && latest.Job.Status != codersdk.ProvisionerJobCanceled {
+				return xerrors.Errorf("the latest build of workspace %q is %s, only failed or canceled builds can be resumed", workspace.Name, latest.Job.Status)
+			}
+
+			build, err := client.CreateWorkspaceBuild(inv.Context(), workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+				Transition: latest.Transition,
+				Resume:     true,
+			})
+			if err != nil {
+				return err
+			}
+
+			err = cliui.WorkspaceBuild(inv.Context(), inv.Stdout, client, build.ID)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "\nThe %s build of the %s workspace has been resumed at %s!\n", latest.Transition, cliui.DefaultStyles.Keyword.Render(workspace.Name), cliui.DefaultStyles.DateTimeStamp.Render(time.Now().Format(time.Stamp)))
+			return nil
+		},
+	}
+	return cmd
+}
@@ -84,20 +84,28 @@ func (r *RootCmd) Core() []*clibase.Cmd {
 		r.login(),
 		r.logout(),
 		r.netcheck(),
+		r.organizations(),
 		r.portForward(),
+		r.proxy(),
 		r.publickey(),
 		r.resetPassword(),
 		r.state(),
+		r.support(),
 		r.templates(),
 		r.tokens(),
 		r.users(),
 		r.version(defaultVersionInfo),
+		r.workspaces(),
 
 		// Workspace Commands
 		r.configSSH(),
+		r.clone(),
+		r.cp(),
 		r.create(),
 		r.deleteWorkspace(),
+		r.extend(),
 		r.list(),
+		r.logs(),
 		r.ping(),
 		r.rename(),
 		r.schedules(),
@@ -108,9 +116,11 @@ func (r *RootCmd) Core() []*clibase.Cmd {
 		r.stop(),
 		r.update(),
 		r.restart(),
+		r.resume(),
 		r.stat(),
 
 		// Hidden
+		r.gitCredentials(),
 		r.gitssh(),
 		r.vscodeSSH(),
 		r.workspaceAgent(),
@@ -688,10 +698,10 @@ func splitNamedWorkspace(identifier string) (owner string, workspaceName string,
 	return owner, workspaceName, nil
 }
 
-// namedWorkspace fetches and returns a workspace by an identifier, which may be either
+// NamedWorkspace fetches and returns a workspace by an identifier, which may be either
 // a bare name (for a workspace owned by the current user) or a "user/workspace" combination,
 // where user is either a username or UUID.
-func namedWorkspace(ctx context.Context, client *codersdk.Client, identifier string) (codersdk.Workspace, error) {
+func NamedWorkspace(ctx context.Context, client *codersdk.Client, identifier string) (codersdk.Workspace, error) {
 	owner, name, err := splitNamedWorkspace(identifier)
 	if err != nil {
 		return codersdk.Workspace{}, err
@@ -32,6 +32,9 @@ Schedule format: <start-time> [day-of-week] [location].
   * Location (optional) must be a valid location in the IANA timezone database.
     If omitted, we will fall back to either the TZ environment variable or /etc/localtime.
     You can check your corresponding location by visiting https://ipinfo.io - it shows in the demo widget on the right.
+Human-readable schedules such as "weekdays 9am Europe/Berlin" are accepted as well.
+  * Days may be daily, weekdays, weekends, a day of the week, a range (mon-fri) or a list (mon,wed,fri).
+  * The days, time and location may be given in any order.
 `
 	scheduleStopDescriptionLong = `Schedules a workspace to stop after a given duration has elapsed.
   * Workspace runtime is measured from the time that the workspace build completed.
@@ -83,7 +86,7 @@ func (r *RootCmd) scheduleShow() *clibase.Cmd {
 			r.InitClient(client),
 		),
 		Handler: func(inv *clibase.Invocation) error {
-			workspace, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			workspace, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
 			if err != nil {
 				return err
 			}
@@ -103,6 +106,10 @@ func (r *RootCmd) scheduleStart() *clibase.Cmd {
 				Description: "Set the workspace to start at 9:30am (in Dublin) from Monday to Friday",
 				Command:     "coder schedule start my-workspace 9:30AM Mon-Fri Europe/Dublin",
 			},
+			example{
+				Description: "Set the workspace to start at 9am (in Berlin) on weekdays",
+				Command:     "coder schedule start my-workspace weekdays 9am Europe/Berlin",
+			},
 		),
 		Short: "Edit workspace start schedule",
 		Middleware: clibase.Chain(
@@ -110,7 +117,7 @@ func (r *RootCmd) scheduleStart() *clibase.Cmd {
 			r.InitClient(client),
 		),
 		Handler: func(inv *clibase.Invocation) error {
-			workspace, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			workspace, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
 			if err != nil {
 				return err
 			}
@@ -132,7 +139,7 @@ func (r *RootCmd) scheduleStart() *clibase.Cmd {
 				return err
 			}
 
-			updated, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			updated, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
 			if err != nil {
 				return err
 			}
@@ -158,7 +165,7 @@ func (r *RootCmd) scheduleStop() *clibase.Cmd {
 			r.InitClient(client),
 		),
 		Handler: func(inv *clibase.Invocation) error {
-			workspace, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			workspace, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
 			if err != nil {
 				return err
 			}
@@ -178,7 +185,7 @@ func (r *RootCmd) scheduleStop() *clibase.Cmd {
 				return err
 			}
 
-			updated, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			updated, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
 			if err != nil {
 				return err
 			}
@@ -207,7 +214,7 @@ func (r *RootCmd) scheduleOverride() *clibase.Cmd {
 				return err
 			}
 
-			workspace, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			workspace, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
 			if err != nil {
 				return xerrors.Errorf("get workspace: %w", err)
 			}
@@ -232,7 +239,7 @@ func (r *RootCmd) scheduleOverride() *clibase.Cmd {
 				return err
 			}
 
-			updated, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			updated, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
 			if err != nil {
 				return err
 			}
@@ -69,6 +69,18 @@ func TestParseCLISchedule(t *testing.T) {
 			expectedSchedule: "CRON_TZ=America/Chicago 0 9 * * *",
 			tzEnv:            "UTC",
 		},
+		{
+			name:             "HumanReadable",
+			input:            []string{"weekdays", "9am", "Europe/Berlin"},
+			expectedSchedule: "CRON_TZ=Europe/Berlin 0 9 * * 1-5",
+			tzEnv:            "UTC",
+		},
+		{
+			name:             "HumanReadableDaysAfterTime",
+			input:            []string{"9:30am", "weekdays"},
+			expectedSchedule: "CRON_TZ=America/Chicago 30 9 * * 1-5",
+			tzEnv:            "America/Chicago",
+		},
 		{
 			name:          "InvalidTime",
 			input:         []string{"nine"},
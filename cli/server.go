@@ -10,6 +10,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
+	"encoding/base64"
 	"encoding/hex"
 	"errors"
 	"flag"
@@ -24,6 +25,7 @@ import (
 	"os"
 	"os/signal"
 	"os/user"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -44,6 +46,8 @@ import (
 	"github.com/spf13/afero"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/mod/semver"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"golang.org/x/oauth2"
 	xgithub "golang.org/x/oauth2/github"
 	"golang.org/x/sync/errgroup"
@@ -62,6 +66,7 @@ import (
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/cli/config"
 	"github.com/coder/coder/coderd"
+	"github.com/coder/coder/coderd/acmecert"
 	"github.com/coder/coder/coderd/autobuild"
 	"github.com/coder/coder/coderd/batchstats"
 	"github.com/coder/coder/coderd/database"
@@ -77,19 +82,24 @@ import (
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/oauthpki"
+	"github.com/coder/coder/coderd/prebuilds"
 	"github.com/coder/coder/coderd/prometheusmetrics"
 	"github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/telemetry"
+	"github.com/coder/coder/coderd/templateregistry"
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/coderd/unhanger"
 	"github.com/coder/coder/coderd/updatecheck"
+	"github.com/coder/coder/coderd/usersecrets"
 	"github.com/coder/coder/coderd/util/slice"
+	"github.com/coder/coder/coderd/webhooks"
 	"github.com/coder/coder/coderd/workspaceapps"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/cryptorand"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/provisioner/terraform"
 	"github.com/coder/coder/provisionerd"
+	provisionerdcache "github.com/coder/coder/provisionerd/cache"
 	"github.com/coder/coder/provisionerd/proto"
 	"github.com/coder/coder/provisionersdk"
 	sdkproto "github.com/coder/coder/provisionersdk/proto"
@@ -504,6 +514,11 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 					HostnamePrefix:   cfg.SSHConfig.DeploymentName.String(),
 					SSHConfigOptions: configSSHOptions,
 				},
+				ServerTailnetPoolOptions: coderd.ServerTailnetPoolOptions{
+					MaxIdlePerAgent:     int(cfg.ServerTailnetMaxIdlePerAgent.Value()),
+					IdleTTL:             cfg.ServerTailnetIdleTTL.Value(),
+					HealthCheckInterval: cfg.ServerTailnetHealthInterval.Value(),
+				},
 			}
 			if httpServers.TLSConfig != nil {
 				options.TLSCertificates = httpServers.TLSConfig.Certificates
@@ -518,6 +533,32 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 				}
 			}
 
+			options.WorkspaceProxyClientCertCfg, err = httpmw.WorkspaceProxyClientCertConfigOptions(
+				cfg.TLS.ProxyClientCAFile.String(), cfg.TLS.ProxyRequireClientCert.Value(),
+			)
+			if err != nil {
+				return xerrors.Errorf("coderd: configure workspace proxy client certificates: %w", err)
+			}
+
+			if cfg.UserSecretsEncryptionKey != "" {
+				key, err := base64.StdEncoding.DecodeString(cfg.UserSecretsEncryptionKey.String())
+				if err != nil {
+					return xerrors.Errorf("decode --user-secrets-encryption-key: %w", err)
+				}
+				options.UserSecretsCipher, err = usersecrets.New(key)
+				if err != nil {
+					return xerrors.Errorf("coderd: configure user secrets: %w", err)
+				}
+			}
+
+			for _, raw := range cfg.TemplateRegistries.Value() {
+				registry, err := templateregistry.ParseRegistry(raw)
+				if err != nil {
+					return xerrors.Errorf("parse --template-registries: %w", err)
+				}
+				options.TemplateRegistries = append(options.TemplateRegistries, registry)
+			}
+
 			if cfg.UpdateCheck {
 				options.UpdateCheckOptions = &updatecheck.Options{
 					// Avoid spamming GitHub API checking for updates.
@@ -628,6 +669,41 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 				}
 			}
 
+			options.TokenExchangePolicies, err = configureTokenExchangePolicies(ctx, cfg.OIDC.TokenExchangePolicies.Value, cfg.MaxTokenLifetime.Value())
+			if err != nil {
+				return xerrors.Errorf("configure token exchange policies: %w", err)
+			}
+
+			if cfg.WorkspaceAppOIDC.IssuerURL != "" {
+				if cfg.WorkspaceAppOIDC.ClientID == "" {
+					return xerrors.Errorf("workspace app OIDC client ID must be set!")
+				}
+				appOIDCProvider, err := oidc.NewProvider(ctx, cfg.WorkspaceAppOIDC.IssuerURL.String())
+				if err != nil {
+					return xerrors.Errorf("configure workspace app oidc provider: %w", err)
+				}
+				redirectURL, err := cfg.AccessURL.Value().Parse("/api/v2/applications/oidc/callback")
+				if err != nil {
+					return xerrors.Errorf("parse workspace app oidc callback url: %w", err)
+				}
+				options.WorkspaceAppsOIDCConfig = &coderd.WorkspaceAppsOIDCConfig{
+					OAuth2Config: &oauth2.Config{
+						ClientID:     cfg.WorkspaceAppOIDC.ClientID.String(),
+						ClientSecret: cfg.WorkspaceAppOIDC.ClientSecret.String(),
+						RedirectURL:  redirectURL.String(),
+						Endpoint:     appOIDCProvider.Endpoint(),
+						Scopes:       []string{oidc.ScopeOpenID, "email"},
+					},
+					// The audience of ID tokens must be the client ID, so
+					// tokens issued to other clients of the provider are
+					// rejected.
+					Verifier: appOIDCProvider.Verifier(&oidc.Config{
+						ClientID: cfg.WorkspaceAppOIDC.ClientID.String(),
+					}),
+					EmailDomain: cfg.WorkspaceAppOIDC.EmailDomain,
+				}
+			}
+
 			if cfg.InMemoryDatabase {
 				// This is only used for testing.
 				options.Database = dbfake.New()
@@ -845,6 +921,36 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 			options.StatsBatcher = batcher
 			defer closeBatcher()
 
+			if addr := cfg.WorkspaceAppStats.StatsDAddress.String(); addr != "" {
+				statsdReporter, err := workspaceapps.NewStatsDReporter(addr)
+				if err != nil {
+					return xerrors.Errorf("create workspace app stats statsd reporter: %w", err)
+				}
+				defer statsdReporter.Close()
+				options.WorkspaceAppsStatsSinks = append(options.WorkspaceAppsStatsSinks, statsdReporter)
+			}
+			if endpoint := cfg.WorkspaceAppStats.OTLPEndpoint.String(); endpoint != "" {
+				otlpReporter, err := workspaceapps.NewStatsOTLPReporter(httpClient, endpoint)
+				if err != nil {
+					return xerrors.Errorf("create workspace app stats otlp reporter: %w", err)
+				}
+				options.WorkspaceAppsStatsSinks = append(options.WorkspaceAppsStatsSinks, otlpReporter)
+			}
+
+			if urls := cfg.Webhooks.URLs.Value(); len(urls) > 0 {
+				dispatcher, err := webhooks.New(logger.Named("webhooks"), webhooks.Options{
+					URLs:          urls,
+					SigningSecret: cfg.Webhooks.SigningSecret.String(),
+					MaxAttempts:   int(cfg.Webhooks.MaxAttempts.Value()),
+					Client:        httpClient,
+				})
+				if err != nil {
+					return xerrors.Errorf("create webhook dispatcher: %w", err)
+				}
+				defer dispatcher.Close()
+				options.Webhooks = dispatcher
+			}
+
 			closeCheckInactiveUsersFunc := dormancy.CheckInactiveUsers(ctx, logger, options.Database)
 			defer closeCheckInactiveUsersFunc()
 
@@ -856,6 +962,18 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 				return xerrors.Errorf("create coder API: %w", err)
 			}
 
+			if interval := cfg.DERP.Config.ReloadInterval.Value(); interval > 0 && (cfg.DERP.Config.URL != "" || cfg.DERP.Config.Path != "") {
+				// Reload the DERP map from its source so changes reach
+				// agents and clients without a restart.
+				go tailnet.ReloadDERPMap(ctx, logger.Named("derpmap"), interval, derpMap, func(ctx context.Context) (*tailcfg.DERPMap, error) {
+					return tailnet.NewDERPMap(
+						ctx, defaultRegion, cfg.DERP.Server.STUNAddresses,
+						cfg.DERP.Config.URL.String(), cfg.DERP.Config.Path.String(),
+						cfg.DERP.Config.BlockDirect.Value(),
+					)
+				}, coderAPI.SetBaseDERPMap)
+			}
+
 			if cfg.Prometheus.Enable {
 				// Agent metrics require reference to the tailnet coordinator, so must be initiated after Coder API.
 				closeAgentsFunc, err := prometheusmetrics.Agents(ctx, logger, options.PrometheusRegistry, coderAPI.Database, &coderAPI.TailnetCoordinator, coderAPI.DERPMap, coderAPI.Options.AgentInactiveDisconnectTimeout, 0)
@@ -863,6 +981,12 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 					return xerrors.Errorf("register agents prometheus metric: %w", err)
 				}
 				defer closeAgentsFunc()
+
+				closeDERPFunc, err := prometheusmetrics.DERP(ctx, logger.Named("derp_metrics"), options.PrometheusRegistry, coderAPI.DERPServer, coderAPI.DERPMap, 0)
+				if err != nil {
+					return xerrors.Errorf("register derp prometheus metric: %w", err)
+				}
+				defer closeDERPFunc()
 			}
 
 			client := codersdk.New(localURL)
@@ -904,10 +1028,23 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 			var provisionerdWaitGroup sync.WaitGroup
 			defer provisionerdWaitGroup.Wait()
 			provisionerdMetrics := provisionerd.NewMetrics(options.PrometheusRegistry)
+			// The built-in provisioner daemons share a cache of Terraform
+			// providers and modules.
+			var provisionerdCache *provisionerdcache.Cache
+			if cfg.Provisioner.Daemons.Value() > 0 && cfg.Provisioner.DaemonCacheMaxSize.Value() > 0 {
+				provisionerdCache, err = provisionerdcache.New(ctx, provisionerdcache.Options{
+					Logger:    logger.Named("provisionerd-cache"),
+					Directory: filepath.Join(cacheDir, "provisionerd-cache"),
+					MaxSize:   cfg.Provisioner.DaemonCacheMaxSize.Value() << 20,
+				})
+				if err != nil {
+					return xerrors.Errorf("create provisioner daemon cache: %w", err)
+				}
+			}
 			for i := int64(0); i < cfg.Provisioner.Daemons.Value(); i++ {
 				daemonCacheDir := filepath.Join(cacheDir, fmt.Sprintf("provisioner-%d", i))
 				daemon, err := newProvisionerDaemon(
-					ctx, coderAPI, provisionerdMetrics, logger, cfg, daemonCacheDir, errCh, &provisionerdWaitGroup,
+					ctx, coderAPI, provisionerdMetrics, provisionerdCache, logger, cfg, daemonCacheDir, errCh, &provisionerdWaitGroup,
 				)
 				if err != nil {
 					return xerrors.Errorf("create provisioner daemon: %w", err)
@@ -923,12 +1060,26 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 			purger := dbpurge.New(ctx, logger, options.Database)
 			defer purger.Close()
 
+			acmeManager, err := ConfigureACME(ctx, logger, cfg, httpServers, acmecert.NewDatabaseStore(options.Database))
+			if err != nil {
+				return xerrors.Errorf("configure acme: %w", err)
+			}
+			if acmeManager != nil {
+				defer acmeManager.Close()
+			}
+
 			// Wrap the server in middleware that redirects to the access URL if
 			// the request is not to a local IP.
 			var handler http.Handler = coderAPI.RootHandler
 			if cfg.RedirectToAccessURL {
 				handler = redirectToAccessURL(handler, cfg.AccessURL.Value(), tunnel != nil, appHostnameRegex)
 			}
+			// Optionally accept HTTP/2 without TLS so gRPC workspace apps can
+			// be served behind load balancers that terminate TLS. HTTP/2 over
+			// TLS is negotiated by the TLS listener.
+			if cfg.HTTP2Cleartext.Value() {
+				handler = h2c.NewHandler(handler, &http2.Server{})
+			}
 
 			// ReadHeaderTimeout is purposefully not enabled. It caused some
 			// issues with websockets over the dev tunnel.
@@ -987,6 +1138,9 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 			autobuildTicker := time.NewTicker(cfg.AutobuildPollInterval.Value())
 			defer autobuildTicker.Stop()
 			autobuildExecutor := autobuild.NewExecutor(ctx, options.Database, coderAPI.TemplateScheduleStore, logger, autobuildTicker.C)
+			autobuildExecutor.WithWebhooks(options.Webhooks, cfg.Webhooks.AutostopImminentWindow.Value())
+			autobuildExecutor.WithAgentDrain(cfg.AgentDrainGracePeriod.Value(), coderAPI.AgentInactiveDisconnectTimeout)
+			autobuildExecutor.WithMetrics(options.PrometheusRegistry)
 			autobuildExecutor.Run()
 
 			hangDetectorTicker := time.NewTicker(cfg.JobHangDetectorInterval.Value())
@@ -995,6 +1149,15 @@ func (r *RootCmd) Server(newAPI func(context.Context, *coderd.Options) (*coderd.
 			hangDetector.Start()
 			defer hangDetector.Close()
 
+			// Prebuilt workspaces are reconciled as often as the autobuild
+			// executor runs.
+			prebuildsTicker := time.NewTicker(cfg.AutobuildPollInterval.Value())
+			defer prebuildsTicker.Stop()
+			prebuildsController := prebuilds.New(ctx, options.Database, logger.Named("prebuilds"), prebuildsTicker.C).
+				WithMetrics(options.PrometheusRegistry)
+			prebuildsController.Start()
+			defer prebuildsController.Close()
+
 			// Currently there is no way to ask the server to shut
 			// itself down, so any exit signal will result in a non-zero
 			// exit of the server.
@@ -1265,6 +1428,7 @@ func newProvisionerDaemon(
 	ctx context.Context,
 	coderAPI *coderd.API,
 	metrics provisionerd.Metrics,
+	cache *provisionerdcache.Cache,
 	logger slog.Logger,
 	cfg *codersdk.DeploymentValues,
 	cacheDir string,
@@ -1369,6 +1533,7 @@ func newProvisionerDaemon(
 		WorkDirectory:       workDir,
 		TracerProvider:      coderAPI.TracerProvider,
 		Metrics:             &metrics,
+		Cache:               cache,
 	}), nil
 }
 
@@ -1407,6 +1572,103 @@ func loadCertificates(tlsCertFiles, tlsKeyFiles []string) ([]tls.Certificate, er
 	return certs, nil
 }
 
+// keyPairReloadInterval is how often client key pairs are checked for
+// modifications.
+const keyPairReloadInterval = time.Minute
+
+// keyPairReloader reloads a TLS key pair when its certificate or key file is
+// modified, so rotated client certificates are presented without a restart.
+type keyPairReloader struct {
+	certFile string
+	keyFile  string
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+func newKeyPairReloader(certFile, keyFile string) (*keyPairReloader, error) {
+	certificates, err := loadCertificates([]string{certFile}, []string{keyFile})
+	if err != nil {
+		return nil, err
+	}
+	modTime, err := keyPairModTime(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return &keyPairReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		cert:     &certificates[0],
+		modTime:  modTime,
+	}, nil
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate. The
+// previous key pair is presented while the modified one fails to load, e.g.
+// when only the certificate has been replaced yet.
+func (r *keyPairReloader) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reloadLocked()
+	return r.cert, nil
+}
+
+// reloadLocked loads the key pair if either file was modified, and returns
+// whether it was replaced.
+func (r *keyPairReloader) reloadLocked() bool {
+	modTime, err := keyPairModTime(r.certFile, r.keyFile)
+	if err != nil || !modTime.After(r.modTime) {
+		return false
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return true
+}
+
+// watch checks the key pair for modifications every interval until the
+// context is canceled, and calls onReload after it was replaced. Key pairs
+// are only presented in TLS handshakes, so onReload should close the
+// connections that were established with the previous one.
+func (r *keyPairReloader) watch(ctx context.Context, interval time.Duration, onReload func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		r.mu.Lock()
+		reloaded := r.reloadLocked()
+		r.mu.Unlock()
+		if reloaded {
+			onReload()
+		}
+	}
+}
+
+// keyPairModTime returns when the certificate or key file was last modified.
+func keyPairModTime(certFile, keyFile string) (time.Time, error) {
+	var modTime time.Time
+	for _, name := range []string{certFile, keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, xerrors.Errorf("stat %q: %w", name, err)
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return modTime, nil
+}
+
 // generateSelfSignedCertificate creates an unsafe self-signed certificate
 // at random that allows users to proceed with setup in the event they
 // haven't configured any TLS certificates.
@@ -1513,6 +1775,89 @@ func configureTLS(tlsMinVersion, tlsClientAuth string, tlsCertFiles, tlsKeyFiles
 	return tlsConfig, nil
 }
 
+// ConfigureACME issues the certificate of the wildcard access URL over ACME
+// and serves it from the TLS listener. Certificates are shared through the
+// store, so it must be the same for all replicas. Returns nil if ACME is
+// disabled.
+func ConfigureACME(ctx context.Context, logger slog.Logger, cfg *codersdk.DeploymentValues, httpServers *HTTPServers, store acmecert.Store) (*acmecert.Manager, error) {
+	if !cfg.TLS.ACMEEnable.Value() {
+		return nil, nil
+	}
+	if httpServers.TLSConfig == nil {
+		return nil, xerrors.New("--tls-enable must be set to issue certificates over ACME")
+	}
+	if cfg.WildcardAccessURL.String() == "" {
+		return nil, xerrors.New("--wildcard-access-url must be set to issue certificates over ACME")
+	}
+	if cfg.TLS.ACMEDNSHook.String() == "" {
+		return nil, xerrors.New("--tls-acme-dns-hook must be set to issue certificates over ACME")
+	}
+	encryptionKey, err := base64.StdEncoding.DecodeString(cfg.TLS.ACMEEncryptionKey.String())
+	if err != nil {
+		return nil, xerrors.Errorf("decode --tls-acme-encryption-key: %w", err)
+	}
+
+	manager, err := acmecert.New(ctx, acmecert.Options{
+		Logger:        logger.Named("acme"),
+		Domain:        cfg.WildcardAccessURL.String(),
+		DirectoryURL:  cfg.TLS.ACMEDirectoryURL.String(),
+		Email:         cfg.TLS.ACMEEmail.String(),
+		DNS:           acmecert.ExecDNSProvider{Path: cfg.TLS.ACMEDNSHook.String()},
+		Store:         store,
+		EncryptionKey: encryptionKey,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("create acme manager: %w", err)
+	}
+	// The listener isn't serving yet, so the config can still be changed.
+	httpServers.TLSConfig.GetCertificate = manager.WrapGetCertificate(httpServers.TLSConfig.GetCertificate)
+	return manager, nil
+}
+
+// configureTokenExchangePolicies validates the policies and discovers the
+// signing keys of their issuers.
+func configureTokenExchangePolicies(ctx context.Context, policies []codersdk.TokenExchangePolicy, maxLifetime time.Duration) ([]coderd.TokenExchangePolicy, error) {
+	providers := make(map[string]*oidc.Provider)
+	configured := make([]coderd.TokenExchangePolicy, 0, len(policies))
+	for i, policy := range policies {
+		if policy.Issuer == "" || policy.Audience == "" || policy.Username == "" {
+			return nil, xerrors.Errorf("policy %d: issuer, audience and username must be set", i)
+		}
+		if len(policy.Claims) == 0 {
+			return nil, xerrors.Errorf("policy %d: at least one claim must be set, otherwise every token of %q is accepted", i, policy.Issuer)
+		}
+		for name, pattern := range policy.Claims {
+			_, err := path.Match(pattern, "")
+			if err != nil {
+				return nil, xerrors.Errorf("policy %d: invalid pattern of claim %q: %w", i, name, err)
+			}
+		}
+		if policy.Scope != "" && !database.APIKeyScope(policy.Scope).Valid() {
+			return nil, xerrors.Errorf("policy %d: invalid scope %q", i, policy.Scope)
+		}
+		if policy.Lifetime < 0 || policy.Lifetime > maxLifetime {
+			return nil, xerrors.Errorf("policy %d: lifetime must be between 0 and the max token lifetime %s", i, maxLifetime)
+		}
+
+		provider, ok := providers[policy.Issuer]
+		if !ok {
+			var err error
+			provider, err = oidc.NewProvider(ctx, policy.Issuer)
+			if err != nil {
+				return nil, xerrors.Errorf("policy %d: discover issuer %q: %w", i, policy.Issuer, err)
+			}
+			providers[policy.Issuer] = provider
+		}
+		configured = append(configured, coderd.TokenExchangePolicy{
+			TokenExchangePolicy: policy,
+			Verifier: provider.Verifier(&oidc.Config{
+				ClientID: policy.Audience,
+			}),
+		})
+	}
+	return configured, nil
+}
+
 func configureOIDCPKI(orig *oauth2.Config, keyFile string, certFile string) (*oauthpki.Config, error) {
 	// Read the files
 	keyData, err := os.ReadFile(keyFile)
@@ -1750,24 +2095,31 @@ func startBuiltinPostgres(ctx context.Context, cfg config.Root, logger slog.Logg
 
 func ConfigureHTTPClient(ctx context.Context, clientCertFile, clientKeyFile string, tlsClientCAFile string) (context.Context, *http.Client, error) {
 	if clientCertFile != "" && clientKeyFile != "" {
-		certificates, err := loadCertificates([]string{clientCertFile}, []string{clientKeyFile})
+		keyPair, err := newKeyPairReloader(clientCertFile, clientKeyFile)
 		if err != nil {
 			return ctx, nil, err
 		}
 
 		tlsClientConfig := &tls.Config{ //nolint:gosec
-			Certificates: certificates,
-			NextProtos:   []string{"h2", "http/1.1"},
+			GetClientCertificate: keyPair.GetClientCertificate,
+			NextProtos:           []string{"h2", "http/1.1"},
 		}
 		err = configureCAPool(tlsClientCAFile, tlsClientConfig)
 		if err != nil {
 			return nil, nil, err
 		}
 
+		transport := &http.Transport{
+			TLSClientConfig: tlsClientConfig,
+		}
+		// Idle connections are closed once the key pair is replaced, so the
+		// following requests present the new one. Connections that are in
+		// use, like websockets, keep the key pair they were established with
+		// until they are closed.
+		go keyPair.watch(ctx, keyPairReloadInterval, transport.CloseIdleConnections)
+
 		httpClient := &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: tlsClientConfig,
-			},
+			Transport: transport,
 		}
 		return context.WithValue(ctx, oauth2.HTTPClient, httpClient), httpClient, nil
 	}
@@ -2193,6 +2545,11 @@ func ConfigureHTTPServers(inv *clibase.Invocation, cfg *codersdk.DeploymentValue
 		if err != nil {
 			return nil, xerrors.Errorf("configure tls: %w", err)
 		}
+		// Client certificates of workspace proxies are verified by the
+		// workspace proxy middleware, so they must at least be requested.
+		if cfg.TLS.ProxyClientCAFile.String() != "" && tlsConfig.ClientAuth == tls.NoClientCert {
+			tlsConfig.ClientAuth = tls.RequestClientCert
+		}
 		httpsListenerInner, err := net.Listen("tcp", cfg.TLS.Address.String())
 		if err != nil {
 			return nil, err
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/testutil"
+)
+
+func TestKeyPairReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	writeTestKeyPair(t, certFile, keyFile, "first")
+
+	reloader, err := newKeyPairReloader(certFile, keyFile)
+	require.NoError(t, err)
+	cert, err := reloader.GetClientCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "first", commonName(t, cert.Certificate[0]))
+
+	// The previous key pair is presented until both files are replaced.
+	writeTestKeyPair(t, certFile, filepath.Join(dir, "unused.key"), "second")
+	touch(t, certFile, time.Now().Add(time.Minute))
+	cert, err = reloader.GetClientCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "first", commonName(t, cert.Certificate[0]))
+
+	writeTestKeyPair(t, certFile, keyFile, "second")
+	touch(t, certFile, time.Now().Add(2*time.Minute))
+	cert, err = reloader.GetClientCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "second", commonName(t, cert.Certificate[0]))
+}
+
+func TestKeyPairReloaderWatch(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.crt")
+	keyFile := filepath.Join(dir, "client.key")
+	writeTestKeyPair(t, certFile, keyFile, "first")
+
+	reloader, err := newKeyPairReloader(certFile, keyFile)
+	require.NoError(t, err)
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	reloaded := make(chan struct{}, 1)
+	go reloader.watch(ctx, testutil.IntervalFast, func() {
+		reloaded <- struct{}{}
+	})
+
+	// The key pair is replaced without a handshake asking for it.
+	writeTestKeyPair(t, certFile, keyFile, "second")
+	touch(t, certFile, time.Now().Add(time.Minute))
+	select {
+	case <-reloaded:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the key pair to be reloaded")
+	}
+	reloader.mu.Lock()
+	require.Equal(t, "second", commonName(t, reloader.cert.Certificate[0]))
+	reloader.mu.Unlock()
+}
+
+func writeTestKeyPair(t *testing.T, certFile, keyFile, name string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	require.NoError(t, err)
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600)
+	require.NoError(t, err)
+}
+
+func touch(t *testing.T, name string, modTime time.Time) {
+	t.Helper()
+
+	err := os.Chtimes(name, modTime, modTime)
+	require.NoError(t, err)
+}
+
+func commonName(t *testing.T, der []byte) string {
+	t.Helper()
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert.Subject.CommonName
+}
@@ -46,12 +46,18 @@ func (r *RootCmd) ssh() *clibase.Cmd {
 		stdio          bool
 		forwardAgent   bool
 		forwardGPG     bool
+		forwardX11     bool
 		identityAgent  string
 		wsPollInterval time.Duration
 		waitEnum       string
 		noWait         bool
 		logDirPath     string
 		remoteForward  string
+		keepalive      time.Duration
+
+		multiplex        bool
+		multiplexPersist time.Duration
+		multiplexServe   bool
 	)
 	client := new(codersdk.Client)
 	cmd := &clibase.Cmd{
@@ -131,6 +137,34 @@ func (r *RootCmd) ssh() *clibase.Cmd {
 				}
 			}
 
+			// Multiplexing only applies to the stdio mode, which is how
+			// OpenSSH and IDEs run coder ssh.
+			var controlPath string
+			if (multiplex && stdio) || multiplexServe {
+				controlPath = sshControlPath(r.createConfig(), client, inv.Args[0])
+			}
+			// Without waiting for the agent, an existing multiplexed
+			// connection is used before looking up the workspace, so the
+			// session doesn't need any API requests.
+			useControlPath := func() bool {
+				if controlPath == "" || multiplexServe {
+					return false
+				}
+				rawSSH, err := codersdk.DialWorkspaceAgentControl(ctx, controlPath)
+				if err != nil {
+					logger.Debug(ctx, "no multiplexed connection", slog.Error(err))
+					return false
+				}
+				logger.Info(ctx, "using multiplexed connection", slog.F("control_path", controlPath))
+				defer rawSSH.Close()
+				sshStdio(ctx, inv, logger, &wg, rawSSH)
+				return true
+			}
+			skipWait := noWait || waitEnum == "no"
+			if skipWait && useControlPath() {
+				return nil
+			}
+
 			workspace, workspaceAgent, err := getWorkspaceAndAgent(ctx, inv, client, codersdk.Me, inv.Args[0])
 			if err != nil {
 				return err
@@ -195,12 +229,27 @@ func (r *RootCmd) ssh() *clibase.Cmd {
 				}
 			}
 
+			if !skipWait && useControlPath() {
+				return nil
+			}
+			if controlPath != "" && !multiplexServe {
+				rawSSH, err := startSSHMultiplexMaster(ctx, inv, controlPath)
+				if err == nil {
+					logger.Info(ctx, "using new multiplexed connection", slog.F("control_path", controlPath))
+					defer rawSSH.Close()
+					sshStdio(ctx, inv, logger, &wg, rawSSH)
+					return nil
+				}
+				logger.Warn(ctx, "start multiplexing master, connecting directly", slog.Error(err))
+			}
+
 			if r.disableDirect {
 				_, _ = fmt.Fprintln(inv.Stderr, "Direct connections disabled.")
 			}
 			conn, err := client.DialWorkspaceAgent(ctx, workspaceAgent.ID, &codersdk.DialWorkspaceAgentOptions{
-				Logger:         logger,
-				BlockEndpoints: r.disableDirect,
+				Logger:           logger,
+				BlockEndpoints:   r.disableDirect,
+				PreferDERPRegion: r.preferDERPRegion(ctx, inv, logger, client),
 			})
 			if err != nil {
 				return xerrors.Errorf("dial agent: %w", err)
@@ -211,6 +260,25 @@ func (r *RootCmd) ssh() *clibase.Cmd {
 			stopPolling := tryPollWorkspaceAutostop(ctx, client, workspace)
 			defer stopPolling()
 
+			// IDEs keep sessions open for days, so keep the connection and
+			// the session token alive for as long as the session runs.
+			if keepalive > 0 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					sshKeepalive(ctx, logger, conn, keepalive)
+				}()
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sshRefreshSession(ctx, logger, client, r.createConfig())
+			}()
+
+			if multiplexServe {
+				return serveSSHMultiplex(ctx, logger, &wg, client, workspace, conn, controlPath, multiplexPersist)
+			}
+
 			if stdio {
 				rawSSH, err := conn.SSH(ctx)
 				if err != nil {
@@ -226,27 +294,7 @@ func (r *RootCmd) ssh() *clibase.Cmd {
 					}, logger, client, workspace)
 				}()
 
-				wg.Add(1)
-				go func() {
-					defer wg.Done()
-					// Ensure stdout copy closes incase stdin is closed
-					// unexpectedly. Typically we wouldn't worry about
-					// this since OpenSSH should kill the proxy command.
-					defer rawSSH.Close()
-
-					_, err := io.Copy(rawSSH, inv.Stdin)
-					if err != nil {
-						logger.Error(ctx, "copy stdin error", slog.Error(err))
-					} else {
-						logger.Debug(ctx, "copy stdin complete")
-					}
-				}()
-				_, err = io.Copy(inv.Stdout, rawSSH)
-				if err != nil {
-					logger.Error(ctx, "copy stdout error", slog.Error(err))
-				} else {
-					logger.Debug(ctx, "copy stdout complete")
-				}
+				sshStdio(ctx, inv, logger, &wg, rawSSH)
 				return nil
 			}
 
@@ -310,6 +358,22 @@ func (r *RootCmd) ssh() *clibase.Cmd {
 				defer closer.Close()
 			}
 
+			if forwardX11 {
+				// Like OpenSSH, the session continues without X11 if
+				// forwarding isn't possible.
+				switch {
+				case workspaceAgent.OperatingSystem == "windows":
+					cliui.Warn(inv.Stderr, "X11 forwarding is not supported for Windows workspaces")
+				case os.Getenv("DISPLAY") == "":
+					cliui.Warn(inv.Stderr, "X11 forwarding requested but $DISPLAY is not set")
+				default:
+					err = sshForwardX11(ctx, inv.Stderr, sshClient, sshSession, os.Getenv("DISPLAY"))
+					if err != nil {
+						cliui.Warnf(inv.Stderr, "X11 forwarding failed: %s", err)
+					}
+				}
+			}
+
 			if remoteForward != "" {
 				localAddr, remoteAddr, err := parseRemoteForward(remoteForward)
 				if err != nil {
@@ -405,6 +469,26 @@ func (r *RootCmd) ssh() *clibase.Cmd {
 			Description: "Specifies whether to emit SSH output over stdin/stdout.",
 			Value:       clibase.BoolOf(&stdio),
 		},
+		{
+			Flag:        "multiplex",
+			Env:         "CODER_SSH_MULTIPLEX",
+			Description: "Specifies whether to share a single connection to the workspace between concurrent sessions in the stdio mode, like OpenSSH's ControlMaster. The first session starts a background process that holds the connection, so following sessions connect in milliseconds.",
+			Value:       clibase.BoolOf(&multiplex),
+		},
+		{
+			Flag:        "multiplex-persist",
+			Env:         "CODER_SSH_MULTIPLEX_PERSIST",
+			Description: "Specifies how long a multiplexed connection stays open after the last session using it ends.",
+			Default:     "10m",
+			Value:       clibase.DurationOf(&multiplexPersist),
+		},
+		{
+			Flag:        "multiplex-serve",
+			Env:         envSSHMultiplexServe,
+			Description: "Serve multiplexed connections instead of starting a session. Used by the background process started by --multiplex.",
+			Value:       clibase.BoolOf(&multiplexServe),
+			Hidden:      true,
+		},
 		{
 			Flag:          "forward-agent",
 			FlagShorthand: "A",
@@ -419,6 +503,13 @@ func (r *RootCmd) ssh() *clibase.Cmd {
 			Description:   "Specifies whether to forward the GPG agent. Unsupported on Windows workspaces, but supports all clients. Requires gnupg (gpg, gpgconf) on both the client and workspace. The GPG agent must already be running locally and will not be started for you. If a GPG agent is already running in the workspace, it will be attempted to be killed.",
 			Value:         clibase.BoolOf(&forwardGPG),
 		},
+		{
+			Flag:          "forward-x11",
+			FlagShorthand: "X",
+			Env:           "CODER_SSH_FORWARD_X11",
+			Description:   "Specifies whether to forward X11 connections from the workspace to the display in $DISPLAY. Unsupported on Windows workspaces. Requires xauth on the client if the display uses authentication.",
+			Value:         clibase.BoolOf(&forwardX11),
+		},
 		{
 			Flag:        "identity-agent",
 			Env:         "CODER_SSH_IDENTITY_AGENT",
@@ -447,6 +538,13 @@ func (r *RootCmd) ssh() *clibase.Cmd {
 			FlagShorthand: "l",
 			Value:         clibase.StringOf(&logDirPath),
 		},
+		{
+			Flag:        "keepalive",
+			Env:         "CODER_SSH_KEEPALIVE",
+			Description: "Specifies how often to ping the workspace agent to keep idle connections alive through NATs and relays. Set to 0 to disable.",
+			Default:     "30s",
+			Value:       clibase.DurationOf(&keepalive),
+		},
 		{
 			Flag:          "remote-forward",
 			Description:   "Enable remote port forwarding (remote_port:local_address:local_port).",
@@ -534,7 +632,7 @@ func getWorkspaceAndAgent(ctx context.Context, inv *clibase.Invocation, client *
 		err            error
 	)
 
-	workspace, err = namedWorkspace(ctx, client, workspaceParts[0])
+	workspace, err = NamedWorkspace(ctx, client, workspaceParts[0])
 	if err != nil {
 		return codersdk.Workspace{}, codersdk.WorkspaceAgent{}, err
 	}
@@ -9,6 +9,7 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"syscall"
 
 	gossh "golang.org/x/crypto/ssh"
 	"golang.org/x/sys/unix"
@@ -46,3 +47,9 @@ func forwardGPGAgent(ctx context.Context, stderr io.Writer, sshClient *gossh.Cli
 
 	return sshRemoteForward(ctx, stderr, sshClient, localAddr, remoteAddr)
 }
+
+// sshMultiplexSysProcAttr starts the multiplexing master in a new session, so
+// it keeps running after the terminal or ProxyCommand that started it exits.
+func sshMultiplexSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -14,6 +14,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -21,6 +22,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pkg/sftp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/ssh"
@@ -32,6 +34,7 @@ import (
 	"github.com/coder/coder/agent"
 	"github.com/coder/coder/cli/clitest"
 	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/cli/config"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/codersdk"
@@ -255,6 +258,229 @@ func TestSSH(t *testing.T) {
 		<-cmdDone
 	})
 
+	t.Run("StdioMultiplex", func(t *testing.T) {
+		t.Parallel()
+		client, workspace, agentToken := setupWorkspaceForAgent(t, nil)
+		agentClient := agentsdk.New(client.URL)
+		agentClient.SetSessionToken(agentToken)
+		agentCloser := agent.New(agent.Options{
+			Client: agentClient,
+			Logger: slogtest.Make(t, nil).Named("agent"),
+		})
+		defer agentCloser.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		root := config.Root(t.TempDir())
+		clitest.SetupConfig(t, client, root)
+		_, stopMaster := serveSSHMultiplexMaster(ctx, t, root, workspace)
+		defer stopMaster()
+
+		logDir := t.TempDir()
+		command := "sh -c exit"
+		if runtime.GOOS == "windows" {
+			command = "cmd.exe /c exit"
+		}
+		// Both sessions are open at the same time.
+		sshClients := []*ssh.Client{
+			connectSSHStdio(ctx, t, "--global-config", string(root), "ssh", "--stdio", "--multiplex", "--log-dir", logDir, workspace.Name),
+			connectSSHStdio(ctx, t, "--global-config", string(root), "ssh", "--stdio", "--multiplex", "--log-dir", logDir, workspace.Name),
+		}
+		for _, sshClient := range sshClients {
+			session, err := sshClient.NewSession()
+			require.NoError(t, err)
+			err = session.Run(command)
+			require.NoError(t, err)
+		}
+		for _, sshClient := range sshClients {
+			err := sshClient.Close()
+			require.NoError(t, err)
+		}
+
+		logs, err := os.ReadDir(logDir)
+		require.NoError(t, err)
+		require.Len(t, logs, 2)
+		for _, log := range logs {
+			content, err := os.ReadFile(filepath.Join(logDir, log.Name()))
+			require.NoError(t, err)
+			require.Contains(t, string(content), "using multiplexed connection")
+		}
+	})
+
+	t.Run("StdioMultiplexWait", func(t *testing.T) {
+		t.Parallel()
+		client, workspace, agentToken := setupWorkspaceForAgent(t, nil)
+		agentClient := agentsdk.New(client.URL)
+		agentClient.SetSessionToken(agentToken)
+		agentCloser := agent.New(agent.Options{
+			Client: agentClient,
+			Logger: slogtest.Make(t, nil).Named("agent"),
+		})
+		defer agentCloser.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		root := config.Root(t.TempDir())
+		clitest.SetupConfig(t, client, root)
+		_, stopMaster := serveSSHMultiplexMaster(ctx, t, root, workspace)
+		defer stopMaster()
+
+		// Waiting for the agent needs the workspace to be looked up, even
+		// if a multiplexed connection exists.
+		for _, wait := range []string{"yes", "no"} {
+			logDir := t.TempDir()
+			sshClient := connectSSHStdio(ctx, t, "--global-config", string(root), "--verbose", "ssh", "--stdio", "--multiplex", "--wait", wait, "--log-dir", logDir, workspace.Name)
+			session, err := sshClient.NewSession()
+			require.NoError(t, err)
+			_ = session.Close()
+			err = sshClient.Close()
+			require.NoError(t, err)
+
+			logs, err := os.ReadDir(logDir)
+			require.NoError(t, err)
+			require.Len(t, logs, 1)
+			content, err := os.ReadFile(filepath.Join(logDir, logs[0].Name()))
+			require.NoError(t, err)
+			require.Contains(t, string(content), "using multiplexed connection")
+			lookup := "/api/v2/users/me/workspace/" + workspace.Name
+			if wait == "yes" {
+				require.Contains(t, string(content), lookup)
+			} else {
+				require.NotContains(t, string(content), lookup)
+			}
+		}
+	})
+
+	t.Run("StdioMultiplexStaleMaster", func(t *testing.T) {
+		t.Parallel()
+		client, workspace, agentToken := setupWorkspaceForAgent(t, nil)
+		agentClient := agentsdk.New(client.URL)
+		agentClient.SetSessionToken(agentToken)
+		agentCloser := agent.New(agent.Options{
+			Client: agentClient,
+			Logger: slogtest.Make(t, nil).Named("agent"),
+		})
+		defer agentCloser.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		root := config.Root(t.TempDir())
+		clitest.SetupConfig(t, client, root)
+		controlPath, stopMaster := serveSSHMultiplexMaster(ctx, t, root, workspace)
+		stopMaster()
+
+		// A master that lost its connection to the agent still accepts
+		// connections, but closes them right away.
+		staleListener, err := net.Listen("unix", controlPath)
+		require.NoError(t, err)
+		defer staleListener.Close()
+		go func() {
+			for {
+				conn, err := staleListener.Accept()
+				if err != nil {
+					return
+				}
+				_ = conn.Close()
+			}
+		}()
+		_, err = codersdk.DialWorkspaceAgentControl(ctx, controlPath)
+		require.Error(t, err)
+
+		// A new master replaces the socket of the stale one.
+		_, stopMaster = serveSSHMultiplexMaster(ctx, t, root, workspace)
+		defer stopMaster()
+
+		logDir := t.TempDir()
+		sshClient := connectSSHStdio(ctx, t, "--global-config", string(root), "ssh", "--stdio", "--multiplex", "--wait", "no", "--log-dir", logDir, workspace.Name)
+		session, err := sshClient.NewSession()
+		require.NoError(t, err)
+		_ = session.Close()
+		err = sshClient.Close()
+		require.NoError(t, err)
+
+		logs, err := os.ReadDir(logDir)
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		content, err := os.ReadFile(filepath.Join(logDir, logs[0].Name()))
+		require.NoError(t, err)
+		require.Contains(t, string(content), "using multiplexed connection")
+	})
+
+	t.Run("StdioSFTP", func(t *testing.T) {
+		t.Parallel()
+		client, workspace, agentToken := setupWorkspaceForAgent(t, nil)
+		agentClient := agentsdk.New(client.URL)
+		agentClient.SetSessionToken(agentToken)
+		agentCloser := agent.New(agent.Options{
+			Client: agentClient,
+			Logger: slogtest.Make(t, nil).Named("agent"),
+		})
+		defer agentCloser.Close()
+
+		clientOutput, clientInput := io.Pipe()
+		serverOutput, serverInput := io.Pipe()
+		defer func() {
+			for _, c := range []io.Closer{clientOutput, clientInput, serverOutput, serverInput} {
+				_ = c.Close()
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		// This is how "sftp" and "rsync" connect with the ProxyCommand
+		// written by "coder config-ssh".
+		inv, root := clitest.New(t, "ssh", "--stdio", workspace.Name)
+		clitest.SetupConfig(t, client, root)
+		inv.Stdin = clientOutput
+		inv.Stdout = serverInput
+		inv.Stderr = io.Discard
+		cmdDone := tGo(t, func() {
+			err := inv.WithContext(ctx).Run()
+			assert.NoError(t, err)
+		})
+
+		conn, channels, requests, err := ssh.NewClientConn(&stdioConn{
+			Reader: serverOutput,
+			Writer: clientInput,
+		}, "", &ssh.ClientConfig{
+			// #nosec
+			HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		sshClient := ssh.NewClient(conn, channels, requests)
+		sftpClient, err := sftp.NewClient(sshClient)
+		require.NoError(t, err)
+
+		tempFile := filepath.Join(t.TempDir(), "sftp")
+		remoteFile := filepath.ToSlash(tempFile)
+		if !path.IsAbs(remoteFile) {
+			// On Windows, e.g. "/C:/Users/...".
+			remoteFile = path.Join("/", remoteFile)
+		}
+		file, err := sftpClient.Create(remoteFile)
+		require.NoError(t, err)
+		_, err = file.Write([]byte("hello"))
+		require.NoError(t, err)
+		err = file.Close()
+		require.NoError(t, err)
+		content, err := os.ReadFile(tempFile)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(content))
+
+		err = sftpClient.Close()
+		require.NoError(t, err)
+		_ = sshClient.Close()
+		_ = clientOutput.Close()
+
+		<-cmdDone
+	})
+
 	t.Run("StdioExitOnStop", func(t *testing.T) {
 		t.Parallel()
 		if runtime.GOOS == "windows" {
@@ -748,6 +974,73 @@ func tGoContext(t *testing.T, fn func(context.Context)) (done <-chan struct{}, c
 // exit.
 //
 // NOTE(mafredri): This could be moved to a helper library.
+// serveSSHMultiplexMaster serves the control socket of the workspace from the
+// test, since the master started by --multiplex is a copy of the running
+// binary. It returns once the socket is served.
+func serveSSHMultiplexMaster(ctx context.Context, t *testing.T, root config.Root, workspace codersdk.Workspace) (controlPath string, stop func()) {
+	t.Helper()
+
+	inv, _ := clitest.New(t, "--global-config", string(root), "ssh", "--multiplex-serve", workspace.Name)
+	ctx, cancel := context.WithCancel(ctx)
+	done := tGo(t, func() {
+		err := inv.WithContext(ctx).Run()
+		assert.NoError(t, err)
+	})
+	require.Eventually(t, func() bool {
+		matches, err := filepath.Glob(filepath.Join(string(root), "ssh", "*.sock"))
+		if err != nil || len(matches) != 1 {
+			return false
+		}
+		conn, err := codersdk.DialWorkspaceAgentControl(ctx, matches[0])
+		if err != nil {
+			return false
+		}
+		_ = conn.Close()
+		controlPath = matches[0]
+		return true
+	}, testutil.WaitLong, testutil.IntervalFast)
+	return controlPath, func() {
+		cancel()
+		<-done
+	}
+}
+
+// connectSSHStdio runs coder ssh --stdio with args, and connects to it.
+func connectSSHStdio(ctx context.Context, t *testing.T, args ...string) *ssh.Client {
+	t.Helper()
+
+	clientOutput, clientInput := io.Pipe()
+	serverOutput, serverInput := io.Pipe()
+	t.Cleanup(func() {
+		for _, c := range []io.Closer{clientOutput, clientInput, serverOutput, serverInput} {
+			_ = c.Close()
+		}
+	})
+
+	inv, _ := clitest.New(t, args...)
+	inv.Stdin = clientOutput
+	inv.Stdout = serverInput
+	inv.Stderr = io.Discard
+	cmdDone := tGo(t, func() {
+		err := inv.WithContext(ctx).Run()
+		assert.NoError(t, err)
+	})
+	t.Cleanup(func() {
+		_ = clientOutput.Close()
+		<-cmdDone
+	})
+
+	conn, channels, requests, err := ssh.NewClientConn(&stdioConn{
+		Reader: serverOutput,
+		Writer: clientInput,
+	}, "", &ssh.ClientConfig{
+		// #nosec
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+	require.NoError(t, err)
+	return ssh.NewClient(conn, channels, requests)
+}
+
 func tGo(t *testing.T, fn func()) (done <-chan struct{}) {
 	t.Helper()
 
@@ -10,9 +10,11 @@ import (
 	"net"
 	"os"
 	"strconv"
+	"syscall"
 	"time"
 
 	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/sys/windows"
 	"golang.org/x/xerrors"
 )
 
@@ -103,3 +105,9 @@ func forwardGPGAgent(ctx context.Context, stderr io.Writer, sshClient *gossh.Cli
 
 	return sshRemoteForward(ctx, stderr, sshClient, localAddr, remoteAddr)
 }
+
+// sshMultiplexSysProcAttr starts the multiplexing master without a console, so
+// it keeps running after the console or ProxyCommand that started it exits.
+func sshMultiplexSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS}
+}
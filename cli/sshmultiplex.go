@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/config"
+	"github.com/coder/coder/codersdk"
+)
+
+// envSSHMultiplexServe makes coder ssh serve the control socket instead of
+// starting a session. It's set on the master started by --multiplex.
+const envSSHMultiplexServe = "CODER_SSH_MULTIPLEX_SERVE"
+
+// sshMultiplexStartTimeout is how long to wait for a new master to serve the
+// control socket before connecting without it.
+const sshMultiplexStartTimeout = 30 * time.Second
+
+// sshMultiplexLockTimeout is how long a new master waits for the master it
+// replaces to exit. A master that still serves the socket keeps the lock, so
+// the new one exits after the timeout.
+const sshMultiplexLockTimeout = 5 * time.Second
+
+// sshControlPath returns the control socket that multiplexes connections to
+// the workspace agent selected by arg. The session token is part of the
+// path, so a socket is never reused by another user or after logging out.
+func sshControlPath(configDir config.Root, client *codersdk.Client, arg string) string {
+	sum := sha256.Sum256([]byte(client.URL.String() + "\n" + client.SessionToken() + "\n" + arg))
+	// Unix socket paths are limited to about 100 bytes, so keep the name
+	// short.
+	return filepath.Join(string(configDir), "ssh", hex.EncodeToString(sum[:8])+".sock")
+}
+
+// startSSHMultiplexMaster starts a copy of the current command in the
+// background to serve the control socket, and connects through it. The
+// master is detached, so the multiplexed sessions aren't closed when OpenSSH
+// closes the ProxyCommand that started it.
+func startSSHMultiplexMaster(ctx context.Context, inv *clibase.Invocation, controlPath string) (net.Conn, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, xerrors.Errorf("get executable: %w", err)
+	}
+	//nolint:gosec // The arguments are the ones this process was started with.
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Env = append(inv.Environ.ToOS(), envSSHMultiplexServe+"=true")
+	cmd.SysProcAttr = sshMultiplexSysProcAttr()
+	err = cmd.Start()
+	if err != nil {
+		return nil, xerrors.Errorf("start master: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, sshMultiplexStartTimeout)
+	defer cancel()
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		conn, err := codersdk.DialWorkspaceAgentControl(ctx, controlPath)
+		if err == nil {
+			return conn, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, xerrors.Errorf("wait for master: %w", ctx.Err())
+		case err := <-exited:
+			// The master exits without an error when another process
+			// already serves the socket, so keep waiting for that one.
+			if err != nil {
+				return nil, xerrors.Errorf("master exited: %w", err)
+			}
+			exited = nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// serveSSHMultiplex serves the control socket over conn until the workspace
+// stops or the socket is idle for the persist duration.
+func serveSSHMultiplex(ctx context.Context, logger slog.Logger, wg *sync.WaitGroup, client *codersdk.Client, workspace codersdk.Workspace, conn *codersdk.WorkspaceAgentConn, controlPath string, persist time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	err := os.MkdirAll(filepath.Dir(controlPath), 0o700)
+	if err != nil {
+		return xerrors.Errorf("create control socket directory: %w", err)
+	}
+	// The lock is held for as long as the socket is served, so concurrent
+	// masters can't replace each other's socket.
+	lock := flock.New(controlPath + ".lock")
+	lockCtx, cancelLock := context.WithTimeout(ctx, sshMultiplexLockTimeout)
+	locked, err := lock.TryLockContext(lockCtx, 50*time.Millisecond)
+	cancelLock()
+	if err != nil && lockCtx.Err() == nil {
+		return xerrors.Errorf("lock control socket: %w", err)
+	}
+	if !locked {
+		logger.Info(ctx, "another process is serving the control socket", slog.F("control_path", controlPath))
+		return nil
+	}
+	defer func() {
+		_ = lock.Unlock()
+	}()
+
+	l, err := codersdk.ListenWorkspaceAgentControl(ctx, controlPath)
+	if err != nil {
+		return err
+	}
+	logger.Info(ctx, "serving control socket", slog.F("control_path", controlPath), slog.F("persist", persist))
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		watchAndClose(ctx, func() error {
+			cancel()
+			return nil
+		}, logger, client, workspace)
+	}()
+	return codersdk.ServeWorkspaceAgentControl(ctx, l, conn, codersdk.WorkspaceAgentControlOptions{
+		Persist: persist,
+		Logger:  logger,
+	})
+}
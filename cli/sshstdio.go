@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/config"
+	"github.com/coder/coder/codersdk"
+)
+
+// sshSessionRefreshInterval is how often a stdio proxy uses its session
+// token. coderd extends the expiry of tokens at most once an hour.
+const sshSessionRefreshInterval = 30 * time.Minute
+
+// sshStdio copies between rawSSH and the stdin and stdout of the invocation
+// until either side closes.
+func sshStdio(ctx context.Context, inv *clibase.Invocation, logger slog.Logger, wg *sync.WaitGroup, rawSSH net.Conn) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Ensure stdout copy closes incase stdin is closed
+		// unexpectedly. Typically we wouldn't worry about
+		// this since OpenSSH should kill the proxy command.
+		defer rawSSH.Close()
+
+		_, err := io.Copy(rawSSH, inv.Stdin)
+		if err != nil {
+			logger.Error(ctx, "copy stdin error", slog.Error(err))
+		} else {
+			logger.Debug(ctx, "copy stdin complete")
+		}
+	}()
+	_, err := io.Copy(inv.Stdout, rawSSH)
+	if err != nil {
+		logger.Error(ctx, "copy stdout error", slog.Error(err))
+	} else {
+		logger.Debug(ctx, "copy stdout complete")
+	}
+}
+
+// sshKeepalive pings the agent at the interval, so NAT mappings and DERP
+// connections stay alive while an SSH session is idle. Failures are logged,
+// since tailnet recovers from most of them on its own, e.g. when the
+// coordinator reconnects to another replica.
+func sshKeepalive(ctx context.Context, logger slog.Logger, conn *codersdk.WorkspaceAgentConn, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	failing := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		latency, _, _, err := conn.Ping(pingCtx)
+		cancel()
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return
+		case err != nil:
+			if !failing {
+				logger.Warn(ctx, "workspace agent is unreachable", slog.Error(err))
+			}
+			failing = true
+		case failing:
+			logger.Info(ctx, "workspace agent is reachable again", slog.F("latency", latency))
+			failing = false
+		default:
+			logger.Debug(ctx, "keepalive", slog.F("latency", latency))
+		}
+	}
+}
+
+// sshRefreshSession uses the session token of the client at an interval,
+// which extends its expiry. The connection to the coordinator only uses the
+// token when it connects, so without this it could expire during a long
+// session and reconnecting to the coordinator would fail. If the token is
+// rejected anyway, e.g. because the user logged out, the token from the
+// config is used if it changed.
+func sshRefreshSession(ctx context.Context, logger slog.Logger, client *codersdk.Client, configDir config.Root) {
+	ticker := time.NewTicker(sshSessionRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		_, err := client.User(ctx, codersdk.Me)
+		if err == nil {
+			continue
+		}
+		var sdkErr *codersdk.Error
+		if !xerrors.As(err, &sdkErr) || sdkErr.StatusCode() != http.StatusUnauthorized {
+			logger.Warn(ctx, "refresh session token", slog.Error(err))
+			continue
+		}
+		token, err := configDir.Session().Read()
+		if err != nil || token == client.SessionToken() {
+			logger.Error(ctx, "session token was rejected, run \"coder login\" to reconnect when the connection drops", slog.Error(sdkErr))
+			continue
+		}
+		logger.Info(ctx, "session token was rejected, using the session token from the config")
+		client.SetSessionToken(token)
+	}
+}
@@ -0,0 +1,220 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/agent/agentssh"
+	"github.com/coder/coder/cli/cliui"
+)
+
+const x11AuthProtocol = "MIT-MAGIC-COOKIE-1"
+
+// x11Display is a parsed $DISPLAY value.
+type x11Display struct {
+	network string
+	address string
+	screen  uint32
+}
+
+// parseX11Display parses the forms of $DISPLAY used by X servers:
+// ":0", ":0.0", "unix:0", "localhost:10.0" and the socket paths used by
+// XQuartz on macOS, e.g. "/private/tmp/com.apple.launchd.abc/org.xquartz:0".
+func parseX11Display(display string) (x11Display, error) {
+	colon := strings.LastIndex(display, ":")
+	if colon < 0 {
+		return x11Display{}, xerrors.Errorf("invalid display %q: missing display number", display)
+	}
+	host, rest := display[:colon], display[colon+1:]
+	numberStr, screenStr, hasScreen := strings.Cut(rest, ".")
+	number, err := strconv.Atoi(numberStr)
+	if err != nil || number < 0 {
+		return x11Display{}, xerrors.Errorf("invalid display %q: invalid display number", display)
+	}
+	var screen uint64
+	if hasScreen {
+		screen, err = strconv.ParseUint(screenStr, 10, 32)
+		if err != nil {
+			return x11Display{}, xerrors.Errorf("invalid display %q: invalid screen number", display)
+		}
+	}
+
+	d := x11Display{screen: uint32(screen)}
+	switch {
+	case strings.HasPrefix(host, "/"):
+		d.network, d.address = "unix", host
+	case host == "" || host == "unix":
+		d.network, d.address = "unix", filepath.Join("/tmp", ".X11-unix", "X"+numberStr)
+	default:
+		d.network, d.address = "tcp", net.JoinHostPort(strings.Trim(host, "[]"), strconv.Itoa(6000+number))
+	}
+	return d, nil
+}
+
+// sshForwardX11 requests X11 forwarding for the session and forwards the X11
+// connections opened in the workspace to the local display.
+//
+// Like OpenSSH, the workspace only gets a random cookie. It's replaced with
+// the cookie of the local display when a connection is forwarded, so the real
+// cookie never leaves this machine.
+func sshForwardX11(ctx context.Context, stderr io.Writer, sshClient *gossh.Client, sshSession *gossh.Session, display string) error {
+	d, err := parseX11Display(display)
+	if err != nil {
+		return err
+	}
+	realCookie, err := x11LocalCookie(ctx, display)
+	if err != nil {
+		// Displays without access control, e.g. XQuartz with
+		// authentication disabled, still work without a cookie.
+		cliui.Warnf(stderr, "No X11 cookie was found for %s, connections will be forwarded without authentication: %s", display, err)
+	}
+	fakeCookie := make([]byte, 16)
+	_, err = rand.Read(fakeCookie)
+	if err != nil {
+		return xerrors.Errorf("generate X11 cookie: %w", err)
+	}
+
+	channels := sshClient.HandleChannelOpen("x11")
+	if channels == nil {
+		return xerrors.New("x11 channels are already being handled")
+	}
+	ok, err := sshSession.SendRequest("x11-req", true, gossh.Marshal(struct {
+		SingleConnection bool
+		AuthProtocol     string
+		AuthCookie       string
+		ScreenNumber     uint32
+	}{
+		AuthProtocol: x11AuthProtocol,
+		AuthCookie:   hex.EncodeToString(fakeCookie),
+		ScreenNumber: d.screen,
+	}))
+	if err != nil {
+		return xerrors.Errorf("request X11 forwarding: %w", err)
+	}
+	if !ok {
+		return xerrors.New("X11 forwarding was rejected by the workspace, it may be disabled by the deployment")
+	}
+
+	go func() {
+		for newChannel := range channels {
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				_, _ = fmt.Fprintf(stderr, "Accept X11 channel: %+v\n", err)
+				continue
+			}
+			go gossh.DiscardRequests(requests)
+
+			go func() {
+				var dialer net.Dialer
+				localConn, err := dialer.DialContext(ctx, d.network, d.address)
+				if err != nil {
+					_, _ = fmt.Fprintf(stderr, "Dial local X11 display %s: %+v\n", display, err)
+					_ = channel.Close()
+					return
+				}
+				if realCookie != nil {
+					setup, err := x11ReplaceCookie(channel, fakeCookie, realCookie)
+					if err != nil {
+						_, _ = fmt.Fprintf(stderr, "Rejected X11 connection: %+v\n", err)
+						_ = channel.Close()
+						_ = localConn.Close()
+						return
+					}
+					_, err = localConn.Write(setup)
+					if err != nil {
+						_ = channel.Close()
+						_ = localConn.Close()
+						return
+					}
+				}
+				agentssh.Bicopy(ctx, localConn, channel)
+			}()
+		}
+	}()
+	return nil
+}
+
+// x11LocalCookie returns the MIT-MAGIC-COOKIE-1 cookie of the display from
+// the local Xauthority file.
+func x11LocalCookie(ctx context.Context, display string) ([]byte, error) {
+	out, err := exec.CommandContext(ctx, "xauth", "list", display).Output()
+	if err != nil {
+		return nil, xerrors.Errorf("xauth list: %w", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		// Lines look like: "host/unix:0  MIT-MAGIC-COOKIE-1  0123abcd..."
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[1] != x11AuthProtocol {
+			continue
+		}
+		cookie, err := hex.DecodeString(fields[2])
+		if err != nil {
+			return nil, xerrors.Errorf("decode cookie: %w", err)
+		}
+		return cookie, nil
+	}
+	return nil, xerrors.Errorf("no %s entry", x11AuthProtocol)
+}
+
+// x11ReplaceCookie reads the connection setup request an X11 client sends
+// first, verifies that it authenticates with fakeCookie and returns it with
+// realCookie instead.
+func x11ReplaceCookie(r io.Reader, fakeCookie, realCookie []byte) ([]byte, error) {
+	// byte-order, unused, major version, minor version,
+	// auth protocol name length, auth data length, unused.
+	header := make([]byte, 12)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, xerrors.Errorf("read setup request: %w", err)
+	}
+	var order binary.ByteOrder
+	switch header[0] {
+	case 'B':
+		order = binary.BigEndian
+	case 'l':
+		order = binary.LittleEndian
+	default:
+		return nil, xerrors.Errorf("invalid byte order %#x", header[0])
+	}
+	nameLen := int(order.Uint16(header[6:8]))
+	dataLen := int(order.Uint16(header[8:10]))
+	body := make([]byte, x11Pad(nameLen)+x11Pad(dataLen))
+	_, err = io.ReadFull(r, body)
+	if err != nil {
+		return nil, xerrors.Errorf("read setup request: %w", err)
+	}
+	name := body[:nameLen]
+	data := body[x11Pad(nameLen) : x11Pad(nameLen)+dataLen]
+	if string(name) != x11AuthProtocol || subtle.ConstantTimeCompare(data, fakeCookie) != 1 {
+		return nil, xerrors.New("invalid authentication")
+	}
+
+	setup := make([]byte, 0, len(header)+x11Pad(nameLen)+x11Pad(len(realCookie)))
+	setup = append(setup, header...)
+	order.PutUint16(setup[8:10], uint16(len(realCookie)))
+	setup = append(setup, body[:x11Pad(nameLen)]...)
+	setup = append(setup, realCookie...)
+	setup = append(setup, make([]byte, x11Pad(len(realCookie))-len(realCookie))...)
+	return setup, nil
+}
+
+// x11Pad rounds n up to the 4 byte alignment of the X11 protocol.
+func x11Pad(n int) int {
+	return (n + 3) &^ 3
+}
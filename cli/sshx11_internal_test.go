@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseX11Display(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		display string
+		want    x11Display
+		wantErr bool
+	}{
+		{display: ":0", want: x11Display{network: "unix", address: "/tmp/.X11-unix/X0"}},
+		{display: ":1.2", want: x11Display{network: "unix", address: "/tmp/.X11-unix/X1", screen: 2}},
+		{display: "unix:3", want: x11Display{network: "unix", address: "/tmp/.X11-unix/X3"}},
+		{display: "localhost:10.0", want: x11Display{network: "tcp", address: "localhost:6010"}},
+		{display: "[::1]:11", want: x11Display{network: "tcp", address: "[::1]:6011"}},
+		{
+			display: "/private/tmp/com.apple.launchd.abc/org.xquartz:0",
+			want:    x11Display{network: "unix", address: "/private/tmp/com.apple.launchd.abc/org.xquartz"},
+		},
+		{display: "", wantErr: true},
+		{display: "localhost", wantErr: true},
+		{display: ":abc", wantErr: true},
+		{display: ":0.x", wantErr: true},
+	} {
+		tt := tt
+		t.Run(tt.display, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseX11Display(tt.display)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestX11ReplaceCookie(t *testing.T) {
+	t.Parallel()
+
+	setupRequest := func(order binary.ByteOrder, name string, data []byte) []byte {
+		header := make([]byte, 12)
+		if order == binary.BigEndian {
+			header[0] = 'B'
+		} else {
+			header[0] = 'l'
+		}
+		order.PutUint16(header[2:4], 11)
+		order.PutUint16(header[6:8], uint16(len(name)))
+		order.PutUint16(header[8:10], uint16(len(data)))
+		req := append(header, name...)
+		req = append(req, make([]byte, x11Pad(len(name))-len(name))...)
+		req = append(req, data...)
+		return append(req, make([]byte, x11Pad(len(data))-len(data))...)
+	}
+	fakeCookie := []byte("fake-cookie-1234")
+	realCookie := []byte("real-cookie-for-display")
+
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		order := order
+		t.Run(order.String(), func(t *testing.T) {
+			t.Parallel()
+
+			var r bytes.Buffer
+			r.Write(setupRequest(order, x11AuthProtocol, fakeCookie))
+			// Data after the setup request is left to be forwarded.
+			r.WriteString("rest")
+			got, err := x11ReplaceCookie(&r, fakeCookie, realCookie)
+			require.NoError(t, err)
+			require.Equal(t, setupRequest(order, x11AuthProtocol, realCookie), got)
+			require.Equal(t, "rest", r.String())
+		})
+	}
+
+	t.Run("WrongCookie", func(t *testing.T) {
+		t.Parallel()
+		r := bytes.NewReader(setupRequest(binary.LittleEndian, x11AuthProtocol, []byte("guessed-cookie!!")))
+		_, err := x11ReplaceCookie(r, fakeCookie, realCookie)
+		require.ErrorContains(t, err, "invalid authentication")
+	})
+
+	t.Run("WrongProtocol", func(t *testing.T) {
+		t.Parallel()
+		r := bytes.NewReader(setupRequest(binary.LittleEndian, "XDM-AUTHORIZATION-1", fakeCookie))
+		_, err := x11ReplaceCookie(r, fakeCookie, realCookie)
+		require.ErrorContains(t, err, "invalid authentication")
+	})
+
+	t.Run("InvalidByteOrder", func(t *testing.T) {
+		t.Parallel()
+		req := setupRequest(binary.LittleEndian, x11AuthProtocol, fakeCookie)
+		req[0] = 'x'
+		_, err := x11ReplaceCookie(bytes.NewReader(req), fakeCookie, realCookie)
+		require.ErrorContains(t, err, "invalid byte order")
+	})
+}
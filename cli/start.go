@@ -25,7 +25,7 @@ func (r *RootCmd) start() *clibase.Cmd {
 		),
 		Options: append(parameterFlags.cliBuildOptions(), cliui.SkipPromptOption()),
 		Handler: func(inv *clibase.Invocation) error {
-			workspace, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			workspace, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
 			if err != nil {
 				return err
 			}
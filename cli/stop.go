@@ -2,27 +2,52 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
+	"golang.org/x/xerrors"
+
 	"github.com/coder/coder/cli/clibase"
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/codersdk"
 )
 
 func (r *RootCmd) stop() *clibase.Cmd {
+	var (
+		all      bool
+		template string
+		owner    string
+	)
 	client := new(codersdk.Client)
 	cmd := &clibase.Cmd{
 		Annotations: workspaceCommand,
-		Use:         "stop <workspace>",
+		Use:         "stop [workspace]",
 		Short:       "Stop a workspace",
+		Long: "Use --all to stop all running workspaces that match --template and --owner at once.\n\n" + formatExamples(
+			example{
+				Description: "Stop a workspace",
+				Command:     "coder stop my-workspace",
+			},
+			example{
+				Description: "Stop all of your workspaces of a template",
+				Command:     "coder stop --all --template docker",
+			},
+		),
 		Middleware: clibase.Chain(
-			clibase.RequireNArgs(1),
+			clibase.RequireRangeArgs(0, 1),
 			r.InitClient(client),
 		),
-		Options: clibase.OptionSet{
-			cliui.SkipPromptOption(),
-		},
 		Handler: func(inv *clibase.Invocation) error {
+			if all {
+				if len(inv.Args) > 0 {
+					return xerrors.New("a workspace cannot be specified with --all")
+				}
+				return stopAllWorkspaces(inv, client, template, owner)
+			}
+			if len(inv.Args) == 0 {
+				return xerrors.New("specify a workspace, or stop all workspaces with --all")
+			}
+
 			_, err := cliui.Prompt(inv, cliui.PromptOptions{
 				Text:      "Confirm stop workspace?",
 				IsConfirm: true,
@@ -31,7 +56,7 @@ func (r *RootCmd) stop() *clibase.Cmd {
 				return err
 			}
 
-			workspace, err := namedWorkspace(inv.Context(), client, inv.Args[0])
+			workspace, err := NamedWorkspace(inv.Context(), client, inv.Args[0])
 			if err != nil {
 				return err
 			}
@@ -51,5 +76,98 @@ func (r *RootCmd) stop() *clibase.Cmd {
 			return nil
 		},
 	}
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:          "all",
+			FlagShorthand: "a",
+			Description:   "Stop all workspaces that match --template and --owner instead of a single workspace.",
+			Value:         clibase.BoolOf(&all),
+		},
+		{
+			Flag:        "template",
+			Description: "Only stop workspaces of this template with --all.",
+			Value:       clibase.StringOf(&template),
+		},
+		{
+			Flag:        "owner",
+			Description: "Only stop workspaces of this user with --all. Set to an empty value to stop the workspaces of all users.",
+			Default:     codersdk.Me,
+			Value:       clibase.StringOf(&owner),
+		},
+		cliui.SkipPromptOption(),
+	}
 	return cmd
 }
+
+func stopAllWorkspaces(inv *clibase.Invocation, client *codersdk.Client, template, owner string) error {
+	// Quote the values so they're parsed as a single term.
+	var terms []string
+	if owner != "" {
+		terms = append(terms, fmt.Sprintf("owner:%q", owner))
+	}
+	if template != "" {
+		terms = append(terms, fmt.Sprintf("template:%q", template))
+	}
+	if len(terms) == 0 {
+		return xerrors.New("--template or --owner must be set with --all")
+	}
+	query := strings.Join(terms, " ")
+
+	_, err := cliui.Prompt(inv, cliui.PromptOptions{
+		Text:      fmt.Sprintf("Confirm stop of all workspaces matching %s?", cliui.DefaultStyles.Code.Render(query)),
+		IsConfirm: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	batch, err := client.BatchWorkspaces(inv.Context(), codersdk.BatchWorkspacesRequest{
+		Operation: codersdk.BatchWorkspaceOperationStop,
+		Query:     query,
+	})
+	if err != nil {
+		return xerrors.Errorf("stop workspaces: %w", err)
+	}
+	if len(batch.Results) == 0 {
+		_, _ = fmt.Fprintln(inv.Stdout, "No workspaces match.")
+		return nil
+	}
+
+	// The batch runs in the background and keeps running if we're
+	// interrupted, so only wait for it to show the results.
+	_, _ = fmt.Fprintf(inv.Stdout, "Stopping %d workspaces...\n", len(batch.Results))
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for batch.CompletedAt == nil {
+		select {
+		case <-inv.Context().Done():
+			return inv.Context().Err()
+		case <-ticker.C:
+		}
+		batch, err = client.WorkspaceBatch(inv.Context(), batch.ID)
+		if err != nil {
+			return xerrors.Errorf("get workspace batch: %w", err)
+		}
+	}
+	if batch.Status == codersdk.WorkspaceBatchStatusFailed {
+		return xerrors.Errorf("stop workspaces: %s", batch.Error)
+	}
+
+	var failed int
+	for _, result := range batch.Results {
+		name := cliui.DefaultStyles.Keyword.Render(result.WorkspaceOwnerName + "/" + result.WorkspaceName)
+		switch result.Status {
+		case codersdk.BatchWorkspaceResultStatusQueued:
+			_, _ = fmt.Fprintf(inv.Stdout, "%s is stopping\n", name)
+		case codersdk.BatchWorkspaceResultStatusSkipped:
+			_, _ = fmt.Fprintf(inv.Stdout, "%s is already stopped\n", name)
+		default:
+			failed++
+			_, _ = fmt.Fprintf(inv.Stdout, "%s failed to stop: %s\n", name, result.Error)
+		}
+	}
+	if failed > 0 {
+		return xerrors.Errorf("failed to stop %d of %d workspaces", failed, len(batch.Results))
+	}
+	return nil
+}
@@ -0,0 +1,368 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/coderd/healthcheck"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/tailnet"
+)
+
+func (r *RootCmd) support() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Use:   "support",
+		Short: "Commands for troubleshooting issues with a workspace",
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.supportBundle(),
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) supportBundle() *clibase.Cmd {
+	var (
+		outputPath string
+		timeout    time.Duration
+	)
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Annotations: workspaceCommand,
+		Use:         "bundle <workspace>",
+		Short:       "Collect troubleshooting information about a workspace into a tarball",
+		Long: "The bundle contains the build logs of the workspace, the logs of " +
+			"its agent, a network report, the DERP map and the coordinator state of " +
+			"the agent. Secrets such as tokens and passwords are redacted, but " +
+			"review the bundle before sharing it.\n" + formatExamples(
+			example{
+				Description: "Collect a bundle for the agent \"main\" of the workspace \"dev\"",
+				Command:     "coder support bundle dev.main -O bundle.tar.gz",
+			},
+		),
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx, cancel := context.WithTimeout(inv.Context(), timeout)
+			defer cancel()
+
+			workspace, err := NamedWorkspace(ctx, client, strings.Split(inv.Args[0], ".")[0])
+			if err != nil {
+				return err
+			}
+			if outputPath == "" {
+				outputPath = fmt.Sprintf("coder-support-%s-%s.tar.gz", workspace.Name, time.Now().UTC().Format("20060102T150405Z"))
+			}
+
+			f, err := os.OpenFile(outputPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+			if err != nil {
+				return xerrors.Errorf("create bundle: %w", err)
+			}
+			defer f.Close()
+
+			_, _ = fmt.Fprintln(inv.Stderr, "Collecting support bundle. This may take a few seconds...")
+			var logs strings.Builder
+			bundle := &supportBundle{
+				redact: newSupportRedactor(client.SessionToken()),
+				logger: slog.Make(sloghuman.Sink(&logs)).Leveled(slog.LevelDebug),
+			}
+			bundle.collect(ctx, inv, client, workspace)
+			bundle.addFile("client.log", []byte(logs.String()))
+
+			err = bundle.write(f)
+			if err != nil {
+				return xerrors.Errorf("write bundle: %w", err)
+			}
+			err = f.Close()
+			if err != nil {
+				return xerrors.Errorf("close bundle: %w", err)
+			}
+
+			for _, collectErr := range bundle.errors {
+				cliui.Warnf(inv.Stderr, "%s", collectErr)
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Wrote support bundle to %s\n", cliui.DefaultStyles.Code.Render(outputPath))
+			return nil
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:          "output-file",
+			FlagShorthand: "O",
+			Description:   "File to write the bundle to. Defaults to a file named after the workspace in the current directory.",
+			Value:         clibase.StringOf(&outputPath),
+		},
+		{
+			Flag:        "timeout",
+			Default:     "2m",
+			Description: "How long to wait for the bundle to be collected.",
+			Value:       clibase.DurationOf(&timeout),
+		},
+	}
+	return cmd
+}
+
+type supportBundleFile struct {
+	name    string
+	content []byte
+}
+
+// supportBundle collects files for a support bundle. Failing to collect a
+// file doesn't fail the bundle, so as much as possible is collected for
+// broken workspaces.
+type supportBundle struct {
+	redact func(string) string
+	logger slog.Logger
+	files  []supportBundleFile
+	errors []string
+}
+
+func (b *supportBundle) addFile(name string, content []byte) {
+	b.files = append(b.files, supportBundleFile{
+		name:    name,
+		content: []byte(b.redact(string(content))),
+	})
+}
+
+func (b *supportBundle) addJSON(name string, v any) {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		b.addError(name, err)
+		return
+	}
+	b.addFile(name, raw)
+}
+
+func (b *supportBundle) addError(name string, err error) {
+	b.errors = append(b.errors, b.redact(fmt.Sprintf("collect %s: %s", name, err)))
+}
+
+func (b *supportBundle) collect(ctx context.Context, inv *clibase.Invocation, client *codersdk.Client, workspace codersdk.Workspace) {
+	buildInfo, err := client.BuildInfo(ctx)
+	if err != nil {
+		b.addError("buildinfo.json", err)
+	} else {
+		b.addJSON("buildinfo.json", buildInfo)
+	}
+	b.addJSON("workspace.json", workspace)
+
+	var buildLogs strings.Builder
+	logs, closer, err := client.WorkspaceBuildLogsAfter(ctx, workspace.LatestBuild.ID, 0)
+	if err != nil {
+		b.addError("build.log", err)
+	} else {
+		for log := range logs {
+			_, _ = fmt.Fprintf(&buildLogs, "%s [%s] %s: %s\n", log.CreatedAt.Format(time.RFC3339), log.Level, log.Stage, log.Output)
+		}
+		_ = closer.Close()
+		b.addFile("build.log", []byte(buildLogs.String()))
+	}
+
+	connInfo, err := client.WorkspaceAgentConnectionInfoGeneric(ctx)
+	if err != nil {
+		b.addError("network/derp_map.json", err)
+	} else {
+		b.addJSON("network/derp_map.json", connInfo.DERPMap)
+
+		var report healthcheck.DERPReport
+		report.Run(ctx, &healthcheck.DERPReportOptions{
+			DERPMap: connInfo.DERPMap,
+		})
+		b.addJSON("network/netcheck.json", report)
+	}
+
+	agent, ok := findWorkspaceAgent(workspace.LatestBuild.Resources, inv.Args[0])
+	if !ok {
+		b.addError("agent", xerrors.Errorf("agent not found in the latest build of workspace %q", workspace.Name))
+		return
+	}
+	b.addJSON("agent/agent.json", agent)
+
+	var agentLogs strings.Builder
+	startupLogs, closer, err := client.WorkspaceAgentLogsAfter(ctx, agent.ID, 0, false)
+	if err != nil {
+		b.addError("agent/startup.log", err)
+	} else {
+		for batch := range startupLogs {
+			for _, log := range batch {
+				_, _ = fmt.Fprintf(&agentLogs, "%s [%s] %s\n", log.CreatedAt.Format(time.RFC3339), log.Level, log.Output)
+			}
+		}
+		_ = closer.Close()
+		b.addFile("agent/startup.log", []byte(agentLogs.String()))
+	}
+
+	coordinator, err := client.DebugCoordinator(ctx)
+	if err != nil {
+		b.addError("network/coordinator.json", err)
+	} else {
+		b.addJSON("network/coordinator.json", filterCoordinatorDebug(coordinator, agent.ID))
+	}
+
+	if agent.Status != codersdk.WorkspaceAgentConnected {
+		b.addError("agent logs", xerrors.Errorf("agent is %s", agent.Status))
+		return
+	}
+	conn, err := client.DialWorkspaceAgent(ctx, agent.ID, &codersdk.DialWorkspaceAgentOptions{
+		Logger: b.logger,
+	})
+	if err != nil {
+		b.addError("agent logs", xerrors.Errorf("dial agent: %w", err))
+		return
+	}
+	defer conn.Close()
+
+	if !conn.AwaitReachable(ctx) {
+		b.addError("agent logs", xerrors.Errorf("agent not reachable: %w", ctx.Err()))
+		return
+	}
+	latency, p2p, pong, err := conn.Ping(ctx)
+	if err != nil {
+		b.addError("network/connection.json", xerrors.Errorf("ping agent: %w", err))
+	} else {
+		b.addJSON("network/connection.json", map[string]any{
+			"latency": latency.String(),
+			"p2p":     p2p,
+			"pong":    pong,
+			"stats":   conn.Stats(),
+		})
+	}
+
+	debugLogs, err := conn.DebugLogs(ctx)
+	if err != nil {
+		b.addError("agent logs", err)
+		return
+	}
+	for _, file := range debugLogs.Files {
+		b.addFile(path.Join("agent", path.Base(file.Name)), []byte(file.Content))
+	}
+}
+
+// write writes the collected files as a gzipped tarball.
+func (b *supportBundle) write(w io.Writer) error {
+	if len(b.errors) > 0 {
+		b.files = append(b.files, supportBundleFile{
+			name:    "errors.txt",
+			content: []byte(strings.Join(b.errors, "\n") + "\n"),
+		})
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	now := time.Now()
+	for _, file := range b.files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:    file.name,
+			Mode:    0o600,
+			Size:    int64(len(file.content)),
+			ModTime: now,
+		})
+		if err != nil {
+			return xerrors.Errorf("write header of %q: %w", file.name, err)
+		}
+		_, err = tw.Write(file.content)
+		if err != nil {
+			return xerrors.Errorf("write %q: %w", file.name, err)
+		}
+	}
+	err := tw.Close()
+	if err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// findWorkspaceAgent returns the agent of the resources that matches the
+// "workspace.agent" name. Without an agent name, the first agent is returned.
+func findWorkspaceAgent(resources []codersdk.WorkspaceResource, in string) (codersdk.WorkspaceAgent, bool) {
+	_, agentName, _ := strings.Cut(in, ".")
+	for _, resource := range resources {
+		for _, agent := range resource.Agents {
+			if agentName == "" || agent.Name == agentName {
+				return agent, true
+			}
+		}
+	}
+	return codersdk.WorkspaceAgent{}, false
+}
+
+// filterCoordinatorDebug removes the agents, clients and nodes of other
+// workspaces from the coordinator state.
+func filterCoordinatorDebug(debug tailnet.HTMLDebug, agentID uuid.UUID) tailnet.HTMLDebug {
+	filtered := tailnet.HTMLDebug{
+		HA:            debug.HA,
+		Agents:        []*tailnet.HTMLAgent{},
+		MissingAgents: []*tailnet.HTMLAgent{},
+		Nodes:         []*tailnet.HTMLNode{},
+	}
+	ids := map[uuid.UUID]struct{}{agentID: {}}
+	for _, agent := range debug.Agents {
+		if agent.ID != agentID {
+			continue
+		}
+		filtered.Agents = append(filtered.Agents, agent)
+		for _, conn := range agent.Connections {
+			ids[conn.ID] = struct{}{}
+		}
+	}
+	for _, agent := range debug.MissingAgents {
+		if agent.ID != agentID {
+			continue
+		}
+		filtered.MissingAgents = append(filtered.MissingAgents, agent)
+		for _, conn := range agent.Connections {
+			ids[conn.ID] = struct{}{}
+		}
+	}
+	for _, node := range debug.Nodes {
+		if _, ok := ids[node.ID]; ok {
+			filtered.Nodes = append(filtered.Nodes, node)
+		}
+	}
+	return filtered
+}
+
+var (
+	// supportSecretAssignment matches values assigned to keys that look like
+	// they hold secrets, e.g. "token=abc" or `"password": "abc"`.
+	supportSecretAssignment = regexp.MustCompile(`(?i)((?:[a-z0-9_-]*token|secret|password|passwd|api[_-]?key|access[_-]?key)["']?\s*[:=]\s*["']?)[^\s"',&]+`)
+	// supportSessionToken matches Coder API keys, which are an ID and a
+	// secret joined by a dash.
+	supportSessionToken = regexp.MustCompile(`\b[a-zA-Z0-9]{10}-[a-zA-Z0-9]{22}\b`)
+)
+
+// newSupportRedactor returns a function that redacts the given secrets and
+// anything that looks like a secret from a bundle file.
+func newSupportRedactor(secrets ...string) func(string) string {
+	return func(s string) string {
+		for _, secret := range secrets {
+			if secret != "" {
+				s = strings.ReplaceAll(s, secret, "[REDACTED]")
+			}
+		}
+		s = supportSessionToken.ReplaceAllString(s, "[REDACTED]")
+		return supportSecretAssignment.ReplaceAllString(s, "${1}[REDACTED]")
+	}
+}
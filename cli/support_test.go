@@ -0,0 +1,95 @@
+package cli_test
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestSupportBundle(t *testing.T) {
+	t.Parallel()
+
+	client, workspace, agentToken := setupWorkspaceForAgent(t, nil)
+
+	logDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(logDir, "coder-agent.log"), []byte("agent started\napi_token=supersecret\n"), 0o600)
+	require.NoError(t, err)
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(agentToken)
+	agentCloser := agent.New(agent.Options{
+		Client: agentClient,
+		Logger: slogtest.Make(t, nil).Named("agent"),
+		LogDir: logDir,
+	})
+	defer func() {
+		_ = agentCloser.Close()
+	}()
+	coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+
+	outputPath := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	inv, root := clitest.New(t, "support", "bundle", workspace.Name, "--output-file", outputPath)
+	clitest.SetupConfig(t, client, root)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+
+	f, err := os.Open(outputPath)
+	require.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	tr := tar.NewReader(gr)
+	files := map[string]string{}
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		files[header.Name] = string(content)
+	}
+
+	for _, name := range []string{
+		"buildinfo.json",
+		"workspace.json",
+		"build.log",
+		"agent/agent.json",
+		"agent/startup.log",
+		"agent/coder-agent.log",
+		"network/derp_map.json",
+		"network/netcheck.json",
+		"network/connection.json",
+		"network/coordinator.json",
+	} {
+		require.Contains(t, files, name)
+	}
+	require.Contains(t, files["workspace.json"], workspace.ID.String())
+	require.Contains(t, files["agent/coder-agent.log"], "agent started")
+	require.Contains(t, files["agent/coder-agent.log"], "api_token=[REDACTED]")
+	require.NotContains(t, files["agent/coder-agent.log"], "supersecret")
+	for name, content := range files {
+		require.NotContains(t, content, client.SessionToken(), name)
+	}
+
+	// Bundles are never overwritten.
+	inv, root = clitest.New(t, "support", "bundle", workspace.Name, "--output-file", outputPath)
+	clitest.SetupConfig(t, client, root)
+	err = inv.WithContext(ctx).Run()
+	require.ErrorIs(t, err, os.ErrExist)
+}
@@ -28,8 +28,10 @@ func (r *RootCmd) templateCreate() *clibase.Cmd {
 		provisionerTags []string
 		variablesFile   string
 		variables       []string
+		presetsFile     string
 		disableEveryone bool
 		defaultTTL      time.Duration
+		activityBump    time.Duration
 		failureTTL      time.Duration
 		inactivityTTL   time.Duration
 
@@ -114,6 +116,7 @@ func (r *RootCmd) templateCreate() *clibase.Cmd {
 				ProvisionerTags: tags,
 				VariablesFile:   variablesFile,
 				Variables:       variables,
+				PresetsFile:     presetsFile,
 			})
 			if err != nil {
 				return err
@@ -133,6 +136,7 @@ func (r *RootCmd) templateCreate() *clibase.Cmd {
 				Name:                       templateName,
 				VersionID:                  job.ID,
 				DefaultTTLMillis:           ptr.Ref(defaultTTL.Milliseconds()),
+				ActivityBumpMillis:         ptr.Ref(activityBump.Milliseconds()),
 				FailureTTLMillis:           ptr.Ref(failureTTL.Milliseconds()),
 				InactivityTTLMillis:        ptr.Ref(inactivityTTL.Milliseconds()),
 				DisableEveryoneGroupAccess: disableEveryone,
@@ -175,6 +179,11 @@ func (r *RootCmd) templateCreate() *clibase.Cmd {
 			Description: "Alias of --variable.",
 			Value:       clibase.StringArrayOf(&variables),
 		},
+		{
+			Flag:        "presets-file",
+			Description: "Specify a file path with the parameter presets of the template version.",
+			Value:       clibase.StringOf(&presetsFile),
+		},
 		{
 			Flag:        "provisioner-tag",
 			Description: "Specify a set of tags to target provisioner daemons.",
@@ -186,6 +195,12 @@ func (r *RootCmd) templateCreate() *clibase.Cmd {
 			Default:     "24h",
 			Value:       clibase.DurationOf(&defaultTTL),
 		},
+		{
+			Flag:        "activity-bump",
+			Description: "Specify how long workspaces created from this template stay running after activity was last detected. Idle workspaces are stopped early. By default the deadline is bumped by the workspace TTL.",
+			Default:     "0h",
+			Value:       clibase.DurationOf(&activityBump),
+		},
 		{
 			Flag:        "failure-ttl",
 			Description: "Specify a failure TTL for workspaces created from this template. This licensed feature's default is 0h (off).",
@@ -199,11 +214,10 @@ func (r *RootCmd) templateCreate() *clibase.Cmd {
 			Value:       clibase.DurationOf(&inactivityTTL),
 		},
 		{
-			Flag:        "test.provisioner",
-			Description: "Customize the provisioner backend.",
+			Flag:        "provisioner",
+			Description: "The provisioner that runs the template: terraform, or the name of a provisioner plugin served by external provisioner daemons.",
 			Default:     "terraform",
 			Value:       clibase.StringOf(&provisioner),
-			Hidden:      true,
 		},
 		cliui.SkipPromptOption(),
 	}
@@ -221,6 +235,7 @@ type createValidTemplateVersionArgs struct {
 
 	VariablesFile string
 	Variables     []string
+	PresetsFile   string
 
 	// Template is only required if updating a template's active version.
 	Template *codersdk.Template
@@ -245,6 +260,11 @@ func createValidTemplateVersion(inv *clibase.Invocation, args createValidTemplat
 	}
 	variableValues = append(variableValues, variableValuesFromKeyValues...)
 
+	presets, err := loadPresetsFromFile(args.PresetsFile)
+	if err != nil {
+		return nil, err
+	}
+
 	req := codersdk.CreateTemplateVersionRequest{
 		Name:               args.Name,
 		Message:            args.Message,
@@ -253,6 +273,7 @@ func createValidTemplateVersion(inv *clibase.Invocation, args createValidTemplat
 		Provisioner:        codersdk.ProvisionerType(args.Provisioner),
 		ProvisionerTags:    args.ProvisionerTags,
 		UserVariableValues: variableValues,
+		Presets:            presets,
 	}
 	if args.Template != nil {
 		req.TemplateID = args.Template.ID
@@ -56,7 +56,7 @@ func TestTemplateCreate(t *testing.T) {
 			"create",
 			"my-template",
 			"--directory", source,
-			"--test.provisioner", string(database.ProvisionerTypeEcho),
+			"--provisioner", string(database.ProvisionerTypeEcho),
 			"--default-ttl", "24h",
 		}
 		inv, root := clitest.New(t, args...)
@@ -95,7 +95,7 @@ func TestTemplateCreate(t *testing.T) {
 			"create",
 			"my-template",
 			"--directory", source,
-			"--test.provisioner", string(database.ProvisionerTypeEcho),
+			"--provisioner", string(database.ProvisionerTypeEcho),
 			"--default-ttl", "24h",
 		}
 		inv, root := clitest.New(t, args...)
@@ -138,7 +138,7 @@ func TestTemplateCreate(t *testing.T) {
 			"create",
 			"my-template",
 			"--directory", source,
-			"--test.provisioner", string(database.ProvisionerTypeEcho),
+			"--provisioner", string(database.ProvisionerTypeEcho),
 			"--default-ttl", "24h",
 			"--ignore-lockfile",
 		}
@@ -178,7 +178,7 @@ func TestTemplateCreate(t *testing.T) {
 			"create",
 			"my-template",
 			"--directory", "-",
-			"--test.provisioner", string(database.ProvisionerTypeEcho),
+			"--provisioner", string(database.ProvisionerTypeEcho),
 			"--default-ttl", "24h",
 		}
 		inv, root := clitest.New(t, args...)
@@ -206,7 +206,7 @@ func TestTemplateCreate(t *testing.T) {
 				"my-template",
 				"--yes",
 				"--directory", source,
-				"--test.provisioner", string(database.ProvisionerTypeEcho),
+				"--provisioner", string(database.ProvisionerTypeEcho),
 			}
 			inv, root := clitest.New(t, args...)
 			clitest.SetupConfig(t, client, root)
@@ -267,7 +267,7 @@ func TestTemplateCreate(t *testing.T) {
 		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
 		defer cancel()
 
-		inv, root := clitest.New(t, "templates", "create", "my-template", "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--variables-file", variablesFile.Name())
+		inv, root := clitest.New(t, "templates", "create", "my-template", "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--variables-file", variablesFile.Name())
 		clitest.SetupConfig(t, client, root)
 		inv = inv.WithContext(ctx)
 		pty := ptytest.New(t).Attach(inv)
@@ -325,7 +325,7 @@ func TestTemplateCreate(t *testing.T) {
 		removeTmpDirUntilSuccessAfterTest(t, tempDir)
 		variablesFile, _ := os.CreateTemp(tempDir, "variables*.yaml")
 		_, _ = variablesFile.WriteString(`first_variable: foobar`)
-		inv, root := clitest.New(t, "templates", "create", "my-template", "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--variables-file", variablesFile.Name())
+		inv, root := clitest.New(t, "templates", "create", "my-template", "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--variables-file", variablesFile.Name())
 		clitest.SetupConfig(t, client, root)
 		pty := ptytest.New(t).Attach(inv)
 
@@ -362,7 +362,7 @@ func TestTemplateCreate(t *testing.T) {
 		}
 		source := clitest.CreateTemplateVersionSource(t,
 			createEchoResponsesWithTemplateVariables(templateVariables))
-		inv, root := clitest.New(t, "templates", "create", "my-template", "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--variable", "first_variable=foobar")
+		inv, root := clitest.New(t, "templates", "create", "my-template", "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--variable", "first_variable=foobar")
 		clitest.SetupConfig(t, client, root)
 		pty := ptytest.New(t).Attach(inv)
 
@@ -10,7 +10,9 @@ import (
 
 	"github.com/coder/coder/cli/clibase"
 	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisionersdk"
 )
 
 func (r *RootCmd) templateEdit() *clibase.Cmd {
@@ -20,14 +22,27 @@ func (r *RootCmd) templateEdit() *clibase.Cmd {
 		description                  string
 		icon                         string
 		defaultTTL                   time.Duration
+		activityBump                 time.Duration
 		maxTTL                       time.Duration
 		restartRequirementDaysOfWeek []string
 		restartRequirementWeeks      int64
+		restartRequirementTimezone   string
+		restartRequirementSpread     time.Duration
+		updateOnRestart              bool
 		failureTTL                   time.Duration
 		inactivityTTL                time.Duration
 		allowUserCancelWorkspaceJobs bool
 		allowUserAutostart           bool
 		allowUserAutostop            bool
+		maxConcurrentBuilds          int64
+		maxDeadlineExtensionsPerDay  int64
+		maxDeadlineExtension         time.Duration
+		requiredPromotionApprovals   int64
+		userCPUQuota                 string
+		userMemoryQuota              string
+		userDiskQuota                string
+		applyDotfiles                bool
+		disableAutostop              bool
 	)
 	client := new(codersdk.Client)
 
@@ -54,6 +69,9 @@ func (r *RootCmd) templateEdit() *clibase.Cmd {
 			unsetRestartRequirementDaysOfWeek := len(restartRequirementDaysOfWeek) == 1 && restartRequirementDaysOfWeek[0] == "none"
 			requiresEntitlement := (len(restartRequirementDaysOfWeek) > 0 && !unsetRestartRequirementDaysOfWeek) ||
 				restartRequirementWeeks > 0 ||
+				restartRequirementTimezone != "" ||
+				restartRequirementSpread != 0 ||
+				updateOnRestart ||
 				!allowUserAutostart ||
 				!allowUserAutostop ||
 				maxTTL != 0 ||
@@ -90,6 +108,23 @@ func (r *RootCmd) templateEdit() *clibase.Cmd {
 			if unsetRestartRequirementDaysOfWeek {
 				restartRequirementDaysOfWeek = []string{}
 			}
+			// Keep the existing timezone unless a new one was specified, or
+			// clear it if the user specified "none".
+			if restartRequirementTimezone == "" {
+				restartRequirementTimezone = template.RestartRequirement.Timezone
+			}
+			if restartRequirementTimezone == "none" {
+				restartRequirementTimezone = ""
+			}
+			// Keep the existing spread unless a new one was specified.
+			if !inv.ParsedFlags().Changed("restart-requirement-spread") {
+				restartRequirementSpread = time.Duration(template.RestartRequirement.SpreadMillis) * time.Millisecond
+			}
+			// Keep the existing update on restart policy unless a new one was
+			// specified.
+			if !inv.ParsedFlags().Changed("restart-requirement-update-on-restart") {
+				updateOnRestart = template.RestartRequirement.UpdateOnRestart
+			}
 
 			// NOTE: coderd will ignore empty fields.
 			req := codersdk.UpdateTemplateMeta{
@@ -100,8 +135,11 @@ func (r *RootCmd) templateEdit() *clibase.Cmd {
 				DefaultTTLMillis: defaultTTL.Milliseconds(),
 				MaxTTLMillis:     maxTTL.Milliseconds(),
 				RestartRequirement: &codersdk.TemplateRestartRequirement{
-					DaysOfWeek: restartRequirementDaysOfWeek,
-					Weeks:      restartRequirementWeeks,
+					DaysOfWeek:      restartRequirementDaysOfWeek,
+					Weeks:           restartRequirementWeeks,
+					Timezone:        restartRequirementTimezone,
+					SpreadMillis:    restartRequirementSpread.Milliseconds(),
+					UpdateOnRestart: updateOnRestart,
 				},
 				FailureTTLMillis:             failureTTL.Milliseconds(),
 				InactivityTTLMillis:          inactivityTTL.Milliseconds(),
@@ -109,6 +147,50 @@ func (r *RootCmd) templateEdit() *clibase.Cmd {
 				AllowUserAutostart:           allowUserAutostart,
 				AllowUserAutostop:            allowUserAutostop,
 			}
+			// Keep the existing activity bump unless a new one was specified.
+			if inv.ParsedFlags().Changed("activity-bump") {
+				req.ActivityBumpMillis = ptr.Ref(activityBump.Milliseconds())
+			}
+			// Keep the existing limit unless a new one was specified.
+			if inv.ParsedFlags().Changed("max-concurrent-builds") {
+				req.MaxConcurrentBuilds = ptr.Ref(int32(maxConcurrentBuilds))
+			}
+			if inv.ParsedFlags().Changed("max-deadline-extensions-per-day") {
+				req.MaxDeadlineExtensionsPerDay = ptr.Ref(int32(maxDeadlineExtensionsPerDay))
+			}
+			if inv.ParsedFlags().Changed("max-deadline-extension") {
+				req.MaxDeadlineExtensionMillis = ptr.Ref(maxDeadlineExtension.Milliseconds())
+			}
+			if inv.ParsedFlags().Changed("required-promotion-approvals") {
+				req.RequiredPromotionApprovals = ptr.Ref(int32(requiredPromotionApprovals))
+			}
+			if inv.ParsedFlags().Changed("user-cpu-quota") {
+				millicores, err := provisionersdk.ParseCPUMillicores(userCPUQuota)
+				if err != nil {
+					return xerrors.Errorf("parse --user-cpu-quota: %w", err)
+				}
+				req.UserCPUQuotaMillicores = &millicores
+			}
+			if inv.ParsedFlags().Changed("user-memory-quota") {
+				memory, err := provisionersdk.ParseBytes(userMemoryQuota)
+				if err != nil {
+					return xerrors.Errorf("parse --user-memory-quota: %w", err)
+				}
+				req.UserMemoryQuotaBytes = &memory
+			}
+			if inv.ParsedFlags().Changed("user-disk-quota") {
+				disk, err := provisionersdk.ParseBytes(userDiskQuota)
+				if err != nil {
+					return xerrors.Errorf("parse --user-disk-quota: %w", err)
+				}
+				req.UserDiskQuotaBytes = &disk
+			}
+			if inv.ParsedFlags().Changed("apply-dotfiles") {
+				req.ApplyDotfiles = ptr.Ref(applyDotfiles)
+			}
+			if inv.ParsedFlags().Changed("disable-autostop") {
+				req.DisableAutostop = ptr.Ref(disableAutostop)
+			}
 
 			_, err = client.UpdateTemplateMeta(inv.Context(), template.ID, req)
 			if err != nil {
@@ -145,6 +227,11 @@ func (r *RootCmd) templateEdit() *clibase.Cmd {
 			Description: "Edit the template default time before shutdown - workspaces created from this template default to this value.",
 			Value:       clibase.DurationOf(&defaultTTL),
 		},
+		{
+			Flag:        "activity-bump",
+			Description: "Edit the template activity bump - workspaces created from this template stay running for this long after activity was last detected, and are stopped early when idle. To bump the deadline by the workspace TTL instead, pass 0.",
+			Value:       clibase.DurationOf(&activityBump),
+		},
 		{
 			Flag:        "max-ttl",
 			Description: "Edit the template maximum time before shutdown - workspaces created from this template must shutdown within the given duration after starting. This is an enterprise-only feature.",
@@ -174,6 +261,35 @@ func (r *RootCmd) templateEdit() *clibase.Cmd {
 			Hidden: true,
 			Value:  clibase.Int64Of(&restartRequirementWeeks),
 		},
+		{
+			Flag:        "restart-requirement-timezone",
+			Description: "Edit the template restart requirement timezone - the IANA timezone in which users' quiet hours are evaluated for restarts. By default the timezone of each user's quiet hours schedule is used. To unset this value for the template, pass 'none'.",
+			// TODO(@dean): unhide when we delete max_ttl
+			Hidden: true,
+			Value: clibase.Validate(clibase.StringOf(&restartRequirementTimezone), func(value *clibase.String) error {
+				v := value.String()
+				if v == "" || v == "none" {
+					return nil
+				}
+				_, err := time.LoadLocation(v)
+				if err != nil {
+					return xerrors.Errorf("invalid restart requirement timezone %q: %w", v, err)
+				}
+				return nil
+			}),
+		},
+		{
+			Flag:        "restart-requirement-spread",
+			Description: "Edit the template restart requirement spread - required restarts are spread across this window after the start of users' quiet hours, so workspaces don't all restart at the same time. Pass 0 to disable.",
+			Hidden:      true,
+			Value:       clibase.DurationOf(&restartRequirementSpread),
+		},
+		{
+			Flag:        "restart-requirement-update-on-restart",
+			Description: "Edit whether workspaces stopped by the template restart requirement are started again on the active template version.",
+			Hidden:      true,
+			Value:       clibase.BoolOf(&updateOnRestart),
+		},
 		{
 			Flag:        "failure-ttl",
 			Description: "Specify a failure TTL for workspaces created from this template. This licensed feature's default is 0h (off).",
@@ -204,6 +320,51 @@ func (r *RootCmd) templateEdit() *clibase.Cmd {
 			Default:     "true",
 			Value:       clibase.BoolOf(&allowUserAutostop),
 		},
+		{
+			Flag:        "max-concurrent-builds",
+			Description: "Edit the maximum number of workspace builds of this template that provisioners run at the same time, other builds wait in the queue. To remove the limit, pass 0.",
+			Value:       clibase.Int64Of(&maxConcurrentBuilds),
+		},
+		{
+			Flag:        "max-deadline-extensions-per-day",
+			Description: "Edit the maximum number of times that users can extend the deadline of a workspace within 24 hours using \"coder extend\". To remove the limit, pass 0.",
+			Value:       clibase.Int64Of(&maxDeadlineExtensionsPerDay),
+		},
+		{
+			Flag:        "max-deadline-extension",
+			Description: "Edit the maximum duration that users can extend the deadline of a workspace by at once using \"coder extend\". To remove the limit, pass 0.",
+			Value:       clibase.DurationOf(&maxDeadlineExtension),
+		},
+		{
+			Flag:        "required-promotion-approvals",
+			Description: "Edit the number of approvals a template version promotion needs before the version becomes active. To allow versions to be made active directly, pass 0.",
+			Value:       clibase.Int64Of(&requiredPromotionApprovals),
+		},
+		{
+			Flag:        "user-cpu-quota",
+			Description: "Edit the CPU that the workspaces of this template owned by a single user can use in total, e.g. \"8\" or \"500m\". Templates declare the CPU of resources with \"cpu\" metadata items. To remove the quota, pass 0.",
+			Value:       clibase.StringOf(&userCPUQuota),
+		},
+		{
+			Flag:        "user-memory-quota",
+			Description: "Edit the memory that the workspaces of this template owned by a single user can use in total, e.g. \"32GiB\". Templates declare the memory of resources with \"memory\" metadata items. To remove the quota, pass 0.",
+			Value:       clibase.StringOf(&userMemoryQuota),
+		},
+		{
+			Flag:        "user-disk-quota",
+			Description: "Edit the disk space that the workspaces of this template owned by a single user can use in total, e.g. \"500GiB\". Templates declare the disk space of resources with \"disk\" metadata items. To remove the quota, pass 0.",
+			Value:       clibase.StringOf(&userDiskQuota),
+		},
+		{
+			Flag:        "apply-dotfiles",
+			Description: "Edit whether the agents of workspaces of this template apply the dotfiles repositories of their owners when they start.",
+			Value:       clibase.BoolOf(&applyDotfiles),
+		},
+		{
+			Flag:        "disable-autostop",
+			Description: "Edit whether workspaces of this template never stop automatically when the template has no default TTL, even if the organization has a default autostop.",
+			Value:       clibase.BoolOf(&disableAutostop),
+		},
 		cliui.SkipPromptOption(),
 	}
 
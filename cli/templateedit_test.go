@@ -141,6 +141,36 @@ func TestTemplateEdit(t *testing.T) {
 		assert.Equal(t, template.Name, updated.Name)
 		assert.Equal(t, "", template.DisplayName)
 	})
+	t.Run("MaxConcurrentBuilds", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		inv, root := clitest.New(t, "templates", "edit", template.Name, "--max-concurrent-builds", "2")
+		clitest.SetupConfig(t, client, root)
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+
+		updated, err := client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, updated.MaxConcurrentBuilds)
+
+		// The limit is kept when other properties are edited.
+		inv, root = clitest.New(t, "templates", "edit", template.Name, "--description", "New description")
+		clitest.SetupConfig(t, client, root)
+		err = inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+
+		updated, err = client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "New description", updated.Description)
+		assert.EqualValues(t, 2, updated.MaxConcurrentBuilds)
+	})
+
 	t.Run("WithPropertiesThenModified", func(t *testing.T) {
 		t.Parallel()
 		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"sort"
 
@@ -14,13 +15,18 @@ import (
 
 	"github.com/coder/coder/cli/clibase"
 	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/coderd/templateregistry"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/examples"
 	"github.com/coder/coder/provisionersdk"
 )
 
 func (*RootCmd) templateInit() *clibase.Cmd {
-	var templateID string
+	var (
+		templateID    string
+		from          string
+		registryFlags templateRegistryFlags
+	)
 	exampleList, err := examples.List()
 	if err != nil {
 		// This should not happen. If it does, something is very wrong.
@@ -36,8 +42,11 @@ func (*RootCmd) templateInit() *clibase.Cmd {
 		Short:      "Get started with a templated template.",
 		Middleware: clibase.RequireRangeArgs(0, 1),
 		Handler: func(inv *clibase.Invocation) error {
+			if templateID != "" && from != "" {
+				return xerrors.New("only one of --id and --from can be specified")
+			}
 			// If the user didn't specify any template, prompt them to select one.
-			if templateID == "" {
+			if templateID == "" && from == "" {
 				optsToID := map[string]string{}
 				for _, example := range exampleList {
 					name := fmt.Sprintf(
@@ -69,14 +78,36 @@ func (*RootCmd) templateInit() *clibase.Cmd {
 				templateID = optsToID[selected]
 			}
 
-			selectedTemplate, ok := templateByID(templateID, exampleList)
-			if !ok {
-				// clibase.EnumOf would normally handle this.
-				return xerrors.Errorf("template not found: %q", templateID)
-			}
-			archive, err := examples.Archive(selectedTemplate.ID)
-			if err != nil {
-				return err
+			var (
+				// name is the name of the template, and the default
+				// directory to extract it to.
+				name    string
+				source  string
+				archive []byte
+			)
+			if from != "" {
+				ref, err := templateregistry.ParseReference(from)
+				if err != nil {
+					return err
+				}
+				archive, err = registryFlags.client(ref).Pull(inv.Context(), ref)
+				if err != nil {
+					return xerrors.Errorf("pull %s: %w", ref, err)
+				}
+				name = path.Base(ref.Repository)
+				source = ref.String()
+			} else {
+				selectedTemplate, ok := templateByID(templateID, exampleList)
+				if !ok {
+					// clibase.EnumOf would normally handle this.
+					return xerrors.Errorf("template not found: %q", templateID)
+				}
+				archive, err = examples.Archive(selectedTemplate.ID)
+				if err != nil {
+					return err
+				}
+				name = selectedTemplate.ID
+				source = selectedTemplate.ID
 			}
 			workingDir, err := os.Getwd()
 			if err != nil {
@@ -86,7 +117,7 @@ func (*RootCmd) templateInit() *clibase.Cmd {
 			if len(inv.Args) > 0 {
 				directory = inv.Args[0]
 			} else {
-				directory = filepath.Join(workingDir, selectedTemplate.ID)
+				directory = filepath.Join(workingDir, name)
 			}
 			relPath, err := filepath.Rel(workingDir, directory)
 			if err != nil {
@@ -94,7 +125,7 @@ func (*RootCmd) templateInit() *clibase.Cmd {
 			} else {
 				relPath = "./" + relPath
 			}
-			_, _ = fmt.Fprintf(inv.Stdout, "Extracting %s to %s...\n", cliui.DefaultStyles.Field.Render(selectedTemplate.ID), relPath)
+			_, _ = fmt.Fprintf(inv.Stdout, "Extracting %s to %s...\n", cliui.DefaultStyles.Field.Render(source), relPath)
 			err = os.MkdirAll(directory, 0o700)
 			if err != nil {
 				return err
@@ -105,7 +136,9 @@ func (*RootCmd) templateInit() *clibase.Cmd {
 			}
 			_, _ = fmt.Fprintln(inv.Stdout, "Create your template by running:")
 			_, _ = fmt.Fprintln(inv.Stdout, cliui.DefaultStyles.Paragraph.Render(cliui.DefaultStyles.Code.Render("cd "+relPath+" && coder templates create"))+"\n")
-			_, _ = fmt.Fprintln(inv.Stdout, cliui.DefaultStyles.Wrap.Render("Examples provide a starting point and are expected to be edited! 🎨"))
+			if from == "" {
+				_, _ = fmt.Fprintln(inv.Stdout, cliui.DefaultStyles.Wrap.Render("Examples provide a starting point and are expected to be edited! 🎨"))
+			}
 			return nil
 		},
 	}
@@ -116,7 +149,13 @@ func (*RootCmd) templateInit() *clibase.Cmd {
 			Description: "Specify a given example template by ID.",
 			Value:       clibase.EnumOf(&templateID, templateIDs...),
 		},
+		{
+			Flag:        "from",
+			Description: "Pull the template from a template registry instead, e.g. oci://ghcr.io/coder/templates/docker:v1.",
+			Value:       clibase.StringOf(&from),
+		},
 	}
+	cmd.Options = append(cmd.Options, registryFlags.options()...)
 
 	return cmd
 }
@@ -7,7 +7,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/templateregistry"
+	"github.com/coder/coder/coderd/templateregistry/templateregistrytest"
+	"github.com/coder/coder/examples"
 	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
 )
 
 func TestTemplateInit(t *testing.T) {
@@ -45,4 +49,46 @@ func TestTemplateInit(t *testing.T) {
 		require.NoError(t, err)
 		require.Empty(t, files)
 	})
+	t.Run("FromRegistry", func(t *testing.T) {
+		t.Parallel()
+		registry := templateregistrytest.New(t, &templateregistrytest.Options{
+			Username: "coder",
+			Password: "hunter2",
+		})
+		ref, err := templateregistry.ParseReference("oci://" + registry.Host + "/coder/docker:v1")
+		require.NoError(t, err)
+		archive, err := examples.Archive("docker")
+		require.NoError(t, err)
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err = (&templateregistry.Client{
+			Registries: registry.Registries(),
+			Username:   "coder",
+			Password:   "hunter2",
+		}).Push(ctx, ref, archive)
+		require.NoError(t, err)
+
+		tempDir := t.TempDir()
+		inv, _ := clitest.New(t, "templates", "init", "--from", ref.String(), "--registry-username", "coder", "--registry-password", "hunter2", tempDir)
+		ptytest.New(t).Attach(inv)
+		clitest.Run(t, inv)
+		files, err := os.ReadDir(tempDir)
+		require.NoError(t, err)
+		require.Greater(t, len(files), 0)
+	})
+
+	t.Run("FromRegistryUnauthenticated", func(t *testing.T) {
+		t.Parallel()
+		registry := templateregistrytest.New(t, &templateregistrytest.Options{
+			Username: "coder",
+			Password: "hunter2",
+		})
+		tempDir := t.TempDir()
+		inv, _ := clitest.New(t, "templates", "init", "--from", "oci://"+registry.Host+"/coder/docker:v1", tempDir)
+		ptytest.New(t).Attach(inv)
+		err := inv.Run()
+		require.ErrorContains(t, err, "get token")
+		files, err := os.ReadDir(tempDir)
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
 }
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"os"
+
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/coder/coder/coderd/util/slice"
+	"github.com/coder/coder/codersdk"
+)
+
+// templatePresetFile is a parameter preset in a presets file. Presets are
+// keyed by their name:
+//
+//	GPU:
+//	  description: A workspace with a GPU for machine learning
+//	  parameters:
+//	    instance_type: g5.xlarge
+//	    disk_size: 100
+type templatePresetFile struct {
+	Description string            `yaml:"description"`
+	Parameters  map[string]string `yaml:"parameters"`
+}
+
+func loadPresetsFromFile(presetsFile string) ([]codersdk.TemplateVersionPreset, error) {
+	if presetsFile == "" {
+		return nil, nil
+	}
+
+	presetsFileContents, err := os.ReadFile(presetsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	presetsMap := make(map[string]templatePresetFile)
+	err = yaml.Unmarshal(presetsFileContents, &presetsMap)
+	if err != nil {
+		return nil, xerrors.Errorf("parse presets file: %w", err)
+	}
+
+	presets := make([]codersdk.TemplateVersionPreset, 0, len(presetsMap))
+	for name, preset := range presetsMap {
+		parameters := make([]codersdk.WorkspaceBuildParameter, 0, len(preset.Parameters))
+		for parameterName, value := range preset.Parameters {
+			parameters = append(parameters, codersdk.WorkspaceBuildParameter{
+				Name:  parameterName,
+				Value: value,
+			})
+		}
+		slices.SortFunc(parameters, func(a, b codersdk.WorkspaceBuildParameter) int {
+			return slice.Ascending(a.Name, b.Name)
+		})
+		presets = append(presets, codersdk.TemplateVersionPreset{
+			Name:        name,
+			Description: preset.Description,
+			Parameters:  parameters,
+		})
+	}
+	slices.SortFunc(presets, func(a, b codersdk.TemplateVersionPreset) int {
+		return slice.Ascending(a.Name, b.Name)
+	})
+	return presets, nil
+}
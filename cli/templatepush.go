@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"net"
 	"path/filepath"
 	"strings"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/coder/coder/cli/clibase"
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/templateregistry"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisionersdk"
 )
@@ -152,6 +154,55 @@ func (pf *templateUploadFlags) templateName(args []string) (string, error) {
 	return filepath.Base(absPath), nil
 }
 
+// templateRegistryFlags is shared by `templates push` and `templates init`.
+type templateRegistryFlags struct {
+	username string
+	password string
+}
+
+func (rf *templateRegistryFlags) options() []clibase.Option {
+	return []clibase.Option{{
+		Flag:        "registry-username",
+		Env:         "CODER_TEMPLATE_REGISTRY_USERNAME",
+		Description: "Specify the username to authenticate with the template registry.",
+		Value:       clibase.StringOf(&rf.username),
+	}, {
+		Flag:        "registry-password",
+		Env:         "CODER_TEMPLATE_REGISTRY_PASSWORD",
+		Description: "Specify the password or access token to authenticate with the template registry.",
+		Value:       clibase.StringOf(&rf.password),
+	}}
+}
+
+func (rf *templateRegistryFlags) auth() *codersdk.TemplateRegistryAuth {
+	if rf.username == "" && rf.password == "" {
+		return nil
+	}
+	return &codersdk.TemplateRegistryAuth{
+		Username: rf.username,
+		Password: rf.password,
+	}
+}
+
+// client returns a client that can only access the registry of ref, so the
+// credentials are never sent to another host. Registries on the loopback
+// interface are accessed over HTTP.
+func (rf *templateRegistryFlags) client(ref templateregistry.Reference) *templateregistry.Client {
+	host, _, err := net.SplitHostPort(ref.Registry)
+	if err != nil {
+		host = ref.Registry
+	}
+	ip := net.ParseIP(host)
+	return &templateregistry.Client{
+		Registries: []templateregistry.Registry{{
+			Host:     ref.Registry,
+			Insecure: host == "localhost" || (ip != nil && ip.IsLoopback()),
+		}},
+		Username: rf.username,
+		Password: rf.password,
+	}
+}
+
 func (r *RootCmd) templatePush() *clibase.Cmd {
 	var (
 		versionName     string
@@ -159,11 +210,14 @@ func (r *RootCmd) templatePush() *clibase.Cmd {
 		workdir         string
 		variablesFile   string
 		variables       []string
+		presetsFile     string
 		alwaysPrompt    bool
 		provisionerTags []string
 		uploadFlags     templateUploadFlags
 		activate        bool
 		create          bool
+		registry        string
+		registryFlags   templateRegistryFlags
 	)
 	client := new(codersdk.Client)
 	cmd := &clibase.Cmd{
@@ -221,6 +275,7 @@ func (r *RootCmd) templatePush() *clibase.Cmd {
 				ProvisionerTags: tags,
 				VariablesFile:   variablesFile,
 				Variables:       variables,
+				PresetsFile:     presetsFile,
 			}
 
 			if !createTemplate {
@@ -250,6 +305,16 @@ func (r *RootCmd) templatePush() *clibase.Cmd {
 				_, _ = fmt.Fprintln(inv.Stdout, "\n"+cliui.DefaultStyles.Wrap.Render(
 					"The "+cliui.DefaultStyles.Keyword.Render(name)+" template has been created at "+cliui.DefaultStyles.DateTimeStamp.Render(time.Now().Format(time.Stamp))+"! "+
 						"Developers can provision a workspace with this template using:")+"\n")
+			} else if activate && template.RequiredPromotionApprovals > 0 {
+				// The version only becomes active once other users approve it.
+				promotion, err := client.CreateTemplateVersionPromotion(inv.Context(), template.ID, codersdk.CreateTemplateVersionPromotionRequest{
+					TemplateVersionID: job.ID,
+				})
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(inv.Stdout, "The %s template requires %d approval(s) to promote the version. Promotion %s is pending approval.\n",
+					cliui.DefaultStyles.Keyword.Render(template.Name), template.RequiredPromotionApprovals, promotion.ID)
 			} else if activate {
 				err = client.UpdateActiveTemplateVersion(inv.Context(), template.ID, codersdk.UpdateActiveTemplateVersion{
 					ID: job.ID,
@@ -260,18 +325,27 @@ func (r *RootCmd) templatePush() *clibase.Cmd {
 			}
 
 			_, _ = fmt.Fprintf(inv.Stdout, "Updated version at %s!\n", cliui.DefaultStyles.DateTimeStamp.Render(time.Now().Format(time.Stamp)))
+
+			if registry != "" {
+				pushed, err := client.PushTemplateVersion(inv.Context(), job.ID, codersdk.PushTemplateVersionRequest{
+					Reference: registry,
+					Auth:      registryFlags.auth(),
+				})
+				if err != nil {
+					return xerrors.Errorf("push to registry: %w", err)
+				}
+				_, _ = fmt.Fprintf(inv.Stdout, "Pushed version to %s@%s!\n", cliui.DefaultStyles.Keyword.Render(pushed.Reference), pushed.Digest)
+			}
 			return nil
 		},
 	}
 
 	cmd.Options = clibase.OptionSet{
 		{
-			Flag:        "test.provisioner",
-			Description: "Customize the provisioner backend.",
+			Flag:        "provisioner",
+			Description: "The provisioner that runs the template: terraform, or the name of a provisioner plugin served by external provisioner daemons.",
 			Default:     "terraform",
 			Value:       clibase.StringOf(&provisioner),
-			// This is for testing!
-			Hidden: true,
 		},
 		{
 			Flag:        "test.workdir",
@@ -296,6 +370,11 @@ func (r *RootCmd) templatePush() *clibase.Cmd {
 			Description: "Alias of --variable.",
 			Value:       clibase.StringArrayOf(&variables),
 		},
+		{
+			Flag:        "presets-file",
+			Description: "Specify a file path with the parameter presets of the template version.",
+			Value:       clibase.StringOf(&presetsFile),
+		},
 		{
 			Flag:        "provisioner-tag",
 			Description: "Specify a set of tags to target provisioner daemons.",
@@ -323,8 +402,14 @@ func (r *RootCmd) templatePush() *clibase.Cmd {
 			Default:     "false",
 			Value:       clibase.BoolOf(&create),
 		},
+		{
+			Flag:        "registry",
+			Description: "Also publish the new version to a template registry, e.g. oci://ghcr.io/coder/templates/docker:v1. The Coder server pushes the version, so it must be able to reach the registry.",
+			Value:       clibase.StringOf(&registry),
+		},
 		cliui.SkipPromptOption(),
 	}
 	cmd.Options = append(cmd.Options, uploadFlags.options()...)
+	cmd.Options = append(cmd.Options, registryFlags.options()...)
 	return cmd
 }
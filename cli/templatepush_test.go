@@ -16,6 +16,8 @@ import (
 	"github.com/coder/coder/cli/clitest"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/templateregistry/templateregistrytest"
+	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/provisionersdk/proto"
@@ -40,7 +42,7 @@ func TestTemplatePush(t *testing.T) {
 			Parse:          echo.ParseComplete,
 			ProvisionApply: echo.ProvisionComplete,
 		})
-		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--name", "example")
+		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--name", "example")
 		clitest.SetupConfig(t, client, root)
 		pty := ptytest.New(t).Attach(inv)
 
@@ -87,7 +89,7 @@ func TestTemplatePush(t *testing.T) {
 
 		wantMessage := strings.Repeat("a", 72)
 
-		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--name", "example", "--message", wantMessage, "--yes")
+		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--name", "example", "--message", wantMessage, "--yes")
 		clitest.SetupConfig(t, client, root)
 		pty := ptytest.New(t).Attach(inv)
 
@@ -134,7 +136,7 @@ func TestTemplatePush(t *testing.T) {
 			{wantMessage: strings.Repeat("a", 73), wantMatch: "Template message is longer than 72 characters"},
 			{wantMessage: "This is my title\n\nAnd this is my body.", wantMatch: "Template message contains newlines"},
 		} {
-			inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--message", tt.wantMessage, "--yes")
+			inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--message", tt.wantMessage, "--yes")
 			clitest.SetupConfig(t, client, root)
 			pty := ptytest.New(t).Attach(inv)
 
@@ -172,7 +174,7 @@ func TestTemplatePush(t *testing.T) {
 		})
 		require.NoError(t, os.Remove(filepath.Join(source, ".terraform.lock.hcl")))
 
-		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--name", "example")
+		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--name", "example")
 		clitest.SetupConfig(t, client, root)
 		pty := ptytest.New(t).Attach(inv)
 
@@ -215,7 +217,7 @@ func TestTemplatePush(t *testing.T) {
 		})
 		require.NoError(t, os.Remove(filepath.Join(source, ".terraform.lock.hcl")))
 
-		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--name", "example", "--ignore-lockfile")
+		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--name", "example", "--ignore-lockfile")
 		clitest.SetupConfig(t, client, root)
 		pty := ptytest.New(t).Attach(inv)
 
@@ -250,7 +252,7 @@ func TestTemplatePush(t *testing.T) {
 			Parse:          echo.ParseComplete,
 			ProvisionApply: echo.ProvisionComplete,
 		})
-		inv, root := clitest.New(t, "templates", "push", template.Name, "--activate=false", "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--name", "example")
+		inv, root := clitest.New(t, "templates", "push", template.Name, "--activate=false", "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--name", "example")
 		clitest.SetupConfig(t, client, root)
 		pty := ptytest.New(t).Attach(inv)
 		w := clitest.StartWithWaiter(t, inv)
@@ -278,6 +280,135 @@ func TestTemplatePush(t *testing.T) {
 		require.NotEqual(t, "example", templateVersions[0].Name)
 	})
 
+	t.Run("RequiresPromotionApprovals", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		_, err := client.UpdateTemplateMeta(context.Background(), template.ID, codersdk.UpdateTemplateMeta{
+			Name:                       template.Name,
+			RequiredPromotionApprovals: ptr.Ref(int32(1)),
+		})
+		require.NoError(t, err)
+
+		// Test the cli command.
+		source := clitest.CreateTemplateVersionSource(t, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionApply: echo.ProvisionComplete,
+		})
+		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--name", "example")
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t).Attach(inv)
+		w := clitest.StartWithWaiter(t, inv)
+
+		pty.ExpectMatch("Upload")
+		pty.WriteLine("yes")
+		pty.ExpectMatch("pending approval")
+
+		w.RequireSuccess()
+
+		// The version is only promoted once it's approved.
+		promotions, err := client.TemplateVersionPromotions(context.Background(), template.ID, codersdk.TemplateVersionPromotionStatusPending)
+		require.NoError(t, err)
+		require.Len(t, promotions, 1)
+		updated, err := client.Template(context.Background(), template.ID)
+		require.NoError(t, err)
+		require.Equal(t, template.ActiveVersionID, updated.ActiveVersionID)
+		require.NotEqual(t, template.ActiveVersionID, promotions[0].TemplateVersionID)
+	})
+
+	t.Run("Registry", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		registry := templateregistrytest.New(t, nil)
+
+		// Test the cli command.
+		source := clitest.CreateTemplateVersionSource(t, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionApply: echo.ProvisionComplete,
+		})
+		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho),
+			"--registry", "oci://"+registry.Host+"/coder/echo:v1", "--yes")
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t).Attach(inv)
+		w := clitest.StartWithWaiter(t, inv)
+
+		pty.ExpectMatch("Pushed version to")
+
+		w.RequireSuccess()
+
+		_, ok := registry.Manifest("coder/echo", "v1")
+		require.True(t, ok)
+
+		// The pushed version can be pulled again.
+		directory := t.TempDir()
+		inv, _ = clitest.New(t, "templates", "init", "--from", "oci://"+registry.Host+"/coder/echo:v1", directory)
+		clitest.Run(t, inv)
+		expected, err := os.ReadDir(source)
+		require.NoError(t, err)
+		actual, err := os.ReadDir(directory)
+		require.NoError(t, err)
+		require.Len(t, actual, len(expected))
+	})
+
+	t.Run("Presets", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		// Test the cli command.
+		source := clitest.CreateTemplateVersionSource(t, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionApply: echo.ProvisionComplete,
+		})
+		tempDir := t.TempDir()
+		removeTmpDirUntilSuccessAfterTest(t, tempDir)
+		presetsFile, _ := os.CreateTemp(tempDir, "presets*.yaml")
+		_, _ = presetsFile.WriteString(`small:
+  description: Two CPUs for web development
+  parameters:
+    cpu: 2
+GPU:
+  parameters:
+    gpu: true
+    cpu: 16
+`)
+		inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho),
+			"--presets-file", presetsFile.Name(), "--yes")
+		clitest.SetupConfig(t, client, root)
+		clitest.Run(t, inv)
+
+		template, err := client.Template(context.Background(), template.ID)
+		require.NoError(t, err)
+		presets, err := client.TemplateVersionPresets(context.Background(), template.ActiveVersionID)
+		require.NoError(t, err)
+		require.Equal(t, []codersdk.TemplateVersionPreset{{
+			Name: "GPU",
+			Parameters: []codersdk.WorkspaceBuildParameter{
+				{Name: "cpu", Value: "16"},
+				{Name: "gpu", Value: "true"},
+			},
+		}, {
+			Name:        "small",
+			Description: "Two CPUs for web development",
+			Parameters: []codersdk.WorkspaceBuildParameter{
+				{Name: "cpu", Value: "2"},
+			},
+		}}, presets)
+	})
+
 	t.Run("UseWorkingDir", func(t *testing.T) {
 		t.Parallel()
 
@@ -303,7 +434,7 @@ func TestTemplatePush(t *testing.T) {
 
 		// Don't pass the name of the template, it should use the
 		// directory of the source.
-		inv, root := clitest.New(t, "templates", "push", "--test.provisioner", string(database.ProvisionerTypeEcho), "--test.workdir", source)
+		inv, root := clitest.New(t, "templates", "push", "--provisioner", string(database.ProvisionerTypeEcho), "--test.workdir", source)
 		clitest.SetupConfig(t, client, root)
 		pty := ptytest.New(t).Attach(inv)
 
@@ -348,7 +479,7 @@ func TestTemplatePush(t *testing.T) {
 
 		inv, root := clitest.New(
 			t, "templates", "push", "--directory", "-",
-			"--test.provisioner", string(database.ProvisionerTypeEcho),
+			"--provisioner", string(database.ProvisionerTypeEcho),
 			template.Name,
 		)
 		clitest.SetupConfig(t, client, root)
@@ -409,7 +540,7 @@ func TestTemplatePush(t *testing.T) {
 			removeTmpDirUntilSuccessAfterTest(t, tempDir)
 			variablesFile, _ := os.CreateTemp(tempDir, "variables*.yaml")
 			_, _ = variablesFile.WriteString(`second_variable: foobar`)
-			inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--name", "example", "--variables-file", variablesFile.Name())
+			inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--name", "example", "--variables-file", variablesFile.Name())
 			clitest.SetupConfig(t, client, root)
 			pty := ptytest.New(t)
 			inv.Stdin = pty.Input()
@@ -468,7 +599,7 @@ func TestTemplatePush(t *testing.T) {
 				},
 			)
 			source := clitest.CreateTemplateVersionSource(t, createEchoResponsesWithTemplateVariables(modifiedTemplateVariables))
-			inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--name", "example")
+			inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--name", "example")
 			clitest.SetupConfig(t, client, root)
 			pty := ptytest.New(t)
 			inv.Stdin = pty.Input()
@@ -515,7 +646,7 @@ func TestTemplatePush(t *testing.T) {
 				},
 			)
 			source := clitest.CreateTemplateVersionSource(t, createEchoResponsesWithTemplateVariables(modifiedTemplateVariables))
-			inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--name", "example")
+			inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--name", "example")
 			clitest.SetupConfig(t, client, root)
 			pty := ptytest.New(t)
 			inv.Stdin = pty.Input()
@@ -575,7 +706,7 @@ func TestTemplatePush(t *testing.T) {
 				},
 			)
 			source := clitest.CreateTemplateVersionSource(t, createEchoResponsesWithTemplateVariables(modifiedTemplateVariables))
-			inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--test.provisioner", string(database.ProvisionerTypeEcho), "--name", "example", "--variable", "second_variable=foobar")
+			inv, root := clitest.New(t, "templates", "push", template.Name, "--directory", source, "--provisioner", string(database.ProvisionerTypeEcho), "--name", "example", "--variable", "second_variable=foobar")
 			clitest.SetupConfig(t, client, root)
 			pty := ptytest.New(t)
 			inv.Stdin = pty.Input()
@@ -630,7 +761,7 @@ func TestTemplatePush(t *testing.T) {
 				"push",
 				templateName,
 				"--directory", source,
-				"--test.provisioner", string(database.ProvisionerTypeEcho),
+				"--provisioner", string(database.ProvisionerTypeEcho),
 				"--create",
 			}
 			inv, root := clitest.New(t, args...)
@@ -42,6 +42,8 @@ func (r *RootCmd) templates() *clibase.Cmd {
 			r.templateVersions(),
 			r.templateDelete(),
 			r.templatePull(),
+			r.templateSchedule(),
+			r.templateTest(),
 		},
 	}
 
@@ -0,0 +1,470 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+const (
+	templateScheduleShowDescriptionLong = `Shows the following information for the given template:
+  * The default and maximum time before workspaces are stopped
+  * The activity bump
+  * The restart requirement
+  * The failure, inactivity and locked TTLs, and the retries of failed starts
+  * The max lifetime of workspaces and how long they are archived for
+  * Whether users may configure autostart and autostop
+`
+	templateScheduleEditDescriptionLong = `Edits the schedule policy of a template. Options that are not specified keep
+their current value.
+  * Changes to the restart requirement also update the deadlines of running
+    workspaces. Other changes take effect upon the next build of each workspace.
+  * The max TTL, restart requirement, failure, inactivity and locked TTLs,
+    failure retries, max lifetime, archive retention, and disabling user
+    autostart or autostop are enterprise-only.
+`
+)
+
+func (r *RootCmd) templateSchedule() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Use:   "schedule { show | edit } <template>",
+		Short: "Show or edit the schedule policy of a template",
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.templateScheduleShow(),
+			r.templateScheduleEdit(),
+		},
+	}
+
+	return cmd
+}
+
+func (r *RootCmd) templateScheduleShow() *clibase.Cmd {
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "show <template>",
+		Short: "Show the schedule policy of a template",
+		Long:  templateScheduleShowDescriptionLong,
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template: %w", err)
+			}
+
+			return displayTemplateSchedule(template, inv.Stdout)
+		},
+	}
+	return cmd
+}
+
+func (r *RootCmd) templateScheduleEdit() *clibase.Cmd {
+	var (
+		defaultTTL                   time.Duration
+		activityBump                 time.Duration
+		maxTTL                       time.Duration
+		restartRequirementDaysOfWeek []string
+		restartRequirementWeeks      int64
+		restartRequirementTimezone   string
+		restartRequirementSpread     time.Duration
+		updateOnRestart              bool
+		failureTTL                   time.Duration
+		failureRetries               int64
+		failureRetryBackoff          time.Duration
+		inactivityTTL                time.Duration
+		lockedTTL                    time.Duration
+		maxLifetime                  time.Duration
+		archiveRetention             time.Duration
+		allowUserAutostart           bool
+		allowUserAutostop            bool
+	)
+	client := new(codersdk.Client)
+
+	cmd := &clibase.Cmd{
+		Use:   "edit <template>",
+		Short: "Edit the schedule policy of a template",
+		Long: templateScheduleEditDescriptionLong + "\n" + formatExamples(
+			example{
+				Description: "Stop workspaces after 8 hours by default and 30 minutes after activity was last detected",
+				Command:     "coder templates schedule edit my-template --default-ttl 8h --activity-bump 30m",
+			},
+			example{
+				Description: "Require workspaces to be restarted every Saturday during their owner's quiet hours",
+				Command:     "coder templates schedule edit my-template --restart-requirement-weekdays saturday",
+			},
+		),
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			changed := inv.ParsedFlags().Changed
+			// This clause can be removed when workspace_actions is no longer experimental
+			if failureTTL != 0 || inactivityTTL != 0 || lockedTTL != 0 {
+				experiments, exErr := client.Experiments(inv.Context())
+				if exErr != nil {
+					return xerrors.Errorf("get experiments: %w", exErr)
+				}
+
+				if !experiments.Enabled(codersdk.ExperimentWorkspaceActions) {
+					return xerrors.Errorf("--failure-ttl, --inactivity-ttl and --locked-ttl are experimental features. Use the workspace_actions CODER_EXPERIMENTS flag to set these configuration values.")
+				}
+			}
+
+			unsetRestartRequirementDaysOfWeek := len(restartRequirementDaysOfWeek) == 1 && restartRequirementDaysOfWeek[0] == "none"
+			requiresEntitlement := (len(restartRequirementDaysOfWeek) > 0 && !unsetRestartRequirementDaysOfWeek) ||
+				restartRequirementWeeks > 0 ||
+				(restartRequirementTimezone != "" && restartRequirementTimezone != "none") ||
+				restartRequirementSpread != 0 ||
+				(changed("restart-requirement-update-on-restart") && updateOnRestart) ||
+				(changed("allow-user-autostart") && !allowUserAutostart) ||
+				(changed("allow-user-autostop") && !allowUserAutostop) ||
+				maxTTL != 0 ||
+				failureTTL != 0 ||
+				failureRetries != 0 ||
+				failureRetryBackoff != 0 ||
+				inactivityTTL != 0 ||
+				lockedTTL != 0 ||
+				maxLifetime != 0 ||
+				archiveRetention != 0
+			if requiresEntitlement {
+				entitlements, err := client.Entitlements(inv.Context())
+				var sdkErr *codersdk.Error
+				if xerrors.As(err, &sdkErr) && sdkErr.StatusCode() == http.StatusNotFound {
+					return xerrors.Errorf("your deployment appears to be an AGPL deployment, so you can only set --default-ttl and --activity-bump")
+				} else if err != nil {
+					return xerrors.Errorf("get entitlements: %w", err)
+				}
+
+				if !entitlements.Features[codersdk.FeatureAdvancedTemplateScheduling].Enabled {
+					return xerrors.Errorf("your license is not entitled to use advanced template scheduling, so you can only set --default-ttl and --activity-bump")
+				}
+			}
+
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(inv.Context(), organization.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get template: %w", err)
+			}
+
+			// Start from the current values of the template so unspecified
+			// options, including the non-schedule metadata, are kept.
+			req := codersdk.UpdateTemplateMeta{
+				Name:             template.Name,
+				DisplayName:      template.DisplayName,
+				Description:      template.Description,
+				Icon:             template.Icon,
+				DefaultTTLMillis: template.DefaultTTLMillis,
+				MaxTTLMillis:     template.MaxTTLMillis,
+				RestartRequirement: &codersdk.TemplateRestartRequirement{
+					DaysOfWeek:      template.RestartRequirement.DaysOfWeek,
+					Weeks:           template.RestartRequirement.Weeks,
+					Timezone:        template.RestartRequirement.Timezone,
+					SpreadMillis:    template.RestartRequirement.SpreadMillis,
+					UpdateOnRestart: template.RestartRequirement.UpdateOnRestart,
+				},
+				FailureTTLMillis:             template.FailureTTLMillis,
+				InactivityTTLMillis:          template.InactivityTTLMillis,
+				LockedTTLMillis:              template.LockedTTLMillis,
+				AllowUserCancelWorkspaceJobs: template.AllowUserCancelWorkspaceJobs,
+				AllowUserAutostart:           template.AllowUserAutostart,
+				AllowUserAutostop:            template.AllowUserAutostop,
+			}
+			if changed("default-ttl") {
+				req.DefaultTTLMillis = defaultTTL.Milliseconds()
+			}
+			if changed("activity-bump") {
+				bump := activityBump.Milliseconds()
+				req.ActivityBumpMillis = &bump
+			}
+			if changed("max-ttl") {
+				req.MaxTTLMillis = maxTTL.Milliseconds()
+			}
+			if changed("restart-requirement-weekdays") {
+				req.RestartRequirement.DaysOfWeek = restartRequirementDaysOfWeek
+				if unsetRestartRequirementDaysOfWeek {
+					req.RestartRequirement.DaysOfWeek = []string{}
+				}
+			}
+			if changed("restart-requirement-weeks") {
+				req.RestartRequirement.Weeks = restartRequirementWeeks
+			}
+			if changed("restart-requirement-timezone") {
+				req.RestartRequirement.Timezone = restartRequirementTimezone
+				if restartRequirementTimezone == "none" {
+					req.RestartRequirement.Timezone = ""
+				}
+			}
+			if changed("restart-requirement-spread") {
+				req.RestartRequirement.SpreadMillis = restartRequirementSpread.Milliseconds()
+			}
+			if changed("restart-requirement-update-on-restart") {
+				req.RestartRequirement.UpdateOnRestart = updateOnRestart
+			}
+			if changed("failure-ttl") {
+				req.FailureTTLMillis = failureTTL.Milliseconds()
+			}
+			if changed("failure-retries") {
+				retries := int32(failureRetries)
+				req.FailureRetries = &retries
+			}
+			if changed("failure-retry-backoff") {
+				backoff := failureRetryBackoff.Milliseconds()
+				req.FailureRetryBackoffMillis = &backoff
+			}
+			if changed("inactivity-ttl") {
+				req.InactivityTTLMillis = inactivityTTL.Milliseconds()
+			}
+			if changed("locked-ttl") {
+				req.LockedTTLMillis = lockedTTL.Milliseconds()
+			}
+			if changed("max-lifetime") {
+				lifetime := maxLifetime.Milliseconds()
+				req.MaxLifetimeMillis = &lifetime
+			}
+			if changed("archive-retention") {
+				retention := archiveRetention.Milliseconds()
+				req.ArchiveRetentionMillis = &retention
+			}
+			if changed("allow-user-autostart") {
+				req.AllowUserAutostart = allowUserAutostart
+			}
+			if changed("allow-user-autostop") {
+				req.AllowUserAutostop = allowUserAutostop
+			}
+
+			if templateScheduleUnchanged(template, req) {
+				_, _ = fmt.Fprintln(inv.Stdout, "The template schedule was not modified.")
+				return displayTemplateSchedule(template, inv.Stdout)
+			}
+
+			updated, err := client.UpdateTemplateMeta(inv.Context(), template.ID, req)
+			if err != nil {
+				return xerrors.Errorf("update template schedule: %w", err)
+			}
+			return displayTemplateSchedule(updated, inv.Stdout)
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:        "default-ttl",
+			Description: "Edit the default time before shutdown - workspaces created from the template default to this value. Pass 0 to disable autostop by default.",
+			Value:       clibase.DurationOf(&defaultTTL),
+		},
+		{
+			Flag:        "activity-bump",
+			Description: "Edit the activity bump - workspaces stay running for this long after activity was last detected, and are stopped early when idle. To bump the deadline by the workspace TTL instead, pass 0.",
+			Value:       clibase.DurationOf(&activityBump),
+		},
+		{
+			Flag:        "max-ttl",
+			Description: "Edit the maximum time before shutdown - workspaces must shutdown within the given duration after starting. Pass 0 to remove the limit.",
+			Value:       clibase.DurationOf(&maxTTL),
+		},
+		{
+			Flag:        "restart-requirement-weekdays",
+			Description: "Edit the restart requirement weekdays - workspaces must be restarted on the given weekdays during their owner's quiet hours. To disable the restart requirement, pass 'none'.",
+			Value: clibase.Validate(clibase.StringArrayOf(&restartRequirementDaysOfWeek), func(value *clibase.StringArray) error {
+				v := value.GetSlice()
+				if len(v) == 1 && v[0] == "none" {
+					return nil
+				}
+				_, err := codersdk.WeekdaysToBitmap(v)
+				if err != nil {
+					return xerrors.Errorf("invalid restart requirement days of week %q: %w", strings.Join(v, ","), err)
+				}
+				return nil
+			}),
+		},
+		{
+			Flag:        "restart-requirement-weeks",
+			Description: "Edit the restart requirement weeks - workspaces must be restarted on an n-weekly basis.",
+			Value:       clibase.Int64Of(&restartRequirementWeeks),
+		},
+		{
+			Flag:        "restart-requirement-timezone",
+			Description: "Edit the restart requirement timezone - the IANA timezone in which users' quiet hours are evaluated for restarts. To use the timezone of each user's quiet hours schedule, pass 'none'.",
+			Value: clibase.Validate(clibase.StringOf(&restartRequirementTimezone), func(value *clibase.String) error {
+				v := value.String()
+				if v == "" || v == "none" {
+					return nil
+				}
+				_, err := time.LoadLocation(v)
+				if err != nil {
+					return xerrors.Errorf("invalid restart requirement timezone %q: %w", v, err)
+				}
+				return nil
+			}),
+		},
+		{
+			Flag:        "restart-requirement-spread",
+			Description: "Edit the restart requirement spread - required restarts are spread across this window after the start of their owner's quiet hours, so workspaces don't all restart at the same time. Pass 0 to disable.",
+			Value:       clibase.DurationOf(&restartRequirementSpread),
+		},
+		{
+			Flag:        "restart-requirement-update-on-restart",
+			Description: "Edit whether workspaces stopped by the restart requirement are started again on the active template version.",
+			Value:       clibase.BoolOf(&updateOnRestart),
+		},
+		{
+			Flag:        "failure-ttl",
+			Description: "Edit the failure TTL - failed workspaces are stopped after this long. Pass 0 to disable.",
+			Value:       clibase.DurationOf(&failureTTL),
+		},
+		{
+			Flag:        "failure-retries",
+			Description: "Edit the failure retries - failed workspace starts are retried this many times before the owner is notified and the failure TTL applies. Pass 0 to disable.",
+			Value:       clibase.Int64Of(&failureRetries),
+		},
+		{
+			Flag:        "failure-retry-backoff",
+			Description: "Edit the failure retry backoff - failed workspace starts are retried after this long, doubling after every retry.",
+			Value:       clibase.DurationOf(&failureRetryBackoff),
+		},
+		{
+			Flag:        "inactivity-ttl",
+			Description: "Edit the inactivity TTL - workspaces that are not used for this long are locked. Pass 0 to disable.",
+			Value:       clibase.DurationOf(&inactivityTTL),
+		},
+		{
+			Flag:        "locked-ttl",
+			Description: "Edit the locked TTL - workspaces that are locked for this long are deleted. Pass 0 to disable.",
+			Value:       clibase.DurationOf(&lockedTTL),
+		},
+		{
+			Flag:        "archive-retention",
+			Description: "Edit the archive retention - workspaces that reach the locked TTL are archived for this long before they are deleted, and can be restored by unlocking them. Pass 0 to delete them without archiving.",
+			Value:       clibase.DurationOf(&archiveRetention),
+		},
+		{
+			Flag:        "max-lifetime",
+			Description: "Edit the max lifetime - workspaces are deleted this long after they were created, regardless of activity. If the template has a locked TTL, workspaces are locked instead. Pass 0 to disable.",
+			Value:       clibase.DurationOf(&maxLifetime),
+		},
+		{
+			Flag:        "allow-user-autostart",
+			Description: "Allow users to configure autostart for workspaces on the template.",
+			Value:       clibase.BoolOf(&allowUserAutostart),
+		},
+		{
+			Flag:        "allow-user-autostop",
+			Description: "Allow users to customize the autostop TTL for workspaces on the template.",
+			Value:       clibase.BoolOf(&allowUserAutostop),
+		},
+	}
+
+	return cmd
+}
+
+// templateScheduleUnchanged returns true if req would not change the schedule
+// of the template. The API responds with 304 Not Modified to such updates.
+func templateScheduleUnchanged(template codersdk.Template, req codersdk.UpdateTemplateMeta) bool {
+	return req.DefaultTTLMillis == template.DefaultTTLMillis &&
+		(req.ActivityBumpMillis == nil || *req.ActivityBumpMillis == template.ActivityBumpMillis) &&
+		req.MaxTTLMillis == template.MaxTTLMillis &&
+		sameWeekdays(req.RestartRequirement.DaysOfWeek, template.RestartRequirement.DaysOfWeek) &&
+		req.RestartRequirement.Weeks == template.RestartRequirement.Weeks &&
+		req.RestartRequirement.Timezone == template.RestartRequirement.Timezone &&
+		req.RestartRequirement.SpreadMillis == template.RestartRequirement.SpreadMillis &&
+		req.RestartRequirement.UpdateOnRestart == template.RestartRequirement.UpdateOnRestart &&
+		req.FailureTTLMillis == template.FailureTTLMillis &&
+		(req.FailureRetries == nil || *req.FailureRetries == template.FailureRetries) &&
+		(req.FailureRetryBackoffMillis == nil || *req.FailureRetryBackoffMillis == template.FailureRetryBackoffMillis) &&
+		req.InactivityTTLMillis == template.InactivityTTLMillis &&
+		req.LockedTTLMillis == template.LockedTTLMillis &&
+		(req.MaxLifetimeMillis == nil || *req.MaxLifetimeMillis == template.MaxLifetimeMillis) &&
+		(req.ArchiveRetentionMillis == nil || *req.ArchiveRetentionMillis == template.ArchiveRetentionMillis) &&
+		req.AllowUserAutostart == template.AllowUserAutostart &&
+		req.AllowUserAutostop == template.AllowUserAutostop
+}
+
+func sameWeekdays(a, b []string) bool {
+	// Invalid weekdays are rejected when parsing the flags.
+	x, _ := codersdk.WeekdaysToBitmap(a)
+	y, _ := codersdk.WeekdaysToBitmap(b)
+	return x == y
+}
+
+func displayTemplateSchedule(template codersdk.Template, out io.Writer) error {
+	durationOrNone := func(millis int64) string {
+		if millis <= 0 {
+			return "none"
+		}
+		return durationDisplay(time.Duration(millis) * time.Millisecond)
+	}
+	allowed := func(v bool) string {
+		if v {
+			return "allowed"
+		}
+		return "not allowed"
+	}
+
+	activityBump := "workspace TTL"
+	if template.ActivityBumpMillis > 0 {
+		activityBump = durationDisplay(time.Duration(template.ActivityBumpMillis) * time.Millisecond)
+	}
+
+	restartRequirement := "none"
+	if len(template.RestartRequirement.DaysOfWeek) > 0 {
+		restartRequirement = strings.Join(template.RestartRequirement.DaysOfWeek, ", ")
+		if template.RestartRequirement.Weeks > 1 {
+			restartRequirement = fmt.Sprintf("%s every %d weeks", restartRequirement, template.RestartRequirement.Weeks)
+		}
+		timezone := template.RestartRequirement.Timezone
+		if timezone == "" {
+			timezone = "user quiet hours timezone"
+		}
+		restartRequirement = fmt.Sprintf("%s (%s)", restartRequirement, timezone)
+		if template.RestartRequirement.SpreadMillis > 0 {
+			restartRequirement = fmt.Sprintf("%s, spread over %s", restartRequirement, durationDisplay(time.Duration(template.RestartRequirement.SpreadMillis)*time.Millisecond))
+		}
+		if template.RestartRequirement.UpdateOnRestart {
+			restartRequirement += ", updates workspaces"
+		}
+	}
+
+	tw := cliui.Table()
+	tw.AppendRow(table.Row{"Default TTL", durationOrNone(template.DefaultTTLMillis)})
+	tw.AppendRow(table.Row{"Activity bump", activityBump})
+	tw.AppendRow(table.Row{"Max TTL", durationOrNone(template.MaxTTLMillis)})
+	tw.AppendRow(table.Row{"Restart requirement", restartRequirement})
+	failureTTL := durationOrNone(template.FailureTTLMillis)
+	if template.FailureTTLMillis > 0 && template.FailureRetries > 0 {
+		failureTTL = fmt.Sprintf("%s, after %d retries", failureTTL, template.FailureRetries)
+		if template.FailureRetryBackoffMillis > 0 {
+			failureTTL = fmt.Sprintf("%s starting %s apart", failureTTL, durationDisplay(time.Duration(template.FailureRetryBackoffMillis)*time.Millisecond))
+		}
+	}
+	tw.AppendRow(table.Row{"Failure TTL", failureTTL})
+	tw.AppendRow(table.Row{"Inactivity TTL", durationOrNone(template.InactivityTTLMillis)})
+	tw.AppendRow(table.Row{"Locked TTL", durationOrNone(template.LockedTTLMillis)})
+	tw.AppendRow(table.Row{"Archive retention", durationOrNone(template.ArchiveRetentionMillis)})
+	tw.AppendRow(table.Row{"Max lifetime", durationOrNone(template.MaxLifetimeMillis)})
+	tw.AppendRow(table.Row{"User autostart", allowed(template.AllowUserAutostart)})
+	tw.AppendRow(table.Row{"User autostop", allowed(template.AllowUserAutostop)})
+
+	_, _ = fmt.Fprintln(out, tw.Render())
+	return nil
+}
@@ -0,0 +1,148 @@
+package cli_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateSchedule(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Show", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.DefaultTTLMillis = ptr.Ref((8 * time.Hour).Milliseconds())
+		})
+
+		inv, root := clitest.New(t, "templates", "schedule", "show", template.Name)
+		clitest.SetupConfig(t, client, root)
+		var stdout bytes.Buffer
+		inv.Stdout = &stdout
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+
+		lines := bytes.Split(bytes.TrimSpace(stdout.Bytes()), []byte("\n"))
+		require.Len(t, lines, 9)
+		assert.Contains(t, string(lines[0]), "Default TTL")
+		assert.Contains(t, string(lines[0]), "8h")
+		assert.Contains(t, string(lines[1]), "Activity bump")
+		assert.Contains(t, string(lines[1]), "workspace TTL")
+		assert.Contains(t, string(lines[3]), "Restart requirement")
+		assert.Contains(t, string(lines[3]), "none")
+		assert.Contains(t, string(lines[7]), "User autostart")
+		assert.Contains(t, string(lines[7]), "allowed")
+	})
+
+	t.Run("Edit", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.DisplayName = "My Template"
+			ctr.Description = "A template."
+			ctr.AllowUserCancelWorkspaceJobs = ptr.Ref(false)
+		})
+
+		inv, root := clitest.New(t, "templates", "schedule", "edit", template.Name,
+			"--default-ttl", "2h",
+			"--activity-bump", "30m",
+		)
+		clitest.SetupConfig(t, client, root)
+		var stdout bytes.Buffer
+		inv.Stdout = &stdout
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+		assert.Contains(t, stdout.String(), "2h")
+		assert.Contains(t, stdout.String(), "30m")
+
+		updated, err := client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		assert.Equal(t, (2 * time.Hour).Milliseconds(), updated.DefaultTTLMillis)
+		assert.Equal(t, (30 * time.Minute).Milliseconds(), updated.ActivityBumpMillis)
+		// Metadata that isn't part of the schedule is kept.
+		assert.Equal(t, template.Name, updated.Name)
+		assert.Equal(t, "My Template", updated.DisplayName)
+		assert.Equal(t, "A template.", updated.Description)
+		assert.False(t, updated.AllowUserCancelWorkspaceJobs)
+
+		// Only the specified options are changed.
+		inv, root = clitest.New(t, "templates", "schedule", "edit", template.Name, "--activity-bump", "0")
+		clitest.SetupConfig(t, client, root)
+		err = inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+
+		updated, err = client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		assert.Equal(t, (2 * time.Hour).Milliseconds(), updated.DefaultTTLMillis)
+		assert.Zero(t, updated.ActivityBumpMillis)
+	})
+
+	t.Run("NotModified", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		inv, root := clitest.New(t, "templates", "schedule", "edit", template.Name,
+			"--default-ttl", (time.Duration(template.DefaultTTLMillis) * time.Millisecond).String(),
+		)
+		clitest.SetupConfig(t, client, root)
+		var stdout bytes.Buffer
+		inv.Stdout = &stdout
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+		assert.Contains(t, stdout.String(), "not modified")
+
+		updated, err := client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		assert.Equal(t, template.UpdatedAt, updated.UpdatedAt)
+	})
+
+	t.Run("RequiresEntitlement", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		inv, root := clitest.New(t, "templates", "schedule", "edit", template.Name, "--max-ttl", "12h")
+		clitest.SetupConfig(t, client, root)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		err := inv.WithContext(ctx).Run()
+		require.ErrorContains(t, err, "AGPL deployment")
+
+		updated, err := client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		assert.Zero(t, updated.MaxTTLMillis)
+	})
+}
@@ -0,0 +1,346 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/cryptorand"
+)
+
+func (r *RootCmd) templateTest() *clibase.Cmd {
+	var (
+		provisioner     string
+		provisionerTags []string
+		variablesFile   string
+		variables       []string
+		assertions      []string
+		agentName       string
+		timeout         time.Duration
+		keep            bool
+
+		uploadFlags    templateUploadFlags
+		parameterFlags workspaceParameterFlags
+	)
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "test",
+		Short: "Test a template in an ephemeral workspace",
+		Long: "Creates a template with a random name from the directory and a workspace from the template, waits for the agents of the workspace to connect and their startup scripts to succeed, runs the assertions in the workspace, and deletes the workspace and template again. The template is only accessible to you.\n\n" + formatExamples(
+			example{
+				Description: "Test the template in the current directory, e.g. in a CI pipeline",
+				Command:     "coder templates test --yes",
+			},
+			example{
+				Description: "Check that a tool is installed in the workspace",
+				Command:     `coder templates test --yes --assert "go version" --parameter region=eu`,
+			},
+		),
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(0),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) (err error) {
+			ctx, cancel := context.WithTimeout(inv.Context(), timeout)
+			defer cancel()
+
+			organization, err := CurrentOrganization(inv, client)
+			if err != nil {
+				return err
+			}
+
+			suffix, err := cryptorand.StringCharset(cryptorand.Lower, 8)
+			if err != nil {
+				return xerrors.Errorf("generate name: %w", err)
+			}
+			name := "test-" + suffix
+
+			resp, err := uploadFlags.upload(inv, client)
+			if err != nil {
+				return err
+			}
+
+			tags, err := ParseProvisionerTags(provisionerTags)
+			if err != nil {
+				return err
+			}
+
+			version, err := createValidTemplateVersion(inv, createValidTemplateVersionArgs{
+				Message:         "Uploaded by coder templates test",
+				Client:          client,
+				Organization:    organization,
+				Provisioner:     database.ProvisionerType(provisioner),
+				FileID:          resp.ID,
+				ProvisionerTags: tags,
+				VariablesFile:   variablesFile,
+				Variables:       variables,
+			})
+			if err != nil {
+				return err
+			}
+
+			template, err := client.CreateTemplate(ctx, organization.ID, codersdk.CreateTemplateRequest{
+				Name:                       name,
+				VersionID:                  version.ID,
+				DisableEveryoneGroupAccess: true,
+			})
+			if err != nil {
+				return xerrors.Errorf("create template: %w", err)
+			}
+
+			var workspace codersdk.Workspace
+			defer func() {
+				if keep {
+					cliui.Infof(inv.Stdout, "Keeping the %s template and workspace.", cliui.DefaultStyles.Keyword.Render(name))
+					return
+				}
+				cleanupErr := cleanupTemplateTest(inv, client, template, workspace)
+				if cleanupErr != nil && err == nil {
+					err = cleanupErr
+				}
+			}()
+
+			cliRichParameters, err := asWorkspaceBuildParameters(parameterFlags.richParameters)
+			if err != nil {
+				return xerrors.Errorf("can't parse given parameter values: %w", err)
+			}
+
+			richParameters, err := prepWorkspaceBuild(inv, client, prepWorkspaceBuildArgs{
+				Action:           WorkspaceCreate,
+				Template:         template,
+				NewWorkspaceName: name,
+
+				RichParameterFile: parameterFlags.richParameterFile,
+				RichParameters:    cliRichParameters,
+			})
+			if err != nil {
+				return xerrors.Errorf("prepare build: %w", err)
+			}
+
+			workspace, err = client.CreateWorkspace(ctx, organization.ID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+				TemplateID:          template.ID,
+				Name:                name,
+				RichParameterValues: richParameters,
+			})
+			if err != nil {
+				return xerrors.Errorf("create workspace: %w", err)
+			}
+
+			err = cliui.WorkspaceBuild(ctx, inv.Stdout, client, workspace.LatestBuild.ID)
+			if err != nil {
+				return xerrors.Errorf("watch build: %w", err)
+			}
+
+			build, err := client.WorkspaceBuild(ctx, workspace.LatestBuild.ID)
+			if err != nil {
+				return xerrors.Errorf("get workspace build: %w", err)
+			}
+			var agents []codersdk.WorkspaceAgent
+			for _, resource := range build.Resources {
+				agents = append(agents, resource.Agents...)
+			}
+			for _, agent := range agents {
+				err = cliui.Agent(ctx, inv.Stdout, agent.ID, cliui.AgentOptions{
+					Fetch:     client.WorkspaceAgent,
+					FetchLogs: client.WorkspaceAgentLogsAfter,
+					Wait:      true,
+				})
+				if err != nil {
+					return xerrors.Errorf("await agent %q: %w", agent.Name, err)
+				}
+				// The startup script failing doesn't fail the wait.
+				started, err := client.WorkspaceAgent(ctx, agent.ID)
+				if err != nil {
+					return xerrors.Errorf("get agent %q: %w", agent.Name, err)
+				}
+				if started.LifecycleState != codersdk.WorkspaceAgentLifecycleReady {
+					return xerrors.Errorf("agent %q did not start: %s", agent.Name, started.LifecycleState)
+				}
+			}
+
+			if len(assertions) > 0 {
+				err = r.runTemplateTestAssertions(ctx, inv, client, agents, agentName, assertions)
+				if err != nil {
+					return err
+				}
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "\nThe template test passed at %s!\n", cliui.DefaultStyles.DateTimeStamp.Render(time.Now().Format(time.Stamp)))
+			return nil
+		},
+	}
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:          "directory",
+			FlagShorthand: "d",
+			Description:   "Specify the directory of the template to test, use '-' to read tar from stdin.",
+			Default:       ".",
+			Value:         clibase.StringOf(&uploadFlags.directory),
+		},
+		{
+			Flag:        "assert",
+			Description: "Specify a command to run in the workspace once its agents started. The test fails if the command exits with a non-zero status.",
+			Value:       clibase.StringArrayOf(&assertions),
+		},
+		{
+			Flag:        "agent",
+			Description: "Specify the name of the agent to run the assertions in. Required if the workspace has more than one agent.",
+			Value:       clibase.StringOf(&agentName),
+		},
+		{
+			Flag:        "timeout",
+			Description: "Specify how long to wait for the workspace to build, its agents to start, and the assertions to run.",
+			Default:     "30m",
+			Value:       clibase.DurationOf(&timeout),
+		},
+		{
+			Flag:        "keep",
+			Description: "Keep the template and workspace after the test, e.g. to debug a failure. Delete them with 'coder delete' and 'coder templates delete'.",
+			Value:       clibase.BoolOf(&keep),
+		},
+		{
+			Flag:        "variables-file",
+			Description: "Specify a file path with values for Terraform-managed variables.",
+			Value:       clibase.StringOf(&variablesFile),
+		},
+		{
+			Flag:        "variable",
+			Description: "Specify a set of values for Terraform-managed variables.",
+			Value:       clibase.StringArrayOf(&variables),
+		},
+		{
+			Flag:        "provisioner-tag",
+			Description: "Specify a set of tags to target provisioner daemons.",
+			Value:       clibase.StringArrayOf(&provisionerTags),
+		},
+		{
+			Flag:        "provisioner",
+			Description: "The provisioner that runs the template: terraform, or the name of a provisioner plugin served by external provisioner daemons.",
+			Default:     "terraform",
+			Value:       clibase.StringOf(&provisioner),
+		},
+		cliui.SkipPromptOption(),
+	}
+	cmd.Options = append(cmd.Options, parameterFlags.cliParameters()...)
+	return cmd
+}
+
+// runTemplateTestAssertions runs each assertion in the agent over SSH, and
+// returns an error for the first one that fails.
+func (r *RootCmd) runTemplateTestAssertions(ctx context.Context, inv *clibase.Invocation, client *codersdk.Client, agents []codersdk.WorkspaceAgent, agentName string, assertions []string) error {
+	var agent codersdk.WorkspaceAgent
+	switch {
+	case agentName != "":
+		for _, a := range agents {
+			if a.Name == agentName {
+				agent = a
+			}
+		}
+		if agent.ID == uuid.Nil {
+			return xerrors.Errorf("agent not found by name %q", agentName)
+		}
+	case len(agents) == 1:
+		agent = agents[0]
+	case len(agents) == 0:
+		return xerrors.New("the workspace has no agents to run assertions in")
+	default:
+		return xerrors.New("the workspace has more than one agent, specify the agent to run assertions in with --agent")
+	}
+
+	logger, ok := LoggerFromContext(ctx)
+	if !ok {
+		logger = slog.Make(sloghuman.Sink(inv.Stderr))
+	}
+	if r.verbose {
+		logger = logger.Leveled(slog.LevelDebug)
+	}
+	conn, err := client.DialWorkspaceAgent(ctx, agent.ID, &codersdk.DialWorkspaceAgentOptions{
+		Logger: logger,
+	})
+	if err != nil {
+		return xerrors.Errorf("dial workspace agent: %w", err)
+	}
+	defer conn.Close()
+	if !conn.AwaitReachable(ctx) {
+		return xerrors.Errorf("workspace agent not reachable in time: %w", ctx.Err())
+	}
+	sshClient, err := conn.SSHClient(ctx)
+	if err != nil {
+		return xerrors.Errorf("ssh client: %w", err)
+	}
+	defer sshClient.Close()
+
+	for _, assertion := range assertions {
+		_, _ = fmt.Fprintf(inv.Stdout, "\n%s %s\n", cliui.DefaultStyles.Prompt, cliui.DefaultStyles.Code.Render(assertion))
+		err := func() error { // Use func because of defer in for loop.
+			sess, err := sshClient.NewSession()
+			if err != nil {
+				return xerrors.Errorf("ssh session: %w", err)
+			}
+			defer sess.Close()
+			sess.Stdout = inv.Stdout
+			sess.Stderr = inv.Stderr
+			return sess.Run(assertion)
+		}()
+		if err != nil {
+			return xerrors.Errorf("assertion %q failed: %w", assertion, err)
+		}
+	}
+	return nil
+}
+
+// cleanupTemplateTest deletes the workspace and template that were created by
+// a template test. It uses the context of the invocation instead of the
+// context of the test, which may have timed out. A build that is still
+// running, e.g. because the test timed out, is canceled first since the
+// workspace can't be deleted while it runs.
+func cleanupTemplateTest(inv *clibase.Invocation, client *codersdk.Client, template codersdk.Template, workspace codersdk.Workspace) error {
+	ctx := inv.Context()
+	_, _ = fmt.Fprintln(inv.Stdout, "\nCleaning up...")
+	if workspace.ID != uuid.Nil {
+		latest, err := client.Workspace(ctx, workspace.ID)
+		if err != nil {
+			return xerrors.Errorf("get workspace: %w", err)
+		}
+		switch latest.LatestBuild.Job.Status {
+		case codersdk.ProvisionerJobPending, codersdk.ProvisionerJobRunning:
+			err = client.CancelWorkspaceBuild(ctx, latest.LatestBuild.ID)
+			if err != nil {
+				return xerrors.Errorf("cancel workspace build: %w", err)
+			}
+			fallthrough
+		case codersdk.ProvisionerJobCanceling:
+			// The build ends as canceled or failed, either way the workspace
+			// can be deleted once it's done.
+			err = cliui.WorkspaceBuild(ctx, inv.Stdout, client, latest.LatestBuild.ID)
+			if err != nil && ctx.Err() != nil {
+				return xerrors.Errorf("wait for canceled build: %w", err)
+			}
+		}
+
+		build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionDelete,
+		})
+		if err != nil {
+			return xerrors.Errorf("delete workspace: %w", err)
+		}
+		err = cliui.WorkspaceBuild(ctx, inv.Stdout, client, build.ID)
+		if err != nil {
+			return xerrors.Errorf("watch delete build: %w", err)
+		}
+	}
+	err := client.DeleteTemplate(ctx, template.ID)
+	if err != nil {
+		return xerrors.Errorf("delete template: %w", err)
+	}
+	return nil
+}
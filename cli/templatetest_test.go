@@ -0,0 +1,96 @@
+package cli_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateTest(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Passes", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		owner := coderdtest.CreateFirstUser(t, client)
+		source := clitest.CreateTemplateVersionSource(t, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionApply: echo.ProvisionComplete,
+		})
+
+		inv, root := clitest.New(t, "templates", "test",
+			"--directory", source,
+			"--provisioner", string(database.ProvisionerTypeEcho),
+			"--yes",
+		)
+		clitest.SetupConfig(t, client, root)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+
+		// The template and workspace are deleted after the test.
+		templates, err := client.TemplatesByOrganization(ctx, owner.OrganizationID)
+		require.NoError(t, err)
+		require.Empty(t, templates)
+		workspaces, err := client.Workspaces(ctx, codersdk.WorkspaceFilter{})
+		require.NoError(t, err)
+		require.Empty(t, workspaces.Workspaces)
+	})
+
+	t.Run("BuildFails", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		coderdtest.CreateFirstUser(t, client)
+		source := clitest.CreateTemplateVersionSource(t, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionPlan:  echo.ProvisionComplete,
+			ProvisionApply: echo.ProvisionFailed,
+		})
+
+		inv, root := clitest.New(t, "templates", "test",
+			"--directory", source,
+			"--provisioner", string(database.ProvisionerTypeEcho),
+			"--yes",
+		)
+		clitest.SetupConfig(t, client, root)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		err := inv.WithContext(ctx).Run()
+		require.ErrorContains(t, err, "watch build")
+	})
+
+	t.Run("AssertWithoutAgent", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		owner := coderdtest.CreateFirstUser(t, client)
+		source := clitest.CreateTemplateVersionSource(t, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionApply: echo.ProvisionComplete,
+		})
+
+		inv, root := clitest.New(t, "templates", "test",
+			"--directory", source,
+			"--provisioner", string(database.ProvisionerTypeEcho),
+			"--assert", "true",
+			"--yes",
+		)
+		clitest.SetupConfig(t, client, root)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		err := inv.WithContext(ctx).Run()
+		require.ErrorContains(t, err, "no agents")
+
+		// The template is deleted even if the test fails.
+		templates, err := client.TemplatesByOrganization(ctx, owner.OrganizationID)
+		require.NoError(t, err)
+		require.Empty(t, templates)
+	})
+}
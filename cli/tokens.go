@@ -48,6 +48,7 @@ func (r *RootCmd) createToken() *clibase.Cmd {
 	var (
 		tokenLifetime time.Duration
 		name          string
+		scope         string
 	)
 	client := new(codersdk.Client)
 	cmd := &clibase.Cmd{
@@ -60,6 +61,7 @@ func (r *RootCmd) createToken() *clibase.Cmd {
 		Handler: func(inv *clibase.Invocation) error {
 			res, err := client.CreateToken(inv.Context(), codersdk.Me, codersdk.CreateTokenRequest{
 				Lifetime:  tokenLifetime,
+				Scope:     codersdk.APIKeyScope(scope),
 				TokenName: name,
 			})
 			if err != nil {
@@ -87,6 +89,19 @@ func (r *RootCmd) createToken() *clibase.Cmd {
 			Description:   "Specify a human-readable name.",
 			Value:         clibase.StringOf(&name),
 		},
+		{
+			Flag:        "scope",
+			Env:         "CODER_TOKEN_SCOPE",
+			Description: "Restrict what the token can be used for, e.g. workspace:ssh for IDE plugins that only connect to workspaces.",
+			Default:     string(codersdk.APIKeyScopeAll),
+			Value: clibase.EnumOf(&scope,
+				string(codersdk.APIKeyScopeAll),
+				string(codersdk.APIKeyScopeWorkspaceRead),
+				string(codersdk.APIKeyScopeWorkspaceSSH),
+				string(codersdk.APIKeyScopeWorkspaceApps),
+				string(codersdk.APIKeyScopeTemplateRead),
+			),
+		},
 	}
 
 	return cmd
@@ -100,6 +115,7 @@ type tokenListRow struct {
 	// For table format:
 	ID        string    `json:"-" table:"id,default_sort"`
 	TokenName string    `json:"token_name" table:"name"`
+	Scope     string    `json:"-" table:"scope"`
 	LastUsed  time.Time `json:"-" table:"last used"`
 	ExpiresAt time.Time `json:"-" table:"expires at"`
 	CreatedAt time.Time `json:"-" table:"created at"`
@@ -111,6 +127,7 @@ func tokenListRowFromToken(token codersdk.APIKeyWithOwner) tokenListRow {
 		APIKey:    token.APIKey,
 		ID:        token.ID,
 		TokenName: token.TokenName,
+		Scope:     string(token.Scope),
 		LastUsed:  token.LastUsed,
 		ExpiresAt: token.ExpiresAt,
 		CreatedAt: token.CreatedAt,
@@ -120,7 +137,7 @@ func tokenListRowFromToken(token codersdk.APIKeyWithOwner) tokenListRow {
 
 func (r *RootCmd) listTokens() *clibase.Cmd {
 	// we only display the 'owner' column if the --all argument is passed in
-	defaultCols := []string{"id", "name", "last used", "expires at", "created at"}
+	defaultCols := []string{"id", "name", "scope", "last used", "expires at", "created at"}
 	if slices.Contains(os.Args, "-a") || slices.Contains(os.Args, "--all") {
 		defaultCols = append(defaultCols, "owner")
 	}
@@ -73,7 +73,8 @@ func relative(d time.Duration) string {
 	return "now"
 }
 
-// parseCLISchedule parses a schedule in the format HH:MM{AM|PM} [DOW] [LOCATION]
+// parseCLISchedule parses a schedule in the format HH:MM{AM|PM} [DOW] [LOCATION],
+// or a human-readable schedule such as "weekdays 9am Europe/Berlin".
 func parseCLISchedule(parts ...string) (*schedule.Schedule, error) {
 	// If the user was careful and quoted the schedule, un-quote it.
 	// In the case that only time was specified, this will be a no-op.
@@ -84,7 +85,13 @@ func parseCLISchedule(parts ...string) (*schedule.Schedule, error) {
 	dayOfWeek := "*"
 	t, err := parseTime(parts[0])
 	if err != nil {
-		return nil, err
+		// Fall back to human-readable schedules such as "weekdays 9am
+		// Europe/Berlin", which may start with the days of the week.
+		sched, humanErr := schedule.Human(strings.Join(parts, " "), localLocation())
+		if humanErr != nil {
+			return nil, xerrors.Errorf("%w, or the schedule must be in the format %q: %s", err, "weekdays 9am Europe/Berlin", humanErr.Error())
+		}
+		return sched, nil
 	}
 	hour, minute := t.Hour(), t.Minute()
 
@@ -115,10 +122,7 @@ func parseCLISchedule(parts ...string) (*schedule.Schedule, error) {
 
 	// If location was not specified, attempt to automatically determine it as a last resort.
 	if loc == nil {
-		loc, err = tz.TimezoneIANA()
-		if err != nil {
-			loc = time.UTC
-		}
+		loc = localLocation()
 	}
 
 	sched, err := schedule.Weekly(fmt.Sprintf(
@@ -129,6 +133,10 @@ func parseCLISchedule(parts ...string) (*schedule.Schedule, error) {
 		dayOfWeek,
 	))
 	if err != nil {
+		// The day of week may be human-readable, e.g. "9am weekdays".
+		if sched, humanErr := schedule.Human(strings.Join(parts, " "), loc); humanErr == nil {
+			return sched, nil
+		}
 		// This will either be an invalid dayOfWeek or an invalid timezone.
 		return nil, xerrors.Errorf("Invalid schedule: %w", err)
 	}
@@ -156,6 +164,16 @@ func isDigit(s string) bool {
 	}) == -1
 }
 
+// localLocation returns the IANA location of the local timezone, or UTC if it
+// cannot be determined.
+func localLocation() *time.Location {
+	loc, err := tz.TimezoneIANA()
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 // parseTime attempts to parse a time (no date) from the given string using a number of layouts.
 func parseTime(s string) (time.Time, error) {
 	// Try a number of possible layouts.
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"github.com/coder/coder/cli/clibase"
+)
+
+func (r *RootCmd) workspaces() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Short: "Manage workspaces",
+		Long: "Most workspace commands are also available at the top level, e.g. \"coder stop\" is the same as \"coder workspaces stop\".\n\n" + formatExamples(
+			example{
+				Description: "Stop all of your workspaces",
+				Command:     "coder workspaces stop --all",
+			},
+		),
+		Use: "workspaces [subcommand]",
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.stop(),
+		},
+	}
+	return cmd
+}
@@ -140,6 +140,13 @@ func main() {
 								if count%5 == 0 {
 									log.Level = codersdk.LogLevelWarn
 								}
+								if count > 40 && count < 50 {
+									log.Progress = &codersdk.ProvisionerJobLogProgress{
+										Stage:     codersdk.ProvisionerJobLogStageApply,
+										Completed: int32(count - 40),
+										Total:     9,
+									}
+								}
 								count++
 								if log.Output == "" && log.Stage == "" {
 									continue
@@ -0,0 +1,489 @@
+// Package acmecert issues and renews the certificate of the wildcard access URL
+// over ACME with DNS-01 challenges. Certificates are encrypted and persisted in
+// a Store that is shared by all replicas serving the domain, and issued while
+// holding a lock of the store, so only one of them issues a certificate.
+package acmecert
+
+import (
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+const (
+	// DefaultRenewBefore is how long before it expires a certificate is
+	// renewed. Let's Encrypt recommends renewing 30 days before expiry.
+	DefaultRenewBefore = 30 * 24 * time.Hour
+	// DefaultCheckInterval is how often the store is checked for a newer
+	// certificate, and the certificate is renewed if needed.
+	DefaultCheckInterval = time.Hour
+)
+
+// ErrNotFound is returned by a Store that has no certificate for a domain.
+var ErrNotFound = xerrors.New("certificate not found")
+
+// Store persists the encrypted certificate of a domain.
+type Store interface {
+	// Load returns the data saved for the domain, or ErrNotFound.
+	Load(ctx context.Context, domain string) ([]byte, error)
+	// Save replaces the data saved for the domain.
+	Save(ctx context.Context, domain string, data []byte, notAfter time.Time) error
+	// Lock runs fn while holding a lock on the domain that is shared by all
+	// replicas serving it. fn must use the store it is passed.
+	Lock(ctx context.Context, domain string, fn func(store Store) error) error
+}
+
+// DNSProvider creates and deletes the TXT records of DNS-01 challenges.
+type DNSProvider interface {
+	// Present creates a TXT record with the value. It must only return once
+	// the record can be resolved by the ACME server.
+	Present(ctx context.Context, fqdn, value string) error
+	// CleanUp deletes the TXT record created by Present.
+	CleanUp(ctx context.Context, fqdn, value string) error
+}
+
+type Options struct {
+	Logger slog.Logger
+	// Domain is the wildcard domain to issue the certificate for, e.g.
+	// "*.apps.coder.com".
+	Domain string
+	// DirectoryURL is the directory URL of the ACME server.
+	DirectoryURL string
+	// Email is the contact of the ACME account. Optional.
+	Email string
+	DNS   DNSProvider
+	Store Store
+	// EncryptionKey is the 32 byte AES-256 key that certificates are
+	// encrypted with in the store.
+	EncryptionKey []byte
+
+	// RenewBefore defaults to DefaultRenewBefore.
+	RenewBefore time.Duration
+	// CheckInterval defaults to DefaultCheckInterval.
+	CheckInterval time.Duration
+}
+
+// Manager keeps the certificate of a wildcard domain valid. The certificate is
+// hot-reloaded, so it can be served from a TLS listener that was started
+// before the certificate was issued.
+type Manager struct {
+	opts Options
+	aead cipher.AEAD
+	// suffix is the domain without the leading asterisk, e.g.
+	// ".apps.coder.com".
+	suffix string
+
+	cert atomic.Pointer[tls.Certificate]
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New starts a manager that loads the certificate of the domain from the
+// store, and issues a new one if there is none or it expires soon.
+func New(ctx context.Context, opts Options) (*Manager, error) {
+	if !strings.HasPrefix(opts.Domain, "*.") || strings.Count(opts.Domain, "*") != 1 {
+		return nil, xerrors.Errorf("certificates can only be issued for wildcard domains like %q, got %q", "*.apps.coder.com", opts.Domain)
+	}
+	if opts.DirectoryURL == "" {
+		return nil, xerrors.New("ACME directory URL is required")
+	}
+	if opts.DNS == nil {
+		return nil, xerrors.New("DNS provider is required")
+	}
+	if opts.Store == nil {
+		return nil, xerrors.New("store is required")
+	}
+	if len(opts.EncryptionKey) != 32 {
+		return nil, xerrors.Errorf("encryption key must be 32 bytes, got %d", len(opts.EncryptionKey))
+	}
+	if opts.RenewBefore <= 0 {
+		opts.RenewBefore = DefaultRenewBefore
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = DefaultCheckInterval
+	}
+
+	block, err := aes.NewCipher(opts.EncryptionKey)
+	if err != nil {
+		return nil, xerrors.Errorf("create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, xerrors.Errorf("create GCM: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	m := &Manager{
+		opts:   opts,
+		aead:   aead,
+		suffix: strings.TrimPrefix(opts.Domain, "*"),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	m.wg.Add(1)
+	go m.run()
+	return m, nil
+}
+
+// Certificate returns the current certificate, or nil if none was issued yet.
+func (m *Manager) Certificate() *tls.Certificate {
+	return m.cert.Load()
+}
+
+// WrapGetCertificate returns a tls.Config.GetCertificate func that serves the
+// certificate of the domain to clients that request a hostname matching the
+// domain. All other clients, and all clients until the first certificate was
+// issued, are served by next.
+func (m *Manager) WrapGetCertificate(next func(*tls.ClientHelloInfo) (*tls.Certificate, error)) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		if m.matches(hello.ServerName) {
+			if cert := m.cert.Load(); cert != nil {
+				return cert, nil
+			}
+		}
+		if next == nil {
+			return nil, nil //nolint:nilnil
+		}
+		return next(hello)
+	}
+}
+
+// matches returns true if the wildcard domain covers the hostname. Like in
+// certificates, the wildcard only covers a single label.
+func (m *Manager) matches(hostname string) bool {
+	label, ok := strings.CutSuffix(strings.ToLower(hostname), m.suffix)
+	return ok && label != "" && !strings.Contains(label, ".")
+}
+
+// Close stops renewing the certificate.
+func (m *Manager) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	return nil
+}
+
+func (m *Manager) run() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.opts.CheckInterval)
+	defer ticker.Stop()
+	for {
+		err := m.refresh(m.ctx)
+		if err != nil && m.ctx.Err() == nil {
+			m.opts.Logger.Error(m.ctx, "refresh ACME certificate",
+				slog.F("domain", m.opts.Domain),
+				slog.Error(err),
+			)
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// storedCertificate is the data saved in the store, before it's encrypted.
+type storedCertificate struct {
+	// AccountKey is the PKCS #8 key of the ACME account the certificate was
+	// issued with. It's reused on renewal.
+	AccountKey []byte `json:"account_key"`
+	// Certificate is the DER certificate chain, leaf first.
+	Certificate [][]byte `json:"certificate"`
+	// PrivateKey is the PKCS #8 key of the certificate.
+	PrivateKey []byte `json:"private_key"`
+}
+
+// refresh serves the certificate in the store, and issues a new one if there
+// is none or it expires soon. Certificates are issued while holding the lock of
+// the store, so replicas that start at the same time don't each issue one.
+func (m *Manager) refresh(ctx context.Context) error {
+	stored, err := m.load(ctx, m.opts.Store)
+	if err != nil {
+		return err
+	}
+	if m.serve(ctx, stored) {
+		return nil
+	}
+
+	return m.opts.Store.Lock(ctx, m.opts.Domain, func(store Store) error {
+		// Another replica may have issued a certificate while we were
+		// waiting for the lock.
+		stored, err := m.load(ctx, store)
+		if err != nil {
+			return err
+		}
+		if m.serve(ctx, stored) {
+			return nil
+		}
+
+		m.opts.Logger.Info(ctx, "issuing ACME certificate", slog.F("domain", m.opts.Domain))
+		issued, err := m.issue(ctx, stored)
+		if err != nil {
+			return xerrors.Errorf("issue certificate: %w", err)
+		}
+		cert, err := issued.tlsCertificate()
+		if err != nil {
+			return xerrors.Errorf("parse issued certificate: %w", err)
+		}
+		err = m.save(ctx, store, issued, cert.Leaf.NotAfter)
+		if err != nil {
+			return err
+		}
+		m.cert.Store(cert)
+		m.opts.Logger.Info(ctx, "issued ACME certificate",
+			slog.F("domain", m.opts.Domain),
+			slog.F("not_after", cert.Leaf.NotAfter),
+		)
+		return nil
+	})
+}
+
+// serve serves the stored certificate if it's valid. It returns true if the
+// certificate doesn't have to be renewed yet.
+func (m *Manager) serve(ctx context.Context, stored *storedCertificate) bool {
+	if stored == nil {
+		return false
+	}
+	cert, err := stored.tlsCertificate()
+	if err != nil {
+		m.opts.Logger.Warn(ctx, "stored ACME certificate is invalid, issuing a new one", slog.Error(err))
+		return false
+	}
+	m.cert.Store(cert)
+	return time.Until(cert.Leaf.NotAfter) > m.opts.RenewBefore
+}
+
+// load returns the certificate in the store, or nil if there is none.
+func (m *Manager) load(ctx context.Context, store Store) (*storedCertificate, error) {
+	data, err := store.Load(ctx, m.opts.Domain)
+	if xerrors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("load certificate: %w", err)
+	}
+	plaintext, err := decrypt(m.aead, data)
+	if err != nil {
+		// The certificate isn't replaced, as that would break the replicas
+		// that use the right key.
+		return nil, xerrors.Errorf("decrypt certificate, is the encryption key the same on all replicas? %w", err)
+	}
+	var stored storedCertificate
+	err = json.Unmarshal(plaintext, &stored)
+	if err != nil {
+		return nil, xerrors.Errorf("unmarshal certificate: %w", err)
+	}
+	return &stored, nil
+}
+
+func (m *Manager) save(ctx context.Context, store Store, stored *storedCertificate, notAfter time.Time) error {
+	plaintext, err := json.Marshal(stored)
+	if err != nil {
+		return xerrors.Errorf("marshal certificate: %w", err)
+	}
+	data, err := encrypt(m.aead, plaintext)
+	if err != nil {
+		return xerrors.Errorf("encrypt certificate: %w", err)
+	}
+	err = store.Save(ctx, m.opts.Domain, data, notAfter)
+	if err != nil {
+		return xerrors.Errorf("save certificate: %w", err)
+	}
+	return nil
+}
+
+// issue orders a new certificate for the domain. The account key of the
+// previous certificate is reused if there is one.
+func (m *Manager) issue(ctx context.Context, previous *storedCertificate) (*storedCertificate, error) {
+	var accountKey crypto.Signer
+	if previous != nil && len(previous.AccountKey) > 0 {
+		key, err := x509.ParsePKCS8PrivateKey(previous.AccountKey)
+		if err != nil {
+			return nil, xerrors.Errorf("parse account key: %w", err)
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, xerrors.Errorf("account key of type %T can't sign", key)
+		}
+		accountKey = signer
+	} else {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, xerrors.Errorf("generate account key: %w", err)
+		}
+		accountKey = key
+	}
+
+	client := &acme.Client{
+		Key:          accountKey,
+		DirectoryURL: m.opts.DirectoryURL,
+		UserAgent:    "coder",
+	}
+	account := &acme.Account{}
+	if m.opts.Email != "" {
+		account.Contact = []string{"mailto:" + m.opts.Email}
+	}
+	_, err := client.Register(ctx, account, acme.AcceptTOS)
+	if err != nil && !xerrors.Is(err, acme.ErrAccountAlreadyExists) {
+		return nil, xerrors.Errorf("register account: %w", err)
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(m.opts.Domain))
+	if err != nil {
+		return nil, xerrors.Errorf("create order: %w", err)
+	}
+	for _, authzURL := range order.AuthzURLs {
+		err = m.authorize(ctx, client, authzURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, xerrors.Errorf("wait for order: %w", err)
+	}
+
+	certKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, xerrors.Errorf("generate certificate key: %w", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		DNSNames: []string{m.opts.Domain},
+	}, certKey)
+	if err != nil {
+		return nil, xerrors.Errorf("create certificate request: %w", err)
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, xerrors.Errorf("finalize order: %w", err)
+	}
+
+	accountKeyDER, err := x509.MarshalPKCS8PrivateKey(accountKey)
+	if err != nil {
+		return nil, xerrors.Errorf("marshal account key: %w", err)
+	}
+	certKeyDER, err := x509.MarshalPKCS8PrivateKey(certKey)
+	if err != nil {
+		return nil, xerrors.Errorf("marshal certificate key: %w", err)
+	}
+	return &storedCertificate{
+		AccountKey:  accountKeyDER,
+		Certificate: chain,
+		PrivateKey:  certKeyDER,
+	}, nil
+}
+
+// authorize fulfills the DNS-01 challenge of an authorization.
+func (m *Manager) authorize(ctx context.Context, client *acme.Client, authzURL string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return xerrors.Errorf("get authorization: %w", err)
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "dns-01" {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return xerrors.Errorf("ACME server offered no dns-01 challenge for %q", authz.Identifier.Value)
+	}
+	value, err := client.DNS01ChallengeRecord(challenge.Token)
+	if err != nil {
+		return xerrors.Errorf("compute challenge record: %w", err)
+	}
+
+	// Wildcard identifiers are validated against the record of the base
+	// domain.
+	fqdn := "_acme-challenge." + strings.TrimPrefix(authz.Identifier.Value, "*.")
+	err = m.opts.DNS.Present(ctx, fqdn, value)
+	if err != nil {
+		return xerrors.Errorf("present DNS record %q: %w", fqdn, err)
+	}
+	defer func() {
+		// The record is cleaned up even if the context is canceled.
+		cleanupCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		err := m.opts.DNS.CleanUp(cleanupCtx, fqdn, value)
+		if err != nil {
+			m.opts.Logger.Warn(ctx, "clean up DNS record",
+				slog.F("fqdn", fqdn),
+				slog.Error(err),
+			)
+		}
+	}()
+
+	_, err = client.Accept(ctx, challenge)
+	if err != nil {
+		return xerrors.Errorf("accept challenge: %w", err)
+	}
+	_, err = client.WaitAuthorization(ctx, authz.URI)
+	if err != nil {
+		return xerrors.Errorf("wait for authorization of %q: %w", authz.Identifier.Value, err)
+	}
+	return nil
+}
+
+func (s *storedCertificate) tlsCertificate() (*tls.Certificate, error) {
+	if len(s.Certificate) == 0 {
+		return nil, xerrors.New("certificate chain is empty")
+	}
+	leaf, err := x509.ParseCertificate(s.Certificate[0])
+	if err != nil {
+		return nil, xerrors.Errorf("parse certificate: %w", err)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(s.PrivateKey)
+	if err != nil {
+		return nil, xerrors.Errorf("parse private key: %w", err)
+	}
+	return &tls.Certificate{
+		Certificate: s.Certificate,
+		PrivateKey:  key,
+		Leaf:        leaf,
+	}, nil
+}
+
+// encrypt seals the plaintext with a random nonce, which is prepended to the
+// ciphertext.
+func encrypt(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(aead cipher.AEAD, data []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, xerrors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
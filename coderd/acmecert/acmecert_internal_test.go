@@ -0,0 +1,618 @@
+package acmecert
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ServesStoredCertificate", func(t *testing.T) {
+		t.Parallel()
+
+		key := testKey(t)
+		store := &memoryStore{}
+		saveCertificate(t, store, key, time.Now().Add(90*24*time.Hour))
+
+		m := newTestManager(t, store, key)
+		require.Eventually(t, func() bool {
+			return m.Certificate() != nil
+		}, testutil.WaitShort, testutil.IntervalFast)
+
+		fallback := &tls.Certificate{}
+		getCertificate := m.WrapGetCertificate(func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return fallback, nil
+		})
+		for hostname, matches := range map[string]bool{
+			"app--agent--workspace--user.apps.coder.com": true,
+			"APP.APPS.CODER.COM":                         true,
+			"apps.coder.com":                             false,
+			".apps.coder.com":                            false,
+			"a.b.apps.coder.com":                         false,
+			"coder.com":                                  false,
+			"app.apps.coder.com.evil.com":                false,
+		} {
+			cert, err := getCertificate(&tls.ClientHelloInfo{ServerName: hostname})
+			require.NoError(t, err)
+			if matches {
+				require.Equal(t, m.Certificate(), cert, hostname)
+			} else {
+				require.Equal(t, fallback, cert, hostname)
+			}
+		}
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		t.Parallel()
+
+		store := &memoryStore{}
+		saveCertificate(t, store, testKey(t), time.Now().Add(90*24*time.Hour))
+		data := store.data
+
+		m := newTestManager(t, store, testKey(t))
+		err := m.refresh(testutil.Context(t, testutil.WaitShort))
+		require.ErrorContains(t, err, "decrypt certificate")
+		require.Nil(t, m.Certificate())
+		// The certificate of replicas with the right key isn't replaced.
+		require.Equal(t, data, store.data)
+	})
+
+	t.Run("ExpiringCertificateServedUntilRenewed", func(t *testing.T) {
+		t.Parallel()
+
+		key := testKey(t)
+		store := &memoryStore{}
+		saveCertificate(t, store, key, time.Now().Add(24*time.Hour))
+
+		m := newTestManager(t, store, key)
+		// The ACME server fails, so the expiring certificate is still
+		// served.
+		err := m.refresh(testutil.Context(t, testutil.WaitShort))
+		require.ErrorContains(t, err, "issue certificate")
+		require.NotNil(t, m.Certificate())
+	})
+}
+
+func TestIssue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		dns := &memoryDNS{}
+		ca := newFakeACME(t, dns)
+		store := &memoryStore{}
+		m := newIssuingTestManager(t, ca, dns, store, testKey(t))
+
+		require.Eventually(t, func() bool {
+			return m.Certificate() != nil
+		}, testutil.WaitLong, testutil.IntervalFast)
+		cert := m.Certificate()
+		require.Equal(t, []string{"*.apps.coder.com"}, cert.Leaf.DNSNames)
+		require.NoError(t, cert.Leaf.CheckSignatureFrom(ca.cert))
+		require.NotNil(t, store.data)
+		// The challenge record is deleted once the domain is validated.
+		require.Empty(t, dns.records())
+	})
+
+	t.Run("Renew", func(t *testing.T) {
+		t.Parallel()
+
+		key := testKey(t)
+		dns := &memoryDNS{}
+		ca := newFakeACME(t, dns)
+		store := &memoryStore{}
+		saveCertificate(t, store, key, time.Now().Add(24*time.Hour))
+		m := newIssuingTestManager(t, ca, dns, store, key)
+
+		require.Eventually(t, func() bool {
+			cert := m.Certificate()
+			return cert != nil && cert.Leaf.CheckSignatureFrom(ca.cert) == nil
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, 1, ca.orderCount())
+	})
+
+	t.Run("OneReplicaIssues", func(t *testing.T) {
+		t.Parallel()
+
+		key := testKey(t)
+		dns := &memoryDNS{}
+		ca := newFakeACME(t, dns)
+		store := &memoryStore{}
+		managers := make([]*Manager, 3)
+		for i := range managers {
+			managers[i] = newIssuingTestManager(t, ca, dns, store, key)
+		}
+
+		for _, m := range managers {
+			m := m
+			require.Eventually(t, func() bool {
+				return m.Certificate() != nil
+			}, testutil.WaitLong, testutil.IntervalFast)
+		}
+		// The replicas that waited for the lock serve the certificate
+		// issued by the first one.
+		require.Equal(t, 1, ca.orderCount())
+		for _, m := range managers {
+			require.Equal(t, managers[0].Certificate().Leaf.SerialNumber, m.Certificate().Leaf.SerialNumber)
+		}
+	})
+
+	t.Run("ChallengeFailed", func(t *testing.T) {
+		t.Parallel()
+
+		ca := newFakeACME(t, &memoryDNS{})
+		// The record is never created, so the domain can't be validated.
+		m := newIssuingTestManager(t, ca, ExecDNSProvider{Path: "true"}, &memoryStore{}, testKey(t))
+		err := m.refresh(testutil.Context(t, testutil.WaitLong))
+		require.ErrorContains(t, err, "wait for authorization")
+		require.Nil(t, m.Certificate())
+	})
+}
+
+func TestEncrypt(t *testing.T) {
+	t.Parallel()
+
+	aead := testAEAD(t, testKey(t))
+	data, err := encrypt(aead, []byte("hello"))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "hello")
+
+	plaintext, err := decrypt(aead, data)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(plaintext))
+
+	_, err = decrypt(testAEAD(t, testKey(t)), data)
+	require.Error(t, err)
+	_, err = decrypt(aead, data[:4])
+	require.Error(t, err)
+}
+
+func newTestManager(t *testing.T, store Store, key []byte) *Manager {
+	t.Helper()
+
+	// The ACME server fails every request, so certificates are never issued.
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(srv.Close)
+
+	m, err := New(context.Background(), Options{
+		Logger:        slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}),
+		Domain:        "*.apps.coder.com",
+		DirectoryURL:  srv.URL,
+		DNS:           ExecDNSProvider{Path: "false"},
+		Store:         store,
+		EncryptionKey: key,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = m.Close()
+	})
+	return m
+}
+
+func newIssuingTestManager(t *testing.T, ca *fakeACME, dns DNSProvider, store Store, key []byte) *Manager {
+	t.Helper()
+
+	m, err := New(context.Background(), Options{
+		Logger:        slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}),
+		Domain:        "*.apps.coder.com",
+		DirectoryURL:  ca.srv.URL + "/directory",
+		DNS:           dns,
+		Store:         store,
+		EncryptionKey: key,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = m.Close()
+	})
+	return m
+}
+
+func saveCertificate(t *testing.T, store Store, key []byte, notAfter time.Time) {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		DNSNames:     []string{"*.apps.coder.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &privateKey.PublicKey, privateKey)
+	require.NoError(t, err)
+	privateKeyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	plaintext, err := json.Marshal(storedCertificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  privateKeyDER,
+	})
+	require.NoError(t, err)
+	data, err := encrypt(testAEAD(t, key), plaintext)
+	require.NoError(t, err)
+	err = store.Save(context.Background(), "*.apps.coder.com", data, notAfter)
+	require.NoError(t, err)
+}
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+	return key
+}
+
+func testAEAD(t *testing.T, key []byte) cipher.AEAD {
+	t.Helper()
+
+	block, err := aes.NewCipher(key)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	return aead
+}
+
+type memoryStore struct {
+	lock sync.Mutex
+	mu   sync.Mutex
+	data []byte
+}
+
+func (s *memoryStore) Lock(_ context.Context, _ string, fn func(store Store) error) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return fn(s)
+}
+
+func (s *memoryStore) Load(_ context.Context, _ string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data == nil {
+		return nil, ErrNotFound
+	}
+	return s.data, nil
+}
+
+func (s *memoryStore) Save(_ context.Context, _ string, data []byte, _ time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = data
+	return nil
+}
+
+type memoryDNS struct {
+	mu      sync.Mutex
+	entries map[string]string
+}
+
+func (d *memoryDNS) Present(_ context.Context, fqdn, value string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.entries == nil {
+		d.entries = map[string]string{}
+	}
+	d.entries[fqdn] = value
+	return nil
+}
+
+func (d *memoryDNS) CleanUp(_ context.Context, fqdn, _ string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.entries, fqdn)
+	return nil
+}
+
+func (d *memoryDNS) lookup(fqdn string) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.entries[fqdn]
+}
+
+func (d *memoryDNS) records() map[string]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	records := map[string]string{}
+	for fqdn, value := range d.entries {
+		records[fqdn] = value
+	}
+	return records
+}
+
+// fakeACME is a minimal RFC 8555 server that validates DNS-01 challenges
+// against a memoryDNS and signs certificates with its own CA. Signatures of
+// requests aren't verified.
+type fakeACME struct {
+	t    *testing.T
+	srv  *httptest.Server
+	dns  *memoryDNS
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+
+	mu         sync.Mutex
+	nonce      int
+	thumbprint string
+	orders     []*fakeOrder
+}
+
+type fakeOrder struct {
+	domain string
+	token  string
+	// validated is set once the challenge was attempted, valid is set if
+	// it succeeded.
+	validated bool
+	valid     bool
+	cert      []byte
+}
+
+func newFakeACME(t *testing.T, dns *memoryDNS) *fakeACME {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Fake ACME CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	f := &fakeACME{t: t, dns: dns, cert: cert, key: key}
+	f.srv = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+func (f *fakeACME) orderCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.orders)
+}
+
+func (f *fakeACME) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.nonce++
+	rw.Header().Set("Replay-Nonce", fmt.Sprintf("nonce-%d", f.nonce))
+	switch r.URL.Path {
+	case "/directory":
+		f.writeJSON(rw, http.StatusOK, map[string]string{
+			"newNonce":   f.srv.URL + "/nonce",
+			"newAccount": f.srv.URL + "/account",
+			"newOrder":   f.srv.URL + "/order",
+		})
+		return
+	case "/nonce":
+		rw.WriteHeader(http.StatusOK)
+		return
+	}
+
+	var jws struct {
+		Protected string `json:"protected"`
+		Payload   string `json:"payload"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&jws)
+	if !assert.NoError(f.t, err) {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	protected, err := base64.RawURLEncoding.DecodeString(jws.Protected)
+	if !assert.NoError(f.t, err) {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if !assert.NoError(f.t, err) {
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var id int
+	switch {
+	case r.URL.Path == "/account":
+		thumbprint, err := jwkThumbprint(protected)
+		if !assert.NoError(f.t, err) {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.thumbprint = thumbprint
+		rw.Header().Set("Location", f.srv.URL+"/account/1")
+		f.writeJSON(rw, http.StatusCreated, map[string]string{"status": acme.StatusValid})
+
+	case r.URL.Path == "/order":
+		var req struct {
+			Identifiers []struct {
+				Value string `json:"value"`
+			} `json:"identifiers"`
+		}
+		err := json.Unmarshal(payload, &req)
+		if !assert.NoError(f.t, err) || !assert.Len(f.t, req.Identifiers, 1) {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.orders = append(f.orders, &fakeOrder{
+			domain: req.Identifiers[0].Value,
+			token:  fmt.Sprintf("token-%d", len(f.orders)),
+		})
+		id = len(f.orders) - 1
+		rw.Header().Set("Location", fmt.Sprintf("%s/order/%d", f.srv.URL, id))
+		f.writeJSON(rw, http.StatusCreated, f.order(id))
+
+	case f.scan(r.URL.Path, "/order/%d", &id):
+		rw.Header().Set("Location", f.srv.URL+r.URL.Path)
+		f.writeJSON(rw, http.StatusOK, f.order(id))
+
+	case f.scan(r.URL.Path, "/authz/%d", &id):
+		f.writeJSON(rw, http.StatusOK, f.authz(id))
+
+	case f.scan(r.URL.Path, "/challenge/%d", &id):
+		order := f.orders[id]
+		sum := sha256.Sum256([]byte(order.token + "." + f.thumbprint))
+		fqdn := "_acme-challenge." + strings.TrimPrefix(order.domain, "*.")
+		order.validated = true
+		order.valid = f.dns.lookup(fqdn) == base64.RawURLEncoding.EncodeToString(sum[:])
+		f.writeJSON(rw, http.StatusOK, f.authz(id)["challenges"].([]map[string]string)[0])
+
+	case f.scan(r.URL.Path, "/finalize/%d", &id):
+		var req struct {
+			CSR string `json:"csr"`
+		}
+		err := json.Unmarshal(payload, &req)
+		if !assert.NoError(f.t, err) {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		der, err := base64.RawURLEncoding.DecodeString(req.CSR)
+		if !assert.NoError(f.t, err) {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		csr, err := x509.ParseCertificateRequest(der)
+		if !assert.NoError(f.t, err) {
+			rw.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		leaf, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(int64(id) + 2),
+			DNSNames:     csr.DNSNames,
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(90 * 24 * time.Hour),
+		}, f.cert, csr.PublicKey, f.key)
+		if !assert.NoError(f.t, err) {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		f.orders[id].cert = leaf
+		rw.Header().Set("Location", fmt.Sprintf("%s/order/%d", f.srv.URL, id))
+		f.writeJSON(rw, http.StatusOK, f.order(id))
+
+	case f.scan(r.URL.Path, "/cert/%d", &id):
+		rw.Header().Set("Content-Type", "application/pem-certificate-chain")
+		rw.WriteHeader(http.StatusOK)
+		_ = pem.Encode(rw, &pem.Block{Type: "CERTIFICATE", Bytes: f.orders[id].cert})
+		_ = pem.Encode(rw, &pem.Block{Type: "CERTIFICATE", Bytes: f.cert.Raw})
+
+	default:
+		rw.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// scan parses the ID of an order from the path. Only IDs of existing orders
+// are accepted.
+func (f *fakeACME) scan(path, format string, id *int) bool {
+	_, err := fmt.Sscanf(path, format, id)
+	return err == nil && *id >= 0 && *id < len(f.orders)
+}
+
+func (f *fakeACME) order(id int) map[string]any {
+	order := f.orders[id]
+	status := acme.StatusPending
+	switch {
+	case order.cert != nil:
+		status = acme.StatusValid
+	case order.valid:
+		status = acme.StatusReady
+	case order.validated:
+		status = acme.StatusInvalid
+	}
+	return map[string]any{
+		"status":         status,
+		"identifiers":    []map[string]string{{"type": "dns", "value": order.domain}},
+		"authorizations": []string{fmt.Sprintf("%s/authz/%d", f.srv.URL, id)},
+		"finalize":       fmt.Sprintf("%s/finalize/%d", f.srv.URL, id),
+		"certificate":    fmt.Sprintf("%s/cert/%d", f.srv.URL, id),
+	}
+}
+
+func (f *fakeACME) authz(id int) map[string]any {
+	order := f.orders[id]
+	status := acme.StatusPending
+	switch {
+	case order.valid:
+		status = acme.StatusValid
+	case order.validated:
+		status = acme.StatusInvalid
+	}
+	return map[string]any{
+		"status":     status,
+		"identifier": map[string]string{"type": "dns", "value": strings.TrimPrefix(order.domain, "*.")},
+		"wildcard":   strings.HasPrefix(order.domain, "*."),
+		"challenges": []map[string]string{{
+			"type":   "dns-01",
+			"url":    fmt.Sprintf("%s/challenge/%d", f.srv.URL, id),
+			"token":  order.token,
+			"status": status,
+		}},
+	}
+}
+
+func (*fakeACME) writeJSON(rw http.ResponseWriter, status int, v any) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(v)
+}
+
+// jwkThumbprint returns the thumbprint of the P-256 key in the JWS header.
+func jwkThumbprint(protected []byte) (string, error) {
+	var header struct {
+		JWK struct {
+			X string `json:"x"`
+			Y string `json:"y"`
+		} `json:"jwk"`
+	}
+	err := json.Unmarshal(protected, &header)
+	if err != nil {
+		return "", err
+	}
+	x, err := base64.RawURLEncoding.DecodeString(header.JWK.X)
+	if err != nil {
+		return "", err
+	}
+	y, err := base64.RawURLEncoding.DecodeString(header.JWK.Y)
+	if err != nil {
+		return "", err
+	}
+	return acme.JWKThumbprint(&ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	})
+}
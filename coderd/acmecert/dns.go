@@ -0,0 +1,35 @@
+package acmecert
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// ExecDNSProvider creates and deletes DNS records by running an executable,
+// so any DNS provider can be supported with a small script. The executable is
+// called with "present" or "cleanup", the record name and the record value as
+// arguments.
+type ExecDNSProvider struct {
+	Path string
+}
+
+func (p ExecDNSProvider) Present(ctx context.Context, fqdn, value string) error {
+	return p.run(ctx, "present", fqdn, value)
+}
+
+func (p ExecDNSProvider) CleanUp(ctx context.Context, fqdn, value string) error {
+	return p.run(ctx, "cleanup", fqdn, value)
+}
+
+func (p ExecDNSProvider) run(ctx context.Context, args ...string) error {
+	//nolint:gosec // The path is configured by the administrator.
+	cmd := exec.CommandContext(ctx, p.Path, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return xerrors.Errorf("run %q %s: %w: %s", p.Path, args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package acmecert
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+)
+
+// NewDatabaseStore returns a store that keeps the certificates of the replicas
+// of the primary in the database. Workspace proxies store theirs on the
+// primary, which keys them by proxy, so they can't replace the certificate of
+// the primary even if they serve the same domain.
+func NewDatabaseStore(db database.Store) Store {
+	return &databaseStore{db: db}
+}
+
+type databaseStore struct {
+	db database.Store
+}
+
+func (s *databaseStore) Load(ctx context.Context, domain string) ([]byte, error) {
+	//nolint:gocritic // Certificates are managed by the server itself.
+	certificate, err := s.db.GetACMECertificate(dbauthz.AsSystemRestricted(ctx), database.GetACMECertificateParams{
+		WorkspaceProxyID: uuid.Nil,
+		Domain:           domain,
+	})
+	if xerrors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return certificate.Data, nil
+}
+
+func (s *databaseStore) Save(ctx context.Context, domain string, data []byte, notAfter time.Time) error {
+	//nolint:gocritic // Certificates are managed by the server itself.
+	return s.db.UpsertACMECertificate(dbauthz.AsSystemRestricted(ctx), database.UpsertACMECertificateParams{
+		WorkspaceProxyID: uuid.Nil,
+		Domain:           domain,
+		Data:             data,
+		NotAfter:         notAfter,
+		UpdatedAt:        database.Now(),
+	})
+}
+
+// Lock holds an advisory lock for the duration of fn. The lock is released
+// when the transaction ends, so it's released even if the replica goes away
+// while issuing the certificate.
+func (s *databaseStore) Lock(ctx context.Context, domain string, fn func(store Store) error) error {
+	return s.db.InTx(func(tx database.Store) error {
+		//nolint:gocritic // Certificates are managed by the server itself.
+		err := tx.AcquireLock(dbauthz.AsSystemRestricted(ctx), database.GenLockID("acme-certificate:"+domain))
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		return fn(&databaseStore{db: tx})
+	}, nil)
+}
@@ -11,11 +11,14 @@ import (
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/schedule"
 )
 
 // activityBumpWorkspace automatically bumps the workspace's auto-off timer
-// if it is set to expire soon.
-func activityBumpWorkspace(ctx context.Context, log slog.Logger, db database.Store, workspaceID uuid.UUID) {
+// if it is set to expire soon. If the template has an activity bump set, the
+// deadline is bumped by that instead of the workspace's TTL.
+func activityBumpWorkspace(ctx context.Context, log slog.Logger, db database.Store, templateScheduleStore schedule.TemplateScheduleStore, workspaceID uuid.UUID) {
 	// We set a short timeout so if the app is under load, these
 	// low priority operations fail first.
 	ctx, cancel := context.WithTimeout(ctx, time.Second*15)
@@ -48,13 +51,25 @@ func activityBumpWorkspace(ctx context.Context, log slog.Logger, db database.Sto
 			return xerrors.Errorf("get workspace: %w", err)
 		}
 
+		//nolint:gocritic // The workspace agent can't read the template.
+		templateSchedule, err := templateScheduleStore.GetForWorkspace(dbauthz.AsSystemRestricted(ctx), s, workspace)
+		if err != nil {
+			return xerrors.Errorf("get template schedule options: %w", err)
+		}
+
+		// We bump by the original TTL to prevent counter-intuitive behavior
+		// as the TTL wraps. For example, if I set the TTL to 12 hours, sign off
+		// work at midnight, come back at 10am, I would want another full day
+		// of uptime. In the prior implementation, the workspace would enter
+		// a state of always expiring 1 hour in the future
+		bumpAmount := time.Duration(workspace.Ttl.Int64)
+		if templateSchedule.ActivityBumpTTL > 0 {
+			// The template wants workspaces to stop a fixed amount of time
+			// after they were last used, regardless of their TTL.
+			bumpAmount = templateSchedule.ActivityBumpTTL
+		}
+
 		var (
-			// We bump by the original TTL to prevent counter-intuitive behavior
-			// as the TTL wraps. For example, if I set the TTL to 12 hours, sign off
-			// work at midnight, come back at 10am, I would want another full day
-			// of uptime. In the prior implementation, the workspace would enter
-			// a state of always expiring 1 hour in the future
-			bumpAmount = time.Duration(workspace.Ttl.Int64)
 			// DB writes are expensive so we only bump when 5% of the deadline
 			// has elapsed.
 			bumpEvery         = bumpAmount / 20
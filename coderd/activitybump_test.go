@@ -26,15 +26,26 @@ func TestWorkspaceActivityBump(t *testing.T) {
 
 	ctx := context.Background()
 
+	// activityBump is the template's activity bump, if zero the deadline is
+	// bumped by the workspace TTL.
+	//
 	// deadline allows you to forcibly set a max_deadline on the build. This
 	// doesn't use template restart requirements and instead edits the
 	// max_deadline on the build directly in the database.
-	setupActivityTest := func(t *testing.T, deadline ...time.Duration) (client *codersdk.Client, workspace codersdk.Workspace, assertBumped func(want bool)) {
+	setupActivityTest := func(t *testing.T, activityBump time.Duration, deadline ...time.Duration) (client *codersdk.Client, workspace codersdk.Workspace, assertBumped func(want bool)) {
 		const ttl = time.Minute
 		maxTTL := time.Duration(0)
 		if len(deadline) > 0 {
 			maxTTL = deadline[0]
 		}
+		bumpAmount := ttl
+		initialTTL := ttl
+		if activityBump > 0 {
+			bumpAmount = activityBump
+			if activityBump < ttl {
+				initialTTL = activityBump
+			}
+		}
 
 		db, pubsub := dbtestutil.NewDB(t)
 		client = coderdtest.New(t, &coderdtest.Options{
@@ -49,6 +60,7 @@ func TestWorkspaceActivityBump(t *testing.T) {
 					return schedule.TemplateScheduleOptions{
 						UserAutostopEnabled: true,
 						DefaultTTL:          ttl,
+						ActivityBumpTTL:     activityBump,
 						// We set max_deadline manually below.
 						RestartRequirement: schedule.TemplateRestartRequirement{},
 					}, nil
@@ -117,7 +129,7 @@ func TestWorkspaceActivityBump(t *testing.T) {
 		workspace, err := client.Workspace(ctx, workspace.ID)
 		require.NoError(t, err)
 		require.WithinDuration(t,
-			time.Now().Add(time.Duration(ttlMillis)*time.Millisecond),
+			time.Now().Add(initialTTL),
 			workspace.LatestBuild.Deadline.Time,
 			testutil.WaitMedium,
 		)
@@ -163,18 +175,42 @@ func TestWorkspaceActivityBump(t *testing.T) {
 
 			// If the workspace has a max deadline, the deadline must not exceed
 			// it.
-			if maxTTL != 0 && database.Now().Add(ttl).After(workspace.LatestBuild.MaxDeadline.Time) {
+			if maxTTL != 0 && database.Now().Add(bumpAmount).After(workspace.LatestBuild.MaxDeadline.Time) {
 				require.Equal(t, workspace.LatestBuild.Deadline.Time, workspace.LatestBuild.MaxDeadline.Time)
 				return
 			}
-			require.WithinDuration(t, database.Now().Add(ttl), workspace.LatestBuild.Deadline.Time, 3*time.Second)
+			require.WithinDuration(t, database.Now().Add(bumpAmount), workspace.LatestBuild.Deadline.Time, 3*time.Second)
 		}
 	}
 
 	t.Run("Dial", func(t *testing.T) {
 		t.Parallel()
 
-		client, workspace, assertBumped := setupActivityTest(t)
+		client, workspace, assertBumped := setupActivityTest(t, 0)
+
+		resources := coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
+		conn, err := client.DialWorkspaceAgent(ctx, resources[0].Agents[0].ID, &codersdk.DialWorkspaceAgentOptions{
+			Logger: slogtest.Make(t, nil),
+		})
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// Must send network traffic after a few seconds to surpass bump threshold.
+		time.Sleep(time.Second * 3)
+		sshConn, err := conn.SSHClient(ctx)
+		require.NoError(t, err)
+		_ = sshConn.Close()
+
+		assertBumped(true)
+	})
+
+	t.Run("TemplateActivityBump", func(t *testing.T) {
+		t.Parallel()
+
+		// The template's activity bump is shorter than the workspace TTL, so
+		// the workspace starts with a shorter deadline and is bumped by the
+		// activity bump rather than the TTL.
+		client, workspace, assertBumped := setupActivityTest(t, 30*time.Second)
 
 		resources := coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
 		conn, err := client.DialWorkspaceAgent(ctx, resources[0].Agents[0].ID, &codersdk.DialWorkspaceAgentOptions{
@@ -195,7 +231,7 @@ func TestWorkspaceActivityBump(t *testing.T) {
 	t.Run("NoBump", func(t *testing.T) {
 		t.Parallel()
 
-		client, workspace, assertBumped := setupActivityTest(t)
+		client, workspace, assertBumped := setupActivityTest(t, 0)
 
 		// Benign operations like retrieving workspace must not
 		// bump the deadline.
@@ -210,7 +246,7 @@ func TestWorkspaceActivityBump(t *testing.T) {
 
 		// Set the max deadline to be in 61 seconds. We bump by 1 minute, so we
 		// should expect the deadline to match the max deadline exactly.
-		client, workspace, assertBumped := setupActivityTest(t, 61*time.Second)
+		client, workspace, assertBumped := setupActivityTest(t, 0, 61*time.Second)
 
 		// Bump by dialing the workspace and sending traffic.
 		resources := coderdtest.AwaitWorkspaceAgents(t, client, workspace.ID)
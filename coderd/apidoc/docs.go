@@ -160,6 +160,58 @@ const docTemplate = `{
                 }
             }
         },
+        "/applications/oidc": {
+            "get": {
+                "tags": [
+                    "Applications"
+                ],
+                "summary": "Redirect to workspace app OIDC login",
+                "operationId": "redirect-to-workspace-app-oidc-login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Redirect destination",
+                        "name": "redirect_uri",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "307": {
+                        "description": "Temporary Redirect"
+                    }
+                }
+            }
+        },
+        "/applications/oidc/callback": {
+            "get": {
+                "tags": [
+                    "Applications"
+                ],
+                "summary": "Workspace app OIDC callback",
+                "operationId": "workspace-app-oidc-callback",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "307": {
+                        "description": "Temporary Redirect"
+                    }
+                }
+            }
+        },
         "/applications/reconnecting-pty-signed-token": {
             "post": {
                 "security": [
@@ -387,6 +439,7 @@ const docTemplate = `{
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Serves a webpage with the agents, clients and nodes known\nto the coordinator, or the same state as JSON if the request\naccepts application/json.",
                 "produces": [
                     "text/html"
                 ],
@@ -480,6 +533,179 @@ const docTemplate = `{
                 }
             }
         },
+        "/deployment/schedule/holidays": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get schedule holidays",
+                "operationId": "get-schedule-holidays",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ScheduleHoliday"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "Template restart requirements are skipped on holidays.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Create schedule holiday",
+                "operationId": "create-schedule-holiday",
+                "parameters": [
+                    {
+                        "description": "Create holiday request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateScheduleHolidayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ScheduleHoliday"
+                        }
+                    }
+                }
+            }
+        },
+        "/deployment/schedule/holidays/{holiday}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get schedule holiday",
+                "operationId": "get-schedule-holiday",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Holiday ID",
+                        "name": "holiday",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ScheduleHoliday"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Update schedule holiday",
+                "operationId": "update-schedule-holiday",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Holiday ID",
+                        "name": "holiday",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update holiday request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateScheduleHolidayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ScheduleHoliday"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Delete schedule holiday",
+                "operationId": "delete-schedule-holiday",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Holiday ID",
+                        "name": "holiday",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
         "/deployment/ssh": {
             "get": {
                 "security": [
@@ -549,6 +775,32 @@ const docTemplate = `{
                 }
             }
         },
+        "/derp/health": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "The health of the DERP regions is taken from the deployment\nhealthcheck, which is cached for the healthcheck refresh\ninterval. Server statistics are those of the DERP server\nembedded in the replica that serves the request.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Debug"
+                ],
+                "summary": "Get DERP health",
+                "operationId": "get-derp-health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.DERPHealth"
+                        }
+                    }
+                }
+            }
+        },
         "/entitlements": {
             "get": {
                 "security": [
@@ -884,7 +1136,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/insights/daus": {
+        "/insights/app-access-logs": {
             "get": {
                 "security": [
                     {
@@ -897,19 +1149,19 @@ const docTemplate = `{
                 "tags": [
                     "Insights"
                 ],
-                "summary": "Get deployment DAUs",
-                "operationId": "get-deployment-daus",
+                "summary": "Get insights about app access logs",
+                "operationId": "get-insights-about-app-access-logs",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.DAUsResponse"
+                            "$ref": "#/definitions/codersdk.AppAccessLogInsightsResponse"
                         }
                     }
                 }
             }
         },
-        "/insights/templates": {
+        "/insights/app-sessions": {
             "get": {
                 "security": [
                     {
@@ -922,19 +1174,19 @@ const docTemplate = `{
                 "tags": [
                     "Insights"
                 ],
-                "summary": "Get insights about templates",
-                "operationId": "get-insights-about-templates",
+                "summary": "Get insights about app sessions",
+                "operationId": "get-insights-about-app-sessions",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TemplateInsightsResponse"
+                            "$ref": "#/definitions/codersdk.AppSessionInsightsResponse"
                         }
                     }
                 }
             }
         },
-        "/insights/user-latency": {
+        "/insights/builds": {
             "get": {
                 "security": [
                     {
@@ -947,19 +1199,19 @@ const docTemplate = `{
                 "tags": [
                     "Insights"
                 ],
-                "summary": "Get insights about user latency",
-                "operationId": "get-insights-about-user-latency",
+                "summary": "Get insights about builds",
+                "operationId": "get-insights-about-builds",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UserLatencyInsightsResponse"
+                            "$ref": "#/definitions/codersdk.BuildInsightsResponse"
                         }
                     }
                 }
             }
         },
-        "/licenses": {
+        "/insights/daus": {
             "get": {
                 "security": [
                     {
@@ -970,47 +1222,147 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Insights"
                 ],
-                "summary": "Get licenses",
-                "operationId": "get-licenses",
+                "summary": "Get deployment DAUs",
+                "operationId": "get-deployment-daus",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.License"
-                            }
+                            "$ref": "#/definitions/codersdk.DAUsResponse"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/insights/schedules": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Organizations"
+                    "Insights"
                 ],
-                "summary": "Add new license",
-                "operationId": "add-new-license",
-                "parameters": [
-                    {
-                        "description": "Add license request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                "summary": "Get insights about schedules",
+                "operationId": "get-insights-about-schedules",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.AddLicenseRequest"
+                            "$ref": "#/definitions/codersdk.ScheduleInsightsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/insights/templates": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Insights"
+                ],
+                "summary": "Get insights about templates",
+                "operationId": "get-insights-about-templates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateInsightsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/insights/user-latency": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Insights"
+                ],
+                "summary": "Get insights about user latency",
+                "operationId": "get-insights-about-user-latency",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UserLatencyInsightsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/licenses": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get licenses",
+                "operationId": "get-licenses",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.License"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Add new license",
+                "operationId": "add-new-license",
+                "parameters": [
+                    {
+                        "description": "Add license request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.AddLicenseRequest"
                         }
                     }
                 ],
@@ -1056,6 +1408,40 @@ const docTemplate = `{
                 }
             }
         },
+        "/oauth/token-exchange": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Authorization"
+                ],
+                "summary": "Exchange identity token for API token",
+                "operationId": "exchange-identity-token-for-api-token",
+                "parameters": [
+                    {
+                        "description": "Token exchange request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TokenExchangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TokenExchangeResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/organizations": {
             "post": {
                 "security": [
@@ -1466,7 +1852,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/organizations/{organization}/templates": {
+        "/organizations/{organization}/provisionerkeys": {
             "get": {
                 "security": [
                     {
@@ -1477,10 +1863,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get templates by organization",
-                "operationId": "get-templates-by-organization",
+                "summary": "Get provisioner keys",
+                "operationId": "get-provisioner-keys",
                 "parameters": [
                     {
                         "type": "string",
@@ -1497,7 +1883,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.Template"
+                                "$ref": "#/definitions/codersdk.ProvisionerKey"
                             }
                         }
                     }
@@ -1516,39 +1902,76 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Create template by organization",
-                "operationId": "create-template-by-organization",
+                "summary": "Create provisioner key",
+                "operationId": "create-provisioner-key",
                 "parameters": [
                     {
-                        "description": "Request body",
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create provisioner key request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateTemplateRequest"
+                            "$ref": "#/definitions/codersdk.CreateProvisionerKeyRequest"
                         }
-                    },
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateProvisionerKeyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organization}/provisionerkeys/{provisionerkey}": {
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Delete provisioner key",
+                "operationId": "delete-provisioner-key",
+                "parameters": [
                     {
                         "type": "string",
+                        "format": "uuid",
                         "description": "Organization ID",
                         "name": "organization",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provisioner key name",
+                        "name": "provisionerkey",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.Template"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
         },
-        "/organizations/{organization}/templates/examples": {
+        "/organizations/{organization}/settings/budget": {
             "get": {
                 "security": [
                     {
@@ -1559,10 +1982,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get template examples by organization",
-                "operationId": "get-template-examples-by-organization",
+                "summary": "Get organization budget settings",
+                "operationId": "get-organization-budget-settings",
                 "parameters": [
                     {
                         "type": "string",
@@ -1577,30 +2000,29 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateExample"
-                            }
+                            "$ref": "#/definitions/codersdk.OrganizationBudgetSettings"
                         }
                     }
                 }
-            }
-        },
-        "/organizations/{organization}/templates/{templatename}": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Alerts are sent when the daily cost of the workspaces of the organization\ncrosses a threshold of the budget.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get templates by organization and template name",
-                "operationId": "get-templates-by-organization-and-template-name",
+                "summary": "Update organization budget settings",
+                "operationId": "update-organization-budget-settings",
                 "parameters": [
                     {
                         "type": "string",
@@ -1611,24 +2033,26 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Template name",
-                        "name": "templatename",
-                        "in": "path",
-                        "required": true
+                        "description": "Update budget settings request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateOrganizationBudgetSettingsRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Template"
+                            "$ref": "#/definitions/codersdk.OrganizationBudgetSettings"
                         }
                     }
                 }
             }
         },
-        "/organizations/{organization}/templates/{templatename}/versions/{templateversionname}": {
+        "/organizations/{organization}/settings/jit-provisioning": {
             "get": {
                 "security": [
                     {
@@ -1639,10 +2063,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get template version by organization, template, and name",
-                "operationId": "get-template-version-by-organization-template-and-name",
+                "summary": "Get organization JIT provisioning settings",
+                "operationId": "get-organization-jit-provisioning-settings",
                 "parameters": [
                     {
                         "type": "string",
@@ -1651,47 +2075,35 @@ const docTemplate = `{
                         "name": "organization",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Template name",
-                        "name": "templatename",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Template version name",
-                        "name": "templateversionname",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                            "$ref": "#/definitions/codersdk.OrganizationJITProvisioningSettings"
                         }
                     }
                 }
-            }
-        },
-        "/organizations/{organization}/templates/{templatename}/versions/{templateversionname}/previous": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Settings apply to users that log in for the first time after the update.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get previous template version by organization, template, and name",
-                "operationId": "get-previous-template-version-by-organization-template-and-name",
+                "summary": "Update organization JIT provisioning settings",
+                "operationId": "update-organization-jit-provisioning-settings",
                 "parameters": [
                     {
                         "type": "string",
@@ -1702,48 +2114,40 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Template name",
-                        "name": "templatename",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Template version name",
-                        "name": "templateversionname",
-                        "in": "path",
-                        "required": true
+                        "description": "Update JIT provisioning settings request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateOrganizationJITProvisioningSettingsRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                            "$ref": "#/definitions/codersdk.OrganizationJITProvisioningSettings"
                         }
                     }
                 }
             }
         },
-        "/organizations/{organization}/templateversions": {
-            "post": {
+        "/organizations/{organization}/settings/provisioners": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Organizations"
                 ],
-                "summary": "Create template version by organization",
-                "operationId": "create-template-version-by-organization",
+                "summary": "Get organization provisioner settings",
+                "operationId": "get-organization-provisioner-settings",
                 "parameters": [
                     {
                         "type": "string",
@@ -1752,81 +2156,65 @@ const docTemplate = `{
                         "name": "organization",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Create template version request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.CreateTemplateVersionRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                            "$ref": "#/definitions/codersdk.OrganizationProvisionerSettings"
                         }
                     }
                 }
-            }
-        },
-        "/regions": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Limits are applied when provisioners acquire jobs. Jobs that are already running are not affected.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "WorkspaceProxies"
+                    "Organizations"
                 ],
-                "summary": "Get site-wide regions for workspace connections",
-                "operationId": "get-site-wide-regions-for-workspace-connections",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                "summary": "Update organization provisioner settings",
+                "operationId": "update-organization-provisioner-settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update provisioner settings request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.RegionsResponse-codersdk_Region"
+                            "$ref": "#/definitions/codersdk.UpdateOrganizationProvisionerSettingsRequest"
                         }
                     }
-                }
-            }
-        },
-        "/replicas": {
-            "get": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
                 ],
-                "tags": [
-                    "Enterprise"
-                ],
-                "summary": "Get active replicas",
-                "operationId": "get-active-replicas",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.Replica"
-                            }
+                            "$ref": "#/definitions/codersdk.OrganizationProvisionerSettings"
                         }
                     }
                 }
             }
         },
-        "/scim/v2/Users": {
+        "/organizations/{organization}/settings/schedule": {
             "get": {
                 "security": [
                     {
@@ -1834,41 +2222,66 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/scim+json"
+                    "application/json"
                 ],
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "SCIM 2.0: Get users",
-                "operationId": "scim-get-users",
+                "summary": "Get organization schedule settings",
+                "operationId": "get-organization-schedule-settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.OrganizationScheduleSettings"
+                        }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Settings take effect from the next build of workspaces in the organization.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "SCIM 2.0: Create new user",
-                "operationId": "scim-create-new-user",
+                "summary": "Update organization schedule settings",
+                "operationId": "update-organization-schedule-settings",
                 "parameters": [
                     {
-                        "description": "New user",
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update schedule settings request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/coderd.SCIMUser"
+                            "$ref": "#/definitions/codersdk.UpdateOrganizationScheduleSettingsRequest"
                         }
                     }
                 ],
@@ -1876,13 +2289,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/coderd.SCIMUser"
+                            "$ref": "#/definitions/codersdk.OrganizationScheduleSettings"
                         }
                     }
                 }
             }
         },
-        "/scim/v2/Users/{id}": {
+        "/organizations/{organization}/settings/variables": {
             "get": {
                 "security": [
                     {
@@ -1890,59 +2303,78 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/scim+json"
+                    "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Organizations"
                 ],
-                "summary": "SCIM 2.0: Get user by ID",
-                "operationId": "scim-get-user-by-id",
+                "summary": "Get organization template variables",
+                "operationId": "get-organization-template-variables",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "404": {
-                        "description": "Not Found"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.OrganizationTemplateVariable"
+                            }
+                        }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/organizations/{organization}/settings/variables/{variable}": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "The value is used by the builds of all templates in the organization that declare the variable, unless the template version sets a value other than the default.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
-                    "application/scim+json"
+                    "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Organizations"
                 ],
-                "summary": "SCIM 2.0: Update user account",
-                "operationId": "scim-update-user-status",
+                "summary": "Upsert organization template variable",
+                "operationId": "upsert-organization-template-variable",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "User ID",
-                        "name": "id",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update user request",
+                        "type": "string",
+                        "description": "Variable name",
+                        "name": "variable",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Upsert template variable request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/coderd.SCIMUser"
+                            "$ref": "#/definitions/codersdk.UpsertOrganizationTemplateVariableRequest"
                         }
                     }
                 ],
@@ -1950,47 +2382,48 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.OrganizationTemplateVariable"
                         }
                     }
                 }
-            }
-        },
-        "/templates/{template}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "Templates"
+                    "Organizations"
                 ],
-                "summary": "Get template metadata by ID",
-                "operationId": "get-template-metadata-by-id",
+                "summary": "Delete organization template variable",
+                "operationId": "delete-organization-template-variable",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Variable name",
+                        "name": "variable",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.Template"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/organizations/{organization}/templates": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -2002,14 +2435,14 @@ const docTemplate = `{
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Delete template by ID",
-                "operationId": "delete-template-by-id",
+                "summary": "Get templates by organization",
+                "operationId": "get-templates-by-organization",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     }
@@ -2018,31 +2451,45 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.Template"
+                            }
                         }
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Update template metadata by ID",
-                "operationId": "update-template-metadata-by-id",
+                "summary": "Create template by organization",
+                "operationId": "create-template-by-organization",
                 "parameters": [
+                    {
+                        "description": "Request body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateTemplateRequest"
+                        }
+                    },
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     }
@@ -2057,7 +2504,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/templates/{template}/acl": {
+        "/organizations/{organization}/templates/examples": {
             "get": {
                 "security": [
                     {
@@ -2068,16 +2515,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Templates"
                 ],
-                "summary": "Get template ACLs",
-                "operationId": "get-template-acls",
+                "summary": "Get template examples by organization",
+                "operationId": "get-template-examples-by-organization",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     }
@@ -2088,59 +2535,56 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.TemplateUser"
+                                "$ref": "#/definitions/codersdk.TemplateExample"
                             }
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/organizations/{organization}/templates/{templatename}": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Templates"
                 ],
-                "summary": "Update template ACL",
-                "operationId": "update-template-acl",
+                "summary": "Get templates by organization and template name",
+                "operationId": "get-templates-by-organization-and-template-name",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update template request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateTemplateACL"
-                        }
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "templatename",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.Template"
                         }
                     }
                 }
             }
         },
-        "/templates/{template}/acl/available": {
+        "/organizations/{organization}/templates/{templatename}/versions/{templateversionname}": {
             "get": {
                 "security": [
                     {
@@ -2151,16 +2595,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Templates"
                 ],
-                "summary": "Get template available acl users/groups",
-                "operationId": "get-template-available-acl-usersgroups",
+                "summary": "Get template version by organization, template, and name",
+                "operationId": "get-template-version-by-organization-template-and-name",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "templatename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template version name",
+                        "name": "templateversionname",
                         "in": "path",
                         "required": true
                     }
@@ -2169,16 +2627,13 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.ACLAvailable"
-                            }
+                            "$ref": "#/definitions/codersdk.TemplateVersion"
                         }
                     }
                 }
             }
         },
-        "/templates/{template}/daus": {
+        "/organizations/{organization}/templates/{templatename}/versions/{templateversionname}/previous": {
             "get": {
                 "security": [
                     {
@@ -2191,14 +2646,28 @@ const docTemplate = `{
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Get template DAUs by ID",
-                "operationId": "get-template-daus-by-id",
+                "summary": "Get previous template version by organization, template, and name",
+                "operationId": "get-previous-template-version-by-organization-template-and-name",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template name",
+                        "name": "templatename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template version name",
+                        "name": "templateversionname",
                         "in": "path",
                         "required": true
                     }
@@ -2207,115 +2676,85 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.DAUsResponse"
+                            "$ref": "#/definitions/codersdk.TemplateVersion"
                         }
                     }
                 }
             }
         },
-        "/templates/{template}/versions": {
-            "get": {
+        "/organizations/{organization}/templateversions": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Templates"
                 ],
-                "summary": "List template versions by template ID",
-                "operationId": "list-template-versions-by-template-id",
+                "summary": "Create template version by organization",
+                "operationId": "create-template-version-by-organization",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "After ID",
-                        "name": "after_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page offset",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Create template version request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateTemplateVersionRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersion"
-                            }
+                            "$ref": "#/definitions/codersdk.TemplateVersion"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/regions": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
-                ],
-                "summary": "Update active template version by template ID",
-                "operationId": "update-active-template-version-by-template-id",
-                "parameters": [
-                    {
-                        "description": "Modified template version",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateActiveTemplateVersion"
-                        }
-                    },
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
-                        "in": "path",
-                        "required": true
-                    }
+                    "WorkspaceProxies"
                 ],
+                "summary": "Get site-wide regions for workspace connections",
+                "operationId": "get-site-wide-regions-for-workspace-connections",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.RegionsResponse-codersdk_Region"
                         }
                     }
                 }
             }
         },
-        "/templates/{template}/versions/{templateversionname}": {
+        "/replicas": {
             "get": {
                 "security": [
                     {
@@ -2326,41 +2765,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
-                ],
-                "summary": "Get template version by template ID and name",
-                "operationId": "get-template-version-by-template-id-and-name",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Template ID",
-                        "name": "template",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Template version name",
-                        "name": "templateversionname",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Enterprise"
                 ],
+                "summary": "Get active replicas",
+                "operationId": "get-active-replicas",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersion"
+                                "$ref": "#/definitions/codersdk.Replica"
                             }
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}": {
+        "/roles": {
             "get": {
                 "security": [
                     {
@@ -2371,35 +2793,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
-                ],
-                "summary": "Get template version by ID",
-                "operationId": "get-template-version-by-id",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Members"
                 ],
+                "summary": "Get custom roles",
+                "operationId": "get-custom-roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.CustomRole"
+                            }
                         }
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Custom roles are site wide roles composed of permissions on\nresource types. They are assigned to users like site roles.",
                 "consumes": [
                     "application/json"
                 ],
@@ -2407,108 +2823,147 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Members"
                 ],
-                "summary": "Patch template version by ID",
-                "operationId": "patch-template-version-by-id",
+                "summary": "Create custom role",
+                "operationId": "create-custom-role",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Patch template version request",
+                        "description": "Create custom role request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.PatchTemplateVersionRequest"
+                            "$ref": "#/definitions/codersdk.CreateCustomRoleRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                            "$ref": "#/definitions/codersdk.CustomRole"
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/cancel": {
-            "patch": {
+        "/roles/{role}": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Members"
                 ],
-                "summary": "Cancel template version by ID",
-                "operationId": "cancel-template-version-by-id",
+                "summary": "Update custom role",
+                "operationId": "update-custom-role",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Role name",
+                        "name": "role",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Update custom role request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateCustomRoleRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.CustomRole"
                         }
                     }
                 }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "The role is removed from all users that have it.",
+                "tags": [
+                    "Members"
+                ],
+                "summary": "Delete custom role",
+                "operationId": "delete-custom-role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role name",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
             }
         },
-        "/templateversions/{templateversion}/dry-run": {
-            "post": {
+        "/scim/v2/Groups": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Get groups",
+                "operationId": "scim-get-groups",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Create template version dry-run",
-                "operationId": "create-template-version-dry-run",
+                "summary": "SCIM 2.0: Create new group",
+                "operationId": "scim-create-new-group",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Dry-run request",
+                        "description": "New group",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateTemplateVersionDryRunRequest"
+                            "$ref": "#/definitions/coderd.SCIMGroup"
                         }
                     }
                 ],
@@ -2516,13 +2971,13 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.ProvisionerJob"
+                            "$ref": "#/definitions/coderd.SCIMGroup"
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/dry-run/{jobID}": {
+        "/scim/v2/Groups/{id}": {
             "get": {
                 "security": [
                     {
@@ -2530,27 +2985,19 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get template version dry-run by job ID",
-                "operationId": "get-template-version-dry-run-by-job-id",
+                "summary": "SCIM 2.0: Get group by ID",
+                "operationId": "scim-get-group-by-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Job ID",
-                        "name": "jobID",
+                        "description": "Group ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
@@ -2559,167 +3006,145 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.ProvisionerJob"
+                            "$ref": "#/definitions/coderd.SCIMGroup"
                         }
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/dry-run/{jobID}/cancel": {
-            "patch": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Cancel template version dry-run by job ID",
-                "operationId": "cancel-template-version-dry-run-by-job-id",
+                "summary": "SCIM 2.0: Replace group",
+                "operationId": "scim-replace-group",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Job ID",
-                        "name": "jobID",
+                        "description": "Group ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
+                        "description": "Replace group request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMGroup"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/coderd.SCIMGroup"
                         }
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/dry-run/{jobID}/logs": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get template version dry-run logs by job ID",
-                "operationId": "get-template-version-dry-run-logs-by-job-id",
+                "summary": "SCIM 2.0: Delete group",
+                "operationId": "scim-delete-group",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Job ID",
-                        "name": "jobID",
+                        "description": "Group ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Before Unix timestamp",
-                        "name": "before",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "After Unix timestamp",
-                        "name": "after",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Follow log stream",
-                        "name": "follow",
-                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.ProvisionerJobLog"
-                            }
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/dry-run/{jobID}/resources": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get template version dry-run resources by job ID",
-                "operationId": "get-template-version-dry-run-resources-by-job-id",
+                "summary": "SCIM 2.0: Update group",
+                "operationId": "scim-update-group",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Group ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Job ID",
-                        "name": "jobID",
-                        "in": "path",
-                        "required": true
+                        "description": "Update group request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMPatchRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.WorkspaceResource"
-                            }
+                            "$ref": "#/definitions/coderd.SCIMGroup"
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/gitauth": {
+        "/scim/v2/Users": {
             "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/scim+json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "SCIM 2.0: Get users",
+                "operationId": "scim-get-users",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            },
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -2729,34 +3154,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get git auth by template version",
-                "operationId": "get-git-auth-by-template-version",
+                "summary": "SCIM 2.0: Create new user",
+                "operationId": "scim-create-new-user",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
-                        "in": "path",
-                        "required": true
+                        "description": "New user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMUser"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersionGitAuth"
-                            }
+                            "$ref": "#/definitions/coderd.SCIMUser"
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/logs": {
+        "/scim/v2/Users/{id}": {
             "get": {
                 "security": [
                     {
@@ -2764,84 +3187,73 @@ const docTemplate = `{
                     }
                 ],
                 "produces": [
-                    "application/json"
+                    "application/scim+json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get logs by template version",
-                "operationId": "get-logs-by-template-version",
+                "summary": "SCIM 2.0: Get user by ID",
+                "operationId": "scim-get-user-by-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "User ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Before log id",
-                        "name": "before",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "After log id",
-                        "name": "after",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Follow log stream",
-                        "name": "follow",
-                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.ProvisionerJobLog"
-                            }
-                        }
+                    "404": {
+                        "description": "Not Found"
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/parameters": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "produces": [
+                    "application/scim+json"
+                ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Removed: Get parameters by template version",
-                "operationId": "removed-get-parameters-by-template-version",
+                "summary": "SCIM 2.0: Update user account",
+                "operationId": "scim-update-user-status",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "User ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Update user request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/coderd.SCIMUser"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/resources": {
+        "/templates/{template}": {
             "get": {
                 "security": [
                     {
@@ -2854,14 +3266,14 @@ const docTemplate = `{
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Get resources by template version",
-                "operationId": "get-resources-by-template-version",
+                "summary": "Get template metadata by ID",
+                "operationId": "get-template-metadata-by-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     }
@@ -2870,17 +3282,12 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.WorkspaceResource"
-                            }
+                            "$ref": "#/definitions/codersdk.Template"
                         }
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/rich-parameters": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -2892,14 +3299,14 @@ const docTemplate = `{
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Get rich parameters by template version",
-                "operationId": "get-rich-parameters-by-template-version",
+                "summary": "Delete template by ID",
+                "operationId": "delete-template-by-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     }
@@ -2908,45 +3315,46 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersionParameter"
-                            }
+                            "$ref": "#/definitions/codersdk.Response"
                         }
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}/schema": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Removed: Get schema by template version",
-                "operationId": "removed-get-schema-by-template-version",
+                "summary": "Update template metadata by ID",
+                "operationId": "update-template-metadata-by-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Template"
+                        }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/variables": {
+        "/templates/{template}/acl": {
             "get": {
                 "security": [
                     {
@@ -2957,16 +3365,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Enterprise"
                 ],
-                "summary": "Get template variables by template version",
-                "operationId": "get-template-variables-by-template-version",
+                "summary": "Get template ACLs",
+                "operationId": "get-template-acls",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     }
@@ -2977,34 +3385,59 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersionVariable"
+                                "$ref": "#/definitions/codersdk.TemplateUser"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/updatecheck": {
-            "get": {
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "General"
+                    "Enterprise"
+                ],
+                "summary": "Update template ACL",
+                "operationId": "update-template-acl",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update template request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplateACL"
+                        }
+                    }
                 ],
-                "summary": "Update check",
-                "operationId": "update-check",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateCheckResponse"
+                            "$ref": "#/definitions/codersdk.Response"
                         }
                     }
                 }
             }
         },
-        "/users": {
+        "/templates/{template}/acl/available": {
             "get": {
                 "security": [
                     {
@@ -3015,139 +3448,110 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Enterprise"
                 ],
-                "summary": "Get users",
-                "operationId": "get-users",
+                "summary": "Get template available acl users/groups",
+                "operationId": "get-template-available-acl-usersgroups",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Search query",
-                        "name": "q",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "After ID",
-                        "name": "after_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page offset",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.GetUsersResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ACLAvailable"
+                            }
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/templates/{template}/daus": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Create new user",
-                "operationId": "create-new-user",
+                "summary": "Get template DAUs by ID",
+                "operationId": "get-template-daus-by-id",
                 "parameters": [
                     {
-                        "description": "Create user request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.CreateUserRequest"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.DAUsResponse"
                         }
                     }
                 }
             }
         },
-        "/users/authmethods": {
+        "/templates/{template}/parameter-validation": {
             "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Returns the rules that the parameter values of workspace builds of the template are validated with before the builds are queued.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Get authentication methods",
-                "operationId": "get-authentication-methods",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.AuthMethods"
-                        }
-                    }
-                }
-            }
-        },
-        "/users/first": {
-            "get": {
-                "security": [
+                "summary": "Get template parameter validation",
+                "operationId": "get-template-parameter-validation",
+                "parameters": [
                     {
-                        "CoderSessionToken": []
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Users"
-                ],
-                "summary": "Check initial user created",
-                "operationId": "check-initial-user-created",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.TemplateParameterValidation"
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "The rules apply to builds that start workspaces and are created afterwards.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3155,190 +3559,218 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Create initial user",
-                "operationId": "create-initial-user",
+                "summary": "Update template parameter validation",
+                "operationId": "update-template-parameter-validation",
                 "parameters": [
                     {
-                        "description": "First user request",
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update template parameter validation request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateFirstUserRequest"
+                            "$ref": "#/definitions/codersdk.UpdateTemplateParameterValidationRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateFirstUserResponse"
+                            "$ref": "#/definitions/codersdk.TemplateParameterValidation"
                         }
                     }
                 }
             }
         },
-        "/users/login": {
-            "post": {
-                "consumes": [
-                    "application/json"
+        "/templates/{template}/prebuilds": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
                 ],
+                "description": "Returns the pool of prebuilt workspaces of the template.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authorization"
+                    "Templates"
                 ],
-                "summary": "Log in user",
-                "operationId": "log-in-user",
+                "summary": "Get template prebuilds",
+                "operationId": "get-template-prebuilds",
                 "parameters": [
                     {
-                        "description": "Login request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.LoginWithPasswordRequest"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.LoginWithPasswordResponse"
+                            "$ref": "#/definitions/codersdk.TemplatePrebuilds"
                         }
                     }
                 }
-            }
-        },
-        "/users/logout": {
-            "post": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Prebuilt workspaces are created or deleted in the background.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
+                ],
+                "summary": "Update template prebuilds",
+                "operationId": "update-template-prebuilds",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update template prebuilds request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplatePrebuildsRequest"
+                        }
+                    }
                 ],
-                "summary": "Log out user",
-                "operationId": "log-out-user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.TemplatePrebuilds"
                         }
                     }
                 }
             }
         },
-        "/users/oauth2/github/callback": {
+        "/templates/{template}/promotions": {
             "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "tags": [
-                    "Users"
-                ],
-                "summary": "OAuth 2.0 GitHub Callback",
-                "operationId": "oauth-20-github-callback",
-                "responses": {
-                    "307": {
-                        "description": "Temporary Redirect"
-                    }
-                }
-            }
-        },
-        "/users/oidc/callback": {
-            "get": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
+                "produces": [
+                    "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "OpenID Connect Callback",
-                "operationId": "openid-connect-callback",
-                "responses": {
-                    "307": {
-                        "description": "Temporary Redirect"
-                    }
-                }
-            }
-        },
-        "/users/roles": {
-            "get": {
-                "security": [
+                "summary": "Get template version promotions by template ID",
+                "operationId": "get-template-version-promotions-by-template-id",
+                "parameters": [
                     {
-                        "CoderSessionToken": []
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "promoted",
+                            "rejected"
+                        ],
+                        "type": "string",
+                        "description": "Filter by status",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Members"
-                ],
-                "summary": "Get site member roles",
-                "operationId": "get-site-member-roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.AssignableRoles"
+                                "$ref": "#/definitions/codersdk.TemplateVersionPromotion"
                             }
                         }
                     }
                 }
-            }
-        },
-        "/users/{user}": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "If the template doesn't require promotion approvals, the\ntemplate version is made active immediately.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Get user by name",
-                "operationId": "get-user-by-name",
+                "summary": "Create template version promotion",
+                "operationId": "create-template-version-promotion",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Create template version promotion request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateTemplateVersionPromotionRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.TemplateVersionPromotion"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/templates/{template}/promotions/{promotion}": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3348,15 +3780,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Delete user",
-                "operationId": "delete-user",
+                "summary": "Get template version promotion by ID",
+                "operationId": "get-template-version-promotion-by-id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Promotion ID",
+                        "name": "promotion",
                         "in": "path",
                         "required": true
                     }
@@ -3365,60 +3806,58 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.TemplateVersionPromotion"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/convert-login": {
+        "/templates/{template}/promotions/{promotion}/approve": {
             "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "The template version is made active once the promotion has\nthe number of approvals the template requires. Users can't\napprove their own promotions.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Authorization"
+                    "Templates"
                 ],
-                "summary": "Convert user from password to oauth authentication",
-                "operationId": "convert-user-from-password-to-oauth-authentication",
+                "summary": "Approve template version promotion",
+                "operationId": "approve-template-version-promotion",
                 "parameters": [
                     {
-                        "description": "Convert request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.ConvertLoginRequest"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Promotion ID",
+                        "name": "promotion",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.OAuthConversionResponse"
+                            "$ref": "#/definitions/codersdk.TemplateVersionPromotion"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/gitsshkey": {
-            "get": {
+        "/templates/{template}/promotions/{promotion}/reject": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3428,15 +3867,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Get user Git SSH key",
-                "operationId": "get-user-git-ssh-key",
+                "summary": "Reject template version promotion",
+                "operationId": "reject-template-version-promotion",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Promotion ID",
+                        "name": "promotion",
                         "in": "path",
                         "required": true
                     }
@@ -3445,12 +3893,14 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.GitSSHKey"
+                            "$ref": "#/definitions/codersdk.TemplateVersionPromotion"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/templates/{template}/recalculate-deadlines": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3460,15 +3910,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Enterprise"
                 ],
-                "summary": "Regenerate user SSH key",
-                "operationId": "regenerate-user-ssh-key",
+                "summary": "Get template workspace build deadline recalculations",
+                "operationId": "get-template-workspace-build-deadline-recalculations",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     }
@@ -3477,32 +3928,35 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.GitSSHKey"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateDeadlineRecalculation"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/users/{user}/keys": {
+            },
             "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Queues a job that recalculates the deadline and max_deadline of all\nactive workspace builds of the template using the current template\nschedule.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Enterprise"
                 ],
-                "summary": "Create new session key",
-                "operationId": "create-new-session-key",
+                "summary": "Recalculate template workspace build deadlines",
+                "operationId": "recalculate-template-workspace-build-deadlines",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     }
@@ -3511,13 +3965,13 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.GenerateAPIKeyResponse"
+                            "$ref": "#/definitions/codersdk.TemplateDeadlineRecalculation"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/keys/tokens": {
+        "/templates/{template}/recalculate-deadlines/{job}": {
             "get": {
                 "security": [
                     {
@@ -3528,15 +3982,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Enterprise"
                 ],
-                "summary": "Get user tokens",
-                "operationId": "get-user-tokens",
+                "summary": "Get template workspace build deadline recalculation",
+                "operationId": "get-template-workspace-build-deadline-recalculation",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "job",
                         "in": "path",
                         "required": true
                     }
@@ -3545,94 +4008,94 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.APIKey"
-                            }
+                            "$ref": "#/definitions/codersdk.TemplateDeadlineRecalculation"
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/templates/{template}/schedule-policy": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Enterprise"
                 ],
-                "summary": "Create token API key",
-                "operationId": "create-token-api-key",
+                "summary": "Get template schedule policy",
+                "operationId": "get-template-schedule-policy",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Create token request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.CreateTokenRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.GenerateAPIKeyResponse"
+                            "$ref": "#/definitions/codersdk.TemplateSchedulePolicy"
                         }
                     }
                 }
-            }
-        },
-        "/users/{user}/keys/tokens/tokenconfig": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Sets the template that the template inherits its schedule\nsettings from. Workspace build deadlines of the template and of\nthe templates that inherit from it are recalculated.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "General"
+                    "Enterprise"
                 ],
-                "summary": "Get token config",
-                "operationId": "get-token-config",
+                "summary": "Update template schedule policy",
+                "operationId": "update-template-schedule-policy",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Update schedule policy request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplateSchedulePolicyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TokenConfig"
+                            "$ref": "#/definitions/codersdk.TemplateSchedulePolicy"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/keys/tokens/{keyname}": {
+        "/templates/{template}/versions": {
             "get": {
                 "security": [
                     {
@@ -3643,65 +4106,83 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Get API key by token name",
-                "operationId": "get-api-key-by-token-name",
+                "summary": "List template versions by template ID",
+                "operationId": "list-template-versions-by-template-id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "format": "string",
-                        "description": "Key Name",
-                        "name": "keyname",
-                        "in": "path",
-                        "required": true
+                        "format": "uuid",
+                        "description": "After ID",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page offset",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.APIKey"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersion"
+                            }
                         }
                     }
                 }
-            }
-        },
-        "/users/{user}/keys/{keyid}": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Get API key by ID",
-                "operationId": "get-api-key-by-id",
+                "summary": "Update active template version by template ID",
+                "operationId": "update-active-template-version-by-template-id",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
-                        "in": "path",
-                        "required": true
+                        "description": "Modified template version",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateActiveTemplateVersion"
+                        }
                     },
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Key ID",
-                        "name": "keyid",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     }
@@ -3710,47 +4191,58 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.APIKey"
+                            "$ref": "#/definitions/codersdk.Response"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/templates/{template}/versions/{templateversionname}": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Delete API key",
-                "operationId": "delete-api-key",
+                "summary": "Get template version by template ID and name",
+                "operationId": "get-template-version-by-template-id-and-name",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Key ID",
-                        "name": "keyid",
+                        "description": "Template version name",
+                        "name": "templateversionname",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersion"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/users/{user}/login-type": {
+        "/templateversions/{templateversion}": {
             "get": {
                 "security": [
                     {
@@ -3761,15 +4253,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Get user login type",
-                "operationId": "get-user-login-type",
+                "summary": "Get template version by ID",
+                "operationId": "get-template-version-by-id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
                     }
@@ -3778,51 +4271,59 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UserLoginType"
+                            "$ref": "#/definitions/codersdk.TemplateVersion"
                         }
                     }
                 }
-            }
-        },
-        "/users/{user}/organizations": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Get organizations by user",
-                "operationId": "get-organizations-by-user",
+                "summary": "Patch template version by ID",
+                "operationId": "patch-template-version-by-id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Patch template version request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.PatchTemplateVersionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.Organization"
-                            }
+                            "$ref": "#/definitions/codersdk.TemplateVersion"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/organizations/{organizationname}": {
-            "get": {
+        "/templateversions/{templateversion}/cancel": {
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3832,22 +4333,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Get organization by user and organization name",
-                "operationId": "get-organization-by-user-and-organization-name",
+                "summary": "Cancel template version by ID",
+                "operationId": "cancel-template-version-by-id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Organization name",
-                        "name": "organizationname",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
                     }
@@ -3856,14 +4351,14 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Organization"
+                            "$ref": "#/definitions/codersdk.Response"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/password": {
-            "put": {
+        "/templateversions/{templateversion}/dry-run": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3872,84 +4367,88 @@ const docTemplate = `{
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Update user password",
-                "operationId": "update-user-password",
+                "summary": "Create template version dry-run",
+                "operationId": "create-template-version-dry-run",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update password request",
+                        "description": "Dry-run request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateUserPasswordRequest"
+                            "$ref": "#/definitions/codersdk.CreateTemplateVersionDryRunRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ProvisionerJob"
+                        }
                     }
                 }
             }
         },
-        "/users/{user}/profile": {
-            "put": {
+        "/templateversions/{templateversion}/dry-run/{jobID}": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Update user profile",
-                "operationId": "update-user-profile",
+                "summary": "Get template version dry-run by job ID",
+                "operationId": "get-template-version-dry-run-by-job-id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated profile",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateUserProfileRequest"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "$ref": "#/definitions/codersdk.ProvisionerJob"
                         }
                     }
                 }
             }
         },
-        "/users/{user}/quiet-hours": {
-            "get": {
+        "/templateversions/{templateversion}/dry-run/{jobID}/cancel": {
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -3959,16 +4458,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Templates"
                 ],
-                "summary": "Get user quiet hours schedule",
-                "operationId": "get-user-quiet-hours-schedule",
+                "summary": "Cancel template version dry-run by job ID",
+                "operationId": "cancel-template-version-dry-run-by-job-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "User ID",
-                        "name": "user",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
                     }
@@ -3977,48 +4484,61 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.UserQuietHoursScheduleResponse"
-                            }
+                            "$ref": "#/definitions/codersdk.Response"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/templateversions/{templateversion}/dry-run/{jobID}/logs": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Templates"
                 ],
-                "summary": "Update user quiet hours schedule",
-                "operationId": "update-user-quiet-hours-schedule",
+                "summary": "Get template version dry-run logs by job ID",
+                "operationId": "get-template-version-dry-run-logs-by-job-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "User ID",
-                        "name": "user",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update schedule request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateUserQuietHoursScheduleRequest"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "jobID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Before Unix timestamp",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "After Unix timestamp",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Follow log stream",
+                        "name": "follow",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -4027,14 +4547,14 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.UserQuietHoursScheduleResponse"
+                                "$ref": "#/definitions/codersdk.ProvisionerJobLog"
                             }
                         }
                     }
                 }
             }
         },
-        "/users/{user}/roles": {
+        "/templateversions/{templateversion}/dry-run/{jobID}/resources": {
             "get": {
                 "security": [
                     {
@@ -4045,15 +4565,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Get user roles",
-                "operationId": "get-user-roles",
+                "summary": "Get template version dry-run resources by job ID",
+                "operationId": "get-template-version-dry-run-resources-by-job-id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Job ID",
+                        "name": "jobID",
                         "in": "path",
                         "required": true
                     }
@@ -4062,58 +4591,55 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceResource"
+                            }
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/templateversions/{templateversion}/gitauth": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Assign role to user",
-                "operationId": "assign-role-to-user",
+                "summary": "Get git auth by template version",
+                "operationId": "get-git-auth-by-template-version",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Update roles request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateRoles"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersionGitAuth"
+                            }
                         }
                     }
                 }
             }
         },
-        "/users/{user}/status/activate": {
-            "put": {
+        "/templateversions/{templateversion}/logs": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -4123,64 +4649,81 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Activate user account",
-                "operationId": "activate-user-account",
+                "summary": "Get logs by template version",
+                "operationId": "get-logs-by-template-version",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Before log id",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "After log id",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Follow log stream",
+                        "name": "follow",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.User"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ProvisionerJobLog"
+                            }
                         }
                     }
                 }
             }
         },
-        "/users/{user}/status/suspend": {
-            "put": {
+        "/templateversions/{templateversion}/parameters": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "Users"
+                    "Templates"
                 ],
-                "summary": "Suspend user account",
-                "operationId": "suspend-user-account",
+                "summary": "Removed: Get parameters by template version",
+                "operationId": "removed-get-parameters-by-template-version",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.User"
-                        }
+                        "description": "OK"
                     }
                 }
             }
         },
-        "/users/{user}/workspace/{workspacename}": {
+        "/templateversions/{templateversion}/presets": {
             "get": {
                 "security": [
                     {
@@ -4191,92 +4734,81 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Workspaces"
+                    "Templates"
                 ],
-                "summary": "Get workspace metadata by user and workspace name",
-                "operationId": "get-workspace-metadata-by-user-and-workspace-name",
+                "summary": "Get template version presets",
+                "operationId": "get-template-version-presets",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Workspace name",
-                        "name": "workspacename",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Return data instead of HTTP 404 if the workspace is deleted",
-                        "name": "include_deleted",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Workspace"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersionPreset"
+                            }
                         }
                     }
                 }
             }
         },
-        "/users/{user}/workspace/{workspacename}/builds/{buildnumber}": {
-            "get": {
+        "/templateversions/{templateversion}/push": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Builds"
+                    "Templates"
                 ],
-                "summary": "Get workspace build by user, workspace name, and build number",
-                "operationId": "get-workspace-build-by-user-workspace-name-and-build-number",
+                "summary": "Push template version to registry",
+                "operationId": "push-template-version-to-registry",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Workspace name",
-                        "name": "workspacename",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "format": "number",
-                        "description": "Build number",
-                        "name": "buildnumber",
-                        "in": "path",
-                        "required": true
+                        "description": "Push template version request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.PushTemplateVersionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceBuild"
+                            "$ref": "#/definitions/codersdk.PushTemplateVersionResponse"
                         }
                     }
                 }
             }
         },
-        "/workspace-quota/{user}": {
+        "/templateversions/{templateversion}/resources": {
             "get": {
                 "security": [
                     {
@@ -4287,15 +4819,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Templates"
                 ],
-                "summary": "Get workspace quota by user",
-                "operationId": "get-workspace-quota-by-user",
+                "summary": "Get resources by template version",
+                "operationId": "get-resources-by-template-version",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID, name, or me",
-                        "name": "user",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
                         "in": "path",
                         "required": true
                     }
@@ -4304,91 +4837,83 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceQuota"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceResource"
+                            }
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/aws-instance-identity": {
-            "post": {
+        "/templateversions/{templateversion}/rich-parameters": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Templates"
                 ],
-                "summary": "Authenticate agent on AWS instance",
-                "operationId": "authenticate-agent-on-aws-instance",
+                "summary": "Get rich parameters by template version",
+                "operationId": "get-rich-parameters-by-template-version",
                 "parameters": [
                     {
-                        "description": "Instance identity token",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/agentsdk.AWSInstanceIdentityToken"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/agentsdk.AuthenticateResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersionParameter"
+                            }
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/azure-instance-identity": {
-            "post": {
+        "/templateversions/{templateversion}/schema": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "Agents"
+                    "Templates"
                 ],
-                "summary": "Authenticate agent on Azure instance",
-                "operationId": "authenticate-agent-on-azure-instance",
+                "summary": "Removed: Get schema by template version",
+                "operationId": "removed-get-schema-by-template-version",
                 "parameters": [
                     {
-                        "description": "Instance identity token",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/agentsdk.AzureInstanceIdentityToken"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/agentsdk.AuthenticateResponse"
-                        }
+                        "description": "OK"
                     }
                 }
             }
         },
-        "/workspaceagents/connection": {
+        "/templateversions/{templateversion}/variables": {
             "get": {
                 "security": [
                     {
@@ -4399,186 +4924,105 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Templates"
+                ],
+                "summary": "Get template variables by template version",
+                "operationId": "get-template-variables-by-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get connection info for workspace agent generic",
-                "operationId": "get-connection-info-for-workspace-agent-generic",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceAgentConnectionInfo"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersionVariable"
+                            }
                         }
                     }
-                },
-                "x-apidocgen": {
-                    "skip": true
                 }
             }
         },
-        "/workspaceagents/google-instance-identity": {
-            "post": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
-                ],
-                "consumes": [
-                    "application/json"
-                ],
+        "/updatecheck": {
+            "get": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
-                ],
-                "summary": "Authenticate agent on Google Cloud instance",
-                "operationId": "authenticate-agent-on-google-cloud-instance",
-                "parameters": [
-                    {
-                        "description": "Instance identity token",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/agentsdk.GoogleInstanceIdentityToken"
-                        }
-                    }
+                    "General"
                 ],
+                "summary": "Update check",
+                "operationId": "update-check",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/agentsdk.AuthenticateResponse"
+                            "$ref": "#/definitions/codersdk.UpdateCheckResponse"
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/me/app-health": {
-            "post": {
+        "/users": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Submit workspace agent application health",
-                "operationId": "submit-workspace-agent-application-health",
+                "summary": "Get users",
+                "operationId": "get-users",
                 "parameters": [
                     {
-                        "description": "Application health request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/agentsdk.PostAppHealthsRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK"
-                    }
-                }
-            }
-        },
-        "/workspaceagents/me/coordinate": {
-            "get": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
-                ],
-                "description": "It accepts a WebSocket connection to an agent that listens to\nincoming connections and publishes node updates.",
-                "tags": [
-                    "Agents"
-                ],
-                "summary": "Coordinate workspace agent via Tailnet",
-                "operationId": "coordinate-workspace-agent-via-tailnet",
-                "responses": {
-                    "101": {
-                        "description": "Switching Protocols"
-                    }
-                }
-            }
-        },
-        "/workspaceagents/me/gitauth": {
-            "get": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Agents"
-                ],
-                "summary": "Get workspace agent Git auth",
-                "operationId": "get-workspace-agent-git-auth",
-                "parameters": [
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "format": "uri",
-                        "description": "Git URL",
-                        "name": "url",
-                        "in": "query",
-                        "required": true
+                        "format": "uuid",
+                        "description": "After ID",
+                        "name": "after_id",
+                        "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "description": "Wait for a new token to be issued",
-                        "name": "listen",
+                        "type": "integer",
+                        "description": "Page limit",
+                        "name": "limit",
                         "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/agentsdk.GitAuthResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/workspaceagents/me/gitsshkey": {
-            "get": {
-                "security": [
+                    },
                     {
-                        "CoderSessionToken": []
+                        "type": "integer",
+                        "description": "Page offset",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Agents"
-                ],
-                "summary": "Get workspace agent Git SSH key",
-                "operationId": "get-workspace-agent-git-ssh-key",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/agentsdk.GitSSHKey"
+                            "$ref": "#/definitions/codersdk.GetUsersResponse"
                         }
                     }
                 }
-            }
-        },
-        "/workspaceagents/me/logs": {
-            "patch": {
+            },
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -4591,32 +5035,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Patch workspace agent logs",
-                "operationId": "patch-workspace-agent-logs",
+                "summary": "Create new user",
+                "operationId": "create-new-user",
                 "parameters": [
                     {
-                        "description": "logs",
+                        "description": "Create user request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/agentsdk.PatchLogs"
+                            "$ref": "#/definitions/codersdk.CreateUserRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.User"
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/me/manifest": {
+        "/users/authmethods": {
             "get": {
                 "security": [
                     {
@@ -4627,65 +5071,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Get authorized workspace agent manifest",
-                "operationId": "get-authorized-workspace-agent-manifest",
+                "summary": "Get authentication methods",
+                "operationId": "get-authentication-methods",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/agentsdk.Manifest"
+                            "$ref": "#/definitions/codersdk.AuthMethods"
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/me/metadata/{key}": {
-            "post": {
+        "/users/first": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Submit workspace agent metadata",
-                "operationId": "submit-workspace-agent-metadata",
-                "parameters": [
-                    {
-                        "description": "Workspace agent metadata request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                "summary": "Check initial user created",
+                "operationId": "check-initial-user-created",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/agentsdk.PostMetadataRequest"
+                            "$ref": "#/definitions/codersdk.Response"
                         }
-                    },
-                    {
-                        "type": "string",
-                        "format": "string",
-                        "description": "metadata key",
-                        "name": "key",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "Success"
                     }
-                },
-                "x-apidocgen": {
-                    "skip": true
                 }
-            }
-        },
-        "/workspaceagents/me/report-lifecycle": {
+            },
             "post": {
                 "security": [
                     {
@@ -4695,39 +5118,37 @@ const docTemplate = `{
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Submit workspace agent lifecycle state",
-                "operationId": "submit-workspace-agent-lifecycle-state",
+                "summary": "Create initial user",
+                "operationId": "create-initial-user",
                 "parameters": [
                     {
-                        "description": "Workspace agent lifecycle request",
+                        "description": "First user request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/agentsdk.PostLifecycleRequest"
+                            "$ref": "#/definitions/codersdk.CreateFirstUserRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Success"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateFirstUserResponse"
+                        }
                     }
-                },
-                "x-apidocgen": {
-                    "skip": true
                 }
             }
         },
-        "/workspaceagents/me/report-stats": {
+        "/users/login": {
             "post": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
-                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -4735,110 +5156,123 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Authorization"
                 ],
-                "summary": "Submit workspace agent stats",
-                "operationId": "submit-workspace-agent-stats",
+                "summary": "Log in user",
+                "operationId": "log-in-user",
                 "parameters": [
                     {
-                        "description": "Stats request",
+                        "description": "Login request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/agentsdk.Stats"
+                            "$ref": "#/definitions/codersdk.LoginWithPasswordRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/agentsdk.StatsResponse"
+                            "$ref": "#/definitions/codersdk.LoginWithPasswordResponse"
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/me/startup": {
+        "/users/logout": {
             "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Submit workspace agent startup",
-                "operationId": "submit-workspace-agent-startup",
-                "parameters": [
-                    {
-                        "description": "Startup request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                "summary": "Log out user",
+                "operationId": "log-out-user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/agentsdk.PostStartupRequest"
+                            "$ref": "#/definitions/codersdk.Response"
                         }
                     }
-                ],
+                }
+            }
+        },
+        "/users/oauth2/github/callback": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "OAuth 2.0 GitHub Callback",
+                "operationId": "oauth-20-github-callback",
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "307": {
+                        "description": "Temporary Redirect"
                     }
-                },
-                "x-apidocgen": {
-                    "skip": true
                 }
             }
         },
-        "/workspaceagents/me/startup-logs": {
-            "patch": {
+        "/users/oidc/callback": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
+                "tags": [
+                    "Users"
+                ],
+                "summary": "OpenID Connect Callback",
+                "operationId": "openid-connect-callback",
+                "responses": {
+                    "307": {
+                        "description": "Temporary Redirect"
+                    }
+                }
+            }
+        },
+        "/users/roles": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
-                ],
-                "summary": "Removed: Patch workspace agent logs",
-                "operationId": "removed-patch-workspace-agent-logs",
-                "parameters": [
-                    {
-                        "description": "logs",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/agentsdk.PatchLogs"
-                        }
-                    }
+                    "Members"
                 ],
+                "summary": "Get site member roles",
+                "operationId": "get-site-member-roles",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.AssignableRoles"
+                            }
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/{workspaceagent}": {
+        "/users/{user}": {
             "get": {
                 "security": [
                     {
@@ -4849,16 +5283,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Get workspace agent by ID",
-                "operationId": "get-workspace-agent-by-id",
+                "summary": "Get user by name",
+                "operationId": "get-user-by-name",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Workspace agent ID",
-                        "name": "workspaceagent",
+                        "description": "User ID, name, or me",
+                        "name": "user",
                         "in": "path",
                         "required": true
                     }
@@ -4867,14 +5300,12 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceAgent"
+                            "$ref": "#/definitions/codersdk.User"
                         }
                     }
                 }
-            }
-        },
-        "/workspaceagents/{workspaceagent}/connection": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -4884,16 +5315,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Get connection info for workspace agent",
-                "operationId": "get-connection-info-for-workspace-agent",
+                "summary": "Delete user",
+                "operationId": "delete-user",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Workspace agent ID",
-                        "name": "workspaceagent",
+                        "description": "User ID, name, or me",
+                        "name": "user",
                         "in": "path",
                         "required": true
                     }
@@ -4902,62 +5332,79 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceAgentConnectionInfo"
+                            "$ref": "#/definitions/codersdk.User"
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/{workspaceagent}/coordinate": {
-            "get": {
+        "/users/{user}/convert-login": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Agents"
+                    "Authorization"
                 ],
-                "summary": "Coordinate workspace agent",
-                "operationId": "coordinate-workspace-agent",
+                "summary": "Convert user from password to oauth authentication",
+                "operationId": "convert-user-from-password-to-oauth-authentication",
                 "parameters": [
+                    {
+                        "description": "Convert request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ConvertLoginRequest"
+                        }
+                    },
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Workspace agent ID",
-                        "name": "workspaceagent",
+                        "description": "User ID, name, or me",
+                        "name": "user",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "101": {
-                        "description": "Switching Protocols"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.OAuthConversionResponse"
+                        }
                     }
                 }
             }
         },
-        "/workspaceagents/{workspaceagent}/legacy": {
+        "/users/{user}/dotfiles": {
             "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "The repository URL is empty if the user hasn't configured dotfiles.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Users"
                 ],
-                "summary": "Agent is legacy",
-                "operationId": "agent-is-legacy",
+                "summary": "Get user dotfiles",
+                "operationId": "get-user-dotfiles",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Workspace Agent ID",
-                        "name": "workspaceagent",
+                        "description": "User ID, name, or me",
+                        "name": "user",
                         "in": "path",
                         "required": true
                     }
@@ -4966,51 +5413,58 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/wsproxysdk.AgentIsLegacyResponse"
+                            "$ref": "#/definitions/codersdk.UserDotfiles"
                         }
                     }
-                },
-                "x-apidocgen": {
-                    "skip": true
                 }
-            }
-        },
-        "/workspaceagents/{workspaceagent}/listening-ports": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "An empty repository URL removes the dotfiles of the user.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Get listening ports for workspace agent",
-                "operationId": "get-listening-ports-for-workspace-agent",
+                "summary": "Update user dotfiles",
+                "operationId": "update-user-dotfiles",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Workspace agent ID",
-                        "name": "workspaceagent",
+                        "description": "User ID, name, or me",
+                        "name": "user",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Update dotfiles request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateUserDotfilesRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceAgentListeningPortsResponse"
+                            "$ref": "#/definitions/codersdk.UserDotfiles"
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/{workspaceagent}/logs": {
+        "/users/{user}/gitsshkey": {
             "get": {
                 "security": [
                     {
@@ -5021,88 +5475,63 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Get logs by workspace agent",
-                "operationId": "get-logs-by-workspace-agent",
+                "summary": "Get user Git SSH key",
+                "operationId": "get-user-git-ssh-key",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Workspace agent ID",
-                        "name": "workspaceagent",
+                        "description": "User ID, name, or me",
+                        "name": "user",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Before log id",
-                        "name": "before",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "After log id",
-                        "name": "after",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Follow log stream",
-                        "name": "follow",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Disable compression for WebSocket connection",
-                        "name": "no_compression",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.WorkspaceAgentLog"
-                            }
+                            "$ref": "#/definitions/codersdk.GitSSHKey"
                         }
                     }
                 }
-            }
-        },
-        "/workspaceagents/{workspaceagent}/pty": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Open PTY to workspace agent",
-                "operationId": "open-pty-to-workspace-agent",
+                "summary": "Regenerate user SSH key",
+                "operationId": "regenerate-user-ssh-key",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Workspace agent ID",
-                        "name": "workspaceagent",
+                        "description": "User ID, name, or me",
+                        "name": "user",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "101": {
-                        "description": "Switching Protocols"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.GitSSHKey"
+                        }
                     }
                 }
             }
         },
-        "/workspaceagents/{workspaceagent}/startup-logs": {
-            "get": {
+        "/users/{user}/keys": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -5112,82 +5541,2956 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Removed: Get logs by workspace agent",
-                "operationId": "removed-get-logs-by-workspace-agent",
+                "summary": "Create new session key",
+                "operationId": "create-new-session-key",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Workspace agent ID",
-                        "name": "workspaceagent",
+                        "description": "User ID, name, or me",
+                        "name": "user",
                         "in": "path",
                         "required": true
-                    },
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.GenerateAPIKeyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/keys/tokens": {
+            "get": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "Before log id",
-                        "name": "before",
-                        "in": "query"
-                    },
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user tokens",
+                "operationId": "get-user-tokens",
+                "parameters": [
                     {
-                        "type": "integer",
-                        "description": "After log id",
-                        "name": "after",
-                        "in": "query"
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.APIKey"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create token API key",
+                "operationId": "create-token-api-key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "boolean",
-                        "description": "Follow log stream",
-                        "name": "follow",
-                        "in": "query"
+                        "description": "Create token request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.GenerateAPIKeyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/keys/tokens/tokenconfig": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "General"
+                ],
+                "summary": "Get token config",
+                "operationId": "get-token-config",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TokenConfig"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/keys/tokens/{keyname}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get API key by token name",
+                "operationId": "get-api-key-by-token-name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "boolean",
-                        "description": "Disable compression for WebSocket connection",
-                        "name": "no_compression",
-                        "in": "query"
+                        "type": "string",
+                        "format": "string",
+                        "description": "Key Name",
+                        "name": "keyname",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.WorkspaceAgentLog"
-                            }
+                            "$ref": "#/definitions/codersdk.APIKey"
                         }
                     }
                 }
             }
         },
-        "/workspaceagents/{workspaceagent}/watch-metadata": {
+        "/users/{user}/keys/{keyid}": {
             "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Agents"
+                    "Users"
                 ],
-                "summary": "Watch for workspace agent metadata updates",
-                "operationId": "watch-for-workspace-agent-metadata-updates",
+                "summary": "Get API key by ID",
+                "operationId": "get-api-key-by-id",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Workspace agent ID",
-                        "name": "workspaceagent",
+                        "description": "Key ID",
+                        "name": "keyid",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.APIKey"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete API key",
+                "operationId": "delete-api-key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Key ID",
+                        "name": "keyid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/users/{user}/login-type": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user login type",
+                "operationId": "get-user-login-type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UserLoginType"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/organizations": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get organizations by user",
+                "operationId": "get-organizations-by-user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.Organization"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/organizations/{organizationname}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get organization by user and organization name",
+                "operationId": "get-organization-by-user-and-organization-name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization name",
+                        "name": "organizationname",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Organization"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/password": {
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update user password",
+                "operationId": "update-user-password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update password request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateUserPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
+        "/users/{user}/profile": {
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update user profile",
+                "operationId": "update-user-profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated profile",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateUserProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/quiet-hours": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get user quiet hours schedule",
+                "operationId": "get-user-quiet-hours-schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.UserQuietHoursScheduleResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Update user quiet hours schedule",
+                "operationId": "update-user-quiet-hours-schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update schedule request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateUserQuietHoursScheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.UserQuietHoursScheduleResponse"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/quiet-hours/exceptions": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "Template restart requirements are skipped on excepted dates.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Create user quiet hours exception",
+                "operationId": "create-user-quiet-hours-exception",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create exception request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateUserQuietHoursExceptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UserQuietHoursScheduleResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/quiet-hours/exceptions/{date}": {
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Delete user quiet hours exception",
+                "operationId": "delete-user-quiet-hours-exception",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "User ID",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "date",
+                        "description": "Exception date",
+                        "name": "date",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UserQuietHoursScheduleResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/quota": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "Returns the quota consumed by the user broken down by the\nworkspaces that consume it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get quota usage by user",
+                "operationId": "get-quota-usage-by-user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UserQuota"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/roles": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user roles",
+                "operationId": "get-user-roles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Assign role to user",
+                "operationId": "assign-role-to-user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update roles request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateRoles"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/secrets": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "The values of secrets are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user secrets",
+                "operationId": "get-user-secrets",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.UserSecret"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create user secret",
+                "operationId": "create-user-secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create secret request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateUserSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UserSecret"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/secrets/{name}": {
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete user secret",
+                "operationId": "delete-user-secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update user secret",
+                "operationId": "update-user-secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update secret request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateUserSecretRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UserSecret"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/status/activate": {
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Activate user account",
+                "operationId": "activate-user-account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/status/suspend": {
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Suspend user account",
+                "operationId": "suspend-user-account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.User"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/workspace-filters": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Get saved workspace filters",
+                "operationId": "get-saved-workspace-filters",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.SavedWorkspaceFilter"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Create saved workspace filter",
+                "operationId": "create-saved-workspace-filter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create saved workspace filter request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateSavedWorkspaceFilterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.SavedWorkspaceFilter"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/workspace-filters/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Get saved workspace filter by name",
+                "operationId": "get-saved-workspace-filter-by-name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.SavedWorkspaceFilter"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Delete saved workspace filter",
+                "operationId": "delete-saved-workspace-filter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Update saved workspace filter",
+                "operationId": "update-saved-workspace-filter",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update saved workspace filter request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateSavedWorkspaceFilterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.SavedWorkspaceFilter"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/workspace/{workspacename}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Get workspace metadata by user and workspace name",
+                "operationId": "get-workspace-metadata-by-user-and-workspace-name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Workspace name",
+                        "name": "workspacename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return data instead of HTTP 404 if the workspace is deleted",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Workspace"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{user}/workspace/{workspacename}/builds/{buildnumber}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Builds"
+                ],
+                "summary": "Get workspace build by user, workspace name, and build number",
+                "operationId": "get-workspace-build-by-user-workspace-name-and-build-number",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Workspace name",
+                        "name": "workspacename",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "number",
+                        "description": "Build number",
+                        "name": "buildnumber",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceBuild"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspace-quota/{user}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get workspace quota by user",
+                "operationId": "get-workspace-quota-by-user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceQuota"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/aws-instance-identity": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Authenticate agent on AWS instance",
+                "operationId": "authenticate-agent-on-aws-instance",
+                "parameters": [
+                    {
+                        "description": "Instance identity token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.AWSInstanceIdentityToken"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.AuthenticateResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/azure-instance-identity": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Authenticate agent on Azure instance",
+                "operationId": "authenticate-agent-on-azure-instance",
+                "parameters": [
+                    {
+                        "description": "Instance identity token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.AzureInstanceIdentityToken"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.AuthenticateResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/connection": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get connection info for workspace agent generic",
+                "operationId": "get-connection-info-for-workspace-agent-generic",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentConnectionInfo"
+                        }
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspaceagents/google-instance-identity": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Authenticate agent on Google Cloud instance",
+                "operationId": "authenticate-agent-on-google-cloud-instance",
+                "parameters": [
+                    {
+                        "description": "Instance identity token",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.GoogleInstanceIdentityToken"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.AuthenticateResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/app-health": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Submit workspace agent application health",
+                "operationId": "submit-workspace-agent-application-health",
+                "parameters": [
+                    {
+                        "description": "Application health request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.PostAppHealthsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/coordinate": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "It accepts a WebSocket connection to an agent that listens to\nincoming connections and publishes node updates.",
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Coordinate workspace agent via Tailnet",
+                "operationId": "coordinate-workspace-agent-via-tailnet",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/drain": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "It accepts a WebSocket connection and sends a single drain\nrequest once a stop or delete build of the workspace is\nwaiting for the agent to drain. Returns 404 if draining is\ndisabled.",
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Wait for workspace agent drain request",
+                "operationId": "wait-for-workspace-agent-drain-request",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/gitauth": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get workspace agent Git auth",
+                "operationId": "get-workspace-agent-git-auth",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uri",
+                        "description": "Git URL",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Wait for a new token to be issued",
+                        "name": "listen",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.GitAuthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/gitsshkey": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get workspace agent Git SSH key",
+                "operationId": "get-workspace-agent-git-ssh-key",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.GitSSHKey"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/logs": {
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Patch workspace agent logs",
+                "operationId": "patch-workspace-agent-logs",
+                "parameters": [
+                    {
+                        "description": "logs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.PatchLogs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/manifest": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get authorized workspace agent manifest",
+                "operationId": "get-authorized-workspace-agent-manifest",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.Manifest"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/metadata-plugins": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Submit workspace agent metadata plugins",
+                "operationId": "submit-workspace-agent-metadata-plugins",
+                "parameters": [
+                    {
+                        "description": "Workspace agent metadata plugins request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.PostMetadataPluginsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspaceagents/me/metadata/{key}": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Submit workspace agent metadata",
+                "operationId": "submit-workspace-agent-metadata",
+                "parameters": [
+                    {
+                        "description": "Workspace agent metadata request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.PostMetadataRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "format": "string",
+                        "description": "metadata key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspaceagents/me/peers": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "Peers are the agents in the latest builds of the other\nworkspaces owned by the owner of the authenticated agent's\nworkspace.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "List workspace agent peers",
+                "operationId": "list-workspace-agent-peers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/agentsdk.Peer"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/peers/{workspaceagent}/connection": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get connection info for workspace agent peer",
+                "operationId": "get-connection-info-for-workspace-agent-peer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Peer workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentConnectionInfo"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/peers/{workspaceagent}/coordinate": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Coordinate workspace agent peer",
+                "operationId": "coordinate-workspace-agent-peer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Peer workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/report-lifecycle": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Submit workspace agent lifecycle state",
+                "operationId": "submit-workspace-agent-lifecycle-state",
+                "parameters": [
+                    {
+                        "description": "Workspace agent lifecycle request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.PostLifecycleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspaceagents/me/report-script-status": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Submit workspace agent script status",
+                "operationId": "submit-workspace-agent-script-status",
+                "parameters": [
+                    {
+                        "description": "Workspace agent script status request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.PostScriptStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspaceagents/me/report-stats": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Submit workspace agent stats",
+                "operationId": "submit-workspace-agent-stats",
+                "parameters": [
+                    {
+                        "description": "Stats request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.Stats"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.StatsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/me/startup": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Submit workspace agent startup",
+                "operationId": "submit-workspace-agent-startup",
+                "parameters": [
+                    {
+                        "description": "Startup request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.PostStartupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspaceagents/me/startup-logs": {
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Removed: Patch workspace agent logs",
+                "operationId": "removed-patch-workspace-agent-logs",
+                "parameters": [
+                    {
+                        "description": "logs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.PatchLogs"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get workspace agent by ID",
+                "operationId": "get-workspace-agent-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgent"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/connection": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get connection info for workspace agent",
+                "operationId": "get-connection-info-for-workspace-agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentConnectionInfo"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/coordinate": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Coordinate workspace agent",
+                "operationId": "coordinate-workspace-agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/legacy": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Agent is legacy",
+                "operationId": "agent-is-legacy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace Agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/wsproxysdk.AgentIsLegacyResponse"
+                        }
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/listening-ports": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get listening ports for workspace agent",
+                "operationId": "get-listening-ports-for-workspace-agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceAgentListeningPortsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/logs": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get logs by workspace agent",
+                "operationId": "get-logs-by-workspace-agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Before log id",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "After log id",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Follow log stream",
+                        "name": "follow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Disable compression for WebSocket connection",
+                        "name": "no_compression",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceAgentLog"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/metadata": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get workspace agent metadata",
+                "operationId": "get-workspace-agent-metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceAgentMetadata"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/pty": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Open PTY to workspace agent",
+                "operationId": "open-pty-to-workspace-agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/scripts": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Get scripts by workspace agent",
+                "operationId": "get-scripts-by-workspace-agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceAgentScript"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/startup-logs": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Removed: Get logs by workspace agent",
+                "operationId": "removed-get-logs-by-workspace-agent",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Before log id",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "After log id",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Follow log stream",
+                        "name": "follow",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Disable compression for WebSocket connection",
+                        "name": "no_compression",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceAgentLog"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceagents/{workspaceagent}/watch-metadata": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Watch for workspace agent metadata updates",
+                "operationId": "watch-for-workspace-agent-metadata-updates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace agent ID",
+                        "name": "workspaceagent",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspacebuilds/{workspacebuild}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Builds"
+                ],
+                "summary": "Get workspace build",
+                "operationId": "get-workspace-build",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace build ID",
+                        "name": "workspacebuild",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceBuild"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspacebuilds/{workspacebuild}/cancel": {
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Builds"
+                ],
+                "summary": "Cancel workspace build",
+                "operationId": "cancel-workspace-build",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace build ID",
+                        "name": "workspacebuild",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspacebuilds/{workspacebuild}/logs": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Builds"
+                ],
+                "summary": "Get workspace build logs",
+                "operationId": "get-workspace-build-logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace build ID",
+                        "name": "workspacebuild",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Before Unix timestamp",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "After Unix timestamp",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Follow log stream",
+                        "name": "follow",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ProvisionerJobLog"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workspacebuilds/{workspacebuild}/parameters": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Builds"
+                ],
+                "summary": "Get build parameters for workspace build",
+                "operationId": "get-build-parameters-for-workspace-build",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace build ID",
+                        "name": "workspacebuild",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceBuildParameter"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workspacebuilds/{workspacebuild}/resources": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Builds"
+                ],
+                "summary": "Get workspace resources for workspace build",
+                "operationId": "get-workspace-resources-for-workspace-build",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace build ID",
+                        "name": "workspacebuild",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceResource"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/workspacebuilds/{workspacebuild}/state": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Builds"
+                ],
+                "summary": "Get provisioner state for workspace build",
+                "operationId": "get-provisioner-state-for-workspace-build",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace build ID",
+                        "name": "workspacebuild",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceBuild"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspacebuilds/{workspacebuild}/timeline": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Builds"
+                ],
+                "summary": "Get workspace build timeline",
+                "operationId": "get-workspace-build-timeline",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workspace build ID",
+                        "name": "workspacebuild",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceBuildTimeline"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceproxies": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get workspace proxies",
+                "operationId": "get-workspace-proxies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.RegionsResponse-codersdk_WorkspaceProxy"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Create workspace proxy",
+                "operationId": "create-workspace-proxy",
+                "parameters": [
+                    {
+                        "description": "Create workspace proxy request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateWorkspaceProxyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceProxy"
+                        }
+                    }
+                }
+            }
+        },
+        "/workspaceproxies/me/acme-certificate": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Get workspace proxy ACME certificate",
+                "operationId": "get-workspace-proxy-acme-certificate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wildcard hostname of the proxy",
+                        "name": "domain",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/wsproxysdk.ACMECertificate"
+                        }
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Update workspace proxy ACME certificate",
+                "operationId": "update-workspace-proxy-acme-certificate",
+                "parameters": [
+                    {
+                        "description": "ACME certificate",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wsproxysdk.ACMECertificate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspaceproxies/me/app-stats": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Report workspace app stats",
+                "operationId": "report-workspace-app-stats",
+                "parameters": [
+                    {
+                        "description": "Report app stats request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wsproxysdk.ReportAppStatsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspaceproxies/me/coordinate": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Workspace Proxy Coordinate",
+                "operationId": "workspace-proxy-coordinate",
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
+        "/workspaceproxies/me/deregister": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Deregister workspace proxy",
+                "operationId": "deregister-workspace-proxy",
+                "parameters": [
+                    {
+                        "description": "Deregister workspace proxy request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wsproxysdk.DeregisterWorkspaceProxyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
                     }
                 },
                 "x-apidocgen": {
@@ -5195,76 +8498,92 @@ const docTemplate = `{
                 }
             }
         },
-        "/workspacebuilds/{workspacebuild}": {
-            "get": {
+        "/workspaceproxies/me/issue-signed-app-token": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Builds"
+                    "Enterprise"
                 ],
-                "summary": "Get workspace build",
-                "operationId": "get-workspace-build",
+                "summary": "Issue signed workspace app token",
+                "operationId": "issue-signed-workspace-app-token",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Workspace build ID",
-                        "name": "workspacebuild",
-                        "in": "path",
-                        "required": true
+                        "description": "Issue signed app token request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/workspaceapps.IssueTokenRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceBuild"
+                            "$ref": "#/definitions/wsproxysdk.IssueSignedAppTokenResponse"
                         }
                     }
+                },
+                "x-apidocgen": {
+                    "skip": true
                 }
             }
         },
-        "/workspacebuilds/{workspacebuild}/cancel": {
-            "patch": {
+        "/workspaceproxies/me/register": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Builds"
+                    "Enterprise"
                 ],
-                "summary": "Cancel workspace build",
-                "operationId": "cancel-workspace-build",
+                "summary": "Register workspace proxy",
+                "operationId": "register-workspace-proxy",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Workspace build ID",
-                        "name": "workspacebuild",
-                        "in": "path",
-                        "required": true
+                        "description": "Register workspace proxy request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/wsproxysdk.RegisterWorkspaceProxyRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/wsproxysdk.RegisterWorkspaceProxyResponse"
                         }
                     }
+                },
+                "x-apidocgen": {
+                    "skip": true
                 }
             }
         },
-        "/workspacebuilds/{workspacebuild}/logs": {
-            "get": {
+        "/workspaceproxies/me/rotate-token": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -5274,51 +8593,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Builds"
-                ],
-                "summary": "Get workspace build logs",
-                "operationId": "get-workspace-build-logs",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Workspace build ID",
-                        "name": "workspacebuild",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Before Unix timestamp",
-                        "name": "before",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "After Unix timestamp",
-                        "name": "after",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Follow log stream",
-                        "name": "follow",
-                        "in": "query"
-                    }
+                    "Enterprise"
                 ],
+                "summary": "Rotate workspace proxy token",
+                "operationId": "rotate-workspace-proxy-token",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.ProvisionerJobLog"
-                            }
+                            "$ref": "#/definitions/wsproxysdk.RotateWorkspaceProxyTokenResponse"
                         }
                     }
+                },
+                "x-apidocgen": {
+                    "skip": true
                 }
             }
         },
-        "/workspacebuilds/{workspacebuild}/parameters": {
+        "/workspaceproxies/{workspaceproxy}": {
             "get": {
                 "security": [
                     {
@@ -5329,15 +8621,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Builds"
+                    "Enterprise"
                 ],
-                "summary": "Get build parameters for workspace build",
-                "operationId": "get-build-parameters-for-workspace-build",
+                "summary": "Get workspace proxy",
+                "operationId": "get-workspace-proxy",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Workspace build ID",
-                        "name": "workspacebuild",
+                        "format": "uuid",
+                        "description": "Proxy ID or name",
+                        "name": "workspaceproxy",
                         "in": "path",
                         "required": true
                     }
@@ -5346,17 +8639,12 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.WorkspaceBuildParameter"
-                            }
+                            "$ref": "#/definitions/codersdk.WorkspaceProxy"
                         }
                     }
                 }
-            }
-        },
-        "/workspacebuilds/{workspacebuild}/resources": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -5366,15 +8654,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Builds"
+                    "Enterprise"
                 ],
-                "summary": "Get workspace resources for workspace build",
-                "operationId": "get-workspace-resources-for-workspace-build",
+                "summary": "Delete workspace proxy",
+                "operationId": "delete-workspace-proxy",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Workspace build ID",
-                        "name": "workspacebuild",
+                        "format": "uuid",
+                        "description": "Proxy ID or name",
+                        "name": "workspaceproxy",
                         "in": "path",
                         "required": true
                     }
@@ -5383,50 +8672,58 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.WorkspaceResource"
-                            }
+                            "$ref": "#/definitions/codersdk.Response"
                         }
                     }
                 }
-            }
-        },
-        "/workspacebuilds/{workspacebuild}/state": {
-            "get": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Builds"
+                    "Enterprise"
                 ],
-                "summary": "Get provisioner state for workspace build",
-                "operationId": "get-provisioner-state-for-workspace-build",
+                "summary": "Update workspace proxy",
+                "operationId": "update-workspace-proxy",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Workspace build ID",
-                        "name": "workspacebuild",
+                        "format": "uuid",
+                        "description": "Proxy ID or name",
+                        "name": "workspaceproxy",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Update workspace proxy request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.PatchWorkspaceProxy"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceBuild"
+                            "$ref": "#/definitions/codersdk.WorkspaceProxy"
                         }
                     }
                 }
             }
         },
-        "/workspaceproxies": {
+        "/workspaces": {
             "get": {
                 "security": [
                     {
@@ -5437,28 +8734,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Workspaces"
+                ],
+                "summary": "List workspaces",
+                "operationId": "list-workspaces",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query in the format ` + "`" + `key:value` + "`" + `. Available keys are: owner, template, name, status, has-agent, locked, lock_reason, outdated, deleting_by, last_used_before, last_used_after, deadline_before, deadline_after, max_deadline_before, max_deadline_after.",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get workspace proxies",
-                "operationId": "get-workspace-proxies",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.RegionsResponse-codersdk_WorkspaceProxy"
-                            }
+                            "$ref": "#/definitions/codersdk.WorkspacesResponse"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/workspaces/batch": {
             "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Queues an operation on all workspaces that match the query.\nThe batch runs in the background, use the returned ID to follow its progress.",
                 "consumes": [
                     "application/json"
                 ],
@@ -5466,18 +8783,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Workspaces"
                 ],
-                "summary": "Create workspace proxy",
-                "operationId": "create-workspace-proxy",
+                "summary": "Run operation on batch of workspaces",
+                "operationId": "run-operation-on-batch-of-workspaces",
                 "parameters": [
                     {
-                        "description": "Create workspace proxy request",
+                        "description": "Batch workspaces request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateWorkspaceProxyRequest"
+                            "$ref": "#/definitions/codersdk.BatchWorkspacesRequest"
                         }
                     }
                 ],
@@ -5485,72 +8802,114 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceProxy"
+                            "$ref": "#/definitions/codersdk.WorkspaceBatch"
                         }
                     }
                 }
             }
         },
-        "/workspaceproxies/me/app-stats": {
-            "post": {
+        "/workspaces/batch/{workspacebatch}": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
+                "description": "Returns the progress of a batch, with the result of the\noperation on each of its workspaces.",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Workspaces"
                 ],
-                "summary": "Report workspace app stats",
-                "operationId": "report-workspace-app-stats",
+                "summary": "Get workspace batch",
+                "operationId": "get-workspace-batch",
                 "parameters": [
                     {
-                        "description": "Report app stats request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace batch ID",
+                        "name": "workspacebatch",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/wsproxysdk.ReportAppStatsRequest"
+                            "$ref": "#/definitions/codersdk.WorkspaceBatch"
                         }
                     }
+                }
+            }
+        },
+        "/workspaces/watch": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Workspaces"
                 ],
+                "summary": "Watch all workspaces",
+                "operationId": "watch-all-workspaces",
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
                     }
-                },
-                "x-apidocgen": {
-                    "skip": true
                 }
             }
         },
-        "/workspaceproxies/me/coordinate": {
+        "/workspaces/{workspace}": {
             "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Enterprise"
+                    "Workspaces"
+                ],
+                "summary": "Get workspace metadata by ID",
+                "operationId": "get-workspace-metadata-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return data instead of HTTP 404 if the workspace is deleted",
+                        "name": "include_deleted",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Workspace Proxy Coordinate",
-                "operationId": "workspace-proxy-coordinate",
                 "responses": {
-                    "101": {
-                        "description": "Switching Protocols"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Workspace"
+                        }
                     }
-                },
-                "x-apidocgen": {
-                    "skip": true
                 }
-            }
-        },
-        "/workspaceproxies/me/deregister": {
-            "post": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -5560,18 +8919,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Workspaces"
                 ],
-                "summary": "Deregister workspace proxy",
-                "operationId": "deregister-workspace-proxy",
+                "summary": "Update workspace metadata by ID",
+                "operationId": "update-workspace-metadata-by-id",
                 "parameters": [
                     {
-                        "description": "Deregister workspace proxy request",
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Metadata update request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/wsproxysdk.DeregisterWorkspaceProxyRequest"
+                            "$ref": "#/definitions/codersdk.UpdateWorkspaceRequest"
                         }
                     }
                 ],
@@ -5579,56 +8946,44 @@ const docTemplate = `{
                     "204": {
                         "description": "No Content"
                     }
-                },
-                "x-apidocgen": {
-                    "skip": true
                 }
             }
         },
-        "/workspaceproxies/me/issue-signed-app-token": {
-            "post": {
+        "/workspaces/{workspace}/acl": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "Issue signed workspace app token",
-                "operationId": "issue-signed-workspace-app-token",
+                "summary": "Get workspace ACL",
+                "operationId": "get-workspace-acl",
                 "parameters": [
                     {
-                        "description": "Issue signed app token request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/workspaceapps.IssueTokenRequest"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/wsproxysdk.IssueSignedAppTokenResponse"
+                            "$ref": "#/definitions/codersdk.WorkspaceACL"
                         }
                     }
-                },
-                "x-apidocgen": {
-                    "skip": true
                 }
-            }
-        },
-        "/workspaceproxies/me/register": {
-            "post": {
+            },
+            "patch": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -5643,67 +8998,80 @@ const docTemplate = `{
                 "tags": [
                     "Enterprise"
                 ],
-                "summary": "Register workspace proxy",
-                "operationId": "register-workspace-proxy",
+                "summary": "Update workspace ACL",
+                "operationId": "update-workspace-acl",
                 "parameters": [
                     {
-                        "description": "Register workspace proxy request",
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update workspace ACL request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/wsproxysdk.RegisterWorkspaceProxyRequest"
+                            "$ref": "#/definitions/codersdk.UpdateWorkspaceACL"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/wsproxysdk.RegisterWorkspaceProxyResponse"
+                            "$ref": "#/definitions/codersdk.Response"
                         }
                     }
-                },
-                "x-apidocgen": {
-                    "skip": true
                 }
             }
         },
-        "/workspaceproxies/{workspaceproxy}": {
-            "get": {
+        "/workspaces/{workspace}/autostart": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "produces": [
+                "consumes": [
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Workspaces"
                 ],
-                "summary": "Get workspace proxy",
-                "operationId": "get-workspace-proxy",
+                "summary": "Update workspace autostart schedule by ID",
+                "operationId": "update-workspace-autostart-schedule-by-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Proxy ID or name",
-                        "name": "workspaceproxy",
+                        "description": "Workspace ID",
+                        "name": "workspace",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Schedule update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateWorkspaceAutostartRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceProxy"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/workspaces/{workspace}/builds": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -5713,30 +9081,59 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Builds"
                 ],
-                "summary": "Delete workspace proxy",
-                "operationId": "delete-workspace-proxy",
+                "summary": "Get workspace builds by workspace ID",
+                "operationId": "get-workspace-builds-by-workspace-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Proxy ID or name",
-                        "name": "workspaceproxy",
+                        "description": "Workspace ID",
+                        "name": "workspace",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "After ID",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Since timestamp",
+                        "name": "since",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WorkspaceBuild"
+                            }
                         }
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -5749,26 +9146,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Enterprise"
+                    "Builds"
                 ],
-                "summary": "Update workspace proxy",
-                "operationId": "update-workspace-proxy",
+                "summary": "Create workspace build",
+                "operationId": "create-workspace-build",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Proxy ID or name",
-                        "name": "workspaceproxy",
+                        "description": "Workspace ID",
+                        "name": "workspace",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Update workspace proxy request",
+                        "description": "Create workspace build request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.PatchWorkspaceProxy"
+                            "$ref": "#/definitions/codersdk.CreateWorkspaceBuildRequest"
                         }
                     }
                 ],
@@ -5776,72 +9173,78 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceProxy"
+                            "$ref": "#/definitions/codersdk.WorkspaceBuild"
                         }
                     }
                 }
             }
         },
-        "/workspaces": {
-            "get": {
+        "/workspaces/{workspace}/clone": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Ephemeral parameters are not copied. Volumes are copied if ` + "`" + `copy_volumes` + "`" + ` is set and the template version declares the ` + "`" + `coder_clone_source_workspace_id` + "`" + ` parameter.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "List workspaces",
-                "operationId": "list-workspaces",
+                "summary": "Clone workspace",
+                "operationId": "clone-workspace",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Search query in the format ` + "`" + `key:value` + "`" + `. Available keys are: owner, template, name, status, has-agent, deleting_by.",
-                        "name": "q",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page limit",
-                        "name": "limit",
-                        "in": "query"
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Page offset",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Clone workspace request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CloneWorkspaceRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspacesResponse"
+                            "$ref": "#/definitions/codersdk.Workspace"
                         }
                     }
                 }
             }
         },
-        "/workspaces/{workspace}": {
-            "get": {
+        "/workspaces/{workspace}/extend": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "Get workspace metadata by ID",
-                "operationId": "get-workspace-metadata-by-id",
+                "summary": "Extend workspace deadline by ID",
+                "operationId": "extend-workspace-deadline-by-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -5852,35 +9255,42 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "boolean",
-                        "description": "Return data instead of HTTP 404 if the workspace is deleted",
-                        "name": "include_deleted",
-                        "in": "query"
+                        "description": "Extend deadline update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.PutExtendWorkspaceRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Workspace"
+                            "$ref": "#/definitions/codersdk.Response"
                         }
                     }
                 }
             },
-            "patch": {
+            "post": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Extends the deadline of the active workspace build by a\nduration, bounded by the deadline extension limits of the\ntemplate.",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "Update workspace metadata by ID",
-                "operationId": "update-workspace-metadata-by-id",
+                "summary": "Extend workspace deadline by duration",
+                "operationId": "extend-workspace-deadline-by-duration",
                 "parameters": [
                     {
                         "type": "string",
@@ -5891,37 +9301,44 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Metadata update request",
+                        "description": "Extend deadline request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateWorkspaceRequest"
+                            "$ref": "#/definitions/codersdk.ExtendWorkspaceRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ExtendWorkspaceResponse"
+                        }
                     }
                 }
             }
         },
-        "/workspaces/{workspace}/autostart": {
+        "/workspaces/{workspace}/lock": {
             "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "The request body is optional, workspaces are locked if it is omitted.",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "Update workspace autostart schedule by ID",
-                "operationId": "update-workspace-autostart-schedule-by-id",
+                "summary": "Update workspace lock by id.",
+                "operationId": "update-workspace-lock-by-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -5932,23 +9349,25 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Schedule update request",
+                        "description": "Lock or unlock a workspace",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateWorkspaceAutostartRequest"
+                            "$ref": "#/definitions/codersdk.UpdateWorkspaceLock"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Workspace"
+                        }
                     }
                 }
             }
         },
-        "/workspaces/{workspace}/builds": {
+        "/workspaces/{workspace}/schedule-override": {
             "get": {
                 "security": [
                     {
@@ -5959,10 +9378,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Builds"
+                    "Enterprise"
                 ],
-                "summary": "Get workspace builds by workspace ID",
-                "operationId": "get-workspace-builds-by-workspace-id",
+                "summary": "Get workspace schedule override",
+                "operationId": "get-workspace-schedule-override",
                 "parameters": [
                     {
                         "type": "string",
@@ -5971,52 +9390,24 @@ const docTemplate = `{
                         "name": "workspace",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "After ID",
-                        "name": "after_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page offset",
-                        "name": "offset",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "format": "date-time",
-                        "description": "Since timestamp",
-                        "name": "since",
-                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.WorkspaceBuild"
-                            }
+                            "$ref": "#/definitions/codersdk.WorkspaceScheduleOverride"
                         }
                     }
                 }
             },
-            "post": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Overrides take effect from the next build of the workspace.",
                 "consumes": [
                     "application/json"
                 ],
@@ -6024,10 +9415,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Builds"
+                    "Enterprise"
                 ],
-                "summary": "Create workspace build",
-                "operationId": "create-workspace-build",
+                "summary": "Update workspace schedule override",
+                "operationId": "update-workspace-schedule-override",
                 "parameters": [
                     {
                         "type": "string",
@@ -6038,12 +9429,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Create workspace build request",
+                        "description": "Update schedule override request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.CreateWorkspaceBuildRequest"
+                            "$ref": "#/definitions/codersdk.UpdateWorkspaceScheduleOverrideRequest"
                         }
                     }
                 ],
@@ -6051,30 +9442,22 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceBuild"
+                            "$ref": "#/definitions/codersdk.WorkspaceScheduleOverride"
                         }
                     }
                 }
-            }
-        },
-        "/workspaces/{workspace}/extend": {
-            "put": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
-                    "Workspaces"
+                    "Enterprise"
                 ],
-                "summary": "Extend workspace deadline by ID",
-                "operationId": "extend-workspace-deadline-by-id",
+                "summary": "Delete workspace schedule override",
+                "operationId": "delete-workspace-schedule-override",
                 "parameters": [
                     {
                         "type": "string",
@@ -6083,28 +9466,16 @@ const docTemplate = `{
                         "name": "workspace",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Extend deadline update request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.PutExtendWorkspaceRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
         },
-        "/workspaces/{workspace}/lock": {
+        "/workspaces/{workspace}/ttl": {
             "put": {
                 "security": [
                     {
@@ -6114,14 +9485,11 @@ const docTemplate = `{
                 "consumes": [
                     "application/json"
                 ],
-                "produces": [
-                    "application/json"
-                ],
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "Update workspace lock by id.",
-                "operationId": "update-workspace-lock-by-id",
+                "summary": "Update workspace TTL by ID",
+                "operationId": "update-workspace-ttl-by-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -6132,40 +9500,37 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Lock or unlock a workspace",
+                        "description": "Workspace TTL update request",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateWorkspaceLock"
+                            "$ref": "#/definitions/codersdk.UpdateWorkspaceTTLRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.Workspace"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
         },
-        "/workspaces/{workspace}/ttl": {
+        "/workspaces/{workspace}/unlock": {
             "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Workspaces"
                 ],
-                "summary": "Update workspace TTL by ID",
-                "operationId": "update-workspace-ttl-by-id",
+                "summary": "Unlock workspace by id.",
+                "operationId": "unlock-workspace-by-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -6174,20 +9539,14 @@ const docTemplate = `{
                         "name": "workspace",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Workspace TTL update request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateWorkspaceTTLRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Workspace"
+                        }
                     }
                 }
             }
@@ -6329,6 +9688,11 @@ const docTemplate = `{
         "agentsdk.GitAuthResponse": {
             "type": "object",
             "properties": {
+                "expiry": {
+                    "description": "Expiry is when the token expires. It's zero if the token doesn't\nexpire.",
+                    "type": "string",
+                    "format": "date-time"
+                },
                 "password": {
                     "type": "string"
                 },
@@ -6394,12 +9758,30 @@ const docTemplate = `{
                 "derpmap": {
                     "$ref": "#/definitions/tailcfg.DERPMap"
                 },
+                "devcontainers": {
+                    "description": "Devcontainers are the repositories whose devcontainer.json the agent\napplies after the startup scripts have run.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceAgentDevcontainer"
+                    }
+                },
                 "directory": {
                     "type": "string"
                 },
                 "disable_direct_connections": {
                     "type": "boolean"
                 },
+                "disable_x11_forwarding": {
+                    "type": "boolean"
+                },
+                "dotfiles": {
+                    "description": "Dotfiles is the dotfiles repository of the workspace owner, which the\nagent applies when it starts. It's nil if the owner hasn't configured\none or the template disables dotfiles.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.UserDotfiles"
+                        }
+                    ]
+                },
                 "environment_variables": {
                     "type": "object",
                     "additionalProperties": {
@@ -6419,6 +9801,19 @@ const docTemplate = `{
                 "motd_file": {
                     "type": "string"
                 },
+                "scripts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceAgentScript"
+                    }
+                },
+                "secrets": {
+                    "description": "Secrets maps the names of the secrets of the workspace owner that\nare referenced by the template to their decrypted values. They are\nset as environment variables.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "shutdown_script": {
                     "type": "string"
                 },
@@ -6447,6 +9842,28 @@ const docTemplate = `{
                 }
             }
         },
+        "agentsdk.Peer": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "agent_name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "workspace_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "workspace_name": {
+                    "type": "string"
+                }
+            }
+        },
         "agentsdk.PostAppHealthsRequest": {
             "type": "object",
             "properties": {
@@ -6470,6 +9887,17 @@ const docTemplate = `{
                 }
             }
         },
+        "agentsdk.PostMetadataPluginsRequest": {
+            "type": "object",
+            "properties": {
+                "plugins": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceAgentMetadataDescription"
+                    }
+                }
+            }
+        },
         "agentsdk.PostMetadataRequest": {
             "type": "object",
             "properties": {
@@ -6481,11 +9909,31 @@ const docTemplate = `{
                     "type": "string",
                     "format": "date-time"
                 },
-                "error": {
+                "error": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "agentsdk.PostScriptStatusRequest": {
+            "type": "object",
+            "properties": {
+                "ended_at": {
+                    "type": "string"
+                },
+                "exit_code": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
                 },
-                "value": {
+                "started_at": {
                     "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/codersdk.WorkspaceAgentScriptStatus"
                 }
             }
         },
@@ -6531,6 +9979,14 @@ const docTemplate = `{
                         "$ref": "#/definitions/agentsdk.AgentMetric"
                     }
                 },
+                "peer_count_derp": {
+                    "description": "PeerCountDERP is the number of active peers connected to the agent\nthrough a DERP relay.",
+                    "type": "integer"
+                },
+                "peer_count_p2p": {
+                    "description": "PeerCountP2P is the number of active peers connected directly to the\nagent.",
+                    "type": "integer"
+                },
                 "rx_bytes": {
                     "description": "RxBytes is the number of received bytes.",
                     "type": "integer"
@@ -6700,6 +10156,17 @@ const docTemplate = `{
                 }
             }
         },
+        "clibase.Struct-array_codersdk_TokenExchangePolicy": {
+            "type": "object",
+            "properties": {
+                "value": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TokenExchangePolicy"
+                    }
+                }
+            }
+        },
         "clibase.URL": {
             "type": "object",
             "properties": {
@@ -6769,6 +10236,78 @@ const docTemplate = `{
                 "ValueSourceDefault"
             ]
         },
+        "coderd.SCIMGroup": {
+            "type": "object",
+            "properties": {
+                "displayName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/coderd.SCIMGroupMember"
+                    }
+                },
+                "meta": {
+                    "type": "object",
+                    "properties": {
+                        "resourceType": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "coderd.SCIMGroupMember": {
+            "type": "object",
+            "properties": {
+                "display": {
+                    "type": "string"
+                },
+                "value": {
+                    "description": "Value is the ID of the user that was returned when it was created.",
+                    "type": "string"
+                }
+            }
+        },
+        "coderd.SCIMPatchOperation": {
+            "type": "object",
+            "properties": {
+                "op": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "value": {}
+            }
+        },
+        "coderd.SCIMPatchRequest": {
+            "type": "object",
+            "properties": {
+                "Operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/coderd.SCIMPatchOperation"
+                    }
+                },
+                "schemas": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "coderd.SCIMUser": {
             "type": "object",
             "properties": {
@@ -6859,117 +10398,298 @@ const docTemplate = `{
                 }
             }
         },
-        "codersdk.APIKey": {
+        "codersdk.APIKey": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "expires_at",
+                "id",
+                "last_used",
+                "lifetime_seconds",
+                "login_type",
+                "scope",
+                "token_name",
+                "updated_at",
+                "user_id"
+            ],
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "lifetime_seconds": {
+                    "type": "integer"
+                },
+                "login_type": {
+                    "enum": [
+                        "password",
+                        "github",
+                        "oidc",
+                        "token"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.LoginType"
+                        }
+                    ]
+                },
+                "scope": {
+                    "enum": [
+                        "all",
+                        "application_connect",
+                        "workspace:read",
+                        "workspace:ssh",
+                        "workspace:apps",
+                        "template:read"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.APIKeyScope"
+                        }
+                    ]
+                },
+                "token_name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.APIKeyScope": {
+            "type": "string",
+            "enum": [
+                "all",
+                "application_connect",
+                "workspace:read",
+                "workspace:ssh",
+                "workspace:apps",
+                "template:read"
+            ],
+            "x-enum-varnames": [
+                "APIKeyScopeAll",
+                "APIKeyScopeApplicationConnect",
+                "APIKeyScopeWorkspaceRead",
+                "APIKeyScopeWorkspaceSSH",
+                "APIKeyScopeWorkspaceApps",
+                "APIKeyScopeTemplateRead"
+            ]
+        },
+        "codersdk.AddLicenseRequest": {
+            "type": "object",
+            "required": [
+                "license"
+            ],
+            "properties": {
+                "license": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.AgentSubsystem": {
+            "type": "string",
+            "enum": [
+                "envbox",
+                "envbuilder",
+                "exectrace"
+            ],
+            "x-enum-varnames": [
+                "AgentSubsystemEnvbox",
+                "AgentSubsystemEnvbuilder",
+                "AgentSubsystemExectrace"
+            ]
+        },
+        "codersdk.AppAccessLogInsight": {
+            "type": "object",
+            "properties": {
+                "access_method": {
+                    "description": "AccessMethod is one of \"path\" or \"subdomain\".",
+                    "type": "string",
+                    "example": "subdomain"
+                },
+                "avatar_url": {
+                    "type": "string",
+                    "format": "uri"
+                },
+                "last_request_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "latency_p50_ms": {
+                    "type": "number",
+                    "example": 12
+                },
+                "latency_p95_ms": {
+                    "type": "number",
+                    "example": 85
+                },
+                "path_prefix": {
+                    "description": "PathPrefix is the first segment of the requested path.",
+                    "type": "string",
+                    "example": "/api"
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "slug_or_port": {
+                    "type": "string",
+                    "example": "code-server"
+                },
+                "status_code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "user_id": {
+                    "description": "UserID is the zero UUID for requests of users that were not signed in\nto Coder, e.g. to public apps.",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.AppAccessLogInsightsReport": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.AppAccessLogInsight"
+                    }
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "template_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                }
+            }
+        },
+        "codersdk.AppAccessLogInsightsResponse": {
+            "type": "object",
+            "properties": {
+                "report": {
+                    "$ref": "#/definitions/codersdk.AppAccessLogInsightsReport"
+                }
+            }
+        },
+        "codersdk.AppHostResponse": {
+            "type": "object",
+            "properties": {
+                "host": {
+                    "description": "Host is the externally accessible URL for the Coder instance.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.AppSessionInsight": {
             "type": "object",
-            "required": [
-                "created_at",
-                "expires_at",
-                "id",
-                "last_used",
-                "lifetime_seconds",
-                "login_type",
-                "scope",
-                "token_name",
-                "updated_at",
-                "user_id"
-            ],
             "properties": {
-                "created_at": {
+                "access_method": {
+                    "description": "AccessMethod is one of \"path\", \"subdomain\" or \"terminal\".",
                     "type": "string",
-                    "format": "date-time"
+                    "example": "subdomain"
                 },
-                "expires_at": {
-                    "type": "string",
-                    "format": "date-time"
+                "active_seconds": {
+                    "description": "ActiveSeconds is the time the app was in use, time between sessions\nisn't counted.",
+                    "type": "integer",
+                    "example": 5400
                 },
-                "id": {
-                    "type": "string"
+                "avatar_url": {
+                    "type": "string",
+                    "format": "uri"
                 },
-                "last_used": {
+                "first_session_started_at": {
                     "type": "string",
                     "format": "date-time"
                 },
-                "lifetime_seconds": {
-                    "type": "integer"
-                },
-                "login_type": {
-                    "enum": [
-                        "password",
-                        "github",
-                        "oidc",
-                        "token"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/codersdk.LoginType"
-                        }
-                    ]
+                "last_session_ended_at": {
+                    "type": "string",
+                    "format": "date-time"
                 },
-                "scope": {
-                    "enum": [
-                        "all",
-                        "application_connect"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/codersdk.APIKeyScope"
-                        }
-                    ]
+                "sessions": {
+                    "type": "integer",
+                    "example": 12
                 },
-                "token_name": {
-                    "type": "string"
+                "slug_or_port": {
+                    "type": "string",
+                    "example": "code-server"
                 },
-                "updated_at": {
+                "template_id": {
                     "type": "string",
-                    "format": "date-time"
+                    "format": "uuid"
                 },
                 "user_id": {
                     "type": "string",
                     "format": "uuid"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "codersdk.APIKeyScope": {
-            "type": "string",
-            "enum": [
-                "all",
-                "application_connect"
-            ],
-            "x-enum-varnames": [
-                "APIKeyScopeAll",
-                "APIKeyScopeApplicationConnect"
-            ]
-        },
-        "codersdk.AddLicenseRequest": {
+        "codersdk.AppSessionInsightsReport": {
             "type": "object",
-            "required": [
-                "license"
-            ],
             "properties": {
-                "license": {
-                    "type": "string"
+                "apps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.AppSessionInsight"
+                    }
+                },
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "template_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
                 }
             }
         },
-        "codersdk.AgentSubsystem": {
-            "type": "string",
-            "enum": [
-                "envbox",
-                "envbuilder",
-                "exectrace"
-            ],
-            "x-enum-varnames": [
-                "AgentSubsystemEnvbox",
-                "AgentSubsystemEnvbuilder",
-                "AgentSubsystemExectrace"
-            ]
-        },
-        "codersdk.AppHostResponse": {
+        "codersdk.AppSessionInsightsResponse": {
             "type": "object",
             "properties": {
-                "host": {
-                    "description": "Host is the externally accessible URL for the Coder instance.",
-                    "type": "string"
+                "report": {
+                    "$ref": "#/definitions/codersdk.AppSessionInsightsReport"
                 }
             }
         },
@@ -7043,6 +10763,26 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.AuditExportConfig": {
+            "type": "object",
+            "properties": {
+                "batch_size": {
+                    "type": "integer"
+                },
+                "kafka_topic": {
+                    "type": "string"
+                },
+                "sink": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.AuditLog": {
             "type": "object",
             "properties": {
@@ -7125,6 +10865,17 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.AuditLogRetentionConfig": {
+            "type": "object",
+            "properties": {
+                "archive_url": {
+                    "type": "string"
+                },
+                "days": {
+                    "type": "integer"
+                }
+            }
+        },
         "codersdk.AuthMethod": {
             "type": "object",
             "properties": {
@@ -7158,79 +10909,234 @@ const docTemplate = `{
                         "read",
                         "update",
                         "delete"
-                    ]
-                },
-                "object": {
-                    "description": "Object can represent a \"set\" of objects, such as: all workspaces in an organization, all workspaces owned by me, and all workspaces across the entire product.\nWhen defining an object, use the most specific language when possible to\nproduce the smallest set. Meaning to set as many fields on 'Object' as\nyou can. Example, if you want to check if you can update all workspaces\nowned by 'me', try to also add an 'OrganizationID' to the settings.\nOmitting the 'OrganizationID' could produce the incorrect value, as\nworkspaces have both ` + "`" + `user` + "`" + ` and ` + "`" + `organization` + "`" + ` owners.",
+                    ]
+                },
+                "object": {
+                    "description": "Object can represent a \"set\" of objects, such as: all workspaces in an organization, all workspaces owned by me, and all workspaces across the entire product.\nWhen defining an object, use the most specific language when possible to\nproduce the smallest set. Meaning to set as many fields on 'Object' as\nyou can. Example, if you want to check if you can update all workspaces\nowned by 'me', try to also add an 'OrganizationID' to the settings.\nOmitting the 'OrganizationID' could produce the incorrect value, as\nworkspaces have both ` + "`" + `user` + "`" + ` and ` + "`" + `organization` + "`" + ` owners.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.AuthorizationObject"
+                        }
+                    ]
+                }
+            }
+        },
+        "codersdk.AuthorizationObject": {
+            "description": "AuthorizationObject can represent a \"set\" of objects, such as: all workspaces in an organization, all workspaces owned by me, all workspaces across the entire product.",
+            "type": "object",
+            "properties": {
+                "organization_id": {
+                    "description": "OrganizationID (optional) adds the set constraint to all resources owned by a given organization.",
+                    "type": "string"
+                },
+                "owner_id": {
+                    "description": "OwnerID (optional) adds the set constraint to all resources owned by a given user.",
+                    "type": "string"
+                },
+                "resource_id": {
+                    "description": "ResourceID (optional) reduces the set to a singular resource. This assigns\na resource ID to the resource type, eg: a single workspace.\nThe rbac library will not fetch the resource from the database, so if you\nare using this option, you should also set the owner ID and organization ID\nif possible. Be as specific as possible using all the fields relevant.",
+                    "type": "string"
+                },
+                "resource_type": {
+                    "description": "ResourceType is the name of the resource.\n` + "`" + `./coderd/rbac/object.go` + "`" + ` has the list of valid resource types.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.RBACResource"
+                        }
+                    ]
+                }
+            }
+        },
+        "codersdk.AuthorizationRequest": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "description": "Checks is a map keyed with an arbitrary string to a permission check.\nThe key can be any string that is helpful to the caller, and allows\nmultiple permission checks to be run in a single request.\nThe key ensures that each permission check has the same key in the\nresponse.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/codersdk.AuthorizationCheck"
+                    }
+                }
+            }
+        },
+        "codersdk.AuthorizationResponse": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "boolean"
+            }
+        },
+        "codersdk.BatchWorkspaceOperation": {
+            "type": "string",
+            "enum": [
+                "start",
+                "stop",
+                "update",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "BatchWorkspaceOperationStart",
+                "BatchWorkspaceOperationStop",
+                "BatchWorkspaceOperationUpdate",
+                "BatchWorkspaceOperationDelete"
+            ]
+        },
+        "codersdk.BatchWorkspaceResult": {
+            "type": "object",
+            "properties": {
+                "build_id": {
+                    "description": "BuildID is the ID of the build queued for the workspace.",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "error": {
+                    "description": "Error is why the operation failed.",
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "queued",
+                        "skipped",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.BatchWorkspaceResultStatus"
+                        }
+                    ]
+                },
+                "workspace_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "workspace_name": {
+                    "type": "string"
+                },
+                "workspace_owner_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.BatchWorkspaceResultStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "queued",
+                "skipped",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "BatchWorkspaceResultStatusPending",
+                "BatchWorkspaceResultStatusQueued",
+                "BatchWorkspaceResultStatusSkipped",
+                "BatchWorkspaceResultStatusFailed"
+            ]
+        },
+        "codersdk.BatchWorkspacesRequest": {
+            "type": "object",
+            "required": [
+                "operation",
+                "q"
+            ],
+            "properties": {
+                "operation": {
+                    "enum": [
+                        "start",
+                        "stop",
+                        "update",
+                        "delete"
+                    ],
                     "allOf": [
                         {
-                            "$ref": "#/definitions/codersdk.AuthorizationObject"
+                            "$ref": "#/definitions/codersdk.BatchWorkspaceOperation"
                         }
                     ]
+                },
+                "q": {
+                    "description": "Query is a workspace search query, e.g.\n\"template:docker last_used_before:2023-06-01\". It is required, so that\nan empty query doesn't select every workspace.",
+                    "type": "string"
                 }
             }
         },
-        "codersdk.AuthorizationObject": {
-            "description": "AuthorizationObject can represent a \"set\" of objects, such as: all workspaces in an organization, all workspaces owned by me, all workspaces across the entire product.",
+        "codersdk.BuildInfoResponse": {
             "type": "object",
             "properties": {
-                "organization_id": {
-                    "description": "OrganizationID (optional) adds the set constraint to all resources owned by a given organization.",
+                "dashboard_url": {
+                    "description": "DashboardURL is the URL to hit the deployment's dashboard.\nFor external workspace proxies, this is the coderd they are connected\nto.",
                     "type": "string"
                 },
-                "owner_id": {
-                    "description": "OwnerID (optional) adds the set constraint to all resources owned by a given user.",
+                "external_url": {
+                    "description": "ExternalURL references the current Coder version.\nFor production builds, this will link directly to a release. For development builds, this will link to a commit.",
                     "type": "string"
                 },
-                "resource_id": {
-                    "description": "ResourceID (optional) reduces the set to a singular resource. This assigns\na resource ID to the resource type, eg: a single workspace.\nThe rbac library will not fetch the resource from the database, so if you\nare using this option, you should also set the owner ID and organization ID\nif possible. Be as specific as possible using all the fields relevant.",
+                "version": {
+                    "description": "Version returns the semantic version of the build.",
                     "type": "string"
                 },
-                "resource_type": {
-                    "description": "ResourceType is the name of the resource.\n` + "`" + `./coderd/rbac/object.go` + "`" + ` has the list of valid resource types.",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/codersdk.RBACResource"
-                        }
-                    ]
+                "workspace_proxy": {
+                    "type": "boolean"
                 }
             }
         },
-        "codersdk.AuthorizationRequest": {
+        "codersdk.BuildInsightsIntervalReport": {
             "type": "object",
             "properties": {
-                "checks": {
-                    "description": "Checks is a map keyed with an arbitrary string to a permission check.\nThe key can be any string that is helpful to the caller, and allows\nmultiple permission checks to be run in a single request.\nThe key ensures that each permission check has the same key in the\nresponse.",
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/codersdk.AuthorizationCheck"
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "interval": {
+                    "$ref": "#/definitions/codersdk.InsightsReportInterval"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "template_versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionBuildInsight"
                     }
                 }
             }
         },
-        "codersdk.AuthorizationResponse": {
+        "codersdk.BuildInsightsReport": {
             "type": "object",
-            "additionalProperties": {
-                "type": "boolean"
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "template_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
+                "template_versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionBuildInsight"
+                    }
+                }
             }
         },
-        "codersdk.BuildInfoResponse": {
+        "codersdk.BuildInsightsResponse": {
             "type": "object",
             "properties": {
-                "dashboard_url": {
-                    "description": "DashboardURL is the URL to hit the deployment's dashboard.\nFor external workspace proxies, this is the coderd they are connected\nto.",
-                    "type": "string"
-                },
-                "external_url": {
-                    "description": "ExternalURL references the current Coder version.\nFor production builds, this will link directly to a release. For development builds, this will link to a commit.",
-                    "type": "string"
-                },
-                "version": {
-                    "description": "Version returns the semantic version of the build.",
-                    "type": "string"
+                "interval_reports": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.BuildInsightsIntervalReport"
+                    }
                 },
-                "workspace_proxy": {
-                    "type": "boolean"
+                "report": {
+                    "$ref": "#/definitions/codersdk.BuildInsightsReport"
                 }
             }
         },
@@ -7239,14 +11145,45 @@ const docTemplate = `{
             "enum": [
                 "initiator",
                 "autostart",
-                "autostop"
+                "autostop",
+                "restart_requirement",
+                "failure_ttl",
+                "inactivity_ttl",
+                "locked_ttl",
+                "admin_forced",
+                "max_lifetime",
+                "prebuild",
+                "resume"
             ],
             "x-enum-varnames": [
                 "BuildReasonInitiator",
                 "BuildReasonAutostart",
-                "BuildReasonAutostop"
+                "BuildReasonAutostop",
+                "BuildReasonRestartRequirement",
+                "BuildReasonFailureTTL",
+                "BuildReasonInactivityTTL",
+                "BuildReasonLockedTTL",
+                "BuildReasonAdminForced",
+                "BuildReasonMaxLifetime",
+                "BuildReasonPrebuild",
+                "BuildReasonResume"
             ]
         },
+        "codersdk.CloneWorkspaceRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "copy_volumes": {
+                    "description": "CopyVolumes sets the CloneSourceWorkspaceParameterName parameter of the\nclone, so that the template can copy the persistent volumes of the\nsource workspace. The template version must declare the parameter.",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.ConnectionLatency": {
             "type": "object",
             "properties": {
@@ -7280,6 +11217,33 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateCustomRoleRequest": {
+            "type": "object",
+            "required": [
+                "display_name",
+                "name"
+            ],
+            "properties": {
+                "display_name": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "site_permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.Permission"
+                    }
+                },
+                "user_permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.Permission"
+                    }
+                }
+            }
+        },
         "codersdk.CreateFirstUserRequest": {
             "type": "object",
             "required": [
@@ -7343,6 +11307,67 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateProvisionerKeyRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "codersdk.CreateProvisionerKeyResponse": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                },
+                "provisioner_key": {
+                    "$ref": "#/definitions/codersdk.ProvisionerKey"
+                }
+            }
+        },
+        "codersdk.CreateSavedWorkspaceFilterRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "query"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "query": {
+                    "description": "Query is a workspace search query, e.g. \"outdated:true status:running\".",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.CreateScheduleHolidayRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "name"
+            ],
+            "properties": {
+                "date": {
+                    "description": "Date is the calendar date of the holiday in YYYY-MM-DD format.",
+                    "type": "string",
+                    "format": "date"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.CreateTemplateRequest": {
             "type": "object",
             "required": [
@@ -7350,6 +11375,10 @@ const docTemplate = `{
                 "template_version_id"
             ],
             "properties": {
+                "activity_bump_ms": {
+                    "description": "ActivityBumpMillis allows optionally specifying how long workspaces\ncreated from this template stay running after activity was last\ndetected. Workspaces that are idle for longer are stopped.",
+                    "type": "integer"
+                },
                 "allow_user_autostart": {
                     "description": "AllowUserAutostart allows users to set a schedule for autostarting their\nworkspace. By default this is true. This can only be disabled when using\nan enterprise license.",
                     "type": "boolean"
@@ -7437,6 +11466,18 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateTemplateVersionPromotionRequest": {
+            "type": "object",
+            "required": [
+                "template_version_id"
+            ],
+            "properties": {
+                "template_version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.CreateTemplateVersionRequest": {
             "type": "object",
             "required": [
@@ -7457,12 +11498,22 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "presets": {
+                    "description": "Presets are named sets of parameter values that users can select when\ncreating workspaces from the version.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateVersionPreset"
+                    }
+                },
                 "provisioner": {
-                    "type": "string",
-                    "enum": [
-                        "terraform",
-                        "echo"
-                    ]
+                    "type": "string"
+                },
+                "registry_auth": {
+                    "$ref": "#/definitions/codersdk.TemplateRegistryAuth"
+                },
+                "registry_reference": {
+                    "description": "RegistryReference pulls the source of the version from a template\nregistry, e.g. oci://ghcr.io/coder/templates/docker:v1. RegistryAuth\nauthenticates with the registry if set.",
+                    "type": "string"
                 },
                 "storage_method": {
                     "enum": [
@@ -7520,7 +11571,15 @@ const docTemplate = `{
                     "enum": [
                         "autostart",
                         "autostop",
-                        "initiator"
+                        "initiator",
+                        "restart_requirement",
+                        "failure_ttl",
+                        "inactivity_ttl",
+                        "locked_ttl",
+                        "admin_forced",
+                        "max_lifetime",
+                        "prebuild",
+                        "resume"
                     ],
                     "allOf": [
                         {
@@ -7563,7 +11622,11 @@ const docTemplate = `{
                 "scope": {
                     "enum": [
                         "all",
-                        "application_connect"
+                        "application_connect",
+                        "workspace:read",
+                        "workspace:ssh",
+                        "workspace:apps",
+                        "template:read"
                     ],
                     "allOf": [
                         {
@@ -7576,6 +11639,19 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateUserQuietHoursExceptionRequest": {
+            "type": "object",
+            "required": [
+                "date"
+            ],
+            "properties": {
+                "date": {
+                    "description": "Date is the calendar date in YYYY-MM-DD format on which the user's quiet\nhours window should be skipped. It is evaluated in the timezone of the\nuser's quiet hours schedule and must not be in the past.",
+                    "type": "string",
+                    "format": "date"
+                }
+            }
+        },
         "codersdk.CreateUserRequest": {
             "type": "object",
             "required": [
@@ -7611,6 +11687,25 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateUserSecretRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "value"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name is the name of the environment variable the secret is set as. It\nmust only contain letters, digits and underscores, and must not start\nwith a digit.",
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.CreateWorkspaceBuildRequest": {
             "type": "object",
             "required": [
@@ -7635,6 +11730,10 @@ const docTemplate = `{
                     "description": "Orphan may be set for the Destroy transition.",
                     "type": "boolean"
                 },
+                "resume": {
+                    "description": "Resume runs the latest build of the workspace again after it failed or\nwas canceled. The build uses the template version, parameters and saved\nstate of the interrupted build, so resources that were already applied\nare kept. Transition must match the interrupted build.",
+                    "type": "boolean"
+                },
                 "rich_parameter_values": {
                     "description": "ParameterValues are optional. It will write params to the 'workspace' scope.\nThis will overwrite any existing parameters with the same name.\nThis will not delete old params not included in this list.",
                     "type": "array",
@@ -7692,6 +11791,7 @@ const docTemplate = `{
             ],
             "properties": {
                 "autostart_schedule": {
+                    "description": "AutostartSchedule accepts the same formats as\nUpdateWorkspaceAutostartRequest.Schedule.",
                     "type": "string"
                 },
                 "name": {
@@ -7708,8 +11808,41 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
-                "ttl_ms": {
-                    "type": "integer"
+                "ttl_ms": {
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.CustomRole": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "site_permissions": {
+                    "description": "SitePermissions apply to all resources of the site.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.Permission"
+                    }
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "user_permissions": {
+                    "description": "UserPermissions apply to resources owned by the user with the role.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.Permission"
+                    }
                 }
             }
         },
@@ -7759,11 +11892,65 @@ const docTemplate = `{
                 "path": {
                     "type": "string"
                 },
+                "reload_interval": {
+                    "type": "integer"
+                },
                 "url": {
                     "type": "string"
                 }
             }
         },
+        "codersdk.DERPHealth": {
+            "type": "object",
+            "properties": {
+                "healthy": {
+                    "type": "boolean"
+                },
+                "regions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.DERPRegionHealth"
+                    }
+                },
+                "server": {
+                    "$ref": "#/definitions/codersdk.DERPServerStats"
+                },
+                "time": {
+                    "description": "Time is the time at which the DERP regions were probed.",
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "codersdk.DERPNodeHealth": {
+            "type": "object",
+            "properties": {
+                "can_exchange_messages": {
+                    "type": "boolean"
+                },
+                "can_stun": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "host_name": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "uses_websocket": {
+                    "type": "boolean"
+                }
+            }
+        },
         "codersdk.DERPRegion": {
             "type": "object",
             "properties": {
@@ -7775,6 +11962,36 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.DERPRegionHealth": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "latency_ms": {
+                    "description": "LatencyMillis is the lowest round trip latency of the nodes of the\nregion. It is zero if no node could relay messages.",
+                    "type": "integer"
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.DERPNodeHealth"
+                    }
+                },
+                "region_code": {
+                    "type": "string"
+                },
+                "region_id": {
+                    "type": "integer"
+                },
+                "region_name": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.DERPServerConfig": {
             "type": "object",
             "properties": {
@@ -7801,6 +12018,35 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.DERPServerStats": {
+            "type": "object",
+            "properties": {
+                "accepts": {
+                    "type": "integer"
+                },
+                "bytes_received": {
+                    "type": "integer"
+                },
+                "bytes_sent": {
+                    "type": "integer"
+                },
+                "current_connections": {
+                    "type": "integer"
+                },
+                "current_home_connections": {
+                    "type": "integer"
+                },
+                "packets_dropped": {
+                    "type": "integer"
+                },
+                "packets_received": {
+                    "type": "integer"
+                },
+                "packets_sent": {
+                    "type": "integer"
+                }
+            }
+        },
         "codersdk.DangerousConfig": {
             "type": "object",
             "properties": {
@@ -7869,12 +12115,21 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "agent_drain_grace_period": {
+                    "type": "integer"
+                },
                 "agent_fallback_troubleshooting_url": {
                     "$ref": "#/definitions/clibase.URL"
                 },
                 "agent_stat_refresh_interval": {
                     "type": "integer"
                 },
+                "audit_export": {
+                    "$ref": "#/definitions/codersdk.AuditExportConfig"
+                },
+                "audit_log_retention": {
+                    "$ref": "#/definitions/codersdk.AuditLogRetentionConfig"
+                },
                 "autobuild_poll_interval": {
                     "type": "integer"
                 },
@@ -7908,6 +12163,9 @@ const docTemplate = `{
                 "disable_session_expiry_refresh": {
                     "type": "boolean"
                 },
+                "disable_x11_forwarding": {
+                    "type": "boolean"
+                },
                 "docs_url": {
                     "$ref": "#/definitions/clibase.URL"
                 },
@@ -7923,6 +12181,9 @@ const docTemplate = `{
                 "git_auth": {
                     "$ref": "#/definitions/clibase.Struct-array_codersdk_GitAuthConfig"
                 },
+                "http2_cleartext": {
+                    "type": "boolean"
+                },
                 "http_address": {
                     "description": "HTTPAddress is a string because it may be set to zero to disable.",
                     "type": "string"
@@ -7987,9 +12248,21 @@ const docTemplate = `{
                 "scim_api_key": {
                     "type": "string"
                 },
+                "scim_group_template_acls": {
+                    "type": "object"
+                },
                 "secure_auth_cookie": {
                     "type": "boolean"
                 },
+                "server_tailnet_health_check_interval": {
+                    "type": "integer"
+                },
+                "server_tailnet_idle_ttl": {
+                    "type": "integer"
+                },
+                "server_tailnet_max_idle_per_agent": {
+                    "type": "integer"
+                },
                 "ssh_keygen_algorithm": {
                     "type": "string"
                 },
@@ -8011,6 +12284,12 @@ const docTemplate = `{
                 "telemetry": {
                     "$ref": "#/definitions/codersdk.TelemetryConfig"
                 },
+                "template_registries": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "tls": {
                     "$ref": "#/definitions/codersdk.TLSConfig"
                 },
@@ -8023,15 +12302,30 @@ const docTemplate = `{
                 "user_quiet_hours_schedule": {
                     "$ref": "#/definitions/codersdk.UserQuietHoursScheduleConfig"
                 },
+                "user_secrets_encryption_key": {
+                    "type": "string"
+                },
                 "verbose": {
                     "type": "boolean"
                 },
+                "webhooks": {
+                    "$ref": "#/definitions/codersdk.WebhooksConfig"
+                },
                 "wgtunnel_host": {
                     "type": "string"
                 },
                 "wildcard_access_url": {
                     "$ref": "#/definitions/clibase.URL"
                 },
+                "workspace_app_oidc": {
+                    "$ref": "#/definitions/codersdk.WorkspaceAppOIDCConfig"
+                },
+                "workspace_app_stats": {
+                    "$ref": "#/definitions/codersdk.WorkspaceAppStatsConfig"
+                },
+                "workspace_app_sticky_sessions": {
+                    "type": "boolean"
+                },
                 "write_config": {
                     "type": "boolean"
                 }
@@ -8091,7 +12385,8 @@ const docTemplate = `{
                 "single_tailnet",
                 "template_restart_requirement",
                 "deployment_health_page",
-                "workspaces_batch_actions"
+                "workspaces_batch_actions",
+                "workspace_schedule_overrides"
             ],
             "x-enum-varnames": [
                 "ExperimentMoons",
@@ -8100,9 +12395,34 @@ const docTemplate = `{
                 "ExperimentSingleTailnet",
                 "ExperimentTemplateRestartRequirement",
                 "ExperimentDeploymentHealthPage",
-                "ExperimentWorkspacesBatchActions"
+                "ExperimentWorkspacesBatchActions",
+                "ExperimentWorkspaceScheduleOverrides"
             ]
         },
+        "codersdk.ExtendWorkspaceRequest": {
+            "type": "object",
+            "required": [
+                "duration_ms"
+            ],
+            "properties": {
+                "duration_ms": {
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.ExtendWorkspaceResponse": {
+            "type": "object",
+            "properties": {
+                "deadline": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "remaining_extensions": {
+                    "description": "RemainingExtensions is the number of times the deadline can still be\nextended within the last 24 hours. It is nil if the template doesn't\nlimit the number of extensions.",
+                    "type": "integer"
+                }
+            }
+        },
         "codersdk.Feature": {
             "type": "object",
             "properties": {
@@ -8283,13 +12603,15 @@ const docTemplate = `{
                 "azure-devops",
                 "github",
                 "gitlab",
-                "bitbucket"
+                "bitbucket",
+                "bitbucket-server"
             ],
             "x-enum-varnames": [
                 "GitProviderAzureDevops",
                 "GitProviderGitHub",
                 "GitProviderGitLab",
-                "GitProviderBitBucket"
+                "GitProviderBitBucket",
+                "GitProviderBitBucketServer"
             ]
         },
         "codersdk.GitSSHKey": {
@@ -8350,11 +12672,13 @@ const docTemplate = `{
             "type": "string",
             "enum": [
                 "user",
-                "oidc"
+                "oidc",
+                "scim"
             ],
             "x-enum-varnames": [
                 "GroupSourceUser",
-                "GroupSourceOIDC"
+                "GroupSourceOIDC",
+                "GroupSourceSCIM"
             ]
         },
         "codersdk.Healthcheck": {
@@ -8705,6 +13029,9 @@ const docTemplate = `{
                 "sign_in_text": {
                     "type": "string"
                 },
+                "token_exchange_policies": {
+                    "$ref": "#/definitions/clibase.Struct-array_codersdk_TokenExchangePolicy"
+                },
                 "user_role_field": {
                     "type": "string"
                 },
@@ -8735,12 +13062,148 @@ const docTemplate = `{
                     "type": "string",
                     "format": "date-time"
                 },
-                "id": {
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "codersdk.OrganizationBudgetSettings": {
+            "type": "object",
+            "properties": {
+                "alerted_threshold_percent": {
+                    "description": "AlertedThresholdPercent is the highest threshold that is currently\ncrossed, or zero.",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "critical_threshold_percent": {
+                    "description": "CriticalThresholdPercent is the percentage of the daily budget at which\na critical alert is sent.",
+                    "type": "integer"
+                },
+                "daily_budget": {
+                    "description": "DailyBudget is the number of credits the workspaces of the\norganization may consume per day in total. If zero, no alerts are sent.",
+                    "type": "integer"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "warning_threshold_percent": {
+                    "description": "WarningThresholdPercent is the percentage of the daily budget at which\na warning is sent.",
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.OrganizationJITProvisioningSettings": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "template_id": {
+                    "description": "TemplateID is the template the workspace is created from. If it's the\nnil UUID, no workspace is created.",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "workspace_name": {
+                    "description": "WorkspaceName is the name of the workspace. If it's empty, the name of\nthe template is used.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.OrganizationMember": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.Role"
+                    }
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.OrganizationProvisionerSettings": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "max_concurrent_jobs": {
+                    "description": "MaxConcurrentJobs is the maximum number of provisioner jobs of the\norganization that provisioners run at the same time. Other jobs wait in\nthe queue. If zero, the number of jobs is not limited.",
+                    "type": "integer"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "codersdk.OrganizationScheduleSettings": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "default_quiet_hours_schedule": {
+                    "description": "DefaultQuietHoursSchedule is used for members of the organization that\nhave no quiet hours schedule set, instead of the deployment default.",
+                    "type": "string"
+                },
+                "default_ttl_ms": {
+                    "description": "DefaultTTLMillis is used for templates that have no default TTL set.",
+                    "type": "integer"
+                },
+                "organization_id": {
                     "type": "string",
                     "format": "uuid"
                 },
-                "name": {
-                    "type": "string"
+                "restart_requirement": {
+                    "description": "RestartRequirement is used for templates that have no restart\nrequirement days set. The timezone is unused, the template's timezone\nalways applies.",
+                    "$ref": "#/definitions/codersdk.TemplateRestartRequirement"
                 },
                 "updated_at": {
                     "type": "string",
@@ -8748,30 +13211,26 @@ const docTemplate = `{
                 }
             }
         },
-        "codersdk.OrganizationMember": {
+        "codersdk.OrganizationTemplateVariable": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string",
                     "format": "date-time"
                 },
-                "organization_id": {
-                    "type": "string",
-                    "format": "uuid"
+                "name": {
+                    "type": "string"
                 },
-                "roles": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/codersdk.Role"
-                    }
+                "sensitive": {
+                    "type": "boolean"
                 },
                 "updated_at": {
                     "type": "string",
                     "format": "date-time"
                 },
-                "user_id": {
-                    "type": "string",
-                    "format": "uuid"
+                "value": {
+                    "description": "Value is redacted if the variable is sensitive.",
+                    "type": "string"
                 }
             }
         },
@@ -8842,6 +13301,27 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.Permission": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "read",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "negate": {
+                    "description": "Negate denies the action instead of granting it.",
+                    "type": "boolean"
+                },
+                "resource_type": {
+                    "$ref": "#/definitions/codersdk.RBACResource"
+                }
+            }
+        },
         "codersdk.PprofConfig": {
             "type": "object",
             "properties": {
@@ -8873,6 +13353,9 @@ const docTemplate = `{
         "codersdk.ProvisionerConfig": {
             "type": "object",
             "properties": {
+                "daemon_cache_max_size": {
+                    "type": "integer"
+                },
                 "daemon_poll_interval": {
                     "type": "integer"
                 },
@@ -8896,6 +13379,14 @@ const docTemplate = `{
         "codersdk.ProvisionerDaemon": {
             "type": "object",
             "properties": {
+                "cache": {
+                    "description": "Cache is the provider and module cache of the daemon, as of the last\njob the daemon completed.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerDaemonCache"
+                        }
+                    ]
+                },
                 "created_at": {
                     "type": "string",
                     "format": "date-time"
@@ -8929,6 +13420,29 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.ProvisionerDaemonCache": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "integer"
+                },
+                "hits": {
+                    "description": "Hits and Misses are counted since the daemon started.",
+                    "type": "integer"
+                },
+                "max_size_bytes": {
+                    "description": "MaxSizeBytes is 0 if the daemon has no cache.",
+                    "type": "integer"
+                },
+                "misses": {
+                    "type": "integer"
+                },
+                "size_bytes": {
+                    "description": "SizeBytes is the size of the distinct files in the cache.",
+                    "type": "integer"
+                }
+            }
+        },
         "codersdk.ProvisionerJob": {
             "type": "object",
             "properties": {
@@ -9033,11 +13547,61 @@ const docTemplate = `{
                 "output": {
                     "type": "string"
                 },
+                "progress": {
+                    "description": "Progress is set by provisioners that report how far they've come,\nsuch as Terraform.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerJobLogProgress"
+                        }
+                    ]
+                },
                 "stage": {
                     "type": "string"
                 }
             }
         },
+        "codersdk.ProvisionerJobLogProgress": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "description": "Completed is the number of resources that are done in the stage.",
+                    "type": "integer"
+                },
+                "resource": {
+                    "description": "Resource is the address of the resource the log is about, if any.",
+                    "type": "string"
+                },
+                "stage": {
+                    "enum": [
+                        "init",
+                        "plan",
+                        "apply"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerJobLogStage"
+                        }
+                    ]
+                },
+                "total": {
+                    "description": "Total is the number of resources in the stage, or zero if unknown.",
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.ProvisionerJobLogStage": {
+            "type": "string",
+            "enum": [
+                "init",
+                "plan",
+                "apply"
+            ],
+            "x-enum-varnames": [
+                "ProvisionerJobLogStageInit",
+                "ProvisionerJobLogStagePlan",
+                "ProvisionerJobLogStageApply"
+            ]
+        },
         "codersdk.ProvisionerJobStatus": {
             "type": "string",
             "enum": [
@@ -9057,6 +13621,37 @@ const docTemplate = `{
                 "ProvisionerJobFailed"
             ]
         },
+        "codersdk.ProvisionerKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "last_used_at": {
+                    "description": "LastUsedAt is the last time a provisioner daemon connected with the\nkey, if any.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "tags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "codersdk.ProvisionerLogLevel": {
             "type": "string",
             "enum": [
@@ -9109,6 +13704,33 @@ const docTemplate = `{
                 "ProxyUnregistered"
             ]
         },
+        "codersdk.PushTemplateVersionRequest": {
+            "type": "object",
+            "required": [
+                "reference"
+            ],
+            "properties": {
+                "auth": {
+                    "$ref": "#/definitions/codersdk.TemplateRegistryAuth"
+                },
+                "reference": {
+                    "description": "Reference is the registry, repository, and tag to push to, e.g.\noci://ghcr.io/coder/templates/docker:v1.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.PushTemplateVersionResponse": {
+            "type": "object",
+            "properties": {
+                "digest": {
+                    "description": "Digest is the digest of the pushed manifest. Append it to the\nreference with an @ to pull exactly this version.",
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.PutExtendWorkspaceRequest": {
             "type": "object",
             "required": [
@@ -9136,6 +13758,7 @@ const docTemplate = `{
                 "organization",
                 "assign_role",
                 "assign_org_role",
+                "custom_role",
                 "api_key",
                 "user",
                 "user_data",
@@ -9160,6 +13783,7 @@ const docTemplate = `{
                 "ResourceOrganization",
                 "ResourceRoleAssignment",
                 "ResourceOrgRoleAssignment",
+                "ResourceCustomRole",
                 "ResourceAPIKey",
                 "ResourceUser",
                 "ResourceUserData",
@@ -9274,6 +13898,7 @@ const docTemplate = `{
             "enum": [
                 "template",
                 "template_version",
+                "template_version_promotion",
                 "user",
                 "workspace",
                 "workspace_build",
@@ -9281,11 +13906,16 @@ const docTemplate = `{
                 "api_key",
                 "group",
                 "license",
-                "convert_login"
+                "convert_login",
+                "provisioner_key",
+                "template_parameter_validation",
+                "organization_template_variable",
+                "custom_role"
             ],
             "x-enum-varnames": [
                 "ResourceTypeTemplate",
                 "ResourceTypeTemplateVersion",
+                "ResourceTypeTemplateVersionPromotion",
                 "ResourceTypeUser",
                 "ResourceTypeWorkspace",
                 "ResourceTypeWorkspaceBuild",
@@ -9293,7 +13923,11 @@ const docTemplate = `{
                 "ResourceTypeAPIKey",
                 "ResourceTypeGroup",
                 "ResourceTypeLicense",
-                "ResourceTypeConvertLogin"
+                "ResourceTypeConvertLogin",
+                "ResourceTypeProvisionerKey",
+                "ResourceTypeTemplateParameterValidation",
+                "ResourceTypeOrganizationTemplateVariable",
+                "ResourceTypeCustomRole"
             ]
         },
         "codersdk.Response": {
@@ -9357,6 +13991,92 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.SavedWorkspaceFilter": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.ScheduleHoliday": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "date": {
+                    "description": "Date is the calendar date of the holiday in YYYY-MM-DD format. It is\nevaluated in the timezone of the restart requirement.",
+                    "type": "string",
+                    "format": "date"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "codersdk.ScheduleInsightsReport": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "template_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
+                "templates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateScheduleInsight"
+                    }
+                }
+            }
+        },
+        "codersdk.ScheduleInsightsResponse": {
+            "type": "object",
+            "properties": {
+                "report": {
+                    "$ref": "#/definitions/codersdk.ScheduleInsightsReport"
+                }
+            }
+        },
         "codersdk.ServiceBannerConfig": {
             "type": "object",
             "properties": {
@@ -9407,6 +14127,21 @@ const docTemplate = `{
         "codersdk.TLSConfig": {
             "type": "object",
             "properties": {
+                "acme_directory_url": {
+                    "type": "string"
+                },
+                "acme_dns_hook": {
+                    "type": "string"
+                },
+                "acme_email": {
+                    "type": "string"
+                },
+                "acme_enable": {
+                    "type": "boolean"
+                },
+                "acme_encryption_key": {
+                    "type": "string"
+                },
                 "address": {
                     "$ref": "#/definitions/clibase.HostPort"
                 },
@@ -9440,6 +14175,12 @@ const docTemplate = `{
                 "min_version": {
                     "type": "string"
                 },
+                "proxy_client_ca_file": {
+                    "type": "string"
+                },
+                "proxy_require_client_cert": {
+                    "type": "boolean"
+                },
                 "redirect_http": {
                     "type": "boolean"
                 }
@@ -9470,6 +14211,10 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "activity_bump_ms": {
+                    "description": "ActivityBumpMillis is how long workspaces stay running after activity\nwas last detected. If zero, activity bumps the deadline by the\nworkspace's TTL.",
+                    "type": "integer"
+                },
                 "allow_user_autostart": {
                     "description": "AllowUserAutostart and AllowUserAutostop are enterprise-only. Their\nvalues are only used if your license is entitled to use the advanced\ntemplate scheduling feature.",
                     "type": "boolean"
@@ -9480,6 +14225,14 @@ const docTemplate = `{
                 "allow_user_cancel_workspace_jobs": {
                     "type": "boolean"
                 },
+                "apply_dotfiles": {
+                    "description": "ApplyDotfiles is whether the agents of the template apply the dotfiles\nrepository of the workspace owner when they start.",
+                    "type": "boolean"
+                },
+                "archive_retention_ms": {
+                    "description": "ArchiveRetentionMillis is how long workspaces that reached the locked\nTTL are archived for before they are deleted. Owners can restore\narchived workspaces by unlocking them. Zero deletes workspaces without\narchiving them.",
+                    "type": "integer"
+                },
                 "build_time_stats": {
                     "$ref": "#/definitions/codersdk.TemplateBuildTimeStats"
                 },
@@ -9500,9 +14253,21 @@ const docTemplate = `{
                 "description": {
                     "type": "string"
                 },
+                "disable_autostop": {
+                    "description": "DisableAutostop is whether workspaces of the template never stop\nautomatically when they have no TTL, even if the organization has a\ndefault autostop.",
+                    "type": "boolean"
+                },
                 "display_name": {
                     "type": "string"
                 },
+                "failure_retries": {
+                    "description": "FailureRetries is the number of times a failed workspace start is\nretried before the workspace is stopped because of the failure TTL.\nThe owner is notified once the retries are exhausted.",
+                    "type": "integer"
+                },
+                "failure_retry_backoff_ms": {
+                    "description": "FailureRetryBackoffMillis is how long to wait after a failed workspace\nstart before it is retried. It doubles after every retry.",
+                    "type": "integer"
+                },
                 "failure_ttl_ms": {
                     "description": "FailureTTLMillis, InactivityTTLMillis, and LockedTTLMillis are enterprise-only. Their\nvalues are used if your license is entitled to use the advanced\ntemplate scheduling feature.",
                     "type": "integer"
@@ -9517,7 +14282,23 @@ const docTemplate = `{
                 "inactivity_ttl_ms": {
                     "type": "integer"
                 },
-                "locked_ttl_ms": {
+                "locked_ttl_ms": {
+                    "type": "integer"
+                },
+                "max_concurrent_builds": {
+                    "description": "MaxConcurrentBuilds is the maximum number of workspace builds of the\ntemplate that provisioners run at the same time. Other builds wait in\nthe queue. If zero, the number of builds is not limited.",
+                    "type": "integer"
+                },
+                "max_deadline_extension_ms": {
+                    "description": "MaxDeadlineExtensionMillis is the maximum duration that users can extend\nthe deadline of a workspace by at once. If zero, extensions are only\nlimited by the max deadline of the workspace build.",
+                    "type": "integer"
+                },
+                "max_deadline_extensions_per_day": {
+                    "description": "MaxDeadlineExtensionsPerDay is the maximum number of times that users\ncan extend the deadline of a workspace within 24 hours. If zero, the\nnumber of extensions is not limited.",
+                    "type": "integer"
+                },
+                "max_lifetime_ms": {
+                    "description": "MaxLifetimeMillis is how long after their creation workspaces are\ndeleted, regardless of their activity. If the template has a locked\nTTL, workspaces are locked instead. Zero disables the max lifetime.",
                     "type": "integer"
                 },
                 "max_ttl_ms": {
@@ -9532,10 +14313,11 @@ const docTemplate = `{
                     "format": "uuid"
                 },
                 "provisioner": {
-                    "type": "string",
-                    "enum": [
-                        "terraform"
-                    ]
+                    "type": "string"
+                },
+                "required_promotion_approvals": {
+                    "description": "RequiredPromotionApprovals is the number of approvals a template version\npromotion needs before the version becomes active. If zero, versions\ncan be made active directly.",
+                    "type": "integer"
                 },
                 "restart_requirement": {
                     "description": "RestartRequirement is an enterprise feature. Its value is only used if\nyour license is entitled to use the advanced template scheduling feature.",
@@ -9548,6 +14330,16 @@ const docTemplate = `{
                 "updated_at": {
                     "type": "string",
                     "format": "date-time"
+                },
+                "user_cpu_quota_millicores": {
+                    "description": "UserCPUQuotaMillicores, UserMemoryQuotaBytes and UserDiskQuotaBytes\nlimit the resources that the workspaces of the template owned by a\nsingle user can use in total. Templates declare the usage of resources\nwith \"cpu\", \"memory\" and \"disk\" metadata items. Workspaces that would\nexceed a quota fail to start. If zero, the usage is not limited.",
+                    "type": "integer"
+                },
+                "user_disk_quota_bytes": {
+                    "type": "integer"
+                },
+                "user_memory_quota_bytes": {
+                    "type": "integer"
                 }
             }
         },
@@ -9601,6 +14393,66 @@ const docTemplate = `{
                 "$ref": "#/definitions/codersdk.TransitionStats"
             }
         },
+        "codersdk.TemplateDeadlineRecalculation": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "processed_builds": {
+                    "description": "ProcessedBuilds is the number of workspace builds that have been\nprocessed so far.",
+                    "type": "integer"
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "running",
+                        "succeeded",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.TemplateDeadlineRecalculationStatus"
+                        }
+                    ]
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "total_builds": {
+                    "description": "TotalBuilds is the number of active workspace builds of the template.\nIt is zero until the builds have been fetched.",
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.TemplateDeadlineRecalculationStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "TemplateDeadlineRecalculationStatusPending",
+                "TemplateDeadlineRecalculationStatusRunning",
+                "TemplateDeadlineRecalculationStatusSucceeded",
+                "TemplateDeadlineRecalculationStatusFailed"
+            ]
+        },
         "codersdk.TemplateExample": {
             "type": "object",
             "properties": {
@@ -9708,6 +14560,28 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TemplateParameterConstraint": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error is the message shown if the constraint isn't satisfied.",
+                    "type": "string"
+                },
+                "parameter": {
+                    "type": "string"
+                },
+                "regex": {
+                    "type": "string"
+                },
+                "when_parameter": {
+                    "description": "WhenParameter and WhenRegex limit the constraint to builds where the\nvalue of WhenParameter matches WhenRegex. If WhenParameter is empty,\nthe constraint applies to all builds.",
+                    "type": "string"
+                },
+                "when_regex": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.TemplateParameterUsage": {
             "type": "object",
             "properties": {
@@ -9744,6 +14618,25 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TemplateParameterValidation": {
+            "type": "object",
+            "properties": {
+                "constraints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateParameterConstraint"
+                    }
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "webhook_url": {
+                    "description": "WebhookURL receives a POST request with a ParameterValidationRequest\nfor every build that starts a workspace. The build fails if the webhook\nresponds with validation errors, a status other than 200 OK, or doesn't\nrespond in time. If empty, no webhook is called.",
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.TemplateParameterValue": {
             "type": "object",
             "properties": {
@@ -9755,11 +14648,43 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TemplatePrebuilds": {
+            "type": "object",
+            "properties": {
+                "building": {
+                    "description": "Building is the number of prebuilt workspaces that are being built.",
+                    "type": "integer"
+                },
+                "ready": {
+                    "description": "Ready is the number of prebuilt workspaces that can be assigned to\nusers.",
+                    "type": "integer"
+                },
+                "size": {
+                    "description": "Size is the number of prebuilt workspaces the template keeps. 0\ndisables prebuilds.",
+                    "type": "integer"
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.TemplateRegistryAuth": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.TemplateRestartRequirement": {
             "type": "object",
             "properties": {
                 "days_of_week": {
-                    "description": "DaysOfWeek is a list of days of the week on which restarts are required.\nRestarts happen within the user's quiet hours (in their configured\ntimezone). If no days are specified, restarts are not required. Weekdays\ncannot be specified twice.\n\nRestarts will only happen on weekdays in this list on weeks which line up\nwith Weeks.",
+                    "description": "DaysOfWeek is a list of days of the week on which restarts are required.\nRestarts happen within the user's quiet hours (in their configured\ntimezone, unless Timezone is set). If no days are specified, restarts\nare not required. Weekdays cannot be specified twice.\n\nRestarts will only happen on weekdays in this list on weeks which line up\nwith Weeks.",
                     "type": "array",
                     "items": {
                         "type": "string",
@@ -9774,6 +14699,18 @@ const docTemplate = `{
                         ]
                     }
                 },
+                "spread_ms": {
+                    "description": "SpreadMillis is the duration after the start of the user's quiet hours\nthat required restarts are spread across. Each workspace is assigned a\nstable offset within this window, so workspaces don't all restart at\nthe same time. If zero, all workspaces restart at the start of the quiet\nhours.",
+                    "type": "integer"
+                },
+                "timezone": {
+                    "description": "Timezone is an optional IANA timezone (e.g. \"Europe/London\") that the\nuser's quiet hours are evaluated in. If empty, the timezone of the\nuser's quiet hours schedule is used. Setting this anchors restarts to a\nsingle timezone across all users of the template.",
+                    "type": "string"
+                },
+                "update_on_restart": {
+                    "description": "UpdateOnRestart starts workspaces that were stopped because of the\nrestart requirement again, updated to the active template version. If\nfalse, these workspaces stay stopped until their users start them.",
+                    "type": "boolean"
+                },
                 "weeks": {
                     "description": "Weeks is the number of weeks between required restarts. Weeks are synced\nacross all workspaces (and Coder deployments) using modulo math on a\nhardcoded epoch week of January 2nd, 2023 (the first Monday of 2023).\nValues of 0 or 1 indicate weekly restarts. Values of 2 indicate\nfortnightly restarts, etc.",
                     "type": "integer"
@@ -9793,6 +14730,59 @@ const docTemplate = `{
                 "TemplateRoleDeleted"
             ]
         },
+        "codersdk.TemplateScheduleInsight": {
+            "type": "object",
+            "properties": {
+                "allocated_seconds": {
+                    "description": "AllocatedSeconds is the time the workspaces existed, which is how long\nthey would have been running if they were never stopped.",
+                    "type": "integer",
+                    "example": 604800
+                },
+                "autostarts": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "autostops": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "running_seconds": {
+                    "type": "integer",
+                    "example": 201600
+                },
+                "schedule_stopped_seconds": {
+                    "description": "ScheduleStoppedSeconds is the part of the stopped time after the\nworkspaces were stopped by autostop or the restart requirement of the\ntemplate, which is the compute time saved by the schedule.",
+                    "type": "integer",
+                    "example": 345600
+                },
+                "stopped_seconds": {
+                    "type": "integer",
+                    "example": 403200
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.TemplateSchedulePolicy": {
+            "type": "object",
+            "properties": {
+                "inherited_by": {
+                    "description": "InheritedBy are the templates that inherit their schedule settings\nfrom this template.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
+                "policy_template_id": {
+                    "description": "PolicyTemplateID is the template that the schedule settings are\ninherited from. If nil, the template uses its own schedule settings.",
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.TemplateUser": {
             "type": "object",
             "required": [
@@ -9914,6 +14904,53 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TemplateVersionBuildInsight": {
+            "type": "object",
+            "properties": {
+                "agent_connect_p50_ms": {
+                    "description": "AgentConnectP50MS is the median time from the creation of the agents of\nthe builds until they first connected.",
+                    "type": "number",
+                    "example": 12000
+                },
+                "agent_connect_p95_ms": {
+                    "type": "number",
+                    "example": 31000
+                },
+                "build_duration_p50_ms": {
+                    "type": "number",
+                    "example": 45000
+                },
+                "build_duration_p95_ms": {
+                    "type": "number",
+                    "example": 98000
+                },
+                "builds": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "failed_builds": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "failure_rate": {
+                    "description": "FailureRate is the fraction of builds that failed, between 0 and 1.",
+                    "type": "number",
+                    "example": 0.05
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "template_version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "template_version_name": {
+                    "type": "string",
+                    "example": "vigorous_wozniak"
+                }
+            }
+        },
         "codersdk.TemplateVersionGitAuth": {
             "type": "object",
             "properties": {
@@ -9982,42 +15019,125 @@ const docTemplate = `{
                 "validation_max": {
                     "type": "integer"
                 },
-                "validation_min": {
+                "validation_min": {
+                    "type": "integer"
+                },
+                "validation_monotonic": {
+                    "enum": [
+                        "increasing",
+                        "decreasing"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ValidationMonotonicOrder"
+                        }
+                    ]
+                },
+                "validation_regex": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateVersionParameterOption": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateVersionPreset": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parameters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceBuildParameter"
+                    }
+                }
+            }
+        },
+        "codersdk.TemplateVersionPromotion": {
+            "type": "object",
+            "properties": {
+                "approved_by": {
+                    "description": "ApprovedBy are the IDs of the users that approved the promotion.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "requested_by": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "required_approvals": {
+                    "description": "RequiredApprovals is the number of approvals the template currently\nrequires.",
                     "type": "integer"
                 },
-                "validation_monotonic": {
+                "status": {
                     "enum": [
-                        "increasing",
-                        "decreasing"
+                        "pending",
+                        "promoted",
+                        "rejected"
                     ],
                     "allOf": [
                         {
-                            "$ref": "#/definitions/codersdk.ValidationMonotonicOrder"
+                            "$ref": "#/definitions/codersdk.TemplateVersionPromotionStatus"
                         }
                     ]
                 },
-                "validation_regex": {
-                    "type": "string"
-                }
-            }
-        },
-        "codersdk.TemplateVersionParameterOption": {
-            "type": "object",
-            "properties": {
-                "description": {
-                    "type": "string"
-                },
-                "icon": {
-                    "type": "string"
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
                 },
-                "name": {
-                    "type": "string"
+                "template_version_id": {
+                    "type": "string",
+                    "format": "uuid"
                 },
-                "value": {
-                    "type": "string"
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
                 }
             }
         },
+        "codersdk.TemplateVersionPromotionStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "promoted",
+                "rejected"
+            ],
+            "x-enum-varnames": [
+                "TemplateVersionPromotionStatusPending",
+                "TemplateVersionPromotionStatusPromoted",
+                "TemplateVersionPromotionStatusRejected"
+            ]
+        },
         "codersdk.TemplateVersionVariable": {
             "type": "object",
             "properties": {
@@ -10066,6 +15186,80 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TokenExchangePolicy": {
+            "type": "object",
+            "properties": {
+                "audience": {
+                    "description": "Audience must be in the aud claim of the identity tokens.",
+                    "type": "string"
+                },
+                "claims": {
+                    "description": "Claims maps claim names to the patterns their values must match, in the\nsyntax of path.Match. At least one claim is required, otherwise every\nidentity token of the issuer would be accepted.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "issuer": {
+                    "description": "Issuer is the issuer URL of the identity tokens, e.g.\nhttps://token.actions.githubusercontent.com for GitHub Actions.",
+                    "type": "string"
+                },
+                "lifetime": {
+                    "description": "Lifetime is how long the API tokens are valid, an hour by default.",
+                    "type": "integer"
+                },
+                "scope": {
+                    "description": "Scope is the scope of the API tokens, all by default.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.APIKeyScope"
+                        }
+                    ]
+                },
+                "username": {
+                    "description": "Username is the user the API tokens are created for.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TokenExchangeRequest": {
+            "type": "object",
+            "required": [
+                "grant_type",
+                "subject_token"
+            ],
+            "properties": {
+                "grant_type": {
+                    "type": "string"
+                },
+                "subject_token": {
+                    "type": "string"
+                },
+                "subject_token_type": {
+                    "description": "SubjectTokenType is an ID token if it's empty.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TokenExchangeResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "description": "AccessToken is the API token, it's used like a session token.",
+                    "type": "string"
+                },
+                "expires_in": {
+                    "description": "ExpiresIn is the number of seconds the API token is valid.",
+                    "type": "integer"
+                },
+                "issued_token_type": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.TraceConfig": {
             "type": "object",
             "properties": {
@@ -10133,6 +15327,77 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateCustomRoleRequest": {
+            "type": "object",
+            "required": [
+                "display_name"
+            ],
+            "properties": {
+                "display_name": {
+                    "type": "string"
+                },
+                "site_permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.Permission"
+                    }
+                },
+                "user_permissions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.Permission"
+                    }
+                }
+            }
+        },
+        "codersdk.UpdateOrganizationBudgetSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "critical_threshold_percent": {
+                    "type": "integer"
+                },
+                "daily_budget": {
+                    "type": "integer"
+                },
+                "warning_threshold_percent": {
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.UpdateOrganizationJITProvisioningSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "workspace_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.UpdateOrganizationProvisionerSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "max_concurrent_jobs": {
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.UpdateOrganizationScheduleSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "default_quiet_hours_schedule": {
+                    "type": "string"
+                },
+                "default_ttl_ms": {
+                    "type": "integer"
+                },
+                "restart_requirement": {
+                    "$ref": "#/definitions/codersdk.TemplateRestartRequirement"
+                }
+            }
+        },
         "codersdk.UpdateRoles": {
             "type": "object",
             "properties": {
@@ -10144,6 +15409,34 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateSavedWorkspaceFilterRequest": {
+            "type": "object",
+            "required": [
+                "query"
+            ],
+            "properties": {
+                "query": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.UpdateScheduleHolidayRequest": {
+            "type": "object",
+            "required": [
+                "date",
+                "name"
+            ],
+            "properties": {
+                "date": {
+                    "description": "Date is the calendar date of the holiday in YYYY-MM-DD format.",
+                    "type": "string",
+                    "format": "date"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.UpdateTemplateACL": {
             "type": "object",
             "properties": {
@@ -10171,6 +15464,49 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateTemplateParameterValidationRequest": {
+            "type": "object",
+            "properties": {
+                "constraints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateParameterConstraint"
+                    }
+                },
+                "webhook_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.UpdateTemplatePrebuildsRequest": {
+            "type": "object",
+            "properties": {
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.UpdateTemplateSchedulePolicyRequest": {
+            "type": "object",
+            "properties": {
+                "policy_template_id": {
+                    "description": "PolicyTemplateID is the template to inherit the schedule settings\nfrom. It must be in the same organization. If nil, the template uses\nits own schedule settings again.",
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.UpdateUserDotfilesRequest": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string"
+                },
+                "repo_url": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.UpdateUserPasswordRequest": {
             "type": "object",
             "required": [
@@ -10208,10 +15544,37 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateUserSecretRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.UpdateWorkspaceACL": {
+            "type": "object",
+            "properties": {
+                "group_perms": {
+                    "description": "GroupPerms should be a mapping of group id to role. An empty role stops\nsharing the workspace with the group.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/codersdk.WorkspaceRole"
+                    },
+                    "example": {
+                        "8bd26b20-f3e8-48be-a903-46bb920cf671": "read"
+                    }
+                }
+            }
+        },
         "codersdk.UpdateWorkspaceAutostartRequest": {
             "type": "object",
             "properties": {
                 "schedule": {
+                    "description": "Schedule is either a cron expression such as\n\"CRON_TZ=Europe/Berlin 0 9 * * 1-5\", or a human-readable schedule such\nas \"weekdays 9am Europe/Berlin\" which is stored as the equivalent cron\nexpression. Human-readable schedules without a timezone use UTC.",
                     "type": "string"
                 }
             }
@@ -10232,6 +15595,20 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateWorkspaceScheduleOverrideRequest": {
+            "type": "object",
+            "properties": {
+                "default_ttl_ms": {
+                    "type": "integer"
+                },
+                "max_ttl_ms": {
+                    "type": "integer"
+                },
+                "restart_requirement": {
+                    "$ref": "#/definitions/codersdk.TemplateRestartRequirement"
+                }
+            }
+        },
         "codersdk.UpdateWorkspaceTTLRequest": {
             "type": "object",
             "properties": {
@@ -10249,6 +15626,17 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpsertOrganizationTemplateVariableRequest": {
+            "type": "object",
+            "properties": {
+                "sensitive": {
+                    "type": "boolean"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.User": {
             "type": "object",
             "required": [
@@ -10310,6 +15698,27 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UserDotfiles": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "description": "Branch is the branch to check out. If empty, the default branch of the\nrepository is used.",
+                    "type": "string"
+                },
+                "repo_url": {
+                    "description": "RepoURL is the URL of the repository. It's empty if the user hasn't\nconfigured a dotfiles repository.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.UserLatency": {
             "type": "object",
             "properties": {
@@ -10389,6 +15798,13 @@ const docTemplate = `{
         "codersdk.UserQuietHoursScheduleResponse": {
             "type": "object",
             "properties": {
+                "exceptions": {
+                    "description": "Exceptions are the dates in YYYY-MM-DD format on which the quiet hours\nwindow is skipped, ordered by date. Template restart requirements that\nfall on one of these dates are moved to the next applicable day.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "next": {
                     "description": "Next is the next time that the quiet hours window will start.",
                     "type": "string",
@@ -10411,6 +15827,51 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UserQuota": {
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "description": "Budget is -1 when quotas are not licensed.",
+                    "type": "integer"
+                },
+                "credits_consumed": {
+                    "type": "integer"
+                },
+                "workspaces": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceQuotaUsage"
+                    }
+                }
+            }
+        },
+        "codersdk.UserSecret": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.UserStatus": {
             "type": "string",
             "enum": [
@@ -10461,6 +15922,32 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WebhooksConfig": {
+            "type": "object",
+            "properties": {
+                "autostop_imminent_window": {
+                    "type": "integer"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "parameter_validation_hosts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "signing_secret": {
+                    "type": "string"
+                },
+                "urls": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "codersdk.Workspace": {
             "type": "object",
             "properties": {
@@ -10495,6 +15982,19 @@ const docTemplate = `{
                 "latest_build": {
                     "$ref": "#/definitions/codersdk.WorkspaceBuild"
                 },
+                "lock_reason": {
+                    "description": "LockReason is why the workspace was locked. It is empty if the\nworkspace isn't locked.",
+                    "enum": [
+                        "manual",
+                        "inactivity",
+                        "max_lifetime"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceLockReason"
+                        }
+                    ]
+                },
                 "locked_at": {
                     "description": "LockedAt being non-nil indicates a workspace that has been locked.\nA locked workspace is no longer accessible by a user and must be\nunlocked by an admin. It is subject to deletion if it breaches\nthe duration of the locked_ttl field on its template.",
                     "type": "string",
@@ -10542,6 +16042,17 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceACL": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceGroup"
+                    }
+                }
+            }
+        },
         "codersdk.WorkspaceAgent": {
             "type": "object",
             "properties": {
@@ -10686,6 +16197,17 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceAgentDevcontainer": {
+            "type": "object",
+            "properties": {
+                "config_path": {
+                    "type": "string"
+                },
+                "workspace_folder": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.WorkspaceAgentHealth": {
             "type": "object",
             "properties": {
@@ -10769,6 +16291,9 @@ const docTemplate = `{
                 },
                 "output": {
                     "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/codersdk.WorkspaceAgentLogSource"
                 }
             }
         },
@@ -10780,7 +16305,9 @@ const docTemplate = `{
                 "kubernetes",
                 "envbox",
                 "envbuilder",
-                "external"
+                "external",
+                "dotfiles",
+                "devcontainer"
             ],
             "x-enum-varnames": [
                 "WorkspaceAgentLogSourceStartupScript",
@@ -10788,9 +16315,22 @@ const docTemplate = `{
                 "WorkspaceAgentLogSourceKubernetes",
                 "WorkspaceAgentLogSourceEnvbox",
                 "WorkspaceAgentLogSourceEnvbuilder",
-                "WorkspaceAgentLogSourceExternal"
+                "WorkspaceAgentLogSourceExternal",
+                "WorkspaceAgentLogSourceDotfiles",
+                "WorkspaceAgentLogSourceDevcontainer"
             ]
         },
+        "codersdk.WorkspaceAgentMetadata": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "$ref": "#/definitions/codersdk.WorkspaceAgentMetadataDescription"
+                },
+                "result": {
+                    "$ref": "#/definitions/codersdk.WorkspaceAgentMetadataResult"
+                }
+            }
+        },
         "codersdk.WorkspaceAgentMetadataDescription": {
             "type": "object",
             "properties": {
@@ -10803,6 +16343,10 @@ const docTemplate = `{
                 "key": {
                     "type": "string"
                 },
+                "plugin": {
+                    "description": "Plugin is true if the metadata is collected by a metadata plugin of the\nagent instead of being defined in the template.",
+                    "type": "boolean"
+                },
                 "script": {
                     "type": "string"
                 },
@@ -10811,6 +16355,81 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceAgentMetadataResult": {
+            "type": "object",
+            "properties": {
+                "age": {
+                    "description": "Age is the number of seconds since the metadata was collected.\nIt is provided in addition to CollectedAt to protect against clock skew.",
+                    "type": "integer"
+                },
+                "collected_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentScript": {
+            "type": "object",
+            "properties": {
+                "continue_on_error": {
+                    "type": "boolean"
+                },
+                "ended_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "exit_code": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "run_on_start": {
+                    "type": "boolean"
+                },
+                "run_on_stop": {
+                    "type": "boolean"
+                },
+                "script": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "status": {
+                    "$ref": "#/definitions/codersdk.WorkspaceAgentScriptStatus"
+                },
+                "timeout_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.WorkspaceAgentScriptStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "ok",
+                "error",
+                "timed_out",
+                "skipped"
+            ],
+            "x-enum-varnames": [
+                "WorkspaceAgentScriptStatusPending",
+                "WorkspaceAgentScriptStatusRunning",
+                "WorkspaceAgentScriptStatusOK",
+                "WorkspaceAgentScriptStatusError",
+                "WorkspaceAgentScriptStatusTimedOut",
+                "WorkspaceAgentScriptStatusSkipped"
+            ]
+        },
         "codersdk.WorkspaceAgentStartupScriptBehavior": {
             "type": "string",
             "enum": [
@@ -10840,6 +16459,18 @@ const docTemplate = `{
         "codersdk.WorkspaceApp": {
             "type": "object",
             "properties": {
+                "auth": {
+                    "description": "Auth is how users that are not signed in to Coder authenticate with\nthe app. With \"oidc\", anyone that logs in with the workspace app OIDC\nprovider may use the app.",
+                    "enum": [
+                        "coder",
+                        "oidc"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceAppAuth"
+                        }
+                    ]
+                },
                 "command": {
                     "type": "string"
                 },
@@ -10896,6 +16527,17 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceAppAuth": {
+            "type": "string",
+            "enum": [
+                "coder",
+                "oidc"
+            ],
+            "x-enum-varnames": [
+                "WorkspaceAppAuthCoder",
+                "WorkspaceAppAuthOIDC"
+            ]
+        },
         "codersdk.WorkspaceAppHealth": {
             "type": "string",
             "enum": [
@@ -10911,17 +16553,126 @@ const docTemplate = `{
                 "WorkspaceAppHealthUnhealthy"
             ]
         },
-        "codersdk.WorkspaceAppSharingLevel": {
+        "codersdk.WorkspaceAppOIDCConfig": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string"
+                },
+                "client_secret": {
+                    "type": "string"
+                },
+                "email_domain": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "issuer_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.WorkspaceAppSharingLevel": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "authenticated",
+                "public"
+            ],
+            "x-enum-varnames": [
+                "WorkspaceAppSharingLevelOwner",
+                "WorkspaceAppSharingLevelAuthenticated",
+                "WorkspaceAppSharingLevelPublic"
+            ]
+        },
+        "codersdk.WorkspaceAppStatsConfig": {
+            "type": "object",
+            "properties": {
+                "access_logs": {
+                    "type": "boolean"
+                },
+                "otlp_endpoint": {
+                    "type": "string"
+                },
+                "statsd_address": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.WorkspaceBatch": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "operation": {
+                    "enum": [
+                        "start",
+                        "stop",
+                        "update",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.BatchWorkspaceOperation"
+                        }
+                    ]
+                },
+                "q": {
+                    "type": "string"
+                },
+                "results": {
+                    "description": "Results are in the order the workspaces are processed in.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.BatchWorkspaceResult"
+                    }
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "running",
+                        "completed",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceBatchStatus"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "codersdk.WorkspaceBatchStatus": {
             "type": "string",
             "enum": [
-                "owner",
-                "authenticated",
-                "public"
+                "pending",
+                "running",
+                "completed",
+                "failed"
             ],
             "x-enum-varnames": [
-                "WorkspaceAppSharingLevelOwner",
-                "WorkspaceAppSharingLevelAuthenticated",
-                "WorkspaceAppSharingLevelPublic"
+                "WorkspaceBatchStatusPending",
+                "WorkspaceBatchStatusRunning",
+                "WorkspaceBatchStatusCompleted",
+                "WorkspaceBatchStatusFailed"
             ]
         },
         "codersdk.WorkspaceBuild": {
@@ -10963,7 +16714,15 @@ const docTemplate = `{
                     "enum": [
                         "initiator",
                         "autostart",
-                        "autostop"
+                        "autostop",
+                        "restart_requirement",
+                        "failure_ttl",
+                        "inactivity_ttl",
+                        "locked_ttl",
+                        "admin_forced",
+                        "max_lifetime",
+                        "prebuild",
+                        "resume"
                     ],
                     "allOf": [
                         {
@@ -11007,7 +16766,8 @@ const docTemplate = `{
                     "enum": [
                         "start",
                         "stop",
-                        "delete"
+                        "delete",
+                        "archive"
                     ],
                     "allOf": [
                         {
@@ -11046,6 +16806,92 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceBuildTimeline": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceBuildTimelineEntry"
+                    }
+                },
+                "trace_id": {
+                    "description": "TraceID is the ID of the trace of the provisioner job of the build, to\nfind its spans in the tracing backend. It's empty if the job wasn't\ntraced.",
+                    "type": "string"
+                },
+                "workspace_build_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.WorkspaceBuildTimelineEntry": {
+            "type": "object",
+            "properties": {
+                "agent_id": {
+                    "description": "AgentID and AgentName are set for the stages of agents.",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "agent_name": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "description": "DurationMS is the duration of the stage so far if it's still running.",
+                    "type": "integer"
+                },
+                "ended_at": {
+                    "description": "EndedAt is nil if the stage is still running.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "script_name": {
+                    "description": "ScriptName is the name of the script of the startup_script stage.",
+                    "type": "string"
+                },
+                "stage": {
+                    "enum": [
+                        "queued",
+                        "init",
+                        "plan",
+                        "apply",
+                        "agent_connect",
+                        "agent_startup",
+                        "startup_script"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceBuildTimelineStage"
+                        }
+                    ]
+                },
+                "started_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "codersdk.WorkspaceBuildTimelineStage": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "init",
+                "plan",
+                "apply",
+                "agent_connect",
+                "agent_startup",
+                "startup_script"
+            ],
+            "x-enum-varnames": [
+                "WorkspaceBuildTimelineStageQueued",
+                "WorkspaceBuildTimelineStageInit",
+                "WorkspaceBuildTimelineStagePlan",
+                "WorkspaceBuildTimelineStageApply",
+                "WorkspaceBuildTimelineStageAgentConnect",
+                "WorkspaceBuildTimelineStageAgentStartup",
+                "WorkspaceBuildTimelineStageStartupScript"
+            ]
+        },
         "codersdk.WorkspaceConnectionLatencyMS": {
             "type": "object",
             "properties": {
@@ -11086,6 +16932,51 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceGroup": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.User"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "quota_allowance": {
+                    "type": "integer"
+                },
+                "role": {
+                    "enum": [
+                        "read",
+                        "full"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceRole"
+                        }
+                    ]
+                },
+                "source": {
+                    "$ref": "#/definitions/codersdk.GroupSource"
+                }
+            }
+        },
         "codersdk.WorkspaceHealth": {
             "type": "object",
             "properties": {
@@ -11104,6 +16995,19 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceLockReason": {
+            "type": "string",
+            "enum": [
+                "manual",
+                "inactivity",
+                "max_lifetime"
+            ],
+            "x-enum-varnames": [
+                "WorkspaceLockReasonManual",
+                "WorkspaceLockReasonInactivity",
+                "WorkspaceLockReasonMaxLifetime"
+            ]
+        },
         "codersdk.WorkspaceProxy": {
             "type": "object",
             "properties": {
@@ -11189,6 +17093,41 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceQuotaUsage": {
+            "type": "object",
+            "properties": {
+                "daily_cost": {
+                    "type": "integer"
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "template_name": {
+                    "type": "string"
+                },
+                "transition": {
+                    "enum": [
+                        "start",
+                        "stop",
+                        "delete",
+                        "archive"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceTransition"
+                        }
+                    ]
+                },
+                "workspace_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "workspace_name": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.WorkspaceResource": {
             "type": "object",
             "properties": {
@@ -11235,7 +17174,8 @@ const docTemplate = `{
                     "enum": [
                         "start",
                         "stop",
-                        "delete"
+                        "delete",
+                        "archive"
                     ],
                     "allOf": [
                         {
@@ -11259,6 +17199,19 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceRole": {
+            "type": "string",
+            "enum": [
+                "read",
+                "full",
+                ""
+            ],
+            "x-enum-varnames": [
+                "WorkspaceRoleRead",
+                "WorkspaceRoleFull",
+                "WorkspaceRoleDeleted"
+            ]
+        },
         "codersdk.WorkspaceStatus": {
             "type": "string",
             "enum": [
@@ -11291,14 +17244,42 @@ const docTemplate = `{
             "enum": [
                 "start",
                 "stop",
-                "delete"
+                "delete",
+                "archive"
             ],
             "x-enum-varnames": [
                 "WorkspaceTransitionStart",
                 "WorkspaceTransitionStop",
-                "WorkspaceTransitionDelete"
+                "WorkspaceTransitionDelete",
+                "WorkspaceTransitionArchive"
             ]
         },
+        "codersdk.WorkspaceScheduleOverride": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "default_ttl_ms": {
+                    "type": "integer"
+                },
+                "max_ttl_ms": {
+                    "type": "integer"
+                },
+                "restart_requirement": {
+                    "$ref": "#/definitions/codersdk.TemplateRestartRequirement"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "workspace_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.WorkspacesResponse": {
             "type": "object",
             "properties": {
@@ -11765,6 +17746,41 @@ const docTemplate = `{
         "url.Userinfo": {
             "type": "object"
         },
+        "workspaceapps.AccessLog": {
+            "type": "object",
+            "properties": {
+                "access_method": {
+                    "$ref": "#/definitions/workspaceapps.AccessMethod"
+                },
+                "agent_id": {
+                    "type": "string"
+                },
+                "latency": {
+                    "description": "Latency is the time it took to proxy the request. For WebSocket\nconnections this is the duration of the connection.",
+                    "type": "integer"
+                },
+                "path_prefix": {
+                    "description": "PathPrefix is the first segment of the requested path, e.g. \"/api\" for\n\"/api/users\". Only the prefix is logged to avoid logging IDs and other\nsensitive data that is commonly part of paths.",
+                    "type": "string"
+                },
+                "slug_or_port": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "description": "The user that made the request, uuid.Nil if they were not signed in to Coder.",
+                    "type": "string"
+                },
+                "workspace_id": {
+                    "type": "string"
+                }
+            }
+        },
         "workspaceapps.AccessMethod": {
             "type": "string",
             "enum": [
@@ -11796,6 +17812,10 @@ const docTemplate = `{
                 "app_request": {
                     "$ref": "#/definitions/workspaceapps.Request"
                 },
+                "oidc_session": {
+                    "description": "OIDCSession is the signed session of a user that logged in with the\nworkspace app OIDC provider, if any. It is only accepted for apps that\nuse OIDC auth.",
+                    "type": "string"
+                },
                 "path_app_base_url": {
                     "description": "PathAppBaseURL is required.",
                     "type": "string"
@@ -11838,6 +17858,10 @@ const docTemplate = `{
                 "access_method": {
                     "$ref": "#/definitions/workspaceapps.AccessMethod"
                 },
+                "active_duration": {
+                    "description": "ActiveDuration is the time the app was in use during the session. For\nrolled up sessions this is the combined duration of the sessions, not\ncounting time in between them.",
+                    "type": "integer"
+                },
                 "agent_id": {
                     "type": "string"
                 },
@@ -11865,6 +17889,24 @@ const docTemplate = `{
                 }
             }
         },
+        "wsproxysdk.ACMECertificate": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "domain": {
+                    "type": "string"
+                },
+                "not_after": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
         "wsproxysdk.AgentIsLegacyResponse": {
             "type": "object",
             "properties": {
@@ -11938,6 +17980,10 @@ const docTemplate = `{
         "wsproxysdk.RegisterWorkspaceProxyResponse": {
             "type": "object",
             "properties": {
+                "app_access_logs": {
+                    "description": "AppAccessLogs is true if the proxy should report the access logs of\nworkspace app requests.",
+                    "type": "boolean"
+                },
                 "app_security_key": {
                     "type": "string"
                 },
@@ -11953,12 +17999,24 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/codersdk.Replica"
                     }
+                },
+                "token_expires_at": {
+                    "description": "TokenExpiresAt is when the token the proxy registered with expires. It's\nnil for tokens that don't expire, e.g. tokens created by users.",
+                    "type": "string",
+                    "format": "date-time"
                 }
             }
         },
         "wsproxysdk.ReportAppStatsRequest": {
             "type": "object",
             "properties": {
+                "access_logs": {
+                    "description": "AccessLogs are only sent if the primary enabled access logs, see\nRegisterWorkspaceProxyResponse.AppAccessLogs.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/workspaceapps.AccessLog"
+                    }
+                },
                 "stats": {
                     "type": "array",
                     "items": {
@@ -11966,6 +18024,19 @@ const docTemplate = `{
                     }
                 }
             }
+        },
+        "wsproxysdk.RotateWorkspaceProxyTokenResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "proxy_token": {
+                    "description": "ProxyToken replaces the token the request was made with. The replaced\ntoken stays valid for a while, so all replicas of the proxy can switch\nto the new one.",
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -55,9 +56,23 @@ func (api *API) postToken(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	scope := database.APIKeyScopeAll
-	if scope != "" {
+	if createToken.Scope != "" {
 		scope = database.APIKeyScope(createToken.Scope)
 	}
+	if !scope.Valid() {
+		var scopes []string
+		for _, scope := range database.AllAPIKeyScopeValues() {
+			scopes = append(scopes, string(scope))
+		}
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Invalid scope %q.", createToken.Scope),
+			Validations: []codersdk.ValidationError{{
+				Field:  "scope",
+				Detail: fmt.Sprintf("Must be one of %s.", strings.Join(scopes, ", ")),
+			}},
+		})
+		return
+	}
 
 	// default lifetime is 30 days
 	lifeTime := 30 * 24 * time.Hour
@@ -64,9 +64,7 @@ func Generate(params CreateParams) (database.InsertAPIKeyParams, string, error)
 	if params.Scope != "" {
 		scope = params.Scope
 	}
-	switch scope {
-	case database.APIKeyScopeAll, database.APIKeyScopeApplicationConnect:
-	default:
+	if !scope.Valid() {
 		return database.InsertAPIKeyParams{}, "", xerrors.Errorf("invalid API key scope: %q", scope)
 	}
 
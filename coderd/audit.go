@@ -260,6 +260,13 @@ func auditLogDescription(alog database.GetAuditLogsOffsetRow) string {
 		return str
 	}
 
+	// Provisioner daemons that log in with a provisioner key don't have a user.
+	if alog.ResourceType == database.ResourceTypeProvisionerKey && alog.Action == database.AuditActionLogin {
+		return fmt.Sprintf("A provisioner daemon %s with %s {target}",
+			codersdk.AuditAction(alog.Action).Friendly(),
+			codersdk.ResourceType(alog.ResourceType).FriendlyString())
+	}
+
 	// We don't display the name (target) for git ssh keys. It's fairly long and doesn't
 	// make too much sense to display.
 	if alog.ResourceType == database.ResourceTypeGitSshKey {
@@ -11,6 +11,7 @@ type Auditable interface {
 	database.APIKey |
 		database.Template |
 		database.TemplateVersion |
+		database.TemplateVersionPromotion |
 		database.User |
 		database.Workspace |
 		database.GitSSHKey |
@@ -18,7 +19,11 @@ type Auditable interface {
 		database.AuditableGroup |
 		database.License |
 		database.WorkspaceProxy |
-		database.AuditOAuthConvertState
+		database.AuditOAuthConvertState |
+		database.ProvisionerKey |
+		database.TemplateParameterValidation |
+		database.OrganizationTemplateVariable |
+		database.CustomRole
 }
 
 // Map is a map of changed fields in an audited resource. It maps field names to
@@ -51,6 +51,10 @@ type BuildAuditParams[T Auditable] struct {
 	Status           int
 	Action           database.AuditAction
 	AdditionalFields json.RawMessage
+	// IP and UserAgent are optional, and are set if the action was initiated
+	// by a client that isn't a user, such as a provisioner daemon.
+	IP        string
+	UserAgent string
 
 	New T
 	Old T
@@ -62,6 +66,8 @@ func ResourceTarget[T Auditable](tgt T) string {
 		return typed.Name
 	case database.TemplateVersion:
 		return typed.Name
+	case database.TemplateVersionPromotion:
+		return typed.ID.String()
 	case database.User:
 		return typed.Username
 	case database.Workspace:
@@ -86,6 +92,14 @@ func ResourceTarget[T Auditable](tgt T) string {
 		return typed.Name
 	case database.AuditOAuthConvertState:
 		return string(typed.ToLoginType)
+	case database.ProvisionerKey:
+		return typed.Name
+	case database.TemplateParameterValidation:
+		return typed.TemplateID.String()
+	case database.OrganizationTemplateVariable:
+		return typed.Name
+	case database.CustomRole:
+		return typed.Name
 	default:
 		panic(fmt.Sprintf("unknown resource %T", tgt))
 	}
@@ -97,6 +111,8 @@ func ResourceID[T Auditable](tgt T) uuid.UUID {
 		return typed.ID
 	case database.TemplateVersion:
 		return typed.ID
+	case database.TemplateVersionPromotion:
+		return typed.ID
 	case database.User:
 		return typed.ID
 	case database.Workspace:
@@ -116,6 +132,14 @@ func ResourceID[T Auditable](tgt T) uuid.UUID {
 	case database.AuditOAuthConvertState:
 		// The merge state is for the given user
 		return typed.UserID
+	case database.ProvisionerKey:
+		return typed.ID
+	case database.TemplateParameterValidation:
+		return typed.TemplateID
+	case database.OrganizationTemplateVariable:
+		return typed.OrganizationID
+	case database.CustomRole:
+		return uuid.Nil
 	default:
 		panic(fmt.Sprintf("unknown resource %T", tgt))
 	}
@@ -127,6 +151,8 @@ func ResourceType[T Auditable](tgt T) database.ResourceType {
 		return database.ResourceTypeTemplate
 	case database.TemplateVersion:
 		return database.ResourceTypeTemplateVersion
+	case database.TemplateVersionPromotion:
+		return database.ResourceTypeTemplateVersionPromotion
 	case database.User:
 		return database.ResourceTypeUser
 	case database.Workspace:
@@ -145,6 +171,14 @@ func ResourceType[T Auditable](tgt T) database.ResourceType {
 		return database.ResourceTypeWorkspaceProxy
 	case database.AuditOAuthConvertState:
 		return database.ResourceTypeConvertLogin
+	case database.ProvisionerKey:
+		return database.ResourceTypeProvisionerKey
+	case database.TemplateParameterValidation:
+		return database.ResourceTypeTemplateParameterValidation
+	case database.OrganizationTemplateVariable:
+		return database.ResourceTypeOrganizationTemplateVariable
+	case database.CustomRole:
+		return database.ResourceTypeCustomRole
 	default:
 		panic(fmt.Sprintf("unknown resource %T", typed))
 	}
@@ -246,7 +280,8 @@ func InitRequest[T Auditable](w http.ResponseWriter, p *RequestParams) (*Request
 func BuildAudit[T Auditable](ctx context.Context, p *BuildAuditParams[T]) {
 	// As the audit request has not been initiated directly by a user, we omit
 	// certain user details.
-	ip := parseIP("")
+	ip := parseIP(p.IP)
+	userAgent := sql.NullString{String: p.UserAgent, Valid: p.UserAgent != ""}
 
 	diff := Diff(p.Audit, p.Old, p.New)
 	var err error
@@ -265,7 +300,7 @@ func BuildAudit[T Auditable](ctx context.Context, p *BuildAuditParams[T]) {
 		Time:             database.Now(),
 		UserID:           p.UserID,
 		Ip:               ip,
-		UserAgent:        sql.NullString{},
+		UserAgent:        userAgent,
 		ResourceType:     either(p.Old, p.New, ResourceType[T], p.Action),
 		ResourceID:       either(p.Old, p.New, ResourceID[T], p.Action),
 		ResourceTarget:   either(p.Old, p.New, ResourceTarget[T], p.Action),
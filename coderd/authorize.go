@@ -195,6 +195,12 @@ func (api *API) checkAuthorization(rw http.ResponseWriter, r *http.Request) {
 					dbObj = wrkSpace.ExecutionRBAC()
 				}
 				dbErr = err
+			case rbac.ResourceWorkspaceApplicationConnect.Type:
+				wrkSpace, err := api.Database.GetWorkspaceByID(ctx, id)
+				if err == nil {
+					dbObj = wrkSpace.ApplicationConnectRBAC()
+				}
+				dbErr = err
 			case rbac.ResourceWorkspace.Type:
 				dbObj, dbErr = api.Database.GetWorkspaceByID(ctx, id)
 			case rbac.ResourceTemplate.Type:
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/xerrors"
 
@@ -15,11 +16,17 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/db2sdk"
 	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/prebuilds"
 	"github.com/coder/coder/coderd/schedule"
+	"github.com/coder/coder/coderd/webhooks"
 	"github.com/coder/coder/coderd/wsbuilder"
 	"github.com/coder/coder/codersdk"
 )
 
+// lifetimeExpiringWindow is how long before a workspace reaches the max
+// lifetime of its template its owner is warned.
+const lifetimeExpiringWindow = 24 * time.Hour
+
 // Executor automatically starts or stops workspaces.
 type Executor struct {
 	ctx                   context.Context
@@ -28,6 +35,29 @@ type Executor struct {
 	log                   slog.Logger
 	tick                  <-chan time.Time
 	statsCh               chan<- Stats
+	metrics               Metrics
+
+	webhooks               *webhooks.Dispatcher
+	autostopImminentWindow time.Duration
+	// notifiedImminent maps build IDs to the deadline an autostop imminent
+	// event was last sent for. It is only accessed from runOnce. Executors on
+	// other replicas have their own map, so with multiple replicas an event
+	// may be sent more than once.
+	notifiedImminent map[uuid.UUID]time.Time
+	// notifiedStartFailed maps the IDs of failed builds a start failed event
+	// was sent for to the time their workspace will be stopped. Unlike
+	// notifiedImminent it is accessed from the concurrent workspace updates
+	// in runOnce, so it is guarded by notifiedStartFailedMu.
+	notifiedStartFailedMu sync.Mutex
+	notifiedStartFailed   map[uuid.UUID]time.Time
+	// notifiedLifetimeExpiring maps the IDs of workspaces a lifetime
+	// expiring event was sent for to the time they expire. Like
+	// notifiedStartFailed it is guarded by its own mutex.
+	notifiedLifetimeExpiringMu sync.Mutex
+	notifiedLifetimeExpiring   map[uuid.UUID]time.Time
+
+	agentDrainGracePeriod          time.Duration
+	agentInactiveDisconnectTimeout time.Duration
 }
 
 // Stats contains information about one run of Executor.
@@ -46,6 +76,11 @@ func NewExecutor(ctx context.Context, db database.Store, tss *atomic.Pointer[sch
 		templateScheduleStore: tss,
 		tick:                  tick,
 		log:                   log.Named("autobuild"),
+		metrics:               NewMetrics(prometheus.NewRegistry()),
+		notifiedImminent:      make(map[uuid.UUID]time.Time),
+		notifiedStartFailed:   make(map[uuid.UUID]time.Time),
+
+		notifiedLifetimeExpiring: make(map[uuid.UUID]time.Time),
 	}
 	return le
 }
@@ -57,6 +92,33 @@ func (e *Executor) WithStatsChannel(ch chan<- Stats) *Executor {
 	return e
 }
 
+// WithMetrics will cause Executor to register its metrics with reg.
+func (e *Executor) WithMetrics(reg prometheus.Registerer) *Executor {
+	e.metrics = NewMetrics(reg)
+	return e
+}
+
+// WithWebhooks will cause Executor to send webhook events to d when it stops
+// or deletes workspaces, once for every workspace that failed to start after
+// all retries allowed by its template, and once for every workspace that
+// reaches the max lifetime of its template within a day. If
+// autostopImminentWindow is
+// positive, an event is also sent once for every started workspace whose
+// deadline is within the window.
+func (e *Executor) WithWebhooks(d *webhooks.Dispatcher, autostopImminentWindow time.Duration) *Executor {
+	e.webhooks = d
+	e.autostopImminentWindow = autostopImminentWindow
+	return e
+}
+
+// WithAgentDrain will cause Executor to give connected agents the grace period
+// to drain before the workspaces it stops or deletes are torn down.
+func (e *Executor) WithAgentDrain(gracePeriod, agentInactiveDisconnectTimeout time.Duration) *Executor {
+	e.agentDrainGracePeriod = gracePeriod
+	e.agentInactiveDisconnectTimeout = agentInactiveDisconnectTimeout
+	return e
+}
+
 // Run will cause executor to start or stop workspaces on every
 // tick from its channel. It will stop when its context is Done, or when
 // its channel is closed.
@@ -98,6 +160,11 @@ func (e *Executor) runOnce(t time.Time) Stats {
 		stats.Elapsed = time.Since(t)
 		stats.Error = err
 	}()
+	start := time.Now()
+	e.metrics.TickLag.Set(start.Sub(t).Seconds())
+	defer func() {
+		e.metrics.TickDuration.Observe(time.Since(start).Seconds())
+	}()
 	currentTick := t.Truncate(time.Minute)
 
 	// TTL is set at the workspace level, and deadline at the workspace build level.
@@ -113,8 +180,10 @@ func (e *Executor) runOnce(t time.Time) Stats {
 	workspaces, err := e.db.GetWorkspacesEligibleForTransition(e.ctx, t)
 	if err != nil {
 		e.log.Error(e.ctx, "get workspaces for autostart or autostop", slog.Error(err))
+		e.metrics.Errors.Inc()
 		return stats
 	}
+	e.metrics.WorkspacesEvaluated.Set(float64(len(workspaces)))
 
 	// We only use errgroup here for convenience of API, not for early
 	// cancellation. This means we only return nil errors in th eg.Go.
@@ -123,16 +192,43 @@ func (e *Executor) runOnce(t time.Time) Stats {
 	eg.SetLimit(10)
 
 	for _, ws := range workspaces {
+		// Prebuilt workspaces are managed by the prebuilds controller until
+		// they are claimed.
+		if ws.OwnerID == prebuilds.OwnerID {
+			continue
+		}
 		wsID := ws.ID
 		log := e.log.With(slog.F("workspace_id", wsID))
 
 		eg.Go(func() error {
+			var (
+				event      webhooks.Event
+				eventWs    database.Workspace
+				eventBuild uuid.UUID
+				eventWhy   database.BuildReason
+				// built is the transition of the build made for the
+				// workspace, if any.
+				built database.WorkspaceTransition
+				// failedStop is when a workspace that failed to start and
+				// has no retries left will be stopped.
+				failedStop  *time.Time
+				failedWs    database.Workspace
+				failedBuild database.WorkspaceBuild
+				// expiresAt is when a workspace whose owner has to be
+				// warned reaches the max lifetime of its template.
+				expiresAt     *time.Time
+				expiringWs    database.Workspace
+				expiringBuild uuid.UUID
+			)
 			err := e.db.InTx(func(tx database.Store) error {
+				event, built = "", ""
+				failedStop, expiresAt = nil, nil
 				// Re-check eligibility since the first check was outside the
 				// transaction and the workspace settings may have changed.
 				ws, err := tx.GetWorkspaceByID(e.ctx, wsID)
 				if err != nil {
 					log.Error(e.ctx, "get workspace autostart failed", slog.Error(err))
+					e.metrics.Errors.Inc()
 					return nil
 				}
 
@@ -140,21 +236,53 @@ func (e *Executor) runOnce(t time.Time) Stats {
 				latestBuild, err := tx.GetLatestWorkspaceBuildByWorkspaceID(e.ctx, ws.ID)
 				if err != nil {
 					log.Warn(e.ctx, "get latest workspace build", slog.Error(err))
+					e.metrics.Errors.Inc()
 					return nil
 				}
 				templateSchedule, err := (*(e.templateScheduleStore.Load())).Get(e.ctx, tx, ws.TemplateID)
 				if err != nil {
 					log.Warn(e.ctx, "get template schedule options", slog.Error(err))
+					e.metrics.Errors.Inc()
 					return nil
 				}
 
 				latestJob, err := tx.GetProvisionerJobByID(e.ctx, latestBuild.JobID)
 				if err != nil {
 					log.Warn(e.ctx, "get last provisioner job for workspace %q: %w", slog.Error(err))
+					e.metrics.Errors.Inc()
 					return nil
 				}
 
-				nextTransition, reason, err := getNextTransition(ws, latestBuild, latestJob, templateSchedule, currentTick)
+				// Owners are warned before their workspace reaches the
+				// max lifetime of its template.
+				if !ws.LockedAt.Valid && templateSchedule.MaxLifetime > 0 {
+					expiry := ws.CreatedAt.Add(templateSchedule.MaxLifetime)
+					if currentTick.Before(expiry) && expiry.Sub(currentTick) <= lifetimeExpiringWindow {
+						expiresAt, expiringWs, expiringBuild = &expiry, ws, latestBuild.ID
+					}
+				}
+
+				// Workspaces stopped by the restart requirement are started
+				// again on the active version if the template asks for it.
+				updateOnRestart := isEligibleForUpdateOnRestart(ws, latestBuild, latestJob, templateSchedule)
+
+				// Failed starts are retried before the workspace is stopped
+				// because of the failure TTL.
+				var failureRetries int32
+				if isFailedStart(latestBuild, latestJob) && templateSchedule.FailureTTL > 0 {
+					failureRetries, err = countFailureRetries(e.ctx, tx, latestBuild, templateSchedule.FailureRetries)
+					if err != nil {
+						log.Warn(e.ctx, "count failure retries", slog.Error(err))
+						e.metrics.Errors.Inc()
+						return nil
+					}
+					if failureRetries >= templateSchedule.FailureRetries && latestJob.CompletedAt.Valid {
+						stopAt := latestJob.CompletedAt.Time.Add(templateSchedule.FailureTTL)
+						failedStop, failedWs, failedBuild = &stopAt, ws, latestBuild
+					}
+				}
+
+				nextTransition, reason, err := getNextTransition(ws, latestBuild, latestJob, templateSchedule, updateOnRestart, failureRetries, currentTick)
 				if err != nil {
 					log.Debug(e.ctx, "skipping workspace", slog.Error(err))
 					return nil
@@ -164,46 +292,96 @@ func (e *Executor) runOnce(t time.Time) Stats {
 					builder := wsbuilder.New(ws, nextTransition).
 						SetLastWorkspaceBuildInTx(&latestBuild).
 						SetLastWorkspaceBuildJobInTx(&latestJob).
-						Reason(reason)
+						Reason(reason).
+						DrainAgents(e.agentDrainGracePeriod, e.agentInactiveDisconnectTimeout)
+					if updateOnRestart {
+						builder = builder.ActiveVersion()
+					}
 
-					if _, _, err := builder.Build(e.ctx, tx, nil); err != nil {
+					build, _, err := builder.Build(e.ctx, tx, nil)
+					if err != nil {
 						log.Error(e.ctx, "unable to transition workspace",
 							slog.F("transition", nextTransition),
 							slog.Error(err),
 						)
+						e.metrics.Errors.Inc()
 						return nil
 					}
+
+					switch nextTransition {
+					case database.WorkspaceTransitionStop:
+						event = webhooks.EventWorkspaceStopped
+					case database.WorkspaceTransitionArchive:
+						event = webhooks.EventWorkspaceArchived
+					case database.WorkspaceTransitionDelete:
+						switch reason {
+						case database.BuildReasonLockedTTL:
+							event = webhooks.EventWorkspaceDeletedDueToLockedTTL
+						case database.BuildReasonMaxLifetime:
+							event = webhooks.EventWorkspaceDeletedDueToMaxLifetime
+						}
+					}
+					eventWs, eventBuild, eventWhy = ws, build.ID, reason
+					built = nextTransition
 				}
 
 				// Lock the workspace if it has breached the template's
-				// threshold for inactivity.
-				if reason == database.BuildReasonAutolock {
+				// threshold for inactivity, or if it reached the max
+				// lifetime and the template archives expired workspaces.
+				if reason == database.BuildReasonInactivityTTL ||
+					(reason == database.BuildReasonMaxLifetime && nextTransition != database.WorkspaceTransitionDelete) {
+					lockReason := database.WorkspaceLockReasonMaxLifetime
+					if reason == database.BuildReasonInactivityTTL {
+						lockReason = database.WorkspaceLockReasonInactivity
+					}
 					ws, err = tx.UpdateWorkspaceLockedDeletingAt(e.ctx, database.UpdateWorkspaceLockedDeletingAtParams{
 						ID: ws.ID,
 						LockedAt: sql.NullTime{
 							Time:  database.Now(),
 							Valid: true,
 						},
+						LockReason: database.NullWorkspaceLockReason{
+							WorkspaceLockReason: lockReason,
+							Valid:               true,
+						},
 					})
 					if err != nil {
 						log.Error(e.ctx, "unable to lock workspace",
 							slog.F("transition", nextTransition),
 							slog.Error(err),
 						)
+						e.metrics.Errors.Inc()
 						return nil
 					}
 
 					log.Info(e.ctx, "locked workspace",
+						slog.F("reason", reason),
 						slog.F("last_used_at", ws.LastUsedAt),
 						slog.F("inactivity_ttl", templateSchedule.InactivityTTL),
 						slog.F("since_last_used_at", time.Since(ws.LastUsedAt)),
 					)
 				}
 
-				if reason == database.BuildReasonAutodelete {
-					log.Info(e.ctx, "deleted workspace",
-						slog.F("locked_at", ws.LockedAt.Time),
-						slog.F("locked_ttl", templateSchedule.LockedTTL),
+				if reason == database.BuildReasonLockedTTL {
+					if nextTransition == database.WorkspaceTransitionArchive {
+						log.Info(e.ctx, "archived workspace",
+							slog.F("locked_at", ws.LockedAt.Time),
+							slog.F("locked_ttl", templateSchedule.LockedTTL),
+							slog.F("deleting_at", ws.DeletingAt.Time),
+						)
+					} else {
+						log.Info(e.ctx, "deleted workspace",
+							slog.F("locked_at", ws.LockedAt.Time),
+							slog.F("locked_ttl", templateSchedule.LockedTTL),
+						)
+					}
+				}
+
+				if reason == database.BuildReasonMaxLifetime {
+					log.Info(e.ctx, "workspace reached max lifetime",
+						slog.F("transition", nextTransition),
+						slog.F("created_at", ws.CreatedAt),
+						slog.F("max_lifetime", templateSchedule.MaxLifetime),
 					)
 				}
 
@@ -226,7 +404,31 @@ func (e *Executor) runOnce(t time.Time) Stats {
 				// as our calculation that determines whether an autobuild is necessary.
 			}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
 			if err != nil {
+				// The error was already counted when it happened in the
+				// transaction, which fails the commit in Postgres.
 				log.Error(e.ctx, "workspace scheduling failed", slog.Error(err))
+				return nil
+			}
+			if built != "" {
+				e.metrics.Builds.WithLabelValues(string(built), string(eventWhy)).Inc()
+			}
+			// Only send events once the transaction has been committed,
+			// otherwise the transition may not have happened. The owner is
+			// notified about the failure before the workspace is stopped.
+			if failedStop != nil && e.webhooks != nil && e.markStartFailedNotified(failedBuild.ID, *failedStop) {
+				e.webhooks.Dispatch(webhooks.EventWorkspaceStartFailed, e.webhookWorkspace(failedWs, failedBuild.ID, failedBuild.Reason, failedStop))
+			}
+			if expiresAt != nil && e.webhooks != nil && e.markLifetimeExpiringNotified(expiringWs.ID, *expiresAt) {
+				e.webhooks.Dispatch(webhooks.EventWorkspaceLifetimeExpiring, e.webhookWorkspace(expiringWs, expiringBuild, database.BuildReasonMaxLifetime, expiresAt))
+			}
+			if event != "" && e.webhooks != nil {
+				payload := e.webhookWorkspace(eventWs, eventBuild, eventWhy, nil)
+				// Owners of archived workspaces have until the workspace
+				// is deleted to restore it.
+				if event == webhooks.EventWorkspaceArchived && eventWs.DeletingAt.Valid {
+					payload.DeletingAt = &eventWs.DeletingAt.Time
+				}
+				e.webhooks.Dispatch(event, payload)
 			}
 			return nil
 		})
@@ -238,20 +440,152 @@ func (e *Executor) runOnce(t time.Time) Stats {
 		e.log.Error(e.ctx, "workspace scheduling errgroup failed", slog.Error(err))
 	}
 
+	if e.webhooks != nil && e.autostopImminentWindow > 0 {
+		e.notifyImminentAutostops(t)
+	}
+
+	// Forget failed builds whose workspace has been stopped.
+	e.notifiedStartFailedMu.Lock()
+	for id, stopAt := range e.notifiedStartFailed {
+		if stopAt.Before(t) {
+			delete(e.notifiedStartFailed, id)
+		}
+	}
+	e.notifiedStartFailedMu.Unlock()
+
+	// Forget workspaces that have expired.
+	e.notifiedLifetimeExpiringMu.Lock()
+	for id, expiresAt := range e.notifiedLifetimeExpiring {
+		if expiresAt.Before(t) {
+			delete(e.notifiedLifetimeExpiring, id)
+		}
+	}
+	e.notifiedLifetimeExpiringMu.Unlock()
+
 	return stats
 }
 
+// notifyImminentAutostops sends an autostop imminent webhook event for every
+// started workspace that will reach its deadline within the configured
+// window. Each deadline is only notified once, if activity bumps the deadline
+// and it enters the window again another event is sent.
+func (e *Executor) notifyImminentAutostops(t time.Time) {
+	workspaces, err := e.db.GetWorkspacesWithImminentAutostop(e.ctx, database.GetWorkspacesWithImminentAutostopParams{
+		Now:    t,
+		Before: t.Add(e.autostopImminentWindow),
+	})
+	if err != nil {
+		e.log.Error(e.ctx, "get workspaces with imminent autostop", slog.Error(err))
+		return
+	}
+
+	for _, row := range workspaces {
+		if deadline, ok := e.notifiedImminent[row.BuildID]; ok && deadline.Equal(row.BuildDeadline) {
+			continue
+		}
+		e.notifiedImminent[row.BuildID] = row.BuildDeadline
+		deadline := row.BuildDeadline
+		// The owner and template are returned by the query, so they aren't
+		// looked up for each workspace.
+		e.webhooks.Dispatch(webhooks.EventWorkspaceAutostopImminent, webhooks.Workspace{
+			ID:           row.Workspace.ID,
+			Name:         row.Workspace.Name,
+			OwnerID:      row.Workspace.OwnerID,
+			OwnerName:    row.OwnerUsername,
+			OwnerEmail:   row.OwnerEmail,
+			TemplateID:   row.Workspace.TemplateID,
+			TemplateName: row.TemplateName,
+			BuildID:      row.BuildID,
+			Deadline:     &deadline,
+			Reason:       string(database.BuildReasonAutostop),
+		})
+	}
+
+	// Forget deadlines that have passed, the workspace has either been
+	// stopped or its deadline was bumped.
+	for id, deadline := range e.notifiedImminent {
+		if deadline.Before(t) {
+			delete(e.notifiedImminent, id)
+		}
+	}
+}
+
+// markStartFailedNotified records that a start failed event is sent for the
+// failed build. It returns false if an event was already sent for the build.
+func (e *Executor) markStartFailedNotified(buildID uuid.UUID, stopAt time.Time) bool {
+	e.notifiedStartFailedMu.Lock()
+	defer e.notifiedStartFailedMu.Unlock()
+	if _, ok := e.notifiedStartFailed[buildID]; ok {
+		return false
+	}
+	e.notifiedStartFailed[buildID] = stopAt
+	return true
+}
+
+// markLifetimeExpiringNotified records that a lifetime expiring event is sent
+// for the workspace. It returns false if an event was already sent for the
+// workspace.
+func (e *Executor) markLifetimeExpiringNotified(workspaceID uuid.UUID, expiresAt time.Time) bool {
+	e.notifiedLifetimeExpiringMu.Lock()
+	defer e.notifiedLifetimeExpiringMu.Unlock()
+	if _, ok := e.notifiedLifetimeExpiring[workspaceID]; ok {
+		return false
+	}
+	e.notifiedLifetimeExpiring[workspaceID] = expiresAt
+	return true
+}
+
+// webhookWorkspace builds the workspace description for a webhook event.
+// Failing to look up the owner or template is not fatal, the event is still
+// sent with their IDs.
+func (e *Executor) webhookWorkspace(ws database.Workspace, buildID uuid.UUID, reason database.BuildReason, deadline *time.Time) webhooks.Workspace {
+	w := webhooks.Workspace{
+		ID:         ws.ID,
+		Name:       ws.Name,
+		OwnerID:    ws.OwnerID,
+		TemplateID: ws.TemplateID,
+		BuildID:    buildID,
+		Deadline:   deadline,
+		Reason:     string(reason),
+	}
+	// The autostart actor is not allowed to read users.
+	//nolint:gocritic // Webhook receivers need to know who owns the workspace.
+	ctx := dbauthz.AsSystemRestricted(e.ctx)
+	owner, err := e.db.GetUserByID(ctx, ws.OwnerID)
+	if err != nil {
+		e.log.Warn(e.ctx, "get workspace owner for webhook", slog.F("workspace_id", ws.ID), slog.Error(err))
+	} else {
+		w.OwnerName = owner.Username
+		w.OwnerEmail = owner.Email
+	}
+	template, err := e.db.GetTemplateByID(ctx, ws.TemplateID)
+	if err != nil {
+		e.log.Warn(e.ctx, "get workspace template for webhook", slog.F("workspace_id", ws.ID), slog.Error(err))
+	} else {
+		w.TemplateName = template.Name
+	}
+	return w
+}
+
 // getNextTransition returns the next eligible transition for the workspace
 // as well as the reason for why it is transitioning. It is possible
 // for this function to return a nil error as well as an empty transition.
 // In such cases it means no provisioning should occur but the workspace
 // may be "transitioning" to a new state (such as an inactive, stopped
 // workspace transitioning to the locked state).
+//
+// updateOnRestart must be true if the workspace was stopped by the restart
+// requirement and should be started again on the active version, see
+// isEligibleForUpdateOnRestart.
+// failureRetries is the number of times a failed start was already retried,
+// see countFailureRetries.
 func getNextTransition(
 	ws database.Workspace,
 	latestBuild database.WorkspaceBuild,
 	latestJob database.ProvisionerJob,
 	templateSchedule schedule.TemplateScheduleOptions,
+	updateOnRestart bool,
+	failureRetries int32,
 	currentTick time.Time,
 ) (
 	database.WorkspaceTransition,
@@ -259,23 +593,45 @@ func getNextTransition(
 	error,
 ) {
 	switch {
+	case isEligibleForMaxLifetime(ws, latestBuild, templateSchedule, currentTick):
+		// Templates with a locked TTL archive expired workspaces by
+		// stopping and locking them, they are deleted once the locked TTL
+		// has passed.
+		if templateSchedule.LockedTTL > 0 {
+			if latestBuild.Transition == database.WorkspaceTransitionStart {
+				return database.WorkspaceTransitionStop, database.BuildReasonMaxLifetime, nil
+			}
+			return "", database.BuildReasonMaxLifetime, nil
+		}
+		return database.WorkspaceTransitionDelete, database.BuildReasonMaxLifetime, nil
 	case isEligibleForAutostop(ws, latestBuild, latestJob, currentTick):
+		// The max deadline is enforced by the restart requirement if the
+		// template uses it.
+		if templateSchedule.UseRestartRequirement && reachedMaxDeadline(latestBuild) {
+			return database.WorkspaceTransitionStop, database.BuildReasonRestartRequirement, nil
+		}
 		return database.WorkspaceTransitionStop, database.BuildReasonAutostop, nil
+	case updateOnRestart:
+		return database.WorkspaceTransitionStart, database.BuildReasonRestartRequirement, nil
 	case isEligibleForAutostart(ws, latestBuild, latestJob, templateSchedule, currentTick):
 		return database.WorkspaceTransitionStart, database.BuildReasonAutostart, nil
-	case isEligibleForFailedStop(latestBuild, latestJob, templateSchedule, currentTick):
-		return database.WorkspaceTransitionStop, database.BuildReasonAutostop, nil
+	case isEligibleForFailedRetry(ws, latestBuild, latestJob, templateSchedule, failureRetries, currentTick):
+		return database.WorkspaceTransitionStart, database.BuildReasonFailureTTL, nil
+	case isEligibleForFailedStop(latestBuild, latestJob, templateSchedule, failureRetries, currentTick):
+		return database.WorkspaceTransitionStop, database.BuildReasonFailureTTL, nil
 	case isEligibleForLockedStop(ws, templateSchedule, currentTick):
 		// Only stop started workspaces.
 		if latestBuild.Transition == database.WorkspaceTransitionStart {
-			return database.WorkspaceTransitionStop, database.BuildReasonAutolock, nil
+			return database.WorkspaceTransitionStop, database.BuildReasonInactivityTTL, nil
 		}
 		// We shouldn't transition the workspace but we should still
 		// lock it.
-		return "", database.BuildReasonAutolock, nil
+		return "", database.BuildReasonInactivityTTL, nil
 
+	case isEligibleForArchive(ws, latestBuild, templateSchedule, currentTick):
+		return database.WorkspaceTransitionArchive, database.BuildReasonLockedTTL, nil
 	case isEligibleForDelete(ws, templateSchedule, currentTick):
-		return database.WorkspaceTransitionDelete, database.BuildReasonAutodelete, nil
+		return database.WorkspaceTransitionDelete, database.BuildReasonLockedTTL, nil
 	default:
 		return "", "", xerrors.Errorf("last transition not valid for autostart or autostop")
 	}
@@ -294,8 +650,9 @@ func isEligibleForAutostart(ws database.Workspace, build database.WorkspaceBuild
 	}
 
 	// If the last transition for the workspace was not 'stop' then the workspace
-	// cannot be started.
-	if build.Transition != database.WorkspaceTransitionStop {
+	// cannot be started. Archived workspaces are stopped too, and autostart
+	// once their owner restores them by unlocking them.
+	if build.Transition != database.WorkspaceTransitionStop && build.Transition != database.WorkspaceTransitionArchive {
 		return false
 	}
 
@@ -334,6 +691,33 @@ func isEligibleForAutostop(ws database.Workspace, build database.WorkspaceBuild,
 		!currentTick.Before(build.Deadline)
 }
 
+// isEligibleForUpdateOnRestart returns true if the workspace was stopped by
+// the restart requirement of a template that updates workspaces on restart.
+func isEligibleForUpdateOnRestart(ws database.Workspace, build database.WorkspaceBuild, job database.ProvisionerJob, templateSchedule schedule.TemplateScheduleOptions) bool {
+	// The restart requirement is only used if it's licensed, otherwise the
+	// max deadline comes from the max TTL.
+	if !templateSchedule.UseRestartRequirement || !templateSchedule.UpdateOnRestart {
+		return false
+	}
+
+	// If the workspace is locked we should not start it.
+	if ws.LockedAt.Valid {
+		return false
+	}
+
+	return build.Transition == database.WorkspaceTransitionStop &&
+		build.Reason == database.BuildReasonRestartRequirement &&
+		db2sdk.ProvisionerJobStatus(job) == codersdk.ProvisionerJobSucceeded
+}
+
+// reachedMaxDeadline returns true if the build is a start build that ran
+// until its max deadline, which is when the restart requirement stops it.
+func reachedMaxDeadline(build database.WorkspaceBuild) bool {
+	return build.Transition == database.WorkspaceTransitionStart &&
+		!build.MaxDeadline.IsZero() &&
+		!build.Deadline.Before(build.MaxDeadline)
+}
+
 // isEligibleForLockedStop returns true if the workspace should be locked
 // for breaching the inactivity threshold of the template.
 func isEligibleForLockedStop(ws database.Workspace, templateSchedule schedule.TemplateScheduleOptions, currentTick time.Time) bool {
@@ -345,6 +729,34 @@ func isEligibleForLockedStop(ws database.Workspace, templateSchedule schedule.Te
 		currentTick.Sub(ws.LastUsedAt) > templateSchedule.InactivityTTL
 }
 
+// isEligibleForMaxLifetime returns true if the workspace reached the max
+// lifetime of the template.
+func isEligibleForMaxLifetime(ws database.Workspace, build database.WorkspaceBuild, templateSchedule schedule.TemplateScheduleOptions, currentTick time.Time) bool {
+	// Locked workspaces were already archived.
+	return !ws.LockedAt.Valid &&
+		// Workspaces that are being deleted don't have to be deleted again.
+		build.Transition != database.WorkspaceTransitionDelete &&
+		// The template must specify a max lifetime.
+		templateSchedule.MaxLifetime > 0 &&
+		// The workspace must reach the max lifetime, regardless of activity.
+		!currentTick.Before(ws.CreatedAt.Add(templateSchedule.MaxLifetime))
+}
+
+// isEligibleForArchive returns true if the locked workspace breached the
+// locked TTL of a template that archives workspaces before deleting them.
+// The deleting_at of such workspaces includes the archive retention.
+func isEligibleForArchive(ws database.Workspace, build database.WorkspaceBuild, templateSchedule schedule.TemplateScheduleOptions, currentTick time.Time) bool {
+	// Only attempt to archive locked workspaces.
+	return ws.LockedAt.Valid && ws.DeletingAt.Valid &&
+		// The template must specify a locked_ttl and an archive retention.
+		templateSchedule.LockedTTL > 0 && templateSchedule.ArchiveRetention > 0 &&
+		// Workspaces are only archived once.
+		build.Transition != database.WorkspaceTransitionArchive &&
+		build.Transition != database.WorkspaceTransitionDelete &&
+		// The workspace must breach the locked_ttl.
+		currentTick.After(ws.DeletingAt.Time.Add(-templateSchedule.ArchiveRetention))
+}
+
 func isEligibleForDelete(ws database.Workspace, templateSchedule schedule.TemplateScheduleOptions, currentTick time.Time) bool {
 	// Only attempt to delete locked workspaces.
 	return ws.LockedAt.Valid && ws.DeletingAt.Valid &&
@@ -354,15 +766,71 @@ func isEligibleForDelete(ws database.Workspace, templateSchedule schedule.Templa
 		currentTick.After(ws.DeletingAt.Time)
 }
 
+// isEligibleForFailedRetry returns true if the failed start of the workspace
+// should be retried before it is stopped due to the failure TTL.
+func isEligibleForFailedRetry(ws database.Workspace, build database.WorkspaceBuild, job database.ProvisionerJob, templateSchedule schedule.TemplateScheduleOptions, failureRetries int32, currentTick time.Time) bool {
+	// If the workspace is locked we should not start it.
+	if ws.LockedAt.Valid {
+		return false
+	}
+
+	// If the template has specified a failure TTL.
+	return templateSchedule.FailureTTL > 0 &&
+		// And the failed start has retries left.
+		failureRetries < templateSchedule.FailureRetries &&
+		isFailedStart(build, job) &&
+		// And the backoff has elapsed since the job has completed. The
+		// backoff doubles after every retry.
+		job.CompletedAt.Valid &&
+		!currentTick.Before(job.CompletedAt.Time.Add(schedule.FailureRetryBackoff(templateSchedule.FailureRetryBackoff, failureRetries)))
+}
+
 // isEligibleForFailedStop returns true if the workspace is eligible to be stopped
 // due to a failed build.
-func isEligibleForFailedStop(build database.WorkspaceBuild, job database.ProvisionerJob, templateSchedule schedule.TemplateScheduleOptions, currentTick time.Time) bool {
+func isEligibleForFailedStop(build database.WorkspaceBuild, job database.ProvisionerJob, templateSchedule schedule.TemplateScheduleOptions, failureRetries int32, currentTick time.Time) bool {
 	// If the template has specified a failure TLL.
 	return templateSchedule.FailureTTL > 0 &&
 		// And the job resulted in failure.
-		db2sdk.ProvisionerJobStatus(job) == codersdk.ProvisionerJobFailed &&
-		build.Transition == database.WorkspaceTransitionStart &&
+		isFailedStart(build, job) &&
+		// And the failed start has no retries left.
+		failureRetries >= templateSchedule.FailureRetries &&
 		// And sufficient time has elapsed since the job has completed.
 		job.CompletedAt.Valid &&
 		currentTick.Sub(job.CompletedAt.Time) > templateSchedule.FailureTTL
 }
+
+// isFailedStart returns true if the build is a start build whose job failed.
+func isFailedStart(build database.WorkspaceBuild, job database.ProvisionerJob) bool {
+	return build.Transition == database.WorkspaceTransitionStart &&
+		db2sdk.ProvisionerJobStatus(job) == codersdk.ProvisionerJobFailed
+}
+
+// countFailureRetries returns the number of times the failed start of the
+// workspace was already retried, which is the number of failed start builds
+// directly before the latest build. Counting stops at maxRetries. Builds
+// started by users in between count as retries too.
+func countFailureRetries(ctx context.Context, db database.Store, latestBuild database.WorkspaceBuild, maxRetries int32) (int32, error) {
+	var retries int32
+	for retries < maxRetries {
+		buildNumber := latestBuild.BuildNumber - 1 - retries
+		if buildNumber < 1 {
+			break
+		}
+		build, err := db.GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx, database.GetWorkspaceBuildByWorkspaceIDAndBuildNumberParams{
+			WorkspaceID: latestBuild.WorkspaceID,
+			BuildNumber: buildNumber,
+		})
+		if err != nil {
+			return 0, xerrors.Errorf("get workspace build %d: %w", buildNumber, err)
+		}
+		job, err := db.GetProvisionerJobByID(ctx, build.JobID)
+		if err != nil {
+			return 0, xerrors.Errorf("get provisioner job of workspace build %d: %w", buildNumber, err)
+		}
+		if !isFailedStart(build, job) {
+			break
+		}
+		retries++
+	}
+	return retries, nil
+}
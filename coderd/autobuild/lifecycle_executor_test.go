@@ -2,11 +2,16 @@ package autobuild_test
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
@@ -18,9 +23,11 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/coderd/webhooks"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
 )
 
 func TestExecutorAutostartOK(t *testing.T) {
@@ -750,6 +757,145 @@ func TestExecutorInactiveWorkspace(t *testing.T) {
 	})
 }
 
+func TestExecutorMetrics(t *testing.T) {
+	t.Parallel()
+
+	var (
+		tickCh   = make(chan time.Time)
+		statsCh  = make(chan autobuild.Stats)
+		registry = prometheus.NewRegistry()
+		client   = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:          tickCh,
+			IncludeProvisionerDaemon: true,
+			AutobuildStats:           statsCh,
+			AutobuildMetrics:         registry,
+		})
+		// Given: we have a user with a running workspace
+		workspace = mustProvisionWorkspace(t, client)
+	)
+	require.NotZero(t, workspace.LatestBuild.Deadline)
+
+	// When: the autobuild executor ticks after the deadline
+	go func() {
+		tickCh <- workspace.LatestBuild.Deadline.Time.Add(time.Minute)
+		close(tickCh)
+	}()
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.Transitions, 1)
+
+	// Then: the stop is counted by transition and reason
+	metrics, err := registry.Gather()
+	require.NoError(t, err)
+	found := map[string]*dto.MetricFamily{}
+	for _, metric := range metrics {
+		found[metric.GetName()] = metric
+	}
+
+	require.Contains(t, found, "coderd_autobuild_workspaces_evaluated")
+	assert.EqualValues(t, 1, found["coderd_autobuild_workspaces_evaluated"].Metric[0].Gauge.GetValue())
+
+	require.Contains(t, found, "coderd_autobuild_builds_total")
+	builds := found["coderd_autobuild_builds_total"].Metric
+	require.Len(t, builds, 1)
+	labels := map[string]string{}
+	for _, label := range builds[0].Label {
+		labels[label.GetName()] = label.GetValue()
+	}
+	assert.Equal(t, map[string]string{
+		"transition": string(database.WorkspaceTransitionStop),
+		"reason":     string(database.BuildReasonAutostop),
+	}, labels)
+	assert.EqualValues(t, 1, builds[0].Counter.GetValue())
+
+	require.Contains(t, found, "coderd_autobuild_errors_total")
+	assert.Zero(t, found["coderd_autobuild_errors_total"].Metric[0].Counter.GetValue())
+
+	require.Contains(t, found, "coderd_autobuild_tick_duration_seconds")
+	assert.EqualValues(t, 1, found["coderd_autobuild_tick_duration_seconds"].Metric[0].Histogram.GetSampleCount())
+}
+
+func TestExecutorWebhooks(t *testing.T) {
+	t.Parallel()
+
+	payloads := make(chan webhooks.Payload, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhooks.Payload
+		if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		payloads <- payload
+	}))
+	t.Cleanup(srv.Close)
+	dispatcher, err := webhooks.New(slogtest.Make(t, nil), webhooks.Options{
+		URLs: []string{srv.URL},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = dispatcher.Close() })
+
+	var (
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan autobuild.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:          tickCh,
+			IncludeProvisionerDaemon: true,
+			AutobuildStats:           statsCh,
+			Webhooks:                 dispatcher,
+		})
+		// Given: we have a user with a running workspace
+		workspace = mustProvisionWorkspace(t, client)
+		deadline  = workspace.LatestBuild.Deadline.Time
+	)
+	require.NotZero(t, deadline)
+
+	awaitPayload := func() webhooks.Payload {
+		t.Helper()
+		select {
+		case payload := <-payloads:
+			return payload
+		case <-time.After(testutil.WaitShort):
+			t.Fatal("timed out waiting for webhook")
+			return webhooks.Payload{}
+		}
+	}
+
+	// When: the executor ticks twice within the autostop imminent window
+	tickCh <- deadline.Add(-10 * time.Minute)
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Empty(t, stats.Transitions)
+	tickCh <- deadline.Add(-5 * time.Minute)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+
+	// Then: a single autostop imminent event is sent
+	payload := awaitPayload()
+	assert.Equal(t, webhooks.EventWorkspaceAutostopImminent, payload.Event)
+	assert.Equal(t, workspace.ID, payload.Workspace.ID)
+	assert.Equal(t, workspace.OwnerName, payload.Workspace.OwnerName)
+	assert.Equal(t, workspace.TemplateName, payload.Workspace.TemplateName)
+	assert.Equal(t, workspace.LatestBuild.ID, payload.Workspace.BuildID)
+	if assert.NotNil(t, payload.Workspace.Deadline) {
+		assert.WithinDuration(t, deadline, *payload.Workspace.Deadline, time.Second)
+	}
+
+	// When: the executor ticks after the deadline
+	tickCh <- deadline.Add(time.Minute)
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Equal(t, database.WorkspaceTransitionStop, stats.Transitions[workspace.ID])
+	close(tickCh)
+
+	// Then: a stopped event is sent for the new build
+	payload = awaitPayload()
+	assert.Equal(t, webhooks.EventWorkspaceStopped, payload.Event)
+	assert.Equal(t, workspace.ID, payload.Workspace.ID)
+	assert.NotEqual(t, workspace.LatestBuild.ID, payload.Workspace.BuildID)
+	assert.Equal(t, string(database.BuildReasonAutostop), payload.Workspace.Reason)
+	assert.Nil(t, payload.Workspace.Deadline)
+}
+
 func mustProvisionWorkspace(t *testing.T, client *codersdk.Client, mut ...func(*codersdk.CreateWorkspaceRequest)) codersdk.Workspace {
 	t.Helper()
 	user := coderdtest.CreateFirstUser(t, client)
@@ -0,0 +1,66 @@
+package autobuild
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics are the Prometheus metrics of the Executor. They allow operators to
+// alert when the executor stops transitioning workspaces, e.g. when scheduled
+// stops stop happening.
+type Metrics struct {
+	WorkspacesEvaluated prometheus.Gauge
+	Builds              *prometheus.CounterVec
+	Errors              prometheus.Counter
+	TickDuration        prometheus.Histogram
+	TickLag             prometheus.Gauge
+}
+
+// NewMetrics registers the metrics of the Executor with reg.
+func NewMetrics(reg prometheus.Registerer) Metrics {
+	auto := promauto.With(reg)
+
+	return Metrics{
+		WorkspacesEvaluated: auto.NewGauge(prometheus.GaugeOpts{
+			Namespace: "coderd",
+			Subsystem: "autobuild",
+			Name:      "workspaces_evaluated",
+			Help:      "The number of workspaces evaluated for a transition on the last tick.",
+		}),
+		Builds: auto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "autobuild",
+			Name:      "builds_total",
+			Help:      "The number of workspace builds triggered by the autobuild executor.",
+		}, []string{"transition", "reason"}),
+		Errors: auto.NewCounter(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "autobuild",
+			Name:      "errors_total",
+			Help:      "The number of errors while evaluating or transitioning workspaces.",
+		}),
+		TickDuration: auto.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "coderd",
+			Subsystem: "autobuild",
+			Name:      "tick_duration_seconds",
+			Help:      "The time it took to evaluate and transition workspaces on a tick.",
+			Buckets: []float64{
+				0.01, // 10ms
+				0.05,
+				0.1,
+				0.5,
+				1, // 1s
+				5,
+				10,
+				30,
+				60, // 1min
+			},
+		}),
+		TickLag: auto.NewGauge(prometheus.GaugeOpts{
+			Namespace: "coderd",
+			Subsystem: "autobuild",
+			Name:      "tick_lag_seconds",
+			Help:      "The time between the last tick and when the executor started to handle it.",
+		}),
+	}
+}
@@ -158,6 +158,8 @@ func (b *Batcher) Add(
 	b.buf.SessionCountReconnectingPTY = append(b.buf.SessionCountReconnectingPTY, st.SessionCountReconnectingPTY)
 	b.buf.SessionCountSSH = append(b.buf.SessionCountSSH, st.SessionCountSSH)
 	b.buf.ConnectionMedianLatencyMS = append(b.buf.ConnectionMedianLatencyMS, st.ConnectionMedianLatencyMS)
+	b.buf.PeerCountP2P = append(b.buf.PeerCountP2P, st.PeerCountP2P)
+	b.buf.PeerCountDERP = append(b.buf.PeerCountDERP, st.PeerCountDERP)
 
 	// If the buffer is over 80% full, signal the flusher to flush immediately.
 	// We want to trigger flushes early to reduce the likelihood of
@@ -262,6 +264,8 @@ func (b *Batcher) initBuf(size int) {
 		SessionCountReconnectingPTY: make([]int64, 0, b.batchSize),
 		SessionCountSSH:             make([]int64, 0, b.batchSize),
 		ConnectionMedianLatencyMS:   make([]float64, 0, b.batchSize),
+		PeerCountP2P:                make([]int64, 0, b.batchSize),
+		PeerCountDERP:               make([]int64, 0, b.batchSize),
 	}
 
 	b.connectionsByProto = make([]map[string]int64, 0, size)
@@ -285,5 +289,7 @@ func (b *Batcher) resetBuf() {
 	b.buf.SessionCountReconnectingPTY = b.buf.SessionCountReconnectingPTY[:0]
 	b.buf.SessionCountSSH = b.buf.SessionCountSSH[:0]
 	b.buf.ConnectionMedianLatencyMS = b.buf.ConnectionMedianLatencyMS[:0]
+	b.buf.PeerCountP2P = b.buf.PeerCountP2P[:0]
+	b.buf.PeerCountDERP = b.buf.PeerCountDERP[:0]
 	b.connectionsByProto = b.connectionsByProto[:0]
 }
@@ -147,6 +147,8 @@ func randAgentSDKStats(t *testing.T, opts ...func(*agentsdk.Stats)) agentsdk.Sta
 		SessionCountJetBrains:       mustRandInt64n(t, 9) + 1,
 		SessionCountReconnectingPTY: mustRandInt64n(t, 9) + 1,
 		SessionCountSSH:             mustRandInt64n(t, 9) + 1,
+		PeerCountP2P:                mustRandInt64n(t, 9) + 1,
+		PeerCountDERP:               mustRandInt64n(t, 9) + 1,
 		Metrics:                     []agentsdk.AgentMetric{},
 	}
 	for _, opt := range opts {
@@ -53,14 +53,20 @@ import (
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/metricscache"
+	"github.com/coder/coder/coderd/parametervalidation"
+	"github.com/coder/coder/coderd/prebuilds"
 	"github.com/coder/coder/coderd/provisionerdserver"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/telemetry"
+	"github.com/coder/coder/coderd/templateregistry"
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/coderd/updatecheck"
+	"github.com/coder/coder/coderd/usersecrets"
 	"github.com/coder/coder/coderd/util/slice"
+	"github.com/coder/coder/coderd/webhooks"
 	"github.com/coder/coder/coderd/workspaceapps"
+	"github.com/coder/coder/coderd/workspacebatch"
 	"github.com/coder/coder/coderd/wsconncache"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
@@ -109,9 +115,12 @@ type Options struct {
 	GoogleTokenValidator           *idtoken.Validator
 	GithubOAuth2Config             *GithubOAuth2Config
 	OIDCConfig                     *OIDCConfig
+	TokenExchangePolicies          []TokenExchangePolicy
+	WorkspaceAppsOIDCConfig        *WorkspaceAppsOIDCConfig
 	PrometheusRegistry             *prometheus.Registry
 	SecureAuthCookie               bool
 	StrictTransportSecurityCfg     httpmw.HSTSConfig
+	WorkspaceProxyClientCertCfg    httpmw.WorkspaceProxyClientCertConfig
 	SSHKeygenAlgorithm             gitsshkey.Algorithm
 	Telemetry                      telemetry.Reporter
 	TracerProvider                 trace.TracerProvider
@@ -123,7 +132,8 @@ type Options struct {
 	TailnetCoordinator tailnet.Coordinator
 	DERPServer         *derp.Server
 	// BaseDERPMap is used as the base DERP map for all clients and agents.
-	// Proxies are added to this list.
+	// Proxies are added to this list. It can be replaced at runtime with
+	// API.SetBaseDERPMap.
 	BaseDERPMap                 *tailcfg.DERPMap
 	DERPMapUpdateFrequency      time.Duration
 	SwaggerEndpoint             bool
@@ -131,6 +141,9 @@ type Options struct {
 	SetUserSiteRoles            func(ctx context.Context, logger slog.Logger, tx database.Store, userID uuid.UUID, roles []string) error
 	TemplateScheduleStore       *atomic.Pointer[schedule.TemplateScheduleStore]
 	UserQuietHoursScheduleStore *atomic.Pointer[schedule.UserQuietHoursScheduleStore]
+	// ScheduleHolidays caches the schedule holidays skipped by template
+	// restart requirements.
+	ScheduleHolidays *schedule.HolidayCache
 	// AppSecurityKey is the crypto key used to sign and encrypt tokens related to
 	// workspace applications. It consists of both a signing and encryption key.
 	AppSecurityKey     workspaceapps.SecurityKey
@@ -143,6 +156,11 @@ type Options struct {
 	// So this secret should **never** be exposed to the client.
 	OAuthSigningKey [32]byte
 
+	// WorkspaceAppsOIDCEnabled is set by the enterprise license. Apps only
+	// accept users that logged in with WorkspaceAppsOIDCConfig while it's
+	// true.
+	WorkspaceAppsOIDCEnabled *atomic.Bool
+
 	// APIRateLimit is the minutely throughput rate limit per user or ip.
 	// Setting a rate limit <0 will disable the rate limiter across the entire
 	// app. Some specific routes have their own configurable rate limits.
@@ -164,6 +182,21 @@ type Options struct {
 	StatsBatcher       *batchstats.Batcher
 
 	WorkspaceAppsStatsCollectorOptions workspaceapps.StatsCollectorOptions
+	// WorkspaceAppsStatsSinks receive workspace app stats in addition to the
+	// database, e.g. to stream them to StatsD or an OTLP collector.
+	WorkspaceAppsStatsSinks []workspaceapps.StatsReporter
+	// ServerTailnetPoolOptions configures the agent connection pool of the
+	// server tailnet. It is only used with the single tailnet experiment.
+	ServerTailnetPoolOptions ServerTailnetPoolOptions
+	// Webhooks is used to send workspace events to external endpoints. Nil
+	// if no webhooks are configured.
+	Webhooks *webhooks.Dispatcher
+	// UserSecretsCipher encrypts the values of user secrets. User secrets
+	// are disabled if nil.
+	UserSecretsCipher *usersecrets.Cipher
+	// TemplateRegistries are the registries that templates can be pushed to
+	// and pulled from. Pushing and pulling is disabled if empty.
+	TemplateRegistries []templateregistry.Registry
 }
 
 // @title Coder API
@@ -286,6 +319,9 @@ func New(options *Options) *API {
 		v := schedule.NewAGPLTemplateScheduleStore()
 		options.TemplateScheduleStore.Store(&v)
 	}
+	if options.WorkspaceAppsOIDCEnabled == nil {
+		options.WorkspaceAppsOIDCEnabled = &atomic.Bool{}
+	}
 	if options.UserQuietHoursScheduleStore == nil {
 		options.UserQuietHoursScheduleStore = &atomic.Pointer[schedule.UserQuietHoursScheduleStore]{}
 	}
@@ -293,6 +329,9 @@ func New(options *Options) *API {
 		v := schedule.NewAGPLUserQuietHoursScheduleStore()
 		options.UserQuietHoursScheduleStore.Store(&v)
 	}
+	if options.ScheduleHolidays == nil {
+		options.ScheduleHolidays = schedule.NewHolidayCache(schedule.DefaultHolidayCacheTTL)
+	}
 
 	if options.StatsBatcher == nil {
 		panic("developer error: options.StatsBatcher is nil")
@@ -361,6 +400,7 @@ func New(options *Options) *API {
 			oauthConfigs,
 			options.AgentInactiveDisconnectTimeout,
 			options.AppSecurityKey,
+			options.WorkspaceAppsOIDCEnabled,
 		),
 		metricsCache:                metricsCache,
 		Auditor:                     atomic.Pointer[audit.Auditor]{},
@@ -368,7 +408,21 @@ func New(options *Options) *API {
 		UserQuietHoursScheduleStore: options.UserQuietHoursScheduleStore,
 		Experiments:                 experiments,
 		healthCheckGroup:            &singleflight.Group[string, *healthcheck.Report]{},
+		prebuildsClaimer:            prebuilds.NewClaimer(options.PrometheusRegistry),
+		parameterValidator: &parametervalidation.Validator{
+			HTTPClient:    options.HTTPClient,
+			SigningSecret: options.DeploymentValues.Webhooks.SigningSecret.String(),
+			AllowedHosts:  options.DeploymentValues.Webhooks.ParameterValidationHosts.Value(),
+		},
 	}
+	api.workspaceBatchRunner = workspacebatch.New(
+		ctx,
+		options.Logger.Named("workspacebatch"),
+		options.Database,
+		api.prepareWorkspaceBatch,
+		workspaceBatchBuildsPerSecond,
+		workspaceBatchBuildsBurst,
+	)
 	if options.UpdateCheckOptions != nil {
 		api.updateChecker = updatecheck.New(
 			options.Database,
@@ -398,10 +452,22 @@ func New(options *Options) *API {
 		oidcAuthURLParams = options.OIDCConfig.AuthURLParams
 	}
 
+	err = api.loadCustomRoles(ctx)
+	if err != nil {
+		panic(xerrors.Errorf("load custom roles: %w", err))
+	}
+	api.customRolesCancel, err = options.Pubsub.Subscribe(PubsubEventCustomRoles, func(ctx context.Context, message []byte) {
+		api.reloadCustomRole(ctx, string(message))
+	})
+	if err != nil {
+		panic(xerrors.Errorf("subscribe to custom role changes: %w", err))
+	}
+
 	api.Auditor.Store(&options.Auditor)
 	api.TailnetCoordinator.Store(&options.TailnetCoordinator)
+	api.baseDERPMap.Store(options.BaseDERPMap)
 	if api.Experiments.Enabled(codersdk.ExperimentSingleTailnet) {
-		api.agentProvider, err = NewServerTailnet(api.ctx,
+		stn, err := NewServerTailnet(api.ctx,
 			options.Logger,
 			options.DERPServer,
 			options.BaseDERPMap,
@@ -410,10 +476,14 @@ func New(options *Options) *API {
 			},
 			wsconncache.New(api._dialWorkspaceAgentTailnet, 0),
 			api.TracerProvider,
+			options.ServerTailnetPoolOptions,
 		)
 		if err != nil {
 			panic("failed to setup server tailnet: " + err.Error())
 		}
+		options.PrometheusRegistry.MustRegister(stn)
+		api.agentProvider = stn
+		api.serverTailnet = stn
 	} else {
 		api.agentProvider = &wsconncache.AgentProvider{
 			Cache: wsconncache.New(api._dialWorkspaceAgentTailnet, 0),
@@ -428,6 +498,20 @@ func New(options *Options) *API {
 	if options.WorkspaceAppsStatsCollectorOptions.Reporter == nil {
 		options.WorkspaceAppsStatsCollectorOptions.Reporter = workspaceapps.NewStatsDBReporter(options.Database, workspaceapps.DefaultStatsDBReporterBatchSize)
 	}
+	if len(options.WorkspaceAppsStatsSinks) > 0 {
+		options.WorkspaceAppsStatsCollectorOptions.Reporter = workspaceapps.NewStatsReporterFanout(
+			workspaceAppsLogger.Named("stats_fanout"),
+			options.WorkspaceAppsStatsCollectorOptions.Reporter,
+			options.WorkspaceAppsStatsSinks...,
+		)
+	}
+	if options.WorkspaceAppsStatsCollectorOptions.AccessLogReporter == nil && options.DeploymentValues.WorkspaceAppStats.AccessLogs.Value() {
+		options.WorkspaceAppsStatsCollectorOptions.AccessLogReporter = workspaceapps.NewStatsDBReporter(options.Database, workspaceapps.DefaultStatsDBReporterBatchSize)
+	}
+
+	if options.DeploymentValues.WorkspaceAppStickySessions.Value() {
+		api.WorkspaceAppReplicas = workspaceapps.NewReplicaSet(api.ID)
+	}
 
 	api.workspaceAppServer = &workspaceapps.Server{
 		Logger: workspaceAppsLogger,
@@ -442,6 +526,7 @@ func New(options *Options) *API {
 		AgentProvider:       api.agentProvider,
 		AppSecurityKey:      options.AppSecurityKey,
 		StatsCollector:      workspaceapps.NewStatsCollector(options.WorkspaceAppsStatsCollectorOptions),
+		Replicas:            api.WorkspaceAppReplicas,
 
 		DisablePathApps:  options.DeploymentValues.DisablePathApps.Value(),
 		SecureAuthCookie: options.DeploymentValues.SecureAuthCookie.Value(),
@@ -573,6 +658,10 @@ func New(options *Options) *API {
 			r.Use(apiKeyMiddleware)
 			r.Get("/regions", api.regions)
 		})
+		r.Route("/derp", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/health", api.derpHealth)
+		})
 		r.Route("/derp-map", func(r chi.Router) {
 			// r.Use(apiKeyMiddleware)
 			r.Get("/", api.derpMapUpdates)
@@ -623,6 +712,15 @@ func New(options *Options) *API {
 					httpmw.ExtractOrganizationParam(options.Database),
 				)
 				r.Get("/", api.organization)
+				r.Route("/settings/provisioners", func(r chi.Router) {
+					r.Get("/", api.organizationProvisionerSettings)
+					r.Put("/", api.putOrganizationProvisionerSettings)
+				})
+				r.Route("/settings/variables", func(r chi.Router) {
+					r.Get("/", api.organizationTemplateVariables)
+					r.Put("/{variable}", api.putOrganizationTemplateVariable)
+					r.Delete("/{variable}", api.deleteOrganizationTemplateVariable)
+				})
 				r.Post("/templateversions", api.postTemplateVersionsByOrganization)
 				r.Route("/templates", func(r chi.Router) {
 					r.Post("/", api.postTemplateByOrganization)
@@ -658,11 +756,24 @@ func New(options *Options) *API {
 			r.Get("/", api.template)
 			r.Delete("/", api.deleteTemplate)
 			r.Patch("/", api.patchTemplateMeta)
+			r.Get("/prebuilds", api.templatePrebuilds)
+			r.Put("/prebuilds", api.putTemplatePrebuilds)
+			r.Get("/parameter-validation", api.templateParameterValidation)
+			r.Put("/parameter-validation", api.putTemplateParameterValidation)
 			r.Route("/versions", func(r chi.Router) {
 				r.Get("/", api.templateVersionsByTemplate)
 				r.Patch("/", api.patchActiveTemplateVersion)
 				r.Get("/{templateversionname}", api.templateVersionByName)
 			})
+			r.Route("/promotions", func(r chi.Router) {
+				r.Get("/", api.templateVersionPromotions)
+				r.Post("/", api.postTemplateVersionPromotion)
+				r.Route("/{promotion}", func(r chi.Router) {
+					r.Get("/", api.templateVersionPromotion)
+					r.Post("/approve", api.postApproveTemplateVersionPromotion)
+					r.Post("/reject", api.postRejectTemplateVersionPromotion)
+				})
+			})
 		})
 		r.Route("/templateversions/{templateversion}", func(r chi.Router) {
 			r.Use(
@@ -672,6 +783,7 @@ func New(options *Options) *API {
 			r.Get("/", api.templateVersion)
 			r.Patch("/", api.patchTemplateVersion)
 			r.Patch("/cancel", api.patchCancelTemplateVersion)
+			r.Post("/push", api.postTemplateVersionPush)
 			// Old agents may expect a non-error response from /schema and /parameters endpoints.
 			// The idea is to return an empty [], so that the coder CLI won't get blocked accidentally.
 			r.Get("/schema", templateVersionSchemaDeprecated)
@@ -679,6 +791,7 @@ func New(options *Options) *API {
 			r.Get("/rich-parameters", api.templateVersionRichParameters)
 			r.Get("/gitauth", api.templateVersionGitAuth)
 			r.Get("/variables", api.templateVersionVariables)
+			r.Get("/presets", api.templateVersionPresets)
 			r.Get("/resources", api.templateVersionResources)
 			r.Get("/logs", api.templateVersionLogs)
 			r.Route("/dry-run", func(r chi.Router) {
@@ -689,6 +802,21 @@ func New(options *Options) *API {
 				r.Patch("/{jobID}/cancel", api.patchTemplateVersionDryRunCancel)
 			})
 		})
+		r.Route("/oauth", func(r chi.Router) {
+			// Exchanged tokens are API keys, so this shares the limit of
+			// logins.
+			r.Use(httpmw.RateLimit(options.LoginRateLimit, time.Minute))
+			r.Post("/token-exchange", api.postTokenExchange)
+		})
+		r.Route("/roles", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/", api.customRoles)
+			r.Post("/", api.postCustomRole)
+			r.Route("/{role}", func(r chi.Router) {
+				r.Put("/", api.putCustomRole)
+				r.Delete("/", api.deleteCustomRole)
+			})
+		})
 		r.Route("/users", func(r chi.Router) {
 			r.Get("/first", api.firstUser)
 			r.Post("/first", api.postFirstUser)
@@ -772,6 +900,25 @@ func New(options *Options) *API {
 					})
 					r.Get("/gitsshkey", api.gitSSHKey)
 					r.Put("/gitsshkey", api.regenerateGitSSHKey)
+					r.Get("/dotfiles", api.userDotfiles)
+					r.Put("/dotfiles", api.putUserDotfiles)
+					r.Route("/secrets", func(r chi.Router) {
+						r.Get("/", api.userSecrets)
+						r.Post("/", api.postUserSecret)
+						r.Route("/{name}", func(r chi.Router) {
+							r.Patch("/", api.patchUserSecret)
+							r.Delete("/", api.deleteUserSecret)
+						})
+					})
+					r.Route("/workspace-filters", func(r chi.Router) {
+						r.Get("/", api.savedWorkspaceFilters)
+						r.Post("/", api.postSavedWorkspaceFilter)
+						r.Route("/{name}", func(r chi.Router) {
+							r.Get("/", api.savedWorkspaceFilter)
+							r.Patch("/", api.patchSavedWorkspaceFilter)
+							r.Delete("/", api.deleteSavedWorkspaceFilter)
+						})
+					})
 				})
 			})
 		})
@@ -782,8 +929,9 @@ func New(options *Options) *API {
 			r.With(
 				apiKeyMiddlewareOptional,
 				httpmw.ExtractWorkspaceProxy(httpmw.ExtractWorkspaceProxyConfig{
-					DB:       options.Database,
-					Optional: true,
+					DB:         options.Database,
+					Optional:   true,
+					ClientCert: options.WorkspaceProxyClientCertCfg,
 				}),
 				httpmw.RequireAPIKeyOrWorkspaceProxyAuth(),
 			).Get("/connection", api.workspaceAgentConnectionGeneric)
@@ -805,15 +953,24 @@ func New(options *Options) *API {
 				r.Get("/coordinate", api.workspaceAgentCoordinate)
 				r.Post("/report-stats", api.workspaceAgentReportStats)
 				r.Post("/report-lifecycle", api.workspaceAgentReportLifecycle)
+				r.Post("/report-script-status", api.workspaceAgentReportScriptStatus)
+				r.Get("/drain", api.workspaceAgentDrain)
 				r.Post("/metadata/{key}", api.workspaceAgentPostMetadata)
+				r.Post("/metadata-plugins", api.workspaceAgentPostMetadataPlugins)
+				r.Route("/peers", func(r chi.Router) {
+					r.Get("/", api.workspaceAgentPeers)
+					r.Get("/{workspaceagent}/connection", api.workspaceAgentPeerConnection)
+					r.Get("/{workspaceagent}/coordinate", api.workspaceAgentPeerCoordinate)
+				})
 			})
 			r.Route("/{workspaceagent}", func(r chi.Router) {
 				r.Use(
 					// Allow either API key or external workspace proxy auth and require it.
 					apiKeyMiddlewareOptional,
 					httpmw.ExtractWorkspaceProxy(httpmw.ExtractWorkspaceProxyConfig{
-						DB:       options.Database,
-						Optional: true,
+						DB:         options.Database,
+						Optional:   true,
+						ClientCert: options.WorkspaceProxyClientCertCfg,
 					}),
 					httpmw.RequireAPIKeyOrWorkspaceProxyAuth(),
 
@@ -821,9 +978,11 @@ func New(options *Options) *API {
 					httpmw.ExtractWorkspaceParam(options.Database),
 				)
 				r.Get("/", api.workspaceAgent)
+				r.Get("/metadata", api.workspaceAgentMetadata)
 				r.Get("/watch-metadata", api.watchWorkspaceAgentMetadata)
 				r.Get("/startup-logs", api.workspaceAgentLogsDeprecated)
 				r.Get("/logs", api.workspaceAgentLogs)
+				r.Get("/scripts", api.workspaceAgentScripts)
 				r.Get("/listening-ports", api.workspaceAgentListeningPorts)
 				r.Get("/connection", api.workspaceAgentConnection)
 				r.Get("/coordinate", api.workspaceAgentClientCoordinate)
@@ -836,6 +995,11 @@ func New(options *Options) *API {
 				apiKeyMiddleware,
 			)
 			r.Get("/", api.workspaces)
+			r.Route("/batch", func(r chi.Router) {
+				r.Post("/", api.postWorkspacesBatch)
+				r.Get("/{workspacebatch}", api.workspaceBatch)
+			})
+			r.Get("/watch", api.watchWorkspaces)
 			r.Route("/{workspace}", func(r chi.Router) {
 				r.Use(
 					httpmw.ExtractWorkspaceParam(options.Database),
@@ -852,9 +1016,12 @@ func New(options *Options) *API {
 				r.Route("/ttl", func(r chi.Router) {
 					r.Put("/", api.putWorkspaceTTL)
 				})
+				r.Post("/clone", api.postWorkspaceClone)
 				r.Get("/watch", api.watchWorkspace)
 				r.Put("/extend", api.putExtendWorkspace)
+				r.Post("/extend", api.postExtendWorkspace)
 				r.Put("/lock", api.putWorkspaceLock)
+				r.Put("/unlock", api.putWorkspaceUnlock)
 			})
 		})
 		r.Route("/workspacebuilds/{workspacebuild}", func(r chi.Router) {
@@ -869,6 +1036,7 @@ func New(options *Options) *API {
 			r.Get("/parameters", api.workspaceBuildParameters)
 			r.Get("/resources", api.workspaceBuildResources)
 			r.Get("/state", api.workspaceBuildState)
+			r.Get("/timeline", api.workspaceBuildTimeline)
 		})
 		r.Route("/authcheck", func(r chi.Router) {
 			r.Use(apiKeyMiddleware)
@@ -888,12 +1056,24 @@ func New(options *Options) *API {
 				// handler and the login page.
 				r.Get("/", api.workspaceApplicationAuth)
 			})
+			r.Route("/oidc", func(r chi.Router) {
+				// Users that are signed in to Coder don't need to log in
+				// with the workspace app OIDC provider.
+				r.With(apiKeyMiddlewareOptional).Get("/", api.workspaceApplicationOIDC)
+				r.With(
+					httpmw.ExtractOAuth2(options.WorkspaceAppsOIDCConfig, options.HTTPClient, nil),
+				).Get("/callback", api.workspaceApplicationOIDCCallback)
+			})
 		})
 		r.Route("/insights", func(r chi.Router) {
 			r.Use(apiKeyMiddleware)
 			r.Get("/daus", api.deploymentDAUs)
 			r.Get("/user-latency", api.insightsUserLatency)
 			r.Get("/templates", api.insightsTemplates)
+			r.Get("/app-access-logs", api.insightsAppAccessLogs)
+			r.Get("/app-sessions", api.insightsAppSessions)
+			r.Get("/schedules", api.insightsSchedules)
+			r.Get("/builds", api.insightsBuilds)
 		})
 		r.Route("/debug", func(r chi.Router) {
 			r.Use(
@@ -980,6 +1160,13 @@ type API struct {
 	// WorkspaceProxyHostsFn returns the hosts of healthy workspace proxies
 	// for header reasons.
 	WorkspaceProxyHostsFn atomic.Pointer[func() []string]
+	// FirstLoginFn is called in the background after a user logs in for the
+	// first time.
+	FirstLoginFn atomic.Pointer[func(ctx context.Context, user database.User)]
+	// TemplateResourceQuotasEnabled is set by the enterprise license.
+	// Templates can only set resource quotas, and resource quotas are only
+	// enforced, while it's true.
+	TemplateResourceQuotasEnabled atomic.Bool
 	// TemplateScheduleStore is a pointer to an atomic pointer because this is
 	// passed to another struct, and we want them all to be the same reference.
 	TemplateScheduleStore *atomic.Pointer[schedule.TemplateScheduleStore]
@@ -988,6 +1175,9 @@ type API struct {
 	UserQuietHoursScheduleStore *atomic.Pointer[schedule.UserQuietHoursScheduleStore]
 	// DERPMapper mutates the DERPMap to include workspace proxies.
 	DERPMapper atomic.Pointer[func(derpMap *tailcfg.DERPMap) *tailcfg.DERPMap]
+	// baseDERPMap is the DERP map before DERPMapper is applied. It starts as
+	// Options.BaseDERPMap and is replaced by SetBaseDERPMap.
+	baseDERPMap atomic.Pointer[tailcfg.DERPMap]
 
 	HTTPAuth *HTTPAuthorizer
 
@@ -1002,12 +1192,18 @@ type API struct {
 	WebsocketWaitMutex sync.Mutex
 	WebsocketWaitGroup sync.WaitGroup
 	derpCloseFunc      func()
+	customRolesCancel  func()
 
 	metricsCache          *metricscache.Cache
 	updateChecker         *updatecheck.Checker
 	WorkspaceAppsProvider workspaceapps.SignedTokenProvider
-	workspaceAppServer    *workspaceapps.Server
-	agentProvider         workspaceapps.AgentProvider
+	// WorkspaceAppReplicas is nil unless workspace app sticky sessions are
+	// enabled. Its peers are set by the replica manager of enterprise.
+	WorkspaceAppReplicas *workspaceapps.ReplicaSet
+	workspaceAppServer   *workspaceapps.Server
+	agentProvider        workspaceapps.AgentProvider
+	// serverTailnet is nil unless the single tailnet experiment is enabled.
+	serverTailnet *ServerTailnet
 
 	// Experiments contains the list of experiments currently enabled.
 	// This is used to gate features that are not yet ready for production.
@@ -1017,18 +1213,26 @@ type API struct {
 	healthCheckCache atomic.Pointer[healthcheck.Report]
 
 	statsBatcher *batchstats.Batcher
+
+	prebuildsClaimer *prebuilds.Claimer
+
+	parameterValidator *parametervalidation.Validator
+
+	workspaceBatchRunner *workspacebatch.Runner
 }
 
 // Close waits for all WebSocket connections to drain before returning.
 func (api *API) Close() error {
 	api.cancel()
 	api.derpCloseFunc()
+	api.customRolesCancel()
 
 	api.WebsocketWaitMutex.Lock()
 	api.WebsocketWaitGroup.Wait()
 	api.WebsocketWaitMutex.Unlock()
 
 	api.metricsCache.Close()
+	api.workspaceBatchRunner.Close()
 	if api.updateChecker != nil {
 		api.updateChecker.Close()
 	}
@@ -1115,6 +1319,7 @@ func (api *API) CreateInMemoryProvisionerDaemon(ctx context.Context, debounce ti
 		Auditor:                     &api.Auditor,
 		TemplateScheduleStore:       api.TemplateScheduleStore,
 		UserQuietHoursScheduleStore: api.UserQuietHoursScheduleStore,
+		ScheduleHolidays:            api.ScheduleHolidays,
 		AcquireJobDebounce:          debounce,
 		Logger:                      api.Logger.Named(fmt.Sprintf("provisionerd-%s", daemon.Name)),
 		DeploymentValues:            api.DeploymentValues,
@@ -1146,12 +1351,29 @@ func (api *API) CreateInMemoryProvisionerDaemon(ctx context.Context, debounce ti
 }
 
 func (api *API) DERPMap() *tailcfg.DERPMap {
+	baseDERPMap := api.BaseDERPMap()
 	fn := api.DERPMapper.Load()
 	if fn != nil {
-		return (*fn)(api.Options.BaseDERPMap)
+		return (*fn)(baseDERPMap)
 	}
 
-	return api.Options.BaseDERPMap
+	return baseDERPMap
+}
+
+// BaseDERPMap returns the DERP map of the deployment without workspace
+// proxies.
+func (api *API) BaseDERPMap() *tailcfg.DERPMap {
+	return api.baseDERPMap.Load()
+}
+
+// SetBaseDERPMap replaces the base DERP map at runtime. Connected agents and
+// clients receive the new map on their next DERP map update, and the server
+// tailnet switches to it immediately.
+func (api *API) SetBaseDERPMap(derpMap *tailcfg.DERPMap) {
+	api.baseDERPMap.Store(derpMap)
+	if api.serverTailnet != nil {
+		api.serverTailnet.SetDERPMap(derpMap)
+	}
 }
 
 // nolint:revive
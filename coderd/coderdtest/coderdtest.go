@@ -67,12 +67,16 @@ import (
 	"github.com/coder/coder/coderd/healthcheck"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/prebuilds"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/telemetry"
+	"github.com/coder/coder/coderd/templateregistry"
 	"github.com/coder/coder/coderd/unhanger"
 	"github.com/coder/coder/coderd/updatecheck"
+	"github.com/coder/coder/coderd/usersecrets"
 	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/coderd/webhooks"
 	"github.com/coder/coder/coderd/workspaceapps"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
@@ -102,10 +106,18 @@ type Options struct {
 	GithubOAuth2Config    *coderd.GithubOAuth2Config
 	RealIPConfig          *httpmw.RealIPConfig
 	OIDCConfig            *coderd.OIDCConfig
+	TokenExchangePolicies []coderd.TokenExchangePolicy
 	GoogleTokenValidator  *idtoken.Validator
 	SSHKeygenAlgorithm    gitsshkey.Algorithm
 	AutobuildTicker       <-chan time.Time
 	AutobuildStats        chan<- autobuild.Stats
+	AutobuildMetrics      prometheus.Registerer
+	PrebuildsTicker       <-chan time.Time
+	PrebuildsStats        chan<- prebuilds.Stats
+	PrebuildsMetrics      prometheus.Registerer
+	Webhooks              *webhooks.Dispatcher
+	UserSecretsCipher     *usersecrets.Cipher
+	TemplateRegistries    []templateregistry.Registry
 	Auditor               audit.Auditor
 	TLSCertificates       []tls.Certificate
 	GitAuthConfigs        []*gitauth.Config
@@ -113,6 +125,10 @@ type Options struct {
 	TemplateScheduleStore schedule.TemplateScheduleStore
 	Coordinator           tailnet.Coordinator
 
+	// WorkspaceAppsOIDCConfig is the provider users log in with to use
+	// subdomain apps with OIDC auth.
+	WorkspaceAppsOIDCConfig *coderd.WorkspaceAppsOIDCConfig
+
 	HealthcheckFunc    func(ctx context.Context, apiKey string) *healthcheck.Report
 	HealthcheckTimeout time.Duration
 	HealthcheckRefresh time.Duration
@@ -202,6 +218,16 @@ func NewOptions(t testing.TB, options *Options) (func(http.Handler), context.Can
 			close(options.AutobuildStats)
 		})
 	}
+	if options.PrebuildsTicker == nil {
+		ticker := make(chan time.Time)
+		options.PrebuildsTicker = ticker
+		t.Cleanup(func() { close(ticker) })
+	}
+	if options.PrebuildsStats != nil {
+		t.Cleanup(func() {
+			close(options.PrebuildsStats)
+		})
+	}
 
 	if options.Authorizer == nil {
 		defAuth := rbac.NewCachingAuthorizer(prometheus.NewRegistry())
@@ -272,7 +298,11 @@ func NewOptions(t testing.TB, options *Options) (func(http.Handler), context.Can
 		&templateScheduleStore,
 		slogtest.Make(t, nil).Named("autobuild.executor").Leveled(slog.LevelDebug),
 		options.AutobuildTicker,
-	).WithStatsChannel(options.AutobuildStats)
+	).WithStatsChannel(options.AutobuildStats).
+		WithWebhooks(options.Webhooks, options.DeploymentValues.Webhooks.AutostopImminentWindow.Value())
+	if options.AutobuildMetrics != nil {
+		lifecycleExecutor.WithMetrics(options.AutobuildMetrics)
+	}
 	lifecycleExecutor.Run()
 
 	hangDetectorTicker := time.NewTicker(options.DeploymentValues.JobHangDetectorInterval.Value())
@@ -281,6 +311,14 @@ func NewOptions(t testing.TB, options *Options) (func(http.Handler), context.Can
 	hangDetector.Start()
 	t.Cleanup(hangDetector.Close)
 
+	prebuildsController := prebuilds.New(ctx, options.Database, slogtest.Make(t, nil).Named("prebuilds.controller"), options.PrebuildsTicker).
+		WithStatsChannel(options.PrebuildsStats)
+	if options.PrebuildsMetrics != nil {
+		prebuildsController.WithMetrics(options.PrebuildsMetrics)
+	}
+	prebuildsController.Start()
+	t.Cleanup(prebuildsController.Close)
+
 	var mutex sync.RWMutex
 	var handler http.Handler
 	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -402,6 +440,8 @@ func NewOptions(t testing.TB, options *Options) (func(http.Handler), context.Can
 			GithubOAuth2Config:                 options.GithubOAuth2Config,
 			RealIPConfig:                       options.RealIPConfig,
 			OIDCConfig:                         options.OIDCConfig,
+			TokenExchangePolicies:              options.TokenExchangePolicies,
+			WorkspaceAppsOIDCConfig:            options.WorkspaceAppsOIDCConfig,
 			GoogleTokenValidator:               options.GoogleTokenValidator,
 			SSHKeygenAlgorithm:                 options.SSHKeygenAlgorithm,
 			DERPServer:                         derpServer,
@@ -428,6 +468,9 @@ func NewOptions(t testing.TB, options *Options) (func(http.Handler), context.Can
 			HealthcheckRefresh:                 options.HealthcheckRefresh,
 			StatsBatcher:                       options.StatsBatcher,
 			WorkspaceAppsStatsCollectorOptions: options.WorkspaceAppsStatsCollectorOptions,
+			Webhooks:                           options.Webhooks,
+			UserSecretsCipher:                  options.UserSecretsCipher,
+			TemplateRegistries:                 options.TemplateRegistries,
 		}
 }
 
@@ -1168,6 +1211,36 @@ func (cfg *OIDCConfig) OIDCConfig(t *testing.T, userInfoClaims jwt.MapClaims, op
 	return newCFG
 }
 
+// TokenExchangePolicy returns the policy with a verifier that accepts the
+// tokens signed by EncodeClaims. The issuer of the config is used if the
+// policy doesn't set one.
+func (cfg *OIDCConfig) TokenExchangePolicy(policy codersdk.TokenExchangePolicy) coderd.TokenExchangePolicy {
+	if policy.Issuer == "" {
+		policy.Issuer = cfg.issuer
+	}
+	return coderd.TokenExchangePolicy{
+		TokenExchangePolicy: policy,
+		Verifier: oidc.NewVerifier(policy.Issuer, &oidc.StaticKeySet{
+			PublicKeys: []crypto.PublicKey{cfg.key.Public()},
+		}, &oidc.Config{
+			ClientID: policy.Audience,
+		}),
+	}
+}
+
+// WorkspaceAppsOIDCConfig returns a workspace app OIDC provider that accepts
+// the ID tokens signed by EncodeClaims for the audience.
+func (cfg *OIDCConfig) WorkspaceAppsOIDCConfig(audience string) *coderd.WorkspaceAppsOIDCConfig {
+	return &coderd.WorkspaceAppsOIDCConfig{
+		OAuth2Config: cfg,
+		Verifier: oidc.NewVerifier(cfg.issuer, &oidc.StaticKeySet{
+			PublicKeys: []crypto.PublicKey{cfg.key.Public()},
+		}, &oidc.Config{
+			ClientID: audience,
+		}),
+	}
+}
+
 // NewAzureInstanceIdentity returns a metadata client and ID token validator for faking
 // instance authentication for Azure.
 func NewAzureInstanceIdentity(t *testing.T, instanceID string) (x509.VerifyOptions, *http.Client) {
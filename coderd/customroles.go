@@ -0,0 +1,417 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// PubsubEventCustomRoles is published with the name of a custom role when it
+// is created, updated or deleted, so every replica reloads it.
+const PubsubEventCustomRoles = "custom_roles"
+
+// @Summary Get custom roles
+// @ID get-custom-roles
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Members
+// @Success 200 {array} codersdk.CustomRole
+// @Router /roles [get]
+func (api *API) customRoles(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	roles, err := api.Database.GetCustomRoles(ctx)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching custom roles.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	sdkRoles := make([]codersdk.CustomRole, 0, len(roles))
+	for _, role := range roles {
+		sdkRole, err := convertCustomRole(role)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error converting custom role.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		sdkRoles = append(sdkRoles, sdkRole)
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, sdkRoles)
+}
+
+// @Summary Create custom role
+// @Description Custom roles are site wide roles composed of permissions on
+// @Description resource types. They are assigned to users like site roles.
+// @ID create-custom-role
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Members
+// @Param request body codersdk.CreateCustomRoleRequest true "Create custom role request"
+// @Success 201 {object} codersdk.CustomRole
+// @Router /roles [post]
+func (api *API) postCustomRole(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.CustomRole](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	defer commitAudit()
+
+	var req codersdk.CreateCustomRoleRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	sitePermissions, userPermissions, ok := validateCustomRole(ctx, rw, rbac.Role{
+		Name:        req.Name,
+		DisplayName: req.DisplayName,
+		Site:        convertPermissions(req.SitePermissions),
+		User:        convertPermissions(req.UserPermissions),
+	})
+	if !ok {
+		return
+	}
+
+	now := database.Now()
+	role, err := api.Database.InsertCustomRole(ctx, database.InsertCustomRoleParams{
+		Name:            req.Name,
+		DisplayName:     req.DisplayName,
+		SitePermissions: sitePermissions,
+		UserPermissions: userPermissions,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("Custom role with name %q already exists.", req.Name),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating custom role.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	aReq.New = role
+
+	api.customRoleChanged(ctx, role.Name)
+	sdkRole, err := convertCustomRole(role)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error converting custom role.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusCreated, sdkRole)
+}
+
+// @Summary Update custom role
+// @ID update-custom-role
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Members
+// @Param role path string true "Role name"
+// @Param request body codersdk.UpdateCustomRoleRequest true "Update custom role request"
+// @Success 200 {object} codersdk.CustomRole
+// @Router /roles/{role} [put]
+func (api *API) putCustomRole(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		name              = chi.URLParam(r, "role")
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.CustomRole](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+
+	var req codersdk.UpdateCustomRoleRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	oldRole, err := api.Database.GetCustomRoleByName(ctx, name)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching custom role.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.Old = oldRole
+
+	sitePermissions, userPermissions, ok := validateCustomRole(ctx, rw, rbac.Role{
+		Name:        name,
+		DisplayName: req.DisplayName,
+		Site:        convertPermissions(req.SitePermissions),
+		User:        convertPermissions(req.UserPermissions),
+	})
+	if !ok {
+		return
+	}
+
+	role, err := api.Database.UpdateCustomRole(ctx, database.UpdateCustomRoleParams{
+		Name:            name,
+		DisplayName:     req.DisplayName,
+		SitePermissions: sitePermissions,
+		UserPermissions: userPermissions,
+		UpdatedAt:       database.Now(),
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating custom role.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	aReq.New = role
+
+	api.customRoleChanged(ctx, role.Name)
+	sdkRole, err := convertCustomRole(role)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error converting custom role.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, sdkRole)
+}
+
+// @Summary Delete custom role
+// @Description The role is removed from all users that have it.
+// @ID delete-custom-role
+// @Security CoderSessionToken
+// @Tags Members
+// @Param role path string true "Role name"
+// @Success 204
+// @Router /roles/{role} [delete]
+func (api *API) deleteCustomRole(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		name              = chi.URLParam(r, "role")
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.CustomRole](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionDelete,
+		})
+	)
+	defer commitAudit()
+
+	role, err := api.Database.GetCustomRoleByName(ctx, name)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching custom role.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.Old = role
+
+	err = api.Database.DeleteCustomRole(ctx, name)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting custom role.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	api.customRoleChanged(ctx, name)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// validateCustomRole writes a bad request response and returns false if the
+// role is invalid. Otherwise it returns the permissions of the role in the
+// format they are stored in the database.
+func validateCustomRole(ctx context.Context, rw http.ResponseWriter, role rbac.Role) (site json.RawMessage, user json.RawMessage, ok bool) {
+	err := rbac.ValidateCustomRole(role)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid custom role.",
+			Detail:  err.Error(),
+		})
+		return nil, nil, false
+	}
+
+	site, err = json.Marshal(role.Site)
+	if err == nil {
+		user, err = json.Marshal(role.User)
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error encoding custom role permissions.",
+			Detail:  err.Error(),
+		})
+		return nil, nil, false
+	}
+	return site, user, true
+}
+
+// customRoleChanged reloads the custom role in this replica and notifies the
+// other replicas to reload it too.
+func (api *API) customRoleChanged(ctx context.Context, name string) {
+	api.reloadCustomRole(ctx, name)
+	err := api.Pubsub.Publish(PubsubEventCustomRoles, []byte(name))
+	if err != nil {
+		api.Logger.Warn(ctx, "failed to publish custom role change",
+			slog.F("role", name), slog.Error(err))
+	}
+}
+
+// loadCustomRoles adds all custom roles stored in the database to the rbac
+// package, so they can be used for authorization.
+func (api *API) loadCustomRoles(ctx context.Context) error {
+	// nolint:gocritic // Custom roles are needed to authorize any request.
+	roles, err := api.Database.GetCustomRoles(dbauthz.AsSystemRestricted(ctx))
+	if err != nil {
+		return xerrors.Errorf("get custom roles: %w", err)
+	}
+	for _, role := range roles {
+		rbacRole, err := convertCustomRoleToRBAC(role)
+		if err != nil {
+			return err
+		}
+		err = rbac.SetCustomRole(rbacRole)
+		if err != nil {
+			return xerrors.Errorf("set custom role %q: %w", role.Name, err)
+		}
+	}
+	return nil
+}
+
+// reloadCustomRole updates the custom role in the rbac package to match the
+// database, removing it if it was deleted.
+func (api *API) reloadCustomRole(ctx context.Context, name string) {
+	// nolint:gocritic // Custom roles are needed to authorize any request.
+	role, err := api.Database.GetCustomRoleByName(dbauthz.AsSystemRestricted(ctx), name)
+	if errors.Is(err, sql.ErrNoRows) {
+		rbac.DeleteCustomRole(name)
+		return
+	}
+	if err == nil {
+		var rbacRole rbac.Role
+		rbacRole, err = convertCustomRoleToRBAC(role)
+		if err == nil {
+			err = rbac.SetCustomRole(rbacRole)
+		}
+	}
+	if err != nil {
+		api.Logger.Error(ctx, "failed to reload custom role",
+			slog.F("role", name), slog.Error(err))
+	}
+}
+
+func convertPermissions(permissions []codersdk.Permission) []rbac.Permission {
+	converted := make([]rbac.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		converted = append(converted, rbac.Permission{
+			Negate:       permission.Negate,
+			ResourceType: string(permission.ResourceType),
+			Action:       rbac.Action(permission.Action),
+		})
+	}
+	return converted
+}
+
+func convertCustomRoleToRBAC(role database.CustomRole) (rbac.Role, error) {
+	rbacRole := rbac.Role{
+		Name:        role.Name,
+		DisplayName: role.DisplayName,
+	}
+	err := json.Unmarshal(role.SitePermissions, &rbacRole.Site)
+	if err != nil {
+		return rbac.Role{}, xerrors.Errorf("unmarshal site permissions of custom role %q: %w", role.Name, err)
+	}
+	err = json.Unmarshal(role.UserPermissions, &rbacRole.User)
+	if err != nil {
+		return rbac.Role{}, xerrors.Errorf("unmarshal user permissions of custom role %q: %w", role.Name, err)
+	}
+	return rbacRole, nil
+}
+
+func convertCustomRole(role database.CustomRole) (codersdk.CustomRole, error) {
+	sdkRole := codersdk.CustomRole{
+		Name:            role.Name,
+		DisplayName:     role.DisplayName,
+		SitePermissions: []codersdk.Permission{},
+		UserPermissions: []codersdk.Permission{},
+		CreatedAt:       role.CreatedAt,
+		UpdatedAt:       role.UpdatedAt,
+	}
+	// The permissions are stored as rbac.Permission, which has the same JSON
+	// representation as codersdk.Permission.
+	err := json.Unmarshal(role.SitePermissions, &sdkRole.SitePermissions)
+	if err != nil {
+		return codersdk.CustomRole{}, xerrors.Errorf("unmarshal site permissions: %w", err)
+	}
+	err = json.Unmarshal(role.UserPermissions, &sdkRole.UserPermissions)
+	if err != nil {
+		return codersdk.CustomRole{}, xerrors.Errorf("unmarshal user permissions: %w", err)
+	}
+	return sdkRole, nil
+}
@@ -0,0 +1,219 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestCustomRoles(t *testing.T) {
+	t.Parallel()
+
+	// Custom roles are shared by all coderd instances in the process, so
+	// each test uses its own role name.
+	customRoleName := func() string {
+		return "scheduler-" + uuid.NewString()[:8]
+	}
+	templatePermissions := func(actions ...string) []codersdk.Permission {
+		permissions := make([]codersdk.Permission, 0, len(actions))
+		for _, action := range actions {
+			permissions = append(permissions, codersdk.Permission{
+				ResourceType: codersdk.ResourceTemplate,
+				Action:       action,
+			})
+		}
+		return permissions
+	}
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		name := customRoleName()
+		role, err := client.CreateCustomRole(ctx, codersdk.CreateCustomRoleRequest{
+			Name:            name,
+			DisplayName:     "Template Scheduler",
+			SitePermissions: templatePermissions(codersdk.ActionRead, codersdk.ActionUpdate),
+		})
+		require.NoError(t, err)
+		require.Equal(t, name, role.Name)
+		require.Len(t, role.SitePermissions, 2)
+		require.Empty(t, role.UserPermissions)
+
+		roles, err := client.CustomRoles(ctx)
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		require.Equal(t, role.Name, roles[0].Name)
+
+		// Custom roles can be assigned like site roles.
+		siteRoles, err := client.ListSiteRoles(ctx)
+		require.NoError(t, err)
+		require.Contains(t, siteRoles, codersdk.AssignableRoles{
+			Role:       codersdk.Role{Name: name, DisplayName: "Template Scheduler"},
+			Assignable: true,
+		})
+
+		role, err = client.UpdateCustomRole(ctx, name, codersdk.UpdateCustomRoleRequest{
+			DisplayName:     "Scheduler",
+			SitePermissions: templatePermissions(codersdk.ActionRead),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "Scheduler", role.DisplayName)
+		require.Len(t, role.SitePermissions, 1)
+
+		err = client.DeleteCustomRole(ctx, name)
+		require.NoError(t, err)
+		roles, err = client.CustomRoles(ctx)
+		require.NoError(t, err)
+		require.Empty(t, roles)
+
+		auditLogs := auditor.AuditLogs()
+		require.GreaterOrEqual(t, len(auditLogs), 3)
+		auditLogs = auditLogs[len(auditLogs)-3:]
+		for i, action := range []database.AuditAction{
+			database.AuditActionCreate,
+			database.AuditActionWrite,
+			database.AuditActionDelete,
+		} {
+			require.Equal(t, action, auditLogs[i].Action)
+			require.Equal(t, database.ResourceTypeCustomRole, auditLogs[i].ResourceType)
+			require.Equal(t, name, auditLogs[i].ResourceTarget)
+		}
+	})
+
+	t.Run("Authorize", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		member, memberUser := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		name := customRoleName()
+		_, err := client.CreateCustomRole(ctx, codersdk.CreateCustomRoleRequest{
+			Name:            name,
+			DisplayName:     "Template Scheduler",
+			SitePermissions: templatePermissions(codersdk.ActionRead, codersdk.ActionUpdate),
+		})
+		require.NoError(t, err)
+
+		_, err = client.UpdateUserRoles(ctx, memberUser.ID.String(), codersdk.UpdateRoles{
+			Roles: []string{name},
+		})
+		require.NoError(t, err)
+
+		checks := codersdk.AuthorizationRequest{
+			Checks: map[string]codersdk.AuthorizationCheck{
+				"update": {
+					Object: codersdk.AuthorizationObject{ResourceType: codersdk.ResourceTemplate, OrganizationID: first.OrganizationID.String()},
+					Action: codersdk.ActionUpdate,
+				},
+				"delete": {
+					Object: codersdk.AuthorizationObject{ResourceType: codersdk.ResourceTemplate, OrganizationID: first.OrganizationID.String()},
+					Action: codersdk.ActionDelete,
+				},
+			},
+		}
+		res, err := member.AuthCheck(ctx, checks)
+		require.NoError(t, err)
+		require.True(t, res["update"])
+		require.False(t, res["delete"])
+
+		// Changes to the role apply immediately.
+		_, err = client.UpdateCustomRole(ctx, name, codersdk.UpdateCustomRoleRequest{
+			DisplayName:     "Template Scheduler",
+			SitePermissions: templatePermissions(codersdk.ActionRead),
+		})
+		require.NoError(t, err)
+		res, err = member.AuthCheck(ctx, checks)
+		require.NoError(t, err)
+		require.False(t, res["update"])
+
+		// Deleting the role removes it from its users.
+		err = client.DeleteCustomRole(ctx, name)
+		require.NoError(t, err)
+		memberUser, err = client.User(ctx, memberUser.ID.String())
+		require.NoError(t, err)
+		require.Empty(t, memberUser.Roles)
+	})
+
+	t.Run("MemberForbidden", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		_, err := member.CreateCustomRole(ctx, codersdk.CreateCustomRoleRequest{
+			Name:        customRoleName(),
+			DisplayName: "Template Scheduler",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		_, err = member.CustomRoles(ctx)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		for _, req := range []codersdk.CreateCustomRoleRequest{
+			{Name: "owner", DisplayName: "Owner"},
+			{Name: "bad:name", DisplayName: "Bad"},
+			{
+				Name:            customRoleName(),
+				DisplayName:     "Wildcard",
+				SitePermissions: []codersdk.Permission{{ResourceType: "*", Action: codersdk.ActionRead}},
+			},
+			{
+				Name:            customRoleName(),
+				DisplayName:     "Unknown action",
+				SitePermissions: templatePermissions("execute"),
+			},
+		} {
+			_, err := client.CreateCustomRole(ctx, req)
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr, req.Name)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode(), req.Name)
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		req := codersdk.CreateCustomRoleRequest{
+			Name:        customRoleName(),
+			DisplayName: "Template Scheduler",
+		}
+		_, err := client.CreateCustomRole(ctx, req)
+		require.NoError(t, err)
+		_, err = client.CreateCustomRole(ctx, req)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+}
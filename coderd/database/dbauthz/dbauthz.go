@@ -197,6 +197,27 @@ var (
 		Scope: rbac.ScopeAll,
 	}.WithCachedASTValue()
 
+	// See prebuilds package.
+	subjectPrebuildsController = rbac.Subject{
+		ID: uuid.Nil.String(),
+		Roles: rbac.Roles([]rbac.Role{
+			{
+				Name:        "prebuildscontroller",
+				DisplayName: "Prebuilds Controller Daemon",
+				Site: rbac.Permissions(map[string][]rbac.Action{
+					rbac.ResourceSystem.Type:         {rbac.WildcardSymbol},
+					rbac.ResourceTemplate.Type:       {rbac.ActionRead},
+					rbac.ResourceUser.Type:           {rbac.ActionRead},
+					rbac.ResourceWorkspace.Type:      {rbac.ActionRead, rbac.ActionCreate, rbac.ActionUpdate, rbac.ActionDelete},
+					rbac.ResourceWorkspaceBuild.Type: {rbac.ActionRead, rbac.ActionUpdate, rbac.ActionDelete},
+				}),
+				Org:  map[string][]rbac.Permission{},
+				User: []rbac.Permission{},
+			},
+		}),
+		Scope: rbac.ScopeAll,
+	}.WithCachedASTValue()
+
 	subjectSystemRestricted = rbac.Subject{
 		ID: uuid.Nil.String(),
 		Roles: rbac.Roles([]rbac.Role{
@@ -206,9 +227,10 @@ var (
 				Site: rbac.Permissions(map[string][]rbac.Action{
 					rbac.ResourceWildcard.Type:           {rbac.ActionRead},
 					rbac.ResourceAPIKey.Type:             {rbac.ActionCreate, rbac.ActionUpdate, rbac.ActionDelete},
-					rbac.ResourceGroup.Type:              {rbac.ActionCreate, rbac.ActionUpdate},
+					rbac.ResourceGroup.Type:              {rbac.ActionCreate, rbac.ActionUpdate, rbac.ActionDelete},
 					rbac.ResourceRoleAssignment.Type:     {rbac.ActionCreate, rbac.ActionDelete},
 					rbac.ResourceSystem.Type:             {rbac.WildcardSymbol},
+					rbac.ResourceTemplate.Type:           {rbac.ActionCreate, rbac.ActionUpdate}, // SCIM group sync updates the ACLs of templates, and build deadlines are recalculated in the background.
 					rbac.ResourceOrganization.Type:       {rbac.ActionCreate},
 					rbac.ResourceOrganizationMember.Type: {rbac.ActionCreate},
 					rbac.ResourceOrgRoleAssignment.Type:  {rbac.ActionCreate},
@@ -245,6 +267,12 @@ func AsHangDetector(ctx context.Context) context.Context {
 	return context.WithValue(ctx, authContextKey{}, subjectHangDetector)
 }
 
+// AsPrebuildsController returns a context with an actor that has permissions
+// required for prebuilds.Controller to function.
+func AsPrebuildsController(ctx context.Context) context.Context {
+	return context.WithValue(ctx, authContextKey{}, subjectPrebuildsController)
+}
+
 // AsSystemRestricted returns a context with an actor that has permissions
 // required for various system operations (login, logout, metrics cache).
 func AsSystemRestricted(ctx context.Context) context.Context {
@@ -647,6 +675,44 @@ func (q *querier) AcquireProvisionerJob(ctx context.Context, arg database.Acquir
 	return q.db.AcquireProvisionerJob(ctx, arg)
 }
 
+func (q *querier) AcquireTemplateDeadlineRecalculation(ctx context.Context, arg database.AcquireTemplateDeadlineRecalculationParams) (database.TemplateDeadlineRecalculation, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return database.TemplateDeadlineRecalculation{}, err
+	}
+	return q.db.AcquireTemplateDeadlineRecalculation(ctx, arg)
+}
+
+func (q *querier) AcquireWorkspaceBatch(ctx context.Context, arg database.AcquireWorkspaceBatchParams) (database.WorkspaceBatch, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return database.WorkspaceBatch{}, err
+	}
+	return q.db.AcquireWorkspaceBatch(ctx, arg)
+}
+
+func (q *querier) ApproveTemplateVersionPromotion(ctx context.Context, arg database.ApproveTemplateVersionPromotionParams) (database.TemplateVersionPromotion, error) {
+	promotion, err := q.db.GetTemplateVersionPromotionByID(ctx, arg.ID)
+	if err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+	template, err := q.db.GetTemplateByID(ctx, promotion.TemplateID)
+	if err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+	return q.db.ApproveTemplateVersionPromotion(ctx, arg)
+}
+
+func (q *querier) ClaimPrebuiltWorkspace(ctx context.Context, arg database.ClaimPrebuiltWorkspaceParams) (database.Workspace, error) {
+	// Prebuilt workspaces are owned by the prebuilds user until they are
+	// claimed, so only the system may claim them.
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return database.Workspace{}, err
+	}
+	return q.db.ClaimPrebuiltWorkspace(ctx, arg)
+}
+
 func (q *querier) CleanTailnetCoordinators(ctx context.Context) error {
 	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceTailnetCoordinator); err != nil {
 		return err
@@ -678,6 +744,13 @@ func (q *querier) DeleteApplicationConnectAPIKeysByUserID(ctx context.Context, u
 	return q.db.DeleteApplicationConnectAPIKeysByUserID(ctx, userID)
 }
 
+func (q *querier) DeleteAuditLogsByIDs(ctx context.Context, ids []uuid.UUID) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteAuditLogsByIDs(ctx, ids)
+}
+
 func (q *querier) DeleteCoordinator(ctx context.Context, id uuid.UUID) error {
 	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceTailnetCoordinator); err != nil {
 		return err
@@ -685,6 +758,20 @@ func (q *querier) DeleteCoordinator(ctx context.Context, id uuid.UUID) error {
 	return q.db.DeleteCoordinator(ctx, id)
 }
 
+func (q *querier) DeleteCustomRole(ctx context.Context, name string) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceCustomRole); err != nil {
+		return err
+	}
+	return q.db.DeleteCustomRole(ctx, name)
+}
+
+func (q *querier) DeleteExpiredWorkspaceNameRedirects(ctx context.Context) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteExpiredWorkspaceNameRedirects(ctx)
+}
+
 func (q *querier) DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error {
 	return deleteQ(q.log, q.auth, q.db.GetGitSSHKey, q.db.DeleteGitSSHKey)(ctx, userID)
 }
@@ -722,6 +809,13 @@ func (q *querier) DeleteLicense(ctx context.Context, id int32) (int32, error) {
 	return id, nil
 }
 
+func (q *querier) DeleteOldTemplateDeadlineRecalculations(ctx context.Context, completedBefore time.Time) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteOldTemplateDeadlineRecalculations(ctx, completedBefore)
+}
+
 func (q *querier) DeleteOldWorkspaceAgentLogs(ctx context.Context) error {
 	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
 		return err
@@ -736,6 +830,37 @@ func (q *querier) DeleteOldWorkspaceAgentStats(ctx context.Context) error {
 	return q.db.DeleteOldWorkspaceAgentStats(ctx)
 }
 
+func (q *querier) DeleteOldWorkspaceAppAccessLogs(ctx context.Context) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteOldWorkspaceAppAccessLogs(ctx)
+}
+
+func (q *querier) DeleteOldWorkspaceBatches(ctx context.Context, completedBefore time.Time) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.DeleteOldWorkspaceBatches(ctx, completedBefore)
+}
+
+func (q *querier) DeleteOrganizationTemplateVariable(ctx context.Context, arg database.DeleteOrganizationTemplateVariableParams) error {
+	// An actor is allowed to delete the template variables of an organization
+	// if they are authorized to update the organization.
+	organization, err := q.db.GetOrganizationByID(ctx, arg.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, organization); err != nil {
+		return err
+	}
+	return q.db.DeleteOrganizationTemplateVariable(ctx, arg)
+}
+
+func (q *querier) DeleteProvisionerKey(ctx context.Context, id uuid.UUID) error {
+	return deleteQ(q.log, q.auth, q.db.GetProvisionerKeyByID, q.db.DeleteProvisionerKey)(ctx, id)
+}
+
 func (q *querier) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt time.Time) error {
 	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceSystem); err != nil {
 		return err
@@ -743,6 +868,23 @@ func (q *querier) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt tim
 	return q.db.DeleteReplicasUpdatedBefore(ctx, updatedAt)
 }
 
+func (q *querier) DeleteSavedWorkspaceFilter(ctx context.Context, arg database.DeleteSavedWorkspaceFilterParams) error {
+	fetch := func(ctx context.Context, arg database.DeleteSavedWorkspaceFilterParams) (database.SavedWorkspaceFilter, error) {
+		return q.db.GetSavedWorkspaceFilterByUserIDAndName(ctx, database.GetSavedWorkspaceFilterByUserIDAndNameParams{
+			UserID: arg.UserID,
+			Name:   arg.Name,
+		})
+	}
+	return deleteQ(q.log, q.auth, fetch, q.db.DeleteSavedWorkspaceFilter)(ctx, arg)
+}
+
+func (q *querier) DeleteScheduleHolidayByID(ctx context.Context, id uuid.UUID) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceDeploymentValues); err != nil {
+		return err
+	}
+	return q.db.DeleteScheduleHolidayByID(ctx, id)
+}
+
 func (q *querier) DeleteTailnetAgent(ctx context.Context, arg database.DeleteTailnetAgentParams) (database.DeleteTailnetAgentRow, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceTailnetCoordinator); err != nil {
 		return database.DeleteTailnetAgentRow{}, err
@@ -757,6 +899,64 @@ func (q *querier) DeleteTailnetClient(ctx context.Context, arg database.DeleteTa
 	return q.db.DeleteTailnetClient(ctx, arg)
 }
 
+func (q *querier) DeleteUserDotfiles(ctx context.Context, userID uuid.UUID) error {
+	if err := q.authorizeContext(ctx, rbac.ActionDelete, rbac.ResourceUserData.WithOwner(userID.String()).WithID(userID)); err != nil {
+		return err
+	}
+	return q.db.DeleteUserDotfiles(ctx, userID)
+}
+
+func (q *querier) DeleteUserQuietHoursException(ctx context.Context, arg database.DeleteUserQuietHoursExceptionParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceUserObject(arg.UserID)); err != nil {
+		return err
+	}
+	return q.db.DeleteUserQuietHoursException(ctx, arg)
+}
+
+func (q *querier) DeleteUserSecret(ctx context.Context, arg database.DeleteUserSecretParams) error {
+	fetch := func(ctx context.Context, arg database.DeleteUserSecretParams) (database.UserSecret, error) {
+		return q.db.GetUserSecretByUserIDAndName(ctx, database.GetUserSecretByUserIDAndNameParams{
+			UserID: arg.UserID,
+			Name:   arg.Name,
+		})
+	}
+	return deleteQ(q.log, q.auth, fetch, q.db.DeleteUserSecret)(ctx, arg)
+}
+
+func (q *querier) DeleteWorkspaceAgentPluginMetadata(ctx context.Context, arg database.DeleteWorkspaceAgentPluginMetadataParams) error {
+	workspace, err := q.db.GetWorkspaceByAgentID(ctx, arg.WorkspaceAgentID)
+	if err != nil {
+		return err
+	}
+
+	err = q.authorizeContext(ctx, rbac.ActionUpdate, workspace)
+	if err != nil {
+		return err
+	}
+
+	return q.db.DeleteWorkspaceAgentPluginMetadata(ctx, arg)
+}
+
+func (q *querier) DeleteWorkspaceScheduleOverrideByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) error {
+	// An actor is allowed to delete a workspace schedule override if they are
+	// authorized to update the workspace's template.
+	fetch := func(ctx context.Context, workspaceID uuid.UUID) (database.Template, error) {
+		workspace, err := q.db.GetWorkspaceByID(ctx, workspaceID)
+		if err != nil {
+			return database.Template{}, err
+		}
+		return q.db.GetTemplateByID(ctx, workspace.TemplateID)
+	}
+	return fetchAndExec(q.log, q.auth, rbac.ActionUpdate, fetch, q.db.DeleteWorkspaceScheduleOverrideByWorkspaceID)(ctx, workspaceID)
+}
+
+func (q *querier) GetACMECertificate(ctx context.Context, arg database.GetACMECertificateParams) (database.ACMECertificate, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return database.ACMECertificate{}, err
+	}
+	return q.db.GetACMECertificate(ctx, arg)
+}
+
 func (q *querier) GetAPIKeyByID(ctx context.Context, id string) (database.APIKey, error) {
 	return fetch(q.log, q.auth, q.db.GetAPIKeyByID)(ctx, id)
 }
@@ -785,8 +985,13 @@ func (q *querier) GetActiveUserCount(ctx context.Context) (int64, error) {
 }
 
 func (q *querier) GetActiveWorkspaceBuildsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]database.WorkspaceBuild, error) {
-	// This is a system-only function.
-	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+	// The active builds are only fetched to recalculate their deadlines after
+	// the template schedule changes, so updating the template is required.
+	template, err := q.db.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return []database.WorkspaceBuild{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
 		return []database.WorkspaceBuild{}, err
 	}
 	return q.db.GetActiveWorkspaceBuildsByTemplateID(ctx, templateID)
@@ -806,11 +1011,70 @@ func (q *querier) GetAllTailnetClients(ctx context.Context) ([]database.TailnetC
 	return q.db.GetAllTailnetClients(ctx)
 }
 
+func (q *querier) GetAppAccessLogInsights(ctx context.Context, arg database.GetAppAccessLogInsightsParams) ([]database.GetAppAccessLogInsightsRow, error) {
+	for _, templateID := range arg.TemplateIDs {
+		template, err := q.db.GetTemplateByID(ctx, templateID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+			return nil, err
+		}
+	}
+	if len(arg.TemplateIDs) == 0 {
+		if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceTemplate.All()); err != nil {
+			return nil, err
+		}
+	}
+	return q.db.GetAppAccessLogInsights(ctx, arg)
+}
+
 func (q *querier) GetAppSecurityKey(ctx context.Context) (string, error) {
 	// No authz checks
 	return q.db.GetAppSecurityKey(ctx)
 }
 
+func (q *querier) GetAppSessionInsights(ctx context.Context, arg database.GetAppSessionInsightsParams) ([]database.GetAppSessionInsightsRow, error) {
+	for _, templateID := range arg.TemplateIDs {
+		template, err := q.db.GetTemplateByID(ctx, templateID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+			return nil, err
+		}
+	}
+	if len(arg.TemplateIDs) == 0 {
+		if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceTemplate.All()); err != nil {
+			return nil, err
+		}
+	}
+	return q.db.GetAppSessionInsights(ctx, arg)
+}
+
+func (q *querier) GetAuditLogExportCursor(ctx context.Context, sink string) (database.AuditLogExportCursor, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return database.AuditLogExportCursor{}, err
+	}
+	return q.db.GetAuditLogExportCursor(ctx, sink)
+}
+
+func (q *querier) GetAuditLogsAfterCursor(ctx context.Context, arg database.GetAuditLogsAfterCursorParams) ([]database.AuditLog, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceAuditLog); err != nil {
+		return nil, err
+	}
+	return q.db.GetAuditLogsAfterCursor(ctx, arg)
+}
+
+func (q *querier) GetAuditLogsCreatedBefore(ctx context.Context, arg database.GetAuditLogsCreatedBeforeParams) ([]database.AuditLog, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceAuditLog); err != nil {
+		return nil, err
+	}
+	return q.db.GetAuditLogsCreatedBefore(ctx, arg)
+}
+
 func (q *querier) GetAuditLogsOffset(ctx context.Context, arg database.GetAuditLogsOffsetParams) ([]database.GetAuditLogsOffsetRow, error) {
 	// To optimize audit logs, we only check the global audit log permission once.
 	// This is because we expect a large unbounded set of audit logs, and applying a SQL
@@ -828,6 +1092,20 @@ func (q *querier) GetAuthorizationUserRoles(ctx context.Context, userID uuid.UUI
 	return q.db.GetAuthorizationUserRoles(ctx, userID)
 }
 
+func (q *querier) GetCustomRoleByName(ctx context.Context, name string) (database.CustomRole, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceCustomRole); err != nil {
+		return database.CustomRole{}, err
+	}
+	return q.db.GetCustomRoleByName(ctx, name)
+}
+
+func (q *querier) GetCustomRoles(ctx context.Context) ([]database.CustomRole, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceCustomRole); err != nil {
+		return nil, err
+	}
+	return q.db.GetCustomRoles(ctx)
+}
+
 func (q *querier) GetDERPMeshKey(ctx context.Context) (string, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
 		return "", err
@@ -916,6 +1194,13 @@ func (q *querier) GetGroupByOrgAndName(ctx context.Context, arg database.GetGrou
 	return fetch(q.log, q.auth, q.db.GetGroupByOrgAndName)(ctx, arg)
 }
 
+func (q *querier) GetGroupMemberUserIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	if _, err := q.GetGroupByID(ctx, groupID); err != nil { // AuthZ check
+		return nil, err
+	}
+	return q.db.GetGroupMemberUserIDs(ctx, groupID)
+}
+
 func (q *querier) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]database.User, error) {
 	if _, err := q.GetGroupByID(ctx, groupID); err != nil { // AuthZ check
 		return nil, err
@@ -992,6 +1277,28 @@ func (q *querier) GetOAuthSigningKey(ctx context.Context) (string, error) {
 	return q.db.GetOAuthSigningKey(ctx)
 }
 
+func (q *querier) GetOrganizationBudgetSettings(ctx context.Context, organizationID uuid.UUID) (database.OrganizationBudgetSettings, error) {
+	// An actor is allowed to read the budget settings of an organization if
+	// they are authorized to read the organization.
+	organization, err := q.db.GetOrganizationByID(ctx, organizationID)
+	if err != nil {
+		return database.OrganizationBudgetSettings{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionRead, organization); err != nil {
+		return database.OrganizationBudgetSettings{}, err
+	}
+	return q.db.GetOrganizationBudgetSettings(ctx, organizationID)
+}
+
+// GetOrganizationBudgetUsages is used by the budget alert monitor to evaluate
+// the budgets of all organizations.
+func (q *querier) GetOrganizationBudgetUsages(ctx context.Context) ([]database.GetOrganizationBudgetUsagesRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetOrganizationBudgetUsages(ctx)
+}
+
 func (q *querier) GetOrganizationByID(ctx context.Context, id uuid.UUID) (database.Organization, error) {
 	return fetch(q.log, q.auth, q.db.GetOrganizationByID)(ctx, id)
 }
@@ -1006,6 +1313,19 @@ func (q *querier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.
 	return fetchWithPostFilter(q.auth, q.db.GetOrganizationIDsByMemberIDs)(ctx, ids)
 }
 
+func (q *querier) GetOrganizationJITProvisioningSettings(ctx context.Context, organizationID uuid.UUID) (database.OrganizationJITProvisioningSettings, error) {
+	// An actor is allowed to read the JIT provisioning settings of an
+	// organization if they are authorized to read the organization.
+	organization, err := q.db.GetOrganizationByID(ctx, organizationID)
+	if err != nil {
+		return database.OrganizationJITProvisioningSettings{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionRead, organization); err != nil {
+		return database.OrganizationJITProvisioningSettings{}, err
+	}
+	return q.db.GetOrganizationJITProvisioningSettings(ctx, organizationID)
+}
+
 func (q *querier) GetOrganizationMemberByUserID(ctx context.Context, arg database.GetOrganizationMemberByUserIDParams) (database.OrganizationMember, error) {
 	return fetch(q.log, q.auth, q.db.GetOrganizationMemberByUserID)(ctx, arg)
 }
@@ -1014,6 +1334,42 @@ func (q *querier) GetOrganizationMembershipsByUserID(ctx context.Context, userID
 	return fetchWithPostFilter(q.auth, q.db.GetOrganizationMembershipsByUserID)(ctx, userID)
 }
 
+func (q *querier) GetOrganizationProvisionerSettings(ctx context.Context, organizationID uuid.UUID) (database.OrganizationProvisionerSettings, error) {
+	// An actor is allowed to read the provisioner settings of an organization
+	// if they are authorized to read the organization.
+	organization, err := q.db.GetOrganizationByID(ctx, organizationID)
+	if err != nil {
+		return database.OrganizationProvisionerSettings{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionRead, organization); err != nil {
+		return database.OrganizationProvisionerSettings{}, err
+	}
+	return q.db.GetOrganizationProvisionerSettings(ctx, organizationID)
+}
+
+func (q *querier) GetOrganizationScheduleSettings(ctx context.Context, organizationID uuid.UUID) (database.OrganizationScheduleSettings, error) {
+	// An actor is allowed to read the schedule settings of an organization if
+	// they are authorized to read the organization.
+	organization, err := q.db.GetOrganizationByID(ctx, organizationID)
+	if err != nil {
+		return database.OrganizationScheduleSettings{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionRead, organization); err != nil {
+		return database.OrganizationScheduleSettings{}, err
+	}
+	return q.db.GetOrganizationScheduleSettings(ctx, organizationID)
+}
+
+func (q *querier) GetOrganizationTemplateVariables(ctx context.Context, organizationID uuid.UUID) ([]database.OrganizationTemplateVariable, error) {
+	// The values are used by the templates of the organization, so an actor
+	// is allowed to read them if they are authorized to read all templates of
+	// the organization.
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceTemplate.InOrg(organizationID)); err != nil {
+		return nil, err
+	}
+	return q.db.GetOrganizationTemplateVariables(ctx, organizationID)
+}
+
 func (q *querier) GetOrganizations(ctx context.Context) ([]database.Organization, error) {
 	fetch := func(ctx context.Context, _ interface{}) ([]database.Organization, error) {
 		return q.db.GetOrganizations(ctx)
@@ -1046,6 +1402,13 @@ func (q *querier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUI
 	return q.db.GetParameterSchemasByJobID(ctx, jobID)
 }
 
+func (q *querier) GetPrebuiltWorkspaces(ctx context.Context, ownerID uuid.UUID) ([]database.GetPrebuiltWorkspacesRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetPrebuiltWorkspaces(ctx, ownerID)
+}
+
 func (q *querier) GetPreviousTemplateVersion(ctx context.Context, arg database.GetPreviousTemplateVersionParams) (database.TemplateVersion, error) {
 	// An actor can read the previous template version if they can read the related template.
 	// If no linked template exists, we check if the actor can read *a* template.
@@ -1094,6 +1457,13 @@ func (q *querier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (data
 	return job, nil
 }
 
+func (q *querier) GetProvisionerJobConcurrency(ctx context.Context, id uuid.UUID) (database.GetProvisionerJobConcurrencyRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return database.GetProvisionerJobConcurrencyRow{}, err
+	}
+	return q.db.GetProvisionerJobConcurrency(ctx, id)
+}
+
 // TODO: we need to add a provisioner job resource
 func (q *querier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.ProvisionerJob, error) {
 	// if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
@@ -1115,6 +1485,18 @@ func (q *querier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt
 	return q.db.GetProvisionerJobsCreatedAfter(ctx, createdAt)
 }
 
+func (q *querier) GetProvisionerKeyByID(ctx context.Context, id uuid.UUID) (database.ProvisionerKey, error) {
+	return fetch(q.log, q.auth, q.db.GetProvisionerKeyByID)(ctx, id)
+}
+
+func (q *querier) GetProvisionerKeyByOrganizationIDAndName(ctx context.Context, arg database.GetProvisionerKeyByOrganizationIDAndNameParams) (database.ProvisionerKey, error) {
+	return fetch(q.log, q.auth, q.db.GetProvisionerKeyByOrganizationIDAndName)(ctx, arg)
+}
+
+func (q *querier) GetProvisionerKeysByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]database.ProvisionerKey, error) {
+	return fetchWithPostFilter(q.auth, q.db.GetProvisionerKeysByOrganizationID)(ctx, organizationID)
+}
+
 func (q *querier) GetProvisionerLogsAfterID(ctx context.Context, arg database.GetProvisionerLogsAfterIDParams) ([]database.ProvisionerJobLog, error) {
 	// Authorized read on job lets the actor also read the logs.
 	_, err := q.GetProvisionerJobByID(ctx, arg.JobID)
@@ -1140,6 +1522,22 @@ func (q *querier) GetQuotaConsumedForUser(ctx context.Context, userID uuid.UUID)
 	return q.db.GetQuotaConsumedForUser(ctx, userID)
 }
 
+func (q *querier) GetQuotaConsumedForUserByWorkspace(ctx context.Context, ownerID uuid.UUID) ([]database.GetQuotaConsumedForUserByWorkspaceRow, error) {
+	err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceUserObject(ownerID))
+	if err != nil {
+		return nil, err
+	}
+	return q.db.GetQuotaConsumedForUserByWorkspace(ctx, ownerID)
+}
+
+func (q *querier) GetQuotaResourceMetadataForUser(ctx context.Context, arg database.GetQuotaResourceMetadataForUserParams) ([]database.WorkspaceResourceMetadatum, error) {
+	err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceUserObject(arg.OwnerID))
+	if err != nil {
+		return nil, err
+	}
+	return q.db.GetQuotaResourceMetadataForUser(ctx, arg)
+}
+
 func (q *querier) GetReplicaByID(ctx context.Context, id uuid.UUID) (database.Replica, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
 		return database.Replica{}, err
@@ -1154,36 +1552,76 @@ func (q *querier) GetReplicasUpdatedAfter(ctx context.Context, updatedAt time.Ti
 	return q.db.GetReplicasUpdatedAfter(ctx, updatedAt)
 }
 
-func (q *querier) GetServiceBanner(ctx context.Context) (string, error) {
-	// No authz checks
-	return q.db.GetServiceBanner(ctx)
+func (q *querier) GetSavedWorkspaceFilterByUserIDAndName(ctx context.Context, arg database.GetSavedWorkspaceFilterByUserIDAndNameParams) (database.SavedWorkspaceFilter, error) {
+	return fetch(q.log, q.auth, q.db.GetSavedWorkspaceFilterByUserIDAndName)(ctx, arg)
 }
 
-func (q *querier) GetTailnetAgents(ctx context.Context, id uuid.UUID) ([]database.TailnetAgent, error) {
-	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceTailnetCoordinator); err != nil {
+func (q *querier) GetSavedWorkspaceFiltersByUserID(ctx context.Context, userID uuid.UUID) ([]database.SavedWorkspaceFilter, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceUserData.WithOwner(userID.String()).WithID(userID)); err != nil {
 		return nil, err
 	}
-	return q.db.GetTailnetAgents(ctx, id)
+	return q.db.GetSavedWorkspaceFiltersByUserID(ctx, userID)
 }
 
-func (q *querier) GetTailnetClientsForAgent(ctx context.Context, agentID uuid.UUID) ([]database.TailnetClient, error) {
-	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceTailnetCoordinator); err != nil {
-		return nil, err
-	}
-	return q.db.GetTailnetClientsForAgent(ctx, agentID)
+func (q *querier) GetScheduleHolidayByID(ctx context.Context, id uuid.UUID) (database.ScheduleHoliday, error) {
+	// No authz checks, holidays apply to every workspace in the deployment.
+	return q.db.GetScheduleHolidayByID(ctx, id)
 }
 
-// Only used by metrics cache.
-func (q *querier) GetTemplateAverageBuildTime(ctx context.Context, arg database.GetTemplateAverageBuildTimeParams) (database.GetTemplateAverageBuildTimeRow, error) {
-	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
-		return database.GetTemplateAverageBuildTimeRow{}, err
-	}
-	return q.db.GetTemplateAverageBuildTime(ctx, arg)
+func (q *querier) GetScheduleHolidays(ctx context.Context) ([]database.ScheduleHoliday, error) {
+	// No authz checks, holidays apply to every workspace in the deployment.
+	return q.db.GetScheduleHolidays(ctx)
 }
 
-func (q *querier) GetTemplateByID(ctx context.Context, id uuid.UUID) (database.Template, error) {
-	return fetch(q.log, q.auth, q.db.GetTemplateByID)(ctx, id)
-}
+func (q *querier) GetScheduleInsights(ctx context.Context, arg database.GetScheduleInsightsParams) ([]database.GetScheduleInsightsRow, error) {
+	for _, templateID := range arg.TemplateIDs {
+		template, err := q.db.GetTemplateByID(ctx, templateID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+			return nil, err
+		}
+	}
+	if len(arg.TemplateIDs) == 0 {
+		if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceTemplate.All()); err != nil {
+			return nil, err
+		}
+	}
+	return q.db.GetScheduleInsights(ctx, arg)
+}
+
+func (q *querier) GetServiceBanner(ctx context.Context) (string, error) {
+	// No authz checks
+	return q.db.GetServiceBanner(ctx)
+}
+
+func (q *querier) GetTailnetAgents(ctx context.Context, id uuid.UUID) ([]database.TailnetAgent, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceTailnetCoordinator); err != nil {
+		return nil, err
+	}
+	return q.db.GetTailnetAgents(ctx, id)
+}
+
+func (q *querier) GetTailnetClientsForAgent(ctx context.Context, agentID uuid.UUID) ([]database.TailnetClient, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceTailnetCoordinator); err != nil {
+		return nil, err
+	}
+	return q.db.GetTailnetClientsForAgent(ctx, agentID)
+}
+
+// Only used by metrics cache.
+func (q *querier) GetTemplateAverageBuildTime(ctx context.Context, arg database.GetTemplateAverageBuildTimeParams) (database.GetTemplateAverageBuildTimeRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return database.GetTemplateAverageBuildTimeRow{}, err
+	}
+	return q.db.GetTemplateAverageBuildTime(ctx, arg)
+}
+
+func (q *querier) GetTemplateByID(ctx context.Context, id uuid.UUID) (database.Template, error) {
+	return fetch(q.log, q.auth, q.db.GetTemplateByID)(ctx, id)
+}
 
 func (q *querier) GetTemplateByOrganizationAndName(ctx context.Context, arg database.GetTemplateByOrganizationAndNameParams) (database.Template, error) {
 	return fetch(q.log, q.auth, q.db.GetTemplateByOrganizationAndName)(ctx, arg)
@@ -1216,6 +1654,26 @@ func (q *querier) GetTemplateDailyInsights(ctx context.Context, arg database.Get
 	return q.db.GetTemplateDailyInsights(ctx, arg)
 }
 
+func (q *querier) GetTemplateDeadlineRecalculationByID(ctx context.Context, id uuid.UUID) (database.TemplateDeadlineRecalculation, error) {
+	recalculation, err := q.db.GetTemplateDeadlineRecalculationByID(ctx, id)
+	if err != nil {
+		return database.TemplateDeadlineRecalculation{}, err
+	}
+	// An actor can read the recalculation if they can read the related
+	// template.
+	if _, err := q.GetTemplateByID(ctx, recalculation.TemplateID); err != nil {
+		return database.TemplateDeadlineRecalculation{}, err
+	}
+	return recalculation, nil
+}
+
+func (q *querier) GetTemplateDeadlineRecalculationsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]database.TemplateDeadlineRecalculation, error) {
+	if _, err := q.GetTemplateByID(ctx, templateID); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateDeadlineRecalculationsByTemplateID(ctx, templateID)
+}
+
 func (q *querier) GetTemplateInsights(ctx context.Context, arg database.GetTemplateInsightsParams) (database.GetTemplateInsightsRow, error) {
 	for _, templateID := range arg.TemplateIDs {
 		template, err := q.db.GetTemplateByID(ctx, templateID)
@@ -1254,6 +1712,46 @@ func (q *querier) GetTemplateParameterInsights(ctx context.Context, arg database
 	return q.db.GetTemplateParameterInsights(ctx, arg)
 }
 
+func (q *querier) GetTemplateParameterValidation(ctx context.Context, templateID uuid.UUID) (database.TemplateParameterValidation, error) {
+	if _, err := q.GetTemplateByID(ctx, templateID); err != nil {
+		return database.TemplateParameterValidation{}, err
+	}
+	return q.db.GetTemplateParameterValidation(ctx, templateID)
+}
+
+func (q *querier) GetTemplatePrebuildPool(ctx context.Context, templateID uuid.UUID) (database.TemplatePrebuildPool, error) {
+	if _, err := q.GetTemplateByID(ctx, templateID); err != nil {
+		return database.TemplatePrebuildPool{}, err
+	}
+	return q.db.GetTemplatePrebuildPool(ctx, templateID)
+}
+
+func (q *querier) GetTemplatePrebuildPools(ctx context.Context) ([]database.TemplatePrebuildPool, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplatePrebuildPools(ctx)
+}
+
+func (q *querier) GetTemplateVersionBuildInsights(ctx context.Context, arg database.GetTemplateVersionBuildInsightsParams) ([]database.GetTemplateVersionBuildInsightsRow, error) {
+	for _, templateID := range arg.TemplateIDs {
+		template, err := q.db.GetTemplateByID(ctx, templateID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+			return nil, err
+		}
+	}
+	if len(arg.TemplateIDs) == 0 {
+		if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceTemplate.All()); err != nil {
+			return nil, err
+		}
+	}
+	return q.db.GetTemplateVersionBuildInsights(ctx, arg)
+}
+
 func (q *querier) GetTemplateVersionByID(ctx context.Context, tvid uuid.UUID) (database.TemplateVersion, error) {
 	tv, err := q.db.GetTemplateVersionByID(ctx, tvid)
 	if err != nil {
@@ -1329,6 +1827,34 @@ func (q *querier) GetTemplateVersionParameters(ctx context.Context, templateVers
 	return q.db.GetTemplateVersionParameters(ctx, templateVersionID)
 }
 
+func (q *querier) GetTemplateVersionPresets(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionPreset, error) {
+	// An actor can read the presets of a template version if they can read
+	// the template version.
+	if _, err := q.GetTemplateVersionByID(ctx, templateVersionID); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateVersionPresets(ctx, templateVersionID)
+}
+
+func (q *querier) GetTemplateVersionPromotionByID(ctx context.Context, id uuid.UUID) (database.TemplateVersionPromotion, error) {
+	promotion, err := q.db.GetTemplateVersionPromotionByID(ctx, id)
+	if err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+	// An actor can read the promotion if they can read the related template.
+	if _, err := q.GetTemplateByID(ctx, promotion.TemplateID); err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+	return promotion, nil
+}
+
+func (q *querier) GetTemplateVersionPromotionsByTemplateID(ctx context.Context, arg database.GetTemplateVersionPromotionsByTemplateIDParams) ([]database.TemplateVersionPromotion, error) {
+	if _, err := q.GetTemplateByID(ctx, arg.TemplateID); err != nil {
+		return nil, err
+	}
+	return q.db.GetTemplateVersionPromotionsByTemplateID(ctx, arg)
+}
+
 func (q *querier) GetTemplateVersionVariables(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionVariable, error) {
 	tv, err := q.db.GetTemplateVersionByID(ctx, templateVersionID)
 	if err != nil {
@@ -1390,6 +1916,10 @@ func (q *querier) GetTemplates(ctx context.Context) ([]database.Template, error)
 	return q.db.GetTemplates(ctx)
 }
 
+func (q *querier) GetTemplatesBySchedulePolicyTemplateID(ctx context.Context, schedulePolicyTemplateID uuid.NullUUID) ([]database.Template, error) {
+	return fetchWithPostFilter(q.auth, q.db.GetTemplatesBySchedulePolicyTemplateID)(ctx, schedulePolicyTemplateID)
+}
+
 func (q *querier) GetTemplatesWithFilter(ctx context.Context, arg database.GetTemplatesWithFilterParams) ([]database.Template, error) {
 	prep, err := prepareSQLFilter(ctx, q.auth, rbac.ActionRead, rbac.ResourceTemplate.Type)
 	if err != nil {
@@ -1420,6 +1950,10 @@ func (q *querier) GetUserCount(ctx context.Context) (int64, error) {
 	return q.db.GetUserCount(ctx)
 }
 
+func (q *querier) GetUserDotfiles(ctx context.Context, userID uuid.UUID) (database.UserDotfile, error) {
+	return fetch(q.log, q.auth, q.db.GetUserDotfiles)(ctx, userID)
+}
+
 func (q *querier) GetUserLatencyInsights(ctx context.Context, arg database.GetUserLatencyInsightsParams) ([]database.GetUserLatencyInsightsRow, error) {
 	for _, templateID := range arg.TemplateIDs {
 		template, err := q.db.GetTemplateByID(ctx, templateID)
@@ -1453,6 +1987,24 @@ func (q *querier) GetUserLinkByUserIDLoginType(ctx context.Context, arg database
 	return q.db.GetUserLinkByUserIDLoginType(ctx, arg)
 }
 
+func (q *querier) GetUserQuietHoursExceptions(ctx context.Context, userID uuid.UUID) ([]database.UserQuietHoursException, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceUserObject(userID)); err != nil {
+		return nil, err
+	}
+	return q.db.GetUserQuietHoursExceptions(ctx, userID)
+}
+
+func (q *querier) GetUserSecretByUserIDAndName(ctx context.Context, arg database.GetUserSecretByUserIDAndNameParams) (database.UserSecret, error) {
+	return fetch(q.log, q.auth, q.db.GetUserSecretByUserIDAndName)(ctx, arg)
+}
+
+func (q *querier) GetUserSecretsByUserID(ctx context.Context, userID uuid.UUID) ([]database.UserSecret, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceUserData.WithOwner(userID.String()).WithID(userID)); err != nil {
+		return nil, err
+	}
+	return q.db.GetUserSecretsByUserID(ctx, userID)
+}
+
 func (q *querier) GetUsers(ctx context.Context, arg database.GetUsersParams) ([]database.GetUsersRow, error) {
 	// This does the filtering in SQL.
 	prep, err := prepareSQLFilter(ctx, q.auth, rbac.ActionRead, rbac.ResourceUser.Type)
@@ -1506,6 +2058,20 @@ func (q *querier) GetWorkspaceAgentByInstanceID(ctx context.Context, authInstanc
 	return agent, nil
 }
 
+func (q *querier) GetWorkspaceAgentDevcontainersByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentDevcontainer, error) {
+	workspace, err := q.db.GetWorkspaceByAgentID(ctx, workspaceAgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.authorizeContext(ctx, rbac.ActionRead, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.db.GetWorkspaceAgentDevcontainersByAgentID(ctx, workspaceAgentID)
+}
+
 func (q *querier) GetWorkspaceAgentLifecycleStateByID(ctx context.Context, id uuid.UUID) (database.GetWorkspaceAgentLifecycleStateByIDRow, error) {
 	_, err := q.GetWorkspaceAgentByID(ctx, id)
 	if err != nil {
@@ -1536,6 +2102,34 @@ func (q *querier) GetWorkspaceAgentMetadata(ctx context.Context, workspaceAgentI
 	return q.db.GetWorkspaceAgentMetadata(ctx, workspaceAgentID)
 }
 
+func (q *querier) GetWorkspaceAgentScriptsByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentScript, error) {
+	workspace, err := q.db.GetWorkspaceByAgentID(ctx, workspaceAgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.authorizeContext(ctx, rbac.ActionRead, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.db.GetWorkspaceAgentScriptsByAgentID(ctx, workspaceAgentID)
+}
+
+func (q *querier) GetWorkspaceAgentSecretsByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentSecret, error) {
+	workspace, err := q.db.GetWorkspaceByAgentID(ctx, workspaceAgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = q.authorizeContext(ctx, rbac.ActionRead, workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return q.db.GetWorkspaceAgentSecretsByAgentID(ctx, workspaceAgentID)
+}
+
 func (q *querier) GetWorkspaceAgentStats(ctx context.Context, createdAfter time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
 	return q.db.GetWorkspaceAgentStats(ctx, createdAfter)
 }
@@ -1601,6 +2195,18 @@ func (q *querier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt ti
 	return q.db.GetWorkspaceAppsCreatedAfter(ctx, createdAt)
 }
 
+func (q *querier) GetWorkspaceBatchByID(ctx context.Context, id uuid.UUID) (database.WorkspaceBatch, error) {
+	return fetch(q.log, q.auth, q.db.GetWorkspaceBatchByID)(ctx, id)
+}
+
+func (q *querier) GetWorkspaceBatchWorkspacesByBatchID(ctx context.Context, batchID uuid.UUID) ([]database.GetWorkspaceBatchWorkspacesByBatchIDRow, error) {
+	// An actor can read the results of a batch if they can read the batch.
+	if _, err := q.GetWorkspaceBatchByID(ctx, batchID); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceBatchWorkspacesByBatchID(ctx, batchID)
+}
+
 func (q *querier) GetWorkspaceBuildByID(ctx context.Context, buildID uuid.UUID) (database.WorkspaceBuild, error) {
 	build, err := q.db.GetWorkspaceBuildByID(ctx, buildID)
 	if err != nil {
@@ -1676,6 +2282,28 @@ func (q *querier) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspaceApp
 	return fetch(q.log, q.auth, q.db.GetWorkspaceByWorkspaceAppID)(ctx, workspaceAppID)
 }
 
+func (q *querier) GetWorkspaceDeadlineExtensionCount(ctx context.Context, arg database.GetWorkspaceDeadlineExtensionCountParams) (int64, error) {
+	// An actor is allowed to count the deadline extensions of a workspace if
+	// they are authorized to read the workspace.
+	workspace, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
+	if err != nil {
+		return 0, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionRead, workspace); err != nil {
+		return 0, err
+	}
+	return q.db.GetWorkspaceDeadlineExtensionCount(ctx, arg)
+}
+
+// GetWorkspaceNameRedirect is used by the workspace app resolver, which
+// authorizes access to the workspace the redirect points to.
+func (q *querier) GetWorkspaceNameRedirect(ctx context.Context, arg database.GetWorkspaceNameRedirectParams) (database.WorkspaceNameRedirect, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return database.WorkspaceNameRedirect{}, err
+	}
+	return q.db.GetWorkspaceNameRedirect(ctx, arg)
+}
+
 func (q *querier) GetWorkspaceProxies(ctx context.Context) ([]database.WorkspaceProxy, error) {
 	return fetchWithPostFilter(q.auth, func(ctx context.Context, _ interface{}) ([]database.WorkspaceProxy, error) {
 		return q.db.GetWorkspaceProxies(ctx)
@@ -1790,6 +2418,19 @@ func (q *querier) GetWorkspaceResourcesCreatedAfter(ctx context.Context, created
 	return q.db.GetWorkspaceResourcesCreatedAfter(ctx, createdAt)
 }
 
+func (q *querier) GetWorkspaceScheduleOverrideByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (database.WorkspaceScheduleOverride, error) {
+	// An actor is allowed to read a workspace schedule override if they are
+	// authorized to read the workspace.
+	workspace, err := q.db.GetWorkspaceByID(ctx, workspaceID)
+	if err != nil {
+		return database.WorkspaceScheduleOverride{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionRead, workspace); err != nil {
+		return database.WorkspaceScheduleOverride{}, err
+	}
+	return q.db.GetWorkspaceScheduleOverrideByWorkspaceID(ctx, workspaceID)
+}
+
 func (q *querier) GetWorkspaces(ctx context.Context, arg database.GetWorkspacesParams) ([]database.GetWorkspacesRow, error) {
 	prep, err := prepareSQLFilter(ctx, q.auth, rbac.ActionRead, rbac.ResourceWorkspace.Type)
 	if err != nil {
@@ -1798,10 +2439,21 @@ func (q *querier) GetWorkspaces(ctx context.Context, arg database.GetWorkspacesP
 	return q.db.GetAuthorizedWorkspaces(ctx, arg, prep)
 }
 
+func (q *querier) GetWorkspacesByIDs(ctx context.Context, ids []uuid.UUID) ([]database.Workspace, error) {
+	return fetchWithPostFilter(q.auth, q.db.GetWorkspacesByIDs)(ctx, ids)
+}
+
 func (q *querier) GetWorkspacesEligibleForTransition(ctx context.Context, now time.Time) ([]database.Workspace, error) {
 	return q.db.GetWorkspacesEligibleForTransition(ctx, now)
 }
 
+func (q *querier) GetWorkspacesWithImminentAutostop(ctx context.Context, arg database.GetWorkspacesWithImminentAutostopParams) ([]database.GetWorkspacesWithImminentAutostopRow, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceSystem); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspacesWithImminentAutostop(ctx, arg)
+}
+
 func (q *querier) InsertAPIKey(ctx context.Context, arg database.InsertAPIKeyParams) (database.APIKey, error) {
 	return insert(q.log, q.auth,
 		rbac.ResourceAPIKey.WithOwner(arg.UserID.String()),
@@ -1817,6 +2469,13 @@ func (q *querier) InsertAuditLog(ctx context.Context, arg database.InsertAuditLo
 	return insert(q.log, q.auth, rbac.ResourceAuditLog, q.db.InsertAuditLog)(ctx, arg)
 }
 
+func (q *querier) InsertCustomRole(ctx context.Context, arg database.InsertCustomRoleParams) (database.CustomRole, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceCustomRole); err != nil {
+		return database.CustomRole{}, err
+	}
+	return q.db.InsertCustomRole(ctx, arg)
+}
+
 func (q *querier) InsertDERPMeshKey(ctx context.Context, value string) error {
 	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
 		return err
@@ -1908,6 +2567,10 @@ func (q *querier) InsertProvisionerJobLogs(ctx context.Context, arg database.Ins
 	return q.db.InsertProvisionerJobLogs(ctx, arg)
 }
 
+func (q *querier) InsertProvisionerKey(ctx context.Context, arg database.InsertProvisionerKeyParams) (database.ProvisionerKey, error) {
+	return insert(q.log, q.auth, rbac.ResourceProvisionerDaemon.InOrg(arg.OrganizationID), q.db.InsertProvisionerKey)(ctx, arg)
+}
+
 func (q *querier) InsertReplica(ctx context.Context, arg database.InsertReplicaParams) (database.Replica, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
 		return database.Replica{}, err
@@ -1915,6 +2578,17 @@ func (q *querier) InsertReplica(ctx context.Context, arg database.InsertReplicaP
 	return q.db.InsertReplica(ctx, arg)
 }
 
+func (q *querier) InsertSavedWorkspaceFilter(ctx context.Context, arg database.InsertSavedWorkspaceFilterParams) (database.SavedWorkspaceFilter, error) {
+	return insert(q.log, q.auth, rbac.ResourceUserData.WithOwner(arg.UserID.String()).WithID(arg.UserID), q.db.InsertSavedWorkspaceFilter)(ctx, arg)
+}
+
+func (q *querier) InsertScheduleHoliday(ctx context.Context, arg database.InsertScheduleHolidayParams) (database.ScheduleHoliday, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceDeploymentValues); err != nil {
+		return database.ScheduleHoliday{}, err
+	}
+	return q.db.InsertScheduleHoliday(ctx, arg)
+}
+
 func (q *querier) InsertTemplate(ctx context.Context, arg database.InsertTemplateParams) error {
 	obj := rbac.ResourceTemplate.InOrg(arg.OrganizationID)
 	if err := q.authorizeContext(ctx, rbac.ActionCreate, obj); err != nil {
@@ -1923,6 +2597,19 @@ func (q *querier) InsertTemplate(ctx context.Context, arg database.InsertTemplat
 	return q.db.InsertTemplate(ctx, arg)
 }
 
+func (q *querier) InsertTemplateDeadlineRecalculation(ctx context.Context, arg database.InsertTemplateDeadlineRecalculationParams) (database.TemplateDeadlineRecalculation, error) {
+	template, err := q.db.GetTemplateByID(ctx, arg.TemplateID)
+	if err != nil {
+		return database.TemplateDeadlineRecalculation{}, err
+	}
+	// Recalculating the deadlines requires the same permission as changing
+	// the schedule of the template.
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+		return database.TemplateDeadlineRecalculation{}, err
+	}
+	return q.db.InsertTemplateDeadlineRecalculation(ctx, arg)
+}
+
 func (q *querier) InsertTemplateVersion(ctx context.Context, arg database.InsertTemplateVersionParams) error {
 	if !arg.TemplateID.Valid {
 		// Making a new template version is the same permission as creating a new template.
@@ -1953,6 +2640,44 @@ func (q *querier) InsertTemplateVersionParameter(ctx context.Context, arg databa
 	return q.db.InsertTemplateVersionParameter(ctx, arg)
 }
 
+func (q *querier) InsertTemplateVersionPreset(ctx context.Context, arg database.InsertTemplateVersionPresetParams) (database.TemplateVersionPreset, error) {
+	tv, err := q.db.GetTemplateVersionByID(ctx, arg.TemplateVersionID)
+	if err != nil {
+		return database.TemplateVersionPreset{}, err
+	}
+
+	// Presets are created with their template version, so this requires the
+	// same permission as creating the template version.
+	var object rbac.Objecter
+	template, err := q.db.GetTemplateByID(ctx, tv.TemplateID.UUID)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return database.TemplateVersionPreset{}, err
+		}
+		object = rbac.ResourceTemplate.InOrg(tv.OrganizationID)
+	} else {
+		object = template
+	}
+
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, object); err != nil {
+		return database.TemplateVersionPreset{}, err
+	}
+	return q.db.InsertTemplateVersionPreset(ctx, arg)
+}
+
+func (q *querier) InsertTemplateVersionPromotion(ctx context.Context, arg database.InsertTemplateVersionPromotionParams) (database.TemplateVersionPromotion, error) {
+	template, err := q.db.GetTemplateByID(ctx, arg.TemplateID)
+	if err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+	// Requesting a promotion requires the same permission as changing the
+	// active version of the template.
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+	return q.db.InsertTemplateVersionPromotion(ctx, arg)
+}
+
 func (q *querier) InsertTemplateVersionVariable(ctx context.Context, arg database.InsertTemplateVersionVariableParams) (database.TemplateVersionVariable, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
 		return database.TemplateVersionVariable{}, err
@@ -1989,6 +2714,17 @@ func (q *querier) InsertUserLink(ctx context.Context, arg database.InsertUserLin
 	return q.db.InsertUserLink(ctx, arg)
 }
 
+func (q *querier) InsertUserQuietHoursException(ctx context.Context, arg database.InsertUserQuietHoursExceptionParams) (database.UserQuietHoursException, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceUserObject(arg.UserID)); err != nil {
+		return database.UserQuietHoursException{}, err
+	}
+	return q.db.InsertUserQuietHoursException(ctx, arg)
+}
+
+func (q *querier) InsertUserSecret(ctx context.Context, arg database.InsertUserSecretParams) (database.UserSecret, error) {
+	return insert(q.log, q.auth, rbac.ResourceUserData.WithOwner(arg.UserID.String()).WithID(arg.UserID), q.db.InsertUserSecret)(ctx, arg)
+}
+
 func (q *querier) InsertWorkspace(ctx context.Context, arg database.InsertWorkspaceParams) (database.Workspace, error) {
 	obj := rbac.ResourceWorkspace.WithOwner(arg.OwnerID.String()).InOrg(arg.OrganizationID)
 	return insert(q.log, q.auth, obj, q.db.InsertWorkspace)(ctx, arg)
@@ -2003,6 +2739,16 @@ func (q *querier) InsertWorkspaceAgent(ctx context.Context, arg database.InsertW
 	return q.db.InsertWorkspaceAgent(ctx, arg)
 }
 
+func (q *querier) InsertWorkspaceAgentDevcontainer(ctx context.Context, arg database.InsertWorkspaceAgentDevcontainerParams) error {
+	// Like agent scripts, devcontainers may belong to an orphaned agent used by
+	// a dry run build.
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+
+	return q.db.InsertWorkspaceAgentDevcontainer(ctx, arg)
+}
+
 func (q *querier) InsertWorkspaceAgentLogs(ctx context.Context, arg database.InsertWorkspaceAgentLogsParams) ([]database.WorkspaceAgentLog, error) {
 	return q.db.InsertWorkspaceAgentLogs(ctx, arg)
 }
@@ -2017,6 +2763,26 @@ func (q *querier) InsertWorkspaceAgentMetadata(ctx context.Context, arg database
 	return q.db.InsertWorkspaceAgentMetadata(ctx, arg)
 }
 
+func (q *querier) InsertWorkspaceAgentScript(ctx context.Context, arg database.InsertWorkspaceAgentScriptParams) error {
+	// Like agent metadata, scripts may belong to an orphaned agent used by a
+	// dry run build.
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+
+	return q.db.InsertWorkspaceAgentScript(ctx, arg)
+}
+
+func (q *querier) InsertWorkspaceAgentSecret(ctx context.Context, arg database.InsertWorkspaceAgentSecretParams) error {
+	// Like agent scripts, secrets may belong to an orphaned agent used by a
+	// dry run build.
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+
+	return q.db.InsertWorkspaceAgentSecret(ctx, arg)
+}
+
 func (q *querier) InsertWorkspaceAgentStat(ctx context.Context, arg database.InsertWorkspaceAgentStatParams) (database.WorkspaceAgentStat, error) {
 	// TODO: This is a workspace agent operation. Should users be able to query this?
 	// Not really sure what this is for.
@@ -2046,6 +2812,13 @@ func (q *querier) InsertWorkspaceApp(ctx context.Context, arg database.InsertWor
 	return q.db.InsertWorkspaceApp(ctx, arg)
 }
 
+func (q *querier) InsertWorkspaceAppAccessLogs(ctx context.Context, arg database.InsertWorkspaceAppAccessLogsParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.InsertWorkspaceAppAccessLogs(ctx, arg)
+}
+
 func (q *querier) InsertWorkspaceAppStats(ctx context.Context, arg database.InsertWorkspaceAppStatsParams) error {
 	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceSystem); err != nil {
 		return err
@@ -2053,6 +2826,21 @@ func (q *querier) InsertWorkspaceAppStats(ctx context.Context, arg database.Inse
 	return q.db.InsertWorkspaceAppStats(ctx, arg)
 }
 
+func (q *querier) InsertWorkspaceBatch(ctx context.Context, arg database.InsertWorkspaceBatchParams) (database.WorkspaceBatch, error) {
+	return insert(q.log, q.auth, rbac.ResourceUserData.WithOwner(arg.InitiatorID.String()).WithID(arg.InitiatorID), q.db.InsertWorkspaceBatch)(ctx, arg)
+}
+
+func (q *querier) InsertWorkspaceBatchWorkspaces(ctx context.Context, arg database.InsertWorkspaceBatchWorkspacesParams) error {
+	batch, err := q.db.GetWorkspaceBatchByID(ctx, arg.BatchID)
+	if err != nil {
+		return err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, batch); err != nil {
+		return err
+	}
+	return q.db.InsertWorkspaceBatchWorkspaces(ctx, arg)
+}
+
 func (q *querier) InsertWorkspaceBuild(ctx context.Context, arg database.InsertWorkspaceBuildParams) error {
 	w, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
 	if err != nil {
@@ -2091,6 +2879,19 @@ func (q *querier) InsertWorkspaceBuildParameters(ctx context.Context, arg databa
 	return q.db.InsertWorkspaceBuildParameters(ctx, arg)
 }
 
+func (q *querier) InsertWorkspaceDeadlineExtension(ctx context.Context, arg database.InsertWorkspaceDeadlineExtensionParams) (database.WorkspaceDeadlineExtension, error) {
+	// An actor is allowed to extend the deadline of a workspace if they are
+	// authorized to update the workspace.
+	workspace, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
+	if err != nil {
+		return database.WorkspaceDeadlineExtension{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, workspace); err != nil {
+		return database.WorkspaceDeadlineExtension{}, err
+	}
+	return q.db.InsertWorkspaceDeadlineExtension(ctx, arg)
+}
+
 func (q *querier) InsertWorkspaceProxy(ctx context.Context, arg database.InsertWorkspaceProxyParams) (database.WorkspaceProxy, error) {
 	return insert(q.log, q.auth, rbac.ResourceWorkspaceProxy, q.db.InsertWorkspaceProxy)(ctx, arg)
 }
@@ -2116,6 +2917,13 @@ func (q *querier) RegisterWorkspaceProxy(ctx context.Context, arg database.Regis
 	return updateWithReturn(q.log, q.auth, fetch, q.db.RegisterWorkspaceProxy)(ctx, arg)
 }
 
+func (q *querier) RotateWorkspaceProxyToken(ctx context.Context, arg database.RotateWorkspaceProxyTokenParams) (database.WorkspaceProxy, error) {
+	fetch := func(ctx context.Context, arg database.RotateWorkspaceProxyTokenParams) (database.WorkspaceProxy, error) {
+		return q.db.GetWorkspaceProxyByID(ctx, arg.ID)
+	}
+	return updateWithReturn(q.log, q.auth, fetch, q.db.RotateWorkspaceProxyToken)(ctx, arg)
+}
+
 func (q *querier) TryAcquireLock(ctx context.Context, id int64) (bool, error) {
 	return q.db.TryAcquireLock(ctx, id)
 }
@@ -2127,6 +2935,13 @@ func (q *querier) UpdateAPIKeyByID(ctx context.Context, arg database.UpdateAPIKe
 	return update(q.log, q.auth, fetch, q.db.UpdateAPIKeyByID)(ctx, arg)
 }
 
+func (q *querier) UpdateCustomRole(ctx context.Context, arg database.UpdateCustomRoleParams) (database.CustomRole, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceCustomRole); err != nil {
+		return database.CustomRole{}, err
+	}
+	return q.db.UpdateCustomRole(ctx, arg)
+}
+
 func (q *querier) UpdateGitAuthLink(ctx context.Context, arg database.UpdateGitAuthLinkParams) (database.GitAuthLink, error) {
 	fetch := func(ctx context.Context, arg database.UpdateGitAuthLinkParams) (database.GitAuthLink, error) {
 		return q.db.GetGitAuthLink(ctx, database.GetGitAuthLinkParams{UserID: arg.UserID, ProviderID: arg.ProviderID})
@@ -2176,6 +2991,22 @@ func (q *querier) UpdateMemberRoles(ctx context.Context, arg database.UpdateMemb
 	return q.db.UpdateMemberRoles(ctx, arg)
 }
 
+// UpdateOrganizationBudgetAlertedThreshold is used by the budget alert monitor
+// to record the thresholds it sent alerts for.
+func (q *querier) UpdateOrganizationBudgetAlertedThreshold(ctx context.Context, arg database.UpdateOrganizationBudgetAlertedThresholdParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateOrganizationBudgetAlertedThreshold(ctx, arg)
+}
+
+func (q *querier) UpdateProvisionerDaemonCacheStats(ctx context.Context, arg database.UpdateProvisionerDaemonCacheStatsParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateProvisionerDaemonCacheStats(ctx, arg)
+}
+
 // TODO: We need to create a ProvisionerJob resource type
 func (q *querier) UpdateProvisionerJobByID(ctx context.Context, arg database.UpdateProvisionerJobByIDParams) error {
 	// if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
@@ -2184,6 +3015,13 @@ func (q *querier) UpdateProvisionerJobByID(ctx context.Context, arg database.Upd
 	return q.db.UpdateProvisionerJobByID(ctx, arg)
 }
 
+func (q *querier) UpdateProvisionerJobNotBeforeByID(ctx context.Context, arg database.UpdateProvisionerJobNotBeforeByIDParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateProvisionerJobNotBeforeByID(ctx, arg)
+}
+
 func (q *querier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg database.UpdateProvisionerJobWithCancelByIDParams) error {
 	job, err := q.db.GetProvisionerJobByID(ctx, arg.ID)
 	if err != nil {
@@ -2259,6 +3097,13 @@ func (q *querier) UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg
 	return q.db.UpdateProvisionerJobWithCompleteByID(ctx, arg)
 }
 
+func (q *querier) UpdateProvisionerKeyLastUsedAt(ctx context.Context, arg database.UpdateProvisionerKeyLastUsedAtParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateProvisionerKeyLastUsedAt(ctx, arg)
+}
+
 func (q *querier) UpdateReplica(ctx context.Context, arg database.UpdateReplicaParams) (database.Replica, error) {
 	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
 		return database.Replica{}, err
@@ -2266,6 +3111,23 @@ func (q *querier) UpdateReplica(ctx context.Context, arg database.UpdateReplicaP
 	return q.db.UpdateReplica(ctx, arg)
 }
 
+func (q *querier) UpdateSavedWorkspaceFilter(ctx context.Context, arg database.UpdateSavedWorkspaceFilterParams) (database.SavedWorkspaceFilter, error) {
+	fetch := func(ctx context.Context, arg database.UpdateSavedWorkspaceFilterParams) (database.SavedWorkspaceFilter, error) {
+		return q.db.GetSavedWorkspaceFilterByUserIDAndName(ctx, database.GetSavedWorkspaceFilterByUserIDAndNameParams{
+			UserID: arg.UserID,
+			Name:   arg.Name,
+		})
+	}
+	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateSavedWorkspaceFilter)(ctx, arg)
+}
+
+func (q *querier) UpdateScheduleHolidayByID(ctx context.Context, arg database.UpdateScheduleHolidayByIDParams) (database.ScheduleHoliday, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceDeploymentValues); err != nil {
+		return database.ScheduleHoliday{}, err
+	}
+	return q.db.UpdateScheduleHolidayByID(ctx, arg)
+}
+
 func (q *querier) UpdateTemplateACLByID(ctx context.Context, arg database.UpdateTemplateACLByIDParams) error {
 	fetch := func(ctx context.Context, arg database.UpdateTemplateACLByIDParams) (database.Template, error) {
 		return q.db.GetTemplateByID(ctx, arg.ID)
@@ -2282,6 +3144,20 @@ func (q *querier) UpdateTemplateActiveVersionByID(ctx context.Context, arg datab
 	return update(q.log, q.auth, fetch, q.db.UpdateTemplateActiveVersionByID)(ctx, arg)
 }
 
+func (q *querier) UpdateTemplateDeadlineRecalculationCompletedByID(ctx context.Context, arg database.UpdateTemplateDeadlineRecalculationCompletedByIDParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateTemplateDeadlineRecalculationCompletedByID(ctx, arg)
+}
+
+func (q *querier) UpdateTemplateDeadlineRecalculationProgressByID(ctx context.Context, arg database.UpdateTemplateDeadlineRecalculationProgressByIDParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateTemplateDeadlineRecalculationProgressByID(ctx, arg)
+}
+
 // Deprecated: use SoftDeleteTemplateByID instead.
 func (q *querier) UpdateTemplateDeletedByID(ctx context.Context, arg database.UpdateTemplateDeletedByIDParams) error {
 	return q.SoftDeleteTemplateByID(ctx, arg.ID)
@@ -2301,6 +3177,13 @@ func (q *querier) UpdateTemplateScheduleByID(ctx context.Context, arg database.U
 	return update(q.log, q.auth, fetch, q.db.UpdateTemplateScheduleByID)(ctx, arg)
 }
 
+func (q *querier) UpdateTemplateSchedulePolicyByID(ctx context.Context, arg database.UpdateTemplateSchedulePolicyByIDParams) error {
+	fetch := func(ctx context.Context, arg database.UpdateTemplateSchedulePolicyByIDParams) (database.Template, error) {
+		return q.db.GetTemplateByID(ctx, arg.ID)
+	}
+	return update(q.log, q.auth, fetch, q.db.UpdateTemplateSchedulePolicyByID)(ctx, arg)
+}
+
 func (q *querier) UpdateTemplateVersionByID(ctx context.Context, arg database.UpdateTemplateVersionByIDParams) error {
 	// An actor is allowed to update the template version if they are authorized to update the template.
 	tv, err := q.db.GetTemplateVersionByID(ctx, arg.ID)
@@ -2367,6 +3250,21 @@ func (q *querier) UpdateTemplateVersionGitAuthProvidersByJobID(ctx context.Conte
 	return q.db.UpdateTemplateVersionGitAuthProvidersByJobID(ctx, arg)
 }
 
+func (q *querier) UpdateTemplateVersionPromotionStatusByID(ctx context.Context, arg database.UpdateTemplateVersionPromotionStatusByIDParams) (database.TemplateVersionPromotion, error) {
+	promotion, err := q.db.GetTemplateVersionPromotionByID(ctx, arg.ID)
+	if err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+	template, err := q.db.GetTemplateByID(ctx, promotion.TemplateID)
+	if err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+	return q.db.UpdateTemplateVersionPromotionStatusByID(ctx, arg)
+}
+
 // UpdateUserDeletedByID
 // Deprecated: Delete this function in favor of 'SoftDeleteUserByID'. Deletes are
 // irreversible.
@@ -2469,6 +3367,16 @@ func (q *querier) UpdateUserRoles(ctx context.Context, arg database.UpdateUserRo
 	return q.db.UpdateUserRoles(ctx, arg)
 }
 
+func (q *querier) UpdateUserSecret(ctx context.Context, arg database.UpdateUserSecretParams) (database.UserSecret, error) {
+	fetch := func(ctx context.Context, arg database.UpdateUserSecretParams) (database.UserSecret, error) {
+		return q.db.GetUserSecretByUserIDAndName(ctx, database.GetUserSecretByUserIDAndNameParams{
+			UserID: arg.UserID,
+			Name:   arg.Name,
+		})
+	}
+	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateUserSecret)(ctx, arg)
+}
+
 func (q *querier) UpdateUserStatus(ctx context.Context, arg database.UpdateUserStatusParams) (database.User, error) {
 	fetch := func(ctx context.Context, arg database.UpdateUserStatusParams) (database.User, error) {
 		return q.db.GetUserByID(ctx, arg.ID)
@@ -2535,6 +3443,20 @@ func (q *querier) UpdateWorkspaceAgentMetadata(ctx context.Context, arg database
 	return q.db.UpdateWorkspaceAgentMetadata(ctx, arg)
 }
 
+func (q *querier) UpdateWorkspaceAgentScriptStatus(ctx context.Context, arg database.UpdateWorkspaceAgentScriptStatusParams) error {
+	workspace, err := q.db.GetWorkspaceByAgentID(ctx, arg.WorkspaceAgentID)
+	if err != nil {
+		return err
+	}
+
+	err = q.authorizeContext(ctx, rbac.ActionUpdate, workspace)
+	if err != nil {
+		return err
+	}
+
+	return q.db.UpdateWorkspaceAgentScriptStatus(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspaceAgentStartupByID(ctx context.Context, arg database.UpdateWorkspaceAgentStartupByIDParams) error {
 	agent, err := q.db.GetWorkspaceAgentByID(ctx, arg.ID)
 	if err != nil {
@@ -2574,6 +3496,27 @@ func (q *querier) UpdateWorkspaceAutostart(ctx context.Context, arg database.Upd
 	return update(q.log, q.auth, fetch, q.db.UpdateWorkspaceAutostart)(ctx, arg)
 }
 
+func (q *querier) UpdateWorkspaceBatchCompletedByID(ctx context.Context, arg database.UpdateWorkspaceBatchCompletedByIDParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateWorkspaceBatchCompletedByID(ctx, arg)
+}
+
+func (q *querier) UpdateWorkspaceBatchUpdatedAtByID(ctx context.Context, arg database.UpdateWorkspaceBatchUpdatedAtByIDParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateWorkspaceBatchUpdatedAtByID(ctx, arg)
+}
+
+func (q *querier) UpdateWorkspaceBatchWorkspace(ctx context.Context, arg database.UpdateWorkspaceBatchWorkspaceParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpdateWorkspaceBatchWorkspace(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspaceBuildByID(ctx context.Context, arg database.UpdateWorkspaceBuildByIDParams) error {
 	build, err := q.db.GetWorkspaceBuildByID(ctx, arg.ID)
 	if err != nil {
@@ -2610,6 +3553,13 @@ func (q *querier) UpdateWorkspaceDeletedByID(ctx context.Context, arg database.U
 	return deleteQ(q.log, q.auth, fetch, q.db.UpdateWorkspaceDeletedByID)(ctx, arg)
 }
 
+func (q *querier) UpdateWorkspaceGroupACLByID(ctx context.Context, arg database.UpdateWorkspaceGroupACLByIDParams) error {
+	fetch := func(ctx context.Context, arg database.UpdateWorkspaceGroupACLByIDParams) (database.Workspace, error) {
+		return q.db.GetWorkspaceByID(ctx, arg.ID)
+	}
+	return update(q.log, q.auth, fetch, q.db.UpdateWorkspaceGroupACLByID)(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspaceLastUsedAt(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	fetch := func(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) (database.Workspace, error) {
 		return q.db.GetWorkspaceByID(ctx, arg.ID)
@@ -2653,11 +3603,25 @@ func (q *querier) UpdateWorkspacesDeletingAtByTemplateID(ctx context.Context, ar
 	return fetchAndExec(q.log, q.auth, rbac.ActionUpdate, fetch, q.db.UpdateWorkspacesDeletingAtByTemplateID)(ctx, arg)
 }
 
+func (q *querier) UpsertACMECertificate(ctx context.Context, arg database.UpsertACMECertificateParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpsertACMECertificate(ctx, arg)
+}
+
 func (q *querier) UpsertAppSecurityKey(ctx context.Context, data string) error {
 	// No authz checks as this is done during startup
 	return q.db.UpsertAppSecurityKey(ctx, data)
 }
 
+func (q *querier) UpsertAuditLogExportCursor(ctx context.Context, arg database.UpsertAuditLogExportCursorParams) error {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
+		return err
+	}
+	return q.db.UpsertAuditLogExportCursor(ctx, arg)
+}
+
 func (q *querier) UpsertDefaultProxy(ctx context.Context, arg database.UpsertDefaultProxyParams) error {
 	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceSystem); err != nil {
 		return err
@@ -2686,6 +3650,71 @@ func (q *querier) UpsertOAuthSigningKey(ctx context.Context, value string) error
 	return q.db.UpsertOAuthSigningKey(ctx, value)
 }
 
+func (q *querier) UpsertOrganizationBudgetSettings(ctx context.Context, arg database.UpsertOrganizationBudgetSettingsParams) (database.OrganizationBudgetSettings, error) {
+	// An actor is allowed to update the budget settings of an organization if
+	// they are authorized to update the organization.
+	organization, err := q.db.GetOrganizationByID(ctx, arg.OrganizationID)
+	if err != nil {
+		return database.OrganizationBudgetSettings{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, organization); err != nil {
+		return database.OrganizationBudgetSettings{}, err
+	}
+	return q.db.UpsertOrganizationBudgetSettings(ctx, arg)
+}
+
+func (q *querier) UpsertOrganizationJITProvisioningSettings(ctx context.Context, arg database.UpsertOrganizationJITProvisioningSettingsParams) (database.OrganizationJITProvisioningSettings, error) {
+	// An actor is allowed to update the JIT provisioning settings of an
+	// organization if they are authorized to update the organization.
+	organization, err := q.db.GetOrganizationByID(ctx, arg.OrganizationID)
+	if err != nil {
+		return database.OrganizationJITProvisioningSettings{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, organization); err != nil {
+		return database.OrganizationJITProvisioningSettings{}, err
+	}
+	return q.db.UpsertOrganizationJITProvisioningSettings(ctx, arg)
+}
+
+func (q *querier) UpsertOrganizationProvisionerSettings(ctx context.Context, arg database.UpsertOrganizationProvisionerSettingsParams) (database.OrganizationProvisionerSettings, error) {
+	// An actor is allowed to update the provisioner settings of an
+	// organization if they are authorized to update the organization.
+	organization, err := q.db.GetOrganizationByID(ctx, arg.OrganizationID)
+	if err != nil {
+		return database.OrganizationProvisionerSettings{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, organization); err != nil {
+		return database.OrganizationProvisionerSettings{}, err
+	}
+	return q.db.UpsertOrganizationProvisionerSettings(ctx, arg)
+}
+
+func (q *querier) UpsertOrganizationScheduleSettings(ctx context.Context, arg database.UpsertOrganizationScheduleSettingsParams) (database.OrganizationScheduleSettings, error) {
+	// An actor is allowed to update the schedule settings of an organization
+	// if they are authorized to update the organization.
+	organization, err := q.db.GetOrganizationByID(ctx, arg.OrganizationID)
+	if err != nil {
+		return database.OrganizationScheduleSettings{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, organization); err != nil {
+		return database.OrganizationScheduleSettings{}, err
+	}
+	return q.db.UpsertOrganizationScheduleSettings(ctx, arg)
+}
+
+func (q *querier) UpsertOrganizationTemplateVariable(ctx context.Context, arg database.UpsertOrganizationTemplateVariableParams) (database.OrganizationTemplateVariable, error) {
+	// An actor is allowed to update the template variables of an organization
+	// if they are authorized to update the organization.
+	organization, err := q.db.GetOrganizationByID(ctx, arg.OrganizationID)
+	if err != nil {
+		return database.OrganizationTemplateVariable{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, organization); err != nil {
+		return database.OrganizationTemplateVariable{}, err
+	}
+	return q.db.UpsertOrganizationTemplateVariable(ctx, arg)
+}
+
 func (q *querier) UpsertServiceBanner(ctx context.Context, value string) error {
 	if err := q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceDeploymentValues); err != nil {
 		return err
@@ -2714,6 +3743,82 @@ func (q *querier) UpsertTailnetCoordinator(ctx context.Context, id uuid.UUID) (d
 	return q.db.UpsertTailnetCoordinator(ctx, id)
 }
 
+func (q *querier) UpsertTemplateParameterValidation(ctx context.Context, arg database.UpsertTemplateParameterValidationParams) (database.TemplateParameterValidation, error) {
+	// An actor is allowed to change the validation rules of a template if
+	// they are authorized to update the template.
+	template, err := q.db.GetTemplateByID(ctx, arg.TemplateID)
+	if err != nil {
+		return database.TemplateParameterValidation{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+		return database.TemplateParameterValidation{}, err
+	}
+	return q.db.UpsertTemplateParameterValidation(ctx, arg)
+}
+
+func (q *querier) UpsertTemplatePrebuildPool(ctx context.Context, arg database.UpsertTemplatePrebuildPoolParams) (database.TemplatePrebuildPool, error) {
+	// An actor is allowed to size the pool of a template if they are
+	// authorized to update the template.
+	template, err := q.db.GetTemplateByID(ctx, arg.TemplateID)
+	if err != nil {
+		return database.TemplatePrebuildPool{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+		return database.TemplatePrebuildPool{}, err
+	}
+	return q.db.UpsertTemplatePrebuildPool(ctx, arg)
+}
+
+func (q *querier) UpsertUserDotfiles(ctx context.Context, arg database.UpsertUserDotfilesParams) (database.UserDotfile, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceUserData.WithOwner(arg.UserID.String()).WithID(arg.UserID)); err != nil {
+		return database.UserDotfile{}, err
+	}
+	return q.db.UpsertUserDotfiles(ctx, arg)
+}
+
+func (q *querier) UpsertWorkspaceAgentPluginMetadata(ctx context.Context, arg database.UpsertWorkspaceAgentPluginMetadataParams) error {
+	workspace, err := q.db.GetWorkspaceByAgentID(ctx, arg.WorkspaceAgentID)
+	if err != nil {
+		return err
+	}
+
+	err = q.authorizeContext(ctx, rbac.ActionUpdate, workspace)
+	if err != nil {
+		return err
+	}
+
+	return q.db.UpsertWorkspaceAgentPluginMetadata(ctx, arg)
+}
+
+func (q *querier) UpsertWorkspaceNameRedirect(ctx context.Context, arg database.UpsertWorkspaceNameRedirectParams) (database.WorkspaceNameRedirect, error) {
+	// Redirects are created when workspaces are renamed.
+	workspace, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
+	if err != nil {
+		return database.WorkspaceNameRedirect{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, workspace); err != nil {
+		return database.WorkspaceNameRedirect{}, err
+	}
+	return q.db.UpsertWorkspaceNameRedirect(ctx, arg)
+}
+
+func (q *querier) UpsertWorkspaceScheduleOverride(ctx context.Context, arg database.UpsertWorkspaceScheduleOverrideParams) (database.WorkspaceScheduleOverride, error) {
+	// An actor is allowed to upsert a workspace schedule override if they are
+	// authorized to update the workspace's template.
+	workspace, err := q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
+	if err != nil {
+		return database.WorkspaceScheduleOverride{}, err
+	}
+	template, err := q.db.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		return database.WorkspaceScheduleOverride{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+		return database.WorkspaceScheduleOverride{}, err
+	}
+	return q.db.UpsertWorkspaceScheduleOverride(ctx, arg)
+}
+
 func (q *querier) GetAuthorizedTemplates(ctx context.Context, arg database.GetTemplatesWithFilterParams, _ rbac.PreparedAuthorized) ([]database.Template, error) {
 	// TODO Delete this function, all GetTemplates should be authorized. For now just call getTemplates on the authz querier.
 	return q.GetTemplatesWithFilter(ctx, arg)
@@ -237,6 +237,84 @@ func (s *MethodTestSuite) TestAuditLogs() {
 			Limit: 10,
 		}).Asserts(rbac.ResourceAuditLog, rbac.ActionRead)
 	}))
+	s.Run("GetAuditLogsCreatedBefore", s.Subtest(func(db database.Store, check *expects) {
+		_ = dbgen.AuditLog(s.T(), db, database.AuditLog{})
+		check.Args(database.GetAuditLogsCreatedBeforeParams{
+			CreatedBefore: database.Now(),
+			LimitCount:    10,
+		}).Asserts(rbac.ResourceAuditLog, rbac.ActionRead)
+	}))
+	s.Run("DeleteAuditLogsByIDs", s.Subtest(func(db database.Store, check *expects) {
+		alog := dbgen.AuditLog(s.T(), db, database.AuditLog{})
+		check.Args([]uuid.UUID{alog.ID}).Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("GetAuditLogsAfterCursor", s.Subtest(func(db database.Store, check *expects) {
+		_ = dbgen.AuditLog(s.T(), db, database.AuditLog{})
+		check.Args(database.GetAuditLogsAfterCursorParams{
+			CreatedBefore: database.Now(),
+			LimitCount:    10,
+		}).Asserts(rbac.ResourceAuditLog, rbac.ActionRead)
+	}))
+	s.Run("UpsertAuditLogExportCursor", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpsertAuditLogExportCursorParams{
+			Sink:      "http http://localhost",
+			LastTime:  database.Now(),
+			UpdatedAt: database.Now(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("GetAuditLogExportCursor", s.Subtest(func(db database.Store, check *expects) {
+		err := db.UpsertAuditLogExportCursor(context.Background(), database.UpsertAuditLogExportCursorParams{
+			Sink:      "http http://localhost",
+			LastTime:  database.Now(),
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(s.T(), err)
+		check.Args("http http://localhost").Asserts(rbac.ResourceSystem, rbac.ActionRead)
+	}))
+}
+
+func (s *MethodTestSuite) TestCustomRoles() {
+	insertRole := func(db database.Store) database.CustomRole {
+		role, err := db.InsertCustomRole(context.Background(), database.InsertCustomRoleParams{
+			Name:            "template-scheduler",
+			DisplayName:     "Template Scheduler",
+			SitePermissions: []byte(`[]`),
+			UserPermissions: []byte(`[]`),
+			CreatedAt:       database.Now(),
+			UpdatedAt:       database.Now(),
+		})
+		require.NoError(s.T(), err)
+		return role
+	}
+	s.Run("GetCustomRoles", s.Subtest(func(db database.Store, check *expects) {
+		role := insertRole(db)
+		check.Args().Asserts(rbac.ResourceCustomRole, rbac.ActionRead).Returns([]database.CustomRole{role})
+	}))
+	s.Run("GetCustomRoleByName", s.Subtest(func(db database.Store, check *expects) {
+		role := insertRole(db)
+		check.Args(role.Name).Asserts(rbac.ResourceCustomRole, rbac.ActionRead).Returns(role)
+	}))
+	s.Run("InsertCustomRole", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertCustomRoleParams{
+			Name:            "template-scheduler",
+			DisplayName:     "Template Scheduler",
+			SitePermissions: []byte(`[]`),
+			UserPermissions: []byte(`[]`),
+		}).Asserts(rbac.ResourceCustomRole, rbac.ActionCreate)
+	}))
+	s.Run("UpdateCustomRole", s.Subtest(func(db database.Store, check *expects) {
+		role := insertRole(db)
+		check.Args(database.UpdateCustomRoleParams{
+			Name:            role.Name,
+			DisplayName:     "Scheduler",
+			SitePermissions: []byte(`[]`),
+			UserPermissions: []byte(`[]`),
+		}).Asserts(rbac.ResourceCustomRole, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteCustomRole", s.Subtest(func(db database.Store, check *expects) {
+		role := insertRole(db)
+		check.Args(role.Name).Asserts(rbac.ResourceCustomRole, rbac.ActionDelete)
+	}))
 }
 
 func (s *MethodTestSuite) TestFile() {
@@ -285,6 +363,11 @@ func (s *MethodTestSuite) TestGroup() {
 			Name:           g.Name,
 		}).Asserts(g, rbac.ActionRead).Returns(g)
 	}))
+	s.Run("GetGroupMemberUserIDs", s.Subtest(func(db database.Store, check *expects) {
+		g := dbgen.Group(s.T(), db, database.Group{})
+		m := dbgen.GroupMember(s.T(), db, database.GroupMember{GroupID: g.ID})
+		check.Args(g.ID).Asserts(g, rbac.ActionRead).Returns([]uuid.UUID{m.UserID})
+	}))
 	s.Run("GetGroupMembers", s.Subtest(func(db database.Store, check *expects) {
 		g := dbgen.Group(s.T(), db, database.Group{})
 		_ = dbgen.GroupMember(s.T(), db, database.GroupMember{})
@@ -445,6 +528,44 @@ func (s *MethodTestSuite) TestProvsionerJob() {
 	}))
 }
 
+func (s *MethodTestSuite) TestProvisionerKey() {
+	s.Run("InsertProvisionerKey", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		check.Args(database.InsertProvisionerKeyParams{
+			ID:             uuid.New(),
+			OrganizationID: o.ID,
+			Name:           "default",
+			Tags:           database.StringMap{},
+		}).Asserts(rbac.ResourceProvisionerDaemon.InOrg(o.ID), rbac.ActionCreate)
+	}))
+	s.Run("GetProvisionerKeyByID", s.Subtest(func(db database.Store, check *expects) {
+		k := dbgen.ProvisionerKey(s.T(), db, database.ProvisionerKey{})
+		check.Args(k.ID).Asserts(k, rbac.ActionRead).Returns(k)
+	}))
+	s.Run("GetProvisionerKeyByOrganizationIDAndName", s.Subtest(func(db database.Store, check *expects) {
+		k := dbgen.ProvisionerKey(s.T(), db, database.ProvisionerKey{})
+		check.Args(database.GetProvisionerKeyByOrganizationIDAndNameParams{
+			OrganizationID: k.OrganizationID,
+			Name:           k.Name,
+		}).Asserts(k, rbac.ActionRead).Returns(k)
+	}))
+	s.Run("GetProvisionerKeysByOrganizationID", s.Subtest(func(db database.Store, check *expects) {
+		k := dbgen.ProvisionerKey(s.T(), db, database.ProvisionerKey{})
+		check.Args(k.OrganizationID).Asserts(k, rbac.ActionRead).Returns([]database.ProvisionerKey{k})
+	}))
+	s.Run("UpdateProvisionerKeyLastUsedAt", s.Subtest(func(db database.Store, check *expects) {
+		k := dbgen.ProvisionerKey(s.T(), db, database.ProvisionerKey{})
+		check.Args(database.UpdateProvisionerKeyLastUsedAtParams{
+			ID:         k.ID,
+			LastUsedAt: sql.NullTime{Time: database.Now(), Valid: true},
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate).Returns()
+	}))
+	s.Run("DeleteProvisionerKey", s.Subtest(func(db database.Store, check *expects) {
+		k := dbgen.ProvisionerKey(s.T(), db, database.ProvisionerKey{})
+		check.Args(k.ID).Asserts(k, rbac.ActionDelete).Returns()
+	}))
+}
+
 func (s *MethodTestSuite) TestLicense() {
 	s.Run("GetLicenses", s.Subtest(func(db database.Store, check *expects) {
 		l, err := db.InsertLicense(context.Background(), database.InsertLicenseParams{
@@ -497,9 +618,47 @@ func (s *MethodTestSuite) TestLicense() {
 		require.NoError(s.T(), err)
 		check.Args().Asserts().Returns("value")
 	}))
+	s.Run("GetScheduleHolidays", s.Subtest(func(db database.Store, check *expects) {
+		h := dbgen.ScheduleHoliday(s.T(), db, database.ScheduleHoliday{})
+		check.Args().Asserts().Returns([]database.ScheduleHoliday{h})
+	}))
+	s.Run("GetScheduleHolidayByID", s.Subtest(func(db database.Store, check *expects) {
+		h := dbgen.ScheduleHoliday(s.T(), db, database.ScheduleHoliday{})
+		check.Args(h.ID).Asserts().Returns(h)
+	}))
+	s.Run("InsertScheduleHoliday", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertScheduleHolidayParams{
+			ID:   uuid.New(),
+			Date: time.Date(2023, 12, 25, 0, 0, 0, 0, time.UTC),
+		}).Asserts(rbac.ResourceDeploymentValues, rbac.ActionCreate)
+	}))
+	s.Run("UpdateScheduleHolidayByID", s.Subtest(func(db database.Store, check *expects) {
+		h := dbgen.ScheduleHoliday(s.T(), db, database.ScheduleHoliday{})
+		check.Args(database.UpdateScheduleHolidayByIDParams{
+			ID:   h.ID,
+			Date: h.Date,
+		}).Asserts(rbac.ResourceDeploymentValues, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteScheduleHolidayByID", s.Subtest(func(db database.Store, check *expects) {
+		h := dbgen.ScheduleHoliday(s.T(), db, database.ScheduleHoliday{})
+		check.Args(h.ID).Asserts(rbac.ResourceDeploymentValues, rbac.ActionDelete).Returns()
+	}))
 }
 
 func (s *MethodTestSuite) TestOrganization() {
+	s.Run("DeleteOrganizationTemplateVariable", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		_, err := db.UpsertOrganizationTemplateVariable(context.Background(), database.UpsertOrganizationTemplateVariableParams{
+			OrganizationID: o.ID,
+			Name:           "vpc_id",
+			Value:          "vpc-123",
+		})
+		require.NoError(s.T(), err)
+		check.Args(database.DeleteOrganizationTemplateVariableParams{
+			OrganizationID: o.ID,
+			Name:           "vpc_id",
+		}).Asserts(o, rbac.ActionUpdate)
+	}))
 	s.Run("GetGroupsByOrganizationID", s.Subtest(func(db database.Store, check *expects) {
 		o := dbgen.Organization(s.T(), db, database.Organization{})
 		a := dbgen.Group(s.T(), db, database.Group{OrganizationID: o.ID})
@@ -515,6 +674,17 @@ func (s *MethodTestSuite) TestOrganization() {
 		o := dbgen.Organization(s.T(), db, database.Organization{})
 		check.Args(o.Name).Asserts(o, rbac.ActionRead).Returns(o)
 	}))
+	s.Run("GetOrganizationBudgetSettings", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		settings, err := db.UpsertOrganizationBudgetSettings(context.Background(), database.UpsertOrganizationBudgetSettingsParams{
+			OrganizationID:           o.ID,
+			DailyBudget:              100,
+			WarningThresholdPercent:  80,
+			CriticalThresholdPercent: 100,
+		})
+		require.NoError(s.T(), err)
+		check.Args(o.ID).Asserts(o, rbac.ActionRead).Returns(settings)
+	}))
 	s.Run("GetOrganizationIDsByMemberIDs", s.Subtest(func(db database.Store, check *expects) {
 		oa := dbgen.Organization(s.T(), db, database.Organization{})
 		ob := dbgen.Organization(s.T(), db, database.Organization{})
@@ -523,6 +693,15 @@ func (s *MethodTestSuite) TestOrganization() {
 		check.Args([]uuid.UUID{ma.UserID, mb.UserID}).
 			Asserts(rbac.ResourceUserObject(ma.UserID), rbac.ActionRead, rbac.ResourceUserObject(mb.UserID), rbac.ActionRead)
 	}))
+	s.Run("GetOrganizationJITProvisioningSettings", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		settings, err := db.UpsertOrganizationJITProvisioningSettings(context.Background(), database.UpsertOrganizationJITProvisioningSettingsParams{
+			OrganizationID: o.ID,
+			WorkspaceName:  "dev",
+		})
+		require.NoError(s.T(), err)
+		check.Args(o.ID).Asserts(o, rbac.ActionRead).Returns(settings)
+	}))
 	s.Run("GetOrganizationMemberByUserID", s.Subtest(func(db database.Store, check *expects) {
 		mem := dbgen.OrganizationMember(s.T(), db, database.OrganizationMember{})
 		check.Args(database.GetOrganizationMemberByUserIDParams{
@@ -536,6 +715,34 @@ func (s *MethodTestSuite) TestOrganization() {
 		b := dbgen.OrganizationMember(s.T(), db, database.OrganizationMember{UserID: u.ID})
 		check.Args(u.ID).Asserts(a, rbac.ActionRead, b, rbac.ActionRead).Returns(slice.New(a, b))
 	}))
+	s.Run("GetOrganizationProvisionerSettings", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		settings, err := db.UpsertOrganizationProvisionerSettings(context.Background(), database.UpsertOrganizationProvisionerSettingsParams{
+			OrganizationID:    o.ID,
+			MaxConcurrentJobs: 10,
+		})
+		require.NoError(s.T(), err)
+		check.Args(o.ID).Asserts(o, rbac.ActionRead).Returns(settings)
+	}))
+	s.Run("GetOrganizationScheduleSettings", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		settings, err := db.UpsertOrganizationScheduleSettings(context.Background(), database.UpsertOrganizationScheduleSettingsParams{
+			OrganizationID: o.ID,
+			DefaultTTL:     int64(time.Hour),
+		})
+		require.NoError(s.T(), err)
+		check.Args(o.ID).Asserts(o, rbac.ActionRead).Returns(settings)
+	}))
+	s.Run("GetOrganizationTemplateVariables", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		variable, err := db.UpsertOrganizationTemplateVariable(context.Background(), database.UpsertOrganizationTemplateVariableParams{
+			OrganizationID: o.ID,
+			Name:           "vpc_id",
+			Value:          "vpc-123",
+		})
+		require.NoError(s.T(), err)
+		check.Args(o.ID).Asserts(rbac.ResourceTemplate.InOrg(o.ID), rbac.ActionRead).Returns([]database.OrganizationTemplateVariable{variable})
+	}))
 	s.Run("GetOrganizations", s.Subtest(func(db database.Store, check *expects) {
 		a := dbgen.Organization(s.T(), db, database.Organization{})
 		b := dbgen.Organization(s.T(), db, database.Organization{})
@@ -588,6 +795,44 @@ func (s *MethodTestSuite) TestOrganization() {
 			rbac.ResourceRoleAssignment.InOrg(o.ID), rbac.ActionDelete, // org-admin
 		).Returns(out)
 	}))
+	s.Run("UpsertOrganizationBudgetSettings", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		check.Args(database.UpsertOrganizationBudgetSettingsParams{
+			OrganizationID:           o.ID,
+			DailyBudget:              100,
+			WarningThresholdPercent:  80,
+			CriticalThresholdPercent: 100,
+		}).Asserts(o, rbac.ActionUpdate)
+	}))
+	s.Run("UpsertOrganizationJITProvisioningSettings", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		check.Args(database.UpsertOrganizationJITProvisioningSettingsParams{
+			OrganizationID: o.ID,
+			WorkspaceName:  "dev",
+		}).Asserts(o, rbac.ActionUpdate)
+	}))
+	s.Run("UpsertOrganizationProvisionerSettings", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		check.Args(database.UpsertOrganizationProvisionerSettingsParams{
+			OrganizationID:    o.ID,
+			MaxConcurrentJobs: 10,
+		}).Asserts(o, rbac.ActionUpdate)
+	}))
+	s.Run("UpsertOrganizationScheduleSettings", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		check.Args(database.UpsertOrganizationScheduleSettingsParams{
+			OrganizationID: o.ID,
+			DefaultTTL:     int64(time.Hour),
+		}).Asserts(o, rbac.ActionUpdate)
+	}))
+	s.Run("UpsertOrganizationTemplateVariable", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		check.Args(database.UpsertOrganizationTemplateVariableParams{
+			OrganizationID: o.ID,
+			Name:           "vpc_id",
+			Value:          "vpc-123",
+		}).Asserts(o, rbac.ActionUpdate)
+	}))
 }
 
 func (s *MethodTestSuite) TestWorkspaceProxy() {
@@ -602,6 +847,16 @@ func (s *MethodTestSuite) TestWorkspaceProxy() {
 			ID: p.ID,
 		}).Asserts(p, rbac.ActionUpdate)
 	}))
+	s.Run("RotateWorkspaceProxyToken", s.Subtest(func(db database.Store, check *expects) {
+		p, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{})
+		check.Args(database.RotateWorkspaceProxyTokenParams{
+			ID:                       p.ID,
+			CurrentTokenHashedSecret: p.TokenHashedSecret,
+			TokenHashedSecret:        []byte("new-secret"),
+			TokenExpiresAt:           database.Now().Add(time.Hour),
+			PreviousTokenExpiresAt:   database.Now().Add(time.Minute),
+		}).Asserts(p, rbac.ActionUpdate)
+	}))
 	s.Run("GetWorkspaceProxyByID", s.Subtest(func(db database.Store, check *expects) {
 		p, _ := dbgen.WorkspaceProxy(s.T(), db, database.WorkspaceProxy{})
 		check.Args(p.ID).Asserts(p, rbac.ActionRead).Returns(p)
@@ -621,6 +876,10 @@ func (s *MethodTestSuite) TestWorkspaceProxy() {
 }
 
 func (s *MethodTestSuite) TestTemplate() {
+	s.Run("GetActiveWorkspaceBuildsByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(t1.ID).Asserts(t1, rbac.ActionUpdate)
+	}))
 	s.Run("GetPreviousTemplateVersion", s.Subtest(func(db database.Store, check *expects) {
 		tvid := uuid.New()
 		now := time.Now()
@@ -696,6 +955,24 @@ func (s *MethodTestSuite) TestTemplate() {
 		})
 		check.Args(tv.ID).Asserts(t1, rbac.ActionRead).Returns([]database.TemplateVersionVariable{tvv1})
 	}))
+	s.Run("GetTemplateVersionPresets", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		check.Args(tv.ID).Asserts(t1, rbac.ActionRead)
+	}))
+	s.Run("InsertTemplateVersionPreset", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		check.Args(database.InsertTemplateVersionPresetParams{
+			TemplateVersionID: tv.ID,
+			Name:              "small",
+			Parameters:        json.RawMessage(`[]`),
+		}).Asserts(t1, rbac.ActionCreate)
+	}))
 	s.Run("GetTemplateGroupRoles", s.Subtest(func(db database.Store, check *expects) {
 		t1 := dbgen.Template(s.T(), db, database.Template{})
 		check.Args(t1.ID).Asserts(t1, rbac.ActionUpdate)
@@ -737,6 +1014,19 @@ func (s *MethodTestSuite) TestTemplate() {
 		})
 		check.Args(now.Add(-time.Hour)).Asserts(rbac.ResourceTemplate.All(), rbac.ActionRead)
 	}))
+	s.Run("GetTemplatesBySchedulePolicyTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		policy := dbgen.Template(s.T(), db, database.Template{})
+		a := dbgen.Template(s.T(), db, database.Template{})
+		policyID := uuid.NullUUID{UUID: policy.ID, Valid: true}
+		err := db.UpdateTemplateSchedulePolicyByID(context.Background(), database.UpdateTemplateSchedulePolicyByIDParams{
+			ID:                       a.ID,
+			UpdatedAt:                a.UpdatedAt,
+			SchedulePolicyTemplateID: policyID,
+		})
+		require.NoError(s.T(), err)
+		a.SchedulePolicyTemplateID = policyID
+		check.Args(policyID).Asserts(a, rbac.ActionRead).Returns(slice.New(a))
+	}))
 	s.Run("GetTemplatesWithFilter", s.Subtest(func(db database.Store, check *expects) {
 		a := dbgen.Template(s.T(), db, database.Template{})
 		// No asserts because SQLFilter.
@@ -787,6 +1077,108 @@ func (s *MethodTestSuite) TestTemplate() {
 			ActiveVersionID: tv.ID,
 		}).Asserts(t1, rbac.ActionUpdate).Returns()
 	}))
+	s.Run("InsertTemplateVersionPromotion", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		check.Args(database.InsertTemplateVersionPromotionParams{
+			ID:                uuid.New(),
+			TemplateID:        t1.ID,
+			TemplateVersionID: tv.ID,
+			Status:            database.TemplateVersionPromotionStatusPending,
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("GetTemplateVersionPromotionByID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		p := dbgen.TemplateVersionPromotion(s.T(), db, database.TemplateVersionPromotion{TemplateID: t1.ID})
+		check.Args(p.ID).Asserts(t1, rbac.ActionRead).Returns(p)
+	}))
+	s.Run("GetTemplateVersionPromotionsByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		p := dbgen.TemplateVersionPromotion(s.T(), db, database.TemplateVersionPromotion{TemplateID: t1.ID})
+		check.Args(database.GetTemplateVersionPromotionsByTemplateIDParams{
+			TemplateID: t1.ID,
+		}).Asserts(t1, rbac.ActionRead).Returns([]database.TemplateVersionPromotion{p})
+	}))
+	s.Run("InsertTemplateDeadlineRecalculation", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.InsertTemplateDeadlineRecalculationParams{
+			ID:         uuid.New(),
+			TemplateID: t1.ID,
+			CreatedAt:  database.Now(),
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("GetTemplateDeadlineRecalculationByID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		r, err := db.InsertTemplateDeadlineRecalculation(context.Background(), database.InsertTemplateDeadlineRecalculationParams{
+			ID:         uuid.New(),
+			TemplateID: t1.ID,
+			CreatedAt:  database.Now(),
+		})
+		require.NoError(s.T(), err)
+		check.Args(r.ID).Asserts(t1, rbac.ActionRead).Returns(r)
+	}))
+	s.Run("GetTemplateDeadlineRecalculationsByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		r, err := db.InsertTemplateDeadlineRecalculation(context.Background(), database.InsertTemplateDeadlineRecalculationParams{
+			ID:         uuid.New(),
+			TemplateID: t1.ID,
+			CreatedAt:  database.Now(),
+		})
+		require.NoError(s.T(), err)
+		check.Args(t1.ID).Asserts(t1, rbac.ActionRead).Returns([]database.TemplateDeadlineRecalculation{r})
+	}))
+	s.Run("GetTemplatePrebuildPool", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		pool, err := db.UpsertTemplatePrebuildPool(context.Background(), database.UpsertTemplatePrebuildPoolParams{
+			TemplateID: t1.ID,
+			Size:       2,
+			UpdatedAt:  database.Now(),
+		})
+		require.NoError(s.T(), err)
+		check.Args(t1.ID).Asserts(t1, rbac.ActionRead).Returns(pool)
+	}))
+	s.Run("GetTemplateParameterValidation", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		validation, err := db.UpsertTemplateParameterValidation(context.Background(), database.UpsertTemplateParameterValidationParams{
+			TemplateID:  t1.ID,
+			WebhookURL:  "https://example.com",
+			Constraints: json.RawMessage("[]"),
+		})
+		require.NoError(s.T(), err)
+		check.Args(t1.ID).Asserts(t1, rbac.ActionRead).Returns(validation)
+	}))
+	s.Run("UpsertTemplateParameterValidation", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.UpsertTemplateParameterValidationParams{
+			TemplateID:  t1.ID,
+			Constraints: json.RawMessage("[]"),
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("UpsertTemplatePrebuildPool", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.UpsertTemplatePrebuildPoolParams{
+			TemplateID: t1.ID,
+			Size:       2,
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("ApproveTemplateVersionPromotion", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		p := dbgen.TemplateVersionPromotion(s.T(), db, database.TemplateVersionPromotion{TemplateID: t1.ID})
+		check.Args(database.ApproveTemplateVersionPromotionParams{
+			ID:     p.ID,
+			UserID: uuid.New(),
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("UpdateTemplateVersionPromotionStatusByID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		p := dbgen.TemplateVersionPromotion(s.T(), db, database.TemplateVersionPromotion{TemplateID: t1.ID})
+		check.Args(database.UpdateTemplateVersionPromotionStatusByIDParams{
+			ID:     p.ID,
+			Status: database.TemplateVersionPromotionStatusRejected,
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
 	s.Run("UpdateTemplateDeletedByID", s.Subtest(func(db database.Store, check *expects) {
 		t1 := dbgen.Template(s.T(), db, database.Template{})
 		check.Args(database.UpdateTemplateDeletedByIDParams{
@@ -800,6 +1192,18 @@ func (s *MethodTestSuite) TestTemplate() {
 			ID: t1.ID,
 		}).Asserts(t1, rbac.ActionUpdate)
 	}))
+	s.Run("UpdateTemplateSchedulePolicyByID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.UpdateTemplateSchedulePolicyByIDParams{
+			ID: t1.ID,
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("UpdateWorkspacesDeletingAtByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.UpdateWorkspacesDeletingAtByTemplateIDParams{
+			TemplateID: t1.ID,
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
 	s.Run("UpdateTemplateVersionByID", s.Subtest(func(db database.Store, check *expects) {
 		t1 := dbgen.Template(s.T(), db, database.Template{})
 		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
@@ -847,10 +1251,21 @@ func (s *MethodTestSuite) TestUser() {
 		u := dbgen.User(s.T(), db, database.User{})
 		check.Args(u.ID).Asserts(u, rbac.ActionRead).Returns(int64(0))
 	}))
+	s.Run("GetQuotaConsumedForUserByWorkspace", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(u.ID).Asserts(u, rbac.ActionRead).Returns([]database.GetQuotaConsumedForUserByWorkspaceRow{})
+	}))
 	s.Run("GetQuotaConsumedForUser", s.Subtest(func(db database.Store, check *expects) {
 		u := dbgen.User(s.T(), db, database.User{})
 		check.Args(u.ID).Asserts(u, rbac.ActionRead).Returns(int64(0))
 	}))
+	s.Run("GetQuotaResourceMetadataForUser", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.GetQuotaResourceMetadataForUserParams{
+			OwnerID:    u.ID,
+			TemplateID: uuid.New(),
+		}).Asserts(u, rbac.ActionRead).Returns([]database.WorkspaceResourceMetadatum{})
+	}))
 	s.Run("GetUserByEmailOrUsername", s.Subtest(func(db database.Store, check *expects) {
 		u := dbgen.User(s.T(), db, database.User{})
 		check.Args(database.GetUserByEmailOrUsernameParams{
@@ -889,6 +1304,171 @@ func (s *MethodTestSuite) TestUser() {
 			LoginType: database.LoginTypeOIDC,
 		}).Asserts(u, rbac.ActionUpdate)
 	}))
+	s.Run("GetUserQuietHoursExceptions", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		e := dbgen.UserQuietHoursException(s.T(), db, database.UserQuietHoursException{UserID: u.ID})
+		check.Args(u.ID).Asserts(u, rbac.ActionRead).Returns([]database.UserQuietHoursException{e})
+	}))
+	s.Run("InsertUserQuietHoursException", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.InsertUserQuietHoursExceptionParams{
+			UserID: u.ID,
+			Date:   time.Date(2023, 8, 25, 0, 0, 0, 0, time.UTC),
+		}).Asserts(u, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteUserQuietHoursException", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		e := dbgen.UserQuietHoursException(s.T(), db, database.UserQuietHoursException{UserID: u.ID})
+		check.Args(database.DeleteUserQuietHoursExceptionParams{
+			UserID: u.ID,
+			Date:   e.Date,
+		}).Asserts(u, rbac.ActionUpdate).Returns()
+	}))
+	s.Run("GetUserSecretsByUserID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		secret := dbgen.UserSecret(s.T(), db, database.UserSecret{UserID: u.ID})
+		check.Args(u.ID).Asserts(rbac.ResourceUserData.WithID(u.ID).WithOwner(u.ID.String()), rbac.ActionRead).Returns([]database.UserSecret{secret})
+	}))
+	s.Run("GetUserSecretByUserIDAndName", s.Subtest(func(db database.Store, check *expects) {
+		secret := dbgen.UserSecret(s.T(), db, database.UserSecret{})
+		check.Args(database.GetUserSecretByUserIDAndNameParams{
+			UserID: secret.UserID,
+			Name:   secret.Name,
+		}).Asserts(secret, rbac.ActionRead).Returns(secret)
+	}))
+	s.Run("InsertUserSecret", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.InsertUserSecretParams{
+			ID:     uuid.New(),
+			UserID: u.ID,
+			Name:   "API_TOKEN",
+		}).Asserts(rbac.ResourceUserData.WithID(u.ID).WithOwner(u.ID.String()), rbac.ActionCreate)
+	}))
+	s.Run("UpdateUserSecret", s.Subtest(func(db database.Store, check *expects) {
+		secret := dbgen.UserSecret(s.T(), db, database.UserSecret{})
+		secret.Description = "updated"
+		check.Args(database.UpdateUserSecretParams{
+			UserID:      secret.UserID,
+			Name:        secret.Name,
+			Description: secret.Description,
+			Value:       secret.Value,
+			UpdatedAt:   secret.UpdatedAt,
+		}).Asserts(secret, rbac.ActionUpdate).Returns(secret)
+	}))
+	s.Run("DeleteUserSecret", s.Subtest(func(db database.Store, check *expects) {
+		secret := dbgen.UserSecret(s.T(), db, database.UserSecret{})
+		check.Args(database.DeleteUserSecretParams{
+			UserID: secret.UserID,
+			Name:   secret.Name,
+		}).Asserts(secret, rbac.ActionDelete).Returns()
+	}))
+	s.Run("GetSavedWorkspaceFiltersByUserID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		filter := dbgen.SavedWorkspaceFilter(s.T(), db, database.SavedWorkspaceFilter{UserID: u.ID})
+		check.Args(u.ID).Asserts(rbac.ResourceUserData.WithID(u.ID).WithOwner(u.ID.String()), rbac.ActionRead).Returns([]database.SavedWorkspaceFilter{filter})
+	}))
+	s.Run("GetSavedWorkspaceFilterByUserIDAndName", s.Subtest(func(db database.Store, check *expects) {
+		filter := dbgen.SavedWorkspaceFilter(s.T(), db, database.SavedWorkspaceFilter{})
+		check.Args(database.GetSavedWorkspaceFilterByUserIDAndNameParams{
+			UserID: filter.UserID,
+			Name:   filter.Name,
+		}).Asserts(filter, rbac.ActionRead).Returns(filter)
+	}))
+	s.Run("InsertSavedWorkspaceFilter", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.InsertSavedWorkspaceFilterParams{
+			ID:     uuid.New(),
+			UserID: u.ID,
+			Name:   "running",
+			Query:  "status:running",
+		}).Asserts(rbac.ResourceUserData.WithID(u.ID).WithOwner(u.ID.String()), rbac.ActionCreate)
+	}))
+	s.Run("UpdateSavedWorkspaceFilter", s.Subtest(func(db database.Store, check *expects) {
+		filter := dbgen.SavedWorkspaceFilter(s.T(), db, database.SavedWorkspaceFilter{})
+		filter.Query = "status:stopped"
+		check.Args(database.UpdateSavedWorkspaceFilterParams{
+			UserID:    filter.UserID,
+			Name:      filter.Name,
+			Query:     filter.Query,
+			UpdatedAt: filter.UpdatedAt,
+		}).Asserts(filter, rbac.ActionUpdate).Returns(filter)
+	}))
+	s.Run("DeleteSavedWorkspaceFilter", s.Subtest(func(db database.Store, check *expects) {
+		filter := dbgen.SavedWorkspaceFilter(s.T(), db, database.SavedWorkspaceFilter{})
+		check.Args(database.DeleteSavedWorkspaceFilterParams{
+			UserID: filter.UserID,
+			Name:   filter.Name,
+		}).Asserts(filter, rbac.ActionDelete).Returns()
+	}))
+	s.Run("InsertWorkspaceBatch", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.InsertWorkspaceBatchParams{
+			ID:          uuid.New(),
+			InitiatorID: u.ID,
+			Operation:   "stop",
+			Query:       "owner:me",
+			CreatedAt:   database.Now(),
+		}).Asserts(rbac.ResourceUserData.WithID(u.ID).WithOwner(u.ID.String()), rbac.ActionCreate)
+	}))
+	s.Run("InsertWorkspaceBatchWorkspaces", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		batch, err := db.InsertWorkspaceBatch(context.Background(), database.InsertWorkspaceBatchParams{
+			ID:          uuid.New(),
+			InitiatorID: u.ID,
+			Operation:   "stop",
+			CreatedAt:   database.Now(),
+		})
+		require.NoError(s.T(), err)
+		w := dbgen.Workspace(s.T(), db, database.Workspace{OwnerID: u.ID})
+		check.Args(database.InsertWorkspaceBatchWorkspacesParams{
+			BatchID:      batch.ID,
+			UpdatedAt:    database.Now(),
+			WorkspaceIds: []uuid.UUID{w.ID},
+		}).Asserts(batch, rbac.ActionUpdate)
+	}))
+	s.Run("GetWorkspaceBatchByID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		batch, err := db.InsertWorkspaceBatch(context.Background(), database.InsertWorkspaceBatchParams{
+			ID:          uuid.New(),
+			InitiatorID: u.ID,
+			Operation:   "stop",
+			CreatedAt:   database.Now(),
+		})
+		require.NoError(s.T(), err)
+		check.Args(batch.ID).Asserts(batch, rbac.ActionRead).Returns(batch)
+	}))
+	s.Run("GetWorkspaceBatchWorkspacesByBatchID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		batch, err := db.InsertWorkspaceBatch(context.Background(), database.InsertWorkspaceBatchParams{
+			ID:          uuid.New(),
+			InitiatorID: u.ID,
+			Operation:   "stop",
+			CreatedAt:   database.Now(),
+		})
+		require.NoError(s.T(), err)
+		check.Args(batch.ID).Asserts(batch, rbac.ActionRead)
+	}))
+	s.Run("GetUserDotfiles", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		dotfiles, err := db.UpsertUserDotfiles(context.Background(), database.UpsertUserDotfilesParams{
+			UserID:    u.ID,
+			RepoURL:   "https://github.com/coder/dotfiles",
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(s.T(), err)
+		check.Args(u.ID).Asserts(dotfiles, rbac.ActionRead).Returns(dotfiles)
+	}))
+	s.Run("UpsertUserDotfiles", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.UpsertUserDotfilesParams{
+			UserID:  u.ID,
+			RepoURL: "https://github.com/coder/dotfiles",
+		}).Asserts(rbac.ResourceUserData.WithID(u.ID).WithOwner(u.ID.String()), rbac.ActionUpdate)
+	}))
+	s.Run("DeleteUserDotfiles", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(u.ID).Asserts(rbac.ResourceUserData.WithID(u.ID).WithOwner(u.ID.String()), rbac.ActionDelete).Returns()
+	}))
 	s.Run("SoftDeleteUserByID", s.Subtest(func(db database.Store, check *expects) {
 		u := dbgen.User(s.T(), db, database.User{})
 		check.Args(u.ID).Asserts(u, rbac.ActionDelete).Returns()
@@ -1013,6 +1593,11 @@ func (s *MethodTestSuite) TestWorkspace() {
 		// No asserts here because SQLFilter.
 		check.Args(database.GetWorkspacesParams{}).Asserts()
 	}))
+	s.Run("GetWorkspacesByIDs", s.Subtest(func(db database.Store, check *expects) {
+		a := dbgen.Workspace(s.T(), db, database.Workspace{})
+		b := dbgen.Workspace(s.T(), db, database.Workspace{})
+		check.Args([]uuid.UUID{a.ID, b.ID}).Asserts(a, rbac.ActionRead, b, rbac.ActionRead)
+	}))
 	s.Run("GetAuthorizedWorkspaces", s.Subtest(func(db database.Store, check *expects) {
 		_ = dbgen.Workspace(s.T(), db, database.Workspace{})
 		_ = dbgen.Workspace(s.T(), db, database.Workspace{})
@@ -1058,6 +1643,81 @@ func (s *MethodTestSuite) TestWorkspace() {
 			LogsOverflowed: true,
 		}).Asserts(ws, rbac.ActionUpdate).Returns()
 	}))
+	s.Run("GetWorkspaceAgentScriptsByAgentID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		err := db.InsertWorkspaceAgentScript(context.Background(), database.InsertWorkspaceAgentScriptParams{
+			WorkspaceAgentID: agt.ID,
+			Name:             "install",
+			Script:           "echo install",
+			RunOnStart:       true,
+		})
+		require.NoError(s.T(), err)
+		scripts, err := db.GetWorkspaceAgentScriptsByAgentID(context.Background(), agt.ID)
+		require.NoError(s.T(), err)
+		check.Args(agt.ID).Asserts(ws, rbac.ActionRead).Returns(scripts)
+	}))
+	s.Run("InsertWorkspaceAgentScript", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertWorkspaceAgentScriptParams{
+			WorkspaceAgentID: uuid.New(),
+			Name:             "install",
+		}).Asserts(rbac.ResourceSystem, rbac.ActionCreate).Returns()
+	}))
+	s.Run("GetWorkspaceAgentDevcontainersByAgentID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		err := db.InsertWorkspaceAgentDevcontainer(context.Background(), database.InsertWorkspaceAgentDevcontainerParams{
+			WorkspaceAgentID: agt.ID,
+			WorkspaceFolder:  "/home/coder/coder",
+		})
+		require.NoError(s.T(), err)
+		check.Args(agt.ID).Asserts(ws, rbac.ActionRead).Returns([]database.WorkspaceAgentDevcontainer{{
+			WorkspaceAgentID: agt.ID,
+			WorkspaceFolder:  "/home/coder/coder",
+		}})
+	}))
+	s.Run("InsertWorkspaceAgentDevcontainer", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertWorkspaceAgentDevcontainerParams{
+			WorkspaceAgentID: uuid.New(),
+			WorkspaceFolder:  "/home/coder/coder",
+		}).Asserts(rbac.ResourceSystem, rbac.ActionCreate).Returns()
+	}))
+	s.Run("GetWorkspaceAgentSecretsByAgentID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		err := db.InsertWorkspaceAgentSecret(context.Background(), database.InsertWorkspaceAgentSecretParams{
+			WorkspaceAgentID: agt.ID,
+			Name:             "API_TOKEN",
+		})
+		require.NoError(s.T(), err)
+		check.Args(agt.ID).Asserts(ws, rbac.ActionRead).Returns([]database.WorkspaceAgentSecret{{
+			WorkspaceAgentID: agt.ID,
+			Name:             "API_TOKEN",
+		}})
+	}))
+	s.Run("InsertWorkspaceAgentSecret", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertWorkspaceAgentSecretParams{
+			WorkspaceAgentID: uuid.New(),
+			Name:             "API_TOKEN",
+		}).Asserts(rbac.ResourceSystem, rbac.ActionCreate).Returns()
+	}))
+	s.Run("UpdateWorkspaceAgentScriptStatus", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		check.Args(database.UpdateWorkspaceAgentScriptStatusParams{
+			WorkspaceAgentID: agt.ID,
+			Name:             "install",
+			Status:           database.WorkspaceAgentScriptStatusRunning,
+		}).Asserts(ws, rbac.ActionUpdate).Returns()
+	}))
 	s.Run("UpdateWorkspaceAgentStartupByID", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
@@ -1070,6 +1730,29 @@ func (s *MethodTestSuite) TestWorkspace() {
 			},
 		}).Asserts(ws, rbac.ActionUpdate).Returns()
 	}))
+	s.Run("DeleteWorkspaceAgentPluginMetadata", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		check.Args(database.DeleteWorkspaceAgentPluginMetadataParams{
+			WorkspaceAgentID: agt.ID,
+			Keys:             []string{"load"},
+		}).Asserts(ws, rbac.ActionUpdate).Returns()
+	}))
+	s.Run("UpsertWorkspaceAgentPluginMetadata", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
+		res := dbgen.WorkspaceResource(s.T(), db, database.WorkspaceResource{JobID: build.JobID})
+		agt := dbgen.WorkspaceAgent(s.T(), db, database.WorkspaceAgent{ResourceID: res.ID})
+		check.Args(database.UpsertWorkspaceAgentPluginMetadataParams{
+			WorkspaceAgentID: agt.ID,
+			DisplayName:      "Load",
+			Key:              "load",
+			Script:           "cat /proc/loadavg",
+			Interval:         10,
+		}).Asserts(ws, rbac.ActionUpdate).Returns()
+	}))
 	s.Run("GetWorkspaceAgentLogsAfter", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID, JobID: uuid.New()})
@@ -1208,6 +1891,16 @@ func (s *MethodTestSuite) TestWorkspace() {
 			ID: w.ID,
 		}).Asserts(w, rbac.ActionUpdate).Returns(expected)
 	}))
+	s.Run("UpsertWorkspaceNameRedirect", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Workspace(s.T(), db, database.Workspace{})
+		check.Args(database.UpsertWorkspaceNameRedirectParams{
+			OwnerID:     w.OwnerID,
+			Name:        "old-name",
+			WorkspaceID: w.ID,
+			CreatedAt:   database.Now(),
+			ExpiresAt:   database.Now().Add(time.Hour),
+		}).Asserts(w, rbac.ActionUpdate)
+	}))
 	s.Run("InsertWorkspaceAgentStat", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		check.Args(database.InsertWorkspaceAgentStatParams{
@@ -1253,6 +1946,13 @@ func (s *MethodTestSuite) TestWorkspace() {
 			Deleted: true,
 		}).Asserts(ws, rbac.ActionDelete).Returns()
 	}))
+	s.Run("UpdateWorkspaceGroupACLByID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		check.Args(database.UpdateWorkspaceGroupACLByIDParams{
+			ID:       ws.ID,
+			GroupACL: database.WorkspaceACL{},
+		}).Asserts(ws, rbac.ActionUpdate).Returns()
+	}))
 	s.Run("UpdateWorkspaceLastUsedAt", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		check.Args(database.UpdateWorkspaceLastUsedAtParams{
@@ -1273,6 +1973,45 @@ func (s *MethodTestSuite) TestWorkspace() {
 		app := dbgen.WorkspaceApp(s.T(), db, database.WorkspaceApp{AgentID: agt.ID})
 		check.Args(app.ID).Asserts(ws, rbac.ActionRead).Returns(ws)
 	}))
+	s.Run("GetWorkspaceScheduleOverrideByWorkspaceID", s.Subtest(func(db database.Store, check *expects) {
+		tpl := dbgen.Template(s.T(), db, database.Template{})
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{TemplateID: tpl.ID})
+		o, err := db.UpsertWorkspaceScheduleOverride(context.Background(), database.UpsertWorkspaceScheduleOverrideParams{
+			WorkspaceID: ws.ID,
+		})
+		s.NoError(err, "upsert workspace schedule override")
+		check.Args(ws.ID).Asserts(ws, rbac.ActionRead).Returns(o)
+	}))
+	s.Run("UpsertWorkspaceScheduleOverride", s.Subtest(func(db database.Store, check *expects) {
+		tpl := dbgen.Template(s.T(), db, database.Template{})
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{TemplateID: tpl.ID})
+		check.Args(database.UpsertWorkspaceScheduleOverrideParams{
+			WorkspaceID: ws.ID,
+		}).Asserts(tpl, rbac.ActionUpdate)
+	}))
+	s.Run("InsertWorkspaceDeadlineExtension", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		build := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID})
+		check.Args(database.InsertWorkspaceDeadlineExtensionParams{
+			ID:               uuid.New(),
+			WorkspaceID:      ws.ID,
+			WorkspaceBuildID: build.ID,
+			UserID:           ws.OwnerID,
+		}).Asserts(ws, rbac.ActionUpdate)
+	}))
+	s.Run("GetWorkspaceDeadlineExtensionCount", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		_ = dbgen.WorkspaceDeadlineExtension(s.T(), db, database.WorkspaceDeadlineExtension{WorkspaceID: ws.ID})
+		check.Args(database.GetWorkspaceDeadlineExtensionCountParams{
+			WorkspaceID: ws.ID,
+			CreatedAt:   database.Now().Add(-time.Hour),
+		}).Asserts(ws, rbac.ActionRead).Returns(int64(1))
+	}))
+	s.Run("DeleteWorkspaceScheduleOverrideByWorkspaceID", s.Subtest(func(db database.Store, check *expects) {
+		tpl := dbgen.Template(s.T(), db, database.Template{})
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{TemplateID: tpl.ID})
+		check.Args(ws.ID).Asserts(tpl, rbac.ActionUpdate).Returns()
+	}))
 }
 
 func (s *MethodTestSuite) TestExtraMethods() {
@@ -1303,6 +2042,35 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("UpsertDefaultProxy", s.Subtest(func(db database.Store, check *expects) {
 		check.Args(database.UpsertDefaultProxyParams{}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate).Returns()
 	}))
+	s.Run("GetACMECertificate", s.Subtest(func(db database.Store, check *expects) {
+		params := database.UpsertACMECertificateParams{
+			WorkspaceProxyID: uuid.New(),
+			Domain:           "*.apps.coder.com",
+			Data:             []byte("data"),
+			NotAfter:         database.Now().Add(time.Hour),
+			UpdatedAt:        database.Now(),
+		}
+		err := db.UpsertACMECertificate(context.Background(), params)
+		require.NoError(s.T(), err)
+		check.Args(database.GetACMECertificateParams{
+			WorkspaceProxyID: params.WorkspaceProxyID,
+			Domain:           params.Domain,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(database.ACMECertificate{
+			Domain:           params.Domain,
+			Data:             params.Data,
+			NotAfter:         params.NotAfter,
+			UpdatedAt:        params.UpdatedAt,
+			WorkspaceProxyID: params.WorkspaceProxyID,
+		})
+	}))
+	s.Run("UpsertACMECertificate", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpsertACMECertificateParams{
+			Domain:    "*.apps.coder.com",
+			Data:      []byte("data"),
+			NotAfter:  database.Now().Add(time.Hour),
+			UpdatedAt: database.Now(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate).Returns()
+	}))
 	s.Run("GetUserLinkByLinkedID", s.Subtest(func(db database.Store, check *expects) {
 		l := dbgen.UserLink(s.T(), db, database.UserLink{})
 		check.Args(l.LinkedID).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(l)
@@ -1381,6 +2149,16 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			DailyCost: 10,
 		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
 	}))
+	s.Run("GetOrganizationBudgetUsages", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns([]database.GetOrganizationBudgetUsagesRow{})
+	}))
+	s.Run("UpdateOrganizationBudgetAlertedThreshold", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		check.Args(database.UpdateOrganizationBudgetAlertedThresholdParams{
+			OrganizationID:          o.ID,
+			AlertedThresholdPercent: 80,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
 	s.Run("UpsertLastUpdateCheck", s.Subtest(func(db database.Store, check *expects) {
 		check.Args("value").Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
 	}))
@@ -1389,6 +2167,12 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 		require.NoError(s.T(), err)
 		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionRead)
 	}))
+	s.Run("GetWorkspacesWithImminentAutostop", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.GetWorkspacesWithImminentAutostopParams{
+			Now:    time.Now(),
+			Before: time.Now().Add(time.Hour),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionRead)
+	}))
 	s.Run("GetWorkspaceBuildsCreatedAfter", s.Subtest(func(db database.Store, check *expects) {
 		_ = dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{CreatedAt: time.Now().Add(-time.Hour)})
 		check.Args(time.Now()).Asserts(rbac.ResourceSystem, rbac.ActionRead)
@@ -1412,6 +2196,89 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("DeleteOldWorkspaceAgentStats", s.Subtest(func(db database.Store, check *expects) {
 		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionDelete)
 	}))
+	s.Run("DeleteOldWorkspaceAppAccessLogs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("DeleteOldTemplateDeadlineRecalculations", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.Now()).Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("AcquireTemplateDeadlineRecalculation", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		_, err := db.InsertTemplateDeadlineRecalculation(context.Background(), database.InsertTemplateDeadlineRecalculationParams{
+			ID:         uuid.New(),
+			TemplateID: t1.ID,
+			CreatedAt:  database.Now(),
+		})
+		require.NoError(s.T(), err)
+		check.Args(database.AcquireTemplateDeadlineRecalculationParams{
+			Now:         database.Now(),
+			StaleBefore: database.Now(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("UpdateTemplateDeadlineRecalculationProgressByID", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpdateTemplateDeadlineRecalculationProgressByIDParams{
+			ID: uuid.New(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("UpdateTemplateDeadlineRecalculationCompletedByID", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpdateTemplateDeadlineRecalculationCompletedByIDParams{
+			ID:     uuid.New(),
+			Status: database.TemplateDeadlineRecalculationStatusSucceeded,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("AcquireWorkspaceBatch", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		_, err := db.InsertWorkspaceBatch(context.Background(), database.InsertWorkspaceBatchParams{
+			ID:          uuid.New(),
+			InitiatorID: u.ID,
+			Operation:   "stop",
+			CreatedAt:   database.Now(),
+		})
+		require.NoError(s.T(), err)
+		check.Args(database.AcquireWorkspaceBatchParams{
+			Now:         database.Now(),
+			StaleBefore: database.Now(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("UpdateWorkspaceBatchWorkspace", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpdateWorkspaceBatchWorkspaceParams{
+			BatchID:     uuid.New(),
+			WorkspaceID: uuid.New(),
+			Status:      database.WorkspaceBatchWorkspaceStatusQueued,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("UpdateWorkspaceBatchUpdatedAtByID", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpdateWorkspaceBatchUpdatedAtByIDParams{
+			ID: uuid.New(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("UpdateWorkspaceBatchCompletedByID", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpdateWorkspaceBatchCompletedByIDParams{
+			ID:     uuid.New(),
+			Status: database.WorkspaceBatchStatusCompleted,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteOldWorkspaceBatches", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.Now()).Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("DeleteExpiredWorkspaceNameRedirects", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionDelete)
+	}))
+	s.Run("GetWorkspaceNameRedirect", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Workspace(s.T(), db, database.Workspace{})
+		redirect, err := db.UpsertWorkspaceNameRedirect(context.Background(), database.UpsertWorkspaceNameRedirectParams{
+			OwnerID:     w.OwnerID,
+			Name:        "old-name",
+			WorkspaceID: w.ID,
+			CreatedAt:   database.Now(),
+			ExpiresAt:   database.Now().Add(time.Hour),
+		})
+		require.NoError(s.T(), err)
+		check.Args(database.GetWorkspaceNameRedirectParams{
+			OwnerID: w.OwnerID,
+			Name:    "old-name",
+		}).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(redirect)
+	}))
 	s.Run("GetProvisionerJobsCreatedAfter", s.Subtest(func(db database.Store, check *expects) {
 		// TODO: add provisioner job resource type
 		_ = dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{CreatedAt: time.Now().Add(-time.Hour)})
@@ -1480,6 +2347,39 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			Asserts(rbac.ResourceSystem, rbac.ActionRead).
 			Returns([]database.WorkspaceAgent{agt})
 	}))
+	s.Run("GetTemplatePrebuildPools", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts(rbac.ResourceSystem, rbac.ActionRead)
+	}))
+	s.Run("GetPrebuiltWorkspaces", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(uuid.New()).Asserts(rbac.ResourceSystem, rbac.ActionRead)
+	}))
+	s.Run("ClaimPrebuiltWorkspace", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		tpl := dbgen.Template(s.T(), db, database.Template{})
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{OwnerID: u.ID, TemplateID: tpl.ID})
+		job := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{
+			CompletedAt: sql.NullTime{Time: database.Now(), Valid: true},
+		})
+		_ = dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{
+			WorkspaceID:       ws.ID,
+			JobID:             job.ID,
+			TemplateVersionID: tpl.ActiveVersionID,
+			Transition:        database.WorkspaceTransitionStop,
+		})
+		check.Args(database.ClaimPrebuiltWorkspaceParams{
+			NewOwnerID:       uuid.New(),
+			Name:             "claimed",
+			Now:              database.Now(),
+			PrebuildsOwnerID: u.ID,
+			TemplateID:       tpl.ID,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("GetProvisionerJobConcurrency", s.Subtest(func(db database.Store, check *expects) {
+		j := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{})
+		check.Args(j.ID).Asserts(rbac.ResourceSystem, rbac.ActionRead).Returns(database.GetProvisionerJobConcurrencyRow{
+			OrganizationID: j.OrganizationID,
+		})
+	}))
 	s.Run("GetProvisionerJobsByIDs", s.Subtest(func(db database.Store, check *expects) {
 		// TODO: add a ProvisionerJob resource type
 		a := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{})
@@ -1520,7 +2420,7 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 		j := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{
 			StartedAt: sql.NullTime{Valid: false},
 		})
-		check.Args(database.AcquireProvisionerJobParams{Types: []database.ProvisionerType{j.Provisioner}, Tags: must(json.Marshal(j.Tags))}).
+		check.Args(database.AcquireProvisionerJobParams{Types: []string{string(j.Provisioner)}, Tags: must(json.Marshal(j.Tags))}).
 			Asserts( /*rbac.ResourceSystem, rbac.ActionUpdate*/ )
 	}))
 	s.Run("UpdateProvisionerJobWithCompleteByID", s.Subtest(func(db database.Store, check *expects) {
@@ -1530,6 +2430,17 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			ID: j.ID,
 		}).Asserts( /*rbac.ResourceSystem, rbac.ActionUpdate*/ )
 	}))
+	s.Run("UpdateProvisionerJobNotBeforeByID", s.Subtest(func(db database.Store, check *expects) {
+		j := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{})
+		check.Args(database.UpdateProvisionerJobNotBeforeByIDParams{
+			ID: j.ID,
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
+	s.Run("UpdateProvisionerDaemonCacheStats", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.UpdateProvisionerDaemonCacheStatsParams{
+			ID: uuid.New(),
+		}).Asserts(rbac.ResourceSystem, rbac.ActionUpdate)
+	}))
 	s.Run("UpdateProvisionerJobByID", s.Subtest(func(db database.Store, check *expects) {
 		// TODO: we need to create a ProvisionerJob resource
 		j := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{})
@@ -1,6 +1,7 @@
 package dbfake
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -114,35 +115,63 @@ type data struct {
 	userLinks           []database.UserLink
 
 	// New tables
-	workspaceAgentStats           []database.WorkspaceAgentStat
-	auditLogs                     []database.AuditLog
-	files                         []database.File
-	gitAuthLinks                  []database.GitAuthLink
-	gitSSHKey                     []database.GitSSHKey
-	groupMembers                  []database.GroupMember
-	groups                        []database.Group
-	licenses                      []database.License
-	parameterSchemas              []database.ParameterSchema
-	provisionerDaemons            []database.ProvisionerDaemon
-	provisionerJobLogs            []database.ProvisionerJobLog
-	provisionerJobs               []database.ProvisionerJob
-	replicas                      []database.Replica
-	templateVersions              []database.TemplateVersionTable
-	templateVersionParameters     []database.TemplateVersionParameter
-	templateVersionVariables      []database.TemplateVersionVariable
-	templates                     []database.TemplateTable
-	workspaceAgents               []database.WorkspaceAgent
-	workspaceAgentMetadata        []database.WorkspaceAgentMetadatum
-	workspaceAgentLogs            []database.WorkspaceAgentLog
-	workspaceApps                 []database.WorkspaceApp
-	workspaceAppStatsLastInsertID int64
-	workspaceAppStats             []database.WorkspaceAppStat
-	workspaceBuilds               []database.WorkspaceBuildTable
-	workspaceBuildParameters      []database.WorkspaceBuildParameter
-	workspaceResourceMetadata     []database.WorkspaceResourceMetadatum
-	workspaceResources            []database.WorkspaceResource
-	workspaces                    []database.Workspace
-	workspaceProxies              []database.WorkspaceProxy
+	acmeCertificates                    []database.ACMECertificate
+	workspaceAgentStats                 []database.WorkspaceAgentStat
+	auditLogs                           []database.AuditLog
+	auditLogExportCursors               []database.AuditLogExportCursor
+	customRoles                         []database.CustomRole
+	files                               []database.File
+	gitAuthLinks                        []database.GitAuthLink
+	gitSSHKey                           []database.GitSSHKey
+	groupMembers                        []database.GroupMember
+	groups                              []database.Group
+	licenses                            []database.License
+	parameterSchemas                    []database.ParameterSchema
+	provisionerDaemons                  []database.ProvisionerDaemon
+	provisionerJobLogs                  []database.ProvisionerJobLog
+	provisionerJobs                     []database.ProvisionerJob
+	provisionerKeys                     []database.ProvisionerKey
+	replicas                            []database.Replica
+	organizationBudgetSettings          []database.OrganizationBudgetSettings
+	organizationJITProvisioningSettings []database.OrganizationJITProvisioningSettings
+	organizationProvisionerSettings     []database.OrganizationProvisionerSettings
+	organizationScheduleSettings        []database.OrganizationScheduleSettings
+	organizationTemplateVariables       []database.OrganizationTemplateVariable
+	savedWorkspaceFilters               []database.SavedWorkspaceFilter
+	scheduleHolidays                    []database.ScheduleHoliday
+	templateDeadlineRecalculations      []database.TemplateDeadlineRecalculation
+	templateVersions                    []database.TemplateVersionTable
+	templateParameterValidations        []database.TemplateParameterValidation
+	templatePrebuildPools               []database.TemplatePrebuildPool
+	templateVersionParameters           []database.TemplateVersionParameter
+	templateVersionPresets              []database.TemplateVersionPreset
+	templateVersionPromotions           []database.TemplateVersionPromotion
+	templateVersionVariables            []database.TemplateVersionVariable
+	templates                           []database.TemplateTable
+	userDotfiles                        []database.UserDotfile
+	userQuietHoursExceptions            []database.UserQuietHoursException
+	userSecrets                         []database.UserSecret
+	workspaceAgents                     []database.WorkspaceAgent
+	workspaceAgentMetadata              []database.WorkspaceAgentMetadatum
+	workspaceAgentLogs                  []database.WorkspaceAgentLog
+	workspaceAgentScripts               []database.WorkspaceAgentScript
+	workspaceAgentSecrets               []database.WorkspaceAgentSecret
+	workspaceAgentDevcontainers         []database.WorkspaceAgentDevcontainer
+	workspaceApps                       []database.WorkspaceApp
+	workspaceAppAccessLogs              []database.WorkspaceAppAccessLog
+	workspaceAppStatsLastInsertID       int64
+	workspaceAppStats                   []database.WorkspaceAppStat
+	workspaceBatches                    []database.WorkspaceBatch
+	workspaceBatchWorkspaces            []database.WorkspaceBatchWorkspace
+	workspaceBuilds                     []database.WorkspaceBuildTable
+	workspaceBuildParameters            []database.WorkspaceBuildParameter
+	workspaceDeadlineExtensions         []database.WorkspaceDeadlineExtension
+	workspaceNameRedirects              []database.WorkspaceNameRedirect
+	workspaceResourceMetadata           []database.WorkspaceResourceMetadatum
+	workspaceResources                  []database.WorkspaceResource
+	workspaceScheduleOverrides          []database.WorkspaceScheduleOverride
+	workspaces                          []database.Workspace
+	workspaceProxies                    []database.WorkspaceProxy
 	// Locks is a map of lock names. Any keys within the map are currently
 	// locked.
 	locks                   map[int64]struct{}
@@ -343,6 +372,8 @@ func (q *FakeQuerier) convertToWorkspaceRowsNoLock(ctx context.Context, workspac
 			LastUsedAt:        w.LastUsedAt,
 			LockedAt:          w.LockedAt,
 			DeletingAt:        w.DeletingAt,
+			GroupACL:          w.GroupACL,
+			LockReason:        w.LockReason,
 			Count:             count,
 		}
 
@@ -580,6 +611,63 @@ func (q *FakeQuerier) getGroupByIDNoLock(_ context.Context, id uuid.UUID) (datab
 	return database.Group{}, sql.ErrNoRows
 }
 
+// provisionerJobAtConcurrencyLimitNoLock returns true if the organization of
+// the job, or the template of the workspace built by the job, is running its
+// maximum number of concurrent jobs.
+func (q *FakeQuerier) provisionerJobAtConcurrencyLimitNoLock(job database.ProvisionerJob) bool {
+	running := func(job database.ProvisionerJob) bool {
+		return job.StartedAt.Valid && !job.CompletedAt.Valid
+	}
+	templateID := func(jobID uuid.UUID) uuid.UUID {
+		for _, build := range q.workspaceBuilds {
+			if build.JobID != jobID {
+				continue
+			}
+			for _, workspace := range q.workspaces {
+				if workspace.ID == build.WorkspaceID {
+					return workspace.TemplateID
+				}
+			}
+		}
+		return uuid.Nil
+	}
+
+	for _, settings := range q.organizationProvisionerSettings {
+		if settings.OrganizationID != job.OrganizationID || settings.MaxConcurrentJobs <= 0 {
+			continue
+		}
+		var count int32
+		for _, other := range q.provisionerJobs {
+			if other.OrganizationID == job.OrganizationID && running(other) {
+				count++
+			}
+		}
+		if count >= settings.MaxConcurrentJobs {
+			return true
+		}
+	}
+
+	jobTemplateID := templateID(job.ID)
+	if jobTemplateID == uuid.Nil {
+		return false
+	}
+	for _, template := range q.templates {
+		if template.ID != jobTemplateID || template.MaxConcurrentBuilds <= 0 {
+			continue
+		}
+		var count int32
+		for _, other := range q.provisionerJobs {
+			if running(other) && templateID(other.ID) == jobTemplateID {
+				count++
+			}
+		}
+		if count >= template.MaxConcurrentBuilds {
+			return true
+		}
+	}
+	return false
+}
+
 // isNull is only used in dbfake, so reflect is ok. Use this to make the logic
 // look more similar to the postgres.
 func isNull(v interface{}) bool {
@@ -613,6 +701,30 @@ func uniqueSortedUUIDs(uuids []uuid.UUID) []uuid.UUID {
 	return unique
 }
 
+// truncateToDate mimics storing a time in a date column, which drops the
+// time of day.
+func truncateToDate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// percentileCont mimics PERCENTILE_CONT in Postgres, which interpolates
+// linearly between the two values closest to the percentile p (0-100). It
+// returns -1 if there are no values, like the queries that coalesce it. The
+// values are sorted in place.
+func percentileCont(fs []float64, p float64) float64 {
+	if len(fs) == 0 {
+		return -1
+	}
+	sort.Float64s(fs)
+	pos := float64(len(fs)-1) * p / 100
+	lower := int(pos)
+	if lower >= len(fs)-1 {
+		return fs[len(fs)-1]
+	}
+	return fs[lower] + (fs[lower+1]-fs[lower])*(pos-float64(lower))
+}
+
 func (*FakeQuerier) AcquireLock(_ context.Context, _ int64) error {
 	return xerrors.New("AcquireLock must only be called within a transaction")
 }
@@ -625,13 +737,25 @@ func (q *FakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
+	// Jobs are stored in the order they were created, so the first job with
+	// the highest priority is acquired.
+	acquire := -1
 	for index, provisionerJob := range q.provisionerJobs {
 		if provisionerJob.StartedAt.Valid {
 			continue
 		}
+		if provisionerJob.NotBefore.Valid && provisionerJob.NotBefore.Time.After(arg.StartedAt.Time) {
+			continue
+		}
+		if arg.OrganizationID != uuid.Nil && provisionerJob.OrganizationID != arg.OrganizationID {
+			continue
+		}
+		if acquire >= 0 && provisionerJob.Priority <= q.provisionerJobs[acquire].Priority {
+			continue
+		}
 		found := false
 		for _, provisionerType := range arg.Types {
-			if provisionerJob.Provisioner != provisionerType {
+			if string(provisionerJob.Provisioner) != provisionerType {
 				continue
 			}
 			found = true
@@ -663,19 +787,152 @@ func (q *FakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 		if missing {
 			continue
 		}
-		provisionerJob.StartedAt = arg.StartedAt
-		provisionerJob.UpdatedAt = arg.StartedAt.Time
-		provisionerJob.WorkerID = arg.WorkerID
-		q.provisionerJobs[index] = provisionerJob
-		return provisionerJob, nil
+		if q.provisionerJobAtConcurrencyLimitNoLock(provisionerJob) {
+			continue
+		}
+		acquire = index
 	}
-	return database.ProvisionerJob{}, sql.ErrNoRows
+	if acquire < 0 {
+		return database.ProvisionerJob{}, sql.ErrNoRows
+	}
+
+	provisionerJob := q.provisionerJobs[acquire]
+	provisionerJob.StartedAt = arg.StartedAt
+	provisionerJob.UpdatedAt = arg.StartedAt.Time
+	provisionerJob.WorkerID = arg.WorkerID
+	q.provisionerJobs[acquire] = provisionerJob
+	return provisionerJob, nil
 }
 
 func (*FakeQuerier) CleanTailnetCoordinators(_ context.Context) error {
 	return ErrUnimplemented
 }
 
+func (q *FakeQuerier) AcquireTemplateDeadlineRecalculation(_ context.Context, arg database.AcquireTemplateDeadlineRecalculationParams) (database.TemplateDeadlineRecalculation, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateDeadlineRecalculation{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	acquire := -1
+	for i, recalculation := range q.templateDeadlineRecalculations {
+		stale := recalculation.Status == database.TemplateDeadlineRecalculationStatusRunning && recalculation.UpdatedAt.Before(arg.StaleBefore)
+		if recalculation.Status != database.TemplateDeadlineRecalculationStatusPending && !stale {
+			continue
+		}
+		if acquire >= 0 && !recalculation.CreatedAt.Before(q.templateDeadlineRecalculations[acquire].CreatedAt) {
+			continue
+		}
+		acquire = i
+	}
+	if acquire < 0 {
+		return database.TemplateDeadlineRecalculation{}, sql.ErrNoRows
+	}
+	q.templateDeadlineRecalculations[acquire].Status = database.TemplateDeadlineRecalculationStatusRunning
+	q.templateDeadlineRecalculations[acquire].UpdatedAt = arg.Now
+	return q.templateDeadlineRecalculations[acquire], nil
+}
+
+func (q *FakeQuerier) AcquireWorkspaceBatch(_ context.Context, arg database.AcquireWorkspaceBatchParams) (database.WorkspaceBatch, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceBatch{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	acquire := -1
+	for i, batch := range q.workspaceBatches {
+		stale := batch.Status == database.WorkspaceBatchStatusRunning && batch.UpdatedAt.Before(arg.StaleBefore)
+		if batch.Status != database.WorkspaceBatchStatusPending && !stale {
+			continue
+		}
+		if acquire >= 0 && !batch.CreatedAt.Before(q.workspaceBatches[acquire].CreatedAt) {
+			continue
+		}
+		acquire = i
+	}
+	if acquire < 0 {
+		return database.WorkspaceBatch{}, sql.ErrNoRows
+	}
+	q.workspaceBatches[acquire].Status = database.WorkspaceBatchStatusRunning
+	q.workspaceBatches[acquire].UpdatedAt = arg.Now
+	return q.workspaceBatches[acquire], nil
+}
+
+func (q *FakeQuerier) ApproveTemplateVersionPromotion(_ context.Context, arg database.ApproveTemplateVersionPromotionParams) (database.TemplateVersionPromotion, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, promotion := range q.templateVersionPromotions {
+		if promotion.ID != arg.ID {
+			continue
+		}
+		if promotion.Status != database.TemplateVersionPromotionStatusPending || slices.Contains(promotion.ApprovedBy, arg.UserID) {
+			return database.TemplateVersionPromotion{}, sql.ErrNoRows
+		}
+		promotion.UpdatedAt = arg.UpdatedAt
+		promotion.ApprovedBy = append(slices.Clone(promotion.ApprovedBy), arg.UserID)
+		q.templateVersionPromotions[i] = promotion
+		return promotion, nil
+	}
+	return database.TemplateVersionPromotion{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) ClaimPrebuiltWorkspace(ctx context.Context, arg database.ClaimPrebuiltWorkspaceParams) (database.Workspace, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.Workspace{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	template, err := q.getTemplateByIDNoLock(ctx, arg.TemplateID)
+	if err != nil {
+		return database.Workspace{}, err
+	}
+	// Workspaces are stored in the order they were created in, so the oldest
+	// ready prebuilt workspace is claimed.
+	for i, workspace := range q.workspaces {
+		if workspace.OwnerID != arg.PrebuildsOwnerID || workspace.TemplateID != arg.TemplateID || workspace.Deleted {
+			continue
+		}
+		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return database.Workspace{}, err
+		}
+		job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
+		if err != nil {
+			return database.Workspace{}, err
+		}
+		if build.TemplateVersionID != template.ActiveVersionID ||
+			build.Transition != database.WorkspaceTransitionStop ||
+			!job.CompletedAt.Valid || job.CanceledAt.Valid || job.Error.Valid {
+			continue
+		}
+
+		workspace.OwnerID = arg.NewOwnerID
+		workspace.Name = arg.Name
+		workspace.AutostartSchedule = arg.AutostartSchedule
+		workspace.Ttl = arg.Ttl
+		workspace.CreatedAt = arg.Now
+		workspace.UpdatedAt = arg.Now
+		workspace.LastUsedAt = arg.Now
+		q.workspaces[i] = workspace
+		return workspace, nil
+	}
+	return database.Workspace{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) DeleteAPIKeyByID(_ context.Context, id string) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -717,10 +974,59 @@ func (q *FakeQuerier) DeleteApplicationConnectAPIKeysByUserID(_ context.Context,
 	return nil
 }
 
+func (q *FakeQuerier) DeleteAuditLogsByIDs(_ context.Context, ids []uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i := len(q.auditLogs) - 1; i >= 0; i-- {
+		if slices.Contains(ids, q.auditLogs[i].ID) {
+			q.auditLogs = append(q.auditLogs[:i], q.auditLogs[i+1:]...)
+		}
+	}
+
+	return nil
+}
+
 func (*FakeQuerier) DeleteCoordinator(context.Context, uuid.UUID) error {
 	return ErrUnimplemented
 }
 
+func (q *FakeQuerier) DeleteCustomRole(_ context.Context, name string) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, user := range q.users {
+		if slices.Contains(user.RBACRoles, name) {
+			user.RBACRoles = slices.DeleteFunc(slices.Clone(user.RBACRoles), func(role string) bool {
+				return role == name
+			})
+			q.users[i] = user
+		}
+	}
+	for i, role := range q.customRoles {
+		if role.Name == name {
+			q.customRoles = append(q.customRoles[:i], q.customRoles[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *FakeQuerier) DeleteExpiredWorkspaceNameRedirects(_ context.Context) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	now := database.Now()
+	redirects := q.workspaceNameRedirects[:0]
+	for _, redirect := range q.workspaceNameRedirects {
+		if redirect.ExpiresAt.After(now) {
+			redirects = append(redirects, redirect)
+		}
+	}
+	q.workspaceNameRedirects = redirects
+	return nil
+}
+
 func (q *FakeQuerier) DeleteGitSSHKey(_ context.Context, userID uuid.UUID) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -778,8 +1084,9 @@ func (q *FakeQuerier) DeleteGroupMembersByOrgAndUser(_ context.Context, arg data
 				if group.ID == member.GroupID {
 					// Only add back the member if the organization ID does not match
 					// the arg organization ID. Since the arg is saying which
-					// org to delete.
-					if group.OrganizationID != arg.OrganizationID {
+					// org to delete. Members of groups pushed by SCIM are
+					// kept too.
+					if group.OrganizationID != arg.OrganizationID || group.Source == database.GroupSourceScim {
 						newMembers = append(newMembers, member)
 					}
 					break
@@ -816,6 +1123,93 @@ func (*FakeQuerier) DeleteOldWorkspaceAgentStats(_ context.Context) error {
 	return nil
 }
 
+func (q *FakeQuerier) DeleteOldTemplateDeadlineRecalculations(_ context.Context, completedBefore time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	recalculations := q.templateDeadlineRecalculations[:0]
+	for _, recalculation := range q.templateDeadlineRecalculations {
+		if recalculation.CompletedAt.Valid && recalculation.CompletedAt.Time.Before(completedBefore) {
+			continue
+		}
+		recalculations = append(recalculations, recalculation)
+	}
+	q.templateDeadlineRecalculations = recalculations
+	return nil
+}
+
+func (q *FakeQuerier) DeleteOldWorkspaceAppAccessLogs(_ context.Context) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	before := database.Now().Add(-30 * 24 * time.Hour)
+	logs := q.workspaceAppAccessLogs[:0]
+	for _, l := range q.workspaceAppAccessLogs {
+		if l.CreatedAt.Before(before) {
+			continue
+		}
+		logs = append(logs, l)
+	}
+	q.workspaceAppAccessLogs = logs
+	return nil
+}
+
+func (q *FakeQuerier) DeleteOldWorkspaceBatches(_ context.Context, completedBefore time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	deleted := map[uuid.UUID]struct{}{}
+	batches := q.workspaceBatches[:0]
+	for _, batch := range q.workspaceBatches {
+		if batch.CompletedAt.Valid && batch.CompletedAt.Time.Before(completedBefore) {
+			deleted[batch.ID] = struct{}{}
+			continue
+		}
+		batches = append(batches, batch)
+	}
+	q.workspaceBatches = batches
+
+	workspaces := q.workspaceBatchWorkspaces[:0]
+	for _, workspace := range q.workspaceBatchWorkspaces {
+		if _, ok := deleted[workspace.BatchID]; ok {
+			continue
+		}
+		workspaces = append(workspaces, workspace)
+	}
+	q.workspaceBatchWorkspaces = workspaces
+	return nil
+}
+
+func (q *FakeQuerier) DeleteOrganizationTemplateVariable(_ context.Context, arg database.DeleteOrganizationTemplateVariableParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, variable := range q.organizationTemplateVariables {
+		if variable.OrganizationID == arg.OrganizationID && variable.Name == arg.Name {
+			q.organizationTemplateVariables = append(q.organizationTemplateVariables[:i], q.organizationTemplateVariables[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *FakeQuerier) DeleteProvisionerKey(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, key := range q.provisionerKeys {
+		if key.ID == id {
+			q.provisionerKeys = append(q.provisionerKeys[:i], q.provisionerKeys[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
 func (q *FakeQuerier) DeleteReplicasUpdatedBefore(_ context.Context, before time.Time) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -829,6 +1223,37 @@ func (q *FakeQuerier) DeleteReplicasUpdatedBefore(_ context.Context, before time
 	return nil
 }
 
+func (q *FakeQuerier) DeleteSavedWorkspaceFilter(_ context.Context, arg database.DeleteSavedWorkspaceFilterParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, filter := range q.savedWorkspaceFilters {
+		if filter.UserID == arg.UserID && filter.Name == arg.Name {
+			q.savedWorkspaceFilters = append(q.savedWorkspaceFilters[:i], q.savedWorkspaceFilters[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *FakeQuerier) DeleteScheduleHolidayByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, holiday := range q.scheduleHolidays {
+		if holiday.ID == id {
+			q.scheduleHolidays = append(q.scheduleHolidays[:i], q.scheduleHolidays[i+1:]...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
 func (*FakeQuerier) DeleteTailnetAgent(context.Context, database.DeleteTailnetAgentParams) (database.DeleteTailnetAgentRow, error) {
 	return database.DeleteTailnetAgentRow{}, ErrUnimplemented
 }
@@ -837,6 +1262,104 @@ func (*FakeQuerier) DeleteTailnetClient(context.Context, database.DeleteTailnetC
 	return database.DeleteTailnetClientRow{}, ErrUnimplemented
 }
 
+func (q *FakeQuerier) DeleteUserDotfiles(_ context.Context, userID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, dotfiles := range q.userDotfiles {
+		if dotfiles.UserID == userID {
+			q.userDotfiles = append(q.userDotfiles[:i], q.userDotfiles[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *FakeQuerier) DeleteUserQuietHoursException(_ context.Context, arg database.DeleteUserQuietHoursExceptionParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	date := truncateToDate(arg.Date)
+	for i, exception := range q.userQuietHoursExceptions {
+		if exception.UserID == arg.UserID && exception.Date.Equal(date) {
+			q.userQuietHoursExceptions = append(q.userQuietHoursExceptions[:i], q.userQuietHoursExceptions[i+1:]...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (q *FakeQuerier) DeleteUserSecret(_ context.Context, arg database.DeleteUserSecretParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, secret := range q.userSecrets {
+		if secret.UserID == arg.UserID && secret.Name == arg.Name {
+			q.userSecrets = append(q.userSecrets[:i], q.userSecrets[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (q *FakeQuerier) DeleteWorkspaceAgentPluginMetadata(_ context.Context, arg database.DeleteWorkspaceAgentPluginMetadataParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	metadata := make([]database.WorkspaceAgentMetadatum, 0, len(q.workspaceAgentMetadata))
+	for _, m := range q.workspaceAgentMetadata {
+		if m.WorkspaceAgentID == arg.WorkspaceAgentID && m.Plugin && !slices.Contains(arg.Keys, m.Key) {
+			continue
+		}
+		metadata = append(metadata, m)
+	}
+	q.workspaceAgentMetadata = metadata
+	return nil
+}
+
+func (q *FakeQuerier) DeleteWorkspaceScheduleOverrideByWorkspaceID(_ context.Context, workspaceID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, override := range q.workspaceScheduleOverrides {
+		if override.WorkspaceID == workspaceID {
+			q.workspaceScheduleOverrides = append(q.workspaceScheduleOverrides[:i], q.workspaceScheduleOverrides[i+1:]...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (q *FakeQuerier) GetACMECertificate(_ context.Context, arg database.GetACMECertificateParams) (database.ACMECertificate, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.ACMECertificate{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, certificate := range q.acmeCertificates {
+		if certificate.WorkspaceProxyID == arg.WorkspaceProxyID && certificate.Domain == arg.Domain {
+			return certificate, nil
+		}
+	}
+	return database.ACMECertificate{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetAPIKeyByID(_ context.Context, id string) (database.APIKey, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -864,103 +1387,361 @@ func (q *FakeQuerier) GetAPIKeyByName(_ context.Context, params database.GetAPIK
 	return database.APIKey{}, sql.ErrNoRows
 }
 
-func (q *FakeQuerier) GetAPIKeysByLoginType(_ context.Context, t database.LoginType) ([]database.APIKey, error) {
-	if err := validateDatabaseType(t); err != nil {
+func (q *FakeQuerier) GetAPIKeysByLoginType(_ context.Context, t database.LoginType) ([]database.APIKey, error) {
+	if err := validateDatabaseType(t); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	apiKeys := make([]database.APIKey, 0)
+	for _, key := range q.apiKeys {
+		if key.LoginType == t {
+			apiKeys = append(apiKeys, key)
+		}
+	}
+	return apiKeys, nil
+}
+
+func (q *FakeQuerier) GetAPIKeysByUserID(_ context.Context, params database.GetAPIKeysByUserIDParams) ([]database.APIKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	apiKeys := make([]database.APIKey, 0)
+	for _, key := range q.apiKeys {
+		if key.UserID == params.UserID && key.LoginType == params.LoginType {
+			apiKeys = append(apiKeys, key)
+		}
+	}
+	return apiKeys, nil
+}
+
+func (q *FakeQuerier) GetAPIKeysLastUsedAfter(_ context.Context, after time.Time) ([]database.APIKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	apiKeys := make([]database.APIKey, 0)
+	for _, key := range q.apiKeys {
+		if key.LastUsed.After(after) {
+			apiKeys = append(apiKeys, key)
+		}
+	}
+	return apiKeys, nil
+}
+
+func (q *FakeQuerier) GetActiveUserCount(_ context.Context) (int64, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	active := int64(0)
+	for _, u := range q.users {
+		if u.Status == database.UserStatusActive && !u.Deleted {
+			active++
+		}
+	}
+	return active, nil
+}
+
+func (q *FakeQuerier) GetActiveWorkspaceBuildsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]database.WorkspaceBuild, error) {
+	workspaceIDs := func() []uuid.UUID {
+		q.mutex.RLock()
+		defer q.mutex.RUnlock()
+
+		ids := []uuid.UUID{}
+		for _, workspace := range q.workspaces {
+			if workspace.TemplateID == templateID {
+				ids = append(ids, workspace.ID)
+			}
+		}
+		return ids
+	}()
+
+	builds, err := q.GetLatestWorkspaceBuildsByWorkspaceIDs(ctx, workspaceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	filteredBuilds := []database.WorkspaceBuild{}
+	for _, build := range builds {
+		if build.Transition == database.WorkspaceTransitionStart {
+			filteredBuilds = append(filteredBuilds, build)
+		}
+	}
+	return filteredBuilds, nil
+}
+
+func (*FakeQuerier) GetAllTailnetAgents(_ context.Context) ([]database.TailnetAgent, error) {
+	return nil, ErrUnimplemented
+}
+
+func (*FakeQuerier) GetAllTailnetClients(_ context.Context) ([]database.TailnetClient, error) {
+	return nil, ErrUnimplemented
+}
+
+func (q *FakeQuerier) GetAppAccessLogInsights(_ context.Context, arg database.GetAppAccessLogInsightsParams) ([]database.GetAppAccessLogInsightsRow, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	type appAccessLogKey struct {
+		UserID       uuid.NullUUID
+		TemplateID   uuid.UUID
+		AccessMethod string
+		SlugOrPort   string
+		PathPrefix   string
+		StatusCode   int32
+	}
+	rowsByKey := make(map[appAccessLogKey]*database.GetAppAccessLogInsightsRow)
+	latenciesByKey := make(map[appAccessLogKey][]float64)
+	for _, l := range q.workspaceAppAccessLogs {
+		if l.CreatedAt.Before(arg.StartTime) || !l.CreatedAt.Before(arg.EndTime) {
+			continue
+		}
+		w, err := q.getWorkspaceByIDNoLock(context.Background(), l.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if len(arg.TemplateIDs) > 0 && !slices.Contains(arg.TemplateIDs, w.TemplateID) {
+			continue
+		}
+
+		key := appAccessLogKey{
+			UserID:       l.UserID,
+			TemplateID:   w.TemplateID,
+			AccessMethod: l.AccessMethod,
+			SlugOrPort:   l.SlugOrPort,
+			PathPrefix:   l.PathPrefix,
+			StatusCode:   l.StatusCode,
+		}
+		row, ok := rowsByKey[key]
+		if !ok {
+			row = &database.GetAppAccessLogInsightsRow{
+				UserID:        l.UserID,
+				TemplateID:    w.TemplateID,
+				AccessMethod:  l.AccessMethod,
+				SlugOrPort:    l.SlugOrPort,
+				PathPrefix:    l.PathPrefix,
+				StatusCode:    l.StatusCode,
+				LastRequestAt: l.CreatedAt,
+			}
+			if l.UserID.Valid {
+				user, err := q.getUserByIDNoLock(l.UserID.UUID)
+				if err != nil {
+					return nil, err
+				}
+				row.Username = user.Username
+				row.AvatarURL = user.AvatarURL.String
+			}
+			rowsByKey[key] = row
+		}
+		row.Requests++
+		if l.CreatedAt.After(row.LastRequestAt) {
+			row.LastRequestAt = l.CreatedAt
+		}
+		latenciesByKey[key] = append(latenciesByKey[key], float64(l.LatencyMS))
+	}
+
+	tryPercentile := func(fs []float64, p float64) float64 {
+		if len(fs) == 0 {
+			return -1
+		}
+		sort.Float64s(fs)
+		return fs[int(float64(len(fs))*p/100)]
+	}
+
+	rows := make([]database.GetAppAccessLogInsightsRow, 0, len(rowsByKey))
+	for key, row := range rowsByKey {
+		row.Latency50 = tryPercentile(latenciesByKey[key], 50)
+		row.Latency95 = tryPercentile(latenciesByKey[key], 95)
+		rows = append(rows, *row)
+	}
+	slices.SortFunc(rows, func(a, b database.GetAppAccessLogInsightsRow) int {
+		if a.UserID != b.UserID {
+			// Anonymous requests are sorted last, like NULLs in Postgres.
+			if a.UserID.Valid != b.UserID.Valid {
+				if a.UserID.Valid {
+					return -1
+				}
+				return 1
+			}
+			return slice.Ascending(a.UserID.UUID.String(), b.UserID.UUID.String())
+		}
+		if a.TemplateID != b.TemplateID {
+			return slice.Ascending(a.TemplateID.String(), b.TemplateID.String())
+		}
+		if a.SlugOrPort != b.SlugOrPort {
+			return slice.Ascending(a.SlugOrPort, b.SlugOrPort)
+		}
+		if a.AccessMethod != b.AccessMethod {
+			return slice.Ascending(a.AccessMethod, b.AccessMethod)
+		}
+		if a.PathPrefix != b.PathPrefix {
+			return slice.Ascending(a.PathPrefix, b.PathPrefix)
+		}
+		return slice.Ascending(a.StatusCode, b.StatusCode)
+	})
+
+	return rows, nil
+}
+
+func (q *FakeQuerier) GetAppSecurityKey(_ context.Context) (string, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return q.appSecurityKey, nil
+}
+
+func (q *FakeQuerier) GetAppSessionInsights(_ context.Context, arg database.GetAppSessionInsightsParams) ([]database.GetAppSessionInsightsRow, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
 		return nil, err
 	}
 
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	apiKeys := make([]database.APIKey, 0)
-	for _, key := range q.apiKeys {
-		if key.LoginType == t {
-			apiKeys = append(apiKeys, key)
-		}
+	type appSessionKey struct {
+		UserID       uuid.UUID
+		TemplateID   uuid.UUID
+		AccessMethod string
+		SlugOrPort   string
 	}
-	return apiKeys, nil
-}
-
-func (q *FakeQuerier) GetAPIKeysByUserID(_ context.Context, params database.GetAPIKeysByUserIDParams) ([]database.APIKey, error) {
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
+	rowsByKey := make(map[appSessionKey]*database.GetAppSessionInsightsRow)
+	for _, s := range q.workspaceAppStats {
+		if s.SessionStartedAt.Before(arg.StartTime) || !s.SessionStartedAt.Before(arg.EndTime) {
+			continue
+		}
+		w, err := q.getWorkspaceByIDNoLock(context.Background(), s.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if len(arg.TemplateIDs) > 0 && !slices.Contains(arg.TemplateIDs, w.TemplateID) {
+			continue
+		}
 
-	apiKeys := make([]database.APIKey, 0)
-	for _, key := range q.apiKeys {
-		if key.UserID == params.UserID && key.LoginType == params.LoginType {
-			apiKeys = append(apiKeys, key)
+		key := appSessionKey{
+			UserID:       s.UserID,
+			TemplateID:   w.TemplateID,
+			AccessMethod: s.AccessMethod,
+			SlugOrPort:   s.SlugOrPort,
+		}
+		row, ok := rowsByKey[key]
+		if !ok {
+			user, err := q.getUserByIDNoLock(s.UserID)
+			if err != nil {
+				return nil, err
+			}
+			row = &database.GetAppSessionInsightsRow{
+				UserID:                s.UserID,
+				Username:              user.Username,
+				AvatarURL:             user.AvatarURL,
+				TemplateID:            w.TemplateID,
+				AccessMethod:          s.AccessMethod,
+				SlugOrPort:            s.SlugOrPort,
+				FirstSessionStartedAt: s.SessionStartedAt,
+				LastSessionEndedAt:    s.SessionEndedAt,
+			}
+			rowsByKey[key] = row
+		}
+		row.Sessions += int64(s.Requests)
+		row.ActiveDurationMS += s.ActiveDurationMS
+		if s.SessionStartedAt.Before(row.FirstSessionStartedAt) {
+			row.FirstSessionStartedAt = s.SessionStartedAt
+		}
+		if s.SessionEndedAt.After(row.LastSessionEndedAt) {
+			row.LastSessionEndedAt = s.SessionEndedAt
 		}
 	}
-	return apiKeys, nil
-}
-
-func (q *FakeQuerier) GetAPIKeysLastUsedAfter(_ context.Context, after time.Time) ([]database.APIKey, error) {
-	q.mutex.RLock()
-	defer q.mutex.RUnlock()
 
-	apiKeys := make([]database.APIKey, 0)
-	for _, key := range q.apiKeys {
-		if key.LastUsed.After(after) {
-			apiKeys = append(apiKeys, key)
-		}
+	rows := make([]database.GetAppSessionInsightsRow, 0, len(rowsByKey))
+	for _, row := range rowsByKey {
+		rows = append(rows, *row)
 	}
-	return apiKeys, nil
+	slices.SortFunc(rows, func(a, b database.GetAppSessionInsightsRow) int {
+		if a.UserID != b.UserID {
+			return slice.Ascending(a.UserID.String(), b.UserID.String())
+		}
+		if a.TemplateID != b.TemplateID {
+			return slice.Ascending(a.TemplateID.String(), b.TemplateID.String())
+		}
+		if a.SlugOrPort != b.SlugOrPort {
+			return slice.Ascending(a.SlugOrPort, b.SlugOrPort)
+		}
+		return slice.Ascending(a.AccessMethod, b.AccessMethod)
+	})
+
+	return rows, nil
 }
 
-func (q *FakeQuerier) GetActiveUserCount(_ context.Context) (int64, error) {
+func (q *FakeQuerier) GetAuditLogExportCursor(_ context.Context, sink string) (database.AuditLogExportCursor, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	active := int64(0)
-	for _, u := range q.users {
-		if u.Status == database.UserStatusActive && !u.Deleted {
-			active++
+	for _, cursor := range q.auditLogExportCursors {
+		if cursor.Sink == sink {
+			return cursor, nil
 		}
 	}
-	return active, nil
+	return database.AuditLogExportCursor{}, sql.ErrNoRows
 }
 
-func (q *FakeQuerier) GetActiveWorkspaceBuildsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]database.WorkspaceBuild, error) {
-	workspaceIDs := func() []uuid.UUID {
-		q.mutex.RLock()
-		defer q.mutex.RUnlock()
-
-		ids := []uuid.UUID{}
-		for _, workspace := range q.workspaces {
-			if workspace.TemplateID == templateID {
-				ids = append(ids, workspace.ID)
-			}
-		}
-		return ids
-	}()
-
-	builds, err := q.GetLatestWorkspaceBuildsByWorkspaceIDs(ctx, workspaceIDs)
-	if err != nil {
+func (q *FakeQuerier) GetAuditLogsAfterCursor(_ context.Context, arg database.GetAuditLogsAfterCursorParams) ([]database.AuditLog, error) {
+	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
 	}
 
-	filteredBuilds := []database.WorkspaceBuild{}
-	for _, build := range builds {
-		if build.Transition == database.WorkspaceTransitionStart {
-			filteredBuilds = append(filteredBuilds, build)
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	logs := make([]database.AuditLog, 0)
+	for _, alog := range q.auditLogs {
+		after := alog.Time.After(arg.AfterTime) ||
+			(alog.Time.Equal(arg.AfterTime) && alog.ID.String() > arg.AfterID.String())
+		if after && alog.Time.Before(arg.CreatedBefore) {
+			logs = append(logs, alog)
 		}
 	}
-	return filteredBuilds, nil
-}
-
-func (*FakeQuerier) GetAllTailnetAgents(_ context.Context) ([]database.TailnetAgent, error) {
-	return nil, ErrUnimplemented
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].Time.Equal(logs[j].Time) {
+			return logs[i].ID.String() < logs[j].ID.String()
+		}
+		return logs[i].Time.Before(logs[j].Time)
+	})
+	if arg.LimitCount > 0 && len(logs) > int(arg.LimitCount) {
+		logs = logs[:arg.LimitCount]
+	}
+	return logs, nil
 }
 
-func (*FakeQuerier) GetAllTailnetClients(_ context.Context) ([]database.TailnetClient, error) {
-	return nil, ErrUnimplemented
-}
+func (q *FakeQuerier) GetAuditLogsCreatedBefore(_ context.Context, arg database.GetAuditLogsCreatedBeforeParams) ([]database.AuditLog, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
 
-func (q *FakeQuerier) GetAppSecurityKey(_ context.Context) (string, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	return q.appSecurityKey, nil
+	logs := make([]database.AuditLog, 0)
+	for _, alog := range q.auditLogs {
+		if alog.Time.Before(arg.CreatedBefore) {
+			logs = append(logs, alog)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool {
+		if logs[i].Time.Equal(logs[j].Time) {
+			return logs[i].ID.String() < logs[j].ID.String()
+		}
+		return logs[i].Time.Before(logs[j].Time)
+	})
+	if arg.LimitCount > 0 && len(logs) > int(arg.LimitCount) {
+		logs = logs[:arg.LimitCount]
+	}
+	return logs, nil
 }
 
 func (q *FakeQuerier) GetAuditLogsOffset(_ context.Context, arg database.GetAuditLogsOffsetParams) ([]database.GetAuditLogsOffsetRow, error) {
@@ -1099,6 +1880,29 @@ func (q *FakeQuerier) GetAuthorizationUserRoles(_ context.Context, userID uuid.U
 	}, nil
 }
 
+func (q *FakeQuerier) GetCustomRoleByName(_ context.Context, name string) (database.CustomRole, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, role := range q.customRoles {
+		if role.Name == name {
+			return role, nil
+		}
+	}
+	return database.CustomRole{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetCustomRoles(_ context.Context) ([]database.CustomRole, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	roles := slices.Clone(q.customRoles)
+	slices.SortFunc(roles, func(a, b database.CustomRole) int {
+		return slice.Ascending(a.Name, b.Name)
+	})
+	return roles, nil
+}
+
 func (q *FakeQuerier) GetDERPMeshKey(_ context.Context) (string, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1240,7 +2044,8 @@ func (q *FakeQuerier) GetDeploymentWorkspaceStats(ctx context.Context) (database
 			if build.Transition == database.WorkspaceTransitionStart {
 				stat.RunningWorkspaces++
 			}
-			if build.Transition == database.WorkspaceTransitionStop {
+			if build.Transition == database.WorkspaceTransitionStop ||
+				build.Transition == database.WorkspaceTransitionArchive {
 				stat.StoppedWorkspaces++
 			}
 			continue
@@ -1378,6 +2183,19 @@ func (q *FakeQuerier) GetGroupByOrgAndName(_ context.Context, arg database.GetGr
 	return database.Group{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) GetGroupMemberUserIDs(_ context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var userIDs []uuid.UUID
+	for _, member := range q.groupMembers {
+		if member.GroupID == groupID {
+			userIDs = append(userIDs, member.UserID)
+		}
+	}
+	return userIDs, nil
+}
+
 func (q *FakeQuerier) GetGroupMembers(_ context.Context, groupID uuid.UUID) ([]database.User, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1540,6 +2358,71 @@ func (q *FakeQuerier) GetOAuthSigningKey(_ context.Context) (string, error) {
 	return q.oauthSigningKey, nil
 }
 
+func (q *FakeQuerier) GetOrganizationBudgetSettings(_ context.Context, organizationID uuid.UUID) (database.OrganizationBudgetSettings, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, settings := range q.organizationBudgetSettings {
+		if settings.OrganizationID == organizationID {
+			return settings, nil
+		}
+	}
+	return database.OrganizationBudgetSettings{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetOrganizationBudgetUsages(_ context.Context) ([]database.GetOrganizationBudgetUsagesRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetOrganizationBudgetUsagesRow, 0)
+	for _, settings := range q.organizationBudgetSettings {
+		if settings.DailyBudget <= 0 {
+			continue
+		}
+		var organizationName string
+		for _, organization := range q.organizations {
+			if organization.ID == settings.OrganizationID {
+				organizationName = organization.Name
+				break
+			}
+		}
+
+		var consumed int64
+		for _, workspace := range q.workspaces {
+			if workspace.OrganizationID != settings.OrganizationID {
+				continue
+			}
+			if workspace.Deleted {
+				continue
+			}
+
+			var lastBuild database.WorkspaceBuildTable
+			for _, build := range q.workspaceBuilds {
+				if build.WorkspaceID != workspace.ID {
+					continue
+				}
+				if build.CreatedAt.After(lastBuild.CreatedAt) {
+					lastBuild = build
+				}
+			}
+			consumed += int64(lastBuild.DailyCost)
+		}
+
+		rows = append(rows, database.GetOrganizationBudgetUsagesRow{
+			OrganizationID:           settings.OrganizationID,
+			CreatedAt:                settings.CreatedAt,
+			UpdatedAt:                settings.UpdatedAt,
+			DailyBudget:              settings.DailyBudget,
+			WarningThresholdPercent:  settings.WarningThresholdPercent,
+			CriticalThresholdPercent: settings.CriticalThresholdPercent,
+			AlertedThresholdPercent:  settings.AlertedThresholdPercent,
+			OrganizationName:         organizationName,
+			CreditsConsumed:          consumed,
+		})
+	}
+	return rows, nil
+}
+
 func (q *FakeQuerier) GetOrganizationByID(_ context.Context, id uuid.UUID) (database.Organization, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1587,6 +2470,18 @@ func (q *FakeQuerier) GetOrganizationIDsByMemberIDs(_ context.Context, ids []uui
 	return getOrganizationIDsByMemberIDRows, nil
 }
 
+func (q *FakeQuerier) GetOrganizationJITProvisioningSettings(_ context.Context, organizationID uuid.UUID) (database.OrganizationJITProvisioningSettings, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, settings := range q.organizationJITProvisioningSettings {
+		if settings.OrganizationID == organizationID {
+			return settings, nil
+		}
+	}
+	return database.OrganizationJITProvisioningSettings{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetOrganizationMemberByUserID(_ context.Context, arg database.GetOrganizationMemberByUserIDParams) (database.OrganizationMember, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.OrganizationMember{}, err
@@ -1622,6 +2517,46 @@ func (q *FakeQuerier) GetOrganizationMembershipsByUserID(_ context.Context, user
 	return memberships, nil
 }
 
+func (q *FakeQuerier) GetOrganizationProvisionerSettings(_ context.Context, organizationID uuid.UUID) (database.OrganizationProvisionerSettings, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, settings := range q.organizationProvisionerSettings {
+		if settings.OrganizationID == organizationID {
+			return settings, nil
+		}
+	}
+	return database.OrganizationProvisionerSettings{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetOrganizationScheduleSettings(_ context.Context, organizationID uuid.UUID) (database.OrganizationScheduleSettings, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, settings := range q.organizationScheduleSettings {
+		if settings.OrganizationID == organizationID {
+			return settings, nil
+		}
+	}
+	return database.OrganizationScheduleSettings{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetOrganizationTemplateVariables(_ context.Context, organizationID uuid.UUID) ([]database.OrganizationTemplateVariable, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var variables []database.OrganizationTemplateVariable
+	for _, variable := range q.organizationTemplateVariables {
+		if variable.OrganizationID == organizationID {
+			variables = append(variables, variable)
+		}
+	}
+	slices.SortFunc(variables, func(a, b database.OrganizationTemplateVariable) int {
+		return slice.Ascending(a.Name, b.Name)
+	})
+	return variables, nil
+}
+
 func (q *FakeQuerier) GetOrganizations(_ context.Context) ([]database.Organization, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1674,6 +2609,44 @@ func (q *FakeQuerier) GetParameterSchemasByJobID(_ context.Context, jobID uuid.U
 	return parameters, nil
 }
 
+func (q *FakeQuerier) GetPrebuiltWorkspaces(ctx context.Context, ownerID uuid.UUID) ([]database.GetPrebuiltWorkspacesRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetPrebuiltWorkspacesRow, 0)
+	for _, workspace := range q.workspaces {
+		if workspace.OwnerID != ownerID || workspace.Deleted {
+			continue
+		}
+		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, database.GetPrebuiltWorkspacesRow{
+			ID:                workspace.ID,
+			CreatedAt:         workspace.CreatedAt,
+			TemplateID:        workspace.TemplateID,
+			TemplateVersionID: build.TemplateVersionID,
+			Transition:        build.Transition,
+			StartedAt:         job.StartedAt,
+			CanceledAt:        job.CanceledAt,
+			CompletedAt:       job.CompletedAt,
+			Error:             job.Error,
+		})
+	}
+	slices.SortStableFunc(rows, func(a, b database.GetPrebuiltWorkspacesRow) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return rows, nil
+}
+
 func (q *FakeQuerier) GetPreviousTemplateVersion(_ context.Context, arg database.GetPreviousTemplateVersionParams) (database.TemplateVersion, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.TemplateVersion{}, err
@@ -1742,6 +2715,59 @@ func (q *FakeQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (
 	return q.getProvisionerJobByIDNoLock(ctx, id)
 }
 
+func (q *FakeQuerier) GetProvisionerJobConcurrency(ctx context.Context, id uuid.UUID) (database.GetProvisionerJobConcurrencyRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	job, err := q.getProvisionerJobByIDNoLock(ctx, id)
+	if err != nil {
+		return database.GetProvisionerJobConcurrencyRow{}, err
+	}
+	running := func(other database.ProvisionerJob) bool {
+		return other.ID != job.ID && other.StartedAt.Valid && !other.CompletedAt.Valid
+	}
+	templateID := func(jobID uuid.UUID) uuid.UUID {
+		for _, build := range q.workspaceBuilds {
+			if build.JobID != jobID {
+				continue
+			}
+			for _, workspace := range q.workspaces {
+				if workspace.ID == build.WorkspaceID {
+					return workspace.TemplateID
+				}
+			}
+		}
+		return uuid.Nil
+	}
+
+	row := database.GetProvisionerJobConcurrencyRow{
+		OrganizationID: job.OrganizationID,
+		TemplateID:     templateID(job.ID),
+	}
+	for _, settings := range q.organizationProvisionerSettings {
+		if settings.OrganizationID == job.OrganizationID {
+			row.OrganizationMaxConcurrentJobs = settings.MaxConcurrentJobs
+		}
+	}
+	for _, template := range q.templates {
+		if row.TemplateID != uuid.Nil && template.ID == row.TemplateID {
+			row.TemplateMaxConcurrentBuilds = template.MaxConcurrentBuilds
+		}
+	}
+	for _, other := range q.provisionerJobs {
+		if !running(other) {
+			continue
+		}
+		if other.OrganizationID == job.OrganizationID {
+			row.OrganizationRunningJobs++
+		}
+		if row.TemplateID != uuid.Nil && templateID(other.ID) == row.TemplateID {
+			row.TemplateRunningBuilds++
+		}
+	}
+	return row, nil
+}
+
 func (q *FakeQuerier) GetProvisionerJobsByIDs(_ context.Context, ids []uuid.UUID) ([]database.ProvisionerJob, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1766,30 +2792,35 @@ func (q *FakeQuerier) GetProvisionerJobsByIDsWithQueuePosition(_ context.Context
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	jobs := make([]database.GetProvisionerJobsByIDsWithQueuePositionRow, 0)
-	queuePosition := int64(1)
+	// Unstarted jobs are queued by priority, then in the order they were
+	// created.
+	queued := make([]database.ProvisionerJob, 0)
 	for _, job := range q.provisionerJobs {
-		for _, id := range ids {
-			if id == job.ID {
-				job := database.GetProvisionerJobsByIDsWithQueuePositionRow{
-					ProvisionerJob: job,
-				}
-				if !job.ProvisionerJob.StartedAt.Valid {
-					job.QueuePosition = queuePosition
-				}
-				jobs = append(jobs, job)
-				break
-			}
+		if !job.StartedAt.Valid {
+			queued = append(queued, job)
+		}
+	}
+	sort.SliceStable(queued, func(i, j int) bool {
+		return queued[i].Priority > queued[j].Priority
+	})
+	queuePositions := make(map[uuid.UUID]int64, len(queued))
+	for i, job := range queued {
+		queuePositions[job.ID] = int64(i + 1)
+	}
+
+	jobs := make([]database.GetProvisionerJobsByIDsWithQueuePositionRow, 0)
+	for _, job := range q.provisionerJobs {
+		if !slices.Contains(ids, job.ID) {
+			continue
 		}
-		if !job.StartedAt.Valid {
-			queuePosition++
+		row := database.GetProvisionerJobsByIDsWithQueuePositionRow{
+			ProvisionerJob: job,
 		}
-	}
-	for _, job := range jobs {
-		if !job.ProvisionerJob.StartedAt.Valid {
-			// Set it to the max position!
-			job.QueueSize = queuePosition
+		if position, ok := queuePositions[job.ID]; ok {
+			row.QueuePosition = position
+			row.QueueSize = int64(len(queued))
 		}
+		jobs = append(jobs, row)
 	}
 	return jobs, nil
 }
@@ -1807,6 +2838,50 @@ func (q *FakeQuerier) GetProvisionerJobsCreatedAfter(_ context.Context, after ti
 	return jobs, nil
 }
 
+func (q *FakeQuerier) GetProvisionerKeyByID(_ context.Context, id uuid.UUID) (database.ProvisionerKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, key := range q.provisionerKeys {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+	return database.ProvisionerKey{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetProvisionerKeyByOrganizationIDAndName(_ context.Context, arg database.GetProvisionerKeyByOrganizationIDAndNameParams) (database.ProvisionerKey, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.ProvisionerKey{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, key := range q.provisionerKeys {
+		if key.OrganizationID == arg.OrganizationID && strings.EqualFold(key.Name, arg.Name) {
+			return key, nil
+		}
+	}
+	return database.ProvisionerKey{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetProvisionerKeysByOrganizationID(_ context.Context, organizationID uuid.UUID) ([]database.ProvisionerKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	keys := make([]database.ProvisionerKey, 0)
+	for _, key := range q.provisionerKeys {
+		if key.OrganizationID == organizationID {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b database.ProvisionerKey) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return keys, nil
+}
+
 func (q *FakeQuerier) GetProvisionerLogsAfterID(_ context.Context, arg database.GetProvisionerLogsAfterIDParams) ([]database.ProvisionerJobLog, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
@@ -1873,6 +2948,94 @@ func (q *FakeQuerier) GetQuotaConsumedForUser(_ context.Context, userID uuid.UUI
 	return sum, nil
 }
 
+func (q *FakeQuerier) GetQuotaConsumedForUserByWorkspace(ctx context.Context, ownerID uuid.UUID) ([]database.GetQuotaConsumedForUserByWorkspaceRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetQuotaConsumedForUserByWorkspaceRow, 0)
+	for _, workspace := range q.workspaces {
+		if workspace.OwnerID != ownerID {
+			continue
+		}
+		if workspace.Deleted {
+			continue
+		}
+
+		var lastBuild database.WorkspaceBuildTable
+		for _, build := range q.workspaceBuilds {
+			if build.WorkspaceID != workspace.ID {
+				continue
+			}
+			if build.CreatedAt.After(lastBuild.CreatedAt) {
+				lastBuild = build
+			}
+		}
+		if lastBuild.ID == uuid.Nil {
+			continue
+		}
+		template, err := q.getTemplateByIDNoLock(ctx, workspace.TemplateID)
+		if err != nil {
+			continue
+		}
+		rows = append(rows, database.GetQuotaConsumedForUserByWorkspaceRow{
+			WorkspaceID:   workspace.ID,
+			WorkspaceName: workspace.Name,
+			TemplateID:    template.ID,
+			TemplateName:  template.Name,
+			Transition:    lastBuild.Transition,
+			DailyCost:     lastBuild.DailyCost,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].WorkspaceName < rows[j].WorkspaceName
+	})
+	return rows, nil
+}
+
+func (q *FakeQuerier) GetQuotaResourceMetadataForUser(_ context.Context, arg database.GetQuotaResourceMetadataForUserParams) ([]database.WorkspaceResourceMetadatum, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	metadata := make([]database.WorkspaceResourceMetadatum, 0)
+	for _, workspace := range q.workspaces {
+		if workspace.OwnerID != arg.OwnerID || workspace.TemplateID != arg.TemplateID {
+			continue
+		}
+		if workspace.Deleted {
+			continue
+		}
+
+		var lastBuild database.WorkspaceBuildTable
+		for _, build := range q.workspaceBuilds {
+			if build.WorkspaceID != workspace.ID {
+				continue
+			}
+			if build.CreatedAt.After(lastBuild.CreatedAt) {
+				lastBuild = build
+			}
+		}
+		for _, resource := range q.workspaceResources {
+			if resource.JobID != lastBuild.JobID {
+				continue
+			}
+			for _, m := range q.workspaceResourceMetadata {
+				if m.WorkspaceResourceID != resource.ID {
+					continue
+				}
+				switch m.Key {
+				case "cpu", "memory", "disk":
+					metadata = append(metadata, m)
+				}
+			}
+		}
+	}
+	return metadata, nil
+}
+
 func (q *FakeQuerier) GetReplicaByID(_ context.Context, id uuid.UUID) (database.Replica, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -1898,6 +3061,158 @@ func (q *FakeQuerier) GetReplicasUpdatedAfter(_ context.Context, updatedAt time.
 	return replicas, nil
 }
 
+func (q *FakeQuerier) GetSavedWorkspaceFilterByUserIDAndName(_ context.Context, arg database.GetSavedWorkspaceFilterByUserIDAndNameParams) (database.SavedWorkspaceFilter, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.SavedWorkspaceFilter{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, filter := range q.savedWorkspaceFilters {
+		if filter.UserID == arg.UserID && filter.Name == arg.Name {
+			return filter, nil
+		}
+	}
+	return database.SavedWorkspaceFilter{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetSavedWorkspaceFiltersByUserID(_ context.Context, userID uuid.UUID) ([]database.SavedWorkspaceFilter, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	filters := make([]database.SavedWorkspaceFilter, 0)
+	for _, filter := range q.savedWorkspaceFilters {
+		if filter.UserID == userID {
+			filters = append(filters, filter)
+		}
+	}
+	sort.Slice(filters, func(i, j int) bool {
+		return filters[i].Name < filters[j].Name
+	})
+	return filters, nil
+}
+
+func (q *FakeQuerier) GetScheduleHolidayByID(_ context.Context, id uuid.UUID) (database.ScheduleHoliday, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, holiday := range q.scheduleHolidays {
+		if holiday.ID == id {
+			return holiday, nil
+		}
+	}
+	return database.ScheduleHoliday{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetScheduleHolidays(_ context.Context) ([]database.ScheduleHoliday, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	holidays := slices.Clone(q.scheduleHolidays)
+	slices.SortFunc(holidays, func(a, b database.ScheduleHoliday) int {
+		return a.Date.Compare(b.Date)
+	})
+	return holidays, nil
+}
+
+func (q *FakeQuerier) GetScheduleInsights(_ context.Context, arg database.GetScheduleInsightsParams) ([]database.GetScheduleInsightsRow, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	buildsByWorkspace := make(map[uuid.UUID][]database.WorkspaceBuildTable)
+	for _, build := range q.workspaceBuilds {
+		if !build.CreatedAt.Before(arg.EndTime) {
+			continue
+		}
+		buildsByWorkspace[build.WorkspaceID] = append(buildsByWorkspace[build.WorkspaceID], build)
+	}
+
+	type scheduleDurations struct {
+		allocated, running, scheduleStopped time.Duration
+	}
+	durationsByTemplate := make(map[uuid.UUID]*scheduleDurations)
+	rowsByTemplate := make(map[uuid.UUID]*database.GetScheduleInsightsRow)
+	for workspaceID, builds := range buildsByWorkspace {
+		w, err := q.getWorkspaceByIDNoLock(context.Background(), workspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if len(arg.TemplateIDs) > 0 && !slices.Contains(arg.TemplateIDs, w.TemplateID) {
+			continue
+		}
+
+		slices.SortFunc(builds, func(a, b database.WorkspaceBuildTable) int {
+			return int(a.BuildNumber - b.BuildNumber)
+		})
+		for i, build := range builds {
+			start := build.CreatedAt
+			if start.Before(arg.StartTime) {
+				start = arg.StartTime
+			}
+			end := arg.EndTime
+			if i+1 < len(builds) && builds[i+1].CreatedAt.Before(end) {
+				end = builds[i+1].CreatedAt
+			}
+			if !end.After(start) {
+				continue
+			}
+
+			job, err := q.getProvisionerJobByIDNoLock(context.Background(), build.JobID)
+			if err != nil {
+				return nil, err
+			}
+			row, ok := rowsByTemplate[w.TemplateID]
+			if !ok {
+				row = &database.GetScheduleInsightsRow{TemplateID: w.TemplateID}
+				rowsByTemplate[w.TemplateID] = row
+				durationsByTemplate[w.TemplateID] = &scheduleDurations{}
+			}
+			durations := durationsByTemplate[w.TemplateID]
+
+			scheduled := build.Reason == database.BuildReasonAutostop || build.Reason == database.BuildReasonRestartRequirement
+			inPeriod := !build.CreatedAt.Before(arg.StartTime)
+			switch build.Transition {
+			case database.WorkspaceTransitionStart:
+				durations.allocated += end.Sub(start)
+				if !job.Error.Valid {
+					durations.running += end.Sub(start)
+				}
+				if inPeriod && build.Reason == database.BuildReasonAutostart {
+					row.Autostarts++
+				}
+			case database.WorkspaceTransitionStop:
+				durations.allocated += end.Sub(start)
+				if scheduled {
+					durations.scheduleStopped += end.Sub(start)
+					if inPeriod {
+						row.Autostops++
+					}
+				}
+			}
+		}
+	}
+
+	rows := make([]database.GetScheduleInsightsRow, 0, len(rowsByTemplate))
+	for templateID, row := range rowsByTemplate {
+		durations := durationsByTemplate[templateID]
+		row.AllocatedSeconds = int64(durations.allocated.Round(time.Second) / time.Second)
+		row.RunningSeconds = int64(durations.running.Round(time.Second) / time.Second)
+		row.ScheduleStoppedSeconds = int64(durations.scheduleStopped.Round(time.Second) / time.Second)
+		rows = append(rows, *row)
+	}
+	slices.SortFunc(rows, func(a, b database.GetScheduleInsightsRow) int {
+		return slice.Ascending(a.TemplateID.String(), b.TemplateID.String())
+	})
+
+	return rows, nil
+}
+
 func (q *FakeQuerier) GetServiceBanner(_ context.Context) (string, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2103,6 +3418,34 @@ func (q *FakeQuerier) GetTemplateDailyInsights(_ context.Context, arg database.G
 	return result, nil
 }
 
+func (q *FakeQuerier) GetTemplateDeadlineRecalculationByID(_ context.Context, id uuid.UUID) (database.TemplateDeadlineRecalculation, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, recalculation := range q.templateDeadlineRecalculations {
+		if recalculation.ID == id {
+			return recalculation, nil
+		}
+	}
+	return database.TemplateDeadlineRecalculation{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetTemplateDeadlineRecalculationsByTemplateID(_ context.Context, templateID uuid.UUID) ([]database.TemplateDeadlineRecalculation, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var recalculations []database.TemplateDeadlineRecalculation
+	for _, recalculation := range q.templateDeadlineRecalculations {
+		if recalculation.TemplateID == templateID {
+			recalculations = append(recalculations, recalculation)
+		}
+	}
+	sort.Slice(recalculations, func(i, j int) bool {
+		return recalculations[i].CreatedAt.After(recalculations[j].CreatedAt)
+	})
+	return recalculations, nil
+}
+
 func (q *FakeQuerier) GetTemplateInsights(_ context.Context, arg database.GetTemplateInsightsParams) (database.GetTemplateInsightsRow, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -2263,6 +3606,175 @@ func (q *FakeQuerier) GetTemplateParameterInsights(ctx context.Context, arg data
 	return rows, nil
 }
 
+func (q *FakeQuerier) GetTemplateParameterValidation(_ context.Context, templateID uuid.UUID) (database.TemplateParameterValidation, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, validation := range q.templateParameterValidations {
+		if validation.TemplateID == templateID {
+			return validation, nil
+		}
+	}
+	return database.TemplateParameterValidation{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetTemplatePrebuildPool(_ context.Context, templateID uuid.UUID) (database.TemplatePrebuildPool, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, pool := range q.templatePrebuildPools {
+		if pool.TemplateID == templateID {
+			return pool, nil
+		}
+	}
+	return database.TemplatePrebuildPool{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetTemplatePrebuildPools(ctx context.Context) ([]database.TemplatePrebuildPool, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	pools := make([]database.TemplatePrebuildPool, 0)
+	for _, pool := range q.templatePrebuildPools {
+		if pool.Size <= 0 {
+			continue
+		}
+		template, err := q.getTemplateByIDNoLock(ctx, pool.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		if template.Deleted {
+			continue
+		}
+		pools = append(pools, pool)
+	}
+	return pools, nil
+}
+
+func (q *FakeQuerier) GetTemplateVersionBuildInsights(ctx context.Context, arg database.GetTemplateVersionBuildInsightsParams) ([]database.GetTemplateVersionBuildInsightsRow, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+	if arg.IntervalSeconds <= 0 {
+		return nil, xerrors.New("interval must be positive")
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	interval := time.Duration(arg.IntervalSeconds) * time.Second
+	type buildInsightsKey struct {
+		StartTime         time.Time
+		TemplateVersionID uuid.UUID
+	}
+	rowsByKey := make(map[buildInsightsKey]*database.GetTemplateVersionBuildInsightsRow)
+	durationsByKey := make(map[buildInsightsKey][]float64)
+	connectTimesByKey := make(map[buildInsightsKey][]float64)
+	versionCreatedAt := make(map[uuid.UUID]time.Time)
+	for _, wb := range q.workspaceBuilds {
+		if wb.Transition != database.WorkspaceTransitionStart {
+			continue
+		}
+		if wb.CreatedAt.Before(arg.StartTime) || !wb.CreatedAt.Before(arg.EndTime) {
+			continue
+		}
+		job, err := q.getProvisionerJobByIDNoLock(ctx, wb.JobID)
+		if err != nil {
+			return nil, err
+		}
+		if !job.CompletedAt.Valid || job.CanceledAt.Valid {
+			continue
+		}
+		w, err := q.getWorkspaceByIDNoLock(ctx, wb.WorkspaceID)
+		if err != nil {
+			return nil, err
+		}
+		if len(arg.TemplateIDs) > 0 && !slices.Contains(arg.TemplateIDs, w.TemplateID) {
+			continue
+		}
+
+		startTime := arg.StartTime.Add(wb.CreatedAt.Sub(arg.StartTime) / interval * interval)
+		key := buildInsightsKey{
+			StartTime:         startTime,
+			TemplateVersionID: wb.TemplateVersionID,
+		}
+		row, ok := rowsByKey[key]
+		if !ok {
+			version, err := q.getTemplateVersionByIDNoLock(ctx, wb.TemplateVersionID)
+			if err != nil {
+				return nil, err
+			}
+			endTime := startTime.Add(interval)
+			if endTime.After(arg.EndTime) {
+				endTime = arg.EndTime
+			}
+			row = &database.GetTemplateVersionBuildInsightsRow{
+				StartTime:           startTime,
+				EndTime:             endTime,
+				TemplateID:          w.TemplateID,
+				TemplateVersionID:   version.ID,
+				TemplateVersionName: version.Name,
+			}
+			rowsByKey[key] = row
+			versionCreatedAt[version.ID] = version.CreatedAt
+		}
+		row.Builds++
+		if job.Error.Valid {
+			row.FailedBuilds++
+			continue
+		}
+		durationsByKey[key] = append(durationsByKey[key], float64(job.CompletedAt.Time.Sub(job.StartedAt.Time).Milliseconds()))
+
+		resources, err := q.getWorkspaceResourcesByJobIDNoLock(ctx, job.ID)
+		if err != nil {
+			return nil, err
+		}
+		resourceIDs := make([]uuid.UUID, 0, len(resources))
+		for _, resource := range resources {
+			resourceIDs = append(resourceIDs, resource.ID)
+		}
+		agents, err := q.getWorkspaceAgentsByResourceIDsNoLock(ctx, resourceIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, agent := range agents {
+			if !agent.FirstConnectedAt.Valid {
+				continue
+			}
+			connectTimesByKey[key] = append(connectTimesByKey[key], float64(agent.FirstConnectedAt.Time.Sub(agent.CreatedAt).Milliseconds()))
+		}
+	}
+
+	rows := make([]database.GetTemplateVersionBuildInsightsRow, 0, len(rowsByKey))
+	for key, row := range rowsByKey {
+		row.BuildDuration50 = percentileCont(durationsByKey[key], 50)
+		row.BuildDuration95 = percentileCont(durationsByKey[key], 95)
+		row.AgentConnect50 = percentileCont(connectTimesByKey[key], 50)
+		row.AgentConnect95 = percentileCont(connectTimesByKey[key], 95)
+		rows = append(rows, *row)
+	}
+	slices.SortFunc(rows, func(a, b database.GetTemplateVersionBuildInsightsRow) int {
+		if !a.StartTime.Equal(b.StartTime) {
+			if a.StartTime.Before(b.StartTime) {
+				return -1
+			}
+			return 1
+		}
+		if a.TemplateID != b.TemplateID {
+			return slice.Ascending(a.TemplateID.String(), b.TemplateID.String())
+		}
+		if a.TemplateVersionID != b.TemplateVersionID {
+			if versionCreatedAt[a.TemplateVersionID].Before(versionCreatedAt[b.TemplateVersionID]) {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	})
+	return rows, nil
+}
+
 func (q *FakeQuerier) GetTemplateVersionByID(ctx context.Context, templateVersionID uuid.UUID) (database.TemplateVersion, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2323,6 +3835,58 @@ func (q *FakeQuerier) GetTemplateVersionParameters(_ context.Context, templateVe
 	return parameters, nil
 }
 
+func (q *FakeQuerier) GetTemplateVersionPresets(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionPreset, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var presets []database.TemplateVersionPreset
+	for _, preset := range q.templateVersionPresets {
+		if preset.TemplateVersionID == templateVersionID {
+			presets = append(presets, preset)
+		}
+	}
+	slices.SortFunc(presets, func(a, b database.TemplateVersionPreset) int {
+		return slice.Ascending(a.Name, b.Name)
+	})
+	return presets, nil
+}
+
+func (q *FakeQuerier) GetTemplateVersionPromotionByID(_ context.Context, id uuid.UUID) (database.TemplateVersionPromotion, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, promotion := range q.templateVersionPromotions {
+		if promotion.ID == id {
+			return promotion, nil
+		}
+	}
+	return database.TemplateVersionPromotion{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetTemplateVersionPromotionsByTemplateID(_ context.Context, arg database.GetTemplateVersionPromotionsByTemplateIDParams) ([]database.TemplateVersionPromotion, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	promotions := make([]database.TemplateVersionPromotion, 0)
+	for _, promotion := range q.templateVersionPromotions {
+		if promotion.TemplateID != arg.TemplateID {
+			continue
+		}
+		if arg.Status != "" && string(promotion.Status) != arg.Status {
+			continue
+		}
+		promotions = append(promotions, promotion)
+	}
+	slices.SortFunc(promotions, func(a, b database.TemplateVersionPromotion) int {
+		return slice.Descending(a.CreatedAt.UnixNano(), b.CreatedAt.UnixNano())
+	})
+	return promotions, nil
+}
+
 func (q *FakeQuerier) GetTemplateVersionVariables(_ context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionVariable, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2451,6 +4015,30 @@ func (q *FakeQuerier) GetTemplates(_ context.Context) ([]database.Template, erro
 	return q.templatesWithUserNoLock(templates), nil
 }
 
+func (q *FakeQuerier) GetTemplatesBySchedulePolicyTemplateID(_ context.Context, schedulePolicyTemplateID uuid.NullUUID) ([]database.Template, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	templates := []database.TemplateTable{}
+	for _, template := range q.templates {
+		if template.Deleted || !schedulePolicyTemplateID.Valid {
+			continue
+		}
+		if template.SchedulePolicyTemplateID != schedulePolicyTemplateID {
+			continue
+		}
+		templates = append(templates, template)
+	}
+	slices.SortFunc(templates, func(a, b database.TemplateTable) int {
+		if a.Name != b.Name {
+			return slice.Ascending(a.Name, b.Name)
+		}
+		return slice.Ascending(a.ID.String(), b.ID.String())
+	})
+
+	return q.templatesWithUserNoLock(templates), nil
+}
+
 func (q *FakeQuerier) GetTemplatesWithFilter(ctx context.Context, arg database.GetTemplatesWithFilterParams) ([]database.Template, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
@@ -2510,6 +4098,18 @@ func (q *FakeQuerier) GetUserCount(_ context.Context) (int64, error) {
 	return existing, nil
 }
 
+func (q *FakeQuerier) GetUserDotfiles(_ context.Context, userID uuid.UUID) (database.UserDotfile, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, dotfiles := range q.userDotfiles {
+		if dotfiles.UserID == userID {
+			return dotfiles, nil
+		}
+	}
+	return database.UserDotfile{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetUserLatencyInsights(_ context.Context, arg database.GetUserLatencyInsightsParams) ([]database.GetUserLatencyInsightsRow, error) {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -2610,6 +4210,54 @@ func (q *FakeQuerier) GetUserLinkByUserIDLoginType(_ context.Context, params dat
 	return database.UserLink{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) GetUserQuietHoursExceptions(_ context.Context, userID uuid.UUID) ([]database.UserQuietHoursException, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	exceptions := make([]database.UserQuietHoursException, 0)
+	for _, exception := range q.userQuietHoursExceptions {
+		if exception.UserID == userID {
+			exceptions = append(exceptions, exception)
+		}
+	}
+	slices.SortFunc(exceptions, func(a, b database.UserQuietHoursException) int {
+		return a.Date.Compare(b.Date)
+	})
+	return exceptions, nil
+}
+
+func (q *FakeQuerier) GetUserSecretByUserIDAndName(_ context.Context, arg database.GetUserSecretByUserIDAndNameParams) (database.UserSecret, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserSecret{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, secret := range q.userSecrets {
+		if secret.UserID == arg.UserID && secret.Name == arg.Name {
+			return secret, nil
+		}
+	}
+	return database.UserSecret{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetUserSecretsByUserID(_ context.Context, userID uuid.UUID) ([]database.UserSecret, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	secrets := make([]database.UserSecret, 0)
+	for _, secret := range q.userSecrets {
+		if secret.UserID == userID {
+			secrets = append(secrets, secret)
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+	return secrets, nil
+}
+
 func (q *FakeQuerier) GetUsers(_ context.Context, params database.GetUsersParams) ([]database.GetUsersRow, error) {
 	if err := validateDatabaseType(params); err != nil {
 		return nil, err
@@ -2777,6 +4425,22 @@ func (q *FakeQuerier) GetWorkspaceAgentByInstanceID(_ context.Context, instanceI
 	return database.WorkspaceAgent{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) GetWorkspaceAgentDevcontainersByAgentID(_ context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentDevcontainer, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	devcontainers := make([]database.WorkspaceAgentDevcontainer, 0)
+	for _, devcontainer := range q.workspaceAgentDevcontainers {
+		if devcontainer.WorkspaceAgentID == workspaceAgentID {
+			devcontainers = append(devcontainers, devcontainer)
+		}
+	}
+	sort.Slice(devcontainers, func(i, j int) bool {
+		return devcontainers[i].WorkspaceFolder < devcontainers[j].WorkspaceFolder
+	})
+	return devcontainers, nil
+}
+
 func (q *FakeQuerier) GetWorkspaceAgentLifecycleStateByID(ctx context.Context, id uuid.UUID) (database.GetWorkspaceAgentLifecycleStateByIDRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -2826,6 +4490,38 @@ func (q *FakeQuerier) GetWorkspaceAgentMetadata(_ context.Context, workspaceAgen
 	return metadata, nil
 }
 
+func (q *FakeQuerier) GetWorkspaceAgentScriptsByAgentID(_ context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentScript, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	scripts := make([]database.WorkspaceAgentScript, 0)
+	for _, script := range q.workspaceAgentScripts {
+		if script.WorkspaceAgentID == workspaceAgentID {
+			scripts = append(scripts, script)
+		}
+	}
+	sort.Slice(scripts, func(i, j int) bool {
+		return scripts[i].Ordinal < scripts[j].Ordinal
+	})
+	return scripts, nil
+}
+
+func (q *FakeQuerier) GetWorkspaceAgentSecretsByAgentID(_ context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentSecret, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	secrets := make([]database.WorkspaceAgentSecret, 0)
+	for _, secret := range q.workspaceAgentSecrets {
+		if secret.WorkspaceAgentID == workspaceAgentID {
+			secrets = append(secrets, secret)
+		}
+	}
+	sort.Slice(secrets, func(i, j int) bool {
+		return secrets[i].Name < secrets[j].Name
+	})
+	return secrets, nil
+}
+
 func (q *FakeQuerier) GetWorkspaceAgentStats(_ context.Context, createdAfter time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -3091,6 +4787,53 @@ func (q *FakeQuerier) GetWorkspaceAppsCreatedAfter(_ context.Context, after time
 	return apps, nil
 }
 
+func (q *FakeQuerier) GetWorkspaceBatchByID(_ context.Context, id uuid.UUID) (database.WorkspaceBatch, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, batch := range q.workspaceBatches {
+		if batch.ID == id {
+			return batch, nil
+		}
+	}
+	return database.WorkspaceBatch{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) GetWorkspaceBatchWorkspacesByBatchID(ctx context.Context, batchID uuid.UUID) ([]database.GetWorkspaceBatchWorkspacesByBatchIDRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	rows := make([]database.GetWorkspaceBatchWorkspacesByBatchIDRow, 0)
+	for _, batchWorkspace := range q.workspaceBatchWorkspaces {
+		if batchWorkspace.BatchID != batchID {
+			continue
+		}
+		workspace, err := q.getWorkspaceByIDNoLock(ctx, batchWorkspace.WorkspaceID)
+		if err != nil {
+			continue
+		}
+		owner, err := q.getUserByIDNoLock(workspace.OwnerID)
+		if err != nil {
+			continue
+		}
+		rows = append(rows, database.GetWorkspaceBatchWorkspacesByBatchIDRow{
+			BatchID:                batchWorkspace.BatchID,
+			WorkspaceID:            batchWorkspace.WorkspaceID,
+			Position:               batchWorkspace.Position,
+			Status:                 batchWorkspace.Status,
+			BuildID:                batchWorkspace.BuildID,
+			Error:                  batchWorkspace.Error,
+			UpdatedAt:              batchWorkspace.UpdatedAt,
+			WorkspaceName:          workspace.Name,
+			WorkspaceOwnerUsername: owner.Username,
+		})
+	}
+	slices.SortFunc(rows, func(a, b database.GetWorkspaceBatchWorkspacesByBatchIDRow) int {
+		return int(a.Position - b.Position)
+	})
+	return rows, nil
+}
+
 func (q *FakeQuerier) GetWorkspaceBuildByID(ctx context.Context, id uuid.UUID) (database.WorkspaceBuild, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -3282,6 +5025,40 @@ func (q *FakeQuerier) GetWorkspaceByWorkspaceAppID(_ context.Context, workspaceA
 	return database.Workspace{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) GetWorkspaceDeadlineExtensionCount(_ context.Context, arg database.GetWorkspaceDeadlineExtensionCountParams) (int64, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return 0, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var count int64
+	for _, extension := range q.workspaceDeadlineExtensions {
+		if extension.WorkspaceID == arg.WorkspaceID && extension.CreatedAt.After(arg.CreatedAt) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (q *FakeQuerier) GetWorkspaceNameRedirect(_ context.Context, arg database.GetWorkspaceNameRedirectParams) (database.WorkspaceNameRedirect, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceNameRedirect{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	now := database.Now()
+	for _, redirect := range q.workspaceNameRedirects {
+		if redirect.OwnerID == arg.OwnerID && redirect.Name == arg.Name && redirect.ExpiresAt.After(now) {
+			return redirect, nil
+		}
+	}
+	return database.WorkspaceNameRedirect{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetWorkspaceProxies(_ context.Context) ([]database.WorkspaceProxy, error) {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -3446,6 +5223,18 @@ func (q *FakeQuerier) GetWorkspaceResourcesCreatedAfter(_ context.Context, after
 	return resources, nil
 }
 
+func (q *FakeQuerier) GetWorkspaceScheduleOverrideByWorkspaceID(_ context.Context, workspaceID uuid.UUID) (database.WorkspaceScheduleOverride, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, override := range q.workspaceScheduleOverrides {
+		if override.WorkspaceID == workspaceID {
+			return override, nil
+		}
+	}
+	return database.WorkspaceScheduleOverride{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) GetWorkspaces(ctx context.Context, arg database.GetWorkspacesParams) ([]database.GetWorkspacesRow, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
@@ -3456,56 +5245,132 @@ func (q *FakeQuerier) GetWorkspaces(ctx context.Context, arg database.GetWorkspa
 	return workspaceRows, err
 }
 
-func (q *FakeQuerier) GetWorkspacesEligibleForTransition(ctx context.Context, now time.Time) ([]database.Workspace, error) {
+func (q *FakeQuerier) GetWorkspacesByIDs(_ context.Context, ids []uuid.UUID) ([]database.Workspace, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	workspaces := make([]database.Workspace, 0)
+	for _, workspace := range q.workspaces {
+		if slices.Contains(ids, workspace.ID) {
+			workspaces = append(workspaces, workspace)
+		}
+	}
+	return workspaces, nil
+}
+
+func (q *FakeQuerier) GetWorkspacesEligibleForTransition(ctx context.Context, now time.Time) ([]database.Workspace, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	workspaces := []database.Workspace{}
+	for _, workspace := range q.workspaces {
+		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if build.Transition == database.WorkspaceTransitionStart &&
+			!build.Deadline.IsZero() &&
+			build.Deadline.Before(now) &&
+			!workspace.LockedAt.Valid {
+			workspaces = append(workspaces, workspace)
+			continue
+		}
+
+		if build.Transition == database.WorkspaceTransitionStop &&
+			workspace.AutostartSchedule.Valid &&
+			!workspace.LockedAt.Valid {
+			workspaces = append(workspaces, workspace)
+			continue
+		}
+
+		job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
+		if err != nil {
+			return nil, xerrors.Errorf("get provisioner job by ID: %w", err)
+		}
+		if db2sdk.ProvisionerJobStatus(job) == codersdk.ProvisionerJobFailed {
+			workspaces = append(workspaces, workspace)
+			continue
+		}
+
+		template, err := q.GetTemplateByID(ctx, workspace.TemplateID)
+		if err != nil {
+			return nil, xerrors.Errorf("get template by ID: %w", err)
+		}
+		if build.Transition == database.WorkspaceTransitionStop &&
+			build.Reason == database.BuildReasonRestartRequirement &&
+			template.UpdateOnRestart &&
+			!workspace.LockedAt.Valid &&
+			db2sdk.ProvisionerJobStatus(job) == codersdk.ProvisionerJobSucceeded {
+			workspaces = append(workspaces, workspace)
+			continue
+		}
+		if !workspace.LockedAt.Valid && template.InactivityTTL > 0 {
+			workspaces = append(workspaces, workspace)
+			continue
+		}
+		if workspace.LockedAt.Valid && template.LockedTTL > 0 {
+			workspaces = append(workspaces, workspace)
+			continue
+		}
+		if !workspace.LockedAt.Valid && template.MaxLifetime > 0 {
+			workspaces = append(workspaces, workspace)
+			continue
+		}
+	}
+
+	return workspaces, nil
+}
+
+func (q *FakeQuerier) GetWorkspacesWithImminentAutostop(ctx context.Context, arg database.GetWorkspacesWithImminentAutostopParams) ([]database.GetWorkspacesWithImminentAutostopRow, error) {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return nil, err
+	}
+
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
-	workspaces := []database.Workspace{}
+	rows := []database.GetWorkspacesWithImminentAutostopRow{}
 	for _, workspace := range q.workspaces {
+		if workspace.Deleted || workspace.LockedAt.Valid {
+			continue
+		}
 		build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
 		if err != nil {
 			return nil, err
 		}
-
-		if build.Transition == database.WorkspaceTransitionStart &&
-			!build.Deadline.IsZero() &&
-			build.Deadline.Before(now) &&
-			!workspace.LockedAt.Valid {
-			workspaces = append(workspaces, workspace)
-			continue
-		}
-
-		if build.Transition == database.WorkspaceTransitionStop &&
-			workspace.AutostartSchedule.Valid &&
-			!workspace.LockedAt.Valid {
-			workspaces = append(workspaces, workspace)
+		if build.Transition != database.WorkspaceTransitionStart ||
+			!build.Deadline.After(arg.Now) ||
+			build.Deadline.After(arg.Before) {
 			continue
 		}
-
 		job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
 		if err != nil {
 			return nil, xerrors.Errorf("get provisioner job by ID: %w", err)
 		}
-		if db2sdk.ProvisionerJobStatus(job) == codersdk.ProvisionerJobFailed {
-			workspaces = append(workspaces, workspace)
+		if !job.CompletedAt.Valid || job.Error.String != "" {
 			continue
 		}
-
-		template, err := q.GetTemplateByID(ctx, workspace.TemplateID)
+		owner, err := q.getUserByIDNoLock(workspace.OwnerID)
 		if err != nil {
-			return nil, xerrors.Errorf("get template by ID: %w", err)
-		}
-		if !workspace.LockedAt.Valid && template.InactivityTTL > 0 {
-			workspaces = append(workspaces, workspace)
-			continue
+			return nil, xerrors.Errorf("get user by ID: %w", err)
 		}
-		if workspace.LockedAt.Valid && template.LockedTTL > 0 {
-			workspaces = append(workspaces, workspace)
-			continue
+		template, err := q.getTemplateByIDNoLock(ctx, workspace.TemplateID)
+		if err != nil {
+			return nil, xerrors.Errorf("get template by ID: %w", err)
 		}
+		rows = append(rows, database.GetWorkspacesWithImminentAutostopRow{
+			Workspace:     workspace,
+			BuildID:       build.ID,
+			BuildDeadline: build.Deadline,
+			OwnerUsername: owner.Username,
+			OwnerEmail:    owner.Email,
+			TemplateName:  template.Name,
+		})
 	}
 
-	return workspaces, nil
+	return rows, nil
 }
 
 func (q *FakeQuerier) InsertAPIKey(_ context.Context, arg database.InsertAPIKeyParams) (database.APIKey, error) {
@@ -3578,6 +5443,33 @@ func (q *FakeQuerier) InsertAuditLog(_ context.Context, arg database.InsertAudit
 	return alog, nil
 }
 
+func (q *FakeQuerier) InsertCustomRole(_ context.Context, arg database.InsertCustomRoleParams) (database.CustomRole, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.CustomRole{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, role := range q.customRoles {
+		if role.Name == arg.Name {
+			return database.CustomRole{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	role := database.CustomRole{
+		Name:            arg.Name,
+		DisplayName:     arg.DisplayName,
+		SitePermissions: arg.SitePermissions,
+		UserPermissions: arg.UserPermissions,
+		CreatedAt:       arg.CreatedAt,
+		UpdatedAt:       arg.UpdatedAt,
+	}
+	q.customRoles = append(q.customRoles, role)
+	return role, nil
+}
+
 func (q *FakeQuerier) InsertDERPMeshKey(_ context.Context, id string) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -3848,6 +5740,8 @@ func (q *FakeQuerier) InsertProvisionerJob(_ context.Context, arg database.Inser
 		Type:           arg.Type,
 		Input:          arg.Input,
 		Tags:           arg.Tags,
+		Priority:       arg.Priority,
+		NotBefore:      arg.NotBefore,
 	}
 	q.provisionerJobs = append(q.provisionerJobs, job)
 	return job, nil
@@ -3876,12 +5770,44 @@ func (q *FakeQuerier) InsertProvisionerJobLogs(_ context.Context, arg database.I
 			Level:     arg.Level[index],
 			Stage:     arg.Stage[index],
 			Output:    output,
+
+			ProgressStage:     arg.ProgressStage[index],
+			ProgressResource:  arg.ProgressResource[index],
+			ProgressCompleted: arg.ProgressCompleted[index],
+			ProgressTotal:     arg.ProgressTotal[index],
 		})
 	}
 	q.provisionerJobLogs = append(q.provisionerJobLogs, logs...)
 	return logs, nil
 }
 
+func (q *FakeQuerier) InsertProvisionerKey(_ context.Context, arg database.InsertProvisionerKeyParams) (database.ProvisionerKey, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.ProvisionerKey{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, key := range q.provisionerKeys {
+		if key.OrganizationID == arg.OrganizationID && strings.EqualFold(key.Name, arg.Name) {
+			return database.ProvisionerKey{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	key := database.ProvisionerKey{
+		ID:             arg.ID,
+		CreatedAt:      arg.CreatedAt,
+		OrganizationID: arg.OrganizationID,
+		Name:           arg.Name,
+		HashedSecret:   arg.HashedSecret,
+		Tags:           arg.Tags,
+	}
+	q.provisionerKeys = append(q.provisionerKeys, key)
+	return key, nil
+}
+
 func (q *FakeQuerier) InsertReplica(_ context.Context, arg database.InsertReplicaParams) (database.Replica, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.Replica{}, err
@@ -3906,6 +5832,59 @@ func (q *FakeQuerier) InsertReplica(_ context.Context, arg database.InsertReplic
 	return replica, nil
 }
 
+func (q *FakeQuerier) InsertSavedWorkspaceFilter(_ context.Context, arg database.InsertSavedWorkspaceFilterParams) (database.SavedWorkspaceFilter, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.SavedWorkspaceFilter{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, filter := range q.savedWorkspaceFilters {
+		if filter.UserID == arg.UserID && filter.Name == arg.Name {
+			return database.SavedWorkspaceFilter{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	filter := database.SavedWorkspaceFilter{
+		ID:        arg.ID,
+		UserID:    arg.UserID,
+		Name:      arg.Name,
+		Query:     arg.Query,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+	}
+	q.savedWorkspaceFilters = append(q.savedWorkspaceFilters, filter)
+	return filter, nil
+}
+
+func (q *FakeQuerier) InsertScheduleHoliday(_ context.Context, arg database.InsertScheduleHolidayParams) (database.ScheduleHoliday, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.ScheduleHoliday{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	date := truncateToDate(arg.Date)
+	for _, holiday := range q.scheduleHolidays {
+		if holiday.Date.Equal(date) {
+			return database.ScheduleHoliday{}, errDuplicateKey
+		}
+	}
+
+	holiday := database.ScheduleHoliday{
+		ID:        arg.ID,
+		CreatedAt: arg.CreatedAt,
+		UpdatedAt: arg.UpdatedAt,
+		Date:      date,
+		Name:      arg.Name,
+	}
+	q.scheduleHolidays = append(q.scheduleHolidays, holiday)
+	return holiday, nil
+}
+
 func (q *FakeQuerier) InsertTemplate(_ context.Context, arg database.InsertTemplateParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -3932,11 +5911,39 @@ func (q *FakeQuerier) InsertTemplate(_ context.Context, arg database.InsertTempl
 		AllowUserCancelWorkspaceJobs: arg.AllowUserCancelWorkspaceJobs,
 		AllowUserAutostart:           true,
 		AllowUserAutostop:            true,
+		ApplyDotfiles:                true,
 	}
 	q.templates = append(q.templates, template)
 	return nil
 }
 
+func (q *FakeQuerier) InsertTemplateDeadlineRecalculation(_ context.Context, arg database.InsertTemplateDeadlineRecalculationParams) (database.TemplateDeadlineRecalculation, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateDeadlineRecalculation{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, recalculation := range q.templateDeadlineRecalculations {
+		if recalculation.TemplateID == arg.TemplateID && recalculation.Status == database.TemplateDeadlineRecalculationStatusPending {
+			q.templateDeadlineRecalculations[i].UpdatedAt = arg.CreatedAt
+			return q.templateDeadlineRecalculations[i], nil
+		}
+	}
+
+	//nolint:gosimple
+	recalculation := database.TemplateDeadlineRecalculation{
+		ID:         arg.ID,
+		TemplateID: arg.TemplateID,
+		Status:     database.TemplateDeadlineRecalculationStatusPending,
+		CreatedAt:  arg.CreatedAt,
+		UpdatedAt:  arg.CreatedAt,
+	}
+	q.templateDeadlineRecalculations = append(q.templateDeadlineRecalculations, recalculation)
+	return recalculation, nil
+}
+
 func (q *FakeQuerier) InsertTemplateVersion(_ context.Context, arg database.InsertTemplateVersionParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -3998,6 +6005,60 @@ func (q *FakeQuerier) InsertTemplateVersionParameter(_ context.Context, arg data
 	return param, nil
 }
 
+func (q *FakeQuerier) InsertTemplateVersionPreset(_ context.Context, arg database.InsertTemplateVersionPresetParams) (database.TemplateVersionPreset, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateVersionPreset{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, preset := range q.templateVersionPresets {
+		if preset.TemplateVersionID == arg.TemplateVersionID && preset.Name == arg.Name {
+			return database.TemplateVersionPreset{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	preset := database.TemplateVersionPreset{
+		TemplateVersionID: arg.TemplateVersionID,
+		Name:              arg.Name,
+		Description:       arg.Description,
+		Parameters:        arg.Parameters,
+		CreatedAt:         arg.CreatedAt,
+	}
+	q.templateVersionPresets = append(q.templateVersionPresets, preset)
+	return preset, nil
+}
+
+func (q *FakeQuerier) InsertTemplateVersionPromotion(_ context.Context, arg database.InsertTemplateVersionPromotionParams) (database.TemplateVersionPromotion, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, promotion := range q.templateVersionPromotions {
+		if promotion.TemplateVersionID == arg.TemplateVersionID && promotion.Status == database.TemplateVersionPromotionStatusPending && arg.Status == database.TemplateVersionPromotionStatusPending {
+			return database.TemplateVersionPromotion{}, errDuplicateKey
+		}
+	}
+
+	promotion := database.TemplateVersionPromotion{
+		ID:                arg.ID,
+		TemplateID:        arg.TemplateID,
+		TemplateVersionID: arg.TemplateVersionID,
+		RequestedBy:       arg.RequestedBy,
+		CreatedAt:         arg.CreatedAt,
+		UpdatedAt:         arg.UpdatedAt,
+		Status:            arg.Status,
+		ApprovedBy:        []uuid.UUID{},
+	}
+	q.templateVersionPromotions = append(q.templateVersionPromotions, promotion)
+	return promotion, nil
+}
+
 func (q *FakeQuerier) InsertTemplateVersionVariable(_ context.Context, arg database.InsertTemplateVersionVariableParams) (database.TemplateVersionVariable, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.TemplateVersionVariable{}, err
@@ -4071,6 +6132,9 @@ func (q *FakeQuerier) InsertUserGroupsByName(_ context.Context, arg database.Ins
 
 	var groupIDs []uuid.UUID
 	for _, group := range q.groups {
+		if group.OrganizationID != arg.OrganizationID || group.Source == database.GroupSourceScim {
+			continue
+		}
 		for _, groupName := range arg.GroupNames {
 			if group.Name == groupName {
 				groupIDs = append(groupIDs, group.ID)
@@ -4107,6 +6171,58 @@ func (q *FakeQuerier) InsertUserLink(_ context.Context, args database.InsertUser
 	return link, nil
 }
 
+func (q *FakeQuerier) InsertUserQuietHoursException(_ context.Context, arg database.InsertUserQuietHoursExceptionParams) (database.UserQuietHoursException, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserQuietHoursException{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	date := truncateToDate(arg.Date)
+	for _, exception := range q.userQuietHoursExceptions {
+		if exception.UserID == arg.UserID && exception.Date.Equal(date) {
+			return database.UserQuietHoursException{}, errDuplicateKey
+		}
+	}
+
+	exception := database.UserQuietHoursException{
+		UserID:    arg.UserID,
+		Date:      date,
+		CreatedAt: arg.CreatedAt,
+	}
+	q.userQuietHoursExceptions = append(q.userQuietHoursExceptions, exception)
+	return exception, nil
+}
+
+func (q *FakeQuerier) InsertUserSecret(_ context.Context, arg database.InsertUserSecretParams) (database.UserSecret, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserSecret{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, secret := range q.userSecrets {
+		if secret.UserID == arg.UserID && secret.Name == arg.Name {
+			return database.UserSecret{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	secret := database.UserSecret{
+		ID:          arg.ID,
+		UserID:      arg.UserID,
+		Name:        arg.Name,
+		Description: arg.Description,
+		Value:       arg.Value,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+	}
+	q.userSecrets = append(q.userSecrets, secret)
+	return secret, nil
+}
+
 func (q *FakeQuerier) InsertWorkspace(_ context.Context, arg database.InsertWorkspaceParams) (database.Workspace, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.Workspace{}, err
@@ -4127,6 +6243,7 @@ func (q *FakeQuerier) InsertWorkspace(_ context.Context, arg database.InsertWork
 		AutostartSchedule: arg.AutostartSchedule,
 		Ttl:               arg.Ttl,
 		LastUsedAt:        arg.LastUsedAt,
+		GroupACL:          database.WorkspaceACL{},
 	}
 	q.workspaces = append(q.workspaces, workspace)
 	return workspace, nil
@@ -4167,6 +6284,28 @@ func (q *FakeQuerier) InsertWorkspaceAgent(_ context.Context, arg database.Inser
 	return agent, nil
 }
 
+func (q *FakeQuerier) InsertWorkspaceAgentDevcontainer(_ context.Context, arg database.InsertWorkspaceAgentDevcontainerParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, devcontainer := range q.workspaceAgentDevcontainers {
+		if devcontainer.WorkspaceAgentID == arg.WorkspaceAgentID && devcontainer.WorkspaceFolder == arg.WorkspaceFolder {
+			return errDuplicateKey
+		}
+	}
+
+	q.workspaceAgentDevcontainers = append(q.workspaceAgentDevcontainers, database.WorkspaceAgentDevcontainer{
+		WorkspaceAgentID: arg.WorkspaceAgentID,
+		WorkspaceFolder:  arg.WorkspaceFolder,
+		ConfigPath:       arg.ConfigPath,
+	})
+	return nil
+}
+
 func (q *FakeQuerier) InsertWorkspaceAgentLogs(_ context.Context, arg database.InsertWorkspaceAgentLogsParams) ([]database.WorkspaceAgentLog, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
@@ -4230,6 +6369,55 @@ func (q *FakeQuerier) InsertWorkspaceAgentMetadata(_ context.Context, arg databa
 	return nil
 }
 
+func (q *FakeQuerier) InsertWorkspaceAgentScript(_ context.Context, arg database.InsertWorkspaceAgentScriptParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, script := range q.workspaceAgentScripts {
+		if script.WorkspaceAgentID == arg.WorkspaceAgentID && script.Name == arg.Name {
+			return errDuplicateKey
+		}
+	}
+
+	q.workspaceAgentScripts = append(q.workspaceAgentScripts, database.WorkspaceAgentScript{
+		WorkspaceAgentID: arg.WorkspaceAgentID,
+		Name:             arg.Name,
+		Ordinal:          arg.Ordinal,
+		Script:           arg.Script,
+		RunOnStart:       arg.RunOnStart,
+		RunOnStop:        arg.RunOnStop,
+		TimeoutSeconds:   arg.TimeoutSeconds,
+		ContinueOnError:  arg.ContinueOnError,
+		Status:           database.WorkspaceAgentScriptStatusPending,
+	})
+	return nil
+}
+
+func (q *FakeQuerier) InsertWorkspaceAgentSecret(_ context.Context, arg database.InsertWorkspaceAgentSecretParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, secret := range q.workspaceAgentSecrets {
+		if secret.WorkspaceAgentID == arg.WorkspaceAgentID && secret.Name == arg.Name {
+			return errDuplicateKey
+		}
+	}
+
+	q.workspaceAgentSecrets = append(q.workspaceAgentSecrets, database.WorkspaceAgentSecret{
+		WorkspaceAgentID: arg.WorkspaceAgentID,
+		Name:             arg.Name,
+	})
+	return nil
+}
+
 func (q *FakeQuerier) InsertWorkspaceAgentStat(_ context.Context, p database.InsertWorkspaceAgentStatParams) (database.WorkspaceAgentStat, error) {
 	if err := validateDatabaseType(p); err != nil {
 		return database.WorkspaceAgentStat{}, err
@@ -4256,6 +6444,8 @@ func (q *FakeQuerier) InsertWorkspaceAgentStat(_ context.Context, p database.Ins
 		SessionCountReconnectingPTY: p.SessionCountReconnectingPTY,
 		SessionCountSSH:             p.SessionCountSSH,
 		ConnectionMedianLatencyMS:   p.ConnectionMedianLatencyMS,
+		PeerCountP2P:                p.PeerCountP2P,
+		PeerCountDERP:               p.PeerCountDERP,
 	}
 	q.workspaceAgentStats = append(q.workspaceAgentStats, stat)
 	return stat, nil
@@ -4297,6 +6487,8 @@ func (q *FakeQuerier) InsertWorkspaceAgentStats(_ context.Context, arg database.
 			SessionCountReconnectingPTY: arg.SessionCountReconnectingPTY[i],
 			SessionCountSSH:             arg.SessionCountSSH[i],
 			ConnectionMedianLatencyMS:   arg.ConnectionMedianLatencyMS[i],
+			PeerCountP2P:                arg.PeerCountP2P[i],
+			PeerCountDERP:               arg.PeerCountDERP[i],
 		}
 		q.workspaceAgentStats = append(q.workspaceAgentStats, stat)
 	}
@@ -4315,29 +6507,63 @@ func (q *FakeQuerier) InsertWorkspaceApp(_ context.Context, arg database.InsertW
 	if arg.SharingLevel == "" {
 		arg.SharingLevel = database.AppSharingLevelOwner
 	}
+	if arg.Auth == "" {
+		arg.Auth = database.WorkspaceAppAuthCoder
+	}
 
 	// nolint:gosimple
 	workspaceApp := database.WorkspaceApp{
-		ID:                   arg.ID,
-		AgentID:              arg.AgentID,
-		CreatedAt:            arg.CreatedAt,
-		Slug:                 arg.Slug,
-		DisplayName:          arg.DisplayName,
-		Icon:                 arg.Icon,
-		Command:              arg.Command,
-		Url:                  arg.Url,
-		External:             arg.External,
-		Subdomain:            arg.Subdomain,
-		SharingLevel:         arg.SharingLevel,
-		HealthcheckUrl:       arg.HealthcheckUrl,
-		HealthcheckInterval:  arg.HealthcheckInterval,
-		HealthcheckThreshold: arg.HealthcheckThreshold,
-		Health:               arg.Health,
+		ID:                         arg.ID,
+		AgentID:                    arg.AgentID,
+		CreatedAt:                  arg.CreatedAt,
+		Slug:                       arg.Slug,
+		DisplayName:                arg.DisplayName,
+		Icon:                       arg.Icon,
+		Command:                    arg.Command,
+		Url:                        arg.Url,
+		External:                   arg.External,
+		Subdomain:                  arg.Subdomain,
+		SharingLevel:               arg.SharingLevel,
+		HealthcheckUrl:             arg.HealthcheckUrl,
+		HealthcheckInterval:        arg.HealthcheckInterval,
+		HealthcheckThreshold:       arg.HealthcheckThreshold,
+		Health:                     arg.Health,
+		Auth:                       arg.Auth,
+		RateLimitRequestsPerMinute: arg.RateLimitRequestsPerMinute,
+		RateLimitBurst:             arg.RateLimitBurst,
+		RewritePaths:               arg.RewritePaths,
 	}
 	q.workspaceApps = append(q.workspaceApps, workspaceApp)
 	return workspaceApp, nil
 }
 
+func (q *FakeQuerier) InsertWorkspaceAppAccessLogs(_ context.Context, arg database.InsertWorkspaceAppAccessLogsParams) error {
+	err := validateDatabaseType(arg)
+	if err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i := 0; i < len(arg.WorkspaceID); i++ {
+		q.workspaceAppAccessLogs = append(q.workspaceAppAccessLogs, database.WorkspaceAppAccessLog{
+			ID:           int64(len(q.workspaceAppAccessLogs)) + 1,
+			CreatedAt:    arg.CreatedAt[i],
+			UserID:       uuid.NullUUID{UUID: arg.UserID[i], Valid: arg.UserID[i] != uuid.Nil},
+			WorkspaceID:  arg.WorkspaceID[i],
+			AgentID:      arg.AgentID[i],
+			AccessMethod: arg.AccessMethod[i],
+			SlugOrPort:   arg.SlugOrPort[i],
+			PathPrefix:   arg.PathPrefix[i],
+			StatusCode:   arg.StatusCode[i],
+			LatencyMS:    arg.LatencyMS[i],
+		})
+	}
+
+	return nil
+}
+
 func (q *FakeQuerier) InsertWorkspaceAppStats(_ context.Context, arg database.InsertWorkspaceAppStatsParams) error {
 	err := validateDatabaseType(arg)
 	if err != nil {
@@ -4360,12 +6586,14 @@ InsertWorkspaceAppStatsLoop:
 			SessionStartedAt: arg.SessionStartedAt[i],
 			SessionEndedAt:   arg.SessionEndedAt[i],
 			Requests:         arg.Requests[i],
+			ActiveDurationMS: arg.ActiveDurationMS[i],
 		}
 		for j, s := range q.workspaceAppStats {
 			// Check unique constraint for upsert.
 			if s.UserID == stat.UserID && s.AgentID == stat.AgentID && s.SessionID == stat.SessionID {
 				q.workspaceAppStats[j].SessionEndedAt = stat.SessionEndedAt
 				q.workspaceAppStats[j].Requests = stat.Requests
+				q.workspaceAppStats[j].ActiveDurationMS = stat.ActiveDurationMS
 				continue InsertWorkspaceAppStatsLoop
 			}
 		}
@@ -4376,6 +6604,47 @@ InsertWorkspaceAppStatsLoop:
 	return nil
 }
 
+func (q *FakeQuerier) InsertWorkspaceBatch(_ context.Context, arg database.InsertWorkspaceBatchParams) (database.WorkspaceBatch, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceBatch{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	batch := database.WorkspaceBatch{
+		ID:          arg.ID,
+		InitiatorID: arg.InitiatorID,
+		Operation:   arg.Operation,
+		Query:       arg.Query,
+		Status:      database.WorkspaceBatchStatusPending,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.CreatedAt,
+	}
+	q.workspaceBatches = append(q.workspaceBatches, batch)
+	return batch, nil
+}
+
+func (q *FakeQuerier) InsertWorkspaceBatchWorkspaces(_ context.Context, arg database.InsertWorkspaceBatchWorkspacesParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, workspaceID := range arg.WorkspaceIds {
+		q.workspaceBatchWorkspaces = append(q.workspaceBatchWorkspaces, database.WorkspaceBatchWorkspace{
+			BatchID:     arg.BatchID,
+			WorkspaceID: workspaceID,
+			Position:    int32(i + 1),
+			Status:      database.WorkspaceBatchWorkspaceStatusPending,
+			UpdatedAt:   arg.UpdatedAt,
+		})
+	}
+	return nil
+}
+
 func (q *FakeQuerier) InsertWorkspaceBuild(_ context.Context, arg database.InsertWorkspaceBuildParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -4420,6 +6689,26 @@ func (q *FakeQuerier) InsertWorkspaceBuildParameters(_ context.Context, arg data
 	return nil
 }
 
+func (q *FakeQuerier) InsertWorkspaceDeadlineExtension(_ context.Context, arg database.InsertWorkspaceDeadlineExtensionParams) (database.WorkspaceDeadlineExtension, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceDeadlineExtension{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	extension := database.WorkspaceDeadlineExtension{
+		ID:               arg.ID,
+		WorkspaceID:      arg.WorkspaceID,
+		WorkspaceBuildID: arg.WorkspaceBuildID,
+		UserID:           arg.UserID,
+		CreatedAt:        arg.CreatedAt,
+		Duration:         arg.Duration,
+	}
+	q.workspaceDeadlineExtensions = append(q.workspaceDeadlineExtensions, extension)
+	return extension, nil
+}
+
 func (q *FakeQuerier) InsertWorkspaceProxy(_ context.Context, arg database.InsertWorkspaceProxyParams) (database.WorkspaceProxy, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -4528,6 +6817,29 @@ func (*FakeQuerier) TryAcquireLock(_ context.Context, _ int64) (bool, error) {
 	return false, xerrors.New("TryAcquireLock must only be called within a transaction")
 }
 
+func (q *FakeQuerier) RotateWorkspaceProxyToken(_ context.Context, arg database.RotateWorkspaceProxyTokenParams) (database.WorkspaceProxy, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceProxy{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, p := range q.workspaceProxies {
+		if p.ID != arg.ID || p.Deleted || !bytes.Equal(p.TokenHashedSecret, arg.CurrentTokenHashedSecret) {
+			continue
+		}
+		p.PreviousTokenHashedSecret = p.TokenHashedSecret
+		p.PreviousTokenExpiresAt = sql.NullTime{Time: arg.PreviousTokenExpiresAt, Valid: true}
+		p.TokenHashedSecret = arg.TokenHashedSecret
+		p.TokenExpiresAt = sql.NullTime{Time: arg.TokenExpiresAt, Valid: true}
+		p.UpdatedAt = database.Now()
+		q.workspaceProxies[i] = p
+		return p, nil
+	}
+	return database.WorkspaceProxy{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateAPIKeyByID(_ context.Context, arg database.UpdateAPIKeyByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -4549,6 +6861,28 @@ func (q *FakeQuerier) UpdateAPIKeyByID(_ context.Context, arg database.UpdateAPI
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateCustomRole(_ context.Context, arg database.UpdateCustomRoleParams) (database.CustomRole, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.CustomRole{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, role := range q.customRoles {
+		if role.Name != arg.Name {
+			continue
+		}
+		role.DisplayName = arg.DisplayName
+		role.SitePermissions = arg.SitePermissions
+		role.UserPermissions = arg.UserPermissions
+		role.UpdatedAt = arg.UpdatedAt
+		q.customRoles[i] = role
+		return role, nil
+	}
+	return database.CustomRole{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateGitAuthLink(_ context.Context, arg database.UpdateGitAuthLinkParams) (database.GitAuthLink, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.GitAuthLink{}, err
@@ -4669,6 +7003,49 @@ func (q *FakeQuerier) UpdateMemberRoles(_ context.Context, arg database.UpdateMe
 	return database.OrganizationMember{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateOrganizationBudgetAlertedThreshold(_ context.Context, arg database.UpdateOrganizationBudgetAlertedThresholdParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, settings := range q.organizationBudgetSettings {
+		if settings.OrganizationID != arg.OrganizationID {
+			continue
+		}
+		settings.AlertedThresholdPercent = arg.AlertedThresholdPercent
+		q.organizationBudgetSettings[i] = settings
+		return nil
+	}
+	return nil
+}
+
+func (q *FakeQuerier) UpdateProvisionerDaemonCacheStats(_ context.Context, arg database.UpdateProvisionerDaemonCacheStatsParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, daemon := range q.provisionerDaemons {
+		if daemon.ID != arg.ID {
+			continue
+		}
+		daemon.UpdatedAt = arg.UpdatedAt
+		daemon.CacheEntries = arg.CacheEntries
+		daemon.CacheSizeBytes = arg.CacheSizeBytes
+		daemon.CacheMaxSizeBytes = arg.CacheMaxSizeBytes
+		daemon.CacheHits = arg.CacheHits
+		daemon.CacheMisses = arg.CacheMisses
+		q.provisionerDaemons[index] = daemon
+		return nil
+	}
+	return nil
+}
+
 func (q *FakeQuerier) UpdateProvisionerJobByID(_ context.Context, arg database.UpdateProvisionerJobByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -4688,6 +7065,26 @@ func (q *FakeQuerier) UpdateProvisionerJobByID(_ context.Context, arg database.U
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateProvisionerJobNotBeforeByID(_ context.Context, arg database.UpdateProvisionerJobNotBeforeByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, job := range q.provisionerJobs {
+		if arg.ID != job.ID {
+			continue
+		}
+		job.UpdatedAt = arg.UpdatedAt
+		job.NotBefore = arg.NotBefore
+		q.provisionerJobs[index] = job
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateProvisionerJobWithCancelByID(_ context.Context, arg database.UpdateProvisionerJobWithCancelByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -4730,6 +7127,24 @@ func (q *FakeQuerier) UpdateProvisionerJobWithCompleteByID(_ context.Context, ar
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateProvisionerKeyLastUsedAt(_ context.Context, arg database.UpdateProvisionerKeyLastUsedAtParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, key := range q.provisionerKeys {
+		if key.ID != arg.ID {
+			continue
+		}
+		key.LastUsedAt = arg.LastUsedAt
+		q.provisionerKeys[index] = key
+	}
+	return nil
+}
+
 func (q *FakeQuerier) UpdateReplica(_ context.Context, arg database.UpdateReplicaParams) (database.Replica, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.Replica{}, err
@@ -4758,6 +7173,54 @@ func (q *FakeQuerier) UpdateReplica(_ context.Context, arg database.UpdateReplic
 	return database.Replica{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateSavedWorkspaceFilter(_ context.Context, arg database.UpdateSavedWorkspaceFilterParams) (database.SavedWorkspaceFilter, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.SavedWorkspaceFilter{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, filter := range q.savedWorkspaceFilters {
+		if filter.UserID != arg.UserID || filter.Name != arg.Name {
+			continue
+		}
+		filter.Query = arg.Query
+		filter.UpdatedAt = arg.UpdatedAt
+		q.savedWorkspaceFilters[i] = filter
+		return filter, nil
+	}
+	return database.SavedWorkspaceFilter{}, sql.ErrNoRows
+}
+
+func (q *FakeQuerier) UpdateScheduleHolidayByID(_ context.Context, arg database.UpdateScheduleHolidayByIDParams) (database.ScheduleHoliday, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.ScheduleHoliday{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	date := truncateToDate(arg.Date)
+	for _, holiday := range q.scheduleHolidays {
+		if holiday.ID != arg.ID && holiday.Date.Equal(date) {
+			return database.ScheduleHoliday{}, errDuplicateKey
+		}
+	}
+
+	for index, holiday := range q.scheduleHolidays {
+		if holiday.ID != arg.ID {
+			continue
+		}
+		holiday.UpdatedAt = arg.UpdatedAt
+		holiday.Date = date
+		holiday.Name = arg.Name
+		q.scheduleHolidays[index] = holiday
+		return holiday, nil
+	}
+	return database.ScheduleHoliday{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateTemplateACLByID(_ context.Context, arg database.UpdateTemplateACLByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -4799,6 +7262,49 @@ func (q *FakeQuerier) UpdateTemplateActiveVersionByID(_ context.Context, arg dat
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateTemplateDeadlineRecalculationCompletedByID(_ context.Context, arg database.UpdateTemplateDeadlineRecalculationCompletedByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, recalculation := range q.templateDeadlineRecalculations {
+		if recalculation.ID != arg.ID {
+			continue
+		}
+		recalculation.UpdatedAt = arg.UpdatedAt
+		recalculation.CompletedAt = sql.NullTime{Time: arg.UpdatedAt, Valid: true}
+		recalculation.Status = arg.Status
+		recalculation.Error = arg.Error
+		q.templateDeadlineRecalculations[i] = recalculation
+		return nil
+	}
+	return nil
+}
+
+func (q *FakeQuerier) UpdateTemplateDeadlineRecalculationProgressByID(_ context.Context, arg database.UpdateTemplateDeadlineRecalculationProgressByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, recalculation := range q.templateDeadlineRecalculations {
+		if recalculation.ID != arg.ID {
+			continue
+		}
+		recalculation.UpdatedAt = arg.UpdatedAt
+		recalculation.TotalBuilds = arg.TotalBuilds
+		recalculation.ProcessedBuilds = arg.ProcessedBuilds
+		q.templateDeadlineRecalculations[i] = recalculation
+		return nil
+	}
+	return nil
+}
+
 func (q *FakeQuerier) UpdateTemplateDeletedByID(_ context.Context, arg database.UpdateTemplateDeletedByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -4836,6 +7342,15 @@ func (q *FakeQuerier) UpdateTemplateMetaByID(_ context.Context, arg database.Upd
 		tpl.DisplayName = arg.DisplayName
 		tpl.Description = arg.Description
 		tpl.Icon = arg.Icon
+		tpl.MaxConcurrentBuilds = arg.MaxConcurrentBuilds
+		tpl.MaxDeadlineExtensionsPerDay = arg.MaxDeadlineExtensionsPerDay
+		tpl.MaxDeadlineExtension = arg.MaxDeadlineExtension
+		tpl.RequiredPromotionApprovals = arg.RequiredPromotionApprovals
+		tpl.UserCPUQuotaMillicores = arg.UserCPUQuotaMillicores
+		tpl.UserMemoryQuotaBytes = arg.UserMemoryQuotaBytes
+		tpl.UserDiskQuotaBytes = arg.UserDiskQuotaBytes
+		tpl.ApplyDotfiles = arg.ApplyDotfiles
+		tpl.DisableAutostop = arg.DisableAutostop
 		q.templates[idx] = tpl
 		return nil
 	}
@@ -4862,9 +7377,38 @@ func (q *FakeQuerier) UpdateTemplateScheduleByID(_ context.Context, arg database
 		tpl.MaxTTL = arg.MaxTTL
 		tpl.RestartRequirementDaysOfWeek = arg.RestartRequirementDaysOfWeek
 		tpl.RestartRequirementWeeks = arg.RestartRequirementWeeks
+		tpl.RestartRequirementTimezone = arg.RestartRequirementTimezone
 		tpl.FailureTTL = arg.FailureTTL
 		tpl.InactivityTTL = arg.InactivityTTL
 		tpl.LockedTTL = arg.LockedTTL
+		tpl.ActivityBumpTTL = arg.ActivityBumpTTL
+		tpl.RestartRequirementSpread = arg.RestartRequirementSpread
+		tpl.UpdateOnRestart = arg.UpdateOnRestart
+		tpl.FailureRetries = arg.FailureRetries
+		tpl.FailureRetryBackoff = arg.FailureRetryBackoff
+		tpl.MaxLifetime = arg.MaxLifetime
+		tpl.ArchiveRetention = arg.ArchiveRetention
+		q.templates[idx] = tpl
+		return nil
+	}
+
+	return sql.ErrNoRows
+}
+
+func (q *FakeQuerier) UpdateTemplateSchedulePolicyByID(_ context.Context, arg database.UpdateTemplateSchedulePolicyByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for idx, tpl := range q.templates {
+		if tpl.ID != arg.ID {
+			continue
+		}
+		tpl.UpdatedAt = arg.UpdatedAt
+		tpl.SchedulePolicyTemplateID = arg.SchedulePolicyTemplateID
 		q.templates[idx] = tpl
 		return nil
 	}
@@ -4934,6 +7478,29 @@ func (q *FakeQuerier) UpdateTemplateVersionGitAuthProvidersByJobID(_ context.Con
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateTemplateVersionPromotionStatusByID(_ context.Context, arg database.UpdateTemplateVersionPromotionStatusByIDParams) (database.TemplateVersionPromotion, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, promotion := range q.templateVersionPromotions {
+		if promotion.ID != arg.ID {
+			continue
+		}
+		if promotion.Status != database.TemplateVersionPromotionStatusPending {
+			return database.TemplateVersionPromotion{}, sql.ErrNoRows
+		}
+		promotion.UpdatedAt = arg.UpdatedAt
+		promotion.Status = arg.Status
+		q.templateVersionPromotions[i] = promotion
+		return promotion, nil
+	}
+	return database.TemplateVersionPromotion{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateUserDeletedByID(_ context.Context, params database.UpdateUserDeletedByIDParams) error {
 	if err := validateDatabaseType(params); err != nil {
 		return err
@@ -5143,6 +7710,27 @@ func (q *FakeQuerier) UpdateUserRoles(_ context.Context, arg database.UpdateUser
 	return database.User{}, sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateUserSecret(_ context.Context, arg database.UpdateUserSecretParams) (database.UserSecret, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserSecret{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, secret := range q.userSecrets {
+		if secret.UserID != arg.UserID || secret.Name != arg.Name {
+			continue
+		}
+		secret.Description = arg.Description
+		secret.Value = arg.Value
+		secret.UpdatedAt = arg.UpdatedAt
+		q.userSecrets[i] = secret
+		return secret, nil
+	}
+	return database.UserSecret{}, sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateUserStatus(_ context.Context, arg database.UpdateUserStatusParams) (database.User, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.User{}, err
@@ -5255,18 +7843,12 @@ func (q *FakeQuerier) UpdateWorkspaceAgentMetadata(_ context.Context, arg databa
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	//nolint:gosimple
-	updated := database.WorkspaceAgentMetadatum{
-		WorkspaceAgentID: arg.WorkspaceAgentID,
-		Key:              arg.Key,
-		Value:            arg.Value,
-		Error:            arg.Error,
-		CollectedAt:      arg.CollectedAt,
-	}
-
 	for i, m := range q.workspaceAgentMetadata {
 		if m.WorkspaceAgentID == arg.WorkspaceAgentID && m.Key == arg.Key {
-			q.workspaceAgentMetadata[i] = updated
+			m.Value = arg.Value
+			m.Error = arg.Error
+			m.CollectedAt = arg.CollectedAt
+			q.workspaceAgentMetadata[i] = m
 			return nil
 		}
 	}
@@ -5274,6 +7856,27 @@ func (q *FakeQuerier) UpdateWorkspaceAgentMetadata(_ context.Context, arg databa
 	return nil
 }
 
+func (q *FakeQuerier) UpdateWorkspaceAgentScriptStatus(_ context.Context, arg database.UpdateWorkspaceAgentScriptStatusParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, script := range q.workspaceAgentScripts {
+		if script.WorkspaceAgentID == arg.WorkspaceAgentID && script.Name == arg.Name {
+			script.Status = arg.Status
+			script.ExitCode = arg.ExitCode
+			script.StartedAt = arg.StartedAt
+			script.EndedAt = arg.EndedAt
+			q.workspaceAgentScripts[i] = script
+			return nil
+		}
+	}
+	return nil
+}
+
 func (q *FakeQuerier) UpdateWorkspaceAgentStartupByID(_ context.Context, arg database.UpdateWorkspaceAgentStartupByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -5352,6 +7955,69 @@ func (q *FakeQuerier) UpdateWorkspaceAutostart(_ context.Context, arg database.U
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateWorkspaceBatchCompletedByID(_ context.Context, arg database.UpdateWorkspaceBatchCompletedByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, batch := range q.workspaceBatches {
+		if batch.ID != arg.ID {
+			continue
+		}
+		batch.UpdatedAt = arg.UpdatedAt
+		batch.CompletedAt = sql.NullTime{Time: arg.UpdatedAt, Valid: true}
+		batch.Status = arg.Status
+		batch.Error = arg.Error
+		q.workspaceBatches[i] = batch
+		return nil
+	}
+	return nil
+}
+
+func (q *FakeQuerier) UpdateWorkspaceBatchUpdatedAtByID(_ context.Context, arg database.UpdateWorkspaceBatchUpdatedAtByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, batch := range q.workspaceBatches {
+		if batch.ID != arg.ID {
+			continue
+		}
+		batch.UpdatedAt = arg.UpdatedAt
+		q.workspaceBatches[i] = batch
+		return nil
+	}
+	return nil
+}
+
+func (q *FakeQuerier) UpdateWorkspaceBatchWorkspace(_ context.Context, arg database.UpdateWorkspaceBatchWorkspaceParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, batchWorkspace := range q.workspaceBatchWorkspaces {
+		if batchWorkspace.BatchID != arg.BatchID || batchWorkspace.WorkspaceID != arg.WorkspaceID {
+			continue
+		}
+		batchWorkspace.Status = arg.Status
+		batchWorkspace.BuildID = arg.BuildID
+		batchWorkspace.Error = arg.Error
+		batchWorkspace.UpdatedAt = arg.UpdatedAt
+		q.workspaceBatchWorkspaces[i] = batchWorkspace
+		return nil
+	}
+	return nil
+}
+
 func (q *FakeQuerier) UpdateWorkspaceBuildByID(_ context.Context, arg database.UpdateWorkspaceBuildByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -5412,6 +8078,26 @@ func (q *FakeQuerier) UpdateWorkspaceDeletedByID(_ context.Context, arg database
 	return sql.ErrNoRows
 }
 
+func (q *FakeQuerier) UpdateWorkspaceGroupACLByID(_ context.Context, arg database.UpdateWorkspaceGroupACLByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, workspace := range q.workspaces {
+		if workspace.ID != arg.ID {
+			continue
+		}
+		workspace.GroupACL = arg.GroupACL
+		q.workspaces[index] = workspace
+		return nil
+	}
+
+	return sql.ErrNoRows
+}
+
 func (q *FakeQuerier) UpdateWorkspaceLastUsedAt(_ context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -5443,6 +8129,7 @@ func (q *FakeQuerier) UpdateWorkspaceLockedDeletingAt(_ context.Context, arg dat
 			continue
 		}
 		workspace.LockedAt = arg.LockedAt
+		workspace.LockReason = arg.LockReason
 		if workspace.LockedAt.Time.IsZero() {
 			workspace.LastUsedAt = database.Now()
 			workspace.DeletingAt = sql.NullTime{}
@@ -5461,7 +8148,7 @@ func (q *FakeQuerier) UpdateWorkspaceLockedDeletingAt(_ context.Context, arg dat
 			if template.LockedTTL > 0 {
 				workspace.DeletingAt = sql.NullTime{
 					Valid: true,
-					Time:  workspace.LockedAt.Time.Add(time.Duration(template.LockedTTL)),
+					Time:  workspace.LockedAt.Time.Add(time.Duration(template.LockedTTL + template.ArchiveRetention)),
 				}
 			}
 		}
@@ -5486,8 +8173,11 @@ func (q *FakeQuerier) UpdateWorkspaceProxy(_ context.Context, arg database.Updat
 			p.Name = arg.Name
 			p.DisplayName = arg.DisplayName
 			p.Icon = arg.Icon
-			if len(p.TokenHashedSecret) > 0 {
+			if len(arg.TokenHashedSecret) > 0 {
 				p.TokenHashedSecret = arg.TokenHashedSecret
+				p.TokenExpiresAt = sql.NullTime{}
+				p.PreviousTokenHashedSecret = nil
+				p.PreviousTokenExpiresAt = sql.NullTime{}
 			}
 			q.workspaceProxies[i] = p
 			return p, nil
@@ -5548,7 +8238,7 @@ func (q *FakeQuerier) UpdateWorkspacesDeletingAtByTemplateID(_ context.Context,
 			Valid: arg.LockedTtlMs > 0,
 		}
 		if arg.LockedTtlMs > 0 {
-			deletingAt.Time = ws.LockedAt.Time.Add(time.Duration(arg.LockedTtlMs) * time.Millisecond)
+			deletingAt.Time = ws.LockedAt.Time.Add(time.Duration(arg.LockedTtlMs+arg.ArchiveRetentionMs) * time.Millisecond)
 		}
 		ws.DeletingAt = deletingAt
 		q.workspaces[i] = ws
@@ -5557,6 +8247,31 @@ func (q *FakeQuerier) UpdateWorkspacesDeletingAtByTemplateID(_ context.Context,
 	return nil
 }
 
+func (q *FakeQuerier) UpsertACMECertificate(_ context.Context, arg database.UpsertACMECertificateParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	certificate := database.ACMECertificate{
+		Domain:           arg.Domain,
+		Data:             arg.Data,
+		NotAfter:         arg.NotAfter,
+		UpdatedAt:        arg.UpdatedAt,
+		WorkspaceProxyID: arg.WorkspaceProxyID,
+	}
+	for i, existing := range q.acmeCertificates {
+		if existing.WorkspaceProxyID == arg.WorkspaceProxyID && existing.Domain == arg.Domain {
+			q.acmeCertificates[i] = certificate
+			return nil
+		}
+	}
+	q.acmeCertificates = append(q.acmeCertificates, certificate)
+	return nil
+}
+
 func (q *FakeQuerier) UpsertAppSecurityKey(_ context.Context, data string) error {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -5565,6 +8280,25 @@ func (q *FakeQuerier) UpsertAppSecurityKey(_ context.Context, data string) error
 	return nil
 }
 
+func (q *FakeQuerier) UpsertAuditLogExportCursor(_ context.Context, arg database.UpsertAuditLogExportCursorParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	cursor := database.AuditLogExportCursor(arg)
+	for i, existing := range q.auditLogExportCursors {
+		if existing.Sink == arg.Sink {
+			q.auditLogExportCursors[i] = cursor
+			return nil
+		}
+	}
+	q.auditLogExportCursors = append(q.auditLogExportCursors, cursor)
+	return nil
+}
+
 func (q *FakeQuerier) UpsertDefaultProxy(_ context.Context, arg database.UpsertDefaultProxyParams) error {
 	q.defaultProxyDisplayName = arg.DisplayName
 	q.defaultProxyIconURL = arg.IconUrl
@@ -5595,6 +8329,166 @@ func (q *FakeQuerier) UpsertOAuthSigningKey(_ context.Context, value string) err
 	return nil
 }
 
+func (q *FakeQuerier) UpsertOrganizationBudgetSettings(_ context.Context, arg database.UpsertOrganizationBudgetSettingsParams) (database.OrganizationBudgetSettings, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.OrganizationBudgetSettings{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, settings := range q.organizationBudgetSettings {
+		if settings.OrganizationID != arg.OrganizationID {
+			continue
+		}
+		settings.UpdatedAt = arg.UpdatedAt
+		settings.DailyBudget = arg.DailyBudget
+		settings.WarningThresholdPercent = arg.WarningThresholdPercent
+		settings.CriticalThresholdPercent = arg.CriticalThresholdPercent
+		settings.AlertedThresholdPercent = 0
+		q.organizationBudgetSettings[i] = settings
+		return settings, nil
+	}
+
+	settings := database.OrganizationBudgetSettings{
+		OrganizationID:           arg.OrganizationID,
+		CreatedAt:                arg.CreatedAt,
+		UpdatedAt:                arg.UpdatedAt,
+		DailyBudget:              arg.DailyBudget,
+		WarningThresholdPercent:  arg.WarningThresholdPercent,
+		CriticalThresholdPercent: arg.CriticalThresholdPercent,
+	}
+	q.organizationBudgetSettings = append(q.organizationBudgetSettings, settings)
+	return settings, nil
+}
+
+func (q *FakeQuerier) UpsertOrganizationJITProvisioningSettings(_ context.Context, arg database.UpsertOrganizationJITProvisioningSettingsParams) (database.OrganizationJITProvisioningSettings, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.OrganizationJITProvisioningSettings{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, settings := range q.organizationJITProvisioningSettings {
+		if settings.OrganizationID != arg.OrganizationID {
+			continue
+		}
+		settings.UpdatedAt = arg.UpdatedAt
+		settings.TemplateID = arg.TemplateID
+		settings.WorkspaceName = arg.WorkspaceName
+		q.organizationJITProvisioningSettings[i] = settings
+		return settings, nil
+	}
+
+	//nolint:gosimple
+	settings := database.OrganizationJITProvisioningSettings{
+		OrganizationID: arg.OrganizationID,
+		CreatedAt:      arg.CreatedAt,
+		UpdatedAt:      arg.UpdatedAt,
+		TemplateID:     arg.TemplateID,
+		WorkspaceName:  arg.WorkspaceName,
+	}
+	q.organizationJITProvisioningSettings = append(q.organizationJITProvisioningSettings, settings)
+	return settings, nil
+}
+
+func (q *FakeQuerier) UpsertOrganizationProvisionerSettings(_ context.Context, arg database.UpsertOrganizationProvisionerSettingsParams) (database.OrganizationProvisionerSettings, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.OrganizationProvisionerSettings{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, settings := range q.organizationProvisionerSettings {
+		if settings.OrganizationID != arg.OrganizationID {
+			continue
+		}
+		settings.UpdatedAt = arg.UpdatedAt
+		settings.MaxConcurrentJobs = arg.MaxConcurrentJobs
+		q.organizationProvisionerSettings[i] = settings
+		return settings, nil
+	}
+
+	//nolint:gosimple
+	settings := database.OrganizationProvisionerSettings{
+		OrganizationID:    arg.OrganizationID,
+		CreatedAt:         arg.CreatedAt,
+		UpdatedAt:         arg.UpdatedAt,
+		MaxConcurrentJobs: arg.MaxConcurrentJobs,
+	}
+	q.organizationProvisionerSettings = append(q.organizationProvisionerSettings, settings)
+	return settings, nil
+}
+
+func (q *FakeQuerier) UpsertOrganizationScheduleSettings(_ context.Context, arg database.UpsertOrganizationScheduleSettingsParams) (database.OrganizationScheduleSettings, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.OrganizationScheduleSettings{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, settings := range q.organizationScheduleSettings {
+		if settings.OrganizationID != arg.OrganizationID {
+			continue
+		}
+		settings.UpdatedAt = arg.UpdatedAt
+		settings.DefaultTTL = arg.DefaultTTL
+		settings.RestartRequirementDaysOfWeek = arg.RestartRequirementDaysOfWeek
+		settings.RestartRequirementWeeks = arg.RestartRequirementWeeks
+		settings.DefaultQuietHoursSchedule = arg.DefaultQuietHoursSchedule
+		q.organizationScheduleSettings[i] = settings
+		return settings, nil
+	}
+
+	//nolint:gosimple
+	settings := database.OrganizationScheduleSettings{
+		OrganizationID:               arg.OrganizationID,
+		CreatedAt:                    arg.CreatedAt,
+		UpdatedAt:                    arg.UpdatedAt,
+		DefaultTTL:                   arg.DefaultTTL,
+		RestartRequirementDaysOfWeek: arg.RestartRequirementDaysOfWeek,
+		RestartRequirementWeeks:      arg.RestartRequirementWeeks,
+		DefaultQuietHoursSchedule:    arg.DefaultQuietHoursSchedule,
+	}
+	q.organizationScheduleSettings = append(q.organizationScheduleSettings, settings)
+	return settings, nil
+}
+
+func (q *FakeQuerier) UpsertOrganizationTemplateVariable(_ context.Context, arg database.UpsertOrganizationTemplateVariableParams) (database.OrganizationTemplateVariable, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.OrganizationTemplateVariable{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, variable := range q.organizationTemplateVariables {
+		if variable.OrganizationID != arg.OrganizationID || variable.Name != arg.Name {
+			continue
+		}
+		variable.Value = arg.Value
+		variable.Sensitive = arg.Sensitive
+		variable.UpdatedAt = arg.UpdatedAt
+		q.organizationTemplateVariables[i] = variable
+		return variable, nil
+	}
+
+	//nolint:gosimple
+	variable := database.OrganizationTemplateVariable{
+		OrganizationID: arg.OrganizationID,
+		Name:           arg.Name,
+		Value:          arg.Value,
+		Sensitive:      arg.Sensitive,
+		CreatedAt:      arg.CreatedAt,
+		UpdatedAt:      arg.UpdatedAt,
+	}
+	q.organizationTemplateVariables = append(q.organizationTemplateVariables, variable)
+	return variable, nil
+}
+
 func (q *FakeQuerier) UpsertServiceBanner(_ context.Context, data string) error {
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
@@ -5615,6 +8509,180 @@ func (*FakeQuerier) UpsertTailnetCoordinator(context.Context, uuid.UUID) (databa
 	return database.TailnetCoordinator{}, ErrUnimplemented
 }
 
+func (q *FakeQuerier) UpsertTemplateParameterValidation(_ context.Context, arg database.UpsertTemplateParameterValidationParams) (database.TemplateParameterValidation, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateParameterValidation{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, validation := range q.templateParameterValidations {
+		if validation.TemplateID != arg.TemplateID {
+			continue
+		}
+		validation.WebhookURL = arg.WebhookURL
+		validation.Constraints = arg.Constraints
+		validation.UpdatedAt = arg.UpdatedAt
+		q.templateParameterValidations[i] = validation
+		return validation, nil
+	}
+
+	//nolint:gosimple
+	validation := database.TemplateParameterValidation{
+		TemplateID:  arg.TemplateID,
+		WebhookURL:  arg.WebhookURL,
+		Constraints: arg.Constraints,
+		CreatedAt:   arg.CreatedAt,
+		UpdatedAt:   arg.UpdatedAt,
+	}
+	q.templateParameterValidations = append(q.templateParameterValidations, validation)
+	return validation, nil
+}
+
+func (q *FakeQuerier) UpsertTemplatePrebuildPool(_ context.Context, arg database.UpsertTemplatePrebuildPoolParams) (database.TemplatePrebuildPool, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplatePrebuildPool{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	pool := database.TemplatePrebuildPool{
+		TemplateID: arg.TemplateID,
+		Size:       arg.Size,
+		UpdatedAt:  arg.UpdatedAt,
+	}
+	for i, existing := range q.templatePrebuildPools {
+		if existing.TemplateID == arg.TemplateID {
+			q.templatePrebuildPools[i] = pool
+			return pool, nil
+		}
+	}
+	q.templatePrebuildPools = append(q.templatePrebuildPools, pool)
+	return pool, nil
+}
+
+func (q *FakeQuerier) UpsertUserDotfiles(_ context.Context, arg database.UpsertUserDotfilesParams) (database.UserDotfile, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.UserDotfile{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	dotfiles := database.UserDotfile{
+		UserID:    arg.UserID,
+		RepoURL:   arg.RepoURL,
+		Branch:    arg.Branch,
+		UpdatedAt: arg.UpdatedAt,
+	}
+	for i, existing := range q.userDotfiles {
+		if existing.UserID == arg.UserID {
+			q.userDotfiles[i] = dotfiles
+			return dotfiles, nil
+		}
+	}
+	q.userDotfiles = append(q.userDotfiles, dotfiles)
+	return dotfiles, nil
+}
+
+func (q *FakeQuerier) UpsertWorkspaceAgentPluginMetadata(_ context.Context, arg database.UpsertWorkspaceAgentPluginMetadataParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, m := range q.workspaceAgentMetadata {
+		if m.WorkspaceAgentID != arg.WorkspaceAgentID || m.Key != arg.Key {
+			continue
+		}
+		if !m.Plugin {
+			return nil
+		}
+		m.DisplayName = arg.DisplayName
+		m.Script = arg.Script
+		m.Timeout = arg.Timeout
+		m.Interval = arg.Interval
+		q.workspaceAgentMetadata[i] = m
+		return nil
+	}
+
+	q.workspaceAgentMetadata = append(q.workspaceAgentMetadata, database.WorkspaceAgentMetadatum{
+		WorkspaceAgentID: arg.WorkspaceAgentID,
+		DisplayName:      arg.DisplayName,
+		Key:              arg.Key,
+		Script:           arg.Script,
+		Timeout:          arg.Timeout,
+		Interval:         arg.Interval,
+		Plugin:           true,
+	})
+	return nil
+}
+
+func (q *FakeQuerier) UpsertWorkspaceNameRedirect(_ context.Context, arg database.UpsertWorkspaceNameRedirectParams) (database.WorkspaceNameRedirect, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceNameRedirect{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	redirect := database.WorkspaceNameRedirect{
+		OwnerID:     arg.OwnerID,
+		Name:        arg.Name,
+		WorkspaceID: arg.WorkspaceID,
+		CreatedAt:   arg.CreatedAt,
+		ExpiresAt:   arg.ExpiresAt,
+	}
+	for i, existing := range q.workspaceNameRedirects {
+		if existing.OwnerID == arg.OwnerID && existing.Name == arg.Name {
+			q.workspaceNameRedirects[i] = redirect
+			return redirect, nil
+		}
+	}
+	q.workspaceNameRedirects = append(q.workspaceNameRedirects, redirect)
+	return redirect, nil
+}
+
+func (q *FakeQuerier) UpsertWorkspaceScheduleOverride(_ context.Context, arg database.UpsertWorkspaceScheduleOverrideParams) (database.WorkspaceScheduleOverride, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceScheduleOverride{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, override := range q.workspaceScheduleOverrides {
+		if override.WorkspaceID != arg.WorkspaceID {
+			continue
+		}
+		override.UpdatedAt = arg.UpdatedAt
+		override.DefaultTTL = arg.DefaultTTL
+		override.MaxTTL = arg.MaxTTL
+		override.RestartRequirementDaysOfWeek = arg.RestartRequirementDaysOfWeek
+		override.RestartRequirementWeeks = arg.RestartRequirementWeeks
+		q.workspaceScheduleOverrides[i] = override
+		return override, nil
+	}
+
+	//nolint:gosimple
+	override := database.WorkspaceScheduleOverride{
+		WorkspaceID:                  arg.WorkspaceID,
+		CreatedAt:                    arg.CreatedAt,
+		UpdatedAt:                    arg.UpdatedAt,
+		DefaultTTL:                   arg.DefaultTTL,
+		MaxTTL:                       arg.MaxTTL,
+		RestartRequirementDaysOfWeek: arg.RestartRequirementDaysOfWeek,
+		RestartRequirementWeeks:      arg.RestartRequirementWeeks,
+	}
+	q.workspaceScheduleOverrides = append(q.workspaceScheduleOverrides, override)
+	return override, nil
+}
+
 func (q *FakeQuerier) GetAuthorizedTemplates(ctx context.Context, arg database.GetTemplatesWithFilterParams, prepared rbac.PreparedAuthorized) ([]database.Template, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
@@ -5765,7 +8833,7 @@ func (q *FakeQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg database.
 
 	if prepared != nil {
 		// Call this to match the same function calls as the SQL implementation.
-		_, err := prepared.CompileToSQL(ctx, rbac.ConfigWithoutACL())
+		_, err := prepared.CompileToSQL(ctx, rbac.ConfigWithACL())
 		if err != nil {
 			return nil, err
 		}
@@ -5833,13 +8901,15 @@ func (q *FakeQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg database.
 					isNull(job.CanceledAt) &&
 					isNull(job.CompletedAt) &&
 					time.Since(job.UpdatedAt) < 30*time.Second &&
-					build.Transition == database.WorkspaceTransitionStop
+					(build.Transition == database.WorkspaceTransitionStop ||
+						build.Transition == database.WorkspaceTransitionArchive)
 
 			case database.WorkspaceStatusStopped:
 				statusMatch = isNotNull(job.CompletedAt) &&
 					isNull(job.CanceledAt) &&
 					isNull(job.Error) &&
-					build.Transition == database.WorkspaceTransitionStop
+					(build.Transition == database.WorkspaceTransitionStop ||
+						build.Transition == database.WorkspaceTransitionArchive)
 			case database.WorkspaceStatusFailed:
 				statusMatch = (isNotNull(job.CanceledAt) && isNotNull(job.Error)) ||
 					(isNotNull(job.CompletedAt) && isNotNull(job.Error))
@@ -5922,6 +8992,53 @@ func (q *FakeQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg database.
 			continue
 		}
 
+		if !arg.LastUsedBefore.IsZero() && !workspace.LastUsedAt.Before(arg.LastUsedBefore) {
+			continue
+		}
+		if !arg.LastUsedAfter.IsZero() && !workspace.LastUsedAt.After(arg.LastUsedAfter) {
+			continue
+		}
+
+		if arg.LockReason != "" && (!workspace.LockReason.Valid || string(workspace.LockReason.WorkspaceLockReason) != arg.LockReason) {
+			continue
+		}
+
+		if arg.Outdated != "" {
+			build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
+			if err != nil {
+				return nil, xerrors.Errorf("get latest build: %w", err)
+			}
+			template, err := q.getTemplateByIDNoLock(ctx, workspace.TemplateID)
+			if err != nil {
+				return nil, xerrors.Errorf("get template: %w", err)
+			}
+			outdated := build.TemplateVersionID != template.ActiveVersionID
+			if (arg.Outdated == "true") != outdated {
+				continue
+			}
+		}
+
+		if !arg.DeadlineBefore.IsZero() || !arg.DeadlineAfter.IsZero() ||
+			!arg.MaxDeadlineBefore.IsZero() || !arg.MaxDeadlineAfter.IsZero() {
+			build, err := q.getLatestWorkspaceBuildByWorkspaceIDNoLock(ctx, workspace.ID)
+			if err != nil {
+				return nil, xerrors.Errorf("get latest build: %w", err)
+			}
+			// Builds without a deadline have a zero deadline, and never match.
+			if !arg.DeadlineBefore.IsZero() && (build.Deadline.IsZero() || !build.Deadline.Before(arg.DeadlineBefore)) {
+				continue
+			}
+			if !arg.DeadlineAfter.IsZero() && !build.Deadline.After(arg.DeadlineAfter) {
+				continue
+			}
+			if !arg.MaxDeadlineBefore.IsZero() && (build.MaxDeadline.IsZero() || !build.MaxDeadline.Before(arg.MaxDeadlineBefore)) {
+				continue
+			}
+			if !arg.MaxDeadlineAfter.IsZero() && !build.MaxDeadline.After(arg.MaxDeadlineAfter) {
+				continue
+			}
+		}
+
 		if len(arg.TemplateIDs) > 0 {
 			match := false
 			for _, id := range arg.TemplateIDs {
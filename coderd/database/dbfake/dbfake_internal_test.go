@@ -0,0 +1,18 @@
+package dbfake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentileCont(t *testing.T) {
+	t.Parallel()
+
+	// The expected values are the results of PERCENTILE_CONT in Postgres.
+	require.EqualValues(t, -1, percentileCont(nil, 50))
+	require.EqualValues(t, 7, percentileCont([]float64{7}, 95))
+	require.EqualValues(t, 25, percentileCont([]float64{40, 10, 20, 30}, 50))
+	require.InDelta(t, 38.5, percentileCont([]float64{40, 10, 20, 30}, 95), 1e-9)
+	require.EqualValues(t, 40, percentileCont([]float64{40, 10, 20, 30}, 100))
+}
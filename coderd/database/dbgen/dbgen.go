@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -329,13 +330,15 @@ func ProvisionerJob(t testing.TB, db database.Store, orig database.ProvisionerJo
 		Type:           takeFirst(orig.Type, database.ProvisionerJobTypeWorkspaceBuild),
 		Input:          takeFirstSlice(orig.Input, []byte("{}")),
 		Tags:           orig.Tags,
+		Priority:       orig.Priority,
+		NotBefore:      orig.NotBefore,
 	})
 	require.NoError(t, err, "insert job")
 
 	if !orig.StartedAt.Time.IsZero() {
 		job, err = db.AcquireProvisionerJob(genCtx, database.AcquireProvisionerJobParams{
 			StartedAt: orig.StartedAt,
-			Types:     []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Types:     []string{string(database.ProvisionerTypeEcho)},
 			Tags:      must(json.Marshal(orig.Tags)),
 		})
 		require.NoError(t, err)
@@ -366,6 +369,22 @@ func ProvisionerJob(t testing.TB, db database.Store, orig database.ProvisionerJo
 	return job
 }
 
+func ProvisionerKey(t testing.TB, db database.Store, orig database.ProvisionerKey) database.ProvisionerKey {
+	if orig.Tags == nil {
+		orig.Tags = database.StringMap{}
+	}
+	key, err := db.InsertProvisionerKey(genCtx, database.InsertProvisionerKeyParams{
+		ID:             takeFirst(orig.ID, uuid.New()),
+		CreatedAt:      takeFirst(orig.CreatedAt, database.Now()),
+		OrganizationID: takeFirst(orig.OrganizationID, uuid.New()),
+		Name:           takeFirst(orig.Name, namesgenerator.GetRandomName(1)),
+		HashedSecret:   takeFirstSlice(orig.HashedSecret, []byte("hashed-secret")),
+		Tags:           orig.Tags,
+	})
+	require.NoError(t, err, "insert provisioner key")
+	return key
+}
+
 func WorkspaceApp(t testing.TB, db database.Store, orig database.WorkspaceApp) database.WorkspaceApp {
 	resource, err := db.InsertWorkspaceApp(genCtx, database.InsertWorkspaceAppParams{
 		ID:          takeFirst(orig.ID, uuid.New()),
@@ -382,13 +401,17 @@ func WorkspaceApp(t testing.TB, db database.Store, orig database.WorkspaceApp) d
 			String: takeFirst(orig.Url.String),
 			Valid:  orig.Url.Valid,
 		},
-		External:             orig.External,
-		Subdomain:            orig.Subdomain,
-		SharingLevel:         takeFirst(orig.SharingLevel, database.AppSharingLevelOwner),
-		HealthcheckUrl:       takeFirst(orig.HealthcheckUrl, "https://localhost:8000"),
-		HealthcheckInterval:  takeFirst(orig.HealthcheckInterval, 60),
-		HealthcheckThreshold: takeFirst(orig.HealthcheckThreshold, 60),
-		Health:               takeFirst(orig.Health, database.WorkspaceAppHealthHealthy),
+		External:                   orig.External,
+		Subdomain:                  orig.Subdomain,
+		SharingLevel:               takeFirst(orig.SharingLevel, database.AppSharingLevelOwner),
+		HealthcheckUrl:             takeFirst(orig.HealthcheckUrl, "https://localhost:8000"),
+		HealthcheckInterval:        takeFirst(orig.HealthcheckInterval, 60),
+		HealthcheckThreshold:       takeFirst(orig.HealthcheckThreshold, 60),
+		Health:                     takeFirst(orig.Health, database.WorkspaceAppHealthHealthy),
+		Auth:                       takeFirst(orig.Auth, database.WorkspaceAppAuthCoder),
+		RateLimitRequestsPerMinute: orig.RateLimitRequestsPerMinute,
+		RateLimitBurst:             orig.RateLimitBurst,
+		RewritePaths:               orig.RewritePaths,
 	})
 	require.NoError(t, err, "insert app")
 	return resource
@@ -541,6 +564,20 @@ func TemplateVersionVariable(t testing.TB, db database.Store, orig database.Temp
 	return version
 }
 
+func TemplateVersionPromotion(t testing.TB, db database.Store, orig database.TemplateVersionPromotion) database.TemplateVersionPromotion {
+	promotion, err := db.InsertTemplateVersionPromotion(genCtx, database.InsertTemplateVersionPromotionParams{
+		ID:                takeFirst(orig.ID, uuid.New()),
+		TemplateID:        takeFirst(orig.TemplateID, uuid.New()),
+		TemplateVersionID: takeFirst(orig.TemplateVersionID, uuid.New()),
+		RequestedBy:       takeFirst(orig.RequestedBy, uuid.New()),
+		CreatedAt:         takeFirst(orig.CreatedAt, database.Now()),
+		UpdatedAt:         takeFirst(orig.UpdatedAt, database.Now()),
+		Status:            takeFirst(orig.Status, database.TemplateVersionPromotionStatusPending),
+	})
+	require.NoError(t, err, "insert template version promotion")
+	return promotion
+}
+
 func WorkspaceAgentStat(t testing.TB, db database.Store, orig database.WorkspaceAgentStat) database.WorkspaceAgentStat {
 	if orig.ConnectionsByProto == nil {
 		orig.ConnectionsByProto = json.RawMessage([]byte("{}"))
@@ -563,11 +600,77 @@ func WorkspaceAgentStat(t testing.TB, db database.Store, orig database.Workspace
 		SessionCountReconnectingPTY: takeFirst(orig.SessionCountReconnectingPTY, 0),
 		SessionCountSSH:             takeFirst(orig.SessionCountSSH, 0),
 		ConnectionMedianLatencyMS:   takeFirst(orig.ConnectionMedianLatencyMS, 0),
+		PeerCountP2P:                takeFirst(orig.PeerCountP2P, 0),
+		PeerCountDERP:               takeFirst(orig.PeerCountDERP, 0),
 	})
 	require.NoError(t, err, "insert workspace agent stat")
 	return scheme
 }
 
+func SavedWorkspaceFilter(t testing.TB, db database.Store, orig database.SavedWorkspaceFilter) database.SavedWorkspaceFilter {
+	filter, err := db.InsertSavedWorkspaceFilter(genCtx, database.InsertSavedWorkspaceFilterParams{
+		ID:        takeFirst(orig.ID, uuid.New()),
+		UserID:    takeFirst(orig.UserID, uuid.New()),
+		Name:      takeFirst(orig.Name, namesgenerator.GetRandomName(1)),
+		Query:     takeFirst(orig.Query, "status:running"),
+		CreatedAt: takeFirst(orig.CreatedAt, database.Now()),
+		UpdatedAt: takeFirst(orig.UpdatedAt, database.Now()),
+	})
+	require.NoError(t, err, "insert saved workspace filter")
+	return filter
+}
+
+func ScheduleHoliday(t testing.TB, db database.Store, orig database.ScheduleHoliday) database.ScheduleHoliday {
+	holiday, err := db.InsertScheduleHoliday(genCtx, database.InsertScheduleHolidayParams{
+		ID:        takeFirst(orig.ID, uuid.New()),
+		CreatedAt: takeFirst(orig.CreatedAt, database.Now()),
+		UpdatedAt: takeFirst(orig.UpdatedAt, database.Now()),
+		// Dates are unique, so pick a random one to avoid collisions.
+		Date: takeFirst(orig.Date, time.Date(2000, 1, 1+must(cryptorand.Intn(365*100)), 0, 0, 0, 0, time.UTC)),
+		Name: takeFirst(orig.Name, namesgenerator.GetRandomName(1)),
+	})
+	require.NoError(t, err, "insert schedule holiday")
+	return holiday
+}
+
+func UserQuietHoursException(t testing.TB, db database.Store, orig database.UserQuietHoursException) database.UserQuietHoursException {
+	exception, err := db.InsertUserQuietHoursException(genCtx, database.InsertUserQuietHoursExceptionParams{
+		UserID: takeFirst(orig.UserID, uuid.New()),
+		// Dates are unique per user, so pick a random one to avoid collisions.
+		Date:      takeFirst(orig.Date, time.Date(2000, 1, 1+must(cryptorand.Intn(365*100)), 0, 0, 0, 0, time.UTC)),
+		CreatedAt: takeFirst(orig.CreatedAt, database.Now()),
+	})
+	require.NoError(t, err, "insert user quiet hours exception")
+	return exception
+}
+
+func UserSecret(t testing.TB, db database.Store, orig database.UserSecret) database.UserSecret {
+	secret, err := db.InsertUserSecret(genCtx, database.InsertUserSecretParams{
+		ID:          takeFirst(orig.ID, uuid.New()),
+		UserID:      takeFirst(orig.UserID, uuid.New()),
+		Name:        takeFirst(orig.Name, strings.ToUpper(namesgenerator.GetRandomName(1))),
+		Description: takeFirst(orig.Description, ""),
+		Value:       takeFirstSlice(orig.Value, []byte("encrypted")),
+		CreatedAt:   takeFirst(orig.CreatedAt, database.Now()),
+		UpdatedAt:   takeFirst(orig.UpdatedAt, database.Now()),
+	})
+	require.NoError(t, err, "insert user secret")
+	return secret
+}
+
+func WorkspaceDeadlineExtension(t testing.TB, db database.Store, orig database.WorkspaceDeadlineExtension) database.WorkspaceDeadlineExtension {
+	extension, err := db.InsertWorkspaceDeadlineExtension(genCtx, database.InsertWorkspaceDeadlineExtensionParams{
+		ID:               takeFirst(orig.ID, uuid.New()),
+		WorkspaceID:      takeFirst(orig.WorkspaceID, uuid.New()),
+		WorkspaceBuildID: takeFirst(orig.WorkspaceBuildID, uuid.New()),
+		UserID:           takeFirst(orig.UserID, uuid.New()),
+		CreatedAt:        takeFirst(orig.CreatedAt, database.Now()),
+		Duration:         takeFirst(orig.Duration, int64(time.Hour)),
+	})
+	require.NoError(t, err, "insert workspace deadline extension")
+	return extension
+}
+
 func must[V any](v V, err error) V {
 	if err != nil {
 		panic(err)
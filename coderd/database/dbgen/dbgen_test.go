@@ -3,6 +3,7 @@ package dbgen_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
@@ -153,6 +154,44 @@ func TestGenerator(t *testing.T) {
 		require.Equal(t, exp, must(db.GetTemplateVersionByID(context.Background(), exp.ID)))
 	})
 
+	t.Run("TemplateVersionPromotion", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.TemplateVersionPromotion(t, db, database.TemplateVersionPromotion{})
+		require.Equal(t, exp, must(db.GetTemplateVersionPromotionByID(context.Background(), exp.ID)))
+	})
+
+	t.Run("ProvisionerKey", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.ProvisionerKey(t, db, database.ProvisionerKey{})
+		require.Equal(t, exp, must(db.GetProvisionerKeyByID(context.Background(), exp.ID)))
+	})
+
+	t.Run("ScheduleHoliday", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.ScheduleHoliday(t, db, database.ScheduleHoliday{})
+		require.Equal(t, exp, must(db.GetScheduleHolidayByID(context.Background(), exp.ID)))
+	})
+
+	t.Run("UserQuietHoursException", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.UserQuietHoursException(t, db, database.UserQuietHoursException{})
+		require.Equal(t, []database.UserQuietHoursException{exp}, must(db.GetUserQuietHoursExceptions(context.Background(), exp.UserID)))
+	})
+
+	t.Run("WorkspaceDeadlineExtension", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.WorkspaceDeadlineExtension(t, db, database.WorkspaceDeadlineExtension{})
+		require.Equal(t, int64(1), must(db.GetWorkspaceDeadlineExtensionCount(context.Background(), database.GetWorkspaceDeadlineExtensionCountParams{
+			WorkspaceID: exp.WorkspaceID,
+			CreatedAt:   exp.CreatedAt.Add(-time.Second),
+		})))
+	})
+
 	t.Run("WorkspaceBuild", func(t *testing.T) {
 		t.Parallel()
 		db := dbfake.New()
@@ -93,6 +93,34 @@ func (m metricsStore) AcquireProvisionerJob(ctx context.Context, arg database.Ac
 	return provisionerJob, err
 }
 
+func (m metricsStore) AcquireTemplateDeadlineRecalculation(ctx context.Context, arg database.AcquireTemplateDeadlineRecalculationParams) (database.TemplateDeadlineRecalculation, error) {
+	start := time.Now()
+	recalculation, err := m.s.AcquireTemplateDeadlineRecalculation(ctx, arg)
+	m.queryLatencies.WithLabelValues("AcquireTemplateDeadlineRecalculation").Observe(time.Since(start).Seconds())
+	return recalculation, err
+}
+
+func (m metricsStore) AcquireWorkspaceBatch(ctx context.Context, arg database.AcquireWorkspaceBatchParams) (database.WorkspaceBatch, error) {
+	start := time.Now()
+	batch, err := m.s.AcquireWorkspaceBatch(ctx, arg)
+	m.queryLatencies.WithLabelValues("AcquireWorkspaceBatch").Observe(time.Since(start).Seconds())
+	return batch, err
+}
+
+func (m metricsStore) ApproveTemplateVersionPromotion(ctx context.Context, arg database.ApproveTemplateVersionPromotionParams) (database.TemplateVersionPromotion, error) {
+	start := time.Now()
+	r0, r1 := m.s.ApproveTemplateVersionPromotion(ctx, arg)
+	m.queryLatencies.WithLabelValues("ApproveTemplateVersionPromotion").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) ClaimPrebuiltWorkspace(ctx context.Context, arg database.ClaimPrebuiltWorkspaceParams) (database.Workspace, error) {
+	start := time.Now()
+	workspace, err := m.s.ClaimPrebuiltWorkspace(ctx, arg)
+	m.queryLatencies.WithLabelValues("ClaimPrebuiltWorkspace").Observe(time.Since(start).Seconds())
+	return workspace, err
+}
+
 func (m metricsStore) CleanTailnetCoordinators(ctx context.Context) error {
 	start := time.Now()
 	err := m.s.CleanTailnetCoordinators(ctx)
@@ -121,12 +149,33 @@ func (m metricsStore) DeleteApplicationConnectAPIKeysByUserID(ctx context.Contex
 	return err
 }
 
+func (m metricsStore) DeleteAuditLogsByIDs(ctx context.Context, ids []uuid.UUID) error {
+	start := time.Now()
+	err := m.s.DeleteAuditLogsByIDs(ctx, ids)
+	m.queryLatencies.WithLabelValues("DeleteAuditLogsByIDs").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) DeleteCoordinator(ctx context.Context, id uuid.UUID) error {
 	start := time.Now()
 	defer m.queryLatencies.WithLabelValues("DeleteCoordinator").Observe(time.Since(start).Seconds())
 	return m.s.DeleteCoordinator(ctx, id)
 }
 
+func (m metricsStore) DeleteCustomRole(ctx context.Context, name string) error {
+	start := time.Now()
+	err := m.s.DeleteCustomRole(ctx, name)
+	m.queryLatencies.WithLabelValues("DeleteCustomRole").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteExpiredWorkspaceNameRedirects(ctx context.Context) error {
+	start := time.Now()
+	err := m.s.DeleteExpiredWorkspaceNameRedirects(ctx)
+	m.queryLatencies.WithLabelValues("DeleteExpiredWorkspaceNameRedirects").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error {
 	start := time.Now()
 	err := m.s.DeleteGitSSHKey(ctx, userID)
@@ -162,6 +211,13 @@ func (m metricsStore) DeleteLicense(ctx context.Context, id int32) (int32, error
 	return licenseID, err
 }
 
+func (m metricsStore) DeleteOldTemplateDeadlineRecalculations(ctx context.Context, completedBefore time.Time) error {
+	start := time.Now()
+	err := m.s.DeleteOldTemplateDeadlineRecalculations(ctx, completedBefore)
+	m.queryLatencies.WithLabelValues("DeleteOldTemplateDeadlineRecalculations").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) DeleteOldWorkspaceAgentLogs(ctx context.Context) error {
 	start := time.Now()
 	r0 := m.s.DeleteOldWorkspaceAgentLogs(ctx)
@@ -176,6 +232,34 @@ func (m metricsStore) DeleteOldWorkspaceAgentStats(ctx context.Context) error {
 	return err
 }
 
+func (m metricsStore) DeleteOldWorkspaceAppAccessLogs(ctx context.Context) error {
+	start := time.Now()
+	err := m.s.DeleteOldWorkspaceAppAccessLogs(ctx)
+	m.queryLatencies.WithLabelValues("DeleteOldWorkspaceAppAccessLogs").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteOldWorkspaceBatches(ctx context.Context, completedBefore time.Time) error {
+	start := time.Now()
+	err := m.s.DeleteOldWorkspaceBatches(ctx, completedBefore)
+	m.queryLatencies.WithLabelValues("DeleteOldWorkspaceBatches").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteOrganizationTemplateVariable(ctx context.Context, arg database.DeleteOrganizationTemplateVariableParams) error {
+	start := time.Now()
+	err := m.s.DeleteOrganizationTemplateVariable(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteOrganizationTemplateVariable").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteProvisionerKey(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	err := m.s.DeleteProvisionerKey(ctx, id)
+	m.queryLatencies.WithLabelValues("DeleteProvisionerKey").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt time.Time) error {
 	start := time.Now()
 	err := m.s.DeleteReplicasUpdatedBefore(ctx, updatedAt)
@@ -183,6 +267,20 @@ func (m metricsStore) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt
 	return err
 }
 
+func (m metricsStore) DeleteSavedWorkspaceFilter(ctx context.Context, arg database.DeleteSavedWorkspaceFilterParams) error {
+	start := time.Now()
+	err := m.s.DeleteSavedWorkspaceFilter(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteSavedWorkspaceFilter").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) DeleteScheduleHolidayByID(ctx context.Context, id uuid.UUID) error {
+	start := time.Now()
+	r0 := m.s.DeleteScheduleHolidayByID(ctx, id)
+	m.queryLatencies.WithLabelValues("DeleteScheduleHolidayByID").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) DeleteTailnetAgent(ctx context.Context, arg database.DeleteTailnetAgentParams) (database.DeleteTailnetAgentRow, error) {
 	start := time.Now()
 	defer m.queryLatencies.WithLabelValues("DeleteTailnetAgent").Observe(time.Since(start).Seconds())
@@ -195,6 +293,48 @@ func (m metricsStore) DeleteTailnetClient(ctx context.Context, arg database.Dele
 	return m.s.DeleteTailnetClient(ctx, arg)
 }
 
+func (m metricsStore) DeleteUserDotfiles(ctx context.Context, userID uuid.UUID) error {
+	start := time.Now()
+	r0 := m.s.DeleteUserDotfiles(ctx, userID)
+	m.queryLatencies.WithLabelValues("DeleteUserDotfiles").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m metricsStore) DeleteUserQuietHoursException(ctx context.Context, arg database.DeleteUserQuietHoursExceptionParams) error {
+	start := time.Now()
+	r0 := m.s.DeleteUserQuietHoursException(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteUserQuietHoursException").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m metricsStore) DeleteUserSecret(ctx context.Context, arg database.DeleteUserSecretParams) error {
+	start := time.Now()
+	r0 := m.s.DeleteUserSecret(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteUserSecret").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m metricsStore) DeleteWorkspaceAgentPluginMetadata(ctx context.Context, arg database.DeleteWorkspaceAgentPluginMetadataParams) error {
+	start := time.Now()
+	r0 := m.s.DeleteWorkspaceAgentPluginMetadata(ctx, arg)
+	m.queryLatencies.WithLabelValues("DeleteWorkspaceAgentPluginMetadata").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m metricsStore) DeleteWorkspaceScheduleOverrideByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) error {
+	start := time.Now()
+	r0 := m.s.DeleteWorkspaceScheduleOverrideByWorkspaceID(ctx, workspaceID)
+	m.queryLatencies.WithLabelValues("DeleteWorkspaceScheduleOverrideByWorkspaceID").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m metricsStore) GetACMECertificate(ctx context.Context, arg database.GetACMECertificateParams) (database.ACMECertificate, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetACMECertificate(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetACMECertificate").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetAPIKeyByID(ctx context.Context, id string) (database.APIKey, error) {
 	start := time.Now()
 	apiKey, err := m.s.GetAPIKeyByID(ctx, id)
@@ -258,6 +398,13 @@ func (m metricsStore) GetAllTailnetClients(ctx context.Context) ([]database.Tail
 	return r0, r1
 }
 
+func (m metricsStore) GetAppAccessLogInsights(ctx context.Context, arg database.GetAppAccessLogInsightsParams) ([]database.GetAppAccessLogInsightsRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetAppAccessLogInsights(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetAppAccessLogInsights").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetAppSecurityKey(ctx context.Context) (string, error) {
 	start := time.Now()
 	key, err := m.s.GetAppSecurityKey(ctx)
@@ -265,6 +412,34 @@ func (m metricsStore) GetAppSecurityKey(ctx context.Context) (string, error) {
 	return key, err
 }
 
+func (m metricsStore) GetAppSessionInsights(ctx context.Context, arg database.GetAppSessionInsightsParams) ([]database.GetAppSessionInsightsRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetAppSessionInsights(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetAppSessionInsights").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetAuditLogExportCursor(ctx context.Context, sink string) (database.AuditLogExportCursor, error) {
+	start := time.Now()
+	cursor, err := m.s.GetAuditLogExportCursor(ctx, sink)
+	m.queryLatencies.WithLabelValues("GetAuditLogExportCursor").Observe(time.Since(start).Seconds())
+	return cursor, err
+}
+
+func (m metricsStore) GetAuditLogsAfterCursor(ctx context.Context, arg database.GetAuditLogsAfterCursorParams) ([]database.AuditLog, error) {
+	start := time.Now()
+	logs, err := m.s.GetAuditLogsAfterCursor(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetAuditLogsAfterCursor").Observe(time.Since(start).Seconds())
+	return logs, err
+}
+
+func (m metricsStore) GetAuditLogsCreatedBefore(ctx context.Context, arg database.GetAuditLogsCreatedBeforeParams) ([]database.AuditLog, error) {
+	start := time.Now()
+	logs, err := m.s.GetAuditLogsCreatedBefore(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetAuditLogsCreatedBefore").Observe(time.Since(start).Seconds())
+	return logs, err
+}
+
 func (m metricsStore) GetAuditLogsOffset(ctx context.Context, arg database.GetAuditLogsOffsetParams) ([]database.GetAuditLogsOffsetRow, error) {
 	start := time.Now()
 	rows, err := m.s.GetAuditLogsOffset(ctx, arg)
@@ -279,6 +454,20 @@ func (m metricsStore) GetAuthorizationUserRoles(ctx context.Context, userID uuid
 	return row, err
 }
 
+func (m metricsStore) GetCustomRoleByName(ctx context.Context, name string) (database.CustomRole, error) {
+	start := time.Now()
+	role, err := m.s.GetCustomRoleByName(ctx, name)
+	m.queryLatencies.WithLabelValues("GetCustomRoleByName").Observe(time.Since(start).Seconds())
+	return role, err
+}
+
+func (m metricsStore) GetCustomRoles(ctx context.Context) ([]database.CustomRole, error) {
+	start := time.Now()
+	roles, err := m.s.GetCustomRoles(ctx)
+	m.queryLatencies.WithLabelValues("GetCustomRoles").Observe(time.Since(start).Seconds())
+	return roles, err
+}
+
 func (m metricsStore) GetDERPMeshKey(ctx context.Context) (string, error) {
 	start := time.Now()
 	key, err := m.s.GetDERPMeshKey(ctx)
@@ -370,6 +559,13 @@ func (m metricsStore) GetGroupByOrgAndName(ctx context.Context, arg database.Get
 	return group, err
 }
 
+func (m metricsStore) GetGroupMemberUserIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetGroupMemberUserIDs(ctx, groupID)
+	m.queryLatencies.WithLabelValues("GetGroupMemberUserIDs").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]database.User, error) {
 	start := time.Now()
 	users, err := m.s.GetGroupMembers(ctx, groupID)
@@ -447,6 +643,20 @@ func (m metricsStore) GetOAuthSigningKey(ctx context.Context) (string, error) {
 	return r0, r1
 }
 
+func (m metricsStore) GetOrganizationBudgetSettings(ctx context.Context, organizationID uuid.UUID) (database.OrganizationBudgetSettings, error) {
+	start := time.Now()
+	settings, err := m.s.GetOrganizationBudgetSettings(ctx, organizationID)
+	m.queryLatencies.WithLabelValues("GetOrganizationBudgetSettings").Observe(time.Since(start).Seconds())
+	return settings, err
+}
+
+func (m metricsStore) GetOrganizationBudgetUsages(ctx context.Context) ([]database.GetOrganizationBudgetUsagesRow, error) {
+	start := time.Now()
+	usages, err := m.s.GetOrganizationBudgetUsages(ctx)
+	m.queryLatencies.WithLabelValues("GetOrganizationBudgetUsages").Observe(time.Since(start).Seconds())
+	return usages, err
+}
+
 func (m metricsStore) GetOrganizationByID(ctx context.Context, id uuid.UUID) (database.Organization, error) {
 	start := time.Now()
 	organization, err := m.s.GetOrganizationByID(ctx, id)
@@ -468,6 +678,13 @@ func (m metricsStore) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []u
 	return organizations, err
 }
 
+func (m metricsStore) GetOrganizationJITProvisioningSettings(ctx context.Context, organizationID uuid.UUID) (database.OrganizationJITProvisioningSettings, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetOrganizationJITProvisioningSettings(ctx, organizationID)
+	m.queryLatencies.WithLabelValues("GetOrganizationJITProvisioningSettings").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetOrganizationMemberByUserID(ctx context.Context, arg database.GetOrganizationMemberByUserIDParams) (database.OrganizationMember, error) {
 	start := time.Now()
 	member, err := m.s.GetOrganizationMemberByUserID(ctx, arg)
@@ -482,6 +699,27 @@ func (m metricsStore) GetOrganizationMembershipsByUserID(ctx context.Context, us
 	return memberships, err
 }
 
+func (m metricsStore) GetOrganizationProvisionerSettings(ctx context.Context, organizationID uuid.UUID) (database.OrganizationProvisionerSettings, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetOrganizationProvisionerSettings(ctx, organizationID)
+	m.queryLatencies.WithLabelValues("GetOrganizationProvisionerSettings").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetOrganizationScheduleSettings(ctx context.Context, organizationID uuid.UUID) (database.OrganizationScheduleSettings, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetOrganizationScheduleSettings(ctx, organizationID)
+	m.queryLatencies.WithLabelValues("GetOrganizationScheduleSettings").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetOrganizationTemplateVariables(ctx context.Context, organizationID uuid.UUID) ([]database.OrganizationTemplateVariable, error) {
+	start := time.Now()
+	variables, err := m.s.GetOrganizationTemplateVariables(ctx, organizationID)
+	m.queryLatencies.WithLabelValues("GetOrganizationTemplateVariables").Observe(time.Since(start).Seconds())
+	return variables, err
+}
+
 func (m metricsStore) GetOrganizations(ctx context.Context) ([]database.Organization, error) {
 	start := time.Now()
 	organizations, err := m.s.GetOrganizations(ctx)
@@ -503,6 +741,13 @@ func (m metricsStore) GetParameterSchemasByJobID(ctx context.Context, jobID uuid
 	return schemas, err
 }
 
+func (m metricsStore) GetPrebuiltWorkspaces(ctx context.Context, ownerID uuid.UUID) ([]database.GetPrebuiltWorkspacesRow, error) {
+	start := time.Now()
+	workspaces, err := m.s.GetPrebuiltWorkspaces(ctx, ownerID)
+	m.queryLatencies.WithLabelValues("GetPrebuiltWorkspaces").Observe(time.Since(start).Seconds())
+	return workspaces, err
+}
+
 func (m metricsStore) GetPreviousTemplateVersion(ctx context.Context, arg database.GetPreviousTemplateVersionParams) (database.TemplateVersion, error) {
 	start := time.Now()
 	version, err := m.s.GetPreviousTemplateVersion(ctx, arg)
@@ -524,6 +769,13 @@ func (m metricsStore) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (
 	return job, err
 }
 
+func (m metricsStore) GetProvisionerJobConcurrency(ctx context.Context, id uuid.UUID) (database.GetProvisionerJobConcurrencyRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetProvisionerJobConcurrency(ctx, id)
+	m.queryLatencies.WithLabelValues("GetProvisionerJobConcurrency").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.ProvisionerJob, error) {
 	start := time.Now()
 	jobs, err := m.s.GetProvisionerJobsByIDs(ctx, ids)
@@ -545,6 +797,27 @@ func (m metricsStore) GetProvisionerJobsCreatedAfter(ctx context.Context, create
 	return jobs, err
 }
 
+func (m metricsStore) GetProvisionerKeyByID(ctx context.Context, id uuid.UUID) (database.ProvisionerKey, error) {
+	start := time.Now()
+	key, err := m.s.GetProvisionerKeyByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetProvisionerKeyByID").Observe(time.Since(start).Seconds())
+	return key, err
+}
+
+func (m metricsStore) GetProvisionerKeyByOrganizationIDAndName(ctx context.Context, arg database.GetProvisionerKeyByOrganizationIDAndNameParams) (database.ProvisionerKey, error) {
+	start := time.Now()
+	key, err := m.s.GetProvisionerKeyByOrganizationIDAndName(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetProvisionerKeyByOrganizationIDAndName").Observe(time.Since(start).Seconds())
+	return key, err
+}
+
+func (m metricsStore) GetProvisionerKeysByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]database.ProvisionerKey, error) {
+	start := time.Now()
+	keys, err := m.s.GetProvisionerKeysByOrganizationID(ctx, organizationID)
+	m.queryLatencies.WithLabelValues("GetProvisionerKeysByOrganizationID").Observe(time.Since(start).Seconds())
+	return keys, err
+}
+
 func (m metricsStore) GetProvisionerLogsAfterID(ctx context.Context, arg database.GetProvisionerLogsAfterIDParams) ([]database.ProvisionerJobLog, error) {
 	start := time.Now()
 	logs, err := m.s.GetProvisionerLogsAfterID(ctx, arg)
@@ -566,6 +839,20 @@ func (m metricsStore) GetQuotaConsumedForUser(ctx context.Context, ownerID uuid.
 	return consumed, err
 }
 
+func (m metricsStore) GetQuotaConsumedForUserByWorkspace(ctx context.Context, ownerID uuid.UUID) ([]database.GetQuotaConsumedForUserByWorkspaceRow, error) {
+	start := time.Now()
+	rows, err := m.s.GetQuotaConsumedForUserByWorkspace(ctx, ownerID)
+	m.queryLatencies.WithLabelValues("GetQuotaConsumedForUserByWorkspace").Observe(time.Since(start).Seconds())
+	return rows, err
+}
+
+func (m metricsStore) GetQuotaResourceMetadataForUser(ctx context.Context, arg database.GetQuotaResourceMetadataForUserParams) ([]database.WorkspaceResourceMetadatum, error) {
+	start := time.Now()
+	metadata, err := m.s.GetQuotaResourceMetadataForUser(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetQuotaResourceMetadataForUser").Observe(time.Since(start).Seconds())
+	return metadata, err
+}
+
 func (m metricsStore) GetReplicaByID(ctx context.Context, id uuid.UUID) (database.Replica, error) {
 	start := time.Now()
 	replica, err := m.s.GetReplicaByID(ctx, id)
@@ -580,6 +867,41 @@ func (m metricsStore) GetReplicasUpdatedAfter(ctx context.Context, updatedAt tim
 	return replicas, err
 }
 
+func (m metricsStore) GetSavedWorkspaceFilterByUserIDAndName(ctx context.Context, arg database.GetSavedWorkspaceFilterByUserIDAndNameParams) (database.SavedWorkspaceFilter, error) {
+	start := time.Now()
+	filter, err := m.s.GetSavedWorkspaceFilterByUserIDAndName(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetSavedWorkspaceFilterByUserIDAndName").Observe(time.Since(start).Seconds())
+	return filter, err
+}
+
+func (m metricsStore) GetSavedWorkspaceFiltersByUserID(ctx context.Context, userID uuid.UUID) ([]database.SavedWorkspaceFilter, error) {
+	start := time.Now()
+	filters, err := m.s.GetSavedWorkspaceFiltersByUserID(ctx, userID)
+	m.queryLatencies.WithLabelValues("GetSavedWorkspaceFiltersByUserID").Observe(time.Since(start).Seconds())
+	return filters, err
+}
+
+func (m metricsStore) GetScheduleHolidayByID(ctx context.Context, id uuid.UUID) (database.ScheduleHoliday, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetScheduleHolidayByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetScheduleHolidayByID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetScheduleHolidays(ctx context.Context) ([]database.ScheduleHoliday, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetScheduleHolidays(ctx)
+	m.queryLatencies.WithLabelValues("GetScheduleHolidays").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetScheduleInsights(ctx context.Context, arg database.GetScheduleInsightsParams) ([]database.GetScheduleInsightsRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetScheduleInsights(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetScheduleInsights").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetServiceBanner(ctx context.Context) (string, error) {
 	start := time.Now()
 	banner, err := m.s.GetServiceBanner(ctx)
@@ -634,6 +956,20 @@ func (m metricsStore) GetTemplateDailyInsights(ctx context.Context, arg database
 	return r0, r1
 }
 
+func (m metricsStore) GetTemplateDeadlineRecalculationByID(ctx context.Context, id uuid.UUID) (database.TemplateDeadlineRecalculation, error) {
+	start := time.Now()
+	recalculation, err := m.s.GetTemplateDeadlineRecalculationByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetTemplateDeadlineRecalculationByID").Observe(time.Since(start).Seconds())
+	return recalculation, err
+}
+
+func (m metricsStore) GetTemplateDeadlineRecalculationsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]database.TemplateDeadlineRecalculation, error) {
+	start := time.Now()
+	recalculations, err := m.s.GetTemplateDeadlineRecalculationsByTemplateID(ctx, templateID)
+	m.queryLatencies.WithLabelValues("GetTemplateDeadlineRecalculationsByTemplateID").Observe(time.Since(start).Seconds())
+	return recalculations, err
+}
+
 func (m metricsStore) GetTemplateInsights(ctx context.Context, arg database.GetTemplateInsightsParams) (database.GetTemplateInsightsRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetTemplateInsights(ctx, arg)
@@ -648,6 +984,34 @@ func (m metricsStore) GetTemplateParameterInsights(ctx context.Context, arg data
 	return r0, r1
 }
 
+func (m metricsStore) GetTemplateParameterValidation(ctx context.Context, templateID uuid.UUID) (database.TemplateParameterValidation, error) {
+	start := time.Now()
+	validation, err := m.s.GetTemplateParameterValidation(ctx, templateID)
+	m.queryLatencies.WithLabelValues("GetTemplateParameterValidation").Observe(time.Since(start).Seconds())
+	return validation, err
+}
+
+func (m metricsStore) GetTemplatePrebuildPool(ctx context.Context, templateID uuid.UUID) (database.TemplatePrebuildPool, error) {
+	start := time.Now()
+	pool, err := m.s.GetTemplatePrebuildPool(ctx, templateID)
+	m.queryLatencies.WithLabelValues("GetTemplatePrebuildPool").Observe(time.Since(start).Seconds())
+	return pool, err
+}
+
+func (m metricsStore) GetTemplatePrebuildPools(ctx context.Context) ([]database.TemplatePrebuildPool, error) {
+	start := time.Now()
+	pools, err := m.s.GetTemplatePrebuildPools(ctx)
+	m.queryLatencies.WithLabelValues("GetTemplatePrebuildPools").Observe(time.Since(start).Seconds())
+	return pools, err
+}
+
+func (m metricsStore) GetTemplateVersionBuildInsights(ctx context.Context, arg database.GetTemplateVersionBuildInsightsParams) ([]database.GetTemplateVersionBuildInsightsRow, error) {
+	start := time.Now()
+	r0, err := m.s.GetTemplateVersionBuildInsights(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionBuildInsights").Observe(time.Since(start).Seconds())
+	return r0, err
+}
+
 func (m metricsStore) GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (database.TemplateVersion, error) {
 	start := time.Now()
 	version, err := m.s.GetTemplateVersionByID(ctx, id)
@@ -676,6 +1040,27 @@ func (m metricsStore) GetTemplateVersionParameters(ctx context.Context, template
 	return parameters, err
 }
 
+func (m metricsStore) GetTemplateVersionPresets(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionPreset, error) {
+	start := time.Now()
+	presets, err := m.s.GetTemplateVersionPresets(ctx, templateVersionID)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionPresets").Observe(time.Since(start).Seconds())
+	return presets, err
+}
+
+func (m metricsStore) GetTemplateVersionPromotionByID(ctx context.Context, id uuid.UUID) (database.TemplateVersionPromotion, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetTemplateVersionPromotionByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionPromotionByID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetTemplateVersionPromotionsByTemplateID(ctx context.Context, arg database.GetTemplateVersionPromotionsByTemplateIDParams) ([]database.TemplateVersionPromotion, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetTemplateVersionPromotionsByTemplateID(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetTemplateVersionPromotionsByTemplateID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetTemplateVersionVariables(ctx context.Context, templateVersionID uuid.UUID) ([]database.TemplateVersionVariable, error) {
 	start := time.Now()
 	variables, err := m.s.GetTemplateVersionVariables(ctx, templateVersionID)
@@ -711,6 +1096,13 @@ func (m metricsStore) GetTemplates(ctx context.Context) ([]database.Template, er
 	return templates, err
 }
 
+func (m metricsStore) GetTemplatesBySchedulePolicyTemplateID(ctx context.Context, schedulePolicyTemplateID uuid.NullUUID) ([]database.Template, error) {
+	start := time.Now()
+	templates, err := m.s.GetTemplatesBySchedulePolicyTemplateID(ctx, schedulePolicyTemplateID)
+	m.queryLatencies.WithLabelValues("GetTemplatesBySchedulePolicyTemplateID").Observe(time.Since(start).Seconds())
+	return templates, err
+}
+
 func (m metricsStore) GetTemplatesWithFilter(ctx context.Context, arg database.GetTemplatesWithFilterParams) ([]database.Template, error) {
 	start := time.Now()
 	templates, err := m.s.GetTemplatesWithFilter(ctx, arg)
@@ -746,6 +1138,13 @@ func (m metricsStore) GetUserCount(ctx context.Context) (int64, error) {
 	return count, err
 }
 
+func (m metricsStore) GetUserDotfiles(ctx context.Context, userID uuid.UUID) (database.UserDotfile, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetUserDotfiles(ctx, userID)
+	m.queryLatencies.WithLabelValues("GetUserDotfiles").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetUserLatencyInsights(ctx context.Context, arg database.GetUserLatencyInsightsParams) ([]database.GetUserLatencyInsightsRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetUserLatencyInsights(ctx, arg)
@@ -767,6 +1166,27 @@ func (m metricsStore) GetUserLinkByUserIDLoginType(ctx context.Context, arg data
 	return link, err
 }
 
+func (m metricsStore) GetUserQuietHoursExceptions(ctx context.Context, userID uuid.UUID) ([]database.UserQuietHoursException, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetUserQuietHoursExceptions(ctx, userID)
+	m.queryLatencies.WithLabelValues("GetUserQuietHoursExceptions").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetUserSecretByUserIDAndName(ctx context.Context, arg database.GetUserSecretByUserIDAndNameParams) (database.UserSecret, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetUserSecretByUserIDAndName(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetUserSecretByUserIDAndName").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetUserSecretsByUserID(ctx context.Context, userID uuid.UUID) ([]database.UserSecret, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetUserSecretsByUserID(ctx, userID)
+	m.queryLatencies.WithLabelValues("GetUserSecretsByUserID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetUsers(ctx context.Context, arg database.GetUsersParams) ([]database.GetUsersRow, error) {
 	start := time.Now()
 	users, err := m.s.GetUsers(ctx, arg)
@@ -802,6 +1222,13 @@ func (m metricsStore) GetWorkspaceAgentByInstanceID(ctx context.Context, authIns
 	return agent, err
 }
 
+func (m metricsStore) GetWorkspaceAgentDevcontainersByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentDevcontainer, error) {
+	start := time.Now()
+	devcontainers, err := m.s.GetWorkspaceAgentDevcontainersByAgentID(ctx, workspaceAgentID)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAgentDevcontainersByAgentID").Observe(time.Since(start).Seconds())
+	return devcontainers, err
+}
+
 func (m metricsStore) GetWorkspaceAgentLifecycleStateByID(ctx context.Context, id uuid.UUID) (database.GetWorkspaceAgentLifecycleStateByIDRow, error) {
 	start := time.Now()
 	r0, r1 := m.s.GetWorkspaceAgentLifecycleStateByID(ctx, id)
@@ -823,6 +1250,20 @@ func (m metricsStore) GetWorkspaceAgentMetadata(ctx context.Context, workspaceAg
 	return metadata, err
 }
 
+func (m metricsStore) GetWorkspaceAgentScriptsByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentScript, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceAgentScriptsByAgentID(ctx, workspaceAgentID)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAgentScriptsByAgentID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetWorkspaceAgentSecretsByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]database.WorkspaceAgentSecret, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceAgentSecretsByAgentID(ctx, workspaceAgentID)
+	m.queryLatencies.WithLabelValues("GetWorkspaceAgentSecretsByAgentID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetWorkspaceAgentStats(ctx context.Context, createdAt time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
 	start := time.Now()
 	stats, err := m.s.GetWorkspaceAgentStats(ctx, createdAt)
@@ -886,6 +1327,20 @@ func (m metricsStore) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdA
 	return apps, err
 }
 
+func (m metricsStore) GetWorkspaceBatchByID(ctx context.Context, id uuid.UUID) (database.WorkspaceBatch, error) {
+	start := time.Now()
+	batch, err := m.s.GetWorkspaceBatchByID(ctx, id)
+	m.queryLatencies.WithLabelValues("GetWorkspaceBatchByID").Observe(time.Since(start).Seconds())
+	return batch, err
+}
+
+func (m metricsStore) GetWorkspaceBatchWorkspacesByBatchID(ctx context.Context, batchID uuid.UUID) ([]database.GetWorkspaceBatchWorkspacesByBatchIDRow, error) {
+	start := time.Now()
+	workspaces, err := m.s.GetWorkspaceBatchWorkspacesByBatchID(ctx, batchID)
+	m.queryLatencies.WithLabelValues("GetWorkspaceBatchWorkspacesByBatchID").Observe(time.Since(start).Seconds())
+	return workspaces, err
+}
+
 func (m metricsStore) GetWorkspaceBuildByID(ctx context.Context, id uuid.UUID) (database.WorkspaceBuild, error) {
 	start := time.Now()
 	build, err := m.s.GetWorkspaceBuildByID(ctx, id)
@@ -956,6 +1411,20 @@ func (m metricsStore) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspac
 	return workspace, err
 }
 
+func (m metricsStore) GetWorkspaceDeadlineExtensionCount(ctx context.Context, arg database.GetWorkspaceDeadlineExtensionCountParams) (int64, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceDeadlineExtensionCount(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetWorkspaceDeadlineExtensionCount").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) GetWorkspaceNameRedirect(ctx context.Context, arg database.GetWorkspaceNameRedirectParams) (database.WorkspaceNameRedirect, error) {
+	start := time.Now()
+	redirect, err := m.s.GetWorkspaceNameRedirect(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetWorkspaceNameRedirect").Observe(time.Since(start).Seconds())
+	return redirect, err
+}
+
 func (m metricsStore) GetWorkspaceProxies(ctx context.Context) ([]database.WorkspaceProxy, error) {
 	start := time.Now()
 	proxies, err := m.s.GetWorkspaceProxies(ctx)
@@ -1026,6 +1495,13 @@ func (m metricsStore) GetWorkspaceResourcesCreatedAfter(ctx context.Context, cre
 	return resources, err
 }
 
+func (m metricsStore) GetWorkspaceScheduleOverrideByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (database.WorkspaceScheduleOverride, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspaceScheduleOverrideByWorkspaceID(ctx, workspaceID)
+	m.queryLatencies.WithLabelValues("GetWorkspaceScheduleOverrideByWorkspaceID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetWorkspaces(ctx context.Context, arg database.GetWorkspacesParams) ([]database.GetWorkspacesRow, error) {
 	start := time.Now()
 	workspaces, err := m.s.GetWorkspaces(ctx, arg)
@@ -1033,6 +1509,13 @@ func (m metricsStore) GetWorkspaces(ctx context.Context, arg database.GetWorkspa
 	return workspaces, err
 }
 
+func (m metricsStore) GetWorkspacesByIDs(ctx context.Context, ids []uuid.UUID) ([]database.Workspace, error) {
+	start := time.Now()
+	workspaces, err := m.s.GetWorkspacesByIDs(ctx, ids)
+	m.queryLatencies.WithLabelValues("GetWorkspacesByIDs").Observe(time.Since(start).Seconds())
+	return workspaces, err
+}
+
 func (m metricsStore) GetWorkspacesEligibleForTransition(ctx context.Context, now time.Time) ([]database.Workspace, error) {
 	start := time.Now()
 	workspaces, err := m.s.GetWorkspacesEligibleForTransition(ctx, now)
@@ -1040,6 +1523,13 @@ func (m metricsStore) GetWorkspacesEligibleForTransition(ctx context.Context, no
 	return workspaces, err
 }
 
+func (m metricsStore) GetWorkspacesWithImminentAutostop(ctx context.Context, arg database.GetWorkspacesWithImminentAutostopParams) ([]database.GetWorkspacesWithImminentAutostopRow, error) {
+	start := time.Now()
+	r0, r1 := m.s.GetWorkspacesWithImminentAutostop(ctx, arg)
+	m.queryLatencies.WithLabelValues("GetWorkspacesWithImminentAutostop").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) InsertAPIKey(ctx context.Context, arg database.InsertAPIKeyParams) (database.APIKey, error) {
 	start := time.Now()
 	key, err := m.s.InsertAPIKey(ctx, arg)
@@ -1061,6 +1551,13 @@ func (m metricsStore) InsertAuditLog(ctx context.Context, arg database.InsertAud
 	return log, err
 }
 
+func (m metricsStore) InsertCustomRole(ctx context.Context, arg database.InsertCustomRoleParams) (database.CustomRole, error) {
+	start := time.Now()
+	role, err := m.s.InsertCustomRole(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertCustomRole").Observe(time.Since(start).Seconds())
+	return role, err
+}
+
 func (m metricsStore) InsertDERPMeshKey(ctx context.Context, value string) error {
 	start := time.Now()
 	err := m.s.InsertDERPMeshKey(ctx, value)
@@ -1159,6 +1656,13 @@ func (m metricsStore) InsertProvisionerJobLogs(ctx context.Context, arg database
 	return logs, err
 }
 
+func (m metricsStore) InsertProvisionerKey(ctx context.Context, arg database.InsertProvisionerKeyParams) (database.ProvisionerKey, error) {
+	start := time.Now()
+	key, err := m.s.InsertProvisionerKey(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertProvisionerKey").Observe(time.Since(start).Seconds())
+	return key, err
+}
+
 func (m metricsStore) InsertReplica(ctx context.Context, arg database.InsertReplicaParams) (database.Replica, error) {
 	start := time.Now()
 	replica, err := m.s.InsertReplica(ctx, arg)
@@ -1166,6 +1670,20 @@ func (m metricsStore) InsertReplica(ctx context.Context, arg database.InsertRepl
 	return replica, err
 }
 
+func (m metricsStore) InsertSavedWorkspaceFilter(ctx context.Context, arg database.InsertSavedWorkspaceFilterParams) (database.SavedWorkspaceFilter, error) {
+	start := time.Now()
+	filter, err := m.s.InsertSavedWorkspaceFilter(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertSavedWorkspaceFilter").Observe(time.Since(start).Seconds())
+	return filter, err
+}
+
+func (m metricsStore) InsertScheduleHoliday(ctx context.Context, arg database.InsertScheduleHolidayParams) (database.ScheduleHoliday, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertScheduleHoliday(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertScheduleHoliday").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) InsertTemplate(ctx context.Context, arg database.InsertTemplateParams) error {
 	start := time.Now()
 	err := m.s.InsertTemplate(ctx, arg)
@@ -1173,6 +1691,13 @@ func (m metricsStore) InsertTemplate(ctx context.Context, arg database.InsertTem
 	return err
 }
 
+func (m metricsStore) InsertTemplateDeadlineRecalculation(ctx context.Context, arg database.InsertTemplateDeadlineRecalculationParams) (database.TemplateDeadlineRecalculation, error) {
+	start := time.Now()
+	recalculation, err := m.s.InsertTemplateDeadlineRecalculation(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertTemplateDeadlineRecalculation").Observe(time.Since(start).Seconds())
+	return recalculation, err
+}
+
 func (m metricsStore) InsertTemplateVersion(ctx context.Context, arg database.InsertTemplateVersionParams) error {
 	start := time.Now()
 	err := m.s.InsertTemplateVersion(ctx, arg)
@@ -1187,6 +1712,20 @@ func (m metricsStore) InsertTemplateVersionParameter(ctx context.Context, arg da
 	return parameter, err
 }
 
+func (m metricsStore) InsertTemplateVersionPreset(ctx context.Context, arg database.InsertTemplateVersionPresetParams) (database.TemplateVersionPreset, error) {
+	start := time.Now()
+	preset, err := m.s.InsertTemplateVersionPreset(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertTemplateVersionPreset").Observe(time.Since(start).Seconds())
+	return preset, err
+}
+
+func (m metricsStore) InsertTemplateVersionPromotion(ctx context.Context, arg database.InsertTemplateVersionPromotionParams) (database.TemplateVersionPromotion, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertTemplateVersionPromotion(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertTemplateVersionPromotion").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) InsertTemplateVersionVariable(ctx context.Context, arg database.InsertTemplateVersionVariableParams) (database.TemplateVersionVariable, error) {
 	start := time.Now()
 	variable, err := m.s.InsertTemplateVersionVariable(ctx, arg)
@@ -1215,6 +1754,20 @@ func (m metricsStore) InsertUserLink(ctx context.Context, arg database.InsertUse
 	return link, err
 }
 
+func (m metricsStore) InsertUserQuietHoursException(ctx context.Context, arg database.InsertUserQuietHoursExceptionParams) (database.UserQuietHoursException, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertUserQuietHoursException(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertUserQuietHoursException").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) InsertUserSecret(ctx context.Context, arg database.InsertUserSecretParams) (database.UserSecret, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertUserSecret(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertUserSecret").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) InsertWorkspace(ctx context.Context, arg database.InsertWorkspaceParams) (database.Workspace, error) {
 	start := time.Now()
 	workspace, err := m.s.InsertWorkspace(ctx, arg)
@@ -1229,6 +1782,13 @@ func (m metricsStore) InsertWorkspaceAgent(ctx context.Context, arg database.Ins
 	return agent, err
 }
 
+func (m metricsStore) InsertWorkspaceAgentDevcontainer(ctx context.Context, arg database.InsertWorkspaceAgentDevcontainerParams) error {
+	start := time.Now()
+	err := m.s.InsertWorkspaceAgentDevcontainer(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertWorkspaceAgentDevcontainer").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) InsertWorkspaceAgentLogs(ctx context.Context, arg database.InsertWorkspaceAgentLogsParams) ([]database.WorkspaceAgentLog, error) {
 	start := time.Now()
 	r0, r1 := m.s.InsertWorkspaceAgentLogs(ctx, arg)
@@ -1243,6 +1803,20 @@ func (m metricsStore) InsertWorkspaceAgentMetadata(ctx context.Context, arg data
 	return err
 }
 
+func (m metricsStore) InsertWorkspaceAgentScript(ctx context.Context, arg database.InsertWorkspaceAgentScriptParams) error {
+	start := time.Now()
+	r0 := m.s.InsertWorkspaceAgentScript(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertWorkspaceAgentScript").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m metricsStore) InsertWorkspaceAgentSecret(ctx context.Context, arg database.InsertWorkspaceAgentSecretParams) error {
+	start := time.Now()
+	r0 := m.s.InsertWorkspaceAgentSecret(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertWorkspaceAgentSecret").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) InsertWorkspaceAgentStat(ctx context.Context, arg database.InsertWorkspaceAgentStatParams) (database.WorkspaceAgentStat, error) {
 	start := time.Now()
 	stat, err := m.s.InsertWorkspaceAgentStat(ctx, arg)
@@ -1264,6 +1838,13 @@ func (m metricsStore) InsertWorkspaceApp(ctx context.Context, arg database.Inser
 	return app, err
 }
 
+func (m metricsStore) InsertWorkspaceAppAccessLogs(ctx context.Context, arg database.InsertWorkspaceAppAccessLogsParams) error {
+	start := time.Now()
+	r0 := m.s.InsertWorkspaceAppAccessLogs(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertWorkspaceAppAccessLogs").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) InsertWorkspaceAppStats(ctx context.Context, arg database.InsertWorkspaceAppStatsParams) error {
 	start := time.Now()
 	r0 := m.s.InsertWorkspaceAppStats(ctx, arg)
@@ -1271,6 +1852,20 @@ func (m metricsStore) InsertWorkspaceAppStats(ctx context.Context, arg database.
 	return r0
 }
 
+func (m metricsStore) InsertWorkspaceBatch(ctx context.Context, arg database.InsertWorkspaceBatchParams) (database.WorkspaceBatch, error) {
+	start := time.Now()
+	batch, err := m.s.InsertWorkspaceBatch(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertWorkspaceBatch").Observe(time.Since(start).Seconds())
+	return batch, err
+}
+
+func (m metricsStore) InsertWorkspaceBatchWorkspaces(ctx context.Context, arg database.InsertWorkspaceBatchWorkspacesParams) error {
+	start := time.Now()
+	err := m.s.InsertWorkspaceBatchWorkspaces(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertWorkspaceBatchWorkspaces").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) InsertWorkspaceBuild(ctx context.Context, arg database.InsertWorkspaceBuildParams) error {
 	start := time.Now()
 	err := m.s.InsertWorkspaceBuild(ctx, arg)
@@ -1285,6 +1880,13 @@ func (m metricsStore) InsertWorkspaceBuildParameters(ctx context.Context, arg da
 	return err
 }
 
+func (m metricsStore) InsertWorkspaceDeadlineExtension(ctx context.Context, arg database.InsertWorkspaceDeadlineExtensionParams) (database.WorkspaceDeadlineExtension, error) {
+	start := time.Now()
+	r0, r1 := m.s.InsertWorkspaceDeadlineExtension(ctx, arg)
+	m.queryLatencies.WithLabelValues("InsertWorkspaceDeadlineExtension").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) InsertWorkspaceProxy(ctx context.Context, arg database.InsertWorkspaceProxyParams) (database.WorkspaceProxy, error) {
 	start := time.Now()
 	proxy, err := m.s.InsertWorkspaceProxy(ctx, arg)
@@ -1313,6 +1915,13 @@ func (m metricsStore) RegisterWorkspaceProxy(ctx context.Context, arg database.R
 	return proxy, err
 }
 
+func (m metricsStore) RotateWorkspaceProxyToken(ctx context.Context, arg database.RotateWorkspaceProxyTokenParams) (database.WorkspaceProxy, error) {
+	start := time.Now()
+	r0, r1 := m.s.RotateWorkspaceProxyToken(ctx, arg)
+	m.queryLatencies.WithLabelValues("RotateWorkspaceProxyToken").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) TryAcquireLock(ctx context.Context, pgTryAdvisoryXactLock int64) (bool, error) {
 	start := time.Now()
 	ok, err := m.s.TryAcquireLock(ctx, pgTryAdvisoryXactLock)
@@ -1327,6 +1936,13 @@ func (m metricsStore) UpdateAPIKeyByID(ctx context.Context, arg database.UpdateA
 	return err
 }
 
+func (m metricsStore) UpdateCustomRole(ctx context.Context, arg database.UpdateCustomRoleParams) (database.CustomRole, error) {
+	start := time.Now()
+	role, err := m.s.UpdateCustomRole(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateCustomRole").Observe(time.Since(start).Seconds())
+	return role, err
+}
+
 func (m metricsStore) UpdateGitAuthLink(ctx context.Context, arg database.UpdateGitAuthLinkParams) (database.GitAuthLink, error) {
 	start := time.Now()
 	link, err := m.s.UpdateGitAuthLink(ctx, arg)
@@ -1362,6 +1978,20 @@ func (m metricsStore) UpdateMemberRoles(ctx context.Context, arg database.Update
 	return member, err
 }
 
+func (m metricsStore) UpdateOrganizationBudgetAlertedThreshold(ctx context.Context, arg database.UpdateOrganizationBudgetAlertedThresholdParams) error {
+	start := time.Now()
+	err := m.s.UpdateOrganizationBudgetAlertedThreshold(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateOrganizationBudgetAlertedThreshold").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) UpdateProvisionerDaemonCacheStats(ctx context.Context, arg database.UpdateProvisionerDaemonCacheStatsParams) error {
+	start := time.Now()
+	err := m.s.UpdateProvisionerDaemonCacheStats(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateProvisionerDaemonCacheStats").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpdateProvisionerJobByID(ctx context.Context, arg database.UpdateProvisionerJobByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateProvisionerJobByID(ctx, arg)
@@ -1369,6 +1999,13 @@ func (m metricsStore) UpdateProvisionerJobByID(ctx context.Context, arg database
 	return err
 }
 
+func (m metricsStore) UpdateProvisionerJobNotBeforeByID(ctx context.Context, arg database.UpdateProvisionerJobNotBeforeByIDParams) error {
+	start := time.Now()
+	r0 := m.s.UpdateProvisionerJobNotBeforeByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateProvisionerJobNotBeforeByID").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg database.UpdateProvisionerJobWithCancelByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateProvisionerJobWithCancelByID(ctx, arg)
@@ -1383,6 +2020,13 @@ func (m metricsStore) UpdateProvisionerJobWithCompleteByID(ctx context.Context,
 	return err
 }
 
+func (m metricsStore) UpdateProvisionerKeyLastUsedAt(ctx context.Context, arg database.UpdateProvisionerKeyLastUsedAtParams) error {
+	start := time.Now()
+	err := m.s.UpdateProvisionerKeyLastUsedAt(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateProvisionerKeyLastUsedAt").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpdateReplica(ctx context.Context, arg database.UpdateReplicaParams) (database.Replica, error) {
 	start := time.Now()
 	replica, err := m.s.UpdateReplica(ctx, arg)
@@ -1390,6 +2034,20 @@ func (m metricsStore) UpdateReplica(ctx context.Context, arg database.UpdateRepl
 	return replica, err
 }
 
+func (m metricsStore) UpdateSavedWorkspaceFilter(ctx context.Context, arg database.UpdateSavedWorkspaceFilterParams) (database.SavedWorkspaceFilter, error) {
+	start := time.Now()
+	filter, err := m.s.UpdateSavedWorkspaceFilter(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateSavedWorkspaceFilter").Observe(time.Since(start).Seconds())
+	return filter, err
+}
+
+func (m metricsStore) UpdateScheduleHolidayByID(ctx context.Context, arg database.UpdateScheduleHolidayByIDParams) (database.ScheduleHoliday, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpdateScheduleHolidayByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateScheduleHolidayByID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) UpdateTemplateACLByID(ctx context.Context, arg database.UpdateTemplateACLByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateTemplateACLByID(ctx, arg)
@@ -1404,6 +2062,20 @@ func (m metricsStore) UpdateTemplateActiveVersionByID(ctx context.Context, arg d
 	return err
 }
 
+func (m metricsStore) UpdateTemplateDeadlineRecalculationCompletedByID(ctx context.Context, arg database.UpdateTemplateDeadlineRecalculationCompletedByIDParams) error {
+	start := time.Now()
+	err := m.s.UpdateTemplateDeadlineRecalculationCompletedByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTemplateDeadlineRecalculationCompletedByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) UpdateTemplateDeadlineRecalculationProgressByID(ctx context.Context, arg database.UpdateTemplateDeadlineRecalculationProgressByIDParams) error {
+	start := time.Now()
+	err := m.s.UpdateTemplateDeadlineRecalculationProgressByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTemplateDeadlineRecalculationProgressByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpdateTemplateDeletedByID(ctx context.Context, arg database.UpdateTemplateDeletedByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateTemplateDeletedByID(ctx, arg)
@@ -1425,6 +2097,13 @@ func (m metricsStore) UpdateTemplateScheduleByID(ctx context.Context, arg databa
 	return err
 }
 
+func (m metricsStore) UpdateTemplateSchedulePolicyByID(ctx context.Context, arg database.UpdateTemplateSchedulePolicyByIDParams) error {
+	start := time.Now()
+	err := m.s.UpdateTemplateSchedulePolicyByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTemplateSchedulePolicyByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpdateTemplateVersionByID(ctx context.Context, arg database.UpdateTemplateVersionByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateTemplateVersionByID(ctx, arg)
@@ -1446,6 +2125,13 @@ func (m metricsStore) UpdateTemplateVersionGitAuthProvidersByJobID(ctx context.C
 	return err
 }
 
+func (m metricsStore) UpdateTemplateVersionPromotionStatusByID(ctx context.Context, arg database.UpdateTemplateVersionPromotionStatusByIDParams) (database.TemplateVersionPromotion, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpdateTemplateVersionPromotionStatusByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateTemplateVersionPromotionStatusByID").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) UpdateUserDeletedByID(ctx context.Context, arg database.UpdateUserDeletedByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateUserDeletedByID(ctx, arg)
@@ -1509,6 +2195,13 @@ func (m metricsStore) UpdateUserRoles(ctx context.Context, arg database.UpdateUs
 	return user, err
 }
 
+func (m metricsStore) UpdateUserSecret(ctx context.Context, arg database.UpdateUserSecretParams) (database.UserSecret, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpdateUserSecret(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateUserSecret").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) UpdateUserStatus(ctx context.Context, arg database.UpdateUserStatusParams) (database.User, error) {
 	start := time.Now()
 	user, err := m.s.UpdateUserStatus(ctx, arg)
@@ -1551,6 +2244,13 @@ func (m metricsStore) UpdateWorkspaceAgentMetadata(ctx context.Context, arg data
 	return err
 }
 
+func (m metricsStore) UpdateWorkspaceAgentScriptStatus(ctx context.Context, arg database.UpdateWorkspaceAgentScriptStatusParams) error {
+	start := time.Now()
+	r0 := m.s.UpdateWorkspaceAgentScriptStatus(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateWorkspaceAgentScriptStatus").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) UpdateWorkspaceAgentStartupByID(ctx context.Context, arg database.UpdateWorkspaceAgentStartupByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateWorkspaceAgentStartupByID(ctx, arg)
@@ -1572,6 +2272,27 @@ func (m metricsStore) UpdateWorkspaceAutostart(ctx context.Context, arg database
 	return err
 }
 
+func (m metricsStore) UpdateWorkspaceBatchCompletedByID(ctx context.Context, arg database.UpdateWorkspaceBatchCompletedByIDParams) error {
+	start := time.Now()
+	err := m.s.UpdateWorkspaceBatchCompletedByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateWorkspaceBatchCompletedByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) UpdateWorkspaceBatchUpdatedAtByID(ctx context.Context, arg database.UpdateWorkspaceBatchUpdatedAtByIDParams) error {
+	start := time.Now()
+	err := m.s.UpdateWorkspaceBatchUpdatedAtByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateWorkspaceBatchUpdatedAtByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
+func (m metricsStore) UpdateWorkspaceBatchWorkspace(ctx context.Context, arg database.UpdateWorkspaceBatchWorkspaceParams) error {
+	start := time.Now()
+	err := m.s.UpdateWorkspaceBatchWorkspace(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateWorkspaceBatchWorkspace").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpdateWorkspaceBuildByID(ctx context.Context, arg database.UpdateWorkspaceBuildByIDParams) error {
 	start := time.Now()
 	err := m.s.UpdateWorkspaceBuildByID(ctx, arg)
@@ -1593,6 +2314,13 @@ func (m metricsStore) UpdateWorkspaceDeletedByID(ctx context.Context, arg databa
 	return err
 }
 
+func (m metricsStore) UpdateWorkspaceGroupACLByID(ctx context.Context, arg database.UpdateWorkspaceGroupACLByIDParams) error {
+	start := time.Now()
+	err := m.s.UpdateWorkspaceGroupACLByID(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpdateWorkspaceGroupACLByID").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpdateWorkspaceLastUsedAt(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	start := time.Now()
 	err := m.s.UpdateWorkspaceLastUsedAt(ctx, arg)
@@ -1635,6 +2363,13 @@ func (m metricsStore) UpdateWorkspacesDeletingAtByTemplateID(ctx context.Context
 	return r0
 }
 
+func (m metricsStore) UpsertACMECertificate(ctx context.Context, arg database.UpsertACMECertificateParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertACMECertificate(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertACMECertificate").Observe(time.Since(start).Seconds())
+	return r0
+}
+
 func (m metricsStore) UpsertAppSecurityKey(ctx context.Context, value string) error {
 	start := time.Now()
 	r0 := m.s.UpsertAppSecurityKey(ctx, value)
@@ -1642,6 +2377,13 @@ func (m metricsStore) UpsertAppSecurityKey(ctx context.Context, value string) er
 	return r0
 }
 
+func (m metricsStore) UpsertAuditLogExportCursor(ctx context.Context, arg database.UpsertAuditLogExportCursorParams) error {
+	start := time.Now()
+	err := m.s.UpsertAuditLogExportCursor(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertAuditLogExportCursor").Observe(time.Since(start).Seconds())
+	return err
+}
+
 func (m metricsStore) UpsertDefaultProxy(ctx context.Context, arg database.UpsertDefaultProxyParams) error {
 	start := time.Now()
 	r0 := m.s.UpsertDefaultProxy(ctx, arg)
@@ -1670,6 +2412,41 @@ func (m metricsStore) UpsertOAuthSigningKey(ctx context.Context, value string) e
 	return r0
 }
 
+func (m metricsStore) UpsertOrganizationBudgetSettings(ctx context.Context, arg database.UpsertOrganizationBudgetSettingsParams) (database.OrganizationBudgetSettings, error) {
+	start := time.Now()
+	settings, err := m.s.UpsertOrganizationBudgetSettings(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertOrganizationBudgetSettings").Observe(time.Since(start).Seconds())
+	return settings, err
+}
+
+func (m metricsStore) UpsertOrganizationJITProvisioningSettings(ctx context.Context, arg database.UpsertOrganizationJITProvisioningSettingsParams) (database.OrganizationJITProvisioningSettings, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpsertOrganizationJITProvisioningSettings(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertOrganizationJITProvisioningSettings").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) UpsertOrganizationProvisionerSettings(ctx context.Context, arg database.UpsertOrganizationProvisionerSettingsParams) (database.OrganizationProvisionerSettings, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpsertOrganizationProvisionerSettings(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertOrganizationProvisionerSettings").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) UpsertOrganizationScheduleSettings(ctx context.Context, arg database.UpsertOrganizationScheduleSettingsParams) (database.OrganizationScheduleSettings, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpsertOrganizationScheduleSettings(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertOrganizationScheduleSettings").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) UpsertOrganizationTemplateVariable(ctx context.Context, arg database.UpsertOrganizationTemplateVariableParams) (database.OrganizationTemplateVariable, error) {
+	start := time.Now()
+	variable, err := m.s.UpsertOrganizationTemplateVariable(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertOrganizationTemplateVariable").Observe(time.Since(start).Seconds())
+	return variable, err
+}
+
 func (m metricsStore) UpsertServiceBanner(ctx context.Context, value string) error {
 	start := time.Now()
 	r0 := m.s.UpsertServiceBanner(ctx, value)
@@ -1695,6 +2472,48 @@ func (m metricsStore) UpsertTailnetCoordinator(ctx context.Context, id uuid.UUID
 	return m.s.UpsertTailnetCoordinator(ctx, id)
 }
 
+func (m metricsStore) UpsertTemplateParameterValidation(ctx context.Context, arg database.UpsertTemplateParameterValidationParams) (database.TemplateParameterValidation, error) {
+	start := time.Now()
+	validation, err := m.s.UpsertTemplateParameterValidation(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertTemplateParameterValidation").Observe(time.Since(start).Seconds())
+	return validation, err
+}
+
+func (m metricsStore) UpsertTemplatePrebuildPool(ctx context.Context, arg database.UpsertTemplatePrebuildPoolParams) (database.TemplatePrebuildPool, error) {
+	start := time.Now()
+	pool, err := m.s.UpsertTemplatePrebuildPool(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertTemplatePrebuildPool").Observe(time.Since(start).Seconds())
+	return pool, err
+}
+
+func (m metricsStore) UpsertUserDotfiles(ctx context.Context, arg database.UpsertUserDotfilesParams) (database.UserDotfile, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpsertUserDotfiles(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertUserDotfiles").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
+func (m metricsStore) UpsertWorkspaceAgentPluginMetadata(ctx context.Context, arg database.UpsertWorkspaceAgentPluginMetadataParams) error {
+	start := time.Now()
+	r0 := m.s.UpsertWorkspaceAgentPluginMetadata(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertWorkspaceAgentPluginMetadata").Observe(time.Since(start).Seconds())
+	return r0
+}
+
+func (m metricsStore) UpsertWorkspaceNameRedirect(ctx context.Context, arg database.UpsertWorkspaceNameRedirectParams) (database.WorkspaceNameRedirect, error) {
+	start := time.Now()
+	redirect, err := m.s.UpsertWorkspaceNameRedirect(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertWorkspaceNameRedirect").Observe(time.Since(start).Seconds())
+	return redirect, err
+}
+
+func (m metricsStore) UpsertWorkspaceScheduleOverride(ctx context.Context, arg database.UpsertWorkspaceScheduleOverrideParams) (database.WorkspaceScheduleOverride, error) {
+	start := time.Now()
+	r0, r1 := m.s.UpsertWorkspaceScheduleOverride(ctx, arg)
+	m.queryLatencies.WithLabelValues("UpsertWorkspaceScheduleOverride").Observe(time.Since(start).Seconds())
+	return r0, r1
+}
+
 func (m metricsStore) GetAuthorizedTemplates(ctx context.Context, arg database.GetTemplatesWithFilterParams, prepared rbac.PreparedAuthorized) ([]database.Template, error) {
 	start := time.Now()
 	templates, err := m.s.GetAuthorizedTemplates(ctx, arg, prepared)
@@ -68,6 +68,66 @@ func (mr *MockStoreMockRecorder) AcquireProvisionerJob(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireProvisionerJob", reflect.TypeOf((*MockStore)(nil).AcquireProvisionerJob), arg0, arg1)
 }
 
+// AcquireTemplateDeadlineRecalculation mocks base method.
+func (m *MockStore) AcquireTemplateDeadlineRecalculation(arg0 context.Context, arg1 database.AcquireTemplateDeadlineRecalculationParams) (database.TemplateDeadlineRecalculation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireTemplateDeadlineRecalculation", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateDeadlineRecalculation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireTemplateDeadlineRecalculation indicates an expected call of AcquireTemplateDeadlineRecalculation.
+func (mr *MockStoreMockRecorder) AcquireTemplateDeadlineRecalculation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTemplateDeadlineRecalculation", reflect.TypeOf((*MockStore)(nil).AcquireTemplateDeadlineRecalculation), arg0, arg1)
+}
+
+// AcquireWorkspaceBatch mocks base method.
+func (m *MockStore) AcquireWorkspaceBatch(arg0 context.Context, arg1 database.AcquireWorkspaceBatchParams) (database.WorkspaceBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireWorkspaceBatch", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireWorkspaceBatch indicates an expected call of AcquireWorkspaceBatch.
+func (mr *MockStoreMockRecorder) AcquireWorkspaceBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireWorkspaceBatch", reflect.TypeOf((*MockStore)(nil).AcquireWorkspaceBatch), arg0, arg1)
+}
+
+// ApproveTemplateVersionPromotion mocks base method.
+func (m *MockStore) ApproveTemplateVersionPromotion(arg0 context.Context, arg1 database.ApproveTemplateVersionPromotionParams) (database.TemplateVersionPromotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApproveTemplateVersionPromotion", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionPromotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApproveTemplateVersionPromotion indicates an expected call of ApproveTemplateVersionPromotion.
+func (mr *MockStoreMockRecorder) ApproveTemplateVersionPromotion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveTemplateVersionPromotion", reflect.TypeOf((*MockStore)(nil).ApproveTemplateVersionPromotion), arg0, arg1)
+}
+
+// ClaimPrebuiltWorkspace mocks base method.
+func (m *MockStore) ClaimPrebuiltWorkspace(arg0 context.Context, arg1 database.ClaimPrebuiltWorkspaceParams) (database.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPrebuiltWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(database.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPrebuiltWorkspace indicates an expected call of ClaimPrebuiltWorkspace.
+func (mr *MockStoreMockRecorder) ClaimPrebuiltWorkspace(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPrebuiltWorkspace", reflect.TypeOf((*MockStore)(nil).ClaimPrebuiltWorkspace), arg0, arg1)
+}
+
 // CleanTailnetCoordinators mocks base method.
 func (m *MockStore) CleanTailnetCoordinators(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -124,6 +184,20 @@ func (mr *MockStoreMockRecorder) DeleteApplicationConnectAPIKeysByUserID(arg0, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteApplicationConnectAPIKeysByUserID", reflect.TypeOf((*MockStore)(nil).DeleteApplicationConnectAPIKeysByUserID), arg0, arg1)
 }
 
+// DeleteAuditLogsByIDs mocks base method.
+func (m *MockStore) DeleteAuditLogsByIDs(arg0 context.Context, arg1 []uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAuditLogsByIDs", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAuditLogsByIDs indicates an expected call of DeleteAuditLogsByIDs.
+func (mr *MockStoreMockRecorder) DeleteAuditLogsByIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAuditLogsByIDs", reflect.TypeOf((*MockStore)(nil).DeleteAuditLogsByIDs), arg0, arg1)
+}
+
 // DeleteCoordinator mocks base method.
 func (m *MockStore) DeleteCoordinator(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -138,6 +212,34 @@ func (mr *MockStoreMockRecorder) DeleteCoordinator(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCoordinator", reflect.TypeOf((*MockStore)(nil).DeleteCoordinator), arg0, arg1)
 }
 
+// DeleteCustomRole mocks base method.
+func (m *MockStore) DeleteCustomRole(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCustomRole", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCustomRole indicates an expected call of DeleteCustomRole.
+func (mr *MockStoreMockRecorder) DeleteCustomRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCustomRole", reflect.TypeOf((*MockStore)(nil).DeleteCustomRole), arg0, arg1)
+}
+
+// DeleteExpiredWorkspaceNameRedirects mocks base method.
+func (m *MockStore) DeleteExpiredWorkspaceNameRedirects(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredWorkspaceNameRedirects", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredWorkspaceNameRedirects indicates an expected call of DeleteExpiredWorkspaceNameRedirects.
+func (mr *MockStoreMockRecorder) DeleteExpiredWorkspaceNameRedirects(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredWorkspaceNameRedirects", reflect.TypeOf((*MockStore)(nil).DeleteExpiredWorkspaceNameRedirects), arg0)
+}
+
 // DeleteGitSSHKey mocks base method.
 func (m *MockStore) DeleteGitSSHKey(arg0 context.Context, arg1 uuid.UUID) error {
 	m.ctrl.T.Helper()
@@ -209,6 +311,20 @@ func (mr *MockStoreMockRecorder) DeleteLicense(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLicense", reflect.TypeOf((*MockStore)(nil).DeleteLicense), arg0, arg1)
 }
 
+// DeleteOldTemplateDeadlineRecalculations mocks base method.
+func (m *MockStore) DeleteOldTemplateDeadlineRecalculations(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOldTemplateDeadlineRecalculations", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOldTemplateDeadlineRecalculations indicates an expected call of DeleteOldTemplateDeadlineRecalculations.
+func (mr *MockStoreMockRecorder) DeleteOldTemplateDeadlineRecalculations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldTemplateDeadlineRecalculations", reflect.TypeOf((*MockStore)(nil).DeleteOldTemplateDeadlineRecalculations), arg0, arg1)
+}
+
 // DeleteOldWorkspaceAgentLogs mocks base method.
 func (m *MockStore) DeleteOldWorkspaceAgentLogs(arg0 context.Context) error {
 	m.ctrl.T.Helper()
@@ -237,6 +353,62 @@ func (mr *MockStoreMockRecorder) DeleteOldWorkspaceAgentStats(arg0 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldWorkspaceAgentStats", reflect.TypeOf((*MockStore)(nil).DeleteOldWorkspaceAgentStats), arg0)
 }
 
+// DeleteOldWorkspaceAppAccessLogs mocks base method.
+func (m *MockStore) DeleteOldWorkspaceAppAccessLogs(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOldWorkspaceAppAccessLogs", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOldWorkspaceAppAccessLogs indicates an expected call of DeleteOldWorkspaceAppAccessLogs.
+func (mr *MockStoreMockRecorder) DeleteOldWorkspaceAppAccessLogs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldWorkspaceAppAccessLogs", reflect.TypeOf((*MockStore)(nil).DeleteOldWorkspaceAppAccessLogs), arg0)
+}
+
+// DeleteOldWorkspaceBatches mocks base method.
+func (m *MockStore) DeleteOldWorkspaceBatches(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOldWorkspaceBatches", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOldWorkspaceBatches indicates an expected call of DeleteOldWorkspaceBatches.
+func (mr *MockStoreMockRecorder) DeleteOldWorkspaceBatches(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOldWorkspaceBatches", reflect.TypeOf((*MockStore)(nil).DeleteOldWorkspaceBatches), arg0, arg1)
+}
+
+// DeleteOrganizationTemplateVariable mocks base method.
+func (m *MockStore) DeleteOrganizationTemplateVariable(arg0 context.Context, arg1 database.DeleteOrganizationTemplateVariableParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrganizationTemplateVariable", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrganizationTemplateVariable indicates an expected call of DeleteOrganizationTemplateVariable.
+func (mr *MockStoreMockRecorder) DeleteOrganizationTemplateVariable(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganizationTemplateVariable", reflect.TypeOf((*MockStore)(nil).DeleteOrganizationTemplateVariable), arg0, arg1)
+}
+
+// DeleteProvisionerKey mocks base method.
+func (m *MockStore) DeleteProvisionerKey(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProvisionerKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProvisionerKey indicates an expected call of DeleteProvisionerKey.
+func (mr *MockStoreMockRecorder) DeleteProvisionerKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProvisionerKey", reflect.TypeOf((*MockStore)(nil).DeleteProvisionerKey), arg0, arg1)
+}
+
 // DeleteReplicasUpdatedBefore mocks base method.
 func (m *MockStore) DeleteReplicasUpdatedBefore(arg0 context.Context, arg1 time.Time) error {
 	m.ctrl.T.Helper()
@@ -251,6 +423,34 @@ func (mr *MockStoreMockRecorder) DeleteReplicasUpdatedBefore(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReplicasUpdatedBefore", reflect.TypeOf((*MockStore)(nil).DeleteReplicasUpdatedBefore), arg0, arg1)
 }
 
+// DeleteSavedWorkspaceFilter mocks base method.
+func (m *MockStore) DeleteSavedWorkspaceFilter(arg0 context.Context, arg1 database.DeleteSavedWorkspaceFilterParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSavedWorkspaceFilter", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSavedWorkspaceFilter indicates an expected call of DeleteSavedWorkspaceFilter.
+func (mr *MockStoreMockRecorder) DeleteSavedWorkspaceFilter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSavedWorkspaceFilter", reflect.TypeOf((*MockStore)(nil).DeleteSavedWorkspaceFilter), arg0, arg1)
+}
+
+// DeleteScheduleHolidayByID mocks base method.
+func (m *MockStore) DeleteScheduleHolidayByID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteScheduleHolidayByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteScheduleHolidayByID indicates an expected call of DeleteScheduleHolidayByID.
+func (mr *MockStoreMockRecorder) DeleteScheduleHolidayByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteScheduleHolidayByID", reflect.TypeOf((*MockStore)(nil).DeleteScheduleHolidayByID), arg0, arg1)
+}
+
 // DeleteTailnetAgent mocks base method.
 func (m *MockStore) DeleteTailnetAgent(arg0 context.Context, arg1 database.DeleteTailnetAgentParams) (database.DeleteTailnetAgentRow, error) {
 	m.ctrl.T.Helper()
@@ -281,6 +481,91 @@ func (mr *MockStoreMockRecorder) DeleteTailnetClient(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTailnetClient", reflect.TypeOf((*MockStore)(nil).DeleteTailnetClient), arg0, arg1)
 }
 
+// DeleteUserDotfiles mocks base method.
+func (m *MockStore) DeleteUserDotfiles(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserDotfiles", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserDotfiles indicates an expected call of DeleteUserDotfiles.
+func (mr *MockStoreMockRecorder) DeleteUserDotfiles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserDotfiles", reflect.TypeOf((*MockStore)(nil).DeleteUserDotfiles), arg0, arg1)
+}
+
+// DeleteUserQuietHoursException mocks base method.
+func (m *MockStore) DeleteUserQuietHoursException(arg0 context.Context, arg1 database.DeleteUserQuietHoursExceptionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserQuietHoursException", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserQuietHoursException indicates an expected call of DeleteUserQuietHoursException.
+func (mr *MockStoreMockRecorder) DeleteUserQuietHoursException(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserQuietHoursException", reflect.TypeOf((*MockStore)(nil).DeleteUserQuietHoursException), arg0, arg1)
+}
+
+// DeleteUserSecret mocks base method.
+func (m *MockStore) DeleteUserSecret(arg0 context.Context, arg1 database.DeleteUserSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserSecret indicates an expected call of DeleteUserSecret.
+func (mr *MockStoreMockRecorder) DeleteUserSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSecret", reflect.TypeOf((*MockStore)(nil).DeleteUserSecret), arg0, arg1)
+}
+
+// DeleteWorkspaceAgentPluginMetadata mocks base method.
+func (m *MockStore) DeleteWorkspaceAgentPluginMetadata(arg0 context.Context, arg1 database.DeleteWorkspaceAgentPluginMetadataParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspaceAgentPluginMetadata", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkspaceAgentPluginMetadata indicates an expected call of DeleteWorkspaceAgentPluginMetadata.
+func (mr *MockStoreMockRecorder) DeleteWorkspaceAgentPluginMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspaceAgentPluginMetadata", reflect.TypeOf((*MockStore)(nil).DeleteWorkspaceAgentPluginMetadata), arg0, arg1)
+}
+
+// DeleteWorkspaceScheduleOverrideByWorkspaceID mocks base method.
+func (m *MockStore) DeleteWorkspaceScheduleOverrideByWorkspaceID(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWorkspaceScheduleOverrideByWorkspaceID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWorkspaceScheduleOverrideByWorkspaceID indicates an expected call of DeleteWorkspaceScheduleOverrideByWorkspaceID.
+func (mr *MockStoreMockRecorder) DeleteWorkspaceScheduleOverrideByWorkspaceID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWorkspaceScheduleOverrideByWorkspaceID", reflect.TypeOf((*MockStore)(nil).DeleteWorkspaceScheduleOverrideByWorkspaceID), arg0, arg1)
+}
+
+// GetACMECertificate mocks base method.
+func (m *MockStore) GetACMECertificate(arg0 context.Context, arg1 database.GetACMECertificateParams) (database.ACMECertificate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetACMECertificate", arg0, arg1)
+	ret0, _ := ret[0].(database.ACMECertificate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetACMECertificate indicates an expected call of GetACMECertificate.
+func (mr *MockStoreMockRecorder) GetACMECertificate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetACMECertificate", reflect.TypeOf((*MockStore)(nil).GetACMECertificate), arg0, arg1)
+}
+
 // GetAPIKeyByID mocks base method.
 func (m *MockStore) GetAPIKeyByID(arg0 context.Context, arg1 string) (database.APIKey, error) {
 	m.ctrl.T.Helper()
@@ -416,6 +701,21 @@ func (mr *MockStoreMockRecorder) GetAllTailnetClients(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTailnetClients", reflect.TypeOf((*MockStore)(nil).GetAllTailnetClients), arg0)
 }
 
+// GetAppAccessLogInsights mocks base method.
+func (m *MockStore) GetAppAccessLogInsights(arg0 context.Context, arg1 database.GetAppAccessLogInsightsParams) ([]database.GetAppAccessLogInsightsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppAccessLogInsights", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetAppAccessLogInsightsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppAccessLogInsights indicates an expected call of GetAppAccessLogInsights.
+func (mr *MockStoreMockRecorder) GetAppAccessLogInsights(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppAccessLogInsights", reflect.TypeOf((*MockStore)(nil).GetAppAccessLogInsights), arg0, arg1)
+}
+
 // GetAppSecurityKey mocks base method.
 func (m *MockStore) GetAppSecurityKey(arg0 context.Context) (string, error) {
 	m.ctrl.T.Helper()
@@ -431,6 +731,66 @@ func (mr *MockStoreMockRecorder) GetAppSecurityKey(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppSecurityKey", reflect.TypeOf((*MockStore)(nil).GetAppSecurityKey), arg0)
 }
 
+// GetAppSessionInsights mocks base method.
+func (m *MockStore) GetAppSessionInsights(arg0 context.Context, arg1 database.GetAppSessionInsightsParams) ([]database.GetAppSessionInsightsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppSessionInsights", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetAppSessionInsightsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppSessionInsights indicates an expected call of GetAppSessionInsights.
+func (mr *MockStoreMockRecorder) GetAppSessionInsights(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppSessionInsights", reflect.TypeOf((*MockStore)(nil).GetAppSessionInsights), arg0, arg1)
+}
+
+// GetAuditLogExportCursor mocks base method.
+func (m *MockStore) GetAuditLogExportCursor(arg0 context.Context, arg1 string) (database.AuditLogExportCursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogExportCursor", arg0, arg1)
+	ret0, _ := ret[0].(database.AuditLogExportCursor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogExportCursor indicates an expected call of GetAuditLogExportCursor.
+func (mr *MockStoreMockRecorder) GetAuditLogExportCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogExportCursor", reflect.TypeOf((*MockStore)(nil).GetAuditLogExportCursor), arg0, arg1)
+}
+
+// GetAuditLogsAfterCursor mocks base method.
+func (m *MockStore) GetAuditLogsAfterCursor(arg0 context.Context, arg1 database.GetAuditLogsAfterCursorParams) ([]database.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogsAfterCursor", arg0, arg1)
+	ret0, _ := ret[0].([]database.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogsAfterCursor indicates an expected call of GetAuditLogsAfterCursor.
+func (mr *MockStoreMockRecorder) GetAuditLogsAfterCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogsAfterCursor", reflect.TypeOf((*MockStore)(nil).GetAuditLogsAfterCursor), arg0, arg1)
+}
+
+// GetAuditLogsCreatedBefore mocks base method.
+func (m *MockStore) GetAuditLogsCreatedBefore(arg0 context.Context, arg1 database.GetAuditLogsCreatedBeforeParams) ([]database.AuditLog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditLogsCreatedBefore", arg0, arg1)
+	ret0, _ := ret[0].([]database.AuditLog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditLogsCreatedBefore indicates an expected call of GetAuditLogsCreatedBefore.
+func (mr *MockStoreMockRecorder) GetAuditLogsCreatedBefore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditLogsCreatedBefore", reflect.TypeOf((*MockStore)(nil).GetAuditLogsCreatedBefore), arg0, arg1)
+}
+
 // GetAuditLogsOffset mocks base method.
 func (m *MockStore) GetAuditLogsOffset(arg0 context.Context, arg1 database.GetAuditLogsOffsetParams) ([]database.GetAuditLogsOffsetRow, error) {
 	m.ctrl.T.Helper()
@@ -506,6 +866,36 @@ func (mr *MockStoreMockRecorder) GetAuthorizedWorkspaces(arg0, arg1, arg2 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthorizedWorkspaces", reflect.TypeOf((*MockStore)(nil).GetAuthorizedWorkspaces), arg0, arg1, arg2)
 }
 
+// GetCustomRoleByName mocks base method.
+func (m *MockStore) GetCustomRoleByName(arg0 context.Context, arg1 string) (database.CustomRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomRoleByName", arg0, arg1)
+	ret0, _ := ret[0].(database.CustomRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomRoleByName indicates an expected call of GetCustomRoleByName.
+func (mr *MockStoreMockRecorder) GetCustomRoleByName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomRoleByName", reflect.TypeOf((*MockStore)(nil).GetCustomRoleByName), arg0, arg1)
+}
+
+// GetCustomRoles mocks base method.
+func (m *MockStore) GetCustomRoles(arg0 context.Context) ([]database.CustomRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomRoles", arg0)
+	ret0, _ := ret[0].([]database.CustomRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomRoles indicates an expected call of GetCustomRoles.
+func (mr *MockStoreMockRecorder) GetCustomRoles(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomRoles", reflect.TypeOf((*MockStore)(nil).GetCustomRoles), arg0)
+}
+
 // GetDERPMeshKey mocks base method.
 func (m *MockStore) GetDERPMeshKey(arg0 context.Context) (string, error) {
 	m.ctrl.T.Helper()
@@ -701,6 +1091,21 @@ func (mr *MockStoreMockRecorder) GetGroupByOrgAndName(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupByOrgAndName", reflect.TypeOf((*MockStore)(nil).GetGroupByOrgAndName), arg0, arg1)
 }
 
+// GetGroupMemberUserIDs mocks base method.
+func (m *MockStore) GetGroupMemberUserIDs(arg0 context.Context, arg1 uuid.UUID) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGroupMemberUserIDs", arg0, arg1)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGroupMemberUserIDs indicates an expected call of GetGroupMemberUserIDs.
+func (mr *MockStoreMockRecorder) GetGroupMemberUserIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGroupMemberUserIDs", reflect.TypeOf((*MockStore)(nil).GetGroupMemberUserIDs), arg0, arg1)
+}
+
 // GetGroupMembers mocks base method.
 func (m *MockStore) GetGroupMembers(arg0 context.Context, arg1 uuid.UUID) ([]database.User, error) {
 	m.ctrl.T.Helper()
@@ -866,6 +1271,36 @@ func (mr *MockStoreMockRecorder) GetOAuthSigningKey(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOAuthSigningKey", reflect.TypeOf((*MockStore)(nil).GetOAuthSigningKey), arg0)
 }
 
+// GetOrganizationBudgetSettings mocks base method.
+func (m *MockStore) GetOrganizationBudgetSettings(arg0 context.Context, arg1 uuid.UUID) (database.OrganizationBudgetSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationBudgetSettings", arg0, arg1)
+	ret0, _ := ret[0].(database.OrganizationBudgetSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationBudgetSettings indicates an expected call of GetOrganizationBudgetSettings.
+func (mr *MockStoreMockRecorder) GetOrganizationBudgetSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationBudgetSettings", reflect.TypeOf((*MockStore)(nil).GetOrganizationBudgetSettings), arg0, arg1)
+}
+
+// GetOrganizationBudgetUsages mocks base method.
+func (m *MockStore) GetOrganizationBudgetUsages(arg0 context.Context) ([]database.GetOrganizationBudgetUsagesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationBudgetUsages", arg0)
+	ret0, _ := ret[0].([]database.GetOrganizationBudgetUsagesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationBudgetUsages indicates an expected call of GetOrganizationBudgetUsages.
+func (mr *MockStoreMockRecorder) GetOrganizationBudgetUsages(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationBudgetUsages", reflect.TypeOf((*MockStore)(nil).GetOrganizationBudgetUsages), arg0)
+}
+
 // GetOrganizationByID mocks base method.
 func (m *MockStore) GetOrganizationByID(arg0 context.Context, arg1 uuid.UUID) (database.Organization, error) {
 	m.ctrl.T.Helper()
@@ -911,6 +1346,21 @@ func (mr *MockStoreMockRecorder) GetOrganizationIDsByMemberIDs(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationIDsByMemberIDs", reflect.TypeOf((*MockStore)(nil).GetOrganizationIDsByMemberIDs), arg0, arg1)
 }
 
+// GetOrganizationJITProvisioningSettings mocks base method.
+func (m *MockStore) GetOrganizationJITProvisioningSettings(arg0 context.Context, arg1 uuid.UUID) (database.OrganizationJITProvisioningSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationJITProvisioningSettings", arg0, arg1)
+	ret0, _ := ret[0].(database.OrganizationJITProvisioningSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationJITProvisioningSettings indicates an expected call of GetOrganizationJITProvisioningSettings.
+func (mr *MockStoreMockRecorder) GetOrganizationJITProvisioningSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationJITProvisioningSettings", reflect.TypeOf((*MockStore)(nil).GetOrganizationJITProvisioningSettings), arg0, arg1)
+}
+
 // GetOrganizationMemberByUserID mocks base method.
 func (m *MockStore) GetOrganizationMemberByUserID(arg0 context.Context, arg1 database.GetOrganizationMemberByUserIDParams) (database.OrganizationMember, error) {
 	m.ctrl.T.Helper()
@@ -941,19 +1391,64 @@ func (mr *MockStoreMockRecorder) GetOrganizationMembershipsByUserID(arg0, arg1 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationMembershipsByUserID", reflect.TypeOf((*MockStore)(nil).GetOrganizationMembershipsByUserID), arg0, arg1)
 }
 
-// GetOrganizations mocks base method.
-func (m *MockStore) GetOrganizations(arg0 context.Context) ([]database.Organization, error) {
+// GetOrganizationProvisionerSettings mocks base method.
+func (m *MockStore) GetOrganizationProvisionerSettings(arg0 context.Context, arg1 uuid.UUID) (database.OrganizationProvisionerSettings, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrganizations", arg0)
-	ret0, _ := ret[0].([]database.Organization)
+	ret := m.ctrl.Call(m, "GetOrganizationProvisionerSettings", arg0, arg1)
+	ret0, _ := ret[0].(database.OrganizationProvisionerSettings)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOrganizations indicates an expected call of GetOrganizations.
-func (mr *MockStoreMockRecorder) GetOrganizations(arg0 interface{}) *gomock.Call {
+// GetOrganizationProvisionerSettings indicates an expected call of GetOrganizationProvisionerSettings.
+func (mr *MockStoreMockRecorder) GetOrganizationProvisionerSettings(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizations", reflect.TypeOf((*MockStore)(nil).GetOrganizations), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationProvisionerSettings", reflect.TypeOf((*MockStore)(nil).GetOrganizationProvisionerSettings), arg0, arg1)
+}
+
+// GetOrganizationScheduleSettings mocks base method.
+func (m *MockStore) GetOrganizationScheduleSettings(arg0 context.Context, arg1 uuid.UUID) (database.OrganizationScheduleSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationScheduleSettings", arg0, arg1)
+	ret0, _ := ret[0].(database.OrganizationScheduleSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationScheduleSettings indicates an expected call of GetOrganizationScheduleSettings.
+func (mr *MockStoreMockRecorder) GetOrganizationScheduleSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationScheduleSettings", reflect.TypeOf((*MockStore)(nil).GetOrganizationScheduleSettings), arg0, arg1)
+}
+
+// GetOrganizationTemplateVariables mocks base method.
+func (m *MockStore) GetOrganizationTemplateVariables(arg0 context.Context, arg1 uuid.UUID) ([]database.OrganizationTemplateVariable, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationTemplateVariables", arg0, arg1)
+	ret0, _ := ret[0].([]database.OrganizationTemplateVariable)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationTemplateVariables indicates an expected call of GetOrganizationTemplateVariables.
+func (mr *MockStoreMockRecorder) GetOrganizationTemplateVariables(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationTemplateVariables", reflect.TypeOf((*MockStore)(nil).GetOrganizationTemplateVariables), arg0, arg1)
+}
+
+// GetOrganizations mocks base method.
+func (m *MockStore) GetOrganizations(arg0 context.Context) ([]database.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizations", arg0)
+	ret0, _ := ret[0].([]database.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizations indicates an expected call of GetOrganizations.
+func (mr *MockStoreMockRecorder) GetOrganizations(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizations", reflect.TypeOf((*MockStore)(nil).GetOrganizations), arg0)
 }
 
 // GetOrganizationsByUserID mocks base method.
@@ -986,6 +1481,21 @@ func (mr *MockStoreMockRecorder) GetParameterSchemasByJobID(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetParameterSchemasByJobID", reflect.TypeOf((*MockStore)(nil).GetParameterSchemasByJobID), arg0, arg1)
 }
 
+// GetPrebuiltWorkspaces mocks base method.
+func (m *MockStore) GetPrebuiltWorkspaces(arg0 context.Context, arg1 uuid.UUID) ([]database.GetPrebuiltWorkspacesRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrebuiltWorkspaces", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetPrebuiltWorkspacesRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrebuiltWorkspaces indicates an expected call of GetPrebuiltWorkspaces.
+func (mr *MockStoreMockRecorder) GetPrebuiltWorkspaces(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrebuiltWorkspaces", reflect.TypeOf((*MockStore)(nil).GetPrebuiltWorkspaces), arg0, arg1)
+}
+
 // GetPreviousTemplateVersion mocks base method.
 func (m *MockStore) GetPreviousTemplateVersion(arg0 context.Context, arg1 database.GetPreviousTemplateVersionParams) (database.TemplateVersion, error) {
 	m.ctrl.T.Helper()
@@ -1031,6 +1541,21 @@ func (mr *MockStoreMockRecorder) GetProvisionerJobByID(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvisionerJobByID", reflect.TypeOf((*MockStore)(nil).GetProvisionerJobByID), arg0, arg1)
 }
 
+// GetProvisionerJobConcurrency mocks base method.
+func (m *MockStore) GetProvisionerJobConcurrency(arg0 context.Context, arg1 uuid.UUID) (database.GetProvisionerJobConcurrencyRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProvisionerJobConcurrency", arg0, arg1)
+	ret0, _ := ret[0].(database.GetProvisionerJobConcurrencyRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProvisionerJobConcurrency indicates an expected call of GetProvisionerJobConcurrency.
+func (mr *MockStoreMockRecorder) GetProvisionerJobConcurrency(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvisionerJobConcurrency", reflect.TypeOf((*MockStore)(nil).GetProvisionerJobConcurrency), arg0, arg1)
+}
+
 // GetProvisionerJobsByIDs mocks base method.
 func (m *MockStore) GetProvisionerJobsByIDs(arg0 context.Context, arg1 []uuid.UUID) ([]database.ProvisionerJob, error) {
 	m.ctrl.T.Helper()
@@ -1076,6 +1601,51 @@ func (mr *MockStoreMockRecorder) GetProvisionerJobsCreatedAfter(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvisionerJobsCreatedAfter", reflect.TypeOf((*MockStore)(nil).GetProvisionerJobsCreatedAfter), arg0, arg1)
 }
 
+// GetProvisionerKeyByID mocks base method.
+func (m *MockStore) GetProvisionerKeyByID(arg0 context.Context, arg1 uuid.UUID) (database.ProvisionerKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProvisionerKeyByID", arg0, arg1)
+	ret0, _ := ret[0].(database.ProvisionerKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProvisionerKeyByID indicates an expected call of GetProvisionerKeyByID.
+func (mr *MockStoreMockRecorder) GetProvisionerKeyByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvisionerKeyByID", reflect.TypeOf((*MockStore)(nil).GetProvisionerKeyByID), arg0, arg1)
+}
+
+// GetProvisionerKeyByOrganizationIDAndName mocks base method.
+func (m *MockStore) GetProvisionerKeyByOrganizationIDAndName(arg0 context.Context, arg1 database.GetProvisionerKeyByOrganizationIDAndNameParams) (database.ProvisionerKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProvisionerKeyByOrganizationIDAndName", arg0, arg1)
+	ret0, _ := ret[0].(database.ProvisionerKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProvisionerKeyByOrganizationIDAndName indicates an expected call of GetProvisionerKeyByOrganizationIDAndName.
+func (mr *MockStoreMockRecorder) GetProvisionerKeyByOrganizationIDAndName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvisionerKeyByOrganizationIDAndName", reflect.TypeOf((*MockStore)(nil).GetProvisionerKeyByOrganizationIDAndName), arg0, arg1)
+}
+
+// GetProvisionerKeysByOrganizationID mocks base method.
+func (m *MockStore) GetProvisionerKeysByOrganizationID(arg0 context.Context, arg1 uuid.UUID) ([]database.ProvisionerKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProvisionerKeysByOrganizationID", arg0, arg1)
+	ret0, _ := ret[0].([]database.ProvisionerKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProvisionerKeysByOrganizationID indicates an expected call of GetProvisionerKeysByOrganizationID.
+func (mr *MockStoreMockRecorder) GetProvisionerKeysByOrganizationID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProvisionerKeysByOrganizationID", reflect.TypeOf((*MockStore)(nil).GetProvisionerKeysByOrganizationID), arg0, arg1)
+}
+
 // GetProvisionerLogsAfterID mocks base method.
 func (m *MockStore) GetProvisionerLogsAfterID(arg0 context.Context, arg1 database.GetProvisionerLogsAfterIDParams) ([]database.ProvisionerJobLog, error) {
 	m.ctrl.T.Helper()
@@ -1121,6 +1691,36 @@ func (mr *MockStoreMockRecorder) GetQuotaConsumedForUser(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuotaConsumedForUser", reflect.TypeOf((*MockStore)(nil).GetQuotaConsumedForUser), arg0, arg1)
 }
 
+// GetQuotaConsumedForUserByWorkspace mocks base method.
+func (m *MockStore) GetQuotaConsumedForUserByWorkspace(arg0 context.Context, arg1 uuid.UUID) ([]database.GetQuotaConsumedForUserByWorkspaceRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuotaConsumedForUserByWorkspace", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetQuotaConsumedForUserByWorkspaceRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuotaConsumedForUserByWorkspace indicates an expected call of GetQuotaConsumedForUserByWorkspace.
+func (mr *MockStoreMockRecorder) GetQuotaConsumedForUserByWorkspace(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuotaConsumedForUserByWorkspace", reflect.TypeOf((*MockStore)(nil).GetQuotaConsumedForUserByWorkspace), arg0, arg1)
+}
+
+// GetQuotaResourceMetadataForUser mocks base method.
+func (m *MockStore) GetQuotaResourceMetadataForUser(arg0 context.Context, arg1 database.GetQuotaResourceMetadataForUserParams) ([]database.WorkspaceResourceMetadatum, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuotaResourceMetadataForUser", arg0, arg1)
+	ret0, _ := ret[0].([]database.WorkspaceResourceMetadatum)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuotaResourceMetadataForUser indicates an expected call of GetQuotaResourceMetadataForUser.
+func (mr *MockStoreMockRecorder) GetQuotaResourceMetadataForUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuotaResourceMetadataForUser", reflect.TypeOf((*MockStore)(nil).GetQuotaResourceMetadataForUser), arg0, arg1)
+}
+
 // GetReplicaByID mocks base method.
 func (m *MockStore) GetReplicaByID(arg0 context.Context, arg1 uuid.UUID) (database.Replica, error) {
 	m.ctrl.T.Helper()
@@ -1151,6 +1751,81 @@ func (mr *MockStoreMockRecorder) GetReplicasUpdatedAfter(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReplicasUpdatedAfter", reflect.TypeOf((*MockStore)(nil).GetReplicasUpdatedAfter), arg0, arg1)
 }
 
+// GetSavedWorkspaceFilterByUserIDAndName mocks base method.
+func (m *MockStore) GetSavedWorkspaceFilterByUserIDAndName(arg0 context.Context, arg1 database.GetSavedWorkspaceFilterByUserIDAndNameParams) (database.SavedWorkspaceFilter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSavedWorkspaceFilterByUserIDAndName", arg0, arg1)
+	ret0, _ := ret[0].(database.SavedWorkspaceFilter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSavedWorkspaceFilterByUserIDAndName indicates an expected call of GetSavedWorkspaceFilterByUserIDAndName.
+func (mr *MockStoreMockRecorder) GetSavedWorkspaceFilterByUserIDAndName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSavedWorkspaceFilterByUserIDAndName", reflect.TypeOf((*MockStore)(nil).GetSavedWorkspaceFilterByUserIDAndName), arg0, arg1)
+}
+
+// GetSavedWorkspaceFiltersByUserID mocks base method.
+func (m *MockStore) GetSavedWorkspaceFiltersByUserID(arg0 context.Context, arg1 uuid.UUID) ([]database.SavedWorkspaceFilter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSavedWorkspaceFiltersByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]database.SavedWorkspaceFilter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSavedWorkspaceFiltersByUserID indicates an expected call of GetSavedWorkspaceFiltersByUserID.
+func (mr *MockStoreMockRecorder) GetSavedWorkspaceFiltersByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSavedWorkspaceFiltersByUserID", reflect.TypeOf((*MockStore)(nil).GetSavedWorkspaceFiltersByUserID), arg0, arg1)
+}
+
+// GetScheduleHolidayByID mocks base method.
+func (m *MockStore) GetScheduleHolidayByID(arg0 context.Context, arg1 uuid.UUID) (database.ScheduleHoliday, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduleHolidayByID", arg0, arg1)
+	ret0, _ := ret[0].(database.ScheduleHoliday)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduleHolidayByID indicates an expected call of GetScheduleHolidayByID.
+func (mr *MockStoreMockRecorder) GetScheduleHolidayByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduleHolidayByID", reflect.TypeOf((*MockStore)(nil).GetScheduleHolidayByID), arg0, arg1)
+}
+
+// GetScheduleHolidays mocks base method.
+func (m *MockStore) GetScheduleHolidays(arg0 context.Context) ([]database.ScheduleHoliday, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduleHolidays", arg0)
+	ret0, _ := ret[0].([]database.ScheduleHoliday)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduleHolidays indicates an expected call of GetScheduleHolidays.
+func (mr *MockStoreMockRecorder) GetScheduleHolidays(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduleHolidays", reflect.TypeOf((*MockStore)(nil).GetScheduleHolidays), arg0)
+}
+
+// GetScheduleInsights mocks base method.
+func (m *MockStore) GetScheduleInsights(arg0 context.Context, arg1 database.GetScheduleInsightsParams) ([]database.GetScheduleInsightsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduleInsights", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetScheduleInsightsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduleInsights indicates an expected call of GetScheduleInsights.
+func (mr *MockStoreMockRecorder) GetScheduleInsights(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduleInsights", reflect.TypeOf((*MockStore)(nil).GetScheduleInsights), arg0, arg1)
+}
+
 // GetServiceBanner mocks base method.
 func (m *MockStore) GetServiceBanner(arg0 context.Context) (string, error) {
 	m.ctrl.T.Helper()
@@ -1271,6 +1946,36 @@ func (mr *MockStoreMockRecorder) GetTemplateDailyInsights(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateDailyInsights", reflect.TypeOf((*MockStore)(nil).GetTemplateDailyInsights), arg0, arg1)
 }
 
+// GetTemplateDeadlineRecalculationByID mocks base method.
+func (m *MockStore) GetTemplateDeadlineRecalculationByID(arg0 context.Context, arg1 uuid.UUID) (database.TemplateDeadlineRecalculation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateDeadlineRecalculationByID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateDeadlineRecalculation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateDeadlineRecalculationByID indicates an expected call of GetTemplateDeadlineRecalculationByID.
+func (mr *MockStoreMockRecorder) GetTemplateDeadlineRecalculationByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateDeadlineRecalculationByID", reflect.TypeOf((*MockStore)(nil).GetTemplateDeadlineRecalculationByID), arg0, arg1)
+}
+
+// GetTemplateDeadlineRecalculationsByTemplateID mocks base method.
+func (m *MockStore) GetTemplateDeadlineRecalculationsByTemplateID(arg0 context.Context, arg1 uuid.UUID) ([]database.TemplateDeadlineRecalculation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateDeadlineRecalculationsByTemplateID", arg0, arg1)
+	ret0, _ := ret[0].([]database.TemplateDeadlineRecalculation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateDeadlineRecalculationsByTemplateID indicates an expected call of GetTemplateDeadlineRecalculationsByTemplateID.
+func (mr *MockStoreMockRecorder) GetTemplateDeadlineRecalculationsByTemplateID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateDeadlineRecalculationsByTemplateID", reflect.TypeOf((*MockStore)(nil).GetTemplateDeadlineRecalculationsByTemplateID), arg0, arg1)
+}
+
 // GetTemplateGroupRoles mocks base method.
 func (m *MockStore) GetTemplateGroupRoles(arg0 context.Context, arg1 uuid.UUID) ([]database.TemplateGroup, error) {
 	m.ctrl.T.Helper()
@@ -1316,6 +2021,51 @@ func (mr *MockStoreMockRecorder) GetTemplateParameterInsights(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateParameterInsights", reflect.TypeOf((*MockStore)(nil).GetTemplateParameterInsights), arg0, arg1)
 }
 
+// GetTemplateParameterValidation mocks base method.
+func (m *MockStore) GetTemplateParameterValidation(arg0 context.Context, arg1 uuid.UUID) (database.TemplateParameterValidation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateParameterValidation", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateParameterValidation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateParameterValidation indicates an expected call of GetTemplateParameterValidation.
+func (mr *MockStoreMockRecorder) GetTemplateParameterValidation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateParameterValidation", reflect.TypeOf((*MockStore)(nil).GetTemplateParameterValidation), arg0, arg1)
+}
+
+// GetTemplatePrebuildPool mocks base method.
+func (m *MockStore) GetTemplatePrebuildPool(arg0 context.Context, arg1 uuid.UUID) (database.TemplatePrebuildPool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplatePrebuildPool", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplatePrebuildPool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplatePrebuildPool indicates an expected call of GetTemplatePrebuildPool.
+func (mr *MockStoreMockRecorder) GetTemplatePrebuildPool(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplatePrebuildPool", reflect.TypeOf((*MockStore)(nil).GetTemplatePrebuildPool), arg0, arg1)
+}
+
+// GetTemplatePrebuildPools mocks base method.
+func (m *MockStore) GetTemplatePrebuildPools(arg0 context.Context) ([]database.TemplatePrebuildPool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplatePrebuildPools", arg0)
+	ret0, _ := ret[0].([]database.TemplatePrebuildPool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplatePrebuildPools indicates an expected call of GetTemplatePrebuildPools.
+func (mr *MockStoreMockRecorder) GetTemplatePrebuildPools(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplatePrebuildPools", reflect.TypeOf((*MockStore)(nil).GetTemplatePrebuildPools), arg0)
+}
+
 // GetTemplateUserRoles mocks base method.
 func (m *MockStore) GetTemplateUserRoles(arg0 context.Context, arg1 uuid.UUID) ([]database.TemplateUser, error) {
 	m.ctrl.T.Helper()
@@ -1331,6 +2081,21 @@ func (mr *MockStoreMockRecorder) GetTemplateUserRoles(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateUserRoles", reflect.TypeOf((*MockStore)(nil).GetTemplateUserRoles), arg0, arg1)
 }
 
+// GetTemplateVersionBuildInsights mocks base method.
+func (m *MockStore) GetTemplateVersionBuildInsights(arg0 context.Context, arg1 database.GetTemplateVersionBuildInsightsParams) ([]database.GetTemplateVersionBuildInsightsRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionBuildInsights", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetTemplateVersionBuildInsightsRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionBuildInsights indicates an expected call of GetTemplateVersionBuildInsights.
+func (mr *MockStoreMockRecorder) GetTemplateVersionBuildInsights(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionBuildInsights", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionBuildInsights), arg0, arg1)
+}
+
 // GetTemplateVersionByID mocks base method.
 func (m *MockStore) GetTemplateVersionByID(arg0 context.Context, arg1 uuid.UUID) (database.TemplateVersion, error) {
 	m.ctrl.T.Helper()
@@ -1391,6 +2156,51 @@ func (mr *MockStoreMockRecorder) GetTemplateVersionParameters(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionParameters", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionParameters), arg0, arg1)
 }
 
+// GetTemplateVersionPresets mocks base method.
+func (m *MockStore) GetTemplateVersionPresets(arg0 context.Context, arg1 uuid.UUID) ([]database.TemplateVersionPreset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionPresets", arg0, arg1)
+	ret0, _ := ret[0].([]database.TemplateVersionPreset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionPresets indicates an expected call of GetTemplateVersionPresets.
+func (mr *MockStoreMockRecorder) GetTemplateVersionPresets(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionPresets", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionPresets), arg0, arg1)
+}
+
+// GetTemplateVersionPromotionByID mocks base method.
+func (m *MockStore) GetTemplateVersionPromotionByID(arg0 context.Context, arg1 uuid.UUID) (database.TemplateVersionPromotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionPromotionByID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionPromotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionPromotionByID indicates an expected call of GetTemplateVersionPromotionByID.
+func (mr *MockStoreMockRecorder) GetTemplateVersionPromotionByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionPromotionByID", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionPromotionByID), arg0, arg1)
+}
+
+// GetTemplateVersionPromotionsByTemplateID mocks base method.
+func (m *MockStore) GetTemplateVersionPromotionsByTemplateID(arg0 context.Context, arg1 database.GetTemplateVersionPromotionsByTemplateIDParams) ([]database.TemplateVersionPromotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplateVersionPromotionsByTemplateID", arg0, arg1)
+	ret0, _ := ret[0].([]database.TemplateVersionPromotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplateVersionPromotionsByTemplateID indicates an expected call of GetTemplateVersionPromotionsByTemplateID.
+func (mr *MockStoreMockRecorder) GetTemplateVersionPromotionsByTemplateID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplateVersionPromotionsByTemplateID", reflect.TypeOf((*MockStore)(nil).GetTemplateVersionPromotionsByTemplateID), arg0, arg1)
+}
+
 // GetTemplateVersionVariables mocks base method.
 func (m *MockStore) GetTemplateVersionVariables(arg0 context.Context, arg1 uuid.UUID) ([]database.TemplateVersionVariable, error) {
 	m.ctrl.T.Helper()
@@ -1466,6 +2276,21 @@ func (mr *MockStoreMockRecorder) GetTemplates(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplates", reflect.TypeOf((*MockStore)(nil).GetTemplates), arg0)
 }
 
+// GetTemplatesBySchedulePolicyTemplateID mocks base method.
+func (m *MockStore) GetTemplatesBySchedulePolicyTemplateID(arg0 context.Context, arg1 uuid.NullUUID) ([]database.Template, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTemplatesBySchedulePolicyTemplateID", arg0, arg1)
+	ret0, _ := ret[0].([]database.Template)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTemplatesBySchedulePolicyTemplateID indicates an expected call of GetTemplatesBySchedulePolicyTemplateID.
+func (mr *MockStoreMockRecorder) GetTemplatesBySchedulePolicyTemplateID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTemplatesBySchedulePolicyTemplateID", reflect.TypeOf((*MockStore)(nil).GetTemplatesBySchedulePolicyTemplateID), arg0, arg1)
+}
+
 // GetTemplatesWithFilter mocks base method.
 func (m *MockStore) GetTemplatesWithFilter(arg0 context.Context, arg1 database.GetTemplatesWithFilterParams) ([]database.Template, error) {
 	m.ctrl.T.Helper()
@@ -1541,6 +2366,21 @@ func (mr *MockStoreMockRecorder) GetUserCount(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserCount", reflect.TypeOf((*MockStore)(nil).GetUserCount), arg0)
 }
 
+// GetUserDotfiles mocks base method.
+func (m *MockStore) GetUserDotfiles(arg0 context.Context, arg1 uuid.UUID) (database.UserDotfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserDotfiles", arg0, arg1)
+	ret0, _ := ret[0].(database.UserDotfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserDotfiles indicates an expected call of GetUserDotfiles.
+func (mr *MockStoreMockRecorder) GetUserDotfiles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserDotfiles", reflect.TypeOf((*MockStore)(nil).GetUserDotfiles), arg0, arg1)
+}
+
 // GetUserLatencyInsights mocks base method.
 func (m *MockStore) GetUserLatencyInsights(arg0 context.Context, arg1 database.GetUserLatencyInsightsParams) ([]database.GetUserLatencyInsightsRow, error) {
 	m.ctrl.T.Helper()
@@ -1586,6 +2426,51 @@ func (mr *MockStoreMockRecorder) GetUserLinkByUserIDLoginType(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserLinkByUserIDLoginType", reflect.TypeOf((*MockStore)(nil).GetUserLinkByUserIDLoginType), arg0, arg1)
 }
 
+// GetUserQuietHoursExceptions mocks base method.
+func (m *MockStore) GetUserQuietHoursExceptions(arg0 context.Context, arg1 uuid.UUID) ([]database.UserQuietHoursException, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserQuietHoursExceptions", arg0, arg1)
+	ret0, _ := ret[0].([]database.UserQuietHoursException)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserQuietHoursExceptions indicates an expected call of GetUserQuietHoursExceptions.
+func (mr *MockStoreMockRecorder) GetUserQuietHoursExceptions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserQuietHoursExceptions", reflect.TypeOf((*MockStore)(nil).GetUserQuietHoursExceptions), arg0, arg1)
+}
+
+// GetUserSecretByUserIDAndName mocks base method.
+func (m *MockStore) GetUserSecretByUserIDAndName(arg0 context.Context, arg1 database.GetUserSecretByUserIDAndNameParams) (database.UserSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSecretByUserIDAndName", arg0, arg1)
+	ret0, _ := ret[0].(database.UserSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSecretByUserIDAndName indicates an expected call of GetUserSecretByUserIDAndName.
+func (mr *MockStoreMockRecorder) GetUserSecretByUserIDAndName(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSecretByUserIDAndName", reflect.TypeOf((*MockStore)(nil).GetUserSecretByUserIDAndName), arg0, arg1)
+}
+
+// GetUserSecretsByUserID mocks base method.
+func (m *MockStore) GetUserSecretsByUserID(arg0 context.Context, arg1 uuid.UUID) ([]database.UserSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserSecretsByUserID", arg0, arg1)
+	ret0, _ := ret[0].([]database.UserSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserSecretsByUserID indicates an expected call of GetUserSecretsByUserID.
+func (mr *MockStoreMockRecorder) GetUserSecretsByUserID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSecretsByUserID", reflect.TypeOf((*MockStore)(nil).GetUserSecretsByUserID), arg0, arg1)
+}
+
 // GetUsers mocks base method.
 func (m *MockStore) GetUsers(arg0 context.Context, arg1 database.GetUsersParams) ([]database.GetUsersRow, error) {
 	m.ctrl.T.Helper()
@@ -1661,6 +2546,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaceAgentByInstanceID(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentByInstanceID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentByInstanceID), arg0, arg1)
 }
 
+// GetWorkspaceAgentDevcontainersByAgentID mocks base method.
+func (m *MockStore) GetWorkspaceAgentDevcontainersByAgentID(arg0 context.Context, arg1 uuid.UUID) ([]database.WorkspaceAgentDevcontainer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAgentDevcontainersByAgentID", arg0, arg1)
+	ret0, _ := ret[0].([]database.WorkspaceAgentDevcontainer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAgentDevcontainersByAgentID indicates an expected call of GetWorkspaceAgentDevcontainersByAgentID.
+func (mr *MockStoreMockRecorder) GetWorkspaceAgentDevcontainersByAgentID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentDevcontainersByAgentID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentDevcontainersByAgentID), arg0, arg1)
+}
+
 // GetWorkspaceAgentLifecycleStateByID mocks base method.
 func (m *MockStore) GetWorkspaceAgentLifecycleStateByID(arg0 context.Context, arg1 uuid.UUID) (database.GetWorkspaceAgentLifecycleStateByIDRow, error) {
 	m.ctrl.T.Helper()
@@ -1706,6 +2606,36 @@ func (mr *MockStoreMockRecorder) GetWorkspaceAgentMetadata(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentMetadata", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentMetadata), arg0, arg1)
 }
 
+// GetWorkspaceAgentScriptsByAgentID mocks base method.
+func (m *MockStore) GetWorkspaceAgentScriptsByAgentID(arg0 context.Context, arg1 uuid.UUID) ([]database.WorkspaceAgentScript, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAgentScriptsByAgentID", arg0, arg1)
+	ret0, _ := ret[0].([]database.WorkspaceAgentScript)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAgentScriptsByAgentID indicates an expected call of GetWorkspaceAgentScriptsByAgentID.
+func (mr *MockStoreMockRecorder) GetWorkspaceAgentScriptsByAgentID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentScriptsByAgentID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentScriptsByAgentID), arg0, arg1)
+}
+
+// GetWorkspaceAgentSecretsByAgentID mocks base method.
+func (m *MockStore) GetWorkspaceAgentSecretsByAgentID(arg0 context.Context, arg1 uuid.UUID) ([]database.WorkspaceAgentSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceAgentSecretsByAgentID", arg0, arg1)
+	ret0, _ := ret[0].([]database.WorkspaceAgentSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceAgentSecretsByAgentID indicates an expected call of GetWorkspaceAgentSecretsByAgentID.
+func (mr *MockStoreMockRecorder) GetWorkspaceAgentSecretsByAgentID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAgentSecretsByAgentID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAgentSecretsByAgentID), arg0, arg1)
+}
+
 // GetWorkspaceAgentStats mocks base method.
 func (m *MockStore) GetWorkspaceAgentStats(arg0 context.Context, arg1 time.Time) ([]database.GetWorkspaceAgentStatsRow, error) {
 	m.ctrl.T.Helper()
@@ -1841,6 +2771,36 @@ func (mr *MockStoreMockRecorder) GetWorkspaceAppsCreatedAfter(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceAppsCreatedAfter", reflect.TypeOf((*MockStore)(nil).GetWorkspaceAppsCreatedAfter), arg0, arg1)
 }
 
+// GetWorkspaceBatchByID mocks base method.
+func (m *MockStore) GetWorkspaceBatchByID(arg0 context.Context, arg1 uuid.UUID) (database.WorkspaceBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceBatchByID", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceBatchByID indicates an expected call of GetWorkspaceBatchByID.
+func (mr *MockStoreMockRecorder) GetWorkspaceBatchByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceBatchByID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceBatchByID), arg0, arg1)
+}
+
+// GetWorkspaceBatchWorkspacesByBatchID mocks base method.
+func (m *MockStore) GetWorkspaceBatchWorkspacesByBatchID(arg0 context.Context, arg1 uuid.UUID) ([]database.GetWorkspaceBatchWorkspacesByBatchIDRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceBatchWorkspacesByBatchID", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetWorkspaceBatchWorkspacesByBatchIDRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceBatchWorkspacesByBatchID indicates an expected call of GetWorkspaceBatchWorkspacesByBatchID.
+func (mr *MockStoreMockRecorder) GetWorkspaceBatchWorkspacesByBatchID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceBatchWorkspacesByBatchID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceBatchWorkspacesByBatchID), arg0, arg1)
+}
+
 // GetWorkspaceBuildByID mocks base method.
 func (m *MockStore) GetWorkspaceBuildByID(arg0 context.Context, arg1 uuid.UUID) (database.WorkspaceBuild, error) {
 	m.ctrl.T.Helper()
@@ -1991,6 +2951,36 @@ func (mr *MockStoreMockRecorder) GetWorkspaceByWorkspaceAppID(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceByWorkspaceAppID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceByWorkspaceAppID), arg0, arg1)
 }
 
+// GetWorkspaceDeadlineExtensionCount mocks base method.
+func (m *MockStore) GetWorkspaceDeadlineExtensionCount(arg0 context.Context, arg1 database.GetWorkspaceDeadlineExtensionCountParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceDeadlineExtensionCount", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceDeadlineExtensionCount indicates an expected call of GetWorkspaceDeadlineExtensionCount.
+func (mr *MockStoreMockRecorder) GetWorkspaceDeadlineExtensionCount(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceDeadlineExtensionCount", reflect.TypeOf((*MockStore)(nil).GetWorkspaceDeadlineExtensionCount), arg0, arg1)
+}
+
+// GetWorkspaceNameRedirect mocks base method.
+func (m *MockStore) GetWorkspaceNameRedirect(arg0 context.Context, arg1 database.GetWorkspaceNameRedirectParams) (database.WorkspaceNameRedirect, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceNameRedirect", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceNameRedirect)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceNameRedirect indicates an expected call of GetWorkspaceNameRedirect.
+func (mr *MockStoreMockRecorder) GetWorkspaceNameRedirect(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceNameRedirect", reflect.TypeOf((*MockStore)(nil).GetWorkspaceNameRedirect), arg0, arg1)
+}
+
 // GetWorkspaceProxies mocks base method.
 func (m *MockStore) GetWorkspaceProxies(arg0 context.Context) ([]database.WorkspaceProxy, error) {
 	m.ctrl.T.Helper()
@@ -2141,6 +3131,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaceResourcesCreatedAfter(arg0, arg1 in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceResourcesCreatedAfter", reflect.TypeOf((*MockStore)(nil).GetWorkspaceResourcesCreatedAfter), arg0, arg1)
 }
 
+// GetWorkspaceScheduleOverrideByWorkspaceID mocks base method.
+func (m *MockStore) GetWorkspaceScheduleOverrideByWorkspaceID(arg0 context.Context, arg1 uuid.UUID) (database.WorkspaceScheduleOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspaceScheduleOverrideByWorkspaceID", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceScheduleOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspaceScheduleOverrideByWorkspaceID indicates an expected call of GetWorkspaceScheduleOverrideByWorkspaceID.
+func (mr *MockStoreMockRecorder) GetWorkspaceScheduleOverrideByWorkspaceID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaceScheduleOverrideByWorkspaceID", reflect.TypeOf((*MockStore)(nil).GetWorkspaceScheduleOverrideByWorkspaceID), arg0, arg1)
+}
+
 // GetWorkspaces mocks base method.
 func (m *MockStore) GetWorkspaces(arg0 context.Context, arg1 database.GetWorkspacesParams) ([]database.GetWorkspacesRow, error) {
 	m.ctrl.T.Helper()
@@ -2156,6 +3161,21 @@ func (mr *MockStoreMockRecorder) GetWorkspaces(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspaces", reflect.TypeOf((*MockStore)(nil).GetWorkspaces), arg0, arg1)
 }
 
+// GetWorkspacesByIDs mocks base method.
+func (m *MockStore) GetWorkspacesByIDs(arg0 context.Context, arg1 []uuid.UUID) ([]database.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspacesByIDs", arg0, arg1)
+	ret0, _ := ret[0].([]database.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspacesByIDs indicates an expected call of GetWorkspacesByIDs.
+func (mr *MockStoreMockRecorder) GetWorkspacesByIDs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspacesByIDs", reflect.TypeOf((*MockStore)(nil).GetWorkspacesByIDs), arg0, arg1)
+}
+
 // GetWorkspacesEligibleForTransition mocks base method.
 func (m *MockStore) GetWorkspacesEligibleForTransition(arg0 context.Context, arg1 time.Time) ([]database.Workspace, error) {
 	m.ctrl.T.Helper()
@@ -2171,6 +3191,21 @@ func (mr *MockStoreMockRecorder) GetWorkspacesEligibleForTransition(arg0, arg1 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspacesEligibleForTransition", reflect.TypeOf((*MockStore)(nil).GetWorkspacesEligibleForTransition), arg0, arg1)
 }
 
+// GetWorkspacesWithImminentAutostop mocks base method.
+func (m *MockStore) GetWorkspacesWithImminentAutostop(arg0 context.Context, arg1 database.GetWorkspacesWithImminentAutostopParams) ([]database.GetWorkspacesWithImminentAutostopRow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWorkspacesWithImminentAutostop", arg0, arg1)
+	ret0, _ := ret[0].([]database.GetWorkspacesWithImminentAutostopRow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWorkspacesWithImminentAutostop indicates an expected call of GetWorkspacesWithImminentAutostop.
+func (mr *MockStoreMockRecorder) GetWorkspacesWithImminentAutostop(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWorkspacesWithImminentAutostop", reflect.TypeOf((*MockStore)(nil).GetWorkspacesWithImminentAutostop), arg0, arg1)
+}
+
 // InTx mocks base method.
 func (m *MockStore) InTx(arg0 func(database.Store) error, arg1 *sql.TxOptions) error {
 	m.ctrl.T.Helper()
@@ -2230,6 +3265,21 @@ func (mr *MockStoreMockRecorder) InsertAuditLog(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAuditLog", reflect.TypeOf((*MockStore)(nil).InsertAuditLog), arg0, arg1)
 }
 
+// InsertCustomRole mocks base method.
+func (m *MockStore) InsertCustomRole(arg0 context.Context, arg1 database.InsertCustomRoleParams) (database.CustomRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertCustomRole", arg0, arg1)
+	ret0, _ := ret[0].(database.CustomRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertCustomRole indicates an expected call of InsertCustomRole.
+func (mr *MockStoreMockRecorder) InsertCustomRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCustomRole", reflect.TypeOf((*MockStore)(nil).InsertCustomRole), arg0, arg1)
+}
+
 // InsertDERPMeshKey mocks base method.
 func (m *MockStore) InsertDERPMeshKey(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -2437,6 +3487,21 @@ func (mr *MockStoreMockRecorder) InsertProvisionerJobLogs(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertProvisionerJobLogs", reflect.TypeOf((*MockStore)(nil).InsertProvisionerJobLogs), arg0, arg1)
 }
 
+// InsertProvisionerKey mocks base method.
+func (m *MockStore) InsertProvisionerKey(arg0 context.Context, arg1 database.InsertProvisionerKeyParams) (database.ProvisionerKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertProvisionerKey", arg0, arg1)
+	ret0, _ := ret[0].(database.ProvisionerKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertProvisionerKey indicates an expected call of InsertProvisionerKey.
+func (mr *MockStoreMockRecorder) InsertProvisionerKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertProvisionerKey", reflect.TypeOf((*MockStore)(nil).InsertProvisionerKey), arg0, arg1)
+}
+
 // InsertReplica mocks base method.
 func (m *MockStore) InsertReplica(arg0 context.Context, arg1 database.InsertReplicaParams) (database.Replica, error) {
 	m.ctrl.T.Helper()
@@ -2452,6 +3517,36 @@ func (mr *MockStoreMockRecorder) InsertReplica(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReplica", reflect.TypeOf((*MockStore)(nil).InsertReplica), arg0, arg1)
 }
 
+// InsertSavedWorkspaceFilter mocks base method.
+func (m *MockStore) InsertSavedWorkspaceFilter(arg0 context.Context, arg1 database.InsertSavedWorkspaceFilterParams) (database.SavedWorkspaceFilter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertSavedWorkspaceFilter", arg0, arg1)
+	ret0, _ := ret[0].(database.SavedWorkspaceFilter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertSavedWorkspaceFilter indicates an expected call of InsertSavedWorkspaceFilter.
+func (mr *MockStoreMockRecorder) InsertSavedWorkspaceFilter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertSavedWorkspaceFilter", reflect.TypeOf((*MockStore)(nil).InsertSavedWorkspaceFilter), arg0, arg1)
+}
+
+// InsertScheduleHoliday mocks base method.
+func (m *MockStore) InsertScheduleHoliday(arg0 context.Context, arg1 database.InsertScheduleHolidayParams) (database.ScheduleHoliday, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertScheduleHoliday", arg0, arg1)
+	ret0, _ := ret[0].(database.ScheduleHoliday)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertScheduleHoliday indicates an expected call of InsertScheduleHoliday.
+func (mr *MockStoreMockRecorder) InsertScheduleHoliday(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertScheduleHoliday", reflect.TypeOf((*MockStore)(nil).InsertScheduleHoliday), arg0, arg1)
+}
+
 // InsertTemplate mocks base method.
 func (m *MockStore) InsertTemplate(arg0 context.Context, arg1 database.InsertTemplateParams) error {
 	m.ctrl.T.Helper()
@@ -2466,6 +3561,21 @@ func (mr *MockStoreMockRecorder) InsertTemplate(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplate", reflect.TypeOf((*MockStore)(nil).InsertTemplate), arg0, arg1)
 }
 
+// InsertTemplateDeadlineRecalculation mocks base method.
+func (m *MockStore) InsertTemplateDeadlineRecalculation(arg0 context.Context, arg1 database.InsertTemplateDeadlineRecalculationParams) (database.TemplateDeadlineRecalculation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTemplateDeadlineRecalculation", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateDeadlineRecalculation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertTemplateDeadlineRecalculation indicates an expected call of InsertTemplateDeadlineRecalculation.
+func (mr *MockStoreMockRecorder) InsertTemplateDeadlineRecalculation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplateDeadlineRecalculation", reflect.TypeOf((*MockStore)(nil).InsertTemplateDeadlineRecalculation), arg0, arg1)
+}
+
 // InsertTemplateVersion mocks base method.
 func (m *MockStore) InsertTemplateVersion(arg0 context.Context, arg1 database.InsertTemplateVersionParams) error {
 	m.ctrl.T.Helper()
@@ -2495,6 +3605,36 @@ func (mr *MockStoreMockRecorder) InsertTemplateVersionParameter(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplateVersionParameter", reflect.TypeOf((*MockStore)(nil).InsertTemplateVersionParameter), arg0, arg1)
 }
 
+// InsertTemplateVersionPreset mocks base method.
+func (m *MockStore) InsertTemplateVersionPreset(arg0 context.Context, arg1 database.InsertTemplateVersionPresetParams) (database.TemplateVersionPreset, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTemplateVersionPreset", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionPreset)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertTemplateVersionPreset indicates an expected call of InsertTemplateVersionPreset.
+func (mr *MockStoreMockRecorder) InsertTemplateVersionPreset(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplateVersionPreset", reflect.TypeOf((*MockStore)(nil).InsertTemplateVersionPreset), arg0, arg1)
+}
+
+// InsertTemplateVersionPromotion mocks base method.
+func (m *MockStore) InsertTemplateVersionPromotion(arg0 context.Context, arg1 database.InsertTemplateVersionPromotionParams) (database.TemplateVersionPromotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTemplateVersionPromotion", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionPromotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertTemplateVersionPromotion indicates an expected call of InsertTemplateVersionPromotion.
+func (mr *MockStoreMockRecorder) InsertTemplateVersionPromotion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTemplateVersionPromotion", reflect.TypeOf((*MockStore)(nil).InsertTemplateVersionPromotion), arg0, arg1)
+}
+
 // InsertTemplateVersionVariable mocks base method.
 func (m *MockStore) InsertTemplateVersionVariable(arg0 context.Context, arg1 database.InsertTemplateVersionVariableParams) (database.TemplateVersionVariable, error) {
 	m.ctrl.T.Helper()
@@ -2554,6 +3694,36 @@ func (mr *MockStoreMockRecorder) InsertUserLink(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUserLink", reflect.TypeOf((*MockStore)(nil).InsertUserLink), arg0, arg1)
 }
 
+// InsertUserQuietHoursException mocks base method.
+func (m *MockStore) InsertUserQuietHoursException(arg0 context.Context, arg1 database.InsertUserQuietHoursExceptionParams) (database.UserQuietHoursException, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertUserQuietHoursException", arg0, arg1)
+	ret0, _ := ret[0].(database.UserQuietHoursException)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertUserQuietHoursException indicates an expected call of InsertUserQuietHoursException.
+func (mr *MockStoreMockRecorder) InsertUserQuietHoursException(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUserQuietHoursException", reflect.TypeOf((*MockStore)(nil).InsertUserQuietHoursException), arg0, arg1)
+}
+
+// InsertUserSecret mocks base method.
+func (m *MockStore) InsertUserSecret(arg0 context.Context, arg1 database.InsertUserSecretParams) (database.UserSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertUserSecret", arg0, arg1)
+	ret0, _ := ret[0].(database.UserSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertUserSecret indicates an expected call of InsertUserSecret.
+func (mr *MockStoreMockRecorder) InsertUserSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUserSecret", reflect.TypeOf((*MockStore)(nil).InsertUserSecret), arg0, arg1)
+}
+
 // InsertWorkspace mocks base method.
 func (m *MockStore) InsertWorkspace(arg0 context.Context, arg1 database.InsertWorkspaceParams) (database.Workspace, error) {
 	m.ctrl.T.Helper()
@@ -2584,6 +3754,20 @@ func (mr *MockStoreMockRecorder) InsertWorkspaceAgent(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAgent", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAgent), arg0, arg1)
 }
 
+// InsertWorkspaceAgentDevcontainer mocks base method.
+func (m *MockStore) InsertWorkspaceAgentDevcontainer(arg0 context.Context, arg1 database.InsertWorkspaceAgentDevcontainerParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWorkspaceAgentDevcontainer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertWorkspaceAgentDevcontainer indicates an expected call of InsertWorkspaceAgentDevcontainer.
+func (mr *MockStoreMockRecorder) InsertWorkspaceAgentDevcontainer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAgentDevcontainer", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAgentDevcontainer), arg0, arg1)
+}
+
 // InsertWorkspaceAgentLogs mocks base method.
 func (m *MockStore) InsertWorkspaceAgentLogs(arg0 context.Context, arg1 database.InsertWorkspaceAgentLogsParams) ([]database.WorkspaceAgentLog, error) {
 	m.ctrl.T.Helper()
@@ -2613,6 +3797,34 @@ func (mr *MockStoreMockRecorder) InsertWorkspaceAgentMetadata(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAgentMetadata", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAgentMetadata), arg0, arg1)
 }
 
+// InsertWorkspaceAgentScript mocks base method.
+func (m *MockStore) InsertWorkspaceAgentScript(arg0 context.Context, arg1 database.InsertWorkspaceAgentScriptParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWorkspaceAgentScript", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertWorkspaceAgentScript indicates an expected call of InsertWorkspaceAgentScript.
+func (mr *MockStoreMockRecorder) InsertWorkspaceAgentScript(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAgentScript", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAgentScript), arg0, arg1)
+}
+
+// InsertWorkspaceAgentSecret mocks base method.
+func (m *MockStore) InsertWorkspaceAgentSecret(arg0 context.Context, arg1 database.InsertWorkspaceAgentSecretParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWorkspaceAgentSecret", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertWorkspaceAgentSecret indicates an expected call of InsertWorkspaceAgentSecret.
+func (mr *MockStoreMockRecorder) InsertWorkspaceAgentSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAgentSecret", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAgentSecret), arg0, arg1)
+}
+
 // InsertWorkspaceAgentStat mocks base method.
 func (m *MockStore) InsertWorkspaceAgentStat(arg0 context.Context, arg1 database.InsertWorkspaceAgentStatParams) (database.WorkspaceAgentStat, error) {
 	m.ctrl.T.Helper()
@@ -2657,6 +3869,20 @@ func (mr *MockStoreMockRecorder) InsertWorkspaceApp(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceApp", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceApp), arg0, arg1)
 }
 
+// InsertWorkspaceAppAccessLogs mocks base method.
+func (m *MockStore) InsertWorkspaceAppAccessLogs(arg0 context.Context, arg1 database.InsertWorkspaceAppAccessLogsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWorkspaceAppAccessLogs", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertWorkspaceAppAccessLogs indicates an expected call of InsertWorkspaceAppAccessLogs.
+func (mr *MockStoreMockRecorder) InsertWorkspaceAppAccessLogs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAppAccessLogs", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAppAccessLogs), arg0, arg1)
+}
+
 // InsertWorkspaceAppStats mocks base method.
 func (m *MockStore) InsertWorkspaceAppStats(arg0 context.Context, arg1 database.InsertWorkspaceAppStatsParams) error {
 	m.ctrl.T.Helper()
@@ -2671,6 +3897,35 @@ func (mr *MockStoreMockRecorder) InsertWorkspaceAppStats(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceAppStats", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceAppStats), arg0, arg1)
 }
 
+// InsertWorkspaceBatch mocks base method.
+func (m *MockStore) InsertWorkspaceBatch(arg0 context.Context, arg1 database.InsertWorkspaceBatchParams) (database.WorkspaceBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWorkspaceBatch", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertWorkspaceBatch indicates an expected call of InsertWorkspaceBatch.
+func (mr *MockStoreMockRecorder) InsertWorkspaceBatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceBatch", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceBatch), arg0, arg1)
+}
+
+// InsertWorkspaceBatchWorkspaces mocks base method.
+func (m *MockStore) InsertWorkspaceBatchWorkspaces(arg0 context.Context, arg1 database.InsertWorkspaceBatchWorkspacesParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWorkspaceBatchWorkspaces", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertWorkspaceBatchWorkspaces indicates an expected call of InsertWorkspaceBatchWorkspaces.
+func (mr *MockStoreMockRecorder) InsertWorkspaceBatchWorkspaces(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceBatchWorkspaces", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceBatchWorkspaces), arg0, arg1)
+}
+
 // InsertWorkspaceBuild mocks base method.
 func (m *MockStore) InsertWorkspaceBuild(arg0 context.Context, arg1 database.InsertWorkspaceBuildParams) error {
 	m.ctrl.T.Helper()
@@ -2693,10 +3948,25 @@ func (m *MockStore) InsertWorkspaceBuildParameters(arg0 context.Context, arg1 da
 	return ret0
 }
 
-// InsertWorkspaceBuildParameters indicates an expected call of InsertWorkspaceBuildParameters.
-func (mr *MockStoreMockRecorder) InsertWorkspaceBuildParameters(arg0, arg1 interface{}) *gomock.Call {
+// InsertWorkspaceBuildParameters indicates an expected call of InsertWorkspaceBuildParameters.
+func (mr *MockStoreMockRecorder) InsertWorkspaceBuildParameters(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceBuildParameters", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceBuildParameters), arg0, arg1)
+}
+
+// InsertWorkspaceDeadlineExtension mocks base method.
+func (m *MockStore) InsertWorkspaceDeadlineExtension(arg0 context.Context, arg1 database.InsertWorkspaceDeadlineExtensionParams) (database.WorkspaceDeadlineExtension, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWorkspaceDeadlineExtension", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceDeadlineExtension)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertWorkspaceDeadlineExtension indicates an expected call of InsertWorkspaceDeadlineExtension.
+func (mr *MockStoreMockRecorder) InsertWorkspaceDeadlineExtension(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceBuildParameters", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceBuildParameters), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWorkspaceDeadlineExtension", reflect.TypeOf((*MockStore)(nil).InsertWorkspaceDeadlineExtension), arg0, arg1)
 }
 
 // InsertWorkspaceProxy mocks base method.
@@ -2774,6 +4044,21 @@ func (mr *MockStoreMockRecorder) RegisterWorkspaceProxy(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterWorkspaceProxy", reflect.TypeOf((*MockStore)(nil).RegisterWorkspaceProxy), arg0, arg1)
 }
 
+// RotateWorkspaceProxyToken mocks base method.
+func (m *MockStore) RotateWorkspaceProxyToken(arg0 context.Context, arg1 database.RotateWorkspaceProxyTokenParams) (database.WorkspaceProxy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateWorkspaceProxyToken", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceProxy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateWorkspaceProxyToken indicates an expected call of RotateWorkspaceProxyToken.
+func (mr *MockStoreMockRecorder) RotateWorkspaceProxyToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateWorkspaceProxyToken", reflect.TypeOf((*MockStore)(nil).RotateWorkspaceProxyToken), arg0, arg1)
+}
+
 // TryAcquireLock mocks base method.
 func (m *MockStore) TryAcquireLock(arg0 context.Context, arg1 int64) (bool, error) {
 	m.ctrl.T.Helper()
@@ -2803,6 +4088,21 @@ func (mr *MockStoreMockRecorder) UpdateAPIKeyByID(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAPIKeyByID", reflect.TypeOf((*MockStore)(nil).UpdateAPIKeyByID), arg0, arg1)
 }
 
+// UpdateCustomRole mocks base method.
+func (m *MockStore) UpdateCustomRole(arg0 context.Context, arg1 database.UpdateCustomRoleParams) (database.CustomRole, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCustomRole", arg0, arg1)
+	ret0, _ := ret[0].(database.CustomRole)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCustomRole indicates an expected call of UpdateCustomRole.
+func (mr *MockStoreMockRecorder) UpdateCustomRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCustomRole", reflect.TypeOf((*MockStore)(nil).UpdateCustomRole), arg0, arg1)
+}
+
 // UpdateGitAuthLink mocks base method.
 func (m *MockStore) UpdateGitAuthLink(arg0 context.Context, arg1 database.UpdateGitAuthLinkParams) (database.GitAuthLink, error) {
 	m.ctrl.T.Helper()
@@ -2878,6 +4178,34 @@ func (mr *MockStoreMockRecorder) UpdateMemberRoles(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMemberRoles", reflect.TypeOf((*MockStore)(nil).UpdateMemberRoles), arg0, arg1)
 }
 
+// UpdateOrganizationBudgetAlertedThreshold mocks base method.
+func (m *MockStore) UpdateOrganizationBudgetAlertedThreshold(arg0 context.Context, arg1 database.UpdateOrganizationBudgetAlertedThresholdParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrganizationBudgetAlertedThreshold", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrganizationBudgetAlertedThreshold indicates an expected call of UpdateOrganizationBudgetAlertedThreshold.
+func (mr *MockStoreMockRecorder) UpdateOrganizationBudgetAlertedThreshold(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrganizationBudgetAlertedThreshold", reflect.TypeOf((*MockStore)(nil).UpdateOrganizationBudgetAlertedThreshold), arg0, arg1)
+}
+
+// UpdateProvisionerDaemonCacheStats mocks base method.
+func (m *MockStore) UpdateProvisionerDaemonCacheStats(arg0 context.Context, arg1 database.UpdateProvisionerDaemonCacheStatsParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProvisionerDaemonCacheStats", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProvisionerDaemonCacheStats indicates an expected call of UpdateProvisionerDaemonCacheStats.
+func (mr *MockStoreMockRecorder) UpdateProvisionerDaemonCacheStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProvisionerDaemonCacheStats", reflect.TypeOf((*MockStore)(nil).UpdateProvisionerDaemonCacheStats), arg0, arg1)
+}
+
 // UpdateProvisionerJobByID mocks base method.
 func (m *MockStore) UpdateProvisionerJobByID(arg0 context.Context, arg1 database.UpdateProvisionerJobByIDParams) error {
 	m.ctrl.T.Helper()
@@ -2892,6 +4220,20 @@ func (mr *MockStoreMockRecorder) UpdateProvisionerJobByID(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProvisionerJobByID", reflect.TypeOf((*MockStore)(nil).UpdateProvisionerJobByID), arg0, arg1)
 }
 
+// UpdateProvisionerJobNotBeforeByID mocks base method.
+func (m *MockStore) UpdateProvisionerJobNotBeforeByID(arg0 context.Context, arg1 database.UpdateProvisionerJobNotBeforeByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProvisionerJobNotBeforeByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProvisionerJobNotBeforeByID indicates an expected call of UpdateProvisionerJobNotBeforeByID.
+func (mr *MockStoreMockRecorder) UpdateProvisionerJobNotBeforeByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProvisionerJobNotBeforeByID", reflect.TypeOf((*MockStore)(nil).UpdateProvisionerJobNotBeforeByID), arg0, arg1)
+}
+
 // UpdateProvisionerJobWithCancelByID mocks base method.
 func (m *MockStore) UpdateProvisionerJobWithCancelByID(arg0 context.Context, arg1 database.UpdateProvisionerJobWithCancelByIDParams) error {
 	m.ctrl.T.Helper()
@@ -2920,6 +4262,20 @@ func (mr *MockStoreMockRecorder) UpdateProvisionerJobWithCompleteByID(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProvisionerJobWithCompleteByID", reflect.TypeOf((*MockStore)(nil).UpdateProvisionerJobWithCompleteByID), arg0, arg1)
 }
 
+// UpdateProvisionerKeyLastUsedAt mocks base method.
+func (m *MockStore) UpdateProvisionerKeyLastUsedAt(arg0 context.Context, arg1 database.UpdateProvisionerKeyLastUsedAtParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProvisionerKeyLastUsedAt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProvisionerKeyLastUsedAt indicates an expected call of UpdateProvisionerKeyLastUsedAt.
+func (mr *MockStoreMockRecorder) UpdateProvisionerKeyLastUsedAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProvisionerKeyLastUsedAt", reflect.TypeOf((*MockStore)(nil).UpdateProvisionerKeyLastUsedAt), arg0, arg1)
+}
+
 // UpdateReplica mocks base method.
 func (m *MockStore) UpdateReplica(arg0 context.Context, arg1 database.UpdateReplicaParams) (database.Replica, error) {
 	m.ctrl.T.Helper()
@@ -2935,6 +4291,36 @@ func (mr *MockStoreMockRecorder) UpdateReplica(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReplica", reflect.TypeOf((*MockStore)(nil).UpdateReplica), arg0, arg1)
 }
 
+// UpdateSavedWorkspaceFilter mocks base method.
+func (m *MockStore) UpdateSavedWorkspaceFilter(arg0 context.Context, arg1 database.UpdateSavedWorkspaceFilterParams) (database.SavedWorkspaceFilter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSavedWorkspaceFilter", arg0, arg1)
+	ret0, _ := ret[0].(database.SavedWorkspaceFilter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSavedWorkspaceFilter indicates an expected call of UpdateSavedWorkspaceFilter.
+func (mr *MockStoreMockRecorder) UpdateSavedWorkspaceFilter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSavedWorkspaceFilter", reflect.TypeOf((*MockStore)(nil).UpdateSavedWorkspaceFilter), arg0, arg1)
+}
+
+// UpdateScheduleHolidayByID mocks base method.
+func (m *MockStore) UpdateScheduleHolidayByID(arg0 context.Context, arg1 database.UpdateScheduleHolidayByIDParams) (database.ScheduleHoliday, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScheduleHolidayByID", arg0, arg1)
+	ret0, _ := ret[0].(database.ScheduleHoliday)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateScheduleHolidayByID indicates an expected call of UpdateScheduleHolidayByID.
+func (mr *MockStoreMockRecorder) UpdateScheduleHolidayByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScheduleHolidayByID", reflect.TypeOf((*MockStore)(nil).UpdateScheduleHolidayByID), arg0, arg1)
+}
+
 // UpdateTemplateACLByID mocks base method.
 func (m *MockStore) UpdateTemplateACLByID(arg0 context.Context, arg1 database.UpdateTemplateACLByIDParams) error {
 	m.ctrl.T.Helper()
@@ -2963,6 +4349,34 @@ func (mr *MockStoreMockRecorder) UpdateTemplateActiveVersionByID(arg0, arg1 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateActiveVersionByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateActiveVersionByID), arg0, arg1)
 }
 
+// UpdateTemplateDeadlineRecalculationCompletedByID mocks base method.
+func (m *MockStore) UpdateTemplateDeadlineRecalculationCompletedByID(arg0 context.Context, arg1 database.UpdateTemplateDeadlineRecalculationCompletedByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateDeadlineRecalculationCompletedByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTemplateDeadlineRecalculationCompletedByID indicates an expected call of UpdateTemplateDeadlineRecalculationCompletedByID.
+func (mr *MockStoreMockRecorder) UpdateTemplateDeadlineRecalculationCompletedByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateDeadlineRecalculationCompletedByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateDeadlineRecalculationCompletedByID), arg0, arg1)
+}
+
+// UpdateTemplateDeadlineRecalculationProgressByID mocks base method.
+func (m *MockStore) UpdateTemplateDeadlineRecalculationProgressByID(arg0 context.Context, arg1 database.UpdateTemplateDeadlineRecalculationProgressByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateDeadlineRecalculationProgressByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTemplateDeadlineRecalculationProgressByID indicates an expected call of UpdateTemplateDeadlineRecalculationProgressByID.
+func (mr *MockStoreMockRecorder) UpdateTemplateDeadlineRecalculationProgressByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateDeadlineRecalculationProgressByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateDeadlineRecalculationProgressByID), arg0, arg1)
+}
+
 // UpdateTemplateDeletedByID mocks base method.
 func (m *MockStore) UpdateTemplateDeletedByID(arg0 context.Context, arg1 database.UpdateTemplateDeletedByIDParams) error {
 	m.ctrl.T.Helper()
@@ -3005,6 +4419,20 @@ func (mr *MockStoreMockRecorder) UpdateTemplateScheduleByID(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateScheduleByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateScheduleByID), arg0, arg1)
 }
 
+// UpdateTemplateSchedulePolicyByID mocks base method.
+func (m *MockStore) UpdateTemplateSchedulePolicyByID(arg0 context.Context, arg1 database.UpdateTemplateSchedulePolicyByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateSchedulePolicyByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTemplateSchedulePolicyByID indicates an expected call of UpdateTemplateSchedulePolicyByID.
+func (mr *MockStoreMockRecorder) UpdateTemplateSchedulePolicyByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateSchedulePolicyByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateSchedulePolicyByID), arg0, arg1)
+}
+
 // UpdateTemplateVersionByID mocks base method.
 func (m *MockStore) UpdateTemplateVersionByID(arg0 context.Context, arg1 database.UpdateTemplateVersionByIDParams) error {
 	m.ctrl.T.Helper()
@@ -3047,6 +4475,21 @@ func (mr *MockStoreMockRecorder) UpdateTemplateVersionGitAuthProvidersByJobID(ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateVersionGitAuthProvidersByJobID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateVersionGitAuthProvidersByJobID), arg0, arg1)
 }
 
+// UpdateTemplateVersionPromotionStatusByID mocks base method.
+func (m *MockStore) UpdateTemplateVersionPromotionStatusByID(arg0 context.Context, arg1 database.UpdateTemplateVersionPromotionStatusByIDParams) (database.TemplateVersionPromotion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTemplateVersionPromotionStatusByID", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateVersionPromotion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTemplateVersionPromotionStatusByID indicates an expected call of UpdateTemplateVersionPromotionStatusByID.
+func (mr *MockStoreMockRecorder) UpdateTemplateVersionPromotionStatusByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplateVersionPromotionStatusByID", reflect.TypeOf((*MockStore)(nil).UpdateTemplateVersionPromotionStatusByID), arg0, arg1)
+}
+
 // UpdateUserDeletedByID mocks base method.
 func (m *MockStore) UpdateUserDeletedByID(arg0 context.Context, arg1 database.UpdateUserDeletedByIDParams) error {
 	m.ctrl.T.Helper()
@@ -3180,6 +4623,21 @@ func (mr *MockStoreMockRecorder) UpdateUserRoles(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRoles", reflect.TypeOf((*MockStore)(nil).UpdateUserRoles), arg0, arg1)
 }
 
+// UpdateUserSecret mocks base method.
+func (m *MockStore) UpdateUserSecret(arg0 context.Context, arg1 database.UpdateUserSecretParams) (database.UserSecret, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserSecret", arg0, arg1)
+	ret0, _ := ret[0].(database.UserSecret)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserSecret indicates an expected call of UpdateUserSecret.
+func (mr *MockStoreMockRecorder) UpdateUserSecret(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserSecret", reflect.TypeOf((*MockStore)(nil).UpdateUserSecret), arg0, arg1)
+}
+
 // UpdateUserStatus mocks base method.
 func (m *MockStore) UpdateUserStatus(arg0 context.Context, arg1 database.UpdateUserStatusParams) (database.User, error) {
 	m.ctrl.T.Helper()
@@ -3266,6 +4724,20 @@ func (mr *MockStoreMockRecorder) UpdateWorkspaceAgentMetadata(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceAgentMetadata", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceAgentMetadata), arg0, arg1)
 }
 
+// UpdateWorkspaceAgentScriptStatus mocks base method.
+func (m *MockStore) UpdateWorkspaceAgentScriptStatus(arg0 context.Context, arg1 database.UpdateWorkspaceAgentScriptStatusParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceAgentScriptStatus", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkspaceAgentScriptStatus indicates an expected call of UpdateWorkspaceAgentScriptStatus.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceAgentScriptStatus(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceAgentScriptStatus", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceAgentScriptStatus), arg0, arg1)
+}
+
 // UpdateWorkspaceAgentStartupByID mocks base method.
 func (m *MockStore) UpdateWorkspaceAgentStartupByID(arg0 context.Context, arg1 database.UpdateWorkspaceAgentStartupByIDParams) error {
 	m.ctrl.T.Helper()
@@ -3308,6 +4780,48 @@ func (mr *MockStoreMockRecorder) UpdateWorkspaceAutostart(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceAutostart", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceAutostart), arg0, arg1)
 }
 
+// UpdateWorkspaceBatchCompletedByID mocks base method.
+func (m *MockStore) UpdateWorkspaceBatchCompletedByID(arg0 context.Context, arg1 database.UpdateWorkspaceBatchCompletedByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceBatchCompletedByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkspaceBatchCompletedByID indicates an expected call of UpdateWorkspaceBatchCompletedByID.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceBatchCompletedByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceBatchCompletedByID", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceBatchCompletedByID), arg0, arg1)
+}
+
+// UpdateWorkspaceBatchUpdatedAtByID mocks base method.
+func (m *MockStore) UpdateWorkspaceBatchUpdatedAtByID(arg0 context.Context, arg1 database.UpdateWorkspaceBatchUpdatedAtByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceBatchUpdatedAtByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkspaceBatchUpdatedAtByID indicates an expected call of UpdateWorkspaceBatchUpdatedAtByID.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceBatchUpdatedAtByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceBatchUpdatedAtByID", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceBatchUpdatedAtByID), arg0, arg1)
+}
+
+// UpdateWorkspaceBatchWorkspace mocks base method.
+func (m *MockStore) UpdateWorkspaceBatchWorkspace(arg0 context.Context, arg1 database.UpdateWorkspaceBatchWorkspaceParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceBatchWorkspace", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkspaceBatchWorkspace indicates an expected call of UpdateWorkspaceBatchWorkspace.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceBatchWorkspace(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceBatchWorkspace", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceBatchWorkspace), arg0, arg1)
+}
+
 // UpdateWorkspaceBuildByID mocks base method.
 func (m *MockStore) UpdateWorkspaceBuildByID(arg0 context.Context, arg1 database.UpdateWorkspaceBuildByIDParams) error {
 	m.ctrl.T.Helper()
@@ -3350,6 +4864,20 @@ func (mr *MockStoreMockRecorder) UpdateWorkspaceDeletedByID(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceDeletedByID", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceDeletedByID), arg0, arg1)
 }
 
+// UpdateWorkspaceGroupACLByID mocks base method.
+func (m *MockStore) UpdateWorkspaceGroupACLByID(arg0 context.Context, arg1 database.UpdateWorkspaceGroupACLByIDParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkspaceGroupACLByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkspaceGroupACLByID indicates an expected call of UpdateWorkspaceGroupACLByID.
+func (mr *MockStoreMockRecorder) UpdateWorkspaceGroupACLByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspaceGroupACLByID", reflect.TypeOf((*MockStore)(nil).UpdateWorkspaceGroupACLByID), arg0, arg1)
+}
+
 // UpdateWorkspaceLastUsedAt mocks base method.
 func (m *MockStore) UpdateWorkspaceLastUsedAt(arg0 context.Context, arg1 database.UpdateWorkspaceLastUsedAtParams) error {
 	m.ctrl.T.Helper()
@@ -3436,6 +4964,20 @@ func (mr *MockStoreMockRecorder) UpdateWorkspacesDeletingAtByTemplateID(arg0, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkspacesDeletingAtByTemplateID", reflect.TypeOf((*MockStore)(nil).UpdateWorkspacesDeletingAtByTemplateID), arg0, arg1)
 }
 
+// UpsertACMECertificate mocks base method.
+func (m *MockStore) UpsertACMECertificate(arg0 context.Context, arg1 database.UpsertACMECertificateParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertACMECertificate", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertACMECertificate indicates an expected call of UpsertACMECertificate.
+func (mr *MockStoreMockRecorder) UpsertACMECertificate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertACMECertificate", reflect.TypeOf((*MockStore)(nil).UpsertACMECertificate), arg0, arg1)
+}
+
 // UpsertAppSecurityKey mocks base method.
 func (m *MockStore) UpsertAppSecurityKey(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -3450,6 +4992,20 @@ func (mr *MockStoreMockRecorder) UpsertAppSecurityKey(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAppSecurityKey", reflect.TypeOf((*MockStore)(nil).UpsertAppSecurityKey), arg0, arg1)
 }
 
+// UpsertAuditLogExportCursor mocks base method.
+func (m *MockStore) UpsertAuditLogExportCursor(arg0 context.Context, arg1 database.UpsertAuditLogExportCursorParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertAuditLogExportCursor", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertAuditLogExportCursor indicates an expected call of UpsertAuditLogExportCursor.
+func (mr *MockStoreMockRecorder) UpsertAuditLogExportCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertAuditLogExportCursor", reflect.TypeOf((*MockStore)(nil).UpsertAuditLogExportCursor), arg0, arg1)
+}
+
 // UpsertDefaultProxy mocks base method.
 func (m *MockStore) UpsertDefaultProxy(arg0 context.Context, arg1 database.UpsertDefaultProxyParams) error {
 	m.ctrl.T.Helper()
@@ -3506,6 +5062,81 @@ func (mr *MockStoreMockRecorder) UpsertOAuthSigningKey(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOAuthSigningKey", reflect.TypeOf((*MockStore)(nil).UpsertOAuthSigningKey), arg0, arg1)
 }
 
+// UpsertOrganizationBudgetSettings mocks base method.
+func (m *MockStore) UpsertOrganizationBudgetSettings(arg0 context.Context, arg1 database.UpsertOrganizationBudgetSettingsParams) (database.OrganizationBudgetSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOrganizationBudgetSettings", arg0, arg1)
+	ret0, _ := ret[0].(database.OrganizationBudgetSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertOrganizationBudgetSettings indicates an expected call of UpsertOrganizationBudgetSettings.
+func (mr *MockStoreMockRecorder) UpsertOrganizationBudgetSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOrganizationBudgetSettings", reflect.TypeOf((*MockStore)(nil).UpsertOrganizationBudgetSettings), arg0, arg1)
+}
+
+// UpsertOrganizationJITProvisioningSettings mocks base method.
+func (m *MockStore) UpsertOrganizationJITProvisioningSettings(arg0 context.Context, arg1 database.UpsertOrganizationJITProvisioningSettingsParams) (database.OrganizationJITProvisioningSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOrganizationJITProvisioningSettings", arg0, arg1)
+	ret0, _ := ret[0].(database.OrganizationJITProvisioningSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertOrganizationJITProvisioningSettings indicates an expected call of UpsertOrganizationJITProvisioningSettings.
+func (mr *MockStoreMockRecorder) UpsertOrganizationJITProvisioningSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOrganizationJITProvisioningSettings", reflect.TypeOf((*MockStore)(nil).UpsertOrganizationJITProvisioningSettings), arg0, arg1)
+}
+
+// UpsertOrganizationProvisionerSettings mocks base method.
+func (m *MockStore) UpsertOrganizationProvisionerSettings(arg0 context.Context, arg1 database.UpsertOrganizationProvisionerSettingsParams) (database.OrganizationProvisionerSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOrganizationProvisionerSettings", arg0, arg1)
+	ret0, _ := ret[0].(database.OrganizationProvisionerSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertOrganizationProvisionerSettings indicates an expected call of UpsertOrganizationProvisionerSettings.
+func (mr *MockStoreMockRecorder) UpsertOrganizationProvisionerSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOrganizationProvisionerSettings", reflect.TypeOf((*MockStore)(nil).UpsertOrganizationProvisionerSettings), arg0, arg1)
+}
+
+// UpsertOrganizationScheduleSettings mocks base method.
+func (m *MockStore) UpsertOrganizationScheduleSettings(arg0 context.Context, arg1 database.UpsertOrganizationScheduleSettingsParams) (database.OrganizationScheduleSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOrganizationScheduleSettings", arg0, arg1)
+	ret0, _ := ret[0].(database.OrganizationScheduleSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertOrganizationScheduleSettings indicates an expected call of UpsertOrganizationScheduleSettings.
+func (mr *MockStoreMockRecorder) UpsertOrganizationScheduleSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOrganizationScheduleSettings", reflect.TypeOf((*MockStore)(nil).UpsertOrganizationScheduleSettings), arg0, arg1)
+}
+
+// UpsertOrganizationTemplateVariable mocks base method.
+func (m *MockStore) UpsertOrganizationTemplateVariable(arg0 context.Context, arg1 database.UpsertOrganizationTemplateVariableParams) (database.OrganizationTemplateVariable, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertOrganizationTemplateVariable", arg0, arg1)
+	ret0, _ := ret[0].(database.OrganizationTemplateVariable)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertOrganizationTemplateVariable indicates an expected call of UpsertOrganizationTemplateVariable.
+func (mr *MockStoreMockRecorder) UpsertOrganizationTemplateVariable(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertOrganizationTemplateVariable", reflect.TypeOf((*MockStore)(nil).UpsertOrganizationTemplateVariable), arg0, arg1)
+}
+
 // UpsertServiceBanner mocks base method.
 func (m *MockStore) UpsertServiceBanner(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -3565,6 +5196,95 @@ func (mr *MockStoreMockRecorder) UpsertTailnetCoordinator(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTailnetCoordinator", reflect.TypeOf((*MockStore)(nil).UpsertTailnetCoordinator), arg0, arg1)
 }
 
+// UpsertTemplateParameterValidation mocks base method.
+func (m *MockStore) UpsertTemplateParameterValidation(arg0 context.Context, arg1 database.UpsertTemplateParameterValidationParams) (database.TemplateParameterValidation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTemplateParameterValidation", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplateParameterValidation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTemplateParameterValidation indicates an expected call of UpsertTemplateParameterValidation.
+func (mr *MockStoreMockRecorder) UpsertTemplateParameterValidation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTemplateParameterValidation", reflect.TypeOf((*MockStore)(nil).UpsertTemplateParameterValidation), arg0, arg1)
+}
+
+// UpsertTemplatePrebuildPool mocks base method.
+func (m *MockStore) UpsertTemplatePrebuildPool(arg0 context.Context, arg1 database.UpsertTemplatePrebuildPoolParams) (database.TemplatePrebuildPool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertTemplatePrebuildPool", arg0, arg1)
+	ret0, _ := ret[0].(database.TemplatePrebuildPool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertTemplatePrebuildPool indicates an expected call of UpsertTemplatePrebuildPool.
+func (mr *MockStoreMockRecorder) UpsertTemplatePrebuildPool(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertTemplatePrebuildPool", reflect.TypeOf((*MockStore)(nil).UpsertTemplatePrebuildPool), arg0, arg1)
+}
+
+// UpsertUserDotfiles mocks base method.
+func (m *MockStore) UpsertUserDotfiles(arg0 context.Context, arg1 database.UpsertUserDotfilesParams) (database.UserDotfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertUserDotfiles", arg0, arg1)
+	ret0, _ := ret[0].(database.UserDotfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertUserDotfiles indicates an expected call of UpsertUserDotfiles.
+func (mr *MockStoreMockRecorder) UpsertUserDotfiles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUserDotfiles", reflect.TypeOf((*MockStore)(nil).UpsertUserDotfiles), arg0, arg1)
+}
+
+// UpsertWorkspaceAgentPluginMetadata mocks base method.
+func (m *MockStore) UpsertWorkspaceAgentPluginMetadata(arg0 context.Context, arg1 database.UpsertWorkspaceAgentPluginMetadataParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspaceAgentPluginMetadata", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertWorkspaceAgentPluginMetadata indicates an expected call of UpsertWorkspaceAgentPluginMetadata.
+func (mr *MockStoreMockRecorder) UpsertWorkspaceAgentPluginMetadata(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceAgentPluginMetadata", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceAgentPluginMetadata), arg0, arg1)
+}
+
+// UpsertWorkspaceNameRedirect mocks base method.
+func (m *MockStore) UpsertWorkspaceNameRedirect(arg0 context.Context, arg1 database.UpsertWorkspaceNameRedirectParams) (database.WorkspaceNameRedirect, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspaceNameRedirect", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceNameRedirect)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertWorkspaceNameRedirect indicates an expected call of UpsertWorkspaceNameRedirect.
+func (mr *MockStoreMockRecorder) UpsertWorkspaceNameRedirect(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceNameRedirect", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceNameRedirect), arg0, arg1)
+}
+
+// UpsertWorkspaceScheduleOverride mocks base method.
+func (m *MockStore) UpsertWorkspaceScheduleOverride(arg0 context.Context, arg1 database.UpsertWorkspaceScheduleOverrideParams) (database.WorkspaceScheduleOverride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertWorkspaceScheduleOverride", arg0, arg1)
+	ret0, _ := ret[0].(database.WorkspaceScheduleOverride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertWorkspaceScheduleOverride indicates an expected call of UpsertWorkspaceScheduleOverride.
+func (mr *MockStoreMockRecorder) UpsertWorkspaceScheduleOverride(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertWorkspaceScheduleOverride", reflect.TypeOf((*MockStore)(nil).UpsertWorkspaceScheduleOverride), arg0, arg1)
+}
+
 // Wrappers mocks base method.
 func (m *MockStore) Wrappers() []string {
 	m.ctrl.T.Helper()
@@ -45,6 +45,12 @@ func New(ctx context.Context, logger slog.Logger, db database.Store) io.Closer {
 			eg.Go(func() error {
 				return db.DeleteOldWorkspaceAgentStats(ctx)
 			})
+			eg.Go(func() error {
+				return db.DeleteExpiredWorkspaceNameRedirects(ctx)
+			})
+			eg.Go(func() error {
+				return db.DeleteOldWorkspaceAppAccessLogs(ctx)
+			})
 			err := eg.Wait()
 			if err != nil {
 				if errors.Is(err, context.Canceled) {
@@ -2,7 +2,11 @@
 
 CREATE TYPE api_key_scope AS ENUM (
     'all',
-    'application_connect'
+    'application_connect',
+    'workspace:read',
+    'workspace:ssh',
+    'workspace:apps',
+    'template:read'
 );
 
 CREATE TYPE app_sharing_level AS ENUM (
@@ -28,12 +32,21 @@ CREATE TYPE build_reason AS ENUM (
     'autostop',
     'autolock',
     'failedstop',
-    'autodelete'
+    'autodelete',
+    'restart_requirement',
+    'failure_ttl',
+    'inactivity_ttl',
+    'locked_ttl',
+    'admin_forced',
+    'max_lifetime',
+    'prebuild',
+    'resume'
 );
 
 CREATE TYPE group_source AS ENUM (
     'user',
-    'oidc'
+    'oidc',
+    'scim'
 );
 
 CREATE TYPE log_level AS ENUM (
@@ -91,11 +104,6 @@ CREATE TYPE provisioner_storage_method AS ENUM (
     'file'
 );
 
-CREATE TYPE provisioner_type AS ENUM (
-    'echo',
-    'terraform'
-);
-
 CREATE TYPE resource_type AS ENUM (
     'organization',
     'template',
@@ -108,7 +116,12 @@ CREATE TYPE resource_type AS ENUM (
     'workspace_build',
     'license',
     'workspace_proxy',
-    'convert_login'
+    'convert_login',
+    'template_version_promotion',
+    'provisioner_key',
+    'template_parameter_validation',
+    'organization_template_variable',
+    'custom_role'
 );
 
 CREATE TYPE startup_script_behavior AS ENUM (
@@ -116,6 +129,19 @@ CREATE TYPE startup_script_behavior AS ENUM (
     'non-blocking'
 );
 
+CREATE TYPE template_deadline_recalculation_status AS ENUM (
+    'pending',
+    'running',
+    'succeeded',
+    'failed'
+);
+
+CREATE TYPE template_version_promotion_status AS ENUM (
+    'pending',
+    'promoted',
+    'rejected'
+);
+
 CREATE TYPE user_status AS ENUM (
     'active',
     'suspended',
@@ -142,7 +168,18 @@ CREATE TYPE workspace_agent_log_source AS ENUM (
     'kubernetes_logs',
     'envbox',
     'envbuilder',
-    'external'
+    'external',
+    'dotfiles',
+    'devcontainer'
+);
+
+CREATE TYPE workspace_agent_script_status AS ENUM (
+    'pending',
+    'running',
+    'ok',
+    'error',
+    'timed_out',
+    'skipped'
 );
 
 CREATE TYPE workspace_agent_subsystem AS ENUM (
@@ -152,6 +189,11 @@ CREATE TYPE workspace_agent_subsystem AS ENUM (
     'exectrace'
 );
 
+CREATE TYPE workspace_app_auth AS ENUM (
+    'coder',
+    'oidc'
+);
+
 CREATE TYPE workspace_app_health AS ENUM (
     'disabled',
     'initializing',
@@ -159,10 +201,33 @@ CREATE TYPE workspace_app_health AS ENUM (
     'unhealthy'
 );
 
+CREATE TYPE workspace_batch_status AS ENUM (
+    'pending',
+    'running',
+    'completed',
+    'failed'
+);
+
+CREATE TYPE workspace_batch_workspace_status AS ENUM (
+    'pending',
+    'queued',
+    'skipped',
+    'failed'
+);
+
+CREATE TYPE workspace_lock_reason AS ENUM (
+    'manual',
+    'inactivity',
+    'max_lifetime'
+);
+
+COMMENT ON TYPE workspace_lock_reason IS 'Why a workspace was locked: by a user, after it was inactive for the inactivity TTL of its template, or after it reached the max lifetime of its template.';
+
 CREATE TYPE workspace_transition AS ENUM (
     'start',
     'stop',
-    'delete'
+    'delete',
+    'archive'
 );
 
 CREATE FUNCTION delete_deleted_user_api_keys() RETURNS trigger
@@ -232,6 +297,22 @@ BEGIN
 END;
 $$;
 
+CREATE TABLE acme_certificates (
+    domain text NOT NULL,
+    data bytea NOT NULL,
+    not_after timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    workspace_proxy_id uuid DEFAULT '00000000-0000-0000-0000-000000000000'::uuid NOT NULL
+);
+
+COMMENT ON TABLE acme_certificates IS 'Certificates issued over ACME for wildcard app domains, shared by all replicas serving the domain';
+
+COMMENT ON COLUMN acme_certificates.data IS 'The ACME account key, certificate chain and certificate key, encrypted with the key configured on the replicas';
+
+COMMENT ON COLUMN acme_certificates.not_after IS 'When the certificate expires';
+
+COMMENT ON COLUMN acme_certificates.workspace_proxy_id IS 'The workspace proxy whose replicas serve the domain, or the nil UUID for the replicas of the primary';
+
 CREATE TABLE api_keys (
     id text NOT NULL,
     hashed_secret bytea NOT NULL,
@@ -249,6 +330,21 @@ CREATE TABLE api_keys (
 
 COMMENT ON COLUMN api_keys.hashed_secret IS 'hashed_secret contains a SHA256 hash of the key secret. This is considered a secret and MUST NOT be returned from the API as it is used for API key encryption in app proxying code.';
 
+CREATE TABLE audit_log_export_cursors (
+    sink text NOT NULL,
+    last_time timestamp with time zone NOT NULL,
+    last_id uuid NOT NULL,
+    leased_by uuid NOT NULL,
+    leased_until timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE audit_log_export_cursors IS 'The last audit log exported to each audit export sink';
+
+COMMENT ON COLUMN audit_log_export_cursors.sink IS 'Identifies the sink by its type and URL, so changing the sink starts a new cursor';
+
+COMMENT ON COLUMN audit_log_export_cursors.leased_by IS 'The sink that is delivering the audit logs after the cursor, so other replicas don''t deliver them too';
+
 CREATE TABLE audit_logs (
     id uuid NOT NULL,
     "time" timestamp with time zone NOT NULL,
@@ -267,6 +363,21 @@ CREATE TABLE audit_logs (
     resource_icon text NOT NULL
 );
 
+CREATE TABLE custom_roles (
+    name text NOT NULL,
+    display_name text NOT NULL,
+    site_permissions jsonb DEFAULT '[]'::jsonb NOT NULL,
+    user_permissions jsonb DEFAULT '[]'::jsonb NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE custom_roles IS 'Site wide roles defined by admins in addition to the built-in roles';
+
+COMMENT ON COLUMN custom_roles.site_permissions IS 'Permissions that apply to all resources of the site';
+
+COMMENT ON COLUMN custom_roles.user_permissions IS 'Permissions that apply to resources owned by the user with the role';
+
 CREATE TABLE files (
     hash character varying(64) NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -333,6 +444,40 @@ CREATE SEQUENCE licenses_id_seq
 
 ALTER SEQUENCE licenses_id_seq OWNED BY licenses.id;
 
+CREATE TABLE organization_budget_settings (
+    organization_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    daily_budget bigint DEFAULT 0 NOT NULL,
+    warning_threshold_percent integer DEFAULT 80 NOT NULL,
+    critical_threshold_percent integer DEFAULT 100 NOT NULL,
+    alerted_threshold_percent integer DEFAULT 0 NOT NULL
+);
+
+COMMENT ON TABLE organization_budget_settings IS 'Budget that the quota consumption of the workspaces of an organization is compared to, to send alerts';
+
+COMMENT ON COLUMN organization_budget_settings.daily_budget IS 'The quota credits the workspaces of the organization consume per day in total before the budget is exceeded. If 0, no alerts are sent.';
+
+COMMENT ON COLUMN organization_budget_settings.warning_threshold_percent IS 'Percentage of the daily budget at which a warning is sent';
+
+COMMENT ON COLUMN organization_budget_settings.critical_threshold_percent IS 'Percentage of the daily budget at which a critical alert is sent';
+
+COMMENT ON COLUMN organization_budget_settings.alerted_threshold_percent IS 'The highest threshold that was crossed when the budget was last evaluated, or 0. Alerts are only sent when a higher threshold is crossed.';
+
+CREATE TABLE organization_jit_provisioning_settings (
+    organization_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    template_id uuid,
+    workspace_name text DEFAULT ''::text NOT NULL
+);
+
+COMMENT ON TABLE organization_jit_provisioning_settings IS 'Workspaces that are created for members of an organization when they log in for the first time';
+
+COMMENT ON COLUMN organization_jit_provisioning_settings.template_id IS 'The template workspaces are created from. If null, no workspaces are created.';
+
+COMMENT ON COLUMN organization_jit_provisioning_settings.workspace_name IS 'The name of created workspaces. If empty, the name of the template is used.';
+
 CREATE TABLE organization_members (
     user_id uuid NOT NULL,
     organization_id uuid NOT NULL,
@@ -341,6 +486,50 @@ CREATE TABLE organization_members (
     roles text[] DEFAULT '{organization-member}'::text[] NOT NULL
 );
 
+CREATE TABLE organization_provisioner_settings (
+    organization_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    max_concurrent_jobs integer DEFAULT 0 NOT NULL
+);
+
+COMMENT ON TABLE organization_provisioner_settings IS 'Provisioner options for jobs in an organization';
+
+COMMENT ON COLUMN organization_provisioner_settings.max_concurrent_jobs IS 'The maximum number of provisioner jobs of the organization that can run at the same time. If zero, the number of jobs is not limited.';
+
+CREATE TABLE organization_schedule_settings (
+    organization_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    default_ttl bigint DEFAULT 0 NOT NULL,
+    restart_requirement_days_of_week smallint DEFAULT 0 NOT NULL,
+    restart_requirement_weeks bigint DEFAULT 0 NOT NULL,
+    default_quiet_hours_schedule text DEFAULT ''::text NOT NULL
+);
+
+COMMENT ON TABLE organization_schedule_settings IS 'Default scheduling options for templates and users in an organization';
+
+COMMENT ON COLUMN organization_schedule_settings.default_ttl IS 'Used for templates in the organization that have no default_ttl set';
+
+COMMENT ON COLUMN organization_schedule_settings.restart_requirement_days_of_week IS 'Used for templates in the organization that have no restart_requirement_days_of_week set';
+
+COMMENT ON COLUMN organization_schedule_settings.restart_requirement_weeks IS 'Used together with restart_requirement_days_of_week';
+
+COMMENT ON COLUMN organization_schedule_settings.default_quiet_hours_schedule IS 'Used for members of the organization that have no quiet hours schedule set, instead of the deployment default';
+
+CREATE TABLE organization_template_variables (
+    organization_id uuid NOT NULL,
+    name text NOT NULL,
+    value text NOT NULL,
+    sensitive boolean DEFAULT false NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE organization_template_variables IS 'Values of Terraform-managed template variables for the templates of an organization';
+
+COMMENT ON COLUMN organization_template_variables.value IS 'Used for template variables of the same name that a template version sets no value for, instead of their default value';
+
 CREATE TABLE organizations (
     id uuid NOT NULL,
     name text NOT NULL,
@@ -386,11 +575,22 @@ CREATE TABLE provisioner_daemons (
     created_at timestamp with time zone NOT NULL,
     updated_at timestamp with time zone,
     name character varying(64) NOT NULL,
-    provisioners provisioner_type[] NOT NULL,
+    provisioners text[] NOT NULL,
     replica_id uuid,
-    tags jsonb DEFAULT '{}'::jsonb NOT NULL
+    tags jsonb DEFAULT '{}'::jsonb NOT NULL,
+    cache_entries integer DEFAULT 0 NOT NULL,
+    cache_size_bytes bigint DEFAULT 0 NOT NULL,
+    cache_max_size_bytes bigint DEFAULT 0 NOT NULL,
+    cache_hits bigint DEFAULT 0 NOT NULL,
+    cache_misses bigint DEFAULT 0 NOT NULL
 );
 
+COMMENT ON COLUMN provisioner_daemons.cache_max_size_bytes IS 'The maximum size of the provider and module cache of the daemon. Zero if the daemon has no cache or has not reported it yet.';
+
+COMMENT ON COLUMN provisioner_daemons.cache_hits IS 'The number of jobs that restored providers and modules from the cache since the daemon started.';
+
+COMMENT ON COLUMN provisioner_daemons.cache_misses IS 'The number of jobs that found no cached providers and modules since the daemon started.';
+
 CREATE TABLE provisioner_job_logs (
     job_id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -398,9 +598,21 @@ CREATE TABLE provisioner_job_logs (
     level log_level NOT NULL,
     stage character varying(128) NOT NULL,
     output character varying(1024) NOT NULL,
-    id bigint NOT NULL
+    id bigint NOT NULL,
+    progress_stage text DEFAULT ''::text NOT NULL,
+    progress_resource text DEFAULT ''::text NOT NULL,
+    progress_completed integer DEFAULT 0 NOT NULL,
+    progress_total integer DEFAULT 0 NOT NULL
 );
 
+COMMENT ON COLUMN provisioner_job_logs.progress_stage IS 'The step of provisioning that the log reports progress of: init, plan, or apply. Empty if the log does not report progress.';
+
+COMMENT ON COLUMN provisioner_job_logs.progress_resource IS 'The address of the resource that the log is about. Empty if the log is not about a resource.';
+
+COMMENT ON COLUMN provisioner_job_logs.progress_completed IS 'The number of resources that are done in the stage.';
+
+COMMENT ON COLUMN provisioner_job_logs.progress_total IS 'The number of resources in the stage. Zero if unknown.';
+
 CREATE SEQUENCE provisioner_job_logs_id_seq
     START WITH 1
     INCREMENT BY 1
@@ -420,7 +632,7 @@ CREATE TABLE provisioner_jobs (
     error text,
     organization_id uuid NOT NULL,
     initiator_id uuid NOT NULL,
-    provisioner provisioner_type NOT NULL,
+    provisioner text NOT NULL,
     storage_method provisioner_storage_method NOT NULL,
     type provisioner_job_type NOT NULL,
     input jsonb NOT NULL,
@@ -428,9 +640,33 @@ CREATE TABLE provisioner_jobs (
     file_id uuid NOT NULL,
     tags jsonb DEFAULT '{"scope": "organization"}'::jsonb NOT NULL,
     error_code text,
-    trace_metadata jsonb
+    trace_metadata jsonb,
+    priority integer DEFAULT 0 NOT NULL,
+    not_before timestamp with time zone
 );
 
+COMMENT ON COLUMN provisioner_jobs.provisioner IS 'The type of provisioner that runs the job: echo, terraform, or the name of a provisioner plugin.';
+
+COMMENT ON COLUMN provisioner_jobs.priority IS 'Jobs with a higher priority are acquired by provisioners first. Jobs with the same priority are acquired in the order they were created.';
+
+COMMENT ON COLUMN provisioner_jobs.not_before IS 'Stop and delete builds aren''t acquired before this time, so the workspace agents of the previous build can drain first. NULL if the job can be acquired right away.';
+
+CREATE TABLE provisioner_keys (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    organization_id uuid NOT NULL,
+    name character varying(64) NOT NULL,
+    hashed_secret bytea NOT NULL,
+    tags jsonb DEFAULT '{}'::jsonb NOT NULL,
+    last_used_at timestamp with time zone
+);
+
+COMMENT ON TABLE provisioner_keys IS 'Keys that external provisioner daemons authenticate with';
+
+COMMENT ON COLUMN provisioner_keys.hashed_secret IS 'The SHA256 hash of the secret of the key';
+
+COMMENT ON COLUMN provisioner_keys.tags IS 'The tags of the provisioner daemons that authenticate with the key';
+
 CREATE TABLE replicas (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -446,6 +682,33 @@ CREATE TABLE replicas (
     "primary" boolean DEFAULT true NOT NULL
 );
 
+CREATE TABLE saved_workspace_filters (
+    id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    query text NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE saved_workspace_filters IS 'Workspace search queries saved by users under a name';
+
+COMMENT ON COLUMN saved_workspace_filters.query IS 'The workspace search query, in the same format as the q parameter of the workspaces endpoint';
+
+CREATE TABLE schedule_holidays (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    date date NOT NULL,
+    name text NOT NULL
+);
+
+COMMENT ON TABLE schedule_holidays IS 'Dates on which template restart requirements are skipped';
+
+COMMENT ON COLUMN schedule_holidays.date IS 'The calendar date of the holiday, in the timezone the restart requirement is evaluated in';
+
+COMMENT ON COLUMN schedule_holidays.name IS 'A human readable name for the holiday';
+
 CREATE TABLE site_configs (
     key character varying(256) NOT NULL,
     value character varying(8192) NOT NULL
@@ -473,6 +736,46 @@ CREATE TABLE tailnet_coordinators (
 
 COMMENT ON TABLE tailnet_coordinators IS 'We keep this separate from replicas in case we need to break the coordinator out into its own service';
 
+CREATE TABLE template_deadline_recalculations (
+    id uuid NOT NULL,
+    template_id uuid NOT NULL,
+    status template_deadline_recalculation_status DEFAULT 'pending'::template_deadline_recalculation_status NOT NULL,
+    total_builds integer DEFAULT 0 NOT NULL,
+    processed_builds integer DEFAULT 0 NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    completed_at timestamp with time zone
+);
+
+COMMENT ON TABLE template_deadline_recalculations IS 'Jobs that recalculate the deadlines of the active workspace builds of templates after their schedule changed';
+
+COMMENT ON COLUMN template_deadline_recalculations.updated_at IS 'Updated while the job is running. Running jobs that have not been updated for a while are picked up by another replica.';
+
+CREATE TABLE template_parameter_validations (
+    template_id uuid NOT NULL,
+    webhook_url text DEFAULT ''::text NOT NULL,
+    constraints jsonb DEFAULT '[]'::jsonb NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE template_parameter_validations IS 'Validation rules that are evaluated for the rich parameter values of workspace builds before they are queued';
+
+COMMENT ON COLUMN template_parameter_validations.webhook_url IS 'Receives the parameter values of builds and responds with validation errors. Empty if no webhook is called.';
+
+COMMENT ON COLUMN template_parameter_validations.constraints IS 'Constraints between the values of different parameters';
+
+CREATE TABLE template_prebuild_pools (
+    template_id uuid NOT NULL,
+    size integer NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE template_prebuild_pools IS 'The number of prebuilt workspaces that are kept provisioned for the active versions of templates';
+
+COMMENT ON COLUMN template_prebuild_pools.size IS 'The number of stopped prebuilt workspaces that are ready to be claimed by users. 0 disables prebuilds for the template.';
+
 CREATE TABLE template_version_parameters (
     template_version_id uuid NOT NULL,
     name text NOT NULL,
@@ -526,6 +829,35 @@ COMMENT ON COLUMN template_version_parameters.display_order IS 'Specifies the or
 
 COMMENT ON COLUMN template_version_parameters.ephemeral IS 'The value of an ephemeral parameter will not be preserved between consecutive workspace builds.';
 
+CREATE TABLE template_version_presets (
+    template_version_id uuid NOT NULL,
+    name text NOT NULL,
+    description text DEFAULT ''::text NOT NULL,
+    parameters jsonb DEFAULT '[]'::jsonb NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE template_version_presets IS 'Named sets of rich parameter values that users can select when creating workspaces';
+
+COMMENT ON COLUMN template_version_presets.parameters IS 'The names and values of the parameters set by the preset';
+
+CREATE TABLE template_version_promotions (
+    id uuid NOT NULL,
+    template_id uuid NOT NULL,
+    template_version_id uuid NOT NULL,
+    requested_by uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    status template_version_promotion_status DEFAULT 'pending'::template_version_promotion_status NOT NULL,
+    approved_by uuid[] DEFAULT '{}'::uuid[] NOT NULL
+);
+
+COMMENT ON TABLE template_version_promotions IS 'Requests to make a template version the active version of its template';
+
+COMMENT ON COLUMN template_version_promotions.requested_by IS 'The user that requested the promotion';
+
+COMMENT ON COLUMN template_version_promotions.approved_by IS 'The users that approved the promotion';
+
 CREATE TABLE template_version_variables (
     template_version_id uuid NOT NULL,
     name text NOT NULL,
@@ -621,7 +953,7 @@ CREATE TABLE templates (
     organization_id uuid NOT NULL,
     deleted boolean DEFAULT false NOT NULL,
     name character varying(64) NOT NULL,
-    provisioner provisioner_type NOT NULL,
+    provisioner text NOT NULL,
     active_version_id uuid NOT NULL,
     description character varying(128) DEFAULT ''::character varying NOT NULL,
     default_ttl bigint DEFAULT '604800000000000'::bigint NOT NULL,
@@ -638,7 +970,25 @@ CREATE TABLE templates (
     inactivity_ttl bigint DEFAULT 0 NOT NULL,
     locked_ttl bigint DEFAULT 0 NOT NULL,
     restart_requirement_days_of_week smallint DEFAULT 0 NOT NULL,
-    restart_requirement_weeks bigint DEFAULT 0 NOT NULL
+    restart_requirement_weeks bigint DEFAULT 0 NOT NULL,
+    restart_requirement_timezone text DEFAULT ''::text NOT NULL,
+    activity_bump_ttl bigint DEFAULT 0 NOT NULL,
+    max_concurrent_builds integer DEFAULT 0 NOT NULL,
+    restart_requirement_spread bigint DEFAULT 0 NOT NULL,
+    max_deadline_extensions_per_day integer DEFAULT 0 NOT NULL,
+    max_deadline_extension bigint DEFAULT 0 NOT NULL,
+    required_promotion_approvals integer DEFAULT 0 NOT NULL,
+    update_on_restart boolean DEFAULT false NOT NULL,
+    failure_retries integer DEFAULT 0 NOT NULL,
+    failure_retry_backoff bigint DEFAULT 0 NOT NULL,
+    schedule_policy_template_id uuid,
+    max_lifetime bigint DEFAULT 0 NOT NULL,
+    archive_retention bigint DEFAULT 0 NOT NULL,
+    user_cpu_quota_millicores bigint DEFAULT 0 NOT NULL,
+    user_memory_quota_bytes bigint DEFAULT 0 NOT NULL,
+    user_disk_quota_bytes bigint DEFAULT 0 NOT NULL,
+    apply_dotfiles boolean DEFAULT true NOT NULL,
+    disable_autostop boolean DEFAULT false NOT NULL
 );
 
 COMMENT ON COLUMN templates.default_ttl IS 'The default duration for autostop for workspaces created from this template.';
@@ -655,6 +1005,42 @@ COMMENT ON COLUMN templates.restart_requirement_days_of_week IS 'A bitmap of day
 
 COMMENT ON COLUMN templates.restart_requirement_weeks IS 'The number of weeks between restarts. 0 or 1 weeks means "every week", 2 week means "every second week", etc. Weeks are counted from January 2, 2023, which is the first Monday of 2023. This is to ensure workspaces are started consistently for all customers on the same n-week cycles.';
 
+COMMENT ON COLUMN templates.restart_requirement_timezone IS 'The IANA timezone to use when calculating the restart requirement. If empty, the timezone of the user''s quiet hours schedule is used.';
+
+COMMENT ON COLUMN templates.activity_bump_ttl IS 'The duration that a workspace''s deadline is extended to from the time activity is detected. Idle workspaces are stopped once this duration has passed without activity. If zero, the deadline is bumped by the workspace''s TTL instead.';
+
+COMMENT ON COLUMN templates.max_concurrent_builds IS 'The maximum number of workspace builds of the template that can run at the same time. If zero, the number of builds is not limited.';
+
+COMMENT ON COLUMN templates.restart_requirement_spread IS 'The duration that the max deadlines of workspaces are spread across after the start of the user''s quiet hours, so workspaces don''t all reach their restart requirement at the same time. If zero, all workspaces reach it at the start of the quiet hours.';
+
+COMMENT ON COLUMN templates.max_deadline_extensions_per_day IS 'The maximum number of times that the deadline of a workspace can be extended by its users within 24 hours. If zero, the number of extensions is not limited.';
+
+COMMENT ON COLUMN templates.max_deadline_extension IS 'The maximum duration that the deadline of a workspace can be extended by at once. If zero, extensions are only limited by the max deadline of the workspace build.';
+
+COMMENT ON COLUMN templates.required_promotion_approvals IS 'The number of approvals required to make a template version the active version. If zero, versions are promoted without approvals.';
+
+COMMENT ON COLUMN templates.update_on_restart IS 'Whether workspaces are updated to the active template version when they are restarted because of the restart requirement.';
+
+COMMENT ON COLUMN templates.failure_retries IS 'The number of times a failed workspace start is retried before the workspace is stopped because of the failure TTL.';
+
+COMMENT ON COLUMN templates.failure_retry_backoff IS 'The duration to wait before the first retry of a failed workspace start. The duration doubles after every retry.';
+
+COMMENT ON COLUMN templates.schedule_policy_template_id IS 'The template to inherit the schedule settings from. If NULL, the template uses its own schedule settings.';
+
+COMMENT ON COLUMN templates.max_lifetime IS 'The duration after the creation of a workspace after which it is deleted, or locked if the template has a locked TTL, regardless of its activity.';
+
+COMMENT ON COLUMN templates.archive_retention IS 'The duration workspaces that reached the locked TTL are archived for before they are deleted. Zero deletes them without archiving.';
+
+COMMENT ON COLUMN templates.user_cpu_quota_millicores IS 'The CPU, in millicores, that the workspaces of the template owned by a single user can use in total. If zero, CPU usage is not limited.';
+
+COMMENT ON COLUMN templates.user_memory_quota_bytes IS 'The memory, in bytes, that the workspaces of the template owned by a single user can use in total. If zero, memory usage is not limited.';
+
+COMMENT ON COLUMN templates.user_disk_quota_bytes IS 'The disk space, in bytes, that the workspaces of the template owned by a single user can use in total. If zero, disk usage is not limited.';
+
+COMMENT ON COLUMN templates.apply_dotfiles IS 'Whether the agents of the template apply the dotfiles repository of the workspace owner when they start.';
+
+COMMENT ON COLUMN templates.disable_autostop IS 'Whether workspaces of the template never stop automatically, even if the organization has a default autostop.';
+
 CREATE VIEW template_with_users AS
  SELECT templates.id,
     templates.created_at,
@@ -680,6 +1066,24 @@ CREATE VIEW template_with_users AS
     templates.locked_ttl,
     templates.restart_requirement_days_of_week,
     templates.restart_requirement_weeks,
+    templates.restart_requirement_timezone,
+    templates.activity_bump_ttl,
+    templates.max_concurrent_builds,
+    templates.restart_requirement_spread,
+    templates.max_deadline_extensions_per_day,
+    templates.max_deadline_extension,
+    templates.required_promotion_approvals,
+    templates.update_on_restart,
+    templates.failure_retries,
+    templates.failure_retry_backoff,
+    templates.schedule_policy_template_id,
+    templates.max_lifetime,
+    templates.archive_retention,
+    templates.user_cpu_quota_millicores,
+    templates.user_memory_quota_bytes,
+    templates.user_disk_quota_bytes,
+    templates.apply_dotfiles,
+    templates.disable_autostop,
     COALESCE(visible_users.avatar_url, ''::text) AS created_by_avatar_url,
     COALESCE(visible_users.username, ''::text) AS created_by_username
    FROM (public.templates
@@ -687,6 +1091,17 @@ CREATE VIEW template_with_users AS
 
 COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
 
+CREATE TABLE user_dotfiles (
+    user_id uuid NOT NULL,
+    repo_url text NOT NULL,
+    branch text DEFAULT ''::text NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE user_dotfiles IS 'The dotfiles repositories of users that the agents of their workspaces apply when they start';
+
+COMMENT ON COLUMN user_dotfiles.branch IS 'The branch of the repository to check out. If empty, the default branch is used.';
+
 CREATE TABLE user_links (
     user_id uuid NOT NULL,
     login_type login_type NOT NULL,
@@ -696,6 +1111,44 @@ CREATE TABLE user_links (
     oauth_expiry timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL
 );
 
+CREATE TABLE user_quiet_hours_exceptions (
+    user_id uuid NOT NULL,
+    date date NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE user_quiet_hours_exceptions IS 'Dates on which the quiet hours window of a user is skipped';
+
+COMMENT ON COLUMN user_quiet_hours_exceptions.date IS 'The calendar date of the skipped quiet hours window, in the timezone of the quiet hours schedule';
+
+CREATE TABLE user_secrets (
+    id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    name text NOT NULL,
+    description text DEFAULT ''::text NOT NULL,
+    value bytea NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE user_secrets IS 'Secrets of users that are given to the agents of their workspaces as environment variables';
+
+COMMENT ON COLUMN user_secrets.name IS 'The name of the secret, which is also the name of its environment variable';
+
+COMMENT ON COLUMN user_secrets.value IS 'The value of the secret, encrypted with the user secrets encryption key of the deployment';
+
+CREATE TABLE workspace_agent_devcontainers (
+    workspace_agent_id uuid NOT NULL,
+    workspace_folder text NOT NULL,
+    config_path text DEFAULT ''::text NOT NULL
+);
+
+COMMENT ON TABLE workspace_agent_devcontainers IS 'The repositories with a devcontainer.json the agent applies when it starts';
+
+COMMENT ON COLUMN workspace_agent_devcontainers.workspace_folder IS 'The directory of the repository the devcontainer.json is applied in.';
+
+COMMENT ON COLUMN workspace_agent_devcontainers.config_path IS 'The path of the devcontainer.json relative to the workspace folder. If empty, the default locations are searched.';
+
 CREATE TABLE workspace_agent_logs (
     agent_id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -714,9 +1167,42 @@ CREATE UNLOGGED TABLE workspace_agent_metadata (
     error character varying(65535) DEFAULT ''::character varying NOT NULL,
     timeout bigint NOT NULL,
     "interval" bigint NOT NULL,
-    collected_at timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL
+    collected_at timestamp with time zone DEFAULT '0001-01-01 00:00:00+00'::timestamp with time zone NOT NULL,
+    plugin boolean DEFAULT false NOT NULL
+);
+
+COMMENT ON COLUMN workspace_agent_metadata.plugin IS 'Whether the metadata was registered at runtime by a metadata plugin of the agent instead of being defined in the template.';
+
+CREATE TABLE workspace_agent_scripts (
+    workspace_agent_id uuid NOT NULL,
+    name character varying(127) NOT NULL,
+    ordinal integer NOT NULL,
+    script character varying(65534) NOT NULL,
+    run_on_start boolean NOT NULL,
+    run_on_stop boolean NOT NULL,
+    timeout_seconds integer NOT NULL,
+    continue_on_error boolean NOT NULL,
+    status workspace_agent_script_status DEFAULT 'pending'::workspace_agent_script_status NOT NULL,
+    exit_code integer DEFAULT 0 NOT NULL,
+    started_at timestamp with time zone,
+    ended_at timestamp with time zone
 );
 
+COMMENT ON COLUMN workspace_agent_scripts.ordinal IS 'The position of the script in the list of scripts of the agent. Scripts run in this order.';
+
+COMMENT ON COLUMN workspace_agent_scripts.timeout_seconds IS 'The number of seconds the script may run before it is killed. Zero means no timeout.';
+
+COMMENT ON COLUMN workspace_agent_scripts.continue_on_error IS 'Whether the following scripts still run if this script fails or times out.';
+
+COMMENT ON COLUMN workspace_agent_scripts.status IS 'The status of the last run of the script.';
+
+CREATE TABLE workspace_agent_secrets (
+    workspace_agent_id uuid NOT NULL,
+    name text NOT NULL
+);
+
+COMMENT ON TABLE workspace_agent_secrets IS 'The names of the secrets of the workspace owner the template gives to the agent';
+
 CREATE SEQUENCE workspace_agent_startup_logs_id_seq
     START WITH 1
     INCREMENT BY 1
@@ -743,7 +1229,9 @@ CREATE TABLE workspace_agent_stats (
     session_count_vscode bigint DEFAULT 0 NOT NULL,
     session_count_jetbrains bigint DEFAULT 0 NOT NULL,
     session_count_reconnecting_pty bigint DEFAULT 0 NOT NULL,
-    session_count_ssh bigint DEFAULT 0 NOT NULL
+    session_count_ssh bigint DEFAULT 0 NOT NULL,
+    peer_count_p2p bigint DEFAULT 0 NOT NULL,
+    peer_count_derp bigint DEFAULT 0 NOT NULL
 );
 
 CREATE TABLE workspace_agents (
@@ -812,6 +1300,50 @@ COMMENT ON COLUMN workspace_agents.started_at IS 'The time the agent entered the
 
 COMMENT ON COLUMN workspace_agents.ready_at IS 'The time the agent entered the ready or start_error lifecycle state';
 
+CREATE TABLE workspace_app_access_logs (
+    id bigint NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    user_id uuid,
+    workspace_id uuid NOT NULL,
+    agent_id uuid NOT NULL,
+    access_method text NOT NULL,
+    slug_or_port text NOT NULL,
+    path_prefix text NOT NULL,
+    status_code integer NOT NULL,
+    latency_ms bigint NOT NULL
+);
+
+COMMENT ON TABLE workspace_app_access_logs IS 'A record of requests to workspace apps';
+
+COMMENT ON COLUMN workspace_app_access_logs.id IS 'The ID of the record';
+
+COMMENT ON COLUMN workspace_app_access_logs.created_at IS 'The time the request was received';
+
+COMMENT ON COLUMN workspace_app_access_logs.user_id IS 'The user who made the request, NULL if they were not signed in to Coder';
+
+COMMENT ON COLUMN workspace_app_access_logs.workspace_id IS 'The workspace that the workspace app belongs to';
+
+COMMENT ON COLUMN workspace_app_access_logs.agent_id IS 'The workspace agent that the request was proxied to';
+
+COMMENT ON COLUMN workspace_app_access_logs.access_method IS 'The method used to access the workspace app';
+
+COMMENT ON COLUMN workspace_app_access_logs.slug_or_port IS 'The slug or port used to to identify the app';
+
+COMMENT ON COLUMN workspace_app_access_logs.path_prefix IS 'The first segment of the requested path within the app';
+
+COMMENT ON COLUMN workspace_app_access_logs.status_code IS 'The HTTP status code of the response';
+
+COMMENT ON COLUMN workspace_app_access_logs.latency_ms IS 'The time in milliseconds until the response was complete';
+
+CREATE SEQUENCE workspace_app_access_logs_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE workspace_app_access_logs_id_seq OWNED BY workspace_app_access_logs.id;
+
 CREATE TABLE workspace_app_stats (
     id bigint NOT NULL,
     user_id uuid NOT NULL,
@@ -822,7 +1354,8 @@ CREATE TABLE workspace_app_stats (
     session_id uuid NOT NULL,
     session_started_at timestamp with time zone NOT NULL,
     session_ended_at timestamp with time zone NOT NULL,
-    requests integer NOT NULL
+    requests integer NOT NULL,
+    active_duration_ms bigint DEFAULT 0 NOT NULL
 );
 
 COMMENT ON TABLE workspace_app_stats IS 'A record of workspace app usage statistics';
@@ -847,6 +1380,8 @@ COMMENT ON COLUMN workspace_app_stats.session_ended_at IS 'The time the session
 
 COMMENT ON COLUMN workspace_app_stats.requests IS 'The number of requests made during the session, a number larger than 1 indicates that multiple sessions were rolled up into one';
 
+COMMENT ON COLUMN workspace_app_stats.active_duration_ms IS 'The time in milliseconds that the app was in use during the session, for rolled up sessions this excludes the time between sessions';
+
 CREATE SEQUENCE workspace_app_stats_id_seq
     START WITH 1
     INCREMENT BY 1
@@ -871,9 +1406,53 @@ CREATE TABLE workspace_apps (
     subdomain boolean DEFAULT false NOT NULL,
     sharing_level app_sharing_level DEFAULT 'owner'::app_sharing_level NOT NULL,
     slug text NOT NULL,
-    external boolean DEFAULT false NOT NULL
+    external boolean DEFAULT false NOT NULL,
+    auth workspace_app_auth DEFAULT 'coder'::workspace_app_auth NOT NULL,
+    rate_limit_requests_per_minute integer DEFAULT 0 NOT NULL,
+    rate_limit_burst integer DEFAULT 0 NOT NULL,
+    rewrite_paths boolean DEFAULT false NOT NULL
+);
+
+COMMENT ON COLUMN workspace_apps.auth IS 'How users that are not signed in to Coder authenticate with the app. With "oidc", anyone that logs in with the workspace app OIDC provider may use the subdomain app.';
+
+COMMENT ON COLUMN workspace_apps.rate_limit_requests_per_minute IS 'The number of requests per minute each user may make to the app. Zero disables rate limiting.';
+
+COMMENT ON COLUMN workspace_apps.rate_limit_burst IS 'The number of requests each user may make to the app in a burst. Zero uses rate_limit_requests_per_minute.';
+
+COMMENT ON COLUMN workspace_apps.rewrite_paths IS 'Whether the proxy rewrites redirects and HTML of the path-based app to keep them under the path the app is served from.';
+
+CREATE TABLE workspace_batch_workspaces (
+    batch_id uuid NOT NULL,
+    workspace_id uuid NOT NULL,
+    "position" integer NOT NULL,
+    status workspace_batch_workspace_status DEFAULT 'pending'::workspace_batch_workspace_status NOT NULL,
+    build_id uuid,
+    error text DEFAULT ''::text NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE workspace_batch_workspaces IS 'The workspaces of a batch and the result of the operation on each of them';
+
+COMMENT ON COLUMN workspace_batch_workspaces."position" IS 'The order the workspaces were matched in, which is the order they are processed in';
+
+COMMENT ON COLUMN workspace_batch_workspaces.build_id IS 'The build queued for the workspace, if any';
+
+CREATE TABLE workspace_batches (
+    id uuid NOT NULL,
+    initiator_id uuid NOT NULL,
+    operation text NOT NULL,
+    query text NOT NULL,
+    status workspace_batch_status DEFAULT 'pending'::workspace_batch_status NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    completed_at timestamp with time zone
 );
 
+COMMENT ON TABLE workspace_batches IS 'Operations on batches of workspaces that are run in the background';
+
+COMMENT ON COLUMN workspace_batches.updated_at IS 'Updated while the batch is running. Running batches that have not been updated for a while are picked up by another replica.';
+
 CREATE TABLE workspace_build_parameters (
     workspace_build_id uuid NOT NULL,
     name text NOT NULL,
@@ -923,6 +1502,31 @@ CREATE VIEW workspace_build_with_user AS
 
 COMMENT ON VIEW workspace_build_with_user IS 'Joins in the username + avatar url of the initiated by user.';
 
+CREATE TABLE workspace_deadline_extensions (
+    id uuid NOT NULL,
+    workspace_id uuid NOT NULL,
+    workspace_build_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    duration bigint NOT NULL
+);
+
+COMMENT ON TABLE workspace_deadline_extensions IS 'Extensions of workspace build deadlines requested by users';
+
+COMMENT ON COLUMN workspace_deadline_extensions.user_id IS 'The user that extended the deadline';
+
+COMMENT ON COLUMN workspace_deadline_extensions.duration IS 'The duration that the deadline was extended by';
+
+CREATE TABLE workspace_name_redirects (
+    owner_id uuid NOT NULL,
+    name text NOT NULL,
+    workspace_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    expires_at timestamp with time zone NOT NULL
+);
+
+COMMENT ON TABLE workspace_name_redirects IS 'Previous names of renamed workspaces. Subdomain app URLs with a previous name redirect to the workspace until the redirect expires.';
+
 CREATE TABLE workspace_proxies (
     id uuid NOT NULL,
     name text NOT NULL,
@@ -936,7 +1540,10 @@ CREATE TABLE workspace_proxies (
     token_hashed_secret bytea NOT NULL,
     region_id integer NOT NULL,
     derp_enabled boolean DEFAULT true NOT NULL,
-    derp_only boolean DEFAULT false NOT NULL
+    derp_only boolean DEFAULT false NOT NULL,
+    token_expires_at timestamp with time zone,
+    previous_token_hashed_secret bytea,
+    previous_token_expires_at timestamp with time zone
 );
 
 COMMENT ON COLUMN workspace_proxies.icon IS 'Expects an emoji character. (/emojis/1f1fa-1f1f8.png)';
@@ -951,6 +1558,10 @@ COMMENT ON COLUMN workspace_proxies.token_hashed_secret IS 'Hashed secret is use
 
 COMMENT ON COLUMN workspace_proxies.derp_only IS 'Disables app/terminal proxying for this proxy and only acts as a DERP relay.';
 
+COMMENT ON COLUMN workspace_proxies.token_expires_at IS 'When the token expires. Tokens created by users never expire, tokens issued by rotation do.';
+
+COMMENT ON COLUMN workspace_proxies.previous_token_hashed_secret IS 'Hashed secret of the token that was replaced by the last rotation. It stays valid until previous_token_expires_at, so all replicas of the proxy can switch to the new token.';
+
 CREATE SEQUENCE workspace_proxies_region_id_seq
     AS integer
     START WITH 1
@@ -991,6 +1602,28 @@ CREATE TABLE workspace_resources (
     daily_cost integer DEFAULT 0 NOT NULL
 );
 
+CREATE TABLE workspace_schedule_overrides (
+    workspace_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    default_ttl bigint DEFAULT 0 NOT NULL,
+    max_ttl bigint DEFAULT 0 NOT NULL,
+    restart_requirement_days_of_week smallint DEFAULT 0 NOT NULL,
+    restart_requirement_weeks bigint DEFAULT 0 NOT NULL
+);
+
+COMMENT ON TABLE workspace_schedule_overrides IS 'Per-workspace overrides for the scheduling options of the workspace''s template';
+
+COMMENT ON COLUMN workspace_schedule_overrides.workspace_id IS 'The workspace the override applies to';
+
+COMMENT ON COLUMN workspace_schedule_overrides.default_ttl IS 'Overrides the template default_ttl for the workspace';
+
+COMMENT ON COLUMN workspace_schedule_overrides.max_ttl IS 'Overrides the template max_ttl for the workspace';
+
+COMMENT ON COLUMN workspace_schedule_overrides.restart_requirement_days_of_week IS 'Overrides the template restart_requirement_days_of_week for the workspace';
+
+COMMENT ON COLUMN workspace_schedule_overrides.restart_requirement_weeks IS 'Overrides the template restart_requirement_weeks for the workspace';
+
 CREATE TABLE workspaces (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -1004,30 +1637,47 @@ CREATE TABLE workspaces (
     ttl bigint,
     last_used_at timestamp without time zone DEFAULT '0001-01-01 00:00:00'::timestamp without time zone NOT NULL,
     locked_at timestamp with time zone,
-    deleting_at timestamp with time zone
+    deleting_at timestamp with time zone,
+    group_acl jsonb DEFAULT '{}'::jsonb NOT NULL,
+    lock_reason workspace_lock_reason
 );
 
+COMMENT ON COLUMN workspaces.group_acl IS 'The groups the workspace is shared with, mapping group IDs to the actions the members of the group may perform. "read" allows using the apps of the workspace, and "create" also allows connecting to the workspace agent, e.g. with SSH.';
+
+COMMENT ON COLUMN workspaces.lock_reason IS 'Why the workspace was locked, NULL if it isn''t locked.';
+
 ALTER TABLE ONLY licenses ALTER COLUMN id SET DEFAULT nextval('licenses_id_seq'::regclass);
 
 ALTER TABLE ONLY provisioner_job_logs ALTER COLUMN id SET DEFAULT nextval('provisioner_job_logs_id_seq'::regclass);
 
 ALTER TABLE ONLY workspace_agent_logs ALTER COLUMN id SET DEFAULT nextval('workspace_agent_startup_logs_id_seq'::regclass);
 
+ALTER TABLE ONLY workspace_app_access_logs ALTER COLUMN id SET DEFAULT nextval('workspace_app_access_logs_id_seq'::regclass);
+
 ALTER TABLE ONLY workspace_app_stats ALTER COLUMN id SET DEFAULT nextval('workspace_app_stats_id_seq'::regclass);
 
 ALTER TABLE ONLY workspace_proxies ALTER COLUMN region_id SET DEFAULT nextval('workspace_proxies_region_id_seq'::regclass);
 
 ALTER TABLE ONLY workspace_resource_metadata ALTER COLUMN id SET DEFAULT nextval('workspace_resource_metadata_id_seq'::regclass);
 
+ALTER TABLE ONLY acme_certificates
+    ADD CONSTRAINT acme_certificates_pkey PRIMARY KEY (workspace_proxy_id, domain);
+
 ALTER TABLE ONLY workspace_agent_stats
     ADD CONSTRAINT agent_stats_pkey PRIMARY KEY (id);
 
 ALTER TABLE ONLY api_keys
     ADD CONSTRAINT api_keys_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY audit_log_export_cursors
+    ADD CONSTRAINT audit_log_export_cursors_pkey PRIMARY KEY (sink);
+
 ALTER TABLE ONLY audit_logs
     ADD CONSTRAINT audit_logs_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY custom_roles
+    ADD CONSTRAINT custom_roles_pkey PRIMARY KEY (name);
+
 ALTER TABLE ONLY files
     ADD CONSTRAINT files_hash_created_by_key UNIQUE (hash, created_by);
 
@@ -1055,9 +1705,24 @@ ALTER TABLE ONLY licenses
 ALTER TABLE ONLY licenses
     ADD CONSTRAINT licenses_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY organization_budget_settings
+    ADD CONSTRAINT organization_budget_settings_pkey PRIMARY KEY (organization_id);
+
+ALTER TABLE ONLY organization_jit_provisioning_settings
+    ADD CONSTRAINT organization_jit_provisioning_settings_pkey PRIMARY KEY (organization_id);
+
 ALTER TABLE ONLY organization_members
     ADD CONSTRAINT organization_members_pkey PRIMARY KEY (organization_id, user_id);
 
+ALTER TABLE ONLY organization_provisioner_settings
+    ADD CONSTRAINT organization_provisioner_settings_pkey PRIMARY KEY (organization_id);
+
+ALTER TABLE ONLY organization_schedule_settings
+    ADD CONSTRAINT organization_schedule_settings_pkey PRIMARY KEY (organization_id);
+
+ALTER TABLE ONLY organization_template_variables
+    ADD CONSTRAINT organization_template_variables_pkey PRIMARY KEY (organization_id, name);
+
 ALTER TABLE ONLY organizations
     ADD CONSTRAINT organizations_pkey PRIMARY KEY (id);
 
@@ -1085,6 +1750,21 @@ ALTER TABLE ONLY provisioner_job_logs
 ALTER TABLE ONLY provisioner_jobs
     ADD CONSTRAINT provisioner_jobs_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY provisioner_keys
+    ADD CONSTRAINT provisioner_keys_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY saved_workspace_filters
+    ADD CONSTRAINT saved_workspace_filters_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY saved_workspace_filters
+    ADD CONSTRAINT saved_workspace_filters_user_id_name_key UNIQUE (user_id, name);
+
+ALTER TABLE ONLY schedule_holidays
+    ADD CONSTRAINT schedule_holidays_date_key UNIQUE (date);
+
+ALTER TABLE ONLY schedule_holidays
+    ADD CONSTRAINT schedule_holidays_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY site_configs
     ADD CONSTRAINT site_configs_key_key UNIQUE (key);
 
@@ -1097,9 +1777,24 @@ ALTER TABLE ONLY tailnet_clients
 ALTER TABLE ONLY tailnet_coordinators
     ADD CONSTRAINT tailnet_coordinators_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY template_deadline_recalculations
+    ADD CONSTRAINT template_deadline_recalculations_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY template_parameter_validations
+    ADD CONSTRAINT template_parameter_validations_pkey PRIMARY KEY (template_id);
+
+ALTER TABLE ONLY template_prebuild_pools
+    ADD CONSTRAINT template_prebuild_pools_pkey PRIMARY KEY (template_id);
+
 ALTER TABLE ONLY template_version_parameters
     ADD CONSTRAINT template_version_parameters_template_version_id_name_key UNIQUE (template_version_id, name);
 
+ALTER TABLE ONLY template_version_presets
+    ADD CONSTRAINT template_version_presets_pkey PRIMARY KEY (template_version_id, name);
+
+ALTER TABLE ONLY template_version_promotions
+    ADD CONSTRAINT template_version_promotions_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY template_version_variables
     ADD CONSTRAINT template_version_variables_template_version_id_name_key UNIQUE (template_version_id, name);
 
@@ -1112,21 +1807,45 @@ ALTER TABLE ONLY template_versions
 ALTER TABLE ONLY templates
     ADD CONSTRAINT templates_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY user_dotfiles
+    ADD CONSTRAINT user_dotfiles_pkey PRIMARY KEY (user_id);
+
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_pkey PRIMARY KEY (user_id, login_type);
 
+ALTER TABLE ONLY user_quiet_hours_exceptions
+    ADD CONSTRAINT user_quiet_hours_exceptions_pkey PRIMARY KEY (user_id, date);
+
+ALTER TABLE ONLY user_secrets
+    ADD CONSTRAINT user_secrets_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY user_secrets
+    ADD CONSTRAINT user_secrets_user_id_name_key UNIQUE (user_id, name);
+
 ALTER TABLE ONLY users
     ADD CONSTRAINT users_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY workspace_agent_devcontainers
+    ADD CONSTRAINT workspace_agent_devcontainers_pkey PRIMARY KEY (workspace_agent_id, workspace_folder);
+
 ALTER TABLE ONLY workspace_agent_metadata
     ADD CONSTRAINT workspace_agent_metadata_pkey PRIMARY KEY (workspace_agent_id, key);
 
+ALTER TABLE ONLY workspace_agent_scripts
+    ADD CONSTRAINT workspace_agent_scripts_pkey PRIMARY KEY (workspace_agent_id, name);
+
+ALTER TABLE ONLY workspace_agent_secrets
+    ADD CONSTRAINT workspace_agent_secrets_pkey PRIMARY KEY (workspace_agent_id, name);
+
 ALTER TABLE ONLY workspace_agent_logs
     ADD CONSTRAINT workspace_agent_startup_logs_pkey PRIMARY KEY (id);
 
 ALTER TABLE ONLY workspace_agents
     ADD CONSTRAINT workspace_agents_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY workspace_app_access_logs
+    ADD CONSTRAINT workspace_app_access_logs_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY workspace_app_stats
     ADD CONSTRAINT workspace_app_stats_pkey PRIMARY KEY (id);
 
@@ -1142,6 +1861,12 @@ ALTER TABLE ONLY workspace_apps
 ALTER TABLE ONLY workspace_build_parameters
     ADD CONSTRAINT workspace_build_parameters_workspace_build_id_name_key UNIQUE (workspace_build_id, name);
 
+ALTER TABLE ONLY workspace_batch_workspaces
+    ADD CONSTRAINT workspace_batch_workspaces_pkey PRIMARY KEY (batch_id, workspace_id);
+
+ALTER TABLE ONLY workspace_batches
+    ADD CONSTRAINT workspace_batches_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_job_id_key UNIQUE (job_id);
 
@@ -1151,6 +1876,12 @@ ALTER TABLE ONLY workspace_builds
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_build_number_key UNIQUE (workspace_id, build_number);
 
+ALTER TABLE ONLY workspace_deadline_extensions
+    ADD CONSTRAINT workspace_deadline_extensions_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY workspace_name_redirects
+    ADD CONSTRAINT workspace_name_redirects_pkey PRIMARY KEY (owner_id, name);
+
 ALTER TABLE ONLY workspace_proxies
     ADD CONSTRAINT workspace_proxies_pkey PRIMARY KEY (id);
 
@@ -1166,6 +1897,9 @@ ALTER TABLE ONLY workspace_resource_metadata
 ALTER TABLE ONLY workspace_resources
     ADD CONSTRAINT workspace_resources_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY workspace_schedule_overrides
+    ADD CONSTRAINT workspace_schedule_overrides_pkey PRIMARY KEY (workspace_id);
+
 ALTER TABLE ONLY workspaces
     ADD CONSTRAINT workspaces_pkey PRIMARY KEY (id);
 
@@ -1207,8 +1941,20 @@ CREATE INDEX provisioner_job_logs_id_job_id_idx ON provisioner_job_logs USING bt
 
 CREATE INDEX provisioner_jobs_started_at_idx ON provisioner_jobs USING btree (started_at) WHERE (started_at IS NULL);
 
+CREATE UNIQUE INDEX provisioner_keys_organization_id_name_idx ON provisioner_keys USING btree (organization_id, lower((name)::text));
+
+CREATE UNIQUE INDEX template_deadline_recalculations_pending_idx ON template_deadline_recalculations USING btree (template_id) WHERE (status = 'pending'::template_deadline_recalculation_status);
+
+CREATE INDEX template_deadline_recalculations_template_id_created_at_idx ON template_deadline_recalculations USING btree (template_id, created_at);
+
+CREATE UNIQUE INDEX template_version_promotions_pending_idx ON template_version_promotions USING btree (template_version_id) WHERE (status = 'pending'::template_version_promotion_status);
+
+CREATE INDEX template_version_promotions_template_id_created_at_idx ON template_version_promotions USING btree (template_id, created_at);
+
 CREATE UNIQUE INDEX templates_organization_id_name_idx ON templates USING btree (organization_id, lower((name)::text)) WHERE (deleted = false);
 
+CREATE INDEX templates_schedule_policy_template_id_idx ON templates USING btree (schedule_policy_template_id);
+
 CREATE UNIQUE INDEX users_email_lower_idx ON users USING btree (lower(email)) WHERE (deleted = false);
 
 CREATE UNIQUE INDEX users_username_lower_idx ON users USING btree (lower(username)) WHERE (deleted = false);
@@ -1219,8 +1965,16 @@ CREATE INDEX workspace_agents_auth_token_idx ON workspace_agents USING btree (au
 
 CREATE INDEX workspace_agents_resource_id_idx ON workspace_agents USING btree (resource_id);
 
+CREATE INDEX workspace_app_access_logs_created_at_idx ON workspace_app_access_logs USING btree (created_at);
+
+CREATE INDEX workspace_app_access_logs_workspace_id_idx ON workspace_app_access_logs USING btree (workspace_id);
+
 CREATE INDEX workspace_app_stats_workspace_id_idx ON workspace_app_stats USING btree (workspace_id);
 
+CREATE INDEX workspace_batches_status_idx ON workspace_batches USING btree (status);
+
+CREATE INDEX workspace_deadline_extensions_workspace_id_created_at_idx ON workspace_deadline_extensions USING btree (workspace_id, created_at);
+
 CREATE UNIQUE INDEX workspace_proxies_lower_name_idx ON workspace_proxies USING btree (lower(name)) WHERE (deleted = false);
 
 CREATE INDEX workspace_resources_job_id_idx ON workspace_resources USING btree (job_id);
@@ -1252,12 +2006,30 @@ ALTER TABLE ONLY group_members
 ALTER TABLE ONLY groups
     ADD CONSTRAINT groups_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY organization_budget_settings
+    ADD CONSTRAINT organization_budget_settings_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_jit_provisioning_settings
+    ADD CONSTRAINT organization_jit_provisioning_settings_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_jit_provisioning_settings
+    ADD CONSTRAINT organization_jit_provisioning_settings_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE SET NULL;
+
 ALTER TABLE ONLY organization_members
     ADD CONSTRAINT organization_members_organization_id_uuid_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
 ALTER TABLE ONLY organization_members
     ADD CONSTRAINT organization_members_user_id_uuid_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY organization_provisioner_settings
+    ADD CONSTRAINT organization_provisioner_settings_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_schedule_settings
+    ADD CONSTRAINT organization_schedule_settings_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY organization_template_variables
+    ADD CONSTRAINT organization_template_variables_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY parameter_schemas
     ADD CONSTRAINT parameter_schemas_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
@@ -1267,15 +2039,42 @@ ALTER TABLE ONLY provisioner_job_logs
 ALTER TABLE ONLY provisioner_jobs
     ADD CONSTRAINT provisioner_jobs_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY provisioner_keys
+    ADD CONSTRAINT provisioner_keys_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY saved_workspace_filters
+    ADD CONSTRAINT saved_workspace_filters_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY tailnet_agents
     ADD CONSTRAINT tailnet_agents_coordinator_id_fkey FOREIGN KEY (coordinator_id) REFERENCES tailnet_coordinators(id) ON DELETE CASCADE;
 
 ALTER TABLE ONLY tailnet_clients
     ADD CONSTRAINT tailnet_clients_coordinator_id_fkey FOREIGN KEY (coordinator_id) REFERENCES tailnet_coordinators(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY template_deadline_recalculations
+    ADD CONSTRAINT template_deadline_recalculations_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_parameter_validations
+    ADD CONSTRAINT template_parameter_validations_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_prebuild_pools
+    ADD CONSTRAINT template_prebuild_pools_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY template_version_parameters
     ADD CONSTRAINT template_version_parameters_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY template_version_presets
+    ADD CONSTRAINT template_version_presets_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_version_promotions
+    ADD CONSTRAINT template_version_promotions_requested_by_fkey FOREIGN KEY (requested_by) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_version_promotions
+    ADD CONSTRAINT template_version_promotions_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_version_promotions
+    ADD CONSTRAINT template_version_promotions_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY template_version_variables
     ADD CONSTRAINT template_version_variables_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
 
@@ -1294,18 +2093,48 @@ ALTER TABLE ONLY templates
 ALTER TABLE ONLY templates
     ADD CONSTRAINT templates_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY templates
+    ADD CONSTRAINT templates_schedule_policy_template_id_fkey FOREIGN KEY (schedule_policy_template_id) REFERENCES templates(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY user_dotfiles
+    ADD CONSTRAINT user_dotfiles_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY user_quiet_hours_exceptions
+    ADD CONSTRAINT user_quiet_hours_exceptions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY user_secrets
+    ADD CONSTRAINT user_secrets_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_agent_devcontainers
+    ADD CONSTRAINT workspace_agent_devcontainers_workspace_agent_id_fkey FOREIGN KEY (workspace_agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_agent_metadata
     ADD CONSTRAINT workspace_agent_metadata_workspace_agent_id_fkey FOREIGN KEY (workspace_agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_agent_scripts
+    ADD CONSTRAINT workspace_agent_scripts_workspace_agent_id_fkey FOREIGN KEY (workspace_agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_agent_secrets
+    ADD CONSTRAINT workspace_agent_secrets_workspace_agent_id_fkey FOREIGN KEY (workspace_agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_agent_logs
     ADD CONSTRAINT workspace_agent_startup_logs_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
 
 ALTER TABLE ONLY workspace_agents
     ADD CONSTRAINT workspace_agents_resource_id_fkey FOREIGN KEY (resource_id) REFERENCES workspace_resources(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_app_access_logs
+    ADD CONSTRAINT workspace_app_access_logs_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id);
+
+ALTER TABLE ONLY workspace_app_access_logs
+    ADD CONSTRAINT workspace_app_access_logs_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id);
+
+ALTER TABLE ONLY workspace_app_access_logs
+    ADD CONSTRAINT workspace_app_access_logs_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id);
+
 ALTER TABLE ONLY workspace_app_stats
     ADD CONSTRAINT workspace_app_stats_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id);
 
@@ -1318,6 +2147,15 @@ ALTER TABLE ONLY workspace_app_stats
 ALTER TABLE ONLY workspace_apps
     ADD CONSTRAINT workspace_apps_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES workspace_agents(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_batch_workspaces
+    ADD CONSTRAINT workspace_batch_workspaces_batch_id_fkey FOREIGN KEY (batch_id) REFERENCES workspace_batches(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_batch_workspaces
+    ADD CONSTRAINT workspace_batch_workspaces_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_batches
+    ADD CONSTRAINT workspace_batches_initiator_id_fkey FOREIGN KEY (initiator_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_build_parameters
     ADD CONSTRAINT workspace_build_parameters_workspace_build_id_fkey FOREIGN KEY (workspace_build_id) REFERENCES workspace_builds(id) ON DELETE CASCADE;
 
@@ -1330,12 +2168,30 @@ ALTER TABLE ONLY workspace_builds
 ALTER TABLE ONLY workspace_builds
     ADD CONSTRAINT workspace_builds_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_deadline_extensions
+    ADD CONSTRAINT workspace_deadline_extensions_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_deadline_extensions
+    ADD CONSTRAINT workspace_deadline_extensions_workspace_build_id_fkey FOREIGN KEY (workspace_build_id) REFERENCES workspace_builds(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_deadline_extensions
+    ADD CONSTRAINT workspace_deadline_extensions_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_name_redirects
+    ADD CONSTRAINT workspace_name_redirects_owner_id_fkey FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY workspace_name_redirects
+    ADD CONSTRAINT workspace_name_redirects_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_resource_metadata
     ADD CONSTRAINT workspace_resource_metadata_workspace_resource_id_fkey FOREIGN KEY (workspace_resource_id) REFERENCES workspace_resources(id) ON DELETE CASCADE;
 
 ALTER TABLE ONLY workspace_resources
     ADD CONSTRAINT workspace_resources_job_id_fkey FOREIGN KEY (job_id) REFERENCES provisioner_jobs(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY workspace_schedule_overrides
+    ADD CONSTRAINT workspace_schedule_overrides_workspace_id_fkey FOREIGN KEY (workspace_id) REFERENCES workspaces(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspaces
     ADD CONSTRAINT workspaces_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE RESTRICT;
 
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS workspace_schedule_overrides;
@@ -0,0 +1,17 @@
+CREATE TABLE workspace_schedule_overrides (
+	workspace_id uuid NOT NULL PRIMARY KEY REFERENCES workspaces (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	default_ttl bigint NOT NULL DEFAULT 0,
+	max_ttl bigint NOT NULL DEFAULT 0,
+	restart_requirement_days_of_week smallint NOT NULL DEFAULT 0,
+	restart_requirement_weeks bigint NOT NULL DEFAULT 0
+);
+
+COMMENT ON TABLE workspace_schedule_overrides IS 'Per-workspace overrides for the scheduling options of the workspace''s template';
+
+COMMENT ON COLUMN workspace_schedule_overrides.workspace_id IS 'The workspace the override applies to';
+COMMENT ON COLUMN workspace_schedule_overrides.default_ttl IS 'Overrides the template default_ttl for the workspace';
+COMMENT ON COLUMN workspace_schedule_overrides.max_ttl IS 'Overrides the template max_ttl for the workspace';
+COMMENT ON COLUMN workspace_schedule_overrides.restart_requirement_days_of_week IS 'Overrides the template restart_requirement_days_of_week for the workspace';
+COMMENT ON COLUMN workspace_schedule_overrides.restart_requirement_weeks IS 'Overrides the template restart_requirement_weeks for the workspace';
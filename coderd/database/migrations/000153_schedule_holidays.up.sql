@@ -0,0 +1,12 @@
+CREATE TABLE schedule_holidays (
+	id uuid NOT NULL PRIMARY KEY,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	date date NOT NULL UNIQUE,
+	name text NOT NULL
+);
+
+COMMENT ON TABLE schedule_holidays IS 'Dates on which template restart requirements are skipped';
+
+COMMENT ON COLUMN schedule_holidays.date IS 'The calendar date of the holiday, in the timezone the restart requirement is evaluated in';
+COMMENT ON COLUMN schedule_holidays.name IS 'A human readable name for the holiday';
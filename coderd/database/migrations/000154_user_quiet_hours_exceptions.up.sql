@@ -0,0 +1,10 @@
+CREATE TABLE user_quiet_hours_exceptions (
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	date date NOT NULL,
+	created_at timestamptz NOT NULL,
+	PRIMARY KEY (user_id, date)
+);
+
+COMMENT ON TABLE user_quiet_hours_exceptions IS 'Dates on which the quiet hours window of a user is skipped';
+
+COMMENT ON COLUMN user_quiet_hours_exceptions.date IS 'The calendar date of the skipped quiet hours window, in the timezone of the quiet hours schedule';
@@ -0,0 +1 @@
+ALTER TABLE workspace_app_stats DROP COLUMN active_duration_ms;
@@ -0,0 +1,7 @@
+ALTER TABLE workspace_app_stats ADD COLUMN active_duration_ms bigint NOT NULL DEFAULT 0;
+
+-- Existing stats don't track active time, so assume the app was in use for
+-- the whole session.
+UPDATE workspace_app_stats SET active_duration_ms = (EXTRACT(epoch FROM session_ended_at - session_started_at) * 1000)::bigint;
+
+COMMENT ON COLUMN workspace_app_stats.active_duration_ms IS 'The time in milliseconds that the app was in use during the session, for rolled up sessions this excludes the time between sessions';
@@ -0,0 +1,25 @@
+BEGIN;
+
+ALTER TABLE templates
+	ADD COLUMN activity_bump bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN templates.activity_bump IS 'The duration that a workspace''s deadline is extended to from the time activity is detected. Idle workspaces are stopped once this duration has passed without activity. If zero, the deadline is bumped by the workspace''s TTL instead.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
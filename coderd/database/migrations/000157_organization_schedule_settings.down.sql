@@ -0,0 +1 @@
+DROP TABLE IF EXISTS organization_schedule_settings;
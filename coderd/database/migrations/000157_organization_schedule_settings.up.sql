@@ -0,0 +1,16 @@
+CREATE TABLE organization_schedule_settings (
+	organization_id uuid NOT NULL PRIMARY KEY REFERENCES organizations (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	default_ttl bigint NOT NULL DEFAULT 0,
+	restart_requirement_days_of_week smallint NOT NULL DEFAULT 0,
+	restart_requirement_weeks bigint NOT NULL DEFAULT 0,
+	default_quiet_hours_schedule text NOT NULL DEFAULT ''
+);
+
+COMMENT ON TABLE organization_schedule_settings IS 'Default scheduling options for templates and users in an organization';
+
+COMMENT ON COLUMN organization_schedule_settings.default_ttl IS 'Used for templates in the organization that have no default_ttl set';
+COMMENT ON COLUMN organization_schedule_settings.restart_requirement_days_of_week IS 'Used for templates in the organization that have no restart_requirement_days_of_week set';
+COMMENT ON COLUMN organization_schedule_settings.restart_requirement_weeks IS 'Used together with restart_requirement_days_of_week';
+COMMENT ON COLUMN organization_schedule_settings.default_quiet_hours_schedule IS 'Used for members of the organization that have no quiet hours schedule set, instead of the deployment default';
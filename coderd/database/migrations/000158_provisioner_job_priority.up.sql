@@ -0,0 +1,4 @@
+ALTER TABLE provisioner_jobs
+	ADD COLUMN priority integer NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN provisioner_jobs.priority IS 'Jobs with a higher priority are acquired by provisioners first. Jobs with the same priority are acquired in the order they were created.';
@@ -0,0 +1,36 @@
+BEGIN;
+
+CREATE TABLE organization_provisioner_settings (
+	organization_id uuid NOT NULL PRIMARY KEY REFERENCES organizations (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	max_concurrent_jobs integer NOT NULL DEFAULT 0
+);
+
+COMMENT ON TABLE organization_provisioner_settings IS 'Provisioner options for jobs in an organization';
+
+COMMENT ON COLUMN organization_provisioner_settings.max_concurrent_jobs IS 'The maximum number of provisioner jobs of the organization that can run at the same time. If zero, the number of jobs is not limited.';
+
+ALTER TABLE templates
+	ADD COLUMN max_concurrent_builds integer NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN templates.max_concurrent_builds IS 'The maximum number of workspace builds of the template that can run at the same time. If zero, the number of builds is not limited.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
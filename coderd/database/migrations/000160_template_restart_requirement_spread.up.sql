@@ -0,0 +1,25 @@
+BEGIN;
+
+ALTER TABLE templates
+	ADD COLUMN restart_requirement_spread bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN templates.restart_requirement_spread IS 'The duration that the max deadlines of workspaces are spread across after the start of the user''s quiet hours, so workspaces don''t all reach their restart requirement at the same time. If zero, all workspaces reach it at the start of the quiet hours.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
@@ -0,0 +1,45 @@
+BEGIN;
+
+CREATE TABLE workspace_deadline_extensions (
+	id uuid NOT NULL PRIMARY KEY,
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	workspace_build_id uuid NOT NULL REFERENCES workspace_builds (id) ON DELETE CASCADE,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL,
+	duration bigint NOT NULL
+);
+
+CREATE INDEX workspace_deadline_extensions_workspace_id_created_at_idx ON workspace_deadline_extensions USING btree (workspace_id, created_at);
+
+COMMENT ON TABLE workspace_deadline_extensions IS 'Extensions of workspace build deadlines requested by users';
+
+COMMENT ON COLUMN workspace_deadline_extensions.user_id IS 'The user that extended the deadline';
+
+COMMENT ON COLUMN workspace_deadline_extensions.duration IS 'The duration that the deadline was extended by';
+
+ALTER TABLE templates
+	ADD COLUMN max_deadline_extensions_per_day integer NOT NULL DEFAULT 0,
+	ADD COLUMN max_deadline_extension bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN templates.max_deadline_extensions_per_day IS 'The maximum number of times that the deadline of a workspace can be extended by its users within 24 hours. If zero, the number of extensions is not limited.';
+
+COMMENT ON COLUMN templates.max_deadline_extension IS 'The maximum duration that the deadline of a workspace can be extended by at once. If zero, extensions are only limited by the max deadline of the workspace build.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
@@ -0,0 +1 @@
+ALTER TABLE provisioner_jobs DROP COLUMN not_before;
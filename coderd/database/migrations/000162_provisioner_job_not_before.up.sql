@@ -0,0 +1,4 @@
+ALTER TABLE provisioner_jobs
+	ADD COLUMN not_before timestamp with time zone;
+
+COMMENT ON COLUMN provisioner_jobs.not_before IS 'Stop and delete builds aren''t acquired before this time, so the workspace agents of the previous build can drain first. NULL if the job can be acquired right away.';
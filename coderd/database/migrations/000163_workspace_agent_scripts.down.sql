@@ -0,0 +1,3 @@
+DROP TABLE workspace_agent_scripts;
+
+DROP TYPE workspace_agent_script_status;
@@ -0,0 +1,32 @@
+CREATE TYPE workspace_agent_script_status AS ENUM (
+	'pending',
+	'running',
+	'ok',
+	'error',
+	'timed_out',
+	'skipped'
+);
+
+CREATE TABLE workspace_agent_scripts (
+	workspace_agent_id uuid NOT NULL REFERENCES workspace_agents(id) ON DELETE CASCADE,
+	name character varying(127) NOT NULL,
+	ordinal integer NOT NULL,
+	script character varying(65534) NOT NULL,
+	run_on_start boolean NOT NULL,
+	run_on_stop boolean NOT NULL,
+	timeout_seconds integer NOT NULL,
+	continue_on_error boolean NOT NULL,
+	status workspace_agent_script_status DEFAULT 'pending'::workspace_agent_script_status NOT NULL,
+	exit_code integer DEFAULT 0 NOT NULL,
+	started_at timestamp with time zone,
+	ended_at timestamp with time zone,
+	PRIMARY KEY (workspace_agent_id, name)
+);
+
+COMMENT ON COLUMN workspace_agent_scripts.ordinal IS 'The position of the script in the list of scripts of the agent. Scripts run in this order.';
+
+COMMENT ON COLUMN workspace_agent_scripts.timeout_seconds IS 'The number of seconds the script may run before it is killed. Zero means no timeout.';
+
+COMMENT ON COLUMN workspace_agent_scripts.continue_on_error IS 'Whether the following scripts still run if this script fails or times out.';
+
+COMMENT ON COLUMN workspace_agent_scripts.status IS 'The status of the last run of the script.';
@@ -0,0 +1 @@
+ALTER TABLE workspace_agent_metadata DROP COLUMN plugin;
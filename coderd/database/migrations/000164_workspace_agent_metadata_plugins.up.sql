@@ -0,0 +1,3 @@
+ALTER TABLE workspace_agent_metadata ADD COLUMN plugin boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN workspace_agent_metadata.plugin IS 'Whether the metadata was registered at runtime by a metadata plugin of the agent instead of being defined in the template.';
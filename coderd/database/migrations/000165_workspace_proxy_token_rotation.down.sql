@@ -0,0 +1,4 @@
+ALTER TABLE workspace_proxies
+	DROP COLUMN token_expires_at,
+	DROP COLUMN previous_token_hashed_secret,
+	DROP COLUMN previous_token_expires_at;
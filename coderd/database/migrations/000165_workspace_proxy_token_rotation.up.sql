@@ -0,0 +1,7 @@
+ALTER TABLE workspace_proxies
+	ADD COLUMN token_expires_at timestamp with time zone,
+	ADD COLUMN previous_token_hashed_secret bytea,
+	ADD COLUMN previous_token_expires_at timestamp with time zone;
+
+COMMENT ON COLUMN workspace_proxies.token_expires_at IS 'When the token expires. Tokens created by users never expire, tokens issued by rotation do.';
+COMMENT ON COLUMN workspace_proxies.previous_token_hashed_secret IS 'Hashed secret of the token that was replaced by the last rotation. It stays valid until previous_token_expires_at, so all replicas of the proxy can switch to the new token.';
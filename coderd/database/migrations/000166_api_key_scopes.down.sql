@@ -0,0 +1,3 @@
+-- Values can't be removed from an enum type, so delete the keys that use
+-- them instead. The keys would be rejected by older versions anyway.
+DELETE FROM api_keys WHERE scope::text NOT IN ('all', 'application_connect');
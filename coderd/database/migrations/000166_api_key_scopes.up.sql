@@ -0,0 +1,4 @@
+ALTER TYPE api_key_scope ADD VALUE IF NOT EXISTS 'workspace:read';
+ALTER TYPE api_key_scope ADD VALUE IF NOT EXISTS 'workspace:ssh';
+ALTER TYPE api_key_scope ADD VALUE IF NOT EXISTS 'workspace:apps';
+ALTER TYPE api_key_scope ADD VALUE IF NOT EXISTS 'template:read';
@@ -0,0 +1,3 @@
+-- Values can't be removed from an enum type, so groups that were pushed by
+-- SCIM become regular groups instead.
+UPDATE groups SET source = 'user' WHERE source = 'scim';
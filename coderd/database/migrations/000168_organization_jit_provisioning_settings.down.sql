@@ -0,0 +1 @@
+DROP TABLE IF EXISTS organization_jit_provisioning_settings;
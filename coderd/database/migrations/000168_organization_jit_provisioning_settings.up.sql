@@ -0,0 +1,12 @@
+CREATE TABLE organization_jit_provisioning_settings (
+	organization_id uuid NOT NULL PRIMARY KEY REFERENCES organizations (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	template_id uuid REFERENCES templates (id) ON DELETE SET NULL,
+	workspace_name text NOT NULL DEFAULT ''
+);
+
+COMMENT ON TABLE organization_jit_provisioning_settings IS 'Workspaces that are created for members of an organization when they log in for the first time';
+
+COMMENT ON COLUMN organization_jit_provisioning_settings.template_id IS 'The template workspaces are created from. If null, no workspaces are created.';
+COMMENT ON COLUMN organization_jit_provisioning_settings.workspace_name IS 'The name of created workspaces. If empty, the name of the template is used.';
@@ -0,0 +1,53 @@
+BEGIN;
+
+CREATE TYPE template_version_promotion_status AS ENUM (
+	'pending',
+	'promoted',
+	'rejected'
+);
+
+CREATE TABLE template_version_promotions (
+	id uuid NOT NULL PRIMARY KEY,
+	template_id uuid NOT NULL REFERENCES templates (id) ON DELETE CASCADE,
+	template_version_id uuid NOT NULL REFERENCES template_versions (id) ON DELETE CASCADE,
+	requested_by uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	status template_version_promotion_status NOT NULL DEFAULT 'pending',
+	approved_by uuid[] NOT NULL DEFAULT '{}'
+);
+
+-- A version can only have one pending promotion at a time.
+CREATE UNIQUE INDEX template_version_promotions_pending_idx ON template_version_promotions USING btree (template_version_id) WHERE (status = 'pending');
+
+CREATE INDEX template_version_promotions_template_id_created_at_idx ON template_version_promotions USING btree (template_id, created_at);
+
+COMMENT ON TABLE template_version_promotions IS 'Requests to make a template version the active version of its template';
+
+COMMENT ON COLUMN template_version_promotions.requested_by IS 'The user that requested the promotion';
+
+COMMENT ON COLUMN template_version_promotions.approved_by IS 'The users that approved the promotion';
+
+ALTER TABLE templates
+	ADD COLUMN required_promotion_approvals integer NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN templates.required_promotion_approvals IS 'The number of approvals required to make a template version the active version. If zero, versions are promoted without approvals.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
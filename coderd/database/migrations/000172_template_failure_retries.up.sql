@@ -0,0 +1,27 @@
+BEGIN;
+
+ALTER TABLE templates
+	ADD COLUMN failure_retries integer NOT NULL DEFAULT 0,
+	ADD COLUMN failure_retry_backoff bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN templates.failure_retries IS 'The number of times a failed workspace start is retried before the workspace is stopped because of the failure TTL.';
+COMMENT ON COLUMN templates.failure_retry_backoff IS 'The duration to wait before the first retry of a failed workspace start. The duration doubles after every retry.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
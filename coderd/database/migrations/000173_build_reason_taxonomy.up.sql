@@ -0,0 +1,7 @@
+BEGIN;
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'restart_requirement';
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'failure_ttl';
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'inactivity_ttl';
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'locked_ttl';
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'admin_forced';
+COMMIT;
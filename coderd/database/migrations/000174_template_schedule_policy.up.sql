@@ -0,0 +1,27 @@
+BEGIN;
+
+ALTER TABLE templates
+	ADD COLUMN schedule_policy_template_id uuid NULL REFERENCES templates (id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN templates.schedule_policy_template_id IS 'The template to inherit the schedule settings from. If NULL, the template uses its own schedule settings.';
+
+CREATE INDEX templates_schedule_policy_template_id_idx ON templates USING btree (schedule_policy_template_id);
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
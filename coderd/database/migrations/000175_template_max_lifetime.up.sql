@@ -0,0 +1,29 @@
+BEGIN;
+
+-- It's not possible to delete enum values, so this value stays after a down
+-- migration.
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'max_lifetime';
+
+ALTER TABLE templates
+	ADD COLUMN max_lifetime bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN templates.max_lifetime IS 'The duration after the creation of a workspace after which it is deleted, or locked if the template has a locked TTL, regardless of its activity.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+-- It's not possible to delete enum values, so this value stays after a down
+-- migration.
+ALTER TYPE workspace_transition ADD VALUE IF NOT EXISTS 'archive';
+
+ALTER TABLE templates
+	ADD COLUMN archive_retention bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN templates.archive_retention IS 'The duration workspaces that reached the locked TTL are archived for before they are deleted. Zero deletes them without archiving.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
@@ -0,0 +1,31 @@
+BEGIN;
+
+ALTER TABLE templates
+	ADD COLUMN user_cpu_quota_millicores bigint NOT NULL DEFAULT 0,
+	ADD COLUMN user_memory_quota_bytes bigint NOT NULL DEFAULT 0,
+	ADD COLUMN user_disk_quota_bytes bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN templates.user_cpu_quota_millicores IS 'The CPU, in millicores, that the workspaces of the template owned by a single user can use in total. If zero, CPU usage is not limited.';
+
+COMMENT ON COLUMN templates.user_memory_quota_bytes IS 'The memory, in bytes, that the workspaces of the template owned by a single user can use in total. If zero, memory usage is not limited.';
+
+COMMENT ON COLUMN templates.user_disk_quota_bytes IS 'The disk space, in bytes, that the workspaces of the template owned by a single user can use in total. If zero, disk usage is not limited.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
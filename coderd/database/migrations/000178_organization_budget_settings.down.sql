@@ -0,0 +1 @@
+DROP TABLE IF EXISTS organization_budget_settings;
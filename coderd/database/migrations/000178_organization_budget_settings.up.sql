@@ -0,0 +1,16 @@
+CREATE TABLE organization_budget_settings (
+	organization_id uuid NOT NULL PRIMARY KEY REFERENCES organizations (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	daily_budget bigint NOT NULL DEFAULT 0,
+	warning_threshold_percent integer NOT NULL DEFAULT 80,
+	critical_threshold_percent integer NOT NULL DEFAULT 100,
+	alerted_threshold_percent integer NOT NULL DEFAULT 0
+);
+
+COMMENT ON TABLE organization_budget_settings IS 'Budget that the quota consumption of the workspaces of an organization is compared to, to send alerts';
+
+COMMENT ON COLUMN organization_budget_settings.daily_budget IS 'The quota credits the workspaces of the organization consume per day in total before the budget is exceeded. If 0, no alerts are sent.';
+COMMENT ON COLUMN organization_budget_settings.warning_threshold_percent IS 'Percentage of the daily budget at which a warning is sent';
+COMMENT ON COLUMN organization_budget_settings.critical_threshold_percent IS 'Percentage of the daily budget at which a critical alert is sent';
+COMMENT ON COLUMN organization_budget_settings.alerted_threshold_percent IS 'The highest threshold that was crossed when the budget was last evaluated, or 0. Alerts are only sent when a higher threshold is crossed.';
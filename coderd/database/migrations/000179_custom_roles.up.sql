@@ -0,0 +1,13 @@
+CREATE TABLE custom_roles (
+	name text NOT NULL PRIMARY KEY,
+	display_name text NOT NULL,
+	site_permissions jsonb NOT NULL DEFAULT '[]'::jsonb,
+	user_permissions jsonb NOT NULL DEFAULT '[]'::jsonb,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL
+);
+
+COMMENT ON TABLE custom_roles IS 'Site wide roles defined by admins in addition to the built-in roles';
+
+COMMENT ON COLUMN custom_roles.site_permissions IS 'Permissions that apply to all resources of the site';
+COMMENT ON COLUMN custom_roles.user_permissions IS 'Permissions that apply to resources owned by the user with the role';
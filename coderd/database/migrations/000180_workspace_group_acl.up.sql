@@ -0,0 +1,3 @@
+ALTER TABLE workspaces ADD COLUMN group_acl jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN workspaces.group_acl IS 'The groups the workspace is shared with, mapping group IDs to the actions the members of the group may perform. "read" allows using the apps of the workspace, and "create" also allows connecting to the workspace agent, e.g. with SSH.';
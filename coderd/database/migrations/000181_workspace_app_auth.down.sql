@@ -0,0 +1,3 @@
+ALTER TABLE workspace_apps DROP COLUMN auth;
+
+DROP TYPE workspace_app_auth;
@@ -0,0 +1,8 @@
+CREATE TYPE workspace_app_auth AS ENUM (
+    'coder',
+    'oidc'
+);
+
+ALTER TABLE workspace_apps ADD COLUMN auth workspace_app_auth NOT NULL DEFAULT 'coder'::workspace_app_auth;
+
+COMMENT ON COLUMN workspace_apps.auth IS 'How users that are not signed in to Coder authenticate with the app. With "oidc", anyone that logs in with the workspace app OIDC provider may use the subdomain app.';
@@ -0,0 +1,30 @@
+CREATE TABLE workspace_app_access_logs (
+	id BIGSERIAL PRIMARY KEY,
+	created_at timestamptz NOT NULL,
+	user_id uuid REFERENCES users (id),
+	workspace_id uuid NOT NULL REFERENCES workspaces (id),
+	agent_id uuid NOT NULL REFERENCES workspace_agents (id),
+	access_method text NOT NULL,
+	slug_or_port text NOT NULL,
+	path_prefix text NOT NULL,
+	status_code integer NOT NULL,
+	latency_ms bigint NOT NULL
+);
+
+COMMENT ON TABLE workspace_app_access_logs IS 'A record of requests to workspace apps';
+
+COMMENT ON COLUMN workspace_app_access_logs.id IS 'The ID of the record';
+COMMENT ON COLUMN workspace_app_access_logs.created_at IS 'The time the request was received';
+COMMENT ON COLUMN workspace_app_access_logs.user_id IS 'The user who made the request, NULL if they were not signed in to Coder';
+COMMENT ON COLUMN workspace_app_access_logs.workspace_id IS 'The workspace that the workspace app belongs to';
+COMMENT ON COLUMN workspace_app_access_logs.agent_id IS 'The workspace agent that the request was proxied to';
+COMMENT ON COLUMN workspace_app_access_logs.access_method IS 'The method used to access the workspace app';
+COMMENT ON COLUMN workspace_app_access_logs.slug_or_port IS 'The slug or port used to to identify the app';
+COMMENT ON COLUMN workspace_app_access_logs.path_prefix IS 'The first segment of the requested path within the app';
+COMMENT ON COLUMN workspace_app_access_logs.status_code IS 'The HTTP status code of the response';
+COMMENT ON COLUMN workspace_app_access_logs.latency_ms IS 'The time in milliseconds until the response was complete';
+
+-- Access logs are queried by time and joined with workspaces to filter by
+-- template.
+CREATE INDEX workspace_app_access_logs_created_at_idx ON workspace_app_access_logs (created_at);
+CREATE INDEX workspace_app_access_logs_workspace_id_idx ON workspace_app_access_logs (workspace_id);
@@ -0,0 +1,3 @@
+ALTER TABLE workspace_apps
+	DROP COLUMN rate_limit_requests_per_minute,
+	DROP COLUMN rate_limit_burst;
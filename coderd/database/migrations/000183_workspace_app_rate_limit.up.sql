@@ -0,0 +1,6 @@
+ALTER TABLE workspace_apps
+	ADD COLUMN rate_limit_requests_per_minute integer NOT NULL DEFAULT 0,
+	ADD COLUMN rate_limit_burst integer NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN workspace_apps.rate_limit_requests_per_minute IS 'The number of requests per minute each user may make to the app. Zero disables rate limiting.';
+COMMENT ON COLUMN workspace_apps.rate_limit_burst IS 'The number of requests each user may make to the app in a burst. Zero uses rate_limit_requests_per_minute.';
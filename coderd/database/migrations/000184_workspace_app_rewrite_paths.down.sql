@@ -0,0 +1,2 @@
+ALTER TABLE workspace_apps
+	DROP COLUMN rewrite_paths;
@@ -0,0 +1,4 @@
+ALTER TABLE workspace_apps
+	ADD COLUMN rewrite_paths boolean NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN workspace_apps.rewrite_paths IS 'Whether the proxy rewrites redirects and HTML of the path-based app to keep them under the path the app is served from.';
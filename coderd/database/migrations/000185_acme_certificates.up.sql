@@ -0,0 +1,11 @@
+CREATE TABLE acme_certificates (
+	domain text NOT NULL,
+	data bytea NOT NULL,
+	not_after timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (domain)
+);
+
+COMMENT ON TABLE acme_certificates IS 'Certificates issued over ACME for wildcard app domains, shared by all replicas serving the domain';
+COMMENT ON COLUMN acme_certificates.data IS 'The ACME account key, certificate chain and certificate key, encrypted with the key configured on the replicas';
+COMMENT ON COLUMN acme_certificates.not_after IS 'When the certificate expires';
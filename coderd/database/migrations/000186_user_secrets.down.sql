@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS workspace_agent_secrets;
+DROP TABLE IF EXISTS user_secrets;
@@ -0,0 +1,25 @@
+CREATE TABLE user_secrets (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	name text NOT NULL,
+	description text DEFAULT ''::text NOT NULL,
+	value bytea NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE (user_id, name)
+);
+
+COMMENT ON TABLE user_secrets IS 'Secrets of users that are given to the agents of their workspaces as environment variables';
+
+COMMENT ON COLUMN user_secrets.name IS 'The name of the secret, which is also the name of its environment variable';
+
+COMMENT ON COLUMN user_secrets.value IS 'The value of the secret, encrypted with the user secrets encryption key of the deployment';
+
+CREATE TABLE workspace_agent_secrets (
+	workspace_agent_id uuid NOT NULL REFERENCES workspace_agents(id) ON DELETE CASCADE,
+	name text NOT NULL,
+	PRIMARY KEY (workspace_agent_id, name)
+);
+
+COMMENT ON TABLE workspace_agent_secrets IS 'The names of the secrets of the workspace owner the template gives to the agent';
@@ -0,0 +1,39 @@
+BEGIN;
+
+ALTER TYPE workspace_agent_log_source ADD VALUE 'dotfiles';
+
+CREATE TABLE user_dotfiles (
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	repo_url text NOT NULL,
+	branch text DEFAULT ''::text NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (user_id)
+);
+
+COMMENT ON TABLE user_dotfiles IS 'The dotfiles repositories of users that the agents of their workspaces apply when they start';
+
+COMMENT ON COLUMN user_dotfiles.branch IS 'The branch of the repository to check out. If empty, the default branch is used.';
+
+ALTER TABLE templates
+	ADD COLUMN apply_dotfiles boolean NOT NULL DEFAULT true;
+
+COMMENT ON COLUMN templates.apply_dotfiles IS 'Whether the agents of the template apply the dotfiles repository of the workspace owner when they start.';
+
+-- Update the template_with_users view by recreating it.
+DROP VIEW template_with_users;
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
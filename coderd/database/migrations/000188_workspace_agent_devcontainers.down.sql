@@ -0,0 +1,9 @@
+BEGIN;
+
+-- We cannot drop the "devcontainer" value from the workspace_agent_log_source
+-- type, so logs from devcontainers are attributed to the startup script instead.
+UPDATE workspace_agent_logs SET source = 'startup_script' WHERE source = 'devcontainer';
+
+DROP TABLE IF EXISTS workspace_agent_devcontainers;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+ALTER TYPE workspace_agent_log_source ADD VALUE 'devcontainer';
+
+CREATE TABLE workspace_agent_devcontainers (
+	workspace_agent_id uuid NOT NULL REFERENCES workspace_agents(id) ON DELETE CASCADE,
+	workspace_folder text NOT NULL,
+	config_path text DEFAULT ''::text NOT NULL,
+	PRIMARY KEY (workspace_agent_id, workspace_folder)
+);
+
+COMMENT ON TABLE workspace_agent_devcontainers IS 'The repositories with a devcontainer.json the agent applies when it starts';
+
+COMMENT ON COLUMN workspace_agent_devcontainers.workspace_folder IS 'The directory of the repository the devcontainer.json is applied in.';
+
+COMMENT ON COLUMN workspace_agent_devcontainers.config_path IS 'The path of the devcontainer.json relative to the workspace folder. If empty, the default locations are searched.';
+
+COMMIT;
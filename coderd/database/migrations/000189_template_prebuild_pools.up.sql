@@ -0,0 +1,18 @@
+BEGIN;
+
+-- It's not possible to delete enum values, so this value stays after a down
+-- migration.
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'prebuild';
+
+CREATE TABLE template_prebuild_pools (
+	template_id uuid NOT NULL REFERENCES templates(id) ON DELETE CASCADE,
+	size integer NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (template_id)
+);
+
+COMMENT ON TABLE template_prebuild_pools IS 'The number of prebuilt workspaces that are kept provisioned for the active versions of templates';
+
+COMMENT ON COLUMN template_prebuild_pools.size IS 'The number of stopped prebuilt workspaces that are ready to be claimed by users. 0 disables prebuilds for the template.';
+
+COMMIT;
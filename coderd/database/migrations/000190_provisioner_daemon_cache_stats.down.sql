@@ -0,0 +1,6 @@
+ALTER TABLE provisioner_daemons
+	DROP COLUMN cache_entries,
+	DROP COLUMN cache_size_bytes,
+	DROP COLUMN cache_max_size_bytes,
+	DROP COLUMN cache_hits,
+	DROP COLUMN cache_misses;
@@ -0,0 +1,10 @@
+ALTER TABLE provisioner_daemons
+	ADD COLUMN cache_entries integer NOT NULL DEFAULT 0,
+	ADD COLUMN cache_size_bytes bigint NOT NULL DEFAULT 0,
+	ADD COLUMN cache_max_size_bytes bigint NOT NULL DEFAULT 0,
+	ADD COLUMN cache_hits bigint NOT NULL DEFAULT 0,
+	ADD COLUMN cache_misses bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN provisioner_daemons.cache_max_size_bytes IS 'The maximum size of the provider and module cache of the daemon. Zero if the daemon has no cache or has not reported it yet.';
+COMMENT ON COLUMN provisioner_daemons.cache_hits IS 'The number of jobs that restored providers and modules from the cache since the daemon started.';
+COMMENT ON COLUMN provisioner_daemons.cache_misses IS 'The number of jobs that found no cached providers and modules since the daemon started.';
@@ -0,0 +1,39 @@
+BEGIN;
+
+-- Jobs and templates of provisioner plugins can't be converted back, so this
+-- migration fails if any exist.
+
+DROP VIEW template_with_users;
+
+CREATE TYPE provisioner_type AS ENUM (
+	'echo',
+	'terraform'
+);
+
+COMMENT ON COLUMN provisioner_jobs.provisioner IS NULL;
+
+ALTER TABLE provisioner_daemons
+	ALTER COLUMN provisioners TYPE provisioner_type[] USING provisioners::provisioner_type[];
+
+ALTER TABLE provisioner_jobs
+	ALTER COLUMN provisioner TYPE provisioner_type USING provisioner::provisioner_type;
+
+ALTER TABLE templates
+	ALTER COLUMN provisioner TYPE provisioner_type USING provisioner::provisioner_type;
+
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
@@ -0,0 +1,38 @@
+BEGIN;
+
+-- Provisioner types are no longer a fixed set, so that provisioner daemons
+-- can serve provisioner plugins next to Terraform.
+
+-- Delete the template_with_users view to remove the column dependency.
+DROP VIEW template_with_users;
+
+ALTER TABLE provisioner_daemons
+	ALTER COLUMN provisioners TYPE text[] USING provisioners::text[];
+
+ALTER TABLE provisioner_jobs
+	ALTER COLUMN provisioner TYPE text USING provisioner::text;
+
+ALTER TABLE templates
+	ALTER COLUMN provisioner TYPE text USING provisioner::text;
+
+DROP TYPE provisioner_type;
+
+COMMENT ON COLUMN provisioner_jobs.provisioner IS 'The type of provisioner that runs the job: echo, terraform, or the name of a provisioner plugin.';
+
+-- Recreate the template_with_users view.
+CREATE VIEW
+    template_with_users
+AS
+    SELECT
+        templates.*,
+		coalesce(visible_users.avatar_url, '') AS created_by_avatar_url,
+		coalesce(visible_users.username, '') AS created_by_username
+    FROM
+        templates
+    LEFT JOIN
+		visible_users
+	ON
+	    templates.created_by = visible_users.id;
+COMMENT ON VIEW template_with_users IS 'Joins in the username + avatar url of the created by user.';
+
+COMMIT;
@@ -0,0 +1,5 @@
+ALTER TABLE provisioner_job_logs
+	DROP COLUMN progress_stage,
+	DROP COLUMN progress_resource,
+	DROP COLUMN progress_completed,
+	DROP COLUMN progress_total;
@@ -0,0 +1,10 @@
+ALTER TABLE provisioner_job_logs
+	ADD COLUMN progress_stage text NOT NULL DEFAULT '',
+	ADD COLUMN progress_resource text NOT NULL DEFAULT '',
+	ADD COLUMN progress_completed integer NOT NULL DEFAULT 0,
+	ADD COLUMN progress_total integer NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN provisioner_job_logs.progress_stage IS 'The step of provisioning that the log reports progress of: init, plan, or apply. Empty if the log does not report progress.';
+COMMENT ON COLUMN provisioner_job_logs.progress_resource IS 'The address of the resource that the log is about. Empty if the log is not about a resource.';
+COMMENT ON COLUMN provisioner_job_logs.progress_completed IS 'The number of resources that are done in the stage.';
+COMMENT ON COLUMN provisioner_job_logs.progress_total IS 'The number of resources in the stage. Zero if unknown.';
@@ -0,0 +1,3 @@
+BEGIN;
+ALTER TYPE build_reason ADD VALUE IF NOT EXISTS 'resume';
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+CREATE TABLE provisioner_keys (
+	id uuid NOT NULL PRIMARY KEY,
+	created_at timestamptz NOT NULL,
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	name varchar(64) NOT NULL,
+	hashed_secret bytea NOT NULL,
+	tags jsonb NOT NULL DEFAULT '{}',
+	last_used_at timestamptz
+);
+
+CREATE UNIQUE INDEX provisioner_keys_organization_id_name_idx ON provisioner_keys USING btree (organization_id, lower((name)::text));
+
+COMMENT ON TABLE provisioner_keys IS 'Keys that external provisioner daemons authenticate with';
+
+COMMENT ON COLUMN provisioner_keys.hashed_secret IS 'The SHA256 hash of the secret of the key';
+
+COMMENT ON COLUMN provisioner_keys.tags IS 'The tags of the provisioner daemons that authenticate with the key';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS organization_template_variables;
+
+COMMIT;
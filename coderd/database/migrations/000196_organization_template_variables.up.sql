@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE organization_template_variables (
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	name text NOT NULL,
+	value text NOT NULL,
+	sensitive boolean NOT NULL DEFAULT false,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	PRIMARY KEY (organization_id, name)
+);
+
+COMMENT ON TABLE organization_template_variables IS 'Values of Terraform-managed template variables for the templates of an organization';
+
+COMMENT ON COLUMN organization_template_variables.value IS 'Used for template variables of the same name that a template version sets no value for, instead of their default value';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS template_parameter_validations;
+
+COMMIT;
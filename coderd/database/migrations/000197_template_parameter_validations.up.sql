@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE template_parameter_validations (
+	template_id uuid NOT NULL REFERENCES templates (id) ON DELETE CASCADE,
+	webhook_url text NOT NULL DEFAULT '',
+	constraints jsonb NOT NULL DEFAULT '[]'::jsonb,
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	PRIMARY KEY (template_id)
+);
+
+COMMENT ON TABLE template_parameter_validations IS 'Validation rules that are evaluated for the rich parameter values of workspace builds before they are queued';
+
+COMMENT ON COLUMN template_parameter_validations.webhook_url IS 'Receives the parameter values of builds and responds with validation errors. Empty if no webhook is called.';
+
+COMMENT ON COLUMN template_parameter_validations.constraints IS 'Constraints between the values of different parameters';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS template_version_presets;
+
+COMMIT;
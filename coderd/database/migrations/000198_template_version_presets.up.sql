@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE template_version_presets (
+	template_version_id uuid NOT NULL REFERENCES template_versions (id) ON DELETE CASCADE,
+	name text NOT NULL,
+	description text NOT NULL DEFAULT '',
+	parameters jsonb NOT NULL DEFAULT '[]'::jsonb,
+	created_at timestamptz NOT NULL,
+	PRIMARY KEY (template_version_id, name)
+);
+
+COMMENT ON TABLE template_version_presets IS 'Named sets of rich parameter values that users can select when creating workspaces';
+
+COMMENT ON COLUMN template_version_presets.parameters IS 'The names and values of the parameters set by the preset';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS workspace_name_redirects;
+
+COMMIT;
@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE TABLE workspace_name_redirects (
+	owner_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	name text NOT NULL,
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	created_at timestamptz NOT NULL,
+	expires_at timestamptz NOT NULL,
+	PRIMARY KEY (owner_id, name)
+);
+
+COMMENT ON TABLE workspace_name_redirects IS 'Previous names of renamed workspaces. Subdomain app URLs with a previous name redirect to the workspace until the redirect expires.';
+
+COMMIT;
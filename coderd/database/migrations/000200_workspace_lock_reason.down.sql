@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE workspaces DROP COLUMN lock_reason;
+
+DROP TYPE workspace_lock_reason;
+
+COMMIT;
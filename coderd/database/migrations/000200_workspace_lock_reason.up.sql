@@ -0,0 +1,40 @@
+BEGIN;
+
+CREATE TYPE workspace_lock_reason AS ENUM (
+	'manual',
+	'inactivity',
+	'max_lifetime'
+);
+
+COMMENT ON TYPE workspace_lock_reason IS 'Why a workspace was locked: by a user, after it was inactive for the inactivity TTL of its template, or after it reached the max lifetime of its template.';
+
+ALTER TABLE workspaces ADD COLUMN lock_reason workspace_lock_reason;
+
+COMMENT ON COLUMN workspaces.lock_reason IS 'Why the workspace was locked, NULL if it isn''t locked.';
+
+-- Coder locks workspaces when it stops them because they were inactive or
+-- reached their max lifetime, so use the reason of their latest build. Other
+-- locked workspaces are assumed to be locked by a user.
+UPDATE
+	workspaces
+SET
+	lock_reason = CASE latest_build.reason
+		WHEN 'inactivity_ttl' THEN 'inactivity'
+		WHEN 'max_lifetime' THEN 'max_lifetime'
+		ELSE 'manual'
+	END::workspace_lock_reason
+FROM (
+	SELECT DISTINCT ON (workspace_id)
+		workspace_id,
+		reason
+	FROM
+		workspace_builds
+	ORDER BY
+		workspace_id,
+		build_number DESC
+) latest_build
+WHERE
+	workspaces.id = latest_build.workspace_id
+	AND workspaces.locked_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE saved_workspace_filters (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	name text NOT NULL,
+	query text NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE (user_id, name)
+);
+
+COMMENT ON TABLE saved_workspace_filters IS 'Workspace search queries saved by users under a name';
+
+COMMENT ON COLUMN saved_workspace_filters.query IS 'The workspace search query, in the same format as the q parameter of the workspaces endpoint';
+
+COMMIT;
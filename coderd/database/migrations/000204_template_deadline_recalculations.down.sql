@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS template_deadline_recalculations;
+
+DROP TYPE IF EXISTS template_deadline_recalculation_status;
+
+COMMIT;
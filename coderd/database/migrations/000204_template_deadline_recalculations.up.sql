@@ -0,0 +1,31 @@
+BEGIN;
+
+CREATE TYPE template_deadline_recalculation_status AS ENUM (
+	'pending',
+	'running',
+	'succeeded',
+	'failed'
+);
+
+CREATE TABLE template_deadline_recalculations (
+	id uuid NOT NULL PRIMARY KEY,
+	template_id uuid NOT NULL REFERENCES templates (id) ON DELETE CASCADE,
+	status template_deadline_recalculation_status NOT NULL DEFAULT 'pending',
+	total_builds integer NOT NULL DEFAULT 0,
+	processed_builds integer NOT NULL DEFAULT 0,
+	error text NOT NULL DEFAULT '',
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	completed_at timestamptz
+);
+
+-- A template can only have one pending recalculation at a time.
+CREATE UNIQUE INDEX template_deadline_recalculations_pending_idx ON template_deadline_recalculations USING btree (template_id) WHERE (status = 'pending');
+
+CREATE INDEX template_deadline_recalculations_template_id_created_at_idx ON template_deadline_recalculations USING btree (template_id, created_at);
+
+COMMENT ON TABLE template_deadline_recalculations IS 'Jobs that recalculate the deadlines of the active workspace builds of templates after their schedule changed';
+
+COMMENT ON COLUMN template_deadline_recalculations.updated_at IS 'Updated while the job is running. Running jobs that have not been updated for a while are picked up by another replica.';
+
+COMMIT;
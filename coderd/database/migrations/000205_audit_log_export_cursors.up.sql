@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE audit_log_export_cursors (
+	sink text NOT NULL PRIMARY KEY,
+	last_time timestamptz NOT NULL,
+	last_id uuid NOT NULL,
+	leased_by uuid NOT NULL,
+	leased_until timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL
+);
+
+COMMENT ON TABLE audit_log_export_cursors IS 'The last audit log exported to each audit export sink';
+
+COMMENT ON COLUMN audit_log_export_cursors.sink IS 'Identifies the sink by its type and URL, so changing the sink starts a new cursor';
+
+COMMENT ON COLUMN audit_log_export_cursors.leased_by IS 'The sink that is delivering the audit logs after the cursor, so other replicas don''t deliver them too';
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP TABLE IF EXISTS workspace_batch_workspaces;
+
+DROP TABLE IF EXISTS workspace_batches;
+
+DROP TYPE IF EXISTS workspace_batch_workspace_status;
+
+DROP TYPE IF EXISTS workspace_batch_status;
+
+COMMIT;
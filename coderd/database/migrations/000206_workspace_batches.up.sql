@@ -0,0 +1,52 @@
+BEGIN;
+
+CREATE TYPE workspace_batch_status AS ENUM (
+	'pending',
+	'running',
+	'completed',
+	'failed'
+);
+
+CREATE TYPE workspace_batch_workspace_status AS ENUM (
+	'pending',
+	'queued',
+	'skipped',
+	'failed'
+);
+
+CREATE TABLE workspace_batches (
+	id uuid NOT NULL PRIMARY KEY,
+	initiator_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	operation text NOT NULL,
+	query text NOT NULL,
+	status workspace_batch_status NOT NULL DEFAULT 'pending',
+	error text NOT NULL DEFAULT '',
+	created_at timestamptz NOT NULL,
+	updated_at timestamptz NOT NULL,
+	completed_at timestamptz
+);
+
+CREATE INDEX workspace_batches_status_idx ON workspace_batches USING btree (status);
+
+COMMENT ON TABLE workspace_batches IS 'Operations on batches of workspaces that are run in the background';
+
+COMMENT ON COLUMN workspace_batches.updated_at IS 'Updated while the batch is running. Running batches that have not been updated for a while are picked up by another replica.';
+
+CREATE TABLE workspace_batch_workspaces (
+	batch_id uuid NOT NULL REFERENCES workspace_batches (id) ON DELETE CASCADE,
+	workspace_id uuid NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+	position integer NOT NULL,
+	status workspace_batch_workspace_status NOT NULL DEFAULT 'pending',
+	build_id uuid,
+	error text NOT NULL DEFAULT '',
+	updated_at timestamptz NOT NULL,
+	PRIMARY KEY (batch_id, workspace_id)
+);
+
+COMMENT ON TABLE workspace_batch_workspaces IS 'The workspaces of a batch and the result of the operation on each of them';
+
+COMMENT ON COLUMN workspace_batch_workspaces.position IS 'The order the workspaces were matched in, which is the order they are processed in';
+
+COMMENT ON COLUMN workspace_batch_workspaces.build_id IS 'The build queued for the workspace, if any';
+
+COMMIT;
@@ -0,0 +1,5 @@
+DELETE FROM acme_certificates WHERE workspace_proxy_id != '00000000-0000-0000-0000-000000000000';
+
+ALTER TABLE acme_certificates DROP COLUMN workspace_proxy_id;
+
+ALTER TABLE acme_certificates ADD PRIMARY KEY (domain);
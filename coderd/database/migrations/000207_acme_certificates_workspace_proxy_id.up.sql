@@ -0,0 +1,10 @@
+-- Certificates were keyed by domain only, so a proxy with the same wildcard
+-- hostname as the primary could overwrite its certificate. Existing
+-- certificates are kept for the primary, proxies issue theirs again.
+ALTER TABLE acme_certificates DROP CONSTRAINT acme_certificates_pkey;
+
+ALTER TABLE acme_certificates ADD COLUMN workspace_proxy_id uuid NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+
+ALTER TABLE acme_certificates ADD PRIMARY KEY (workspace_proxy_id, domain);
+
+COMMENT ON COLUMN acme_certificates.workspace_proxy_id IS 'The workspace proxy whose replicas serve the domain, or the nil UUID for the replicas of the primary';
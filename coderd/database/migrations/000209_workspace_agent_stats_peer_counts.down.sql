@@ -0,0 +1,3 @@
+ALTER TABLE workspace_agent_stats
+	DROP COLUMN peer_count_p2p,
+	DROP COLUMN peer_count_derp;
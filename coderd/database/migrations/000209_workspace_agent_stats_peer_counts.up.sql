@@ -0,0 +1,3 @@
+ALTER TABLE workspace_agent_stats
+	ADD COLUMN peer_count_p2p bigint NOT NULL DEFAULT 0,
+	ADD COLUMN peer_count_derp bigint NOT NULL DEFAULT 0;
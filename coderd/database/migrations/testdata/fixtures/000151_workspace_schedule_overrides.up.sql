@@ -0,0 +1,19 @@
+INSERT INTO public.workspace_schedule_overrides (
+	workspace_id,
+	created_at,
+	updated_at,
+	default_ttl,
+	max_ttl,
+	restart_requirement_days_of_week,
+	restart_requirement_weeks
+)
+VALUES
+	(
+		'3a9a1feb-e89d-457c-9d53-ac751b198ebe',
+		'2023-08-21 10:00:00+00',
+		'2023-08-21 10:00:00+00',
+		28800000000000,
+		0,
+		31,
+		1
+	);
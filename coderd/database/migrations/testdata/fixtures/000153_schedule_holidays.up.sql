@@ -0,0 +1,15 @@
+INSERT INTO public.schedule_holidays (
+	id,
+	created_at,
+	updated_at,
+	date,
+	name
+)
+VALUES
+	(
+		'b1d8f1a4-6a0e-4c5e-9f0c-2f8f1c5d7e21',
+		'2023-08-21 10:00:00+00',
+		'2023-08-21 10:00:00+00',
+		'2023-12-25',
+		'Christmas Day'
+	);
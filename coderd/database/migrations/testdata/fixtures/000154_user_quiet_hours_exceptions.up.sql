@@ -0,0 +1,11 @@
+INSERT INTO public.user_quiet_hours_exceptions (
+	user_id,
+	date,
+	created_at
+)
+VALUES
+	(
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'2023-08-25',
+		'2023-08-21 10:00:00+00'
+	);
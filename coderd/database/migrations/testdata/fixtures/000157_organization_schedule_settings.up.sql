@@ -0,0 +1,19 @@
+INSERT INTO public.organization_schedule_settings (
+	organization_id,
+	created_at,
+	updated_at,
+	default_ttl,
+	restart_requirement_days_of_week,
+	restart_requirement_weeks,
+	default_quiet_hours_schedule
+)
+VALUES
+	(
+		'bb640d07-ca8a-4869-b6bc-ae61ebb2fda1',
+		'2023-08-21 10:00:00+00',
+		'2023-08-21 10:00:00+00',
+		28800000000000,
+		31,
+		1,
+		'CRON_TZ=Europe/Berlin 0 1 * * *'
+	);
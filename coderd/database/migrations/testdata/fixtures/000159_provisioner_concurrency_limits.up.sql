@@ -0,0 +1,13 @@
+INSERT INTO public.organization_provisioner_settings (
+	organization_id,
+	created_at,
+	updated_at,
+	max_concurrent_jobs
+)
+VALUES
+	(
+		'bb640d07-ca8a-4869-b6bc-ae61ebb2fda1',
+		'2023-08-21 10:00:00+00',
+		'2023-08-21 10:00:00+00',
+		10
+	);
@@ -0,0 +1,17 @@
+INSERT INTO public.workspace_deadline_extensions (
+	id,
+	workspace_id,
+	workspace_build_id,
+	user_id,
+	created_at,
+	duration
+)
+VALUES
+	(
+		'5e2b4c3a-7d1f-4a6e-9b8c-0f1e2d3c4b5a',
+		'3a9a1feb-e89d-457c-9d53-ac751b198ebe',
+		'a8c0b8c5-c9a8-4f33-93a4-8142e6858244',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'2023-08-21 10:00:00+00',
+		3600000000000
+	);
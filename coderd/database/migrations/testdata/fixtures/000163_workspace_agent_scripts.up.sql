@@ -0,0 +1,29 @@
+INSERT INTO public.workspace_agent_scripts (
+	workspace_agent_id,
+	name,
+	ordinal,
+	script,
+	run_on_start,
+	run_on_stop,
+	timeout_seconds,
+	continue_on_error,
+	status,
+	exit_code,
+	started_at,
+	ended_at
+)
+VALUES
+	(
+		'7a1ce5f8-8d00-431c-ad1b-97a846512804',
+		'install-deps',
+		0,
+		'npm install',
+		true,
+		false,
+		300,
+		false,
+		'ok',
+		0,
+		'2022-11-02 13:05:44+02',
+		'2022-11-02 13:05:50+02'
+	);
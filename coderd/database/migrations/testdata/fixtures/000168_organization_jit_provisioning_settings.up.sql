@@ -0,0 +1,15 @@
+INSERT INTO public.organization_jit_provisioning_settings (
+	organization_id,
+	created_at,
+	updated_at,
+	template_id,
+	workspace_name
+)
+VALUES
+	(
+		'bb640d07-ca8a-4869-b6bc-ae61ebb2fda1',
+		'2023-08-21 10:00:00+00',
+		'2023-08-21 10:00:00+00',
+		'4cc1f466-f326-477e-8762-9d0c6781fc56',
+		'dev'
+	);
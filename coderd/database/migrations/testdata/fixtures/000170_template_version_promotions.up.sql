@@ -0,0 +1,21 @@
+INSERT INTO public.template_version_promotions (
+	id,
+	template_id,
+	template_version_id,
+	requested_by,
+	created_at,
+	updated_at,
+	status,
+	approved_by
+)
+VALUES
+	(
+		'd1c7e8f2-3b4a-4c5d-8e6f-7a8b9c0d1e2f',
+		'4cc1f466-f326-477e-8762-9d0c6781fc56',
+		'4e681a60-83da-42c2-902e-6535376ebb77',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'2023-08-21 10:00:00+00',
+		'2023-08-21 10:00:00+00',
+		'pending',
+		'{}'
+	);
@@ -0,0 +1,19 @@
+INSERT INTO public.organization_budget_settings (
+	organization_id,
+	created_at,
+	updated_at,
+	daily_budget,
+	warning_threshold_percent,
+	critical_threshold_percent,
+	alerted_threshold_percent
+)
+VALUES
+	(
+		'bb640d07-ca8a-4869-b6bc-ae61ebb2fda1',
+		'2023-08-21 10:00:00+00',
+		'2023-08-21 10:00:00+00',
+		500,
+		80,
+		100,
+		80
+	);
@@ -0,0 +1,17 @@
+INSERT INTO public.custom_roles (
+	name,
+	display_name,
+	site_permissions,
+	user_permissions,
+	created_at,
+	updated_at
+)
+VALUES
+	(
+		'template-scheduler',
+		'Template Scheduler',
+		'[{"negate": false, "resource_type": "template", "action": "read"}, {"negate": false, "resource_type": "template", "action": "update"}]',
+		'[]',
+		'2023-08-21 10:00:00+00',
+		'2023-08-21 10:00:00+00'
+	);
@@ -0,0 +1,37 @@
+INSERT INTO public.workspace_app_access_logs (
+	id,
+	created_at,
+	user_id,
+	workspace_id,
+	agent_id,
+	access_method,
+	slug_or_port,
+	path_prefix,
+	status_code,
+	latency_ms
+)
+VALUES
+	(
+		1,
+		'2023-08-14 13:00:12.843977+00',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'3a9a1feb-e89d-457c-9d53-ac751b198ebe',
+		'7a1ce5f8-8d00-431c-ad1b-97a846512804',
+		'path',
+		'code-server',
+		'/static',
+		200,
+		12
+	),
+	(
+		2,
+		'2023-08-14 13:00:13.323196+00',
+		NULL,
+		'3a9a1feb-e89d-457c-9d53-ac751b198ebe',
+		'7a1ce5f8-8d00-431c-ad1b-97a846512804',
+		'subdomain',
+		'8080',
+		'/',
+		404,
+		3
+	);
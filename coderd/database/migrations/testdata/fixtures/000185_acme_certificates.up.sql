@@ -0,0 +1,13 @@
+INSERT INTO public.acme_certificates (
+	domain,
+	data,
+	not_after,
+	updated_at
+)
+VALUES
+	(
+		'*.apps.coder.com',
+		'\x00',
+		'2023-11-14 13:00:12.843977+00',
+		'2023-08-16 13:00:12.843977+00'
+	);
@@ -0,0 +1,29 @@
+INSERT INTO public.user_secrets (
+	id,
+	user_id,
+	name,
+	description,
+	value,
+	created_at,
+	updated_at
+)
+VALUES
+	(
+		'5b3a3e5c-7d1e-4b9a-9a0f-4d4c2a0f6e11',
+		'0ed9befc-4911-4ccf-a8e2-559bf72daa94',
+		'AWS_SECRET_ACCESS_KEY',
+		'Deploys to staging',
+		'\x00',
+		'2023-08-16 13:00:12.843977+00',
+		'2023-08-16 13:00:12.843977+00'
+	);
+
+INSERT INTO public.workspace_agent_secrets (
+	workspace_agent_id,
+	name
+)
+VALUES
+	(
+		'7a1ce5f8-8d00-431c-ad1b-97a846512804',
+		'AWS_SECRET_ACCESS_KEY'
+	);
@@ -0,0 +1,13 @@
+INSERT INTO public.user_dotfiles (
+	user_id,
+	repo_url,
+	branch,
+	updated_at
+)
+VALUES
+	(
+		'0ed9befc-4911-4ccf-a8e2-559bf72daa94',
+		'https://github.com/coder/dotfiles',
+		'',
+		'2023-08-16 13:00:12.843977+00'
+	);
@@ -0,0 +1,11 @@
+INSERT INTO public.workspace_agent_devcontainers (
+	workspace_agent_id,
+	workspace_folder,
+	config_path
+)
+VALUES
+	(
+		'7a1ce5f8-8d00-431c-ad1b-97a846512804',
+		'/home/coder/coder',
+		''
+	);
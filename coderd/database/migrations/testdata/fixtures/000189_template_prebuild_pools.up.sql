@@ -0,0 +1,11 @@
+INSERT INTO public.template_prebuild_pools (
+	template_id,
+	size,
+	updated_at
+)
+VALUES
+	(
+		'4cc1f466-f326-477e-8762-9d0c6781fc56',
+		2,
+		'2023-08-16 13:00:12.843977+00'
+	);
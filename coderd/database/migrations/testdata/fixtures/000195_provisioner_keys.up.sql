@@ -0,0 +1,19 @@
+INSERT INTO public.provisioner_keys (
+	id,
+	created_at,
+	organization_id,
+	name,
+	hashed_secret,
+	tags,
+	last_used_at
+)
+VALUES
+	(
+		'b90547be-8870-4d68-8184-e8b2242b7c01',
+		'2023-08-22 10:00:00+00',
+		'bb640d07-ca8a-4869-b6bc-ae61ebb2fda1',
+		'default',
+		'\xdeadbeef'::bytea,
+		'{"scope": "organization"}',
+		NULL
+	);
@@ -0,0 +1,17 @@
+INSERT INTO public.organization_template_variables (
+	organization_id,
+	name,
+	value,
+	sensitive,
+	created_at,
+	updated_at
+)
+VALUES
+	(
+		'bb640d07-ca8a-4869-b6bc-ae61ebb2fda1',
+		'vpc_id',
+		'vpc-0123456789abcdef0',
+		false,
+		'2023-08-22 10:00:00+00',
+		'2023-08-22 10:00:00+00'
+	);
@@ -0,0 +1,15 @@
+INSERT INTO public.template_parameter_validations (
+	template_id,
+	webhook_url,
+	constraints,
+	created_at,
+	updated_at
+)
+VALUES
+	(
+		'4cc1f466-f326-477e-8762-9d0c6781fc56',
+		'https://validation.example.com/coder',
+		'[{"parameter": "instance_type", "regex": "^g", "when_parameter": "region", "when_regex": "^us-", "error": "GPU instances are only available in US regions."}]',
+		'2023-08-16 13:00:12.843977+00',
+		'2023-08-16 13:00:12.843977+00'
+	);
@@ -0,0 +1,15 @@
+INSERT INTO public.template_version_presets (
+	template_version_id,
+	name,
+	description,
+	parameters,
+	created_at
+)
+VALUES
+	(
+		'4e681a60-83da-42c2-902e-6535376ebb77',
+		'GPU',
+		'A workspace with a GPU for machine learning',
+		'[{"name": "instance_type", "value": "g5.xlarge"}, {"name": "disk_size", "value": "100"}]',
+		'2023-08-16 13:00:12.843977+00'
+	);
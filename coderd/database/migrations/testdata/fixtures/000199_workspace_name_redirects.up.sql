@@ -0,0 +1,15 @@
+INSERT INTO public.workspace_name_redirects (
+	owner_id,
+	name,
+	workspace_id,
+	created_at,
+	expires_at
+)
+VALUES
+	(
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'my-old-workspace',
+		'3a9a1feb-e89d-457c-9d53-ac751b198ebe',
+		'2023-08-16 13:00:12.843977+00',
+		'2023-08-23 13:00:12.843977+00'
+	);
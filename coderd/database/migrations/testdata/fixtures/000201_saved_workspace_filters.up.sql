@@ -0,0 +1,17 @@
+INSERT INTO public.saved_workspace_filters (
+	id,
+	user_id,
+	name,
+	query,
+	created_at,
+	updated_at
+)
+VALUES
+	(
+		'b1d5f1a2-3c4e-4f6a-8b9c-0d1e2f3a4b5c',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'stale',
+		'outdated:true status:running',
+		'2023-08-16 13:00:12.843977+00',
+		'2023-08-16 13:00:12.843977+00'
+	);
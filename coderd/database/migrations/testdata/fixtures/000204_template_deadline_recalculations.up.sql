@@ -0,0 +1,23 @@
+INSERT INTO public.template_deadline_recalculations (
+	id,
+	template_id,
+	status,
+	total_builds,
+	processed_builds,
+	error,
+	created_at,
+	updated_at,
+	completed_at
+)
+VALUES
+	(
+		'8a9d1f3e-4e5c-4f0b-9a41-2c9c8b4e7d10',
+		'4cc1f466-f326-477e-8762-9d0c6781fc56',
+		'succeeded',
+		2,
+		2,
+		'',
+		'2023-08-16 13:00:12.843977+00',
+		'2023-08-16 13:00:13.843977+00',
+		'2023-08-16 13:00:13.843977+00'
+	);
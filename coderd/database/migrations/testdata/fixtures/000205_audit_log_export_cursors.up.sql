@@ -0,0 +1,17 @@
+INSERT INTO public.audit_log_export_cursors (
+	sink,
+	last_time,
+	last_id,
+	leased_by,
+	leased_until,
+	updated_at
+)
+VALUES
+	(
+		'http https://audit.example.com',
+		'2023-08-16 13:00:12.843977+00',
+		'5f8a1c9e-2b7d-4e3a-9c61-0d4f2a8b7e35',
+		'00000000-0000-0000-0000-000000000000',
+		'2023-08-16 13:00:13.843977+00',
+		'2023-08-16 13:00:13.843977+00'
+	);
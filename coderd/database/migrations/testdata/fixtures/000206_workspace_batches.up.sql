@@ -0,0 +1,43 @@
+INSERT INTO public.workspace_batches (
+	id,
+	initiator_id,
+	operation,
+	query,
+	status,
+	error,
+	created_at,
+	updated_at,
+	completed_at
+)
+VALUES
+	(
+		'6f3c2a1e-8b4d-4c7a-9e2f-1d5b7c9a0e34',
+		'30095c71-380b-457a-8995-97b8ee6e5307',
+		'stop',
+		'owner:me',
+		'completed',
+		'',
+		'2023-08-16 13:00:12.843977+00',
+		'2023-08-16 13:00:13.843977+00',
+		'2023-08-16 13:00:13.843977+00'
+	);
+
+INSERT INTO public.workspace_batch_workspaces (
+	batch_id,
+	workspace_id,
+	position,
+	status,
+	build_id,
+	error,
+	updated_at
+)
+VALUES
+	(
+		'6f3c2a1e-8b4d-4c7a-9e2f-1d5b7c9a0e34',
+		'3a9a1feb-e89d-457c-9d53-ac751b198ebe',
+		1,
+		'skipped',
+		NULL,
+		'',
+		'2023-08-16 13:00:13.843977+00'
+	);
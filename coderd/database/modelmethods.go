@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	"github.com/coder/coder/coderd/rbac"
 )
@@ -57,6 +58,34 @@ func (s WorkspaceAgentStatus) Valid() bool {
 	}
 }
 
+// Provisioner jobs with a higher priority are acquired first, jobs with the
+// same priority are acquired in the order they were created.
+const (
+	// ProvisionerJobPriorityDefault is used for jobs started by users, like
+	// template imports and workspace builds.
+	ProvisionerJobPriorityDefault int32 = 0
+	// ProvisionerJobPriorityBackground is used for workspace builds the server
+	// starts to stop or clean up workspaces, e.g. because of the restart
+	// requirement or failure TTL of the template. Many of these builds may be
+	// started at once, so they must not delay builds started by users.
+	ProvisionerJobPriorityBackground int32 = -10
+)
+
+// ProvisionerJobPriority returns the priority of the provisioner job of a
+// workspace build with the reason.
+func (r BuildReason) ProvisionerJobPriority() int32 {
+	switch r {
+	case BuildReasonAutostop, BuildReasonAutolock, BuildReasonFailedstop, BuildReasonAutodelete,
+		BuildReasonRestartRequirement, BuildReasonFailureTTL, BuildReasonInactivityTTL, BuildReasonLockedTTL,
+		BuildReasonMaxLifetime, BuildReasonPrebuild:
+		return ProvisionerJobPriorityBackground
+	default:
+		// Autostarts are scheduled by users, who expect their workspace to
+		// be running at the time they chose.
+		return ProvisionerJobPriorityDefault
+	}
+}
+
 type AuditableGroup struct {
 	Group
 	Members []GroupMember `json:"members"`
@@ -92,11 +121,31 @@ func (s APIKeyScope) ToRBAC() rbac.ScopeName {
 		return rbac.ScopeAll
 	case APIKeyScopeApplicationConnect:
 		return rbac.ScopeApplicationConnect
+	case APIKeyScopeWorkspaceRead:
+		return rbac.ScopeWorkspaceRead
+	case APIKeyScopeWorkspaceSSH:
+		return rbac.ScopeWorkspaceSSH
+	case APIKeyScopeWorkspaceApps:
+		return rbac.ScopeWorkspaceApps
+	case APIKeyScopeTemplateRead:
+		return rbac.ScopeTemplateRead
 	default:
 		panic("developer error: unknown scope type " + string(s))
 	}
 }
 
+// ReadOnly reports whether API keys with the scope can only be used for API
+// requests that read resources or connect to workspaces, which never use
+// methods that change state.
+func (s APIKeyScope) ReadOnly() bool {
+	switch s {
+	case APIKeyScopeWorkspaceRead, APIKeyScopeWorkspaceSSH, APIKeyScopeWorkspaceApps, APIKeyScopeTemplateRead:
+		return true
+	default:
+		return false
+	}
+}
+
 func (k APIKey) RBACObject() rbac.Object {
 	return rbac.ResourceAPIKey.WithIDString(k.ID).
 		WithOwner(k.UserID.String())
@@ -141,7 +190,8 @@ func (g Group) RBACObject() rbac.Object {
 func (w Workspace) RBACObject() rbac.Object {
 	return rbac.ResourceWorkspace.WithID(w.ID).
 		InOrg(w.OrganizationID).
-		WithOwner(w.OwnerID.String())
+		WithOwner(w.OwnerID.String()).
+		WithGroupACL(w.sharedGroupACL(rbac.ActionRead, rbac.ActionRead))
 }
 
 func (w Workspace) ExecutionRBAC() rbac.Object {
@@ -153,7 +203,8 @@ func (w Workspace) ExecutionRBAC() rbac.Object {
 	return rbac.ResourceWorkspaceExecution.
 		WithID(w.ID).
 		InOrg(w.OrganizationID).
-		WithOwner(w.OwnerID.String())
+		WithOwner(w.OwnerID.String()).
+		WithGroupACL(w.sharedGroupACL(rbac.ActionCreate, rbac.ActionCreate))
 }
 
 func (w Workspace) ApplicationConnectRBAC() rbac.Object {
@@ -165,15 +216,32 @@ func (w Workspace) ApplicationConnectRBAC() rbac.Object {
 	return rbac.ResourceWorkspaceApplicationConnect.
 		WithID(w.ID).
 		InOrg(w.OrganizationID).
-		WithOwner(w.OwnerID.String())
+		WithOwner(w.OwnerID.String()).
+		WithGroupACL(w.sharedGroupACL(rbac.ActionRead, rbac.ActionCreate))
+}
+
+// sharedGroupACL returns the group ACL that allows the action to the groups
+// the workspace is shared with that have the required action in GroupACL.
+// Groups with "read" may see the workspace and use its apps, and groups with
+// "create" may also connect to the workspace agent.
+func (w Workspace) sharedGroupACL(required, action rbac.Action) map[string][]rbac.Action {
+	acl := make(map[string][]rbac.Action)
+	for group, actions := range w.GroupACL {
+		if slices.Contains(actions, required) {
+			acl[group] = []rbac.Action{action}
+		}
+	}
+	return acl
 }
 
 func (w Workspace) WorkspaceBuildRBAC(transition WorkspaceTransition) rbac.Object {
 	// If a workspace is locked it cannot be built.
-	// However we need to allow stopping a workspace by a caller once a workspace
-	// is locked (e.g. for autobuild). Additionally, if a user wants to delete
-	// a locked workspace, they shouldn't have to have it unlocked first.
+	// However we need to allow stopping or archiving a workspace by a caller
+	// once a workspace is locked (e.g. for autobuild). Additionally, if a user
+	// wants to delete a locked workspace, they shouldn't have to have it
+	// unlocked first.
 	if w.LockedAt.Valid && transition != WorkspaceTransitionStop &&
+		transition != WorkspaceTransitionArchive &&
 		transition != WorkspaceTransitionDelete {
 		return w.LockedRBAC()
 	}
@@ -215,6 +283,10 @@ func (p ProvisionerDaemon) RBACObject() rbac.Object {
 	return rbac.ResourceProvisionerDaemon.WithID(p.ID)
 }
 
+func (k ProvisionerKey) RBACObject() rbac.Object {
+	return rbac.ResourceProvisionerDaemon.WithID(k.ID).InOrg(k.OrganizationID)
+}
+
 func (w WorkspaceProxy) RBACObject() rbac.Object {
 	return rbac.ResourceWorkspaceProxy.
 		WithID(w.ID)
@@ -259,6 +331,22 @@ func (u UserLink) RBACObject() rbac.Object {
 	return rbac.ResourceUserData.WithOwner(u.UserID.String()).WithID(u.UserID)
 }
 
+func (d UserDotfile) RBACObject() rbac.Object {
+	return rbac.ResourceUserData.WithOwner(d.UserID.String()).WithID(d.UserID)
+}
+
+func (f SavedWorkspaceFilter) RBACObject() rbac.Object {
+	return rbac.ResourceUserData.WithOwner(f.UserID.String()).WithID(f.UserID)
+}
+
+func (b WorkspaceBatch) RBACObject() rbac.Object {
+	return rbac.ResourceUserData.WithOwner(b.InitiatorID.String()).WithID(b.InitiatorID)
+}
+
+func (s UserSecret) RBACObject() rbac.Object {
+	return rbac.ResourceUserData.WithOwner(s.UserID.String()).WithID(s.UserID)
+}
+
 func (l License) RBACObject() rbac.Object {
 	return rbac.ResourceLicense.WithIDString(strconv.FormatInt(int64(l.ID), 10))
 }
@@ -357,6 +445,8 @@ func ConvertWorkspaceRows(rows []GetWorkspacesRow) []Workspace {
 			LastUsedAt:        r.LastUsedAt,
 			LockedAt:          r.LockedAt,
 			DeletingAt:        r.DeletingAt,
+			GroupACL:          r.GroupACL,
+			LockReason:        r.LockReason,
 		}
 	}
 
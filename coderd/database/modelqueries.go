@@ -85,6 +85,24 @@ func (q *sqlQuerier) GetAuthorizedTemplates(ctx context.Context, arg GetTemplate
 			&i.LockedTTL,
 			&i.RestartRequirementDaysOfWeek,
 			&i.RestartRequirementWeeks,
+			&i.RestartRequirementTimezone,
+			&i.ActivityBumpTTL,
+			&i.MaxConcurrentBuilds,
+			&i.RestartRequirementSpread,
+			&i.MaxDeadlineExtensionsPerDay,
+			&i.MaxDeadlineExtension,
+			&i.RequiredPromotionApprovals,
+			&i.UpdateOnRestart,
+			&i.FailureRetries,
+			&i.FailureRetryBackoff,
+			&i.SchedulePolicyTemplateID,
+			&i.MaxLifetime,
+			&i.ArchiveRetention,
+			&i.UserCPUQuotaMillicores,
+			&i.UserMemoryQuotaBytes,
+			&i.UserDiskQuotaBytes,
+			&i.ApplyDotfiles,
+			&i.DisableAutostop,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 		); err != nil {
@@ -193,7 +211,9 @@ type workspaceQuerier interface {
 // This code is copied from `GetWorkspaces` and adds the authorized filter WHERE
 // clause.
 func (q *sqlQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspacesParams, prepared rbac.PreparedAuthorized) ([]GetWorkspacesRow, error) {
-	authorizedFilter, err := prepared.CompileToSQL(ctx, rbac.ConfigWithoutACL())
+	authorizedFilter, err := prepared.CompileToSQL(ctx, regosql.ConvertConfig{
+		VariableConverter: regosql.WorkspaceConverter(),
+	})
 	if err != nil {
 		return nil, xerrors.Errorf("compile authorized filter: %w", err)
 	}
@@ -218,6 +238,14 @@ func (q *sqlQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspa
 		arg.HasAgent,
 		arg.AgentInactiveDisconnectTimeoutSeconds,
 		arg.LockedAt,
+		arg.LastUsedBefore,
+		arg.LastUsedAfter,
+		arg.LockReason,
+		arg.Outdated,
+		arg.DeadlineBefore,
+		arg.DeadlineAfter,
+		arg.MaxDeadlineBefore,
+		arg.MaxDeadlineAfter,
 		arg.Offset,
 		arg.Limit,
 	)
@@ -242,6 +270,8 @@ func (q *sqlQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspa
 			&i.LastUsedAt,
 			&i.LockedAt,
 			&i.DeletingAt,
+			&i.GroupACL,
+			&i.LockReason,
 			&i.TemplateName,
 			&i.TemplateVersionID,
 			&i.TemplateVersionName,
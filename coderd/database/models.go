@@ -21,6 +21,10 @@ type APIKeyScope string
 const (
 	APIKeyScopeAll                APIKeyScope = "all"
 	APIKeyScopeApplicationConnect APIKeyScope = "application_connect"
+	APIKeyScopeWorkspaceRead      APIKeyScope = "workspace:read"
+	APIKeyScopeWorkspaceSSH       APIKeyScope = "workspace:ssh"
+	APIKeyScopeWorkspaceApps      APIKeyScope = "workspace:apps"
+	APIKeyScopeTemplateRead       APIKeyScope = "template:read"
 )
 
 func (e *APIKeyScope) Scan(src interface{}) error {
@@ -61,7 +65,11 @@ func (ns NullAPIKeyScope) Value() (driver.Value, error) {
 func (e APIKeyScope) Valid() bool {
 	switch e {
 	case APIKeyScopeAll,
-		APIKeyScopeApplicationConnect:
+		APIKeyScopeApplicationConnect,
+		APIKeyScopeWorkspaceRead,
+		APIKeyScopeWorkspaceSSH,
+		APIKeyScopeWorkspaceApps,
+		APIKeyScopeTemplateRead:
 		return true
 	}
 	return false
@@ -71,6 +79,10 @@ func AllAPIKeyScopeValues() []APIKeyScope {
 	return []APIKeyScope{
 		APIKeyScopeAll,
 		APIKeyScopeApplicationConnect,
+		APIKeyScopeWorkspaceRead,
+		APIKeyScopeWorkspaceSSH,
+		APIKeyScopeWorkspaceApps,
+		APIKeyScopeTemplateRead,
 	}
 }
 
@@ -214,12 +226,20 @@ func AllAuditActionValues() []AuditAction {
 type BuildReason string
 
 const (
-	BuildReasonInitiator  BuildReason = "initiator"
-	BuildReasonAutostart  BuildReason = "autostart"
-	BuildReasonAutostop   BuildReason = "autostop"
-	BuildReasonAutolock   BuildReason = "autolock"
-	BuildReasonFailedstop BuildReason = "failedstop"
-	BuildReasonAutodelete BuildReason = "autodelete"
+	BuildReasonInitiator          BuildReason = "initiator"
+	BuildReasonAutostart          BuildReason = "autostart"
+	BuildReasonAutostop           BuildReason = "autostop"
+	BuildReasonAutolock           BuildReason = "autolock"
+	BuildReasonFailedstop         BuildReason = "failedstop"
+	BuildReasonAutodelete         BuildReason = "autodelete"
+	BuildReasonRestartRequirement BuildReason = "restart_requirement"
+	BuildReasonFailureTTL         BuildReason = "failure_ttl"
+	BuildReasonInactivityTTL      BuildReason = "inactivity_ttl"
+	BuildReasonLockedTTL          BuildReason = "locked_ttl"
+	BuildReasonAdminForced        BuildReason = "admin_forced"
+	BuildReasonMaxLifetime        BuildReason = "max_lifetime"
+	BuildReasonPrebuild           BuildReason = "prebuild"
+	BuildReasonResume             BuildReason = "resume"
 )
 
 func (e *BuildReason) Scan(src interface{}) error {
@@ -264,7 +284,15 @@ func (e BuildReason) Valid() bool {
 		BuildReasonAutostop,
 		BuildReasonAutolock,
 		BuildReasonFailedstop,
-		BuildReasonAutodelete:
+		BuildReasonAutodelete,
+		BuildReasonRestartRequirement,
+		BuildReasonFailureTTL,
+		BuildReasonInactivityTTL,
+		BuildReasonLockedTTL,
+		BuildReasonAdminForced,
+		BuildReasonMaxLifetime,
+		BuildReasonPrebuild,
+		BuildReasonResume:
 		return true
 	}
 	return false
@@ -278,6 +306,14 @@ func AllBuildReasonValues() []BuildReason {
 		BuildReasonAutolock,
 		BuildReasonFailedstop,
 		BuildReasonAutodelete,
+		BuildReasonRestartRequirement,
+		BuildReasonFailureTTL,
+		BuildReasonInactivityTTL,
+		BuildReasonLockedTTL,
+		BuildReasonAdminForced,
+		BuildReasonMaxLifetime,
+		BuildReasonPrebuild,
+		BuildReasonResume,
 	}
 }
 
@@ -286,6 +322,7 @@ type GroupSource string
 const (
 	GroupSourceUser GroupSource = "user"
 	GroupSourceOidc GroupSource = "oidc"
+	GroupSourceScim GroupSource = "scim"
 )
 
 func (e *GroupSource) Scan(src interface{}) error {
@@ -326,7 +363,8 @@ func (ns NullGroupSource) Value() (driver.Value, error) {
 func (e GroupSource) Valid() bool {
 	switch e {
 	case GroupSourceUser,
-		GroupSourceOidc:
+		GroupSourceOidc,
+		GroupSourceScim:
 		return true
 	}
 	return false
@@ -336,6 +374,7 @@ func AllGroupSourceValues() []GroupSource {
 	return []GroupSource{
 		GroupSourceUser,
 		GroupSourceOidc,
+		GroupSourceScim,
 	}
 }
 
@@ -886,79 +925,26 @@ func AllProvisionerStorageMethodValues() []ProvisionerStorageMethod {
 	}
 }
 
-type ProvisionerType string
-
-const (
-	ProvisionerTypeEcho      ProvisionerType = "echo"
-	ProvisionerTypeTerraform ProvisionerType = "terraform"
-)
-
-func (e *ProvisionerType) Scan(src interface{}) error {
-	switch s := src.(type) {
-	case []byte:
-		*e = ProvisionerType(s)
-	case string:
-		*e = ProvisionerType(s)
-	default:
-		return fmt.Errorf("unsupported scan type for ProvisionerType: %T", src)
-	}
-	return nil
-}
-
-type NullProvisionerType struct {
-	ProvisionerType ProvisionerType `json:"provisioner_type"`
-	Valid           bool            `json:"valid"` // Valid is true if ProvisionerType is not NULL
-}
-
-// Scan implements the Scanner interface.
-func (ns *NullProvisionerType) Scan(value interface{}) error {
-	if value == nil {
-		ns.ProvisionerType, ns.Valid = "", false
-		return nil
-	}
-	ns.Valid = true
-	return ns.ProvisionerType.Scan(value)
-}
-
-// Value implements the driver Valuer interface.
-func (ns NullProvisionerType) Value() (driver.Value, error) {
-	if !ns.Valid {
-		return nil, nil
-	}
-	return string(ns.ProvisionerType), nil
-}
-
-func (e ProvisionerType) Valid() bool {
-	switch e {
-	case ProvisionerTypeEcho,
-		ProvisionerTypeTerraform:
-		return true
-	}
-	return false
-}
-
-func AllProvisionerTypeValues() []ProvisionerType {
-	return []ProvisionerType{
-		ProvisionerTypeEcho,
-		ProvisionerTypeTerraform,
-	}
-}
-
 type ResourceType string
 
 const (
-	ResourceTypeOrganization    ResourceType = "organization"
-	ResourceTypeTemplate        ResourceType = "template"
-	ResourceTypeTemplateVersion ResourceType = "template_version"
-	ResourceTypeUser            ResourceType = "user"
-	ResourceTypeWorkspace       ResourceType = "workspace"
-	ResourceTypeGitSshKey       ResourceType = "git_ssh_key"
-	ResourceTypeApiKey          ResourceType = "api_key"
-	ResourceTypeGroup           ResourceType = "group"
-	ResourceTypeWorkspaceBuild  ResourceType = "workspace_build"
-	ResourceTypeLicense         ResourceType = "license"
-	ResourceTypeWorkspaceProxy  ResourceType = "workspace_proxy"
-	ResourceTypeConvertLogin    ResourceType = "convert_login"
+	ResourceTypeOrganization                 ResourceType = "organization"
+	ResourceTypeTemplate                     ResourceType = "template"
+	ResourceTypeTemplateVersion              ResourceType = "template_version"
+	ResourceTypeUser                         ResourceType = "user"
+	ResourceTypeWorkspace                    ResourceType = "workspace"
+	ResourceTypeGitSshKey                    ResourceType = "git_ssh_key"
+	ResourceTypeApiKey                       ResourceType = "api_key"
+	ResourceTypeGroup                        ResourceType = "group"
+	ResourceTypeWorkspaceBuild               ResourceType = "workspace_build"
+	ResourceTypeLicense                      ResourceType = "license"
+	ResourceTypeWorkspaceProxy               ResourceType = "workspace_proxy"
+	ResourceTypeConvertLogin                 ResourceType = "convert_login"
+	ResourceTypeTemplateVersionPromotion     ResourceType = "template_version_promotion"
+	ResourceTypeProvisionerKey               ResourceType = "provisioner_key"
+	ResourceTypeTemplateParameterValidation  ResourceType = "template_parameter_validation"
+	ResourceTypeOrganizationTemplateVariable ResourceType = "organization_template_variable"
+	ResourceTypeCustomRole                   ResourceType = "custom_role"
 )
 
 func (e *ResourceType) Scan(src interface{}) error {
@@ -1009,7 +995,12 @@ func (e ResourceType) Valid() bool {
 		ResourceTypeWorkspaceBuild,
 		ResourceTypeLicense,
 		ResourceTypeWorkspaceProxy,
-		ResourceTypeConvertLogin:
+		ResourceTypeConvertLogin,
+		ResourceTypeTemplateVersionPromotion,
+		ResourceTypeProvisionerKey,
+		ResourceTypeTemplateParameterValidation,
+		ResourceTypeOrganizationTemplateVariable,
+		ResourceTypeCustomRole:
 		return true
 	}
 	return false
@@ -1029,6 +1020,11 @@ func AllResourceTypeValues() []ResourceType {
 		ResourceTypeLicense,
 		ResourceTypeWorkspaceProxy,
 		ResourceTypeConvertLogin,
+		ResourceTypeTemplateVersionPromotion,
+		ResourceTypeProvisionerKey,
+		ResourceTypeTemplateParameterValidation,
+		ResourceTypeOrganizationTemplateVariable,
+		ResourceTypeCustomRole,
 	}
 }
 
@@ -1090,6 +1086,131 @@ func AllStartupScriptBehaviorValues() []StartupScriptBehavior {
 	}
 }
 
+type TemplateDeadlineRecalculationStatus string
+
+const (
+	TemplateDeadlineRecalculationStatusPending   TemplateDeadlineRecalculationStatus = "pending"
+	TemplateDeadlineRecalculationStatusRunning   TemplateDeadlineRecalculationStatus = "running"
+	TemplateDeadlineRecalculationStatusSucceeded TemplateDeadlineRecalculationStatus = "succeeded"
+	TemplateDeadlineRecalculationStatusFailed    TemplateDeadlineRecalculationStatus = "failed"
+)
+
+func (e *TemplateDeadlineRecalculationStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TemplateDeadlineRecalculationStatus(s)
+	case string:
+		*e = TemplateDeadlineRecalculationStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TemplateDeadlineRecalculationStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTemplateDeadlineRecalculationStatus struct {
+	TemplateDeadlineRecalculationStatus TemplateDeadlineRecalculationStatus `json:"template_deadline_recalculation_status"`
+	Valid                               bool                                `json:"valid"` // Valid is true if TemplateDeadlineRecalculationStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTemplateDeadlineRecalculationStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TemplateDeadlineRecalculationStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TemplateDeadlineRecalculationStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTemplateDeadlineRecalculationStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TemplateDeadlineRecalculationStatus), nil
+}
+
+func (e TemplateDeadlineRecalculationStatus) Valid() bool {
+	switch e {
+	case TemplateDeadlineRecalculationStatusPending,
+		TemplateDeadlineRecalculationStatusRunning,
+		TemplateDeadlineRecalculationStatusSucceeded,
+		TemplateDeadlineRecalculationStatusFailed:
+		return true
+	}
+	return false
+}
+
+func AllTemplateDeadlineRecalculationStatusValues() []TemplateDeadlineRecalculationStatus {
+	return []TemplateDeadlineRecalculationStatus{
+		TemplateDeadlineRecalculationStatusPending,
+		TemplateDeadlineRecalculationStatusRunning,
+		TemplateDeadlineRecalculationStatusSucceeded,
+		TemplateDeadlineRecalculationStatusFailed,
+	}
+}
+
+type TemplateVersionPromotionStatus string
+
+const (
+	TemplateVersionPromotionStatusPending  TemplateVersionPromotionStatus = "pending"
+	TemplateVersionPromotionStatusPromoted TemplateVersionPromotionStatus = "promoted"
+	TemplateVersionPromotionStatusRejected TemplateVersionPromotionStatus = "rejected"
+)
+
+func (e *TemplateVersionPromotionStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = TemplateVersionPromotionStatus(s)
+	case string:
+		*e = TemplateVersionPromotionStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for TemplateVersionPromotionStatus: %T", src)
+	}
+	return nil
+}
+
+type NullTemplateVersionPromotionStatus struct {
+	TemplateVersionPromotionStatus TemplateVersionPromotionStatus `json:"template_version_promotion_status"`
+	Valid                          bool                           `json:"valid"` // Valid is true if TemplateVersionPromotionStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullTemplateVersionPromotionStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.TemplateVersionPromotionStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.TemplateVersionPromotionStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullTemplateVersionPromotionStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.TemplateVersionPromotionStatus), nil
+}
+
+func (e TemplateVersionPromotionStatus) Valid() bool {
+	switch e {
+	case TemplateVersionPromotionStatusPending,
+		TemplateVersionPromotionStatusPromoted,
+		TemplateVersionPromotionStatusRejected:
+		return true
+	}
+	return false
+}
+
+func AllTemplateVersionPromotionStatusValues() []TemplateVersionPromotionStatus {
+	return []TemplateVersionPromotionStatus{
+		TemplateVersionPromotionStatusPending,
+		TemplateVersionPromotionStatusPromoted,
+		TemplateVersionPromotionStatusRejected,
+	}
+}
+
 // Defines the user status: active, dormant, or suspended.
 type UserStatus string
 
@@ -1240,6 +1361,8 @@ const (
 	WorkspaceAgentLogSourceEnvbox         WorkspaceAgentLogSource = "envbox"
 	WorkspaceAgentLogSourceEnvbuilder     WorkspaceAgentLogSource = "envbuilder"
 	WorkspaceAgentLogSourceExternal       WorkspaceAgentLogSource = "external"
+	WorkspaceAgentLogSourceDotfiles       WorkspaceAgentLogSource = "dotfiles"
+	WorkspaceAgentLogSourceDevcontainer   WorkspaceAgentLogSource = "devcontainer"
 )
 
 func (e *WorkspaceAgentLogSource) Scan(src interface{}) error {
@@ -1284,7 +1407,9 @@ func (e WorkspaceAgentLogSource) Valid() bool {
 		WorkspaceAgentLogSourceKubernetesLogs,
 		WorkspaceAgentLogSourceEnvbox,
 		WorkspaceAgentLogSourceEnvbuilder,
-		WorkspaceAgentLogSourceExternal:
+		WorkspaceAgentLogSourceExternal,
+		WorkspaceAgentLogSourceDotfiles,
+		WorkspaceAgentLogSourceDevcontainer:
 		return true
 	}
 	return false
@@ -1298,6 +1423,78 @@ func AllWorkspaceAgentLogSourceValues() []WorkspaceAgentLogSource {
 		WorkspaceAgentLogSourceEnvbox,
 		WorkspaceAgentLogSourceEnvbuilder,
 		WorkspaceAgentLogSourceExternal,
+		WorkspaceAgentLogSourceDotfiles,
+		WorkspaceAgentLogSourceDevcontainer,
+	}
+}
+
+type WorkspaceAgentScriptStatus string
+
+const (
+	WorkspaceAgentScriptStatusPending  WorkspaceAgentScriptStatus = "pending"
+	WorkspaceAgentScriptStatusRunning  WorkspaceAgentScriptStatus = "running"
+	WorkspaceAgentScriptStatusOk       WorkspaceAgentScriptStatus = "ok"
+	WorkspaceAgentScriptStatusError    WorkspaceAgentScriptStatus = "error"
+	WorkspaceAgentScriptStatusTimedOut WorkspaceAgentScriptStatus = "timed_out"
+	WorkspaceAgentScriptStatusSkipped  WorkspaceAgentScriptStatus = "skipped"
+)
+
+func (e *WorkspaceAgentScriptStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceAgentScriptStatus(s)
+	case string:
+		*e = WorkspaceAgentScriptStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceAgentScriptStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWorkspaceAgentScriptStatus struct {
+	WorkspaceAgentScriptStatus WorkspaceAgentScriptStatus `json:"workspace_agent_script_status"`
+	Valid                      bool                       `json:"valid"` // Valid is true if WorkspaceAgentScriptStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWorkspaceAgentScriptStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WorkspaceAgentScriptStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WorkspaceAgentScriptStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWorkspaceAgentScriptStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WorkspaceAgentScriptStatus), nil
+}
+
+func (e WorkspaceAgentScriptStatus) Valid() bool {
+	switch e {
+	case WorkspaceAgentScriptStatusPending,
+		WorkspaceAgentScriptStatusRunning,
+		WorkspaceAgentScriptStatusOk,
+		WorkspaceAgentScriptStatusError,
+		WorkspaceAgentScriptStatusTimedOut,
+		WorkspaceAgentScriptStatusSkipped:
+		return true
+	}
+	return false
+}
+
+func AllWorkspaceAgentScriptStatusValues() []WorkspaceAgentScriptStatus {
+	return []WorkspaceAgentScriptStatus{
+		WorkspaceAgentScriptStatusPending,
+		WorkspaceAgentScriptStatusRunning,
+		WorkspaceAgentScriptStatusOk,
+		WorkspaceAgentScriptStatusError,
+		WorkspaceAgentScriptStatusTimedOut,
+		WorkspaceAgentScriptStatusSkipped,
 	}
 }
 
@@ -1365,6 +1562,64 @@ func AllWorkspaceAgentSubsystemValues() []WorkspaceAgentSubsystem {
 	}
 }
 
+type WorkspaceAppAuth string
+
+const (
+	WorkspaceAppAuthCoder WorkspaceAppAuth = "coder"
+	WorkspaceAppAuthOidc  WorkspaceAppAuth = "oidc"
+)
+
+func (e *WorkspaceAppAuth) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceAppAuth(s)
+	case string:
+		*e = WorkspaceAppAuth(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceAppAuth: %T", src)
+	}
+	return nil
+}
+
+type NullWorkspaceAppAuth struct {
+	WorkspaceAppAuth WorkspaceAppAuth `json:"workspace_app_auth"`
+	Valid            bool             `json:"valid"` // Valid is true if WorkspaceAppAuth is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWorkspaceAppAuth) Scan(value interface{}) error {
+	if value == nil {
+		ns.WorkspaceAppAuth, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WorkspaceAppAuth.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWorkspaceAppAuth) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WorkspaceAppAuth), nil
+}
+
+func (e WorkspaceAppAuth) Valid() bool {
+	switch e {
+	case WorkspaceAppAuthCoder,
+		WorkspaceAppAuthOidc:
+		return true
+	}
+	return false
+}
+
+func AllWorkspaceAppAuthValues() []WorkspaceAppAuth {
+	return []WorkspaceAppAuth{
+		WorkspaceAppAuthCoder,
+		WorkspaceAppAuthOidc,
+	}
+}
+
 type WorkspaceAppHealth string
 
 const (
@@ -1429,12 +1684,203 @@ func AllWorkspaceAppHealthValues() []WorkspaceAppHealth {
 	}
 }
 
+// Why a workspace was locked: by a user, after it was inactive for the inactivity TTL of its template, or after it reached the max lifetime of its template.
+type WorkspaceBatchStatus string
+
+const (
+	WorkspaceBatchStatusPending   WorkspaceBatchStatus = "pending"
+	WorkspaceBatchStatusRunning   WorkspaceBatchStatus = "running"
+	WorkspaceBatchStatusCompleted WorkspaceBatchStatus = "completed"
+	WorkspaceBatchStatusFailed    WorkspaceBatchStatus = "failed"
+)
+
+func (e *WorkspaceBatchStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceBatchStatus(s)
+	case string:
+		*e = WorkspaceBatchStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceBatchStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWorkspaceBatchStatus struct {
+	WorkspaceBatchStatus WorkspaceBatchStatus `json:"workspace_batch_status"`
+	Valid                bool                 `json:"valid"` // Valid is true if WorkspaceBatchStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWorkspaceBatchStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WorkspaceBatchStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WorkspaceBatchStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWorkspaceBatchStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WorkspaceBatchStatus), nil
+}
+
+func (e WorkspaceBatchStatus) Valid() bool {
+	switch e {
+	case WorkspaceBatchStatusPending,
+		WorkspaceBatchStatusRunning,
+		WorkspaceBatchStatusCompleted,
+		WorkspaceBatchStatusFailed:
+		return true
+	}
+	return false
+}
+
+func AllWorkspaceBatchStatusValues() []WorkspaceBatchStatus {
+	return []WorkspaceBatchStatus{
+		WorkspaceBatchStatusPending,
+		WorkspaceBatchStatusRunning,
+		WorkspaceBatchStatusCompleted,
+		WorkspaceBatchStatusFailed,
+	}
+}
+
+type WorkspaceBatchWorkspaceStatus string
+
+const (
+	WorkspaceBatchWorkspaceStatusPending WorkspaceBatchWorkspaceStatus = "pending"
+	WorkspaceBatchWorkspaceStatusQueued  WorkspaceBatchWorkspaceStatus = "queued"
+	WorkspaceBatchWorkspaceStatusSkipped WorkspaceBatchWorkspaceStatus = "skipped"
+	WorkspaceBatchWorkspaceStatusFailed  WorkspaceBatchWorkspaceStatus = "failed"
+)
+
+func (e *WorkspaceBatchWorkspaceStatus) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceBatchWorkspaceStatus(s)
+	case string:
+		*e = WorkspaceBatchWorkspaceStatus(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceBatchWorkspaceStatus: %T", src)
+	}
+	return nil
+}
+
+type NullWorkspaceBatchWorkspaceStatus struct {
+	WorkspaceBatchWorkspaceStatus WorkspaceBatchWorkspaceStatus `json:"workspace_batch_workspace_status"`
+	Valid                         bool                          `json:"valid"` // Valid is true if WorkspaceBatchWorkspaceStatus is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWorkspaceBatchWorkspaceStatus) Scan(value interface{}) error {
+	if value == nil {
+		ns.WorkspaceBatchWorkspaceStatus, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WorkspaceBatchWorkspaceStatus.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWorkspaceBatchWorkspaceStatus) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WorkspaceBatchWorkspaceStatus), nil
+}
+
+func (e WorkspaceBatchWorkspaceStatus) Valid() bool {
+	switch e {
+	case WorkspaceBatchWorkspaceStatusPending,
+		WorkspaceBatchWorkspaceStatusQueued,
+		WorkspaceBatchWorkspaceStatusSkipped,
+		WorkspaceBatchWorkspaceStatusFailed:
+		return true
+	}
+	return false
+}
+
+func AllWorkspaceBatchWorkspaceStatusValues() []WorkspaceBatchWorkspaceStatus {
+	return []WorkspaceBatchWorkspaceStatus{
+		WorkspaceBatchWorkspaceStatusPending,
+		WorkspaceBatchWorkspaceStatusQueued,
+		WorkspaceBatchWorkspaceStatusSkipped,
+		WorkspaceBatchWorkspaceStatusFailed,
+	}
+}
+
+type WorkspaceLockReason string
+
+const (
+	WorkspaceLockReasonManual      WorkspaceLockReason = "manual"
+	WorkspaceLockReasonInactivity  WorkspaceLockReason = "inactivity"
+	WorkspaceLockReasonMaxLifetime WorkspaceLockReason = "max_lifetime"
+)
+
+func (e *WorkspaceLockReason) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WorkspaceLockReason(s)
+	case string:
+		*e = WorkspaceLockReason(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WorkspaceLockReason: %T", src)
+	}
+	return nil
+}
+
+type NullWorkspaceLockReason struct {
+	WorkspaceLockReason WorkspaceLockReason `json:"workspace_lock_reason"`
+	Valid               bool                `json:"valid"` // Valid is true if WorkspaceLockReason is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullWorkspaceLockReason) Scan(value interface{}) error {
+	if value == nil {
+		ns.WorkspaceLockReason, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.WorkspaceLockReason.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullWorkspaceLockReason) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return string(ns.WorkspaceLockReason), nil
+}
+
+func (e WorkspaceLockReason) Valid() bool {
+	switch e {
+	case WorkspaceLockReasonManual,
+		WorkspaceLockReasonInactivity,
+		WorkspaceLockReasonMaxLifetime:
+		return true
+	}
+	return false
+}
+
+func AllWorkspaceLockReasonValues() []WorkspaceLockReason {
+	return []WorkspaceLockReason{
+		WorkspaceLockReasonManual,
+		WorkspaceLockReasonInactivity,
+		WorkspaceLockReasonMaxLifetime,
+	}
+}
+
 type WorkspaceTransition string
 
 const (
-	WorkspaceTransitionStart  WorkspaceTransition = "start"
-	WorkspaceTransitionStop   WorkspaceTransition = "stop"
-	WorkspaceTransitionDelete WorkspaceTransition = "delete"
+	WorkspaceTransitionStart   WorkspaceTransition = "start"
+	WorkspaceTransitionStop    WorkspaceTransition = "stop"
+	WorkspaceTransitionDelete  WorkspaceTransition = "delete"
+	WorkspaceTransitionArchive WorkspaceTransition = "archive"
 )
 
 func (e *WorkspaceTransition) Scan(src interface{}) error {
@@ -1476,7 +1922,8 @@ func (e WorkspaceTransition) Valid() bool {
 	switch e {
 	case WorkspaceTransitionStart,
 		WorkspaceTransitionStop,
-		WorkspaceTransitionDelete:
+		WorkspaceTransitionDelete,
+		WorkspaceTransitionArchive:
 		return true
 	}
 	return false
@@ -1487,9 +1934,22 @@ func AllWorkspaceTransitionValues() []WorkspaceTransition {
 		WorkspaceTransitionStart,
 		WorkspaceTransitionStop,
 		WorkspaceTransitionDelete,
+		WorkspaceTransitionArchive,
 	}
 }
 
+// Certificates issued over ACME for wildcard app domains, shared by all replicas serving the domain
+type ACMECertificate struct {
+	Domain string `db:"domain" json:"domain"`
+	// The ACME account key, certificate chain and certificate key, encrypted with the key configured on the replicas
+	Data []byte `db:"data" json:"data"`
+	// When the certificate expires
+	NotAfter  time.Time `db:"not_after" json:"not_after"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	// The workspace proxy whose replicas serve the domain, or the nil UUID for the replicas of the primary
+	WorkspaceProxyID uuid.UUID `db:"workspace_proxy_id" json:"workspace_proxy_id"`
+}
+
 type APIKey struct {
 	ID string `db:"id" json:"id"`
 	// hashed_secret contains a SHA256 hash of the key secret. This is considered a secret and MUST NOT be returned from the API as it is used for API key encryption in app proxying code.
@@ -1524,6 +1984,30 @@ type AuditLog struct {
 	ResourceIcon     string          `db:"resource_icon" json:"resource_icon"`
 }
 
+// The last audit log exported to each audit export sink
+type AuditLogExportCursor struct {
+	// Identifies the sink by its type and URL, so changing the sink starts a new cursor
+	Sink     string    `db:"sink" json:"sink"`
+	LastTime time.Time `db:"last_time" json:"last_time"`
+	LastID   uuid.UUID `db:"last_id" json:"last_id"`
+	// The sink that is delivering the audit logs after the cursor, so other replicas don't deliver them too
+	LeasedBy    uuid.UUID `db:"leased_by" json:"leased_by"`
+	LeasedUntil time.Time `db:"leased_until" json:"leased_until"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Site wide roles defined by admins in addition to the built-in roles
+type CustomRole struct {
+	Name        string `db:"name" json:"name"`
+	DisplayName string `db:"display_name" json:"display_name"`
+	// Permissions that apply to all resources of the site
+	SitePermissions json.RawMessage `db:"site_permissions" json:"site_permissions"`
+	// Permissions that apply to resources owned by the user with the role
+	UserPermissions json.RawMessage `db:"user_permissions" json:"user_permissions"`
+	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time       `db:"updated_at" json:"updated_at"`
+}
+
 type File struct {
 	Hash      string    `db:"hash" json:"hash"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
@@ -1585,6 +2069,32 @@ type Organization struct {
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// Budget that the quota consumption of the workspaces of an organization is compared to, to send alerts
+type OrganizationBudgetSettings struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+	// The quota credits the workspaces of the organization consume per day in total before the budget is exceeded. If 0, no alerts are sent.
+	DailyBudget int64 `db:"daily_budget" json:"daily_budget"`
+	// Percentage of the daily budget at which a warning is sent
+	WarningThresholdPercent int32 `db:"warning_threshold_percent" json:"warning_threshold_percent"`
+	// Percentage of the daily budget at which a critical alert is sent
+	CriticalThresholdPercent int32 `db:"critical_threshold_percent" json:"critical_threshold_percent"`
+	// The highest threshold that was crossed when the budget was last evaluated, or 0. Alerts are only sent when a higher threshold is crossed.
+	AlertedThresholdPercent int32 `db:"alerted_threshold_percent" json:"alerted_threshold_percent"`
+}
+
+// Workspaces that are created for members of an organization when they log in for the first time
+type OrganizationJITProvisioningSettings struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+	// The template workspaces are created from. If null, no workspaces are created.
+	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
+	// The name of created workspaces. If empty, the name of the template is used.
+	WorkspaceName string `db:"workspace_name" json:"workspace_name"`
+}
+
 type OrganizationMember struct {
 	UserID         uuid.UUID `db:"user_id" json:"user_id"`
 	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
@@ -1593,6 +2103,41 @@ type OrganizationMember struct {
 	Roles          []string  `db:"roles" json:"roles"`
 }
 
+// Provisioner options for jobs in an organization
+type OrganizationProvisionerSettings struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+	// The maximum number of provisioner jobs of the organization that can run at the same time. If zero, the number of jobs is not limited.
+	MaxConcurrentJobs int32 `db:"max_concurrent_jobs" json:"max_concurrent_jobs"`
+}
+
+// Default scheduling options for templates and users in an organization
+type OrganizationScheduleSettings struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+	// Used for templates in the organization that have no default_ttl set
+	DefaultTTL int64 `db:"default_ttl" json:"default_ttl"`
+	// Used for templates in the organization that have no restart_requirement_days_of_week set
+	RestartRequirementDaysOfWeek int16 `db:"restart_requirement_days_of_week" json:"restart_requirement_days_of_week"`
+	// Used together with restart_requirement_days_of_week
+	RestartRequirementWeeks int64 `db:"restart_requirement_weeks" json:"restart_requirement_weeks"`
+	// Used for members of the organization that have no quiet hours schedule set, instead of the deployment default
+	DefaultQuietHoursSchedule string `db:"default_quiet_hours_schedule" json:"default_quiet_hours_schedule"`
+}
+
+// Values of Terraform-managed template variables for the templates of an organization
+type OrganizationTemplateVariable struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+	// Used for template variables of the same name that a template version sets no value for, instead of their default value
+	Value     string    `db:"value" json:"value"`
+	Sensitive bool      `db:"sensitive" json:"sensitive"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
 type ParameterSchema struct {
 	ID                       uuid.UUID                  `db:"id" json:"id"`
 	CreatedAt                time.Time                  `db:"created_at" json:"created_at"`
@@ -1626,34 +2171,47 @@ type ParameterValue struct {
 }
 
 type ProvisionerDaemon struct {
-	ID           uuid.UUID         `db:"id" json:"id"`
-	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
-	UpdatedAt    sql.NullTime      `db:"updated_at" json:"updated_at"`
-	Name         string            `db:"name" json:"name"`
-	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
-	ReplicaID    uuid.NullUUID     `db:"replica_id" json:"replica_id"`
-	Tags         StringMap         `db:"tags" json:"tags"`
+	ID             uuid.UUID        `db:"id" json:"id"`
+	CreatedAt      time.Time        `db:"created_at" json:"created_at"`
+	UpdatedAt      sql.NullTime     `db:"updated_at" json:"updated_at"`
+	Name           string           `db:"name" json:"name"`
+	Provisioners   ProvisionerTypes `db:"provisioners" json:"provisioners"`
+	ReplicaID      uuid.NullUUID    `db:"replica_id" json:"replica_id"`
+	Tags           StringMap        `db:"tags" json:"tags"`
+	CacheEntries   int32            `db:"cache_entries" json:"cache_entries"`
+	CacheSizeBytes int64            `db:"cache_size_bytes" json:"cache_size_bytes"`
+	// The maximum size of the provider and module cache of the daemon. Zero if the daemon has no cache or has not reported it yet.
+	CacheMaxSizeBytes int64 `db:"cache_max_size_bytes" json:"cache_max_size_bytes"`
+	// The number of jobs that restored providers and modules from the cache since the daemon started.
+	CacheHits int64 `db:"cache_hits" json:"cache_hits"`
+	// The number of jobs that found no cached providers and modules since the daemon started.
+	CacheMisses int64 `db:"cache_misses" json:"cache_misses"`
 }
 
 type ProvisionerJob struct {
-	ID             uuid.UUID                `db:"id" json:"id"`
-	CreatedAt      time.Time                `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time                `db:"updated_at" json:"updated_at"`
-	StartedAt      sql.NullTime             `db:"started_at" json:"started_at"`
-	CanceledAt     sql.NullTime             `db:"canceled_at" json:"canceled_at"`
-	CompletedAt    sql.NullTime             `db:"completed_at" json:"completed_at"`
-	Error          sql.NullString           `db:"error" json:"error"`
-	OrganizationID uuid.UUID                `db:"organization_id" json:"organization_id"`
-	InitiatorID    uuid.UUID                `db:"initiator_id" json:"initiator_id"`
-	Provisioner    ProvisionerType          `db:"provisioner" json:"provisioner"`
-	StorageMethod  ProvisionerStorageMethod `db:"storage_method" json:"storage_method"`
-	Type           ProvisionerJobType       `db:"type" json:"type"`
-	Input          json.RawMessage          `db:"input" json:"input"`
-	WorkerID       uuid.NullUUID            `db:"worker_id" json:"worker_id"`
-	FileID         uuid.UUID                `db:"file_id" json:"file_id"`
-	Tags           StringMap                `db:"tags" json:"tags"`
-	ErrorCode      sql.NullString           `db:"error_code" json:"error_code"`
-	TraceMetadata  pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
+	ID             uuid.UUID      `db:"id" json:"id"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
+	StartedAt      sql.NullTime   `db:"started_at" json:"started_at"`
+	CanceledAt     sql.NullTime   `db:"canceled_at" json:"canceled_at"`
+	CompletedAt    sql.NullTime   `db:"completed_at" json:"completed_at"`
+	Error          sql.NullString `db:"error" json:"error"`
+	OrganizationID uuid.UUID      `db:"organization_id" json:"organization_id"`
+	InitiatorID    uuid.UUID      `db:"initiator_id" json:"initiator_id"`
+	// The type of provisioner that runs the job: echo, terraform, or the name of a provisioner plugin.
+	Provisioner   ProvisionerType          `db:"provisioner" json:"provisioner"`
+	StorageMethod ProvisionerStorageMethod `db:"storage_method" json:"storage_method"`
+	Type          ProvisionerJobType       `db:"type" json:"type"`
+	Input         json.RawMessage          `db:"input" json:"input"`
+	WorkerID      uuid.NullUUID            `db:"worker_id" json:"worker_id"`
+	FileID        uuid.UUID                `db:"file_id" json:"file_id"`
+	Tags          StringMap                `db:"tags" json:"tags"`
+	ErrorCode     sql.NullString           `db:"error_code" json:"error_code"`
+	TraceMetadata pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
+	// Jobs with a higher priority are acquired by provisioners first. Jobs with the same priority are acquired in the order they were created.
+	Priority int32 `db:"priority" json:"priority"`
+	// Stop and delete builds aren't acquired before this time, so the workspace agents of the previous build can drain first. NULL if the job can be acquired right away.
+	NotBefore sql.NullTime `db:"not_before" json:"not_before"`
 }
 
 type ProvisionerJobLog struct {
@@ -1664,6 +2222,27 @@ type ProvisionerJobLog struct {
 	Stage     string    `db:"stage" json:"stage"`
 	Output    string    `db:"output" json:"output"`
 	ID        int64     `db:"id" json:"id"`
+	// The step of provisioning that the log reports progress of: init, plan, or apply. Empty if the log does not report progress.
+	ProgressStage string `db:"progress_stage" json:"progress_stage"`
+	// The address of the resource that the log is about. Empty if the log is not about a resource.
+	ProgressResource string `db:"progress_resource" json:"progress_resource"`
+	// The number of resources that are done in the stage.
+	ProgressCompleted int32 `db:"progress_completed" json:"progress_completed"`
+	// The number of resources in the stage. Zero if unknown.
+	ProgressTotal int32 `db:"progress_total" json:"progress_total"`
+}
+
+// Keys that external provisioner daemons authenticate with
+type ProvisionerKey struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+	// The SHA256 hash of the secret of the key
+	HashedSecret []byte `db:"hashed_secret" json:"hashed_secret"`
+	// The tags of the provisioner daemons that authenticate with the key
+	Tags       StringMap    `db:"tags" json:"tags"`
+	LastUsedAt sql.NullTime `db:"last_used_at" json:"last_used_at"`
 }
 
 type Replica struct {
@@ -1681,6 +2260,28 @@ type Replica struct {
 	Primary         bool         `db:"primary" json:"primary"`
 }
 
+// Workspace search queries saved by users under a name
+type SavedWorkspaceFilter struct {
+	ID     uuid.UUID `db:"id" json:"id"`
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	Name   string    `db:"name" json:"name"`
+	// The workspace search query, in the same format as the q parameter of the workspaces endpoint
+	Query     string    `db:"query" json:"query"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Dates on which template restart requirements are skipped
+type ScheduleHoliday struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	// The calendar date of the holiday, in the timezone the restart requirement is evaluated in
+	Date time.Time `db:"date" json:"date"`
+	// A human readable name for the holiday
+	Name string `db:"name" json:"name"`
+}
+
 type SiteConfig struct {
 	Key   string `db:"key" json:"key"`
 	Value string `db:"value" json:"value"`
@@ -1733,10 +2334,61 @@ type Template struct {
 	LockedTTL                    int64           `db:"locked_ttl" json:"locked_ttl"`
 	RestartRequirementDaysOfWeek int16           `db:"restart_requirement_days_of_week" json:"restart_requirement_days_of_week"`
 	RestartRequirementWeeks      int64           `db:"restart_requirement_weeks" json:"restart_requirement_weeks"`
+	RestartRequirementTimezone   string          `db:"restart_requirement_timezone" json:"restart_requirement_timezone"`
+	ActivityBumpTTL              int64           `db:"activity_bump_ttl" json:"activity_bump_ttl"`
+	MaxConcurrentBuilds          int32           `db:"max_concurrent_builds" json:"max_concurrent_builds"`
+	RestartRequirementSpread     int64           `db:"restart_requirement_spread" json:"restart_requirement_spread"`
+	MaxDeadlineExtensionsPerDay  int32           `db:"max_deadline_extensions_per_day" json:"max_deadline_extensions_per_day"`
+	MaxDeadlineExtension         int64           `db:"max_deadline_extension" json:"max_deadline_extension"`
+	RequiredPromotionApprovals   int32           `db:"required_promotion_approvals" json:"required_promotion_approvals"`
+	UpdateOnRestart              bool            `db:"update_on_restart" json:"update_on_restart"`
+	FailureRetries               int32           `db:"failure_retries" json:"failure_retries"`
+	FailureRetryBackoff          int64           `db:"failure_retry_backoff" json:"failure_retry_backoff"`
+	SchedulePolicyTemplateID     uuid.NullUUID   `db:"schedule_policy_template_id" json:"schedule_policy_template_id"`
+	MaxLifetime                  int64           `db:"max_lifetime" json:"max_lifetime"`
+	ArchiveRetention             int64           `db:"archive_retention" json:"archive_retention"`
+	UserCPUQuotaMillicores       int64           `db:"user_cpu_quota_millicores" json:"user_cpu_quota_millicores"`
+	UserMemoryQuotaBytes         int64           `db:"user_memory_quota_bytes" json:"user_memory_quota_bytes"`
+	UserDiskQuotaBytes           int64           `db:"user_disk_quota_bytes" json:"user_disk_quota_bytes"`
+	ApplyDotfiles                bool            `db:"apply_dotfiles" json:"apply_dotfiles"`
+	DisableAutostop              bool            `db:"disable_autostop" json:"disable_autostop"`
 	CreatedByAvatarURL           sql.NullString  `db:"created_by_avatar_url" json:"created_by_avatar_url"`
 	CreatedByUsername            string          `db:"created_by_username" json:"created_by_username"`
 }
 
+// Jobs that recalculate the deadlines of the active workspace builds of templates after their schedule changed
+type TemplateDeadlineRecalculation struct {
+	ID              uuid.UUID                           `db:"id" json:"id"`
+	TemplateID      uuid.UUID                           `db:"template_id" json:"template_id"`
+	Status          TemplateDeadlineRecalculationStatus `db:"status" json:"status"`
+	TotalBuilds     int32                               `db:"total_builds" json:"total_builds"`
+	ProcessedBuilds int32                               `db:"processed_builds" json:"processed_builds"`
+	Error           string                              `db:"error" json:"error"`
+	CreatedAt       time.Time                           `db:"created_at" json:"created_at"`
+	// Updated while the job is running. Running jobs that have not been updated for a while are picked up by another replica.
+	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`
+	CompletedAt sql.NullTime `db:"completed_at" json:"completed_at"`
+}
+
+// Validation rules that are evaluated for the rich parameter values of workspace builds before they are queued
+type TemplateParameterValidation struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	// Receives the parameter values of builds and responds with validation errors. Empty if no webhook is called.
+	WebhookURL string `db:"webhook_url" json:"webhook_url"`
+	// Constraints between the values of different parameters
+	Constraints json.RawMessage `db:"constraints" json:"constraints"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// The number of prebuilt workspaces that are kept provisioned for the active versions of templates
+type TemplatePrebuildPool struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	// The number of stopped prebuilt workspaces that are ready to be claimed by users. 0 disables prebuilds for the template.
+	Size      int32     `db:"size" json:"size"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
 type TemplateTable struct {
 	ID              uuid.UUID       `db:"id" json:"id"`
 	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
@@ -1769,6 +2421,42 @@ type TemplateTable struct {
 	RestartRequirementDaysOfWeek int16 `db:"restart_requirement_days_of_week" json:"restart_requirement_days_of_week"`
 	// The number of weeks between restarts. 0 or 1 weeks means "every week", 2 week means "every second week", etc. Weeks are counted from January 2, 2023, which is the first Monday of 2023. This is to ensure workspaces are started consistently for all customers on the same n-week cycles.
 	RestartRequirementWeeks int64 `db:"restart_requirement_weeks" json:"restart_requirement_weeks"`
+	// The IANA timezone to use when calculating the restart requirement. If empty, the timezone of the user's quiet hours schedule is used.
+	RestartRequirementTimezone string `db:"restart_requirement_timezone" json:"restart_requirement_timezone"`
+	// The duration that a workspace's deadline is extended to from the time activity is detected. Idle workspaces are stopped once this duration has passed without activity. If zero, the deadline is bumped by the workspace's TTL instead.
+	ActivityBumpTTL int64 `db:"activity_bump_ttl" json:"activity_bump_ttl"`
+	// The maximum number of workspace builds of the template that can run at the same time. If zero, the number of builds is not limited.
+	MaxConcurrentBuilds int32 `db:"max_concurrent_builds" json:"max_concurrent_builds"`
+	// The duration that the max deadlines of workspaces are spread across after the start of the user's quiet hours, so workspaces don't all reach their restart requirement at the same time. If zero, all workspaces reach it at the start of the quiet hours.
+	RestartRequirementSpread int64 `db:"restart_requirement_spread" json:"restart_requirement_spread"`
+	// The maximum number of times that the deadline of a workspace can be extended by its users within 24 hours. If zero, the number of extensions is not limited.
+	MaxDeadlineExtensionsPerDay int32 `db:"max_deadline_extensions_per_day" json:"max_deadline_extensions_per_day"`
+	// The maximum duration that the deadline of a workspace can be extended by at once. If zero, extensions are only limited by the max deadline of the workspace build.
+	MaxDeadlineExtension int64 `db:"max_deadline_extension" json:"max_deadline_extension"`
+	// The number of approvals required to make a template version the active version. If zero, versions are promoted without approvals.
+	RequiredPromotionApprovals int32 `db:"required_promotion_approvals" json:"required_promotion_approvals"`
+	// Whether workspaces are updated to the active template version when they are restarted because of the restart requirement.
+	UpdateOnRestart bool `db:"update_on_restart" json:"update_on_restart"`
+	// The number of times a failed workspace start is retried before the workspace is stopped because of the failure TTL.
+	FailureRetries int32 `db:"failure_retries" json:"failure_retries"`
+	// The duration to wait before the first retry of a failed workspace start. The duration doubles after every retry.
+	FailureRetryBackoff int64 `db:"failure_retry_backoff" json:"failure_retry_backoff"`
+	// The template to inherit the schedule settings from. If NULL, the template uses its own schedule settings.
+	SchedulePolicyTemplateID uuid.NullUUID `db:"schedule_policy_template_id" json:"schedule_policy_template_id"`
+	// The duration after the creation of a workspace after which it is deleted, or locked if the template has a locked TTL, regardless of its activity.
+	MaxLifetime int64 `db:"max_lifetime" json:"max_lifetime"`
+	// The duration workspaces that reached the locked TTL are archived for before they are deleted. Zero deletes them without archiving.
+	ArchiveRetention int64 `db:"archive_retention" json:"archive_retention"`
+	// The CPU, in millicores, that the workspaces of the template owned by a single user can use in total. If zero, CPU usage is not limited.
+	UserCPUQuotaMillicores int64 `db:"user_cpu_quota_millicores" json:"user_cpu_quota_millicores"`
+	// The memory, in bytes, that the workspaces of the template owned by a single user can use in total. If zero, memory usage is not limited.
+	UserMemoryQuotaBytes int64 `db:"user_memory_quota_bytes" json:"user_memory_quota_bytes"`
+	// The disk space, in bytes, that the workspaces of the template owned by a single user can use in total. If zero, disk usage is not limited.
+	UserDiskQuotaBytes int64 `db:"user_disk_quota_bytes" json:"user_disk_quota_bytes"`
+	// Whether the agents of the template apply the dotfiles repository of the workspace owner when they start.
+	ApplyDotfiles bool `db:"apply_dotfiles" json:"apply_dotfiles"`
+	// Whether workspaces of the template never stop automatically, even if the organization has a default autostop.
+	DisableAutostop bool `db:"disable_autostop" json:"disable_autostop"`
 }
 
 // Joins in the username + avatar url of the created by user.
@@ -1824,6 +2512,30 @@ type TemplateVersionParameter struct {
 	Ephemeral bool `db:"ephemeral" json:"ephemeral"`
 }
 
+// Named sets of rich parameter values that users can select when creating workspaces
+type TemplateVersionPreset struct {
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	Name              string    `db:"name" json:"name"`
+	Description       string    `db:"description" json:"description"`
+	// The names and values of the parameters set by the preset
+	Parameters json.RawMessage `db:"parameters" json:"parameters"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+// Requests to make a template version the active version of its template
+type TemplateVersionPromotion struct {
+	ID                uuid.UUID `db:"id" json:"id"`
+	TemplateID        uuid.UUID `db:"template_id" json:"template_id"`
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	// The user that requested the promotion
+	RequestedBy uuid.UUID                      `db:"requested_by" json:"requested_by"`
+	CreatedAt   time.Time                      `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time                      `db:"updated_at" json:"updated_at"`
+	Status      TemplateVersionPromotionStatus `db:"status" json:"status"`
+	// The users that approved the promotion
+	ApprovedBy []uuid.UUID `db:"approved_by" json:"approved_by"`
+}
+
 type TemplateVersionTable struct {
 	ID             uuid.UUID     `db:"id" json:"id"`
 	TemplateID     uuid.NullUUID `db:"template_id" json:"template_id"`
@@ -1875,6 +2587,15 @@ type User struct {
 	QuietHoursSchedule string `db:"quiet_hours_schedule" json:"quiet_hours_schedule"`
 }
 
+// The dotfiles repositories of users that the agents of their workspaces apply when they start
+type UserDotfile struct {
+	UserID  uuid.UUID `db:"user_id" json:"user_id"`
+	RepoURL string    `db:"repo_url" json:"repo_url"`
+	// The branch of the repository to check out. If empty, the default branch is used.
+	Branch    string    `db:"branch" json:"branch"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
 type UserLink struct {
 	UserID            uuid.UUID `db:"user_id" json:"user_id"`
 	LoginType         LoginType `db:"login_type" json:"login_type"`
@@ -1884,6 +2605,27 @@ type UserLink struct {
 	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
 }
 
+// Dates on which the quiet hours window of a user is skipped
+type UserQuietHoursException struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	// The calendar date of the skipped quiet hours window, in the timezone of the quiet hours schedule
+	Date      time.Time `db:"date" json:"date"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Secrets of users that are given to the agents of their workspaces as environment variables
+type UserSecret struct {
+	ID     uuid.UUID `db:"id" json:"id"`
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	// The name of the secret, which is also the name of its environment variable
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+	// The value of the secret, encrypted with the user secrets encryption key of the deployment
+	Value     []byte    `db:"value" json:"value"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
 // Visible fields of users are allowed to be joined with other tables for including context of other resources.
 type VisibleUser struct {
 	ID        uuid.UUID      `db:"id" json:"id"`
@@ -1905,6 +2647,10 @@ type Workspace struct {
 	LastUsedAt        time.Time      `db:"last_used_at" json:"last_used_at"`
 	LockedAt          sql.NullTime   `db:"locked_at" json:"locked_at"`
 	DeletingAt        sql.NullTime   `db:"deleting_at" json:"deleting_at"`
+	// The groups the workspace is shared with, mapping group IDs to the actions the members of the group may perform. "read" allows using the apps of the workspace, and "create" also allows connecting to the workspace agent, e.g. with SSH.
+	GroupACL WorkspaceACL `db:"group_acl" json:"group_acl"`
+	// Why the workspace was locked, NULL if it isn't locked.
+	LockReason NullWorkspaceLockReason `db:"lock_reason" json:"lock_reason"`
 }
 
 type WorkspaceAgent struct {
@@ -1957,6 +2703,15 @@ type WorkspaceAgent struct {
 	Subsystems []WorkspaceAgentSubsystem `db:"subsystems" json:"subsystems"`
 }
 
+// The repositories with a devcontainer.json the agent applies when it starts
+type WorkspaceAgentDevcontainer struct {
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	// The directory of the repository the devcontainer.json is applied in.
+	WorkspaceFolder string `db:"workspace_folder" json:"workspace_folder"`
+	// The path of the devcontainer.json relative to the workspace folder. If empty, the default locations are searched.
+	ConfigPath string `db:"config_path" json:"config_path"`
+}
+
 type WorkspaceAgentLog struct {
 	AgentID   uuid.UUID               `db:"agent_id" json:"agent_id"`
 	CreatedAt time.Time               `db:"created_at" json:"created_at"`
@@ -1976,6 +2731,33 @@ type WorkspaceAgentMetadatum struct {
 	Timeout          int64     `db:"timeout" json:"timeout"`
 	Interval         int64     `db:"interval" json:"interval"`
 	CollectedAt      time.Time `db:"collected_at" json:"collected_at"`
+	// Whether the metadata was registered at runtime by a metadata plugin of the agent instead of being defined in the template.
+	Plugin bool `db:"plugin" json:"plugin"`
+}
+
+type WorkspaceAgentScript struct {
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	Name             string    `db:"name" json:"name"`
+	// The position of the script in the list of scripts of the agent. Scripts run in this order.
+	Ordinal    int32  `db:"ordinal" json:"ordinal"`
+	Script     string `db:"script" json:"script"`
+	RunOnStart bool   `db:"run_on_start" json:"run_on_start"`
+	RunOnStop  bool   `db:"run_on_stop" json:"run_on_stop"`
+	// The number of seconds the script may run before it is killed. Zero means no timeout.
+	TimeoutSeconds int32 `db:"timeout_seconds" json:"timeout_seconds"`
+	// Whether the following scripts still run if this script fails or times out.
+	ContinueOnError bool `db:"continue_on_error" json:"continue_on_error"`
+	// The status of the last run of the script.
+	Status    WorkspaceAgentScriptStatus `db:"status" json:"status"`
+	ExitCode  int32                      `db:"exit_code" json:"exit_code"`
+	StartedAt sql.NullTime               `db:"started_at" json:"started_at"`
+	EndedAt   sql.NullTime               `db:"ended_at" json:"ended_at"`
+}
+
+// The names of the secrets of the workspace owner the template gives to the agent
+type WorkspaceAgentSecret struct {
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	Name             string    `db:"name" json:"name"`
 }
 
 type WorkspaceAgentStat struct {
@@ -1996,6 +2778,8 @@ type WorkspaceAgentStat struct {
 	SessionCountJetBrains       int64           `db:"session_count_jetbrains" json:"session_count_jetbrains"`
 	SessionCountReconnectingPTY int64           `db:"session_count_reconnecting_pty" json:"session_count_reconnecting_pty"`
 	SessionCountSSH             int64           `db:"session_count_ssh" json:"session_count_ssh"`
+	PeerCountP2P                int64           `db:"peer_count_p2p" json:"peer_count_p2p"`
+	PeerCountDERP               int64           `db:"peer_count_derp" json:"peer_count_derp"`
 }
 
 type WorkspaceApp struct {
@@ -2014,6 +2798,38 @@ type WorkspaceApp struct {
 	SharingLevel         AppSharingLevel    `db:"sharing_level" json:"sharing_level"`
 	Slug                 string             `db:"slug" json:"slug"`
 	External             bool               `db:"external" json:"external"`
+	// How users that are not signed in to Coder authenticate with the app. With "oidc", anyone that logs in with the workspace app OIDC provider may use the subdomain app.
+	Auth WorkspaceAppAuth `db:"auth" json:"auth"`
+	// The number of requests per minute each user may make to the app. Zero disables rate limiting.
+	RateLimitRequestsPerMinute int32 `db:"rate_limit_requests_per_minute" json:"rate_limit_requests_per_minute"`
+	// The number of requests each user may make to the app in a burst. Zero uses rate_limit_requests_per_minute.
+	RateLimitBurst int32 `db:"rate_limit_burst" json:"rate_limit_burst"`
+	// Whether the proxy rewrites redirects and HTML of the path-based app to keep them under the path the app is served from.
+	RewritePaths bool `db:"rewrite_paths" json:"rewrite_paths"`
+}
+
+// A record of requests to workspace apps
+type WorkspaceAppAccessLog struct {
+	// The ID of the record
+	ID int64 `db:"id" json:"id"`
+	// The time the request was received
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// The user who made the request, NULL if they were not signed in to Coder
+	UserID uuid.NullUUID `db:"user_id" json:"user_id"`
+	// The workspace that the workspace app belongs to
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	// The workspace agent that the request was proxied to
+	AgentID uuid.UUID `db:"agent_id" json:"agent_id"`
+	// The method used to access the workspace app
+	AccessMethod string `db:"access_method" json:"access_method"`
+	// The slug or port used to to identify the app
+	SlugOrPort string `db:"slug_or_port" json:"slug_or_port"`
+	// The first segment of the requested path within the app
+	PathPrefix string `db:"path_prefix" json:"path_prefix"`
+	// The HTTP status code of the response
+	StatusCode int32 `db:"status_code" json:"status_code"`
+	// The time in milliseconds until the response was complete
+	LatencyMS int64 `db:"latency_ms" json:"latency_ms"`
 }
 
 // A record of workspace app usage statistics
@@ -2038,6 +2854,35 @@ type WorkspaceAppStat struct {
 	SessionEndedAt time.Time `db:"session_ended_at" json:"session_ended_at"`
 	// The number of requests made during the session, a number larger than 1 indicates that multiple sessions were rolled up into one
 	Requests int32 `db:"requests" json:"requests"`
+	// The time in milliseconds that the app was in use during the session, for rolled up sessions this excludes the time between sessions
+	ActiveDurationMS int64 `db:"active_duration_ms" json:"active_duration_ms"`
+}
+
+// Operations on batches of workspaces that are run in the background
+type WorkspaceBatch struct {
+	ID          uuid.UUID            `db:"id" json:"id"`
+	InitiatorID uuid.UUID            `db:"initiator_id" json:"initiator_id"`
+	Operation   string               `db:"operation" json:"operation"`
+	Query       string               `db:"query" json:"query"`
+	Status      WorkspaceBatchStatus `db:"status" json:"status"`
+	Error       string               `db:"error" json:"error"`
+	CreatedAt   time.Time            `db:"created_at" json:"created_at"`
+	// Updated while the batch is running. Running batches that have not been updated for a while are picked up by another replica.
+	UpdatedAt   time.Time    `db:"updated_at" json:"updated_at"`
+	CompletedAt sql.NullTime `db:"completed_at" json:"completed_at"`
+}
+
+// The workspaces of a batch and the result of the operation on each of them
+type WorkspaceBatchWorkspace struct {
+	BatchID     uuid.UUID `db:"batch_id" json:"batch_id"`
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	// The order the workspaces were matched in, which is the order they are processed in
+	Position int32                         `db:"position" json:"position"`
+	Status   WorkspaceBatchWorkspaceStatus `db:"status" json:"status"`
+	// The build queued for the workspace, if any
+	BuildID   uuid.NullUUID `db:"build_id" json:"build_id"`
+	Error     string        `db:"error" json:"error"`
+	UpdatedAt time.Time     `db:"updated_at" json:"updated_at"`
 }
 
 // Joins in the username + avatar url of the initiated by user.
@@ -2085,6 +2930,27 @@ type WorkspaceBuildTable struct {
 	MaxDeadline       time.Time           `db:"max_deadline" json:"max_deadline"`
 }
 
+// Extensions of workspace build deadlines requested by users
+type WorkspaceDeadlineExtension struct {
+	ID               uuid.UUID `db:"id" json:"id"`
+	WorkspaceID      uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	WorkspaceBuildID uuid.UUID `db:"workspace_build_id" json:"workspace_build_id"`
+	// The user that extended the deadline
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// The duration that the deadline was extended by
+	Duration int64 `db:"duration" json:"duration"`
+}
+
+// Previous names of renamed workspaces. Subdomain app URLs with a previous name redirect to the workspace until the redirect expires.
+type WorkspaceNameRedirect struct {
+	OwnerID     uuid.UUID `db:"owner_id" json:"owner_id"`
+	Name        string    `db:"name" json:"name"`
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt   time.Time `db:"expires_at" json:"expires_at"`
+}
+
 type WorkspaceProxy struct {
 	ID          uuid.UUID `db:"id" json:"id"`
 	Name        string    `db:"name" json:"name"`
@@ -2105,6 +2971,11 @@ type WorkspaceProxy struct {
 	DerpEnabled       bool   `db:"derp_enabled" json:"derp_enabled"`
 	// Disables app/terminal proxying for this proxy and only acts as a DERP relay.
 	DerpOnly bool `db:"derp_only" json:"derp_only"`
+	// When the token expires. Tokens created by users never expire, tokens issued by rotation do.
+	TokenExpiresAt sql.NullTime `db:"token_expires_at" json:"token_expires_at"`
+	// Hashed secret of the token that was replaced by the last rotation. It stays valid until previous_token_expires_at, so all replicas of the proxy can switch to the new token.
+	PreviousTokenHashedSecret []byte       `db:"previous_token_hashed_secret" json:"previous_token_hashed_secret"`
+	PreviousTokenExpiresAt    sql.NullTime `db:"previous_token_expires_at" json:"previous_token_expires_at"`
 }
 
 type WorkspaceResource struct {
@@ -2127,3 +2998,19 @@ type WorkspaceResourceMetadatum struct {
 	Sensitive           bool           `db:"sensitive" json:"sensitive"`
 	ID                  int64          `db:"id" json:"id"`
 }
+
+// Per-workspace overrides for the scheduling options of the workspace's template
+type WorkspaceScheduleOverride struct {
+	// The workspace the override applies to
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+	// Overrides the template default_ttl for the workspace
+	DefaultTTL int64 `db:"default_ttl" json:"default_ttl"`
+	// Overrides the template max_ttl for the workspace
+	MaxTTL int64 `db:"max_ttl" json:"max_ttl"`
+	// Overrides the template restart_requirement_days_of_week for the workspace
+	RestartRequirementDaysOfWeek int16 `db:"restart_requirement_days_of_week" json:"restart_requirement_days_of_week"`
+	// Overrides the template restart_requirement_weeks for the workspace
+	RestartRequirementWeeks int64 `db:"restart_requirement_weeks" json:"restart_requirement_weeks"`
+}
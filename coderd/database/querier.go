@@ -18,29 +18,67 @@ type sqlcQuerier interface {
 	// released when the transaction ends.
 	AcquireLock(ctx context.Context, pgAdvisoryXactLock int64) error
 	// Acquires the lock for a single job that isn't started, completed,
-	// canceled, and that matches an array of provisioner types.
+	// canceled, and that matches an array of provisioner types. Jobs of
+	// organizations and templates that are running their maximum number of
+	// concurrent jobs are skipped until one of their jobs completes.
 	//
 	// SKIP LOCKED is used to jump over locked rows. This prevents
 	// multiple provisioners from acquiring the same jobs. See:
 	// https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
 	AcquireProvisionerJob(ctx context.Context, arg AcquireProvisionerJobParams) (ProvisionerJob, error)
+	// Acquires the oldest pending recalculation, or a running one that has not
+	// been updated since stale_before because the replica running it went away.
+	// SKIP LOCKED lets replicas acquire different recalculations concurrently.
+	AcquireTemplateDeadlineRecalculation(ctx context.Context, arg AcquireTemplateDeadlineRecalculationParams) (TemplateDeadlineRecalculation, error)
+	// Acquires the oldest pending batch, or a running one that has not been
+	// updated since stale_before because the replica running it went away.
+	// SKIP LOCKED lets replicas acquire different batches concurrently.
+	AcquireWorkspaceBatch(ctx context.Context, arg AcquireWorkspaceBatchParams) (WorkspaceBatch, error)
+	// Adds an approval to a pending promotion. Returns no rows if the promotion
+	// isn't pending or the user already approved it.
+	ApproveTemplateVersionPromotion(ctx context.Context, arg ApproveTemplateVersionPromotionParams) (TemplateVersionPromotion, error)
+	// Assigns the oldest prebuilt workspace of the template that is ready to a
+	// user. Prebuilt workspaces are ready once they have been stopped
+	// successfully on the active version of their template. Returns no rows if
+	// there is no such workspace.
+	ClaimPrebuiltWorkspace(ctx context.Context, arg ClaimPrebuiltWorkspaceParams) (Workspace, error)
 	CleanTailnetCoordinators(ctx context.Context) error
 	DeleteAPIKeyByID(ctx context.Context, id string) error
 	DeleteAPIKeysByUserID(ctx context.Context, userID uuid.UUID) error
 	DeleteApplicationConnectAPIKeysByUserID(ctx context.Context, userID uuid.UUID) error
+	DeleteAuditLogsByIDs(ctx context.Context, ids []uuid.UUID) error
 	DeleteCoordinator(ctx context.Context, id uuid.UUID) error
+	// Users keep the names of their roles, so the role is removed from all users
+	// before it is deleted. Unknown role names fail authorization.
+	DeleteCustomRole(ctx context.Context, name string) error
+	DeleteExpiredWorkspaceNameRedirects(ctx context.Context) error
 	DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error
 	DeleteGroupByID(ctx context.Context, id uuid.UUID) error
 	DeleteGroupMemberFromGroup(ctx context.Context, arg DeleteGroupMemberFromGroupParams) error
+	// Groups pushed by SCIM are skipped, their members are managed by SCIM.
 	DeleteGroupMembersByOrgAndUser(ctx context.Context, arg DeleteGroupMembersByOrgAndUserParams) error
 	DeleteLicense(ctx context.Context, id int32) (int32, error)
+	DeleteOldTemplateDeadlineRecalculations(ctx context.Context, completedBefore time.Time) error
 	// If an agent hasn't connected in the last 7 days, we purge it's logs.
 	// Logs can take up a lot of space, so it's important we clean up frequently.
 	DeleteOldWorkspaceAgentLogs(ctx context.Context) error
 	DeleteOldWorkspaceAgentStats(ctx context.Context) error
+	// Access logs are kept as long as workspace agent stats.
+	DeleteOldWorkspaceAppAccessLogs(ctx context.Context) error
+	DeleteOldWorkspaceBatches(ctx context.Context, completedBefore time.Time) error
+	DeleteOrganizationTemplateVariable(ctx context.Context, arg DeleteOrganizationTemplateVariableParams) error
+	DeleteProvisionerKey(ctx context.Context, id uuid.UUID) error
 	DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt time.Time) error
+	DeleteSavedWorkspaceFilter(ctx context.Context, arg DeleteSavedWorkspaceFilterParams) error
+	DeleteScheduleHolidayByID(ctx context.Context, id uuid.UUID) error
 	DeleteTailnetAgent(ctx context.Context, arg DeleteTailnetAgentParams) (DeleteTailnetAgentRow, error)
 	DeleteTailnetClient(ctx context.Context, arg DeleteTailnetClientParams) (DeleteTailnetClientRow, error)
+	DeleteUserDotfiles(ctx context.Context, userID uuid.UUID) error
+	DeleteUserQuietHoursException(ctx context.Context, arg DeleteUserQuietHoursExceptionParams) error
+	DeleteUserSecret(ctx context.Context, arg DeleteUserSecretParams) error
+	DeleteWorkspaceAgentPluginMetadata(ctx context.Context, arg DeleteWorkspaceAgentPluginMetadataParams) error
+	DeleteWorkspaceScheduleOverrideByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) error
+	GetACMECertificate(ctx context.Context, arg GetACMECertificateParams) (ACMECertificate, error)
 	GetAPIKeyByID(ctx context.Context, id string) (APIKey, error)
 	// there is no unique constraint on empty token names
 	GetAPIKeyByName(ctx context.Context, arg GetAPIKeyByNameParams) (APIKey, error)
@@ -51,13 +89,35 @@ type sqlcQuerier interface {
 	GetActiveWorkspaceBuildsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]WorkspaceBuild, error)
 	GetAllTailnetAgents(ctx context.Context) ([]TailnetAgent, error)
 	GetAllTailnetClients(ctx context.Context) ([]TailnetClient, error)
+	// GetAppAccessLogInsights returns the number of requests to each workspace app
+	// and their median and 95th percentile latency per user, path prefix and status
+	// code, for requests received within the given timeframe. Requests of users
+	// that were not signed in to Coder have a NULL user_id. The result can be
+	// filtered on template_ids, meaning only requests to workspaces based on those
+	// templates will be included.
+	GetAppAccessLogInsights(ctx context.Context, arg GetAppAccessLogInsightsParams) ([]GetAppAccessLogInsightsRow, error)
 	GetAppSecurityKey(ctx context.Context) (string, error)
+	// GetAppSessionInsights returns the number of sessions and the time spent in
+	// each workspace app per user, for sessions that started within the given
+	// timeframe. The result can be filtered on template_ids, meaning only sessions
+	// in workspaces based on those templates will be included.
+	GetAppSessionInsights(ctx context.Context, arg GetAppSessionInsightsParams) ([]GetAppSessionInsightsRow, error)
+	GetAuditLogExportCursor(ctx context.Context, sink string) (AuditLogExportCursor, error)
+	// GetAuditLogsAfterCursor returns the audit logs after the cursor that were
+	// created before the given time, oldest first. It's used to export audit logs
+	// to an audit export sink.
+	GetAuditLogsAfterCursor(ctx context.Context, arg GetAuditLogsAfterCursorParams) ([]AuditLog, error)
+	// GetAuditLogsCreatedBefore returns the oldest audit logs created before the
+	// given time, oldest first. It's used to archive expired audit logs.
+	GetAuditLogsCreatedBefore(ctx context.Context, arg GetAuditLogsCreatedBeforeParams) ([]AuditLog, error)
 	// GetAuditLogsBefore retrieves `row_limit` number of audit logs before the provided
 	// ID.
 	GetAuditLogsOffset(ctx context.Context, arg GetAuditLogsOffsetParams) ([]GetAuditLogsOffsetRow, error)
 	// This function returns roles for authorization purposes. Implied member roles
 	// are included.
 	GetAuthorizationUserRoles(ctx context.Context, userID uuid.UUID) (GetAuthorizationUserRolesRow, error)
+	GetCustomRoleByName(ctx context.Context, name string) (CustomRole, error)
+	GetCustomRoles(ctx context.Context) ([]CustomRole, error)
 	GetDERPMeshKey(ctx context.Context) (string, error)
 	GetDefaultProxyConfig(ctx context.Context) (GetDefaultProxyConfigRow, error)
 	GetDeploymentDAUs(ctx context.Context, tzOffset int32) ([]GetDeploymentDAUsRow, error)
@@ -72,6 +132,9 @@ type sqlcQuerier interface {
 	GetGitSSHKey(ctx context.Context, userID uuid.UUID) (GitSSHKey, error)
 	GetGroupByID(ctx context.Context, id uuid.UUID) (Group, error)
 	GetGroupByOrgAndName(ctx context.Context, arg GetGroupByOrgAndNameParams) (Group, error)
+	// GetGroupMemberUserIDs returns the IDs of all members of a group, unlike
+	// GetGroupMembers it includes members that aren't active.
+	GetGroupMemberUserIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error)
 	GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]User, error)
 	GetGroupsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]Group, error)
 	GetHungProvisionerJobs(ctx context.Context, updatedAt time.Time) ([]ProvisionerJob, error)
@@ -83,25 +146,63 @@ type sqlcQuerier interface {
 	GetLicenses(ctx context.Context) ([]License, error)
 	GetLogoURL(ctx context.Context) (string, error)
 	GetOAuthSigningKey(ctx context.Context) (string, error)
+	GetOrganizationBudgetSettings(ctx context.Context, organizationID uuid.UUID) (OrganizationBudgetSettings, error)
+	// Returns the budget settings of every organization with a budget together
+	// with the daily cost of the latest builds of the organization's workspaces.
+	GetOrganizationBudgetUsages(ctx context.Context) ([]GetOrganizationBudgetUsagesRow, error)
 	GetOrganizationByID(ctx context.Context, id uuid.UUID) (Organization, error)
 	GetOrganizationByName(ctx context.Context, name string) (Organization, error)
 	GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error)
+	GetOrganizationJITProvisioningSettings(ctx context.Context, organizationID uuid.UUID) (OrganizationJITProvisioningSettings, error)
 	GetOrganizationMemberByUserID(ctx context.Context, arg GetOrganizationMemberByUserIDParams) (OrganizationMember, error)
 	GetOrganizationMembershipsByUserID(ctx context.Context, userID uuid.UUID) ([]OrganizationMember, error)
+	GetOrganizationProvisionerSettings(ctx context.Context, organizationID uuid.UUID) (OrganizationProvisionerSettings, error)
+	GetOrganizationScheduleSettings(ctx context.Context, organizationID uuid.UUID) (OrganizationScheduleSettings, error)
+	GetOrganizationTemplateVariables(ctx context.Context, organizationID uuid.UUID) ([]OrganizationTemplateVariable, error)
 	GetOrganizations(ctx context.Context) ([]Organization, error)
 	GetOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]Organization, error)
 	GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUID) ([]ParameterSchema, error)
+	// Returns the prebuilt workspaces that aren't deleted with the status of
+	// their latest build. Prebuilt workspaces are owned by @owner_id until they
+	// are claimed.
+	GetPrebuiltWorkspaces(ctx context.Context, ownerID uuid.UUID) ([]GetPrebuiltWorkspacesRow, error)
 	GetPreviousTemplateVersion(ctx context.Context, arg GetPreviousTemplateVersionParams) (TemplateVersion, error)
 	GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error)
 	GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (ProvisionerJob, error)
+	// Returns the concurrency limits of the organization and template of a job,
+	// and how many other jobs are running against each of them. A limit of zero
+	// means the job isn't limited.
+	GetProvisionerJobConcurrency(ctx context.Context, id uuid.UUID) (GetProvisionerJobConcurrencyRow, error)
 	GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]ProvisionerJob, error)
 	GetProvisionerJobsByIDsWithQueuePosition(ctx context.Context, ids []uuid.UUID) ([]GetProvisionerJobsByIDsWithQueuePositionRow, error)
 	GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error)
+	GetProvisionerKeyByID(ctx context.Context, id uuid.UUID) (ProvisionerKey, error)
+	GetProvisionerKeyByOrganizationIDAndName(ctx context.Context, arg GetProvisionerKeyByOrganizationIDAndNameParams) (ProvisionerKey, error)
+	GetProvisionerKeysByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]ProvisionerKey, error)
 	GetProvisionerLogsAfterID(ctx context.Context, arg GetProvisionerLogsAfterIDParams) ([]ProvisionerJobLog, error)
 	GetQuotaAllowanceForUser(ctx context.Context, userID uuid.UUID) (int64, error)
 	GetQuotaConsumedForUser(ctx context.Context, ownerID uuid.UUID) (int64, error)
+	// Returns the daily cost of the latest build of each of the user's workspaces,
+	// which add up to the quota consumed by the user.
+	GetQuotaConsumedForUserByWorkspace(ctx context.Context, ownerID uuid.UUID) ([]GetQuotaConsumedForUserByWorkspaceRow, error)
+	// Returns the "cpu", "memory" and "disk" metadata of the resources of the
+	// latest builds of the user's workspaces of a template, which the resource
+	// quotas of the template are enforced against.
+	GetQuotaResourceMetadataForUser(ctx context.Context, arg GetQuotaResourceMetadataForUserParams) ([]WorkspaceResourceMetadatum, error)
 	GetReplicaByID(ctx context.Context, id uuid.UUID) (Replica, error)
 	GetReplicasUpdatedAfter(ctx context.Context, updatedAt time.Time) ([]Replica, error)
+	GetSavedWorkspaceFilterByUserIDAndName(ctx context.Context, arg GetSavedWorkspaceFilterByUserIDAndNameParams) (SavedWorkspaceFilter, error)
+	GetSavedWorkspaceFiltersByUserID(ctx context.Context, userID uuid.UUID) ([]SavedWorkspaceFilter, error)
+	GetScheduleHolidayByID(ctx context.Context, id uuid.UUID) (ScheduleHoliday, error)
+	GetScheduleHolidays(ctx context.Context) ([]ScheduleHoliday, error)
+	// GetScheduleInsights returns how long the workspaces of each template existed,
+	// were running and were stopped by their schedule within the given timeframe,
+	// based on the build history. A build lasts until the next build of the
+	// workspace, workspaces are running after successful start builds and stopped
+	// by their schedule after autostop or restart requirement builds. The result
+	// can be filtered on template_ids, meaning only workspaces based on those
+	// templates will be included.
+	GetScheduleInsights(ctx context.Context, arg GetScheduleInsightsParams) ([]GetScheduleInsightsRow, error)
 	GetServiceBanner(ctx context.Context) (string, error)
 	GetTailnetAgents(ctx context.Context, id uuid.UUID) ([]TailnetAgent, error)
 	GetTailnetClientsForAgent(ctx context.Context, agentID uuid.UUID) ([]TailnetClient, error)
@@ -114,6 +215,8 @@ type sqlcQuerier interface {
 	// that interval will be less than 24 hours. If there is no data for a selected
 	// interval/template, it will be included in the results with 0 active users.
 	GetTemplateDailyInsights(ctx context.Context, arg GetTemplateDailyInsightsParams) ([]GetTemplateDailyInsightsRow, error)
+	GetTemplateDeadlineRecalculationByID(ctx context.Context, id uuid.UUID) (TemplateDeadlineRecalculation, error)
+	GetTemplateDeadlineRecalculationsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]TemplateDeadlineRecalculation, error)
 	// GetTemplateInsights has a granularity of 5 minutes where if a session/app was
 	// in use, we will add 5 minutes to the total usage for that session (per user).
 	GetTemplateInsights(ctx context.Context, arg GetTemplateInsightsParams) (GetTemplateInsightsRow, error)
@@ -122,20 +225,41 @@ type sqlcQuerier interface {
 	// created in the timeframe and return the aggregate usage counts of parameter
 	// values.
 	GetTemplateParameterInsights(ctx context.Context, arg GetTemplateParameterInsightsParams) ([]GetTemplateParameterInsightsRow, error)
+	GetTemplateParameterValidation(ctx context.Context, templateID uuid.UUID) (TemplateParameterValidation, error)
+	GetTemplatePrebuildPool(ctx context.Context, templateID uuid.UUID) (TemplatePrebuildPool, error)
+	// Returns the pools of all templates that aren't deleted and keep at least one
+	// prebuilt workspace.
+	GetTemplatePrebuildPools(ctx context.Context) ([]TemplatePrebuildPool, error)
+	// GetTemplateVersionBuildInsights returns the number of start builds of each
+	// template version and how many of them failed, the median and 95th percentile
+	// time that successful builds took to provision, and the median and 95th
+	// percentile time that their agents took to connect, in intervals of the given
+	// length between start and end time. Builds are counted in the interval they
+	// were created in, canceled and unfinished builds are not counted. Percentiles
+	// are -1 if there is no data. The result can be filtered on template_ids,
+	// meaning only builds of those templates will be included.
+	GetTemplateVersionBuildInsights(ctx context.Context, arg GetTemplateVersionBuildInsightsParams) ([]GetTemplateVersionBuildInsightsRow, error)
 	GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (TemplateVersion, error)
 	GetTemplateVersionByJobID(ctx context.Context, jobID uuid.UUID) (TemplateVersion, error)
 	GetTemplateVersionByTemplateIDAndName(ctx context.Context, arg GetTemplateVersionByTemplateIDAndNameParams) (TemplateVersion, error)
 	GetTemplateVersionParameters(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionParameter, error)
+	GetTemplateVersionPresets(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionPreset, error)
+	GetTemplateVersionPromotionByID(ctx context.Context, id uuid.UUID) (TemplateVersionPromotion, error)
+	GetTemplateVersionPromotionsByTemplateID(ctx context.Context, arg GetTemplateVersionPromotionsByTemplateIDParams) ([]TemplateVersionPromotion, error)
 	GetTemplateVersionVariables(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionVariable, error)
 	GetTemplateVersionsByIDs(ctx context.Context, ids []uuid.UUID) ([]TemplateVersion, error)
 	GetTemplateVersionsByTemplateID(ctx context.Context, arg GetTemplateVersionsByTemplateIDParams) ([]TemplateVersion, error)
 	GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt time.Time) ([]TemplateVersion, error)
 	GetTemplates(ctx context.Context) ([]Template, error)
+	// Returns the templates that inherit their schedule settings from the given
+	// template.
+	GetTemplatesBySchedulePolicyTemplateID(ctx context.Context, schedulePolicyTemplateID uuid.NullUUID) ([]Template, error)
 	GetTemplatesWithFilter(ctx context.Context, arg GetTemplatesWithFilterParams) ([]Template, error)
 	GetUnexpiredLicenses(ctx context.Context) ([]License, error)
 	GetUserByEmailOrUsername(ctx context.Context, arg GetUserByEmailOrUsernameParams) (User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserCount(ctx context.Context) (int64, error)
+	GetUserDotfiles(ctx context.Context, userID uuid.UUID) (UserDotfile, error)
 	// GetUserLatencyInsights returns the median and 95th percentile connection
 	// latency that users have experienced. The result can be filtered on
 	// template_ids, meaning only user data from workspaces based on those templates
@@ -143,6 +267,9 @@ type sqlcQuerier interface {
 	GetUserLatencyInsights(ctx context.Context, arg GetUserLatencyInsightsParams) ([]GetUserLatencyInsightsRow, error)
 	GetUserLinkByLinkedID(ctx context.Context, linkedID string) (UserLink, error)
 	GetUserLinkByUserIDLoginType(ctx context.Context, arg GetUserLinkByUserIDLoginTypeParams) (UserLink, error)
+	GetUserQuietHoursExceptions(ctx context.Context, userID uuid.UUID) ([]UserQuietHoursException, error)
+	GetUserSecretByUserIDAndName(ctx context.Context, arg GetUserSecretByUserIDAndNameParams) (UserSecret, error)
+	GetUserSecretsByUserID(ctx context.Context, userID uuid.UUID) ([]UserSecret, error)
 	// This will never return deleted users.
 	GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUsersRow, error)
 	// This shouldn't check for deleted, because it's frequently used
@@ -152,9 +279,12 @@ type sqlcQuerier interface {
 	GetWorkspaceAgentByAuthToken(ctx context.Context, authToken uuid.UUID) (WorkspaceAgent, error)
 	GetWorkspaceAgentByID(ctx context.Context, id uuid.UUID) (WorkspaceAgent, error)
 	GetWorkspaceAgentByInstanceID(ctx context.Context, authInstanceID string) (WorkspaceAgent, error)
+	GetWorkspaceAgentDevcontainersByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]WorkspaceAgentDevcontainer, error)
 	GetWorkspaceAgentLifecycleStateByID(ctx context.Context, id uuid.UUID) (GetWorkspaceAgentLifecycleStateByIDRow, error)
 	GetWorkspaceAgentLogsAfter(ctx context.Context, arg GetWorkspaceAgentLogsAfterParams) ([]WorkspaceAgentLog, error)
 	GetWorkspaceAgentMetadata(ctx context.Context, workspaceAgentID uuid.UUID) ([]WorkspaceAgentMetadatum, error)
+	GetWorkspaceAgentScriptsByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]WorkspaceAgentScript, error)
+	GetWorkspaceAgentSecretsByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]WorkspaceAgentSecret, error)
 	GetWorkspaceAgentStats(ctx context.Context, createdAt time.Time) ([]GetWorkspaceAgentStatsRow, error)
 	GetWorkspaceAgentStatsAndLabels(ctx context.Context, createdAt time.Time) ([]GetWorkspaceAgentStatsAndLabelsRow, error)
 	GetWorkspaceAgentsByResourceIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceAgent, error)
@@ -164,6 +294,8 @@ type sqlcQuerier interface {
 	GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid.UUID) ([]WorkspaceApp, error)
 	GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceApp, error)
 	GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceApp, error)
+	GetWorkspaceBatchByID(ctx context.Context, id uuid.UUID) (WorkspaceBatch, error)
+	GetWorkspaceBatchWorkspacesByBatchID(ctx context.Context, batchID uuid.UUID) ([]GetWorkspaceBatchWorkspacesByBatchIDRow, error)
 	GetWorkspaceBuildByID(ctx context.Context, id uuid.UUID) (WorkspaceBuild, error)
 	GetWorkspaceBuildByJobID(ctx context.Context, jobID uuid.UUID) (WorkspaceBuild, error)
 	GetWorkspaceBuildByWorkspaceIDAndBuildNumber(ctx context.Context, arg GetWorkspaceBuildByWorkspaceIDAndBuildNumberParams) (WorkspaceBuild, error)
@@ -174,6 +306,10 @@ type sqlcQuerier interface {
 	GetWorkspaceByID(ctx context.Context, id uuid.UUID) (Workspace, error)
 	GetWorkspaceByOwnerIDAndName(ctx context.Context, arg GetWorkspaceByOwnerIDAndNameParams) (Workspace, error)
 	GetWorkspaceByWorkspaceAppID(ctx context.Context, workspaceAppID uuid.UUID) (Workspace, error)
+	// Returns the number of deadline extensions of the workspace since the given
+	// time.
+	GetWorkspaceDeadlineExtensionCount(ctx context.Context, arg GetWorkspaceDeadlineExtensionCountParams) (int64, error)
+	GetWorkspaceNameRedirect(ctx context.Context, arg GetWorkspaceNameRedirectParams) (WorkspaceNameRedirect, error)
 	GetWorkspaceProxies(ctx context.Context) ([]WorkspaceProxy, error)
 	// Finds a workspace proxy that has an access URL or app hostname that matches
 	// the provided hostname. This is to check if a hostname matches any workspace
@@ -191,14 +327,21 @@ type sqlcQuerier interface {
 	GetWorkspaceResourcesByJobID(ctx context.Context, jobID uuid.UUID) ([]WorkspaceResource, error)
 	GetWorkspaceResourcesByJobIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceResource, error)
 	GetWorkspaceResourcesCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceResource, error)
+	GetWorkspaceScheduleOverrideByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (WorkspaceScheduleOverride, error)
 	GetWorkspaces(ctx context.Context, arg GetWorkspacesParams) ([]GetWorkspacesRow, error)
+	GetWorkspacesByIDs(ctx context.Context, ids []uuid.UUID) ([]Workspace, error)
 	GetWorkspacesEligibleForTransition(ctx context.Context, now time.Time) ([]Workspace, error)
+	// Returns started workspaces whose latest build successfully completed and
+	// reaches its deadline after @now and no later than @before, with the latest
+	// build, owner and template details the autostop imminent events include.
+	GetWorkspacesWithImminentAutostop(ctx context.Context, arg GetWorkspacesWithImminentAutostopParams) ([]GetWorkspacesWithImminentAutostopRow, error)
 	InsertAPIKey(ctx context.Context, arg InsertAPIKeyParams) (APIKey, error)
 	// We use the organization_id as the id
 	// for simplicity since all users is
 	// every member of the org.
 	InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (Group, error)
 	InsertAuditLog(ctx context.Context, arg InsertAuditLogParams) (AuditLog, error)
+	InsertCustomRole(ctx context.Context, arg InsertCustomRoleParams) (CustomRole, error)
 	InsertDERPMeshKey(ctx context.Context, value string) error
 	InsertDeploymentID(ctx context.Context, value string) error
 	InsertFile(ctx context.Context, arg InsertFileParams) (File, error)
@@ -217,52 +360,90 @@ type sqlcQuerier interface {
 	InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error)
 	InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error)
 	InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error)
+	InsertProvisionerKey(ctx context.Context, arg InsertProvisionerKeyParams) (ProvisionerKey, error)
 	InsertReplica(ctx context.Context, arg InsertReplicaParams) (Replica, error)
+	InsertSavedWorkspaceFilter(ctx context.Context, arg InsertSavedWorkspaceFilterParams) (SavedWorkspaceFilter, error)
+	InsertScheduleHoliday(ctx context.Context, arg InsertScheduleHolidayParams) (ScheduleHoliday, error)
 	InsertTemplate(ctx context.Context, arg InsertTemplateParams) error
+	// Queues a recalculation of the template. If a recalculation of the template
+	// is already pending, it is returned instead, as it will use the latest
+	// template schedule when it runs.
+	InsertTemplateDeadlineRecalculation(ctx context.Context, arg InsertTemplateDeadlineRecalculationParams) (TemplateDeadlineRecalculation, error)
 	InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) error
 	InsertTemplateVersionParameter(ctx context.Context, arg InsertTemplateVersionParameterParams) (TemplateVersionParameter, error)
+	InsertTemplateVersionPreset(ctx context.Context, arg InsertTemplateVersionPresetParams) (TemplateVersionPreset, error)
+	InsertTemplateVersionPromotion(ctx context.Context, arg InsertTemplateVersionPromotionParams) (TemplateVersionPromotion, error)
 	InsertTemplateVersionVariable(ctx context.Context, arg InsertTemplateVersionVariableParams) (TemplateVersionVariable, error)
 	InsertUser(ctx context.Context, arg InsertUserParams) (User, error)
 	// InsertUserGroupsByName adds a user to all provided groups, if they exist.
+	// Groups pushed by SCIM are skipped, their members are managed by SCIM.
 	InsertUserGroupsByName(ctx context.Context, arg InsertUserGroupsByNameParams) error
 	InsertUserLink(ctx context.Context, arg InsertUserLinkParams) (UserLink, error)
+	InsertUserQuietHoursException(ctx context.Context, arg InsertUserQuietHoursExceptionParams) (UserQuietHoursException, error)
+	InsertUserSecret(ctx context.Context, arg InsertUserSecretParams) (UserSecret, error)
 	InsertWorkspace(ctx context.Context, arg InsertWorkspaceParams) (Workspace, error)
 	InsertWorkspaceAgent(ctx context.Context, arg InsertWorkspaceAgentParams) (WorkspaceAgent, error)
+	InsertWorkspaceAgentDevcontainer(ctx context.Context, arg InsertWorkspaceAgentDevcontainerParams) error
 	InsertWorkspaceAgentLogs(ctx context.Context, arg InsertWorkspaceAgentLogsParams) ([]WorkspaceAgentLog, error)
 	InsertWorkspaceAgentMetadata(ctx context.Context, arg InsertWorkspaceAgentMetadataParams) error
+	InsertWorkspaceAgentScript(ctx context.Context, arg InsertWorkspaceAgentScriptParams) error
+	InsertWorkspaceAgentSecret(ctx context.Context, arg InsertWorkspaceAgentSecretParams) error
 	InsertWorkspaceAgentStat(ctx context.Context, arg InsertWorkspaceAgentStatParams) (WorkspaceAgentStat, error)
 	InsertWorkspaceAgentStats(ctx context.Context, arg InsertWorkspaceAgentStatsParams) error
 	InsertWorkspaceApp(ctx context.Context, arg InsertWorkspaceAppParams) (WorkspaceApp, error)
+	InsertWorkspaceAppAccessLogs(ctx context.Context, arg InsertWorkspaceAppAccessLogsParams) error
 	InsertWorkspaceAppStats(ctx context.Context, arg InsertWorkspaceAppStatsParams) error
+	InsertWorkspaceBatch(ctx context.Context, arg InsertWorkspaceBatchParams) (WorkspaceBatch, error)
+	// Workspaces are processed in the order they are given.
+	InsertWorkspaceBatchWorkspaces(ctx context.Context, arg InsertWorkspaceBatchWorkspacesParams) error
 	InsertWorkspaceBuild(ctx context.Context, arg InsertWorkspaceBuildParams) error
 	InsertWorkspaceBuildParameters(ctx context.Context, arg InsertWorkspaceBuildParametersParams) error
+	InsertWorkspaceDeadlineExtension(ctx context.Context, arg InsertWorkspaceDeadlineExtensionParams) (WorkspaceDeadlineExtension, error)
 	InsertWorkspaceProxy(ctx context.Context, arg InsertWorkspaceProxyParams) (WorkspaceProxy, error)
 	InsertWorkspaceResource(ctx context.Context, arg InsertWorkspaceResourceParams) (WorkspaceResource, error)
 	InsertWorkspaceResourceMetadata(ctx context.Context, arg InsertWorkspaceResourceMetadataParams) ([]WorkspaceResourceMetadatum, error)
 	RegisterWorkspaceProxy(ctx context.Context, arg RegisterWorkspaceProxyParams) (WorkspaceProxy, error)
+	// Replaces the token of a workspace proxy, and keeps the replaced token valid
+	// until @previous_token_expires_at. The token is only replaced if it still
+	// matches @current_token_hashed_secret, so concurrent rotations by replicas of
+	// the same proxy don't replace each other's tokens.
+	RotateWorkspaceProxyToken(ctx context.Context, arg RotateWorkspaceProxyTokenParams) (WorkspaceProxy, error)
 	// Non blocking lock. Returns true if the lock was acquired, false otherwise.
 	//
 	// This must be called from within a transaction. The lock will be automatically
 	// released when the transaction ends.
 	TryAcquireLock(ctx context.Context, pgTryAdvisoryXactLock int64) (bool, error)
 	UpdateAPIKeyByID(ctx context.Context, arg UpdateAPIKeyByIDParams) error
+	UpdateCustomRole(ctx context.Context, arg UpdateCustomRoleParams) (CustomRole, error)
 	UpdateGitAuthLink(ctx context.Context, arg UpdateGitAuthLinkParams) (GitAuthLink, error)
 	UpdateGitSSHKey(ctx context.Context, arg UpdateGitSSHKeyParams) (GitSSHKey, error)
 	UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDParams) (Group, error)
 	UpdateInactiveUsersToDormant(ctx context.Context, arg UpdateInactiveUsersToDormantParams) ([]UpdateInactiveUsersToDormantRow, error)
 	UpdateMemberRoles(ctx context.Context, arg UpdateMemberRolesParams) (OrganizationMember, error)
+	UpdateOrganizationBudgetAlertedThreshold(ctx context.Context, arg UpdateOrganizationBudgetAlertedThresholdParams) error
+	UpdateProvisionerDaemonCacheStats(ctx context.Context, arg UpdateProvisionerDaemonCacheStatsParams) error
 	UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error
+	UpdateProvisionerJobNotBeforeByID(ctx context.Context, arg UpdateProvisionerJobNotBeforeByIDParams) error
 	UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error
 	UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error
+	UpdateProvisionerKeyLastUsedAt(ctx context.Context, arg UpdateProvisionerKeyLastUsedAtParams) error
 	UpdateReplica(ctx context.Context, arg UpdateReplicaParams) (Replica, error)
+	UpdateSavedWorkspaceFilter(ctx context.Context, arg UpdateSavedWorkspaceFilterParams) (SavedWorkspaceFilter, error)
+	UpdateScheduleHolidayByID(ctx context.Context, arg UpdateScheduleHolidayByIDParams) (ScheduleHoliday, error)
 	UpdateTemplateACLByID(ctx context.Context, arg UpdateTemplateACLByIDParams) error
 	UpdateTemplateActiveVersionByID(ctx context.Context, arg UpdateTemplateActiveVersionByIDParams) error
+	UpdateTemplateDeadlineRecalculationCompletedByID(ctx context.Context, arg UpdateTemplateDeadlineRecalculationCompletedByIDParams) error
+	UpdateTemplateDeadlineRecalculationProgressByID(ctx context.Context, arg UpdateTemplateDeadlineRecalculationProgressByIDParams) error
 	UpdateTemplateDeletedByID(ctx context.Context, arg UpdateTemplateDeletedByIDParams) error
 	UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) error
 	UpdateTemplateScheduleByID(ctx context.Context, arg UpdateTemplateScheduleByIDParams) error
+	UpdateTemplateSchedulePolicyByID(ctx context.Context, arg UpdateTemplateSchedulePolicyByIDParams) error
 	UpdateTemplateVersionByID(ctx context.Context, arg UpdateTemplateVersionByIDParams) error
 	UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg UpdateTemplateVersionDescriptionByJobIDParams) error
 	UpdateTemplateVersionGitAuthProvidersByJobID(ctx context.Context, arg UpdateTemplateVersionGitAuthProvidersByJobIDParams) error
+	// Resolves a pending promotion. Returns no rows if the promotion isn't
+	// pending.
+	UpdateTemplateVersionPromotionStatusByID(ctx context.Context, arg UpdateTemplateVersionPromotionStatusByIDParams) (TemplateVersionPromotion, error)
 	UpdateUserDeletedByID(ctx context.Context, arg UpdateUserDeletedByIDParams) error
 	UpdateUserHashedPassword(ctx context.Context, arg UpdateUserHashedPasswordParams) error
 	UpdateUserLastSeenAt(ctx context.Context, arg UpdateUserLastSeenAtParams) (User, error)
@@ -272,18 +453,24 @@ type sqlcQuerier interface {
 	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
 	UpdateUserQuietHoursSchedule(ctx context.Context, arg UpdateUserQuietHoursScheduleParams) (User, error)
 	UpdateUserRoles(ctx context.Context, arg UpdateUserRolesParams) (User, error)
+	UpdateUserSecret(ctx context.Context, arg UpdateUserSecretParams) (UserSecret, error)
 	UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error)
 	UpdateWorkspace(ctx context.Context, arg UpdateWorkspaceParams) (Workspace, error)
 	UpdateWorkspaceAgentConnectionByID(ctx context.Context, arg UpdateWorkspaceAgentConnectionByIDParams) error
 	UpdateWorkspaceAgentLifecycleStateByID(ctx context.Context, arg UpdateWorkspaceAgentLifecycleStateByIDParams) error
 	UpdateWorkspaceAgentLogOverflowByID(ctx context.Context, arg UpdateWorkspaceAgentLogOverflowByIDParams) error
 	UpdateWorkspaceAgentMetadata(ctx context.Context, arg UpdateWorkspaceAgentMetadataParams) error
+	UpdateWorkspaceAgentScriptStatus(ctx context.Context, arg UpdateWorkspaceAgentScriptStatusParams) error
 	UpdateWorkspaceAgentStartupByID(ctx context.Context, arg UpdateWorkspaceAgentStartupByIDParams) error
 	UpdateWorkspaceAppHealthByID(ctx context.Context, arg UpdateWorkspaceAppHealthByIDParams) error
 	UpdateWorkspaceAutostart(ctx context.Context, arg UpdateWorkspaceAutostartParams) error
+	UpdateWorkspaceBatchCompletedByID(ctx context.Context, arg UpdateWorkspaceBatchCompletedByIDParams) error
+	UpdateWorkspaceBatchUpdatedAtByID(ctx context.Context, arg UpdateWorkspaceBatchUpdatedAtByIDParams) error
+	UpdateWorkspaceBatchWorkspace(ctx context.Context, arg UpdateWorkspaceBatchWorkspaceParams) error
 	UpdateWorkspaceBuildByID(ctx context.Context, arg UpdateWorkspaceBuildByIDParams) error
 	UpdateWorkspaceBuildCostByID(ctx context.Context, arg UpdateWorkspaceBuildCostByIDParams) error
 	UpdateWorkspaceDeletedByID(ctx context.Context, arg UpdateWorkspaceDeletedByIDParams) error
+	UpdateWorkspaceGroupACLByID(ctx context.Context, arg UpdateWorkspaceGroupACLByIDParams) error
 	UpdateWorkspaceLastUsedAt(ctx context.Context, arg UpdateWorkspaceLastUsedAtParams) error
 	UpdateWorkspaceLockedDeletingAt(ctx context.Context, arg UpdateWorkspaceLockedDeletingAtParams) (Workspace, error)
 	// This allows editing the properties of a workspace proxy.
@@ -291,7 +478,9 @@ type sqlcQuerier interface {
 	UpdateWorkspaceProxyDeleted(ctx context.Context, arg UpdateWorkspaceProxyDeletedParams) error
 	UpdateWorkspaceTTL(ctx context.Context, arg UpdateWorkspaceTTLParams) error
 	UpdateWorkspacesDeletingAtByTemplateID(ctx context.Context, arg UpdateWorkspacesDeletingAtByTemplateIDParams) error
+	UpsertACMECertificate(ctx context.Context, arg UpsertACMECertificateParams) error
 	UpsertAppSecurityKey(ctx context.Context, value string) error
+	UpsertAuditLogExportCursor(ctx context.Context, arg UpsertAuditLogExportCursorParams) error
 	// The default proxy is implied and not actually stored in the database.
 	// So we need to store it's configuration here for display purposes.
 	// The functional values are immutable and controlled implicitly.
@@ -299,10 +488,25 @@ type sqlcQuerier interface {
 	UpsertLastUpdateCheck(ctx context.Context, value string) error
 	UpsertLogoURL(ctx context.Context, value string) error
 	UpsertOAuthSigningKey(ctx context.Context, value string) error
+	// Changing the settings resets the alerted threshold, so alerts are sent again
+	// for thresholds that are still crossed with the new settings.
+	UpsertOrganizationBudgetSettings(ctx context.Context, arg UpsertOrganizationBudgetSettingsParams) (OrganizationBudgetSettings, error)
+	UpsertOrganizationJITProvisioningSettings(ctx context.Context, arg UpsertOrganizationJITProvisioningSettingsParams) (OrganizationJITProvisioningSettings, error)
+	UpsertOrganizationProvisionerSettings(ctx context.Context, arg UpsertOrganizationProvisionerSettingsParams) (OrganizationProvisionerSettings, error)
+	UpsertOrganizationScheduleSettings(ctx context.Context, arg UpsertOrganizationScheduleSettingsParams) (OrganizationScheduleSettings, error)
+	UpsertOrganizationTemplateVariable(ctx context.Context, arg UpsertOrganizationTemplateVariableParams) (OrganizationTemplateVariable, error)
 	UpsertServiceBanner(ctx context.Context, value string) error
 	UpsertTailnetAgent(ctx context.Context, arg UpsertTailnetAgentParams) (TailnetAgent, error)
 	UpsertTailnetClient(ctx context.Context, arg UpsertTailnetClientParams) (TailnetClient, error)
 	UpsertTailnetCoordinator(ctx context.Context, id uuid.UUID) (TailnetCoordinator, error)
+	UpsertTemplateParameterValidation(ctx context.Context, arg UpsertTemplateParameterValidationParams) (TemplateParameterValidation, error)
+	UpsertTemplatePrebuildPool(ctx context.Context, arg UpsertTemplatePrebuildPoolParams) (TemplatePrebuildPool, error)
+	UpsertUserDotfiles(ctx context.Context, arg UpsertUserDotfilesParams) (UserDotfile, error)
+	// Registers metadata defined at runtime by the metadata plugins of an agent.
+	// Metadata defined in the template is never overwritten.
+	UpsertWorkspaceAgentPluginMetadata(ctx context.Context, arg UpsertWorkspaceAgentPluginMetadataParams) error
+	UpsertWorkspaceNameRedirect(ctx context.Context, arg UpsertWorkspaceNameRedirectParams) (WorkspaceNameRedirect, error)
+	UpsertWorkspaceScheduleOverride(ctx context.Context, arg UpsertWorkspaceScheduleOverrideParams) (WorkspaceScheduleOverride, error)
 }
 
 var _ sqlcQuerier = (*sqlQuerier)(nil)
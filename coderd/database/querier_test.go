@@ -363,7 +363,7 @@ func TestQueuePosition(t *testing.T) {
 			Time:  database.Now(),
 			Valid: true,
 		},
-		Types: database.AllProvisionerTypeValues(),
+		Types: []string{string(database.ProvisionerTypeEcho), string(database.ProvisionerTypeTerraform)},
 		WorkerID: uuid.NullUUID{
 			UUID:  uuid.New(),
 			Valid: true,
@@ -390,6 +390,230 @@ func TestQueuePosition(t *testing.T) {
 	}
 }
 
+func TestQueuePriority(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.SkipNow()
+	}
+	sqlDB := testSQLDB(t)
+	err := migrations.Up(sqlDB)
+	require.NoError(t, err)
+	db := database.New(sqlDB)
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	background := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		OrganizationID: org.ID,
+		Tags:           database.StringMap{},
+		Priority:       database.ProvisionerJobPriorityBackground,
+	})
+	time.Sleep(time.Millisecond)
+	first := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		OrganizationID: org.ID,
+		Tags:           database.StringMap{},
+	})
+	time.Sleep(time.Millisecond)
+	second := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		OrganizationID: org.ID,
+		Tags:           database.StringMap{},
+	})
+
+	// Jobs with a higher priority are queued first, even though the
+	// background job was created before them.
+	queued, err := db.GetProvisionerJobsByIDsWithQueuePosition(ctx, []uuid.UUID{background.ID, first.ID, second.ID})
+	require.NoError(t, err)
+	require.Len(t, queued, 3)
+	positions := map[uuid.UUID]int64{}
+	for _, job := range queued {
+		positions[job.ProvisionerJob.ID] = job.QueuePosition
+	}
+	require.Equal(t, int64(1), positions[first.ID])
+	require.Equal(t, int64(2), positions[second.ID])
+	require.Equal(t, int64(3), positions[background.ID])
+
+	for _, expected := range []database.ProvisionerJob{first, second, background} {
+		job, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			StartedAt: sql.NullTime{
+				Time:  database.Now(),
+				Valid: true,
+			},
+			Types: []string{string(database.ProvisionerTypeEcho), string(database.ProvisionerTypeTerraform)},
+			WorkerID: uuid.NullUUID{
+				UUID:  uuid.New(),
+				Valid: true,
+			},
+			Tags: json.RawMessage("{}"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, expected.ID, job.ID)
+		require.Equal(t, expected.Priority, job.Priority)
+	}
+}
+
+//nolint:tparallel
+func TestAcquireProvisionerJobConcurrencyLimits(t *testing.T) {
+	t.Parallel()
+
+	if testing.Short() {
+		t.SkipNow()
+	}
+	sqlDB := testSQLDB(t)
+	err := migrations.Up(sqlDB)
+	require.NoError(t, err)
+	db := database.New(sqlDB)
+	ctx := testutil.Context(t, testutil.WaitLong)
+	acquire := func(t *testing.T) (database.ProvisionerJob, error) {
+		t.Helper()
+		return db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			StartedAt: sql.NullTime{
+				Time:  database.Now(),
+				Valid: true,
+			},
+			Types: []string{string(database.ProvisionerTypeEcho), string(database.ProvisionerTypeTerraform)},
+			WorkerID: uuid.NullUUID{
+				UUID:  uuid.New(),
+				Valid: true,
+			},
+			Tags: json.RawMessage("{}"),
+		})
+	}
+	complete := func(t *testing.T, job database.ProvisionerJob) {
+		t.Helper()
+		err := db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+			ID:        job.ID,
+			UpdatedAt: database.Now(),
+			CompletedAt: sql.NullTime{
+				Time:  database.Now(),
+				Valid: true,
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	// Each limit is tested with its own organization, so jobs aren't acquired
+	// by the wrong subtest. The subtests can't run in parallel for the same
+	// reason.
+	// nolint:paralleltest
+	t.Run("Organization", func(t *testing.T) {
+		org := dbgen.Organization(t, db, database.Organization{})
+		_, err := db.UpsertOrganizationProvisionerSettings(ctx, database.UpsertOrganizationProvisionerSettingsParams{
+			OrganizationID:    org.ID,
+			CreatedAt:         database.Now(),
+			UpdatedAt:         database.Now(),
+			MaxConcurrentJobs: 1,
+		})
+		require.NoError(t, err)
+
+		running := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			StartedAt: sql.NullTime{
+				Time:  database.Now(),
+				Valid: true,
+			},
+		})
+		queued := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			Tags:           database.StringMap{},
+		})
+
+		// The organization is running its maximum number of jobs.
+		_, err = acquire(t)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		complete(t, running)
+		job, err := acquire(t)
+		require.NoError(t, err)
+		require.Equal(t, queued.ID, job.ID)
+		complete(t, job)
+	})
+
+	// nolint:paralleltest
+	t.Run("Template", func(t *testing.T) {
+		user := dbgen.User(t, db, database.User{})
+		org := dbgen.Organization(t, db, database.Organization{})
+		versionJob := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			InitiatorID:    user.ID,
+			Type:           database.ProvisionerJobTypeTemplateVersionImport,
+			StartedAt: sql.NullTime{
+				Time:  database.Now(),
+				Valid: true,
+			},
+			CompletedAt: sql.NullTime{
+				Time:  database.Now(),
+				Valid: true,
+			},
+		})
+		version := dbgen.TemplateVersion(t, db, database.TemplateVersion{
+			OrganizationID: org.ID,
+			JobID:          versionJob.ID,
+			CreatedBy:      user.ID,
+		})
+		template := dbgen.Template(t, db, database.Template{
+			OrganizationID:  org.ID,
+			ActiveVersionID: version.ID,
+			CreatedBy:       user.ID,
+		})
+		err := db.UpdateTemplateMetaByID(ctx, database.UpdateTemplateMetaByIDParams{
+			ID:                  template.ID,
+			UpdatedAt:           database.Now(),
+			Name:                template.Name,
+			MaxConcurrentBuilds: 1,
+		})
+		require.NoError(t, err)
+
+		build := func(started bool) database.ProvisionerJob {
+			workspace := dbgen.Workspace(t, db, database.Workspace{
+				OwnerID:        user.ID,
+				OrganizationID: org.ID,
+				TemplateID:     template.ID,
+			})
+			seed := database.ProvisionerJob{
+				OrganizationID: org.ID,
+				InitiatorID:    user.ID,
+				Tags:           database.StringMap{},
+			}
+			if started {
+				seed.StartedAt = sql.NullTime{
+					Time:  database.Now(),
+					Valid: true,
+				}
+			}
+			job := dbgen.ProvisionerJob(t, db, seed)
+			dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+				WorkspaceID:       workspace.ID,
+				TemplateVersionID: version.ID,
+				InitiatorID:       user.ID,
+				JobID:             job.ID,
+			})
+			return job
+		}
+		running := build(true)
+		queued := build(false)
+		// Jobs that don't build workspaces aren't limited.
+		importJob := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			InitiatorID:    user.ID,
+			Type:           database.ProvisionerJobTypeTemplateVersionImport,
+			Tags:           database.StringMap{},
+		})
+
+		// The template is running its maximum number of builds, so the
+		// build is skipped.
+		job, err := acquire(t)
+		require.NoError(t, err)
+		require.Equal(t, importJob.ID, job.ID)
+		_, err = acquire(t)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+
+		complete(t, running)
+		job, err = acquire(t)
+		require.NoError(t, err)
+		require.Equal(t, queued.ID, job.ID)
+	})
+}
+
 func TestUserLastSeenFilter(t *testing.T) {
 	t.Parallel()
 	if testing.Short() {
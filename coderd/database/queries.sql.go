@@ -15,6 +15,72 @@ import (
 	"github.com/sqlc-dev/pqtype"
 )
 
+const getACMECertificate = `-- name: GetACMECertificate :one
+SELECT
+	domain, data, not_after, updated_at, workspace_proxy_id
+FROM
+	acme_certificates
+WHERE
+	workspace_proxy_id = $1
+	AND domain = $2
+`
+
+type GetACMECertificateParams struct {
+	WorkspaceProxyID uuid.UUID `db:"workspace_proxy_id" json:"workspace_proxy_id"`
+	Domain           string    `db:"domain" json:"domain"`
+}
+
+func (q *sqlQuerier) GetACMECertificate(ctx context.Context, arg GetACMECertificateParams) (ACMECertificate, error) {
+	row := q.db.QueryRowContext(ctx, getACMECertificate, arg.WorkspaceProxyID, arg.Domain)
+	var i ACMECertificate
+	err := row.Scan(
+		&i.Domain,
+		&i.Data,
+		&i.NotAfter,
+		&i.UpdatedAt,
+		&i.WorkspaceProxyID,
+	)
+	return i, err
+}
+
+const upsertACMECertificate = `-- name: UpsertACMECertificate :exec
+INSERT INTO
+	acme_certificates (
+		workspace_proxy_id,
+		domain,
+		data,
+		not_after,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(workspace_proxy_id, domain)
+DO UPDATE SET
+	data = $3,
+	not_after = $4,
+	updated_at = $5
+`
+
+type UpsertACMECertificateParams struct {
+	WorkspaceProxyID uuid.UUID `db:"workspace_proxy_id" json:"workspace_proxy_id"`
+	Domain           string    `db:"domain" json:"domain"`
+	Data             []byte    `db:"data" json:"data"`
+	NotAfter         time.Time `db:"not_after" json:"not_after"`
+	UpdatedAt        time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertACMECertificate(ctx context.Context, arg UpsertACMECertificateParams) error {
+	_, err := q.db.ExecContext(ctx, upsertACMECertificate,
+		arg.WorkspaceProxyID,
+		arg.Domain,
+		arg.Data,
+		arg.NotAfter,
+		arg.UpdatedAt,
+	)
+	return err
+}
+
 const deleteAPIKeyByID = `-- name: DeleteAPIKeyByID :exec
 DELETE FROM
 	api_keys
@@ -349,6 +415,168 @@ func (q *sqlQuerier) UpdateAPIKeyByID(ctx context.Context, arg UpdateAPIKeyByIDP
 	return err
 }
 
+const deleteAuditLogsByIDs = `-- name: DeleteAuditLogsByIDs :exec
+DELETE FROM
+	audit_logs
+WHERE
+	id = ANY($1 :: uuid [ ])
+`
+
+func (q *sqlQuerier) DeleteAuditLogsByIDs(ctx context.Context, ids []uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteAuditLogsByIDs, pq.Array(ids))
+	return err
+}
+
+const getAuditLogExportCursor = `-- name: GetAuditLogExportCursor :one
+SELECT
+	sink, last_time, last_id, leased_by, leased_until, updated_at
+FROM
+	audit_log_export_cursors
+WHERE
+	sink = $1
+`
+
+func (q *sqlQuerier) GetAuditLogExportCursor(ctx context.Context, sink string) (AuditLogExportCursor, error) {
+	row := q.db.QueryRowContext(ctx, getAuditLogExportCursor, sink)
+	var i AuditLogExportCursor
+	err := row.Scan(
+		&i.Sink,
+		&i.LastTime,
+		&i.LastID,
+		&i.LeasedBy,
+		&i.LeasedUntil,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getAuditLogsAfterCursor = `-- name: GetAuditLogsAfterCursor :many
+SELECT
+	id, time, user_id, organization_id, ip, user_agent, resource_type, resource_id, resource_target, action, diff, status_code, additional_fields, request_id, resource_icon
+FROM
+	audit_logs
+WHERE
+	("time", id) > ($1 :: timestamptz, $2 :: uuid)
+	AND "time" < $3 :: timestamptz
+ORDER BY
+	"time" ASC, id ASC
+LIMIT
+	$4 :: int
+`
+
+type GetAuditLogsAfterCursorParams struct {
+	AfterTime     time.Time `db:"after_time" json:"after_time"`
+	AfterID       uuid.UUID `db:"after_id" json:"after_id"`
+	CreatedBefore time.Time `db:"created_before" json:"created_before"`
+	LimitCount    int32     `db:"limit_count" json:"limit_count"`
+}
+
+// GetAuditLogsAfterCursor returns the audit logs after the cursor that were
+// created before the given time, oldest first. It's used to export audit logs
+// to an audit export sink.
+func (q *sqlQuerier) GetAuditLogsAfterCursor(ctx context.Context, arg GetAuditLogsAfterCursorParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditLogsAfterCursor,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.CreatedBefore,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Time,
+			&i.UserID,
+			&i.OrganizationID,
+			&i.Ip,
+			&i.UserAgent,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.ResourceTarget,
+			&i.Action,
+			&i.Diff,
+			&i.StatusCode,
+			&i.AdditionalFields,
+			&i.RequestID,
+			&i.ResourceIcon,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAuditLogsCreatedBefore = `-- name: GetAuditLogsCreatedBefore :many
+SELECT
+	id, time, user_id, organization_id, ip, user_agent, resource_type, resource_id, resource_target, action, diff, status_code, additional_fields, request_id, resource_icon
+FROM
+	audit_logs
+WHERE
+	"time" < $1 :: timestamptz
+ORDER BY
+	"time" ASC, id ASC
+LIMIT
+	$2 :: int
+`
+
+type GetAuditLogsCreatedBeforeParams struct {
+	CreatedBefore time.Time `db:"created_before" json:"created_before"`
+	LimitCount    int32     `db:"limit_count" json:"limit_count"`
+}
+
+// GetAuditLogsCreatedBefore returns the oldest audit logs created before the
+// given time, oldest first. It's used to archive expired audit logs.
+func (q *sqlQuerier) GetAuditLogsCreatedBefore(ctx context.Context, arg GetAuditLogsCreatedBeforeParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditLogsCreatedBefore, arg.CreatedBefore, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Time,
+			&i.UserID,
+			&i.OrganizationID,
+			&i.Ip,
+			&i.UserAgent,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.ResourceTarget,
+			&i.Action,
+			&i.Diff,
+			&i.StatusCode,
+			&i.AdditionalFields,
+			&i.RequestID,
+			&i.ResourceIcon,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getAuditLogsOffset = `-- name: GetAuditLogsOffset :many
 SELECT
     audit_logs.id, audit_logs.time, audit_logs.user_id, audit_logs.organization_id, audit_logs.ip, audit_logs.user_agent, audit_logs.resource_type, audit_logs.resource_id, audit_logs.resource_target, audit_logs.action, audit_logs.diff, audit_logs.status_code, audit_logs.additional_fields, audit_logs.request_id, audit_logs.resource_icon,
@@ -636,118 +864,112 @@ func (q *sqlQuerier) InsertAuditLog(ctx context.Context, arg InsertAuditLogParam
 	return i, err
 }
 
-const getFileByHashAndCreator = `-- name: GetFileByHashAndCreator :one
-SELECT
-	hash, created_at, created_by, mimetype, data, id
-FROM
-	files
-WHERE
-	hash = $1
-AND
-	created_by = $2
-LIMIT
-	1
+const upsertAuditLogExportCursor = `-- name: UpsertAuditLogExportCursor :exec
+INSERT INTO
+	audit_log_export_cursors (sink, last_time, last_id, leased_by, leased_until, updated_at)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+ON CONFLICT
+	(sink)
+DO UPDATE SET
+	last_time = $2,
+	last_id = $3,
+	leased_by = $4,
+	leased_until = $5,
+	updated_at = $6
 `
 
-type GetFileByHashAndCreatorParams struct {
-	Hash      string    `db:"hash" json:"hash"`
-	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
+type UpsertAuditLogExportCursorParams struct {
+	Sink        string    `db:"sink" json:"sink"`
+	LastTime    time.Time `db:"last_time" json:"last_time"`
+	LastID      uuid.UUID `db:"last_id" json:"last_id"`
+	LeasedBy    uuid.UUID `db:"leased_by" json:"leased_by"`
+	LeasedUntil time.Time `db:"leased_until" json:"leased_until"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) GetFileByHashAndCreator(ctx context.Context, arg GetFileByHashAndCreatorParams) (File, error) {
-	row := q.db.QueryRowContext(ctx, getFileByHashAndCreator, arg.Hash, arg.CreatedBy)
-	var i File
-	err := row.Scan(
-		&i.Hash,
-		&i.CreatedAt,
-		&i.CreatedBy,
-		&i.Mimetype,
-		&i.Data,
-		&i.ID,
+func (q *sqlQuerier) UpsertAuditLogExportCursor(ctx context.Context, arg UpsertAuditLogExportCursorParams) error {
+	_, err := q.db.ExecContext(ctx, upsertAuditLogExportCursor,
+		arg.Sink,
+		arg.LastTime,
+		arg.LastID,
+		arg.LeasedBy,
+		arg.LeasedUntil,
+		arg.UpdatedAt,
 	)
-	return i, err
+	return err
 }
 
-const getFileByID = `-- name: GetFileByID :one
+const deleteCustomRole = `-- name: DeleteCustomRole :exec
+WITH removed AS (
+	UPDATE
+		users
+	SET
+		rbac_roles = array_remove(rbac_roles, $1 :: text)
+	WHERE
+		$1 :: text = ANY(rbac_roles)
+)
+DELETE FROM
+	custom_roles
+WHERE
+	name = $1
+`
+
+// Users keep the names of their roles, so the role is removed from all users
+// before it is deleted. Unknown role names fail authorization.
+func (q *sqlQuerier) DeleteCustomRole(ctx context.Context, name string) error {
+	_, err := q.db.ExecContext(ctx, deleteCustomRole, name)
+	return err
+}
+
+const getCustomRoleByName = `-- name: GetCustomRoleByName :one
 SELECT
-	hash, created_at, created_by, mimetype, data, id
+	name, display_name, site_permissions, user_permissions, created_at, updated_at
 FROM
-	files
+	custom_roles
 WHERE
-	id = $1
-LIMIT
-	1
+	name = $1
 `
 
-func (q *sqlQuerier) GetFileByID(ctx context.Context, id uuid.UUID) (File, error) {
-	row := q.db.QueryRowContext(ctx, getFileByID, id)
-	var i File
+func (q *sqlQuerier) GetCustomRoleByName(ctx context.Context, name string) (CustomRole, error) {
+	row := q.db.QueryRowContext(ctx, getCustomRoleByName, name)
+	var i CustomRole
 	err := row.Scan(
-		&i.Hash,
+		&i.Name,
+		&i.DisplayName,
+		&i.SitePermissions,
+		&i.UserPermissions,
 		&i.CreatedAt,
-		&i.CreatedBy,
-		&i.Mimetype,
-		&i.Data,
-		&i.ID,
+		&i.UpdatedAt,
 	)
 	return i, err
 }
 
-const getFileTemplates = `-- name: GetFileTemplates :many
+const getCustomRoles = `-- name: GetCustomRoles :many
 SELECT
-	files.id AS file_id,
-	files.created_by AS file_created_by,
-	templates.id AS template_id,
-	templates.organization_id AS template_organization_id,
-	templates.created_by AS template_created_by,
-	templates.user_acl,
-	templates.group_acl
+	name, display_name, site_permissions, user_permissions, created_at, updated_at
 FROM
-	templates
-INNER JOIN
-	template_versions
-	ON templates.id = template_versions.template_id
-INNER JOIN
-	provisioner_jobs
-	ON job_id = provisioner_jobs.id
-INNER JOIN
-	files
-	ON files.id = provisioner_jobs.file_id
-WHERE
-    -- Only fetch template version associated files.
-	storage_method = 'file'
-	AND provisioner_jobs.type = 'template_version_import'
-	AND file_id = $1
+	custom_roles
+ORDER BY
+	name
 `
 
-type GetFileTemplatesRow struct {
-	FileID                 uuid.UUID   `db:"file_id" json:"file_id"`
-	FileCreatedBy          uuid.UUID   `db:"file_created_by" json:"file_created_by"`
-	TemplateID             uuid.UUID   `db:"template_id" json:"template_id"`
-	TemplateOrganizationID uuid.UUID   `db:"template_organization_id" json:"template_organization_id"`
-	TemplateCreatedBy      uuid.UUID   `db:"template_created_by" json:"template_created_by"`
-	UserACL                TemplateACL `db:"user_acl" json:"user_acl"`
-	GroupACL               TemplateACL `db:"group_acl" json:"group_acl"`
-}
-
-// Get all templates that use a file.
-func (q *sqlQuerier) GetFileTemplates(ctx context.Context, fileID uuid.UUID) ([]GetFileTemplatesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getFileTemplates, fileID)
+func (q *sqlQuerier) GetCustomRoles(ctx context.Context) ([]CustomRole, error) {
+	rows, err := q.db.QueryContext(ctx, getCustomRoles)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetFileTemplatesRow
+	var items []CustomRole
 	for rows.Next() {
-		var i GetFileTemplatesRow
+		var i CustomRole
 		if err := rows.Scan(
-			&i.FileID,
-			&i.FileCreatedBy,
-			&i.TemplateID,
-			&i.TemplateOrganizationID,
-			&i.TemplateCreatedBy,
-			&i.UserACL,
-			&i.GroupACL,
+			&i.Name,
+			&i.DisplayName,
+			&i.SitePermissions,
+			&i.UserPermissions,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -762,11 +984,222 @@ func (q *sqlQuerier) GetFileTemplates(ctx context.Context, fileID uuid.UUID) ([]
 	return items, nil
 }
 
-const insertFile = `-- name: InsertFile :one
+const insertCustomRole = `-- name: InsertCustomRole :one
 INSERT INTO
-	files (id, hash, created_at, created_by, mimetype, "data")
-VALUES
-	($1, $2, $3, $4, $5, $6) RETURNING hash, created_at, created_by, mimetype, data, id
+	custom_roles (
+		name,
+		display_name,
+		site_permissions,
+		user_permissions,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING name, display_name, site_permissions, user_permissions, created_at, updated_at
+`
+
+type InsertCustomRoleParams struct {
+	Name            string          `db:"name" json:"name"`
+	DisplayName     string          `db:"display_name" json:"display_name"`
+	SitePermissions json.RawMessage `db:"site_permissions" json:"site_permissions"`
+	UserPermissions json.RawMessage `db:"user_permissions" json:"user_permissions"`
+	CreatedAt       time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) InsertCustomRole(ctx context.Context, arg InsertCustomRoleParams) (CustomRole, error) {
+	row := q.db.QueryRowContext(ctx, insertCustomRole,
+		arg.Name,
+		arg.DisplayName,
+		arg.SitePermissions,
+		arg.UserPermissions,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i CustomRole
+	err := row.Scan(
+		&i.Name,
+		&i.DisplayName,
+		&i.SitePermissions,
+		&i.UserPermissions,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateCustomRole = `-- name: UpdateCustomRole :one
+UPDATE
+	custom_roles
+SET
+	display_name = $2,
+	site_permissions = $3,
+	user_permissions = $4,
+	updated_at = $5
+WHERE
+	name = $1
+RETURNING name, display_name, site_permissions, user_permissions, created_at, updated_at
+`
+
+type UpdateCustomRoleParams struct {
+	Name            string          `db:"name" json:"name"`
+	DisplayName     string          `db:"display_name" json:"display_name"`
+	SitePermissions json.RawMessage `db:"site_permissions" json:"site_permissions"`
+	UserPermissions json.RawMessage `db:"user_permissions" json:"user_permissions"`
+	UpdatedAt       time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateCustomRole(ctx context.Context, arg UpdateCustomRoleParams) (CustomRole, error) {
+	row := q.db.QueryRowContext(ctx, updateCustomRole,
+		arg.Name,
+		arg.DisplayName,
+		arg.SitePermissions,
+		arg.UserPermissions,
+		arg.UpdatedAt,
+	)
+	var i CustomRole
+	err := row.Scan(
+		&i.Name,
+		&i.DisplayName,
+		&i.SitePermissions,
+		&i.UserPermissions,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getFileByHashAndCreator = `-- name: GetFileByHashAndCreator :one
+SELECT
+	hash, created_at, created_by, mimetype, data, id
+FROM
+	files
+WHERE
+	hash = $1
+AND
+	created_by = $2
+LIMIT
+	1
+`
+
+type GetFileByHashAndCreatorParams struct {
+	Hash      string    `db:"hash" json:"hash"`
+	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
+}
+
+func (q *sqlQuerier) GetFileByHashAndCreator(ctx context.Context, arg GetFileByHashAndCreatorParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, getFileByHashAndCreator, arg.Hash, arg.CreatedBy)
+	var i File
+	err := row.Scan(
+		&i.Hash,
+		&i.CreatedAt,
+		&i.CreatedBy,
+		&i.Mimetype,
+		&i.Data,
+		&i.ID,
+	)
+	return i, err
+}
+
+const getFileByID = `-- name: GetFileByID :one
+SELECT
+	hash, created_at, created_by, mimetype, data, id
+FROM
+	files
+WHERE
+	id = $1
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetFileByID(ctx context.Context, id uuid.UUID) (File, error) {
+	row := q.db.QueryRowContext(ctx, getFileByID, id)
+	var i File
+	err := row.Scan(
+		&i.Hash,
+		&i.CreatedAt,
+		&i.CreatedBy,
+		&i.Mimetype,
+		&i.Data,
+		&i.ID,
+	)
+	return i, err
+}
+
+const getFileTemplates = `-- name: GetFileTemplates :many
+SELECT
+	files.id AS file_id,
+	files.created_by AS file_created_by,
+	templates.id AS template_id,
+	templates.organization_id AS template_organization_id,
+	templates.created_by AS template_created_by,
+	templates.user_acl,
+	templates.group_acl
+FROM
+	templates
+INNER JOIN
+	template_versions
+	ON templates.id = template_versions.template_id
+INNER JOIN
+	provisioner_jobs
+	ON job_id = provisioner_jobs.id
+INNER JOIN
+	files
+	ON files.id = provisioner_jobs.file_id
+WHERE
+    -- Only fetch template version associated files.
+	storage_method = 'file'
+	AND provisioner_jobs.type = 'template_version_import'
+	AND file_id = $1
+`
+
+type GetFileTemplatesRow struct {
+	FileID                 uuid.UUID   `db:"file_id" json:"file_id"`
+	FileCreatedBy          uuid.UUID   `db:"file_created_by" json:"file_created_by"`
+	TemplateID             uuid.UUID   `db:"template_id" json:"template_id"`
+	TemplateOrganizationID uuid.UUID   `db:"template_organization_id" json:"template_organization_id"`
+	TemplateCreatedBy      uuid.UUID   `db:"template_created_by" json:"template_created_by"`
+	UserACL                TemplateACL `db:"user_acl" json:"user_acl"`
+	GroupACL               TemplateACL `db:"group_acl" json:"group_acl"`
+}
+
+// Get all templates that use a file.
+func (q *sqlQuerier) GetFileTemplates(ctx context.Context, fileID uuid.UUID) ([]GetFileTemplatesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFileTemplates, fileID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFileTemplatesRow
+	for rows.Next() {
+		var i GetFileTemplatesRow
+		if err := rows.Scan(
+			&i.FileID,
+			&i.FileCreatedBy,
+			&i.TemplateID,
+			&i.TemplateOrganizationID,
+			&i.TemplateCreatedBy,
+			&i.UserACL,
+			&i.GroupACL,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertFile = `-- name: InsertFile :one
+INSERT INTO
+	files (id, hash, created_at, created_by, mimetype, "data")
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING hash, created_at, created_by, mimetype, data, id
 `
 
 type InsertFileParams struct {
@@ -1051,7 +1484,7 @@ DELETE FROM
 	group_members
 WHERE
 	group_members.user_id = $1
-	AND group_id = ANY(SELECT id FROM groups WHERE organization_id = $2)
+	AND group_id = ANY(SELECT id FROM groups WHERE organization_id = $2 AND source != 'scim')
 `
 
 type DeleteGroupMembersByOrgAndUserParams struct {
@@ -1059,11 +1492,46 @@ type DeleteGroupMembersByOrgAndUserParams struct {
 	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
 }
 
+// Groups pushed by SCIM are skipped, their members are managed by SCIM.
 func (q *sqlQuerier) DeleteGroupMembersByOrgAndUser(ctx context.Context, arg DeleteGroupMembersByOrgAndUserParams) error {
 	_, err := q.db.ExecContext(ctx, deleteGroupMembersByOrgAndUser, arg.UserID, arg.OrganizationID)
 	return err
 }
 
+const getGroupMemberUserIDs = `-- name: GetGroupMemberUserIDs :many
+SELECT
+	user_id
+FROM
+	group_members
+WHERE
+	group_id = $1
+`
+
+// GetGroupMemberUserIDs returns the IDs of all members of a group, unlike
+// GetGroupMembers it includes members that aren't active.
+func (q *sqlQuerier) GetGroupMemberUserIDs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupMemberUserIDs, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getGroupMembers = `-- name: GetGroupMembers :many
 SELECT
 	users.id, users.email, users.username, users.hashed_password, users.created_at, users.updated_at, users.status, users.rbac_roles, users.login_type, users.avatar_url, users.deleted, users.last_seen_at, users.quiet_hours_schedule
@@ -1143,7 +1611,8 @@ WITH groups AS (
         groups
     WHERE
         groups.organization_id = $2 AND
-        groups.name = ANY($3 :: text [])
+        groups.name = ANY($3 :: text []) AND
+        groups.source != 'scim'
 )
 INSERT INTO
     group_members (user_id, group_id)
@@ -1161,6 +1630,7 @@ type InsertUserGroupsByNameParams struct {
 }
 
 // InsertUserGroupsByName adds a user to all provided groups, if they exist.
+// Groups pushed by SCIM are skipped, their members are managed by SCIM.
 func (q *sqlQuerier) InsertUserGroupsByName(ctx context.Context, arg InsertUserGroupsByNameParams) error {
 	_, err := q.db.ExecContext(ctx, insertUserGroupsByName, arg.UserID, arg.OrganizationID, pq.Array(arg.GroupNames))
 	return err
@@ -1452,68 +1922,325 @@ func (q *sqlQuerier) UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDPar
 	return i, err
 }
 
-const getTemplateDailyInsights = `-- name: GetTemplateDailyInsights :many
-WITH d AS (
-	-- sqlc workaround, use SELECT generate_series instead of SELECT * FROM generate_series.
-	-- Subtract 1 second from end_time to avoid including the next interval in the results.
-	SELECT generate_series($1::timestamptz, ($2::timestamptz) - '1 second'::interval, '1 day'::interval) AS d
-), ts AS (
-	SELECT
-		d::timestamptz AS from_,
-		CASE WHEN (d + '1 day'::interval)::timestamptz <= $2::timestamptz THEN (d + '1 day'::interval)::timestamptz ELSE $2::timestamptz END AS to_
-	FROM d
-), usage_by_day AS (
-	SELECT
-		ts.from_, ts.to_,
-		was.user_id,
-		array_agg(was.template_id) AS template_ids
-	FROM ts
-	LEFT JOIN workspace_agent_stats was ON (
-		was.created_at >= ts.from_
-		AND was.created_at < ts.to_
-		AND was.connection_count > 0
-		AND CASE WHEN COALESCE(array_length($3::uuid[], 1), 0) > 0 THEN was.template_id = ANY($3::uuid[]) ELSE TRUE END
-	)
-	GROUP BY ts.from_, ts.to_, was.user_id
-), template_ids AS (
-	SELECT
-		template_usage_by_day.from_,
-		array_agg(template_id) AS ids
-	FROM (
-		SELECT DISTINCT
-			from_,
-			unnest(template_ids) AS template_id
-		FROM usage_by_day
-	) AS template_usage_by_day
-	WHERE template_id IS NOT NULL
-	GROUP BY template_usage_by_day.from_
-)
-
+const getAppAccessLogInsights = `-- name: GetAppAccessLogInsights :many
 SELECT
-	from_ AS start_time,
-	to_ AS end_time,
-	COALESCE((SELECT template_ids.ids FROM template_ids WHERE template_ids.from_ = usage_by_day.from_), '{}')::uuid[] AS template_ids,
-	COUNT(DISTINCT user_id) AS active_users
-FROM usage_by_day
-GROUP BY from_, to_
+	wal.user_id,
+	COALESCE(users.username, '')::text AS username,
+	COALESCE(users.avatar_url, '')::text AS avatar_url,
+	workspaces.template_id,
+	wal.access_method,
+	wal.slug_or_port,
+	wal.path_prefix,
+	wal.status_code,
+	COUNT(*)::bigint AS requests,
+	(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY wal.latency_ms))::FLOAT AS latency_50,
+	(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY wal.latency_ms))::FLOAT AS latency_95,
+	MAX(wal.created_at)::timestamptz AS last_request_at
+FROM workspace_app_access_logs wal
+LEFT JOIN users ON (users.id = wal.user_id)
+JOIN workspaces ON (workspaces.id = wal.workspace_id)
+WHERE
+	wal.created_at >= $1
+	AND wal.created_at < $2
+	AND CASE WHEN COALESCE(array_length($3::uuid[], 1), 0) > 0 THEN workspaces.template_id = ANY($3::uuid[]) ELSE TRUE END
+GROUP BY wal.user_id, users.username, users.avatar_url, workspaces.template_id, wal.access_method, wal.slug_or_port, wal.path_prefix, wal.status_code
+ORDER BY wal.user_id ASC, workspaces.template_id ASC, wal.slug_or_port ASC, wal.access_method ASC, wal.path_prefix ASC, wal.status_code ASC
 `
 
-type GetTemplateDailyInsightsParams struct {
-	StartTime   time.Time   `db:"start_time" json:"start_time"`
-	EndTime     time.Time   `db:"end_time" json:"end_time"`
-	TemplateIDs []uuid.UUID `db:"template_ids" json:"template_ids"`
-}
-
-type GetTemplateDailyInsightsRow struct {
+type GetAppAccessLogInsightsParams struct {
 	StartTime   time.Time   `db:"start_time" json:"start_time"`
 	EndTime     time.Time   `db:"end_time" json:"end_time"`
 	TemplateIDs []uuid.UUID `db:"template_ids" json:"template_ids"`
-	ActiveUsers int64       `db:"active_users" json:"active_users"`
 }
 
-// GetTemplateDailyInsights returns all daily intervals between start and end
-// time, if end time is a partial day, it will be included in the results and
-// that interval will be less than 24 hours. If there is no data for a selected
+type GetAppAccessLogInsightsRow struct {
+	UserID        uuid.NullUUID `db:"user_id" json:"user_id"`
+	Username      string        `db:"username" json:"username"`
+	AvatarURL     string        `db:"avatar_url" json:"avatar_url"`
+	TemplateID    uuid.UUID     `db:"template_id" json:"template_id"`
+	AccessMethod  string        `db:"access_method" json:"access_method"`
+	SlugOrPort    string        `db:"slug_or_port" json:"slug_or_port"`
+	PathPrefix    string        `db:"path_prefix" json:"path_prefix"`
+	StatusCode    int32         `db:"status_code" json:"status_code"`
+	Requests      int64         `db:"requests" json:"requests"`
+	Latency50     float64       `db:"latency_50" json:"latency_50"`
+	Latency95     float64       `db:"latency_95" json:"latency_95"`
+	LastRequestAt time.Time     `db:"last_request_at" json:"last_request_at"`
+}
+
+// GetAppAccessLogInsights returns the number of requests to each workspace app
+// and their median and 95th percentile latency per user, path prefix and status
+// code, for requests received within the given timeframe. Requests of users
+// that were not signed in to Coder have a NULL user_id. The result can be
+// filtered on template_ids, meaning only requests to workspaces based on those
+// templates will be included.
+func (q *sqlQuerier) GetAppAccessLogInsights(ctx context.Context, arg GetAppAccessLogInsightsParams) ([]GetAppAccessLogInsightsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAppAccessLogInsights, arg.StartTime, arg.EndTime, pq.Array(arg.TemplateIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAppAccessLogInsightsRow
+	for rows.Next() {
+		var i GetAppAccessLogInsightsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.AvatarURL,
+			&i.TemplateID,
+			&i.AccessMethod,
+			&i.SlugOrPort,
+			&i.PathPrefix,
+			&i.StatusCode,
+			&i.Requests,
+			&i.Latency50,
+			&i.Latency95,
+			&i.LastRequestAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAppSessionInsights = `-- name: GetAppSessionInsights :many
+SELECT
+	was.user_id,
+	users.username,
+	users.avatar_url,
+	workspaces.template_id,
+	was.access_method,
+	was.slug_or_port,
+	COALESCE(SUM(was.requests), 0)::bigint AS sessions,
+	COALESCE(SUM(was.active_duration_ms), 0)::bigint AS active_duration_ms,
+	MIN(was.session_started_at)::timestamptz AS first_session_started_at,
+	MAX(was.session_ended_at)::timestamptz AS last_session_ended_at
+FROM workspace_app_stats was
+JOIN users ON (users.id = was.user_id)
+JOIN workspaces ON (workspaces.id = was.workspace_id)
+WHERE
+	was.session_started_at >= $1
+	AND was.session_started_at < $2
+	AND CASE WHEN COALESCE(array_length($3::uuid[], 1), 0) > 0 THEN workspaces.template_id = ANY($3::uuid[]) ELSE TRUE END
+GROUP BY was.user_id, users.username, users.avatar_url, workspaces.template_id, was.access_method, was.slug_or_port
+ORDER BY was.user_id ASC, workspaces.template_id ASC, was.slug_or_port ASC, was.access_method ASC
+`
+
+type GetAppSessionInsightsParams struct {
+	StartTime   time.Time   `db:"start_time" json:"start_time"`
+	EndTime     time.Time   `db:"end_time" json:"end_time"`
+	TemplateIDs []uuid.UUID `db:"template_ids" json:"template_ids"`
+}
+
+type GetAppSessionInsightsRow struct {
+	UserID                uuid.UUID      `db:"user_id" json:"user_id"`
+	Username              string         `db:"username" json:"username"`
+	AvatarURL             sql.NullString `db:"avatar_url" json:"avatar_url"`
+	TemplateID            uuid.UUID      `db:"template_id" json:"template_id"`
+	AccessMethod          string         `db:"access_method" json:"access_method"`
+	SlugOrPort            string         `db:"slug_or_port" json:"slug_or_port"`
+	Sessions              int64          `db:"sessions" json:"sessions"`
+	ActiveDurationMS      int64          `db:"active_duration_ms" json:"active_duration_ms"`
+	FirstSessionStartedAt time.Time      `db:"first_session_started_at" json:"first_session_started_at"`
+	LastSessionEndedAt    time.Time      `db:"last_session_ended_at" json:"last_session_ended_at"`
+}
+
+// GetAppSessionInsights returns the number of sessions and the time spent in
+// each workspace app per user, for sessions that started within the given
+// timeframe. The result can be filtered on template_ids, meaning only sessions
+// in workspaces based on those templates will be included.
+func (q *sqlQuerier) GetAppSessionInsights(ctx context.Context, arg GetAppSessionInsightsParams) ([]GetAppSessionInsightsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAppSessionInsights, arg.StartTime, arg.EndTime, pq.Array(arg.TemplateIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAppSessionInsightsRow
+	for rows.Next() {
+		var i GetAppSessionInsightsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.AvatarURL,
+			&i.TemplateID,
+			&i.AccessMethod,
+			&i.SlugOrPort,
+			&i.Sessions,
+			&i.ActiveDurationMS,
+			&i.FirstSessionStartedAt,
+			&i.LastSessionEndedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getScheduleInsights = `-- name: GetScheduleInsights :many
+WITH build_intervals AS (
+	SELECT
+		workspaces.template_id,
+		workspace_builds.transition,
+		workspace_builds.reason,
+		workspace_builds.created_at,
+		provisioner_jobs.error IS NULL AS succeeded,
+		GREATEST(workspace_builds.created_at, $1::timestamptz) AS interval_start,
+		LEAST(
+			COALESCE(
+				LEAD(workspace_builds.created_at) OVER (PARTITION BY workspace_builds.workspace_id ORDER BY workspace_builds.build_number),
+				$2::timestamptz
+			),
+			$2::timestamptz
+		) AS interval_end
+	FROM workspace_builds
+	JOIN workspaces ON (workspaces.id = workspace_builds.workspace_id)
+	JOIN provisioner_jobs ON (provisioner_jobs.id = workspace_builds.job_id)
+	WHERE
+		workspace_builds.created_at < $2::timestamptz
+		AND CASE WHEN COALESCE(array_length($3::uuid[], 1), 0) > 0 THEN workspaces.template_id = ANY($3::uuid[]) ELSE TRUE END
+)
+
+SELECT
+	template_id,
+	COALESCE(SUM(EXTRACT(EPOCH FROM interval_end - interval_start)) FILTER (WHERE transition != 'delete'), 0)::bigint AS allocated_seconds,
+	COALESCE(SUM(EXTRACT(EPOCH FROM interval_end - interval_start)) FILTER (WHERE transition = 'start' AND succeeded), 0)::bigint AS running_seconds,
+	COALESCE(SUM(EXTRACT(EPOCH FROM interval_end - interval_start)) FILTER (WHERE transition = 'stop' AND reason IN ('autostop', 'restart_requirement')), 0)::bigint AS schedule_stopped_seconds,
+	COUNT(*) FILTER (WHERE created_at >= $1::timestamptz AND transition = 'start' AND reason = 'autostart') AS autostarts,
+	COUNT(*) FILTER (WHERE created_at >= $1::timestamptz AND transition = 'stop' AND reason IN ('autostop', 'restart_requirement')) AS autostops
+FROM build_intervals
+WHERE interval_end > interval_start
+GROUP BY template_id
+ORDER BY template_id ASC
+`
+
+type GetScheduleInsightsParams struct {
+	StartTime   time.Time   `db:"start_time" json:"start_time"`
+	EndTime     time.Time   `db:"end_time" json:"end_time"`
+	TemplateIDs []uuid.UUID `db:"template_ids" json:"template_ids"`
+}
+
+type GetScheduleInsightsRow struct {
+	TemplateID             uuid.UUID `db:"template_id" json:"template_id"`
+	AllocatedSeconds       int64     `db:"allocated_seconds" json:"allocated_seconds"`
+	RunningSeconds         int64     `db:"running_seconds" json:"running_seconds"`
+	ScheduleStoppedSeconds int64     `db:"schedule_stopped_seconds" json:"schedule_stopped_seconds"`
+	Autostarts             int64     `db:"autostarts" json:"autostarts"`
+	Autostops              int64     `db:"autostops" json:"autostops"`
+}
+
+// GetScheduleInsights returns how long the workspaces of each template existed,
+// were running and were stopped by their schedule within the given timeframe,
+// based on the build history. A build lasts until the next build of the
+// workspace, workspaces are running after successful start builds and stopped
+// by their schedule after autostop or restart requirement builds. The result
+// can be filtered on template_ids, meaning only workspaces based on those
+// templates will be included.
+func (q *sqlQuerier) GetScheduleInsights(ctx context.Context, arg GetScheduleInsightsParams) ([]GetScheduleInsightsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getScheduleInsights, arg.StartTime, arg.EndTime, pq.Array(arg.TemplateIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetScheduleInsightsRow
+	for rows.Next() {
+		var i GetScheduleInsightsRow
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.AllocatedSeconds,
+			&i.RunningSeconds,
+			&i.ScheduleStoppedSeconds,
+			&i.Autostarts,
+			&i.Autostops,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplateDailyInsights = `-- name: GetTemplateDailyInsights :many
+WITH d AS (
+	-- sqlc workaround, use SELECT generate_series instead of SELECT * FROM generate_series.
+	-- Subtract 1 second from end_time to avoid including the next interval in the results.
+	SELECT generate_series($1::timestamptz, ($2::timestamptz) - '1 second'::interval, '1 day'::interval) AS d
+), ts AS (
+	SELECT
+		d::timestamptz AS from_,
+		CASE WHEN (d + '1 day'::interval)::timestamptz <= $2::timestamptz THEN (d + '1 day'::interval)::timestamptz ELSE $2::timestamptz END AS to_
+	FROM d
+), usage_by_day AS (
+	SELECT
+		ts.from_, ts.to_,
+		was.user_id,
+		array_agg(was.template_id) AS template_ids
+	FROM ts
+	LEFT JOIN workspace_agent_stats was ON (
+		was.created_at >= ts.from_
+		AND was.created_at < ts.to_
+		AND was.connection_count > 0
+		AND CASE WHEN COALESCE(array_length($3::uuid[], 1), 0) > 0 THEN was.template_id = ANY($3::uuid[]) ELSE TRUE END
+	)
+	GROUP BY ts.from_, ts.to_, was.user_id
+), template_ids AS (
+	SELECT
+		template_usage_by_day.from_,
+		array_agg(template_id) AS ids
+	FROM (
+		SELECT DISTINCT
+			from_,
+			unnest(template_ids) AS template_id
+		FROM usage_by_day
+	) AS template_usage_by_day
+	WHERE template_id IS NOT NULL
+	GROUP BY template_usage_by_day.from_
+)
+
+SELECT
+	from_ AS start_time,
+	to_ AS end_time,
+	COALESCE((SELECT template_ids.ids FROM template_ids WHERE template_ids.from_ = usage_by_day.from_), '{}')::uuid[] AS template_ids,
+	COUNT(DISTINCT user_id) AS active_users
+FROM usage_by_day
+GROUP BY from_, to_
+`
+
+type GetTemplateDailyInsightsParams struct {
+	StartTime   time.Time   `db:"start_time" json:"start_time"`
+	EndTime     time.Time   `db:"end_time" json:"end_time"`
+	TemplateIDs []uuid.UUID `db:"template_ids" json:"template_ids"`
+}
+
+type GetTemplateDailyInsightsRow struct {
+	StartTime   time.Time   `db:"start_time" json:"start_time"`
+	EndTime     time.Time   `db:"end_time" json:"end_time"`
+	TemplateIDs []uuid.UUID `db:"template_ids" json:"template_ids"`
+	ActiveUsers int64       `db:"active_users" json:"active_users"`
+}
+
+// GetTemplateDailyInsights returns all daily intervals between start and end
+// time, if end time is a partial day, it will be included in the results and
+// that interval will be less than 24 hours. If there is no data for a selected
 // interval/template, it will be included in the results with 0 active users.
 func (q *sqlQuerier) GetTemplateDailyInsights(ctx context.Context, arg GetTemplateDailyInsightsParams) ([]GetTemplateDailyInsightsRow, error) {
 	rows, err := q.db.QueryContext(ctx, getTemplateDailyInsights, arg.StartTime, arg.EndTime, pq.Array(arg.TemplateIDs))
@@ -1724,6 +2451,154 @@ func (q *sqlQuerier) GetTemplateParameterInsights(ctx context.Context, arg GetTe
 	return items, nil
 }
 
+const getTemplateVersionBuildInsights = `-- name: GetTemplateVersionBuildInsights :many
+WITH d AS (
+	-- sqlc workaround, use SELECT generate_series instead of SELECT * FROM generate_series.
+	-- Subtract 1 second from end_time to avoid including the next interval in the results.
+	SELECT generate_series($1::timestamptz, ($2::timestamptz) - '1 second'::interval, $3::bigint * '1 second'::interval) AS d
+), ts AS (
+	SELECT
+		d::timestamptz AS from_,
+		LEAST(d + $3::bigint * '1 second'::interval, $2::timestamptz)::timestamptz AS to_
+	FROM d
+), builds AS (
+	SELECT
+		ts.from_,
+		ts.to_,
+		workspaces.template_id,
+		workspace_builds.template_version_id,
+		workspace_builds.job_id,
+		provisioner_jobs.error IS NOT NULL AS failed,
+		EXTRACT(EPOCH FROM provisioner_jobs.completed_at - provisioner_jobs.started_at) * 1000 AS duration_ms
+	FROM ts
+	JOIN workspace_builds ON (
+		workspace_builds.created_at >= ts.from_
+		AND workspace_builds.created_at < ts.to_
+	)
+	JOIN workspaces ON (workspaces.id = workspace_builds.workspace_id)
+	JOIN provisioner_jobs ON (provisioner_jobs.id = workspace_builds.job_id)
+	WHERE
+		workspace_builds.transition = 'start'
+		AND provisioner_jobs.completed_at IS NOT NULL
+		AND provisioner_jobs.canceled_at IS NULL
+		AND CASE WHEN COALESCE(array_length($4::uuid[], 1), 0) > 0 THEN workspaces.template_id = ANY($4::uuid[]) ELSE TRUE END
+), build_stats AS (
+	SELECT
+		from_,
+		to_,
+		template_id,
+		template_version_id,
+		COUNT(*) AS builds,
+		COUNT(*) FILTER (WHERE failed) AS failed_builds,
+		PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE NOT failed) AS duration_50,
+		PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE NOT failed) AS duration_95
+	FROM builds
+	GROUP BY from_, to_, template_id, template_version_id
+), agent_stats AS (
+	SELECT
+		builds.from_,
+		builds.template_version_id,
+		PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM workspace_agents.first_connected_at - workspace_agents.created_at) * 1000) AS connect_50,
+		PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM workspace_agents.first_connected_at - workspace_agents.created_at) * 1000) AS connect_95
+	FROM builds
+	JOIN workspace_resources ON (workspace_resources.job_id = builds.job_id)
+	JOIN workspace_agents ON (workspace_agents.resource_id = workspace_resources.id)
+	WHERE
+		NOT builds.failed
+		AND workspace_agents.first_connected_at IS NOT NULL
+	GROUP BY builds.from_, builds.template_version_id
+)
+
+SELECT
+	build_stats.from_ AS start_time,
+	build_stats.to_ AS end_time,
+	build_stats.template_id,
+	build_stats.template_version_id,
+	template_versions.name AS template_version_name,
+	build_stats.builds,
+	build_stats.failed_builds,
+	COALESCE(build_stats.duration_50, -1)::FLOAT AS build_duration_50,
+	COALESCE(build_stats.duration_95, -1)::FLOAT AS build_duration_95,
+	COALESCE(agent_stats.connect_50, -1)::FLOAT AS agent_connect_50,
+	COALESCE(agent_stats.connect_95, -1)::FLOAT AS agent_connect_95
+FROM build_stats
+JOIN template_versions ON (template_versions.id = build_stats.template_version_id)
+LEFT JOIN agent_stats ON (
+	agent_stats.from_ = build_stats.from_
+	AND agent_stats.template_version_id = build_stats.template_version_id
+)
+ORDER BY build_stats.from_ ASC, build_stats.template_id ASC, template_versions.created_at ASC
+`
+
+type GetTemplateVersionBuildInsightsParams struct {
+	StartTime       time.Time   `db:"start_time" json:"start_time"`
+	EndTime         time.Time   `db:"end_time" json:"end_time"`
+	IntervalSeconds int64       `db:"interval_seconds" json:"interval_seconds"`
+	TemplateIDs     []uuid.UUID `db:"template_ids" json:"template_ids"`
+}
+
+type GetTemplateVersionBuildInsightsRow struct {
+	StartTime           time.Time `db:"start_time" json:"start_time"`
+	EndTime             time.Time `db:"end_time" json:"end_time"`
+	TemplateID          uuid.UUID `db:"template_id" json:"template_id"`
+	TemplateVersionID   uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	TemplateVersionName string    `db:"template_version_name" json:"template_version_name"`
+	Builds              int64     `db:"builds" json:"builds"`
+	FailedBuilds        int64     `db:"failed_builds" json:"failed_builds"`
+	BuildDuration50     float64   `db:"build_duration_50" json:"build_duration_50"`
+	BuildDuration95     float64   `db:"build_duration_95" json:"build_duration_95"`
+	AgentConnect50      float64   `db:"agent_connect_50" json:"agent_connect_50"`
+	AgentConnect95      float64   `db:"agent_connect_95" json:"agent_connect_95"`
+}
+
+// GetTemplateVersionBuildInsights returns the number of start builds of each
+// template version and how many of them failed, the median and 95th percentile
+// time that successful builds took to provision, and the median and 95th
+// percentile time that their agents took to connect, in intervals of the given
+// length between start and end time. Builds are counted in the interval they
+// were created in, canceled and unfinished builds are not counted. Percentiles
+// are -1 if there is no data. The result can be filtered on template_ids,
+// meaning only builds of those templates will be included.
+func (q *sqlQuerier) GetTemplateVersionBuildInsights(ctx context.Context, arg GetTemplateVersionBuildInsightsParams) ([]GetTemplateVersionBuildInsightsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionBuildInsights,
+		arg.StartTime,
+		arg.EndTime,
+		arg.IntervalSeconds,
+		pq.Array(arg.TemplateIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTemplateVersionBuildInsightsRow
+	for rows.Next() {
+		var i GetTemplateVersionBuildInsightsRow
+		if err := rows.Scan(
+			&i.StartTime,
+			&i.EndTime,
+			&i.TemplateID,
+			&i.TemplateVersionID,
+			&i.TemplateVersionName,
+			&i.Builds,
+			&i.FailedBuilds,
+			&i.BuildDuration50,
+			&i.BuildDuration95,
+			&i.AgentConnect50,
+			&i.AgentConnect95,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserLatencyInsights = `-- name: GetUserLatencyInsights :many
 SELECT
 	workspace_agent_stats.user_id,
@@ -1966,32 +2841,104 @@ func (q *sqlQuerier) TryAcquireLock(ctx context.Context, pgTryAdvisoryXactLock i
 	return pg_try_advisory_xact_lock, err
 }
 
-const getOrganizationIDsByMemberIDs = `-- name: GetOrganizationIDsByMemberIDs :many
+const getOrganizationBudgetSettings = `-- name: GetOrganizationBudgetSettings :one
 SELECT
-    user_id, array_agg(organization_id) :: uuid [ ] AS "organization_IDs"
+	organization_id, created_at, updated_at, daily_budget, warning_threshold_percent, critical_threshold_percent, alerted_threshold_percent
 FROM
-    organization_members
+	organization_budget_settings
 WHERE
-    user_id = ANY($1 :: uuid [ ])
+	organization_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationBudgetSettings(ctx context.Context, organizationID uuid.UUID) (OrganizationBudgetSettings, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationBudgetSettings, organizationID)
+	var i OrganizationBudgetSettings
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DailyBudget,
+		&i.WarningThresholdPercent,
+		&i.CriticalThresholdPercent,
+		&i.AlertedThresholdPercent,
+	)
+	return i, err
+}
+
+const getOrganizationBudgetUsages = `-- name: GetOrganizationBudgetUsages :many
+WITH latest_builds AS (
+SELECT
+	DISTINCT ON
+	(workspace_id) id,
+	workspace_id,
+	daily_cost
+FROM
+	workspace_builds wb
+ORDER BY
+	workspace_id,
+	created_at DESC
+),
+consumed AS (
+SELECT
+	workspaces.organization_id,
+	SUM(latest_builds.daily_cost) AS credits_consumed
+FROM
+	workspaces
+JOIN latest_builds ON
+	latest_builds.workspace_id = workspaces.id
+WHERE
+	NOT workspaces.deleted
 GROUP BY
-    user_id
+	workspaces.organization_id
+)
+SELECT
+	organization_budget_settings.organization_id, organization_budget_settings.created_at, organization_budget_settings.updated_at, organization_budget_settings.daily_budget, organization_budget_settings.warning_threshold_percent, organization_budget_settings.critical_threshold_percent, organization_budget_settings.alerted_threshold_percent,
+	organizations.name AS organization_name,
+	coalesce(consumed.credits_consumed, 0)::BIGINT AS credits_consumed
+FROM
+	organization_budget_settings
+JOIN organizations ON
+	organizations.id = organization_budget_settings.organization_id
+LEFT JOIN consumed ON
+	consumed.organization_id = organization_budget_settings.organization_id
+WHERE
+	organization_budget_settings.daily_budget > 0
 `
 
-type GetOrganizationIDsByMemberIDsRow struct {
-	UserID          uuid.UUID   `db:"user_id" json:"user_id"`
-	OrganizationIDs []uuid.UUID `db:"organization_IDs" json:"organization_IDs"`
+type GetOrganizationBudgetUsagesRow struct {
+	OrganizationID           uuid.UUID `db:"organization_id" json:"organization_id"`
+	CreatedAt                time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt                time.Time `db:"updated_at" json:"updated_at"`
+	DailyBudget              int64     `db:"daily_budget" json:"daily_budget"`
+	WarningThresholdPercent  int32     `db:"warning_threshold_percent" json:"warning_threshold_percent"`
+	CriticalThresholdPercent int32     `db:"critical_threshold_percent" json:"critical_threshold_percent"`
+	AlertedThresholdPercent  int32     `db:"alerted_threshold_percent" json:"alerted_threshold_percent"`
+	OrganizationName         string    `db:"organization_name" json:"organization_name"`
+	CreditsConsumed          int64     `db:"credits_consumed" json:"credits_consumed"`
 }
 
-func (q *sqlQuerier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizationIDsByMemberIDs, pq.Array(ids))
+// Returns the budget settings of every organization with a budget together
+// with the daily cost of the latest builds of the organization's workspaces.
+func (q *sqlQuerier) GetOrganizationBudgetUsages(ctx context.Context) ([]GetOrganizationBudgetUsagesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationBudgetUsages)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetOrganizationIDsByMemberIDsRow
+	var items []GetOrganizationBudgetUsagesRow
 	for rows.Next() {
-		var i GetOrganizationIDsByMemberIDsRow
-		if err := rows.Scan(&i.UserID, pq.Array(&i.OrganizationIDs)); err != nil {
+		var i GetOrganizationBudgetUsagesRow
+		if err := rows.Scan(
+			&i.OrganizationID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DailyBudget,
+			&i.WarningThresholdPercent,
+			&i.CriticalThresholdPercent,
+			&i.AlertedThresholdPercent,
+			&i.OrganizationName,
+			&i.CreditsConsumed,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -2005,7 +2952,190 @@ func (q *sqlQuerier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uu
 	return items, nil
 }
 
-const getOrganizationMemberByUserID = `-- name: GetOrganizationMemberByUserID :one
+const updateOrganizationBudgetAlertedThreshold = `-- name: UpdateOrganizationBudgetAlertedThreshold :exec
+UPDATE
+	organization_budget_settings
+SET
+	alerted_threshold_percent = $2
+WHERE
+	organization_id = $1
+`
+
+type UpdateOrganizationBudgetAlertedThresholdParams struct {
+	OrganizationID          uuid.UUID `db:"organization_id" json:"organization_id"`
+	AlertedThresholdPercent int32     `db:"alerted_threshold_percent" json:"alerted_threshold_percent"`
+}
+
+func (q *sqlQuerier) UpdateOrganizationBudgetAlertedThreshold(ctx context.Context, arg UpdateOrganizationBudgetAlertedThresholdParams) error {
+	_, err := q.db.ExecContext(ctx, updateOrganizationBudgetAlertedThreshold, arg.OrganizationID, arg.AlertedThresholdPercent)
+	return err
+}
+
+const upsertOrganizationBudgetSettings = `-- name: UpsertOrganizationBudgetSettings :one
+INSERT INTO
+	organization_budget_settings (
+		organization_id,
+		created_at,
+		updated_at,
+		daily_budget,
+		warning_threshold_percent,
+		critical_threshold_percent
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+ON CONFLICT
+	(organization_id)
+DO UPDATE SET
+	updated_at = $3,
+	daily_budget = $4,
+	warning_threshold_percent = $5,
+	critical_threshold_percent = $6,
+	alerted_threshold_percent = 0
+RETURNING organization_id, created_at, updated_at, daily_budget, warning_threshold_percent, critical_threshold_percent, alerted_threshold_percent
+`
+
+type UpsertOrganizationBudgetSettingsParams struct {
+	OrganizationID           uuid.UUID `db:"organization_id" json:"organization_id"`
+	CreatedAt                time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt                time.Time `db:"updated_at" json:"updated_at"`
+	DailyBudget              int64     `db:"daily_budget" json:"daily_budget"`
+	WarningThresholdPercent  int32     `db:"warning_threshold_percent" json:"warning_threshold_percent"`
+	CriticalThresholdPercent int32     `db:"critical_threshold_percent" json:"critical_threshold_percent"`
+}
+
+// Changing the settings resets the alerted threshold, so alerts are sent again
+// for thresholds that are still crossed with the new settings.
+func (q *sqlQuerier) UpsertOrganizationBudgetSettings(ctx context.Context, arg UpsertOrganizationBudgetSettingsParams) (OrganizationBudgetSettings, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrganizationBudgetSettings,
+		arg.OrganizationID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.DailyBudget,
+		arg.WarningThresholdPercent,
+		arg.CriticalThresholdPercent,
+	)
+	var i OrganizationBudgetSettings
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DailyBudget,
+		&i.WarningThresholdPercent,
+		&i.CriticalThresholdPercent,
+		&i.AlertedThresholdPercent,
+	)
+	return i, err
+}
+
+const getOrganizationJITProvisioningSettings = `-- name: GetOrganizationJITProvisioningSettings :one
+SELECT
+	organization_id, created_at, updated_at, template_id, workspace_name
+FROM
+	organization_jit_provisioning_settings
+WHERE
+	organization_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationJITProvisioningSettings(ctx context.Context, organizationID uuid.UUID) (OrganizationJITProvisioningSettings, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationJITProvisioningSettings, organizationID)
+	var i OrganizationJITProvisioningSettings
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TemplateID,
+		&i.WorkspaceName,
+	)
+	return i, err
+}
+
+const upsertOrganizationJITProvisioningSettings = `-- name: UpsertOrganizationJITProvisioningSettings :one
+INSERT INTO
+	organization_jit_provisioning_settings (
+		organization_id,
+		created_at,
+		updated_at,
+		template_id,
+		workspace_name
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(organization_id)
+DO UPDATE SET
+	updated_at = $3,
+	template_id = $4,
+	workspace_name = $5
+RETURNING organization_id, created_at, updated_at, template_id, workspace_name
+`
+
+type UpsertOrganizationJITProvisioningSettingsParams struct {
+	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
+	TemplateID     uuid.NullUUID `db:"template_id" json:"template_id"`
+	WorkspaceName  string        `db:"workspace_name" json:"workspace_name"`
+}
+
+func (q *sqlQuerier) UpsertOrganizationJITProvisioningSettings(ctx context.Context, arg UpsertOrganizationJITProvisioningSettingsParams) (OrganizationJITProvisioningSettings, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrganizationJITProvisioningSettings,
+		arg.OrganizationID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.TemplateID,
+		arg.WorkspaceName,
+	)
+	var i OrganizationJITProvisioningSettings
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.TemplateID,
+		&i.WorkspaceName,
+	)
+	return i, err
+}
+
+const getOrganizationIDsByMemberIDs = `-- name: GetOrganizationIDsByMemberIDs :many
+SELECT
+    user_id, array_agg(organization_id) :: uuid [ ] AS "organization_IDs"
+FROM
+    organization_members
+WHERE
+    user_id = ANY($1 :: uuid [ ])
+GROUP BY
+    user_id
+`
+
+type GetOrganizationIDsByMemberIDsRow struct {
+	UserID          uuid.UUID   `db:"user_id" json:"user_id"`
+	OrganizationIDs []uuid.UUID `db:"organization_IDs" json:"organization_IDs"`
+}
+
+func (q *sqlQuerier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationIDsByMemberIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrganizationIDsByMemberIDsRow
+	for rows.Next() {
+		var i GetOrganizationIDsByMemberIDsRow
+		if err := rows.Scan(&i.UserID, pq.Array(&i.OrganizationIDs)); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrganizationMemberByUserID = `-- name: GetOrganizationMemberByUserID :one
 SELECT
 	user_id, organization_id, created_at, updated_at, roles
 FROM
@@ -2144,6 +3274,69 @@ func (q *sqlQuerier) UpdateMemberRoles(ctx context.Context, arg UpdateMemberRole
 	return i, err
 }
 
+const getOrganizationProvisionerSettings = `-- name: GetOrganizationProvisionerSettings :one
+SELECT
+	organization_id, created_at, updated_at, max_concurrent_jobs
+FROM
+	organization_provisioner_settings
+WHERE
+	organization_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationProvisionerSettings(ctx context.Context, organizationID uuid.UUID) (OrganizationProvisionerSettings, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationProvisionerSettings, organizationID)
+	var i OrganizationProvisionerSettings
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MaxConcurrentJobs,
+	)
+	return i, err
+}
+
+const upsertOrganizationProvisionerSettings = `-- name: UpsertOrganizationProvisionerSettings :one
+INSERT INTO
+	organization_provisioner_settings (
+		organization_id,
+		created_at,
+		updated_at,
+		max_concurrent_jobs
+	)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT
+	(organization_id)
+DO UPDATE SET
+	updated_at = $3,
+	max_concurrent_jobs = $4
+RETURNING organization_id, created_at, updated_at, max_concurrent_jobs
+`
+
+type UpsertOrganizationProvisionerSettingsParams struct {
+	OrganizationID    uuid.UUID `db:"organization_id" json:"organization_id"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	MaxConcurrentJobs int32     `db:"max_concurrent_jobs" json:"max_concurrent_jobs"`
+}
+
+func (q *sqlQuerier) UpsertOrganizationProvisionerSettings(ctx context.Context, arg UpsertOrganizationProvisionerSettingsParams) (OrganizationProvisionerSettings, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrganizationProvisionerSettings,
+		arg.OrganizationID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.MaxConcurrentJobs,
+	)
+	var i OrganizationProvisionerSettings
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.MaxConcurrentJobs,
+	)
+	return i, err
+}
+
 const getOrganizationByID = `-- name: GetOrganizationByID :one
 SELECT
 	id, name, description, created_at, updated_at
@@ -2305,44 +3498,131 @@ func (q *sqlQuerier) InsertOrganization(ctx context.Context, arg InsertOrganizat
 	return i, err
 }
 
-const getParameterSchemasByJobID = `-- name: GetParameterSchemasByJobID :many
+const getOrganizationScheduleSettings = `-- name: GetOrganizationScheduleSettings :one
 SELECT
-	id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
+	organization_id, created_at, updated_at, default_ttl, restart_requirement_days_of_week, restart_requirement_weeks, default_quiet_hours_schedule
 FROM
-	parameter_schemas
+	organization_schedule_settings
 WHERE
-	job_id = $1
+	organization_id = $1
+`
+
+func (q *sqlQuerier) GetOrganizationScheduleSettings(ctx context.Context, organizationID uuid.UUID) (OrganizationScheduleSettings, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationScheduleSettings, organizationID)
+	var i OrganizationScheduleSettings
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DefaultTTL,
+		&i.RestartRequirementDaysOfWeek,
+		&i.RestartRequirementWeeks,
+		&i.DefaultQuietHoursSchedule,
+	)
+	return i, err
+}
+
+const upsertOrganizationScheduleSettings = `-- name: UpsertOrganizationScheduleSettings :one
+INSERT INTO
+	organization_schedule_settings (
+		organization_id,
+		created_at,
+		updated_at,
+		default_ttl,
+		restart_requirement_days_of_week,
+		restart_requirement_weeks,
+		default_quiet_hours_schedule
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT
+	(organization_id)
+DO UPDATE SET
+	updated_at = $3,
+	default_ttl = $4,
+	restart_requirement_days_of_week = $5,
+	restart_requirement_weeks = $6,
+	default_quiet_hours_schedule = $7
+RETURNING organization_id, created_at, updated_at, default_ttl, restart_requirement_days_of_week, restart_requirement_weeks, default_quiet_hours_schedule
+`
+
+type UpsertOrganizationScheduleSettingsParams struct {
+	OrganizationID               uuid.UUID `db:"organization_id" json:"organization_id"`
+	CreatedAt                    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt                    time.Time `db:"updated_at" json:"updated_at"`
+	DefaultTTL                   int64     `db:"default_ttl" json:"default_ttl"`
+	RestartRequirementDaysOfWeek int16     `db:"restart_requirement_days_of_week" json:"restart_requirement_days_of_week"`
+	RestartRequirementWeeks      int64     `db:"restart_requirement_weeks" json:"restart_requirement_weeks"`
+	DefaultQuietHoursSchedule    string    `db:"default_quiet_hours_schedule" json:"default_quiet_hours_schedule"`
+}
+
+func (q *sqlQuerier) UpsertOrganizationScheduleSettings(ctx context.Context, arg UpsertOrganizationScheduleSettingsParams) (OrganizationScheduleSettings, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrganizationScheduleSettings,
+		arg.OrganizationID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.DefaultTTL,
+		arg.RestartRequirementDaysOfWeek,
+		arg.RestartRequirementWeeks,
+		arg.DefaultQuietHoursSchedule,
+	)
+	var i OrganizationScheduleSettings
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DefaultTTL,
+		&i.RestartRequirementDaysOfWeek,
+		&i.RestartRequirementWeeks,
+		&i.DefaultQuietHoursSchedule,
+	)
+	return i, err
+}
+
+const deleteOrganizationTemplateVariable = `-- name: DeleteOrganizationTemplateVariable :exec
+DELETE FROM
+	organization_template_variables
+WHERE
+	organization_id = $1 AND name = $2
+`
+
+type DeleteOrganizationTemplateVariableParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) DeleteOrganizationTemplateVariable(ctx context.Context, arg DeleteOrganizationTemplateVariableParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOrganizationTemplateVariable, arg.OrganizationID, arg.Name)
+	return err
+}
+
+const getOrganizationTemplateVariables = `-- name: GetOrganizationTemplateVariables :many
+SELECT
+	organization_id, name, value, sensitive, created_at, updated_at
+FROM
+	organization_template_variables
+WHERE
+	organization_id = $1
 ORDER BY
-	index
+	name ASC
 `
 
-func (q *sqlQuerier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUID) ([]ParameterSchema, error) {
-	rows, err := q.db.QueryContext(ctx, getParameterSchemasByJobID, jobID)
+func (q *sqlQuerier) GetOrganizationTemplateVariables(ctx context.Context, organizationID uuid.UUID) ([]OrganizationTemplateVariable, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationTemplateVariables, organizationID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ParameterSchema
+	var items []OrganizationTemplateVariable
 	for rows.Next() {
-		var i ParameterSchema
+		var i OrganizationTemplateVariable
 		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
-			&i.JobID,
+			&i.OrganizationID,
 			&i.Name,
-			&i.Description,
-			&i.DefaultSourceScheme,
-			&i.DefaultSourceValue,
-			&i.AllowOverrideSource,
-			&i.DefaultDestinationScheme,
-			&i.AllowOverrideDestination,
-			&i.DefaultRefresh,
-			&i.RedisplayValue,
-			&i.ValidationError,
-			&i.ValidationCondition,
-			&i.ValidationTypeSystem,
-			&i.ValidationValueType,
-			&i.Index,
+			&i.Value,
+			&i.Sensitive,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -2357,9 +3637,390 @@ func (q *sqlQuerier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.
 	return items, nil
 }
 
-const getProvisionerDaemons = `-- name: GetProvisionerDaemons :many
+const upsertOrganizationTemplateVariable = `-- name: UpsertOrganizationTemplateVariable :one
+INSERT INTO
+	organization_template_variables (
+		organization_id,
+		name,
+		value,
+		sensitive,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+ON CONFLICT
+	(organization_id, name)
+DO UPDATE SET
+	value = $3,
+	sensitive = $4,
+	updated_at = $6
+RETURNING organization_id, name, value, sensitive, created_at, updated_at
+`
+
+type UpsertOrganizationTemplateVariableParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+	Value          string    `db:"value" json:"value"`
+	Sensitive      bool      `db:"sensitive" json:"sensitive"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertOrganizationTemplateVariable(ctx context.Context, arg UpsertOrganizationTemplateVariableParams) (OrganizationTemplateVariable, error) {
+	row := q.db.QueryRowContext(ctx, upsertOrganizationTemplateVariable,
+		arg.OrganizationID,
+		arg.Name,
+		arg.Value,
+		arg.Sensitive,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i OrganizationTemplateVariable
+	err := row.Scan(
+		&i.OrganizationID,
+		&i.Name,
+		&i.Value,
+		&i.Sensitive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getParameterSchemasByJobID = `-- name: GetParameterSchemasByJobID :many
+SELECT
+	id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
+FROM
+	parameter_schemas
+WHERE
+	job_id = $1
+ORDER BY
+	index
+`
+
+func (q *sqlQuerier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUID) ([]ParameterSchema, error) {
+	rows, err := q.db.QueryContext(ctx, getParameterSchemasByJobID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ParameterSchema
+	for rows.Next() {
+		var i ParameterSchema
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.JobID,
+			&i.Name,
+			&i.Description,
+			&i.DefaultSourceScheme,
+			&i.DefaultSourceValue,
+			&i.AllowOverrideSource,
+			&i.DefaultDestinationScheme,
+			&i.AllowOverrideDestination,
+			&i.DefaultRefresh,
+			&i.RedisplayValue,
+			&i.ValidationError,
+			&i.ValidationCondition,
+			&i.ValidationTypeSystem,
+			&i.ValidationValueType,
+			&i.Index,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimPrebuiltWorkspace = `-- name: ClaimPrebuiltWorkspace :one
+UPDATE
+	workspaces
+SET
+	owner_id = $1,
+	name = $2,
+	autostart_schedule = $3,
+	ttl = $4,
+	created_at = $5,
+	updated_at = $5,
+	last_used_at = $5
+WHERE
+	id = (
+		SELECT
+			workspaces.id
+		FROM
+			workspaces
+		JOIN
+			templates
+		ON
+			templates.id = workspaces.template_id
+		JOIN LATERAL (
+			SELECT
+				workspace_builds.template_version_id,
+				workspace_builds.transition,
+				provisioner_jobs.canceled_at,
+				provisioner_jobs.completed_at,
+				provisioner_jobs.error
+			FROM
+				workspace_builds
+			JOIN
+				provisioner_jobs
+			ON
+				provisioner_jobs.id = workspace_builds.job_id
+			WHERE
+				workspace_builds.workspace_id = workspaces.id
+			ORDER BY
+				build_number DESC
+			LIMIT
+				1
+		) latest_build ON TRUE
+		WHERE
+			workspaces.owner_id = $6
+			AND workspaces.template_id = $7
+			AND workspaces.deleted = false
+			AND latest_build.template_version_id = templates.active_version_id
+			AND latest_build.transition = 'stop'::workspace_transition
+			AND latest_build.completed_at IS NOT NULL
+			AND latest_build.canceled_at IS NULL
+			AND latest_build.error IS NULL
+		ORDER BY
+			workspaces.created_at ASC
+		LIMIT
+			1
+		FOR UPDATE OF workspaces SKIP LOCKED
+	)
+RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at, group_acl, lock_reason
+`
+
+type ClaimPrebuiltWorkspaceParams struct {
+	NewOwnerID        uuid.UUID      `db:"new_owner_id" json:"new_owner_id"`
+	Name              string         `db:"name" json:"name"`
+	AutostartSchedule sql.NullString `db:"autostart_schedule" json:"autostart_schedule"`
+	Ttl               sql.NullInt64  `db:"ttl" json:"ttl"`
+	Now               time.Time      `db:"now" json:"now"`
+	PrebuildsOwnerID  uuid.UUID      `db:"prebuilds_owner_id" json:"prebuilds_owner_id"`
+	TemplateID        uuid.UUID      `db:"template_id" json:"template_id"`
+}
+
+// Assigns the oldest prebuilt workspace of the template that is ready to a
+// user. Prebuilt workspaces are ready once they have been stopped
+// successfully on the active version of their template. Returns no rows if
+// there is no such workspace.
+func (q *sqlQuerier) ClaimPrebuiltWorkspace(ctx context.Context, arg ClaimPrebuiltWorkspaceParams) (Workspace, error) {
+	row := q.db.QueryRowContext(ctx, claimPrebuiltWorkspace,
+		arg.NewOwnerID,
+		arg.Name,
+		arg.AutostartSchedule,
+		arg.Ttl,
+		arg.Now,
+		arg.PrebuildsOwnerID,
+		arg.TemplateID,
+	)
+	var i Workspace
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerID,
+		&i.OrganizationID,
+		&i.TemplateID,
+		&i.Deleted,
+		&i.Name,
+		&i.AutostartSchedule,
+		&i.Ttl,
+		&i.LastUsedAt,
+		&i.LockedAt,
+		&i.DeletingAt,
+		&i.GroupACL,
+		&i.LockReason,
+	)
+	return i, err
+}
+
+const getPrebuiltWorkspaces = `-- name: GetPrebuiltWorkspaces :many
+SELECT
+	workspaces.id,
+	workspaces.created_at,
+	workspaces.template_id,
+	latest_build.template_version_id,
+	latest_build.transition,
+	latest_build.started_at,
+	latest_build.canceled_at,
+	latest_build.completed_at,
+	latest_build.error
+FROM
+	workspaces
+JOIN LATERAL (
+	SELECT
+		workspace_builds.template_version_id,
+		workspace_builds.transition,
+		provisioner_jobs.started_at,
+		provisioner_jobs.canceled_at,
+		provisioner_jobs.completed_at,
+		provisioner_jobs.error
+	FROM
+		workspace_builds
+	JOIN
+		provisioner_jobs
+	ON
+		provisioner_jobs.id = workspace_builds.job_id
+	WHERE
+		workspace_builds.workspace_id = workspaces.id
+	ORDER BY
+		build_number DESC
+	LIMIT
+		1
+) latest_build ON TRUE
+WHERE
+	workspaces.owner_id = $1
+	AND workspaces.deleted = false
+ORDER BY
+	workspaces.created_at ASC
+`
+
+type GetPrebuiltWorkspacesRow struct {
+	ID                uuid.UUID           `db:"id" json:"id"`
+	CreatedAt         time.Time           `db:"created_at" json:"created_at"`
+	TemplateID        uuid.UUID           `db:"template_id" json:"template_id"`
+	TemplateVersionID uuid.UUID           `db:"template_version_id" json:"template_version_id"`
+	Transition        WorkspaceTransition `db:"transition" json:"transition"`
+	StartedAt         sql.NullTime        `db:"started_at" json:"started_at"`
+	CanceledAt        sql.NullTime        `db:"canceled_at" json:"canceled_at"`
+	CompletedAt       sql.NullTime        `db:"completed_at" json:"completed_at"`
+	Error             sql.NullString      `db:"error" json:"error"`
+}
+
+// Returns the prebuilt workspaces that aren't deleted with the status of
+// their latest build. Prebuilt workspaces are owned by @owner_id until they
+// are claimed.
+func (q *sqlQuerier) GetPrebuiltWorkspaces(ctx context.Context, ownerID uuid.UUID) ([]GetPrebuiltWorkspacesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPrebuiltWorkspaces, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPrebuiltWorkspacesRow
+	for rows.Next() {
+		var i GetPrebuiltWorkspacesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.TemplateID,
+			&i.TemplateVersionID,
+			&i.Transition,
+			&i.StartedAt,
+			&i.CanceledAt,
+			&i.CompletedAt,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplatePrebuildPool = `-- name: GetTemplatePrebuildPool :one
+SELECT
+	template_id, size, updated_at
+FROM
+	template_prebuild_pools
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) GetTemplatePrebuildPool(ctx context.Context, templateID uuid.UUID) (TemplatePrebuildPool, error) {
+	row := q.db.QueryRowContext(ctx, getTemplatePrebuildPool, templateID)
+	var i TemplatePrebuildPool
+	err := row.Scan(&i.TemplateID, &i.Size, &i.UpdatedAt)
+	return i, err
+}
+
+const getTemplatePrebuildPools = `-- name: GetTemplatePrebuildPools :many
+SELECT
+	template_prebuild_pools.template_id, template_prebuild_pools.size, template_prebuild_pools.updated_at
+FROM
+	template_prebuild_pools
+JOIN
+	templates
+ON
+	templates.id = template_prebuild_pools.template_id
+WHERE
+	templates.deleted = false
+	AND template_prebuild_pools.size > 0
+`
+
+// Returns the pools of all templates that aren't deleted and keep at least one
+// prebuilt workspace.
+func (q *sqlQuerier) GetTemplatePrebuildPools(ctx context.Context) ([]TemplatePrebuildPool, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplatePrebuildPools)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplatePrebuildPool
+	for rows.Next() {
+		var i TemplatePrebuildPool
+		if err := rows.Scan(&i.TemplateID, &i.Size, &i.UpdatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertTemplatePrebuildPool = `-- name: UpsertTemplatePrebuildPool :one
+INSERT INTO
+	template_prebuild_pools (
+		template_id,
+		size,
+		updated_at
+	)
+VALUES
+	($1, $2, $3)
+ON CONFLICT
+	(template_id)
+DO UPDATE SET
+	size = $2,
+	updated_at = $3
+RETURNING template_id, size, updated_at
+`
+
+type UpsertTemplatePrebuildPoolParams struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	Size       int32     `db:"size" json:"size"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertTemplatePrebuildPool(ctx context.Context, arg UpsertTemplatePrebuildPoolParams) (TemplatePrebuildPool, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplatePrebuildPool, arg.TemplateID, arg.Size, arg.UpdatedAt)
+	var i TemplatePrebuildPool
+	err := row.Scan(&i.TemplateID, &i.Size, &i.UpdatedAt)
+	return i, err
+}
+
+const getProvisionerDaemons = `-- name: GetProvisionerDaemons :many
 SELECT
-	id, created_at, updated_at, name, provisioners, replica_id, tags
+	id, created_at, updated_at, name, provisioners, replica_id, tags, cache_entries, cache_size_bytes, cache_max_size_bytes, cache_hits, cache_misses
 FROM
 	provisioner_daemons
 `
@@ -2378,9 +4039,14 @@ func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDa
 			&i.CreatedAt,
 			&i.UpdatedAt,
 			&i.Name,
-			pq.Array(&i.Provisioners),
+			&i.Provisioners,
 			&i.ReplicaID,
 			&i.Tags,
+			&i.CacheEntries,
+			&i.CacheSizeBytes,
+			&i.CacheMaxSizeBytes,
+			&i.CacheHits,
+			&i.CacheMisses,
 		); err != nil {
 			return nil, err
 		}
@@ -2405,15 +4071,15 @@ INSERT INTO
 		tags
 	)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at, name, provisioners, replica_id, tags
+	($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at, name, provisioners, replica_id, tags, cache_entries, cache_size_bytes, cache_max_size_bytes, cache_hits, cache_misses
 `
 
 type InsertProvisionerDaemonParams struct {
-	ID           uuid.UUID         `db:"id" json:"id"`
-	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
-	Name         string            `db:"name" json:"name"`
-	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
-	Tags         StringMap         `db:"tags" json:"tags"`
+	ID           uuid.UUID        `db:"id" json:"id"`
+	CreatedAt    time.Time        `db:"created_at" json:"created_at"`
+	Name         string           `db:"name" json:"name"`
+	Provisioners ProvisionerTypes `db:"provisioners" json:"provisioners"`
+	Tags         StringMap        `db:"tags" json:"tags"`
 }
 
 func (q *sqlQuerier) InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error) {
@@ -2421,7 +4087,7 @@ func (q *sqlQuerier) InsertProvisionerDaemon(ctx context.Context, arg InsertProv
 		arg.ID,
 		arg.CreatedAt,
 		arg.Name,
-		pq.Array(arg.Provisioners),
+		arg.Provisioners,
 		arg.Tags,
 	)
 	var i ProvisionerDaemon
@@ -2430,16 +4096,58 @@ func (q *sqlQuerier) InsertProvisionerDaemon(ctx context.Context, arg InsertProv
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.Name,
-		pq.Array(&i.Provisioners),
+		&i.Provisioners,
 		&i.ReplicaID,
 		&i.Tags,
+		&i.CacheEntries,
+		&i.CacheSizeBytes,
+		&i.CacheMaxSizeBytes,
+		&i.CacheHits,
+		&i.CacheMisses,
 	)
 	return i, err
 }
 
+const updateProvisionerDaemonCacheStats = `-- name: UpdateProvisionerDaemonCacheStats :exec
+UPDATE
+	provisioner_daemons
+SET
+	updated_at = $1,
+	cache_entries = $2,
+	cache_size_bytes = $3,
+	cache_max_size_bytes = $4,
+	cache_hits = $5,
+	cache_misses = $6
+WHERE
+	id = $7
+`
+
+type UpdateProvisionerDaemonCacheStatsParams struct {
+	UpdatedAt         sql.NullTime `db:"updated_at" json:"updated_at"`
+	CacheEntries      int32        `db:"cache_entries" json:"cache_entries"`
+	CacheSizeBytes    int64        `db:"cache_size_bytes" json:"cache_size_bytes"`
+	CacheMaxSizeBytes int64        `db:"cache_max_size_bytes" json:"cache_max_size_bytes"`
+	CacheHits         int64        `db:"cache_hits" json:"cache_hits"`
+	CacheMisses       int64        `db:"cache_misses" json:"cache_misses"`
+	ID                uuid.UUID    `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerDaemonCacheStats(ctx context.Context, arg UpdateProvisionerDaemonCacheStatsParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerDaemonCacheStats,
+		arg.UpdatedAt,
+		arg.CacheEntries,
+		arg.CacheSizeBytes,
+		arg.CacheMaxSizeBytes,
+		arg.CacheHits,
+		arg.CacheMisses,
+		arg.ID,
+	)
+	return err
+}
+
 const getProvisionerLogsAfterID = `-- name: GetProvisionerLogsAfterID :many
 SELECT
-	job_id, created_at, source, level, stage, output, id
+	job_id, created_at, source, level, stage, output, id, progress_stage, progress_resource, progress_completed, progress_total
 FROM
 	provisioner_job_logs
 WHERE
@@ -2471,6 +4179,10 @@ func (q *sqlQuerier) GetProvisionerLogsAfterID(ctx context.Context, arg GetProvi
 			&i.Stage,
 			&i.Output,
 			&i.ID,
+			&i.ProgressStage,
+			&i.ProgressResource,
+			&i.ProgressCompleted,
+			&i.ProgressTotal,
 		); err != nil {
 			return nil, err
 		}
@@ -2487,23 +4199,31 @@ func (q *sqlQuerier) GetProvisionerLogsAfterID(ctx context.Context, arg GetProvi
 
 const insertProvisionerJobLogs = `-- name: InsertProvisionerJobLogs :many
 INSERT INTO
-	provisioner_job_logs
+	provisioner_job_logs (job_id, created_at, source, level, stage, output, progress_stage, progress_resource, progress_completed, progress_total)
 SELECT
 	$1 :: uuid AS job_id,
 	unnest($2 :: timestamptz [ ]) AS created_at,
 	unnest($3 :: log_source [ ]) AS source,
 	unnest($4 :: log_level [ ]) AS LEVEL,
 	unnest($5 :: VARCHAR(128) [ ]) AS stage,
-	unnest($6 :: VARCHAR(1024) [ ]) AS output RETURNING job_id, created_at, source, level, stage, output, id
+	unnest($6 :: VARCHAR(1024) [ ]) AS output,
+	unnest($7 :: text [ ]) AS progress_stage,
+	unnest($8 :: text [ ]) AS progress_resource,
+	unnest($9 :: integer [ ]) AS progress_completed,
+	unnest($10 :: integer [ ]) AS progress_total RETURNING job_id, created_at, source, level, stage, output, id, progress_stage, progress_resource, progress_completed, progress_total
 `
 
 type InsertProvisionerJobLogsParams struct {
-	JobID     uuid.UUID   `db:"job_id" json:"job_id"`
-	CreatedAt []time.Time `db:"created_at" json:"created_at"`
-	Source    []LogSource `db:"source" json:"source"`
-	Level     []LogLevel  `db:"level" json:"level"`
-	Stage     []string    `db:"stage" json:"stage"`
-	Output    []string    `db:"output" json:"output"`
+	JobID             uuid.UUID   `db:"job_id" json:"job_id"`
+	CreatedAt         []time.Time `db:"created_at" json:"created_at"`
+	Source            []LogSource `db:"source" json:"source"`
+	Level             []LogLevel  `db:"level" json:"level"`
+	Stage             []string    `db:"stage" json:"stage"`
+	Output            []string    `db:"output" json:"output"`
+	ProgressStage     []string    `db:"progress_stage" json:"progress_stage"`
+	ProgressResource  []string    `db:"progress_resource" json:"progress_resource"`
+	ProgressCompleted []int32     `db:"progress_completed" json:"progress_completed"`
+	ProgressTotal     []int32     `db:"progress_total" json:"progress_total"`
 }
 
 func (q *sqlQuerier) InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error) {
@@ -2514,6 +4234,10 @@ func (q *sqlQuerier) InsertProvisionerJobLogs(ctx context.Context, arg InsertPro
 		pq.Array(arg.Level),
 		pq.Array(arg.Stage),
 		pq.Array(arg.Output),
+		pq.Array(arg.ProgressStage),
+		pq.Array(arg.ProgressResource),
+		pq.Array(arg.ProgressCompleted),
+		pq.Array(arg.ProgressTotal),
 	)
 	if err != nil {
 		return nil, err
@@ -2530,6 +4254,10 @@ func (q *sqlQuerier) InsertProvisionerJobLogs(ctx context.Context, arg InsertPro
 			&i.Stage,
 			&i.Output,
 			&i.ID,
+			&i.ProgressStage,
+			&i.ProgressResource,
+			&i.ProgressCompleted,
+			&i.ProgressTotal,
 		); err != nil {
 			return nil, err
 		}
@@ -2560,27 +4288,90 @@ WHERE
 		WHERE
 			nested.started_at IS NULL
 			-- Ensure the caller has the correct provisioner.
-			AND nested.provisioner = ANY($3 :: provisioner_type [ ])
+			AND nested.provisioner = ANY($3 :: text [ ])
 			-- Ensure the caller satisfies all job tags.
 			AND nested.tags <@ $4 :: jsonb
+			-- Ensure the job belongs to the organization of the caller, if the
+			-- caller is scoped to one.
+			AND (
+				$5 :: uuid = '00000000-0000-0000-0000-000000000000' :: uuid
+				OR nested.organization_id = $5
+			)
+			-- Ensure the job isn't waiting for workspace agents to drain.
+			AND (nested.not_before IS NULL OR nested.not_before <= $1)
+			-- Ensure the organization isn't running its maximum number of
+			-- concurrent jobs.
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					organization_provisioner_settings AS settings
+				WHERE
+					settings.organization_id = nested.organization_id
+					AND settings.max_concurrent_jobs > 0
+					AND settings.max_concurrent_jobs <= (
+						SELECT
+							COUNT(*)
+						FROM
+							provisioner_jobs AS running
+						WHERE
+							running.organization_id = nested.organization_id
+							AND running.started_at IS NOT NULL
+							AND running.completed_at IS NULL
+					)
+			)
+			-- Ensure the template of a workspace build isn't running its
+			-- maximum number of concurrent builds.
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					workspace_builds
+				INNER JOIN
+					workspaces ON workspaces.id = workspace_builds.workspace_id
+				INNER JOIN
+					templates ON templates.id = workspaces.template_id
+				WHERE
+					workspace_builds.job_id = nested.id
+					AND templates.max_concurrent_builds > 0
+					AND templates.max_concurrent_builds <= (
+						SELECT
+							COUNT(*)
+						FROM
+							provisioner_jobs AS running
+						INNER JOIN
+							workspace_builds AS running_builds ON running_builds.job_id = running.id
+						INNER JOIN
+							workspaces AS running_workspaces ON running_workspaces.id = running_builds.workspace_id
+						WHERE
+							running_workspaces.template_id = templates.id
+							AND running.started_at IS NOT NULL
+							AND running.completed_at IS NULL
+					)
+			)
 		ORDER BY
+			nested.priority DESC,
 			nested.created_at
 		FOR UPDATE
 		SKIP LOCKED
 		LIMIT
 			1
-	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
+	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, priority, not_before
 `
 
 type AcquireProvisionerJobParams struct {
-	StartedAt sql.NullTime      `db:"started_at" json:"started_at"`
-	WorkerID  uuid.NullUUID     `db:"worker_id" json:"worker_id"`
-	Types     []ProvisionerType `db:"types" json:"types"`
-	Tags      json.RawMessage   `db:"tags" json:"tags"`
+	StartedAt      sql.NullTime    `db:"started_at" json:"started_at"`
+	WorkerID       uuid.NullUUID   `db:"worker_id" json:"worker_id"`
+	Types          []string        `db:"types" json:"types"`
+	Tags           json.RawMessage `db:"tags" json:"tags"`
+	OrganizationID uuid.UUID       `db:"organization_id" json:"organization_id"`
 }
 
 // Acquires the lock for a single job that isn't started, completed,
-// canceled, and that matches an array of provisioner types.
+// canceled, and that matches an array of provisioner types. Jobs of
+// organizations and templates that are running their maximum number of
+// concurrent jobs are skipped until one of their jobs completes. Jobs are
+// skipped until their not_before time.
 //
 // SKIP LOCKED is used to jump over locked rows. This prevents
 // multiple provisioners from acquiring the same jobs. See:
@@ -2591,6 +4382,7 @@ func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvi
 		arg.WorkerID,
 		pq.Array(arg.Types),
 		arg.Tags,
+		arg.OrganizationID,
 	)
 	var i ProvisionerJob
 	err := row.Scan(
@@ -2612,13 +4404,15 @@ func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvi
 		&i.Tags,
 		&i.ErrorCode,
 		&i.TraceMetadata,
+		&i.Priority,
+		&i.NotBefore,
 	)
 	return i, err
 }
 
 const getHungProvisionerJobs = `-- name: GetHungProvisionerJobs :many
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, priority, not_before
 FROM
 	provisioner_jobs
 WHERE
@@ -2655,6 +4449,8 @@ func (q *sqlQuerier) GetHungProvisionerJobs(ctx context.Context, updatedAt time.
 			&i.Tags,
 			&i.ErrorCode,
 			&i.TraceMetadata,
+			&i.Priority,
+			&i.NotBefore,
 		); err != nil {
 			return nil, err
 		}
@@ -2671,7 +4467,7 @@ func (q *sqlQuerier) GetHungProvisionerJobs(ctx context.Context, updatedAt time.
 
 const getProvisionerJobByID = `-- name: GetProvisionerJobByID :one
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, priority, not_before
 FROM
 	provisioner_jobs
 WHERE
@@ -2682,31 +4478,105 @@ func (q *sqlQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (P
 	row := q.db.QueryRowContext(ctx, getProvisionerJobByID, id)
 	var i ProvisionerJob
 	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.StartedAt,
-		&i.CanceledAt,
-		&i.CompletedAt,
-		&i.Error,
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CanceledAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Provisioner,
+		&i.StorageMethod,
+		&i.Type,
+		&i.Input,
+		&i.WorkerID,
+		&i.FileID,
+		&i.Tags,
+		&i.ErrorCode,
+		&i.TraceMetadata,
+		&i.Priority,
+		&i.NotBefore,
+	)
+	return i, err
+}
+
+const getProvisionerJobConcurrency = `-- name: GetProvisionerJobConcurrency :one
+SELECT
+	provisioner_jobs.organization_id,
+	coalesce(settings.max_concurrent_jobs, 0) :: integer AS organization_max_concurrent_jobs,
+	(
+		SELECT
+			COUNT(*)
+		FROM
+			provisioner_jobs AS running
+		WHERE
+			running.organization_id = provisioner_jobs.organization_id
+			AND running.id != provisioner_jobs.id
+			AND running.started_at IS NOT NULL
+			AND running.completed_at IS NULL
+	) AS organization_running_jobs,
+	coalesce(templates.id, '00000000-0000-0000-0000-000000000000' :: uuid) :: uuid AS template_id,
+	coalesce(templates.max_concurrent_builds, 0) :: integer AS template_max_concurrent_builds,
+	(
+		SELECT
+			COUNT(*)
+		FROM
+			provisioner_jobs AS running
+		INNER JOIN
+			workspace_builds AS running_builds ON running_builds.job_id = running.id
+		INNER JOIN
+			workspaces AS running_workspaces ON running_workspaces.id = running_builds.workspace_id
+		WHERE
+			running_workspaces.template_id = templates.id
+			AND running.id != provisioner_jobs.id
+			AND running.started_at IS NOT NULL
+			AND running.completed_at IS NULL
+	) AS template_running_builds
+FROM
+	provisioner_jobs
+LEFT JOIN
+	organization_provisioner_settings AS settings ON settings.organization_id = provisioner_jobs.organization_id
+LEFT JOIN
+	workspace_builds ON workspace_builds.job_id = provisioner_jobs.id
+LEFT JOIN
+	workspaces ON workspaces.id = workspace_builds.workspace_id
+LEFT JOIN
+	templates ON templates.id = workspaces.template_id
+WHERE
+	provisioner_jobs.id = $1
+`
+
+type GetProvisionerJobConcurrencyRow struct {
+	OrganizationID                uuid.UUID `db:"organization_id" json:"organization_id"`
+	OrganizationMaxConcurrentJobs int32     `db:"organization_max_concurrent_jobs" json:"organization_max_concurrent_jobs"`
+	OrganizationRunningJobs       int64     `db:"organization_running_jobs" json:"organization_running_jobs"`
+	TemplateID                    uuid.UUID `db:"template_id" json:"template_id"`
+	TemplateMaxConcurrentBuilds   int32     `db:"template_max_concurrent_builds" json:"template_max_concurrent_builds"`
+	TemplateRunningBuilds         int64     `db:"template_running_builds" json:"template_running_builds"`
+}
+
+// Returns the concurrency limits of the organization and template of a job,
+// and how many other jobs are running against each of them. A limit of zero
+// means the job isn't limited.
+func (q *sqlQuerier) GetProvisionerJobConcurrency(ctx context.Context, id uuid.UUID) (GetProvisionerJobConcurrencyRow, error) {
+	row := q.db.QueryRowContext(ctx, getProvisionerJobConcurrency, id)
+	var i GetProvisionerJobConcurrencyRow
+	err := row.Scan(
 		&i.OrganizationID,
-		&i.InitiatorID,
-		&i.Provisioner,
-		&i.StorageMethod,
-		&i.Type,
-		&i.Input,
-		&i.WorkerID,
-		&i.FileID,
-		&i.Tags,
-		&i.ErrorCode,
-		&i.TraceMetadata,
+		&i.OrganizationMaxConcurrentJobs,
+		&i.OrganizationRunningJobs,
+		&i.TemplateID,
+		&i.TemplateMaxConcurrentBuilds,
+		&i.TemplateRunningBuilds,
 	)
 	return i, err
 }
 
 const getProvisionerJobsByIDs = `-- name: GetProvisionerJobsByIDs :many
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, priority, not_before
 FROM
 	provisioner_jobs
 WHERE
@@ -2741,6 +4611,8 @@ func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUI
 			&i.Tags,
 			&i.ErrorCode,
 			&i.TraceMetadata,
+			&i.Priority,
+			&i.NotBefore,
 		); err != nil {
 			return nil, err
 		}
@@ -2758,7 +4630,7 @@ func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUI
 const getProvisionerJobsByIDsWithQueuePosition = `-- name: GetProvisionerJobsByIDsWithQueuePosition :many
 WITH unstarted_jobs AS (
     SELECT
-        id, created_at
+        id, created_at, priority
     FROM
         provisioner_jobs
     WHERE
@@ -2767,7 +4639,7 @@ WITH unstarted_jobs AS (
 queue_position AS (
     SELECT
         id,
-        ROW_NUMBER() OVER (ORDER BY created_at ASC) AS queue_position
+        ROW_NUMBER() OVER (ORDER BY priority DESC, created_at ASC) AS queue_position
     FROM
         unstarted_jobs
 ),
@@ -2775,7 +4647,7 @@ queue_size AS (
 	SELECT COUNT(*) as count FROM unstarted_jobs
 )
 SELECT
-	pj.id, pj.created_at, pj.updated_at, pj.started_at, pj.canceled_at, pj.completed_at, pj.error, pj.organization_id, pj.initiator_id, pj.provisioner, pj.storage_method, pj.type, pj.input, pj.worker_id, pj.file_id, pj.tags, pj.error_code, pj.trace_metadata,
+	pj.id, pj.created_at, pj.updated_at, pj.started_at, pj.canceled_at, pj.completed_at, pj.error, pj.organization_id, pj.initiator_id, pj.provisioner, pj.storage_method, pj.type, pj.input, pj.worker_id, pj.file_id, pj.tags, pj.error_code, pj.trace_metadata, pj.priority, pj.not_before,
     COALESCE(qp.queue_position, 0) AS queue_position,
     COALESCE(qs.count, 0) AS queue_size
 FROM
@@ -2822,6 +4694,8 @@ func (q *sqlQuerier) GetProvisionerJobsByIDsWithQueuePosition(ctx context.Contex
 			&i.ProvisionerJob.Tags,
 			&i.ProvisionerJob.ErrorCode,
 			&i.ProvisionerJob.TraceMetadata,
+			&i.ProvisionerJob.Priority,
+			&i.ProvisionerJob.NotBefore,
 			&i.QueuePosition,
 			&i.QueueSize,
 		); err != nil {
@@ -2839,7 +4713,7 @@ func (q *sqlQuerier) GetProvisionerJobsByIDsWithQueuePosition(ctx context.Contex
 }
 
 const getProvisionerJobsCreatedAfter = `-- name: GetProvisionerJobsCreatedAfter :many
-SELECT id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata FROM provisioner_jobs WHERE created_at > $1
+SELECT id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, priority, not_before FROM provisioner_jobs WHERE created_at > $1
 `
 
 func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error) {
@@ -2870,6 +4744,290 @@ func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, created
 			&i.Tags,
 			&i.ErrorCode,
 			&i.TraceMetadata,
+			&i.Priority,
+			&i.NotBefore,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertProvisionerJob = `-- name: InsertProvisionerJob :one
+INSERT INTO
+	provisioner_jobs (
+		id,
+		created_at,
+		updated_at,
+		organization_id,
+		initiator_id,
+		provisioner,
+		storage_method,
+		file_id,
+		"type",
+		"input",
+		tags,
+		trace_metadata,
+		priority,
+		not_before
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata, priority, not_before
+`
+
+type InsertProvisionerJobParams struct {
+	ID             uuid.UUID                `db:"id" json:"id"`
+	CreatedAt      time.Time                `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time                `db:"updated_at" json:"updated_at"`
+	OrganizationID uuid.UUID                `db:"organization_id" json:"organization_id"`
+	InitiatorID    uuid.UUID                `db:"initiator_id" json:"initiator_id"`
+	Provisioner    ProvisionerType          `db:"provisioner" json:"provisioner"`
+	StorageMethod  ProvisionerStorageMethod `db:"storage_method" json:"storage_method"`
+	FileID         uuid.UUID                `db:"file_id" json:"file_id"`
+	Type           ProvisionerJobType       `db:"type" json:"type"`
+	Input          json.RawMessage          `db:"input" json:"input"`
+	Tags           StringMap                `db:"tags" json:"tags"`
+	TraceMetadata  pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
+	Priority       int32                    `db:"priority" json:"priority"`
+	NotBefore      sql.NullTime             `db:"not_before" json:"not_before"`
+}
+
+func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error) {
+	row := q.db.QueryRowContext(ctx, insertProvisionerJob,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.OrganizationID,
+		arg.InitiatorID,
+		arg.Provisioner,
+		arg.StorageMethod,
+		arg.FileID,
+		arg.Type,
+		arg.Input,
+		arg.Tags,
+		arg.TraceMetadata,
+		arg.Priority,
+		arg.NotBefore,
+	)
+	var i ProvisionerJob
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CanceledAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Provisioner,
+		&i.StorageMethod,
+		&i.Type,
+		&i.Input,
+		&i.WorkerID,
+		&i.FileID,
+		&i.Tags,
+		&i.ErrorCode,
+		&i.TraceMetadata,
+		&i.Priority,
+		&i.NotBefore,
+	)
+	return i, err
+}
+
+const updateProvisionerJobByID = `-- name: UpdateProvisionerJobByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = $2
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobByID, arg.ID, arg.UpdatedAt)
+	return err
+}
+
+const updateProvisionerJobNotBeforeByID = `-- name: UpdateProvisionerJobNotBeforeByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = $2,
+	not_before = $3
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobNotBeforeByIDParams struct {
+	ID        uuid.UUID    `db:"id" json:"id"`
+	UpdatedAt time.Time    `db:"updated_at" json:"updated_at"`
+	NotBefore sql.NullTime `db:"not_before" json:"not_before"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobNotBeforeByID(ctx context.Context, arg UpdateProvisionerJobNotBeforeByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobNotBeforeByID, arg.ID, arg.UpdatedAt, arg.NotBefore)
+	return err
+}
+
+const updateProvisionerJobWithCancelByID = `-- name: UpdateProvisionerJobWithCancelByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	canceled_at = $2,
+	completed_at = $3
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobWithCancelByIDParams struct {
+	ID          uuid.UUID    `db:"id" json:"id"`
+	CanceledAt  sql.NullTime `db:"canceled_at" json:"canceled_at"`
+	CompletedAt sql.NullTime `db:"completed_at" json:"completed_at"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCancelByID, arg.ID, arg.CanceledAt, arg.CompletedAt)
+	return err
+}
+
+const updateProvisionerJobWithCompleteByID = `-- name: UpdateProvisionerJobWithCompleteByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = $2,
+	completed_at = $3,
+	error = $4,
+	error_code = $5
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobWithCompleteByIDParams struct {
+	ID          uuid.UUID      `db:"id" json:"id"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+	CompletedAt sql.NullTime   `db:"completed_at" json:"completed_at"`
+	Error       sql.NullString `db:"error" json:"error"`
+	ErrorCode   sql.NullString `db:"error_code" json:"error_code"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCompleteByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.CompletedAt,
+		arg.Error,
+		arg.ErrorCode,
+	)
+	return err
+}
+
+const deleteProvisionerKey = `-- name: DeleteProvisionerKey :exec
+DELETE FROM
+	provisioner_keys
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteProvisionerKey(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteProvisionerKey, id)
+	return err
+}
+
+const getProvisionerKeyByID = `-- name: GetProvisionerKeyByID :one
+SELECT
+	id, created_at, organization_id, name, hashed_secret, tags, last_used_at
+FROM
+	provisioner_keys
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetProvisionerKeyByID(ctx context.Context, id uuid.UUID) (ProvisionerKey, error) {
+	row := q.db.QueryRowContext(ctx, getProvisionerKeyByID, id)
+	var i ProvisionerKey
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.OrganizationID,
+		&i.Name,
+		&i.HashedSecret,
+		&i.Tags,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getProvisionerKeyByOrganizationIDAndName = `-- name: GetProvisionerKeyByOrganizationIDAndName :one
+SELECT
+	id, created_at, organization_id, name, hashed_secret, tags, last_used_at
+FROM
+	provisioner_keys
+WHERE
+	organization_id = $1
+	AND lower(name) = lower($2)
+`
+
+type GetProvisionerKeyByOrganizationIDAndNameParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetProvisionerKeyByOrganizationIDAndName(ctx context.Context, arg GetProvisionerKeyByOrganizationIDAndNameParams) (ProvisionerKey, error) {
+	row := q.db.QueryRowContext(ctx, getProvisionerKeyByOrganizationIDAndName, arg.OrganizationID, arg.Name)
+	var i ProvisionerKey
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.OrganizationID,
+		&i.Name,
+		&i.HashedSecret,
+		&i.Tags,
+		&i.LastUsedAt,
+	)
+	return i, err
+}
+
+const getProvisionerKeysByOrganizationID = `-- name: GetProvisionerKeysByOrganizationID :many
+SELECT
+	id, created_at, organization_id, name, hashed_secret, tags, last_used_at
+FROM
+	provisioner_keys
+WHERE
+	organization_id = $1
+ORDER BY
+	lower(name)
+`
+
+func (q *sqlQuerier) GetProvisionerKeysByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]ProvisionerKey, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerKeysByOrganizationID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerKey
+	for rows.Next() {
+		var i ProvisionerKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.OrganizationID,
+			&i.Name,
+			&i.HashedSecret,
+			&i.Tags,
+			&i.LastUsedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -2884,154 +5042,73 @@ func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, created
 	return items, nil
 }
 
-const insertProvisionerJob = `-- name: InsertProvisionerJob :one
+const insertProvisionerKey = `-- name: InsertProvisionerKey :one
 INSERT INTO
-	provisioner_jobs (
+	provisioner_keys (
 		id,
 		created_at,
-		updated_at,
 		organization_id,
-		initiator_id,
-		provisioner,
-		storage_method,
-		file_id,
-		"type",
-		"input",
-		tags,
-		trace_metadata
+		name,
+		hashed_secret,
+		tags
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, error_code, trace_metadata
+	($1, $2, $3, $4, $5, $6) RETURNING id, created_at, organization_id, name, hashed_secret, tags, last_used_at
 `
 
-type InsertProvisionerJobParams struct {
-	ID             uuid.UUID                `db:"id" json:"id"`
-	CreatedAt      time.Time                `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time                `db:"updated_at" json:"updated_at"`
-	OrganizationID uuid.UUID                `db:"organization_id" json:"organization_id"`
-	InitiatorID    uuid.UUID                `db:"initiator_id" json:"initiator_id"`
-	Provisioner    ProvisionerType          `db:"provisioner" json:"provisioner"`
-	StorageMethod  ProvisionerStorageMethod `db:"storage_method" json:"storage_method"`
-	FileID         uuid.UUID                `db:"file_id" json:"file_id"`
-	Type           ProvisionerJobType       `db:"type" json:"type"`
-	Input          json.RawMessage          `db:"input" json:"input"`
-	Tags           StringMap                `db:"tags" json:"tags"`
-	TraceMetadata  pqtype.NullRawMessage    `db:"trace_metadata" json:"trace_metadata"`
+type InsertProvisionerKeyParams struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+	HashedSecret   []byte    `db:"hashed_secret" json:"hashed_secret"`
+	Tags           StringMap `db:"tags" json:"tags"`
 }
 
-func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error) {
-	row := q.db.QueryRowContext(ctx, insertProvisionerJob,
+func (q *sqlQuerier) InsertProvisionerKey(ctx context.Context, arg InsertProvisionerKeyParams) (ProvisionerKey, error) {
+	row := q.db.QueryRowContext(ctx, insertProvisionerKey,
 		arg.ID,
 		arg.CreatedAt,
-		arg.UpdatedAt,
 		arg.OrganizationID,
-		arg.InitiatorID,
-		arg.Provisioner,
-		arg.StorageMethod,
-		arg.FileID,
-		arg.Type,
-		arg.Input,
+		arg.Name,
+		arg.HashedSecret,
 		arg.Tags,
-		arg.TraceMetadata,
 	)
-	var i ProvisionerJob
+	var i ProvisionerKey
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.StartedAt,
-		&i.CanceledAt,
-		&i.CompletedAt,
-		&i.Error,
 		&i.OrganizationID,
-		&i.InitiatorID,
-		&i.Provisioner,
-		&i.StorageMethod,
-		&i.Type,
-		&i.Input,
-		&i.WorkerID,
-		&i.FileID,
+		&i.Name,
+		&i.HashedSecret,
 		&i.Tags,
-		&i.ErrorCode,
-		&i.TraceMetadata,
+		&i.LastUsedAt,
 	)
 	return i, err
 }
 
-const updateProvisionerJobByID = `-- name: UpdateProvisionerJobByID :exec
-UPDATE
-	provisioner_jobs
-SET
-	updated_at = $2
-WHERE
-	id = $1
-`
-
-type UpdateProvisionerJobByIDParams struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-}
-
-func (q *sqlQuerier) UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobByID, arg.ID, arg.UpdatedAt)
-	return err
-}
-
-const updateProvisionerJobWithCancelByID = `-- name: UpdateProvisionerJobWithCancelByID :exec
-UPDATE
-	provisioner_jobs
-SET
-	canceled_at = $2,
-	completed_at = $3
-WHERE
-	id = $1
-`
-
-type UpdateProvisionerJobWithCancelByIDParams struct {
-	ID          uuid.UUID    `db:"id" json:"id"`
-	CanceledAt  sql.NullTime `db:"canceled_at" json:"canceled_at"`
-	CompletedAt sql.NullTime `db:"completed_at" json:"completed_at"`
-}
-
-func (q *sqlQuerier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCancelByID, arg.ID, arg.CanceledAt, arg.CompletedAt)
-	return err
-}
-
-const updateProvisionerJobWithCompleteByID = `-- name: UpdateProvisionerJobWithCompleteByID :exec
+const updateProvisionerKeyLastUsedAt = `-- name: UpdateProvisionerKeyLastUsedAt :exec
 UPDATE
-	provisioner_jobs
+	provisioner_keys
 SET
-	updated_at = $2,
-	completed_at = $3,
-	error = $4,
-	error_code = $5
+	last_used_at = $2
 WHERE
 	id = $1
 `
 
-type UpdateProvisionerJobWithCompleteByIDParams struct {
-	ID          uuid.UUID      `db:"id" json:"id"`
-	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
-	CompletedAt sql.NullTime   `db:"completed_at" json:"completed_at"`
-	Error       sql.NullString `db:"error" json:"error"`
-	ErrorCode   sql.NullString `db:"error_code" json:"error_code"`
+type UpdateProvisionerKeyLastUsedAtParams struct {
+	ID         uuid.UUID    `db:"id" json:"id"`
+	LastUsedAt sql.NullTime `db:"last_used_at" json:"last_used_at"`
 }
 
-func (q *sqlQuerier) UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCompleteByID,
-		arg.ID,
-		arg.UpdatedAt,
-		arg.CompletedAt,
-		arg.Error,
-		arg.ErrorCode,
-	)
+func (q *sqlQuerier) UpdateProvisionerKeyLastUsedAt(ctx context.Context, arg UpdateProvisionerKeyLastUsedAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerKeyLastUsedAt, arg.ID, arg.LastUsedAt)
 	return err
 }
 
 const getWorkspaceProxies = `-- name: GetWorkspaceProxies :many
 SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, token_expires_at, previous_token_hashed_secret, previous_token_expires_at
 FROM
 	workspace_proxies
 WHERE
@@ -3061,6 +5138,9 @@ func (q *sqlQuerier) GetWorkspaceProxies(ctx context.Context) ([]WorkspaceProxy,
 			&i.RegionID,
 			&i.DerpEnabled,
 			&i.DerpOnly,
+			&i.TokenExpiresAt,
+			&i.PreviousTokenHashedSecret,
+			&i.PreviousTokenExpiresAt,
 		); err != nil {
 			return nil, err
 		}
@@ -3077,7 +5157,7 @@ func (q *sqlQuerier) GetWorkspaceProxies(ctx context.Context) ([]WorkspaceProxy,
 
 const getWorkspaceProxyByHostname = `-- name: GetWorkspaceProxyByHostname :one
 SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, token_expires_at, previous_token_hashed_secret, previous_token_expires_at
 FROM
 	workspace_proxies
 WHERE
@@ -3136,13 +5216,16 @@ func (q *sqlQuerier) GetWorkspaceProxyByHostname(ctx context.Context, arg GetWor
 		&i.RegionID,
 		&i.DerpEnabled,
 		&i.DerpOnly,
+		&i.TokenExpiresAt,
+		&i.PreviousTokenHashedSecret,
+		&i.PreviousTokenExpiresAt,
 	)
 	return i, err
 }
 
 const getWorkspaceProxyByID = `-- name: GetWorkspaceProxyByID :one
 SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, token_expires_at, previous_token_hashed_secret, previous_token_expires_at
 FROM
 	workspace_proxies
 WHERE
@@ -3168,13 +5251,16 @@ func (q *sqlQuerier) GetWorkspaceProxyByID(ctx context.Context, id uuid.UUID) (W
 		&i.RegionID,
 		&i.DerpEnabled,
 		&i.DerpOnly,
+		&i.TokenExpiresAt,
+		&i.PreviousTokenHashedSecret,
+		&i.PreviousTokenExpiresAt,
 	)
 	return i, err
 }
 
 const getWorkspaceProxyByName = `-- name: GetWorkspaceProxyByName :one
 SELECT
-	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only
+	id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, token_expires_at, previous_token_hashed_secret, previous_token_expires_at
 FROM
 	workspace_proxies
 WHERE
@@ -3201,6 +5287,9 @@ func (q *sqlQuerier) GetWorkspaceProxyByName(ctx context.Context, name string) (
 		&i.RegionID,
 		&i.DerpEnabled,
 		&i.DerpOnly,
+		&i.TokenExpiresAt,
+		&i.PreviousTokenHashedSecret,
+		&i.PreviousTokenExpiresAt,
 	)
 	return i, err
 }
@@ -3222,7 +5311,7 @@ INSERT INTO
 		deleted
 	)
 VALUES
-	($1, '', '', $2, $3, $4, $5, $6, $7, $8, $9, false) RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only
+	($1, '', '', $2, $3, $4, $5, $6, $7, $8, $9, false) RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, token_expires_at, previous_token_hashed_secret, previous_token_expires_at
 `
 
 type InsertWorkspaceProxyParams struct {
@@ -3264,6 +5353,9 @@ func (q *sqlQuerier) InsertWorkspaceProxy(ctx context.Context, arg InsertWorkspa
 		&i.RegionID,
 		&i.DerpEnabled,
 		&i.DerpOnly,
+		&i.TokenExpiresAt,
+		&i.PreviousTokenHashedSecret,
+		&i.PreviousTokenExpiresAt,
 	)
 	return i, err
 }
@@ -3279,7 +5371,7 @@ SET
 	updated_at = Now()
 WHERE
 	id = $5
-RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only
+RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, token_expires_at, previous_token_hashed_secret, previous_token_expires_at
 `
 
 type RegisterWorkspaceProxyParams struct {
@@ -3313,6 +5405,67 @@ func (q *sqlQuerier) RegisterWorkspaceProxy(ctx context.Context, arg RegisterWor
 		&i.RegionID,
 		&i.DerpEnabled,
 		&i.DerpOnly,
+		&i.TokenExpiresAt,
+		&i.PreviousTokenHashedSecret,
+		&i.PreviousTokenExpiresAt,
+	)
+	return i, err
+}
+
+const rotateWorkspaceProxyToken = `-- name: RotateWorkspaceProxyToken :one
+UPDATE
+	workspace_proxies
+SET
+	previous_token_hashed_secret = token_hashed_secret,
+	previous_token_expires_at = $1 :: timestamptz,
+	token_hashed_secret = $2 :: bytea,
+	token_expires_at = $3 :: timestamptz,
+	updated_at = Now()
+WHERE
+	id = $4
+	AND token_hashed_secret = $5 :: bytea
+	AND deleted = false
+RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, token_expires_at, previous_token_hashed_secret, previous_token_expires_at
+`
+
+type RotateWorkspaceProxyTokenParams struct {
+	PreviousTokenExpiresAt   time.Time `db:"previous_token_expires_at" json:"previous_token_expires_at"`
+	TokenHashedSecret        []byte    `db:"token_hashed_secret" json:"token_hashed_secret"`
+	TokenExpiresAt           time.Time `db:"token_expires_at" json:"token_expires_at"`
+	ID                       uuid.UUID `db:"id" json:"id"`
+	CurrentTokenHashedSecret []byte    `db:"current_token_hashed_secret" json:"current_token_hashed_secret"`
+}
+
+// Replaces the token of a workspace proxy, and keeps the replaced token valid
+// until @previous_token_expires_at. The token is only replaced if it still
+// matches @current_token_hashed_secret, so concurrent rotations by replicas of
+// the same proxy don't replace each other's tokens.
+func (q *sqlQuerier) RotateWorkspaceProxyToken(ctx context.Context, arg RotateWorkspaceProxyTokenParams) (WorkspaceProxy, error) {
+	row := q.db.QueryRowContext(ctx, rotateWorkspaceProxyToken,
+		arg.PreviousTokenExpiresAt,
+		arg.TokenHashedSecret,
+		arg.TokenExpiresAt,
+		arg.ID,
+		arg.CurrentTokenHashedSecret,
+	)
+	var i WorkspaceProxy
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.DisplayName,
+		&i.Icon,
+		&i.Url,
+		&i.WildcardHostname,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Deleted,
+		&i.TokenHashedSecret,
+		&i.RegionID,
+		&i.DerpEnabled,
+		&i.DerpOnly,
+		&i.TokenExpiresAt,
+		&i.PreviousTokenHashedSecret,
+		&i.PreviousTokenExpiresAt,
 	)
 	return i, err
 }
@@ -3331,11 +5484,25 @@ SET
 		WHEN length($4 :: bytea) > 0  THEN $4 :: bytea
 		ELSE  workspace_proxies.token_hashed_secret
 	END,
+	-- A new token replaces any rotated tokens, and like all tokens created by
+	-- users it doesn't expire.
+	token_expires_at = CASE
+		WHEN length($4 :: bytea) > 0  THEN NULL
+		ELSE  workspace_proxies.token_expires_at
+	END,
+	previous_token_hashed_secret = CASE
+		WHEN length($4 :: bytea) > 0  THEN NULL
+		ELSE  workspace_proxies.previous_token_hashed_secret
+	END,
+	previous_token_expires_at = CASE
+		WHEN length($4 :: bytea) > 0  THEN NULL
+		ELSE  workspace_proxies.previous_token_expires_at
+	END,
 	-- Always update this timestamp.
 	updated_at = Now()
 WHERE
 	id = $5
-RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only
+RETURNING id, name, display_name, icon, url, wildcard_hostname, created_at, updated_at, deleted, token_hashed_secret, region_id, derp_enabled, derp_only, token_expires_at, previous_token_hashed_secret, previous_token_expires_at
 `
 
 type UpdateWorkspaceProxyParams struct {
@@ -3370,6 +5537,9 @@ func (q *sqlQuerier) UpdateWorkspaceProxy(ctx context.Context, arg UpdateWorkspa
 		&i.RegionID,
 		&i.DerpEnabled,
 		&i.DerpOnly,
+		&i.TokenExpiresAt,
+		&i.PreviousTokenHashedSecret,
+		&i.PreviousTokenExpiresAt,
 	)
 	return i, err
 }
@@ -3441,6 +5611,148 @@ func (q *sqlQuerier) GetQuotaConsumedForUser(ctx context.Context, ownerID uuid.U
 	return column_1, err
 }
 
+const getQuotaConsumedForUserByWorkspace = `-- name: GetQuotaConsumedForUserByWorkspace :many
+WITH latest_builds AS (
+SELECT
+	DISTINCT ON
+	(workspace_id) id,
+	workspace_id,
+	transition,
+	daily_cost
+FROM
+	workspace_builds wb
+ORDER BY
+	workspace_id,
+	created_at DESC
+)
+SELECT
+	workspaces.id AS workspace_id,
+	workspaces.name AS workspace_name,
+	templates.id AS template_id,
+	templates.name AS template_name,
+	latest_builds.transition,
+	latest_builds.daily_cost
+FROM
+	workspaces
+JOIN latest_builds ON
+	latest_builds.workspace_id = workspaces.id
+JOIN templates ON
+	templates.id = workspaces.template_id
+WHERE
+	NOT workspaces.deleted
+	AND workspaces.owner_id = $1
+ORDER BY
+	workspaces.name ASC
+`
+
+type GetQuotaConsumedForUserByWorkspaceRow struct {
+	WorkspaceID   uuid.UUID           `db:"workspace_id" json:"workspace_id"`
+	WorkspaceName string              `db:"workspace_name" json:"workspace_name"`
+	TemplateID    uuid.UUID           `db:"template_id" json:"template_id"`
+	TemplateName  string              `db:"template_name" json:"template_name"`
+	Transition    WorkspaceTransition `db:"transition" json:"transition"`
+	DailyCost     int32               `db:"daily_cost" json:"daily_cost"`
+}
+
+// Returns the daily cost of the latest build of each of the user's workspaces,
+// which add up to the quota consumed by the user.
+func (q *sqlQuerier) GetQuotaConsumedForUserByWorkspace(ctx context.Context, ownerID uuid.UUID) ([]GetQuotaConsumedForUserByWorkspaceRow, error) {
+	rows, err := q.db.QueryContext(ctx, getQuotaConsumedForUserByWorkspace, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetQuotaConsumedForUserByWorkspaceRow
+	for rows.Next() {
+		var i GetQuotaConsumedForUserByWorkspaceRow
+		if err := rows.Scan(
+			&i.WorkspaceID,
+			&i.WorkspaceName,
+			&i.TemplateID,
+			&i.TemplateName,
+			&i.Transition,
+			&i.DailyCost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getQuotaResourceMetadataForUser = `-- name: GetQuotaResourceMetadataForUser :many
+WITH latest_builds AS (
+SELECT
+	DISTINCT ON
+	(workspace_id) id,
+	workspace_id,
+	job_id
+FROM
+	workspace_builds wb
+ORDER BY
+	workspace_id,
+	created_at DESC
+)
+SELECT
+	workspace_resource_metadata.workspace_resource_id, workspace_resource_metadata.key, workspace_resource_metadata.value, workspace_resource_metadata.sensitive, workspace_resource_metadata.id
+FROM
+	workspaces
+JOIN latest_builds ON
+	latest_builds.workspace_id = workspaces.id
+JOIN workspace_resources ON
+	workspace_resources.job_id = latest_builds.job_id
+JOIN workspace_resource_metadata ON
+	workspace_resource_metadata.workspace_resource_id = workspace_resources.id
+WHERE
+	NOT workspaces.deleted
+	AND workspaces.owner_id = $1
+	AND workspaces.template_id = $2
+	AND workspace_resource_metadata.key IN ('cpu', 'memory', 'disk')
+`
+
+type GetQuotaResourceMetadataForUserParams struct {
+	OwnerID    uuid.UUID `db:"owner_id" json:"owner_id"`
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+}
+
+// Returns the "cpu", "memory" and "disk" metadata of the resources of the
+// latest builds of the user's workspaces of a template, which the resource
+// quotas of the template are enforced against.
+func (q *sqlQuerier) GetQuotaResourceMetadataForUser(ctx context.Context, arg GetQuotaResourceMetadataForUserParams) ([]WorkspaceResourceMetadatum, error) {
+	rows, err := q.db.QueryContext(ctx, getQuotaResourceMetadataForUser, arg.OwnerID, arg.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceResourceMetadatum
+	for rows.Next() {
+		var i WorkspaceResourceMetadatum
+		if err := rows.Scan(
+			&i.WorkspaceResourceID,
+			&i.Key,
+			&i.Value,
+			&i.Sensitive,
+			&i.ID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const deleteReplicasUpdatedBefore = `-- name: DeleteReplicasUpdatedBefore :exec
 DELETE FROM replicas WHERE updated_at < $1
 `
@@ -3573,63 +5885,382 @@ func (q *sqlQuerier) InsertReplica(ctx context.Context, arg InsertReplicaParams)
 	return i, err
 }
 
-const updateReplica = `-- name: UpdateReplica :one
-UPDATE replicas SET
-    updated_at = $2,
-    started_at = $3,
-    stopped_at = $4,
-    relay_address = $5,
-    region_id = $6,
-    hostname = $7,
-    version = $8,
-    error = $9,
-    database_latency = $10,
-	"primary" = $11
-WHERE id = $1 RETURNING id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error, "primary"
+const updateReplica = `-- name: UpdateReplica :one
+UPDATE replicas SET
+    updated_at = $2,
+    started_at = $3,
+    stopped_at = $4,
+    relay_address = $5,
+    region_id = $6,
+    hostname = $7,
+    version = $8,
+    error = $9,
+    database_latency = $10,
+	"primary" = $11
+WHERE id = $1 RETURNING id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error, "primary"
+`
+
+type UpdateReplicaParams struct {
+	ID              uuid.UUID    `db:"id" json:"id"`
+	UpdatedAt       time.Time    `db:"updated_at" json:"updated_at"`
+	StartedAt       time.Time    `db:"started_at" json:"started_at"`
+	StoppedAt       sql.NullTime `db:"stopped_at" json:"stopped_at"`
+	RelayAddress    string       `db:"relay_address" json:"relay_address"`
+	RegionID        int32        `db:"region_id" json:"region_id"`
+	Hostname        string       `db:"hostname" json:"hostname"`
+	Version         string       `db:"version" json:"version"`
+	Error           string       `db:"error" json:"error"`
+	DatabaseLatency int32        `db:"database_latency" json:"database_latency"`
+	Primary         bool         `db:"primary" json:"primary"`
+}
+
+func (q *sqlQuerier) UpdateReplica(ctx context.Context, arg UpdateReplicaParams) (Replica, error) {
+	row := q.db.QueryRowContext(ctx, updateReplica,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.StartedAt,
+		arg.StoppedAt,
+		arg.RelayAddress,
+		arg.RegionID,
+		arg.Hostname,
+		arg.Version,
+		arg.Error,
+		arg.DatabaseLatency,
+		arg.Primary,
+	)
+	var i Replica
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.StartedAt,
+		&i.StoppedAt,
+		&i.UpdatedAt,
+		&i.Hostname,
+		&i.RegionID,
+		&i.RelayAddress,
+		&i.DatabaseLatency,
+		&i.Version,
+		&i.Error,
+		&i.Primary,
+	)
+	return i, err
+}
+
+const deleteSavedWorkspaceFilter = `-- name: DeleteSavedWorkspaceFilter :exec
+DELETE FROM
+	saved_workspace_filters
+WHERE
+	user_id = $1
+	AND name = $2
+`
+
+type DeleteSavedWorkspaceFilterParams struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	Name   string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) DeleteSavedWorkspaceFilter(ctx context.Context, arg DeleteSavedWorkspaceFilterParams) error {
+	_, err := q.db.ExecContext(ctx, deleteSavedWorkspaceFilter, arg.UserID, arg.Name)
+	return err
+}
+
+const getSavedWorkspaceFilterByUserIDAndName = `-- name: GetSavedWorkspaceFilterByUserIDAndName :one
+SELECT
+	id, user_id, name, query, created_at, updated_at
+FROM
+	saved_workspace_filters
+WHERE
+	user_id = $1
+	AND name = $2
+`
+
+type GetSavedWorkspaceFilterByUserIDAndNameParams struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	Name   string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetSavedWorkspaceFilterByUserIDAndName(ctx context.Context, arg GetSavedWorkspaceFilterByUserIDAndNameParams) (SavedWorkspaceFilter, error) {
+	row := q.db.QueryRowContext(ctx, getSavedWorkspaceFilterByUserIDAndName, arg.UserID, arg.Name)
+	var i SavedWorkspaceFilter
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Query,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSavedWorkspaceFiltersByUserID = `-- name: GetSavedWorkspaceFiltersByUserID :many
+SELECT
+	id, user_id, name, query, created_at, updated_at
+FROM
+	saved_workspace_filters
+WHERE
+	user_id = $1
+ORDER BY
+	name ASC
+`
+
+func (q *sqlQuerier) GetSavedWorkspaceFiltersByUserID(ctx context.Context, userID uuid.UUID) ([]SavedWorkspaceFilter, error) {
+	rows, err := q.db.QueryContext(ctx, getSavedWorkspaceFiltersByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SavedWorkspaceFilter
+	for rows.Next() {
+		var i SavedWorkspaceFilter
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Query,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertSavedWorkspaceFilter = `-- name: InsertSavedWorkspaceFilter :one
+INSERT INTO
+	saved_workspace_filters (
+		id,
+		user_id,
+		name,
+		query,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING id, user_id, name, query, created_at, updated_at
+`
+
+type InsertSavedWorkspaceFilterParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Name      string    `db:"name" json:"name"`
+	Query     string    `db:"query" json:"query"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) InsertSavedWorkspaceFilter(ctx context.Context, arg InsertSavedWorkspaceFilterParams) (SavedWorkspaceFilter, error) {
+	row := q.db.QueryRowContext(ctx, insertSavedWorkspaceFilter,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Query,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i SavedWorkspaceFilter
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Query,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateSavedWorkspaceFilter = `-- name: UpdateSavedWorkspaceFilter :one
+UPDATE
+	saved_workspace_filters
+SET
+	query = $3,
+	updated_at = $4
+WHERE
+	user_id = $1
+	AND name = $2 RETURNING id, user_id, name, query, created_at, updated_at
+`
+
+type UpdateSavedWorkspaceFilterParams struct {
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Name      string    `db:"name" json:"name"`
+	Query     string    `db:"query" json:"query"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateSavedWorkspaceFilter(ctx context.Context, arg UpdateSavedWorkspaceFilterParams) (SavedWorkspaceFilter, error) {
+	row := q.db.QueryRowContext(ctx, updateSavedWorkspaceFilter,
+		arg.UserID,
+		arg.Name,
+		arg.Query,
+		arg.UpdatedAt,
+	)
+	var i SavedWorkspaceFilter
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Query,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteScheduleHolidayByID = `-- name: DeleteScheduleHolidayByID :exec
+DELETE FROM
+	schedule_holidays
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteScheduleHolidayByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteScheduleHolidayByID, id)
+	return err
+}
+
+const getScheduleHolidayByID = `-- name: GetScheduleHolidayByID :one
+SELECT
+	id, created_at, updated_at, date, name
+FROM
+	schedule_holidays
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetScheduleHolidayByID(ctx context.Context, id uuid.UUID) (ScheduleHoliday, error) {
+	row := q.db.QueryRowContext(ctx, getScheduleHolidayByID, id)
+	var i ScheduleHoliday
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Date,
+		&i.Name,
+	)
+	return i, err
+}
+
+const getScheduleHolidays = `-- name: GetScheduleHolidays :many
+SELECT
+	id, created_at, updated_at, date, name
+FROM
+	schedule_holidays
+ORDER BY
+	date ASC
+`
+
+func (q *sqlQuerier) GetScheduleHolidays(ctx context.Context) ([]ScheduleHoliday, error) {
+	rows, err := q.db.QueryContext(ctx, getScheduleHolidays)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ScheduleHoliday
+	for rows.Next() {
+		var i ScheduleHoliday
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Date,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertScheduleHoliday = `-- name: InsertScheduleHoliday :one
+INSERT INTO
+	schedule_holidays (
+		id,
+		created_at,
+		updated_at,
+		date,
+		name
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+RETURNING id, created_at, updated_at, date, name
+`
+
+type InsertScheduleHolidayParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	Date      time.Time `db:"date" json:"date"`
+	Name      string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) InsertScheduleHoliday(ctx context.Context, arg InsertScheduleHolidayParams) (ScheduleHoliday, error) {
+	row := q.db.QueryRowContext(ctx, insertScheduleHoliday,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Date,
+		arg.Name,
+	)
+	var i ScheduleHoliday
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Date,
+		&i.Name,
+	)
+	return i, err
+}
+
+const updateScheduleHolidayByID = `-- name: UpdateScheduleHolidayByID :one
+UPDATE
+	schedule_holidays
+SET
+	updated_at = $2,
+	date = $3,
+	name = $4
+WHERE
+	id = $1
+RETURNING id, created_at, updated_at, date, name
 `
 
-type UpdateReplicaParams struct {
-	ID              uuid.UUID    `db:"id" json:"id"`
-	UpdatedAt       time.Time    `db:"updated_at" json:"updated_at"`
-	StartedAt       time.Time    `db:"started_at" json:"started_at"`
-	StoppedAt       sql.NullTime `db:"stopped_at" json:"stopped_at"`
-	RelayAddress    string       `db:"relay_address" json:"relay_address"`
-	RegionID        int32        `db:"region_id" json:"region_id"`
-	Hostname        string       `db:"hostname" json:"hostname"`
-	Version         string       `db:"version" json:"version"`
-	Error           string       `db:"error" json:"error"`
-	DatabaseLatency int32        `db:"database_latency" json:"database_latency"`
-	Primary         bool         `db:"primary" json:"primary"`
+type UpdateScheduleHolidayByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	Date      time.Time `db:"date" json:"date"`
+	Name      string    `db:"name" json:"name"`
 }
 
-func (q *sqlQuerier) UpdateReplica(ctx context.Context, arg UpdateReplicaParams) (Replica, error) {
-	row := q.db.QueryRowContext(ctx, updateReplica,
+func (q *sqlQuerier) UpdateScheduleHolidayByID(ctx context.Context, arg UpdateScheduleHolidayByIDParams) (ScheduleHoliday, error) {
+	row := q.db.QueryRowContext(ctx, updateScheduleHolidayByID,
 		arg.ID,
 		arg.UpdatedAt,
-		arg.StartedAt,
-		arg.StoppedAt,
-		arg.RelayAddress,
-		arg.RegionID,
-		arg.Hostname,
-		arg.Version,
-		arg.Error,
-		arg.DatabaseLatency,
-		arg.Primary,
+		arg.Date,
+		arg.Name,
 	)
-	var i Replica
+	var i ScheduleHoliday
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
-		&i.StartedAt,
-		&i.StoppedAt,
 		&i.UpdatedAt,
-		&i.Hostname,
-		&i.RegionID,
-		&i.RelayAddress,
-		&i.DatabaseLatency,
-		&i.Version,
-		&i.Error,
-		&i.Primary,
+		&i.Date,
+		&i.Name,
 	)
 	return i, err
 }
@@ -3998,21 +6629,258 @@ FROM tailnet_clients
 WHERE agent_id = $1
 `
 
-func (q *sqlQuerier) GetTailnetClientsForAgent(ctx context.Context, agentID uuid.UUID) ([]TailnetClient, error) {
-	rows, err := q.db.QueryContext(ctx, getTailnetClientsForAgent, agentID)
+func (q *sqlQuerier) GetTailnetClientsForAgent(ctx context.Context, agentID uuid.UUID) ([]TailnetClient, error) {
+	rows, err := q.db.QueryContext(ctx, getTailnetClientsForAgent, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TailnetClient
+	for rows.Next() {
+		var i TailnetClient
+		if err := rows.Scan(
+			&i.ID,
+			&i.CoordinatorID,
+			&i.AgentID,
+			&i.UpdatedAt,
+			&i.Node,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertTailnetAgent = `-- name: UpsertTailnetAgent :one
+INSERT INTO
+	tailnet_agents (
+	id,
+	coordinator_id,
+	node,
+	updated_at
+)
+VALUES
+	($1, $2, $3, now() at time zone 'utc')
+ON CONFLICT (id, coordinator_id)
+DO UPDATE SET
+	id = $1,
+	coordinator_id = $2,
+	node = $3,
+	updated_at = now() at time zone 'utc'
+RETURNING id, coordinator_id, updated_at, node
+`
+
+type UpsertTailnetAgentParams struct {
+	ID            uuid.UUID       `db:"id" json:"id"`
+	CoordinatorID uuid.UUID       `db:"coordinator_id" json:"coordinator_id"`
+	Node          json.RawMessage `db:"node" json:"node"`
+}
+
+func (q *sqlQuerier) UpsertTailnetAgent(ctx context.Context, arg UpsertTailnetAgentParams) (TailnetAgent, error) {
+	row := q.db.QueryRowContext(ctx, upsertTailnetAgent, arg.ID, arg.CoordinatorID, arg.Node)
+	var i TailnetAgent
+	err := row.Scan(
+		&i.ID,
+		&i.CoordinatorID,
+		&i.UpdatedAt,
+		&i.Node,
+	)
+	return i, err
+}
+
+const upsertTailnetClient = `-- name: UpsertTailnetClient :one
+INSERT INTO
+	tailnet_clients (
+	id,
+	coordinator_id,
+	agent_id,
+	node,
+	updated_at
+)
+VALUES
+	($1, $2, $3, $4, now() at time zone 'utc')
+ON CONFLICT (id, coordinator_id)
+DO UPDATE SET
+	id = $1,
+	coordinator_id = $2,
+	agent_id = $3,
+	node = $4,
+	updated_at = now() at time zone 'utc'
+RETURNING id, coordinator_id, agent_id, updated_at, node
+`
+
+type UpsertTailnetClientParams struct {
+	ID            uuid.UUID       `db:"id" json:"id"`
+	CoordinatorID uuid.UUID       `db:"coordinator_id" json:"coordinator_id"`
+	AgentID       uuid.UUID       `db:"agent_id" json:"agent_id"`
+	Node          json.RawMessage `db:"node" json:"node"`
+}
+
+func (q *sqlQuerier) UpsertTailnetClient(ctx context.Context, arg UpsertTailnetClientParams) (TailnetClient, error) {
+	row := q.db.QueryRowContext(ctx, upsertTailnetClient,
+		arg.ID,
+		arg.CoordinatorID,
+		arg.AgentID,
+		arg.Node,
+	)
+	var i TailnetClient
+	err := row.Scan(
+		&i.ID,
+		&i.CoordinatorID,
+		&i.AgentID,
+		&i.UpdatedAt,
+		&i.Node,
+	)
+	return i, err
+}
+
+const upsertTailnetCoordinator = `-- name: UpsertTailnetCoordinator :one
+INSERT INTO
+	tailnet_coordinators (
+	id,
+	heartbeat_at
+)
+VALUES
+	($1, now() at time zone 'utc')
+ON CONFLICT (id)
+DO UPDATE SET
+  id = $1,
+  heartbeat_at = now() at time zone 'utc'
+RETURNING id, heartbeat_at
+`
+
+func (q *sqlQuerier) UpsertTailnetCoordinator(ctx context.Context, id uuid.UUID) (TailnetCoordinator, error) {
+	row := q.db.QueryRowContext(ctx, upsertTailnetCoordinator, id)
+	var i TailnetCoordinator
+	err := row.Scan(&i.ID, &i.HeartbeatAt)
+	return i, err
+}
+
+const acquireTemplateDeadlineRecalculation = `-- name: AcquireTemplateDeadlineRecalculation :one
+UPDATE
+	template_deadline_recalculations
+SET
+	status = 'running',
+	updated_at = $1
+WHERE
+	id = (
+		SELECT
+			id
+		FROM
+			template_deadline_recalculations AS nested
+		WHERE
+			nested.status = 'pending'
+			OR (nested.status = 'running' AND nested.updated_at < $2)
+		ORDER BY
+			nested.created_at
+		FOR UPDATE
+		SKIP LOCKED
+		LIMIT
+			1
+	)
+RETURNING id, template_id, status, total_builds, processed_builds, error, created_at, updated_at, completed_at
+`
+
+type AcquireTemplateDeadlineRecalculationParams struct {
+	Now         time.Time `db:"now" json:"now"`
+	StaleBefore time.Time `db:"stale_before" json:"stale_before"`
+}
+
+// Acquires the oldest pending recalculation, or a running one that has not
+// been updated since stale_before because the replica running it went away.
+// SKIP LOCKED lets replicas acquire different recalculations concurrently.
+func (q *sqlQuerier) AcquireTemplateDeadlineRecalculation(ctx context.Context, arg AcquireTemplateDeadlineRecalculationParams) (TemplateDeadlineRecalculation, error) {
+	row := q.db.QueryRowContext(ctx, acquireTemplateDeadlineRecalculation, arg.Now, arg.StaleBefore)
+	var i TemplateDeadlineRecalculation
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.Status,
+		&i.TotalBuilds,
+		&i.ProcessedBuilds,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const deleteOldTemplateDeadlineRecalculations = `-- name: DeleteOldTemplateDeadlineRecalculations :exec
+DELETE FROM
+	template_deadline_recalculations
+WHERE
+	completed_at < $1
+`
+
+func (q *sqlQuerier) DeleteOldTemplateDeadlineRecalculations(ctx context.Context, completedBefore time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteOldTemplateDeadlineRecalculations, completedBefore)
+	return err
+}
+
+const getTemplateDeadlineRecalculationByID = `-- name: GetTemplateDeadlineRecalculationByID :one
+SELECT
+	id, template_id, status, total_builds, processed_builds, error, created_at, updated_at, completed_at
+FROM
+	template_deadline_recalculations
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetTemplateDeadlineRecalculationByID(ctx context.Context, id uuid.UUID) (TemplateDeadlineRecalculation, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateDeadlineRecalculationByID, id)
+	var i TemplateDeadlineRecalculation
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.Status,
+		&i.TotalBuilds,
+		&i.ProcessedBuilds,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getTemplateDeadlineRecalculationsByTemplateID = `-- name: GetTemplateDeadlineRecalculationsByTemplateID :many
+SELECT
+	id, template_id, status, total_builds, processed_builds, error, created_at, updated_at, completed_at
+FROM
+	template_deadline_recalculations
+WHERE
+	template_id = $1
+ORDER BY
+	created_at DESC
+`
+
+func (q *sqlQuerier) GetTemplateDeadlineRecalculationsByTemplateID(ctx context.Context, templateID uuid.UUID) ([]TemplateDeadlineRecalculation, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateDeadlineRecalculationsByTemplateID, templateID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TailnetClient
+	var items []TemplateDeadlineRecalculation
 	for rows.Next() {
-		var i TailnetClient
+		var i TemplateDeadlineRecalculation
 		if err := rows.Scan(
 			&i.ID,
-			&i.CoordinatorID,
-			&i.AgentID,
+			&i.TemplateID,
+			&i.Status,
+			&i.TotalBuilds,
+			&i.ProcessedBuilds,
+			&i.Error,
+			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.Node,
+			&i.CompletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -4027,108 +6895,172 @@ func (q *sqlQuerier) GetTailnetClientsForAgent(ctx context.Context, agentID uuid
 	return items, nil
 }
 
-const upsertTailnetAgent = `-- name: UpsertTailnetAgent :one
+const insertTemplateDeadlineRecalculation = `-- name: InsertTemplateDeadlineRecalculation :one
 INSERT INTO
-	tailnet_agents (
-	id,
-	coordinator_id,
-	node,
-	updated_at
-)
+	template_deadline_recalculations (
+		id,
+		template_id,
+		created_at,
+		updated_at
+	)
 VALUES
-	($1, $2, $3, now() at time zone 'utc')
-ON CONFLICT (id, coordinator_id)
+	($1, $2, $3, $3)
+ON CONFLICT
+	(template_id) WHERE status = 'pending'
 DO UPDATE SET
-	id = $1,
-	coordinator_id = $2,
-	node = $3,
-	updated_at = now() at time zone 'utc'
-RETURNING id, coordinator_id, updated_at, node
+	updated_at = $3
+RETURNING id, template_id, status, total_builds, processed_builds, error, created_at, updated_at, completed_at
 `
 
-type UpsertTailnetAgentParams struct {
-	ID            uuid.UUID       `db:"id" json:"id"`
-	CoordinatorID uuid.UUID       `db:"coordinator_id" json:"coordinator_id"`
-	Node          json.RawMessage `db:"node" json:"node"`
+type InsertTemplateDeadlineRecalculationParams struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
 }
 
-func (q *sqlQuerier) UpsertTailnetAgent(ctx context.Context, arg UpsertTailnetAgentParams) (TailnetAgent, error) {
-	row := q.db.QueryRowContext(ctx, upsertTailnetAgent, arg.ID, arg.CoordinatorID, arg.Node)
-	var i TailnetAgent
+// Queues a recalculation of the template. If a recalculation of the template
+// is already pending, it is returned instead, as it will use the latest
+// template schedule when it runs.
+func (q *sqlQuerier) InsertTemplateDeadlineRecalculation(ctx context.Context, arg InsertTemplateDeadlineRecalculationParams) (TemplateDeadlineRecalculation, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateDeadlineRecalculation, arg.ID, arg.TemplateID, arg.CreatedAt)
+	var i TemplateDeadlineRecalculation
 	err := row.Scan(
 		&i.ID,
-		&i.CoordinatorID,
+		&i.TemplateID,
+		&i.Status,
+		&i.TotalBuilds,
+		&i.ProcessedBuilds,
+		&i.Error,
+		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Node,
+		&i.CompletedAt,
 	)
 	return i, err
 }
 
-const upsertTailnetClient = `-- name: UpsertTailnetClient :one
-INSERT INTO
-	tailnet_clients (
-	id,
-	coordinator_id,
-	agent_id,
-	node,
-	updated_at
-)
-VALUES
-	($1, $2, $3, $4, now() at time zone 'utc')
-ON CONFLICT (id, coordinator_id)
-DO UPDATE SET
-	id = $1,
-	coordinator_id = $2,
-	agent_id = $3,
-	node = $4,
-	updated_at = now() at time zone 'utc'
-RETURNING id, coordinator_id, agent_id, updated_at, node
+const updateTemplateDeadlineRecalculationCompletedByID = `-- name: UpdateTemplateDeadlineRecalculationCompletedByID :exec
+UPDATE
+	template_deadline_recalculations
+SET
+	updated_at = $2,
+	completed_at = $2,
+	status = $3,
+	error = $4
+WHERE
+	id = $1
 `
 
-type UpsertTailnetClientParams struct {
-	ID            uuid.UUID       `db:"id" json:"id"`
-	CoordinatorID uuid.UUID       `db:"coordinator_id" json:"coordinator_id"`
-	AgentID       uuid.UUID       `db:"agent_id" json:"agent_id"`
-	Node          json.RawMessage `db:"node" json:"node"`
+type UpdateTemplateDeadlineRecalculationCompletedByIDParams struct {
+	ID        uuid.UUID                           `db:"id" json:"id"`
+	UpdatedAt time.Time                           `db:"updated_at" json:"updated_at"`
+	Status    TemplateDeadlineRecalculationStatus `db:"status" json:"status"`
+	Error     string                              `db:"error" json:"error"`
 }
 
-func (q *sqlQuerier) UpsertTailnetClient(ctx context.Context, arg UpsertTailnetClientParams) (TailnetClient, error) {
-	row := q.db.QueryRowContext(ctx, upsertTailnetClient,
+func (q *sqlQuerier) UpdateTemplateDeadlineRecalculationCompletedByID(ctx context.Context, arg UpdateTemplateDeadlineRecalculationCompletedByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateDeadlineRecalculationCompletedByID,
 		arg.ID,
-		arg.CoordinatorID,
-		arg.AgentID,
-		arg.Node,
+		arg.UpdatedAt,
+		arg.Status,
+		arg.Error,
 	)
-	var i TailnetClient
+	return err
+}
+
+const updateTemplateDeadlineRecalculationProgressByID = `-- name: UpdateTemplateDeadlineRecalculationProgressByID :exec
+UPDATE
+	template_deadline_recalculations
+SET
+	updated_at = $2,
+	total_builds = $3,
+	processed_builds = $4
+WHERE
+	id = $1
+`
+
+type UpdateTemplateDeadlineRecalculationProgressByIDParams struct {
+	ID              uuid.UUID `db:"id" json:"id"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+	TotalBuilds     int32     `db:"total_builds" json:"total_builds"`
+	ProcessedBuilds int32     `db:"processed_builds" json:"processed_builds"`
+}
+
+func (q *sqlQuerier) UpdateTemplateDeadlineRecalculationProgressByID(ctx context.Context, arg UpdateTemplateDeadlineRecalculationProgressByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateDeadlineRecalculationProgressByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.TotalBuilds,
+		arg.ProcessedBuilds,
+	)
+	return err
+}
+
+const getTemplateParameterValidation = `-- name: GetTemplateParameterValidation :one
+SELECT
+	template_id, webhook_url, constraints, created_at, updated_at
+FROM
+	template_parameter_validations
+WHERE
+	template_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateParameterValidation(ctx context.Context, templateID uuid.UUID) (TemplateParameterValidation, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateParameterValidation, templateID)
+	var i TemplateParameterValidation
 	err := row.Scan(
-		&i.ID,
-		&i.CoordinatorID,
-		&i.AgentID,
+		&i.TemplateID,
+		&i.WebhookURL,
+		&i.Constraints,
+		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Node,
 	)
 	return i, err
 }
 
-const upsertTailnetCoordinator = `-- name: UpsertTailnetCoordinator :one
+const upsertTemplateParameterValidation = `-- name: UpsertTemplateParameterValidation :one
 INSERT INTO
-	tailnet_coordinators (
-	id,
-	heartbeat_at
-)
+	template_parameter_validations (
+		template_id,
+		webhook_url,
+		constraints,
+		created_at,
+		updated_at
+	)
 VALUES
-	($1, now() at time zone 'utc')
-ON CONFLICT (id)
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(template_id)
 DO UPDATE SET
-  id = $1,
-  heartbeat_at = now() at time zone 'utc'
-RETURNING id, heartbeat_at
+	webhook_url = $2,
+	constraints = $3,
+	updated_at = $5
+RETURNING template_id, webhook_url, constraints, created_at, updated_at
 `
 
-func (q *sqlQuerier) UpsertTailnetCoordinator(ctx context.Context, id uuid.UUID) (TailnetCoordinator, error) {
-	row := q.db.QueryRowContext(ctx, upsertTailnetCoordinator, id)
-	var i TailnetCoordinator
-	err := row.Scan(&i.ID, &i.HeartbeatAt)
+type UpsertTemplateParameterValidationParams struct {
+	TemplateID  uuid.UUID       `db:"template_id" json:"template_id"`
+	WebhookURL  string          `db:"webhook_url" json:"webhook_url"`
+	Constraints json.RawMessage `db:"constraints" json:"constraints"`
+	CreatedAt   time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertTemplateParameterValidation(ctx context.Context, arg UpsertTemplateParameterValidationParams) (TemplateParameterValidation, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplateParameterValidation,
+		arg.TemplateID,
+		arg.WebhookURL,
+		arg.Constraints,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i TemplateParameterValidation
+	err := row.Scan(
+		&i.TemplateID,
+		&i.WebhookURL,
+		&i.Constraints,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
 	return i, err
 }
 
@@ -4194,7 +7126,7 @@ func (q *sqlQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg GetTem
 
 const getTemplateByID = `-- name: GetTemplateByID :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, created_by_avatar_url, created_by_username
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, restart_requirement_timezone, activity_bump_ttl, max_concurrent_builds, restart_requirement_spread, max_deadline_extensions_per_day, max_deadline_extension, required_promotion_approvals, update_on_restart, failure_retries, failure_retry_backoff, schedule_policy_template_id, max_lifetime, archive_retention, user_cpu_quota_millicores, user_memory_quota_bytes, user_disk_quota_bytes, apply_dotfiles, disable_autostop, created_by_avatar_url, created_by_username
 FROM
 	template_with_users
 WHERE
@@ -4231,72 +7163,189 @@ func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Templat
 		&i.LockedTTL,
 		&i.RestartRequirementDaysOfWeek,
 		&i.RestartRequirementWeeks,
+		&i.RestartRequirementTimezone,
+		&i.ActivityBumpTTL,
+		&i.MaxConcurrentBuilds,
+		&i.RestartRequirementSpread,
+		&i.MaxDeadlineExtensionsPerDay,
+		&i.MaxDeadlineExtension,
+		&i.RequiredPromotionApprovals,
+		&i.UpdateOnRestart,
+		&i.FailureRetries,
+		&i.FailureRetryBackoff,
+		&i.SchedulePolicyTemplateID,
+		&i.MaxLifetime,
+		&i.ArchiveRetention,
+		&i.UserCPUQuotaMillicores,
+		&i.UserMemoryQuotaBytes,
+		&i.UserDiskQuotaBytes,
+		&i.ApplyDotfiles,
+		&i.DisableAutostop,
+		&i.CreatedByAvatarURL,
+		&i.CreatedByUsername,
+	)
+	return i, err
+}
+
+const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
+SELECT
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, restart_requirement_timezone, activity_bump_ttl, max_concurrent_builds, restart_requirement_spread, max_deadline_extensions_per_day, max_deadline_extension, required_promotion_approvals, update_on_restart, failure_retries, failure_retry_backoff, schedule_policy_template_id, max_lifetime, archive_retention, user_cpu_quota_millicores, user_memory_quota_bytes, user_disk_quota_bytes, apply_dotfiles, disable_autostop, created_by_avatar_url, created_by_username
+FROM
+	template_with_users AS templates
+WHERE
+	organization_id = $1
+	AND deleted = $2
+	AND LOWER("name") = LOWER($3)
+LIMIT
+	1
+`
+
+type GetTemplateByOrganizationAndNameParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Deleted        bool      `db:"deleted" json:"deleted"`
+	Name           string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateByOrganizationAndName, arg.OrganizationID, arg.Deleted, arg.Name)
+	var i Template
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.Deleted,
+		&i.Name,
+		&i.Provisioner,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.MaxTTL,
+		&i.AllowUserAutostart,
+		&i.AllowUserAutostop,
+		&i.FailureTTL,
+		&i.InactivityTTL,
+		&i.LockedTTL,
+		&i.RestartRequirementDaysOfWeek,
+		&i.RestartRequirementWeeks,
+		&i.RestartRequirementTimezone,
+		&i.ActivityBumpTTL,
+		&i.MaxConcurrentBuilds,
+		&i.RestartRequirementSpread,
+		&i.MaxDeadlineExtensionsPerDay,
+		&i.MaxDeadlineExtension,
+		&i.RequiredPromotionApprovals,
+		&i.UpdateOnRestart,
+		&i.FailureRetries,
+		&i.FailureRetryBackoff,
+		&i.SchedulePolicyTemplateID,
+		&i.MaxLifetime,
+		&i.ArchiveRetention,
+		&i.UserCPUQuotaMillicores,
+		&i.UserMemoryQuotaBytes,
+		&i.UserDiskQuotaBytes,
+		&i.ApplyDotfiles,
+		&i.DisableAutostop,
 		&i.CreatedByAvatarURL,
 		&i.CreatedByUsername,
 	)
 	return i, err
 }
 
-const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
+const getTemplates = `-- name: GetTemplates :many
+SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, restart_requirement_timezone, activity_bump_ttl, max_concurrent_builds, restart_requirement_spread, max_deadline_extensions_per_day, max_deadline_extension, required_promotion_approvals, update_on_restart, failure_retries, failure_retry_backoff, schedule_policy_template_id, max_lifetime, archive_retention, user_cpu_quota_millicores, user_memory_quota_bytes, user_disk_quota_bytes, apply_dotfiles, disable_autostop, created_by_avatar_url, created_by_username FROM template_with_users AS templates
+ORDER BY (name, id) ASC
+`
+
+func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Template
+	for rows.Next() {
+		var i Template
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OrganizationID,
+			&i.Deleted,
+			&i.Name,
+			&i.Provisioner,
+			&i.ActiveVersionID,
+			&i.Description,
+			&i.DefaultTTL,
+			&i.CreatedBy,
+			&i.Icon,
+			&i.UserACL,
+			&i.GroupACL,
+			&i.DisplayName,
+			&i.AllowUserCancelWorkspaceJobs,
+			&i.MaxTTL,
+			&i.AllowUserAutostart,
+			&i.AllowUserAutostop,
+			&i.FailureTTL,
+			&i.InactivityTTL,
+			&i.LockedTTL,
+			&i.RestartRequirementDaysOfWeek,
+			&i.RestartRequirementWeeks,
+			&i.RestartRequirementTimezone,
+			&i.ActivityBumpTTL,
+			&i.MaxConcurrentBuilds,
+			&i.RestartRequirementSpread,
+			&i.MaxDeadlineExtensionsPerDay,
+			&i.MaxDeadlineExtension,
+			&i.RequiredPromotionApprovals,
+			&i.UpdateOnRestart,
+			&i.FailureRetries,
+			&i.FailureRetryBackoff,
+			&i.SchedulePolicyTemplateID,
+			&i.MaxLifetime,
+			&i.ArchiveRetention,
+			&i.UserCPUQuotaMillicores,
+			&i.UserMemoryQuotaBytes,
+			&i.UserDiskQuotaBytes,
+			&i.ApplyDotfiles,
+			&i.DisableAutostop,
+			&i.CreatedByAvatarURL,
+			&i.CreatedByUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplatesBySchedulePolicyTemplateID = `-- name: GetTemplatesBySchedulePolicyTemplateID :many
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, created_by_avatar_url, created_by_username
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, restart_requirement_timezone, activity_bump_ttl, max_concurrent_builds, restart_requirement_spread, max_deadline_extensions_per_day, max_deadline_extension, required_promotion_approvals, update_on_restart, failure_retries, failure_retry_backoff, schedule_policy_template_id, max_lifetime, archive_retention, user_cpu_quota_millicores, user_memory_quota_bytes, user_disk_quota_bytes, apply_dotfiles, disable_autostop, created_by_avatar_url, created_by_username
 FROM
 	template_with_users AS templates
 WHERE
-	organization_id = $1
-	AND deleted = $2
-	AND LOWER("name") = LOWER($3)
-LIMIT
-	1
-`
-
-type GetTemplateByOrganizationAndNameParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	Deleted        bool      `db:"deleted" json:"deleted"`
-	Name           string    `db:"name" json:"name"`
-}
-
-func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateByOrganizationAndName, arg.OrganizationID, arg.Deleted, arg.Name)
-	var i Template
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
-		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
-		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
-		&i.MaxTTL,
-		&i.AllowUserAutostart,
-		&i.AllowUserAutostop,
-		&i.FailureTTL,
-		&i.InactivityTTL,
-		&i.LockedTTL,
-		&i.RestartRequirementDaysOfWeek,
-		&i.RestartRequirementWeeks,
-		&i.CreatedByAvatarURL,
-		&i.CreatedByUsername,
-	)
-	return i, err
-}
-
-const getTemplates = `-- name: GetTemplates :many
-SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, created_by_avatar_url, created_by_username FROM template_with_users AS templates
+	schedule_policy_template_id = $1
+	AND deleted = false
 ORDER BY (name, id) ASC
 `
 
-func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplates)
+// Returns the templates that inherit their schedule settings from the given
+// template.
+func (q *sqlQuerier) GetTemplatesBySchedulePolicyTemplateID(ctx context.Context, schedulePolicyTemplateID uuid.NullUUID) ([]Template, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplatesBySchedulePolicyTemplateID, schedulePolicyTemplateID)
 	if err != nil {
 		return nil, err
 	}
@@ -4329,6 +7378,24 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 			&i.LockedTTL,
 			&i.RestartRequirementDaysOfWeek,
 			&i.RestartRequirementWeeks,
+			&i.RestartRequirementTimezone,
+			&i.ActivityBumpTTL,
+			&i.MaxConcurrentBuilds,
+			&i.RestartRequirementSpread,
+			&i.MaxDeadlineExtensionsPerDay,
+			&i.MaxDeadlineExtension,
+			&i.RequiredPromotionApprovals,
+			&i.UpdateOnRestart,
+			&i.FailureRetries,
+			&i.FailureRetryBackoff,
+			&i.SchedulePolicyTemplateID,
+			&i.MaxLifetime,
+			&i.ArchiveRetention,
+			&i.UserCPUQuotaMillicores,
+			&i.UserMemoryQuotaBytes,
+			&i.UserDiskQuotaBytes,
+			&i.ApplyDotfiles,
+			&i.DisableAutostop,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 		); err != nil {
@@ -4347,7 +7414,7 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 
 const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, created_by_avatar_url, created_by_username
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, max_ttl, allow_user_autostart, allow_user_autostop, failure_ttl, inactivity_ttl, locked_ttl, restart_requirement_days_of_week, restart_requirement_weeks, restart_requirement_timezone, activity_bump_ttl, max_concurrent_builds, restart_requirement_spread, max_deadline_extensions_per_day, max_deadline_extension, required_promotion_approvals, update_on_restart, failure_retries, failure_retry_backoff, schedule_policy_template_id, max_lifetime, archive_retention, user_cpu_quota_millicores, user_memory_quota_bytes, user_disk_quota_bytes, apply_dotfiles, disable_autostop, created_by_avatar_url, created_by_username
 FROM
 	template_with_users AS templates
 WHERE
@@ -4422,6 +7489,24 @@ func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplate
 			&i.LockedTTL,
 			&i.RestartRequirementDaysOfWeek,
 			&i.RestartRequirementWeeks,
+			&i.RestartRequirementTimezone,
+			&i.ActivityBumpTTL,
+			&i.MaxConcurrentBuilds,
+			&i.RestartRequirementSpread,
+			&i.MaxDeadlineExtensionsPerDay,
+			&i.MaxDeadlineExtension,
+			&i.RequiredPromotionApprovals,
+			&i.UpdateOnRestart,
+			&i.FailureRetries,
+			&i.FailureRetryBackoff,
+			&i.SchedulePolicyTemplateID,
+			&i.MaxLifetime,
+			&i.ArchiveRetention,
+			&i.UserCPUQuotaMillicores,
+			&i.UserMemoryQuotaBytes,
+			&i.UserDiskQuotaBytes,
+			&i.ApplyDotfiles,
+			&i.DisableAutostop,
 			&i.CreatedByAvatarURL,
 			&i.CreatedByUsername,
 		); err != nil {
@@ -4569,7 +7654,16 @@ SET
 	name = $4,
 	icon = $5,
 	display_name = $6,
-	allow_user_cancel_workspace_jobs = $7
+	allow_user_cancel_workspace_jobs = $7,
+	max_concurrent_builds = $8,
+	max_deadline_extensions_per_day = $9,
+	max_deadline_extension = $10,
+	required_promotion_approvals = $11,
+	user_cpu_quota_millicores = $12,
+	user_memory_quota_bytes = $13,
+	user_disk_quota_bytes = $14,
+	apply_dotfiles = $15,
+	disable_autostop = $16
 WHERE
 	id = $1
 `
@@ -4582,6 +7676,15 @@ type UpdateTemplateMetaByIDParams struct {
 	Icon                         string    `db:"icon" json:"icon"`
 	DisplayName                  string    `db:"display_name" json:"display_name"`
 	AllowUserCancelWorkspaceJobs bool      `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
+	MaxConcurrentBuilds          int32     `db:"max_concurrent_builds" json:"max_concurrent_builds"`
+	MaxDeadlineExtensionsPerDay  int32     `db:"max_deadline_extensions_per_day" json:"max_deadline_extensions_per_day"`
+	MaxDeadlineExtension         int64     `db:"max_deadline_extension" json:"max_deadline_extension"`
+	RequiredPromotionApprovals   int32     `db:"required_promotion_approvals" json:"required_promotion_approvals"`
+	UserCPUQuotaMillicores       int64     `db:"user_cpu_quota_millicores" json:"user_cpu_quota_millicores"`
+	UserMemoryQuotaBytes         int64     `db:"user_memory_quota_bytes" json:"user_memory_quota_bytes"`
+	UserDiskQuotaBytes           int64     `db:"user_disk_quota_bytes" json:"user_disk_quota_bytes"`
+	ApplyDotfiles                bool      `db:"apply_dotfiles" json:"apply_dotfiles"`
+	DisableAutostop              bool      `db:"disable_autostop" json:"disable_autostop"`
 }
 
 func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) error {
@@ -4593,6 +7696,15 @@ func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTempl
 		arg.Icon,
 		arg.DisplayName,
 		arg.AllowUserCancelWorkspaceJobs,
+		arg.MaxConcurrentBuilds,
+		arg.MaxDeadlineExtensionsPerDay,
+		arg.MaxDeadlineExtension,
+		arg.RequiredPromotionApprovals,
+		arg.UserCPUQuotaMillicores,
+		arg.UserMemoryQuotaBytes,
+		arg.UserDiskQuotaBytes,
+		arg.ApplyDotfiles,
+		arg.DisableAutostop,
 	)
 	return err
 }
@@ -4608,9 +7720,17 @@ SET
 	max_ttl = $6,
 	restart_requirement_days_of_week = $7,
 	restart_requirement_weeks = $8,
-	failure_ttl = $9,
-	inactivity_ttl = $10,
-	locked_ttl = $11
+	restart_requirement_timezone = $9,
+	failure_ttl = $10,
+	inactivity_ttl = $11,
+	locked_ttl = $12,
+	activity_bump_ttl = $13,
+	restart_requirement_spread = $14,
+	update_on_restart = $15,
+	failure_retries = $16,
+	failure_retry_backoff = $17,
+	max_lifetime = $18,
+	archive_retention = $19
 WHERE
 	id = $1
 `
@@ -4624,9 +7744,17 @@ type UpdateTemplateScheduleByIDParams struct {
 	MaxTTL                       int64     `db:"max_ttl" json:"max_ttl"`
 	RestartRequirementDaysOfWeek int16     `db:"restart_requirement_days_of_week" json:"restart_requirement_days_of_week"`
 	RestartRequirementWeeks      int64     `db:"restart_requirement_weeks" json:"restart_requirement_weeks"`
+	RestartRequirementTimezone   string    `db:"restart_requirement_timezone" json:"restart_requirement_timezone"`
 	FailureTTL                   int64     `db:"failure_ttl" json:"failure_ttl"`
 	InactivityTTL                int64     `db:"inactivity_ttl" json:"inactivity_ttl"`
 	LockedTTL                    int64     `db:"locked_ttl" json:"locked_ttl"`
+	ActivityBumpTTL              int64     `db:"activity_bump_ttl" json:"activity_bump_ttl"`
+	RestartRequirementSpread     int64     `db:"restart_requirement_spread" json:"restart_requirement_spread"`
+	UpdateOnRestart              bool      `db:"update_on_restart" json:"update_on_restart"`
+	FailureRetries               int32     `db:"failure_retries" json:"failure_retries"`
+	FailureRetryBackoff          int64     `db:"failure_retry_backoff" json:"failure_retry_backoff"`
+	MaxLifetime                  int64     `db:"max_lifetime" json:"max_lifetime"`
+	ArchiveRetention             int64     `db:"archive_retention" json:"archive_retention"`
 }
 
 func (q *sqlQuerier) UpdateTemplateScheduleByID(ctx context.Context, arg UpdateTemplateScheduleByIDParams) error {
@@ -4639,13 +7767,42 @@ func (q *sqlQuerier) UpdateTemplateScheduleByID(ctx context.Context, arg UpdateT
 		arg.MaxTTL,
 		arg.RestartRequirementDaysOfWeek,
 		arg.RestartRequirementWeeks,
+		arg.RestartRequirementTimezone,
 		arg.FailureTTL,
 		arg.InactivityTTL,
 		arg.LockedTTL,
+		arg.ActivityBumpTTL,
+		arg.RestartRequirementSpread,
+		arg.UpdateOnRestart,
+		arg.FailureRetries,
+		arg.FailureRetryBackoff,
+		arg.MaxLifetime,
+		arg.ArchiveRetention,
 	)
 	return err
 }
 
+const updateTemplateSchedulePolicyByID = `-- name: UpdateTemplateSchedulePolicyByID :exec
+UPDATE
+	templates
+SET
+	updated_at = $2,
+	schedule_policy_template_id = $3
+WHERE
+	id = $1
+`
+
+type UpdateTemplateSchedulePolicyByIDParams struct {
+	ID                       uuid.UUID     `db:"id" json:"id"`
+	UpdatedAt                time.Time     `db:"updated_at" json:"updated_at"`
+	SchedulePolicyTemplateID uuid.NullUUID `db:"schedule_policy_template_id" json:"schedule_policy_template_id"`
+}
+
+func (q *sqlQuerier) UpdateTemplateSchedulePolicyByID(ctx context.Context, arg UpdateTemplateSchedulePolicyByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateSchedulePolicyByID, arg.ID, arg.UpdatedAt, arg.SchedulePolicyTemplateID)
+	return err
+}
+
 const getTemplateVersionParameters = `-- name: GetTemplateVersionParameters :many
 SELECT template_version_id, name, description, type, mutable, default_value, icon, options, validation_regex, validation_min, validation_max, validation_error, validation_monotonic, required, display_name, display_order, ephemeral FROM template_version_parameters WHERE template_version_id = $1 ORDER BY display_order ASC, LOWER(name) ASC
 `
@@ -4777,22 +7934,302 @@ func (q *sqlQuerier) InsertTemplateVersionParameter(ctx context.Context, arg Ins
 	var i TemplateVersionParameter
 	err := row.Scan(
 		&i.TemplateVersionID,
-		&i.Name,
-		&i.Description,
-		&i.Type,
-		&i.Mutable,
-		&i.DefaultValue,
-		&i.Icon,
-		&i.Options,
-		&i.ValidationRegex,
-		&i.ValidationMin,
-		&i.ValidationMax,
-		&i.ValidationError,
-		&i.ValidationMonotonic,
-		&i.Required,
-		&i.DisplayName,
-		&i.DisplayOrder,
-		&i.Ephemeral,
+		&i.Name,
+		&i.Description,
+		&i.Type,
+		&i.Mutable,
+		&i.DefaultValue,
+		&i.Icon,
+		&i.Options,
+		&i.ValidationRegex,
+		&i.ValidationMin,
+		&i.ValidationMax,
+		&i.ValidationError,
+		&i.ValidationMonotonic,
+		&i.Required,
+		&i.DisplayName,
+		&i.DisplayOrder,
+		&i.Ephemeral,
+	)
+	return i, err
+}
+
+const approveTemplateVersionPromotion = `-- name: ApproveTemplateVersionPromotion :one
+UPDATE
+	template_version_promotions
+SET
+	updated_at = $1,
+	approved_by = array_append(approved_by, $2 :: uuid)
+WHERE
+	id = $3
+	AND status = 'pending'
+	AND NOT ($2 :: uuid = ANY(approved_by))
+RETURNING id, template_id, template_version_id, requested_by, created_at, updated_at, status, approved_by
+`
+
+type ApproveTemplateVersionPromotionParams struct {
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	ID        uuid.UUID `db:"id" json:"id"`
+}
+
+// Adds an approval to a pending promotion. Returns no rows if the promotion
+// isn't pending or the user already approved it.
+func (q *sqlQuerier) ApproveTemplateVersionPromotion(ctx context.Context, arg ApproveTemplateVersionPromotionParams) (TemplateVersionPromotion, error) {
+	row := q.db.QueryRowContext(ctx, approveTemplateVersionPromotion, arg.UpdatedAt, arg.UserID, arg.ID)
+	var i TemplateVersionPromotion
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		pq.Array(&i.ApprovedBy),
+	)
+	return i, err
+}
+
+const getTemplateVersionPresets = `-- name: GetTemplateVersionPresets :many
+SELECT
+	template_version_id, name, description, parameters, created_at
+FROM
+	template_version_presets
+WHERE
+	template_version_id = $1
+ORDER BY
+	name ASC
+`
+
+func (q *sqlQuerier) GetTemplateVersionPresets(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionPreset, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionPresets, templateVersionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersionPreset
+	for rows.Next() {
+		var i TemplateVersionPreset
+		if err := rows.Scan(
+			&i.TemplateVersionID,
+			&i.Name,
+			&i.Description,
+			&i.Parameters,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertTemplateVersionPreset = `-- name: InsertTemplateVersionPreset :one
+INSERT INTO
+	template_version_presets (
+		template_version_id,
+		name,
+		description,
+		parameters,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING template_version_id, name, description, parameters, created_at
+`
+
+type InsertTemplateVersionPresetParams struct {
+	TemplateVersionID uuid.UUID       `db:"template_version_id" json:"template_version_id"`
+	Name              string          `db:"name" json:"name"`
+	Description       string          `db:"description" json:"description"`
+	Parameters        json.RawMessage `db:"parameters" json:"parameters"`
+	CreatedAt         time.Time       `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertTemplateVersionPreset(ctx context.Context, arg InsertTemplateVersionPresetParams) (TemplateVersionPreset, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersionPreset,
+		arg.TemplateVersionID,
+		arg.Name,
+		arg.Description,
+		arg.Parameters,
+		arg.CreatedAt,
+	)
+	var i TemplateVersionPreset
+	err := row.Scan(
+		&i.TemplateVersionID,
+		&i.Name,
+		&i.Description,
+		&i.Parameters,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTemplateVersionPromotionByID = `-- name: GetTemplateVersionPromotionByID :one
+SELECT
+	id, template_id, template_version_id, requested_by, created_at, updated_at, status, approved_by
+FROM
+	template_version_promotions
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetTemplateVersionPromotionByID(ctx context.Context, id uuid.UUID) (TemplateVersionPromotion, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionPromotionByID, id)
+	var i TemplateVersionPromotion
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		pq.Array(&i.ApprovedBy),
+	)
+	return i, err
+}
+
+const getTemplateVersionPromotionsByTemplateID = `-- name: GetTemplateVersionPromotionsByTemplateID :many
+SELECT
+	id, template_id, template_version_id, requested_by, created_at, updated_at, status, approved_by
+FROM
+	template_version_promotions
+WHERE
+	template_id = $1
+	AND CASE
+		WHEN $2 :: text != '' THEN
+			status = $2 :: template_version_promotion_status
+		ELSE true
+	END
+ORDER BY
+	created_at DESC
+`
+
+type GetTemplateVersionPromotionsByTemplateIDParams struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	Status     string    `db:"status" json:"status"`
+}
+
+func (q *sqlQuerier) GetTemplateVersionPromotionsByTemplateID(ctx context.Context, arg GetTemplateVersionPromotionsByTemplateIDParams) ([]TemplateVersionPromotion, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionPromotionsByTemplateID, arg.TemplateID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersionPromotion
+	for rows.Next() {
+		var i TemplateVersionPromotion
+		if err := rows.Scan(
+			&i.ID,
+			&i.TemplateID,
+			&i.TemplateVersionID,
+			&i.RequestedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			pq.Array(&i.ApprovedBy),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertTemplateVersionPromotion = `-- name: InsertTemplateVersionPromotion :one
+INSERT INTO
+	template_version_promotions (
+		id,
+		template_id,
+		template_version_id,
+		requested_by,
+		created_at,
+		updated_at,
+		status
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7) RETURNING id, template_id, template_version_id, requested_by, created_at, updated_at, status, approved_by
+`
+
+type InsertTemplateVersionPromotionParams struct {
+	ID                uuid.UUID                      `db:"id" json:"id"`
+	TemplateID        uuid.UUID                      `db:"template_id" json:"template_id"`
+	TemplateVersionID uuid.UUID                      `db:"template_version_id" json:"template_version_id"`
+	RequestedBy       uuid.UUID                      `db:"requested_by" json:"requested_by"`
+	CreatedAt         time.Time                      `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time                      `db:"updated_at" json:"updated_at"`
+	Status            TemplateVersionPromotionStatus `db:"status" json:"status"`
+}
+
+func (q *sqlQuerier) InsertTemplateVersionPromotion(ctx context.Context, arg InsertTemplateVersionPromotionParams) (TemplateVersionPromotion, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersionPromotion,
+		arg.ID,
+		arg.TemplateID,
+		arg.TemplateVersionID,
+		arg.RequestedBy,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Status,
+	)
+	var i TemplateVersionPromotion
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		pq.Array(&i.ApprovedBy),
+	)
+	return i, err
+}
+
+const updateTemplateVersionPromotionStatusByID = `-- name: UpdateTemplateVersionPromotionStatusByID :one
+UPDATE
+	template_version_promotions
+SET
+	updated_at = $2,
+	status = $3
+WHERE
+	id = $1
+	AND status = 'pending'
+RETURNING id, template_id, template_version_id, requested_by, created_at, updated_at, status, approved_by
+`
+
+type UpdateTemplateVersionPromotionStatusByIDParams struct {
+	ID        uuid.UUID                      `db:"id" json:"id"`
+	UpdatedAt time.Time                      `db:"updated_at" json:"updated_at"`
+	Status    TemplateVersionPromotionStatus `db:"status" json:"status"`
+}
+
+// Resolves a pending promotion. Returns no rows if the promotion isn't
+// pending.
+func (q *sqlQuerier) UpdateTemplateVersionPromotionStatusByID(ctx context.Context, arg UpdateTemplateVersionPromotionStatusByIDParams) (TemplateVersionPromotion, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateVersionPromotionStatusByID, arg.ID, arg.UpdatedAt, arg.Status)
+	var i TemplateVersionPromotion
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
+		&i.TemplateVersionID,
+		&i.RequestedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		pq.Array(&i.ApprovedBy),
 	)
 	return i, err
 }
@@ -5488,6 +8925,159 @@ func (q *sqlQuerier) UpdateUserLinkedID(ctx context.Context, arg UpdateUserLinke
 	return i, err
 }
 
+const deleteUserDotfiles = `-- name: DeleteUserDotfiles :exec
+DELETE FROM
+	user_dotfiles
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) DeleteUserDotfiles(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteUserDotfiles, userID)
+	return err
+}
+
+const getUserDotfiles = `-- name: GetUserDotfiles :one
+SELECT
+	user_id, repo_url, branch, updated_at
+FROM
+	user_dotfiles
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) GetUserDotfiles(ctx context.Context, userID uuid.UUID) (UserDotfile, error) {
+	row := q.db.QueryRowContext(ctx, getUserDotfiles, userID)
+	var i UserDotfile
+	err := row.Scan(
+		&i.UserID,
+		&i.RepoURL,
+		&i.Branch,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertUserDotfiles = `-- name: UpsertUserDotfiles :one
+INSERT INTO
+	user_dotfiles (
+		user_id,
+		repo_url,
+		branch,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT
+	(user_id)
+DO UPDATE SET
+	repo_url = $2,
+	branch = $3,
+	updated_at = $4
+RETURNING user_id, repo_url, branch, updated_at
+`
+
+type UpsertUserDotfilesParams struct {
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	RepoURL   string    `db:"repo_url" json:"repo_url"`
+	Branch    string    `db:"branch" json:"branch"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertUserDotfiles(ctx context.Context, arg UpsertUserDotfilesParams) (UserDotfile, error) {
+	row := q.db.QueryRowContext(ctx, upsertUserDotfiles,
+		arg.UserID,
+		arg.RepoURL,
+		arg.Branch,
+		arg.UpdatedAt,
+	)
+	var i UserDotfile
+	err := row.Scan(
+		&i.UserID,
+		&i.RepoURL,
+		&i.Branch,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteUserQuietHoursException = `-- name: DeleteUserQuietHoursException :exec
+DELETE FROM
+	user_quiet_hours_exceptions
+WHERE
+	user_id = $1
+	AND date = $2
+`
+
+type DeleteUserQuietHoursExceptionParams struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	Date   time.Time `db:"date" json:"date"`
+}
+
+func (q *sqlQuerier) DeleteUserQuietHoursException(ctx context.Context, arg DeleteUserQuietHoursExceptionParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserQuietHoursException, arg.UserID, arg.Date)
+	return err
+}
+
+const getUserQuietHoursExceptions = `-- name: GetUserQuietHoursExceptions :many
+SELECT
+	user_id, date, created_at
+FROM
+	user_quiet_hours_exceptions
+WHERE
+	user_id = $1
+ORDER BY
+	date ASC
+`
+
+func (q *sqlQuerier) GetUserQuietHoursExceptions(ctx context.Context, userID uuid.UUID) ([]UserQuietHoursException, error) {
+	rows, err := q.db.QueryContext(ctx, getUserQuietHoursExceptions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserQuietHoursException
+	for rows.Next() {
+		var i UserQuietHoursException
+		if err := rows.Scan(&i.UserID, &i.Date, &i.CreatedAt); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertUserQuietHoursException = `-- name: InsertUserQuietHoursException :one
+INSERT INTO
+	user_quiet_hours_exceptions (
+		user_id,
+		date,
+		created_at
+	)
+VALUES
+	($1, $2, $3)
+RETURNING user_id, date, created_at
+`
+
+type InsertUserQuietHoursExceptionParams struct {
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Date      time.Time `db:"date" json:"date"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+func (q *sqlQuerier) InsertUserQuietHoursException(ctx context.Context, arg InsertUserQuietHoursExceptionParams) (UserQuietHoursException, error) {
+	row := q.db.QueryRowContext(ctx, insertUserQuietHoursException, arg.UserID, arg.Date, arg.CreatedAt)
+	var i UserQuietHoursException
+	err := row.Scan(&i.UserID, &i.Date, &i.CreatedAt)
+	return i, err
+}
+
 const getActiveUserCount = `-- name: GetActiveUserCount :one
 SELECT
 	COUNT(*)
@@ -6161,65 +9751,244 @@ WHERE
 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule
 `
 
-type UpdateUserRolesParams struct {
-	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
-	ID           uuid.UUID `db:"id" json:"id"`
+type UpdateUserRolesParams struct {
+	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
+	ID           uuid.UUID `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateUserRoles(ctx context.Context, arg UpdateUserRolesParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRoles, pq.Array(arg.GrantedRoles), arg.ID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
+		&i.QuietHoursSchedule,
+	)
+	return i, err
+}
+
+const updateUserStatus = `-- name: UpdateUserStatus :one
+UPDATE
+	users
+SET
+	status = $2,
+	updated_at = $3
+WHERE
+	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule
+`
+
+type UpdateUserStatusParams struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	Status    UserStatus `db:"status" json:"status"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserStatus, arg.ID, arg.Status, arg.UpdatedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
+		&i.QuietHoursSchedule,
+	)
+	return i, err
+}
+
+const deleteUserSecret = `-- name: DeleteUserSecret :exec
+DELETE FROM
+	user_secrets
+WHERE
+	user_id = $1
+	AND name = $2
+`
+
+type DeleteUserSecretParams struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	Name   string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) DeleteUserSecret(ctx context.Context, arg DeleteUserSecretParams) error {
+	_, err := q.db.ExecContext(ctx, deleteUserSecret, arg.UserID, arg.Name)
+	return err
+}
+
+const getUserSecretByUserIDAndName = `-- name: GetUserSecretByUserIDAndName :one
+SELECT
+	id, user_id, name, description, value, created_at, updated_at
+FROM
+	user_secrets
+WHERE
+	user_id = $1
+	AND name = $2
+`
+
+type GetUserSecretByUserIDAndNameParams struct {
+	UserID uuid.UUID `db:"user_id" json:"user_id"`
+	Name   string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetUserSecretByUserIDAndName(ctx context.Context, arg GetUserSecretByUserIDAndNameParams) (UserSecret, error) {
+	row := q.db.QueryRowContext(ctx, getUserSecretByUserIDAndName, arg.UserID, arg.Name)
+	var i UserSecret
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Value,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getUserSecretsByUserID = `-- name: GetUserSecretsByUserID :many
+SELECT
+	id, user_id, name, description, value, created_at, updated_at
+FROM
+	user_secrets
+WHERE
+	user_id = $1
+ORDER BY
+	name ASC
+`
+
+func (q *sqlQuerier) GetUserSecretsByUserID(ctx context.Context, userID uuid.UUID) ([]UserSecret, error) {
+	rows, err := q.db.QueryContext(ctx, getUserSecretsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []UserSecret
+	for rows.Next() {
+		var i UserSecret
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Description,
+			&i.Value,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertUserSecret = `-- name: InsertUserSecret :one
+INSERT INTO
+	user_secrets (
+		id,
+		user_id,
+		name,
+		description,
+		value,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7) RETURNING id, user_id, name, description, value, created_at, updated_at
+`
+
+type InsertUserSecretParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	UserID      uuid.UUID `db:"user_id" json:"user_id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	Value       []byte    `db:"value" json:"value"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateUserRoles(ctx context.Context, arg UpdateUserRolesParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserRoles, pq.Array(arg.GrantedRoles), arg.ID)
-	var i User
+func (q *sqlQuerier) InsertUserSecret(ctx context.Context, arg InsertUserSecretParams) (UserSecret, error) {
+	row := q.db.QueryRowContext(ctx, insertUserSecret,
+		arg.ID,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.Value,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+	)
+	var i UserSecret
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Value,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
-		&i.QuietHoursSchedule,
 	)
 	return i, err
 }
 
-const updateUserStatus = `-- name: UpdateUserStatus :one
+const updateUserSecret = `-- name: UpdateUserSecret :one
 UPDATE
-	users
+	user_secrets
 SET
-	status = $2,
-	updated_at = $3
+	description = $3,
+	value = $4,
+	updated_at = $5
 WHERE
-	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, quiet_hours_schedule
+	user_id = $1
+	AND name = $2 RETURNING id, user_id, name, description, value, created_at, updated_at
 `
 
-type UpdateUserStatusParams struct {
-	ID        uuid.UUID  `db:"id" json:"id"`
-	Status    UserStatus `db:"status" json:"status"`
-	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+type UpdateUserSecretParams struct {
+	UserID      uuid.UUID `db:"user_id" json:"user_id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	Value       []byte    `db:"value" json:"value"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserStatus, arg.ID, arg.Status, arg.UpdatedAt)
-	var i User
+func (q *sqlQuerier) UpdateUserSecret(ctx context.Context, arg UpdateUserSecretParams) (UserSecret, error) {
+	row := q.db.QueryRowContext(ctx, updateUserSecret,
+		arg.UserID,
+		arg.Name,
+		arg.Description,
+		arg.Value,
+		arg.UpdatedAt,
+	)
+	var i UserSecret
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
+		&i.UserID,
+		&i.Name,
+		&i.Description,
+		&i.Value,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
-		&i.QuietHoursSchedule,
 	)
 	return i, err
 }
@@ -6237,6 +10006,25 @@ func (q *sqlQuerier) DeleteOldWorkspaceAgentLogs(ctx context.Context) error {
 	return err
 }
 
+const deleteWorkspaceAgentPluginMetadata = `-- name: DeleteWorkspaceAgentPluginMetadata :exec
+DELETE FROM
+	workspace_agent_metadata
+WHERE
+	workspace_agent_id = $1
+	AND plugin
+	AND NOT (key = ANY($2 :: text[]))
+`
+
+type DeleteWorkspaceAgentPluginMetadataParams struct {
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	Keys             []string  `db:"keys" json:"keys"`
+}
+
+func (q *sqlQuerier) DeleteWorkspaceAgentPluginMetadata(ctx context.Context, arg DeleteWorkspaceAgentPluginMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, deleteWorkspaceAgentPluginMetadata, arg.WorkspaceAgentID, pq.Array(arg.Keys))
+	return err
+}
+
 const getWorkspaceAgentByAuthToken = `-- name: GetWorkspaceAgentByAuthToken :one
 SELECT
 	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, logs_length, logs_overflowed, startup_script_behavior, started_at, ready_at, subsystems
@@ -6391,6 +10179,40 @@ func (q *sqlQuerier) GetWorkspaceAgentByInstanceID(ctx context.Context, authInst
 	return i, err
 }
 
+const getWorkspaceAgentDevcontainersByAgentID = `-- name: GetWorkspaceAgentDevcontainersByAgentID :many
+SELECT
+	workspace_agent_id, workspace_folder, config_path
+FROM
+	workspace_agent_devcontainers
+WHERE
+	workspace_agent_id = $1
+ORDER BY
+	workspace_folder ASC
+`
+
+func (q *sqlQuerier) GetWorkspaceAgentDevcontainersByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]WorkspaceAgentDevcontainer, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentDevcontainersByAgentID, workspaceAgentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAgentDevcontainer
+	for rows.Next() {
+		var i WorkspaceAgentDevcontainer
+		if err := rows.Scan(&i.WorkspaceAgentID, &i.WorkspaceFolder, &i.ConfigPath); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWorkspaceAgentLifecycleStateByID = `-- name: GetWorkspaceAgentLifecycleStateByID :one
 SELECT
 	lifecycle_state,
@@ -6464,7 +10286,7 @@ func (q *sqlQuerier) GetWorkspaceAgentLogsAfter(ctx context.Context, arg GetWork
 
 const getWorkspaceAgentMetadata = `-- name: GetWorkspaceAgentMetadata :many
 SELECT
-	workspace_agent_id, display_name, key, script, value, error, timeout, interval, collected_at
+	workspace_agent_id, display_name, key, script, value, error, timeout, interval, collected_at, plugin
 FROM
 	workspace_agent_metadata
 WHERE
@@ -6490,6 +10312,54 @@ func (q *sqlQuerier) GetWorkspaceAgentMetadata(ctx context.Context, workspaceAge
 			&i.Timeout,
 			&i.Interval,
 			&i.CollectedAt,
+			&i.Plugin,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspaceAgentScriptsByAgentID = `-- name: GetWorkspaceAgentScriptsByAgentID :many
+SELECT
+	workspace_agent_id, name, ordinal, script, run_on_start, run_on_stop, timeout_seconds, continue_on_error, status, exit_code, started_at, ended_at
+FROM
+	workspace_agent_scripts
+WHERE
+	workspace_agent_id = $1
+ORDER BY
+	ordinal ASC
+`
+
+func (q *sqlQuerier) GetWorkspaceAgentScriptsByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]WorkspaceAgentScript, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentScriptsByAgentID, workspaceAgentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAgentScript
+	for rows.Next() {
+		var i WorkspaceAgentScript
+		if err := rows.Scan(
+			&i.WorkspaceAgentID,
+			&i.Name,
+			&i.Ordinal,
+			&i.Script,
+			&i.RunOnStart,
+			&i.RunOnStop,
+			&i.TimeoutSeconds,
+			&i.ContinueOnError,
+			&i.Status,
+			&i.ExitCode,
+			&i.StartedAt,
+			&i.EndedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -6504,6 +10374,40 @@ func (q *sqlQuerier) GetWorkspaceAgentMetadata(ctx context.Context, workspaceAge
 	return items, nil
 }
 
+const getWorkspaceAgentSecretsByAgentID = `-- name: GetWorkspaceAgentSecretsByAgentID :many
+SELECT
+	workspace_agent_id, name
+FROM
+	workspace_agent_secrets
+WHERE
+	workspace_agent_id = $1
+ORDER BY
+	name ASC
+`
+
+func (q *sqlQuerier) GetWorkspaceAgentSecretsByAgentID(ctx context.Context, workspaceAgentID uuid.UUID) ([]WorkspaceAgentSecret, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceAgentSecretsByAgentID, workspaceAgentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkspaceAgentSecret
+	for rows.Next() {
+		var i WorkspaceAgentSecret
+		if err := rows.Scan(&i.WorkspaceAgentID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWorkspaceAgentsByResourceIDs = `-- name: GetWorkspaceAgentsByResourceIDs :many
 SELECT
 	id, created_at, updated_at, name, first_connected_at, last_connected_at, disconnected_at, resource_id, auth_token, auth_instance_id, architecture, environment_variables, operating_system, startup_script, instance_metadata, resource_metadata, directory, version, last_connected_replica_id, connection_timeout_seconds, troubleshooting_url, motd_file, lifecycle_state, startup_script_timeout_seconds, expanded_directory, shutdown_script, shutdown_script_timeout_seconds, logs_length, logs_overflowed, startup_script_behavior, started_at, ready_at, subsystems
@@ -6825,6 +10729,28 @@ func (q *sqlQuerier) InsertWorkspaceAgent(ctx context.Context, arg InsertWorkspa
 	return i, err
 }
 
+const insertWorkspaceAgentDevcontainer = `-- name: InsertWorkspaceAgentDevcontainer :exec
+INSERT INTO
+	workspace_agent_devcontainers (
+		workspace_agent_id,
+		workspace_folder,
+		config_path
+	)
+VALUES
+	($1, $2, $3)
+`
+
+type InsertWorkspaceAgentDevcontainerParams struct {
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	WorkspaceFolder  string    `db:"workspace_folder" json:"workspace_folder"`
+	ConfigPath       string    `db:"config_path" json:"config_path"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceAgentDevcontainer(ctx context.Context, arg InsertWorkspaceAgentDevcontainerParams) error {
+	_, err := q.db.ExecContext(ctx, insertWorkspaceAgentDevcontainer, arg.WorkspaceAgentID, arg.WorkspaceFolder, arg.ConfigPath)
+	return err
+}
+
 const insertWorkspaceAgentLogs = `-- name: InsertWorkspaceAgentLogs :many
 WITH new_length AS (
 	UPDATE workspace_agents SET
@@ -6922,6 +10848,67 @@ func (q *sqlQuerier) InsertWorkspaceAgentMetadata(ctx context.Context, arg Inser
 	return err
 }
 
+const insertWorkspaceAgentScript = `-- name: InsertWorkspaceAgentScript :exec
+INSERT INTO
+	workspace_agent_scripts (
+		workspace_agent_id,
+		name,
+		ordinal,
+		script,
+		run_on_start,
+		run_on_stop,
+		timeout_seconds,
+		continue_on_error
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+`
+
+type InsertWorkspaceAgentScriptParams struct {
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	Name             string    `db:"name" json:"name"`
+	Ordinal          int32     `db:"ordinal" json:"ordinal"`
+	Script           string    `db:"script" json:"script"`
+	RunOnStart       bool      `db:"run_on_start" json:"run_on_start"`
+	RunOnStop        bool      `db:"run_on_stop" json:"run_on_stop"`
+	TimeoutSeconds   int32     `db:"timeout_seconds" json:"timeout_seconds"`
+	ContinueOnError  bool      `db:"continue_on_error" json:"continue_on_error"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceAgentScript(ctx context.Context, arg InsertWorkspaceAgentScriptParams) error {
+	_, err := q.db.ExecContext(ctx, insertWorkspaceAgentScript,
+		arg.WorkspaceAgentID,
+		arg.Name,
+		arg.Ordinal,
+		arg.Script,
+		arg.RunOnStart,
+		arg.RunOnStop,
+		arg.TimeoutSeconds,
+		arg.ContinueOnError,
+	)
+	return err
+}
+
+const insertWorkspaceAgentSecret = `-- name: InsertWorkspaceAgentSecret :exec
+INSERT INTO
+	workspace_agent_secrets (
+		workspace_agent_id,
+		name
+	)
+VALUES
+	($1, $2)
+`
+
+type InsertWorkspaceAgentSecretParams struct {
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	Name             string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceAgentSecret(ctx context.Context, arg InsertWorkspaceAgentSecretParams) error {
+	_, err := q.db.ExecContext(ctx, insertWorkspaceAgentSecret, arg.WorkspaceAgentID, arg.Name)
+	return err
+}
+
 const updateWorkspaceAgentConnectionByID = `-- name: UpdateWorkspaceAgentConnectionByID :exec
 UPDATE
 	workspace_agents
@@ -7034,6 +11021,40 @@ func (q *sqlQuerier) UpdateWorkspaceAgentMetadata(ctx context.Context, arg Updat
 	return err
 }
 
+const updateWorkspaceAgentScriptStatus = `-- name: UpdateWorkspaceAgentScriptStatus :exec
+UPDATE
+	workspace_agent_scripts
+SET
+	status = $3,
+	exit_code = $4,
+	started_at = $5,
+	ended_at = $6
+WHERE
+	workspace_agent_id = $1
+	AND name = $2
+`
+
+type UpdateWorkspaceAgentScriptStatusParams struct {
+	WorkspaceAgentID uuid.UUID                  `db:"workspace_agent_id" json:"workspace_agent_id"`
+	Name             string                     `db:"name" json:"name"`
+	Status           WorkspaceAgentScriptStatus `db:"status" json:"status"`
+	ExitCode         int32                      `db:"exit_code" json:"exit_code"`
+	StartedAt        sql.NullTime               `db:"started_at" json:"started_at"`
+	EndedAt          sql.NullTime               `db:"ended_at" json:"ended_at"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceAgentScriptStatus(ctx context.Context, arg UpdateWorkspaceAgentScriptStatusParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentScriptStatus,
+		arg.WorkspaceAgentID,
+		arg.Name,
+		arg.Status,
+		arg.ExitCode,
+		arg.StartedAt,
+		arg.EndedAt,
+	)
+	return err
+}
+
 const updateWorkspaceAgentStartupByID = `-- name: UpdateWorkspaceAgentStartupByID :exec
 UPDATE
 	workspace_agents
@@ -7062,6 +11083,51 @@ func (q *sqlQuerier) UpdateWorkspaceAgentStartupByID(ctx context.Context, arg Up
 	return err
 }
 
+const upsertWorkspaceAgentPluginMetadata = `-- name: UpsertWorkspaceAgentPluginMetadata :exec
+INSERT INTO
+	workspace_agent_metadata (
+		workspace_agent_id,
+		display_name,
+		key,
+		script,
+		timeout,
+		interval,
+		plugin
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, true)
+ON CONFLICT (workspace_agent_id, key) DO UPDATE SET
+	display_name = EXCLUDED.display_name,
+	script = EXCLUDED.script,
+	timeout = EXCLUDED.timeout,
+	interval = EXCLUDED.interval
+WHERE
+	workspace_agent_metadata.plugin
+`
+
+type UpsertWorkspaceAgentPluginMetadataParams struct {
+	WorkspaceAgentID uuid.UUID `db:"workspace_agent_id" json:"workspace_agent_id"`
+	DisplayName      string    `db:"display_name" json:"display_name"`
+	Key              string    `db:"key" json:"key"`
+	Script           string    `db:"script" json:"script"`
+	Timeout          int64     `db:"timeout" json:"timeout"`
+	Interval         int64     `db:"interval" json:"interval"`
+}
+
+// Registers metadata defined at runtime by the metadata plugins of an agent.
+// Metadata defined in the template is never overwritten.
+func (q *sqlQuerier) UpsertWorkspaceAgentPluginMetadata(ctx context.Context, arg UpsertWorkspaceAgentPluginMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, upsertWorkspaceAgentPluginMetadata,
+		arg.WorkspaceAgentID,
+		arg.DisplayName,
+		arg.Key,
+		arg.Script,
+		arg.Timeout,
+		arg.Interval,
+	)
+	return err
+}
+
 const deleteOldWorkspaceAgentStats = `-- name: DeleteOldWorkspaceAgentStats :exec
 DELETE FROM workspace_agent_stats WHERE created_at < NOW() - INTERVAL '30 days'
 `
@@ -7130,7 +11196,7 @@ WITH agent_stats AS (
 		coalesce(SUM(session_count_jetbrains), 0)::bigint AS session_count_jetbrains,
 		coalesce(SUM(session_count_reconnecting_pty), 0)::bigint AS session_count_reconnecting_pty
 	 FROM (
-		SELECT id, created_at, user_id, agent_id, workspace_id, template_id, connections_by_proto, connection_count, rx_packets, rx_bytes, tx_packets, tx_bytes, connection_median_latency_ms, session_count_vscode, session_count_jetbrains, session_count_reconnecting_pty, session_count_ssh, ROW_NUMBER() OVER(PARTITION BY agent_id ORDER BY created_at DESC) AS rn
+		SELECT id, created_at, user_id, agent_id, workspace_id, template_id, connections_by_proto, connection_count, rx_packets, rx_bytes, tx_packets, tx_bytes, connection_median_latency_ms, session_count_vscode, session_count_jetbrains, session_count_reconnecting_pty, session_count_ssh, peer_count_p2p, peer_count_derp, ROW_NUMBER() OVER(PARTITION BY agent_id ORDER BY created_at DESC) AS rn
 		FROM workspace_agent_stats WHERE created_at > $1
 	) AS a WHERE a.rn = 1
 )
@@ -7235,7 +11301,7 @@ WITH agent_stats AS (
 		coalesce(SUM(session_count_jetbrains), 0)::bigint AS session_count_jetbrains,
 		coalesce(SUM(session_count_reconnecting_pty), 0)::bigint AS session_count_reconnecting_pty
 	 FROM (
-		SELECT id, created_at, user_id, agent_id, workspace_id, template_id, connections_by_proto, connection_count, rx_packets, rx_bytes, tx_packets, tx_bytes, connection_median_latency_ms, session_count_vscode, session_count_jetbrains, session_count_reconnecting_pty, session_count_ssh, ROW_NUMBER() OVER(PARTITION BY agent_id ORDER BY created_at DESC) AS rn
+		SELECT id, created_at, user_id, agent_id, workspace_id, template_id, connections_by_proto, connection_count, rx_packets, rx_bytes, tx_packets, tx_bytes, connection_median_latency_ms, session_count_vscode, session_count_jetbrains, session_count_reconnecting_pty, session_count_ssh, peer_count_p2p, peer_count_derp, ROW_NUMBER() OVER(PARTITION BY agent_id ORDER BY created_at DESC) AS rn
 		FROM workspace_agent_stats WHERE created_at > $1
 	) AS a WHERE a.rn = 1 GROUP BY a.user_id, a.agent_id, a.workspace_id, a.template_id
 )
@@ -7318,7 +11384,7 @@ WITH agent_stats AS (
 		coalesce(SUM(connection_count), 0)::bigint AS connection_count,
 		coalesce(MAX(connection_median_latency_ms), 0)::float AS connection_median_latency_ms
 	 FROM (
-		SELECT id, created_at, user_id, agent_id, workspace_id, template_id, connections_by_proto, connection_count, rx_packets, rx_bytes, tx_packets, tx_bytes, connection_median_latency_ms, session_count_vscode, session_count_jetbrains, session_count_reconnecting_pty, session_count_ssh, ROW_NUMBER() OVER(PARTITION BY agent_id ORDER BY created_at DESC) AS rn
+		SELECT id, created_at, user_id, agent_id, workspace_id, template_id, connections_by_proto, connection_count, rx_packets, rx_bytes, tx_packets, tx_bytes, connection_median_latency_ms, session_count_vscode, session_count_jetbrains, session_count_reconnecting_pty, session_count_ssh, peer_count_p2p, peer_count_derp, ROW_NUMBER() OVER(PARTITION BY agent_id ORDER BY created_at DESC) AS rn
 		FROM workspace_agent_stats
 		-- The greater than 0 is to support legacy agents that don't report connection_median_latency_ms.
 		WHERE created_at > $1 AND connection_median_latency_ms > 0
@@ -7418,10 +11484,12 @@ INSERT INTO
 		session_count_jetbrains,
 		session_count_reconnecting_pty,
 		session_count_ssh,
-		connection_median_latency_ms
+		connection_median_latency_ms,
+		peer_count_p2p,
+		peer_count_derp
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17) RETURNING id, created_at, user_id, agent_id, workspace_id, template_id, connections_by_proto, connection_count, rx_packets, rx_bytes, tx_packets, tx_bytes, connection_median_latency_ms, session_count_vscode, session_count_jetbrains, session_count_reconnecting_pty, session_count_ssh
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) RETURNING id, created_at, user_id, agent_id, workspace_id, template_id, connections_by_proto, connection_count, rx_packets, rx_bytes, tx_packets, tx_bytes, connection_median_latency_ms, session_count_vscode, session_count_jetbrains, session_count_reconnecting_pty, session_count_ssh, peer_count_p2p, peer_count_derp
 `
 
 type InsertWorkspaceAgentStatParams struct {
@@ -7442,6 +11510,8 @@ type InsertWorkspaceAgentStatParams struct {
 	SessionCountReconnectingPTY int64           `db:"session_count_reconnecting_pty" json:"session_count_reconnecting_pty"`
 	SessionCountSSH             int64           `db:"session_count_ssh" json:"session_count_ssh"`
 	ConnectionMedianLatencyMS   float64         `db:"connection_median_latency_ms" json:"connection_median_latency_ms"`
+	PeerCountP2P                int64           `db:"peer_count_p2p" json:"peer_count_p2p"`
+	PeerCountDERP               int64           `db:"peer_count_derp" json:"peer_count_derp"`
 }
 
 func (q *sqlQuerier) InsertWorkspaceAgentStat(ctx context.Context, arg InsertWorkspaceAgentStatParams) (WorkspaceAgentStat, error) {
@@ -7463,6 +11533,8 @@ func (q *sqlQuerier) InsertWorkspaceAgentStat(ctx context.Context, arg InsertWor
 		arg.SessionCountReconnectingPTY,
 		arg.SessionCountSSH,
 		arg.ConnectionMedianLatencyMS,
+		arg.PeerCountP2P,
+		arg.PeerCountDERP,
 	)
 	var i WorkspaceAgentStat
 	err := row.Scan(
@@ -7483,6 +11555,8 @@ func (q *sqlQuerier) InsertWorkspaceAgentStat(ctx context.Context, arg InsertWor
 		&i.SessionCountJetBrains,
 		&i.SessionCountReconnectingPTY,
 		&i.SessionCountSSH,
+		&i.PeerCountP2P,
+		&i.PeerCountDERP,
 	)
 	return i, err
 }
@@ -7506,7 +11580,9 @@ INSERT INTO
 		session_count_jetbrains,
 		session_count_reconnecting_pty,
 		session_count_ssh,
-		connection_median_latency_ms
+		connection_median_latency_ms,
+		peer_count_p2p,
+		peer_count_derp
 	)
 SELECT
 	unnest($1 :: uuid[]) AS id,
@@ -7525,7 +11601,9 @@ SELECT
 	unnest($14 :: bigint[]) AS session_count_jetbrains,
 	unnest($15 :: bigint[]) AS session_count_reconnecting_pty,
 	unnest($16 :: bigint[]) AS session_count_ssh,
-	unnest($17 :: double precision[]) AS connection_median_latency_ms
+	unnest($17 :: double precision[]) AS connection_median_latency_ms,
+	unnest($18 :: bigint[]) AS peer_count_p2p,
+	unnest($19 :: bigint[]) AS peer_count_derp
 `
 
 type InsertWorkspaceAgentStatsParams struct {
@@ -7546,6 +11624,8 @@ type InsertWorkspaceAgentStatsParams struct {
 	SessionCountReconnectingPTY []int64         `db:"session_count_reconnecting_pty" json:"session_count_reconnecting_pty"`
 	SessionCountSSH             []int64         `db:"session_count_ssh" json:"session_count_ssh"`
 	ConnectionMedianLatencyMS   []float64       `db:"connection_median_latency_ms" json:"connection_median_latency_ms"`
+	PeerCountP2P                []int64         `db:"peer_count_p2p" json:"peer_count_p2p"`
+	PeerCountDERP               []int64         `db:"peer_count_derp" json:"peer_count_derp"`
 }
 
 func (q *sqlQuerier) InsertWorkspaceAgentStats(ctx context.Context, arg InsertWorkspaceAgentStatsParams) error {
@@ -7567,12 +11647,77 @@ func (q *sqlQuerier) InsertWorkspaceAgentStats(ctx context.Context, arg InsertWo
 		pq.Array(arg.SessionCountReconnectingPTY),
 		pq.Array(arg.SessionCountSSH),
 		pq.Array(arg.ConnectionMedianLatencyMS),
+		pq.Array(arg.PeerCountP2P),
+		pq.Array(arg.PeerCountDERP),
+	)
+	return err
+}
+
+const deleteOldWorkspaceAppAccessLogs = `-- name: DeleteOldWorkspaceAppAccessLogs :exec
+DELETE FROM workspace_app_access_logs WHERE created_at < NOW() - INTERVAL '30 days'
+`
+
+// Access logs are kept as long as workspace agent stats.
+func (q *sqlQuerier) DeleteOldWorkspaceAppAccessLogs(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteOldWorkspaceAppAccessLogs)
+	return err
+}
+
+const insertWorkspaceAppAccessLogs = `-- name: InsertWorkspaceAppAccessLogs :exec
+INSERT INTO
+	workspace_app_access_logs (
+		created_at,
+		user_id,
+		workspace_id,
+		agent_id,
+		access_method,
+		slug_or_port,
+		path_prefix,
+		status_code,
+		latency_ms
+	)
+SELECT
+	unnest($1::timestamptz[]) AS created_at,
+	-- Requests of users that were not signed in to Coder have a nil user ID.
+	NULLIF(unnest($2::uuid[]), '00000000-0000-0000-0000-000000000000'::uuid) AS user_id,
+	unnest($3::uuid[]) AS workspace_id,
+	unnest($4::uuid[]) AS agent_id,
+	unnest($5::text[]) AS access_method,
+	unnest($6::text[]) AS slug_or_port,
+	unnest($7::text[]) AS path_prefix,
+	unnest($8::int[]) AS status_code,
+	unnest($9::bigint[]) AS latency_ms
+`
+
+type InsertWorkspaceAppAccessLogsParams struct {
+	CreatedAt    []time.Time `db:"created_at" json:"created_at"`
+	UserID       []uuid.UUID `db:"user_id" json:"user_id"`
+	WorkspaceID  []uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	AgentID      []uuid.UUID `db:"agent_id" json:"agent_id"`
+	AccessMethod []string    `db:"access_method" json:"access_method"`
+	SlugOrPort   []string    `db:"slug_or_port" json:"slug_or_port"`
+	PathPrefix   []string    `db:"path_prefix" json:"path_prefix"`
+	StatusCode   []int32     `db:"status_code" json:"status_code"`
+	LatencyMS    []int64     `db:"latency_ms" json:"latency_ms"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceAppAccessLogs(ctx context.Context, arg InsertWorkspaceAppAccessLogsParams) error {
+	_, err := q.db.ExecContext(ctx, insertWorkspaceAppAccessLogs,
+		pq.Array(arg.CreatedAt),
+		pq.Array(arg.UserID),
+		pq.Array(arg.WorkspaceID),
+		pq.Array(arg.AgentID),
+		pq.Array(arg.AccessMethod),
+		pq.Array(arg.SlugOrPort),
+		pq.Array(arg.PathPrefix),
+		pq.Array(arg.StatusCode),
+		pq.Array(arg.LatencyMS),
 	)
 	return err
 }
 
 const getWorkspaceAppByAgentIDAndSlug = `-- name: GetWorkspaceAppByAgentIDAndSlug :one
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE agent_id = $1 AND slug = $2
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, auth, rate_limit_requests_per_minute, rate_limit_burst, rewrite_paths FROM workspace_apps WHERE agent_id = $1 AND slug = $2
 `
 
 type GetWorkspaceAppByAgentIDAndSlugParams struct {
@@ -7599,12 +11744,16 @@ func (q *sqlQuerier) GetWorkspaceAppByAgentIDAndSlug(ctx context.Context, arg Ge
 		&i.SharingLevel,
 		&i.Slug,
 		&i.External,
+		&i.Auth,
+		&i.RateLimitRequestsPerMinute,
+		&i.RateLimitBurst,
+		&i.RewritePaths,
 	)
 	return i, err
 }
 
 const getWorkspaceAppsByAgentID = `-- name: GetWorkspaceAppsByAgentID :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE agent_id = $1 ORDER BY slug ASC
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, auth, rate_limit_requests_per_minute, rate_limit_burst, rewrite_paths FROM workspace_apps WHERE agent_id = $1 ORDER BY slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid.UUID) ([]WorkspaceApp, error) {
@@ -7632,6 +11781,10 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid
 			&i.SharingLevel,
 			&i.Slug,
 			&i.External,
+			&i.Auth,
+			&i.RateLimitRequestsPerMinute,
+			&i.RateLimitBurst,
+			&i.RewritePaths,
 		); err != nil {
 			return nil, err
 		}
@@ -7647,7 +11800,7 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentID(ctx context.Context, agentID uuid
 }
 
 const getWorkspaceAppsByAgentIDs = `-- name: GetWorkspaceAppsByAgentIDs :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE agent_id = ANY($1 :: uuid [ ]) ORDER BY slug ASC
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, auth, rate_limit_requests_per_minute, rate_limit_burst, rewrite_paths FROM workspace_apps WHERE agent_id = ANY($1 :: uuid [ ]) ORDER BY slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.UUID) ([]WorkspaceApp, error) {
@@ -7675,6 +11828,10 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.
 			&i.SharingLevel,
 			&i.Slug,
 			&i.External,
+			&i.Auth,
+			&i.RateLimitRequestsPerMinute,
+			&i.RateLimitBurst,
+			&i.RewritePaths,
 		); err != nil {
 			return nil, err
 		}
@@ -7690,7 +11847,7 @@ func (q *sqlQuerier) GetWorkspaceAppsByAgentIDs(ctx context.Context, ids []uuid.
 }
 
 const getWorkspaceAppsCreatedAfter = `-- name: GetWorkspaceAppsCreatedAfter :many
-SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external FROM workspace_apps WHERE created_at > $1 ORDER BY slug ASC
+SELECT id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, auth, rate_limit_requests_per_minute, rate_limit_burst, rewrite_paths FROM workspace_apps WHERE created_at > $1 ORDER BY slug ASC
 `
 
 func (q *sqlQuerier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt time.Time) ([]WorkspaceApp, error) {
@@ -7718,6 +11875,347 @@ func (q *sqlQuerier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt
 			&i.SharingLevel,
 			&i.Slug,
 			&i.External,
+			&i.Auth,
+			&i.RateLimitRequestsPerMinute,
+			&i.RateLimitBurst,
+			&i.RewritePaths,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertWorkspaceApp = `-- name: InsertWorkspaceApp :one
+INSERT INTO
+    workspace_apps (
+        id,
+        created_at,
+        agent_id,
+        slug,
+        display_name,
+        icon,
+        command,
+        url,
+        external,
+        subdomain,
+        sharing_level,
+        healthcheck_url,
+        healthcheck_interval,
+        healthcheck_threshold,
+        health,
+        auth,
+        rate_limit_requests_per_minute,
+        rate_limit_burst,
+        rewrite_paths
+    )
+VALUES
+    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) RETURNING id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external, auth, rate_limit_requests_per_minute, rate_limit_burst, rewrite_paths
+`
+
+type InsertWorkspaceAppParams struct {
+	ID                         uuid.UUID          `db:"id" json:"id"`
+	CreatedAt                  time.Time          `db:"created_at" json:"created_at"`
+	AgentID                    uuid.UUID          `db:"agent_id" json:"agent_id"`
+	Slug                       string             `db:"slug" json:"slug"`
+	DisplayName                string             `db:"display_name" json:"display_name"`
+	Icon                       string             `db:"icon" json:"icon"`
+	Command                    sql.NullString     `db:"command" json:"command"`
+	Url                        sql.NullString     `db:"url" json:"url"`
+	External                   bool               `db:"external" json:"external"`
+	Subdomain                  bool               `db:"subdomain" json:"subdomain"`
+	SharingLevel               AppSharingLevel    `db:"sharing_level" json:"sharing_level"`
+	HealthcheckUrl             string             `db:"healthcheck_url" json:"healthcheck_url"`
+	HealthcheckInterval        int32              `db:"healthcheck_interval" json:"healthcheck_interval"`
+	HealthcheckThreshold       int32              `db:"healthcheck_threshold" json:"healthcheck_threshold"`
+	Health                     WorkspaceAppHealth `db:"health" json:"health"`
+	Auth                       WorkspaceAppAuth   `db:"auth" json:"auth"`
+	RateLimitRequestsPerMinute int32              `db:"rate_limit_requests_per_minute" json:"rate_limit_requests_per_minute"`
+	RateLimitBurst             int32              `db:"rate_limit_burst" json:"rate_limit_burst"`
+	RewritePaths               bool               `db:"rewrite_paths" json:"rewrite_paths"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceApp(ctx context.Context, arg InsertWorkspaceAppParams) (WorkspaceApp, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceApp,
+		arg.ID,
+		arg.CreatedAt,
+		arg.AgentID,
+		arg.Slug,
+		arg.DisplayName,
+		arg.Icon,
+		arg.Command,
+		arg.Url,
+		arg.External,
+		arg.Subdomain,
+		arg.SharingLevel,
+		arg.HealthcheckUrl,
+		arg.HealthcheckInterval,
+		arg.HealthcheckThreshold,
+		arg.Health,
+		arg.Auth,
+		arg.RateLimitRequestsPerMinute,
+		arg.RateLimitBurst,
+		arg.RewritePaths,
+	)
+	var i WorkspaceApp
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.AgentID,
+		&i.DisplayName,
+		&i.Icon,
+		&i.Command,
+		&i.Url,
+		&i.HealthcheckUrl,
+		&i.HealthcheckInterval,
+		&i.HealthcheckThreshold,
+		&i.Health,
+		&i.Subdomain,
+		&i.SharingLevel,
+		&i.Slug,
+		&i.External,
+		&i.Auth,
+		&i.RateLimitRequestsPerMinute,
+		&i.RateLimitBurst,
+		&i.RewritePaths,
+	)
+	return i, err
+}
+
+const updateWorkspaceAppHealthByID = `-- name: UpdateWorkspaceAppHealthByID :exec
+UPDATE
+	workspace_apps
+SET
+	health = $2
+WHERE
+	id = $1
+`
+
+type UpdateWorkspaceAppHealthByIDParams struct {
+	ID     uuid.UUID          `db:"id" json:"id"`
+	Health WorkspaceAppHealth `db:"health" json:"health"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceAppHealthByID(ctx context.Context, arg UpdateWorkspaceAppHealthByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAppHealthByID, arg.ID, arg.Health)
+	return err
+}
+
+const insertWorkspaceAppStats = `-- name: InsertWorkspaceAppStats :exec
+INSERT INTO
+	workspace_app_stats (
+		user_id,
+		workspace_id,
+		agent_id,
+		access_method,
+		slug_or_port,
+		session_id,
+		session_started_at,
+		session_ended_at,
+		requests,
+		active_duration_ms
+	)
+SELECT
+	unnest($1::uuid[]) AS user_id,
+	unnest($2::uuid[]) AS workspace_id,
+	unnest($3::uuid[]) AS agent_id,
+	unnest($4::text[]) AS access_method,
+	unnest($5::text[]) AS slug_or_port,
+	unnest($6::uuid[]) AS session_id,
+	unnest($7::timestamptz[]) AS session_started_at,
+	unnest($8::timestamptz[]) AS session_ended_at,
+	unnest($9::int[]) AS requests,
+	unnest($10::bigint[]) AS active_duration_ms
+ON CONFLICT
+	(user_id, agent_id, session_id)
+DO
+	UPDATE SET
+		session_ended_at = EXCLUDED.session_ended_at,
+		requests = EXCLUDED.requests,
+		active_duration_ms = EXCLUDED.active_duration_ms
+	WHERE
+		workspace_app_stats.user_id = EXCLUDED.user_id
+		AND workspace_app_stats.agent_id = EXCLUDED.agent_id
+		AND workspace_app_stats.session_id = EXCLUDED.session_id
+		-- Since stats are updated in place as time progresses, we only
+		-- want to update this row if it's fresh.
+		AND workspace_app_stats.session_ended_at <= EXCLUDED.session_ended_at
+		AND workspace_app_stats.requests <= EXCLUDED.requests
+		AND workspace_app_stats.active_duration_ms <= EXCLUDED.active_duration_ms
+`
+
+type InsertWorkspaceAppStatsParams struct {
+	UserID           []uuid.UUID `db:"user_id" json:"user_id"`
+	WorkspaceID      []uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	AgentID          []uuid.UUID `db:"agent_id" json:"agent_id"`
+	AccessMethod     []string    `db:"access_method" json:"access_method"`
+	SlugOrPort       []string    `db:"slug_or_port" json:"slug_or_port"`
+	SessionID        []uuid.UUID `db:"session_id" json:"session_id"`
+	SessionStartedAt []time.Time `db:"session_started_at" json:"session_started_at"`
+	SessionEndedAt   []time.Time `db:"session_ended_at" json:"session_ended_at"`
+	Requests         []int32     `db:"requests" json:"requests"`
+	ActiveDurationMS []int64     `db:"active_duration_ms" json:"active_duration_ms"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceAppStats(ctx context.Context, arg InsertWorkspaceAppStatsParams) error {
+	_, err := q.db.ExecContext(ctx, insertWorkspaceAppStats,
+		pq.Array(arg.UserID),
+		pq.Array(arg.WorkspaceID),
+		pq.Array(arg.AgentID),
+		pq.Array(arg.AccessMethod),
+		pq.Array(arg.SlugOrPort),
+		pq.Array(arg.SessionID),
+		pq.Array(arg.SessionStartedAt),
+		pq.Array(arg.SessionEndedAt),
+		pq.Array(arg.Requests),
+		pq.Array(arg.ActiveDurationMS),
+	)
+	return err
+}
+
+const acquireWorkspaceBatch = `-- name: AcquireWorkspaceBatch :one
+UPDATE
+	workspace_batches
+SET
+	status = 'running',
+	updated_at = $1
+WHERE
+	id = (
+		SELECT
+			id
+		FROM
+			workspace_batches AS nested
+		WHERE
+			nested.status = 'pending'
+			OR (nested.status = 'running' AND nested.updated_at < $2)
+		ORDER BY
+			nested.created_at
+		FOR UPDATE
+		SKIP LOCKED
+		LIMIT
+			1
+	)
+RETURNING id, initiator_id, operation, query, status, error, created_at, updated_at, completed_at
+`
+
+type AcquireWorkspaceBatchParams struct {
+	Now         time.Time `db:"now" json:"now"`
+	StaleBefore time.Time `db:"stale_before" json:"stale_before"`
+}
+
+// Acquires the oldest pending batch, or a running one that has not been
+// updated since stale_before because the replica running it went away.
+// SKIP LOCKED lets replicas acquire different batches concurrently.
+func (q *sqlQuerier) AcquireWorkspaceBatch(ctx context.Context, arg AcquireWorkspaceBatchParams) (WorkspaceBatch, error) {
+	row := q.db.QueryRowContext(ctx, acquireWorkspaceBatch, arg.Now, arg.StaleBefore)
+	var i WorkspaceBatch
+	err := row.Scan(
+		&i.ID,
+		&i.InitiatorID,
+		&i.Operation,
+		&i.Query,
+		&i.Status,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const deleteOldWorkspaceBatches = `-- name: DeleteOldWorkspaceBatches :exec
+DELETE FROM
+	workspace_batches
+WHERE
+	completed_at < $1
+`
+
+func (q *sqlQuerier) DeleteOldWorkspaceBatches(ctx context.Context, completedBefore time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteOldWorkspaceBatches, completedBefore)
+	return err
+}
+
+const getWorkspaceBatchByID = `-- name: GetWorkspaceBatchByID :one
+SELECT
+	id, initiator_id, operation, query, status, error, created_at, updated_at, completed_at
+FROM
+	workspace_batches
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetWorkspaceBatchByID(ctx context.Context, id uuid.UUID) (WorkspaceBatch, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceBatchByID, id)
+	var i WorkspaceBatch
+	err := row.Scan(
+		&i.ID,
+		&i.InitiatorID,
+		&i.Operation,
+		&i.Query,
+		&i.Status,
+		&i.Error,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const getWorkspaceBatchWorkspacesByBatchID = `-- name: GetWorkspaceBatchWorkspacesByBatchID :many
+SELECT
+	workspace_batch_workspaces.batch_id, workspace_batch_workspaces.workspace_id, workspace_batch_workspaces.position, workspace_batch_workspaces.status, workspace_batch_workspaces.build_id, workspace_batch_workspaces.error, workspace_batch_workspaces.updated_at,
+	workspaces.name AS workspace_name,
+	users.username AS workspace_owner_username
+FROM
+	workspace_batch_workspaces
+INNER JOIN
+	workspaces ON workspaces.id = workspace_batch_workspaces.workspace_id
+INNER JOIN
+	users ON users.id = workspaces.owner_id
+WHERE
+	workspace_batch_workspaces.batch_id = $1
+ORDER BY
+	workspace_batch_workspaces.position
+`
+
+type GetWorkspaceBatchWorkspacesByBatchIDRow struct {
+	BatchID                uuid.UUID                     `db:"batch_id" json:"batch_id"`
+	WorkspaceID            uuid.UUID                     `db:"workspace_id" json:"workspace_id"`
+	Position               int32                         `db:"position" json:"position"`
+	Status                 WorkspaceBatchWorkspaceStatus `db:"status" json:"status"`
+	BuildID                uuid.NullUUID                 `db:"build_id" json:"build_id"`
+	Error                  string                        `db:"error" json:"error"`
+	UpdatedAt              time.Time                     `db:"updated_at" json:"updated_at"`
+	WorkspaceName          string                        `db:"workspace_name" json:"workspace_name"`
+	WorkspaceOwnerUsername string                        `db:"workspace_owner_username" json:"workspace_owner_username"`
+}
+
+func (q *sqlQuerier) GetWorkspaceBatchWorkspacesByBatchID(ctx context.Context, batchID uuid.UUID) ([]GetWorkspaceBatchWorkspacesByBatchIDRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspaceBatchWorkspacesByBatchID, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspaceBatchWorkspacesByBatchIDRow
+	for rows.Next() {
+		var i GetWorkspaceBatchWorkspacesByBatchIDRow
+		if err := rows.Scan(
+			&i.BatchID,
+			&i.WorkspaceID,
+			&i.Position,
+			&i.Status,
+			&i.BuildID,
+			&i.Error,
+			&i.UpdatedAt,
+			&i.WorkspaceName,
+			&i.WorkspaceOwnerUsername,
 		); err != nil {
 			return nil, err
 		}
@@ -7732,167 +12230,159 @@ func (q *sqlQuerier) GetWorkspaceAppsCreatedAfter(ctx context.Context, createdAt
 	return items, nil
 }
 
-const insertWorkspaceApp = `-- name: InsertWorkspaceApp :one
+const insertWorkspaceBatch = `-- name: InsertWorkspaceBatch :one
 INSERT INTO
-    workspace_apps (
-        id,
-        created_at,
-        agent_id,
-        slug,
-        display_name,
-        icon,
-        command,
-        url,
-        external,
-        subdomain,
-        sharing_level,
-        healthcheck_url,
-        healthcheck_interval,
-        healthcheck_threshold,
-        health
-    )
+	workspace_batches (
+		id,
+		initiator_id,
+		operation,
+		query,
+		created_at,
+		updated_at
+	)
 VALUES
-    ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, created_at, agent_id, display_name, icon, command, url, healthcheck_url, healthcheck_interval, healthcheck_threshold, health, subdomain, sharing_level, slug, external
+	($1, $2, $3, $4, $5, $5)
+RETURNING id, initiator_id, operation, query, status, error, created_at, updated_at, completed_at
 `
 
-type InsertWorkspaceAppParams struct {
-	ID                   uuid.UUID          `db:"id" json:"id"`
-	CreatedAt            time.Time          `db:"created_at" json:"created_at"`
-	AgentID              uuid.UUID          `db:"agent_id" json:"agent_id"`
-	Slug                 string             `db:"slug" json:"slug"`
-	DisplayName          string             `db:"display_name" json:"display_name"`
-	Icon                 string             `db:"icon" json:"icon"`
-	Command              sql.NullString     `db:"command" json:"command"`
-	Url                  sql.NullString     `db:"url" json:"url"`
-	External             bool               `db:"external" json:"external"`
-	Subdomain            bool               `db:"subdomain" json:"subdomain"`
-	SharingLevel         AppSharingLevel    `db:"sharing_level" json:"sharing_level"`
-	HealthcheckUrl       string             `db:"healthcheck_url" json:"healthcheck_url"`
-	HealthcheckInterval  int32              `db:"healthcheck_interval" json:"healthcheck_interval"`
-	HealthcheckThreshold int32              `db:"healthcheck_threshold" json:"healthcheck_threshold"`
-	Health               WorkspaceAppHealth `db:"health" json:"health"`
+type InsertWorkspaceBatchParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	InitiatorID uuid.UUID `db:"initiator_id" json:"initiator_id"`
+	Operation   string    `db:"operation" json:"operation"`
+	Query       string    `db:"query" json:"query"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 }
 
-func (q *sqlQuerier) InsertWorkspaceApp(ctx context.Context, arg InsertWorkspaceAppParams) (WorkspaceApp, error) {
-	row := q.db.QueryRowContext(ctx, insertWorkspaceApp,
+func (q *sqlQuerier) InsertWorkspaceBatch(ctx context.Context, arg InsertWorkspaceBatchParams) (WorkspaceBatch, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceBatch,
 		arg.ID,
+		arg.InitiatorID,
+		arg.Operation,
+		arg.Query,
 		arg.CreatedAt,
-		arg.AgentID,
-		arg.Slug,
-		arg.DisplayName,
-		arg.Icon,
-		arg.Command,
-		arg.Url,
-		arg.External,
-		arg.Subdomain,
-		arg.SharingLevel,
-		arg.HealthcheckUrl,
-		arg.HealthcheckInterval,
-		arg.HealthcheckThreshold,
-		arg.Health,
 	)
-	var i WorkspaceApp
+	var i WorkspaceBatch
 	err := row.Scan(
 		&i.ID,
+		&i.InitiatorID,
+		&i.Operation,
+		&i.Query,
+		&i.Status,
+		&i.Error,
 		&i.CreatedAt,
-		&i.AgentID,
-		&i.DisplayName,
-		&i.Icon,
-		&i.Command,
-		&i.Url,
-		&i.HealthcheckUrl,
-		&i.HealthcheckInterval,
-		&i.HealthcheckThreshold,
-		&i.Health,
-		&i.Subdomain,
-		&i.SharingLevel,
-		&i.Slug,
-		&i.External,
+		&i.UpdatedAt,
+		&i.CompletedAt,
 	)
 	return i, err
 }
 
-const updateWorkspaceAppHealthByID = `-- name: UpdateWorkspaceAppHealthByID :exec
+const insertWorkspaceBatchWorkspaces = `-- name: InsertWorkspaceBatchWorkspaces :exec
+INSERT INTO
+	workspace_batch_workspaces (
+		batch_id,
+		workspace_id,
+		position,
+		updated_at
+	)
+SELECT
+	$1 :: uuid AS batch_id,
+	workspace_id,
+	position,
+	$2 :: timestamptz AS updated_at
+FROM
+	unnest($3 :: uuid [ ]) WITH ORDINALITY AS ids(workspace_id, position)
+`
+
+type InsertWorkspaceBatchWorkspacesParams struct {
+	BatchID      uuid.UUID   `db:"batch_id" json:"batch_id"`
+	UpdatedAt    time.Time   `db:"updated_at" json:"updated_at"`
+	WorkspaceIds []uuid.UUID `db:"workspace_ids" json:"workspace_ids"`
+}
+
+// Workspaces are processed in the order they are given.
+func (q *sqlQuerier) InsertWorkspaceBatchWorkspaces(ctx context.Context, arg InsertWorkspaceBatchWorkspacesParams) error {
+	_, err := q.db.ExecContext(ctx, insertWorkspaceBatchWorkspaces, arg.BatchID, arg.UpdatedAt, pq.Array(arg.WorkspaceIds))
+	return err
+}
+
+const updateWorkspaceBatchCompletedByID = `-- name: UpdateWorkspaceBatchCompletedByID :exec
 UPDATE
-	workspace_apps
+	workspace_batches
 SET
-	health = $2
+	updated_at = $2,
+	completed_at = $2,
+	status = $3,
+	error = $4
 WHERE
 	id = $1
 `
 
-type UpdateWorkspaceAppHealthByIDParams struct {
-	ID     uuid.UUID          `db:"id" json:"id"`
-	Health WorkspaceAppHealth `db:"health" json:"health"`
+type UpdateWorkspaceBatchCompletedByIDParams struct {
+	ID        uuid.UUID            `db:"id" json:"id"`
+	UpdatedAt time.Time            `db:"updated_at" json:"updated_at"`
+	Status    WorkspaceBatchStatus `db:"status" json:"status"`
+	Error     string               `db:"error" json:"error"`
 }
 
-func (q *sqlQuerier) UpdateWorkspaceAppHealthByID(ctx context.Context, arg UpdateWorkspaceAppHealthByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateWorkspaceAppHealthByID, arg.ID, arg.Health)
+func (q *sqlQuerier) UpdateWorkspaceBatchCompletedByID(ctx context.Context, arg UpdateWorkspaceBatchCompletedByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceBatchCompletedByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.Status,
+		arg.Error,
+	)
 	return err
 }
 
-const insertWorkspaceAppStats = `-- name: InsertWorkspaceAppStats :exec
-INSERT INTO
-	workspace_app_stats (
-		user_id,
-		workspace_id,
-		agent_id,
-		access_method,
-		slug_or_port,
-		session_id,
-		session_started_at,
-		session_ended_at,
-		requests
-	)
-SELECT
-	unnest($1::uuid[]) AS user_id,
-	unnest($2::uuid[]) AS workspace_id,
-	unnest($3::uuid[]) AS agent_id,
-	unnest($4::text[]) AS access_method,
-	unnest($5::text[]) AS slug_or_port,
-	unnest($6::uuid[]) AS session_id,
-	unnest($7::timestamptz[]) AS session_started_at,
-	unnest($8::timestamptz[]) AS session_ended_at,
-	unnest($9::int[]) AS requests
-ON CONFLICT
-	(user_id, agent_id, session_id)
-DO
-	UPDATE SET
-		session_ended_at = EXCLUDED.session_ended_at,
-		requests = EXCLUDED.requests
-	WHERE
-		workspace_app_stats.user_id = EXCLUDED.user_id
-		AND workspace_app_stats.agent_id = EXCLUDED.agent_id
-		AND workspace_app_stats.session_id = EXCLUDED.session_id
-		-- Since stats are updated in place as time progresses, we only
-		-- want to update this row if it's fresh.
-		AND workspace_app_stats.session_ended_at <= EXCLUDED.session_ended_at
-		AND workspace_app_stats.requests <= EXCLUDED.requests
+const updateWorkspaceBatchUpdatedAtByID = `-- name: UpdateWorkspaceBatchUpdatedAtByID :exec
+UPDATE
+	workspace_batches
+SET
+	updated_at = $2
+WHERE
+	id = $1
 `
 
-type InsertWorkspaceAppStatsParams struct {
-	UserID           []uuid.UUID `db:"user_id" json:"user_id"`
-	WorkspaceID      []uuid.UUID `db:"workspace_id" json:"workspace_id"`
-	AgentID          []uuid.UUID `db:"agent_id" json:"agent_id"`
-	AccessMethod     []string    `db:"access_method" json:"access_method"`
-	SlugOrPort       []string    `db:"slug_or_port" json:"slug_or_port"`
-	SessionID        []uuid.UUID `db:"session_id" json:"session_id"`
-	SessionStartedAt []time.Time `db:"session_started_at" json:"session_started_at"`
-	SessionEndedAt   []time.Time `db:"session_ended_at" json:"session_ended_at"`
-	Requests         []int32     `db:"requests" json:"requests"`
+type UpdateWorkspaceBatchUpdatedAtByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) InsertWorkspaceAppStats(ctx context.Context, arg InsertWorkspaceAppStatsParams) error {
-	_, err := q.db.ExecContext(ctx, insertWorkspaceAppStats,
-		pq.Array(arg.UserID),
-		pq.Array(arg.WorkspaceID),
-		pq.Array(arg.AgentID),
-		pq.Array(arg.AccessMethod),
-		pq.Array(arg.SlugOrPort),
-		pq.Array(arg.SessionID),
-		pq.Array(arg.SessionStartedAt),
-		pq.Array(arg.SessionEndedAt),
-		pq.Array(arg.Requests),
+func (q *sqlQuerier) UpdateWorkspaceBatchUpdatedAtByID(ctx context.Context, arg UpdateWorkspaceBatchUpdatedAtByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceBatchUpdatedAtByID, arg.ID, arg.UpdatedAt)
+	return err
+}
+
+const updateWorkspaceBatchWorkspace = `-- name: UpdateWorkspaceBatchWorkspace :exec
+UPDATE
+	workspace_batch_workspaces
+SET
+	status = $3,
+	build_id = $4,
+	error = $5,
+	updated_at = $6
+WHERE
+	batch_id = $1
+	AND workspace_id = $2
+`
+
+type UpdateWorkspaceBatchWorkspaceParams struct {
+	BatchID     uuid.UUID                     `db:"batch_id" json:"batch_id"`
+	WorkspaceID uuid.UUID                     `db:"workspace_id" json:"workspace_id"`
+	Status      WorkspaceBatchWorkspaceStatus `db:"status" json:"status"`
+	BuildID     uuid.NullUUID                 `db:"build_id" json:"build_id"`
+	Error       string                        `db:"error" json:"error"`
+	UpdatedAt   time.Time                     `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceBatchWorkspace(ctx context.Context, arg UpdateWorkspaceBatchWorkspaceParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceBatchWorkspace,
+		arg.BatchID,
+		arg.WorkspaceID,
+		arg.Status,
+		arg.BuildID,
+		arg.Error,
+		arg.UpdatedAt,
 	)
 	return err
 }
@@ -8482,34 +12972,186 @@ type UpdateWorkspaceBuildByIDParams struct {
 	MaxDeadline      time.Time `db:"max_deadline" json:"max_deadline"`
 }
 
-func (q *sqlQuerier) UpdateWorkspaceBuildByID(ctx context.Context, arg UpdateWorkspaceBuildByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateWorkspaceBuildByID,
-		arg.ID,
-		arg.UpdatedAt,
-		arg.ProvisionerState,
-		arg.Deadline,
-		arg.MaxDeadline,
+func (q *sqlQuerier) UpdateWorkspaceBuildByID(ctx context.Context, arg UpdateWorkspaceBuildByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceBuildByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.ProvisionerState,
+		arg.Deadline,
+		arg.MaxDeadline,
+	)
+	return err
+}
+
+const updateWorkspaceBuildCostByID = `-- name: UpdateWorkspaceBuildCostByID :exec
+UPDATE
+	workspace_builds
+SET
+	daily_cost = $2
+WHERE
+	id = $1
+`
+
+type UpdateWorkspaceBuildCostByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	DailyCost int32     `db:"daily_cost" json:"daily_cost"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceBuildCostByID(ctx context.Context, arg UpdateWorkspaceBuildCostByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceBuildCostByID, arg.ID, arg.DailyCost)
+	return err
+}
+
+const getWorkspaceDeadlineExtensionCount = `-- name: GetWorkspaceDeadlineExtensionCount :one
+SELECT
+	COUNT(*)
+FROM
+	workspace_deadline_extensions
+WHERE
+	workspace_id = $1
+	AND created_at > $2
+`
+
+type GetWorkspaceDeadlineExtensionCountParams struct {
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// Returns the number of deadline extensions of the workspace since the given
+// time.
+func (q *sqlQuerier) GetWorkspaceDeadlineExtensionCount(ctx context.Context, arg GetWorkspaceDeadlineExtensionCountParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceDeadlineExtensionCount, arg.WorkspaceID, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const insertWorkspaceDeadlineExtension = `-- name: InsertWorkspaceDeadlineExtension :one
+INSERT INTO
+	workspace_deadline_extensions (
+		id,
+		workspace_id,
+		workspace_build_id,
+		user_id,
+		created_at,
+		duration
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+RETURNING id, workspace_id, workspace_build_id, user_id, created_at, duration
+`
+
+type InsertWorkspaceDeadlineExtensionParams struct {
+	ID               uuid.UUID `db:"id" json:"id"`
+	WorkspaceID      uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	WorkspaceBuildID uuid.UUID `db:"workspace_build_id" json:"workspace_build_id"`
+	UserID           uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt        time.Time `db:"created_at" json:"created_at"`
+	Duration         int64     `db:"duration" json:"duration"`
+}
+
+func (q *sqlQuerier) InsertWorkspaceDeadlineExtension(ctx context.Context, arg InsertWorkspaceDeadlineExtensionParams) (WorkspaceDeadlineExtension, error) {
+	row := q.db.QueryRowContext(ctx, insertWorkspaceDeadlineExtension,
+		arg.ID,
+		arg.WorkspaceID,
+		arg.WorkspaceBuildID,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.Duration,
+	)
+	var i WorkspaceDeadlineExtension
+	err := row.Scan(
+		&i.ID,
+		&i.WorkspaceID,
+		&i.WorkspaceBuildID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.Duration,
+	)
+	return i, err
+}
+
+const deleteExpiredWorkspaceNameRedirects = `-- name: DeleteExpiredWorkspaceNameRedirects :exec
+DELETE FROM workspace_name_redirects WHERE expires_at <= NOW()
+`
+
+func (q *sqlQuerier) DeleteExpiredWorkspaceNameRedirects(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteExpiredWorkspaceNameRedirects)
+	return err
+}
+
+const getWorkspaceNameRedirect = `-- name: GetWorkspaceNameRedirect :one
+SELECT
+	owner_id, name, workspace_id, created_at, expires_at
+FROM
+	workspace_name_redirects
+WHERE
+	owner_id = $1
+	AND name = $2
+	AND expires_at > NOW()
+`
+
+type GetWorkspaceNameRedirectParams struct {
+	OwnerID uuid.UUID `db:"owner_id" json:"owner_id"`
+	Name    string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetWorkspaceNameRedirect(ctx context.Context, arg GetWorkspaceNameRedirectParams) (WorkspaceNameRedirect, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceNameRedirect, arg.OwnerID, arg.Name)
+	var i WorkspaceNameRedirect
+	err := row.Scan(
+		&i.OwnerID,
+		&i.Name,
+		&i.WorkspaceID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
 	)
-	return err
+	return i, err
 }
 
-const updateWorkspaceBuildCostByID = `-- name: UpdateWorkspaceBuildCostByID :exec
-UPDATE
-	workspace_builds
-SET
-	daily_cost = $2
-WHERE
-	id = $1
+const upsertWorkspaceNameRedirect = `-- name: UpsertWorkspaceNameRedirect :one
+INSERT INTO
+	workspace_name_redirects (
+		owner_id,
+		name,
+		workspace_id,
+		created_at,
+		expires_at
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT (owner_id, name) DO UPDATE SET
+	workspace_id = $3,
+	created_at = $4,
+	expires_at = $5
+RETURNING owner_id, name, workspace_id, created_at, expires_at
 `
 
-type UpdateWorkspaceBuildCostByIDParams struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	DailyCost int32     `db:"daily_cost" json:"daily_cost"`
+type UpsertWorkspaceNameRedirectParams struct {
+	OwnerID     uuid.UUID `db:"owner_id" json:"owner_id"`
+	Name        string    `db:"name" json:"name"`
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt   time.Time `db:"expires_at" json:"expires_at"`
 }
 
-func (q *sqlQuerier) UpdateWorkspaceBuildCostByID(ctx context.Context, arg UpdateWorkspaceBuildCostByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateWorkspaceBuildCostByID, arg.ID, arg.DailyCost)
-	return err
+func (q *sqlQuerier) UpsertWorkspaceNameRedirect(ctx context.Context, arg UpsertWorkspaceNameRedirectParams) (WorkspaceNameRedirect, error) {
+	row := q.db.QueryRowContext(ctx, upsertWorkspaceNameRedirect,
+		arg.OwnerID,
+		arg.Name,
+		arg.WorkspaceID,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	var i WorkspaceNameRedirect
+	err := row.Scan(
+		&i.OwnerID,
+		&i.Name,
+		&i.WorkspaceID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
 }
 
 const getWorkspaceResourceByID = `-- name: GetWorkspaceResourceByID :one
@@ -8888,7 +13530,7 @@ WITH workspaces_with_jobs AS (
 		completed_at IS NOT NULL AND
 		canceled_at IS NULL AND
 		error IS NULL AND
-		transition = 'stop'::workspace_transition
+		transition IN ('stop'::workspace_transition, 'archive'::workspace_transition)
 )
 SELECT
 	pending_workspaces.count AS pending_workspaces,
@@ -8922,7 +13564,7 @@ func (q *sqlQuerier) GetDeploymentWorkspaceStats(ctx context.Context) (GetDeploy
 
 const getWorkspaceByAgentID = `-- name: GetWorkspaceByAgentID :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at, group_acl, lock_reason
 FROM
 	workspaces
 WHERE
@@ -8967,13 +13609,15 @@ func (q *sqlQuerier) GetWorkspaceByAgentID(ctx context.Context, agentID uuid.UUI
 		&i.LastUsedAt,
 		&i.LockedAt,
 		&i.DeletingAt,
+		&i.GroupACL,
+		&i.LockReason,
 	)
 	return i, err
 }
 
 const getWorkspaceByID = `-- name: GetWorkspaceByID :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at, group_acl, lock_reason
 FROM
 	workspaces
 WHERE
@@ -8999,13 +13643,15 @@ func (q *sqlQuerier) GetWorkspaceByID(ctx context.Context, id uuid.UUID) (Worksp
 		&i.LastUsedAt,
 		&i.LockedAt,
 		&i.DeletingAt,
+		&i.GroupACL,
+		&i.LockReason,
 	)
 	return i, err
 }
 
 const getWorkspaceByOwnerIDAndName = `-- name: GetWorkspaceByOwnerIDAndName :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at, group_acl, lock_reason
 FROM
 	workspaces
 WHERE
@@ -9038,13 +13684,15 @@ func (q *sqlQuerier) GetWorkspaceByOwnerIDAndName(ctx context.Context, arg GetWo
 		&i.LastUsedAt,
 		&i.LockedAt,
 		&i.DeletingAt,
+		&i.GroupACL,
+		&i.LockReason,
 	)
 	return i, err
 }
 
 const getWorkspaceByWorkspaceAppID = `-- name: GetWorkspaceByWorkspaceAppID :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at, group_acl, lock_reason
 FROM
 	workspaces
 WHERE
@@ -9096,13 +13744,15 @@ func (q *sqlQuerier) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspace
 		&i.LastUsedAt,
 		&i.LockedAt,
 		&i.DeletingAt,
+		&i.GroupACL,
+		&i.LockReason,
 	)
 	return i, err
 }
 
 const getWorkspaces = `-- name: GetWorkspaces :many
 SELECT
-	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.locked_at, workspaces.deleting_at,
+	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.locked_at, workspaces.deleting_at, workspaces.group_acl, workspaces.lock_reason,
 	COALESCE(template_name.template_name, 'unknown') as template_name,
 	latest_build.template_version_id,
 	latest_build.template_version_name,
@@ -9117,6 +13767,8 @@ LEFT JOIN LATERAL (
 	SELECT
 		workspace_builds.transition,
 		workspace_builds.template_version_id,
+		workspace_builds.deadline,
+		workspace_builds.max_deadline,
 		template_versions.name AS template_version_name,
 		provisioner_jobs.id AS provisioner_job_id,
 		provisioner_jobs.started_at,
@@ -9143,7 +13795,8 @@ LEFT JOIN LATERAL (
 ) latest_build ON TRUE
 LEFT JOIN LATERAL (
 	SELECT
-		templates.name AS template_name
+		templates.name AS template_name,
+		templates.active_version_id
 	FROM
 		templates
 	WHERE
@@ -9175,13 +13828,13 @@ WHERE
 					latest_build.canceled_at IS NULL AND
 					latest_build.completed_at IS NULL AND
 					latest_build.updated_at - INTERVAL '30 seconds' < NOW() AND
-					latest_build.transition = 'stop'::workspace_transition
+					latest_build.transition IN ('stop'::workspace_transition, 'archive'::workspace_transition)
 
 				WHEN $2 = 'stopped' THEN
 					latest_build.completed_at IS NOT NULL AND
 					latest_build.canceled_at IS NULL AND
 					latest_build.error IS NULL AND
-					latest_build.transition = 'stop'::workspace_transition
+					latest_build.transition IN ('stop'::workspace_transition, 'archive'::workspace_transition)
 
 				WHEN $2 = 'failed' THEN
 					(latest_build.canceled_at IS NOT NULL AND
@@ -9294,6 +13947,57 @@ WHERE
 		ELSE
 			locked_at IS NULL
 	END
+	-- Filter by last used
+	AND CASE
+		WHEN $11 :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			last_used_at < $11
+		ELSE true
+	END
+	AND CASE
+		WHEN $12 :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			last_used_at > $12
+		ELSE true
+	END
+	-- Filter by the reason workspaces were locked
+	AND CASE
+		WHEN $13 :: text != '' THEN
+			workspaces.lock_reason :: text = $13
+		ELSE true
+	END
+	-- Filter by whether the latest build uses the active version of the
+	-- template
+	AND CASE
+		WHEN $14 :: text = 'true' THEN
+			latest_build.template_version_id != template_name.active_version_id
+		WHEN $14 = 'false' THEN
+			latest_build.template_version_id = template_name.active_version_id
+		ELSE true
+	END
+	-- Filter by the deadline of the latest build. Builds without a deadline
+	-- have a zero deadline, and never match.
+	AND CASE
+		WHEN $15 :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			latest_build.deadline > '0001-01-01 00:00:00+00'::timestamptz AND
+			latest_build.deadline < $15
+		ELSE true
+	END
+	AND CASE
+		WHEN $16 :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			latest_build.deadline > $16
+		ELSE true
+	END
+	-- Filter by the max deadline of the latest build
+	AND CASE
+		WHEN $17 :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			latest_build.max_deadline > '0001-01-01 00:00:00+00'::timestamptz AND
+			latest_build.max_deadline < $17
+		ELSE true
+	END
+	AND CASE
+		WHEN $18 :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			latest_build.max_deadline > $18
+		ELSE true
+	END
 	-- Authorize Filter clause will be injected below in GetAuthorizedWorkspaces
 	-- @authorize_filter
 ORDER BY
@@ -9305,11 +14009,11 @@ ORDER BY
 	LOWER(workspaces.name) ASC
 LIMIT
 	CASE
-		WHEN $12 :: integer > 0 THEN
-			$12
+		WHEN $20 :: integer > 0 THEN
+			$20
 	END
 OFFSET
-	$11
+	$19
 `
 
 type GetWorkspacesParams struct {
@@ -9323,28 +14027,38 @@ type GetWorkspacesParams struct {
 	HasAgent                              string      `db:"has_agent" json:"has_agent"`
 	AgentInactiveDisconnectTimeoutSeconds int64       `db:"agent_inactive_disconnect_timeout_seconds" json:"agent_inactive_disconnect_timeout_seconds"`
 	LockedAt                              time.Time   `db:"locked_at" json:"locked_at"`
+	LastUsedBefore                        time.Time   `db:"last_used_before" json:"last_used_before"`
+	LastUsedAfter                         time.Time   `db:"last_used_after" json:"last_used_after"`
+	LockReason                            string      `db:"lock_reason" json:"lock_reason"`
+	Outdated                              string      `db:"outdated" json:"outdated"`
+	DeadlineBefore                        time.Time   `db:"deadline_before" json:"deadline_before"`
+	DeadlineAfter                         time.Time   `db:"deadline_after" json:"deadline_after"`
+	MaxDeadlineBefore                     time.Time   `db:"max_deadline_before" json:"max_deadline_before"`
+	MaxDeadlineAfter                      time.Time   `db:"max_deadline_after" json:"max_deadline_after"`
 	Offset                                int32       `db:"offset_" json:"offset_"`
 	Limit                                 int32       `db:"limit_" json:"limit_"`
 }
 
 type GetWorkspacesRow struct {
-	ID                  uuid.UUID      `db:"id" json:"id"`
-	CreatedAt           time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt           time.Time      `db:"updated_at" json:"updated_at"`
-	OwnerID             uuid.UUID      `db:"owner_id" json:"owner_id"`
-	OrganizationID      uuid.UUID      `db:"organization_id" json:"organization_id"`
-	TemplateID          uuid.UUID      `db:"template_id" json:"template_id"`
-	Deleted             bool           `db:"deleted" json:"deleted"`
-	Name                string         `db:"name" json:"name"`
-	AutostartSchedule   sql.NullString `db:"autostart_schedule" json:"autostart_schedule"`
-	Ttl                 sql.NullInt64  `db:"ttl" json:"ttl"`
-	LastUsedAt          time.Time      `db:"last_used_at" json:"last_used_at"`
-	LockedAt            sql.NullTime   `db:"locked_at" json:"locked_at"`
-	DeletingAt          sql.NullTime   `db:"deleting_at" json:"deleting_at"`
-	TemplateName        string         `db:"template_name" json:"template_name"`
-	TemplateVersionID   uuid.UUID      `db:"template_version_id" json:"template_version_id"`
-	TemplateVersionName sql.NullString `db:"template_version_name" json:"template_version_name"`
-	Count               int64          `db:"count" json:"count"`
+	ID                  uuid.UUID               `db:"id" json:"id"`
+	CreatedAt           time.Time               `db:"created_at" json:"created_at"`
+	UpdatedAt           time.Time               `db:"updated_at" json:"updated_at"`
+	OwnerID             uuid.UUID               `db:"owner_id" json:"owner_id"`
+	OrganizationID      uuid.UUID               `db:"organization_id" json:"organization_id"`
+	TemplateID          uuid.UUID               `db:"template_id" json:"template_id"`
+	Deleted             bool                    `db:"deleted" json:"deleted"`
+	Name                string                  `db:"name" json:"name"`
+	AutostartSchedule   sql.NullString          `db:"autostart_schedule" json:"autostart_schedule"`
+	Ttl                 sql.NullInt64           `db:"ttl" json:"ttl"`
+	LastUsedAt          time.Time               `db:"last_used_at" json:"last_used_at"`
+	LockedAt            sql.NullTime            `db:"locked_at" json:"locked_at"`
+	DeletingAt          sql.NullTime            `db:"deleting_at" json:"deleting_at"`
+	GroupACL            WorkspaceACL            `db:"group_acl" json:"group_acl"`
+	LockReason          NullWorkspaceLockReason `db:"lock_reason" json:"lock_reason"`
+	TemplateName        string                  `db:"template_name" json:"template_name"`
+	TemplateVersionID   uuid.UUID               `db:"template_version_id" json:"template_version_id"`
+	TemplateVersionName sql.NullString          `db:"template_version_name" json:"template_version_name"`
+	Count               int64                   `db:"count" json:"count"`
 }
 
 func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams) ([]GetWorkspacesRow, error) {
@@ -9359,6 +14073,14 @@ func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams)
 		arg.HasAgent,
 		arg.AgentInactiveDisconnectTimeoutSeconds,
 		arg.LockedAt,
+		arg.LastUsedBefore,
+		arg.LastUsedAfter,
+		arg.LockReason,
+		arg.Outdated,
+		arg.DeadlineBefore,
+		arg.DeadlineAfter,
+		arg.MaxDeadlineBefore,
+		arg.MaxDeadlineAfter,
 		arg.Offset,
 		arg.Limit,
 	)
@@ -9383,6 +14105,8 @@ func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams)
 			&i.LastUsedAt,
 			&i.LockedAt,
 			&i.DeletingAt,
+			&i.GroupACL,
+			&i.LockReason,
 			&i.TemplateName,
 			&i.TemplateVersionID,
 			&i.TemplateVersionName,
@@ -9401,9 +14125,57 @@ func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams)
 	return items, nil
 }
 
+const getWorkspacesByIDs = `-- name: GetWorkspacesByIDs :many
+SELECT
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at, group_acl, lock_reason
+FROM
+	workspaces
+WHERE
+	id = ANY($1 :: uuid [ ])
+`
+
+func (q *sqlQuerier) GetWorkspacesByIDs(ctx context.Context, ids []uuid.UUID) ([]Workspace, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspacesByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Workspace
+	for rows.Next() {
+		var i Workspace
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.OwnerID,
+			&i.OrganizationID,
+			&i.TemplateID,
+			&i.Deleted,
+			&i.Name,
+			&i.AutostartSchedule,
+			&i.Ttl,
+			&i.LastUsedAt,
+			&i.LockedAt,
+			&i.DeletingAt,
+			&i.GroupACL,
+			&i.LockReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getWorkspacesEligibleForTransition = `-- name: GetWorkspacesEligibleForTransition :many
 SELECT
-	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.locked_at, workspaces.deleting_at
+	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.locked_at, workspaces.deleting_at, workspaces.group_acl, workspaces.lock_reason
 FROM
 	workspaces
 LEFT JOIN
@@ -9443,6 +14215,21 @@ WHERE
 			workspaces.autostart_schedule IS NOT NULL
 		) OR
 
+		-- If the workspace was stopped by the restart requirement and the
+		-- template updates workspaces on restart, it may be eligible for a
+		-- start on the active version. Only successful stops of unlocked
+		-- workspaces qualify. The caller must check the license as we cannot
+		-- check it here.
+		(
+			workspace_builds.transition = 'stop'::workspace_transition AND
+			workspace_builds.reason = 'restart_requirement'::build_reason AND
+			templates.update_on_restart AND
+			workspaces.locked_at IS NULL AND
+			provisioner_jobs.completed_at IS NOT NULL AND
+			provisioner_jobs.canceled_at IS NULL AND
+			(provisioner_jobs.error IS NULL OR provisioner_jobs.error = '')
+		) OR
+
 		-- If the workspace's most recent job resulted in an error
 		-- it may be eligible for failed stop.
 		(
@@ -9463,6 +14250,14 @@ WHERE
 		(
 			templates.locked_ttl > 0 AND
 			workspaces.locked_at IS NOT NULL
+		) OR
+
+		-- If the workspace's template has a max_lifetime set it may be
+		-- eligible for deletion or locking, or its owner may have to be
+		-- warned that it expires soon.
+		(
+			templates.max_lifetime > 0 AND
+			workspaces.locked_at IS NULL
 		)
 	) AND workspaces.deleted = 'false'
 `
@@ -9490,6 +14285,105 @@ func (q *sqlQuerier) GetWorkspacesEligibleForTransition(ctx context.Context, now
 			&i.LastUsedAt,
 			&i.LockedAt,
 			&i.DeletingAt,
+			&i.GroupACL,
+			&i.LockReason,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getWorkspacesWithImminentAutostop = `-- name: GetWorkspacesWithImminentAutostop :many
+SELECT
+	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.locked_at, workspaces.deleting_at, workspaces.group_acl, workspaces.lock_reason,
+	workspace_builds.id AS build_id,
+	workspace_builds.deadline AS build_deadline,
+	users.username AS owner_username,
+	users.email AS owner_email,
+	templates.name AS template_name
+FROM
+	workspaces
+INNER JOIN
+	workspace_builds ON workspace_builds.workspace_id = workspaces.id
+INNER JOIN
+	provisioner_jobs ON workspace_builds.job_id = provisioner_jobs.id
+INNER JOIN
+	users ON users.id = workspaces.owner_id
+INNER JOIN
+	templates ON templates.id = workspaces.template_id
+WHERE
+	workspace_builds.build_number = (
+		SELECT
+			MAX(build_number)
+		FROM
+			workspace_builds
+		WHERE
+			workspace_builds.workspace_id = workspaces.id
+	) AND
+	workspace_builds.transition = 'start'::workspace_transition AND
+	workspace_builds.deadline > $1 :: timestamptz AND
+	workspace_builds.deadline <= $2 :: timestamptz AND
+	provisioner_jobs.completed_at IS NOT NULL AND
+	COALESCE(provisioner_jobs.error, '') = '' AND
+	workspaces.locked_at IS NULL AND
+	workspaces.deleted = 'false'
+`
+
+type GetWorkspacesWithImminentAutostopParams struct {
+	Now    time.Time `db:"now" json:"now"`
+	Before time.Time `db:"before" json:"before"`
+}
+
+type GetWorkspacesWithImminentAutostopRow struct {
+	Workspace     Workspace `db:"workspace" json:"workspace"`
+	BuildID       uuid.UUID `db:"build_id" json:"build_id"`
+	BuildDeadline time.Time `db:"build_deadline" json:"build_deadline"`
+	OwnerUsername string    `db:"owner_username" json:"owner_username"`
+	OwnerEmail    string    `db:"owner_email" json:"owner_email"`
+	TemplateName  string    `db:"template_name" json:"template_name"`
+}
+
+// Returns started workspaces whose latest build successfully completed and
+// reaches its deadline after @now and no later than @before, with the latest
+// build, owner and template details the autostop imminent events include.
+func (q *sqlQuerier) GetWorkspacesWithImminentAutostop(ctx context.Context, arg GetWorkspacesWithImminentAutostopParams) ([]GetWorkspacesWithImminentAutostopRow, error) {
+	rows, err := q.db.QueryContext(ctx, getWorkspacesWithImminentAutostop, arg.Now, arg.Before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWorkspacesWithImminentAutostopRow
+	for rows.Next() {
+		var i GetWorkspacesWithImminentAutostopRow
+		if err := rows.Scan(
+			&i.Workspace.ID,
+			&i.Workspace.CreatedAt,
+			&i.Workspace.UpdatedAt,
+			&i.Workspace.OwnerID,
+			&i.Workspace.OrganizationID,
+			&i.Workspace.TemplateID,
+			&i.Workspace.Deleted,
+			&i.Workspace.Name,
+			&i.Workspace.AutostartSchedule,
+			&i.Workspace.Ttl,
+			&i.Workspace.LastUsedAt,
+			&i.Workspace.LockedAt,
+			&i.Workspace.DeletingAt,
+			&i.Workspace.GroupACL,
+			&i.Workspace.LockReason,
+			&i.BuildID,
+			&i.BuildDeadline,
+			&i.OwnerUsername,
+			&i.OwnerEmail,
+			&i.TemplateName,
 		); err != nil {
 			return nil, err
 		}
@@ -9519,7 +14413,7 @@ INSERT INTO
 		last_used_at
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at, group_acl, lock_reason
 `
 
 type InsertWorkspaceParams struct {
@@ -9563,6 +14457,8 @@ func (q *sqlQuerier) InsertWorkspace(ctx context.Context, arg InsertWorkspacePar
 		&i.LastUsedAt,
 		&i.LockedAt,
 		&i.DeletingAt,
+		&i.GroupACL,
+		&i.LockReason,
 	)
 	return i, err
 }
@@ -9575,7 +14471,7 @@ SET
 WHERE
 	id = $1
 	AND deleted = false
-RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at
+RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, locked_at, deleting_at, group_acl, lock_reason
 `
 
 type UpdateWorkspaceParams struct {
@@ -9600,6 +14496,8 @@ func (q *sqlQuerier) UpdateWorkspace(ctx context.Context, arg UpdateWorkspacePar
 		&i.LastUsedAt,
 		&i.LockedAt,
 		&i.DeletingAt,
+		&i.GroupACL,
+		&i.LockReason,
 	)
 	return i, err
 }
@@ -9642,6 +14540,25 @@ func (q *sqlQuerier) UpdateWorkspaceDeletedByID(ctx context.Context, arg UpdateW
 	return err
 }
 
+const updateWorkspaceGroupACLByID = `-- name: UpdateWorkspaceGroupACLByID :exec
+UPDATE
+	workspaces
+SET
+	group_acl = $2
+WHERE
+	id = $1
+`
+
+type UpdateWorkspaceGroupACLByIDParams struct {
+	ID       uuid.UUID    `db:"id" json:"id"`
+	GroupACL WorkspaceACL `db:"group_acl" json:"group_acl"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceGroupACLByID(ctx context.Context, arg UpdateWorkspaceGroupACLByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceGroupACLByID, arg.ID, arg.GroupACL)
+	return err
+}
+
 const updateWorkspaceLastUsedAt = `-- name: UpdateWorkspaceLastUsedAt :exec
 UPDATE
 	workspaces
@@ -9666,28 +14583,31 @@ UPDATE
 	workspaces
 SET
 	locked_at = $2,
+	lock_reason = $3,
 	-- When a workspace is unlocked we want to update the last_used_at to avoid the workspace getting re-locked.
 	-- if we're locking the workspace then we leave it alone.
 	last_used_at = CASE WHEN $2::timestamptz IS NULL THEN now() at time zone 'utc' ELSE last_used_at END,
 	-- If locked_at is null (meaning unlocked) or the template-defined locked_ttl is 0 we should set
-	-- deleting_at to NULL else set it to the locked_at + locked_ttl duration.
-	deleting_at = CASE WHEN $2::timestamptz IS NULL OR templates.locked_ttl = 0 THEN NULL ELSE $2::timestamptz + INTERVAL '1 milliseconds' * templates.locked_ttl / 1000000 END
+	-- deleting_at to NULL else set it to the locked_at + locked_ttl duration. Templates that archive
+	-- workspaces delete them once the archive_retention has passed after that.
+	deleting_at = CASE WHEN $2::timestamptz IS NULL OR templates.locked_ttl = 0 THEN NULL ELSE $2::timestamptz + INTERVAL '1 milliseconds' * (templates.locked_ttl + templates.archive_retention) / 1000000 END
 FROM
 	templates
 WHERE
 	workspaces.template_id = templates.id
 AND
 	workspaces.id = $1
-RETURNING workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.locked_at, workspaces.deleting_at
+RETURNING workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.locked_at, workspaces.deleting_at, workspaces.group_acl, workspaces.lock_reason
 `
 
 type UpdateWorkspaceLockedDeletingAtParams struct {
-	ID       uuid.UUID    `db:"id" json:"id"`
-	LockedAt sql.NullTime `db:"locked_at" json:"locked_at"`
+	ID         uuid.UUID               `db:"id" json:"id"`
+	LockedAt   sql.NullTime            `db:"locked_at" json:"locked_at"`
+	LockReason NullWorkspaceLockReason `db:"lock_reason" json:"lock_reason"`
 }
 
 func (q *sqlQuerier) UpdateWorkspaceLockedDeletingAt(ctx context.Context, arg UpdateWorkspaceLockedDeletingAtParams) (Workspace, error) {
-	row := q.db.QueryRowContext(ctx, updateWorkspaceLockedDeletingAt, arg.ID, arg.LockedAt)
+	row := q.db.QueryRowContext(ctx, updateWorkspaceLockedDeletingAt, arg.ID, arg.LockedAt, arg.LockReason)
 	var i Workspace
 	err := row.Scan(
 		&i.ID,
@@ -9703,6 +14623,8 @@ func (q *sqlQuerier) UpdateWorkspaceLockedDeletingAt(ctx context.Context, arg Up
 		&i.LastUsedAt,
 		&i.LockedAt,
 		&i.DeletingAt,
+		&i.GroupACL,
+		&i.LockReason,
 	)
 	return i, err
 }
@@ -9730,19 +14652,113 @@ const updateWorkspacesDeletingAtByTemplateID = `-- name: UpdateWorkspacesDeletin
 UPDATE
 	workspaces
 SET
-	deleting_at = CASE WHEN $1::bigint = 0 THEN NULL ELSE locked_at + interval '1 milliseconds' * $1::bigint END
+	deleting_at = CASE WHEN $1::bigint = 0 THEN NULL ELSE locked_at + interval '1 milliseconds' * ($1::bigint + $2::bigint) END
 WHERE
-	template_id = $2
+	template_id = $3
 AND
 	locked_at IS NOT NULL
 `
 
 type UpdateWorkspacesDeletingAtByTemplateIDParams struct {
-	LockedTtlMs int64     `db:"locked_ttl_ms" json:"locked_ttl_ms"`
-	TemplateID  uuid.UUID `db:"template_id" json:"template_id"`
+	LockedTtlMs        int64     `db:"locked_ttl_ms" json:"locked_ttl_ms"`
+	ArchiveRetentionMs int64     `db:"archive_retention_ms" json:"archive_retention_ms"`
+	TemplateID         uuid.UUID `db:"template_id" json:"template_id"`
 }
 
 func (q *sqlQuerier) UpdateWorkspacesDeletingAtByTemplateID(ctx context.Context, arg UpdateWorkspacesDeletingAtByTemplateIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateWorkspacesDeletingAtByTemplateID, arg.LockedTtlMs, arg.TemplateID)
+	_, err := q.db.ExecContext(ctx, updateWorkspacesDeletingAtByTemplateID, arg.LockedTtlMs, arg.ArchiveRetentionMs, arg.TemplateID)
+	return err
+}
+
+const deleteWorkspaceScheduleOverrideByWorkspaceID = `-- name: DeleteWorkspaceScheduleOverrideByWorkspaceID :exec
+DELETE FROM
+	workspace_schedule_overrides
+WHERE
+	workspace_id = $1
+`
+
+func (q *sqlQuerier) DeleteWorkspaceScheduleOverrideByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteWorkspaceScheduleOverrideByWorkspaceID, workspaceID)
 	return err
 }
+
+const getWorkspaceScheduleOverrideByWorkspaceID = `-- name: GetWorkspaceScheduleOverrideByWorkspaceID :one
+SELECT
+	workspace_id, created_at, updated_at, default_ttl, max_ttl, restart_requirement_days_of_week, restart_requirement_weeks
+FROM
+	workspace_schedule_overrides
+WHERE
+	workspace_id = $1
+`
+
+func (q *sqlQuerier) GetWorkspaceScheduleOverrideByWorkspaceID(ctx context.Context, workspaceID uuid.UUID) (WorkspaceScheduleOverride, error) {
+	row := q.db.QueryRowContext(ctx, getWorkspaceScheduleOverrideByWorkspaceID, workspaceID)
+	var i WorkspaceScheduleOverride
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DefaultTTL,
+		&i.MaxTTL,
+		&i.RestartRequirementDaysOfWeek,
+		&i.RestartRequirementWeeks,
+	)
+	return i, err
+}
+
+const upsertWorkspaceScheduleOverride = `-- name: UpsertWorkspaceScheduleOverride :one
+INSERT INTO
+	workspace_schedule_overrides (
+		workspace_id,
+		created_at,
+		updated_at,
+		default_ttl,
+		max_ttl,
+		restart_requirement_days_of_week,
+		restart_requirement_weeks
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT
+	(workspace_id)
+DO UPDATE SET
+	updated_at = $3,
+	default_ttl = $4,
+	max_ttl = $5,
+	restart_requirement_days_of_week = $6,
+	restart_requirement_weeks = $7
+RETURNING workspace_id, created_at, updated_at, default_ttl, max_ttl, restart_requirement_days_of_week, restart_requirement_weeks
+`
+
+type UpsertWorkspaceScheduleOverrideParams struct {
+	WorkspaceID                  uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	CreatedAt                    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt                    time.Time `db:"updated_at" json:"updated_at"`
+	DefaultTTL                   int64     `db:"default_ttl" json:"default_ttl"`
+	MaxTTL                       int64     `db:"max_ttl" json:"max_ttl"`
+	RestartRequirementDaysOfWeek int16     `db:"restart_requirement_days_of_week" json:"restart_requirement_days_of_week"`
+	RestartRequirementWeeks      int64     `db:"restart_requirement_weeks" json:"restart_requirement_weeks"`
+}
+
+func (q *sqlQuerier) UpsertWorkspaceScheduleOverride(ctx context.Context, arg UpsertWorkspaceScheduleOverrideParams) (WorkspaceScheduleOverride, error) {
+	row := q.db.QueryRowContext(ctx, upsertWorkspaceScheduleOverride,
+		arg.WorkspaceID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.DefaultTTL,
+		arg.MaxTTL,
+		arg.RestartRequirementDaysOfWeek,
+		arg.RestartRequirementWeeks,
+	)
+	var i WorkspaceScheduleOverride
+	err := row.Scan(
+		&i.WorkspaceID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DefaultTTL,
+		&i.MaxTTL,
+		&i.RestartRequirementDaysOfWeek,
+		&i.RestartRequirementWeeks,
+	)
+	return i, err
+}
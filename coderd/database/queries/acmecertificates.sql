@@ -0,0 +1,26 @@
+-- name: GetACMECertificate :one
+SELECT
+	*
+FROM
+	acme_certificates
+WHERE
+	workspace_proxy_id = $1
+	AND domain = $2;
+
+-- name: UpsertACMECertificate :exec
+INSERT INTO
+	acme_certificates (
+		workspace_proxy_id,
+		domain,
+		data,
+		not_after,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(workspace_proxy_id, domain)
+DO UPDATE SET
+	data = $3,
+	not_after = $4,
+	updated_at = $5;
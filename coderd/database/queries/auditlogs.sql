@@ -126,3 +126,61 @@ INSERT INTO
     )
 VALUES
 	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING *;
+
+-- GetAuditLogsCreatedBefore returns the oldest audit logs created before the
+-- given time, oldest first. It's used to archive expired audit logs.
+-- name: GetAuditLogsCreatedBefore :many
+SELECT
+	*
+FROM
+	audit_logs
+WHERE
+	"time" < @created_before :: timestamptz
+ORDER BY
+	"time" ASC, id ASC
+LIMIT
+	@limit_count :: int;
+
+-- name: DeleteAuditLogsByIDs :exec
+DELETE FROM
+	audit_logs
+WHERE
+	id = ANY(@ids :: uuid [ ]);
+
+-- GetAuditLogsAfterCursor returns the audit logs after the cursor that were
+-- created before the given time, oldest first. It's used to export audit logs
+-- to an audit export sink.
+-- name: GetAuditLogsAfterCursor :many
+SELECT
+	*
+FROM
+	audit_logs
+WHERE
+	("time", id) > (@after_time :: timestamptz, @after_id :: uuid)
+	AND "time" < @created_before :: timestamptz
+ORDER BY
+	"time" ASC, id ASC
+LIMIT
+	@limit_count :: int;
+
+-- name: GetAuditLogExportCursor :one
+SELECT
+	*
+FROM
+	audit_log_export_cursors
+WHERE
+	sink = $1;
+
+-- name: UpsertAuditLogExportCursor :exec
+INSERT INTO
+	audit_log_export_cursors (sink, last_time, last_id, leased_by, leased_until, updated_at)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+ON CONFLICT
+	(sink)
+DO UPDATE SET
+	last_time = $2,
+	last_id = $3,
+	leased_by = $4,
+	leased_until = $5,
+	updated_at = $6;
@@ -0,0 +1,56 @@
+-- name: GetCustomRoles :many
+SELECT
+	*
+FROM
+	custom_roles
+ORDER BY
+	name;
+
+-- name: GetCustomRoleByName :one
+SELECT
+	*
+FROM
+	custom_roles
+WHERE
+	name = $1;
+
+-- name: InsertCustomRole :one
+INSERT INTO
+	custom_roles (
+		name,
+		display_name,
+		site_permissions,
+		user_permissions,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: UpdateCustomRole :one
+UPDATE
+	custom_roles
+SET
+	display_name = $2,
+	site_permissions = $3,
+	user_permissions = $4,
+	updated_at = $5
+WHERE
+	name = $1
+RETURNING *;
+
+-- name: DeleteCustomRole :exec
+-- Users keep the names of their roles, so the role is removed from all users
+-- before it is deleted. Unknown role names fail authorization.
+WITH removed AS (
+	UPDATE
+		users
+	SET
+		rbac_roles = array_remove(rbac_roles, @name :: text)
+	WHERE
+		@name :: text = ANY(rbac_roles)
+)
+DELETE FROM
+	custom_roles
+WHERE
+	name = @name;
@@ -14,7 +14,18 @@ AND
 AND
 	users.deleted = 'false';
 
+-- GetGroupMemberUserIDs returns the IDs of all members of a group, unlike
+-- GetGroupMembers it includes members that aren't active.
+-- name: GetGroupMemberUserIDs :many
+SELECT
+	user_id
+FROM
+	group_members
+WHERE
+	group_id = $1;
+
 -- InsertUserGroupsByName adds a user to all provided groups, if they exist.
+-- Groups pushed by SCIM are skipped, their members are managed by SCIM.
 -- name: InsertUserGroupsByName :exec
 WITH groups AS (
     SELECT
@@ -23,7 +34,8 @@ WITH groups AS (
         groups
     WHERE
         groups.organization_id = @organization_id AND
-        groups.name = ANY(@group_names :: text [])
+        groups.name = ANY(@group_names :: text []) AND
+        groups.source != 'scim'
 )
 INSERT INTO
     group_members (user_id, group_id)
@@ -33,12 +45,13 @@ SELECT
 FROM
     groups;
 
+-- Groups pushed by SCIM are skipped, their members are managed by SCIM.
 -- name: DeleteGroupMembersByOrgAndUser :exec
 DELETE FROM
 	group_members
 WHERE
 	group_members.user_id = @user_id
-	AND group_id = ANY(SELECT id FROM groups WHERE organization_id = @organization_id);
+	AND group_id = ANY(SELECT id FROM groups WHERE organization_id = @organization_id AND source != 'scim');
 
 -- name: InsertGroupMember :exec
 INSERT INTO
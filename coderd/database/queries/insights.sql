@@ -169,3 +169,188 @@ SELECT
 FROM unique_template_params utp
 JOIN workspace_build_parameters wbp ON (utp.workspace_build_ids @> ARRAY[wbp.workspace_build_id] AND utp.name = wbp.name)
 GROUP BY utp.num, utp.name, utp.display_name, utp.description, utp.options, utp.template_ids, utp.type, wbp.value;
+
+-- name: GetAppSessionInsights :many
+-- GetAppSessionInsights returns the number of sessions and the time spent in
+-- each workspace app per user, for sessions that started within the given
+-- timeframe. The result can be filtered on template_ids, meaning only sessions
+-- in workspaces based on those templates will be included.
+SELECT
+	was.user_id,
+	users.username,
+	users.avatar_url,
+	workspaces.template_id,
+	was.access_method,
+	was.slug_or_port,
+	COALESCE(SUM(was.requests), 0)::bigint AS sessions,
+	COALESCE(SUM(was.active_duration_ms), 0)::bigint AS active_duration_ms,
+	MIN(was.session_started_at)::timestamptz AS first_session_started_at,
+	MAX(was.session_ended_at)::timestamptz AS last_session_ended_at
+FROM workspace_app_stats was
+JOIN users ON (users.id = was.user_id)
+JOIN workspaces ON (workspaces.id = was.workspace_id)
+WHERE
+	was.session_started_at >= @start_time
+	AND was.session_started_at < @end_time
+	AND CASE WHEN COALESCE(array_length(@template_ids::uuid[], 1), 0) > 0 THEN workspaces.template_id = ANY(@template_ids::uuid[]) ELSE TRUE END
+GROUP BY was.user_id, users.username, users.avatar_url, workspaces.template_id, was.access_method, was.slug_or_port
+ORDER BY was.user_id ASC, workspaces.template_id ASC, was.slug_or_port ASC, was.access_method ASC;
+
+-- name: GetScheduleInsights :many
+-- GetScheduleInsights returns how long the workspaces of each template existed,
+-- were running and were stopped by their schedule within the given timeframe,
+-- based on the build history. A build lasts until the next build of the
+-- workspace, workspaces are running after successful start builds and stopped
+-- by their schedule after autostop or restart requirement builds. The result
+-- can be filtered on template_ids, meaning only workspaces based on those
+-- templates will be included.
+WITH build_intervals AS (
+	SELECT
+		workspaces.template_id,
+		workspace_builds.transition,
+		workspace_builds.reason,
+		workspace_builds.created_at,
+		provisioner_jobs.error IS NULL AS succeeded,
+		GREATEST(workspace_builds.created_at, @start_time::timestamptz) AS interval_start,
+		LEAST(
+			COALESCE(
+				LEAD(workspace_builds.created_at) OVER (PARTITION BY workspace_builds.workspace_id ORDER BY workspace_builds.build_number),
+				@end_time::timestamptz
+			),
+			@end_time::timestamptz
+		) AS interval_end
+	FROM workspace_builds
+	JOIN workspaces ON (workspaces.id = workspace_builds.workspace_id)
+	JOIN provisioner_jobs ON (provisioner_jobs.id = workspace_builds.job_id)
+	WHERE
+		workspace_builds.created_at < @end_time::timestamptz
+		AND CASE WHEN COALESCE(array_length(@template_ids::uuid[], 1), 0) > 0 THEN workspaces.template_id = ANY(@template_ids::uuid[]) ELSE TRUE END
+)
+
+SELECT
+	template_id,
+	COALESCE(SUM(EXTRACT(EPOCH FROM interval_end - interval_start)) FILTER (WHERE transition != 'delete'), 0)::bigint AS allocated_seconds,
+	COALESCE(SUM(EXTRACT(EPOCH FROM interval_end - interval_start)) FILTER (WHERE transition = 'start' AND succeeded), 0)::bigint AS running_seconds,
+	COALESCE(SUM(EXTRACT(EPOCH FROM interval_end - interval_start)) FILTER (WHERE transition = 'stop' AND reason IN ('autostop', 'restart_requirement')), 0)::bigint AS schedule_stopped_seconds,
+	COUNT(*) FILTER (WHERE created_at >= @start_time::timestamptz AND transition = 'start' AND reason = 'autostart') AS autostarts,
+	COUNT(*) FILTER (WHERE created_at >= @start_time::timestamptz AND transition = 'stop' AND reason IN ('autostop', 'restart_requirement')) AS autostops
+FROM build_intervals
+WHERE interval_end > interval_start
+GROUP BY template_id
+ORDER BY template_id ASC;
+
+-- name: GetAppAccessLogInsights :many
+-- GetAppAccessLogInsights returns the number of requests to each workspace app
+-- and their median and 95th percentile latency per user, path prefix and status
+-- code, for requests received within the given timeframe. Requests of users
+-- that were not signed in to Coder have a NULL user_id. The result can be
+-- filtered on template_ids, meaning only requests to workspaces based on those
+-- templates will be included.
+SELECT
+	wal.user_id,
+	COALESCE(users.username, '')::text AS username,
+	COALESCE(users.avatar_url, '')::text AS avatar_url,
+	workspaces.template_id,
+	wal.access_method,
+	wal.slug_or_port,
+	wal.path_prefix,
+	wal.status_code,
+	COUNT(*)::bigint AS requests,
+	(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY wal.latency_ms))::FLOAT AS latency_50,
+	(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY wal.latency_ms))::FLOAT AS latency_95,
+	MAX(wal.created_at)::timestamptz AS last_request_at
+FROM workspace_app_access_logs wal
+LEFT JOIN users ON (users.id = wal.user_id)
+JOIN workspaces ON (workspaces.id = wal.workspace_id)
+WHERE
+	wal.created_at >= @start_time
+	AND wal.created_at < @end_time
+	AND CASE WHEN COALESCE(array_length(@template_ids::uuid[], 1), 0) > 0 THEN workspaces.template_id = ANY(@template_ids::uuid[]) ELSE TRUE END
+GROUP BY wal.user_id, users.username, users.avatar_url, workspaces.template_id, wal.access_method, wal.slug_or_port, wal.path_prefix, wal.status_code
+ORDER BY wal.user_id ASC, workspaces.template_id ASC, wal.slug_or_port ASC, wal.access_method ASC, wal.path_prefix ASC, wal.status_code ASC;
+
+-- name: GetTemplateVersionBuildInsights :many
+-- GetTemplateVersionBuildInsights returns the number of start builds of each
+-- template version and how many of them failed, the median and 95th percentile
+-- time that successful builds took to provision, and the median and 95th
+-- percentile time that their agents took to connect, in intervals of the given
+-- length between start and end time. Builds are counted in the interval they
+-- were created in, canceled and unfinished builds are not counted. Percentiles
+-- are -1 if there is no data. The result can be filtered on template_ids,
+-- meaning only builds of those templates will be included.
+WITH d AS (
+	-- sqlc workaround, use SELECT generate_series instead of SELECT * FROM generate_series.
+	-- Subtract 1 second from end_time to avoid including the next interval in the results.
+	SELECT generate_series(@start_time::timestamptz, (@end_time::timestamptz) - '1 second'::interval, @interval_seconds::bigint * '1 second'::interval) AS d
+), ts AS (
+	SELECT
+		d::timestamptz AS from_,
+		LEAST(d + @interval_seconds::bigint * '1 second'::interval, @end_time::timestamptz)::timestamptz AS to_
+	FROM d
+), builds AS (
+	SELECT
+		ts.from_,
+		ts.to_,
+		workspaces.template_id,
+		workspace_builds.template_version_id,
+		workspace_builds.job_id,
+		provisioner_jobs.error IS NOT NULL AS failed,
+		EXTRACT(EPOCH FROM provisioner_jobs.completed_at - provisioner_jobs.started_at) * 1000 AS duration_ms
+	FROM ts
+	JOIN workspace_builds ON (
+		workspace_builds.created_at >= ts.from_
+		AND workspace_builds.created_at < ts.to_
+	)
+	JOIN workspaces ON (workspaces.id = workspace_builds.workspace_id)
+	JOIN provisioner_jobs ON (provisioner_jobs.id = workspace_builds.job_id)
+	WHERE
+		workspace_builds.transition = 'start'
+		AND provisioner_jobs.completed_at IS NOT NULL
+		AND provisioner_jobs.canceled_at IS NULL
+		AND CASE WHEN COALESCE(array_length(@template_ids::uuid[], 1), 0) > 0 THEN workspaces.template_id = ANY(@template_ids::uuid[]) ELSE TRUE END
+), build_stats AS (
+	SELECT
+		from_,
+		to_,
+		template_id,
+		template_version_id,
+		COUNT(*) AS builds,
+		COUNT(*) FILTER (WHERE failed) AS failed_builds,
+		PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE NOT failed) AS duration_50,
+		PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY duration_ms) FILTER (WHERE NOT failed) AS duration_95
+	FROM builds
+	GROUP BY from_, to_, template_id, template_version_id
+), agent_stats AS (
+	SELECT
+		builds.from_,
+		builds.template_version_id,
+		PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM workspace_agents.first_connected_at - workspace_agents.created_at) * 1000) AS connect_50,
+		PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM workspace_agents.first_connected_at - workspace_agents.created_at) * 1000) AS connect_95
+	FROM builds
+	JOIN workspace_resources ON (workspace_resources.job_id = builds.job_id)
+	JOIN workspace_agents ON (workspace_agents.resource_id = workspace_resources.id)
+	WHERE
+		NOT builds.failed
+		AND workspace_agents.first_connected_at IS NOT NULL
+	GROUP BY builds.from_, builds.template_version_id
+)
+
+SELECT
+	build_stats.from_ AS start_time,
+	build_stats.to_ AS end_time,
+	build_stats.template_id,
+	build_stats.template_version_id,
+	template_versions.name AS template_version_name,
+	build_stats.builds,
+	build_stats.failed_builds,
+	COALESCE(build_stats.duration_50, -1)::FLOAT AS build_duration_50,
+	COALESCE(build_stats.duration_95, -1)::FLOAT AS build_duration_95,
+	COALESCE(agent_stats.connect_50, -1)::FLOAT AS agent_connect_50,
+	COALESCE(agent_stats.connect_95, -1)::FLOAT AS agent_connect_95
+FROM build_stats
+JOIN template_versions ON (template_versions.id = build_stats.template_version_id)
+LEFT JOIN agent_stats ON (
+	agent_stats.from_ = build_stats.from_
+	AND agent_stats.template_version_id = build_stats.template_version_id
+)
+ORDER BY build_stats.from_ ASC, build_stats.template_id ASC, template_versions.created_at ASC;
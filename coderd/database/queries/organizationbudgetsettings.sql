@@ -0,0 +1,80 @@
+-- name: GetOrganizationBudgetSettings :one
+SELECT
+	*
+FROM
+	organization_budget_settings
+WHERE
+	organization_id = $1;
+
+-- name: GetOrganizationBudgetUsages :many
+-- Returns the budget settings of every organization with a budget together
+-- with the daily cost of the latest builds of the organization's workspaces.
+WITH latest_builds AS (
+SELECT
+	DISTINCT ON
+	(workspace_id) id,
+	workspace_id,
+	daily_cost
+FROM
+	workspace_builds wb
+ORDER BY
+	workspace_id,
+	created_at DESC
+),
+consumed AS (
+SELECT
+	workspaces.organization_id,
+	SUM(latest_builds.daily_cost) AS credits_consumed
+FROM
+	workspaces
+JOIN latest_builds ON
+	latest_builds.workspace_id = workspaces.id
+WHERE
+	NOT workspaces.deleted
+GROUP BY
+	workspaces.organization_id
+)
+SELECT
+	organization_budget_settings.*,
+	organizations.name AS organization_name,
+	coalesce(consumed.credits_consumed, 0)::BIGINT AS credits_consumed
+FROM
+	organization_budget_settings
+JOIN organizations ON
+	organizations.id = organization_budget_settings.organization_id
+LEFT JOIN consumed ON
+	consumed.organization_id = organization_budget_settings.organization_id
+WHERE
+	organization_budget_settings.daily_budget > 0;
+
+-- name: UpdateOrganizationBudgetAlertedThreshold :exec
+UPDATE
+	organization_budget_settings
+SET
+	alerted_threshold_percent = $2
+WHERE
+	organization_id = $1;
+
+-- name: UpsertOrganizationBudgetSettings :one
+-- Changing the settings resets the alerted threshold, so alerts are sent again
+-- for thresholds that are still crossed with the new settings.
+INSERT INTO
+	organization_budget_settings (
+		organization_id,
+		created_at,
+		updated_at,
+		daily_budget,
+		warning_threshold_percent,
+		critical_threshold_percent
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+ON CONFLICT
+	(organization_id)
+DO UPDATE SET
+	updated_at = $3,
+	daily_budget = $4,
+	warning_threshold_percent = $5,
+	critical_threshold_percent = $6,
+	alerted_threshold_percent = 0
+RETURNING *;
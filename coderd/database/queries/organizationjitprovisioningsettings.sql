@@ -0,0 +1,26 @@
+-- name: GetOrganizationJITProvisioningSettings :one
+SELECT
+	*
+FROM
+	organization_jit_provisioning_settings
+WHERE
+	organization_id = $1;
+
+-- name: UpsertOrganizationJITProvisioningSettings :one
+INSERT INTO
+	organization_jit_provisioning_settings (
+		organization_id,
+		created_at,
+		updated_at,
+		template_id,
+		workspace_name
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(organization_id)
+DO UPDATE SET
+	updated_at = $3,
+	template_id = $4,
+	workspace_name = $5
+RETURNING *;
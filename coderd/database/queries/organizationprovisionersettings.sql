@@ -0,0 +1,24 @@
+-- name: GetOrganizationProvisionerSettings :one
+SELECT
+	*
+FROM
+	organization_provisioner_settings
+WHERE
+	organization_id = $1;
+
+-- name: UpsertOrganizationProvisionerSettings :one
+INSERT INTO
+	organization_provisioner_settings (
+		organization_id,
+		created_at,
+		updated_at,
+		max_concurrent_jobs
+	)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT
+	(organization_id)
+DO UPDATE SET
+	updated_at = $3,
+	max_concurrent_jobs = $4
+RETURNING *;
@@ -0,0 +1,30 @@
+-- name: GetOrganizationScheduleSettings :one
+SELECT
+	*
+FROM
+	organization_schedule_settings
+WHERE
+	organization_id = $1;
+
+-- name: UpsertOrganizationScheduleSettings :one
+INSERT INTO
+	organization_schedule_settings (
+		organization_id,
+		created_at,
+		updated_at,
+		default_ttl,
+		restart_requirement_days_of_week,
+		restart_requirement_weeks,
+		default_quiet_hours_schedule
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT
+	(organization_id)
+DO UPDATE SET
+	updated_at = $3,
+	default_ttl = $4,
+	restart_requirement_days_of_week = $5,
+	restart_requirement_weeks = $6,
+	default_quiet_hours_schedule = $7
+RETURNING *;
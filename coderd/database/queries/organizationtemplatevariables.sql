@@ -0,0 +1,35 @@
+-- name: GetOrganizationTemplateVariables :many
+SELECT
+	*
+FROM
+	organization_template_variables
+WHERE
+	organization_id = $1
+ORDER BY
+	name ASC;
+
+-- name: UpsertOrganizationTemplateVariable :one
+INSERT INTO
+	organization_template_variables (
+		organization_id,
+		name,
+		value,
+		sensitive,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+ON CONFLICT
+	(organization_id, name)
+DO UPDATE SET
+	value = $3,
+	sensitive = $4,
+	updated_at = $6
+RETURNING *;
+
+-- name: DeleteOrganizationTemplateVariable :exec
+DELETE FROM
+	organization_template_variables
+WHERE
+	organization_id = $1 AND name = $2;
@@ -0,0 +1,143 @@
+-- name: GetTemplatePrebuildPool :one
+SELECT
+	*
+FROM
+	template_prebuild_pools
+WHERE
+	template_id = $1;
+
+-- name: GetTemplatePrebuildPools :many
+-- Returns the pools of all templates that aren't deleted and keep at least one
+-- prebuilt workspace.
+SELECT
+	template_prebuild_pools.*
+FROM
+	template_prebuild_pools
+JOIN
+	templates
+ON
+	templates.id = template_prebuild_pools.template_id
+WHERE
+	templates.deleted = false
+	AND template_prebuild_pools.size > 0;
+
+-- name: UpsertTemplatePrebuildPool :one
+INSERT INTO
+	template_prebuild_pools (
+		template_id,
+		size,
+		updated_at
+	)
+VALUES
+	($1, $2, $3)
+ON CONFLICT
+	(template_id)
+DO UPDATE SET
+	size = $2,
+	updated_at = $3
+RETURNING *;
+
+-- name: GetPrebuiltWorkspaces :many
+-- Returns the prebuilt workspaces that aren't deleted with the status of
+-- their latest build. Prebuilt workspaces are owned by @owner_id until they
+-- are claimed.
+SELECT
+	workspaces.id,
+	workspaces.created_at,
+	workspaces.template_id,
+	latest_build.template_version_id,
+	latest_build.transition,
+	latest_build.started_at,
+	latest_build.canceled_at,
+	latest_build.completed_at,
+	latest_build.error
+FROM
+	workspaces
+JOIN LATERAL (
+	SELECT
+		workspace_builds.template_version_id,
+		workspace_builds.transition,
+		provisioner_jobs.started_at,
+		provisioner_jobs.canceled_at,
+		provisioner_jobs.completed_at,
+		provisioner_jobs.error
+	FROM
+		workspace_builds
+	JOIN
+		provisioner_jobs
+	ON
+		provisioner_jobs.id = workspace_builds.job_id
+	WHERE
+		workspace_builds.workspace_id = workspaces.id
+	ORDER BY
+		build_number DESC
+	LIMIT
+		1
+) latest_build ON TRUE
+WHERE
+	workspaces.owner_id = @owner_id
+	AND workspaces.deleted = false
+ORDER BY
+	workspaces.created_at ASC;
+
+-- name: ClaimPrebuiltWorkspace :one
+-- Assigns the oldest prebuilt workspace of the template that is ready to a
+-- user. Prebuilt workspaces are ready once they have been stopped
+-- successfully on the active version of their template. Returns no rows if
+-- there is no such workspace.
+UPDATE
+	workspaces
+SET
+	owner_id = @new_owner_id,
+	name = @name,
+	autostart_schedule = @autostart_schedule,
+	ttl = @ttl,
+	created_at = @now,
+	updated_at = @now,
+	last_used_at = @now
+WHERE
+	id = (
+		SELECT
+			workspaces.id
+		FROM
+			workspaces
+		JOIN
+			templates
+		ON
+			templates.id = workspaces.template_id
+		JOIN LATERAL (
+			SELECT
+				workspace_builds.template_version_id,
+				workspace_builds.transition,
+				provisioner_jobs.canceled_at,
+				provisioner_jobs.completed_at,
+				provisioner_jobs.error
+			FROM
+				workspace_builds
+			JOIN
+				provisioner_jobs
+			ON
+				provisioner_jobs.id = workspace_builds.job_id
+			WHERE
+				workspace_builds.workspace_id = workspaces.id
+			ORDER BY
+				build_number DESC
+			LIMIT
+				1
+		) latest_build ON TRUE
+		WHERE
+			workspaces.owner_id = @prebuilds_owner_id
+			AND workspaces.template_id = @template_id
+			AND workspaces.deleted = false
+			AND latest_build.template_version_id = templates.active_version_id
+			AND latest_build.transition = 'stop'::workspace_transition
+			AND latest_build.completed_at IS NOT NULL
+			AND latest_build.canceled_at IS NULL
+			AND latest_build.error IS NULL
+		ORDER BY
+			workspaces.created_at ASC
+		LIMIT
+			1
+		FOR UPDATE OF workspaces SKIP LOCKED
+	)
+RETURNING *;
@@ -15,3 +15,16 @@ INSERT INTO
 	)
 VALUES
 	($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: UpdateProvisionerDaemonCacheStats :exec
+UPDATE
+	provisioner_daemons
+SET
+	updated_at = @updated_at,
+	cache_entries = @cache_entries,
+	cache_size_bytes = @cache_size_bytes,
+	cache_max_size_bytes = @cache_max_size_bytes,
+	cache_hits = @cache_hits,
+	cache_misses = @cache_misses
+WHERE
+	id = @id;
@@ -11,11 +11,15 @@ WHERE
 
 -- name: InsertProvisionerJobLogs :many
 INSERT INTO
-	provisioner_job_logs
+	provisioner_job_logs (job_id, created_at, source, level, stage, output, progress_stage, progress_resource, progress_completed, progress_total)
 SELECT
 	@job_id :: uuid AS job_id,
 	unnest(@created_at :: timestamptz [ ]) AS created_at,
 	unnest(@source :: log_source [ ]) AS source,
 	unnest(@level :: log_level [ ]) AS LEVEL,
 	unnest(@stage :: VARCHAR(128) [ ]) AS stage,
-	unnest(@output :: VARCHAR(1024) [ ]) AS output RETURNING *;
+	unnest(@output :: VARCHAR(1024) [ ]) AS output,
+	unnest(@progress_stage :: text [ ]) AS progress_stage,
+	unnest(@progress_resource :: text [ ]) AS progress_resource,
+	unnest(@progress_completed :: integer [ ]) AS progress_completed,
+	unnest(@progress_total :: integer [ ]) AS progress_total RETURNING *;
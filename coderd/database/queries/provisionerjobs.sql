@@ -1,5 +1,8 @@
 -- Acquires the lock for a single job that isn't started, completed,
--- canceled, and that matches an array of provisioner types.
+-- canceled, and that matches an array of provisioner types. Jobs of
+-- organizations and templates that are running their maximum number of
+-- concurrent jobs are skipped until one of their jobs completes. Jobs are
+-- skipped until their not_before time.
 --
 -- SKIP LOCKED is used to jump over locked rows. This prevents
 -- multiple provisioners from acquiring the same jobs. See:
@@ -20,10 +23,69 @@ WHERE
 		WHERE
 			nested.started_at IS NULL
 			-- Ensure the caller has the correct provisioner.
-			AND nested.provisioner = ANY(@types :: provisioner_type [ ])
+			AND nested.provisioner = ANY(@types :: text [ ])
 			-- Ensure the caller satisfies all job tags.
 			AND nested.tags <@ @tags :: jsonb
+			-- Ensure the job belongs to the organization of the caller, if the
+			-- caller is scoped to one.
+			AND (
+				@organization_id :: uuid = '00000000-0000-0000-0000-000000000000' :: uuid
+				OR nested.organization_id = @organization_id
+			)
+			-- Ensure the job isn't waiting for workspace agents to drain.
+			AND (nested.not_before IS NULL OR nested.not_before <= @started_at)
+			-- Ensure the organization isn't running its maximum number of
+			-- concurrent jobs.
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					organization_provisioner_settings AS settings
+				WHERE
+					settings.organization_id = nested.organization_id
+					AND settings.max_concurrent_jobs > 0
+					AND settings.max_concurrent_jobs <= (
+						SELECT
+							COUNT(*)
+						FROM
+							provisioner_jobs AS running
+						WHERE
+							running.organization_id = nested.organization_id
+							AND running.started_at IS NOT NULL
+							AND running.completed_at IS NULL
+					)
+			)
+			-- Ensure the template of a workspace build isn't running its
+			-- maximum number of concurrent builds.
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					workspace_builds
+				INNER JOIN
+					workspaces ON workspaces.id = workspace_builds.workspace_id
+				INNER JOIN
+					templates ON templates.id = workspaces.template_id
+				WHERE
+					workspace_builds.job_id = nested.id
+					AND templates.max_concurrent_builds > 0
+					AND templates.max_concurrent_builds <= (
+						SELECT
+							COUNT(*)
+						FROM
+							provisioner_jobs AS running
+						INNER JOIN
+							workspace_builds AS running_builds ON running_builds.job_id = running.id
+						INNER JOIN
+							workspaces AS running_workspaces ON running_workspaces.id = running_builds.workspace_id
+						WHERE
+							running_workspaces.template_id = templates.id
+							AND running.started_at IS NOT NULL
+							AND running.completed_at IS NULL
+					)
+			)
 		ORDER BY
+			nested.priority DESC,
 			nested.created_at
 		FOR UPDATE
 		SKIP LOCKED
@@ -39,6 +101,54 @@ FROM
 WHERE
 	id = $1;
 
+-- name: GetProvisionerJobConcurrency :one
+-- Returns the concurrency limits of the organization and template of a job,
+-- and how many other jobs are running against each of them. A limit of zero
+-- means the job isn't limited.
+SELECT
+	provisioner_jobs.organization_id,
+	coalesce(settings.max_concurrent_jobs, 0) :: integer AS organization_max_concurrent_jobs,
+	(
+		SELECT
+			COUNT(*)
+		FROM
+			provisioner_jobs AS running
+		WHERE
+			running.organization_id = provisioner_jobs.organization_id
+			AND running.id != provisioner_jobs.id
+			AND running.started_at IS NOT NULL
+			AND running.completed_at IS NULL
+	) AS organization_running_jobs,
+	coalesce(templates.id, '00000000-0000-0000-0000-000000000000' :: uuid) :: uuid AS template_id,
+	coalesce(templates.max_concurrent_builds, 0) :: integer AS template_max_concurrent_builds,
+	(
+		SELECT
+			COUNT(*)
+		FROM
+			provisioner_jobs AS running
+		INNER JOIN
+			workspace_builds AS running_builds ON running_builds.job_id = running.id
+		INNER JOIN
+			workspaces AS running_workspaces ON running_workspaces.id = running_builds.workspace_id
+		WHERE
+			running_workspaces.template_id = templates.id
+			AND running.id != provisioner_jobs.id
+			AND running.started_at IS NOT NULL
+			AND running.completed_at IS NULL
+	) AS template_running_builds
+FROM
+	provisioner_jobs
+LEFT JOIN
+	organization_provisioner_settings AS settings ON settings.organization_id = provisioner_jobs.organization_id
+LEFT JOIN
+	workspace_builds ON workspace_builds.job_id = provisioner_jobs.id
+LEFT JOIN
+	workspaces ON workspaces.id = workspace_builds.workspace_id
+LEFT JOIN
+	templates ON templates.id = workspaces.template_id
+WHERE
+	provisioner_jobs.id = $1;
+
 -- name: GetProvisionerJobsByIDs :many
 SELECT
 	*
@@ -50,7 +160,7 @@ WHERE
 -- name: GetProvisionerJobsByIDsWithQueuePosition :many
 WITH unstarted_jobs AS (
     SELECT
-        id, created_at
+        id, created_at, priority
     FROM
         provisioner_jobs
     WHERE
@@ -59,7 +169,7 @@ WITH unstarted_jobs AS (
 queue_position AS (
     SELECT
         id,
-        ROW_NUMBER() OVER (ORDER BY created_at ASC) AS queue_position
+        ROW_NUMBER() OVER (ORDER BY priority DESC, created_at ASC) AS queue_position
     FROM
         unstarted_jobs
 ),
@@ -96,10 +206,12 @@ INSERT INTO
 		"type",
 		"input",
 		tags,
-		trace_metadata
+		trace_metadata,
+		priority,
+		not_before
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING *;
 
 -- name: UpdateProvisionerJobByID :exec
 UPDATE
@@ -109,6 +221,15 @@ SET
 WHERE
 	id = $1;
 
+-- name: UpdateProvisionerJobNotBeforeByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = $2,
+	not_before = $3
+WHERE
+	id = $1;
+
 -- name: UpdateProvisionerJobWithCancelByID :exec
 UPDATE
 	provisioner_jobs
@@ -0,0 +1,53 @@
+-- name: InsertProvisionerKey :one
+INSERT INTO
+	provisioner_keys (
+		id,
+		created_at,
+		organization_id,
+		name,
+		hashed_secret,
+		tags
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: GetProvisionerKeyByID :one
+SELECT
+	*
+FROM
+	provisioner_keys
+WHERE
+	id = $1;
+
+-- name: GetProvisionerKeyByOrganizationIDAndName :one
+SELECT
+	*
+FROM
+	provisioner_keys
+WHERE
+	organization_id = @organization_id
+	AND lower(name) = lower(@name);
+
+-- name: GetProvisionerKeysByOrganizationID :many
+SELECT
+	*
+FROM
+	provisioner_keys
+WHERE
+	organization_id = $1
+ORDER BY
+	lower(name);
+
+-- name: UpdateProvisionerKeyLastUsedAt :exec
+UPDATE
+	provisioner_keys
+SET
+	last_used_at = $2
+WHERE
+	id = $1;
+
+-- name: DeleteProvisionerKey :exec
+DELETE FROM
+	provisioner_keys
+WHERE
+	id = $1;
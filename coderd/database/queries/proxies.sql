@@ -55,6 +55,20 @@ SET
 		WHEN length(@token_hashed_secret :: bytea) > 0  THEN @token_hashed_secret :: bytea
 		ELSE  workspace_proxies.token_hashed_secret
 	END,
+	-- A new token replaces any rotated tokens, and like all tokens created by
+	-- users it doesn't expire.
+	token_expires_at = CASE
+		WHEN length(@token_hashed_secret :: bytea) > 0  THEN NULL
+		ELSE  workspace_proxies.token_expires_at
+	END,
+	previous_token_hashed_secret = CASE
+		WHEN length(@token_hashed_secret :: bytea) > 0  THEN NULL
+		ELSE  workspace_proxies.previous_token_hashed_secret
+	END,
+	previous_token_expires_at = CASE
+		WHEN length(@token_hashed_secret :: bytea) > 0  THEN NULL
+		ELSE  workspace_proxies.previous_token_expires_at
+	END,
 	-- Always update this timestamp.
 	updated_at = Now()
 WHERE
@@ -62,6 +76,25 @@ WHERE
 RETURNING *
 ;
 
+-- name: RotateWorkspaceProxyToken :one
+-- Replaces the token of a workspace proxy, and keeps the replaced token valid
+-- until @previous_token_expires_at. The token is only replaced if it still
+-- matches @current_token_hashed_secret, so concurrent rotations by replicas of
+-- the same proxy don't replace each other's tokens.
+UPDATE
+	workspace_proxies
+SET
+	previous_token_hashed_secret = token_hashed_secret,
+	previous_token_expires_at = @previous_token_expires_at :: timestamptz,
+	token_hashed_secret = @token_hashed_secret :: bytea,
+	token_expires_at = @token_expires_at :: timestamptz,
+	updated_at = Now()
+WHERE
+	id = @id
+	AND token_hashed_secret = @current_token_hashed_secret :: bytea
+	AND deleted = false
+RETURNING *;
+
 -- name: GetWorkspaceProxyByID :one
 SELECT
 	*
@@ -28,3 +28,70 @@ FROM
 JOIN latest_builds ON
 	latest_builds.workspace_id = workspaces.id
 WHERE NOT deleted AND workspaces.owner_id = $1;
+
+-- name: GetQuotaResourceMetadataForUser :many
+-- Returns the "cpu", "memory" and "disk" metadata of the resources of the
+-- latest builds of the user's workspaces of a template, which the resource
+-- quotas of the template are enforced against.
+WITH latest_builds AS (
+SELECT
+	DISTINCT ON
+	(workspace_id) id,
+	workspace_id,
+	job_id
+FROM
+	workspace_builds wb
+ORDER BY
+	workspace_id,
+	created_at DESC
+)
+SELECT
+	workspace_resource_metadata.*
+FROM
+	workspaces
+JOIN latest_builds ON
+	latest_builds.workspace_id = workspaces.id
+JOIN workspace_resources ON
+	workspace_resources.job_id = latest_builds.job_id
+JOIN workspace_resource_metadata ON
+	workspace_resource_metadata.workspace_resource_id = workspace_resources.id
+WHERE
+	NOT workspaces.deleted
+	AND workspaces.owner_id = @owner_id
+	AND workspaces.template_id = @template_id
+	AND workspace_resource_metadata.key IN ('cpu', 'memory', 'disk');
+
+-- name: GetQuotaConsumedForUserByWorkspace :many
+-- Returns the daily cost of the latest build of each of the user's workspaces,
+-- which add up to the quota consumed by the user.
+WITH latest_builds AS (
+SELECT
+	DISTINCT ON
+	(workspace_id) id,
+	workspace_id,
+	transition,
+	daily_cost
+FROM
+	workspace_builds wb
+ORDER BY
+	workspace_id,
+	created_at DESC
+)
+SELECT
+	workspaces.id AS workspace_id,
+	workspaces.name AS workspace_name,
+	templates.id AS template_id,
+	templates.name AS template_name,
+	latest_builds.transition,
+	latest_builds.daily_cost
+FROM
+	workspaces
+JOIN latest_builds ON
+	latest_builds.workspace_id = workspaces.id
+JOIN templates ON
+	templates.id = workspaces.template_id
+WHERE
+	NOT workspaces.deleted
+	AND workspaces.owner_id = $1
+ORDER BY
+	workspaces.name ASC;
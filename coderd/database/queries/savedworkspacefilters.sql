@@ -0,0 +1,48 @@
+-- name: GetSavedWorkspaceFiltersByUserID :many
+SELECT
+	*
+FROM
+	saved_workspace_filters
+WHERE
+	user_id = $1
+ORDER BY
+	name ASC;
+
+-- name: GetSavedWorkspaceFilterByUserIDAndName :one
+SELECT
+	*
+FROM
+	saved_workspace_filters
+WHERE
+	user_id = $1
+	AND name = $2;
+
+-- name: InsertSavedWorkspaceFilter :one
+INSERT INTO
+	saved_workspace_filters (
+		id,
+		user_id,
+		name,
+		query,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: UpdateSavedWorkspaceFilter :one
+UPDATE
+	saved_workspace_filters
+SET
+	query = $3,
+	updated_at = $4
+WHERE
+	user_id = $1
+	AND name = $2 RETURNING *;
+
+-- name: DeleteSavedWorkspaceFilter :exec
+DELETE FROM
+	saved_workspace_filters
+WHERE
+	user_id = $1
+	AND name = $2;
@@ -0,0 +1,45 @@
+-- name: GetScheduleHolidays :many
+SELECT
+	*
+FROM
+	schedule_holidays
+ORDER BY
+	date ASC;
+
+-- name: GetScheduleHolidayByID :one
+SELECT
+	*
+FROM
+	schedule_holidays
+WHERE
+	id = $1;
+
+-- name: InsertScheduleHoliday :one
+INSERT INTO
+	schedule_holidays (
+		id,
+		created_at,
+		updated_at,
+		date,
+		name
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: UpdateScheduleHolidayByID :one
+UPDATE
+	schedule_holidays
+SET
+	updated_at = $2,
+	date = $3,
+	name = $4
+WHERE
+	id = $1
+RETURNING *;
+
+-- name: DeleteScheduleHolidayByID :exec
+DELETE FROM
+	schedule_holidays
+WHERE
+	id = $1;
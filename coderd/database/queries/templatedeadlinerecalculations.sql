@@ -0,0 +1,90 @@
+-- name: InsertTemplateDeadlineRecalculation :one
+-- Queues a recalculation of the template. If a recalculation of the template
+-- is already pending, it is returned instead, as it will use the latest
+-- template schedule when it runs.
+INSERT INTO
+	template_deadline_recalculations (
+		id,
+		template_id,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $3)
+ON CONFLICT
+	(template_id) WHERE status = 'pending'
+DO UPDATE SET
+	updated_at = $3
+RETURNING *;
+
+-- name: GetTemplateDeadlineRecalculationByID :one
+SELECT
+	*
+FROM
+	template_deadline_recalculations
+WHERE
+	id = $1;
+
+-- name: GetTemplateDeadlineRecalculationsByTemplateID :many
+SELECT
+	*
+FROM
+	template_deadline_recalculations
+WHERE
+	template_id = $1
+ORDER BY
+	created_at DESC;
+
+-- name: AcquireTemplateDeadlineRecalculation :one
+-- Acquires the oldest pending recalculation, or a running one that has not
+-- been updated since stale_before because the replica running it went away.
+-- SKIP LOCKED lets replicas acquire different recalculations concurrently.
+UPDATE
+	template_deadline_recalculations
+SET
+	status = 'running',
+	updated_at = @now
+WHERE
+	id = (
+		SELECT
+			id
+		FROM
+			template_deadline_recalculations AS nested
+		WHERE
+			nested.status = 'pending'
+			OR (nested.status = 'running' AND nested.updated_at < @stale_before)
+		ORDER BY
+			nested.created_at
+		FOR UPDATE
+		SKIP LOCKED
+		LIMIT
+			1
+	)
+RETURNING *;
+
+-- name: UpdateTemplateDeadlineRecalculationProgressByID :exec
+UPDATE
+	template_deadline_recalculations
+SET
+	updated_at = $2,
+	total_builds = $3,
+	processed_builds = $4
+WHERE
+	id = $1;
+
+-- name: UpdateTemplateDeadlineRecalculationCompletedByID :exec
+UPDATE
+	template_deadline_recalculations
+SET
+	updated_at = $2,
+	completed_at = $2,
+	status = $3,
+	error = $4
+WHERE
+	id = $1;
+
+-- name: DeleteOldTemplateDeadlineRecalculations :exec
+DELETE FROM
+	template_deadline_recalculations
+WHERE
+	completed_at < @completed_before;
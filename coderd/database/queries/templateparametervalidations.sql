@@ -0,0 +1,26 @@
+-- name: GetTemplateParameterValidation :one
+SELECT
+	*
+FROM
+	template_parameter_validations
+WHERE
+	template_id = $1;
+
+-- name: UpsertTemplateParameterValidation :one
+INSERT INTO
+	template_parameter_validations (
+		template_id,
+		webhook_url,
+		constraints,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT
+	(template_id)
+DO UPDATE SET
+	webhook_url = $2,
+	constraints = $3,
+	updated_at = $5
+RETURNING *;
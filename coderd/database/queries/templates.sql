@@ -51,6 +51,18 @@ WHERE
 LIMIT
 	1;
 
+-- name: GetTemplatesBySchedulePolicyTemplateID :many
+-- Returns the templates that inherit their schedule settings from the given
+-- template.
+SELECT
+	*
+FROM
+	template_with_users AS templates
+WHERE
+	schedule_policy_template_id = @schedule_policy_template_id
+	AND deleted = false
+ORDER BY (name, id) ASC;
+
 -- name: GetTemplates :many
 SELECT * FROM template_with_users AS templates
 ORDER BY (name, id) ASC
@@ -104,7 +116,16 @@ SET
 	name = $4,
 	icon = $5,
 	display_name = $6,
-	allow_user_cancel_workspace_jobs = $7
+	allow_user_cancel_workspace_jobs = $7,
+	max_concurrent_builds = $8,
+	max_deadline_extensions_per_day = $9,
+	max_deadline_extension = $10,
+	required_promotion_approvals = $11,
+	user_cpu_quota_millicores = $12,
+	user_memory_quota_bytes = $13,
+	user_disk_quota_bytes = $14,
+	apply_dotfiles = $15,
+	disable_autostop = $16
 WHERE
 	id = $1
 ;
@@ -120,9 +141,27 @@ SET
 	max_ttl = $6,
 	restart_requirement_days_of_week = $7,
 	restart_requirement_weeks = $8,
-	failure_ttl = $9,
-	inactivity_ttl = $10,
-	locked_ttl = $11
+	restart_requirement_timezone = $9,
+	failure_ttl = $10,
+	inactivity_ttl = $11,
+	locked_ttl = $12,
+	activity_bump_ttl = $13,
+	restart_requirement_spread = $14,
+	update_on_restart = $15,
+	failure_retries = $16,
+	failure_retry_backoff = $17,
+	max_lifetime = $18,
+	archive_retention = $19
+WHERE
+	id = $1
+;
+
+-- name: UpdateTemplateSchedulePolicyByID :exec
+UPDATE
+	templates
+SET
+	updated_at = $2,
+	schedule_policy_template_id = $3
 WHERE
 	id = $1
 ;
@@ -0,0 +1,63 @@
+-- name: InsertTemplateVersionPromotion :one
+INSERT INTO
+	template_version_promotions (
+		id,
+		template_id,
+		template_version_id,
+		requested_by,
+		created_at,
+		updated_at,
+		status
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7) RETURNING *;
+
+-- name: GetTemplateVersionPromotionByID :one
+SELECT
+	*
+FROM
+	template_version_promotions
+WHERE
+	id = $1;
+
+-- name: GetTemplateVersionPromotionsByTemplateID :many
+SELECT
+	*
+FROM
+	template_version_promotions
+WHERE
+	template_id = @template_id
+	AND CASE
+		WHEN @status :: text != '' THEN
+			status = @status :: template_version_promotion_status
+		ELSE true
+	END
+ORDER BY
+	created_at DESC;
+
+-- name: ApproveTemplateVersionPromotion :one
+-- Adds an approval to a pending promotion. Returns no rows if the promotion
+-- isn't pending or the user already approved it.
+UPDATE
+	template_version_promotions
+SET
+	updated_at = @updated_at,
+	approved_by = array_append(approved_by, @user_id :: uuid)
+WHERE
+	id = @id
+	AND status = 'pending'
+	AND NOT (@user_id :: uuid = ANY(approved_by))
+RETURNING *;
+
+-- name: UpdateTemplateVersionPromotionStatusByID :one
+-- Resolves a pending promotion. Returns no rows if the promotion isn't
+-- pending.
+UPDATE
+	template_version_promotions
+SET
+	updated_at = $2,
+	status = $3
+WHERE
+	id = $1
+	AND status = 'pending'
+RETURNING *;
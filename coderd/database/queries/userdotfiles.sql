@@ -0,0 +1,31 @@
+-- name: GetUserDotfiles :one
+SELECT
+	*
+FROM
+	user_dotfiles
+WHERE
+	user_id = $1;
+
+-- name: UpsertUserDotfiles :one
+INSERT INTO
+	user_dotfiles (
+		user_id,
+		repo_url,
+		branch,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT
+	(user_id)
+DO UPDATE SET
+	repo_url = $2,
+	branch = $3,
+	updated_at = $4
+RETURNING *;
+
+-- name: DeleteUserDotfiles :exec
+DELETE FROM
+	user_dotfiles
+WHERE
+	user_id = $1;
@@ -0,0 +1,27 @@
+-- name: GetUserQuietHoursExceptions :many
+SELECT
+	*
+FROM
+	user_quiet_hours_exceptions
+WHERE
+	user_id = $1
+ORDER BY
+	date ASC;
+
+-- name: InsertUserQuietHoursException :one
+INSERT INTO
+	user_quiet_hours_exceptions (
+		user_id,
+		date,
+		created_at
+	)
+VALUES
+	($1, $2, $3)
+RETURNING *;
+
+-- name: DeleteUserQuietHoursException :exec
+DELETE FROM
+	user_quiet_hours_exceptions
+WHERE
+	user_id = $1
+	AND date = $2;
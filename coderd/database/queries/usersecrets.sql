@@ -0,0 +1,50 @@
+-- name: GetUserSecretsByUserID :many
+SELECT
+	*
+FROM
+	user_secrets
+WHERE
+	user_id = $1
+ORDER BY
+	name ASC;
+
+-- name: GetUserSecretByUserIDAndName :one
+SELECT
+	*
+FROM
+	user_secrets
+WHERE
+	user_id = $1
+	AND name = $2;
+
+-- name: InsertUserSecret :one
+INSERT INTO
+	user_secrets (
+		id,
+		user_id,
+		name,
+		description,
+		value,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7) RETURNING *;
+
+-- name: UpdateUserSecret :one
+UPDATE
+	user_secrets
+SET
+	description = $3,
+	value = $4,
+	updated_at = $5
+WHERE
+	user_id = $1
+	AND name = $2 RETURNING *;
+
+-- name: DeleteUserSecret :exec
+DELETE FROM
+	user_secrets
+WHERE
+	user_id = $1
+	AND name = $2;
@@ -140,6 +140,113 @@ FROM
 WHERE
 	workspace_agent_id = $1;
 
+-- Registers metadata defined at runtime by the metadata plugins of an agent.
+-- Metadata defined in the template is never overwritten.
+-- name: UpsertWorkspaceAgentPluginMetadata :exec
+INSERT INTO
+	workspace_agent_metadata (
+		workspace_agent_id,
+		display_name,
+		key,
+		script,
+		timeout,
+		interval,
+		plugin
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, true)
+ON CONFLICT (workspace_agent_id, key) DO UPDATE SET
+	display_name = EXCLUDED.display_name,
+	script = EXCLUDED.script,
+	timeout = EXCLUDED.timeout,
+	interval = EXCLUDED.interval
+WHERE
+	workspace_agent_metadata.plugin;
+
+-- name: DeleteWorkspaceAgentPluginMetadata :exec
+DELETE FROM
+	workspace_agent_metadata
+WHERE
+	workspace_agent_id = @workspace_agent_id
+	AND plugin
+	AND NOT (key = ANY(@keys :: text[]));
+
+-- name: InsertWorkspaceAgentScript :exec
+INSERT INTO
+	workspace_agent_scripts (
+		workspace_agent_id,
+		name,
+		ordinal,
+		script,
+		run_on_start,
+		run_on_stop,
+		timeout_seconds,
+		continue_on_error
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: UpdateWorkspaceAgentScriptStatus :exec
+UPDATE
+	workspace_agent_scripts
+SET
+	status = $3,
+	exit_code = $4,
+	started_at = $5,
+	ended_at = $6
+WHERE
+	workspace_agent_id = $1
+	AND name = $2;
+
+-- name: GetWorkspaceAgentScriptsByAgentID :many
+SELECT
+	*
+FROM
+	workspace_agent_scripts
+WHERE
+	workspace_agent_id = $1
+ORDER BY
+	ordinal ASC;
+
+-- name: InsertWorkspaceAgentSecret :exec
+INSERT INTO
+	workspace_agent_secrets (
+		workspace_agent_id,
+		name
+	)
+VALUES
+	($1, $2);
+
+-- name: GetWorkspaceAgentSecretsByAgentID :many
+SELECT
+	*
+FROM
+	workspace_agent_secrets
+WHERE
+	workspace_agent_id = $1
+ORDER BY
+	name ASC;
+
+-- name: InsertWorkspaceAgentDevcontainer :exec
+INSERT INTO
+	workspace_agent_devcontainers (
+		workspace_agent_id,
+		workspace_folder,
+		config_path
+	)
+VALUES
+	($1, $2, $3);
+
+-- name: GetWorkspaceAgentDevcontainersByAgentID :many
+SELECT
+	*
+FROM
+	workspace_agent_devcontainers
+WHERE
+	workspace_agent_id = $1
+ORDER BY
+	workspace_folder ASC;
+
 -- name: UpdateWorkspaceAgentLogOverflowByID :exec
 UPDATE
 	workspace_agents
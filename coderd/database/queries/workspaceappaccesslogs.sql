@@ -0,0 +1,28 @@
+-- name: DeleteOldWorkspaceAppAccessLogs :exec
+-- Access logs are kept as long as workspace agent stats.
+DELETE FROM workspace_app_access_logs WHERE created_at < NOW() - INTERVAL '30 days';
+
+-- name: InsertWorkspaceAppAccessLogs :exec
+INSERT INTO
+	workspace_app_access_logs (
+		created_at,
+		user_id,
+		workspace_id,
+		agent_id,
+		access_method,
+		slug_or_port,
+		path_prefix,
+		status_code,
+		latency_ms
+	)
+SELECT
+	unnest(@created_at::timestamptz[]) AS created_at,
+	-- Requests of users that were not signed in to Coder have a nil user ID.
+	NULLIF(unnest(@user_id::uuid[]), '00000000-0000-0000-0000-000000000000'::uuid) AS user_id,
+	unnest(@workspace_id::uuid[]) AS workspace_id,
+	unnest(@agent_id::uuid[]) AS agent_id,
+	unnest(@access_method::text[]) AS access_method,
+	unnest(@slug_or_port::text[]) AS slug_or_port,
+	unnest(@path_prefix::text[]) AS path_prefix,
+	unnest(@status_code::int[]) AS status_code,
+	unnest(@latency_ms::bigint[]) AS latency_ms;
@@ -9,7 +9,8 @@ INSERT INTO
 		session_id,
 		session_started_at,
 		session_ended_at,
-		requests
+		requests,
+		active_duration_ms
 	)
 SELECT
 	unnest(@user_id::uuid[]) AS user_id,
@@ -20,13 +21,15 @@ SELECT
 	unnest(@session_id::uuid[]) AS session_id,
 	unnest(@session_started_at::timestamptz[]) AS session_started_at,
 	unnest(@session_ended_at::timestamptz[]) AS session_ended_at,
-	unnest(@requests::int[]) AS requests
+	unnest(@requests::int[]) AS requests,
+	unnest(@active_duration_ms::bigint[]) AS active_duration_ms
 ON CONFLICT
 	(user_id, agent_id, session_id)
 DO
 	UPDATE SET
 		session_ended_at = EXCLUDED.session_ended_at,
-		requests = EXCLUDED.requests
+		requests = EXCLUDED.requests,
+		active_duration_ms = EXCLUDED.active_duration_ms
 	WHERE
 		workspace_app_stats.user_id = EXCLUDED.user_id
 		AND workspace_app_stats.agent_id = EXCLUDED.agent_id
@@ -34,4 +37,5 @@ DO
 		-- Since stats are updated in place as time progresses, we only
 		-- want to update this row if it's fresh.
 		AND workspace_app_stats.session_ended_at <= EXCLUDED.session_ended_at
-		AND workspace_app_stats.requests <= EXCLUDED.requests;
+		AND workspace_app_stats.requests <= EXCLUDED.requests
+		AND workspace_app_stats.active_duration_ms <= EXCLUDED.active_duration_ms;
@@ -0,0 +1,118 @@
+-- name: InsertWorkspaceBatch :one
+INSERT INTO
+	workspace_batches (
+		id,
+		initiator_id,
+		operation,
+		query,
+		created_at,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $5)
+RETURNING *;
+
+-- name: InsertWorkspaceBatchWorkspaces :exec
+-- Workspaces are processed in the order they are given.
+INSERT INTO
+	workspace_batch_workspaces (
+		batch_id,
+		workspace_id,
+		position,
+		updated_at
+	)
+SELECT
+	@batch_id :: uuid AS batch_id,
+	workspace_id,
+	position,
+	@updated_at :: timestamptz AS updated_at
+FROM
+	unnest(@workspace_ids :: uuid [ ]) WITH ORDINALITY AS ids(workspace_id, position);
+
+-- name: GetWorkspaceBatchByID :one
+SELECT
+	*
+FROM
+	workspace_batches
+WHERE
+	id = $1;
+
+-- name: GetWorkspaceBatchWorkspacesByBatchID :many
+SELECT
+	workspace_batch_workspaces.*,
+	workspaces.name AS workspace_name,
+	users.username AS workspace_owner_username
+FROM
+	workspace_batch_workspaces
+INNER JOIN
+	workspaces ON workspaces.id = workspace_batch_workspaces.workspace_id
+INNER JOIN
+	users ON users.id = workspaces.owner_id
+WHERE
+	workspace_batch_workspaces.batch_id = $1
+ORDER BY
+	workspace_batch_workspaces.position;
+
+-- name: AcquireWorkspaceBatch :one
+-- Acquires the oldest pending batch, or a running one that has not been
+-- updated since stale_before because the replica running it went away.
+-- SKIP LOCKED lets replicas acquire different batches concurrently.
+UPDATE
+	workspace_batches
+SET
+	status = 'running',
+	updated_at = @now
+WHERE
+	id = (
+		SELECT
+			id
+		FROM
+			workspace_batches AS nested
+		WHERE
+			nested.status = 'pending'
+			OR (nested.status = 'running' AND nested.updated_at < @stale_before)
+		ORDER BY
+			nested.created_at
+		FOR UPDATE
+		SKIP LOCKED
+		LIMIT
+			1
+	)
+RETURNING *;
+
+-- name: UpdateWorkspaceBatchWorkspace :exec
+UPDATE
+	workspace_batch_workspaces
+SET
+	status = $3,
+	build_id = $4,
+	error = $5,
+	updated_at = $6
+WHERE
+	batch_id = $1
+	AND workspace_id = $2;
+
+-- name: UpdateWorkspaceBatchUpdatedAtByID :exec
+UPDATE
+	workspace_batches
+SET
+	updated_at = $2
+WHERE
+	id = $1;
+
+-- name: UpdateWorkspaceBatchCompletedByID :exec
+UPDATE
+	workspace_batches
+SET
+	updated_at = $2,
+	completed_at = $2,
+	status = $3,
+	error = $4
+WHERE
+	id = $1;
+
+-- name: DeleteOldWorkspaceBatches :exec
+DELETE FROM
+	workspace_batches
+WHERE
+	completed_at < @completed_before;
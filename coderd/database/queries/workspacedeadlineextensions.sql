@@ -0,0 +1,24 @@
+-- name: InsertWorkspaceDeadlineExtension :one
+INSERT INTO
+	workspace_deadline_extensions (
+		id,
+		workspace_id,
+		workspace_build_id,
+		user_id,
+		created_at,
+		duration
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetWorkspaceDeadlineExtensionCount :one
+-- Returns the number of deadline extensions of the workspace since the given
+-- time.
+SELECT
+	COUNT(*)
+FROM
+	workspace_deadline_extensions
+WHERE
+	workspace_id = $1
+	AND created_at > $2;
@@ -0,0 +1,29 @@
+-- name: UpsertWorkspaceNameRedirect :one
+INSERT INTO
+	workspace_name_redirects (
+		owner_id,
+		name,
+		workspace_id,
+		created_at,
+		expires_at
+	)
+VALUES
+	($1, $2, $3, $4, $5)
+ON CONFLICT (owner_id, name) DO UPDATE SET
+	workspace_id = $3,
+	created_at = $4,
+	expires_at = $5
+RETURNING *;
+
+-- name: GetWorkspaceNameRedirect :one
+SELECT
+	*
+FROM
+	workspace_name_redirects
+WHERE
+	owner_id = $1
+	AND name = $2
+	AND expires_at > NOW();
+
+-- name: DeleteExpiredWorkspaceNameRedirects :exec
+DELETE FROM workspace_name_redirects WHERE expires_at <= NOW();
@@ -8,6 +8,14 @@ WHERE
 LIMIT
 	1;
 
+-- name: GetWorkspacesByIDs :many
+SELECT
+	*
+FROM
+	workspaces
+WHERE
+	id = ANY(@ids :: uuid [ ]);
+
 -- name: GetWorkspaceByWorkspaceAppID :one
 SELECT
 	*
@@ -90,6 +98,8 @@ LEFT JOIN LATERAL (
 	SELECT
 		workspace_builds.transition,
 		workspace_builds.template_version_id,
+		workspace_builds.deadline,
+		workspace_builds.max_deadline,
 		template_versions.name AS template_version_name,
 		provisioner_jobs.id AS provisioner_job_id,
 		provisioner_jobs.started_at,
@@ -116,7 +126,8 @@ LEFT JOIN LATERAL (
 ) latest_build ON TRUE
 LEFT JOIN LATERAL (
 	SELECT
-		templates.name AS template_name
+		templates.name AS template_name,
+		templates.active_version_id
 	FROM
 		templates
 	WHERE
@@ -148,13 +159,13 @@ WHERE
 					latest_build.canceled_at IS NULL AND
 					latest_build.completed_at IS NULL AND
 					latest_build.updated_at - INTERVAL '30 seconds' < NOW() AND
-					latest_build.transition = 'stop'::workspace_transition
+					latest_build.transition IN ('stop'::workspace_transition, 'archive'::workspace_transition)
 
 				WHEN @status = 'stopped' THEN
 					latest_build.completed_at IS NOT NULL AND
 					latest_build.canceled_at IS NULL AND
 					latest_build.error IS NULL AND
-					latest_build.transition = 'stop'::workspace_transition
+					latest_build.transition IN ('stop'::workspace_transition, 'archive'::workspace_transition)
 
 				WHEN @status = 'failed' THEN
 					(latest_build.canceled_at IS NOT NULL AND
@@ -267,6 +278,57 @@ WHERE
 		ELSE
 			locked_at IS NULL
 	END
+	-- Filter by last used
+	AND CASE
+		WHEN @last_used_before :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			last_used_at < @last_used_before
+		ELSE true
+	END
+	AND CASE
+		WHEN @last_used_after :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			last_used_at > @last_used_after
+		ELSE true
+	END
+	-- Filter by the reason workspaces were locked
+	AND CASE
+		WHEN @lock_reason :: text != '' THEN
+			workspaces.lock_reason :: text = @lock_reason
+		ELSE true
+	END
+	-- Filter by whether the latest build uses the active version of the
+	-- template
+	AND CASE
+		WHEN @outdated :: text = 'true' THEN
+			latest_build.template_version_id != template_name.active_version_id
+		WHEN @outdated = 'false' THEN
+			latest_build.template_version_id = template_name.active_version_id
+		ELSE true
+	END
+	-- Filter by the deadline of the latest build. Builds without a deadline
+	-- have a zero deadline, and never match.
+	AND CASE
+		WHEN @deadline_before :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			latest_build.deadline > '0001-01-01 00:00:00+00'::timestamptz AND
+			latest_build.deadline < @deadline_before
+		ELSE true
+	END
+	AND CASE
+		WHEN @deadline_after :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			latest_build.deadline > @deadline_after
+		ELSE true
+	END
+	-- Filter by the max deadline of the latest build
+	AND CASE
+		WHEN @max_deadline_before :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			latest_build.max_deadline > '0001-01-01 00:00:00+00'::timestamptz AND
+			latest_build.max_deadline < @max_deadline_before
+		ELSE true
+	END
+	AND CASE
+		WHEN @max_deadline_after :: timestamptz > '0001-01-01 00:00:00+00'::timestamptz THEN
+			latest_build.max_deadline > @max_deadline_after
+		ELSE true
+	END
 	-- Authorize Filter clause will be injected below in GetAuthorizedWorkspaces
 	-- @authorize_filter
 ORDER BY
@@ -355,6 +417,14 @@ SET
 WHERE
 	id = $1;
 
+-- name: UpdateWorkspaceGroupACLByID :exec
+UPDATE
+	workspaces
+SET
+	group_acl = $2
+WHERE
+	id = $1;
+
 -- name: GetDeploymentWorkspaceStats :one
 WITH workspaces_with_jobs AS (
 	SELECT
@@ -407,7 +477,7 @@ WITH workspaces_with_jobs AS (
 		completed_at IS NOT NULL AND
 		canceled_at IS NULL AND
 		error IS NULL AND
-		transition = 'stop'::workspace_transition
+		transition IN ('stop'::workspace_transition, 'archive'::workspace_transition)
 )
 SELECT
 	pending_workspaces.count AS pending_workspaces,
@@ -459,6 +529,21 @@ WHERE
 			workspaces.autostart_schedule IS NOT NULL
 		) OR
 
+		-- If the workspace was stopped by the restart requirement and the
+		-- template updates workspaces on restart, it may be eligible for a
+		-- start on the active version. Only successful stops of unlocked
+		-- workspaces qualify. The caller must check the license as we cannot
+		-- check it here.
+		(
+			workspace_builds.transition = 'stop'::workspace_transition AND
+			workspace_builds.reason = 'restart_requirement'::build_reason AND
+			templates.update_on_restart AND
+			workspaces.locked_at IS NULL AND
+			provisioner_jobs.completed_at IS NOT NULL AND
+			provisioner_jobs.canceled_at IS NULL AND
+			(provisioner_jobs.error IS NULL OR provisioner_jobs.error = '')
+		) OR
+
 		-- If the workspace's most recent job resulted in an error
 		-- it may be eligible for failed stop.
 		(
@@ -479,20 +564,68 @@ WHERE
 		(
 			templates.locked_ttl > 0 AND
 			workspaces.locked_at IS NOT NULL
+		) OR
+
+		-- If the workspace's template has a max_lifetime set it may be
+		-- eligible for deletion or locking, or its owner may have to be
+		-- warned that it expires soon.
+		(
+			templates.max_lifetime > 0 AND
+			workspaces.locked_at IS NULL
 		)
 	) AND workspaces.deleted = 'false';
 
+-- name: GetWorkspacesWithImminentAutostop :many
+-- Returns started workspaces whose latest build successfully completed and
+-- reaches its deadline after @now and no later than @before, with the latest
+-- build, owner and template details the autostop imminent events include.
+SELECT
+	sqlc.embed(workspaces),
+	workspace_builds.id AS build_id,
+	workspace_builds.deadline AS build_deadline,
+	users.username AS owner_username,
+	users.email AS owner_email,
+	templates.name AS template_name
+FROM
+	workspaces
+INNER JOIN
+	workspace_builds ON workspace_builds.workspace_id = workspaces.id
+INNER JOIN
+	provisioner_jobs ON workspace_builds.job_id = provisioner_jobs.id
+INNER JOIN
+	users ON users.id = workspaces.owner_id
+INNER JOIN
+	templates ON templates.id = workspaces.template_id
+WHERE
+	workspace_builds.build_number = (
+		SELECT
+			MAX(build_number)
+		FROM
+			workspace_builds
+		WHERE
+			workspace_builds.workspace_id = workspaces.id
+	) AND
+	workspace_builds.transition = 'start'::workspace_transition AND
+	workspace_builds.deadline > @now :: timestamptz AND
+	workspace_builds.deadline <= @before :: timestamptz AND
+	provisioner_jobs.completed_at IS NOT NULL AND
+	COALESCE(provisioner_jobs.error, '') = '' AND
+	workspaces.locked_at IS NULL AND
+	workspaces.deleted = 'false';
+
 -- name: UpdateWorkspaceLockedDeletingAt :one
 UPDATE
 	workspaces
 SET
 	locked_at = $2,
+	lock_reason = $3,
 	-- When a workspace is unlocked we want to update the last_used_at to avoid the workspace getting re-locked.
 	-- if we're locking the workspace then we leave it alone.
 	last_used_at = CASE WHEN $2::timestamptz IS NULL THEN now() at time zone 'utc' ELSE last_used_at END,
 	-- If locked_at is null (meaning unlocked) or the template-defined locked_ttl is 0 we should set
-	-- deleting_at to NULL else set it to the locked_at + locked_ttl duration.
-	deleting_at = CASE WHEN $2::timestamptz IS NULL OR templates.locked_ttl = 0 THEN NULL ELSE $2::timestamptz + INTERVAL '1 milliseconds' * templates.locked_ttl / 1000000 END
+	-- deleting_at to NULL else set it to the locked_at + locked_ttl duration. Templates that archive
+	-- workspaces delete them once the archive_retention has passed after that.
+	deleting_at = CASE WHEN $2::timestamptz IS NULL OR templates.locked_ttl = 0 THEN NULL ELSE $2::timestamptz + INTERVAL '1 milliseconds' * (templates.locked_ttl + templates.archive_retention) / 1000000 END
 FROM
 	templates
 WHERE
@@ -505,7 +638,7 @@ RETURNING workspaces.*;
 UPDATE
 	workspaces
 SET
-	deleting_at = CASE WHEN @locked_ttl_ms::bigint = 0 THEN NULL ELSE locked_at + interval '1 milliseconds' * @locked_ttl_ms::bigint END
+	deleting_at = CASE WHEN @locked_ttl_ms::bigint = 0 THEN NULL ELSE locked_at + interval '1 milliseconds' * (@locked_ttl_ms::bigint + @archive_retention_ms::bigint) END
 WHERE
 	template_id = @template_id
 AND
@@ -0,0 +1,36 @@
+-- name: GetWorkspaceScheduleOverrideByWorkspaceID :one
+SELECT
+	*
+FROM
+	workspace_schedule_overrides
+WHERE
+	workspace_id = $1;
+
+-- name: UpsertWorkspaceScheduleOverride :one
+INSERT INTO
+	workspace_schedule_overrides (
+		workspace_id,
+		created_at,
+		updated_at,
+		default_ttl,
+		max_ttl,
+		restart_requirement_days_of_week,
+		restart_requirement_weeks
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT
+	(workspace_id)
+DO UPDATE SET
+	updated_at = $3,
+	default_ttl = $4,
+	max_ttl = $5,
+	restart_requirement_days_of_week = $6,
+	restart_requirement_weeks = $7
+RETURNING *;
+
+-- name: DeleteWorkspaceScheduleOverrideByWorkspaceID :exec
+DELETE FROM
+	workspace_schedule_overrides
+WHERE
+	workspace_id = $1;
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/coderd/rbac"
@@ -58,6 +59,25 @@ func (t TemplateACL) Value() (driver.Value, error) {
 	return json.Marshal(t)
 }
 
+// WorkspaceACL is a map of group ids to the actions the members of the group
+// may perform on the workspace.
+type WorkspaceACL map[string][]rbac.Action
+
+func (t *WorkspaceACL) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), &t)
+	case []byte:
+		return json.Unmarshal(v, &t)
+	}
+
+	return xerrors.Errorf("unexpected type %T", src)
+}
+
+func (t WorkspaceACL) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
 type StringMap map[string]string
 
 func (m *StringMap) Scan(src interface{}) error {
@@ -80,6 +100,40 @@ func (m StringMap) Value() (driver.Value, error) {
 	return json.Marshal(m)
 }
 
+// ProvisionerType is the type of provisioner that runs a job. Besides the
+// built-in types, it's the name of a provisioner plugin served by external
+// provisioner daemons.
+type ProvisionerType string
+
+const (
+	ProvisionerTypeEcho      ProvisionerType = "echo"
+	ProvisionerTypeTerraform ProvisionerType = "terraform"
+)
+
+// ProvisionerTypes is stored as a text array.
+type ProvisionerTypes []ProvisionerType
+
+func (p *ProvisionerTypes) Scan(src interface{}) error {
+	var types pq.StringArray
+	err := types.Scan(src)
+	if err != nil {
+		return err
+	}
+	*p = make(ProvisionerTypes, 0, len(types))
+	for _, t := range types {
+		*p = append(*p, ProvisionerType(t))
+	}
+	return nil
+}
+
+func (p ProvisionerTypes) Value() (driver.Value, error) {
+	types := make(pq.StringArray, 0, len(p))
+	for _, t := range p {
+		types = append(types, string(t))
+	}
+	return types.Value()
+}
+
 // Now returns a standardized timezone used for database resources.
 func Now() time.Time {
 	return Time(time.Now().UTC())
@@ -12,6 +12,9 @@ import (
 )
 
 // @Summary Debug Info Wireguard Coordinator
+// @Description Serves a webpage with the agents, clients and nodes known
+// @Description to the coordinator, or the same state as JSON if the request
+// @Description accepts application/json.
 // @ID debug-info-wireguard-coordinator
 // @Security CoderSessionToken
 // @Produce text/html
@@ -34,11 +37,24 @@ func (api *API) debugDeploymentHealth(rw http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), api.HealthcheckTimeout)
 	defer cancel()
 
+	report, ok := api.deploymentHealthReport(ctx, apiKey)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "Healthcheck is in progress and did not complete in time. Try again in a few seconds.",
+		})
+		return
+	}
+	httpapi.WriteIndent(ctx, rw, http.StatusOK, report)
+}
+
+// deploymentHealthReport returns the cached deployment health report, or runs
+// the healthcheck if the cached report is outdated. It returns false if the
+// healthcheck did not complete before the context was canceled.
+func (api *API) deploymentHealthReport(ctx context.Context, apiKey string) (*healthcheck.Report, bool) {
 	// Get cached report if it exists.
 	if report := api.healthCheckCache.Load(); report != nil {
 		if time.Since(report.Time) < api.HealthcheckRefresh {
-			httpapi.WriteIndent(ctx, rw, http.StatusOK, report)
-			return
+			return report, true
 		}
 	}
 
@@ -54,13 +70,9 @@ func (api *API) debugDeploymentHealth(rw http.ResponseWriter, r *http.Request) {
 
 	select {
 	case <-ctx.Done():
-		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
-			Message: "Healthcheck is in progress and did not complete in time. Try again in a few seconds.",
-		})
-		return
+		return nil, false
 	case res := <-resChan:
-		httpapi.WriteIndent(ctx, rw, http.StatusOK, res.Val)
-		return
+		return res.Val, true
 	}
 }
 
@@ -12,6 +12,7 @@ import (
 
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/healthcheck"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/testutil"
 )
 
@@ -108,6 +109,53 @@ func TestDebugHealth(t *testing.T) {
 	})
 }
 
+func TestDebugCoordinator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("HTML", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		res, err := client.Request(ctx, "GET", "/api/v2/debug/coordinator", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		_, _ = io.ReadAll(res.Body)
+
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Contains(t, res.Header.Get("Content-Type"), "text/html")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		debug, err := client.DebugCoordinator(ctx)
+		require.NoError(t, err)
+		require.False(t, debug.HA)
+		require.Empty(t, debug.Agents)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		_, err := member.DebugCoordinator(ctx)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
 func TestDebugWebsocket(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,130 @@
+package coderd
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/coder/coder/coderd/healthcheck"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/tailnet"
+)
+
+// @Summary Get DERP health
+// @Description The health of the DERP regions is taken from the deployment
+// @Description healthcheck, which is cached for the healthcheck refresh
+// @Description interval. Server statistics are those of the DERP server
+// @Description embedded in the replica that serves the request.
+// @ID get-derp-health
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Debug
+// @Success 200 {object} codersdk.DERPHealth
+// @Router /derp/health [get]
+func (api *API) derpHealth(rw http.ResponseWriter, r *http.Request) {
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceDebugInfo) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	apiKey := httpmw.APITokenFromRequest(r)
+	ctx, cancel := context.WithTimeout(r.Context(), api.HealthcheckTimeout)
+	defer cancel()
+
+	report, ok := api.deploymentHealthReport(ctx, apiKey)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "Healthcheck is in progress and did not complete in time. Try again in a few seconds.",
+		})
+		return
+	}
+
+	health := convertDERPReport(report.DERP)
+	health.Time = report.Time
+	health.Server = convertDERPServerStats(tailnet.ReadDERPServerStats(api.DERPServer))
+	httpapi.Write(ctx, rw, http.StatusOK, health)
+}
+
+func convertDERPServerStats(stats tailnet.DERPServerStats) codersdk.DERPServerStats {
+	return codersdk.DERPServerStats{
+		CurrentConnections:     stats.CurrentConnections,
+		CurrentHomeConnections: stats.CurrentHomeConnections,
+		Accepts:                stats.Accepts,
+		BytesReceived:          stats.BytesReceived,
+		BytesSent:              stats.BytesSent,
+		PacketsReceived:        stats.PacketsReceived,
+		PacketsSent:            stats.PacketsSent,
+		PacketsDropped:         stats.PacketsDropped,
+	}
+}
+
+func convertDERPReport(report healthcheck.DERPReport) codersdk.DERPHealth {
+	health := codersdk.DERPHealth{
+		Healthy: report.Healthy,
+		Regions: make([]codersdk.DERPRegionHealth, 0, len(report.Regions)),
+	}
+	for _, regionReport := range report.Regions {
+		if regionReport == nil || regionReport.Region == nil {
+			continue
+		}
+		region := codersdk.DERPRegionHealth{
+			RegionID:   regionReport.Region.RegionID,
+			RegionCode: regionReport.Region.RegionCode,
+			RegionName: regionReport.Region.RegionName,
+			Healthy:    regionReport.Healthy,
+			Nodes:      make([]codersdk.DERPNodeHealth, 0, len(regionReport.NodeReports)),
+		}
+		if regionReport.Error != nil {
+			region.Error = *regionReport.Error
+		}
+		for _, nodeReport := range regionReport.NodeReports {
+			if nodeReport == nil || nodeReport.Node == nil {
+				continue
+			}
+			node := codersdk.DERPNodeHealth{
+				Name:                nodeReport.Node.Name,
+				HostName:            nodeReport.Node.HostName,
+				Healthy:             nodeReport.Healthy,
+				CanExchangeMessages: nodeReport.CanExchangeMessages,
+				UsesWebsocket:       nodeReport.UsesWebsocket,
+				CanSTUN:             nodeReport.STUN.CanSTUN,
+				Error:               derpNodeError(nodeReport),
+			}
+			if nodeReport.CanExchangeMessages {
+				node.LatencyMillis = int64(nodeReport.RoundTripPingMs)
+				if region.LatencyMillis == 0 || node.LatencyMillis < region.LatencyMillis {
+					region.LatencyMillis = node.LatencyMillis
+				}
+			}
+			region.Nodes = append(region.Nodes, node)
+		}
+		sort.Slice(region.Nodes, func(i, j int) bool {
+			return region.Nodes[i].Name < region.Nodes[j].Name
+		})
+		health.Regions = append(health.Regions, region)
+	}
+	sort.Slice(health.Regions, func(i, j int) bool {
+		return health.Regions[i].RegionID < health.Regions[j].RegionID
+	})
+	return health
+}
+
+// derpNodeError combines the errors of a DERP node report into a single
+// message.
+func derpNodeError(report *healthcheck.DERPNodeReport) string {
+	var errs []string
+	if report.Error != nil {
+		errs = append(errs, *report.Error)
+	}
+	for _, clientErrs := range report.ClientErrs {
+		errs = append(errs, clientErrs...)
+	}
+	if report.STUN.Error != nil {
+		errs = append(errs, "stun: "+*report.STUN.Error)
+	}
+	return strings.Join(errs, "; ")
+}
@@ -0,0 +1,140 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"tailscale.com/derp/derphttp"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/healthcheck"
+	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/tailnet"
+	"github.com/coder/coder/testutil"
+)
+
+func TestDERPHealth(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Now().UTC().Truncate(time.Second)
+		client := coderdtest.New(t, &coderdtest.Options{
+			HealthcheckFunc: func(context.Context, string) *healthcheck.Report {
+				return &healthcheck.Report{
+					Time: now,
+					DERP: healthcheck.DERPReport{
+						Healthy: false,
+						Regions: map[int]*healthcheck.DERPRegionReport{
+							2: {
+								Healthy: false,
+								Region:  &tailcfg.DERPRegion{RegionID: 2, RegionCode: "eu", RegionName: "Europe"},
+								NodeReports: []*healthcheck.DERPNodeReport{{
+									Node:       &tailcfg.DERPNode{Name: "2a", HostName: "eu.example.com"},
+									ClientErrs: [][]string{{"dial failed"}},
+								}},
+							},
+							1: {
+								Healthy: true,
+								Region:  &tailcfg.DERPRegion{RegionID: 1, RegionCode: "us", RegionName: "United States"},
+								NodeReports: []*healthcheck.DERPNodeReport{{
+									Healthy:             true,
+									Node:                &tailcfg.DERPNode{Name: "1b", HostName: "us-2.example.com"},
+									CanExchangeMessages: true,
+									RoundTripPingMs:     40,
+								}, {
+									Healthy:             true,
+									Node:                &tailcfg.DERPNode{Name: "1a", HostName: "us-1.example.com"},
+									CanExchangeMessages: true,
+									RoundTripPingMs:     25,
+									STUN: healthcheck.DERPStunReport{
+										Enabled: true,
+										Error:   ptr.Ref("timeout"),
+									},
+								}},
+							},
+						},
+					},
+				}
+			},
+		})
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		health, err := client.DERPHealth(ctx)
+		require.NoError(t, err)
+		require.False(t, health.Healthy)
+		require.True(t, now.Equal(health.Time))
+
+		require.Len(t, health.Regions, 2)
+		us := health.Regions[0]
+		require.Equal(t, "us", us.RegionCode)
+		require.True(t, us.Healthy)
+		require.EqualValues(t, 25, us.LatencyMillis)
+		require.Len(t, us.Nodes, 2)
+		require.Equal(t, "1a", us.Nodes[0].Name)
+		require.Equal(t, "stun: timeout", us.Nodes[0].Error)
+		require.Equal(t, "1b", us.Nodes[1].Name)
+		require.EqualValues(t, 40, us.Nodes[1].LatencyMillis)
+
+		eu := health.Regions[1]
+		require.Equal(t, "eu", eu.RegionCode)
+		require.False(t, eu.Healthy)
+		require.Zero(t, eu.LatencyMillis)
+		require.Len(t, eu.Nodes, 1)
+		require.Equal(t, "dial failed", eu.Nodes[0].Error)
+	})
+
+	t.Run("ServerStats", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{
+			HealthcheckFunc: func(context.Context, string) *healthcheck.Report {
+				return &healthcheck.Report{Time: time.Now()}
+			},
+		})
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		// Connect a client to the embedded DERP server.
+		ctx := testutil.Context(t, testutil.WaitLong)
+		derpURL := client.URL.JoinPath("/derp")
+		derpClient, err := derphttp.NewClient(key.NewNode(), derpURL.String(), tailnet.Logger(slogtest.Make(t, nil).Named("derp")))
+		require.NoError(t, err)
+		defer derpClient.Close()
+		require.NoError(t, derpClient.Connect(ctx))
+
+		require.Eventually(t, func() bool {
+			health, err := client.DERPHealth(ctx)
+			if err != nil {
+				return false
+			}
+			return health.Server.CurrentConnections > 0 && health.Server.Accepts > 0
+		}, testutil.WaitLong, testutil.IntervalFast)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{
+			HealthcheckFunc: func(context.Context, string) *healthcheck.Report {
+				return &healthcheck.Report{}
+			},
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err := member.DERPHealth(ctx)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
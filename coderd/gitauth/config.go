@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -231,6 +232,8 @@ func ConvertConfig(entries []codersdk.GitAuthConfig, accessURL *url.URL) ([]*Con
 			typ = codersdk.GitProviderAzureDevops
 		case codersdk.GitProviderBitBucket:
 			typ = codersdk.GitProviderBitBucket
+		case codersdk.GitProviderBitBucketServer:
+			typ = codersdk.GitProviderBitBucketServer
 		case codersdk.GitProviderGitHub:
 			typ = codersdk.GitProviderGitHub
 		case codersdk.GitProviderGitLab:
@@ -262,6 +265,32 @@ func ConvertConfig(entries []codersdk.GitAuthConfig, accessURL *url.URL) ([]*Con
 		if err != nil {
 			return nil, xerrors.Errorf("parse gitauth callback url: %w", err)
 		}
+		if paths, ok := selfHostedPaths[typ]; ok && entry.AuthURL != "" {
+			authURL, err := url.Parse(entry.AuthURL)
+			if err != nil {
+				return nil, xerrors.Errorf("parse auth url for git auth provider %q: %w", entry.ID, err)
+			}
+			// The provider may be served from a subpath, e.g.
+			// "https://example.com/gitlab/oauth/authorize".
+			base := *authURL
+			base.Path = strings.TrimSuffix(authURL.Path, paths.Auth)
+			base.RawQuery = ""
+			if entry.TokenURL == "" {
+				entry.TokenURL = base.JoinPath(paths.Token).String()
+			}
+			if entry.ValidateURL == "" {
+				entry.ValidateURL = base.JoinPath(paths.Validate).String()
+			}
+			if entry.DeviceCodeURL == "" && paths.DeviceAuth != "" {
+				entry.DeviceCodeURL = base.JoinPath(paths.DeviceAuth).String()
+			}
+			if entry.Regex == "" {
+				entry.Regex = fmt.Sprintf(`^(https?://)?%s(/.*)?$`, regexp.QuoteMeta(authURL.Host))
+			}
+		}
+		if _, ok := endpoint[typ]; !ok && (entry.AuthURL == "" || entry.TokenURL == "") {
+			return nil, xerrors.Errorf("%q git auth provider: auth_url must be provided for self-hosted providers", entry.ID)
+		}
 		regex := regex[typ]
 		if entry.Regex != "" {
 			regex, err = regexp.Compile(entry.Regex)
@@ -321,7 +350,7 @@ func ConvertConfig(entries []codersdk.GitAuthConfig, accessURL *url.URL) ([]*Con
 			cfg.DeviceAuth = &DeviceAuth{
 				ClientID: entry.ClientID,
 				TokenURL: oc.Endpoint.TokenURL,
-				Scopes:   entry.Scopes,
+				Scopes:   oc.Scopes,
 				CodeURL:  entry.DeviceCodeURL,
 			}
 		}
@@ -198,12 +198,20 @@ func TestConvertYAML(t *testing.T) {
 	}, {
 		Name: "NoDeviceURL",
 		Input: []codersdk.GitAuthConfig{{
-			Type:         string(codersdk.GitProviderGitLab),
+			Type:         string(codersdk.GitProviderBitBucket),
 			ClientID:     "example",
 			ClientSecret: "example",
 			DeviceFlow:   true,
 		}},
 		Error: "device auth url must be provided",
+	}, {
+		Name: "NoAuthURL",
+		Input: []codersdk.GitAuthConfig{{
+			Type:         string(codersdk.GitProviderBitBucketServer),
+			ClientID:     "example",
+			ClientSecret: "example",
+		}},
+		Error: "auth_url must be provided",
 	}} {
 		tc := tc
 		t.Run(tc.Name, func(t *testing.T) {
@@ -231,4 +239,39 @@ func TestConvertYAML(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, "https://auth.com?client_id=id&redirect_uri=%2Fgitauth%2Fgitlab%2Fcallback&response_type=code&scope=read", config[0].AuthCodeURL(""))
 	})
+	t.Run("GitLabSelfHosted", func(t *testing.T) {
+		t.Parallel()
+		config, err := gitauth.ConvertConfig([]codersdk.GitAuthConfig{{
+			Type:         string(codersdk.GitProviderGitLab),
+			ClientID:     "id",
+			ClientSecret: "secret",
+			AuthURL:      "https://example.com/gitlab/oauth/authorize",
+			Scopes:       []string{"read_repository"},
+			DeviceFlow:   true,
+		}}, &url.URL{})
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/gitlab/oauth/token/info", config[0].ValidateURL)
+		require.Equal(t, &gitauth.DeviceAuth{
+			ClientID: "id",
+			TokenURL: "https://example.com/gitlab/oauth/token",
+			Scopes:   []string{"read_repository"},
+			CodeURL:  "https://example.com/gitlab/oauth/authorize_device",
+		}, config[0].DeviceAuth)
+		require.True(t, config[0].Regex.MatchString("https://example.com/gitlab/coder/coder.git"))
+		require.False(t, config[0].Regex.MatchString("https://gitlab.com/coder/coder.git"))
+	})
+
+	t.Run("BitbucketServer", func(t *testing.T) {
+		t.Parallel()
+		config, err := gitauth.ConvertConfig([]codersdk.GitAuthConfig{{
+			Type:         string(codersdk.GitProviderBitBucketServer),
+			ClientID:     "id",
+			ClientSecret: "secret",
+			AuthURL:      "https://bitbucket.example.com/rest/oauth2/latest/authorize",
+		}}, &url.URL{})
+		require.NoError(t, err)
+		require.Equal(t, "https://bitbucket.example.com/rest/api/latest/inbox/pull-requests/count", config[0].ValidateURL)
+		require.Equal(t, "https://bitbucket.example.com/rest/oauth2/latest/authorize?client_id=id&redirect_uri=%2Fgitauth%2Fbitbucket-server%2Fcallback&response_type=code&scope=REPO_WRITE", config[0].AuthCodeURL(""))
+		require.True(t, config[0].Regex.MatchString("https://bitbucket.example.com/scm/coder/coder.git"))
+	})
 }
@@ -0,0 +1,66 @@
+package gitauth
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// ParseCredentialHelper returns the URL described by the attributes git
+// passes to a credential helper, for example "https://github.com" or
+// "https://github.com/coder/coder" if credential.useHttpPath is set. An empty
+// URL is returned for protocols other than HTTP, which the helper can't
+// provide credentials for.
+//
+// For details on the format, see:
+// https://git-scm.com/docs/git-credential#IOFMT
+func ParseCredentialHelper(r io.Reader) (string, error) {
+	attrs := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return "", xerrors.Errorf("invalid credential attribute %q", line)
+		}
+		attrs[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return "", xerrors.Errorf("read credential attributes: %w", err)
+	}
+
+	u := &url.URL{
+		Scheme: attrs["protocol"],
+		Host:   attrs["host"],
+		Path:   attrs["path"],
+	}
+	if rawURL, ok := attrs["url"]; ok {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return "", xerrors.Errorf("parse url: %w", err)
+		}
+		u = &url.URL{
+			Scheme: parsed.Scheme,
+			Host:   parsed.Host,
+			Path:   parsed.Path,
+		}
+	}
+	switch u.Scheme {
+	case "http", "https":
+	default:
+		return "", nil
+	}
+	if u.Host == "" {
+		return "", xerrors.New("host is empty")
+	}
+	if u.Path != "" && !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+	}
+	return u.String(), nil
+}
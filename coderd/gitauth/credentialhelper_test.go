@@ -0,0 +1,56 @@
+package gitauth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/gitauth"
+)
+
+func TestParseCredentialHelper(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name    string
+		in      string
+		wantURL string
+	}{
+		{
+			name:    "Host",
+			in:      "protocol=https\nhost=github.com\n\n",
+			wantURL: "https://github.com",
+		},
+		{
+			name:    "Path",
+			in:      "protocol=https\nhost=github.com\npath=coder/coder.git\nusername=user\n",
+			wantURL: "https://github.com/coder/coder.git",
+		},
+		{
+			name:    "URL",
+			in:      "url=http://user@gitlab.example.com:8080/group/project\n",
+			wantURL: "http://gitlab.example.com:8080/group/project",
+		},
+		{
+			name:    "SSH",
+			in:      "protocol=ssh\nhost=github.com\n",
+			wantURL: "",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			gitURL, err := gitauth.ParseCredentialHelper(strings.NewReader(tc.in))
+			require.NoError(t, err)
+			require.Equal(t, tc.wantURL, gitURL)
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := gitauth.ParseCredentialHelper(strings.NewReader("protocol=https\nhost\n"))
+		require.Error(t, err)
+		_, err = gitauth.ParseCredentialHelper(strings.NewReader("protocol=https\n"))
+		require.Error(t, err)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -42,6 +43,31 @@ var validateURL = map[codersdk.GitProvider]string{
 
 var deviceAuthURL = map[codersdk.GitProvider]string{
 	codersdk.GitProviderGitHub: "https://github.com/login/device/code",
+	codersdk.GitProviderGitLab: "https://gitlab.com/oauth/authorize_device",
+}
+
+// selfHostedPaths contains the paths of each endpoint for Git providers
+// that can be self-hosted. The other endpoints default to the same host
+// as the authorization URL, so self-hosted providers only need one URL.
+var selfHostedPaths = map[codersdk.GitProvider]struct {
+	Auth       string
+	Token      string
+	Validate   string
+	DeviceAuth string
+}{
+	codersdk.GitProviderGitLab: {
+		Auth:       "/oauth/authorize",
+		Token:      "/oauth/token",
+		Validate:   "/oauth/token/info",
+		DeviceAuth: "/oauth/authorize_device",
+	},
+	// Bitbucket Data Center doesn't support the device flow.
+	// See: https://confluence.atlassian.com/bitbucketserver/bitbucket-oauth-2-0-provider-api-1108483661.html
+	codersdk.GitProviderBitBucketServer: {
+		Auth:     "/rest/oauth2/latest/authorize",
+		Token:    "/rest/oauth2/latest/token",
+		Validate: "/rest/api/latest/inbox/pull-requests/count",
+	},
 }
 
 var appInstallationsURL = map[codersdk.GitProvider]string{
@@ -52,7 +78,10 @@ var appInstallationsURL = map[codersdk.GitProvider]string{
 var scope = map[codersdk.GitProvider][]string{
 	codersdk.GitProviderAzureDevops: {"vso.code_write"},
 	codersdk.GitProviderBitBucket:   {"account", "repository:write"},
-	codersdk.GitProviderGitLab:      {"write_repository"},
+	// Bitbucket Data Center scopes are inclusive, so "REPO_WRITE" grants
+	// "REPO_READ" and "PUBLIC_REPOS" too.
+	codersdk.GitProviderBitBucketServer: {"REPO_WRITE"},
+	codersdk.GitProviderGitLab:          {"write_repository"},
 	// "workflow" is required for managing GitHub Actions in a repository.
 	codersdk.GitProviderGitHub: {"repo", "workflow"},
 }
@@ -111,31 +140,32 @@ func (c *DeviceAuth) AuthorizeDevice(ctx context.Context) (*codersdk.GitAuthDevi
 	if c.CodeURL == "" {
 		return nil, xerrors.New("oauth2: device code URL not set")
 	}
-	codeURL, err := c.formatDeviceCodeURL()
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, codeURL, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Accept", "application/json")
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.postForm(ctx, c.CodeURL, url.Values{
+		"client_id": {c.ClientID},
+		"scope":     {strings.Join(c.Scopes, " ")},
+	})
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	var r struct {
 		codersdk.GitAuthDevice
+		Error            string `json:"error"`
 		ErrorDescription string `json:"error_description"`
 	}
 	err = json.NewDecoder(resp.Body).Decode(&r)
-	if err != nil {
-		return nil, err
-	}
 	if r.ErrorDescription != "" {
 		return nil, xerrors.New(r.ErrorDescription)
 	}
+	if r.Error != "" {
+		return nil, xerrors.New(r.Error)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("oauth2: device code request failed with status %d", resp.StatusCode)
+	}
+	if err != nil {
+		return nil, err
+	}
 	return &r.GitAuthDevice, nil
 }
 
@@ -148,65 +178,55 @@ type ExchangeDeviceCodeResponse struct {
 }
 
 // ExchangeDeviceCode exchanges a device code for an access token.
-// The boolean returned indicates whether the device code is still pending
-// and the caller should try again.
+// While the user hasn't authorized the device yet, the error is the
+// "authorization_pending" error code and the caller should try again.
+// See: https://tools.ietf.org/html/rfc8628#section-3.5
 func (c *DeviceAuth) ExchangeDeviceCode(ctx context.Context, deviceCode string) (*oauth2.Token, error) {
 	if c.TokenURL == "" {
 		return nil, xerrors.New("oauth2: token URL not set")
 	}
-	tokenURL, err := c.formatDeviceTokenURL(deviceCode)
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Accept", "application/json")
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.postForm(ctx, c.TokenURL, url.Values{
+		"client_id":   {c.ClientID},
+		"device_code": {deviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	})
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		return nil, codersdk.ReadBodyAsError(resp)
-	}
 	var body ExchangeDeviceCodeResponse
 	err = json.NewDecoder(resp.Body).Decode(&body)
-	if err != nil {
-		return nil, err
-	}
+	// The RFC returns error codes with a 400 status, but GitHub
+	// returns them with a 200 status.
 	if body.Error != "" {
 		return nil, xerrors.New(body.Error)
 	}
-	return &oauth2.Token{
+	if resp.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("oauth2: device token request failed with status %d", resp.StatusCode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := &oauth2.Token{
 		AccessToken:  body.AccessToken,
 		RefreshToken: body.RefreshToken,
-		Expiry:       database.Now().Add(time.Duration(body.ExpiresIn) * time.Second),
-	}, nil
-}
-
-func (c *DeviceAuth) formatDeviceTokenURL(deviceCode string) (string, error) {
-	tok, err := url.Parse(c.TokenURL)
-	if err != nil {
-		return "", err
 	}
-	tok.RawQuery = url.Values{
-		"client_id":   {c.ClientID},
-		"device_code": {deviceCode},
-		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
-	}.Encode()
-	return tok.String(), nil
+	// Providers omit the expiry for tokens that are valid until revoked.
+	if body.ExpiresIn > 0 {
+		token.Expiry = database.Now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	return token, nil
 }
 
-func (c *DeviceAuth) formatDeviceCodeURL() (string, error) {
-	cod, err := url.Parse(c.CodeURL)
+// postForm sends the parameters as a form-encoded body, which is
+// required by the RFC. GitHub accepts them in the query too, but
+// GitLab does not.
+func (*DeviceAuth) postForm(ctx context.Context, rawURL string, params url.Values) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, strings.NewReader(params.Encode()))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	cod.RawQuery = url.Values{
-		"client_id": {c.ClientID},
-		"scope":     c.Scopes,
-	}.Encode()
-	return cod.String(), nil
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	return http.DefaultClient.Do(req)
 }
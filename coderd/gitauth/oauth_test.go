@@ -1,9 +1,96 @@
 package gitauth_test
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/gitauth"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
 )
 
 func TestOAuthJWTConfig(t *testing.T) {
 	t.Parallel()
 }
+
+func TestDeviceAuth(t *testing.T) {
+	t.Parallel()
+	t.Run("AuthorizeDevice", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// GitLab requires the parameters in a form-encoded body.
+			require.NoError(t, r.ParseForm())
+			require.Empty(t, r.URL.RawQuery)
+			require.Equal(t, "test", r.PostForm.Get("client_id"))
+			require.Equal(t, "read_repository write_repository", r.PostForm.Get("scope"))
+			httpapi.Write(r.Context(), w, http.StatusOK, codersdk.GitAuthDevice{
+				DeviceCode: "device",
+				UserCode:   "user",
+			})
+		}))
+		defer srv.Close()
+		device := &gitauth.DeviceAuth{
+			ClientID: "test",
+			CodeURL:  srv.URL,
+			Scopes:   []string{"read_repository", "write_repository"},
+		}
+		ctx := testutil.Context(t, testutil.WaitShort)
+		code, err := device.AuthorizeDevice(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "user", code.UserCode)
+	})
+	t.Run("ExchangeDeviceCode", func(t *testing.T) {
+		t.Parallel()
+		var pending atomic.Bool
+		pending.Store(true)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "device", r.PostForm.Get("device_code"))
+			require.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", r.PostForm.Get("grant_type"))
+			if pending.Load() {
+				// Unlike GitHub, RFC 8628 providers respond with a 400.
+				httpapi.Write(r.Context(), w, http.StatusBadRequest, gitauth.ExchangeDeviceCodeResponse{
+					Error: "authorization_pending",
+				})
+				return
+			}
+			httpapi.Write(r.Context(), w, http.StatusOK, gitauth.ExchangeDeviceCodeResponse{
+				AccessToken: "token",
+			})
+		}))
+		defer srv.Close()
+		device := &gitauth.DeviceAuth{
+			ClientID: "test",
+			TokenURL: srv.URL,
+		}
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err := device.ExchangeDeviceCode(ctx, "device")
+		require.EqualError(t, err, "authorization_pending")
+
+		pending.Store(false)
+		token, err := device.ExchangeDeviceCode(ctx, "device")
+		require.NoError(t, err)
+		require.Equal(t, "token", token.AccessToken)
+		// The token doesn't expire, so it must not be refreshed.
+		require.True(t, token.Expiry.IsZero())
+	})
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+		device := &gitauth.DeviceAuth{
+			ClientID: "test",
+			TokenURL: srv.URL,
+		}
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err := device.ExchangeDeviceCode(ctx, "device")
+		require.ErrorContains(t, err, "500")
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/netip"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -177,9 +178,17 @@ func (r *DERPNodeReport) derpURL() *url.URL {
 	}
 	if r.Node.HostName == "" {
 		derpURL.Host = r.Node.IPv4
+		if ip, err := netip.ParseAddr(r.Node.IPv4); err != nil || !ip.Is4() {
+			derpURL.Host = r.Node.IPv6
+		}
+	}
+	// IPv6 literals must be bracketed in URLs.
+	host := strings.Trim(derpURL.Host, "[]")
+	if ip, err := netip.ParseAddr(host); err == nil && ip.Is6() {
+		derpURL.Host = "[" + host + "]"
 	}
 	if r.Node.DERPPort != 0 && !(r.Node.DERPPort == 443 && derpURL.Scheme == "https") && !(r.Node.DERPPort == 80 && derpURL.Scheme == "http") {
-		derpURL.Host = fmt.Sprintf("%s:%d", derpURL.Host, r.Node.DERPPort)
+		derpURL.Host = net.JoinHostPort(host, strconv.Itoa(r.Node.DERPPort))
 	}
 
 	return derpURL
@@ -307,7 +316,7 @@ func (r *DERPNodeReport) doSTUNTest(ctx context.Context) {
 	r.STUN.Enabled = true
 	r.mu.Unlock()
 
-	addr, port, err := r.stunAddr(ctx)
+	addrs, port, err := r.stunAddrs(ctx)
 	if err != nil {
 		r.STUN.Error = convertError(xerrors.Errorf("get stun addr: %w", err))
 		return
@@ -320,7 +329,14 @@ func (r *DERPNodeReport) doSTUNTest(ctx context.Context) {
 		return
 	}
 
-	err = p.ProbeUDP(addr, port)(ctx)
+	// Hosts with a single IP family can't reach the addresses of the other
+	// family, so every address of the node is tried until one responds.
+	for _, addr := range addrs {
+		err = p.ProbeUDP(addr, port)(ctx)
+		if err == nil {
+			break
+		}
+	}
 	if err != nil {
 		r.STUN.Error = convertError(xerrors.Errorf("probe stun: %w", err))
 		return
@@ -331,60 +347,72 @@ func (r *DERPNodeReport) doSTUNTest(ctx context.Context) {
 	r.mu.Unlock()
 }
 
-func (r *DERPNodeReport) stunAddr(ctx context.Context) (string, int, error) {
+// stunAddrs returns the addresses and port to probe the STUN server of the
+// node on. All addresses the host name resolves to are returned, so nodes can
+// be probed on hosts with only one IP family.
+func (r *DERPNodeReport) stunAddrs(ctx context.Context) ([]string, int, error) {
 	port := r.Node.STUNPort
 	if port == 0 {
 		port = 3478
 	}
 	if port < 0 || port > 1<<16-1 {
-		return "", 0, xerrors.Errorf("invalid stun port %d", port)
+		return nil, 0, xerrors.Errorf("invalid stun port %d", port)
 	}
 
 	if r.Node.STUNTestIP != "" {
 		ip, err := netip.ParseAddr(r.Node.STUNTestIP)
 		if err != nil {
-			return "", 0, xerrors.Errorf("invalid stun test ip %q: %w", r.Node.STUNTestIP, err)
+			return nil, 0, xerrors.Errorf("invalid stun test ip %q: %w", r.Node.STUNTestIP, err)
 		}
 
-		return ip.String(), port, nil
+		return []string{ip.String()}, port, nil
 	}
 
 	if r.Node.HostName != "" {
-		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, r.Node.HostName)
+		addrs, err := net.DefaultResolver.LookupIPAddr(ctx, strings.Trim(r.Node.HostName, "[]"))
 		if err != nil {
-			return "", 0, xerrors.Errorf("lookup ip addr: %w", err)
+			return nil, 0, xerrors.Errorf("lookup ip addr: %w", err)
 		}
+		ips := make([]string, 0, len(addrs))
 		for _, a := range addrs {
-			return a.String(), port, nil
+			ips = append(ips, a.IP.String())
+		}
+		if len(ips) > 0 {
+			return ips, port, nil
 		}
 	}
 
-	if r.Node.IPv4 != "" {
+	// "none" disables an IP family of the node.
+	var ips []string
+	if r.Node.IPv4 != "" && r.Node.IPv4 != "none" {
 		ip, err := netip.ParseAddr(r.Node.IPv4)
 		if err != nil {
-			return "", 0, xerrors.Errorf("invalid ipv4 %q: %w", r.Node.IPv4, err)
+			return nil, 0, xerrors.Errorf("invalid ipv4 %q: %w", r.Node.IPv4, err)
 		}
 
 		if !ip.Is4() {
-			return "", 0, xerrors.Errorf("provided node ipv4 is not v4 %q: %w", r.Node.IPv4, err)
+			return nil, 0, xerrors.Errorf("provided node ipv4 is not v4 %q: %w", r.Node.IPv4, err)
 		}
 
-		return ip.String(), port, nil
+		ips = append(ips, ip.String())
 	}
-	if r.Node.IPv6 != "" {
+	if r.Node.IPv6 != "" && r.Node.IPv6 != "none" {
 		ip, err := netip.ParseAddr(r.Node.IPv6)
 		if err != nil {
-			return "", 0, xerrors.Errorf("invalid ipv6 %q: %w", r.Node.IPv6, err)
+			return nil, 0, xerrors.Errorf("invalid ipv6 %q: %w", r.Node.IPv6, err)
 		}
 
 		if !ip.Is6() {
-			return "", 0, xerrors.Errorf("provided node ipv6 is not v6 %q: %w", r.Node.IPv6, err)
+			return nil, 0, xerrors.Errorf("provided node ipv6 is not v6 %q: %w", r.Node.IPv6, err)
 		}
 
-		return ip.String(), port, nil
+		ips = append(ips, ip.String())
+	}
+	if len(ips) == 0 {
+		return nil, 0, xerrors.New("no stun ips provided")
 	}
 
-	return "", 0, xerrors.New("no stun ips provided")
+	return ips, port, nil
 }
 
 func (r *DERPNodeReport) writeClientErr(clientID int, err error) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -19,6 +20,7 @@ import (
 
 	"github.com/coder/coder/coderd/healthcheck"
 	"github.com/coder/coder/tailnet"
+	"github.com/coder/coder/tailnet/tailnettest"
 	"github.com/coder/coder/testutil"
 )
 
@@ -231,6 +233,62 @@ func TestDERP(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("IPv6/OK", func(t *testing.T) {
+		t.Parallel()
+
+		derpSrv := derp.NewServer(key.NewNode(), func(format string, args ...any) { t.Logf(format, args...) })
+		defer derpSrv.Close()
+		srv := httptest.NewUnstartedServer(derphttp.Handler(derpSrv))
+		_ = srv.Listener.Close()
+		srv.Listener = tailnettest.ListenIPv6(t)
+		srv.Start()
+		defer srv.Close()
+		stunAddr := tailnettest.RunSTUNIPv6(t)
+
+		tcpAddr, ok := srv.Listener.Addr().(*net.TCPAddr)
+		require.True(t, ok)
+
+		var (
+			ctx    = context.Background()
+			report = healthcheck.DERPReport{}
+			opts   = &healthcheck.DERPReportOptions{
+				DERPMap: &tailcfg.DERPMap{Regions: map[int]*tailcfg.DERPRegion{
+					1: {
+						EmbeddedRelay: true,
+						RegionID:      999,
+						Nodes: []*tailcfg.DERPNode{{
+							Name:             "1a",
+							RegionID:         999,
+							IPv4:             "none",
+							IPv6:             "::1",
+							DERPPort:         tcpAddr.Port,
+							STUNPort:         stunAddr.Port,
+							InsecureForTests: true,
+							ForceHTTP:        true,
+						}},
+					},
+				}},
+			}
+		)
+
+		report.Run(ctx, opts)
+
+		assert.True(t, report.Healthy)
+		for _, region := range report.Regions {
+			assert.True(t, region.Healthy)
+			for _, node := range region.NodeReports {
+				assert.True(t, node.Healthy)
+				assert.True(t, node.CanExchangeMessages)
+				assert.Len(t, node.ClientErrs[0], 0)
+				assert.Len(t, node.ClientErrs[1], 0)
+
+				assert.True(t, node.STUN.Enabled)
+				assert.True(t, node.STUN.CanSTUN)
+				assert.Nil(t, node.STUN.Error)
+			}
+		}
+	})
 }
 
 func tsDERPMap(ctx context.Context, t testing.TB) *tailcfg.DERPMap {
@@ -24,7 +24,9 @@ func StripCoderCookies(header string) string {
 			name == codersdk.OAuth2StateCookie ||
 			name == codersdk.OAuth2RedirectCookie ||
 			name == codersdk.DevURLSessionTokenCookie ||
-			name == codersdk.DevURLSignedAppTokenCookie {
+			name == codersdk.DevURLSignedAppTokenCookie ||
+			name == codersdk.DevURLOIDCSessionCookie ||
+			name == codersdk.DevURLReplicaCookie {
 			continue
 		}
 		cookies = append(cookies, part)
@@ -25,6 +25,9 @@ func TestStripCoderCookies(t *testing.T) {
 	}, {
 		"coder_session_token=ok; oauth_state=wow; oauth_redirect=/",
 		"",
+	}, {
+		"coder_devurl_replica=abc; coder_devurl_oidc_session=def; app=1",
+		"app=1",
 	}} {
 		tc := tc
 		t.Run(tc.Input, func(t *testing.T) {
@@ -81,6 +81,16 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	provisionerTypeValidator := func(fl validator.FieldLevel) bool {
+		str := fl.Field().String()
+		valid := ProvisionerTypeValid(str)
+		return valid == nil
+	}
+	err = Validate.RegisterValidation("provisioner_type", provisionerTypeValidator)
+	if err != nil {
+		panic(err)
+	}
 }
 
 // Is404Error returns true if the given error should return a 404 status code.
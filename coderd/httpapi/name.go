@@ -14,6 +14,7 @@ var (
 
 	templateVersionName = regexp.MustCompile(`^[a-zA-Z0-9]+(?:[_.-]{1}[a-zA-Z0-9]+)*$`)
 	templateDisplayName = regexp.MustCompile(`^[^\s](.*[^\s])?$`)
+	provisionerType     = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
 )
 
 // UsernameFrom returns a best-effort username from the provided string.
@@ -79,3 +80,17 @@ func TemplateDisplayNameValid(str string) error {
 	}
 	return nil
 }
+
+// ProvisionerTypeValid returns whether the input string is a valid provisioner
+// type. Besides the built-in types, provisioner daemons serve plugins by any
+// valid name.
+func ProvisionerTypeValid(str string) error {
+	if len(str) > 32 {
+		return xerrors.New("must be <= 32 characters")
+	}
+	matched := provisionerType.MatchString(str)
+	if !matched {
+		return xerrors.New("must be lowercase alphanumeric with hyphens")
+	}
+	return nil
+}
@@ -162,6 +162,37 @@ func TestTemplateVersionNameValid(t *testing.T) {
 	}
 }
 
+func TestProvisionerTypeValid(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name  string
+		Valid bool
+	}{
+		{"terraform", true},
+		{"echo", true},
+		{"pulumi", true},
+		{"kubernetes-manifests", true},
+		{"shell2", true},
+
+		{"", false},
+		{"Pulumi", false},
+		{"-shell", false},
+		{"shell-", false},
+		{"kubernetes--manifests", false},
+		{"kubernetes_manifests", false},
+		{"a-provisioner-type-longer-than-32", false},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.Name, func(t *testing.T) {
+			t.Parallel()
+			valid := httpapi.ProvisionerTypeValid(testCase.Name)
+			require.Equal(t, testCase.Valid, valid == nil)
+		})
+	}
+}
+
 func TestGeneratedTemplateVersionNameValid(t *testing.T) {
 	t.Parallel()
 
@@ -130,6 +130,16 @@ func ExtractAPIKeyMW(cfg ExtractAPIKeyConfig) func(http.Handler) http.Handler {
 			}
 			key, authz := *keyPtr, *authzPtr
 
+			// Handlers expect most authorization errors to be impossible for
+			// the roles of the user, so reject requests that a read-only
+			// scope never allows before they get there.
+			if key.Scope.ReadOnly() && !isReadOnlyMethod(r.Method) {
+				httpapi.Write(r.Context(), rw, http.StatusForbidden, codersdk.Response{
+					Message: fmt.Sprintf("API key scope %q does not allow %s requests.", key.Scope, r.Method),
+				})
+				return
+			}
+
 			// Actor is the user's authorization context.
 			ctx := r.Context()
 			ctx = context.WithValue(ctx, apiKeyContextKey{}, key)
@@ -142,6 +152,15 @@ func ExtractAPIKeyMW(cfg ExtractAPIKeyConfig) func(http.Handler) http.Handler {
 	}
 }
 
+func isReadOnlyMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
 func APIKeyFromRequest(ctx context.Context, db database.Store, sessionTokenFunc func(r *http.Request) string, r *http.Request) (*database.APIKey, codersdk.Response, bool) {
 	tokenFunc := APITokenFromRequest
 	if sessionTokenFunc != nil {
@@ -271,6 +271,43 @@ func TestAPIKey(t *testing.T) {
 		require.Equal(t, http.StatusOK, res.StatusCode)
 	})
 
+	t.Run("ReadOnlyScope", func(t *testing.T) {
+		t.Parallel()
+		var (
+			db       = dbfake.New()
+			user     = dbgen.User(t, db, database.User{})
+			_, token = dbgen.APIKey(t, db, database.APIKey{
+				UserID:    user.ID,
+				ExpiresAt: database.Now().AddDate(0, 0, 1),
+				Scope:     database.APIKeyScopeWorkspaceSSH,
+			})
+			mw = httpmw.ExtractAPIKeyMW(httpmw.ExtractAPIKeyConfig{
+				DB:              db,
+				RedirectToLogin: false,
+			})
+			handler = mw(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				httpapi.Write(r.Context(), rw, http.StatusOK, codersdk.Response{
+					Message: "it worked!",
+				})
+			}))
+		)
+
+		for method, status := range map[string]int{
+			http.MethodGet:    http.StatusOK,
+			http.MethodPost:   http.StatusForbidden,
+			http.MethodPatch:  http.StatusForbidden,
+			http.MethodDelete: http.StatusForbidden,
+		} {
+			r := httptest.NewRequest(method, "/", nil)
+			r.Header.Set(codersdk.SessionTokenHeader, token)
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, r)
+			res := rw.Result()
+			_ = res.Body.Close()
+			require.Equal(t, status, res.StatusCode, method)
+		}
+	})
+
 	t.Run("QueryParameter", func(t *testing.T) {
 		t.Parallel()
 		var (
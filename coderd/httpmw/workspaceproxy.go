@@ -4,12 +4,17 @@ import (
 	"context"
 	"crypto/sha256"
 	"crypto/subtle"
+	"crypto/x509"
 	"database/sql"
+	"fmt"
 	"net/http"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/coderd/database"
@@ -48,6 +53,84 @@ func WorkspaceProxy(r *http.Request) database.WorkspaceProxy {
 	return proxy
 }
 
+type workspaceProxyTokenExpiryContextKey struct{}
+
+// WorkspaceProxyTokenExpiry returns when the token the workspace proxy
+// authenticated with expires, or false if it doesn't expire. It requires the
+// ExtractWorkspaceProxy middleware.
+func WorkspaceProxyTokenExpiry(r *http.Request) (time.Time, bool) {
+	expiry, ok := r.Context().Value(workspaceProxyTokenExpiryContextKey{}).(sql.NullTime)
+	if !ok {
+		panic("developer error: ExtractWorkspaceProxy middleware not provided")
+	}
+	return expiry.Time, expiry.Valid
+}
+
+// WorkspaceProxyClientCertConfig configures the TLS client certificates
+// workspace proxies authenticate with in addition to their token.
+type WorkspaceProxyClientCertConfig struct {
+	// ClientCAs verifies the client certificates of proxies. Client
+	// certificates are ignored if nil.
+	ClientCAs *x509.CertPool
+	// Require indicates whether proxies must present a client certificate.
+	Require bool
+}
+
+func WorkspaceProxyClientCertConfigOptions(caFile string, require bool) (WorkspaceProxyClientCertConfig, error) {
+	if caFile == "" {
+		if require {
+			return WorkspaceProxyClientCertConfig{}, xerrors.New("proxy client cert: a CA file is required to require client certificates")
+		}
+		return WorkspaceProxyClientCertConfig{}, nil
+	}
+
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return WorkspaceProxyClientCertConfig{}, xerrors.Errorf("proxy client cert: read %q: %w", caFile, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return WorkspaceProxyClientCertConfig{}, xerrors.Errorf("proxy client cert: no PEM-encoded certificates in %q", caFile)
+	}
+	return WorkspaceProxyClientCertConfig{
+		ClientCAs: pool,
+		Require:   require,
+	}, nil
+}
+
+// verify checks the client certificate of the request was signed by the
+// client CAs and issued for the proxy, by having its name as common name or
+// DNS name.
+func (c WorkspaceProxyClientCertConfig) verify(r *http.Request, proxy database.WorkspaceProxy) error {
+	if c.ClientCAs == nil {
+		return nil
+	}
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		if c.Require {
+			return xerrors.New("no client certificate was presented")
+		}
+		return nil
+	}
+
+	cert := r.TLS.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, intermediate := range r.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(intermediate)
+	}
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:         c.ClientCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return xerrors.Errorf("verify client certificate: %w", err)
+	}
+	if cert.Subject.CommonName != proxy.Name && !slices.Contains(cert.DNSNames, proxy.Name) {
+		return xerrors.Errorf("client certificate was not issued for proxy %q", proxy.Name)
+	}
+	return nil
+}
+
 type ExtractWorkspaceProxyConfig struct {
 	DB database.Store
 	// Optional indicates whether the middleware should be optional. If true,
@@ -55,6 +138,9 @@ type ExtractWorkspaceProxyConfig struct {
 	// allowed to continue and no workspace proxy will be set on the request
 	// context.
 	Optional bool
+	// ClientCert configures the client certificate the proxy must present
+	// in addition to its token.
+	ClientCert WorkspaceProxyClientCertConfig
 }
 
 // ExtractWorkspaceProxy extracts the external workspace proxy from the request
@@ -125,17 +211,42 @@ func ExtractWorkspaceProxy(opts ExtractWorkspaceProxyConfig) func(http.Handler)
 			}
 
 			// Do a subtle constant time comparison of the hash of the secret.
+			// The token replaced by the last rotation is accepted until it
+			// expires, so all replicas of the proxy can switch to the new
+			// token.
+			now := database.Now()
 			hashedSecret := sha256.Sum256([]byte(secret))
-			if subtle.ConstantTimeCompare(proxy.TokenHashedSecret, hashedSecret[:]) != 1 {
+			expiry := proxy.TokenExpiresAt
+			switch {
+			case subtle.ConstantTimeCompare(proxy.TokenHashedSecret, hashedSecret[:]) == 1:
+			case subtle.ConstantTimeCompare(proxy.PreviousTokenHashedSecret, hashedSecret[:]) == 1:
+				expiry = proxy.PreviousTokenExpiresAt
+			default:
 				httpapi.Write(ctx, w, http.StatusUnauthorized, codersdk.Response{
 					Message: "Invalid external proxy token",
 					Detail:  "Invalid proxy token secret.",
 				})
 				return
 			}
+			if expiry.Valid && !now.Before(expiry.Time) {
+				httpapi.Write(ctx, w, http.StatusUnauthorized, codersdk.Response{
+					Message: "Invalid external proxy token",
+					Detail:  fmt.Sprintf("Proxy token expired at %s.", expiry.Time.Format(time.RFC3339)),
+				})
+				return
+			}
+			err = opts.ClientCert.verify(r, proxy)
+			if err != nil {
+				httpapi.Write(ctx, w, http.StatusUnauthorized, codersdk.Response{
+					Message: "Invalid external proxy client certificate",
+					Detail:  err.Error(),
+				})
+				return
+			}
 
 			ctx = r.Context()
 			ctx = context.WithValue(ctx, workspaceProxyContextKey{}, proxy)
+			ctx = context.WithValue(ctx, workspaceProxyTokenExpiryContextKey{}, expiry)
 			//nolint:gocritic // Workspace proxies have full permissions. The
 			// workspace proxy auth middleware is not mounted to every route, so
 			// they can still only access the routes that the middleware is
@@ -2,13 +2,23 @@ package httpmw_test
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd/database"
@@ -162,6 +172,76 @@ func TestExtractWorkspaceProxy(t *testing.T) {
 		require.Equal(t, http.StatusOK, res.StatusCode)
 	})
 
+	t.Run("Rotated", func(t *testing.T) {
+		t.Parallel()
+		var (
+			db = dbfake.New()
+
+			proxy, oldSecret = dbgen.WorkspaceProxy(t, db, database.WorkspaceProxy{})
+		)
+		newSecret, err := cryptorand.HexString(64)
+		require.NoError(t, err)
+		hashedSecret := sha256.Sum256([]byte(newSecret))
+		expiresAt := database.Now().Add(time.Hour)
+		previousExpiresAt := database.Now().Add(time.Minute)
+		_, err = db.RotateWorkspaceProxyToken(context.Background(), database.RotateWorkspaceProxyTokenParams{
+			ID:                       proxy.ID,
+			CurrentTokenHashedSecret: proxy.TokenHashedSecret,
+			TokenHashedSecret:        hashedSecret[:],
+			TokenExpiresAt:           expiresAt,
+			PreviousTokenExpiresAt:   previousExpiresAt,
+		})
+		require.NoError(t, err)
+
+		// Both tokens are accepted until the old one expires.
+		for secret, expected := range map[string]time.Time{
+			newSecret: expiresAt,
+			oldSecret: previousExpiresAt,
+		} {
+			r := httptest.NewRequest("GET", "/", nil)
+			rw := httptest.NewRecorder()
+			r.Header.Set(httpmw.WorkspaceProxyAuthTokenHeader, fmt.Sprintf("%s:%s", proxy.ID.String(), secret))
+			httpmw.ExtractWorkspaceProxy(httpmw.ExtractWorkspaceProxyConfig{
+				DB: db,
+			})(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				expiry, ok := httpmw.WorkspaceProxyTokenExpiry(r)
+				assert.True(t, ok)
+				assert.WithinDuration(t, expected, expiry, time.Second)
+				successHandler.ServeHTTP(rw, r)
+			})).ServeHTTP(rw, r)
+			res := rw.Result()
+			_ = res.Body.Close()
+			require.Equal(t, http.StatusOK, res.StatusCode)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		t.Parallel()
+		var (
+			db = dbfake.New()
+			r  = httptest.NewRequest("GET", "/", nil)
+			rw = httptest.NewRecorder()
+
+			proxy, oldSecret = dbgen.WorkspaceProxy(t, db, database.WorkspaceProxy{})
+		)
+		_, err := db.RotateWorkspaceProxyToken(context.Background(), database.RotateWorkspaceProxyTokenParams{
+			ID:                       proxy.ID,
+			CurrentTokenHashedSecret: proxy.TokenHashedSecret,
+			TokenHashedSecret:        []byte("new-secret"),
+			TokenExpiresAt:           database.Now().Add(time.Hour),
+			PreviousTokenExpiresAt:   database.Now().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+
+		r.Header.Set(httpmw.WorkspaceProxyAuthTokenHeader, fmt.Sprintf("%s:%s", proxy.ID.String(), oldSecret))
+		httpmw.ExtractWorkspaceProxy(httpmw.ExtractWorkspaceProxyConfig{
+			DB: db,
+		})(successHandler).ServeHTTP(rw, r)
+		res := rw.Result()
+		defer res.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
 	t.Run("Deleted", func(t *testing.T) {
 		t.Parallel()
 		var (
@@ -186,6 +266,96 @@ func TestExtractWorkspaceProxy(t *testing.T) {
 		defer res.Body.Close()
 		require.Equal(t, http.StatusUnauthorized, res.StatusCode)
 	})
+
+	t.Run("ClientCertificate", func(t *testing.T) {
+		t.Parallel()
+		var (
+			db = dbfake.New()
+
+			proxy, secret     = dbgen.WorkspaceProxy(t, db, database.WorkspaceProxy{})
+			ca, caKey         = newTestCA(t)
+			otherCA, otherKey = newTestCA(t)
+			pool              = x509.NewCertPool()
+		)
+		pool.AddCert(ca)
+
+		for _, tc := range []struct {
+			name     string
+			cert     *x509.Certificate
+			require  bool
+			expected int
+		}{
+			{name: "Valid", cert: newTestClientCert(t, ca, caKey, proxy.Name), expected: http.StatusOK},
+			{name: "Missing", expected: http.StatusUnauthorized, require: true},
+			{name: "NotRequired", expected: http.StatusOK},
+			{name: "OtherProxy", cert: newTestClientCert(t, ca, caKey, "other"), expected: http.StatusUnauthorized},
+			{name: "UntrustedCA", cert: newTestClientCert(t, otherCA, otherKey, proxy.Name), expected: http.StatusUnauthorized},
+		} {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				t.Parallel()
+				r := httptest.NewRequest("GET", "/", nil)
+				rw := httptest.NewRecorder()
+				r.Header.Set(httpmw.WorkspaceProxyAuthTokenHeader, fmt.Sprintf("%s:%s", proxy.ID.String(), secret))
+				r.TLS = &tls.ConnectionState{}
+				if tc.cert != nil {
+					r.TLS.PeerCertificates = []*x509.Certificate{tc.cert}
+				}
+
+				httpmw.ExtractWorkspaceProxy(httpmw.ExtractWorkspaceProxyConfig{
+					DB: db,
+					ClientCert: httpmw.WorkspaceProxyClientCertConfig{
+						ClientCAs: pool,
+						Require:   tc.require,
+					},
+				})(successHandler).ServeHTTP(rw, r)
+				res := rw.Result()
+				defer res.Body.Close()
+				require.Equal(t, tc.expected, res.StatusCode)
+			})
+		}
+	})
+}
+
+func newTestCA(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+func newTestClientCert(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, commonName string) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
 }
 
 func TestExtractWorkspaceProxyParam(t *testing.T) {
@@ -147,6 +147,423 @@ func (api *API) insightsUserLatency(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(ctx, rw, http.StatusOK, resp)
 }
 
+// @Summary Get insights about app sessions
+// @ID get-insights-about-app-sessions
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Insights
+// @Success 200 {object} codersdk.AppSessionInsightsResponse
+// @Router /insights/app-sessions [get]
+func (api *API) insightsAppSessions(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p := httpapi.NewQueryParamParser().
+		Required("start_time").
+		Required("end_time")
+	vals := r.URL.Query()
+	var (
+		// The QueryParamParser does not preserve timezone, so we need
+		// to parse the time ourselves.
+		startTimeString = p.String(vals, "", "start_time")
+		endTimeString   = p.String(vals, "", "end_time")
+		templateIDs     = p.UUIDs(vals, []uuid.UUID{}, "template_ids")
+	)
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	startTime, endTime, ok := parseInsightsStartAndEndTime(ctx, rw, startTimeString, endTimeString)
+	if !ok {
+		return
+	}
+
+	rows, err := api.Database.GetAppSessionInsights(ctx, database.GetAppSessionInsightsParams{
+		StartTime:   startTime,
+		EndTime:     endTime,
+		TemplateIDs: templateIDs,
+	})
+	if err != nil {
+		if httpapi.Is404Error(err) {
+			httpapi.ResourceNotFound(rw)
+			return
+		}
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching app session insights.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	templateIDSet := make(map[uuid.UUID]struct{})
+	apps := make([]codersdk.AppSessionInsight, 0, len(rows))
+	for _, row := range rows {
+		templateIDSet[row.TemplateID] = struct{}{}
+		apps = append(apps, codersdk.AppSessionInsight{
+			UserID:                row.UserID,
+			Username:              row.Username,
+			AvatarURL:             row.AvatarURL.String,
+			TemplateID:            row.TemplateID,
+			AccessMethod:          row.AccessMethod,
+			SlugOrPort:            row.SlugOrPort,
+			Sessions:              row.Sessions,
+			ActiveSeconds:         row.ActiveDurationMS / 1000,
+			FirstSessionStartedAt: row.FirstSessionStartedAt,
+			LastSessionEndedAt:    row.LastSessionEndedAt,
+		})
+	}
+
+	// TemplateIDs that contributed to the data.
+	seenTemplateIDs := make([]uuid.UUID, 0, len(templateIDSet))
+	for templateID := range templateIDSet {
+		seenTemplateIDs = append(seenTemplateIDs, templateID)
+	}
+	slices.SortFunc(seenTemplateIDs, func(a, b uuid.UUID) int {
+		return slice.Ascending(a.String(), b.String())
+	})
+
+	resp := codersdk.AppSessionInsightsResponse{
+		Report: codersdk.AppSessionInsightsReport{
+			StartTime:   startTime,
+			EndTime:     endTime,
+			TemplateIDs: seenTemplateIDs,
+			Apps:        apps,
+		},
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// @Summary Get insights about app access logs
+// @ID get-insights-about-app-access-logs
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Insights
+// @Success 200 {object} codersdk.AppAccessLogInsightsResponse
+// @Router /insights/app-access-logs [get]
+func (api *API) insightsAppAccessLogs(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p := httpapi.NewQueryParamParser().
+		Required("start_time").
+		Required("end_time")
+	vals := r.URL.Query()
+	var (
+		// The QueryParamParser does not preserve timezone, so we need
+		// to parse the time ourselves.
+		startTimeString = p.String(vals, "", "start_time")
+		endTimeString   = p.String(vals, "", "end_time")
+		templateIDs     = p.UUIDs(vals, []uuid.UUID{}, "template_ids")
+	)
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	startTime, endTime, ok := parseInsightsStartAndEndTime(ctx, rw, startTimeString, endTimeString)
+	if !ok {
+		return
+	}
+
+	rows, err := api.Database.GetAppAccessLogInsights(ctx, database.GetAppAccessLogInsightsParams{
+		StartTime:   startTime,
+		EndTime:     endTime,
+		TemplateIDs: templateIDs,
+	})
+	if err != nil {
+		if httpapi.Is404Error(err) {
+			httpapi.ResourceNotFound(rw)
+			return
+		}
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching app access log insights.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	templateIDSet := make(map[uuid.UUID]struct{})
+	entries := make([]codersdk.AppAccessLogInsight, 0, len(rows))
+	for _, row := range rows {
+		templateIDSet[row.TemplateID] = struct{}{}
+		entries = append(entries, codersdk.AppAccessLogInsight{
+			UserID:        row.UserID.UUID,
+			Username:      row.Username,
+			AvatarURL:     row.AvatarURL,
+			TemplateID:    row.TemplateID,
+			AccessMethod:  row.AccessMethod,
+			SlugOrPort:    row.SlugOrPort,
+			PathPrefix:    row.PathPrefix,
+			StatusCode:    int(row.StatusCode),
+			Requests:      row.Requests,
+			LatencyP50MS:  row.Latency50,
+			LatencyP95MS:  row.Latency95,
+			LastRequestAt: row.LastRequestAt,
+		})
+	}
+
+	// TemplateIDs that contributed to the data.
+	seenTemplateIDs := make([]uuid.UUID, 0, len(templateIDSet))
+	for templateID := range templateIDSet {
+		seenTemplateIDs = append(seenTemplateIDs, templateID)
+	}
+	slices.SortFunc(seenTemplateIDs, func(a, b uuid.UUID) int {
+		return slice.Ascending(a.String(), b.String())
+	})
+
+	resp := codersdk.AppAccessLogInsightsResponse{
+		Report: codersdk.AppAccessLogInsightsReport{
+			StartTime:   startTime,
+			EndTime:     endTime,
+			TemplateIDs: seenTemplateIDs,
+			Entries:     entries,
+		},
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// @Summary Get insights about builds
+// @ID get-insights-about-builds
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Insights
+// @Success 200 {object} codersdk.BuildInsightsResponse
+// @Router /insights/builds [get]
+func (api *API) insightsBuilds(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p := httpapi.NewQueryParamParser().
+		Required("start_time").
+		Required("end_time")
+	vals := r.URL.Query()
+	var (
+		// The QueryParamParser does not preserve timezone, so we need
+		// to parse the time ourselves.
+		startTimeString = p.String(vals, "", "start_time")
+		endTimeString   = p.String(vals, "", "end_time")
+		intervalString  = p.String(vals, "", "interval")
+		templateIDs     = p.UUIDs(vals, []uuid.UUID{}, "template_ids")
+	)
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	startTime, endTime, ok := parseInsightsStartAndEndTime(ctx, rw, startTimeString, endTimeString)
+	if !ok {
+		return
+	}
+	interval, ok := verifyInsightsInterval(ctx, rw, intervalString)
+	if !ok {
+		return
+	}
+
+	var rows, intervalRows []database.GetTemplateVersionBuildInsightsRow
+
+	// Use a transaction to ensure that we get consistent data between
+	// the full and interval report.
+	err := api.Database.InTx(func(tx database.Store) error {
+		var err error
+
+		if interval != "" {
+			intervalRows, err = tx.GetTemplateVersionBuildInsights(ctx, database.GetTemplateVersionBuildInsightsParams{
+				StartTime:       startTime,
+				EndTime:         endTime,
+				IntervalSeconds: int64((24 * time.Hour).Seconds()),
+				TemplateIDs:     templateIDs,
+			})
+			if err != nil {
+				return xerrors.Errorf("get daily template version build insights: %w", err)
+			}
+		}
+
+		// The full report is a single interval that spans the whole range.
+		rows, err = tx.GetTemplateVersionBuildInsights(ctx, database.GetTemplateVersionBuildInsightsParams{
+			StartTime:       startTime,
+			EndTime:         endTime,
+			IntervalSeconds: int64(endTime.Sub(startTime).Seconds()),
+			TemplateIDs:     templateIDs,
+		})
+		if err != nil {
+			return xerrors.Errorf("get template version build insights: %w", err)
+		}
+
+		return nil
+	}, nil)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching build insights.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// TemplateIDs that contributed to the data.
+	seenTemplateIDs := make([]uuid.UUID, 0, len(rows))
+	for _, row := range rows {
+		if !slices.Contains(seenTemplateIDs, row.TemplateID) {
+			seenTemplateIDs = append(seenTemplateIDs, row.TemplateID)
+		}
+	}
+
+	resp := codersdk.BuildInsightsResponse{
+		Report: codersdk.BuildInsightsReport{
+			StartTime:        startTime,
+			EndTime:          endTime,
+			TemplateIDs:      seenTemplateIDs,
+			TemplateVersions: convertTemplateVersionBuildInsights(rows),
+		},
+		IntervalReports: []codersdk.BuildInsightsIntervalReport{},
+	}
+	if interval != "" {
+		// Intervals without builds have no rows, but are still reported so
+		// that the reports can be charted.
+		for t := startTime; t.Before(endTime); t = t.Add(24 * time.Hour) {
+			intervalEnd := t.Add(24 * time.Hour)
+			if intervalEnd.After(endTime) {
+				intervalEnd = endTime
+			}
+			var versionRows []database.GetTemplateVersionBuildInsightsRow
+			for _, row := range intervalRows {
+				if row.StartTime.Equal(t) {
+					versionRows = append(versionRows, row)
+				}
+			}
+			resp.IntervalReports = append(resp.IntervalReports, codersdk.BuildInsightsIntervalReport{
+				StartTime:        t,
+				EndTime:          intervalEnd,
+				Interval:         interval,
+				TemplateVersions: convertTemplateVersionBuildInsights(versionRows),
+			})
+		}
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+func convertTemplateVersionBuildInsights(rows []database.GetTemplateVersionBuildInsightsRow) []codersdk.TemplateVersionBuildInsight {
+	insights := make([]codersdk.TemplateVersionBuildInsight, 0, len(rows))
+	for _, row := range rows {
+		var failureRate float64
+		if row.Builds > 0 {
+			failureRate = float64(row.FailedBuilds) / float64(row.Builds)
+		}
+		insights = append(insights, codersdk.TemplateVersionBuildInsight{
+			TemplateID:          row.TemplateID,
+			TemplateVersionID:   row.TemplateVersionID,
+			TemplateVersionName: row.TemplateVersionName,
+			Builds:              row.Builds,
+			FailedBuilds:        row.FailedBuilds,
+			FailureRate:         failureRate,
+			BuildDurationP50MS:  row.BuildDuration50,
+			BuildDurationP95MS:  row.BuildDuration95,
+			AgentConnectP50MS:   row.AgentConnect50,
+			AgentConnectP95MS:   row.AgentConnect95,
+		})
+	}
+	return insights
+}
+
+// @Summary Get insights about schedules
+// @ID get-insights-about-schedules
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Insights
+// @Success 200 {object} codersdk.ScheduleInsightsResponse
+// @Router /insights/schedules [get]
+func (api *API) insightsSchedules(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	p := httpapi.NewQueryParamParser().
+		Required("start_time").
+		Required("end_time")
+	vals := r.URL.Query()
+	var (
+		// The QueryParamParser does not preserve timezone, so we need
+		// to parse the time ourselves.
+		startTimeString = p.String(vals, "", "start_time")
+		endTimeString   = p.String(vals, "", "end_time")
+		templateIDs     = p.UUIDs(vals, []uuid.UUID{}, "template_ids")
+	)
+	p.ErrorExcessParams(vals)
+	if len(p.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: p.Errors,
+		})
+		return
+	}
+
+	startTime, endTime, ok := parseInsightsStartAndEndTime(ctx, rw, startTimeString, endTimeString)
+	if !ok {
+		return
+	}
+
+	// The latest build of a workspace lasts until the end of the report, it
+	// must not be counted past the current time.
+	queryEndTime := endTime
+	if now := time.Now(); now.Before(queryEndTime) {
+		queryEndTime = now
+	}
+
+	rows, err := api.Database.GetScheduleInsights(ctx, database.GetScheduleInsightsParams{
+		StartTime:   startTime,
+		EndTime:     queryEndTime,
+		TemplateIDs: templateIDs,
+	})
+	if err != nil {
+		if httpapi.Is404Error(err) {
+			httpapi.ResourceNotFound(rw)
+			return
+		}
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching schedule insights.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// TemplateIDs that contributed to the data.
+	seenTemplateIDs := make([]uuid.UUID, 0, len(rows))
+	templates := make([]codersdk.TemplateScheduleInsight, 0, len(rows))
+	for _, row := range rows {
+		seenTemplateIDs = append(seenTemplateIDs, row.TemplateID)
+		templates = append(templates, codersdk.TemplateScheduleInsight{
+			TemplateID:             row.TemplateID,
+			AllocatedSeconds:       row.AllocatedSeconds,
+			RunningSeconds:         row.RunningSeconds,
+			StoppedSeconds:         row.AllocatedSeconds - row.RunningSeconds,
+			ScheduleStoppedSeconds: row.ScheduleStoppedSeconds,
+			Autostarts:             row.Autostarts,
+			Autostops:              row.Autostops,
+		})
+	}
+
+	resp := codersdk.ScheduleInsightsResponse{
+		Report: codersdk.ScheduleInsightsReport{
+			StartTime:   startTime,
+			EndTime:     endTime,
+			TemplateIDs: seenTemplateIDs,
+			Templates:   templates,
+		},
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
 // @Summary Get insights about templates
 // @ID get-insights-about-templates
 // @Security CoderSessionToken
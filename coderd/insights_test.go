@@ -2,6 +2,7 @@ package coderd_test
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"io"
 	"net/http"
@@ -17,7 +18,11 @@ import (
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/agent"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/database/dbgen"
 	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/workspaceapps"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
 	"github.com/coder/coder/provisioner/echo"
@@ -230,6 +235,458 @@ func TestUserLatencyInsights_BadRequest(t *testing.T) {
 	assert.Error(t, err, "want error for end time partial day when not today")
 }
 
+func TestAppSessionInsights(t *testing.T) {
+	t.Parallel()
+
+	client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:          echo.ParseComplete,
+		ProvisionPlan:  echo.ProvisionComplete,
+		ProvisionApply: echo.ProvisionApplyWithAgent(authToken),
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	build := coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	// The agent doesn't need to be connected, the stats only reference it.
+	agentID := build.Resources[0].Agents[0].ID
+
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	start := database.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	stats := []workspaceapps.StatsReport{
+		{
+			AccessMethod:     workspaceapps.AccessMethodPath,
+			SlugOrPort:       "code-server",
+			SessionStartedAt: start,
+			SessionEndedAt:   start.Add(time.Minute),
+			Requests:         2,
+			ActiveDuration:   30 * time.Second,
+		},
+		{
+			AccessMethod:     workspaceapps.AccessMethodPath,
+			SlugOrPort:       "code-server",
+			SessionStartedAt: start.Add(time.Minute),
+			SessionEndedAt:   start.Add(2 * time.Minute),
+			Requests:         1,
+			ActiveDuration:   time.Minute,
+		},
+		{
+			AccessMethod:     workspaceapps.AccessMethodTerminal,
+			SessionStartedAt: start,
+			SessionEndedAt:   start.Add(10 * time.Minute),
+			Requests:         1,
+			ActiveDuration:   10 * time.Minute,
+		},
+	}
+	for i := range stats {
+		stats[i].UserID = user.UserID
+		stats[i].WorkspaceID = workspace.ID
+		stats[i].AgentID = agentID
+		stats[i].SessionID = uuid.New()
+	}
+	reporter := workspaceapps.NewStatsDBReporter(api.Database, workspaceapps.DefaultStatsDBReporterBatchSize)
+	//nolint:gocritic // Inserting app stats is a system function.
+	err := reporter.Report(dbauthz.AsSystemRestricted(ctx), stats)
+	require.NoError(t, err)
+
+	resp, err := client.AppSessionInsights(ctx, codersdk.AppSessionInsightsRequest{
+		StartTime: today,
+		EndTime:   time.Now().UTC().Truncate(time.Hour).Add(time.Hour), // Round up to include the current hour.
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{template.ID}, resp.Report.TemplateIDs)
+	require.Len(t, resp.Report.Apps, 2)
+
+	terminal := resp.Report.Apps[0]
+	assert.Equal(t, user.UserID, terminal.UserID)
+	assert.Equal(t, template.ID, terminal.TemplateID)
+	assert.Equal(t, string(workspaceapps.AccessMethodTerminal), terminal.AccessMethod)
+	assert.EqualValues(t, 1, terminal.Sessions)
+	assert.EqualValues(t, 600, terminal.ActiveSeconds)
+
+	codeServer := resp.Report.Apps[1]
+	assert.Equal(t, "code-server", codeServer.SlugOrPort)
+	assert.Equal(t, string(workspaceapps.AccessMethodPath), codeServer.AccessMethod)
+	assert.EqualValues(t, 3, codeServer.Sessions)
+	assert.EqualValues(t, 90, codeServer.ActiveSeconds)
+	assert.WithinDuration(t, start, codeServer.FirstSessionStartedAt, time.Second)
+	assert.WithinDuration(t, start.Add(2*time.Minute), codeServer.LastSessionEndedAt, time.Second)
+
+	// Sessions from other templates are excluded.
+	otherVersion := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	otherTemplate := coderdtest.CreateTemplate(t, client, user.OrganizationID, otherVersion.ID)
+	resp, err = client.AppSessionInsights(ctx, codersdk.AppSessionInsightsRequest{
+		StartTime:   today,
+		EndTime:     time.Now().UTC().Truncate(time.Hour).Add(time.Hour),
+		TemplateIDs: []uuid.UUID{otherTemplate.ID},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Report.Apps)
+}
+
+func TestAppSessionInsights_RBAC(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{})
+	admin := coderdtest.CreateFirstUser(t, client)
+	templateAdmin, _ := coderdtest.CreateAnotherUser(t, client, admin.OrganizationID, rbac.RoleTemplateAdmin())
+	regular, _ := coderdtest.CreateAnotherUser(t, client, admin.OrganizationID)
+
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	req := codersdk.AppSessionInsightsRequest{
+		StartTime: today,
+		EndTime:   time.Now().UTC().Truncate(time.Hour).Add(time.Hour), // Round up to include the current hour.
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	_, err := client.AppSessionInsights(ctx, req)
+	require.NoError(t, err)
+	_, err = templateAdmin.AppSessionInsights(ctx, req)
+	require.NoError(t, err)
+
+	_, err = regular.AppSessionInsights(ctx, req)
+	require.Error(t, err)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+}
+
+func TestAppAccessLogInsights(t *testing.T) {
+	t.Parallel()
+
+	client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:          echo.ParseComplete,
+		ProvisionPlan:  echo.ProvisionComplete,
+		ProvisionApply: echo.ProvisionApplyWithAgent(authToken),
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	build := coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	// The agent doesn't need to be connected, the logs only reference it.
+	agentID := build.Resources[0].Agents[0].ID
+
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	start := database.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	logs := []workspaceapps.AccessLog{
+		{
+			Time:       start,
+			UserID:     user.UserID,
+			PathPrefix: "/api",
+			StatusCode: http.StatusOK,
+			Latency:    20 * time.Millisecond,
+		},
+		{
+			Time:       start.Add(time.Second),
+			UserID:     user.UserID,
+			PathPrefix: "/api",
+			StatusCode: http.StatusOK,
+			Latency:    20 * time.Millisecond,
+		},
+		{
+			// Users that are not signed in to Coder have no user ID.
+			Time:       start,
+			PathPrefix: "/",
+			StatusCode: http.StatusNotFound,
+			Latency:    5 * time.Millisecond,
+		},
+	}
+	for i := range logs {
+		logs[i].WorkspaceID = workspace.ID
+		logs[i].AgentID = agentID
+		logs[i].AccessMethod = workspaceapps.AccessMethodSubdomain
+		logs[i].SlugOrPort = "code-server"
+	}
+	reporter := workspaceapps.NewStatsDBReporter(api.Database, workspaceapps.DefaultStatsDBReporterBatchSize)
+	//nolint:gocritic // Inserting app access logs is a system function.
+	err := reporter.ReportAccessLogs(dbauthz.AsSystemRestricted(ctx), logs)
+	require.NoError(t, err)
+
+	resp, err := client.AppAccessLogInsights(ctx, codersdk.AppAccessLogInsightsRequest{
+		StartTime: today,
+		EndTime:   time.Now().UTC().Truncate(time.Hour).Add(time.Hour), // Round up to include the current hour.
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{template.ID}, resp.Report.TemplateIDs)
+	require.Len(t, resp.Report.Entries, 2)
+
+	apiEntry := resp.Report.Entries[0]
+	assert.Equal(t, user.UserID, apiEntry.UserID)
+	assert.Equal(t, template.ID, apiEntry.TemplateID)
+	assert.Equal(t, string(workspaceapps.AccessMethodSubdomain), apiEntry.AccessMethod)
+	assert.Equal(t, "code-server", apiEntry.SlugOrPort)
+	assert.Equal(t, "/api", apiEntry.PathPrefix)
+	assert.Equal(t, http.StatusOK, apiEntry.StatusCode)
+	assert.EqualValues(t, 2, apiEntry.Requests)
+	assert.EqualValues(t, 20, apiEntry.LatencyP50MS)
+	assert.EqualValues(t, 20, apiEntry.LatencyP95MS)
+	assert.WithinDuration(t, start.Add(time.Second), apiEntry.LastRequestAt, time.Second)
+
+	anonymous := resp.Report.Entries[1]
+	assert.Equal(t, uuid.Nil, anonymous.UserID)
+	assert.Empty(t, anonymous.Username)
+	assert.Equal(t, "/", anonymous.PathPrefix)
+	assert.Equal(t, http.StatusNotFound, anonymous.StatusCode)
+	assert.EqualValues(t, 1, anonymous.Requests)
+
+	// Requests to workspaces of other templates are excluded.
+	otherVersion := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	otherTemplate := coderdtest.CreateTemplate(t, client, user.OrganizationID, otherVersion.ID)
+	resp, err = client.AppAccessLogInsights(ctx, codersdk.AppAccessLogInsightsRequest{
+		StartTime:   today,
+		EndTime:     time.Now().UTC().Truncate(time.Hour).Add(time.Hour),
+		TemplateIDs: []uuid.UUID{otherTemplate.ID},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Report.Entries)
+}
+
+func TestBuildInsights(t *testing.T) {
+	t.Parallel()
+
+	client, _, api := coderdtest.NewWithAPI(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	regular, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+	twoDaysAgo := today.AddDate(0, 0, -2)
+
+	template := dbgen.Template(t, api.Database, database.Template{
+		OrganizationID: user.OrganizationID,
+		CreatedBy:      user.UserID,
+	})
+	version := dbgen.TemplateVersion(t, api.Database, database.TemplateVersion{
+		OrganizationID: user.OrganizationID,
+		TemplateID:     uuid.NullUUID{UUID: template.ID, Valid: true},
+		CreatedBy:      user.UserID,
+	})
+	workspace := dbgen.Workspace(t, api.Database, database.Workspace{
+		OrganizationID: user.OrganizationID,
+		OwnerID:        user.UserID,
+		TemplateID:     template.ID,
+	})
+	// Two builds succeed and one fails the day before yesterday, and one
+	// build is canceled yesterday.
+	for i, b := range []struct {
+		createdAt   time.Time
+		duration    time.Duration
+		failed      bool
+		canceled    bool
+		connectTime time.Duration
+	}{
+		{twoDaysAgo.Add(time.Hour), time.Minute, false, false, 10 * time.Second},
+		{twoDaysAgo.Add(2 * time.Hour), 3 * time.Minute, false, false, 30 * time.Second},
+		{twoDaysAgo.Add(3 * time.Hour), 5 * time.Minute, true, false, 0},
+		{yesterday.Add(time.Hour), time.Minute, false, true, 0},
+	} {
+		job := database.ProvisionerJob{
+			OrganizationID: user.OrganizationID,
+			CreatedAt:      b.createdAt,
+			StartedAt:      sql.NullTime{Time: b.createdAt, Valid: true},
+			CompletedAt:    sql.NullTime{Time: b.createdAt.Add(b.duration), Valid: true},
+		}
+		if b.failed {
+			job.Error = sql.NullString{String: "failed", Valid: true}
+		}
+		if b.canceled {
+			job.CanceledAt = sql.NullTime{Time: b.createdAt.Add(b.duration), Valid: true}
+		}
+		job = dbgen.ProvisionerJob(t, api.Database, job)
+		dbgen.WorkspaceBuild(t, api.Database, database.WorkspaceBuild{
+			WorkspaceID:       workspace.ID,
+			TemplateVersionID: version.ID,
+			BuildNumber:       int32(i + 1),
+			JobID:             job.ID,
+			CreatedAt:         b.createdAt,
+			Transition:        database.WorkspaceTransitionStart,
+		})
+		if b.connectTime == 0 {
+			continue
+		}
+		resource := dbgen.WorkspaceResource(t, api.Database, database.WorkspaceResource{
+			JobID: job.ID,
+		})
+		agentCreatedAt := b.createdAt.Add(b.duration)
+		workspaceAgent := dbgen.WorkspaceAgent(t, api.Database, database.WorkspaceAgent{
+			ResourceID: resource.ID,
+			CreatedAt:  agentCreatedAt,
+		})
+		err := api.Database.UpdateWorkspaceAgentConnectionByID(dbauthz.AsSystemRestricted(context.Background()), database.UpdateWorkspaceAgentConnectionByIDParams{
+			ID:               workspaceAgent.ID,
+			FirstConnectedAt: sql.NullTime{Time: agentCreatedAt.Add(b.connectTime), Valid: true},
+			LastConnectedAt:  sql.NullTime{Time: agentCreatedAt.Add(b.connectTime), Valid: true},
+			UpdatedAt:        agentCreatedAt.Add(b.connectTime),
+		})
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	req := codersdk.BuildInsightsRequest{
+		StartTime: twoDaysAgo,
+		EndTime:   today,
+		Interval:  codersdk.InsightsReportIntervalDay,
+	}
+	resp, err := client.BuildInsights(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{template.ID}, resp.Report.TemplateIDs)
+	require.Len(t, resp.Report.TemplateVersions, 1)
+	insight := resp.Report.TemplateVersions[0]
+	assert.Equal(t, template.ID, insight.TemplateID)
+	assert.Equal(t, version.ID, insight.TemplateVersionID)
+	assert.Equal(t, version.Name, insight.TemplateVersionName)
+	// The canceled build is not counted, and the failed build has no duration.
+	assert.EqualValues(t, 3, insight.Builds)
+	assert.EqualValues(t, 1, insight.FailedBuilds)
+	assert.InDelta(t, 1.0/3, insight.FailureRate, 0.001)
+	assert.Greater(t, insight.BuildDurationP50MS, float64(0))
+	assert.LessOrEqual(t, insight.BuildDurationP50MS, insight.BuildDurationP95MS)
+	assert.LessOrEqual(t, insight.BuildDurationP95MS, float64((3 * time.Minute).Milliseconds()))
+	assert.Greater(t, insight.AgentConnectP50MS, float64(0))
+	assert.LessOrEqual(t, insight.AgentConnectP95MS, float64((30 * time.Second).Milliseconds()))
+
+	// Days without builds are reported without template versions.
+	require.Len(t, resp.IntervalReports, 2)
+	assert.Equal(t, twoDaysAgo, resp.IntervalReports[0].StartTime.UTC())
+	require.Len(t, resp.IntervalReports[0].TemplateVersions, 1)
+	assert.EqualValues(t, 3, resp.IntervalReports[0].TemplateVersions[0].Builds)
+	assert.Equal(t, yesterday, resp.IntervalReports[1].StartTime.UTC())
+	assert.Empty(t, resp.IntervalReports[1].TemplateVersions)
+
+	// Builds of other templates are excluded.
+	otherTemplate := dbgen.Template(t, api.Database, database.Template{
+		OrganizationID: user.OrganizationID,
+		CreatedBy:      user.UserID,
+	})
+	resp, err = client.BuildInsights(ctx, codersdk.BuildInsightsRequest{
+		StartTime:   twoDaysAgo,
+		EndTime:     today,
+		TemplateIDs: []uuid.UUID{otherTemplate.ID},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Report.TemplateVersions)
+	assert.Empty(t, resp.IntervalReports)
+
+	// Only template admins can see build insights.
+	_, err = regular.BuildInsights(ctx, req)
+	require.Error(t, err)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+}
+
+func TestScheduleInsights(t *testing.T) {
+	t.Parallel()
+
+	client, _, api := coderdtest.NewWithAPI(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	regular, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+	y, m, d := time.Now().UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	template := dbgen.Template(t, api.Database, database.Template{
+		OrganizationID: user.OrganizationID,
+		CreatedBy:      user.UserID,
+	})
+	workspace := dbgen.Workspace(t, api.Database, database.Workspace{
+		OrganizationID: user.OrganizationID,
+		OwnerID:        user.UserID,
+		TemplateID:     template.ID,
+	})
+	// The workspace runs from the day before, is stopped by autostop at
+	// 02:00, started by autostart at 09:00 and stopped by its owner at 18:00.
+	for i, b := range []struct {
+		createdAt  time.Time
+		transition database.WorkspaceTransition
+		reason     database.BuildReason
+	}{
+		{yesterday.Add(-4 * time.Hour), database.WorkspaceTransitionStart, database.BuildReasonInitiator},
+		{yesterday.Add(2 * time.Hour), database.WorkspaceTransitionStop, database.BuildReasonAutostop},
+		{yesterday.Add(9 * time.Hour), database.WorkspaceTransitionStart, database.BuildReasonAutostart},
+		{yesterday.Add(18 * time.Hour), database.WorkspaceTransitionStop, database.BuildReasonInitiator},
+	} {
+		job := dbgen.ProvisionerJob(t, api.Database, database.ProvisionerJob{
+			OrganizationID: user.OrganizationID,
+			CreatedAt:      b.createdAt,
+		})
+		dbgen.WorkspaceBuild(t, api.Database, database.WorkspaceBuild{
+			WorkspaceID: workspace.ID,
+			BuildNumber: int32(i + 1),
+			JobID:       job.ID,
+			CreatedAt:   b.createdAt,
+			Transition:  b.transition,
+			Reason:      b.reason,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	req := codersdk.ScheduleInsightsRequest{
+		StartTime: yesterday,
+		EndTime:   today,
+	}
+	resp, err := client.ScheduleInsights(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{template.ID}, resp.Report.TemplateIDs)
+	require.Len(t, resp.Report.Templates, 1)
+	insight := resp.Report.Templates[0]
+	assert.Equal(t, template.ID, insight.TemplateID)
+	assert.EqualValues(t, (24 * time.Hour).Seconds(), insight.AllocatedSeconds)
+	assert.EqualValues(t, (11 * time.Hour).Seconds(), insight.RunningSeconds)
+	assert.EqualValues(t, (13 * time.Hour).Seconds(), insight.StoppedSeconds)
+	assert.EqualValues(t, (7 * time.Hour).Seconds(), insight.ScheduleStoppedSeconds)
+	assert.EqualValues(t, 1, insight.Autostarts)
+	assert.EqualValues(t, 1, insight.Autostops)
+
+	// Workspaces of other templates are excluded.
+	otherTemplate := dbgen.Template(t, api.Database, database.Template{
+		OrganizationID: user.OrganizationID,
+		CreatedBy:      user.UserID,
+	})
+	resp, err = client.ScheduleInsights(ctx, codersdk.ScheduleInsightsRequest{
+		StartTime:   yesterday,
+		EndTime:     today,
+		TemplateIDs: []uuid.UUID{otherTemplate.ID},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Report.Templates)
+
+	// Only template admins can see schedule insights.
+	_, err = regular.ScheduleInsights(ctx, req)
+	require.Error(t, err)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+}
+
 func TestTemplateInsights(t *testing.T) {
 	t.Parallel()
 
@@ -379,8 +379,8 @@ func TestCache_BuildTime(t *testing.T) {
 
 				job, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
 					StartedAt: sql.NullTime{Time: row.startedAt, Valid: true},
-					Types: []database.ProvisionerType{
-						database.ProvisionerTypeEcho,
+					Types: []string{
+						string(database.ProvisionerTypeEcho),
 					},
 				})
 				require.NoError(t, err)
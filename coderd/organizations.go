@@ -5,11 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"regexp"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/rbac"
@@ -109,6 +114,299 @@ func (api *API) postOrganizations(rw http.ResponseWriter, r *http.Request) {
 	httpapi.Write(ctx, rw, http.StatusCreated, convertOrganization(organization))
 }
 
+// @Summary Get organization provisioner settings
+// @ID get-organization-provisioner-settings
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Organizations
+// @Param organization path string true "Organization ID" format(uuid)
+// @Success 200 {object} codersdk.OrganizationProvisionerSettings
+// @Router /organizations/{organization}/settings/provisioners [get]
+func (api *API) organizationProvisionerSettings(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	settings, err := api.Database.GetOrganizationProvisionerSettings(ctx, organization.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Organizations without settings have no limits.
+		settings = database.OrganizationProvisionerSettings{
+			OrganizationID: organization.ID,
+		}
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization provisioner settings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationProvisionerSettings(settings))
+}
+
+// @Summary Update organization provisioner settings
+// @Description Limits are applied when provisioners acquire jobs. Jobs that are already running are not affected.
+// @ID update-organization-provisioner-settings
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Organizations
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param request body codersdk.UpdateOrganizationProvisionerSettingsRequest true "Update provisioner settings request"
+// @Success 200 {object} codersdk.OrganizationProvisionerSettings
+// @Router /organizations/{organization}/settings/provisioners [put]
+func (api *API) putOrganizationProvisionerSettings(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	var req codersdk.UpdateOrganizationProvisionerSettingsRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if req.MaxConcurrentJobs < 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid request to update organization provisioner settings!",
+			Validations: []codersdk.ValidationError{
+				{Field: "max_concurrent_jobs", Detail: "Must be a positive integer."},
+			},
+		})
+		return
+	}
+
+	now := database.Now()
+	settings, err := api.Database.UpsertOrganizationProvisionerSettings(ctx, database.UpsertOrganizationProvisionerSettingsParams{
+		OrganizationID:    organization.ID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		MaxConcurrentJobs: req.MaxConcurrentJobs,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating organization provisioner settings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationProvisionerSettings(settings))
+}
+
+func convertOrganizationProvisionerSettings(settings database.OrganizationProvisionerSettings) codersdk.OrganizationProvisionerSettings {
+	return codersdk.OrganizationProvisionerSettings{
+		OrganizationID:    settings.OrganizationID,
+		CreatedAt:         settings.CreatedAt,
+		UpdatedAt:         settings.UpdatedAt,
+		MaxConcurrentJobs: settings.MaxConcurrentJobs,
+	}
+}
+
+// templateVariableNameRegex matches the identifiers Terraform accepts as
+// variable names.
+var templateVariableNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+
+// @Summary Get organization template variables
+// @ID get-organization-template-variables
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Organizations
+// @Param organization path string true "Organization ID" format(uuid)
+// @Success 200 {array} codersdk.OrganizationTemplateVariable
+// @Router /organizations/{organization}/settings/variables [get]
+func (api *API) organizationTemplateVariables(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	variables, err := api.Database.GetOrganizationTemplateVariables(ctx, organization.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization template variables.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiVariables := make([]codersdk.OrganizationTemplateVariable, 0, len(variables))
+	for _, variable := range variables {
+		apiVariables = append(apiVariables, convertOrganizationTemplateVariable(variable))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, apiVariables)
+}
+
+// @Summary Upsert organization template variable
+// @Description The value is used by the builds of all templates in the organization that declare the variable, unless the template version sets a value other than the default.
+// @ID upsert-organization-template-variable
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Organizations
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param variable path string true "Variable name"
+// @Param request body codersdk.UpsertOrganizationTemplateVariableRequest true "Upsert template variable request"
+// @Success 200 {object} codersdk.OrganizationTemplateVariable
+// @Router /organizations/{organization}/settings/variables/{variable} [put]
+func (api *API) putOrganizationTemplateVariable(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		organization      = httpmw.OrganizationParam(r)
+		name              = chi.URLParam(r, "variable")
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.OrganizationTemplateVariable](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+
+	var req codersdk.UpsertOrganizationTemplateVariableRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if !templateVariableNameRegex.MatchString(name) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Invalid template variable name %q.", name),
+			Detail:  "Names must start with a letter or underscore and contain only letters, digits, underscores and dashes.",
+		})
+		return
+	}
+
+	// New variables are audited as if the previous value was empty.
+	variables, err := api.Database.GetOrganizationTemplateVariables(ctx, organization.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization template variables.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if idx := slices.IndexFunc(variables, func(variable database.OrganizationTemplateVariable) bool {
+		return variable.Name == name
+	}); idx >= 0 {
+		aReq.Old = variables[idx]
+	}
+
+	now := database.Now()
+	variable, err := api.Database.UpsertOrganizationTemplateVariable(ctx, database.UpsertOrganizationTemplateVariableParams{
+		OrganizationID: organization.ID,
+		Name:           name,
+		Value:          req.Value,
+		Sensitive:      req.Sensitive,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating organization template variable.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = variable
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationTemplateVariable(variable))
+}
+
+// @Summary Delete organization template variable
+// @ID delete-organization-template-variable
+// @Security CoderSessionToken
+// @Tags Organizations
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param variable path string true "Variable name"
+// @Success 204
+// @Router /organizations/{organization}/settings/variables/{variable} [delete]
+func (api *API) deleteOrganizationTemplateVariable(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		organization      = httpmw.OrganizationParam(r)
+		name              = chi.URLParam(r, "variable")
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.OrganizationTemplateVariable](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionDelete,
+		})
+	)
+	defer commitAudit()
+
+	variables, err := api.Database.GetOrganizationTemplateVariables(ctx, organization.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization template variables.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	idx := slices.IndexFunc(variables, func(variable database.OrganizationTemplateVariable) bool {
+		return variable.Name == name
+	})
+	if idx < 0 {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	aReq.Old = variables[idx]
+
+	err = api.Database.DeleteOrganizationTemplateVariable(ctx, database.DeleteOrganizationTemplateVariableParams{
+		OrganizationID: organization.ID,
+		Name:           name,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting organization template variable.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func convertOrganizationTemplateVariable(variable database.OrganizationTemplateVariable) codersdk.OrganizationTemplateVariable {
+	apiVariable := codersdk.OrganizationTemplateVariable{
+		Name:      variable.Name,
+		Value:     variable.Value,
+		Sensitive: variable.Sensitive,
+		CreatedAt: variable.CreatedAt,
+		UpdatedAt: variable.UpdatedAt,
+	}
+	if variable.Sensitive {
+		apiVariable.Value = redacted
+	}
+	return apiVariable
+}
+
 // convertOrganization consumes the database representation and outputs an API friendly representation.
 func convertOrganization(organization database.Organization) codersdk.Organization {
 	return codersdk.Organization{
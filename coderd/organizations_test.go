@@ -7,7 +7,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/testutil"
 )
@@ -110,3 +112,149 @@ func TestPostOrganizationsByUser(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestOrganizationProvisionerSettings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		settings, err := client.OrganizationProvisionerSettings(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, user.OrganizationID, settings.OrganizationID)
+		require.Zero(t, settings.MaxConcurrentJobs)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		updated, err := client.UpdateOrganizationProvisionerSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationProvisionerSettingsRequest{
+			MaxConcurrentJobs: 5,
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 5, updated.MaxConcurrentJobs)
+
+		settings, err := client.OrganizationProvisionerSettings(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, updated, settings)
+
+		_, err = client.UpdateOrganizationProvisionerSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationProvisionerSettingsRequest{
+			MaxConcurrentJobs: -1,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "max_concurrent_jobs", apiErr.Validations[0].Field)
+	})
+
+	t.Run("MemberCannotUpdate", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := member.OrganizationProvisionerSettings(ctx, user.OrganizationID)
+		require.NoError(t, err)
+
+		_, err = member.UpdateOrganizationProvisionerSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationProvisionerSettingsRequest{
+			MaxConcurrentJobs: 1,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
+
+func TestOrganizationTemplateVariables(t *testing.T) {
+	t.Parallel()
+
+	t.Run("UpsertAndDelete", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		variables, err := client.OrganizationTemplateVariables(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Empty(t, variables)
+
+		variable, err := client.UpsertOrganizationTemplateVariable(ctx, user.OrganizationID, "vpc_id", codersdk.UpsertOrganizationTemplateVariableRequest{
+			Value: "vpc-0123",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "vpc_id", variable.Name)
+		require.Equal(t, "vpc-0123", variable.Value)
+
+		_, err = client.UpsertOrganizationTemplateVariable(ctx, user.OrganizationID, "region", codersdk.UpsertOrganizationTemplateVariableRequest{
+			Value:     "eu-west-1",
+			Sensitive: true,
+		})
+		require.NoError(t, err)
+
+		variables, err = client.OrganizationTemplateVariables(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, variables, 2)
+		require.Equal(t, "region", variables[0].Name)
+		require.Equal(t, "*redacted*", variables[0].Value)
+		require.Equal(t, variable, variables[1])
+
+		err = client.DeleteOrganizationTemplateVariable(ctx, user.OrganizationID, "vpc_id")
+		require.NoError(t, err)
+		variables, err = client.OrganizationTemplateVariables(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, variables, 1)
+
+		logs := auditor.AuditLogs()
+		require.GreaterOrEqual(t, len(logs), 3)
+		logs = logs[len(logs)-3:]
+		for i, action := range []database.AuditAction{database.AuditActionWrite, database.AuditActionWrite, database.AuditActionDelete} {
+			require.Equal(t, action, logs[i].Action)
+			require.Equal(t, database.ResourceTypeOrganizationTemplateVariable, logs[i].ResourceType)
+			require.Equal(t, user.OrganizationID, logs[i].ResourceID)
+		}
+		require.Equal(t, "vpc_id", logs[2].ResourceTarget)
+
+		err = client.DeleteOrganizationTemplateVariable(ctx, user.OrganizationID, "vpc_id")
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.UpsertOrganizationTemplateVariable(ctx, user.OrganizationID, "1vpc", codersdk.UpsertOrganizationTemplateVariableRequest{
+			Value: "vpc-0123",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberCannotUpdate", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := member.UpsertOrganizationTemplateVariable(ctx, user.OrganizationID, "vpc_id", codersdk.UpsertOrganizationTemplateVariableRequest{
+			Value: "vpc-0123",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,252 @@
+// Package parametervalidation evaluates the parameter validation rules of
+// templates for the rich parameter values of workspace builds.
+package parametervalidation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/codersdk"
+)
+
+// webhookTimeout is how long webhooks have to respond. Builds are validated
+// while they are created, so webhooks must respond quickly.
+const webhookTimeout = 10 * time.Second
+
+// Error is returned if parameter values don't satisfy the validation rules
+// of their template.
+type Error struct {
+	Validations []codersdk.ValidationError
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, 0, len(e.Validations))
+	for _, validation := range e.Validations {
+		messages = append(messages, fmt.Sprintf("parameter %q: %s", validation.Field, validation.Detail))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validator evaluates the constraints and calls the webhook of the
+// validation rules of templates.
+type Validator struct {
+	// HTTPClient is used to call webhooks. If nil, http.DefaultClient is
+	// used.
+	HTTPClient *http.Client
+	// SigningSecret is used to sign webhook request bodies the same way as
+	// the bodies of workspace event webhooks. If empty, requests are not
+	// signed.
+	SigningSecret string
+	// AllowedHosts are the hosts, with an optional port, that webhooks may be
+	// sent to. Webhooks to other hosts fail.
+	AllowedHosts []string
+}
+
+// Validate returns an *Error if the parameter values of the request don't
+// satisfy the rules. Constraints are evaluated first, the webhook is only
+// called if they are satisfied. Other errors mean that the values couldn't
+// be validated.
+func (v *Validator) Validate(ctx context.Context, rules database.TemplateParameterValidation, req codersdk.ParameterValidationRequest) error {
+	var constraints []codersdk.TemplateParameterConstraint
+	if len(rules.Constraints) > 0 {
+		err := json.Unmarshal(rules.Constraints, &constraints)
+		if err != nil {
+			return xerrors.Errorf("unmarshal constraints: %w", err)
+		}
+	}
+	validations, err := EvaluateConstraints(constraints, req.Parameters)
+	if err != nil {
+		return err
+	}
+	if len(validations) > 0 {
+		return &Error{Validations: validations}
+	}
+	if rules.WebhookURL == "" {
+		return nil
+	}
+
+	if !v.WebhookAllowed(rules.WebhookURL) {
+		return xerrors.Errorf("parameter validation webhook %q is not on an allowed host", rules.WebhookURL)
+	}
+	validations, err = v.callWebhook(ctx, rules.WebhookURL, req)
+	if err != nil {
+		return xerrors.Errorf("call parameter validation webhook: %w", err)
+	}
+	if len(validations) > 0 {
+		return &Error{Validations: validations}
+	}
+	return nil
+}
+
+// WebhookAllowed returns whether the webhook URL is on one of the allowed
+// hosts.
+func (v *Validator) WebhookAllowed(webhookURL string) bool {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return false
+	}
+	for _, host := range v.AllowedHosts {
+		if strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Validator) callWebhook(ctx context.Context, webhookURL string, validationReq codersdk.ParameterValidationRequest) ([]codersdk.ValidationError, error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(validationReq)
+	if err != nil {
+		return nil, xerrors.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, xerrors.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Coder-Parameter-Validation")
+	if v.SigningSecret != "" {
+		req.Header.Set(webhooks.HeaderSignature, webhooks.Sign(v.SigningSecret, body))
+	}
+
+	res, err := v.httpClient().Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	var validationRes codersdk.ParameterValidationResponse
+	err = json.NewDecoder(io.LimitReader(res.Body, 1<<20)).Decode(&validationRes)
+	if err != nil {
+		return nil, xerrors.Errorf("decode response: %w", err)
+	}
+	return validationRes.Validations, nil
+}
+
+// httpClient returns a copy of the HTTP client that doesn't follow redirects
+// to hosts that aren't allowed.
+func (v *Validator) httpClient() *http.Client {
+	client := v.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	restricted := *client
+	restricted.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return xerrors.New("stopped after 10 redirects")
+		}
+		if client.CheckRedirect != nil {
+			err := client.CheckRedirect(req, via)
+			if err != nil {
+				return err
+			}
+		}
+		if !v.WebhookAllowed(req.URL.String()) {
+			return xerrors.Errorf("redirect to %q is not on an allowed host", req.URL.Host)
+		}
+		return nil
+	}
+	return &restricted
+}
+
+// EvaluateConstraints returns a validation error for every constraint the
+// parameter values don't satisfy. Parameters without a value have the value
+// "".
+func EvaluateConstraints(constraints []codersdk.TemplateParameterConstraint, parameters []codersdk.WorkspaceBuildParameter) ([]codersdk.ValidationError, error) {
+	values := make(map[string]string, len(parameters))
+	for _, parameter := range parameters {
+		values[parameter.Name] = parameter.Value
+	}
+
+	var validations []codersdk.ValidationError
+	for _, constraint := range constraints {
+		if constraint.WhenParameter != "" {
+			matched, err := regexp.MatchString(constraint.WhenRegex, values[constraint.WhenParameter])
+			if err != nil {
+				return nil, xerrors.Errorf("match regex of parameter %q: %w", constraint.WhenParameter, err)
+			}
+			if !matched {
+				continue
+			}
+		}
+		matched, err := regexp.MatchString(constraint.Regex, values[constraint.Parameter])
+		if err != nil {
+			return nil, xerrors.Errorf("match regex of parameter %q: %w", constraint.Parameter, err)
+		}
+		if matched {
+			continue
+		}
+		detail := constraint.Error
+		if detail == "" {
+			detail = fmt.Sprintf("value must match %q", constraint.Regex)
+			if constraint.WhenParameter != "" {
+				detail += fmt.Sprintf(" when %q matches %q", constraint.WhenParameter, constraint.WhenRegex)
+			}
+		}
+		validations = append(validations, codersdk.ValidationError{
+			Field:  constraint.Parameter,
+			Detail: detail,
+		})
+	}
+	return validations, nil
+}
+
+// ValidateRules returns a validation error for every invalid field of a
+// request to update the validation rules of a template.
+func ValidateRules(req codersdk.UpdateTemplateParameterValidationRequest) []codersdk.ValidationError {
+	var validations []codersdk.ValidationError
+	if req.WebhookURL != "" {
+		u, err := url.Parse(req.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  "webhook_url",
+				Detail: "Must be an absolute HTTP or HTTPS URL.",
+			})
+		}
+	}
+	for i, constraint := range req.Constraints {
+		field := fmt.Sprintf("constraints[%d]", i)
+		if constraint.Parameter == "" {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".parameter",
+				Detail: "Must be the name of a parameter.",
+			})
+		}
+		if _, err := regexp.Compile(constraint.Regex); err != nil {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".regex",
+				Detail: fmt.Sprintf("Invalid regular expression: %s", err),
+			})
+		}
+		if constraint.WhenParameter == "" && constraint.WhenRegex != "" {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".when_parameter",
+				Detail: "Must be set if when_regex is set.",
+			})
+		}
+		if _, err := regexp.Compile(constraint.WhenRegex); err != nil {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".when_regex",
+				Detail: fmt.Sprintf("Invalid regular expression: %s", err),
+			})
+		}
+	}
+	return validations
+}
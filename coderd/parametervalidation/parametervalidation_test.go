@@ -0,0 +1,204 @@
+package parametervalidation_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/parametervalidation"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestEvaluateConstraints(t *testing.T) {
+	t.Parallel()
+
+	constraints := []codersdk.TemplateParameterConstraint{{
+		Parameter:     "instance_type",
+		Regex:         "^t",
+		WhenParameter: "region",
+		WhenRegex:     "^eu-",
+		Error:         "Only T instances are available in EU regions.",
+	}, {
+		Parameter: "disk_size",
+		Regex:     "^[0-9]+$",
+	}}
+
+	for _, c := range []struct {
+		Name       string
+		Parameters map[string]string
+		Fields     []string
+	}{{
+		Name:       "Satisfied",
+		Parameters: map[string]string{"region": "eu-west-1", "instance_type": "t3.large", "disk_size": "10"},
+	}, {
+		Name:       "ConditionNotMet",
+		Parameters: map[string]string{"region": "us-east-1", "instance_type": "g5.xlarge", "disk_size": "10"},
+	}, {
+		Name:       "Unsatisfied",
+		Parameters: map[string]string{"region": "eu-west-1", "instance_type": "g5.xlarge"},
+		Fields:     []string{"instance_type", "disk_size"},
+	}} {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			var parameters []codersdk.WorkspaceBuildParameter
+			for name, value := range c.Parameters {
+				parameters = append(parameters, codersdk.WorkspaceBuildParameter{Name: name, Value: value})
+			}
+			validations, err := parametervalidation.EvaluateConstraints(constraints, parameters)
+			require.NoError(t, err)
+			var fields []string
+			for _, validation := range validations {
+				fields = append(fields, validation.Field)
+			}
+			require.Equal(t, c.Fields, fields)
+			if len(validations) > 0 {
+				require.Equal(t, "Only T instances are available in EU regions.", validations[0].Detail)
+			}
+		})
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	t.Parallel()
+
+	validations := parametervalidation.ValidateRules(codersdk.UpdateTemplateParameterValidationRequest{
+		WebhookURL: "ftp://example.com",
+		Constraints: []codersdk.TemplateParameterConstraint{{
+			Regex:     "(",
+			WhenRegex: "^eu-",
+		}},
+	})
+	var fields []string
+	for _, validation := range validations {
+		fields = append(fields, validation.Field)
+	}
+	require.Equal(t, []string{
+		"webhook_url",
+		"constraints[0].parameter",
+		"constraints[0].regex",
+		"constraints[0].when_parameter",
+	}, fields)
+
+	require.Empty(t, parametervalidation.ValidateRules(codersdk.UpdateTemplateParameterValidationRequest{
+		WebhookURL: "https://example.com/validate",
+		Constraints: []codersdk.TemplateParameterConstraint{{
+			Parameter: "region",
+			Regex:     "^eu-",
+		}},
+	}))
+}
+
+func TestValidator(t *testing.T) {
+	t.Parallel()
+
+	request := codersdk.ParameterValidationRequest{
+		TemplateID:  uuid.New(),
+		WorkspaceID: uuid.New(),
+		Transition:  codersdk.WorkspaceTransitionStart,
+		Parameters: []codersdk.WorkspaceBuildParameter{
+			{Name: "region", Value: "eu-west-1"},
+		},
+	}
+
+	t.Run("Webhook", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.True(t, webhooks.Verify("secret", body, r.Header.Get(webhooks.HeaderSignature)))
+			var req codersdk.ParameterValidationRequest
+			assert.NoError(t, json.Unmarshal(body, &req))
+			assert.Equal(t, request, req)
+			_ = json.NewEncoder(rw).Encode(codersdk.ParameterValidationResponse{
+				Validations: []codersdk.ValidationError{{Field: "region", Detail: "Region is at capacity."}},
+			})
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		validator := &parametervalidation.Validator{SigningSecret: "secret", AllowedHosts: []string{srv.Listener.Addr().String()}}
+		err := validator.Validate(ctx, database.TemplateParameterValidation{WebhookURL: srv.URL}, request)
+		var validationErr *parametervalidation.Error
+		require.ErrorAs(t, err, &validationErr)
+		require.Equal(t, []codersdk.ValidationError{{Field: "region", Detail: "Region is at capacity."}}, validationErr.Validations)
+	})
+
+	t.Run("ConstraintsBeforeWebhook", func(t *testing.T) {
+		t.Parallel()
+		var called atomic.Bool
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			called.Store(true)
+			_ = json.NewEncoder(rw).Encode(codersdk.ParameterValidationResponse{})
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		err := (&parametervalidation.Validator{AllowedHosts: []string{srv.Listener.Addr().String()}}).Validate(ctx, database.TemplateParameterValidation{
+			WebhookURL:  srv.URL,
+			Constraints: json.RawMessage(`[{"parameter":"region","regex":"^us-"}]`),
+		}, request)
+		var validationErr *parametervalidation.Error
+		require.ErrorAs(t, err, &validationErr)
+		require.False(t, called.Load())
+	})
+
+	t.Run("WebhookFailed", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			rw.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		err := (&parametervalidation.Validator{AllowedHosts: []string{srv.Listener.Addr().String()}}).Validate(ctx, database.TemplateParameterValidation{WebhookURL: srv.URL}, request)
+		require.ErrorContains(t, err, "unexpected status code 500")
+		var validationErr *parametervalidation.Error
+		require.False(t, errors.As(err, &validationErr))
+	})
+
+	t.Run("NotAllowed", func(t *testing.T) {
+		t.Parallel()
+		var called atomic.Bool
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			called.Store(true)
+			_ = json.NewEncoder(rw).Encode(codersdk.ParameterValidationResponse{})
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		err := (&parametervalidation.Validator{AllowedHosts: []string{"example.com"}}).Validate(ctx, database.TemplateParameterValidation{WebhookURL: srv.URL}, request)
+		require.ErrorContains(t, err, "is not on an allowed host")
+		require.False(t, called.Load())
+	})
+
+	t.Run("RedirectNotAllowed", func(t *testing.T) {
+		t.Parallel()
+		var called atomic.Bool
+		target := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			called.Store(true)
+			_ = json.NewEncoder(rw).Encode(codersdk.ParameterValidationResponse{})
+		}))
+		t.Cleanup(target.Close)
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			http.Redirect(rw, r, target.URL, http.StatusTemporaryRedirect)
+		}))
+		t.Cleanup(srv.Close)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		err := (&parametervalidation.Validator{AllowedHosts: []string{srv.Listener.Addr().String()}}).Validate(ctx, database.TemplateParameterValidation{WebhookURL: srv.URL}, request)
+		require.ErrorContains(t, err, "is not on an allowed host")
+		require.False(t, called.Load())
+	})
+}
@@ -0,0 +1,98 @@
+package prebuilds
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/codersdk"
+)
+
+// ErrNoPrebuiltWorkspace is returned by Claim if no prebuilt workspace of the
+// template can be assigned to the user.
+var ErrNoPrebuiltWorkspace = xerrors.New("no prebuilt workspace is ready")
+
+// ClaimParams describe the workspace a user creates.
+type ClaimParams struct {
+	OwnerID           uuid.UUID
+	Name              string
+	AutostartSchedule sql.NullString
+	TTL               sql.NullInt64
+	// RichParameterValues are the parameter values provided by the user.
+	RichParameterValues []codersdk.WorkspaceBuildParameter
+	Now                 time.Time
+}
+
+// Claimer assigns prebuilt workspaces to the users that create workspaces.
+type Claimer struct {
+	claimed *prometheus.CounterVec
+}
+
+// NewClaimer returns a Claimer that registers its metrics with reg.
+func NewClaimer(reg prometheus.Registerer) *Claimer {
+	return &Claimer{
+		claimed: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "prebuilds",
+			Name:      "claimed_total",
+			Help:      "The number of prebuilt workspaces assigned to users.",
+		}, []string{"template_name"}),
+	}
+}
+
+// Claim assigns a ready prebuilt workspace of the active version of the
+// template to the user. The caller has to start the workspace. It returns
+// ErrNoPrebuiltWorkspace if there is none, or if the user provided a value
+// for an immutable parameter that differs from the default the prebuilt
+// workspaces were built with.
+//
+// Claim should be called in the transaction that starts the workspace, and
+// Claimed once it has been committed.
+func (*Claimer) Claim(ctx context.Context, db database.Store, template database.Template, params ClaimParams) (database.Workspace, error) {
+	parameters, err := db.GetTemplateVersionParameters(ctx, template.ActiveVersionID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return database.Workspace{}, xerrors.Errorf("get template version parameters: %w", err)
+	}
+	for _, parameter := range parameters {
+		if parameter.Mutable {
+			continue
+		}
+		for _, value := range params.RichParameterValues {
+			if value.Name == parameter.Name && value.Value != parameter.DefaultValue {
+				return database.Workspace{}, ErrNoPrebuiltWorkspace
+			}
+		}
+	}
+
+	//nolint:gocritic // Prebuilt workspaces are owned by the prebuilds user until they are claimed.
+	workspace, err := db.ClaimPrebuiltWorkspace(dbauthz.AsPrebuildsController(ctx), database.ClaimPrebuiltWorkspaceParams{
+		NewOwnerID:        params.OwnerID,
+		Name:              params.Name,
+		AutostartSchedule: params.AutostartSchedule,
+		Ttl:               params.TTL,
+		Now:               params.Now,
+		PrebuildsOwnerID:  OwnerID,
+		TemplateID:        template.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Workspace{}, ErrNoPrebuiltWorkspace
+	}
+	if err != nil {
+		return database.Workspace{}, xerrors.Errorf("claim prebuilt workspace: %w", err)
+	}
+	return workspace, nil
+}
+
+// Claimed records that a prebuilt workspace of the template was assigned to
+// a user.
+func (c *Claimer) Claimed(template database.Template) {
+	c.claimed.WithLabelValues(template.Name).Inc()
+}
@@ -0,0 +1,483 @@
+// Package prebuilds keeps pools of prebuilt workspaces for templates.
+//
+// Prebuilt workspaces are workspaces of the active version of a template that
+// have been built stopped, so their persistent resources are provisioned but
+// nothing is running. They are owned by a system user until a user creates a
+// workspace from the template, at which point one of them is assigned to the
+// user and only the start build has to run.
+package prebuilds
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/wsbuilder"
+	"github.com/coder/coder/cryptorand"
+)
+
+const (
+	// MaxPoolSize is the maximum number of prebuilt workspaces a template can
+	// keep.
+	MaxPoolSize = 50
+
+	// MaxActionsPerRun is the maximum number of prebuilt workspaces the
+	// controller creates or deletes for a template in a single run. This
+	// avoids flooding the provisioners when a pool is resized or a new
+	// version of a template is promoted.
+	MaxActionsPerRun = 5
+
+	// FailedBuildBackoff is how long a prebuilt workspace that failed to
+	// build is kept before it is deleted. No prebuilt workspaces are created
+	// for the template in the meantime, so templates that can't be prebuilt
+	// don't keep the provisioners busy.
+	FailedBuildBackoff = 10 * time.Minute
+
+	// ownerUsername isn't a valid username, so it's reserved for the owner
+	// of prebuilt workspaces and can't be taken by another user.
+	ownerUsername = "coder_prebuilds"
+	ownerEmail    = "prebuilds@coder.internal"
+)
+
+// OwnerID is the ID of the user that owns prebuilt workspaces until they are
+// claimed. The user is created suspended by the controller when the first
+// pool is reconciled.
+var OwnerID = uuid.MustParse("c42fdf75-3097-471c-8c33-fb52454d81c0")
+
+// acquireLockError is returned when the controller fails to acquire the lock
+// of a template, because another replica is reconciling its pool.
+type acquireLockError struct{}
+
+// Error implements error.
+func (acquireLockError) Error() string {
+	return "lock is held by another client"
+}
+
+// Controller creates and deletes prebuilt workspaces so that every template
+// keeps the number of prebuilt workspaces its pool asks for.
+type Controller struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	db      database.Store
+	log     slog.Logger
+	tick    <-chan time.Time
+	stats   chan<- Stats
+	metrics Metrics
+
+	// createFailedAt is when a prebuilt workspace of each template last
+	// failed to be created. The failed workspace is rolled back, so unlike
+	// failed builds it isn't in the database. It is only accessed by the
+	// goroutine started by Start.
+	createFailedAt map[uuid.UUID]time.Time
+}
+
+// Stats contains statistics about the last run of the controller.
+type Stats struct {
+	// CreatedWorkspaceIDs contains the IDs of the prebuilt workspaces that
+	// were created.
+	CreatedWorkspaceIDs []uuid.UUID
+	// DeletedWorkspaceIDs contains the IDs of the prebuilt workspaces that a
+	// delete build was started for.
+	DeletedWorkspaceIDs []uuid.UUID
+	// Error is the fatal error that occurred during the last run of the
+	// controller, if any.
+	Error error
+}
+
+// New returns a new prebuilds controller.
+func New(ctx context.Context, db database.Store, log slog.Logger, tick <-chan time.Time) *Controller {
+	//nolint:gocritic // Prebuilds controller has a limited set of permissions.
+	ctx, cancel := context.WithCancel(dbauthz.AsPrebuildsController(ctx))
+	return &Controller{
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		db:      db,
+		log:     log,
+		tick:    tick,
+		metrics: NewMetrics(prometheus.NewRegistry()),
+
+		createFailedAt: make(map[uuid.UUID]time.Time),
+	}
+}
+
+// WithStatsChannel will cause Controller to push a Stats to ch after every
+// tick. This push is blocking, so if ch is not read, the controller will
+// hang. This should only be used in tests.
+func (c *Controller) WithStatsChannel(ch chan<- Stats) *Controller {
+	c.stats = ch
+	return c
+}
+
+// WithMetrics will cause Controller to register its metrics with reg.
+func (c *Controller) WithMetrics(reg prometheus.Registerer) *Controller {
+	c.metrics = NewMetrics(reg)
+	return c
+}
+
+// Start will cause the controller to reconcile the pools of all templates on
+// every tick from its channel. It will stop when its context is Done, or when
+// its channel is closed.
+//
+// Start should only be called once.
+func (c *Controller) Start() {
+	go func() {
+		defer close(c.done)
+		defer c.cancel()
+
+		for {
+			select {
+			case <-c.ctx.Done():
+				return
+			case t, ok := <-c.tick:
+				if !ok {
+					return
+				}
+				stats := c.run(t)
+				if stats.Error != nil {
+					c.log.Warn(c.ctx, "error running prebuilds controller once", slog.Error(stats.Error))
+				}
+				if c.stats != nil {
+					select {
+					case <-c.ctx.Done():
+						return
+					case c.stats <- stats:
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Wait will block until the controller is stopped.
+func (c *Controller) Wait() {
+	<-c.done
+}
+
+// Close will stop the controller.
+func (c *Controller) Close() {
+	c.cancel()
+	<-c.done
+}
+
+func (c *Controller) run(t time.Time) Stats {
+	ctx, cancel := context.WithTimeout(c.ctx, 5*time.Minute)
+	defer cancel()
+
+	stats := Stats{
+		CreatedWorkspaceIDs: []uuid.UUID{},
+		DeletedWorkspaceIDs: []uuid.UUID{},
+		Error:               nil,
+	}
+
+	pools, err := c.db.GetTemplatePrebuildPools(ctx)
+	if err != nil {
+		stats.Error = xerrors.Errorf("get template prebuild pools: %w", err)
+		return stats
+	}
+	workspaces, err := c.db.GetPrebuiltWorkspaces(ctx, OwnerID)
+	if err != nil {
+		stats.Error = xerrors.Errorf("get prebuilt workspaces: %w", err)
+		return stats
+	}
+
+	// Templates that don't keep prebuilt workspaces anymore are reconciled
+	// as well, so their prebuilt workspaces are deleted.
+	templateIDs := make([]uuid.UUID, 0, len(pools))
+	seen := make(map[uuid.UUID]bool)
+	for _, pool := range pools {
+		if !seen[pool.TemplateID] {
+			seen[pool.TemplateID] = true
+			templateIDs = append(templateIDs, pool.TemplateID)
+		}
+	}
+	for _, workspace := range workspaces {
+		if !seen[workspace.TemplateID] {
+			seen[workspace.TemplateID] = true
+			templateIDs = append(templateIDs, workspace.TemplateID)
+		}
+	}
+
+	if len(pools) > 0 {
+		err = ensureOwner(ctx, c.db)
+		if err != nil {
+			stats.Error = xerrors.Errorf("ensure prebuilds owner: %w", err)
+			return stats
+		}
+	}
+
+	for _, templateID := range templateIDs {
+		log := c.log.With(slog.F("template_id", templateID))
+
+		for i := 0; i < MaxActionsPerRun; i++ {
+			act, err := c.reconcile(ctx, t, templateID)
+			if err != nil {
+				if !xerrors.As(err, &acquireLockError{}) {
+					log.Error(ctx, "error reconciling prebuilt workspaces", slog.Error(err))
+				}
+				break
+			}
+			if act.workspaceID == uuid.Nil {
+				break
+			}
+
+			log.Info(ctx, "reconciled prebuilt workspace",
+				slog.F("workspace_id", act.workspaceID),
+				slog.F("transition", act.transition),
+			)
+			switch act.transition {
+			case database.WorkspaceTransitionStop:
+				stats.CreatedWorkspaceIDs = append(stats.CreatedWorkspaceIDs, act.workspaceID)
+				c.metrics.Created.WithLabelValues(act.templateName).Inc()
+			case database.WorkspaceTransitionDelete:
+				stats.DeletedWorkspaceIDs = append(stats.DeletedWorkspaceIDs, act.workspaceID)
+				c.metrics.Deleted.WithLabelValues(act.templateName).Inc()
+				if act.failed {
+					c.metrics.Failed.WithLabelValues(act.templateName).Inc()
+				}
+			}
+		}
+	}
+
+	return stats
+}
+
+// PoolStatus groups the prebuilt workspaces of a template by the state of
+// their latest build. Workspaces that are being deleted, or failed to be
+// deleted, aren't part of the pool.
+type PoolStatus struct {
+	// Ready are the workspaces that can be claimed, oldest first.
+	Ready []uuid.UUID
+	// Building are the workspaces whose build hasn't completed yet.
+	Building []uuid.UUID
+	// Outdated are the workspaces built on another version than the active
+	// version of the template.
+	Outdated []uuid.UUID
+	// Failed are the workspaces that failed to build more than
+	// FailedBuildBackoff ago.
+	Failed []uuid.UUID
+	// Backoff is set if a workspace failed to build within the last
+	// FailedBuildBackoff.
+	Backoff bool
+}
+
+// NewPoolStatus returns the status of the prebuilt workspaces of the template.
+// workspaces may contain the prebuilt workspaces of other templates.
+func NewPoolStatus(template database.Template, workspaces []database.GetPrebuiltWorkspacesRow, now time.Time) PoolStatus {
+	var status PoolStatus
+	for _, workspace := range workspaces {
+		if workspace.TemplateID != template.ID {
+			continue
+		}
+		switch {
+		case workspace.Transition == database.WorkspaceTransitionDelete:
+			// Workspaces that failed to be deleted are left for admins to
+			// investigate.
+		case !workspace.CompletedAt.Valid:
+			status.Building = append(status.Building, workspace.ID)
+		case workspace.TemplateVersionID != template.ActiveVersionID:
+			status.Outdated = append(status.Outdated, workspace.ID)
+		case workspace.CanceledAt.Valid || workspace.Error.Valid:
+			if now.Sub(workspace.CompletedAt.Time) < FailedBuildBackoff {
+				status.Backoff = true
+				continue
+			}
+			status.Failed = append(status.Failed, workspace.ID)
+		default:
+			status.Ready = append(status.Ready, workspace.ID)
+		}
+	}
+	return status
+}
+
+// action is the change reconcile made to the prebuilt workspaces of a
+// template. The zero value means that the pool is reconciled.
+type action struct {
+	templateName string
+	workspaceID  uuid.UUID
+	// transition is stop for created and delete for deleted workspaces.
+	transition database.WorkspaceTransition
+	// failed is set if the workspace was deleted because it failed to build.
+	failed bool
+}
+
+// reconcile creates or deletes at most one prebuilt workspace of the template
+// to move its pool towards the desired size. Every call runs in its own
+// transaction, so a workspace that can't be built doesn't roll back the
+// changes made to the others.
+func (c *Controller) reconcile(ctx context.Context, now time.Time, templateID uuid.UUID) (action, error) {
+	var act action
+	err := c.db.InTx(func(db database.Store) error {
+		act = action{}
+
+		locked, err := db.TryAcquireLock(ctx, database.GenLockID(fmt.Sprintf("prebuilds:%s", templateID)))
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !locked {
+			// This error is ignored.
+			return acquireLockError{}
+		}
+
+		// Refetch the pool and its workspaces while we hold the lock.
+		template, err := db.GetTemplateByID(ctx, templateID)
+		if err != nil {
+			return xerrors.Errorf("get template: %w", err)
+		}
+		act.templateName = template.Name
+
+		var desired int
+		if !template.Deleted {
+			pool, err := db.GetTemplatePrebuildPool(ctx, templateID)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("get template prebuild pool: %w", err)
+			}
+			desired = int(pool.Size)
+		}
+		workspaces, err := db.GetPrebuiltWorkspaces(ctx, OwnerID)
+		if err != nil {
+			return xerrors.Errorf("get prebuilt workspaces: %w", err)
+		}
+
+		status := NewPoolStatus(template, workspaces, now)
+		c.metrics.Desired.WithLabelValues(template.Name).Set(float64(desired))
+		c.metrics.Ready.WithLabelValues(template.Name).Set(float64(len(status.Ready)))
+		c.metrics.Building.WithLabelValues(template.Name).Set(float64(len(status.Building)))
+
+		current := len(status.Ready) + len(status.Building)
+		switch {
+		case len(status.Outdated) > 0:
+			act.workspaceID, act.transition = status.Outdated[0], database.WorkspaceTransitionDelete
+			return deleteWorkspace(ctx, db, act.workspaceID)
+		case len(status.Failed) > 0:
+			act.workspaceID, act.transition, act.failed = status.Failed[0], database.WorkspaceTransitionDelete, true
+			return deleteWorkspace(ctx, db, act.workspaceID)
+		case len(status.Ready) > 0 && current > desired:
+			// Workspaces are claimed oldest first, so the newest one is
+			// deleted.
+			act.workspaceID, act.transition = status.Ready[len(status.Ready)-1], database.WorkspaceTransitionDelete
+			return deleteWorkspace(ctx, db, act.workspaceID)
+		case !status.Backoff && now.Sub(c.createFailedAt[templateID]) >= FailedBuildBackoff && current < desired:
+			act.workspaceID, act.transition = uuid.New(), database.WorkspaceTransitionStop
+			return createWorkspace(ctx, db, template, act.workspaceID)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		if act.transition == database.WorkspaceTransitionStop {
+			// The workspace was rolled back with the transaction, so back
+			// off the same way as if its build had failed.
+			c.createFailedAt[templateID] = now
+			c.metrics.Failed.WithLabelValues(act.templateName).Inc()
+		}
+		return action{}, err
+	}
+	if act.transition == database.WorkspaceTransitionStop {
+		delete(c.createFailedAt, templateID)
+	}
+	return act, nil
+}
+
+// createWorkspace creates a prebuilt workspace of the active version of the
+// template. It is built stopped, so only its persistent resources are
+// provisioned.
+func createWorkspace(ctx context.Context, db database.Store, template database.Template, id uuid.UUID) error {
+	suffix, err := cryptorand.HexString(8)
+	if err != nil {
+		return xerrors.Errorf("generate workspace name: %w", err)
+	}
+
+	now := database.Now()
+	workspace, err := db.InsertWorkspace(ctx, database.InsertWorkspaceParams{
+		ID:             id,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		OwnerID:        OwnerID,
+		OrganizationID: template.OrganizationID,
+		TemplateID:     template.ID,
+		Name:           "prebuild-" + suffix,
+		LastUsedAt:     now,
+	})
+	if err != nil {
+		return xerrors.Errorf("insert workspace: %w", err)
+	}
+
+	builder := wsbuilder.New(workspace, database.WorkspaceTransitionStop).
+		ActiveVersion().
+		Initiator(OwnerID).
+		Reason(database.BuildReasonPrebuild)
+	_, _, err = builder.Build(ctx, db, nil)
+	if err != nil {
+		return xerrors.Errorf("build workspace: %w", err)
+	}
+	return nil
+}
+
+// deleteWorkspace starts a delete build for the prebuilt workspace.
+func deleteWorkspace(ctx context.Context, db database.Store, id uuid.UUID) error {
+	workspace, err := db.GetWorkspaceByID(ctx, id)
+	if err != nil {
+		return xerrors.Errorf("get workspace: %w", err)
+	}
+
+	builder := wsbuilder.New(workspace, database.WorkspaceTransitionDelete).
+		Initiator(OwnerID).
+		Reason(database.BuildReasonPrebuild)
+	_, _, err = builder.Build(ctx, db, nil)
+	if err != nil {
+		return xerrors.Errorf("build workspace: %w", err)
+	}
+	return nil
+}
+
+// ensureOwner creates the user that owns prebuilt workspaces if it doesn't
+// exist yet. The user is suspended, so it can't log in and doesn't count
+// towards the active users.
+func ensureOwner(ctx context.Context, db database.Store) error {
+	//nolint:gocritic // The prebuilds controller can't assign roles to users.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+
+	_, err := db.GetUserByID(ctx, OwnerID)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("get user: %w", err)
+	}
+
+	return db.InTx(func(db database.Store) error {
+		now := database.Now()
+		_, err := db.InsertUser(ctx, database.InsertUserParams{
+			ID:        OwnerID,
+			Email:     ownerEmail,
+			Username:  ownerUsername,
+			CreatedAt: now,
+			UpdatedAt: now,
+			RBACRoles: []string{},
+			LoginType: database.LoginTypeNone,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert user: %w", err)
+		}
+		_, err = db.UpdateUserStatus(ctx, database.UpdateUserStatusParams{
+			ID:        OwnerID,
+			Status:    database.UserStatusSuspended,
+			UpdatedAt: now,
+		})
+		if err != nil {
+			return xerrors.Errorf("suspend user: %w", err)
+		}
+		return nil
+	}, nil)
+}
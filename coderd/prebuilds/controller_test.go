@@ -0,0 +1,214 @@
+package prebuilds_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/prebuilds"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestController(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = testutil.Context(t, testutil.WaitLong)
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan prebuilds.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+			PrebuildsTicker:          tickCh,
+			PrebuildsStats:           statsCh,
+		})
+		owner    = coderdtest.CreateFirstUser(t, client)
+		version  = coderdtest.CreateTemplateVersion(t, client, owner.OrganizationID, &echo.Responses{Parse: echo.ParseComplete, ProvisionApply: echo.ProvisionComplete})
+		_        = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template = coderdtest.CreateTemplate(t, client, owner.OrganizationID, version.ID)
+	)
+	tick := func() prebuilds.Stats {
+		tickCh <- time.Now()
+		stats := <-statsCh
+		require.NoError(t, stats.Error)
+		return stats
+	}
+	awaitBuilds := func(ids []uuid.UUID) {
+		for _, id := range ids {
+			workspace, err := client.Workspace(ctx, id)
+			require.NoError(t, err)
+			coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		}
+	}
+
+	// Templates don't keep prebuilt workspaces by default.
+	stats := tick()
+	require.Empty(t, stats.CreatedWorkspaceIDs)
+
+	_, err := client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Size: 2})
+	require.NoError(t, err)
+	stats = tick()
+	require.Len(t, stats.CreatedWorkspaceIDs, 2)
+	awaitBuilds(stats.CreatedWorkspaceIDs)
+	prebuilt := stats.CreatedWorkspaceIDs
+
+	pool, err := client.TemplatePrebuilds(ctx, template.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, pool.Size)
+	require.EqualValues(t, 2, pool.Ready)
+	require.EqualValues(t, 0, pool.Building)
+
+	workspace, err := client.Workspace(ctx, prebuilt[0])
+	require.NoError(t, err)
+	require.Equal(t, prebuilds.OwnerID, workspace.OwnerID)
+	require.Equal(t, codersdk.WorkspaceTransitionStop, workspace.LatestBuild.Transition)
+	require.Equal(t, codersdk.BuildReasonPrebuild, workspace.LatestBuild.Reason)
+
+	// The oldest prebuilt workspace is assigned to the next user that creates
+	// a workspace from the template.
+	memberClient, member := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+	workspace = coderdtest.CreateWorkspace(t, memberClient, owner.OrganizationID, template.ID)
+	require.Contains(t, prebuilt, workspace.ID)
+	require.Equal(t, member.ID, workspace.OwnerID)
+	require.Equal(t, codersdk.WorkspaceTransitionStart, workspace.LatestBuild.Transition)
+	require.EqualValues(t, 2, workspace.LatestBuild.BuildNumber)
+	coderdtest.AwaitWorkspaceBuildJob(t, memberClient, workspace.LatestBuild.ID)
+
+	// The pool is refilled.
+	stats = tick()
+	require.Len(t, stats.CreatedWorkspaceIDs, 1)
+	awaitBuilds(stats.CreatedWorkspaceIDs)
+
+	// Prebuilt workspaces are deleted once the pool is disabled, but
+	// claimed workspaces are kept.
+	_, err = client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Size: 0})
+	require.NoError(t, err)
+	stats = tick()
+	require.Len(t, stats.DeletedWorkspaceIDs, 2)
+	require.NotContains(t, stats.DeletedWorkspaceIDs, workspace.ID)
+	awaitBuilds(stats.DeletedWorkspaceIDs)
+
+	stats = tick()
+	require.Empty(t, stats.CreatedWorkspaceIDs)
+	require.Empty(t, stats.DeletedWorkspaceIDs)
+}
+
+func TestControllerOutdated(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = testutil.Context(t, testutil.WaitLong)
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan prebuilds.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+			PrebuildsTicker:          tickCh,
+			PrebuildsStats:           statsCh,
+		})
+		owner    = coderdtest.CreateFirstUser(t, client)
+		version  = coderdtest.CreateTemplateVersion(t, client, owner.OrganizationID, &echo.Responses{Parse: echo.ParseComplete, ProvisionApply: echo.ProvisionComplete})
+		_        = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template = coderdtest.CreateTemplate(t, client, owner.OrganizationID, version.ID)
+	)
+	_, err := client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Size: 1})
+	require.NoError(t, err)
+
+	tickCh <- time.Now()
+	stats := <-statsCh
+	require.NoError(t, stats.Error)
+	require.Len(t, stats.CreatedWorkspaceIDs, 1)
+	workspace, err := client.Workspace(ctx, stats.CreatedWorkspaceIDs[0])
+	require.NoError(t, err)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	// Prebuilt workspaces of previous versions are replaced once a new
+	// version is promoted.
+	version = coderdtest.UpdateTemplateVersion(t, client, owner.OrganizationID, &echo.Responses{Parse: echo.ParseComplete, ProvisionApply: echo.ProvisionComplete}, template.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	err = client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{ID: version.ID})
+	require.NoError(t, err)
+
+	tickCh <- time.Now()
+	stats = <-statsCh
+	require.NoError(t, stats.Error)
+	require.Equal(t, []uuid.UUID{workspace.ID}, stats.DeletedWorkspaceIDs)
+	require.Len(t, stats.CreatedWorkspaceIDs, 1)
+	workspace, err = client.Workspace(ctx, stats.CreatedWorkspaceIDs[0])
+	require.NoError(t, err)
+	require.Equal(t, version.ID, workspace.LatestBuild.TemplateVersionID)
+}
+
+func TestControllerCreateFailed(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx      = testutil.Context(t, testutil.WaitLong)
+		tickCh   = make(chan time.Time)
+		statsCh  = make(chan prebuilds.Stats)
+		registry = prometheus.NewRegistry()
+		client   = coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+			PrebuildsTicker:          tickCh,
+			PrebuildsStats:           statsCh,
+			PrebuildsMetrics:         registry,
+		})
+		owner = coderdtest.CreateFirstUser(t, client)
+		// Prebuilt workspaces can't be created without a value for the
+		// required parameter.
+		version = coderdtest.CreateTemplateVersion(t, client, owner.OrganizationID, &echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionPlan: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Parameters: []*proto.RichParameter{{
+							Name:     "region",
+							Type:     "string",
+							Required: true,
+						}},
+					},
+				},
+			}},
+			ProvisionApply: echo.ProvisionComplete,
+		})
+		_        = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template = coderdtest.CreateTemplate(t, client, owner.OrganizationID, version.ID)
+	)
+	_, err := client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Size: 2})
+	require.NoError(t, err)
+
+	tick := func(now time.Time) float64 {
+		tickCh <- now
+		stats := <-statsCh
+		require.NoError(t, stats.Error)
+		require.Empty(t, stats.CreatedWorkspaceIDs)
+
+		metrics, err := registry.Gather()
+		require.NoError(t, err)
+		for _, metric := range metrics {
+			if metric.GetName() == "coderd_prebuilds_failed_total" {
+				return metric.Metric[0].Counter.GetValue()
+			}
+		}
+		return 0
+	}
+
+	// The failure is recorded, even though the workspace is rolled back.
+	now := time.Now()
+	require.EqualValues(t, 1, tick(now))
+
+	// No prebuilt workspaces are created for the template until the backoff
+	// has passed.
+	require.EqualValues(t, 1, tick(now.Add(time.Minute)))
+	require.EqualValues(t, 2, tick(now.Add(prebuilds.FailedBuildBackoff)))
+}
@@ -0,0 +1,62 @@
+package prebuilds
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Metrics are the Prometheus metrics of the Controller. They allow operators
+// to alert when pools stay empty, e.g. because the prebuilt workspaces of a
+// template keep failing to build.
+type Metrics struct {
+	Desired  *prometheus.GaugeVec
+	Ready    *prometheus.GaugeVec
+	Building *prometheus.GaugeVec
+	Created  *prometheus.CounterVec
+	Deleted  *prometheus.CounterVec
+	Failed   *prometheus.CounterVec
+}
+
+// NewMetrics registers the metrics of the Controller with reg.
+func NewMetrics(reg prometheus.Registerer) Metrics {
+	auto := promauto.With(reg)
+
+	return Metrics{
+		Desired: auto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "coderd",
+			Subsystem: "prebuilds",
+			Name:      "desired",
+			Help:      "The number of prebuilt workspaces the pool of a template asks for.",
+		}, []string{"template_name"}),
+		Ready: auto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "coderd",
+			Subsystem: "prebuilds",
+			Name:      "ready",
+			Help:      "The number of prebuilt workspaces of a template that can be claimed.",
+		}, []string{"template_name"}),
+		Building: auto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "coderd",
+			Subsystem: "prebuilds",
+			Name:      "building",
+			Help:      "The number of prebuilt workspaces of a template that are being built.",
+		}, []string{"template_name"}),
+		Created: auto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "prebuilds",
+			Name:      "created_total",
+			Help:      "The number of prebuilt workspaces created.",
+		}, []string{"template_name"}),
+		Deleted: auto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "prebuilds",
+			Name:      "deleted_total",
+			Help:      "The number of prebuilt workspaces deleted because they were outdated, failed or in excess.",
+		}, []string{"template_name"}),
+		Failed: auto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "prebuilds",
+			Name:      "failed_total",
+			Help:      "The number of prebuilt workspaces that failed to be created or built.",
+		}, []string{"template_name"}),
+	}
+}
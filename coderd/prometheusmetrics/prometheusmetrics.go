@@ -7,17 +7,20 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
+	"tailscale.com/derp"
 	"tailscale.com/tailcfg"
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/db2sdk"
 	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/healthcheck"
 	"github.com/coder/coder/tailnet"
 )
 
@@ -500,3 +503,182 @@ func AgentStats(ctx context.Context, logger slog.Logger, registerer prometheus.R
 		<-done
 	}, nil
 }
+
+// DERP tracks the statistics of the embedded DERP server and periodically
+// probes the regions of the DERP map for their health and latency.
+func DERP(ctx context.Context, logger slog.Logger, registerer prometheus.Registerer, server *derp.Server, derpMapFn func() *tailcfg.DERPMap, duration time.Duration) (func(), error) {
+	if duration == 0 {
+		duration = 1 * time.Minute
+	}
+
+	serverStats := []struct {
+		name    string
+		help    string
+		counter bool
+		value   func(stats tailnet.DERPServerStats) int64
+	}{
+		{
+			name:    "current_connections",
+			help:    "The number of clients connected to the embedded DERP server.",
+			counter: false,
+			value:   func(s tailnet.DERPServerStats) int64 { return s.CurrentConnections },
+		},
+		{
+			name:    "current_home_connections",
+			help:    "The number of connected clients that use the embedded DERP server as their preferred DERP server.",
+			counter: false,
+			value:   func(s tailnet.DERPServerStats) int64 { return s.CurrentHomeConnections },
+		},
+		{
+			name:    "accepts_total",
+			help:    "The total number of connections accepted by the embedded DERP server.",
+			counter: true,
+			value:   func(s tailnet.DERPServerStats) int64 { return s.Accepts },
+		},
+		{
+			name:    "bytes_received_total",
+			help:    "The total number of bytes received by the embedded DERP server.",
+			counter: true,
+			value:   func(s tailnet.DERPServerStats) int64 { return s.BytesReceived },
+		},
+		{
+			name:    "bytes_sent_total",
+			help:    "The total number of bytes relayed by the embedded DERP server.",
+			counter: true,
+			value:   func(s tailnet.DERPServerStats) int64 { return s.BytesSent },
+		},
+		{
+			name:    "packets_received_total",
+			help:    "The total number of packets received by the embedded DERP server.",
+			counter: true,
+			value:   func(s tailnet.DERPServerStats) int64 { return s.PacketsReceived },
+		},
+		{
+			name:    "packets_sent_total",
+			help:    "The total number of packets relayed by the embedded DERP server.",
+			counter: true,
+			value:   func(s tailnet.DERPServerStats) int64 { return s.PacketsSent },
+		},
+		{
+			name:    "packets_dropped_total",
+			help:    "The total number of packets dropped by the embedded DERP server.",
+			counter: true,
+			value:   func(s tailnet.DERPServerStats) int64 { return s.PacketsDropped },
+		},
+	}
+	for _, stat := range serverStats {
+		stat := stat
+		opts := prometheus.Opts{
+			Namespace: "coderd",
+			Subsystem: "derp_server",
+			Name:      stat.name,
+			Help:      stat.help,
+		}
+		value := func() float64 {
+			return float64(stat.value(tailnet.ReadDERPServerStats(server)))
+		}
+		var collector prometheus.Collector
+		if stat.counter {
+			collector = prometheus.NewCounterFunc(prometheus.CounterOpts(opts), value)
+		} else {
+			collector = prometheus.NewGaugeFunc(prometheus.GaugeOpts(opts), value)
+		}
+		err := registerer.Register(collector)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	regionHealthyGauge := NewCachedGaugeVec(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "coderd",
+		Subsystem: "derp_region",
+		Name:      "healthy",
+		Help:      "Whether the nodes of the DERP region can relay messages, 1 if healthy and 0 if not.",
+	}, []string{"region_id", "region_code"}))
+	err := registerer.Register(regionHealthyGauge)
+	if err != nil {
+		return nil, err
+	}
+
+	nodeLatencyGauge := NewCachedGaugeVec(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "coderd",
+		Subsystem: "derp_region",
+		Name:      "node_latency_seconds",
+		Help:      "The round trip latency of relaying a message through the DERP node in seconds.",
+	}, []string{"region_id", "region_code", "node"}))
+	err = registerer.Register(nodeLatencyGauge)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	// Use time.Nanosecond to force an initial tick. It will be reset to the
+	// correct duration after executing once.
+	ticker := time.NewTicker(time.Nanosecond)
+	doTick := func() {
+		defer ticker.Reset(duration)
+
+		derpMap := derpMapFn()
+		if derpMap == nil {
+			return
+		}
+
+		// Regions that don't respond before the next tick are reported as
+		// unhealthy instead of blocking the probes.
+		probeCtx, probeCancel := context.WithTimeout(ctx, duration)
+		defer probeCancel()
+
+		logger.Debug(ctx, "probe derp regions", slog.F("regions", len(derpMap.Regions)))
+		var wg sync.WaitGroup
+		reports := make([]*healthcheck.DERPRegionReport, 0, len(derpMap.Regions))
+		for _, region := range derpMap.Regions {
+			report := &healthcheck.DERPRegionReport{Region: region}
+			reports = append(reports, report)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				report.Run(probeCtx)
+			}()
+		}
+		wg.Wait()
+		if ctx.Err() != nil {
+			return
+		}
+
+		for _, report := range reports {
+			regionID := strconv.Itoa(report.Region.RegionID)
+			healthy := 0.0
+			if report.Healthy {
+				healthy = 1
+			}
+			regionHealthyGauge.WithLabelValues(VectorOperationSet, healthy, regionID, report.Region.RegionCode)
+			for _, node := range report.NodeReports {
+				if !node.CanExchangeMessages {
+					continue
+				}
+				nodeLatencyGauge.WithLabelValues(VectorOperationSet, float64(node.RoundTripPingMs)/1000, regionID, report.Region.RegionCode, node.Node.Name)
+			}
+		}
+		regionHealthyGauge.Commit()
+		nodeLatencyGauge.Commit()
+	}
+
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				doTick()
+			}
+		}
+	}()
+	return func() {
+		cancelFunc()
+		<-done
+	}, nil
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"reflect"
 	"sync/atomic"
@@ -18,7 +20,10 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"tailscale.com/derp"
+	"tailscale.com/derp/derphttp"
 	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
@@ -137,7 +142,7 @@ func TestWorkspaces(t *testing.T) {
 				Time:  database.Now(),
 				Valid: true,
 			},
-			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Types: []string{string(database.ProvisionerTypeEcho)},
 		})
 		require.NoError(t, err)
 		return job
@@ -372,6 +377,75 @@ func TestAgents(t *testing.T) {
 	}, testutil.WaitShort, testutil.IntervalFast)
 }
 
+func TestDERP(t *testing.T) {
+	t.Parallel()
+
+	derpServer := derp.NewServer(key.NewNode(), tailnet.Logger(slogtest.Make(t, nil)))
+	defer derpServer.Close()
+	srv := httptest.NewServer(derphttp.Handler(derpServer))
+	defer srv.Close()
+	derpURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	derpMapFn := func() *tailcfg.DERPMap {
+		return &tailcfg.DERPMap{Regions: map[int]*tailcfg.DERPRegion{
+			1: {
+				RegionID:   1,
+				RegionCode: "test",
+				Nodes: []*tailcfg.DERPNode{{
+					Name:             "1a",
+					RegionID:         1,
+					HostName:         derpURL.Host,
+					IPv4:             derpURL.Host,
+					STUNPort:         -1,
+					InsecureForTests: true,
+					ForceHTTP:        true,
+				}},
+			},
+		}}
+	}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	registry := prometheus.NewRegistry()
+	closeFunc, err := prometheusmetrics.DERP(ctx, slogtest.Make(t, &slogtest.Options{
+		IgnoreErrors: true,
+	}), registry, derpServer, derpMapFn, 50*time.Millisecond)
+	require.NoError(t, err)
+	t.Cleanup(closeFunc)
+
+	var regionHealthy bool
+	var nodeLatency bool
+	var serverAccepts bool
+	require.Eventually(t, func() bool {
+		metrics, err := registry.Gather()
+		assert.NoError(t, err)
+
+		for _, metric := range metrics {
+			switch metric.GetName() {
+			case "coderd_derp_region_healthy":
+				if len(metric.Metric) == 0 {
+					continue
+				}
+				assert.Equal(t, "test", metric.Metric[0].Label[0].GetValue()) // Region code
+				assert.Equal(t, "1", metric.Metric[0].Label[1].GetValue())    // Region ID
+				regionHealthy = metric.Metric[0].Gauge.GetValue() == 1
+			case "coderd_derp_region_node_latency_seconds":
+				if len(metric.Metric) == 0 {
+					continue
+				}
+				assert.Equal(t, "1a", metric.Metric[0].Label[0].GetValue()) // Node
+				nodeLatency = true
+			case "coderd_derp_server_accepts_total":
+				// Probing the region connects to the DERP server.
+				serverAccepts = metric.Metric[0].Counter.GetValue() > 0
+			}
+		}
+		return regionHealthy && nodeLatency && serverAccepts
+	}, testutil.WaitLong, testutil.IntervalFast)
+}
+
 func TestAgentStats(t *testing.T) {
 	t.Parallel()
 
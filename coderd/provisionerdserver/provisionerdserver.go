@@ -36,6 +36,7 @@ import (
 	"github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/coderd/tracing"
+	"github.com/coder/coder/coderd/usersecrets"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisioner"
 	"github.com/coder/coder/provisionerd/proto"
@@ -63,8 +64,13 @@ type Server struct {
 	Auditor                     *atomic.Pointer[audit.Auditor]
 	TemplateScheduleStore       *atomic.Pointer[schedule.TemplateScheduleStore]
 	UserQuietHoursScheduleStore *atomic.Pointer[schedule.UserQuietHoursScheduleStore]
+	ScheduleHolidays            *schedule.HolidayCache
 	DeploymentValues            *codersdk.DeploymentValues
 
+	// OrganizationID scopes the daemon to the jobs of an organization. If
+	// it's nil, the daemon acquires jobs of all organizations.
+	OrganizationID uuid.UUID
+
 	AcquireJobDebounce time.Duration
 	OIDCConfig         httpmw.OAuth2Config
 
@@ -80,6 +86,74 @@ func (server *Server) timeNow() time.Time {
 	return database.Now()
 }
 
+// publishWorkspaceUpdate notifies watchers of the workspace and watchers of
+// all workspaces that the workspace changed.
+func (server *Server) publishWorkspaceUpdate(workspaceID uuid.UUID) error {
+	err := server.Pubsub.Publish(codersdk.WorkspaceNotifyChannel(workspaceID), []byte{})
+	if err != nil {
+		return err
+	}
+	return server.Pubsub.Publish(codersdk.WorkspacesNotifyChannel, []byte(workspaceID.String()))
+}
+
+// acquireJob acquires a job, and rolls the acquisition back if a concurrent
+// acquisition took the last free slot of the organization or template of the
+// job. AcquireProvisionerJob checks the concurrency limits as well, but
+// against a snapshot taken before concurrent acquisitions committed, so they
+// are counted again while holding a lock on each limit. sql.ErrNoRows is
+// returned if no job could be acquired.
+func acquireJob(ctx context.Context, db database.Store, params database.AcquireProvisionerJobParams) (database.ProvisionerJob, error) {
+	var job database.ProvisionerJob
+	err := db.InTx(func(tx database.Store) error {
+		var err error
+		job, err = tx.AcquireProvisionerJob(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		concurrency, err := tx.GetProvisionerJobConcurrency(ctx, job.ID)
+		if err != nil {
+			return xerrors.Errorf("get provisioner job concurrency: %w", err)
+		}
+		if concurrency.OrganizationMaxConcurrentJobs <= 0 && concurrency.TemplateMaxConcurrentBuilds <= 0 {
+			return nil
+		}
+		// The locks are always taken in the same order to prevent deadlocks.
+		if concurrency.OrganizationMaxConcurrentJobs > 0 {
+			err = tx.AcquireLock(ctx, database.GenLockID("provisioner-jobs-organization:"+concurrency.OrganizationID.String()))
+			if err != nil {
+				return xerrors.Errorf("lock organization: %w", err)
+			}
+		}
+		if concurrency.TemplateMaxConcurrentBuilds > 0 {
+			err = tx.AcquireLock(ctx, database.GenLockID("provisioner-jobs-template:"+concurrency.TemplateID.String()))
+			if err != nil {
+				return xerrors.Errorf("lock template: %w", err)
+			}
+		}
+
+		// Acquisitions that held the locks before have committed, so they're
+		// counted now.
+		concurrency, err = tx.GetProvisionerJobConcurrency(ctx, job.ID)
+		if err != nil {
+			return xerrors.Errorf("get provisioner job concurrency: %w", err)
+		}
+		if concurrency.OrganizationMaxConcurrentJobs > 0 &&
+			concurrency.OrganizationRunningJobs >= int64(concurrency.OrganizationMaxConcurrentJobs) {
+			return sql.ErrNoRows
+		}
+		if concurrency.TemplateMaxConcurrentBuilds > 0 &&
+			concurrency.TemplateRunningBuilds >= int64(concurrency.TemplateMaxConcurrentBuilds) {
+			return sql.ErrNoRows
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return database.ProvisionerJob{}, err
+	}
+	return job, nil
+}
+
 // AcquireJob queries the database to lock a job.
 func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
 	//nolint:gocritic // Provisionerd has specific authz rules.
@@ -95,8 +169,12 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 		return &proto.AcquiredJob{}, nil
 	}
 	lastAcquireMutex.RUnlock()
+	types := make([]string, 0, len(server.Provisioners))
+	for _, provisionerType := range server.Provisioners {
+		types = append(types, string(provisionerType))
+	}
 	// This marks the job as locked in the database.
-	job, err := server.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+	job, err := acquireJob(ctx, server.Database, database.AcquireProvisionerJobParams{
 		StartedAt: sql.NullTime{
 			Time:  database.Now(),
 			Valid: true,
@@ -105,8 +183,9 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 			UUID:  server.ID,
 			Valid: true,
 		},
-		Types: server.Provisioners,
-		Tags:  server.Tags,
+		Types:          types,
+		Tags:           server.Tags,
+		OrganizationID: server.OrganizationID,
 	})
 	if errors.Is(err, sql.ErrNoRows) {
 		// The provisioner daemon assumes no jobs are available if
@@ -185,6 +264,10 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
 			return nil, failJob(fmt.Sprintf("get template version variables: %s", err))
 		}
+		organizationVariables, err := server.organizationTemplateVariables(ctx, job.OrganizationID)
+		if err != nil {
+			return nil, failJob(err.Error())
+		}
 		template, err := server.Database.GetTemplateByID(ctx, templateVersion.TemplateID.UUID)
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("get template: %s", err))
@@ -193,7 +276,7 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("get owner: %s", err))
 		}
-		err = server.Pubsub.Publish(codersdk.WorkspaceNotifyChannel(workspace.ID), []byte{})
+		err = server.publishWorkspaceUpdate(workspace.ID)
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("publish workspace update: %s", err))
 		}
@@ -213,7 +296,7 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 			if err != nil {
 				return nil, failJob(fmt.Sprintf("regenerate session token: %s", err))
 			}
-		case database.WorkspaceTransitionStop, database.WorkspaceTransitionDelete:
+		case database.WorkspaceTransitionStop, database.WorkspaceTransitionArchive, database.WorkspaceTransitionDelete:
 			err = deleteSessionToken(ctx, server.Database, workspace)
 			if err != nil {
 				return nil, failJob(fmt.Sprintf("delete session token: %s", err))
@@ -278,7 +361,7 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 				WorkspaceName:       workspace.Name,
 				State:               workspaceBuild.ProvisionerState,
 				RichParameterValues: convertRichParameterValues(workspaceBuildParameters),
-				VariableValues:      asVariableValues(templateVariables),
+				VariableValues:      asVariableValues(templateVariables, organizationVariables),
 				GitAuthProviders:    gitAuthProviders,
 				Metadata: &sdkproto.Provision_Metadata{
 					CoderUrl:                      server.AccessURL.String(),
@@ -311,11 +394,15 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
 			return nil, failJob(fmt.Sprintf("get template version variables: %s", err))
 		}
+		organizationVariables, err := server.organizationTemplateVariables(ctx, job.OrganizationID)
+		if err != nil {
+			return nil, failJob(err.Error())
+		}
 
 		protoJob.Type = &proto.AcquiredJob_TemplateDryRun_{
 			TemplateDryRun: &proto.AcquiredJob_TemplateDryRun{
 				RichParameterValues: convertRichParameterValues(input.RichParameterValues),
-				VariableValues:      asVariableValues(templateVariables),
+				VariableValues:      asVariableValues(templateVariables, organizationVariables),
 				Metadata: &sdkproto.Provision_Metadata{
 					CoderUrl:      server.AccessURL.String(),
 					WorkspaceName: input.WorkspaceName,
@@ -493,6 +580,10 @@ func (server *Server) UpdateJob(ctx context.Context, request *proto.UpdateJobReq
 			insertParams.Stage = append(insertParams.Stage, log.Stage)
 			insertParams.Source = append(insertParams.Source, logSource)
 			insertParams.Output = append(insertParams.Output, log.Output)
+			insertParams.ProgressStage = append(insertParams.ProgressStage, string(convertProgressStage(log.Progress.GetStage())))
+			insertParams.ProgressResource = append(insertParams.ProgressResource, log.Progress.GetResource())
+			insertParams.ProgressCompleted = append(insertParams.ProgressCompleted, log.Progress.GetCompleted())
+			insertParams.ProgressTotal = append(insertParams.ProgressTotal, log.Progress.GetTotal())
 			server.Logger.Debug(ctx, "job log",
 				slog.F("job_id", parsedID),
 				slog.F("stage", log.Stage),
@@ -540,6 +631,11 @@ func (server *Server) UpdateJob(ctx context.Context, request *proto.UpdateJobReq
 			return nil, xerrors.Errorf("get template version by job id: %w", err)
 		}
 
+		organizationVariables, err := server.organizationTemplateVariables(ctx, job.OrganizationID)
+		if err != nil {
+			return nil, err
+		}
+
 		var variableValues []*sdkproto.VariableValue
 		var variablesWithMissingValues []string
 		for _, templateVariable := range request.TemplateVariables {
@@ -553,14 +649,23 @@ func (server *Server) UpdateJob(ctx context.Context, request *proto.UpdateJobReq
 				}
 			}
 
-			if templateVariable.Required && value == "" {
+			// The value of the organization is resolved again for each build,
+			// so it's not stored with the version.
+			resolvedValue := value
+			sensitive := templateVariable.Sensitive
+			if organizationVariable, ok := organizationVariables[templateVariable.Name]; ok && (value == "" || value == templateVariable.DefaultValue) {
+				resolvedValue = organizationVariable.Value
+				sensitive = sensitive || organizationVariable.Sensitive
+			}
+
+			if templateVariable.Required && resolvedValue == "" {
 				variablesWithMissingValues = append(variablesWithMissingValues, templateVariable.Name)
 			}
 
 			variableValues = append(variableValues, &sdkproto.VariableValue{
 				Name:      templateVariable.Name,
-				Value:     value,
-				Sensitive: templateVariable.Sensitive,
+				Value:     resolvedValue,
+				Sensitive: sensitive,
 			})
 
 			_, err = server.Database.InsertTemplateVersionVariable(ctx, database.InsertTemplateVersionVariableParams{
@@ -614,6 +719,7 @@ func (server *Server) FailJob(ctx context.Context, failJob *proto.FailedJob) (*p
 	if job.CompletedAt.Valid {
 		return nil, xerrors.Errorf("job already completed")
 	}
+	server.updateCacheStats(ctx, failJob.CacheStats)
 	job.CompletedAt = sql.NullTime{
 		Time:  database.Now(),
 		Valid: true,
@@ -675,7 +781,7 @@ func (server *Server) FailJob(ctx context.Context, failJob *proto.FailedJob) (*p
 			return nil, err
 		}
 
-		err = server.Pubsub.Publish(codersdk.WorkspaceNotifyChannel(build.WorkspaceID), []byte{})
+		err = server.publishWorkspaceUpdate(build.WorkspaceID)
 		if err != nil {
 			return nil, xerrors.Errorf("update workspace: %w", err)
 		}
@@ -743,6 +849,31 @@ func (server *Server) FailJob(ctx context.Context, failJob *proto.FailedJob) (*p
 	return &proto.Empty{}, nil
 }
 
+// validatePresetParameters returns a job error if a preset sets a parameter
+// the template version does not declare.
+func validatePresetParameters(presets []database.TemplateVersionPreset, richParameters []*sdkproto.RichParameter) (sql.NullString, error) {
+	names := make(map[string]struct{}, len(richParameters))
+	for _, richParameter := range richParameters {
+		names[richParameter.Name] = struct{}{}
+	}
+	for _, preset := range presets {
+		var parameters []codersdk.WorkspaceBuildParameter
+		err := json.Unmarshal(preset.Parameters, &parameters)
+		if err != nil {
+			return sql.NullString{}, xerrors.Errorf("unmarshal parameters of preset %q: %w", preset.Name, err)
+		}
+		for _, parameter := range parameters {
+			if _, ok := names[parameter.Name]; !ok {
+				return sql.NullString{
+					String: fmt.Sprintf("preset %q sets parameter %q, which is not declared by the template", preset.Name, parameter.Name),
+					Valid:  true,
+				}, nil
+			}
+		}
+	}
+	return sql.NullString{}, nil
+}
+
 // CompleteJob is triggered by a provision daemon to mark a provisioner job as completed.
 //
 //nolint:gocyclo
@@ -764,6 +895,7 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 	if job.WorkerID.UUID.String() != server.ID.String() {
 		return nil, xerrors.Errorf("you don't own this job")
 	}
+	server.updateCacheStats(ctx, completed.CacheStats)
 
 	telemetrySnapshot := &telemetry.Snapshot{}
 	// Items are added to this snapshot as they complete!
@@ -864,6 +996,20 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 			}
 		}
 
+		if !completedError.Valid {
+			// Presets are stored when the version is created, before the
+			// parameters are known. Reject presets that would silently do
+			// nothing when a workspace is created from them.
+			presets, err := server.Database.GetTemplateVersionPresets(ctx, input.TemplateVersionID)
+			if err != nil {
+				return nil, xerrors.Errorf("get template version presets: %w", err)
+			}
+			completedError, err = validatePresetParameters(presets, jobType.TemplateImport.RichParameters)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		err = server.Database.UpdateTemplateVersionGitAuthProvidersByJobID(ctx, database.UpdateTemplateVersionGitAuthProvidersByJobIDParams{
 			JobID:            jobID,
 			GitAuthProviders: jobType.TemplateImport.GitAuthProviders,
@@ -903,6 +1049,7 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 
 		var workspace database.Workspace
 		var getWorkspaceError error
+		var dailyCost int32
 
 		err = server.Database.InTx(func(db database.Store) error {
 			// It's important we use server.timeNow() here because we want to be
@@ -923,6 +1070,7 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 				Database:                    db,
 				TemplateScheduleStore:       *server.TemplateScheduleStore.Load(),
 				UserQuietHoursScheduleStore: *server.UserQuietHoursScheduleStore.Load(),
+				Holidays:                    server.ScheduleHolidays,
 				Now:                         now,
 				Workspace:                   workspace,
 			})
@@ -953,6 +1101,7 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 			}
 
 			agentTimeouts := make(map[time.Duration]bool) // A set of agent timeouts.
+			dailyCost = 0
 			// This could be a bulk insert to improve performance.
 			for _, protoResource := range jobType.WorkspaceBuild.Resources {
 				for _, protoAgent := range protoResource.Agents {
@@ -963,6 +1112,7 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 				if err != nil {
 					return xerrors.Errorf("insert provisioner job: %w", err)
 				}
+				dailyCost += protoResource.DailyCost
 			}
 
 			// On start, we want to ensure that workspace agents timeout statuses
@@ -993,7 +1143,7 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 						// after this function has returned. The server also doesn't
 						// have a shutdown signal we can listen to.
 						<-wait
-						if err := server.Pubsub.Publish(codersdk.WorkspaceNotifyChannel(workspaceBuild.WorkspaceID), []byte{}); err != nil {
+						if err := server.publishWorkspaceUpdate(workspaceBuild.WorkspaceID); err != nil {
 							server.Logger.Error(ctx, "workspace notification after agent timeout failed",
 								slog.F("workspace_build_id", workspaceBuild.ID),
 								slog.Error(err),
@@ -1022,6 +1172,30 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 			return nil, xerrors.Errorf("complete job: %w", err)
 		}
 
+		// The cost committed when the build was planned is replaced by the
+		// cost of the resources that were applied, so quota consumption
+		// matches the resources of the workspace once the build completes.
+		if q := server.QuotaCommitter.Load(); q != nil && dailyCost != workspaceBuild.DailyCost {
+			resp, err := (*q).CommitQuota(ctx, &proto.CommitQuotaRequest{
+				JobId:     jobID.String(),
+				DailyCost: dailyCost,
+			})
+			if err != nil {
+				return nil, xerrors.Errorf("commit quota: %w", err)
+			}
+			if !resp.Ok {
+				// The resources exist already, so the build can't fail
+				// anymore. The planned cost is kept.
+				server.Logger.Warn(ctx, "applied workspace build cost exceeds quota",
+					slog.F("workspace_build_id", workspaceBuild.ID),
+					slog.F("planned_daily_cost", workspaceBuild.DailyCost),
+					slog.F("daily_cost", dailyCost),
+					slog.F("credits_consumed", resp.CreditsConsumed),
+					slog.F("budget", resp.Budget),
+				)
+			}
+		}
+
 		// audit the outcome of the workspace build
 		if getWorkspaceError == nil {
 			auditor := server.Auditor.Load()
@@ -1062,7 +1236,7 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 			})
 		}
 
-		err = server.Pubsub.Publish(codersdk.WorkspaceNotifyChannel(workspaceBuild.WorkspaceID), []byte{})
+		err = server.publishWorkspaceUpdate(workspaceBuild.WorkspaceID)
 		if err != nil {
 			return nil, xerrors.Errorf("update workspace: %w", err)
 		}
@@ -1117,6 +1291,28 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 	return &proto.Empty{}, nil
 }
 
+// updateCacheStats stores the statistics of the provider and module cache
+// the daemon reported with a job. Only external provisioner daemons are
+// stored, so nothing is updated for the daemons of coderd.
+func (server *Server) updateCacheStats(ctx context.Context, stats *proto.CacheStats) {
+	if stats == nil {
+		return
+	}
+	err := server.Database.UpdateProvisionerDaemonCacheStats(ctx, database.UpdateProvisionerDaemonCacheStatsParams{
+		ID:                server.ID,
+		UpdatedAt:         sql.NullTime{Time: database.Now(), Valid: true},
+		CacheEntries:      stats.Entries,
+		CacheSizeBytes:    stats.SizeBytes,
+		CacheMaxSizeBytes: stats.MaxSizeBytes,
+		CacheHits:         stats.Hits,
+		CacheMisses:       stats.Misses,
+	})
+	if err != nil {
+		// The statistics are informational, so they don't fail the job.
+		server.Logger.Warn(ctx, "failed to update provisioner daemon cache stats", slog.Error(err))
+	}
+}
+
 func (server *Server) startTrace(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
 	return server.Tracer.Start(ctx, name, append(opts, trace.WithAttributes(
 		semconv.ServiceNameKey.String("coderd.provisionerd"),
@@ -1233,6 +1429,74 @@ func InsertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.
 			}
 		}
 
+		scriptNames := make(map[string]struct{})
+		for i, script := range prAgent.Scripts {
+			// The name is used in the file name of the log of the script.
+			if !provisioner.AppSlugRegex.MatchString(script.Name) {
+				return xerrors.Errorf("script name %q does not match regex %q", script.Name, provisioner.AppSlugRegex.String())
+			}
+			if _, exists := scriptNames[script.Name]; exists {
+				return xerrors.Errorf("duplicate script name %q", script.Name)
+			}
+			scriptNames[script.Name] = struct{}{}
+
+			p := database.InsertWorkspaceAgentScriptParams{
+				WorkspaceAgentID: agentID,
+				Name:             script.Name,
+				Ordinal:          int32(i),
+				Script:           script.Script,
+				RunOnStart:       script.RunOnStart,
+				RunOnStop:        script.RunOnStop,
+				TimeoutSeconds:   script.TimeoutSeconds,
+				ContinueOnError:  script.ContinueOnError,
+			}
+			err := db.InsertWorkspaceAgentScript(ctx, p)
+			if err != nil {
+				return xerrors.Errorf("insert agent script: %w, params: %+v", err, p)
+			}
+		}
+
+		secretNames := make(map[string]struct{})
+		for _, name := range prAgent.Secrets {
+			// Secrets are set as environment variables of the same name.
+			if err := usersecrets.NameValid(name); err != nil {
+				return xerrors.Errorf("invalid secret name: %w", err)
+			}
+			if _, exists := secretNames[name]; exists {
+				return xerrors.Errorf("duplicate secret name %q", name)
+			}
+			secretNames[name] = struct{}{}
+
+			err := db.InsertWorkspaceAgentSecret(ctx, database.InsertWorkspaceAgentSecretParams{
+				WorkspaceAgentID: agentID,
+				Name:             name,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert agent secret %q: %w", name, err)
+			}
+		}
+
+		devcontainerFolders := make(map[string]struct{})
+		for _, devcontainer := range prAgent.Devcontainers {
+			if devcontainer.WorkspaceFolder == "" {
+				return xerrors.New("devcontainer workspace folder must not be empty")
+			}
+			if _, exists := devcontainerFolders[devcontainer.WorkspaceFolder]; exists {
+				return xerrors.Errorf("duplicate devcontainer workspace folder %q", devcontainer.WorkspaceFolder)
+			}
+			devcontainerFolders[devcontainer.WorkspaceFolder] = struct{}{}
+
+			p := database.InsertWorkspaceAgentDevcontainerParams{
+				WorkspaceAgentID: agentID,
+				WorkspaceFolder:  devcontainer.WorkspaceFolder,
+				ConfigPath:       devcontainer.ConfigPath,
+			}
+			err := db.InsertWorkspaceAgentDevcontainer(ctx, p)
+			if err != nil {
+				return xerrors.Errorf("insert agent devcontainer: %w, params: %+v", err, p)
+			}
+		}
+
 		for _, app := range prAgent.Apps {
 			slug := app.Slug
 			if slug == "" {
@@ -1262,6 +1526,23 @@ func InsertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.
 				sharingLevel = database.AppSharingLevelPublic
 			}
 
+			auth := database.WorkspaceAppAuthCoder
+			if app.Auth != "" {
+				auth = database.WorkspaceAppAuth(app.Auth)
+				if !auth.Valid() {
+					return xerrors.Errorf("app %q has invalid auth %q, must be one of %v", slug, app.Auth, database.AllWorkspaceAppAuthValues())
+				}
+				if auth == database.WorkspaceAppAuthOidc && !app.Subdomain {
+					return xerrors.Errorf("app %q must be a subdomain app to use oidc auth", slug)
+				}
+			}
+			if app.RewritePaths && app.Subdomain {
+				return xerrors.Errorf("app %q must be a path-based app to rewrite paths", slug)
+			}
+			if app.RateLimitRequestsPerMinute < 0 || app.RateLimitBurst < 0 {
+				return xerrors.Errorf("app %q has invalid rate limit, requests_per_minute and burst must not be negative", slug)
+			}
+
 			dbApp, err := db.InsertWorkspaceApp(ctx, database.InsertWorkspaceAppParams{
 				ID:          uuid.New(),
 				CreatedAt:   database.Now(),
@@ -1277,13 +1558,17 @@ func InsertWorkspaceResource(ctx context.Context, db database.Store, jobID uuid.
 					String: app.Url,
 					Valid:  app.Url != "",
 				},
-				External:             app.External,
-				Subdomain:            app.Subdomain,
-				SharingLevel:         sharingLevel,
-				HealthcheckUrl:       app.Healthcheck.Url,
-				HealthcheckInterval:  app.Healthcheck.Interval,
-				HealthcheckThreshold: app.Healthcheck.Threshold,
-				Health:               health,
+				External:                   app.External,
+				Subdomain:                  app.Subdomain,
+				SharingLevel:               sharingLevel,
+				HealthcheckUrl:             app.Healthcheck.Url,
+				HealthcheckInterval:        app.Healthcheck.Interval,
+				HealthcheckThreshold:       app.Healthcheck.Threshold,
+				Health:                     health,
+				Auth:                       auth,
+				RateLimitRequestsPerMinute: app.RateLimitRequestsPerMinute,
+				RateLimitBurst:             app.RateLimitBurst,
+				RewritePaths:               app.RewritePaths,
 			})
 			if err != nil {
 				return xerrors.Errorf("insert app: %w", err)
@@ -1434,6 +1719,21 @@ func convertLogLevel(logLevel sdkproto.LogLevel) (database.LogLevel, error) {
 	}
 }
 
+// convertProgressStage returns an empty stage for logs that don't report
+// progress, or that come from provisioners newer than this server.
+func convertProgressStage(stage sdkproto.Stage) codersdk.ProvisionerJobLogStage {
+	switch stage {
+	case sdkproto.Stage_INIT:
+		return codersdk.ProvisionerJobLogStageInit
+	case sdkproto.Stage_PLAN:
+		return codersdk.ProvisionerJobLogStagePlan
+	case sdkproto.Stage_APPLY:
+		return codersdk.ProvisionerJobLogStageApply
+	default:
+		return ""
+	}
+}
+
 func convertLogSource(logSource proto.LogSource) (database.LogSource, error) {
 	switch logSource {
 	case proto.LogSource_PROVISIONER_DAEMON:
@@ -1476,6 +1776,8 @@ func convertWorkspaceTransition(transition database.WorkspaceTransition) (sdkpro
 		return sdkproto.WorkspaceTransition_STOP, nil
 	case database.WorkspaceTransitionDelete:
 		return sdkproto.WorkspaceTransition_DESTROY, nil
+	case database.WorkspaceTransitionArchive:
+		return sdkproto.WorkspaceTransition_ARCHIVE, nil
 	default:
 		return 0, xerrors.Errorf("unrecognized transition: %q", transition)
 	}
@@ -1485,7 +1787,7 @@ func auditActionFromTransition(transition database.WorkspaceTransition) database
 	switch transition {
 	case database.WorkspaceTransitionStart:
 		return database.AuditActionStart
-	case database.WorkspaceTransitionStop:
+	case database.WorkspaceTransitionStop, database.WorkspaceTransitionArchive:
 		return database.AuditActionStop
 	case database.WorkspaceTransitionDelete:
 		return database.AuditActionDelete
@@ -1513,19 +1815,38 @@ type TemplateVersionDryRunJob struct {
 	RichParameterValues []database.WorkspaceBuildParameter `json:"rich_parameter_values"`
 }
 
-func asVariableValues(templateVariables []database.TemplateVersionVariable) []*sdkproto.VariableValue {
+// organizationTemplateVariables returns the template variable values of the
+// organization by name.
+func (server *Server) organizationTemplateVariables(ctx context.Context, organizationID uuid.UUID) (map[string]database.OrganizationTemplateVariable, error) {
+	variables, err := server.Database.GetOrganizationTemplateVariables(ctx, organizationID)
+	if err != nil {
+		return nil, xerrors.Errorf("get organization template variables: %w", err)
+	}
+	variablesByName := make(map[string]database.OrganizationTemplateVariable, len(variables))
+	for _, variable := range variables {
+		variablesByName[variable.Name] = variable
+	}
+	return variablesByName, nil
+}
+
+// asVariableValues returns the values of the template variables. The values
+// of the organization are used for variables that the template version sets no
+// value for, instead of their default value.
+func asVariableValues(templateVariables []database.TemplateVersionVariable, organizationVariables map[string]database.OrganizationTemplateVariable) []*sdkproto.VariableValue {
 	var apiVariableValues []*sdkproto.VariableValue
 	for _, v := range templateVariables {
 		value := v.Value
-		if value == "" && v.DefaultValue != "" {
-			value = v.DefaultValue
+		sensitive := v.Sensitive
+		if organizationVariable, ok := organizationVariables[v.Name]; ok && (value == "" || value == v.DefaultValue) {
+			value = organizationVariable.Value
+			sensitive = sensitive || organizationVariable.Sensitive
 		}
 
-		if value != "" || v.Required {
+		if value != "" || v.DefaultValue != "" || v.Required {
 			apiVariableValues = append(apiVariableValues, &sdkproto.VariableValue{
 				Name:      v.Name,
-				Value:     v.Value,
-				Sensitive: v.Sensitive,
+				Value:     value,
+				Sensitive: sensitive,
 			})
 		}
 	}
@@ -2,16 +2,23 @@ package provisionerdserver
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbfake"
 	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/database/dbtestutil"
 	"github.com/coder/coder/testutil"
 )
 
@@ -60,3 +67,77 @@ func TestObtainOIDCAccessToken(t *testing.T) {
 		require.Equal(t, "token", link.OAuthAccessToken)
 	})
 }
+
+func TestAcquireJobConcurrencyLimits(t *testing.T) {
+	t.Parallel()
+	if !dbtestutil.WillUsePostgres() {
+		t.Skip("concurrent acquisitions only race against PostgreSQL")
+	}
+	ctx := testutil.Context(t, testutil.WaitLong)
+	db, _ := dbtestutil.NewDB(t)
+
+	const maxConcurrentJobs = 2
+	org := dbgen.Organization(t, db, database.Organization{})
+	_, err := db.UpsertOrganizationProvisionerSettings(ctx, database.UpsertOrganizationProvisionerSettingsParams{
+		OrganizationID:    org.ID,
+		CreatedAt:         database.Now(),
+		UpdatedAt:         database.Now(),
+		MaxConcurrentJobs: maxConcurrentJobs,
+	})
+	require.NoError(t, err)
+
+	const jobs = 10
+	for i := 0; i < jobs; i++ {
+		dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			Tags:           database.StringMap{},
+		})
+	}
+
+	// Every daemon acquires at the same time, so each of them sees the
+	// organization running no jobs before acquiring.
+	var (
+		wg       sync.WaitGroup
+		acquired atomic.Int32
+	)
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := acquireJob(ctx, db, database.AcquireProvisionerJobParams{
+				StartedAt: sql.NullTime{
+					Time:  database.Now(),
+					Valid: true,
+				},
+				WorkerID: uuid.NullUUID{
+					UUID:  uuid.New(),
+					Valid: true,
+				},
+				Types:          []string{string(database.ProvisionerTypeEcho), string(database.ProvisionerTypeTerraform)},
+				Tags:           json.RawMessage("{}"),
+				OrganizationID: org.ID,
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				return
+			}
+			if assert.NoError(t, err) {
+				acquired.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.NotZero(t, acquired.Load())
+	require.LessOrEqual(t, acquired.Load(), int32(maxConcurrentJobs))
+
+	// Acquisitions that were rolled back left their jobs queued.
+	all, err := db.GetProvisionerJobsCreatedAfter(ctx, time.Time{})
+	require.NoError(t, err)
+	var running int32
+	for _, job := range all {
+		if job.OrganizationID == org.ID && job.StartedAt.Valid {
+			running++
+		}
+	}
+	require.Equal(t, acquired.Load(), running)
+}
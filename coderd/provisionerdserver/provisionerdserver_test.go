@@ -98,6 +98,25 @@ func TestAcquireJob(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, &proto.AcquiredJob{}, job)
 	})
+	t.Run("OtherOrganization", func(t *testing.T) {
+		t.Parallel()
+		srv := setup(t, false)
+		// Daemons that authenticate with a provisioner key only acquire jobs
+		// of the organization of the key.
+		srv.OrganizationID = uuid.New()
+		_, err := srv.Database.InsertProvisionerJob(context.Background(), database.InsertProvisionerJobParams{
+			ID:             uuid.New(),
+			OrganizationID: uuid.New(),
+			InitiatorID:    uuid.New(),
+			Provisioner:    database.ProvisionerTypeEcho,
+			StorageMethod:  database.ProvisionerStorageMethodFile,
+			Type:           database.ProvisionerJobTypeTemplateVersionDryRun,
+		})
+		require.NoError(t, err)
+		job, err := srv.AcquireJob(context.Background(), nil)
+		require.NoError(t, err)
+		require.Equal(t, &proto.AcquiredJob{}, job)
+	})
 	t.Run("InitiatorNotFound", func(t *testing.T) {
 		t.Parallel()
 		srv := setup(t, false)
@@ -491,7 +510,7 @@ func TestUpdateJob(t *testing.T) {
 				UUID:  uuid.New(),
 				Valid: true,
 			},
-			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Types: []string{string(database.ProvisionerTypeEcho)},
 		})
 		require.NoError(t, err)
 		_, err = srv.UpdateJob(ctx, &proto.UpdateJobRequest{
@@ -513,7 +532,7 @@ func TestUpdateJob(t *testing.T) {
 				UUID:  srv.ID,
 				Valid: true,
 			},
-			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Types: []string{string(database.ProvisionerTypeEcho)},
 		})
 		require.NoError(t, err)
 		return job.ID
@@ -554,6 +573,43 @@ func TestUpdateJob(t *testing.T) {
 
 		<-published
 	})
+	t.Run("LogProgress", func(t *testing.T) {
+		t.Parallel()
+		srv := setup(t, false)
+		job := setupJob(t, srv)
+
+		_, err := srv.UpdateJob(ctx, &proto.UpdateJobRequest{
+			JobId: job.String(),
+			Logs: []*proto.Log{{
+				Source: proto.LogSource_PROVISIONER,
+				Level:  sdkproto.LogLevel_INFO,
+				Output: "coder_agent.main: Creation complete after 0s",
+				Progress: &sdkproto.Progress{
+					Stage:     sdkproto.Stage_APPLY,
+					Resource:  "coder_agent.main",
+					Completed: 1,
+					Total:     2,
+				},
+			}, {
+				Source: proto.LogSource_PROVISIONER,
+				Level:  sdkproto.LogLevel_INFO,
+				Output: "hi",
+			}},
+		})
+		require.NoError(t, err)
+
+		logs, err := srv.Database.GetProvisionerLogsAfterID(ctx, database.GetProvisionerLogsAfterIDParams{
+			JobID: job,
+		})
+		require.NoError(t, err)
+		require.Len(t, logs, 2)
+		require.Equal(t, "apply", logs[0].ProgressStage)
+		require.Equal(t, "coder_agent.main", logs[0].ProgressResource)
+		require.EqualValues(t, 1, logs[0].ProgressCompleted)
+		require.EqualValues(t, 2, logs[0].ProgressTotal)
+		// Logs without progress have no stage.
+		require.Empty(t, logs[1].ProgressStage)
+	})
 	t.Run("Readme", func(t *testing.T) {
 		t.Parallel()
 		srv := setup(t, false)
@@ -667,6 +723,59 @@ func TestUpdateJob(t *testing.T) {
 			require.Equal(t, templateVariables[0].Value, firstTemplateVariable.DefaultValue)
 			require.Equal(t, templateVariables[1].Value, "")
 		})
+
+		t.Run("OrganizationValue", func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+			defer cancel()
+
+			srv := setup(t, false)
+			job := setupJob(t, srv)
+			versionID := uuid.New()
+			err := srv.Database.InsertTemplateVersion(ctx, database.InsertTemplateVersionParams{
+				ID:    versionID,
+				JobID: job,
+			})
+			require.NoError(t, err)
+			for name, value := range map[string]string{"first": "organization_first", "second": "organization_second"} {
+				_, err = srv.Database.UpsertOrganizationTemplateVariable(ctx, database.UpsertOrganizationTemplateVariableParams{
+					Name:  name,
+					Value: value,
+				})
+				require.NoError(t, err)
+			}
+			response, err := srv.UpdateJob(ctx, &proto.UpdateJobRequest{
+				JobId: job.String(),
+				TemplateVariables: []*sdkproto.TemplateVariable{{
+					Name:         "first",
+					Type:         "string",
+					DefaultValue: "default_value",
+				}, {
+					Name:     "second",
+					Type:     "string",
+					Required: true,
+				}, {
+					Name: "third",
+					Type: "string",
+				}},
+				UserVariableValues: []*sdkproto.VariableValue{{
+					Name:  "third",
+					Value: "foobar",
+				}},
+			})
+			require.NoError(t, err)
+			require.Len(t, response.VariableValues, 3)
+			require.Equal(t, "organization_first", response.VariableValues[0].Value)
+			require.Equal(t, "organization_second", response.VariableValues[1].Value)
+			require.Equal(t, "foobar", response.VariableValues[2].Value)
+
+			// The values of the organization are resolved for each build
+			// instead of being stored with the version.
+			templateVariables, err := srv.Database.GetTemplateVersionVariables(ctx, versionID)
+			require.NoError(t, err)
+			require.Len(t, templateVariables, 3)
+			require.Equal(t, "default_value", templateVariables[0].Value)
+			require.Equal(t, "", templateVariables[1].Value)
+		})
 	})
 }
 
@@ -702,7 +811,7 @@ func TestFailJob(t *testing.T) {
 				UUID:  uuid.New(),
 				Valid: true,
 			},
-			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Types: []string{string(database.ProvisionerTypeEcho)},
 		})
 		require.NoError(t, err)
 		_, err = srv.FailJob(ctx, &proto.FailedJob{
@@ -725,7 +834,7 @@ func TestFailJob(t *testing.T) {
 				UUID:  srv.ID,
 				Valid: true,
 			},
-			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Types: []string{string(database.ProvisionerTypeEcho)},
 		})
 		require.NoError(t, err)
 		err = srv.Database.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
@@ -778,7 +887,7 @@ func TestFailJob(t *testing.T) {
 				UUID:  srv.ID,
 				Valid: true,
 			},
-			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Types: []string{string(database.ProvisionerTypeEcho)},
 		})
 		require.NoError(t, err)
 
@@ -844,7 +953,7 @@ func TestCompleteJob(t *testing.T) {
 				UUID:  uuid.New(),
 				Valid: true,
 			},
-			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Types: []string{string(database.ProvisionerTypeEcho)},
 		})
 		require.NoError(t, err)
 		_, err = srv.CompleteJob(ctx, &proto.CompletedJob{
@@ -852,6 +961,56 @@ func TestCompleteJob(t *testing.T) {
 		})
 		require.ErrorContains(t, err, "you don't own this job")
 	})
+	t.Run("CacheStats", func(t *testing.T) {
+		t.Parallel()
+		srv := setup(t, false)
+		_, err := srv.Database.InsertProvisionerDaemon(ctx, database.InsertProvisionerDaemonParams{
+			ID:           srv.ID,
+			CreatedAt:    database.Now(),
+			Name:         "daemon",
+			Provisioners: []database.ProvisionerType{database.ProvisionerTypeEcho},
+		})
+		require.NoError(t, err)
+		job, err := srv.Database.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:            uuid.New(),
+			Provisioner:   database.ProvisionerTypeEcho,
+			StorageMethod: database.ProvisionerStorageMethodFile,
+			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+		})
+		require.NoError(t, err)
+		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			WorkerID: uuid.NullUUID{
+				UUID:  srv.ID,
+				Valid: true,
+			},
+			Types: []string{string(database.ProvisionerTypeEcho)},
+		})
+		require.NoError(t, err)
+		_, err = srv.CompleteJob(ctx, &proto.CompletedJob{
+			JobId: job.ID.String(),
+			Type: &proto.CompletedJob_TemplateDryRun_{
+				TemplateDryRun: &proto.CompletedJob_TemplateDryRun{},
+			},
+			CacheStats: &proto.CacheStats{
+				Entries:      2,
+				SizeBytes:    1024,
+				MaxSizeBytes: 4096,
+				Hits:         3,
+				Misses:       1,
+			},
+		})
+		require.NoError(t, err)
+
+		daemons, err := srv.Database.GetProvisionerDaemons(ctx)
+		require.NoError(t, err)
+		require.Len(t, daemons, 1)
+		require.EqualValues(t, 2, daemons[0].CacheEntries)
+		require.EqualValues(t, 1024, daemons[0].CacheSizeBytes)
+		require.EqualValues(t, 4096, daemons[0].CacheMaxSizeBytes)
+		require.EqualValues(t, 3, daemons[0].CacheHits)
+		require.EqualValues(t, 1, daemons[0].CacheMisses)
+		require.True(t, daemons[0].UpdatedAt.Valid)
+	})
 	t.Run("TemplateImport", func(t *testing.T) {
 		t.Parallel()
 		srv := setup(t, false)
@@ -875,7 +1034,7 @@ func TestCompleteJob(t *testing.T) {
 				UUID:  srv.ID,
 				Valid: true,
 			},
-			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Types: []string{string(database.ProvisionerTypeEcho)},
 		})
 		require.NoError(t, err)
 		completeJob := func() {
@@ -906,6 +1065,55 @@ func TestCompleteJob(t *testing.T) {
 		require.NoError(t, err)
 		require.False(t, job.Error.Valid)
 	})
+	t.Run("TemplateImportUnknownPresetParameter", func(t *testing.T) {
+		t.Parallel()
+		srv := setup(t, false)
+		jobID := uuid.New()
+		versionID := uuid.New()
+		err := srv.Database.InsertTemplateVersion(ctx, database.InsertTemplateVersionParams{
+			ID:    versionID,
+			JobID: jobID,
+		})
+		require.NoError(t, err)
+		_, err = srv.Database.InsertTemplateVersionPreset(ctx, database.InsertTemplateVersionPresetParams{
+			TemplateVersionID: versionID,
+			Name:              "GPU",
+			Parameters:        []byte(`[{"name":"instance_type","value":"g5.xlarge"},{"name":"dsik_size","value":"100"}]`),
+			CreatedAt:         database.Now(),
+		})
+		require.NoError(t, err)
+		job, err := srv.Database.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:            jobID,
+			Provisioner:   database.ProvisionerTypeEcho,
+			Input:         []byte(`{"template_version_id": "` + versionID.String() + `"}`),
+			StorageMethod: database.ProvisionerStorageMethodFile,
+			Type:          database.ProvisionerJobTypeTemplateVersionImport,
+		})
+		require.NoError(t, err)
+		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			WorkerID: uuid.NullUUID{
+				UUID:  srv.ID,
+				Valid: true,
+			},
+			Types: []string{string(database.ProvisionerTypeEcho)},
+		})
+		require.NoError(t, err)
+		_, err = srv.CompleteJob(ctx, &proto.CompletedJob{
+			JobId: job.ID.String(),
+			Type: &proto.CompletedJob_TemplateImport_{
+				TemplateImport: &proto.CompletedJob_TemplateImport{
+					RichParameters: []*sdkproto.RichParameter{
+						{Name: "instance_type", Type: "string"},
+						{Name: "disk_size", Type: "number"},
+					},
+				},
+			},
+		})
+		require.NoError(t, err)
+		job, err = srv.Database.GetProvisionerJobByID(ctx, job.ID)
+		require.NoError(t, err)
+		require.Contains(t, job.Error.String, `preset "GPU" sets parameter "dsik_size"`)
+	})
 
 	// TODO(@dean): remove this legacy test for MaxTTL
 	t.Run("WorkspaceBuildLegacy", func(t *testing.T) {
@@ -1087,7 +1295,7 @@ func TestCompleteJob(t *testing.T) {
 						UUID:  srv.ID,
 						Valid: true,
 					},
-					Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+					Types: []string{string(database.ProvisionerTypeEcho)},
 				})
 				require.NoError(t, err)
 
@@ -1329,7 +1537,7 @@ func TestCompleteJob(t *testing.T) {
 						UUID:  srv.ID,
 						Valid: true,
 					},
-					Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+					Types: []string{string(database.ProvisionerTypeEcho)},
 				})
 				require.NoError(t, err)
 
@@ -1405,7 +1613,7 @@ func TestCompleteJob(t *testing.T) {
 				UUID:  srv.ID,
 				Valid: true,
 			},
-			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Types: []string{string(database.ProvisionerTypeEcho)},
 		})
 		require.NoError(t, err)
 
@@ -1471,6 +1679,48 @@ func TestInsertWorkspaceResource(t *testing.T) {
 		})
 		require.ErrorContains(t, err, "duplicate app slug")
 	})
+	t.Run("DuplicateScripts", func(t *testing.T) {
+		t.Parallel()
+		err := insert(dbfake.New(), uuid.New(), &sdkproto.Resource{
+			Name: "something",
+			Type: "aws_instance",
+			Agents: []*sdkproto.Agent{{
+				Scripts: []*sdkproto.Agent_Script{{
+					Name: "a",
+				}, {
+					Name: "a",
+				}},
+			}},
+		})
+		require.ErrorContains(t, err, "duplicate script name")
+	})
+	t.Run("InvalidSecretName", func(t *testing.T) {
+		t.Parallel()
+		err := insert(dbfake.New(), uuid.New(), &sdkproto.Resource{
+			Name: "something",
+			Type: "aws_instance",
+			Agents: []*sdkproto.Agent{{
+				Secrets: []string{"NOT-AN-ENV-VAR"},
+			}},
+		})
+		require.ErrorContains(t, err, "invalid secret name")
+	})
+	t.Run("DuplicateDevcontainerFolder", func(t *testing.T) {
+		t.Parallel()
+		err := insert(dbfake.New(), uuid.New(), &sdkproto.Resource{
+			Name: "something",
+			Type: "aws_instance",
+			Agents: []*sdkproto.Agent{{
+				Devcontainers: []*sdkproto.Agent_Devcontainer{{
+					WorkspaceFolder: "/home/coder/coder",
+				}, {
+					WorkspaceFolder: "/home/coder/coder",
+					ConfigPath:      ".devcontainer/go/devcontainer.json",
+				}},
+			}},
+		})
+		require.ErrorContains(t, err, "duplicate devcontainer workspace folder")
+	})
 	t.Run("Success", func(t *testing.T) {
 		t.Parallel()
 		db := dbfake.New()
@@ -1494,6 +1744,22 @@ func TestInsertWorkspaceResource(t *testing.T) {
 					Slug: "a",
 				}},
 				ShutdownScript: "shutdown",
+				Scripts: []*sdkproto.Agent_Script{{
+					Name:       "install",
+					Script:     "echo install",
+					RunOnStart: true,
+				}, {
+					Name:            "cleanup",
+					Script:          "echo cleanup",
+					RunOnStop:       true,
+					TimeoutSeconds:  60,
+					ContinueOnError: true,
+				}},
+				Secrets: []string{"NPM_TOKEN"},
+				Devcontainers: []*sdkproto.Agent_Devcontainer{{
+					WorkspaceFolder: "/home/coder/coder",
+					ConfigPath:      ".devcontainer/go/devcontainer.json",
+				}},
 			}},
 		})
 		require.NoError(t, err)
@@ -1509,6 +1775,25 @@ func TestInsertWorkspaceResource(t *testing.T) {
 		require.Equal(t, "linux", agent.OperatingSystem)
 		require.Equal(t, "value", agent.StartupScript.String)
 		require.Equal(t, "shutdown", agent.ShutdownScript.String)
+		scripts, err := db.GetWorkspaceAgentScriptsByAgentID(ctx, agent.ID)
+		require.NoError(t, err)
+		require.Len(t, scripts, 2)
+		require.Equal(t, "install", scripts[0].Name)
+		require.True(t, scripts[0].RunOnStart)
+		require.Equal(t, "cleanup", scripts[1].Name)
+		require.EqualValues(t, 1, scripts[1].Ordinal)
+		require.EqualValues(t, 60, scripts[1].TimeoutSeconds)
+		require.True(t, scripts[1].ContinueOnError)
+		require.Equal(t, database.WorkspaceAgentScriptStatusPending, scripts[1].Status)
+		secrets, err := db.GetWorkspaceAgentSecretsByAgentID(ctx, agent.ID)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		require.Equal(t, "NPM_TOKEN", secrets[0].Name)
+		devcontainers, err := db.GetWorkspaceAgentDevcontainersByAgentID(ctx, agent.ID)
+		require.NoError(t, err)
+		require.Len(t, devcontainers, 1)
+		require.Equal(t, "/home/coder/coder", devcontainers[0].WorkspaceFolder)
+		require.Equal(t, ".devcontainer/go/devcontainer.json", devcontainers[0].ConfigPath)
 		want, err := json.Marshal(map[string]string{
 			"something": "test",
 		})
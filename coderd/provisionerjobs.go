@@ -185,7 +185,7 @@ func convertProvisionerJobLogs(provisionerJobLogs []database.ProvisionerJobLog)
 }
 
 func convertProvisionerJobLog(provisionerJobLog database.ProvisionerJobLog) codersdk.ProvisionerJobLog {
-	return codersdk.ProvisionerJobLog{
+	log := codersdk.ProvisionerJobLog{
 		ID:        provisionerJobLog.ID,
 		CreatedAt: provisionerJobLog.CreatedAt,
 		Source:    codersdk.LogSource(provisionerJobLog.Source),
@@ -193,6 +193,15 @@ func convertProvisionerJobLog(provisionerJobLog database.ProvisionerJobLog) code
 		Stage:     provisionerJobLog.Stage,
 		Output:    provisionerJobLog.Output,
 	}
+	if provisionerJobLog.ProgressStage != "" {
+		log.Progress = &codersdk.ProvisionerJobLogProgress{
+			Stage:     codersdk.ProvisionerJobLogStage(provisionerJobLog.ProgressStage),
+			Resource:  provisionerJobLog.ProgressResource,
+			Completed: provisionerJobLog.ProgressCompleted,
+			Total:     provisionerJobLog.ProgressTotal,
+		}
+	}
+	return log
 }
 
 func convertProvisionerJob(pj database.GetProvisionerJobsByIDsWithQueuePositionRow) codersdk.ProvisionerJob {
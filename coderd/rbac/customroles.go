@@ -0,0 +1,119 @@
+package rbac
+
+import (
+	"sort"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+// customRoles are the site wide roles defined by admins in addition to the
+// built-in roles. They are stored in the database, and coderd keeps this map
+// in sync with it.
+//
+// Like builtInRoles this is a global, so roles are set and deleted one at a
+// time instead of replacing the whole set. This keeps multiple coderd
+// instances in the same process (e.g. in tests) from removing each other's
+// roles.
+var customRoles = struct {
+	sync.RWMutex
+	roles map[string]Role
+}{
+	roles: map[string]Role{},
+}
+
+// SetCustomRole adds the custom role, replacing the custom role with the same
+// name if it exists. The role must be valid according to ValidateCustomRole.
+func SetCustomRole(role Role) error {
+	err := ValidateCustomRole(role)
+	if err != nil {
+		return err
+	}
+
+	// Custom roles only change through SetCustomRole, so the rego value can
+	// be cached until the role is replaced.
+	role = role.withCachedRegoValue()
+	customRoles.Lock()
+	defer customRoles.Unlock()
+	customRoles.roles[role.Name] = role
+	return nil
+}
+
+// DeleteCustomRole removes the custom role with the given name, if it exists.
+func DeleteCustomRole(name string) {
+	customRoles.Lock()
+	defer customRoles.Unlock()
+	delete(customRoles.roles, name)
+}
+
+// CustomRoles lists all custom roles, sorted by name.
+func CustomRoles() []Role {
+	customRoles.RLock()
+	defer customRoles.RUnlock()
+
+	roles := make([]Role, 0, len(customRoles.roles))
+	for _, role := range customRoles.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].Name < roles[j].Name
+	})
+	return roles
+}
+
+func customRoleByName(name string) (Role, bool) {
+	customRoles.RLock()
+	defer customRoles.RUnlock()
+	role, ok := customRoles.roles[name]
+	return role, ok
+}
+
+// IsBuiltInRole returns true if name is the name of a built-in role, which
+// can't be used for a custom role.
+func IsBuiltInRole(name string) bool {
+	_, ok := builtInRoles[name]
+	return ok || name == "system"
+}
+
+// ValidateCustomRole returns an error if the role can't be used as a custom
+// role. Custom roles are site wide, so they only have site and user
+// permissions. Permissions must be for a known resource type and action, and
+// can't use wildcards.
+func ValidateCustomRole(role Role) error {
+	name, orgID, err := roleSplit(role.Name)
+	if err != nil {
+		return xerrors.Errorf("invalid role name: %w", err)
+	}
+	if orgID != "" {
+		return xerrors.Errorf("custom role %q must not be an organization role", role.Name)
+	}
+	if IsBuiltInRole(name) {
+		return xerrors.Errorf("custom role %q must not have the name of a built-in role", role.Name)
+	}
+	if role.DisplayName == "" {
+		return xerrors.Errorf("custom role %q must have a display name", role.Name)
+	}
+	if len(role.Org) > 0 {
+		return xerrors.Errorf("custom role %q must not have organization permissions", role.Name)
+	}
+
+	resourceTypes := make(map[string]bool)
+	for _, r := range AllResources() {
+		if r.Type != ResourceWildcard.Type {
+			resourceTypes[r.Type] = true
+		}
+	}
+	actions := make(map[Action]bool)
+	for _, a := range AllActions() {
+		actions[a] = true
+	}
+	for _, perm := range append(append([]Permission{}, role.Site...), role.User...) {
+		if !resourceTypes[perm.ResourceType] {
+			return xerrors.Errorf("custom role %q has a permission for unknown resource type %q", role.Name, perm.ResourceType)
+		}
+		if !actions[perm.Action] {
+			return xerrors.Errorf("custom role %q has a permission for unknown action %q", role.Name, perm.Action)
+		}
+	}
+	return nil
+}
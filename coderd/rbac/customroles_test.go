@@ -0,0 +1,166 @@
+package rbac_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/rbac"
+)
+
+func TestValidateCustomRole(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		Name  string
+		Role  rbac.Role
+		Valid bool
+	}{
+		{
+			Name: "Valid",
+			Role: rbac.Role{
+				Name:        "template-scheduler",
+				DisplayName: "Template Scheduler",
+				Site: rbac.Permissions(map[string][]rbac.Action{
+					rbac.ResourceTemplate.Type: {rbac.ActionRead, rbac.ActionUpdate},
+				}),
+				User: rbac.Permissions(map[string][]rbac.Action{
+					rbac.ResourceWorkspace.Type: {rbac.ActionRead},
+				}),
+			},
+			Valid: true,
+		},
+		{
+			Name: "EmptyName",
+			Role: rbac.Role{DisplayName: "Empty"},
+		},
+		{
+			Name: "OrgRole",
+			Role: rbac.Role{Name: "scheduler:" + uuid.NewString(), DisplayName: "Scheduler"},
+		},
+		{
+			Name: "BuiltIn",
+			Role: rbac.Role{Name: "owner", DisplayName: "Owner"},
+		},
+		{
+			Name: "System",
+			Role: rbac.Role{Name: "system", DisplayName: "System"},
+		},
+		{
+			Name: "NoDisplayName",
+			Role: rbac.Role{Name: "scheduler"},
+		},
+		{
+			Name: "OrgPermissions",
+			Role: rbac.Role{
+				Name:        "scheduler",
+				DisplayName: "Scheduler",
+				Org: map[string][]rbac.Permission{
+					uuid.NewString(): rbac.Permissions(map[string][]rbac.Action{
+						rbac.ResourceTemplate.Type: {rbac.ActionUpdate},
+					}),
+				},
+			},
+		},
+		{
+			Name: "Wildcard",
+			Role: rbac.Role{
+				Name:        "scheduler",
+				DisplayName: "Scheduler",
+				Site: rbac.Permissions(map[string][]rbac.Action{
+					rbac.ResourceWildcard.Type: {rbac.ActionRead},
+				}),
+			},
+		},
+		{
+			Name: "UnknownResource",
+			Role: rbac.Role{
+				Name:        "scheduler",
+				DisplayName: "Scheduler",
+				Site: rbac.Permissions(map[string][]rbac.Action{
+					"unknown": {rbac.ActionRead},
+				}),
+			},
+		},
+		{
+			Name: "UnknownAction",
+			Role: rbac.Role{
+				Name:        "scheduler",
+				DisplayName: "Scheduler",
+				User: rbac.Permissions(map[string][]rbac.Action{
+					rbac.ResourceWorkspace.Type: {"execute"},
+				}),
+			},
+		},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			err := rbac.ValidateCustomRole(c.Role)
+			if c.Valid {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestCustomRole(t *testing.T) {
+	t.Parallel()
+
+	// Custom roles are global, so the name must be unique to this test.
+	name := "scheduler-" + uuid.NewString()[:8]
+	err := rbac.SetCustomRole(rbac.Role{
+		Name:        name,
+		DisplayName: "Scheduler",
+		Site: rbac.Permissions(map[string][]rbac.Action{
+			rbac.ResourceTemplate.Type: {rbac.ActionRead, rbac.ActionUpdate},
+		}),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { rbac.DeleteCustomRole(name) })
+
+	role, err := rbac.RoleByName(name)
+	require.NoError(t, err)
+	require.Equal(t, "Scheduler", role.DisplayName)
+	require.Contains(t, customRoleNames(rbac.CustomRoles()), name)
+
+	// Custom roles are never organization roles.
+	_, err = rbac.RoleByName(name + ":" + uuid.NewString())
+	require.Error(t, err)
+
+	// Only owners can assign custom roles.
+	require.True(t, rbac.CanAssignRole(rbac.RoleNames{rbac.RoleOwner()}, name))
+	require.False(t, rbac.CanAssignRole(rbac.RoleNames{rbac.RoleUserAdmin()}, name))
+	require.False(t, rbac.CanAssignRole(rbac.RoleNames{rbac.RoleMember()}, name))
+
+	auth := rbac.NewCachingAuthorizer(prometheus.NewRegistry())
+	subject := rbac.Subject{
+		ID:    uuid.NewString(),
+		Roles: rbac.RoleNames{rbac.RoleMember(), name},
+		Scope: rbac.ScopeAll,
+	}
+	err = auth.Authorize(context.Background(), subject, rbac.ActionUpdate, rbac.ResourceTemplate.InOrg(uuid.New()))
+	require.NoError(t, err)
+	err = auth.Authorize(context.Background(), subject, rbac.ActionDelete, rbac.ResourceTemplate.InOrg(uuid.New()))
+	require.Error(t, err)
+
+	rbac.DeleteCustomRole(name)
+	_, err = rbac.RoleByName(name)
+	require.Error(t, err)
+	require.False(t, rbac.CanAssignRole(rbac.RoleNames{rbac.RoleOwner()}, name))
+}
+
+func customRoleNames(roles []rbac.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
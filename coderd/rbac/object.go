@@ -124,6 +124,16 @@ var (
 		Type: "assign_org_role",
 	}
 
+	// ResourceCustomRole is a site wide role defined by an admin.
+	// Never has an owner or org.
+	//	create  = Define a new custom role
+	//	update  = Change the permissions of a custom role
+	//	read	= View custom roles and their permissions
+	//	delete	= Delete a custom role
+	ResourceCustomRole = Object{
+		Type: "custom_role",
+	}
+
 	// ResourceAPIKey is owned by a user.
 	//	create  = Create a new api key for user
 	//	update  = ??
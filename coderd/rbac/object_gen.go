@@ -5,6 +5,7 @@ func AllResources() []Object {
 	return []Object{
 		ResourceAPIKey,
 		ResourceAuditLog,
+		ResourceCustomRole,
 		ResourceDebugInfo,
 		ResourceDeploymentStats,
 		ResourceDeploymentValues,
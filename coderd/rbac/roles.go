@@ -19,6 +19,10 @@ const (
 
 	orgAdmin  string = "organization-admin"
 	orgMember string = "organization-member"
+
+	// anyCustomRole is used in assignRoles to allow assigning all custom
+	// roles. It is not a valid role name, so it never matches a role.
+	anyCustomRole string = "*custom"
 )
 
 func init() {
@@ -298,6 +302,7 @@ var assignRoles = map[string]map[string]bool{
 		orgMember:     true,
 		templateAdmin: true,
 		userAdmin:     true,
+		anyCustomRole: true,
 	},
 	owner: {
 		owner:         true,
@@ -307,6 +312,7 @@ var assignRoles = map[string]map[string]bool{
 		orgMember:     true,
 		templateAdmin: true,
 		userAdmin:     true,
+		anyCustomRole: true,
 	},
 	userAdmin: {
 		member:    true,
@@ -390,6 +396,8 @@ func CanAssignRole(expandable ExpandableRoles, assignedRole string) bool {
 	if err != nil {
 		return false
 	}
+	_, isCustomRole := customRoleByName(assigned)
+	isCustomRole = isCustomRole && assignedOrg == "" && !IsBuiltInRole(assigned)
 
 	for _, longRole := range roles {
 		role, orgID, err := roleSplit(longRole)
@@ -407,7 +415,7 @@ func CanAssignRole(expandable ExpandableRoles, assignedRole string) bool {
 			continue
 		}
 
-		if allowed[assigned] {
+		if allowed[assigned] || (isCustomRole && allowed[anyCustomRole]) {
 			return true
 		}
 	}
@@ -428,6 +436,10 @@ func RoleByName(name string) (Role, error) {
 
 	roleFunc, ok := builtInRoles[roleName]
 	if !ok {
+		// Custom roles are site wide, so they never have an org id.
+		if role, ok := customRoleByName(roleName); ok && orgID == "" {
+			return role, nil
+		}
 		// No role found
 		return Role{}, xerrors.Errorf("role %q not found", roleName)
 	}
@@ -34,8 +34,28 @@ func WorkspaceAgentScope(workspaceID, ownerID uuid.UUID) Scope {
 const (
 	ScopeAll                ScopeName = "all"
 	ScopeApplicationConnect ScopeName = "application_connect"
+	ScopeWorkspaceRead      ScopeName = "workspace:read"
+	ScopeWorkspaceSSH       ScopeName = "workspace:ssh"
+	ScopeWorkspaceApps      ScopeName = "workspace:apps"
+	ScopeTemplateRead       ScopeName = "template:read"
 )
 
+// workspaceReadPermissions allow listing workspaces, and reading the users,
+// organizations and templates they refer to. Scopes that connect to
+// workspaces include them, since the workspace has to be looked up first.
+func workspaceReadPermissions(extra map[string][]Action) map[string][]Action {
+	perms := map[string][]Action{
+		ResourceWorkspace.Type:    {ActionRead},
+		ResourceTemplate.Type:     {ActionRead},
+		ResourceUser.Type:         {ActionRead},
+		ResourceOrganization.Type: {ActionRead},
+	}
+	for resource, actions := range extra {
+		perms[resource] = actions
+	}
+	return perms
+}
+
 // TODO: Support passing in scopeID list for allowlisting resources.
 var builtinScopes = map[ScopeName]Scope{
 	// ScopeAll is a special scope that allows access to all resources. During
@@ -65,6 +85,58 @@ var builtinScopes = map[ScopeName]Scope{
 		},
 		AllowIDList: []string{WildcardSymbol},
 	},
+
+	ScopeWorkspaceRead: {
+		Role: Role{
+			Name:        fmt.Sprintf("Scope_%s", ScopeWorkspaceRead),
+			DisplayName: "Ability to read workspaces",
+			Site:        Permissions(workspaceReadPermissions(nil)),
+			Org:         map[string][]Permission{},
+			User:        []Permission{},
+		},
+		AllowIDList: []string{WildcardSymbol},
+	},
+
+	ScopeWorkspaceSSH: {
+		Role: Role{
+			Name:        fmt.Sprintf("Scope_%s", ScopeWorkspaceSSH),
+			DisplayName: "Ability to connect to workspaces over SSH",
+			Site: Permissions(workspaceReadPermissions(map[string][]Action{
+				ResourceWorkspaceExecution.Type: {ActionCreate},
+			})),
+			Org:  map[string][]Permission{},
+			User: []Permission{},
+		},
+		AllowIDList: []string{WildcardSymbol},
+	},
+
+	ScopeWorkspaceApps: {
+		Role: Role{
+			Name:        fmt.Sprintf("Scope_%s", ScopeWorkspaceApps),
+			DisplayName: "Ability to connect to workspace applications",
+			Site: Permissions(workspaceReadPermissions(map[string][]Action{
+				ResourceWorkspaceApplicationConnect.Type: {ActionCreate},
+			})),
+			Org:  map[string][]Permission{},
+			User: []Permission{},
+		},
+		AllowIDList: []string{WildcardSymbol},
+	},
+
+	ScopeTemplateRead: {
+		Role: Role{
+			Name:        fmt.Sprintf("Scope_%s", ScopeTemplateRead),
+			DisplayName: "Ability to read templates",
+			Site: Permissions(map[string][]Action{
+				ResourceTemplate.Type:     {ActionRead},
+				ResourceUser.Type:         {ActionRead},
+				ResourceOrganization.Type: {ActionRead},
+			}),
+			Org:  map[string][]Permission{},
+			User: []Permission{},
+		},
+		AllowIDList: []string{WildcardSymbol},
+	},
 }
 
 type ExpandableScope interface {
@@ -3,6 +3,7 @@ package coderd
 import (
 	"net/http"
 
+	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/codersdk"
 
@@ -28,6 +29,20 @@ func (api *API) assignableSiteRoles(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	roles := rbac.SiteRoles()
+	// Custom roles are listed from the database instead of the rbac package,
+	// so only the roles of this deployment are returned.
+	// nolint:gocritic // Any user that can read role assignments can see the custom roles.
+	customRoles, err := api.Database.GetCustomRoles(dbauthz.AsSystemRestricted(ctx))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching custom roles.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	for _, customRole := range customRoles {
+		roles = append(roles, rbac.Role{Name: customRole.Name, DisplayName: customRole.DisplayName})
+	}
 	httpapi.Write(ctx, rw, http.StatusOK, assignableRoles(actorRoles.Actor.Roles, roles))
 }
 
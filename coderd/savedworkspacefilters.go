@@ -0,0 +1,233 @@
+package coderd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/searchquery"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get saved workspace filters
+// @ID get-saved-workspace-filters
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param user path string true "User ID, name, or me"
+// @Success 200 {array} codersdk.SavedWorkspaceFilter
+// @Router /users/{user}/workspace-filters [get]
+func (api *API) savedWorkspaceFilters(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	filters, err := api.Database.GetSavedWorkspaceFiltersByUserID(ctx, user.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching saved workspace filters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	sdkFilters := make([]codersdk.SavedWorkspaceFilter, 0, len(filters))
+	for _, filter := range filters {
+		sdkFilters = append(sdkFilters, convertSavedWorkspaceFilter(filter))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, sdkFilters)
+}
+
+// @Summary Get saved workspace filter by name
+// @ID get-saved-workspace-filter-by-name
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param user path string true "User ID, name, or me"
+// @Param name path string true "Filter name"
+// @Success 200 {object} codersdk.SavedWorkspaceFilter
+// @Router /users/{user}/workspace-filters/{name} [get]
+func (api *API) savedWorkspaceFilter(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	filter, err := api.Database.GetSavedWorkspaceFilterByUserIDAndName(ctx, database.GetSavedWorkspaceFilterByUserIDAndNameParams{
+		UserID: user.ID,
+		Name:   chi.URLParam(r, "name"),
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching saved workspace filter.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertSavedWorkspaceFilter(filter))
+}
+
+// @Summary Create saved workspace filter
+// @ID create-saved-workspace-filter
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Workspaces
+// @Param user path string true "User ID, name, or me"
+// @Param request body codersdk.CreateSavedWorkspaceFilterRequest true "Create saved workspace filter request"
+// @Success 201 {object} codersdk.SavedWorkspaceFilter
+// @Router /users/{user}/workspace-filters [post]
+func (api *API) postSavedWorkspaceFilter(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	var req codersdk.CreateSavedWorkspaceFilterRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if !validateSavedWorkspaceFilterQuery(rw, r, req.Query) {
+		return
+	}
+
+	now := database.Now()
+	filter, err := api.Database.InsertSavedWorkspaceFilter(ctx, database.InsertSavedWorkspaceFilterParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Name:      req.Name,
+		Query:     req.Query,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if database.IsUniqueViolation(err, database.UniqueSavedWorkspaceFiltersUserIDNameKey) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("Saved workspace filter with name %q already exists.", req.Name),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating saved workspace filter.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertSavedWorkspaceFilter(filter))
+}
+
+// @Summary Update saved workspace filter
+// @ID update-saved-workspace-filter
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Workspaces
+// @Param user path string true "User ID, name, or me"
+// @Param name path string true "Filter name"
+// @Param request body codersdk.UpdateSavedWorkspaceFilterRequest true "Update saved workspace filter request"
+// @Success 200 {object} codersdk.SavedWorkspaceFilter
+// @Router /users/{user}/workspace-filters/{name} [patch]
+func (api *API) patchSavedWorkspaceFilter(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	var req codersdk.UpdateSavedWorkspaceFilterRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if !validateSavedWorkspaceFilterQuery(rw, r, req.Query) {
+		return
+	}
+
+	filter, err := api.Database.UpdateSavedWorkspaceFilter(ctx, database.UpdateSavedWorkspaceFilterParams{
+		UserID:    user.ID,
+		Name:      chi.URLParam(r, "name"),
+		Query:     req.Query,
+		UpdatedAt: database.Now(),
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating saved workspace filter.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertSavedWorkspaceFilter(filter))
+}
+
+// @Summary Delete saved workspace filter
+// @ID delete-saved-workspace-filter
+// @Security CoderSessionToken
+// @Tags Workspaces
+// @Param user path string true "User ID, name, or me"
+// @Param name path string true "Filter name"
+// @Success 204
+// @Router /users/{user}/workspace-filters/{name} [delete]
+func (api *API) deleteSavedWorkspaceFilter(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	err := api.Database.DeleteSavedWorkspaceFilter(ctx, database.DeleteSavedWorkspaceFilterParams{
+		UserID: user.ID,
+		Name:   chi.URLParam(r, "name"),
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting saved workspace filter.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// validateSavedWorkspaceFilterQuery parses the query like the workspaces
+// endpoint does, so invalid filters are rejected when they're saved instead
+// of when they're used.
+func validateSavedWorkspaceFilterQuery(rw http.ResponseWriter, r *http.Request, query string) bool {
+	_, _, errs := searchquery.Workspaces(query, codersdk.Pagination{}, 0)
+	if len(errs) > 0 {
+		httpapi.Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid workspace search query.",
+			Validations: errs,
+		})
+		return false
+	}
+	return true
+}
+
+func convertSavedWorkspaceFilter(filter database.SavedWorkspaceFilter) codersdk.SavedWorkspaceFilter {
+	return codersdk.SavedWorkspaceFilter{
+		ID:        filter.ID,
+		UserID:    filter.UserID,
+		Name:      filter.Name,
+		Query:     filter.Query,
+		CreatedAt: filter.CreatedAt,
+		UpdatedAt: filter.UpdatedAt,
+	}
+}
@@ -0,0 +1,100 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestSavedWorkspaceFilters(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		filter, err := client.CreateSavedWorkspaceFilter(ctx, codersdk.Me, codersdk.CreateSavedWorkspaceFilterRequest{
+			Name:  "outdated",
+			Query: "outdated:true status:running",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "outdated", filter.Name)
+		require.Equal(t, "outdated:true status:running", filter.Query)
+
+		_, err = client.CreateSavedWorkspaceFilter(ctx, codersdk.Me, codersdk.CreateSavedWorkspaceFilterRequest{
+			Name:  "outdated",
+			Query: "outdated:true",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		filter, err = client.UpdateSavedWorkspaceFilter(ctx, codersdk.Me, "outdated", codersdk.UpdateSavedWorkspaceFilterRequest{
+			Query: "outdated:true",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "outdated:true", filter.Query)
+
+		got, err := client.SavedWorkspaceFilter(ctx, codersdk.Me, "outdated")
+		require.NoError(t, err)
+		require.Equal(t, filter, got)
+
+		filters, err := client.SavedWorkspaceFilters(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, filters, 1)
+		require.Equal(t, filter, filters[0])
+
+		err = client.DeleteSavedWorkspaceFilter(ctx, codersdk.Me, "outdated")
+		require.NoError(t, err)
+		err = client.DeleteSavedWorkspaceFilter(ctx, codersdk.Me, "outdated")
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		filters, err = client.SavedWorkspaceFilters(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Empty(t, filters)
+	})
+
+	t.Run("InvalidQuery", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		_, err := client.CreateSavedWorkspaceFilter(ctx, codersdk.Me, codersdk.CreateSavedWorkspaceFilterRequest{
+			Name:  "broken",
+			Query: "lock_reason:bored",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "lock_reason", apiErr.Validations[0].Field)
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, nil)
+		owner := coderdtest.CreateFirstUser(t, client)
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+
+		_, err := client.CreateSavedWorkspaceFilter(ctx, codersdk.Me, codersdk.CreateSavedWorkspaceFilterRequest{
+			Name:  "running",
+			Query: "status:running",
+		})
+		require.NoError(t, err)
+
+		_, err = memberClient.SavedWorkspaceFilters(ctx, owner.UserID.String())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
@@ -2,8 +2,10 @@ package schedule
 
 import (
 	"context"
+	"hash/fnv"
 	"time"
 
+	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/xerrors"
@@ -38,12 +40,19 @@ const (
 	//      This resolves that problem by subtracting 15 minutes from midnight
 	//      when we check the next cron time.
 	restartRequirementBuffer = -15 * time.Minute
+
+	// holidayDateLayout is the layout used to compare stop days against
+	// schedule holidays.
+	holidayDateLayout = "2006-01-02"
 )
 
 type CalculateAutostopParams struct {
 	Database                    database.Store
 	TemplateScheduleStore       TemplateScheduleStore
 	UserQuietHoursScheduleStore UserQuietHoursScheduleStore
+	// Holidays caches the schedule holidays. If nil, they're loaded from the
+	// database.
+	Holidays *HolidayCache
 
 	Now       time.Time
 	Workspace database.Workspace
@@ -64,13 +73,18 @@ type AutostopTime struct {
 // doesn't see any new activity (such as SSH, app requests, etc.). When activity
 // is detected the deadline is bumped by the workspace's TTL (this only happens
 // when activity is detected and more than 20% of the TTL has passed to save
-// database queries).
+// database queries). If the template has an activity bump set, the deadline is
+// bumped by that instead, and the initial deadline is capped to it so idle
+// workspaces are stopped early.
 //
 // MaxDeadline is the maximum value for deadline. The deadline cannot be bumped
 // past this value, so it denotes the absolute deadline that the workspace build
 // must be stopped by. MaxDeadline is calculated using the template's "restart
 // requirement" settings and the user's "quiet hours" settings to pick a time
-// outside of working hours.
+// outside of working hours. If the template updates workspaces on restart, the
+// autobuild executor starts workspaces that were stopped at their max deadline
+// again on the active template version, and the new build gets the next max
+// deadline.
 //
 // Deadline is a cost saving measure, while max deadline is a
 // compliance/updating measure.
@@ -97,7 +111,7 @@ func CalculateAutostop(ctx context.Context, params CalculateAutostopParams) (Aut
 		autostop.Deadline = now.Add(time.Duration(workspace.Ttl.Int64))
 	}
 
-	templateSchedule, err := params.TemplateScheduleStore.Get(ctx, db, workspace.TemplateID)
+	templateSchedule, err := params.TemplateScheduleStore.GetForWorkspace(ctx, db, workspace)
 	if err != nil {
 		return autostop, xerrors.Errorf("get template schedule options: %w", err)
 	}
@@ -109,6 +123,15 @@ func CalculateAutostop(ctx context.Context, params CalculateAutostopParams) (Aut
 			autostop.Deadline = now.Add(templateSchedule.DefaultTTL)
 		}
 	}
+	if templateSchedule.ActivityBumpTTL > 0 && !autostop.Deadline.IsZero() {
+		// The workspace is stopped if it doesn't see any activity within the
+		// activity bump. Activity extends the deadline from there, so the
+		// workspace can end up running for longer than its TTL.
+		idleDeadline := now.Add(templateSchedule.ActivityBumpTTL)
+		if idleDeadline.Before(autostop.Deadline) {
+			autostop.Deadline = idleDeadline
+		}
+	}
 
 	// Use the old algorithm for calculating max_deadline if the instance isn't
 	// configured or entitled to use the new feature flag yet.
@@ -133,7 +156,20 @@ func CalculateAutostop(ctx context.Context, params CalculateAutostopParams) (Aut
 		// use quiet hours or the default schedule has not been set. In this
 		// case, do not set a max deadline on the workspace.
 		if userQuietHoursSchedule.Schedule != nil {
-			loc := userQuietHoursSchedule.Schedule.Location()
+			quietHoursSchedule := userQuietHoursSchedule.Schedule
+
+			// If the template anchors the restart requirement to a specific
+			// timezone, evaluate the user's quiet hours in that timezone
+			// instead of the user's own timezone.
+			templateLoc, err := templateSchedule.RestartRequirement.Location()
+			if err != nil {
+				return autostop, xerrors.Errorf("get template restart requirement location: %w", err)
+			}
+			if templateLoc != nil {
+				quietHoursSchedule = quietHoursSchedule.InLocation(templateLoc)
+			}
+
+			loc := quietHoursSchedule.Location()
 			now := now.In(loc)
 			// Add the leeway here so we avoid checking today's quiet hours if
 			// the workspace was started <1h before midnight.
@@ -155,18 +191,36 @@ func CalculateAutostop(ctx context.Context, params CalculateAutostopParams) (Aut
 			// Allow an hour of leeway (i.e. any workspaces started within an
 			// hour of the scheduled stop time will always bounce to the next
 			// stop window).
-			checkSchedule := userQuietHoursSchedule.Schedule.Next(startOfStopDay.Add(restartRequirementBuffer))
+			checkSchedule := quietHoursSchedule.Next(startOfStopDay.Add(restartRequirementBuffer))
 			if checkSchedule.Before(now.Add(restartRequirementLeeway)) {
 				// Set the first stop day we try to tomorrow because today's
 				// schedule is too close to now or has already passed.
 				startOfStopDay = nextDayMidnight(startOfStopDay)
 			}
 
-			// Iterate from 0 to 7, check if the current startOfDay is in the
-			// restart requirement. If it isn't then add a day and try again.
+			// Restart requirements are skipped on holidays and on the days the
+			// user has asked to skip their quiet hours. Both are plain dates,
+			// so they're compared against the date of the stop day in the
+			// quiet hours timezone.
+			holidays, err := params.Holidays.Get(ctx, db)
+			if err != nil {
+				return autostop, xerrors.Errorf("get schedule holidays: %w", err)
+			}
+			skipDates := make(map[string]struct{}, len(holidays)+len(userQuietHoursSchedule.Exceptions))
+			for _, holiday := range holidays {
+				skipDates[holiday.Date.Format(holidayDateLayout)] = struct{}{}
+			}
+			for _, exception := range userQuietHoursSchedule.Exceptions {
+				skipDates[exception.Format(holidayDateLayout)] = struct{}{}
+			}
+
+			// Iterate from 0 to 7 (plus a week for each skipped date), check
+			// if the current startOfDay is in the restart requirement and
+			// isn't skipped. If it isn't then add a day and try again.
 			requirementDays := templateSchedule.RestartRequirement.DaysMap()
-			for i := 0; i < len(DaysOfWeek)+1; i++ {
-				if i == len(DaysOfWeek) {
+			maxDays := len(DaysOfWeek) * (len(skipDates) + 1)
+			for i := 0; i < maxDays+1; i++ {
+				if i == maxDays {
 					// We've wrapped, so somehow we couldn't find a day in the
 					// restart requirement in the next week.
 					//
@@ -174,13 +228,15 @@ func CalculateAutostop(ctx context.Context, params CalculateAutostopParams) (Aut
 					// checked that there is a day in the restart requirement
 					// above with the
 					// `if templateSchedule.RestartRequirement.DaysOfWeek != 0`
-					// check.
+					// check, and each skipped date can only skip a single
+					// occurrence of the restart requirement.
 					//
 					// The eighth bit shouldn't be set, as we validate the
 					// bitmap in the enterprise TemplateScheduleStore.
-					return autostop, xerrors.New("could not find suitable day for template restart requirement in the next 7 days")
+					return autostop, xerrors.Errorf("could not find suitable day for template restart requirement in the next %d days", maxDays)
 				}
-				if requirementDays[startOfStopDay.Weekday()] {
+				_, skip := skipDates[startOfStopDay.Format(holidayDateLayout)]
+				if requirementDays[startOfStopDay.Weekday()] && !skip {
 					break
 				}
 				startOfStopDay = nextDayMidnight(startOfStopDay)
@@ -198,10 +254,14 @@ func CalculateAutostop(ctx context.Context, params CalculateAutostopParams) (Aut
 			}
 
 			// Get the next occurrence of the restart schedule.
-			autostop.MaxDeadline = userQuietHoursSchedule.Schedule.Next(checkTime)
+			autostop.MaxDeadline = quietHoursSchedule.Next(checkTime)
 			if autostop.MaxDeadline.IsZero() {
 				return autostop, xerrors.New("could not find next occurrence of template restart requirement in user quiet hours schedule")
 			}
+
+			// Spread the max deadlines of the template's workspaces across
+			// the spread window, so they don't all restart at the same time.
+			autostop.MaxDeadline = autostop.MaxDeadline.Add(restartRequirementSpreadOffset(workspace.ID, templateSchedule.RestartRequirementSpread))
 		}
 	}
 
@@ -220,6 +280,18 @@ func CalculateAutostop(ctx context.Context, params CalculateAutostopParams) (Aut
 	return autostop, nil
 }
 
+// restartRequirementSpreadOffset returns the offset in [0, spread) that is
+// added to the max deadline of the workspace. The offset is derived from the
+// workspace ID, so it's the same every time the deadline is calculated.
+func restartRequirementSpreadOffset(workspaceID uuid.UUID, spread time.Duration) time.Duration {
+	if spread <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(workspaceID[:])
+	return time.Duration(h.Sum64() % uint64(spread)).Truncate(time.Second)
+}
+
 // truncateMidnight truncates a time to midnight in the time object's timezone.
 // t.Truncate(24 * time.Hour) truncates based on the internal time and doesn't
 // factor daylight savings properly.
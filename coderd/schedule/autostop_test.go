@@ -72,6 +72,8 @@ func TestCalculateAutoStop(t *testing.T) {
 		now                   time.Time
 		templateAllowAutostop bool
 		templateDefaultTTL    time.Duration
+		// templateActivityBump caps the initial deadline of the workspace.
+		templateActivityBump time.Duration
 		// TODO(@dean): remove max_ttl tests
 		useMaxTTL                  bool
 		templateMaxTTL             time.Duration
@@ -81,6 +83,11 @@ func TestCalculateAutoStop(t *testing.T) {
 		// workspace is made, so it takes precedence unless
 		// templateAllowAutostop is false.
 		workspaceTTL time.Duration
+		// holidays are inserted into the database as schedule holidays.
+		holidays []time.Time
+		// quietHoursExceptions are returned as the user's quiet hours
+		// exceptions.
+		quietHoursExceptions []time.Time
 
 		// expectedDeadline is copied from expectedMaxDeadline if unset.
 		expectedDeadline    time.Time
@@ -147,6 +154,34 @@ func TestCalculateAutoStop(t *testing.T) {
 			expectedDeadline:           now.Add(3 * time.Hour),
 			expectedMaxDeadline:        time.Time{},
 		},
+		{
+			name:                  "TemplateActivityBump",
+			now:                   now,
+			templateAllowAutostop: true,
+			templateActivityBump:  30 * time.Minute,
+			workspaceTTL:          8 * time.Hour,
+			expectedDeadline:      now.Add(30 * time.Minute),
+			expectedMaxDeadline:   time.Time{},
+		},
+		{
+			name:                  "TemplateActivityBumpLongerThanTTL",
+			now:                   now,
+			templateAllowAutostop: true,
+			templateActivityBump:  2 * time.Hour,
+			workspaceTTL:          time.Hour,
+			expectedDeadline:      now.Add(time.Hour),
+			expectedMaxDeadline:   time.Time{},
+		},
+		{
+			// Workspaces without a TTL are never stopped for being idle.
+			name:                  "TemplateActivityBumpNoTTL",
+			now:                   now,
+			templateAllowAutostop: true,
+			templateActivityBump:  30 * time.Minute,
+			workspaceTTL:          0,
+			expectedDeadline:      time.Time{},
+			expectedMaxDeadline:   time.Time{},
+		},
 		{
 			name:                   "TemplateRestartRequirement",
 			now:                    wednesdayMidnightUTC,
@@ -161,6 +196,37 @@ func TestCalculateAutoStop(t *testing.T) {
 			// expectedDeadline is copied from expectedMaxDeadline.
 			expectedMaxDeadline: saturdayMidnightSydney.In(time.UTC),
 		},
+		{
+			// The user's quiet hours should be evaluated in the template's
+			// timezone rather than the user's own timezone.
+			name:                   "TemplateRestartRequirementTimezone",
+			now:                    wednesdayMidnightUTC,
+			templateAllowAutostop:  true,
+			templateDefaultTTL:     0,
+			userQuietHoursSchedule: "CRON_TZ=America/Chicago 0 0 * * *",
+			templateRestartRequirement: schedule.TemplateRestartRequirement{
+				DaysOfWeek: 0b00100000, // Saturday
+				Weeks:      0,          // weekly
+				Timezone:   "Australia/Sydney",
+			},
+			workspaceTTL: 0,
+			// expectedDeadline is copied from expectedMaxDeadline.
+			expectedMaxDeadline: saturdayMidnightSydney.In(time.UTC),
+		},
+		{
+			name:                   "TemplateRestartRequirementInvalidTimezone",
+			now:                    wednesdayMidnightUTC,
+			templateAllowAutostop:  true,
+			templateDefaultTTL:     0,
+			userQuietHoursSchedule: sydneyQuietHours,
+			templateRestartRequirement: schedule.TemplateRestartRequirement{
+				DaysOfWeek: 0b00100000, // Saturday
+				Weeks:      0,          // weekly
+				Timezone:   "Not/AZone",
+			},
+			workspaceTTL: 0,
+			errContains:  "invalid restart requirement timezone",
+		},
 		{
 			name:                   "TemplateRestartRequirement1HourSkip",
 			now:                    saturdayMidnightSydney.Add(-59 * time.Minute),
@@ -191,6 +257,75 @@ func TestCalculateAutoStop(t *testing.T) {
 			// expectedDeadline is copied from expectedMaxDeadline.
 			expectedMaxDeadline: saturdayMidnightSydney.In(time.UTC),
 		},
+		{
+			// Restart requirements that fall on a holiday should be skipped
+			// until the next applicable day.
+			name:                   "TemplateRestartRequirementHoliday",
+			now:                    wednesdayMidnightUTC,
+			templateAllowAutostop:  true,
+			templateDefaultTTL:     0,
+			userQuietHoursSchedule: sydneyQuietHours,
+			templateRestartRequirement: schedule.TemplateRestartRequirement{
+				DaysOfWeek: 0b00100000, // Saturday
+				Weeks:      0,          // weekly
+			},
+			workspaceTTL: 0,
+			holidays:     []time.Time{time.Date(2023, 2, 11, 0, 0, 0, 0, time.UTC)},
+			// expectedDeadline is copied from expectedMaxDeadline.
+			expectedMaxDeadline: saturdayMidnightSydney.AddDate(0, 0, 7).In(time.UTC),
+		},
+		{
+			name:                   "TemplateRestartRequirementDailyHoliday",
+			now:                    fridayEveningSydney,
+			templateAllowAutostop:  true,
+			templateDefaultTTL:     0,
+			userQuietHoursSchedule: sydneyQuietHours,
+			templateRestartRequirement: schedule.TemplateRestartRequirement{
+				DaysOfWeek: 0b01111111, // daily
+				Weeks:      0,          // all weeks
+			},
+			workspaceTTL: 0,
+			holidays: []time.Time{
+				time.Date(2023, 2, 11, 0, 0, 0, 0, time.UTC),
+				time.Date(2023, 2, 12, 0, 0, 0, 0, time.UTC),
+			},
+			// expectedDeadline is copied from expectedMaxDeadline.
+			expectedMaxDeadline: saturdayMidnightSydney.AddDate(0, 0, 2).In(time.UTC),
+		},
+		{
+			// Restart requirements that fall on a day the user skipped
+			// their quiet hours should be skipped until the next applicable
+			// day.
+			name:                   "TemplateRestartRequirementQuietHoursException",
+			now:                    wednesdayMidnightUTC,
+			templateAllowAutostop:  true,
+			templateDefaultTTL:     0,
+			userQuietHoursSchedule: sydneyQuietHours,
+			templateRestartRequirement: schedule.TemplateRestartRequirement{
+				DaysOfWeek: 0b00100000, // Saturday
+				Weeks:      0,          // weekly
+			},
+			workspaceTTL:         0,
+			quietHoursExceptions: []time.Time{time.Date(2023, 2, 11, 0, 0, 0, 0, time.UTC)},
+			// expectedDeadline is copied from expectedMaxDeadline.
+			expectedMaxDeadline: saturdayMidnightSydney.AddDate(0, 0, 7).In(time.UTC),
+		},
+		{
+			name:                   "TemplateRestartRequirementDailyHolidayAndQuietHoursException",
+			now:                    fridayEveningSydney,
+			templateAllowAutostop:  true,
+			templateDefaultTTL:     0,
+			userQuietHoursSchedule: sydneyQuietHours,
+			templateRestartRequirement: schedule.TemplateRestartRequirement{
+				DaysOfWeek: 0b01111111, // daily
+				Weeks:      0,          // all weeks
+			},
+			workspaceTTL:         0,
+			holidays:             []time.Time{time.Date(2023, 2, 11, 0, 0, 0, 0, time.UTC)},
+			quietHoursExceptions: []time.Time{time.Date(2023, 2, 12, 0, 0, 0, 0, time.UTC)},
+			// expectedDeadline is copied from expectedMaxDeadline.
+			expectedMaxDeadline: saturdayMidnightSydney.AddDate(0, 0, 2).In(time.UTC),
+		},
 		{
 			name:                   "TemplateRestartRequirementFortnightly/Skip",
 			now:                    wednesdayMidnightUTC,
@@ -416,6 +551,7 @@ func TestCalculateAutoStop(t *testing.T) {
 						UserAutostartEnabled:  false,
 						UserAutostopEnabled:   c.templateAllowAutostop,
 						DefaultTTL:            c.templateDefaultTTL,
+						ActivityBumpTTL:       c.templateActivityBump,
 						MaxTTL:                c.templateMaxTTL,
 						UseRestartRequirement: !c.useMaxTTL,
 						RestartRequirement:    c.templateRestartRequirement,
@@ -437,12 +573,17 @@ func TestCalculateAutoStop(t *testing.T) {
 					}
 
 					return schedule.UserQuietHoursScheduleOptions{
-						Schedule: sched,
-						UserSet:  false,
+						Schedule:   sched,
+						UserSet:    false,
+						Exceptions: c.quietHoursExceptions,
 					}, nil
 				},
 			}
 
+			for _, date := range c.holidays {
+				dbgen.ScheduleHoliday(t, db, database.ScheduleHoliday{Date: date})
+			}
+
 			org := dbgen.Organization(t, db, database.Organization{})
 			user := dbgen.User(t, db, database.User{
 				QuietHoursSchedule: c.userQuietHoursSchedule,
@@ -512,6 +653,81 @@ func TestCalculateAutoStop(t *testing.T) {
 	}
 }
 
+func TestCalculateAutoStopRestartRequirementSpread(t *testing.T) {
+	t.Parallel()
+
+	sydneyLoc, err := time.LoadLocation("Australia/Sydney")
+	require.NoError(t, err)
+	// Wednesday the 8th of February 2023 at midnight.
+	now := time.Date(2023, 2, 8, 0, 0, 0, 0, time.UTC)
+	// 12am on Saturday the 11th of February 2023 in Sydney.
+	saturdayMidnightSydney := time.Date(2023, 2, 11, 0, 0, 0, 0, sydneyLoc)
+	spread := 2 * time.Hour
+
+	db, _ := dbtestutil.NewDB(t)
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	templateScheduleStore := schedule.MockTemplateScheduleStore{
+		GetFn: func(_ context.Context, _ database.Store, _ uuid.UUID) (schedule.TemplateScheduleOptions, error) {
+			return schedule.TemplateScheduleOptions{
+				UserAutostopEnabled:   true,
+				UseRestartRequirement: true,
+				RestartRequirement: schedule.TemplateRestartRequirement{
+					DaysOfWeek: 0b00100000, // Saturday
+				},
+				RestartRequirementSpread: spread,
+			}, nil
+		},
+	}
+	userQuietHoursScheduleStore := schedule.MockUserQuietHoursScheduleStore{
+		GetFn: func(_ context.Context, _ database.Store, _ uuid.UUID) (schedule.UserQuietHoursScheduleOptions, error) {
+			sched, err := schedule.Daily("CRON_TZ=Australia/Sydney 0 0 * * *")
+			if !assert.NoError(t, err) {
+				return schedule.UserQuietHoursScheduleOptions{}, err
+			}
+			return schedule.UserQuietHoursScheduleOptions{Schedule: sched}, nil
+		},
+	}
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	user := dbgen.User(t, db, database.User{})
+	template := dbgen.Template(t, db, database.Template{
+		OrganizationID: org.ID,
+		CreatedBy:      user.ID,
+	})
+
+	maxDeadlines := map[time.Time]struct{}{}
+	for i := 0; i < 10; i++ {
+		workspace := dbgen.Workspace(t, db, database.Workspace{
+			TemplateID:     template.ID,
+			OrganizationID: org.ID,
+			OwnerID:        user.ID,
+		})
+		params := schedule.CalculateAutostopParams{
+			Database:                    db,
+			TemplateScheduleStore:       templateScheduleStore,
+			UserQuietHoursScheduleStore: userQuietHoursScheduleStore,
+			Now:                         now,
+			Workspace:                   workspace,
+		}
+
+		autostop, err := schedule.CalculateAutostop(ctx, params)
+		require.NoError(t, err)
+		require.False(t, autostop.MaxDeadline.Before(saturdayMidnightSydney), "max deadline is before the start of the quiet hours")
+		require.True(t, autostop.MaxDeadline.Before(saturdayMidnightSydney.Add(spread)), "max deadline is after the end of the spread")
+		require.Equal(t, autostop.MaxDeadline, autostop.Deadline)
+
+		// The offset is stable, so recalculating the deadlines of the
+		// workspace doesn't move them around.
+		again, err := schedule.CalculateAutostop(ctx, params)
+		require.NoError(t, err)
+		require.Equal(t, autostop.MaxDeadline, again.MaxDeadline)
+
+		maxDeadlines[autostop.MaxDeadline] = struct{}{}
+	}
+	require.Greater(t, len(maxDeadlines), 1, "max deadlines were not spread")
+}
+
 func TestFindWeek(t *testing.T) {
 	t.Parallel()
 
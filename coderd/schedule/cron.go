@@ -131,6 +131,17 @@ func (s Schedule) Location() *time.Location {
 	return s.sched.Location
 }
 
+// InLocation returns a copy of the schedule that is evaluated in the given
+// location instead of the schedule's own location.
+func (s Schedule) InLocation(loc *time.Location) *Schedule {
+	sched := *s.sched
+	sched.Location = loc
+	return &Schedule{
+		sched:   &sched,
+		cronStr: s.cronStr,
+	}
+}
+
 // Cron returns the cron spec for the schedule with the leading CRON_TZ
 // stripped, if present.
 func (s Schedule) Cron() string {
@@ -0,0 +1,80 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/coder/coder/coderd/database"
+)
+
+// DefaultHolidayCacheTTL is how long schedule holidays are cached for. Changes
+// made on the same replica are applied immediately, changes made on other
+// replicas are applied once the cache expires.
+const DefaultHolidayCacheTTL = time.Minute
+
+// HolidayCache caches the schedule holidays, so calculating the autostop of a
+// workspace build doesn't have to load them from the database every time.
+type HolidayCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	holidays  []database.ScheduleHoliday
+	expiresAt time.Time
+
+	// Custom time.Now() function to use in tests. Defaults to time.Now().
+	TimeNowFn func() time.Time
+}
+
+// NewHolidayCache returns a HolidayCache that reloads the holidays after the
+// given TTL.
+func NewHolidayCache(ttl time.Duration) *HolidayCache {
+	return &HolidayCache{ttl: ttl}
+}
+
+func (c *HolidayCache) now() time.Time {
+	if c.TimeNowFn != nil {
+		return c.TimeNowFn()
+	}
+	return time.Now()
+}
+
+// Get returns the cached holidays, loading them from the database if they
+// haven't been loaded yet or have expired. A nil cache always loads them from
+// the database.
+func (c *HolidayCache) Get(ctx context.Context, db database.Store) ([]database.ScheduleHoliday, error) {
+	if c == nil {
+		return db.GetScheduleHolidays(ctx)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.holidays != nil && c.now().Before(c.expiresAt) {
+		return c.holidays, nil
+	}
+
+	holidays, err := db.GetScheduleHolidays(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if holidays == nil {
+		// Cache the absence of holidays too.
+		holidays = []database.ScheduleHoliday{}
+	}
+	c.holidays = holidays
+	c.expiresAt = c.now().Add(c.ttl)
+	return holidays, nil
+}
+
+// Invalidate drops the cached holidays, so the next Get loads them from the
+// database. It should be called after the holidays are changed.
+func (c *HolidayCache) Invalidate() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.holidays = nil
+	c.expiresAt = time.Time{}
+}
@@ -0,0 +1,105 @@
+package schedule_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbmock"
+	"github.com/coder/coder/coderd/schedule"
+	"github.com/coder/coder/testutil"
+)
+
+func TestHolidayCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Cached", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			ctx, cancel = context.WithTimeout(context.Background(), testutil.WaitShort)
+			db          = dbmock.NewMockStore(gomock.NewController(t))
+			now         = time.Now()
+			cache       = schedule.NewHolidayCache(time.Minute)
+			holidays    = []database.ScheduleHoliday{{ID: uuid.New(), Name: "New Year's Day"}}
+		)
+		defer cancel()
+		cache.TimeNowFn = func() time.Time { return now }
+
+		db.EXPECT().GetScheduleHolidays(gomock.Any()).Return(holidays, nil).Times(2)
+
+		for i := 0; i < 3; i++ {
+			got, err := cache.Get(ctx, db)
+			require.NoError(t, err)
+			require.Equal(t, holidays, got)
+		}
+
+		// The holidays are loaded again once the cache expires.
+		now = now.Add(time.Minute)
+		got, err := cache.Get(ctx, db)
+		require.NoError(t, err)
+		require.Equal(t, holidays, got)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			ctx, cancel = context.WithTimeout(context.Background(), testutil.WaitShort)
+			db          = dbmock.NewMockStore(gomock.NewController(t))
+			cache       = schedule.NewHolidayCache(time.Minute)
+		)
+		defer cancel()
+
+		db.EXPECT().GetScheduleHolidays(gomock.Any()).Return(nil, nil).Times(1)
+
+		for i := 0; i < 2; i++ {
+			got, err := cache.Get(ctx, db)
+			require.NoError(t, err)
+			require.Empty(t, got)
+		}
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			ctx, cancel = context.WithTimeout(context.Background(), testutil.WaitShort)
+			db          = dbmock.NewMockStore(gomock.NewController(t))
+			cache       = schedule.NewHolidayCache(time.Minute)
+		)
+		defer cancel()
+
+		db.EXPECT().GetScheduleHolidays(gomock.Any()).Return([]database.ScheduleHoliday{}, nil).Times(2)
+
+		_, err := cache.Get(ctx, db)
+		require.NoError(t, err)
+		cache.Invalidate()
+		_, err = cache.Get(ctx, db)
+		require.NoError(t, err)
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			ctx, cancel = context.WithTimeout(context.Background(), testutil.WaitShort)
+			db          = dbmock.NewMockStore(gomock.NewController(t))
+			cache       *schedule.HolidayCache
+		)
+		defer cancel()
+
+		db.EXPECT().GetScheduleHolidays(gomock.Any()).Return([]database.ScheduleHoliday{}, nil).Times(2)
+
+		for i := 0; i < 2; i++ {
+			_, err := cache.Get(ctx, db)
+			require.NoError(t, err)
+		}
+		cache.Invalidate()
+	})
+}
@@ -0,0 +1,230 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// AutostartFormats describes the formats accepted by Autostart. It is
+// included in errors returned for invalid schedules.
+const AutostartFormats = `a schedule such as "weekdays 9am Europe/Berlin", "mon,wed,fri 17:30" or "daily 8:30am", ` +
+	`or a cron expression such as "CRON_TZ=Europe/Berlin 0 9 * * 1-5"`
+
+var humanWeekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"sun":       time.Sunday,
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tue":       time.Tuesday,
+	"tues":      time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thu":       time.Thursday,
+	"thur":      time.Thursday,
+	"thurs":     time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+	"sat":       time.Saturday,
+}
+
+// Autostart parses a Schedule for a workspace autostart schedule. In
+// addition to the cron expressions accepted by Weekly, it accepts the
+// human-readable schedules accepted by Human, evaluated in UTC unless a
+// timezone is specified. The error explains the accepted formats.
+func Autostart(raw string) (*Schedule, error) {
+	sched, cronErr := Weekly(raw)
+	if cronErr == nil {
+		return sched, nil
+	}
+	sched, humanErr := Human(raw, time.UTC)
+	if humanErr == nil {
+		return sched, nil
+	}
+
+	// Report the error of the format the user most likely attempted.
+	err := humanErr
+	if strings.HasPrefix(raw, "CRON_TZ=") || len(strings.Fields(raw)) >= 5 {
+		err = cronErr
+	}
+	return nil, xerrors.Errorf("invalid schedule %q: %s. Expected %s", raw, err.Error(), AutostartFormats)
+}
+
+// Human parses a Schedule from a human-readable weekly schedule. The schedule
+// consists of the following space-delimited fields, in any order:
+// - days of week e.g. daily, weekdays, weekends, mon-fri or mon,wed,fri
+// (optional, defaults to daily)
+// - time of day e.g. 9am, 9:30am or 17:30 (required)
+// - timezone e.g. Europe/Berlin (optional, defaults to loc or UTC)
+//
+// The words "at", "on" and "every" are ignored, so schedules like "every
+// monday at 9am" are accepted as well.
+//
+// Example Usage:
+//
+//	sched, _ := schedule.Human("weekdays 9am Europe/Berlin", nil)
+//	fmt.Println(sched.String())
+//	// Output: CRON_TZ=Europe/Berlin 0 9 * * 1-5
+func Human(raw string, loc *time.Location) (*Schedule, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var (
+		days         string
+		clock        time.Time
+		haveDays     bool
+		haveClock    bool
+		haveLocation bool
+	)
+	for _, field := range strings.Fields(raw) {
+		lower := strings.ToLower(field)
+		switch lower {
+		case "at", "on", "every":
+			continue
+		}
+
+		if t, ok := parseHumanClock(lower); ok {
+			if haveClock {
+				return nil, xerrors.Errorf("more than one time of day specified")
+			}
+			clock, haveClock = t, true
+			continue
+		}
+		if d, ok := parseHumanDays(lower); ok {
+			if haveDays {
+				return nil, xerrors.Errorf("more than one set of days specified")
+			}
+			days, haveDays = d, true
+			continue
+		}
+		if l, err := time.LoadLocation(field); err == nil && field != "" && field != "Local" {
+			if haveLocation {
+				return nil, xerrors.Errorf("more than one timezone specified")
+			}
+			loc, haveLocation = l, true
+			continue
+		}
+		return nil, xerrors.Errorf(`unrecognized %q, expected a time of day such as "9am" or "17:30", days such as "weekdays", "mon-fri" or "mon,wed,fri", or an IANA timezone such as "Europe/Berlin"`, field)
+	}
+	if !haveClock {
+		return nil, xerrors.Errorf(`missing time of day such as "9am" or "17:30"`)
+	}
+	if !haveDays {
+		days = "*"
+	}
+
+	return parse(fmt.Sprintf("CRON_TZ=%s %d %d * * %s", loc.String(), clock.Minute(), clock.Hour(), days))
+}
+
+// parseHumanClock parses a time of day in 12-hour or 24-hour format.
+func parseHumanClock(s string) (time.Time, bool) {
+	switch s {
+	case "noon":
+		return time.Date(0, 1, 1, 12, 0, 0, 0, time.UTC), true
+	case "midnight":
+		return time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC), true
+	}
+	for _, layout := range []string{"3:04pm", "3pm", "15:04"} {
+		t, err := time.Parse(layout, s)
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseHumanDays parses a comma separated list of days of week or ranges of
+// days of week and returns the cron day-of-week field for them.
+func parseHumanDays(s string) (string, bool) {
+	set := map[time.Weekday]bool{}
+	for _, item := range strings.Split(s, ",") {
+		switch item {
+		case "daily", "everyday", "day", "days":
+			for d := time.Sunday; d <= time.Saturday; d++ {
+				set[d] = true
+			}
+			continue
+		case "weekday", "weekdays":
+			for d := time.Monday; d <= time.Friday; d++ {
+				set[d] = true
+			}
+			continue
+		case "weekend", "weekends":
+			set[time.Saturday] = true
+			set[time.Sunday] = true
+			continue
+		}
+
+		if from, to, ok := strings.Cut(item, "-"); ok {
+			start, ok := parseHumanWeekday(from)
+			if !ok {
+				return "", false
+			}
+			end, ok := parseHumanWeekday(to)
+			if !ok {
+				return "", false
+			}
+			// Ranges may wrap around the end of the week, e.g. fri-mon.
+			for d := start; ; d = (d + 1) % 7 {
+				set[d] = true
+				if d == end {
+					break
+				}
+			}
+			continue
+		}
+
+		d, ok := parseHumanWeekday(item)
+		if !ok {
+			return "", false
+		}
+		set[d] = true
+	}
+	if len(set) == 7 {
+		return "*", true
+	}
+
+	weekdays := make([]int, 0, len(set))
+	for d := range set {
+		weekdays = append(weekdays, int(d))
+	}
+	sort.Ints(weekdays)
+
+	// Collapse runs of three or more days into ranges to keep the schedule
+	// readable, e.g. 1,2,3,4,5 becomes 1-5.
+	var parts []string
+	for i := 0; i < len(weekdays); {
+		j := i
+		for j+1 < len(weekdays) && weekdays[j+1] == weekdays[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			parts = append(parts, fmt.Sprintf("%d-%d", weekdays[i], weekdays[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				parts = append(parts, strconv.Itoa(weekdays[k]))
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ","), true
+}
+
+func parseHumanWeekday(s string) (time.Weekday, bool) {
+	if d, ok := humanWeekdays[s]; ok {
+		return d, true
+	}
+	// Accept plurals such as "mondays".
+	if d, ok := humanWeekdays[strings.TrimSuffix(s, "s")]; ok && strings.HasSuffix(s, "s") {
+		return d, true
+	}
+	return 0, false
+}
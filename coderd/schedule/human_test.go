@@ -0,0 +1,145 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/schedule"
+)
+
+func Test_Human(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name               string
+		spec               string
+		loc                *time.Location
+		expectedString     string
+		expectedDaysOfWeek string
+		expectedError      string
+	}{
+		{
+			name:               "weekdays with timezone",
+			spec:               "weekdays 9am Europe/Berlin",
+			expectedString:     "CRON_TZ=Europe/Berlin 0 9 * * 1-5",
+			expectedDaysOfWeek: "Mon-Fri",
+		},
+		{
+			name:               "time only",
+			spec:               "17:30",
+			expectedString:     "CRON_TZ=UTC 30 17 * * *",
+			expectedDaysOfWeek: "daily",
+		},
+		{
+			name:               "default location",
+			spec:               "daily 8:30am",
+			loc:                mustLocation(t, "America/Chicago"),
+			expectedString:     "CRON_TZ=America/Chicago 30 8 * * *",
+			expectedDaysOfWeek: "daily",
+		},
+		{
+			name:               "any order with filler words",
+			spec:               "at 9:15PM every Monday US/Central",
+			expectedString:     "CRON_TZ=US/Central 15 21 * * 1",
+			expectedDaysOfWeek: "Mon",
+		},
+		{
+			name:               "list of days",
+			spec:               "mon,wed,fri noon",
+			expectedString:     "CRON_TZ=UTC 0 12 * * 1,3,5",
+			expectedDaysOfWeek: "Mon,Wed,Fri",
+		},
+		{
+			name:               "weekends",
+			spec:               "weekends midnight",
+			expectedString:     "CRON_TZ=UTC 0 0 * * 0,6",
+			expectedDaysOfWeek: "Sun,Sat",
+		},
+		{
+			name:               "wrapping range",
+			spec:               "fri-mon 7am",
+			expectedString:     "CRON_TZ=UTC 0 7 * * 0,1,5,6",
+			expectedDaysOfWeek: "Sun,Mon,Fri,Sat",
+		},
+		{
+			name:               "plural day names",
+			spec:               "tuesdays,thursdays 10am",
+			expectedString:     "CRON_TZ=UTC 0 10 * * 2,4",
+			expectedDaysOfWeek: "Tue,Thu",
+		},
+		{
+			name:               "all days",
+			spec:               "weekdays,weekends 6am",
+			expectedString:     "CRON_TZ=UTC 0 6 * * *",
+			expectedDaysOfWeek: "daily",
+		},
+		{
+			name:          "missing time",
+			spec:          "weekdays Europe/Berlin",
+			expectedError: "missing time of day",
+		},
+		{
+			name:          "unknown day",
+			spec:          "someday 9am",
+			expectedError: `unrecognized "someday"`,
+		},
+		{
+			name:          "invalid timezone",
+			spec:          "9am Mars/Olympus_Mons",
+			expectedError: `unrecognized "Mars/Olympus_Mons"`,
+		},
+		{
+			name:          "two times",
+			spec:          "9am 5pm",
+			expectedError: "more than one time of day specified",
+		},
+	}
+
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			actual, err := schedule.Human(testCase.spec, testCase.loc)
+			if testCase.expectedError != "" {
+				require.ErrorContains(t, err, testCase.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, testCase.expectedString, actual.String())
+			require.Equal(t, testCase.expectedDaysOfWeek, actual.DaysOfWeek())
+		})
+	}
+}
+
+func Test_Autostart(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Cron", func(t *testing.T) {
+		t.Parallel()
+		sched, err := schedule.Autostart("CRON_TZ=US/Central 30 9 * * 1-5")
+		require.NoError(t, err)
+		require.Equal(t, "CRON_TZ=US/Central 30 9 * * 1-5", sched.String())
+	})
+
+	t.Run("Human", func(t *testing.T) {
+		t.Parallel()
+		sched, err := schedule.Autostart("weekdays 9am Europe/Berlin")
+		require.NoError(t, err)
+		require.Equal(t, "CRON_TZ=Europe/Berlin 0 9 * * 1-5", sched.String())
+	})
+
+	t.Run("InvalidCron", func(t *testing.T) {
+		t.Parallel()
+		_, err := schedule.Autostart("CRON_TZ=US/Central 30 9 1 * 1-5")
+		require.ErrorContains(t, err, "expected day-of-month and month to be *")
+		require.ErrorContains(t, err, schedule.AutostartFormats)
+	})
+
+	t.Run("InvalidHuman", func(t *testing.T) {
+		t.Parallel()
+		_, err := schedule.Autostart("weekdays nine")
+		require.ErrorContains(t, err, `unrecognized "nine"`)
+		require.ErrorContains(t, err, schedule.AutostartFormats)
+	})
+}
@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -9,8 +10,10 @@ import (
 )
 
 type MockTemplateScheduleStore struct {
-	GetFn func(ctx context.Context, db database.Store, templateID uuid.UUID) (TemplateScheduleOptions, error)
-	SetFn func(ctx context.Context, db database.Store, template database.Template, options TemplateScheduleOptions) (database.Template, error)
+	GetFn             func(ctx context.Context, db database.Store, templateID uuid.UUID) (TemplateScheduleOptions, error)
+	GetForWorkspaceFn func(ctx context.Context, db database.Store, workspace database.Workspace) (TemplateScheduleOptions, error)
+	SetFn             func(ctx context.Context, db database.Store, template database.Template, options TemplateScheduleOptions) (database.Template, TemplateScheduleChanges, error)
+	DryRunFn          func(ctx context.Context, db database.Store, template database.Template, options TemplateScheduleOptions) ([]WorkspaceBuildDeadlineChange, error)
 }
 
 var _ TemplateScheduleStore = MockTemplateScheduleStore{}
@@ -23,7 +26,15 @@ func (m MockTemplateScheduleStore) Get(ctx context.Context, db database.Store, t
 	return NewAGPLTemplateScheduleStore().Get(ctx, db, templateID)
 }
 
-func (m MockTemplateScheduleStore) Set(ctx context.Context, db database.Store, template database.Template, options TemplateScheduleOptions) (database.Template, error) {
+func (m MockTemplateScheduleStore) GetForWorkspace(ctx context.Context, db database.Store, workspace database.Workspace) (TemplateScheduleOptions, error) {
+	if m.GetForWorkspaceFn != nil {
+		return m.GetForWorkspaceFn(ctx, db, workspace)
+	}
+
+	return m.Get(ctx, db, workspace.TemplateID)
+}
+
+func (m MockTemplateScheduleStore) Set(ctx context.Context, db database.Store, template database.Template, options TemplateScheduleOptions) (database.Template, TemplateScheduleChanges, error) {
 	if m.SetFn != nil {
 		return m.SetFn(ctx, db, template, options)
 	}
@@ -31,9 +42,19 @@ func (m MockTemplateScheduleStore) Set(ctx context.Context, db database.Store, t
 	return NewAGPLTemplateScheduleStore().Set(ctx, db, template, options)
 }
 
+func (m MockTemplateScheduleStore) DryRun(ctx context.Context, db database.Store, template database.Template, options TemplateScheduleOptions) ([]WorkspaceBuildDeadlineChange, error) {
+	if m.DryRunFn != nil {
+		return m.DryRunFn(ctx, db, template, options)
+	}
+
+	return NewAGPLTemplateScheduleStore().DryRun(ctx, db, template, options)
+}
+
 type MockUserQuietHoursScheduleStore struct {
-	GetFn func(ctx context.Context, db database.Store, userID uuid.UUID) (UserQuietHoursScheduleOptions, error)
-	SetFn func(ctx context.Context, db database.Store, userID uuid.UUID, schedule string) (UserQuietHoursScheduleOptions, error)
+	GetFn             func(ctx context.Context, db database.Store, userID uuid.UUID) (UserQuietHoursScheduleOptions, error)
+	SetFn             func(ctx context.Context, db database.Store, userID uuid.UUID, schedule string) (UserQuietHoursScheduleOptions, error)
+	AddExceptionFn    func(ctx context.Context, db database.Store, userID uuid.UUID, date time.Time) (UserQuietHoursScheduleOptions, error)
+	DeleteExceptionFn func(ctx context.Context, db database.Store, userID uuid.UUID, date time.Time) (UserQuietHoursScheduleOptions, error)
 }
 
 var _ UserQuietHoursScheduleStore = MockUserQuietHoursScheduleStore{}
@@ -53,3 +74,19 @@ func (m MockUserQuietHoursScheduleStore) Set(ctx context.Context, db database.St
 
 	return NewAGPLUserQuietHoursScheduleStore().Set(ctx, db, userID, schedule)
 }
+
+func (m MockUserQuietHoursScheduleStore) AddException(ctx context.Context, db database.Store, userID uuid.UUID, date time.Time) (UserQuietHoursScheduleOptions, error) {
+	if m.AddExceptionFn != nil {
+		return m.AddExceptionFn(ctx, db, userID, date)
+	}
+
+	return NewAGPLUserQuietHoursScheduleStore().AddException(ctx, db, userID, date)
+}
+
+func (m MockUserQuietHoursScheduleStore) DeleteException(ctx context.Context, db database.Store, userID uuid.UUID, date time.Time) (UserQuietHoursScheduleOptions, error) {
+	if m.DeleteExceptionFn != nil {
+		return m.DeleteExceptionFn(ctx, db, userID, date)
+	}
+
+	return NewAGPLUserQuietHoursScheduleStore().DeleteException(ctx, db, userID, date)
+}
@@ -2,17 +2,29 @@ package schedule
 
 import (
 	"context"
+	"math"
 	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/tracing"
 )
 
 const MaxTemplateRestartRequirementWeeks = 16
 
+// MaxTemplateRestartRequirementSpread is the maximum duration that max
+// deadlines can be spread across. Larger spreads would push restarts well past
+// the end of the user's quiet hours.
+const MaxTemplateRestartRequirementSpread = 12 * time.Hour
+
+// MaxTemplateFailureRetries is the maximum number of times a failed workspace
+// start can be retried. With the backoff doubling after every retry, more
+// retries would keep failed workspaces around for too long.
+const MaxTemplateFailureRetries = 10
+
 func TemplateRestartRequirementEpoch(loc *time.Location) time.Time {
 	// The "first week" starts on January 2nd, 2023, which is the first Monday
 	// of 2023. All other weeks are counted using modulo arithmetic from that
@@ -51,6 +63,11 @@ type TemplateRestartRequirement struct {
 	// of 2023. All other weeks are counted using modulo arithmetic from that
 	// date.
 	Weeks int64
+	// Timezone is an optional IANA timezone name. If set, the user's quiet
+	// hours schedule is evaluated in this timezone instead of the timezone of
+	// the user's schedule, so restarts are anchored to the same timezone for
+	// all users of the template.
+	Timezone string
 }
 
 // DaysMap returns a map of the days of the week that the workspace must be
@@ -63,6 +80,20 @@ func (r TemplateRestartRequirement) DaysMap() map[time.Weekday]bool {
 	return days
 }
 
+// Location returns the location to evaluate the restart requirement in. If the
+// timezone is unset, nil is returned and the user's quiet hours timezone should
+// be used instead.
+func (r TemplateRestartRequirement) Location() (*time.Location, error) {
+	if r.Timezone == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid restart requirement timezone %q: %w", r.Timezone, err)
+	}
+	return loc, nil
+}
+
 // VerifyTemplateRestartRequirement returns an error if the restart requirement
 // is invalid.
 func VerifyTemplateRestartRequirement(days uint8, weeks int64) error {
@@ -81,6 +112,45 @@ func VerifyTemplateRestartRequirement(days uint8, weeks int64) error {
 	return nil
 }
 
+// VerifyTemplateRestartRequirementSpread returns an error if the restart
+// requirement spread is invalid. The template handlers validate the spread
+// with it before it reaches the template schedule store.
+func VerifyTemplateRestartRequirementSpread(spread time.Duration) error {
+	if spread < 0 {
+		return xerrors.New("restart requirement spread must not be negative")
+	}
+	if spread > MaxTemplateRestartRequirementSpread {
+		return xerrors.Errorf("restart requirement spread must be at most %s", MaxTemplateRestartRequirementSpread)
+	}
+	return nil
+}
+
+func VerifyTemplateFailureRetries(retries int32, backoff time.Duration) error {
+	if retries < 0 {
+		return xerrors.New("failure retries must not be negative")
+	}
+	if retries > MaxTemplateFailureRetries {
+		return xerrors.Errorf("failure retries must be at most %d", MaxTemplateFailureRetries)
+	}
+	if backoff < 0 {
+		return xerrors.New("failure retry backoff must not be negative")
+	}
+	return nil
+}
+
+// FailureRetryBackoff returns how long to wait before the next retry of a
+// failed workspace start, given the number of retries so far. The backoff
+// doubles after every retry, and is clamped instead of overflowing.
+func FailureRetryBackoff(backoff time.Duration, retries int32) time.Duration {
+	if backoff <= 0 || retries <= 0 {
+		return backoff
+	}
+	if retries >= 63 || backoff > time.Duration(math.MaxInt64)>>retries {
+		return time.Duration(math.MaxInt64)
+	}
+	return backoff << retries
+}
+
 type TemplateScheduleOptions struct {
 	UserAutostartEnabled bool          `json:"user_autostart_enabled"`
 	UserAutostopEnabled  bool          `json:"user_autostop_enabled"`
@@ -96,22 +166,131 @@ type TemplateScheduleOptions struct {
 	// RestartRequirement dictates when the workspace must be restarted. This
 	// used to be handled by MaxTTL.
 	RestartRequirement TemplateRestartRequirement `json:"restart_requirement"`
+	// RestartRequirementSpread dictates the window after the start of the
+	// user's quiet hours that max deadlines are distributed across. Each
+	// workspace gets a stable pseudo-random offset within the window so
+	// workspaces don't all hit their max deadline at the same instant.
+	RestartRequirementSpread time.Duration `json:"restart_requirement_spread"`
+	// UpdateOnRestart dictates whether workspaces that were stopped because
+	// they reached the max deadline of the restart requirement are started
+	// again on the active template version. If false, workspaces stay stopped
+	// until their users start them.
+	UpdateOnRestart bool `json:"update_on_restart"`
 	// FailureTTL dictates the duration after which failed workspaces will be
 	// stopped automatically.
 	FailureTTL time.Duration `json:"failure_ttl"`
+	// FailureRetries dictates how many times a failed workspace start is
+	// retried before the workspace is stopped because of the FailureTTL. The
+	// owner is notified once the retries are exhausted, and the FailureTTL is
+	// counted from the last failure. Retries are only attempted if FailureTTL
+	// is set.
+	FailureRetries int32 `json:"failure_retries"`
+	// FailureRetryBackoff dictates how long to wait after a failed workspace
+	// start before it is retried. The duration doubles after every retry.
+	FailureRetryBackoff time.Duration `json:"failure_retry_backoff"`
 	// InactivityTTL dictates the duration after which inactive workspaces will
 	// be locked.
 	InactivityTTL time.Duration `json:"inactivity_ttl"`
 	// LockedTTL dictates the duration after which locked workspaces will be
 	// permanently deleted.
 	LockedTTL time.Duration `json:"locked_ttl"`
+	// MaxLifetime dictates the duration after the creation of a workspace
+	// after which it is deleted regardless of its activity. If LockedTTL is
+	// set, the workspace is stopped and locked instead, and deleted once the
+	// LockedTTL has passed. Owners are warned before their workspace expires.
+	MaxLifetime time.Duration `json:"max_lifetime"`
+	// ArchiveRetention dictates how long workspaces that reached the
+	// LockedTTL are archived for before they are deleted. Archiving runs the
+	// archive transition of the template, so persistent resources can be
+	// snapshotted, and keeps the Terraform state so owners can restore the
+	// workspace by unlocking it. If zero, workspaces are deleted without
+	// archiving.
+	ArchiveRetention time.Duration `json:"archive_retention"`
+	// ActivityBumpTTL dictates how long a workspace stays running after
+	// activity was last detected. If set, the deadline of a running workspace
+	// is extended to this far in the future whenever activity is detected,
+	// and workspaces that don't see any activity are stopped once it has
+	// passed, even if their TTL would keep them running for longer. If zero,
+	// the deadline is bumped by the workspace's TTL instead.
+	ActivityBumpTTL time.Duration `json:"activity_bump_ttl"`
 }
 
 // TemplateScheduleStore provides an interface for retrieving template
 // scheduling options set by the template/site admin.
 type TemplateScheduleStore interface {
 	Get(ctx context.Context, db database.Store, templateID uuid.UUID) (TemplateScheduleOptions, error)
-	Set(ctx context.Context, db database.Store, template database.Template, opts TemplateScheduleOptions) (database.Template, error)
+	// GetForWorkspace returns the scheduling options that apply to the given
+	// workspace. These are the options of the workspace's template with any
+	// per-workspace overrides applied on top.
+	GetForWorkspace(ctx context.Context, db database.Store, workspace database.Workspace) (TemplateScheduleOptions, error)
+	// Set updates the scheduling options of the template and returns the
+	// changes it made to the active workspace builds of the template. Nothing
+	// is audited, so callers should record the changes in the audit log of the
+	// update once it is committed.
+	Set(ctx context.Context, db database.Store, template database.Template, opts TemplateScheduleOptions) (database.Template, TemplateScheduleChanges, error)
+	// DryRun returns the changes that Set would make to the deadlines of the
+	// active workspace builds of the template. Nothing is written to the
+	// database.
+	DryRun(ctx context.Context, db database.Store, template database.Template, opts TemplateScheduleOptions) ([]WorkspaceBuildDeadlineChange, error)
+}
+
+// WorkspaceBuildDeadlineChange is a change to the deadlines of an active
+// workspace build caused by a template schedule update.
+type WorkspaceBuildDeadlineChange struct {
+	WorkspaceID      uuid.UUID
+	WorkspaceName    string
+	OwnerID          uuid.UUID
+	WorkspaceBuildID uuid.UUID
+	Deadline         time.Time
+	MaxDeadline      time.Time
+	NewDeadline      time.Time
+	NewMaxDeadline   time.Time
+}
+
+// TemplateScheduleChanges are the changes a template schedule update made to
+// the active workspace builds. They are recorded in the additional fields of
+// the audit log of the update.
+type TemplateScheduleChanges struct {
+	// RecalculatedWorkspaces are the workspaces whose build deadlines were
+	// changed to match the new schedule.
+	RecalculatedWorkspaces []RecalculatedWorkspace `json:"recalculated_workspaces,omitempty"`
+	// DeadlineRecalculationJobIDs are the jobs recalculating the build
+	// deadlines in the background. Their audit logs share the job ID as
+	// request ID.
+	DeadlineRecalculationJobIDs []uuid.UUID `json:"deadline_recalculation_job_ids,omitempty"`
+}
+
+// Empty returns true if the update didn't change any workspace builds.
+func (c TemplateScheduleChanges) Empty() bool {
+	return len(c.RecalculatedWorkspaces) == 0 && len(c.DeadlineRecalculationJobIDs) == 0
+}
+
+type RecalculatedWorkspace struct {
+	WorkspaceID      uuid.UUID `json:"workspace_id"`
+	WorkspaceName    string    `json:"workspace_name"`
+	OwnerID          uuid.UUID `json:"owner_id"`
+	WorkspaceBuildID uuid.UUID `json:"workspace_build_id"`
+	OldDeadline      time.Time `json:"old_deadline"`
+	OldMaxDeadline   time.Time `json:"old_max_deadline"`
+	NewDeadline      time.Time `json:"new_deadline"`
+	NewMaxDeadline   time.Time `json:"new_max_deadline"`
+}
+
+func ConvertRecalculatedWorkspaces(changes []WorkspaceBuildDeadlineChange) []RecalculatedWorkspace {
+	workspaces := make([]RecalculatedWorkspace, 0, len(changes))
+	for _, change := range changes {
+		workspaces = append(workspaces, RecalculatedWorkspace{
+			WorkspaceID:      change.WorkspaceID,
+			WorkspaceName:    change.WorkspaceName,
+			OwnerID:          change.OwnerID,
+			WorkspaceBuildID: change.WorkspaceBuildID,
+			OldDeadline:      change.Deadline,
+			OldMaxDeadline:   change.MaxDeadline,
+			NewDeadline:      change.NewDeadline,
+			NewMaxDeadline:   change.NewMaxDeadline,
+		})
+	}
+	return workspaces
 }
 
 type agplTemplateScheduleStore struct{}
@@ -137,27 +316,43 @@ func (*agplTemplateScheduleStore) Get(ctx context.Context, db database.Store, te
 		UserAutostartEnabled: true,
 		UserAutostopEnabled:  true,
 		DefaultTTL:           time.Duration(tpl.DefaultTTL),
+		ActivityBumpTTL:      time.Duration(tpl.ActivityBumpTTL),
 		// Disregard the values in the database, since RestartRequirement,
-		// FailureTTL, InactivityTTL, and LockedTTL are enterprise features.
+		// FailureTTL and its retries, InactivityTTL, LockedTTL,
+		// MaxLifetime and ArchiveRetention are enterprise features.
 		UseRestartRequirement: false,
 		MaxTTL:                0,
 		RestartRequirement: TemplateRestartRequirement{
 			DaysOfWeek: 0,
 			Weeks:      0,
+			Timezone:   "",
 		},
-		FailureTTL:    0,
-		InactivityTTL: 0,
-		LockedTTL:     0,
+		RestartRequirementSpread: 0,
+		UpdateOnRestart:          false,
+		FailureTTL:               0,
+		FailureRetries:           0,
+		FailureRetryBackoff:      0,
+		InactivityTTL:            0,
+		LockedTTL:                0,
+		MaxLifetime:              0,
+		ArchiveRetention:         0,
 	}, nil
 }
 
-func (*agplTemplateScheduleStore) Set(ctx context.Context, db database.Store, tpl database.Template, opts TemplateScheduleOptions) (database.Template, error) {
+func (s *agplTemplateScheduleStore) GetForWorkspace(ctx context.Context, db database.Store, workspace database.Workspace) (TemplateScheduleOptions, error) {
+	// Per-workspace schedule overrides are an enterprise feature, so always
+	// use the template's options.
+	return s.Get(ctx, db, workspace.TemplateID)
+}
+
+func (*agplTemplateScheduleStore) Set(ctx context.Context, db database.Store, tpl database.Template, opts TemplateScheduleOptions) (database.Template, TemplateScheduleChanges, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
 
-	if int64(opts.DefaultTTL) == tpl.DefaultTTL {
+	if int64(opts.DefaultTTL) == tpl.DefaultTTL &&
+		int64(opts.ActivityBumpTTL) == tpl.ActivityBumpTTL {
 		// Avoid updating the UpdatedAt timestamp if nothing will be changed.
-		return tpl, nil
+		return tpl, TemplateScheduleChanges{}, nil
 	}
 
 	var template database.Template
@@ -171,11 +366,19 @@ func (*agplTemplateScheduleStore) Set(ctx context.Context, db database.Store, tp
 			MaxTTL:                       tpl.MaxTTL,
 			RestartRequirementDaysOfWeek: tpl.RestartRequirementDaysOfWeek,
 			RestartRequirementWeeks:      tpl.RestartRequirementWeeks,
+			RestartRequirementTimezone:   tpl.RestartRequirementTimezone,
+			RestartRequirementSpread:     tpl.RestartRequirementSpread,
+			UpdateOnRestart:              tpl.UpdateOnRestart,
 			AllowUserAutostart:           tpl.AllowUserAutostart,
 			AllowUserAutostop:            tpl.AllowUserAutostop,
 			FailureTTL:                   tpl.FailureTTL,
+			FailureRetries:               tpl.FailureRetries,
+			FailureRetryBackoff:          tpl.FailureRetryBackoff,
 			InactivityTTL:                tpl.InactivityTTL,
 			LockedTTL:                    tpl.LockedTTL,
+			MaxLifetime:                  tpl.MaxLifetime,
+			ArchiveRetention:             tpl.ArchiveRetention,
+			ActivityBumpTTL:              int64(opts.ActivityBumpTTL),
 		})
 		if err != nil {
 			return xerrors.Errorf("update template schedule: %w", err)
@@ -188,9 +391,84 @@ func (*agplTemplateScheduleStore) Set(ctx context.Context, db database.Store, tp
 
 		return nil
 	}, nil)
+	if err != nil {
+		return database.Template{}, TemplateScheduleChanges{}, err
+	}
+
+	// Updating the template schedule never touches existing workspace builds.
+	return template, TemplateScheduleChanges{}, nil
+}
+
+func (*agplTemplateScheduleStore) DryRun(ctx context.Context, _ database.Store, _ database.Template, _ TemplateScheduleOptions) ([]WorkspaceBuildDeadlineChange, error) {
+	_, span := tracing.StartSpan(ctx)
+	defer span.End()
+
+	// Updating the template schedule never touches existing workspace builds.
+	return []WorkspaceBuildDeadlineChange{}, nil
+}
+
+// ErrSchedulePolicyCycle is returned when a template would inherit its
+// schedule settings from itself.
+var ErrSchedulePolicyCycle = xerrors.New("schedule policy templates must not form a cycle")
+
+// ResolveSchedulePolicy returns the template whose schedule settings apply to
+// tpl by following the schedule policy templates. Deleted policy templates are
+// ignored.
+func ResolveSchedulePolicy(ctx context.Context, db database.Store, tpl database.Template) (database.Template, error) {
+	seen := map[uuid.UUID]struct{}{tpl.ID: {}}
+	for tpl.SchedulePolicyTemplateID.Valid {
+		policyID := tpl.SchedulePolicyTemplateID.UUID
+		if _, ok := seen[policyID]; ok {
+			return database.Template{}, ErrSchedulePolicyCycle
+		}
+		seen[policyID] = struct{}{}
+
+		//nolint:gocritic // The schedule policy applies to everyone that can
+		// read the template, even if they can't read the policy template.
+		policy, err := db.GetTemplateByID(dbauthz.AsSystemRestricted(ctx), policyID)
+		if err != nil {
+			return database.Template{}, xerrors.Errorf("get schedule policy template %q: %w", policyID, err)
+		}
+		if policy.Deleted {
+			break
+		}
+		tpl = policy
+	}
+	return tpl, nil
+}
+
+// WithSchedulePolicy returns tpl with the schedule settings it inherits from
+// its schedule policy template, if it has one.
+func WithSchedulePolicy(ctx context.Context, db database.Store, tpl database.Template) (database.Template, error) {
+	if !tpl.SchedulePolicyTemplateID.Valid {
+		return tpl, nil
+	}
+	policy, err := ResolveSchedulePolicy(ctx, db, tpl)
 	if err != nil {
 		return database.Template{}, err
 	}
+	return InheritSchedule(tpl, policy), nil
+}
 
-	return template, err
+// InheritSchedule returns tpl with its schedule settings replaced by the ones
+// of policy. All other fields are kept.
+func InheritSchedule(tpl, policy database.Template) database.Template {
+	tpl.AllowUserAutostart = policy.AllowUserAutostart
+	tpl.AllowUserAutostop = policy.AllowUserAutostop
+	tpl.DefaultTTL = policy.DefaultTTL
+	tpl.ActivityBumpTTL = policy.ActivityBumpTTL
+	tpl.MaxTTL = policy.MaxTTL
+	tpl.RestartRequirementDaysOfWeek = policy.RestartRequirementDaysOfWeek
+	tpl.RestartRequirementWeeks = policy.RestartRequirementWeeks
+	tpl.RestartRequirementTimezone = policy.RestartRequirementTimezone
+	tpl.RestartRequirementSpread = policy.RestartRequirementSpread
+	tpl.UpdateOnRestart = policy.UpdateOnRestart
+	tpl.FailureTTL = policy.FailureTTL
+	tpl.FailureRetries = policy.FailureRetries
+	tpl.FailureRetryBackoff = policy.FailureRetryBackoff
+	tpl.InactivityTTL = policy.InactivityTTL
+	tpl.LockedTTL = policy.LockedTTL
+	tpl.MaxLifetime = policy.MaxLifetime
+	tpl.ArchiveRetention = policy.ArchiveRetention
+	return tpl
 }
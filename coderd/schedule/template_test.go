@@ -0,0 +1,36 @@
+package schedule_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/schedule"
+)
+
+func TestFailureRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		backoff  time.Duration
+		retries  int32
+		expected time.Duration
+	}{
+		{name: "NoBackoff", backoff: 0, retries: 3, expected: 0},
+		{name: "FirstRetry", backoff: time.Minute, retries: 0, expected: time.Minute},
+		{name: "Doubles", backoff: time.Minute, retries: 3, expected: 8 * time.Minute},
+		{name: "MaxRetries", backoff: time.Hour, retries: schedule.MaxTemplateFailureRetries, expected: 1024 * time.Hour},
+		{name: "Overflow", backoff: time.Duration(math.MaxInt64) / 2, retries: 2, expected: time.Duration(math.MaxInt64)},
+		{name: "LargeShift", backoff: time.Nanosecond, retries: 70, expected: time.Duration(math.MaxInt64)},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.expected, schedule.FailureRetryBackoff(tc.backoff, tc.retries))
+		})
+	}
+}
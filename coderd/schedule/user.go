@@ -2,6 +2,7 @@ package schedule
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
@@ -19,6 +20,11 @@ type UserQuietHoursScheduleOptions struct {
 	// quiet hours windows should not be used.
 	Schedule *Schedule
 	UserSet  bool
+	// Exceptions are the dates on which the user's quiet hours window is
+	// skipped, e.g. because the user is working late that day. The dates are
+	// evaluated in the timezone of the schedule and only the year, month and
+	// day are meaningful.
+	Exceptions []time.Time
 }
 
 type UserQuietHoursScheduleStore interface {
@@ -33,6 +39,16 @@ type UserQuietHoursScheduleStore interface {
 	// schedules are not entitled or disabled instance-wide, this will do
 	// nothing and return a nil schedule.
 	Set(ctx context.Context, db database.Store, userID uuid.UUID, rawSchedule string) (UserQuietHoursScheduleOptions, error)
+	// AddException skips the user's quiet hours window on the given date and
+	// returns the updated schedule. If quiet hours schedules are not entitled
+	// or disabled instance-wide, this will do nothing and return a nil
+	// schedule.
+	AddException(ctx context.Context, db database.Store, userID uuid.UUID, date time.Time) (UserQuietHoursScheduleOptions, error)
+	// DeleteException removes a previously added exception for the given date
+	// and returns the updated schedule. If quiet hours schedules are not
+	// entitled or disabled instance-wide, this will do nothing and return a
+	// nil schedule.
+	DeleteException(ctx context.Context, db database.Store, userID uuid.UUID, date time.Time) (UserQuietHoursScheduleOptions, error)
 }
 
 type agplUserQuietHoursScheduleStore struct{}
@@ -58,3 +74,19 @@ func (*agplUserQuietHoursScheduleStore) Set(_ context.Context, _ database.Store,
 		UserSet:  false,
 	}, nil
 }
+
+func (*agplUserQuietHoursScheduleStore) AddException(_ context.Context, _ database.Store, _ uuid.UUID, _ time.Time) (UserQuietHoursScheduleOptions, error) {
+	// User quiet hours windows are not supported in AGPL.
+	return UserQuietHoursScheduleOptions{
+		Schedule: nil,
+		UserSet:  false,
+	}, nil
+}
+
+func (*agplUserQuietHoursScheduleStore) DeleteException(_ context.Context, _ database.Store, _ uuid.UUID, _ time.Time) (UserQuietHoursScheduleOptions, error) {
+	// User quiet hours windows are not supported in AGPL.
+	return UserQuietHoursScheduleOptions{
+		Schedule: nil,
+		UserSet:  false,
+	}, nil
+}
@@ -3,6 +3,7 @@ package searchquery
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -115,6 +116,21 @@ func Workspaces(query string, page codersdk.Pagination, agentInactiveDisconnectT
 	filter.Status = string(httpapi.ParseCustom(parser, values, "", "status", httpapi.ParseEnum[database.WorkspaceStatus]))
 	filter.HasAgent = parser.String(values, "", "has-agent")
 	filter.LockedAt = parser.Time(values, time.Time{}, "locked_at", "2006-01-02")
+	filter.LastUsedBefore = parser.Time(values, time.Time{}, "last_used_before", "2006-01-02")
+	filter.LastUsedAfter = parser.Time(values, time.Time{}, "last_used_after", "2006-01-02")
+	filter.DeadlineBefore = parser.Time3339Nano(values, time.Time{}, "deadline_before")
+	filter.DeadlineAfter = parser.Time3339Nano(values, time.Time{}, "deadline_after")
+	filter.MaxDeadlineBefore = parser.Time3339Nano(values, time.Time{}, "max_deadline_before")
+	filter.MaxDeadlineAfter = parser.Time3339Nano(values, time.Time{}, "max_deadline_after")
+	filter.LockReason = string(httpapi.ParseCustom(parser, values, "", "lock_reason", httpapi.ParseEnum[database.WorkspaceLockReason]))
+	filter.Outdated = httpapi.ParseCustom(parser, values, "", "outdated", parseBool)
+
+	// Locked workspaces are omitted by default, so they have to be included
+	// explicitly to filter by the reason they were locked.
+	locked := httpapi.ParseCustom(parser, values, "", "locked", parseBool)
+	if (locked == "true" || filter.LockReason != "") && filter.LockedAt.IsZero() {
+		filter.LockedAt = time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
 
 	if _, ok := values["deleting_by"]; ok {
 		postFilter.DeletingBy = ptr.Ref(parser.Time(values, time.Time{}, "deleting_by", "2006-01-02"))
@@ -181,6 +197,16 @@ func searchTerms(query string, defaultKey func(term string, values url.Values) e
 	return searchValues, nil
 }
 
+// parseBool parses a boolean term into "true" or "false", so the term can be
+// passed to queries as text.
+func parseBool(term string) (string, error) {
+	v, err := strconv.ParseBool(term)
+	if err != nil {
+		return "", xerrors.Errorf("%q is not a valid boolean", term)
+	}
+	return strconv.FormatBool(v), nil
+}
+
 // splitQueryParameterByDelimiter takes a query string and splits it into the individual elements
 // of the query. Each element is separated by a delimiter. All quoted strings are
 // kept as a single element.
@@ -117,6 +117,44 @@ func TestSearchWorkspace(t *testing.T) {
 				OwnerUsername: "foo",
 			},
 		},
+		{
+			Name:  "LastUsed",
+			Query: `last_used_after:2023-01-02 last_used_before:2023-03-04`,
+			Expected: database.GetWorkspacesParams{
+				LastUsedAfter:  time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC),
+				LastUsedBefore: time.Date(2023, 3, 4, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			Name:  "Deadline",
+			Query: `deadline_after:"2023-01-02T15:04:05Z" max_deadline_before:"2023-03-04T05:06:07Z"`,
+			Expected: database.GetWorkspacesParams{
+				DeadlineAfter:     time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+				MaxDeadlineBefore: time.Date(2023, 3, 4, 5, 6, 7, 0, time.UTC),
+			},
+		},
+		{
+			Name:  "Locked",
+			Query: `locked:true`,
+			Expected: database.GetWorkspacesParams{
+				LockedAt: time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			Name:  "LockReason",
+			Query: `lock_reason:inactivity`,
+			Expected: database.GetWorkspacesParams{
+				LockedAt:   time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC),
+				LockReason: string(database.WorkspaceLockReasonInactivity),
+			},
+		},
+		{
+			Name:  "Outdated",
+			Query: `outdated:false`,
+			Expected: database.GetWorkspacesParams{
+				Outdated: "false",
+			},
+		},
 
 		// Failures
 		{
@@ -144,6 +182,16 @@ func TestSearchWorkspace(t *testing.T) {
 			Query:                 `foo:bar`,
 			ExpectedErrorContains: `"foo" is not a valid query param`,
 		},
+		{
+			Name:                  "InvalidLockReason",
+			Query:                 `lock_reason:bored`,
+			ExpectedErrorContains: `"bored" is not a valid value`,
+		},
+		{
+			Name:                  "InvalidOutdated",
+			Query:                 `outdated:maybe`,
+			ExpectedErrorContains: `"maybe" is not a valid boolean`,
+		},
 	}
 
 	for _, c := range testCases {
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/xerrors"
 	"tailscale.com/derp"
@@ -21,6 +22,7 @@ import (
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/tracing"
+	"github.com/coder/coder/coderd/workspaceapps"
 	"github.com/coder/coder/coderd/wsconncache"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/site"
@@ -38,8 +40,54 @@ func init() {
 	}
 }
 
+const (
+	// serverTailnetTransportTailnet is the transport label for connections
+	// made directly over the server tailnet.
+	serverTailnetTransportTailnet = "tailnet"
+	// serverTailnetTransportWSConnCache is the transport label for connections
+	// to legacy agents made through wsconncache.
+	serverTailnetTransportWSConnCache = "wsconncache"
+
+	defaultServerTailnetMaxIdlePerAgent     = 10
+	defaultServerTailnetIdleTTL             = 5 * time.Minute
+	defaultServerTailnetHealthCheckInterval = 30 * time.Second
+	// serverTailnetHealthCheckTimeout is how long a pooled agent connection
+	// has to respond to a health check ping.
+	serverTailnetHealthCheckTimeout = 10 * time.Second
+)
+
+// ServerTailnetPoolOptions configures how the ServerTailnet pools connections
+// to workspace agents. Zero values are replaced with defaults.
+type ServerTailnetPoolOptions struct {
+	// MaxIdlePerAgent is the maximum number of idle proxied connections kept
+	// open to each agent.
+	MaxIdlePerAgent int
+	// IdleTTL is how long a pooled agent connection may go unused before it
+	// is evicted.
+	IdleTTL time.Duration
+	// HealthCheckInterval is how often pooled agent connections are pinged.
+	// Connections that fail the health check are evicted from the pool.
+	HealthCheckInterval time.Duration
+}
+
+func (o ServerTailnetPoolOptions) withDefaults() ServerTailnetPoolOptions {
+	if o.MaxIdlePerAgent <= 0 {
+		o.MaxIdlePerAgent = defaultServerTailnetMaxIdlePerAgent
+	}
+	if o.IdleTTL <= 0 {
+		o.IdleTTL = defaultServerTailnetIdleTTL
+	}
+	if o.HealthCheckInterval <= 0 {
+		o.HealthCheckInterval = defaultServerTailnetHealthCheckInterval
+	}
+	return o
+}
+
 // NewServerTailnet creates a new tailnet intended for use by coderd. It
 // automatically falls back to wsconncache if a legacy agent is encountered.
+//
+// The returned ServerTailnet is a prometheus.Collector, so callers should
+// register it with their registry to expose its metrics.
 func NewServerTailnet(
 	ctx context.Context,
 	logger slog.Logger,
@@ -48,6 +96,7 @@ func NewServerTailnet(
 	getMultiAgent func(context.Context) (tailnet.MultiAgentConn, error),
 	cache *wsconncache.Cache,
 	traceProvider trace.TracerProvider,
+	poolOptions ServerTailnetPoolOptions,
 ) (*ServerTailnet, error) {
 	logger = logger.Named("servertailnet")
 	conn, err := tailnet.NewConn(&tailnet.Options{
@@ -70,11 +119,52 @@ func NewServerTailnet(
 		cache:                cache,
 		agentConnectionTimes: map[uuid.UUID]time.Time{},
 		agentTickets:         map[uuid.UUID]map[uuid.UUID]struct{}{},
+		poolOptions:          poolOptions.withDefaults(),
+		pool:                 map[uuid.UUID]*pooledAgentConn{},
+		transports:           map[uuid.UUID]*workspaceapps.GRPCPassthroughTransport{},
 		transport:            tailnetTransport.Clone(),
+		trackedAgents: prometheus.NewDesc(
+			prometheus.BuildFQName("coderd", "servertailnet", "tracked_agents"),
+			"The number of agents the server tailnet is subscribed to.",
+			nil, nil,
+		),
+		activeConns: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "coderd",
+			Subsystem: "servertailnet",
+			Name:      "active_connections",
+			Help:      "The number of open connections to workspace agents, by transport.",
+		}, []string{"transport"}),
+		totalConns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "servertailnet",
+			Name:      "connections_total",
+			Help:      "The total number of connections made to workspace agents, by transport.",
+		}, []string{"transport"}),
+		proxyRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "servertailnet",
+			Name:      "proxy_requests_total",
+			Help:      "The total number of requests reverse proxied to workspace agents.",
+		}, []string{"agent_id"}),
+		proxyLatencies: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "coderd",
+			Subsystem: "servertailnet",
+			Name:      "proxy_request_latencies_seconds",
+			Help:      "Latency distribution of requests reverse proxied to workspace agents, until the response headers are received.",
+			Buckets:   []float64{0.001, 0.005, 0.010, 0.025, 0.050, 0.100, 0.500, 1, 5, 10, 30},
+		}, []string{"agent_id"}),
+		poolEvictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "servertailnet",
+			Name:      "pool_evictions_total",
+			Help:      "The total number of pooled agent connections evicted, by reason.",
+		}, []string{"reason"}),
 	}
-	tn.transport.DialContext = tn.dialContext
-	tn.transport.MaxIdleConnsPerHost = 10
+	// The transport is only used as a template for the per-agent transports
+	// handed out by agentTransport.
+	tn.transport.MaxIdleConnsPerHost = tn.poolOptions.MaxIdlePerAgent
 	tn.transport.MaxIdleConns = 0
+	tn.transport.IdleConnTimeout = tn.poolOptions.IdleTTL
 	// We intentionally don't verify the certificate chain here.
 	// The connection to the workspace is already established and most
 	// apps are already going to be accessed over plain HTTP, this config
@@ -124,9 +214,15 @@ func NewServerTailnet(
 
 	go tn.watchAgentUpdates()
 	go tn.expireOldAgents()
+	go tn.checkPooledConns()
 	return tn, nil
 }
 
+// SetDERPMap updates the DERP map of the server tailnet.
+func (s *ServerTailnet) SetDERPMap(derpMap *tailcfg.DERPMap) {
+	s.conn.SetDERPMap(derpMap)
+}
+
 func (s *ServerTailnet) expireOldAgents() {
 	const (
 		tick   = 5 * time.Minute
@@ -176,6 +272,9 @@ func (s *ServerTailnet) doExpireOldAgents(cutoff time.Duration) {
 
 			deletedCount++
 			delete(s.agentConnectionTimes, agentID)
+			// Drop the per-agent series so expired agents don't accumulate.
+			s.proxyRequests.DeleteLabelValues(agentID.String())
+			s.proxyLatencies.DeleteLabelValues(agentID.String())
 			err = agentConn.UnsubscribeAgent(agentID)
 			if err != nil {
 				s.logger.Error(ctx, "unsubscribe expired agent", slog.Error(err), slog.F("agent_id", agentID))
@@ -254,12 +353,56 @@ type ServerTailnet struct {
 	// agentTockets holds a map of all open connections to an agent.
 	agentTickets map[uuid.UUID]map[uuid.UUID]struct{}
 
-	transport *http.Transport
+	poolOptions ServerTailnetPoolOptions
+	poolMu      sync.Mutex
+	// pool holds a reachable connection for each agent that is shared by all
+	// callers of AgentConn, so each call doesn't have to wait for the agent to
+	// become reachable again.
+	pool map[uuid.UUID]*pooledAgentConn
+	// transports holds an HTTP transport for each agent, so idle proxied
+	// connections are kept and limited per agent rather than per target host.
+	transports map[uuid.UUID]*workspaceapps.GRPCPassthroughTransport
+	transport  *http.Transport
+
+	trackedAgents  *prometheus.Desc
+	activeConns    *prometheus.GaugeVec
+	totalConns     *prometheus.CounterVec
+	proxyRequests  *prometheus.CounterVec
+	proxyLatencies *prometheus.HistogramVec
+	poolEvictions  *prometheus.CounterVec
+}
+
+var _ prometheus.Collector = (*ServerTailnet)(nil)
+
+func (s *ServerTailnet) Describe(descs chan<- *prometheus.Desc) {
+	descs <- s.trackedAgents
+	s.activeConns.Describe(descs)
+	s.totalConns.Describe(descs)
+	s.proxyRequests.Describe(descs)
+	s.proxyLatencies.Describe(descs)
+	s.poolEvictions.Describe(descs)
+}
+
+func (s *ServerTailnet) Collect(metrics chan<- prometheus.Metric) {
+	s.nodesMu.Lock()
+	tracked := len(s.agentConnectionTimes)
+	s.nodesMu.Unlock()
+
+	metrics <- prometheus.MustNewConstMetric(s.trackedAgents, prometheus.GaugeValue, float64(tracked))
+	s.activeConns.Collect(metrics)
+	s.totalConns.Collect(metrics)
+	s.proxyRequests.Collect(metrics)
+	s.proxyLatencies.Collect(metrics)
+	s.poolEvictions.Collect(metrics)
 }
 
 func (s *ServerTailnet) ReverseProxy(targetURL, dashboardURL *url.URL, agentID uuid.UUID) (_ *httputil.ReverseProxy, release func(), _ error) {
 	proxy := httputil.NewSingleHostReverseProxy(targetURL)
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if workspaceapps.IsGRPCRequest(r) {
+			workspaceapps.WriteGRPCUnavailable(w, "Failed to proxy request to application: "+err.Error())
+			return
+		}
 		site.RenderStaticErrorPage(w, r, site.ErrorPageData{
 			Status:       http.StatusBadGateway,
 			Title:        "Bad Gateway",
@@ -268,31 +411,48 @@ func (s *ServerTailnet) ReverseProxy(targetURL, dashboardURL *url.URL, agentID u
 			DashboardURL: dashboardURL.String(),
 		})
 	}
-	proxy.Director = s.director(agentID, proxy.Director)
-	proxy.Transport = s.transport
+	proxy.Transport = s.instrumentedTransport(agentID, s.agentTransport(agentID))
 
 	return proxy, func() {}, nil
 }
 
-type agentIDKey struct{}
+// agentTransport returns the HTTP transport used to proxy requests to the
+// given agent, creating it if necessary. gRPC requests are proxied over HTTP/2.
+func (s *ServerTailnet) agentTransport(agentID uuid.UUID) *workspaceapps.GRPCPassthroughTransport {
+	s.poolMu.Lock()
+	defer s.poolMu.Unlock()
 
-// director makes sure agentIDKey is set on the context in the reverse proxy.
-// This allows the transport to correctly identify which agent to dial to.
-func (*ServerTailnet) director(agentID uuid.UUID, prev func(req *http.Request)) func(req *http.Request) {
-	return func(req *http.Request) {
-		ctx := context.WithValue(req.Context(), agentIDKey{}, agentID)
-		*req = *req.WithContext(ctx)
-		prev(req)
+	transport, ok := s.transports[agentID]
+	if !ok {
+		dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return s.DialAgentNetConn(ctx, agentID, network, addr)
+		}
+		http1 := s.transport.Clone()
+		http1.DialContext = dial
+		transport = workspaceapps.NewGRPCPassthroughTransport(http1, dial)
+		s.transports[agentID] = transport
 	}
+	return transport
 }
 
-func (s *ServerTailnet) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
-	agentID, ok := ctx.Value(agentIDKey{}).(uuid.UUID)
-	if !ok {
-		return nil, xerrors.Errorf("no agent id attached")
-	}
+// instrumentedTransport wraps the transport to record request counts and
+// latencies for the given agent.
+func (s *ServerTailnet) instrumentedTransport(agentID uuid.UUID, transport http.RoundTripper) http.RoundTripper {
+	requests := s.proxyRequests.WithLabelValues(agentID.String())
+	latencies := s.proxyLatencies.WithLabelValues(agentID.String())
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		res, err := transport.RoundTrip(req)
+		requests.Inc()
+		latencies.Observe(time.Since(start).Seconds())
+		return res, err
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
 
-	return s.DialAgentNetConn(ctx, agentID, network, addr)
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
 }
 
 func (s *ServerTailnet) ensureAgent(agentID uuid.UUID) error {
@@ -329,8 +489,9 @@ func (s *ServerTailnet) acquireTicket(agentID uuid.UUID) (release func()) {
 
 func (s *ServerTailnet) AgentConn(ctx context.Context, agentID uuid.UUID) (*codersdk.WorkspaceAgentConn, func(), error) {
 	var (
-		conn *codersdk.WorkspaceAgentConn
-		ret  func()
+		conn      *codersdk.WorkspaceAgentConn
+		ret       func()
+		transport string
 	)
 
 	if s.getAgentConn().AgentIsLegacy(agentID) {
@@ -340,32 +501,192 @@ func (s *ServerTailnet) AgentConn(ctx context.Context, agentID uuid.UUID) (*code
 			return nil, nil, xerrors.Errorf("acquire legacy agent conn: %w", err)
 		}
 
+		// Since we now have an open conn, be careful to close it if we error
+		// without returning it to the user.
+
+		reachable := cconn.AwaitReachable(ctx)
+		if !reachable {
+			release()
+			return nil, nil, xerrors.New("agent is unreachable")
+		}
+
 		conn = cconn.WorkspaceAgentConn
 		ret = release
+		transport = serverTailnetTransportWSConnCache
 	} else {
 		s.logger.Debug(s.ctx, "acquiring agent", slog.F("agent_id", agentID))
 		err := s.ensureAgent(agentID)
 		if err != nil {
 			return nil, nil, xerrors.Errorf("ensure agent: %w", err)
 		}
-		ret = s.acquireTicket(agentID)
+		releaseTicket := s.acquireTicket(agentID)
 
-		conn = codersdk.NewWorkspaceAgentConn(s.conn, codersdk.WorkspaceAgentConnOptions{
-			AgentID:   agentID,
-			CloseFunc: func() error { return codersdk.ErrSkipClose },
-		})
+		entry, err := s.acquirePooledConn(ctx, agentID)
+		if err != nil {
+			releaseTicket()
+			return nil, nil, err
+		}
+
+		conn = entry.conn
+		ret = func() {
+			s.releasePooledConn(entry)
+			releaseTicket()
+		}
+		transport = serverTailnetTransportTailnet
 	}
 
-	// Since we now have an open conn, be careful to close it if we error
-	// without returning it to the user.
+	s.totalConns.WithLabelValues(transport).Inc()
+	active := s.activeConns.WithLabelValues(transport)
+	active.Inc()
+	var once sync.Once
+	return conn, func() {
+		once.Do(func() {
+			active.Dec()
+			ret()
+		})
+	}, nil
+}
 
+// pooledAgentConn is a reachable connection to an agent that is shared by all
+// callers of AgentConn for that agent.
+type pooledAgentConn struct {
+	conn *codersdk.WorkspaceAgentConn
+	// refs is the number of callers currently holding the connection.
+	refs     int
+	lastUsed time.Time
+}
+
+// acquirePooledConn returns the pooled connection for the agent, waiting for
+// the agent to become reachable if there isn't one yet. The returned entry must
+// be released with releasePooledConn.
+func (s *ServerTailnet) acquirePooledConn(ctx context.Context, agentID uuid.UUID) (*pooledAgentConn, error) {
+	s.poolMu.Lock()
+	entry, ok := s.pool[agentID]
+	if ok {
+		entry.refs++
+		entry.lastUsed = time.Now()
+		s.poolMu.Unlock()
+		return entry, nil
+	}
+	s.poolMu.Unlock()
+
+	conn := codersdk.NewWorkspaceAgentConn(s.conn, codersdk.WorkspaceAgentConnOptions{
+		AgentID:   agentID,
+		CloseFunc: func() error { return codersdk.ErrSkipClose },
+	})
 	reachable := conn.AwaitReachable(ctx)
 	if !reachable {
-		ret()
-		return nil, nil, xerrors.New("agent is unreachable")
+		return nil, xerrors.New("agent is unreachable")
 	}
 
-	return conn, ret, nil
+	s.poolMu.Lock()
+	defer s.poolMu.Unlock()
+	// Another caller may have pooled a connection while we were waiting for
+	// the agent to become reachable.
+	entry, ok = s.pool[agentID]
+	if !ok {
+		entry = &pooledAgentConn{conn: conn}
+		s.pool[agentID] = entry
+	}
+	entry.refs++
+	entry.lastUsed = time.Now()
+	return entry, nil
+}
+
+func (s *ServerTailnet) releasePooledConn(entry *pooledAgentConn) {
+	s.poolMu.Lock()
+	defer s.poolMu.Unlock()
+
+	entry.refs--
+	entry.lastUsed = time.Now()
+}
+
+func (s *ServerTailnet) checkPooledConns() {
+	ticker := time.NewTicker(s.poolOptions.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.doCheckPooledConns()
+	}
+}
+
+// doCheckPooledConns evicts pooled connections that have been idle for longer
+// than the idle TTL or that fail a health check. Evicted connections are not
+// closed, as callers may still hold them, but new callers will wait for the
+// agent to become reachable again.
+func (s *ServerTailnet) doCheckPooledConns() {
+	ctx, span := s.tracer.Start(s.ctx, tracing.FuncName())
+	defer span.End()
+
+	s.poolMu.Lock()
+	var (
+		idle    = map[uuid.UUID]*pooledAgentConn{}
+		healthy = map[uuid.UUID]*pooledAgentConn{}
+	)
+	for agentID, entry := range s.pool {
+		if entry.refs == 0 && time.Since(entry.lastUsed) > s.poolOptions.IdleTTL {
+			idle[agentID] = entry
+			continue
+		}
+		healthy[agentID] = entry
+	}
+	s.poolMu.Unlock()
+
+	for agentID, entry := range idle {
+		s.evictPooledConn(agentID, entry, "idle")
+	}
+
+	// Ping outside the lock so slow agents don't block AgentConn.
+	for agentID, entry := range healthy {
+		pingCtx, cancel := context.WithTimeout(ctx, serverTailnetHealthCheckTimeout)
+		_, _, _, err := entry.conn.Ping(pingCtx)
+		cancel()
+		if err != nil {
+			s.logger.Debug(ctx, "pooled agent conn failed health check", slog.F("agent_id", agentID), slog.Error(err))
+			s.evictPooledConn(agentID, entry, "unhealthy")
+		}
+	}
+
+	// Drop the transports of agents without a pooled conn. Transports still
+	// referenced by a reverse proxy keep working and are recreated on the next
+	// call to ReverseProxy.
+	s.poolMu.Lock()
+	var unused []*workspaceapps.GRPCPassthroughTransport
+	for agentID, transport := range s.transports {
+		if _, ok := s.pool[agentID]; !ok {
+			unused = append(unused, transport)
+			delete(s.transports, agentID)
+		}
+	}
+	s.poolMu.Unlock()
+	for _, transport := range unused {
+		transport.CloseIdleConnections()
+	}
+}
+
+func (s *ServerTailnet) evictPooledConn(agentID uuid.UUID, entry *pooledAgentConn, reason string) {
+	s.poolMu.Lock()
+	// The entry may have already been replaced while we weren't holding the
+	// lock.
+	if s.pool[agentID] != entry {
+		s.poolMu.Unlock()
+		return
+	}
+	delete(s.pool, agentID)
+	transport, ok := s.transports[agentID]
+	delete(s.transports, agentID)
+	s.poolMu.Unlock()
+
+	if ok {
+		transport.CloseIdleConnections()
+	}
+	s.poolEvictions.WithLabelValues(reason).Inc()
 }
 
 func (s *ServerTailnet) DialAgentNetConn(ctx context.Context, agentID uuid.UUID, network, addr string) (net.Conn, error) {
@@ -402,6 +723,10 @@ func (s *ServerTailnet) Close() error {
 	s.cancel()
 	_ = s.cache.Close()
 	_ = s.conn.Close()
-	s.transport.CloseIdleConnections()
+	s.poolMu.Lock()
+	for _, transport := range s.transports {
+		transport.CloseIdleConnections()
+	}
+	s.poolMu.Unlock()
 	return nil
 }
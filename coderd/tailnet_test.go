@@ -3,18 +3,23 @@ package coderd_test
 import (
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/netip"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
@@ -63,6 +68,41 @@ func TestServerTailnet_AgentConn_Legacy(t *testing.T) {
 	assert.True(t, conn.AwaitReachable(ctx))
 }
 
+func TestServerTailnet_AgentConn_Pooled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitMedium)
+	defer cancel()
+
+	agentID, _, serverTailnet := setupAgentWithPool(t, nil, coderd.ServerTailnetPoolOptions{
+		IdleTTL:             testutil.IntervalFast,
+		HealthCheckInterval: testutil.IntervalFast,
+	})
+
+	conn1, release1, err := serverTailnet.AgentConn(ctx, agentID)
+	require.NoError(t, err)
+	conn2, release2, err := serverTailnet.AgentConn(ctx, agentID)
+	require.NoError(t, err)
+
+	// Concurrent callers share the pooled conn.
+	require.Same(t, conn1, conn2)
+	release1()
+	release2()
+	// Releasing more than once is a no-op.
+	release2()
+
+	// Once the conn has been idle for longer than the TTL it is evicted, and
+	// the next caller gets a fresh conn.
+	require.Eventually(t, func() bool {
+		conn, release, err := serverTailnet.AgentConn(ctx, agentID)
+		if !assert.NoError(t, err) {
+			return false
+		}
+		release()
+		return conn != conn1
+	}, testutil.WaitShort, testutil.IntervalMedium)
+}
+
 func TestServerTailnet_ReverseProxy(t *testing.T) {
 	t.Parallel()
 
@@ -131,6 +171,63 @@ func TestServerTailnet_ReverseProxy(t *testing.T) {
 		assert.Equal(t, expectedResponseCode, res.StatusCode)
 	})
 
+	t.Run("GRPC", func(t *testing.T) {
+		t.Parallel()
+
+		agentID, _, serverTailnet := setupAgent(t, nil)
+
+		// gRPC apps are usually served over plain HTTP/2 without TLS.
+		s := httptest.NewServer(h2c.NewHandler(grpcEchoHandler(t), &http2.Server{}))
+		t.Cleanup(s.Close)
+
+		requireGRPCProxied(t, serverTailnet, agentID, s.URL)
+	})
+
+	t.Run("GRPCOverTLS", func(t *testing.T) {
+		t.Parallel()
+
+		agentID, _, serverTailnet := setupAgent(t, nil)
+
+		s := httptest.NewUnstartedServer(grpcEchoHandler(t))
+		s.EnableHTTP2 = true
+		s.StartTLS()
+		t.Cleanup(s.Close)
+
+		requireGRPCProxied(t, serverTailnet, agentID, s.URL)
+	})
+
+	t.Run("GRPCUnavailable", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		agentID, _, serverTailnet := setupAgent(t, nil)
+
+		// Nothing is listening on this port.
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		u, err := url.Parse("http://" + l.Addr().String())
+		require.NoError(t, err)
+		_ = l.Close()
+
+		rp, release, err := serverTailnet.ReverseProxy(u, u, agentID)
+		require.NoError(t, err)
+		defer release()
+
+		rw := httptest.NewRecorder()
+		req := newGRPCRequest(ctx, t, u.String())
+
+		rp.ServeHTTP(rw, req)
+		res := rw.Result()
+		defer res.Body.Close()
+
+		// Errors are reported as gRPC statuses instead of HTML pages.
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/grpc", res.Header.Get("Content-Type"))
+		assert.Equal(t, "14", res.Header.Get("Grpc-Status"))
+	})
+
 	t.Run("Legacy", func(t *testing.T) {
 		t.Parallel()
 
@@ -164,7 +261,128 @@ func TestServerTailnet_ReverseProxy(t *testing.T) {
 	})
 }
 
+func TestServerTailnet_Metrics(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	agentID, _, serverTailnet := setupAgent(t, nil)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(serverTailnet)
+
+	u, err := url.Parse(fmt.Sprintf("http://127.0.0.1:%d", codersdk.WorkspaceAgentHTTPAPIServerPort))
+	require.NoError(t, err)
+
+	rp, release, err := serverTailnet.ReverseProxy(u, u, agentID)
+	require.NoError(t, err)
+	defer release()
+
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(
+		http.MethodGet,
+		u.String(),
+		nil,
+	).WithContext(ctx)
+
+	rp.ServeHTTP(rw, req)
+	res := rw.Result()
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	metrics, err := registry.Gather()
+	require.NoError(t, err)
+
+	values := map[string]float64{}
+	for _, family := range metrics {
+		for _, metric := range family.GetMetric() {
+			labels := ""
+			for _, label := range metric.GetLabel() {
+				labels += label.GetName() + "=" + label.GetValue()
+			}
+			key := family.GetName() + "{" + labels + "}"
+			switch {
+			case metric.GetCounter() != nil:
+				values[key] = metric.GetCounter().GetValue()
+			case metric.GetGauge() != nil:
+				values[key] = metric.GetGauge().GetValue()
+			case metric.GetHistogram() != nil:
+				values[key] = float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+
+	agentLabel := "{agent_id=" + agentID.String() + "}"
+	assert.Equal(t, float64(1), values["coderd_servertailnet_tracked_agents{}"])
+	assert.Equal(t, float64(1), values["coderd_servertailnet_connections_total{transport=tailnet}"])
+	assert.Equal(t, float64(1), values["coderd_servertailnet_proxy_requests_total"+agentLabel])
+	assert.Equal(t, float64(1), values["coderd_servertailnet_proxy_request_latencies_seconds"+agentLabel])
+	// The idle connection is kept open by the transport for reuse.
+	assert.Equal(t, float64(1), values["coderd_servertailnet_active_connections{transport=tailnet}"])
+}
+
+// grpcEchoHandler mimics a gRPC server by echoing the request body and
+// sending the status in a trailer. It fails the test if the request wasn't
+// made over HTTP/2.
+func grpcEchoHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, 2, r.ProtoMajor, "gRPC requests must be proxied over HTTP/2")
+		assert.Equal(t, "application/grpc", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+		w.Header().Set("Grpc-Status", "0")
+	})
+}
+
+func newGRPCRequest(ctx context.Context, t *testing.T, target string) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader("hello")).WithContext(ctx)
+	req.Proto = "HTTP/2.0"
+	req.ProtoMajor = 2
+	req.ProtoMinor = 0
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("Te", "trailers")
+	return req
+}
+
+func requireGRPCProxied(t *testing.T, serverTailnet *coderd.ServerTailnet, agentID uuid.UUID, target string) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	u, err := url.Parse(target)
+	require.NoError(t, err)
+
+	rp, release, err := serverTailnet.ReverseProxy(u, u, agentID)
+	require.NoError(t, err)
+	defer release()
+
+	rw := httptest.NewRecorder()
+	req := newGRPCRequest(ctx, t, u.String())
+
+	rp.ServeHTTP(rw, req)
+	res := rw.Result()
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(body))
+	require.Equal(t, "0", res.Trailer.Get("Grpc-Status"))
+}
+
 func setupAgent(t *testing.T, agentAddresses []netip.Prefix) (uuid.UUID, agent.Agent, *coderd.ServerTailnet) {
+	return setupAgentWithPool(t, agentAddresses, coderd.ServerTailnetPoolOptions{})
+}
+
+func setupAgentWithPool(t *testing.T, agentAddresses []netip.Prefix, poolOptions coderd.ServerTailnetPoolOptions) (uuid.UUID, agent.Agent, *coderd.ServerTailnet) {
 	logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
 	derpMap, derpServer := tailnettest.RunDERPAndSTUN(t)
 	manifest := agentsdk.Manifest{
@@ -234,6 +452,7 @@ func setupAgent(t *testing.T, agentAddresses []netip.Prefix) (uuid.UUID, agent.A
 		func(context.Context) (tailnet.MultiAgentConn, error) { return coord.ServeMultiAgent(uuid.New()), nil },
 		cache,
 		trace.NewNoopTracerProvider(),
+		poolOptions,
 	)
 	require.NoError(t, err)
 
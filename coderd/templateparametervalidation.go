@@ -0,0 +1,177 @@
+package coderd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/parametervalidation"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get template parameter validation
+// @Description Returns the rules that the parameter values of workspace builds of the template are validated with before the builds are queued.
+// @ID get-template-parameter-validation
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 200 {object} codersdk.TemplateParameterValidation
+// @Router /templates/{template}/parameter-validation [get]
+func (api *API) templateParameterValidation(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	validation, err := api.Database.GetTemplateParameterValidation(ctx, template.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Templates without rules only validate each parameter.
+		validation = database.TemplateParameterValidation{
+			TemplateID: template.ID,
+		}
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template parameter validation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiValidation, err := convertTemplateParameterValidation(validation)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error converting template parameter validation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, apiValidation)
+}
+
+// @Summary Update template parameter validation
+// @Description The rules apply to builds that start workspaces and are created afterwards.
+// @ID update-template-parameter-validation
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.UpdateTemplateParameterValidationRequest true "Update template parameter validation request"
+// @Success 200 {object} codersdk.TemplateParameterValidation
+// @Router /templates/{template}/parameter-validation [put]
+func (api *API) putTemplateParameterValidation(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		template          = httpmw.TemplateParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.TemplateParameterValidation](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+
+	var req codersdk.UpdateTemplateParameterValidationRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if validations := parametervalidation.ValidateRules(req); len(validations) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid template parameter validation.",
+			Validations: validations,
+		})
+		return
+	}
+	if req.WebhookURL != "" && !api.parameterValidator.WebhookAllowed(req.WebhookURL) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid template parameter validation.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "webhook_url",
+				Detail: "The host of the webhook is not allowed by the deployment.",
+			}},
+		})
+		return
+	}
+	if req.Constraints == nil {
+		req.Constraints = []codersdk.TemplateParameterConstraint{}
+	}
+	constraints, err := json.Marshal(req.Constraints)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error marshaling constraints.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// Templates without rules are audited as if the previous rules were
+	// empty.
+	old, err := api.Database.GetTemplateParameterValidation(ctx, template.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template parameter validation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.Old = old
+
+	now := database.Now()
+	validation, err := api.Database.UpsertTemplateParameterValidation(ctx, database.UpsertTemplateParameterValidationParams{
+		TemplateID:  template.ID,
+		WebhookURL:  req.WebhookURL,
+		Constraints: constraints,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template parameter validation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = validation
+
+	apiValidation, err := convertTemplateParameterValidation(validation)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error converting template parameter validation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, apiValidation)
+}
+
+func convertTemplateParameterValidation(validation database.TemplateParameterValidation) (codersdk.TemplateParameterValidation, error) {
+	constraints := []codersdk.TemplateParameterConstraint{}
+	if len(validation.Constraints) > 0 {
+		err := json.Unmarshal(validation.Constraints, &constraints)
+		if err != nil {
+			return codersdk.TemplateParameterValidation{}, xerrors.Errorf("unmarshal constraints: %w", err)
+		}
+	}
+	return codersdk.TemplateParameterValidation{
+		TemplateID:  validation.TemplateID,
+		WebhookURL:  validation.WebhookURL,
+		Constraints: constraints,
+	}, nil
+}
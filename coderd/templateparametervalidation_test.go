@@ -0,0 +1,211 @@
+package coderd_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateParameterValidation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		dv := coderdtest.DeploymentValues(t)
+		dv.Webhooks.ParameterValidationHosts = []string{"example.com"}
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor, DeploymentValues: dv})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		validation, err := client.TemplateParameterValidation(ctx, template.ID)
+		require.NoError(t, err)
+		require.Empty(t, validation.WebhookURL)
+		require.Empty(t, validation.Constraints)
+
+		constraints := []codersdk.TemplateParameterConstraint{{
+			Parameter:     "instance_type",
+			Regex:         "^t",
+			WhenParameter: "region",
+			WhenRegex:     "^eu-",
+		}}
+		updated, err := client.UpdateTemplateParameterValidation(ctx, template.ID, codersdk.UpdateTemplateParameterValidationRequest{
+			WebhookURL:  "https://example.com/validate",
+			Constraints: constraints,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/validate", updated.WebhookURL)
+		require.Equal(t, constraints, updated.Constraints)
+
+		validation, err = client.TemplateParameterValidation(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, updated, validation)
+
+		logs := auditor.AuditLogs()
+		require.NotEmpty(t, logs)
+		last := logs[len(logs)-1]
+		require.Equal(t, database.AuditActionWrite, last.Action)
+		require.Equal(t, database.ResourceTypeTemplateParameterValidation, last.ResourceType)
+		require.Equal(t, template.ID, last.ResourceID)
+	})
+
+	t.Run("WebhookNotAllowed", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.UpdateTemplateParameterValidation(ctx, template.ID, codersdk.UpdateTemplateParameterValidationRequest{
+			WebhookURL: "https://example.com/validate",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "webhook_url", apiErr.Validations[0].Field)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.UpdateTemplateParameterValidation(ctx, template.ID, codersdk.UpdateTemplateParameterValidationRequest{
+			Constraints: []codersdk.TemplateParameterConstraint{{
+				Parameter: "region",
+				Regex:     "(",
+			}},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "constraints[0].regex", apiErr.Validations[0].Field)
+	})
+
+	t.Run("MemberCannotUpdate", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := member.TemplateParameterValidation(ctx, template.ID)
+		require.NoError(t, err)
+
+		_, err = member.UpdateTemplateParameterValidation(ctx, template.ID, codersdk.UpdateTemplateParameterValidationRequest{
+			Constraints: []codersdk.TemplateParameterConstraint{{
+				Parameter: "region",
+				Regex:     "^eu-",
+			}},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("RejectsBuilds", func(t *testing.T) {
+		t.Parallel()
+		// The webhook rejects all regions other than eu-west-1.
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			var req codersdk.ParameterValidationRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			var res codersdk.ParameterValidationResponse
+			for _, parameter := range req.Parameters {
+				if parameter.Name == "region" && parameter.Value != "eu-west-1" {
+					res.Validations = append(res.Validations, codersdk.ValidationError{
+						Field:  "region",
+						Detail: "Region is at capacity.",
+					})
+				}
+			}
+			_ = json.NewEncoder(rw).Encode(res)
+		}))
+		t.Cleanup(srv.Close)
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Webhooks.ParameterValidationHosts = []string{srv.Listener.Addr().String()}
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true, DeploymentValues: dv})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionPlan: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Parameters: []*proto.RichParameter{
+							{Name: "region", Type: "string", Mutable: true},
+							{Name: "instance_type", Type: "string", Mutable: true},
+						},
+					},
+				},
+			}},
+			ProvisionApply: echo.ProvisionComplete,
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.UpdateTemplateParameterValidation(ctx, template.ID, codersdk.UpdateTemplateParameterValidationRequest{
+			WebhookURL: srv.URL,
+			Constraints: []codersdk.TemplateParameterConstraint{{
+				Parameter:     "instance_type",
+				Regex:         "^t",
+				WhenParameter: "region",
+				WhenRegex:     "^eu-",
+				Error:         "Only T instances are available in EU regions.",
+			}},
+		})
+		require.NoError(t, err)
+
+		for _, c := range []struct {
+			Region       string
+			InstanceType string
+			Field        string
+		}{
+			{Region: "eu-west-1", InstanceType: "g5.xlarge", Field: "instance_type"},
+			{Region: "us-east-1", InstanceType: "g5.xlarge", Field: "region"},
+		} {
+			_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+				TemplateID: template.ID,
+				Name:       "invalid",
+				RichParameterValues: []codersdk.WorkspaceBuildParameter{
+					{Name: "region", Value: c.Region},
+					{Name: "instance_type", Value: c.InstanceType},
+				},
+			})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+			require.Len(t, apiErr.Validations, 1)
+			require.Equal(t, c.Field, apiErr.Validations[0].Field)
+		}
+
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.RichParameterValues = []codersdk.WorkspaceBuildParameter{
+				{Name: "region", Value: "eu-west-1"},
+				{Name: "instance_type", Value: "t3.large"},
+			}
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	})
+}
@@ -0,0 +1,130 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/prebuilds"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get template prebuilds
+// @Description Returns the pool of prebuilt workspaces of the template.
+// @ID get-template-prebuilds
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 200 {object} codersdk.TemplatePrebuilds
+// @Router /templates/{template}/prebuilds [get]
+func (api *API) templatePrebuilds(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	pool, err := api.Database.GetTemplatePrebuildPool(ctx, template.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template prebuild pool.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiPrebuilds, err := api.convertTemplatePrebuilds(ctx, template, pool.Size)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching prebuilt workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, apiPrebuilds)
+}
+
+// @Summary Update template prebuilds
+// @Description Prebuilt workspaces are created or deleted in the background.
+// @ID update-template-prebuilds
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.UpdateTemplatePrebuildsRequest true "Update template prebuilds request"
+// @Success 200 {object} codersdk.TemplatePrebuilds
+// @Router /templates/{template}/prebuilds [put]
+func (api *API) putTemplatePrebuilds(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	var req codersdk.UpdateTemplatePrebuildsRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if req.Size < 0 || req.Size > prebuilds.MaxPoolSize {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid prebuilds.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "size",
+				Detail: fmt.Sprintf("Must be between 0 and %d.", prebuilds.MaxPoolSize),
+			}},
+		})
+		return
+	}
+
+	pool, err := api.Database.UpsertTemplatePrebuildPool(ctx, database.UpsertTemplatePrebuildPoolParams{
+		TemplateID: template.ID,
+		Size:       req.Size,
+		UpdatedAt:  database.Now(),
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template prebuild pool.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiPrebuilds, err := api.convertTemplatePrebuilds(ctx, template, pool.Size)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching prebuilt workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, apiPrebuilds)
+}
+
+func (api *API) convertTemplatePrebuilds(ctx context.Context, template database.Template, size int32) (codersdk.TemplatePrebuilds, error) {
+	// Prebuilt workspaces are owned by the prebuilds user, so users that can
+	// read the template may not be able to read them.
+	// nolint:gocritic
+	workspaces, err := api.Database.GetPrebuiltWorkspaces(dbauthz.AsSystemRestricted(ctx), prebuilds.OwnerID)
+	if err != nil {
+		return codersdk.TemplatePrebuilds{}, xerrors.Errorf("get prebuilt workspaces: %w", err)
+	}
+	status := prebuilds.NewPoolStatus(template, workspaces, database.Now())
+	return codersdk.TemplatePrebuilds{
+		TemplateID: template.ID,
+		Size:       size,
+		Ready:      int32(len(status.Ready)),
+		Building:   int32(len(status.Building)),
+	}, nil
+}
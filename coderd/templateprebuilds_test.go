@@ -0,0 +1,73 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplatePrebuilds(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		prebuilds, err := client.TemplatePrebuilds(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, template.ID, prebuilds.TemplateID)
+		require.EqualValues(t, 0, prebuilds.Size)
+
+		prebuilds, err = client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Size: 3})
+		require.NoError(t, err)
+		require.EqualValues(t, 3, prebuilds.Size)
+		require.EqualValues(t, 0, prebuilds.Ready)
+
+		prebuilds, err = client.TemplatePrebuilds(ctx, template.ID)
+		require.NoError(t, err)
+		require.EqualValues(t, 3, prebuilds.Size)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		for _, size := range []int32{-1, 1000} {
+			_, err := client.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Size: size})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		}
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		// Members can see the pool, but can't change it.
+		_, err := memberClient.TemplatePrebuilds(ctx, template.ID)
+		require.NoError(t, err)
+		_, err = memberClient.UpdateTemplatePrebuilds(ctx, template.ID, codersdk.UpdateTemplatePrebuildsRequest{Size: 1})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,575 @@
+// Package templateregistry pushes template source archives to, and pulls them
+// from, registries that implement the OCI distribution specification, such as
+// GitHub Container Registry, Docker Hub, or Harbor.
+package templateregistry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// Scheme prefixes template registry references.
+	Scheme = "oci://"
+
+	// ManifestMediaType is the media type of the manifests pushed by Push.
+	ManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	// ConfigMediaType is the media type of the config blob of a template.
+	ConfigMediaType = "application/vnd.coder.template.config.v1+json"
+	// LayerMediaType is the media type of the template source archive.
+	LayerMediaType = "application/vnd.coder.template.layer.v1.tar"
+
+	// MaxArchiveSize is the largest archive that is pulled, matching the
+	// size limit of uploaded files.
+	MaxArchiveSize = 10 * (10 << 20)
+)
+
+var (
+	repositoryRegex = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	tagRegex        = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]{0,127}$`)
+	digestRegex     = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// Reference identifies a template in a registry, e.g.
+// oci://ghcr.io/coder/templates/docker:v1.
+type Reference struct {
+	Registry   string
+	Repository string
+	// Tag is the tag of the template. Digest takes precedence if both are
+	// set.
+	Tag    string
+	Digest string
+}
+
+// ParseReference parses a reference with an optional oci:// scheme. The tag
+// defaults to "latest" if neither a tag nor a digest is given.
+func ParseReference(raw string) (Reference, error) {
+	s := strings.TrimPrefix(raw, Scheme)
+	registry, rest, ok := strings.Cut(s, "/")
+	if !ok || registry == "" || rest == "" {
+		return Reference{}, xerrors.Errorf("reference %q must include a registry and a repository", raw)
+	}
+	ref := Reference{Registry: registry}
+	if repository, digest, ok := strings.Cut(rest, "@"); ok {
+		if !digestRegex.MatchString(digest) {
+			return Reference{}, xerrors.Errorf("reference %q has an invalid digest", raw)
+		}
+		rest, ref.Digest = repository, digest
+	}
+	// Only a colon after the last slash separates the tag.
+	if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		rest, ref.Tag = rest[:i], rest[i+1:]
+		if !tagRegex.MatchString(ref.Tag) {
+			return Reference{}, xerrors.Errorf("reference %q has an invalid tag", raw)
+		}
+	}
+	if !repositoryRegex.MatchString(rest) {
+		return Reference{}, xerrors.Errorf("reference %q has an invalid repository, it must be lowercase", raw)
+	}
+	ref.Repository = rest
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+// String returns the reference with the oci:// scheme.
+func (r Reference) String() string {
+	s := Scheme + r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// manifestReference is the tag or digest that identifies the manifest.
+func (r Reference) manifestReference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+type manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	Config        descriptor   `json:"config"`
+	Layers        []descriptor `json:"layers"`
+}
+
+// Registry is a registry that a Client may access.
+type Registry struct {
+	// Host is the host and optional port of the registry, e.g. ghcr.io.
+	Host string
+	// Insecure accesses the registry over HTTP instead of HTTPS.
+	Insecure bool
+}
+
+// ParseRegistry parses a registry host with an optional port, e.g. ghcr.io
+// or registry.example.com:5000. Registries prefixed with http:// are accessed
+// over HTTP.
+func ParseRegistry(raw string) (Registry, error) {
+	registry := Registry{Host: raw}
+	if host, ok := strings.CutPrefix(raw, "http://"); ok {
+		registry = Registry{Host: host, Insecure: true}
+	} else if host, ok := strings.CutPrefix(raw, "https://"); ok {
+		registry.Host = host
+	}
+	registry.Host = strings.ToLower(strings.TrimSuffix(registry.Host, "/"))
+	if registry.Host == "" || strings.ContainsAny(registry.Host, "/?#@ ") {
+		return Registry{}, xerrors.Errorf("registry %q must be a host with an optional port", raw)
+	}
+	return registry, nil
+}
+
+func (r Registry) scheme() string {
+	if r.Insecure {
+		return "http"
+	}
+	return "https"
+}
+
+// ErrRegistryNotAllowed is returned for references to registries that the
+// client may not access.
+var ErrRegistryNotAllowed = xerrors.New("registry is not allowed")
+
+// Client pushes and pulls templates.
+type Client struct {
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Registries are the registries the client may access. References to
+	// other registries fail with ErrRegistryNotAllowed. Registries may be on
+	// private networks, but token realms must be on one of the registries,
+	// and other hosts that registries redirect to must use HTTPS and resolve
+	// to public addresses.
+	Registries []Registry
+	// Username and Password authenticate with the registry, either directly
+	// or to obtain a bearer token. Registries such as GitHub Container
+	// Registry accept an access token as the password.
+	Username string
+	Password string
+
+	mutex sync.Mutex
+	// tokens caches bearer tokens by scope.
+	tokens map[string]string
+}
+
+// Push uploads the tar archive of a template and tags it with the tag of the
+// reference. It returns the digest of the manifest, which identifies the
+// pushed template.
+func (c *Client) Push(ctx context.Context, ref Reference, archive []byte) (string, error) {
+	if ref.Tag == "" {
+		return "", xerrors.New("a tag is required to push")
+	}
+	if _, ok := c.registry(ref.Registry); !ok {
+		return "", xerrors.Errorf("%w: %s", ErrRegistryNotAllowed, ref.Registry)
+	}
+	scope := fmt.Sprintf("repository:%s:pull,push", ref.Repository)
+
+	config := []byte("{}")
+	layer := descriptor{
+		MediaType: LayerMediaType,
+		Digest:    digestOf(archive),
+		Size:      int64(len(archive)),
+	}
+	for _, blob := range [][]byte{config, archive} {
+		err := c.pushBlob(ctx, ref, scope, blob)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	data, err := json.Marshal(manifest{
+		SchemaVersion: 2,
+		MediaType:     ManifestMediaType,
+		Config: descriptor{
+			MediaType: ConfigMediaType,
+			Digest:    digestOf(config),
+			Size:      int64(len(config)),
+		},
+		Layers: []descriptor{layer},
+	})
+	if err != nil {
+		return "", xerrors.Errorf("marshal manifest: %w", err)
+	}
+	res, err := c.do(ctx, ref, scope, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.url(ref, "manifests/"+ref.Tag), bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", ManifestMediaType)
+		return req, nil
+	})
+	if err != nil {
+		return "", xerrors.Errorf("push manifest: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return "", xerrors.Errorf("push manifest: %w", readError(res))
+	}
+	return digestOf(data), nil
+}
+
+// pushBlob uploads the blob unless the registry already has it.
+func (c *Client) pushBlob(ctx context.Context, ref Reference, scope string, blob []byte) error {
+	digest := digestOf(blob)
+	res, err := c.do(ctx, ref, scope, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodHead, c.url(ref, "blobs/"+digest), nil)
+	})
+	if err != nil {
+		return xerrors.Errorf("check blob: %w", err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	res, err = c.do(ctx, ref, scope, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodPost, c.url(ref, "blobs/uploads/"), nil)
+	})
+	if err != nil {
+		return xerrors.Errorf("start blob upload: %w", err)
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusAccepted {
+		return xerrors.Errorf("start blob upload: %w", readError(res))
+	}
+	location, err := res.Request.URL.Parse(res.Header.Get("Location"))
+	if err != nil {
+		return xerrors.Errorf("parse upload location: %w", err)
+	}
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+
+	res, err = c.do(ctx, ref, scope, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, location.String(), bytes.NewReader(blob))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		return req, nil
+	})
+	if err != nil {
+		return xerrors.Errorf("upload blob: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return xerrors.Errorf("upload blob: %w", readError(res))
+	}
+	return nil
+}
+
+// Pull downloads the tar archive of a template.
+func (c *Client) Pull(ctx context.Context, ref Reference) ([]byte, error) {
+	if _, ok := c.registry(ref.Registry); !ok {
+		return nil, xerrors.Errorf("%w: %s", ErrRegistryNotAllowed, ref.Registry)
+	}
+	scope := fmt.Sprintf("repository:%s:pull", ref.Repository)
+
+	res, err := c.do(ctx, ref, scope, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(ref, "manifests/"+ref.manifestReference()), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", ManifestMediaType)
+		return req, nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("get manifest: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("get manifest: %w", readError(res))
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, 4<<20))
+	if err != nil {
+		return nil, xerrors.Errorf("read manifest: %w", err)
+	}
+	if ref.Digest != "" && digestOf(data) != ref.Digest {
+		return nil, xerrors.Errorf("manifest does not match digest %s", ref.Digest)
+	}
+	var m manifest
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		return nil, xerrors.Errorf("decode manifest: %w", err)
+	}
+	var layer *descriptor
+	for i := range m.Layers {
+		if m.Layers[i].MediaType == LayerMediaType {
+			layer = &m.Layers[i]
+			break
+		}
+	}
+	if layer == nil {
+		return nil, xerrors.Errorf("%s is not a template, it has no layer of type %s", ref, LayerMediaType)
+	}
+	if layer.Size > MaxArchiveSize {
+		return nil, xerrors.Errorf("template archive is too large: %d bytes", layer.Size)
+	}
+
+	res, err = c.do(ctx, ref, scope, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, c.url(ref, "blobs/"+layer.Digest), nil)
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("get archive: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("get archive: %w", readError(res))
+	}
+	archive, err := io.ReadAll(io.LimitReader(res.Body, MaxArchiveSize+1))
+	if err != nil {
+		return nil, xerrors.Errorf("read archive: %w", err)
+	}
+	if len(archive) > MaxArchiveSize {
+		return nil, xerrors.New("template archive is too large")
+	}
+	if digestOf(archive) != layer.Digest {
+		return nil, xerrors.Errorf("archive does not match digest %s", layer.Digest)
+	}
+	return archive, nil
+}
+
+// do sends the request, and authenticates and retries it once if the
+// registry challenges it. newRequest is called for each attempt so the body
+// can be read again.
+func (c *Client) do(ctx context.Context, ref Reference, scope string, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+	err = c.checkURL(ctx, req.URL)
+	if err != nil {
+		return nil, err
+	}
+	// Credentials are only sent to the registries, not to other hosts they
+	// point to, such as upload locations.
+	_, authenticate := c.registry(req.URL.Host)
+	c.mutex.Lock()
+	token, ok := c.tokens[ref.Registry+" "+scope]
+	c.mutex.Unlock()
+	if ok && authenticate {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusUnauthorized || !authenticate {
+		return res, nil
+	}
+	challenge := res.Header.Get("WWW-Authenticate")
+	_ = res.Body.Close()
+
+	scheme, params := parseChallenge(challenge)
+	req, err = newRequest()
+	if err != nil {
+		return nil, err
+	}
+	switch scheme {
+	case "basic":
+		if c.Username == "" && c.Password == "" {
+			return nil, xerrors.New("the registry requires credentials")
+		}
+		req.SetBasicAuth(c.Username, c.Password)
+	case "bearer":
+		token, err := c.token(ctx, params, scope)
+		if err != nil {
+			return nil, err
+		}
+		c.mutex.Lock()
+		if c.tokens == nil {
+			c.tokens = map[string]string{}
+		}
+		c.tokens[ref.Registry+" "+scope] = token
+		c.mutex.Unlock()
+		req.Header.Set("Authorization", "Bearer "+token)
+	default:
+		return nil, xerrors.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	return c.httpClient().Do(req)
+}
+
+// token requests a bearer token from the realm of the challenge.
+func (c *Client) token(ctx context.Context, params map[string]string, scope string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", xerrors.Errorf("invalid token realm %q", params["realm"])
+	}
+	// The credentials are sent to the realm, so it must be one of the
+	// registries.
+	registry, ok := c.registry(realm.Host)
+	if !ok || realm.Scheme != registry.scheme() {
+		return "", xerrors.Errorf("token realm %q is not on an allowed registry", params["realm"])
+	}
+	query := realm.Query()
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	res, err := c.httpClient().Do(req)
+	if err != nil {
+		return "", xerrors.Errorf("get token: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return "", xerrors.Errorf("get token: %w", readError(res))
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(res.Body).Decode(&body)
+	if err != nil {
+		return "", xerrors.Errorf("decode token: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", xerrors.New("the token response has no token")
+}
+
+// httpClient returns the HTTP client with redirects restricted like other
+// requests.
+func (c *Client) httpClient() *http.Client {
+	client := http.DefaultClient
+	if c.HTTPClient != nil {
+		client = c.HTTPClient
+	}
+	restricted := *client
+	restricted.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return xerrors.New("stopped after 10 redirects")
+		}
+		if client.CheckRedirect != nil {
+			err := client.CheckRedirect(req, via)
+			if err != nil {
+				return err
+			}
+		}
+		return c.checkURL(req.Context(), req.URL)
+	}
+	return &restricted
+}
+
+// registry returns the allowed registry with the host.
+func (c *Client) registry(host string) (Registry, bool) {
+	for _, registry := range c.Registries {
+		if strings.EqualFold(registry.Host, host) {
+			return registry, true
+		}
+	}
+	return Registry{}, false
+}
+
+// checkURL returns an error unless the URL is on an allowed registry, or uses
+// HTTPS and resolves to public addresses. This keeps registries from making
+// the client send requests to internal services, such as cloud metadata
+// endpoints.
+func (c *Client) checkURL(ctx context.Context, u *url.URL) error {
+	if registry, ok := c.registry(u.Host); ok {
+		if u.Scheme != registry.scheme() {
+			return xerrors.Errorf("%s must be accessed over %s", u.Host, registry.scheme())
+		}
+		return nil
+	}
+	if u.Scheme != "https" {
+		return xerrors.Errorf("%s must be accessed over https", u.Host)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return xerrors.Errorf("resolve %s: %w", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		ip := addr.IP
+		if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+			ip.IsUnspecified() || ip.IsMulticast() {
+			return xerrors.Errorf("%s resolves to the non-public address %s", u.Hostname(), ip)
+		}
+	}
+	return nil
+}
+
+// url returns the URL of a path in the repository of the reference.
+func (c *Client) url(ref Reference, path string) string {
+	registry, _ := c.registry(ref.Registry)
+	return fmt.Sprintf("%s://%s/v2/%s/%s", registry.scheme(), ref.Registry, ref.Repository, path)
+}
+
+// parseChallenge parses a WWW-Authenticate header, e.g.
+// Bearer realm="https://ghcr.io/token",service="ghcr.io".
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, ", "), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return strings.ToLower(scheme), params
+}
+
+// readError returns an error with the status and the errors the registry
+// returned.
+func readError(res *http.Response) error {
+	var body struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if json.Unmarshal(data, &body) == nil && len(body.Errors) > 0 {
+		messages := make([]string, 0, len(body.Errors))
+		for _, e := range body.Errors {
+			messages = append(messages, fmt.Sprintf("%s: %s", e.Code, e.Message))
+		}
+		return xerrors.Errorf("%s: %s", res.Status, strings.Join(messages, "; "))
+	}
+	return xerrors.New(res.Status)
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
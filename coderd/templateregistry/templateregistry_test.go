@@ -0,0 +1,209 @@
+package templateregistry_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/templateregistry"
+	"github.com/coder/coder/coderd/templateregistry/templateregistrytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestParseReference(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []struct {
+		Name      string
+		Reference string
+		Expected  templateregistry.Reference
+		Error     string
+	}{{
+		Name:      "Tag",
+		Reference: "oci://ghcr.io/coder/templates/docker:v1",
+		Expected:  templateregistry.Reference{Registry: "ghcr.io", Repository: "coder/templates/docker", Tag: "v1"},
+	}, {
+		Name:      "DefaultTag",
+		Reference: "ghcr.io/coder/docker",
+		Expected:  templateregistry.Reference{Registry: "ghcr.io", Repository: "coder/docker", Tag: "latest"},
+	}, {
+		Name:      "Port",
+		Reference: "oci://localhost:5000/docker",
+		Expected:  templateregistry.Reference{Registry: "localhost:5000", Repository: "docker", Tag: "latest"},
+	}, {
+		Name:      "Digest",
+		Reference: "oci://ghcr.io/coder/docker@sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		Expected: templateregistry.Reference{
+			Registry:   "ghcr.io",
+			Repository: "coder/docker",
+			Digest:     "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		},
+	}, {
+		Name:      "NoRepository",
+		Reference: "oci://ghcr.io",
+		Error:     "must include a registry and a repository",
+	}, {
+		Name:      "Uppercase",
+		Reference: "oci://ghcr.io/Coder/docker",
+		Error:     "invalid repository",
+	}, {
+		Name:      "InvalidDigest",
+		Reference: "oci://ghcr.io/coder/docker@sha256:abc",
+		Error:     "invalid digest",
+	}} {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			ref, err := templateregistry.ParseReference(c.Reference)
+			if c.Error != "" {
+				require.ErrorContains(t, err, c.Error)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.Expected, ref)
+		})
+	}
+}
+
+func TestPushPull(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Anonymous", func(t *testing.T) {
+		t.Parallel()
+		registry := templateregistrytest.New(t, nil)
+		ref, err := templateregistry.ParseReference("oci://" + registry.Host + "/coder/docker:v1")
+		require.NoError(t, err)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client := &templateregistry.Client{Registries: registry.Registries()}
+		digest, err := client.Push(ctx, ref, []byte("archive"))
+		require.NoError(t, err)
+		require.NotEmpty(t, digest)
+
+		archive, err := client.Pull(ctx, ref)
+		require.NoError(t, err)
+		require.Equal(t, []byte("archive"), archive)
+
+		// The digest identifies the pushed template.
+		ref.Tag = ""
+		ref.Digest = digest
+		archive, err = client.Pull(ctx, ref)
+		require.NoError(t, err)
+		require.Equal(t, []byte("archive"), archive)
+	})
+
+	t.Run("Token", func(t *testing.T) {
+		t.Parallel()
+		registry := templateregistrytest.New(t, &templateregistrytest.Options{
+			Username: "coder",
+			Password: "hunter2",
+		})
+		ref, err := templateregistry.ParseReference("oci://" + registry.Host + "/coder/docker")
+		require.NoError(t, err)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err = (&templateregistry.Client{Registries: registry.Registries()}).Push(ctx, ref, []byte("archive"))
+		require.Error(t, err)
+
+		client := &templateregistry.Client{
+			Registries: registry.Registries(),
+			Username:   "coder",
+			Password:   "hunter2",
+		}
+		_, err = client.Push(ctx, ref, []byte("archive"))
+		require.NoError(t, err)
+		archive, err := client.Pull(ctx, ref)
+		require.NoError(t, err)
+		require.Equal(t, []byte("archive"), archive)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		registry := templateregistrytest.New(t, nil)
+		ref, err := templateregistry.ParseReference("oci://" + registry.Host + "/coder/docker:v1")
+		require.NoError(t, err)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err = (&templateregistry.Client{Registries: registry.Registries()}).Pull(ctx, ref)
+		require.ErrorContains(t, err, "MANIFEST_UNKNOWN")
+	})
+
+	t.Run("NotAllowed", func(t *testing.T) {
+		t.Parallel()
+		registry := templateregistrytest.New(t, nil)
+		ref, err := templateregistry.ParseReference("oci://" + registry.Host + "/coder/docker:v1")
+		require.NoError(t, err)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client := &templateregistry.Client{
+			Registries: []templateregistry.Registry{{Host: "ghcr.io"}},
+		}
+		_, err = client.Push(ctx, ref, []byte("archive"))
+		require.ErrorIs(t, err, templateregistry.ErrRegistryNotAllowed)
+		_, err = client.Pull(ctx, ref)
+		require.ErrorIs(t, err, templateregistry.ErrRegistryNotAllowed)
+
+		// Registries are only accessed over HTTP if they are allowed with it.
+		client.Registries = []templateregistry.Registry{{Host: registry.Host}}
+		_, err = client.Pull(ctx, ref)
+		require.ErrorContains(t, err, "server gave HTTP response to HTTPS client")
+	})
+
+	t.Run("RealmNotAllowed", func(t *testing.T) {
+		t.Parallel()
+		registry := templateregistrytest.New(t, &templateregistrytest.Options{
+			Username: "coder",
+			Password: "hunter2",
+			Realm:    "http://169.254.169.254/token",
+		})
+		ref, err := templateregistry.ParseReference("oci://" + registry.Host + "/coder/docker:v1")
+		require.NoError(t, err)
+
+		// The credentials are not sent to realms on other hosts.
+		ctx := testutil.Context(t, testutil.WaitShort)
+		client := &templateregistry.Client{
+			Registries: registry.Registries(),
+			Username:   "coder",
+			Password:   "hunter2",
+		}
+		_, err = client.Pull(ctx, ref)
+		require.ErrorContains(t, err, "is not on an allowed registry")
+	})
+}
+
+func TestParseRegistry(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []struct {
+		Registry string
+		Expected templateregistry.Registry
+		Error    bool
+	}{{
+		Registry: "ghcr.io",
+		Expected: templateregistry.Registry{Host: "ghcr.io"},
+	}, {
+		Registry: "https://registry.example.com:5000",
+		Expected: templateregistry.Registry{Host: "registry.example.com:5000"},
+	}, {
+		Registry: "http://localhost:5000",
+		Expected: templateregistry.Registry{Host: "localhost:5000", Insecure: true},
+	}, {
+		Registry: "ghcr.io/coder",
+		Error:    true,
+	}, {
+		Registry: "",
+		Error:    true,
+	}} {
+		c := c
+		t.Run(c.Registry, func(t *testing.T) {
+			t.Parallel()
+			registry, err := templateregistry.ParseRegistry(c.Registry)
+			if c.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.Expected, registry)
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package templateregistrytest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/templateregistry"
+)
+
+// Options configure the registry.
+type Options struct {
+	// Username and Password are required to obtain a bearer token if set.
+	Username string
+	Password string
+	// Realm overrides the token realm of authentication challenges.
+	Realm string
+}
+
+// Registry is an in-memory registry that implements the parts of the OCI
+// distribution specification used by templateregistry.
+type Registry struct {
+	// Host is the host and port of the registry, to use as the registry of
+	// references.
+	Host string
+
+	options   Options
+	token     string
+	mutex     sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+}
+
+// New starts a registry that is closed when the test ends.
+func New(t testing.TB, options *Options) *Registry {
+	t.Helper()
+	if options == nil {
+		options = &Options{}
+	}
+	registry := &Registry{
+		options:   *options,
+		token:     uuid.NewString(),
+		blobs:     map[string][]byte{},
+		manifests: map[string][]byte{},
+	}
+	srv := httptest.NewServer(registry)
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.Host = u.Host
+	return registry
+}
+
+// Registries returns the registries to allow to access the registry, which is
+// served over HTTP.
+func (r *Registry) Registries() []templateregistry.Registry {
+	return []templateregistry.Registry{{Host: r.Host, Insecure: true}}
+}
+
+// Manifest returns the manifest the repository is tagged with.
+func (r *Registry) Manifest(repository, tag string) ([]byte, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	data, ok := r.manifests[repository+":"+tag]
+	return data, ok
+}
+
+func (r *Registry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		username, password, _ := req.BasicAuth()
+		if username != r.options.Username || password != r.options.Password {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(rw).Encode(map[string]string{"token": r.token})
+		return
+	}
+	if r.options.Username != "" && req.Header.Get("Authorization") != "Bearer "+r.token {
+		realm := "http://" + req.Host + "/token"
+		if r.options.Realm != "" {
+			realm = r.options.Realm
+		}
+		rw.Header().Set("WWW-Authenticate", `Bearer realm="`+realm+`",service="registry"`)
+		writeError(rw, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case strings.HasSuffix(path, "/blobs/uploads/") && req.Method == http.MethodPost:
+		rw.Header().Set("Location", "/v2/"+path+uuid.NewString())
+		rw.WriteHeader(http.StatusAccepted)
+	case strings.Contains(path, "/blobs/uploads/") && req.Method == http.MethodPut:
+		data, _ := io.ReadAll(req.Body)
+		digest := req.URL.Query().Get("digest")
+		if digestOf(data) != digest {
+			writeError(rw, http.StatusBadRequest, "DIGEST_INVALID", "digest does not match")
+			return
+		}
+		r.mutex.Lock()
+		r.blobs[digest] = data
+		r.mutex.Unlock()
+		rw.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/blobs/"):
+		digest := path[strings.LastIndex(path, "/")+1:]
+		r.mutex.Lock()
+		data, ok := r.blobs[digest]
+		r.mutex.Unlock()
+		if !ok {
+			writeError(rw, http.StatusNotFound, "BLOB_UNKNOWN", "blob unknown")
+			return
+		}
+		if req.Method == http.MethodGet {
+			_, _ = rw.Write(data)
+		}
+	case strings.Contains(path, "/manifests/"):
+		repository, reference, _ := strings.Cut(path, "/manifests/")
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		if req.Method == http.MethodPut {
+			data, _ := io.ReadAll(req.Body)
+			r.manifests[repository+":"+reference] = data
+			r.manifests[repository+":"+digestOf(data)] = data
+			rw.WriteHeader(http.StatusCreated)
+			return
+		}
+		data, ok := r.manifests[repository+":"+reference]
+		if !ok {
+			writeError(rw, http.StatusNotFound, "MANIFEST_UNKNOWN", "manifest unknown")
+			return
+		}
+		_, _ = rw.Write(data)
+	default:
+		rw.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeError(rw http.ResponseWriter, status int, code, message string) {
+	rw.WriteHeader(status)
+	_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+		"errors": []map[string]string{{"code": code, "message": message}},
+	})
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -2,6 +2,7 @@ package coderd
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,11 +13,13 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
+	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/prebuilds"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/telemetry"
@@ -39,6 +42,15 @@ func (api *API) template(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	template := httpmw.TemplateParam(r)
 
+	template, err := schedule.WithSchedulePolicy(ctx, api.Database, template)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template schedule policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(template))
 }
 
@@ -78,7 +90,12 @@ func (api *API) deleteTemplate(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	if len(workspaces) > 0 {
+	for _, workspace := range workspaces {
+		// Prebuilt workspaces are deleted by the prebuilds controller once
+		// the template is deleted.
+		if workspace.OwnerID == prebuilds.OwnerID {
+			continue
+		}
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message: "All workspaces must be deleted before a template can be removed.",
 		})
@@ -213,11 +230,15 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 	}
 
 	var (
-		defaultTTL time.Duration
+		defaultTTL   time.Duration
+		activityBump time.Duration
 		// TODO(@dean): remove max_ttl once restart_requirement is ready
 		maxTTL                       time.Duration
 		restartRequirementDaysOfWeek []string
 		restartRequirementWeeks      int64
+		restartRequirementTimezone   string
+		restartRequirementSpread     time.Duration
+		updateOnRestart              bool
 		failureTTL                   time.Duration
 		inactivityTTL                time.Duration
 		lockedTTL                    time.Duration
@@ -225,9 +246,15 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 	if createTemplate.DefaultTTLMillis != nil {
 		defaultTTL = time.Duration(*createTemplate.DefaultTTLMillis) * time.Millisecond
 	}
+	if createTemplate.ActivityBumpMillis != nil {
+		activityBump = time.Duration(*createTemplate.ActivityBumpMillis) * time.Millisecond
+	}
 	if createTemplate.RestartRequirement != nil {
 		restartRequirementDaysOfWeek = createTemplate.RestartRequirement.DaysOfWeek
 		restartRequirementWeeks = createTemplate.RestartRequirement.Weeks
+		restartRequirementTimezone = createTemplate.RestartRequirement.Timezone
+		restartRequirementSpread = time.Duration(createTemplate.RestartRequirement.SpreadMillis) * time.Millisecond
+		updateOnRestart = createTemplate.RestartRequirement.UpdateOnRestart
 	}
 	if createTemplate.FailureTTLMillis != nil {
 		failureTTL = time.Duration(*createTemplate.FailureTTLMillis) * time.Millisecond
@@ -246,6 +273,9 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 	if defaultTTL < 0 {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "default_ttl_ms", Detail: "Must be a positive integer."})
 	}
+	if activityBump < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "activity_bump_ms", Detail: "Must be a positive integer."})
+	}
 	if maxTTL < 0 {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "max_ttl_ms", Detail: "Must be a positive integer."})
 	}
@@ -267,6 +297,15 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 	if restartRequirementWeeks > schedule.MaxTemplateRestartRequirementWeeks {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.weeks", Detail: fmt.Sprintf("Must be less than %d.", schedule.MaxTemplateRestartRequirementWeeks)})
 	}
+	if restartRequirementTimezone != "" {
+		_, err = time.LoadLocation(restartRequirementTimezone)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.timezone", Detail: fmt.Sprintf("Invalid IANA timezone: %s", err.Error())})
+		}
+	}
+	if err := schedule.VerifyTemplateRestartRequirementSpread(restartRequirementSpread); err != nil {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.spread_ms", Detail: err.Error()})
+	}
 	if failureTTL < 0 {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "failure_ttl_ms", Detail: "Must be a positive integer."})
 	}
@@ -328,10 +367,13 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 			return xerrors.Errorf("get template by id: %s", err)
 		}
 
-		dbTemplate, err = (*api.TemplateScheduleStore.Load()).Set(ctx, tx, dbTemplate, schedule.TemplateScheduleOptions{
+		// New templates don't have workspace builds, so there are no
+		// changes to audit.
+		dbTemplate, _, err = (*api.TemplateScheduleStore.Load()).Set(ctx, tx, dbTemplate, schedule.TemplateScheduleOptions{
 			UserAutostartEnabled: allowUserAutostart,
 			UserAutostopEnabled:  allowUserAutostop,
 			DefaultTTL:           defaultTTL,
+			ActivityBumpTTL:      activityBump,
 			MaxTTL:               maxTTL,
 			// Some of these values are enterprise-only, but the
 			// TemplateScheduleStore will handle avoiding setting them if
@@ -339,10 +381,13 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 			RestartRequirement: schedule.TemplateRestartRequirement{
 				DaysOfWeek: restartRequirementDaysOfWeekParsed,
 				Weeks:      restartRequirementWeeks,
+				Timezone:   restartRequirementTimezone,
 			},
-			FailureTTL:    failureTTL,
-			InactivityTTL: inactivityTTL,
-			LockedTTL:     lockedTTL,
+			RestartRequirementSpread: restartRequirementSpread,
+			UpdateOnRestart:          updateOnRestart,
+			FailureTTL:               failureTTL,
+			InactivityTTL:            inactivityTTL,
+			LockedTTL:                lockedTTL,
 		})
 		if err != nil {
 			return xerrors.Errorf("set template schedule options: %s", err)
@@ -426,6 +471,27 @@ func (api *API) templatesByOrganization(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Templates that share a schedule policy template resolve it once.
+	policies := make(map[uuid.UUID]database.Template)
+	for i, template := range templates {
+		if !template.SchedulePolicyTemplateID.Valid {
+			continue
+		}
+		policy, ok := policies[template.SchedulePolicyTemplateID.UUID]
+		if !ok {
+			policy, err = schedule.ResolveSchedulePolicy(ctx, api.Database, template)
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching template schedule policy.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+			policies[template.SchedulePolicyTemplateID.UUID] = policy
+		}
+		templates[i] = schedule.InheritSchedule(template, policy)
+	}
+
 	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplates(templates))
 }
 
@@ -459,6 +525,15 @@ func (api *API) templateByOrganizationAndName(rw http.ResponseWriter, r *http.Re
 		return
 	}
 
+	template, err = schedule.WithSchedulePolicy(ctx, api.Database, template)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template schedule policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(template))
 }
 
@@ -472,15 +547,16 @@ func (api *API) templateByOrganizationAndName(rw http.ResponseWriter, r *http.Re
 // @Router /templates/{template} [patch]
 func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 	var (
-		ctx               = r.Context()
-		template          = httpmw.TemplateParam(r)
-		auditor           = *api.Auditor.Load()
-		aReq, commitAudit = audit.InitRequest[database.Template](rw, &audit.RequestParams{
+		ctx         = r.Context()
+		template    = httpmw.TemplateParam(r)
+		auditor     = *api.Auditor.Load()
+		auditParams = &audit.RequestParams{
 			Audit:   auditor,
 			Log:     api.Logger,
 			Request: r,
 			Action:  database.AuditActionWrite,
-		})
+		}
+		aReq, commitAudit = audit.InitRequest[database.Template](rw, auditParams)
 	)
 	defer commitAudit()
 	aReq.Old = template
@@ -493,6 +569,16 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	// Templates with a schedule policy template report the inherited schedule
+	// settings, so compare the request against those.
+	scheduleTemplate, err := schedule.WithSchedulePolicy(ctx, api.Database, template)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template schedule policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
 
 	var req codersdk.UpdateTemplateMeta
 	if !httpapi.Read(ctx, rw, r, &req) {
@@ -506,6 +592,9 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 	if req.DefaultTTLMillis < 0 {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "default_ttl_ms", Detail: "Must be a positive integer."})
 	}
+	if req.ActivityBumpMillis != nil && *req.ActivityBumpMillis < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "activity_bump_ms", Detail: "Must be a positive integer."})
+	}
 	if req.MaxTTLMillis < 0 {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "max_ttl_ms", Detail: "Must be a positive integer."})
 	}
@@ -514,8 +603,11 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 	}
 	if req.RestartRequirement == nil {
 		req.RestartRequirement = &codersdk.TemplateRestartRequirement{
-			DaysOfWeek: codersdk.BitmapToWeekdays(scheduleOpts.RestartRequirement.DaysOfWeek),
-			Weeks:      scheduleOpts.RestartRequirement.Weeks,
+			DaysOfWeek:      codersdk.BitmapToWeekdays(scheduleOpts.RestartRequirement.DaysOfWeek),
+			Weeks:           scheduleOpts.RestartRequirement.Weeks,
+			Timezone:        scheduleOpts.RestartRequirement.Timezone,
+			SpreadMillis:    scheduleOpts.RestartRequirementSpread.Milliseconds(),
+			UpdateOnRestart: scheduleOpts.UpdateOnRestart,
 		}
 	}
 	if len(req.RestartRequirement.DaysOfWeek) > 0 {
@@ -530,6 +622,16 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 	if req.RestartRequirement.Weeks > schedule.MaxTemplateRestartRequirementWeeks {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.weeks", Detail: fmt.Sprintf("Must be less than %d.", schedule.MaxTemplateRestartRequirementWeeks)})
 	}
+	if req.RestartRequirement.Timezone != "" {
+		_, err = time.LoadLocation(req.RestartRequirement.Timezone)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.timezone", Detail: fmt.Sprintf("Invalid IANA timezone: %s", err.Error())})
+		}
+	}
+	restartRequirementSpread := time.Duration(req.RestartRequirement.SpreadMillis) * time.Millisecond
+	if err := schedule.VerifyTemplateRestartRequirementSpread(restartRequirementSpread); err != nil {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.spread_ms", Detail: err.Error()})
+	}
 	if req.FailureTTLMillis < 0 {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "failure_ttl_ms", Detail: "Must be a positive integer."})
 	}
@@ -542,6 +644,98 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 	if req.LockedTTLMillis < 0 {
 		validErrs = append(validErrs, codersdk.ValidationError{Field: "locked_ttl_ms", Detail: "Must be a positive integer."})
 	}
+	failureRetries := scheduleOpts.FailureRetries
+	if req.FailureRetries != nil {
+		failureRetries = *req.FailureRetries
+	}
+	if failureRetries < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "failure_retries", Detail: "Must not be negative."})
+	}
+	if failureRetries > schedule.MaxTemplateFailureRetries {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "failure_retries", Detail: fmt.Sprintf("Must be at most %d.", schedule.MaxTemplateFailureRetries)})
+	}
+	failureRetryBackoff := scheduleOpts.FailureRetryBackoff
+	if req.FailureRetryBackoffMillis != nil {
+		failureRetryBackoff = time.Duration(*req.FailureRetryBackoffMillis) * time.Millisecond
+	}
+	if failureRetryBackoff < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "failure_retry_backoff_ms", Detail: "Must not be negative."})
+	}
+	maxLifetime := scheduleOpts.MaxLifetime
+	if req.MaxLifetimeMillis != nil {
+		maxLifetime = time.Duration(*req.MaxLifetimeMillis) * time.Millisecond
+	}
+	if maxLifetime < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "max_lifetime_ms", Detail: "Must be a positive integer."})
+	}
+	archiveRetention := scheduleOpts.ArchiveRetention
+	if req.ArchiveRetentionMillis != nil {
+		archiveRetention = time.Duration(*req.ArchiveRetentionMillis) * time.Millisecond
+	}
+	if archiveRetention < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "archive_retention_ms", Detail: "Must be a positive integer."})
+	}
+	maxConcurrentBuilds := template.MaxConcurrentBuilds
+	if req.MaxConcurrentBuilds != nil {
+		maxConcurrentBuilds = *req.MaxConcurrentBuilds
+	}
+	if maxConcurrentBuilds < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "max_concurrent_builds", Detail: "Must be a positive integer."})
+	}
+	maxDeadlineExtensionsPerDay := template.MaxDeadlineExtensionsPerDay
+	if req.MaxDeadlineExtensionsPerDay != nil {
+		maxDeadlineExtensionsPerDay = *req.MaxDeadlineExtensionsPerDay
+	}
+	if maxDeadlineExtensionsPerDay < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "max_deadline_extensions_per_day", Detail: "Must be a positive integer."})
+	}
+	maxDeadlineExtension := time.Duration(template.MaxDeadlineExtension)
+	if req.MaxDeadlineExtensionMillis != nil {
+		maxDeadlineExtension = time.Duration(*req.MaxDeadlineExtensionMillis) * time.Millisecond
+	}
+	if maxDeadlineExtension < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "max_deadline_extension_ms", Detail: "Must be a positive integer."})
+	}
+
+	requiredPromotionApprovals := template.RequiredPromotionApprovals
+	if req.RequiredPromotionApprovals != nil {
+		requiredPromotionApprovals = *req.RequiredPromotionApprovals
+	}
+	if requiredPromotionApprovals < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "required_promotion_approvals", Detail: "Must be a positive integer."})
+	}
+	userCPUQuota := template.UserCPUQuotaMillicores
+	if req.UserCPUQuotaMillicores != nil {
+		userCPUQuota = *req.UserCPUQuotaMillicores
+	}
+	if userCPUQuota < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "user_cpu_quota_millicores", Detail: "Must be a positive integer."})
+	}
+	userMemoryQuota := template.UserMemoryQuotaBytes
+	if req.UserMemoryQuotaBytes != nil {
+		userMemoryQuota = *req.UserMemoryQuotaBytes
+	}
+	if userMemoryQuota < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "user_memory_quota_bytes", Detail: "Must be a positive integer."})
+	}
+	userDiskQuota := template.UserDiskQuotaBytes
+	if req.UserDiskQuotaBytes != nil {
+		userDiskQuota = *req.UserDiskQuotaBytes
+	}
+	if userDiskQuota < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "user_disk_quota_bytes", Detail: "Must be a positive integer."})
+	}
+	applyDotfiles := template.ApplyDotfiles
+	if req.ApplyDotfiles != nil {
+		applyDotfiles = *req.ApplyDotfiles
+	}
+	disableAutostop := template.DisableAutostop
+	if req.DisableAutostop != nil {
+		disableAutostop = *req.DisableAutostop
+	}
+	if disableAutostop && req.DefaultTTLMillis != 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "disable_autostop", Detail: "Must be false if default_ttl_ms is set."})
+	}
 
 	if len(validErrs) > 0 {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
@@ -551,22 +745,110 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var updated database.Template
+	// Removing resource quotas is always allowed, so templates can be
+	// cleaned up after the license expires.
+	resourceQuotasChanged := userCPUQuota != template.UserCPUQuotaMillicores ||
+		userMemoryQuota != template.UserMemoryQuotaBytes ||
+		userDiskQuota != template.UserDiskQuotaBytes
+	if resourceQuotasChanged && (userCPUQuota > 0 || userMemoryQuota > 0 || userDiskQuota > 0) && !api.TemplateResourceQuotasEnabled.Load() {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Template resource quotas require an enterprise license.",
+		})
+		return
+	}
+
+	defaultTTL := time.Duration(req.DefaultTTLMillis) * time.Millisecond
+	activityBump := time.Duration(scheduleTemplate.ActivityBumpTTL)
+	if req.ActivityBumpMillis != nil {
+		activityBump = time.Duration(*req.ActivityBumpMillis) * time.Millisecond
+	}
+	maxTTL := time.Duration(req.MaxTTLMillis) * time.Millisecond
+	failureTTL := time.Duration(req.FailureTTLMillis) * time.Millisecond
+	inactivityTTL := time.Duration(req.InactivityTTLMillis) * time.Millisecond
+	lockedTTL := time.Duration(req.LockedTTLMillis) * time.Millisecond
+
+	scheduleChanged := defaultTTL != time.Duration(scheduleTemplate.DefaultTTL) ||
+		activityBump != time.Duration(scheduleTemplate.ActivityBumpTTL) ||
+		maxTTL != time.Duration(scheduleTemplate.MaxTTL) ||
+		restartRequirementDaysOfWeekParsed != scheduleOpts.RestartRequirement.DaysOfWeek ||
+		req.RestartRequirement.Weeks != scheduleOpts.RestartRequirement.Weeks ||
+		req.RestartRequirement.Timezone != scheduleOpts.RestartRequirement.Timezone ||
+		restartRequirementSpread != scheduleOpts.RestartRequirementSpread ||
+		req.RestartRequirement.UpdateOnRestart != scheduleOpts.UpdateOnRestart ||
+		failureTTL != time.Duration(scheduleTemplate.FailureTTL) ||
+		failureRetries != scheduleOpts.FailureRetries ||
+		failureRetryBackoff != scheduleOpts.FailureRetryBackoff ||
+		maxLifetime != scheduleOpts.MaxLifetime ||
+		archiveRetention != scheduleOpts.ArchiveRetention ||
+		inactivityTTL != time.Duration(scheduleTemplate.InactivityTTL) ||
+		lockedTTL != time.Duration(scheduleTemplate.LockedTTL) ||
+		req.AllowUserAutostart != scheduleTemplate.AllowUserAutostart ||
+		req.AllowUserAutostop != scheduleTemplate.AllowUserAutostop
+	if scheduleChanged && template.SchedulePolicyTemplateID.Valid {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Template schedule settings are inherited from a schedule policy template.",
+			Detail:  "Update the schedule policy template, or remove the schedule policy from this template first.",
+		})
+		return
+	}
+
+	if req.DryRun {
+		// Dry runs don't change anything, so there is nothing to audit.
+		aReq.Old = database.Template{}
+
+		changes, err := (*api.TemplateScheduleStore.Load()).DryRun(ctx, api.Database, template, schedule.TemplateScheduleOptions{
+			UserAutostartEnabled: req.AllowUserAutostart,
+			UserAutostopEnabled:  req.AllowUserAutostop,
+			DefaultTTL:           defaultTTL,
+			ActivityBumpTTL:      activityBump,
+			MaxTTL:               maxTTL,
+			RestartRequirement: schedule.TemplateRestartRequirement{
+				DaysOfWeek: restartRequirementDaysOfWeekParsed,
+				Weeks:      req.RestartRequirement.Weeks,
+				Timezone:   req.RestartRequirement.Timezone,
+			},
+			RestartRequirementSpread: restartRequirementSpread,
+			UpdateOnRestart:          req.RestartRequirement.UpdateOnRestart,
+			FailureTTL:               failureTTL,
+			FailureRetries:           failureRetries,
+			FailureRetryBackoff:      failureRetryBackoff,
+			InactivityTTL:            inactivityTTL,
+			LockedTTL:                lockedTTL,
+			MaxLifetime:              maxLifetime,
+			ArchiveRetention:         archiveRetention,
+		})
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error previewing template schedule changes.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+
+		httpapi.Write(ctx, rw, http.StatusOK, convertTemplateScheduleDryRun(changes))
+		return
+	}
+
+	var (
+		updated         database.Template
+		scheduleChanges schedule.TemplateScheduleChanges
+	)
 	err = api.Database.InTx(func(tx database.Store) error {
 		if req.Name == template.Name &&
 			req.Description == template.Description &&
 			req.DisplayName == template.DisplayName &&
 			req.Icon == template.Icon &&
-			req.AllowUserAutostart == template.AllowUserAutostart &&
-			req.AllowUserAutostop == template.AllowUserAutostop &&
+			!scheduleChanged &&
 			req.AllowUserCancelWorkspaceJobs == template.AllowUserCancelWorkspaceJobs &&
-			req.DefaultTTLMillis == time.Duration(template.DefaultTTL).Milliseconds() &&
-			req.MaxTTLMillis == time.Duration(template.MaxTTL).Milliseconds() &&
-			restartRequirementDaysOfWeekParsed == scheduleOpts.RestartRequirement.DaysOfWeek &&
-			req.RestartRequirement.Weeks == scheduleOpts.RestartRequirement.Weeks &&
-			req.FailureTTLMillis == time.Duration(template.FailureTTL).Milliseconds() &&
-			req.InactivityTTLMillis == time.Duration(template.InactivityTTL).Milliseconds() &&
-			req.LockedTTLMillis == time.Duration(template.LockedTTL).Milliseconds() {
+			maxConcurrentBuilds == template.MaxConcurrentBuilds &&
+			maxDeadlineExtensionsPerDay == template.MaxDeadlineExtensionsPerDay &&
+			int64(maxDeadlineExtension) == template.MaxDeadlineExtension &&
+			requiredPromotionApprovals == template.RequiredPromotionApprovals &&
+			userCPUQuota == template.UserCPUQuotaMillicores &&
+			userMemoryQuota == template.UserMemoryQuotaBytes &&
+			userDiskQuota == template.UserDiskQuotaBytes &&
+			applyDotfiles == template.ApplyDotfiles &&
+			disableAutostop == template.DisableAutostop {
 			return nil
 		}
 
@@ -585,6 +867,15 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			Description:                  req.Description,
 			Icon:                         req.Icon,
 			AllowUserCancelWorkspaceJobs: req.AllowUserCancelWorkspaceJobs,
+			MaxConcurrentBuilds:          maxConcurrentBuilds,
+			MaxDeadlineExtensionsPerDay:  maxDeadlineExtensionsPerDay,
+			MaxDeadlineExtension:         int64(maxDeadlineExtension),
+			RequiredPromotionApprovals:   requiredPromotionApprovals,
+			UserCPUQuotaMillicores:       userCPUQuota,
+			UserMemoryQuotaBytes:         userMemoryQuota,
+			UserDiskQuotaBytes:           userDiskQuota,
+			ApplyDotfiles:                applyDotfiles,
+			DisableAutostop:              disableAutostop,
 		})
 		if err != nil {
 			return xerrors.Errorf("update template metadata: %w", err)
@@ -595,36 +886,30 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 			return xerrors.Errorf("fetch updated template metadata: %w", err)
 		}
 
-		defaultTTL := time.Duration(req.DefaultTTLMillis) * time.Millisecond
-		maxTTL := time.Duration(req.MaxTTLMillis) * time.Millisecond
-		failureTTL := time.Duration(req.FailureTTLMillis) * time.Millisecond
-		inactivityTTL := time.Duration(req.InactivityTTLMillis) * time.Millisecond
-		lockedTTL := time.Duration(req.LockedTTLMillis) * time.Millisecond
-
-		if defaultTTL != time.Duration(template.DefaultTTL) ||
-			maxTTL != time.Duration(template.MaxTTL) ||
-			restartRequirementDaysOfWeekParsed != scheduleOpts.RestartRequirement.DaysOfWeek ||
-			req.RestartRequirement.Weeks != scheduleOpts.RestartRequirement.Weeks ||
-			failureTTL != time.Duration(template.FailureTTL) ||
-			inactivityTTL != time.Duration(template.InactivityTTL) ||
-			lockedTTL != time.Duration(template.LockedTTL) ||
-			req.AllowUserAutostart != template.AllowUserAutostart ||
-			req.AllowUserAutostop != template.AllowUserAutostop {
-			updated, err = (*api.TemplateScheduleStore.Load()).Set(ctx, tx, updated, schedule.TemplateScheduleOptions{
+		if scheduleChanged {
+			updated, scheduleChanges, err = (*api.TemplateScheduleStore.Load()).Set(ctx, tx, updated, schedule.TemplateScheduleOptions{
 				// Some of these values are enterprise-only, but the
 				// TemplateScheduleStore will handle avoiding setting them if
 				// unlicensed.
 				UserAutostartEnabled: req.AllowUserAutostart,
 				UserAutostopEnabled:  req.AllowUserAutostop,
 				DefaultTTL:           defaultTTL,
+				ActivityBumpTTL:      activityBump,
 				MaxTTL:               maxTTL,
 				RestartRequirement: schedule.TemplateRestartRequirement{
 					DaysOfWeek: restartRequirementDaysOfWeekParsed,
 					Weeks:      req.RestartRequirement.Weeks,
+					Timezone:   req.RestartRequirement.Timezone,
 				},
-				FailureTTL:    failureTTL,
-				InactivityTTL: inactivityTTL,
-				LockedTTL:     lockedTTL,
+				RestartRequirementSpread: restartRequirementSpread,
+				UpdateOnRestart:          req.RestartRequirement.UpdateOnRestart,
+				FailureTTL:               failureTTL,
+				FailureRetries:           failureRetries,
+				FailureRetryBackoff:      failureRetryBackoff,
+				InactivityTTL:            inactivityTTL,
+				LockedTTL:                lockedTTL,
+				MaxLifetime:              maxLifetime,
+				ArchiveRetention:         archiveRetention,
 			})
 			if err != nil {
 				return xerrors.Errorf("set template schedule options: %w", err)
@@ -644,6 +929,20 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	aReq.New = updated
+	// The changes to workspace builds are only audited once the update is
+	// committed, together with the update itself.
+	if !scheduleChanges.Empty() {
+		auditParams.AdditionalFields, err = json.Marshal(scheduleChanges)
+		if err != nil {
+			api.Logger.Warn(ctx, "marshal template schedule changes", slog.Error(err))
+		}
+	}
+
+	updated, err = schedule.WithSchedulePolicy(ctx, api.Database, updated)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
 
 	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(updated))
 }
@@ -749,18 +1048,52 @@ func (api *API) convertTemplate(
 		Description:                  template.Description,
 		Icon:                         template.Icon,
 		DefaultTTLMillis:             time.Duration(template.DefaultTTL).Milliseconds(),
+		ActivityBumpMillis:           time.Duration(template.ActivityBumpTTL).Milliseconds(),
 		MaxTTLMillis:                 time.Duration(template.MaxTTL).Milliseconds(),
 		CreatedByID:                  template.CreatedBy,
 		CreatedByName:                template.CreatedByUsername,
 		AllowUserAutostart:           template.AllowUserAutostart,
 		AllowUserAutostop:            template.AllowUserAutostop,
 		AllowUserCancelWorkspaceJobs: template.AllowUserCancelWorkspaceJobs,
+		MaxConcurrentBuilds:          template.MaxConcurrentBuilds,
+		MaxDeadlineExtensionsPerDay:  template.MaxDeadlineExtensionsPerDay,
+		MaxDeadlineExtensionMillis:   time.Duration(template.MaxDeadlineExtension).Milliseconds(),
+		RequiredPromotionApprovals:   template.RequiredPromotionApprovals,
+		UserCPUQuotaMillicores:       template.UserCPUQuotaMillicores,
+		UserMemoryQuotaBytes:         template.UserMemoryQuotaBytes,
+		UserDiskQuotaBytes:           template.UserDiskQuotaBytes,
+		ApplyDotfiles:                template.ApplyDotfiles,
+		DisableAutostop:              template.DisableAutostop,
 		FailureTTLMillis:             time.Duration(template.FailureTTL).Milliseconds(),
 		InactivityTTLMillis:          time.Duration(template.InactivityTTL).Milliseconds(),
 		LockedTTLMillis:              time.Duration(template.LockedTTL).Milliseconds(),
+		FailureRetries:               template.FailureRetries,
+		FailureRetryBackoffMillis:    time.Duration(template.FailureRetryBackoff).Milliseconds(),
+		MaxLifetimeMillis:            time.Duration(template.MaxLifetime).Milliseconds(),
+		ArchiveRetentionMillis:       time.Duration(template.ArchiveRetention).Milliseconds(),
 		RestartRequirement: codersdk.TemplateRestartRequirement{
-			DaysOfWeek: codersdk.BitmapToWeekdays(uint8(template.RestartRequirementDaysOfWeek)),
-			Weeks:      template.RestartRequirementWeeks,
+			DaysOfWeek:      codersdk.BitmapToWeekdays(uint8(template.RestartRequirementDaysOfWeek)),
+			Weeks:           template.RestartRequirementWeeks,
+			Timezone:        template.RestartRequirementTimezone,
+			SpreadMillis:    time.Duration(template.RestartRequirementSpread).Milliseconds(),
+			UpdateOnRestart: template.UpdateOnRestart,
 		},
 	}
 }
+
+func convertTemplateScheduleDryRun(changes []schedule.WorkspaceBuildDeadlineChange) codersdk.TemplateScheduleDryRun {
+	workspaces := make([]codersdk.TemplateScheduleDryRunWorkspace, 0, len(changes))
+	for _, change := range changes {
+		workspaces = append(workspaces, codersdk.TemplateScheduleDryRunWorkspace{
+			WorkspaceID:      change.WorkspaceID,
+			WorkspaceName:    change.WorkspaceName,
+			OwnerID:          change.OwnerID,
+			WorkspaceBuildID: change.WorkspaceBuildID,
+			Deadline:         codersdk.NewNullTime(change.Deadline, !change.Deadline.IsZero()),
+			MaxDeadline:      codersdk.NewNullTime(change.MaxDeadline, !change.MaxDeadline.IsZero()),
+			NewDeadline:      codersdk.NewNullTime(change.NewDeadline, !change.NewDeadline.IsZero()),
+			NewMaxDeadline:   codersdk.NewNullTime(change.NewMaxDeadline, !change.NewMaxDeadline.IsZero()),
+		})
+	}
+	return codersdk.TemplateScheduleDryRun{Workspaces: workspaces}
+}
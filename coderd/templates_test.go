@@ -177,13 +177,13 @@ func TestPostTemplateByOrganization(t *testing.T) {
 			var setCalled int64
 			client := coderdtest.New(t, &coderdtest.Options{
 				TemplateScheduleStore: schedule.MockTemplateScheduleStore{
-					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, error) {
+					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
 						atomic.AddInt64(&setCalled, 1)
 						require.False(t, options.UserAutostartEnabled)
 						require.False(t, options.UserAutostopEnabled)
 						template.AllowUserAutostart = options.UserAutostartEnabled
 						template.AllowUserAutostop = options.UserAutostopEnabled
-						return template, nil
+						return template, schedule.TemplateScheduleChanges{}, nil
 					},
 				},
 			})
@@ -255,7 +255,7 @@ func TestPostTemplateByOrganization(t *testing.T) {
 			var setCalled int64
 			client := coderdtest.New(t, &coderdtest.Options{
 				TemplateScheduleStore: schedule.MockTemplateScheduleStore{
-					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, error) {
+					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
 						atomic.AddInt64(&setCalled, 1)
 						assert.Zero(t, options.RestartRequirement.DaysOfWeek)
 						assert.Zero(t, options.RestartRequirement.Weeks)
@@ -274,10 +274,11 @@ func TestPostTemplateByOrganization(t *testing.T) {
 							LockedTTL:                    int64(options.LockedTTL),
 						})
 						if !assert.NoError(t, err) {
-							return database.Template{}, err
+							return database.Template{}, schedule.TemplateScheduleChanges{}, err
 						}
 
-						return db.GetTemplateByID(ctx, template.ID)
+						template, err = db.GetTemplateByID(ctx, template.ID)
+						return template, schedule.TemplateScheduleChanges{}, err
 					},
 				},
 			})
@@ -305,7 +306,7 @@ func TestPostTemplateByOrganization(t *testing.T) {
 			var setCalled int64
 			client := coderdtest.New(t, &coderdtest.Options{
 				TemplateScheduleStore: schedule.MockTemplateScheduleStore{
-					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, error) {
+					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
 						atomic.AddInt64(&setCalled, 1)
 						assert.EqualValues(t, 0b00110000, options.RestartRequirement.DaysOfWeek)
 						assert.EqualValues(t, 2, options.RestartRequirement.Weeks)
@@ -324,10 +325,11 @@ func TestPostTemplateByOrganization(t *testing.T) {
 							LockedTTL:                    int64(options.LockedTTL),
 						})
 						if !assert.NoError(t, err) {
-							return database.Template{}, err
+							return database.Template{}, schedule.TemplateScheduleChanges{}, err
 						}
 
-						return db.GetTemplateByID(ctx, template.ID)
+						template, err = db.GetTemplateByID(ctx, template.ID)
+						return template, schedule.TemplateScheduleChanges{}, err
 					},
 				},
 			})
@@ -569,6 +571,84 @@ func TestPatchTemplateMeta(t *testing.T) {
 		assert.Equal(t, updated.DefaultTTLMillis, template.DefaultTTLMillis)
 	})
 
+	t.Run("ActivityBump", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.DefaultTTLMillis = ptr.Ref(8 * time.Hour.Milliseconds())
+			ctr.ActivityBumpMillis = ptr.Ref(time.Hour.Milliseconds())
+		})
+		require.Equal(t, time.Hour.Milliseconds(), template.ActivityBumpMillis)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DefaultTTLMillis:   template.DefaultTTLMillis,
+			ActivityBumpMillis: ptr.Ref[int64](-1),
+		})
+		require.ErrorContains(t, err, "activity_bump_ms: Must be a positive integer")
+
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DefaultTTLMillis:   template.DefaultTTLMillis,
+			ActivityBumpMillis: ptr.Ref((30 * time.Minute).Milliseconds()),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, (30 * time.Minute).Milliseconds(), updated.ActivityBumpMillis)
+		assert.Equal(t, template.DefaultTTLMillis, updated.DefaultTTLMillis)
+
+		// Updates that don't specify the activity bump keep it.
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DefaultTTLMillis: template.DefaultTTLMillis,
+			Description:      "keeps the activity bump",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, (30 * time.Minute).Milliseconds(), updated.ActivityBumpMillis)
+	})
+
+	t.Run("MaxConcurrentBuilds", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.Zero(t, template.MaxConcurrentBuilds)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DefaultTTLMillis:    template.DefaultTTLMillis,
+			MaxConcurrentBuilds: ptr.Ref[int32](-1),
+		})
+		require.ErrorContains(t, err, "max_concurrent_builds: Must be a positive integer")
+
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DefaultTTLMillis:    template.DefaultTTLMillis,
+			MaxConcurrentBuilds: ptr.Ref[int32](3),
+		})
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, updated.MaxConcurrentBuilds)
+
+		// The limit is kept if it isn't specified.
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DefaultTTLMillis: template.DefaultTTLMillis,
+			Description:      "New description",
+		})
+		require.NoError(t, err)
+		assert.EqualValues(t, 3, updated.MaxConcurrentBuilds)
+
+		updated, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DefaultTTLMillis:    template.DefaultTTLMillis,
+			Description:         "New description",
+			MaxConcurrentBuilds: ptr.Ref[int32](0),
+		})
+		require.NoError(t, err)
+		assert.Zero(t, updated.MaxConcurrentBuilds)
+	})
+
 	t.Run("MaxTTL", func(t *testing.T) {
 		t.Parallel()
 
@@ -583,7 +663,7 @@ func TestPatchTemplateMeta(t *testing.T) {
 			var setCalled int64
 			client := coderdtest.New(t, &coderdtest.Options{
 				TemplateScheduleStore: schedule.MockTemplateScheduleStore{
-					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, error) {
+					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
 						if atomic.AddInt64(&setCalled, 1) == 2 {
 							require.Equal(t, maxTTL, options.MaxTTL)
 						}
@@ -602,10 +682,11 @@ func TestPatchTemplateMeta(t *testing.T) {
 							LockedTTL:                    int64(options.LockedTTL),
 						})
 						if !assert.NoError(t, err) {
-							return database.Template{}, err
+							return database.Template{}, schedule.TemplateScheduleChanges{}, err
 						}
 
-						return db.GetTemplateByID(ctx, template.ID)
+						template, err = db.GetTemplateByID(ctx, template.ID)
+						return template, schedule.TemplateScheduleChanges{}, err
 					},
 				},
 			})
@@ -708,7 +789,7 @@ func TestPatchTemplateMeta(t *testing.T) {
 			var setCalled int64
 			client := coderdtest.New(t, &coderdtest.Options{
 				TemplateScheduleStore: schedule.MockTemplateScheduleStore{
-					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, error) {
+					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
 						if atomic.AddInt64(&setCalled, 1) == 2 {
 							require.Equal(t, failureTTL, options.FailureTTL)
 							require.Equal(t, inactivityTTL, options.InactivityTTL)
@@ -717,7 +798,7 @@ func TestPatchTemplateMeta(t *testing.T) {
 						template.FailureTTL = int64(options.FailureTTL)
 						template.InactivityTTL = int64(options.InactivityTTL)
 						template.LockedTTL = int64(options.LockedTTL)
-						return template, nil
+						return template, schedule.TemplateScheduleChanges{}, nil
 					},
 				},
 			})
@@ -801,7 +882,7 @@ func TestPatchTemplateMeta(t *testing.T) {
 			allowAutostop.Store(true)
 			client := coderdtest.New(t, &coderdtest.Options{
 				TemplateScheduleStore: schedule.MockTemplateScheduleStore{
-					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, error) {
+					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
 						atomic.AddInt64(&setCalled, 1)
 						assert.Equal(t, allowAutostart.Load(), options.UserAutostartEnabled)
 						assert.Equal(t, allowAutostop.Load(), options.UserAutostopEnabled)
@@ -809,7 +890,7 @@ func TestPatchTemplateMeta(t *testing.T) {
 						template.DefaultTTL = int64(options.DefaultTTL)
 						template.AllowUserAutostart = options.UserAutostartEnabled
 						template.AllowUserAutostop = options.UserAutostopEnabled
-						return template, nil
+						return template, schedule.TemplateScheduleChanges{}, nil
 					},
 				},
 			})
@@ -943,6 +1024,65 @@ func TestPatchTemplateMeta(t *testing.T) {
 		assert.Equal(t, template.DefaultTTLMillis, updated.DefaultTTLMillis)
 	})
 
+	t.Run("DryRun", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			setCalled int64
+			change    = schedule.WorkspaceBuildDeadlineChange{
+				WorkspaceID:      uuid.New(),
+				WorkspaceName:    "dev",
+				OwnerID:          uuid.New(),
+				WorkspaceBuildID: uuid.New(),
+				Deadline:         time.Now().Add(time.Hour).UTC(),
+				MaxDeadline:      time.Now().Add(48 * time.Hour).UTC(),
+				NewDeadline:      time.Now().Add(time.Hour).UTC(),
+				NewMaxDeadline:   time.Now().Add(24 * time.Hour).UTC(),
+			}
+		)
+		client := coderdtest.New(t, &coderdtest.Options{
+			TemplateScheduleStore: schedule.MockTemplateScheduleStore{
+				SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
+					atomic.AddInt64(&setCalled, 1)
+					return template, schedule.TemplateScheduleChanges{}, nil
+				},
+				DryRunFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) ([]schedule.WorkspaceBuildDeadlineChange, error) {
+					assert.Equal(t, 24*time.Hour, options.MaxTTL)
+					return []schedule.WorkspaceBuildDeadlineChange{change}, nil
+				},
+			},
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		setCalledBefore := atomic.LoadInt64(&setCalled)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		dryRun, err := client.UpdateTemplateMetaDryRun(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:         "new-name",
+			MaxTTLMillis: (24 * time.Hour).Milliseconds(),
+		})
+		require.NoError(t, err)
+		require.Len(t, dryRun.Workspaces, 1)
+		got := dryRun.Workspaces[0]
+		assert.Equal(t, change.WorkspaceID, got.WorkspaceID)
+		assert.Equal(t, change.WorkspaceName, got.WorkspaceName)
+		assert.Equal(t, change.OwnerID, got.OwnerID)
+		assert.Equal(t, change.WorkspaceBuildID, got.WorkspaceBuildID)
+		assert.WithinDuration(t, change.Deadline, got.Deadline.Time, time.Second)
+		assert.WithinDuration(t, change.MaxDeadline, got.MaxDeadline.Time, time.Second)
+		assert.WithinDuration(t, change.NewDeadline, got.NewDeadline.Time, time.Second)
+		assert.WithinDuration(t, change.NewMaxDeadline, got.NewMaxDeadline.Time, time.Second)
+
+		// The template is not updated.
+		require.Equal(t, setCalledBefore, atomic.LoadInt64(&setCalled))
+		updated, err := client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		assert.Equal(t, template.Name, updated.Name)
+		assert.Equal(t, template.UpdatedAt, updated.UpdatedAt)
+	})
+
 	t.Run("RemoveIcon", func(t *testing.T) {
 		t.Parallel()
 
@@ -973,7 +1113,7 @@ func TestPatchTemplateMeta(t *testing.T) {
 			var setCalled int64
 			client := coderdtest.New(t, &coderdtest.Options{
 				TemplateScheduleStore: schedule.MockTemplateScheduleStore{
-					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, error) {
+					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
 						if atomic.AddInt64(&setCalled, 1) == 2 {
 							assert.EqualValues(t, 0b0110000, options.RestartRequirement.DaysOfWeek)
 							assert.EqualValues(t, 2, options.RestartRequirement.Weeks)
@@ -993,10 +1133,11 @@ func TestPatchTemplateMeta(t *testing.T) {
 							LockedTTL:                    int64(options.LockedTTL),
 						})
 						if !assert.NoError(t, err) {
-							return database.Template{}, err
+							return database.Template{}, schedule.TemplateScheduleChanges{}, err
 						}
 
-						return db.GetTemplateByID(ctx, template.ID)
+						template, err = db.GetTemplateByID(ctx, template.ID)
+						return template, schedule.TemplateScheduleChanges{}, err
 					},
 				},
 			})
@@ -1042,7 +1183,7 @@ func TestPatchTemplateMeta(t *testing.T) {
 			var setCalled int64
 			client := coderdtest.New(t, &coderdtest.Options{
 				TemplateScheduleStore: schedule.MockTemplateScheduleStore{
-					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, error) {
+					SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
 						if atomic.AddInt64(&setCalled, 1) == 2 {
 							assert.EqualValues(t, 0, options.RestartRequirement.DaysOfWeek)
 							assert.EqualValues(t, 0, options.RestartRequirement.Weeks)
@@ -1062,10 +1203,11 @@ func TestPatchTemplateMeta(t *testing.T) {
 							LockedTTL:                    int64(options.LockedTTL),
 						})
 						if !assert.NoError(t, err) {
-							return database.Template{}, err
+							return database.Template{}, schedule.TemplateScheduleChanges{}, err
 						}
 
-						return db.GetTemplateByID(ctx, template.ID)
+						template, err = db.GetTemplateByID(ctx, template.ID)
+						return template, schedule.TemplateScheduleChanges{}, err
 					},
 				},
 			})
@@ -0,0 +1,423 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get template version promotions by template ID
+// @ID get-template-version-promotions-by-template-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param status query string false "Filter by status" Enums(pending,promoted,rejected)
+// @Success 200 {array} codersdk.TemplateVersionPromotion
+// @Router /templates/{template}/promotions [get]
+func (api *API) templateVersionPromotions(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+		status   = r.URL.Query().Get("status")
+	)
+
+	if status != "" && !database.TemplateVersionPromotionStatus(status).Valid() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid query parameters.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "status",
+				Detail: fmt.Sprintf("Status %q is not one of %v.", status, database.AllTemplateVersionPromotionStatusValues()),
+			}},
+		})
+		return
+	}
+
+	promotions, err := api.Database.GetTemplateVersionPromotionsByTemplateID(ctx, database.GetTemplateVersionPromotionsByTemplateIDParams{
+		TemplateID: template.ID,
+		Status:     status,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version promotions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiPromotions := make([]codersdk.TemplateVersionPromotion, 0, len(promotions))
+	for _, promotion := range promotions {
+		apiPromotions = append(apiPromotions, convertTemplateVersionPromotion(template, promotion))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, apiPromotions)
+}
+
+// @Summary Get template version promotion by ID
+// @ID get-template-version-promotion-by-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param promotion path string true "Promotion ID" format(uuid)
+// @Success 200 {object} codersdk.TemplateVersionPromotion
+// @Router /templates/{template}/promotions/{promotion} [get]
+func (api *API) templateVersionPromotion(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	promotion, ok := api.templateVersionPromotionParam(rw, r, template)
+	if !ok {
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersionPromotion(template, promotion))
+}
+
+// @Summary Create template version promotion
+// @Description If the template doesn't require promotion approvals, the
+// @Description template version is made active immediately.
+// @ID create-template-version-promotion
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.CreateTemplateVersionPromotionRequest true "Create template version promotion request"
+// @Success 201 {object} codersdk.TemplateVersionPromotion
+// @Router /templates/{template}/promotions [post]
+func (api *API) postTemplateVersionPromotion(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		template          = httpmw.TemplateParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.TemplateVersionPromotion](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	defer commitAudit()
+
+	var req codersdk.CreateTemplateVersionPromotionRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	version, err := api.Database.GetTemplateVersionByID(ctx, req.TemplateVersionID)
+	if httpapi.Is404Error(err) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "Template version not found.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if version.TemplateID.UUID != template.ID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The provided template version doesn't belong to the specified template.",
+		})
+		return
+	}
+	if version.ID == template.ActiveVersionID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The provided template version is already the active version.",
+		})
+		return
+	}
+
+	now := database.Now()
+	promotion, err := api.Database.InsertTemplateVersionPromotion(ctx, database.InsertTemplateVersionPromotionParams{
+		ID:                uuid.New(),
+		TemplateID:        template.ID,
+		TemplateVersionID: version.ID,
+		RequestedBy:       apiKey.UserID,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		Status:            database.TemplateVersionPromotionStatusPending,
+	})
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "A promotion of this template version is already pending.",
+		})
+		return
+	}
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating template version promotion.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// Templates without required approvals promote versions right away, so
+	// the promotions API works the same for every template.
+	if template.RequiredPromotionApprovals == 0 {
+		promotion, err = api.promoteTemplateVersion(ctx, template, promotion)
+		if errors.Is(err, errPromotionResolved) {
+			httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+				Message: "The promotion was resolved by another request.",
+			})
+			return
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error promoting template version.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+	aReq.New = promotion
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertTemplateVersionPromotion(template, promotion))
+}
+
+// @Summary Approve template version promotion
+// @Description The template version is made active once the promotion has
+// @Description the number of approvals the template requires. Users can't
+// @Description approve their own promotions.
+// @ID approve-template-version-promotion
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param promotion path string true "Promotion ID" format(uuid)
+// @Success 200 {object} codersdk.TemplateVersionPromotion
+// @Router /templates/{template}/promotions/{promotion}/approve [post]
+func (api *API) postApproveTemplateVersionPromotion(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		template          = httpmw.TemplateParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.TemplateVersionPromotion](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+
+	promotion, ok := api.templateVersionPromotionParam(rw, r, template)
+	if !ok {
+		return
+	}
+	aReq.Old = promotion
+
+	if promotion.RequestedBy == apiKey.UserID {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "You can't approve your own template version promotion.",
+		})
+		return
+	}
+
+	promotion, err := api.Database.ApproveTemplateVersionPromotion(ctx, database.ApproveTemplateVersionPromotionParams{
+		ID:        promotion.ID,
+		UserID:    apiKey.UserID,
+		UpdatedAt: database.Now(),
+	})
+	if errors.Is(err, sql.ErrNoRows) || database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "The promotion isn't pending or you already approved it.",
+		})
+		return
+	}
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error approving template version promotion.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	if int32(len(promotion.ApprovedBy)) >= template.RequiredPromotionApprovals {
+		promotion, err = api.promoteTemplateVersion(ctx, template, promotion)
+		// Concurrent approvals may both reach the required number of
+		// approvals, only the first one promotes the version.
+		if errors.Is(err, errPromotionResolved) || database.IsUniqueViolation(err) {
+			httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+				Message: "The promotion was resolved by another request.",
+			})
+			return
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error promoting template version.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+	aReq.New = promotion
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersionPromotion(template, promotion))
+}
+
+// @Summary Reject template version promotion
+// @ID reject-template-version-promotion
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param promotion path string true "Promotion ID" format(uuid)
+// @Success 200 {object} codersdk.TemplateVersionPromotion
+// @Router /templates/{template}/promotions/{promotion}/reject [post]
+func (api *API) postRejectTemplateVersionPromotion(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		template          = httpmw.TemplateParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.TemplateVersionPromotion](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+
+	promotion, ok := api.templateVersionPromotionParam(rw, r, template)
+	if !ok {
+		return
+	}
+	aReq.Old = promotion
+
+	promotion, err := api.Database.UpdateTemplateVersionPromotionStatusByID(ctx, database.UpdateTemplateVersionPromotionStatusByIDParams{
+		ID:        promotion.ID,
+		UpdatedAt: database.Now(),
+		Status:    database.TemplateVersionPromotionStatusRejected,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "The promotion isn't pending.",
+		})
+		return
+	}
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error rejecting template version promotion.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = promotion
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersionPromotion(template, promotion))
+}
+
+// templateVersionPromotionParam fetches the promotion in the URL and makes
+// sure it belongs to the template. It writes an error response if not.
+func (api *API) templateVersionPromotionParam(rw http.ResponseWriter, r *http.Request, template database.Template) (database.TemplateVersionPromotion, bool) {
+	ctx := r.Context()
+
+	promotionID, ok := httpmw.ParseUUIDParam(rw, r, "promotion")
+	if !ok {
+		return database.TemplateVersionPromotion{}, false
+	}
+	promotion, err := api.Database.GetTemplateVersionPromotionByID(ctx, promotionID)
+	if httpapi.Is404Error(err) || (err == nil && promotion.TemplateID != template.ID) {
+		httpapi.ResourceNotFound(rw)
+		return database.TemplateVersionPromotion{}, false
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version promotion.",
+			Detail:  err.Error(),
+		})
+		return database.TemplateVersionPromotion{}, false
+	}
+	return promotion, true
+}
+
+// errPromotionResolved is returned by promoteTemplateVersion if the promotion
+// was promoted or rejected by another request.
+var errPromotionResolved = xerrors.New("promotion is not pending")
+
+// promoteTemplateVersion marks the promotion as promoted and makes its
+// template version the active version of the template. The status is only
+// changed if the promotion is still pending, so concurrent requests can't both
+// promote it, or promote a rejected promotion.
+func (api *API) promoteTemplateVersion(ctx context.Context, template database.Template, promotion database.TemplateVersionPromotion) (database.TemplateVersionPromotion, error) {
+	err := api.Database.InTx(func(tx database.Store) error {
+		now := database.Now()
+		var err error
+		promotion, err = tx.UpdateTemplateVersionPromotionStatusByID(ctx, database.UpdateTemplateVersionPromotionStatusByIDParams{
+			ID:        promotion.ID,
+			UpdatedAt: now,
+			Status:    database.TemplateVersionPromotionStatusPromoted,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errPromotionResolved
+		}
+		if err != nil {
+			return xerrors.Errorf("update promotion status: %w", err)
+		}
+		err = tx.UpdateTemplateActiveVersionByID(ctx, database.UpdateTemplateActiveVersionByIDParams{
+			ID:              template.ID,
+			ActiveVersionID: promotion.TemplateVersionID,
+			UpdatedAt:       now,
+		})
+		if err != nil {
+			return xerrors.Errorf("update active version: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return database.TemplateVersionPromotion{}, err
+	}
+
+	api.publishTemplateUpdate(ctx, template.ID)
+	return promotion, nil
+}
+
+func convertTemplateVersionPromotion(template database.Template, promotion database.TemplateVersionPromotion) codersdk.TemplateVersionPromotion {
+	approvedBy := promotion.ApprovedBy
+	if approvedBy == nil {
+		approvedBy = []uuid.UUID{}
+	}
+	return codersdk.TemplateVersionPromotion{
+		ID:                promotion.ID,
+		TemplateID:        promotion.TemplateID,
+		TemplateVersionID: promotion.TemplateVersionID,
+		RequestedBy:       promotion.RequestedBy,
+		ApprovedBy:        approvedBy,
+		RequiredApprovals: template.RequiredPromotionApprovals,
+		Status:            codersdk.TemplateVersionPromotionStatus(promotion.Status),
+		CreatedAt:         promotion.CreatedAt,
+		UpdatedAt:         promotion.UpdatedAt,
+	}
+}
@@ -0,0 +1,203 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateVersionPromotions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NoApprovalsRequired", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		version = coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		promotion, err := client.CreateTemplateVersionPromotion(ctx, template.ID, codersdk.CreateTemplateVersionPromotionRequest{
+			TemplateVersionID: version.ID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionPromotionStatusPromoted, promotion.Status)
+		require.Empty(t, promotion.ApprovedBy)
+
+		template, err = client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, version.ID, template.ActiveVersionID)
+	})
+
+	t.Run("Approve", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
+		user := coderdtest.CreateFirstUser(t, client)
+		approverClient1, approver1 := coderdtest.CreateAnotherUser(t, client, user.OrganizationID, rbac.RoleTemplateAdmin())
+		approverClient2, approver2 := coderdtest.CreateAnotherUser(t, client, user.OrganizationID, rbac.RoleTemplateAdmin())
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		activeVersionID := template.ActiveVersionID
+		version = coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		template, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:                       template.Name,
+			RequiredPromotionApprovals: ptr.Ref(int32(2)),
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 2, template.RequiredPromotionApprovals)
+
+		// The active version can't be changed directly anymore.
+		err = client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: version.ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		promotion, err := client.CreateTemplateVersionPromotion(ctx, template.ID, codersdk.CreateTemplateVersionPromotionRequest{
+			TemplateVersionID: version.ID,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionPromotionStatusPending, promotion.Status)
+		require.EqualValues(t, 2, promotion.RequiredApprovals)
+		require.Equal(t, database.AuditActionCreate, auditor.AuditLogs()[len(auditor.AuditLogs())-1].Action)
+
+		pending, err := approverClient1.TemplateVersionPromotions(ctx, template.ID, codersdk.TemplateVersionPromotionStatusPending)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		require.Equal(t, promotion.ID, pending[0].ID)
+
+		// Users can't approve their own promotions.
+		_, err = client.ApproveTemplateVersionPromotion(ctx, template.ID, promotion.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		promotion, err = approverClient1.ApproveTemplateVersionPromotion(ctx, template.ID, promotion.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionPromotionStatusPending, promotion.Status)
+		require.Equal(t, []uuid.UUID{approver1.ID}, promotion.ApprovedBy)
+
+		// Approving twice doesn't count twice.
+		_, err = approverClient1.ApproveTemplateVersionPromotion(ctx, template.ID, promotion.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		template, err = client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, activeVersionID, template.ActiveVersionID)
+
+		promotion, err = approverClient2.ApproveTemplateVersionPromotion(ctx, template.ID, promotion.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionPromotionStatusPromoted, promotion.Status)
+		require.Equal(t, []uuid.UUID{approver1.ID, approver2.ID}, promotion.ApprovedBy)
+		require.Equal(t, database.AuditActionWrite, auditor.AuditLogs()[len(auditor.AuditLogs())-1].Action)
+
+		template, err = client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, version.ID, template.ActiveVersionID)
+
+		pending, err = client.TemplateVersionPromotions(ctx, template.ID, codersdk.TemplateVersionPromotionStatusPending)
+		require.NoError(t, err)
+		require.Empty(t, pending)
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		activeVersionID := template.ActiveVersionID
+		version = coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:                       template.Name,
+			RequiredPromotionApprovals: ptr.Ref(int32(1)),
+		})
+		require.NoError(t, err)
+
+		promotion, err := client.CreateTemplateVersionPromotion(ctx, template.ID, codersdk.CreateTemplateVersionPromotionRequest{
+			TemplateVersionID: version.ID,
+		})
+		require.NoError(t, err)
+
+		// Only one promotion of a version can be pending.
+		_, err = client.CreateTemplateVersionPromotion(ctx, template.ID, codersdk.CreateTemplateVersionPromotionRequest{
+			TemplateVersionID: version.ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		promotion, err = client.RejectTemplateVersionPromotion(ctx, template.ID, promotion.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TemplateVersionPromotionStatusRejected, promotion.Status)
+
+		_, err = client.RejectTemplateVersionPromotion(ctx, template.ID, promotion.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		got, err := client.TemplateVersionPromotion(ctx, template.ID, promotion.ID)
+		require.NoError(t, err)
+		require.Equal(t, promotion, got)
+
+		template, err = client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, activeVersionID, template.ActiveVersionID)
+	})
+
+	t.Run("MemberForbidden", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		version = coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := memberClient.CreateTemplateVersionPromotion(ctx, template.ID, codersdk.CreateTemplateVersionPromotionRequest{
+			TemplateVersionID: version.ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("DoesNotBelong", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		version = coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.CreateTemplateVersionPromotion(ctx, template.ID, codersdk.CreateTemplateVersionPromotionRequest{
+			TemplateVersionID: version.ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
@@ -0,0 +1,157 @@
+package coderd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/templateregistry"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Push template version to registry
+// @ID push-template-version-to-registry
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param templateversion path string true "Template version ID" format(uuid)
+// @Param request body codersdk.PushTemplateVersionRequest true "Push template version request"
+// @Success 200 {object} codersdk.PushTemplateVersionResponse
+// @Router /templateversions/{templateversion}/push [post]
+func (api *API) postTemplateVersionPush(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	templateVersion := httpmw.TemplateVersionParam(r)
+
+	var req codersdk.PushTemplateVersionRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	// Pushing publishes the source of the version outside of the deployment,
+	// so it requires the same permission as changing the template.
+	object := templateVersion.RBACObjectNoTemplate()
+	if templateVersion.TemplateID.Valid {
+		template, err := api.Database.GetTemplateByID(ctx, templateVersion.TemplateID.UUID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching template.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		object = templateVersion.RBACObject(template)
+	}
+	if !api.Authorize(r, rbac.ActionUpdate, object) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	ref, err := templateregistry.ParseReference(req.Reference)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid registry reference.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "reference",
+				Detail: err.Error(),
+			}},
+		})
+		return
+	}
+	if ref.Digest != "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Registry references must have a tag to push to, not a digest.",
+		})
+		return
+	}
+	if !api.templateRegistryAllowed(ref.Registry) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Registry %q is not allowed by the deployment.", ref.Registry),
+		})
+		return
+	}
+
+	job, err := api.Database.GetProvisionerJobByID(ctx, templateVersion.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	file, err := api.Database.GetFileByID(ctx, job.FileID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching file.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if file.Mimetype != tarMimeType {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Only template versions uploaded as tar archives can be pushed.",
+		})
+		return
+	}
+
+	digest, err := api.templateRegistryClient(req.Auth).Push(ctx, ref, file.Data)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadGateway, codersdk.Response{
+			Message: "Failed to push template to registry.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.PushTemplateVersionResponse{
+		Reference: ref.String(),
+		Digest:    digest,
+	})
+}
+
+// pullTemplateFromRegistry returns the tar archive of a template in a
+// registry.
+func (api *API) pullTemplateFromRegistry(ctx context.Context, reference string, auth *codersdk.TemplateRegistryAuth) ([]byte, error) {
+	ref, err := templateregistry.ParseReference(reference)
+	if err != nil {
+		return nil, err
+	}
+	tar, err := api.templateRegistryClient(auth).Pull(ctx, ref)
+	if err != nil {
+		return nil, xerrors.Errorf("pull %s: %w", ref, err)
+	}
+	return tar, nil
+}
+
+// templateRegistryAllowed returns whether the deployment allows pushing to and
+// pulling from a registry.
+func (api *API) templateRegistryAllowed(host string) bool {
+	for _, registry := range api.TemplateRegistries {
+		if strings.EqualFold(registry.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (api *API) templateRegistryClient(auth *codersdk.TemplateRegistryAuth) *templateregistry.Client {
+	client := &templateregistry.Client{
+		HTTPClient: api.HTTPClient,
+		Registries: api.TemplateRegistries,
+	}
+	if auth != nil {
+		client.Username = auth.Username
+		client.Password = auth.Password
+	}
+	return client
+}
@@ -0,0 +1,138 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/templateregistry/templateregistrytest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestPostTemplateVersionPush(t *testing.T) {
+	t.Parallel()
+
+	t.Run("PushAndPull", func(t *testing.T) {
+		t.Parallel()
+		registry := templateregistrytest.New(t, &templateregistrytest.Options{
+			Username: "coder",
+			Password: "hunter2",
+		})
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+			TemplateRegistries:       registry.Registries(),
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		auth := &codersdk.TemplateRegistryAuth{Username: "coder", Password: "hunter2"}
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		pushed, err := client.PushTemplateVersion(ctx, version.ID, codersdk.PushTemplateVersionRequest{
+			Reference: "oci://" + registry.Host + "/coder/docker:v1",
+			Auth:      auth,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "oci://"+registry.Host+"/coder/docker:v1", pushed.Reference)
+		require.NotEmpty(t, pushed.Digest)
+
+		pulled, err := client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod:     codersdk.ProvisionerStorageMethodFile,
+			RegistryReference: pushed.Reference + "@" + pushed.Digest,
+			RegistryAuth:      auth,
+			Provisioner:       codersdk.ProvisionerTypeEcho,
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitTemplateVersionJob(t, client, pulled.ID)
+
+		// The pulled version has the same source as the pushed one.
+		expected, _, err := client.Download(ctx, version.Job.FileID)
+		require.NoError(t, err)
+		actual, _, err := client.Download(ctx, pulled.Job.FileID)
+		require.NoError(t, err)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		t.Parallel()
+		registry := templateregistrytest.New(t, &templateregistrytest.Options{
+			Username: "coder",
+			Password: "hunter2",
+		})
+		client := coderdtest.New(t, &coderdtest.Options{
+			TemplateRegistries: registry.Registries(),
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.PushTemplateVersion(ctx, version.ID, codersdk.PushTemplateVersionRequest{
+			Reference: "oci://" + registry.Host + "/coder/docker:v1",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadGateway, apiErr.StatusCode())
+	})
+
+	t.Run("NotAllowed", func(t *testing.T) {
+		t.Parallel()
+		registry := templateregistrytest.New(t, nil)
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.PushTemplateVersion(ctx, version.ID, codersdk.PushTemplateVersionRequest{
+			Reference: "oci://" + registry.Host + "/coder/docker:v1",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		_, err = client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod:     codersdk.ProvisionerStorageMethodFile,
+			RegistryReference: "oci://" + registry.Host + "/coder/docker:v1",
+			Provisioner:       codersdk.ProvisionerTypeEcho,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Equal(t, "Registry is not allowed by the deployment.", apiErr.Message)
+	})
+
+	t.Run("InvalidReference", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.PushTemplateVersion(ctx, version.ID, codersdk.PushTemplateVersionRequest{
+			Reference: "oci://ghcr.io",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		registry := templateregistrytest.New(t, nil)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := member.PushTemplateVersion(ctx, template.ActiveVersionID, codersdk.PushTemplateVersionRequest{
+			Reference: "oci://" + registry.Host + "/coder/docker:v1",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
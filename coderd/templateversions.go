@@ -26,6 +26,7 @@ import (
 	"github.com/coder/coder/coderd/parameter"
 	"github.com/coder/coder/coderd/provisionerdserver"
 	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/templateregistry"
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/examples"
@@ -396,6 +397,38 @@ func (api *API) templateVersionVariables(rw http.ResponseWriter, r *http.Request
 	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersionVariables(dbTemplateVersionVariables))
 }
 
+// @Summary Get template version presets
+// @ID get-template-version-presets
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param templateversion path string true "Template version ID" format(uuid)
+// @Success 200 {array} codersdk.TemplateVersionPreset
+// @Router /templateversions/{templateversion}/presets [get]
+func (api *API) templateVersionPresets(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	templateVersion := httpmw.TemplateVersionParam(r)
+
+	dbPresets, err := api.Database.GetTemplateVersionPresets(ctx, templateVersion.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version presets.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	presets, err := convertTemplateVersionPresets(dbPresets)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error converting template version presets.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, presets)
+}
+
 // @Summary Create template version dry-run
 // @ID create-template-version-dry-run
 // @Security CoderSessionToken
@@ -982,6 +1015,10 @@ func (api *API) previousTemplateVersionByOrganizationTemplateAndName(rw http.Res
 	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersion(previousTemplateVersion, convertProvisionerJob(jobs[0]), nil))
 }
 
+// errPromotionRequired is returned from the transaction that changes the active
+// version if the template requires approvals for it.
+var errPromotionRequired = xerrors.New("template requires promotion approvals")
+
 // @Summary Update active template version by template ID
 // @ID update-active-template-version-by-template-id
 // @Security CoderSessionToken
@@ -1011,6 +1048,13 @@ func (api *API) patchActiveTemplateVersion(rw http.ResponseWriter, r *http.Reque
 	if !httpapi.Read(ctx, rw, r, &req) {
 		return
 	}
+	if template.RequiredPromotionApprovals > 0 {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "The template requires approvals to change the active version.",
+			Detail:  fmt.Sprintf("Create a promotion with POST /api/v2/templates/%s/promotions instead.", template.ID),
+		})
+		return
+	}
 	version, err := api.Database.GetTemplateVersionByID(ctx, req.ID)
 	if httpapi.Is404Error(err) {
 		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
@@ -1041,8 +1085,24 @@ func (api *API) patchActiveTemplateVersion(rw http.ResponseWriter, r *http.Reque
 		if err != nil {
 			return xerrors.Errorf("update active version: %w", err)
 		}
+		// The update locks the template, so approvals can't be required
+		// concurrently without this seeing them.
+		updated, err := store.GetTemplateByID(ctx, template.ID)
+		if err != nil {
+			return xerrors.Errorf("get template: %w", err)
+		}
+		if updated.RequiredPromotionApprovals > 0 {
+			return errPromotionRequired
+		}
 		return nil
 	}, nil)
+	if errors.Is(err, errPromotionRequired) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "The template requires approvals to change the active version.",
+			Detail:  fmt.Sprintf("Create a promotion with POST /api/v2/templates/%s/promotions instead.", template.ID),
+		})
+		return
+	}
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Internal error updating active template version.",
@@ -1111,20 +1171,35 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 		}
 	}
 
+	if validations := validateTemplateVersionPresets(req.Presets); len(validations) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid template version presets.",
+			Validations: validations,
+		})
+		return
+	}
+
 	// Ensures the "owner" is properly applied.
 	tags := provisionerdserver.MutateTags(apiKey.UserID, req.ProvisionerTags)
 
-	if req.ExampleID != "" && req.FileID != uuid.Nil {
+	sources := 0
+	for _, set := range []bool{req.ExampleID != "", req.FileID != uuid.Nil, req.RegistryReference != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-			Message: "You cannot specify both an example_id and a file_id.",
+			Message: "You can only specify one of example_id, file_id, and registry_reference.",
 		})
 		return
 	}
 
 	var file database.File
 	var err error
-	// if example id is specified we need to copy the embedded tar into a new file in the database
-	if req.ExampleID != "" {
+	// if example id or registry reference is specified we need to copy the tar
+	// into a new file in the database
+	if req.ExampleID != "" || req.RegistryReference != "" {
 		if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceFile.WithOwner(apiKey.UserID.String())) {
 			httpapi.Forbidden(rw)
 			return
@@ -1135,21 +1210,40 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 			return
 		}
 
-		// lookup template tar from embedded examples
-		tar, err := examples.Archive(req.ExampleID)
-		if err != nil {
-			if xerrors.Is(err, examples.ErrNotFound) {
+		var tar []byte
+		if req.ExampleID != "" {
+			// lookup template tar from embedded examples
+			tar, err = examples.Archive(req.ExampleID)
+			if err != nil {
+				if xerrors.Is(err, examples.ErrNotFound) {
+					httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+						Message: "Example not found.",
+						Detail:  err.Error(),
+					})
+					return
+				}
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching example.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+		} else {
+			tar, err = api.pullTemplateFromRegistry(ctx, req.RegistryReference, req.RegistryAuth)
+			if xerrors.Is(err, templateregistry.ErrRegistryNotAllowed) {
 				httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-					Message: "Example not found.",
+					Message: "Registry is not allowed by the deployment.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+					Message: "Failed to pull template from registry.",
 					Detail:  err.Error(),
 				})
 				return
 			}
-			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-				Message: "Internal error fetching example.",
-				Detail:  err.Error(),
-			})
-			return
 		}
 
 		// upload a copy of the template tar as a file in the database
@@ -1169,7 +1263,7 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 				return
 			}
 
-			// If the example tar file doesn't exist, create it.
+			// If the tar file doesn't exist, create it.
 			file, err = api.Database.InsertFile(ctx, database.InsertFileParams{
 				ID:        uuid.New(),
 				Hash:      hash,
@@ -1274,6 +1368,27 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 			return xerrors.Errorf("insert template version: %w", err)
 		}
 
+		for _, preset := range req.Presets {
+			parameters := preset.Parameters
+			if parameters == nil {
+				parameters = []codersdk.WorkspaceBuildParameter{}
+			}
+			parametersRaw, err := json.Marshal(parameters)
+			if err != nil {
+				return xerrors.Errorf("marshal parameters of preset %q: %w", preset.Name, err)
+			}
+			_, err = tx.InsertTemplateVersionPreset(ctx, database.InsertTemplateVersionPresetParams{
+				TemplateVersionID: templateVersionID,
+				Name:              preset.Name,
+				Description:       preset.Description,
+				Parameters:        parametersRaw,
+				CreatedAt:         database.Now(),
+			})
+			if err != nil {
+				return xerrors.Errorf("insert preset %q: %w", preset.Name, err)
+			}
+		}
+
 		templateVersion, err = tx.GetTemplateVersionByID(ctx, templateVersionID)
 		if err != nil {
 			return xerrors.Errorf("fetched inserted template version: %w", err)
@@ -1450,6 +1565,70 @@ func convertTemplateVersionVariables(dbVariables []database.TemplateVersionVaria
 
 const redacted = "*redacted*"
 
+func convertTemplateVersionPresets(dbPresets []database.TemplateVersionPreset) ([]codersdk.TemplateVersionPreset, error) {
+	presets := make([]codersdk.TemplateVersionPreset, 0, len(dbPresets))
+	for _, dbPreset := range dbPresets {
+		parameters := []codersdk.WorkspaceBuildParameter{}
+		err := json.Unmarshal(dbPreset.Parameters, &parameters)
+		if err != nil {
+			return nil, xerrors.Errorf("unmarshal parameters of preset %q: %w", dbPreset.Name, err)
+		}
+		presets = append(presets, codersdk.TemplateVersionPreset{
+			Name:        dbPreset.Name,
+			Description: dbPreset.Description,
+			Parameters:  parameters,
+		})
+	}
+	return presets, nil
+}
+
+// validateTemplateVersionPresets returns a validation error for every preset
+// without a unique name and every parameter that is set more than once.
+func validateTemplateVersionPresets(presets []codersdk.TemplateVersionPreset) []codersdk.ValidationError {
+	var validations []codersdk.ValidationError
+	names := make(map[string]struct{}, len(presets))
+	for i, preset := range presets {
+		field := fmt.Sprintf("presets[%d]", i)
+		if preset.Name == "" {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".name",
+				Detail: "Must not be empty.",
+			})
+		} else if len(preset.Name) > 64 {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".name",
+				Detail: "Must be at most 64 characters.",
+			})
+		} else if _, ok := names[preset.Name]; ok {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".name",
+				Detail: fmt.Sprintf("Preset %q is defined more than once.", preset.Name),
+			})
+		}
+		names[preset.Name] = struct{}{}
+
+		parameters := make(map[string]struct{}, len(preset.Parameters))
+		for j, parameter := range preset.Parameters {
+			parameterField := fmt.Sprintf("%s.parameters[%d].name", field, j)
+			if parameter.Name == "" {
+				validations = append(validations, codersdk.ValidationError{
+					Field:  parameterField,
+					Detail: "Must not be empty.",
+				})
+				continue
+			}
+			if _, ok := parameters[parameter.Name]; ok {
+				validations = append(validations, codersdk.ValidationError{
+					Field:  parameterField,
+					Detail: fmt.Sprintf("Parameter %q is set more than once.", parameter.Name),
+				})
+			}
+			parameters[parameter.Name] = struct{}{}
+		}
+	}
+	return validations
+}
+
 func convertTemplateVersionVariable(variable database.TemplateVersionVariable) codersdk.TemplateVersionVariable {
 	templateVariable := codersdk.TemplateVersionVariable{
 		Name:         variable.Name,
@@ -129,6 +129,35 @@ func TestPostTemplateVersionsByOrganization(t *testing.T) {
 		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
 	})
 
+	t.Run("ProvisionerPlugin", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		file, err := client.Upload(ctx, codersdk.ContentTypeTar, bytes.NewReader(make([]byte, 1024)))
+		require.NoError(t, err)
+		// Jobs of provisioner plugins wait for a daemon that serves them.
+		version, err := client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			FileID:        file.ID,
+			Provisioner:   "pulumi",
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.ProvisionerJobPending, version.Job.Status)
+
+		_, err = client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			FileID:        file.ID,
+			Provisioner:   "Not A Plugin",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
 	t.Run("WithParameters", func(t *testing.T) {
 		t.Parallel()
 		auditor := audit.NewMock()
@@ -1201,6 +1230,73 @@ func TestTemplateVersionVariables(t *testing.T) {
 	})
 }
 
+func TestTemplateVersionPresets(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Create", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		presets := []codersdk.TemplateVersionPreset{{
+			Name:        "small",
+			Description: "Two CPUs for web development",
+			Parameters:  []codersdk.WorkspaceBuildParameter{{Name: "cpu", Value: "2"}},
+		}, {
+			Name:       "GPU",
+			Parameters: []codersdk.WorkspaceBuildParameter{{Name: "cpu", Value: "16"}, {Name: "gpu", Value: "true"}},
+		}}
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil, func(req *codersdk.CreateTemplateVersionRequest) {
+			req.Presets = presets
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		actualPresets, err := client.TemplateVersionPresets(ctx, version.ID)
+		require.NoError(t, err)
+		// Presets are sorted by name.
+		require.Equal(t, []codersdk.TemplateVersionPreset{presets[1], presets[0]}, actualPresets)
+	})
+
+	t.Run("None", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		presets, err := client.TemplateVersionPresets(ctx, version.ID)
+		require.NoError(t, err)
+		require.NotNil(t, presets)
+		require.Empty(t, presets)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		file, err := client.Upload(ctx, codersdk.ContentTypeTar, bytes.NewReader(make([]byte, 1024)))
+		require.NoError(t, err)
+		_, err = client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			FileID:        file.ID,
+			Provisioner:   codersdk.ProvisionerTypeEcho,
+			Presets: []codersdk.TemplateVersionPreset{{
+				Name:       "small",
+				Parameters: []codersdk.WorkspaceBuildParameter{{Name: "cpu", Value: "2"}, {Name: "cpu", Value: "4"}},
+			}, {
+				Name: "small",
+			}},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 2)
+		require.Equal(t, "presets[0].parameters[1].name", apiErr.Validations[0].Field)
+		require.Equal(t, "presets[1].name", apiErr.Validations[1].Field)
+	})
+}
+
 func TestTemplateVersionPatch(t *testing.T) {
 	t.Parallel()
 	t.Run("Update the name", func(t *testing.T) {
@@ -0,0 +1,267 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/apikey"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/cryptorand"
+)
+
+// TokenExchangePolicy is a codersdk.TokenExchangePolicy with the verifier of
+// the identity tokens of its issuer.
+type TokenExchangePolicy struct {
+	codersdk.TokenExchangePolicy
+	Verifier *oidc.IDTokenVerifier
+}
+
+// matchClaims returns an error that names the first claim that doesn't match
+// the policy.
+func (p TokenExchangePolicy) matchClaims(claims map[string]interface{}) error {
+	names := make([]string, 0, len(p.Claims))
+	for name := range p.Claims {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var value string
+		switch v := claims[name].(type) {
+		case string:
+			value = v
+		case bool, float64:
+			value = fmt.Sprint(v)
+		default:
+			return xerrors.Errorf("claim %q is missing or isn't a string", name)
+		}
+		ok, err := path.Match(p.Claims[name], value)
+		if err != nil {
+			return xerrors.Errorf("match claim %q: %w", name, err)
+		}
+		if !ok {
+			return xerrors.Errorf("claim %q with value %q isn't allowed", name, value)
+		}
+	}
+	return nil
+}
+
+// Exchanges an identity token of an external OIDC issuer, like GitHub Actions,
+// for a short-lived API token of the user of the first token exchange policy
+// that accepts it.
+//
+// @Summary Exchange identity token for API token
+// @ID exchange-identity-token-for-api-token
+// @Accept json
+// @Produce json
+// @Tags Authorization
+// @Param request body codersdk.TokenExchangeRequest true "Token exchange request"
+// @Success 200 {object} codersdk.TokenExchangeResponse
+// @Router /oauth/token-exchange [post]
+func (api *API) postTokenExchange(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.APIKey](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	aReq.Old = database.APIKey{}
+	defer commitAudit()
+
+	var req codersdk.TokenExchangeRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if req.GrantType != codersdk.TokenExchangeGrantType {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Unsupported grant type %q.", req.GrantType),
+			Validations: []codersdk.ValidationError{{
+				Field:  "grant_type",
+				Detail: fmt.Sprintf("Must be %q.", codersdk.TokenExchangeGrantType),
+			}},
+		})
+		return
+	}
+	switch req.SubjectTokenType {
+	case "", codersdk.TokenTypeIDToken, codersdk.TokenTypeJWT:
+	default:
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Unsupported subject token type %q.", req.SubjectTokenType),
+			Validations: []codersdk.ValidationError{{
+				Field:  "subject_token_type",
+				Detail: fmt.Sprintf("Must be %q or %q.", codersdk.TokenTypeIDToken, codersdk.TokenTypeJWT),
+			}},
+		})
+		return
+	}
+
+	policy, idToken, err := api.matchTokenExchangePolicy(ctx, req.SubjectToken)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: "The identity token isn't accepted by any token exchange policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	//nolint:gocritic // The request is unauthenticated, the policy authorizes it.
+	user, err := api.Database.GetUserByEmailOrUsername(dbauthz.AsSystemRestricted(ctx), database.GetUserByEmailOrUsernameParams{
+		Username: policy.Username,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// The caller can't list users, so don't tell them who the policy is
+		// for.
+		api.Logger.Warn(ctx, "user of token exchange policy does not exist", slog.F("username", policy.Username))
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.UserID = user.ID
+	if user.Status == database.UserStatusSuspended {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: fmt.Sprintf("The user %s of the token exchange policy is suspended.", user.ID),
+		})
+		return
+	}
+
+	lifetime := policy.Lifetime
+	if lifetime <= 0 {
+		lifetime = codersdk.DefaultTokenExchangeLifetime
+	}
+	if maxLifetime := api.DeploymentValues.MaxTokenLifetime.Value(); lifetime > maxLifetime {
+		lifetime = maxLifetime
+	}
+	suffix, err := cryptorand.HexString(8)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to generate token name.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	//nolint:gocritic // The request is unauthenticated, the policy authorizes it.
+	cookie, key, err := api.createAPIKey(dbauthz.AsSystemRestricted(ctx), apikey.CreateParams{
+		UserID:           user.ID,
+		LoginType:        database.LoginTypeToken,
+		DeploymentValues: api.DeploymentValues,
+		ExpiresAt:        database.Now().Add(lifetime),
+		Scope:            database.APIKeyScope(policy.Scope),
+		LifetimeSeconds:  int64(lifetime.Seconds()),
+		TokenName:        "token-exchange-" + suffix,
+		RemoteAddr:       r.RemoteAddr,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to create API key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = *key
+
+	api.Logger.Info(ctx, "exchanged identity token for api token",
+		slog.F("issuer", idToken.Issuer),
+		slog.F("subject", idToken.Subject),
+		slog.F("username", user.Username),
+		slog.F("token_name", key.TokenName),
+	)
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.TokenExchangeResponse{
+		AccessToken:     cookie.Value,
+		IssuedTokenType: codersdk.TokenTypeAccessToken,
+		// The token isn't an OAuth access token, it's sent like a session
+		// token.
+		TokenType: "N_A",
+		ExpiresIn: int64(lifetime.Seconds()),
+	})
+}
+
+// matchTokenExchangePolicy returns the first policy of the token's issuer
+// that verifies the token and whose claims it matches. The error explains why
+// each policy of the issuer rejected it. It's returned to unauthenticated
+// callers, so policies are referred to by their index rather than their user.
+func (api *API) matchTokenExchangePolicy(ctx context.Context, rawToken string) (TokenExchangePolicy, *oidc.IDToken, error) {
+	if len(api.TokenExchangePolicies) == 0 {
+		return TokenExchangePolicy{}, nil, xerrors.New("no token exchange policies are configured")
+	}
+	issuer, err := unverifiedIssuer(rawToken)
+	if err != nil {
+		return TokenExchangePolicy{}, nil, err
+	}
+
+	var rejections []string
+	for i, policy := range api.TokenExchangePolicies {
+		if policy.Issuer != issuer {
+			continue
+		}
+		idToken, err := policy.Verifier.Verify(ctx, rawToken)
+		if err != nil {
+			rejections = append(rejections, fmt.Sprintf("policy %d: verify token: %s", i, err))
+			continue
+		}
+		var claims map[string]interface{}
+		err = idToken.Claims(&claims)
+		if err != nil {
+			return TokenExchangePolicy{}, nil, xerrors.Errorf("decode claims: %w", err)
+		}
+		err = policy.matchClaims(claims)
+		if err != nil {
+			rejections = append(rejections, fmt.Sprintf("policy %d: %s", i, err))
+			continue
+		}
+		return policy, idToken, nil
+	}
+	if len(rejections) == 0 {
+		return TokenExchangePolicy{}, nil, xerrors.Errorf("no token exchange policy accepts tokens of issuer %q", issuer)
+	}
+	return TokenExchangePolicy{}, nil, xerrors.New(strings.Join(rejections, "; "))
+}
+
+// unverifiedIssuer returns the iss claim of a JWT without verifying it, so
+// only the policies of that issuer verify the token.
+func unverifiedIssuer(rawToken string) (string, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return "", xerrors.New("token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", xerrors.Errorf("decode token payload: %w", err)
+	}
+	var claims struct {
+		Issuer string `json:"iss"`
+	}
+	err = json.Unmarshal(payload, &claims)
+	if err != nil {
+		return "", xerrors.Errorf("decode token claims: %w", err)
+	}
+	if claims.Issuer == "" {
+		return "", xerrors.New("token has no issuer")
+	}
+	return claims.Issuer, nil
+}
@@ -0,0 +1,207 @@
+package coderd_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTokenExchange(t *testing.T) {
+	t.Parallel()
+
+	const username = "ci"
+	setup := func(t *testing.T) (*codersdk.Client, codersdk.User, func(claims jwt.MapClaims) string) {
+		t.Helper()
+		fake := coderdtest.NewOIDCConfig(t, "")
+		client := coderdtest.New(t, &coderdtest.Options{
+			TokenExchangePolicies: []coderd.TokenExchangePolicy{
+				fake.TokenExchangePolicy(codersdk.TokenExchangePolicy{
+					Audience: "coder",
+					Claims: map[string]string{
+						"repository": "coder/*",
+						"ref":        "refs/heads/main",
+					},
+					Username: username,
+					Scope:    codersdk.APIKeyScopeWorkspaceRead,
+					Lifetime: 15 * time.Minute,
+				}),
+			},
+		})
+		first := coderdtest.CreateFirstUser(t, client)
+		_, user := coderdtest.CreateAnotherUserMutators(t, client, first.OrganizationID, nil, func(r *codersdk.CreateUserRequest) {
+			r.Username = username
+		})
+		return client, user, func(claims jwt.MapClaims) string {
+			encoded := fake.EncodeClaims(t, claims)
+			token, err := base64.StdEncoding.DecodeString(encoded)
+			require.NoError(t, err)
+			return string(token)
+		}
+	}
+	exchange := func(ctx context.Context, client *codersdk.Client, token string) (codersdk.TokenExchangeResponse, error) {
+		return codersdk.New(client.URL).ExchangeToken(ctx, codersdk.TokenExchangeRequest{
+			GrantType:    codersdk.TokenExchangeGrantType,
+			SubjectToken: token,
+		})
+	}
+	requireStatus := func(t *testing.T, err error, status int) *codersdk.Error {
+		t.Helper()
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, status, sdkErr.StatusCode())
+		return sdkErr
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client, user, sign := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		res, err := exchange(ctx, client, sign(jwt.MapClaims{
+			"aud":        "coder",
+			"repository": "coder/coder",
+			"ref":        "refs/heads/main",
+		}))
+		require.NoError(t, err)
+		require.Equal(t, codersdk.TokenTypeAccessToken, res.IssuedTokenType)
+		require.EqualValues(t, (15 * time.Minute).Seconds(), res.ExpiresIn)
+
+		exchanged := codersdk.New(client.URL)
+		exchanged.SetSessionToken(res.AccessToken)
+		me, err := exchanged.User(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, user.ID, me.ID)
+
+		keys, err := client.Tokens(ctx, user.ID.String(), codersdk.TokensFilter{})
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		require.Equal(t, codersdk.APIKeyScopeWorkspaceRead, keys[0].Scope)
+		require.Equal(t, codersdk.LoginTypeToken, keys[0].LoginType)
+	})
+
+	t.Run("ClaimMismatch", func(t *testing.T) {
+		t.Parallel()
+		client, _, sign := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		_, err := exchange(ctx, client, sign(jwt.MapClaims{
+			"aud":        "coder",
+			"repository": "someone/coder",
+			"ref":        "refs/heads/main",
+		}))
+		sdkErr := requireStatus(t, err, http.StatusUnauthorized)
+		require.Contains(t, sdkErr.Detail, `claim "repository" with value "someone/coder" isn't allowed`)
+		// The caller isn't authenticated, so the user of the policy isn't
+		// revealed.
+		require.NotContains(t, sdkErr.Detail, fmt.Sprintf("%q", username))
+
+		_, err = exchange(ctx, client, sign(jwt.MapClaims{
+			"aud":        "coder",
+			"repository": "coder/coder",
+		}))
+		sdkErr = requireStatus(t, err, http.StatusUnauthorized)
+		require.Contains(t, sdkErr.Detail, `claim "ref" is missing`)
+	})
+
+	t.Run("WrongAudience", func(t *testing.T) {
+		t.Parallel()
+		client, _, sign := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		_, err := exchange(ctx, client, sign(jwt.MapClaims{
+			"aud":        "someone-else",
+			"repository": "coder/coder",
+			"ref":        "refs/heads/main",
+		}))
+		sdkErr := requireStatus(t, err, http.StatusUnauthorized)
+		require.Contains(t, sdkErr.Detail, "audience")
+	})
+
+	t.Run("UnknownIssuer", func(t *testing.T) {
+		t.Parallel()
+		client, _, sign := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		_, err := exchange(ctx, client, sign(jwt.MapClaims{
+			"iss":        "https://example.com",
+			"aud":        "coder",
+			"repository": "coder/coder",
+			"ref":        "refs/heads/main",
+		}))
+		sdkErr := requireStatus(t, err, http.StatusUnauthorized)
+		require.Contains(t, sdkErr.Detail, `no token exchange policy accepts tokens of issuer "https://example.com"`)
+	})
+
+	t.Run("SuspendedUser", func(t *testing.T) {
+		t.Parallel()
+		client, user, sign := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		_, err := client.UpdateUserStatus(ctx, user.ID.String(), codersdk.UserStatusSuspended)
+		require.NoError(t, err)
+		_, err = exchange(ctx, client, sign(jwt.MapClaims{
+			"aud":        "coder",
+			"repository": "coder/coder",
+			"ref":        "refs/heads/main",
+		}))
+		requireStatus(t, err, http.StatusForbidden)
+	})
+
+	t.Run("DeletedUser", func(t *testing.T) {
+		t.Parallel()
+		client, user, sign := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		err := client.DeleteUser(ctx, user.ID)
+		require.NoError(t, err)
+		_, err = exchange(ctx, client, sign(jwt.MapClaims{
+			"aud":        "coder",
+			"repository": "coder/coder",
+			"ref":        "refs/heads/main",
+		}))
+		sdkErr := requireStatus(t, err, http.StatusNotFound)
+		require.NotContains(t, sdkErr.Message, username)
+	})
+
+	t.Run("GrantType", func(t *testing.T) {
+		t.Parallel()
+		client, _, sign := setup(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		_, err := codersdk.New(client.URL).ExchangeToken(ctx, codersdk.TokenExchangeRequest{
+			GrantType:    "client_credentials",
+			SubjectToken: sign(jwt.MapClaims{"aud": "coder"}),
+		})
+		requireStatus(t, err, http.StatusBadRequest)
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		_, err := exchange(ctx, client, "a.b.c")
+		sdkErr := requireStatus(t, err, http.StatusUnauthorized)
+		require.Contains(t, sdkErr.Detail, "no token exchange policies are configured")
+	})
+}
@@ -283,6 +283,10 @@ func unhangJob(ctx context.Context, log slog.Logger, db database.Store, pub pubs
 			insertParams.Stage = append(insertParams.Stage, logStage)
 			insertParams.Source = append(insertParams.Source, database.LogSourceProvisionerDaemon)
 			insertParams.Output = append(insertParams.Output, msg)
+			insertParams.ProgressStage = append(insertParams.ProgressStage, "")
+			insertParams.ProgressResource = append(insertParams.ProgressResource, "")
+			insertParams.ProgressCompleted = append(insertParams.ProgressCompleted, 0)
+			insertParams.ProgressTotal = append(insertParams.ProgressTotal, 0)
 		}
 		newLogs, err := db.InsertProvisionerJobLogs(ctx, insertParams)
 		if err != nil {
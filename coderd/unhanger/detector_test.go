@@ -672,6 +672,10 @@ func TestDetectorPushesLogs(t *testing.T) {
 					insertParams.Stage = append(insertParams.Stage, c.preLogStage)
 					insertParams.Source = append(insertParams.Source, database.LogSourceProvisioner)
 					insertParams.Output = append(insertParams.Output, fmt.Sprintf("Output %d", i))
+					insertParams.ProgressStage = append(insertParams.ProgressStage, "")
+					insertParams.ProgressResource = append(insertParams.ProgressResource, "")
+					insertParams.ProgressCompleted = append(insertParams.ProgressCompleted, 0)
+					insertParams.ProgressTotal = append(insertParams.ProgressTotal, 0)
 				}
 				logs, err := db.InsertProvisionerJobLogs(ctx, insertParams)
 				require.NoError(t, err)
@@ -259,6 +259,7 @@ func (api *API) postLogin(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	aReq.New = *key
+	api.firstLogin(user)
 
 	http.SetCookie(rw, cookie)
 
@@ -1488,11 +1489,41 @@ func (api *API) oauthLogin(r *http.Request, params *oauthLoginParams) ([]*http.C
 		}
 		cookies = append(cookies, cookie)
 		key = *newKey
+		api.firstLogin(user)
 	}
 
 	return cookies, key, nil
 }
 
+// firstLoginTimeout bounds how long FirstLoginFn may run for.
+const firstLoginTimeout = 5 * time.Minute
+
+// firstLogin calls FirstLoginFn if the user has never been seen before, which
+// means it's their first login. It runs in the background, so the login isn't
+// held up by it.
+func (api *API) firstLogin(user database.User) {
+	if !user.LastSeenAt.IsZero() {
+		return
+	}
+	fn := api.FirstLoginFn.Load()
+	if fn == nil || *fn == nil {
+		return
+	}
+
+	api.WebsocketWaitMutex.Lock()
+	api.WebsocketWaitGroup.Add(1)
+	api.WebsocketWaitMutex.Unlock()
+	go func() {
+		defer api.WebsocketWaitGroup.Done()
+
+		// The request context is canceled once the login is done, so the
+		// API context is used instead.
+		ctx, cancel := context.WithTimeout(api.ctx, firstLoginTimeout)
+		defer cancel()
+		(*fn)(ctx, user)
+	}()
+}
+
 // convertUserToOauth will convert a user from password base loginType to
 // an oauth login type. If it fails, it will return a httpError
 func (api *API) convertUserToOauth(ctx context.Context, r *http.Request, db database.Store, params *oauthLoginParams) (database.User, error) {
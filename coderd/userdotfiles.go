@@ -0,0 +1,152 @@
+package coderd
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get user dotfiles
+// @Description The repository URL is empty if the user hasn't configured dotfiles.
+// @ID get-user-dotfiles
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 200 {object} codersdk.UserDotfiles
+// @Router /users/{user}/dotfiles [get]
+func (api *API) userDotfiles(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	dotfiles, err := api.Database.GetUserDotfiles(ctx, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusOK, codersdk.UserDotfiles{
+			UserID: user.ID,
+		})
+		return
+	}
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user dotfiles.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertUserDotfiles(dotfiles))
+}
+
+// @Summary Update user dotfiles
+// @Description An empty repository URL removes the dotfiles of the user.
+// @ID update-user-dotfiles
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param request body codersdk.UpdateUserDotfilesRequest true "Update dotfiles request"
+// @Success 200 {object} codersdk.UserDotfiles
+// @Router /users/{user}/dotfiles [put]
+func (api *API) putUserDotfiles(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	var req codersdk.UpdateUserDotfilesRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	if req.RepoURL == "" {
+		err := api.Database.DeleteUserDotfiles(ctx, user.ID)
+		if dbauthz.IsNotAuthorizedError(err) {
+			httpapi.ResourceNotFound(rw)
+			return
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error deleting user dotfiles.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		httpapi.Write(ctx, rw, http.StatusOK, codersdk.UserDotfiles{
+			UserID: user.ID,
+		})
+		return
+	}
+
+	var validations []codersdk.ValidationError
+	if err := dotfilesArgValid(req.RepoURL); err != nil {
+		validations = append(validations, codersdk.ValidationError{Field: "repo_url", Detail: err.Error()})
+	}
+	if req.Branch != "" {
+		if err := dotfilesArgValid(req.Branch); err != nil {
+			validations = append(validations, codersdk.ValidationError{Field: "branch", Detail: err.Error()})
+		}
+	}
+	if len(validations) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid dotfiles.",
+			Validations: validations,
+		})
+		return
+	}
+
+	dotfiles, err := api.Database.UpsertUserDotfiles(ctx, database.UpsertUserDotfilesParams{
+		UserID:    user.ID,
+		RepoURL:   req.RepoURL,
+		Branch:    req.Branch,
+		UpdatedAt: database.Now(),
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating user dotfiles.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertUserDotfiles(dotfiles))
+}
+
+// dotfilesArgRegex matches the repository URLs and branches the agent can pass
+// to "coder dotfiles" without them being interpreted by the shell.
+var dotfilesArgRegex = regexp.MustCompile(`^[a-zA-Z0-9._~:/@+=][a-zA-Z0-9._~:/@+=-]*$`)
+
+func dotfilesArgValid(arg string) error {
+	if strings.HasPrefix(arg, "-") {
+		return xerrors.New("must not start with a dash")
+	}
+	if !dotfilesArgRegex.MatchString(arg) {
+		return xerrors.New("must only contain letters, digits and the characters ._~:/@+=-")
+	}
+	return nil
+}
+
+func convertUserDotfiles(dotfiles database.UserDotfile) codersdk.UserDotfiles {
+	return codersdk.UserDotfiles{
+		UserID:    dotfiles.UserID,
+		RepoURL:   dotfiles.RepoURL,
+		Branch:    dotfiles.Branch,
+		UpdatedAt: dotfiles.UpdatedAt,
+	}
+}
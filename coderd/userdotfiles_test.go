@@ -0,0 +1,130 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/testutil"
+)
+
+func TestUserDotfiles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+
+		dotfiles, err := client.UserDotfiles(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, user.UserID, dotfiles.UserID)
+		require.Empty(t, dotfiles.RepoURL)
+
+		dotfiles, err = client.UpdateUserDotfiles(ctx, codersdk.Me, codersdk.UpdateUserDotfilesRequest{
+			RepoURL: "https://github.com/example/dotfiles.git",
+			Branch:  "main",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "https://github.com/example/dotfiles.git", dotfiles.RepoURL)
+		require.Equal(t, "main", dotfiles.Branch)
+
+		fetched, err := client.UserDotfiles(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, dotfiles.RepoURL, fetched.RepoURL)
+		require.Equal(t, dotfiles.Branch, fetched.Branch)
+
+		_, err = client.UpdateUserDotfiles(ctx, codersdk.Me, codersdk.UpdateUserDotfilesRequest{})
+		require.NoError(t, err)
+		dotfiles, err = client.UserDotfiles(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Empty(t, dotfiles.RepoURL)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		for _, req := range []codersdk.UpdateUserDotfilesRequest{
+			{RepoURL: "--upload-pack=touch /tmp/pwned"},
+			{RepoURL: "https://github.com/example/dotfiles.git; rm -rf ~"},
+			{RepoURL: "https://github.com/example/dotfiles.git", Branch: "'main'"},
+		} {
+			_, err := client.UpdateUserDotfiles(ctx, codersdk.Me, req)
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		}
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, nil)
+		owner := coderdtest.CreateFirstUser(t, client)
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+
+		_, err := client.UpdateUserDotfiles(ctx, codersdk.Me, codersdk.UpdateUserDotfilesRequest{
+			RepoURL: "https://github.com/example/dotfiles.git",
+		})
+		require.NoError(t, err)
+
+		_, err = memberClient.UserDotfiles(ctx, owner.UserID.String())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestWorkspaceAgentManifestDotfiles(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitMedium)
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	_, err := client.UpdateUserDotfiles(ctx, codersdk.Me, codersdk.UpdateUserDotfilesRequest{
+		RepoURL: "https://github.com/example/dotfiles.git",
+		Branch:  "main",
+	})
+	require.NoError(t, err)
+
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:          echo.ParseComplete,
+		ProvisionPlan:  echo.ProvisionComplete,
+		ProvisionApply: echo.ProvisionApplyWithAgent(authToken),
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(authToken)
+	manifest, err := agentClient.Manifest(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, manifest.Dotfiles)
+	require.Equal(t, "https://github.com/example/dotfiles.git", manifest.Dotfiles.RepoURL)
+	require.Equal(t, "main", manifest.Dotfiles.Branch)
+
+	// Templates can disable dotfiles.
+	applyDotfiles := false
+	_, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+		ApplyDotfiles: &applyDotfiles,
+	})
+	require.NoError(t, err)
+	manifest, err = agentClient.Manifest(ctx)
+	require.NoError(t, err)
+	require.Nil(t, manifest.Dotfiles)
+}
@@ -0,0 +1,274 @@
+package coderd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/usersecrets"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get user secrets
+// @Description The values of secrets are never returned.
+// @ID get-user-secrets
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 200 {array} codersdk.UserSecret
+// @Router /users/{user}/secrets [get]
+func (api *API) userSecrets(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	if !api.userSecretsEnabled(rw, r) {
+		return
+	}
+
+	secrets, err := api.Database.GetUserSecretsByUserID(ctx, user.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user secrets.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	sdkSecrets := make([]codersdk.UserSecret, 0, len(secrets))
+	for _, secret := range secrets {
+		sdkSecrets = append(sdkSecrets, convertUserSecret(secret))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, sdkSecrets)
+}
+
+// @Summary Create user secret
+// @ID create-user-secret
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param request body codersdk.CreateUserSecretRequest true "Create secret request"
+// @Success 201 {object} codersdk.UserSecret
+// @Router /users/{user}/secrets [post]
+func (api *API) postUserSecret(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	if !api.userSecretsEnabled(rw, r) {
+		return
+	}
+
+	var req codersdk.CreateUserSecretRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if err := usersecrets.NameValid(req.Name); err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid secret name.",
+			Validations: []codersdk.ValidationError{
+				{Field: "name", Detail: err.Error()},
+			},
+		})
+		return
+	}
+	value, ok := api.encryptUserSecret(rw, r, req.Value)
+	if !ok {
+		return
+	}
+
+	now := database.Now()
+	secret, err := api.Database.InsertUserSecret(ctx, database.InsertUserSecretParams{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Value:       value,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if database.IsUniqueViolation(err, database.UniqueUserSecretsUserIDNameKey) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("Secret with name %q already exists.", req.Name),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating user secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertUserSecret(secret))
+}
+
+// @Summary Update user secret
+// @ID update-user-secret
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param name path string true "Secret name"
+// @Param request body codersdk.UpdateUserSecretRequest true "Update secret request"
+// @Success 200 {object} codersdk.UserSecret
+// @Router /users/{user}/secrets/{name} [patch]
+func (api *API) patchUserSecret(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+	name := chi.URLParam(r, "name")
+
+	if !api.userSecretsEnabled(rw, r) {
+		return
+	}
+
+	var req codersdk.UpdateUserSecretRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	secret, err := api.Database.GetUserSecretByUserIDAndName(ctx, database.GetUserSecretByUserIDAndNameParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	if req.Description != nil {
+		secret.Description = *req.Description
+	}
+	if req.Value != nil {
+		value, ok := api.encryptUserSecret(rw, r, *req.Value)
+		if !ok {
+			return
+		}
+		secret.Value = value
+	}
+
+	secret, err = api.Database.UpdateUserSecret(ctx, database.UpdateUserSecretParams{
+		UserID:      secret.UserID,
+		Name:        secret.Name,
+		Description: secret.Description,
+		Value:       secret.Value,
+		UpdatedAt:   database.Now(),
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating user secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertUserSecret(secret))
+}
+
+// @Summary Delete user secret
+// @ID delete-user-secret
+// @Security CoderSessionToken
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param name path string true "Secret name"
+// @Success 204
+// @Router /users/{user}/secrets/{name} [delete]
+func (api *API) deleteUserSecret(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+	name := chi.URLParam(r, "name")
+
+	if !api.userSecretsEnabled(rw, r) {
+		return
+	}
+
+	err := api.Database.DeleteUserSecret(ctx, database.DeleteUserSecretParams{
+		UserID: user.ID,
+		Name:   name,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting user secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (api *API) userSecretsEnabled(rw http.ResponseWriter, r *http.Request) bool {
+	if api.UserSecretsCipher == nil {
+		httpapi.Write(r.Context(), rw, http.StatusForbidden, codersdk.Response{
+			Message: "User secrets are disabled.",
+			Detail:  "Set --user-secrets-encryption-key to enable them.",
+		})
+		return false
+	}
+	return true
+}
+
+func (api *API) encryptUserSecret(rw http.ResponseWriter, r *http.Request, value string) ([]byte, bool) {
+	ctx := r.Context()
+	if len(value) > usersecrets.MaxValueLength {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Secret value is too long.",
+			Validations: []codersdk.ValidationError{
+				{Field: "value", Detail: fmt.Sprintf("must be at most %d bytes", usersecrets.MaxValueLength)},
+			},
+		})
+		return nil, false
+	}
+	encrypted, err := api.UserSecretsCipher.Encrypt([]byte(value))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error encrypting user secret.",
+			Detail:  err.Error(),
+		})
+		return nil, false
+	}
+	return encrypted, true
+}
+
+func convertUserSecret(secret database.UserSecret) codersdk.UserSecret {
+	return codersdk.UserSecret{
+		ID:          secret.ID,
+		UserID:      secret.UserID,
+		Name:        secret.Name,
+		Description: secret.Description,
+		CreatedAt:   secret.CreatedAt,
+		UpdatedAt:   secret.UpdatedAt,
+	}
+}
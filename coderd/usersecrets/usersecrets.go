@@ -0,0 +1,73 @@
+// Package usersecrets encrypts the values of user secrets at rest.
+package usersecrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"regexp"
+
+	"golang.org/x/xerrors"
+)
+
+// MaxValueLength is the maximum length of a secret value in bytes.
+const MaxValueLength = 64 * 1024
+
+// nameRegex matches names that can be used as environment variable names.
+var nameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+// NameValid returns an error if the name can't be used as the name of a
+// secret. Secrets are exposed to workspaces as environment variables of the
+// same name.
+func NameValid(name string) error {
+	if !nameRegex.MatchString(name) {
+		return xerrors.Errorf("%q must start with a letter or underscore, contain only letters, digits and underscores, and be at most 64 characters", name)
+	}
+	return nil
+}
+
+// Cipher encrypts and decrypts secret values with AES-256-GCM.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New returns a cipher for the given 32 byte key.
+func New(key []byte) (*Cipher, error) {
+	if len(key) != 32 {
+		return nil, xerrors.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, xerrors.Errorf("create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, xerrors.Errorf("create GCM: %w", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt seals the value with a random nonce, which is prepended to the
+// ciphertext.
+func (c *Cipher) Encrypt(value []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, xerrors.Errorf("read nonce: %w", err)
+	}
+	return c.aead.Seal(nonce, nonce, value, nil), nil
+}
+
+// Decrypt opens a value sealed by Encrypt.
+func (c *Cipher) Decrypt(data []byte) ([]byte, error) {
+	if len(data) < c.aead.NonceSize() {
+		return nil, xerrors.New("ciphertext is too short")
+	}
+	nonce, ciphertext := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	value, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("decrypt, is the encryption key the same on all replicas? %w", err)
+	}
+	return value, nil
+}
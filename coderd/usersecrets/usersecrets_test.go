@@ -0,0 +1,59 @@
+package usersecrets_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/usersecrets"
+)
+
+func TestCipher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		t.Parallel()
+
+		c, err := usersecrets.New(bytes.Repeat([]byte{1}, 32))
+		require.NoError(t, err)
+		data, err := c.Encrypt([]byte("hunter2"))
+		require.NoError(t, err)
+		require.NotContains(t, string(data), "hunter2")
+		value, err := c.Decrypt(data)
+		require.NoError(t, err)
+		require.Equal(t, "hunter2", string(value))
+	})
+
+	t.Run("WrongKey", func(t *testing.T) {
+		t.Parallel()
+
+		c, err := usersecrets.New(bytes.Repeat([]byte{1}, 32))
+		require.NoError(t, err)
+		data, err := c.Encrypt([]byte("hunter2"))
+		require.NoError(t, err)
+
+		other, err := usersecrets.New(bytes.Repeat([]byte{2}, 32))
+		require.NoError(t, err)
+		_, err = other.Decrypt(data)
+		require.Error(t, err)
+	})
+
+	t.Run("InvalidKeyLength", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := usersecrets.New([]byte("short"))
+		require.Error(t, err)
+	})
+}
+
+func TestNameValid(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"AWS_SECRET_ACCESS_KEY", "_TOKEN", "token2"} {
+		require.NoError(t, usersecrets.NameValid(name), name)
+	}
+	for _, name := range []string{"", "2TOKEN", "MY-TOKEN", "MY TOKEN", string(bytes.Repeat([]byte("A"), 65))} {
+		require.Error(t, usersecrets.NameValid(name), name)
+	}
+}
@@ -0,0 +1,183 @@
+package coderd_test
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/usersecrets"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func newUserSecretsCipher(t *testing.T) *usersecrets.Cipher {
+	t.Helper()
+
+	cipher, err := usersecrets.New(bytes.Repeat([]byte{'k'}, 32))
+	require.NoError(t, err)
+	return cipher
+}
+
+func TestUserSecrets(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		_, err := client.UserSecrets(ctx, codersdk.Me)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, &coderdtest.Options{
+			UserSecretsCipher: newUserSecretsCipher(t),
+		})
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		secret, err := client.CreateUserSecret(ctx, codersdk.Me, codersdk.CreateUserSecretRequest{
+			Name:        "NPM_TOKEN",
+			Description: "Publishes packages",
+			Value:       "hunter2",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "NPM_TOKEN", secret.Name)
+		require.Equal(t, "Publishes packages", secret.Description)
+
+		_, err = client.CreateUserSecret(ctx, codersdk.Me, codersdk.CreateUserSecretRequest{
+			Name:  "NPM_TOKEN",
+			Value: "hunter3",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		description := "Publishes and installs packages"
+		secret, err = client.UpdateUserSecret(ctx, codersdk.Me, "NPM_TOKEN", codersdk.UpdateUserSecretRequest{
+			Description: &description,
+		})
+		require.NoError(t, err)
+		require.Equal(t, description, secret.Description)
+
+		secrets, err := client.UserSecrets(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		require.Equal(t, secret, secrets[0])
+
+		err = client.DeleteUserSecret(ctx, codersdk.Me, "NPM_TOKEN")
+		require.NoError(t, err)
+		err = client.DeleteUserSecret(ctx, codersdk.Me, "NPM_TOKEN")
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		secrets, err = client.UserSecrets(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Empty(t, secrets)
+	})
+
+	t.Run("InvalidName", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, &coderdtest.Options{
+			UserSecretsCipher: newUserSecretsCipher(t),
+		})
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		_, err := client.CreateUserSecret(ctx, codersdk.Me, codersdk.CreateUserSecretRequest{
+			Name:  "NPM-TOKEN",
+			Value: "hunter2",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitMedium)
+		client := coderdtest.New(t, &coderdtest.Options{
+			UserSecretsCipher: newUserSecretsCipher(t),
+		})
+		owner := coderdtest.CreateFirstUser(t, client)
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+
+		_, err := client.CreateUserSecret(ctx, codersdk.Me, codersdk.CreateUserSecretRequest{
+			Name:  "NPM_TOKEN",
+			Value: "hunter2",
+		})
+		require.NoError(t, err)
+
+		_, err = memberClient.UserSecrets(ctx, owner.UserID.String())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestWorkspaceAgentManifestSecrets(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitMedium)
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+		UserSecretsCipher:        newUserSecretsCipher(t),
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	_, err := client.CreateUserSecret(ctx, codersdk.Me, codersdk.CreateUserSecretRequest{
+		Name:  "NPM_TOKEN",
+		Value: "hunter2",
+	})
+	require.NoError(t, err)
+	_, err = client.CreateUserSecret(ctx, codersdk.Me, codersdk.CreateUserSecretRequest{
+		Name:  "UNREFERENCED",
+		Value: "unused",
+	})
+	require.NoError(t, err)
+
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:         echo.ParseComplete,
+		ProvisionPlan: echo.ProvisionComplete,
+		ProvisionApply: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: []*proto.Resource{{
+						Name: "example",
+						Type: "aws_instance",
+						Agents: []*proto.Agent{{
+							Id: uuid.NewString(),
+							Auth: &proto.Agent_Token{
+								Token: authToken,
+							},
+							// GITHUB_TOKEN isn't stored by the user.
+							Secrets: []string{"GITHUB_TOKEN", "NPM_TOKEN"},
+						}},
+					}},
+				},
+			},
+		}},
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(authToken)
+	manifest, err := agentClient.Manifest(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"NPM_TOKEN": "hunter2"}, manifest.Secrets)
+}
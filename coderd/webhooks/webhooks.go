@@ -0,0 +1,379 @@
+// Package webhooks sends workspace lifecycle and budget events to external
+// HTTP endpoints.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/retry"
+)
+
+// Event is the type of a webhook event.
+type Event string
+
+const (
+	// EventWorkspaceAutostopImminent is sent once when a started workspace
+	// is about to reach its deadline and will be stopped by the server.
+	EventWorkspaceAutostopImminent Event = "workspace.autostop_imminent"
+	// EventWorkspaceStopped is sent when the server stops a workspace, either
+	// because it reached its deadline, failed to start, or was locked for
+	// inactivity.
+	EventWorkspaceStopped Event = "workspace.stopped"
+	// EventWorkspaceStartFailed is sent once when a workspace failed to start
+	// and all retries allowed by the template are exhausted. The workspace
+	// will be stopped because of the template's failure TTL.
+	EventWorkspaceStartFailed Event = "workspace.start_failed"
+	// EventWorkspaceDeletedDueToLockedTTL is sent when the server deletes a
+	// workspace that has been locked for longer than the template's locked
+	// TTL.
+	EventWorkspaceDeletedDueToLockedTTL Event = "workspace.deleted_due_to_locked_ttl"
+	// EventWorkspaceArchived is sent when the server archives a workspace
+	// that has been locked for longer than the template's locked TTL, instead
+	// of deleting it. The owner can restore the workspace by unlocking it
+	// until it is deleted.
+	EventWorkspaceArchived Event = "workspace.archived"
+	// EventWorkspaceLifetimeExpiring is sent once when a workspace will reach
+	// the max lifetime of its template within a day. The workspace will be
+	// deleted, or stopped and locked, regardless of its activity.
+	EventWorkspaceLifetimeExpiring Event = "workspace.lifetime_expiring"
+	// EventWorkspaceDeletedDueToMaxLifetime is sent when the server deletes a
+	// workspace that reached the max lifetime of its template.
+	EventWorkspaceDeletedDueToMaxLifetime Event = "workspace.deleted_due_to_max_lifetime"
+	// EventWorkspaceLocked is sent when a user locks a workspace through the
+	// API.
+	EventWorkspaceLocked Event = "workspace.locked"
+	// EventWorkspaceUnlocked is sent when a user unlocks a workspace through
+	// the API.
+	EventWorkspaceUnlocked Event = "workspace.unlocked"
+	// EventOrganizationBudgetThresholdCrossed is sent when the daily cost of
+	// the workspaces of an organization crosses a threshold of the
+	// organization's budget.
+	EventOrganizationBudgetThresholdCrossed Event = "organization.budget_threshold_crossed"
+)
+
+const (
+	// HeaderSignature contains the hex encoded HMAC-SHA256 signature of the
+	// request body, prefixed with "sha256=". It is only set if a signing
+	// secret is configured.
+	HeaderSignature = "X-Coder-Signature-256"
+	// HeaderEvent contains the Event of the request.
+	HeaderEvent = "X-Coder-Event"
+	// HeaderDelivery contains the ID of the event. It is the same for every
+	// attempt to deliver the event so receivers can deduplicate retries.
+	HeaderDelivery = "X-Coder-Delivery"
+)
+
+const (
+	defaultMaxAttempts   = 5
+	defaultRetryInterval = time.Second
+	maxRetryInterval     = time.Minute
+	// queueSize is the number of events that may be waiting for delivery.
+	// Events dispatched while the queue is full are dropped.
+	queueSize = 512
+	// workers is the number of concurrent deliveries.
+	workers = 4
+)
+
+// Payload is the JSON body of a webhook request. Budget events set Budget,
+// and leave Workspace empty, so receivers that only know workspace events
+// still find the workspace object they expect.
+type Payload struct {
+	ID        uuid.UUID `json:"id" format:"uuid"`
+	Event     Event     `json:"event"`
+	CreatedAt time.Time `json:"created_at" format:"date-time"`
+	Workspace Workspace `json:"workspace"`
+	Budget    *Budget   `json:"budget,omitempty"`
+}
+
+// Workspace describes the workspace an event is about.
+type Workspace struct {
+	ID           uuid.UUID `json:"id" format:"uuid"`
+	Name         string    `json:"name"`
+	OwnerID      uuid.UUID `json:"owner_id" format:"uuid"`
+	OwnerName    string    `json:"owner_name"`
+	OwnerEmail   string    `json:"owner_email"`
+	TemplateID   uuid.UUID `json:"template_id" format:"uuid"`
+	TemplateName string    `json:"template_name"`
+	BuildID      uuid.UUID `json:"build_id" format:"uuid"`
+	// Deadline is when the workspace will be stopped. It is only set for
+	// EventWorkspaceAutostopImminent and EventWorkspaceStartFailed. For
+	// EventWorkspaceLifetimeExpiring it is when the workspace expires.
+	Deadline *time.Time `json:"deadline,omitempty" format:"date-time"`
+	// Reason is the build reason of the transition that caused the event.
+	Reason string `json:"reason,omitempty"`
+	// DeletingAt is when the workspace will be deleted. It is only set for
+	// EventWorkspaceLocked if the template has a locked TTL, and for
+	// EventWorkspaceArchived.
+	DeletingAt *time.Time `json:"deleting_at,omitempty" format:"date-time"`
+	// Actor is the username of the user that caused the event. It is only set
+	// for events caused by users.
+	Actor string `json:"actor,omitempty"`
+}
+
+// Budget describes the organization budget an event is about.
+type Budget struct {
+	OrganizationID   uuid.UUID `json:"organization_id" format:"uuid"`
+	OrganizationName string    `json:"organization_name"`
+	DailyBudget      int64     `json:"daily_budget"`
+	// CreditsConsumed is the daily cost of the latest builds of the
+	// organization's workspaces.
+	CreditsConsumed int64 `json:"credits_consumed"`
+	// ThresholdPercent is the percentage of the daily budget that was
+	// crossed.
+	ThresholdPercent int32 `json:"threshold_percent"`
+}
+
+// Options configures a Dispatcher.
+type Options struct {
+	// URLs are the endpoints every event is sent to.
+	URLs []string
+	// SigningSecret is used to sign request bodies. If empty, requests are
+	// not signed.
+	SigningSecret string
+	// MaxAttempts is the number of times delivery to each URL is attempted.
+	// Defaults to 5.
+	MaxAttempts int
+	// RetryInterval is the initial delay between attempts, it doubles after
+	// every attempt up to one minute. Defaults to one second.
+	RetryInterval time.Duration
+	// Client is used to send requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Dispatcher delivers webhook events in the background. A nil *Dispatcher
+// is valid and drops all events, so callers don't need to check whether
+// webhooks are configured.
+type Dispatcher struct {
+	logger  slog.Logger
+	opts    Options
+	now     func() time.Time
+	queue   chan delivery
+	ctx     context.Context
+	cancel  context.CancelFunc
+	closeMu sync.RWMutex
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+type delivery struct {
+	url     string
+	payload Payload
+	body    []byte
+}
+
+// New returns a Dispatcher that sends events to the configured URLs. The
+// Dispatcher must be closed to stop in-flight deliveries.
+func New(logger slog.Logger, opts Options) (*Dispatcher, error) {
+	if len(opts.URLs) == 0 {
+		return nil, xerrors.New("at least one webhook URL is required")
+	}
+	for _, raw := range opts.URLs {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, xerrors.Errorf("parse webhook URL %q: %w", raw, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, xerrors.Errorf("webhook URL must be an http or https URL, got %q", raw)
+		}
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultRetryInterval
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		logger: logger,
+		opts:   opts,
+		now:    time.Now,
+		queue:  make(chan delivery, queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	d.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go d.worker()
+	}
+	return d, nil
+}
+
+// Dispatch queues the event for delivery to all URLs. It never blocks, if
+// the queue is full the event is dropped and a warning is logged.
+func (d *Dispatcher) Dispatch(event Event, workspace Workspace) {
+	if d == nil {
+		return
+	}
+	d.dispatch(Payload{
+		ID:        uuid.New(),
+		Event:     event,
+		CreatedAt: d.now().UTC(),
+		Workspace: workspace,
+	}, slog.F("workspace_id", workspace.ID))
+}
+
+// DispatchBudget queues a budget event for delivery to all URLs. Like
+// Dispatch, it never blocks.
+func (d *Dispatcher) DispatchBudget(event Event, budget Budget) {
+	if d == nil {
+		return
+	}
+	d.dispatch(Payload{
+		ID:        uuid.New(),
+		Event:     event,
+		CreatedAt: d.now().UTC(),
+		Budget:    &budget,
+	}, slog.F("organization_id", budget.OrganizationID))
+}
+
+func (d *Dispatcher) dispatch(payload Payload, subject slog.Field) {
+	event := payload.Event
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.logger.Error(d.ctx, "marshal webhook payload", slog.F("event", event), slog.Error(err))
+		return
+	}
+
+	d.closeMu.RLock()
+	defer d.closeMu.RUnlock()
+	if d.closed {
+		return
+	}
+	for _, u := range d.opts.URLs {
+		select {
+		case d.queue <- delivery{url: u, payload: payload, body: body}:
+		default:
+			d.logger.Warn(d.ctx, "webhook queue is full, dropping event",
+				slog.F("event", event),
+				slog.F("url", u),
+				subject,
+			)
+		}
+	}
+}
+
+// Close stops accepting events, cancels in-flight deliveries and waits for
+// the workers to exit.
+func (d *Dispatcher) Close() error {
+	if d == nil {
+		return nil
+	}
+	d.closeMu.Lock()
+	if d.closed {
+		d.closeMu.Unlock()
+		return nil
+	}
+	d.closed = true
+	close(d.queue)
+	d.closeMu.Unlock()
+
+	d.cancel()
+	d.wg.Wait()
+	return nil
+}
+
+func (d *Dispatcher) worker() {
+	defer d.wg.Done()
+	for del := range d.queue {
+		d.deliver(del)
+	}
+}
+
+func (d *Dispatcher) deliver(del delivery) {
+	logger := d.logger.With(
+		slog.F("event", del.payload.Event),
+		slog.F("delivery_id", del.payload.ID),
+		slog.F("url", del.url),
+	)
+
+	attempt := 0
+	var err error
+	for r := retry.New(d.opts.RetryInterval, maxRetryInterval); r.Wait(d.ctx); {
+		attempt++
+		var retryable bool
+		retryable, err = d.send(del)
+		if err == nil {
+			logger.Debug(d.ctx, "delivered webhook", slog.F("attempt", attempt))
+			return
+		}
+		if !retryable || attempt >= d.opts.MaxAttempts {
+			break
+		}
+		logger.Debug(d.ctx, "webhook delivery failed, retrying", slog.F("attempt", attempt), slog.Error(err))
+	}
+	if err == nil {
+		// The dispatcher was closed before the first attempt.
+		return
+	}
+	logger.Warn(d.ctx, "webhook delivery failed", slog.F("attempts", attempt), slog.Error(err))
+}
+
+// send makes a single delivery attempt. It returns whether the attempt should
+// be retried if it failed.
+func (d *Dispatcher) send(del delivery) (bool, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.url, bytes.NewReader(del.body))
+	if err != nil {
+		return false, xerrors.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Coder-Webhooks")
+	req.Header.Set(HeaderEvent, string(del.payload.Event))
+	req.Header.Set(HeaderDelivery, del.payload.ID.String())
+	if d.opts.SigningSecret != "" {
+		req.Header.Set(HeaderSignature, Sign(d.opts.SigningSecret, del.body))
+	}
+
+	res, err := d.opts.Client.Do(req)
+	if err != nil {
+		return true, xerrors.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<20))
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return false, nil
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+		return true, xerrors.Errorf("unexpected status code %d", res.StatusCode)
+	default:
+		return false, xerrors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+}
+
+// Sign returns the value of the HeaderSignature header for the given body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is a valid HeaderSignature for the given
+// body. Receivers can use it to check that a request was sent by Coder.
+func Verify(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
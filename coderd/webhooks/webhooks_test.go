@@ -0,0 +1,266 @@
+package webhooks_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/testutil"
+)
+
+func TestDispatcher(t *testing.T) {
+	t.Parallel()
+
+	workspace := webhooks.Workspace{
+		ID:        uuid.New(),
+		Name:      "dev",
+		OwnerID:   uuid.New(),
+		OwnerName: "alice",
+		BuildID:   uuid.New(),
+		Reason:    "autostop",
+	}
+
+	t.Run("Signed", func(t *testing.T) {
+		t.Parallel()
+
+		const secret = "hunter2"
+		type request struct {
+			header http.Header
+			body   []byte
+		}
+		requests := make(chan request, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			requests <- request{header: r.Header, body: body}
+		}))
+		defer srv.Close()
+
+		d, err := webhooks.New(slogtest.Make(t, nil), webhooks.Options{
+			URLs:          []string{srv.URL},
+			SigningSecret: secret,
+		})
+		require.NoError(t, err)
+		defer d.Close()
+
+		d.Dispatch(webhooks.EventWorkspaceStopped, workspace)
+
+		var req request
+		select {
+		case req = <-requests:
+		case <-time.After(testutil.WaitShort):
+			t.Fatal("timed out waiting for webhook")
+		}
+		assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+		assert.Equal(t, string(webhooks.EventWorkspaceStopped), req.header.Get(webhooks.HeaderEvent))
+		assert.True(t, webhooks.Verify(secret, req.body, req.header.Get(webhooks.HeaderSignature)))
+		assert.False(t, webhooks.Verify("wrong", req.body, req.header.Get(webhooks.HeaderSignature)))
+
+		var payload webhooks.Payload
+		require.NoError(t, json.Unmarshal(req.body, &payload))
+		assert.Equal(t, req.header.Get(webhooks.HeaderDelivery), payload.ID.String())
+		assert.Equal(t, webhooks.EventWorkspaceStopped, payload.Event)
+		assert.Equal(t, workspace, payload.Workspace)
+		assert.Nil(t, payload.Budget)
+	})
+
+	t.Run("Unsigned", func(t *testing.T) {
+		t.Parallel()
+
+		signatures := make(chan string, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			signatures <- r.Header.Get(webhooks.HeaderSignature)
+		}))
+		defer srv.Close()
+
+		d, err := webhooks.New(slogtest.Make(t, nil), webhooks.Options{
+			URLs: []string{srv.URL},
+		})
+		require.NoError(t, err)
+		defer d.Close()
+
+		d.Dispatch(webhooks.EventWorkspaceStopped, workspace)
+		select {
+		case signature := <-signatures:
+			assert.Empty(t, signature)
+		case <-time.After(testutil.WaitShort):
+			t.Fatal("timed out waiting for webhook")
+		}
+	})
+
+	t.Run("Budget", func(t *testing.T) {
+		t.Parallel()
+
+		bodies := make(chan []byte, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			assert.NoError(t, err)
+			bodies <- body
+		}))
+		defer srv.Close()
+
+		d, err := webhooks.New(slogtest.Make(t, nil), webhooks.Options{
+			URLs: []string{srv.URL},
+		})
+		require.NoError(t, err)
+		defer d.Close()
+
+		budget := webhooks.Budget{
+			OrganizationID:   uuid.New(),
+			OrganizationName: "acme",
+			DailyBudget:      100,
+			CreditsConsumed:  85,
+			ThresholdPercent: 80,
+		}
+		d.DispatchBudget(webhooks.EventOrganizationBudgetThresholdCrossed, budget)
+
+		var body []byte
+		select {
+		case body = <-bodies:
+		case <-time.After(testutil.WaitShort):
+			t.Fatal("timed out waiting for webhook")
+		}
+		var payload webhooks.Payload
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, webhooks.EventOrganizationBudgetThresholdCrossed, payload.Event)
+		assert.Equal(t, &budget, payload.Budget)
+		// Receivers that don't know budget events still get a workspace.
+		assert.Equal(t, webhooks.Workspace{}, payload.Workspace)
+		assert.Contains(t, string(body), `"workspace"`)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts atomic.Int32
+		deliveries := make(chan string, 3)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deliveries <- r.Header.Get(webhooks.HeaderDelivery)
+			switch attempts.Add(1) {
+			case 1:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			case 2:
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		}))
+		defer srv.Close()
+
+		d, err := webhooks.New(slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), webhooks.Options{
+			URLs:          []string{srv.URL},
+			RetryInterval: time.Millisecond,
+		})
+		require.NoError(t, err)
+		defer d.Close()
+
+		d.Dispatch(webhooks.EventWorkspaceAutostopImminent, workspace)
+
+		var ids []string
+		for i := 0; i < 3; i++ {
+			select {
+			case id := <-deliveries:
+				ids = append(ids, id)
+			case <-time.After(testutil.WaitShort):
+				t.Fatalf("timed out waiting for attempt %d", i+1)
+			}
+		}
+		// Every attempt uses the same delivery ID.
+		assert.Equal(t, ids[0], ids[1])
+		assert.Equal(t, ids[0], ids[2])
+	})
+
+	t.Run("MaxAttempts", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts atomic.Int32
+		done := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 2 {
+				close(done)
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		d, err := webhooks.New(slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), webhooks.Options{
+			URLs:          []string{srv.URL},
+			MaxAttempts:   2,
+			RetryInterval: time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		d.Dispatch(webhooks.EventWorkspaceStopped, workspace)
+		select {
+		case <-done:
+		case <-time.After(testutil.WaitShort):
+			t.Fatal("timed out waiting for webhook")
+		}
+		// Give the dispatcher time to make another attempt, if it were to.
+		time.Sleep(50 * time.Millisecond)
+		require.NoError(t, d.Close())
+		assert.EqualValues(t, 2, attempts.Load())
+	})
+
+	t.Run("NoRetryOnClientError", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts atomic.Int32
+		done := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 1 {
+				close(done)
+			}
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		d, err := webhooks.New(slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), webhooks.Options{
+			URLs:          []string{srv.URL},
+			RetryInterval: time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		d.Dispatch(webhooks.EventWorkspaceStopped, workspace)
+		select {
+		case <-done:
+		case <-time.After(testutil.WaitShort):
+			t.Fatal("timed out waiting for webhook")
+		}
+		time.Sleep(50 * time.Millisecond)
+		require.NoError(t, d.Close())
+		assert.EqualValues(t, 1, attempts.Load())
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		t.Parallel()
+
+		var d *webhooks.Dispatcher
+		d.Dispatch(webhooks.EventWorkspaceStopped, workspace)
+		require.NoError(t, d.Close())
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	logger := slogtest.Make(t, nil)
+	_, err := webhooks.New(logger, webhooks.Options{})
+	require.Error(t, err)
+	_, err = webhooks.New(logger, webhooks.Options{URLs: []string{"example.com/hook"}})
+	require.Error(t, err)
+	d, err := webhooks.New(logger, webhooks.Options{URLs: []string{"https://example.com/hook"}})
+	require.NoError(t, err)
+	require.NoError(t, d.Close())
+	// Closing twice is a no-op.
+	require.NoError(t, d.Close())
+}
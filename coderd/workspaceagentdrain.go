@@ -0,0 +1,221 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"nhooyr.io/websocket"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+)
+
+// @Summary Wait for workspace agent drain request
+// @Description It accepts a WebSocket connection and sends a single drain
+// @Description request once a stop or delete build of the workspace is
+// @Description waiting for the agent to drain. Returns 404 if draining is
+// @Description disabled.
+// @ID wait-for-workspace-agent-drain-request
+// @Security CoderSessionToken
+// @Tags Agents
+// @Success 101
+// @Router /workspaceagents/me/drain [get]
+func (api *API) workspaceAgentDrain(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceAgent := httpmw.WorkspaceAgent(r)
+
+	// Builds don't wait for agents to drain without a grace period, so there
+	// is nothing to wait for.
+	if api.DeploymentValues.AgentDrainGracePeriod.Value() <= 0 {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "Draining workspace agents is disabled.",
+		})
+		return
+	}
+
+	// The agent scope does not allow reading provisioner jobs, and the
+	// builds checked here all belong to the agent's own workspace.
+	//nolint:gocritic // Agents can check whether their workspace is stopping.
+	sysCtx := dbauthz.AsSystemRestricted(ctx)
+	resource, err := api.Database.GetWorkspaceResourceByID(sysCtx, workspaceAgent.ResourceID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace resource.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	build, err := api.Database.GetWorkspaceBuildByJobID(sysCtx, resource.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	api.WebsocketWaitMutex.Lock()
+	api.WebsocketWaitGroup.Add(1)
+	api.WebsocketWaitMutex.Unlock()
+	defer api.WebsocketWaitGroup.Done()
+
+	ws, err := websocket.Accept(rw, r, nil)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to accept websocket.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	nconn := websocket.NetConn(ctx, ws, websocket.MessageText)
+	defer nconn.Close()
+
+	// Slurp all packets from the connection into io.Discard so pongs get sent
+	// by the websocket package.
+	go func() {
+		_, _ = io.Copy(io.Discard, nconn)
+	}()
+
+	updates := make(chan struct{}, 1)
+	cancelSubscribe, err := api.Pubsub.Subscribe(codersdk.WorkspaceNotifyChannel(build.WorkspaceID), func(_ context.Context, _ []byte) {
+		select {
+		case updates <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		_ = ws.Close(websocket.StatusInternalError, err.Error())
+		return
+	}
+	defer cancelSubscribe()
+
+	// Builds are not published when their job is updated, so the latest
+	// build is also checked periodically.
+	ticker := time.NewTicker(api.AgentConnectionUpdateFrequency)
+	defer ticker.Stop()
+
+	for {
+		drainBuild, _, ok, err := api.drainingBuild(sysCtx, build)
+		if err != nil {
+			_ = ws.Close(websocket.StatusInternalError, err.Error())
+			return
+		}
+		if ok {
+			err = json.NewEncoder(nconn).Encode(agentsdk.DrainRequest{
+				BuildID:     drainBuild.ID,
+				Transition:  codersdk.WorkspaceTransition(drainBuild.Transition),
+				GracePeriod: api.DeploymentValues.AgentDrainGracePeriod.Value(),
+			})
+			if err != nil {
+				_ = ws.Close(websocket.StatusInternalError, err.Error())
+				return
+			}
+			_ = ws.Close(websocket.StatusNormalClosure, "")
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-api.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-updates:
+		}
+	}
+}
+
+// drainingBuild returns the latest build of the workspace of build if it is a
+// stop, archive or delete build that is still waiting for the agents of build
+// to drain.
+func (api *API) drainingBuild(ctx context.Context, build database.WorkspaceBuild) (database.WorkspaceBuild, database.ProvisionerJob, bool, error) {
+	latestBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, build.WorkspaceID)
+	if err != nil {
+		return database.WorkspaceBuild{}, database.ProvisionerJob{}, false, xerrors.Errorf("get latest workspace build: %w", err)
+	}
+	if latestBuild.ID == build.ID || latestBuild.BuildNumber < build.BuildNumber {
+		return database.WorkspaceBuild{}, database.ProvisionerJob{}, false, nil
+	}
+	if latestBuild.Transition == database.WorkspaceTransitionStart {
+		return database.WorkspaceBuild{}, database.ProvisionerJob{}, false, nil
+	}
+	job, err := api.Database.GetProvisionerJobByID(ctx, latestBuild.JobID)
+	if err != nil {
+		return database.WorkspaceBuild{}, database.ProvisionerJob{}, false, xerrors.Errorf("get provisioner job: %w", err)
+	}
+	if job.StartedAt.Valid || job.CanceledAt.Valid || !job.NotBefore.Valid {
+		return database.WorkspaceBuild{}, database.ProvisionerJob{}, false, nil
+	}
+	return latestBuild, job, true, nil
+}
+
+// releaseDrainedBuild lets a provisioner acquire the stop or delete build that
+// is waiting for the agents of the build of workspaceAgent, once none of the
+// connected agents are still shutting down.
+func (api *API) releaseDrainedBuild(ctx context.Context, workspaceAgent database.WorkspaceAgent) error {
+	//nolint:gocritic // The agent scope does not allow updating provisioner jobs.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	resource, err := api.Database.GetWorkspaceResourceByID(ctx, workspaceAgent.ResourceID)
+	if err != nil {
+		return xerrors.Errorf("get workspace resource: %w", err)
+	}
+	build, err := api.Database.GetWorkspaceBuildByJobID(ctx, resource.JobID)
+	if err != nil {
+		return xerrors.Errorf("get workspace build: %w", err)
+	}
+	_, job, ok, err := api.drainingBuild(ctx, build)
+	if err != nil || !ok {
+		return err
+	}
+
+	resources, err := api.Database.GetWorkspaceResourcesByJobID(ctx, build.JobID)
+	if err != nil {
+		return xerrors.Errorf("get workspace resources: %w", err)
+	}
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	agents, err := api.Database.GetWorkspaceAgentsByResourceIDs(ctx, resourceIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("get workspace agents: %w", err)
+	}
+	for _, agent := range agents {
+		if agent.Status(api.AgentInactiveDisconnectTimeout).Status != database.WorkspaceAgentStatusConnected {
+			continue
+		}
+		switch agent.LifecycleState {
+		case database.WorkspaceAgentLifecycleStateShutdownTimeout,
+			database.WorkspaceAgentLifecycleStateShutdownError,
+			database.WorkspaceAgentLifecycleStateOff:
+		default:
+			// This agent is still draining.
+			return nil
+		}
+	}
+
+	err = api.Database.UpdateProvisionerJobNotBeforeByID(ctx, database.UpdateProvisionerJobNotBeforeByIDParams{
+		ID:        job.ID,
+		UpdatedAt: database.Now(),
+	})
+	if err != nil {
+		return xerrors.Errorf("update provisioner job: %w", err)
+	}
+	api.Logger.Debug(ctx, "agents drained, released workspace build",
+		slog.F("workspace_id", build.WorkspaceID),
+		slog.F("job_id", job.ID),
+	)
+	return nil
+}
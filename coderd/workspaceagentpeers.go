@@ -0,0 +1,206 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/google/uuid"
+	"nhooyr.io/websocket"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+)
+
+// @Summary List workspace agent peers
+// @Description Peers are the agents in the latest builds of the other
+// @Description workspaces owned by the owner of the authenticated agent's
+// @Description workspace.
+// @ID list-workspace-agent-peers
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Agents
+// @Success 200 {array} agentsdk.Peer
+// @Router /workspaceagents/me/peers [get]
+func (api *API) workspaceAgentPeers(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceAgent := httpmw.WorkspaceAgent(r)
+
+	workspace, err := api.Database.GetWorkspaceByAgentID(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// The agent scope only allows access to the agent's own workspace, so
+	// the workspaces of the owner are fetched as the system.
+	//nolint:gocritic // Agents can list the agents of their owner's workspaces.
+	sysCtx := dbauthz.AsSystemRestricted(ctx)
+	workspaces, err := api.Database.GetWorkspaces(sysCtx, database.GetWorkspacesParams{
+		OwnerID: workspace.OwnerID,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	peers := make([]agentsdk.Peer, 0)
+	for _, peerWorkspace := range workspaces {
+		if peerWorkspace.ID == workspace.ID {
+			continue
+		}
+		agents, err := api.Database.GetWorkspaceAgentsInLatestBuildByWorkspaceID(sysCtx, peerWorkspace.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching workspace agents.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		for _, agent := range agents {
+			peers = append(peers, agentsdk.Peer{
+				WorkspaceID:   peerWorkspace.ID,
+				WorkspaceName: peerWorkspace.Name,
+				AgentID:       agent.ID,
+				AgentName:     agent.Name,
+				Status:        codersdk.WorkspaceAgentStatus(agent.Status(api.AgentInactiveDisconnectTimeout).Status),
+			})
+		}
+	}
+	sort.Slice(peers, func(i, j int) bool {
+		if peers[i].WorkspaceName != peers[j].WorkspaceName {
+			return peers[i].WorkspaceName < peers[j].WorkspaceName
+		}
+		return peers[i].AgentName < peers[j].AgentName
+	})
+
+	httpapi.Write(ctx, rw, http.StatusOK, peers)
+}
+
+// @Summary Get connection info for workspace agent peer
+// @ID get-connection-info-for-workspace-agent-peer
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Agents
+// @Param workspaceagent path string true "Peer workspace agent ID" format(uuid)
+// @Success 200 {object} codersdk.WorkspaceAgentConnectionInfo
+// @Router /workspaceagents/me/peers/{workspaceagent}/connection [get]
+func (api *API) workspaceAgentPeerConnection(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if _, ok := api.workspaceAgentPeer(ctx, rw, r); !ok {
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.WorkspaceAgentConnectionInfo{
+		DERPMap:                  api.DERPMap(),
+		DisableDirectConnections: api.DeploymentValues.DERP.Config.BlockDirect.Value(),
+	})
+}
+
+// workspaceAgentPeerCoordinate is the same as workspaceAgentClientCoordinate,
+// but is authenticated by the agent token of a peer of the dialed agent.
+//
+// @Summary Coordinate workspace agent peer
+// @ID coordinate-workspace-agent-peer
+// @Security CoderSessionToken
+// @Tags Agents
+// @Param workspaceagent path string true "Peer workspace agent ID" format(uuid)
+// @Success 101
+// @Router /workspaceagents/me/peers/{workspaceagent}/coordinate [get]
+func (api *API) workspaceAgentPeerCoordinate(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	peer, ok := api.workspaceAgentPeer(ctx, rw, r)
+	if !ok {
+		return
+	}
+
+	api.WebsocketWaitMutex.Lock()
+	api.WebsocketWaitGroup.Add(1)
+	api.WebsocketWaitMutex.Unlock()
+	defer api.WebsocketWaitGroup.Done()
+
+	conn, err := websocket.Accept(rw, r, nil)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to accept websocket.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	ctx, wsNetConn := websocketNetConn(ctx, conn, websocket.MessageBinary)
+	defer wsNetConn.Close()
+
+	go httpapi.Heartbeat(ctx, conn)
+
+	defer conn.Close(websocket.StatusNormalClosure, "")
+	err = (*api.TailnetCoordinator.Load()).ServeClient(wsNetConn, uuid.New(), peer.ID)
+	if err != nil {
+		_ = conn.Close(websocket.StatusInternalError, err.Error())
+		return
+	}
+}
+
+// workspaceAgentPeer returns the agent in the workspaceagent path parameter
+// if it belongs to a workspace of the owner of the authenticated agent. A
+// not found response is written otherwise, so agents can't probe the agents
+// of other users.
+func (api *API) workspaceAgentPeer(ctx context.Context, rw http.ResponseWriter, r *http.Request) (database.WorkspaceAgent, bool) {
+	workspaceAgent := httpmw.WorkspaceAgent(r)
+	peerID, ok := httpmw.ParseUUIDParam(rw, r, "workspaceagent")
+	if !ok {
+		return database.WorkspaceAgent{}, false
+	}
+
+	workspace, err := api.Database.GetWorkspaceByAgentID(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace.",
+			Detail:  err.Error(),
+		})
+		return database.WorkspaceAgent{}, false
+	}
+
+	//nolint:gocritic // Agents can dial the agents of their owner's workspaces.
+	sysCtx := dbauthz.AsSystemRestricted(ctx)
+	peer, err := api.Database.GetWorkspaceAgentByID(sysCtx, peerID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return database.WorkspaceAgent{}, false
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace agent.",
+			Detail:  err.Error(),
+		})
+		return database.WorkspaceAgent{}, false
+	}
+	peerWorkspace, err := api.Database.GetWorkspaceByAgentID(sysCtx, peer.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return database.WorkspaceAgent{}, false
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace.",
+			Detail:  err.Error(),
+		})
+		return database.WorkspaceAgent{}, false
+	}
+	if peerWorkspace.OwnerID != workspace.OwnerID || peerWorkspace.Deleted {
+		httpapi.ResourceNotFound(rw)
+		return database.WorkspaceAgent{}, false
+	}
+	return peer, true
+}
@@ -0,0 +1,94 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceAgentPeers(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+	createWorkspace := func(owner *codersdk.Client) (codersdk.Workspace, string) {
+		authToken := uuid.NewString()
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionPlan:  echo.ProvisionComplete,
+			ProvisionApply: echo.ProvisionApplyWithAgent(authToken),
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, owner, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, owner, workspace.LatestBuild.ID)
+		return workspace, authToken
+	}
+	_, firstToken := createWorkspace(client)
+	second, secondToken := createWorkspace(client)
+	other, otherToken := createWorkspace(member)
+
+	firstClient := agentsdk.New(client.URL)
+	firstClient.SetSessionToken(firstToken)
+	for _, authToken := range []string{secondToken, otherToken} {
+		agentClient := agentsdk.New(client.URL)
+		agentClient.SetSessionToken(authToken)
+		agentCloser := agent.New(agent.Options{
+			Client: agentClient,
+			Logger: slogtest.Make(t, nil).Named("agent").Leveled(slog.LevelDebug),
+		})
+		t.Cleanup(func() {
+			_ = agentCloser.Close()
+		})
+	}
+	secondResources := coderdtest.AwaitWorkspaceAgents(t, client, second.ID)
+	otherResources := coderdtest.AwaitWorkspaceAgents(t, member, other.ID)
+
+	t.Run("List", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		peers, err := firstClient.Peers(ctx)
+		require.NoError(t, err)
+		require.Len(t, peers, 1)
+		require.Equal(t, second.ID, peers[0].WorkspaceID)
+		require.Equal(t, second.Name, peers[0].WorkspaceName)
+		require.Equal(t, secondResources[0].Agents[0].ID, peers[0].AgentID)
+		require.Equal(t, codersdk.WorkspaceAgentConnected, peers[0].Status)
+	})
+
+	t.Run("Dial", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		conn, err := firstClient.DialPeer(ctx, secondResources[0].Agents[0].ID, &codersdk.DialWorkspaceAgentOptions{
+			Logger: slogtest.Make(t, nil).Named("peer").Leveled(slog.LevelDebug),
+		})
+		require.NoError(t, err)
+		defer conn.Close()
+		require.True(t, conn.AwaitReachable(ctx))
+	})
+
+	t.Run("OtherOwner", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := firstClient.DialPeer(ctx, otherResources[0].Agents[0].ID, nil)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
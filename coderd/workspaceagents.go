@@ -119,6 +119,24 @@ func (api *API) workspaceAgentManifest(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	scripts, err := api.Database.GetWorkspaceAgentScriptsByAgentID(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace agent scripts.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	devcontainers, err := api.Database.GetWorkspaceAgentDevcontainersByAgentID(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace agent devcontainers.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	resource, err := api.Database.GetWorkspaceResourceByID(ctx, workspaceAgent.ResourceID)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -151,6 +169,23 @@ func (api *API) workspaceAgentManifest(rw http.ResponseWriter, r *http.Request)
 		})
 		return
 	}
+	secrets, err := api.workspaceAgentSecrets(ctx, workspaceAgent.ID, owner.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace agent secrets.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	dotfiles, err := api.workspaceAgentDotfiles(ctx, workspace)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace owner dotfiles.",
+			Detail:  err.Error(),
+		})
+		return
+	}
 
 	vscodeProxyURI := strings.ReplaceAll(api.AppHostname, "*",
 		fmt.Sprintf("%s://{{port}}--%s--%s--%s",
@@ -177,10 +212,73 @@ func (api *API) workspaceAgentManifest(rw http.ResponseWriter, r *http.Request)
 		ShutdownScript:           apiAgent.ShutdownScript,
 		ShutdownScriptTimeout:    time.Duration(apiAgent.ShutdownScriptTimeoutSeconds) * time.Second,
 		DisableDirectConnections: api.DeploymentValues.DERP.Config.BlockDirect.Value(),
+		DisableX11Forwarding:     api.DeploymentValues.DisableX11Forwarding.Value(),
 		Metadata:                 convertWorkspaceAgentMetadataDesc(metadata),
+		Scripts:                  convertWorkspaceAgentScripts(scripts),
+		Secrets:                  secrets,
+		Dotfiles:                 dotfiles,
+		Devcontainers:            convertWorkspaceAgentDevcontainers(devcontainers),
 	})
 }
 
+// workspaceAgentDotfiles returns the dotfiles of the workspace owner, or nil
+// if the owner hasn't configured dotfiles or the template disables them.
+func (api *API) workspaceAgentDotfiles(ctx context.Context, workspace database.Workspace) (*codersdk.UserDotfiles, error) {
+	//nolint:gocritic // The workspace agent can't read the template.
+	template, err := api.Database.GetTemplateByID(dbauthz.AsSystemRestricted(ctx), workspace.TemplateID)
+	if err != nil {
+		return nil, xerrors.Errorf("get template: %w", err)
+	}
+	if !template.ApplyDotfiles {
+		return nil, nil
+	}
+	dotfiles, err := api.Database.GetUserDotfiles(ctx, workspace.OwnerID)
+	if xerrors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("get user dotfiles: %w", err)
+	}
+	sdkDotfiles := convertUserDotfiles(dotfiles)
+	return &sdkDotfiles, nil
+}
+
+// workspaceAgentSecrets returns the decrypted secrets of the owner that are
+// referenced by the agent. Secrets the owner hasn't stored are left out.
+func (api *API) workspaceAgentSecrets(ctx context.Context, agentID uuid.UUID, ownerID uuid.UUID) (map[string]string, error) {
+	secrets := map[string]string{}
+	if api.UserSecretsCipher == nil {
+		return secrets, nil
+	}
+	agentSecrets, err := api.Database.GetWorkspaceAgentSecretsByAgentID(ctx, agentID)
+	if err != nil {
+		return nil, xerrors.Errorf("get agent secrets: %w", err)
+	}
+	if len(agentSecrets) == 0 {
+		return secrets, nil
+	}
+	userSecrets, err := api.Database.GetUserSecretsByUserID(ctx, ownerID)
+	if err != nil {
+		return nil, xerrors.Errorf("get user secrets: %w", err)
+	}
+	byName := make(map[string]database.UserSecret, len(userSecrets))
+	for _, secret := range userSecrets {
+		byName[secret.Name] = secret
+	}
+	for _, agentSecret := range agentSecrets {
+		secret, ok := byName[agentSecret.Name]
+		if !ok {
+			continue
+		}
+		value, err := api.UserSecretsCipher.Decrypt(secret.Value)
+		if err != nil {
+			return nil, xerrors.Errorf("decrypt secret %q: %w", secret.Name, err)
+		}
+		secrets[secret.Name] = string(value)
+	}
+	return secrets, nil
+}
+
 // @Summary Submit workspace agent startup
 // @ID submit-workspace-agent-startup
 // @Security CoderSessionToken
@@ -1262,6 +1360,7 @@ func convertApps(dbApps []database.WorkspaceApp) []codersdk.WorkspaceApp {
 			Icon:         dbApp.Icon,
 			Subdomain:    dbApp.Subdomain,
 			SharingLevel: codersdk.WorkspaceAppSharingLevel(dbApp.SharingLevel),
+			Auth:         codersdk.WorkspaceAppAuth(dbApp.Auth),
 			Healthcheck: codersdk.Healthcheck{
 				URL:       dbApp.HealthcheckUrl,
 				Interval:  dbApp.HealthcheckInterval,
@@ -1276,6 +1375,11 @@ func convertApps(dbApps []database.WorkspaceApp) []codersdk.WorkspaceApp {
 func convertWorkspaceAgentMetadataDesc(mds []database.WorkspaceAgentMetadatum) []codersdk.WorkspaceAgentMetadataDescription {
 	metadata := make([]codersdk.WorkspaceAgentMetadataDescription, 0)
 	for _, datum := range mds {
+		// Plugin metadata is registered by the agent itself, so it isn't
+		// part of the manifest.
+		if datum.Plugin {
+			continue
+		}
 		metadata = append(metadata, codersdk.WorkspaceAgentMetadataDescription{
 			DisplayName: datum.DisplayName,
 			Key:         datum.Key,
@@ -1287,6 +1391,17 @@ func convertWorkspaceAgentMetadataDesc(mds []database.WorkspaceAgentMetadatum) [
 	return metadata
 }
 
+func convertWorkspaceAgentDevcontainers(dbDevcontainers []database.WorkspaceAgentDevcontainer) []codersdk.WorkspaceAgentDevcontainer {
+	devcontainers := make([]codersdk.WorkspaceAgentDevcontainer, 0, len(dbDevcontainers))
+	for _, devcontainer := range dbDevcontainers {
+		devcontainers = append(devcontainers, codersdk.WorkspaceAgentDevcontainer{
+			WorkspaceFolder: devcontainer.WorkspaceFolder,
+			ConfigPath:      devcontainer.ConfigPath,
+		})
+	}
+	return devcontainers
+}
+
 func convertWorkspaceAgent(derpMap *tailcfg.DERPMap, coordinator tailnet.Coordinator, dbAgent database.WorkspaceAgent, apps []codersdk.WorkspaceApp, agentInactiveDisconnectTimeout time.Duration, agentFallbackTroubleshootingURL string) (codersdk.WorkspaceAgent, error) {
 	var envs map[string]string
 	if dbAgent.EnvironmentVariables.Valid {
@@ -1434,7 +1549,7 @@ func (api *API) workspaceAgentReportStats(rw http.ResponseWriter, r *http.Reques
 	)
 
 	if req.ConnectionCount > 0 {
-		activityBumpWorkspace(ctx, api.Logger.Named("activity_bump"), api.Database, workspace.ID)
+		activityBumpWorkspace(ctx, api.Logger.Named("activity_bump"), api.Database, *api.TemplateScheduleStore.Load(), workspace.ID)
 	}
 
 	now := database.Now()
@@ -1562,6 +1677,182 @@ func (api *API) workspaceAgentPostMetadata(rw http.ResponseWriter, r *http.Reque
 	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
 }
 
+// @Summary Submit workspace agent metadata plugins
+// @ID submit-workspace-agent-metadata-plugins
+// @Security CoderSessionToken
+// @Accept json
+// @Tags Agents
+// @Param request body agentsdk.PostMetadataPluginsRequest true "Workspace agent metadata plugins request"
+// @Success 204 "Success"
+// @Router /workspaceagents/me/metadata-plugins [post]
+// @x-apidocgen {"skip": true}
+func (api *API) workspaceAgentPostMetadataPlugins(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceAgent := httpmw.WorkspaceAgent(r)
+
+	var req agentsdk.PostMetadataPluginsRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	workspace, err := api.Database.GetWorkspaceByAgentID(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to get workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	metadata, err := api.Database.GetWorkspaceAgentMetadata(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	templateKeys := map[string]struct{}{}
+	for _, datum := range metadata {
+		if !datum.Plugin {
+			templateKeys[datum.Key] = struct{}{}
+		}
+	}
+
+	// Plugins are defined inside the workspace, so they are held to the same
+	// limits as the metadata of the template. The agent collects at most
+	// maxMetadata values, plugins included.
+	const (
+		maxMetadata  = 128
+		maxKeyLen    = 127
+		maxScriptLen = 65535
+	)
+	if len(templateKeys)+len(req.Plugins) > maxMetadata {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("At most %d metadata plugins can be registered.", maxMetadata-len(templateKeys)),
+			Detail:  fmt.Sprintf("The agent collects at most %d metadata values, and the template defines %d.", maxMetadata, len(templateKeys)),
+		})
+		return
+	}
+	var validations []codersdk.ValidationError
+	keys := make([]string, 0, len(req.Plugins))
+	for i, plugin := range req.Plugins {
+		field := fmt.Sprintf("plugins[%d]", i)
+		switch {
+		case plugin.Key == "" || len(plugin.Key) > maxKeyLen:
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".key",
+				Detail: fmt.Sprintf("Key must be between 1 and %d characters.", maxKeyLen),
+			})
+		case slices.Contains(keys, plugin.Key):
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".key",
+				Detail: fmt.Sprintf("Duplicate key %q.", plugin.Key),
+			})
+		default:
+			if _, ok := templateKeys[plugin.Key]; ok {
+				validations = append(validations, codersdk.ValidationError{
+					Field:  field + ".key",
+					Detail: fmt.Sprintf("Key %q is already used by the metadata of the template.", plugin.Key),
+				})
+			}
+		}
+		if len(plugin.DisplayName) > maxKeyLen {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".display_name",
+				Detail: fmt.Sprintf("Display name must be at most %d characters.", maxKeyLen),
+			})
+		}
+		if plugin.Script == "" || len(plugin.Script) > maxScriptLen {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field + ".script",
+				Detail: fmt.Sprintf("Script must be between 1 and %d characters.", maxScriptLen),
+			})
+		}
+		if plugin.Interval < 0 || plugin.Timeout < 0 {
+			validations = append(validations, codersdk.ValidationError{
+				Field:  field,
+				Detail: "Interval and timeout must not be negative.",
+			})
+		}
+		keys = append(keys, plugin.Key)
+	}
+	if len(validations) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid metadata plugins.",
+			Validations: validations,
+		})
+		return
+	}
+
+	err = api.Database.InTx(func(tx database.Store) error {
+		err := tx.DeleteWorkspaceAgentPluginMetadata(ctx, database.DeleteWorkspaceAgentPluginMetadataParams{
+			WorkspaceAgentID: workspaceAgent.ID,
+			Keys:             keys,
+		})
+		if err != nil {
+			return xerrors.Errorf("delete stale plugin metadata: %w", err)
+		}
+		for _, plugin := range req.Plugins {
+			err = tx.UpsertWorkspaceAgentPluginMetadata(ctx, database.UpsertWorkspaceAgentPluginMetadataParams{
+				WorkspaceAgentID: workspaceAgent.ID,
+				DisplayName:      plugin.DisplayName,
+				Key:              plugin.Key,
+				Script:           plugin.Script,
+				Timeout:          plugin.Timeout,
+				Interval:         plugin.Interval,
+			})
+			if err != nil {
+				return xerrors.Errorf("upsert plugin metadata %q: %w", plugin.Key, err)
+			}
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	api.Logger.Debug(
+		ctx, "registered metadata plugins",
+		slog.F("workspace_agent_id", workspaceAgent.ID),
+		slog.F("workspace_id", workspace.ID),
+		slog.F("keys", keys),
+	)
+
+	err = api.Pubsub.Publish(watchWorkspaceAgentMetadataChannel(workspaceAgent.ID), []byte{})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
+}
+
+// @Summary Get workspace agent metadata
+// @ID get-workspace-agent-metadata
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Agents
+// @Param workspaceagent path string true "Workspace agent ID" format(uuid)
+// @Success 200 {array} codersdk.WorkspaceAgentMetadata
+// @Router /workspaceagents/{workspaceagent}/metadata [get]
+func (api *API) workspaceAgentMetadata(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceAgent := httpmw.WorkspaceAgentParam(r)
+
+	metadata, err := api.Database.GetWorkspaceAgentMetadata(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace agent metadata.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	slices.SortFunc(metadata, func(a, b database.WorkspaceAgentMetadatum) int {
+		return slice.Ascending(a.Key, b.Key)
+	})
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceAgentMetadata(metadata))
+}
+
 // @Summary Watch for workspace agent metadata updates
 // @ID watch-for-workspace-agent-metadata-updates
 // @Security CoderSessionToken
@@ -1687,6 +1978,7 @@ func convertWorkspaceAgentMetadata(db []database.WorkspaceAgentMetadatum) []code
 				Script:      datum.Script,
 				Interval:    datum.Interval,
 				Timeout:     datum.Timeout,
+				Plugin:      datum.Plugin,
 			},
 		})
 	}
@@ -1769,6 +2061,17 @@ func (api *API) workspaceAgentReportLifecycle(rw http.ResponseWriter, r *http.Re
 		return
 	}
 
+	switch dbLifecycleState {
+	case database.WorkspaceAgentLifecycleStateShutdownTimeout,
+		database.WorkspaceAgentLifecycleStateShutdownError,
+		database.WorkspaceAgentLifecycleStateOff:
+		err = api.releaseDrainedBuild(ctx, workspaceAgent)
+		if err != nil {
+			// The build is still acquired once its drain deadline passes.
+			logger.Warn(ctx, "failed to release drained workspace build", slog.Error(err))
+		}
+	}
+
 	api.publishWorkspaceUpdate(ctx, workspace.ID)
 
 	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
@@ -2008,7 +2311,7 @@ func (api *API) workspaceAgentsGitAuth(rw http.ResponseWriter, r *http.Request)
 			if !valid {
 				continue
 			}
-			httpapi.Write(ctx, rw, http.StatusOK, formatGitAuthAccessToken(gitAuthConfig.Type, gitAuthLink.OAuthAccessToken))
+			httpapi.Write(ctx, rw, http.StatusOK, formatGitAuthAccessToken(gitAuthConfig.Type, gitAuthLink.OAuthAccessToken, gitAuthLink.OAuthExpiry))
 			return
 		}
 	}
@@ -2056,11 +2359,11 @@ func (api *API) workspaceAgentsGitAuth(rw http.ResponseWriter, r *http.Request)
 		})
 		return
 	}
-	httpapi.Write(ctx, rw, http.StatusOK, formatGitAuthAccessToken(gitAuthConfig.Type, gitAuthLink.OAuthAccessToken))
+	httpapi.Write(ctx, rw, http.StatusOK, formatGitAuthAccessToken(gitAuthConfig.Type, gitAuthLink.OAuthAccessToken, gitAuthLink.OAuthExpiry))
 }
 
 // Provider types have different username/password formats.
-func formatGitAuthAccessToken(typ codersdk.GitProvider, token string) agentsdk.GitAuthResponse {
+func formatGitAuthAccessToken(typ codersdk.GitProvider, token string, expiry time.Time) agentsdk.GitAuthResponse {
 	var resp agentsdk.GitAuthResponse
 	switch typ {
 	case codersdk.GitProviderGitLab:
@@ -2069,7 +2372,7 @@ func formatGitAuthAccessToken(typ codersdk.GitProvider, token string) agentsdk.G
 			Username: "oauth2",
 			Password: token,
 		}
-	case codersdk.GitProviderBitBucket:
+	case codersdk.GitProviderBitBucket, codersdk.GitProviderBitBucketServer:
 		// https://support.atlassian.com/bitbucket-cloud/docs/use-oauth-on-bitbucket-cloud/#Cloning-a-repository-with-an-access-token
 		resp = agentsdk.GitAuthResponse{
 			Username: "x-token-auth",
@@ -2080,6 +2383,7 @@ func formatGitAuthAccessToken(typ codersdk.GitProvider, token string) agentsdk.G
 			Username: token,
 		}
 	}
+	resp.Expiry = expiry
 	return resp
 }
 
@@ -2138,6 +2442,7 @@ func convertWorkspaceAgentLog(logEntry database.WorkspaceAgentLog) codersdk.Work
 		CreatedAt: logEntry.CreatedAt,
 		Output:    logEntry.Output,
 		Level:     codersdk.LogLevel(logEntry.Level),
+		Source:    codersdk.WorkspaceAgentLogSource(logEntry.Source),
 	}
 }
 
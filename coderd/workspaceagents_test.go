@@ -222,6 +222,7 @@ func TestWorkspaceAgentStartupLogs(t *testing.T) {
 				{
 					CreatedAt: database.Now(),
 					Output:    "testing2",
+					Source:    codersdk.WorkspaceAgentLogSourceShutdownScript,
 				},
 			},
 		})
@@ -240,7 +241,9 @@ func TestWorkspaceAgentStartupLogs(t *testing.T) {
 		require.NoError(t, ctx.Err())
 		require.Len(t, logChunk, 2) // No EOF.
 		require.Equal(t, "testing", logChunk[0].Output)
+		require.Equal(t, codersdk.WorkspaceAgentLogSourceStartupScript, logChunk[0].Source)
 		require.Equal(t, "testing2", logChunk[1].Output)
+		require.Equal(t, codersdk.WorkspaceAgentLogSourceShutdownScript, logChunk[1].Source)
 	})
 	t.Run("PublishesOnOverflow", func(t *testing.T) {
 		t.Parallel()
@@ -1169,6 +1172,124 @@ func TestWorkspaceAgent_Metadata(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestWorkspaceAgent_MetadataPlugins(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:         echo.ParseComplete,
+		ProvisionPlan: echo.ProvisionComplete,
+		ProvisionApply: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: []*proto.Resource{{
+						Name: "example",
+						Type: "aws_instance",
+						Agents: []*proto.Agent{{
+							Metadata: []*proto.Agent_Metadata{{
+								DisplayName: "Template Meta",
+								Key:         "template",
+								Script:      "echo hi",
+								Interval:    10,
+								Timeout:     3,
+							}},
+							Id: uuid.NewString(),
+							Auth: &proto.Agent_Token{
+								Token: authToken,
+							},
+						}},
+					}},
+				},
+			},
+		}},
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	build := coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	agentID := build.Resources[0].Agents[0].ID
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(authToken)
+
+	ctx := testutil.Context(t, testutil.WaitMedium)
+
+	plugin := codersdk.WorkspaceAgentMetadataDescription{
+		DisplayName: "Load",
+		Key:         "load",
+		Script:      "cat /proc/loadavg",
+		Interval:    5,
+		Timeout:     1,
+	}
+	err := agentClient.PostMetadataPlugins(ctx, agentsdk.PostMetadataPluginsRequest{
+		Plugins: []codersdk.WorkspaceAgentMetadataDescription{plugin},
+	})
+	require.NoError(t, err)
+	err = agentClient.PostMetadata(ctx, "load", agentsdk.PostMetadataRequest{
+		CollectedAt: time.Now(),
+		Value:       "0.42",
+	})
+	require.NoError(t, err)
+
+	metadata, err := client.WorkspaceAgentMetadata(ctx, agentID)
+	require.NoError(t, err)
+	require.Len(t, metadata, 2)
+	plugin.Plugin = true
+	require.Equal(t, plugin, metadata[0].Description)
+	require.Equal(t, "0.42", metadata[0].Result.Value)
+	require.Equal(t, "template", metadata[1].Description.Key)
+	require.False(t, metadata[1].Description.Plugin)
+
+	// Plugins aren't part of the manifest, the agent tracks them itself.
+	manifest, err := agentClient.Manifest(ctx)
+	require.NoError(t, err)
+	require.Len(t, manifest.Metadata, 1)
+	require.Equal(t, "template", manifest.Metadata[0].Key)
+
+	// Plugins can't replace the metadata of the template.
+	err = agentClient.PostMetadataPlugins(ctx, agentsdk.PostMetadataPluginsRequest{
+		Plugins: []codersdk.WorkspaceAgentMetadataDescription{{
+			Key:    "template",
+			Script: "echo hijacked",
+		}},
+	})
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	require.Len(t, apiErr.Validations, 1)
+	require.Equal(t, "plugins[0].key", apiErr.Validations[0].Field)
+
+	// Plugins share the metadata limit with the template.
+	tooMany := make([]codersdk.WorkspaceAgentMetadataDescription, 128)
+	for i := range tooMany {
+		tooMany[i] = codersdk.WorkspaceAgentMetadataDescription{
+			Key:    fmt.Sprintf("plugin-%d", i),
+			Script: "echo hi",
+		}
+	}
+	err = agentClient.PostMetadataPlugins(ctx, agentsdk.PostMetadataPluginsRequest{
+		Plugins: tooMany,
+	})
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	err = agentClient.PostMetadataPlugins(ctx, agentsdk.PostMetadataPluginsRequest{
+		Plugins: tooMany[:127],
+	})
+	require.NoError(t, err)
+
+	// Registering a new set of plugins removes the plugins that are gone.
+	err = agentClient.PostMetadataPlugins(ctx, agentsdk.PostMetadataPluginsRequest{})
+	require.NoError(t, err)
+	metadata, err = client.WorkspaceAgentMetadata(ctx, agentID)
+	require.NoError(t, err)
+	require.Len(t, metadata, 1)
+	require.Equal(t, "template", metadata[0].Description.Key)
+}
+
 func TestWorkspaceAgent_Startup(t *testing.T) {
 	t.Parallel()
 
@@ -1361,3 +1482,41 @@ func TestWorkspaceAgent_UpdatedDERP(t *testing.T) {
 	require.True(t, ok)
 	require.Equal(t, []int{2}, conn2.DERPMap().RegionIDs())
 }
+
+func TestWorkspaceAgent_SetBaseDERPMap(t *testing.T) {
+	t.Parallel()
+
+	client, closer, api := coderdtest.NewWithAPI(t, nil)
+	defer closer.Close()
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	updates, updatesCloser, err := agentsdk.New(client.URL).DERPMapUpdates(ctx)
+	require.NoError(t, err)
+	defer updatesCloser.Close()
+
+	select {
+	case update := <-updates:
+		require.NoError(t, update.Err)
+		require.Equal(t, api.BaseDERPMap().RegionIDs(), update.DERPMap.RegionIDs())
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the initial DERP map")
+	}
+
+	newDerpMap, _ := tailnettest.RunDERPAndSTUN(t)
+	newDerpMap.Regions[3] = newDerpMap.Regions[1]
+	delete(newDerpMap.Regions, 1)
+	newDerpMap.Regions[3].RegionID = 3
+	for _, node := range newDerpMap.Regions[3].Nodes {
+		node.RegionID = 3
+	}
+	api.SetBaseDERPMap(newDerpMap)
+	require.Equal(t, []int{3}, api.DERPMap().RegionIDs())
+
+	select {
+	case update := <-updates:
+		require.NoError(t, update.Err)
+		require.Equal(t, []int{3}, update.DERPMap.RegionIDs())
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the updated DERP map")
+	}
+}
@@ -0,0 +1,117 @@
+package coderd
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+)
+
+// @Summary Get scripts by workspace agent
+// @ID get-scripts-by-workspace-agent
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Agents
+// @Param workspaceagent path string true "Workspace agent ID" format(uuid)
+// @Success 200 {array} codersdk.WorkspaceAgentScript
+// @Router /workspaceagents/{workspaceagent}/scripts [get]
+func (api *API) workspaceAgentScripts(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceAgent := httpmw.WorkspaceAgentParam(r)
+
+	scripts, err := api.Database.GetWorkspaceAgentScriptsByAgentID(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace agent scripts.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceAgentScripts(scripts))
+}
+
+// @Summary Submit workspace agent script status
+// @ID submit-workspace-agent-script-status
+// @Security CoderSessionToken
+// @Accept json
+// @Tags Agents
+// @Param request body agentsdk.PostScriptStatusRequest true "Workspace agent script status request"
+// @Success 204 "Success"
+// @Router /workspaceagents/me/report-script-status [post]
+// @x-apidocgen {"skip": true}
+func (api *API) workspaceAgentReportScriptStatus(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceAgent := httpmw.WorkspaceAgent(r)
+
+	var req agentsdk.PostScriptStatusRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	status := database.WorkspaceAgentScriptStatus(req.Status)
+	if !status.Valid() {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid script status.",
+			Detail:  fmt.Sprintf("Invalid script status %q, must be be one of %q.", req.Status, database.AllWorkspaceAgentScriptStatusValues()),
+		})
+		return
+	}
+
+	workspace, err := api.Database.GetWorkspaceByAgentID(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to get workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	err = api.Database.UpdateWorkspaceAgentScriptStatus(ctx, database.UpdateWorkspaceAgentScriptStatusParams{
+		WorkspaceAgentID: workspaceAgent.ID,
+		Name:             req.Name,
+		Status:           status,
+		ExitCode:         req.ExitCode,
+		StartedAt:        sql.NullTime{Time: req.StartedAt, Valid: !req.StartedAt.IsZero()},
+		EndedAt:          sql.NullTime{Time: req.EndedAt, Valid: !req.EndedAt.IsZero()},
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	api.publishWorkspaceUpdate(ctx, workspace.ID)
+
+	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
+}
+
+func convertWorkspaceAgentScripts(dbScripts []database.WorkspaceAgentScript) []codersdk.WorkspaceAgentScript {
+	scripts := make([]codersdk.WorkspaceAgentScript, 0, len(dbScripts))
+	for _, script := range dbScripts {
+		apiScript := codersdk.WorkspaceAgentScript{
+			Name:            script.Name,
+			Script:          script.Script,
+			RunOnStart:      script.RunOnStart,
+			RunOnStop:       script.RunOnStop,
+			TimeoutSeconds:  script.TimeoutSeconds,
+			ContinueOnError: script.ContinueOnError,
+			Status:          codersdk.WorkspaceAgentScriptStatus(script.Status),
+			ExitCode:        script.ExitCode,
+		}
+		if script.StartedAt.Valid {
+			startedAt := script.StartedAt.Time
+			apiScript.StartedAt = &startedAt
+		}
+		if script.EndedAt.Valid {
+			endedAt := script.EndedAt.Time
+			apiScript.EndedAt = &endedAt
+		}
+		scripts = append(scripts, apiScript)
+	}
+	return scripts
+}
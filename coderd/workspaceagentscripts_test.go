@@ -0,0 +1,103 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceAgentScripts(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitMedium)
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:         echo.ParseComplete,
+		ProvisionPlan: echo.ProvisionComplete,
+		ProvisionApply: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: []*proto.Resource{{
+						Name: "example",
+						Type: "aws_instance",
+						Agents: []*proto.Agent{{
+							Id: uuid.NewString(),
+							Auth: &proto.Agent_Token{
+								Token: authToken,
+							},
+							Scripts: []*proto.Agent_Script{{
+								Name:       "install",
+								Script:     "echo install",
+								RunOnStart: true,
+							}, {
+								Name:           "cleanup",
+								Script:         "echo cleanup",
+								RunOnStop:      true,
+								TimeoutSeconds: 60,
+							}},
+						}},
+					}},
+				},
+			},
+		}},
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	build := coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	agentID := build.Resources[0].Agents[0].ID
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(authToken)
+	manifest, err := agentClient.Manifest(ctx)
+	require.NoError(t, err)
+	require.Len(t, manifest.Scripts, 2)
+	require.Equal(t, "install", manifest.Scripts[0].Name)
+	require.True(t, manifest.Scripts[0].RunOnStart)
+	require.Equal(t, "cleanup", manifest.Scripts[1].Name)
+	require.EqualValues(t, 60, manifest.Scripts[1].TimeoutSeconds)
+
+	startedAt := database.Now()
+	err = agentClient.PostScriptStatus(ctx, agentsdk.PostScriptStatusRequest{
+		Name:      "install",
+		Status:    codersdk.WorkspaceAgentScriptStatusError,
+		ExitCode:  2,
+		StartedAt: startedAt,
+		EndedAt:   startedAt.Add(time.Second),
+	})
+	require.NoError(t, err)
+
+	scripts, err := client.WorkspaceAgentScripts(ctx, agentID)
+	require.NoError(t, err)
+	require.Len(t, scripts, 2)
+	require.Equal(t, codersdk.WorkspaceAgentScriptStatusError, scripts[0].Status)
+	require.EqualValues(t, 2, scripts[0].ExitCode)
+	require.NotNil(t, scripts[0].StartedAt)
+	require.WithinDuration(t, startedAt, *scripts[0].StartedAt, time.Millisecond)
+	require.NotNil(t, scripts[0].EndedAt)
+	require.Equal(t, codersdk.WorkspaceAgentScriptStatusPending, scripts[1].Status)
+	require.Nil(t, scripts[1].StartedAt)
+
+	err = agentClient.PostScriptStatus(ctx, agentsdk.PostScriptStatusRequest{
+		Name:   "install",
+		Status: "bananas",
+	})
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/coreos/go-oidc/v3/oidc"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/coderd/apikey"
@@ -62,24 +63,7 @@ func (api *API) workspaceApplicationAuth(rw http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// Get the redirect URI from the query parameters and parse it.
-	redirectURI := r.URL.Query().Get(workspaceapps.RedirectURIQueryParam)
-	if redirectURI == "" {
-		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-			Message: "Missing redirect_uri query parameter.",
-		})
-		return
-	}
-	u, err := url.Parse(redirectURI)
-	if err != nil {
-		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-			Message: "Invalid redirect_uri query parameter.",
-			Detail:  err.Error(),
-		})
-		return
-	}
-
-	u.Scheme, err = api.ValidWorkspaceAppHostname(ctx, u.Host, ValidWorkspaceAppHostnameOpts{
+	u, ok := api.parseWorkspaceAppRedirectURI(ctx, rw, r.URL.Query().Get(workspaceapps.RedirectURIQueryParam), ValidWorkspaceAppHostnameOpts{
 		// Allow all hosts except primary access URL since we don't need app
 		// tokens on the primary dashboard URL.
 		AllowPrimaryAccessURL: false,
@@ -87,18 +71,7 @@ func (api *API) workspaceApplicationAuth(rw http.ResponseWriter, r *http.Request
 		AllowProxyAccessURL:   true,
 		AllowProxyWildcard:    true,
 	})
-	if err != nil {
-		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-			Message: "Failed to verify redirect_uri query parameter.",
-			Detail:  err.Error(),
-		})
-		return
-	}
-	if u.Scheme == "" {
-		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-			Message: "Invalid redirect_uri.",
-			Detail:  "The redirect_uri query parameter must be the primary wildcard app hostname, a workspace proxy access URL or a workspace proxy wildcard app hostname.",
-		})
+	if !ok {
 		return
 	}
 
@@ -146,6 +119,195 @@ func (api *API) workspaceApplicationAuth(rw http.ResponseWriter, r *http.Request
 	http.Redirect(rw, r, u.String(), http.StatusSeeOther)
 }
 
+// WorkspaceAppsOIDCConfig is the OIDC provider that users who are not signed
+// in to Coder log in with to use subdomain apps with OIDC auth.
+type WorkspaceAppsOIDCConfig struct {
+	httpmw.OAuth2Config
+
+	// Verifier checks that ID tokens are issued by the provider for the
+	// client ID of Coder.
+	Verifier *oidc.IDTokenVerifier
+	// EmailDomain are the domains the emails of users must match. If empty,
+	// every user of the provider may log in.
+	EmailDomain []string
+}
+
+// workspaceApplicationOIDC is redirected to by the subdomain app handler for
+// apps with OIDC auth. Users that are signed in to Coder continue to the app
+// auth endpoint, everybody else logs in with the workspace app OIDC provider.
+//
+// @Summary Redirect to workspace app OIDC login
+// @ID redirect-to-workspace-app-oidc-login
+// @Tags Applications
+// @Param redirect_uri query string false "Redirect destination"
+// @Success 307
+// @Router /applications/oidc [get]
+func (api *API) workspaceApplicationOIDC(rw http.ResponseWriter, r *http.Request) {
+	q := url.Values{}
+	u := &url.URL{Path: "/api/v2/applications/auth-redirect"}
+	_, signedIn := httpmw.APIKeyOptional(r)
+	if signedIn || api.WorkspaceAppsOIDCConfig == nil || !api.WorkspaceAppsOIDCEnabled.Load() {
+		// Without a provider, or without a license for it, users have to log
+		// in to Coder like for any other app.
+		q.Set(workspaceapps.RedirectURIQueryParam, r.URL.Query().Get(workspaceapps.RedirectURIQueryParam))
+	} else {
+		// The OAuth2 middleware keeps the redirect in a cookie until the
+		// provider calls back.
+		u.Path = "/api/v2/applications/oidc/callback"
+		q.Set("redirect", r.URL.Query().Get(workspaceapps.RedirectURIQueryParam))
+	}
+	u.RawQuery = q.Encode()
+
+	http.Redirect(rw, r, u.String(), http.StatusTemporaryRedirect)
+}
+
+// workspaceApplicationOIDCCallback signs a session for the subdomain app the
+// user logged in to with the workspace app OIDC provider, and redirects back
+// to the app with it. The session is only valid for the host of that app.
+//
+// @Summary Workspace app OIDC callback
+// @ID workspace-app-oidc-callback
+// @Tags Applications
+// @Param code query string true "Code"
+// @Param state query string true "State"
+// @Success 307
+// @Router /applications/oidc/callback [get]
+func (api *API) workspaceApplicationOIDCCallback(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		state = httpmw.OAuth2(r)
+	)
+
+	if !api.WorkspaceAppsOIDCEnabled.Load() {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Workspace app OIDC login requires an enterprise license.",
+		})
+		return
+	}
+
+	u, ok := api.parseWorkspaceAppRedirectURI(ctx, rw, state.Redirect, ValidWorkspaceAppHostnameOpts{
+		// OIDC auth is only supported for subdomain apps.
+		AllowPrimaryWildcard: true,
+		AllowProxyWildcard:   true,
+	})
+	if !ok {
+		return
+	}
+
+	rawIDToken, ok := state.Token.Extra("id_token").(string)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "id_token not found in response payload. Ensure your OIDC callback is configured correctly!",
+		})
+		return
+	}
+	idToken, err := api.WorkspaceAppsOIDCConfig.Verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to verify OIDC token.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified *bool  `json:"email_verified"`
+	}
+	err = idToken.Claims(&claims)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to extract OIDC claims.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if claims.Email == "" {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Your OIDC provider did not return an email address.",
+		})
+		return
+	}
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: fmt.Sprintf("Verify the %q email address on your OIDC provider to authenticate!", claims.Email),
+		})
+		return
+	}
+	if domains := api.WorkspaceAppsOIDCConfig.EmailDomain; len(domains) > 0 {
+		ok = false
+		for _, domain := range domains {
+			if strings.HasSuffix(strings.ToLower(claims.Email), strings.ToLower(domain)) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+				Message: fmt.Sprintf("Your email %q is not in domains %q !", claims.Email, domains),
+			})
+			return
+		}
+	}
+
+	session, err := api.AppSecurityKey.SignOIDCSession(workspaceapps.OIDCSessionPayload{
+		Subject:   idToken.Subject,
+		Email:     claims.Email,
+		AppHost:   u.Host,
+		ExpiresAt: database.Now().Add(api.DeploymentValues.SessionDuration.Value()),
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to sign OIDC session.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	q := u.Query()
+	q.Set(workspaceapps.SubdomainProxyOIDCSessionParam, session)
+	u.RawQuery = q.Encode()
+	http.Redirect(rw, r, u.String(), http.StatusTemporaryRedirect)
+}
+
+// parseWorkspaceAppRedirectURI parses the URI that the app auth endpoints
+// redirect back to, and forces the scheme of the access URL it belongs to.
+// If the URI is not valid, an error is written to the response writer and
+// false is returned.
+func (api *API) parseWorkspaceAppRedirectURI(ctx context.Context, rw http.ResponseWriter, redirectURI string, opts ValidWorkspaceAppHostnameOpts) (*url.URL, bool) {
+	if redirectURI == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Missing redirect_uri query parameter.",
+		})
+		return nil, false
+	}
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid redirect_uri query parameter.",
+			Detail:  err.Error(),
+		})
+		return nil, false
+	}
+
+	u.Scheme, err = api.ValidWorkspaceAppHostname(ctx, u.Host, opts)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to verify redirect_uri query parameter.",
+			Detail:  err.Error(),
+		})
+		return nil, false
+	}
+	if u.Scheme == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid redirect_uri.",
+			Detail:  "The redirect_uri query parameter must be the URL of a workspace app.",
+		})
+		return nil, false
+	}
+
+	return u, true
+}
+
 type ValidWorkspaceAppHostnameOpts struct {
 	AllowPrimaryAccessURL bool
 	AllowPrimaryWildcard  bool
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/xerrors"
@@ -34,11 +35,14 @@ type DBTokenProvider struct {
 	OAuth2Configs                 *httpmw.OAuth2Configs
 	WorkspaceAgentInactiveTimeout time.Duration
 	SigningKey                    SecurityKey
+	// OIDCEnabled is true if apps with OIDC auth accept users that logged in
+	// with the workspace app OIDC provider.
+	OIDCEnabled *atomic.Bool
 }
 
 var _ SignedTokenProvider = &DBTokenProvider{}
 
-func NewDBTokenProvider(log slog.Logger, accessURL *url.URL, authz rbac.Authorizer, db database.Store, cfg *codersdk.DeploymentValues, oauth2Cfgs *httpmw.OAuth2Configs, workspaceAgentInactiveTimeout time.Duration, signingKey SecurityKey) SignedTokenProvider {
+func NewDBTokenProvider(log slog.Logger, accessURL *url.URL, authz rbac.Authorizer, db database.Store, cfg *codersdk.DeploymentValues, oauth2Cfgs *httpmw.OAuth2Configs, workspaceAgentInactiveTimeout time.Duration, signingKey SecurityKey, oidcEnabled *atomic.Bool) SignedTokenProvider {
 	if workspaceAgentInactiveTimeout == 0 {
 		workspaceAgentInactiveTimeout = 1 * time.Minute
 	}
@@ -52,6 +56,7 @@ func NewDBTokenProvider(log slog.Logger, accessURL *url.URL, authz rbac.Authoriz
 		OAuth2Configs:                 oauth2Cfgs,
 		WorkspaceAgentInactiveTimeout: workspaceAgentInactiveTimeout,
 		SigningKey:                    signingKey,
+		OIDCEnabled:                   oidcEnabled,
 	}
 }
 
@@ -112,6 +117,11 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 	if dbReq.AppURL != nil {
 		token.AppURL = dbReq.AppURL.String()
 	}
+	token.RateLimit = dbReq.AppRateLimit
+	token.RewritePaths = dbReq.AppRewritePaths
+	if apiKey != nil {
+		token.RequesterID = apiKey.UserID
+	}
 
 	// Verify the user has access to the app.
 	authed, err := p.authorizeRequest(r.Context(), authz, dbReq)
@@ -119,6 +129,18 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 		WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "verify authz")
 		return nil, "", false
 	}
+	oidcAuth := dbReq.AppAuth == database.WorkspaceAppAuthOidc && p.OIDCEnabled.Load()
+	if !authed && apiKey == nil && oidcAuth && issueReq.OIDCSession != "" {
+		// Users that are not signed in to Coder may use subdomain apps with
+		// OIDC auth after logging in with the workspace app OIDC provider.
+		var session OIDCSessionPayload
+		session, authed = p.authorizeOIDCSession(ctx, issueReq)
+		if authed {
+			// The token belongs to the OIDC user, not the workspace owner.
+			token.UserID = OIDCUserID(session.Subject)
+			token.OIDCSubject = session.Subject
+		}
+	}
 	if !authed {
 		if apiKey != nil {
 			// The request has a valid API key but insufficient permissions.
@@ -179,6 +201,12 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 		// accept redirect URIs from the primary access URL or any other host.
 		u := *p.DashboardURL
 		u.Path = "/api/v2/applications/auth-redirect"
+		if oidcAuth && appReq.AccessMethod == AccessMethodSubdomain {
+			// This endpoint sends users that are signed in to Coder on to the
+			// app auth endpoint, and everybody else to the workspace app OIDC
+			// provider.
+			u.Path = "/api/v2/applications/oidc"
+		}
 		q := u.Query()
 		q.Add(RedirectURIQueryParam, redirectURI.String())
 		u.RawQuery = q.Encode()
@@ -187,6 +215,26 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 		return nil, "", false
 	}
 
+	if dbReq.WorkspaceRenamed {
+		// Send users to the app URL with the current workspace name, so
+		// bookmarks keep working after a workspace is renamed.
+		issueReq.AppRequest = appReq
+		issueReq.AppRequest.WorkspaceNameOrID = dbReq.Workspace.Name
+		appBaseURL, err := issueReq.AppBaseURL()
+		if err != nil {
+			WriteWorkspaceApp500(p.Logger, p.DashboardURL, rw, r, &appReq, err, "get app base URL")
+			return nil, "", false
+		}
+		redirectURI := *appBaseURL
+		if issueReq.AppPath != "" {
+			redirectURI.Path = issueReq.AppPath
+		}
+		redirectURI.RawQuery = issueReq.AppQuery
+
+		http.Redirect(rw, r, redirectURI.String(), http.StatusTemporaryRedirect)
+		return nil, "", false
+	}
+
 	// Check that the agent is online.
 	agentStatus := dbReq.Agent.Status(p.WorkspaceAgentInactiveTimeout)
 	if agentStatus.Status != database.WorkspaceAgentStatusConnected {
@@ -218,6 +266,25 @@ func (p *DBTokenProvider) Issue(ctx context.Context, rw http.ResponseWriter, r *
 	return &token, tokenStr, true
 }
 
+// authorizeOIDCSession returns the session and true if the request has a
+// valid session of a user that logged in with the workspace app OIDC provider,
+// and the session was issued for the host of the subdomain app.
+func (p *DBTokenProvider) authorizeOIDCSession(ctx context.Context, issueReq IssueTokenRequest) (OIDCSessionPayload, bool) {
+	if issueReq.AppRequest.AccessMethod != AccessMethodSubdomain {
+		return OIDCSessionPayload{}, false
+	}
+	session, err := p.SigningKey.VerifyOIDCSession(issueReq.OIDCSession)
+	if err != nil {
+		p.Logger.Debug(ctx, "invalid workspace app OIDC session", slog.Error(err))
+		return OIDCSessionPayload{}, false
+	}
+	appBaseURL, err := issueReq.AppBaseURL()
+	if err != nil {
+		return OIDCSessionPayload{}, false
+	}
+	return session, session.AppHost == appBaseURL.Host
+}
+
 func (p *DBTokenProvider) authorizeRequest(ctx context.Context, roles *httpmw.Authorization, dbReq *databaseRequest) (bool, error) {
 	accessMethod := dbReq.AccessMethod
 	if accessMethod == "" {
@@ -258,7 +325,8 @@ func (p *DBTokenProvider) authorizeRequest(ctx context.Context, roles *httpmw.Au
 	}
 
 	// Figure out which RBAC resource to check. For terminals we use execution
-	// instead of application connect.
+	// instead of application connect. Both include the groups the workspace
+	// is shared with, but only groups with full access may use terminals.
 	var (
 		rbacAction   rbac.Action = rbac.ActionCreate
 		rbacResource rbac.Object = dbReq.Workspace.ApplicationConnectRBAC()
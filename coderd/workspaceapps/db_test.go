@@ -20,6 +20,8 @@ import (
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/agent"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/workspaceapps"
 	"github.com/coder/coder/codersdk"
@@ -39,6 +41,7 @@ func Test_ResolveRequest(t *testing.T) {
 		appNamePublic     = "app-public"
 		appNameInvalidURL = "app-invalid-url"
 		appNameUnhealthy  = "app-unhealthy"
+		appNameOIDC       = "app-oidc"
 
 		// This agent will never connect, so it will never become "connected".
 		agentNameUnhealthy    = "agent-unhealthy"
@@ -81,6 +84,8 @@ func Test_ResolveRequest(t *testing.T) {
 	t.Cleanup(func() {
 		_ = closer.Close()
 	})
+	// This is set by the enterprise license.
+	api.WorkspaceAppsOIDCEnabled.Store(true)
 
 	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitMedium)
 	defer cancel()
@@ -144,6 +149,14 @@ func Test_ResolveRequest(t *testing.T) {
 											Threshold: 1,
 										},
 									},
+									{
+										Slug:         appNameOIDC,
+										DisplayName:  appNameOIDC,
+										SharingLevel: proto.AppSharingLevel_OWNER,
+										Url:          appURL,
+										Subdomain:    true,
+										Auth:         "oidc",
+									},
 								},
 							},
 							{
@@ -768,6 +781,165 @@ func Test_ResolveRequest(t *testing.T) {
 		require.Equal(t, "/some-path", redirectURI.Path)
 	})
 
+	t.Run("RenamedWorkspace", func(t *testing.T) {
+		t.Parallel()
+
+		// Renaming the workspace would break the other tests, so only the
+		// redirect of a rename is inserted.
+		ctx := testutil.Context(t, testutil.WaitShort)
+		//nolint:gocritic // The test inserts the redirect directly.
+		_, err := api.Database.UpsertWorkspaceNameRedirect(dbauthz.AsSystemRestricted(ctx), database.UpsertWorkspaceNameRedirectParams{
+			OwnerID:     me.ID,
+			Name:        "old-name",
+			WorkspaceID: workspace.ID,
+			CreatedAt:   database.Now(),
+			ExpiresAt:   database.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		for _, c := range []struct {
+			name         string
+			accessMethod workspaceapps.AccessMethod
+			basePath     string
+			statusCode   int
+		}{
+			{
+				name:         "Subdomain",
+				accessMethod: workspaceapps.AccessMethodSubdomain,
+				basePath:     "/",
+				statusCode:   http.StatusTemporaryRedirect,
+			},
+			{
+				// Path app URLs with previous names are not redirected.
+				name:         "Path",
+				accessMethod: workspaceapps.AccessMethodPath,
+				basePath:     "/app",
+				statusCode:   http.StatusNotFound,
+			},
+		} {
+			c := c
+			t.Run(c.name, func(t *testing.T) {
+				t.Parallel()
+
+				req := workspaceapps.Request{
+					AccessMethod:      c.accessMethod,
+					BasePath:          c.basePath,
+					UsernameOrID:      me.Username,
+					WorkspaceNameOrID: "old-name",
+					AgentNameOrID:     agentName,
+					AppSlugOrPort:     appNameOwner,
+				}
+
+				rw := httptest.NewRecorder()
+				r := httptest.NewRequest("GET", "/some-path?foo=bar", nil)
+				r.Header.Set(codersdk.SessionTokenHeader, client.SessionToken())
+
+				token, ok := workspaceapps.ResolveRequest(rw, r, workspaceapps.ResolveRequestOptions{
+					Logger:              api.Logger,
+					SignedTokenProvider: api.WorkspaceAppsProvider,
+					DashboardURL:        api.AccessURL,
+					PathAppBaseURL:      api.AccessURL,
+					AppHostname:         api.AppHostname,
+					AppRequest:          req,
+					AppPath:             "/some-path",
+					AppQuery:            "foo=bar",
+				})
+				require.False(t, ok)
+				require.Nil(t, token)
+
+				w := rw.Result()
+				defer w.Body.Close()
+				require.Equal(t, c.statusCode, w.StatusCode)
+				if c.statusCode != http.StatusTemporaryRedirect {
+					return
+				}
+
+				loc, err := w.Location()
+				require.NoError(t, err)
+
+				appHost := fmt.Sprintf("%s--%s--%s--%s", req.AppSlugOrPort, req.AgentNameOrID, workspace.Name, req.UsernameOrID)
+				require.Equal(t, strings.Replace(api.AppHostname, "*", appHost, 1), loc.Host)
+				require.Equal(t, "/some-path", loc.Path)
+				require.Equal(t, "foo=bar", loc.RawQuery)
+			})
+		}
+	})
+
+	t.Run("OIDCAuth", func(t *testing.T) {
+		t.Parallel()
+
+		req := workspaceapps.Request{
+			AccessMethod:      workspaceapps.AccessMethodSubdomain,
+			BasePath:          "/",
+			UsernameOrID:      me.Username,
+			WorkspaceNameOrID: workspace.Name,
+			AgentNameOrID:     agentName,
+			AppSlugOrPort:     appNameOIDC,
+		}
+		appHost := fmt.Sprintf("%s--%s--%s--%s", req.AppSlugOrPort, req.AgentNameOrID, req.WorkspaceNameOrID, req.UsernameOrID)
+		host := strings.Replace(api.AppHostname, "*", appHost, 1)
+
+		resolve := func(t *testing.T, session string) (*workspaceapps.SignedToken, *http.Response) {
+			t.Helper()
+
+			rw := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/", nil)
+			if session != "" {
+				r.AddCookie(&http.Cookie{
+					Name:  codersdk.DevURLOIDCSessionCookie,
+					Value: session,
+				})
+			}
+			token, ok := workspaceapps.ResolveRequest(rw, r, workspaceapps.ResolveRequestOptions{
+				Logger:              api.Logger,
+				SignedTokenProvider: api.WorkspaceAppsProvider,
+				DashboardURL:        api.AccessURL,
+				PathAppBaseURL:      api.AccessURL,
+				AppHostname:         api.AppHostname,
+				AppRequest:          req,
+			})
+			w := rw.Result()
+			t.Cleanup(func() {
+				_ = w.Body.Close()
+			})
+			require.Equal(t, ok, token != nil)
+			return token, w
+		}
+		sign := func(t *testing.T, appHost string) string {
+			t.Helper()
+
+			session, err := api.AppSecurityKey.SignOIDCSession(workspaceapps.OIDCSessionPayload{
+				Subject:   "stakeholder",
+				Email:     "stakeholder@example.com",
+				AppHost:   appHost,
+				ExpiresAt: time.Now().Add(time.Hour),
+			})
+			require.NoError(t, err)
+			return session
+		}
+
+		// Users that are not signed in are sent to the OIDC login.
+		token, w := resolve(t, "")
+		require.Nil(t, token)
+		require.Equal(t, http.StatusSeeOther, w.StatusCode)
+		loc, err := w.Location()
+		require.NoError(t, err)
+		require.Equal(t, "/api/v2/applications/oidc", loc.Path)
+
+		// Sessions of other apps are rejected.
+		token, w = resolve(t, sign(t, "other"+host))
+		require.Nil(t, token)
+		require.Equal(t, http.StatusSeeOther, w.StatusCode)
+
+		token, _ = resolve(t, sign(t, host))
+		require.NotNil(t, token)
+		require.Equal(t, workspaceapps.OIDCUserID("stakeholder"), token.UserID)
+		require.NotEqual(t, me.ID, token.UserID)
+		require.Equal(t, "stakeholder", token.OIDCSubject)
+		require.Equal(t, uuid.Nil, token.RequesterID)
+		require.Equal(t, workspace.ID, token.WorkspaceID)
+	})
+
 	t.Run("UnhealthyAgent", func(t *testing.T) {
 		t.Parallel()
 
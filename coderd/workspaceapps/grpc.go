@@ -0,0 +1,124 @@
+package workspaceapps
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/xerrors"
+)
+
+// gRPC status codes returned to clients when the request can't be proxied.
+// See: https://github.com/grpc/grpc/blob/master/doc/statuscodes.md
+const (
+	// grpcStatusResourceExhausted is returned when the client is rate limited.
+	grpcStatusResourceExhausted = 8
+	// grpcStatusUnavailable is returned when the app cannot be reached.
+	grpcStatusUnavailable = 14
+)
+
+// IsGRPCRequest returns true if the request is a gRPC request. gRPC requires
+// HTTP/2 end to end, so these requests must be proxied to the app over HTTP/2
+// rather than the HTTP/1.1 transport used for other app requests.
+func IsGRPCRequest(r *http.Request) bool {
+	if r.ProtoMajor != 2 {
+		return false
+	}
+	contentType := r.Header.Get("Content-Type")
+	return contentType == "application/grpc" ||
+		strings.HasPrefix(contentType, "application/grpc+") ||
+		strings.HasPrefix(contentType, "application/grpc;")
+}
+
+// WriteGRPCUnavailable writes a trailers-only gRPC response with the
+// UNAVAILABLE status. gRPC clients can't parse the HTML error pages returned
+// for other app requests, so proxy errors must be reported this way instead.
+func WriteGRPCUnavailable(rw http.ResponseWriter, msg string) {
+	writeGRPCStatus(rw, grpcStatusUnavailable, msg)
+}
+
+// WriteGRPCResourceExhausted writes a trailers-only gRPC response with the
+// RESOURCE_EXHAUSTED status, which gRPC clients treat as a rate limit.
+func WriteGRPCResourceExhausted(rw http.ResponseWriter, msg string) {
+	writeGRPCStatus(rw, grpcStatusResourceExhausted, msg)
+}
+
+func writeGRPCStatus(rw http.ResponseWriter, code int, msg string) {
+	rw.Header().Set("Content-Type", "application/grpc")
+	rw.Header().Set("Grpc-Status", strconv.Itoa(code))
+	rw.Header().Set("Grpc-Message", msg)
+	rw.WriteHeader(http.StatusOK)
+}
+
+// GRPCPassthroughTransport proxies gRPC requests to apps over HTTP/2 and all
+// other requests over the wrapped transport. Apps served over plain HTTP are
+// dialed with h2c (HTTP/2 with prior knowledge), and apps served over HTTPS
+// negotiate HTTP/2 with ALPN.
+type GRPCPassthroughTransport struct {
+	transport http.RoundTripper
+	h2c       *http2.Transport
+	h2        *http2.Transport
+}
+
+// NewGRPCPassthroughTransport wraps transport so gRPC requests are proxied over
+// HTTP/2 connections made with dial.
+func NewGRPCPassthroughTransport(transport http.RoundTripper, dial func(ctx context.Context, network, addr string) (net.Conn, error)) *GRPCPassthroughTransport {
+	return &GRPCPassthroughTransport{
+		transport: transport,
+		h2c: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				return dial(ctx, network, addr)
+			},
+		},
+		h2: &http2.Transport{
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+				conn, err := dial(ctx, network, addr)
+				if err != nil {
+					return nil, err
+				}
+				// We intentionally don't verify the certificate chain here,
+				// for the same reasons as the HTTP/1.1 app transport: many
+				// apps use self-signed certs.
+				cfg = cfg.Clone()
+				cfg.MinVersion = tls.VersionTLS12
+				//nolint:gosec
+				cfg.InsecureSkipVerify = true
+				tlsConn := tls.Client(conn, cfg)
+				err = tlsConn.HandshakeContext(ctx)
+				if err != nil {
+					_ = conn.Close()
+					return nil, xerrors.Errorf("tls handshake: %w", err)
+				}
+				return tlsConn, nil
+			},
+		},
+	}
+}
+
+func (t *GRPCPassthroughTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !IsGRPCRequest(req) {
+		return t.transport.RoundTrip(req)
+	}
+	if req.URL.Scheme == "https" {
+		return t.h2.RoundTrip(req)
+	}
+	return t.h2c.RoundTrip(req)
+}
+
+// CloseIdleConnections closes idle connections of the wrapped transport and
+// the HTTP/2 transports.
+func (t *GRPCPassthroughTransport) CloseIdleConnections() {
+	type closeIdler interface {
+		CloseIdleConnections()
+	}
+	if c, ok := t.transport.(closeIdler); ok {
+		c.CloseIdleConnections()
+	}
+	t.h2c.CloseIdleConnections()
+	t.h2.CloseIdleConnections()
+}
@@ -0,0 +1,41 @@
+package workspaceapps_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/workspaceapps"
+)
+
+func TestIsGRPCRequest(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name        string
+		protoMajor  int
+		contentType string
+		expected    bool
+	}{
+		{name: "GRPC", protoMajor: 2, contentType: "application/grpc", expected: true},
+		{name: "GRPCProto", protoMajor: 2, contentType: "application/grpc+proto", expected: true},
+		{name: "GRPCParams", protoMajor: 2, contentType: "application/grpc; charset=utf-8", expected: true},
+		{name: "GRPCWeb", protoMajor: 2, contentType: "application/grpc-web", expected: false},
+		{name: "HTTP1", protoMajor: 1, contentType: "application/grpc", expected: false},
+		{name: "JSON", protoMajor: 2, contentType: "application/json", expected: false},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.ProtoMajor = c.protoMajor
+			req.Header.Set("Content-Type", c.contentType)
+			require.Equal(t, c.expected, workspaceapps.IsGRPCRequest(req))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package workspaceapps
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const (
+	// forwardedPrefixHeader tells path-based apps that rewrite paths the path
+	// they are served from, so they can generate correct URLs themselves.
+	forwardedPrefixHeader = "X-Forwarded-Prefix"
+	// maxRewriteHTMLBytes is the largest HTML document that is rewritten.
+	// Larger documents are passed through unchanged.
+	maxRewriteHTMLBytes = 10 << 20
+)
+
+// htmlRootRelativeURL matches attributes with URLs that are relative to the
+// root of the host, e.g. href="/static/app.css". Protocol-relative URLs like
+// src="//cdn.example.com" don't match.
+var htmlRootRelativeURL = regexp.MustCompile(`(?i)(\s(?:href|src|action)\s*=\s*["'])/[^/]`)
+
+// pathRewriter rewrites the responses of a path-based app so redirects and
+// links stay under the path the app is served from. Apps are usually written
+// to be served from the root of a host, so without this a redirect to "/login"
+// would leave the app and go to the Coder dashboard.
+type pathRewriter struct {
+	// prefix is the path the app is served from without a trailing slash,
+	// e.g. "/@user/workspace/apps/app".
+	prefix string
+	appURL *url.URL
+}
+
+func newPathRewriter(basePath string, appURL *url.URL) *pathRewriter {
+	return &pathRewriter{
+		prefix: strings.TrimSuffix(basePath, "/"),
+		appURL: appURL,
+	}
+}
+
+// rewriteRequest prepares a request to the app for its response to be
+// rewritten.
+func (p *pathRewriter) rewriteRequest(r *http.Request) {
+	r.Header.Set(forwardedPrefixHeader, p.prefix)
+	// Compressed responses can't be rewritten.
+	r.Header.Del("Accept-Encoding")
+}
+
+// rewriteResponse rewrites the redirect and HTML body of a response from the
+// app.
+func (p *pathRewriter) rewriteResponse(res *http.Response) error {
+	if location := res.Header.Get("Location"); location != "" {
+		res.Header.Set("Location", p.rewriteLocation(location))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if mediaType != "text/html" {
+		return nil
+	}
+	if encoding := res.Header.Get("Content-Encoding"); encoding != "" && encoding != "identity" {
+		return nil
+	}
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxRewriteHTMLBytes+1))
+	if err != nil {
+		return xerrors.Errorf("read HTML body: %w", err)
+	}
+	if len(body) > maxRewriteHTMLBytes {
+		res.Body = &multiReadCloser{
+			Reader: io.MultiReader(bytes.NewReader(body), res.Body),
+			Closer: res.Body,
+		}
+		return nil
+	}
+	_ = res.Body.Close()
+
+	body = p.rewriteHTML(body)
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	res.ContentLength = int64(len(body))
+	res.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+// rewriteLocation prefixes redirects to the root of the host or to the app URL
+// with the path the app is served from. Relative redirects and redirects to
+// other hosts are left alone.
+func (p *pathRewriter) rewriteLocation(location string) string {
+	u, err := url.Parse(location)
+	if err != nil {
+		return location
+	}
+	if u.IsAbs() {
+		if !strings.EqualFold(u.Host, p.appURL.Host) {
+			return location
+		}
+		u.Scheme = ""
+		u.User = nil
+		u.Host = ""
+		if u.Path == "" {
+			u.Path = "/"
+		}
+	} else if u.Host != "" || !strings.HasPrefix(u.Path, "/") {
+		return location
+	}
+	if p.hasPrefix(u.Path) {
+		return u.String()
+	}
+
+	u.Path = p.prefix + u.Path
+	u.RawPath = ""
+	return u.String()
+}
+
+// rewriteHTML prefixes root-relative URLs in href, src and action attributes
+// with the path the app is served from. This includes the <base> tag, which
+// single-page apps commonly set to "/".
+func (p *pathRewriter) rewriteHTML(body []byte) []byte {
+	matches := htmlRootRelativeURL.FindAllSubmatchIndex(body, -1)
+	if len(matches) == 0 {
+		return body
+	}
+	rewritten := make([]byte, 0, len(body)+len(matches)*len(p.prefix))
+	last := 0
+	for _, match := range matches {
+		// The first group ends where the URL starts.
+		urlStart := match[3]
+		rewritten = append(rewritten, body[last:urlStart]...)
+		if !p.hasPrefixBytes(body[urlStart:]) {
+			rewritten = append(rewritten, p.prefix...)
+		}
+		last = urlStart
+	}
+	return append(rewritten, body[last:]...)
+}
+
+// hasPrefix returns true if the path is already under the path the app is
+// served from.
+func (p *pathRewriter) hasPrefix(path string) bool {
+	return path == p.prefix || strings.HasPrefix(path, p.prefix+"/")
+}
+
+// hasPrefixBytes is like hasPrefix for a URL at the start of an HTML
+// attribute value.
+func (p *pathRewriter) hasPrefixBytes(value []byte) bool {
+	if !bytes.HasPrefix(value, []byte(p.prefix)) {
+		return false
+	}
+	if len(value) == len(p.prefix) {
+		return true
+	}
+	return strings.IndexByte(`/"'?#`, value[len(p.prefix)]) != -1
+}
+
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,97 @@
+package workspaceapps
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathRewriter(t *testing.T) {
+	t.Parallel()
+
+	appURL, err := url.Parse("http://127.0.0.1:3000")
+	require.NoError(t, err)
+	rewriter := newPathRewriter("/@user/workspace/apps/app/", appURL)
+
+	t.Run("Request", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := http.NewRequest(http.MethodGet, "http://127.0.0.1:3000/", nil)
+		require.NoError(t, err)
+		r.Header.Set("Accept-Encoding", "gzip")
+		rewriter.rewriteRequest(r)
+		require.Equal(t, "/@user/workspace/apps/app", r.Header.Get("X-Forwarded-Prefix"))
+		require.Empty(t, r.Header.Get("Accept-Encoding"))
+	})
+
+	t.Run("Location", func(t *testing.T) {
+		t.Parallel()
+
+		for location, expected := range map[string]string{
+			"/login":                                 "/@user/workspace/apps/app/login",
+			"/login?next=%2F#top":                    "/@user/workspace/apps/app/login?next=%2F#top",
+			"/@user/workspace/apps/app/login":        "/@user/workspace/apps/app/login",
+			"http://127.0.0.1:3000":                  "/@user/workspace/apps/app/",
+			"http://127.0.0.1:3000/login":            "/@user/workspace/apps/app/login",
+			"login":                                  "login",
+			"../login":                               "../login",
+			"//example.com/login":                    "//example.com/login",
+			"https://example.com/login":              "https://example.com/login",
+			"http://127.0.0.1:3001/login":            "http://127.0.0.1:3001/login",
+			"/@user/workspace/apps/application/page": "/@user/workspace/apps/app/@user/workspace/apps/application/page",
+		} {
+			require.Equal(t, expected, rewriter.rewriteLocation(location), location)
+		}
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		t.Parallel()
+
+		body := `<html><head><base href="/"><link rel="stylesheet" href="/app.css"></head>` +
+			`<body><script src='/app.js'></script><script src="//cdn.example.com/lib.js"></script>` +
+			`<a href="/@user/workspace/apps/app/page">page</a><a href="page">page</a>` +
+			`<form action="/submit"></form><img src="https://example.com/logo.png"></body></html>`
+		res := &http.Response{
+			Header: http.Header{
+				"Content-Type": []string{"text/html; charset=utf-8"},
+				"Location":     []string{"/"},
+			},
+			Body: io.NopCloser(strings.NewReader(body)),
+		}
+		err := rewriter.rewriteResponse(res)
+		require.NoError(t, err)
+		require.Equal(t, "/@user/workspace/apps/app/", res.Header.Get("Location"))
+
+		rewritten, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, `<html><head><base href="/@user/workspace/apps/app/"><link rel="stylesheet" href="/@user/workspace/apps/app/app.css"></head>`+
+			`<body><script src='/@user/workspace/apps/app/app.js'></script><script src="//cdn.example.com/lib.js"></script>`+
+			`<a href="/@user/workspace/apps/app/page">page</a><a href="page">page</a>`+
+			`<form action="/@user/workspace/apps/app/submit"></form><img src="https://example.com/logo.png"></body></html>`, string(rewritten))
+		require.EqualValues(t, len(rewritten), res.ContentLength)
+	})
+
+	t.Run("NotHTML", func(t *testing.T) {
+		t.Parallel()
+
+		for _, header := range []http.Header{
+			{"Content-Type": []string{"application/json"}},
+			{"Content-Type": []string{"text/html"}, "Content-Encoding": []string{"gzip"}},
+		} {
+			body := `{"href": "/api"} <a href="/api">`
+			res := &http.Response{
+				Header: header,
+				Body:   io.NopCloser(strings.NewReader(body)),
+			}
+			err := rewriter.rewriteResponse(res)
+			require.NoError(t, err)
+			unchanged, err := io.ReadAll(res.Body)
+			require.NoError(t, err)
+			require.Equal(t, body, string(unchanged))
+		}
+	})
+}
@@ -62,6 +62,9 @@ func ResolveRequest(rw http.ResponseWriter, r *http.Request, opts ResolveRequest
 		AppPath:        opts.AppPath,
 		AppQuery:       opts.AppQuery,
 	}
+	if cookie, err := r.Cookie(codersdk.DevURLOIDCSessionCookie); err == nil {
+		issueReq.OIDCSession = cookie.Value
+	}
 
 	token, tokenStr, ok := opts.SignedTokenProvider.Issue(r.Context(), rw, r, issueReq)
 	if !ok {
@@ -3,6 +3,7 @@ package workspaceapps
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"net/http/httputil"
@@ -15,6 +16,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/xerrors"
 	"nhooyr.io/websocket"
 
 	"cdr.dev/slog"
@@ -33,6 +35,11 @@ const (
 	// conflict with query parameters that users may use.
 	//nolint:gosec
 	SubdomainProxyAPIKeyParam = "coder_application_connect_api_key_35e783"
+	// SubdomainProxyOIDCSessionParam is the query parameter the signed
+	// session of a user that logged in with the workspace app OIDC provider
+	// is passed to the app host in, like SubdomainProxyAPIKeyParam.
+	//nolint:gosec
+	SubdomainProxyOIDCSessionParam = "coder_application_oidc_session_9c41e2"
 	// appLogoutHostname is the hostname to use for the logout redirect. When
 	// the dashboard logs out, it will redirect to this subdomain of the app
 	// hostname, and the server will remove the cookie and redirect to the main
@@ -112,9 +119,14 @@ type Server struct {
 
 	AgentProvider  AgentProvider
 	StatsCollector *StatsCollector
+	// Replicas is used to route all requests of a browser to a subdomain app
+	// through the same replica. Sticky sessions are disabled if nil.
+	Replicas *ReplicaSet
 
 	websocketWaitMutex sync.Mutex
 	websocketWaitGroup sync.WaitGroup
+
+	rateLimiter appRateLimiter
 }
 
 // Close waits for all reconnecting-pty WebSocket connections to drain before
@@ -231,20 +243,71 @@ func (s *Server) handleAPIKeySmuggling(rw http.ResponseWriter, r *http.Request,
 		Secure:   s.SecureAuthCookie,
 	})
 
-	// Strip the query parameter.
+	redirectWithoutQueryParam(rw, r, SubdomainProxyAPIKeyParam)
+	return false
+}
+
+// handleOIDCSessionSmuggling is called by the subdomain handler to process the
+// signed session of a user that logged in with the workspace app OIDC
+// provider in the query parameters, like handleAPIKeySmuggling.
+//
+// Unlike API keys, the cookie is only set on the current host because the
+// session is only valid for the app it was issued for.
+func (s *Server) handleOIDCSessionSmuggling(rw http.ResponseWriter, r *http.Request) bool {
+	ctx := r.Context()
+
+	signedSession := r.URL.Query().Get(SubdomainProxyOIDCSessionParam)
+	if signedSession == "" {
+		return true
+	}
+
+	session, err := s.AppSecurityKey.VerifyOIDCSession(signedSession)
+	if err == nil && session.AppHost != r.Host {
+		err = xerrors.Errorf("session was issued for host %q", session.AppHost)
+	}
+	if err != nil {
+		s.Logger.Debug(ctx, "could not verify smuggled workspace app OIDC session", slog.Error(err))
+		site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+			Status:      http.StatusBadRequest,
+			Title:       "Bad Request",
+			Description: "Could not verify OIDC session. Please remove the query parameter and try again.",
+			// Retry is disabled because the user needs to remove the query
+			// parameter before they try again.
+			RetryEnabled: false,
+			DashboardURL: s.DashboardURL.String(),
+		})
+		return false
+	}
+
+	http.SetCookie(rw, &http.Cookie{
+		Name:     codersdk.DevURLOIDCSessionCookie,
+		Value:    signedSession,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   s.SecureAuthCookie,
+	})
+
+	redirectWithoutQueryParam(rw, r, SubdomainProxyOIDCSessionParam)
+	return false
+}
+
+// redirectWithoutQueryParam redirects to the current path without the given
+// query parameter.
+func redirectWithoutQueryParam(rw http.ResponseWriter, r *http.Request, param string) {
 	path := r.URL.Path
 	if path == "" {
 		path = "/"
 	}
 	q := r.URL.Query()
-	q.Del(SubdomainProxyAPIKeyParam)
+	q.Del(param)
 	rawQuery := q.Encode()
 	if rawQuery != "" {
-		path += "?" + q.Encode()
+		path += "?" + rawQuery
 	}
 
 	http.Redirect(rw, r, path, http.StatusSeeOther)
-	return false
 }
 
 // workspaceAppsProxyPath proxies requests to a workspace application
@@ -391,6 +454,12 @@ func (s *Server) HandleSubdomain(middlewares ...func(http.Handler) http.Handler)
 				if !s.handleAPIKeySmuggling(rw, r, AccessMethodSubdomain) {
 					return
 				}
+				if !s.handleOIDCSessionSmuggling(rw, r) {
+					return
+				}
+				if !s.routeToReplica(rw, r) {
+					return
+				}
 
 				token, ok := ResolveRequest(rw, r, ResolveRequestOptions{
 					Logger:              s.Logger,
@@ -484,6 +553,10 @@ func (s *Server) proxyWorkspaceApp(rw http.ResponseWriter, r *http.Request, appT
 		return
 	}
 
+	if !s.allowRequest(rw, r, appToken) {
+		return
+	}
+
 	appURL, err := url.Parse(appToken.AppURL)
 	if err != nil {
 		site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
@@ -541,6 +614,10 @@ func (s *Server) proxyWorkspaceApp(rw http.ResponseWriter, r *http.Request, appT
 
 	proxy, release, err := s.AgentProvider.ReverseProxy(appURL, s.DashboardURL, appToken.AgentID)
 	if err != nil {
+		if IsGRPCRequest(r) {
+			WriteGRPCUnavailable(rw, "Could not connect to workspace agent: "+err.Error())
+			return
+		}
 		site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
 			Status:       http.StatusBadGateway,
 			Title:        "Bad Gateway",
@@ -552,6 +629,18 @@ func (s *Server) proxyWorkspaceApp(rw http.ResponseWriter, r *http.Request, appT
 	}
 	defer release()
 
+	if IsGRPCRequest(r) {
+		// gRPC streams messages in both directions, so responses must be
+		// flushed to the client as soon as they are written.
+		proxy.FlushInterval = -1
+	}
+
+	var rewriter *pathRewriter
+	if appToken.RewritePaths && appToken.AccessMethod == AccessMethodPath {
+		rewriter = newPathRewriter(appToken.BasePath, appURL)
+		rewriter.rewriteRequest(r)
+	}
+
 	proxy.ModifyResponse = func(r *http.Response) error {
 		r.Header.Del(httpmw.AccessControlAllowOriginHeader)
 		r.Header.Del(httpmw.AccessControlAllowCredentialsHeader)
@@ -569,6 +658,9 @@ func (s *Server) proxyWorkspaceApp(rw http.ResponseWriter, r *http.Request, appT
 				r.Header.Add(httpmw.VaryHeader, value)
 			}
 		}
+		if rewriter != nil {
+			return rewriter.rewriteResponse(r)
+		}
 		return nil
 	}
 
@@ -592,17 +684,83 @@ func (s *Server) proxyWorkspaceApp(rw http.ResponseWriter, r *http.Request, appT
 	// end span so we don't get long lived trace data
 	tracing.EndHTTPSpan(r, http.StatusOK, trace.SpanFromContext(ctx))
 
-	report := newStatsReportFromSignedToken(appToken)
-	s.collectStats(report)
-	defer func() {
-		// We must use defer here because ServeHTTP may panic.
-		report.SessionEndedAt = database.Now()
+	// Users that logged in with the workspace app OIDC provider don't have a
+	// Coder account, so their sessions aren't recorded in the app stats.
+	if appToken.OIDCSubject == "" {
+		report := newStatsReportFromSignedToken(appToken)
 		s.collectStats(report)
-	}()
+		defer func() {
+			// We must use defer here because ServeHTTP may panic.
+			report.SessionEndedAt = database.Now()
+			s.collectStats(report)
+		}()
+	}
+
+	if s.StatsCollector != nil && s.StatsCollector.AccessLogsEnabled() {
+		sw := &tracing.StatusWriter{ResponseWriter: rw}
+		rw = sw
+		accessLog := newAccessLogFromSignedToken(appToken, database.Now(), path)
+		defer func() {
+			accessLog.Latency = database.Now().Sub(accessLog.Time)
+			accessLog.StatusCode = sw.Status
+			switch {
+			case sw.Hijacked:
+				accessLog.StatusCode = http.StatusSwitchingProtocols
+			case accessLog.StatusCode == 0:
+				// Nothing was written, which the server turns into an empty
+				// 200 response.
+				accessLog.StatusCode = http.StatusOK
+			}
+			s.StatsCollector.CollectAccessLog(accessLog)
+		}()
+	}
 
 	proxy.ServeHTTP(rw, r)
 }
 
+// allowRequest enforces the rate limit of the app. If the requester has made
+// too many requests, it writes a 429 response and returns false.
+func (s *Server) allowRequest(rw http.ResponseWriter, r *http.Request, appToken SignedToken) bool {
+	if !appToken.RateLimit.Enabled() {
+		return true
+	}
+
+	requester := appToken.RequesterID.String()
+	if appToken.RequesterID == uuid.Nil {
+		ip, err := httpmw.ExtractRealIPAddress(s.RealIPConfig, r)
+		if err != nil {
+			requester = r.RemoteAddr
+		} else {
+			requester = ip.String()
+		}
+	}
+	allowed, retryAfter := s.rateLimiter.Allow(rateLimitKey{
+		AgentID:       appToken.AgentID,
+		AppSlugOrPort: appToken.AppSlugOrPort,
+		Requester:     requester,
+	}, appToken.RateLimit)
+	if allowed {
+		return true
+	}
+
+	// Retry-After is in whole seconds, so round up to avoid clients retrying
+	// before the bucket has refilled.
+	retryAfterSeconds := int(math.Ceil(retryAfter.Seconds()))
+	rw.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+	if IsGRPCRequest(r) {
+		WriteGRPCResourceExhausted(rw, "Too many requests to the application, try again later.")
+		return false
+	}
+	site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+		Status:       http.StatusTooManyRequests,
+		Title:        "Too Many Requests",
+		Description:  fmt.Sprintf("You have made too many requests to this application. Try again in %d seconds.", retryAfterSeconds),
+		RetryEnabled: true,
+		DashboardURL: s.DashboardURL.String(),
+	})
+	return false
+}
+
 // workspaceAgentPTY spawns a PTY and pipes it over a WebSocket.
 // This is used for the web terminal.
 //
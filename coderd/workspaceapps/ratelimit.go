@@ -0,0 +1,129 @@
+package workspaceapps
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// rateLimiterGCInterval is how often idle rate limiters are removed.
+	rateLimiterGCInterval = time.Minute
+	// rateLimiterIdleTimeout is how long a rate limiter must be unused before
+	// it's removed. A removed limiter starts with a full bucket when it's
+	// recreated, so this must be long enough for any limiter to refill.
+	rateLimiterIdleTimeout = 10 * time.Minute
+)
+
+// RateLimit is the rate limit applied to each user of a workspace app. Users
+// get a token bucket that holds Burst requests and refills at
+// RequestsPerMinute.
+type RateLimit struct {
+	// RequestsPerMinute is the number of requests per minute each user may
+	// make to the app. Zero disables rate limiting.
+	RequestsPerMinute int32 `json:"requests_per_minute"`
+	// Burst is the number of requests each user may make in a burst. Zero
+	// uses RequestsPerMinute.
+	Burst int32 `json:"burst"`
+}
+
+// Enabled returns true if requests to the app should be rate limited.
+func (l RateLimit) Enabled() bool {
+	return l.RequestsPerMinute > 0
+}
+
+func (l RateLimit) limit() rate.Limit {
+	return rate.Limit(float64(l.RequestsPerMinute) / time.Minute.Seconds())
+}
+
+func (l RateLimit) burst() int {
+	if l.Burst > 0 {
+		return int(l.Burst)
+	}
+	return int(l.RequestsPerMinute)
+}
+
+// rateLimitKey identifies the token bucket of a requester for an app. Users
+// that are signed in to Coder are identified by their ID, everyone else by
+// their IP address.
+type rateLimitKey struct {
+	AgentID       uuid.UUID
+	AppSlugOrPort string
+	Requester     string
+}
+
+type rateLimiterEntry struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+// appRateLimiter holds the token buckets of workspace app requesters. The zero
+// value is ready to use.
+type appRateLimiter struct {
+	// now is used in tests to control the time, and defaults to time.Now.
+	now func() time.Time
+
+	mu       sync.Mutex
+	limiters map[rateLimitKey]*rateLimiterEntry
+	lastGC   time.Time
+}
+
+// Allow takes a token from the bucket of key. If the bucket is empty, it
+// returns false and how long the requester should wait before retrying.
+func (l *appRateLimiter) Allow(key rateLimitKey, limit RateLimit) (bool, time.Duration) {
+	if !limit.Enabled() {
+		return true, 0
+	}
+
+	now := time.Now()
+	if l.now != nil {
+		now = l.now()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limiters == nil {
+		l.limiters = make(map[rateLimitKey]*rateLimiterEntry)
+		l.lastGC = now
+	}
+	if now.Sub(l.lastGC) >= rateLimiterGCInterval {
+		for k, entry := range l.limiters {
+			if now.Sub(entry.lastUsed) >= rateLimiterIdleTimeout {
+				delete(l.limiters, k)
+			}
+		}
+		l.lastGC = now
+	}
+
+	entry, ok := l.limiters[key]
+	if !ok {
+		entry = &rateLimiterEntry{
+			limiter: rate.NewLimiter(limit.limit(), limit.burst()),
+		}
+		l.limiters[key] = entry
+	}
+	entry.lastUsed = now
+	// The rate limit of the app may have changed since the limiter was
+	// created, e.g. after the workspace was rebuilt with a new template
+	// version.
+	if entry.limiter.Limit() != limit.limit() {
+		entry.limiter.SetLimitAt(now, limit.limit())
+	}
+	if entry.limiter.Burst() != limit.burst() {
+		entry.limiter.SetBurstAt(now, limit.burst())
+	}
+
+	reservation := entry.limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Minute
+	}
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		// Return the token, the request isn't going to be served.
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
@@ -0,0 +1,114 @@
+package workspaceapps
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	newLimiter := func() (*appRateLimiter, *time.Time) {
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		return &appRateLimiter{
+			now: func() time.Time { return now },
+		}, &now
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		l, _ := newLimiter()
+		key := rateLimitKey{AgentID: uuid.New(), AppSlugOrPort: "app", Requester: "me"}
+		for i := 0; i < 100; i++ {
+			ok, _ := l.Allow(key, RateLimit{})
+			require.True(t, ok)
+		}
+		require.Empty(t, l.limiters)
+	})
+
+	t.Run("Burst", func(t *testing.T) {
+		t.Parallel()
+
+		l, now := newLimiter()
+		limit := RateLimit{RequestsPerMinute: 60, Burst: 3}
+		key := rateLimitKey{AgentID: uuid.New(), AppSlugOrPort: "app", Requester: "me"}
+		for i := 0; i < 3; i++ {
+			ok, _ := l.Allow(key, limit)
+			require.True(t, ok)
+		}
+		ok, retryAfter := l.Allow(key, limit)
+		require.False(t, ok)
+		require.Equal(t, time.Second, retryAfter)
+
+		// Other requesters and apps have their own buckets.
+		ok, _ = l.Allow(rateLimitKey{AgentID: key.AgentID, AppSlugOrPort: "app", Requester: "you"}, limit)
+		require.True(t, ok)
+		ok, _ = l.Allow(rateLimitKey{AgentID: key.AgentID, AppSlugOrPort: "other", Requester: "me"}, limit)
+		require.True(t, ok)
+
+		// One token is added every second.
+		*now = now.Add(time.Second)
+		ok, _ = l.Allow(key, limit)
+		require.True(t, ok)
+		ok, _ = l.Allow(key, limit)
+		require.False(t, ok)
+	})
+
+	t.Run("DefaultBurst", func(t *testing.T) {
+		t.Parallel()
+
+		l, _ := newLimiter()
+		limit := RateLimit{RequestsPerMinute: 5}
+		key := rateLimitKey{AgentID: uuid.New(), AppSlugOrPort: "app", Requester: "me"}
+		for i := 0; i < 5; i++ {
+			ok, _ := l.Allow(key, limit)
+			require.True(t, ok)
+		}
+		ok, retryAfter := l.Allow(key, limit)
+		require.False(t, ok)
+		require.Equal(t, 12*time.Second, retryAfter)
+	})
+
+	t.Run("LimitChanged", func(t *testing.T) {
+		t.Parallel()
+
+		l, _ := newLimiter()
+		key := rateLimitKey{AgentID: uuid.New(), AppSlugOrPort: "app", Requester: "me"}
+		ok, _ := l.Allow(key, RateLimit{RequestsPerMinute: 1})
+		require.True(t, ok)
+		ok, retryAfter := l.Allow(key, RateLimit{RequestsPerMinute: 1})
+		require.False(t, ok)
+		require.Equal(t, time.Minute, retryAfter)
+
+		// The bucket refills at the new rate.
+		ok, retryAfter = l.Allow(key, RateLimit{RequestsPerMinute: 60})
+		require.False(t, ok)
+		require.Equal(t, time.Second, retryAfter)
+	})
+
+	t.Run("GC", func(t *testing.T) {
+		t.Parallel()
+
+		l, now := newLimiter()
+		limit := RateLimit{RequestsPerMinute: 60}
+		idle := rateLimitKey{AgentID: uuid.New(), AppSlugOrPort: "app", Requester: "idle"}
+		active := rateLimitKey{AgentID: idle.AgentID, AppSlugOrPort: "app", Requester: "active"}
+		ok, _ := l.Allow(idle, limit)
+		require.True(t, ok)
+
+		*now = now.Add(rateLimiterIdleTimeout - time.Second)
+		ok, _ = l.Allow(active, limit)
+		require.True(t, ok)
+		require.Len(t, l.limiters, 2)
+
+		*now = now.Add(rateLimiterGCInterval)
+		ok, _ = l.Allow(active, limit)
+		require.True(t, ok)
+		require.Len(t, l.limiters, 1)
+		require.Contains(t, l.limiters, active)
+	})
+}
@@ -0,0 +1,179 @@
+package workspaceapps
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/site"
+)
+
+// replicaForwardedHeader is set on requests that were forwarded to the replica
+// named in the replica cookie, so they aren't forwarded again. Its value is
+// signed with the app security key, which is shared by the replicas, so
+// clients can't set it to skip the routing.
+const replicaForwardedHeader = "Coder-App-Replica-Forwarded"
+
+// ReplicaSet is the set of replicas that serve the same app hostname. It is
+// used to route all requests of a browser to a subdomain app through the same
+// replica when sticky sessions are enabled.
+type ReplicaSet struct {
+	self uuid.UUID
+
+	mu        sync.RWMutex
+	peers     map[uuid.UUID]*url.URL
+	transport http.RoundTripper
+}
+
+// NewReplicaSet creates a replica set for the replica with the given ID. It
+// has no peers until SetPeers is called.
+func NewReplicaSet(self uuid.UUID) *ReplicaSet {
+	return &ReplicaSet{
+		self:  self,
+		peers: map[uuid.UUID]*url.URL{},
+	}
+}
+
+// SetPeers replaces the healthy peers of the replica. Peers are keyed by
+// their ID, with the relay address they can be reached at as the value.
+func (s *ReplicaSet) SetPeers(peers map[uuid.UUID]string) {
+	parsed := make(map[uuid.UUID]*url.URL, len(peers))
+	for id, relayAddress := range peers {
+		if id == s.self || relayAddress == "" {
+			continue
+		}
+		u, err := url.Parse(relayAddress)
+		if err != nil {
+			continue
+		}
+		parsed[id] = u
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.peers = parsed
+}
+
+// SetTransport sets the transport requests are forwarded to peers with. It
+// defaults to http.DefaultTransport.
+func (s *ReplicaSet) SetTransport(transport http.RoundTripper) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transport = transport
+}
+
+func (s *ReplicaSet) hasPeers() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.peers) > 0
+}
+
+func (s *ReplicaSet) peer(id uuid.UUID) (*url.URL, http.RoundTripper, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.peers[id]
+	if !ok {
+		return nil, nil, false
+	}
+	transport := s.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return u, transport, true
+}
+
+// routeToReplica forwards a subdomain app request to the replica named in the
+// replica cookie of the browser. If the browser doesn't have a cookie yet, or
+// the replica it names is gone, the request is served by this replica and the
+// cookie is set to it. Returns false if the request was forwarded.
+func (s *Server) routeToReplica(rw http.ResponseWriter, r *http.Request) bool {
+	if s.Replicas == nil {
+		return true
+	}
+	if forwarded := r.Header.Get(replicaForwardedHeader); forwarded != "" {
+		r.Header.Del(replicaForwardedHeader)
+		payload, err := s.AppSecurityKey.VerifyReplicaForward(forwarded)
+		if err == nil && payload.ToReplicaID == s.Replicas.self {
+			return true
+		}
+		s.Logger.Debug(r.Context(), "ignore invalid forwarded workspace app request", slog.Error(err))
+	}
+	if !s.Replicas.hasPeers() {
+		return true
+	}
+
+	if cookie, err := r.Cookie(codersdk.DevURLReplicaCookie); err == nil {
+		payload, err := s.AppSecurityKey.VerifyReplica(cookie.Value)
+		if err == nil {
+			if payload.ReplicaID == s.Replicas.self {
+				return true
+			}
+			if relayURL, transport, ok := s.Replicas.peer(payload.ReplicaID); ok {
+				s.forwardToReplica(rw, r, payload.ReplicaID, relayURL, transport)
+				return false
+			}
+		}
+	}
+
+	signed, err := s.AppSecurityKey.SignReplica(ReplicaPayload{ReplicaID: s.Replicas.self})
+	if err != nil {
+		s.Logger.Warn(r.Context(), "sign workspace app replica cookie", slog.Error(err))
+		return true
+	}
+	http.SetCookie(rw, &http.Cookie{
+		Name:     codersdk.DevURLReplicaCookie,
+		Value:    signed,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+		Secure:   s.SecureAuthCookie,
+	})
+	return true
+}
+
+func (s *Server) forwardToReplica(rw http.ResponseWriter, r *http.Request, replicaID uuid.UUID, relayURL *url.URL, transport http.RoundTripper) {
+	proxy := httputil.NewSingleHostReverseProxy(relayURL)
+	proxy.Transport = transport
+	// Responses are streamed as they are written, for gRPC and server-sent
+	// events.
+	proxy.FlushInterval = -1
+	proxy.ErrorHandler = func(rw http.ResponseWriter, r *http.Request, err error) {
+		s.Logger.Debug(r.Context(), "forward workspace app request to replica",
+			slog.F("replica_url", relayURL.String()),
+			slog.Error(err),
+		)
+		site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+			Status:       http.StatusBadGateway,
+			Title:        "Bad Gateway",
+			Description:  "Could not reach the Coder replica that serves this application. Please try again.",
+			RetryEnabled: true,
+			DashboardURL: s.DashboardURL.String(),
+		})
+	}
+
+	signed, err := s.AppSecurityKey.SignReplicaForward(ReplicaForwardPayload{
+		FromReplicaID: s.Replicas.self,
+		ToReplicaID:   replicaID,
+	})
+	if err != nil {
+		s.Logger.Warn(r.Context(), "sign forwarded workspace app request", slog.Error(err))
+		site.RenderStaticErrorPage(rw, r, site.ErrorPageData{
+			Status:       http.StatusInternalServerError,
+			Title:        "Internal Server Error",
+			Description:  "Could not forward the request to the Coder replica that serves this application.",
+			RetryEnabled: true,
+			DashboardURL: s.DashboardURL.String(),
+		})
+		return
+	}
+
+	// The Host header is kept so the replica routes the request to the same
+	// app.
+	r.Header.Set(replicaForwardedHeader, signed)
+	proxy.ServeHTTP(rw, r)
+}
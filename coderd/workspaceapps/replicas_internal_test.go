@@ -0,0 +1,126 @@
+package workspaceapps
+
+import (
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/codersdk"
+)
+
+func TestRouteToReplica(t *testing.T) {
+	t.Parallel()
+
+	var key SecurityKey
+	_, err := rand.Read(key[:])
+	require.NoError(t, err)
+
+	// newServer returns a server with a single peer, and a channel that
+	// receives the forwarded header of the requests the peer receives.
+	newServer := func(t *testing.T) (*Server, uuid.UUID, chan string) {
+		forwarded := make(chan string, 1)
+		peer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			forwarded <- r.Header.Get(replicaForwardedHeader)
+			rw.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(peer.Close)
+
+		self, peerID := uuid.New(), uuid.New()
+		replicas := NewReplicaSet(self)
+		replicas.SetPeers(map[uuid.UUID]string{peerID: peer.URL})
+		dashboardURL, err := url.Parse("http://coder.example.com")
+		require.NoError(t, err)
+		return &Server{
+			Logger:         slogtest.Make(t, nil),
+			DashboardURL:   dashboardURL,
+			AppSecurityKey: key,
+			Replicas:       replicas,
+		}, peerID, forwarded
+	}
+	cookie := func(t *testing.T, replicaID uuid.UUID) *http.Cookie {
+		signed, err := key.SignReplica(ReplicaPayload{ReplicaID: replicaID})
+		require.NoError(t, err)
+		return &http.Cookie{Name: codersdk.DevURLReplicaCookie, Value: signed}
+	}
+
+	t.Run("Forward", func(t *testing.T) {
+		t.Parallel()
+
+		s, peerID, forwarded := newServer(t)
+		r := httptest.NewRequest(http.MethodGet, "http://app.coder.example.com/", nil)
+		r.AddCookie(cookie(t, peerID))
+		rw := httptest.NewRecorder()
+		require.False(t, s.routeToReplica(rw, r))
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		// The peer can verify that the request was forwarded to it.
+		payload, err := key.VerifyReplicaForward(<-forwarded)
+		require.NoError(t, err)
+		require.Equal(t, s.Replicas.self, payload.FromReplicaID)
+		require.Equal(t, peerID, payload.ToReplicaID)
+	})
+
+	t.Run("Forwarded", func(t *testing.T) {
+		t.Parallel()
+
+		s, peerID, _ := newServer(t)
+		signed, err := key.SignReplicaForward(ReplicaForwardPayload{
+			FromReplicaID: peerID,
+			ToReplicaID:   s.Replicas.self,
+		})
+		require.NoError(t, err)
+		r := httptest.NewRequest(http.MethodGet, "http://app.coder.example.com/", nil)
+		r.AddCookie(cookie(t, peerID))
+		r.Header.Set(replicaForwardedHeader, signed)
+		rw := httptest.NewRecorder()
+		require.True(t, s.routeToReplica(rw, r))
+		require.Empty(t, r.Header.Get(replicaForwardedHeader))
+	})
+
+	t.Run("SpoofedHeader", func(t *testing.T) {
+		t.Parallel()
+
+		s, peerID, forwarded := newServer(t)
+		for _, value := range []string{
+			s.Replicas.self.String(),
+			// The replica cookie is signed with the same key.
+			cookie(t, s.Replicas.self).Value,
+		} {
+			r := httptest.NewRequest(http.MethodGet, "http://app.coder.example.com/", nil)
+			r.AddCookie(cookie(t, peerID))
+			r.Header.Set(replicaForwardedHeader, value)
+			rw := httptest.NewRecorder()
+			// The request is routed as if it didn't have the header.
+			require.False(t, s.routeToReplica(rw, r))
+			_, err := key.VerifyReplicaForward(<-forwarded)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("ForwardedToOtherReplica", func(t *testing.T) {
+		t.Parallel()
+
+		s, peerID, forwarded := newServer(t)
+		// Forwarded requests can't be replayed against other replicas.
+		signed, err := key.SignReplicaForward(ReplicaForwardPayload{
+			FromReplicaID: s.Replicas.self,
+			ToReplicaID:   uuid.New(),
+		})
+		require.NoError(t, err)
+		r := httptest.NewRequest(http.MethodGet, "http://app.coder.example.com/", nil)
+		r.AddCookie(cookie(t, peerID))
+		r.Header.Set(replicaForwardedHeader, signed)
+		rw := httptest.NewRecorder()
+		require.False(t, s.routeToReplica(rw, r))
+		payload, err := key.VerifyReplicaForward(<-forwarded)
+		require.NoError(t, err)
+		require.Equal(t, peerID, payload.ToReplicaID)
+	})
+}
@@ -39,6 +39,10 @@ type IssueTokenRequest struct {
 	AppQuery string `json:"app_query"`
 	// SessionToken is the session token provided by the user.
 	SessionToken string `json:"session_token"`
+	// OIDCSession is the signed session of a user that logged in with the
+	// workspace app OIDC provider, if any. It is only accepted for apps that
+	// use OIDC auth.
+	OIDCSession string `json:"oidc_session"`
 }
 
 // AppBaseURL returns the base URL of this specific app request. An error is
@@ -180,6 +184,20 @@ type databaseRequest struct {
 	// AppSharingLevel is the sharing level of the app. This is forced to be set
 	// to AppSharingLevelOwner if the access method is terminal.
 	AppSharingLevel database.AppSharingLevel
+	// AppAuth is how users that are not signed in to Coder authenticate with
+	// the app. This is always database.WorkspaceAppAuthCoder for terminal and
+	// port requests.
+	AppAuth database.WorkspaceAppAuth
+	// AppRateLimit is the rate limit applied to each user of the app. This is
+	// always disabled for terminal and port requests.
+	AppRateLimit RateLimit
+	// AppRewritePaths is true if redirects and HTML of the path-based app are
+	// rewritten to stay under the path the app is served from.
+	AppRewritePaths bool
+	// WorkspaceRenamed is true if the workspace was found by a previous name
+	// of the workspace. The request should be redirected to the app URL with
+	// the current name.
+	WorkspaceRenamed bool
 }
 
 // getDatabase does queries to get the owner user, workspace and agent
@@ -217,8 +235,9 @@ func (r Request) getDatabase(ctx context.Context, db database.Store) (*databaseR
 
 	// Get workspace.
 	var (
-		workspace    database.Workspace
-		workspaceErr error
+		workspace        database.Workspace
+		workspaceErr     error
+		workspaceRenamed bool
 	)
 	if workspaceID, uuidErr := uuid.Parse(r.WorkspaceNameOrID); uuidErr == nil {
 		workspace, workspaceErr = db.GetWorkspaceByID(ctx, workspaceID)
@@ -228,6 +247,11 @@ func (r Request) getDatabase(ctx context.Context, db database.Store) (*databaseR
 			Name:    r.WorkspaceNameOrID,
 			Deleted: false,
 		})
+		if xerrors.Is(workspaceErr, sql.ErrNoRows) && r.AccessMethod == AccessMethodSubdomain {
+			// The name may be a previous name of a renamed workspace.
+			workspace, workspaceErr = getRenamedWorkspace(ctx, db, user.ID, r.WorkspaceNameOrID)
+			workspaceRenamed = workspaceErr == nil
+		}
 	}
 	if workspaceErr != nil {
 		return nil, xerrors.Errorf("get workspace %q: %w", r.WorkspaceNameOrID, workspaceErr)
@@ -261,6 +285,9 @@ func (r Request) getDatabase(ctx context.Context, db database.Store) (*databaseR
 		agentNameOrID         = r.AgentNameOrID
 		appURL                string
 		appSharingLevel       database.AppSharingLevel
+		appAuth               = database.WorkspaceAppAuthCoder
+		appRateLimit          RateLimit
+		appRewritePaths       bool
 		appHealth             = database.WorkspaceAppHealthDisabled
 		portUint, portUintErr = strconv.ParseUint(r.AppSlugOrPort, 10, 16)
 	)
@@ -299,6 +326,14 @@ func (r Request) getDatabase(ctx context.Context, db database.Store) (*databaseR
 				} else {
 					appSharingLevel = database.AppSharingLevelOwner
 				}
+				if app.Auth != "" {
+					appAuth = app.Auth
+				}
+				appRateLimit = RateLimit{
+					RequestsPerMinute: app.RateLimitRequestsPerMinute,
+					Burst:             app.RateLimitBurst,
+				}
+				appRewritePaths = app.RewritePaths
 				appURL = app.Url.String
 				appHealth = app.Health
 				break
@@ -341,16 +376,40 @@ func (r Request) getDatabase(ctx context.Context, db database.Store) (*databaseR
 	}
 
 	return &databaseRequest{
-		Request:         r,
-		User:            user,
-		Workspace:       workspace,
-		Agent:           agent,
-		AppURL:          appURLParsed,
-		AppHealth:       appHealth,
-		AppSharingLevel: appSharingLevel,
+		Request:          r,
+		User:             user,
+		Workspace:        workspace,
+		Agent:            agent,
+		AppURL:           appURLParsed,
+		AppHealth:        appHealth,
+		AppSharingLevel:  appSharingLevel,
+		AppAuth:          appAuth,
+		AppRateLimit:     appRateLimit,
+		AppRewritePaths:  appRewritePaths,
+		WorkspaceRenamed: workspaceRenamed,
 	}, nil
 }
 
+// getRenamedWorkspace returns the workspace that was renamed from the given
+// name, if the redirect from the name hasn't expired.
+func getRenamedWorkspace(ctx context.Context, db database.Store, ownerID uuid.UUID, name string) (database.Workspace, error) {
+	redirect, err := db.GetWorkspaceNameRedirect(ctx, database.GetWorkspaceNameRedirectParams{
+		OwnerID: ownerID,
+		Name:    name,
+	})
+	if err != nil {
+		return database.Workspace{}, err
+	}
+	workspace, err := db.GetWorkspaceByID(ctx, redirect.WorkspaceID)
+	if err != nil {
+		return database.Workspace{}, err
+	}
+	if workspace.Deleted {
+		return database.Workspace{}, sql.ErrNoRows
+	}
+	return workspace, nil
+}
+
 // getDatabaseTerminal is called by getDatabase for AccessMethodTerminal
 // requests.
 func (r Request) getDatabaseTerminal(ctx context.Context, db database.Store) (*databaseRequest, error) {
@@ -401,5 +460,6 @@ func (r Request) getDatabaseTerminal(ctx context.Context, db database.Store) (*d
 		AppURL:          nil,
 		AppHealth:       database.WorkspaceAppHealthHealthy,
 		AppSharingLevel: database.AppSharingLevelOwner,
+		AppAuth:         database.WorkspaceAppAuthCoder,
 	}, nil
 }
@@ -2,6 +2,7 @@ package workspaceapps
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,6 +19,11 @@ const (
 	DefaultStatsCollectorReportInterval = 30 * time.Second
 	DefaultStatsCollectorRollupWindow   = 1 * time.Minute
 	DefaultStatsDBReporterBatchSize     = 1024
+
+	// maxAccessLogBacklog is the maximum number of access logs kept in memory
+	// by the StatsCollector. Access logs beyond it are dropped, e.g. while
+	// the reporter is failing.
+	maxAccessLogBacklog = 10000
 )
 
 // StatsReport is a report of a workspace app session.
@@ -31,8 +37,13 @@ type StatsReport struct {
 	SessionStartedAt time.Time    `json:"session_started_at"`
 	SessionEndedAt   time.Time    `json:"session_ended_at"` // Updated periodically while app is in use active and when the last connection is closed.
 	Requests         int          `json:"requests"`
+	// ActiveDuration is the time the app was in use during the session. For
+	// rolled up sessions this is the combined duration of the sessions, not
+	// counting time in between them.
+	ActiveDuration time.Duration `json:"active_duration"`
 
-	rolledUp bool // Indicates if this report has been rolled up.
+	rolledUp    bool      // Indicates if this report has been rolled up.
+	activeUntil time.Time // End of the latest session included in the rollup.
 }
 
 func newStatsReportFromSignedToken(token SignedToken) StatsReport {
@@ -48,12 +59,68 @@ func newStatsReportFromSignedToken(token SignedToken) StatsReport {
 	}
 }
 
+// AccessLog is a log entry of a single request proxied to a workspace app.
+type AccessLog struct {
+	Time         time.Time    `json:"time"`
+	UserID       uuid.UUID    `json:"user_id"` // The user that made the request, uuid.Nil if they were not signed in to Coder.
+	WorkspaceID  uuid.UUID    `json:"workspace_id"`
+	AgentID      uuid.UUID    `json:"agent_id"`
+	AccessMethod AccessMethod `json:"access_method"`
+	SlugOrPort   string       `json:"slug_or_port"`
+	// PathPrefix is the first segment of the requested path, e.g. "/api" for
+	// "/api/users". Only the prefix is logged to avoid logging IDs and other
+	// sensitive data that is commonly part of paths.
+	PathPrefix string `json:"path_prefix"`
+	StatusCode int    `json:"status_code"`
+	// Latency is the time it took to proxy the request. For WebSocket
+	// connections this is the duration of the connection.
+	Latency time.Duration `json:"latency"`
+}
+
+func newAccessLogFromSignedToken(token SignedToken, start time.Time, path string) AccessLog {
+	return AccessLog{
+		Time:         start,
+		UserID:       token.RequesterID,
+		WorkspaceID:  token.WorkspaceID,
+		AgentID:      token.AgentID,
+		AccessMethod: token.AccessMethod,
+		SlugOrPort:   token.AppSlugOrPort,
+		PathPrefix:   accessLogPathPrefix(path),
+	}
+}
+
+// accessLogPathPrefix returns the first segment of the path.
+func accessLogPathPrefix(path string) string {
+	path = "/" + strings.TrimPrefix(path, "/")
+	if i := strings.Index(path[1:], "/"); i >= 0 {
+		return path[:i+1]
+	}
+	return path
+}
+
 // StatsReporter reports workspace app StatsReports.
 type StatsReporter interface {
 	Report(context.Context, []StatsReport) error
 }
 
-var _ StatsReporter = (*StatsDBReporter)(nil)
+// AccessLogReporter reports workspace app AccessLogs.
+type AccessLogReporter interface {
+	ReportAccessLogs(context.Context, []AccessLog) error
+}
+
+// AccessLogReporterFunc is an adapter to allow the use of ordinary functions as
+// AccessLogReporters.
+type AccessLogReporterFunc func(context.Context, []AccessLog) error
+
+// ReportAccessLogs calls f(ctx, logs).
+func (f AccessLogReporterFunc) ReportAccessLogs(ctx context.Context, logs []AccessLog) error {
+	return f(ctx, logs)
+}
+
+var (
+	_ StatsReporter     = (*StatsDBReporter)(nil)
+	_ AccessLogReporter = (*StatsDBReporter)(nil)
+)
 
 // StatsDBReporter writes workspace app StatsReports to the database.
 type StatsDBReporter struct {
@@ -86,6 +153,7 @@ func (r *StatsDBReporter) Report(ctx context.Context, stats []StatsReport) error
 			SessionStartedAt: make([]time.Time, 0, maxBatchSize),
 			SessionEndedAt:   make([]time.Time, 0, maxBatchSize),
 			Requests:         make([]int32, 0, maxBatchSize),
+			ActiveDurationMS: make([]int64, 0, maxBatchSize),
 		}
 		for _, stat := range stats {
 			batch.UserID = append(batch.UserID, stat.UserID)
@@ -97,6 +165,7 @@ func (r *StatsDBReporter) Report(ctx context.Context, stats []StatsReport) error
 			batch.SessionStartedAt = append(batch.SessionStartedAt, stat.SessionStartedAt)
 			batch.SessionEndedAt = append(batch.SessionEndedAt, stat.SessionEndedAt)
 			batch.Requests = append(batch.Requests, int32(stat.Requests))
+			batch.ActiveDurationMS = append(batch.ActiveDurationMS, stat.ActiveDuration.Milliseconds())
 
 			if len(batch.UserID) >= r.batchSize {
 				err := tx.InsertWorkspaceAppStats(ctx, batch)
@@ -114,6 +183,7 @@ func (r *StatsDBReporter) Report(ctx context.Context, stats []StatsReport) error
 				batch.SessionStartedAt = batch.SessionStartedAt[:0]
 				batch.SessionEndedAt = batch.SessionEndedAt[:0]
 				batch.Requests = batch.Requests[:0]
+				batch.ActiveDurationMS = batch.ActiveDurationMS[:0]
 			}
 		}
 		if len(batch.UserID) > 0 {
@@ -132,6 +202,52 @@ func (r *StatsDBReporter) Report(ctx context.Context, stats []StatsReport) error
 	return nil
 }
 
+// ReportAccessLogs writes the given AccessLogs to the database.
+func (r *StatsDBReporter) ReportAccessLogs(ctx context.Context, logs []AccessLog) error {
+	err := r.db.InTx(func(tx database.Store) error {
+		for len(logs) > 0 {
+			n := r.batchSize
+			if len(logs) < n {
+				n = len(logs)
+			}
+			batch := database.InsertWorkspaceAppAccessLogsParams{
+				CreatedAt:    make([]time.Time, 0, n),
+				UserID:       make([]uuid.UUID, 0, n),
+				WorkspaceID:  make([]uuid.UUID, 0, n),
+				AgentID:      make([]uuid.UUID, 0, n),
+				AccessMethod: make([]string, 0, n),
+				SlugOrPort:   make([]string, 0, n),
+				PathPrefix:   make([]string, 0, n),
+				StatusCode:   make([]int32, 0, n),
+				LatencyMS:    make([]int64, 0, n),
+			}
+			for _, l := range logs[:n] {
+				batch.CreatedAt = append(batch.CreatedAt, l.Time)
+				batch.UserID = append(batch.UserID, l.UserID)
+				batch.WorkspaceID = append(batch.WorkspaceID, l.WorkspaceID)
+				batch.AgentID = append(batch.AgentID, l.AgentID)
+				batch.AccessMethod = append(batch.AccessMethod, string(l.AccessMethod))
+				batch.SlugOrPort = append(batch.SlugOrPort, l.SlugOrPort)
+				batch.PathPrefix = append(batch.PathPrefix, l.PathPrefix)
+				batch.StatusCode = append(batch.StatusCode, int32(l.StatusCode))
+				batch.LatencyMS = append(batch.LatencyMS, l.Latency.Milliseconds())
+			}
+			err := tx.InsertWorkspaceAppAccessLogs(ctx, batch)
+			if err != nil {
+				return err
+			}
+			logs = logs[n:]
+		}
+
+		return nil
+	}, nil)
+	if err != nil {
+		return xerrors.Errorf("insert workspace app access logs failed: %w", err)
+	}
+
+	return nil
+}
+
 // This should match the database unique constraint.
 type statsGroupKey struct {
 	StartTimeTrunc time.Time
@@ -153,6 +269,21 @@ func (s StatsReport) groupKey(windowSize time.Duration) statsGroupKey {
 	}
 }
 
+// addActive adds the time between start and end to the active duration of a
+// rolled up report. Sessions are mostly rolled up in the order they started,
+// so only the overlap with the latest rolled up session is excluded.
+func (s *StatsReport) addActive(start, end time.Time) {
+	if start.Before(s.activeUntil) {
+		start = s.activeUntil
+	}
+	if end.After(start) {
+		s.ActiveDuration += end.Sub(start)
+	}
+	if end.After(s.activeUntil) {
+		s.activeUntil = end
+	}
+}
+
 // StatsCollector collects workspace app StatsReports and reports them
 // in batches, stats compaction is performed for short-lived sessions.
 type StatsCollector struct {
@@ -166,6 +297,8 @@ type StatsCollector struct {
 	statsBySessionID map[uuid.UUID]*StatsReport       // Track unique sessions.
 	groupedStats     map[statsGroupKey][]*StatsReport // Rolled up stats for sessions in close proximity.
 	backlog          []StatsReport                    // Stats that have not been reported yet (due to error).
+	accessLogs       []AccessLog                      // Access logs that have not been reported yet.
+	droppedLogs      int                              // Access logs dropped since the last report.
 }
 
 type StatsCollectorOptions struct {
@@ -178,6 +311,9 @@ type StatsCollectorOptions struct {
 	// than this will be rolled up and longer than this will be tracked
 	// individually.
 	RollupWindow time.Duration
+	// AccessLogReporter reports the access logs of workspace app requests.
+	// Access logs are not collected if it is nil.
+	AccessLogReporter AccessLogReporter
 
 	// Options for tests.
 	Flush <-chan chan<- struct{}
@@ -235,6 +371,28 @@ func (sc *StatsCollector) Collect(report StatsReport) {
 	}
 }
 
+// AccessLogsEnabled returns true if access logs are collected.
+func (sc *StatsCollector) AccessLogsEnabled() bool {
+	return sc.opts.AccessLogReporter != nil
+}
+
+// CollectAccessLog collects the given AccessLog for later reporting
+// (non-blocking). Access logs are dropped if they are not enabled.
+func (sc *StatsCollector) CollectAccessLog(log AccessLog) {
+	if !sc.AccessLogsEnabled() {
+		return
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if len(sc.accessLogs) >= maxAccessLogBacklog {
+		sc.droppedLogs++
+		return
+	}
+	sc.accessLogs = append(sc.accessLogs, log)
+}
+
 // rollup performs stats rollup for sessions that fall within the
 // configured rollup window. For sessions longer than the window,
 // we report them individually.
@@ -278,6 +436,7 @@ func (sc *StatsCollector) rollup(now time.Time) []StatsReport {
 					rolledUp.SessionID = stat.SessionID // Borrow the first session ID, useful in tests.
 				}
 				rolledUp.Requests += stat.Requests
+				rolledUp.addActive(stat.SessionStartedAt, stat.SessionEndedAt)
 				rollupChanged = true
 				continue
 			}
@@ -296,6 +455,7 @@ func (sc *StatsCollector) rollup(now time.Time) []StatsReport {
 				// will have an end time even if the service is stopped.
 				r.SessionEndedAt = now.UTC() // Use UTC like database.Now().
 			}
+			r.ActiveDuration = r.SessionEndedAt.Sub(r.SessionStartedAt)
 			report = append(report, r) // Report it (ended or incomplete).
 			if stat.SessionEndedAt.IsZero() {
 				newGroup = append(newGroup, stat) // Keep it for future updates.
@@ -358,6 +518,46 @@ func (sc *StatsCollector) flush(ctx context.Context) (err error) {
 	return nil
 }
 
+func (sc *StatsCollector) flushAccessLogs(ctx context.Context) error {
+	if !sc.AccessLogsEnabled() {
+		return nil
+	}
+
+	sc.mu.Lock()
+	logs := sc.accessLogs
+	dropped := sc.droppedLogs
+	sc.accessLogs = nil
+	sc.droppedLogs = 0
+	sc.mu.Unlock()
+
+	if dropped > 0 {
+		sc.opts.Logger.Warn(ctx, "dropped workspace app access logs, too many logs waiting to be reported", slog.F("count", dropped))
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+
+	err := sc.opts.AccessLogReporter.ReportAccessLogs(ctx, logs)
+	if err != nil {
+		sc.opts.Logger.Error(ctx, "failed to report workspace app access logs", slog.Error(err))
+
+		// Put the logs back in front of the ones collected in the meantime
+		// so they are retried on the next flush, dropping the newest logs
+		// if there are too many.
+		sc.mu.Lock()
+		logs = append(logs, sc.accessLogs...)
+		if len(logs) > maxAccessLogBacklog {
+			sc.droppedLogs += len(logs) - maxAccessLogBacklog
+			logs = logs[:maxAccessLogBacklog]
+		}
+		sc.accessLogs = logs
+		sc.mu.Unlock()
+		return xerrors.Errorf("report workspace app access logs failed: %w", err)
+	}
+
+	return nil
+}
+
 func (sc *StatsCollector) Close() error {
 	sc.cancel()
 	<-sc.done
@@ -388,6 +588,8 @@ func (sc *StatsCollector) start() {
 		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 		//nolint:gocritic // Inserting app stats is a system function.
 		_ = sc.flush(dbauthz.AsSystemRestricted(ctx))
+		//nolint:gocritic // Inserting app access logs is a system function.
+		_ = sc.flushAccessLogs(dbauthz.AsSystemRestricted(ctx))
 		cancel()
 
 		if !done {
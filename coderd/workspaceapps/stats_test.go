@@ -402,6 +402,56 @@ func TestStatsCollector_backlog(t *testing.T) {
 	assert.Len(t, reporter.stats(), 2)
 }
 
+func TestStatsCollector_activeDuration(t *testing.T) {
+	t.Parallel()
+
+	rollupWindow := time.Minute
+	flush := make(chan chan<- struct{}, 1)
+
+	start := database.Now().Truncate(time.Minute).UTC()
+	var now atomic.Pointer[time.Time]
+	now.Store(&start)
+
+	reporter := &fakeReporter{}
+	collector := workspaceapps.NewStatsCollector(workspaceapps.StatsCollectorOptions{
+		Reporter:       reporter,
+		ReportInterval: time.Hour,
+		RollupWindow:   rollupWindow,
+
+		Flush: flush,
+		Now:   func() time.Time { return *now.Load() },
+	})
+
+	rollupUUID := uuid.New()
+	longUUID := uuid.New()
+	// Overlapping sessions are only counted once, and the gap between
+	// sessions isn't counted at all.
+	for _, s := range []workspaceapps.StatsReport{
+		{SessionID: rollupUUID, SessionStartedAt: start, SessionEndedAt: start.Add(10 * time.Second)},
+		{SessionID: uuid.New(), SessionStartedAt: start.Add(5 * time.Second), SessionEndedAt: start.Add(15 * time.Second)},
+		{SessionID: uuid.New(), SessionStartedAt: start.Add(30 * time.Second), SessionEndedAt: start.Add(35 * time.Second)},
+		{SessionID: longUUID, SessionStartedAt: start, SessionEndedAt: start.Add(3 * time.Minute)},
+	} {
+		s.Requests = 1
+		collector.Collect(s)
+	}
+
+	flushTime := start.Add(2*rollupWindow + time.Second)
+	now.Store(&flushTime)
+	flushDone := make(chan struct{}, 1)
+	flush <- flushDone
+	<-flushDone
+
+	got := make(map[uuid.UUID]workspaceapps.StatsReport)
+	for _, s := range reporter.stats() {
+		got[s.SessionID] = s
+	}
+	require.Len(t, got, 2)
+	assert.Equal(t, 3, got[rollupUUID].Requests)
+	assert.Equal(t, 20*time.Second, got[rollupUUID].ActiveDuration)
+	assert.Equal(t, 3*time.Minute, got[longUUID].ActiveDuration)
+}
+
 func TestStatsCollector_Close(t *testing.T) {
 	t.Parallel()
 
@@ -424,3 +474,78 @@ func TestStatsCollector_Close(t *testing.T) {
 	// Verify that stats are reported after close.
 	assert.NotEmpty(t, reporter.stats())
 }
+
+type fakeAccessLogReporter struct {
+	mu   sync.Mutex
+	logs []workspaceapps.AccessLog
+	err  error
+	errN int
+}
+
+func (r *fakeAccessLogReporter) ReportAccessLogs(_ context.Context, logs []workspaceapps.AccessLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		r.errN++
+		return r.err
+	}
+	r.logs = append(r.logs, logs...)
+	return nil
+}
+
+func TestStatsCollector_accessLogs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		collector := workspaceapps.NewStatsCollector(workspaceapps.StatsCollectorOptions{
+			Reporter:       &fakeReporter{},
+			ReportInterval: time.Hour,
+		})
+		defer collector.Close()
+
+		require.False(t, collector.AccessLogsEnabled())
+		// Must not panic without a reporter.
+		collector.CollectAccessLog(workspaceapps.AccessLog{})
+	})
+
+	t.Run("Backlog", func(t *testing.T) {
+		t.Parallel()
+
+		flush := make(chan chan<- struct{}, 1)
+		accessLogReporter := &fakeAccessLogReporter{err: xerrors.New("some error")}
+		collector := workspaceapps.NewStatsCollector(workspaceapps.StatsCollectorOptions{
+			Reporter:          &fakeReporter{},
+			AccessLogReporter: accessLogReporter,
+			ReportInterval:    time.Hour,
+
+			Flush: flush,
+		})
+		defer collector.Close()
+		require.True(t, collector.AccessLogsEnabled())
+
+		collector.CollectAccessLog(workspaceapps.AccessLog{PathPrefix: "/first", StatusCode: 200})
+		flushDone := make(chan struct{}, 1)
+		flush <- flushDone
+		<-flushDone
+
+		// The failed logs are kept and reported before the logs collected
+		// in the meantime.
+		accessLogReporter.mu.Lock()
+		require.Equal(t, 1, accessLogReporter.errN)
+		require.Empty(t, accessLogReporter.logs)
+		accessLogReporter.err = nil
+		accessLogReporter.mu.Unlock()
+
+		collector.CollectAccessLog(workspaceapps.AccessLog{PathPrefix: "/second", StatusCode: 404})
+		flush <- flushDone
+		<-flushDone
+
+		accessLogReporter.mu.Lock()
+		defer accessLogReporter.mu.Unlock()
+		require.Len(t, accessLogReporter.logs, 2)
+		assert.Equal(t, "/first", accessLogReporter.logs[0].PathPrefix)
+		assert.Equal(t, "/second", accessLogReporter.logs[1].PathPrefix)
+	})
+}
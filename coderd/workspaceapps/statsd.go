@@ -0,0 +1,117 @@
+package workspaceapps
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+
+	"golang.org/x/xerrors"
+)
+
+// statsDMaxPacketSize is the maximum size of a single StatsD datagram. This
+// keeps packets below the MTU of most networks so they aren't fragmented.
+const statsDMaxPacketSize = 1432
+
+var _ StatsReporter = (*StatsDReporter)(nil)
+
+// StatsDReporter sends workspace app StatsReports to a StatsD server as
+// counters. Tags are sent in the DogStatsD format, which is understood by
+// most StatsD implementations.
+type StatsDReporter struct {
+	conn   net.Conn
+	deltas *statsDeltaTracker
+}
+
+// NewStatsDReporter returns a new StatsDReporter that sends metrics to the
+// given UDP address.
+func NewStatsDReporter(address string) (*StatsDReporter, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, xerrors.Errorf("dial statsd %q: %w", address, err)
+	}
+	return &StatsDReporter{
+		conn:   conn,
+		deltas: newStatsDeltaTracker(nil),
+	}, nil
+}
+
+// Report sends the given StatsReports to the StatsD server.
+func (r *StatsDReporter) Report(ctx context.Context, stats []StatsReport) error {
+	// Writes to a UDP socket rarely block, but respect the deadline of the
+	// report in case they do.
+	deadline, _ := ctx.Deadline()
+	err := r.conn.SetWriteDeadline(deadline)
+	if err != nil {
+		return xerrors.Errorf("set statsd write deadline: %w", err)
+	}
+
+	var (
+		packet bytes.Buffer
+		line   []byte
+	)
+	flush := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+		_, err := r.conn.Write(packet.Bytes())
+		packet.Reset()
+		if err != nil {
+			return xerrors.Errorf("write statsd packet: %w", err)
+		}
+		return nil
+	}
+	write := func() error {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > statsDMaxPacketSize {
+			err := flush()
+			if err != nil {
+				return err
+			}
+		}
+		if packet.Len() > 0 {
+			_ = packet.WriteByte('\n')
+		}
+		_, _ = packet.Write(line)
+		return nil
+	}
+
+	for _, d := range r.deltas.deltas(stats) {
+		if d.Requests > 0 {
+			line = appendStatsDLine(line[:0], StatsMetricRequests, strconv.Itoa(d.Requests), d.StatsReport)
+			err := write()
+			if err != nil {
+				return err
+			}
+		}
+		if d.Duration > 0 {
+			line = appendStatsDLine(line[:0], StatsMetricSessionSeconds, strconv.FormatFloat(d.Duration.Seconds(), 'f', -1, 64), d.StatsReport)
+			err := write()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// Close closes the connection to the StatsD server.
+func (r *StatsDReporter) Close() error {
+	return r.conn.Close()
+}
+
+func appendStatsDLine(b []byte, name, value string, stat StatsReport) []byte {
+	b = append(b, name...)
+	b = append(b, ':')
+	b = append(b, value...)
+	b = append(b, "|c|#access_method:"...)
+	b = append(b, stat.AccessMethod...)
+	b = append(b, ",slug_or_port:"...)
+	b = append(b, stat.SlugOrPort...)
+	b = append(b, ",user_id:"...)
+	b = append(b, stat.UserID.String()...)
+	b = append(b, ",workspace_id:"...)
+	b = append(b, stat.WorkspaceID.String()...)
+	b = append(b, ",agent_id:"...)
+	b = append(b, stat.AgentID.String()...)
+	return b
+}
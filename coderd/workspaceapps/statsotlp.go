@@ -0,0 +1,202 @@
+package workspaceapps
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/proto"
+)
+
+// statsOTLPExportTimeout bounds a single export to the OTLP endpoint.
+const statsOTLPExportTimeout = 5 * time.Second
+
+var _ StatsReporter = (*StatsOTLPReporter)(nil)
+
+// StatsOTLPReporter exports workspace app StatsReports as OTLP metrics over
+// HTTP. Metrics are exported as monotonic sums with delta temporality.
+// Exports are best effort, stats that fail to export are dropped.
+type StatsOTLPReporter struct {
+	client   *http.Client
+	endpoint string
+	deltas   *statsDeltaTracker
+	now      func() time.Time
+
+	mu       sync.Mutex
+	lastTime time.Time
+}
+
+// NewStatsOTLPReporter returns a new StatsOTLPReporter that exports metrics to
+// the given OTLP/HTTP endpoint. If the endpoint doesn't include the metrics
+// path, "/v1/metrics" is appended, matching the behavior of the
+// OTEL_EXPORTER_OTLP_ENDPOINT environment variable.
+func NewStatsOTLPReporter(client *http.Client, endpoint string) (*StatsOTLPReporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, xerrors.Errorf("parse otlp endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, xerrors.Errorf("otlp endpoint must be an http or https URL, got %q", endpoint)
+	}
+	if !strings.HasSuffix(u.Path, "/v1/metrics") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/v1/metrics"
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &StatsOTLPReporter{
+		client:   client,
+		endpoint: u.String(),
+		deltas:   newStatsDeltaTracker(nil),
+		now:      time.Now,
+		lastTime: time.Now(),
+	}, nil
+}
+
+// otlpAttributesKey identifies a single time series.
+type otlpAttributesKey struct {
+	AccessMethod AccessMethod
+	SlugOrPort   string
+	UserID       string
+	WorkspaceID  string
+	AgentID      string
+}
+
+// Report exports the given StatsReports to the OTLP endpoint.
+func (r *StatsOTLPReporter) Report(ctx context.Context, stats []StatsReport) error {
+	deltas := r.deltas.deltas(stats)
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	// Multiple sessions may belong to the same time series, these must be
+	// summed since delta points for a series can't overlap.
+	requests := make(map[otlpAttributesKey]int64)
+	seconds := make(map[otlpAttributesKey]float64)
+	var keys []otlpAttributesKey
+	for _, d := range deltas {
+		key := otlpAttributesKey{
+			AccessMethod: d.AccessMethod,
+			SlugOrPort:   d.SlugOrPort,
+			UserID:       d.UserID.String(),
+			WorkspaceID:  d.WorkspaceID.String(),
+			AgentID:      d.AgentID.String(),
+		}
+		if _, ok := requests[key]; !ok {
+			keys = append(keys, key)
+		}
+		requests[key] += int64(d.Requests)
+		seconds[key] += d.Duration.Seconds()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	start := uint64(r.lastTime.UnixNano())
+	end := uint64(now.UnixNano())
+
+	requestPoints := make([]*metricspb.NumberDataPoint, 0, len(keys))
+	secondsPoints := make([]*metricspb.NumberDataPoint, 0, len(keys))
+	for _, key := range keys {
+		attrs := otlpAttributes(key)
+		requestPoints = append(requestPoints, &metricspb.NumberDataPoint{
+			Attributes:        attrs,
+			StartTimeUnixNano: start,
+			TimeUnixNano:      end,
+			Value:             &metricspb.NumberDataPoint_AsInt{AsInt: requests[key]},
+		})
+		secondsPoints = append(secondsPoints, &metricspb.NumberDataPoint{
+			Attributes:        attrs,
+			StartTimeUnixNano: start,
+			TimeUnixNano:      end,
+			Value:             &metricspb.NumberDataPoint_AsDouble{AsDouble: seconds[key]},
+		})
+	}
+
+	req := &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{
+				Attributes: []*commonpb.KeyValue{otlpStringAttribute("service.name", "coderd")},
+			},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Scope: &commonpb.InstrumentationScope{
+					Name: "github.com/coder/coder/coderd/workspaceapps",
+				},
+				Metrics: []*metricspb.Metric{
+					{
+						Name:        StatsMetricRequests,
+						Description: "The number of requests made to workspace apps.",
+						Unit:        "{request}",
+						Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+							DataPoints:             requestPoints,
+							AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+							IsMonotonic:            true,
+						}},
+					},
+					{
+						Name:        StatsMetricSessionSeconds,
+						Description: "The time spent in workspace app sessions.",
+						Unit:        "s",
+						Data: &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+							DataPoints:             secondsPoints,
+							AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+							IsMonotonic:            true,
+						}},
+					},
+				},
+			}},
+		}},
+	}
+	body, err := proto.Marshal(req)
+	if err != nil {
+		return xerrors.Errorf("marshal otlp metrics: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, statsOTLPExportTimeout)
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return xerrors.Errorf("create otlp request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	res, err := r.client.Do(httpReq)
+	if err != nil {
+		return xerrors.Errorf("export otlp metrics: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return xerrors.Errorf("export otlp metrics: unexpected status code %d", res.StatusCode)
+	}
+
+	r.lastTime = now
+	return nil
+}
+
+func otlpAttributes(key otlpAttributesKey) []*commonpb.KeyValue {
+	return []*commonpb.KeyValue{
+		otlpStringAttribute("access_method", string(key.AccessMethod)),
+		otlpStringAttribute("slug_or_port", key.SlugOrPort),
+		otlpStringAttribute("user_id", key.UserID),
+		otlpStringAttribute("workspace_id", key.WorkspaceID),
+		otlpStringAttribute("agent_id", key.AgentID),
+	}
+}
+
+func otlpStringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
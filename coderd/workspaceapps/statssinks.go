@@ -0,0 +1,188 @@
+package workspaceapps
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cdr.dev/slog"
+)
+
+const (
+	// StatsMetricRequests is the name of the counter for the number of
+	// requests made to workspace apps.
+	StatsMetricRequests = "coderd.workspace_apps.requests"
+	// StatsMetricSessionSeconds is the name of the counter for the time
+	// spent in workspace app sessions.
+	StatsMetricSessionSeconds = "coderd.workspace_apps.session_seconds"
+
+	// statsDeltaTTL is how long a session is remembered after it was last
+	// reported. Active sessions are re-reported every report interval, so
+	// this only needs to outlive a few intervals.
+	statsDeltaTTL = time.Hour
+
+	// statsSinkTimeout bounds how long a report waits for the sinks, so a
+	// slow or unreachable sink can't hold up the StatsCollector or shutdown.
+	statsSinkTimeout = 5 * time.Second
+)
+
+var _ StatsReporter = (*StatsReporterFanout)(nil)
+
+// StatsReporterFanout reports workspace app StatsReports to a primary
+// reporter and a set of additional sinks.
+//
+// Only errors from the primary reporter are returned, which causes the
+// StatsCollector to retry the report later. Sinks only receive stats once the
+// primary has accepted them so a retried report is never sent to a sink
+// twice. Errors from sinks are logged and otherwise ignored, and sinks that
+// don't finish within statsSinkTimeout are left behind.
+type StatsReporterFanout struct {
+	logger  slog.Logger
+	primary StatsReporter
+	sinks   []StatsReporter
+}
+
+// NewStatsReporterFanout returns a new StatsReporterFanout.
+func NewStatsReporterFanout(logger slog.Logger, primary StatsReporter, sinks ...StatsReporter) *StatsReporterFanout {
+	return &StatsReporterFanout{
+		logger:  logger,
+		primary: primary,
+		sinks:   sinks,
+	}
+}
+
+// Report reports the given StatsReports to the primary reporter and, if that
+// succeeds, to all sinks.
+func (f *StatsReporterFanout) Report(ctx context.Context, stats []StatsReport) error {
+	err := f.primary.Report(ctx, stats)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, statsSinkTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, sink := range f.sinks {
+		sink := sink
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := sink.Report(ctx, stats)
+			if err != nil {
+				f.logger.Warn(ctx, "report workspace app stats to sink failed", slog.Error(err))
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		f.logger.Warn(ctx, "timed out reporting workspace app stats to sinks", slog.Error(ctx.Err()))
+	}
+
+	return nil
+}
+
+// statsDelta is the change in a session's stats since it was last reported.
+type statsDelta struct {
+	StatsReport
+	Requests int
+	Duration time.Duration
+}
+
+type statsDeltaKey struct {
+	UserID    uuid.UUID
+	AgentID   uuid.UUID
+	SessionID uuid.UUID
+}
+
+type statsDeltaEntry struct {
+	requests int
+	duration time.Duration
+	seenAt   time.Time
+}
+
+// statsDeltaTracker converts StatsReports into deltas. The StatsCollector
+// reports long-lived sessions repeatedly with cumulative values, which is
+// what the database wants, but metrics sinks expect counter increments.
+type statsDeltaTracker struct {
+	now func() time.Time
+
+	mu       sync.Mutex
+	sessions map[statsDeltaKey]*statsDeltaEntry
+}
+
+func newStatsDeltaTracker(now func() time.Time) *statsDeltaTracker {
+	if now == nil {
+		now = time.Now
+	}
+	return &statsDeltaTracker{
+		now:      now,
+		sessions: make(map[statsDeltaKey]*statsDeltaEntry),
+	}
+}
+
+// deltas returns the change for each of the given stats since they were last
+// seen. Stats that haven't changed are omitted.
+func (t *statsDeltaTracker) deltas(stats []StatsReport) []statsDelta {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	deltas := make([]statsDelta, 0, len(stats))
+	for _, stat := range stats {
+		key := statsDeltaKey{
+			UserID:    stat.UserID,
+			AgentID:   stat.AgentID,
+			SessionID: stat.SessionID,
+		}
+		duration := stat.ActiveDuration
+
+		entry, ok := t.sessions[key]
+		if !ok {
+			entry = &statsDeltaEntry{}
+			t.sessions[key] = entry
+		}
+		d := statsDelta{
+			StatsReport: stat,
+			Requests:    stat.Requests - entry.requests,
+			Duration:    duration - entry.duration,
+		}
+		// Counters can't go backwards, so never report negative deltas
+		// (e.g. if stats are reported out of order).
+		if d.Requests < 0 {
+			d.Requests = 0
+		}
+		if d.Duration < 0 {
+			d.Duration = 0
+		}
+		if stat.Requests > entry.requests {
+			entry.requests = stat.Requests
+		}
+		if duration > entry.duration {
+			entry.duration = duration
+		}
+		entry.seenAt = now
+
+		if d.Requests == 0 && d.Duration == 0 {
+			continue
+		}
+		deltas = append(deltas, d)
+	}
+
+	for key, entry := range t.sessions {
+		if now.Sub(entry.seenAt) > statsDeltaTTL {
+			delete(t.sessions, key)
+		}
+	}
+
+	return deltas
+}
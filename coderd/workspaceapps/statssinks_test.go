@@ -0,0 +1,314 @@
+package workspaceapps_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"golang.org/x/xerrors"
+	"google.golang.org/protobuf/proto"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/coderd/workspaceapps"
+	"github.com/coder/coder/testutil"
+)
+
+func TestStatsReporterFanout(t *testing.T) {
+	t.Parallel()
+
+	stats := []workspaceapps.StatsReport{{
+		SessionID: uuid.New(),
+		Requests:  1,
+	}}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		primary := &fakeReporter{}
+		sink1 := &fakeReporter{}
+		sink2 := &fakeReporter{}
+		fanout := workspaceapps.NewStatsReporterFanout(slogtest.Make(t, nil), primary, sink1, sink2)
+
+		err := fanout.Report(context.Background(), stats)
+		require.NoError(t, err)
+		assert.Equal(t, stats, primary.stats())
+		assert.Equal(t, stats, sink1.stats())
+		assert.Equal(t, stats, sink2.stats())
+	})
+
+	t.Run("PrimaryError", func(t *testing.T) {
+		t.Parallel()
+
+		primary := &fakeReporter{}
+		primary.setError(xerrors.New("primary error"))
+		sink := &fakeReporter{}
+		fanout := workspaceapps.NewStatsReporterFanout(slogtest.Make(t, nil), primary, sink)
+
+		err := fanout.Report(context.Background(), stats)
+		require.Error(t, err)
+		assert.Empty(t, sink.stats(), "sink should not receive stats the primary rejected")
+	})
+
+	t.Run("SinkError", func(t *testing.T) {
+		t.Parallel()
+
+		primary := &fakeReporter{}
+		failing := &fakeReporter{}
+		failing.setError(xerrors.New("sink error"))
+		sink := &fakeReporter{}
+		logger := slogtest.Make(t, &slogtest.Options{IgnoreErrors: true})
+		fanout := workspaceapps.NewStatsReporterFanout(logger, primary, failing, sink)
+
+		err := fanout.Report(context.Background(), stats)
+		require.NoError(t, err)
+		assert.Equal(t, stats, primary.stats())
+		assert.Equal(t, stats, sink.stats())
+		assert.Equal(t, 1, failing.errors())
+	})
+
+	t.Run("SlowSink", func(t *testing.T) {
+		t.Parallel()
+
+		primary := &fakeReporter{}
+		// The slow sink ignores the context, so the fanout must stop waiting
+		// for it on its own.
+		unblock := make(chan struct{})
+		defer close(unblock)
+		slow := blockingReporter(unblock)
+		sink := &fakeReporter{}
+		fanout := workspaceapps.NewStatsReporterFanout(slogtest.Make(t, nil), primary, slow, sink)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.IntervalMedium)
+		defer cancel()
+		err := fanout.Report(ctx, stats)
+		require.NoError(t, err)
+		assert.Equal(t, stats, primary.stats())
+		assert.Equal(t, stats, sink.stats())
+	})
+}
+
+// blockingReporter blocks every report until the channel is closed.
+type blockingReporter chan struct{}
+
+func (r blockingReporter) Report(context.Context, []workspaceapps.StatsReport) error {
+	<-r
+	return nil
+}
+
+func TestStatsDReporter(t *testing.T) {
+	t.Parallel()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	reporter, err := workspaceapps.NewStatsDReporter(pc.LocalAddr().String())
+	require.NoError(t, err)
+	defer reporter.Close()
+
+	readLines := func() []string {
+		buf := make([]byte, 2048)
+		_ = pc.SetReadDeadline(time.Now().Add(testutil.WaitShort))
+		n, _, err := pc.ReadFrom(buf)
+		require.NoError(t, err)
+		lines := strings.Split(string(buf[:n]), "\n")
+		sort.Strings(lines)
+		return lines
+	}
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	stat := workspaceapps.StatsReport{
+		UserID:           uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		WorkspaceID:      uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		AgentID:          uuid.MustParse("33333333-3333-3333-3333-333333333333"),
+		AccessMethod:     workspaceapps.AccessMethodPath,
+		SlugOrPort:       "code-server",
+		SessionID:        uuid.New(),
+		SessionStartedAt: start,
+		SessionEndedAt:   start.Add(30 * time.Second),
+		Requests:         5,
+		ActiveDuration:   30 * time.Second,
+	}
+	tags := "|c|#access_method:path,slug_or_port:code-server" +
+		",user_id:11111111-1111-1111-1111-111111111111" +
+		",workspace_id:22222222-2222-2222-2222-222222222222" +
+		",agent_id:33333333-3333-3333-3333-333333333333"
+
+	err = reporter.Report(context.Background(), []workspaceapps.StatsReport{stat})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		workspaceapps.StatsMetricRequests + ":5" + tags,
+		workspaceapps.StatsMetricSessionSeconds + ":30" + tags,
+	}, readLines())
+
+	// Reporting the same session again only sends the increase.
+	stat.SessionEndedAt = start.Add(45 * time.Second)
+	stat.Requests = 7
+	stat.ActiveDuration = 45 * time.Second
+	err = reporter.Report(context.Background(), []workspaceapps.StatsReport{stat})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		workspaceapps.StatsMetricRequests + ":2" + tags,
+		workspaceapps.StatsMetricSessionSeconds + ":15" + tags,
+	}, readLines())
+}
+
+func TestStatsDReporter_Batching(t *testing.T) {
+	t.Parallel()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	reporter, err := workspaceapps.NewStatsDReporter(pc.LocalAddr().String())
+	require.NoError(t, err)
+	defer reporter.Close()
+
+	var stats []workspaceapps.StatsReport
+	for i := 0; i < 50; i++ {
+		stats = append(stats, workspaceapps.StatsReport{
+			UserID:       uuid.New(),
+			WorkspaceID:  uuid.New(),
+			AgentID:      uuid.New(),
+			AccessMethod: workspaceapps.AccessMethodSubdomain,
+			SlugOrPort:   "8080",
+			SessionID:    uuid.New(),
+			Requests:     1,
+		})
+	}
+	err = reporter.Report(context.Background(), stats)
+	require.NoError(t, err)
+
+	lines := 0
+	buf := make([]byte, 65535)
+	for lines < len(stats) {
+		_ = pc.SetReadDeadline(time.Now().Add(testutil.WaitShort))
+		n, _, err := pc.ReadFrom(buf)
+		require.NoError(t, err)
+		require.LessOrEqual(t, n, 1432, "packet exceeds max size")
+		lines += strings.Count(string(buf[:n]), "\n") + 1
+	}
+	assert.Equal(t, len(stats), lines)
+}
+
+func TestStatsOTLPReporter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu       sync.Mutex
+		requests []*colmetricspb.ExportMetricsServiceRequest
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !assert.Equal(t, "/v1/metrics", r.URL.Path) ||
+			!assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type")) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if !assert.NoError(t, err) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var req colmetricspb.ExportMetricsServiceRequest
+		if !assert.NoError(t, proto.Unmarshal(body, &req)) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, &req)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	reporter, err := workspaceapps.NewStatsOTLPReporter(srv.Client(), srv.URL)
+	require.NoError(t, err)
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	userID, workspaceID, agentID := uuid.New(), uuid.New(), uuid.New()
+	stats := []workspaceapps.StatsReport{
+		{
+			UserID:           userID,
+			WorkspaceID:      workspaceID,
+			AgentID:          agentID,
+			AccessMethod:     workspaceapps.AccessMethodPath,
+			SlugOrPort:       "code-server",
+			SessionID:        uuid.New(),
+			SessionStartedAt: start,
+			SessionEndedAt:   start.Add(10 * time.Second),
+			Requests:         3,
+			ActiveDuration:   10 * time.Second,
+		},
+		{
+			UserID:           userID,
+			WorkspaceID:      workspaceID,
+			AgentID:          agentID,
+			AccessMethod:     workspaceapps.AccessMethodPath,
+			SlugOrPort:       "code-server",
+			SessionID:        uuid.New(),
+			SessionStartedAt: start,
+			SessionEndedAt:   start.Add(5 * time.Second),
+			Requests:         2,
+			ActiveDuration:   5 * time.Second,
+		},
+	}
+	err = reporter.Report(context.Background(), stats)
+	require.NoError(t, err)
+
+	// Unchanged stats aren't exported again.
+	err = reporter.Report(context.Background(), stats)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, requests, 1)
+	require.Len(t, requests[0].ResourceMetrics, 1)
+	rm := requests[0].ResourceMetrics[0]
+	require.Len(t, rm.Resource.Attributes, 1)
+	assert.Equal(t, "service.name", rm.Resource.Attributes[0].Key)
+	assert.Equal(t, "coderd", rm.Resource.Attributes[0].Value.GetStringValue())
+	require.Len(t, rm.ScopeMetrics, 1)
+	metrics := rm.ScopeMetrics[0].Metrics
+	require.Len(t, metrics, 2)
+
+	// Both sessions belong to the same series, so they're summed.
+	assert.Equal(t, workspaceapps.StatsMetricRequests, metrics[0].Name)
+	require.Len(t, metrics[0].GetSum().DataPoints, 1)
+	assert.True(t, metrics[0].GetSum().IsMonotonic)
+	assert.EqualValues(t, 5, metrics[0].GetSum().DataPoints[0].GetAsInt())
+	attrs := make(map[string]string)
+	for _, kv := range metrics[0].GetSum().DataPoints[0].Attributes {
+		attrs[kv.Key] = kv.Value.GetStringValue()
+	}
+	assert.Equal(t, map[string]string{
+		"access_method": "path",
+		"slug_or_port":  "code-server",
+		"user_id":       userID.String(),
+		"workspace_id":  workspaceID.String(),
+		"agent_id":      agentID.String(),
+	}, attrs)
+
+	assert.Equal(t, workspaceapps.StatsMetricSessionSeconds, metrics[1].Name)
+	require.Len(t, metrics[1].GetSum().DataPoints, 1)
+	assert.EqualValues(t, 15, metrics[1].GetSum().DataPoints[0].GetAsDouble())
+}
+
+func TestNewStatsOTLPReporter(t *testing.T) {
+	t.Parallel()
+
+	_, err := workspaceapps.NewStatsOTLPReporter(nil, "localhost:4318")
+	require.Error(t, err)
+	_, err = workspaceapps.NewStatsOTLPReporter(nil, "http://localhost:4318")
+	require.NoError(t, err)
+}
@@ -33,6 +33,19 @@ type SignedToken struct {
 	WorkspaceID uuid.UUID `json:"workspace_id"`
 	AgentID     uuid.UUID `json:"agent_id"`
 	AppURL      string    `json:"app_url"`
+	// RequesterID is the ID of the user that the token was issued to. It is
+	// uuid.Nil for users that are not signed in to Coder, e.g. when using
+	// public apps.
+	RequesterID uuid.UUID `json:"requester_id"`
+	// OIDCSubject is the subject of the user that the token was issued to if
+	// they logged in with the workspace app OIDC provider. UserID is derived
+	// from it with OIDCUserID.
+	OIDCSubject string `json:"oidc_subject,omitempty"`
+	// RateLimit is the rate limit of the app, applied to each requester.
+	RateLimit RateLimit `json:"rate_limit"`
+	// RewritePaths is true if redirects and HTML of the path-based app are
+	// rewritten to stay under the path the app is served from.
+	RewritePaths bool `json:"rewrite_paths"`
 }
 
 // MatchesRequest returns true if the token matches the request. Any token that
@@ -224,6 +237,239 @@ func (k SecurityKey) DecryptAPIKey(encryptedAPIKey string) (string, error) {
 	return payload.APIKey, nil
 }
 
+// oidcUserNamespace is the UUID namespace of OIDCUserID.
+var oidcUserNamespace = uuid.MustParse("5b9f2a43-6c1e-4d8a-9e57-0f3c2d6a8b14")
+
+// OIDCUserID returns the user ID of a user that logged in with the workspace
+// app OIDC provider. These users don't have a Coder account, so the ID is
+// derived from their OIDC subject and is stable across sessions.
+func OIDCUserID(subject string) uuid.UUID {
+	return uuid.NewSHA1(oidcUserNamespace, []byte(subject))
+}
+
+// OIDCSessionPayload is the payload of the signed session of a user that
+// logged in with the workspace app OIDC provider. The session is only valid
+// for the app host it was issued for.
+type OIDCSessionPayload struct {
+	Subject   string    `json:"subject"`
+	Email     string    `json:"email"`
+	AppHost   string    `json:"app_host"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SignOIDCSession signs the session of a user that logged in with the
+// workspace app OIDC provider.
+func (k SecurityKey) SignOIDCSession(payload OIDCSessionPayload) (string, error) {
+	if payload.Subject == "" || payload.Email == "" || payload.AppHost == "" {
+		return "", xerrors.New("subject, email and app host are required")
+	}
+	if payload.ExpiresAt.IsZero() {
+		return "", xerrors.New("expiry is required")
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", xerrors.Errorf("marshal payload to JSON: %w", err)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: tokenSigningAlgorithm,
+		Key:       k.signingKey(),
+	}, nil)
+	if err != nil {
+		return "", xerrors.Errorf("create signer: %w", err)
+	}
+	signedObject, err := signer.Sign(payloadBytes)
+	if err != nil {
+		return "", xerrors.Errorf("sign payload: %w", err)
+	}
+	serialized, err := signedObject.CompactSerialize()
+	if err != nil {
+		return "", xerrors.Errorf("serialize JWS: %w", err)
+	}
+
+	return serialized, nil
+}
+
+// VerifyOIDCSession undoes SignOIDCSession. If the session is invalid or
+// expired, an error is returned. The caller must check that the app host
+// matches the request.
+func (k SecurityKey) VerifyOIDCSession(str string) (OIDCSessionPayload, error) {
+	object, err := jose.ParseSigned(str)
+	if err != nil {
+		return OIDCSessionPayload{}, xerrors.Errorf("parse JWS: %w", err)
+	}
+	if len(object.Signatures) != 1 {
+		return OIDCSessionPayload{}, xerrors.New("expected 1 signature")
+	}
+	if object.Signatures[0].Header.Algorithm != string(tokenSigningAlgorithm) {
+		return OIDCSessionPayload{}, xerrors.Errorf("expected token signing algorithm to be %q, got %q", tokenSigningAlgorithm, object.Signatures[0].Header.Algorithm)
+	}
+
+	output, err := object.Verify(k.signingKey())
+	if err != nil {
+		return OIDCSessionPayload{}, xerrors.Errorf("verify JWS: %w", err)
+	}
+
+	var payload OIDCSessionPayload
+	err = json.Unmarshal(output, &payload)
+	if err != nil {
+		return OIDCSessionPayload{}, xerrors.Errorf("unmarshal payload: %w", err)
+	}
+	// Signed app tokens use the same key, so make sure this is a session.
+	if payload.Subject == "" || payload.Email == "" || payload.AppHost == "" {
+		return OIDCSessionPayload{}, xerrors.New("not an OIDC session")
+	}
+	if payload.ExpiresAt.Before(database.Now()) {
+		return OIDCSessionPayload{}, xerrors.New("OIDC session expired")
+	}
+
+	return payload, nil
+}
+
+// ReplicaPayload is the payload of the cookie that routes the requests of a
+// browser to a subdomain app through the same replica.
+type ReplicaPayload struct {
+	ReplicaID uuid.UUID `json:"replica_id"`
+}
+
+// SignReplica signs the ID of the replica that serves the requests of a
+// browser to a subdomain app.
+func (k SecurityKey) SignReplica(payload ReplicaPayload) (string, error) {
+	if payload.ReplicaID == uuid.Nil {
+		return "", xerrors.New("replica ID is required")
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", xerrors.Errorf("marshal payload to JSON: %w", err)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: tokenSigningAlgorithm,
+		Key:       k.signingKey(),
+	}, nil)
+	if err != nil {
+		return "", xerrors.Errorf("create signer: %w", err)
+	}
+	signedObject, err := signer.Sign(payloadBytes)
+	if err != nil {
+		return "", xerrors.Errorf("sign payload: %w", err)
+	}
+	serialized, err := signedObject.CompactSerialize()
+	if err != nil {
+		return "", xerrors.Errorf("serialize JWS: %w", err)
+	}
+
+	return serialized, nil
+}
+
+// VerifyReplica undoes SignReplica.
+func (k SecurityKey) VerifyReplica(str string) (ReplicaPayload, error) {
+	object, err := jose.ParseSigned(str)
+	if err != nil {
+		return ReplicaPayload{}, xerrors.Errorf("parse JWS: %w", err)
+	}
+	if len(object.Signatures) != 1 {
+		return ReplicaPayload{}, xerrors.New("expected 1 signature")
+	}
+	if object.Signatures[0].Header.Algorithm != string(tokenSigningAlgorithm) {
+		return ReplicaPayload{}, xerrors.Errorf("expected token signing algorithm to be %q, got %q", tokenSigningAlgorithm, object.Signatures[0].Header.Algorithm)
+	}
+
+	output, err := object.Verify(k.signingKey())
+	if err != nil {
+		return ReplicaPayload{}, xerrors.Errorf("verify JWS: %w", err)
+	}
+
+	var payload ReplicaPayload
+	err = json.Unmarshal(output, &payload)
+	if err != nil {
+		return ReplicaPayload{}, xerrors.Errorf("unmarshal payload: %w", err)
+	}
+	// Signed app tokens use the same key, so make sure this is a replica.
+	if payload.ReplicaID == uuid.Nil {
+		return ReplicaPayload{}, xerrors.New("not a replica")
+	}
+
+	return payload, nil
+}
+
+// ReplicaForwardPayload is the payload of the header that authenticates a
+// request that was forwarded from one replica to another, so the receiving
+// replica serves it instead of routing it again.
+type ReplicaForwardPayload struct {
+	FromReplicaID uuid.UUID `json:"from_replica_id"`
+	ToReplicaID   uuid.UUID `json:"to_replica_id"`
+	Expiry        time.Time `json:"expiry"` // set by SignReplicaForward if unset
+}
+
+// SignReplicaForward signs a request forwarded between replicas. It expires
+// after a minute unless an expiry is set.
+func (k SecurityKey) SignReplicaForward(payload ReplicaForwardPayload) (string, error) {
+	if payload.FromReplicaID == uuid.Nil || payload.ToReplicaID == uuid.Nil {
+		return "", xerrors.New("replica IDs are required")
+	}
+	if payload.Expiry.IsZero() {
+		payload.Expiry = time.Now().Add(time.Minute)
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return "", xerrors.Errorf("marshal payload to JSON: %w", err)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{
+		Algorithm: tokenSigningAlgorithm,
+		Key:       k.signingKey(),
+	}, nil)
+	if err != nil {
+		return "", xerrors.Errorf("create signer: %w", err)
+	}
+	signedObject, err := signer.Sign(payloadBytes)
+	if err != nil {
+		return "", xerrors.Errorf("sign payload: %w", err)
+	}
+	serialized, err := signedObject.CompactSerialize()
+	if err != nil {
+		return "", xerrors.Errorf("serialize JWS: %w", err)
+	}
+
+	return serialized, nil
+}
+
+// VerifyReplicaForward undoes SignReplicaForward, and checks that the payload
+// hasn't expired.
+func (k SecurityKey) VerifyReplicaForward(str string) (ReplicaForwardPayload, error) {
+	object, err := jose.ParseSigned(str)
+	if err != nil {
+		return ReplicaForwardPayload{}, xerrors.Errorf("parse JWS: %w", err)
+	}
+	if len(object.Signatures) != 1 {
+		return ReplicaForwardPayload{}, xerrors.New("expected 1 signature")
+	}
+	if object.Signatures[0].Header.Algorithm != string(tokenSigningAlgorithm) {
+		return ReplicaForwardPayload{}, xerrors.Errorf("expected token signing algorithm to be %q, got %q", tokenSigningAlgorithm, object.Signatures[0].Header.Algorithm)
+	}
+
+	output, err := object.Verify(k.signingKey())
+	if err != nil {
+		return ReplicaForwardPayload{}, xerrors.Errorf("verify JWS: %w", err)
+	}
+
+	var payload ReplicaForwardPayload
+	err = json.Unmarshal(output, &payload)
+	if err != nil {
+		return ReplicaForwardPayload{}, xerrors.Errorf("unmarshal payload: %w", err)
+	}
+	// Replica cookies use the same key, so make sure this was forwarded.
+	if payload.FromReplicaID == uuid.Nil || payload.ToReplicaID == uuid.Nil {
+		return ReplicaForwardPayload{}, xerrors.New("not a forwarded request")
+	}
+	if payload.Expiry.Before(time.Now()) {
+		return ReplicaForwardPayload{}, xerrors.New("forwarded request has expired")
+	}
+
+	return payload, nil
+}
+
 // FromRequest returns the signed token from the request, if it exists and is
 // valid. The caller must check that the token matches the request.
 func FromRequest(r *http.Request, key SecurityKey) (*SignedToken, bool) {
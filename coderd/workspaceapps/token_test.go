@@ -420,3 +420,184 @@ func TestAPIKeyEncryption(t *testing.T) {
 		})
 	})
 }
+
+func TestOIDCSession(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		payload := workspaceapps.OIDCSessionPayload{
+			Subject:   "stakeholder",
+			Email:     "stakeholder@example.com",
+			AppHost:   "app--agent--workspace--user.test.coder.com",
+			ExpiresAt: database.Now().Add(time.Hour).Round(time.Second),
+		}
+		session, err := coderdtest.AppSecurityKey.SignOIDCSession(payload)
+		require.NoError(t, err)
+
+		verified, err := coderdtest.AppSecurityKey.VerifyOIDCSession(session)
+		require.NoError(t, err)
+		require.Equal(t, payload.Subject, verified.Subject)
+		require.Equal(t, payload.Email, verified.Email)
+		require.Equal(t, payload.AppHost, verified.AppHost)
+		require.True(t, payload.ExpiresAt.Equal(verified.ExpiresAt))
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		t.Parallel()
+
+		session, err := coderdtest.AppSecurityKey.SignOIDCSession(workspaceapps.OIDCSessionPayload{
+			Subject:   "stakeholder",
+			Email:     "stakeholder@example.com",
+			AppHost:   "app--agent--workspace--user.test.coder.com",
+			ExpiresAt: database.Now().Add(-time.Hour),
+		})
+		require.NoError(t, err)
+
+		_, err = coderdtest.AppSecurityKey.VerifyOIDCSession(session)
+		require.ErrorContains(t, err, "expired")
+	})
+
+	t.Run("SignedToken", func(t *testing.T) {
+		t.Parallel()
+
+		// Signed app tokens use the same key, but aren't sessions.
+		token, err := coderdtest.AppSecurityKey.SignToken(workspaceapps.SignedToken{
+			Request: workspaceapps.Request{
+				AccessMethod:      workspaceapps.AccessMethodSubdomain,
+				BasePath:          "/",
+				UsernameOrID:      "user",
+				WorkspaceNameOrID: "workspace",
+				AgentNameOrID:     "agent",
+				AppSlugOrPort:     "app",
+			},
+			UserID:      uuid.New(),
+			WorkspaceID: uuid.New(),
+			AgentID:     uuid.New(),
+		})
+		require.NoError(t, err)
+
+		_, err = coderdtest.AppSecurityKey.VerifyOIDCSession(token)
+		require.ErrorContains(t, err, "not an OIDC session")
+	})
+}
+
+func TestReplica(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		payload := workspaceapps.ReplicaPayload{
+			ReplicaID: uuid.New(),
+		}
+		signed, err := coderdtest.AppSecurityKey.SignReplica(payload)
+		require.NoError(t, err)
+
+		verified, err := coderdtest.AppSecurityKey.VerifyReplica(signed)
+		require.NoError(t, err)
+		require.Equal(t, payload.ReplicaID, verified.ReplicaID)
+	})
+
+	t.Run("OtherKey", func(t *testing.T) {
+		t.Parallel()
+
+		var key workspaceapps.SecurityKey
+		copy(key[:], "not the app security key")
+		signed, err := key.SignReplica(workspaceapps.ReplicaPayload{
+			ReplicaID: uuid.New(),
+		})
+		require.NoError(t, err)
+
+		_, err = coderdtest.AppSecurityKey.VerifyReplica(signed)
+		require.ErrorContains(t, err, "verify JWS")
+	})
+
+	t.Run("SignedToken", func(t *testing.T) {
+		t.Parallel()
+
+		// Signed app tokens use the same key, but don't name a replica.
+		token, err := coderdtest.AppSecurityKey.SignToken(workspaceapps.SignedToken{
+			Request: workspaceapps.Request{
+				AccessMethod:      workspaceapps.AccessMethodSubdomain,
+				BasePath:          "/",
+				UsernameOrID:      "user",
+				WorkspaceNameOrID: "workspace",
+				AgentNameOrID:     "agent",
+				AppSlugOrPort:     "app",
+			},
+			UserID:      uuid.New(),
+			WorkspaceID: uuid.New(),
+			AgentID:     uuid.New(),
+		})
+		require.NoError(t, err)
+
+		_, err = coderdtest.AppSecurityKey.VerifyReplica(token)
+		require.ErrorContains(t, err, "not a replica")
+	})
+}
+
+func TestReplicaForward(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		payload := workspaceapps.ReplicaForwardPayload{
+			FromReplicaID: uuid.New(),
+			ToReplicaID:   uuid.New(),
+		}
+		signed, err := coderdtest.AppSecurityKey.SignReplicaForward(payload)
+		require.NoError(t, err)
+
+		verified, err := coderdtest.AppSecurityKey.VerifyReplicaForward(signed)
+		require.NoError(t, err)
+		require.Equal(t, payload.FromReplicaID, verified.FromReplicaID)
+		require.Equal(t, payload.ToReplicaID, verified.ToReplicaID)
+		require.WithinDuration(t, time.Now().Add(time.Minute), verified.Expiry, 5*time.Second)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		t.Parallel()
+
+		signed, err := coderdtest.AppSecurityKey.SignReplicaForward(workspaceapps.ReplicaForwardPayload{
+			FromReplicaID: uuid.New(),
+			ToReplicaID:   uuid.New(),
+			Expiry:        time.Now().Add(-time.Minute),
+		})
+		require.NoError(t, err)
+
+		_, err = coderdtest.AppSecurityKey.VerifyReplicaForward(signed)
+		require.ErrorContains(t, err, "expired")
+	})
+
+	t.Run("OtherKey", func(t *testing.T) {
+		t.Parallel()
+
+		var key workspaceapps.SecurityKey
+		copy(key[:], "not the app security key")
+		signed, err := key.SignReplicaForward(workspaceapps.ReplicaForwardPayload{
+			FromReplicaID: uuid.New(),
+			ToReplicaID:   uuid.New(),
+		})
+		require.NoError(t, err)
+
+		_, err = coderdtest.AppSecurityKey.VerifyReplicaForward(signed)
+		require.ErrorContains(t, err, "verify JWS")
+	})
+
+	t.Run("ReplicaCookie", func(t *testing.T) {
+		t.Parallel()
+
+		// Browsers have a signed replica cookie, which must not be accepted
+		// as a forwarded request.
+		cookie, err := coderdtest.AppSecurityKey.SignReplica(workspaceapps.ReplicaPayload{
+			ReplicaID: uuid.New(),
+		})
+		require.NoError(t, err)
+
+		_, err = coderdtest.AppSecurityKey.VerifyReplicaForward(cookie)
+		require.ErrorContains(t, err, "not a forwarded request")
+	})
+}
@@ -7,9 +7,11 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/coderd"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbgen"
@@ -249,6 +251,160 @@ func TestWorkspaceApplicationAuth(t *testing.T) {
 	}
 }
 
+func TestWorkspaceApplicationOIDC(t *testing.T) {
+	t.Parallel()
+
+	const (
+		audience    = "coder-apps"
+		redirectURI = "http://app--agent--workspace--user.test.coder.com/path"
+	)
+	accessURL, err := url.Parse("http://test.coder.com")
+	require.NoError(t, err)
+
+	setup := func(t *testing.T, emailDomain ...string) (*codersdk.Client, *coderdtest.OIDCConfig, *coderd.API) {
+		t.Helper()
+
+		fake := coderdtest.NewOIDCConfig(t, "")
+		oidcConfig := fake.WorkspaceAppsOIDCConfig(audience)
+		oidcConfig.EmailDomain = emailDomain
+		client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{
+			AccessURL:               accessURL,
+			AppHostname:             "*.test.coder.com",
+			WorkspaceAppsOIDCConfig: oidcConfig,
+		})
+		// This is set by the enterprise license.
+		api.WorkspaceAppsOIDCEnabled.Store(true)
+		_ = coderdtest.CreateFirstUser(t, client)
+		client.HTTPClient.CheckRedirect = func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+		return client, fake, api
+	}
+	// callback fakes the redirect of the provider back to Coder after the
+	// user logged in.
+	callback := func(t *testing.T, client *codersdk.Client, code string) *http.Response {
+		t.Helper()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		unauthed := codersdk.New(client.URL)
+		unauthed.HTTPClient.CheckRedirect = client.HTTPClient.CheckRedirect
+		resp, err := unauthed.Request(ctx, http.MethodGet, "/api/v2/applications/oidc/callback", nil, func(req *http.Request) {
+			req.URL.RawQuery = url.Values{"code": {code}, "state": {"somestate"}}.Encode()
+			req.AddCookie(&http.Cookie{Name: codersdk.OAuth2StateCookie, Value: "somestate"})
+			req.AddCookie(&http.Cookie{Name: codersdk.OAuth2RedirectCookie, Value: redirectURI})
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = resp.Body.Close()
+		})
+		return resp
+	}
+
+	t.Run("Login", func(t *testing.T) {
+		t.Parallel()
+
+		client, _, _ := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Users that are signed in to Coder use the regular app auth.
+		resp, err := client.Request(ctx, http.MethodGet, "/api/v2/applications/oidc", nil, func(req *http.Request) {
+			req.URL.RawQuery = url.Values{workspaceapps.RedirectURIQueryParam: {redirectURI}}.Encode()
+		})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+		loc, err := resp.Location()
+		require.NoError(t, err)
+		require.Equal(t, "/api/v2/applications/auth-redirect", loc.Path)
+		require.Equal(t, redirectURI, loc.Query().Get(workspaceapps.RedirectURIQueryParam))
+
+		// Everybody else logs in with the provider.
+		unauthed := codersdk.New(client.URL)
+		unauthed.HTTPClient.CheckRedirect = client.HTTPClient.CheckRedirect
+		resp, err = unauthed.Request(ctx, http.MethodGet, "/api/v2/applications/oidc", nil, func(req *http.Request) {
+			req.URL.RawQuery = url.Values{workspaceapps.RedirectURIQueryParam: {redirectURI}}.Encode()
+		})
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+		loc, err = resp.Location()
+		require.NoError(t, err)
+		require.Equal(t, "/api/v2/applications/oidc/callback", loc.Path)
+		require.Equal(t, redirectURI, loc.Query().Get("redirect"))
+	})
+
+	t.Run("Callback", func(t *testing.T) {
+		t.Parallel()
+
+		client, fake, _ := setup(t)
+		resp := callback(t, client, fake.EncodeClaims(t, jwt.MapClaims{
+			"aud":   audience,
+			"sub":   "stakeholder",
+			"email": "stakeholder@example.com",
+		}))
+		require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+
+		loc, err := resp.Location()
+		require.NoError(t, err)
+		session, err := coderdtest.AppSecurityKey.VerifyOIDCSession(loc.Query().Get(workspaceapps.SubdomainProxyOIDCSessionParam))
+		require.NoError(t, err)
+		require.Equal(t, "stakeholder", session.Subject)
+		require.Equal(t, "stakeholder@example.com", session.Email)
+		require.Equal(t, "app--agent--workspace--user.test.coder.com", session.AppHost)
+
+		q := loc.Query()
+		q.Del(workspaceapps.SubdomainProxyOIDCSessionParam)
+		loc.RawQuery = q.Encode()
+		require.Equal(t, redirectURI, loc.String())
+	})
+
+	t.Run("NotEntitled", func(t *testing.T) {
+		t.Parallel()
+
+		client, fake, api := setup(t)
+		api.WorkspaceAppsOIDCEnabled.Store(false)
+		resp := callback(t, client, fake.EncodeClaims(t, jwt.MapClaims{
+			"aud":   audience,
+			"email": "stakeholder@example.com",
+		}))
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("WrongAudience", func(t *testing.T) {
+		t.Parallel()
+
+		client, fake, _ := setup(t)
+		resp := callback(t, client, fake.EncodeClaims(t, jwt.MapClaims{
+			"aud":   "another-client",
+			"email": "stakeholder@example.com",
+		}))
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("EmailDomain", func(t *testing.T) {
+		t.Parallel()
+
+		client, fake, _ := setup(t, "coder.com")
+		resp := callback(t, client, fake.EncodeClaims(t, jwt.MapClaims{
+			"aud":   audience,
+			"email": "stakeholder@example.com",
+		}))
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("EmailNotVerified", func(t *testing.T) {
+		t.Parallel()
+
+		client, fake, _ := setup(t)
+		resp := callback(t, client, fake.EncodeClaims(t, jwt.MapClaims{
+			"aud":            audience,
+			"email":          "stakeholder@example.com",
+			"email_verified": false,
+		}))
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
+
 func TestWorkspaceApps(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,311 @@
+// Package workspacebatch runs operations on batches of workspaces.
+package workspacebatch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+)
+
+const (
+	// concurrency is how many jobs of a batch run at the same time.
+	concurrency = 10
+	// runningBatches is how many batches a replica runs at the same time.
+	runningBatches = 4
+	// pollInterval is the interval at which the runner looks for queued
+	// batches.
+	pollInterval = 10 * time.Second
+	// retention is how long finished batches are kept around so their
+	// results can be fetched.
+	retention = 24 * time.Hour
+	// staleTimeout is how long a running batch may go without progress
+	// before another replica picks it up, e.g. because the replica running
+	// it was stopped.
+	staleTimeout = 5 * time.Minute
+)
+
+// Job runs an operation on a workspace. It returns the build it created, or
+// nil if there was nothing to do for the workspace.
+type Job func(ctx context.Context, workspace database.Workspace) (*database.WorkspaceBuild, error)
+
+// Prepare returns the job that runs the operation of batch on each of its
+// workspaces. It is called once each time a replica starts running the batch.
+type Prepare func(ctx context.Context, batch database.WorkspaceBatch) (Job, error)
+
+// Runner runs batches in the background. Batches are stored in the database,
+// so they are run by whichever replica acquires them first, survive restarts,
+// and are not canceled when the client that queued them goes away. Each batch
+// has its own rate limit, so large batches don't flood the database and the
+// provisioners with builds, and don't hold up the batches of other users.
+type Runner struct {
+	ctx           context.Context
+	cancel        context.CancelFunc
+	logger        slog.Logger
+	db            database.Store
+	prepare       Prepare
+	jobsPerSecond float64
+	burst         int
+
+	notify chan struct{}
+	done   chan struct{}
+}
+
+// New creates a Runner and starts its worker. The jobs of each batch start at
+// jobsPerSecond jobs per second, with bursts of up to burst jobs. Close must be
+// called to stop the worker.
+func New(ctx context.Context, logger slog.Logger, db database.Store, prepare Prepare, jobsPerSecond float64, burst int) *Runner {
+	ctx, cancel := context.WithCancel(ctx)
+	r := &Runner{
+		ctx:           ctx,
+		cancel:        cancel,
+		logger:        logger,
+		db:            db,
+		prepare:       prepare,
+		jobsPerSecond: jobsPerSecond,
+		burst:         burst,
+		notify:        make(chan struct{}, 1),
+		done:          make(chan struct{}),
+	}
+	// Pick up the batches that were queued or left running while no
+	// replica was around.
+	r.wake()
+	go r.run()
+	return r
+}
+
+// Enqueue queues a batch that runs operation on workspaceIDs, in order, as
+// initiatorID. query is the search query that matched the workspaces, and is
+// only stored for reference. ctx must be authorized to create the batch for
+// the initiator.
+func (r *Runner) Enqueue(ctx context.Context, initiatorID uuid.UUID, operation, query string, workspaceIDs []uuid.UUID) (database.WorkspaceBatch, error) {
+	var batch database.WorkspaceBatch
+	err := r.db.InTx(func(db database.Store) error {
+		var err error
+		now := database.Now()
+		batch, err = db.InsertWorkspaceBatch(ctx, database.InsertWorkspaceBatchParams{
+			ID:          uuid.New(),
+			InitiatorID: initiatorID,
+			Operation:   operation,
+			Query:       query,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert workspace batch: %w", err)
+		}
+		err = db.InsertWorkspaceBatchWorkspaces(ctx, database.InsertWorkspaceBatchWorkspacesParams{
+			BatchID:      batch.ID,
+			UpdatedAt:    now,
+			WorkspaceIds: workspaceIDs,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert workspace batch workspaces: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return database.WorkspaceBatch{}, err
+	}
+	r.wake()
+	return batch, nil
+}
+
+// wake makes the worker look for queued batches.
+func (r *Runner) wake() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops the worker and waits for it to exit. Running batches are picked
+// up again once they are stale.
+func (r *Runner) Close() {
+	r.cancel()
+	<-r.done
+}
+
+func (r *Runner) run() {
+	defer close(r.done)
+
+	//nolint:gocritic // Batches are authorized when they are queued, and
+	// their jobs are authorized as the initiator by Prepare.
+	ctx := dbauthz.AsSystemRestricted(r.ctx)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	sem := make(chan struct{}, runningBatches)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.notify:
+		}
+
+		err := r.db.DeleteOldWorkspaceBatches(ctx, database.Now().Add(-retention))
+		if err != nil && ctx.Err() == nil {
+			r.logger.Warn(ctx, "delete old workspace batches", slog.Error(err))
+		}
+
+	acquire:
+		for ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+			default:
+				// The next batch is acquired once one of the running
+				// batches completes.
+				break acquire
+			}
+			now := database.Now()
+			batch, err := r.db.AcquireWorkspaceBatch(ctx, database.AcquireWorkspaceBatchParams{
+				Now:         now,
+				StaleBefore: now.Add(-staleTimeout),
+			})
+			if err != nil {
+				<-sem
+				if !errors.Is(err, sql.ErrNoRows) && ctx.Err() == nil {
+					r.logger.Warn(ctx, "acquire workspace batch", slog.Error(err))
+				}
+				break
+			}
+
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-sem
+					wg.Done()
+					r.wake()
+				}()
+				r.process(ctx, batch)
+			}()
+		}
+	}
+}
+
+// process runs the job of each workspace of the batch that has not been run
+// yet, and records the results.
+func (r *Runner) process(ctx context.Context, batch database.WorkspaceBatch) {
+	logger := r.logger.With(slog.F("batch_id", batch.ID))
+
+	job, err := r.prepare(ctx, batch)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Warn(ctx, "prepare workspace batch", slog.Error(err))
+			r.complete(ctx, batch, err)
+		}
+		return
+	}
+	workspaces, err := r.db.GetWorkspaceBatchWorkspacesByBatchID(ctx, batch.ID)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Warn(ctx, "get workspace batch workspaces", slog.Error(err))
+			r.complete(ctx, batch, err)
+		}
+		return
+	}
+
+	limiter := rate.NewLimiter(rate.Limit(r.jobsPerSecond), r.burst)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, workspace := range workspaces {
+		if workspace.Status != database.WorkspaceBatchWorkspaceStatusPending {
+			// The batch was picked up from a replica that went away.
+			continue
+		}
+		if limiter.Wait(ctx) != nil {
+			break
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(workspaceID uuid.UUID) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			r.runJob(ctx, logger, batch, workspaceID, job)
+		}(workspace.WorkspaceID)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		// Leave the batch running, so the remaining workspaces are picked
+		// up once it is stale.
+		return
+	}
+	r.complete(ctx, batch, nil)
+}
+
+// runJob runs the job of a workspace and records its result. It also keeps the
+// batch from going stale while it is running.
+func (r *Runner) runJob(ctx context.Context, logger slog.Logger, batch database.WorkspaceBatch, workspaceID uuid.UUID, job Job) {
+	params := database.UpdateWorkspaceBatchWorkspaceParams{
+		BatchID:     batch.ID,
+		WorkspaceID: workspaceID,
+		Status:      database.WorkspaceBatchWorkspaceStatusSkipped,
+	}
+	workspace, err := r.db.GetWorkspaceByID(ctx, workspaceID)
+	var build *database.WorkspaceBuild
+	if err == nil {
+		build, err = job(ctx, workspace)
+	}
+	if ctx.Err() != nil {
+		// Leave the workspace pending, so it runs when the batch is
+		// picked up again.
+		return
+	}
+	switch {
+	case err != nil:
+		params.Status = database.WorkspaceBatchWorkspaceStatusFailed
+		params.Error = err.Error()
+	case build != nil:
+		params.Status = database.WorkspaceBatchWorkspaceStatusQueued
+		params.BuildID = uuid.NullUUID{UUID: build.ID, Valid: true}
+	}
+	params.UpdatedAt = database.Now()
+	err = r.db.UpdateWorkspaceBatchWorkspace(ctx, params)
+	if err != nil {
+		logger.Warn(ctx, "update workspace batch workspace",
+			slog.F("workspace_id", workspaceID),
+			slog.Error(err),
+		)
+	}
+	err = r.db.UpdateWorkspaceBatchUpdatedAtByID(ctx, database.UpdateWorkspaceBatchUpdatedAtByIDParams{
+		ID:        batch.ID,
+		UpdatedAt: params.UpdatedAt,
+	})
+	if err != nil {
+		logger.Warn(ctx, "update workspace batch", slog.Error(err))
+	}
+}
+
+func (r *Runner) complete(ctx context.Context, batch database.WorkspaceBatch, batchErr error) {
+	params := database.UpdateWorkspaceBatchCompletedByIDParams{
+		ID:        batch.ID,
+		UpdatedAt: database.Now(),
+		Status:    database.WorkspaceBatchStatusCompleted,
+	}
+	if batchErr != nil {
+		params.Status = database.WorkspaceBatchStatusFailed
+		params.Error = batchErr.Error()
+	}
+	err := r.db.UpdateWorkspaceBatchCompletedByID(ctx, params)
+	if err != nil {
+		r.logger.Warn(ctx, "complete workspace batch",
+			slog.F("batch_id", batch.ID),
+			slog.Error(err),
+		)
+	}
+}
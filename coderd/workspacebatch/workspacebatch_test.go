@@ -0,0 +1,162 @@
+package workspacebatch_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/workspacebatch"
+	"github.com/coder/coder/testutil"
+)
+
+func TestRunner(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Results", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		db := dbfake.New()
+		user := dbgen.User(t, db, database.User{})
+
+		workspaceIDs := make([]uuid.UUID, 25)
+		for i := range workspaceIDs {
+			workspaceIDs[i] = dbgen.Workspace(t, db, database.Workspace{OwnerID: user.ID}).ID
+		}
+		failed := workspaceIDs[7]
+		skipped := workspaceIDs[8]
+
+		var ran atomic.Int32
+		runner := workspacebatch.New(ctx, slogtest.Make(t, nil), db, func(_ context.Context, batch database.WorkspaceBatch) (workspacebatch.Job, error) {
+			require.Equal(t, "stop", batch.Operation)
+			return func(_ context.Context, workspace database.Workspace) (*database.WorkspaceBuild, error) {
+				ran.Add(1)
+				switch workspace.ID {
+				case failed:
+					return nil, xerrors.New("failed")
+				case skipped:
+					return nil, nil
+				}
+				return &database.WorkspaceBuild{ID: uuid.New(), WorkspaceID: workspace.ID}, nil
+			}, nil
+		}, 1000, 5)
+		t.Cleanup(runner.Close)
+
+		batch, err := runner.Enqueue(ctx, user.ID, "stop", "owner:me", workspaceIDs)
+		require.NoError(t, err)
+		batch = waitForBatch(ctx, t, db, batch.ID)
+		require.Equal(t, database.WorkspaceBatchStatusCompleted, batch.Status)
+		require.True(t, batch.CompletedAt.Valid)
+		require.EqualValues(t, len(workspaceIDs), ran.Load())
+
+		results, err := db.GetWorkspaceBatchWorkspacesByBatchID(ctx, batch.ID)
+		require.NoError(t, err)
+		require.Len(t, results, len(workspaceIDs))
+		for i, result := range results {
+			require.Equal(t, workspaceIDs[i], result.WorkspaceID)
+			switch result.WorkspaceID {
+			case failed:
+				require.Equal(t, database.WorkspaceBatchWorkspaceStatusFailed, result.Status)
+				require.Equal(t, "failed", result.Error)
+				require.False(t, result.BuildID.Valid)
+			case skipped:
+				require.Equal(t, database.WorkspaceBatchWorkspaceStatusSkipped, result.Status)
+				require.False(t, result.BuildID.Valid)
+			default:
+				require.Equal(t, database.WorkspaceBatchWorkspaceStatusQueued, result.Status)
+				require.True(t, result.BuildID.Valid)
+			}
+		}
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		db := dbfake.New()
+		user := dbgen.User(t, db, database.User{})
+		done := dbgen.Workspace(t, db, database.Workspace{OwnerID: user.ID})
+		pending := dbgen.Workspace(t, db, database.Workspace{OwnerID: user.ID})
+
+		// Queue a batch that was partly run by a replica that went away
+		// before any runner is around.
+		batch, err := db.InsertWorkspaceBatch(ctx, database.InsertWorkspaceBatchParams{
+			ID:          uuid.New(),
+			InitiatorID: user.ID,
+			Operation:   "start",
+			CreatedAt:   database.Now(),
+		})
+		require.NoError(t, err)
+		err = db.InsertWorkspaceBatchWorkspaces(ctx, database.InsertWorkspaceBatchWorkspacesParams{
+			BatchID:      batch.ID,
+			UpdatedAt:    database.Now(),
+			WorkspaceIds: []uuid.UUID{done.ID, pending.ID},
+		})
+		require.NoError(t, err)
+		err = db.UpdateWorkspaceBatchWorkspace(ctx, database.UpdateWorkspaceBatchWorkspaceParams{
+			BatchID:     batch.ID,
+			WorkspaceID: done.ID,
+			Status:      database.WorkspaceBatchWorkspaceStatusSkipped,
+			UpdatedAt:   database.Now(),
+		})
+		require.NoError(t, err)
+
+		var ran []uuid.UUID
+		runner := workspacebatch.New(ctx, slogtest.Make(t, nil), db, func(context.Context, database.WorkspaceBatch) (workspacebatch.Job, error) {
+			return func(_ context.Context, workspace database.Workspace) (*database.WorkspaceBuild, error) {
+				ran = append(ran, workspace.ID)
+				return nil, nil
+			}, nil
+		}, 1000, 5)
+		t.Cleanup(runner.Close)
+
+		batch = waitForBatch(ctx, t, db, batch.ID)
+		require.Equal(t, database.WorkspaceBatchStatusCompleted, batch.Status)
+		require.Equal(t, []uuid.UUID{pending.ID}, ran)
+	})
+
+	t.Run("PrepareFailed", func(t *testing.T) {
+		t.Parallel()
+		ctx := testutil.Context(t, testutil.WaitShort)
+		db := dbfake.New()
+		user := dbgen.User(t, db, database.User{})
+		workspace := dbgen.Workspace(t, db, database.Workspace{OwnerID: user.ID})
+
+		runner := workspacebatch.New(ctx, slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), db, func(context.Context, database.WorkspaceBatch) (workspacebatch.Job, error) {
+			return nil, xerrors.New("initiator is suspended")
+		}, 1000, 5)
+		t.Cleanup(runner.Close)
+
+		batch, err := runner.Enqueue(ctx, user.ID, "stop", "owner:me", []uuid.UUID{workspace.ID})
+		require.NoError(t, err)
+		batch = waitForBatch(ctx, t, db, batch.ID)
+		require.Equal(t, database.WorkspaceBatchStatusFailed, batch.Status)
+		require.Equal(t, "initiator is suspended", batch.Error)
+
+		results, err := db.GetWorkspaceBatchWorkspacesByBatchID(ctx, batch.ID)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Equal(t, database.WorkspaceBatchWorkspaceStatusPending, results[0].Status)
+	})
+}
+
+// waitForBatch waits for the batch to complete and returns it.
+func waitForBatch(ctx context.Context, t *testing.T, db database.Store, id uuid.UUID) database.WorkspaceBatch {
+	t.Helper()
+
+	var batch database.WorkspaceBatch
+	require.Eventually(t, func() bool {
+		var err error
+		batch, err = db.GetWorkspaceBatchByID(ctx, id)
+		require.NoError(t, err)
+		return batch.CompletedAt.Valid
+	}, testutil.WaitShort, testutil.IntervalFast)
+	return batch
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/parametervalidation"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/wsbuilder"
 	"github.com/coder/coder/codersdk"
@@ -317,7 +318,9 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		Initiator(apiKey.UserID).
 		RichParameterValues(createBuild.RichParameterValues).
 		LogLevel(string(createBuild.LogLevel)).
-		DeploymentValues(api.Options.DeploymentValues)
+		DeploymentValues(api.Options.DeploymentValues).
+		DrainAgents(api.DeploymentValues.AgentDrainGracePeriod.Value(), api.AgentInactiveDisconnectTimeout).
+		ParameterValidator(api.parameterValidator)
 
 	if createBuild.TemplateVersionID != uuid.Nil {
 		builder = builder.VersionID(createBuild.TemplateVersionID)
@@ -341,6 +344,16 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 	if len(createBuild.ProvisionerState) > 0 {
 		builder = builder.State(createBuild.ProvisionerState)
 	}
+	if createBuild.Resume {
+		if createBuild.TemplateVersionID != uuid.Nil || createBuild.Orphan ||
+			len(createBuild.ProvisionerState) > 0 || len(createBuild.RichParameterValues) > 0 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Resume uses the template version, parameters and state of the interrupted build, they cannot be set.",
+			})
+			return
+		}
+		builder = builder.Resume()
+	}
 
 	workspaceBuild, provisionerJob, err := builder.Build(
 		ctx,
@@ -360,10 +373,15 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 			api.Logger.Error(ctx, "workspace build error", slog.Error(buildErr.Wrapped))
 		}
 
-		httpapi.Write(ctx, rw, buildErr.Status, codersdk.Response{
+		response := codersdk.Response{
 			Message: buildErr.Message,
 			Detail:  buildErr.Error(),
-		})
+		}
+		var validationErr *parametervalidation.Error
+		if xerrors.As(err, &validationErr) {
+			response.Validations = validationErr.Validations
+		}
+		httpapi.Write(ctx, rw, buildErr.Status, response)
 		return
 	}
 	if err != nil {
@@ -902,7 +920,7 @@ func convertWorkspaceStatus(jobStatus codersdk.ProvisionerJobStatus, transition
 		switch transition {
 		case codersdk.WorkspaceTransitionStart:
 			return codersdk.WorkspaceStatusStarting
-		case codersdk.WorkspaceTransitionStop:
+		case codersdk.WorkspaceTransitionStop, codersdk.WorkspaceTransitionArchive:
 			return codersdk.WorkspaceStatusStopping
 		case codersdk.WorkspaceTransitionDelete:
 			return codersdk.WorkspaceStatusDeleting
@@ -911,7 +929,7 @@ func convertWorkspaceStatus(jobStatus codersdk.ProvisionerJobStatus, transition
 		switch transition {
 		case codersdk.WorkspaceTransitionStart:
 			return codersdk.WorkspaceStatusRunning
-		case codersdk.WorkspaceTransitionStop:
+		case codersdk.WorkspaceTransitionStop, codersdk.WorkspaceTransitionArchive:
 			return codersdk.WorkspaceStatusStopped
 		case codersdk.WorkspaceTransitionDelete:
 			return codersdk.WorkspaceStatusDeleted
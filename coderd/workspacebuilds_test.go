@@ -367,6 +367,100 @@ func TestWorkspaceBuildsProvisionerState(t *testing.T) {
 	})
 }
 
+func TestWorkspaceBuildAdminForced(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	first := coderdtest.CreateFirstUser(t, client)
+	member, _ := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	version := coderdtest.CreateTemplateVersion(t, client, first.OrganizationID, nil)
+	template := coderdtest.CreateTemplate(t, client, first.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+	workspace := coderdtest.CreateWorkspace(t, member, first.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, member, workspace.LatestBuild.ID)
+	require.Equal(t, codersdk.BuildReasonInitiator, workspace.LatestBuild.Reason)
+
+	// The owner stopping their own workspace is a regular build.
+	build, err := member.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+		Transition: codersdk.WorkspaceTransitionStop,
+	})
+	require.NoError(t, err)
+	require.Equal(t, codersdk.BuildReasonInitiator, build.Reason)
+	coderdtest.AwaitWorkspaceBuildJob(t, member, build.ID)
+
+	// An admin starting the workspace of another user forces the build.
+	build, err = client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+		Transition: codersdk.WorkspaceTransitionStart,
+	})
+	require.NoError(t, err)
+	require.Equal(t, codersdk.BuildReasonAdminForced, build.Reason)
+	require.Equal(t, first.UserID, build.InitiatorID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+}
+
+func TestWorkspaceBuildResume(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	partialState := []byte("partially applied")
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:         echo.ParseComplete,
+		ProvisionPlan: echo.ProvisionComplete,
+		ProvisionApply: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					State: partialState,
+					Error: "interrupted",
+				},
+			},
+		}},
+	})
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	build := coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+	require.Equal(t, codersdk.ProvisionerJobFailed, build.Job.Status)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	// The transition must match the interrupted build.
+	_, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+		Transition: codersdk.WorkspaceTransitionStop,
+		Resume:     true,
+	})
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, coderdtest.SDKError(t, err).StatusCode())
+
+	// The version is the one of the interrupted build.
+	_, err = client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+		TemplateVersionID: version.ID,
+		Transition:        codersdk.WorkspaceTransitionStart,
+		Resume:            true,
+	})
+	require.Error(t, err)
+	require.Equal(t, http.StatusBadRequest, coderdtest.SDKError(t, err).StatusCode())
+
+	resumed, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+		Transition: codersdk.WorkspaceTransitionStart,
+		Resume:     true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, codersdk.BuildReasonResume, resumed.Reason)
+	require.Equal(t, build.TemplateVersionID, resumed.TemplateVersionID)
+	require.Equal(t, build.BuildNumber+1, resumed.BuildNumber)
+
+	// The build continues from the state of the interrupted build.
+	state, err := client.WorkspaceBuildState(ctx, resumed.ID)
+	require.NoError(t, err)
+	require.Equal(t, partialState, state)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, resumed.ID)
+}
+
 func TestPatchCancelWorkspaceBuild(t *testing.T) {
 	t.Parallel()
 	t.Run("User is allowed to cancel", func(t *testing.T) {
@@ -0,0 +1,239 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slices"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisionerd/runner"
+)
+
+// @Summary Get workspace build timeline
+// @ID get-workspace-build-timeline
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Builds
+// @Param workspacebuild path string true "Workspace build ID"
+// @Success 200 {object} codersdk.WorkspaceBuildTimeline
+// @Router /workspacebuilds/{workspacebuild}/timeline [get]
+func (api *API) workspaceBuildTimeline(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	workspaceBuild := httpmw.WorkspaceBuildParam(r)
+
+	job, err := api.Database.GetProvisionerJobByID(ctx, workspaceBuild.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	logs, err := api.Database.GetProvisionerLogsAfterID(ctx, database.GetProvisionerLogsAfterIDParams{
+		JobID: job.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner logs.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// Agents only run for workspaces that are started.
+	var (
+		agents  []database.WorkspaceAgent
+		scripts = map[uuid.UUID][]database.WorkspaceAgentScript{}
+	)
+	if workspaceBuild.Transition == database.WorkspaceTransitionStart && job.CompletedAt.Valid {
+		agents, err = api.workspaceBuildAgents(ctx, job.ID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching workspace agents.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		for _, agent := range agents {
+			agentScripts, err := api.Database.GetWorkspaceAgentScriptsByAgentID(ctx, agent.ID)
+			if err != nil {
+				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+					Message: "Internal error fetching workspace agent scripts.",
+					Detail:  err.Error(),
+				})
+				return
+			}
+			scripts[agent.ID] = agentScripts
+		}
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.WorkspaceBuildTimeline{
+		WorkspaceBuildID: workspaceBuild.ID,
+		TraceID:          traceIDFromMetadata(job.TraceMetadata),
+		Entries:          workspaceBuildTimelineEntries(job, logs, agents, scripts, database.Now()),
+	})
+}
+
+func (api *API) workspaceBuildAgents(ctx context.Context, jobID uuid.UUID) ([]database.WorkspaceAgent, error) {
+	// nolint:gocritic // GetWorkspaceResourcesByJobID is a system function.
+	resources, err := api.Database.GetWorkspaceResourcesByJobID(dbauthz.AsSystemRestricted(ctx), jobID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	resourceIDs := make([]uuid.UUID, 0, len(resources))
+	for _, resource := range resources {
+		resourceIDs = append(resourceIDs, resource.ID)
+	}
+	// nolint:gocritic // GetWorkspaceAgentsByResourceIDs is a system function.
+	agents, err := api.Database.GetWorkspaceAgentsByResourceIDs(dbauthz.AsSystemRestricted(ctx), resourceIDs)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	return agents, nil
+}
+
+// workspaceBuildTimelineEntries assembles the stages of a workspace build from
+// its provisioner job, the logs of the job, and the lifecycle of its agents.
+// The provisioner stages are taken from the first to the last log of each
+// stage, except for the last stage, which ends when the job completes.
+func workspaceBuildTimelineEntries(
+	job database.ProvisionerJob,
+	logs []database.ProvisionerJobLog,
+	agents []database.WorkspaceAgent,
+	scripts map[uuid.UUID][]database.WorkspaceAgentScript,
+	now time.Time,
+) []codersdk.WorkspaceBuildTimelineEntry {
+	entries := []codersdk.WorkspaceBuildTimelineEntry{}
+	add := func(entry codersdk.WorkspaceBuildTimelineEntry, endedAt sql.NullTime) {
+		end := now
+		if endedAt.Valid {
+			end = endedAt.Time
+			entry.EndedAt = &end
+		}
+		entry.DurationMS = end.Sub(entry.StartedAt).Milliseconds()
+		entries = append(entries, entry)
+	}
+
+	queueEnd := job.StartedAt
+	if !queueEnd.Valid {
+		// Jobs that are canceled before they're acquired never start.
+		queueEnd = job.CompletedAt
+	}
+	add(codersdk.WorkspaceBuildTimelineEntry{
+		Stage:     codersdk.WorkspaceBuildTimelineStageQueued,
+		StartedAt: job.CreatedAt,
+	}, queueEnd)
+
+	var (
+		stages    []codersdk.WorkspaceBuildTimelineStage
+		firstLogs = map[codersdk.WorkspaceBuildTimelineStage]time.Time{}
+		lastLogs  = map[codersdk.WorkspaceBuildTimelineStage]time.Time{}
+	)
+	for _, log := range logs {
+		var stage codersdk.WorkspaceBuildTimelineStage
+		switch {
+		case log.ProgressStage != "":
+			stage = codersdk.WorkspaceBuildTimelineStage(log.ProgressStage)
+		case log.Source != database.LogSourceProvisioner:
+			// Logs of the provisioner daemon, such as setting up and cleaning
+			// up, aren't part of a stage.
+			continue
+		case log.Stage == runner.PlanStage:
+			// Provisioners that don't report the progress of their stages
+			// are assigned to the plan or apply stage by the stage of the
+			// runner.
+			stage = codersdk.WorkspaceBuildTimelineStagePlan
+		default:
+			stage = codersdk.WorkspaceBuildTimelineStageApply
+		}
+		if _, ok := firstLogs[stage]; !ok {
+			stages = append(stages, stage)
+			firstLogs[stage] = log.CreatedAt
+		}
+		lastLogs[stage] = log.CreatedAt
+	}
+	for i, stage := range stages {
+		end := sql.NullTime{Time: lastLogs[stage], Valid: true}
+		if i == len(stages)-1 {
+			end = job.CompletedAt
+		}
+		add(codersdk.WorkspaceBuildTimelineEntry{
+			Stage:     stage,
+			StartedAt: firstLogs[stage],
+		}, end)
+	}
+
+	agents = slices.Clone(agents)
+	slices.SortFunc(agents, func(a, b database.WorkspaceAgent) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	for _, agent := range agents {
+		agentID := agent.ID
+		add(codersdk.WorkspaceBuildTimelineEntry{
+			Stage:     codersdk.WorkspaceBuildTimelineStageAgentConnect,
+			AgentID:   &agentID,
+			AgentName: agent.Name,
+			StartedAt: agent.CreatedAt,
+		}, agent.FirstConnectedAt)
+		if !agent.StartedAt.Valid {
+			continue
+		}
+		add(codersdk.WorkspaceBuildTimelineEntry{
+			Stage:     codersdk.WorkspaceBuildTimelineStageAgentStartup,
+			AgentID:   &agentID,
+			AgentName: agent.Name,
+			StartedAt: agent.StartedAt.Time,
+		}, agent.ReadyAt)
+		for _, script := range scripts[agent.ID] {
+			if !script.RunOnStart || !script.StartedAt.Valid {
+				continue
+			}
+			add(codersdk.WorkspaceBuildTimelineEntry{
+				Stage:      codersdk.WorkspaceBuildTimelineStageStartupScript,
+				AgentID:    &agentID,
+				AgentName:  agent.Name,
+				ScriptName: script.Name,
+				StartedAt:  script.StartedAt.Time,
+			}, script.EndedAt)
+		}
+	}
+
+	return entries
+}
+
+// traceIDFromMetadata returns the ID of the trace that is propagated in the
+// trace metadata of a provisioner job, or an empty string if there is none.
+func traceIDFromMetadata(raw pqtype.NullRawMessage) string {
+	if !raw.Valid {
+		return ""
+	}
+	var metadata map[string]string
+	err := json.Unmarshal(raw.RawMessage, &metadata)
+	if err != nil {
+		return ""
+	}
+	ctx := propagation.TraceContext{}.Extract(context.Background(), propagation.MapCarrier(metadata))
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
@@ -0,0 +1,87 @@
+package coderd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceBuildTimeline(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse: echo.ParseComplete,
+		ProvisionPlan: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Log{
+				Log: &proto.Log{
+					Level:  proto.LogLevel_INFO,
+					Output: "planning",
+				},
+			},
+		}, {
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{},
+			},
+		}},
+		ProvisionApply: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Log{
+				Log: &proto.Log{
+					Level:  proto.LogLevel_INFO,
+					Output: "applying",
+				},
+			},
+		}, {
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: []*proto.Resource{{
+						Name: "some",
+						Type: "example",
+						Agents: []*proto.Agent{{
+							Id:   "something",
+							Name: "dev",
+							Auth: &proto.Agent_Token{},
+						}},
+					}},
+				},
+			},
+		}},
+	})
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	build := coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	timeline, err := client.WorkspaceBuildTimeline(ctx, build.ID)
+	require.NoError(t, err)
+	require.Equal(t, build.ID, timeline.WorkspaceBuildID)
+
+	stages := make([]codersdk.WorkspaceBuildTimelineStage, 0, len(timeline.Entries))
+	for _, entry := range timeline.Entries {
+		stages = append(stages, entry.Stage)
+	}
+	require.Equal(t, []codersdk.WorkspaceBuildTimelineStage{
+		codersdk.WorkspaceBuildTimelineStageQueued,
+		codersdk.WorkspaceBuildTimelineStagePlan,
+		codersdk.WorkspaceBuildTimelineStageApply,
+		codersdk.WorkspaceBuildTimelineStageAgentConnect,
+	}, stages)
+
+	// The provisioner stages have ended, since the build completed.
+	for _, entry := range timeline.Entries[:3] {
+		require.NotNil(t, entry.EndedAt, entry.Stage)
+		require.GreaterOrEqual(t, entry.DurationMS, int64(0))
+	}
+	// The agent never connects.
+	agent := timeline.Entries[3]
+	require.Equal(t, "dev", agent.AgentName)
+	require.NotNil(t, agent.AgentID)
+	require.Nil(t, agent.EndedAt)
+}
@@ -38,7 +38,7 @@ func (api *API) PrimaryRegion(ctx context.Context) (codersdk.Region, error) {
 
 	return codersdk.Region{
 		ID:               deploymentID,
-		Name:             "primary",
+		Name:             codersdk.PrimaryRegionName,
 		DisplayName:      proxy.DisplayName,
 		IconURL:          proxy.IconUrl,
 		Healthy:          true,
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -17,14 +18,19 @@ import (
 	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/db2sdk"
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/parametervalidation"
+	"github.com/coder/coder/coderd/prebuilds"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/searchquery"
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/coderd/workspacebatch"
 	"github.com/coder/coder/coderd/wsbuilder"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
@@ -40,6 +46,22 @@ var (
 	errDeadlineBeforeStart = xerrors.New("new deadline must be before workspace start time")
 )
 
+const (
+	// maxBatchWorkspaces is the maximum number of workspaces a batch
+	// operation can run on.
+	maxBatchWorkspaces = 1000
+	// workspaceBatchBuildsPerSecond is the rate at which each batch queues
+	// builds.
+	workspaceBatchBuildsPerSecond = 10
+	// workspaceBatchBuildsBurst is how many builds a batch can queue at once
+	// before it's limited to workspaceBatchBuildsPerSecond.
+	workspaceBatchBuildsBurst = 20
+)
+
+// workspaceNameRedirectDuration is how long subdomain app URLs with the
+// previous name of a renamed workspace redirect to the workspace.
+const workspaceNameRedirectDuration = 7 * 24 * time.Hour
+
 // @Summary Get workspace metadata by ID
 // @ID get-workspace-metadata-by-id
 // @Security CoderSessionToken
@@ -102,7 +124,7 @@ func (api *API) workspace(rw http.ResponseWriter, r *http.Request) {
 // @Security CoderSessionToken
 // @Produce json
 // @Tags Workspaces
-// @Param q query string false "Search query in the format `key:value`. Available keys are: owner, template, name, status, has-agent, deleting_by."
+// @Param q query string false "Search query in the format `key:value`. Available keys are: owner, template, name, status, has-agent, locked, lock_reason, outdated, deleting_by, last_used_before, last_used_after, deadline_before, deadline_after, max_deadline_before, max_deadline_after."
 // @Param limit query int false "Page limit"
 // @Param offset query int false "Page offset"
 // @Success 200 {object} codersdk.WorkspacesResponse
@@ -201,6 +223,289 @@ func (api *API) workspaces(rw http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// @Summary Run operation on batch of workspaces
+// @Description Queues an operation on all workspaces that match the query.
+// @Description The batch runs in the background, use the returned ID to follow its progress.
+// @ID run-operation-on-batch-of-workspaces
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Workspaces
+// @Param request body codersdk.BatchWorkspacesRequest true "Batch workspaces request"
+// @Success 201 {object} codersdk.WorkspaceBatch
+// @Router /workspaces/batch [post]
+func (api *API) postWorkspacesBatch(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	apiKey := httpmw.APIKey(r)
+
+	var req codersdk.BatchWorkspacesRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	// Fetch one more workspace than allowed to tell if the query matches
+	// too many workspaces.
+	page := codersdk.Pagination{Limit: maxBatchWorkspaces + 1}
+	filter, postFilter, errs := searchquery.Workspaces(req.Query, page, api.AgentInactiveDisconnectTimeout)
+	if len(errs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid workspace search query.",
+			Validations: errs,
+		})
+		return
+	}
+	if filter.OwnerUsername == "me" {
+		filter.OwnerID = apiKey.UserID
+		filter.OwnerUsername = ""
+	}
+
+	prepared, err := api.HTTPAuth.AuthorizeSQLFilter(r, rbac.ActionRead, rbac.ResourceWorkspace.Type)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error preparing sql filter.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	workspaceRows, err := api.Database.GetAuthorizedWorkspaces(ctx, filter, prepared)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if len(workspaceRows) > maxBatchWorkspaces {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("The query matches more than %d workspaces, narrow it down to run the operation in smaller batches.", maxBatchWorkspaces),
+		})
+		return
+	}
+
+	workspaceIDs := make([]uuid.UUID, 0, len(workspaceRows))
+	for _, workspace := range database.ConvertWorkspaceRows(workspaceRows) {
+		// Prebuilt workspaces are managed by the prebuilds controller until
+		// they are claimed.
+		if workspace.OwnerID == prebuilds.OwnerID {
+			continue
+		}
+		if postFilter.DeletingBy != nil {
+			if !workspace.DeletingAt.Valid {
+				continue
+			}
+			deletingAt := workspace.DeletingAt.Time
+			truncatedDeletionAt := time.Date(deletingAt.Year(), deletingAt.Month(), deletingAt.Day(), 0, 0, 0, 0, deletingAt.Location())
+			if truncatedDeletionAt.After(*postFilter.DeletingBy) {
+				continue
+			}
+		}
+		workspaceIDs = append(workspaceIDs, workspace.ID)
+	}
+
+	batch, err := api.workspaceBatchRunner.Enqueue(ctx, apiKey.UserID, string(req.Operation), req.Query, workspaceIDs)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error queueing workspace batch.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	workspaces, err := api.Database.GetWorkspaceBatchWorkspacesByBatchID(ctx, batch.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace batch workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertWorkspaceBatch(batch, workspaces))
+}
+
+// @Summary Get workspace batch
+// @Description Returns the progress of a batch, with the result of the
+// @Description operation on each of its workspaces.
+// @ID get-workspace-batch
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param workspacebatch path string true "Workspace batch ID" format(uuid)
+// @Success 200 {object} codersdk.WorkspaceBatch
+// @Router /workspaces/batch/{workspacebatch} [get]
+func (api *API) workspaceBatch(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := uuid.Parse(chi.URLParam(r, "workspacebatch"))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Workspace batch ID must be a valid UUID.",
+		})
+		return
+	}
+
+	// Only the user who queued the batch can read it.
+	batch, err := api.Database.GetWorkspaceBatchByID(ctx, id)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace batch.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	workspaces, err := api.Database.GetWorkspaceBatchWorkspacesByBatchID(ctx, batch.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace batch workspaces.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceBatch(batch, workspaces))
+}
+
+// prepareWorkspaceBatch returns the job that runs the operation of a batch on
+// each of its workspaces. Builds are authorized as the user who queued the
+// batch, with the roles they have when the batch runs.
+func (api *API) prepareWorkspaceBatch(ctx context.Context, batch database.WorkspaceBatch) (workspacebatch.Job, error) {
+	roles, err := api.Database.GetAuthorizationUserRoles(ctx, batch.InitiatorID)
+	if err != nil {
+		return nil, xerrors.Errorf("get initiator roles: %w", err)
+	}
+	if roles.Status != database.UserStatusActive {
+		return nil, xerrors.Errorf("initiator %q is %s", roles.Username, roles.Status)
+	}
+	subject := rbac.Subject{
+		ID:     batch.InitiatorID.String(),
+		Roles:  rbac.RoleNames(roles.Roles),
+		Groups: roles.Groups,
+		Scope:  rbac.ScopeAll,
+	}.WithCachedASTValue()
+	authorize := func(ctx context.Context, action rbac.Action, object rbac.Objecter) bool {
+		return api.HTTPAuth.Authorizer.Authorize(ctx, subject, action, object.RBACObject()) == nil
+	}
+
+	operation := codersdk.BatchWorkspaceOperation(batch.Operation)
+	return func(ctx context.Context, workspace database.Workspace) (*database.WorkspaceBuild, error) {
+		ctx = dbauthz.As(ctx, subject)
+		build, err := api.batchWorkspaceBuild(ctx, authorize, batch.InitiatorID, workspace, operation)
+		if err != nil {
+			return nil, xerrors.New(batchWorkspaceError(err))
+		}
+		return build, nil
+	}, nil
+}
+
+// batchWorkspaceBuild creates the build of a batch operation for a workspace.
+// It returns a nil build if the workspace is already in the requested state.
+func (api *API) batchWorkspaceBuild(ctx context.Context, authorize func(ctx context.Context, action rbac.Action, object rbac.Objecter) bool, initiatorID uuid.UUID, workspace database.Workspace, operation codersdk.BatchWorkspaceOperation) (*database.WorkspaceBuild, error) {
+	latestBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		return nil, xerrors.Errorf("get latest build: %w", err)
+	}
+	latestJob, err := api.Database.GetProvisionerJobByID(ctx, latestBuild.JobID)
+	if err != nil {
+		return nil, xerrors.Errorf("get latest build job: %w", err)
+	}
+	succeeded := db2sdk.ProvisionerJobStatus(latestJob) == codersdk.ProvisionerJobSucceeded
+
+	var transition database.WorkspaceTransition
+	activeVersion := false
+	switch operation {
+	case codersdk.BatchWorkspaceOperationStart:
+		transition = database.WorkspaceTransitionStart
+	case codersdk.BatchWorkspaceOperationStop:
+		transition = database.WorkspaceTransitionStop
+	case codersdk.BatchWorkspaceOperationUpdate:
+		template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+		if err != nil {
+			return nil, xerrors.Errorf("get template: %w", err)
+		}
+		if latestBuild.TemplateVersionID == template.ActiveVersionID {
+			return nil, nil
+		}
+		transition = database.WorkspaceTransitionStart
+		activeVersion = true
+	case codersdk.BatchWorkspaceOperationDelete:
+		transition = database.WorkspaceTransitionDelete
+	default:
+		return nil, xerrors.Errorf("unknown operation %q", operation)
+	}
+	if !activeVersion && latestBuild.Transition == transition && succeeded {
+		return nil, nil
+	}
+
+	builder := wsbuilder.New(workspace, transition).
+		Initiator(initiatorID).
+		DeploymentValues(api.Options.DeploymentValues).
+		DrainAgents(api.DeploymentValues.AgentDrainGracePeriod.Value(), api.AgentInactiveDisconnectTimeout).
+		ParameterValidator(api.parameterValidator)
+	if activeVersion {
+		builder = builder.ActiveVersion()
+	}
+
+	build, _, err := builder.Build(ctx, api.Database, func(action rbac.Action, object rbac.Objecter) bool {
+		return authorize(ctx, action, object)
+	})
+	if err != nil {
+		return nil, err
+	}
+	api.publishWorkspaceUpdate(ctx, workspace.ID)
+	return build, nil
+}
+
+// batchWorkspaceError returns the message of the error of a batch operation
+// on a workspace.
+func batchWorkspaceError(err error) string {
+	var validationErr *parametervalidation.Error
+	if xerrors.As(err, &validationErr) {
+		return validationErr.Error()
+	}
+	var buildErr wsbuilder.BuildError
+	if xerrors.As(err, &buildErr) {
+		var authErr dbauthz.NotAuthorizedError
+		if xerrors.As(err, &authErr) {
+			return "Unauthorized."
+		}
+		return buildErr.Message
+	}
+	return err.Error()
+}
+
+func convertWorkspaceBatch(batch database.WorkspaceBatch, workspaces []database.GetWorkspaceBatchWorkspacesByBatchIDRow) codersdk.WorkspaceBatch {
+	apiBatch := codersdk.WorkspaceBatch{
+		ID:        batch.ID,
+		Operation: codersdk.BatchWorkspaceOperation(batch.Operation),
+		Query:     batch.Query,
+		Status:    codersdk.WorkspaceBatchStatus(batch.Status),
+		Error:     batch.Error,
+		CreatedAt: batch.CreatedAt,
+		UpdatedAt: batch.UpdatedAt,
+		Results:   make([]codersdk.BatchWorkspaceResult, 0, len(workspaces)),
+	}
+	if batch.CompletedAt.Valid {
+		apiBatch.CompletedAt = &batch.CompletedAt.Time
+	}
+	for _, workspace := range workspaces {
+		result := codersdk.BatchWorkspaceResult{
+			WorkspaceID:        workspace.WorkspaceID,
+			WorkspaceName:      workspace.WorkspaceName,
+			WorkspaceOwnerName: workspace.WorkspaceOwnerUsername,
+			Status:             codersdk.BatchWorkspaceResultStatus(workspace.Status),
+			Error:              workspace.Error,
+		}
+		if workspace.BuildID.Valid {
+			result.BuildID = &workspace.BuildID.UUID
+		}
+		apiBatch.Results = append(apiBatch.Results, result)
+	}
+	return apiBatch
+}
+
 // @Summary Get workspace metadata by user and workspace name
 // @ID get-workspace-metadata-by-user-and-workspace-name
 // @Security CoderSessionToken
@@ -293,7 +598,6 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 	var (
 		ctx                   = r.Context()
 		organization          = httpmw.OrganizationParam(r)
-		apiKey                = httpmw.APIKey(r)
 		auditor               = api.Auditor.Load()
 		user                  = httpmw.UserParam(r)
 		workspaceResourceInfo = audit.AdditionalFields{
@@ -393,17 +697,52 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
+	api.createWorkspace(rw, r, aReq, createWorkspaceRequest{
+		owner:               user,
+		template:            template,
+		name:                createWorkspace.Name,
+		autostartSchedule:   dbAutostartSchedule,
+		ttl:                 dbTTL,
+		richParameterValues: createWorkspace.RichParameterValues,
+		claimPrebuilt:       true,
+	})
+}
+
+// createWorkspaceRequest is a workspace to create with its first build.
+type createWorkspaceRequest struct {
+	owner             database.User
+	template          database.Template
+	name              string
+	autostartSchedule sql.NullString
+	ttl               sql.NullInt64
+	// versionID is the template version to build. The active version of the
+	// template is built if unset.
+	versionID           uuid.NullUUID
+	richParameterValues []codersdk.WorkspaceBuildParameter
+	// claimPrebuilt assigns a prebuilt workspace of the template to the owner
+	// instead of creating one if the template keeps any.
+	claimPrebuilt bool
+	// cloneSource is the workspace that is cloned, if any.
+	cloneSource uuid.NullUUID
+}
+
+// createWorkspace creates a workspace and its first build, and writes the
+// response. The request must be authorized to create the workspace already.
+func (api *API) createWorkspace(rw http.ResponseWriter, r *http.Request, aReq *audit.Request[database.Workspace], req createWorkspaceRequest) {
+	ctx := r.Context()
+	apiKey := httpmw.APIKey(r)
+
 	// TODO: This should be a system call as the actor might not be able to
 	// read other workspaces. Ideally we check the error on create and look for
 	// a postgres conflict error.
 	workspace, err := api.Database.GetWorkspaceByOwnerIDAndName(ctx, database.GetWorkspaceByOwnerIDAndNameParams{
-		OwnerID: user.ID,
-		Name:    createWorkspace.Name,
+		OwnerID: req.owner.ID,
+		Name:    req.name,
 	})
 	if err == nil {
 		// If the workspace already exists, don't allow creation.
 		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
-			Message: fmt.Sprintf("Workspace %q already exists.", createWorkspace.Name),
+			Message: fmt.Sprintf("Workspace %q already exists.", req.name),
 			Validations: []codersdk.ValidationError{{
 				Field:  "name",
 				Detail: "This value is already in use and should be unique.",
@@ -413,54 +752,107 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 	}
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-			Message: fmt.Sprintf("Internal error fetching workspace by name %q.", createWorkspace.Name),
+			Message: fmt.Sprintf("Internal error fetching workspace by name %q.", req.name),
 			Detail:  err.Error(),
 		})
 		return
 	}
 
+	authorize := func(action rbac.Action, object rbac.Objecter) bool {
+		return api.Authorize(r, action, object)
+	}
+	// The parameters are validated before the workspace is inserted, so the
+	// validation webhook isn't called in the transaction.
+	now := database.Now()
+	newWorkspace := database.Workspace{
+		ID:                uuid.New(),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		OwnerID:           req.owner.ID,
+		OrganizationID:    req.template.OrganizationID,
+		TemplateID:        req.template.ID,
+		Name:              req.name,
+		AutostartSchedule: req.autostartSchedule,
+		Ttl:               req.ttl,
+		// The workspaces page will sort by last used at, and it's useful to
+		// have the newly created workspace at the top of the list!
+		LastUsedAt: now,
+	}
+	builder := wsbuilder.New(newWorkspace, database.WorkspaceTransitionStart).
+		Reason(database.BuildReasonInitiator).
+		Initiator(apiKey.UserID).
+		ActiveVersion().
+		RichParameterValues(req.richParameterValues).
+		ParameterValidator(api.parameterValidator)
+	if req.versionID.Valid {
+		builder = builder.VersionID(req.versionID.UUID)
+	}
+	if req.cloneSource.Valid {
+		builder = builder.CloneSource(req.cloneSource.UUID)
+	}
+	err = builder.ValidateParameters(ctx, api.Database, authorize)
+
 	var (
 		provisionerJob *database.ProvisionerJob
 		workspaceBuild *database.WorkspaceBuild
+		claimed        bool
 	)
-	err = api.Database.InTx(func(db database.Store) error {
-		now := database.Now()
-		// Workspaces are created without any versions.
-		workspace, err = db.InsertWorkspace(ctx, database.InsertWorkspaceParams{
-			ID:                uuid.New(),
-			CreatedAt:         now,
-			UpdatedAt:         now,
-			OwnerID:           user.ID,
-			OrganizationID:    template.OrganizationID,
-			TemplateID:        template.ID,
-			Name:              createWorkspace.Name,
-			AutostartSchedule: dbAutostartSchedule,
-			Ttl:               dbTTL,
-			// The workspaces page will sort by last used at, and it's useful to
-			// have the newly created workspace at the top of the list!
-			LastUsedAt: database.Now(),
-		})
-		if err != nil {
-			return xerrors.Errorf("insert workspace: %w", err)
-		}
+	if err == nil {
+		err = api.Database.InTx(func(db database.Store) error {
+			claimed = false
+			err = prebuilds.ErrNoPrebuiltWorkspace
+			if req.claimPrebuilt {
+				// If the template keeps prebuilt workspaces, one of them is
+				// assigned to the user, so only the start build has to run.
+				workspace, err = api.prebuildsClaimer.Claim(ctx, db, req.template, prebuilds.ClaimParams{
+					OwnerID:             req.owner.ID,
+					Name:                req.name,
+					AutostartSchedule:   req.autostartSchedule,
+					TTL:                 req.ttl,
+					RichParameterValues: req.richParameterValues,
+					Now:                 now,
+				})
+			}
+			switch {
+			case err == nil:
+				claimed = true
+			case errors.Is(err, prebuilds.ErrNoPrebuiltWorkspace):
+				// Workspaces are created without any versions.
+				workspace, err = db.InsertWorkspace(ctx, database.InsertWorkspaceParams{
+					ID:                newWorkspace.ID,
+					CreatedAt:         newWorkspace.CreatedAt,
+					UpdatedAt:         newWorkspace.UpdatedAt,
+					OwnerID:           newWorkspace.OwnerID,
+					OrganizationID:    newWorkspace.OrganizationID,
+					TemplateID:        newWorkspace.TemplateID,
+					Name:              newWorkspace.Name,
+					AutostartSchedule: newWorkspace.AutostartSchedule,
+					Ttl:               newWorkspace.Ttl,
+					LastUsedAt:        newWorkspace.LastUsedAt,
+				})
+				if err != nil {
+					return xerrors.Errorf("insert workspace: %w", err)
+				}
+			default:
+				return xerrors.Errorf("claim prebuilt workspace: %w", err)
+			}
 
-		builder := wsbuilder.New(workspace, database.WorkspaceTransitionStart).
-			Reason(database.BuildReasonInitiator).
-			Initiator(apiKey.UserID).
-			ActiveVersion().
-			RichParameterValues(createWorkspace.RichParameterValues)
-		workspaceBuild, provisionerJob, err = builder.Build(
-			ctx, db, func(action rbac.Action, object rbac.Objecter) bool {
-				return api.Authorize(r, action, object)
-			})
-		return err
-	}, nil)
+			b := builder.Workspace(workspace)
+			workspaceBuild, provisionerJob, err = b.Build(ctx, db, authorize)
+			return err
+		}, nil)
+	}
 	var bldErr wsbuilder.BuildError
 	if xerrors.As(err, &bldErr) {
-		httpapi.Write(ctx, rw, bldErr.Status, codersdk.Response{
+		response := codersdk.Response{
 			Message: bldErr.Message,
 			Detail:  bldErr.Error(),
-		})
+		}
+		var validationErr *parametervalidation.Error
+		if xerrors.As(err, &validationErr) {
+			response.Validations = validationErr.Validations
+		}
+		httpapi.Write(ctx, rw, bldErr.Status, response)
 		return
 	}
 	if err != nil {
@@ -471,6 +863,9 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 	aReq.New = workspace
+	if claimed {
+		api.prebuildsClaimer.Claimed(req.template)
+	}
 
 	initiator, err := api.Database.GetUserByID(ctx, workspaceBuild.InitiatorID)
 	if err != nil {
@@ -486,7 +881,7 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		WorkspaceBuilds: []telemetry.WorkspaceBuild{telemetry.ConvertWorkspaceBuild(*workspaceBuild)},
 	})
 
-	users := []database.User{user, initiator}
+	users := []database.User{req.owner, initiator}
 	apiBuild, err := api.convertWorkspaceBuild(
 		*workspaceBuild,
 		workspace,
@@ -512,11 +907,169 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 	httpapi.Write(ctx, rw, http.StatusCreated, convertWorkspace(
 		workspace,
 		apiBuild,
-		template,
-		findUser(user.ID, users),
+		req.template,
+		findUser(req.owner.ID, users),
 	))
 }
 
+// Clone a workspace for the currently authenticated user. The clone is built
+// from the template version and parameter values of the latest build of the
+// source workspace.
+//
+// @Summary Clone workspace
+// @Description Ephemeral parameters are not copied. Volumes are copied if `copy_volumes` is set and the template version declares the `coder_clone_source_workspace_id` parameter.
+// @ID clone-workspace
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param request body codersdk.CloneWorkspaceRequest true "Clone workspace request"
+// @Success 201 {object} codersdk.Workspace
+// @Router /workspaces/{workspace}/clone [post]
+func (api *API) postWorkspaceClone(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx     = r.Context()
+		source  = httpmw.WorkspaceParam(r)
+		apiKey  = httpmw.APIKey(r)
+		auditor = api.Auditor.Load()
+	)
+
+	user, err := api.Database.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	wriBytes, err := json.Marshal(audit.AdditionalFields{
+		WorkspaceOwner: user.Username,
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "marshal workspace owner name")
+	}
+
+	aReq, commitAudit := audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+		Audit:            *auditor,
+		Log:              api.Logger,
+		Request:          r,
+		Action:           database.AuditActionCreate,
+		AdditionalFields: wriBytes,
+	})
+
+	defer commitAudit()
+
+	// The clone is owned by the user cloning the workspace.
+	if !api.Authorize(r, rbac.ActionCreate,
+		rbac.ResourceWorkspace.InOrg(source.OrganizationID).WithOwner(user.ID.String())) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	// The clone copies the parameter values, and optionally the volumes, of
+	// the source workspace, so reading it isn't enough.
+	if !api.Authorize(r, rbac.ActionUpdate, source) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.CloneWorkspaceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	template, err := api.Database.GetTemplateByID(ctx, source.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if template.Deleted {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: fmt.Sprintf("Template %q has been deleted!", template.Name),
+		})
+		return
+	}
+
+	sourceBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, source.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching latest workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	templateVersionParameters, err := api.Database.GetTemplateVersionParameters(ctx, sourceBuild.TemplateVersionID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	sourceParameters, err := api.Database.GetWorkspaceBuildParameters(ctx, sourceBuild.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace build parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	sourceValues := make(map[string]string, len(sourceParameters))
+	for _, parameter := range sourceParameters {
+		sourceValues[parameter.Name] = parameter.Value
+	}
+	var (
+		richParameterValues  []codersdk.WorkspaceBuildParameter
+		copyVolumesSupported bool
+	)
+	for _, parameter := range templateVersionParameters {
+		switch {
+		case parameter.Name == codersdk.CloneSourceWorkspaceParameterName:
+			copyVolumesSupported = true
+		case parameter.Ephemeral:
+			// Ephemeral values only apply to the build they were given for.
+		default:
+			value, ok := sourceValues[parameter.Name]
+			if !ok {
+				continue
+			}
+			richParameterValues = append(richParameterValues, codersdk.WorkspaceBuildParameter{
+				Name:  parameter.Name,
+				Value: value,
+			})
+		}
+	}
+	if req.CopyVolumes && !copyVolumesSupported {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The template version doesn't support copying volumes.",
+			Validations: []codersdk.ValidationError{{
+				Field:  "copy_volumes",
+				Detail: fmt.Sprintf("The template version must declare the %q parameter.", codersdk.CloneSourceWorkspaceParameterName),
+			}},
+		})
+		return
+	}
+
+	createReq := createWorkspaceRequest{
+		owner:               user,
+		template:            template,
+		name:                req.Name,
+		autostartSchedule:   source.AutostartSchedule,
+		ttl:                 source.Ttl,
+		versionID:           uuid.NullUUID{UUID: sourceBuild.TemplateVersionID, Valid: true},
+		richParameterValues: richParameterValues,
+	}
+	if req.CopyVolumes {
+		createReq.cloneSource = uuid.NullUUID{UUID: source.ID, Valid: true}
+	}
+	api.createWorkspace(rw, r, aReq, createReq)
+}
+
 // @Summary Update workspace metadata by ID
 // @ID update-workspace-metadata-by-id
 // @Security CoderSessionToken
@@ -560,10 +1113,32 @@ func (api *API) patchWorkspace(rw http.ResponseWriter, r *http.Request) {
 		name = req.Name
 	}
 
-	newWorkspace, err := api.Database.UpdateWorkspace(ctx, database.UpdateWorkspaceParams{
-		ID:   workspace.ID,
-		Name: name,
-	})
+	var newWorkspace database.Workspace
+	err := api.Database.InTx(func(db database.Store) error {
+		var err error
+		newWorkspace, err = db.UpdateWorkspace(ctx, database.UpdateWorkspaceParams{
+			ID:   workspace.ID,
+			Name: name,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Subdomain app URLs with the previous name keep working for a
+		// while, so renames don't break bookmarks.
+		now := database.Now()
+		_, err = db.UpsertWorkspaceNameRedirect(ctx, database.UpsertWorkspaceNameRedirectParams{
+			OwnerID:     workspace.OwnerID,
+			Name:        workspace.Name,
+			WorkspaceID: workspace.ID,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(workspaceNameRedirectDuration),
+		})
+		if err != nil {
+			return xerrors.Errorf("insert workspace name redirect: %w", err)
+		}
+		return nil
+	}, nil)
 	if err != nil {
 		// The query protects against updating deleted workspaces and
 		// the existence of the workspace is checked in the request,
@@ -761,26 +1336,61 @@ func (api *API) putWorkspaceTTL(rw http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Update workspace lock by id.
+// @Description The request body is optional, workspaces are locked if it is omitted.
 // @ID update-workspace-lock-by-id
 // @Security CoderSessionToken
 // @Accept json
 // @Produce json
 // @Tags Workspaces
 // @Param workspace path string true "Workspace ID" format(uuid)
-// @Param request body codersdk.UpdateWorkspaceLock true "Lock or unlock a workspace"
+// @Param request body codersdk.UpdateWorkspaceLock false "Lock or unlock a workspace"
 // @Success 200 {object} codersdk.Workspace
 // @Router /workspaces/{workspace}/lock [put]
 func (api *API) putWorkspaceLock(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	workspace := httpmw.WorkspaceParam(r)
 
-	var req codersdk.UpdateWorkspaceLock
-	if !httpapi.Read(ctx, rw, r, &req) {
-		return
+	// Older clients send the desired state in the body, so it is still
+	// accepted to unlock workspaces.
+	req := codersdk.UpdateWorkspaceLock{Lock: true}
+	if r.ContentLength != 0 {
+		if !httpapi.Read(ctx, rw, r, &req) {
+			return
+		}
 	}
 
+	api.setWorkspaceLock(rw, r, req.Lock)
+}
+
+// @Summary Unlock workspace by id.
+// @ID unlock-workspace-by-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Success 200 {object} codersdk.Workspace
+// @Router /workspaces/{workspace}/unlock [put]
+func (api *API) putWorkspaceUnlock(rw http.ResponseWriter, r *http.Request) {
+	api.setWorkspaceLock(rw, r, false)
+}
+
+func (api *API) setWorkspaceLock(rw http.ResponseWriter, r *http.Request, lock bool) {
+	var (
+		ctx               = r.Context()
+		workspace         = httpmw.WorkspaceParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = workspace
+
 	// If the workspace is already in the desired state do nothing!
-	if workspace.LockedAt.Valid == req.Lock {
+	if workspace.LockedAt.Valid == lock {
 		httpapi.Write(ctx, rw, http.StatusNotModified, codersdk.Response{
 			Message: "Nothing to do!",
 		})
@@ -788,15 +1398,19 @@ func (api *API) putWorkspaceLock(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	lockedAt := sql.NullTime{
-		Valid: req.Lock,
+		Valid: lock,
 	}
-	if req.Lock {
+	if lock {
 		lockedAt.Time = database.Now()
 	}
 
 	workspace, err := api.Database.UpdateWorkspaceLockedDeletingAt(ctx, database.UpdateWorkspaceLockedDeletingAtParams{
 		ID:       workspace.ID,
 		LockedAt: lockedAt,
+		LockReason: database.NullWorkspaceLockReason{
+			WorkspaceLockReason: database.WorkspaceLockReasonManual,
+			Valid:               lock,
+		},
 	})
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -805,6 +1419,7 @@ func (api *API) putWorkspaceLock(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	aReq.New = workspace
 
 	data, err := api.workspaceData(ctx, []database.Workspace{workspace})
 	if err != nil {
@@ -814,12 +1429,39 @@ func (api *API) putWorkspaceLock(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	owner := findUser(workspace.OwnerID, data.users)
+
+	if api.Webhooks != nil {
+		event := webhooks.EventWorkspaceUnlocked
+		if lock {
+			event = webhooks.EventWorkspaceLocked
+		}
+		payload := webhooks.Workspace{
+			ID:           workspace.ID,
+			Name:         workspace.Name,
+			OwnerID:      workspace.OwnerID,
+			TemplateID:   workspace.TemplateID,
+			TemplateName: data.templates[0].Name,
+			BuildID:      data.builds[0].ID,
+		}
+		if owner != nil {
+			payload.OwnerName = owner.Username
+			payload.OwnerEmail = owner.Email
+		}
+		if actor, err := api.Database.GetUserByID(ctx, apiKey.UserID); err == nil {
+			payload.Actor = actor.Username
+		}
+		if workspace.DeletingAt.Valid {
+			payload.DeletingAt = &workspace.DeletingAt.Time
+		}
+		api.Webhooks.Dispatch(event, payload)
+	}
 
 	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspace(
 		workspace,
 		data.builds[0],
 		data.templates[0],
-		findUser(workspace.OwnerID, data.users),
+		owner,
 	))
 }
 
@@ -834,18 +1476,114 @@ func (api *API) putWorkspaceLock(rw http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} codersdk.Response
 // @Router /workspaces/{workspace}/extend [put]
 func (api *API) putExtendWorkspace(rw http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	workspace := httpmw.WorkspaceParam(r)
+	var (
+		ctx       = r.Context()
+		workspace = httpmw.WorkspaceParam(r)
+		apiKey    = httpmw.APIKey(r)
+	)
 
 	var req codersdk.PutExtendWorkspaceRequest
 	if !httpapi.Read(ctx, rw, r, &req) {
 		return
 	}
 
+	extended, code, resp, err := api.extendWorkspaceDeadline(ctx, workspace, apiKey.UserID, func(_ database.WorkspaceBuild, job database.ProvisionerJob) (time.Time, error) {
+		newDeadline := req.Deadline.UTC()
+		// NOTE(Cian): Putting the error in the Message field on request from the FE folks.
+		// Normally, we would put the validation error in Validations, but this endpoint is
+		// not tied to a form or specific named user input on the FE.
+		return newDeadline, validWorkspaceDeadline(job.CompletedAt.Time, newDeadline)
+	})
+	if err != nil {
+		api.Logger.Info(ctx, "extending workspace", slog.Error(err))
+	} else {
+		resp.Message = "Deadline updated to " + extended.Deadline.Format(time.RFC3339) + "."
+	}
+	api.publishWorkspaceUpdate(ctx, workspace.ID)
+	httpapi.Write(ctx, rw, code, resp)
+}
+
+// @Summary Extend workspace deadline by duration
+// @Description Extends the deadline of the active workspace build by a
+// @Description duration, bounded by the deadline extension limits of the
+// @Description template.
+// @ID extend-workspace-deadline-by-duration
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param request body codersdk.ExtendWorkspaceRequest true "Extend deadline request"
+// @Success 200 {object} codersdk.ExtendWorkspaceResponse
+// @Router /workspaces/{workspace}/extend [post]
+func (api *API) postExtendWorkspace(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		workspace = httpmw.WorkspaceParam(r)
+		apiKey    = httpmw.APIKey(r)
+	)
+
+	var req codersdk.ExtendWorkspaceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if req.DurationMillis <= 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid extension duration.",
+			Validations: []codersdk.ValidationError{
+				{Field: "duration_ms", Detail: "Must be a positive duration."},
+			},
+		})
+		return
+	}
+	duration := time.Duration(req.DurationMillis) * time.Millisecond
+
+	extended, code, resp, err := api.extendWorkspaceDeadline(ctx, workspace, apiKey.UserID, func(build database.WorkspaceBuild, _ database.ProvisionerJob) (time.Time, error) {
+		return extensionStart(build).Add(duration), nil
+	})
+	if err != nil {
+		api.Logger.Info(ctx, "extending workspace", slog.Error(err))
+		httpapi.Write(ctx, rw, code, resp)
+		return
+	}
+	api.publishWorkspaceUpdate(ctx, workspace.ID)
+	httpapi.Write(ctx, rw, http.StatusOK, extended)
+}
+
+// extensionStart returns the time a deadline extension of the build is
+// measured from. Extensions start from now if the deadline has already
+// passed but the workspace hasn't been stopped yet.
+func extensionStart(build database.WorkspaceBuild) time.Time {
+	start := build.Deadline.UTC()
+	if now := database.Now(); start.Before(now) {
+		start = now
+	}
+	return start
+}
+
+// extendWorkspaceDeadline moves the deadline of the active build of a
+// workspace to the one returned by newDeadline. Both extend routes share it,
+// so moving the deadline later is always bounded by the deadline extension
+// limits of the template and counts towards the per-day limit. Moving it
+// earlier is neither limited nor recorded. The transaction is serializable
+// so concurrent extensions can't both pass the per-day limit.
+func (api *API) extendWorkspaceDeadline(
+	ctx context.Context,
+	workspace database.Workspace,
+	userID uuid.UUID,
+	newDeadline func(build database.WorkspaceBuild, job database.ProvisionerJob) (time.Time, error),
+) (codersdk.ExtendWorkspaceResponse, int, codersdk.Response, error) {
 	code := http.StatusOK
 	resp := codersdk.Response{}
+	var extended codersdk.ExtendWorkspaceResponse
 
 	err := api.Database.InTx(func(s database.Store) error {
+		// Serialization failures retry the transaction, so don't leak the
+		// outcome of a previous attempt.
+		code = http.StatusOK
+		resp = codersdk.Response{}
+		extended = codersdk.ExtendWorkspaceResponse{}
+
 		build, err := s.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
 		if err != nil {
 			code = http.StatusInternalServerError
@@ -878,41 +1616,86 @@ func (api *API) putExtendWorkspace(rw http.ResponseWriter, r *http.Request) {
 			return xerrors.Errorf("workspace shutdown is manual")
 		}
 
-		newDeadline := req.Deadline.UTC()
-		if err := validWorkspaceDeadline(job.CompletedAt.Time, newDeadline); err != nil {
-			// NOTE(Cian): Putting the error in the Message field on request from the FE folks.
-			// Normally, we would put the validation error in Validations, but this endpoint is
-			// not tied to a form or specific named user input on the FE.
+		deadline, err := newDeadline(build, job)
+		if err != nil {
 			code = http.StatusBadRequest
 			resp.Message = "Cannot extend workspace: " + err.Error()
 			return err
 		}
-		if !build.MaxDeadline.IsZero() && newDeadline.After(build.MaxDeadline) {
+		if !build.MaxDeadline.IsZero() && deadline.After(build.MaxDeadline) {
 			code = http.StatusBadRequest
 			resp.Message = "Cannot extend workspace beyond max deadline."
 			return xerrors.New("Cannot extend workspace: deadline is beyond max deadline imposed by template")
 		}
 
+		duration := deadline.Sub(extensionStart(build))
+		var remaining *int32
+		if duration > 0 {
+			template, err := s.GetTemplateByID(ctx, workspace.TemplateID)
+			if err != nil {
+				code = http.StatusInternalServerError
+				resp.Message = "Error fetching workspace template."
+				return xerrors.Errorf("get template: %w", err)
+			}
+
+			maxExtension := time.Duration(template.MaxDeadlineExtension)
+			if maxExtension > 0 && duration > maxExtension {
+				code = http.StatusBadRequest
+				resp.Message = fmt.Sprintf("Cannot extend workspace by more than %s at once.", maxExtension)
+				return xerrors.Errorf("extension %s is longer than the template maximum %s", duration, maxExtension)
+			}
+
+			if template.MaxDeadlineExtensionsPerDay > 0 {
+				count, err := s.GetWorkspaceDeadlineExtensionCount(ctx, database.GetWorkspaceDeadlineExtensionCountParams{
+					WorkspaceID: workspace.ID,
+					CreatedAt:   database.Now().Add(-24 * time.Hour),
+				})
+				if err != nil {
+					code = http.StatusInternalServerError
+					resp.Message = "Error fetching workspace deadline extensions."
+					return xerrors.Errorf("get workspace deadline extension count: %w", err)
+				}
+				if count >= int64(template.MaxDeadlineExtensionsPerDay) {
+					code = http.StatusConflict
+					resp.Message = fmt.Sprintf("Workspace deadline extension limit of %d per 24 hours reached.", template.MaxDeadlineExtensionsPerDay)
+					return xerrors.Errorf("workspace deadline extended %d times within 24 hours", count)
+				}
+				remaining = ptr.Ref(template.MaxDeadlineExtensionsPerDay - int32(count) - 1)
+			}
+		}
+
 		if err := s.UpdateWorkspaceBuildByID(ctx, database.UpdateWorkspaceBuildByIDParams{
 			ID:               build.ID,
 			UpdatedAt:        build.UpdatedAt,
 			ProvisionerState: build.ProvisionerState,
-			Deadline:         newDeadline,
+			Deadline:         deadline,
 			MaxDeadline:      build.MaxDeadline,
 		}); err != nil {
 			code = http.StatusInternalServerError
 			resp.Message = "Failed to extend workspace deadline."
 			return xerrors.Errorf("update workspace build: %w", err)
 		}
-		resp.Message = "Deadline updated to " + newDeadline.Format(time.RFC3339) + "."
 
+		if duration > 0 {
+			if _, err := s.InsertWorkspaceDeadlineExtension(ctx, database.InsertWorkspaceDeadlineExtensionParams{
+				ID:               uuid.New(),
+				WorkspaceID:      workspace.ID,
+				WorkspaceBuildID: build.ID,
+				UserID:           userID,
+				CreatedAt:        database.Now(),
+				Duration:         int64(duration),
+			}); err != nil {
+				code = http.StatusInternalServerError
+				resp.Message = "Failed to record workspace deadline extension."
+				return xerrors.Errorf("insert workspace deadline extension: %w", err)
+			}
+		}
+
+		extended.Deadline = deadline
+		extended.RemainingExtensions = remaining
 		return nil
-	}, nil)
-	if err != nil {
-		api.Logger.Info(ctx, "extending workspace", slog.Error(err))
-	}
-	api.publishWorkspaceUpdate(ctx, workspace.ID)
-	httpapi.Write(ctx, rw, code, resp)
+	}, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	return extended, code, resp, err
 }
 
 // @Summary Watch workspace by ID
@@ -1019,6 +1802,142 @@ func (api *API) watchWorkspace(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// watchWorkspacesInterval is how often updates are flushed to watchers of
+// all workspaces. A workspace that changes several times within an interval
+// is only sent once.
+const watchWorkspacesInterval = time.Second
+
+// @Summary Watch all workspaces
+// @ID watch-all-workspaces
+// @Security CoderSessionToken
+// @Produce text/event-stream
+// @Tags Workspaces
+// @Success 200 {object} codersdk.Response
+// @Router /workspaces/watch [get]
+func (api *API) watchWorkspaces(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceWorkspace) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	sendEvent, senderClosed, err := httpapi.ServerSentEventSender(rw, r)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error setting up server-sent events.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Prevent handler from returning until the sender is closed.
+	defer func() {
+		<-senderClosed
+	}()
+
+	var (
+		pendingMu sync.Mutex
+		pending   = map[uuid.UUID]struct{}{}
+	)
+	cancelSubscribe, err := api.Pubsub.Subscribe(codersdk.WorkspacesNotifyChannel, func(_ context.Context, message []byte) {
+		workspaceID, err := uuid.ParseBytes(message)
+		if err != nil {
+			api.Logger.Warn(ctx, "invalid workspaces update message",
+				slog.F("message", string(message)), slog.Error(err))
+			return
+		}
+		pendingMu.Lock()
+		pending[workspaceID] = struct{}{}
+		pendingMu.Unlock()
+	})
+	if err != nil {
+		_ = sendEvent(ctx, codersdk.ServerSentEvent{
+			Type: codersdk.ServerSentEventTypeError,
+			Data: codersdk.Response{
+				Message: "Internal error subscribing to workspace events.",
+				Detail:  err.Error(),
+			},
+		})
+		return
+	}
+	defer cancelSubscribe()
+
+	sendUpdates := func() {
+		pendingMu.Lock()
+		workspaceIDs := make([]uuid.UUID, 0, len(pending))
+		for workspaceID := range pending {
+			workspaceIDs = append(workspaceIDs, workspaceID)
+		}
+		pending = map[uuid.UUID]struct{}{}
+		pendingMu.Unlock()
+		if len(workspaceIDs) == 0 {
+			return
+		}
+
+		workspaces, err := api.Database.GetWorkspacesByIDs(ctx, workspaceIDs)
+		if err != nil {
+			_ = sendEvent(ctx, codersdk.ServerSentEvent{
+				Type: codersdk.ServerSentEventTypeError,
+				Data: codersdk.Response{
+					Message: "Internal error fetching workspaces.",
+					Detail:  err.Error(),
+				},
+			})
+			return
+		}
+		if len(workspaces) == 0 {
+			return
+		}
+
+		data, err := api.workspaceData(ctx, workspaces)
+		if err != nil {
+			_ = sendEvent(ctx, codersdk.ServerSentEvent{
+				Type: codersdk.ServerSentEventTypeError,
+				Data: codersdk.Response{
+					Message: "Internal error fetching workspace data.",
+					Detail:  err.Error(),
+				},
+			})
+			return
+		}
+		apiWorkspaces, err := convertWorkspaces(workspaces, data)
+		if err != nil {
+			_ = sendEvent(ctx, codersdk.ServerSentEvent{
+				Type: codersdk.ServerSentEventTypeError,
+				Data: codersdk.Response{
+					Message: "Internal error converting workspaces.",
+					Detail:  err.Error(),
+				},
+			})
+			return
+		}
+		for _, workspace := range apiWorkspaces {
+			_ = sendEvent(ctx, codersdk.ServerSentEvent{
+				Type: codersdk.ServerSentEventTypeData,
+				Data: workspace,
+			})
+		}
+	}
+
+	// An initial ping signals to the request that the server is now ready
+	// and the client can begin servicing a channel with data.
+	_ = sendEvent(ctx, codersdk.ServerSentEvent{
+		Type: codersdk.ServerSentEventTypePing,
+	})
+
+	ticker := time.NewTicker(watchWorkspacesInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-senderClosed:
+			return
+		case <-ticker.C:
+			sendUpdates()
+		}
+	}
+}
+
 type workspaceData struct {
 	templates []database.Template
 	builds    []codersdk.WorkspaceBuild
@@ -1033,7 +1952,11 @@ func (api *API) workspaceData(ctx context.Context, workspaces []database.Workspa
 		templateIDs = append(templateIDs, workspace.TemplateID)
 	}
 
-	templates, err := api.Database.GetTemplatesWithFilter(ctx, database.GetTemplatesWithFilterParams{
+	// Workspaces can be shared with groups that can't read the template of
+	// the workspace, so the templates are fetched as the system. Only the
+	// templates of workspaces the user can read are returned.
+	// nolint:gocritic
+	templates, err := api.Database.GetTemplatesWithFilter(dbauthz.AsSystemRestricted(ctx), database.GetTemplatesWithFilterParams{
 		IDs: templateIDs,
 	})
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
@@ -1129,6 +2052,11 @@ func convertWorkspace(
 		lockedAt = &workspace.LockedAt.Time
 	}
 
+	var lockReason codersdk.WorkspaceLockReason
+	if workspace.LockReason.Valid {
+		lockReason = codersdk.WorkspaceLockReason(workspace.LockReason.WorkspaceLockReason)
+	}
+
 	var deletedAt *time.Time
 	if workspace.DeletingAt.Valid {
 		deletedAt = &workspace.DeletingAt.Time
@@ -1165,6 +2093,7 @@ func convertWorkspace(
 		LastUsedAt:                           workspace.LastUsedAt,
 		DeletingAt:                           deletedAt,
 		LockedAt:                             lockedAt,
+		LockReason:                           lockReason,
 		Health: codersdk.WorkspaceHealth{
 			Healthy:       len(failingAgents) == 0,
 			FailingAgents: failingAgents,
@@ -1243,14 +2172,21 @@ func validWorkspaceSchedule(s *string) (sql.NullString, error) {
 		return sql.NullString{}, nil
 	}
 
-	_, err := schedule.Weekly(*s)
+	sched, err := schedule.Autostart(*s)
 	if err != nil {
 		return sql.NullString{}, err
 	}
 
+	// Human-readable schedules are stored as the equivalent cron expression
+	// so they can be parsed with schedule.Weekly.
+	value := *s
+	if _, err := schedule.Weekly(*s); err != nil {
+		value = sched.String()
+	}
+
 	return sql.NullString{
 		Valid:  true,
-		String: *s,
+		String: value,
 	}, nil
 }
 
@@ -1260,6 +2196,11 @@ func (api *API) publishWorkspaceUpdate(ctx context.Context, workspaceID uuid.UUI
 		api.Logger.Warn(ctx, "failed to publish workspace update",
 			slog.F("workspace_id", workspaceID), slog.Error(err))
 	}
+	err = api.Pubsub.Publish(codersdk.WorkspacesNotifyChannel, []byte(workspaceID.String()))
+	if err != nil {
+		api.Logger.Warn(ctx, "failed to publish workspaces update",
+			slog.F("workspace_id", workspaceID), slog.Error(err))
+	}
 }
 
 func (api *API) publishWorkspaceAgentLogsUpdate(ctx context.Context, workspaceAgentID uuid.UUID, m agentsdk.LogsNotifyMessage) {
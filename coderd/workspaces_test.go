@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"sync/atomic"
@@ -30,6 +31,7 @@ import (
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/coderd/webhooks"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
 	"github.com/coder/coder/cryptorand"
@@ -1361,12 +1363,12 @@ func TestWorkspaceFilterManual(t *testing.T) {
 		client := coderdtest.New(t, &coderdtest.Options{
 			IncludeProvisionerDaemon: true,
 			TemplateScheduleStore: schedule.MockTemplateScheduleStore{
-				SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, error) {
+				SetFn: func(ctx context.Context, db database.Store, template database.Template, options schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
 					if atomic.AddInt64(&setCalled, 1) == 2 {
 						assert.Equal(t, inactivityTTL, options.InactivityTTL)
 					}
 					template.InactivityTTL = int64(options.InactivityTTL)
-					return template, nil
+					return template, schedule.TemplateScheduleChanges{}, nil
 				},
 			},
 		})
@@ -1447,6 +1449,91 @@ func TestWorkspaceFilterManual(t *testing.T) {
 		require.Len(t, res.Workspaces, 1)
 		require.NotNil(t, res.Workspaces[0].LockedAt)
 	})
+
+	t.Run("LockReason", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		lockedWorkspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		_ = coderdtest.AwaitWorkspaceBuildJob(t, client, lockedWorkspace.LatestBuild.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		_ = coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		err := client.UpdateWorkspaceLock(ctx, lockedWorkspace.ID, codersdk.UpdateWorkspaceLock{
+			Lock: true,
+		})
+		require.NoError(t, err)
+
+		res, err := client.Workspaces(ctx, codersdk.WorkspaceFilter{
+			FilterQuery: "locked:true",
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Workspaces, 1)
+		require.Equal(t, lockedWorkspace.ID, res.Workspaces[0].ID)
+
+		res, err = client.Workspaces(ctx, codersdk.WorkspaceFilter{
+			FilterQuery: "lock_reason:manual",
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Workspaces, 1)
+		require.Equal(t, lockedWorkspace.ID, res.Workspaces[0].ID)
+
+		res, err = client.Workspaces(ctx, codersdk.WorkspaceFilter{
+			FilterQuery: "lock_reason:inactivity",
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Workspaces, 0)
+	})
+
+	t.Run("Outdated", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		outdatedWorkspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		_ = coderdtest.AwaitWorkspaceBuildJob(t, client, outdatedWorkspace.LatestBuild.ID)
+
+		newVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, newVersion.ID)
+		err := client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: newVersion.ID,
+		})
+		require.NoError(t, err)
+
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		_ = coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		res, err := client.Workspaces(ctx, codersdk.WorkspaceFilter{
+			FilterQuery: "outdated:true",
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Workspaces, 1)
+		require.Equal(t, outdatedWorkspace.ID, res.Workspaces[0].ID)
+
+		res, err = client.Workspaces(ctx, codersdk.WorkspaceFilter{
+			FilterQuery: "outdated:false",
+		})
+		require.NoError(t, err)
+		require.Len(t, res.Workspaces, 1)
+		require.Equal(t, workspace.ID, res.Workspaces[0].ID)
+	})
 }
 
 func TestOffsetLimit(t *testing.T) {
@@ -1646,6 +1733,7 @@ func TestWorkspaceUpdateAutostart(t *testing.T) {
 	testCases := []struct {
 		name             string
 		schedule         *string
+		expectedSchedule string
 		expectedError    string
 		at               time.Time
 		expectedNext     time.Time
@@ -1690,6 +1778,14 @@ func TestWorkspaceUpdateAutostart(t *testing.T) {
 			expectedNext:     time.Date(2022, 10, 30, 9, 30, 0, 0, dublinLoc),
 			expectedInterval: 24*time.Hour + 59*time.Minute,
 		},
+		{
+			name:             "human-readable",
+			schedule:         ptr.Ref("weekdays 9:30am Europe/Dublin"),
+			expectedSchedule: "CRON_TZ=Europe/Dublin 30 9 * * 1-5",
+			at:               time.Date(2022, 5, 6, 9, 31, 0, 0, dublinLoc),
+			expectedNext:     time.Date(2022, 5, 9, 9, 30, 0, 0, dublinLoc),
+			expectedInterval: 71*time.Hour + 59*time.Minute,
+		},
 		{
 			name:          "invalid location",
 			schedule:      ptr.Ref("CRON_TZ=Imaginary/Place 30 9 * * 1-5"),
@@ -1698,7 +1794,7 @@ func TestWorkspaceUpdateAutostart(t *testing.T) {
 		{
 			name:          "invalid schedule",
 			schedule:      ptr.Ref("asdf asdf asdf "),
-			expectedError: `validate weekly schedule: expected schedule to consist of 5 fields with an optional CRON_TZ=<timezone> prefix`,
+			expectedError: `unrecognized "asdf"`,
 		},
 		{
 			name:          "only 3 values",
@@ -1752,7 +1848,11 @@ func TestWorkspaceUpdateAutostart(t *testing.T) {
 				return
 			}
 
-			require.EqualValues(t, *testCase.schedule, *updated.AutostartSchedule, "expected autostart schedule to equal requested")
+			expectedSchedule := *testCase.schedule
+			if testCase.expectedSchedule != "" {
+				expectedSchedule = testCase.expectedSchedule
+			}
+			require.EqualValues(t, expectedSchedule, *updated.AutostartSchedule, "expected autostart schedule to equal requested")
 
 			sched, err := schedule.Weekly(*updated.AutostartSchedule)
 			require.NoError(t, err, "parse returned schedule")
@@ -1784,8 +1884,8 @@ func TestWorkspaceUpdateAutostart(t *testing.T) {
 						RestartRequirement:   schedule.TemplateRestartRequirement{},
 					}, nil
 				},
-				SetFn: func(_ context.Context, _ database.Store, tpl database.Template, _ schedule.TemplateScheduleOptions) (database.Template, error) {
-					return tpl, nil
+				SetFn: func(_ context.Context, _ database.Store, tpl database.Template, _ schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
+					return tpl, schedule.TemplateScheduleChanges{}, nil
 				},
 			}
 
@@ -1951,8 +2051,8 @@ func TestWorkspaceUpdateTTL(t *testing.T) {
 						RestartRequirement:   schedule.TemplateRestartRequirement{},
 					}, nil
 				},
-				SetFn: func(_ context.Context, _ database.Store, tpl database.Template, _ schedule.TemplateScheduleOptions) (database.Template, error) {
-					return tpl, nil
+				SetFn: func(_ context.Context, _ database.Store, tpl database.Template, _ schedule.TemplateScheduleOptions) (database.Template, schedule.TemplateScheduleChanges, error) {
+					return tpl, schedule.TemplateScheduleChanges{}, nil
 				},
 			}
 
@@ -2074,6 +2174,191 @@ func TestWorkspaceExtend(t *testing.T) {
 	require.WithinDuration(t, oldDeadline.Add(-time.Hour), updated.LatestBuild.Deadline.Time, time.Minute)
 }
 
+func TestWorkspaceExtendDuration(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Limits", func(t *testing.T) {
+		t.Parallel()
+		var (
+			ttl       = 8 * time.Hour
+			client    = coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+			user      = coderdtest.CreateFirstUser(t, client)
+			version   = coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+			_         = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+			template  = coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+			workspace = coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+				cwr.TTLMillis = ptr.Ref(ttl.Milliseconds())
+			})
+			_ = coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		template, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DefaultTTLMillis:            template.DefaultTTLMillis,
+			MaxDeadlineExtensionsPerDay: ptr.Ref(int32(2)),
+			MaxDeadlineExtensionMillis:  ptr.Ref(time.Hour.Milliseconds()),
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 2, template.MaxDeadlineExtensionsPerDay)
+		require.Equal(t, time.Hour.Milliseconds(), template.MaxDeadlineExtensionMillis)
+
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		deadline := workspace.LatestBuild.Deadline.Time
+
+		// Extensions longer than the template maximum fail.
+		_, err = client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: (2 * time.Hour).Milliseconds(),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		resp, err := client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: time.Hour.Milliseconds(),
+		})
+		require.NoError(t, err)
+		require.WithinDuration(t, deadline.Add(time.Hour), resp.Deadline, time.Second)
+		require.NotNil(t, resp.RemainingExtensions)
+		require.EqualValues(t, 1, *resp.RemainingExtensions)
+
+		resp, err = client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: (30 * time.Minute).Milliseconds(),
+		})
+		require.NoError(t, err)
+		require.WithinDuration(t, deadline.Add(90*time.Minute), resp.Deadline, time.Second)
+		require.NotNil(t, resp.RemainingExtensions)
+		require.Zero(t, *resp.RemainingExtensions)
+
+		updated, err := client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.WithinDuration(t, resp.Deadline, updated.LatestBuild.Deadline.Time, time.Second)
+
+		// The third extension within 24 hours fails.
+		_, err = client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: (30 * time.Minute).Milliseconds(),
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+	t.Run("PutLimits", func(t *testing.T) {
+		t.Parallel()
+		var (
+			ttl       = 8 * time.Hour
+			client    = coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+			user      = coderdtest.CreateFirstUser(t, client)
+			version   = coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+			_         = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+			template  = coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+			workspace = coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+				cwr.TTLMillis = ptr.Ref(ttl.Milliseconds())
+			})
+			_ = coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			DefaultTTLMillis:            template.DefaultTTLMillis,
+			MaxDeadlineExtensionsPerDay: ptr.Ref(int32(2)),
+			MaxDeadlineExtensionMillis:  ptr.Ref(time.Hour.Milliseconds()),
+		})
+		require.NoError(t, err)
+
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		deadline := workspace.LatestBuild.Deadline.Time
+
+		// Setting a deadline further out than the template maximum fails.
+		err = client.PutExtendWorkspace(ctx, workspace.ID, codersdk.PutExtendWorkspaceRequest{
+			Deadline: deadline.Add(2 * time.Hour),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		err = client.PutExtendWorkspace(ctx, workspace.ID, codersdk.PutExtendWorkspaceRequest{
+			Deadline: deadline.Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		// Moving the deadline earlier doesn't count towards the limit.
+		err = client.PutExtendWorkspace(ctx, workspace.ID, codersdk.PutExtendWorkspaceRequest{
+			Deadline: deadline,
+		})
+		require.NoError(t, err)
+
+		// Both routes share the per-day limit.
+		resp, err := client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: (30 * time.Minute).Milliseconds(),
+		})
+		require.NoError(t, err)
+		require.NotNil(t, resp.RemainingExtensions)
+		require.Zero(t, *resp.RemainingExtensions)
+
+		err = client.PutExtendWorkspace(ctx, workspace.ID, codersdk.PutExtendWorkspaceRequest{
+			Deadline: resp.Deadline.Add(30 * time.Minute),
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		updated, err := client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.WithinDuration(t, resp.Deadline, updated.LatestBuild.Deadline.Time, time.Second)
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		t.Parallel()
+		var (
+			client    = coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+			user      = coderdtest.CreateFirstUser(t, client)
+			version   = coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+			_         = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+			template  = coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+			workspace = coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+			_         = coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		workspace, err := client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		deadline := workspace.LatestBuild.Deadline.Time
+
+		resp, err := client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: (4 * time.Hour).Milliseconds(),
+		})
+		require.NoError(t, err)
+		require.WithinDuration(t, deadline.Add(4*time.Hour), resp.Deadline, time.Second)
+		require.Nil(t, resp.RemainingExtensions)
+	})
+
+	t.Run("ManualShutdown", func(t *testing.T) {
+		t.Parallel()
+		var (
+			client    = coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+			user      = coderdtest.CreateFirstUser(t, client)
+			version   = coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+			_         = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+			template  = coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+			workspace = coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+				cwr.TTLMillis = nil
+			})
+			_ = coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.ExtendWorkspace(ctx, workspace.ID, codersdk.ExtendWorkspaceRequest{
+			DurationMillis: time.Hour.Milliseconds(),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+}
+
 func TestWorkspaceWatcher(t *testing.T) {
 	t.Parallel()
 	client, closeFunc := coderdtest.NewWithProvisionerCloser(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
@@ -2225,6 +2510,62 @@ func TestWorkspaceWatcher(t *testing.T) {
 	wait("second is for the build cancel", nil)
 }
 
+func TestWorkspacesWatcher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Updates", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		otherWorkspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, otherWorkspace.LatestBuild.ID)
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		wc, err := client.WatchWorkspaces(ctx)
+		require.NoError(t, err)
+
+		build := coderdtest.CreateWorkspaceBuild(t, client, workspace, database.WorkspaceTransitionStop)
+		otherBuild := coderdtest.CreateWorkspaceBuild(t, client, otherWorkspace, database.WorkspaceTransitionStop)
+
+		// Updates of both workspaces arrive on the same stream, in no
+		// particular order.
+		stopped := map[uuid.UUID]uuid.UUID{}
+		for len(stopped) < 2 {
+			select {
+			case <-ctx.Done():
+				require.FailNow(t, "timed out waiting for workspaces to stop")
+			case w, ok := <-wc:
+				require.True(t, ok, "watch channel closed")
+				if w.LatestBuild.Status == codersdk.WorkspaceStatusStopped {
+					stopped[w.ID] = w.LatestBuild.ID
+				}
+			}
+		}
+		require.Equal(t, build.ID, stopped[workspace.ID])
+		require.Equal(t, otherBuild.ID, stopped[otherWorkspace.ID])
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := memberClient.WatchWorkspaces(ctx)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
+
 func mustLocation(t *testing.T, location string) *time.Location {
 	t.Helper()
 	loc, err := time.LoadLocation(location)
@@ -2760,6 +3101,7 @@ func TestWorkspaceLock(t *testing.T) {
 		require.Nil(t, workspace.DeletingAt)
 		require.NotNil(t, workspace.LockedAt)
 		require.WithinRange(t, *workspace.LockedAt, time.Now().Add(-time.Second*10), time.Now())
+		require.Equal(t, codersdk.WorkspaceLockReasonManual, workspace.LockReason)
 		require.Equal(t, lastUsedAt, workspace.LastUsedAt)
 
 		workspace = coderdtest.MustWorkspace(t, client, workspace.ID)
@@ -2772,6 +3114,7 @@ func TestWorkspaceLock(t *testing.T) {
 		workspace, err = client.Workspace(ctx, workspace.ID)
 		require.NoError(t, err, "fetch provisioned workspace")
 		require.Nil(t, workspace.LockedAt)
+		require.Empty(t, workspace.LockReason)
 		// The template doesn't have a locked_ttl set so this should be nil.
 		require.Nil(t, workspace.DeletingAt)
 		// The last_used_at should get updated when we unlock the workspace.
@@ -2814,4 +3157,462 @@ func TestWorkspaceLock(t *testing.T) {
 		require.NoError(t, err)
 		coderdtest.MustTransitionWorkspace(t, client, workspace.ID, database.WorkspaceTransitionStop, database.WorkspaceTransitionStart)
 	})
+
+	t.Run("AuditAndWebhooks", func(t *testing.T) {
+		t.Parallel()
+
+		payloads := make(chan webhooks.Payload, 2)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload webhooks.Payload
+			if assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload)) {
+				payloads <- payload
+			}
+		}))
+		defer srv.Close()
+		dispatcher, err := webhooks.New(slogtest.Make(t, nil), webhooks.Options{
+			URLs: []string{srv.URL},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = dispatcher.Close() })
+
+		var (
+			auditor   = audit.NewMock()
+			client    = coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true, Auditor: auditor, Webhooks: dispatcher})
+			owner     = coderdtest.CreateFirstUser(t, client)
+			version   = coderdtest.CreateTemplateVersion(t, client, owner.OrganizationID, nil)
+			_         = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+			template  = coderdtest.CreateTemplate(t, client, owner.OrganizationID, version.ID)
+			workspace = coderdtest.CreateWorkspace(t, client, owner.OrganizationID, template.ID)
+			_         = coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		awaitPayload := func() webhooks.Payload {
+			select {
+			case payload := <-payloads:
+				return payload
+			case <-ctx.Done():
+				t.Fatal("timed out waiting for webhook")
+				return webhooks.Payload{}
+			}
+		}
+
+		auditor.ResetLogs()
+		err = client.LockWorkspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.NotNil(t, coderdtest.MustWorkspace(t, client, workspace.ID).LockedAt)
+		require.Len(t, auditor.AuditLogs(), 1)
+		require.Equal(t, database.AuditActionWrite, auditor.AuditLogs()[0].Action)
+		require.Equal(t, workspace.ID, auditor.AuditLogs()[0].ResourceID)
+
+		payload := awaitPayload()
+		require.Equal(t, webhooks.EventWorkspaceLocked, payload.Event)
+		require.Equal(t, workspace.ID, payload.Workspace.ID)
+		require.Equal(t, workspace.OwnerName, payload.Workspace.OwnerName)
+		require.Equal(t, workspace.OwnerName, payload.Workspace.Actor)
+
+		// Locking a locked workspace does nothing.
+		err = client.LockWorkspace(ctx, workspace.ID)
+		require.NoError(t, err)
+
+		err = client.UnlockWorkspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Nil(t, coderdtest.MustWorkspace(t, client, workspace.ID).LockedAt)
+
+		payload = awaitPayload()
+		require.Equal(t, webhooks.EventWorkspaceUnlocked, payload.Event)
+		require.Equal(t, workspace.ID, payload.Workspace.ID)
+	})
+}
+
+func TestWorkspaceClone(t *testing.T) {
+	t.Parallel()
+
+	// cloneResponses returns a template version with a mutable, an ephemeral
+	// and, optionally, the clone source parameter.
+	cloneResponses := func(cloneSource bool) *echo.Responses {
+		parameters := []*proto.RichParameter{
+			{Name: "region", Type: "string", DefaultValue: "us-east-1", Mutable: true},
+			{Name: "force_rebuild", Type: "bool", DefaultValue: "false", Mutable: true, Ephemeral: true},
+		}
+		if cloneSource {
+			parameters = append(parameters, &proto.RichParameter{
+				Name:         codersdk.CloneSourceWorkspaceParameterName,
+				Type:         "string",
+				DefaultValue: "",
+			})
+		}
+		return &echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionPlan: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Parameters: parameters,
+					},
+				},
+			}},
+			ProvisionApply: echo.ProvisionComplete,
+		}
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, cloneResponses(false))
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.RichParameterValues = []codersdk.WorkspaceBuildParameter{
+				{Name: "region", Value: "eu-west-1"},
+				{Name: "force_rebuild", Value: "true"},
+			}
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		// The clone uses the version of the source, not the active one.
+		ctx := testutil.Context(t, testutil.WaitLong)
+		newVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, cloneResponses(false), template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, newVersion.ID)
+		err := client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: newVersion.ID,
+		})
+		require.NoError(t, err)
+
+		clone, err := client.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name: "clone",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "clone", clone.Name)
+		require.Equal(t, source.TemplateID, clone.TemplateID)
+		require.Equal(t, source.TTLMillis, clone.TTLMillis)
+		require.Equal(t, version.ID, clone.LatestBuild.TemplateVersionID)
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, clone.LatestBuild.ID)
+		require.Equal(t, codersdk.WorkspaceStatusRunning, build.Status)
+
+		parameters, err := client.WorkspaceBuildParameters(ctx, build.ID)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []codersdk.WorkspaceBuildParameter{
+			{Name: "region", Value: "eu-west-1"},
+			{Name: "force_rebuild", Value: "false"},
+		}, parameters)
+	})
+
+	t.Run("CopyVolumes", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, cloneResponses(true))
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		clone, err := client.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name:        "clone",
+			CopyVolumes: true,
+		})
+		require.NoError(t, err)
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, clone.LatestBuild.ID)
+
+		parameters, err := client.WorkspaceBuildParameters(ctx, build.ID)
+		require.NoError(t, err)
+		require.Contains(t, parameters, codersdk.WorkspaceBuildParameter{
+			Name:  codersdk.CloneSourceWorkspaceParameterName,
+			Value: source.ID.String(),
+		})
+	})
+
+	t.Run("CopyVolumesUnsupported", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, cloneResponses(false))
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name:        "clone",
+			CopyVolumes: true,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "copy_volumes", apiErr.Validations[0].Field)
+	})
+
+	t.Run("NameConflict", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name: source.Name,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		// Members can't read the workspaces of other users.
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := member.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name: "clone",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("ReadOnly", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		templateAdmin, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID, rbac.RoleTemplateAdmin())
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		// Template admins can read the workspaces of other users, but not
+		// update them.
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := templateAdmin.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name: "clone",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("ReservedParameter", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, cloneResponses(true))
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		// The clone source can only be set by cloning a workspace.
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "clone",
+			RichParameterValues: []codersdk.WorkspaceBuildParameter{{
+				Name:  codersdk.CloneSourceWorkspaceParameterName,
+				Value: source.ID.String(),
+			}},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		_, err = client.CreateWorkspaceBuild(ctx, source.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStart,
+			RichParameterValues: []codersdk.WorkspaceBuildParameter{{
+				Name:  codersdk.CloneSourceWorkspaceParameterName,
+				Value: uuid.NewString(),
+			}},
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
+func TestWorkspacesBatch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Stop", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		memberClient, member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		otherTemplate := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		memberWorkspace := coderdtest.CreateWorkspace(t, memberClient, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, memberWorkspace.LatestBuild.ID)
+		otherWorkspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, otherTemplate.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, otherWorkspace.LatestBuild.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		batch, err := client.BatchWorkspaces(ctx, codersdk.BatchWorkspacesRequest{
+			Operation: codersdk.BatchWorkspaceOperationStop,
+			Query:     "template:" + template.Name,
+		})
+		require.NoError(t, err)
+		batch = awaitWorkspaceBatch(ctx, t, client, batch.ID)
+		require.Len(t, batch.Results, 2)
+		for _, result := range batch.Results {
+			require.Equal(t, codersdk.BatchWorkspaceResultStatusQueued, result.Status, result.Error)
+			require.NotNil(t, result.BuildID)
+			build := coderdtest.AwaitWorkspaceBuildJob(t, client, *result.BuildID)
+			require.Equal(t, codersdk.WorkspaceTransitionStop, build.Transition)
+			if result.WorkspaceID == memberWorkspace.ID {
+				require.Equal(t, member.Username, result.WorkspaceOwnerName)
+				require.Equal(t, codersdk.BuildReasonAdminForced, build.Reason)
+			}
+		}
+
+		// The workspace of the other template is still running.
+		otherWorkspace, err = client.Workspace(ctx, otherWorkspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, otherWorkspace.LatestBuild.Transition)
+
+		// Stopped workspaces are skipped.
+		batch, err = client.BatchWorkspaces(ctx, codersdk.BatchWorkspacesRequest{
+			Operation: codersdk.BatchWorkspaceOperationStop,
+			Query:     "template:" + template.Name,
+		})
+		require.NoError(t, err)
+		batch = awaitWorkspaceBatch(ctx, t, client, batch.ID)
+		require.Len(t, batch.Results, 2)
+		for _, result := range batch.Results {
+			require.Equal(t, codersdk.BatchWorkspaceResultStatusSkipped, result.Status)
+			require.Nil(t, result.BuildID)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		newVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, newVersion.ID)
+		err := client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: newVersion.ID,
+		})
+		require.NoError(t, err)
+
+		batch, err := client.BatchWorkspaces(ctx, codersdk.BatchWorkspacesRequest{
+			Operation: codersdk.BatchWorkspaceOperationUpdate,
+			Query:     "owner:me",
+		})
+		require.NoError(t, err)
+		batch = awaitWorkspaceBatch(ctx, t, client, batch.ID)
+		require.Len(t, batch.Results, 1)
+		require.Equal(t, codersdk.BatchWorkspaceResultStatusQueued, batch.Results[0].Status, batch.Results[0].Error)
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, *batch.Results[0].BuildID)
+		require.Equal(t, newVersion.ID, build.TemplateVersionID)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, build.Transition)
+
+		// Workspaces on the active version are skipped.
+		batch, err = client.BatchWorkspaces(ctx, codersdk.BatchWorkspacesRequest{
+			Operation: codersdk.BatchWorkspaceOperationUpdate,
+			Query:     "owner:me",
+		})
+		require.NoError(t, err)
+		batch = awaitWorkspaceBatch(ctx, t, client, batch.ID)
+		require.Len(t, batch.Results, 1)
+		require.Equal(t, codersdk.BatchWorkspaceResultStatusSkipped, batch.Results[0].Status)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		memberWorkspace := coderdtest.CreateWorkspace(t, memberClient, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, memberWorkspace.LatestBuild.ID)
+
+		// Members only run operations on the workspaces they can see.
+		ctx := testutil.Context(t, testutil.WaitLong)
+		batch, err := memberClient.BatchWorkspaces(ctx, codersdk.BatchWorkspacesRequest{
+			Operation: codersdk.BatchWorkspaceOperationDelete,
+			Query:     "template:" + template.Name,
+		})
+		require.NoError(t, err)
+		batch = awaitWorkspaceBatch(ctx, t, memberClient, batch.ID)
+		require.Len(t, batch.Results, 1)
+		require.Equal(t, memberWorkspace.ID, batch.Results[0].WorkspaceID)
+		require.Equal(t, codersdk.BatchWorkspaceResultStatusQueued, batch.Results[0].Status, batch.Results[0].Error)
+
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, workspace.LatestBuild.Transition)
+
+		// Other members can't read the batch.
+		otherClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		_, err = otherClient.WorkspaceBatch(ctx, batch.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("InvalidQuery", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+
+		ctx := testutil.Context(t, testutil.WaitShort)
+		_, err := client.BatchWorkspaces(ctx, codersdk.BatchWorkspacesRequest{
+			Operation: codersdk.BatchWorkspaceOperationStop,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		_, err = client.BatchWorkspaces(ctx, codersdk.BatchWorkspacesRequest{
+			Operation: codersdk.BatchWorkspaceOperationStop,
+			Query:     "last_used_before:yesterday",
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
+// awaitWorkspaceBatch waits for the batch to complete and returns it.
+func awaitWorkspaceBatch(ctx context.Context, t *testing.T, client *codersdk.Client, id uuid.UUID) codersdk.WorkspaceBatch {
+	t.Helper()
+
+	var batch codersdk.WorkspaceBatch
+	require.Eventually(t, func() bool {
+		var err error
+		batch, err = client.WorkspaceBatch(ctx, id)
+		require.NoError(t, err)
+		return batch.CompletedAt != nil
+	}, testutil.WaitLong, testutil.IntervalFast)
+	require.Equal(t, codersdk.WorkspaceBatchStatusCompleted, batch.Status, batch.Error)
+	return batch
 }
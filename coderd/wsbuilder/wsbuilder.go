@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -17,6 +18,7 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/db2sdk"
 	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/parametervalidation"
 	"github.com/coder/coder/coderd/provisionerdserver"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/tracing"
@@ -42,8 +44,15 @@ type Builder struct {
 	deploymentValues *codersdk.DeploymentValues
 
 	richParameterValues []codersdk.WorkspaceBuildParameter
+	cloneSource         *uuid.UUID
 	initiator           uuid.UUID
 	reason              database.BuildReason
+	drain               drainTarget
+	resume              bool
+	parameterValidator  *parametervalidation.Validator
+	// validatedParameters are the parameter values that were validated by
+	// ValidateParameters, if it was called.
+	validatedParameters *[]codersdk.WorkspaceBuildParameter
 
 	// used during build, makes function arguments less verbose
 	ctx   context.Context
@@ -94,6 +103,16 @@ type stateTarget struct {
 	explicit *[]byte
 }
 
+// drainTarget expresses whether the agents of the last build are given time
+// to drain before a stop or delete build is acquired by a provisioner.
+//
+// The zero value of this struct means not to wait for the agents.
+type drainTarget struct {
+	gracePeriod time.Duration
+	// inactiveTimeout is used to tell whether an agent is connected.
+	inactiveTimeout time.Duration
+}
+
 func New(w database.Workspace, t database.WorkspaceTransition) Builder {
 	return Builder{workspace: w, trans: t}
 }
@@ -148,12 +167,63 @@ func (b Builder) Reason(r database.BuildReason) Builder {
 	return b
 }
 
+// DrainAgents delays stop and delete builds until the connected agents of the
+// last build have drained, or until the grace period and the timeouts of their
+// shutdown script and stop scripts have passed.
+func (b Builder) DrainAgents(gracePeriod, agentInactiveDisconnectTimeout time.Duration) Builder {
+	// nolint: revive
+	b.drain = drainTarget{gracePeriod: gracePeriod, inactiveTimeout: agentInactiveDisconnectTimeout}
+	return b
+}
+
+// Resume runs the last build of the workspace again after it failed or was
+// canceled. The build uses the template version, parameters and state of the
+// last build, so it continues from the resources that were already applied.
+// It replaces the version, state and parameter values set on the Builder.
+func (b Builder) Resume() Builder {
+	// nolint: revive
+	b.resume = true
+	b.reason = database.BuildReasonResume
+	b.version = versionTarget{}
+	b.state = stateTarget{}
+	b.richParameterValues = nil
+	return b
+}
+
 func (b Builder) RichParameterValues(p []codersdk.WorkspaceBuildParameter) Builder {
 	// nolint: revive
 	b.richParameterValues = p
 	return b
 }
 
+// CloneSource sets the codersdk.CloneSourceWorkspaceParameterName parameter of
+// the build to the workspace that is cloned, so the template can copy its
+// volumes. The parameter is reserved, so builds reject values for it
+// otherwise.
+func (b Builder) CloneSource(workspaceID uuid.UUID) Builder {
+	// nolint: revive
+	b.cloneSource = &workspaceID
+	return b
+}
+
+// ParameterValidator validates the parameter values of builds that start the
+// workspace with the parameter validation rules of the template before the
+// build is queued.
+func (b Builder) ParameterValidator(v *parametervalidation.Validator) Builder {
+	// nolint: revive
+	b.parameterValidator = v
+	return b
+}
+
+// Workspace replaces the workspace to build, e.g. with the workspace that is
+// inserted in the same transaction as the build after ValidateParameters was
+// called with a workspace that doesn't exist yet.
+func (b Builder) Workspace(w database.Workspace) Builder {
+	// nolint: revive
+	b.workspace = w
+	return b
+}
+
 // SetLastWorkspaceBuildInTx prepopulates the Builder's cache with the last workspace build.  This allows us
 // to avoid a repeated database query when the Builder's caller also needs the workspace build, e.g. auto-start &
 // auto-stop.
@@ -204,6 +274,15 @@ func (b *Builder) Build(
 ) {
 	b.ctx = ctx
 
+	// The parameter validation webhook may be slow, so it must not be called
+	// in the transaction, or again for each retry.
+	if b.validatedParameters == nil {
+		err := b.ValidateParameters(ctx, store, authFunc)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Run the build in a transaction with RepeatableRead isolation, and retries.
 	// RepeatableRead isolation ensures that we get a consistent view of the database while
 	// computing the new build.  This simplifies the logic so that we do not need to worry if
@@ -233,6 +312,54 @@ func (b *Builder) Build(
 	return nil, nil, xerrors.Errorf("too many errors; last error: %w", err)
 }
 
+// ValidateParameters resolves the parameter values of the build and validates
+// them with the parameter validation rules of the template. Build only uses
+// the values if they resolve the same in its transaction. Build calls it
+// before opening its transaction, so callers only need to call it if they
+// call Build in a transaction of their own.
+func (b *Builder) ValidateParameters(
+	ctx context.Context,
+	store database.Store,
+	authFunc func(action rbac.Action, object rbac.Objecter) bool,
+) error {
+	validated := []codersdk.WorkspaceBuildParameter{}
+	if b.parameterValidator == nil || b.trans != database.WorkspaceTransitionStart {
+		b.validatedParameters = &validated
+		return nil
+	}
+
+	// Resolve the values with a copy of the Builder, so the objects fetched
+	// outside of the transaction of the build aren't cached.
+	v := *b
+	v.ctx = ctx
+	v.store = store
+	if authFunc != nil {
+		err := v.authorize(authFunc)
+		if err != nil {
+			return err
+		}
+	}
+	if v.initiator == uuid.Nil {
+		v.initiator = v.workspace.OwnerID
+	}
+	names, values, err := v.getParameters()
+	if err != nil {
+		return err
+	}
+	err = v.validateParameters(names, values)
+	if err != nil {
+		return err
+	}
+	for i, name := range names {
+		validated = append(validated, codersdk.WorkspaceBuildParameter{
+			Name:  name,
+			Value: values[i],
+		})
+	}
+	b.validatedParameters = &validated
+	return nil
+}
+
 // buildTx contains the business logic of computing a new build.  Attributes of the new database objects are computed
 // in a functional style, rather than imperative, to emphasize the logic of how they are defined.  A simple cache
 // of database-fetched objects is stored on the struct to ensure we only fetch things once, even if they are used in
@@ -260,6 +387,10 @@ func (b *Builder) buildTx(authFunc func(action rbac.Action, object rbac.Objecter
 	if err != nil {
 		return nil, nil, err
 	}
+	err = b.checkResume()
+	if err != nil {
+		return nil, nil, err
+	}
 
 	template, err := b.getTemplate()
 	if err != nil {
@@ -277,9 +408,24 @@ func (b *Builder) buildTx(authFunc func(action rbac.Action, object rbac.Objecter
 	if b.initiator == uuid.Nil {
 		b.initiator = b.workspace.OwnerID
 	}
-	// default reason is initiator
+	// default reason is initiator, or admin_forced if someone other than
+	// the owner builds the workspace, so the owner can tell them apart from
+	// their own builds
 	if b.reason == "" {
 		b.reason = database.BuildReasonInitiator
+		if b.initiator != b.workspace.OwnerID {
+			b.reason = database.BuildReasonAdminForced
+		}
+	}
+
+	names, values, err := b.getParameters()
+	if err != nil {
+		// getParameters already wraps errors in BuildError
+		return nil, nil, err
+	}
+	err = b.checkParametersValidated(names, values)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	workspaceBuildID := uuid.New()
@@ -301,6 +447,10 @@ func (b *Builder) buildTx(authFunc func(action rbac.Action, object rbac.Objecter
 	tags := provisionerdserver.MutateTags(b.workspace.OwnerID, templateVersionJob.Tags)
 
 	now := database.Now()
+	notBefore, err := b.getNotBefore(now)
+	if err != nil {
+		return nil, nil, BuildError{http.StatusInternalServerError, "compute agent drain deadline", err}
+	}
 	provisionerJob, err := b.store.InsertProvisionerJob(b.ctx, database.InsertProvisionerJobParams{
 		ID:             uuid.New(),
 		CreatedAt:      now,
@@ -317,6 +467,8 @@ func (b *Builder) buildTx(authFunc func(action rbac.Action, object rbac.Objecter
 			Valid:      true,
 			RawMessage: traceMetadataRaw,
 		},
+		Priority:  b.reason.ProvisionerJobPriority(),
+		NotBefore: notBefore,
 	})
 	if err != nil {
 		return nil, nil, BuildError{http.StatusInternalServerError, "insert provisioner job", err}
@@ -354,11 +506,6 @@ func (b *Builder) buildTx(authFunc func(action rbac.Action, object rbac.Objecter
 			return BuildError{http.StatusInternalServerError, "insert workspace build", err}
 		}
 
-		names, values, err := b.getParameters()
-		if err != nil {
-			// getParameters already wraps errors in BuildError
-			return err
-		}
 		err = store.InsertWorkspaceBuildParameters(b.ctx, database.InsertWorkspaceBuildParametersParams{
 			WorkspaceBuildID: workspaceBuildID,
 			Name:             names,
@@ -464,6 +611,59 @@ func (b *Builder) getLastBuild() (*database.WorkspaceBuild, error) {
 	return b.lastBuild, nil
 }
 
+// getNotBefore returns the time before which the provisioner job must not be
+// acquired, so the agents of the last build can drain. Agents that are
+// disconnected or already shut down are not waited for.
+func (b *Builder) getNotBefore(now time.Time) (sql.NullTime, error) {
+	if b.drain.gracePeriod <= 0 {
+		return sql.NullTime{}, nil
+	}
+	if b.trans == database.WorkspaceTransitionStart {
+		return sql.NullTime{}, nil
+	}
+	agents, err := b.store.GetWorkspaceAgentsInLatestBuildByWorkspaceID(b.ctx, b.workspace.ID)
+	if xerrors.Is(err, sql.ErrNoRows) {
+		return sql.NullTime{}, nil
+	}
+	if err != nil {
+		return sql.NullTime{}, xerrors.Errorf("get workspace agents: %w", err)
+	}
+	var (
+		draining        bool
+		shutdownTimeout time.Duration
+	)
+	for _, agent := range agents {
+		if agent.Status(b.drain.inactiveTimeout).Status != database.WorkspaceAgentStatusConnected {
+			continue
+		}
+		switch agent.LifecycleState {
+		case database.WorkspaceAgentLifecycleStateShutdownTimeout,
+			database.WorkspaceAgentLifecycleStateShutdownError,
+			database.WorkspaceAgentLifecycleStateOff:
+			continue
+		}
+		draining = true
+		timeout := time.Duration(agent.ShutdownScriptTimeoutSeconds) * time.Second
+		// The scripts that run on stop run after the shutdown script.
+		scripts, err := b.store.GetWorkspaceAgentScriptsByAgentID(b.ctx, agent.ID)
+		if err != nil {
+			return sql.NullTime{}, xerrors.Errorf("get workspace agent scripts: %w", err)
+		}
+		for _, script := range scripts {
+			if script.RunOnStop {
+				timeout += time.Duration(script.TimeoutSeconds) * time.Second
+			}
+		}
+		if timeout > shutdownTimeout {
+			shutdownTimeout = timeout
+		}
+	}
+	if !draining {
+		return sql.NullTime{}, nil
+	}
+	return sql.NullTime{Time: now.Add(b.drain.gracePeriod + shutdownTimeout), Valid: true}, nil
+}
+
 func (b *Builder) getBuildNumber() (int32, error) {
 	bld, err := b.getLastBuild()
 	if xerrors.Is(err, sql.ErrNoRows) {
@@ -509,6 +709,10 @@ func (b *Builder) getParameters() (names, values []string, err error) {
 	if err != nil {
 		return nil, nil, BuildError{http.StatusBadRequest, "Unable to build workspace with unsupported parameters", err}
 	}
+	if b.findNewBuildParameterValue(codersdk.CloneSourceWorkspaceParameterName) != nil {
+		msg := fmt.Sprintf("Parameter %q is reserved for cloning workspaces.", codersdk.CloneSourceWorkspaceParameterName)
+		return nil, nil, BuildError{http.StatusBadRequest, msg, xerrors.New(msg)}
+	}
 	resolver := codersdk.ParameterResolver{
 		Rich: db2sdk.WorkspaceBuildParameters(lastBuildParameters),
 	}
@@ -517,10 +721,14 @@ func (b *Builder) getParameters() (names, values []string, err error) {
 		if err != nil {
 			return nil, nil, BuildError{http.StatusInternalServerError, "failed to convert template version parameter", err}
 		}
-		value, err := resolver.ValidateResolve(
-			tvp,
-			b.findNewBuildParameterValue(templateVersionParameter.Name),
-		)
+		newValue := b.findNewBuildParameterValue(templateVersionParameter.Name)
+		if templateVersionParameter.Name == codersdk.CloneSourceWorkspaceParameterName && b.cloneSource != nil {
+			newValue = &codersdk.WorkspaceBuildParameter{
+				Name:  codersdk.CloneSourceWorkspaceParameterName,
+				Value: b.cloneSource.String(),
+			}
+		}
+		value, err := resolver.ValidateResolve(tvp, newValue)
 		if err != nil {
 			// At this point, we've queried all the data we need from the database,
 			// so the only errors are problems with the request (missing data, failed
@@ -533,6 +741,72 @@ func (b *Builder) getParameters() (names, values []string, err error) {
 	return names, values, nil
 }
 
+// validateParameters evaluates the parameter validation rules of the template
+// for the resolved parameter values. Only builds that start the workspace are
+// validated, so stopping and deleting workspaces works if the webhook of the
+// template is unavailable.
+func (b *Builder) validateParameters(names, values []string) error {
+	if b.parameterValidator == nil || b.trans != database.WorkspaceTransitionStart {
+		return nil
+	}
+	rules, err := b.store.GetTemplateParameterValidation(b.ctx, b.workspace.TemplateID)
+	if xerrors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return BuildError{http.StatusInternalServerError, "failed to fetch template parameter validation", err}
+	}
+	templateVersionID, err := b.getTemplateVersionID()
+	if err != nil {
+		return BuildError{http.StatusInternalServerError, "compute template version ID", err}
+	}
+
+	parameters := make([]codersdk.WorkspaceBuildParameter, 0, len(names))
+	for i, name := range names {
+		parameters = append(parameters, codersdk.WorkspaceBuildParameter{
+			Name:  name,
+			Value: values[i],
+		})
+	}
+	err = b.parameterValidator.Validate(b.ctx, rules, codersdk.ParameterValidationRequest{
+		TemplateID:        b.workspace.TemplateID,
+		TemplateVersionID: templateVersionID,
+		WorkspaceID:       b.workspace.ID,
+		WorkspaceName:     b.workspace.Name,
+		OwnerID:           b.workspace.OwnerID,
+		InitiatorID:       b.initiator,
+		Transition:        codersdk.WorkspaceTransition(b.trans),
+		Parameters:        parameters,
+	})
+	var validationErr *parametervalidation.Error
+	if xerrors.As(err, &validationErr) {
+		return BuildError{http.StatusBadRequest, "Workspace build parameters are invalid.", err}
+	}
+	if err != nil {
+		return BuildError{http.StatusBadGateway, "Failed to validate workspace build parameters.", err}
+	}
+	return nil
+}
+
+// checkParametersValidated returns an error if the parameter values that
+// ValidateParameters validated changed since, e.g. because another build of
+// the workspace was created in the meantime.
+func (b *Builder) checkParametersValidated(names, values []string) error {
+	if b.parameterValidator == nil || b.trans != database.WorkspaceTransitionStart {
+		return nil
+	}
+	validated := *b.validatedParameters
+	changed := len(validated) != len(names)
+	for i := 0; !changed && i < len(names); i++ {
+		changed = validated[i].Name != names[i] || validated[i].Value != values[i]
+	}
+	if changed {
+		msg := "Workspace build parameters changed while they were validated."
+		return BuildError{http.StatusConflict, msg, xerrors.New(msg)}
+	}
+	return nil
+}
+
 func (b *Builder) findNewBuildParameterValue(name string) *codersdk.WorkspaceBuildParameter {
 	for _, v := range b.richParameterValues {
 		if v.Name == name {
@@ -636,7 +910,7 @@ func (b *Builder) authorize(authFunc func(action rbac.Action, object rbac.Object
 	switch b.trans {
 	case database.WorkspaceTransitionDelete:
 		action = rbac.ActionDelete
-	case database.WorkspaceTransitionStart, database.WorkspaceTransitionStop:
+	case database.WorkspaceTransitionStart, database.WorkspaceTransitionStop, database.WorkspaceTransitionArchive:
 		action = rbac.ActionUpdate
 	default:
 		msg := fmt.Sprintf("Transition %q not supported.", b.trans)
@@ -761,3 +1035,31 @@ func (b *Builder) checkRunningBuild() error {
 	}
 	return nil
 }
+
+func (b *Builder) checkResume() error {
+	if !b.resume {
+		return nil
+	}
+	bld, err := b.getLastBuild()
+	if xerrors.Is(err, sql.ErrNoRows) {
+		msg := "The workspace has no build to resume."
+		return BuildError{http.StatusBadRequest, msg, xerrors.New(msg)}
+	}
+	if err != nil {
+		return BuildError{http.StatusInternalServerError, "failed to fetch prior build", err}
+	}
+	job, err := b.getLastBuildJob()
+	if err != nil {
+		return BuildError{http.StatusInternalServerError, "failed to fetch prior build job", err}
+	}
+	status := db2sdk.ProvisionerJobStatus(*job)
+	if status != codersdk.ProvisionerJobFailed && status != codersdk.ProvisionerJobCanceled {
+		msg := "Only failed or canceled builds can be resumed."
+		return BuildError{http.StatusBadRequest, msg, xerrors.Errorf("last build job is %s", status)}
+	}
+	if bld.Transition != b.trans {
+		msg := fmt.Sprintf("The transition must match the build that is resumed (%s).", bld.Transition)
+		return BuildError{http.StatusBadRequest, msg, xerrors.New(msg)}
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbmock"
+	"github.com/coder/coder/coderd/parametervalidation"
 	"github.com/coder/coder/coderd/provisionerdserver"
 	"github.com/coder/coder/coderd/wsbuilder"
 	"github.com/coder/coder/codersdk"
@@ -59,6 +60,7 @@ func TestBuilder_NoOptions(t *testing.T) {
 		expectProvisionerJob(func(job database.InsertProvisionerJobParams) {
 			asrt.Equal(userID, job.InitiatorID)
 			asrt.Equal(inactiveFileID, job.FileID)
+			asrt.Equal(database.ProvisionerJobPriorityDefault, job.Priority)
 			input := provisionerdserver.WorkspaceProvisionJob{}
 			err := json.Unmarshal(job.Input, &input)
 			req.NoError(err)
@@ -114,6 +116,8 @@ func TestBuilder_Initiator(t *testing.T) {
 		withInTx,
 		expectBuild(func(bld database.InsertWorkspaceBuildParams) {
 			asrt.Equal(otherUserID, bld.InitiatorID)
+			// Builds of other users' workspaces are forced by admins.
+			asrt.Equal(database.BuildReasonAdminForced, bld.Reason)
 		}),
 		expectBuildParameters(func(params database.InsertWorkspaceBuildParametersParams) {
 		}),
@@ -160,6 +164,146 @@ func TestBuilder_Reason(t *testing.T) {
 	req.NoError(err)
 }
 
+func TestBuilder_ReasonPriority(t *testing.T) {
+	t.Parallel()
+	req := require.New(t)
+	asrt := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mDB := expectDB(t,
+		// Inputs
+		withTemplate,
+		withInactiveVersion(nil),
+		withLastBuildFound,
+		withRichParameters(nil),
+
+		// Outputs
+		expectProvisionerJob(func(job database.InsertProvisionerJobParams) {
+			// Builds started by the server to stop workspaces must not delay
+			// builds started by users.
+			asrt.Equal(database.ProvisionerJobPriorityBackground, job.Priority)
+		}),
+		withInTx,
+		expectBuild(func(bld database.InsertWorkspaceBuildParams) {
+			asrt.Equal(database.BuildReasonFailedstop, bld.Reason)
+		}),
+		expectBuildParameters(func(params database.InsertWorkspaceBuildParametersParams) {
+		}),
+		withBuild,
+	)
+
+	ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
+	uut := wsbuilder.New(ws, database.WorkspaceTransitionStop).Reason(database.BuildReasonFailedstop)
+	_, _, err := uut.Build(ctx, mDB, nil)
+	req.NoError(err)
+}
+
+func TestBuilder_DrainAgents(t *testing.T) {
+	t.Parallel()
+	req := require.New(t)
+	asrt := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	now := database.Now()
+	connected := database.WorkspaceAgent{
+		ID:                           uuid.New(),
+		FirstConnectedAt:             sql.NullTime{Time: now, Valid: true},
+		LastConnectedAt:              sql.NullTime{Time: now, Valid: true},
+		LifecycleState:               database.WorkspaceAgentLifecycleStateReady,
+		ShutdownScriptTimeoutSeconds: 30,
+	}
+	// Agents that are already off are not waited for.
+	off := connected
+	off.LifecycleState = database.WorkspaceAgentLifecycleStateOff
+	off.ShutdownScriptTimeoutSeconds = 600
+	// Neither are agents that never connected.
+	disconnected := database.WorkspaceAgent{
+		LifecycleState:               database.WorkspaceAgentLifecycleStateCreated,
+		ShutdownScriptTimeoutSeconds: 600,
+	}
+
+	mDB := expectDB(t,
+		// Inputs
+		withTemplate,
+		withInactiveVersion(nil),
+		withLastBuildFound,
+		withRichParameters(nil),
+		func(mTx *dbmock.MockStore) {
+			mTx.EXPECT().GetWorkspaceAgentsInLatestBuildByWorkspaceID(gomock.Any(), workspaceID).
+				Times(1).
+				Return([]database.WorkspaceAgent{connected, off, disconnected}, nil)
+			// The scripts that run on stop extend the drain deadline.
+			mTx.EXPECT().GetWorkspaceAgentScriptsByAgentID(gomock.Any(), connected.ID).
+				Times(1).
+				Return([]database.WorkspaceAgentScript{
+					{Name: "save", RunOnStop: true, TimeoutSeconds: 20},
+					{Name: "install", RunOnStart: true, TimeoutSeconds: 600},
+				}, nil)
+		},
+
+		// Outputs
+		expectProvisionerJob(func(job database.InsertProvisionerJobParams) {
+			req.True(job.NotBefore.Valid)
+			asrt.WithinDuration(now.Add(time.Minute+50*time.Second), job.NotBefore.Time, 10*time.Second)
+		}),
+		withInTx,
+		expectBuild(func(bld database.InsertWorkspaceBuildParams) {
+			asrt.Equal(database.WorkspaceTransitionStop, bld.Transition)
+		}),
+		expectBuildParameters(func(params database.InsertWorkspaceBuildParametersParams) {
+		}),
+		withBuild,
+	)
+
+	ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
+	uut := wsbuilder.New(ws, database.WorkspaceTransitionStop).DrainAgents(time.Minute, time.Minute)
+	_, _, err := uut.Build(ctx, mDB, nil)
+	req.NoError(err)
+}
+
+func TestBuilder_Resume(t *testing.T) {
+	t.Parallel()
+	req := require.New(t)
+	asrt := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mDB := expectDB(t,
+		// Inputs
+		withTemplate,
+		withInactiveVersion(nil),
+		withLastBuildCanceled,
+		withRichParameters(nil),
+		withParameterSchemas(inactiveJobID, nil),
+
+		// Outputs
+		expectProvisionerJob(func(job database.InsertProvisionerJobParams) {
+			asrt.Equal(inactiveFileID, job.FileID)
+		}),
+		withInTx,
+		expectBuild(func(bld database.InsertWorkspaceBuildParams) {
+			// The build continues from the partial state of the canceled
+			// build, with its version.
+			asrt.Equal(inactiveVersionID, bld.TemplateVersionID)
+			asrt.Equal("partially applied state", string(bld.ProvisionerState))
+			asrt.Equal(database.BuildReasonResume, bld.Reason)
+		}),
+		expectBuildParameters(func(params database.InsertWorkspaceBuildParametersParams) {
+		}),
+		withBuild,
+	)
+
+	ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
+	uut := wsbuilder.New(ws, database.WorkspaceTransitionStart).ActiveVersion().Resume()
+	_, _, err := uut.Build(ctx, mDB, nil)
+	req.NoError(err)
+}
+
 func TestBuilder_ActiveVersion(t *testing.T) {
 	t.Parallel()
 	req := require.New(t)
@@ -348,10 +492,7 @@ func TestWorkspaceBuildWithRichParameters(t *testing.T) {
 			withRichParameters(nil),
 			withParameterSchemas(inactiveJobID, schemas),
 
-			// Outputs
-			expectProvisionerJob(func(job database.InsertProvisionerJobParams) {}),
-			withInTx,
-			expectBuild(func(bld database.InsertWorkspaceBuildParams) {}),
+			// no outputs, since parameters are validated before the build is queued.
 		)
 
 		ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
@@ -383,12 +524,7 @@ func TestWorkspaceBuildWithRichParameters(t *testing.T) {
 			withRichParameters(initialBuildParameters),
 			withParameterSchemas(inactiveJobID, nil),
 
-			// Outputs
-			expectProvisionerJob(func(job database.InsertProvisionerJobParams) {}),
-			withInTx,
-			expectBuild(func(bld database.InsertWorkspaceBuildParams) {}),
-			// no build parameters, since we hit an error validating.
-			// expectBuildParameters(func(params database.InsertWorkspaceBuildParametersParams) {}),
+			// no outputs, since parameters are validated before the build is queued.
 		)
 
 		ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
@@ -578,6 +714,110 @@ func TestWorkspaceBuildWithRichParameters(t *testing.T) {
 	})
 }
 
+func TestBuilder_ParameterValidator(t *testing.T) {
+	t.Parallel()
+
+	richParameters := []database.TemplateVersionParameter{
+		{Name: "region", Mutable: true, Options: json.RawMessage("[]")},
+		{Name: "instance_type", Mutable: true, Options: json.RawMessage("[]")},
+	}
+	rules := database.TemplateParameterValidation{
+		TemplateID:  templateID,
+		Constraints: json.RawMessage(`[{"parameter":"instance_type","regex":"^t","when_parameter":"region","when_regex":"^eu-"}]`),
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		t.Parallel()
+		req := require.New(t)
+		asrt := assert.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		mDB := expectDB(t,
+			// Inputs
+			withTemplate,
+			withInactiveVersion(richParameters),
+			withLastBuildFound,
+			withRichParameters(nil),
+			withParameterSchemas(inactiveJobID, nil),
+
+			// Outputs
+			expectProvisionerJob(func(job database.InsertProvisionerJobParams) {}),
+			withInTx,
+			expectBuild(func(bld database.InsertWorkspaceBuildParams) {}),
+			expectBuildParameters(func(params database.InsertWorkspaceBuildParametersParams) {
+				asrt.Equal([]string{"region", "instance_type"}, params.Name)
+				asrt.Equal([]string{"eu-west-1", "t3.large"}, params.Value)
+			}),
+			withBuild,
+		)
+		// The parameters are validated before the transaction.
+		expectValidation(mDB, richParameters, rules)
+
+		ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
+		uut := wsbuilder.New(ws, database.WorkspaceTransitionStart).
+			RichParameterValues([]codersdk.WorkspaceBuildParameter{
+				{Name: "region", Value: "eu-west-1"},
+				{Name: "instance_type", Value: "t3.large"},
+			}).
+			ParameterValidator(&parametervalidation.Validator{})
+		_, _, err := uut.Build(ctx, mDB, nil)
+		req.NoError(err)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		req := require.New(t)
+		asrt := assert.New(t)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// No transaction, since the constraint isn't satisfied.
+		mDB := dbmock.NewMockStore(gomock.NewController(t))
+		expectValidation(mDB, richParameters, rules)
+
+		ws := database.Workspace{ID: workspaceID, TemplateID: templateID, OwnerID: userID}
+		uut := wsbuilder.New(ws, database.WorkspaceTransitionStart).
+			RichParameterValues([]codersdk.WorkspaceBuildParameter{
+				{Name: "region", Value: "eu-west-1"},
+				{Name: "instance_type", Value: "g5.xlarge"},
+			}).
+			ParameterValidator(&parametervalidation.Validator{})
+		_, _, err := uut.Build(ctx, mDB, nil)
+		bldErr := wsbuilder.BuildError{}
+		req.ErrorAs(err, &bldErr)
+		asrt.Equal(http.StatusBadRequest, bldErr.Status)
+		var validationErr *parametervalidation.Error
+		req.ErrorAs(err, &validationErr)
+		req.Len(validationErr.Validations, 1)
+		asrt.Equal("instance_type", validationErr.Validations[0].Field)
+	})
+}
+
+// expectValidation sets up the expectations for validating the parameters
+// before the transaction of the build.
+func expectValidation(mDB *dbmock.MockStore, params []database.TemplateVersionParameter, rules database.TemplateParameterValidation) {
+	mDB.EXPECT().GetLatestWorkspaceBuildByWorkspaceID(gomock.Any(), workspaceID).
+		Times(1).
+		Return(database.WorkspaceBuild{
+			ID:                lastBuildID,
+			WorkspaceID:       workspaceID,
+			TemplateVersionID: inactiveVersionID,
+			BuildNumber:       1,
+			JobID:             lastBuildJobID,
+		}, nil)
+	for _, o := range []txExpect{
+		withInactiveVersion(params),
+		withRichParameters(nil),
+		withParameterSchemas(inactiveJobID, nil),
+		withParameterValidation(rules),
+	} {
+		o(mDB)
+	}
+}
+
 type txExpect func(mTx *dbmock.MockStore)
 
 func expectDB(t *testing.T, opts ...txExpect) *dbmock.MockStore {
@@ -734,6 +974,41 @@ func withLastBuildFound(mTx *dbmock.MockStore) {
 		}, nil)
 }
 
+// withLastBuildCanceled is a start build that was canceled while applying,
+// and kept the state of the resources it applied so far.
+func withLastBuildCanceled(mTx *dbmock.MockStore) {
+	mTx.EXPECT().GetLatestWorkspaceBuildByWorkspaceID(gomock.Any(), workspaceID).
+		Times(1).
+		Return(database.WorkspaceBuild{
+			ID:                lastBuildID,
+			WorkspaceID:       workspaceID,
+			TemplateVersionID: inactiveVersionID,
+			BuildNumber:       1,
+			Transition:        database.WorkspaceTransitionStart,
+			InitiatorID:       userID,
+			JobID:             lastBuildJobID,
+			ProvisionerState:  []byte("partially applied state"),
+			Reason:            database.BuildReasonInitiator,
+		}, nil)
+
+	mTx.EXPECT().GetProvisionerJobByID(gomock.Any(), lastBuildJobID).
+		Times(1).
+		Return(database.ProvisionerJob{
+			ID:             lastBuildJobID,
+			OrganizationID: orgID,
+			InitiatorID:    userID,
+			Provisioner:    database.ProvisionerTypeTerraform,
+			StorageMethod:  database.ProvisionerStorageMethodFile,
+			FileID:         inactiveFileID,
+			Type:           database.ProvisionerJobTypeWorkspaceBuild,
+			StartedAt:      sql.NullTime{Time: database.Now(), Valid: true},
+			UpdatedAt:      time.Now(),
+			CanceledAt:     sql.NullTime{Time: database.Now(), Valid: true},
+			CompletedAt:    sql.NullTime{Time: database.Now(), Valid: true},
+			Error:          sql.NullString{String: "interrupted", Valid: true},
+		}, nil)
+}
+
 func withLastBuildNotFound(mTx *dbmock.MockStore) {
 	mTx.EXPECT().GetLatestWorkspaceBuildByWorkspaceID(gomock.Any(), workspaceID).
 		Times(1).
@@ -754,6 +1029,14 @@ func withParameterSchemas(jobID uuid.UUID, schemas []database.ParameterSchema) f
 	}
 }
 
+func withParameterValidation(rules database.TemplateParameterValidation) func(mTx *dbmock.MockStore) {
+	return func(mTx *dbmock.MockStore) {
+		mTx.EXPECT().GetTemplateParameterValidation(gomock.Any(), templateID).
+			Times(1).
+			Return(rules, nil)
+	}
+}
+
 func withRichParameters(params []database.WorkspaceBuildParameter) func(mTx *dbmock.MockStore) {
 	return func(mTx *dbmock.MockStore) {
 		c := mTx.EXPECT().GetWorkspaceBuildParameters(gomock.Any(), lastBuildID).
@@ -16,6 +16,7 @@ import (
 	"golang.org/x/sync/singleflight"
 	"golang.org/x/xerrors"
 
+	"github.com/coder/coder/coderd/workspaceapps"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/site"
 )
@@ -36,6 +37,10 @@ func (a *AgentProvider) AgentConn(_ context.Context, agentID uuid.UUID) (*coders
 func (a *AgentProvider) ReverseProxy(targetURL *url.URL, dashboardURL *url.URL, agentID uuid.UUID) (*httputil.ReverseProxy, func(), error) {
 	proxy := httputil.NewSingleHostReverseProxy(targetURL)
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		if workspaceapps.IsGRPCRequest(r) {
+			workspaceapps.WriteGRPCUnavailable(w, "Failed to proxy request to application: "+err.Error())
+			return
+		}
 		site.RenderStaticErrorPage(w, r, site.ErrorPageData{
 			Status:       http.StatusBadGateway,
 			Title:        "Bad Gateway",
@@ -50,9 +55,7 @@ func (a *AgentProvider) ReverseProxy(targetURL *url.URL, dashboardURL *url.URL,
 		return nil, nil, xerrors.Errorf("acquire agent connection: %w", err)
 	}
 
-	transport := conn.HTTPTransport()
-
-	proxy.Transport = transport
+	proxy.Transport = conn.appTransport
 	return proxy, release, nil
 }
 
@@ -92,6 +95,9 @@ type Conn struct {
 	timeout       *time.Timer
 	timeoutCancel context.CancelFunc
 	transport     *http.Transport
+	// appTransport proxies gRPC requests over HTTP/2 and all other requests
+	// over transport.
+	appTransport *workspaceapps.GRPCPassthroughTransport
 }
 
 func (c *Conn) HTTPTransport() *http.Transport {
@@ -100,7 +106,9 @@ func (c *Conn) HTTPTransport() *http.Transport {
 
 // Close ends the HTTP transport if exists, and closes the agent.
 func (c *Conn) Close() error {
-	if c.transport != nil {
+	if c.appTransport != nil {
+		c.appTransport.CloseIdleConnections()
+	} else if c.transport != nil {
 		c.transport.CloseIdleConnections()
 	}
 	c.timeoutMutex.Lock()
@@ -172,6 +180,7 @@ func (c *Cache) Acquire(id uuid.UUID) (*Conn, func(), error) {
 				WorkspaceAgentConn: agentConn,
 				timeoutCancel:      timeoutCancelFunc,
 				transport:          transport,
+				appTransport:       workspaceapps.NewGRPCPassthroughTransport(transport, agentConn.DialContext),
 			}
 			go func() {
 				defer c.closeGroup.Done()
@@ -262,6 +262,10 @@ func (*client) PostLifecycle(_ context.Context, _ agentsdk.PostLifecycleRequest)
 	return nil
 }
 
+func (*client) PostScriptStatus(_ context.Context, _ agentsdk.PostScriptStatusRequest) error {
+	return nil
+}
+
 func (*client) PostAppHealth(_ context.Context, _ agentsdk.PostAppHealthsRequest) error {
 	return nil
 }
@@ -270,6 +274,10 @@ func (*client) PostMetadata(_ context.Context, _ string, _ agentsdk.PostMetadata
 	return nil
 }
 
+func (*client) PostMetadataPlugins(_ context.Context, _ agentsdk.PostMetadataPluginsRequest) error {
+	return nil
+}
+
 func (*client) PostStartup(_ context.Context, _ agentsdk.PostStartupRequest) error {
 	return nil
 }
@@ -281,3 +289,12 @@ func (*client) PatchLogs(_ context.Context, _ agentsdk.PatchLogs) error {
 func (*client) GetServiceBanner(_ context.Context) (codersdk.ServiceBannerConfig, error) {
 	return codersdk.ServiceBannerConfig{}, nil
 }
+
+func (*client) WaitForDrain(ctx context.Context) (agentsdk.DrainRequest, error) {
+	<-ctx.Done()
+	return agentsdk.DrainRequest{}, ctx.Err()
+}
+
+func (*client) GitAuth(_ context.Context, _ string, _ bool) (agentsdk.GitAuthResponse, error) {
+	return agentsdk.GitAuthResponse{}, nil
+}
@@ -80,6 +80,29 @@ func (c *Client) PostMetadata(ctx context.Context, key string, req PostMetadataR
 	return nil
 }
 
+// PostMetadataPluginsRequest is the set of metadata collected by the metadata
+// plugins of the agent. Keys must not clash with the metadata defined in the
+// template.
+type PostMetadataPluginsRequest struct {
+	Plugins []codersdk.WorkspaceAgentMetadataDescription `json:"plugins"`
+}
+
+// PostMetadataPlugins replaces the metadata plugins registered by the agent.
+// Metadata can only be posted for plugins that have been registered.
+func (c *Client) PostMetadataPlugins(ctx context.Context, req PostMetadataPluginsRequest) error {
+	res, err := c.SDK.Request(ctx, http.MethodPost, "/api/v2/workspaceagents/me/metadata-plugins", req)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return codersdk.ReadBodyAsError(res)
+	}
+
+	return nil
+}
+
 type Manifest struct {
 	AgentID uuid.UUID `json:"agent_id"`
 	// GitAuthConfigs stores the number of Git configurations
@@ -97,7 +120,20 @@ type Manifest struct {
 	ShutdownScript           string                                       `json:"shutdown_script"`
 	ShutdownScriptTimeout    time.Duration                                `json:"shutdown_script_timeout"`
 	DisableDirectConnections bool                                         `json:"disable_direct_connections"`
+	DisableX11Forwarding     bool                                         `json:"disable_x11_forwarding"`
 	Metadata                 []codersdk.WorkspaceAgentMetadataDescription `json:"metadata"`
+	Scripts                  []codersdk.WorkspaceAgentScript              `json:"scripts"`
+	// Secrets maps the names of the secrets of the workspace owner that
+	// are referenced by the template to their decrypted values. They are
+	// set as environment variables.
+	Secrets map[string]string `json:"secrets"`
+	// Dotfiles is the dotfiles repository of the workspace owner, which the
+	// agent applies when it starts. It's nil if the owner hasn't configured
+	// one or the template disables dotfiles.
+	Dotfiles *codersdk.UserDotfiles `json:"dotfiles,omitempty"`
+	// Devcontainers are the repositories whose devcontainer.json the agent
+	// applies after the startup scripts have run.
+	Devcontainers []codersdk.WorkspaceAgentDevcontainer `json:"devcontainers"`
 }
 
 // Manifest fetches manifest for the currently authenticated workspace agent.
@@ -531,6 +567,12 @@ type Stats struct {
 	TxPackets int64 `json:"tx_packets"`
 	// TxBytes is the number of transmitted bytes.
 	TxBytes int64 `json:"tx_bytes"`
+	// PeerCountP2P is the number of active peers connected directly to the
+	// agent.
+	PeerCountP2P int64 `json:"peer_count_p2p"`
+	// PeerCountDERP is the number of active peers connected to the agent
+	// through a DERP relay.
+	PeerCountDERP int64 `json:"peer_count_derp"`
 
 	// SessionCountVSCode is the number of connections received by an agent
 	// that are from our VS Code extension.
@@ -611,6 +653,29 @@ func (c *Client) PostLifecycle(ctx context.Context, req PostLifecycleRequest) er
 	return nil
 }
 
+type PostScriptStatusRequest struct {
+	Name      string                              `json:"name"`
+	Status    codersdk.WorkspaceAgentScriptStatus `json:"status"`
+	ExitCode  int32                               `json:"exit_code"`
+	StartedAt time.Time                           `json:"started_at"`
+	EndedAt   time.Time                           `json:"ended_at"`
+}
+
+// PostScriptStatus reports the status of a run of one of the scripts in the
+// manifest.
+func (c *Client) PostScriptStatus(ctx context.Context, req PostScriptStatusRequest) error {
+	res, err := c.SDK.Request(ctx, http.MethodPost, "/api/v2/workspaceagents/me/report-script-status", req)
+	if err != nil {
+		return xerrors.Errorf("agent script status post request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return codersdk.ReadBodyAsError(res)
+	}
+
+	return nil
+}
+
 type PostStartupRequest struct {
 	Version           string                    `json:"version"`
 	ExpandedDirectory string                    `json:"expanded_directory"`
@@ -676,6 +741,9 @@ type GitAuthResponse struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	URL      string `json:"url"`
+	// Expiry is when the token expires. It's zero if the token doesn't
+	// expire.
+	Expiry time.Time `json:"expiry" format:"date-time"`
 }
 
 // GitAuth submits a URL to fetch a GIT_ASKPASS username and password for.
@@ -0,0 +1,76 @@
+package agentsdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+	"nhooyr.io/websocket"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// DrainRequest asks the agent to drain before a stop or delete build tears
+// down the resources of its workspace. The build waits until the agent
+// reports a shutdown lifecycle state, or until the grace period and the
+// shutdown script timeout have passed.
+type DrainRequest struct {
+	BuildID    uuid.UUID                    `json:"build_id" format:"uuid"`
+	Transition codersdk.WorkspaceTransition `json:"transition"`
+	// GracePeriod is how long the agent waits for sessions to end before
+	// running the shutdown script.
+	GracePeriod time.Duration `json:"grace_period"`
+}
+
+// WaitForDrain connects to the drain WebSocket and blocks until coderd asks
+// the agent to drain.
+func (c *Client) WaitForDrain(ctx context.Context) (DrainRequest, error) {
+	drainURL, err := c.SDK.URL.Parse("/api/v2/workspaceagents/me/drain")
+	if err != nil {
+		return DrainRequest{}, xerrors.Errorf("parse url: %w", err)
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return DrainRequest{}, xerrors.Errorf("create cookie jar: %w", err)
+	}
+	jar.SetCookies(drainURL, []*http.Cookie{{
+		Name:  codersdk.SessionTokenCookie,
+		Value: c.SDK.SessionToken(),
+	}})
+	httpClient := &http.Client{
+		Jar:       jar,
+		Transport: c.SDK.HTTPClient.Transport,
+	}
+	// nolint:bodyclose
+	conn, res, err := websocket.Dial(ctx, drainURL.String(), &websocket.DialOptions{
+		HTTPClient: httpClient,
+	})
+	if err != nil {
+		if res == nil {
+			return DrainRequest{}, err
+		}
+		return DrainRequest{}, codersdk.ReadBodyAsError(res)
+	}
+	defer conn.Close(websocket.StatusGoingAway, "WaitForDrain closed")
+
+	ctx, cancelFunc := context.WithCancel(ctx)
+	defer cancelFunc()
+	ctx, wsNetConn := websocketNetConn(ctx, conn, websocket.MessageText)
+	defer wsNetConn.Close()
+	pingClosed := pingWebSocket(ctx, c.SDK.Logger(), conn, "drain")
+	defer func() {
+		cancelFunc()
+		<-pingClosed
+	}()
+
+	var req DrainRequest
+	err = json.NewDecoder(wsNetConn).Decode(&req)
+	if err != nil {
+		return DrainRequest{}, xerrors.Errorf("read drain request: %w", err)
+	}
+	return req, nil
+}
@@ -0,0 +1,74 @@
+package agentsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/codersdk"
+)
+
+// Peer is an agent of another workspace owned by the owner of the
+// authenticated agent's workspace. Peers can dial each other over tailnet.
+type Peer struct {
+	WorkspaceID   uuid.UUID                     `json:"workspace_id" format:"uuid"`
+	WorkspaceName string                        `json:"workspace_name"`
+	AgentID       uuid.UUID                     `json:"agent_id" format:"uuid"`
+	AgentName     string                        `json:"agent_name"`
+	Status        codersdk.WorkspaceAgentStatus `json:"status"`
+}
+
+// Peers returns the agents the authenticated agent can dial, sorted by
+// workspace and agent name.
+func (c *Client) Peers(ctx context.Context) ([]Peer, error) {
+	res, err := c.SDK.Request(ctx, http.MethodGet, "/api/v2/workspaceagents/me/peers", nil)
+	if err != nil {
+		return nil, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, codersdk.ReadBodyAsError(res)
+	}
+	var peers []Peer
+	return peers, json.NewDecoder(res.Body).Decode(&peers)
+}
+
+// PeerConnectionInfo returns the connection info needed to dial a peer agent.
+func (c *Client) PeerConnectionInfo(ctx context.Context, agentID uuid.UUID) (codersdk.WorkspaceAgentConnectionInfo, error) {
+	res, err := c.SDK.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaceagents/me/peers/%s/connection", agentID), nil)
+	if err != nil {
+		return codersdk.WorkspaceAgentConnectionInfo{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return codersdk.WorkspaceAgentConnectionInfo{}, codersdk.ReadBodyAsError(res)
+	}
+	var connInfo codersdk.WorkspaceAgentConnectionInfo
+	err = json.NewDecoder(res.Body).Decode(&connInfo)
+	if err != nil {
+		return codersdk.WorkspaceAgentConnectionInfo{}, err
+	}
+	// Peers reach the embedded relay through the same URL as the agent.
+	err = c.rewriteDerpMap(connInfo.DERPMap)
+	if err != nil {
+		return codersdk.WorkspaceAgentConnectionInfo{}, err
+	}
+	return connInfo, nil
+}
+
+// DialPeer dials a peer agent over tailnet, authenticated by the agent token.
+func (c *Client) DialPeer(ctx context.Context, agentID uuid.UUID, options *codersdk.DialWorkspaceAgentOptions) (*codersdk.WorkspaceAgentConn, error) {
+	connInfo, err := c.PeerConnectionInfo(ctx, agentID)
+	if err != nil {
+		return nil, xerrors.Errorf("get connection info: %w", err)
+	}
+	coordinateURL, err := c.SDK.URL.Parse(fmt.Sprintf("/api/v2/workspaceagents/me/peers/%s/coordinate", agentID))
+	if err != nil {
+		return nil, xerrors.Errorf("parse url: %w", err)
+	}
+	return c.SDK.DialWorkspaceAgentWithInfo(ctx, agentID, connInfo, coordinateURL, options)
+}
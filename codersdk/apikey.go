@@ -19,7 +19,7 @@ type APIKey struct {
 	CreatedAt       time.Time   `json:"created_at" validate:"required" format:"date-time"`
 	UpdatedAt       time.Time   `json:"updated_at" validate:"required" format:"date-time"`
 	LoginType       LoginType   `json:"login_type" validate:"required" enums:"password,github,oidc,token"`
-	Scope           APIKeyScope `json:"scope" validate:"required" enums:"all,application_connect"`
+	Scope           APIKeyScope `json:"scope" validate:"required" enums:"all,application_connect,workspace:read,workspace:ssh,workspace:apps,template:read"`
 	TokenName       string      `json:"token_name" validate:"required"`
 	LifetimeSeconds int64       `json:"lifetime_seconds" validate:"required"`
 }
@@ -48,11 +48,24 @@ const (
 	// APIKeyScopeApplicationConnect is a scope that allows the user
 	// to connect to applications in a workspace.
 	APIKeyScopeApplicationConnect APIKeyScope = "application_connect"
+	// APIKeyScopeWorkspaceRead is a scope that allows the user to read
+	// workspaces and the templates they use.
+	APIKeyScopeWorkspaceRead APIKeyScope = "workspace:read"
+	// APIKeyScopeWorkspaceSSH is a scope that allows the user to read
+	// workspaces and to connect to them over SSH, e.g. with coder ssh or
+	// coder port-forward.
+	APIKeyScopeWorkspaceSSH APIKeyScope = "workspace:ssh"
+	// APIKeyScopeWorkspaceApps is a scope that allows the user to read
+	// workspaces and to connect to their applications.
+	APIKeyScopeWorkspaceApps APIKeyScope = "workspace:apps"
+	// APIKeyScopeTemplateRead is a scope that allows the user to read
+	// templates.
+	APIKeyScopeTemplateRead APIKeyScope = "template:read"
 )
 
 type CreateTokenRequest struct {
 	Lifetime  time.Duration `json:"lifetime"`
-	Scope     APIKeyScope   `json:"scope" enums:"all,application_connect"`
+	Scope     APIKeyScope   `json:"scope" enums:"all,application_connect,workspace:read,workspace:ssh,workspace:apps,template:read"`
 	TokenName string        `json:"token_name"`
 }
 
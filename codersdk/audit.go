@@ -14,16 +14,21 @@ import (
 type ResourceType string
 
 const (
-	ResourceTypeTemplate        ResourceType = "template"
-	ResourceTypeTemplateVersion ResourceType = "template_version"
-	ResourceTypeUser            ResourceType = "user"
-	ResourceTypeWorkspace       ResourceType = "workspace"
-	ResourceTypeWorkspaceBuild  ResourceType = "workspace_build"
-	ResourceTypeGitSSHKey       ResourceType = "git_ssh_key"
-	ResourceTypeAPIKey          ResourceType = "api_key"
-	ResourceTypeGroup           ResourceType = "group"
-	ResourceTypeLicense         ResourceType = "license"
-	ResourceTypeConvertLogin    ResourceType = "convert_login"
+	ResourceTypeTemplate                     ResourceType = "template"
+	ResourceTypeTemplateVersion              ResourceType = "template_version"
+	ResourceTypeTemplateVersionPromotion     ResourceType = "template_version_promotion"
+	ResourceTypeUser                         ResourceType = "user"
+	ResourceTypeWorkspace                    ResourceType = "workspace"
+	ResourceTypeWorkspaceBuild               ResourceType = "workspace_build"
+	ResourceTypeGitSSHKey                    ResourceType = "git_ssh_key"
+	ResourceTypeAPIKey                       ResourceType = "api_key"
+	ResourceTypeGroup                        ResourceType = "group"
+	ResourceTypeLicense                      ResourceType = "license"
+	ResourceTypeConvertLogin                 ResourceType = "convert_login"
+	ResourceTypeProvisionerKey               ResourceType = "provisioner_key"
+	ResourceTypeTemplateParameterValidation  ResourceType = "template_parameter_validation"
+	ResourceTypeOrganizationTemplateVariable ResourceType = "organization_template_variable"
+	ResourceTypeCustomRole                   ResourceType = "custom_role"
 )
 
 func (r ResourceType) FriendlyString() string {
@@ -32,6 +37,8 @@ func (r ResourceType) FriendlyString() string {
 		return "template"
 	case ResourceTypeTemplateVersion:
 		return "template version"
+	case ResourceTypeTemplateVersionPromotion:
+		return "template version promotion"
 	case ResourceTypeUser:
 		return "user"
 	case ResourceTypeWorkspace:
@@ -50,6 +57,14 @@ func (r ResourceType) FriendlyString() string {
 		return "license"
 	case ResourceTypeConvertLogin:
 		return "login type conversion"
+	case ResourceTypeProvisionerKey:
+		return "provisioner key"
+	case ResourceTypeTemplateParameterValidation:
+		return "template parameter validation"
+	case ResourceTypeOrganizationTemplateVariable:
+		return "organization template variable"
+	case ResourceTypeCustomRole:
+		return "custom role"
 	default:
 		return "unknown"
 	}
@@ -138,7 +153,7 @@ type CreateTestAuditLogRequest struct {
 	ResourceID       uuid.UUID       `json:"resource_id,omitempty" format:"uuid"`
 	AdditionalFields json.RawMessage `json:"additional_fields,omitempty"`
 	Time             time.Time       `json:"time,omitempty" format:"date-time"`
-	BuildReason      BuildReason     `json:"build_reason,omitempty" enums:"autostart,autostop,initiator"`
+	BuildReason      BuildReason     `json:"build_reason,omitempty" enums:"autostart,autostop,initiator,restart_requirement,failure_ttl,inactivity_ttl,locked_ttl,admin_forced,max_lifetime,prebuild,resume"`
 }
 
 // AuditLogs retrieves audit logs from the given page.
@@ -47,6 +47,15 @@ const (
 	// token.
 	//nolint:gosec
 	DevURLSignedAppTokenCookie = "coder_devurl_signed_app_token"
+	// DevURLOIDCSessionCookie is the name of the cookie that stores the
+	// session of a user that logged in with the workspace app OIDC provider.
+	// It is only valid on the app host it was set on.
+	//nolint:gosec
+	DevURLOIDCSessionCookie = "coder_devurl_oidc_session"
+	// DevURLReplicaCookie is the name of the cookie that stores the signed ID
+	// of the replica that serves the requests of a browser to a subdomain app
+	// when sticky sessions are enabled.
+	DevURLReplicaCookie = "coder_devurl_replica"
 	// SignedAppTokenQueryParameter is the name of the query parameter that
 	// stores a temporary JWT that can be used to authenticate instead of the
 	// session token. This is only acceptable on reconnecting-pty requests, not
@@ -74,6 +83,10 @@ const (
 
 	// ProvisionerDaemonPSK contains the authentication pre-shared key for an external provisioner daemon
 	ProvisionerDaemonPSK = "Coder-Provisioner-Daemon-PSK"
+
+	// ProvisionerDaemonKey contains the provisioner key an external provisioner
+	// daemon authenticates with.
+	ProvisionerDaemonKey = "Coder-Provisioner-Daemon-Key"
 )
 
 // loggableMimeTypes is a list of MIME types that are safe to log
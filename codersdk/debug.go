@@ -0,0 +1,29 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/tailnet"
+)
+
+// DebugCoordinator returns the state of the tailnet coordinator of the coderd
+// replica that served the request.
+func (c *Client) DebugCoordinator(ctx context.Context) (tailnet.HTMLDebug, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/debug/coordinator", nil, func(r *http.Request) {
+		r.Header.Set("Accept", "application/json")
+	})
+	if err != nil {
+		return tailnet.HTMLDebug{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return tailnet.HTMLDebug{}, ReadBodyAsError(res)
+	}
+	var debug tailnet.HTMLDebug
+	return debug, json.NewDecoder(res.Body).Decode(&debug)
+}
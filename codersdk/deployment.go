@@ -48,6 +48,9 @@ const (
 	FeatureAdvancedTemplateScheduling FeatureName = "advanced_template_scheduling"
 	FeatureTemplateRestartRequirement FeatureName = "template_restart_requirement"
 	FeatureWorkspaceProxy             FeatureName = "workspace_proxy"
+	FeatureJITProvisioning            FeatureName = "jit_provisioning"
+	FeatureWorkspaceAppOIDC           FeatureName = "workspace_app_oidc"
+	FeatureTemplateResourceQuotas     FeatureName = "template_resource_quotas"
 )
 
 // FeatureNames must be kept in-sync with the Feature enum above.
@@ -64,6 +67,9 @@ var FeatureNames = []FeatureName{
 	FeatureAdvancedTemplateScheduling,
 	FeatureWorkspaceProxy,
 	FeatureUserRoleManagement,
+	FeatureJITProvisioning,
+	FeatureWorkspaceAppOIDC,
+	FeatureTemplateResourceQuotas,
 }
 
 // Humanize returns the feature name in a human-readable format.
@@ -73,6 +79,10 @@ func (n FeatureName) Humanize() string {
 		return "Template RBAC"
 	case FeatureSCIM:
 		return "SCIM"
+	case FeatureJITProvisioning:
+		return "JIT Provisioning"
+	case FeatureWorkspaceAppOIDC:
+		return "Workspace App OIDC"
 	default:
 		return strings.Title(strings.ReplaceAll(string(n), "_", " "))
 	}
@@ -126,51 +136,66 @@ type DeploymentValues struct {
 	DocsURL             clibase.URL  `json:"docs_url,omitempty"`
 	RedirectToAccessURL clibase.Bool `json:"redirect_to_access_url,omitempty"`
 	// HTTPAddress is a string because it may be set to zero to disable.
-	HTTPAddress                     clibase.String                  `json:"http_address,omitempty" typescript:",notnull"`
-	AutobuildPollInterval           clibase.Duration                `json:"autobuild_poll_interval,omitempty"`
-	JobHangDetectorInterval         clibase.Duration                `json:"job_hang_detector_interval,omitempty"`
-	DERP                            DERP                            `json:"derp,omitempty" typescript:",notnull"`
-	Prometheus                      PrometheusConfig                `json:"prometheus,omitempty" typescript:",notnull"`
-	Pprof                           PprofConfig                     `json:"pprof,omitempty" typescript:",notnull"`
-	ProxyTrustedHeaders             clibase.StringArray             `json:"proxy_trusted_headers,omitempty" typescript:",notnull"`
-	ProxyTrustedOrigins             clibase.StringArray             `json:"proxy_trusted_origins,omitempty" typescript:",notnull"`
-	CacheDir                        clibase.String                  `json:"cache_directory,omitempty" typescript:",notnull"`
-	InMemoryDatabase                clibase.Bool                    `json:"in_memory_database,omitempty" typescript:",notnull"`
-	PostgresURL                     clibase.String                  `json:"pg_connection_url,omitempty" typescript:",notnull"`
-	OAuth2                          OAuth2Config                    `json:"oauth2,omitempty" typescript:",notnull"`
-	OIDC                            OIDCConfig                      `json:"oidc,omitempty" typescript:",notnull"`
-	Telemetry                       TelemetryConfig                 `json:"telemetry,omitempty" typescript:",notnull"`
-	TLS                             TLSConfig                       `json:"tls,omitempty" typescript:",notnull"`
-	Trace                           TraceConfig                     `json:"trace,omitempty" typescript:",notnull"`
-	SecureAuthCookie                clibase.Bool                    `json:"secure_auth_cookie,omitempty" typescript:",notnull"`
-	StrictTransportSecurity         clibase.Int64                   `json:"strict_transport_security,omitempty" typescript:",notnull"`
-	StrictTransportSecurityOptions  clibase.StringArray             `json:"strict_transport_security_options,omitempty" typescript:",notnull"`
-	SSHKeygenAlgorithm              clibase.String                  `json:"ssh_keygen_algorithm,omitempty" typescript:",notnull"`
-	MetricsCacheRefreshInterval     clibase.Duration                `json:"metrics_cache_refresh_interval,omitempty" typescript:",notnull"`
-	AgentStatRefreshInterval        clibase.Duration                `json:"agent_stat_refresh_interval,omitempty" typescript:",notnull"`
-	AgentFallbackTroubleshootingURL clibase.URL                     `json:"agent_fallback_troubleshooting_url,omitempty" typescript:",notnull"`
-	BrowserOnly                     clibase.Bool                    `json:"browser_only,omitempty" typescript:",notnull"`
-	SCIMAPIKey                      clibase.String                  `json:"scim_api_key,omitempty" typescript:",notnull"`
-	Provisioner                     ProvisionerConfig               `json:"provisioner,omitempty" typescript:",notnull"`
-	RateLimit                       RateLimitConfig                 `json:"rate_limit,omitempty" typescript:",notnull"`
-	Experiments                     clibase.StringArray             `json:"experiments,omitempty" typescript:",notnull"`
-	UpdateCheck                     clibase.Bool                    `json:"update_check,omitempty" typescript:",notnull"`
-	MaxTokenLifetime                clibase.Duration                `json:"max_token_lifetime,omitempty" typescript:",notnull"`
-	Swagger                         SwaggerConfig                   `json:"swagger,omitempty" typescript:",notnull"`
-	Logging                         LoggingConfig                   `json:"logging,omitempty" typescript:",notnull"`
-	Dangerous                       DangerousConfig                 `json:"dangerous,omitempty" typescript:",notnull"`
-	DisablePathApps                 clibase.Bool                    `json:"disable_path_apps,omitempty" typescript:",notnull"`
-	SessionDuration                 clibase.Duration                `json:"max_session_expiry,omitempty" typescript:",notnull"`
-	DisableSessionExpiryRefresh     clibase.Bool                    `json:"disable_session_expiry_refresh,omitempty" typescript:",notnull"`
-	DisablePasswordAuth             clibase.Bool                    `json:"disable_password_auth,omitempty" typescript:",notnull"`
-	Support                         SupportConfig                   `json:"support,omitempty" typescript:",notnull"`
-	GitAuthProviders                clibase.Struct[[]GitAuthConfig] `json:"git_auth,omitempty" typescript:",notnull"`
-	SSHConfig                       SSHConfig                       `json:"config_ssh,omitempty" typescript:",notnull"`
-	WgtunnelHost                    clibase.String                  `json:"wgtunnel_host,omitempty" typescript:",notnull"`
-	DisableOwnerWorkspaceExec       clibase.Bool                    `json:"disable_owner_workspace_exec,omitempty" typescript:",notnull"`
-	ProxyHealthStatusInterval       clibase.Duration                `json:"proxy_health_status_interval,omitempty" typescript:",notnull"`
-	EnableTerraformDebugMode        clibase.Bool                    `json:"enable_terraform_debug_mode,omitempty" typescript:",notnull"`
-	UserQuietHoursSchedule          UserQuietHoursScheduleConfig    `json:"user_quiet_hours_schedule,omitempty" typescript:",notnull"`
+	HTTPAddress                     clibase.String                                     `json:"http_address,omitempty" typescript:",notnull"`
+	HTTP2Cleartext                  clibase.Bool                                       `json:"http2_cleartext,omitempty" typescript:",notnull"`
+	AutobuildPollInterval           clibase.Duration                                   `json:"autobuild_poll_interval,omitempty"`
+	JobHangDetectorInterval         clibase.Duration                                   `json:"job_hang_detector_interval,omitempty"`
+	DERP                            DERP                                               `json:"derp,omitempty" typescript:",notnull"`
+	Prometheus                      PrometheusConfig                                   `json:"prometheus,omitempty" typescript:",notnull"`
+	WorkspaceAppStats               WorkspaceAppStatsConfig                            `json:"workspace_app_stats,omitempty" typescript:",notnull"`
+	Pprof                           PprofConfig                                        `json:"pprof,omitempty" typescript:",notnull"`
+	ProxyTrustedHeaders             clibase.StringArray                                `json:"proxy_trusted_headers,omitempty" typescript:",notnull"`
+	ProxyTrustedOrigins             clibase.StringArray                                `json:"proxy_trusted_origins,omitempty" typescript:",notnull"`
+	CacheDir                        clibase.String                                     `json:"cache_directory,omitempty" typescript:",notnull"`
+	InMemoryDatabase                clibase.Bool                                       `json:"in_memory_database,omitempty" typescript:",notnull"`
+	PostgresURL                     clibase.String                                     `json:"pg_connection_url,omitempty" typescript:",notnull"`
+	OAuth2                          OAuth2Config                                       `json:"oauth2,omitempty" typescript:",notnull"`
+	OIDC                            OIDCConfig                                         `json:"oidc,omitempty" typescript:",notnull"`
+	Telemetry                       TelemetryConfig                                    `json:"telemetry,omitempty" typescript:",notnull"`
+	TLS                             TLSConfig                                          `json:"tls,omitempty" typescript:",notnull"`
+	Trace                           TraceConfig                                        `json:"trace,omitempty" typescript:",notnull"`
+	SecureAuthCookie                clibase.Bool                                       `json:"secure_auth_cookie,omitempty" typescript:",notnull"`
+	StrictTransportSecurity         clibase.Int64                                      `json:"strict_transport_security,omitempty" typescript:",notnull"`
+	StrictTransportSecurityOptions  clibase.StringArray                                `json:"strict_transport_security_options,omitempty" typescript:",notnull"`
+	SSHKeygenAlgorithm              clibase.String                                     `json:"ssh_keygen_algorithm,omitempty" typescript:",notnull"`
+	DisableX11Forwarding            clibase.Bool                                       `json:"disable_x11_forwarding,omitempty" typescript:",notnull"`
+	MetricsCacheRefreshInterval     clibase.Duration                                   `json:"metrics_cache_refresh_interval,omitempty" typescript:",notnull"`
+	AgentStatRefreshInterval        clibase.Duration                                   `json:"agent_stat_refresh_interval,omitempty" typescript:",notnull"`
+	AgentFallbackTroubleshootingURL clibase.URL                                        `json:"agent_fallback_troubleshooting_url,omitempty" typescript:",notnull"`
+	AgentDrainGracePeriod           clibase.Duration                                   `json:"agent_drain_grace_period,omitempty" typescript:",notnull"`
+	BrowserOnly                     clibase.Bool                                       `json:"browser_only,omitempty" typescript:",notnull"`
+	SCIMAPIKey                      clibase.String                                     `json:"scim_api_key,omitempty" typescript:",notnull"`
+	SCIMGroupTemplateACLs           clibase.Struct[map[string]map[string]TemplateRole] `json:"scim_group_template_acls,omitempty" typescript:",notnull"`
+	Provisioner                     ProvisionerConfig                                  `json:"provisioner,omitempty" typescript:",notnull"`
+	RateLimit                       RateLimitConfig                                    `json:"rate_limit,omitempty" typescript:",notnull"`
+	Experiments                     clibase.StringArray                                `json:"experiments,omitempty" typescript:",notnull"`
+	UpdateCheck                     clibase.Bool                                       `json:"update_check,omitempty" typescript:",notnull"`
+	MaxTokenLifetime                clibase.Duration                                   `json:"max_token_lifetime,omitempty" typescript:",notnull"`
+	Swagger                         SwaggerConfig                                      `json:"swagger,omitempty" typescript:",notnull"`
+	Logging                         LoggingConfig                                      `json:"logging,omitempty" typescript:",notnull"`
+	Dangerous                       DangerousConfig                                    `json:"dangerous,omitempty" typescript:",notnull"`
+	DisablePathApps                 clibase.Bool                                       `json:"disable_path_apps,omitempty" typescript:",notnull"`
+	SessionDuration                 clibase.Duration                                   `json:"max_session_expiry,omitempty" typescript:",notnull"`
+	DisableSessionExpiryRefresh     clibase.Bool                                       `json:"disable_session_expiry_refresh,omitempty" typescript:",notnull"`
+	DisablePasswordAuth             clibase.Bool                                       `json:"disable_password_auth,omitempty" typescript:",notnull"`
+	Support                         SupportConfig                                      `json:"support,omitempty" typescript:",notnull"`
+	GitAuthProviders                clibase.Struct[[]GitAuthConfig]                    `json:"git_auth,omitempty" typescript:",notnull"`
+	SSHConfig                       SSHConfig                                          `json:"config_ssh,omitempty" typescript:",notnull"`
+	WgtunnelHost                    clibase.String                                     `json:"wgtunnel_host,omitempty" typescript:",notnull"`
+	DisableOwnerWorkspaceExec       clibase.Bool                                       `json:"disable_owner_workspace_exec,omitempty" typescript:",notnull"`
+	ProxyHealthStatusInterval       clibase.Duration                                   `json:"proxy_health_status_interval,omitempty" typescript:",notnull"`
+	EnableTerraformDebugMode        clibase.Bool                                       `json:"enable_terraform_debug_mode,omitempty" typescript:",notnull"`
+	UserQuietHoursSchedule          UserQuietHoursScheduleConfig                       `json:"user_quiet_hours_schedule,omitempty" typescript:",notnull"`
+	Webhooks                        WebhooksConfig                                     `json:"webhooks,omitempty" typescript:",notnull"`
+	AuditExport                     AuditExportConfig                                  `json:"audit_export,omitempty" typescript:",notnull"`
+	AuditLogRetention               AuditLogRetentionConfig                            `json:"audit_log_retention,omitempty" typescript:",notnull"`
+	WorkspaceAppOIDC                WorkspaceAppOIDCConfig                             `json:"workspace_app_oidc,omitempty" typescript:",notnull"`
+	WorkspaceAppStickySessions      clibase.Bool                                       `json:"workspace_app_sticky_sessions,omitempty" typescript:",notnull"`
+	UserSecretsEncryptionKey        clibase.String                                     `json:"user_secrets_encryption_key,omitempty" typescript:",notnull"`
+	TemplateRegistries              clibase.StringArray                                `json:"template_registries,omitempty" typescript:",notnull"`
+	ServerTailnetMaxIdlePerAgent    clibase.Int64                                      `json:"server_tailnet_max_idle_per_agent,omitempty" typescript:",notnull"`
+	ServerTailnetIdleTTL            clibase.Duration                                   `json:"server_tailnet_idle_ttl,omitempty" typescript:",notnull"`
+	ServerTailnetHealthInterval     clibase.Duration                                   `json:"server_tailnet_health_check_interval,omitempty" typescript:",notnull"`
 
 	Config      clibase.YAMLConfigPath `json:"config,omitempty" typescript:",notnull"`
 	WriteConfig clibase.Bool           `json:"write_config,omitempty" typescript:",notnull"`
@@ -228,9 +253,10 @@ type DERPServerConfig struct {
 }
 
 type DERPConfig struct {
-	BlockDirect clibase.Bool   `json:"block_direct" typescript:",notnull"`
-	URL         clibase.String `json:"url" typescript:",notnull"`
-	Path        clibase.String `json:"path" typescript:",notnull"`
+	BlockDirect    clibase.Bool     `json:"block_direct" typescript:",notnull"`
+	URL            clibase.String   `json:"url" typescript:",notnull"`
+	Path           clibase.String   `json:"path" typescript:",notnull"`
+	ReloadInterval clibase.Duration `json:"reload_interval" typescript:",notnull"`
 }
 
 type PrometheusConfig struct {
@@ -240,6 +266,12 @@ type PrometheusConfig struct {
 	CollectDBMetrics  clibase.Bool     `json:"collect_db_metrics" typescript:",notnull"`
 }
 
+type WorkspaceAppStatsConfig struct {
+	StatsDAddress clibase.String `json:"statsd_address" typescript:",notnull"`
+	OTLPEndpoint  clibase.String `json:"otlp_endpoint" typescript:",notnull"`
+	AccessLogs    clibase.Bool   `json:"access_logs" typescript:",notnull"`
+}
+
 type PprofConfig struct {
 	Enable  clibase.Bool     `json:"enable" typescript:",notnull"`
 	Address clibase.HostPort `json:"address" typescript:",notnull"`
@@ -264,25 +296,26 @@ type OIDCConfig struct {
 	ClientID     clibase.String `json:"client_id" typescript:",notnull"`
 	ClientSecret clibase.String `json:"client_secret" typescript:",notnull"`
 	// ClientKeyFile & ClientCertFile are used in place of ClientSecret for PKI auth.
-	ClientKeyFile       clibase.String                      `json:"client_key_file" typescript:",notnull"`
-	ClientCertFile      clibase.String                      `json:"client_cert_file" typescript:",notnull"`
-	EmailDomain         clibase.StringArray                 `json:"email_domain" typescript:",notnull"`
-	IssuerURL           clibase.String                      `json:"issuer_url" typescript:",notnull"`
-	Scopes              clibase.StringArray                 `json:"scopes" typescript:",notnull"`
-	IgnoreEmailVerified clibase.Bool                        `json:"ignore_email_verified" typescript:",notnull"`
-	UsernameField       clibase.String                      `json:"username_field" typescript:",notnull"`
-	EmailField          clibase.String                      `json:"email_field" typescript:",notnull"`
-	AuthURLParams       clibase.Struct[map[string]string]   `json:"auth_url_params" typescript:",notnull"`
-	IgnoreUserInfo      clibase.Bool                        `json:"ignore_user_info" typescript:",notnull"`
-	GroupAutoCreate     clibase.Bool                        `json:"group_auto_create" typescript:",notnull"`
-	GroupRegexFilter    clibase.Regexp                      `json:"group_regex_filter" typescript:",notnull"`
-	GroupField          clibase.String                      `json:"groups_field" typescript:",notnull"`
-	GroupMapping        clibase.Struct[map[string]string]   `json:"group_mapping" typescript:",notnull"`
-	UserRoleField       clibase.String                      `json:"user_role_field" typescript:",notnull"`
-	UserRoleMapping     clibase.Struct[map[string][]string] `json:"user_role_mapping" typescript:",notnull"`
-	UserRolesDefault    clibase.StringArray                 `json:"user_roles_default" typescript:",notnull"`
-	SignInText          clibase.String                      `json:"sign_in_text" typescript:",notnull"`
-	IconURL             clibase.URL                         `json:"icon_url" typescript:",notnull"`
+	ClientKeyFile         clibase.String                        `json:"client_key_file" typescript:",notnull"`
+	ClientCertFile        clibase.String                        `json:"client_cert_file" typescript:",notnull"`
+	EmailDomain           clibase.StringArray                   `json:"email_domain" typescript:",notnull"`
+	IssuerURL             clibase.String                        `json:"issuer_url" typescript:",notnull"`
+	Scopes                clibase.StringArray                   `json:"scopes" typescript:",notnull"`
+	IgnoreEmailVerified   clibase.Bool                          `json:"ignore_email_verified" typescript:",notnull"`
+	UsernameField         clibase.String                        `json:"username_field" typescript:",notnull"`
+	EmailField            clibase.String                        `json:"email_field" typescript:",notnull"`
+	AuthURLParams         clibase.Struct[map[string]string]     `json:"auth_url_params" typescript:",notnull"`
+	IgnoreUserInfo        clibase.Bool                          `json:"ignore_user_info" typescript:",notnull"`
+	GroupAutoCreate       clibase.Bool                          `json:"group_auto_create" typescript:",notnull"`
+	GroupRegexFilter      clibase.Regexp                        `json:"group_regex_filter" typescript:",notnull"`
+	GroupField            clibase.String                        `json:"groups_field" typescript:",notnull"`
+	GroupMapping          clibase.Struct[map[string]string]     `json:"group_mapping" typescript:",notnull"`
+	UserRoleField         clibase.String                        `json:"user_role_field" typescript:",notnull"`
+	UserRoleMapping       clibase.Struct[map[string][]string]   `json:"user_role_mapping" typescript:",notnull"`
+	UserRolesDefault      clibase.StringArray                   `json:"user_roles_default" typescript:",notnull"`
+	SignInText            clibase.String                        `json:"sign_in_text" typescript:",notnull"`
+	IconURL               clibase.URL                           `json:"icon_url" typescript:",notnull"`
+	TokenExchangePolicies clibase.Struct[[]TokenExchangePolicy] `json:"token_exchange_policies" typescript:",notnull"`
 }
 
 type TelemetryConfig struct {
@@ -292,16 +325,23 @@ type TelemetryConfig struct {
 }
 
 type TLSConfig struct {
-	Enable         clibase.Bool        `json:"enable" typescript:",notnull"`
-	Address        clibase.HostPort    `json:"address" typescript:",notnull"`
-	RedirectHTTP   clibase.Bool        `json:"redirect_http" typescript:",notnull"`
-	CertFiles      clibase.StringArray `json:"cert_file" typescript:",notnull"`
-	ClientAuth     clibase.String      `json:"client_auth" typescript:",notnull"`
-	ClientCAFile   clibase.String      `json:"client_ca_file" typescript:",notnull"`
-	KeyFiles       clibase.StringArray `json:"key_file" typescript:",notnull"`
-	MinVersion     clibase.String      `json:"min_version" typescript:",notnull"`
-	ClientCertFile clibase.String      `json:"client_cert_file" typescript:",notnull"`
-	ClientKeyFile  clibase.String      `json:"client_key_file" typescript:",notnull"`
+	Enable                 clibase.Bool        `json:"enable" typescript:",notnull"`
+	Address                clibase.HostPort    `json:"address" typescript:",notnull"`
+	RedirectHTTP           clibase.Bool        `json:"redirect_http" typescript:",notnull"`
+	CertFiles              clibase.StringArray `json:"cert_file" typescript:",notnull"`
+	ClientAuth             clibase.String      `json:"client_auth" typescript:",notnull"`
+	ClientCAFile           clibase.String      `json:"client_ca_file" typescript:",notnull"`
+	KeyFiles               clibase.StringArray `json:"key_file" typescript:",notnull"`
+	MinVersion             clibase.String      `json:"min_version" typescript:",notnull"`
+	ClientCertFile         clibase.String      `json:"client_cert_file" typescript:",notnull"`
+	ClientKeyFile          clibase.String      `json:"client_key_file" typescript:",notnull"`
+	ACMEEnable             clibase.Bool        `json:"acme_enable" typescript:",notnull"`
+	ACMEDirectoryURL       clibase.String      `json:"acme_directory_url" typescript:",notnull"`
+	ACMEEmail              clibase.String      `json:"acme_email" typescript:",notnull"`
+	ACMEDNSHook            clibase.String      `json:"acme_dns_hook" typescript:",notnull"`
+	ACMEEncryptionKey      clibase.String      `json:"acme_encryption_key" typescript:",notnull"`
+	ProxyClientCAFile      clibase.String      `json:"proxy_client_ca_file" typescript:",notnull"`
+	ProxyRequireClientCert clibase.Bool        `json:"proxy_require_client_cert" typescript:",notnull"`
 }
 
 type TraceConfig struct {
@@ -334,6 +374,7 @@ type ProvisionerConfig struct {
 	DaemonPollJitter    clibase.Duration `json:"daemon_poll_jitter" typescript:",notnull"`
 	ForceCancelInterval clibase.Duration `json:"force_cancel_interval" typescript:",notnull"`
 	DaemonPSK           clibase.String   `json:"daemon_psk" typescript:",notnull"`
+	DaemonCacheMaxSize  clibase.Int64    `json:"daemon_cache_max_size" typescript:",notnull"`
 }
 
 type RateLimitConfig struct {
@@ -365,6 +406,34 @@ type UserQuietHoursScheduleConfig struct {
 	// WindowDuration  clibase.Duration `json:"window_duration" typescript:",notnull"`
 }
 
+type WebhooksConfig struct {
+	URLs                     clibase.StringArray `json:"urls" typescript:",notnull"`
+	SigningSecret            clibase.String      `json:"signing_secret" typescript:",notnull"`
+	MaxAttempts              clibase.Int64       `json:"max_attempts" typescript:",notnull"`
+	AutostopImminentWindow   clibase.Duration    `json:"autostop_imminent_window" typescript:",notnull"`
+	ParameterValidationHosts clibase.StringArray `json:"parameter_validation_hosts" typescript:",notnull"`
+}
+
+type AuditExportConfig struct {
+	Sink       clibase.String `json:"sink" typescript:",notnull"`
+	URL        clibase.String `json:"url" typescript:",notnull"`
+	Token      clibase.String `json:"token" typescript:",notnull"`
+	KafkaTopic clibase.String `json:"kafka_topic" typescript:",notnull"`
+	BatchSize  clibase.Int64  `json:"batch_size" typescript:",notnull"`
+}
+
+type AuditLogRetentionConfig struct {
+	Days       clibase.Int64  `json:"days" typescript:",notnull"`
+	ArchiveURL clibase.String `json:"archive_url" typescript:",notnull"`
+}
+
+type WorkspaceAppOIDCConfig struct {
+	IssuerURL    clibase.String      `json:"issuer_url" typescript:",notnull"`
+	ClientID     clibase.String      `json:"client_id" typescript:",notnull"`
+	ClientSecret clibase.String      `json:"client_secret" typescript:",notnull"`
+	EmailDomain  clibase.StringArray `json:"email_domain" typescript:",notnull"`
+}
+
 const (
 	annotationEnterpriseKey = "enterprise"
 	annotationSecretKey     = "secret"
@@ -452,6 +521,12 @@ func (c *DeploymentValues) Options() clibase.OptionSet {
 			Name:   "Prometheus",
 			YAML:   "prometheus",
 		}
+		deploymentGroupIntrospectionWorkspaceAppStats = clibase.Group{
+			Parent:      &deploymentGroupIntrospection,
+			Name:        "Workspace App Stats",
+			Description: `Stream workspace app usage stats to external metrics systems, in addition to the database.`,
+			YAML:        "workspaceAppStats",
+		}
 		deploymentGroupIntrospectionTracing = clibase.Group{
 			Parent: &deploymentGroupIntrospection,
 			Name:   "Tracing",
@@ -493,6 +568,26 @@ when required by your organization's security policy.`,
 			Description: "Allow users to set quiet hours schedules each day for workspaces to avoid workspaces stopping during the day due to template max TTL.",
 			YAML:        "userQuietHoursSchedule",
 		}
+		deploymentGroupWebhooks = clibase.Group{
+			Name:        "Webhooks",
+			Description: "Send workspace lifecycle events, such as upcoming autostops, to external HTTP endpoints.",
+			YAML:        "webhooks",
+		}
+		deploymentGroupAuditExport = clibase.Group{
+			Name:        "Audit Export",
+			Description: "Stream audit logs to an external sink in addition to storing them in the database.",
+			YAML:        "auditExport",
+		}
+		deploymentGroupAuditLogRetention = clibase.Group{
+			Name:        "Audit Log Retention",
+			Description: "Delete old audit logs from the database, optionally archiving them to object storage first.",
+			YAML:        "auditLogRetention",
+		}
+		deploymentGroupWorkspaceAppOIDC = clibase.Group{
+			Name:        "Workspace App OIDC",
+			Description: `Let users without a Coder account use subdomain apps with auth = "oidc" after logging in with an external OIDC provider.`,
+			YAML:        "workspaceAppOIDC",
+		}
 		deploymentGroupDangerous = clibase.Group{
 			Name: "⚠️ Dangerous",
 			YAML: "dangerous",
@@ -603,6 +698,16 @@ when required by your organization's security policy.`,
 			YAML:        "jobHangDetectorInterval",
 		},
 		httpAddress,
+		{
+			Name:        "HTTP/2 Cleartext",
+			Description: "Accept HTTP/2 requests without TLS (h2c) on the HTTP address, e.g. to serve gRPC workspace apps behind a load balancer that terminates TLS. Only enable this if every client of the HTTP address is trusted to speak HTTP/2, as h2c upgrades bypass the HTTP/1.1 handling of intermediate proxies.",
+			Flag:        "http2-cleartext",
+			Env:         "CODER_HTTP2_CLEARTEXT",
+			Value:       &c.HTTP2Cleartext,
+			Group:       &deploymentGroupNetworkingHTTP,
+			YAML:        "http2Cleartext",
+			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
 		tlsBindAddress,
 		{
 			Name:          "Address",
@@ -715,6 +820,74 @@ when required by your organization's security policy.`,
 			YAML:        "clientKeyFile",
 			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
 		},
+		{
+			Name:        "TLS ACME Enable",
+			Description: "Whether to issue and renew the certificate of the wildcard access URL over ACME with DNS-01 challenges. The certificate is stored encrypted in the database and shared by all replicas.",
+			Flag:        "tls-acme-enable",
+			Env:         "CODER_TLS_ACME_ENABLE",
+			Value:       &c.TLS.ACMEEnable,
+			Group:       &deploymentGroupNetworkingTLS,
+			YAML:        "acmeEnable",
+			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
+		{
+			Name:        "TLS ACME Directory URL",
+			Description: "The directory URL of the ACME server to issue the certificate of the wildcard access URL with.",
+			Flag:        "tls-acme-directory-url",
+			Env:         "CODER_TLS_ACME_DIRECTORY_URL",
+			Default:     "https://acme-v02.api.letsencrypt.org/directory",
+			Value:       &c.TLS.ACMEDirectoryURL,
+			Group:       &deploymentGroupNetworkingTLS,
+			YAML:        "acmeDirectoryURL",
+			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
+		{
+			Name:        "TLS ACME Email",
+			Description: "The email address of the ACME account, to receive notices about the certificate of the wildcard access URL.",
+			Flag:        "tls-acme-email",
+			Env:         "CODER_TLS_ACME_EMAIL",
+			Value:       &c.TLS.ACMEEmail,
+			Group:       &deploymentGroupNetworkingTLS,
+			YAML:        "acmeEmail",
+			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
+		{
+			Name:        "TLS ACME DNS Hook",
+			Description: "Path to an executable that creates and deletes the DNS TXT records of DNS-01 challenges. It is called with \"present\" or \"cleanup\", the record name and the record value as arguments. On \"present\", it must only exit once the record can be resolved.",
+			Flag:        "tls-acme-dns-hook",
+			Env:         "CODER_TLS_ACME_DNS_HOOK",
+			Value:       &c.TLS.ACMEDNSHook,
+			Group:       &deploymentGroupNetworkingTLS,
+			YAML:        "acmeDNSHook",
+			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
+		{
+			Name:        "TLS ACME Encryption Key",
+			Description: "Base64 encoded 32 byte key that certificates issued over ACME are encrypted with in the database. Must be the same on all replicas.",
+			Flag:        "tls-acme-encryption-key",
+			Env:         "CODER_TLS_ACME_ENCRYPTION_KEY",
+			Annotations: clibase.Annotations{}.Mark(annotationSecretKey, "true").Mark(annotationExternalProxies, "true"),
+			Value:       &c.TLS.ACMEEncryptionKey,
+			Group:       &deploymentGroupNetworkingTLS,
+		},
+		{
+			Name:        "TLS Proxy Client CA File",
+			Description: "PEM-encoded Certificate Authority file used for checking the authenticity of client certificates presented by workspace proxies. The certificate of a proxy must have the name of the proxy as common name or DNS name.",
+			Flag:        "tls-proxy-client-ca-file",
+			Env:         "CODER_TLS_PROXY_CLIENT_CA_FILE",
+			Value:       &c.TLS.ProxyClientCAFile,
+			Group:       &deploymentGroupNetworkingTLS,
+			YAML:        "proxyClientCAFile",
+		},
+		{
+			Name:        "TLS Proxy Require Client Cert",
+			Description: "Whether workspace proxies must present a client certificate signed by the proxy client CA in addition to their token. Proxies that present a certificate are always verified. Requires TLS to be terminated by Coder.",
+			Flag:        "tls-proxy-require-client-cert",
+			Env:         "CODER_TLS_PROXY_REQUIRE_CLIENT_CERT",
+			Value:       &c.TLS.ProxyRequireClientCert,
+			Group:       &deploymentGroupNetworkingTLS,
+			YAML:        "proxyRequireClientCert",
+		},
 		// Derp settings
 		{
 			Name:        "DERP Server Enable",
@@ -814,6 +987,16 @@ when required by your organization's security policy.`,
 			Group:       &deploymentGroupNetworkingDERP,
 			YAML:        "configPath",
 		},
+		{
+			Name:        "DERP Config Reload Interval",
+			Description: "How often to reload the DERP mapping from the DERP config URL or path. Changes are pushed to connected agents and clients without restarting Coder. Set to 0 to disable reloading.",
+			Flag:        "derp-config-reload-interval",
+			Env:         "CODER_DERP_CONFIG_RELOAD_INTERVAL",
+			Default:     "0",
+			Value:       &c.DERP.Config.ReloadInterval,
+			Group:       &deploymentGroupNetworkingDERP,
+			YAML:        "reloadInterval",
+		},
 		// TODO: support Git Auth settings.
 		// Prometheus settings
 		{
@@ -856,6 +1039,35 @@ when required by your organization's security policy.`,
 			YAML:        "collect_db_metrics",
 			Default:     "false",
 		},
+		// Workspace app stats settings
+		{
+			Name:        "Workspace App Stats StatsD Address",
+			Description: "The UDP address of a StatsD server to send workspace app usage stats to. Tags are sent in the DogStatsD format.",
+			Flag:        "workspace-app-stats-statsd-address",
+			Env:         "CODER_WORKSPACE_APP_STATS_STATSD_ADDRESS",
+			Value:       &c.WorkspaceAppStats.StatsDAddress,
+			Group:       &deploymentGroupIntrospectionWorkspaceAppStats,
+			YAML:        "statsdAddress",
+		},
+		{
+			Name:        "Workspace App Stats OTLP Endpoint",
+			Description: "The URL of an OTLP/HTTP collector to export workspace app usage stats to as metrics, e.g. http://localhost:4318.",
+			Flag:        "workspace-app-stats-otlp-endpoint",
+			Env:         "CODER_WORKSPACE_APP_STATS_OTLP_ENDPOINT",
+			Value:       &c.WorkspaceAppStats.OTLPEndpoint,
+			Group:       &deploymentGroupIntrospectionWorkspaceAppStats,
+			YAML:        "otlpEndpoint",
+		},
+		{
+			Name:        "Workspace App Stats Access Logs",
+			Description: "Record an access log entry for every request proxied to a workspace app, with the user, app, path prefix, status code and latency. Access logs are stored in the database and can be queried through the insights API.",
+			Flag:        "workspace-app-stats-access-logs",
+			Env:         "CODER_WORKSPACE_APP_STATS_ACCESS_LOGS",
+			Value:       &c.WorkspaceAppStats.AccessLogs,
+			Group:       &deploymentGroupIntrospectionWorkspaceAppStats,
+			YAML:        "accessLogs",
+			Default:     "false",
+		},
 		// Pprof settings
 		{
 			Name:        "pprof Enable",
@@ -1164,6 +1376,15 @@ when required by your organization's security policy.`,
 			Group:       &deploymentGroupOIDC,
 			YAML:        "iconURL",
 		},
+		{
+			Name:        "OIDC Token Exchange Policies",
+			Description: "A YAML list of policies that allow exchanging identity tokens of external OIDC issuers, such as GitHub Actions, for short-lived API tokens. Each policy has an issuer, an audience, the claims that tokens must match, the username the tokens are created for, and optionally a scope and a lifetime.",
+			Flag:        "oidc-token-exchange-policies",
+			Env:         "CODER_OIDC_TOKEN_EXCHANGE_POLICIES",
+			Value:       &c.OIDC.TokenExchangePolicies,
+			Group:       &deploymentGroupOIDC,
+			YAML:        "tokenExchangePolicies",
+		},
 		// Telemetry settings
 		{
 			Name:        "Telemetry Enable",
@@ -1287,6 +1508,16 @@ when required by your organization's security policy.`,
 			Group:       &deploymentGroupProvisioning,
 			YAML:        "daemonPSK",
 		},
+		{
+			Name:        "Provisioner Daemon Cache Max Size",
+			Description: "Maximum size in MiB of the cache of Terraform providers and modules shared by the built-in provisioner daemons, so builds of the same template don't download them again. Set to 0 to disable the cache.",
+			Flag:        "provisioner-daemon-cache-max-size",
+			Env:         "CODER_PROVISIONER_DAEMON_CACHE_MAX_SIZE",
+			Default:     "10240",
+			Value:       &c.Provisioner.DaemonCacheMaxSize,
+			Group:       &deploymentGroupProvisioning,
+			YAML:        "daemonCacheMaxSize",
+		},
 		// RateLimit settings
 		{
 			Name:        "Disable All Rate Limits",
@@ -1468,6 +1699,7 @@ when required by your organization's security policy.`,
 			Default:     DefaultCacheDir(),
 			Value:       &c.CacheDir,
 			YAML:        "cacheDir",
+			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
 		},
 		{
 			Name:        "In Memory Database",
@@ -1529,6 +1761,14 @@ when required by your organization's security policy.`,
 			Value:       &c.SSHKeygenAlgorithm,
 			YAML:        "sshKeygenAlgorithm",
 		},
+		{
+			Name:        "Disable X11 Forwarding",
+			Description: "Disable X11 forwarding to workspaces. Workspace agents reject X11 forwarding requests from SSH clients, such as \"coder ssh -X\" and \"ssh -X\".",
+			Flag:        "disable-x11-forwarding",
+			Env:         "CODER_DISABLE_X11_FORWARDING",
+			Value:       &c.DisableX11Forwarding,
+			YAML:        "disableX11Forwarding",
+		},
 		{
 			Name:        "Metrics Cache Refresh Interval",
 			Description: "How frequently metrics are refreshed.",
@@ -1557,6 +1797,15 @@ when required by your organization's security policy.`,
 			Value:       &c.AgentFallbackTroubleshootingURL,
 			YAML:        "agentFallbackTroubleshootingURL",
 		},
+		{
+			Name:        "Agent Drain Grace Period",
+			Description: "How long workspace agents wait for SSH sessions and web terminals to end before running their shutdown scripts when a workspace is stopped or deleted. Stop and delete builds wait for the agents to drain before tearing down resources. 0 disables draining.",
+			Flag:        "agent-drain-grace-period",
+			Env:         "CODER_AGENT_DRAIN_GRACE_PERIOD",
+			Default:     "0s",
+			Value:       &c.AgentDrainGracePeriod,
+			YAML:        "agentDrainGracePeriod",
+		},
 		{
 			Name:        "Browser Only",
 			Description: "Whether Coder only allows connections to workspaces via the browser.",
@@ -1567,6 +1816,39 @@ when required by your organization's security policy.`,
 			Group:       &deploymentGroupNetworking,
 			YAML:        "browserOnly",
 		},
+		{
+			Name:        "Server Tailnet Max Idle Per Agent",
+			Description: "The maximum number of idle connections that are kept open to each workspace agent to proxy workspace apps. Only used with the single_tailnet experiment.",
+			Flag:        "server-tailnet-max-idle-per-agent",
+			Env:         "CODER_SERVER_TAILNET_MAX_IDLE_PER_AGENT",
+			Default:     "10",
+			Value:       &c.ServerTailnetMaxIdlePerAgent,
+			Group:       &deploymentGroupNetworking,
+			YAML:        "serverTailnetMaxIdlePerAgent",
+			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
+		{
+			Name:        "Server Tailnet Idle TTL",
+			Description: "How long a connection to a workspace agent may go unused before it is closed. Only used with the single_tailnet experiment.",
+			Flag:        "server-tailnet-idle-ttl",
+			Env:         "CODER_SERVER_TAILNET_IDLE_TTL",
+			Default:     "5m0s",
+			Value:       &c.ServerTailnetIdleTTL,
+			Group:       &deploymentGroupNetworking,
+			YAML:        "serverTailnetIdleTTL",
+			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
+		{
+			Name:        "Server Tailnet Health Check Interval",
+			Description: "How often idle connections to workspace agents are pinged. Connections that fail the health check are closed. Only used with the single_tailnet experiment.",
+			Flag:        "server-tailnet-health-check-interval",
+			Env:         "CODER_SERVER_TAILNET_HEALTH_CHECK_INTERVAL",
+			Default:     "30s",
+			Value:       &c.ServerTailnetHealthInterval,
+			Group:       &deploymentGroupNetworking,
+			YAML:        "serverTailnetHealthCheckInterval",
+			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
 		{
 			Name:        "SCIM API Key",
 			Description: "Enables SCIM and sets the authentication header for the built-in SCIM server. New users are automatically created with OIDC authentication.",
@@ -1575,6 +1857,16 @@ when required by your organization's security policy.`,
 			Annotations: clibase.Annotations{}.Mark(annotationEnterpriseKey, "true").Mark(annotationSecretKey, "true"),
 			Value:       &c.SCIMAPIKey,
 		},
+		{
+			Name:        "SCIM Group Template ACLs",
+			Description: "A map of the names of groups pushed by SCIM to the templates their members get access to, and the role on each template, e.g. {\"engineering\": {\"docker\": \"use\"}}. The roles are applied every time a group is pushed, and replace any other roles of the group on templates.",
+			Flag:        "scim-group-template-acls",
+			Env:         "CODER_SCIM_GROUP_TEMPLATE_ACLS",
+			Default:     "{}",
+			Annotations: clibase.Annotations{}.Mark(annotationEnterpriseKey, "true"),
+			Value:       &c.SCIMGroupTemplateACLs,
+			YAML:        "scimGroupTemplateACLs",
+		},
 
 		{
 			Name:        "Disable Path Apps",
@@ -1586,6 +1878,31 @@ when required by your organization's security policy.`,
 			YAML:        "disablePathApps",
 			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
 		},
+		{
+			Name:        "Workspace App Sticky Sessions",
+			Description: "Route all requests of a browser to a subdomain workspace app through the same replica, using a signed cookie. This keeps apps that hold per-connection state in memory on a single replica when multiple Coder replicas or workspace proxy replicas serve the same app hostname.",
+			Flag:        "workspace-app-sticky-sessions",
+			Env:         "CODER_WORKSPACE_APP_STICKY_SESSIONS",
+
+			Value:       &c.WorkspaceAppStickySessions,
+			YAML:        "workspaceAppStickySessions",
+			Annotations: clibase.Annotations{}.Mark(annotationExternalProxies, "true"),
+		},
+		{
+			Name:        "User Secrets Encryption Key",
+			Description: "Base64-encoded 32 byte key that user secrets are encrypted with. Generate one with 'openssl rand -base64 32'. The key must be the same on all replicas. User secrets are disabled if unset.",
+			Flag:        "user-secrets-encryption-key",
+			Env:         "CODER_USER_SECRETS_ENCRYPTION_KEY",
+			Annotations: clibase.Annotations{}.Mark(annotationSecretKey, "true"),
+			Value:       &c.UserSecretsEncryptionKey,
+		},
+		{
+			Name:        "Template Registries",
+			Description: "Registries that templates can be pushed to and pulled from by coderd, as hosts with an optional port like ghcr.io. Prefix a registry with http:// to access it over HTTP. The token services of registries must be on an allowed registry. Pushing and pulling templates through coderd is disabled if unset.",
+			Flag:        "template-registries",
+			Env:         "CODER_TEMPLATE_REGISTRIES",
+			Value:       &c.TemplateRegistries,
+		},
 		{
 			Name:        "Disable Owner Workspace Access",
 			Description: "Remove the permission for the 'owner' role to have workspace execution on all workspaces. This prevents the 'owner' from ssh, apps, and terminal access based on the 'owner' role. They still have their user permissions to access their own workspaces.",
@@ -1718,6 +2035,161 @@ Write out the current server config as YAML to stdout.`,
 			Group:       &deploymentGroupUserQuietHoursSchedule,
 			YAML:        "defaultQuietHoursSchedule",
 		},
+		{
+			Name:        "Webhook URLs",
+			Description: "URLs to send workspace lifecycle events to as JSON POST requests. Events are sent when a workspace is about to be autostopped, when it has been stopped by the server, and when a locked workspace is deleted.",
+			Flag:        "webhook-urls",
+			Env:         "CODER_WEBHOOK_URLS",
+			Value:       &c.Webhooks.URLs,
+			Group:       &deploymentGroupWebhooks,
+			YAML:        "urls",
+		},
+		{
+			Name:        "Webhook Signing Secret",
+			Description: "Secret used to sign webhook requests. The HMAC-SHA256 signature of the request body is sent in the X-Coder-Signature-256 header.",
+			Flag:        "webhook-signing-secret",
+			Env:         "CODER_WEBHOOK_SIGNING_SECRET",
+			Annotations: clibase.Annotations{}.Mark(annotationSecretKey, "true"),
+			Value:       &c.Webhooks.SigningSecret,
+			Group:       &deploymentGroupWebhooks,
+		},
+		{
+			Name:        "Webhook Max Attempts",
+			Description: "The number of times delivery of a webhook event is attempted before it is dropped. Deliveries are retried with exponential backoff when the endpoint is unreachable or responds with a 429 or 5xx status code.",
+			Flag:        "webhook-max-attempts",
+			Env:         "CODER_WEBHOOK_MAX_ATTEMPTS",
+			Default:     "5",
+			Value:       &c.Webhooks.MaxAttempts,
+			Group:       &deploymentGroupWebhooks,
+			YAML:        "maxAttempts",
+		},
+		{
+			Name:        "Webhook Autostop Imminent Window",
+			Description: "How long before a workspace is autostopped to send the workspace.autostop_imminent event. Set to 0 to disable the event.",
+			Flag:        "webhook-autostop-imminent-window",
+			Env:         "CODER_WEBHOOK_AUTOSTOP_IMMINENT_WINDOW",
+			Default:     (30 * time.Minute).String(),
+			Value:       &c.Webhooks.AutostopImminentWindow,
+			Group:       &deploymentGroupWebhooks,
+			YAML:        "autostopImminentWindow",
+		},
+		{
+			Name:        "Webhook Parameter Validation Hosts",
+			Description: "Hosts that the parameter validation webhooks of templates may be sent to, with an optional port like validation.example.com. Templates can't use parameter validation webhooks if unset.",
+			Flag:        "webhook-parameter-validation-hosts",
+			Env:         "CODER_WEBHOOK_PARAMETER_VALIDATION_HOSTS",
+			Value:       &c.Webhooks.ParameterValidationHosts,
+			Group:       &deploymentGroupWebhooks,
+			YAML:        "parameterValidationHosts",
+		},
+		{
+			Name:        "Audit Export Sink",
+			Description: "The type of sink audit logs are streamed to, one of \"http\", \"webhook\", \"splunk\" or \"kafka\". If empty, audit logs are only stored in the database.",
+			Flag:        "audit-export-sink",
+			Env:         "CODER_AUDIT_EXPORT_SINK",
+			Annotations: clibase.Annotations{}.Mark(annotationEnterpriseKey, "true"),
+			Value:       &c.AuditExport.Sink,
+			Group:       &deploymentGroupAuditExport,
+			YAML:        "sink",
+		},
+		{
+			Name:        "Audit Export URL",
+			Description: "The URL audit logs are sent to. For the splunk sink, this is the event endpoint of the HTTP Event Collector. For the kafka sink, this is the URL of a Kafka REST Proxy.",
+			Flag:        "audit-export-url",
+			Env:         "CODER_AUDIT_EXPORT_URL",
+			Annotations: clibase.Annotations{}.Mark(annotationEnterpriseKey, "true"),
+			Value:       &c.AuditExport.URL,
+			Group:       &deploymentGroupAuditExport,
+			YAML:        "url",
+		},
+		{
+			Name:        "Audit Export Token",
+			Description: "The token used to authenticate with the audit export sink. It is sent as a bearer token to the http and kafka sinks, and as the HEC token to the splunk sink. The webhook sink uses it to sign requests like workspace webhooks.",
+			Flag:        "audit-export-token",
+			Env:         "CODER_AUDIT_EXPORT_TOKEN",
+			Annotations: clibase.Annotations{}.Mark(annotationEnterpriseKey, "true").Mark(annotationSecretKey, "true"),
+			Value:       &c.AuditExport.Token,
+			Group:       &deploymentGroupAuditExport,
+		},
+		{
+			Name:        "Audit Export Kafka Topic",
+			Description: "The Kafka topic audit logs are produced to by the kafka sink.",
+			Flag:        "audit-export-kafka-topic",
+			Env:         "CODER_AUDIT_EXPORT_KAFKA_TOPIC",
+			Default:     "coder-audit-logs",
+			Annotations: clibase.Annotations{}.Mark(annotationEnterpriseKey, "true"),
+			Value:       &c.AuditExport.KafkaTopic,
+			Group:       &deploymentGroupAuditExport,
+			YAML:        "kafkaTopic",
+		},
+		{
+			Name:        "Audit Export Batch Size",
+			Description: "The maximum number of audit logs sent to the sink in a single request.",
+			Flag:        "audit-export-batch-size",
+			Env:         "CODER_AUDIT_EXPORT_BATCH_SIZE",
+			Default:     "100",
+			Annotations: clibase.Annotations{}.Mark(annotationEnterpriseKey, "true"),
+			Value:       &c.AuditExport.BatchSize,
+			Group:       &deploymentGroupAuditExport,
+			YAML:        "batchSize",
+		},
+		{
+			Name:        "Audit Log Retention Days",
+			Description: "The number of days audit logs are kept in the database. Older audit logs are archived, if an archive URL is set, and deleted every hour. Set to 0 to keep audit logs forever.",
+			Flag:        "audit-log-retention-days",
+			Env:         "CODER_AUDIT_LOG_RETENTION_DAYS",
+			Default:     "0",
+			Annotations: clibase.Annotations{}.Mark(annotationEnterpriseKey, "true"),
+			Value:       &c.AuditLogRetention.Days,
+			Group:       &deploymentGroupAuditLogRetention,
+			YAML:        "days",
+		},
+		{
+			Name:        "Audit Log Archive URL",
+			Description: "Where expired audit logs are archived as gzip compressed JSON Lines before they are deleted, either a directory like \"file:///var/lib/coder/audit\" or an S3 bucket like \"s3://bucket/prefix?region=us-east-1\". Set the endpoint query parameter to use an S3 compatible service. AWS credentials are read from the environment. If empty, expired audit logs are deleted without being archived.",
+			Flag:        "audit-log-archive-url",
+			Env:         "CODER_AUDIT_LOG_ARCHIVE_URL",
+			Annotations: clibase.Annotations{}.Mark(annotationEnterpriseKey, "true"),
+			Value:       &c.AuditLogRetention.ArchiveURL,
+			Group:       &deploymentGroupAuditLogRetention,
+			YAML:        "archiveURL",
+		},
+		{
+			Name:        "Workspace App OIDC Issuer URL",
+			Description: "Issuer URL of the OIDC provider that users log in with to use subdomain apps with OIDC auth. If empty, users must log in to Coder to use these apps.",
+			Flag:        "workspace-app-oidc-issuer-url",
+			Env:         "CODER_WORKSPACE_APP_OIDC_ISSUER_URL",
+			Value:       &c.WorkspaceAppOIDC.IssuerURL,
+			Group:       &deploymentGroupWorkspaceAppOIDC,
+			YAML:        "issuerURL",
+		},
+		{
+			Name:        "Workspace App OIDC Client ID",
+			Description: "Client ID of Coder on the workspace app OIDC provider. ID tokens must be issued for this audience.",
+			Flag:        "workspace-app-oidc-client-id",
+			Env:         "CODER_WORKSPACE_APP_OIDC_CLIENT_ID",
+			Value:       &c.WorkspaceAppOIDC.ClientID,
+			Group:       &deploymentGroupWorkspaceAppOIDC,
+			YAML:        "clientID",
+		},
+		{
+			Name:        "Workspace App OIDC Client Secret",
+			Description: "Client secret of Coder on the workspace app OIDC provider.",
+			Flag:        "workspace-app-oidc-client-secret",
+			Env:         "CODER_WORKSPACE_APP_OIDC_CLIENT_SECRET",
+			Annotations: clibase.Annotations{}.Mark(annotationSecretKey, "true"),
+			Value:       &c.WorkspaceAppOIDC.ClientSecret,
+			Group:       &deploymentGroupWorkspaceAppOIDC,
+		},
+		{
+			Name:        "Workspace App OIDC Email Domain",
+			Description: "Email domains that users logging in with the workspace app OIDC provider must match. If empty, every user of the provider may use subdomain apps with OIDC auth.",
+			Flag:        "workspace-app-oidc-email-domain",
+			Env:         "CODER_WORKSPACE_APP_OIDC_EMAIL_DOMAIN",
+			Value:       &c.WorkspaceAppOIDC.EmailDomain,
+			Group:       &deploymentGroupWorkspaceAppOIDC,
+			YAML:        "emailDomain",
+		},
 	}
 	return opts
 }
@@ -1732,6 +2204,26 @@ type LinkConfig struct {
 	Icon   string `json:"icon" yaml:"icon"`
 }
 
+// TokenExchangePolicy allows exchanging identity tokens of an external OIDC
+// issuer for short-lived API tokens of a user.
+type TokenExchangePolicy struct {
+	// Issuer is the issuer URL of the identity tokens, e.g.
+	// https://token.actions.githubusercontent.com for GitHub Actions.
+	Issuer string `json:"issuer" yaml:"issuer"`
+	// Audience must be in the aud claim of the identity tokens.
+	Audience string `json:"audience" yaml:"audience"`
+	// Claims maps claim names to the patterns their values must match, in the
+	// syntax of path.Match. At least one claim is required, otherwise every
+	// identity token of the issuer would be accepted.
+	Claims map[string]string `json:"claims" yaml:"claims"`
+	// Username is the user the API tokens are created for.
+	Username string `json:"username" yaml:"username"`
+	// Scope is the scope of the API tokens, all by default.
+	Scope APIKeyScope `json:"scope,omitempty" yaml:"scope,omitempty"`
+	// Lifetime is how long the API tokens are valid, an hour by default.
+	Lifetime time.Duration `json:"lifetime,omitempty" yaml:"lifetime,omitempty"`
+}
+
 // WithoutSecrets returns a copy of the config without secret values.
 func (c *DeploymentValues) WithoutSecrets() (*DeploymentValues, error) {
 	var ff DeploymentValues
@@ -1931,6 +2423,11 @@ const (
 	// Workspaces batch actions
 	ExperimentWorkspacesBatchActions Experiment = "workspaces_batch_actions"
 
+	// ExperimentWorkspaceScheduleOverrides allows template admins to override
+	// the TTL and restart requirement of individual workspaces, superseding
+	// the template defaults.
+	ExperimentWorkspaceScheduleOverrides Experiment = "workspace_schedule_overrides"
+
 	// Add new experiments here!
 	// ExperimentExample Experiment = "example"
 )
@@ -0,0 +1,71 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// DERPHealth is a summary of the health of the DERP regions used by the
+// deployment and the statistics of the DERP server embedded in coderd.
+type DERPHealth struct {
+	// Time is the time at which the DERP regions were probed.
+	Time    time.Time          `json:"time" format:"date-time"`
+	Healthy bool               `json:"healthy"`
+	Server  DERPServerStats    `json:"server"`
+	Regions []DERPRegionHealth `json:"regions"`
+}
+
+// DERPServerStats are statistics of the DERP server embedded in the coderd
+// replica that served the request, since the replica was started.
+type DERPServerStats struct {
+	CurrentConnections     int64 `json:"current_connections"`
+	CurrentHomeConnections int64 `json:"current_home_connections"`
+	Accepts                int64 `json:"accepts"`
+	BytesReceived          int64 `json:"bytes_received"`
+	BytesSent              int64 `json:"bytes_sent"`
+	PacketsReceived        int64 `json:"packets_received"`
+	PacketsSent            int64 `json:"packets_sent"`
+	PacketsDropped         int64 `json:"packets_dropped"`
+}
+
+type DERPRegionHealth struct {
+	RegionID   int    `json:"region_id"`
+	RegionCode string `json:"region_code"`
+	RegionName string `json:"region_name"`
+	Healthy    bool   `json:"healthy"`
+	// LatencyMillis is the lowest round trip latency of the nodes of the
+	// region. It is zero if no node could relay messages.
+	LatencyMillis int64            `json:"latency_ms"`
+	Nodes         []DERPNodeHealth `json:"nodes"`
+	Error         string           `json:"error,omitempty"`
+}
+
+type DERPNodeHealth struct {
+	Name                string `json:"name"`
+	HostName            string `json:"host_name"`
+	Healthy             bool   `json:"healthy"`
+	CanExchangeMessages bool   `json:"can_exchange_messages"`
+	UsesWebsocket       bool   `json:"uses_websocket"`
+	CanSTUN             bool   `json:"can_stun"`
+	LatencyMillis       int64  `json:"latency_ms"`
+	Error               string `json:"error,omitempty"`
+}
+
+// DERPHealth returns the health of the DERP regions of the deployment.
+func (c *Client) DERPHealth(ctx context.Context) (DERPHealth, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/derp/health", nil)
+	if err != nil {
+		return DERPHealth{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return DERPHealth{}, ReadBodyAsError(res)
+	}
+	var health DERPHealth
+	return health, json.NewDecoder(res.Body).Decode(&health)
+}
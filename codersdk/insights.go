@@ -86,6 +86,280 @@ func (c *Client) UserLatencyInsights(ctx context.Context, req UserLatencyInsight
 	return result, json.NewDecoder(resp.Body).Decode(&result)
 }
 
+// AppSessionInsightsResponse is the response from the app session insights
+// endpoint.
+type AppSessionInsightsResponse struct {
+	Report AppSessionInsightsReport `json:"report"`
+}
+
+// AppSessionInsightsReport is the report from the app session insights
+// endpoint.
+type AppSessionInsightsReport struct {
+	StartTime   time.Time           `json:"start_time" format:"date-time"`
+	EndTime     time.Time           `json:"end_time" format:"date-time"`
+	TemplateIDs []uuid.UUID         `json:"template_ids" format:"uuid"`
+	Apps        []AppSessionInsight `json:"apps"`
+}
+
+// AppSessionInsight shows how many sessions a user had with a workspace app
+// and how long the app was in use.
+type AppSessionInsight struct {
+	UserID     uuid.UUID `json:"user_id" format:"uuid"`
+	Username   string    `json:"username"`
+	AvatarURL  string    `json:"avatar_url" format:"uri"`
+	TemplateID uuid.UUID `json:"template_id" format:"uuid"`
+	// AccessMethod is one of "path", "subdomain" or "terminal".
+	AccessMethod string `json:"access_method" example:"subdomain"`
+	SlugOrPort   string `json:"slug_or_port" example:"code-server"`
+	Sessions     int64  `json:"sessions" example:"12"`
+	// ActiveSeconds is the time the app was in use, time between sessions
+	// isn't counted.
+	ActiveSeconds         int64     `json:"active_seconds" example:"5400"`
+	FirstSessionStartedAt time.Time `json:"first_session_started_at" format:"date-time"`
+	LastSessionEndedAt    time.Time `json:"last_session_ended_at" format:"date-time"`
+}
+
+type AppSessionInsightsRequest struct {
+	StartTime   time.Time   `json:"start_time" format:"date-time"`
+	EndTime     time.Time   `json:"end_time" format:"date-time"`
+	TemplateIDs []uuid.UUID `json:"template_ids" format:"uuid"`
+}
+
+func (c *Client) AppSessionInsights(ctx context.Context, req AppSessionInsightsRequest) (AppSessionInsightsResponse, error) {
+	var qp []string
+	qp = append(qp, fmt.Sprintf("start_time=%s", req.StartTime.Format(insightsTimeLayout)))
+	qp = append(qp, fmt.Sprintf("end_time=%s", req.EndTime.Format(insightsTimeLayout)))
+	if len(req.TemplateIDs) > 0 {
+		var templateIDs []string
+		for _, id := range req.TemplateIDs {
+			templateIDs = append(templateIDs, id.String())
+		}
+		qp = append(qp, fmt.Sprintf("template_ids=%s", strings.Join(templateIDs, ",")))
+	}
+
+	reqURL := fmt.Sprintf("/api/v2/insights/app-sessions?%s", strings.Join(qp, "&"))
+	resp, err := c.Request(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return AppSessionInsightsResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return AppSessionInsightsResponse{}, ReadBodyAsError(resp)
+	}
+	var result AppSessionInsightsResponse
+	return result, json.NewDecoder(resp.Body).Decode(&result)
+}
+
+// AppAccessLogInsightsResponse is the response from the app access log
+// insights endpoint.
+type AppAccessLogInsightsResponse struct {
+	Report AppAccessLogInsightsReport `json:"report"`
+}
+
+// AppAccessLogInsightsReport is the report from the app access log insights
+// endpoint.
+type AppAccessLogInsightsReport struct {
+	StartTime   time.Time             `json:"start_time" format:"date-time"`
+	EndTime     time.Time             `json:"end_time" format:"date-time"`
+	TemplateIDs []uuid.UUID           `json:"template_ids" format:"uuid"`
+	Entries     []AppAccessLogInsight `json:"entries"`
+}
+
+// AppAccessLogInsight shows how many requests a user made to a path prefix of
+// a workspace app that resulted in a status code, and how long they took.
+type AppAccessLogInsight struct {
+	// UserID is the zero UUID for requests of users that were not signed in
+	// to Coder, e.g. to public apps.
+	UserID     uuid.UUID `json:"user_id" format:"uuid"`
+	Username   string    `json:"username"`
+	AvatarURL  string    `json:"avatar_url" format:"uri"`
+	TemplateID uuid.UUID `json:"template_id" format:"uuid"`
+	// AccessMethod is one of "path" or "subdomain".
+	AccessMethod string `json:"access_method" example:"subdomain"`
+	SlugOrPort   string `json:"slug_or_port" example:"code-server"`
+	// PathPrefix is the first segment of the requested path.
+	PathPrefix    string    `json:"path_prefix" example:"/api"`
+	StatusCode    int       `json:"status_code" example:"200"`
+	Requests      int64     `json:"requests" example:"120"`
+	LatencyP50MS  float64   `json:"latency_p50_ms" example:"12"`
+	LatencyP95MS  float64   `json:"latency_p95_ms" example:"85"`
+	LastRequestAt time.Time `json:"last_request_at" format:"date-time"`
+}
+
+type AppAccessLogInsightsRequest struct {
+	StartTime   time.Time   `json:"start_time" format:"date-time"`
+	EndTime     time.Time   `json:"end_time" format:"date-time"`
+	TemplateIDs []uuid.UUID `json:"template_ids" format:"uuid"`
+}
+
+func (c *Client) AppAccessLogInsights(ctx context.Context, req AppAccessLogInsightsRequest) (AppAccessLogInsightsResponse, error) {
+	var qp []string
+	qp = append(qp, fmt.Sprintf("start_time=%s", req.StartTime.Format(insightsTimeLayout)))
+	qp = append(qp, fmt.Sprintf("end_time=%s", req.EndTime.Format(insightsTimeLayout)))
+	if len(req.TemplateIDs) > 0 {
+		var templateIDs []string
+		for _, id := range req.TemplateIDs {
+			templateIDs = append(templateIDs, id.String())
+		}
+		qp = append(qp, fmt.Sprintf("template_ids=%s", strings.Join(templateIDs, ",")))
+	}
+
+	reqURL := fmt.Sprintf("/api/v2/insights/app-access-logs?%s", strings.Join(qp, "&"))
+	resp, err := c.Request(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return AppAccessLogInsightsResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return AppAccessLogInsightsResponse{}, ReadBodyAsError(resp)
+	}
+	var result AppAccessLogInsightsResponse
+	return result, json.NewDecoder(resp.Body).Decode(&result)
+}
+
+// ScheduleInsightsResponse is the response from the schedule insights
+// endpoint.
+type ScheduleInsightsResponse struct {
+	Report ScheduleInsightsReport `json:"report"`
+}
+
+// ScheduleInsightsReport is the report from the schedule insights endpoint.
+type ScheduleInsightsReport struct {
+	StartTime   time.Time                 `json:"start_time" format:"date-time"`
+	EndTime     time.Time                 `json:"end_time" format:"date-time"`
+	TemplateIDs []uuid.UUID               `json:"template_ids" format:"uuid"`
+	Templates   []TemplateScheduleInsight `json:"templates"`
+}
+
+// TemplateScheduleInsight shows how long the workspaces of a template were
+// running and stopped, and how much compute time their schedule saved.
+type TemplateScheduleInsight struct {
+	TemplateID uuid.UUID `json:"template_id" format:"uuid"`
+	// AllocatedSeconds is the time the workspaces existed, which is how long
+	// they would have been running if they were never stopped.
+	AllocatedSeconds int64 `json:"allocated_seconds" example:"604800"`
+	RunningSeconds   int64 `json:"running_seconds" example:"201600"`
+	StoppedSeconds   int64 `json:"stopped_seconds" example:"403200"`
+	// ScheduleStoppedSeconds is the part of the stopped time after the
+	// workspaces were stopped by autostop or the restart requirement of the
+	// template, which is the compute time saved by the schedule.
+	ScheduleStoppedSeconds int64 `json:"schedule_stopped_seconds" example:"345600"`
+	Autostarts             int64 `json:"autostarts" example:"5"`
+	Autostops              int64 `json:"autostops" example:"5"`
+}
+
+type ScheduleInsightsRequest struct {
+	StartTime   time.Time   `json:"start_time" format:"date-time"`
+	EndTime     time.Time   `json:"end_time" format:"date-time"`
+	TemplateIDs []uuid.UUID `json:"template_ids" format:"uuid"`
+}
+
+func (c *Client) ScheduleInsights(ctx context.Context, req ScheduleInsightsRequest) (ScheduleInsightsResponse, error) {
+	var qp []string
+	qp = append(qp, fmt.Sprintf("start_time=%s", req.StartTime.Format(insightsTimeLayout)))
+	qp = append(qp, fmt.Sprintf("end_time=%s", req.EndTime.Format(insightsTimeLayout)))
+	if len(req.TemplateIDs) > 0 {
+		var templateIDs []string
+		for _, id := range req.TemplateIDs {
+			templateIDs = append(templateIDs, id.String())
+		}
+		qp = append(qp, fmt.Sprintf("template_ids=%s", strings.Join(templateIDs, ",")))
+	}
+
+	reqURL := fmt.Sprintf("/api/v2/insights/schedules?%s", strings.Join(qp, "&"))
+	resp, err := c.Request(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return ScheduleInsightsResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return ScheduleInsightsResponse{}, ReadBodyAsError(resp)
+	}
+	var result ScheduleInsightsResponse
+	return result, json.NewDecoder(resp.Body).Decode(&result)
+}
+
+// BuildInsightsResponse is the response from the build insights endpoint.
+type BuildInsightsResponse struct {
+	Report          BuildInsightsReport           `json:"report"`
+	IntervalReports []BuildInsightsIntervalReport `json:"interval_reports"`
+}
+
+// BuildInsightsReport is the report from the build insights endpoint.
+type BuildInsightsReport struct {
+	StartTime        time.Time                     `json:"start_time" format:"date-time"`
+	EndTime          time.Time                     `json:"end_time" format:"date-time"`
+	TemplateIDs      []uuid.UUID                   `json:"template_ids" format:"uuid"`
+	TemplateVersions []TemplateVersionBuildInsight `json:"template_versions"`
+}
+
+// BuildInsightsIntervalReport is the report from the build insights endpoint
+// for a specific interval.
+type BuildInsightsIntervalReport struct {
+	StartTime        time.Time                     `json:"start_time" format:"date-time"`
+	EndTime          time.Time                     `json:"end_time" format:"date-time"`
+	Interval         InsightsReportInterval        `json:"interval"`
+	TemplateVersions []TemplateVersionBuildInsight `json:"template_versions"`
+}
+
+// TemplateVersionBuildInsight shows how many start builds of a template
+// version failed, and how long the successful ones took to provision and for
+// their agents to connect. Durations are -1 if there is no data.
+type TemplateVersionBuildInsight struct {
+	TemplateID          uuid.UUID `json:"template_id" format:"uuid"`
+	TemplateVersionID   uuid.UUID `json:"template_version_id" format:"uuid"`
+	TemplateVersionName string    `json:"template_version_name" example:"vigorous_wozniak"`
+	Builds              int64     `json:"builds" example:"40"`
+	FailedBuilds        int64     `json:"failed_builds" example:"2"`
+	// FailureRate is the fraction of builds that failed, between 0 and 1.
+	FailureRate        float64 `json:"failure_rate" example:"0.05"`
+	BuildDurationP50MS float64 `json:"build_duration_p50_ms" example:"45000"`
+	BuildDurationP95MS float64 `json:"build_duration_p95_ms" example:"98000"`
+	// AgentConnectP50MS is the median time from the creation of the agents of
+	// the builds until they first connected.
+	AgentConnectP50MS float64 `json:"agent_connect_p50_ms" example:"12000"`
+	AgentConnectP95MS float64 `json:"agent_connect_p95_ms" example:"31000"`
+}
+
+type BuildInsightsRequest struct {
+	StartTime   time.Time              `json:"start_time" format:"date-time"`
+	EndTime     time.Time              `json:"end_time" format:"date-time"`
+	TemplateIDs []uuid.UUID            `json:"template_ids" format:"uuid"`
+	Interval    InsightsReportInterval `json:"interval"`
+}
+
+func (c *Client) BuildInsights(ctx context.Context, req BuildInsightsRequest) (BuildInsightsResponse, error) {
+	var qp []string
+	qp = append(qp, fmt.Sprintf("start_time=%s", req.StartTime.Format(insightsTimeLayout)))
+	qp = append(qp, fmt.Sprintf("end_time=%s", req.EndTime.Format(insightsTimeLayout)))
+	if len(req.TemplateIDs) > 0 {
+		var templateIDs []string
+		for _, id := range req.TemplateIDs {
+			templateIDs = append(templateIDs, id.String())
+		}
+		qp = append(qp, fmt.Sprintf("template_ids=%s", strings.Join(templateIDs, ",")))
+	}
+	if req.Interval != "" {
+		qp = append(qp, fmt.Sprintf("interval=%s", req.Interval))
+	}
+
+	reqURL := fmt.Sprintf("/api/v2/insights/builds?%s", strings.Join(qp, "&"))
+	resp, err := c.Request(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return BuildInsightsResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return BuildInsightsResponse{}, ReadBodyAsError(resp)
+	}
+	var result BuildInsightsResponse
+	return result, json.NewDecoder(resp.Body).Decode(&result)
+}
+
 // TemplateInsightsResponse is the response from the template insights endpoint.
 type TemplateInsightsResponse struct {
 	Report          TemplateInsightsReport           `json:"report"`
@@ -17,6 +17,9 @@ const (
 	ProvisionerStorageMethodFile ProvisionerStorageMethod = "file"
 )
 
+// ProvisionerType is the type of provisioner that runs the jobs of a template.
+// Besides the built-in types, it's the name of a provisioner plugin served by
+// external provisioner daemons.
 type ProvisionerType string
 
 const (
@@ -40,6 +43,119 @@ type OrganizationMember struct {
 	Roles          []Role    `db:"roles" json:"roles"`
 }
 
+// OrganizationScheduleSettings are the default scheduling options of an
+// organization. They are used by templates and users in the organization that
+// don't set their own values.
+type OrganizationScheduleSettings struct {
+	OrganizationID uuid.UUID `json:"organization_id" format:"uuid"`
+	CreatedAt      time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt      time.Time `json:"updated_at" format:"date-time"`
+	// DefaultTTLMillis is used for templates that have no default TTL set.
+	DefaultTTLMillis int64 `json:"default_ttl_ms"`
+	// RestartRequirement is used for templates that have no restart
+	// requirement days set. The timezone is unused, the template's timezone
+	// always applies.
+	RestartRequirement TemplateRestartRequirement `json:"restart_requirement"`
+	// DefaultQuietHoursSchedule is used for members of the organization that
+	// have no quiet hours schedule set, instead of the deployment default.
+	DefaultQuietHoursSchedule string `json:"default_quiet_hours_schedule"`
+}
+
+// UpdateOrganizationScheduleSettingsRequest is a request to set the default
+// scheduling options of an organization. Zero values unset the defaults.
+type UpdateOrganizationScheduleSettingsRequest struct {
+	DefaultTTLMillis          int64                      `json:"default_ttl_ms"`
+	RestartRequirement        TemplateRestartRequirement `json:"restart_requirement"`
+	DefaultQuietHoursSchedule string                     `json:"default_quiet_hours_schedule"`
+}
+
+// OrganizationProvisionerSettings are the provisioner options of an
+// organization.
+type OrganizationProvisionerSettings struct {
+	OrganizationID uuid.UUID `json:"organization_id" format:"uuid"`
+	CreatedAt      time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt      time.Time `json:"updated_at" format:"date-time"`
+	// MaxConcurrentJobs is the maximum number of provisioner jobs of the
+	// organization that provisioners run at the same time. Other jobs wait in
+	// the queue. If zero, the number of jobs is not limited.
+	MaxConcurrentJobs int32 `json:"max_concurrent_jobs"`
+}
+
+// UpdateOrganizationProvisionerSettingsRequest is a request to set the
+// provisioner options of an organization.
+type UpdateOrganizationProvisionerSettingsRequest struct {
+	MaxConcurrentJobs int32 `json:"max_concurrent_jobs"`
+}
+
+// OrganizationTemplateVariable is a value of a template variable that is used
+// by the builds of all templates in an organization. It's applied to the
+// variables that template versions leave unset or at their default.
+type OrganizationTemplateVariable struct {
+	Name string `json:"name"`
+	// Value is redacted if the variable is sensitive.
+	Value     string    `json:"value"`
+	Sensitive bool      `json:"sensitive"`
+	CreatedAt time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt time.Time `json:"updated_at" format:"date-time"`
+}
+
+// UpsertOrganizationTemplateVariableRequest is a request to set the value of
+// a template variable for an organization.
+type UpsertOrganizationTemplateVariableRequest struct {
+	Value     string `json:"value"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+// OrganizationJITProvisioningSettings configure the workspace that is created
+// for members of an organization when they log in for the first time.
+type OrganizationJITProvisioningSettings struct {
+	OrganizationID uuid.UUID `json:"organization_id" format:"uuid"`
+	CreatedAt      time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt      time.Time `json:"updated_at" format:"date-time"`
+	// TemplateID is the template the workspace is created from. If it's the
+	// nil UUID, no workspace is created.
+	TemplateID uuid.UUID `json:"template_id" format:"uuid"`
+	// WorkspaceName is the name of the workspace. If it's empty, the name of
+	// the template is used.
+	WorkspaceName string `json:"workspace_name"`
+}
+
+// UpdateOrganizationJITProvisioningSettingsRequest is a request to set the
+// workspace that is created for members of an organization when they log in
+// for the first time. The nil UUID turns JIT provisioning off.
+type UpdateOrganizationJITProvisioningSettingsRequest struct {
+	TemplateID    uuid.UUID `json:"template_id" format:"uuid"`
+	WorkspaceName string    `json:"workspace_name" validate:"omitempty,workspace_name"`
+}
+
+// OrganizationBudgetSettings configure when alerts are sent about the quota
+// credits consumed by the workspaces of an organization.
+type OrganizationBudgetSettings struct {
+	OrganizationID uuid.UUID `json:"organization_id" format:"uuid"`
+	CreatedAt      time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt      time.Time `json:"updated_at" format:"date-time"`
+	// DailyBudget is the number of credits the workspaces of the
+	// organization may consume per day in total. If zero, no alerts are sent.
+	DailyBudget int64 `json:"daily_budget"`
+	// WarningThresholdPercent is the percentage of the daily budget at which
+	// a warning is sent.
+	WarningThresholdPercent int32 `json:"warning_threshold_percent"`
+	// CriticalThresholdPercent is the percentage of the daily budget at which
+	// a critical alert is sent.
+	CriticalThresholdPercent int32 `json:"critical_threshold_percent"`
+	// AlertedThresholdPercent is the highest threshold that is currently
+	// crossed, or zero.
+	AlertedThresholdPercent int32 `json:"alerted_threshold_percent"`
+}
+
+// UpdateOrganizationBudgetSettingsRequest is a request to set the budget of
+// an organization. Updating the settings resets the alerted threshold.
+type UpdateOrganizationBudgetSettingsRequest struct {
+	DailyBudget              int64 `json:"daily_budget"`
+	WarningThresholdPercent  int32 `json:"warning_threshold_percent"`
+	CriticalThresholdPercent int32 `json:"critical_threshold_percent"`
+}
+
 // CreateTemplateVersionRequest enables callers to create a new Template Version.
 type CreateTemplateVersionRequest struct {
 	Name    string `json:"name,omitempty" validate:"omitempty,template_version_name"`
@@ -47,12 +163,20 @@ type CreateTemplateVersionRequest struct {
 	// TemplateID optionally associates a version with a template.
 	TemplateID      uuid.UUID                `json:"template_id,omitempty" format:"uuid"`
 	StorageMethod   ProvisionerStorageMethod `json:"storage_method" validate:"oneof=file,required" enums:"file"`
-	FileID          uuid.UUID                `json:"file_id,omitempty" validate:"required_without=ExampleID" format:"uuid"`
-	ExampleID       string                   `json:"example_id,omitempty" validate:"required_without=FileID"`
-	Provisioner     ProvisionerType          `json:"provisioner" validate:"oneof=terraform echo,required"`
+	FileID          uuid.UUID                `json:"file_id,omitempty" validate:"required_without_all=ExampleID RegistryReference" format:"uuid"`
+	ExampleID       string                   `json:"example_id,omitempty" validate:"required_without_all=FileID RegistryReference"`
+	Provisioner     ProvisionerType          `json:"provisioner" validate:"required,provisioner_type"`
 	ProvisionerTags map[string]string        `json:"tags"`
+	// RegistryReference pulls the source of the version from a template
+	// registry, e.g. oci://ghcr.io/coder/templates/docker:v1. RegistryAuth
+	// authenticates with the registry if set.
+	RegistryReference string                `json:"registry_reference,omitempty" validate:"required_without_all=FileID ExampleID"`
+	RegistryAuth      *TemplateRegistryAuth `json:"registry_auth,omitempty"`
 
 	UserVariableValues []VariableValue `json:"user_variable_values,omitempty"`
+	// Presets are named sets of parameter values that users can select when
+	// creating workspaces from the version.
+	Presets []TemplateVersionPreset `json:"presets,omitempty"`
 }
 
 type VariableValue struct {
@@ -84,6 +208,10 @@ type CreateTemplateRequest struct {
 	// DefaultTTLMillis allows optionally specifying the default TTL
 	// for all workspaces created from this template.
 	DefaultTTLMillis *int64 `json:"default_ttl_ms,omitempty"`
+	// ActivityBumpMillis allows optionally specifying how long workspaces
+	// created from this template stay running after activity was last
+	// detected. Workspaces that are idle for longer are stopped.
+	ActivityBumpMillis *int64 `json:"activity_bump_ms,omitempty"`
 	// TODO(@dean): remove max_ttl once restart_requirement is matured
 	MaxTTLMillis *int64 `json:"max_ttl_ms,omitempty"`
 	// RestartRequirement allows optionally specifying the restart requirement
@@ -125,10 +253,12 @@ type CreateTemplateRequest struct {
 
 // CreateWorkspaceRequest provides options for creating a new workspace.
 type CreateWorkspaceRequest struct {
-	TemplateID        uuid.UUID `json:"template_id" validate:"required" format:"uuid"`
-	Name              string    `json:"name" validate:"workspace_name,required"`
-	AutostartSchedule *string   `json:"autostart_schedule"`
-	TTLMillis         *int64    `json:"ttl_ms,omitempty"`
+	TemplateID uuid.UUID `json:"template_id" validate:"required" format:"uuid"`
+	Name       string    `json:"name" validate:"workspace_name,required"`
+	// AutostartSchedule accepts the same formats as
+	// UpdateWorkspaceAutostartRequest.Schedule.
+	AutostartSchedule *string `json:"autostart_schedule"`
+	TTLMillis         *int64  `json:"ttl_ms,omitempty"`
 	// ParameterValues allows for additional parameters to be provided
 	// during the initial provision.
 	RichParameterValues []WorkspaceBuildParameter `json:"rich_parameter_values,omitempty"`
@@ -149,6 +279,189 @@ func (c *Client) Organization(ctx context.Context, id uuid.UUID) (Organization,
 	return organization, json.NewDecoder(res.Body).Decode(&organization)
 }
 
+// OrganizationScheduleSettings returns the default scheduling options of an
+// organization.
+func (c *Client) OrganizationScheduleSettings(ctx context.Context, id uuid.UUID) (OrganizationScheduleSettings, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/settings/schedule", id.String()), nil)
+	if err != nil {
+		return OrganizationScheduleSettings{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationScheduleSettings{}, ReadBodyAsError(res)
+	}
+
+	var settings OrganizationScheduleSettings
+	return settings, json.NewDecoder(res.Body).Decode(&settings)
+}
+
+// UpdateOrganizationScheduleSettings sets the default scheduling options of an
+// organization.
+func (c *Client) UpdateOrganizationScheduleSettings(ctx context.Context, id uuid.UUID, req UpdateOrganizationScheduleSettingsRequest) (OrganizationScheduleSettings, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/organizations/%s/settings/schedule", id.String()), req)
+	if err != nil {
+		return OrganizationScheduleSettings{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationScheduleSettings{}, ReadBodyAsError(res)
+	}
+
+	var settings OrganizationScheduleSettings
+	return settings, json.NewDecoder(res.Body).Decode(&settings)
+}
+
+// OrganizationProvisionerSettings returns the provisioner options of an
+// organization.
+func (c *Client) OrganizationProvisionerSettings(ctx context.Context, id uuid.UUID) (OrganizationProvisionerSettings, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/settings/provisioners", id.String()), nil)
+	if err != nil {
+		return OrganizationProvisionerSettings{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationProvisionerSettings{}, ReadBodyAsError(res)
+	}
+
+	var settings OrganizationProvisionerSettings
+	return settings, json.NewDecoder(res.Body).Decode(&settings)
+}
+
+// UpdateOrganizationProvisionerSettings sets the provisioner options of an
+// organization.
+func (c *Client) UpdateOrganizationProvisionerSettings(ctx context.Context, id uuid.UUID, req UpdateOrganizationProvisionerSettingsRequest) (OrganizationProvisionerSettings, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/organizations/%s/settings/provisioners", id.String()), req)
+	if err != nil {
+		return OrganizationProvisionerSettings{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationProvisionerSettings{}, ReadBodyAsError(res)
+	}
+
+	var settings OrganizationProvisionerSettings
+	return settings, json.NewDecoder(res.Body).Decode(&settings)
+}
+
+// OrganizationTemplateVariables returns the template variable values of an
+// organization.
+func (c *Client) OrganizationTemplateVariables(ctx context.Context, id uuid.UUID) ([]OrganizationTemplateVariable, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/settings/variables", id.String()), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+
+	var variables []OrganizationTemplateVariable
+	return variables, json.NewDecoder(res.Body).Decode(&variables)
+}
+
+// UpsertOrganizationTemplateVariable sets the value of a template variable
+// for an organization.
+func (c *Client) UpsertOrganizationTemplateVariable(ctx context.Context, id uuid.UUID, name string, req UpsertOrganizationTemplateVariableRequest) (OrganizationTemplateVariable, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/organizations/%s/settings/variables/%s", id.String(), name), req)
+	if err != nil {
+		return OrganizationTemplateVariable{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationTemplateVariable{}, ReadBodyAsError(res)
+	}
+
+	var variable OrganizationTemplateVariable
+	return variable, json.NewDecoder(res.Body).Decode(&variable)
+}
+
+// DeleteOrganizationTemplateVariable removes the value of a template variable
+// from an organization.
+func (c *Client) DeleteOrganizationTemplateVariable(ctx context.Context, id uuid.UUID, name string) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/organizations/%s/settings/variables/%s", id.String(), name), nil)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// OrganizationJITProvisioningSettings returns the workspace that is created
+// for members of an organization when they log in for the first time.
+func (c *Client) OrganizationJITProvisioningSettings(ctx context.Context, id uuid.UUID) (OrganizationJITProvisioningSettings, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/settings/jit-provisioning", id.String()), nil)
+	if err != nil {
+		return OrganizationJITProvisioningSettings{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationJITProvisioningSettings{}, ReadBodyAsError(res)
+	}
+
+	var settings OrganizationJITProvisioningSettings
+	return settings, json.NewDecoder(res.Body).Decode(&settings)
+}
+
+// UpdateOrganizationJITProvisioningSettings sets the workspace that is
+// created for members of an organization when they log in for the first time.
+func (c *Client) UpdateOrganizationJITProvisioningSettings(ctx context.Context, id uuid.UUID, req UpdateOrganizationJITProvisioningSettingsRequest) (OrganizationJITProvisioningSettings, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/organizations/%s/settings/jit-provisioning", id.String()), req)
+	if err != nil {
+		return OrganizationJITProvisioningSettings{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationJITProvisioningSettings{}, ReadBodyAsError(res)
+	}
+
+	var settings OrganizationJITProvisioningSettings
+	return settings, json.NewDecoder(res.Body).Decode(&settings)
+}
+
+// OrganizationBudgetSettings returns the budget of an organization.
+func (c *Client) OrganizationBudgetSettings(ctx context.Context, id uuid.UUID) (OrganizationBudgetSettings, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/settings/budget", id.String()), nil)
+	if err != nil {
+		return OrganizationBudgetSettings{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationBudgetSettings{}, ReadBodyAsError(res)
+	}
+
+	var settings OrganizationBudgetSettings
+	return settings, json.NewDecoder(res.Body).Decode(&settings)
+}
+
+// UpdateOrganizationBudgetSettings sets the budget of an organization.
+func (c *Client) UpdateOrganizationBudgetSettings(ctx context.Context, id uuid.UUID, req UpdateOrganizationBudgetSettingsRequest) (OrganizationBudgetSettings, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/organizations/%s/settings/budget", id.String()), req)
+	if err != nil {
+		return OrganizationBudgetSettings{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return OrganizationBudgetSettings{}, ReadBodyAsError(res)
+	}
+
+	var settings OrganizationBudgetSettings
+	return settings, json.NewDecoder(res.Body).Decode(&settings)
+}
+
 // ProvisionerDaemons returns provisioner daemons available.
 func (c *Client) ProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error) {
 	res, err := c.Request(ctx, http.MethodGet,
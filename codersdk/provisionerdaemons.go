@@ -42,6 +42,23 @@ type ProvisionerDaemon struct {
 	Name         string            `json:"name"`
 	Provisioners []ProvisionerType `json:"provisioners"`
 	Tags         map[string]string `json:"tags"`
+	// Cache is the provider and module cache of the daemon, as of the last
+	// job the daemon completed.
+	Cache ProvisionerDaemonCache `json:"cache"`
+}
+
+// ProvisionerDaemonCache is the cache of the Terraform providers and modules
+// of a provisioner daemon, which lets builds of the same template skip
+// downloading them again.
+type ProvisionerDaemonCache struct {
+	Entries int32 `json:"entries"`
+	// SizeBytes is the size of the distinct files in the cache.
+	SizeBytes int64 `json:"size_bytes"`
+	// MaxSizeBytes is 0 if the daemon has no cache.
+	MaxSizeBytes int64 `json:"max_size_bytes"`
+	// Hits and Misses are counted since the daemon started.
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
 }
 
 // ProvisionerJobStatus represents the at-time state of a job.
@@ -98,6 +115,30 @@ type ProvisionerJobLog struct {
 	Level     LogLevel  `json:"log_level" enums:"trace,debug,info,warn,error"`
 	Stage     string    `json:"stage"`
 	Output    string    `json:"output"`
+	// Progress is set by provisioners that report how far they've come,
+	// such as Terraform.
+	Progress *ProvisionerJobLogProgress `json:"progress,omitempty"`
+}
+
+// ProvisionerJobLogStage is a step of provisioning that reports progress.
+type ProvisionerJobLogStage string
+
+const (
+	ProvisionerJobLogStageInit  ProvisionerJobLogStage = "init"
+	ProvisionerJobLogStagePlan  ProvisionerJobLogStage = "plan"
+	ProvisionerJobLogStageApply ProvisionerJobLogStage = "apply"
+)
+
+// ProvisionerJobLogProgress reports how many resources a provisioner has
+// planned or applied.
+type ProvisionerJobLogProgress struct {
+	Stage ProvisionerJobLogStage `json:"stage" enums:"init,plan,apply"`
+	// Resource is the address of the resource the log is about, if any.
+	Resource string `json:"resource,omitempty"`
+	// Completed is the number of resources that are done in the stage.
+	Completed int32 `json:"completed"`
+	// Total is the number of resources in the stage, or zero if unknown.
+	Total int32 `json:"total"`
 }
 
 // provisionerJobLogsAfter streams logs that occurred after a specific time.
@@ -138,7 +179,6 @@ func (c *Client) provisionerJobLogsAfter(ctx context.Context, path string, after
 		defer close(closed)
 		defer close(logs)
 		defer conn.Close(websocket.StatusGoingAway, "")
-		var log ProvisionerJobLog
 		for {
 			msgType, msg, err := conn.Read(ctx)
 			if err != nil {
@@ -147,6 +187,9 @@ func (c *Client) provisionerJobLogsAfter(ctx context.Context, path string, after
 			if msgType != websocket.MessageText {
 				return
 			}
+			// Fields that a log omits, such as its progress, must not be
+			// kept from the previous log.
+			var log ProvisionerJobLog
 			err = json.Unmarshal(msg, &log)
 			if err != nil {
 				return
@@ -175,7 +218,13 @@ type ServeProvisionerDaemonRequest struct {
 	// Tags is a map of key-value pairs that tag the jobs this provisioner daemon can handle
 	Tags map[string]string `json:"tags"`
 	// PreSharedKey is an authentication key to use on the API instead of the normal session token from the client.
+	//
+	// Deprecated: use ProvisionerKey instead.
 	PreSharedKey string `json:"pre_shared_key"`
+	// ProvisionerKey is a provisioner key to authenticate with instead of the
+	// normal session token from the client. The provisioner daemon gets the
+	// tags of the key, and only acquires jobs of the organization of the key.
+	ProvisionerKey string `json:"provisioner_key"`
 }
 
 // ServeProvisionerDaemon returns the gRPC service for a provisioner daemon
@@ -199,8 +248,13 @@ func (c *Client) ServeProvisionerDaemon(ctx context.Context, req ServeProvisione
 	}
 	headers := http.Header{}
 
-	if req.PreSharedKey == "" {
-		// use session token if we don't have a PSK.
+	switch {
+	case req.ProvisionerKey != "":
+		headers.Set(ProvisionerDaemonKey, req.ProvisionerKey)
+	case req.PreSharedKey != "":
+		headers.Set(ProvisionerDaemonPSK, req.PreSharedKey)
+	default:
+		// use session token if we don't have a key.
 		jar, err := cookiejar.New(nil)
 		if err != nil {
 			return nil, xerrors.Errorf("create cookie jar: %w", err)
@@ -210,8 +264,6 @@ func (c *Client) ServeProvisionerDaemon(ctx context.Context, req ServeProvisione
 			Value: c.SessionToken(),
 		}})
 		httpClient.Jar = jar
-	} else {
-		headers.Set(ProvisionerDaemonPSK, req.PreSharedKey)
 	}
 
 	conn, res, err := websocket.Dial(ctx, serverURL.String(), &websocket.DialOptions{
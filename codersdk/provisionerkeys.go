@@ -0,0 +1,81 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ProvisionerKey is a key that external provisioner daemons authenticate with
+// instead of a pre-shared key or a user session. Provisioner daemons that
+// authenticate with a key get the tags of the key, and only acquire jobs of
+// the organization of the key.
+type ProvisionerKey struct {
+	ID             uuid.UUID         `json:"id" format:"uuid"`
+	CreatedAt      time.Time         `json:"created_at" format:"date-time"`
+	OrganizationID uuid.UUID         `json:"organization_id" format:"uuid"`
+	Name           string            `json:"name"`
+	Tags           map[string]string `json:"tags"`
+	// LastUsedAt is the last time a provisioner daemon connected with the
+	// key, if any.
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" format:"date-time"`
+}
+
+type CreateProvisionerKeyRequest struct {
+	Name string            `json:"name" validate:"required,username"`
+	Tags map[string]string `json:"tags"`
+}
+
+// CreateProvisionerKeyResponse contains the key that provisioner daemons
+// authenticate with. The key is only returned when it's created.
+type CreateProvisionerKeyResponse struct {
+	ProvisionerKey ProvisionerKey `json:"provisioner_key"`
+	Key            string         `json:"key"`
+}
+
+// CreateProvisionerKey creates a provisioner key in the organization.
+func (c *Client) CreateProvisionerKey(ctx context.Context, organizationID uuid.UUID, req CreateProvisionerKeyRequest) (CreateProvisionerKeyResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/organizations/%s/provisionerkeys", organizationID), req)
+	if err != nil {
+		return CreateProvisionerKeyResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return CreateProvisionerKeyResponse{}, ReadBodyAsError(res)
+	}
+	var resp CreateProvisionerKeyResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// ProvisionerKeys lists the provisioner keys of the organization.
+func (c *Client) ProvisionerKeys(ctx context.Context, organizationID uuid.UUID) ([]ProvisionerKey, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/organizations/%s/provisionerkeys", organizationID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var keys []ProvisionerKey
+	return keys, json.NewDecoder(res.Body).Decode(&keys)
+}
+
+// DeleteProvisionerKey revokes a provisioner key. Provisioner daemons can't
+// connect with the key anymore, but daemons that are connected stay
+// connected.
+func (c *Client) DeleteProvisionerKey(ctx context.Context, organizationID uuid.UUID, name string) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/organizations/%s/provisionerkeys/%s", organizationID, name), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
@@ -15,6 +15,7 @@ const (
 	ResourceOrganization                RBACResource = "organization"
 	ResourceRoleAssignment              RBACResource = "assign_role"
 	ResourceOrgRoleAssignment           RBACResource = "assign_org_role"
+	ResourceCustomRole                  RBACResource = "custom_role"
 	ResourceAPIKey                      RBACResource = "api_key"
 	ResourceUser                        RBACResource = "user"
 	ResourceUserData                    RBACResource = "user_data"
@@ -48,6 +49,7 @@ var (
 		ResourceOrganization,
 		ResourceRoleAssignment,
 		ResourceOrgRoleAssignment,
+		ResourceCustomRole,
 		ResourceAPIKey,
 		ResourceUser,
 		ResourceUserData,
@@ -0,0 +1,164 @@
+package codersdk
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	"tailscale.com/tailcfg"
+)
+
+// PrimaryRegionName is the name of the region of the primary coderd in the
+// regions response.
+const PrimaryRegionName = "primary"
+
+const (
+	// regionLatencySamples is how often the latency to each region is
+	// measured. The first request also includes the DNS lookup and the TLS
+	// handshake, so the lowest sample is used.
+	regionLatencySamples = 3
+	// regionLatencyTimeout is how long measuring the latency to a region may
+	// take before it's reported as unreachable.
+	regionLatencyTimeout = 5 * time.Second
+	// avoidedDERPRegionScore scales the latency of DERP regions that aren't
+	// preferred, so they're only used as the home region if no preferred
+	// region is reachable.
+	avoidedDERPRegionScore = 1000
+)
+
+// RegionLatency is the latency from the client to a region.
+type RegionLatency struct {
+	Region Region
+	// Latency is the round trip time of a request to the region. It's zero
+	// if the region is unhealthy or unreachable.
+	Latency time.Duration
+	// Error explains why the latency couldn't be measured.
+	Error error
+}
+
+// WorkspaceProxyDERPRegionCode returns the code of the DERP region of a
+// workspace proxy in the DERP map.
+func WorkspaceProxyDERPRegionCode(proxyName string) string {
+	return "coder_" + strings.ToLower(proxyName)
+}
+
+// RegionLatencies measures the latency to the regions concurrently by
+// requesting their latency check endpoint. Unhealthy regions aren't
+// requested. The latencies are sorted from the fastest to the slowest region,
+// followed by the regions that couldn't be measured.
+func (c *Client) RegionLatencies(ctx context.Context, regions []Region) []RegionLatency {
+	latencies := make([]RegionLatency, len(regions))
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		i, region := i, region
+		latencies[i].Region = region
+		if !region.Healthy {
+			latencies[i].Error = xerrors.New("region is unhealthy")
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			latencies[i].Latency, latencies[i].Error = c.regionLatency(ctx, region)
+		}()
+	}
+	wg.Wait()
+
+	sort.SliceStable(latencies, func(i, j int) bool {
+		a, b := latencies[i], latencies[j]
+		if (a.Error == nil) != (b.Error == nil) {
+			return a.Error == nil
+		}
+		return a.Latency < b.Latency
+	})
+	return latencies
+}
+
+func (c *Client) regionLatency(ctx context.Context, region Region) (time.Duration, error) {
+	if region.PathAppURL == "" {
+		return 0, xerrors.New("region has no url")
+	}
+	ctx, cancel := context.WithTimeout(ctx, regionLatencyTimeout)
+	defer cancel()
+
+	var lowest time.Duration
+	for i := 0; i < regionLatencySamples; i++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(region.PathAppURL, "/")+"/latency-check", nil)
+		if err != nil {
+			return 0, xerrors.Errorf("create request: %w", err)
+		}
+		start := time.Now()
+		res, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return 0, xerrors.Errorf("request latency check: %w", err)
+		}
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		latency := time.Since(start)
+		if res.StatusCode != http.StatusOK {
+			return 0, xerrors.Errorf("latency check returned status %d", res.StatusCode)
+		}
+		if lowest == 0 || latency < lowest {
+			lowest = latency
+		}
+	}
+	return lowest, nil
+}
+
+// FastestRegion returns the latency to the reachable region with the lowest
+// latency. It returns false if no region is reachable.
+func FastestRegion(latencies []RegionLatency) (RegionLatency, bool) {
+	var (
+		fastest RegionLatency
+		found   bool
+	)
+	for _, latency := range latencies {
+		if latency.Error != nil {
+			continue
+		}
+		if !found || latency.Latency < fastest.Latency {
+			fastest, found = latency, true
+		}
+	}
+	return fastest, found
+}
+
+// PreferDERPRegions returns a copy of derpMap in which the DERP regions that
+// prefer doesn't match are penalized when the connection picks its home
+// region, which relays traffic that can't be sent directly. Penalized regions
+// are still used to reach peers whose home region they are, and as a
+// fallback if no preferred region is reachable. The map is returned as is if
+// prefer doesn't match any region.
+func PreferDERPRegions(derpMap *tailcfg.DERPMap, prefer func(region *tailcfg.DERPRegion) bool) *tailcfg.DERPMap {
+	if derpMap == nil || prefer == nil {
+		return derpMap
+	}
+	scores := make(map[int]float64, len(derpMap.Regions))
+	if derpMap.HomeParams != nil {
+		for id, score := range derpMap.HomeParams.RegionScore {
+			scores[id] = score
+		}
+	}
+	preferred := false
+	for id, region := range derpMap.Regions {
+		if region == nil {
+			continue
+		}
+		if prefer(region) {
+			preferred = true
+			continue
+		}
+		scores[id] = avoidedDERPRegionScore
+	}
+	if !preferred {
+		return derpMap
+	}
+	copied := *derpMap
+	copied.HomeParams = &tailcfg.DERPHomeParams{RegionScore: scores}
+	return &copied
+}
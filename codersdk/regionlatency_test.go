@@ -0,0 +1,91 @@
+package codersdk_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
+
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestRegionLatencies(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/latency-check" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	client := codersdk.New(srvURL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+	latencies := client.RegionLatencies(ctx, []codersdk.Region{
+		{Name: "unhealthy", Healthy: false, PathAppURL: srv.URL},
+		{Name: "missing", Healthy: true, PathAppURL: srv.URL + "/missing"},
+		{Name: codersdk.PrimaryRegionName, Healthy: true, PathAppURL: srv.URL},
+	})
+	require.Len(t, latencies, 3)
+	// Reachable regions are sorted first.
+	require.Equal(t, codersdk.PrimaryRegionName, latencies[0].Region.Name)
+	require.NoError(t, latencies[0].Error)
+	require.Positive(t, latencies[0].Latency)
+	for _, latency := range latencies[1:] {
+		require.Error(t, latency.Error, latency.Region.Name)
+		require.Zero(t, latency.Latency, latency.Region.Name)
+	}
+
+	fastest, ok := codersdk.FastestRegion(latencies)
+	require.True(t, ok)
+	require.Equal(t, codersdk.PrimaryRegionName, fastest.Region.Name)
+
+	_, ok = codersdk.FastestRegion(latencies[1:])
+	require.False(t, ok)
+}
+
+func TestPreferDERPRegions(t *testing.T) {
+	t.Parallel()
+
+	derpMap := &tailcfg.DERPMap{
+		Regions: map[int]*tailcfg.DERPRegion{
+			1:    {RegionID: 1, RegionCode: "coder"},
+			1001: {RegionID: 1001, RegionCode: codersdk.WorkspaceProxyDERPRegionCode("EU")},
+		},
+	}
+	prefer := func(code string) func(region *tailcfg.DERPRegion) bool {
+		return func(region *tailcfg.DERPRegion) bool {
+			return region.RegionCode == code
+		}
+	}
+
+	t.Run("Preferred", func(t *testing.T) {
+		t.Parallel()
+		preferred := codersdk.PreferDERPRegions(derpMap, prefer("coder_eu"))
+		require.NotNil(t, preferred.HomeParams)
+		require.Equal(t, map[int]float64{1: 1000}, preferred.HomeParams.RegionScore)
+		// The map that's passed in isn't modified.
+		require.Nil(t, derpMap.HomeParams)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		t.Parallel()
+		preferred := codersdk.PreferDERPRegions(derpMap, prefer("coder_us"))
+		require.Same(t, derpMap, preferred)
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		t.Parallel()
+		require.Same(t, derpMap, codersdk.PreferDERPRegions(derpMap, nil))
+	})
+}
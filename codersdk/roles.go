@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/xerrors"
 )
 
 type Role struct {
@@ -46,3 +48,94 @@ func (c *Client) ListOrganizationRoles(ctx context.Context, org uuid.UUID) ([]As
 	var roles []AssignableRoles
 	return roles, json.NewDecoder(res.Body).Decode(&roles)
 }
+
+// CustomRole is a site wide role defined by an admin in addition to the
+// built-in roles. Custom roles are assigned to users like site roles.
+type CustomRole struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	// SitePermissions apply to all resources of the site.
+	SitePermissions []Permission `json:"site_permissions"`
+	// UserPermissions apply to resources owned by the user with the role.
+	UserPermissions []Permission `json:"user_permissions"`
+	CreatedAt       time.Time    `json:"created_at" format:"date-time"`
+	UpdatedAt       time.Time    `json:"updated_at" format:"date-time"`
+}
+
+// Permission grants an action on a resource type, or denies it if Negate is
+// set.
+type Permission struct {
+	// Negate denies the action instead of granting it.
+	Negate       bool         `json:"negate"`
+	ResourceType RBACResource `json:"resource_type"`
+	Action       string       `json:"action" enums:"create,read,update,delete"`
+}
+
+type CreateCustomRoleRequest struct {
+	Name            string       `json:"name" validate:"required"`
+	DisplayName     string       `json:"display_name" validate:"required"`
+	SitePermissions []Permission `json:"site_permissions"`
+	UserPermissions []Permission `json:"user_permissions"`
+}
+
+type UpdateCustomRoleRequest struct {
+	DisplayName     string       `json:"display_name" validate:"required"`
+	SitePermissions []Permission `json:"site_permissions"`
+	UserPermissions []Permission `json:"user_permissions"`
+}
+
+// CustomRoles lists all custom roles.
+func (c *Client) CustomRoles(ctx context.Context) ([]CustomRole, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/roles", nil)
+	if err != nil {
+		return nil, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var roles []CustomRole
+	return roles, json.NewDecoder(res.Body).Decode(&roles)
+}
+
+// CreateCustomRole creates a custom role.
+func (c *Client) CreateCustomRole(ctx context.Context, req CreateCustomRoleRequest) (CustomRole, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/roles", req)
+	if err != nil {
+		return CustomRole{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return CustomRole{}, ReadBodyAsError(res)
+	}
+	var role CustomRole
+	return role, json.NewDecoder(res.Body).Decode(&role)
+}
+
+// UpdateCustomRole replaces the display name and permissions of a custom role.
+func (c *Client) UpdateCustomRole(ctx context.Context, name string, req UpdateCustomRoleRequest) (CustomRole, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/roles/%s", name), req)
+	if err != nil {
+		return CustomRole{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return CustomRole{}, ReadBodyAsError(res)
+	}
+	var role CustomRole
+	return role, json.NewDecoder(res.Body).Decode(&role)
+}
+
+// DeleteCustomRole deletes a custom role. The role is removed from all users
+// that have it.
+func (c *Client) DeleteCustomRole(ctx context.Context, name string) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/roles/%s", name), nil)
+	if err != nil {
+		return xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
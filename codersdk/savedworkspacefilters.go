@@ -0,0 +1,101 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SavedWorkspaceFilter is a workspace search query a user saved under a name,
+// so it can be reused with WorkspaceFilter.FilterQuery.
+type SavedWorkspaceFilter struct {
+	ID        uuid.UUID `json:"id" format:"uuid"`
+	UserID    uuid.UUID `json:"user_id" format:"uuid"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	CreatedAt time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt time.Time `json:"updated_at" format:"date-time"`
+}
+
+type CreateSavedWorkspaceFilterRequest struct {
+	Name string `json:"name" validate:"required,username"`
+	// Query is a workspace search query, e.g. "outdated:true status:running".
+	Query string `json:"query" validate:"required"`
+}
+
+type UpdateSavedWorkspaceFilterRequest struct {
+	Query string `json:"query" validate:"required"`
+}
+
+// SavedWorkspaceFilters returns the saved workspace filters of a user.
+func (c *Client) SavedWorkspaceFilters(ctx context.Context, user string) ([]SavedWorkspaceFilter, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/workspace-filters", user), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var filters []SavedWorkspaceFilter
+	return filters, json.NewDecoder(res.Body).Decode(&filters)
+}
+
+// SavedWorkspaceFilter returns a saved workspace filter of a user by name.
+func (c *Client) SavedWorkspaceFilter(ctx context.Context, user string, name string) (SavedWorkspaceFilter, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/workspace-filters/%s", user, name), nil)
+	if err != nil {
+		return SavedWorkspaceFilter{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return SavedWorkspaceFilter{}, ReadBodyAsError(res)
+	}
+	var filter SavedWorkspaceFilter
+	return filter, json.NewDecoder(res.Body).Decode(&filter)
+}
+
+// CreateSavedWorkspaceFilter saves a workspace search query for a user.
+func (c *Client) CreateSavedWorkspaceFilter(ctx context.Context, user string, req CreateSavedWorkspaceFilterRequest) (SavedWorkspaceFilter, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/workspace-filters", user), req)
+	if err != nil {
+		return SavedWorkspaceFilter{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return SavedWorkspaceFilter{}, ReadBodyAsError(res)
+	}
+	var filter SavedWorkspaceFilter
+	return filter, json.NewDecoder(res.Body).Decode(&filter)
+}
+
+// UpdateSavedWorkspaceFilter replaces the query of a saved workspace filter.
+func (c *Client) UpdateSavedWorkspaceFilter(ctx context.Context, user string, name string, req UpdateSavedWorkspaceFilterRequest) (SavedWorkspaceFilter, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/users/%s/workspace-filters/%s", user, name), req)
+	if err != nil {
+		return SavedWorkspaceFilter{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return SavedWorkspaceFilter{}, ReadBodyAsError(res)
+	}
+	var filter SavedWorkspaceFilter
+	return filter, json.NewDecoder(res.Body).Decode(&filter)
+}
+
+// DeleteSavedWorkspaceFilter deletes a saved workspace filter of a user.
+func (c *Client) DeleteSavedWorkspaceFilter(ctx context.Context, user string, name string) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/users/%s/workspace-filters/%s", user, name), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// ScheduleHolidayDateLayout is the layout of the date of a schedule holiday.
+const ScheduleHolidayDateLayout = "2006-01-02"
+
+// ScheduleHoliday is a date on which template restart requirements are
+// skipped. Workspaces that would have been restarted on a holiday are
+// restarted on the next day that matches the restart requirement instead.
+type ScheduleHoliday struct {
+	ID        uuid.UUID `json:"id" format:"uuid"`
+	CreatedAt time.Time `json:"created_at" format:"date-time"`
+	UpdatedAt time.Time `json:"updated_at" format:"date-time"`
+	// Date is the calendar date of the holiday in YYYY-MM-DD format. It is
+	// evaluated in the timezone of the restart requirement.
+	Date string `json:"date" format:"date"`
+	Name string `json:"name"`
+}
+
+type CreateScheduleHolidayRequest struct {
+	// Date is the calendar date of the holiday in YYYY-MM-DD format.
+	Date string `json:"date" validate:"required" format:"date"`
+	Name string `json:"name" validate:"required"`
+}
+
+type UpdateScheduleHolidayRequest struct {
+	// Date is the calendar date of the holiday in YYYY-MM-DD format.
+	Date string `json:"date" validate:"required" format:"date"`
+	Name string `json:"name" validate:"required"`
+}
+
+// ScheduleHolidays returns all schedule holidays in the deployment, ordered by
+// date. This endpoint only exists in enterprise editions.
+func (c *Client) ScheduleHolidays(ctx context.Context) ([]ScheduleHoliday, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/deployment/schedule/holidays", nil)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var holidays []ScheduleHoliday
+	return holidays, json.NewDecoder(res.Body).Decode(&holidays)
+}
+
+// ScheduleHoliday returns a single schedule holiday. This endpoint only exists
+// in enterprise editions.
+func (c *Client) ScheduleHoliday(ctx context.Context, id uuid.UUID) (ScheduleHoliday, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/deployment/schedule/holidays/%s", id), nil)
+	if err != nil {
+		return ScheduleHoliday{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return ScheduleHoliday{}, ReadBodyAsError(res)
+	}
+	var holiday ScheduleHoliday
+	return holiday, json.NewDecoder(res.Body).Decode(&holiday)
+}
+
+// CreateScheduleHoliday adds a holiday to the deployment. This endpoint only
+// exists in enterprise editions.
+func (c *Client) CreateScheduleHoliday(ctx context.Context, req CreateScheduleHolidayRequest) (ScheduleHoliday, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/deployment/schedule/holidays", req)
+	if err != nil {
+		return ScheduleHoliday{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return ScheduleHoliday{}, ReadBodyAsError(res)
+	}
+	var holiday ScheduleHoliday
+	return holiday, json.NewDecoder(res.Body).Decode(&holiday)
+}
+
+// UpdateScheduleHoliday updates the date and name of a holiday. This endpoint
+// only exists in enterprise editions.
+func (c *Client) UpdateScheduleHoliday(ctx context.Context, id uuid.UUID, req UpdateScheduleHolidayRequest) (ScheduleHoliday, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/deployment/schedule/holidays/%s", id), req)
+	if err != nil {
+		return ScheduleHoliday{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return ScheduleHoliday{}, ReadBodyAsError(res)
+	}
+	var holiday ScheduleHoliday
+	return holiday, json.NewDecoder(res.Body).Decode(&holiday)
+}
+
+// DeleteScheduleHoliday removes a holiday from the deployment. This endpoint
+// only exists in enterprise editions.
+func (c *Client) DeleteScheduleHoliday(ctx context.Context, id uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/deployment/schedule/holidays/%s", id), nil)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// TemplateParameterValidation are the rules that the rich parameter values of
+// workspace builds of a template are validated with before the builds are
+// queued, in addition to the validation of each parameter in the template.
+type TemplateParameterValidation struct {
+	TemplateID uuid.UUID `json:"template_id" format:"uuid"`
+	// WebhookURL receives a POST request with a ParameterValidationRequest
+	// for every build that starts a workspace. The build fails if the webhook
+	// responds with validation errors, a status other than 200 OK, or doesn't
+	// respond in time. If empty, no webhook is called.
+	WebhookURL  string                        `json:"webhook_url"`
+	Constraints []TemplateParameterConstraint `json:"constraints"`
+}
+
+// TemplateParameterConstraint requires the value of a parameter to match a
+// regular expression when the value of another parameter matches one, e.g.
+// to only allow GPU instance types in some regions.
+type TemplateParameterConstraint struct {
+	Parameter string `json:"parameter"`
+	Regex     string `json:"regex"`
+	// WhenParameter and WhenRegex limit the constraint to builds where the
+	// value of WhenParameter matches WhenRegex. If WhenParameter is empty,
+	// the constraint applies to all builds.
+	WhenParameter string `json:"when_parameter,omitempty"`
+	WhenRegex     string `json:"when_regex,omitempty"`
+	// Error is the message shown if the constraint isn't satisfied.
+	Error string `json:"error,omitempty"`
+}
+
+type UpdateTemplateParameterValidationRequest struct {
+	WebhookURL  string                        `json:"webhook_url"`
+	Constraints []TemplateParameterConstraint `json:"constraints"`
+}
+
+// ParameterValidationRequest is sent to the parameter validation webhook of
+// a template. Parameters contains the resolved value of every parameter of
+// the template version.
+type ParameterValidationRequest struct {
+	TemplateID        uuid.UUID                 `json:"template_id" format:"uuid"`
+	TemplateVersionID uuid.UUID                 `json:"template_version_id" format:"uuid"`
+	WorkspaceID       uuid.UUID                 `json:"workspace_id" format:"uuid"`
+	WorkspaceName     string                    `json:"workspace_name"`
+	OwnerID           uuid.UUID                 `json:"owner_id" format:"uuid"`
+	InitiatorID       uuid.UUID                 `json:"initiator_id" format:"uuid"`
+	Transition        WorkspaceTransition       `json:"transition" enums:"start,stop,delete"`
+	Parameters        []WorkspaceBuildParameter `json:"parameters"`
+}
+
+// ParameterValidationResponse is the response of a parameter validation
+// webhook. The Field of each validation error is the name of a parameter.
+// The build is valid if there are no validation errors.
+type ParameterValidationResponse struct {
+	Validations []ValidationError `json:"validations"`
+}
+
+// TemplateParameterValidation returns the parameter validation rules of the
+// template.
+func (c *Client) TemplateParameterValidation(ctx context.Context, templateID uuid.UUID) (TemplateParameterValidation, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/parameter-validation", templateID), nil)
+	if err != nil {
+		return TemplateParameterValidation{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateParameterValidation{}, ReadBodyAsError(res)
+	}
+	var validation TemplateParameterValidation
+	return validation, json.NewDecoder(res.Body).Decode(&validation)
+}
+
+// UpdateTemplateParameterValidation sets the parameter validation rules of
+// the template. They apply to builds that are created afterwards.
+func (c *Client) UpdateTemplateParameterValidation(ctx context.Context, templateID uuid.UUID, req UpdateTemplateParameterValidationRequest) (TemplateParameterValidation, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/parameter-validation", templateID), req)
+	if err != nil {
+		return TemplateParameterValidation{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateParameterValidation{}, ReadBodyAsError(res)
+	}
+	var validation TemplateParameterValidation
+	return validation, json.NewDecoder(res.Body).Decode(&validation)
+}
@@ -0,0 +1,58 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// TemplatePrebuilds is the pool of prebuilt workspaces of a template.
+// Prebuilt workspaces are provisioned stopped on the active version of the
+// template and assigned to users when they create a workspace from it.
+type TemplatePrebuilds struct {
+	TemplateID uuid.UUID `json:"template_id" format:"uuid"`
+	// Size is the number of prebuilt workspaces the template keeps. 0
+	// disables prebuilds.
+	Size int32 `json:"size"`
+	// Ready is the number of prebuilt workspaces that can be assigned to
+	// users.
+	Ready int32 `json:"ready"`
+	// Building is the number of prebuilt workspaces that are being built.
+	Building int32 `json:"building"`
+}
+
+type UpdateTemplatePrebuildsRequest struct {
+	Size int32 `json:"size"`
+}
+
+// TemplatePrebuilds returns the pool of prebuilt workspaces of the template.
+func (c *Client) TemplatePrebuilds(ctx context.Context, templateID uuid.UUID) (TemplatePrebuilds, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/prebuilds", templateID), nil)
+	if err != nil {
+		return TemplatePrebuilds{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplatePrebuilds{}, ReadBodyAsError(res)
+	}
+	var prebuilds TemplatePrebuilds
+	return prebuilds, json.NewDecoder(res.Body).Decode(&prebuilds)
+}
+
+// UpdateTemplatePrebuilds sets the number of prebuilt workspaces the template
+// keeps. Prebuilt workspaces are created or deleted in the background.
+func (c *Client) UpdateTemplatePrebuilds(ctx context.Context, templateID uuid.UUID, req UpdateTemplatePrebuildsRequest) (TemplatePrebuilds, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/prebuilds", templateID), req)
+	if err != nil {
+		return TemplatePrebuilds{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplatePrebuilds{}, ReadBodyAsError(res)
+	}
+	var prebuilds TemplatePrebuilds
+	return prebuilds, json.NewDecoder(res.Body).Decode(&prebuilds)
+}
@@ -21,7 +21,7 @@ type Template struct {
 	OrganizationID  uuid.UUID       `json:"organization_id" format:"uuid"`
 	Name            string          `json:"name"`
 	DisplayName     string          `json:"display_name"`
-	Provisioner     ProvisionerType `json:"provisioner" enums:"terraform"`
+	Provisioner     ProvisionerType `json:"provisioner"`
 	ActiveVersionID uuid.UUID       `json:"active_version_id" format:"uuid"`
 	// ActiveUserCount is set to -1 when loading.
 	ActiveUserCount  int                    `json:"active_user_count"`
@@ -29,6 +29,10 @@ type Template struct {
 	Description      string                 `json:"description"`
 	Icon             string                 `json:"icon"`
 	DefaultTTLMillis int64                  `json:"default_ttl_ms"`
+	// ActivityBumpMillis is how long workspaces stay running after activity
+	// was last detected. If zero, activity bumps the deadline by the
+	// workspace's TTL.
+	ActivityBumpMillis int64 `json:"activity_bump_ms"`
 	// TODO(@dean): remove max_ttl once restart_requirement is matured
 	MaxTTLMillis int64 `json:"max_ttl_ms"`
 	// RestartRequirement is an enterprise feature. Its value is only used if
@@ -43,6 +47,37 @@ type Template struct {
 	AllowUserAutostart           bool `json:"allow_user_autostart"`
 	AllowUserAutostop            bool `json:"allow_user_autostop"`
 	AllowUserCancelWorkspaceJobs bool `json:"allow_user_cancel_workspace_jobs"`
+	// MaxConcurrentBuilds is the maximum number of workspace builds of the
+	// template that provisioners run at the same time. Other builds wait in
+	// the queue. If zero, the number of builds is not limited.
+	MaxConcurrentBuilds int32 `json:"max_concurrent_builds"`
+	// MaxDeadlineExtensionsPerDay is the maximum number of times that users
+	// can extend the deadline of a workspace within 24 hours. If zero, the
+	// number of extensions is not limited.
+	MaxDeadlineExtensionsPerDay int32 `json:"max_deadline_extensions_per_day"`
+	// MaxDeadlineExtensionMillis is the maximum duration that users can extend
+	// the deadline of a workspace by at once. If zero, extensions are only
+	// limited by the max deadline of the workspace build.
+	MaxDeadlineExtensionMillis int64 `json:"max_deadline_extension_ms"`
+	// RequiredPromotionApprovals is the number of approvals a template version
+	// promotion needs before the version becomes active. If zero, versions
+	// can be made active directly.
+	RequiredPromotionApprovals int32 `json:"required_promotion_approvals"`
+	// UserCPUQuotaMillicores, UserMemoryQuotaBytes and UserDiskQuotaBytes
+	// limit the resources that the workspaces of the template owned by a
+	// single user can use in total. Templates declare the usage of resources
+	// with "cpu", "memory" and "disk" metadata items. Workspaces that would
+	// exceed a quota fail to start. If zero, the usage is not limited.
+	UserCPUQuotaMillicores int64 `json:"user_cpu_quota_millicores"`
+	UserMemoryQuotaBytes   int64 `json:"user_memory_quota_bytes"`
+	UserDiskQuotaBytes     int64 `json:"user_disk_quota_bytes"`
+	// ApplyDotfiles is whether the agents of the template apply the dotfiles
+	// repository of the workspace owner when they start.
+	ApplyDotfiles bool `json:"apply_dotfiles"`
+	// DisableAutostop is whether workspaces of the template never stop
+	// automatically when they have no TTL, even if the organization has a
+	// default autostop.
+	DisableAutostop bool `json:"disable_autostop"`
 
 	// FailureTTLMillis, InactivityTTLMillis, and LockedTTLMillis are enterprise-only. Their
 	// values are used if your license is entitled to use the advanced
@@ -50,6 +85,22 @@ type Template struct {
 	FailureTTLMillis    int64 `json:"failure_ttl_ms"`
 	InactivityTTLMillis int64 `json:"inactivity_ttl_ms"`
 	LockedTTLMillis     int64 `json:"locked_ttl_ms"`
+	// FailureRetries is the number of times a failed workspace start is
+	// retried before the workspace is stopped because of the failure TTL.
+	// The owner is notified once the retries are exhausted.
+	FailureRetries int32 `json:"failure_retries"`
+	// FailureRetryBackoffMillis is how long to wait after a failed workspace
+	// start before it is retried. It doubles after every retry.
+	FailureRetryBackoffMillis int64 `json:"failure_retry_backoff_ms"`
+	// MaxLifetimeMillis is how long after their creation workspaces are
+	// deleted, regardless of their activity. If the template has a locked
+	// TTL, workspaces are locked instead. Zero disables the max lifetime.
+	MaxLifetimeMillis int64 `json:"max_lifetime_ms"`
+	// ArchiveRetentionMillis is how long workspaces that reached the locked
+	// TTL are archived for before they are deleted. Owners can restore
+	// archived workspaces by unlocking them. Zero deletes workspaces without
+	// archiving them.
+	ArchiveRetentionMillis int64 `json:"archive_retention_ms"`
 }
 
 // WeekdaysToBitmap converts a list of weekdays to a bitmap in accordance with
@@ -110,8 +161,8 @@ func BitmapToWeekdays(bitmap uint8) []string {
 type TemplateRestartRequirement struct {
 	// DaysOfWeek is a list of days of the week on which restarts are required.
 	// Restarts happen within the user's quiet hours (in their configured
-	// timezone). If no days are specified, restarts are not required. Weekdays
-	// cannot be specified twice.
+	// timezone, unless Timezone is set). If no days are specified, restarts
+	// are not required. Weekdays cannot be specified twice.
 	//
 	// Restarts will only happen on weekdays in this list on weeks which line up
 	// with Weeks.
@@ -122,6 +173,21 @@ type TemplateRestartRequirement struct {
 	// Values of 0 or 1 indicate weekly restarts. Values of 2 indicate
 	// fortnightly restarts, etc.
 	Weeks int64 `json:"weeks"`
+	// Timezone is an optional IANA timezone (e.g. "Europe/London") that the
+	// user's quiet hours are evaluated in. If empty, the timezone of the
+	// user's quiet hours schedule is used. Setting this anchors restarts to a
+	// single timezone across all users of the template.
+	Timezone string `json:"timezone,omitempty"`
+	// SpreadMillis is the duration after the start of the user's quiet hours
+	// that required restarts are spread across. Each workspace is assigned a
+	// stable offset within this window, so workspaces don't all restart at
+	// the same time. If zero, all workspaces restart at the start of the quiet
+	// hours.
+	SpreadMillis int64 `json:"spread_ms,omitempty"`
+	// UpdateOnRestart starts workspaces that were stopped because of the
+	// restart requirement again, updated to the active template version. If
+	// false, these workspaces stay stopped until their users start them.
+	UpdateOnRestart bool `json:"update_on_restart,omitempty"`
 }
 
 type TransitionStats struct {
@@ -180,6 +246,11 @@ type UpdateTemplateMeta struct {
 	Description      string `json:"description,omitempty"`
 	Icon             string `json:"icon,omitempty"`
 	DefaultTTLMillis int64  `json:"default_ttl_ms,omitempty"`
+	// ActivityBumpMillis is how long workspaces stay running after activity
+	// was last detected. Workspaces that are idle for longer are stopped. If
+	// zero, activity bumps the deadline by the workspace's TTL. If nil, the
+	// activity bump of the template is kept.
+	ActivityBumpMillis *int64 `json:"activity_bump_ms,omitempty"`
 	// TODO(@dean): remove max_ttl once restart_requirement is matured
 	MaxTTLMillis int64 `json:"max_ttl_ms,omitempty"`
 	// RestartRequirement can only be set if your license includes the advanced
@@ -192,6 +263,124 @@ type UpdateTemplateMeta struct {
 	FailureTTLMillis             int64                       `json:"failure_ttl_ms,omitempty"`
 	InactivityTTLMillis          int64                       `json:"inactivity_ttl_ms,omitempty"`
 	LockedTTLMillis              int64                       `json:"locked_ttl_ms,omitempty"`
+	// FailureRetries is the number of times a failed workspace start is
+	// retried before the workspace is stopped because of the failure TTL. If
+	// nil, the number is unchanged.
+	FailureRetries *int32 `json:"failure_retries,omitempty"`
+	// FailureRetryBackoffMillis is how long to wait after a failed workspace
+	// start before it is retried. It doubles after every retry. If nil, the
+	// backoff is unchanged.
+	FailureRetryBackoffMillis *int64 `json:"failure_retry_backoff_ms,omitempty"`
+	// MaxLifetimeMillis is how long after their creation workspaces are
+	// deleted, or locked if the template has a locked TTL. If nil, the max
+	// lifetime is unchanged. Zero disables it.
+	MaxLifetimeMillis *int64 `json:"max_lifetime_ms,omitempty"`
+	// ArchiveRetentionMillis is how long workspaces that reached the locked
+	// TTL are archived for before they are deleted. If nil, the retention is
+	// unchanged. Zero deletes workspaces without archiving them.
+	ArchiveRetentionMillis *int64 `json:"archive_retention_ms,omitempty"`
+	// MaxConcurrentBuilds is the maximum number of workspace builds of the
+	// template that provisioners run at the same time. If nil, the limit is
+	// unchanged. Zero removes the limit.
+	MaxConcurrentBuilds *int32 `json:"max_concurrent_builds,omitempty"`
+	// MaxDeadlineExtensionsPerDay is the maximum number of times that users
+	// can extend the deadline of a workspace within 24 hours. If nil, the
+	// limit is unchanged. Zero removes the limit.
+	MaxDeadlineExtensionsPerDay *int32 `json:"max_deadline_extensions_per_day,omitempty"`
+	// MaxDeadlineExtensionMillis is the maximum duration that users can extend
+	// the deadline of a workspace by at once. If nil, the limit is unchanged.
+	// Zero removes the limit.
+	MaxDeadlineExtensionMillis *int64 `json:"max_deadline_extension_ms,omitempty"`
+	// RequiredPromotionApprovals is the number of approvals a template version
+	// promotion needs before the version becomes active. If nil, the number is
+	// unchanged. Zero allows versions to be made active directly.
+	RequiredPromotionApprovals *int32 `json:"required_promotion_approvals,omitempty"`
+	// UserCPUQuotaMillicores, UserMemoryQuotaBytes and UserDiskQuotaBytes
+	// limit the resources that the workspaces of the template owned by a
+	// single user can use in total. If nil, the quota is unchanged. Zero
+	// removes the quota.
+	UserCPUQuotaMillicores *int64 `json:"user_cpu_quota_millicores,omitempty"`
+	UserMemoryQuotaBytes   *int64 `json:"user_memory_quota_bytes,omitempty"`
+	UserDiskQuotaBytes     *int64 `json:"user_disk_quota_bytes,omitempty"`
+	// ApplyDotfiles is whether the agents of the template apply the dotfiles
+	// repository of the workspace owner when they start. If nil, the setting
+	// is unchanged.
+	ApplyDotfiles *bool `json:"apply_dotfiles,omitempty"`
+	// DisableAutostop is whether workspaces of the template never stop
+	// automatically when they have no TTL, even if the organization has a
+	// default autostop. It can't be set together with DefaultTTLMillis. If
+	// nil, the setting is unchanged.
+	DisableAutostop *bool `json:"disable_autostop,omitempty"`
+	// DryRun returns a preview of the changes the update would make to the
+	// deadlines of active workspace builds instead of updating the template.
+	// Use UpdateTemplateMetaDryRun to send a dry run request.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// TemplateScheduleDryRun is a preview of the changes a template schedule
+// update would make to the active workspace builds of the template.
+type TemplateScheduleDryRun struct {
+	Workspaces []TemplateScheduleDryRunWorkspace `json:"workspaces"`
+}
+
+// TemplateScheduleDryRunWorkspace is the change to the deadlines of the latest
+// build of a workspace.
+type TemplateScheduleDryRunWorkspace struct {
+	WorkspaceID      uuid.UUID `json:"workspace_id" format:"uuid"`
+	WorkspaceName    string    `json:"workspace_name"`
+	OwnerID          uuid.UUID `json:"owner_id" format:"uuid"`
+	WorkspaceBuildID uuid.UUID `json:"workspace_build_id" format:"uuid"`
+	Deadline         NullTime  `json:"deadline,omitempty" format:"date-time"`
+	MaxDeadline      NullTime  `json:"max_deadline,omitempty" format:"date-time"`
+	NewDeadline      NullTime  `json:"new_deadline,omitempty" format:"date-time"`
+	NewMaxDeadline   NullTime  `json:"new_max_deadline,omitempty" format:"date-time"`
+}
+
+type TemplateDeadlineRecalculationStatus string
+
+const (
+	TemplateDeadlineRecalculationStatusPending   TemplateDeadlineRecalculationStatus = "pending"
+	TemplateDeadlineRecalculationStatusRunning   TemplateDeadlineRecalculationStatus = "running"
+	TemplateDeadlineRecalculationStatusSucceeded TemplateDeadlineRecalculationStatus = "succeeded"
+	TemplateDeadlineRecalculationStatusFailed    TemplateDeadlineRecalculationStatus = "failed"
+)
+
+// TemplateDeadlineRecalculation is a job that recalculates the deadline and
+// max_deadline of all active workspace builds of a template using the current
+// template schedule. Jobs are queued whenever the template schedule changes,
+// and can also be started manually.
+type TemplateDeadlineRecalculation struct {
+	ID          uuid.UUID                           `json:"id" format:"uuid"`
+	TemplateID  uuid.UUID                           `json:"template_id" format:"uuid"`
+	Status      TemplateDeadlineRecalculationStatus `json:"status" enums:"pending,running,succeeded,failed"`
+	CreatedAt   time.Time                           `json:"created_at" format:"date-time"`
+	CompletedAt *time.Time                          `json:"completed_at,omitempty" format:"date-time"`
+	// TotalBuilds is the number of active workspace builds of the template.
+	// It is zero until the builds have been fetched.
+	TotalBuilds int `json:"total_builds"`
+	// ProcessedBuilds is the number of workspace builds that have been
+	// processed so far.
+	ProcessedBuilds int    `json:"processed_builds"`
+	Error           string `json:"error,omitempty"`
+}
+
+// TemplateSchedulePolicy describes where the schedule settings of a template
+// come from. Templates can inherit the schedule settings of a policy
+// template, so changing the policy template affects all of them.
+type TemplateSchedulePolicy struct {
+	// PolicyTemplateID is the template that the schedule settings are
+	// inherited from. If nil, the template uses its own schedule settings.
+	PolicyTemplateID *uuid.UUID `json:"policy_template_id,omitempty" format:"uuid"`
+	// InheritedBy are the templates that inherit their schedule settings
+	// from this template.
+	InheritedBy []uuid.UUID `json:"inherited_by" format:"uuid"`
+}
+
+type UpdateTemplateSchedulePolicyRequest struct {
+	// PolicyTemplateID is the template to inherit the schedule settings
+	// from. It must be in the same organization. If nil, the template uses
+	// its own schedule settings again.
+	PolicyTemplateID *uuid.UUID `json:"policy_template_id,omitempty" format:"uuid"`
 }
 
 type TemplateExample struct {
@@ -246,6 +435,23 @@ func (c *Client) UpdateTemplateMeta(ctx context.Context, templateID uuid.UUID, r
 	return updated, json.NewDecoder(res.Body).Decode(&updated)
 }
 
+// UpdateTemplateMetaDryRun returns the changes that updating the template with
+// req would make to the deadlines of its active workspace builds, without
+// updating the template.
+func (c *Client) UpdateTemplateMetaDryRun(ctx context.Context, templateID uuid.UUID, req UpdateTemplateMeta) (TemplateScheduleDryRun, error) {
+	req.DryRun = true
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/templates/%s", templateID), req)
+	if err != nil {
+		return TemplateScheduleDryRun{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateScheduleDryRun{}, ReadBodyAsError(res)
+	}
+	var dryRun TemplateScheduleDryRun
+	return dryRun, json.NewDecoder(res.Body).Decode(&dryRun)
+}
+
 func (c *Client) UpdateTemplateACL(ctx context.Context, templateID uuid.UUID, req UpdateTemplateACL) error {
 	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/templates/%s/acl", templateID), req)
 	if err != nil {
@@ -285,6 +491,81 @@ func (c *Client) TemplateACL(ctx context.Context, templateID uuid.UUID) (Templat
 	return acl, json.NewDecoder(res.Body).Decode(&acl)
 }
 
+// RecalculateTemplateDeadlines queues a job that recalculates the deadline and
+// max_deadline of all active workspace builds of the template.
+func (c *Client) RecalculateTemplateDeadlines(ctx context.Context, templateID uuid.UUID) (TemplateDeadlineRecalculation, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templates/%s/recalculate-deadlines", templateID), nil)
+	if err != nil {
+		return TemplateDeadlineRecalculation{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return TemplateDeadlineRecalculation{}, ReadBodyAsError(res)
+	}
+	var job TemplateDeadlineRecalculation
+	return job, json.NewDecoder(res.Body).Decode(&job)
+}
+
+// TemplateDeadlineRecalculations returns the recent deadline recalculation jobs
+// of the template, newest first.
+func (c *Client) TemplateDeadlineRecalculations(ctx context.Context, templateID uuid.UUID) ([]TemplateDeadlineRecalculation, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/recalculate-deadlines", templateID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var jobs []TemplateDeadlineRecalculation
+	return jobs, json.NewDecoder(res.Body).Decode(&jobs)
+}
+
+// TemplateDeadlineRecalculation returns the progress of a deadline
+// recalculation job started with RecalculateTemplateDeadlines.
+func (c *Client) TemplateDeadlineRecalculation(ctx context.Context, templateID, jobID uuid.UUID) (TemplateDeadlineRecalculation, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/recalculate-deadlines/%s", templateID, jobID), nil)
+	if err != nil {
+		return TemplateDeadlineRecalculation{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateDeadlineRecalculation{}, ReadBodyAsError(res)
+	}
+	var job TemplateDeadlineRecalculation
+	return job, json.NewDecoder(res.Body).Decode(&job)
+}
+
+// TemplateSchedulePolicy returns where the schedule settings of the template
+// come from.
+func (c *Client) TemplateSchedulePolicy(ctx context.Context, templateID uuid.UUID) (TemplateSchedulePolicy, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/schedule-policy", templateID), nil)
+	if err != nil {
+		return TemplateSchedulePolicy{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateSchedulePolicy{}, ReadBodyAsError(res)
+	}
+	var policy TemplateSchedulePolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
+// UpdateTemplateSchedulePolicy sets the template that the template inherits
+// its schedule settings from.
+func (c *Client) UpdateTemplateSchedulePolicy(ctx context.Context, templateID uuid.UUID, req UpdateTemplateSchedulePolicyRequest) (TemplateSchedulePolicy, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/schedule-policy", templateID), req)
+	if err != nil {
+		return TemplateSchedulePolicy{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateSchedulePolicy{}, ReadBodyAsError(res)
+	}
+	var policy TemplateSchedulePolicy
+	return policy, json.NewDecoder(res.Body).Decode(&policy)
+}
+
 // UpdateActiveTemplateVersion updates the active template version to the ID provided.
 // The template version must be attached to the template.
 func (c *Client) UpdateActiveTemplateVersion(ctx context.Context, template uuid.UUID, req UpdateActiveTemplateVersion) error {
@@ -0,0 +1,119 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TemplateVersionPromotionStatus string
+
+const (
+	TemplateVersionPromotionStatusPending  TemplateVersionPromotionStatus = "pending"
+	TemplateVersionPromotionStatusPromoted TemplateVersionPromotionStatus = "promoted"
+	TemplateVersionPromotionStatusRejected TemplateVersionPromotionStatus = "rejected"
+)
+
+// TemplateVersionPromotion is a request to make a template version the active
+// version of its template. Templates with required promotion approvals only
+// change their active version once enough users approved the promotion.
+type TemplateVersionPromotion struct {
+	ID                uuid.UUID `json:"id" format:"uuid"`
+	TemplateID        uuid.UUID `json:"template_id" format:"uuid"`
+	TemplateVersionID uuid.UUID `json:"template_version_id" format:"uuid"`
+	RequestedBy       uuid.UUID `json:"requested_by" format:"uuid"`
+	// ApprovedBy are the IDs of the users that approved the promotion.
+	ApprovedBy []uuid.UUID `json:"approved_by" format:"uuid"`
+	// RequiredApprovals is the number of approvals the template currently
+	// requires.
+	RequiredApprovals int32                          `json:"required_approvals"`
+	Status            TemplateVersionPromotionStatus `json:"status" enums:"pending,promoted,rejected"`
+	CreatedAt         time.Time                      `json:"created_at" format:"date-time"`
+	UpdatedAt         time.Time                      `json:"updated_at" format:"date-time"`
+}
+
+type CreateTemplateVersionPromotionRequest struct {
+	TemplateVersionID uuid.UUID `json:"template_version_id" validate:"required" format:"uuid"`
+}
+
+// TemplateVersionPromotions returns the promotions of the template, newest
+// first. If status is not empty, only promotions with the status are returned.
+func (c *Client) TemplateVersionPromotions(ctx context.Context, templateID uuid.UUID, status TemplateVersionPromotionStatus) ([]TemplateVersionPromotion, error) {
+	var opts []RequestOption
+	if status != "" {
+		opts = append(opts, WithQueryParam("status", string(status)))
+	}
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/promotions", templateID), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var promotions []TemplateVersionPromotion
+	return promotions, json.NewDecoder(res.Body).Decode(&promotions)
+}
+
+// TemplateVersionPromotion returns a promotion of the template.
+func (c *Client) TemplateVersionPromotion(ctx context.Context, templateID, promotionID uuid.UUID) (TemplateVersionPromotion, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/promotions/%s", templateID, promotionID), nil)
+	if err != nil {
+		return TemplateVersionPromotion{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersionPromotion{}, ReadBodyAsError(res)
+	}
+	var promotion TemplateVersionPromotion
+	return promotion, json.NewDecoder(res.Body).Decode(&promotion)
+}
+
+// CreateTemplateVersionPromotion requests to make the template version the
+// active version of the template. If the template doesn't require approvals,
+// the version is promoted immediately.
+func (c *Client) CreateTemplateVersionPromotion(ctx context.Context, templateID uuid.UUID, req CreateTemplateVersionPromotionRequest) (TemplateVersionPromotion, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templates/%s/promotions", templateID), req)
+	if err != nil {
+		return TemplateVersionPromotion{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return TemplateVersionPromotion{}, ReadBodyAsError(res)
+	}
+	var promotion TemplateVersionPromotion
+	return promotion, json.NewDecoder(res.Body).Decode(&promotion)
+}
+
+// ApproveTemplateVersionPromotion approves a pending promotion. The version is
+// promoted once the promotion has the required number of approvals.
+func (c *Client) ApproveTemplateVersionPromotion(ctx context.Context, templateID, promotionID uuid.UUID) (TemplateVersionPromotion, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templates/%s/promotions/%s/approve", templateID, promotionID), nil)
+	if err != nil {
+		return TemplateVersionPromotion{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersionPromotion{}, ReadBodyAsError(res)
+	}
+	var promotion TemplateVersionPromotion
+	return promotion, json.NewDecoder(res.Body).Decode(&promotion)
+}
+
+// RejectTemplateVersionPromotion rejects a pending promotion.
+func (c *Client) RejectTemplateVersionPromotion(ctx context.Context, templateID, promotionID uuid.UUID) (TemplateVersionPromotion, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templates/%s/promotions/%s/reject", templateID, promotionID), nil)
+	if err != nil {
+		return TemplateVersionPromotion{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateVersionPromotion{}, ReadBodyAsError(res)
+	}
+	var promotion TemplateVersionPromotion
+	return promotion, json.NewDecoder(res.Body).Decode(&promotion)
+}
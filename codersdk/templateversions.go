@@ -86,6 +86,14 @@ type TemplateVersionVariable struct {
 	Sensitive    bool   `json:"sensitive"`
 }
 
+// TemplateVersionPreset is a named set of parameter values that users can
+// select when creating a workspace, e.g. "small" or "GPU".
+type TemplateVersionPreset struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description,omitempty"`
+	Parameters  []WorkspaceBuildParameter `json:"parameters"`
+}
+
 type PatchTemplateVersionRequest struct {
 	Name    string  `json:"name" validate:"omitempty,template_version_name"`
 	Message *string `json:"message,omitempty" validate:"omitempty,lt=1048577"`
@@ -118,6 +126,47 @@ func (c *Client) CancelTemplateVersion(ctx context.Context, version uuid.UUID) e
 	return nil
 }
 
+// TemplateRegistryAuth holds the credentials of a template registry.
+// Registries such as GitHub Container Registry accept an access token as the
+// password.
+type TemplateRegistryAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// PushTemplateVersionRequest publishes the source of a template version to a
+// template registry.
+type PushTemplateVersionRequest struct {
+	// Reference is the registry, repository, and tag to push to, e.g.
+	// oci://ghcr.io/coder/templates/docker:v1.
+	Reference string                `json:"reference" validate:"required"`
+	Auth      *TemplateRegistryAuth `json:"auth,omitempty"`
+}
+
+// PushTemplateVersionResponse identifies a template version that was pushed
+// to a template registry.
+type PushTemplateVersionResponse struct {
+	Reference string `json:"reference"`
+	// Digest is the digest of the pushed manifest. Append it to the
+	// reference with an @ to pull exactly this version.
+	Digest string `json:"digest"`
+}
+
+// PushTemplateVersion publishes the source of a template version to a
+// template registry.
+func (c *Client) PushTemplateVersion(ctx context.Context, version uuid.UUID, req PushTemplateVersionRequest) (PushTemplateVersionResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templateversions/%s/push", version), req)
+	if err != nil {
+		return PushTemplateVersionResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return PushTemplateVersionResponse{}, ReadBodyAsError(res)
+	}
+	var resp PushTemplateVersionResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 // TemplateVersionParameters returns parameters a template version exposes.
 func (c *Client) TemplateVersionRichParameters(ctx context.Context, version uuid.UUID) ([]TemplateVersionParameter, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/rich-parameters", version), nil)
@@ -174,6 +223,20 @@ func (c *Client) TemplateVersionVariables(ctx context.Context, version uuid.UUID
 	return variables, json.NewDecoder(res.Body).Decode(&variables)
 }
 
+// TemplateVersionPresets returns the parameter presets of a template version.
+func (c *Client) TemplateVersionPresets(ctx context.Context, version uuid.UUID) ([]TemplateVersionPreset, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/presets", version), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var presets []TemplateVersionPreset
+	return presets, json.NewDecoder(res.Body).Decode(&presets)
+}
+
 // TemplateVersionLogsAfter streams logs for a template version that occurred after a specific log ID.
 func (c *Client) TemplateVersionLogsAfter(ctx context.Context, version uuid.UUID, after int64) (<-chan ProvisionerJobLog, io.Closer, error) {
 	return c.provisionerJobLogsAfter(ctx, fmt.Sprintf("/api/v2/templateversions/%s/logs", version), after)
@@ -0,0 +1,53 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// The grant and token types of token exchanges, see RFC 8693.
+const (
+	TokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	TokenTypeIDToken       = "urn:ietf:params:oauth:token-type:id_token"
+	TokenTypeJWT           = "urn:ietf:params:oauth:token-type:jwt"
+	TokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+// DefaultTokenExchangeLifetime is how long exchanged API tokens are valid if
+// their policy doesn't set a lifetime.
+const DefaultTokenExchangeLifetime = time.Hour
+
+// TokenExchangeRequest exchanges an identity token of an external OIDC issuer
+// for an API token. The fields are named like in RFC 8693.
+type TokenExchangeRequest struct {
+	GrantType    string `json:"grant_type" validate:"required"`
+	SubjectToken string `json:"subject_token" validate:"required"`
+	// SubjectTokenType is an ID token if it's empty.
+	SubjectTokenType string `json:"subject_token_type,omitempty"`
+}
+
+type TokenExchangeResponse struct {
+	// AccessToken is the API token, it's used like a session token.
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	// ExpiresIn is the number of seconds the API token is valid.
+	ExpiresIn int64 `json:"expires_in"`
+}
+
+// ExchangeToken exchanges an identity token of an external OIDC issuer for a
+// short-lived API token. The request doesn't need to be authenticated.
+func (c *Client) ExchangeToken(ctx context.Context, req TokenExchangeRequest) (TokenExchangeResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/oauth/token-exchange", req)
+	if err != nil {
+		return TokenExchangeResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TokenExchangeResponse{}, ReadBodyAsError(res)
+	}
+	var resp TokenExchangeResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
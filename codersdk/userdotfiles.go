@@ -0,0 +1,60 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserDotfiles is the dotfiles repository of a user. The agents of the
+// user's workspaces apply it with "coder dotfiles" every time they start,
+// unless the template disables it.
+type UserDotfiles struct {
+	UserID uuid.UUID `json:"user_id" format:"uuid"`
+	// RepoURL is the URL of the repository. It's empty if the user hasn't
+	// configured a dotfiles repository.
+	RepoURL string `json:"repo_url"`
+	// Branch is the branch to check out. If empty, the default branch of the
+	// repository is used.
+	Branch    string    `json:"branch"`
+	UpdatedAt time.Time `json:"updated_at" format:"date-time"`
+}
+
+// UpdateUserDotfilesRequest sets the dotfiles repository of a user. An empty
+// repository URL removes it.
+type UpdateUserDotfilesRequest struct {
+	RepoURL string `json:"repo_url"`
+	Branch  string `json:"branch,omitempty"`
+}
+
+// UserDotfiles returns the dotfiles repository of a user.
+func (c *Client) UserDotfiles(ctx context.Context, user string) (UserDotfiles, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/dotfiles", user), nil)
+	if err != nil {
+		return UserDotfiles{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return UserDotfiles{}, ReadBodyAsError(res)
+	}
+	var dotfiles UserDotfiles
+	return dotfiles, json.NewDecoder(res.Body).Decode(&dotfiles)
+}
+
+// UpdateUserDotfiles sets or removes the dotfiles repository of a user.
+func (c *Client) UpdateUserDotfiles(ctx context.Context, user string, req UpdateUserDotfilesRequest) (UserDotfiles, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/users/%s/dotfiles", user), req)
+	if err != nil {
+		return UserDotfiles{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return UserDotfiles{}, ReadBodyAsError(res)
+	}
+	var dotfiles UserDotfiles
+	return dotfiles, json.NewDecoder(res.Body).Decode(&dotfiles)
+}
@@ -108,6 +108,21 @@ type UserQuietHoursScheduleResponse struct {
 	Timezone string `json:"timezone"` // raw format from the cron expression, UTC if unspecified
 	// Next is the next time that the quiet hours window will start.
 	Next time.Time `json:"next" format:"date-time"`
+	// Exceptions are the dates in YYYY-MM-DD format on which the quiet hours
+	// window is skipped, ordered by date. Template restart requirements that
+	// fall on one of these dates are moved to the next applicable day.
+	Exceptions []string `json:"exceptions"`
+}
+
+// UserQuietHoursExceptionDateLayout is the layout of the date of a user quiet
+// hours exception.
+const UserQuietHoursExceptionDateLayout = "2006-01-02"
+
+type CreateUserQuietHoursExceptionRequest struct {
+	// Date is the calendar date in YYYY-MM-DD format on which the user's quiet
+	// hours window should be skipped. It is evaluated in the timezone of the
+	// user's quiet hours schedule and must not be in the past.
+	Date string `json:"date" validate:"required" format:"date"`
 }
 
 type UpdateUserQuietHoursScheduleRequest struct {
@@ -434,6 +449,37 @@ func (c *Client) UpdateUserQuietHoursSchedule(ctx context.Context, userIdent str
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
+// CreateUserQuietHoursException skips the user's quiet hours window on the
+// given date. This endpoint only exists in enterprise editions.
+func (c *Client) CreateUserQuietHoursException(ctx context.Context, userIdent string, req CreateUserQuietHoursExceptionRequest) (UserQuietHoursScheduleResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/users/%s/quiet-hours/exceptions", userIdent), req)
+	if err != nil {
+		return UserQuietHoursScheduleResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return UserQuietHoursScheduleResponse{}, ReadBodyAsError(res)
+	}
+	var resp UserQuietHoursScheduleResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// DeleteUserQuietHoursException removes a quiet hours exception from the
+// user. The date must be in YYYY-MM-DD format. This endpoint only exists in
+// enterprise editions.
+func (c *Client) DeleteUserQuietHoursException(ctx context.Context, userIdent string, date string) (UserQuietHoursScheduleResponse, error) {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/users/%s/quiet-hours/exceptions/%s", userIdent, date), nil)
+	if err != nil {
+		return UserQuietHoursScheduleResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return UserQuietHoursScheduleResponse{}, ReadBodyAsError(res)
+	}
+	var resp UserQuietHoursScheduleResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 // Users returns all users according to the request parameters. If no parameters are set,
 // the default behavior is to return all users in a single page.
 func (c *Client) Users(ctx context.Context, req UsersRequest) (GetUsersResponse, error) {
@@ -9,14 +9,18 @@ import (
 	"net"
 	"net/http"
 	"net/netip"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/go-multierror"
 	"golang.org/x/crypto/ssh"
+	"golang.org/x/exp/slices"
+	"golang.org/x/net/proxy"
 	"golang.org/x/xerrors"
 	"tailscale.com/ipn/ipnstate"
 	"tailscale.com/net/speedtest"
@@ -43,10 +47,13 @@ const (
 	// WorkspaceAgentHTTPAPIServerPort serves a HTTP server with endpoints for e.g.
 	// gathering agent statistics.
 	WorkspaceAgentHTTPAPIServerPort = 4
+	// WorkspaceAgentSOCKS5Port serves a SOCKS5 proxy that dials connections
+	// from the workspace network.
+	WorkspaceAgentSOCKS5Port = 5
 
 	// WorkspaceAgentMinimumListeningPort is the minimum port that the listening-ports
 	// endpoint will return to the client, and the minimum port that is accepted
-	// by the proxy applications endpoint. Coder consumes ports 1-4 at the
+	// by the proxy applications endpoint. Coder consumes ports 1-5 at the
 	// moment, and we reserve some extra ports for future use. Port 9 and up are
 	// available for the user.
 	//
@@ -143,6 +150,34 @@ func NewWorkspaceAgentConn(conn *tailnet.Conn, opts WorkspaceAgentConnOptions) *
 type WorkspaceAgentConn struct {
 	*tailnet.Conn
 	opts WorkspaceAgentConnOptions
+
+	// lastPingNanos is the round-trip time of the last successful ping.
+	lastPingNanos atomic.Int64
+}
+
+// WorkspaceAgentConnStats are statistics of a connection to a workspace
+// agent, useful to diagnose slow connections.
+// @typescript-ignore WorkspaceAgentConnStats
+type WorkspaceAgentConnStats struct {
+	// RxBytes is the number of bytes received from the agent.
+	RxBytes int64
+	// TxBytes is the number of bytes sent to the agent.
+	TxBytes int64
+	// P2P is true if the agent is connected directly rather than through a
+	// DERP relay.
+	P2P bool
+	// Endpoint is the address the agent is connected directly through. It's
+	// empty if the connection isn't P2P.
+	Endpoint string
+	// DERPRegionID is the home DERP region of the agent, which relays the
+	// connection if it isn't P2P.
+	DERPRegionID int
+	// Latency is the round-trip time of the last successful ping to the
+	// agent. It's zero if the agent hasn't been pinged yet.
+	Latency time.Duration
+	// LastHandshake is when the last WireGuard handshake with the agent
+	// happened.
+	LastHandshake time.Time
 }
 
 // @typescript-ignore WorkspaceAgentConnOptions
@@ -175,7 +210,44 @@ func (c *WorkspaceAgentConn) Ping(ctx context.Context) (time.Duration, bool, *ip
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
 
-	return c.Conn.Ping(ctx, c.agentAddress())
+	duration, p2p, pong, err := c.Conn.Ping(ctx, c.agentAddress())
+	if err == nil {
+		c.lastPingNanos.Store(int64(duration))
+	}
+	return duration, p2p, pong, err
+}
+
+// Stats returns the statistics of the connection to the agent. The byte
+// counters and connection path are zero until the agent is reachable.
+func (c *WorkspaceAgentConn) Stats() WorkspaceAgentConnStats {
+	stats := WorkspaceAgentConnStats{
+		Latency: time.Duration(c.lastPingNanos.Load()),
+	}
+	agentAddress := c.agentAddress()
+	status := c.Conn.Status()
+	for nodeKey, peer := range status.Peer {
+		addresses, ok := c.Conn.NodeAddresses(nodeKey)
+		if !ok || !slices.ContainsFunc(addresses, func(prefix netip.Prefix) bool {
+			return prefix.Addr() == agentAddress
+		}) {
+			continue
+		}
+		stats.RxBytes = peer.RxBytes
+		stats.TxBytes = peer.TxBytes
+		stats.P2P = peer.CurAddr != ""
+		stats.Endpoint = peer.CurAddr
+		stats.LastHandshake = peer.LastHandshake
+		if derpMap := c.Conn.DERPMap(); derpMap != nil && peer.Relay != "" {
+			for regionID, region := range derpMap.Regions {
+				if region != nil && region.RegionCode == peer.Relay {
+					stats.DERPRegionID = regionID
+					break
+				}
+			}
+		}
+		break
+	}
+	return stats
 }
 
 // Close ends the connection to the workspace agent.
@@ -284,6 +356,42 @@ func (c *WorkspaceAgentConn) SSHClient(ctx context.Context) (*ssh.Client, error)
 	return ssh.NewClient(sshConn, channels, requests), nil
 }
 
+// SOCKS5 pipes the SOCKS5 protocol over the returned net.Conn.
+// This connects to the built-in SOCKS5 proxy in the workspace agent.
+func (c *WorkspaceAgentConn) SOCKS5(ctx context.Context) (net.Conn, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+
+	if !c.AwaitReachable(ctx) {
+		return nil, xerrors.Errorf("workspace agent not reachable in time: %v", ctx.Err())
+	}
+
+	return c.Conn.DialContextTCP(ctx, netip.AddrPortFrom(c.agentAddress(), WorkspaceAgentSOCKS5Port))
+}
+
+// SOCKS5Dialer returns a dialer that makes connections through the built-in
+// SOCKS5 proxy in the workspace agent. Unlike DialContext, any address that is
+// reachable from the workspace can be dialed, not just ports on the agent.
+func (c *WorkspaceAgentConn) SOCKS5Dialer() proxy.ContextDialer {
+	// SOCKS5 never returns an error.
+	dialer, _ := proxy.SOCKS5("tcp", net.JoinHostPort(c.agentAddress().String(), strconv.Itoa(WorkspaceAgentSOCKS5Port)), nil, socks5Forwarder{conn: c})
+	//nolint:forcetypeassert // The SOCKS5 dialer always implements ContextDialer.
+	return dialer.(proxy.ContextDialer)
+}
+
+// socks5Forwarder dials the SOCKS5 proxy in the workspace agent.
+type socks5Forwarder struct {
+	conn *WorkspaceAgentConn
+}
+
+func (f socks5Forwarder) Dial(network, addr string) (net.Conn, error) {
+	return f.DialContext(context.Background(), network, addr)
+}
+
+func (f socks5Forwarder) DialContext(ctx context.Context, _, _ string) (net.Conn, error) {
+	return f.conn.SOCKS5(ctx)
+}
+
 // Speedtest runs a speedtest against the workspace agent.
 func (c *WorkspaceAgentConn) Speedtest(ctx context.Context, direction speedtest.Direction, duration time.Duration) ([]speedtest.Result, error) {
 	ctx, span := tracing.StartSpan(ctx)
@@ -361,22 +469,152 @@ func (c *WorkspaceAgentConn) ListeningPorts(ctx context.Context) (WorkspaceAgent
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
 
+// WorkspaceAgentDebugLogsResponse is the end of the log files of the agent.
+type WorkspaceAgentDebugLogsResponse struct {
+	Files []WorkspaceAgentDebugLogFile `json:"files"`
+}
+
+type WorkspaceAgentDebugLogFile struct {
+	Name string `json:"name"`
+	// Truncated is true if only the end of the file is included.
+	Truncated bool   `json:"truncated"`
+	Content   string `json:"content"`
+}
+
+// DebugLogs returns the end of the log files of the agent, such as its own
+// log and the logs of the startup and shutdown scripts.
+func (c *WorkspaceAgentConn) DebugLogs(ctx context.Context) (WorkspaceAgentDebugLogsResponse, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodGet, "/api/v0/debug/logs", nil)
+	if err != nil {
+		return WorkspaceAgentDebugLogsResponse{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceAgentDebugLogsResponse{}, ReadBodyAsError(res)
+	}
+
+	var resp WorkspaceAgentDebugLogsResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// WorkspaceAgentFileInfo describes a file in the workspace.
+type WorkspaceAgentFileInfo struct {
+	// Path is the absolute path of the file in the workspace.
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+	// Mode contains the permission bits of the file.
+	Mode    uint32    `json:"mode"`
+	ModTime time.Time `json:"mod_time" format:"date-time"`
+	IsDir   bool      `json:"is_dir"`
+}
+
+// WorkspaceAgentUploadFileOptions configures an upload to the workspace.
+type WorkspaceAgentUploadFileOptions struct {
+	// Offset appends the upload to the file instead of replacing it. It must
+	// match the size of the file in the workspace, and is used to resume an
+	// interrupted upload.
+	Offset int64
+	// Mode sets the permission bits of the file. The file keeps its current
+	// mode, or is created with 0644, if it's zero.
+	Mode os.FileMode
+}
+
+// StatFile returns information about a file in the workspace. Relative paths
+// are relative to the working directory of SSH sessions.
+func (c *WorkspaceAgentConn) StatFile(ctx context.Context, path string) (WorkspaceAgentFileInfo, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	res, err := c.apiRequest(ctx, http.MethodGet, "/api/v0/files/stat?"+url.Values{"path": {path}}.Encode(), nil)
+	if err != nil {
+		return WorkspaceAgentFileInfo{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceAgentFileInfo{}, ReadBodyAsError(res)
+	}
+
+	var resp WorkspaceAgentFileInfo
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// DownloadFile streams a file from the workspace, starting at offset. Callers
+// must close the returned reader.
+func (c *WorkspaceAgentConn) DownloadFile(ctx context.Context, path string, offset int64) (io.ReadCloser, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	req, err := c.newAPIRequest(ctx, http.MethodGet, "/api/v0/files?"+url.Values{"path": {path}}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res, err := c.apiClient().Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("do request: %w", err)
+	}
+	expected := http.StatusOK
+	if offset > 0 {
+		expected = http.StatusPartialContent
+	}
+	if res.StatusCode != expected {
+		defer res.Body.Close()
+		return nil, ReadBodyAsError(res)
+	}
+	return res.Body, nil
+}
+
+// UploadFile streams r to a file in the workspace.
+func (c *WorkspaceAgentConn) UploadFile(ctx context.Context, path string, r io.Reader, opts WorkspaceAgentUploadFileOptions) (WorkspaceAgentFileInfo, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	query := url.Values{"path": {path}}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.FormatInt(opts.Offset, 10))
+	}
+	if opts.Mode != 0 {
+		query.Set("mode", strconv.FormatUint(uint64(opts.Mode.Perm()), 8))
+	}
+	res, err := c.apiRequest(ctx, http.MethodPut, "/api/v0/files?"+query.Encode(), r)
+	if err != nil {
+		return WorkspaceAgentFileInfo{}, xerrors.Errorf("do request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceAgentFileInfo{}, ReadBodyAsError(res)
+	}
+
+	var resp WorkspaceAgentFileInfo
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 // apiRequest makes a request to the workspace agent's HTTP API server.
 func (c *WorkspaceAgentConn) apiRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
 
-	host := net.JoinHostPort(c.agentAddress().String(), strconv.Itoa(WorkspaceAgentHTTPAPIServerPort))
-	url := fmt.Sprintf("http://%s%s", host, path)
-
-	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	req, err := c.newAPIRequest(ctx, method, path, body)
 	if err != nil {
-		return nil, xerrors.Errorf("new http api request to %q: %w", url, err)
+		return nil, err
 	}
 
 	return c.apiClient().Do(req)
 }
 
+// newAPIRequest creates a request to the workspace agent's HTTP API server.
+func (c *WorkspaceAgentConn) newAPIRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	host := net.JoinHostPort(c.agentAddress().String(), strconv.Itoa(WorkspaceAgentHTTPAPIServerPort))
+	reqURL := fmt.Sprintf("http://%s%s", host, path)
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, xerrors.Errorf("new http api request to %q: %w", reqURL, err)
+	}
+	return req, nil
+}
+
 // apiClient returns an HTTP client that can be used to make
 // requests to the workspace agent's HTTP API server.
 func (c *WorkspaceAgentConn) apiClient() *http.Client {
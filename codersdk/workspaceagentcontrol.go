@@ -0,0 +1,200 @@
+package codersdk
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// A control socket shares a connection to a workspace agent between
+// processes, like OpenSSH's ControlMaster. The process that dialed the agent
+// serves the socket, and every connection to the socket is forwarded to the
+// SSH server of the agent over the existing tailnet connection. Connecting
+// through the socket skips the API requests and the tailnet handshake, so it
+// takes milliseconds instead of seconds.
+
+// workspaceAgentControlDialTimeout is how long the process serving a control
+// socket has to connect to the SSH server of the agent. A process that can't
+// reach the agent anymore stops serving the socket, so it can be replaced.
+const workspaceAgentControlDialTimeout = 10 * time.Second
+
+// WorkspaceAgentControlOptions configure ServeWorkspaceAgentControl.
+type WorkspaceAgentControlOptions struct {
+	// Persist is how long the control socket is served while no connections
+	// are forwarded, including before the first one.
+	Persist time.Duration
+	Logger  slog.Logger
+}
+
+// ListenWorkspaceAgentControl listens on a control socket at path. The
+// directory of the socket is created if it doesn't exist, and is only
+// accessible by the current user. A socket left behind by a process that
+// exited is replaced, but a socket that's still served is not.
+func ListenWorkspaceAgentControl(ctx context.Context, path string) (net.Listener, error) {
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return nil, xerrors.Errorf("create control socket directory: %w", err)
+	}
+	conn, err := DialWorkspaceAgentControl(ctx, path)
+	if err == nil {
+		_ = conn.Close()
+		return nil, xerrors.Errorf("control socket %q is already in use", path)
+	}
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, xerrors.Errorf("remove stale control socket: %w", err)
+	}
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, "unix", path)
+	if err != nil {
+		return nil, xerrors.Errorf("listen on control socket: %w", err)
+	}
+	return l, nil
+}
+
+// DialWorkspaceAgentControl connects to the SSH server of a workspace agent
+// through the control socket at path. It fails if the process serving the
+// socket can't reach the agent, so the socket can be replaced.
+func DialWorkspaceAgentControl(ctx context.Context, path string) (net.Conn, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, xerrors.Errorf("dial control socket: %w", err)
+	}
+	// The SSH server sends its version first, so wait for it to make sure
+	// the connection was forwarded to the agent. The serving process closes
+	// the connection if it can't dial the agent.
+	deadline := time.Now().Add(workspaceAgentControlDialTimeout + 5*time.Second)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetReadDeadline(deadline)
+	r := bufio.NewReader(conn)
+	_, err = r.Peek(1)
+	if err != nil {
+		_ = conn.Close()
+		return nil, xerrors.Errorf("control socket is not connected to the agent: %w", err)
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	return &bufferedConn{Conn: conn, r: r}, nil
+}
+
+// bufferedConn reads the bytes buffered while dialing before reading from
+// the connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// ServeWorkspaceAgentControl forwards the connections accepted by l to the
+// SSH server of the agent over conn. It returns when ctx is canceled, no
+// connections were forwarded for the persist duration, or the agent can't be
+// reached over conn anymore. Either way, l and all forwarded connections are
+// closed.
+func ServeWorkspaceAgentControl(ctx context.Context, l net.Listener, conn *WorkspaceAgentConn, opts WorkspaceAgentControlOptions) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		active  int
+		dialErr error
+	)
+	idle := time.AfterFunc(opts.Persist, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if active == 0 {
+			opts.Logger.Debug(ctx, "control socket is idle")
+			cancel()
+		}
+	})
+	defer idle.Stop()
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	for {
+		local, err := l.Accept()
+		if err != nil {
+			cancel()
+			wg.Wait()
+			if dialErr != nil {
+				return xerrors.Errorf("dial agent ssh server: %w", dialErr)
+			}
+			if xerrors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return xerrors.Errorf("accept: %w", err)
+		}
+		mu.Lock()
+		active++
+		idle.Stop()
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				mu.Lock()
+				defer mu.Unlock()
+				active--
+				if active == 0 {
+					idle.Reset(opts.Persist)
+				}
+			}()
+			defer local.Close()
+
+			dialCtx, cancelDial := context.WithTimeout(ctx, workspaceAgentControlDialTimeout)
+			remote, err := conn.SSH(dialCtx)
+			cancelDial()
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				// The connection to the agent is dead, so stop serving
+				// the socket and let the next session replace it.
+				opts.Logger.Warn(ctx, "dial agent ssh server, closing control socket", slog.Error(err))
+				mu.Lock()
+				dialErr = err
+				mu.Unlock()
+				cancel()
+				return
+			}
+			defer remote.Close()
+			opts.Logger.Debug(ctx, "forwarding control socket connection")
+			pipeWorkspaceAgentControl(ctx, local, remote)
+		}()
+	}
+}
+
+// pipeWorkspaceAgentControl copies between the two connections until either
+// closes or ctx is canceled.
+func pipeWorkspaceAgentControl(ctx context.Context, c1, c2 net.Conn) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	copyFunc := func(dst, src net.Conn) {
+		defer cancel()
+		_, _ = io.Copy(dst, src)
+	}
+	go copyFunc(c1, c2)
+	go copyFunc(c2, c1)
+	<-ctx.Done()
+	_ = c1.Close()
+	_ = c2.Close()
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/netip"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -126,6 +127,9 @@ type WorkspaceAgentMetadataDescription struct {
 	Script      string `json:"script"`
 	Interval    int64  `json:"interval"`
 	Timeout     int64  `json:"timeout"`
+	// Plugin is true if the metadata is collected by a metadata plugin of the
+	// agent instead of being defined in the template.
+	Plugin bool `json:"plugin"`
 }
 
 type WorkspaceAgentMetadata struct {
@@ -133,6 +137,47 @@ type WorkspaceAgentMetadata struct {
 	Description WorkspaceAgentMetadataDescription `json:"description"`
 }
 
+// WorkspaceAgentScriptStatus is the status of the last run of a workspace
+// agent script.
+type WorkspaceAgentScriptStatus string
+
+const (
+	WorkspaceAgentScriptStatusPending  WorkspaceAgentScriptStatus = "pending"
+	WorkspaceAgentScriptStatusRunning  WorkspaceAgentScriptStatus = "running"
+	WorkspaceAgentScriptStatusOK       WorkspaceAgentScriptStatus = "ok"
+	WorkspaceAgentScriptStatusError    WorkspaceAgentScriptStatus = "error"
+	WorkspaceAgentScriptStatusTimedOut WorkspaceAgentScriptStatus = "timed_out"
+	// WorkspaceAgentScriptStatusSkipped is set when an earlier script
+	// failed without continue_on_error.
+	WorkspaceAgentScriptStatusSkipped WorkspaceAgentScriptStatus = "skipped"
+)
+
+// WorkspaceAgentScript is a script the agent runs when the workspace starts
+// or stops. It is provided via the `script` list in the `coder_agent` block.
+// Scripts run one after another in the order they are listed, after the
+// startup or shutdown script of the agent.
+type WorkspaceAgentScript struct {
+	Name            string                     `json:"name"`
+	Script          string                     `json:"script"`
+	RunOnStart      bool                       `json:"run_on_start"`
+	RunOnStop       bool                       `json:"run_on_stop"`
+	TimeoutSeconds  int32                      `json:"timeout_seconds"`
+	ContinueOnError bool                       `json:"continue_on_error"`
+	Status          WorkspaceAgentScriptStatus `json:"status"`
+	ExitCode        int32                      `json:"exit_code"`
+	StartedAt       *time.Time                 `json:"started_at,omitempty" format:"date-time"`
+	EndedAt         *time.Time                 `json:"ended_at,omitempty" format:"date-time"`
+}
+
+// WorkspaceAgentDevcontainer is a repository with a devcontainer.json that the
+// agent applies when it starts. It is provided via the `coder_devcontainer`
+// resource. ConfigPath is relative to WorkspaceFolder; if it's empty, the
+// agent looks for `.devcontainer/devcontainer.json` and `.devcontainer.json`.
+type WorkspaceAgentDevcontainer struct {
+	WorkspaceFolder string `json:"workspace_folder"`
+	ConfigPath      string `json:"config_path,omitempty"`
+}
+
 type WorkspaceAgent struct {
 	ID                          uuid.UUID                           `json:"id" format:"uuid"`
 	CreatedAt                   time.Time                           `json:"created_at" format:"date-time"`
@@ -223,6 +268,10 @@ type DialWorkspaceAgentOptions struct {
 	// BlockEndpoints forced a direct connection through DERP. The Client may
 	// have DisableDirect set which will override this value.
 	BlockEndpoints bool
+	// PreferDERPRegion selects the DERP regions the connection prefers as its
+	// home region, which relays traffic that can't be sent directly to the
+	// agent. See PreferDERPRegions.
+	PreferDERPRegion func(region *tailcfg.DERPRegion) bool
 }
 
 func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, options *DialWorkspaceAgentOptions) (agentConn *WorkspaceAgentConn, err error) {
@@ -234,6 +283,25 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 	if err != nil {
 		return nil, xerrors.Errorf("get connection info: %w", err)
 	}
+	coordinateURL, err := c.URL.Parse(fmt.Sprintf("/api/v2/workspaceagents/%s/coordinate", agentID))
+	if err != nil {
+		return nil, xerrors.Errorf("parse url: %w", err)
+	}
+	return c.DialWorkspaceAgentWithInfo(ctx, agentID, connInfo, coordinateURL, options)
+}
+
+// coordinatorResetBackoffAfter is how long a coordinator connection must be
+// up before a failure reconnects without a backoff.
+const coordinatorResetBackoffAfter = time.Minute
+
+// DialWorkspaceAgentWithInfo dials a workspace agent using the given
+// connection info and coordinates with the agent through coordinateURL. The
+// client's session token is sent to the coordinate endpoint, so agents can use
+// it to dial their peers with an agent token.
+func (c *Client) DialWorkspaceAgentWithInfo(ctx context.Context, agentID uuid.UUID, connInfo WorkspaceAgentConnectionInfo, coordinateURL *url.URL, options *DialWorkspaceAgentOptions) (agentConn *WorkspaceAgentConn, err error) {
+	if options == nil {
+		options = &DialWorkspaceAgentOptions{}
+	}
 	if connInfo.DisableDirectConnections {
 		options.BlockEndpoints = true
 	}
@@ -248,7 +316,7 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 	}
 	conn, err := tailnet.NewConn(&tailnet.Options{
 		Addresses:      []netip.Prefix{netip.PrefixFrom(ip, 128)},
-		DERPMap:        connInfo.DERPMap,
+		DERPMap:        PreferDERPRegions(connInfo.DERPMap, options.PreferDERPRegion),
 		DERPHeader:     &header,
 		Logger:         options.Logger,
 		BlockEndpoints: c.DisableDirectConnections || options.BlockEndpoints,
@@ -262,12 +330,17 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 		}
 	}()
 
-	headers := make(http.Header)
 	tokenHeader := SessionTokenHeader
 	if c.SessionTokenHeader != "" {
 		tokenHeader = c.SessionTokenHeader
 	}
-	headers.Set(tokenHeader, c.SessionToken())
+	// The headers are built for every attempt, so reconnecting uses the
+	// current session token of the client if it was replaced.
+	headers := func() http.Header {
+		h := make(http.Header)
+		h.Set(tokenHeader, c.SessionToken())
+		return h
+	}
 	ctx, cancel := context.WithCancel(ctx)
 	defer func() {
 		if err != nil {
@@ -275,10 +348,6 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 		}
 	}()
 
-	coordinateURL, err := c.URL.Parse(fmt.Sprintf("/api/v2/workspaceagents/%s/coordinate", agentID))
-	if err != nil {
-		return nil, xerrors.Errorf("parse url: %w", err)
-	}
 	closedCoordinator := make(chan struct{})
 	firstCoordinator := make(chan error)
 	go func() {
@@ -289,7 +358,7 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 			// nolint:bodyclose
 			ws, res, err := websocket.Dial(ctx, coordinateURL.String(), &websocket.DialOptions{
 				HTTPClient: c.HTTPClient,
-				HTTPHeader: headers,
+				HTTPHeader: headers(),
 				// Need to disable compression to avoid a data-race.
 				CompressionMode: websocket.CompressionDisabled,
 			})
@@ -305,6 +374,10 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 				if errors.Is(err, context.Canceled) {
 					return
 				}
+				if res != nil && res.StatusCode == http.StatusUnauthorized {
+					options.Logger.Warn(ctx, "coordinator rejected the session token", slog.Error(err))
+					continue
+				}
 				options.Logger.Debug(ctx, "failed to dial", slog.Error(err))
 				continue
 			}
@@ -313,7 +386,14 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 			})
 			conn.SetNodeCallback(sendNode)
 			options.Logger.Debug(ctx, "serving coordinator")
+			connected := time.Now()
 			err = <-errChan
+			// Reconnect right away if the connection was up for a while,
+			// e.g. when the replica serving it shut down, instead of
+			// waiting for the backoff of earlier failures.
+			if time.Since(connected) > coordinatorResetBackoffAfter {
+				retrier.Reset()
+			}
 			if errors.Is(err, context.Canceled) {
 				_ = ws.Close(websocket.StatusGoingAway, "")
 				return
@@ -341,7 +421,7 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 			// nolint:bodyclose
 			ws, res, err := websocket.Dial(ctx, derpMapURL.String(), &websocket.DialOptions{
 				HTTPClient: c.HTTPClient,
-				HTTPHeader: headers,
+				HTTPHeader: headers(),
 				// Need to disable compression to avoid a data-race.
 				CompressionMode: websocket.CompressionDisabled,
 			})
@@ -373,14 +453,16 @@ func (c *Client) DialWorkspaceAgent(ctx context.Context, agentID uuid.UUID, opti
 					return
 				}
 				if err != nil {
+					// Reconnect, e.g. to another replica after the one
+					// serving the updates shut down.
 					options.Logger.Debug(ctx, "failed to decode derp map", slog.Error(err))
 					_ = ws.Close(websocket.StatusGoingAway, "")
-					return
+					break
 				}
 
 				if !tailnet.CompareDERPMaps(conn.DERPMap(), &derpMap) {
 					options.Logger.Debug(ctx, "updating derp map due to detected changes")
-					conn.SetDERPMap(&derpMap)
+					conn.SetDERPMap(PreferDERPRegions(&derpMap, options.PreferDERPRegion))
 				}
 			}
 		}
@@ -627,6 +709,37 @@ func (c *Client) WorkspaceAgentListeningPorts(ctx context.Context, agentID uuid.
 	return listeningPorts, json.NewDecoder(res.Body).Decode(&listeningPorts)
 }
 
+// WorkspaceAgentScripts returns the scripts of a workspace agent and the
+// status of their last run.
+func (c *Client) WorkspaceAgentScripts(ctx context.Context, agentID uuid.UUID) ([]WorkspaceAgentScript, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaceagents/%s/scripts", agentID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var scripts []WorkspaceAgentScript
+	return scripts, json.NewDecoder(res.Body).Decode(&scripts)
+}
+
+// WorkspaceAgentMetadata returns the latest metadata reported by a workspace
+// agent, including the metadata collected by its metadata plugins. Use
+// WatchWorkspaceAgentMetadata to receive updates as they are reported.
+func (c *Client) WorkspaceAgentMetadata(ctx context.Context, agentID uuid.UUID) ([]WorkspaceAgentMetadata, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaceagents/%s/metadata", agentID), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var metadata []WorkspaceAgentMetadata
+	return metadata, json.NewDecoder(res.Body).Decode(&metadata)
+}
+
 //nolint:revive // Follow is a control flag on the server as well.
 func (c *Client) WorkspaceAgentLogsAfter(ctx context.Context, agentID uuid.UUID, after int64, follow bool) (<-chan []WorkspaceAgentLog, io.Closer, error) {
 	var queryParams []string
@@ -732,23 +845,27 @@ func (g GitProvider) Pretty() string {
 		return "GitLab"
 	case GitProviderBitBucket:
 		return "Bitbucket"
+	case GitProviderBitBucketServer:
+		return "Bitbucket Server"
 	default:
 		return string(g)
 	}
 }
 
 const (
-	GitProviderAzureDevops GitProvider = "azure-devops"
-	GitProviderGitHub      GitProvider = "github"
-	GitProviderGitLab      GitProvider = "gitlab"
-	GitProviderBitBucket   GitProvider = "bitbucket"
+	GitProviderAzureDevops     GitProvider = "azure-devops"
+	GitProviderGitHub          GitProvider = "github"
+	GitProviderGitLab          GitProvider = "gitlab"
+	GitProviderBitBucket       GitProvider = "bitbucket"
+	GitProviderBitBucketServer GitProvider = "bitbucket-server"
 )
 
 type WorkspaceAgentLog struct {
-	ID        int64     `json:"id"`
-	CreatedAt time.Time `json:"created_at" format:"date-time"`
-	Output    string    `json:"output"`
-	Level     LogLevel  `json:"level"`
+	ID        int64                   `json:"id"`
+	CreatedAt time.Time               `json:"created_at" format:"date-time"`
+	Output    string                  `json:"output"`
+	Level     LogLevel                `json:"level"`
+	Source    WorkspaceAgentLogSource `json:"source"`
 }
 
 type AgentSubsystem string
@@ -777,4 +894,6 @@ const (
 	WorkspaceAgentLogSourceEnvbox         WorkspaceAgentLogSource = "envbox"
 	WorkspaceAgentLogSourceEnvbuilder     WorkspaceAgentLogSource = "envbuilder"
 	WorkspaceAgentLogSourceExternal       WorkspaceAgentLogSource = "external"
+	WorkspaceAgentLogSourceDotfiles       WorkspaceAgentLogSource = "dotfiles"
+	WorkspaceAgentLogSourceDevcontainer   WorkspaceAgentLogSource = "devcontainer"
 )
@@ -2,21 +2,30 @@ package codersdk_test
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"net/url"
 	"strconv"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"nhooyr.io/websocket"
 	"tailscale.com/tailcfg"
 
+	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/tailnet"
+	"github.com/coder/coder/tailnet/tailnettest"
 	"github.com/coder/coder/testutil"
 )
 
@@ -94,3 +103,97 @@ func TestAgentReportStats(t *testing.T) {
 	closeStream.Close()
 	require.Equal(t, int64(1), numIntervalCalls.Load())
 }
+
+func TestDialWorkspaceAgentReconnect(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+	derpMap, _ := tailnettest.RunDERPAndSTUN(t)
+	coordinator := tailnet.NewCoordinator(logger)
+	t.Cleanup(func() {
+		_ = coordinator.Close()
+	})
+
+	// Connect an agent to the coordinator.
+	agentID := uuid.New()
+	agentConn, err := tailnet.NewConn(&tailnet.Options{
+		Addresses: []netip.Prefix{netip.PrefixFrom(codersdk.WorkspaceAgentIP, 128)},
+		DERPMap:   derpMap,
+		Logger:    logger.Named("agent"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = agentConn.Close()
+	})
+	agentClientPipe, agentServerPipe := net.Pipe()
+	t.Cleanup(func() {
+		_ = agentClientPipe.Close()
+		_ = agentServerPipe.Close()
+	})
+	go func() {
+		_ = coordinator.ServeAgent(agentServerPipe, agentID, "agent")
+	}()
+	sendNode, _ := tailnet.ServeCoordinator(agentClientPipe, func(nodes []*tailnet.Node) error {
+		return agentConn.UpdateNodes(nodes, false)
+	})
+	agentConn.SetNodeCallback(sendNode)
+
+	tokens := make(chan string, 1)
+	disconnect := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/coordinate") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		ws, err := websocket.Accept(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer ws.Close(websocket.StatusGoingAway, "")
+		select {
+		case tokens <- r.Header.Get(codersdk.SessionTokenHeader):
+		case <-ctx.Done():
+			return
+		}
+		go func() {
+			_ = coordinator.ServeClient(websocket.NetConn(ctx, ws, websocket.MessageBinary), uuid.New(), agentID)
+		}()
+		// Simulate the replica serving the connection shutting down.
+		select {
+		case <-disconnect:
+		case <-ctx.Done():
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	serverURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	coordinateURL, err := serverURL.Parse("/api/v2/workspaceagents/" + agentID.String() + "/coordinate")
+	require.NoError(t, err)
+	client := codersdk.New(serverURL)
+	client.SetSessionToken("first")
+	conn, err := client.DialWorkspaceAgentWithInfo(ctx, agentID, codersdk.WorkspaceAgentConnectionInfo{
+		DERPMap: derpMap,
+	}, coordinateURL, &codersdk.DialWorkspaceAgentOptions{
+		Logger: logger.Named("client"),
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+	nextToken := func() string {
+		select {
+		case token := <-tokens:
+			return token
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for the coordinator to be dialed")
+			return ""
+		}
+	}
+	require.Equal(t, "first", nextToken())
+
+	// Reconnecting uses the current token of the client.
+	client.SetSessionToken("second")
+	disconnect <- struct{}{}
+	require.Equal(t, "second", nextToken())
+	require.True(t, conn.AwaitReachable(ctx))
+}
@@ -21,6 +21,13 @@ const (
 	WorkspaceAppSharingLevelPublic        WorkspaceAppSharingLevel = "public"
 )
 
+type WorkspaceAppAuth string
+
+const (
+	WorkspaceAppAuthCoder WorkspaceAppAuth = "coder"
+	WorkspaceAppAuthOIDC  WorkspaceAppAuth = "oidc"
+)
+
 type WorkspaceApp struct {
 	ID uuid.UUID `json:"id" format:"uuid"`
 	// URL is the address being proxied to inside the workspace.
@@ -43,6 +50,10 @@ type WorkspaceApp struct {
 	// be accessible in the UI.
 	Subdomain    bool                     `json:"subdomain"`
 	SharingLevel WorkspaceAppSharingLevel `json:"sharing_level" enums:"owner,authenticated,public"`
+	// Auth is how users that are not signed in to Coder authenticate with
+	// the app. With "oidc", anyone that logs in with the workspace app OIDC
+	// provider may use the app.
+	Auth WorkspaceAppAuth `json:"auth" enums:"coder,oidc"`
 	// Healthcheck specifies the configuration for checking app health.
 	Healthcheck Healthcheck        `json:"healthcheck"`
 	Health      WorkspaceAppHealth `json:"health"`
@@ -14,9 +14,10 @@ import (
 type WorkspaceTransition string
 
 const (
-	WorkspaceTransitionStart  WorkspaceTransition = "start"
-	WorkspaceTransitionStop   WorkspaceTransition = "stop"
-	WorkspaceTransitionDelete WorkspaceTransition = "delete"
+	WorkspaceTransitionStart   WorkspaceTransition = "start"
+	WorkspaceTransitionStop    WorkspaceTransition = "stop"
+	WorkspaceTransitionDelete  WorkspaceTransition = "delete"
+	WorkspaceTransitionArchive WorkspaceTransition = "archive"
 )
 
 type WorkspaceStatus string
@@ -46,6 +47,33 @@ const (
 	// "autostop" is used when a build to stop a workspace is triggered by Autostop.
 	// The initiator id/username in this case is the workspace owner and can be ignored.
 	BuildReasonAutostop BuildReason = "autostop"
+	// "restart_requirement" is used when a workspace is stopped, or started
+	// again on the active template version, because of the restart
+	// requirement of its template.
+	BuildReasonRestartRequirement BuildReason = "restart_requirement"
+	// "failure_ttl" is used when a failed start of a workspace is retried, or
+	// the workspace is stopped, because of the failure TTL of its template.
+	BuildReasonFailureTTL BuildReason = "failure_ttl"
+	// "inactivity_ttl" is used when a workspace is stopped because it was
+	// locked after breaching the inactivity TTL of its template.
+	BuildReasonInactivityTTL BuildReason = "inactivity_ttl"
+	// "locked_ttl" is used when a locked workspace is deleted because it
+	// breached the locked TTL of its template.
+	BuildReasonLockedTTL BuildReason = "locked_ttl"
+	// "admin_forced" is used when a build is triggered by a user other than
+	// the workspace owner, e.g. an administrator.
+	BuildReasonAdminForced BuildReason = "admin_forced"
+	// "max_lifetime" is used when a workspace is deleted, or stopped and
+	// locked, because it reached the max lifetime of its template.
+	BuildReasonMaxLifetime BuildReason = "max_lifetime"
+	// "prebuild" is used when a prebuilt workspace is built or deleted by
+	// the prebuilds controller to keep the prebuild pool of its template
+	// full.
+	BuildReasonPrebuild BuildReason = "prebuild"
+	// "resume" is used when a build that failed or was canceled is run
+	// again with the template version, parameters and saved state of the
+	// interrupted build.
+	BuildReasonResume BuildReason = "resume"
 )
 
 // WorkspaceBuild is an at-point representation of a workspace state.
@@ -61,11 +89,11 @@ type WorkspaceBuild struct {
 	TemplateVersionID   uuid.UUID           `json:"template_version_id" format:"uuid"`
 	TemplateVersionName string              `json:"template_version_name"`
 	BuildNumber         int32               `json:"build_number"`
-	Transition          WorkspaceTransition `json:"transition" enums:"start,stop,delete"`
+	Transition          WorkspaceTransition `json:"transition" enums:"start,stop,delete,archive"`
 	InitiatorID         uuid.UUID           `json:"initiator_id" format:"uuid"`
 	InitiatorUsername   string              `json:"initiator_name"`
 	Job                 ProvisionerJob      `json:"job"`
-	Reason              BuildReason         `db:"reason" json:"reason" enums:"initiator,autostart,autostop"`
+	Reason              BuildReason         `db:"reason" json:"reason" enums:"initiator,autostart,autostop,restart_requirement,failure_ttl,inactivity_ttl,locked_ttl,admin_forced,max_lifetime,prebuild,resume"`
 	Resources           []WorkspaceResource `json:"resources"`
 	Deadline            NullTime            `json:"deadline,omitempty" format:"date-time"`
 	MaxDeadline         NullTime            `json:"max_deadline,omitempty" format:"date-time"`
@@ -79,7 +107,7 @@ type WorkspaceResource struct {
 	ID         uuid.UUID                   `json:"id" format:"uuid"`
 	CreatedAt  time.Time                   `json:"created_at" format:"date-time"`
 	JobID      uuid.UUID                   `json:"job_id" format:"uuid"`
-	Transition WorkspaceTransition         `json:"workspace_transition" enums:"start,stop,delete"`
+	Transition WorkspaceTransition         `json:"workspace_transition" enums:"start,stop,delete,archive"`
 	Type       string                      `json:"type"`
 	Name       string                      `json:"name"`
 	Hide       bool                        `json:"hide"`
@@ -102,6 +130,54 @@ type WorkspaceBuildParameter struct {
 	Value string `json:"value"`
 }
 
+// WorkspaceBuildTimelineStage is a step of a workspace build.
+type WorkspaceBuildTimelineStage string
+
+const (
+	// WorkspaceBuildTimelineStageQueued is the time the provisioner job of
+	// the build waited for a provisioner daemon.
+	WorkspaceBuildTimelineStageQueued WorkspaceBuildTimelineStage = "queued"
+	WorkspaceBuildTimelineStageInit   WorkspaceBuildTimelineStage = "init"
+	WorkspaceBuildTimelineStagePlan   WorkspaceBuildTimelineStage = "plan"
+	WorkspaceBuildTimelineStageApply  WorkspaceBuildTimelineStage = "apply"
+	// WorkspaceBuildTimelineStageAgentConnect is the time from the creation
+	// of an agent to its first connection.
+	WorkspaceBuildTimelineStageAgentConnect WorkspaceBuildTimelineStage = "agent_connect"
+	// WorkspaceBuildTimelineStageAgentStartup is the time an agent ran its
+	// startup scripts, until it was ready.
+	WorkspaceBuildTimelineStageAgentStartup WorkspaceBuildTimelineStage = "agent_startup"
+	// WorkspaceBuildTimelineStageStartupScript is the time a single startup
+	// script of an agent ran.
+	WorkspaceBuildTimelineStageStartupScript WorkspaceBuildTimelineStage = "startup_script"
+)
+
+// WorkspaceBuildTimeline is the time spent in each stage of a workspace
+// build, for debugging slow builds.
+type WorkspaceBuildTimeline struct {
+	WorkspaceBuildID uuid.UUID `json:"workspace_build_id" format:"uuid"`
+	// TraceID is the ID of the trace of the provisioner job of the build, to
+	// find its spans in the tracing backend. It's empty if the job wasn't
+	// traced.
+	TraceID string                        `json:"trace_id,omitempty"`
+	Entries []WorkspaceBuildTimelineEntry `json:"entries"`
+}
+
+// WorkspaceBuildTimelineEntry is a stage of a workspace build. Stages that
+// haven't started yet are omitted from the timeline.
+type WorkspaceBuildTimelineEntry struct {
+	Stage WorkspaceBuildTimelineStage `json:"stage" enums:"queued,init,plan,apply,agent_connect,agent_startup,startup_script"`
+	// AgentID and AgentName are set for the stages of agents.
+	AgentID   *uuid.UUID `json:"agent_id,omitempty" format:"uuid"`
+	AgentName string     `json:"agent_name,omitempty"`
+	// ScriptName is the name of the script of the startup_script stage.
+	ScriptName string    `json:"script_name,omitempty"`
+	StartedAt  time.Time `json:"started_at" format:"date-time"`
+	// EndedAt is nil if the stage is still running.
+	EndedAt *time.Time `json:"ended_at,omitempty" format:"date-time"`
+	// DurationMS is the duration of the stage so far if it's still running.
+	DurationMS int64 `json:"duration_ms"`
+}
+
 // WorkspaceBuild returns a single workspace build for a workspace.
 // If history is "", the latest version is returned.
 func (c *Client) WorkspaceBuild(ctx context.Context, id uuid.UUID) (WorkspaceBuild, error) {
@@ -173,3 +249,18 @@ func (c *Client) WorkspaceBuildParameters(ctx context.Context, build uuid.UUID)
 	var params []WorkspaceBuildParameter
 	return params, json.NewDecoder(res.Body).Decode(&params)
 }
+
+// WorkspaceBuildTimeline returns the time spent in each stage of a workspace
+// build.
+func (c *Client) WorkspaceBuildTimeline(ctx context.Context, build uuid.UUID) (WorkspaceBuildTimeline, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspacebuilds/%s/timeline", build), nil)
+	if err != nil {
+		return WorkspaceBuildTimeline{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceBuildTimeline{}, ReadBodyAsError(res)
+	}
+	var timeline WorkspaceBuildTimeline
+	return timeline, json.NewDecoder(res.Body).Decode(&timeline)
+}
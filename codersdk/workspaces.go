@@ -43,11 +43,28 @@ type Workspace struct {
 	// unlocked by an admin. It is subject to deletion if it breaches
 	// the duration of the locked_ttl field on its template.
 	LockedAt *time.Time `json:"locked_at" format:"date-time"`
+	// LockReason is why the workspace was locked. It is empty if the
+	// workspace isn't locked.
+	LockReason WorkspaceLockReason `json:"lock_reason,omitempty" enums:"manual,inactivity,max_lifetime"`
 	// Health shows the health of the workspace and information about
 	// what is causing an unhealthy status.
 	Health WorkspaceHealth `json:"health"`
 }
 
+// WorkspaceLockReason is why a workspace was locked.
+type WorkspaceLockReason string
+
+const (
+	// WorkspaceLockReasonManual means a user locked the workspace.
+	WorkspaceLockReasonManual WorkspaceLockReason = "manual"
+	// WorkspaceLockReasonInactivity means the workspace was inactive for
+	// the inactivity TTL of its template.
+	WorkspaceLockReasonInactivity WorkspaceLockReason = "inactivity"
+	// WorkspaceLockReasonMaxLifetime means the workspace reached the max
+	// lifetime of its template.
+	WorkspaceLockReasonMaxLifetime WorkspaceLockReason = "max_lifetime"
+)
+
 func (w Workspace) FullName() string {
 	return fmt.Sprintf("%s/%s", w.OwnerName, w.Name)
 }
@@ -81,6 +98,11 @@ type CreateWorkspaceBuildRequest struct {
 	ProvisionerState  []byte              `json:"state,omitempty"`
 	// Orphan may be set for the Destroy transition.
 	Orphan bool `json:"orphan,omitempty"`
+	// Resume runs the latest build of the workspace again after it failed or
+	// was canceled. The build uses the template version, parameters and saved
+	// state of the interrupted build, so resources that were already applied
+	// are kept. Transition must match the interrupted build.
+	Resume bool `json:"resume,omitempty"`
 	// ParameterValues are optional. It will write params to the 'workspace' scope.
 	// This will overwrite any existing parameters with the same name.
 	// This will not delete old params not included in this list.
@@ -172,8 +194,22 @@ func (c *Client) CreateWorkspaceBuild(ctx context.Context, workspace uuid.UUID,
 func (c *Client) WatchWorkspace(ctx context.Context, id uuid.UUID) (<-chan Workspace, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
+	return c.watchWorkspaces(ctx, fmt.Sprintf("/api/v2/workspaces/%s/watch", id))
+}
+
+// WatchWorkspaces streams updates of every workspace in the deployment.
+// Updates of a workspace that changes often are coalesced, so each
+// received workspace is the latest state at the time it was sent.
+// Requires permission to read all workspaces.
+func (c *Client) WatchWorkspaces(ctx context.Context) (<-chan Workspace, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+	return c.watchWorkspaces(ctx, "/api/v2/workspaces/watch")
+}
+
+func (c *Client) watchWorkspaces(ctx context.Context, path string) (<-chan Workspace, error) {
 	//nolint:bodyclose
-	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/watch", id), nil)
+	res, err := c.Request(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -235,6 +271,10 @@ func (c *Client) UpdateWorkspace(ctx context.Context, id uuid.UUID, req UpdateWo
 
 // UpdateWorkspaceAutostartRequest is a request to update a workspace's autostart schedule.
 type UpdateWorkspaceAutostartRequest struct {
+	// Schedule is either a cron expression such as
+	// "CRON_TZ=Europe/Berlin 0 9 * * 1-5", or a human-readable schedule such
+	// as "weekdays 9am Europe/Berlin" which is stored as the equivalent cron
+	// expression. Human-readable schedules without a timezone use UTC.
 	Schedule *string `json:"schedule"`
 }
 
@@ -293,6 +333,182 @@ func (c *Client) PutExtendWorkspace(ctx context.Context, id uuid.UUID, req PutEx
 	return nil
 }
 
+// ExtendWorkspaceRequest is a request to extend the deadline of the active
+// workspace build by a duration, within the limits set by the template.
+type ExtendWorkspaceRequest struct {
+	DurationMillis int64 `json:"duration_ms" validate:"required"`
+}
+
+// ExtendWorkspaceResponse is the result of extending the deadline of the
+// active workspace build.
+type ExtendWorkspaceResponse struct {
+	Deadline time.Time `json:"deadline" format:"date-time"`
+	// RemainingExtensions is the number of times the deadline can still be
+	// extended within the last 24 hours. It is nil if the template doesn't
+	// limit the number of extensions.
+	RemainingExtensions *int32 `json:"remaining_extensions,omitempty"`
+}
+
+// ExtendWorkspace extends the deadline of the latest workspace build by a
+// duration. Unlike PutExtendWorkspace, it is bounded by the deadline
+// extension limits of the template.
+func (c *Client) ExtendWorkspace(ctx context.Context, id uuid.UUID, req ExtendWorkspaceRequest) (ExtendWorkspaceResponse, error) {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/extend", id.String())
+	res, err := c.Request(ctx, http.MethodPost, path, req)
+	if err != nil {
+		return ExtendWorkspaceResponse{}, xerrors.Errorf("extend workspace deadline: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ExtendWorkspaceResponse{}, ReadBodyAsError(res)
+	}
+	var resp ExtendWorkspaceResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// CloneSourceWorkspaceParameterName is the name of the parameter that
+// templates declare to copy the persistent volumes of the source workspace
+// to clones, e.g. by creating the volumes from snapshots. The value of the
+// parameter is the ID of the source workspace.
+const CloneSourceWorkspaceParameterName = "coder_clone_source_workspace_id"
+
+// CloneWorkspaceRequest is a request to create a workspace with the template
+// version, parameter values, and schedule of another workspace.
+type CloneWorkspaceRequest struct {
+	Name string `json:"name" validate:"workspace_name,required"`
+	// CopyVolumes sets the CloneSourceWorkspaceParameterName parameter of the
+	// clone, so that the template can copy the persistent volumes of the
+	// source workspace. The template version must declare the parameter.
+	CopyVolumes bool `json:"copy_volumes,omitempty"`
+}
+
+// CloneWorkspace creates a workspace for the authenticated user with the
+// template version and parameter values of the workspace.
+func (c *Client) CloneWorkspace(ctx context.Context, id uuid.UUID, req CloneWorkspaceRequest) (Workspace, error) {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/clone", id.String())
+	res, err := c.Request(ctx, http.MethodPost, path, req)
+	if err != nil {
+		return Workspace{}, xerrors.Errorf("clone workspace: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return Workspace{}, ReadBodyAsError(res)
+	}
+	var workspace Workspace
+	return workspace, json.NewDecoder(res.Body).Decode(&workspace)
+}
+
+// BatchWorkspaceOperation is an operation run on each workspace of a batch.
+type BatchWorkspaceOperation string
+
+const (
+	BatchWorkspaceOperationStart BatchWorkspaceOperation = "start"
+	BatchWorkspaceOperationStop  BatchWorkspaceOperation = "stop"
+	// BatchWorkspaceOperationUpdate starts workspaces on the active version
+	// of their template.
+	BatchWorkspaceOperationUpdate BatchWorkspaceOperation = "update"
+	BatchWorkspaceOperationDelete BatchWorkspaceOperation = "delete"
+)
+
+// BatchWorkspacesRequest runs an operation on all workspaces that match a
+// search query.
+type BatchWorkspacesRequest struct {
+	Operation BatchWorkspaceOperation `json:"operation" validate:"oneof=start stop update delete,required" enums:"start,stop,update,delete"`
+	// Query is a workspace search query, e.g.
+	// "template:docker last_used_before:2023-06-01". It is required, so that
+	// an empty query doesn't select every workspace.
+	Query string `json:"q" validate:"required"`
+}
+
+// BatchWorkspaceResultStatus is the outcome of the operation of a batch on a
+// workspace.
+type BatchWorkspaceResultStatus string
+
+const (
+	// BatchWorkspaceResultStatusPending means the operation has not run on
+	// the workspace yet.
+	BatchWorkspaceResultStatusPending BatchWorkspaceResultStatus = "pending"
+	// BatchWorkspaceResultStatusQueued means a build was queued for the
+	// workspace.
+	BatchWorkspaceResultStatusQueued BatchWorkspaceResultStatus = "queued"
+	// BatchWorkspaceResultStatusSkipped means the workspace is already in
+	// the requested state, or already on the active version for updates.
+	BatchWorkspaceResultStatusSkipped BatchWorkspaceResultStatus = "skipped"
+	BatchWorkspaceResultStatusFailed  BatchWorkspaceResultStatus = "failed"
+)
+
+// BatchWorkspaceResult is the outcome of the operation of a batch on a
+// workspace.
+type BatchWorkspaceResult struct {
+	WorkspaceID        uuid.UUID                  `json:"workspace_id" format:"uuid"`
+	WorkspaceName      string                     `json:"workspace_name"`
+	WorkspaceOwnerName string                     `json:"workspace_owner_name"`
+	Status             BatchWorkspaceResultStatus `json:"status" enums:"pending,queued,skipped,failed"`
+	// BuildID is the ID of the build queued for the workspace.
+	BuildID *uuid.UUID `json:"build_id,omitempty" format:"uuid"`
+	// Error is why the operation failed.
+	Error string `json:"error,omitempty"`
+}
+
+type WorkspaceBatchStatus string
+
+const (
+	WorkspaceBatchStatusPending   WorkspaceBatchStatus = "pending"
+	WorkspaceBatchStatusRunning   WorkspaceBatchStatus = "running"
+	WorkspaceBatchStatusCompleted WorkspaceBatchStatus = "completed"
+	// WorkspaceBatchStatusFailed means the batch could not run at all, e.g.
+	// because the user who queued it was suspended. Failures on single
+	// workspaces are reported in their results instead.
+	WorkspaceBatchStatusFailed WorkspaceBatchStatus = "failed"
+)
+
+// WorkspaceBatch is an operation on a batch of workspaces that runs in the
+// background. The workspaces that matched the query when the batch was queued
+// are processed in order at a rate limit, and the result of each is recorded
+// as it completes. Batches are kept for a day after they complete.
+type WorkspaceBatch struct {
+	ID          uuid.UUID               `json:"id" format:"uuid"`
+	Operation   BatchWorkspaceOperation `json:"operation" enums:"start,stop,update,delete"`
+	Query       string                  `json:"q"`
+	Status      WorkspaceBatchStatus    `json:"status" enums:"pending,running,completed,failed"`
+	Error       string                  `json:"error,omitempty"`
+	CreatedAt   time.Time               `json:"created_at" format:"date-time"`
+	UpdatedAt   time.Time               `json:"updated_at" format:"date-time"`
+	CompletedAt *time.Time              `json:"completed_at,omitempty" format:"date-time"`
+	// Results are in the order the workspaces are processed in.
+	Results []BatchWorkspaceResult `json:"results"`
+}
+
+// BatchWorkspaces queues an operation on all workspaces that match the query
+// of the request. Use WorkspaceBatch to follow its progress.
+func (c *Client) BatchWorkspaces(ctx context.Context, req BatchWorkspacesRequest) (WorkspaceBatch, error) {
+	res, err := c.Request(ctx, http.MethodPost, "/api/v2/workspaces/batch", req)
+	if err != nil {
+		return WorkspaceBatch{}, xerrors.Errorf("batch workspaces: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return WorkspaceBatch{}, ReadBodyAsError(res)
+	}
+	var batch WorkspaceBatch
+	return batch, json.NewDecoder(res.Body).Decode(&batch)
+}
+
+// WorkspaceBatch returns the progress and results of a batch queued with
+// BatchWorkspaces.
+func (c *Client) WorkspaceBatch(ctx context.Context, id uuid.UUID) (WorkspaceBatch, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/batch/%s", id), nil)
+	if err != nil {
+		return WorkspaceBatch{}, xerrors.Errorf("get workspace batch: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceBatch{}, ReadBodyAsError(res)
+	}
+	var batch WorkspaceBatch
+	return batch, json.NewDecoder(res.Body).Decode(&batch)
+}
+
 // UpdateWorkspaceLock is a request to lock or unlock a workspace.
 type UpdateWorkspaceLock struct {
 	Lock bool `json:"lock"`
@@ -300,10 +516,19 @@ type UpdateWorkspaceLock struct {
 
 // UpdateWorkspaceLock locks or unlocks a workspace.
 func (c *Client) UpdateWorkspaceLock(ctx context.Context, id uuid.UUID, req UpdateWorkspaceLock) error {
+	if !req.Lock {
+		return c.UnlockWorkspace(ctx, id)
+	}
+	return c.LockWorkspace(ctx, id)
+}
+
+// LockWorkspace locks a workspace. Locked workspaces can't be started, and
+// are deleted after the template's locked TTL.
+func (c *Client) LockWorkspace(ctx context.Context, id uuid.UUID) error {
 	path := fmt.Sprintf("/api/v2/workspaces/%s/lock", id.String())
-	res, err := c.Request(ctx, http.MethodPut, path, req)
+	res, err := c.Request(ctx, http.MethodPut, path, nil)
 	if err != nil {
-		return xerrors.Errorf("update workspace lock: %w", err)
+		return xerrors.Errorf("lock workspace: %w", err)
 	}
 	defer res.Body.Close()
 	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotModified {
@@ -312,6 +537,141 @@ func (c *Client) UpdateWorkspaceLock(ctx context.Context, id uuid.UUID, req Upda
 	return nil
 }
 
+// UnlockWorkspace unlocks a workspace.
+func (c *Client) UnlockWorkspace(ctx context.Context, id uuid.UUID) error {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/unlock", id.String())
+	res, err := c.Request(ctx, http.MethodPut, path, nil)
+	if err != nil {
+		return xerrors.Errorf("unlock workspace: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotModified {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// WorkspaceScheduleOverride overrides the scheduling options of a workspace's
+// template for that workspace only.
+type WorkspaceScheduleOverride struct {
+	WorkspaceID        uuid.UUID                  `json:"workspace_id" format:"uuid"`
+	CreatedAt          time.Time                  `json:"created_at" format:"date-time"`
+	UpdatedAt          time.Time                  `json:"updated_at" format:"date-time"`
+	DefaultTTLMillis   int64                      `json:"default_ttl_ms"`
+	MaxTTLMillis       int64                      `json:"max_ttl_ms"`
+	RestartRequirement TemplateRestartRequirement `json:"restart_requirement"`
+}
+
+// UpdateWorkspaceScheduleOverrideRequest is a request to set the schedule
+// override of a workspace. The override supersedes the template's values for
+// all fields.
+type UpdateWorkspaceScheduleOverrideRequest struct {
+	DefaultTTLMillis   int64                      `json:"default_ttl_ms"`
+	MaxTTLMillis       int64                      `json:"max_ttl_ms"`
+	RestartRequirement TemplateRestartRequirement `json:"restart_requirement"`
+}
+
+// WorkspaceScheduleOverride returns the schedule override of a workspace.
+func (c *Client) WorkspaceScheduleOverride(ctx context.Context, id uuid.UUID) (WorkspaceScheduleOverride, error) {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/schedule-override", id.String())
+	res, err := c.Request(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return WorkspaceScheduleOverride{}, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceScheduleOverride{}, ReadBodyAsError(res)
+	}
+	var override WorkspaceScheduleOverride
+	return override, json.NewDecoder(res.Body).Decode(&override)
+}
+
+// UpdateWorkspaceScheduleOverride sets the schedule override of a workspace.
+func (c *Client) UpdateWorkspaceScheduleOverride(ctx context.Context, id uuid.UUID, req UpdateWorkspaceScheduleOverrideRequest) (WorkspaceScheduleOverride, error) {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/schedule-override", id.String())
+	res, err := c.Request(ctx, http.MethodPut, path, req)
+	if err != nil {
+		return WorkspaceScheduleOverride{}, xerrors.Errorf("update workspace schedule override: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceScheduleOverride{}, ReadBodyAsError(res)
+	}
+	var override WorkspaceScheduleOverride
+	return override, json.NewDecoder(res.Body).Decode(&override)
+}
+
+// DeleteWorkspaceScheduleOverride removes the schedule override of a
+// workspace, reverting it to the template's scheduling options.
+func (c *Client) DeleteWorkspaceScheduleOverride(ctx context.Context, id uuid.UUID) error {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/schedule-override", id.String())
+	res, err := c.Request(ctx, http.MethodDelete, path, nil)
+	if err != nil {
+		return xerrors.Errorf("delete workspace schedule override: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// WorkspaceRole is the access to a workspace a group is given when the
+// workspace is shared with it.
+type WorkspaceRole string
+
+const (
+	// WorkspaceRoleRead allows viewing the workspace and using its apps.
+	WorkspaceRoleRead WorkspaceRole = "read"
+	// WorkspaceRoleFull also allows connecting to the workspace agent, e.g.
+	// with SSH or the web terminal.
+	WorkspaceRoleFull    WorkspaceRole = "full"
+	WorkspaceRoleDeleted WorkspaceRole = ""
+)
+
+// WorkspaceACL is the list of groups a workspace is shared with.
+type WorkspaceACL struct {
+	Groups []WorkspaceGroup `json:"groups"`
+}
+
+type WorkspaceGroup struct {
+	Group
+	Role WorkspaceRole `json:"role" enums:"read,full"`
+}
+
+type UpdateWorkspaceACL struct {
+	// GroupPerms should be a mapping of group id to role. An empty role stops
+	// sharing the workspace with the group.
+	GroupPerms map[string]WorkspaceRole `json:"group_perms,omitempty" example:"8bd26b20-f3e8-48be-a903-46bb920cf671:read"`
+}
+
+// WorkspaceACL returns the groups a workspace is shared with.
+func (c *Client) WorkspaceACL(ctx context.Context, id uuid.UUID) (WorkspaceACL, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/acl", id), nil)
+	if err != nil {
+		return WorkspaceACL{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceACL{}, ReadBodyAsError(res)
+	}
+	var acl WorkspaceACL
+	return acl, json.NewDecoder(res.Body).Decode(&acl)
+}
+
+// UpdateWorkspaceACL shares a workspace with groups, or stops sharing it.
+func (c *Client) UpdateWorkspaceACL(ctx context.Context, id uuid.UUID, req UpdateWorkspaceACL) error {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/workspaces/%s/acl", id), req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
 type WorkspaceFilter struct {
 	// Owner can be "me" or a username
 	Owner string `json:"owner,omitempty" typescript:"-"`
@@ -417,9 +777,46 @@ func (c *Client) WorkspaceQuota(ctx context.Context, userID string) (WorkspaceQu
 	return quota, json.NewDecoder(res.Body).Decode(&quota)
 }
 
+// UserQuota is the quota consumption of a user broken down by workspace.
+type UserQuota struct {
+	CreditsConsumed int `json:"credits_consumed"`
+	// Budget is -1 when quotas are not licensed.
+	Budget     int                   `json:"budget"`
+	Workspaces []WorkspaceQuotaUsage `json:"workspaces"`
+}
+
+// WorkspaceQuotaUsage is the daily cost of the latest build of a workspace.
+type WorkspaceQuotaUsage struct {
+	WorkspaceID   uuid.UUID           `json:"workspace_id" format:"uuid"`
+	WorkspaceName string              `json:"workspace_name"`
+	TemplateID    uuid.UUID           `json:"template_id" format:"uuid"`
+	TemplateName  string              `json:"template_name"`
+	Transition    WorkspaceTransition `json:"transition" enums:"start,stop,delete,archive"`
+	DailyCost     int32               `json:"daily_cost"`
+}
+
+// UserQuota returns the quota consumption of a user broken down by workspace.
+func (c *Client) UserQuota(ctx context.Context, user string) (UserQuota, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/quota", user), nil)
+	if err != nil {
+		return UserQuota{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return UserQuota{}, ReadBodyAsError(res)
+	}
+	var quota UserQuota
+	return quota, json.NewDecoder(res.Body).Decode(&quota)
+}
+
 // WorkspaceNotifyChannel is the PostgreSQL NOTIFY
 // channel to listen for updates on. The payload is empty,
 // because the size of a workspace payload can be very large.
 func WorkspaceNotifyChannel(id uuid.UUID) string {
 	return fmt.Sprintf("workspace:%s", id)
 }
+
+// WorkspacesNotifyChannel is the PostgreSQL NOTIFY channel to listen
+// for updates of all workspaces on. The payload is the ID of the
+// workspace that changed.
+const WorkspacesNotifyChannel = "workspaces"
@@ -0,0 +1,608 @@
+package backends
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/enterprise/audit"
+	"github.com/coder/retry"
+)
+
+// SinkType is the kind of endpoint a Sink streams audit logs to.
+type SinkType string
+
+const (
+	// SinkTypeHTTP sends batches of audit logs as a JSON array.
+	SinkTypeHTTP SinkType = "http"
+	// SinkTypeWebhook sends batches like SinkTypeHTTP, and signs them like
+	// workspace webhooks.
+	SinkTypeWebhook SinkType = "webhook"
+	// SinkTypeSplunk sends audit logs to a Splunk HTTP Event Collector.
+	SinkTypeSplunk SinkType = "splunk"
+	// SinkTypeKafka produces audit logs to a Kafka topic through a Kafka
+	// REST Proxy.
+	SinkTypeKafka SinkType = "kafka"
+)
+
+const (
+	defaultSinkBatchSize     = 100
+	defaultSinkPollInterval  = 10 * time.Second
+	defaultSinkExportDelay   = 5 * time.Second
+	defaultSinkRetryInterval = time.Second
+	defaultSinkFlushTimeout  = 10 * time.Second
+	maxSinkRetryInterval     = time.Minute
+	// sinkLeaseDuration is how long a sink may deliver a batch before
+	// another replica may deliver it instead. It must be longer than a
+	// delivery attempt.
+	sinkLeaseDuration = 2 * time.Minute
+	sinkSendTimeout   = 30 * time.Second
+)
+
+// SinkEvent is the JSON representation of an audit log sent to a sink.
+type SinkEvent struct {
+	ID               uuid.UUID       `json:"id"`
+	Time             time.Time       `json:"time"`
+	UserID           uuid.UUID       `json:"user_id"`
+	OrganizationID   uuid.UUID       `json:"organization_id"`
+	IP               string          `json:"ip"`
+	UserAgent        string          `json:"user_agent"`
+	ResourceType     string          `json:"resource_type"`
+	ResourceID       uuid.UUID       `json:"resource_id"`
+	ResourceTarget   string          `json:"resource_target"`
+	ResourceIcon     string          `json:"resource_icon"`
+	Action           string          `json:"action"`
+	Diff             json.RawMessage `json:"diff"`
+	StatusCode       int32           `json:"status_code"`
+	AdditionalFields json.RawMessage `json:"additional_fields"`
+	RequestID        uuid.UUID       `json:"request_id"`
+}
+
+// SinkOptions configures a Sink.
+type SinkOptions struct {
+	Type SinkType
+	// URL is the endpoint audit logs are sent to. For SinkTypeKafka, it's
+	// the URL of the REST Proxy.
+	URL string
+	// Token authenticates requests. It's sent as a bearer token for
+	// SinkTypeHTTP and SinkTypeKafka, as the HEC token for SinkTypeSplunk,
+	// and used as the signing secret for SinkTypeWebhook.
+	Token string
+	// KafkaTopic is the topic audit logs are produced to by SinkTypeKafka.
+	KafkaTopic string
+	// BatchSize is the maximum number of audit logs per request. Defaults to
+	// 100.
+	BatchSize int
+	// PollInterval is how often the database is checked for audit logs to
+	// export, in addition to every time an audit log is exported. Defaults
+	// to 10 seconds.
+	PollInterval time.Duration
+	// ExportDelay is how old audit logs must be before they're exported.
+	// Audit logs are exported in the order of their timestamps, so the delay
+	// keeps audit logs that are committed late, or by a replica with a
+	// skewed clock, from being skipped. Defaults to 5 seconds.
+	ExportDelay time.Duration
+	// RetryInterval is the initial delay between attempts, it doubles after
+	// every attempt up to one minute. Defaults to one second.
+	RetryInterval time.Duration
+	// FlushTimeout is how long Close waits for an in-flight batch to be
+	// delivered. Defaults to 10 seconds.
+	FlushTimeout time.Duration
+	// Client is used to send requests. Defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// Sink is a Backend that streams audit logs to an external endpoint. Audit
+// logs are read from the database after a cursor that is persisted per sink,
+// and sent in batches by a background worker. The cursor only moves once a
+// batch is delivered, and batches are retried until the endpoint accepts
+// them, so audit logs are delivered at least once, including audit logs
+// created while the sink was unavailable or Coder was restarted. Exporting an
+// audit log never blocks the request that created it.
+//
+// Every replica runs a worker, and the worker that reads a batch leases the
+// cursor until the batch is delivered, so replicas don't deliver the same
+// audit logs.
+type Sink struct {
+	log    slog.Logger
+	db     database.Store
+	opts   SinkOptions
+	encode func(batch []SinkEvent) (*sinkRequest, error)
+	// name identifies the cursor of the sink.
+	name string
+	// id identifies the leases of this sink.
+	id uuid.UUID
+	// start is where a new cursor starts, so configuring a sink doesn't
+	// export every audit log in the database.
+	start time.Time
+
+	notify    chan struct{}
+	closing   chan struct{}
+	closeOnce sync.Once
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+type sinkRequest struct {
+	url         string
+	contentType string
+	body        []byte
+	header      http.Header
+}
+
+// NewSink returns a Sink that streams the audit logs in db to the configured
+// endpoint. The Sink must be closed to stop the worker.
+func NewSink(logger slog.Logger, db database.Store, opts SinkOptions) (*Sink, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, xerrors.Errorf("parse audit export URL %q: %w", opts.URL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, xerrors.Errorf("audit export URL must be an http or https URL, got %q", opts.URL)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultSinkBatchSize
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultSinkPollInterval
+	}
+	if opts.ExportDelay <= 0 {
+		opts.ExportDelay = defaultSinkExportDelay
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = defaultSinkRetryInterval
+	}
+	if opts.FlushTimeout <= 0 {
+		opts.FlushTimeout = defaultSinkFlushTimeout
+	}
+	if opts.Client == nil {
+		opts.Client = http.DefaultClient
+	}
+
+	ctx, cancel := context.WithCancel(dbauthz.AsSystemRestricted(context.Background()))
+	s := &Sink{
+		log:     logger,
+		db:      db,
+		opts:    opts,
+		name:    fmt.Sprintf("%s %s", opts.Type, opts.URL),
+		id:      uuid.New(),
+		start:   database.Now(),
+		notify:  make(chan struct{}, 1),
+		closing: make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	switch opts.Type {
+	case SinkTypeHTTP:
+		s.encode = s.encodeHTTP
+	case SinkTypeWebhook:
+		s.encode = s.encodeWebhook
+	case SinkTypeSplunk:
+		s.encode = s.encodeSplunk
+	case SinkTypeKafka:
+		if opts.KafkaTopic == "" {
+			cancel()
+			return nil, xerrors.New("a kafka topic is required for the kafka audit export sink")
+		}
+		s.encode = s.encodeKafka
+		s.name += " " + opts.KafkaTopic
+	default:
+		cancel()
+		return nil, xerrors.Errorf("unknown audit export sink %q", opts.Type)
+	}
+
+	go s.worker()
+	return s, nil
+}
+
+func (*Sink) Decision() audit.FilterDecision {
+	return audit.FilterDecisionExport
+}
+
+// Export wakes the worker. The audit log itself is read from the database
+// once it's older than the export delay, so Export never blocks.
+func (s *Sink) Export(_ context.Context, _ database.AuditLog) error {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Close stops the worker, waiting up to the flush timeout for an in-flight
+// batch to be delivered. Audit logs that haven't been delivered stay in the
+// database and are exported by another replica, or once Coder restarts.
+func (s *Sink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.closing)
+	})
+
+	timer := time.NewTimer(s.opts.FlushTimeout)
+	defer timer.Stop()
+	select {
+	case <-s.done:
+	case <-timer.C:
+		s.cancel()
+		<-s.done
+	}
+	s.cancel()
+	return nil
+}
+
+func (s *Sink) worker() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	wait := func(ctx context.Context) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-s.notify:
+			return true
+		case <-ticker.C:
+			return true
+		}
+	}
+	// Retries stop once the sink is closing, while an in-flight batch may
+	// still be delivered until the flush timeout.
+	retryCtx, cancel := context.WithCancel(s.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.closing:
+			cancel()
+		case <-retryCtx.Done():
+		}
+	}()
+
+	for wait(retryCtx) {
+		s.export(retryCtx)
+	}
+}
+
+// export delivers batches until every audit log older than the export delay
+// is delivered, or another replica holds the lease.
+func (s *Sink) export(retryCtx context.Context) {
+	r := retry.New(s.opts.RetryInterval, maxSinkRetryInterval)
+	for retryCtx.Err() == nil {
+		more, err := s.exportBatch(s.ctx)
+		if err != nil {
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.log.Warn(s.ctx, "export audit logs failed, retrying", slog.F("sink", s.opts.Type), slog.Error(err))
+			if !r.Wait(retryCtx) {
+				return
+			}
+			continue
+		}
+		if !more {
+			return
+		}
+		r.Reset()
+	}
+}
+
+// exportBatch delivers the next batch of audit logs after the cursor and
+// moves the cursor past it. It returns whether there may be more audit logs
+// to export.
+func (s *Sink) exportBatch(ctx context.Context) (bool, error) {
+	// The batch is delivered outside of a transaction, so the cursor is
+	// leased while it's delivered.
+	cursor, logs, err := s.leaseBatch(ctx)
+	if err != nil {
+		return false, err
+	}
+	if len(logs) == 0 {
+		return false, nil
+	}
+
+	batch := make([]SinkEvent, 0, len(logs))
+	for _, alog := range logs {
+		batch = append(batch, NewSinkEvent(alog))
+	}
+	logger := s.log.With(slog.F("sink", s.opts.Type), slog.F("count", len(batch)))
+	req, err := s.encode(batch)
+	if err != nil {
+		logger.Error(ctx, "encode audit logs", slog.Error(err))
+	} else {
+		retryable, err := s.send(ctx, req)
+		if err != nil {
+			if retryable {
+				// Release the lease without moving the cursor, so the batch
+				// is retried by whichever replica gets to it first.
+				if rerr := s.releaseCursor(ctx, cursor); rerr != nil {
+					logger.Warn(ctx, "release audit export cursor", slog.Error(rerr))
+				}
+				return false, err
+			}
+			// The sink will never accept the batch, retrying would block
+			// every other audit log.
+			logger.Error(ctx, "audit export sink rejected audit logs", slog.Error(err))
+		} else {
+			logger.Debug(ctx, "exported audit logs")
+		}
+	}
+
+	last := logs[len(logs)-1]
+	cursor.LastTime = last.Time
+	cursor.LastID = last.ID
+	err = s.releaseCursor(ctx, cursor)
+	if err != nil {
+		return false, err
+	}
+	return len(logs) == s.opts.BatchSize, nil
+}
+
+// leaseBatch returns the cursor and the audit logs after it, and leases the
+// cursor to this sink. It returns no audit logs if there are none to export,
+// or another sink holds the lease.
+func (s *Sink) leaseBatch(ctx context.Context) (database.AuditLogExportCursor, []database.AuditLog, error) {
+	var (
+		cursor database.AuditLogExportCursor
+		logs   []database.AuditLog
+	)
+	err := s.db.InTx(func(db database.Store) error {
+		locked, err := db.TryAcquireLock(ctx, database.LockIDAuditLogExport)
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !locked {
+			return nil
+		}
+
+		now := database.Now()
+		cursor, err = db.GetAuditLogExportCursor(ctx, s.name)
+		isNew := errors.Is(err, sql.ErrNoRows)
+		if err != nil && !isNew {
+			return xerrors.Errorf("get audit export cursor: %w", err)
+		}
+		if isNew {
+			cursor = database.AuditLogExportCursor{
+				Sink:     s.name,
+				LastTime: s.start,
+			}
+		}
+		if cursor.LeasedBy != s.id && cursor.LeasedUntil.After(now) {
+			return nil
+		}
+
+		logs, err = db.GetAuditLogsAfterCursor(ctx, database.GetAuditLogsAfterCursorParams{
+			AfterTime:     cursor.LastTime,
+			AfterID:       cursor.LastID,
+			CreatedBefore: now.Add(-s.opts.ExportDelay),
+			LimitCount:    int32(s.opts.BatchSize),
+		})
+		if err != nil {
+			return xerrors.Errorf("get audit logs: %w", err)
+		}
+
+		leasedUntil := now
+		if len(logs) > 0 {
+			leasedUntil = now.Add(sinkLeaseDuration)
+		} else if !isNew {
+			return nil
+		}
+		// New cursors are saved even if there's nothing to export, so audit
+		// logs created before a restart aren't skipped.
+		cursor.LeasedBy = s.id
+		cursor.LeasedUntil = leasedUntil
+		cursor.UpdatedAt = now
+		err = db.UpsertAuditLogExportCursor(ctx, database.UpsertAuditLogExportCursorParams(cursor))
+		if err != nil {
+			return xerrors.Errorf("lease audit export cursor: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return database.AuditLogExportCursor{}, nil, err
+	}
+	return cursor, logs, nil
+}
+
+// releaseCursor saves the position of the cursor and releases the lease. If
+// the lease expired and another sink took it over, the cursor is left alone,
+// and the batch may be delivered again.
+func (s *Sink) releaseCursor(ctx context.Context, cursor database.AuditLogExportCursor) error {
+	return s.db.InTx(func(db database.Store) error {
+		// Wait for replicas that are reading the cursor.
+		err := db.AcquireLock(ctx, database.LockIDAuditLogExport)
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		current, err := db.GetAuditLogExportCursor(ctx, s.name)
+		if err != nil {
+			return xerrors.Errorf("get audit export cursor: %w", err)
+		}
+		if current.LeasedBy != s.id {
+			s.log.Warn(ctx, "audit export lease expired while delivering audit logs", slog.F("sink", s.opts.Type))
+			return nil
+		}
+
+		now := database.Now()
+		cursor.LeasedBy = uuid.Nil
+		cursor.LeasedUntil = now
+		cursor.UpdatedAt = now
+		err = db.UpsertAuditLogExportCursor(ctx, database.UpsertAuditLogExportCursorParams(cursor))
+		if err != nil {
+			return xerrors.Errorf("update audit export cursor: %w", err)
+		}
+		return nil
+	}, nil)
+}
+
+// send makes a single delivery attempt. It returns whether the attempt should
+// be retried if it failed.
+func (s *Sink) send(ctx context.Context, sreq *sinkRequest) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, sinkSendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sreq.url, bytes.NewReader(sreq.body))
+	if err != nil {
+		return false, xerrors.Errorf("create request: %w", err)
+	}
+	for k, v := range sreq.header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", sreq.contentType)
+	req.Header.Set("User-Agent", "Coder-Audit-Export")
+
+	res, err := s.opts.Client.Do(req)
+	if err != nil {
+		return true, xerrors.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1<<20))
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return false, nil
+	case res.StatusCode == http.StatusRequestTimeout || res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+		return true, xerrors.Errorf("unexpected status code %d", res.StatusCode)
+	default:
+		return false, xerrors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+}
+
+func (s *Sink) encodeHTTP(batch []SinkEvent) (*sinkRequest, error) {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	if s.opts.Token != "" {
+		header.Set("Authorization", "Bearer "+s.opts.Token)
+	}
+	return &sinkRequest{
+		url:         s.opts.URL,
+		contentType: "application/json",
+		body:        body,
+		header:      header,
+	}, nil
+}
+
+func (s *Sink) encodeWebhook(batch []SinkEvent) (*sinkRequest, error) {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	// The delivery ID is the same for every attempt, so receivers can
+	// deduplicate retries.
+	header.Set(webhooks.HeaderDelivery, uuid.NewString())
+	if s.opts.Token != "" {
+		header.Set(webhooks.HeaderSignature, webhooks.Sign(s.opts.Token, body))
+	}
+	return &sinkRequest{
+		url:         s.opts.URL,
+		contentType: "application/json",
+		body:        body,
+		header:      header,
+	}, nil
+}
+
+// splunkEvent is the envelope of an event sent to the HTTP Event Collector.
+type splunkEvent struct {
+	Time       float64   `json:"time"`
+	Source     string    `json:"source"`
+	SourceType string    `json:"sourcetype"`
+	Event      SinkEvent `json:"event"`
+}
+
+func (s *Sink) encodeSplunk(batch []SinkEvent) (*sinkRequest, error) {
+	// The collector accepts multiple concatenated events in one request.
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, event := range batch {
+		err := enc.Encode(splunkEvent{
+			Time:       float64(event.Time.UnixMilli()) / 1000,
+			Source:     "coder",
+			SourceType: "coder:audit",
+			Event:      event,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	header := http.Header{}
+	header.Set("Authorization", "Splunk "+s.opts.Token)
+	return &sinkRequest{
+		url:         s.opts.URL,
+		contentType: "application/json",
+		body:        body.Bytes(),
+		header:      header,
+	}, nil
+}
+
+type kafkaRecord struct {
+	Key   string    `json:"key"`
+	Value SinkEvent `json:"value"`
+}
+
+func (s *Sink) encodeKafka(batch []SinkEvent) (*sinkRequest, error) {
+	records := make([]kafkaRecord, 0, len(batch))
+	for _, event := range batch {
+		records = append(records, kafkaRecord{
+			Key:   event.ID.String(),
+			Value: event,
+		})
+	}
+	body, err := json.Marshal(map[string]any{"records": records})
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	if s.opts.Token != "" {
+		header.Set("Authorization", "Bearer "+s.opts.Token)
+	}
+	return &sinkRequest{
+		url:         fmt.Sprintf("%s/topics/%s", strings.TrimSuffix(s.opts.URL, "/"), url.PathEscape(s.opts.KafkaTopic)),
+		contentType: "application/vnd.kafka.json.v2+json",
+		body:        body,
+		header:      header,
+	}, nil
+}
+
+// NewSinkEvent converts an audit log to its JSON representation.
+func NewSinkEvent(alog database.AuditLog) SinkEvent {
+	var ip string
+	if alog.Ip.Valid {
+		ip = alog.Ip.IPNet.IP.String()
+	}
+	return SinkEvent{
+		ID:               alog.ID,
+		Time:             alog.Time,
+		UserID:           alog.UserID,
+		OrganizationID:   alog.OrganizationID,
+		IP:               ip,
+		UserAgent:        alog.UserAgent.String,
+		ResourceType:     string(alog.ResourceType),
+		ResourceID:       alog.ResourceID,
+		ResourceTarget:   alog.ResourceTarget,
+		ResourceIcon:     alog.ResourceIcon,
+		Action:           string(alog.Action),
+		Diff:             alog.Diff,
+		StatusCode:       alog.StatusCode,
+		AdditionalFields: alog.AdditionalFields,
+		RequestID:        alog.RequestID,
+	}
+}
@@ -0,0 +1,351 @@
+package backends_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/enterprise/audit/audittest"
+	"github.com/coder/coder/enterprise/audit/backends"
+	"github.com/coder/coder/testutil"
+)
+
+type sinkRequest struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+func newSinkServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request) bool) (*httptest.Server, chan sinkRequest) {
+	t.Helper()
+
+	requests := make(chan sinkRequest, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		if handler != nil && !handler(w, r) {
+			return
+		}
+		requests <- sinkRequest{path: r.URL.Path, header: r.Header, body: body}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+func recvSinkRequest(ctx context.Context, t *testing.T, requests <-chan sinkRequest) sinkRequest {
+	t.Helper()
+
+	select {
+	case req := <-requests:
+		return req
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for request")
+		return sinkRequest{}
+	}
+}
+
+func newTestSink(t *testing.T, logger slog.Logger, db database.Store, opts backends.SinkOptions) *backends.Sink {
+	t.Helper()
+
+	if opts.PollInterval == 0 {
+		opts.PollInterval = testutil.IntervalFast
+	}
+	if opts.ExportDelay == 0 {
+		opts.ExportDelay = time.Millisecond
+	}
+	sink, err := backends.NewSink(logger, db, opts)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sink.Close() })
+	return sink
+}
+
+// exportLog stores a random audit log like the postgres backend, and exports
+// it to the sink.
+func exportLog(ctx context.Context, t *testing.T, db database.Store, sink *backends.Sink) database.AuditLog {
+	t.Helper()
+
+	alog := audittest.RandomLog()
+	_, err := db.InsertAuditLog(ctx, database.InsertAuditLogParams(alog))
+	require.NoError(t, err)
+	require.NoError(t, sink.Export(ctx, alog))
+	return alog
+}
+
+func TestSinkBackend(t *testing.T) {
+	t.Parallel()
+
+	t.Run("HTTP", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		srv, requests := newSinkServer(t, nil)
+		db := dbfake.New()
+		sink := newTestSink(t, slogtest.Make(t, nil), db, backends.SinkOptions{
+			Type:  backends.SinkTypeHTTP,
+			URL:   srv.URL,
+			Token: "secret",
+		})
+
+		alog := exportLog(ctx, t, db, sink)
+
+		req := recvSinkRequest(ctx, t, requests)
+		require.Equal(t, "Bearer secret", req.header.Get("Authorization"))
+		var events []backends.SinkEvent
+		require.NoError(t, json.Unmarshal(req.body, &events))
+		require.Len(t, events, 1)
+		require.Equal(t, alog.ID, events[0].ID)
+		require.Equal(t, string(alog.Action), events[0].Action)
+		require.Equal(t, alog.ResourceTarget, events[0].ResourceTarget)
+	})
+
+	t.Run("Webhook", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		srv, requests := newSinkServer(t, nil)
+		db := dbfake.New()
+		sink := newTestSink(t, slogtest.Make(t, nil), db, backends.SinkOptions{
+			Type:  backends.SinkTypeWebhook,
+			URL:   srv.URL,
+			Token: "secret",
+		})
+
+		exportLog(ctx, t, db, sink)
+
+		req := recvSinkRequest(ctx, t, requests)
+		require.NotEmpty(t, req.header.Get(webhooks.HeaderDelivery))
+		require.True(t, webhooks.Verify("secret", req.body, req.header.Get(webhooks.HeaderSignature)))
+	})
+
+	t.Run("Splunk", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		srv, requests := newSinkServer(t, nil)
+		db := dbfake.New()
+		sink := newTestSink(t, slogtest.Make(t, nil), db, backends.SinkOptions{
+			Type:  backends.SinkTypeSplunk,
+			URL:   srv.URL,
+			Token: "hec-token",
+		})
+
+		alog := exportLog(ctx, t, db, sink)
+
+		req := recvSinkRequest(ctx, t, requests)
+		require.Equal(t, "Splunk hec-token", req.header.Get("Authorization"))
+		scanner := bufio.NewScanner(bytes.NewReader(req.body))
+		require.True(t, scanner.Scan())
+		var event struct {
+			SourceType string             `json:"sourcetype"`
+			Event      backends.SinkEvent `json:"event"`
+		}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		require.Equal(t, "coder:audit", event.SourceType)
+		require.Equal(t, alog.ID, event.Event.ID)
+	})
+
+	t.Run("Kafka", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		srv, requests := newSinkServer(t, nil)
+		db := dbfake.New()
+		sink := newTestSink(t, slogtest.Make(t, nil), db, backends.SinkOptions{
+			Type:       backends.SinkTypeKafka,
+			URL:        srv.URL,
+			KafkaTopic: "audit",
+		})
+
+		alog := exportLog(ctx, t, db, sink)
+
+		req := recvSinkRequest(ctx, t, requests)
+		require.Equal(t, "/topics/audit", req.path)
+		require.Equal(t, "application/vnd.kafka.json.v2+json", req.header.Get("Content-Type"))
+		var body struct {
+			Records []struct {
+				Key   string             `json:"key"`
+				Value backends.SinkEvent `json:"value"`
+			} `json:"records"`
+		}
+		require.NoError(t, json.Unmarshal(req.body, &body))
+		require.Len(t, body.Records, 1)
+		require.Equal(t, alog.ID.String(), body.Records[0].Key)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		var attempts atomic.Int32
+		srv, requests := newSinkServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+			if attempts.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return false
+			}
+			return true
+		})
+		db := dbfake.New()
+		sink := newTestSink(t, slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), db, backends.SinkOptions{
+			Type:          backends.SinkTypeHTTP,
+			URL:           srv.URL,
+			RetryInterval: time.Millisecond,
+		})
+
+		alog := exportLog(ctx, t, db, sink)
+
+		req := recvSinkRequest(ctx, t, requests)
+		var events []backends.SinkEvent
+		require.NoError(t, json.Unmarshal(req.body, &events))
+		require.Len(t, events, 1)
+		require.Equal(t, alog.ID, events[0].ID)
+		require.EqualValues(t, 3, attempts.Load())
+	})
+
+	t.Run("Batches", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		srv, requests := newSinkServer(t, nil)
+		db := dbfake.New()
+		sink := newTestSink(t, slogtest.Make(t, nil), db, backends.SinkOptions{
+			Type:      backends.SinkTypeHTTP,
+			URL:       srv.URL,
+			BatchSize: 2,
+		})
+
+		want := map[uuid.UUID]bool{}
+		for i := 0; i < 5; i++ {
+			want[exportLog(ctx, t, db, sink).ID] = true
+		}
+
+		got := map[uuid.UUID]bool{}
+		for len(got) < len(want) {
+			req := recvSinkRequest(ctx, t, requests)
+			var events []backends.SinkEvent
+			require.NoError(t, json.Unmarshal(req.body, &events))
+			require.LessOrEqual(t, len(events), 2)
+			for _, event := range events {
+				require.False(t, got[event.ID], "audit log exported twice")
+				got[event.ID] = true
+			}
+		}
+		require.Equal(t, want, got)
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		t.Parallel()
+
+		// Audit logs that weren't delivered before a sink was closed are
+		// delivered by the next sink with the same endpoint.
+		ctx := testutil.Context(t, testutil.WaitLong)
+		var (
+			available atomic.Bool
+			attempts  atomic.Int32
+		)
+		srv, requests := newSinkServer(t, func(w http.ResponseWriter, r *http.Request) bool {
+			attempts.Add(1)
+			if !available.Load() {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return false
+			}
+			return true
+		})
+		db := dbfake.New()
+		opts := backends.SinkOptions{
+			Type:          backends.SinkTypeHTTP,
+			URL:           srv.URL,
+			RetryInterval: time.Millisecond,
+		}
+		sink := newTestSink(t, slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}), db, opts)
+
+		alog := exportLog(ctx, t, db, sink)
+		require.Eventually(t, func() bool {
+			return attempts.Load() > 0
+		}, testutil.WaitShort, testutil.IntervalFast)
+		require.NoError(t, sink.Close())
+
+		available.Store(true)
+		sink = newTestSink(t, slogtest.Make(t, nil), db, opts)
+		require.NoError(t, sink.Export(ctx, database.AuditLog{}))
+
+		req := recvSinkRequest(ctx, t, requests)
+		var events []backends.SinkEvent
+		require.NoError(t, json.Unmarshal(req.body, &events))
+		require.Len(t, events, 1)
+		require.Equal(t, alog.ID, events[0].ID)
+	})
+
+	t.Run("Replicas", func(t *testing.T) {
+		t.Parallel()
+
+		// Sinks on different replicas share the cursor, so every audit log
+		// is delivered once.
+		ctx := testutil.Context(t, testutil.WaitLong)
+		srv, requests := newSinkServer(t, nil)
+		db := dbfake.New()
+		opts := backends.SinkOptions{
+			Type:      backends.SinkTypeHTTP,
+			URL:       srv.URL,
+			BatchSize: 1,
+		}
+		sinks := []*backends.Sink{
+			newTestSink(t, slogtest.Make(t, nil), db, opts),
+			newTestSink(t, slogtest.Make(t, nil), db, opts),
+		}
+
+		want := map[uuid.UUID]bool{}
+		for i := 0; i < 6; i++ {
+			want[exportLog(ctx, t, db, sinks[i%2]).ID] = true
+		}
+
+		got := map[uuid.UUID]bool{}
+		for len(got) < len(want) {
+			req := recvSinkRequest(ctx, t, requests)
+			var events []backends.SinkEvent
+			require.NoError(t, json.Unmarshal(req.body, &events))
+			for _, event := range events {
+				require.False(t, got[event.ID], "audit log exported twice")
+				got[event.ID] = true
+			}
+		}
+		require.Equal(t, want, got)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := backends.NewSink(slogtest.Make(t, nil), dbfake.New(), backends.SinkOptions{
+			Type: "syslog",
+			URL:  "http://localhost",
+		})
+		require.Error(t, err)
+
+		_, err = backends.NewSink(slogtest.Make(t, nil), dbfake.New(), backends.SinkOptions{
+			Type: backends.SinkTypeHTTP,
+			URL:  "localhost:8080",
+		})
+		require.Error(t, err)
+
+		_, err = backends.NewSink(slogtest.Make(t, nil), dbfake.New(), backends.SinkOptions{
+			Type: backends.SinkTypeKafka,
+			URL:  "http://localhost",
+		})
+		require.Error(t, err)
+	})
+}
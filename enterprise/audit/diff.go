@@ -142,7 +142,7 @@ func convertDiffType(left, right any) (newLeft, newRight any, changed bool) {
 		}
 
 		return leftInt64Ptr, rightInt64Ptr, true
-	case database.TemplateACL:
+	case database.TemplateACL, database.WorkspaceACL:
 		return fmt.Sprintf("%+v", left), fmt.Sprintf("%+v", right), true
 	default:
 		return left, right, false
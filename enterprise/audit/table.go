@@ -16,15 +16,20 @@ import (
 // AuditableResources map (below) as our documentation - generated in scripts/auditdocgen/main.go -
 // depends upon it.
 var AuditActionMap = map[string][]codersdk.AuditAction{
-	"GitSSHKey":       {codersdk.AuditActionCreate},
-	"Template":        {codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"TemplateVersion": {codersdk.AuditActionCreate, codersdk.AuditActionWrite},
-	"User":            {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"Workspace":       {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"WorkspaceBuild":  {codersdk.AuditActionStart, codersdk.AuditActionStop},
-	"Group":           {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"APIKey":          {codersdk.AuditActionLogin, codersdk.AuditActionLogout, codersdk.AuditActionRegister, codersdk.AuditActionCreate, codersdk.AuditActionDelete},
-	"License":         {codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"GitSSHKey":                    {codersdk.AuditActionCreate},
+	"Template":                     {codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"TemplateVersion":              {codersdk.AuditActionCreate, codersdk.AuditActionWrite},
+	"TemplateVersionPromotion":     {codersdk.AuditActionCreate, codersdk.AuditActionWrite},
+	"User":                         {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"Workspace":                    {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"WorkspaceBuild":               {codersdk.AuditActionStart, codersdk.AuditActionStop},
+	"Group":                        {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"APIKey":                       {codersdk.AuditActionLogin, codersdk.AuditActionLogout, codersdk.AuditActionRegister, codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"License":                      {codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"ProvisionerKey":               {codersdk.AuditActionCreate, codersdk.AuditActionDelete, codersdk.AuditActionLogin},
+	"TemplateParameterValidation":  {codersdk.AuditActionWrite},
+	"OrganizationTemplateVariable": {codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"CustomRole":                   {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
 }
 
 type Action string
@@ -73,6 +78,16 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"max_ttl":                          ActionTrack,
 		"restart_requirement_days_of_week": ActionTrack,
 		"restart_requirement_weeks":        ActionTrack,
+		"restart_requirement_timezone":     ActionTrack,
+		"restart_requirement_spread":       ActionTrack,
+		"update_on_restart":                ActionTrack,
+		"schedule_policy_template_id":      ActionTrack,
+		"failure_retries":                  ActionTrack,
+		"failure_retry_backoff":            ActionTrack,
+		"max_lifetime":                     ActionTrack,
+		"archive_retention":                ActionTrack,
+		"max_deadline_extensions_per_day":  ActionTrack,
+		"max_deadline_extension":           ActionTrack,
 		"created_by":                       ActionTrack,
 		"created_by_username":              ActionIgnore,
 		"created_by_avatar_url":            ActionIgnore,
@@ -84,6 +99,14 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"failure_ttl":                      ActionTrack,
 		"inactivity_ttl":                   ActionTrack,
 		"locked_ttl":                       ActionTrack,
+		"activity_bump_ttl":                ActionTrack,
+		"max_concurrent_builds":            ActionTrack,
+		"required_promotion_approvals":     ActionTrack,
+		"user_cpu_quota_millicores":        ActionTrack,
+		"user_memory_quota_bytes":          ActionTrack,
+		"user_disk_quota_bytes":            ActionTrack,
+		"apply_dotfiles":                   ActionTrack,
+		"disable_autostop":                 ActionTrack,
 	},
 	&database.TemplateVersion{}: {
 		"id":                    ActionTrack,
@@ -100,6 +123,16 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"created_by_avatar_url": ActionIgnore,
 		"created_by_username":   ActionIgnore,
 	},
+	&database.TemplateVersionPromotion{}: {
+		"id":                  ActionTrack,
+		"template_id":         ActionIgnore, // Never changes.
+		"template_version_id": ActionTrack,
+		"requested_by":        ActionTrack,
+		"created_at":          ActionIgnore, // Never changes.
+		"updated_at":          ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+		"status":              ActionTrack,
+		"approved_by":         ActionTrack,
+	},
 	&database.User{}: {
 		"id":                   ActionTrack,
 		"email":                ActionTrack,
@@ -129,6 +162,7 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"last_used_at":       ActionIgnore,
 		"locked_at":          ActionTrack,
 		"deleting_at":        ActionTrack,
+		"group_acl":          ActionTrack,
 	},
 	&database.WorkspaceBuild{}: {
 		"id":                      ActionIgnore,
@@ -189,19 +223,54 @@ var auditableResourcesTypes = map[any]map[string]Action{
 		"uuid":        ActionTrack,
 	},
 	&database.WorkspaceProxy{}: {
-		"id":                  ActionTrack,
-		"name":                ActionTrack,
-		"display_name":        ActionTrack,
-		"icon":                ActionTrack,
-		"url":                 ActionTrack,
-		"wildcard_hostname":   ActionTrack,
-		"created_at":          ActionTrack,
-		"updated_at":          ActionIgnore,
-		"deleted":             ActionIgnore,
-		"token_hashed_secret": ActionSecret,
-		"derp_enabled":        ActionTrack,
-		"derp_only":           ActionTrack,
-		"region_id":           ActionTrack,
+		"id":                           ActionTrack,
+		"name":                         ActionTrack,
+		"display_name":                 ActionTrack,
+		"icon":                         ActionTrack,
+		"url":                          ActionTrack,
+		"wildcard_hostname":            ActionTrack,
+		"created_at":                   ActionTrack,
+		"updated_at":                   ActionIgnore,
+		"deleted":                      ActionIgnore,
+		"token_hashed_secret":          ActionSecret,
+		"derp_enabled":                 ActionTrack,
+		"derp_only":                    ActionTrack,
+		"region_id":                    ActionTrack,
+		"token_expires_at":             ActionIgnore,
+		"previous_token_hashed_secret": ActionSecret,
+		"previous_token_expires_at":    ActionIgnore,
+	},
+	&database.ProvisionerKey{}: {
+		"id":              ActionTrack,
+		"created_at":      ActionIgnore, // Never changes.
+		"organization_id": ActionIgnore, // Never changes.
+		"name":            ActionTrack,
+		"hashed_secret":   ActionSecret,
+		"tags":            ActionTrack,
+		"last_used_at":    ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+	},
+	&database.TemplateParameterValidation{}: {
+		"template_id": ActionIgnore, // Never changes.
+		"webhook_url": ActionTrack,
+		"constraints": ActionTrack,
+		"created_at":  ActionIgnore, // Never changes.
+		"updated_at":  ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+	},
+	&database.OrganizationTemplateVariable{}: {
+		"organization_id": ActionIgnore, // Never changes.
+		"name":            ActionIgnore, // Never changes.
+		"value":           ActionSecret, // The value may be sensitive.
+		"sensitive":       ActionTrack,
+		"created_at":      ActionIgnore, // Never changes.
+		"updated_at":      ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+	},
+	&database.CustomRole{}: {
+		"name":             ActionTrack,
+		"display_name":     ActionTrack,
+		"site_permissions": ActionTrack,
+		"user_permissions": ActionTrack,
+		"created_at":       ActionIgnore, // Never changes.
+		"updated_at":       ActionIgnore, // Changes, but is implicit and not helpful in a diff.
 	},
 }
 
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"golang.org/x/xerrors"
@@ -15,9 +17,11 @@ import (
 	"github.com/coder/coder/cli/clibase"
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisioner/terraform"
 	"github.com/coder/coder/provisionerd"
+	provisionerdcache "github.com/coder/coder/provisionerd/cache"
 	provisionerdproto "github.com/coder/coder/provisionerd/proto"
 	"github.com/coder/coder/provisionersdk"
 	"github.com/coder/coder/provisionersdk/proto"
@@ -32,6 +36,7 @@ func (r *RootCmd) provisionerDaemons() *clibase.Cmd {
 		},
 		Children: []*clibase.Cmd{
 			r.provisionerDaemonStart(),
+			r.provisionerKeys(),
 		},
 	}
 
@@ -41,10 +46,13 @@ func (r *RootCmd) provisionerDaemons() *clibase.Cmd {
 func (r *RootCmd) provisionerDaemonStart() *clibase.Cmd {
 	var (
 		cacheDir     string
+		cacheMaxSize int64
 		rawTags      []string
+		rawPlugins   []string
 		pollInterval time.Duration
 		pollJitter   time.Duration
 		preSharedKey string
+		key          string
 	)
 	client := new(codersdk.Client)
 	cmd := &clibase.Cmd{
@@ -64,6 +72,10 @@ func (r *RootCmd) provisionerDaemonStart() *clibase.Cmd {
 			if err != nil {
 				return err
 			}
+			plugins, err := parseProvisionerPlugins(rawPlugins)
+			if err != nil {
+				return err
+			}
 
 			err = os.MkdirAll(cacheDir, 0o700)
 			if err != nil {
@@ -102,18 +114,47 @@ func (r *RootCmd) provisionerDaemonStart() *clibase.Cmd {
 				return err
 			}
 
-			logger.Info(ctx, "starting provisioner daemon", slog.F("tags", tags))
+			var providerCache *provisionerdcache.Cache
+			if cacheMaxSize > 0 {
+				providerCache, err = provisionerdcache.New(ctx, provisionerdcache.Options{
+					Logger:    logger.Named("cache"),
+					Directory: filepath.Join(cacheDir, "provisionerd-cache"),
+					MaxSize:   cacheMaxSize << 20,
+				})
+				if err != nil {
+					return xerrors.Errorf("create cache: %w", err)
+				}
+			}
 
 			provisioners := provisionerd.Provisioners{
 				string(database.ProvisionerTypeTerraform): proto.NewDRPCProvisionerClient(terraformClient),
 			}
+			provisionerTypes := []codersdk.ProvisionerType{
+				codersdk.ProvisionerTypeTerraform,
+			}
+			for name, path := range plugins {
+				plugin, err := provisionersdk.StartPlugin(ctx, &provisionersdk.PluginOptions{
+					Path:   path,
+					Stderr: inv.Stderr,
+				})
+				if err != nil {
+					return xerrors.Errorf("start provisioner plugin %q: %w", name, err)
+				}
+				defer func() {
+					_ = plugin.Close()
+				}()
+				provisioners[name] = plugin
+				provisionerTypes = append(provisionerTypes, codersdk.ProvisionerType(name))
+			}
+
+			logger.Info(ctx, "starting provisioner daemon", slog.F("tags", tags), slog.F("provisioners", provisionerTypes))
+
 			srv := provisionerd.New(func(ctx context.Context) (provisionerdproto.DRPCProvisionerDaemonClient, error) {
 				return client.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
-					Provisioners: []codersdk.ProvisionerType{
-						codersdk.ProvisionerTypeTerraform,
-					},
-					Tags:         tags,
-					PreSharedKey: preSharedKey,
+					Provisioners:   provisionerTypes,
+					Tags:           tags,
+					PreSharedKey:   preSharedKey,
+					ProvisionerKey: key,
 				})
 			}, &provisionerd.Options{
 				Logger:          logger,
@@ -122,6 +163,7 @@ func (r *RootCmd) provisionerDaemonStart() *clibase.Cmd {
 				UpdateInterval:  500 * time.Millisecond,
 				Provisioners:    provisioners,
 				WorkDirectory:   tempDir,
+				Cache:           providerCache,
 			})
 
 			var exitErr error
@@ -150,6 +192,12 @@ func (r *RootCmd) provisionerDaemonStart() *clibase.Cmd {
 		},
 	}
 
+	keyOption := clibase.Option{
+		Flag:        "key",
+		Env:         "CODER_PROVISIONER_DAEMON_KEY",
+		Description: "Provisioner key to authenticate with Coder server. Create one with \"coder provisionerd keys create\". The daemon gets the tags of the key.",
+		Value:       clibase.StringOf(&key),
+	}
 	cmd.Options = clibase.OptionSet{
 		{
 			Flag:          "cache-dir",
@@ -159,6 +207,13 @@ func (r *RootCmd) provisionerDaemonStart() *clibase.Cmd {
 			Default:       codersdk.DefaultCacheDir(),
 			Value:         clibase.StringOf(&cacheDir),
 		},
+		{
+			Flag:        "cache-max-size",
+			Env:         "CODER_PROVISIONERD_CACHE_MAX_SIZE",
+			Description: "Maximum size in MiB of the cache of Terraform providers and modules, so builds of the same template don't download them again. Set to 0 to disable the cache.",
+			Default:     "10240",
+			Value:       clibase.Int64Of(&cacheMaxSize),
+		},
 		{
 			Flag:          "tag",
 			FlagShorthand: "t",
@@ -166,6 +221,12 @@ func (r *RootCmd) provisionerDaemonStart() *clibase.Cmd {
 			Description:   "Tags to filter provisioner jobs by.",
 			Value:         clibase.StringArrayOf(&rawTags),
 		},
+		{
+			Flag:        "plugin",
+			Env:         "CODER_PROVISIONERD_PLUGINS",
+			Description: "Provisioner plugins to serve next to Terraform, as name=path pairs. Templates created with --provisioner=name run with the executable at path.",
+			Value:       clibase.StringArrayOf(&rawPlugins),
+		},
 		{
 			Flag:        "poll-interval",
 			Env:         "CODER_PROVISIONERD_POLL_INTERVAL",
@@ -180,13 +241,35 @@ func (r *RootCmd) provisionerDaemonStart() *clibase.Cmd {
 			Default:     (100 * time.Millisecond).String(),
 			Value:       clibase.DurationOf(&pollJitter),
 		},
+		keyOption,
 		{
 			Flag:        "psk",
 			Env:         "CODER_PROVISIONER_DAEMON_PSK",
 			Description: "Pre-shared key to authenticate with Coder server.",
 			Value:       clibase.StringOf(&preSharedKey),
+			UseInstead:  []clibase.Option{keyOption},
 		},
 	}
 
 	return cmd
 }
+
+// parseProvisionerPlugins parses name=path pairs of provisioner plugins.
+func parseProvisionerPlugins(rawPlugins []string) (map[string]string, error) {
+	plugins := map[string]string{}
+	for _, rawPlugin := range rawPlugins {
+		parts := strings.SplitN(rawPlugin, "=", 2)
+		if len(parts) < 2 || parts[1] == "" {
+			return nil, xerrors.Errorf("invalid plugin format for %q. must be name=path", rawPlugin)
+		}
+		err := httpapi.ProvisionerTypeValid(parts[0])
+		if err != nil {
+			return nil, xerrors.Errorf("invalid plugin name %q: %w", parts[0], err)
+		}
+		if parts[0] == string(codersdk.ProvisionerTypeTerraform) || parts[0] == string(codersdk.ProvisionerTypeEcho) {
+			return nil, xerrors.Errorf("plugin name %q is reserved", parts[0])
+		}
+		plugins[parts[0]] = parts[1]
+	}
+	return plugins, nil
+}
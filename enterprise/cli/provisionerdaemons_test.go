@@ -35,6 +35,31 @@ func TestProvisionerDaemon_PSK(t *testing.T) {
 	pty.ExpectMatchContext(ctx, "starting provisioner daemon")
 }
 
+func TestProvisionerDaemon_Key(t *testing.T) {
+	t.Parallel()
+
+	client, admin := coderdenttest.New(t, &coderdenttest.Options{
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureExternalProvisionerDaemons: 1,
+			},
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+	key, err := client.CreateProvisionerKey(ctx, admin.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+		Name: "ci",
+	})
+	require.NoError(t, err)
+
+	inv, conf := newCLI(t, "provisionerd", "start", "--key="+key.Key)
+	err = conf.URL().Write(client.URL.String())
+	require.NoError(t, err)
+	pty := ptytest.New(t).Attach(inv)
+	clitest.Start(t, inv)
+	pty.ExpectMatchContext(ctx, "starting provisioner daemon")
+}
+
 func TestProvisionerDaemon_SessionToken(t *testing.T) {
 	t.Parallel()
 
@@ -54,3 +79,15 @@ func TestProvisionerDaemon_SessionToken(t *testing.T) {
 	clitest.Start(t, inv)
 	pty.ExpectMatchContext(ctx, "starting provisioner daemon")
 }
+
+func TestProvisionerDaemon_InvalidPlugin(t *testing.T) {
+	t.Parallel()
+
+	inv, _ := newCLI(t, "provisionerd", "start", "--psk=provisionersftw", "--plugin", "terraform=/usr/local/bin/terraform")
+	err := inv.Run()
+	require.ErrorContains(t, err, `plugin name "terraform" is reserved`)
+
+	inv, _ = newCLI(t, "provisionerd", "start", "--psk=provisionersftw", "--plugin", "pulumi")
+	err = inv.Run()
+	require.ErrorContains(t, err, "must be name=path")
+}
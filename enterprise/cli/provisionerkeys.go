@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fatih/color"
+	"golang.org/x/xerrors"
+
+	agpl "github.com/coder/coder/cli"
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) provisionerKeys() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Use:     "keys",
+		Short:   "Manage provisioner keys",
+		Aliases: []string{"key"},
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.provisionerKeyCreate(),
+			r.provisionerKeyList(),
+			r.provisionerKeyDelete(),
+		},
+	}
+
+	return cmd
+}
+
+func (r *RootCmd) provisionerKeyCreate() *clibase.Cmd {
+	var rawTags []string
+
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "create <name>",
+		Short: "Create a provisioner key",
+		Long:  "Create a key that provisioner daemons authenticate with. The key is only printed once.",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx := inv.Context()
+
+			org, err := agpl.CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("current organization: %w", err)
+			}
+
+			tags, err := agpl.ParseProvisionerTags(rawTags)
+			if err != nil {
+				return err
+			}
+
+			res, err := client.CreateProvisionerKey(ctx, org.ID, codersdk.CreateProvisionerKeyRequest{
+				Name: inv.Args[0],
+				Tags: tags,
+			})
+			if err != nil {
+				return xerrors.Errorf("create provisioner key: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stderr, "Successfully created provisioner key %s! Start provisioner daemons with it:\n\n", cliui.DefaultStyles.Keyword.Render(res.ProvisionerKey.Name))
+			_, _ = fmt.Fprintln(inv.Stderr, color.HiMagentaString("  $ coder provisionerd start --key <key>\n"))
+			_, _ = fmt.Fprintln(inv.Stdout, res.Key)
+			return nil
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:          "tag",
+			FlagShorthand: "t",
+			Description:   "Tags of the provisioner daemons that authenticate with the key.",
+			Value:         clibase.StringArrayOf(&rawTags),
+		},
+	}
+
+	return cmd
+}
+
+func (r *RootCmd) provisionerKeyList() *clibase.Cmd {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]provisionerKeyTableRow{}, nil),
+		cliui.JSONFormat(),
+	)
+
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:     "list",
+		Short:   "List provisioner keys",
+		Aliases: []string{"ls"},
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(0),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx := inv.Context()
+
+			org, err := agpl.CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("current organization: %w", err)
+			}
+
+			keys, err := client.ProvisionerKeys(ctx, org.ID)
+			if err != nil {
+				return xerrors.Errorf("get provisioner keys: %w", err)
+			}
+
+			if len(keys) == 0 {
+				_, _ = fmt.Fprintf(inv.Stderr, "%s No provisioner keys found in %s! Create one:\n\n", agpl.Caret, color.HiWhiteString(org.Name))
+				_, _ = fmt.Fprintln(inv.Stderr, color.HiMagentaString("  $ coder provisionerd keys create <name>\n"))
+				return nil
+			}
+
+			out, err := formatter.Format(ctx, provisionerKeysToRows(keys...))
+			if err != nil {
+				return xerrors.Errorf("display provisioner keys: %w", err)
+			}
+
+			_, _ = fmt.Fprintln(inv.Stdout, out)
+			return nil
+		},
+	}
+
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
+func (r *RootCmd) provisionerKeyDelete() *clibase.Cmd {
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:     "delete <name>",
+		Short:   "Revoke a provisioner key",
+		Long:    "Revoke a provisioner key. Provisioner daemons that are connected with the key stay connected, but can't connect with it again.",
+		Aliases: []string{"rm", "revoke"},
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx := inv.Context()
+
+			org, err := agpl.CurrentOrganization(inv, client)
+			if err != nil {
+				return xerrors.Errorf("current organization: %w", err)
+			}
+
+			err = client.DeleteProvisionerKey(ctx, org.ID, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("delete provisioner key: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Successfully deleted provisioner key %s!\n", cliui.DefaultStyles.Keyword.Render(inv.Args[0]))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+type provisionerKeyTableRow struct {
+	// For json output:
+	ProvisionerKey codersdk.ProvisionerKey `table:"-"`
+
+	// For table output:
+	Name       string            `json:"-" table:"name,default_sort"`
+	Tags       map[string]string `json:"-" table:"tags"`
+	CreatedAt  time.Time         `json:"-" table:"created_at"`
+	LastUsedAt *time.Time        `json:"-" table:"last_used_at"`
+}
+
+func provisionerKeysToRows(keys ...codersdk.ProvisionerKey) []provisionerKeyTableRow {
+	rows := make([]provisionerKeyTableRow, 0, len(keys))
+	for _, key := range keys {
+		rows = append(rows, provisionerKeyTableRow{
+			ProvisionerKey: key,
+			Name:           key.Name,
+			Tags:           key.Tags,
+			CreatedAt:      key.CreatedAt,
+			LastUsedAt:     key.LastUsedAt,
+		})
+	}
+	return rows
+}
@@ -0,0 +1,65 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestProvisionerKeys(t *testing.T) {
+	t.Parallel()
+
+	client, admin := coderdenttest.New(t, &coderdenttest.Options{
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureExternalProvisionerDaemons: 1,
+			},
+		},
+	})
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	inv, conf := newCLI(t, "provisionerd", "keys", "create", "ci", "--tag", "environment=ci")
+	clitest.SetupConfig(t, client, conf)
+	var stdout bytes.Buffer
+	inv.Stdout = &stdout
+	err := inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	// Only the key is written to stdout, so it can be piped.
+	require.Regexp(t, `^[0-9a-f-]{36}:[0-9a-f]{64}$`, strings.TrimSpace(stdout.String()))
+
+	keys, err := client.ProvisionerKeys(ctx, admin.OrganizationID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	require.Equal(t, "ci", keys[0].Name)
+	require.Equal(t, "ci", keys[0].Tags["environment"])
+
+	inv, conf = newCLI(t, "provisionerd", "keys", "list")
+	clitest.SetupConfig(t, client, conf)
+	pty := ptytest.New(t)
+	inv.Stdout = pty.Output()
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	pty.ExpectMatch("NAME")
+	pty.ExpectMatch("ci")
+
+	inv, conf = newCLI(t, "provisionerd", "keys", "delete", "ci")
+	clitest.SetupConfig(t, client, conf)
+	pty = ptytest.New(t)
+	inv.Stdout = pty.Output()
+	err = inv.WithContext(ctx).Run()
+	require.NoError(t, err)
+	pty.ExpectMatch("Successfully deleted provisioner key")
+
+	keys, err = client.ProvisionerKeys(ctx, admin.OrganizationID)
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
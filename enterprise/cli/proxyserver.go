@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/http/pprof"
 	"os/signal"
+	"path/filepath"
 	"regexp"
 	rpprof "runtime/pprof"
 	"time"
@@ -19,6 +20,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
@@ -54,9 +57,10 @@ func (*RootCmd) proxyServer() *clibase.Cmd {
 			Name: "External Workspace Proxy",
 			YAML: "externalWorkspaceProxy",
 		}
-		proxySessionToken clibase.String
-		primaryAccessURL  clibase.URL
-		derpOnly          clibase.Bool
+		proxySessionToken     clibase.String
+		proxySessionTokenFile clibase.String
+		primaryAccessURL      clibase.URL
+		derpOnly              clibase.Bool
 	)
 	opts.Add(
 		// Options only for external workspace proxies
@@ -73,6 +77,19 @@ func (*RootCmd) proxyServer() *clibase.Cmd {
 			Hidden:      false,
 		},
 
+		clibase.Option{
+			Name: "Proxy Session Token File",
+			Description: "Path to a file the workspace proxy persists its session token to. If set, the proxy rotates its token " +
+				"before it expires and stores the new token in this file, which takes precedence over --proxy-session-token. " +
+				"Replicas of the same proxy must share the file.",
+			Flag:   "proxy-session-token-file",
+			Env:    "CODER_PROXY_SESSION_TOKEN_FILE",
+			YAML:   "proxySessionTokenFile",
+			Value:  &proxySessionTokenFile,
+			Group:  &externalProxyOptionGroup,
+			Hidden: false,
+		},
+
 		clibase.Option{
 			Name:        "Coderd (Primary) Access URL",
 			Description: "URL to communicate with coderd. This should match the access URL of the Coder deployment.",
@@ -249,17 +266,33 @@ func (*RootCmd) proxyServer() *clibase.Cmd {
 				APIRateLimit:           int(cfg.RateLimit.API.Value()),
 				SecureAuthCookie:       cfg.SecureAuthCookie.Value(),
 				DisablePathApps:        cfg.DisablePathApps.Value(),
+				StickySessions:         cfg.WorkspaceAppStickySessions.Value(),
 				ProxySessionToken:      proxySessionToken.Value(),
+				ProxySessionTokenFile:  proxySessionTokenFile.Value(),
 				AllowAllCors:           cfg.Dangerous.AllowAllCors.Value(),
 				DERPEnabled:            cfg.DERP.Server.Enable.Value(),
 				DERPOnly:               derpOnly.Value(),
 				DERPServerRelayAddress: cfg.DERP.Server.RelayURL.String(),
+				AppStatsSpillDir:       filepath.Join(cfg.CacheDir.String(), "app-stats"),
+				ServerTailnetPoolOptions: coderd.ServerTailnetPoolOptions{
+					MaxIdlePerAgent:     int(cfg.ServerTailnetMaxIdlePerAgent.Value()),
+					IdleTTL:             cfg.ServerTailnetIdleTTL.Value(),
+					HealthCheckInterval: cfg.ServerTailnetHealthInterval.Value(),
+				},
 			})
 			if err != nil {
 				return xerrors.Errorf("create workspace proxy: %w", err)
 			}
 			closers.Add(func() { _ = proxy.Close() })
 
+			acmeManager, err := cli.ConfigureACME(ctx, logger, cfg, httpServers, proxy.ACMEStore())
+			if err != nil {
+				return xerrors.Errorf("configure acme: %w", err)
+			}
+			if acmeManager != nil {
+				closers.Add(func() { _ = acmeManager.Close() })
+			}
+
 			shutdownConnsCtx, shutdownConns := context.WithCancel(ctx)
 			defer shutdownConns()
 			closers.Add(shutdownConns)
@@ -277,6 +310,11 @@ func (*RootCmd) proxyServer() *clibase.Cmd {
 					return shutdownConnsCtx
 				},
 			}
+			// Optionally accept HTTP/2 without TLS so gRPC workspace apps can
+			// be served behind load balancers that terminate TLS.
+			if cfg.HTTP2Cleartext.Value() {
+				httpServer.Handler = h2c.NewHandler(httpServer.Handler, &http2.Server{})
+			}
 			defer func() {
 				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 				defer cancel()
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) quietHours() *clibase.Cmd {
+	cmd := &clibase.Cmd{
+		Use:   "quiet-hours",
+		Short: "Manage your quiet hours schedule",
+		Handler: func(inv *clibase.Invocation) error {
+			return inv.Command.HelpHandler(inv)
+		},
+		Children: []*clibase.Cmd{
+			r.quietHoursSkip(),
+			r.quietHoursUnskip(),
+		},
+	}
+
+	return cmd
+}
+
+func (r *RootCmd) quietHoursSkip() *clibase.Cmd {
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "skip <date>",
+		Short: "Skip your quiet hours window on a date in YYYY-MM-DD format",
+		Long: "Template restart requirements that fall on a skipped date are moved to the next applicable day. " +
+			"The date is evaluated in the timezone of your quiet hours schedule.",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			date := inv.Args[0]
+			_, err := client.CreateUserQuietHoursException(inv.Context(), codersdk.Me, codersdk.CreateUserQuietHoursExceptionRequest{
+				Date: date,
+			})
+			if err != nil {
+				return xerrors.Errorf("create quiet hours exception: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Successfully skipped quiet hours on %s!\n", cliui.DefaultStyles.Keyword.Render(date))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func (r *RootCmd) quietHoursUnskip() *clibase.Cmd {
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "unskip <date>",
+		Short: "Restore your quiet hours window on a previously skipped date",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			date := inv.Args[0]
+			_, err := client.DeleteUserQuietHoursException(inv.Context(), codersdk.Me, date)
+			if err != nil {
+				return xerrors.Errorf("delete quiet hours exception: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(inv.Stdout, "Successfully restored quiet hours on %s!\n", cliui.DefaultStyles.Keyword.Render(date))
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,95 @@
+package cli_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestQuietHours(t *testing.T) {
+	t.Parallel()
+
+	t.Run("SkipUnskip", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments.Set(string(codersdk.ExperimentTemplateRestartRequirement))
+
+		client, _ := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues: dv,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+					codersdk.FeatureTemplateRestartRequirement: 1,
+				},
+			},
+		})
+
+		date := time.Now().UTC().AddDate(0, 0, 7).Format(codersdk.UserQuietHoursExceptionDateLayout)
+
+		inv, conf := newCLI(t, "quiet-hours", "skip", date)
+		pty := ptytest.New(t)
+		inv.Stdout = pty.Output()
+		clitest.SetupConfig(t, client, conf)
+
+		err := inv.Run()
+		require.NoError(t, err)
+		pty.ExpectMatch(fmt.Sprintf("Successfully skipped quiet hours on %s", cliui.DefaultStyles.Keyword.Render(date)))
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		sched, err := client.UserQuietHoursSchedule(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, []string{date}, sched.Exceptions)
+
+		inv, conf = newCLI(t, "quiet-hours", "unskip", date)
+		pty = ptytest.New(t)
+		inv.Stdout = pty.Output()
+		clitest.SetupConfig(t, client, conf)
+
+		err = inv.Run()
+		require.NoError(t, err)
+		pty.ExpectMatch(fmt.Sprintf("Successfully restored quiet hours on %s", cliui.DefaultStyles.Keyword.Render(date)))
+
+		sched, err = client.UserQuietHoursSchedule(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Empty(t, sched.Exceptions)
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments.Set(string(codersdk.ExperimentTemplateRestartRequirement))
+
+		client, _ := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues: dv,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+					codersdk.FeatureTemplateRestartRequirement: 1,
+				},
+			},
+		})
+
+		inv, conf := newCLI(t, "quiet-hours", "skip", "friday")
+		clitest.SetupConfig(t, client, conf)
+
+		err := inv.Run()
+		require.ErrorContains(t, err, "YYYY-MM-DD")
+	})
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+type quotaTableRow struct {
+	Workspace  string `table:"workspace,default_sort"`
+	Template   string `table:"template"`
+	Transition string `table:"transition"`
+	DailyCost  int32  `table:"daily cost"`
+}
+
+func (r *RootCmd) quota() *clibase.Cmd {
+	formatter := cliui.NewOutputFormatter(
+		cliui.ChangeFormatterData(cliui.TextFormat(), func(data any) (any, error) {
+			quota, ok := data.(codersdk.UserQuota)
+			if !ok {
+				return nil, xerrors.Errorf("unexpected type %T", data)
+			}
+
+			summary := fmt.Sprintf("%d of %d credits consumed", quota.CreditsConsumed, quota.Budget)
+			if quota.Budget < 0 {
+				summary = fmt.Sprintf("%d credits consumed (quotas are not licensed)", quota.CreditsConsumed)
+			}
+			if len(quota.Workspaces) == 0 {
+				return summary, nil
+			}
+
+			rows := make([]quotaTableRow, 0, len(quota.Workspaces))
+			for _, workspace := range quota.Workspaces {
+				rows = append(rows, quotaTableRow{
+					Workspace:  workspace.WorkspaceName,
+					Template:   workspace.TemplateName,
+					Transition: string(workspace.Transition),
+					DailyCost:  workspace.DailyCost,
+				})
+			}
+			table, err := cliui.DisplayTable(rows, "workspace", nil)
+			if err != nil {
+				return nil, xerrors.Errorf("display workspaces: %w", err)
+			}
+			return summary + "\n\n" + table, nil
+		}),
+		cliui.JSONFormat(),
+	)
+
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "quota [user]",
+		Short: "Show your workspace quota consumption and budget",
+		Long: "Workspaces consume quota credits with the daily cost of their latest build. " +
+			"Admins can show the quota of another user by passing their username.",
+		Middleware: clibase.Chain(
+			clibase.RequireRangeArgs(0, 1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			user := codersdk.Me
+			if len(inv.Args) > 0 {
+				user = inv.Args[0]
+			}
+
+			quota, err := client.UserQuota(inv.Context(), user)
+			if err != nil {
+				return xerrors.Errorf("get quota: %w", err)
+			}
+
+			out, err := formatter.Format(inv.Context(), quota)
+			if err != nil {
+				return xerrors.Errorf("format quota: %w", err)
+			}
+			_, _ = fmt.Fprintln(inv.Stdout, out)
+			return nil
+		},
+	}
+
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
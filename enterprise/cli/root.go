@@ -17,6 +17,9 @@ func (r *RootCmd) enterpriseOnly() []*clibase.Cmd {
 		r.licenses(),
 		r.groups(),
 		r.provisionerDaemons(),
+		r.quietHours(),
+		r.quota(),
+		r.share(),
 	}
 }
 
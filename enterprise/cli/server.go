@@ -8,6 +8,7 @@ import (
 	"errors"
 	"io"
 	"net/url"
+	"time"
 
 	"golang.org/x/xerrors"
 	"tailscale.com/derp"
@@ -18,6 +19,7 @@ import (
 	"github.com/coder/coder/enterprise/audit"
 	"github.com/coder/coder/enterprise/audit/backends"
 	"github.com/coder/coder/enterprise/coderd"
+	"github.com/coder/coder/enterprise/coderd/auditretention"
 	"github.com/coder/coder/enterprise/trialer"
 	"github.com/coder/coder/tailnet"
 
@@ -49,10 +51,33 @@ func (r *RootCmd) server() *clibase.Cmd {
 			}
 		}
 		options.DERPServer.SetMeshKey(meshKey)
-		options.Auditor = audit.NewAuditor(audit.DefaultFilter,
+		auditBackends := []audit.Backend{
 			backends.NewPostgres(options.Database, true),
 			backends.NewSlog(options.Logger),
-		)
+		}
+		var auditSink *backends.Sink
+		if exportCfg := options.DeploymentValues.AuditExport; exportCfg.Sink.String() != "" {
+			auditSink, err = backends.NewSink(options.Logger.Named("audit_export"), options.Database, backends.SinkOptions{
+				Type:       backends.SinkType(exportCfg.Sink.String()),
+				URL:        exportCfg.URL.String(),
+				Token:      exportCfg.Token.String(),
+				KafkaTopic: exportCfg.KafkaTopic.String(),
+				BatchSize:  int(exportCfg.BatchSize.Value()),
+			})
+			if err != nil {
+				return nil, nil, xerrors.Errorf("create audit export sink: %w", err)
+			}
+			auditBackends = append(auditBackends, auditSink)
+		}
+		options.Auditor = audit.NewAuditor(audit.DefaultFilter, auditBackends...)
+
+		var auditLogArchive auditretention.Store
+		if archiveURL := options.DeploymentValues.AuditLogRetention.ArchiveURL.String(); archiveURL != "" {
+			auditLogArchive, err = auditretention.NewStore(ctx, archiveURL)
+			if err != nil {
+				return nil, nil, xerrors.Errorf("create audit log archive: %w", err)
+			}
+		}
 
 		options.TrialGenerator = trialer.New(options.Database, "https://v2-licensor.coder.com/trial", coderd.Keys)
 
@@ -67,13 +92,33 @@ func (r *RootCmd) server() *clibase.Cmd {
 			ProxyHealthInterval:       options.DeploymentValues.ProxyHealthStatusInterval.Value(),
 			DefaultQuietHoursSchedule: options.DeploymentValues.UserQuietHoursSchedule.DefaultSchedule.Value(),
 			ProvisionerDaemonPSK:      options.DeploymentValues.Provisioner.DaemonPSK.Value(),
+			AuditLogRetention:         time.Duration(options.DeploymentValues.AuditLogRetention.Days.Value()) * 24 * time.Hour,
+			AuditLogArchive:           auditLogArchive,
 		}
 
 		api, err := coderd.New(ctx, o)
 		if err != nil {
+			if auditSink != nil {
+				_ = auditSink.Close()
+			}
 			return nil, nil, err
 		}
-		return api.AGPL, api, nil
+		if auditSink == nil {
+			return api.AGPL, api, nil
+		}
+		return api.AGPL, closerFunc(func() error {
+			// Close the API first so the sink isn't woken by in-flight
+			// requests after it's closed.
+			err := api.Close()
+			_ = auditSink.Close()
+			return err
+		}), nil
 	})
 	return cmd
 }
+
+type closerFunc func() error
+
+func (c closerFunc) Close() error {
+	return c()
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"fmt"
+
+	"golang.org/x/xerrors"
+
+	agpl "github.com/coder/coder/cli"
+	"github.com/coder/coder/cli/clibase"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func (r *RootCmd) share() *clibase.Cmd {
+	var (
+		groupName string
+		role      string
+		remove    bool
+	)
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "share <workspace>",
+		Short: "Share a workspace with a group",
+		Long: "Members of the group can use the apps of the workspace with the \"read\" role, " +
+			"and can also connect to the workspace with SSH or the web terminal with the \"full\" role.",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx := inv.Context()
+
+			workspace, err := agpl.NamedWorkspace(ctx, client, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("get workspace: %w", err)
+			}
+
+			group, err := client.GroupByOrgAndName(ctx, workspace.OrganizationID, groupName)
+			if err != nil {
+				return xerrors.Errorf("group by org and name: %w", err)
+			}
+
+			workspaceRole := codersdk.WorkspaceRole(role)
+			if remove {
+				workspaceRole = codersdk.WorkspaceRoleDeleted
+			}
+			err = client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+				GroupPerms: map[string]codersdk.WorkspaceRole{
+					group.ID.String(): workspaceRole,
+				},
+			})
+			if err != nil {
+				return xerrors.Errorf("update workspace ACL: %w", err)
+			}
+
+			if remove {
+				_, _ = fmt.Fprintf(inv.Stdout, "Stopped sharing workspace %s with group %s!\n",
+					cliui.DefaultStyles.Keyword.Render(workspace.Name), cliui.DefaultStyles.Keyword.Render(group.Name))
+				return nil
+			}
+			_, _ = fmt.Fprintf(inv.Stdout, "Shared workspace %s with group %s (%s)!\n",
+				cliui.DefaultStyles.Keyword.Render(workspace.Name), cliui.DefaultStyles.Keyword.Render(group.Name), role)
+			return nil
+		},
+	}
+
+	cmd.Options = clibase.OptionSet{
+		{
+			Flag:          "group",
+			FlagShorthand: "g",
+			Description:   "The name of the group to share the workspace with.",
+			Required:      true,
+			Value:         clibase.StringOf(&groupName),
+		},
+		{
+			Flag:        "role",
+			Description: "The access the members of the group get to the workspace.",
+			Default:     string(codersdk.WorkspaceRoleRead),
+			Value:       clibase.EnumOf(&role, string(codersdk.WorkspaceRoleRead), string(codersdk.WorkspaceRoleFull)),
+		},
+		{
+			Flag:        "remove",
+			Description: "Stop sharing the workspace with the group.",
+			Value:       clibase.BoolOf(&remove),
+		},
+	}
+
+	return cmd
+}
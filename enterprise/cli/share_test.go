@@ -0,0 +1,66 @@
+package cli_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestShare(t *testing.T) {
+	t.Parallel()
+
+	client, admin := coderdenttest.New(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+		},
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureTemplateRBAC: 1,
+			},
+		},
+	})
+	version := coderdtest.CreateTemplateVersion(t, client, admin.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, admin.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, admin.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	group, err := client.CreateGroup(ctx, admin.OrganizationID, codersdk.CreateGroupRequest{
+		Name: "alpha",
+	})
+	require.NoError(t, err)
+
+	inv, conf := newCLI(t, "share", workspace.Name, "--group", group.Name, "--role", "full")
+	pty := ptytest.New(t)
+	inv.Stdout = pty.Output()
+	clitest.SetupConfig(t, client, conf)
+
+	err = inv.Run()
+	require.NoError(t, err)
+	pty.ExpectMatch("Shared workspace")
+
+	acl, err := client.WorkspaceACL(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Len(t, acl.Groups, 1)
+	require.Equal(t, group.ID, acl.Groups[0].ID)
+	require.Equal(t, codersdk.WorkspaceRoleFull, acl.Groups[0].Role)
+
+	inv, conf = newCLI(t, "share", workspace.Name, "--group", group.Name, "--remove")
+	clitest.SetupConfig(t, client, conf)
+
+	err = inv.Run()
+	require.NoError(t, err)
+
+	acl, err = client.WorkspaceACL(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Empty(t, acl.Groups)
+}
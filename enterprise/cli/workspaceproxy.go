@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 
 	"github.com/fatih/color"
@@ -11,6 +12,7 @@ import (
 	"github.com/coder/coder/cli/clibase"
 	"github.com/coder/coder/cli/cliui"
 	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/wsproxy/wsproxysdk"
 )
 
 func (r *RootCmd) workspaceProxy() *clibase.Cmd {
@@ -31,6 +33,7 @@ func (r *RootCmd) workspaceProxy() *clibase.Cmd {
 			r.deleteProxy(),
 			r.listProxies(),
 			r.patchProxy(),
+			r.proxyStatus(),
 			r.regenerateProxyToken(),
 		},
 	}
@@ -390,6 +393,72 @@ func (r *RootCmd) listProxies() *clibase.Cmd {
 	return cmd
 }
 
+func (r *RootCmd) proxyStatus() *clibase.Cmd {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]wsproxysdk.HealthCheck{}, []string{"name", "healthy", "error"}),
+		cliui.ChangeFormatterData(cliui.JSONFormat(), func(data any) (any, error) {
+			checks, ok := data.([]wsproxysdk.HealthCheck)
+			if !ok {
+				return nil, xerrors.Errorf("unexpected type %T", data)
+			}
+			healthy := true
+			for _, check := range checks {
+				healthy = healthy && check.Healthy
+			}
+			return wsproxysdk.HealthResponse{Healthy: healthy, Checks: checks}, nil
+		}),
+	)
+
+	client := new(codersdk.Client)
+	cmd := &clibase.Cmd{
+		Use:   "status <name|id>",
+		Short: "Check whether a workspace proxy is ready to serve traffic",
+		Long: "Runs the readiness checks of a workspace proxy: its token is accepted by " +
+			"the primary, the primary is reachable and its DERP server accepts connections. " +
+			"Exits with an error if any check fails.",
+		Middleware: clibase.Chain(
+			clibase.RequireNArgs(1),
+			r.InitClient(client),
+		),
+		Handler: func(inv *clibase.Invocation) error {
+			ctx := inv.Context()
+
+			proxy, err := client.WorkspaceProxyByName(ctx, inv.Args[0])
+			if err != nil {
+				return xerrors.Errorf("fetch workspace proxy %q: %w", inv.Args[0], err)
+			}
+			if proxy.PathAppURL == "" {
+				return xerrors.Errorf("workspace proxy %q has not registered with the primary yet", proxy.Name)
+			}
+			proxyURL, err := url.Parse(proxy.PathAppURL)
+			if err != nil {
+				return xerrors.Errorf("parse workspace proxy url %q: %w", proxy.PathAppURL, err)
+			}
+
+			res, err := wsproxysdk.Readiness(ctx, client.HTTPClient, proxyURL)
+			if err != nil {
+				return xerrors.Errorf("check workspace proxy %q: %w", proxy.Name, err)
+			}
+
+			output, err := formatter.Format(ctx, res.Checks)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(inv.Stdout, output)
+			if err != nil {
+				return err
+			}
+			if !res.Healthy {
+				return xerrors.Errorf("workspace proxy %q is not ready", proxy.Name)
+			}
+			return nil
+		},
+	}
+
+	formatter.AttachOptions(&cmd.Options)
+	return cmd
+}
+
 // updateProxyResponseFormatter is used for both create and regenerate proxy commands.
 type updateProxyResponseFormatter struct {
 	onlyToken        bool
@@ -1,6 +1,8 @@
 package cli_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"strings"
 	"testing"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/coderd/coderdenttest"
 	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/enterprise/wsproxy/wsproxysdk"
 	"github.com/coder/coder/pty/ptytest"
 	"github.com/coder/coder/testutil"
 )
@@ -137,4 +140,50 @@ func Test_ProxyCRUD(t *testing.T) {
 		require.NoError(t, err, "failed to get workspace proxies")
 		require.Len(t, proxies.Regions, 1, "expected only primary proxy")
 	})
+	t.Run("Status", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments = []string{
+			string(codersdk.ExperimentMoons),
+			"*",
+		}
+
+		client, closer, api, _ := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues: dv,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureWorkspaceProxy: 1,
+				},
+			},
+		})
+		t.Cleanup(func() {
+			_ = closer.Close()
+		})
+
+		expectedName := "test-proxy"
+		_ = coderdenttest.NewWorkspaceProxy(t, api, client, &coderdenttest.ProxyOptions{
+			Name: expectedName,
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		inv, conf := newCLI(
+			t,
+			"wsproxy", "status", expectedName, "--output", "json",
+		)
+		var out bytes.Buffer
+		inv.Stdout = &out
+		clitest.SetupConfig(t, client, conf)
+
+		err := inv.WithContext(ctx).Run()
+		require.NoError(t, err)
+
+		var res wsproxysdk.HealthResponse
+		err = json.Unmarshal(out.Bytes(), &res)
+		require.NoError(t, err, "output: %s", out.String())
+		require.True(t, res.Healthy, "checks: %+v", res.Checks)
+		require.Len(t, res.Checks, 4)
+	})
 }
@@ -0,0 +1,232 @@
+// Package auditretention deletes audit logs that are older than the retention
+// period from the database, archiving them to object storage first.
+package auditretention
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/enterprise/audit/backends"
+)
+
+// batchSize is the number of audit logs archived to a single object.
+const batchSize = 1000
+
+// Archiver periodically archives and deletes expired audit logs.
+type Archiver struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	db        database.Store
+	store     Store
+	log       slog.Logger
+	retention time.Duration
+	tick      <-chan time.Time
+	stats     chan<- Stats
+}
+
+// Stats contains statistics about the last run of the archiver.
+type Stats struct {
+	// Archived is the number of audit logs that were archived.
+	Archived int
+	// Deleted is the number of audit logs that were deleted.
+	Deleted int
+	// Error is the fatal error that occurred during the last run of the
+	// archiver, if any.
+	Error error
+}
+
+// New returns a new audit log archiver. Audit logs older than retention are
+// written to store, which may be nil to delete them without archiving.
+func New(ctx context.Context, db database.Store, store Store, log slog.Logger, retention time.Duration, tick <-chan time.Time) *Archiver {
+	//nolint:gocritic // The archiver reads and deletes audit logs of all users.
+	ctx, cancel := context.WithCancel(dbauthz.AsSystemRestricted(ctx))
+	return &Archiver{
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		db:        db,
+		store:     store,
+		log:       log,
+		retention: retention,
+		tick:      tick,
+		stats:     nil,
+	}
+}
+
+// WithStatsChannel will cause the archiver to push Stats to ch after every
+// tick. This push is blocking, so if ch is not read, the archiver will hang.
+// This should only be used in tests.
+func (a *Archiver) WithStatsChannel(ch chan<- Stats) *Archiver {
+	a.stats = ch
+	return a
+}
+
+// Start will cause the archiver to archive expired audit logs on every tick
+// from its channel. It will stop when its context is Done, or when its
+// channel is closed.
+//
+// Start should only be called once.
+func (a *Archiver) Start() {
+	go func() {
+		defer close(a.done)
+		defer a.cancel()
+
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case t, ok := <-a.tick:
+				if !ok {
+					return
+				}
+				stats := a.run(t)
+				if stats.Error != nil {
+					a.log.Warn(a.ctx, "error archiving expired audit logs", slog.Error(stats.Error))
+				}
+				if a.stats != nil {
+					select {
+					case <-a.ctx.Done():
+						return
+					case a.stats <- stats:
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Wait will block until the archiver is stopped.
+func (a *Archiver) Wait() {
+	<-a.done
+}
+
+// Close will stop the archiver.
+func (a *Archiver) Close() {
+	a.cancel()
+	<-a.done
+}
+
+func (a *Archiver) run(now time.Time) Stats {
+	ctx, cancel := context.WithTimeout(a.ctx, 30*time.Minute)
+	defer cancel()
+
+	stats := Stats{}
+	createdBefore := now.Add(-a.retention)
+	for {
+		archived, deleted, err := a.archiveBatch(ctx, createdBefore)
+		stats.Archived += archived
+		stats.Deleted += deleted
+		if err != nil {
+			stats.Error = err
+			return stats
+		}
+		if deleted < batchSize {
+			break
+		}
+	}
+	if stats.Deleted > 0 {
+		a.log.Info(ctx, "deleted expired audit logs",
+			slog.F("archived", stats.Archived),
+			slog.F("deleted", stats.Deleted),
+			slog.F("created_before", createdBefore),
+		)
+	}
+	return stats
+}
+
+// archiveBatch archives and deletes the oldest expired audit logs. Audit logs
+// are only deleted once they are archived. The archive is uploaded before the
+// transaction starts, so the transaction isn't held open during the upload,
+// and the lock ensures replicas don't delete the same audit logs.
+func (a *Archiver) archiveBatch(ctx context.Context, createdBefore time.Time) (archived int, deleted int, err error) {
+	logs, err := a.db.GetAuditLogsCreatedBefore(ctx, database.GetAuditLogsCreatedBeforeParams{
+		CreatedBefore: createdBefore,
+		LimitCount:    batchSize,
+	})
+	if err != nil {
+		return 0, 0, xerrors.Errorf("get expired audit logs: %w", err)
+	}
+	if len(logs) == 0 {
+		return 0, 0, nil
+	}
+
+	if a.store != nil {
+		data, err := Encode(logs)
+		if err != nil {
+			return 0, 0, xerrors.Errorf("encode audit logs: %w", err)
+		}
+		err = a.store.Put(ctx, ObjectName(logs), data)
+		if err != nil {
+			return 0, 0, xerrors.Errorf("archive audit logs: %w", err)
+		}
+		archived = len(logs)
+	}
+
+	ids := make([]uuid.UUID, 0, len(logs))
+	for _, alog := range logs {
+		ids = append(ids, alog.ID)
+	}
+	err = a.db.InTx(func(db database.Store) error {
+		locked, err := db.TryAcquireLock(ctx, database.LockIDAuditLogRetention)
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !locked {
+			return nil
+		}
+
+		err = db.DeleteAuditLogsByIDs(ctx, ids)
+		if err != nil {
+			return xerrors.Errorf("delete audit logs: %w", err)
+		}
+		deleted = len(logs)
+		return nil
+	}, nil)
+	if err != nil {
+		return archived, 0, err
+	}
+	return archived, deleted, nil
+}
+
+// Encode returns the audit logs as gzip compressed JSON Lines, with the same
+// fields as audit logs streamed to an audit export sink.
+func Encode(logs []database.AuditLog) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+	for _, alog := range logs {
+		err := enc.Encode(backends.NewSinkEvent(alog))
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := zw.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ObjectName returns the name of the archive of the audit logs, which must be
+// ordered by time. The name only depends on the audit logs, so archiving the
+// same audit logs again after a failed delete replaces the archive instead of
+// duplicating it. The number of audit logs is part of the name, so replicas
+// that archive a different number of audit logs concurrently never replace
+// each other's archives.
+func ObjectName(logs []database.AuditLog) string {
+	first := logs[0]
+	t := first.Time.UTC()
+	return fmt.Sprintf("audit-logs/%s/%s-%s-%d.jsonl.gz", t.Format("2006/01/02"), t.Format("20060102T150405Z"), first.ID, len(logs))
+}
@@ -0,0 +1,215 @@
+package auditretention_test
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/database/dbtestutil"
+	"github.com/coder/coder/enterprise/audit/backends"
+	"github.com/coder/coder/enterprise/coderd/auditretention"
+	"github.com/coder/coder/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestArchiver(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Archive", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			ctx     = testutil.Context(t, testutil.WaitLong)
+			db, _   = dbtestutil.NewDB(t)
+			log     = slogtest.Make(t, nil)
+			tickCh  = make(chan time.Time)
+			statsCh = make(chan auditretention.Stats)
+			now     = time.Now()
+			dir     = t.TempDir()
+		)
+
+		expired := []database.AuditLog{
+			dbgen.AuditLog(t, db, database.AuditLog{Time: now.Add(-40 * 24 * time.Hour)}),
+			dbgen.AuditLog(t, db, database.AuditLog{Time: now.Add(-31 * 24 * time.Hour)}),
+		}
+		kept := dbgen.AuditLog(t, db, database.AuditLog{Time: now.Add(-29 * 24 * time.Hour)})
+
+		store, err := auditretention.NewStore(ctx, "file://"+filepath.ToSlash(dir))
+		require.NoError(t, err)
+		archiver := auditretention.New(ctx, db, store, log, 30*24*time.Hour, tickCh).WithStatsChannel(statsCh)
+		archiver.Start()
+		defer archiver.Close()
+
+		tickCh <- now
+		stats := <-statsCh
+		require.NoError(t, stats.Error)
+		require.Equal(t, 2, stats.Archived)
+		require.Equal(t, 2, stats.Deleted)
+
+		// Only the audit log within the retention period is left.
+		remaining, err := db.GetAuditLogsCreatedBefore(ctx, database.GetAuditLogsCreatedBeforeParams{
+			CreatedBefore: now,
+			LimitCount:    10,
+		})
+		require.NoError(t, err)
+		require.Len(t, remaining, 1)
+		require.Equal(t, kept.ID, remaining[0].ID)
+
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(auditretention.ObjectName(expired))))
+		require.NoError(t, err)
+		events := decode(t, data)
+		require.Len(t, events, 2)
+		require.Equal(t, expired[0].ID, events[0].ID)
+		require.Equal(t, expired[1].ID, events[1].ID)
+
+		// Nothing is left to archive.
+		tickCh <- now
+		stats = <-statsCh
+		require.NoError(t, stats.Error)
+		require.Zero(t, stats.Deleted)
+	})
+
+	t.Run("NoStore", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			ctx     = testutil.Context(t, testutil.WaitLong)
+			db, _   = dbtestutil.NewDB(t)
+			log     = slogtest.Make(t, nil)
+			tickCh  = make(chan time.Time)
+			statsCh = make(chan auditretention.Stats)
+			now     = time.Now()
+		)
+
+		_ = dbgen.AuditLog(t, db, database.AuditLog{Time: now.Add(-2 * time.Hour)})
+
+		archiver := auditretention.New(ctx, db, nil, log, time.Hour, tickCh).WithStatsChannel(statsCh)
+		archiver.Start()
+		defer archiver.Close()
+
+		tickCh <- now
+		stats := <-statsCh
+		require.NoError(t, stats.Error)
+		require.Zero(t, stats.Archived)
+		require.Equal(t, 1, stats.Deleted)
+	})
+
+	t.Run("StoreFailure", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			ctx     = testutil.Context(t, testutil.WaitLong)
+			db, _   = dbtestutil.NewDB(t)
+			log     = slogtest.Make(t, &slogtest.Options{IgnoreErrors: true})
+			tickCh  = make(chan time.Time)
+			statsCh = make(chan auditretention.Stats)
+			now     = time.Now()
+		)
+
+		_ = dbgen.AuditLog(t, db, database.AuditLog{Time: now.Add(-2 * time.Hour)})
+
+		archiver := auditretention.New(ctx, db, failingStore{}, log, time.Hour, tickCh).WithStatsChannel(statsCh)
+		archiver.Start()
+		defer archiver.Close()
+
+		// Audit logs are only deleted once they are archived.
+		tickCh <- now
+		stats := <-statsCh
+		require.Error(t, stats.Error)
+		require.Zero(t, stats.Deleted)
+
+		remaining, err := db.GetAuditLogsCreatedBefore(ctx, database.GetAuditLogsCreatedBeforeParams{
+			CreatedBefore: now,
+			LimitCount:    10,
+		})
+		require.NoError(t, err)
+		require.Len(t, remaining, 1)
+	})
+}
+
+//nolint:paralleltest // t.Setenv
+func TestS3Store(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "access-key")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret-key")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	type put struct {
+		path          string
+		authorization string
+		body          []byte
+	}
+	puts := make(chan put, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodPut, r.Method)
+		puts <- put{path: r.URL.Path, authorization: r.Header.Get("Authorization"), body: body}
+	}))
+	defer srv.Close()
+
+	store, err := auditretention.NewStore(ctx, "s3://bucket/coder?region=eu-west-1&endpoint="+srv.URL)
+	require.NoError(t, err)
+	err = store.Put(ctx, "audit-logs/archive.jsonl.gz", []byte("data"))
+	require.NoError(t, err)
+
+	p := <-puts
+	require.Equal(t, "/bucket/coder/audit-logs/archive.jsonl.gz", p.path)
+	require.True(t, strings.HasPrefix(p.authorization, "AWS4-HMAC-SHA256 Credential=access-key/"), p.authorization)
+	require.Contains(t, p.authorization, "/eu-west-1/s3/aws4_request")
+	require.Equal(t, []byte("data"), p.body)
+}
+
+func TestNewStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	for _, u := range []string{"", "gs://bucket", "file://", "s3:///prefix"} {
+		_, err := auditretention.NewStore(ctx, u)
+		require.Error(t, err, u)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Put(context.Context, string, []byte) error {
+	return io.ErrUnexpectedEOF
+}
+
+func decode(t *testing.T, data []byte) []backends.SinkEvent {
+	t.Helper()
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	var events []backends.SinkEvent
+	scanner := bufio.NewScanner(zr)
+	for scanner.Scan() {
+		var event backends.SinkEvent
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, event)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
@@ -0,0 +1,155 @@
+package auditretention
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"golang.org/x/xerrors"
+)
+
+// Store is the object storage expired audit logs are archived to.
+type Store interface {
+	// Put writes the object with the given name, replacing it if it exists.
+	Put(ctx context.Context, name string, data []byte) error
+}
+
+// NewStore returns the Store for the archive URL. Supported URLs are
+// directories like "file:///var/lib/coder/audit" and S3 buckets like
+// "s3://bucket/prefix?region=us-east-1". The endpoint query parameter of an S3
+// URL selects an S3 compatible service other than AWS.
+func NewStore(ctx context.Context, rawURL string) (Store, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, xerrors.Errorf("parse archive URL: %w", err)
+	}
+	switch u.Scheme {
+	case "file":
+		if u.Path == "" {
+			return nil, xerrors.Errorf("archive URL %q has no path", rawURL)
+		}
+		return &fileStore{dir: filepath.FromSlash(u.Path)}, nil
+	case "s3":
+		return newS3Store(ctx, u)
+	default:
+		return nil, xerrors.Errorf("unsupported archive URL scheme %q, must be file or s3", u.Scheme)
+	}
+}
+
+type fileStore struct {
+	dir string
+}
+
+func (s *fileStore) Put(_ context.Context, name string, data []byte) error {
+	p := filepath.Join(s.dir, filepath.FromSlash(name))
+	err := os.MkdirAll(filepath.Dir(p), 0o750)
+	if err != nil {
+		return xerrors.Errorf("create directory: %w", err)
+	}
+	// Write to a temporary file first so a partially written archive is
+	// never mistaken for a complete one.
+	tmp := p + ".tmp"
+	err = os.WriteFile(tmp, data, 0o600)
+	if err != nil {
+		return xerrors.Errorf("write file: %w", err)
+	}
+	err = os.Rename(tmp, p)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return xerrors.Errorf("rename file: %w", err)
+	}
+	return nil
+}
+
+type s3Store struct {
+	client      *http.Client
+	signer      *v4.Signer
+	credentials aws.CredentialsProvider
+	region      string
+	// objectURL returns the URL of the object with the given key.
+	objectURL func(key string) string
+	prefix    string
+}
+
+func newS3Store(ctx context.Context, u *url.URL) (*s3Store, error) {
+	bucket := u.Host
+	if bucket == "" {
+		return nil, xerrors.New("archive URL has no bucket")
+	}
+	query := u.Query()
+	region := query.Get("region")
+	if region == "" {
+		region = "us-east-1"
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, xerrors.Errorf("load AWS config: %w", err)
+	}
+
+	s := &s3Store{
+		client:      http.DefaultClient,
+		signer:      v4.NewSigner(),
+		credentials: cfg.Credentials,
+		region:      region,
+		prefix:      strings.Trim(u.Path, "/"),
+	}
+	if endpoint := query.Get("endpoint"); endpoint != "" {
+		// S3 compatible services don't reliably support virtual-hosted
+		// buckets, so use path-style URLs.
+		endpoint = strings.TrimSuffix(endpoint, "/")
+		s.objectURL = func(key string) string {
+			return fmt.Sprintf("%s/%s/%s", endpoint, bucket, key)
+		}
+	} else {
+		s.objectURL = func(key string) string {
+			return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, region, key)
+		}
+	}
+	return s, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, name string, data []byte) error {
+	key := path.Join(s.prefix, name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return xerrors.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/gzip")
+
+	hash := sha256.Sum256(data)
+	payloadHash := hex.EncodeToString(hash[:])
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	creds, err := s.credentials.Retrieve(ctx)
+	if err != nil {
+		return xerrors.Errorf("retrieve AWS credentials: %w", err)
+	}
+	err = s.signer.SignHTTP(ctx, creds, req, payloadHash, "s3", s.region, time.Now())
+	if err != nil {
+		return xerrors.Errorf("sign request: %w", err)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("put object: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return xerrors.Errorf("put object %q: unexpected status code %d: %s", key, res.StatusCode, body)
+	}
+	return nil
+}
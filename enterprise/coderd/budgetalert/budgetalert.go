@@ -0,0 +1,246 @@
+// Package budgetalert sends alerts when the daily cost of the workspaces of an
+// organization crosses a threshold of the organization's budget.
+package budgetalert
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/webhooks"
+)
+
+// acquireLockError is returned when the monitor fails to acquire the lock
+// because another replica is evaluating the budgets.
+type acquireLockError struct{}
+
+// Error implements error.
+func (acquireLockError) Error() string {
+	return "lock is held by another client"
+}
+
+// Monitor periodically compares the quota credits consumed by the workspaces
+// of every organization with the organization's budget, and sends an alert
+// when a warning or critical threshold is crossed.
+type Monitor struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	done    chan struct{}
+	enabled atomic.Bool
+
+	db       database.Store
+	webhooks *webhooks.Dispatcher
+	log      slog.Logger
+	tick     <-chan time.Time
+	stats    chan<- Stats
+}
+
+// Alert describes a threshold that was crossed.
+type Alert struct {
+	OrganizationID   uuid.UUID
+	ThresholdPercent int32
+	CreditsConsumed  int64
+	DailyBudget      int64
+}
+
+// Stats contains statistics about the last run of the monitor.
+type Stats struct {
+	// Alerts contains the alerts that were sent.
+	Alerts []Alert
+	// Error is the fatal error that occurred during the last run of the
+	// monitor, if any.
+	Error error
+}
+
+// New returns a new budget alert monitor. Alerts are sent to the webhook
+// dispatcher, which may be nil.
+func New(ctx context.Context, db database.Store, dispatcher *webhooks.Dispatcher, log slog.Logger, tick <-chan time.Time) *Monitor {
+	//nolint:gocritic // The monitor reads the budgets of all organizations.
+	ctx, cancel := context.WithCancel(dbauthz.AsSystemRestricted(ctx))
+	return &Monitor{
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		db:       db,
+		webhooks: dispatcher,
+		log:      log,
+		tick:     tick,
+		stats:    nil,
+	}
+}
+
+// SetEnabled enables or disables the monitor. Budgets are based on quotas,
+// which are only enforced if they are licensed, so the monitor is disabled
+// until it is enabled.
+func (m *Monitor) SetEnabled(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// WithStatsChannel will cause the monitor to push Stats to ch after every
+// tick. This push is blocking, so if ch is not read, the monitor will hang.
+// This should only be used in tests.
+func (m *Monitor) WithStatsChannel(ch chan<- Stats) *Monitor {
+	m.stats = ch
+	return m
+}
+
+// Start will cause the monitor to evaluate budgets on every tick from its
+// channel. It will stop when its context is Done, or when its channel is
+// closed.
+//
+// Start should only be called once.
+func (m *Monitor) Start() {
+	go func() {
+		defer close(m.done)
+		defer m.cancel()
+
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case _, ok := <-m.tick:
+				if !ok {
+					return
+				}
+				stats := m.run()
+				if stats.Error != nil {
+					m.log.Warn(m.ctx, "error evaluating organization budgets", slog.Error(stats.Error))
+				}
+				if m.stats != nil {
+					select {
+					case <-m.ctx.Done():
+						return
+					case m.stats <- stats:
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Wait will block until the monitor is stopped.
+func (m *Monitor) Wait() {
+	<-m.done
+}
+
+// Close will stop the monitor.
+func (m *Monitor) Close() {
+	m.cancel()
+	<-m.done
+}
+
+func (m *Monitor) run() Stats {
+	ctx, cancel := context.WithTimeout(m.ctx, time.Minute)
+	defer cancel()
+
+	stats := Stats{
+		Alerts: []Alert{},
+		Error:  nil,
+	}
+	if !m.enabled.Load() {
+		return stats
+	}
+
+	type alert struct {
+		Alert
+		organizationName string
+	}
+	var alerts []alert
+	// The budgets of all organizations are evaluated in one transaction that
+	// holds the lock, so only one replica alerts.
+	err := m.db.InTx(func(db database.Store) error {
+		alerts = nil
+		locked, err := db.TryAcquireLock(ctx, database.GenLockID("budget-alert"))
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !locked {
+			return acquireLockError{}
+		}
+
+		usages, err := db.GetOrganizationBudgetUsages(ctx)
+		if err != nil {
+			return xerrors.Errorf("get organization budget usages: %w", err)
+		}
+		for _, usage := range usages {
+			crossed := CrossedThreshold(usage.CreditsConsumed, usage.DailyBudget, usage.WarningThresholdPercent, usage.CriticalThresholdPercent)
+			if crossed == usage.AlertedThresholdPercent {
+				continue
+			}
+			// If usage dropped below a threshold, the threshold is reset
+			// without an alert, so it alerts again when it's crossed
+			// again.
+			err = db.UpdateOrganizationBudgetAlertedThreshold(ctx, database.UpdateOrganizationBudgetAlertedThresholdParams{
+				OrganizationID:          usage.OrganizationID,
+				AlertedThresholdPercent: crossed,
+			})
+			if err != nil {
+				return xerrors.Errorf("update alerted threshold: %w", err)
+			}
+			if crossed < usage.AlertedThresholdPercent {
+				continue
+			}
+			alerts = append(alerts, alert{
+				Alert: Alert{
+					OrganizationID:   usage.OrganizationID,
+					ThresholdPercent: crossed,
+					CreditsConsumed:  usage.CreditsConsumed,
+					DailyBudget:      usage.DailyBudget,
+				},
+				organizationName: usage.OrganizationName,
+			})
+		}
+		return nil
+	}, nil)
+	if xerrors.As(err, &acquireLockError{}) {
+		return stats
+	}
+	if err != nil {
+		stats.Error = xerrors.Errorf("evaluate organization budgets: %w", err)
+		return stats
+	}
+
+	// Alerts are only sent once the thresholds they're for are recorded.
+	for _, alert := range alerts {
+		m.log.Warn(ctx, "organization budget threshold crossed",
+			slog.F("organization_id", alert.OrganizationID),
+			slog.F("organization_name", alert.organizationName),
+			slog.F("threshold_percent", alert.ThresholdPercent),
+			slog.F("credits_consumed", alert.CreditsConsumed),
+			slog.F("daily_budget", alert.DailyBudget),
+		)
+		m.webhooks.DispatchBudget(webhooks.EventOrganizationBudgetThresholdCrossed, webhooks.Budget{
+			OrganizationID:   alert.OrganizationID,
+			OrganizationName: alert.organizationName,
+			DailyBudget:      alert.DailyBudget,
+			CreditsConsumed:  alert.CreditsConsumed,
+			ThresholdPercent: alert.ThresholdPercent,
+		})
+		stats.Alerts = append(stats.Alerts, alert.Alert)
+	}
+
+	return stats
+}
+
+// CrossedThreshold returns the highest of the warning and critical thresholds
+// that consumed credits reach as a percentage of the daily budget, or 0 if
+// neither is reached.
+func CrossedThreshold(consumed, dailyBudget int64, warningPercent, criticalPercent int32) int32 {
+	if dailyBudget <= 0 {
+		return 0
+	}
+	percent := consumed * 100 / dailyBudget
+	var crossed int32
+	for _, threshold := range []int32{warningPercent, criticalPercent} {
+		if threshold > 0 && percent >= int64(threshold) && threshold > crossed {
+			crossed = threshold
+		}
+	}
+	return crossed
+}
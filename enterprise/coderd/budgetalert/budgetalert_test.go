@@ -0,0 +1,195 @@
+package budgetalert_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/database/dbtestutil"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/enterprise/coderd/budgetalert"
+	"github.com/coder/coder/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestCrossedThreshold(t *testing.T) {
+	t.Parallel()
+
+	for _, c := range []struct {
+		name     string
+		consumed int64
+		budget   int64
+		expected int32
+	}{
+		{name: "NoBudget", consumed: 100, budget: 0, expected: 0},
+		{name: "BelowWarning", consumed: 79, budget: 100, expected: 0},
+		{name: "Warning", consumed: 80, budget: 100, expected: 80},
+		{name: "BelowCritical", consumed: 99, budget: 100, expected: 80},
+		{name: "Critical", consumed: 100, budget: 100, expected: 100},
+		{name: "OverBudget", consumed: 250, budget: 100, expected: 100},
+	} {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, c.expected, budgetalert.CrossedThreshold(c.consumed, c.budget, 80, 100))
+		})
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx     = testutil.Context(t, testutil.WaitLong)
+		db, _   = dbtestutil.NewDB(t)
+		log     = slogtest.Make(t, nil)
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan budgetalert.Stats)
+	)
+
+	payloads := make(chan webhooks.Payload, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		var payload webhooks.Payload
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		payloads <- payload
+	}))
+	defer srv.Close()
+	dispatcher, err := webhooks.New(log, webhooks.Options{URLs: []string{srv.URL}})
+	require.NoError(t, err)
+	defer dispatcher.Close()
+
+	org := dbgen.Organization(t, db, database.Organization{})
+	_, err = db.UpsertOrganizationBudgetSettings(ctx, database.UpsertOrganizationBudgetSettingsParams{
+		OrganizationID:           org.ID,
+		CreatedAt:                database.Now(),
+		UpdatedAt:                database.Now(),
+		DailyBudget:              100,
+		WarningThresholdPercent:  80,
+		CriticalThresholdPercent: 100,
+	})
+	require.NoError(t, err)
+	// Organizations without a budget are never alerted.
+	other := dbgen.Organization(t, db, database.Organization{})
+	setCost(ctx, t, db, other.ID, 1000)
+
+	monitor := budgetalert.New(ctx, db, dispatcher, log, tickCh).WithStatsChannel(statsCh)
+	monitor.Start()
+	defer monitor.Close()
+
+	tick := func() budgetalert.Stats {
+		tickCh <- time.Now()
+		stats := <-statsCh
+		require.NoError(t, stats.Error)
+		return stats
+	}
+
+	// Budgets aren't evaluated until the monitor is enabled.
+	build := setCost(ctx, t, db, org.ID, 90)
+	require.Empty(t, tick().Alerts)
+	monitor.SetEnabled(true)
+
+	// Below the warning threshold.
+	updateCost(ctx, t, db, build, 50)
+	require.Empty(t, tick().Alerts)
+
+	// Crossing the warning threshold alerts once.
+	updateCost(ctx, t, db, build, 85)
+	stats := tick()
+	require.Len(t, stats.Alerts, 1)
+	require.Equal(t, org.ID, stats.Alerts[0].OrganizationID)
+	require.EqualValues(t, 80, stats.Alerts[0].ThresholdPercent)
+	require.EqualValues(t, 85, stats.Alerts[0].CreditsConsumed)
+	require.Empty(t, tick().Alerts)
+
+	select {
+	case payload := <-payloads:
+		require.Equal(t, webhooks.EventOrganizationBudgetThresholdCrossed, payload.Event)
+		require.NotNil(t, payload.Budget)
+		require.Equal(t, org.ID, payload.Budget.OrganizationID)
+		require.Equal(t, org.Name, payload.Budget.OrganizationName)
+		require.EqualValues(t, 80, payload.Budget.ThresholdPercent)
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for webhook")
+	}
+
+	// Crossing the critical threshold alerts again.
+	updateCost(ctx, t, db, build, 120)
+	stats = tick()
+	require.Len(t, stats.Alerts, 1)
+	require.EqualValues(t, 100, stats.Alerts[0].ThresholdPercent)
+
+	// Dropping below the thresholds resets them without alerting, so they
+	// alert again when they're crossed again.
+	updateCost(ctx, t, db, build, 10)
+	require.Empty(t, tick().Alerts)
+	settings, err := db.GetOrganizationBudgetSettings(ctx, org.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 0, settings.AlertedThresholdPercent)
+
+	updateCost(ctx, t, db, build, 90)
+	stats = tick()
+	require.Len(t, stats.Alerts, 1)
+	require.EqualValues(t, 80, stats.Alerts[0].ThresholdPercent)
+}
+
+// setCost creates a workspace in the organization whose latest build costs
+// the given credits.
+func setCost(ctx context.Context, t *testing.T, db database.Store, organizationID uuid.UUID, cost int32) database.WorkspaceBuild {
+	t.Helper()
+
+	user := dbgen.User(t, db, database.User{})
+	template := dbgen.Template(t, db, database.Template{
+		OrganizationID: organizationID,
+		CreatedBy:      user.ID,
+	})
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		OrganizationID: organizationID,
+		InitiatorID:    user.ID,
+	})
+	version := dbgen.TemplateVersion(t, db, database.TemplateVersion{
+		TemplateID:     uuid.NullUUID{UUID: template.ID, Valid: true},
+		OrganizationID: organizationID,
+		JobID:          job.ID,
+		CreatedBy:      user.ID,
+	})
+	workspace := dbgen.Workspace(t, db, database.Workspace{
+		OwnerID:        user.ID,
+		OrganizationID: organizationID,
+		TemplateID:     template.ID,
+	})
+	build := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		WorkspaceID:       workspace.ID,
+		TemplateVersionID: version.ID,
+		InitiatorID:       user.ID,
+		JobID:             job.ID,
+	})
+	updateCost(ctx, t, db, build, cost)
+	return build
+}
+
+func updateCost(ctx context.Context, t *testing.T, db database.Store, build database.WorkspaceBuild, cost int32) {
+	t.Helper()
+
+	err := db.UpdateWorkspaceBuildCostByID(ctx, database.UpdateWorkspaceBuildCostByIDParams{
+		ID:        build.ID,
+		DailyCost: cost,
+	})
+	require.NoError(t, err)
+}
@@ -10,7 +10,6 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -19,6 +18,7 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
 
 	"cdr.dev/slog"
@@ -30,6 +30,8 @@ import (
 	"github.com/coder/coder/coderd/rbac"
 	agplschedule "github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/auditretention"
+	"github.com/coder/coder/enterprise/coderd/budgetalert"
 	"github.com/coder/coder/enterprise/coderd/license"
 	"github.com/coder/coder/enterprise/coderd/proxyhealth"
 	"github.com/coder/coder/enterprise/coderd/schedule"
@@ -47,6 +49,9 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 	if options.EntitlementsUpdateInterval == 0 {
 		options.EntitlementsUpdateInterval = 10 * time.Minute
 	}
+	if options.BudgetAlertInterval == 0 {
+		options.BudgetAlertInterval = 5 * time.Minute
+	}
 	if options.Keys == nil {
 		options.Keys = Keys
 	}
@@ -72,6 +77,7 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 			authorizer: options.Authorizer,
 		},
 	}
+	api.deadlineRecalculator = schedule.NewDeadlineRecalculator(ctx, options.Logger.Named("deadline_recalculator"), options.Database, api.AGPL.TemplateScheduleStore, 0)
 	defer func() {
 		if err != nil {
 			_ = api.Close()
@@ -142,8 +148,9 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 		r.With(
 			apiKeyMiddlewareOptional,
 			httpmw.ExtractWorkspaceProxy(httpmw.ExtractWorkspaceProxyConfig{
-				DB:       options.Database,
-				Optional: true,
+				DB:         options.Database,
+				Optional:   true,
+				ClientCert: options.WorkspaceProxyClientCertCfg,
 			}),
 			httpmw.RequireAPIKeyOrWorkspaceProxyAuth(),
 		).Get("/workspaceagents/{workspaceagent}/legacy", api.agentIsLegacy)
@@ -161,8 +168,9 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 			r.Route("/me", func(r chi.Router) {
 				r.Use(
 					httpmw.ExtractWorkspaceProxy(httpmw.ExtractWorkspaceProxyConfig{
-						DB:       options.Database,
-						Optional: false,
+						DB:         options.Database,
+						Optional:   false,
+						ClientCert: options.WorkspaceProxyClientCertCfg,
 					}),
 				)
 				r.Get("/coordinate", api.workspaceProxyCoordinate)
@@ -170,6 +178,9 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 				r.Post("/app-stats", api.workspaceProxyReportAppStats)
 				r.Post("/register", api.workspaceProxyRegister)
 				r.Post("/deregister", api.workspaceProxyDeregister)
+				r.Post("/rotate-token", api.workspaceProxyRotateToken)
+				r.Get("/acme-certificate", api.workspaceProxyACMECertificate)
+				r.Put("/acme-certificate", api.workspaceProxyUpdateACMECertificate)
 			})
 			r.Route("/{workspaceproxy}", func(r chi.Router) {
 				r.Use(
@@ -198,6 +209,33 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 				r.Get("/", api.groupByOrganization)
 			})
 		})
+		r.Route("/organizations/{organization}/settings/schedule", func(r chi.Router) {
+			r.Use(
+				api.advancedTemplateSchedulingEnabledMW,
+				apiKeyMiddleware,
+				httpmw.ExtractOrganizationParam(api.Database),
+			)
+			r.Get("/", api.organizationScheduleSettings)
+			r.Put("/", api.putOrganizationScheduleSettings)
+		})
+		r.Route("/organizations/{organization}/settings/budget", func(r chi.Router) {
+			r.Use(
+				api.templateRBACEnabledMW,
+				apiKeyMiddleware,
+				httpmw.ExtractOrganizationParam(api.Database),
+			)
+			r.Get("/", api.organizationBudgetSettings)
+			r.Put("/", api.putOrganizationBudgetSettings)
+		})
+		r.Route("/organizations/{organization}/settings/jit-provisioning", func(r chi.Router) {
+			r.Use(
+				api.jitProvisioningEnabledMW,
+				apiKeyMiddleware,
+				httpmw.ExtractOrganizationParam(api.Database),
+			)
+			r.Get("/", api.organizationJITProvisioningSettings)
+			r.Put("/", api.putOrganizationJITProvisioningSettings)
+		})
 		// TODO: provisioner daemons are not scoped to organizations in the database, so placing them
 		// under an organization route doesn't make sense.  In order to allow the /serve endpoint to
 		// work with a pre-shared key (PSK) without an API key, these routes will simply ignore the
@@ -214,6 +252,16 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 			r.With(apiKeyMiddleware).Get("/", api.provisionerDaemons)
 			r.With(apiKeyMiddlewareOptional).Get("/serve", api.provisionerDaemonServe)
 		})
+		r.Route("/organizations/{organization}/provisionerkeys", func(r chi.Router) {
+			r.Use(
+				apiKeyMiddleware,
+				api.provisionerDaemonsEnabledMW,
+				httpmw.ExtractOrganizationParam(api.Database),
+			)
+			r.Get("/", api.provisionerKeys)
+			r.Post("/", api.postProvisionerKey)
+			r.Delete("/{provisionerkey}", api.deleteProvisionerKey)
+		})
 		r.Route("/templates/{template}/acl", func(r chi.Router) {
 			r.Use(
 				api.templateRBACEnabledMW,
@@ -224,6 +272,25 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 			r.Get("/", api.templateACL)
 			r.Patch("/", api.patchTemplateACL)
 		})
+		r.Route("/templates/{template}/recalculate-deadlines", func(r chi.Router) {
+			r.Use(
+				api.advancedTemplateSchedulingEnabledMW,
+				apiKeyMiddleware,
+				httpmw.ExtractTemplateParam(api.Database),
+			)
+			r.Get("/", api.templateRecalculateDeadlinesList)
+			r.Post("/", api.postTemplateRecalculateDeadlines)
+			r.Get("/{job}", api.templateRecalculateDeadlines)
+		})
+		r.Route("/templates/{template}/schedule-policy", func(r chi.Router) {
+			r.Use(
+				api.advancedTemplateSchedulingEnabledMW,
+				apiKeyMiddleware,
+				httpmw.ExtractTemplateParam(api.Database),
+			)
+			r.Get("/", api.templateSchedulePolicy)
+			r.Put("/", api.putTemplateSchedulePolicy)
+		})
 		r.Route("/groups/{group}", func(r chi.Router) {
 			r.Use(
 				api.templateRBACEnabledMW,
@@ -243,6 +310,13 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 				r.Get("/", api.workspaceQuota)
 			})
 		})
+		r.Route("/users/{user}/quota", func(r chi.Router) {
+			r.Use(
+				apiKeyMiddleware,
+				httpmw.ExtractUserParam(options.Database, false),
+			)
+			r.Get("/", api.userQuota)
+		})
 		r.Route("/appearance", func(r chi.Router) {
 			r.Group(func(r chi.Router) {
 				r.Use(
@@ -271,6 +345,42 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 
 			r.Get("/", api.userQuietHoursSchedule)
 			r.Put("/", api.putUserQuietHoursSchedule)
+			r.Route("/exceptions", func(r chi.Router) {
+				r.Post("/", api.postUserQuietHoursException)
+				r.Delete("/{date}", api.deleteUserQuietHoursException)
+			})
+		})
+		r.Route("/deployment/schedule/holidays", func(r chi.Router) {
+			r.Use(
+				api.restartRequirementEnabledMW,
+				apiKeyMiddleware,
+			)
+
+			r.Get("/", api.scheduleHolidays)
+			r.Post("/", api.postScheduleHoliday)
+			r.Get("/{holiday}", api.scheduleHoliday)
+			r.Put("/{holiday}", api.putScheduleHoliday)
+			r.Delete("/{holiday}", api.deleteScheduleHoliday)
+		})
+		r.Route("/workspaces/{workspace}/schedule-override", func(r chi.Router) {
+			r.Use(
+				api.workspaceScheduleOverridesEnabledMW,
+				apiKeyMiddleware,
+				httpmw.ExtractWorkspaceParam(options.Database),
+			)
+
+			r.Get("/", api.workspaceScheduleOverride)
+			r.Put("/", api.putWorkspaceScheduleOverride)
+			r.Delete("/", api.deleteWorkspaceScheduleOverride)
+		})
+		r.Route("/workspaces/{workspace}/acl", func(r chi.Router) {
+			r.Use(
+				api.templateRBACEnabledMW,
+				apiKeyMiddleware,
+				httpmw.ExtractWorkspaceParam(options.Database),
+			)
+			r.Get("/", api.workspaceACL)
+			r.Patch("/", api.patchWorkspaceACL)
 		})
 	})
 
@@ -286,6 +396,16 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 				r.Get("/{id}", api.scimGetUser)
 				r.Patch("/{id}", api.scimPatchUser)
 			})
+			r.Route("/Groups", func(r chi.Router) {
+				// Groups are only useful with template RBAC.
+				r.Use(api.templateRBACEnabledMW)
+				r.Get("/", api.scimGetGroups)
+				r.Post("/", api.scimPostGroup)
+				r.Get("/{id}", api.scimGetGroup)
+				r.Put("/{id}", api.scimPutGroup)
+				r.Patch("/{id}", api.scimPatchGroup)
+				r.Delete("/{id}", api.scimDeleteGroup)
+			})
 		})
 	}
 
@@ -322,6 +442,11 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 		return nil, xerrors.Errorf("initialize replica: %w", err)
 	}
 	api.derpMesh = derpmesh.New(options.Logger.Named("derpmesh"), api.DERPServer, meshTLSConfig)
+	if api.AGPL.WorkspaceAppReplicas != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = meshTLSConfig
+		api.AGPL.WorkspaceAppReplicas.SetTransport(transport)
+	}
 
 	if api.AGPL.Experiments.Enabled(codersdk.ExperimentMoons) {
 		// Proxy health is a moon feature.
@@ -345,12 +470,23 @@ func New(ctx context.Context, options *Options) (_ *API, err error) {
 		api.AGPL.WorkspaceProxyHostsFn.Store(&f)
 	}
 
+	// The budget alert monitor is enabled by the entitlements.
+	api.budgetAlertTicker = time.NewTicker(options.BudgetAlertInterval)
+	api.budgetAlerts = budgetalert.New(ctx, options.Database, options.Webhooks, options.Logger.Named("budget_alerts"), api.budgetAlertTicker.C)
+	api.budgetAlerts.Start()
+
 	err = api.updateEntitlements(ctx)
 	if err != nil {
 		return nil, xerrors.Errorf("update entitlements: %w", err)
 	}
 	go api.runEntitlementsLoop(ctx)
 
+	if options.AuditLogRetention > 0 {
+		api.auditRetentionTicker = time.NewTicker(time.Hour)
+		api.auditRetention = auditretention.New(ctx, options.Database, options.AuditLogArchive, options.Logger.Named("audit_retention"), options.AuditLogRetention, api.auditRetentionTicker.C)
+		api.auditRetention.Start()
+	}
+
 	return api, nil
 }
 
@@ -375,6 +511,17 @@ type Options struct {
 	ProxyHealthInterval        time.Duration
 	Keys                       map[string]ed25519.PublicKey
 
+	// BudgetAlertInterval is how often organization budgets are compared
+	// with the quota consumed by their workspaces. Defaults to 5 minutes.
+	BudgetAlertInterval time.Duration
+
+	// AuditLogRetention is how long audit logs are kept in the database.
+	// Zero keeps them forever.
+	AuditLogRetention time.Duration
+	// AuditLogArchive is where expired audit logs are archived before they
+	// are deleted. If nil, they are deleted without being archived.
+	AuditLogArchive auditretention.Store
+
 	// optional pre-shared key for authentication of external provisioner daemons
 	ProvisionerDaemonPSK string
 }
@@ -400,10 +547,32 @@ type API struct {
 	entitlements         codersdk.Entitlements
 
 	provisionerDaemonAuth *provisionerDaemonAuth
+
+	// deadlineRecalculator recalculates workspace build deadlines in the
+	// background after template schedule changes.
+	deadlineRecalculator *schedule.DeadlineRecalculator
+	// budgetAlerts sends alerts when organizations cross a threshold of
+	// their budget.
+	budgetAlerts      *budgetalert.Monitor
+	budgetAlertTicker *time.Ticker
+	// auditRetention archives and deletes expired audit logs.
+	auditRetention       *auditretention.Archiver
+	auditRetentionTicker *time.Ticker
 }
 
 func (api *API) Close() error {
 	api.cancel()
+	if api.deadlineRecalculator != nil {
+		api.deadlineRecalculator.Close()
+	}
+	if api.budgetAlerts != nil {
+		api.budgetAlertTicker.Stop()
+		api.budgetAlerts.Close()
+	}
+	if api.auditRetention != nil {
+		api.auditRetentionTicker.Stop()
+		api.auditRetention.Close()
+	}
 	if api.replicaManager != nil {
 		_ = api.replicaManager.Close()
 	}
@@ -433,6 +602,9 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 			codersdk.FeatureTemplateRestartRequirement: api.DefaultQuietHoursSchedule != "",
 			codersdk.FeatureWorkspaceProxy:             true,
 			codersdk.FeatureUserRoleManagement:         true,
+			codersdk.FeatureJITProvisioning:            true,
+			codersdk.FeatureWorkspaceAppOIDC:           api.AGPL.WorkspaceAppsOIDCConfig != nil,
+			codersdk.FeatureTemplateResourceQuotas:     true,
 		})
 	if err != nil {
 		return err
@@ -498,17 +670,26 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 
 	if initial, changed, enabled := featureChanged(codersdk.FeatureTemplateRBAC); shouldUpdate(initial, changed, enabled) {
 		if enabled {
-			committer := committer{Database: api.Database}
+			committer := committer{
+				Database:              api.Database,
+				ResourceQuotasEnabled: &api.AGPL.TemplateResourceQuotasEnabled,
+			}
 			ptr := proto.QuotaCommitter(&committer)
 			api.AGPL.QuotaCommitter.Store(&ptr)
 		} else {
 			api.AGPL.QuotaCommitter.Store(nil)
 		}
+		api.budgetAlerts.SetEnabled(enabled)
 	}
 
 	if initial, changed, enabled := featureChanged(codersdk.FeatureAdvancedTemplateScheduling); shouldUpdate(initial, changed, enabled) {
 		if enabled {
 			templateStore := schedule.NewEnterpriseTemplateScheduleStore(api.AGPL.UserQuietHoursScheduleStore)
+			templateStore.UseWorkspaceScheduleOverrides.Store(api.AGPL.Experiments.Enabled(codersdk.ExperimentWorkspaceScheduleOverrides))
+			templateStore.Holidays = api.AGPL.ScheduleHolidays
+			templateStore.DeadlineRecalculator = api.deadlineRecalculator
+			templateStore.Auditor = &api.AGPL.Auditor
+			templateStore.Logger = api.Logger.Named("template_schedule_store")
 			templateStoreInterface := agplschedule.TemplateScheduleStore(templateStore)
 			api.AGPL.TemplateScheduleStore.Store(&templateStoreInterface)
 		} else {
@@ -526,7 +707,7 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 			}
 			enterpriseTemplateStore.UseRestartRequirement.Store(true)
 
-			quietHoursStore, err := schedule.NewEnterpriseUserQuietHoursScheduleStore(api.DefaultQuietHoursSchedule)
+			quietHoursStore, err := schedule.NewEnterpriseUserQuietHoursScheduleStore(api.DefaultQuietHoursSchedule, &api.AGPL.Auditor, api.Logger.Named("user_quiet_hours_schedule_store"))
 			if err != nil {
 				api.Logger.Error(ctx, "unable to set up enterprise user quiet hours schedule store, template restart requirements will not be applied to workspace builds", slog.Error(err))
 			} else {
@@ -567,15 +748,25 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 
 			api.replicaManager.SetCallback(func() {
 				addresses := make([]string, 0)
+				appReplicas := make(map[uuid.UUID]string)
 				for _, replica := range api.replicaManager.Regional() {
 					addresses = append(addresses, replica.RelayAddress)
+					if replica.Error == "" {
+						appReplicas[replica.ID] = replica.RelayAddress
+					}
 				}
 				api.derpMesh.SetAddresses(addresses, false)
+				if api.AGPL.WorkspaceAppReplicas != nil {
+					api.AGPL.WorkspaceAppReplicas.SetPeers(appReplicas)
+				}
 				_ = api.updateEntitlements(ctx)
 			})
 		} else {
 			coordinator = agpltailnet.NewCoordinator(api.Logger)
 			api.derpMesh.SetAddresses([]string{}, false)
+			if api.AGPL.WorkspaceAppReplicas != nil {
+				api.AGPL.WorkspaceAppReplicas.SetPeers(nil)
+			}
 			api.replicaManager.SetCallback(func() {
 				// If the amount of replicas change, so should our entitlements.
 				// This is to display a warning in the UI if the user is unlicensed.
@@ -602,6 +793,23 @@ func (api *API) updateEntitlements(ctx context.Context) error {
 		}
 	}
 
+	if initial, changed, enabled := featureChanged(codersdk.FeatureJITProvisioning); shouldUpdate(initial, changed, enabled) {
+		if enabled {
+			fn := api.jitProvisionWorkspaces
+			api.AGPL.FirstLoginFn.Store(&fn)
+		} else {
+			api.AGPL.FirstLoginFn.Store(nil)
+		}
+	}
+
+	if initial, changed, enabled := featureChanged(codersdk.FeatureWorkspaceAppOIDC); shouldUpdate(initial, changed, enabled) {
+		api.AGPL.WorkspaceAppsOIDCEnabled.Store(enabled)
+	}
+
+	if initial, changed, enabled := featureChanged(codersdk.FeatureTemplateResourceQuotas); shouldUpdate(initial, changed, enabled) {
+		api.AGPL.TemplateResourceQuotasEnabled.Store(enabled)
+	}
+
 	api.entitlementsMu.Lock()
 	defer api.entitlementsMu.Unlock()
 	api.entitlements = entitlements
@@ -683,8 +891,8 @@ func derpMapper(logger slog.Logger, proxyHealth *proxyhealth.ProxyHealth) func(*
 					slog.F("largest_region_id", largestRegionID),
 					slog.F("max_region_id", int64(1<<32-1)),
 				)
-				return derpMap
 			}
+			return derpMap
 		}
 
 		// Add all healthy proxies to the DERP map.
@@ -722,7 +930,7 @@ func derpMapper(logger slog.Logger, proxyHealth *proxyhealth.ProxyHealth) func(*
 			// unique by the database and the computed ID is greater than any
 			// existing ID in the DERP map.
 			regionID := int(startingRegionID) + int(status.Proxy.RegionID)
-			regionCode := fmt.Sprintf("coder_%s", strings.ToLower(status.Proxy.Name))
+			regionCode := codersdk.WorkspaceProxyDERPRegionCode(status.Proxy.Name)
 			regionName := status.Proxy.DisplayName
 			if regionName == "" {
 				regionName = status.Proxy.Name
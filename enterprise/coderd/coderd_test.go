@@ -57,6 +57,9 @@ func TestEntitlements(t *testing.T) {
 				codersdk.FeatureAdvancedTemplateScheduling: 1,
 				codersdk.FeatureWorkspaceProxy:             1,
 				codersdk.FeatureUserRoleManagement:         1,
+				codersdk.FeatureJITProvisioning:            1,
+				codersdk.FeatureWorkspaceAppOIDC:           1,
+				codersdk.FeatureTemplateResourceQuotas:     1,
 			},
 			GraceAt: time.Now().Add(59 * 24 * time.Hour),
 		})
@@ -228,6 +231,36 @@ func TestAuditLogging(t *testing.T) {
 	})
 }
 
+func TestWorkspaceAppOIDC(t *testing.T) {
+	t.Parallel()
+	t.Run("Enabled", func(t *testing.T) {
+		t.Parallel()
+		fake := coderdtest.NewOIDCConfig(t, "")
+		_, _, api, _ := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				WorkspaceAppsOIDCConfig: fake.WorkspaceAppsOIDCConfig("coder-apps"),
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureWorkspaceAppOIDC: 1,
+				},
+			},
+		})
+		assert.True(t, api.AGPL.WorkspaceAppsOIDCEnabled.Load())
+	})
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		fake := coderdtest.NewOIDCConfig(t, "")
+		_, _, api, _ := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				WorkspaceAppsOIDCConfig: fake.WorkspaceAppsOIDCConfig("coder-apps"),
+			},
+			DontAddLicense: true,
+		})
+		assert.False(t, api.AGPL.WorkspaceAppsOIDCEnabled.Load())
+	})
+}
+
 // testDBAuthzRole returns a context with a subject that has a role
 // with permissions required for test setup.
 func testDBAuthzRole(ctx context.Context) context.Context {
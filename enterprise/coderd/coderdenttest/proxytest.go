@@ -34,6 +34,8 @@ type ProxyOptions struct {
 	DisablePathApps bool
 	DerpDisabled    bool
 	DerpOnly        bool
+	// ProxySessionTokenFile enables token rotation.
+	ProxySessionTokenFile string
 
 	// ProxyURL is optional
 	ProxyURL *url.URL
@@ -115,18 +117,19 @@ func NewWorkspaceProxy(t *testing.T, coderdAPI *coderd.API, owner *codersdk.Clie
 	statsCollectorOptions.Reporter = nil
 
 	wssrv, err := wsproxy.New(ctx, &wsproxy.Options{
-		Logger:            slogtest.Make(t, nil).Leveled(slog.LevelDebug),
-		Experiments:       options.Experiments,
-		DashboardURL:      coderdAPI.AccessURL,
-		AccessURL:         accessURL,
-		AppHostname:       options.AppHostname,
-		AppHostnameRegex:  appHostnameRegex,
-		RealIPConfig:      coderdAPI.RealIPConfig,
-		Tracing:           coderdAPI.TracerProvider,
-		APIRateLimit:      coderdAPI.APIRateLimit,
-		SecureAuthCookie:  coderdAPI.SecureAuthCookie,
-		ProxySessionToken: proxyRes.ProxyToken,
-		DisablePathApps:   options.DisablePathApps,
+		Logger:                slogtest.Make(t, nil).Leveled(slog.LevelDebug),
+		Experiments:           options.Experiments,
+		DashboardURL:          coderdAPI.AccessURL,
+		AccessURL:             accessURL,
+		AppHostname:           options.AppHostname,
+		AppHostnameRegex:      appHostnameRegex,
+		RealIPConfig:          coderdAPI.RealIPConfig,
+		Tracing:               coderdAPI.TracerProvider,
+		APIRateLimit:          coderdAPI.APIRateLimit,
+		SecureAuthCookie:      coderdAPI.SecureAuthCookie,
+		ProxySessionToken:     proxyRes.ProxyToken,
+		ProxySessionTokenFile: options.ProxySessionTokenFile,
+		DisablePathApps:       options.DisablePathApps,
 		// We need a new registry to not conflict with the coderd internal
 		// proxy metrics.
 		PrometheusRegistry:     prometheus.NewRegistry(),
@@ -128,6 +128,13 @@ func (api *API) patchGroup(rw http.ResponseWriter, r *http.Request) {
 		req.Name = ""
 	}
 
+	if group.Source == database.GroupSourceScim && (req.Name != "" || len(req.AddUsers) > 0 || len(req.RemoveUsers) > 0) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The name and members of groups pushed by SCIM are managed by the identity provider.",
+		})
+		return
+	}
+
 	users := make([]string, 0, len(req.AddUsers)+len(req.RemoveUsers))
 	users = append(users, req.AddUsers...)
 	users = append(users, req.RemoveUsers...)
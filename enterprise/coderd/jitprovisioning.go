@@ -0,0 +1,289 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/wsbuilder"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get organization JIT provisioning settings
+// @ID get-organization-jit-provisioning-settings
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Success 200 {object} codersdk.OrganizationJITProvisioningSettings
+// @Router /organizations/{organization}/settings/jit-provisioning [get]
+func (api *API) organizationJITProvisioningSettings(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	settings, err := api.Database.GetOrganizationJITProvisioningSettings(ctx, organization.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Organizations without settings don't provision workspaces.
+		settings = database.OrganizationJITProvisioningSettings{
+			OrganizationID: organization.ID,
+		}
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization JIT provisioning settings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationJITProvisioningSettings(settings))
+}
+
+// @Summary Update organization JIT provisioning settings
+// @Description Settings apply to users that log in for the first time after the update.
+// @ID update-organization-jit-provisioning-settings
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param request body codersdk.UpdateOrganizationJITProvisioningSettingsRequest true "Update JIT provisioning settings request"
+// @Success 200 {object} codersdk.OrganizationJITProvisioningSettings
+// @Router /organizations/{organization}/settings/jit-provisioning [put]
+func (api *API) putOrganizationJITProvisioningSettings(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	var req codersdk.UpdateOrganizationJITProvisioningSettingsRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var templateID uuid.NullUUID
+	if req.TemplateID != uuid.Nil {
+		template, err := api.Database.GetTemplateByID(ctx, req.TemplateID)
+		if httpapi.Is404Error(err) || (err == nil && (template.Deleted || template.OrganizationID != organization.ID)) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid request to update organization JIT provisioning settings!",
+				Validations: []codersdk.ValidationError{{
+					Field:  "template_id",
+					Detail: fmt.Sprintf("Template %q doesn't exist in the organization.", req.TemplateID),
+				}},
+			})
+			return
+		}
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching template.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		templateID = uuid.NullUUID{UUID: template.ID, Valid: true}
+	}
+
+	now := database.Now()
+	settings, err := api.Database.UpsertOrganizationJITProvisioningSettings(ctx, database.UpsertOrganizationJITProvisioningSettingsParams{
+		OrganizationID: organization.ID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		TemplateID:     templateID,
+		WorkspaceName:  req.WorkspaceName,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating organization JIT provisioning settings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationJITProvisioningSettings(settings))
+}
+
+func (api *API) jitProvisioningEnabledMW(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		api.entitlementsMu.RLock()
+		enabled := api.entitlements.Features[codersdk.FeatureJITProvisioning].Enabled
+		api.entitlementsMu.RUnlock()
+		if !enabled {
+			httpapi.Write(r.Context(), rw, http.StatusForbidden, codersdk.Response{
+				Message: "JIT provisioning is an Enterprise feature. Contact sales!",
+			})
+			return
+		}
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// jitProvisionWorkspaces creates a workspace for a user that logged in for
+// the first time in every organization of the user that has a JIT
+// provisioning template. Failures are logged and never fail the login.
+func (api *API) jitProvisionWorkspaces(ctx context.Context, user database.User) {
+	logger := api.Logger.With(slog.F("user_id", user.ID), slog.F("username", user.Username))
+
+	//nolint:gocritic // The user has no API key on the context yet.
+	roles, err := api.Database.GetAuthorizationUserRoles(dbauthz.AsSystemRestricted(ctx), user.ID)
+	if err != nil {
+		logger.Warn(ctx, "get user roles for jit provisioning", slog.Error(err))
+		return
+	}
+	// Workspaces are created as the user, so template ACLs and quotas apply
+	// just as if the user created the workspace themselves.
+	userCtx := dbauthz.As(ctx, rbac.Subject{
+		ID:     user.ID.String(),
+		Roles:  rbac.RoleNames(roles.Roles),
+		Groups: roles.Groups,
+		Scope:  rbac.ScopeAll,
+	}.WithCachedASTValue())
+
+	memberships, err := api.Database.GetOrganizationMembershipsByUserID(userCtx, user.ID)
+	if err != nil {
+		logger.Warn(ctx, "get organization memberships for jit provisioning", slog.Error(err))
+		return
+	}
+	for _, membership := range memberships {
+		err := api.jitProvisionWorkspace(userCtx, user, membership.OrganizationID)
+		if err != nil {
+			logger.Warn(ctx, "jit provision workspace",
+				slog.F("organization_id", membership.OrganizationID), slog.Error(err))
+		}
+	}
+}
+
+func (api *API) jitProvisionWorkspace(ctx context.Context, user database.User, organizationID uuid.UUID) error {
+	settings, err := api.Database.GetOrganizationJITProvisioningSettings(ctx, organizationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("get settings: %w", err)
+	}
+	if !settings.TemplateID.Valid {
+		return nil
+	}
+
+	template, err := api.Database.GetTemplateByID(ctx, settings.TemplateID.UUID)
+	if err != nil {
+		return xerrors.Errorf("get template: %w", err)
+	}
+	if template.Deleted {
+		return nil
+	}
+
+	name := settings.WorkspaceName
+	if name == "" {
+		name = template.Name
+	}
+	_, err = api.Database.GetWorkspaceByOwnerIDAndName(ctx, database.GetWorkspaceByOwnerIDAndNameParams{
+		OwnerID: user.ID,
+		Name:    name,
+	})
+	if err == nil {
+		// The user already has a workspace with this name.
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("get workspace by name %q: %w", name, err)
+	}
+
+	templateSchedule, err := (*api.AGPL.TemplateScheduleStore.Load()).Get(ctx, api.Database, template.ID)
+	if err != nil {
+		return xerrors.Errorf("get template schedule: %w", err)
+	}
+	ttl := templateSchedule.DefaultTTL
+	if !templateSchedule.UseRestartRequirement && templateSchedule.MaxTTL > 0 && (ttl == 0 || ttl > templateSchedule.MaxTTL) {
+		ttl = templateSchedule.MaxTTL
+	}
+
+	var (
+		workspace      database.Workspace
+		workspaceBuild database.WorkspaceBuild
+		provisionerJob database.ProvisionerJob
+	)
+	err = api.Database.InTx(func(tx database.Store) error {
+		now := database.Now()
+		workspace, err = tx.InsertWorkspace(ctx, database.InsertWorkspaceParams{
+			ID:             uuid.New(),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			OwnerID:        user.ID,
+			OrganizationID: template.OrganizationID,
+			TemplateID:     template.ID,
+			Name:           name,
+			Ttl: sql.NullInt64{
+				Int64: int64(ttl),
+				Valid: ttl > 0,
+			},
+			LastUsedAt: now,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert workspace: %w", err)
+		}
+
+		// Quotas are enforced by the provisioner when the build is applied,
+		// just like for any other build.
+		builder := wsbuilder.New(workspace, database.WorkspaceTransitionStart).
+			Reason(database.BuildReasonInitiator).
+			Initiator(user.ID).
+			ActiveVersion()
+		build, job, err := builder.Build(ctx, tx, nil)
+		if err != nil {
+			return xerrors.Errorf("build workspace: %w", err)
+		}
+		workspaceBuild, provisionerJob = *build, *job
+		return nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	audit.BuildAudit(ctx, &audit.BuildAuditParams[database.Workspace]{
+		Audit:  *api.AGPL.Auditor.Load(),
+		Log:    api.Logger,
+		UserID: user.ID,
+		JobID:  provisionerJob.ID,
+		Status: http.StatusOK,
+		Action: database.AuditActionCreate,
+		New:    workspace,
+	})
+
+	api.Logger.Info(ctx, "jit provisioned workspace",
+		slog.F("user_id", user.ID),
+		slog.F("workspace_id", workspace.ID),
+		slog.F("workspace_build_id", workspaceBuild.ID),
+		slog.F("template_id", template.ID))
+	return nil
+}
+
+func convertOrganizationJITProvisioningSettings(settings database.OrganizationJITProvisioningSettings) codersdk.OrganizationJITProvisioningSettings {
+	return codersdk.OrganizationJITProvisioningSettings{
+		OrganizationID: settings.OrganizationID,
+		CreatedAt:      settings.CreatedAt,
+		UpdatedAt:      settings.UpdatedAt,
+		TemplateID:     settings.TemplateID.UUID,
+		WorkspaceName:  settings.WorkspaceName,
+	}
+}
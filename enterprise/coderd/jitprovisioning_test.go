@@ -0,0 +1,214 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationJITProvisioningSettings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureJITProvisioning: 1,
+				},
+			},
+		})
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Organizations don't provision workspaces by default.
+		settings, err := memberClient.OrganizationJITProvisioningSettings(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, user.OrganizationID, settings.OrganizationID)
+		require.Equal(t, uuid.Nil, settings.TemplateID)
+		require.Empty(t, settings.WorkspaceName)
+
+		// Members cannot change the settings.
+		_, err = memberClient.UpdateOrganizationJITProvisioningSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationJITProvisioningSettingsRequest{
+			TemplateID: template.ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		settings, err = client.UpdateOrganizationJITProvisioningSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationJITProvisioningSettingsRequest{
+			TemplateID:    template.ID,
+			WorkspaceName: "dev",
+		})
+		require.NoError(t, err)
+		require.Equal(t, template.ID, settings.TemplateID)
+		require.Equal(t, "dev", settings.WorkspaceName)
+
+		got, err := memberClient.OrganizationJITProvisioningSettings(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, settings, got)
+
+		// Clearing the template disables provisioning.
+		settings, err = client.UpdateOrganizationJITProvisioningSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationJITProvisioningSettingsRequest{})
+		require.NoError(t, err)
+		require.Equal(t, uuid.Nil, settings.TemplateID)
+	})
+
+	t.Run("InvalidTemplate", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureJITProvisioning: 1,
+				},
+			},
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.UpdateOrganizationJITProvisioningSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationJITProvisioningSettingsRequest{
+			TemplateID: uuid.New(),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "template_id", apiErr.Validations[0].Field)
+	})
+
+	t.Run("NotEntitled", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.OrganizationJITProvisioningSettings(ctx, user.OrganizationID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
+
+func TestJITProvisioning(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FirstLogin", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureJITProvisioning: 1,
+				},
+			},
+		})
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.UpdateOrganizationJITProvisioningSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationJITProvisioningSettingsRequest{
+			TemplateID:    template.ID,
+			WorkspaceName: "dev",
+		})
+		require.NoError(t, err)
+
+		memberClient, member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		// The workspace is created in the background after the login.
+		var workspace codersdk.Workspace
+		require.Eventually(t, func() bool {
+			workspace, err = memberClient.WorkspaceByOwnerAndName(ctx, codersdk.Me, "dev", codersdk.WorkspaceOptions{})
+			return err == nil
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, member.ID, workspace.OwnerID)
+		require.Equal(t, template.ID, workspace.TemplateID)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, workspace.LatestBuild.Transition)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		// Logging in again doesn't create another workspace.
+		_, err = memberClient.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
+			Email:    member.Email,
+			Password: "SomeSecurePassword!",
+		})
+		require.NoError(t, err)
+		workspaces, err := client.Workspaces(ctx, codersdk.WorkspaceFilter{Owner: member.Username})
+		require.NoError(t, err)
+		require.Len(t, workspaces.Workspaces, 1)
+	})
+
+	t.Run("TemplateName", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureJITProvisioning: 1,
+				},
+			},
+		})
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.UpdateOrganizationJITProvisioningSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationJITProvisioningSettingsRequest{
+			TemplateID: template.ID,
+		})
+		require.NoError(t, err)
+
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		// Workspaces are named after the template by default.
+		require.Eventually(t, func() bool {
+			_, err = memberClient.WorkspaceByOwnerAndName(ctx, codersdk.Me, template.Name, codersdk.WorkspaceOptions{})
+			return err == nil
+		}, testutil.WaitLong, testutil.IntervalFast)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureJITProvisioning: 1,
+				},
+			},
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		workspaces, err := memberClient.Workspaces(ctx, codersdk.WorkspaceFilter{Owner: codersdk.Me})
+		require.NoError(t, err)
+		require.Empty(t, workspaces.Workspaces)
+	})
+}
@@ -0,0 +1,133 @@
+package coderd
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+)
+
+const (
+	defaultBudgetWarningThresholdPercent  = 80
+	defaultBudgetCriticalThresholdPercent = 100
+)
+
+// @Summary Get organization budget settings
+// @ID get-organization-budget-settings
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Success 200 {object} codersdk.OrganizationBudgetSettings
+// @Router /organizations/{organization}/settings/budget [get]
+func (api *API) organizationBudgetSettings(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	settings, err := api.Database.GetOrganizationBudgetSettings(ctx, organization.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Organizations without settings have no budget.
+		settings = database.OrganizationBudgetSettings{
+			OrganizationID:           organization.ID,
+			WarningThresholdPercent:  defaultBudgetWarningThresholdPercent,
+			CriticalThresholdPercent: defaultBudgetCriticalThresholdPercent,
+		}
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization budget settings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationBudgetSettings(settings))
+}
+
+// @Summary Update organization budget settings
+// @Description Alerts are sent when the daily cost of the workspaces of the organization
+// @Description crosses a threshold of the budget.
+// @ID update-organization-budget-settings
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param request body codersdk.UpdateOrganizationBudgetSettingsRequest true "Update budget settings request"
+// @Success 200 {object} codersdk.OrganizationBudgetSettings
+// @Router /organizations/{organization}/settings/budget [put]
+func (api *API) putOrganizationBudgetSettings(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	var req codersdk.UpdateOrganizationBudgetSettingsRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var validErrs []codersdk.ValidationError
+	if req.DailyBudget < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "daily_budget", Detail: "Must be a positive integer."})
+	}
+	if req.WarningThresholdPercent <= 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "warning_threshold_percent", Detail: "Must be greater than zero."})
+	}
+	if req.CriticalThresholdPercent <= 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "critical_threshold_percent", Detail: "Must be greater than zero."})
+	}
+	if req.WarningThresholdPercent > req.CriticalThresholdPercent {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "warning_threshold_percent", Detail: "Must not be greater than the critical threshold."})
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid request to update organization budget settings!",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	now := database.Now()
+	settings, err := api.Database.UpsertOrganizationBudgetSettings(ctx, database.UpsertOrganizationBudgetSettingsParams{
+		OrganizationID:           organization.ID,
+		CreatedAt:                now,
+		UpdatedAt:                now,
+		DailyBudget:              req.DailyBudget,
+		WarningThresholdPercent:  req.WarningThresholdPercent,
+		CriticalThresholdPercent: req.CriticalThresholdPercent,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating organization budget settings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationBudgetSettings(settings))
+}
+
+func convertOrganizationBudgetSettings(settings database.OrganizationBudgetSettings) codersdk.OrganizationBudgetSettings {
+	return codersdk.OrganizationBudgetSettings{
+		OrganizationID:           settings.OrganizationID,
+		CreatedAt:                settings.CreatedAt,
+		UpdatedAt:                settings.UpdatedAt,
+		DailyBudget:              settings.DailyBudget,
+		WarningThresholdPercent:  settings.WarningThresholdPercent,
+		CriticalThresholdPercent: settings.CriticalThresholdPercent,
+		AlertedThresholdPercent:  settings.AlertedThresholdPercent,
+	}
+}
@@ -0,0 +1,103 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationBudgetSettings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureTemplateRBAC: 1,
+				},
+			},
+		})
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Organizations have no budget by default.
+		settings, err := memberClient.OrganizationBudgetSettings(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, user.OrganizationID, settings.OrganizationID)
+		require.Zero(t, settings.DailyBudget)
+		require.EqualValues(t, 80, settings.WarningThresholdPercent)
+		require.EqualValues(t, 100, settings.CriticalThresholdPercent)
+
+		req := codersdk.UpdateOrganizationBudgetSettingsRequest{
+			DailyBudget:              500,
+			WarningThresholdPercent:  75,
+			CriticalThresholdPercent: 90,
+		}
+
+		// Members cannot change the settings.
+		_, err = memberClient.UpdateOrganizationBudgetSettings(ctx, user.OrganizationID, req)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		settings, err = client.UpdateOrganizationBudgetSettings(ctx, user.OrganizationID, req)
+		require.NoError(t, err)
+		require.EqualValues(t, 500, settings.DailyBudget)
+		require.EqualValues(t, 75, settings.WarningThresholdPercent)
+		require.EqualValues(t, 90, settings.CriticalThresholdPercent)
+		require.Zero(t, settings.AlertedThresholdPercent)
+
+		got, err := memberClient.OrganizationBudgetSettings(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, settings, got)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureTemplateRBAC: 1,
+				},
+			},
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.UpdateOrganizationBudgetSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationBudgetSettingsRequest{
+			DailyBudget:              -1,
+			WarningThresholdPercent:  100,
+			CriticalThresholdPercent: 80,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 2)
+	})
+
+	t.Run("NotEntitled", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			DontAddLicense: true,
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.OrganizationBudgetSettings(ctx, user.OrganizationID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
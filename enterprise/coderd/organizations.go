@@ -0,0 +1,152 @@
+package coderd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/schedule"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get organization schedule settings
+// @ID get-organization-schedule-settings
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Success 200 {object} codersdk.OrganizationScheduleSettings
+// @Router /organizations/{organization}/settings/schedule [get]
+func (api *API) organizationScheduleSettings(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	settings, err := api.Database.GetOrganizationScheduleSettings(ctx, organization.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Organizations without settings have no defaults.
+		settings = database.OrganizationScheduleSettings{
+			OrganizationID: organization.ID,
+		}
+		err = nil
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization schedule settings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationScheduleSettings(settings))
+}
+
+// @Summary Update organization schedule settings
+// @Description Settings take effect from the next build of workspaces in the organization.
+// @ID update-organization-schedule-settings
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param request body codersdk.UpdateOrganizationScheduleSettingsRequest true "Update schedule settings request"
+// @Success 200 {object} codersdk.OrganizationScheduleSettings
+// @Router /organizations/{organization}/settings/schedule [put]
+func (api *API) putOrganizationScheduleSettings(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	var req codersdk.UpdateOrganizationScheduleSettingsRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var (
+		validErrs                          []codersdk.ValidationError
+		restartRequirementDaysOfWeekParsed uint8
+		quietHoursSchedule                 string
+		err                                error
+	)
+	if req.DefaultTTLMillis < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "default_ttl_ms", Detail: "Must be a positive integer."})
+	}
+	if len(req.RestartRequirement.DaysOfWeek) > 0 {
+		restartRequirementDaysOfWeekParsed, err = codersdk.WeekdaysToBitmap(req.RestartRequirement.DaysOfWeek)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.days_of_week", Detail: err.Error()})
+		}
+	}
+	if req.RestartRequirement.Weeks < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.weeks", Detail: "Must be a positive integer."})
+	}
+	if req.RestartRequirement.Weeks > schedule.MaxTemplateRestartRequirementWeeks {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.weeks", Detail: fmt.Sprintf("Must be less than %d.", schedule.MaxTemplateRestartRequirementWeeks)})
+	}
+	if strings.TrimSpace(req.DefaultQuietHoursSchedule) != "" {
+		sched, err := schedule.Daily(req.DefaultQuietHoursSchedule)
+		switch {
+		case err != nil:
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "default_quiet_hours_schedule", Detail: err.Error()})
+		case strings.HasPrefix(sched.Time(), "cron("):
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "default_quiet_hours_schedule", Detail: "Must be a single time of day, not a range or list of times."})
+		default:
+			// Use the tidy version when storing in the database.
+			quietHoursSchedule = sched.String()
+		}
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid request to update organization schedule settings!",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	now := database.Now()
+	settings, err := api.Database.UpsertOrganizationScheduleSettings(ctx, database.UpsertOrganizationScheduleSettingsParams{
+		OrganizationID:               organization.ID,
+		CreatedAt:                    now,
+		UpdatedAt:                    now,
+		DefaultTTL:                   int64(time.Duration(req.DefaultTTLMillis) * time.Millisecond),
+		RestartRequirementDaysOfWeek: int16(restartRequirementDaysOfWeekParsed),
+		RestartRequirementWeeks:      req.RestartRequirement.Weeks,
+		DefaultQuietHoursSchedule:    quietHoursSchedule,
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating organization schedule settings.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertOrganizationScheduleSettings(settings))
+}
+
+func convertOrganizationScheduleSettings(settings database.OrganizationScheduleSettings) codersdk.OrganizationScheduleSettings {
+	return codersdk.OrganizationScheduleSettings{
+		OrganizationID:   settings.OrganizationID,
+		CreatedAt:        settings.CreatedAt,
+		UpdatedAt:        settings.UpdatedAt,
+		DefaultTTLMillis: time.Duration(settings.DefaultTTL).Milliseconds(),
+		RestartRequirement: codersdk.TemplateRestartRequirement{
+			DaysOfWeek: codersdk.BitmapToWeekdays(uint8(settings.RestartRequirementDaysOfWeek)),
+			Weeks:      settings.RestartRequirementWeeks,
+		},
+		DefaultQuietHoursSchedule: settings.DefaultQuietHoursSchedule,
+	}
+}
@@ -0,0 +1,139 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/testutil"
+)
+
+func TestOrganizationScheduleSettings(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		const (
+			deploymentQuietHoursSchedule   = "CRON_TZ=America/Chicago 0 0 * * *"
+			organizationQuietHoursSchedule = "CRON_TZ=Europe/Berlin 0 1 * * *"
+		)
+		dv := coderdtest.DeploymentValues(t)
+		dv.UserQuietHoursSchedule.DefaultSchedule.Set(deploymentQuietHoursSchedule)
+		dv.Experiments.Set(string(codersdk.ExperimentTemplateRestartRequirement))
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues:         dv,
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+					codersdk.FeatureTemplateRestartRequirement: 1,
+				},
+			},
+		})
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Organizations have no defaults by default.
+		settings, err := memberClient.OrganizationScheduleSettings(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, user.OrganizationID, settings.OrganizationID)
+		require.Zero(t, settings.DefaultTTLMillis)
+		require.Empty(t, settings.RestartRequirement.DaysOfWeek)
+		require.Empty(t, settings.DefaultQuietHoursSchedule)
+
+		// Members cannot change the defaults.
+		_, err = memberClient.UpdateOrganizationScheduleSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationScheduleSettingsRequest{
+			DefaultTTLMillis: time.Hour.Milliseconds(),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		settings, err = client.UpdateOrganizationScheduleSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationScheduleSettingsRequest{
+			DefaultTTLMillis: (8 * time.Hour).Milliseconds(),
+			RestartRequirement: codersdk.TemplateRestartRequirement{
+				DaysOfWeek: []string{"saturday", "sunday"},
+				Weeks:      1,
+			},
+			DefaultQuietHoursSchedule: organizationQuietHoursSchedule,
+		})
+		require.NoError(t, err)
+		require.Equal(t, (8 * time.Hour).Milliseconds(), settings.DefaultTTLMillis)
+		require.Equal(t, []string{"saturday", "sunday"}, settings.RestartRequirement.DaysOfWeek)
+		require.EqualValues(t, 1, settings.RestartRequirement.Weeks)
+		require.Equal(t, organizationQuietHoursSchedule, settings.DefaultQuietHoursSchedule)
+
+		got, err := memberClient.OrganizationScheduleSettings(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Equal(t, settings, got)
+
+		// Members without a quiet hours schedule use the organization
+		// default.
+		sched, err := memberClient.UserQuietHoursSchedule(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, organizationQuietHoursSchedule, sched.RawSchedule)
+		require.False(t, sched.UserSet)
+
+		// Templates without a default TTL use the organization default.
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.DefaultTTLMillis = ptr.Ref(int64(0))
+		})
+		workspace := coderdtest.CreateWorkspace(t, memberClient, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.TTLMillis = nil
+		})
+		_ = coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		require.NotNil(t, workspace.TTLMillis)
+		require.Equal(t, (8 * time.Hour).Milliseconds(), *workspace.TTLMillis)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+				},
+			},
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.UpdateOrganizationScheduleSettings(ctx, user.OrganizationID, codersdk.UpdateOrganizationScheduleSettingsRequest{
+			DefaultTTLMillis: -1,
+			RestartRequirement: codersdk.TemplateRestartRequirement{
+				DaysOfWeek: []string{"caturday"},
+			},
+			DefaultQuietHoursSchedule: "CRON_TZ=UTC 0 1-2 * * *",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 3)
+	})
+
+	t.Run("NotEntitled", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.OrganizationScheduleSettings(ctx, user.OrganizationID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
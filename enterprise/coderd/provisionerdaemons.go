@@ -16,6 +16,7 @@ import (
 	"github.com/hashicorp/yamux"
 	"github.com/moby/moby/pkg/namesgenerator"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/maps"
 	"golang.org/x/xerrors"
 	"nhooyr.io/websocket"
 	"storj.io/drpc/drpcmux"
@@ -154,36 +155,56 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	provisionersMap := map[codersdk.ProvisionerType]struct{}{}
+	// Besides the built-in types, daemons serve provisioner plugins by any
+	// valid name.
+	provisionersMap := map[database.ProvisionerType]struct{}{}
 	for _, provisioner := range r.URL.Query()["provisioner"] {
-		switch provisioner {
-		case string(codersdk.ProvisionerTypeEcho):
-			provisionersMap[codersdk.ProvisionerTypeEcho] = struct{}{}
-		case string(codersdk.ProvisionerTypeTerraform):
-			provisionersMap[codersdk.ProvisionerTypeTerraform] = struct{}{}
-		default:
+		err := httpapi.ProvisionerTypeValid(provisioner)
+		if err != nil {
 			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
-				Message: fmt.Sprintf("Unknown provisioner type %q", provisioner),
+				Message: fmt.Sprintf("Invalid provisioner type %q.", provisioner),
+				Detail:  err.Error(),
 			})
 			return
 		}
+		provisionersMap[database.ProvisionerType(provisioner)] = struct{}{}
 	}
 
-	tags, authorized := api.provisionerDaemonAuth.authorize(r, tags)
-	if !authorized {
-		httpapi.Write(ctx, rw, http.StatusForbidden,
-			codersdk.Response{Message: "You aren't allowed to create provisioner daemons"})
-		return
+	// Daemons that authenticate with a provisioner key get the tags of the
+	// key, and only acquire jobs of the organization of the key.
+	var organizationID uuid.UUID
+	if rawKey := r.Header.Get(codersdk.ProvisionerDaemonKey); rawKey != "" {
+		provisionerKey, ok := api.authenticateProvisionerKey(r, rawKey)
+		if !ok {
+			httpapi.Write(ctx, rw, http.StatusForbidden,
+				codersdk.Response{Message: "The provisioner key is invalid or was revoked."})
+			return
+		}
+		// The tags of the key were extended with the scope when the key was
+		// created, so extend the tags of the daemon the same way before
+		// comparing them.
+		if len(tags) > 0 && !maps.Equal(provisionerdserver.MutateTags(uuid.Nil, tags), provisionerKey.Tags) {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Tags can't be set for provisioner daemons that authenticate with a provisioner key.",
+				Detail:  "The daemon gets the tags of the provisioner key.",
+			})
+			return
+		}
+		tags = provisionerKey.Tags
+		organizationID = provisionerKey.OrganizationID
+	} else {
+		var authorized bool
+		tags, authorized = api.provisionerDaemonAuth.authorize(r, tags)
+		if !authorized {
+			httpapi.Write(ctx, rw, http.StatusForbidden,
+				codersdk.Response{Message: "You aren't allowed to create provisioner daemons"})
+			return
+		}
 	}
 
-	provisioners := make([]database.ProvisionerType, 0)
+	provisioners := make([]database.ProvisionerType, 0, len(provisionersMap))
 	for p := range provisionersMap {
-		switch p {
-		case codersdk.ProvisionerTypeTerraform:
-			provisioners = append(provisioners, database.ProvisionerTypeTerraform)
-		case codersdk.ProvisionerTypeEcho:
-			provisioners = append(provisioners, database.ProvisionerTypeEcho)
-		}
+		provisioners = append(provisioners, p)
 	}
 
 	name := namesgenerator.GetRandomName(1)
@@ -248,6 +269,7 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 		GitAuthConfigs:              api.GitAuthConfigs,
 		OIDCConfig:                  api.OIDCConfig,
 		ID:                          daemon.ID,
+		OrganizationID:              organizationID,
 		Database:                    api.Database,
 		Pubsub:                      api.Pubsub,
 		Provisioners:                daemon.Provisioners,
@@ -255,6 +277,7 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 		Auditor:                     &api.AGPL.Auditor,
 		TemplateScheduleStore:       api.AGPL.TemplateScheduleStore,
 		UserQuietHoursScheduleStore: api.AGPL.UserQuietHoursScheduleStore,
+		ScheduleHolidays:            api.AGPL.ScheduleHolidays,
 		Logger:                      api.Logger.Named(fmt.Sprintf("provisionerd-%s", daemon.Name)),
 		Tags:                        rawTags,
 		Tracer:                      trace.NewNoopTracerProvider().Tracer("noop"),
@@ -288,6 +311,13 @@ func convertProvisionerDaemon(daemon database.ProvisionerDaemon) codersdk.Provis
 		UpdatedAt: daemon.UpdatedAt,
 		Name:      daemon.Name,
 		Tags:      daemon.Tags,
+		Cache: codersdk.ProvisionerDaemonCache{
+			Entries:      daemon.CacheEntries,
+			SizeBytes:    daemon.CacheSizeBytes,
+			MaxSizeBytes: daemon.CacheMaxSizeBytes,
+			Hits:         daemon.CacheHits,
+			Misses:       daemon.CacheMisses,
+		},
 	}
 	for _, provisionerType := range daemon.Provisioners {
 		result.Provisioners = append(result.Provisioners, codersdk.ProvisionerType(provisionerType))
@@ -0,0 +1,280 @@
+package coderd
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/cryptorand"
+)
+
+// @Summary Create provisioner key
+// @ID create-provisioner-key
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param request body codersdk.CreateProvisionerKeyRequest true "Create provisioner key request"
+// @Success 201 {object} codersdk.CreateProvisionerKeyResponse
+// @Router /organizations/{organization}/provisionerkeys [post]
+func (api *API) postProvisionerKey(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		organization      = httpmw.OrganizationParam(r)
+		auditor           = api.AGPL.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.ProvisionerKey](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	defer commitAudit()
+
+	var req codersdk.CreateProvisionerKeyRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	// Keys aren't owned by a user, so the daemons that authenticate with them
+	// can't be scoped to one.
+	if req.Tags[provisionerdserver.TagScope] == provisionerdserver.ScopeUser {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Provisioner keys can't be scoped to a user.",
+			Validations: []codersdk.ValidationError{
+				{Field: "tags", Detail: fmt.Sprintf("The %q tag must not be %q.", provisionerdserver.TagScope, provisionerdserver.ScopeUser)},
+			},
+		})
+		return
+	}
+
+	id := uuid.New()
+	key, hashedSecret, err := generateProvisionerKey(id)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	provisionerKey, err := api.Database.InsertProvisionerKey(ctx, database.InsertProvisionerKeyParams{
+		ID:             id,
+		CreatedAt:      database.Now(),
+		OrganizationID: organization.ID,
+		Name:           req.Name,
+		HashedSecret:   hashedSecret,
+		Tags:           provisionerdserver.MutateTags(uuid.Nil, req.Tags),
+	})
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("Provisioner key with name %q already exists.", req.Name),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating provisioner key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	aReq.New = provisionerKey
+	httpapi.Write(ctx, rw, http.StatusCreated, codersdk.CreateProvisionerKeyResponse{
+		ProvisionerKey: convertProvisionerKey(provisionerKey),
+		Key:            key,
+	})
+}
+
+// @Summary Get provisioner keys
+// @ID get-provisioner-keys
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Success 200 {array} codersdk.ProvisionerKey
+// @Router /organizations/{organization}/provisionerkeys [get]
+func (api *API) provisionerKeys(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx          = r.Context()
+		organization = httpmw.OrganizationParam(r)
+	)
+
+	keys, err := api.Database.GetProvisionerKeysByOrganizationID(ctx, organization.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner keys.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertProvisionerKeys(keys))
+}
+
+// Revokes a provisioner key. Provisioner daemons that are connected with the
+// key stay connected, but can't connect with it again.
+//
+// @Summary Delete provisioner key
+// @ID delete-provisioner-key
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param provisionerkey path string true "Provisioner key name"
+// @Success 204
+// @Router /organizations/{organization}/provisionerkeys/{provisionerkey} [delete]
+func (api *API) deleteProvisionerKey(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		organization      = httpmw.OrganizationParam(r)
+		auditor           = api.AGPL.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.ProvisionerKey](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionDelete,
+		})
+	)
+	defer commitAudit()
+
+	provisionerKey, err := api.Database.GetProvisionerKeyByOrganizationIDAndName(ctx, database.GetProvisionerKeyByOrganizationIDAndNameParams{
+		OrganizationID: organization.ID,
+		Name:           chi.URLParam(r, "provisionerkey"),
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.Old = provisionerKey
+
+	err = api.Database.DeleteProvisionerKey(ctx, provisionerKey.ID)
+	if dbauthz.IsNotAuthorizedError(err) {
+		httpapi.Forbidden(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting provisioner key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// authenticateProvisionerKey returns the provisioner key of the request. It
+// returns false if the key is invalid or was revoked. Every attempt to use a
+// key that exists is audited.
+func (api *API) authenticateProvisionerKey(r *http.Request, rawKey string) (database.ProvisionerKey, bool) {
+	ctx := r.Context()
+	parts := strings.Split(rawKey, ":")
+	if len(parts) != 2 || len(parts[1]) != 64 {
+		return database.ProvisionerKey{}, false
+	}
+	id, err := uuid.Parse(parts[0])
+	if err != nil {
+		return database.ProvisionerKey{}, false
+	}
+
+	// The provisioner daemon has no actor until it's authenticated.
+	//nolint:gocritic // System needs to read provisioner keys to authenticate them.
+	systemCtx := dbauthz.AsSystemRestricted(ctx)
+	provisionerKey, err := api.Database.GetProvisionerKeyByID(systemCtx, id)
+	if err != nil {
+		if !xerrors.Is(err, sql.ErrNoRows) {
+			api.Logger.Error(ctx, "get provisioner key", slog.Error(err))
+		}
+		return database.ProvisionerKey{}, false
+	}
+
+	hashedSecret := sha256.Sum256([]byte(parts[1]))
+	authenticated := subtle.ConstantTimeCompare(provisionerKey.HashedSecret, hashedSecret[:]) == 1
+
+	status := http.StatusSwitchingProtocols
+	if !authenticated {
+		status = http.StatusForbidden
+	}
+	audit.BuildAudit(ctx, &audit.BuildAuditParams[database.ProvisionerKey]{
+		Audit:     *api.AGPL.Auditor.Load(),
+		Log:       api.Logger,
+		JobID:     httpmw.RequestID(r),
+		Status:    status,
+		Action:    database.AuditActionLogin,
+		IP:        r.RemoteAddr,
+		UserAgent: r.UserAgent(),
+		// The key doesn't change, so the log has no diff.
+		Old: provisionerKey,
+		New: provisionerKey,
+	})
+	if !authenticated {
+		return database.ProvisionerKey{}, false
+	}
+
+	err = api.Database.UpdateProvisionerKeyLastUsedAt(systemCtx, database.UpdateProvisionerKeyLastUsedAtParams{
+		ID:         provisionerKey.ID,
+		LastUsedAt: sql.NullTime{Time: database.Now(), Valid: true},
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "update provisioner key last used at", slog.Error(err))
+	}
+	return provisionerKey, true
+}
+
+// generateProvisionerKey returns a key in the format "<id>:<secret>", and the
+// hash of the secret that is stored.
+func generateProvisionerKey(id uuid.UUID) (key string, hashedSecret []byte, err error) {
+	secret, err := cryptorand.HexString(64)
+	if err != nil {
+		return "", nil, xerrors.Errorf("generate secret: %w", err)
+	}
+	hashed := sha256.Sum256([]byte(secret))
+	return fmt.Sprintf("%s:%s", id, secret), hashed[:], nil
+}
+
+func convertProvisionerKey(key database.ProvisionerKey) codersdk.ProvisionerKey {
+	result := codersdk.ProvisionerKey{
+		ID:             key.ID,
+		CreatedAt:      key.CreatedAt,
+		OrganizationID: key.OrganizationID,
+		Name:           key.Name,
+		Tags:           key.Tags,
+	}
+	if key.LastUsedAt.Valid {
+		result.LastUsedAt = &key.LastUsedAt.Time
+	}
+	return result
+}
+
+func convertProvisionerKeys(keys []database.ProvisionerKey) []codersdk.ProvisionerKey {
+	result := make([]codersdk.ProvisionerKey, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, convertProvisionerKey(key))
+	}
+	return result
+}
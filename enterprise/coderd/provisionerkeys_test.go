@@ -0,0 +1,204 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/testutil"
+)
+
+func TestProvisionerKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureExternalProvisionerDaemons: 1,
+				},
+			},
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		created, err := client.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "ci",
+			Tags: map[string]string{"environment": "ci"},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, created.Key)
+		require.Equal(t, "ci", created.ProvisionerKey.Name)
+		// Keys are scoped to the organization by default.
+		require.Equal(t, map[string]string{
+			"environment":               "ci",
+			provisionerdserver.TagScope: provisionerdserver.ScopeOrganization,
+		}, created.ProvisionerKey.Tags)
+
+		_, err = client.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "ci",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+
+		keys, err := client.ProvisionerKeys(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		require.Equal(t, created.ProvisionerKey.ID, keys[0].ID)
+		require.Nil(t, keys[0].LastUsedAt)
+
+		err = client.DeleteProvisionerKey(ctx, user.OrganizationID, "ci")
+		require.NoError(t, err)
+		keys, err = client.ProvisionerKeys(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Empty(t, keys)
+
+		err = client.DeleteProvisionerKey(ctx, user.OrganizationID, "ci")
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("UserScope", func(t *testing.T) {
+		t.Parallel()
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureExternalProvisionerDaemons: 1,
+				},
+			},
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := client.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "mine",
+			Tags: map[string]string{provisionerdserver.TagScope: provisionerdserver.ScopeUser},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberForbidden", func(t *testing.T) {
+		t.Parallel()
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureExternalProvisionerDaemons: 1,
+				},
+			},
+		})
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		_, err := member.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "ci",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("Serve", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			AuditLogging: true,
+			Options: &coderdtest.Options{
+				Auditor: auditor,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureExternalProvisionerDaemons: 1,
+					codersdk.FeatureAuditLog:                   1,
+				},
+			},
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		created, err := client.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "ci",
+			Tags: map[string]string{"environment": "ci"},
+		})
+		require.NoError(t, err)
+
+		// The daemon doesn't need a session to authenticate with a key.
+		another := codersdk.New(client.URL)
+		srv, err := another.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+			Organization: user.OrganizationID,
+			Provisioners: []codersdk.ProvisionerType{
+				codersdk.ProvisionerTypeEcho,
+			},
+			ProvisionerKey: created.Key,
+		})
+		require.NoError(t, err)
+		err = srv.DRPCConn().Close()
+		require.NoError(t, err)
+
+		daemons, err := client.ProvisionerDaemons(ctx)
+		require.NoError(t, err)
+		require.Len(t, daemons, 1)
+		require.Equal(t, created.ProvisionerKey.Tags, daemons[0].Tags)
+
+		keys, err := client.ProvisionerKeys(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		require.NotNil(t, keys[0].LastUsedAt)
+
+		// Every use of the key is audited.
+		alogs := auditor.AuditLogs()
+		alog := alogs[len(alogs)-1]
+		require.Equal(t, database.AuditActionLogin, alog.Action)
+		require.Equal(t, database.ResourceTypeProvisionerKey, alog.ResourceType)
+		require.Equal(t, created.ProvisionerKey.ID, alog.ResourceID)
+		require.EqualValues(t, http.StatusSwitchingProtocols, alog.StatusCode)
+
+		// Daemons can repeat the tags of the key, without the scope that
+		// was added to them when the key was created.
+		srv, err = another.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+			Organization: user.OrganizationID,
+			Provisioners: []codersdk.ProvisionerType{
+				codersdk.ProvisionerTypeEcho,
+			},
+			Tags:           map[string]string{"environment": "ci"},
+			ProvisionerKey: created.Key,
+		})
+		require.NoError(t, err)
+		err = srv.DRPCConn().Close()
+		require.NoError(t, err)
+
+		// Daemons get the tags of the key.
+		_, err = another.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+			Organization: user.OrganizationID,
+			Provisioners: []codersdk.ProvisionerType{
+				codersdk.ProvisionerTypeEcho,
+			},
+			Tags:           map[string]string{"environment": "prod"},
+			ProvisionerKey: created.Key,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		// Revoked keys can't be used anymore.
+		err = client.DeleteProvisionerKey(ctx, user.OrganizationID, "ci")
+		require.NoError(t, err)
+		_, err = another.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+			Organization: user.OrganizationID,
+			Provisioners: []codersdk.ProvisionerType{
+				codersdk.ProvisionerTypeEcho,
+			},
+			ProvisionerKey: created.Key,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
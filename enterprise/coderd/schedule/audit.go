@@ -0,0 +1,53 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	agpl "github.com/coder/coder/coderd/schedule"
+)
+
+// auditScheduleChange exports an audit log for a schedule change made outside
+// of an HTTP request handler. The actor in the context is recorded as the user
+// that made the change. Nothing is exported if auditor is nil.
+func auditScheduleChange[T audit.Auditable](ctx context.Context, auditor *atomic.Pointer[audit.Auditor], log slog.Logger, requestID uuid.UUID, status int, old, new T, fields agpl.TemplateScheduleChanges) {
+	if auditor == nil {
+		return
+	}
+	a := auditor.Load()
+	if a == nil {
+		return
+	}
+
+	var userID uuid.UUID
+	if actor, ok := dbauthz.ActorFromContext(ctx); ok {
+		// System actors don't have a user ID, so the audit log is attributed
+		// to no user.
+		userID, _ = uuid.Parse(actor.ID)
+	}
+
+	additionalFields, err := json.Marshal(fields)
+	if err != nil {
+		log.Warn(ctx, "marshal schedule audit fields", slog.Error(err))
+		additionalFields = nil
+	}
+
+	audit.BuildAudit(ctx, &audit.BuildAuditParams[T]{
+		Audit:            *a,
+		Log:              log,
+		UserID:           userID,
+		JobID:            requestID,
+		Status:           status,
+		Action:           database.AuditActionWrite,
+		AdditionalFields: additionalFields,
+		Old:              old,
+		New:              new,
+	})
+}
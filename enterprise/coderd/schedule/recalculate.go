@@ -0,0 +1,347 @@
+package schedule
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	agpl "github.com/coder/coder/coderd/schedule"
+	"github.com/coder/retry"
+)
+
+const (
+	// DefaultDeadlineRecalculationBatchSize is the default number of workspace
+	// builds updated per transaction.
+	DefaultDeadlineRecalculationBatchSize = 100
+	// DefaultDeadlineRecalculationMaxAttempts is the default number of times a
+	// batch is attempted before the job is marked as failed.
+	DefaultDeadlineRecalculationMaxAttempts = 5
+	// DefaultDeadlineRecalculationPollInterval is the default interval at
+	// which the worker looks for queued jobs.
+	DefaultDeadlineRecalculationPollInterval = 5 * time.Second
+
+	// deadlineRecalculationRetention is how long finished jobs are kept around
+	// so their result can be fetched.
+	deadlineRecalculationRetention = time.Hour
+	// deadlineRecalculationStaleTimeout is how long a running job may go
+	// without progress before another replica picks it up, e.g. because the
+	// replica running it was stopped.
+	deadlineRecalculationStaleTimeout = 5 * time.Minute
+)
+
+// DeadlineRecalculator recalculates the deadline and max_deadline of the active
+// workspace builds of templates in the background. Jobs are stored in the
+// database, so they are processed by whichever replica acquires them first and
+// survive restarts. Builds are updated in batches so a template with many
+// workspaces does not hold a single transaction open for the whole
+// recalculation.
+type DeadlineRecalculator struct {
+	// BatchSize is the number of workspace builds updated per transaction.
+	BatchSize int
+	// MaxAttempts is the number of times a batch is attempted before the job
+	// is marked as failed.
+	MaxAttempts int
+
+	ctx                   context.Context
+	cancel                context.CancelFunc
+	logger                slog.Logger
+	db                    database.Store
+	templateScheduleStore *atomic.Pointer[agpl.TemplateScheduleStore]
+	pollInterval          time.Duration
+
+	mu     sync.Mutex
+	notify chan struct{}
+	done   chan struct{}
+}
+
+// NewDeadlineRecalculator creates a DeadlineRecalculator and starts its worker.
+// Jobs are processed with the template schedule store, which must be an
+// *EnterpriseTemplateScheduleStore. Close must be called to stop the worker.
+func NewDeadlineRecalculator(ctx context.Context, logger slog.Logger, db database.Store, templateScheduleStore *atomic.Pointer[agpl.TemplateScheduleStore], pollInterval time.Duration) *DeadlineRecalculator {
+	if pollInterval <= 0 {
+		pollInterval = DefaultDeadlineRecalculationPollInterval
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	d := &DeadlineRecalculator{
+		BatchSize:             DefaultDeadlineRecalculationBatchSize,
+		MaxAttempts:           DefaultDeadlineRecalculationMaxAttempts,
+		ctx:                   ctx,
+		cancel:                cancel,
+		logger:                logger,
+		db:                    db,
+		templateScheduleStore: templateScheduleStore,
+		pollInterval:          pollInterval,
+		notify:                make(chan struct{}, 1),
+		done:                  make(chan struct{}),
+	}
+	go d.run()
+	return d
+}
+
+// Enqueue queues a recalculation of the workspace builds of the template in
+// db. If db is a transaction, the job is only visible to the workers once the
+// transaction is committed, so it always sees the schedule the transaction
+// changed. If a job for the template is already pending, that job is returned
+// instead and created is false, as it will use the latest template schedule
+// when it runs.
+//
+// The caller must authorize the recalculation, as schedule changes also
+// recalculate the templates that inherit the changed schedule.
+func (d *DeadlineRecalculator) Enqueue(ctx context.Context, db database.Store, templateID uuid.UUID) (job database.TemplateDeadlineRecalculation, created bool, err error) {
+	id := uuid.New()
+	//nolint:gocritic // The caller authorizes the recalculation.
+	job, err = db.InsertTemplateDeadlineRecalculation(dbauthz.AsSystemRestricted(ctx), database.InsertTemplateDeadlineRecalculationParams{
+		ID:         id,
+		TemplateID: templateID,
+		CreatedAt:  database.Now(),
+	})
+	if err != nil {
+		return database.TemplateDeadlineRecalculation{}, false, xerrors.Errorf("insert template deadline recalculation: %w", err)
+	}
+	d.wake()
+	return job, job.ID == id, nil
+}
+
+// wake makes the worker look for queued jobs. Jobs queued in a transaction that
+// is not committed yet are found on the next poll.
+func (d *DeadlineRecalculator) wake() {
+	select {
+	case d.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Close stops the worker and waits for it to exit. Running jobs are picked up
+// again once they are stale.
+func (d *DeadlineRecalculator) Close() {
+	d.cancel()
+	<-d.done
+}
+
+func (d *DeadlineRecalculator) run() {
+	defer close(d.done)
+
+	//nolint:gocritic // Jobs are authorized when they are queued, and need to
+	// update the workspace builds of all users on the template.
+	ctx := dbauthz.AsSystemRestricted(d.ctx)
+	ticker := time.NewTicker(d.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.notify:
+		}
+
+		err := d.db.DeleteOldTemplateDeadlineRecalculations(ctx, database.Now().Add(-deadlineRecalculationRetention))
+		if err != nil && ctx.Err() == nil {
+			d.logger.Warn(ctx, "delete old template deadline recalculations", slog.Error(err))
+		}
+
+		for ctx.Err() == nil {
+			now := database.Now()
+			job, err := d.db.AcquireTemplateDeadlineRecalculation(ctx, database.AcquireTemplateDeadlineRecalculationParams{
+				Now:         now,
+				StaleBefore: now.Add(-deadlineRecalculationStaleTimeout),
+			})
+			if errors.Is(err, sql.ErrNoRows) {
+				break
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					d.logger.Warn(ctx, "acquire template deadline recalculation", slog.Error(err))
+				}
+				break
+			}
+
+			store, template, changes, err := d.process(ctx, job)
+			if err != nil {
+				d.logger.Warn(ctx, "recalculate template workspace build deadlines",
+					slog.F("template_id", job.TemplateID),
+					slog.F("job_id", job.ID),
+					slog.Error(err),
+				)
+			}
+			if ctx.Err() != nil {
+				// Leave the job running, so it is picked up again once it
+				// is stale.
+				return
+			}
+			d.complete(ctx, job, err)
+			if store != nil {
+				d.audit(ctx, store, job, template, changes, err)
+			}
+		}
+	}
+}
+
+// process recalculates the workspace build deadlines of the job's template
+// with the enterprise template schedule store. The changed deadlines are
+// returned even if the job fails partway through.
+func (d *DeadlineRecalculator) process(ctx context.Context, job database.TemplateDeadlineRecalculation) (*EnterpriseTemplateScheduleStore, database.Template, []agpl.WorkspaceBuildDeadlineChange, error) {
+	var store *EnterpriseTemplateScheduleStore
+	if ptr := d.templateScheduleStore.Load(); ptr != nil {
+		store, _ = (*ptr).(*EnterpriseTemplateScheduleStore)
+	}
+	if store == nil {
+		return nil, database.Template{}, nil, xerrors.New("advanced template scheduling is not enabled")
+	}
+
+	d.mu.Lock()
+	batchSize, maxAttempts := d.BatchSize, d.MaxAttempts
+	d.mu.Unlock()
+	if batchSize <= 0 {
+		batchSize = DefaultDeadlineRecalculationBatchSize
+	}
+
+	var (
+		template database.Template
+		builds   []database.WorkspaceBuild
+		changes  []agpl.WorkspaceBuildDeadlineChange
+	)
+	err := d.attempt(ctx, job, maxAttempts, func() error {
+		var err error
+		template, err = d.db.GetTemplateByID(ctx, job.TemplateID)
+		if err != nil {
+			return xerrors.Errorf("get template: %w", err)
+		}
+		builds, err = d.db.GetActiveWorkspaceBuildsByTemplateID(ctx, job.TemplateID)
+		if err != nil {
+			return xerrors.Errorf("get active workspace builds: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return store, template, nil, err
+	}
+	d.progress(ctx, job.ID, 0, len(builds))
+
+	for start := 0; start < len(builds); start += batchSize {
+		end := start + batchSize
+		if end > len(builds) {
+			end = len(builds)
+		}
+		batch := builds[start:end]
+
+		var batchChanges []agpl.WorkspaceBuildDeadlineChange
+		err := d.attempt(ctx, job, maxAttempts, func() error {
+			// Only keep the changes of the attempt that was committed.
+			batchChanges = nil
+			return d.db.InTx(func(db database.Store) error {
+				for _, build := range batch {
+					// Fetch the build again in case it changed since the
+					// job started, e.g. if the deadline was extended.
+					latest, err := db.GetWorkspaceBuildByID(ctx, build.ID)
+					if err != nil {
+						return xerrors.Errorf("get workspace build %q: %w", build.ID, err)
+					}
+					change, changed, err := store.updateWorkspaceBuild(ctx, db, latest)
+					if err != nil {
+						return xerrors.Errorf("update workspace build %q: %w", build.ID, err)
+					}
+					if changed {
+						batchChanges = append(batchChanges, change)
+					}
+				}
+				return nil
+			}, nil)
+		})
+		if err != nil {
+			return store, template, changes, err
+		}
+		changes = append(changes, batchChanges...)
+		d.progress(ctx, job.ID, end, len(builds))
+	}
+
+	return store, template, changes, nil
+}
+
+// audit exports an audit log of the workspaces whose build deadlines were
+// recalculated by the job. The job ID is the request ID of the audit log, so it
+// can be matched with the audit log of the schedule change that queued it.
+func (d *DeadlineRecalculator) audit(ctx context.Context, store *EnterpriseTemplateScheduleStore, job database.TemplateDeadlineRecalculation, template database.Template, changes []agpl.WorkspaceBuildDeadlineChange, err error) {
+	if len(changes) == 0 {
+		return
+	}
+	status := http.StatusOK
+	if err != nil {
+		status = http.StatusInternalServerError
+	}
+	auditScheduleChange(ctx, store.Auditor, d.logger, job.ID, status, template, template, agpl.TemplateScheduleChanges{
+		RecalculatedWorkspaces: agpl.ConvertRecalculatedWorkspaces(changes),
+	})
+}
+
+// attempt calls fn until it succeeds or has been called maxAttempts times,
+// backing off between attempts.
+func (d *DeadlineRecalculator) attempt(ctx context.Context, job database.TemplateDeadlineRecalculation, maxAttempts int, fn func() error) error {
+	var (
+		err      error
+		attempts int
+	)
+	for r := retry.New(50*time.Millisecond, 5*time.Second); r.Wait(ctx); {
+		attempts++
+		err = fn()
+		if err == nil || attempts >= maxAttempts {
+			return err
+		}
+		d.logger.Debug(ctx, "retrying template workspace build deadline recalculation",
+			slog.F("template_id", job.TemplateID),
+			slog.F("job_id", job.ID),
+			slog.F("attempt", attempts),
+			slog.Error(err),
+		)
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+	return err
+}
+
+// progress records the progress of the job. It also keeps the job from going
+// stale while it is running.
+func (d *DeadlineRecalculator) progress(ctx context.Context, id uuid.UUID, processed, total int) {
+	err := d.db.UpdateTemplateDeadlineRecalculationProgressByID(ctx, database.UpdateTemplateDeadlineRecalculationProgressByIDParams{
+		ID:              id,
+		UpdatedAt:       database.Now(),
+		TotalBuilds:     int32(total),
+		ProcessedBuilds: int32(processed),
+	})
+	if err != nil && ctx.Err() == nil {
+		d.logger.Warn(ctx, "update template deadline recalculation progress",
+			slog.F("job_id", id),
+			slog.Error(err),
+		)
+	}
+}
+
+func (d *DeadlineRecalculator) complete(ctx context.Context, job database.TemplateDeadlineRecalculation, jobErr error) {
+	params := database.UpdateTemplateDeadlineRecalculationCompletedByIDParams{
+		ID:        job.ID,
+		UpdatedAt: database.Now(),
+		Status:    database.TemplateDeadlineRecalculationStatusSucceeded,
+	}
+	if jobErr != nil {
+		params.Status = database.TemplateDeadlineRecalculationStatusFailed
+		params.Error = jobErr.Error()
+	}
+	err := d.db.UpdateTemplateDeadlineRecalculationCompletedByID(ctx, params)
+	if err != nil {
+		d.logger.Warn(ctx, "complete template deadline recalculation",
+			slog.F("job_id", job.ID),
+			slog.Error(err),
+		)
+	}
+}
@@ -0,0 +1,264 @@
+package schedule_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/database/dbtestutil"
+	agplschedule "github.com/coder/coder/coderd/schedule"
+	"github.com/coder/coder/enterprise/coderd/schedule"
+	"github.com/coder/coder/testutil"
+)
+
+func TestDeadlineRecalculator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Batches", func(t *testing.T) {
+		t.Parallel()
+
+		db, _ := dbtestutil.NewDB(t)
+
+		var (
+			org  = dbgen.Organization(t, db, database.Organization{})
+			user = dbgen.User(t, db, database.User{})
+			file = dbgen.File(t, db, database.File{
+				CreatedBy: user.ID,
+			})
+			templateJob = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+				OrganizationID: org.ID,
+				FileID:         file.ID,
+				InitiatorID:    user.ID,
+				Tags: database.StringMap{
+					"foo": "bar",
+				},
+			})
+			templateVersion = dbgen.TemplateVersion(t, db, database.TemplateVersion{
+				OrganizationID: org.ID,
+				CreatedBy:      user.ID,
+				JobID:          templateJob.ID,
+			})
+			template = dbgen.Template(t, db, database.Template{
+				OrganizationID:  org.ID,
+				ActiveVersionID: templateVersion.ID,
+				CreatedBy:       user.ID,
+			})
+		)
+
+		const userQuietHoursSchedule = "CRON_TZ=UTC 0 0 * * *" // midnight UTC
+		ctx := testutil.Context(t, testutil.WaitLong)
+		user, err := db.UpdateUserQuietHoursSchedule(ctx, database.UpdateUserQuietHoursScheduleParams{
+			ID:                 user.ID,
+			QuietHoursSchedule: userQuietHoursSchedule,
+		})
+		require.NoError(t, err)
+
+		realNow := time.Now().UTC()
+		nowY, nowM, nowD := realNow.Date()
+		buildTime := time.Date(nowY, nowM, nowD, 12, 0, 0, 0, time.UTC)       // noon today UTC
+		nextQuietHours := time.Date(nowY, nowM, nowD+1, 0, 0, 0, 0, time.UTC) // midnight tomorrow UTC
+
+		// Create more builds than fit in a single batch.
+		const numBuilds = 5
+		buildIDs := make([]uuid.UUID, 0, numBuilds)
+		for i := 0; i < numBuilds; i++ {
+			wsBuild := createCompletedWorkspaceBuild(t, db, template, templateVersion.ID, user.ID, buildTime, buildTime, nextQuietHours.Add(24*time.Hour))
+			buildIDs = append(buildIDs, wsBuild.ID)
+		}
+
+		userQuietHoursStore, err := schedule.NewEnterpriseUserQuietHoursScheduleStore(userQuietHoursSchedule, nil, slogtest.Make(t, nil))
+		require.NoError(t, err)
+		userQuietHoursStorePtr := &atomic.Pointer[agplschedule.UserQuietHoursScheduleStore]{}
+		userQuietHoursStorePtr.Store(&userQuietHoursStore)
+
+		templateScheduleStore := schedule.NewEnterpriseTemplateScheduleStore(userQuietHoursStorePtr)
+		templateScheduleStorePtr := &atomic.Pointer[agplschedule.TemplateScheduleStore]{}
+		templateScheduleStoreInterface := agplschedule.TemplateScheduleStore(templateScheduleStore)
+		templateScheduleStorePtr.Store(&templateScheduleStoreInterface)
+
+		recalculator := schedule.NewDeadlineRecalculator(ctx, slogtest.Make(t, nil), db, templateScheduleStorePtr, testutil.IntervalFast)
+		t.Cleanup(recalculator.Close)
+		recalculator.BatchSize = 2
+
+		templateScheduleStore.UseRestartRequirement.Store(true)
+		templateScheduleStore.DeadlineRecalculator = recalculator
+		templateScheduleStore.TimeNowFn = func() time.Time {
+			return nextQuietHours.Add(-6 * time.Hour)
+		}
+		template, changes, err := templateScheduleStore.Set(ctx, db, template, agplschedule.TemplateScheduleOptions{
+			UseRestartRequirement: true,
+			RestartRequirement: agplschedule.TemplateRestartRequirement{
+				// Every day
+				DaysOfWeek: 0b01111111,
+				Weeks:      0,
+			},
+		})
+		require.NoError(t, err)
+
+		// The update queues a job instead of updating the builds inline.
+		var jobs []database.TemplateDeadlineRecalculation
+		require.Eventually(t, func() bool {
+			jobs, err = db.GetTemplateDeadlineRecalculationsByTemplateID(ctx, template.ID)
+			return err == nil && len(jobs) == 1 && jobs[0].CompletedAt.Valid
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, database.TemplateDeadlineRecalculationStatusSucceeded, jobs[0].Status, jobs[0].Error)
+		require.Equal(t, []uuid.UUID{jobs[0].ID}, changes.DeadlineRecalculationJobIDs)
+		require.EqualValues(t, numBuilds, jobs[0].TotalBuilds)
+		require.EqualValues(t, numBuilds, jobs[0].ProcessedBuilds)
+
+		for _, id := range buildIDs {
+			build, err := db.GetWorkspaceBuildByID(ctx, id)
+			require.NoError(t, err)
+			assert.WithinDuration(t, buildTime, build.Deadline, time.Second)
+			assert.WithinDuration(t, nextQuietHours, build.MaxDeadline, time.Second)
+		}
+	})
+
+	t.Run("Coalesce", func(t *testing.T) {
+		t.Parallel()
+
+		db, _ := dbtestutil.NewDB(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Stop the worker so jobs stay in the queue.
+		recalculator := schedule.NewDeadlineRecalculator(ctx, slogtest.Make(t, nil), db, &atomic.Pointer[agplschedule.TemplateScheduleStore]{}, 0)
+		recalculator.Close()
+
+		template := dbgen.Template(t, db, database.Template{})
+		other := dbgen.Template(t, db, database.Template{})
+
+		first, created, err := recalculator.Enqueue(ctx, db, template.ID)
+		require.NoError(t, err)
+		require.True(t, created)
+		require.Equal(t, database.TemplateDeadlineRecalculationStatusPending, first.Status)
+
+		// Pending jobs for the same template are reused.
+		second, created, err := recalculator.Enqueue(ctx, db, template.ID)
+		require.NoError(t, err)
+		require.False(t, created)
+		require.Equal(t, first.ID, second.ID)
+
+		// Jobs for other templates are not.
+		third, created, err := recalculator.Enqueue(ctx, db, other.ID)
+		require.NoError(t, err)
+		require.True(t, created)
+		require.NotEqual(t, first.ID, third.ID)
+
+		jobs, err := db.GetTemplateDeadlineRecalculationsByTemplateID(ctx, template.ID)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+		jobs, err = db.GetTemplateDeadlineRecalculationsByTemplateID(ctx, other.ID)
+		require.NoError(t, err)
+		require.Len(t, jobs, 1)
+	})
+
+	t.Run("RolledBack", func(t *testing.T) {
+		t.Parallel()
+		if !dbtestutil.WillUsePostgres() {
+			t.Skip("dbfake does not roll back transactions")
+		}
+
+		db, _ := dbtestutil.NewDB(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		recalculator := schedule.NewDeadlineRecalculator(ctx, slogtest.Make(t, nil), db, &atomic.Pointer[agplschedule.TemplateScheduleStore]{}, 0)
+		recalculator.Close()
+
+		template := dbgen.Template(t, db, database.Template{})
+
+		// Jobs queued in a transaction that is rolled back are never run.
+		errRollback := xerrors.New("rollback")
+		err := db.InTx(func(tx database.Store) error {
+			_, _, err := recalculator.Enqueue(ctx, tx, template.ID)
+			require.NoError(t, err)
+			return errRollback
+		}, nil)
+		require.ErrorIs(t, err, errRollback)
+
+		jobs, err := db.GetTemplateDeadlineRecalculationsByTemplateID(ctx, template.ID)
+		require.NoError(t, err)
+		require.Empty(t, jobs)
+	})
+}
+
+// createCompletedWorkspaceBuild creates a workspace on the template with a
+// build that completed at buildTime and has the given deadlines.
+func createCompletedWorkspaceBuild(t *testing.T, db database.Store, template database.Template, templateVersionID, userID uuid.UUID, buildTime, deadline, maxDeadline time.Time) database.WorkspaceBuild {
+	t.Helper()
+
+	ctx := testutil.Context(t, testutil.WaitShort)
+	file := dbgen.File(t, db, database.File{
+		CreatedBy: userID,
+	})
+	ws := dbgen.Workspace(t, db, database.Workspace{
+		OrganizationID: template.OrganizationID,
+		OwnerID:        userID,
+		TemplateID:     template.ID,
+	})
+	tag := uuid.NewString()
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		OrganizationID: template.OrganizationID,
+		FileID:         file.ID,
+		InitiatorID:    userID,
+		Provisioner:    database.ProvisionerTypeEcho,
+		Tags: database.StringMap{
+			tag: "yeah",
+		},
+	})
+	wsBuild := dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+		WorkspaceID:       ws.ID,
+		BuildNumber:       1,
+		JobID:             job.ID,
+		InitiatorID:       userID,
+		TemplateVersionID: templateVersionID,
+	})
+
+	acquiredJob, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+		StartedAt: sql.NullTime{
+			Time:  buildTime,
+			Valid: true,
+		},
+		WorkerID: uuid.NullUUID{
+			UUID:  uuid.New(),
+			Valid: true,
+		},
+		Types: []string{string(database.ProvisionerTypeEcho)},
+		Tags:  json.RawMessage(fmt.Sprintf(`{%q: "yeah"}`, tag)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, job.ID, acquiredJob.ID)
+	err = db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+		ID: job.ID,
+		CompletedAt: sql.NullTime{
+			Time:  buildTime,
+			Valid: true,
+		},
+		UpdatedAt: buildTime,
+	})
+	require.NoError(t, err)
+
+	err = db.UpdateWorkspaceBuildByID(ctx, database.UpdateWorkspaceBuildByIDParams{
+		ID:               wsBuild.ID,
+		UpdatedAt:        buildTime,
+		ProvisionerState: []byte{},
+		Deadline:         deadline,
+		MaxDeadline:      maxDeadline,
+	})
+	require.NoError(t, err)
+
+	wsBuild, err = db.GetWorkspaceBuildByID(ctx, wsBuild.ID)
+	require.NoError(t, err)
+	return wsBuild
+}
@@ -2,6 +2,8 @@ package schedule
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"sync/atomic"
 	"time"
 
@@ -10,6 +12,8 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/xerrors"
 
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/db2sdk"
 	"github.com/coder/coder/coderd/database/dbauthz"
@@ -27,10 +31,31 @@ type EnterpriseTemplateScheduleStore struct {
 	// and whether a default user quiet hours schedule is set.
 	UseRestartRequirement atomic.Bool
 
+	// UseWorkspaceScheduleOverrides decides whether per-workspace schedule
+	// overrides should be applied on top of the template's scheduling options.
+	// This value is determined by a feature flag.
+	UseWorkspaceScheduleOverrides atomic.Bool
+
 	// UserQuietHoursScheduleStore is used when recalculating build deadlines on
 	// update.
 	UserQuietHoursScheduleStore *atomic.Pointer[agpl.UserQuietHoursScheduleStore]
 
+	// Holidays caches the schedule holidays used when recalculating build
+	// deadlines. If nil, they're loaded from the database.
+	Holidays *agpl.HolidayCache
+
+	// DeadlineRecalculator is used to recalculate build deadlines in the
+	// background after the template schedule is updated. If nil, the builds
+	// are recalculated in the same transaction as the update.
+	DeadlineRecalculator *DeadlineRecalculator
+
+	// Auditor is used to audit the workspace build deadlines recalculated by
+	// DeadlineRecalculator. Schedule changes made by Set and
+	// SetSchedulePolicy are returned to the caller to audit instead. If nil,
+	// nothing is audited.
+	Auditor *atomic.Pointer[audit.Auditor]
+	Logger  slog.Logger
+
 	// Custom time.Now() function to use in tests. Defaults to database.Now().
 	TimeNowFn func() time.Time
 }
@@ -60,6 +85,34 @@ func (s *EnterpriseTemplateScheduleStore) Get(ctx context.Context, db database.S
 		return agpl.TemplateScheduleOptions{}, err
 	}
 
+	// Templates that reference a schedule policy template use the schedule
+	// settings of that template instead of their own.
+	tpl, err = agpl.ResolveSchedulePolicy(ctx, db, tpl)
+	if err != nil {
+		return agpl.TemplateScheduleOptions{}, err
+	}
+
+	// Templates that don't set a default TTL or restart requirement use the
+	// defaults of their organization, if any. Templates that disable autostop
+	// keep a zero default TTL.
+	if (tpl.DefaultTTL == 0 && !tpl.DisableAutostop) || tpl.RestartRequirementDaysOfWeek == 0 {
+		//nolint:gocritic // The organization defaults apply to everyone
+		// that can read the template.
+		settings, err := db.GetOrganizationScheduleSettings(dbauthz.AsSystemRestricted(ctx), tpl.OrganizationID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return agpl.TemplateScheduleOptions{}, xerrors.Errorf("get organization schedule settings: %w", err)
+		}
+		if err == nil {
+			if tpl.DefaultTTL == 0 && !tpl.DisableAutostop {
+				tpl.DefaultTTL = settings.DefaultTTL
+			}
+			if tpl.RestartRequirementDaysOfWeek == 0 {
+				tpl.RestartRequirementDaysOfWeek = settings.RestartRequirementDaysOfWeek
+				tpl.RestartRequirementWeeks = settings.RestartRequirementWeeks
+			}
+		}
+	}
+
 	// These extra checks have to be done before the conversion because we lose
 	// precision and signs when converting to the agpl types from the database.
 	if tpl.RestartRequirementDaysOfWeek < 0 {
@@ -82,15 +135,104 @@ func (s *EnterpriseTemplateScheduleStore) Get(ctx context.Context, db database.S
 		RestartRequirement: agpl.TemplateRestartRequirement{
 			DaysOfWeek: uint8(tpl.RestartRequirementDaysOfWeek),
 			Weeks:      tpl.RestartRequirementWeeks,
+			Timezone:   tpl.RestartRequirementTimezone,
 		},
-		FailureTTL:    time.Duration(tpl.FailureTTL),
-		InactivityTTL: time.Duration(tpl.InactivityTTL),
-		LockedTTL:     time.Duration(tpl.LockedTTL),
+		RestartRequirementSpread: time.Duration(tpl.RestartRequirementSpread),
+		UpdateOnRestart:          tpl.UpdateOnRestart,
+		FailureTTL:               time.Duration(tpl.FailureTTL),
+		FailureRetries:           tpl.FailureRetries,
+		FailureRetryBackoff:      time.Duration(tpl.FailureRetryBackoff),
+		InactivityTTL:            time.Duration(tpl.InactivityTTL),
+		LockedTTL:                time.Duration(tpl.LockedTTL),
+		MaxLifetime:              time.Duration(tpl.MaxLifetime),
+		ArchiveRetention:         time.Duration(tpl.ArchiveRetention),
+		ActivityBumpTTL:          time.Duration(tpl.ActivityBumpTTL),
 	}, nil
 }
 
+// schedulePolicyDependents returns all templates that inherit their schedule
+// settings from the template, directly or through other policy templates.
+func schedulePolicyDependents(ctx context.Context, db database.Store, templateID uuid.UUID) ([]database.Template, error) {
+	//nolint:gocritic // Changing the policy template affects all dependent
+	// templates, even if the actor can't read them.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+
+	var (
+		dependents []database.Template
+		seen       = map[uuid.UUID]struct{}{templateID: {}}
+		queue      = []uuid.UUID{templateID}
+	)
+	for len(queue) > 0 {
+		templates, err := db.GetTemplatesBySchedulePolicyTemplateID(ctx, uuid.NullUUID{UUID: queue[0], Valid: true})
+		if err != nil {
+			return nil, xerrors.Errorf("get templates by schedule policy template: %w", err)
+		}
+		queue = queue[1:]
+		for _, template := range templates {
+			if _, ok := seen[template.ID]; ok {
+				continue
+			}
+			seen[template.ID] = struct{}{}
+			dependents = append(dependents, template)
+			queue = append(queue, template.ID)
+		}
+	}
+	return dependents, nil
+}
+
+// GetForWorkspace implements agpl.TemplateScheduleStore.
+func (s *EnterpriseTemplateScheduleStore) GetForWorkspace(ctx context.Context, db database.Store, workspace database.Workspace) (agpl.TemplateScheduleOptions, error) {
+	ctx, span := tracing.StartSpan(ctx,
+		trace.WithAttributes(attribute.String("coder.workspace_id", workspace.ID.String())),
+		trace.WithAttributes(attribute.String("coder.template_id", workspace.TemplateID.String())),
+	)
+	defer span.End()
+
+	opts, err := s.Get(ctx, db, workspace.TemplateID)
+	if err != nil {
+		return agpl.TemplateScheduleOptions{}, err
+	}
+	return s.applyWorkspaceScheduleOverride(ctx, db, workspace, opts)
+}
+
+// applyWorkspaceScheduleOverride applies the schedule override of the
+// workspace, if any, on top of the template's scheduling options.
+func (s *EnterpriseTemplateScheduleStore) applyWorkspaceScheduleOverride(ctx context.Context, db database.Store, workspace database.Workspace, opts agpl.TemplateScheduleOptions) (agpl.TemplateScheduleOptions, error) {
+	if !s.UseWorkspaceScheduleOverrides.Load() {
+		return opts, nil
+	}
+
+	override, err := db.GetWorkspaceScheduleOverrideByWorkspaceID(ctx, workspace.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return opts, nil
+	}
+	if err != nil {
+		return agpl.TemplateScheduleOptions{}, xerrors.Errorf("get workspace schedule override: %w", err)
+	}
+
+	// Same as above, verify before converting to the agpl types.
+	if override.RestartRequirementDaysOfWeek < 0 {
+		return agpl.TemplateScheduleOptions{}, xerrors.New("invalid override restart requirement days, negative")
+	}
+	if override.RestartRequirementDaysOfWeek > 0b11111111 {
+		return agpl.TemplateScheduleOptions{}, xerrors.New("invalid override restart requirement days, too large")
+	}
+	err = agpl.VerifyTemplateRestartRequirement(uint8(override.RestartRequirementDaysOfWeek), override.RestartRequirementWeeks)
+	if err != nil {
+		return agpl.TemplateScheduleOptions{}, xerrors.Errorf("invalid override: %w", err)
+	}
+
+	opts.DefaultTTL = time.Duration(override.DefaultTTL)
+	opts.MaxTTL = time.Duration(override.MaxTTL)
+	// The restart requirement timezone is not overridable, so keep the
+	// template's value.
+	opts.RestartRequirement.DaysOfWeek = uint8(override.RestartRequirementDaysOfWeek)
+	opts.RestartRequirement.Weeks = override.RestartRequirementWeeks
+	return opts, nil
+}
+
 // Set implements agpl.TemplateScheduleStore.
-func (s *EnterpriseTemplateScheduleStore) Set(ctx context.Context, db database.Store, tpl database.Template, opts agpl.TemplateScheduleOptions) (database.Template, error) {
+func (s *EnterpriseTemplateScheduleStore) Set(ctx context.Context, db database.Store, tpl database.Template, opts agpl.TemplateScheduleOptions) (database.Template, agpl.TemplateScheduleChanges, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
 
@@ -98,21 +240,44 @@ func (s *EnterpriseTemplateScheduleStore) Set(ctx context.Context, db database.S
 		int64(opts.MaxTTL) == tpl.MaxTTL &&
 		int16(opts.RestartRequirement.DaysOfWeek) == tpl.RestartRequirementDaysOfWeek &&
 		opts.RestartRequirement.Weeks == tpl.RestartRequirementWeeks &&
+		opts.RestartRequirement.Timezone == tpl.RestartRequirementTimezone &&
+		int64(opts.RestartRequirementSpread) == tpl.RestartRequirementSpread &&
+		opts.UpdateOnRestart == tpl.UpdateOnRestart &&
 		int64(opts.FailureTTL) == tpl.FailureTTL &&
+		opts.FailureRetries == tpl.FailureRetries &&
+		int64(opts.FailureRetryBackoff) == tpl.FailureRetryBackoff &&
 		int64(opts.InactivityTTL) == tpl.InactivityTTL &&
 		int64(opts.LockedTTL) == tpl.LockedTTL &&
+		int64(opts.MaxLifetime) == tpl.MaxLifetime &&
+		int64(opts.ArchiveRetention) == tpl.ArchiveRetention &&
+		int64(opts.ActivityBumpTTL) == tpl.ActivityBumpTTL &&
 		opts.UserAutostartEnabled == tpl.AllowUserAutostart &&
 		opts.UserAutostopEnabled == tpl.AllowUserAutostop {
 		// Avoid updating the UpdatedAt timestamp if nothing will be changed.
-		return tpl, nil
+		return tpl, agpl.TemplateScheduleChanges{}, nil
 	}
 
 	err := agpl.VerifyTemplateRestartRequirement(opts.RestartRequirement.DaysOfWeek, opts.RestartRequirement.Weeks)
 	if err != nil {
-		return database.Template{}, err
+		return database.Template{}, agpl.TemplateScheduleChanges{}, err
+	}
+	_, err = opts.RestartRequirement.Location()
+	if err != nil {
+		return database.Template{}, agpl.TemplateScheduleChanges{}, err
+	}
+	err = agpl.VerifyTemplateFailureRetries(opts.FailureRetries, opts.FailureRetryBackoff)
+	if err != nil {
+		return database.Template{}, agpl.TemplateScheduleChanges{}, err
 	}
 
-	var template database.Template
+	var (
+		template database.Template
+		// affected are the templates whose workspace build deadlines
+		// change with the update.
+		affected []database.Template
+		changes  []agpl.WorkspaceBuildDeadlineChange
+		jobIDs   []uuid.UUID
+	)
 	err = db.InTx(func(db database.Store) error {
 		ctx, span := tracing.StartSpanWithName(ctx, "(*schedule.EnterpriseTemplateScheduleStore).Set()-InTx()")
 		defer span.End()
@@ -126,21 +291,30 @@ func (s *EnterpriseTemplateScheduleStore) Set(ctx context.Context, db database.S
 			MaxTTL:                       int64(opts.MaxTTL),
 			RestartRequirementDaysOfWeek: int16(opts.RestartRequirement.DaysOfWeek),
 			RestartRequirementWeeks:      opts.RestartRequirement.Weeks,
+			RestartRequirementTimezone:   opts.RestartRequirement.Timezone,
+			RestartRequirementSpread:     int64(opts.RestartRequirementSpread),
+			UpdateOnRestart:              opts.UpdateOnRestart,
 			FailureTTL:                   int64(opts.FailureTTL),
+			FailureRetries:               opts.FailureRetries,
+			FailureRetryBackoff:          int64(opts.FailureRetryBackoff),
 			InactivityTTL:                int64(opts.InactivityTTL),
 			LockedTTL:                    int64(opts.LockedTTL),
+			MaxLifetime:                  int64(opts.MaxLifetime),
+			ArchiveRetention:             int64(opts.ArchiveRetention),
+			ActivityBumpTTL:              int64(opts.ActivityBumpTTL),
 		})
 		if err != nil {
 			return xerrors.Errorf("update template schedule: %w", err)
 		}
 
-		// If we updated the locked_ttl we need to update all the workspaces deleting_at
-		// to ensure workspaces are being cleaned up correctly. Similarly if we are
-		// disabling it (by passing 0), then we want to delete nullify the deleting_at
-		// fields of all the template workspaces.
+		// If we updated the locked_ttl or archive_retention we need to update all the
+		// workspaces deleting_at to ensure workspaces are being cleaned up correctly.
+		// Similarly if we are disabling it (by passing 0), then we want to delete
+		// nullify the deleting_at fields of all the template workspaces.
 		err = db.UpdateWorkspacesDeletingAtByTemplateID(ctx, database.UpdateWorkspacesDeletingAtByTemplateIDParams{
-			TemplateID:  tpl.ID,
-			LockedTtlMs: opts.LockedTTL.Milliseconds(),
+			TemplateID:         tpl.ID,
+			LockedTtlMs:        opts.LockedTTL.Milliseconds(),
+			ArchiveRetentionMs: opts.ArchiveRetention.Milliseconds(),
 		})
 		if err != nil {
 			return xerrors.Errorf("update deleting_at of all workspaces for new locked_ttl %q: %w", opts.LockedTTL, err)
@@ -151,90 +325,311 @@ func (s *EnterpriseTemplateScheduleStore) Set(ctx context.Context, db database.S
 			return xerrors.Errorf("get updated template schedule: %w", err)
 		}
 
+		// Templates that inherit the schedule of this template are affected
+		// by the update too.
+		dependents, err := schedulePolicyDependents(ctx, db, template.ID)
+		if err != nil {
+			return err
+		}
+		affected = append([]database.Template{template}, dependents...)
+
 		// Recalculate max_deadline and deadline for all running workspace
 		// builds on this template.
-		if s.UseRestartRequirement.Load() {
-			err = s.updateWorkspaceBuilds(ctx, db, template)
-			if err != nil {
-				return xerrors.Errorf("update workspace builds: %w", err)
-			}
+		changes, jobIDs, err = s.recalculateAffectedWorkspaceBuilds(ctx, db, affected)
+		return err
+	}, nil)
+	if err != nil {
+		return database.Template{}, agpl.TemplateScheduleChanges{}, err
+	}
+
+	return template, scheduleChanges(changes, jobIDs), nil
+}
+
+// SetSchedulePolicy sets the template that tpl inherits its schedule settings
+// from. If policyID is not valid, tpl uses its own schedule settings again.
+// The workspace build deadlines of tpl and its dependent templates are
+// recalculated, and the changes to their workspace builds are returned.
+func (s *EnterpriseTemplateScheduleStore) SetSchedulePolicy(ctx context.Context, db database.Store, tpl database.Template, policyID uuid.NullUUID) (database.Template, agpl.TemplateScheduleChanges, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+
+	if policyID == tpl.SchedulePolicyTemplateID {
+		return tpl, agpl.TemplateScheduleChanges{}, nil
+	}
+
+	var (
+		template database.Template
+		// affected are the templates whose workspace build deadlines
+		// change with the update.
+		affected []database.Template
+		changes  []agpl.WorkspaceBuildDeadlineChange
+		jobIDs   []uuid.UUID
+	)
+	err := db.InTx(func(db database.Store) error {
+		err := db.UpdateTemplateSchedulePolicyByID(ctx, database.UpdateTemplateSchedulePolicyByIDParams{
+			ID:                       tpl.ID,
+			UpdatedAt:                s.now(),
+			SchedulePolicyTemplateID: policyID,
+		})
+		if err != nil {
+			return xerrors.Errorf("update template schedule policy: %w", err)
 		}
 
-		return nil
+		template, err = db.GetTemplateByID(ctx, tpl.ID)
+		if err != nil {
+			return xerrors.Errorf("get updated template: %w", err)
+		}
+		// Fail if the policy template inherits from the template itself.
+		_, err = agpl.ResolveSchedulePolicy(ctx, db, template)
+		if err != nil {
+			return err
+		}
+
+		dependents, err := schedulePolicyDependents(ctx, db, template.ID)
+		if err != nil {
+			return err
+		}
+		affected = append([]database.Template{template}, dependents...)
+
+		changes, jobIDs, err = s.recalculateAffectedWorkspaceBuilds(ctx, db, affected)
+		return err
 	}, nil)
 	if err != nil {
-		return database.Template{}, err
+		return database.Template{}, agpl.TemplateScheduleChanges{}, err
+	}
+
+	return template, scheduleChanges(changes, jobIDs), nil
+}
+
+// scheduleChanges returns the changes of a schedule update for its audit log.
+// changes are the workspace build deadlines that were recalculated, and jobIDs
+// the jobs that recalculate them in the background.
+func scheduleChanges(changes []agpl.WorkspaceBuildDeadlineChange, jobIDs []uuid.UUID) agpl.TemplateScheduleChanges {
+	c := agpl.TemplateScheduleChanges{DeadlineRecalculationJobIDs: jobIDs}
+	if len(changes) > 0 {
+		c.RecalculatedWorkspaces = agpl.ConvertRecalculatedWorkspaces(changes)
+	}
+	return c
+}
+
+// recalculateAffectedWorkspaceBuilds recalculates the deadlines of the active
+// workspace builds of the affected templates if the restart requirement is
+// used. If the recalculation is done in the background, the jobs are queued in
+// db, so they only run once the update of the schedule is committed.
+func (s *EnterpriseTemplateScheduleStore) recalculateAffectedWorkspaceBuilds(ctx context.Context, db database.Store, affected []database.Template) ([]agpl.WorkspaceBuildDeadlineChange, []uuid.UUID, error) {
+	if !s.UseRestartRequirement.Load() {
+		return nil, nil, nil
+	}
+	if s.DeadlineRecalculator == nil {
+		changes, err := s.updateAffectedWorkspaceBuilds(ctx, db, affected)
+		return changes, nil, err
 	}
 
-	return template, nil
+	jobIDs := make([]uuid.UUID, 0, len(affected))
+	for _, t := range affected {
+		job, _, err := s.DeadlineRecalculator.Enqueue(ctx, db, t.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		jobIDs = append(jobIDs, job.ID)
+	}
+	return nil, jobIDs, nil
+}
+
+// updateAffectedWorkspaceBuilds recalculates the deadlines of the active
+// workspace builds of the affected templates, the first of which is the
+// updated template, and returns the changed deadlines.
+func (s *EnterpriseTemplateScheduleStore) updateAffectedWorkspaceBuilds(ctx context.Context, db database.Store, affected []database.Template) ([]agpl.WorkspaceBuildDeadlineChange, error) {
+	var changes []agpl.WorkspaceBuildDeadlineChange
+	for i, t := range affected {
+		templateCtx := ctx
+		if i > 0 {
+			//nolint:gocritic // Templates that inherit the schedule of the
+			// updated template are affected even if the actor can't update
+			// them.
+			templateCtx = dbauthz.AsSystemRestricted(ctx)
+		}
+		templateChanges, err := s.updateWorkspaceBuilds(templateCtx, db, t)
+		if err != nil {
+			return nil, xerrors.Errorf("update workspace builds of template %q: %w", t.ID, err)
+		}
+		changes = append(changes, templateChanges...)
+	}
+	return changes, nil
 }
 
-func (s *EnterpriseTemplateScheduleStore) updateWorkspaceBuilds(ctx context.Context, db database.Store, template database.Template) error {
+// updateWorkspaceBuilds recalculates the deadlines of the active workspace
+// builds of the template and returns the changed deadlines. The actor must be
+// allowed to update the template.
+func (s *EnterpriseTemplateScheduleStore) updateWorkspaceBuilds(ctx context.Context, db database.Store, template database.Template) ([]agpl.WorkspaceBuildDeadlineChange, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
 
-	//nolint:gocritic // This function will retrieve all workspace builds on
-	// the template and update their max deadline to be within the new
-	// policy parameters.
+	builds, err := db.GetActiveWorkspaceBuildsByTemplateID(ctx, template.ID)
+	if err != nil {
+		return nil, xerrors.Errorf("get active workspace builds: %w", err)
+	}
+
+	//nolint:gocritic // Updating the template allows recalculating the
+	// deadlines of the workspace builds of all users on the template.
 	ctx = dbauthz.AsSystemRestricted(ctx)
 
-	builds, err := db.GetActiveWorkspaceBuildsByTemplateID(ctx, template.ID)
+	var changes []agpl.WorkspaceBuildDeadlineChange
+	for _, build := range builds {
+		change, changed, err := s.updateWorkspaceBuild(ctx, db, build)
+		if err != nil {
+			return nil, xerrors.Errorf("update workspace build %q: %w", build.ID, err)
+		}
+		if changed {
+			changes = append(changes, change)
+		}
+	}
+
+	return changes, nil
+}
+
+// DryRun implements agpl.TemplateScheduleStore.
+func (s *EnterpriseTemplateScheduleStore) DryRun(ctx context.Context, db database.Store, tpl database.Template, opts agpl.TemplateScheduleOptions) ([]agpl.WorkspaceBuildDeadlineChange, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+
+	err := agpl.VerifyTemplateRestartRequirement(opts.RestartRequirement.DaysOfWeek, opts.RestartRequirement.Weeks)
+	if err != nil {
+		return nil, err
+	}
+	_, err = opts.RestartRequirement.Location()
 	if err != nil {
-		return xerrors.Errorf("get active workspace builds: %w", err)
+		return nil, err
 	}
 
+	changes := []agpl.WorkspaceBuildDeadlineChange{}
+	// Set only recalculates existing builds when the restart requirement is
+	// in use.
+	if !s.UseRestartRequirement.Load() {
+		return changes, nil
+	}
+
+	builds, err := db.GetActiveWorkspaceBuildsByTemplateID(ctx, tpl.ID)
+	if err != nil {
+		return nil, xerrors.Errorf("get active workspace builds: %w", err)
+	}
+
+	//nolint:gocritic // Updating the template allows previewing the deadlines
+	// of the workspace builds of all users on the template.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+
+	opts.UseRestartRequirement = true
+	proposed := &dryRunTemplateScheduleStore{
+		EnterpriseTemplateScheduleStore: s,
+		templateID:                      tpl.ID,
+		opts:                            opts,
+	}
 	for _, build := range builds {
-		err := s.updateWorkspaceBuild(ctx, db, build)
+		change, ok, err := s.calculateWorkspaceBuildDeadlines(ctx, db, proposed, build)
 		if err != nil {
-			return xerrors.Errorf("update workspace build %q: %w", build.ID, err)
+			return nil, xerrors.Errorf("calculate workspace build %q deadlines: %w", build.ID, err)
 		}
+		if !ok || (change.NewDeadline.Equal(build.Deadline) && change.NewMaxDeadline.Equal(build.MaxDeadline)) {
+			continue
+		}
+		changes = append(changes, change)
 	}
 
-	return nil
+	return changes, nil
+}
+
+// dryRunTemplateScheduleStore returns the proposed scheduling options for a
+// single template, so deadlines can be calculated without storing them.
+type dryRunTemplateScheduleStore struct {
+	*EnterpriseTemplateScheduleStore
+	templateID uuid.UUID
+	opts       agpl.TemplateScheduleOptions
+}
+
+func (s *dryRunTemplateScheduleStore) Get(ctx context.Context, db database.Store, templateID uuid.UUID) (agpl.TemplateScheduleOptions, error) {
+	if templateID != s.templateID {
+		return s.EnterpriseTemplateScheduleStore.Get(ctx, db, templateID)
+	}
+	return s.opts, nil
+}
+
+func (s *dryRunTemplateScheduleStore) GetForWorkspace(ctx context.Context, db database.Store, workspace database.Workspace) (agpl.TemplateScheduleOptions, error) {
+	opts, err := s.Get(ctx, db, workspace.TemplateID)
+	if err != nil {
+		return agpl.TemplateScheduleOptions{}, err
+	}
+	return s.applyWorkspaceScheduleOverride(ctx, db, workspace, opts)
 }
 
-func (s *EnterpriseTemplateScheduleStore) updateWorkspaceBuild(ctx context.Context, db database.Store, build database.WorkspaceBuild) error {
+// updateWorkspaceBuild recalculates the deadlines of the workspace build. If
+// the deadlines changed, the change and true are returned.
+func (s *EnterpriseTemplateScheduleStore) updateWorkspaceBuild(ctx context.Context, db database.Store, build database.WorkspaceBuild) (agpl.WorkspaceBuildDeadlineChange, bool, error) {
 	ctx, span := tracing.StartSpan(ctx,
 		trace.WithAttributes(attribute.String("coder.workspace_id", build.WorkspaceID.String())),
 		trace.WithAttributes(attribute.String("coder.workspace_build_id", build.ID.String())),
 	)
 	defer span.End()
 
+	change, ok, err := s.calculateWorkspaceBuildDeadlines(ctx, db, s, build)
+	if err != nil || !ok {
+		return agpl.WorkspaceBuildDeadlineChange{}, false, err
+	}
+
+	// Update the workspace build.
+	err = db.UpdateWorkspaceBuildByID(ctx, database.UpdateWorkspaceBuildByIDParams{
+		ID:          build.ID,
+		UpdatedAt:   s.now(),
+		Deadline:    change.NewDeadline,
+		MaxDeadline: change.NewMaxDeadline,
+	})
+	if err != nil {
+		return agpl.WorkspaceBuildDeadlineChange{}, false, xerrors.Errorf("update workspace build %q: %w", build.ID, err)
+	}
+
+	changed := !change.NewDeadline.Equal(build.Deadline) || !change.NewMaxDeadline.Equal(build.MaxDeadline)
+	return change, changed, nil
+}
+
+// calculateWorkspaceBuildDeadlines calculates the new deadline and max_deadline
+// of the build using the scheduling options from templateScheduleStore. If the
+// build should be left untouched, false is returned.
+func (s *EnterpriseTemplateScheduleStore) calculateWorkspaceBuildDeadlines(ctx context.Context, db database.Store, templateScheduleStore agpl.TemplateScheduleStore, build database.WorkspaceBuild) (agpl.WorkspaceBuildDeadlineChange, bool, error) {
 	if !build.MaxDeadline.IsZero() && build.MaxDeadline.Before(s.now().Add(2*time.Hour)) {
 		// Skip this since it's already too close to the max_deadline.
-		return nil
+		return agpl.WorkspaceBuildDeadlineChange{}, false, nil
 	}
 
 	workspace, err := db.GetWorkspaceByID(ctx, build.WorkspaceID)
 	if err != nil {
-		return xerrors.Errorf("get workspace %q: %w", build.WorkspaceID, err)
+		return agpl.WorkspaceBuildDeadlineChange{}, false, xerrors.Errorf("get workspace %q: %w", build.WorkspaceID, err)
 	}
 
 	job, err := db.GetProvisionerJobByID(ctx, build.JobID)
 	if err != nil {
-		return xerrors.Errorf("get provisioner job %q: %w", build.JobID, err)
+		return agpl.WorkspaceBuildDeadlineChange{}, false, xerrors.Errorf("get provisioner job %q: %w", build.JobID, err)
 	}
 	if db2sdk.ProvisionerJobStatus(job) != codersdk.ProvisionerJobSucceeded {
 		// Only touch builds that are completed.
-		return nil
+		return agpl.WorkspaceBuildDeadlineChange{}, false, nil
 	}
 
 	// If the job completed before the autostop epoch, then it must be skipped
 	// to avoid failures below. Add a week to account for timezones.
 	if job.CompletedAt.Time.Before(agpl.TemplateRestartRequirementEpoch(time.UTC).Add(time.Hour * 7 * 24)) {
-		return nil
+		return agpl.WorkspaceBuildDeadlineChange{}, false, nil
 	}
 
 	autostop, err := agpl.CalculateAutostop(ctx, agpl.CalculateAutostopParams{
 		Database:                    db,
-		TemplateScheduleStore:       s,
+		TemplateScheduleStore:       templateScheduleStore,
 		UserQuietHoursScheduleStore: *s.UserQuietHoursScheduleStore.Load(),
+		Holidays:                    s.Holidays,
 		// Use the job completion time as the time we calculate autostop from.
 		Now:       job.CompletedAt.Time,
 		Workspace: workspace,
 	})
 	if err != nil {
-		return xerrors.Errorf("calculate new autostop for workspace %q: %w", workspace.ID, err)
+		return agpl.WorkspaceBuildDeadlineChange{}, false, xerrors.Errorf("calculate new autostop for workspace %q: %w", workspace.ID, err)
 	}
 
 	// If max deadline is before now()+2h, then set it to that.
@@ -250,16 +645,14 @@ func (s *EnterpriseTemplateScheduleStore) updateWorkspaceBuild(ctx context.Conte
 		autostop.Deadline = autostop.MaxDeadline
 	}
 
-	// Update the workspace build.
-	err = db.UpdateWorkspaceBuildByID(ctx, database.UpdateWorkspaceBuildByIDParams{
-		ID:          build.ID,
-		UpdatedAt:   now,
-		Deadline:    autostop.Deadline,
-		MaxDeadline: autostop.MaxDeadline,
-	})
-	if err != nil {
-		return xerrors.Errorf("update workspace build %q: %w", build.ID, err)
-	}
-
-	return nil
+	return agpl.WorkspaceBuildDeadlineChange{
+		WorkspaceID:      workspace.ID,
+		WorkspaceName:    workspace.Name,
+		OwnerID:          workspace.OwnerID,
+		WorkspaceBuildID: build.ID,
+		Deadline:         build.Deadline,
+		MaxDeadline:      build.MaxDeadline,
+		NewDeadline:      autostop.Deadline,
+		NewMaxDeadline:   autostop.MaxDeadline,
+	}, true, nil
 }
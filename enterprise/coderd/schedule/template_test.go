@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbgen"
 	"github.com/coder/coder/coderd/database/dbtestutil"
@@ -176,7 +177,7 @@ func TestTemplateUpdateBuildDeadlines(t *testing.T) {
 					UUID:  uuid.New(),
 					Valid: true,
 				},
-				Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+				Types: []string{string(database.ProvisionerTypeEcho)},
 				Tags:  json.RawMessage(fmt.Sprintf(`{%q: "yeah"}`, c.name)),
 			})
 			require.NoError(t, err)
@@ -203,7 +204,7 @@ func TestTemplateUpdateBuildDeadlines(t *testing.T) {
 			wsBuild, err = db.GetWorkspaceBuildByID(ctx, wsBuild.ID)
 			require.NoError(t, err)
 
-			userQuietHoursStore, err := schedule.NewEnterpriseUserQuietHoursScheduleStore(userQuietHoursSchedule)
+			userQuietHoursStore, err := schedule.NewEnterpriseUserQuietHoursScheduleStore(userQuietHoursSchedule, nil, slogtest.Make(t, nil))
 			require.NoError(t, err)
 			userQuietHoursStorePtr := &atomic.Pointer[agplschedule.UserQuietHoursScheduleStore]{}
 			userQuietHoursStorePtr.Store(&userQuietHoursStore)
@@ -214,7 +215,7 @@ func TestTemplateUpdateBuildDeadlines(t *testing.T) {
 			templateScheduleStore.TimeNowFn = func() time.Time {
 				return c.now
 			}
-			_, err = templateScheduleStore.Set(ctx, db, template, agplschedule.TemplateScheduleOptions{
+			_, changes, err := templateScheduleStore.Set(ctx, db, template, agplschedule.TemplateScheduleOptions{
 				UserAutostartEnabled:  false,
 				UserAutostopEnabled:   false,
 				DefaultTTL:            0,
@@ -235,11 +236,22 @@ func TestTemplateUpdateBuildDeadlines(t *testing.T) {
 			newBuild, err := db.GetWorkspaceBuildByID(ctx, wsBuild.ID)
 			require.NoError(t, err)
 
+			changed := !newBuild.Deadline.Equal(wsBuild.Deadline) || !newBuild.MaxDeadline.Equal(wsBuild.MaxDeadline)
 			if c.newDeadline.IsZero() {
 				c.newDeadline = wsBuild.Deadline
 			}
 			require.WithinDuration(t, c.newDeadline, newBuild.Deadline, time.Second)
 			require.WithinDuration(t, c.newMaxDeadline, newBuild.MaxDeadline, time.Second)
+
+			// The workspaces whose deadlines were recalculated are returned
+			// for the audit log of the change.
+			if !changed {
+				require.Empty(t, changes.RecalculatedWorkspaces)
+				return
+			}
+			require.Len(t, changes.RecalculatedWorkspaces, 1)
+			require.Equal(t, ws.ID, changes.RecalculatedWorkspaces[0].WorkspaceID)
+			require.WithinDuration(t, newBuild.MaxDeadline, changes.RecalculatedWorkspaces[0].NewMaxDeadline, time.Second)
 		})
 	}
 }
@@ -449,7 +461,7 @@ func TestTemplateUpdateBuildDeadlinesSkip(t *testing.T) {
 				UUID:  uuid.New(),
 				Valid: true,
 			},
-			Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Types: []string{string(database.ProvisionerTypeEcho)},
 			Tags:  json.RawMessage(fmt.Sprintf(`{%q: "yeah"}`, wsID)),
 		})
 		require.NoError(t, err)
@@ -478,7 +490,7 @@ func TestTemplateUpdateBuildDeadlinesSkip(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	userQuietHoursStore, err := schedule.NewEnterpriseUserQuietHoursScheduleStore(userQuietHoursSchedule)
+	userQuietHoursStore, err := schedule.NewEnterpriseUserQuietHoursScheduleStore(userQuietHoursSchedule, nil, slogtest.Make(t, nil))
 	require.NoError(t, err)
 	userQuietHoursStorePtr := &atomic.Pointer[agplschedule.UserQuietHoursScheduleStore]{}
 	userQuietHoursStorePtr.Store(&userQuietHoursStore)
@@ -489,7 +501,7 @@ func TestTemplateUpdateBuildDeadlinesSkip(t *testing.T) {
 	templateScheduleStore.TimeNowFn = func() time.Time {
 		return now
 	}
-	_, err = templateScheduleStore.Set(ctx, db, template, agplschedule.TemplateScheduleOptions{
+	_, _, err = templateScheduleStore.Set(ctx, db, template, agplschedule.TemplateScheduleOptions{
 		UserAutostartEnabled:  false,
 		UserAutostopEnabled:   false,
 		DefaultTTL:            0,
@@ -521,3 +533,402 @@ func TestTemplateUpdateBuildDeadlinesSkip(t *testing.T) {
 		}
 	}
 }
+
+func TestTemplateScheduleGetForWorkspace(t *testing.T) {
+	t.Parallel()
+
+	db, _ := dbtestutil.NewDB(t)
+
+	var (
+		org  = dbgen.Organization(t, db, database.Organization{})
+		user = dbgen.User(t, db, database.User{})
+		file = dbgen.File(t, db, database.File{
+			CreatedBy: user.ID,
+		})
+		templateJob = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			FileID:         file.ID,
+			InitiatorID:    user.ID,
+		})
+		templateVersion = dbgen.TemplateVersion(t, db, database.TemplateVersion{
+			OrganizationID: org.ID,
+			CreatedBy:      user.ID,
+			JobID:          templateJob.ID,
+		})
+		template = dbgen.Template(t, db, database.Template{
+			OrganizationID:  org.ID,
+			ActiveVersionID: templateVersion.ID,
+			CreatedBy:       user.ID,
+		})
+		ws = dbgen.Workspace(t, db, database.Workspace{
+			OrganizationID: org.ID,
+			OwnerID:        user.ID,
+			TemplateID:     template.ID,
+		})
+	)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	userQuietHoursStorePtr := &atomic.Pointer[agplschedule.UserQuietHoursScheduleStore]{}
+	templateScheduleStore := schedule.NewEnterpriseTemplateScheduleStore(userQuietHoursStorePtr)
+
+	template, _, err := templateScheduleStore.Set(ctx, db, template, agplschedule.TemplateScheduleOptions{
+		UserAutostartEnabled: true,
+		UserAutostopEnabled:  true,
+		DefaultTTL:           time.Hour,
+		MaxTTL:               2 * time.Hour,
+		RestartRequirement: agplschedule.TemplateRestartRequirement{
+			// Every day
+			DaysOfWeek: 0b01111111,
+			Weeks:      0,
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = db.UpsertWorkspaceScheduleOverride(ctx, database.UpsertWorkspaceScheduleOverrideParams{
+		WorkspaceID: ws.ID,
+		CreatedAt:   database.Now(),
+		UpdatedAt:   database.Now(),
+		DefaultTTL:  int64(8 * time.Hour),
+		MaxTTL:      int64(24 * time.Hour),
+		// Saturday and Sunday
+		RestartRequirementDaysOfWeek: 0b01100000,
+		RestartRequirementWeeks:      2,
+	})
+	require.NoError(t, err)
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		opts, err := templateScheduleStore.GetForWorkspace(ctx, db, ws)
+		require.NoError(t, err)
+		require.Equal(t, time.Hour, opts.DefaultTTL)
+		require.Equal(t, 2*time.Hour, opts.MaxTTL)
+		require.Equal(t, uint8(0b01111111), opts.RestartRequirement.DaysOfWeek)
+		require.EqualValues(t, 0, opts.RestartRequirement.Weeks)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		t.Parallel()
+
+		store := schedule.NewEnterpriseTemplateScheduleStore(userQuietHoursStorePtr)
+		store.UseWorkspaceScheduleOverrides.Store(true)
+
+		opts, err := store.GetForWorkspace(ctx, db, ws)
+		require.NoError(t, err)
+		require.Equal(t, 8*time.Hour, opts.DefaultTTL)
+		require.Equal(t, 24*time.Hour, opts.MaxTTL)
+		require.Equal(t, uint8(0b01100000), opts.RestartRequirement.DaysOfWeek)
+		require.EqualValues(t, 2, opts.RestartRequirement.Weeks)
+		// Other options are inherited from the template.
+		require.True(t, opts.UserAutostartEnabled)
+		require.True(t, opts.UserAutostopEnabled)
+	})
+
+	t.Run("NoOverride", func(t *testing.T) {
+		t.Parallel()
+
+		store := schedule.NewEnterpriseTemplateScheduleStore(userQuietHoursStorePtr)
+		store.UseWorkspaceScheduleOverrides.Store(true)
+
+		otherWs := dbgen.Workspace(t, db, database.Workspace{
+			OrganizationID: org.ID,
+			OwnerID:        user.ID,
+			TemplateID:     template.ID,
+		})
+		opts, err := store.GetForWorkspace(ctx, db, otherWs)
+		require.NoError(t, err)
+		require.Equal(t, time.Hour, opts.DefaultTTL)
+		require.Equal(t, 2*time.Hour, opts.MaxTTL)
+	})
+}
+
+func TestTemplateScheduleGetOrganizationDefaults(t *testing.T) {
+	t.Parallel()
+
+	db, _ := dbtestutil.NewDB(t)
+
+	var (
+		org  = dbgen.Organization(t, db, database.Organization{})
+		user = dbgen.User(t, db, database.User{})
+		file = dbgen.File(t, db, database.File{
+			CreatedBy: user.ID,
+		})
+		templateJob = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			FileID:         file.ID,
+			InitiatorID:    user.ID,
+		})
+		templateVersion = dbgen.TemplateVersion(t, db, database.TemplateVersion{
+			OrganizationID: org.ID,
+			CreatedBy:      user.ID,
+			JobID:          templateJob.ID,
+		})
+		unsetTemplate = dbgen.Template(t, db, database.Template{
+			OrganizationID:  org.ID,
+			ActiveVersionID: templateVersion.ID,
+			CreatedBy:       user.ID,
+		})
+		setTemplate = dbgen.Template(t, db, database.Template{
+			OrganizationID:  org.ID,
+			ActiveVersionID: templateVersion.ID,
+			CreatedBy:       user.ID,
+		})
+		noAutostopTemplate = dbgen.Template(t, db, database.Template{
+			OrganizationID:  org.ID,
+			ActiveVersionID: templateVersion.ID,
+			CreatedBy:       user.ID,
+		})
+	)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	userQuietHoursStorePtr := &atomic.Pointer[agplschedule.UserQuietHoursScheduleStore]{}
+	templateScheduleStore := schedule.NewEnterpriseTemplateScheduleStore(userQuietHoursStorePtr)
+
+	err := db.UpdateTemplateMetaByID(ctx, database.UpdateTemplateMetaByIDParams{
+		ID:              noAutostopTemplate.ID,
+		UpdatedAt:       database.Now(),
+		Name:            noAutostopTemplate.Name,
+		DisableAutostop: true,
+	})
+	require.NoError(t, err)
+
+	// Without organization settings the template values are used as is.
+	opts, err := templateScheduleStore.Get(ctx, db, unsetTemplate.ID)
+	require.NoError(t, err)
+	require.Zero(t, opts.DefaultTTL)
+	require.Zero(t, opts.RestartRequirement.DaysOfWeek)
+
+	_, err = db.UpsertOrganizationScheduleSettings(ctx, database.UpsertOrganizationScheduleSettingsParams{
+		OrganizationID: org.ID,
+		CreatedAt:      database.Now(),
+		UpdatedAt:      database.Now(),
+		DefaultTTL:     int64(8 * time.Hour),
+		// Saturday and Sunday
+		RestartRequirementDaysOfWeek: 0b01100000,
+		RestartRequirementWeeks:      2,
+	})
+	require.NoError(t, err)
+
+	_, _, err = templateScheduleStore.Set(ctx, db, setTemplate, agplschedule.TemplateScheduleOptions{
+		DefaultTTL: time.Hour,
+		RestartRequirement: agplschedule.TemplateRestartRequirement{
+			// Every day
+			DaysOfWeek: 0b01111111,
+			Weeks:      1,
+		},
+	})
+	require.NoError(t, err)
+
+	// Templates without values use the organization defaults.
+	opts, err = templateScheduleStore.Get(ctx, db, unsetTemplate.ID)
+	require.NoError(t, err)
+	require.Equal(t, 8*time.Hour, opts.DefaultTTL)
+	require.Equal(t, uint8(0b01100000), opts.RestartRequirement.DaysOfWeek)
+	require.EqualValues(t, 2, opts.RestartRequirement.Weeks)
+
+	// Templates with values keep them.
+	opts, err = templateScheduleStore.Get(ctx, db, setTemplate.ID)
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, opts.DefaultTTL)
+	require.Equal(t, uint8(0b01111111), opts.RestartRequirement.DaysOfWeek)
+	require.EqualValues(t, 1, opts.RestartRequirement.Weeks)
+
+	// Templates that disable autostop don't use the organization default TTL.
+	opts, err = templateScheduleStore.Get(ctx, db, noAutostopTemplate.ID)
+	require.NoError(t, err)
+	require.Zero(t, opts.DefaultTTL)
+	require.Equal(t, uint8(0b01100000), opts.RestartRequirement.DaysOfWeek)
+}
+
+func TestTemplateScheduleGetSchedulePolicy(t *testing.T) {
+	t.Parallel()
+
+	db, _ := dbtestutil.NewDB(t)
+
+	var (
+		org  = dbgen.Organization(t, db, database.Organization{})
+		user = dbgen.User(t, db, database.User{})
+		file = dbgen.File(t, db, database.File{
+			CreatedBy: user.ID,
+		})
+		templateJob = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			FileID:         file.ID,
+			InitiatorID:    user.ID,
+		})
+		templateVersion = dbgen.TemplateVersion(t, db, database.TemplateVersion{
+			OrganizationID: org.ID,
+			CreatedBy:      user.ID,
+			JobID:          templateJob.ID,
+		})
+		newTemplate = func() database.Template {
+			return dbgen.Template(t, db, database.Template{
+				OrganizationID:  org.ID,
+				ActiveVersionID: templateVersion.ID,
+				CreatedBy:       user.ID,
+			})
+		}
+		policyTemplate = newTemplate()
+		childTemplate  = newTemplate()
+		// grandchildTemplate inherits from the policy template through
+		// childTemplate.
+		grandchildTemplate = newTemplate()
+	)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	userQuietHoursStorePtr := &atomic.Pointer[agplschedule.UserQuietHoursScheduleStore]{}
+	templateScheduleStore := schedule.NewEnterpriseTemplateScheduleStore(userQuietHoursStorePtr)
+
+	var err error
+	policyTemplate, _, err = templateScheduleStore.Set(ctx, db, policyTemplate, agplschedule.TemplateScheduleOptions{
+		DefaultTTL:           8 * time.Hour,
+		UserAutostartEnabled: true,
+		FailureTTL:           time.Hour,
+	})
+	require.NoError(t, err)
+	childTemplate, _, err = templateScheduleStore.Set(ctx, db, childTemplate, agplschedule.TemplateScheduleOptions{
+		DefaultTTL: time.Hour,
+	})
+	require.NoError(t, err)
+
+	childTemplate, _, err = templateScheduleStore.SetSchedulePolicy(ctx, db, childTemplate, uuid.NullUUID{UUID: policyTemplate.ID, Valid: true})
+	require.NoError(t, err)
+	grandchildTemplate, _, err = templateScheduleStore.SetSchedulePolicy(ctx, db, grandchildTemplate, uuid.NullUUID{UUID: childTemplate.ID, Valid: true})
+	require.NoError(t, err)
+
+	// Both templates use the schedule of the policy template.
+	for _, template := range []database.Template{childTemplate, grandchildTemplate} {
+		opts, err := templateScheduleStore.Get(ctx, db, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, 8*time.Hour, opts.DefaultTTL)
+		require.True(t, opts.UserAutostartEnabled)
+		require.Equal(t, time.Hour, opts.FailureTTL)
+	}
+
+	// The policy template must not inherit from its dependents.
+	_, _, err = templateScheduleStore.SetSchedulePolicy(ctx, db, policyTemplate, uuid.NullUUID{UUID: grandchildTemplate.ID, Valid: true})
+	require.ErrorIs(t, err, agplschedule.ErrSchedulePolicyCycle)
+
+	// Deleted policy templates are ignored.
+	err = db.UpdateTemplateDeletedByID(ctx, database.UpdateTemplateDeletedByIDParams{
+		ID:        policyTemplate.ID,
+		Deleted:   true,
+		UpdatedAt: database.Now(),
+	})
+	require.NoError(t, err)
+	opts, err := templateScheduleStore.Get(ctx, db, grandchildTemplate.ID)
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, opts.DefaultTTL)
+	require.False(t, opts.UserAutostartEnabled)
+
+	// Removing the policy restores the template's own schedule.
+	_, _, err = templateScheduleStore.SetSchedulePolicy(ctx, db, grandchildTemplate, uuid.NullUUID{})
+	require.NoError(t, err)
+	opts, err = templateScheduleStore.Get(ctx, db, grandchildTemplate.ID)
+	require.NoError(t, err)
+	require.Zero(t, opts.DefaultTTL)
+}
+
+func TestTemplateScheduleDryRun(t *testing.T) {
+	t.Parallel()
+
+	db, _ := dbtestutil.NewDB(t)
+
+	var (
+		org  = dbgen.Organization(t, db, database.Organization{})
+		user = dbgen.User(t, db, database.User{})
+		file = dbgen.File(t, db, database.File{
+			CreatedBy: user.ID,
+		})
+		templateJob = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			OrganizationID: org.ID,
+			FileID:         file.ID,
+			InitiatorID:    user.ID,
+			Tags: database.StringMap{
+				"foo": "bar",
+			},
+		})
+		templateVersion = dbgen.TemplateVersion(t, db, database.TemplateVersion{
+			OrganizationID: org.ID,
+			CreatedBy:      user.ID,
+			JobID:          templateJob.ID,
+		})
+		template = dbgen.Template(t, db, database.Template{
+			OrganizationID:  org.ID,
+			ActiveVersionID: templateVersion.ID,
+			CreatedBy:       user.ID,
+		})
+	)
+
+	const userQuietHoursSchedule = "CRON_TZ=UTC 0 0 * * *" // midnight UTC
+	ctx := testutil.Context(t, testutil.WaitLong)
+	user, err := db.UpdateUserQuietHoursSchedule(ctx, database.UpdateUserQuietHoursScheduleParams{
+		ID:                 user.ID,
+		QuietHoursSchedule: userQuietHoursSchedule,
+	})
+	require.NoError(t, err)
+
+	realNow := time.Now().UTC()
+	nowY, nowM, nowD := realNow.Date()
+	buildTime := time.Date(nowY, nowM, nowD, 12, 0, 0, 0, time.UTC)       // noon today UTC
+	nextQuietHours := time.Date(nowY, nowM, nowD+1, 0, 0, 0, 0, time.UTC) // midnight tomorrow UTC
+
+	// This build will get a new max deadline.
+	changed := createCompletedWorkspaceBuild(t, db, template, templateVersion.ID, user.ID, buildTime, buildTime, nextQuietHours.Add(24*time.Hour))
+	// This build already has the max deadline it would get, so it is not
+	// included in the preview.
+	unchanged := createCompletedWorkspaceBuild(t, db, template, templateVersion.ID, user.ID, buildTime, buildTime, nextQuietHours)
+
+	userQuietHoursStore, err := schedule.NewEnterpriseUserQuietHoursScheduleStore(userQuietHoursSchedule, nil, slogtest.Make(t, nil))
+	require.NoError(t, err)
+	userQuietHoursStorePtr := &atomic.Pointer[agplschedule.UserQuietHoursScheduleStore]{}
+	userQuietHoursStorePtr.Store(&userQuietHoursStore)
+
+	templateScheduleStore := schedule.NewEnterpriseTemplateScheduleStore(userQuietHoursStorePtr)
+	templateScheduleStore.TimeNowFn = func() time.Time {
+		return nextQuietHours.Add(-6 * time.Hour)
+	}
+	opts := agplschedule.TemplateScheduleOptions{
+		RestartRequirement: agplschedule.TemplateRestartRequirement{
+			// Every day
+			DaysOfWeek: 0b01111111,
+			Weeks:      0,
+		},
+	}
+
+	// Existing builds are not touched unless the restart requirement is in
+	// use.
+	changes, err := templateScheduleStore.DryRun(ctx, db, template, opts)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	templateScheduleStore.UseRestartRequirement.Store(true)
+	changes, err = templateScheduleStore.DryRun(ctx, db, template, opts)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, changed.ID, changes[0].WorkspaceBuildID)
+	require.Equal(t, changed.WorkspaceID, changes[0].WorkspaceID)
+	require.Equal(t, user.ID, changes[0].OwnerID)
+	require.WithinDuration(t, changed.MaxDeadline, changes[0].MaxDeadline, time.Second)
+	require.WithinDuration(t, buildTime, changes[0].NewDeadline, time.Second)
+	require.WithinDuration(t, nextQuietHours, changes[0].NewMaxDeadline, time.Second)
+
+	// Nothing was written.
+	got, err := db.GetWorkspaceBuildByID(ctx, changed.ID)
+	require.NoError(t, err)
+	require.Equal(t, changed, got)
+	got, err = db.GetWorkspaceBuildByID(ctx, unchanged.ID)
+	require.NoError(t, err)
+	require.Equal(t, unchanged, got)
+	gotTemplate, err := db.GetTemplateByID(ctx, template.ID)
+	require.NoError(t, err)
+	require.Equal(t, template, gotTemplate)
+
+	// Invalid options are rejected.
+	_, err = templateScheduleStore.DryRun(ctx, db, template, agplschedule.TemplateScheduleOptions{
+		RestartRequirement: agplschedule.TemplateRestartRequirement{
+			Weeks: agplschedule.MaxTemplateRestartRequirementWeeks + 1,
+		},
+	})
+	require.Error(t, err)
+}
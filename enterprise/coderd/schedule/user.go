@@ -2,12 +2,20 @@ package schedule
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"net/http"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
 	agpl "github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/tracing"
 )
@@ -17,21 +25,28 @@ import (
 // enterprise customers.
 type enterpriseUserQuietHoursScheduleStore struct {
 	defaultSchedule string
+	auditor         *atomic.Pointer[audit.Auditor]
+	logger          slog.Logger
 }
 
 var _ agpl.UserQuietHoursScheduleStore = &enterpriseUserQuietHoursScheduleStore{}
 
-func NewEnterpriseUserQuietHoursScheduleStore(defaultSchedule string) (agpl.UserQuietHoursScheduleStore, error) {
+// NewEnterpriseUserQuietHoursScheduleStore returns the enterprise user quiet
+// hours schedule store. Changes to the schedule of a user are audited with
+// auditor, which may be nil.
+func NewEnterpriseUserQuietHoursScheduleStore(defaultSchedule string, auditor *atomic.Pointer[audit.Auditor], logger slog.Logger) (agpl.UserQuietHoursScheduleStore, error) {
 	if defaultSchedule == "" {
 		return nil, xerrors.Errorf("default schedule must be set")
 	}
 
 	s := &enterpriseUserQuietHoursScheduleStore{
 		defaultSchedule: defaultSchedule,
+		auditor:         auditor,
+		logger:          logger,
 	}
 
 	// The context is only used for tracing so using a background ctx is fine.
-	_, err := s.parseSchedule(context.Background(), defaultSchedule)
+	_, err := s.parseSchedule(context.Background(), defaultSchedule, defaultSchedule)
 	if err != nil {
 		return nil, xerrors.Errorf("parse default schedule: %w", err)
 	}
@@ -39,14 +54,16 @@ func NewEnterpriseUserQuietHoursScheduleStore(defaultSchedule string) (agpl.User
 	return s, nil
 }
 
-func (s *enterpriseUserQuietHoursScheduleStore) parseSchedule(ctx context.Context, rawSchedule string) (agpl.UserQuietHoursScheduleOptions, error) {
+// parseSchedule parses the user's schedule, falling back to defaultSchedule
+// if the user hasn't set one.
+func (*enterpriseUserQuietHoursScheduleStore) parseSchedule(ctx context.Context, rawSchedule, defaultSchedule string) (agpl.UserQuietHoursScheduleOptions, error) {
 	_, span := tracing.StartSpan(ctx)
 	defer span.End()
 
 	userSet := true
 	if strings.TrimSpace(rawSchedule) == "" {
 		userSet = false
-		rawSchedule = s.defaultSchedule
+		rawSchedule = defaultSchedule
 	}
 
 	sched, err := agpl.Daily(rawSchedule)
@@ -77,14 +94,41 @@ func (s *enterpriseUserQuietHoursScheduleStore) Get(ctx context.Context, db data
 		return agpl.UserQuietHoursScheduleOptions{}, xerrors.Errorf("get user by ID: %w", err)
 	}
 
-	return s.parseSchedule(ctx, user.QuietHoursSchedule)
+	defaultSchedule := s.defaultSchedule
+	if strings.TrimSpace(user.QuietHoursSchedule) == "" {
+		defaultSchedule, err = s.userDefaultSchedule(ctx, db, userID)
+		if err != nil {
+			return agpl.UserQuietHoursScheduleOptions{}, err
+		}
+	}
+
+	opts, err := s.parseSchedule(ctx, user.QuietHoursSchedule, defaultSchedule)
+	if err != nil {
+		return agpl.UserQuietHoursScheduleOptions{}, err
+	}
+
+	opts.Exceptions, err = getExceptions(ctx, db, userID)
+	if err != nil {
+		return agpl.UserQuietHoursScheduleOptions{}, err
+	}
+
+	return opts, nil
 }
 
 func (s *enterpriseUserQuietHoursScheduleStore) Set(ctx context.Context, db database.Store, userID uuid.UUID, rawSchedule string) (agpl.UserQuietHoursScheduleOptions, error) {
 	ctx, span := tracing.StartSpan(ctx)
 	defer span.End()
 
-	opts, err := s.parseSchedule(ctx, rawSchedule)
+	defaultSchedule := s.defaultSchedule
+	if strings.TrimSpace(rawSchedule) == "" {
+		var err error
+		defaultSchedule, err = s.userDefaultSchedule(ctx, db, userID)
+		if err != nil {
+			return agpl.UserQuietHoursScheduleOptions{}, err
+		}
+	}
+
+	opts, err := s.parseSchedule(ctx, rawSchedule, defaultSchedule)
 	if err != nil {
 		return opts, err
 	}
@@ -94,13 +138,25 @@ func (s *enterpriseUserQuietHoursScheduleStore) Set(ctx context.Context, db data
 	if opts.UserSet {
 		rawSchedule = opts.Schedule.String()
 	}
-	_, err = db.UpdateUserQuietHoursSchedule(ctx, database.UpdateUserQuietHoursScheduleParams{
+	oldUser, err := db.GetUserByID(ctx, userID)
+	if err != nil {
+		return agpl.UserQuietHoursScheduleOptions{}, xerrors.Errorf("get user by ID: %w", err)
+	}
+	newUser, err := db.UpdateUserQuietHoursSchedule(ctx, database.UpdateUserQuietHoursScheduleParams{
 		ID:                 userID,
 		QuietHoursSchedule: rawSchedule,
 	})
 	if err != nil {
 		return agpl.UserQuietHoursScheduleOptions{}, xerrors.Errorf("update user quiet hours schedule: %w", err)
 	}
+	if oldUser.QuietHoursSchedule != newUser.QuietHoursSchedule {
+		auditScheduleChange(ctx, s.auditor, s.logger, uuid.New(), http.StatusOK, oldUser, newUser, agpl.TemplateScheduleChanges{})
+	}
+
+	opts.Exceptions, err = getExceptions(ctx, db, userID)
+	if err != nil {
+		return agpl.UserQuietHoursScheduleOptions{}, err
+	}
 
 	// We don't update workspace build deadlines when the user changes their own
 	// quiet hours schedule, because they could potentially keep their workspace
@@ -111,3 +167,88 @@ func (s *enterpriseUserQuietHoursScheduleStore) Set(ctx context.Context, db data
 
 	return opts, nil
 }
+
+func (s *enterpriseUserQuietHoursScheduleStore) AddException(ctx context.Context, db database.Store, userID uuid.UUID, date time.Time) (agpl.UserQuietHoursScheduleOptions, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+
+	_, err := db.InsertUserQuietHoursException(ctx, database.InsertUserQuietHoursExceptionParams{
+		UserID:    userID,
+		Date:      date,
+		CreatedAt: database.Now(),
+	})
+	if err != nil {
+		return agpl.UserQuietHoursScheduleOptions{}, xerrors.Errorf("insert user quiet hours exception: %w", err)
+	}
+
+	return s.Get(ctx, db, userID)
+}
+
+func (s *enterpriseUserQuietHoursScheduleStore) DeleteException(ctx context.Context, db database.Store, userID uuid.UUID, date time.Time) (agpl.UserQuietHoursScheduleOptions, error) {
+	ctx, span := tracing.StartSpan(ctx)
+	defer span.End()
+
+	err := db.DeleteUserQuietHoursException(ctx, database.DeleteUserQuietHoursExceptionParams{
+		UserID: userID,
+		Date:   date,
+	})
+	if err != nil {
+		return agpl.UserQuietHoursScheduleOptions{}, xerrors.Errorf("delete user quiet hours exception: %w", err)
+	}
+
+	return s.Get(ctx, db, userID)
+}
+
+// userDefaultSchedule returns the quiet hours schedule used for the user if
+// they haven't set one. If all organizations of the user that have a default
+// quiet hours schedule agree on it, it is used instead of the deployment
+// default.
+func (s *enterpriseUserQuietHoursScheduleStore) userDefaultSchedule(ctx context.Context, db database.Store, userID uuid.UUID) (string, error) {
+	//nolint:gocritic // Users can't necessarily read the schedule settings of
+	// their organizations, but they apply to them regardless.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+
+	rows, err := db.GetOrganizationIDsByMemberIDs(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return "", xerrors.Errorf("get organizations of user: %w", err)
+	}
+
+	schedule := ""
+	for _, row := range rows {
+		for _, organizationID := range row.OrganizationIDs {
+			settings, err := db.GetOrganizationScheduleSettings(ctx, organizationID)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return "", xerrors.Errorf("get organization schedule settings: %w", err)
+			}
+			if settings.DefaultQuietHoursSchedule == "" {
+				continue
+			}
+			if schedule != "" && schedule != settings.DefaultQuietHoursSchedule {
+				// The organizations disagree, so there is no obvious
+				// choice.
+				return s.defaultSchedule, nil
+			}
+			schedule = settings.DefaultQuietHoursSchedule
+		}
+	}
+	if schedule == "" {
+		return s.defaultSchedule, nil
+	}
+	return schedule, nil
+}
+
+func getExceptions(ctx context.Context, db database.Store, userID uuid.UUID) ([]time.Time, error) {
+	exceptions, err := db.GetUserQuietHoursExceptions(ctx, userID)
+	if err != nil {
+		return nil, xerrors.Errorf("get user quiet hours exceptions: %w", err)
+	}
+
+	dates := make([]time.Time, 0, len(exceptions))
+	for _, exception := range exceptions {
+		dates = append(dates, exception.Date)
+	}
+	return dates, nil
+}
@@ -0,0 +1,48 @@
+package schedule_test
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/database/dbtestutil"
+	"github.com/coder/coder/enterprise/coderd/schedule"
+	"github.com/coder/coder/testutil"
+)
+
+func TestUserQuietHoursScheduleSetAudit(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ctx   = testutil.Context(t, testutil.WaitLong)
+		db, _ = dbtestutil.NewDB(t)
+		user  = dbgen.User(t, db, database.User{})
+	)
+
+	auditor := audit.NewMock()
+	auditorPtr := &atomic.Pointer[audit.Auditor]{}
+	var auditorInterface audit.Auditor = auditor
+	auditorPtr.Store(&auditorInterface)
+
+	store, err := schedule.NewEnterpriseUserQuietHoursScheduleStore("CRON_TZ=UTC 0 0 * * *", auditorPtr, slogtest.Make(t, nil))
+	require.NoError(t, err)
+
+	_, err = store.Set(ctx, db, user.ID, "CRON_TZ=UTC 0 1 * * *")
+	require.NoError(t, err)
+
+	logs := auditor.AuditLogs()
+	require.Len(t, logs, 1)
+	require.Equal(t, database.ResourceTypeUser, logs[0].ResourceType)
+	require.Equal(t, user.ID, logs[0].ResourceID)
+	require.Equal(t, database.AuditActionWrite, logs[0].Action)
+
+	// Setting the same schedule again is not audited.
+	_, err = store.Set(ctx, db, user.ID, "CRON_TZ=UTC 0 1 * * *")
+	require.NoError(t, err)
+	require.Len(t, auditor.AuditLogs(), 1)
+}
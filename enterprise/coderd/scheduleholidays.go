@@ -0,0 +1,260 @@
+package coderd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get schedule holidays
+// @ID get-schedule-holidays
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Success 200 {array} codersdk.ScheduleHoliday
+// @Router /deployment/schedule/holidays [get]
+func (api *API) scheduleHolidays(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	holidays, err := api.Database.GetScheduleHolidays(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching schedule holidays.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	res := make([]codersdk.ScheduleHoliday, 0, len(holidays))
+	for _, holiday := range holidays {
+		res = append(res, convertScheduleHoliday(holiday))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, res)
+}
+
+// @Summary Get schedule holiday
+// @ID get-schedule-holiday
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param holiday path string true "Holiday ID" format(uuid)
+// @Success 200 {object} codersdk.ScheduleHoliday
+// @Router /deployment/schedule/holidays/{holiday} [get]
+func (api *API) scheduleHoliday(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	holidayID, ok := httpmw.ParseUUIDParam(rw, r, "holiday")
+	if !ok {
+		return
+	}
+
+	holiday, err := api.Database.GetScheduleHolidayByID(ctx, holidayID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching schedule holiday.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertScheduleHoliday(holiday))
+}
+
+// @Summary Create schedule holiday
+// @Description Template restart requirements are skipped on holidays.
+// @ID create-schedule-holiday
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param request body codersdk.CreateScheduleHolidayRequest true "Create holiday request"
+// @Success 201 {object} codersdk.ScheduleHoliday
+// @Router /deployment/schedule/holidays [post]
+func (api *API) postScheduleHoliday(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.CreateScheduleHolidayRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	date, ok := parseScheduleHolidayDate(rw, r, req.Date)
+	if !ok {
+		return
+	}
+
+	now := database.Now()
+	holiday, err := api.Database.InsertScheduleHoliday(ctx, database.InsertScheduleHolidayParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Date:      date,
+		Name:      req.Name,
+	})
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("A holiday already exists on %s.", req.Date),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating schedule holiday.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	api.AGPL.ScheduleHolidays.Invalidate()
+	httpapi.Write(ctx, rw, http.StatusCreated, convertScheduleHoliday(holiday))
+}
+
+// @Summary Update schedule holiday
+// @ID update-schedule-holiday
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param holiday path string true "Holiday ID" format(uuid)
+// @Param request body codersdk.UpdateScheduleHolidayRequest true "Update holiday request"
+// @Success 200 {object} codersdk.ScheduleHoliday
+// @Router /deployment/schedule/holidays/{holiday} [put]
+func (api *API) putScheduleHoliday(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	holidayID, ok := httpmw.ParseUUIDParam(rw, r, "holiday")
+	if !ok {
+		return
+	}
+
+	var req codersdk.UpdateScheduleHolidayRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	date, ok := parseScheduleHolidayDate(rw, r, req.Date)
+	if !ok {
+		return
+	}
+
+	holiday, err := api.Database.UpdateScheduleHolidayByID(ctx, database.UpdateScheduleHolidayByIDParams{
+		ID:        holidayID,
+		UpdatedAt: database.Now(),
+		Date:      date,
+		Name:      req.Name,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("A holiday already exists on %s.", req.Date),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating schedule holiday.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	api.AGPL.ScheduleHolidays.Invalidate()
+	httpapi.Write(ctx, rw, http.StatusOK, convertScheduleHoliday(holiday))
+}
+
+// @Summary Delete schedule holiday
+// @ID delete-schedule-holiday
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param holiday path string true "Holiday ID" format(uuid)
+// @Success 204
+// @Router /deployment/schedule/holidays/{holiday} [delete]
+func (api *API) deleteScheduleHoliday(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if !api.Authorize(r, rbac.ActionDelete, rbac.ResourceDeploymentValues) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	holidayID, ok := httpmw.ParseUUIDParam(rw, r, "holiday")
+	if !ok {
+		return
+	}
+
+	_, err := api.Database.GetScheduleHolidayByID(ctx, holidayID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching schedule holiday.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	err = api.Database.DeleteScheduleHolidayByID(ctx, holidayID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting schedule holiday.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	api.AGPL.ScheduleHolidays.Invalidate()
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// parseScheduleHolidayDate parses the date of a holiday request. If the date
+// is invalid, a 400 is written to the response.
+func parseScheduleHolidayDate(rw http.ResponseWriter, r *http.Request, raw string) (time.Time, bool) {
+	date, err := time.Parse(codersdk.ScheduleHolidayDateLayout, raw)
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid request to create or update schedule holiday!",
+			Validations: []codersdk.ValidationError{
+				{Field: "date", Detail: "Must be a date in the format YYYY-MM-DD."},
+			},
+		})
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func convertScheduleHoliday(holiday database.ScheduleHoliday) codersdk.ScheduleHoliday {
+	return codersdk.ScheduleHoliday{
+		ID:        holiday.ID,
+		CreatedAt: holiday.CreatedAt,
+		UpdatedAt: holiday.UpdatedAt,
+		Date:      holiday.Date.Format(codersdk.ScheduleHolidayDateLayout),
+		Name:      holiday.Name,
+	}
+}
@@ -0,0 +1,194 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/testutil"
+)
+
+func TestScheduleHolidays(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments.Set(string(codersdk.ExperimentTemplateRestartRequirement))
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues: dv,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+					codersdk.FeatureTemplateRestartRequirement: 1,
+				},
+			},
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		christmas, err := client.CreateScheduleHoliday(ctx, codersdk.CreateScheduleHolidayRequest{
+			Date: "2023-12-25",
+			Name: "Christmas Day",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "2023-12-25", christmas.Date)
+		require.Equal(t, "Christmas Day", christmas.Name)
+
+		newYear, err := client.CreateScheduleHoliday(ctx, codersdk.CreateScheduleHolidayRequest{
+			Date: "2023-01-01",
+			Name: "New Year's Day",
+		})
+		require.NoError(t, err)
+
+		// Holidays are ordered by date.
+		holidays, err := client.ScheduleHolidays(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []codersdk.ScheduleHoliday{newYear, christmas}, holidays)
+
+		got, err := client.ScheduleHoliday(ctx, christmas.ID)
+		require.NoError(t, err)
+		require.Equal(t, christmas, got)
+
+		updated, err := client.UpdateScheduleHoliday(ctx, christmas.ID, codersdk.UpdateScheduleHolidayRequest{
+			Date: "2023-12-26",
+			Name: "Boxing Day",
+		})
+		require.NoError(t, err)
+		require.Equal(t, christmas.ID, updated.ID)
+		require.Equal(t, "2023-12-26", updated.Date)
+		require.Equal(t, "Boxing Day", updated.Name)
+
+		// Members can read holidays but not change them.
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		holidays, err = member.ScheduleHolidays(ctx)
+		require.NoError(t, err)
+		require.Len(t, holidays, 2)
+		_, err = member.CreateScheduleHoliday(ctx, codersdk.CreateScheduleHolidayRequest{
+			Date: "2023-07-04",
+			Name: "Independence Day",
+		})
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusForbidden, sdkErr.StatusCode())
+
+		err = client.DeleteScheduleHoliday(ctx, newYear.ID)
+		require.NoError(t, err)
+		_, err = client.ScheduleHoliday(ctx, newYear.ID)
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+		err = client.DeleteScheduleHoliday(ctx, newYear.ID)
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments.Set(string(codersdk.ExperimentTemplateRestartRequirement))
+
+		client, _ := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues: dv,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+					codersdk.FeatureTemplateRestartRequirement: 1,
+				},
+			},
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.CreateScheduleHoliday(ctx, codersdk.CreateScheduleHolidayRequest{
+			Date: "2023-12-25",
+			Name: "Christmas Day",
+		})
+		require.NoError(t, err)
+		boxingDay, err := client.CreateScheduleHoliday(ctx, codersdk.CreateScheduleHolidayRequest{
+			Date: "2023-12-26",
+			Name: "Boxing Day",
+		})
+		require.NoError(t, err)
+
+		var sdkErr *codersdk.Error
+		_, err = client.CreateScheduleHoliday(ctx, codersdk.CreateScheduleHolidayRequest{
+			Date: "2023-12-25",
+			Name: "Christmas",
+		})
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusConflict, sdkErr.StatusCode())
+
+		_, err = client.UpdateScheduleHoliday(ctx, boxingDay.ID, codersdk.UpdateScheduleHolidayRequest{
+			Date: "2023-12-25",
+			Name: "Boxing Day",
+		})
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusConflict, sdkErr.StatusCode())
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments.Set(string(codersdk.ExperimentTemplateRestartRequirement))
+
+		client, _ := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues: dv,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+					codersdk.FeatureTemplateRestartRequirement: 1,
+				},
+			},
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.CreateScheduleHoliday(ctx, codersdk.CreateScheduleHolidayRequest{
+			Date: "25/12/2023",
+			Name: "Christmas Day",
+		})
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode())
+		require.Len(t, sdkErr.Validations, 1)
+		require.Equal(t, "date", sdkErr.Validations[0].Field)
+	})
+
+	t.Run("NotEntitled", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments.Set(string(codersdk.ExperimentTemplateRestartRequirement))
+
+		client, _ := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues: dv,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+					// Not entitled.
+					// codersdk.FeatureTemplateRestartRequirement: 1,
+				},
+			},
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.ScheduleHolidays(ctx)
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusForbidden, sdkErr.StatusCode())
+	})
+}
@@ -8,9 +8,13 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/cryptorand"
 	"github.com/coder/coder/enterprise/coderd"
@@ -339,3 +343,188 @@ func TestScim(t *testing.T) {
 		})
 	})
 }
+
+func TestScimGroups(t *testing.T) {
+	t.Parallel()
+
+	scimAPIKey := []byte("hi")
+	setup := func(t *testing.T) (*codersdk.Client, codersdk.CreateFirstUserResponse, *audit.MockAuditor, codersdk.Template) {
+		t.Helper()
+		auditor := audit.NewMock()
+		dv := coderdtest.DeploymentValues(t)
+		dv.SCIMGroupTemplateACLs.Value = map[string]map[string]codersdk.TemplateRole{
+			"engineers": {"docker": codersdk.TemplateRoleUse},
+		}
+		client, first := coderdenttest.New(t, &coderdenttest.Options{
+			SCIMAPIKey:   scimAPIKey,
+			AuditLogging: true,
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+				Auditor:                  auditor,
+				DeploymentValues:         dv,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				AccountID: "coolin",
+				Features: license.Features{
+					codersdk.FeatureSCIM:         1,
+					codersdk.FeatureTemplateRBAC: 1,
+					codersdk.FeatureAuditLog:     1,
+				},
+			},
+		})
+		version := coderdtest.CreateTemplateVersion(t, client, first.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, first.OrganizationID, version.ID, func(r *codersdk.CreateTemplateRequest) {
+			r.Name = "docker"
+		})
+		return client, first, auditor, template
+	}
+	request := func(ctx context.Context, t *testing.T, client *codersdk.Client, method, path string, body interface{}, status int) coderd.SCIMGroup {
+		t.Helper()
+		res, err := client.Request(ctx, method, path, body, setScimAuth(scimAPIKey))
+		require.NoError(t, err)
+		defer res.Body.Close()
+		raw, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		require.Equal(t, status, res.StatusCode, string(raw))
+		var sGroup coderd.SCIMGroup
+		if status == http.StatusOK || status == http.StatusCreated {
+			err = json.Unmarshal(raw, &sGroup)
+			require.NoError(t, err)
+		}
+		return sGroup
+	}
+	members := func(users ...codersdk.User) []coderd.SCIMGroupMember {
+		out := make([]coderd.SCIMGroupMember, 0, len(users))
+		for _, user := range users {
+			out = append(out, coderd.SCIMGroupMember{Value: user.ID.String()})
+		}
+		return out
+	}
+	groupRole := func(ctx context.Context, t *testing.T, client *codersdk.Client, templateID, groupID uuid.UUID) codersdk.TemplateRole {
+		t.Helper()
+		acl, err := client.TemplateACL(ctx, templateID)
+		require.NoError(t, err)
+		for _, group := range acl.Groups {
+			if group.ID == groupID {
+				return group.Role
+			}
+		}
+		return ""
+	}
+	memberIDs := func(t *testing.T, group codersdk.Group) []uuid.UUID {
+		t.Helper()
+		ids := make([]uuid.UUID, 0, len(group.Members))
+		for _, member := range group.Members {
+			ids = append(ids, member.ID)
+		}
+		return ids
+	}
+
+	t.Run("postGroup", func(t *testing.T) {
+		t.Parallel()
+		client, first, auditor, template := setup(t)
+		_, alice := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		numLogs := len(auditor.AuditLogs())
+		sGroup := request(ctx, t, client, http.MethodPost, "/scim/v2/Groups", coderd.SCIMGroup{
+			DisplayName: "engineers",
+			Members:     members(alice),
+		}, http.StatusCreated)
+
+		group, err := client.Group(ctx, uuid.MustParse(sGroup.ID))
+		require.NoError(t, err)
+		require.Equal(t, codersdk.GroupSourceSCIM, group.Source)
+		require.Equal(t, []uuid.UUID{alice.ID}, memberIDs(t, group))
+
+		require.Equal(t, codersdk.TemplateRoleUse, groupRole(ctx, t, client, template.ID, group.ID))
+
+		// The creation, the membership and the template ACL changes are
+		// audited.
+		logs := auditor.AuditLogs()[numLogs:]
+		require.Len(t, logs, 3)
+		require.Equal(t, group.ID, logs[0].ResourceID)
+		require.Equal(t, database.AuditActionCreate, logs[0].Action)
+		require.Equal(t, group.ID, logs[1].ResourceID)
+		require.Equal(t, database.ResourceTypeGroup, logs[1].ResourceType)
+		require.Equal(t, database.AuditActionWrite, logs[1].Action)
+		require.Equal(t, template.ID, logs[2].ResourceID)
+		require.Equal(t, database.ResourceTypeTemplate, logs[2].ResourceType)
+
+		// Pushing the group again adopts it. Nothing changed, so nothing is
+		// audited.
+		numLogs = len(auditor.AuditLogs())
+		again := request(ctx, t, client, http.MethodPost, "/scim/v2/Groups", coderd.SCIMGroup{
+			DisplayName: "engineers",
+			Members:     members(alice),
+		}, http.StatusOK)
+		require.Equal(t, sGroup.ID, again.ID)
+		require.Len(t, auditor.AuditLogs(), numLogs)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+		client, first, _, _ := setup(t)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.CreateGroup(ctx, first.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "engineers",
+		})
+		require.NoError(t, err)
+		request(ctx, t, client, http.MethodPost, "/scim/v2/Groups", coderd.SCIMGroup{
+			DisplayName: "engineers",
+		}, http.StatusConflict)
+	})
+
+	t.Run("patchGroup", func(t *testing.T) {
+		t.Parallel()
+		client, first, _, _ := setup(t)
+		_, alice := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+		_, bob := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		sGroup := request(ctx, t, client, http.MethodPost, "/scim/v2/Groups", coderd.SCIMGroup{
+			DisplayName: "testers",
+			Members:     members(alice),
+		}, http.StatusCreated)
+		groupID := uuid.MustParse(sGroup.ID)
+
+		request(ctx, t, client, http.MethodPatch, "/scim/v2/Groups/"+sGroup.ID, coderd.SCIMPatchRequest{
+			Operations: []coderd.SCIMPatchOperation{
+				{Op: "add", Path: "members", Value: members(bob)},
+				{Op: "remove", Path: fmt.Sprintf("members[value eq %q]", alice.ID)},
+				{Op: "replace", Path: "displayName", Value: "qa"},
+			},
+		}, http.StatusOK)
+		group, err := client.Group(ctx, groupID)
+		require.NoError(t, err)
+		require.Equal(t, "qa", group.Name)
+		require.Equal(t, []uuid.UUID{bob.ID}, memberIDs(t, group))
+
+		// Only the identity provider manages the group.
+		_, err = client.PatchGroup(ctx, groupID, codersdk.PatchGroupRequest{
+			AddUsers: []string{alice.ID.String()},
+		})
+		var sdkErr *codersdk.Error
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode())
+	})
+
+	t.Run("deleteGroup", func(t *testing.T) {
+		t.Parallel()
+		client, _, _, template := setup(t)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		sGroup := request(ctx, t, client, http.MethodPost, "/scim/v2/Groups", coderd.SCIMGroup{
+			DisplayName: "engineers",
+		}, http.StatusCreated)
+		request(ctx, t, client, http.MethodDelete, "/scim/v2/Groups/"+sGroup.ID, nil, http.StatusNoContent)
+
+		_, err := client.Group(ctx, uuid.MustParse(sGroup.ID))
+		require.Error(t, err)
+		require.Empty(t, groupRole(ctx, t, client, template.ID, uuid.MustParse(sGroup.ID)))
+
+		request(ctx, t, client, http.MethodGet, "/scim/v2/Groups/"+sGroup.ID, nil, http.StatusNotFound)
+	})
+}
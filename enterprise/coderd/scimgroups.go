@@ -0,0 +1,749 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/imulab/go-scim/pkg/v2/handlerutil"
+	scimjson "github.com/imulab/go-scim/pkg/v2/json"
+	"github.com/imulab/go-scim/pkg/v2/service"
+	"github.com/imulab/go-scim/pkg/v2/spec"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+)
+
+const scimGroupSchema = "urn:ietf:params:scim:schemas:core:2.0:Group"
+
+// SCIMGroup is a group pushed by the identity provider. Like SCIMUser, only
+// the fields Coder needs are included. This was tested only with Okta.
+type SCIMGroup struct {
+	Schemas     []string          `json:"schemas"`
+	ID          string            `json:"id"`
+	DisplayName string            `json:"displayName"`
+	Members     []SCIMGroupMember `json:"members"`
+	Meta        struct {
+		ResourceType string `json:"resourceType"`
+	} `json:"meta"`
+}
+
+type SCIMGroupMember struct {
+	// Value is the ID of the user that was returned when it was created.
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// SCIMPatchRequest modifies a group, see RFC 7644 section 3.5.2.
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+type SCIMPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// scimMemberFilterPath matches the path that removes a single member, e.g.
+// members[value eq "2819c223-7f76-453a-919d-413861904646"].
+var scimMemberFilterPath = regexp.MustCompile(`(?i)^members\[value eq "([^"]+)"\]$`)
+
+// scimGetGroups intentionally always returns no groups, so the identity
+// provider creates each group it pushes. Creating a group that already exists
+// returns the existing group.
+//
+// @Summary SCIM 2.0: Get groups
+// @ID scim-get-groups
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Success 200
+// @Router /scim/v2/Groups [get]
+//
+//nolint:revive
+func (api *API) scimGetGroups(rw http.ResponseWriter, r *http.Request) {
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	_ = handlerutil.WriteSearchResultToResponse(rw, &service.QueryResponse{
+		TotalResults: 0,
+		StartIndex:   1,
+		ItemsPerPage: 0,
+		Resources:    []scimjson.Serializable{},
+	})
+}
+
+// scimGetGroup returns a group that was pushed by SCIM. Groups that were
+// created in Coder aren't returned.
+//
+// @Summary SCIM 2.0: Get group by ID
+// @ID scim-get-group-by-id
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Success 200 {object} coderd.SCIMGroup
+// @Router /scim/v2/Groups/{id} [get]
+func (api *API) scimGetGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	group, memberIDs, err := api.scimGroupParam(ctx, r)
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertSCIMGroup(group, memberIDs))
+}
+
+// scimPostGroup creates a group, or adopts the existing group of the same name
+// if it was pushed by SCIM before. Groups that were created in Coder are never
+// adopted.
+//
+// @Summary SCIM 2.0: Create new group
+// @ID scim-create-new-group
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param request body coderd.SCIMGroup true "New group"
+// @Success 201 {object} coderd.SCIMGroup
+// @Router /scim/v2/Groups [post]
+func (api *API) scimPostGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	var sGroup SCIMGroup
+	err := json.NewDecoder(r.Body).Decode(&sGroup)
+	if err != nil {
+		scimWriteError(rw, xerrors.Errorf("decode group: %w", spec.ErrInvalidSyntax))
+		return
+	}
+	if sGroup.DisplayName == "" {
+		scimWriteError(rw, xerrors.Errorf("displayName is required: %w", spec.ErrInvalidValue))
+		return
+	}
+	if sGroup.DisplayName == database.AllUsersGroup {
+		scimWriteError(rw, xerrors.Errorf("%q is a reserved group name: %w", database.AllUsersGroup, spec.ErrUniqueness))
+		return
+	}
+	memberIDs, err := scimMemberIDs(sGroup.Members)
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+
+	//nolint:gocritic // needed for SCIM
+	organizations, err := api.Database.GetOrganizations(dbauthz.AsSystemRestricted(ctx))
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+	if len(organizations) == 0 {
+		scimWriteError(rw, xerrors.Errorf("no organization exists: %w", spec.ErrConflict))
+		return
+	}
+	// Groups are created in the organization SCIM users are added to.
+	organizationID := organizations[0].ID
+
+	//nolint:gocritic // needed for SCIM
+	created, err := api.Database.InsertMissingGroups(dbauthz.AsSystemRestricted(ctx), database.InsertMissingGroupsParams{
+		OrganizationID: organizationID,
+		GroupNames:     []string{sGroup.DisplayName},
+		Source:         database.GroupSourceScim,
+	})
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+	status := http.StatusCreated
+	var group database.Group
+	if len(created) == 1 {
+		group = created[0]
+		scimAudit(ctx, api, database.AuditActionCreate, database.AuditableGroup{}, scimAuditableGroup(group, nil))
+	} else {
+		//nolint:gocritic // needed for SCIM
+		group, err = api.Database.GetGroupByOrgAndName(dbauthz.AsSystemRestricted(ctx), database.GetGroupByOrgAndNameParams{
+			OrganizationID: organizationID,
+			Name:           sGroup.DisplayName,
+		})
+		if err != nil {
+			scimWriteError(rw, err)
+			return
+		}
+		if group.Source != database.GroupSourceScim {
+			scimWriteError(rw, xerrors.Errorf("a group named %q that isn't managed by SCIM already exists: %w", group.Name, spec.ErrUniqueness))
+			return
+		}
+		status = http.StatusOK
+	}
+
+	group, memberIDs, err = api.scimSyncGroup(ctx, group, group.Name, memberIDs)
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, status, convertSCIMGroup(group, memberIDs))
+}
+
+// scimPutGroup replaces the name and the members of a group.
+//
+// @Summary SCIM 2.0: Replace group
+// @ID scim-replace-group
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Param request body coderd.SCIMGroup true "Replace group request"
+// @Success 200 {object} coderd.SCIMGroup
+// @Router /scim/v2/Groups/{id} [put]
+func (api *API) scimPutGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	group, _, err := api.scimGroupParam(ctx, r)
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+	var sGroup SCIMGroup
+	err = json.NewDecoder(r.Body).Decode(&sGroup)
+	if err != nil {
+		scimWriteError(rw, xerrors.Errorf("decode group: %w", spec.ErrInvalidSyntax))
+		return
+	}
+	memberIDs, err := scimMemberIDs(sGroup.Members)
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+	name := sGroup.DisplayName
+	if name == "" {
+		name = group.Name
+	}
+
+	group, memberIDs, err = api.scimSyncGroup(ctx, group, name, memberIDs)
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertSCIMGroup(group, memberIDs))
+}
+
+// scimPatchGroup adds, removes and replaces the members of a group, and
+// renames it.
+//
+// @Summary SCIM 2.0: Update group
+// @ID scim-update-group
+// @Security CoderSessionToken
+// @Produce application/scim+json
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Param request body coderd.SCIMPatchRequest true "Update group request"
+// @Success 200 {object} coderd.SCIMGroup
+// @Router /scim/v2/Groups/{id} [patch]
+func (api *API) scimPatchGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	group, currentIDs, err := api.scimGroupParam(ctx, r)
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+	var req SCIMPatchRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		scimWriteError(rw, xerrors.Errorf("decode patch request: %w", spec.ErrInvalidSyntax))
+		return
+	}
+
+	name := group.Name
+	members := make(map[uuid.UUID]struct{}, len(currentIDs))
+	for _, id := range currentIDs {
+		members[id] = struct{}{}
+	}
+	for _, op := range req.Operations {
+		err = applySCIMPatchOperation(op, &name, members)
+		if err != nil {
+			scimWriteError(rw, err)
+			return
+		}
+	}
+	memberIDs := make([]uuid.UUID, 0, len(members))
+	for id := range members {
+		memberIDs = append(memberIDs, id)
+	}
+
+	group, memberIDs, err = api.scimSyncGroup(ctx, group, name, memberIDs)
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, convertSCIMGroup(group, memberIDs))
+}
+
+// scimDeleteGroup deletes a group that was pushed by SCIM, and removes its
+// roles on templates.
+//
+// @Summary SCIM 2.0: Delete group
+// @ID scim-delete-group
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param id path string true "Group ID" format(uuid)
+// @Success 204
+// @Router /scim/v2/Groups/{id} [delete]
+func (api *API) scimDeleteGroup(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.scimVerifyAuthHeader(r) {
+		_ = handlerutil.WriteError(rw, spec.Error{Status: http.StatusUnauthorized, Type: "invalidAuthorization"})
+		return
+	}
+
+	group, memberIDs, err := api.scimGroupParam(ctx, r)
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+
+	var templateChanges []scimTemplateChange
+	//nolint:gocritic // needed for SCIM
+	err = api.Database.InTx(func(tx database.Store) error {
+		ctx := dbauthz.AsSystemRestricted(ctx)
+		var err error
+		templateChanges, err = api.scimSyncTemplateACLs(ctx, tx, group, nil)
+		if err != nil {
+			return err
+		}
+		err = tx.DeleteGroupByID(ctx, group.ID)
+		if err != nil {
+			return xerrors.Errorf("delete group: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		scimWriteError(rw, err)
+		return
+	}
+
+	scimAudit(ctx, api, database.AuditActionDelete, scimAuditableGroup(group, memberIDs), database.AuditableGroup{})
+	for _, change := range templateChanges {
+		scimAudit(ctx, api, database.AuditActionWrite, change.Old, change.New)
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// scimWriteError writes err with the status of the SCIM error it wraps.
+// handlerutil.WriteError only looks at the error err wraps directly, which
+// isn't enough for errors returned from a transaction.
+func scimWriteError(rw http.ResponseWriter, err error) {
+	var scimErr *spec.Error
+	if errors.As(err, &scimErr) {
+		err = scimWrappedError{err: err, cause: scimErr}
+	}
+	_ = handlerutil.WriteError(rw, err)
+}
+
+type scimWrappedError struct {
+	err   error
+	cause *spec.Error
+}
+
+func (e scimWrappedError) Error() string { return e.err.Error() }
+func (e scimWrappedError) Unwrap() error { return e.cause }
+
+// scimGroupParam returns the group of the id URL parameter and the IDs of its
+// members. Groups that weren't pushed by SCIM aren't found.
+func (api *API) scimGroupParam(ctx context.Context, r *http.Request) (database.Group, []uuid.UUID, error) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return database.Group{}, nil, xerrors.Errorf("invalid group id: %w", spec.ErrInvalidValue)
+	}
+	//nolint:gocritic // needed for SCIM
+	group, err := api.Database.GetGroupByID(dbauthz.AsSystemRestricted(ctx), id)
+	if xerrors.Is(err, sql.ErrNoRows) || (err == nil && group.Source != database.GroupSourceScim) {
+		return database.Group{}, nil, xerrors.Errorf("group %s: %w", id, spec.ErrNotFound)
+	}
+	if err != nil {
+		return database.Group{}, nil, err
+	}
+	//nolint:gocritic // needed for SCIM
+	memberIDs, err := api.Database.GetGroupMemberUserIDs(dbauthz.AsSystemRestricted(ctx), group.ID)
+	if err != nil {
+		return database.Group{}, nil, err
+	}
+	return group, memberIDs, nil
+}
+
+type scimTemplateChange struct {
+	Old database.Template
+	New database.Template
+}
+
+// scimSyncGroup renames a group, replaces its members, and applies the roles
+// on templates that are mapped to it. Members that aren't in the organization
+// of the group are skipped. The changes are recorded in the audit log, a sync
+// that doesn't change anything isn't.
+func (api *API) scimSyncGroup(ctx context.Context, group database.Group, name string, memberIDs []uuid.UUID) (database.Group, []uuid.UUID, error) {
+	var (
+		old             database.AuditableGroup
+		groupChanged    bool
+		templateChanges []scimTemplateChange
+		synced          []uuid.UUID
+	)
+	//nolint:gocritic // needed for SCIM
+	err := api.Database.InTx(func(tx database.Store) error {
+		ctx := dbauthz.AsSystemRestricted(ctx)
+		currentIDs, err := tx.GetGroupMemberUserIDs(ctx, group.ID)
+		if err != nil {
+			return xerrors.Errorf("get group members: %w", err)
+		}
+		old = scimAuditableGroup(group, currentIDs)
+
+		if name != group.Name {
+			if name == database.AllUsersGroup {
+				return xerrors.Errorf("%q is a reserved group name: %w", database.AllUsersGroup, spec.ErrUniqueness)
+			}
+			_, err = tx.GetGroupByOrgAndName(ctx, database.GetGroupByOrgAndNameParams{
+				OrganizationID: group.OrganizationID,
+				Name:           name,
+			})
+			if err == nil {
+				return xerrors.Errorf("a group named %q already exists: %w", name, spec.ErrUniqueness)
+			}
+			if !xerrors.Is(err, sql.ErrNoRows) {
+				return xerrors.Errorf("get group by name: %w", err)
+			}
+			group, err = tx.UpdateGroupByID(ctx, database.UpdateGroupByIDParams{
+				ID:             group.ID,
+				Name:           name,
+				DisplayName:    group.DisplayName,
+				AvatarURL:      group.AvatarURL,
+				QuotaAllowance: group.QuotaAllowance,
+			})
+			if err != nil {
+				return xerrors.Errorf("rename group: %w", err)
+			}
+			groupChanged = true
+		}
+
+		current := make(map[uuid.UUID]struct{}, len(currentIDs))
+		for _, id := range currentIDs {
+			current[id] = struct{}{}
+		}
+		desired := make(map[uuid.UUID]struct{}, len(memberIDs))
+		for _, id := range memberIDs {
+			if _, ok := desired[id]; ok {
+				continue
+			}
+			_, err := tx.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+				OrganizationID: group.OrganizationID,
+				UserID:         id,
+			})
+			if xerrors.Is(err, sql.ErrNoRows) {
+				api.Logger.Warn(ctx, "skipping scim group member that isn't in the organization",
+					slog.F("group", group.Name), slog.F("user_id", id))
+				continue
+			}
+			if err != nil {
+				return xerrors.Errorf("get organization member: %w", err)
+			}
+			desired[id] = struct{}{}
+			synced = append(synced, id)
+			if _, ok := current[id]; ok {
+				continue
+			}
+			err = tx.InsertGroupMember(ctx, database.InsertGroupMemberParams{
+				UserID:  id,
+				GroupID: group.ID,
+			})
+			if err != nil {
+				return xerrors.Errorf("insert group member %q: %w", id, err)
+			}
+			groupChanged = true
+		}
+		for _, id := range currentIDs {
+			if _, ok := desired[id]; ok {
+				continue
+			}
+			err = tx.DeleteGroupMemberFromGroup(ctx, database.DeleteGroupMemberFromGroupParams{
+				UserID:  id,
+				GroupID: group.ID,
+			})
+			if err != nil {
+				return xerrors.Errorf("delete group member %q: %w", id, err)
+			}
+			groupChanged = true
+		}
+
+		templateChanges, err = api.scimSyncTemplateACLs(ctx, tx, group, api.AGPL.DeploymentValues.SCIMGroupTemplateACLs.Value[group.Name])
+		return err
+	}, nil)
+	if err != nil {
+		return database.Group{}, nil, err
+	}
+
+	if groupChanged {
+		scimAudit(ctx, api, database.AuditActionWrite, old, scimAuditableGroup(group, synced))
+	}
+	for _, change := range templateChanges {
+		scimAudit(ctx, api, database.AuditActionWrite, change.Old, change.New)
+	}
+	return group, synced, nil
+}
+
+// scimSyncTemplateACLs gives the group the roles on templates it's mapped to,
+// and removes its roles on all other templates of the organization. Mapped
+// templates that don't exist are skipped.
+func (api *API) scimSyncTemplateACLs(ctx context.Context, tx database.Store, group database.Group, roles map[string]codersdk.TemplateRole) ([]scimTemplateChange, error) {
+	templates, err := tx.GetTemplatesWithFilter(ctx, database.GetTemplatesWithFilterParams{
+		OrganizationID: group.OrganizationID,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("get templates: %w", err)
+	}
+
+	var (
+		groupID = group.ID.String()
+		found   = make(map[string]struct{}, len(roles))
+		changes []scimTemplateChange
+	)
+	for _, template := range templates {
+		role, ok := roles[template.Name]
+		if ok {
+			found[template.Name] = struct{}{}
+		}
+		want := convertSDKTemplateRole(role)
+		if convertToTemplateRole(template.GroupACL[groupID]) == convertToTemplateRole(want) {
+			continue
+		}
+
+		groupACL := make(database.TemplateACL, len(template.GroupACL)+1)
+		for id, actions := range template.GroupACL {
+			groupACL[id] = actions
+		}
+		if len(want) == 0 {
+			delete(groupACL, groupID)
+		} else {
+			groupACL[groupID] = want
+		}
+		err = tx.UpdateTemplateACLByID(ctx, database.UpdateTemplateACLByIDParams{
+			ID:       template.ID,
+			UserACL:  template.UserACL,
+			GroupACL: groupACL,
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("update acl of template %q: %w", template.Name, err)
+		}
+		updated, err := tx.GetTemplateByID(ctx, template.ID)
+		if err != nil {
+			return nil, xerrors.Errorf("get updated template %q: %w", template.Name, err)
+		}
+		changes = append(changes, scimTemplateChange{Old: template, New: updated})
+	}
+
+	missing := make([]string, 0, len(roles))
+	for name := range roles {
+		if _, ok := found[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		api.Logger.Warn(ctx, "skipping templates mapped to scim group that don't exist",
+			slog.F("group", group.Name), slog.F("templates", missing))
+	}
+	return changes, nil
+}
+
+// scimAudit records a change made by SCIM. SCIM requests aren't made by a
+// user and don't pass through the request ID middleware, so they're recorded
+// like builds started by the system.
+func scimAudit[T audit.Auditable](ctx context.Context, api *API, action database.AuditAction, old, new T) {
+	audit.BuildAudit(ctx, &audit.BuildAuditParams[T]{
+		Audit:  *api.AGPL.Auditor.Load(),
+		Log:    api.Logger,
+		JobID:  uuid.New(),
+		Status: http.StatusOK,
+		Action: action,
+		Old:    old,
+		New:    new,
+	})
+}
+
+func scimAuditableGroup(group database.Group, memberIDs []uuid.UUID) database.AuditableGroup {
+	users := make([]database.User, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		users = append(users, database.User{ID: id})
+	}
+	return group.Auditable(users)
+}
+
+// applySCIMPatchOperation applies an operation of a patch request to the name
+// and the members of a group.
+func applySCIMPatchOperation(op SCIMPatchOperation, name *string, members map[uuid.UUID]struct{}) error {
+	path := strings.ToLower(op.Path)
+	switch strings.ToLower(op.Op) {
+	case "add":
+		if path != "members" {
+			return xerrors.Errorf("can't add to %q: %w", op.Path, spec.ErrInvalidPath)
+		}
+		ids, err := scimPatchMemberIDs(op.Value)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			members[id] = struct{}{}
+		}
+	case "remove":
+		if match := scimMemberFilterPath.FindStringSubmatch(op.Path); match != nil {
+			id, err := uuid.Parse(match[1])
+			if err != nil {
+				return xerrors.Errorf("invalid member id %q: %w", match[1], spec.ErrInvalidValue)
+			}
+			delete(members, id)
+			return nil
+		}
+		if path != "members" {
+			return xerrors.Errorf("can't remove %q: %w", op.Path, spec.ErrInvalidPath)
+		}
+		if op.Value == nil {
+			for id := range members {
+				delete(members, id)
+			}
+			return nil
+		}
+		ids, err := scimPatchMemberIDs(op.Value)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			delete(members, id)
+		}
+	case "replace":
+		switch path {
+		case "members":
+			ids, err := scimPatchMemberIDs(op.Value)
+			if err != nil {
+				return err
+			}
+			for id := range members {
+				delete(members, id)
+			}
+			for _, id := range ids {
+				members[id] = struct{}{}
+			}
+		case "displayname":
+			displayName, ok := op.Value.(string)
+			if !ok || displayName == "" {
+				return xerrors.Errorf("displayName must be a string: %w", spec.ErrInvalidValue)
+			}
+			*name = displayName
+		case "":
+			// Without a path, the value has the attributes to replace.
+			var value SCIMGroup
+			err := remarshalSCIMValue(op.Value, &value)
+			if err != nil {
+				return err
+			}
+			if value.DisplayName != "" {
+				*name = value.DisplayName
+			}
+			if value.Members != nil {
+				ids, err := scimMemberIDs(value.Members)
+				if err != nil {
+					return err
+				}
+				for id := range members {
+					delete(members, id)
+				}
+				for _, id := range ids {
+					members[id] = struct{}{}
+				}
+			}
+		default:
+			return xerrors.Errorf("can't replace %q: %w", op.Path, spec.ErrInvalidPath)
+		}
+	default:
+		return xerrors.Errorf("unsupported operation %q: %w", op.Op, spec.ErrInvalidSyntax)
+	}
+	return nil
+}
+
+func scimPatchMemberIDs(value interface{}) ([]uuid.UUID, error) {
+	var members []SCIMGroupMember
+	err := remarshalSCIMValue(value, &members)
+	if err != nil {
+		return nil, err
+	}
+	return scimMemberIDs(members)
+}
+
+// remarshalSCIMValue decodes the value of a patch operation into v.
+func remarshalSCIMValue(value interface{}, v interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return xerrors.Errorf("encode value: %w", spec.ErrInvalidValue)
+	}
+	err = json.Unmarshal(raw, v)
+	if err != nil {
+		return xerrors.Errorf("decode value: %w", spec.ErrInvalidValue)
+	}
+	return nil
+}
+
+func scimMemberIDs(members []SCIMGroupMember) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		id, err := uuid.Parse(member.Value)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid member id %q: %w", member.Value, spec.ErrInvalidValue)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func convertSCIMGroup(group database.Group, memberIDs []uuid.UUID) SCIMGroup {
+	sGroup := SCIMGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          group.ID.String(),
+		DisplayName: group.Name,
+		Members:     make([]SCIMGroupMember, 0, len(memberIDs)),
+	}
+	sGroup.Meta.ResourceType = "Group"
+	for _, id := range memberIDs {
+		sGroup.Members = append(sGroup.Members, SCIMGroupMember{Value: id.String()})
+	}
+	sort.Slice(sGroup.Members, func(i, j int) bool {
+		return sGroup.Members[i].Value < sGroup.Members[j].Value
+	})
+	return sGroup
+}
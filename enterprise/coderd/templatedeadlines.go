@@ -0,0 +1,168 @@
+package coderd
+
+import (
+	"net/http"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/schedule"
+)
+
+// @Summary Recalculate template workspace build deadlines
+// @Description Queues a job that recalculates the deadline and max_deadline of all
+// @Description active workspace builds of the template using the current template
+// @Description schedule.
+// @ID recalculate-template-workspace-build-deadlines
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param template path string true "Template ID" format(uuid)
+// @Success 201 {object} codersdk.TemplateDeadlineRecalculation
+// @Router /templates/{template}/recalculate-deadlines [post]
+func (api *API) postTemplateRecalculateDeadlines(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	if _, ok := (*api.AGPL.TemplateScheduleStore.Load()).(*schedule.EnterpriseTemplateScheduleStore); !ok {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Template schedule store is not configured for advanced template scheduling.",
+		})
+		return
+	}
+
+	job, created, err := api.deadlineRecalculator.Enqueue(ctx, api.Database, template.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error queuing deadline recalculation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !created {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "A deadline recalculation is already queued for this template.",
+			Detail:  "Job ID: " + job.ID.String(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertTemplateDeadlineRecalculation(job))
+}
+
+// @Summary Get template workspace build deadline recalculations
+// @ID get-template-workspace-build-deadline-recalculations
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param template path string true "Template ID" format(uuid)
+// @Success 200 {array} codersdk.TemplateDeadlineRecalculation
+// @Router /templates/{template}/recalculate-deadlines [get]
+func (api *API) templateRecalculateDeadlinesList(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	jobs, err := api.Database.GetTemplateDeadlineRecalculationsByTemplateID(ctx, template.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching deadline recalculations.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiJobs := make([]codersdk.TemplateDeadlineRecalculation, 0, len(jobs))
+	for _, job := range jobs {
+		apiJobs = append(apiJobs, convertTemplateDeadlineRecalculation(job))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, apiJobs)
+}
+
+// @Summary Get template workspace build deadline recalculation
+// @ID get-template-workspace-build-deadline-recalculation
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param template path string true "Template ID" format(uuid)
+// @Param job path string true "Job ID" format(uuid)
+// @Success 200 {object} codersdk.TemplateDeadlineRecalculation
+// @Router /templates/{template}/recalculate-deadlines/{job} [get]
+func (api *API) templateRecalculateDeadlines(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	jobID, ok := httpmw.ParseUUIDParam(rw, r, "job")
+	if !ok {
+		return
+	}
+
+	job, err := api.Database.GetTemplateDeadlineRecalculationByID(ctx, jobID)
+	if httpapi.Is404Error(err) || (err == nil && job.TemplateID != template.ID) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching deadline recalculation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateDeadlineRecalculation(job))
+}
+
+func convertTemplateDeadlineRecalculation(job database.TemplateDeadlineRecalculation) codersdk.TemplateDeadlineRecalculation {
+	apiJob := codersdk.TemplateDeadlineRecalculation{
+		ID:              job.ID,
+		TemplateID:      job.TemplateID,
+		Status:          codersdk.TemplateDeadlineRecalculationStatus(job.Status),
+		CreatedAt:       job.CreatedAt,
+		TotalBuilds:     int(job.TotalBuilds),
+		ProcessedBuilds: int(job.ProcessedBuilds),
+		Error:           job.Error,
+	}
+	if job.CompletedAt.Valid {
+		apiJob.CompletedAt = &job.CompletedAt.Time
+	}
+	return apiJob
+}
+
+func (api *API) advancedTemplateSchedulingEnabledMW(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		api.entitlementsMu.RLock()
+		enabled := api.entitlements.Features[codersdk.FeatureAdvancedTemplateScheduling].Enabled
+		api.entitlementsMu.RUnlock()
+		if !enabled {
+			httpapi.Write(r.Context(), rw, http.StatusForbidden, codersdk.Response{
+				Message: "Advanced template scheduling is an Enterprise feature. Contact sales!",
+			})
+			return
+		}
+
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -8,12 +8,15 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/schedule"
+	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/cryptorand"
 	"github.com/coder/coder/enterprise/coderd/coderdenttest"
@@ -184,6 +187,163 @@ func TestTemplates(t *testing.T) {
 		require.EqualValues(t, 3, template.RestartRequirement.Weeks)
 	})
 
+	t.Run("SetRestartRequirementTimezone", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+				},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.Empty(t, template.RestartRequirement.Timezone)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		req := codersdk.UpdateTemplateMeta{
+			Name:                         template.Name,
+			DisplayName:                  template.DisplayName,
+			Description:                  template.Description,
+			Icon:                         template.Icon,
+			AllowUserCancelWorkspaceJobs: template.AllowUserCancelWorkspaceJobs,
+			DefaultTTLMillis:             time.Hour.Milliseconds(),
+			RestartRequirement: &codersdk.TemplateRestartRequirement{
+				DaysOfWeek: []string{"saturday"},
+				Weeks:      1,
+				Timezone:   "Europe/London",
+			},
+		}
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, req)
+		require.NoError(t, err)
+		require.Equal(t, "Europe/London", updated.RestartRequirement.Timezone)
+
+		template, err = client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Europe/London", template.RestartRequirement.Timezone)
+
+		req.RestartRequirement.Timezone = "Not/AZone"
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, req)
+		require.Error(t, err)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "restart_requirement.timezone", apiErr.Validations[0].Field)
+	})
+
+	t.Run("SetRestartRequirementSpread", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+				},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.Zero(t, template.RestartRequirement.SpreadMillis)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		req := codersdk.UpdateTemplateMeta{
+			Name:                         template.Name,
+			DisplayName:                  template.DisplayName,
+			Description:                  template.Description,
+			Icon:                         template.Icon,
+			AllowUserCancelWorkspaceJobs: template.AllowUserCancelWorkspaceJobs,
+			DefaultTTLMillis:             time.Hour.Milliseconds(),
+			RestartRequirement: &codersdk.TemplateRestartRequirement{
+				DaysOfWeek:   []string{"saturday"},
+				Weeks:        1,
+				SpreadMillis: (2 * time.Hour).Milliseconds(),
+			},
+		}
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, req)
+		require.NoError(t, err)
+		require.Equal(t, (2 * time.Hour).Milliseconds(), updated.RestartRequirement.SpreadMillis)
+
+		// The spread is kept if the restart requirement is omitted.
+		req.RestartRequirement = nil
+		req.DefaultTTLMillis = (2 * time.Hour).Milliseconds()
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, req)
+		require.NoError(t, err)
+		template, err = client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, (2 * time.Hour).Milliseconds(), template.RestartRequirement.SpreadMillis)
+
+		req.RestartRequirement = &codersdk.TemplateRestartRequirement{
+			DaysOfWeek:   []string{"saturday"},
+			Weeks:        1,
+			SpreadMillis: (schedule.MaxTemplateRestartRequirementSpread + time.Hour).Milliseconds(),
+		}
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, req)
+		require.Error(t, err)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "restart_requirement.spread_ms", apiErr.Validations[0].Field)
+	})
+
+	t.Run("SetRestartRequirementUpdateOnRestart", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+				},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		require.False(t, template.RestartRequirement.UpdateOnRestart)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		req := codersdk.UpdateTemplateMeta{
+			Name:                         template.Name,
+			DisplayName:                  template.DisplayName,
+			Description:                  template.Description,
+			Icon:                         template.Icon,
+			AllowUserCancelWorkspaceJobs: template.AllowUserCancelWorkspaceJobs,
+			RestartRequirement: &codersdk.TemplateRestartRequirement{
+				DaysOfWeek:      []string{"saturday"},
+				Weeks:           1,
+				UpdateOnRestart: true,
+			},
+		}
+		updated, err := client.UpdateTemplateMeta(ctx, template.ID, req)
+		require.NoError(t, err)
+		require.True(t, updated.RestartRequirement.UpdateOnRestart)
+
+		// The policy is kept if the restart requirement is omitted.
+		req.RestartRequirement = nil
+		req.DefaultTTLMillis = time.Hour.Milliseconds()
+		_, err = client.UpdateTemplateMeta(ctx, template.ID, req)
+		require.NoError(t, err)
+		template, err = client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.True(t, template.RestartRequirement.UpdateOnRestart)
+	})
+
 	t.Run("CleanupTTLs", func(t *testing.T) {
 		t.Parallel()
 
@@ -1312,3 +1472,267 @@ func TestTemplateAccess(t *testing.T) {
 		}
 	})
 }
+
+func TestTemplateRecalculateDeadlines(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+				},
+			},
+		})
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, memberClient, user.OrganizationID, template.ID)
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		require.False(t, build.MaxDeadline.Valid)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Changing the max TTL doesn't touch existing builds unless the
+		// restart requirement is in use.
+		_, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:                         template.Name,
+			DisplayName:                  template.DisplayName,
+			Description:                  template.Description,
+			Icon:                         template.Icon,
+			AllowUserCancelWorkspaceJobs: template.AllowUserCancelWorkspaceJobs,
+			DefaultTTLMillis:             template.DefaultTTLMillis,
+			MaxTTLMillis:                 (72 * time.Hour).Milliseconds(),
+		})
+		require.NoError(t, err)
+
+		// Members cannot recalculate deadlines.
+		_, err = memberClient.RecalculateTemplateDeadlines(ctx, template.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		job, err := client.RecalculateTemplateDeadlines(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, template.ID, job.TemplateID)
+
+		require.Eventually(t, func() bool {
+			job, err = client.TemplateDeadlineRecalculation(ctx, template.ID, job.ID)
+			return assert.NoError(t, err) && job.CompletedAt != nil
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, codersdk.TemplateDeadlineRecalculationStatusSucceeded, job.Status, job.Error)
+		require.Equal(t, 1, job.TotalBuilds)
+		require.Equal(t, 1, job.ProcessedBuilds)
+
+		jobs, err := client.TemplateDeadlineRecalculations(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, []codersdk.TemplateDeadlineRecalculation{job}, jobs)
+
+		build, err = client.WorkspaceBuild(ctx, build.ID)
+		require.NoError(t, err)
+		require.True(t, build.MaxDeadline.Valid)
+		require.WithinDuration(t, build.Job.CompletedAt.Add(72*time.Hour), build.MaxDeadline.Time, time.Minute)
+	})
+
+	t.Run("TemplateAdmin", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+				},
+			},
+		})
+		templateAdminClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID, rbac.RoleTemplateAdmin())
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		version := coderdtest.CreateTemplateVersion(t, templateAdminClient, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, templateAdminClient, version.ID)
+		template := coderdtest.CreateTemplate(t, templateAdminClient, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, memberClient, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// Template admins can recalculate the deadlines of workspaces they
+		// don't own.
+		job, err := templateAdminClient.RecalculateTemplateDeadlines(ctx, template.ID)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			job, err = templateAdminClient.TemplateDeadlineRecalculation(ctx, template.ID, job.ID)
+			return assert.NoError(t, err) && job.CompletedAt != nil
+		}, testutil.WaitLong, testutil.IntervalFast)
+		require.Equal(t, codersdk.TemplateDeadlineRecalculationStatusSucceeded, job.Status, job.Error)
+		require.Equal(t, 1, job.ProcessedBuilds)
+	})
+
+	t.Run("UnknownJob", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+				},
+			},
+		})
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.TemplateDeadlineRecalculation(ctx, template.ID, uuid.New())
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("NotEntitled", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{},
+			},
+		})
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.RecalculateTemplateDeadlines(ctx, template.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
+
+func TestTemplateScheduleAudit(t *testing.T) {
+	t.Parallel()
+
+	auditor := audit.NewMock()
+	client, user := coderdenttest.New(t, &coderdenttest.Options{
+		AuditLogging: true,
+		Options: &coderdtest.Options{
+			Auditor: auditor,
+		},
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureAdvancedTemplateScheduling: 1,
+				codersdk.FeatureAuditLog:                   1,
+			},
+		},
+	})
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	numLogs := len(auditor.AuditLogs())
+	_, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+		Name:             template.Name,
+		DisplayName:      template.DisplayName,
+		Description:      template.Description,
+		Icon:             template.Icon,
+		DefaultTTLMillis: time.Hour.Milliseconds(),
+		FailureTTLMillis: time.Hour.Milliseconds(),
+	})
+	require.NoError(t, err)
+
+	// The schedule change is audited once, by the request.
+	logs := auditor.AuditLogs()[numLogs:]
+	require.Len(t, logs, 1)
+	require.Equal(t, database.AuditActionWrite, logs[0].Action)
+	require.Equal(t, template.ID, logs[0].ResourceID)
+}
+
+func TestTemplateSchedulePolicy(t *testing.T) {
+	t.Parallel()
+
+	client, user := coderdenttest.New(t, &coderdenttest.Options{
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureAdvancedTemplateScheduling: 1,
+			},
+		},
+	})
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	policyTemplate := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+		ctr.DefaultTTLMillis = ptr.Ref((5 * time.Hour).Milliseconds())
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+
+	policy, err := client.UpdateTemplateSchedulePolicy(ctx, template.ID, codersdk.UpdateTemplateSchedulePolicyRequest{
+		PolicyTemplateID: &policyTemplate.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, &policyTemplate.ID, policy.PolicyTemplateID)
+
+	policy, err = client.TemplateSchedulePolicy(ctx, policyTemplate.ID)
+	require.NoError(t, err)
+	require.Nil(t, policy.PolicyTemplateID)
+	require.Equal(t, []uuid.UUID{template.ID}, policy.InheritedBy)
+
+	// Workspaces of the template use the schedule of the policy template.
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+		cwr.TTLMillis = nil
+	})
+	require.NotNil(t, workspace.TTLMillis)
+	require.Equal(t, (5 * time.Hour).Milliseconds(), *workspace.TTLMillis)
+
+	// The template reports the inherited schedule.
+	template, err = client.Template(ctx, template.ID)
+	require.NoError(t, err)
+	require.Equal(t, (5 * time.Hour).Milliseconds(), template.DefaultTTLMillis)
+
+	// Other settings can be updated while the schedule is inherited.
+	req := codersdk.UpdateTemplateMeta{
+		Name:                         template.Name,
+		DisplayName:                  template.DisplayName,
+		Description:                  "inherits its schedule",
+		Icon:                         template.Icon,
+		AllowUserAutostart:           template.AllowUserAutostart,
+		AllowUserAutostop:            template.AllowUserAutostop,
+		AllowUserCancelWorkspaceJobs: template.AllowUserCancelWorkspaceJobs,
+		DefaultTTLMillis:             template.DefaultTTLMillis,
+		MaxTTLMillis:                 template.MaxTTLMillis,
+		FailureTTLMillis:             template.FailureTTLMillis,
+		InactivityTTLMillis:          template.InactivityTTLMillis,
+		LockedTTLMillis:              template.LockedTTLMillis,
+	}
+	updated, err := client.UpdateTemplateMeta(ctx, template.ID, req)
+	require.NoError(t, err)
+	require.Equal(t, "inherits its schedule", updated.Description)
+	require.Equal(t, (5 * time.Hour).Milliseconds(), updated.DefaultTTLMillis)
+
+	// The schedule itself can't be updated.
+	req.DefaultTTLMillis = time.Hour.Milliseconds()
+	_, err = client.UpdateTemplateMeta(ctx, template.ID, req)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+	// The policy template cannot inherit from its dependents.
+	_, err = client.UpdateTemplateSchedulePolicy(ctx, policyTemplate.ID, codersdk.UpdateTemplateSchedulePolicyRequest{
+		PolicyTemplateID: &template.ID,
+	})
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+	policy, err = client.UpdateTemplateSchedulePolicy(ctx, template.ID, codersdk.UpdateTemplateSchedulePolicyRequest{})
+	require.NoError(t, err)
+	require.Nil(t, policy.PolicyTemplateID)
+}
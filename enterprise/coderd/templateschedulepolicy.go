@@ -0,0 +1,174 @@
+package coderd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	agplschedule "github.com/coder/coder/coderd/schedule"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/schedule"
+)
+
+// @Summary Get template schedule policy
+// @ID get-template-schedule-policy
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param template path string true "Template ID" format(uuid)
+// @Success 200 {object} codersdk.TemplateSchedulePolicy
+// @Router /templates/{template}/schedule-policy [get]
+func (api *API) templateSchedulePolicy(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	policy, ok := api.convertTemplateSchedulePolicy(rw, r, template)
+	if !ok {
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, policy)
+}
+
+// @Summary Update template schedule policy
+// @Description Sets the template that the template inherits its schedule
+// @Description settings from. Workspace build deadlines of the template and of
+// @Description the templates that inherit from it are recalculated.
+// @ID update-template-schedule-policy
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.UpdateTemplateSchedulePolicyRequest true "Update schedule policy request"
+// @Success 200 {object} codersdk.TemplateSchedulePolicy
+// @Router /templates/{template}/schedule-policy [put]
+func (api *API) putTemplateSchedulePolicy(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx         = r.Context()
+		template    = httpmw.TemplateParam(r)
+		auditor     = api.AGPL.Auditor.Load()
+		auditParams = &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		}
+		aReq, commitAudit = audit.InitRequest[database.Template](rw, auditParams)
+	)
+	defer commitAudit()
+	aReq.Old = template
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateTemplateSchedulePolicyRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var policyID uuid.NullUUID
+	if req.PolicyTemplateID != nil {
+		policyID = uuid.NullUUID{UUID: *req.PolicyTemplateID, Valid: true}
+
+		var validErrs []codersdk.ValidationError
+		policy, err := api.Database.GetTemplateByID(ctx, policyID.UUID)
+		switch {
+		case httpapi.Is404Error(err):
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "policy_template_id", Detail: "Template not found."})
+		case err != nil:
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching policy template.",
+				Detail:  err.Error(),
+			})
+			return
+		case policy.ID == template.ID:
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "policy_template_id", Detail: "A template cannot inherit the schedule from itself."})
+		case policy.Deleted:
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "policy_template_id", Detail: "The policy template is deleted."})
+		case policy.OrganizationID != template.OrganizationID:
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "policy_template_id", Detail: "The policy template must be in the same organization."})
+		}
+		if len(validErrs) > 0 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message:     "Invalid request to update template schedule policy!",
+				Validations: validErrs,
+			})
+			return
+		}
+	}
+
+	store, ok := (*api.AGPL.TemplateScheduleStore.Load()).(*schedule.EnterpriseTemplateScheduleStore)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Template schedule store is not configured for advanced template scheduling.",
+		})
+		return
+	}
+
+	template, changes, err := store.SetSchedulePolicy(ctx, api.Database, template, policyID)
+	if errors.Is(err, agplschedule.ErrSchedulePolicyCycle) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid request to update template schedule policy!",
+			Validations: []codersdk.ValidationError{
+				{Field: "policy_template_id", Detail: "The policy template inherits its schedule from this template."},
+			},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template schedule policy.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = template
+	if !changes.Empty() {
+		auditParams.AdditionalFields, err = json.Marshal(changes)
+		if err != nil {
+			api.Logger.Warn(ctx, "marshal template schedule changes", slog.Error(err))
+		}
+	}
+
+	policy, ok := api.convertTemplateSchedulePolicy(rw, r, template)
+	if !ok {
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, policy)
+}
+
+func (api *API) convertTemplateSchedulePolicy(rw http.ResponseWriter, r *http.Request, template database.Template) (codersdk.TemplateSchedulePolicy, bool) {
+	ctx := r.Context()
+
+	dependents, err := api.Database.GetTemplatesBySchedulePolicyTemplateID(ctx, uuid.NullUUID{UUID: template.ID, Valid: true})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching templates that inherit the schedule.",
+			Detail:  err.Error(),
+		})
+		return codersdk.TemplateSchedulePolicy{}, false
+	}
+
+	policy := codersdk.TemplateSchedulePolicy{
+		InheritedBy: make([]uuid.UUID, 0, len(dependents)),
+	}
+	if template.SchedulePolicyTemplateID.Valid {
+		policy.PolicyTemplateID = &template.SchedulePolicyTemplateID.UUID
+	}
+	for _, dependent := range dependents {
+		policy.InheritedBy = append(policy.InheritedBy, dependent.ID)
+	}
+	return policy, true
+}
@@ -1,13 +1,16 @@
 package coderd
 
 import (
+	"fmt"
 	"net/http"
 	"time"
 
-	"github.com/coder/coder/coderd/audit"
+	"github.com/go-chi/chi/v5"
+
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/codersdk"
 )
 
@@ -65,13 +68,7 @@ func (api *API) userQuietHoursSchedule(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, codersdk.UserQuietHoursScheduleResponse{
-		RawSchedule: opts.Schedule.String(),
-		UserSet:     opts.UserSet,
-		Time:        opts.Schedule.Time(),
-		Timezone:    opts.Schedule.Location().String(),
-		Next:        opts.Schedule.Next(time.Now().In(opts.Schedule.Location())),
-	})
+	httpapi.Write(ctx, rw, http.StatusOK, convertUserQuietHoursSchedule(opts))
 }
 
 // @Summary Update user quiet hours schedule
@@ -86,23 +83,16 @@ func (api *API) userQuietHoursSchedule(rw http.ResponseWriter, r *http.Request)
 // @Router /users/{user}/quiet-hours [put]
 func (api *API) putUserQuietHoursSchedule(rw http.ResponseWriter, r *http.Request) {
 	var (
-		ctx               = r.Context()
-		user              = httpmw.UserParam(r)
-		params            codersdk.UpdateUserQuietHoursScheduleRequest
-		aReq, commitAudit = audit.InitRequest[database.User](rw, &audit.RequestParams{
-			Audit:   api.Auditor,
-			Log:     api.Logger,
-			Request: r,
-			Action:  database.AuditActionWrite,
-		})
+		ctx    = r.Context()
+		user   = httpmw.UserParam(r)
+		params codersdk.UpdateUserQuietHoursScheduleRequest
 	)
-	defer commitAudit()
-	aReq.Old = user
 
 	if !httpapi.Read(ctx, rw, r, &params) {
 		return
 	}
 
+	// The quiet hours schedule store audits the change.
 	opts, err := (*api.UserQuietHoursScheduleStore.Load()).Set(ctx, api.Database, user.ID, params.Schedule)
 	if err != nil {
 		// TODO(@dean): some of these errors are related to bad syntax, so it
@@ -111,11 +101,151 @@ func (api *API) putUserQuietHoursSchedule(rw http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, codersdk.UserQuietHoursScheduleResponse{
+	httpapi.Write(ctx, rw, http.StatusOK, convertUserQuietHoursSchedule(opts))
+}
+
+// @Summary Create user quiet hours exception
+// @Description Template restart requirements are skipped on excepted dates.
+// @ID create-user-quiet-hours-exception
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param user path string true "User ID" format(uuid)
+// @Param request body codersdk.CreateUserQuietHoursExceptionRequest true "Create exception request"
+// @Success 201 {object} codersdk.UserQuietHoursScheduleResponse
+// @Router /users/{user}/quiet-hours/exceptions [post]
+func (api *API) postUserQuietHoursException(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		user   = httpmw.UserParam(r)
+		params codersdk.CreateUserQuietHoursExceptionRequest
+		store  = *api.UserQuietHoursScheduleStore.Load()
+	)
+
+	if !httpapi.Read(ctx, rw, r, &params) {
+		return
+	}
+
+	date, ok := parseUserQuietHoursExceptionDate(rw, r, params.Date)
+	if !ok {
+		return
+	}
+
+	opts, err := store.Get(ctx, api.Database, user.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	if opts.Schedule == nil {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	// Dates are evaluated in the timezone of the quiet hours schedule, so
+	// "today" is too.
+	now := time.Now().In(opts.Schedule.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if date.Before(today) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid request to create user quiet hours exception!",
+			Validations: []codersdk.ValidationError{
+				{Field: "date", Detail: "Must not be in the past."},
+			},
+		})
+		return
+	}
+
+	opts, err = store.AddException(ctx, api.Database, user.ID, date)
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("Quiet hours are already skipped on %s.", params.Date),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertUserQuietHoursSchedule(opts))
+}
+
+// @Summary Delete user quiet hours exception
+// @ID delete-user-quiet-hours-exception
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param user path string true "User ID" format(uuid)
+// @Param date path string true "Exception date" format(date)
+// @Success 200 {object} codersdk.UserQuietHoursScheduleResponse
+// @Router /users/{user}/quiet-hours/exceptions/{date} [delete]
+func (api *API) deleteUserQuietHoursException(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		user  = httpmw.UserParam(r)
+		store = *api.UserQuietHoursScheduleStore.Load()
+	)
+
+	date, ok := parseUserQuietHoursExceptionDate(rw, r, chi.URLParam(r, "date"))
+	if !ok {
+		return
+	}
+
+	opts, err := store.Get(ctx, api.Database, user.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	found := false
+	for _, exception := range opts.Exceptions {
+		if exception.Equal(date) {
+			found = true
+			break
+		}
+	}
+	if opts.Schedule == nil || !found {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	opts, err = store.DeleteException(ctx, api.Database, user.ID, date)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertUserQuietHoursSchedule(opts))
+}
+
+// parseUserQuietHoursExceptionDate parses the date of a quiet hours exception.
+// If the date is invalid, a 400 is written to the response.
+func parseUserQuietHoursExceptionDate(rw http.ResponseWriter, r *http.Request, raw string) (time.Time, bool) {
+	date, err := time.Parse(codersdk.UserQuietHoursExceptionDateLayout, raw)
+	if err != nil {
+		httpapi.Write(r.Context(), rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid user quiet hours exception date!",
+			Validations: []codersdk.ValidationError{
+				{Field: "date", Detail: "Must be a date in the format YYYY-MM-DD."},
+			},
+		})
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+func convertUserQuietHoursSchedule(opts schedule.UserQuietHoursScheduleOptions) codersdk.UserQuietHoursScheduleResponse {
+	exceptions := make([]string, 0, len(opts.Exceptions))
+	for _, exception := range opts.Exceptions {
+		exceptions = append(exceptions, exception.Format(codersdk.UserQuietHoursExceptionDateLayout))
+	}
+
+	return codersdk.UserQuietHoursScheduleResponse{
 		RawSchedule: opts.Schedule.String(),
 		UserSet:     opts.UserSet,
 		Time:        opts.Schedule.Time(),
 		Timezone:    opts.Schedule.Location().String(),
 		Next:        opts.Schedule.Next(time.Now().In(opts.Schedule.Location())),
-	})
+		Exceptions:  exceptions,
+	}
 }
@@ -128,6 +128,83 @@ func TestUserQuietHours(t *testing.T) {
 		// about it in this test.
 	})
 
+	t.Run("Exceptions", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.UserQuietHoursSchedule.DefaultSchedule.Set("CRON_TZ=America/Chicago 0 0 * * *")
+		dv.Experiments.Set(string(codersdk.ExperimentTemplateRestartRequirement))
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues: dv,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+					codersdk.FeatureTemplateRestartRequirement: 1,
+				},
+			},
+		})
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		sched, err := client.UserQuietHoursSchedule(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Empty(t, sched.Exceptions)
+
+		// Use dates far enough away that they can't be in the past in the
+		// schedule's timezone.
+		now := time.Now().UTC()
+		nextWeek := now.AddDate(0, 0, 7).Format(codersdk.UserQuietHoursExceptionDateLayout)
+		inTwoDays := now.AddDate(0, 0, 2).Format(codersdk.UserQuietHoursExceptionDateLayout)
+
+		sched, err = client.CreateUserQuietHoursException(ctx, codersdk.Me, codersdk.CreateUserQuietHoursExceptionRequest{
+			Date: nextWeek,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{nextWeek}, sched.Exceptions)
+
+		// Exceptions are ordered by date.
+		sched, err = client.CreateUserQuietHoursException(ctx, user.UserID.String(), codersdk.CreateUserQuietHoursExceptionRequest{
+			Date: inTwoDays,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{inTwoDays, nextWeek}, sched.Exceptions)
+
+		sched, err = client.UserQuietHoursSchedule(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, []string{inTwoDays, nextWeek}, sched.Exceptions)
+
+		var sdkErr *codersdk.Error
+		_, err = client.CreateUserQuietHoursException(ctx, codersdk.Me, codersdk.CreateUserQuietHoursExceptionRequest{
+			Date: nextWeek,
+		})
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusConflict, sdkErr.StatusCode())
+
+		_, err = client.CreateUserQuietHoursException(ctx, codersdk.Me, codersdk.CreateUserQuietHoursExceptionRequest{
+			Date: now.AddDate(0, 0, -2).Format(codersdk.UserQuietHoursExceptionDateLayout),
+		})
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode())
+		require.Len(t, sdkErr.Validations, 1)
+		require.Equal(t, "date", sdkErr.Validations[0].Field)
+
+		_, err = client.CreateUserQuietHoursException(ctx, codersdk.Me, codersdk.CreateUserQuietHoursExceptionRequest{
+			Date: "next friday",
+		})
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusBadRequest, sdkErr.StatusCode())
+
+		sched, err = client.DeleteUserQuietHoursException(ctx, codersdk.Me, inTwoDays)
+		require.NoError(t, err)
+		require.Equal(t, []string{nextWeek}, sched.Exceptions)
+
+		_, err = client.DeleteUserQuietHoursException(ctx, codersdk.Me, inTwoDays)
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+	})
+
 	t.Run("NotEntitled", func(t *testing.T) {
 		t.Parallel()
 
@@ -0,0 +1,230 @@
+package coderd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get workspace ACL
+// @ID get-workspace-acl
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Success 200 {object} codersdk.WorkspaceACL
+// @Router /workspaces/{workspace}/acl [get]
+func (api *API) workspaceACL(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		workspace = httpmw.WorkspaceParam(r)
+	)
+
+	groups := make([]codersdk.WorkspaceGroup, 0, len(workspace.GroupACL))
+	for id, actions := range workspace.GroupACL {
+		groupID, err := uuid.Parse(id)
+		if err != nil {
+			httpapi.InternalServerError(rw, xerrors.Errorf("parse group ID %q: %w", id, err))
+			return
+		}
+
+		// Like for template ACLs, the caller might not have permission to
+		// read the group, but they can read the ACL of the workspace.
+		// nolint:gocritic
+		group, err := api.Database.GetGroupByID(dbauthz.AsSystemRestricted(ctx), groupID)
+		if httpapi.Is404Error(err) {
+			// The group was deleted, so nobody has access through it.
+			continue
+		}
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		// nolint:gocritic
+		members, err := api.Database.GetGroupMembers(dbauthz.AsSystemRestricted(ctx), group.ID)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+
+		groups = append(groups, codersdk.WorkspaceGroup{
+			Group: convertGroup(group, members),
+			Role:  convertToWorkspaceRole(actions),
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.WorkspaceACL{
+		Groups: groups,
+	})
+}
+
+// @Summary Update workspace ACL
+// @ID update-workspace-acl
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param request body codersdk.UpdateWorkspaceACL true "Update workspace ACL request"
+// @Success 200 {object} codersdk.Response
+// @Router /workspaces/{workspace}/acl [patch]
+func (api *API) patchWorkspaceACL(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		workspace         = httpmw.WorkspaceParam(r)
+		auditor           = api.AGPL.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = workspace
+
+	// Members of the groups the workspace is shared with can read it, but
+	// only those that can update the workspace may share it.
+	if !api.Authorize(r, rbac.ActionUpdate, workspace) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.UpdateWorkspaceACL
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	validErrs := validateWorkspaceACLPerms(ctx, api.Database, workspace.OrganizationID, req.GroupPerms)
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid request to update workspace ACL!",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	err := api.Database.InTx(func(tx database.Store) error {
+		var err error
+		workspace, err = tx.GetWorkspaceByID(ctx, workspace.ID)
+		if err != nil {
+			return xerrors.Errorf("get workspace by ID: %w", err)
+		}
+
+		acl := make(database.WorkspaceACL, len(workspace.GroupACL))
+		for id, actions := range workspace.GroupACL {
+			acl[id] = actions
+		}
+		for id, role := range req.GroupPerms {
+			// The IDs were validated, and the ACL must use the same format
+			// as the group IDs of the subject.
+			groupID := uuid.MustParse(id).String()
+			// An id with an empty string implies deletion.
+			if role == codersdk.WorkspaceRoleDeleted {
+				delete(acl, groupID)
+				continue
+			}
+			acl[groupID] = convertSDKWorkspaceRole(role)
+		}
+
+		err = tx.UpdateWorkspaceGroupACLByID(ctx, database.UpdateWorkspaceGroupACLByIDParams{
+			ID:       workspace.ID,
+			GroupACL: acl,
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspace group ACL by ID: %w", err)
+		}
+		workspace, err = tx.GetWorkspaceByID(ctx, workspace.ID)
+		if err != nil {
+			return xerrors.Errorf("get updated workspace by ID: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	aReq.New = workspace
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Successfully updated workspace ACL list.",
+	})
+}
+
+// validateWorkspaceACLPerms checks that the roles are valid and that the
+// groups exist in the organization of the workspace. Groups can always be
+// removed, so stale entries of deleted groups can be cleaned up.
+func validateWorkspaceACLPerms(ctx context.Context, db database.Store, orgID uuid.UUID, perms map[string]codersdk.WorkspaceRole) []codersdk.ValidationError {
+	// Validate requires full read access to groups.
+	// nolint:gocritic
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	var validErrs []codersdk.ValidationError
+	for k, v := range perms {
+		if convertSDKWorkspaceRole(v) == nil && v != codersdk.WorkspaceRoleDeleted {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "group_perms", Detail: fmt.Sprintf("role %q is not a valid workspace role", v)})
+			continue
+		}
+
+		id, err := uuid.Parse(k)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "group_perms", Detail: "ID " + k + " must be a valid UUID."})
+			continue
+		}
+		if v == codersdk.WorkspaceRoleDeleted {
+			continue
+		}
+
+		group, err := db.GetGroupByID(ctx, id)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "group_perms", Detail: fmt.Sprintf("Failed to find group with ID %q: %v", k, err.Error())})
+			continue
+		}
+		if group.OrganizationID != orgID {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "group_perms", Detail: fmt.Sprintf("Group %q is not in the organization of the workspace.", group.Name)})
+		}
+	}
+
+	return validErrs
+}
+
+func convertToWorkspaceRole(actions []rbac.Action) codersdk.WorkspaceRole {
+	switch {
+	case slices.Contains(actions, rbac.ActionCreate):
+		return codersdk.WorkspaceRoleFull
+	case slices.Contains(actions, rbac.ActionRead):
+		return codersdk.WorkspaceRoleRead
+	}
+
+	return ""
+}
+
+// convertSDKWorkspaceRole returns the actions stored in the group ACL of the
+// workspace for the role. See database.Workspace.RBACObject for how they
+// apply.
+func convertSDKWorkspaceRole(role codersdk.WorkspaceRole) []rbac.Action {
+	switch role {
+	case codersdk.WorkspaceRoleFull:
+		return []rbac.Action{rbac.ActionRead, rbac.ActionCreate}
+	case codersdk.WorkspaceRoleRead:
+		return []rbac.Action{rbac.ActionRead}
+	}
+
+	return nil
+}
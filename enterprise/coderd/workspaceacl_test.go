@@ -0,0 +1,151 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceACL(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (*codersdk.Client, codersdk.CreateFirstUserResponse, codersdk.Workspace) {
+		t.Helper()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureTemplateRBAC: 1,
+				},
+			},
+		})
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		return client, user, workspace
+	}
+
+	t.Run("Share", func(t *testing.T) {
+		t.Parallel()
+
+		client, user, workspace := setup(t)
+		member, memberUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		group, err := client.CreateGroup(ctx, user.OrganizationID, codersdk.CreateGroupRequest{
+			Name: "shared",
+		})
+		require.NoError(t, err)
+		_, err = client.PatchGroup(ctx, group.ID, codersdk.PatchGroupRequest{
+			AddUsers: []string{memberUser.ID.String()},
+		})
+		require.NoError(t, err)
+
+		_, err = member.Workspace(ctx, workspace.ID)
+		require.Error(t, err, "workspace is not shared yet")
+
+		checks := codersdk.AuthorizationRequest{
+			Checks: map[string]codersdk.AuthorizationCheck{
+				"apps": {
+					Object: codersdk.AuthorizationObject{ResourceType: codersdk.ResourceWorkspaceApplicationConnect, ResourceID: workspace.ID.String()},
+					Action: codersdk.ActionCreate,
+				},
+				"ssh": {
+					Object: codersdk.AuthorizationObject{ResourceType: codersdk.ResourceWorkspaceExecution, ResourceID: workspace.ID.String()},
+					Action: codersdk.ActionCreate,
+				},
+			},
+		}
+
+		err = client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			GroupPerms: map[string]codersdk.WorkspaceRole{
+				group.ID.String(): codersdk.WorkspaceRoleRead,
+			},
+		})
+		require.NoError(t, err)
+
+		acl, err := client.WorkspaceACL(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Len(t, acl.Groups, 1)
+		require.Equal(t, group.ID, acl.Groups[0].ID)
+		require.Equal(t, codersdk.WorkspaceRoleRead, acl.Groups[0].Role)
+
+		_, err = member.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		workspaces, err := member.Workspaces(ctx, codersdk.WorkspaceFilter{})
+		require.NoError(t, err)
+		require.Len(t, workspaces.Workspaces, 1)
+		require.Equal(t, workspace.ID, workspaces.Workspaces[0].ID)
+
+		res, err := member.AuthCheck(ctx, checks)
+		require.NoError(t, err)
+		require.True(t, res["apps"])
+		require.False(t, res["ssh"])
+
+		err = client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			GroupPerms: map[string]codersdk.WorkspaceRole{
+				group.ID.String(): codersdk.WorkspaceRoleFull,
+			},
+		})
+		require.NoError(t, err)
+		res, err = member.AuthCheck(ctx, checks)
+		require.NoError(t, err)
+		require.True(t, res["apps"])
+		require.True(t, res["ssh"])
+
+		// Members of the group can't share the workspace further.
+		err = member.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			GroupPerms: map[string]codersdk.WorkspaceRole{
+				user.OrganizationID.String(): codersdk.WorkspaceRoleFull,
+			},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		err = client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			GroupPerms: map[string]codersdk.WorkspaceRole{
+				group.ID.String(): codersdk.WorkspaceRoleDeleted,
+			},
+		})
+		require.NoError(t, err)
+		acl, err = client.WorkspaceACL(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Empty(t, acl.Groups)
+		_, err = member.Workspace(ctx, workspace.ID)
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		client, user, workspace := setup(t)
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		for _, perms := range []map[string]codersdk.WorkspaceRole{
+			{uuid.NewString(): codersdk.WorkspaceRoleRead},
+			{"not-a-uuid": codersdk.WorkspaceRoleRead},
+			{user.OrganizationID.String(): "admin"},
+		} {
+			err := client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+				GroupPerms: perms,
+			})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		}
+	})
+}
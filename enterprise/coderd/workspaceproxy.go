@@ -32,6 +32,16 @@ import (
 	"github.com/coder/coder/enterprise/wsproxy/wsproxysdk"
 )
 
+const (
+	// workspaceProxyTokenLifetime is how long tokens issued by rotation are
+	// valid.
+	workspaceProxyTokenLifetime = 7 * 24 * time.Hour
+	// workspaceProxyTokenOverlap is how long a token stays valid after it was
+	// replaced by a rotation. Replicas of the proxy that share the token
+	// switch to the new one in the meantime.
+	workspaceProxyTokenOverlap = time.Hour
+)
+
 // forceWorkspaceProxyHealthUpdate forces an update of the proxy health.
 // This is useful when a proxy is created or deleted. Errors will be logged.
 func (api *API) forceWorkspaceProxyHealthUpdate(ctx context.Context) {
@@ -324,7 +334,7 @@ func (api *API) postWorkspaceProxy(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if strings.ToLower(req.Name) == "primary" {
+	if strings.ToLower(req.Name) == codersdk.PrimaryRegionName {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message: `The name "primary" is reserved for the primary region.`,
 			Detail:  "Cannot name a workspace proxy 'primary'.",
@@ -515,13 +525,24 @@ func (api *API) workspaceProxyReportAppStats(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
-	api.Logger.Debug(ctx, "report app stats", slog.F("stats", req.Stats))
+	api.Logger.Debug(ctx, "report app stats", slog.F("stats", req.Stats), slog.F("access_logs", len(req.AccessLogs)))
 
-	reporter := api.WorkspaceAppsStatsCollectorOptions.Reporter
-	if err := reporter.Report(ctx, req.Stats); err != nil {
-		api.Logger.Error(ctx, "report app stats failed", slog.Error(err))
-		httpapi.InternalServerError(rw, err)
-		return
+	if len(req.Stats) > 0 {
+		reporter := api.WorkspaceAppsStatsCollectorOptions.Reporter
+		if err := reporter.Report(ctx, req.Stats); err != nil {
+			api.Logger.Error(ctx, "report app stats failed", slog.Error(err))
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+	}
+	// Access logs are dropped if they were disabled after the proxy
+	// registered.
+	if accessLogReporter := api.WorkspaceAppsStatsCollectorOptions.AccessLogReporter; len(req.AccessLogs) > 0 && accessLogReporter != nil {
+		if err := accessLogReporter.ReportAccessLogs(ctx, req.AccessLogs); err != nil {
+			api.Logger.Error(ctx, "report app access logs failed", slog.Error(err))
+			httpapi.InternalServerError(rw, err)
+			return
+		}
 	}
 
 	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
@@ -613,7 +634,7 @@ func (api *API) workspaceProxyRegister(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	startingRegionID, _ := getProxyDERPStartingRegionID(api.Options.BaseDERPMap)
+	startingRegionID, _ := getProxyDERPStartingRegionID(api.AGPL.BaseDERPMap())
 	regionID := int32(startingRegionID) + proxy.RegionID
 
 	err := api.Database.InTx(func(db database.Store) error {
@@ -715,12 +736,19 @@ func (api *API) workspaceProxyRegister(rw http.ResponseWriter, r *http.Request)
 		siblingsRes = append(siblingsRes, convertReplica(replica))
 	}
 
+	var tokenExpiresAt *time.Time
+	if expiry, ok := httpmw.WorkspaceProxyTokenExpiry(r); ok {
+		tokenExpiresAt = &expiry
+	}
+
 	// aReq.New = updatedProxy
 	httpapi.Write(ctx, rw, http.StatusCreated, wsproxysdk.RegisterWorkspaceProxyResponse{
 		AppSecurityKey:  api.AppSecurityKey.String(),
 		DERPMeshKey:     api.DERPServer.MeshKey(),
 		DERPRegionID:    regionID,
 		SiblingReplicas: siblingsRes,
+		TokenExpiresAt:  tokenExpiresAt,
+		AppAccessLogs:   api.WorkspaceAppsStatsCollectorOptions.AccessLogReporter != nil,
 	})
 
 	go api.forceWorkspaceProxyHealthUpdate(api.ctx)
@@ -798,6 +826,161 @@ func (api *API) workspaceProxyDeregister(rw http.ResponseWriter, r *http.Request
 	go api.forceWorkspaceProxyHealthUpdate(api.ctx)
 }
 
+// workspaceProxyRotateToken replaces the token of the proxy with a new one
+// that expires after workspaceProxyTokenLifetime. Proxies call this before
+// their token expires, so their token never has to be regenerated manually.
+//
+// @Summary Rotate workspace proxy token
+// @ID rotate-workspace-proxy-token
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Success 201 {object} wsproxysdk.RotateWorkspaceProxyTokenResponse
+// @Router /workspaceproxies/me/rotate-token [post]
+// @x-apidocgen {"skip": true}
+func (api *API) workspaceProxyRotateToken(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		proxy = httpmw.WorkspaceProxy(r)
+	)
+
+	// The middleware already validated the token.
+	_, secret, _ := strings.Cut(r.Header.Get(httpmw.WorkspaceProxyAuthTokenHeader), ":")
+	currentHashedSecret := sha256.Sum256([]byte(secret))
+
+	fullToken, hashedSecret, err := generateWorkspaceProxyToken(proxy.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	now := database.Now()
+	updatedProxy, err := api.Database.RotateWorkspaceProxyToken(ctx, database.RotateWorkspaceProxyTokenParams{
+		ID:                       proxy.ID,
+		CurrentTokenHashedSecret: currentHashedSecret[:],
+		TokenHashedSecret:        hashedSecret,
+		TokenExpiresAt:           now.Add(workspaceProxyTokenLifetime),
+		PreviousTokenExpiresAt:   now.Add(workspaceProxyTokenOverlap),
+	})
+	if httpapi.Is404Error(err) {
+		// Another replica of the proxy rotated the token first, or the
+		// request was made with the token replaced by the last rotation.
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "Workspace proxy token was already rotated.",
+			Detail:  "Only the current token of a workspace proxy can be rotated.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, wsproxysdk.RotateWorkspaceProxyTokenResponse{
+		ProxyToken: fullToken,
+		ExpiresAt:  updatedProxy.TokenExpiresAt.Time,
+	})
+}
+
+// workspaceProxyACMECertificate returns the certificate of the wildcard
+// hostname of the proxy, issued over ACME by one of its replicas. The
+// certificate is encrypted by the proxy, so the primary can't read it.
+//
+// @Summary Get workspace proxy ACME certificate
+// @ID get-workspace-proxy-acme-certificate
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param domain query string true "Wildcard hostname of the proxy"
+// @Success 200 {object} wsproxysdk.ACMECertificate
+// @Router /workspaceproxies/me/acme-certificate [get]
+// @x-apidocgen {"skip": true}
+func (api *API) workspaceProxyACMECertificate(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx    = r.Context()
+		proxy  = httpmw.WorkspaceProxy(r)
+		domain = r.URL.Query().Get("domain")
+	)
+
+	if !validateWorkspaceProxyACMEDomain(ctx, rw, proxy, domain) {
+		return
+	}
+
+	certificate, err := api.Database.GetACMECertificate(ctx, database.GetACMECertificateParams{
+		WorkspaceProxyID: proxy.ID,
+		Domain:           domain,
+	})
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, wsproxysdk.ACMECertificate{
+		Domain:   certificate.Domain,
+		Data:     certificate.Data,
+		NotAfter: certificate.NotAfter,
+	})
+}
+
+// workspaceProxyUpdateACMECertificate stores the certificate of the wildcard
+// hostname of the proxy, so all replicas of the proxy can serve it. It's stored
+// for the proxy only, so it never replaces the certificate of the primary or
+// of another proxy.
+//
+// @Summary Update workspace proxy ACME certificate
+// @ID update-workspace-proxy-acme-certificate
+// @Security CoderSessionToken
+// @Accept json
+// @Tags Enterprise
+// @Param request body wsproxysdk.ACMECertificate true "ACME certificate"
+// @Success 204
+// @Router /workspaceproxies/me/acme-certificate [put]
+// @x-apidocgen {"skip": true}
+func (api *API) workspaceProxyUpdateACMECertificate(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		proxy = httpmw.WorkspaceProxy(r)
+	)
+
+	var req wsproxysdk.ACMECertificate
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if !validateWorkspaceProxyACMEDomain(ctx, rw, proxy, req.Domain) {
+		return
+	}
+
+	err := api.Database.UpsertACMECertificate(ctx, database.UpsertACMECertificateParams{
+		WorkspaceProxyID: proxy.ID,
+		Domain:           req.Domain,
+		Data:             req.Data,
+		NotAfter:         req.NotAfter,
+		UpdatedAt:        database.Now(),
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// validateWorkspaceProxyACMEDomain ensures proxies can only access the
+// certificate of their own wildcard hostname.
+func validateWorkspaceProxyACMEDomain(ctx context.Context, rw http.ResponseWriter, proxy database.WorkspaceProxy, domain string) bool {
+	if proxy.WildcardHostname == "" || !strings.EqualFold(domain, proxy.WildcardHostname) {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: "Workspace proxies can only access the certificate of their own wildcard hostname.",
+			Detail:  fmt.Sprintf("Requested %q, but the proxy registered with wildcard hostname %q.", domain, proxy.WildcardHostname),
+		})
+		return false
+	}
+	return true
+}
+
 // reconnectingPTYSignedToken issues a signed app token for use when connecting
 // to the reconnecting PTY websocket on an external workspace proxy. This is set
 // by the client as a query parameter when connecting.
@@ -18,6 +18,7 @@ import (
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/agent"
 	"github.com/coder/coder/buildinfo"
+	"github.com/coder/coder/coderd/acmecert"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbtestutil"
@@ -640,6 +641,175 @@ func TestProxyRegisterDeregister(t *testing.T) {
 	})
 }
 
+func TestWorkspaceProxyRotateToken(t *testing.T) {
+	t.Parallel()
+
+	dv := coderdtest.DeploymentValues(t)
+	dv.Experiments = []string{
+		string(codersdk.ExperimentMoons),
+		"*",
+	}
+	client, _ := coderdenttest.New(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			DeploymentValues: dv,
+		},
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureWorkspaceProxy: 1,
+			},
+		},
+	})
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	createRes, err := client.CreateWorkspaceProxy(ctx, codersdk.CreateWorkspaceProxyRequest{
+		Name:        "rotate",
+		DisplayName: "Rotate",
+		Icon:        "/emojis/flag.png",
+	})
+	require.NoError(t, err)
+
+	register := func(token string) (wsproxysdk.RegisterWorkspaceProxyResponse, error) {
+		proxyClient := wsproxysdk.New(client.URL)
+		_ = proxyClient.SetSessionToken(token)
+		return proxyClient.RegisterWorkspaceProxy(ctx, wsproxysdk.RegisterWorkspaceProxyRequest{
+			AccessURL:           "https://proxy.coder.test",
+			WildcardHostname:    "*.proxy.coder.test",
+			DerpEnabled:         true,
+			ReplicaID:           uuid.New(),
+			ReplicaHostname:     "venus",
+			ReplicaRelayAddress: "http://127.0.0.1:8080",
+			Version:             buildinfo.Version(),
+		})
+	}
+
+	// Tokens created by users don't expire.
+	registerRes, err := register(createRes.ProxyToken)
+	require.NoError(t, err)
+	require.Nil(t, registerRes.TokenExpiresAt)
+
+	proxyClient := wsproxysdk.New(client.URL)
+	_ = proxyClient.SetSessionToken(createRes.ProxyToken)
+	rotateRes, err := proxyClient.RotateWorkspaceProxyToken(ctx)
+	require.NoError(t, err)
+	require.NotEqual(t, createRes.ProxyToken, rotateRes.ProxyToken)
+	require.True(t, rotateRes.ExpiresAt.After(time.Now()))
+
+	registerRes, err = register(rotateRes.ProxyToken)
+	require.NoError(t, err)
+	require.NotNil(t, registerRes.TokenExpiresAt)
+	require.WithinDuration(t, rotateRes.ExpiresAt, *registerRes.TokenExpiresAt, time.Second)
+
+	// The replaced token is still accepted for a while, but expires before
+	// the new one.
+	registerRes, err = register(createRes.ProxyToken)
+	require.NoError(t, err)
+	require.NotNil(t, registerRes.TokenExpiresAt)
+	require.True(t, registerRes.TokenExpiresAt.Before(rotateRes.ExpiresAt))
+
+	// It can't be rotated again.
+	_, err = proxyClient.RotateWorkspaceProxyToken(ctx)
+	var sdkErr *codersdk.Error
+	require.ErrorAs(t, err, &sdkErr)
+	require.Equal(t, http.StatusConflict, sdkErr.StatusCode())
+
+	// Regenerating the token invalidates both tokens.
+	_, err = client.PatchWorkspaceProxy(ctx, codersdk.PatchWorkspaceProxy{
+		ID:              createRes.Proxy.ID,
+		Name:            createRes.Proxy.Name,
+		DisplayName:     createRes.Proxy.DisplayName,
+		Icon:            createRes.Proxy.IconURL,
+		RegenerateToken: true,
+	})
+	require.NoError(t, err)
+	for _, token := range []string{createRes.ProxyToken, rotateRes.ProxyToken} {
+		_, err = register(token)
+		require.ErrorAs(t, err, &sdkErr)
+		require.Equal(t, http.StatusUnauthorized, sdkErr.StatusCode())
+	}
+}
+
+func TestWorkspaceProxyACMECertificate(t *testing.T) {
+	t.Parallel()
+
+	dv := coderdtest.DeploymentValues(t)
+	dv.Experiments = []string{
+		string(codersdk.ExperimentMoons),
+		"*",
+	}
+	client, closer, api, _ := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			DeploymentValues: dv,
+		},
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureWorkspaceProxy: 1,
+			},
+		},
+	})
+	t.Cleanup(func() {
+		_ = closer.Close()
+	})
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	// The primary serves the same domain as the proxy.
+	primaryStore := acmecert.NewDatabaseStore(api.Database)
+	err := primaryStore.Save(ctx, "*.proxy.coder.test", []byte("primary"), time.Now().Add(90*24*time.Hour))
+	require.NoError(t, err)
+
+	createRes, err := client.CreateWorkspaceProxy(ctx, codersdk.CreateWorkspaceProxyRequest{
+		Name:        "acme",
+		DisplayName: "ACME",
+		Icon:        "/emojis/flag.png",
+	})
+	require.NoError(t, err)
+
+	proxyClient := wsproxysdk.New(client.URL)
+	_ = proxyClient.SetSessionToken(createRes.ProxyToken)
+	_, err = proxyClient.RegisterWorkspaceProxy(ctx, wsproxysdk.RegisterWorkspaceProxyRequest{
+		AccessURL:           "https://proxy.coder.test",
+		WildcardHostname:    "*.proxy.coder.test",
+		DerpEnabled:         true,
+		ReplicaID:           uuid.New(),
+		ReplicaHostname:     "venus",
+		ReplicaRelayAddress: "http://127.0.0.1:8080",
+		Version:             buildinfo.Version(),
+	})
+	require.NoError(t, err)
+
+	var sdkErr *codersdk.Error
+	_, err = proxyClient.ACMECertificate(ctx, "*.proxy.coder.test")
+	require.ErrorAs(t, err, &sdkErr)
+	require.Equal(t, http.StatusNotFound, sdkErr.StatusCode())
+
+	certificate := wsproxysdk.ACMECertificate{
+		Domain:   "*.proxy.coder.test",
+		Data:     []byte("encrypted"),
+		NotAfter: time.Now().Add(90 * 24 * time.Hour).UTC().Truncate(time.Second),
+	}
+	err = proxyClient.UpdateACMECertificate(ctx, certificate)
+	require.NoError(t, err)
+
+	got, err := proxyClient.ACMECertificate(ctx, "*.proxy.coder.test")
+	require.NoError(t, err)
+	require.Equal(t, certificate.Data, got.Data)
+	require.WithinDuration(t, certificate.NotAfter, got.NotAfter, time.Second)
+
+	// The certificate of the primary isn't replaced.
+	data, err := primaryStore.Load(ctx, "*.proxy.coder.test")
+	require.NoError(t, err)
+	require.Equal(t, []byte("primary"), data)
+
+	// Proxies can't access the certificates of other domains, like the
+	// wildcard access URL of the primary.
+	certificate.Domain = "*.apps.coder.test"
+	err = proxyClient.UpdateACMECertificate(ctx, certificate)
+	require.ErrorAs(t, err, &sdkErr)
+	require.Equal(t, http.StatusForbidden, sdkErr.StatusCode())
+	_, err = proxyClient.ACMECertificate(ctx, "*.apps.coder.test")
+	require.ErrorAs(t, err, &sdkErr)
+	require.Equal(t, http.StatusForbidden, sdkErr.StatusCode())
+}
+
 func TestIssueSignedAppToken(t *testing.T) {
 	t.Parallel()
 
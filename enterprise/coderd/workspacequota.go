@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"net/http"
+	"sync/atomic"
 
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
@@ -14,10 +15,14 @@ import (
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisionerd/proto"
+	"github.com/coder/coder/provisionersdk"
 )
 
 type committer struct {
 	Database database.Store
+	// ResourceQuotasEnabled is set by the license. Resource quotas of
+	// templates are only enforced while it's true.
+	ResourceQuotasEnabled *atomic.Bool
 }
 
 func (c *committer) CommitQuota(
@@ -39,9 +44,11 @@ func (c *committer) CommitQuota(
 	}
 
 	var (
-		consumed int64
-		budget   int64
-		permit   bool
+		consumed      int64
+		budget        int64
+		usage         provisionersdk.ResourceUsage
+		resourceQuota provisionersdk.ResourceUsage
+		permit        bool
 	)
 	err = c.Database.InTx(func(s database.Store) error {
 		var err error
@@ -69,12 +76,33 @@ func (c *committer) CommitQuota(
 		} else if !xerrors.Is(err, sql.ErrNoRows) {
 			return err
 		}
+		if request.DailyCost <= build.DailyCost {
+			// Lowering the cost that was committed for the build.
+			netIncrease = false
+		}
+
+		// The build is committed again with the cost of the applied
+		// resources once it completes, which replaces the planned cost.
+		newConsumed := consumed - int64(build.DailyCost) + int64(request.DailyCost)
 
-		newConsumed := int64(request.DailyCost) + consumed
-		if newConsumed > budget && netIncrease {
+		// Builds without a cost are only committed for their resource usage.
+		if request.DailyCost > 0 && newConsumed > budget && netIncrease {
 			return nil
 		}
 
+		// Stopping or deleting a workspace never increases the resource
+		// usage, so resource quotas are only enforced when starting.
+		if build.Transition == database.WorkspaceTransitionStart && c.ResourceQuotasEnabled.Load() {
+			var exceeded bool
+			usage, resourceQuota, exceeded, err = evaluateResourceQuota(ctx, s, workspace, request)
+			if err != nil {
+				return err
+			}
+			if exceeded {
+				return nil
+			}
+		}
+
 		err = s.UpdateWorkspaceBuildCostByID(ctx, database.UpdateWorkspaceBuildCostByIDParams{
 			ID:        build.ID,
 			DailyCost: request.DailyCost,
@@ -93,12 +121,62 @@ func (c *committer) CommitQuota(
 	}
 
 	return &proto.CommitQuotaResponse{
-		Ok:              permit,
-		CreditsConsumed: int32(consumed),
-		Budget:          int32(budget),
+		Ok:                    permit,
+		CreditsConsumed:       int32(consumed),
+		Budget:                int32(budget),
+		CpuMillicoresConsumed: usage.CPUMillicores,
+		CpuMillicoresQuota:    resourceQuota.CPUMillicores,
+		MemoryBytesConsumed:   usage.MemoryBytes,
+		MemoryBytesQuota:      resourceQuota.MemoryBytes,
+		DiskBytesConsumed:     usage.DiskBytes,
+		DiskBytesQuota:        resourceQuota.DiskBytes,
 	}, nil
 }
 
+// evaluateResourceQuota sums the resource usage of the owner's workspaces of
+// the template and the usage planned by the build, and compares it to the
+// per-user resource quotas of the template.
+func evaluateResourceQuota(ctx context.Context, db database.Store, workspace database.Workspace, request *proto.CommitQuotaRequest) (usage provisionersdk.ResourceUsage, quota provisionersdk.ResourceUsage, exceeded bool, err error) {
+	template, err := db.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		return usage, quota, false, xerrors.Errorf("get template: %w", err)
+	}
+	quota = provisionersdk.ResourceUsage{
+		CPUMillicores: template.UserCPUQuotaMillicores,
+		MemoryBytes:   template.UserMemoryQuotaBytes,
+		DiskBytes:     template.UserDiskQuotaBytes,
+	}
+	if quota.IsZero() {
+		return usage, quota, false, nil
+	}
+
+	// The latest build of the workspace is the build being committed, which
+	// has no resources yet, so the planned usage replaces the usage of the
+	// previous build. Once the build completes it's committed again without
+	// planned usage, and its resources are counted instead.
+	metadata, err := db.GetQuotaResourceMetadataForUser(ctx, database.GetQuotaResourceMetadataForUserParams{
+		OwnerID:    workspace.OwnerID,
+		TemplateID: workspace.TemplateID,
+	})
+	if err != nil {
+		return usage, quota, false, xerrors.Errorf("get resource metadata: %w", err)
+	}
+	usage = provisionersdk.ResourceUsage{
+		CPUMillicores: request.CpuMillicores,
+		MemoryBytes:   request.MemoryBytes,
+		DiskBytes:     request.DiskBytes,
+	}
+	for _, m := range metadata {
+		// Invalid values were reported when the resources were planned.
+		_ = usage.AddMetadata(m.Key, m.Value.String)
+	}
+
+	exceeded = (quota.CPUMillicores > 0 && usage.CPUMillicores > quota.CPUMillicores) ||
+		(quota.MemoryBytes > 0 && usage.MemoryBytes > quota.MemoryBytes) ||
+		(quota.DiskBytes > 0 && usage.DiskBytes > quota.DiskBytes)
+	return usage, quota, exceeded, nil
+}
+
 // @Summary Get workspace quota by user
 // @ID get-workspace-quota-by-user
 // @Security CoderSessionToken
@@ -147,3 +225,73 @@ func (api *API) workspaceQuota(rw http.ResponseWriter, r *http.Request) {
 		Budget:          int(quotaAllowance),
 	})
 }
+
+// @Summary Get quota usage by user
+// @Description Returns the quota consumed by the user broken down by the
+// @Description workspaces that consume it.
+// @ID get-quota-usage-by-user
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param user path string true "User ID, name, or me"
+// @Success 200 {object} codersdk.UserQuota
+// @Router /users/{user}/quota [get]
+func (api *API) userQuota(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	if !api.AGPL.Authorize(r, rbac.ActionRead, user) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	api.entitlementsMu.RLock()
+	licensed := api.entitlements.Features[codersdk.FeatureTemplateRBAC].Enabled
+	api.entitlementsMu.RUnlock()
+
+	// The allowance and the breakdown are read in one transaction so the
+	// workspaces add up to the consumption reported against the budget.
+	var (
+		quotaAllowance int64 = -1
+		workspaces     []database.GetQuotaConsumedForUserByWorkspaceRow
+	)
+	err := api.Database.InTx(func(tx database.Store) error {
+		var err error
+		// There are no groups and thus no allowance if RBAC isn't licensed.
+		if licensed {
+			quotaAllowance, err = tx.GetQuotaAllowanceForUser(ctx, user.ID)
+			if err != nil {
+				return xerrors.Errorf("get allowance: %w", err)
+			}
+		}
+		workspaces, err = tx.GetQuotaConsumedForUserByWorkspace(ctx, user.ID)
+		if err != nil {
+			return xerrors.Errorf("get consumed: %w", err)
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to get quota usage",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	quota := codersdk.UserQuota{
+		Budget:     int(quotaAllowance),
+		Workspaces: make([]codersdk.WorkspaceQuotaUsage, 0, len(workspaces)),
+	}
+	for _, workspace := range workspaces {
+		quota.CreditsConsumed += int(workspace.DailyCost)
+		quota.Workspaces = append(quota.Workspaces, codersdk.WorkspaceQuotaUsage{
+			WorkspaceID:   workspace.WorkspaceID,
+			WorkspaceName: workspace.WorkspaceName,
+			TemplateID:    workspace.TemplateID,
+			TemplateName:  workspace.TemplateName,
+			Transition:    codersdk.WorkspaceTransition(workspace.Transition),
+			DailyCost:     workspace.DailyCost,
+		})
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, quota)
+}
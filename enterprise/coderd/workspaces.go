@@ -0,0 +1,250 @@
+package coderd
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/schedule"
+	"github.com/coder/coder/codersdk"
+)
+
+// workspaceScheduleOverrideMinTTL is the minimum TTL of a schedule override,
+// matching the minimum TTL of a workspace.
+const workspaceScheduleOverrideMinTTL = time.Minute
+
+func (api *API) workspaceScheduleOverridesEnabledMW(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		// The experiment must be enabled.
+		if !api.AGPL.Experiments.Enabled(codersdk.ExperimentWorkspaceScheduleOverrides) {
+			httpapi.RouteNotFound(rw)
+			return
+		}
+
+		// Entitlement must be enabled.
+		api.entitlementsMu.RLock()
+		enabled := api.entitlements.Features[codersdk.FeatureAdvancedTemplateScheduling].Enabled
+		api.entitlementsMu.RUnlock()
+		if !enabled {
+			httpapi.Write(r.Context(), rw, http.StatusForbidden, codersdk.Response{
+				Message: "Workspace schedule overrides require advanced template scheduling, which is an Enterprise feature. Contact sales!",
+			})
+			return
+		}
+
+		next.ServeHTTP(rw, r)
+	})
+}
+
+// @Summary Get workspace schedule override
+// @ID get-workspace-schedule-override
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Success 200 {object} codersdk.WorkspaceScheduleOverride
+// @Router /workspaces/{workspace}/schedule-override [get]
+func (api *API) workspaceScheduleOverride(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		workspace = httpmw.WorkspaceParam(r)
+	)
+
+	override, err := api.Database.GetWorkspaceScheduleOverrideByWorkspaceID(ctx, workspace.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace schedule override.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceScheduleOverride(override))
+}
+
+// @Summary Update workspace schedule override
+// @Description Overrides take effect from the next build of the workspace.
+// @ID update-workspace-schedule-override
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param request body codersdk.UpdateWorkspaceScheduleOverrideRequest true "Update schedule override request"
+// @Success 200 {object} codersdk.WorkspaceScheduleOverride
+// @Router /workspaces/{workspace}/schedule-override [put]
+func (api *API) putWorkspaceScheduleOverride(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		workspace = httpmw.WorkspaceParam(r)
+	)
+
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Only template admins can override the schedule of a workspace.
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	var req codersdk.UpdateWorkspaceScheduleOverrideRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	var (
+		validErrs                          []codersdk.ValidationError
+		restartRequirementDaysOfWeekParsed uint8
+	)
+	if req.DefaultTTLMillis < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "default_ttl_ms", Detail: "Must be a positive integer."})
+	}
+	if req.MaxTTLMillis < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "max_ttl_ms", Detail: "Must be a positive integer."})
+	}
+	if req.MaxTTLMillis != 0 && req.DefaultTTLMillis > req.MaxTTLMillis {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "default_ttl_ms", Detail: "Must be less than or equal to max_ttl_ms if max_ttl_ms is set."})
+	}
+	if req.DefaultTTLMillis > 0 && time.Duration(req.DefaultTTLMillis)*time.Millisecond < workspaceScheduleOverrideMinTTL {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "default_ttl_ms", Detail: "Must be at least one minute if set."})
+	}
+	if req.MaxTTLMillis > 0 && time.Duration(req.MaxTTLMillis)*time.Millisecond < workspaceScheduleOverrideMinTTL {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "max_ttl_ms", Detail: "Must be at least one minute if set."})
+	}
+	// Overrides can't lift the template's max TTL, only tighten it.
+	if templateMaxTTL := time.Duration(template.MaxTTL); templateMaxTTL > 0 {
+		if req.MaxTTLMillis == 0 || time.Duration(req.MaxTTLMillis)*time.Millisecond > templateMaxTTL {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "max_ttl_ms", Detail: fmt.Sprintf("Must be set and at most the template's max TTL of %s.", templateMaxTTL)})
+		}
+		if time.Duration(req.DefaultTTLMillis)*time.Millisecond > templateMaxTTL {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "default_ttl_ms", Detail: fmt.Sprintf("Must be at most the template's max TTL of %s.", templateMaxTTL)})
+		}
+	}
+	if len(req.RestartRequirement.DaysOfWeek) > 0 {
+		restartRequirementDaysOfWeekParsed, err = codersdk.WeekdaysToBitmap(req.RestartRequirement.DaysOfWeek)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.days_of_week", Detail: err.Error()})
+		}
+	}
+	if req.RestartRequirement.Weeks < 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.weeks", Detail: "Must be a positive integer."})
+	}
+	if req.RestartRequirement.Weeks > schedule.MaxTemplateRestartRequirementWeeks {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.weeks", Detail: fmt.Sprintf("Must be less than %d.", schedule.MaxTemplateRestartRequirementWeeks)})
+	}
+	if req.RestartRequirement.Timezone != "" {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.timezone", Detail: "The restart requirement timezone can only be set on the template."})
+	}
+	if req.RestartRequirement.SpreadMillis != 0 {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.spread_ms", Detail: "The restart requirement spread can only be set on the template."})
+	}
+	if req.RestartRequirement.UpdateOnRestart {
+		validErrs = append(validErrs, codersdk.ValidationError{Field: "restart_requirement.update_on_restart", Detail: "Updating workspaces on restart can only be set on the template."})
+	}
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid request to update workspace schedule override!",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	now := database.Now()
+	override, err := api.Database.UpsertWorkspaceScheduleOverride(ctx, database.UpsertWorkspaceScheduleOverrideParams{
+		WorkspaceID:                  workspace.ID,
+		CreatedAt:                    now,
+		UpdatedAt:                    now,
+		DefaultTTL:                   int64(time.Duration(req.DefaultTTLMillis) * time.Millisecond),
+		MaxTTL:                       int64(time.Duration(req.MaxTTLMillis) * time.Millisecond),
+		RestartRequirementDaysOfWeek: int16(restartRequirementDaysOfWeekParsed),
+		RestartRequirementWeeks:      req.RestartRequirement.Weeks,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating workspace schedule override.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspaceScheduleOverride(override))
+}
+
+// @Summary Delete workspace schedule override
+// @ID delete-workspace-schedule-override
+// @Security CoderSessionToken
+// @Tags Enterprise
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Success 204
+// @Router /workspaces/{workspace}/schedule-override [delete]
+func (api *API) deleteWorkspaceScheduleOverride(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		workspace = httpmw.WorkspaceParam(r)
+	)
+
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	_, err = api.Database.GetWorkspaceScheduleOverrideByWorkspaceID(ctx, workspace.ID)
+	if httpapi.Is404Error(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace schedule override.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	err = api.Database.DeleteWorkspaceScheduleOverrideByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting workspace schedule override.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func convertWorkspaceScheduleOverride(override database.WorkspaceScheduleOverride) codersdk.WorkspaceScheduleOverride {
+	return codersdk.WorkspaceScheduleOverride{
+		WorkspaceID:      override.WorkspaceID,
+		CreatedAt:        override.CreatedAt,
+		UpdatedAt:        override.UpdatedAt,
+		DefaultTTLMillis: time.Duration(override.DefaultTTL).Milliseconds(),
+		MaxTTLMillis:     time.Duration(override.MaxTTL).Milliseconds(),
+		RestartRequirement: codersdk.TemplateRestartRequirement{
+			DaysOfWeek: codersdk.BitmapToWeekdays(uint8(override.RestartRequirementDaysOfWeek)),
+			Weeks:      override.RestartRequirementWeeks,
+		},
+	}
+}
@@ -2,12 +2,15 @@ package coderd_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"cdr.dev/slog/sloggers/slogtest"
@@ -17,6 +20,7 @@ import (
 	"github.com/coder/coder/coderd/database"
 	agplschedule "github.com/coder/coder/coderd/schedule"
 	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/coderd/webhooks"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/coderd/coderdenttest"
 	"github.com/coder/coder/enterprise/coderd/license"
@@ -183,6 +187,119 @@ func TestWorkspaceAutobuild(t *testing.T) {
 		require.Len(t, stats.Transitions, 0)
 	})
 
+	t.Run("FailureTTLRetries", func(t *testing.T) {
+		t.Parallel()
+
+		payloads := make(chan webhooks.Payload, 10)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload webhooks.Payload
+			if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload)) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			payloads <- payload
+		}))
+		t.Cleanup(srv.Close)
+		dispatcher, err := webhooks.New(slogtest.Make(t, nil), webhooks.Options{
+			URLs: []string{srv.URL},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = dispatcher.Close() })
+
+		var (
+			ctx    = testutil.Context(t, testutil.WaitLong)
+			ticker = make(chan time.Time)
+			statCh = make(chan autobuild.Stats)
+			logger = slogtest.Make(t, &slogtest.Options{
+				// We ignore errors here since we expect to fail
+				// builds.
+				IgnoreErrors: true,
+			})
+			failureTTL = time.Minute
+			backoff    = time.Minute
+		)
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				Logger:                   &logger,
+				AutobuildTicker:          ticker,
+				IncludeProvisionerDaemon: true,
+				AutobuildStats:           statCh,
+				Webhooks:                 dispatcher,
+				TemplateScheduleStore:    schedule.NewEnterpriseTemplateScheduleStore(agplUserQuietHoursScheduleStore()),
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{codersdk.FeatureAdvancedTemplateScheduling: 1},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionPlan:  echo.ProvisionComplete,
+			ProvisionApply: echo.ProvisionFailed,
+		})
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.FailureTTLMillis = ptr.Ref[int64](failureTTL.Milliseconds())
+		})
+		template, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:                      template.Name,
+			DefaultTTLMillis:          template.DefaultTTLMillis,
+			AllowUserAutostart:        template.AllowUserAutostart,
+			AllowUserAutostop:         template.AllowUserAutostop,
+			FailureTTLMillis:          template.FailureTTLMillis,
+			FailureRetries:            ptr.Ref[int32](1),
+			FailureRetryBackoffMillis: ptr.Ref(backoff.Milliseconds()),
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 1, template.FailureRetries)
+		require.Equal(t, backoff.Milliseconds(), template.FailureRetryBackoffMillis)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		ws := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		require.Equal(t, codersdk.WorkspaceStatusFailed, build.Status)
+
+		// The failed start is not retried before the backoff has elapsed.
+		ticker <- build.Job.CompletedAt.Add(backoff / 2)
+		stats := <-statCh
+		require.Len(t, stats.Transitions, 0)
+
+		// The failed start is retried instead of being stopped.
+		ticker <- build.Job.CompletedAt.Add(backoff)
+		stats = <-statCh
+		require.Len(t, stats.Transitions, 1)
+		require.Equal(t, database.WorkspaceTransitionStart, stats.Transitions[ws.ID])
+		ws = coderdtest.MustWorkspace(t, client, ws.ID)
+		build = coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		require.Equal(t, codersdk.WorkspaceStatusFailed, build.Status)
+		require.Equal(t, codersdk.BuildReasonFailureTTL, build.Reason)
+
+		// Once the retries are exhausted, the owner is notified and the
+		// workspace is stopped after the failure TTL.
+		ticker <- build.Job.CompletedAt.Add(failureTTL * 2)
+		stats = <-statCh
+		require.Len(t, stats.Transitions, 1)
+		require.Equal(t, database.WorkspaceTransitionStop, stats.Transitions[ws.ID])
+
+		// Events are delivered concurrently, so they may arrive in any order.
+		events := make(map[webhooks.Event]webhooks.Payload)
+		for len(events) < 2 {
+			select {
+			case payload := <-payloads:
+				events[payload.Event] = payload
+			case <-ctx.Done():
+				t.Fatal("timed out waiting for webhooks")
+			}
+		}
+		require.Contains(t, events, webhooks.EventWorkspaceStopped)
+		require.Contains(t, events, webhooks.EventWorkspaceStartFailed)
+		failed := events[webhooks.EventWorkspaceStartFailed]
+		require.Equal(t, build.ID, failed.Workspace.BuildID)
+		require.Equal(t, string(database.BuildReasonFailureTTL), events[webhooks.EventWorkspaceStopped].Workspace.Reason)
+		if assert.NotNil(t, failed.Workspace.Deadline) {
+			assert.WithinDuration(t, build.Job.CompletedAt.Add(failureTTL), *failed.Workspace.Deadline, time.Second)
+		}
+	})
+
 	// This just provides a baseline that no actions are being taken
 	// against a workspace when none of the TTL fields are set.
 	t.Run("TemplateTTLsUnset", func(t *testing.T) {
@@ -630,6 +747,421 @@ func TestWorkspaceAutobuild(t *testing.T) {
 		stats = <-statsCh
 		require.Len(t, stats.Transitions, 0)
 	})
+
+	t.Run("UpdateOnRestart", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			ctx     = testutil.Context(t, testutil.WaitMedium)
+			tickCh  = make(chan time.Time)
+			statsCh = make(chan autobuild.Stats)
+		)
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments.Set(string(codersdk.ExperimentTemplateRestartRequirement))
+		dv.UserQuietHoursSchedule.DefaultSchedule.Set("CRON_TZ=UTC 0 0 * * *")
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues:         dv,
+				AutobuildTicker:          tickCh,
+				IncludeProvisionerDaemon: true,
+				AutobuildStats:           statsCh,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+					codersdk.FeatureTemplateRestartRequirement: 1,
+				},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.RestartRequirement = &codersdk.TemplateRestartRequirement{
+				DaysOfWeek:      []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"},
+				UpdateOnRestart: true,
+			}
+		})
+		require.True(t, template.RestartRequirement.UpdateOnRestart)
+
+		ws := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		require.True(t, build.MaxDeadline.Valid)
+
+		// Given: the template has a new active version.
+		newVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, newVersion.ID)
+		err := client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: newVersion.ID,
+		})
+		require.NoError(t, err)
+
+		// When: the workspace reaches the max deadline of the restart
+		// requirement, it's stopped.
+		tickCh <- build.MaxDeadline.Time.Add(time.Minute)
+		stats := <-statsCh
+		require.NoError(t, stats.Error)
+		require.Len(t, stats.Transitions, 1)
+		require.Equal(t, database.WorkspaceTransitionStop, stats.Transitions[ws.ID])
+		ws = coderdtest.MustWorkspace(t, client, ws.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		require.Equal(t, codersdk.BuildReasonRestartRequirement, ws.LatestBuild.Reason)
+
+		// Then: it's started again on the active version.
+		tickCh <- build.MaxDeadline.Time.Add(2 * time.Minute)
+		stats = <-statsCh
+		require.NoError(t, stats.Error)
+		require.Len(t, stats.Transitions, 1)
+		require.Equal(t, database.WorkspaceTransitionStart, stats.Transitions[ws.ID])
+		ws = coderdtest.MustWorkspace(t, client, ws.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		require.Equal(t, newVersion.ID, ws.LatestBuild.TemplateVersionID)
+		require.Equal(t, codersdk.BuildReasonRestartRequirement, ws.LatestBuild.Reason)
+
+		// The workspace isn't started again after it's stopped manually.
+		ws = coderdtest.MustTransitionWorkspace(t, client, ws.ID, database.WorkspaceTransitionStart, database.WorkspaceTransitionStop)
+		tickCh <- build.MaxDeadline.Time.Add(3 * time.Minute)
+		stats = <-statsCh
+		require.NoError(t, stats.Error)
+		require.Len(t, stats.Transitions, 0)
+	})
+
+	t.Run("MaxLifetime", func(t *testing.T) {
+		t.Parallel()
+
+		payloads := make(chan webhooks.Payload, 10)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload webhooks.Payload
+			if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload)) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			payloads <- payload
+		}))
+		t.Cleanup(srv.Close)
+		dispatcher, err := webhooks.New(slogtest.Make(t, nil), webhooks.Options{
+			URLs: []string{srv.URL},
+		})
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = dispatcher.Close() })
+
+		var (
+			ctx         = testutil.Context(t, testutil.WaitLong)
+			ticker      = make(chan time.Time)
+			statCh      = make(chan autobuild.Stats)
+			maxLifetime = 7 * 24 * time.Hour
+		)
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				AutobuildTicker:          ticker,
+				IncludeProvisionerDaemon: true,
+				AutobuildStats:           statCh,
+				Webhooks:                 dispatcher,
+				TemplateScheduleStore:    schedule.NewEnterpriseTemplateScheduleStore(agplUserQuietHoursScheduleStore()),
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{codersdk.FeatureAdvancedTemplateScheduling: 1},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionPlan:  echo.ProvisionComplete,
+			ProvisionApply: echo.ProvisionComplete,
+		})
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		template, err = client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:               template.Name,
+			DefaultTTLMillis:   template.DefaultTTLMillis,
+			AllowUserAutostart: template.AllowUserAutostart,
+			AllowUserAutostop:  template.AllowUserAutostop,
+			MaxLifetimeMillis:  ptr.Ref(maxLifetime.Milliseconds()),
+		})
+		require.NoError(t, err)
+		require.Equal(t, maxLifetime.Milliseconds(), template.MaxLifetimeMillis)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		// Workspaces expire regardless of autostop, so disable it.
+		ws := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.TTLMillis = nil
+		})
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		require.Equal(t, codersdk.WorkspaceStatusRunning, build.Status)
+
+		// The owner is warned a day before the workspace expires, even though
+		// it is in use.
+		ticker <- ws.CreatedAt.Add(maxLifetime - time.Hour)
+		stats := <-statCh
+		require.Len(t, stats.Transitions, 0)
+		select {
+		case payload := <-payloads:
+			require.Equal(t, webhooks.EventWorkspaceLifetimeExpiring, payload.Event)
+			require.Equal(t, ws.ID, payload.Workspace.ID)
+			require.Equal(t, string(database.BuildReasonMaxLifetime), payload.Workspace.Reason)
+			if assert.NotNil(t, payload.Workspace.Deadline) {
+				assert.WithinDuration(t, ws.CreatedAt.Add(maxLifetime), *payload.Workspace.Deadline, time.Second)
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for webhook")
+		}
+
+		// The workspace is deleted once it expires.
+		ticker <- ws.CreatedAt.Add(maxLifetime + time.Minute)
+		stats = <-statCh
+		require.Len(t, stats.Transitions, 1)
+		require.Equal(t, database.WorkspaceTransitionDelete, stats.Transitions[ws.ID])
+		ws = coderdtest.MustWorkspace(t, client, ws.ID)
+		require.Equal(t, codersdk.BuildReasonMaxLifetime, ws.LatestBuild.Reason)
+		select {
+		case payload := <-payloads:
+			require.Equal(t, webhooks.EventWorkspaceDeletedDueToMaxLifetime, payload.Event)
+			require.Equal(t, ws.LatestBuild.ID, payload.Workspace.BuildID)
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for webhook")
+		}
+	})
+
+	t.Run("MaxLifetimeArchive", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			ticker      = make(chan time.Time)
+			statCh      = make(chan autobuild.Stats)
+			maxLifetime = 24 * time.Hour
+			lockedTTL   = 7 * 24 * time.Hour
+		)
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				AutobuildTicker:          ticker,
+				IncludeProvisionerDaemon: true,
+				AutobuildStats:           statCh,
+				TemplateScheduleStore:    schedule.NewEnterpriseTemplateScheduleStore(agplUserQuietHoursScheduleStore()),
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{codersdk.FeatureAdvancedTemplateScheduling: 1},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionPlan:  echo.ProvisionComplete,
+			ProvisionApply: echo.ProvisionComplete,
+		})
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.LockedTTLMillis = ptr.Ref[int64](lockedTTL.Milliseconds())
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+		template, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:               template.Name,
+			DefaultTTLMillis:   template.DefaultTTLMillis,
+			AllowUserAutostart: template.AllowUserAutostart,
+			AllowUserAutostop:  template.AllowUserAutostop,
+			LockedTTLMillis:    template.LockedTTLMillis,
+			MaxLifetimeMillis:  ptr.Ref(maxLifetime.Milliseconds()),
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		ws := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.TTLMillis = nil
+		})
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		require.Equal(t, codersdk.WorkspaceStatusRunning, build.Status)
+
+		// Templates with a locked TTL stop and lock expired workspaces
+		// instead of deleting them.
+		ticker <- ws.CreatedAt.Add(maxLifetime + time.Minute)
+		stats := <-statCh
+		require.Len(t, stats.Transitions, 1)
+		require.Equal(t, database.WorkspaceTransitionStop, stats.Transitions[ws.ID])
+		ws = coderdtest.MustWorkspace(t, client, ws.ID)
+		require.NotNil(t, ws.LockedAt)
+		require.Equal(t, codersdk.BuildReasonMaxLifetime, ws.LatestBuild.Reason)
+		build = coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		require.Equal(t, codersdk.WorkspaceStatusStopped, build.Status)
+
+		// Locked workspaces are left to the locked TTL.
+		ticker <- ws.CreatedAt.Add(maxLifetime + 2*time.Minute)
+		stats = <-statCh
+		require.Len(t, stats.Transitions, 0)
+	})
+
+	t.Run("LockedTTLArchive", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			ticker           = make(chan time.Time)
+			statCh           = make(chan autobuild.Stats)
+			lockedTTL        = time.Minute
+			archiveRetention = time.Hour
+		)
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				AutobuildTicker:          ticker,
+				IncludeProvisionerDaemon: true,
+				AutobuildStats:           statCh,
+				TemplateScheduleStore:    schedule.NewEnterpriseTemplateScheduleStore(agplUserQuietHoursScheduleStore()),
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{codersdk.FeatureAdvancedTemplateScheduling: 1},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionPlan:  echo.ProvisionComplete,
+			ProvisionApply: echo.ProvisionComplete,
+		})
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.LockedTTLMillis = ptr.Ref[int64](lockedTTL.Milliseconds())
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+		template, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:                   template.Name,
+			DefaultTTLMillis:       template.DefaultTTLMillis,
+			AllowUserAutostart:     template.AllowUserAutostart,
+			AllowUserAutostop:      template.AllowUserAutostop,
+			LockedTTLMillis:        template.LockedTTLMillis,
+			ArchiveRetentionMillis: ptr.Ref(archiveRetention.Milliseconds()),
+		})
+		require.NoError(t, err)
+		require.Equal(t, archiveRetention.Milliseconds(), template.ArchiveRetentionMillis)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		ws := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.TTLMillis = nil
+		})
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		require.Equal(t, codersdk.WorkspaceStatusRunning, build.Status)
+		build = coderdtest.CreateWorkspaceBuild(t, client, ws, database.WorkspaceTransitionStop)
+		_ = coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+
+		err = client.UpdateWorkspaceLock(ctx, ws.ID, codersdk.UpdateWorkspaceLock{
+			Lock: true,
+		})
+		require.NoError(t, err)
+		ws = coderdtest.MustWorkspace(t, client, ws.ID)
+		require.NotNil(t, ws.LockedAt)
+		// Workspaces are deleted once the archive retention has passed.
+		require.NotNil(t, ws.DeletingAt)
+		require.Equal(t, lockedTTL+archiveRetention, ws.DeletingAt.Sub(*ws.LockedAt))
+
+		// Workspaces that breach the locked TTL are archived instead of
+		// deleted.
+		ticker <- ws.LockedAt.Add(lockedTTL + time.Minute)
+		stats := <-statCh
+		require.Len(t, stats.Transitions, 1)
+		require.Equal(t, database.WorkspaceTransitionArchive, stats.Transitions[ws.ID])
+		ws = coderdtest.MustWorkspace(t, client, ws.ID)
+		require.NotNil(t, ws.LockedAt)
+		require.Equal(t, codersdk.BuildReasonLockedTTL, ws.LatestBuild.Reason)
+		build = coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		require.Equal(t, codersdk.WorkspaceTransitionArchive, build.Transition)
+		require.Equal(t, codersdk.WorkspaceStatusStopped, build.Status)
+
+		// Archived workspaces are only archived once.
+		ticker <- ws.LockedAt.Add(lockedTTL + 2*time.Minute)
+		stats = <-statCh
+		require.Len(t, stats.Transitions, 0)
+
+		// The workspace is deleted once the archive retention has passed.
+		ticker <- ws.DeletingAt.Add(time.Minute)
+		stats = <-statCh
+		require.Len(t, stats.Transitions, 1)
+		require.Equal(t, database.WorkspaceTransitionDelete, stats.Transitions[ws.ID])
+	})
+
+	t.Run("LockedTTLArchiveRestore", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			ticker           = make(chan time.Time)
+			statCh           = make(chan autobuild.Stats)
+			lockedTTL        = time.Minute
+			archiveRetention = time.Hour
+		)
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				AutobuildTicker:          ticker,
+				IncludeProvisionerDaemon: true,
+				AutobuildStats:           statCh,
+				TemplateScheduleStore:    schedule.NewEnterpriseTemplateScheduleStore(agplUserQuietHoursScheduleStore()),
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{codersdk.FeatureAdvancedTemplateScheduling: 1},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionPlan:  echo.ProvisionComplete,
+			ProvisionApply: echo.ProvisionComplete,
+		})
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.LockedTTLMillis = ptr.Ref[int64](lockedTTL.Milliseconds())
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+		template, err := client.UpdateTemplateMeta(ctx, template.ID, codersdk.UpdateTemplateMeta{
+			Name:                   template.Name,
+			DefaultTTLMillis:       template.DefaultTTLMillis,
+			AllowUserAutostart:     template.AllowUserAutostart,
+			AllowUserAutostop:      template.AllowUserAutostop,
+			LockedTTLMillis:        template.LockedTTLMillis,
+			ArchiveRetentionMillis: ptr.Ref(archiveRetention.Milliseconds()),
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		ws := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.AutostartSchedule = nil
+			cwr.TTLMillis = nil
+		})
+		build := coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		build = coderdtest.CreateWorkspaceBuild(t, client, ws, database.WorkspaceTransitionStop)
+		_ = coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+		err = client.UpdateWorkspaceLock(ctx, ws.ID, codersdk.UpdateWorkspaceLock{
+			Lock: true,
+		})
+		require.NoError(t, err)
+		ws = coderdtest.MustWorkspace(t, client, ws.ID)
+
+		ticker <- ws.LockedAt.Add(lockedTTL + time.Minute)
+		stats := <-statCh
+		require.Len(t, stats.Transitions, 1)
+		require.Equal(t, database.WorkspaceTransitionArchive, stats.Transitions[ws.ID])
+		ws = coderdtest.MustWorkspace(t, client, ws.ID)
+		archived := coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		deletingAt := *ws.DeletingAt
+
+		// Owners restore archived workspaces by unlocking them.
+		err = client.UpdateWorkspaceLock(ctx, ws.ID, codersdk.UpdateWorkspaceLock{
+			Lock: false,
+		})
+		require.NoError(t, err)
+		ws = coderdtest.MustWorkspace(t, client, ws.ID)
+		require.Nil(t, ws.LockedAt)
+		require.Nil(t, ws.DeletingAt)
+
+		// Restored workspaces are not deleted.
+		ticker <- deletingAt.Add(time.Minute)
+		stats = <-statCh
+		require.Len(t, stats.Transitions, 0)
+
+		// And are autostarted like stopped workspaces.
+		sched, err := agplschedule.Weekly("CRON_TZ=UTC 0 * * * *")
+		require.NoError(t, err)
+		err = client.UpdateWorkspaceAutostart(ctx, ws.ID, codersdk.UpdateWorkspaceAutostartRequest{
+			Schedule: ptr.Ref(sched.String()),
+		})
+		require.NoError(t, err)
+		ticker <- sched.Next(archived.CreatedAt)
+		stats = <-statCh
+		require.Len(t, stats.Transitions, 1)
+		require.Equal(t, database.WorkspaceTransitionStart, stats.Transitions[ws.ID])
+		ws = coderdtest.MustWorkspace(t, client, ws.ID)
+		build = coderdtest.AwaitWorkspaceBuildJob(t, client, ws.LatestBuild.ID)
+		require.Equal(t, codersdk.WorkspaceStatusRunning, build.Status)
+	})
 }
 
 func TestWorkspacesFiltering(t *testing.T) {
@@ -752,3 +1284,213 @@ func TestWorkspaceLock(t *testing.T) {
 		require.True(t, workspace.LastUsedAt.After(lastUsedAt))
 	})
 }
+
+func TestWorkspaceScheduleOverride(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments.Set(string(codersdk.ExperimentWorkspaceScheduleOverrides))
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues:         dv,
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+				},
+			},
+		})
+		memberClient, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.MaxTTLMillis = ptr.Ref((96 * time.Hour).Milliseconds())
+		})
+		workspace := coderdtest.CreateWorkspace(t, memberClient, user.OrganizationID, template.ID)
+		_ = coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+
+		// No override is set by default.
+		_, err := client.WorkspaceScheduleOverride(ctx, workspace.ID)
+		require.Error(t, err)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		// Workspace owners cannot override their own schedule.
+		_, err = memberClient.UpdateWorkspaceScheduleOverride(ctx, workspace.ID, codersdk.UpdateWorkspaceScheduleOverrideRequest{
+			DefaultTTLMillis: time.Hour.Milliseconds(),
+			MaxTTLMillis:     (72 * time.Hour).Milliseconds(),
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		override, err := client.UpdateWorkspaceScheduleOverride(ctx, workspace.ID, codersdk.UpdateWorkspaceScheduleOverrideRequest{
+			DefaultTTLMillis: time.Hour.Milliseconds(),
+			MaxTTLMillis:     (72 * time.Hour).Milliseconds(),
+			RestartRequirement: codersdk.TemplateRestartRequirement{
+				DaysOfWeek: []string{"saturday", "sunday"},
+				Weeks:      2,
+			},
+		})
+		require.NoError(t, err)
+		require.Equal(t, workspace.ID, override.WorkspaceID)
+		require.Equal(t, time.Hour.Milliseconds(), override.DefaultTTLMillis)
+		require.Equal(t, (72 * time.Hour).Milliseconds(), override.MaxTTLMillis)
+		require.Equal(t, []string{"saturday", "sunday"}, override.RestartRequirement.DaysOfWeek)
+		require.EqualValues(t, 2, override.RestartRequirement.Weeks)
+
+		// Workspace owners can see the override.
+		got, err := memberClient.WorkspaceScheduleOverride(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, override, got)
+
+		// The override applies to the next build of the workspace.
+		build := coderdtest.CreateWorkspaceBuild(t, memberClient, workspace, database.WorkspaceTransitionStart)
+		build = coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+		require.WithinDuration(t, time.Now().Add(72*time.Hour), build.MaxDeadline.Time, time.Minute)
+
+		err = memberClient.DeleteWorkspaceScheduleOverride(ctx, workspace.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		err = client.DeleteWorkspaceScheduleOverride(ctx, workspace.ID)
+		require.NoError(t, err)
+		_, err = client.WorkspaceScheduleOverride(ctx, workspace.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("InvalidRequest", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments.Set(string(codersdk.ExperimentWorkspaceScheduleOverrides))
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues:         dv,
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+				},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.UpdateWorkspaceScheduleOverride(ctx, workspace.ID, codersdk.UpdateWorkspaceScheduleOverrideRequest{
+			DefaultTTLMillis: (2 * time.Hour).Milliseconds(),
+			MaxTTLMillis:     time.Hour.Milliseconds(),
+			RestartRequirement: codersdk.TemplateRestartRequirement{
+				DaysOfWeek: []string{"notaday"},
+			},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 2)
+	})
+
+	t.Run("OutsideTemplateBounds", func(t *testing.T) {
+		t.Parallel()
+
+		dv := coderdtest.DeploymentValues(t)
+		dv.Experiments.Set(string(codersdk.ExperimentWorkspaceScheduleOverrides))
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				DeploymentValues:         dv,
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+				},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.MaxTTLMillis = ptr.Ref((24 * time.Hour).Milliseconds())
+		})
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		for _, tc := range []struct {
+			name  string
+			req   codersdk.UpdateWorkspaceScheduleOverrideRequest
+			field string
+		}{
+			{
+				name:  "DefaultTTLBelowMinimum",
+				req:   codersdk.UpdateWorkspaceScheduleOverrideRequest{DefaultTTLMillis: (30 * time.Second).Milliseconds(), MaxTTLMillis: time.Hour.Milliseconds()},
+				field: "default_ttl_ms",
+			},
+			{
+				name:  "MaxTTLAboveTemplate",
+				req:   codersdk.UpdateWorkspaceScheduleOverrideRequest{DefaultTTLMillis: time.Hour.Milliseconds(), MaxTTLMillis: (48 * time.Hour).Milliseconds()},
+				field: "max_ttl_ms",
+			},
+			{
+				name:  "MaxTTLUnset",
+				req:   codersdk.UpdateWorkspaceScheduleOverrideRequest{DefaultTTLMillis: time.Hour.Milliseconds()},
+				field: "max_ttl_ms",
+			},
+		} {
+			_, err := client.UpdateWorkspaceScheduleOverride(ctx, workspace.ID, tc.req)
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr, tc.name)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode(), tc.name)
+			require.Len(t, apiErr.Validations, 1, tc.name)
+			require.Equal(t, tc.field, apiErr.Validations[0].Field, tc.name)
+		}
+
+		// An override within the template's bounds is accepted.
+		_, err := client.UpdateWorkspaceScheduleOverride(ctx, workspace.ID, codersdk.UpdateWorkspaceScheduleOverrideRequest{
+			DefaultTTLMillis: time.Hour.Milliseconds(),
+			MaxTTLMillis:     (12 * time.Hour).Milliseconds(),
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("ExperimentDisabled", func(t *testing.T) {
+		t.Parallel()
+
+		client, user := coderdenttest.New(t, &coderdenttest.Options{
+			Options: &coderdtest.Options{
+				IncludeProvisionerDaemon: true,
+			},
+			LicenseOptions: &coderdenttest.LicenseOptions{
+				Features: license.Features{
+					codersdk.FeatureAdvancedTemplateScheduling: 1,
+				},
+			},
+		})
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+
+		ctx := testutil.Context(t, testutil.WaitLong)
+		_, err := client.WorkspaceScheduleOverride(ctx, workspace.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/coder/coder/coderd/database/pubsub"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/util/slice"
+	"github.com/coder/coder/codersdk"
 	agpl "github.com/coder/coder/tailnet"
 )
 
@@ -81,6 +82,13 @@ type pgCoord struct {
 
 	binder  *binder
 	querier *querier
+
+	// legacyMu protects legacyAgents, a cache of whether agents are
+	// legacy, see agentIsLegacy. Entries are evicted when the agent
+	// disconnects from this coordinator or is no longer subscribed to, as the
+	// agent may come back with different addresses, e.g. after an upgrade.
+	legacyMu     sync.Mutex
+	legacyAgents map[uuid.UUID]bool
 }
 
 var pgCoordSubject = rbac.Subject{
@@ -122,14 +130,60 @@ func NewPGCoord(ctx context.Context, logger slog.Logger, ps pubsub.Pubsub, store
 		id:             id,
 		querier:        newQuerier(ctx, logger, ps, store, id, cCh, numQuerierWorkers, fHB),
 		closed:         make(chan struct{}),
+		legacyAgents:   make(map[uuid.UUID]bool),
 	}
 	logger.Info(ctx, "starting coordinator")
 	return c, nil
 }
 
+// ServeMultiAgent serves a connection that subscribes to many agents, such as
+// coderd's server tailnet. Each subscription is served as a separate client of
+// the coordinator, so the nodes are shared with the other coordinators through
+// the database like those of any other client. If the coordinator closes a
+// subscription, e.g. because it's unhealthy or shutting down, the whole
+// connection is closed so that the caller reconnects.
 func (c *pgCoord) ServeMultiAgent(id uuid.UUID) agpl.MultiAgentConn {
-	_, _ = c, id
-	panic("not implemented") // TODO: Implement
+	ma := &pgMultiAgent{
+		coord:  c,
+		logger: c.logger.With(slog.F("multi_agent_id", id)),
+		subs:   make(map[uuid.UUID]*pgMultiAgentSub),
+	}
+	ma.conn = (&agpl.MultiAgent{
+		ID:                id,
+		AgentIsLegacyFunc: c.agentIsLegacy,
+		OnSubscribe:       ma.subscribe,
+		OnUnsubscribe:     ma.unsubscribe,
+		OnNodeUpdate:      ma.updateNode,
+		OnRemove:          ma.remove,
+	}).Init()
+	return ma.conn
+}
+
+// agentIsLegacy reports whether the agent only listens on the legacy agent IP,
+// and therefore can't be reached over the server tailnet.
+func (c *pgCoord) agentIsLegacy(agentID uuid.UUID) bool {
+	c.legacyMu.Lock()
+	legacy, ok := c.legacyAgents[agentID]
+	c.legacyMu.Unlock()
+	if ok {
+		return legacy
+	}
+	node := c.Node(agentID)
+	if node == nil {
+		return false
+	}
+	legacy = len(node.Addresses) > 0 && node.Addresses[0].Addr() == codersdk.WorkspaceAgentIP
+	c.legacyMu.Lock()
+	c.legacyAgents[agentID] = legacy
+	c.legacyMu.Unlock()
+	return legacy
+}
+
+// forgetAgent evicts the agent from the legacy agents cache.
+func (c *pgCoord) forgetAgent(agentID uuid.UUID) {
+	c.legacyMu.Lock()
+	delete(c.legacyAgents, agentID)
+	c.legacyMu.Unlock()
 }
 
 func (c *pgCoord) Node(id uuid.UUID) *agpl.Node {
@@ -172,6 +226,7 @@ func (c *pgCoord) ServeClient(conn net.Conn, id uuid.UUID, agent uuid.UUID) erro
 }
 
 func (c *pgCoord) ServeAgent(conn net.Conn, id uuid.UUID, name string) error {
+	defer c.forgetAgent(id)
 	defer func() {
 		err := conn.Close()
 		if err != nil {
@@ -197,6 +252,140 @@ func (c *pgCoord) Close() error {
 	return nil
 }
 
+// pgMultiAgent connects a MultiAgent to the pgCoord. Clients are only ever
+// mapped to a single agent, so every subscription is a client connection of
+// its own, with a unique client ID.
+type pgMultiAgent struct {
+	coord  *pgCoord
+	logger slog.Logger
+	conn   *agpl.MultiAgent
+
+	mu   sync.Mutex
+	node *agpl.Node
+	// nodeVersion is incremented on every node update, so that subscriptions
+	// never replace a newer node with an older one.
+	nodeVersion uint64
+	subs        map[uuid.UUID]*pgMultiAgentSub
+}
+
+type pgMultiAgentSub struct {
+	conn     net.Conn
+	sendNode func(node *agpl.Node)
+
+	// sendMu serializes sends, which block until the coordinator reads the
+	// node, so they are never done while holding the mutex of the
+	// pgMultiAgent.
+	sendMu  sync.Mutex
+	version uint64
+}
+
+// send sends the node to the coordinator unless a newer node was already sent.
+func (s *pgMultiAgentSub) send(node *agpl.Node, version uint64) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if version <= s.version {
+		return
+	}
+	s.version = version
+	s.sendNode(node)
+}
+
+func (m *pgMultiAgent) subscribe(enq agpl.Queue, agentID uuid.UUID) (*agpl.Node, error) {
+	m.mu.Lock()
+	if _, ok := m.subs[agentID]; ok {
+		m.mu.Unlock()
+		return nil, nil
+	}
+
+	clientID := uuid.New()
+	logger := m.logger.With(slog.F("agent_id", agentID), slog.F("client_id", clientID))
+	conn, serverConn := net.Pipe()
+	go func() {
+		err := m.coord.ServeClient(serverConn, clientID, agentID)
+		if err != nil {
+			logger.Debug(m.coord.ctx, "serve multi agent subscription", slog.Error(err))
+		}
+	}()
+	sendNode, errChan := agpl.ServeCoordinator(conn, func(nodes []*agpl.Node) error {
+		err := enq.Enqueue(nodes)
+		if err != nil {
+			logger.Warn(m.coord.ctx, "enqueue agent nodes", slog.Error(err))
+		}
+		return nil
+	})
+	sub := &pgMultiAgentSub{
+		conn:     conn,
+		sendNode: sendNode,
+	}
+	m.subs[agentID] = sub
+	node, version := m.node, m.nodeVersion
+	m.mu.Unlock()
+	go m.watchSub(agentID, sub, errChan)
+
+	if node != nil {
+		sub.send(node, version)
+	}
+	// The agent's node is sent asynchronously once the coordinator has
+	// queried it.
+	return nil, nil
+}
+
+// watchSub closes the whole connection if a subscription is closed by the
+// coordinator.
+func (m *pgMultiAgent) watchSub(agentID uuid.UUID, sub *pgMultiAgentSub, errChan <-chan error) {
+	err := <-errChan
+	m.mu.Lock()
+	current := m.subs[agentID] == sub
+	m.mu.Unlock()
+	if !current {
+		// We unsubscribed ourselves.
+		return
+	}
+	m.logger.Info(m.coord.ctx, "multi agent subscription closed by coordinator",
+		slog.F("agent_id", agentID), slog.Error(err))
+	_ = m.conn.CoordinatorClose()
+	m.remove(m.conn.ID)
+}
+
+func (m *pgMultiAgent) unsubscribe(_ agpl.Queue, agentID uuid.UUID) error {
+	m.mu.Lock()
+	sub, ok := m.subs[agentID]
+	delete(m.subs, agentID)
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	m.coord.forgetAgent(agentID)
+	return sub.conn.Close()
+}
+
+func (m *pgMultiAgent) updateNode(_ uuid.UUID, node *agpl.Node) error {
+	m.mu.Lock()
+	m.node = node
+	m.nodeVersion++
+	version := m.nodeVersion
+	subs := make([]*pgMultiAgentSub, 0, len(m.subs))
+	for _, sub := range m.subs {
+		subs = append(subs, sub)
+	}
+	m.mu.Unlock()
+	for _, sub := range subs {
+		sub.send(node, version)
+	}
+	return nil
+}
+
+func (m *pgMultiAgent) remove(_ uuid.UUID) {
+	m.mu.Lock()
+	subs := m.subs
+	m.subs = make(map[uuid.UUID]*pgMultiAgentSub)
+	m.mu.Unlock()
+	for agentID, sub := range subs {
+		m.coord.forgetAgent(agentID)
+		_ = sub.conn.Close()
+	}
+}
+
 // connIO manages the reading and writing to a connected client or agent.  Agent connIOs have their client field set to
 // uuid.Nil.  It reads node updates via its decoder, then pushes them onto the bindings channel.  It receives mappings
 // via its updates TrackedConn, which then writes them.
@@ -1410,7 +1599,7 @@ func (c *pgCoord) ServeHTTPDebug(w http.ResponseWriter, r *http.Request) {
 
 func (c *pgCoord) htmlDebug(ctx context.Context) (agpl.HTMLDebug, error) {
 	now := time.Now()
-	data := agpl.HTMLDebug{}
+	data := agpl.HTMLDebug{HA: true}
 	agents, clients, err := c.querier.getAll(ctx)
 	if err != nil {
 		return data, xerrors.Errorf("get all agents and clients: %w", err)
@@ -1420,29 +1609,23 @@ func (c *pgCoord) htmlDebug(ctx context.Context) (agpl.HTMLDebug, error) {
 		htmlAgent := &agpl.HTMLAgent{
 			ID: agent.ID,
 			// Name: ??, TODO: get agent names
-			LastWriteAge: now.Sub(agent.UpdatedAt).Round(time.Second),
+			LastWriteAge: agpl.HTMLDuration(now.Sub(agent.UpdatedAt).Round(time.Second)),
 		}
 		for _, conn := range clients[agent.ID] {
 			htmlAgent.Connections = append(htmlAgent.Connections, &agpl.HTMLClient{
 				ID:           conn.ID,
 				Name:         conn.ID.String(),
-				LastWriteAge: now.Sub(conn.UpdatedAt).Round(time.Second),
-			})
-			data.Nodes = append(data.Nodes, &agpl.HTMLNode{
-				ID:   conn.ID,
-				Node: conn.Node,
+				LastWriteAge: agpl.HTMLDuration(now.Sub(conn.UpdatedAt).Round(time.Second)),
 			})
+			data.Nodes = append(data.Nodes, agpl.NewHTMLNode(now, conn.ID, "", debugNode(conn.Node)))
 		}
 		slices.SortFunc(htmlAgent.Connections, func(a, b *agpl.HTMLClient) int {
 			return slice.Ascending(a.Name, b.Name)
 		})
 
 		data.Agents = append(data.Agents, htmlAgent)
-		data.Nodes = append(data.Nodes, &agpl.HTMLNode{
-			ID: agent.ID,
-			// Name: ??, TODO: get agent names
-			Node: agent.Node,
-		})
+		// TODO: get agent names
+		data.Nodes = append(data.Nodes, agpl.NewHTMLNode(now, agent.ID, "", debugNode(agent.Node)))
 	}
 	slices.SortFunc(data.Agents, func(a, b *agpl.HTMLAgent) int {
 		return slice.Ascending(a.Name, b.Name)
@@ -1464,12 +1647,9 @@ func (c *pgCoord) htmlDebug(ctx context.Context) (agpl.HTMLDebug, error) {
 			agent.Connections = append(agent.Connections, &agpl.HTMLClient{
 				Name:         conn.ID.String(),
 				ID:           conn.ID,
-				LastWriteAge: now.Sub(conn.UpdatedAt).Round(time.Second),
-			})
-			data.Nodes = append(data.Nodes, &agpl.HTMLNode{
-				ID:   conn.ID,
-				Node: conn.Node,
+				LastWriteAge: agpl.HTMLDuration(now.Sub(conn.UpdatedAt).Round(time.Second)),
 			})
+			data.Nodes = append(data.Nodes, agpl.NewHTMLNode(now, conn.ID, "", debugNode(conn.Node)))
 		}
 		slices.SortFunc(agent.Connections, func(a, b *agpl.HTMLClient) int {
 			return slice.Ascending(a.Name, b.Name)
@@ -1483,3 +1663,13 @@ func (c *pgCoord) htmlDebug(ctx context.Context) (agpl.HTMLDebug, error) {
 
 	return data, nil
 }
+
+// debugNode decodes a node stored in the database for the debug page. Nodes
+// that can't be decoded are shown as missing.
+func debugNode(raw json.RawMessage) *agpl.Node {
+	var node agpl.Node
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return nil
+	}
+	return &node
+}
@@ -519,6 +519,83 @@ func TestPGCoordinator_MultiAgent(t *testing.T) {
 	assertEventuallyNoAgents(ctx, t, store, agent1.id)
 }
 
+// TestPGCoordinatorDual_MultiAgentFailover tests a multi agent connection, like the one of the server tailnet, to an
+// agent connected to another coordinator.  When the coordinator of the multi agent goes away, the multi agent
+// connection is closed, and a new one to the remaining coordinator picks up where it left off.
+//
+//	            +---------+
+//	agent1 ---> | coord1  | <--- ma2 (after failover)
+//	            +---------+
+//	            +---------+
+//	            | coord2  | <--- ma1
+//	            +---------+
+func TestPGCoordinatorDual_MultiAgentFailover(t *testing.T) {
+	t.Parallel()
+	if !dbtestutil.WillUsePostgres() {
+		t.Skip("test only with postgres")
+	}
+	store, ps := dbtestutil.NewDB(t)
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitSuperLong)
+	defer cancel()
+	logger := slogtest.Make(t, nil).Leveled(slog.LevelDebug)
+	coord1, err := tailnet.NewPGCoord(ctx, logger, ps, store)
+	require.NoError(t, err)
+	defer coord1.Close()
+	coord2, err := tailnet.NewPGCoord(ctx, logger, ps, store)
+	require.NoError(t, err)
+	defer coord2.Close()
+
+	agent1 := newTestAgent(t, coord1)
+	defer agent1.close()
+	agent1.sendNode(&agpl.Node{PreferredDERP: 1})
+
+	ma1 := coord2.ServeMultiAgent(uuid.New())
+	defer ma1.Close()
+	err = ma1.UpdateSelf(&agpl.Node{PreferredDERP: 3})
+	require.NoError(t, err)
+	err = ma1.SubscribeAgent(agent1.id)
+	require.NoError(t, err)
+	assertMultiAgentEventuallyHasDERPs(ctx, t, ma1, 1)
+	assertEventuallyHasDERPs(ctx, t, agent1, 3)
+	require.False(t, ma1.AgentIsLegacy(agent1.id))
+
+	// closing coord2 closes the multi agent connection, so that it reconnects.
+	err = coord2.Close()
+	require.NoError(t, err)
+	require.Eventually(t, ma1.IsClosed, testutil.WaitShort, testutil.IntervalFast)
+
+	ma2 := coord1.ServeMultiAgent(uuid.New())
+	defer ma2.Close()
+	err = ma2.UpdateSelf(&agpl.Node{PreferredDERP: 4})
+	require.NoError(t, err)
+	err = ma2.SubscribeAgent(agent1.id)
+	require.NoError(t, err)
+	assertMultiAgentEventuallyHasDERPs(ctx, t, ma2, 1)
+	assertEventuallyHasDERPs(ctx, t, agent1, 4)
+
+	agent1.sendNode(&agpl.Node{PreferredDERP: 11})
+	assertMultiAgentEventuallyHasDERPs(ctx, t, ma2, 11)
+
+	err = ma2.UpdateSelf(&agpl.Node{PreferredDERP: 41})
+	require.NoError(t, err)
+	assertEventuallyHasDERPs(ctx, t, agent1, 41)
+
+	// unsubscribing withdraws the client of the agent.
+	err = ma2.UnsubscribeAgent(agent1.id)
+	require.NoError(t, err)
+	assertEventuallyNoClientsForAgent(ctx, t, store, agent1.id)
+
+	err = ma2.Close()
+	require.NoError(t, err)
+	require.True(t, ma2.IsClosed())
+
+	err = agent1.close()
+	require.NoError(t, err)
+	_ = agent1.recvErr(ctx, t)
+	agent1.waitForClose(ctx, t)
+	assertEventuallyNoAgents(ctx, t, store, agent1.id)
+}
+
 func TestPGCoordinator_Unhealthy(t *testing.T) {
 	t.Parallel()
 
@@ -698,6 +775,25 @@ func assertEventuallyHasDERPs(ctx context.Context, t *testing.T, c *testConn, ex
 	}
 }
 
+func assertMultiAgentEventuallyHasDERPs(ctx context.Context, t *testing.T, ma agpl.MultiAgentConn, expected ...int) {
+	t.Helper()
+	for {
+		nodes, ok := ma.NextUpdate(ctx)
+		require.True(t, ok, "multi agent closed or timed out")
+		derps := make([]int, 0, len(nodes))
+		for _, n := range nodes {
+			derps = append(derps, n.PreferredDERP)
+		}
+		slices.Sort(derps)
+		want := slices.Clone(expected)
+		slices.Sort(want)
+		if slices.Equal(derps, want) {
+			return
+		}
+		t.Logf("expected DERPs %v, got %v", want, derps)
+	}
+}
+
 func assertEventuallyNoAgents(ctx context.Context, t *testing.T, store database.Store, agentID uuid.UUID) {
 	assert.Eventually(t, func() bool {
 		agents, err := store.GetTailnetAgents(ctx, agentID)
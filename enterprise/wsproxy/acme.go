@@ -0,0 +1,51 @@
+package wsproxy
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/acmecert"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/wsproxy/wsproxysdk"
+)
+
+// ACMEStore returns a store that keeps the ACME certificate of the proxy on
+// the primary, so all replicas of the proxy share it.
+func (s *Server) ACMEStore() acmecert.Store {
+	return &acmeStore{client: s.SDKClient}
+}
+
+type acmeStore struct {
+	client *wsproxysdk.Client
+}
+
+func (s *acmeStore) Load(ctx context.Context, domain string) ([]byte, error) {
+	certificate, err := s.client.ACMECertificate(ctx, domain)
+	if err != nil {
+		var sdkErr *codersdk.Error
+		if xerrors.As(err, &sdkErr) && sdkErr.StatusCode() == http.StatusNotFound {
+			return nil, acmecert.ErrNotFound
+		}
+		return nil, err
+	}
+	return certificate.Data, nil
+}
+
+func (s *acmeStore) Save(ctx context.Context, domain string, data []byte, notAfter time.Time) error {
+	return s.client.UpdateACMECertificate(ctx, wsproxysdk.ACMECertificate{
+		Domain:   domain,
+		Data:     data,
+		NotAfter: notAfter,
+	})
+}
+
+// Lock doesn't lock anything, as the lock of the primary can't be held across
+// requests. Replicas of the proxy that start at the same time may each issue a
+// certificate, in which case the last one saved is used by all of them after
+// the next check.
+func (s *acmeStore) Lock(_ context.Context, _ string, fn func(store acmecert.Store) error) error {
+	return fn(s)
+}
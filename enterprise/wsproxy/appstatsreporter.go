@@ -1,21 +1,433 @@
 package wsproxy
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/workspaceapps"
-	"github.com/coder/coder/enterprise/wsproxy/wsproxysdk"
+)
+
+const (
+	defaultAppStatsMaxBuffered = 10000
+	defaultAppStatsMinBackoff  = time.Second
+	defaultAppStatsMaxBackoff  = time.Minute
+	// appStatsReportTimeout is how long a single batch has to be accepted by
+	// the primary.
+	appStatsReportTimeout = 15 * time.Second
+
+	// appStatsSpillFile holds stats that didn't fit in memory, appended as
+	// JSON lines.
+	appStatsSpillFile = "app-stats.jsonl"
+	// appStatsSendingFile holds spilled stats that are being sent to the
+	// primary. It is always older than appStatsSpillFile.
+	appStatsSendingFile = "app-stats.sending.jsonl"
 )
 
 var _ workspaceapps.StatsReporter = (*appStatsReporter)(nil)
 
+type appStatsReporterOptions struct {
+	Logger slog.Logger
+	// Report sends a batch of stats to the primary.
+	Report func(ctx context.Context, stats []workspaceapps.StatsReport) error
+	// SpillDir is the directory stats are written to when they don't fit in
+	// memory or the reporter is closed before they could be sent. Spilled
+	// stats are sent when the primary is reachable again, including after a
+	// restart. If empty, stats that don't fit in memory are dropped.
+	SpillDir string
+	// BatchSize is the maximum number of stats sent to the primary at once.
+	BatchSize int
+	// MaxBuffered is the maximum number of stats kept in memory.
+	MaxBuffered int
+	// MinBackoff and MaxBackoff bound the delay between retries while the
+	// primary is unreachable.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// appStatsKey identifies a session in the primary. The primary upserts stats
+// by this key, so only the latest report for each session has to be sent.
+type appStatsKey struct {
+	UserID    uuid.UUID
+	AgentID   uuid.UUID
+	SessionID uuid.UUID
+}
+
+func appStatsKeyOf(stat workspaceapps.StatsReport) appStatsKey {
+	return appStatsKey{
+		UserID:    stat.UserID,
+		AgentID:   stat.AgentID,
+		SessionID: stat.SessionID,
+	}
+}
+
+type bufferedAppStat struct {
+	stat workspaceapps.StatsReport
+	// seq is incremented every time the stat is replaced, so a stat that was
+	// updated while being sent isn't removed from the buffer.
+	seq uint64
+}
+
+// appStatsReporter reports app stats to the primary in the background. Stats
+// are buffered in memory and spilled to disk, and sending is retried with
+// exponential backoff, so stats survive outages of the primary instead of
+// being dropped.
 type appStatsReporter struct {
-	Client *wsproxysdk.Client
+	opts appStatsReporterOptions
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	notify chan struct{}
+
+	mu       sync.Mutex // Protects following.
+	buffered []*bufferedAppStat
+	index    map[appStatsKey]*bufferedAppStat
+	seq      uint64
+
+	// spillMu protects the spill file. It isn't held while sending.
+	spillMu sync.Mutex
+}
+
+func newAppStatsReporter(opts appStatsReporterOptions) *appStatsReporter {
+	if opts.BatchSize == 0 {
+		opts.BatchSize = workspaceapps.DefaultStatsDBReporterBatchSize
+	}
+	if opts.MaxBuffered == 0 {
+		opts.MaxBuffered = defaultAppStatsMaxBuffered
+	}
+	if opts.MinBackoff == 0 {
+		opts.MinBackoff = defaultAppStatsMinBackoff
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = defaultAppStatsMaxBackoff
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &appStatsReporter{
+		opts:   opts,
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+		notify: make(chan struct{}, 1),
+		index:  make(map[appStatsKey]*bufferedAppStat),
+	}
+	go r.start()
+	// Send stats spilled by a previous run.
+	r.wake()
+	return r
+}
+
+// Report buffers the stats to be sent to the primary. It never blocks on the
+// primary and never fails.
+func (r *appStatsReporter) Report(_ context.Context, stats []workspaceapps.StatsReport) error {
+	r.mu.Lock()
+	for _, stat := range stats {
+		r.seq++
+		key := appStatsKeyOf(stat)
+		if existing, ok := r.index[key]; ok {
+			existing.stat = stat
+			existing.seq = r.seq
+			continue
+		}
+		entry := &bufferedAppStat{stat: stat, seq: r.seq}
+		r.index[key] = entry
+		r.buffered = append(r.buffered, entry)
+	}
+	var overflow []workspaceapps.StatsReport
+	if n := len(r.buffered) - r.opts.MaxBuffered; n > 0 {
+		overflow = r.takeLocked(n)
+	}
+	r.mu.Unlock()
+
+	if len(overflow) > 0 {
+		r.spill(overflow)
+	}
+	r.wake()
+	return nil
+}
+
+// Close stops sending stats in the background. Stats that can't be sent
+// before Close returns are spilled to disk.
+func (r *appStatsReporter) Close() error {
+	r.cancel()
+	<-r.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), appStatsReportTimeout)
+	defer cancel()
+	err := r.sendBuffered(ctx)
+	if err != nil {
+		r.opts.Logger.Warn(ctx, "failed to report app stats before close", slog.Error(err))
+	}
+
+	r.mu.Lock()
+	remaining := r.takeLocked(len(r.buffered))
+	r.mu.Unlock()
+	if len(remaining) > 0 {
+		r.spill(remaining)
+	}
+	return nil
+}
+
+func (r *appStatsReporter) wake() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (r *appStatsReporter) start() {
+	defer close(r.done)
+
+	var (
+		backoff = r.opts.MinBackoff
+		retry   *time.Timer
+		retryC  <-chan time.Time
+	)
+	defer func() {
+		if retry != nil {
+			retry.Stop()
+		}
+	}()
+
+	for {
+		// New stats don't skip the backoff while the primary is unreachable.
+		notify := r.notify
+		if retryC != nil {
+			notify = nil
+		}
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-notify:
+		case <-retryC:
+			retryC = nil
+		}
+
+		err := r.send(r.ctx)
+		if err == nil {
+			backoff = r.opts.MinBackoff
+			continue
+		}
+		if r.ctx.Err() != nil {
+			return
+		}
+		r.opts.Logger.Warn(r.ctx, "failed to report app stats, retrying",
+			slog.F("backoff", backoff),
+			slog.Error(err),
+		)
+		retry = time.NewTimer(backoff)
+		retryC = retry.C
+		backoff *= 2
+		if backoff > r.opts.MaxBackoff {
+			backoff = r.opts.MaxBackoff
+		}
+	}
+}
+
+// send sends spilled stats followed by buffered stats. Spilled stats are
+// older, so they must be sent first or the primary would keep stale values.
+func (r *appStatsReporter) send(ctx context.Context) error {
+	err := r.sendSpilled(ctx)
+	if err != nil {
+		return xerrors.Errorf("send spilled stats: %w", err)
+	}
+	err = r.sendBuffered(ctx)
+	if err != nil {
+		return xerrors.Errorf("send buffered stats: %w", err)
+	}
+	return nil
+}
+
+func (r *appStatsReporter) sendBuffered(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		n := len(r.buffered)
+		if n > r.opts.BatchSize {
+			n = r.opts.BatchSize
+		}
+		batch := make([]workspaceapps.StatsReport, 0, n)
+		seqs := make([]uint64, 0, n)
+		for _, entry := range r.buffered[:n] {
+			batch = append(batch, entry.stat)
+			seqs = append(seqs, entry.seq)
+		}
+		r.mu.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+		err := r.report(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		r.mu.Lock()
+		for i, stat := range batch {
+			key := appStatsKeyOf(stat)
+			// Stats that were updated while being sent are sent again.
+			if entry, ok := r.index[key]; ok && entry.seq == seqs[i] {
+				delete(r.index, key)
+			}
+		}
+		kept := r.buffered[:0]
+		for _, entry := range r.buffered {
+			if r.index[appStatsKeyOf(entry.stat)] == entry {
+				kept = append(kept, entry)
+			}
+		}
+		r.buffered = kept
+		r.mu.Unlock()
+	}
+}
+
+// sendSpilled sends stats that were spilled to disk. The spill file is moved
+// aside before sending so new stats can be spilled in the meantime.
+func (r *appStatsReporter) sendSpilled(ctx context.Context) error {
+	if r.opts.SpillDir == "" {
+		return nil
+	}
+	sendingPath := filepath.Join(r.opts.SpillDir, appStatsSendingFile)
+
+	for {
+		stats, err := readAppStats(sendingPath)
+		if xerrors.Is(err, os.ErrNotExist) {
+			r.spillMu.Lock()
+			err = os.Rename(filepath.Join(r.opts.SpillDir, appStatsSpillFile), sendingPath)
+			r.spillMu.Unlock()
+			if xerrors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return xerrors.Errorf("move spill file: %w", err)
+			}
+			continue
+		}
+		if err != nil {
+			return xerrors.Errorf("read spill file: %w", err)
+		}
+
+		for len(stats) > 0 {
+			n := len(stats)
+			if n > r.opts.BatchSize {
+				n = r.opts.BatchSize
+			}
+			err = r.report(ctx, stats[:n])
+			if err != nil {
+				// Keep the stats that weren't sent for the next attempt.
+				if writeErr := writeAppStats(sendingPath, stats, os.O_TRUNC); writeErr != nil {
+					r.opts.Logger.Error(ctx, "failed to rewrite app stats spill file", slog.Error(writeErr))
+				}
+				return err
+			}
+			stats = stats[n:]
+		}
+		err = os.Remove(sendingPath)
+		if err != nil {
+			return xerrors.Errorf("remove spill file: %w", err)
+		}
+	}
 }
 
-func (r *appStatsReporter) Report(ctx context.Context, stats []workspaceapps.StatsReport) error {
-	err := r.Client.ReportAppStats(ctx, wsproxysdk.ReportAppStatsRequest{
-		Stats: stats,
-	})
-	return err
+func (r *appStatsReporter) report(ctx context.Context, stats []workspaceapps.StatsReport) error {
+	ctx, cancel := context.WithTimeout(ctx, appStatsReportTimeout)
+	defer cancel()
+	return r.opts.Report(ctx, stats)
+}
+
+// spill appends the stats to the spill file, or drops them if there is no
+// spill directory.
+func (r *appStatsReporter) spill(stats []workspaceapps.StatsReport) {
+	if r.opts.SpillDir == "" {
+		r.opts.Logger.Warn(r.ctx, "app stats buffer is full, dropping stats", slog.F("count", len(stats)))
+		return
+	}
+
+	r.spillMu.Lock()
+	defer r.spillMu.Unlock()
+	err := os.MkdirAll(r.opts.SpillDir, 0o700)
+	if err == nil {
+		err = writeAppStats(filepath.Join(r.opts.SpillDir, appStatsSpillFile), stats, os.O_APPEND)
+	}
+	if err != nil {
+		r.opts.Logger.Error(r.ctx, "failed to spill app stats, dropping stats",
+			slog.F("count", len(stats)),
+			slog.Error(err),
+		)
+	}
+}
+
+// takeLocked removes and returns the n oldest buffered stats. The caller must
+// hold mu.
+func (r *appStatsReporter) takeLocked(n int) []workspaceapps.StatsReport {
+	stats := make([]workspaceapps.StatsReport, 0, n)
+	for _, entry := range r.buffered[:n] {
+		stats = append(stats, entry.stat)
+		delete(r.index, appStatsKeyOf(entry.stat))
+	}
+	r.buffered = append(r.buffered[:0], r.buffered[n:]...)
+	return stats
+}
+
+func writeAppStats(path string, stats []workspaceapps.StatsReport, flag int) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|flag, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, stat := range stats {
+		err = enc.Encode(stat)
+		if err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	err = w.Flush()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readAppStats reads spilled stats, keeping only the latest report for each
+// session in the order they were first spilled.
+func readAppStats(path string) ([]workspaceapps.StatsReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		stats []workspaceapps.StatsReport
+		index = map[appStatsKey]int{}
+		dec   = json.NewDecoder(bufio.NewReader(f))
+	)
+	for dec.More() {
+		var stat workspaceapps.StatsReport
+		err = dec.Decode(&stat)
+		if err != nil {
+			// A partially written line can be left behind if the proxy
+			// exited while spilling. Keep everything before it.
+			break
+		}
+		key := appStatsKeyOf(stat)
+		if i, ok := index[key]; ok {
+			stats[i] = stat
+			continue
+		}
+		index[key] = len(stats)
+		stats = append(stats, stat)
+	}
+	return stats, nil
 }
@@ -0,0 +1,201 @@
+package wsproxy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/workspaceapps"
+	"github.com/coder/coder/testutil"
+)
+
+// fakePrimary records the stats reported to it and fails while unavailable.
+type fakePrimary struct {
+	mu          sync.Mutex
+	unavailable bool
+	calls       int
+	stats       []workspaceapps.StatsReport
+}
+
+func (p *fakePrimary) Report(_ context.Context, stats []workspaceapps.StatsReport) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.unavailable {
+		return xerrors.New("primary unavailable")
+	}
+	p.stats = append(p.stats, stats...)
+	return nil
+}
+
+func (p *fakePrimary) setUnavailable(unavailable bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.unavailable = unavailable
+}
+
+func (p *fakePrimary) reported() []workspaceapps.StatsReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]workspaceapps.StatsReport(nil), p.stats...)
+}
+
+func (p *fakePrimary) callCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+func newTestStat(requests int) workspaceapps.StatsReport {
+	return workspaceapps.StatsReport{
+		UserID:           uuid.New(),
+		WorkspaceID:      uuid.New(),
+		AgentID:          uuid.New(),
+		AccessMethod:     workspaceapps.AccessMethodPath,
+		SlugOrPort:       "code-server",
+		SessionID:        uuid.New(),
+		SessionStartedAt: time.Now().UTC().Truncate(time.Second),
+		Requests:         requests,
+	}
+}
+
+func TestAppStatsReporter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RetryAndDedup", func(t *testing.T) {
+		t.Parallel()
+
+		primary := &fakePrimary{unavailable: true}
+		reporter := newAppStatsReporter(appStatsReporterOptions{
+			Logger:     slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}),
+			Report:     primary.Report,
+			MinBackoff: time.Millisecond,
+			MaxBackoff: 10 * time.Millisecond,
+		})
+		defer reporter.Close()
+
+		first := newTestStat(1)
+		err := reporter.Report(context.Background(), []workspaceapps.StatsReport{first})
+		require.NoError(t, err)
+
+		// Wait for a few retries while the primary is down.
+		require.Eventually(t, func() bool {
+			return primary.callCount() >= 3
+		}, testutil.WaitShort, testutil.IntervalFast)
+
+		// Updates to the same session replace the buffered stat.
+		updated := first
+		updated.Requests = 5
+		other := newTestStat(2)
+		err = reporter.Report(context.Background(), []workspaceapps.StatsReport{updated, other})
+		require.NoError(t, err)
+
+		primary.setUnavailable(false)
+		require.Eventually(t, func() bool {
+			return len(primary.reported()) == 2
+		}, testutil.WaitShort, testutil.IntervalFast)
+		require.Equal(t, []workspaceapps.StatsReport{updated, other}, primary.reported())
+	})
+
+	t.Run("Batches", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu      sync.Mutex
+			batches [][]workspaceapps.StatsReport
+		)
+		reporter := newAppStatsReporter(appStatsReporterOptions{
+			Logger: slogtest.Make(t, nil),
+			Report: func(_ context.Context, stats []workspaceapps.StatsReport) error {
+				mu.Lock()
+				defer mu.Unlock()
+				batches = append(batches, stats)
+				return nil
+			},
+			BatchSize: 2,
+		})
+
+		stats := []workspaceapps.StatsReport{newTestStat(1), newTestStat(2), newTestStat(3)}
+		err := reporter.Report(context.Background(), stats)
+		require.NoError(t, err)
+		require.NoError(t, reporter.Close())
+
+		mu.Lock()
+		defer mu.Unlock()
+		var got []workspaceapps.StatsReport
+		for _, batch := range batches {
+			require.LessOrEqual(t, len(batch), 2)
+			got = append(got, batch...)
+		}
+		require.Equal(t, stats, got)
+	})
+
+	t.Run("Spill", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		primary := &fakePrimary{unavailable: true}
+		reporter := newAppStatsReporter(appStatsReporterOptions{
+			Logger:      slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}),
+			Report:      primary.Report,
+			SpillDir:    dir,
+			MaxBuffered: 1,
+			MinBackoff:  time.Hour,
+		})
+
+		// Only one stat fits in memory, the rest are spilled to disk.
+		stats := []workspaceapps.StatsReport{newTestStat(1), newTestStat(2), newTestStat(3)}
+		err := reporter.Report(context.Background(), stats)
+		require.NoError(t, err)
+		// Updating a spilled stat spills the update too.
+		updated := stats[0]
+		updated.Requests = 10
+		err = reporter.Report(context.Background(), []workspaceapps.StatsReport{updated})
+		require.NoError(t, err)
+
+		// Stats that couldn't be sent before close are spilled as well, so
+		// they survive a restart.
+		require.NoError(t, reporter.Close())
+		require.Empty(t, primary.reported())
+		require.FileExists(t, filepath.Join(dir, appStatsSpillFile))
+
+		primary.setUnavailable(false)
+		reporter = newAppStatsReporter(appStatsReporterOptions{
+			Logger:   slogtest.Make(t, nil),
+			Report:   primary.Report,
+			SpillDir: dir,
+		})
+		defer reporter.Close()
+
+		// A session can be reported more than once if it was spilled before
+		// and after an update. The primary keeps the latest report.
+		latest := func() map[uuid.UUID]workspaceapps.StatsReport {
+			m := map[uuid.UUID]workspaceapps.StatsReport{}
+			for _, stat := range primary.reported() {
+				m[stat.SessionID] = stat
+			}
+			return m
+		}
+		require.Eventually(t, func() bool {
+			return len(latest()) == 3
+		}, testutil.WaitShort, testutil.IntervalFast)
+		require.Equal(t, map[uuid.UUID]workspaceapps.StatsReport{
+			updated.SessionID:  updated,
+			stats[1].SessionID: stats[1],
+			stats[2].SessionID: stats[2],
+		}, latest())
+		require.Eventually(t, func() bool {
+			_, spillErr := os.Stat(filepath.Join(dir, appStatsSpillFile))
+			_, sendingErr := os.Stat(filepath.Join(dir, appStatsSendingFile))
+			return os.IsNotExist(spillErr) && os.IsNotExist(sendingErr)
+		}, testutil.WaitShort, testutil.IntervalFast)
+	})
+}
@@ -0,0 +1,217 @@
+package wsproxy
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+	"tailscale.com/derp"
+	"tailscale.com/types/key"
+
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/wsproxy/wsproxysdk"
+	"github.com/coder/coder/tailnet"
+)
+
+const (
+	// healthCheckTimeout is how long each check of the health endpoints may
+	// take before it's reported as failed.
+	healthCheckTimeout = 5 * time.Second
+	// readyCheckCacheDuration is how long the results of the readiness checks
+	// that reach out to the primary or the DERP server are reused for. /readyz
+	// is unauthenticated, so it must not be able to trigger them on every
+	// request.
+	readyCheckCacheDuration = 10 * time.Second
+)
+
+// readyCheckResult is a cached result of the readiness checks.
+type readyCheckResult struct {
+	time   time.Time
+	checks []wsproxysdk.HealthCheck
+}
+
+// healthz only checks that the proxy is running, so it can be used as a
+// liveness probe. Problems with the primary or the network must not restart
+// the proxy, they're reported by readyz instead.
+func (s *Server) healthz(rw http.ResponseWriter, r *http.Request) {
+	writeHealthResponse(rw, r, []wsproxysdk.HealthCheck{
+		s.healthCheck(r.Context(), "running", s.runningCheck),
+	})
+}
+
+// readyz checks that the proxy can serve traffic:
+// - It's running and isn't shutting down.
+// - Its last registration with the primary wasn't rejected.
+// - The primary is reachable and isn't a workspace proxy itself.
+// - Its DERP server accepts connections, if DERP is enabled.
+//
+// All but the first are cached for readyCheckCacheDuration.
+func (s *Server) readyz(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	running := s.healthCheck(ctx, "running", s.runningCheck)
+
+	checks, ok := s.cachedReadyChecks(ctx)
+	if !ok {
+		// The client went away before the checks completed.
+		return
+	}
+	writeHealthResponse(rw, r, append([]wsproxysdk.HealthCheck{running}, checks...))
+}
+
+// cachedReadyChecks returns the cached results of the readiness checks, or
+// runs them if the cached results are outdated. Concurrent requests share a
+// single run. It returns false if the context was canceled before the checks
+// completed.
+func (s *Server) cachedReadyChecks(ctx context.Context) ([]wsproxysdk.HealthCheck, bool) {
+	if res := s.readyCheckCache.Load(); res != nil && time.Since(res.time) < readyCheckCacheDuration {
+		return res.checks, true
+	}
+
+	resChan := s.readyCheckGroup.DoChan("", func() ([]wsproxysdk.HealthCheck, error) {
+		// Don't tie the checks to the request, they're shared.
+		checks := s.readyChecks(s.ctx)
+		s.readyCheckCache.Store(&readyCheckResult{time: time.Now(), checks: checks})
+		return checks, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, false
+	case res := <-resChan:
+		return res.Val, true
+	}
+}
+
+// readyChecks runs the readiness checks that depend on the primary and the
+// DERP server.
+func (s *Server) readyChecks(ctx context.Context) []wsproxysdk.HealthCheck {
+	checks := []struct {
+		name string
+		fn   func(ctx context.Context) error
+	}{
+		{name: "token", fn: s.tokenCheck},
+		{name: "primary", fn: s.primaryCheck},
+	}
+	if s.Options.DERPEnabled {
+		checks = append(checks, struct {
+			name string
+			fn   func(ctx context.Context) error
+		}{name: "derp", fn: s.derpCheck})
+	}
+
+	results := make([]wsproxysdk.HealthCheck, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		i, check := i, check
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.healthCheck(ctx, check.name, check.fn)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func (*Server) healthCheck(ctx context.Context, name string, fn func(ctx context.Context) error) wsproxysdk.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	check := wsproxysdk.HealthCheck{Name: name, Healthy: true}
+	err := fn(ctx)
+	if err != nil {
+		check.Healthy = false
+		check.Error = err.Error()
+	}
+	return check
+}
+
+func writeHealthResponse(rw http.ResponseWriter, r *http.Request, checks []wsproxysdk.HealthCheck) {
+	resp := wsproxysdk.HealthResponse{Healthy: true, Checks: checks}
+	for _, check := range checks {
+		if !check.Healthy {
+			resp.Healthy = false
+		}
+	}
+	status := http.StatusOK
+	if !resp.Healthy {
+		status = http.StatusServiceUnavailable
+	}
+	httpapi.Write(r.Context(), rw, status, resp)
+}
+
+func (s *Server) runningCheck(_ context.Context) error {
+	if s.ctx.Err() != nil {
+		return xerrors.New("workspace proxy is shutting down")
+	}
+	return nil
+}
+
+// tokenCheck reports the error of the last attempt to re-register with the
+// primary. Registering is the only request that's made periodically with the
+// proxy token, so it's the first to notice when the token is revoked.
+func (s *Server) tokenCheck(_ context.Context) error {
+	s.registerMu.Lock()
+	err := s.registerErr
+	s.registerMu.Unlock()
+	if err == nil {
+		return nil
+	}
+	var sdkErr *codersdk.Error
+	if xerrors.As(err, &sdkErr) && (sdkErr.StatusCode() == http.StatusUnauthorized || sdkErr.StatusCode() == http.StatusForbidden) {
+		return xerrors.Errorf("proxy token was rejected by the primary: %w", err)
+	}
+	return xerrors.Errorf("last registration with the primary failed: %w", err)
+}
+
+func (s *Server) primaryCheck(ctx context.Context) error {
+	primaryBuild, err := s.SDKClient.SDKClient.BuildInfo(ctx)
+	if err != nil {
+		return xerrors.Errorf("get primary build info: %w", err)
+	}
+	if primaryBuild.WorkspaceProxy {
+		return xerrors.Errorf("dashboard url (%s) is a workspace proxy, must be a primary coderd", s.DashboardURL.String())
+	}
+	return nil
+}
+
+// derpCheck connects a DERP client to the DERP server over an in-memory
+// connection. Connecting through the access URL instead would also check
+// the load balancer in front of the proxy, but proxies often can't reach
+// their own access URL.
+func (s *Server) derpCheck(ctx context.Context) error {
+	serverConn, clientConn := net.Pipe()
+	defer clientConn.Close()
+	deadline, _ := ctx.Deadline()
+	_ = clientConn.SetDeadline(deadline)
+
+	go s.derpServer.Accept(ctx, serverConn, bufio.NewReadWriter(bufio.NewReader(serverConn), bufio.NewWriter(serverConn)), "healthcheck")
+
+	brw := bufio.NewReadWriter(bufio.NewReader(clientConn), bufio.NewWriter(clientConn))
+	client, err := derp.NewClient(key.NewNode(), clientConn, brw, tailnet.Logger(s.Logger.Named("derp_healthcheck")))
+	if err != nil {
+		return xerrors.Errorf("connect to derp server: %w", err)
+	}
+	// The server only sends its info after it accepted the client.
+	msg, err := client.Recv()
+	if err != nil {
+		return xerrors.Errorf("receive from derp server: %w", err)
+	}
+	if _, ok := msg.(derp.ServerInfoMessage); !ok {
+		return xerrors.Errorf("unexpected derp message %T", msg)
+	}
+	return nil
+}
+
+// setRegisterErr records the result of the last registration attempt for
+// tokenCheck.
+func (s *Server) setRegisterErr(err error) {
+	s.registerMu.Lock()
+	defer s.registerMu.Unlock()
+	s.registerErr = err
+}
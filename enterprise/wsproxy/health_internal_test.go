@@ -0,0 +1,53 @@
+package wsproxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/wsproxy/wsproxysdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestReadyzCached(t *testing.T) {
+	t.Parallel()
+
+	var buildInfoCalls atomic.Int64
+	primary := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		buildInfoCalls.Add(1)
+		httpapi.Write(r.Context(), rw, http.StatusOK, codersdk.BuildInfoResponse{})
+	}))
+	t.Cleanup(primary.Close)
+	primaryURL, err := url.Parse(primary.URL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	s := &Server{
+		Options:   &Options{},
+		SDKClient: wsproxysdk.New(primaryURL),
+		ctx:       ctx,
+	}
+
+	for i := 0; i < 3; i++ {
+		rw := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/readyz", nil).WithContext(testutil.Context(t, testutil.WaitShort))
+		s.readyz(rw, r)
+		require.Equal(t, http.StatusOK, rw.Code, rw.Body.String())
+	}
+	require.EqualValues(t, 1, buildInfoCalls.Load(), "primary should only be checked once")
+
+	// The cached checks don't hide that the proxy is shutting down.
+	cancel()
+	rw := httptest.NewRecorder()
+	s.readyz(rw, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rw.Code)
+	require.EqualValues(t, 1, buildInfoCalls.Load())
+}
@@ -0,0 +1,151 @@
+package wsproxy
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/codersdk"
+)
+
+// sessionTokenRotateBefore is how long before its token expires the proxy
+// rotates it.
+const sessionTokenRotateBefore = 24 * time.Hour
+
+// readSessionTokenFile returns the token persisted to the session token file,
+// or an empty string if no token was persisted yet.
+func readSessionTokenFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", xerrors.Errorf("read session token file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// writeSessionTokenFile atomically replaces the session token file, so other
+// replicas reading it never see a partially written token.
+func writeSessionTokenFile(path, token string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return xerrors.Errorf("create session token directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return xerrors.Errorf("create temporary session token file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(token + "\n")
+	if err != nil {
+		_ = tmp.Close()
+		return xerrors.Errorf("write session token file: %w", err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return xerrors.Errorf("close session token file: %w", err)
+	}
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return xerrors.Errorf("replace session token file: %w", err)
+	}
+	return nil
+}
+
+// reloadSessionToken switches to the token in the session token file if
+// another replica of the proxy rotated it.
+func (s *Server) reloadSessionToken() {
+	if s.Options.ProxySessionTokenFile == "" {
+		return
+	}
+	s.sessionTokenMu.Lock()
+	defer s.sessionTokenMu.Unlock()
+	s.reloadSessionTokenLocked()
+}
+
+// reloadSessionTokenLocked is reloadSessionToken for callers that hold
+// sessionTokenMu. It returns true if the token was switched.
+func (s *Server) reloadSessionTokenLocked() bool {
+	if s.sessionTokenUnsaved {
+		// The file still has the token that was replaced, so persist the
+		// current token instead of loading it.
+		err := writeSessionTokenFile(s.Options.ProxySessionTokenFile, s.SDKClient.SessionToken())
+		if err != nil {
+			s.Logger.Error(s.ctx, "persist rotated proxy session token", slog.Error(err))
+			return false
+		}
+		s.sessionTokenUnsaved = false
+	}
+	token, err := readSessionTokenFile(s.Options.ProxySessionTokenFile)
+	if err != nil {
+		s.Logger.Warn(s.ctx, "reload proxy session token", slog.Error(err))
+		return false
+	}
+	if token == "" || token == s.SDKClient.SessionToken() {
+		return false
+	}
+	err = s.SDKClient.SetSessionToken(token)
+	if err != nil {
+		s.Logger.Warn(s.ctx, "set reloaded proxy session token", slog.Error(err))
+		return false
+	}
+	s.Logger.Info(s.ctx, "loaded rotated proxy session token", slog.F("path", s.Options.ProxySessionTokenFile))
+	return true
+}
+
+// rotateSessionToken replaces the session token of the proxy if it expires
+// within sessionTokenRotateBefore, or if it doesn't expire. Rotation is only
+// enabled when the new token can be persisted to the session token file,
+// otherwise the proxy couldn't authenticate after a restart.
+func (s *Server) rotateSessionToken(ctx context.Context, expiresAt *time.Time) {
+	if s.Options.ProxySessionTokenFile == "" {
+		return
+	}
+	if expiresAt != nil && time.Until(*expiresAt) > sessionTokenRotateBefore {
+		return
+	}
+	s.sessionTokenMu.Lock()
+	defer s.sessionTokenMu.Unlock()
+
+	res, err := s.SDKClient.RotateWorkspaceProxyToken(ctx)
+	if err != nil {
+		var sdkErr *codersdk.Error
+		if xerrors.As(err, &sdkErr) && sdkErr.StatusCode() == http.StatusConflict {
+			// Another replica rotated the token. The replaced token only
+			// stays valid for a short while, so switch to the new token right
+			// away instead of waiting for the next registration.
+			if s.reloadSessionTokenLocked() {
+				return
+			}
+			fields := []any{slog.F("path", s.Options.ProxySessionTokenFile)}
+			if expiresAt != nil {
+				fields = append(fields, slog.F("expires_at", *expiresAt))
+			}
+			s.Logger.Warn(ctx, "proxy session token was rotated by another replica, but the session token file doesn't have the new token; replicas must share the file", fields...)
+			return
+		}
+		s.Logger.Warn(ctx, "rotate proxy session token", slog.Error(err))
+		return
+	}
+	// The old token can't be rotated anymore, so the new token is used even
+	// if it can't be persisted. Persisting it is retried before the next
+	// registration.
+	err = writeSessionTokenFile(s.Options.ProxySessionTokenFile, res.ProxyToken)
+	if err != nil {
+		s.Logger.Error(ctx, "persist rotated proxy session token", slog.Error(err))
+		s.sessionTokenUnsaved = true
+	}
+	err = s.SDKClient.SetSessionToken(res.ProxyToken)
+	if err != nil {
+		s.Logger.Error(ctx, "set rotated proxy session token", slog.Error(err))
+		return
+	}
+	s.Logger.Info(ctx, "rotated proxy session token", slog.F("expires_at", res.ExpiresAt))
+}
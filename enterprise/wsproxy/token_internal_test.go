@@ -0,0 +1,54 @@
+package wsproxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/wsproxy/wsproxysdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestRotateSessionTokenConflict(t *testing.T) {
+	t.Parallel()
+
+	// The primary rejects the rotation because another replica rotated the
+	// token first.
+	primary := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		httpapi.Write(r.Context(), rw, http.StatusConflict, codersdk.Response{
+			Message: "Workspace proxy token was already rotated.",
+		})
+	}))
+	t.Cleanup(primary.Close)
+	primaryURL, err := url.Parse(primary.URL)
+	require.NoError(t, err)
+
+	tokenFile := filepath.Join(t.TempDir(), "proxy-token")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	s := &Server{
+		Options:   &Options{ProxySessionTokenFile: tokenFile},
+		Logger:    slogtest.Make(t, nil),
+		SDKClient: wsproxysdk.New(primaryURL),
+		ctx:       ctx,
+	}
+	err = s.SDKClient.SetSessionToken("replaced")
+	require.NoError(t, err)
+	err = writeSessionTokenFile(tokenFile, "rotated")
+	require.NoError(t, err)
+
+	// The replaced token is switched for the one in the file right away,
+	// rather than on the next registration.
+	expiresAt := time.Now().Add(time.Hour)
+	s.rotateSessionToken(testutil.Context(t, testutil.WaitShort), &expiresAt)
+	require.Equal(t, "rotated", s.SDKClient.SessionToken())
+}
@@ -11,6 +11,8 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -22,6 +24,7 @@ import (
 	"tailscale.com/derp"
 	"tailscale.com/derp/derphttp"
 	"tailscale.com/types/key"
+	"tailscale.com/util/singleflight"
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/buildinfo"
@@ -65,9 +68,12 @@ type Options struct {
 	PrometheusRegistry *prometheus.Registry
 	TLSCertificates    []tls.Certificate
 
-	APIRateLimit           int
-	SecureAuthCookie       bool
-	DisablePathApps        bool
+	APIRateLimit     int
+	SecureAuthCookie bool
+	DisablePathApps  bool
+	// StickySessions routes all requests of a browser to a subdomain app
+	// through the same replica of the proxy.
+	StickySessions         bool
 	DERPEnabled            bool
 	DERPServerRelayAddress string
 	// DERPOnly determines whether this proxy only provides DERP and does not
@@ -75,12 +81,23 @@ type Options struct {
 	DERPOnly bool
 
 	ProxySessionToken string
+	// ProxySessionTokenFile is where the proxy persists its session token.
+	// If set, the proxy rotates its token before it expires, and a token in
+	// the file takes precedence over ProxySessionToken. Replicas of the same
+	// proxy must share the file.
+	ProxySessionTokenFile string
 	// AllowAllCors will set all CORs headers to '*'.
 	// By default, CORs is set to accept external requests
 	// from the dashboardURL. This should only be used in development.
 	AllowAllCors bool
 
 	StatsCollectorOptions workspaceapps.StatsCollectorOptions
+	// AppStatsSpillDir is the directory app stats are persisted to while the
+	// primary is unreachable. If empty, stats are only buffered in memory.
+	AppStatsSpillDir string
+	// ServerTailnetPoolOptions configures the agent connection pool of the
+	// server tailnet. It is only used with the single tailnet experiment.
+	ServerTailnetPoolOptions coderd.ServerTailnetPoolOptions
 }
 
 func (o *Options) Validate() error {
@@ -119,7 +136,28 @@ type Server struct {
 	SDKClient *wsproxysdk.Client
 
 	// DERP
-	derpMesh *derpmesh.Mesh
+	derpServer *derp.Server
+	derpMesh   *derpmesh.Mesh
+
+	// registerErr is the error of the last attempt to re-register with the
+	// primary, or nil if it succeeded. It's reported by /readyz.
+	registerMu  sync.Mutex
+	registerErr error
+
+	// readyCheckCache holds the last results of the /readyz checks, and
+	// readyCheckGroup deduplicates concurrent runs of them.
+	readyCheckCache atomic.Pointer[readyCheckResult]
+	readyCheckGroup singleflight.Group[string, []wsproxysdk.HealthCheck]
+
+	// sessionTokenUnsaved is true if the rotated session token couldn't be
+	// persisted to Options.ProxySessionTokenFile yet.
+	sessionTokenMu      sync.Mutex
+	sessionTokenUnsaved bool
+
+	// appStatsReporter is nil if a reporter was provided in the options.
+	appStatsReporter *appStatsReporter
+	// appReplicas is nil unless Options.StickySessions is set.
+	appReplicas *workspaceapps.ReplicaSet
 
 	// Used for graceful shutdown. Required for the dialer.
 	ctx           context.Context
@@ -140,8 +178,21 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 		return nil, err
 	}
 
+	sessionToken := opts.ProxySessionToken
+	if opts.ProxySessionTokenFile != "" {
+		// A token in the file was rotated from the configured one, which
+		// might not be valid anymore.
+		token, err := readSessionTokenFile(opts.ProxySessionTokenFile)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			sessionToken = token
+		}
+	}
+
 	client := wsproxysdk.New(opts.DashboardURL)
-	err := client.SetSessionToken(opts.ProxySessionToken)
+	err := client.SetSessionToken(sessionToken)
 	if err != nil {
 		return nil, xerrors.Errorf("set client token: %w", err)
 	}
@@ -195,6 +246,7 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 		TracerProvider:     opts.Tracing,
 		PrometheusRegistry: opts.PrometheusRegistry,
 		SDKClient:          client,
+		derpServer:         derpServer,
 		derpMesh:           derpmesh.New(opts.Logger.Named("net.derpmesh"), derpServer, meshTLSConfig),
 		ctx:                ctx,
 		cancel:             cancel,
@@ -203,6 +255,12 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 	// Register the workspace proxy with the primary coderd instance and start a
 	// goroutine to periodically re-register.
 	replicaID := uuid.New()
+	if opts.StickySessions {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = meshTLSConfig
+		s.appReplicas = workspaceapps.NewReplicaSet(replicaID)
+		s.appReplicas.SetTransport(transport)
+	}
 	osHostname, err := os.Hostname()
 	if err != nil {
 		return nil, xerrors.Errorf("get OS hostname: %w", err)
@@ -222,6 +280,7 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 		},
 		MutateFn:   s.mutateRegister,
 		CallbackFn: s.handleRegister,
+		ErrorFn:    s.setRegisterErr,
 		FailureFn:  s.handleRegisterFailure,
 	})
 	if err != nil {
@@ -253,10 +312,12 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 			s.DialCoordinator,
 			wsconncache.New(s.DialWorkspaceAgent, 0),
 			s.TracerProvider,
+			opts.ServerTailnetPoolOptions,
 		)
 		if err != nil {
 			return nil, xerrors.Errorf("create server tailnet: %w", err)
 		}
+		opts.PrometheusRegistry.MustRegister(stn)
 		agentProvider = stn
 	} else {
 		agentProvider = &wsconncache.AgentProvider{
@@ -270,7 +331,23 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 		opts.StatsCollectorOptions.Logger = &named
 	}
 	if opts.StatsCollectorOptions.Reporter == nil {
-		opts.StatsCollectorOptions.Reporter = &appStatsReporter{Client: client}
+		s.appStatsReporter = newAppStatsReporter(appStatsReporterOptions{
+			Logger: s.Logger.Named("app_stats_reporter"),
+			Report: func(ctx context.Context, stats []workspaceapps.StatsReport) error {
+				return client.ReportAppStats(ctx, wsproxysdk.ReportAppStatsRequest{
+					Stats: stats,
+				})
+			},
+			SpillDir: opts.AppStatsSpillDir,
+		})
+		opts.StatsCollectorOptions.Reporter = s.appStatsReporter
+	}
+	if opts.StatsCollectorOptions.AccessLogReporter == nil && regResp.AppAccessLogs {
+		opts.StatsCollectorOptions.AccessLogReporter = workspaceapps.AccessLogReporterFunc(func(ctx context.Context, logs []workspaceapps.AccessLog) error {
+			return client.ReportAppStats(ctx, wsproxysdk.ReportAppStatsRequest{
+				AccessLogs: logs,
+			})
+		})
 	}
 
 	s.AppServer = &workspaceapps.Server{
@@ -295,6 +372,7 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 
 		AgentProvider:  agentProvider,
 		StatsCollector: workspaceapps.NewStatsCollector(opts.StatsCollectorOptions),
+		Replicas:       s.appReplicas,
 	}
 
 	derpHandler := derphttp.Handler(derpServer)
@@ -392,7 +470,8 @@ func New(ctx context.Context, opts *Options) (*Server, error) {
 	}
 
 	r.Get("/api/v2/buildinfo", s.buildInfo)
-	r.Get("/healthz", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write([]byte("OK")) })
+	r.Get("/healthz", s.healthz)
+	r.Get("/readyz", s.readyz)
 	// TODO: @emyrk should this be authenticated or debounced?
 	r.Get("/healthz-report", s.healthReport)
 	r.NotFound(func(rw http.ResponseWriter, r *http.Request) {
@@ -432,6 +511,14 @@ func (s *Server) Close() error {
 	if appServerErr != nil {
 		err = multierror.Append(err, appServerErr)
 	}
+	// The app server flushes its last stats on close, so the reporter must be
+	// closed after it.
+	if s.appStatsReporter != nil {
+		reporterErr := s.appStatsReporter.Close()
+		if reporterErr != nil {
+			err = multierror.Append(err, reporterErr)
+		}
+	}
 	agentProviderErr := s.AppServer.AgentProvider.Close()
 	if agentProviderErr != nil {
 		err = multierror.Append(err, agentProviderErr)
@@ -444,17 +531,29 @@ func (s *Server) DialWorkspaceAgent(id uuid.UUID) (*codersdk.WorkspaceAgentConn,
 	return s.SDKClient.DialWorkspaceAgent(s.ctx, id, nil)
 }
 
-func (*Server) mutateRegister(_ *wsproxysdk.RegisterWorkspaceProxyRequest) {
+func (s *Server) mutateRegister(_ *wsproxysdk.RegisterWorkspaceProxyRequest) {
 	// TODO: we should probably ping replicas similarly to the replicasync
 	// package in the primary and update req.ReplicaError accordingly.
+	s.reloadSessionToken()
 }
 
-func (s *Server) handleRegister(_ context.Context, res wsproxysdk.RegisterWorkspaceProxyResponse) error {
+func (s *Server) handleRegister(ctx context.Context, res wsproxysdk.RegisterWorkspaceProxyResponse) error {
 	addresses := make([]string, len(res.SiblingReplicas))
 	for i, replica := range res.SiblingReplicas {
 		addresses[i] = replica.RelayAddress
 	}
 	s.derpMesh.SetAddresses(addresses, false)
+	if s.appReplicas != nil {
+		peers := make(map[uuid.UUID]string, len(res.SiblingReplicas))
+		for _, replica := range res.SiblingReplicas {
+			if replica.Error == "" {
+				peers[replica.ID] = replica.RelayAddress
+			}
+		}
+		s.appReplicas.SetPeers(peers)
+	}
+	s.setRegisterErr(nil)
+	s.rotateSessionToken(ctx, res.TokenExpiresAt)
 
 	return nil
 }
@@ -1,8 +1,13 @@
 package wsproxy_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
@@ -26,6 +31,7 @@ import (
 	"github.com/coder/coder/codersdk/agentsdk"
 	"github.com/coder/coder/enterprise/coderd/coderdenttest"
 	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/enterprise/wsproxy/wsproxysdk"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/testutil"
 )
@@ -565,3 +571,128 @@ func TestWorkspaceProxyWorkspaceApps_SingleTailnet(t *testing.T) {
 		}
 	})
 }
+
+func TestReadyz(t *testing.T) {
+	t.Parallel()
+
+	deploymentValues := coderdtest.DeploymentValues(t)
+	deploymentValues.Experiments = []string{
+		string(codersdk.ExperimentMoons),
+		"*",
+	}
+
+	client, closer, api, _ := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			DeploymentValues: deploymentValues,
+		},
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureWorkspaceProxy: 1,
+			},
+		},
+	})
+	t.Cleanup(func() {
+		_ = closer.Close()
+	})
+
+	t.Run("Ready", func(t *testing.T) {
+		t.Parallel()
+
+		proxy := coderdenttest.NewWorkspaceProxy(t, api, client, &coderdenttest.ProxyOptions{
+			Name: "ready-proxy",
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+		res, err := wsproxysdk.Readiness(ctx, http.DefaultClient, proxy.Options.AccessURL)
+		require.NoError(t, err)
+		require.True(t, res.Healthy, "checks: %+v", res.Checks)
+
+		names := make([]string, 0, len(res.Checks))
+		for _, check := range res.Checks {
+			names = append(names, check.Name)
+			require.Empty(t, check.Error)
+		}
+		require.Equal(t, []string{"running", "token", "primary", "derp"}, names)
+	})
+
+	t.Run("DERPDisabled", func(t *testing.T) {
+		t.Parallel()
+
+		proxy := coderdenttest.NewWorkspaceProxy(t, api, client, &coderdenttest.ProxyOptions{
+			Name:         "no-derp-proxy",
+			DerpDisabled: true,
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+		res, err := wsproxysdk.Readiness(ctx, http.DefaultClient, proxy.Options.AccessURL)
+		require.NoError(t, err)
+		require.True(t, res.Healthy, "checks: %+v", res.Checks)
+		for _, check := range res.Checks {
+			require.NotEqual(t, "derp", check.Name)
+		}
+	})
+
+	t.Run("Healthz", func(t *testing.T) {
+		t.Parallel()
+
+		proxy := coderdenttest.NewWorkspaceProxy(t, api, client, &coderdenttest.ProxyOptions{
+			Name: "healthz-proxy",
+		})
+		ctx := testutil.Context(t, testutil.WaitLong)
+		healthURL, err := proxy.Options.AccessURL.Parse("/healthz")
+		require.NoError(t, err)
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL.String(), nil)
+		require.NoError(t, err)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var health wsproxysdk.HealthResponse
+		err = json.NewDecoder(res.Body).Decode(&health)
+		require.NoError(t, err)
+		require.True(t, health.Healthy)
+		require.Len(t, health.Checks, 1)
+		require.Equal(t, "running", health.Checks[0].Name)
+	})
+}
+
+func TestSessionTokenRotation(t *testing.T) {
+	t.Parallel()
+
+	deploymentValues := coderdtest.DeploymentValues(t)
+	deploymentValues.Experiments = []string{
+		string(codersdk.ExperimentMoons),
+		"*",
+	}
+
+	client, closer, api, _ := coderdenttest.NewWithAPI(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			DeploymentValues: deploymentValues,
+		},
+		LicenseOptions: &coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureWorkspaceProxy: 1,
+			},
+		},
+	})
+	t.Cleanup(func() {
+		_ = closer.Close()
+	})
+
+	// The token created with the proxy doesn't expire, so it's rotated as
+	// soon as the proxy registers.
+	tokenFile := filepath.Join(t.TempDir(), "proxy-token")
+	proxy := coderdenttest.NewWorkspaceProxy(t, api, client, &coderdenttest.ProxyOptions{
+		Name:                  "rotating-proxy",
+		ProxySessionTokenFile: tokenFile,
+	})
+	data, err := os.ReadFile(tokenFile)
+	require.NoError(t, err)
+	token := strings.TrimSpace(string(data))
+	require.NotEqual(t, proxy.Options.ProxySessionToken, token)
+	require.Equal(t, token, proxy.SDKClient.SessionToken())
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	res, err := wsproxysdk.Readiness(ctx, http.DefaultClient, proxy.Options.AccessURL)
+	require.NoError(t, err)
+	require.True(t, res.Healthy, "checks: %+v", res.Checks)
+}
@@ -154,6 +154,9 @@ func (c *Client) IssueSignedAppTokenHTML(ctx context.Context, rw http.ResponseWr
 
 type ReportAppStatsRequest struct {
 	Stats []workspaceapps.StatsReport `json:"stats"`
+	// AccessLogs are only sent if the primary enabled access logs, see
+	// RegisterWorkspaceProxyResponse.AppAccessLogs.
+	AccessLogs []workspaceapps.AccessLog `json:"access_logs,omitempty"`
 }
 
 // ReportAppStats reports the given app stats to the primary coder server.
@@ -212,6 +215,12 @@ type RegisterWorkspaceProxyResponse struct {
 	// SiblingReplicas is a list of all other replicas of the proxy that have
 	// not timed out.
 	SiblingReplicas []codersdk.Replica `json:"sibling_replicas"`
+	// TokenExpiresAt is when the token the proxy registered with expires. It's
+	// nil for tokens that don't expire, e.g. tokens created by users.
+	TokenExpiresAt *time.Time `json:"token_expires_at,omitempty" format:"date-time"`
+	// AppAccessLogs is true if the proxy should report the access logs of
+	// workspace app requests.
+	AppAccessLogs bool `json:"app_access_logs"`
 }
 
 func (c *Client) RegisterWorkspaceProxy(ctx context.Context, req RegisterWorkspaceProxyRequest) (RegisterWorkspaceProxyResponse, error) {
@@ -254,6 +263,80 @@ func (c *Client) DeregisterWorkspaceProxy(ctx context.Context, req DeregisterWor
 	return nil
 }
 
+type RotateWorkspaceProxyTokenResponse struct {
+	// ProxyToken replaces the token the request was made with. The replaced
+	// token stays valid for a while, so all replicas of the proxy can switch
+	// to the new one.
+	ProxyToken string    `json:"proxy_token"`
+	ExpiresAt  time.Time `json:"expires_at" format:"date-time"`
+}
+
+// RotateWorkspaceProxyToken replaces the token of the proxy with a new one
+// that expires. Only the current token of the proxy can be rotated, rotating
+// a token that was already replaced fails with http.StatusConflict.
+func (c *Client) RotateWorkspaceProxyToken(ctx context.Context) (RotateWorkspaceProxyTokenResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost,
+		"/api/v2/workspaceproxies/me/rotate-token",
+		nil,
+	)
+	if err != nil {
+		return RotateWorkspaceProxyTokenResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return RotateWorkspaceProxyTokenResponse{}, codersdk.ReadBodyAsError(res)
+	}
+	var resp RotateWorkspaceProxyTokenResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// ACMECertificate is the certificate of the wildcard hostname of the proxy,
+// issued over ACME. Data is encrypted by the proxy, the primary only stores it.
+type ACMECertificate struct {
+	Domain   string    `json:"domain"`
+	Data     []byte    `json:"data"`
+	NotAfter time.Time `json:"not_after" format:"date-time"`
+}
+
+// ACMECertificate returns the certificate stored for the wildcard hostname of
+// the proxy. It fails with http.StatusNotFound if none was stored yet.
+func (c *Client) ACMECertificate(ctx context.Context, domain string) (ACMECertificate, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		"/api/v2/workspaceproxies/me/acme-certificate",
+		nil,
+		codersdk.WithQueryParam("domain", domain),
+	)
+	if err != nil {
+		return ACMECertificate{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return ACMECertificate{}, codersdk.ReadBodyAsError(res)
+	}
+	var resp ACMECertificate
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// UpdateACMECertificate stores the certificate of the wildcard hostname of the
+// proxy, so all replicas of the proxy can serve it.
+func (c *Client) UpdateACMECertificate(ctx context.Context, req ACMECertificate) error {
+	res, err := c.Request(ctx, http.MethodPut,
+		"/api/v2/workspaceproxies/me/acme-certificate",
+		req,
+	)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusNoContent {
+		return codersdk.ReadBodyAsError(res)
+	}
+	return nil
+}
+
 type RegisterWorkspaceProxyLoopOpts struct {
 	Logger  slog.Logger
 	Request RegisterWorkspaceProxyRequest
@@ -278,6 +361,10 @@ type RegisterWorkspaceProxyLoopOpts struct {
 	// the callback returns an error, the loop will stop immediately and the
 	// error will be returned to the FailureFn.
 	CallbackFn func(ctx context.Context, res RegisterWorkspaceProxyResponse) error
+	// ErrorFn is called with the error of each failed registration attempt,
+	// except the first. It's called before the failure counts towards
+	// MaxFailureCount, so it's also called for the last attempt.
+	ErrorFn func(err error)
 	// FailureFn is called with the last error returned from the server if the
 	// context is canceled, registration fails for more than MaxFailureCount,
 	// or if any permanent values in the response change.
@@ -313,6 +400,9 @@ func (c *Client) RegisterWorkspaceProxyLoop(ctx context.Context, opts RegisterWo
 			return nil
 		}
 	}
+	if opts.ErrorFn == nil {
+		opts.ErrorFn = func(_ error) {}
+	}
 
 	failureFn := func(err error) {
 		// We have to use background context here because the original context
@@ -374,6 +464,7 @@ func (c *Client) RegisterWorkspaceProxyLoop(ctx context.Context, opts RegisterWo
 					slog.F("failed_attempts", failedAttempts),
 					slog.Error(err),
 				)
+				opts.ErrorFn(err)
 
 				if failedAttempts > opts.MaxFailureCount {
 					failureFn(xerrors.Errorf("exceeded re-registration failure count of %d: last error: %w", opts.MaxFailureCount, err))
@@ -610,3 +701,45 @@ func (c *Client) AgentIsLegacy(ctx context.Context, agentID uuid.UUID) (AgentIsL
 	var resp AgentIsLegacyResponse
 	return resp, json.NewDecoder(res.Body).Decode(&resp)
 }
+
+// HealthCheck is the result of one of the checks run by the health endpoints
+// of a workspace proxy.
+type HealthCheck struct {
+	Name    string `json:"name" table:"name,default_sort"`
+	Healthy bool   `json:"healthy" table:"healthy"`
+	Error   string `json:"error,omitempty" table:"error"`
+}
+
+// HealthResponse is returned by the /healthz and /readyz endpoints of a
+// workspace proxy. /healthz only checks the proxy itself, while /readyz also
+// checks that it can serve traffic: its token is accepted by the primary, the
+// primary is reachable and its DERP server accepts connections.
+type HealthResponse struct {
+	Healthy bool          `json:"healthy"`
+	Checks  []HealthCheck `json:"checks"`
+}
+
+// Readiness returns the response of the /readyz endpoint of the workspace
+// proxy at proxyURL. Proxies that aren't ready respond with
+// http.StatusServiceUnavailable, which isn't treated as an error.
+func Readiness(ctx context.Context, httpClient *http.Client, proxyURL *url.URL) (HealthResponse, error) {
+	readyURL, err := proxyURL.Parse("/readyz")
+	if err != nil {
+		return HealthResponse{}, xerrors.Errorf("parse url: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, readyURL.String(), nil)
+	if err != nil {
+		return HealthResponse{}, xerrors.Errorf("create request: %w", err)
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return HealthResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusServiceUnavailable {
+		return HealthResponse{}, codersdk.ReadBodyAsError(res)
+	}
+	var resp HealthResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
@@ -185,6 +185,7 @@ func (e *executor) init(ctx, killCtx context.Context, logr logSink) error {
 	e.mut.Lock()
 	defer e.mut.Unlock()
 
+	logr = &stageLogSink{sink: logr, stage: proto.Stage_INIT}
 	outWriter, doneOut := logWriter(logr, proto.LogLevel_DEBUG)
 	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
 	defer func() {
@@ -227,7 +228,8 @@ func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr l
 		args = append(args, "-var", variable)
 	}
 
-	outWriter, doneOut := provisionLogWriter(logr)
+	logr = &stageLogSink{sink: logr, stage: proto.Stage_PLAN}
+	outWriter, doneOut := provisionLogWriter(logr, &provisionProgress{stage: proto.Stage_PLAN})
 	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
 	defer func() {
 		_ = outWriter.Close()
@@ -240,7 +242,7 @@ func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr l
 	if err != nil {
 		return nil, xerrors.Errorf("terraform plan: %w", err)
 	}
-	state, err := e.planResources(ctx, killCtx, planfilePath)
+	state, plan, err := e.planResources(ctx, killCtx, planfilePath)
 	if err != nil {
 		return nil, err
 	}
@@ -255,24 +257,25 @@ func (e *executor) plan(ctx, killCtx context.Context, env, vars []string, logr l
 				Resources:        state.Resources,
 				GitAuthProviders: state.GitAuthProviders,
 				Plan:             planFileByt,
+				PlannedChanges:   plannedChanges(plan),
 			},
 		},
 	}, nil
 }
 
 // planResources must only be called while the lock is held.
-func (e *executor) planResources(ctx, killCtx context.Context, planfilePath string) (*State, error) {
+func (e *executor) planResources(ctx, killCtx context.Context, planfilePath string) (*State, *tfjson.Plan, error) {
 	ctx, span := e.server.startTrace(ctx, tracing.FuncName())
 	defer span.End()
 
 	plan, err := e.showPlan(ctx, killCtx, planfilePath)
 	if err != nil {
-		return nil, xerrors.Errorf("show terraform plan file: %w", err)
+		return nil, nil, xerrors.Errorf("show terraform plan file: %w", err)
 	}
 
 	rawGraph, err := e.graph(ctx, killCtx)
 	if err != nil {
-		return nil, xerrors.Errorf("graph: %w", err)
+		return nil, nil, xerrors.Errorf("graph: %w", err)
 	}
 	modules := []*tfjson.StateModule{}
 	if plan.PriorState != nil {
@@ -282,9 +285,9 @@ func (e *executor) planResources(ctx, killCtx context.Context, planfilePath stri
 
 	state, err := ConvertState(modules, rawGraph)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return state, nil
+	return state, plan, nil
 }
 
 // showPlan must only be called while the lock is held.
@@ -329,6 +332,7 @@ func (e *executor) graph(ctx, killCtx context.Context) (string, error) {
 func (e *executor) apply(
 	ctx, killCtx context.Context,
 	plan []byte,
+	plannedChanges int32,
 	env []string,
 	logr logSink,
 ) (*proto.Provision_Response, error) {
@@ -348,6 +352,9 @@ func (e *executor) apply(
 	}
 	defer os.Remove(planFile.Name())
 
+	// The total lets clients show how many resources are left to apply.
+	progress := &provisionProgress{stage: proto.Stage_APPLY, total: plannedChanges}
+
 	args := []string{
 		"apply",
 		"-no-color",
@@ -357,7 +364,8 @@ func (e *executor) apply(
 		planFile.Name(),
 	}
 
-	outWriter, doneOut := provisionLogWriter(logr)
+	logr = &stageLogSink{sink: logr, stage: proto.Stage_APPLY}
+	outWriter, doneOut := provisionLogWriter(logr, progress)
 	errWriter, doneErr := logWriter(logr, proto.LogLevel_ERROR)
 	defer func() {
 		_ = outWriter.Close()
@@ -521,14 +529,14 @@ func readAndLog(sink logSink, r io.Reader, done chan<- any, level proto.LogLevel
 // provisionLogWriter creates a WriteCloser that will log each JSON formatted terraform log.  The WriteCloser must be
 // closed by the caller to end logging, after which the returned channel will be closed to indicate that logging of the
 // written data has finished.  Failure to close the WriteCloser will leak a goroutine.
-func provisionLogWriter(sink logSink) (io.WriteCloser, <-chan any) {
+func provisionLogWriter(sink logSink, progress *provisionProgress) (io.WriteCloser, <-chan any) {
 	r, w := io.Pipe()
 	done := make(chan any)
-	go provisionReadAndLog(sink, r, done)
+	go provisionReadAndLog(sink, r, done, progress)
 	return w, done
 }
 
-func provisionReadAndLog(sink logSink, r io.Reader, done chan<- any) {
+func provisionReadAndLog(sink logSink, r io.Reader, done chan<- any, progress *provisionProgress) {
 	defer close(done)
 	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
@@ -559,7 +567,7 @@ func provisionReadAndLog(sink logSink, r io.Reader, done chan<- any) {
 		}
 
 		logLevel := convertTerraformLogLevel(log.Level, sink)
-		sink.Log(&proto.Log{Level: logLevel, Output: log.Message})
+		sink.Log(&proto.Log{Level: logLevel, Output: log.Message, Progress: progress.update(log)})
 
 		// If the diagnostic is provided, let's provide a bit more info!
 		if log.Diagnostic == nil {
@@ -596,8 +604,120 @@ func convertTerraformLogLevel(logLevel string, sink logSink) proto.LogLevel {
 type terraformProvisionLog struct {
 	Level   string `json:"@level"`
 	Message string `json:"@message"`
+	Type    string `json:"type"`
 
 	Diagnostic *tfjson.Diagnostic `json:"diagnostic,omitempty"`
+	// Change is set by "planned_change" logs.
+	Change *terraformResourceChange `json:"change,omitempty"`
+	// Hook is set by "apply_*" logs.
+	Hook *terraformResourceChange `json:"hook,omitempty"`
+	// Changes is set by "change_summary" logs.
+	Changes *terraformChangeSummary `json:"changes,omitempty"`
+}
+
+type terraformResourceChange struct {
+	Resource struct {
+		Addr string `json:"addr"`
+	} `json:"resource"`
+	Action string `json:"action"`
+}
+
+type terraformChangeSummary struct {
+	Add    int32 `json:"add"`
+	Change int32 `json:"change"`
+	Remove int32 `json:"remove"`
+}
+
+// provisionProgress counts the resources that terraform has planned or
+// applied in a stage from its JSON logs.
+type provisionProgress struct {
+	stage     proto.Stage
+	completed int32
+	// total is zero until it's known.
+	total int32
+}
+
+// update returns the progress to attach to the log, or nil if the log isn't
+// about a resource.
+func (p *provisionProgress) update(log terraformProvisionLog) *proto.Progress {
+	var resource string
+	switch log.Type {
+	case "planned_change":
+		// Data sources are read while planning, they aren't changes.
+		if log.Change == nil || log.Change.Action == "read" {
+			return nil
+		}
+		p.completed++
+		resource = log.Change.Resource.Addr
+	case "change_summary":
+		if log.Changes == nil || p.stage != proto.Stage_PLAN {
+			return nil
+		}
+		p.total = log.Changes.Add + log.Changes.Change + log.Changes.Remove
+		p.completed = p.total
+	case "apply_start", "apply_errored":
+		if log.Hook == nil {
+			return nil
+		}
+		resource = log.Hook.Resource.Addr
+	case "apply_complete":
+		if log.Hook == nil {
+			return nil
+		}
+		p.completed++
+		if p.total > 0 && p.completed > p.total {
+			p.total = p.completed
+		}
+		resource = log.Hook.Resource.Addr
+	default:
+		return nil
+	}
+	return &proto.Progress{
+		Stage:     p.stage,
+		Resource:  resource,
+		Completed: p.completed,
+		Total:     p.total,
+	}
+}
+
+// plannedChanges returns the number of resource changes that applying the
+// plan makes, counted the same way as the change summary of terraform.
+func plannedChanges(plan *tfjson.Plan) int32 {
+	var changes int32
+	for _, resourceChange := range plan.ResourceChanges {
+		if resourceChange.Change == nil {
+			continue
+		}
+		for _, action := range resourceChange.Change.Actions {
+			switch action {
+			case tfjson.ActionCreate, tfjson.ActionUpdate, tfjson.ActionDelete:
+				changes++
+			}
+		}
+	}
+	return changes
+}
+
+// stageLogSink attaches the stage to the first log of the stage, unless it
+// reports progress already. Clients keep the stage until it changes.
+type stageLogSink struct {
+	sink  logSink
+	stage proto.Stage
+
+	mu   sync.Mutex
+	sent bool
+}
+
+func (s *stageLogSink) Log(l *proto.Log) {
+	s.mu.Lock()
+	if l.Progress != nil {
+		s.sent = true
+	} else if !s.sent {
+		l.Progress = &proto.Progress{Stage: s.stage}
+		s.sent = true
+	}
+	s.mu.Unlock()
+	s.sink.Log(l)
 }
 
 // syncWriter wraps an io.Writer in a sync.Mutex.
@@ -3,6 +3,7 @@ package terraform
 import (
 	"testing"
 
+	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/provisionersdk/proto"
@@ -41,3 +42,102 @@ From standing in the English rain`))
 	}
 	require.Equal(t, expected, logr.logs)
 }
+
+func TestProvisionLogWriter_Progress(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Plan", func(t *testing.T) {
+		t.Parallel()
+
+		logr := &mockLogger{}
+		writer, doneLogging := provisionLogWriter(logr, &provisionProgress{stage: proto.Stage_PLAN})
+		_, err := writer.Write([]byte(`{"@level":"info","@message":"Terraform 1.4.6","type":"version"}
+{"@level":"info","@message":"data.coder_workspace.me: Refreshing...","type":"refresh_start"}
+{"@level":"info","@message":"data.coder_workspace.me: Plan to read","type":"planned_change","change":{"resource":{"addr":"data.coder_workspace.me"},"action":"read"}}
+{"@level":"info","@message":"coder_agent.main: Plan to create","type":"planned_change","change":{"resource":{"addr":"coder_agent.main"},"action":"create"}}
+{"@level":"info","@message":"null_resource.dev: Plan to create","type":"planned_change","change":{"resource":{"addr":"null_resource.dev"},"action":"create"}}
+{"@level":"info","@message":"Plan: 2 to add, 0 to change, 0 to destroy.","type":"change_summary","changes":{"add":2,"change":0,"remove":0,"operation":"plan"}}
+`))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		<-doneLogging
+
+		progress := make([]*proto.Progress, 0, len(logr.logs))
+		for _, log := range logr.logs {
+			progress = append(progress, log.Progress)
+		}
+		require.Equal(t, []*proto.Progress{
+			nil,
+			nil,
+			nil,
+			{Stage: proto.Stage_PLAN, Resource: "coder_agent.main", Completed: 1},
+			{Stage: proto.Stage_PLAN, Resource: "null_resource.dev", Completed: 2},
+			{Stage: proto.Stage_PLAN, Completed: 2, Total: 2},
+		}, progress)
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		t.Parallel()
+
+		logr := &mockLogger{}
+		writer, doneLogging := provisionLogWriter(logr, &provisionProgress{stage: proto.Stage_APPLY, total: 2})
+		_, err := writer.Write([]byte(`{"@level":"info","@message":"coder_agent.main: Creating...","type":"apply_start","hook":{"resource":{"addr":"coder_agent.main"},"action":"create"}}
+{"@level":"info","@message":"coder_agent.main: Creation complete after 0s","type":"apply_complete","hook":{"resource":{"addr":"coder_agent.main"},"action":"create"}}
+{"@level":"info","@message":"null_resource.dev: Creating...","type":"apply_start","hook":{"resource":{"addr":"null_resource.dev"},"action":"create"}}
+{"@level":"info","@message":"null_resource.dev: Creation complete after 0s","type":"apply_complete","hook":{"resource":{"addr":"null_resource.dev"},"action":"create"}}
+{"@level":"info","@message":"Apply complete! Resources: 2 added, 0 changed, 0 destroyed.","type":"change_summary","changes":{"add":2,"change":0,"remove":0,"operation":"apply"}}
+`))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		<-doneLogging
+
+		progress := make([]*proto.Progress, 0, len(logr.logs))
+		for _, log := range logr.logs {
+			progress = append(progress, log.Progress)
+		}
+		require.Equal(t, []*proto.Progress{
+			{Stage: proto.Stage_APPLY, Resource: "coder_agent.main", Total: 2},
+			{Stage: proto.Stage_APPLY, Resource: "coder_agent.main", Completed: 1, Total: 2},
+			{Stage: proto.Stage_APPLY, Resource: "null_resource.dev", Completed: 1, Total: 2},
+			{Stage: proto.Stage_APPLY, Resource: "null_resource.dev", Completed: 2, Total: 2},
+			nil,
+		}, progress)
+	})
+
+	t.Run("Stage", func(t *testing.T) {
+		t.Parallel()
+
+		// The first log of the stage reports the stage, even if it isn't
+		// about a resource.
+		logr := &mockLogger{}
+		writer, doneLogging := logWriter(&stageLogSink{sink: logr, stage: proto.Stage_INIT}, proto.LogLevel_DEBUG)
+		_, err := writer.Write([]byte("Initializing the backend...\nInitializing provider plugins...\n"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		<-doneLogging
+
+		require.Equal(t, []*proto.Log{{
+			Level:    proto.LogLevel_DEBUG,
+			Output:   "Initializing the backend...",
+			Progress: &proto.Progress{Stage: proto.Stage_INIT},
+		}, {
+			Level:  proto.LogLevel_DEBUG,
+			Output: "Initializing provider plugins...",
+		}}, logr.logs)
+	})
+}
+
+func TestPlannedChanges(t *testing.T) {
+	t.Parallel()
+
+	plan := &tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			{Address: "coder_agent.main", Change: &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionCreate}}},
+			// Replacing a resource deletes and creates it.
+			{Address: "null_resource.dev", Change: &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionDelete, tfjson.ActionCreate}}},
+			{Address: "null_resource.other", Change: &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionNoop}}},
+			{Address: "data.coder_workspace.me", Change: &tfjson.Change{Actions: tfjson.Actions{tfjson.ActionRead}}},
+		},
+	}
+	require.EqualValues(t, 3, plannedChanges(plan))
+}
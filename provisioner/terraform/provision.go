@@ -174,7 +174,7 @@ func (s *server) Provision(stream proto.DRPCProvisioner_ProvisionStream) error {
 	}
 	// Must be apply
 	resp, err = e.apply(
-		ctx, killCtx, applyRequest.Plan, env, sink,
+		ctx, killCtx, applyRequest.Plan, applyRequest.PlannedChanges, env, sink,
 	)
 	if err != nil {
 		errorMessage := err.Error()
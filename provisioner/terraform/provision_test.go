@@ -101,6 +101,7 @@ func TestProvision_Cancel(t *testing.T) {
 		mode          string
 		startSequence []string
 		wantLog       []string
+		wantState     []byte
 	}{
 		{
 			name:          "Cancel init",
@@ -113,6 +114,7 @@ func TestProvision_Cancel(t *testing.T) {
 			mode:          "apply",
 			startSequence: []string{"init", "apply_start"},
 			wantLog:       []string{"interrupt", "exit"},
+			wantState:     []byte("partial_state\n"),
 		},
 	}
 	for _, tt := range tests {
@@ -177,6 +179,9 @@ func TestProvision_Cancel(t *testing.T) {
 				}
 				if c := msg.GetComplete(); c != nil {
 					require.Contains(t, c.Error, "exit status 1")
+					// The state of a canceled apply is kept, so the build
+					// can be resumed.
+					require.Equal(t, tt.wantState, c.State)
 					break
 				}
 			}
@@ -44,7 +44,9 @@ apply)
 	sleep_pid=$!
 
 	trap 'json_print exit; kill -9 $sleep_pid 2>/dev/null' EXIT
-	trap 'json_print interrupt; exit 1' INT
+	# Terraform saves the state of the resources it applied so far when
+	# it's interrupted.
+	trap 'json_print interrupt; echo partial_state >terraform.tfstate; exit 1' INT
 	trap 'json_print terminate; exit 2' TERM
 
 	json_print apply_start
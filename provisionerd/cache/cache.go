@@ -0,0 +1,612 @@
+// Package cache implements the Terraform provider and module cache of
+// provisioner daemons.
+//
+// After a job completes, the providers and modules "terraform init"
+// installed in the work directory are stored in the cache, keyed by the hash
+// of the template source archive. The next job of the same template source
+// restores them before running Terraform, so they aren't downloaded again.
+//
+// Files are stored once by the hash of their content, so providers shared by
+// many templates or template versions only take up space once. Providers that
+// Terraform links from its plugin cache directory (TF_PLUGIN_CACHE_DIR) are
+// left to it, since it keeps them already. The least recently used entries
+// are evicted when the cache grows over its maximum size.
+//
+// Entries expire after their maximum age, so modules with floating sources,
+// e.g. Git branches or registry version constraints, pick up new releases.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+)
+
+// DefaultMaxSize is the default maximum size of the cache in bytes.
+const DefaultMaxSize int64 = 10 << 30
+
+// DefaultMaxAge is the default time after which an entry is downloaded again.
+const DefaultMaxAge = time.Hour
+
+// Paths are the files and directories of the work directory that are cached.
+// Terraform installs providers and modules in them.
+var Paths = []string{".terraform", ".terraform.lock.hcl"}
+
+const indexFile = "index.json"
+
+// Options configures a cache.
+type Options struct {
+	// Filesystem must be the filesystem of the work directories of jobs.
+	Filesystem afero.Fs
+	Logger     slog.Logger
+	// Directory must not be used by multiple processes at once.
+	Directory string
+	// MaxSize is the maximum size of the cache in bytes. Defaults to
+	// DefaultMaxSize.
+	MaxSize int64
+	// MaxAge is how long an entry is restored after it was stored. Defaults
+	// to DefaultMaxAge.
+	MaxAge time.Duration
+}
+
+// Stats are the statistics of a cache. Hits and misses are counted since
+// the cache was opened.
+type Stats struct {
+	Entries      int32
+	SizeBytes    int64
+	MaxSizeBytes int64
+	Hits         int64
+	Misses       int64
+}
+
+type entry struct {
+	Files     []file    `json:"files"`
+	CreatedAt time.Time `json:"created_at"`
+	LastUsed  time.Time `json:"last_used"`
+}
+
+// size returns the size of the distinct files of the entry.
+func (e *entry) size() int64 {
+	var size int64
+	hashes := map[string]struct{}{}
+	for _, f := range e.Files {
+		if _, ok := hashes[f.Hash]; ok {
+			continue
+		}
+		hashes[f.Hash] = struct{}{}
+		size += f.Size
+	}
+	return size
+}
+
+type file struct {
+	// Path is relative to the work directory, separated by slashes.
+	Path string      `json:"path"`
+	Mode fs.FileMode `json:"mode"`
+	Hash string      `json:"hash"`
+	Size int64       `json:"size"`
+}
+
+type blob struct {
+	size int64
+	refs int
+}
+
+// keyLock serializes the use of a key. It's removed once nobody waits for it.
+type keyLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// Cache is safe for concurrent use by the provisioner daemons of a process.
+// Files are copied without holding the lock of the cache, so jobs of other
+// templates don't wait for each other.
+type Cache struct {
+	fs      afero.Fs
+	logger  slog.Logger
+	dir     string
+	maxSize int64
+	maxAge  time.Duration
+
+	mutex    sync.Mutex
+	keyLocks map[string]*keyLock
+	entries  map[string]*entry
+	blobs    map[string]*blob
+	size     int64
+	hits     int64
+	misses   int64
+}
+
+// Key returns the cache key of a job's template source archive.
+func Key(provisioner string, archive []byte) string {
+	hash := sha256.New()
+	_, _ = hash.Write([]byte(provisioner))
+	_, _ = hash.Write([]byte{0})
+	_, _ = hash.Write(archive)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// New opens the cache in the directory. Entries whose files are missing and
+// files that no entry references are removed.
+func New(ctx context.Context, opts Options) (*Cache, error) {
+	if opts.Filesystem == nil {
+		opts.Filesystem = afero.NewOsFs()
+	}
+	if opts.MaxSize == 0 {
+		opts.MaxSize = DefaultMaxSize
+	}
+	if opts.MaxAge == 0 {
+		opts.MaxAge = DefaultMaxAge
+	}
+	c := &Cache{
+		fs:       opts.Filesystem,
+		logger:   opts.Logger,
+		dir:      opts.Directory,
+		maxSize:  opts.MaxSize,
+		maxAge:   opts.MaxAge,
+		keyLocks: map[string]*keyLock{},
+		entries:  map[string]*entry{},
+		blobs:    map[string]*blob{},
+	}
+
+	err := c.fs.MkdirAll(filepath.Join(c.dir, "blobs"), 0o700)
+	if err != nil {
+		return nil, xerrors.Errorf("create cache directory: %w", err)
+	}
+	data, err := afero.ReadFile(c.fs, filepath.Join(c.dir, indexFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, xerrors.Errorf("read index: %w", err)
+	}
+	if len(data) > 0 {
+		err = json.Unmarshal(data, &c.entries)
+		if err != nil {
+			c.logger.Warn(ctx, "discarding corrupt cache index", slog.Error(err))
+			c.entries = map[string]*entry{}
+		}
+	}
+
+	for key, e := range c.entries {
+		if !c.valid(e) {
+			delete(c.entries, key)
+			continue
+		}
+		for _, f := range e.Files {
+			c.ref(f)
+		}
+	}
+	err = c.removeStrayBlobs()
+	if err != nil {
+		return nil, err
+	}
+	err = c.evict(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return c, c.saveIndex()
+}
+
+// Restore copies the cached files of the key into the work directory. It
+// returns false if the key isn't cached, or its entry expired.
+func (c *Cache) Restore(key, workDirectory string) (bool, error) {
+	unlock := c.lockKey(key)
+	defer unlock()
+
+	c.mutex.Lock()
+	e, ok := c.entries[key]
+	if ok && time.Since(e.CreatedAt) > c.maxAge {
+		// The entry is replaced when the job stores its files.
+		ok = false
+	}
+	if !ok {
+		c.misses++
+		c.mutex.Unlock()
+		return false, nil
+	}
+	// Referencing the files keeps them from being evicted while they are
+	// copied.
+	for _, f := range e.Files {
+		c.ref(f)
+	}
+	c.mutex.Unlock()
+
+	var restoreErr error
+	for _, f := range e.Files {
+		restoreErr = c.restoreFile(f, workDirectory)
+		if restoreErr != nil {
+			// Don't leave partially restored files behind, Terraform would
+			// consider them installed.
+			for _, p := range Paths {
+				_ = c.fs.RemoveAll(filepath.Join(workDirectory, p))
+			}
+			restoreErr = xerrors.Errorf("restore %q: %w", f.Path, restoreErr)
+			break
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, f := range e.Files {
+		c.unref(f)
+	}
+	if restoreErr != nil {
+		_ = c.removeUnreferencedBlobs()
+		return false, restoreErr
+	}
+	c.hits++
+	e.LastUsed = time.Now()
+	// The entry may have been evicted while its files were copied.
+	err := c.removeUnreferencedBlobs()
+	if err != nil {
+		return false, err
+	}
+	return true, c.saveIndex()
+}
+
+// Store adds the cached paths of the work directory to the cache under the
+// key. The least recently used entries are evicted if the cache grows over
+// its maximum size.
+func (c *Cache) Store(ctx context.Context, key, workDirectory string) error {
+	unlock := c.lockKey(key)
+	defer unlock()
+
+	c.mutex.Lock()
+	if e, ok := c.entries[key]; ok && time.Since(e.CreatedAt) <= c.maxAge {
+		e.LastUsed = time.Now()
+		err := c.saveIndex()
+		c.mutex.Unlock()
+		return err
+	}
+	c.mutex.Unlock()
+
+	e := &entry{}
+	for _, p := range Paths {
+		err := c.storeTree(e, workDirectory, p)
+		if err != nil {
+			c.mutex.Lock()
+			defer c.mutex.Unlock()
+			for _, f := range e.Files {
+				c.unref(f)
+			}
+			_ = c.removeUnreferencedBlobs()
+			return xerrors.Errorf("store %q: %w", p, err)
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if len(e.Files) == 0 {
+		return nil
+	}
+	if e.size() > c.maxSize {
+		c.logger.Debug(ctx, "not caching entry larger than the cache", slog.F("key", key))
+		for _, f := range e.Files {
+			c.unref(f)
+		}
+		return c.removeUnreferencedBlobs()
+	}
+	// Replace the expired entry of the key, if any.
+	c.remove(key)
+	e.CreatedAt = time.Now()
+	e.LastUsed = e.CreatedAt
+	c.entries[key] = e
+
+	err := c.removeUnreferencedBlobs()
+	if err != nil {
+		return err
+	}
+	err = c.evict(ctx, key)
+	if err != nil {
+		return err
+	}
+	return c.saveIndex()
+}
+
+// Stats returns the statistics of the cache.
+func (c *Cache) Stats() Stats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return Stats{
+		Entries:      int32(len(c.entries)),
+		SizeBytes:    c.size,
+		MaxSizeBytes: c.maxSize,
+		Hits:         c.hits,
+		Misses:       c.misses,
+	}
+}
+
+// storeTree stores the file or directory at rel. Symbolic links aren't
+// stored: Terraform links providers into the work directory from its plugin
+// cache directory, which keeps them, and links them again when the work
+// directory is initialized.
+func (c *Cache) storeTree(e *entry, workDirectory, rel string) error {
+	name := filepath.Join(workDirectory, rel)
+	info, err := c.lstat(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSymlink != 0 {
+		return nil
+	}
+	if !info.IsDir() {
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		f, err := c.storeBlob(name)
+		if err != nil {
+			return err
+		}
+		f.Path = filepath.ToSlash(rel)
+		f.Mode = info.Mode().Perm()
+		e.Files = append(e.Files, f)
+		return nil
+	}
+	children, err := afero.ReadDir(c.fs, name)
+	if err != nil {
+		return err
+	}
+	for _, child := range children {
+		err = c.storeTree(e, workDirectory, filepath.Join(rel, child.Name()))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Cache) lstat(name string) (fs.FileInfo, error) {
+	if lstater, ok := c.fs.(afero.Lstater); ok {
+		info, _, err := lstater.LstatIfPossible(name)
+		return info, err
+	}
+	return c.fs.Stat(name)
+}
+
+// storeBlob copies the file into the blobs directory unless a file with the
+// same content is already stored.
+func (c *Cache) storeBlob(name string) (file, error) {
+	src, err := c.fs.Open(name)
+	if err != nil {
+		return file{}, err
+	}
+	defer src.Close()
+
+	tmp, err := afero.TempFile(c.fs, filepath.Join(c.dir, "blobs"), "tmp")
+	if err != nil {
+		return file{}, err
+	}
+	tmpName := tmp.Name()
+	defer func() {
+		_ = tmp.Close()
+		_ = c.fs.Remove(tmpName)
+	}()
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), src)
+	if err != nil {
+		return file{}, err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return file{}, err
+	}
+
+	f := file{
+		Hash: hex.EncodeToString(hash.Sum(nil)),
+		Size: size,
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.blobs[f.Hash]; !ok {
+		err = c.fs.MkdirAll(filepath.Dir(c.blobPath(f.Hash)), 0o700)
+		if err != nil {
+			return file{}, err
+		}
+		err = c.fs.Rename(tmpName, c.blobPath(f.Hash))
+		if err != nil {
+			return file{}, err
+		}
+	}
+	c.ref(f)
+	return f, nil
+}
+
+func (c *Cache) restoreFile(f file, workDirectory string) error {
+	rel := filepath.FromSlash(f.Path)
+	if !filepath.IsLocal(rel) {
+		return xerrors.New("path is outside of the work directory")
+	}
+	name := filepath.Join(workDirectory, rel)
+	err := c.fs.MkdirAll(filepath.Dir(name), 0o700)
+	if err != nil {
+		return err
+	}
+
+	src, err := c.fs.Open(c.blobPath(f.Hash))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := c.fs.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if err != nil {
+		_ = dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+// evict removes the least recently used entries, except for keep, until the
+// cache fits its maximum size.
+func (c *Cache) evict(ctx context.Context, keep string) error {
+	if c.size <= c.maxSize {
+		return nil
+	}
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		if key != keep {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].LastUsed.Before(c.entries[keys[j]].LastUsed)
+	})
+	for _, key := range keys {
+		if c.size <= c.maxSize {
+			break
+		}
+		c.logger.Debug(ctx, "evicting cache entry", slog.F("key", key))
+		c.remove(key)
+	}
+	return c.removeUnreferencedBlobs()
+}
+
+// remove removes the entry of the key, if any. Its files are removed by the
+// next call to removeUnreferencedBlobs.
+func (c *Cache) remove(key string) {
+	e, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	for _, f := range e.Files {
+		c.unref(f)
+	}
+	delete(c.entries, key)
+}
+
+// lockKey locks the key until the returned function is called, without
+// blocking the use of other keys.
+func (c *Cache) lockKey(key string) func() {
+	c.mutex.Lock()
+	l, ok := c.keyLocks[key]
+	if !ok {
+		l = &keyLock{}
+		c.keyLocks[key] = l
+	}
+	l.waiters++
+	c.mutex.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		l.waiters--
+		if l.waiters == 0 {
+			delete(c.keyLocks, key)
+		}
+	}
+}
+
+// valid returns whether all files of the entry are stored.
+func (c *Cache) valid(e *entry) bool {
+	for _, f := range e.Files {
+		if len(f.Hash) != sha256.Size*2 {
+			return false
+		}
+		info, err := c.fs.Stat(c.blobPath(f.Hash))
+		if err != nil || info.Size() != f.Size {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Cache) ref(f file) {
+	b, ok := c.blobs[f.Hash]
+	if !ok {
+		b = &blob{size: f.Size}
+		c.blobs[f.Hash] = b
+		c.size += f.Size
+	}
+	b.refs++
+}
+
+func (c *Cache) unref(f file) {
+	b, ok := c.blobs[f.Hash]
+	if !ok {
+		return
+	}
+	b.refs--
+	if b.refs == 0 {
+		c.size -= b.size
+	}
+}
+
+// removeUnreferencedBlobs removes the blobs that no entry references
+// anymore.
+func (c *Cache) removeUnreferencedBlobs() error {
+	for hash, b := range c.blobs {
+		if b.refs > 0 {
+			continue
+		}
+		err := c.fs.Remove(c.blobPath(hash))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return xerrors.Errorf("remove blob: %w", err)
+		}
+		delete(c.blobs, hash)
+	}
+	return nil
+}
+
+// removeStrayBlobs removes the files in the blobs directory that no entry
+// references, e.g. blobs of entries that were evicted or dropped from the
+// index, or temporary files left behind by a previous process.
+func (c *Cache) removeStrayBlobs() error {
+	return afero.Walk(c.fs, filepath.Join(c.dir, "blobs"), func(name string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if _, ok := c.blobs[path.Base(filepath.ToSlash(name))]; ok {
+			return nil
+		}
+		err = c.fs.Remove(name)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return xerrors.Errorf("remove unreferenced blob: %w", err)
+		}
+		return nil
+	})
+}
+
+func (c *Cache) saveIndex() error {
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return xerrors.Errorf("marshal index: %w", err)
+	}
+	name := filepath.Join(c.dir, indexFile)
+	err = afero.WriteFile(c.fs, name+".tmp", data, 0o600)
+	if err != nil {
+		return xerrors.Errorf("write index: %w", err)
+	}
+	err = c.fs.Rename(name+".tmp", name)
+	if err != nil {
+		return xerrors.Errorf("rename index: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) blobPath(hash string) string {
+	return filepath.Join(c.dir, "blobs", hash[:2], hash)
+}
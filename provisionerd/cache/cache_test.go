@@ -0,0 +1,235 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/provisionerd/cache"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("StoreRestore", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		fs := afero.NewMemMapFs()
+		c := newCache(t, fs, 0)
+
+		writeFiles(t, fs, "/work", map[string]string{
+			".terraform/providers/coder/coder/provider": "provider",
+			".terraform/modules/modules.json":           "{}",
+			".terraform.lock.hcl":                       "lock",
+			"main.tf":                                   "resource",
+		})
+		key := cache.Key("terraform", []byte("archive"))
+		ok, err := c.Restore(key, "/work")
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.NoError(t, c.Store(ctx, key, "/work"))
+
+		ok, err = c.Restore(key, "/other")
+		require.NoError(t, err)
+		require.True(t, ok)
+		data, err := afero.ReadFile(fs, "/other/.terraform/providers/coder/coder/provider")
+		require.NoError(t, err)
+		require.Equal(t, "provider", string(data))
+		data, err = afero.ReadFile(fs, "/other/.terraform.lock.hcl")
+		require.NoError(t, err)
+		require.Equal(t, "lock", string(data))
+		// Only the files Terraform installs are cached.
+		_, err = fs.Stat("/other/main.tf")
+		require.Error(t, err)
+
+		stats := c.Stats()
+		require.EqualValues(t, 1, stats.Entries)
+		require.EqualValues(t, len("provider")+len("{}")+len("lock"), stats.SizeBytes)
+		require.EqualValues(t, 1, stats.Hits)
+		require.EqualValues(t, 1, stats.Misses)
+
+		// Entries are kept when the cache is opened again.
+		c = newCache(t, fs, 0)
+		ok, err = c.Restore(key, "/another")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.EqualValues(t, 1, c.Stats().Entries)
+	})
+
+	t.Run("Deduplicate", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		fs := afero.NewMemMapFs()
+		c := newCache(t, fs, 0)
+
+		writeFiles(t, fs, "/work", map[string]string{
+			".terraform/providers/coder/coder/provider": "provider",
+		})
+		require.NoError(t, c.Store(ctx, cache.Key("terraform", []byte("v1")), "/work"))
+		require.NoError(t, c.Store(ctx, cache.Key("terraform", []byte("v2")), "/work"))
+
+		stats := c.Stats()
+		require.EqualValues(t, 2, stats.Entries)
+		require.EqualValues(t, len("provider"), stats.SizeBytes)
+	})
+
+	t.Run("Evict", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		fs := afero.NewMemMapFs()
+		c := newCache(t, fs, 20)
+
+		store := func(key, content string) {
+			dir := filepath.Join("/work", key)
+			writeFiles(t, fs, dir, map[string]string{
+				".terraform/provider": content,
+			})
+			require.NoError(t, c.Store(ctx, key, dir))
+		}
+		store("a", "aaaaaaaaaa")
+		store("b", "bbbbbbbbbb")
+		// Using "a" makes "b" the least recently used entry.
+		ok, err := c.Restore("a", "/restore")
+		require.NoError(t, err)
+		require.True(t, ok)
+		store("c", "cccccccccc")
+
+		ok, err = c.Restore("b", "/restore")
+		require.NoError(t, err)
+		require.False(t, ok)
+		ok, err = c.Restore("a", "/restore")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.EqualValues(t, 20, c.Stats().SizeBytes)
+
+		// Entries larger than the cache aren't kept.
+		store("d", "dddddddddddddddddddddddddddddd")
+		ok, err = c.Restore("d", "/restore")
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.EqualValues(t, 2, c.Stats().Entries)
+	})
+
+	t.Run("Expire", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		fs := afero.NewMemMapFs()
+		c, err := cache.New(ctx, cache.Options{
+			Filesystem: fs,
+			Logger:     slogtest.Make(t, nil),
+			Directory:  "/cache",
+			MaxAge:     time.Nanosecond,
+		})
+		require.NoError(t, err)
+
+		key := cache.Key("terraform", []byte("archive"))
+		writeFiles(t, fs, "/work", map[string]string{
+			".terraform/modules/module/main.tf": "v1",
+		})
+		require.NoError(t, c.Store(ctx, key, "/work"))
+		time.Sleep(time.Millisecond)
+
+		// Expired entries aren't restored, so floating module sources are
+		// downloaded again, and are replaced by the new files.
+		ok, err := c.Restore(key, "/other")
+		require.NoError(t, err)
+		require.False(t, ok)
+		writeFiles(t, fs, "/work", map[string]string{
+			".terraform/modules/module/main.tf": "v2",
+		})
+		require.NoError(t, c.Store(ctx, key, "/work"))
+
+		stats := c.Stats()
+		require.EqualValues(t, 1, stats.Entries)
+		require.EqualValues(t, len("v2"), stats.SizeBytes)
+	})
+
+	t.Run("PluginCache", func(t *testing.T) {
+		t.Parallel()
+		if runtime.GOOS == "windows" {
+			t.Skip("Creating symbolic links requires privileges on Windows")
+		}
+		ctx := context.Background()
+		fs := afero.NewOsFs()
+		dir := t.TempDir()
+		c, err := cache.New(ctx, cache.Options{
+			Filesystem: fs,
+			Logger:     slogtest.Make(t, nil),
+			Directory:  filepath.Join(dir, "cache"),
+		})
+		require.NoError(t, err)
+
+		work := filepath.Join(dir, "work")
+		writeFiles(t, fs, dir, map[string]string{
+			"plugins/coder/coder/provider":    "provider",
+			"work/.terraform/modules/main.tf": "module",
+			"work/.terraform/providers/.keep": "",
+			"work/.terraform.lock.hcl":        "lock",
+		})
+		err = os.Symlink(filepath.Join(dir, "plugins", "coder"), filepath.Join(work, ".terraform", "providers", "coder"))
+		require.NoError(t, err)
+
+		// Providers linked from the plugin cache are left to it.
+		require.NoError(t, c.Store(ctx, "key", work))
+		require.EqualValues(t, len("module")+len("lock"), c.Stats().SizeBytes)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		fs := afero.NewMemMapFs()
+		c := newCache(t, fs, 0)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				key := fmt.Sprintf("key-%d", i%3)
+				dir := filepath.Join("/work", strconv.Itoa(i))
+				writeFiles(t, fs, dir, map[string]string{
+					".terraform/modules/main.tf": key,
+				})
+				assert.NoError(t, c.Store(ctx, key, dir))
+				ok, err := c.Restore(key, filepath.Join("/restore", strconv.Itoa(i)))
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			}()
+		}
+		wg.Wait()
+		require.EqualValues(t, 3, c.Stats().Entries)
+	})
+}
+
+func newCache(t *testing.T, fs afero.Fs, maxSize int64) *cache.Cache {
+	t.Helper()
+	c, err := cache.New(context.Background(), cache.Options{
+		Filesystem: fs,
+		Logger:     slogtest.Make(t, nil),
+		Directory:  "/cache",
+		MaxSize:    maxSize,
+	})
+	require.NoError(t, err)
+	return c
+}
+
+func writeFiles(t *testing.T, fs afero.Fs, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		name = filepath.Join(dir, name)
+		require.NoError(t, fs.MkdirAll(filepath.Dir(name), 0o700))
+		require.NoError(t, afero.WriteFile(fs, name, []byte(content), 0o600))
+	}
+}
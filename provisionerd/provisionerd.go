@@ -24,6 +24,7 @@ import (
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/cryptorand"
+	"github.com/coder/coder/provisionerd/cache"
 	"github.com/coder/coder/provisionerd/proto"
 	"github.com/coder/coder/provisionerd/runner"
 	sdkproto "github.com/coder/coder/provisionersdk/proto"
@@ -58,6 +59,9 @@ type Options struct {
 	Provisioners        Provisioners
 	// WorkDirectory must not be used by multiple processes at once.
 	WorkDirectory string
+	// Cache is the cache of providers and modules, which may be shared by
+	// the provisioner daemons of a process. It's disabled if nil.
+	Cache *cache.Cache
 }
 
 // New creates and starts a provisioner daemon.
@@ -407,6 +411,7 @@ func (p *Server) acquireJob(ctx context.Context) {
 			Logger:              p.opts.Logger,
 			Filesystem:          p.opts.Filesystem,
 			WorkDirectory:       p.opts.WorkDirectory,
+			Cache:               p.opts.Cache,
 			Provisioner:         provisioner,
 			UpdateInterval:      p.opts.UpdateInterval,
 			ForceCancelInterval: p.opts.ForceCancelInterval,
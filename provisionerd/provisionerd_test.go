@@ -24,6 +24,7 @@ import (
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/provisionerd"
+	"github.com/coder/coder/provisionerd/cache"
 	"github.com/coder/coder/provisionerd/proto"
 	"github.com/coder/coder/provisionerd/runner"
 	"github.com/coder/coder/provisionersdk"
@@ -509,6 +510,101 @@ func TestProvisionerd(t *testing.T) {
 		assert.True(t, didComplete.Load(), "should complete the job")
 	})
 
+	t.Run("WorkspaceBuildCache", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		providerCache, err := cache.New(context.Background(), cache.Options{
+			Logger:    slogtest.Make(t, nil),
+			Directory: t.TempDir(),
+		})
+		require.NoError(t, err)
+		var (
+			acquired     atomic.Int32
+			completeChan = make(chan struct{})
+			completeOnce sync.Once
+			mutex        sync.Mutex
+			// restored is whether the module was in the work directory
+			// when each job started.
+			restored   []bool
+			cacheStats []*proto.CacheStats
+		)
+
+		server := provisionerd.New(func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJob: func(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
+					n := acquired.Inc()
+					if n > 2 {
+						completeOnce.Do(func() { close(completeChan) })
+						return &proto.AcquiredJob{}, nil
+					}
+					// Both jobs build the same template source.
+					return &proto.AcquiredJob{
+						JobId:       fmt.Sprintf("test-%d", n),
+						Provisioner: "terraform",
+						TemplateSourceArchive: createTar(t, map[string]string{
+							"main.tf": "module",
+						}),
+						Type: &proto.AcquiredJob_WorkspaceBuild_{
+							WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+								Metadata: &sdkproto.Provision_Metadata{},
+							},
+						},
+					}, nil
+				},
+				updateJob: noopUpdateJob,
+				completeJob: func(ctx context.Context, job *proto.CompletedJob) (*proto.Empty, error) {
+					mutex.Lock()
+					defer mutex.Unlock()
+					cacheStats = append(cacheStats, job.CacheStats)
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, &provisionerd.Options{
+			Logger:          slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}).Named("provisionerd").Leveled(slog.LevelDebug),
+			JobPollInterval: 50 * time.Millisecond,
+			UpdateInterval:  50 * time.Millisecond,
+			Provisioners: provisionerd.Provisioners{
+				"terraform": createProvisionerClient(t, done, provisionerTestServer{
+					provision: func(stream sdkproto.DRPCProvisioner_ProvisionStream) error {
+						request, err := stream.Recv()
+						require.NoError(t, err)
+						if plan := request.GetPlan(); plan != nil {
+							// Install the module like "terraform init".
+							module := filepath.Join(plan.GetConfig().GetDirectory(), ".terraform", "modules", "module", "main.tf")
+							_, err := os.Stat(module)
+							mutex.Lock()
+							restored = append(restored, err == nil)
+							mutex.Unlock()
+							require.NoError(t, os.MkdirAll(filepath.Dir(module), 0o700))
+							require.NoError(t, os.WriteFile(module, []byte("resource"), 0o600))
+						}
+						return stream.Send(&sdkproto.Provision_Response{
+							Type: &sdkproto.Provision_Response_Complete{
+								Complete: &sdkproto.Provision_Complete{},
+							},
+						})
+					},
+				}),
+			},
+			WorkDirectory: t.TempDir(),
+			Cache:         providerCache,
+		})
+		require.Condition(t, closedWithin(completeChan, testutil.WaitShort))
+		require.NoError(t, server.Close())
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		// The module installed by the first job is restored for the second.
+		require.Equal(t, []bool{false, true}, restored)
+		require.Len(t, cacheStats, 2)
+		require.EqualValues(t, 1, cacheStats[1].Entries)
+		require.EqualValues(t, 1, cacheStats[1].Hits)
+		require.EqualValues(t, 1, cacheStats[1].Misses)
+	})
+
 	t.Run("WorkspaceBuildQuotaExceeded", func(t *testing.T) {
 		t.Parallel()
 		done := make(chan struct{})
@@ -838,6 +934,212 @@ func TestProvisionerd(t *testing.T) {
 		require.NoError(t, server.Close())
 	})
 
+	t.Run("CancelBeforeApply", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		var (
+			completed  sync.Once
+			updated    sync.Once
+			provisions atomic.Int32
+			failed     *proto.FailedJob
+		)
+		updateChan := make(chan struct{})
+		completeChan := make(chan struct{})
+		server := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJob: func(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
+					return &proto.AcquiredJob{
+						JobId:       "test",
+						Provisioner: "someprovisioner",
+						TemplateSourceArchive: createTar(t, map[string]string{
+							"test.txt": "content",
+						}),
+						Type: &proto.AcquiredJob_WorkspaceBuild_{
+							WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+								Metadata: &sdkproto.Provision_Metadata{},
+							},
+						},
+					}, nil
+				},
+				updateJob: func(ctx context.Context, update *proto.UpdateJobRequest) (*proto.UpdateJobResponse, error) {
+					resp := &proto.UpdateJobResponse{}
+					for _, log := range update.Logs {
+						if log.Source != proto.LogSource_PROVISIONER {
+							continue
+						}
+						updated.Do(func() {
+							close(updateChan)
+						})
+						break
+					}
+					// Cancel the job while it's planning.
+					select {
+					case <-updateChan:
+						resp.Canceled = true
+					default:
+					}
+					return resp, nil
+				},
+				failJob: func(ctx context.Context, job *proto.FailedJob) (*proto.Empty, error) {
+					completed.Do(func() {
+						failed = job
+						close(completeChan)
+					})
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.Provisioners{
+			"someprovisioner": createProvisionerClient(t, done, provisionerTestServer{
+				provision: func(stream sdkproto.DRPCProvisioner_ProvisionStream) error {
+					provisions.Add(1)
+					msg, err := stream.Recv()
+					require.NoError(t, err)
+					if msg.GetApply() != nil {
+						return stream.Send(&sdkproto.Provision_Response{
+							Type: &sdkproto.Provision_Response_Complete{
+								Complete: &sdkproto.Provision_Complete{},
+							},
+						})
+					}
+
+					err = stream.Send(&sdkproto.Provision_Response{
+						Type: &sdkproto.Provision_Response_Log{
+							Log: &sdkproto.Log{
+								Level:  sdkproto.LogLevel_DEBUG,
+								Output: "planning",
+							},
+						},
+					})
+					require.NoError(t, err)
+
+					msg, err = stream.Recv()
+					require.NoError(t, err)
+					require.NotNil(t, msg.GetCancel())
+
+					// The plan completes even though the job was canceled.
+					return stream.Send(&sdkproto.Provision_Response{
+						Type: &sdkproto.Provision_Response_Complete{
+							Complete: &sdkproto.Provision_Complete{},
+						},
+					})
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(completeChan, testutil.WaitShort))
+		require.NoError(t, server.Close())
+		// The apply never starts, and the build keeps its state.
+		assert.EqualValues(t, 1, provisions.Load())
+		assert.Equal(t, "canceled before apply", failed.Error)
+		assert.Nil(t, failed.GetWorkspaceBuild().GetState())
+	})
+	t.Run("CancelDuringApply", func(t *testing.T) {
+		t.Parallel()
+		done := make(chan struct{})
+		t.Cleanup(func() {
+			close(done)
+		})
+		var (
+			completed    sync.Once
+			updated      sync.Once
+			failed       *proto.FailedJob
+			partialState = []byte("partially applied")
+		)
+		updateChan := make(chan struct{})
+		completeChan := make(chan struct{})
+		server := createProvisionerd(t, func(ctx context.Context) (proto.DRPCProvisionerDaemonClient, error) {
+			return createProvisionerDaemonClient(t, done, provisionerDaemonTestServer{
+				acquireJob: func(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
+					return &proto.AcquiredJob{
+						JobId:       "test",
+						Provisioner: "someprovisioner",
+						TemplateSourceArchive: createTar(t, map[string]string{
+							"test.txt": "content",
+						}),
+						Type: &proto.AcquiredJob_WorkspaceBuild_{
+							WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
+								Metadata: &sdkproto.Provision_Metadata{},
+								State:    []byte("initial state"),
+							},
+						},
+					}, nil
+				},
+				updateJob: func(ctx context.Context, update *proto.UpdateJobRequest) (*proto.UpdateJobResponse, error) {
+					resp := &proto.UpdateJobResponse{}
+					for _, log := range update.Logs {
+						if log.Source != proto.LogSource_PROVISIONER || log.Output != "applying" {
+							continue
+						}
+						updated.Do(func() {
+							close(updateChan)
+						})
+						break
+					}
+					// Cancel the job once it's applying.
+					select {
+					case <-updateChan:
+						resp.Canceled = true
+					default:
+					}
+					return resp, nil
+				},
+				failJob: func(ctx context.Context, job *proto.FailedJob) (*proto.Empty, error) {
+					completed.Do(func() {
+						failed = job
+						close(completeChan)
+					})
+					return &proto.Empty{}, nil
+				},
+			}), nil
+		}, provisionerd.Provisioners{
+			"someprovisioner": createProvisionerClient(t, done, provisionerTestServer{
+				provision: func(stream sdkproto.DRPCProvisioner_ProvisionStream) error {
+					msg, err := stream.Recv()
+					require.NoError(t, err)
+					if msg.GetPlan() != nil {
+						return stream.Send(&sdkproto.Provision_Response{
+							Type: &sdkproto.Provision_Response_Complete{
+								Complete: &sdkproto.Provision_Complete{},
+							},
+						})
+					}
+
+					err = stream.Send(&sdkproto.Provision_Response{
+						Type: &sdkproto.Provision_Response_Log{
+							Log: &sdkproto.Log{
+								Level:  sdkproto.LogLevel_DEBUG,
+								Output: "applying",
+							},
+						},
+					})
+					require.NoError(t, err)
+
+					msg, err = stream.Recv()
+					require.NoError(t, err)
+					require.NotNil(t, msg.GetCancel())
+
+					// The apply stops gracefully and returns the state of the
+					// resources that were applied so far.
+					return stream.Send(&sdkproto.Provision_Response{
+						Type: &sdkproto.Provision_Response_Complete{
+							Complete: &sdkproto.Provision_Complete{
+								State: partialState,
+								Error: "interrupted",
+							},
+						},
+					})
+				},
+			}),
+		})
+		require.Condition(t, closedWithin(completeChan, testutil.WaitShort))
+		require.NoError(t, server.Close())
+		// The build keeps the partial state so it can be resumed.
+		assert.Equal(t, "interrupted", failed.Error)
+		assert.Equal(t, partialState, failed.GetWorkspaceBuild().GetState())
+	})
+
 	t.Run("ReconnectAndFail", func(t *testing.T) {
 		t.Parallel()
 		done := make(chan struct{})
@@ -1,6 +1,13 @@
 package runner
 
-import "github.com/coder/coder/provisionersdk/proto"
+import (
+	"strconv"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/provisionersdk"
+	"github.com/coder/coder/provisionersdk/proto"
+)
 
 func sumDailyCost(resources []*proto.Resource) int {
 	var sum int
@@ -9,3 +16,32 @@ func sumDailyCost(resources []*proto.Resource) int {
 	}
 	return sum
 }
+
+// sumResourceUsage sums the resource usage declared by the metadata of the
+// resources. Metadata that can't be parsed is skipped and returned as errors.
+func sumResourceUsage(resources []*proto.Resource) (provisionersdk.ResourceUsage, []error) {
+	var (
+		usage provisionersdk.ResourceUsage
+		errs  []error
+	)
+	for _, r := range resources {
+		for _, m := range r.Metadata {
+			if m.IsNull {
+				continue
+			}
+			err := usage.AddMetadata(m.Key, m.Value)
+			if err != nil {
+				errs = append(errs, xerrors.Errorf("resource %q: %w", r.Name, err))
+			}
+		}
+	}
+	return usage, errs
+}
+
+func formatCores(millicores int64) string {
+	return strconv.FormatFloat(float64(millicores)/1000, 'f', -1, 64)
+}
+
+func formatGiB(bytes int64) string {
+	return strconv.FormatFloat(float64(bytes)/(1<<30), 'f', 1, 64) + " GiB"
+}
@@ -28,6 +28,7 @@ import (
 	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/coderd/util/ptr"
+	"github.com/coder/coder/provisionerd/cache"
 	"github.com/coder/coder/provisionerd/proto"
 	sdkproto "github.com/coder/coder/provisionersdk/proto"
 )
@@ -40,6 +41,9 @@ const (
 	requiredTemplateVariablesErrorText = "required template variables"
 )
 
+// PlanStage is the stage of the logs sent while planning a workspace build.
+const PlanStage = "Planning infrastructure"
+
 var errorCodes = map[string]string{
 	MissingParameterErrorCode:          missingParameterErrorText,
 	RequiredTemplateVariablesErrorCode: requiredTemplateVariablesErrorText,
@@ -56,6 +60,7 @@ type Runner struct {
 	logger              slog.Logger
 	filesystem          afero.Fs
 	workDirectory       string
+	cache               *cache.Cache
 	provisioner         sdkproto.DRPCProvisionerClient
 	lastUpdate          atomic.Pointer[time.Time]
 	updateInterval      time.Duration
@@ -105,11 +110,13 @@ type QuotaCommitter interface {
 }
 
 type Options struct {
-	Updater             JobUpdater
-	QuotaCommitter      QuotaCommitter
-	Logger              slog.Logger
-	Filesystem          afero.Fs
-	WorkDirectory       string
+	Updater        JobUpdater
+	QuotaCommitter QuotaCommitter
+	Logger         slog.Logger
+	Filesystem     afero.Fs
+	WorkDirectory  string
+	// Cache is optional.
+	Cache               *cache.Cache
 	Provisioner         sdkproto.DRPCProvisionerClient
 	UpdateInterval      time.Duration
 	ForceCancelInterval time.Duration
@@ -151,6 +158,7 @@ func New(
 		logger:              logger,
 		filesystem:          opts.Filesystem,
 		workDirectory:       opts.WorkDirectory,
+		cache:               opts.Cache,
 		provisioner:         opts.Provisioner,
 		updateInterval:      opts.UpdateInterval,
 		forceCancelInterval: opts.ForceCancelInterval,
@@ -380,9 +388,11 @@ func (r *Runner) doCleanFinish(ctx context.Context) {
 		defer span.End()
 
 		if failedJob != nil {
+			failedJob.CacheStats = r.cacheStats()
 			r.setFail(failedJob)
 			return
 		}
+		completedJob.CacheStats = r.cacheStats()
 		r.setComplete(completedJob)
 	}()
 
@@ -397,6 +407,10 @@ func (r *Runner) doCleanFinish(ctx context.Context) {
 			CreatedAt: time.Now().UnixMilli(),
 		})
 
+		if failedJob == nil {
+			r.storeCache(ctx)
+		}
+
 		// Cleanup the work directory after execution.
 		for attempt := 0; attempt < 5; attempt++ {
 			err := r.filesystem.RemoveAll(r.workDirectory)
@@ -493,6 +507,8 @@ func (r *Runner) do(ctx context.Context) (*proto.CompletedJob, *proto.FailedJob)
 			)
 		}
 	}
+	r.restoreCache(ctx)
+
 	switch jobType := r.job.Type.(type) {
 	case *proto.AcquiredJob_TemplateImport_:
 		r.logger.Debug(context.Background(), "acquired job is template import",
@@ -804,6 +820,7 @@ func (r *Runner) runTemplateImportProvisionWithRichParameters(ctx context.Contex
 				CreatedAt: time.Now().UnixMilli(),
 				Output:    msgType.Log.Output,
 				Stage:     stage,
+				Progress:  msgType.Log.Progress,
 			})
 		case *sdkproto.Provision_Response_Complete:
 			if msgType.Complete.Error != "" {
@@ -931,6 +948,7 @@ func (r *Runner) buildWorkspace(ctx context.Context, stage string, req *sdkproto
 				CreatedAt: time.Now().UnixMilli(),
 				Output:    msgType.Log.Output,
 				Stage:     stage,
+				Progress:  msgType.Log.Progress,
 			})
 		case *sdkproto.Provision_Response_Complete:
 			if msgType.Complete.Error != "" {
@@ -964,16 +982,29 @@ func (r *Runner) buildWorkspace(ctx context.Context, stage string, req *sdkproto
 }
 
 func (r *Runner) commitQuota(ctx context.Context, resources []*sdkproto.Resource) *proto.FailedJob {
+	const stage = "Commit quota"
+
 	cost := sumDailyCost(resources)
-	if cost == 0 {
+	usage, errs := sumResourceUsage(resources)
+	for _, err := range errs {
+		r.queueLog(ctx, &proto.Log{
+			Source:    proto.LogSource_PROVISIONER,
+			Level:     sdkproto.LogLevel_WARN,
+			CreatedAt: time.Now().UnixMilli(),
+			Output:    fmt.Sprintf("Ignoring resource usage: %s", err),
+			Stage:     stage,
+		})
+	}
+	if cost == 0 && usage.IsZero() {
 		return nil
 	}
 
-	const stage = "Commit quota"
-
 	resp, err := r.quotaCommitter.CommitQuota(ctx, &proto.CommitQuotaRequest{
-		JobId:     r.job.JobId,
-		DailyCost: int32(cost),
+		JobId:         r.job.JobId,
+		DailyCost:     int32(cost),
+		CpuMillicores: usage.CPUMillicores,
+		MemoryBytes:   usage.MemoryBytes,
+		DiskBytes:     usage.DiskBytes,
 	})
 	if err != nil {
 		r.queueLog(ctx, &proto.Log{
@@ -985,11 +1016,25 @@ func (r *Runner) commitQuota(ctx context.Context, resources []*sdkproto.Resource
 		})
 		return r.failedJobf("commit quota: %+v", err)
 	}
-	for _, line := range []string{
-		fmt.Sprintf("Build cost       —   %v", cost),
-		fmt.Sprintf("Budget           —   %v", resp.Budget),
-		fmt.Sprintf("Credits consumed —   %v", resp.CreditsConsumed),
-	} {
+	var lines []string
+	if cost > 0 {
+		lines = append(lines,
+			fmt.Sprintf("Build cost       —   %v", cost),
+			fmt.Sprintf("Budget           —   %v", resp.Budget),
+			fmt.Sprintf("Credits consumed —   %v", resp.CreditsConsumed),
+		)
+	}
+	// Resource quotas are only reported when the template sets them.
+	if resp.CpuMillicoresQuota > 0 {
+		lines = append(lines, fmt.Sprintf("CPU usage        —   %s of %s cores", formatCores(resp.CpuMillicoresConsumed), formatCores(resp.CpuMillicoresQuota)))
+	}
+	if resp.MemoryBytesQuota > 0 {
+		lines = append(lines, fmt.Sprintf("Memory usage     —   %s of %s", formatGiB(resp.MemoryBytesConsumed), formatGiB(resp.MemoryBytesQuota)))
+	}
+	if resp.DiskBytesQuota > 0 {
+		lines = append(lines, fmt.Sprintf("Disk usage       —   %s of %s", formatGiB(resp.DiskBytesConsumed), formatGiB(resp.DiskBytesQuota)))
+	}
+	for _, line := range lines {
 		r.queueLog(ctx, &proto.Log{
 			Source:    proto.LogSource_PROVISIONER,
 			Level:     sdkproto.LogLevel_INFO,
@@ -1027,6 +1072,9 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 	case sdkproto.WorkspaceTransition_STOP:
 		applyStage = "Stopping workspace"
 		commitQuota = true
+	case sdkproto.WorkspaceTransition_ARCHIVE:
+		applyStage = "Archiving workspace"
+		commitQuota = true
 	case sdkproto.WorkspaceTransition_DESTROY:
 		applyStage = "Destroying workspace"
 	}
@@ -1039,7 +1087,7 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 		ProvisionerLogLevel: r.job.GetWorkspaceBuild().LogLevel,
 	}
 
-	completedPlan, failed := r.buildWorkspace(ctx, "Planning infrastructure", &sdkproto.Provision_Request{
+	completedPlan, failed := r.buildWorkspace(ctx, PlanStage, &sdkproto.Provision_Request{
 		Type: &sdkproto.Provision_Request_Plan{
 			Plan: &sdkproto.Provision_Plan{
 				Config:              config,
@@ -1061,6 +1109,12 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 		}
 	}
 
+	// A build that is canceled while planning must not start to apply. The
+	// build keeps the state it started with, since nothing was changed yet.
+	if r.notCanceled.Err() != nil {
+		return nil, r.failedWorkspaceBuildf("canceled before apply")
+	}
+
 	r.queueLog(ctx, &proto.Log{
 		Source:    proto.LogSource_PROVISIONER_DAEMON,
 		Level:     sdkproto.LogLevel_INFO,
@@ -1071,8 +1125,9 @@ func (r *Runner) runWorkspaceBuild(ctx context.Context) (*proto.CompletedJob, *p
 	completedApply, failed := r.buildWorkspace(ctx, applyStage, &sdkproto.Provision_Request{
 		Type: &sdkproto.Provision_Request_Apply{
 			Apply: &sdkproto.Provision_Apply{
-				Config: config,
-				Plan:   completedPlan.GetPlan(),
+				Config:         config,
+				Plan:           completedPlan.GetPlan(),
+				PlannedChanges: completedPlan.GetPlannedChanges(),
 			},
 		},
 	})
@@ -1197,3 +1252,51 @@ func (r *Runner) logProvisionerJobLog(ctx context.Context, logLevel sdkproto.Log
 		r.logger.Info(ctx, msg, fields...)
 	}
 }
+
+// restoreCache restores the providers and modules of the template source
+// from the cache, so the provisioner doesn't download them again. Failing to
+// restore them isn't fatal.
+func (r *Runner) restoreCache(ctx context.Context) {
+	if !r.usesCache() {
+		return
+	}
+	ok, err := r.cache.Restore(cache.Key(r.job.Provisioner, r.job.TemplateSourceArchive), r.workDirectory)
+	if err != nil {
+		r.logger.Warn(ctx, "failed to restore cache", slog.Error(err))
+		return
+	}
+	r.logger.Debug(ctx, "restored cache", slog.F("hit", ok))
+}
+
+// storeCache stores the providers and modules the provisioner installed in
+// the work directory in the cache.
+func (r *Runner) storeCache(ctx context.Context) {
+	if !r.usesCache() {
+		return
+	}
+	err := r.cache.Store(ctx, cache.Key(r.job.Provisioner, r.job.TemplateSourceArchive), r.workDirectory)
+	if err != nil {
+		r.logger.Warn(ctx, "failed to store cache", slog.Error(err))
+	}
+}
+
+// usesCache returns whether the job uses the cache. Only Terraform installs
+// providers and modules in the cached paths, so jobs of other provisioners,
+// e.g. provisioner plugins, don't.
+func (r *Runner) usesCache() bool {
+	return r.cache != nil && r.job.Provisioner == "terraform"
+}
+
+func (r *Runner) cacheStats() *proto.CacheStats {
+	if r.cache == nil {
+		return nil
+	}
+	stats := r.cache.Stats()
+	return &proto.CacheStats{
+		Entries:      stats.Entries,
+		SizeBytes:    stats.SizeBytes,
+		MaxSizeBytes: stats.MaxSizeBytes,
+		Hits:         stats.Hits,
+		Misses:       stats.Misses,
+	}
+}
@@ -0,0 +1,93 @@
+package provisionersdk
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/hashicorp/yamux"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/provisionersdk/proto"
+)
+
+// PluginOptions configures a provisioner plugin.
+type PluginOptions struct {
+	// Path is the executable of the plugin.
+	Path string
+	Args []string
+	// Env is appended to the environment of the daemon.
+	Env []string
+	// Stderr receives the standard error of the plugin. It's discarded if nil.
+	Stderr io.Writer
+}
+
+// Plugin is a provisioner plugin: an executable that serves the provisioner
+// API with Serve over its standard input and output. Plugins let provisioner
+// daemons run templates with backends other than Terraform.
+type Plugin struct {
+	proto.DRPCProvisionerClient
+
+	cmd     *exec.Cmd
+	session *yamux.Session
+}
+
+// StartPlugin starts a provisioner plugin. The plugin is killed when the
+// context is canceled.
+func StartPlugin(ctx context.Context, options *PluginOptions) (*Plugin, error) {
+	//nolint:gosec // The plugin is configured by the operator of the daemon.
+	cmd := exec.CommandContext(ctx, options.Path, options.Args...)
+	cmd.Env = append(os.Environ(), options.Env...)
+	cmd.Stderr = options.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, xerrors.Errorf("stdin pipe: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, xerrors.Errorf("stdout pipe: %w", err)
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, xerrors.Errorf("start %q: %w", options.Path, err)
+	}
+
+	config := yamux.DefaultConfig()
+	config.LogOutput = io.Discard
+	session, err := yamux.Client(&pluginConn{
+		ReadCloser:  stdout,
+		WriteCloser: stdin,
+	}, config)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, xerrors.Errorf("create yamux: %w", err)
+	}
+	return &Plugin{
+		DRPCProvisionerClient: proto.NewDRPCProvisionerClient(MultiplexedConn(session)),
+		cmd:                   cmd,
+		session:               session,
+	}, nil
+}
+
+// Close closes the standard input of the plugin, which makes Serve return,
+// and waits for the plugin to exit.
+func (p *Plugin) Close() error {
+	_ = p.session.Close()
+	err := p.cmd.Wait()
+	if err != nil {
+		return xerrors.Errorf("wait for plugin: %w", err)
+	}
+	return nil
+}
+
+type pluginConn struct {
+	io.ReadCloser
+	io.WriteCloser
+}
+
+func (c *pluginConn) Close() error {
+	_ = c.WriteCloser.Close()
+	return c.ReadCloser.Close()
+}
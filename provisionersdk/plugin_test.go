@@ -0,0 +1,49 @@
+package provisionersdk_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"storj.io/drpc/drpcerr"
+
+	"github.com/coder/coder/provisionersdk"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestPlugin(t *testing.T) {
+	if os.Getenv("TEST_SUBPROCESS") == "1" {
+		pluginHelper()
+		return
+	}
+	t.Parallel()
+
+	ctx := testutil.Context(t, testutil.WaitLong)
+	plugin, err := provisionersdk.StartPlugin(ctx, &provisionersdk.PluginOptions{
+		Path: os.Args[0],
+		Args: []string{"-test.run=TestPlugin"},
+		Env:  []string{"TEST_SUBPROCESS=1"},
+	})
+	require.NoError(t, err)
+
+	// Requests are served by the plugin process.
+	stream, err := plugin.Parse(ctx, &proto.Parse_Request{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, drpcerr.Unimplemented, int(drpcerr.Code(err)))
+
+	// The plugin exits once its standard input is closed.
+	require.NoError(t, plugin.Close())
+}
+
+// nolint:unused
+func pluginHelper() {
+	err := provisionersdk.Serve(context.Background(), &proto.DRPCProvisionerUnimplementedServer{}, nil)
+	if err != nil {
+		panic(err)
+	}
+	// Exit before the test framework writes to the standard output.
+	os.Exit(0)
+}
@@ -0,0 +1,123 @@
+package provisionersdk
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// ResourceUsage is the CPU, memory and disk usage of workspace resources.
+// Templates declare the usage of a resource with "cpu", "memory" and "disk"
+// items of its coder_metadata, e.g. "2 cores", "500m", "8 GiB" or "512MB".
+// Numbers without a unit are cores for CPU and GiB for memory and disk.
+type ResourceUsage struct {
+	CPUMillicores int64
+	MemoryBytes   int64
+	DiskBytes     int64
+}
+
+// AddMetadata adds the usage declared by a resource metadata item to the
+// usage. Items with other keys and empty values are ignored.
+func (u *ResourceUsage) AddMetadata(key, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+
+	switch key {
+	case "cpu":
+		cpu, err := ParseCPUMillicores(value)
+		if err != nil {
+			return xerrors.Errorf("parse cpu: %w", err)
+		}
+		u.CPUMillicores += cpu
+	case "memory":
+		memory, err := ParseBytes(value)
+		if err != nil {
+			return xerrors.Errorf("parse memory: %w", err)
+		}
+		u.MemoryBytes += memory
+	case "disk":
+		disk, err := ParseBytes(value)
+		if err != nil {
+			return xerrors.Errorf("parse disk: %w", err)
+		}
+		u.DiskBytes += disk
+	}
+	return nil
+}
+
+// IsZero returns whether no usage is declared.
+func (u ResourceUsage) IsZero() bool {
+	return u == ResourceUsage{}
+}
+
+var cpuUnits = []string{"vcpus", "vcpu", "cpus", "cpu", "cores", "core"}
+
+// ParseCPUMillicores parses a CPU quantity, e.g. "2", "0.5 cores" or "500m",
+// into millicores.
+func ParseCPUMillicores(value string) (int64, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	if millicores, ok := strings.CutSuffix(v, "m"); ok {
+		n, err := strconv.ParseInt(strings.TrimSpace(millicores), 10, 64)
+		if err != nil || n < 0 {
+			return 0, xerrors.Errorf("invalid millicores %q", value)
+		}
+		return n, nil
+	}
+	for _, unit := range cpuUnits {
+		if cores, ok := strings.CutSuffix(v, unit); ok {
+			v = cores
+			break
+		}
+	}
+	cores, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || cores < 0 || math.IsInf(cores, 0) || math.IsNaN(cores) {
+		return 0, xerrors.Errorf("invalid cores %q", value)
+	}
+	return int64(math.Round(cores * 1000)), nil
+}
+
+// byteUnits are ordered so that longer suffixes are matched first.
+var byteUnits = []struct {
+	suffix string
+	bytes  float64
+}{
+	{"kib", 1 << 10},
+	{"mib", 1 << 20},
+	{"gib", 1 << 30},
+	{"tib", 1 << 40},
+	{"ki", 1 << 10},
+	{"mi", 1 << 20},
+	{"gi", 1 << 30},
+	{"ti", 1 << 40},
+	{"kb", 1e3},
+	{"mb", 1e6},
+	{"gb", 1e9},
+	{"tb", 1e12},
+	{"k", 1e3},
+	{"m", 1e6},
+	{"g", 1e9},
+	{"t", 1e12},
+	{"b", 1},
+}
+
+// ParseBytes parses a memory or disk size, e.g. "8 GiB", "512MB" or "10", into
+// bytes. Sizes without a unit are GiB.
+func ParseBytes(value string) (int64, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	multiplier := float64(1 << 30)
+	for _, unit := range byteUnits {
+		if n, ok := strings.CutSuffix(v, unit.suffix); ok {
+			v = n
+			multiplier = unit.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, xerrors.Errorf("invalid size %q", value)
+	}
+	return int64(math.Round(n * multiplier)), nil
+}
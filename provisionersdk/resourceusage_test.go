@@ -0,0 +1,65 @@
+package provisionersdk_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/provisionersdk"
+)
+
+func TestResourceUsage(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		Name     string
+		Key      string
+		Value    string
+		Expected provisionersdk.ResourceUsage
+		Error    bool
+	}{
+		{Name: "Cores", Key: "cpu", Value: "2", Expected: provisionersdk.ResourceUsage{CPUMillicores: 2000}},
+		{Name: "FractionalCores", Key: "cpu", Value: "0.5 cores", Expected: provisionersdk.ResourceUsage{CPUMillicores: 500}},
+		{Name: "VCPUs", Key: "cpu", Value: "4 vCPU", Expected: provisionersdk.ResourceUsage{CPUMillicores: 4000}},
+		{Name: "Millicores", Key: "cpu", Value: "250m", Expected: provisionersdk.ResourceUsage{CPUMillicores: 250}},
+		{Name: "InvalidCPU", Key: "cpu", Value: "Intel Xeon", Error: true},
+		{Name: "MemoryGiB", Key: "memory", Value: "8 GiB", Expected: provisionersdk.ResourceUsage{MemoryBytes: 8 << 30}},
+		{Name: "MemoryWithoutUnit", Key: "memory", Value: "4", Expected: provisionersdk.ResourceUsage{MemoryBytes: 4 << 30}},
+		{Name: "MemoryMB", Key: "memory", Value: "512MB", Expected: provisionersdk.ResourceUsage{MemoryBytes: 512e6}},
+		{Name: "MemoryMi", Key: "memory", Value: "512Mi", Expected: provisionersdk.ResourceUsage{MemoryBytes: 512 << 20}},
+		{Name: "DiskTB", Key: "disk", Value: "1.5 TB", Expected: provisionersdk.ResourceUsage{DiskBytes: 15e11}},
+		{Name: "NegativeDisk", Key: "disk", Value: "-10 GiB", Error: true},
+		{Name: "Empty", Key: "disk", Value: ""},
+		{Name: "OtherKey", Key: "region", Value: "us-east-1"},
+	} {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			var usage provisionersdk.ResourceUsage
+			err := usage.AddMetadata(tc.Key, tc.Value)
+			if tc.Error {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.Expected, usage)
+		})
+	}
+
+	t.Run("Sum", func(t *testing.T) {
+		t.Parallel()
+
+		var usage provisionersdk.ResourceUsage
+		require.True(t, usage.IsZero())
+		require.NoError(t, usage.AddMetadata("cpu", "2"))
+		require.NoError(t, usage.AddMetadata("cpu", "500m"))
+		require.NoError(t, usage.AddMetadata("disk", "10 GiB"))
+		require.NoError(t, usage.AddMetadata("disk", "10 GiB"))
+		require.False(t, usage.IsZero())
+		require.Equal(t, provisionersdk.ResourceUsage{
+			CPUMillicores: 2500,
+			DiskBytes:     20 << 30,
+		}, usage)
+	})
+}
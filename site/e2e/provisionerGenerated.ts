@@ -14,6 +14,15 @@ export enum LogLevel {
   UNRECOGNIZED = -1,
 }
 
+/** Stage is a step of provisioning that reports progress. */
+export enum Stage {
+  UNKNOWN_STAGE = 0,
+  INIT = 1,
+  PLAN = 2,
+  APPLY = 3,
+  UNRECOGNIZED = -1,
+}
+
 export enum AppSharingLevel {
   OWNER = 0,
   AUTHENTICATED = 1,
@@ -25,6 +34,7 @@ export enum WorkspaceTransition {
   START = 0,
   STOP = 1,
   DESTROY = 2,
+  ARCHIVE = 3,
   UNRECOGNIZED = -1,
 }
 
@@ -80,10 +90,22 @@ export interface VariableValue {
   sensitive: boolean
 }
 
+/** Progress reports how far provisioning has come. */
+export interface Progress {
+  stage: Stage
+  /** resource is the address of the resource the log is about, if any. */
+  resource: string
+  /** completed is the number of resources that are done in the stage. */
+  completed: number
+  /** total is the number of resources in the stage, or zero if unknown. */
+  total: number
+}
+
 /** Log represents output from a request. */
 export interface Log {
   level: LogLevel
   output: string
+  progress?: Progress | undefined
 }
 
 export interface InstanceIdentityAuth {
@@ -231,6 +253,8 @@ export interface Provision_Plan {
 export interface Provision_Apply {
   config: Provision_Config | undefined
   plan: Uint8Array
+  /** planned_changes is the number of resource changes in the plan. */
+  plannedChanges: number
 }
 
 export interface Provision_Cancel {}
@@ -248,6 +272,8 @@ export interface Provision_Complete {
   parameters: RichParameter[]
   gitAuthProviders: string[]
   plan: Uint8Array
+  /** planned_changes is the number of resource changes in the plan. */
+  plannedChanges: number
 }
 
 export interface Provision_Response {
@@ -393,6 +419,27 @@ export const VariableValue = {
   },
 }
 
+export const Progress = {
+  encode(
+    message: Progress,
+    writer: _m0.Writer = _m0.Writer.create(),
+  ): _m0.Writer {
+    if (message.stage !== 0) {
+      writer.uint32(8).int32(message.stage)
+    }
+    if (message.resource !== "") {
+      writer.uint32(18).string(message.resource)
+    }
+    if (message.completed !== 0) {
+      writer.uint32(24).int32(message.completed)
+    }
+    if (message.total !== 0) {
+      writer.uint32(32).int32(message.total)
+    }
+    return writer
+  },
+}
+
 export const Log = {
   encode(message: Log, writer: _m0.Writer = _m0.Writer.create()): _m0.Writer {
     if (message.level !== 0) {
@@ -401,6 +448,9 @@ export const Log = {
     if (message.output !== "") {
       writer.uint32(18).string(message.output)
     }
+    if (message.progress !== undefined) {
+      Progress.encode(message.progress, writer.uint32(26).fork()).ldelim()
+    }
     return writer
   },
 }
@@ -788,6 +838,9 @@ export const Provision_Apply = {
     if (message.plan.length !== 0) {
       writer.uint32(18).bytes(message.plan)
     }
+    if (message.plannedChanges !== 0) {
+      writer.uint32(24).int32(message.plannedChanges)
+    }
     return writer
   },
 }
@@ -842,6 +895,9 @@ export const Provision_Complete = {
     if (message.plan.length !== 0) {
       writer.uint32(50).bytes(message.plan)
     }
+    if (message.plannedChanges !== 0) {
+      writer.uint32(56).int32(message.plannedChanges)
+    }
     return writer
   },
 }
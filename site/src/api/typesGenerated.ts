@@ -39,11 +39,81 @@ export interface AgentStatsReportResponse {
   readonly tx_bytes: number
 }
 
+// From codersdk/insights.go
+export interface AppAccessLogInsight {
+  readonly user_id: string
+  readonly username: string
+  readonly avatar_url: string
+  readonly template_id: string
+  readonly access_method: string
+  readonly slug_or_port: string
+  readonly path_prefix: string
+  readonly status_code: number
+  readonly requests: number
+  readonly latency_p50_ms: number
+  readonly latency_p95_ms: number
+  readonly last_request_at: string
+}
+
+// From codersdk/insights.go
+export interface AppAccessLogInsightsReport {
+  readonly start_time: string
+  readonly end_time: string
+  readonly template_ids: string[]
+  readonly entries: AppAccessLogInsight[]
+}
+
+// From codersdk/insights.go
+export interface AppAccessLogInsightsRequest {
+  readonly start_time: string
+  readonly end_time: string
+  readonly template_ids: string[]
+}
+
+// From codersdk/insights.go
+export interface AppAccessLogInsightsResponse {
+  readonly report: AppAccessLogInsightsReport
+}
+
 // From codersdk/deployment.go
 export interface AppHostResponse {
   readonly host: string
 }
 
+// From codersdk/insights.go
+export interface AppSessionInsight {
+  readonly user_id: string
+  readonly username: string
+  readonly avatar_url: string
+  readonly template_id: string
+  readonly access_method: string
+  readonly slug_or_port: string
+  readonly sessions: number
+  readonly active_seconds: number
+  readonly first_session_started_at: string
+  readonly last_session_ended_at: string
+}
+
+// From codersdk/insights.go
+export interface AppSessionInsightsReport {
+  readonly start_time: string
+  readonly end_time: string
+  readonly template_ids: string[]
+  readonly apps: AppSessionInsight[]
+}
+
+// From codersdk/insights.go
+export interface AppSessionInsightsRequest {
+  readonly start_time: string
+  readonly end_time: string
+  readonly template_ids: string[]
+}
+
+// From codersdk/insights.go
+export interface AppSessionInsightsResponse {
+  readonly report: AppSessionInsightsReport
+}
+
 // From codersdk/deployment.go
 export interface AppearanceConfig {
   readonly logo_url: string
@@ -70,6 +140,15 @@ export interface AuditDiffField {
   readonly secret: boolean
 }
 
+// From codersdk/deployment.go
+export interface AuditExportConfig {
+  readonly sink: string
+  readonly url: string
+  readonly token: string
+  readonly kafka_topic: string
+  readonly batch_size: number
+}
+
 // From codersdk/audit.go
 export interface AuditLog {
   readonly id: string
@@ -100,6 +179,12 @@ export interface AuditLogResponse {
   readonly count: number
 }
 
+// From codersdk/deployment.go
+export interface AuditLogRetentionConfig {
+  readonly days: number
+  readonly archive_url: string
+}
+
 // From codersdk/audit.go
 export interface AuditLogsRequest extends Pagination {
   readonly q?: string
@@ -139,6 +224,22 @@ export interface AuthorizationRequest {
 // From codersdk/authorization.go
 export type AuthorizationResponse = Record<string, boolean>
 
+// From codersdk/workspaces.go
+export interface BatchWorkspaceResult {
+  readonly workspace_id: string
+  readonly workspace_name: string
+  readonly workspace_owner_name: string
+  readonly status: BatchWorkspaceResultStatus
+  readonly build_id?: string
+  readonly error?: string
+}
+
+// From codersdk/workspaces.go
+export interface BatchWorkspacesRequest {
+  readonly operation: BatchWorkspaceOperation
+  readonly q: string
+}
+
 // From codersdk/deployment.go
 export interface BuildInfoResponse {
   readonly external_url: string
@@ -147,6 +248,42 @@ export interface BuildInfoResponse {
   readonly workspace_proxy: boolean
 }
 
+// From codersdk/insights.go
+export interface BuildInsightsIntervalReport {
+  readonly start_time: string
+  readonly end_time: string
+  readonly interval: InsightsReportInterval
+  readonly template_versions: TemplateVersionBuildInsight[]
+}
+
+// From codersdk/insights.go
+export interface BuildInsightsReport {
+  readonly start_time: string
+  readonly end_time: string
+  readonly template_ids: string[]
+  readonly template_versions: TemplateVersionBuildInsight[]
+}
+
+// From codersdk/insights.go
+export interface BuildInsightsRequest {
+  readonly start_time: string
+  readonly end_time: string
+  readonly template_ids: string[]
+  readonly interval: InsightsReportInterval
+}
+
+// From codersdk/insights.go
+export interface BuildInsightsResponse {
+  readonly report: BuildInsightsReport
+  readonly interval_reports: BuildInsightsIntervalReport[]
+}
+
+// From codersdk/workspaces.go
+export interface CloneWorkspaceRequest {
+  readonly name: string
+  readonly copy_volumes?: boolean
+}
+
 // From codersdk/insights.go
 export interface ConnectionLatency {
   readonly p50: number
@@ -159,6 +296,14 @@ export interface ConvertLoginRequest {
   readonly password: string
 }
 
+// From codersdk/roles.go
+export interface CreateCustomRoleRequest {
+  readonly name: string
+  readonly display_name: string
+  readonly site_permissions: Permission[]
+  readonly user_permissions: Permission[]
+}
+
 // From codersdk/users.go
 export interface CreateFirstUserRequest {
   readonly email: string
@@ -186,6 +331,30 @@ export interface CreateOrganizationRequest {
   readonly name: string
 }
 
+// From codersdk/provisionerkeys.go
+export interface CreateProvisionerKeyRequest {
+  readonly name: string
+  readonly tags: Record<string, string>
+}
+
+// From codersdk/provisionerkeys.go
+export interface CreateProvisionerKeyResponse {
+  readonly provisioner_key: ProvisionerKey
+  readonly key: string
+}
+
+// From codersdk/savedworkspacefilters.go
+export interface CreateSavedWorkspaceFilterRequest {
+  readonly name: string
+  readonly query: string
+}
+
+// From codersdk/scheduleholidays.go
+export interface CreateScheduleHolidayRequest {
+  readonly date: string
+  readonly name: string
+}
+
 // From codersdk/organizations.go
 export interface CreateTemplateRequest {
   readonly name: string
@@ -194,6 +363,7 @@ export interface CreateTemplateRequest {
   readonly icon?: string
   readonly template_version_id: string
   readonly default_ttl_ms?: number
+  readonly activity_bump_ms?: number
   readonly max_ttl_ms?: number
   readonly restart_requirement?: TemplateRestartRequirement
   readonly allow_user_cancel_workspace_jobs?: boolean
@@ -212,6 +382,11 @@ export interface CreateTemplateVersionDryRunRequest {
   readonly user_variable_values?: VariableValue[]
 }
 
+// From codersdk/templateversionpromotions.go
+export interface CreateTemplateVersionPromotionRequest {
+  readonly template_version_id: string
+}
+
 // From codersdk/organizations.go
 export interface CreateTemplateVersionRequest {
   readonly name?: string
@@ -222,7 +397,10 @@ export interface CreateTemplateVersionRequest {
   readonly example_id?: string
   readonly provisioner: ProvisionerType
   readonly tags: Record<string, string>
+  readonly registry_reference?: string
+  readonly registry_auth?: TemplateRegistryAuth
   readonly user_variable_values?: VariableValue[]
+  readonly presets?: TemplateVersionPreset[]
 }
 
 // From codersdk/audit.go
@@ -242,6 +420,11 @@ export interface CreateTokenRequest {
   readonly token_name: string
 }
 
+// From codersdk/users.go
+export interface CreateUserQuietHoursExceptionRequest {
+  readonly date: string
+}
+
 // From codersdk/users.go
 export interface CreateUserRequest {
   readonly email: string
@@ -252,6 +435,13 @@ export interface CreateUserRequest {
   readonly organization_id: string
 }
 
+// From codersdk/usersecrets.go
+export interface CreateUserSecretRequest {
+  readonly name: string
+  readonly description?: string
+  readonly value: string
+}
+
 // From codersdk/workspaces.go
 export interface CreateWorkspaceBuildRequest {
   readonly template_version_id?: string
@@ -259,6 +449,7 @@ export interface CreateWorkspaceBuildRequest {
   readonly dry_run?: boolean
   readonly state?: string
   readonly orphan?: boolean
+  readonly resume?: boolean
   readonly rich_parameter_values?: WorkspaceBuildParameter[]
   readonly log_level?: ProvisionerLogLevel
 }
@@ -279,6 +470,16 @@ export interface CreateWorkspaceRequest {
   readonly rich_parameter_values?: WorkspaceBuildParameter[]
 }
 
+// From codersdk/roles.go
+export interface CustomRole {
+  readonly name: string
+  readonly display_name: string
+  readonly site_permissions: Permission[]
+  readonly user_permissions: Permission[]
+  readonly created_at: string
+  readonly updated_at: string
+}
+
 // From codersdk/deployment.go
 export interface DAUEntry {
   readonly date: string
@@ -307,6 +508,27 @@ export interface DERPConfig {
   readonly block_direct: boolean
   readonly url: string
   readonly path: string
+  readonly reload_interval: number
+}
+
+// From codersdk/derp.go
+export interface DERPHealth {
+  readonly time: string
+  readonly healthy: boolean
+  readonly server: DERPServerStats
+  readonly regions: DERPRegionHealth[]
+}
+
+// From codersdk/derp.go
+export interface DERPNodeHealth {
+  readonly name: string
+  readonly host_name: string
+  readonly healthy: boolean
+  readonly can_exchange_messages: boolean
+  readonly uses_websocket: boolean
+  readonly can_stun: boolean
+  readonly latency_ms: number
+  readonly error?: string
 }
 
 // From codersdk/workspaceagents.go
@@ -315,6 +537,17 @@ export interface DERPRegion {
   readonly latency_ms: number
 }
 
+// From codersdk/derp.go
+export interface DERPRegionHealth {
+  readonly region_id: number
+  readonly region_code: string
+  readonly region_name: string
+  readonly healthy: boolean
+  readonly latency_ms: number
+  readonly nodes: DERPNodeHealth[]
+  readonly error?: string
+}
+
 // From codersdk/deployment.go
 export interface DERPServerConfig {
   readonly enable: boolean
@@ -326,6 +559,18 @@ export interface DERPServerConfig {
   readonly relay_url: string
 }
 
+// From codersdk/derp.go
+export interface DERPServerStats {
+  readonly current_connections: number
+  readonly current_home_connections: number
+  readonly accepts: number
+  readonly bytes_received: number
+  readonly bytes_sent: number
+  readonly packets_received: number
+  readonly packets_sent: number
+  readonly packets_dropped: number
+}
+
 // From codersdk/deployment.go
 export interface DangerousConfig {
   readonly allow_path_app_sharing: boolean
@@ -350,10 +595,12 @@ export interface DeploymentValues {
   readonly docs_url?: string
   readonly redirect_to_access_url?: boolean
   readonly http_address?: string
+  readonly http2_cleartext?: boolean
   readonly autobuild_poll_interval?: number
   readonly job_hang_detector_interval?: number
   readonly derp?: DERP
   readonly prometheus?: PrometheusConfig
+  readonly workspace_app_stats?: WorkspaceAppStatsConfig
   readonly pprof?: PprofConfig
   // This is likely an enum in an external package ("github.com/coder/coder/cli/clibase.StringArray")
   readonly proxy_trusted_headers?: string[]
@@ -372,11 +619,14 @@ export interface DeploymentValues {
   // This is likely an enum in an external package ("github.com/coder/coder/cli/clibase.StringArray")
   readonly strict_transport_security_options?: string[]
   readonly ssh_keygen_algorithm?: string
+  readonly disable_x11_forwarding?: boolean
   readonly metrics_cache_refresh_interval?: number
   readonly agent_stat_refresh_interval?: number
   readonly agent_fallback_troubleshooting_url?: string
+  readonly agent_drain_grace_period?: number
   readonly browser_only?: boolean
   readonly scim_api_key?: string
+  readonly scim_group_template_acls?: any
   readonly provisioner?: ProvisionerConfig
   readonly rate_limit?: RateLimitConfig
   // This is likely an enum in an external package ("github.com/coder/coder/cli/clibase.StringArray")
@@ -400,6 +650,16 @@ export interface DeploymentValues {
   readonly proxy_health_status_interval?: number
   readonly enable_terraform_debug_mode?: boolean
   readonly user_quiet_hours_schedule?: UserQuietHoursScheduleConfig
+  readonly webhooks?: WebhooksConfig
+  readonly audit_export?: AuditExportConfig
+  readonly audit_log_retention?: AuditLogRetentionConfig
+  readonly workspace_app_oidc?: WorkspaceAppOIDCConfig
+  readonly workspace_app_sticky_sessions?: boolean
+  readonly user_secrets_encryption_key?: string
+  readonly template_registries?: string[]
+  readonly server_tailnet_max_idle_per_agent?: number
+  readonly server_tailnet_idle_ttl?: number
+  readonly server_tailnet_health_check_interval?: number
   // This is likely an enum in an external package ("github.com/coder/coder/cli/clibase.YAMLConfigPath")
   readonly config?: string
   readonly write_config?: boolean
@@ -421,6 +681,17 @@ export interface Entitlements {
 // From codersdk/deployment.go
 export type Experiments = Experiment[]
 
+// From codersdk/workspaces.go
+export interface ExtendWorkspaceRequest {
+  readonly duration_ms: number
+}
+
+// From codersdk/workspaces.go
+export interface ExtendWorkspaceResponse {
+  readonly deadline: string
+  readonly remaining_extensions?: number
+}
+
 // From codersdk/deployment.go
 export interface Feature {
   readonly entitlement: Entitlement
@@ -646,6 +917,7 @@ export interface OIDCConfig {
   readonly user_roles_default: string[]
   readonly sign_in_text: string
   readonly icon_url: string
+  readonly token_exchange_policies: any
 }
 
 // From codersdk/organizations.go
@@ -656,6 +928,26 @@ export interface Organization {
   readonly updated_at: string
 }
 
+// From codersdk/organizations.go
+export interface OrganizationBudgetSettings {
+  readonly organization_id: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly daily_budget: number
+  readonly warning_threshold_percent: number
+  readonly critical_threshold_percent: number
+  readonly alerted_threshold_percent: number
+}
+
+// From codersdk/organizations.go
+export interface OrganizationJITProvisioningSettings {
+  readonly organization_id: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly template_id: string
+  readonly workspace_name: string
+}
+
 // From codersdk/organizations.go
 export interface OrganizationMember {
   readonly user_id: string
@@ -665,6 +957,24 @@ export interface OrganizationMember {
   readonly roles: Role[]
 }
 
+// From codersdk/organizations.go
+export interface OrganizationProvisionerSettings {
+  readonly organization_id: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly max_concurrent_jobs: number
+}
+
+// From codersdk/organizations.go
+export interface OrganizationScheduleSettings {
+  readonly organization_id: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly default_ttl_ms: number
+  readonly restart_requirement: TemplateRestartRequirement
+  readonly default_quiet_hours_schedule: string
+}
+
 // From codersdk/pagination.go
 export interface Pagination {
   readonly after_id?: string
@@ -672,6 +982,32 @@ export interface Pagination {
   readonly offset?: number
 }
 
+// From codersdk/organizations.go
+export interface OrganizationTemplateVariable {
+  readonly name: string
+  readonly value: string
+  readonly sensitive: boolean
+  readonly created_at: string
+  readonly updated_at: string
+}
+
+// From codersdk/templateparametervalidation.go
+export interface ParameterValidationRequest {
+  readonly template_id: string
+  readonly template_version_id: string
+  readonly workspace_id: string
+  readonly workspace_name: string
+  readonly owner_id: string
+  readonly initiator_id: string
+  readonly transition: WorkspaceTransition
+  readonly parameters: WorkspaceBuildParameter[]
+}
+
+// From codersdk/templateparametervalidation.go
+export interface ParameterValidationResponse {
+  readonly validations: ValidationError[]
+}
+
 // From codersdk/groups.go
 export interface PatchGroupRequest {
   readonly add_users: string[]
@@ -697,6 +1033,13 @@ export interface PatchWorkspaceProxy {
   readonly regenerate_token: boolean
 }
 
+// From codersdk/roles.go
+export interface Permission {
+  readonly negate: boolean
+  readonly resource_type: RBACResource
+  readonly action: string
+}
+
 // From codersdk/deployment.go
 export interface PprofConfig {
   readonly enable: boolean
@@ -723,6 +1066,7 @@ export interface ProvisionerConfig {
   readonly daemon_poll_jitter: number
   readonly force_cancel_interval: number
   readonly daemon_psk: string
+  readonly daemon_cache_max_size: number
 }
 
 // From codersdk/provisionerdaemons.go
@@ -733,6 +1077,16 @@ export interface ProvisionerDaemon {
   readonly name: string
   readonly provisioners: ProvisionerType[]
   readonly tags: Record<string, string>
+  readonly cache: ProvisionerDaemonCache
+}
+
+// From codersdk/provisionerdaemons.go
+export interface ProvisionerDaemonCache {
+  readonly entries: number
+  readonly size_bytes: number
+  readonly max_size_bytes: number
+  readonly hits: number
+  readonly misses: number
 }
 
 // From codersdk/provisionerdaemons.go
@@ -760,6 +1114,25 @@ export interface ProvisionerJobLog {
   readonly log_level: LogLevel
   readonly stage: string
   readonly output: string
+  readonly progress?: ProvisionerJobLogProgress
+}
+
+// From codersdk/provisionerdaemons.go
+export interface ProvisionerJobLogProgress {
+  readonly stage: ProvisionerJobLogStage
+  readonly resource?: string
+  readonly completed: number
+  readonly total: number
+}
+
+// From codersdk/provisionerkeys.go
+export interface ProvisionerKey {
+  readonly id: string
+  readonly created_at: string
+  readonly organization_id: string
+  readonly name: string
+  readonly tags: Record<string, string>
+  readonly last_used_at?: string
 }
 
 // From codersdk/workspaceproxy.go
@@ -768,6 +1141,18 @@ export interface ProxyHealthReport {
   readonly warnings: string[]
 }
 
+// From codersdk/templateversions.go
+export interface PushTemplateVersionRequest {
+  readonly reference: string
+  readonly auth?: TemplateRegistryAuth
+}
+
+// From codersdk/templateversions.go
+export interface PushTemplateVersionResponse {
+  readonly reference: string
+  readonly digest: string
+}
+
 // From codersdk/workspaces.go
 export interface PutExtendWorkspaceRequest {
   readonly deadline: string
@@ -832,6 +1217,45 @@ export interface SSHConfigResponse {
   readonly ssh_config_options: Record<string, string>
 }
 
+// From codersdk/savedworkspacefilters.go
+export interface SavedWorkspaceFilter {
+  readonly id: string
+  readonly user_id: string
+  readonly name: string
+  readonly query: string
+  readonly created_at: string
+  readonly updated_at: string
+}
+
+// From codersdk/scheduleholidays.go
+export interface ScheduleHoliday {
+  readonly id: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly date: string
+  readonly name: string
+}
+
+// From codersdk/insights.go
+export interface ScheduleInsightsReport {
+  readonly start_time: string
+  readonly end_time: string
+  readonly template_ids: string[]
+  readonly templates: TemplateScheduleInsight[]
+}
+
+// From codersdk/insights.go
+export interface ScheduleInsightsRequest {
+  readonly start_time: string
+  readonly end_time: string
+  readonly template_ids: string[]
+}
+
+// From codersdk/insights.go
+export interface ScheduleInsightsResponse {
+  readonly report: ScheduleInsightsReport
+}
+
 // From codersdk/serversentevents.go
 export interface ServerSentEvent {
   readonly type: ServerSentEventType
@@ -883,6 +1307,13 @@ export interface TLSConfig {
   readonly min_version: string
   readonly client_cert_file: string
   readonly client_key_file: string
+  readonly acme_enable: boolean
+  readonly acme_directory_url: string
+  readonly acme_email: string
+  readonly acme_dns_hook: string
+  readonly acme_encryption_key: string
+  readonly proxy_client_ca_file: string
+  readonly proxy_require_client_cert: boolean
 }
 
 // From codersdk/deployment.go
@@ -905,8 +1336,10 @@ export interface Template {
   readonly active_user_count: number
   readonly build_time_stats: TemplateBuildTimeStats
   readonly description: string
+  readonly disable_autostop: boolean
   readonly icon: string
   readonly default_ttl_ms: number
+  readonly activity_bump_ms: number
   readonly max_ttl_ms: number
   readonly restart_requirement: TemplateRestartRequirement
   readonly created_by_id: string
@@ -914,9 +1347,21 @@ export interface Template {
   readonly allow_user_autostart: boolean
   readonly allow_user_autostop: boolean
   readonly allow_user_cancel_workspace_jobs: boolean
+  readonly max_concurrent_builds: number
+  readonly max_deadline_extensions_per_day: number
+  readonly max_deadline_extension_ms: number
+  readonly required_promotion_approvals: number
+  readonly user_cpu_quota_millicores: number
+  readonly user_memory_quota_bytes: number
+  readonly user_disk_quota_bytes: number
+  readonly apply_dotfiles: boolean
   readonly failure_ttl_ms: number
   readonly inactivity_ttl_ms: number
   readonly locked_ttl_ms: number
+  readonly failure_retries: number
+  readonly failure_retry_backoff_ms: number
+  readonly max_lifetime_ms: number
+  readonly archive_retention_ms: number
 }
 
 // From codersdk/templates.go
@@ -941,6 +1386,18 @@ export type TemplateBuildTimeStats = Record<
   TransitionStats
 >
 
+// From codersdk/templates.go
+export interface TemplateDeadlineRecalculation {
+  readonly id: string
+  readonly template_id: string
+  readonly status: TemplateDeadlineRecalculationStatus
+  readonly created_at: string
+  readonly completed_at?: string
+  readonly total_builds: number
+  readonly processed_builds: number
+  readonly error?: string
+}
+
 // From codersdk/templates.go
 export interface TemplateExample {
   readonly id: string
@@ -990,6 +1447,15 @@ export interface TemplateInsightsResponse {
   readonly interval_reports: TemplateInsightsIntervalReport[]
 }
 
+// From codersdk/templateparametervalidation.go
+export interface TemplateParameterConstraint {
+  readonly parameter: string
+  readonly regex: string
+  readonly when_parameter?: string
+  readonly when_regex?: string
+  readonly error?: string
+}
+
 // From codersdk/insights.go
 export interface TemplateParameterUsage {
   readonly template_ids: string[]
@@ -1001,16 +1467,74 @@ export interface TemplateParameterUsage {
   readonly values: TemplateParameterValue[]
 }
 
-// From codersdk/insights.go
-export interface TemplateParameterValue {
-  readonly value: string
-  readonly count: number
+// From codersdk/insights.go
+export interface TemplateParameterValue {
+  readonly value: string
+  readonly count: number
+}
+
+// From codersdk/templateparametervalidation.go
+export interface TemplateParameterValidation {
+  readonly template_id: string
+  readonly webhook_url: string
+  readonly constraints: TemplateParameterConstraint[]
+}
+
+// From codersdk/templateprebuilds.go
+export interface TemplatePrebuilds {
+  readonly template_id: string
+  readonly size: number
+  readonly ready: number
+  readonly building: number
+}
+
+// From codersdk/templateversions.go
+export interface TemplateRegistryAuth {
+  readonly username: string
+  readonly password: string
 }
 
 // From codersdk/templates.go
 export interface TemplateRestartRequirement {
   readonly days_of_week: string[]
   readonly weeks: number
+  readonly timezone?: string
+  readonly spread_ms?: number
+  readonly update_on_restart?: boolean
+}
+
+// From codersdk/templates.go
+export interface TemplateScheduleDryRun {
+  readonly workspaces: TemplateScheduleDryRunWorkspace[]
+}
+
+// From codersdk/templates.go
+export interface TemplateScheduleDryRunWorkspace {
+  readonly workspace_id: string
+  readonly workspace_name: string
+  readonly owner_id: string
+  readonly workspace_build_id: string
+  readonly deadline?: string
+  readonly max_deadline?: string
+  readonly new_deadline?: string
+  readonly new_max_deadline?: string
+}
+
+// From codersdk/insights.go
+export interface TemplateScheduleInsight {
+  readonly template_id: string
+  readonly allocated_seconds: number
+  readonly running_seconds: number
+  readonly stopped_seconds: number
+  readonly schedule_stopped_seconds: number
+  readonly autostarts: number
+  readonly autostops: number
+}
+
+// From codersdk/templates.go
+export interface TemplateSchedulePolicy {
+  readonly policy_template_id?: string
+  readonly inherited_by: string[]
 }
 
 // From codersdk/templates.go
@@ -1033,6 +1557,20 @@ export interface TemplateVersion {
   readonly warnings?: TemplateVersionWarning[]
 }
 
+// From codersdk/insights.go
+export interface TemplateVersionBuildInsight {
+  readonly template_id: string
+  readonly template_version_id: string
+  readonly template_version_name: string
+  readonly builds: number
+  readonly failed_builds: number
+  readonly failure_rate: number
+  readonly build_duration_p50_ms: number
+  readonly build_duration_p95_ms: number
+  readonly agent_connect_p50_ms: number
+  readonly agent_connect_p95_ms: number
+}
+
 // From codersdk/templateversions.go
 export interface TemplateVersionGitAuth {
   readonly id: string
@@ -1069,6 +1607,26 @@ export interface TemplateVersionParameterOption {
   readonly icon: string
 }
 
+// From codersdk/templateversions.go
+export interface TemplateVersionPreset {
+  readonly name: string
+  readonly description?: string
+  readonly parameters: WorkspaceBuildParameter[]
+}
+
+// From codersdk/templateversionpromotions.go
+export interface TemplateVersionPromotion {
+  readonly id: string
+  readonly template_id: string
+  readonly template_version_id: string
+  readonly requested_by: string
+  readonly approved_by: string[]
+  readonly required_approvals: number
+  readonly status: TemplateVersionPromotionStatus
+  readonly created_at: string
+  readonly updated_at: string
+}
+
 // From codersdk/templateversions.go
 export interface TemplateVersionVariable {
   readonly name: string
@@ -1090,6 +1648,31 @@ export interface TokenConfig {
   readonly max_token_lifetime: number
 }
 
+// From codersdk/deployment.go
+export interface TokenExchangePolicy {
+  readonly issuer: string
+  readonly audience: string
+  readonly claims: Record<string, string>
+  readonly username: string
+  readonly scope?: APIKeyScope
+  readonly lifetime?: number
+}
+
+// From codersdk/tokenexchange.go
+export interface TokenExchangeRequest {
+  readonly grant_type: string
+  readonly subject_token: string
+  readonly subject_token_type?: string
+}
+
+// From codersdk/tokenexchange.go
+export interface TokenExchangeResponse {
+  readonly access_token: string
+  readonly issued_token_type: string
+  readonly token_type: string
+  readonly expires_in: number
+}
+
 // From codersdk/apikey.go
 export interface TokensFilter {
   readonly include_all: boolean
@@ -1126,11 +1709,54 @@ export interface UpdateCheckResponse {
   readonly url: string
 }
 
+// From codersdk/roles.go
+export interface UpdateCustomRoleRequest {
+  readonly display_name: string
+  readonly site_permissions: Permission[]
+  readonly user_permissions: Permission[]
+}
+
+// From codersdk/organizations.go
+export interface UpdateOrganizationBudgetSettingsRequest {
+  readonly daily_budget: number
+  readonly warning_threshold_percent: number
+  readonly critical_threshold_percent: number
+}
+
+// From codersdk/organizations.go
+export interface UpdateOrganizationJITProvisioningSettingsRequest {
+  readonly template_id: string
+  readonly workspace_name: string
+}
+
+// From codersdk/organizations.go
+export interface UpdateOrganizationProvisionerSettingsRequest {
+  readonly max_concurrent_jobs: number
+}
+
+// From codersdk/organizations.go
+export interface UpdateOrganizationScheduleSettingsRequest {
+  readonly default_ttl_ms: number
+  readonly restart_requirement: TemplateRestartRequirement
+  readonly default_quiet_hours_schedule: string
+}
+
 // From codersdk/users.go
 export interface UpdateRoles {
   readonly roles: string[]
 }
 
+// From codersdk/savedworkspacefilters.go
+export interface UpdateSavedWorkspaceFilterRequest {
+  readonly query: string
+}
+
+// From codersdk/scheduleholidays.go
+export interface UpdateScheduleHolidayRequest {
+  readonly date: string
+  readonly name: string
+}
+
 // From codersdk/templates.go
 export interface UpdateTemplateACL {
   readonly user_perms?: Record<string, TemplateRole>
@@ -1144,6 +1770,7 @@ export interface UpdateTemplateMeta {
   readonly description?: string
   readonly icon?: string
   readonly default_ttl_ms?: number
+  readonly activity_bump_ms?: number
   readonly max_ttl_ms?: number
   readonly restart_requirement?: TemplateRestartRequirement
   readonly allow_user_autostart?: boolean
@@ -1152,6 +1779,42 @@ export interface UpdateTemplateMeta {
   readonly failure_ttl_ms?: number
   readonly inactivity_ttl_ms?: number
   readonly locked_ttl_ms?: number
+  readonly failure_retries?: number
+  readonly failure_retry_backoff_ms?: number
+  readonly max_lifetime_ms?: number
+  readonly archive_retention_ms?: number
+  readonly max_concurrent_builds?: number
+  readonly max_deadline_extensions_per_day?: number
+  readonly max_deadline_extension_ms?: number
+  readonly required_promotion_approvals?: number
+  readonly user_cpu_quota_millicores?: number
+  readonly user_memory_quota_bytes?: number
+  readonly user_disk_quota_bytes?: number
+  readonly apply_dotfiles?: boolean
+  readonly disable_autostop?: boolean
+  readonly dry_run?: boolean
+}
+
+// From codersdk/templateparametervalidation.go
+export interface UpdateTemplateParameterValidationRequest {
+  readonly webhook_url: string
+  readonly constraints: TemplateParameterConstraint[]
+}
+
+// From codersdk/templateprebuilds.go
+export interface UpdateTemplatePrebuildsRequest {
+  readonly size: number
+}
+
+// From codersdk/templates.go
+export interface UpdateTemplateSchedulePolicyRequest {
+  readonly policy_template_id?: string
+}
+
+// From codersdk/userdotfiles.go
+export interface UpdateUserDotfilesRequest {
+  readonly repo_url: string
+  readonly branch?: string
 }
 
 // From codersdk/users.go
@@ -1170,6 +1833,17 @@ export interface UpdateUserQuietHoursScheduleRequest {
   readonly schedule: string
 }
 
+// From codersdk/usersecrets.go
+export interface UpdateUserSecretRequest {
+  readonly description?: string
+  readonly value?: string
+}
+
+// From codersdk/workspaces.go
+export interface UpdateWorkspaceACL {
+  readonly group_perms?: Record<string, WorkspaceRole>
+}
+
 // From codersdk/workspaces.go
 export interface UpdateWorkspaceAutostartRequest {
   readonly schedule?: string
@@ -1191,6 +1865,13 @@ export interface UpdateWorkspaceRequest {
   readonly name?: string
 }
 
+// From codersdk/workspaces.go
+export interface UpdateWorkspaceScheduleOverrideRequest {
+  readonly default_ttl_ms: number
+  readonly max_ttl_ms: number
+  readonly restart_requirement: TemplateRestartRequirement
+}
+
 // From codersdk/workspaces.go
 export interface UpdateWorkspaceTTLRequest {
   readonly ttl_ms?: number
@@ -1201,6 +1882,12 @@ export interface UploadResponse {
   readonly hash: string
 }
 
+// From codersdk/organizations.go
+export interface UpsertOrganizationTemplateVariableRequest {
+  readonly value: string
+  readonly sensitive: boolean
+}
+
 // From codersdk/users.go
 export interface User {
   readonly id: string
@@ -1215,6 +1902,14 @@ export interface User {
   readonly login_type: LoginType
 }
 
+// From codersdk/userdotfiles.go
+export interface UserDotfiles {
+  readonly user_id: string
+  readonly repo_url: string
+  readonly branch: string
+  readonly updated_at: string
+}
+
 // From codersdk/insights.go
 export interface UserLatency {
   readonly template_ids: string[]
@@ -1261,6 +1956,14 @@ export interface UserQuietHoursScheduleResponse {
   readonly time: string
   readonly timezone: string
   readonly next: string
+  readonly exceptions: string[]
+}
+
+// From codersdk/workspaces.go
+export interface UserQuota {
+  readonly credits_consumed: number
+  readonly budget: number
+  readonly workspaces: WorkspaceQuotaUsage[]
 }
 
 // From codersdk/users.go
@@ -1269,6 +1972,16 @@ export interface UserRoles {
   readonly organization_roles: Record<string, string[]>
 }
 
+// From codersdk/usersecrets.go
+export interface UserSecret {
+  readonly id: string
+  readonly user_id: string
+  readonly name: string
+  readonly description: string
+  readonly created_at: string
+  readonly updated_at: string
+}
+
 // From codersdk/users.go
 export interface UsersRequest extends Pagination {
   readonly q?: string
@@ -1286,6 +1999,15 @@ export interface VariableValue {
   readonly value: string
 }
 
+// From codersdk/deployment.go
+export interface WebhooksConfig {
+  readonly urls: string[]
+  readonly signing_secret: string
+  readonly max_attempts: number
+  readonly autostop_imminent_window: number
+  readonly parameter_validation_hosts: string[]
+}
+
 // From codersdk/workspaces.go
 export interface Workspace {
   readonly id: string
@@ -1307,9 +2029,15 @@ export interface Workspace {
   readonly last_used_at: string
   readonly deleting_at?: string
   readonly locked_at?: string
+  readonly lock_reason?: WorkspaceLockReason
   readonly health: WorkspaceHealth
 }
 
+// From codersdk/workspaces.go
+export interface WorkspaceACL {
+  readonly groups: WorkspaceGroup[]
+}
+
 // From codersdk/workspaceagents.go
 export interface WorkspaceAgent {
   readonly id: string
@@ -1347,6 +2075,33 @@ export interface WorkspaceAgent {
   readonly health: WorkspaceAgentHealth
 }
 
+// From codersdk/workspaceagentconn.go
+export interface WorkspaceAgentDebugLogFile {
+  readonly name: string
+  readonly truncated: boolean
+  readonly content: string
+}
+
+// From codersdk/workspaceagentconn.go
+export interface WorkspaceAgentDebugLogsResponse {
+  readonly files: WorkspaceAgentDebugLogFile[]
+}
+
+// From codersdk/workspaceagents.go
+export interface WorkspaceAgentDevcontainer {
+  readonly workspace_folder: string
+  readonly config_path?: string
+}
+
+// From codersdk/workspaceagentconn.go
+export interface WorkspaceAgentFileInfo {
+  readonly path: string
+  readonly size: number
+  readonly mode: number
+  readonly mod_time: string
+  readonly is_dir: boolean
+}
+
 // From codersdk/workspaceagents.go
 export interface WorkspaceAgentHealth {
   readonly healthy: boolean
@@ -1371,6 +2126,7 @@ export interface WorkspaceAgentLog {
   readonly created_at: string
   readonly output: string
   readonly level: LogLevel
+  readonly source: WorkspaceAgentLogSource
 }
 
 // From codersdk/workspaceagents.go
@@ -1386,6 +2142,7 @@ export interface WorkspaceAgentMetadataDescription {
   readonly script: string
   readonly interval: number
   readonly timeout: number
+  readonly plugin: boolean
 }
 
 // From codersdk/workspaceagents.go
@@ -1396,6 +2153,26 @@ export interface WorkspaceAgentMetadataResult {
   readonly error: string
 }
 
+// From codersdk/workspaceagents.go
+export interface WorkspaceAgentScript {
+  readonly name: string
+  readonly script: string
+  readonly run_on_start: boolean
+  readonly run_on_stop: boolean
+  readonly timeout_seconds: number
+  readonly continue_on_error: boolean
+  readonly status: WorkspaceAgentScriptStatus
+  readonly exit_code: number
+  readonly started_at?: string
+  readonly ended_at?: string
+}
+
+// From codersdk/workspaceagentconn.go
+export interface WorkspaceAgentUploadFileOptions {
+  readonly Offset: number
+  readonly Mode: number
+}
+
 // From codersdk/workspaceapps.go
 export interface WorkspaceApp {
   readonly id: string
@@ -1407,10 +2184,40 @@ export interface WorkspaceApp {
   readonly icon?: string
   readonly subdomain: boolean
   readonly sharing_level: WorkspaceAppSharingLevel
+  readonly auth: WorkspaceAppAuth
   readonly healthcheck: Healthcheck
   readonly health: WorkspaceAppHealth
 }
 
+// From codersdk/deployment.go
+export interface WorkspaceAppOIDCConfig {
+  readonly issuer_url: string
+  readonly client_id: string
+  readonly client_secret: string
+  // This is likely an enum in an external package ("github.com/coder/coder/cli/clibase.StringArray")
+  readonly email_domain: string[]
+}
+
+// From codersdk/deployment.go
+export interface WorkspaceAppStatsConfig {
+  readonly statsd_address: string
+  readonly otlp_endpoint: string
+  readonly access_logs: boolean
+}
+
+// From codersdk/workspaces.go
+export interface WorkspaceBatch {
+  readonly id: string
+  readonly operation: BatchWorkspaceOperation
+  readonly q: string
+  readonly status: WorkspaceBatchStatus
+  readonly error?: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly completed_at?: string
+  readonly results: BatchWorkspaceResult[]
+}
+
 // From codersdk/workspacebuilds.go
 export interface WorkspaceBuild {
   readonly id: string
@@ -1441,6 +2248,24 @@ export interface WorkspaceBuildParameter {
   readonly value: string
 }
 
+// From codersdk/workspacebuilds.go
+export interface WorkspaceBuildTimeline {
+  readonly workspace_build_id: string
+  readonly trace_id?: string
+  readonly entries: WorkspaceBuildTimelineEntry[]
+}
+
+// From codersdk/workspacebuilds.go
+export interface WorkspaceBuildTimelineEntry {
+  readonly stage: WorkspaceBuildTimelineStage
+  readonly agent_id?: string
+  readonly agent_name?: string
+  readonly script_name?: string
+  readonly started_at: string
+  readonly ended_at?: string
+  readonly duration_ms: number
+}
+
 // From codersdk/workspaces.go
 export interface WorkspaceBuildsRequest extends Pagination {
   readonly WorkspaceID: string
@@ -1470,6 +2295,11 @@ export interface WorkspaceFilter {
   readonly q?: string
 }
 
+// From codersdk/workspaces.go
+export interface WorkspaceGroup extends Group {
+  readonly role: WorkspaceRole
+}
+
 // From codersdk/workspaces.go
 export interface WorkspaceHealth {
   readonly healthy: boolean
@@ -1510,6 +2340,16 @@ export interface WorkspaceQuota {
   readonly budget: number
 }
 
+// From codersdk/workspaces.go
+export interface WorkspaceQuotaUsage {
+  readonly workspace_id: string
+  readonly workspace_name: string
+  readonly template_id: string
+  readonly template_name: string
+  readonly transition: WorkspaceTransition
+  readonly daily_cost: number
+}
+
 // From codersdk/workspacebuilds.go
 export interface WorkspaceResource {
   readonly id: string
@@ -1532,6 +2372,16 @@ export interface WorkspaceResourceMetadata {
   readonly sensitive: boolean
 }
 
+// From codersdk/workspaces.go
+export interface WorkspaceScheduleOverride {
+  readonly workspace_id: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly default_ttl_ms: number
+  readonly max_ttl_ms: number
+  readonly restart_requirement: TemplateRestartRequirement
+}
+
 // From codersdk/workspaces.go
 export interface WorkspacesRequest extends Pagination {
   readonly q?: string
@@ -1544,8 +2394,21 @@ export interface WorkspacesResponse {
 }
 
 // From codersdk/apikey.go
-export type APIKeyScope = "all" | "application_connect"
-export const APIKeyScopes: APIKeyScope[] = ["all", "application_connect"]
+export type APIKeyScope =
+  | "all"
+  | "application_connect"
+  | "template:read"
+  | "workspace:apps"
+  | "workspace:read"
+  | "workspace:ssh"
+export const APIKeyScopes: APIKeyScope[] = [
+  "all",
+  "application_connect",
+  "template:read",
+  "workspace:apps",
+  "workspace:read",
+  "workspace:ssh",
+]
 
 // From codersdk/workspaceagents.go
 export type AgentSubsystem = "envbox" | "envbuilder" | "exectrace"
@@ -1576,12 +2439,53 @@ export const AuditActions: AuditAction[] = [
   "write",
 ]
 
+// From codersdk/workspaces.go
+export type BatchWorkspaceOperation = "delete" | "start" | "stop" | "update"
+export const BatchWorkspaceOperations: BatchWorkspaceOperation[] = [
+  "delete",
+  "start",
+  "stop",
+  "update",
+]
+
+// From codersdk/workspaces.go
+export type BatchWorkspaceResultStatus =
+  | "failed"
+  | "pending"
+  | "queued"
+  | "skipped"
+export const BatchWorkspaceResultStatuses: BatchWorkspaceResultStatus[] = [
+  "failed",
+  "pending",
+  "queued",
+  "skipped",
+]
+
 // From codersdk/workspacebuilds.go
-export type BuildReason = "autostart" | "autostop" | "initiator"
+export type BuildReason =
+  | "admin_forced"
+  | "autostart"
+  | "autostop"
+  | "failure_ttl"
+  | "inactivity_ttl"
+  | "initiator"
+  | "locked_ttl"
+  | "max_lifetime"
+  | "prebuild"
+  | "restart_requirement"
+  | "resume"
 export const BuildReasons: BuildReason[] = [
+  "admin_forced",
   "autostart",
   "autostop",
+  "failure_ttl",
+  "inactivity_ttl",
   "initiator",
+  "locked_ttl",
+  "max_lifetime",
+  "prebuild",
+  "restart_requirement",
+  "resume",
 ]
 
 // From codersdk/deployment.go
@@ -1600,6 +2504,7 @@ export type Experiment =
   | "tailnet_pg_coordinator"
   | "template_restart_requirement"
   | "workspace_actions"
+  | "workspace_schedule_overrides"
   | "workspaces_batch_actions"
 export const Experiments: Experiment[] = [
   "deployment_health_page",
@@ -1608,6 +2513,7 @@ export const Experiments: Experiment[] = [
   "tailnet_pg_coordinator",
   "template_restart_requirement",
   "workspace_actions",
+  "workspace_schedule_overrides",
   "workspaces_batch_actions",
 ]
 
@@ -1619,12 +2525,15 @@ export type FeatureName =
   | "browser_only"
   | "external_provisioner_daemons"
   | "high_availability"
+  | "jit_provisioning"
   | "multiple_git_auth"
   | "scim"
   | "template_rbac"
+  | "template_resource_quotas"
   | "template_restart_requirement"
   | "user_limit"
   | "user_role_management"
+  | "workspace_app_oidc"
   | "workspace_proxy"
 export const FeatureNames: FeatureName[] = [
   "advanced_template_scheduling",
@@ -1633,27 +2542,36 @@ export const FeatureNames: FeatureName[] = [
   "browser_only",
   "external_provisioner_daemons",
   "high_availability",
+  "jit_provisioning",
   "multiple_git_auth",
   "scim",
   "template_rbac",
+  "template_resource_quotas",
   "template_restart_requirement",
   "user_limit",
   "user_role_management",
+  "workspace_app_oidc",
   "workspace_proxy",
 ]
 
 // From codersdk/workspaceagents.go
-export type GitProvider = "azure-devops" | "bitbucket" | "github" | "gitlab"
+export type GitProvider =
+  | "azure-devops"
+  | "bitbucket"
+  | "bitbucket-server"
+  | "github"
+  | "gitlab"
 export const GitProviders: GitProvider[] = [
   "azure-devops",
   "bitbucket",
+  "bitbucket-server",
   "github",
   "gitlab",
 ]
 
 // From codersdk/groups.go
-export type GroupSource = "oidc" | "user"
-export const GroupSources: GroupSource[] = ["oidc", "user"]
+export type GroupSource = "oidc" | "scim" | "user"
+export const GroupSources: GroupSource[] = ["oidc", "scim", "user"]
 
 // From codersdk/insights.go
 export type InsightsReportInterval = "day"
@@ -1687,6 +2605,14 @@ export const LoginTypes: LoginType[] = [
   "token",
 ]
 
+// From codersdk/provisionerdaemons.go
+export type ProvisionerJobLogStage = "apply" | "init" | "plan"
+export const ProvisionerJobLogStages: ProvisionerJobLogStage[] = [
+  "apply",
+  "init",
+  "plan",
+]
+
 // From codersdk/provisionerdaemons.go
 export type ProvisionerJobStatus =
   | "canceled"
@@ -1736,6 +2662,7 @@ export type RBACResource =
   | "assign_org_role"
   | "assign_role"
   | "audit_log"
+  | "custom_role"
   | "debug_info"
   | "deployment_config"
   | "deployment_stats"
@@ -1759,6 +2686,7 @@ export const RBACResources: RBACResource[] = [
   "assign_org_role",
   "assign_role",
   "audit_log",
+  "custom_role",
   "debug_info",
   "deployment_config",
   "deployment_stats",
@@ -1782,22 +2710,32 @@ export const RBACResources: RBACResource[] = [
 export type ResourceType =
   | "api_key"
   | "convert_login"
+  | "custom_role"
   | "git_ssh_key"
   | "group"
   | "license"
+  | "organization_template_variable"
+  | "provisioner_key"
   | "template"
+  | "template_parameter_validation"
   | "template_version"
+  | "template_version_promotion"
   | "user"
   | "workspace"
   | "workspace_build"
 export const ResourceTypes: ResourceType[] = [
   "api_key",
   "convert_login",
+  "custom_role",
   "git_ssh_key",
   "group",
   "license",
+  "organization_template_variable",
+  "provisioner_key",
   "template",
+  "template_parameter_validation",
   "template_version",
+  "template_version_promotion",
   "user",
   "workspace",
   "workspace_build",
@@ -1815,10 +2753,27 @@ export const ServerSentEventTypes: ServerSentEventType[] = [
 export type TemplateAppsType = "builtin"
 export const TemplateAppsTypes: TemplateAppsType[] = ["builtin"]
 
+// From codersdk/templates.go
+export type TemplateDeadlineRecalculationStatus =
+  | "failed"
+  | "pending"
+  | "running"
+  | "succeeded"
+export const TemplateDeadlineRecalculationStatuses: TemplateDeadlineRecalculationStatus[] =
+  ["failed", "pending", "running", "succeeded"]
+
 // From codersdk/templates.go
 export type TemplateRole = "" | "admin" | "use"
 export const TemplateRoles: TemplateRole[] = ["", "admin", "use"]
 
+// From codersdk/templateversionpromotions.go
+export type TemplateVersionPromotionStatus =
+  | "pending"
+  | "promoted"
+  | "rejected"
+export const TemplateVersionPromotionStatuses: TemplateVersionPromotionStatus[] =
+  ["pending", "promoted", "rejected"]
+
 // From codersdk/templateversions.go
 export type TemplateVersionWarning = "UNSUPPORTED_WORKSPACES"
 export const TemplateVersionWarnings: TemplateVersionWarning[] = [
@@ -1861,6 +2816,8 @@ export const WorkspaceAgentLifecycles: WorkspaceAgentLifecycle[] = [
 
 // From codersdk/workspaceagents.go
 export type WorkspaceAgentLogSource =
+  | "devcontainer"
+  | "dotfiles"
   | "envbox"
   | "envbuilder"
   | "external"
@@ -1868,6 +2825,8 @@ export type WorkspaceAgentLogSource =
   | "shutdown_script"
   | "startup_script"
 export const WorkspaceAgentLogSources: WorkspaceAgentLogSource[] = [
+  "devcontainer",
+  "dotfiles",
   "envbox",
   "envbuilder",
   "external",
@@ -1876,6 +2835,23 @@ export const WorkspaceAgentLogSources: WorkspaceAgentLogSource[] = [
   "startup_script",
 ]
 
+// From codersdk/workspaceagents.go
+export type WorkspaceAgentScriptStatus =
+  | "error"
+  | "ok"
+  | "pending"
+  | "running"
+  | "skipped"
+  | "timed_out"
+export const WorkspaceAgentScriptStatuses: WorkspaceAgentScriptStatus[] = [
+  "error",
+  "ok",
+  "pending",
+  "running",
+  "skipped",
+  "timed_out",
+]
+
 // From codersdk/workspaceagents.go
 export type WorkspaceAgentStartupScriptBehavior = "blocking" | "non-blocking"
 export const WorkspaceAgentStartupScriptBehaviors: WorkspaceAgentStartupScriptBehavior[] =
@@ -1894,6 +2870,10 @@ export const WorkspaceAgentStatuses: WorkspaceAgentStatus[] = [
   "timeout",
 ]
 
+// From codersdk/workspaceapps.go
+export type WorkspaceAppAuth = "coder" | "oidc"
+export const WorkspaceAppAuths: WorkspaceAppAuth[] = ["coder", "oidc"]
+
 // From codersdk/workspaceapps.go
 export type WorkspaceAppHealth =
   | "disabled"
@@ -1915,6 +2895,50 @@ export const WorkspaceAppSharingLevels: WorkspaceAppSharingLevel[] = [
   "public",
 ]
 
+// From codersdk/workspaces.go
+export type WorkspaceBatchStatus =
+  | "completed"
+  | "failed"
+  | "pending"
+  | "running"
+export const WorkspaceBatchStatuses: WorkspaceBatchStatus[] = [
+  "completed",
+  "failed",
+  "pending",
+  "running",
+]
+
+// From codersdk/workspacebuilds.go
+export type WorkspaceBuildTimelineStage =
+  | "agent_connect"
+  | "agent_startup"
+  | "apply"
+  | "init"
+  | "plan"
+  | "queued"
+  | "startup_script"
+export const WorkspaceBuildTimelineStages: WorkspaceBuildTimelineStage[] = [
+  "agent_connect",
+  "agent_startup",
+  "apply",
+  "init",
+  "plan",
+  "queued",
+  "startup_script",
+]
+
+// From codersdk/workspaces.go
+export type WorkspaceLockReason = "inactivity" | "manual" | "max_lifetime"
+export const WorkspaceLockReasons: WorkspaceLockReason[] = [
+  "inactivity",
+  "manual",
+  "max_lifetime",
+]
+
+// From codersdk/workspaces.go
+export type WorkspaceRole = "" | "full" | "read"
+export const WorkspaceRoles: WorkspaceRole[] = ["", "full", "read"]
+
 // From codersdk/workspacebuilds.go
 export type WorkspaceStatus =
   | "canceled"
@@ -1941,8 +2965,9 @@ export const WorkspaceStatuses: WorkspaceStatus[] = [
 ]
 
 // From codersdk/workspacebuilds.go
-export type WorkspaceTransition = "delete" | "start" | "stop"
+export type WorkspaceTransition = "archive" | "delete" | "start" | "stop"
 export const WorkspaceTransitions: WorkspaceTransition[] = [
+  "archive",
   "delete",
   "start",
   "stop",
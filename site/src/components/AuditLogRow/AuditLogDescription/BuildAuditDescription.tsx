@@ -13,7 +13,8 @@ export const BuildAuditDescription: FC<{ auditLog: AuditLog }> = ({
   // workspaces can be started/stopped/deleted by a user, or kicked off automatically by Coder
   const user =
     auditLog.additional_fields?.build_reason &&
-    auditLog.additional_fields?.build_reason !== "initiator"
+    auditLog.additional_fields?.build_reason !== "initiator" &&
+    auditLog.additional_fields?.build_reason !== "admin_forced"
       ? "Coder automatically"
       : auditLog.user?.username.trim()
 
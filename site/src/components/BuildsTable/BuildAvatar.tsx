@@ -4,6 +4,7 @@ import { FC } from "react"
 import PlayArrowOutlined from "@mui/icons-material/PlayArrowOutlined"
 import PauseOutlined from "@mui/icons-material/PauseOutlined"
 import DeleteOutlined from "@mui/icons-material/DeleteOutlined"
+import ArchiveOutlined from "@mui/icons-material/ArchiveOutlined"
 import { WorkspaceBuild, WorkspaceTransition } from "api/typesGenerated"
 import { getDisplayWorkspaceBuildStatus } from "utils/workspace"
 import { Avatar, AvatarProps } from "components/Avatar/Avatar"
@@ -35,6 +36,7 @@ const iconByTransition: Record<WorkspaceTransition, JSX.Element> = {
   start: <PlayArrowOutlined />,
   stop: <PauseOutlined />,
   delete: <DeleteOutlined />,
+  archive: <ArchiveOutlined />,
 }
 
 export const BuildAvatar: FC<BuildAvatarProps> = ({ build, size }) => {
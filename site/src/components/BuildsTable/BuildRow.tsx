@@ -54,7 +54,8 @@ export const BuildRow: React.FC<BuildRowProps> = ({ build }) => {
               >
                 <span>
                   <strong>{initiatedBy}</strong>{" "}
-                  {build.reason !== "initiator"
+                  {build.reason !== "initiator" &&
+                  build.reason !== "admin_forced"
                     ? t("buildMessage.automatically")
                     : ""}
                   <strong>{t(`buildMessage.${build.transition}`)}</strong>{" "}
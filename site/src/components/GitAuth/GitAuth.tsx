@@ -38,6 +38,10 @@ export const GitAuth: FC<GitAuthProps> = ({
       prettyName = "Bitbucket"
       Icon = BitbucketIcon
       break
+    case "bitbucket-server":
+      prettyName = "Bitbucket Server"
+      Icon = BitbucketIcon
+      break
     case "github":
       prettyName = "GitHub"
       Icon = GitHub as (props: SvgIconProps) => JSX.Element
@@ -27,6 +27,7 @@ const descriptionDefaults: WorkspaceAgentMetadataDescription = {
   interval: 10,
   timeout: 10,
   script: "some command",
+  plugin: false,
 }
 
 export const Example = Template.bind({})
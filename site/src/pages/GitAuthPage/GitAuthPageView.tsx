@@ -164,6 +164,7 @@ const GitDeviceAuth: FC<{
     // See https://datatracker.ietf.org/doc/html/rfc8628#section-3.5
     switch (deviceExchangeError.detail) {
       case "authorization_pending":
+      case "slow_down":
         break
       case "expired_token":
         status = (
@@ -324,6 +324,13 @@ export const MockProvisioner: TypesGen.ProvisionerDaemon = {
   name: "Test Provisioner",
   provisioners: ["echo"],
   tags: {},
+  cache: {
+    entries: 0,
+    size_bytes: 0,
+    max_size_bytes: 0,
+    hits: 0,
+    misses: 0,
+  },
 }
 
 export const MockProvisionerJob: TypesGen.ProvisionerJob = {
@@ -435,6 +442,7 @@ export const MockTemplate: TypesGen.Template = {
   },
   description: "This is a test description.",
   default_ttl_ms: 24 * 60 * 60 * 1000,
+  activity_bump_ms: 0,
   max_ttl_ms: 2 * 24 * 60 * 60 * 1000,
   restart_requirement: {
     days_of_week: [],
@@ -444,9 +452,22 @@ export const MockTemplate: TypesGen.Template = {
   created_by_name: "test_creator",
   icon: "/icon/code.svg",
   allow_user_cancel_workspace_jobs: true,
+  max_concurrent_builds: 0,
+  max_deadline_extensions_per_day: 0,
+  max_deadline_extension_ms: 0,
+  required_promotion_approvals: 0,
+  user_cpu_quota_millicores: 0,
+  user_memory_quota_bytes: 0,
+  user_disk_quota_bytes: 0,
+  apply_dotfiles: true,
+  disable_autostop: false,
   failure_ttl_ms: 0,
   inactivity_ttl_ms: 0,
   locked_ttl_ms: 0,
+  failure_retries: 0,
+  failure_retry_backoff_ms: 0,
+  max_lifetime_ms: 0,
+  archive_retention_ms: 0,
   allow_user_autostart: false,
   allow_user_autostop: false,
 }
@@ -529,6 +550,7 @@ export const MockWorkspaceApp: TypesGen.WorkspaceApp = {
   external: false,
   url: "",
   sharing_level: "owner",
+  auth: "coder",
   healthcheck: {
     url: "",
     interval: 0,
@@ -1801,6 +1823,7 @@ export const MockWorkspaceAgentLogs: TypesGen.WorkspaceAgentLog[] = [
     created_at: "2023-05-04T11:30:41.402072Z",
     output: "+ curl -fsSL https://code-server.dev/install.sh",
     level: "info",
+    source: "startup_script",
   },
   {
     id: 166664,
@@ -1808,18 +1831,21 @@ export const MockWorkspaceAgentLogs: TypesGen.WorkspaceAgentLog[] = [
     output:
       "+ sh -s -- --method=standalone --prefix=/tmp/code-server --version 4.8.3",
     level: "info",
+    source: "startup_script",
   },
   {
     id: 166665,
     created_at: "2023-05-04T11:30:42.590731Z",
     output: "Ubuntu 22.04.2 LTS",
     level: "info",
+    source: "startup_script",
   },
   {
     id: 166666,
     created_at: "2023-05-04T11:30:42.593686Z",
     output: "Installing v4.8.3 of the amd64 release from GitHub.",
     level: "info",
+    source: "startup_script",
   },
 ]
 
@@ -78,9 +78,17 @@ export const getDisplayWorkspaceBuildInitiatedBy = (
 ): string => {
   switch (build.reason) {
     case "initiator":
+    case "admin_forced":
+    case "resume":
       return build.initiator_name
     case "autostart":
     case "autostop":
+    case "restart_requirement":
+    case "failure_ttl":
+    case "inactivity_ttl":
+    case "locked_ttl":
+    case "max_lifetime":
+    case "prebuild":
       return "Coder"
   }
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+	"tailscale.com/tailcfg"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
@@ -164,6 +165,60 @@ func TestTailnet(t *testing.T) {
 		w1.Close()
 		w2.Close()
 	})
+
+	t.Run("IPv6", func(t *testing.T) {
+		t.Parallel()
+
+		addressDERPMap, _ := tailnettest.RunDERPAndSTUNIPv6(t)
+		hostNameDERPMap := addressDERPMap.Clone()
+		for _, node := range hostNameDERPMap.Regions[1].Nodes {
+			node.HostName = "::1"
+			node.IPv4 = ""
+			node.IPv6 = ""
+		}
+
+		for name, derpMap := range map[string]*tailcfg.DERPMap{
+			"Address":  addressDERPMap,
+			"HostName": hostNameDERPMap,
+		} {
+			derpMap := derpMap
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				ctx := testutil.Context(t, testutil.WaitMedium)
+
+				w1IP := tailnet.IP()
+				w1, err := tailnet.NewConn(&tailnet.Options{
+					Addresses:      []netip.Prefix{netip.PrefixFrom(w1IP, 128)},
+					Logger:         logger.Named("w1"),
+					DERPMap:        derpMap,
+					BlockEndpoints: true,
+				})
+				require.NoError(t, err)
+
+				w2, err := tailnet.NewConn(&tailnet.Options{
+					Addresses:      []netip.Prefix{netip.PrefixFrom(tailnet.IP(), 128)},
+					Logger:         logger.Named("w2"),
+					DERPMap:        derpMap,
+					BlockEndpoints: true,
+				})
+				require.NoError(t, err)
+				t.Cleanup(func() {
+					_ = w1.Close()
+					_ = w2.Close()
+				})
+				w1.SetNodeCallback(func(node *tailnet.Node) {
+					err := w2.UpdateNodes([]*tailnet.Node{node}, false)
+					assert.NoError(t, err)
+				})
+				w2.SetNodeCallback(func(node *tailnet.Node) {
+					err := w1.UpdateNodes([]*tailnet.Node{node}, false)
+					assert.NoError(t, err)
+				})
+				require.True(t, w2.AwaitReachable(ctx, w1IP))
+				require.Equal(t, 1, w1.Node().PreferredDERP)
+			})
+		}
+	})
 }
 
 // TestConn_PreferredDERP tests that we only trigger the NodeCallback when we have a preferred DERP server.
@@ -1,6 +1,7 @@
 package tailnet
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,6 +11,7 @@ import (
 	"net"
 	"net/http"
 	"net/netip"
+	"strings"
 	"sync"
 	"time"
 
@@ -670,8 +672,8 @@ func HTTPDebugFromLocal(
 		agent := &HTMLAgent{
 			Name:         conn.Name(),
 			ID:           id,
-			CreatedAge:   now.Sub(time.Unix(start, 0)).Round(time.Second),
-			LastWriteAge: now.Sub(time.Unix(lastWrite, 0)).Round(time.Second),
+			CreatedAge:   debugAge(now, time.Unix(start, 0)),
+			LastWriteAge: debugAge(now, time.Unix(lastWrite, 0)),
 			Overwrites:   int(conn.Overwrites()),
 		}
 
@@ -680,8 +682,8 @@ func HTTPDebugFromLocal(
 			agent.Connections = append(agent.Connections, &HTMLClient{
 				Name:         conn.Name(),
 				ID:           id,
-				CreatedAge:   now.Sub(time.Unix(start, 0)).Round(time.Second),
-				LastWriteAge: now.Sub(time.Unix(lastWrite, 0)).Round(time.Second),
+				CreatedAge:   debugAge(now, time.Unix(start, 0)),
+				LastWriteAge: debugAge(now, time.Unix(lastWrite, 0)),
 			})
 		}
 		slices.SortFunc(agent.Connections, func(a, b *HTMLClient) int {
@@ -716,8 +718,8 @@ func HTTPDebugFromLocal(
 			agent.Connections = append(agent.Connections, &HTMLClient{
 				Name:         conn.Name(),
 				ID:           id,
-				CreatedAge:   now.Sub(time.Unix(start, 0)).Round(time.Second),
-				LastWriteAge: now.Sub(time.Unix(lastWrite, 0)).Round(time.Second),
+				CreatedAge:   debugAge(now, time.Unix(start, 0)),
+				LastWriteAge: debugAge(now, time.Unix(lastWrite, 0)),
 			})
 		}
 		slices.SortFunc(agent.Connections, func(a, b *HTMLClient) int {
@@ -732,11 +734,7 @@ func HTTPDebugFromLocal(
 
 	for id, node := range nodesMap {
 		name, _ := agentNameCache.Get(id)
-		data.Nodes = append(data.Nodes, &HTMLNode{
-			ID:   id,
-			Name: name,
-			Node: node,
-		})
+		data.Nodes = append(data.Nodes, NewHTMLNode(now, id, name, node))
 	}
 	slices.SortFunc(data.Nodes, func(a, b *HTMLNode) int {
 		return slice.Ascending(a.Name+a.ID.String(), b.Name+b.ID.String())
@@ -745,9 +743,26 @@ func HTTPDebugFromLocal(
 	return data
 }
 
-func CoordinatorHTTPDebug(data HTMLDebug) func(w http.ResponseWriter, _ *http.Request) {
-	return func(w http.ResponseWriter, _ *http.Request) {
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+// CoordinatorHTTPDebug serves the debug data as a webpage, or as JSON if the
+// request accepts application/json.
+func CoordinatorHTTPDebug(data HTMLDebug) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// The response is rendered before it's written, so errors can still
+		// be reported with a status code.
+		var buf bytes.Buffer
+		if strings.Contains(r.Header.Get("Accept"), "application/json") {
+			enc := json.NewEncoder(&buf)
+			enc.SetIndent("", "  ")
+			err := enc.Encode(data)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(err.Error()))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(buf.Bytes())
+			return
+		}
 
 		tmpl, err := template.New("coordinator_debug").Funcs(template.FuncMap{
 			"marshal": func(v any) template.JS {
@@ -766,42 +781,103 @@ func CoordinatorHTTPDebug(data HTMLDebug) func(w http.ResponseWriter, _ *http.Re
 			return
 		}
 
-		err = tmpl.Execute(w, data)
+		err = tmpl.Execute(&buf, data)
 		if err != nil {
 			w.WriteHeader(http.StatusInternalServerError)
 			_, _ = w.Write([]byte(err.Error()))
 			return
 		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(buf.Bytes())
 	}
 }
 
+// HTMLDebug is the state of a coordinator. It's served as a webpage, or as
+// JSON to clients such as "coder netcheck --coordinator". The HA coordinator
+// only knows about the agents and clients connected to the replica that
+// served the request.
 type HTMLDebug struct {
-	HA            bool
-	Agents        []*HTMLAgent
-	MissingAgents []*HTMLAgent
-	Nodes         []*HTMLNode
+	HA     bool         `json:"ha"`
+	Agents []*HTMLAgent `json:"agents"`
+	// MissingAgents are agents that clients are trying to connect to, but
+	// that aren't connected to the coordinator.
+	MissingAgents []*HTMLAgent `json:"missing_agents"`
+	Nodes         []*HTMLNode  `json:"nodes"`
 }
 
 type HTMLAgent struct {
-	Name         string
-	ID           uuid.UUID
-	CreatedAge   time.Duration
-	LastWriteAge time.Duration
-	Overwrites   int
-	Connections  []*HTMLClient
+	Name string    `json:"name"`
+	ID   uuid.UUID `json:"id"`
+	// CreatedAge is how long ago the agent connected.
+	CreatedAge HTMLDuration `json:"created_age"`
+	// LastWriteAge is how long ago the connection of the agent was last
+	// updated.
+	LastWriteAge HTMLDuration  `json:"last_write_age"`
+	Overwrites   int           `json:"overwrites"`
+	Connections  []*HTMLClient `json:"connections"`
 }
 
 type HTMLClient struct {
-	Name         string
-	ID           uuid.UUID
-	CreatedAge   time.Duration
-	LastWriteAge time.Duration
+	Name         string       `json:"name"`
+	ID           uuid.UUID    `json:"id"`
+	CreatedAge   HTMLDuration `json:"created_age"`
+	LastWriteAge HTMLDuration `json:"last_write_age"`
 }
 
+// HTMLNode is the latest node of an agent or client, which includes its
+// preferred DERP region.
 type HTMLNode struct {
-	ID   uuid.UUID
-	Name string
-	Node any
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// HandshakeAge is how long ago the peer last sent the node, with the
+	// keys and endpoints that other peers handshake with, to the
+	// coordinator.
+	HandshakeAge HTMLDuration `json:"handshake_age"`
+	Node         *Node        `json:"node"`
+}
+
+// NewHTMLNode returns the debug state of the node of an agent or client.
+func NewHTMLNode(now time.Time, id uuid.UUID, name string, node *Node) *HTMLNode {
+	n := &HTMLNode{
+		ID:   id,
+		Name: name,
+		Node: node,
+	}
+	if node != nil && !node.AsOf.IsZero() {
+		n.HandshakeAge = debugAge(now, node.AsOf)
+	}
+	return n
+}
+
+// HTMLDuration is a duration that is formatted like "1m30s", so it's readable
+// on the debug page and in its JSON.
+type HTMLDuration time.Duration
+
+func (d HTMLDuration) String() string {
+	return time.Duration(d).String()
+}
+
+func (d HTMLDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *HTMLDuration) UnmarshalJSON(b []byte) error {
+	var s string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = HTMLDuration(parsed)
+	return nil
+}
+
+// debugAge returns how long ago t was, rounded to seconds.
+func debugAge(now, t time.Time) HTMLDuration {
+	return HTMLDuration(now.Sub(t).Round(time.Second))
 }
 
 var coordinatorDebugTmpl = `
@@ -849,7 +925,7 @@ var coordinatorDebugTmpl = `
 		<h2 id=nodes><a href=#nodes>#</a> nodes: total {{ len .Nodes }}</h2>
 		<ul>
 		{{- range .Nodes }}
-			<li style="margin-top:4px"><b>{{ .Name }}</b> (<code>{{ .ID }}</code>):
+			<li style="margin-top:4px"><b>{{ .Name }}</b> (<code>{{ .ID }}</code>): handshake {{ if .Node }}{{ .HandshakeAge }} ago{{ else }}never{{ end }}
 				<span style="white-space: pre;"><code>{{ marshal .Node }}</code></span>
 			</li>
 		{{- end }}
@@ -3,6 +3,7 @@ package tailnet
 import (
 	"bufio"
 	"context"
+	"expvar"
 	"log"
 	"net/http"
 	"strings"
@@ -10,9 +11,52 @@ import (
 
 	"nhooyr.io/websocket"
 	"tailscale.com/derp"
+	"tailscale.com/metrics"
 	"tailscale.com/net/wsconn"
 )
 
+// DERPServerStats are statistics of a DERP server since it was started.
+type DERPServerStats struct {
+	// CurrentConnections is the number of clients connected to the server.
+	CurrentConnections int64
+	// CurrentHomeConnections is the number of connected clients that use the
+	// server as their preferred DERP server.
+	CurrentHomeConnections int64
+	Accepts                int64
+	BytesReceived          int64
+	BytesSent              int64
+	PacketsReceived        int64
+	PacketsSent            int64
+	PacketsDropped         int64
+}
+
+// ReadDERPServerStats returns the current statistics of the DERP server.
+func ReadDERPServerStats(s *derp.Server) DERPServerStats {
+	set, ok := s.ExpVar().(*metrics.Set)
+	if !ok {
+		return DERPServerStats{}
+	}
+	value := func(name string) int64 {
+		// Only read counters, the other values of the set may lock the
+		// server or read its state without synchronization.
+		v, ok := set.Get(name).(*expvar.Int)
+		if !ok {
+			return 0
+		}
+		return v.Value()
+	}
+	return DERPServerStats{
+		CurrentConnections:     value("gauge_current_connections"),
+		CurrentHomeConnections: value("gauge_current_home_connections"),
+		Accepts:                value("accepts"),
+		BytesReceived:          value("bytes_received"),
+		BytesSent:              value("bytes_sent"),
+		PacketsReceived:        value("packets_received"),
+		PacketsSent:            value("packets_sent"),
+		PacketsDropped:         value("packets_dropped"),
+	}
+}
+
 // WithWebsocketSupport returns an http.Handler that upgrades
 // connections to the "derp" subprotocol to WebSockets and
 // passes them to the DERP server.
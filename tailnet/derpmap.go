@@ -6,11 +6,15 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/netip"
 	"os"
 	"strconv"
+	"time"
 
 	"golang.org/x/xerrors"
 	"tailscale.com/tailcfg"
+
+	"cdr.dev/slog"
 )
 
 func STUNRegions(baseRegionID int, stunAddrs []string) ([]*tailcfg.DERPRegion, error) {
@@ -30,18 +34,29 @@ func STUNRegions(baseRegionID int, stunAddrs []string) ([]*tailcfg.DERPRegion, e
 		}
 
 		regionID := baseRegionID + index + 1
+		node := &tailcfg.DERPNode{
+			Name:     fmt.Sprintf("%dstun0", regionID),
+			RegionID: regionID,
+			HostName: host,
+			STUNOnly: true,
+			STUNPort: port,
+		}
+		// STUN servers given by IP are only probed over the IP family of
+		// their address, which lets IPv6-only hosts use IPv6 STUN servers.
+		if ip, err := netip.ParseAddr(host); err == nil {
+			node.IPv4, node.IPv6 = "none", "none"
+			if ip.Is4() {
+				node.IPv4 = ip.String()
+			} else {
+				node.IPv6 = ip.String()
+			}
+		}
 		regions = append(regions, &tailcfg.DERPRegion{
 			EmbeddedRelay: false,
 			RegionID:      regionID,
 			RegionCode:    fmt.Sprintf("coder_stun_%d", regionID),
 			RegionName:    fmt.Sprintf("Coder STUN %d", regionID),
-			Nodes: []*tailcfg.DERPNode{{
-				Name:     fmt.Sprintf("%dstun0", regionID),
-				RegionID: regionID,
-				HostName: host,
-				STUNOnly: true,
-				STUNPort: port,
-			}},
+			Nodes:         []*tailcfg.DERPNode{node},
 		})
 	}
 
@@ -131,6 +146,37 @@ func NewDERPMap(ctx context.Context, region *tailcfg.DERPRegion, stunAddrs []str
 	return derpMap, nil
 }
 
+// ReloadDERPMap calls fetch every interval until the context is canceled, and
+// calls update with the fetched DERP map whenever it differs from the previous
+// one. Fetch errors are logged and the previous DERP map is kept.
+func ReloadDERPMap(ctx context.Context, logger slog.Logger, interval time.Duration, current *tailcfg.DERPMap, fetch func(ctx context.Context) (*tailcfg.DERPMap, error), update func(derpMap *tailcfg.DERPMap)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		derpMap, err := fetch(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			logger.Warn(ctx, "reload derp map", slog.Error(err))
+			continue
+		}
+		if CompareDERPMaps(current, derpMap) {
+			continue
+		}
+		logger.Info(ctx, "derp map changed, updating", slog.F("region_ids", derpMap.RegionIDs()))
+		update(derpMap)
+		current = derpMap
+	}
+}
+
 // CompareDERPMaps returns true if the given DERPMaps are equivalent. Ordering
 // of slices is ignored.
 //
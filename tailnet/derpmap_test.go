@@ -7,12 +7,17 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
 	"tailscale.com/tailcfg"
 
+	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/tailnet"
+	"github.com/coder/coder/testutil"
 )
 
 func TestNewDERPMap(t *testing.T) {
@@ -28,6 +33,28 @@ func TestNewDERPMap(t *testing.T) {
 		require.Len(t, derpMap.Regions[1].Nodes, 1)
 		require.Len(t, derpMap.Regions[2].Nodes, 1)
 	})
+	t.Run("STUNAddresses", func(t *testing.T) {
+		t.Parallel()
+		derpMap, err := tailnet.NewDERPMap(context.Background(), &tailcfg.DERPRegion{
+			RegionID: 1,
+			Nodes:    []*tailcfg.DERPNode{{}},
+		}, []string{"stun.google.com:2345", "127.0.0.1:3478", "[2001:db8::1]:3478"}, "", "", false)
+		require.NoError(t, err)
+		require.Len(t, derpMap.Regions, 4)
+
+		node := derpMap.Regions[2].Nodes[0]
+		require.Equal(t, "stun.google.com", node.HostName)
+		require.Empty(t, node.IPv4)
+		require.Empty(t, node.IPv6)
+		node = derpMap.Regions[3].Nodes[0]
+		require.Equal(t, "127.0.0.1", node.IPv4)
+		require.Equal(t, "none", node.IPv6)
+		node = derpMap.Regions[4].Nodes[0]
+		require.Equal(t, "2001:db8::1", node.HostName)
+		require.Equal(t, "none", node.IPv4)
+		require.Equal(t, "2001:db8::1", node.IPv6)
+		require.Equal(t, 3478, node.STUNPort)
+	})
 	t.Run("RemoteURL", func(t *testing.T) {
 		t.Parallel()
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -132,3 +159,49 @@ func TestNewDERPMap(t *testing.T) {
 		require.EqualValues(t, -1, derpMap.Regions[3].Nodes[0].STUNPort)
 	})
 }
+
+func TestReloadDERPMap(t *testing.T) {
+	t.Parallel()
+
+	logger := slogtest.Make(t, &slogtest.Options{IgnoreErrors: true})
+	initial := &tailcfg.DERPMap{Regions: map[int]*tailcfg.DERPRegion{
+		1: {RegionID: 1, RegionCode: "one"},
+	}}
+	changed := &tailcfg.DERPMap{Regions: map[int]*tailcfg.DERPRegion{
+		2: {RegionID: 2, RegionCode: "two"},
+	}}
+
+	var fetches atomic.Int64
+	fetch := func(context.Context) (*tailcfg.DERPMap, error) {
+		switch fetches.Add(1) {
+		case 1:
+			// Unchanged maps are not passed to update.
+			return initial.Clone(), nil
+		case 2:
+			return nil, xerrors.New("fetch failed")
+		default:
+			return changed.Clone(), nil
+		}
+	}
+	updates := make(chan *tailcfg.DERPMap, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tailnet.ReloadDERPMap(ctx, logger, testutil.IntervalFast, initial, fetch, func(derpMap *tailcfg.DERPMap) {
+		updates <- derpMap
+	})
+
+	select {
+	case derpMap := <-updates:
+		require.True(t, tailnet.CompareDERPMaps(changed, derpMap))
+		require.GreaterOrEqual(t, fetches.Load(), int64(3))
+	case <-time.After(testutil.WaitShort):
+		t.Fatal("timed out waiting for the DERP map to be reloaded")
+	}
+
+	// The same map is fetched again, so no further updates are sent.
+	require.Eventually(t, func() bool {
+		return fetches.Load() >= 6
+	}, testutil.WaitShort, testutil.IntervalFast)
+	require.Empty(t, updates)
+}
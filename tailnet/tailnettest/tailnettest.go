@@ -1,6 +1,7 @@
 package tailnettest
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
 	"html"
@@ -66,6 +67,85 @@ func RunDERPAndSTUN(t *testing.T) (*tailcfg.DERPMap, *derp.Server) {
 	}, d
 }
 
+// RunDERPAndSTUNIPv6 is like RunDERPAndSTUN, but the DERP and STUN servers only
+// listen on the IPv6 loopback address, and IPv4 is disabled for the node. The
+// test is skipped if the host doesn't support IPv6.
+func RunDERPAndSTUNIPv6(t *testing.T) (*tailcfg.DERPMap, *derp.Server) {
+	logf := tailnet.Logger(slogtest.Make(t, nil))
+	d := derp.NewServer(key.NewNode(), logf)
+	server := httptest.NewUnstartedServer(derphttp.Handler(d))
+	_ = server.Listener.Close()
+	server.Listener = ListenIPv6(t)
+	server.Config.ErrorLog = tslogger.StdLogger(logf)
+	server.Config.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	server.StartTLS()
+
+	stunAddr := RunSTUNIPv6(t)
+	t.Cleanup(func() {
+		server.CloseClientConnections()
+		server.Close()
+		d.Close()
+	})
+	tcpAddr, ok := server.Listener.Addr().(*net.TCPAddr)
+	if !ok {
+		t.FailNow()
+	}
+
+	return &tailcfg.DERPMap{
+		Regions: map[int]*tailcfg.DERPRegion{
+			1: {
+				RegionID:   1,
+				RegionCode: "test",
+				RegionName: "Test",
+				Nodes: []*tailcfg.DERPNode{
+					{
+						Name:             "t6",
+						RegionID:         1,
+						IPv4:             "none",
+						IPv6:             "::1",
+						STUNPort:         stunAddr.Port,
+						DERPPort:         tcpAddr.Port,
+						InsecureForTests: true,
+					},
+				},
+			},
+		},
+	}, d
+}
+
+// ListenIPv6 listens on a random TCP port of the IPv6 loopback address. The
+// test is skipped if the host doesn't support IPv6.
+func ListenIPv6(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 is not supported: %s", err)
+	}
+	return listener
+}
+
+// RunSTUNIPv6 runs a STUN server that only listens on the IPv6 loopback
+// address. The test is skipped if the host doesn't support IPv6.
+func RunSTUNIPv6(t *testing.T) *net.UDPAddr {
+	stunAddr, stunCleanup := stuntest.ServeWithPacketListener(t, ipv6PacketListener{t: t})
+	t.Cleanup(stunCleanup)
+	return stunAddr
+}
+
+// ipv6PacketListener listens on the IPv6 loopback address, regardless of the
+// network and address it's asked to listen on.
+type ipv6PacketListener struct {
+	t *testing.T
+}
+
+func (l ipv6PacketListener) ListenPacket(ctx context.Context, _, _ string) (net.PacketConn, error) {
+	var lc net.ListenConfig
+	conn, err := lc.ListenPacket(ctx, "udp6", "[::1]:0")
+	if err != nil {
+		l.t.Skipf("IPv6 is not supported: %s", err)
+	}
+	return conn, nil
+}
+
 // RunDERPOnlyWebSockets creates a DERP mapping for tests that
 // only allows WebSockets through it. Many proxies do not support
 // upgrading DERP, so this is a good fallback.